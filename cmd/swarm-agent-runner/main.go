@@ -10,10 +10,13 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/opencode-ai/swarm/internal/adapters"
 	"github.com/opencode-ai/swarm/internal/agent/runner"
 	"github.com/opencode-ai/swarm/internal/config"
 	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/fsperm"
 	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/redact"
 	"github.com/rs/zerolog"
 )
 
@@ -28,6 +31,7 @@ func main() {
 	workspaceID := flag.String("workspace", "", "workspace id (required)")
 	agentID := flag.String("agent", "", "agent id (required)")
 	eventSocket := flag.String("event-socket", "", "unix socket path for runner events")
+	adapter := flag.String("adapter", "", "agent type whose prompt and busy regexes are the defaults")
 	promptRegex := flag.String("prompt-regex", "", "regex to detect prompt readiness")
 	busyRegex := flag.String("busy-regex", "", "regex to detect busy output")
 	heartbeat := flag.Duration("heartbeat", 5*time.Second, "heartbeat interval")
@@ -58,7 +62,21 @@ func main() {
 		logger.Warn().Err(err).Msg("failed to create directories")
 	}
 
-	sink, cleanup, err := buildEventSink(context.Background(), cfg, *workspaceID, *agentID, *eventSocket, *dbPath)
+	// The event socket is checked too: one another user owns or can
+	// write to could read or forge this agent's events.
+	permissionPaths := append(cfg.PermissionPaths(), *eventSocket, *dbPath)
+	if err := fsperm.Verify(logger, cfg.Global.StrictPermissions, permissionPaths...); err != nil {
+		logger.Error().Err(err).Msg("refusing to start; run 'swarm doctor --fix-permissions'")
+		os.Exit(1)
+	}
+
+	redactor, err := redact.FromConfig(cfg.Redaction)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to build redactor")
+		os.Exit(1)
+	}
+
+	sink, cleanup, err := buildEventSink(context.Background(), cfg, redactor, *workspaceID, *agentID, *eventSocket, *dbPath)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to initialize event sink")
 		os.Exit(1)
@@ -85,11 +103,13 @@ func main() {
 		WorkspaceID:       *workspaceID,
 		AgentID:           *agentID,
 		Command:           cmdArgs,
+		Adapter:           *adapter,
 		PromptRegex:       promptRE,
 		BusyRegex:         busyRE,
 		HeartbeatInterval: *heartbeat,
 		TailLines:         *tailLines,
 		EventSink:         sink,
+		Redactor:          redactor,
 		ControlReader:     os.Stdin,
 		OutputWriter:      os.Stdout,
 	}
@@ -122,6 +142,10 @@ func loadConfig(path, level, format string) (*config.Config, zerolog.Logger) {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
+	if err := adapters.ApplyOverrides(cfg.AdapterOverrides()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error applying adapter overrides: %v\n", err)
+		os.Exit(1)
+	}
 
 	if level != "" {
 		cfg.Logging.Level = level
@@ -144,7 +168,7 @@ func loadConfig(path, level, format string) (*config.Config, zerolog.Logger) {
 	return cfg, logger
 }
 
-func buildEventSink(ctx context.Context, cfg *config.Config, workspaceID, agentID, eventSocket, dbPath string) (runner.EventSink, func(), error) {
+func buildEventSink(ctx context.Context, cfg *config.Config, redactor *redact.Redactor, workspaceID, agentID, eventSocket, dbPath string) (runner.EventSink, func(), error) {
 	if eventSocket != "" {
 		sink, err := runner.NewSocketEventSink(eventSocket)
 		if err != nil {
@@ -159,6 +183,7 @@ func buildEventSink(ctx context.Context, cfg *config.Config, workspaceID, agentI
 
 	dbConfig := db.DefaultConfig()
 	dbConfig.Path = dbPath
+	dbConfig.Redactor = redactor
 	if cfg.Database.MaxConnections > 0 {
 		dbConfig.MaxOpenConns = cfg.Database.MaxConnections
 	}
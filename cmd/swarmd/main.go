@@ -11,9 +11,19 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/agent"
 	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/fsperm"
 	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/redact"
 	"github.com/opencode-ai/swarm/internal/swarmd"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/vault"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/rs/zerolog"
 )
 
 // Version information (set by goreleaser)
@@ -35,19 +45,39 @@ func main() {
 	diskCritical := flag.Float64("disk-critical", defaultDisk.CriticalPercent, "disk usage percent to treat as critical")
 	diskResume := flag.Float64("disk-resume", defaultDisk.ResumePercent, "disk usage percent to resume paused agents")
 	diskPause := flag.Bool("disk-pause", defaultDisk.PauseAgents, "pause agent processes when disk is critically full")
+	skipRecovery := flag.Bool("skip-recovery", false, "skip reconciling stored agents against live tmux panes on startup")
+	skipSchedules := flag.Bool("skip-schedules", false, "do not run recurring schedules")
+	skipStandby := flag.Bool("skip-standby", false, "do not keep standby agent pools filled")
+	enableReflection := flag.Bool("reflection", false, "register the gRPC server reflection service")
+	healthAddr := flag.String("health-addr", "", "address for the /healthz, /readyz, and /metrics HTTP endpoints (e.g. 127.0.0.1:8081; empty disables)")
+	serveDegraded := flag.Bool("serve-degraded", false, "report ready, as degraded, while tmux is unavailable")
+	debugEndpoint := flag.Bool("debug-endpoint", false, "enable the DumpState debug RPC (requires "+swarmd.DebugTokenEnv+")")
+	strictPermissions := flag.Bool("strict-permissions", false, "refuse to start when the data directory, database, or vault is open to other users (also global.strict_permissions)")
 	flag.Parse()
 
-	cfg, loader, err := loadConfig(*configFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
+	// Reloads read the config the same way, flags included, so only real
+	// changes to the file are reported.
+	load := func() (*config.Config, string, error) {
+		cfg, loader, err := loadConfig(*configFile)
+		if err != nil {
+			return nil, "", err
+		}
+		if *logLevel != "" {
+			cfg.Logging.Level = *logLevel
+		}
+		if *logFormat != "" {
+			cfg.Logging.Format = *logFormat
+		}
+		return cfg, loader.ConfigFileUsed(), nil
 	}
 
-	if *logLevel != "" {
-		cfg.Logging.Level = *logLevel
+	cfg, cfgUsed, err := load()
+	if err == nil {
+		err = adapters.ApplyOverrides(cfg.AdapterOverrides())
 	}
-	if *logFormat != "" {
-		cfg.Logging.Format = *logFormat
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
 	}
 
 	logging.Init(logging.Config{
@@ -61,7 +91,13 @@ func main() {
 		logger.Warn().Err(err).Msg("failed to create directories")
 	}
 
-	if cfgUsed := loader.ConfigFileUsed(); cfgUsed != "" {
+	permissionPaths := append(cfg.PermissionPaths(), vault.PermissionPaths(vault.DefaultVaultPath())...)
+	if err := fsperm.Verify(logger, *strictPermissions || cfg.Global.StrictPermissions, permissionPaths...); err != nil {
+		logger.Error().Err(err).Msg("refusing to start; run 'swarm doctor --fix-permissions'")
+		os.Exit(1)
+	}
+
+	if cfgUsed != "" {
 		logger.Debug().Str("config_file", cfgUsed).Msg("loaded config file")
 	}
 
@@ -86,10 +122,49 @@ func main() {
 	diskConfig.ResumePercent = *diskResume
 	diskConfig.PauseAgents = *diskPause
 
-	daemon, err := swarmd.New(cfg, logger, swarmd.Options{
+	database := openDatabase(cfg, logger)
+	if database != nil {
+		defer database.Close()
+
+		// Held while swarmd runs so destructive maintenance such as
+		// 'swarm migrate down' refuses to proceed underneath it.
+		daemonLock, err := database.HoldDaemonLock(ctx)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to lock the database")
+			os.Exit(1)
+		}
+		defer daemonLock.Release()
+	}
+
+	// Standby claims made through the daemon's agent service ask it to
+	// refill the pool right away rather than at the next check.
+	var daemon *swarmd.Daemon
+	standbySpawner := newStandbySpawner(database, func(workspaceID string) {
+		if daemon != nil && daemon.StandbyRunner() != nil {
+			daemon.StandbyRunner().Trigger(workspaceID)
+		}
+	})
+
+	daemon, err = swarmd.New(cfg, logger, swarmd.Options{
 		Hostname:          *hostname,
 		Port:              *port,
+		Version:           version,
+		Commit:            commit,
+		BuildDate:         date,
 		DiskMonitorConfig: &diskConfig,
+		Database:          database,
+		SkipRecovery:      *skipRecovery,
+		SkipSchedules:     *skipSchedules,
+		StandbySpawner:    standbySpawner,
+		SkipStandby:       *skipStandby,
+		Reflection:        *enableReflection,
+		DebugEndpoint:     *debugEndpoint,
+		HealthAddr:        *healthAddr,
+		ServeDegraded:     *serveDegraded,
+		DebugToken:        os.Getenv(swarmd.DebugTokenEnv),
+		AuthToken:         os.Getenv(swarmd.AuthTokenEnv),
+		LoadConfig:        load,
+		ConfigFile:        cfgUsed,
 	})
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to initialize swarmd")
@@ -102,6 +177,44 @@ func main() {
 	}
 }
 
+// openDatabase opens the existing swarm database for startup recovery and
+// recurring schedules. It returns nil when there is no database yet or it
+// cannot be opened.
+func openDatabase(cfg *config.Config, logger zerolog.Logger) *db.DB {
+	path := cfg.DatabasePath()
+	if _, err := os.Stat(path); err != nil {
+		logger.Debug().Str("path", path).Msg("no database found, skipping startup recovery")
+		return nil
+	}
+
+	redactor, err := redact.FromConfig(cfg.Redaction)
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid redaction config; redacting events with the default rules")
+	}
+
+	database, err := db.Open(db.Config{Path: path, MaxOpenConns: 4, BusyTimeoutMs: 5000, EventOutbox: cfg.EventBridge.Enabled, Redactor: redactor})
+	if err != nil {
+		logger.Warn().Err(err).Str("path", path).Msg("failed to open database, skipping startup recovery")
+		return nil
+	}
+	return database
+}
+
+// newStandbySpawner returns the agent service standby pools spawn through,
+// or nil without a database.
+func newStandbySpawner(database *db.DB, replenish func(workspaceID string)) swarmd.StandbySpawner {
+	if database == nil {
+		return nil
+	}
+	agentRepo := db.NewAgentRepository(database)
+	nodeRepo := db.NewNodeRepository(database)
+	workspaceService := workspace.NewService(db.NewWorkspaceRepository(database), node.NewService(nodeRepo), agentRepo)
+	return agent.NewService(agentRepo, db.NewQueueRepository(database), workspaceService, nil, tmux.NewLocalClient(),
+		agent.WithEventRepository(db.NewEventRepository(database)),
+		agent.WithStandbyReplenish(replenish),
+	)
+}
+
 func loadConfig(path string) (*config.Config, *config.Loader, error) {
 	loader := config.NewLoader()
 	if path != "" {
@@ -273,6 +273,7 @@ const (
 	TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE TranscriptEntryType = 4
 	TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_APPROVAL     TranscriptEntryType = 5
 	TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_USER_INPUT   TranscriptEntryType = 6
+	TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_SUMMARY      TranscriptEntryType = 7
 )
 
 // Enum value maps for TranscriptEntryType.
@@ -285,6 +286,7 @@ var (
 		4: "TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE",
 		5: "TRANSCRIPT_ENTRY_TYPE_APPROVAL",
 		6: "TRANSCRIPT_ENTRY_TYPE_USER_INPUT",
+		7: "TRANSCRIPT_ENTRY_TYPE_SUMMARY",
 	}
 	TranscriptEntryType_value = map[string]int32{
 		"TRANSCRIPT_ENTRY_TYPE_UNSPECIFIED":  0,
@@ -294,6 +296,7 @@ var (
 		"TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE": 4,
 		"TRANSCRIPT_ENTRY_TYPE_APPROVAL":     5,
 		"TRANSCRIPT_ENTRY_TYPE_USER_INPUT":   6,
+		"TRANSCRIPT_ENTRY_TYPE_SUMMARY":      7,
 	}
 )
 
@@ -396,8 +399,20 @@ type SpawnAgentRequest struct {
 	Adapter string `protobuf:"bytes,8,opt,name=adapter,proto3" json:"adapter,omitempty"`
 	// Resource limits for the agent (optional).
 	ResourceLimits *ResourceLimits `protobuf:"bytes,9,opt,name=resource_limits,json=resourceLimits,proto3" json:"resource_limits,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// Adopt the existing pane pane_id instead of creating one. Nothing is
+	// sent to the pane; command may be empty and only describes the agent.
+	// The pane's scrollback is imported into the transcript as backfilled
+	// OUTPUT entries.
+	AttachExistingPane bool `protobuf:"varint,10,opt,name=attach_existing_pane,json=attachExistingPane,proto3" json:"attach_existing_pane,omitempty"`
+	// Pane to adopt when attach_existing_pane is set (e.g. "%3").
+	PaneId string `protobuf:"bytes,11,opt,name=pane_id,json=paneId,proto3" json:"pane_id,omitempty"`
+	// Initial pane size in cells. Unset keeps the size the pane is split
+	// at; either may be set alone. Ignored by the runner backend, which
+	// has no panes.
+	PaneWidth     int32 `protobuf:"varint,12,opt,name=pane_width,json=paneWidth,proto3" json:"pane_width,omitempty"`
+	PaneHeight    int32 `protobuf:"varint,13,opt,name=pane_height,json=paneHeight,proto3" json:"pane_height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SpawnAgentRequest) Reset() {
@@ -493,6 +508,34 @@ func (x *SpawnAgentRequest) GetResourceLimits() *ResourceLimits {
 	return nil
 }
 
+func (x *SpawnAgentRequest) GetAttachExistingPane() bool {
+	if x != nil {
+		return x.AttachExistingPane
+	}
+	return false
+}
+
+func (x *SpawnAgentRequest) GetPaneId() string {
+	if x != nil {
+		return x.PaneId
+	}
+	return ""
+}
+
+func (x *SpawnAgentRequest) GetPaneWidth() int32 {
+	if x != nil {
+		return x.PaneWidth
+	}
+	return 0
+}
+
+func (x *SpawnAgentRequest) GetPaneHeight() int32 {
+	if x != nil {
+		return x.PaneHeight
+	}
+	return 0
+}
+
 // ResourceLimits defines resource constraints for an agent.
 type ResourceLimits struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -747,7 +790,11 @@ type SendInputRequest struct {
 	// If true, press Enter after the text.
 	SendEnter bool `protobuf:"varint,3,opt,name=send_enter,json=sendEnter,proto3" json:"send_enter,omitempty"`
 	// Special keys to send (e.g., "C-c" for Ctrl+C).
-	Keys          []string `protobuf:"bytes,4,rep,name=keys,proto3" json:"keys,omitempty"`
+	Keys []string `protobuf:"bytes,4,rep,name=keys,proto3" json:"keys,omitempty"`
+	// If true, the text is a secret: the transcript records a
+	// "[sensitive input]" marker instead, and pane output is not recorded
+	// while it may show the text.
+	Sensitive     bool `protobuf:"varint,5,opt,name=sensitive,proto3" json:"sensitive,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -810,6 +857,13 @@ func (x *SendInputRequest) GetKeys() []string {
 	return nil
 }
 
+func (x *SendInputRequest) GetSensitive() bool {
+	if x != nil {
+		return x.Sensitive
+	}
+	return false
+}
+
 type SendInputResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Whether input was successfully sent.
@@ -954,8 +1008,15 @@ func (x *ListAgentsResponse) GetAgents() []*Agent {
 }
 
 type GetAgentRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Optional long poll. When set, the request is held until the agent
+	// leaves this state or max_wait elapses, then the agent is returned as
+	// it is. An agent removed meanwhile yields NOT_FOUND.
+	WaitForStateOtherThan AgentState `protobuf:"varint,2,opt,name=wait_for_state_other_than,json=waitForStateOtherThan,proto3,enum=swarmd.v1.AgentState" json:"wait_for_state_other_than,omitempty"`
+	// Longest to hold a long poll. Unset or above the server's cap waits for
+	// the cap (60s).
+	MaxWait       *durationpb.Duration `protobuf:"bytes,3,opt,name=max_wait,json=maxWait,proto3" json:"max_wait,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -997,6 +1058,20 @@ func (x *GetAgentRequest) GetAgentId() string {
 	return ""
 }
 
+func (x *GetAgentRequest) GetWaitForStateOtherThan() AgentState {
+	if x != nil {
+		return x.WaitForStateOtherThan
+	}
+	return AgentState_AGENT_STATE_UNSPECIFIED
+}
+
+func (x *GetAgentRequest) GetMaxWait() *durationpb.Duration {
+	if x != nil {
+		return x.MaxWait
+	}
+	return nil
+}
+
 type GetAgentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Agent         *Agent                 `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
@@ -1067,8 +1142,17 @@ type Agent struct {
 	ResourceLimits *ResourceLimits `protobuf:"bytes,11,opt,name=resource_limits,json=resourceLimits,proto3" json:"resource_limits,omitempty"`
 	// Current resource usage.
 	ResourceUsage *AgentResourceUsage `protobuf:"bytes,12,opt,name=resource_usage,json=resourceUsage,proto3" json:"resource_usage,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// Day the cost figures are for (YYYY-MM-DD in the daemon's cost time zone).
+	CostDate string `protobuf:"bytes,13,opt,name=cost_date,json=costDate,proto3" json:"cost_date,omitempty"`
+	// Usage cost recorded for the agent that day, in cents.
+	ActualCostCents int64 `protobuf:"varint,14,opt,name=actual_cost_cents,json=actualCostCents,proto3" json:"actual_cost_cents,omitempty"`
+	// Estimated cost of the agent's working time that day with no usage
+	// recorded for it, in cents. Never included in actual_cost_cents.
+	EstimatedCostCents int64 `protobuf:"varint,15,opt,name=estimated_cost_cents,json=estimatedCostCents,proto3" json:"estimated_cost_cents,omitempty"`
+	// Working time the estimate covers, in seconds.
+	EstimatedWorkingSeconds int64 `protobuf:"varint,16,opt,name=estimated_working_seconds,json=estimatedWorkingSeconds,proto3" json:"estimated_working_seconds,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
 }
 
 func (x *Agent) Reset() {
@@ -1185,6 +1269,34 @@ func (x *Agent) GetResourceUsage() *AgentResourceUsage {
 	return nil
 }
 
+func (x *Agent) GetCostDate() string {
+	if x != nil {
+		return x.CostDate
+	}
+	return ""
+}
+
+func (x *Agent) GetActualCostCents() int64 {
+	if x != nil {
+		return x.ActualCostCents
+	}
+	return 0
+}
+
+func (x *Agent) GetEstimatedCostCents() int64 {
+	if x != nil {
+		return x.EstimatedCostCents
+	}
+	return 0
+}
+
+func (x *Agent) GetEstimatedWorkingSeconds() int64 {
+	if x != nil {
+		return x.EstimatedWorkingSeconds
+	}
+	return 0
+}
+
 // AgentResourceUsage tracks current resource consumption of an agent.
 type AgentResourceUsage struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -1273,8 +1385,20 @@ type CapturePaneRequest struct {
 	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	// If true, include ANSI escape sequences.
 	IncludeEscapeSequences bool `protobuf:"varint,2,opt,name=include_escape_sequences,json=includeEscapeSequences,proto3" json:"include_escape_sequences,omitempty"`
-	// Number of lines to capture (0 = visible area, -1 = full history).
-	Lines         int32 `protobuf:"varint,3,opt,name=lines,proto3" json:"lines,omitempty"`
+	// Number of lines to capture (0 = visible area, -1 = full history,
+	// N > 0 = the last N lines, reaching into scrollback as needed).
+	Lines int32 `protobuf:"varint,3,opt,name=lines,proto3" json:"lines,omitempty"`
+	// Optional regular expression. When set, only the lines after the last
+	// line matching it are returned; with no match the whole capture is.
+	SincePattern string `protobuf:"bytes,4,opt,name=since_pattern,json=sincePattern,proto3" json:"since_pattern,omitempty"`
+	// Optional long poll. When set to a content_hash from an earlier
+	// capture, the request is held until the capture's hash differs from it
+	// or max_wait elapses, then the pane is captured as it is. An agent
+	// removed meanwhile yields NOT_FOUND.
+	WaitForChangeFrom string `protobuf:"bytes,5,opt,name=wait_for_change_from,json=waitForChangeFrom,proto3" json:"wait_for_change_from,omitempty"`
+	// Longest to hold a long poll. Unset or above the server's cap waits for
+	// the cap (60s).
+	MaxWait       *durationpb.Duration `protobuf:"bytes,6,opt,name=max_wait,json=maxWait,proto3" json:"max_wait,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1330,6 +1454,27 @@ func (x *CapturePaneRequest) GetLines() int32 {
 	return 0
 }
 
+func (x *CapturePaneRequest) GetSincePattern() string {
+	if x != nil {
+		return x.SincePattern
+	}
+	return ""
+}
+
+func (x *CapturePaneRequest) GetWaitForChangeFrom() string {
+	if x != nil {
+		return x.WaitForChangeFrom
+	}
+	return ""
+}
+
+func (x *CapturePaneRequest) GetMaxWait() *durationpb.Duration {
+	if x != nil {
+		return x.MaxWait
+	}
+	return nil
+}
+
 type CapturePaneResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The pane content.
@@ -1343,9 +1488,17 @@ type CapturePaneResponse struct {
 	CursorX int32 `protobuf:"varint,5,opt,name=cursor_x,json=cursorX,proto3" json:"cursor_x,omitempty"`
 	CursorY int32 `protobuf:"varint,6,opt,name=cursor_y,json=cursorY,proto3" json:"cursor_y,omitempty"`
 	// Capture timestamp.
-	CapturedAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=captured_at,json=capturedAt,proto3" json:"captured_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	CapturedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=captured_at,json=capturedAt,proto3" json:"captured_at,omitempty"`
+	// Number of lines in content.
+	LineCount int32 `protobuf:"varint,8,opt,name=line_count,json=lineCount,proto3" json:"line_count,omitempty"`
+	// Number of lines captured before since_pattern was applied.
+	TotalLines int32 `protobuf:"varint,9,opt,name=total_lines,json=totalLines,proto3" json:"total_lines,omitempty"`
+	// True when older output was left out by lines or since_pattern.
+	Truncated bool `protobuf:"varint,10,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	// True when since_pattern matched a line.
+	PatternMatched bool `protobuf:"varint,11,opt,name=pattern_matched,json=patternMatched,proto3" json:"pattern_matched,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *CapturePaneResponse) Reset() {
@@ -1427,6 +1580,34 @@ func (x *CapturePaneResponse) GetCapturedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *CapturePaneResponse) GetLineCount() int32 {
+	if x != nil {
+		return x.LineCount
+	}
+	return 0
+}
+
+func (x *CapturePaneResponse) GetTotalLines() int32 {
+	if x != nil {
+		return x.TotalLines
+	}
+	return 0
+}
+
+func (x *CapturePaneResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *CapturePaneResponse) GetPatternMatched() bool {
+	if x != nil {
+		return x.PatternMatched
+	}
+	return false
+}
+
 type StreamPaneUpdatesRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Agent to monitor.
@@ -1437,8 +1618,12 @@ type StreamPaneUpdatesRequest struct {
 	LastKnownHash string `protobuf:"bytes,3,opt,name=last_known_hash,json=lastKnownHash,proto3" json:"last_known_hash,omitempty"`
 	// If true, include full content; otherwise just hash + diff info.
 	IncludeContent bool `protobuf:"varint,4,opt,name=include_content,json=includeContent,proto3" json:"include_content,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// Longest interval the pane is polled at while its content is
+	// unchanged. Polling starts at min_interval and backs off toward it.
+	// Unset uses the daemon's pane_poll_max_interval.
+	MaxInterval   *durationpb.Duration `protobuf:"bytes,5,opt,name=max_interval,json=maxInterval,proto3" json:"max_interval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StreamPaneUpdatesRequest) Reset() {
@@ -1499,6 +1684,13 @@ func (x *StreamPaneUpdatesRequest) GetIncludeContent() bool {
 	return false
 }
 
+func (x *StreamPaneUpdatesRequest) GetMaxInterval() *durationpb.Duration {
+	if x != nil {
+		return x.MaxInterval
+	}
+	return nil
+}
+
 type StreamPaneUpdatesResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Agent ID.
@@ -1513,6 +1705,14 @@ type StreamPaneUpdatesResponse struct {
 	Timestamp *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	// Detected agent state based on content analysis.
 	DetectedState AgentState `protobuf:"varint,6,opt,name=detected_state,json=detectedState,proto3,enum=swarmd.v1.AgentState" json:"detected_state,omitempty"`
+	// Interval the pane was being polled at when this change was seen.
+	PollInterval *durationpb.Duration `protobuf:"bytes,7,opt,name=poll_interval,json=pollInterval,proto3" json:"poll_interval,omitempty"`
+	// State detector that produced detected_state: the agent's adapter, or
+	// "default" for the fallback patterns used when the adapter has none.
+	StateDetector string `protobuf:"bytes,8,opt,name=state_detector,json=stateDetector,proto3" json:"state_detector,omitempty"`
+	// Set by StreamWorkspaceUpdates when the agent left the stream because
+	// it was killed. Only agent_id and timestamp accompany it.
+	Removed       bool `protobuf:"varint,9,opt,name=removed,proto3" json:"removed,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1589,34 +1789,62 @@ func (x *StreamPaneUpdatesResponse) GetDetectedState() AgentState {
 	return AgentState_AGENT_STATE_UNSPECIFIED
 }
 
-type StreamEventsRequest struct {
+func (x *StreamPaneUpdatesResponse) GetPollInterval() *durationpb.Duration {
+	if x != nil {
+		return x.PollInterval
+	}
+	return nil
+}
+
+func (x *StreamPaneUpdatesResponse) GetStateDetector() string {
+	if x != nil {
+		return x.StateDetector
+	}
+	return ""
+}
+
+func (x *StreamPaneUpdatesResponse) GetRemoved() bool {
+	if x != nil {
+		return x.Removed
+	}
+	return false
+}
+
+type StreamWorkspaceUpdatesRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Resume from this cursor (event ID). Empty = start from now.
-	Cursor string `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
-	// Filter by event types.
-	Types []EventType `protobuf:"varint,2,rep,packed,name=types,proto3,enum=swarmd.v1.EventType" json:"types,omitempty"`
-	// Filter by agent IDs.
-	AgentIds []string `protobuf:"bytes,3,rep,name=agent_ids,json=agentIds,proto3" json:"agent_ids,omitempty"`
-	// Filter by workspace IDs.
-	WorkspaceIds  []string `protobuf:"bytes,4,rep,name=workspace_ids,json=workspaceIds,proto3" json:"workspace_ids,omitempty"`
+	// Workspace whose agents to monitor. Unset matches every workspace.
+	WorkspaceId string `protobuf:"bytes,1,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	// Agents to monitor, within workspace_id when both are set. Unset
+	// matches every agent.
+	AgentIds []string `protobuf:"bytes,2,rep,name=agent_ids,json=agentIds,proto3" json:"agent_ids,omitempty"`
+	// Minimum interval between updates for each agent.
+	MinInterval *durationpb.Duration `protobuf:"bytes,3,opt,name=min_interval,json=minInterval,proto3" json:"min_interval,omitempty"`
+	// Last known content hash by agent ID. An agent is sent only once its
+	// hash differs.
+	LastKnownHashes map[string]string `protobuf:"bytes,4,rep,name=last_known_hashes,json=lastKnownHashes,proto3" json:"last_known_hashes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// If true, include full content; otherwise just hash + diff info.
+	IncludeContent bool `protobuf:"varint,5,opt,name=include_content,json=includeContent,proto3" json:"include_content,omitempty"`
+	// Longest interval a pane is polled at while its content is unchanged,
+	// as in StreamPaneUpdatesRequest.
+	MaxInterval   *durationpb.Duration `protobuf:"bytes,6,opt,name=max_interval,json=maxInterval,proto3" json:"max_interval,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StreamEventsRequest) Reset() {
-	*x = StreamEventsRequest{}
+func (x *StreamWorkspaceUpdatesRequest) Reset() {
+	*x = StreamWorkspaceUpdatesRequest{}
 	mi := &file_swarmd_v1_swarmd_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StreamEventsRequest) String() string {
+func (x *StreamWorkspaceUpdatesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamEventsRequest) ProtoMessage() {}
+func (*StreamWorkspaceUpdatesRequest) ProtoMessage() {}
 
-func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+func (x *StreamWorkspaceUpdatesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_swarmd_v1_swarmd_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1628,61 +1856,79 @@ func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
-func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use StreamWorkspaceUpdatesRequest.ProtoReflect.Descriptor instead.
+func (*StreamWorkspaceUpdatesRequest) Descriptor() ([]byte, []int) {
 	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *StreamEventsRequest) GetCursor() string {
+func (x *StreamWorkspaceUpdatesRequest) GetWorkspaceId() string {
 	if x != nil {
-		return x.Cursor
+		return x.WorkspaceId
 	}
 	return ""
 }
 
-func (x *StreamEventsRequest) GetTypes() []EventType {
+func (x *StreamWorkspaceUpdatesRequest) GetAgentIds() []string {
 	if x != nil {
-		return x.Types
+		return x.AgentIds
 	}
 	return nil
 }
 
-func (x *StreamEventsRequest) GetAgentIds() []string {
+func (x *StreamWorkspaceUpdatesRequest) GetMinInterval() *durationpb.Duration {
 	if x != nil {
-		return x.AgentIds
+		return x.MinInterval
 	}
 	return nil
 }
 
-func (x *StreamEventsRequest) GetWorkspaceIds() []string {
+func (x *StreamWorkspaceUpdatesRequest) GetLastKnownHashes() map[string]string {
 	if x != nil {
-		return x.WorkspaceIds
+		return x.LastKnownHashes
 	}
 	return nil
 }
 
-type StreamEventsResponse struct {
+func (x *StreamWorkspaceUpdatesRequest) GetIncludeContent() bool {
+	if x != nil {
+		return x.IncludeContent
+	}
+	return false
+}
+
+func (x *StreamWorkspaceUpdatesRequest) GetMaxInterval() *durationpb.Duration {
+	if x != nil {
+		return x.MaxInterval
+	}
+	return nil
+}
+
+type ResizePaneRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// The event.
-	Event         *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	// Agent whose pane to resize.
+	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// New size in cells. Either may be left unset to keep that dimension,
+	// but not both.
+	Width         int32 `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32 `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StreamEventsResponse) Reset() {
-	*x = StreamEventsResponse{}
+func (x *ResizePaneRequest) Reset() {
+	*x = ResizePaneRequest{}
 	mi := &file_swarmd_v1_swarmd_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StreamEventsResponse) String() string {
+func (x *ResizePaneRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamEventsResponse) ProtoMessage() {}
+func (*ResizePaneRequest) ProtoMessage() {}
 
-func (x *StreamEventsResponse) ProtoReflect() protoreflect.Message {
+func (x *ResizePaneRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_swarmd_v1_swarmd_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1694,60 +1940,56 @@ func (x *StreamEventsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamEventsResponse.ProtoReflect.Descriptor instead.
-func (*StreamEventsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ResizePaneRequest.ProtoReflect.Descriptor instead.
+func (*ResizePaneRequest) Descriptor() ([]byte, []int) {
 	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *StreamEventsResponse) GetEvent() *Event {
+func (x *ResizePaneRequest) GetAgentId() string {
 	if x != nil {
-		return x.Event
+		return x.AgentId
 	}
-	return nil
+	return ""
 }
 
-type Event struct {
+func (x *ResizePaneRequest) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ResizePaneRequest) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type ResizePaneResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Unique event ID (also serves as cursor).
-	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	// Event type.
-	Type EventType `protobuf:"varint,2,opt,name=type,proto3,enum=swarmd.v1.EventType" json:"type,omitempty"`
-	// When the event occurred.
-	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	// Associated agent (if applicable).
-	AgentId string `protobuf:"bytes,4,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	// Associated workspace (if applicable).
-	WorkspaceId string `protobuf:"bytes,5,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
-	// Event-specific payload.
-	//
-	// Types that are valid to be assigned to Payload:
-	//
-	//	*Event_AgentStateChanged
-	//	*Event_AgentOutput
-	//	*Event_ApprovalRequested
-	//	*Event_ApprovalResolved
-	//	*Event_Error
-	//	*Event_PaneContentChanged
-	//	*Event_ResourceViolation
-	Payload       isEvent_Payload `protobuf_oneof:"payload"`
+	// Size of the pane after the resize. tmux may not honor the request
+	// exactly, e.g. when the window is smaller than asked for.
+	Width         int32 `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32 `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Event) Reset() {
-	*x = Event{}
+func (x *ResizePaneResponse) Reset() {
+	*x = ResizePaneResponse{}
 	mi := &file_swarmd_v1_swarmd_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Event) String() string {
+func (x *ResizePaneResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Event) ProtoMessage() {}
+func (*ResizePaneResponse) ProtoMessage() {}
 
-func (x *Event) ProtoReflect() protoreflect.Message {
+func (x *ResizePaneResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_swarmd_v1_swarmd_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1759,186 +2001,1096 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Event.ProtoReflect.Descriptor instead.
-func (*Event) Descriptor() ([]byte, []int) {
+// Deprecated: Use ResizePaneResponse.ProtoReflect.Descriptor instead.
+func (*ResizePaneResponse) Descriptor() ([]byte, []int) {
 	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *Event) GetId() string {
+func (x *ResizePaneResponse) GetWidth() int32 {
 	if x != nil {
-		return x.Id
+		return x.Width
 	}
-	return ""
+	return 0
 }
 
-func (x *Event) GetType() EventType {
+func (x *ResizePaneResponse) GetHeight() int32 {
 	if x != nil {
-		return x.Type
+		return x.Height
 	}
-	return EventType_EVENT_TYPE_UNSPECIFIED
+	return 0
 }
 
-func (x *Event) GetTimestamp() *timestamppb.Timestamp {
-	if x != nil {
-		return x.Timestamp
-	}
-	return nil
+type SetPaneLayoutRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// tmux session whose current window to arrange, as in
+	// SpawnAgentRequest.session_name.
+	Session string `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	// Layout to apply: a preset (even-horizontal, even-vertical,
+	// main-horizontal, main-vertical, tiled) or a layout string from
+	// tmux's window_layout.
+	Layout        string `protobuf:"bytes,2,opt,name=layout,proto3" json:"layout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Event) GetAgentId() string {
-	if x != nil {
-		return x.AgentId
-	}
-	return ""
+func (x *SetPaneLayoutRequest) Reset() {
+	*x = SetPaneLayoutRequest{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Event) GetWorkspaceId() string {
-	if x != nil {
-		return x.WorkspaceId
-	}
-	return ""
+func (x *SetPaneLayoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *Event) GetPayload() isEvent_Payload {
+func (*SetPaneLayoutRequest) ProtoMessage() {}
+
+func (x *SetPaneLayoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[20]
 	if x != nil {
-		return x.Payload
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *Event) GetAgentStateChanged() *AgentStateChangedEvent {
+// Deprecated: Use SetPaneLayoutRequest.ProtoReflect.Descriptor instead.
+func (*SetPaneLayoutRequest) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SetPaneLayoutRequest) GetSession() string {
 	if x != nil {
-		if x, ok := x.Payload.(*Event_AgentStateChanged); ok {
-			return x.AgentStateChanged
+		return x.Session
+	}
+	return ""
+}
+
+func (x *SetPaneLayoutRequest) GetLayout() string {
+	if x != nil {
+		return x.Layout
+	}
+	return ""
+}
+
+type SetPaneLayoutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPaneLayoutResponse) Reset() {
+	*x = SetPaneLayoutResponse{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPaneLayoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPaneLayoutResponse) ProtoMessage() {}
+
+func (x *SetPaneLayoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPaneLayoutResponse.ProtoReflect.Descriptor instead.
+func (*SetPaneLayoutResponse) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{21}
+}
+
+type StreamEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Resume from this cursor (event ID). Empty = start from now.
+	Cursor string `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Filter by event types.
+	Types []EventType `protobuf:"varint,2,rep,packed,name=types,proto3,enum=swarmd.v1.EventType" json:"types,omitempty"`
+	// Filter by agent IDs.
+	AgentIds []string `protobuf:"bytes,3,rep,name=agent_ids,json=agentIds,proto3" json:"agent_ids,omitempty"`
+	// Filter by workspace IDs.
+	WorkspaceIds  []string `protobuf:"bytes,4,rep,name=workspace_ids,json=workspaceIds,proto3" json:"workspace_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *StreamEventsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *StreamEventsRequest) GetTypes() []EventType {
+	if x != nil {
+		return x.Types
 	}
 	return nil
 }
 
-func (x *Event) GetAgentOutput() *AgentOutputEvent {
+func (x *StreamEventsRequest) GetAgentIds() []string {
 	if x != nil {
-		if x, ok := x.Payload.(*Event_AgentOutput); ok {
-			return x.AgentOutput
+		return x.AgentIds
+	}
+	return nil
+}
+
+func (x *StreamEventsRequest) GetWorkspaceIds() []string {
+	if x != nil {
+		return x.WorkspaceIds
+	}
+	return nil
+}
+
+type StreamEventsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The event.
+	Event         *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsResponse) Reset() {
+	*x = StreamEventsResponse{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsResponse) ProtoMessage() {}
+
+func (x *StreamEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsResponse.ProtoReflect.Descriptor instead.
+func (*StreamEventsResponse) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StreamEventsResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
 	}
 	return nil
 }
 
-func (x *Event) GetApprovalRequested() *ApprovalRequestedEvent {
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unique event ID (also serves as cursor).
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Event type.
+	Type EventType `protobuf:"varint,2,opt,name=type,proto3,enum=swarmd.v1.EventType" json:"type,omitempty"`
+	// When the event occurred.
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Associated agent (if applicable).
+	AgentId string `protobuf:"bytes,4,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Associated workspace (if applicable).
+	WorkspaceId string `protobuf:"bytes,5,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	// Event-specific payload.
+	//
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Event_AgentStateChanged
+	//	*Event_AgentOutput
+	//	*Event_ApprovalRequested
+	//	*Event_ApprovalResolved
+	//	*Event_Error
+	//	*Event_PaneContentChanged
+	//	*Event_ResourceViolation
+	Payload       isEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[24]
 	if x != nil {
-		if x, ok := x.Payload.(*Event_ApprovalRequested); ok {
-			return x.ApprovalRequested
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *Event) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Event) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
 	}
 	return nil
 }
 
-func (x *Event) GetApprovalResolved() *ApprovalResolvedEvent {
+func (x *Event) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *Event) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+func (x *Event) GetPayload() isEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetAgentStateChanged() *AgentStateChangedEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_AgentStateChanged); ok {
+			return x.AgentStateChanged
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAgentOutput() *AgentOutputEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_AgentOutput); ok {
+			return x.AgentOutput
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetApprovalRequested() *ApprovalRequestedEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ApprovalRequested); ok {
+			return x.ApprovalRequested
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetApprovalResolved() *ApprovalResolvedEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ApprovalResolved); ok {
+			return x.ApprovalResolved
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetError() *ErrorEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetPaneContentChanged() *PaneContentChangedEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_PaneContentChanged); ok {
+			return x.PaneContentChanged
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetResourceViolation() *ResourceViolationEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ResourceViolation); ok {
+			return x.ResourceViolation
+		}
+	}
+	return nil
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_AgentStateChanged struct {
+	AgentStateChanged *AgentStateChangedEvent `protobuf:"bytes,10,opt,name=agent_state_changed,json=agentStateChanged,proto3,oneof"`
+}
+
+type Event_AgentOutput struct {
+	AgentOutput *AgentOutputEvent `protobuf:"bytes,11,opt,name=agent_output,json=agentOutput,proto3,oneof"`
+}
+
+type Event_ApprovalRequested struct {
+	ApprovalRequested *ApprovalRequestedEvent `protobuf:"bytes,12,opt,name=approval_requested,json=approvalRequested,proto3,oneof"`
+}
+
+type Event_ApprovalResolved struct {
+	ApprovalResolved *ApprovalResolvedEvent `protobuf:"bytes,13,opt,name=approval_resolved,json=approvalResolved,proto3,oneof"`
+}
+
+type Event_Error struct {
+	Error *ErrorEvent `protobuf:"bytes,14,opt,name=error,proto3,oneof"`
+}
+
+type Event_PaneContentChanged struct {
+	PaneContentChanged *PaneContentChangedEvent `protobuf:"bytes,15,opt,name=pane_content_changed,json=paneContentChanged,proto3,oneof"`
+}
+
+type Event_ResourceViolation struct {
+	ResourceViolation *ResourceViolationEvent `protobuf:"bytes,16,opt,name=resource_violation,json=resourceViolation,proto3,oneof"`
+}
+
+func (*Event_AgentStateChanged) isEvent_Payload() {}
+
+func (*Event_AgentOutput) isEvent_Payload() {}
+
+func (*Event_ApprovalRequested) isEvent_Payload() {}
+
+func (*Event_ApprovalResolved) isEvent_Payload() {}
+
+func (*Event_Error) isEvent_Payload() {}
+
+func (*Event_PaneContentChanged) isEvent_Payload() {}
+
+func (*Event_ResourceViolation) isEvent_Payload() {}
+
+type AgentStateChangedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PreviousState AgentState             `protobuf:"varint,1,opt,name=previous_state,json=previousState,proto3,enum=swarmd.v1.AgentState" json:"previous_state,omitempty"`
+	NewState      AgentState             `protobuf:"varint,2,opt,name=new_state,json=newState,proto3,enum=swarmd.v1.AgentState" json:"new_state,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentStateChangedEvent) Reset() {
+	*x = AgentStateChangedEvent{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentStateChangedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentStateChangedEvent) ProtoMessage() {}
+
+func (x *AgentStateChangedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentStateChangedEvent.ProtoReflect.Descriptor instead.
+func (*AgentStateChangedEvent) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *AgentStateChangedEvent) GetPreviousState() AgentState {
+	if x != nil {
+		return x.PreviousState
+	}
+	return AgentState_AGENT_STATE_UNSPECIFIED
+}
+
+func (x *AgentStateChangedEvent) GetNewState() AgentState {
+	if x != nil {
+		return x.NewState
+	}
+	return AgentState_AGENT_STATE_UNSPECIFIED
+}
+
+func (x *AgentStateChangedEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type AgentOutputEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Output text (may be partial line).
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// Whether this is stderr.
+	IsStderr      bool `protobuf:"varint,2,opt,name=is_stderr,json=isStderr,proto3" json:"is_stderr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentOutputEvent) Reset() {
+	*x = AgentOutputEvent{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentOutputEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentOutputEvent) ProtoMessage() {}
+
+func (x *AgentOutputEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentOutputEvent.ProtoReflect.Descriptor instead.
+func (*AgentOutputEvent) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *AgentOutputEvent) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *AgentOutputEvent) GetIsStderr() bool {
+	if x != nil {
+		return x.IsStderr
+	}
+	return false
+}
+
+type ApprovalRequestedEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Approval request ID.
+	ApprovalId string `protobuf:"bytes,1,opt,name=approval_id,json=approvalId,proto3" json:"approval_id,omitempty"`
+	// What action needs approval.
+	Action string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	// Details about the action.
+	Details string `protobuf:"bytes,3,opt,name=details,proto3" json:"details,omitempty"`
+	// Risk level assessment.
+	RiskLevel     string `protobuf:"bytes,4,opt,name=risk_level,json=riskLevel,proto3" json:"risk_level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApprovalRequestedEvent) Reset() {
+	*x = ApprovalRequestedEvent{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApprovalRequestedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApprovalRequestedEvent) ProtoMessage() {}
+
+func (x *ApprovalRequestedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApprovalRequestedEvent.ProtoReflect.Descriptor instead.
+func (*ApprovalRequestedEvent) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ApprovalRequestedEvent) GetApprovalId() string {
+	if x != nil {
+		return x.ApprovalId
+	}
+	return ""
+}
+
+func (x *ApprovalRequestedEvent) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ApprovalRequestedEvent) GetDetails() string {
+	if x != nil {
+		return x.Details
+	}
+	return ""
+}
+
+func (x *ApprovalRequestedEvent) GetRiskLevel() string {
+	if x != nil {
+		return x.RiskLevel
+	}
+	return ""
+}
+
+type ApprovalResolvedEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Approval request ID.
+	ApprovalId string `protobuf:"bytes,1,opt,name=approval_id,json=approvalId,proto3" json:"approval_id,omitempty"`
+	// Whether it was approved.
+	Approved bool `protobuf:"varint,2,opt,name=approved,proto3" json:"approved,omitempty"`
+	// Who/what resolved it.
+	ResolvedBy    string `protobuf:"bytes,3,opt,name=resolved_by,json=resolvedBy,proto3" json:"resolved_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApprovalResolvedEvent) Reset() {
+	*x = ApprovalResolvedEvent{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApprovalResolvedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApprovalResolvedEvent) ProtoMessage() {}
+
+func (x *ApprovalResolvedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApprovalResolvedEvent.ProtoReflect.Descriptor instead.
+func (*ApprovalResolvedEvent) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ApprovalResolvedEvent) GetApprovalId() string {
+	if x != nil {
+		return x.ApprovalId
+	}
+	return ""
+}
+
+func (x *ApprovalResolvedEvent) GetApproved() bool {
+	if x != nil {
+		return x.Approved
+	}
+	return false
+}
+
+func (x *ApprovalResolvedEvent) GetResolvedBy() string {
+	if x != nil {
+		return x.ResolvedBy
+	}
+	return ""
+}
+
+type ErrorEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Error code.
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Error message.
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Whether this is recoverable.
+	Recoverable   bool `protobuf:"varint,3,opt,name=recoverable,proto3" json:"recoverable,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorEvent) Reset() {
+	*x = ErrorEvent{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorEvent) ProtoMessage() {}
+
+func (x *ErrorEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorEvent.ProtoReflect.Descriptor instead.
+func (*ErrorEvent) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ErrorEvent) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ErrorEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ErrorEvent) GetRecoverable() bool {
+	if x != nil {
+		return x.Recoverable
+	}
+	return false
+}
+
+// ResourceViolationEvent is emitted when an agent exceeds resource limits.
+type ResourceViolationEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Type of resource violated.
+	ResourceType ResourceType `protobuf:"varint,1,opt,name=resource_type,json=resourceType,proto3,enum=swarmd.v1.ResourceType" json:"resource_type,omitempty"`
+	// Current value.
+	CurrentValue float64 `protobuf:"fixed64,2,opt,name=current_value,json=currentValue,proto3" json:"current_value,omitempty"`
+	// Limit value.
+	LimitValue float64 `protobuf:"fixed64,3,opt,name=limit_value,json=limitValue,proto3" json:"limit_value,omitempty"`
+	// Number of consecutive violations.
+	ViolationCount int32 `protobuf:"varint,4,opt,name=violation_count,json=violationCount,proto3" json:"violation_count,omitempty"`
+	// Action taken in response.
+	ActionTaken   ResourceLimitAction `protobuf:"varint,5,opt,name=action_taken,json=actionTaken,proto3,enum=swarmd.v1.ResourceLimitAction" json:"action_taken,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceViolationEvent) Reset() {
+	*x = ResourceViolationEvent{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceViolationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceViolationEvent) ProtoMessage() {}
+
+func (x *ResourceViolationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceViolationEvent.ProtoReflect.Descriptor instead.
+func (*ResourceViolationEvent) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ResourceViolationEvent) GetResourceType() ResourceType {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ResourceType_RESOURCE_TYPE_UNSPECIFIED
+}
+
+func (x *ResourceViolationEvent) GetCurrentValue() float64 {
+	if x != nil {
+		return x.CurrentValue
+	}
+	return 0
+}
+
+func (x *ResourceViolationEvent) GetLimitValue() float64 {
+	if x != nil {
+		return x.LimitValue
+	}
+	return 0
+}
+
+func (x *ResourceViolationEvent) GetViolationCount() int32 {
+	if x != nil {
+		return x.ViolationCount
+	}
+	return 0
+}
+
+func (x *ResourceViolationEvent) GetActionTaken() ResourceLimitAction {
+	if x != nil {
+		return x.ActionTaken
+	}
+	return ResourceLimitAction_RESOURCE_LIMIT_ACTION_UNSPECIFIED
+}
+
+type PaneContentChangedEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// New content hash.
+	ContentHash string `protobuf:"bytes,1,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
+	// Number of lines changed.
+	LinesChanged  int32 `protobuf:"varint,2,opt,name=lines_changed,json=linesChanged,proto3" json:"lines_changed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaneContentChangedEvent) Reset() {
+	*x = PaneContentChangedEvent{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaneContentChangedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaneContentChangedEvent) ProtoMessage() {}
+
+func (x *PaneContentChangedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaneContentChangedEvent.ProtoReflect.Descriptor instead.
+func (*PaneContentChangedEvent) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *PaneContentChangedEvent) GetContentHash() string {
+	if x != nil {
+		return x.ContentHash
+	}
+	return ""
+}
+
+func (x *PaneContentChangedEvent) GetLinesChanged() int32 {
+	if x != nil {
+		return x.LinesChanged
+	}
+	return 0
+}
+
+type GetTranscriptRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Agent ID.
+	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Start time (optional, defaults to session start).
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// End time (optional, defaults to now).
+	EndTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// Maximum number of entries to return.
+	Limit int32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Resume from the next_cursor of an earlier response (optional).
+	Cursor        string `protobuf:"bytes,5,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTranscriptRequest) Reset() {
+	*x = GetTranscriptRequest{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTranscriptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTranscriptRequest) ProtoMessage() {}
+
+func (x *GetTranscriptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTranscriptRequest.ProtoReflect.Descriptor instead.
+func (*GetTranscriptRequest) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetTranscriptRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetTranscriptRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *GetTranscriptRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *GetTranscriptRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetTranscriptRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type GetTranscriptResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Agent ID.
+	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Transcript entries.
+	Entries []*TranscriptEntry `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+	// Whether there are more entries.
+	HasMore bool `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	// Cursor for pagination.
+	NextCursor string `protobuf:"bytes,4,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	// Whether entries in the requested range were evicted by the daemon's
+	// per-agent transcript limit and are missing from the response.
+	Truncated bool `protobuf:"varint,5,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	// When truncated, the cursor of the oldest entry still available.
+	EarliestCursor string `protobuf:"bytes,6,opt,name=earliest_cursor,json=earliestCursor,proto3" json:"earliest_cursor,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetTranscriptResponse) Reset() {
+	*x = GetTranscriptResponse{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTranscriptResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTranscriptResponse) ProtoMessage() {}
+
+func (x *GetTranscriptResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTranscriptResponse.ProtoReflect.Descriptor instead.
+func (*GetTranscriptResponse) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetTranscriptResponse) GetAgentId() string {
 	if x != nil {
-		if x, ok := x.Payload.(*Event_ApprovalResolved); ok {
-			return x.ApprovalResolved
-		}
+		return x.AgentId
 	}
-	return nil
+	return ""
 }
 
-func (x *Event) GetError() *ErrorEvent {
+func (x *GetTranscriptResponse) GetEntries() []*TranscriptEntry {
 	if x != nil {
-		if x, ok := x.Payload.(*Event_Error); ok {
-			return x.Error
-		}
+		return x.Entries
 	}
 	return nil
 }
 
-func (x *Event) GetPaneContentChanged() *PaneContentChangedEvent {
+func (x *GetTranscriptResponse) GetHasMore() bool {
 	if x != nil {
-		if x, ok := x.Payload.(*Event_PaneContentChanged); ok {
-			return x.PaneContentChanged
-		}
+		return x.HasMore
 	}
-	return nil
+	return false
 }
 
-func (x *Event) GetResourceViolation() *ResourceViolationEvent {
+func (x *GetTranscriptResponse) GetNextCursor() string {
 	if x != nil {
-		if x, ok := x.Payload.(*Event_ResourceViolation); ok {
-			return x.ResourceViolation
-		}
+		return x.NextCursor
 	}
-	return nil
-}
-
-type isEvent_Payload interface {
-	isEvent_Payload()
-}
-
-type Event_AgentStateChanged struct {
-	AgentStateChanged *AgentStateChangedEvent `protobuf:"bytes,10,opt,name=agent_state_changed,json=agentStateChanged,proto3,oneof"`
-}
-
-type Event_AgentOutput struct {
-	AgentOutput *AgentOutputEvent `protobuf:"bytes,11,opt,name=agent_output,json=agentOutput,proto3,oneof"`
-}
-
-type Event_ApprovalRequested struct {
-	ApprovalRequested *ApprovalRequestedEvent `protobuf:"bytes,12,opt,name=approval_requested,json=approvalRequested,proto3,oneof"`
-}
-
-type Event_ApprovalResolved struct {
-	ApprovalResolved *ApprovalResolvedEvent `protobuf:"bytes,13,opt,name=approval_resolved,json=approvalResolved,proto3,oneof"`
-}
-
-type Event_Error struct {
-	Error *ErrorEvent `protobuf:"bytes,14,opt,name=error,proto3,oneof"`
+	return ""
 }
 
-type Event_PaneContentChanged struct {
-	PaneContentChanged *PaneContentChangedEvent `protobuf:"bytes,15,opt,name=pane_content_changed,json=paneContentChanged,proto3,oneof"`
+func (x *GetTranscriptResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
 }
 
-type Event_ResourceViolation struct {
-	ResourceViolation *ResourceViolationEvent `protobuf:"bytes,16,opt,name=resource_violation,json=resourceViolation,proto3,oneof"`
+func (x *GetTranscriptResponse) GetEarliestCursor() string {
+	if x != nil {
+		return x.EarliestCursor
+	}
+	return ""
 }
 
-func (*Event_AgentStateChanged) isEvent_Payload() {}
-
-func (*Event_AgentOutput) isEvent_Payload() {}
-
-func (*Event_ApprovalRequested) isEvent_Payload() {}
-
-func (*Event_ApprovalResolved) isEvent_Payload() {}
-
-func (*Event_Error) isEvent_Payload() {}
-
-func (*Event_PaneContentChanged) isEvent_Payload() {}
-
-func (*Event_ResourceViolation) isEvent_Payload() {}
-
-type AgentStateChangedEvent struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PreviousState AgentState             `protobuf:"varint,1,opt,name=previous_state,json=previousState,proto3,enum=swarmd.v1.AgentState" json:"previous_state,omitempty"`
-	NewState      AgentState             `protobuf:"varint,2,opt,name=new_state,json=newState,proto3,enum=swarmd.v1.AgentState" json:"new_state,omitempty"`
-	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+type TranscriptEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Entry timestamp.
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Entry type.
+	Type TranscriptEntryType `protobuf:"varint,2,opt,name=type,proto3,enum=swarmd.v1.TranscriptEntryType" json:"type,omitempty"`
+	// Content.
+	Content string `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	// Associated metadata.
+	Metadata map[string]string `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Entry ID, the cursor position of the entry. A SUMMARY entry takes the
+	// first ID of the range it replaced.
+	Id            int64 `protobuf:"varint,5,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentStateChangedEvent) Reset() {
-	*x = AgentStateChangedEvent{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[20]
+func (x *TranscriptEntry) Reset() {
+	*x = TranscriptEntry{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentStateChangedEvent) String() string {
+func (x *TranscriptEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentStateChangedEvent) ProtoMessage() {}
+func (*TranscriptEntry) ProtoMessage() {}
 
-func (x *AgentStateChangedEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[20]
+func (x *TranscriptEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1949,57 +3101,71 @@ func (x *AgentStateChangedEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentStateChangedEvent.ProtoReflect.Descriptor instead.
-func (*AgentStateChangedEvent) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use TranscriptEntry.ProtoReflect.Descriptor instead.
+func (*TranscriptEntry) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *AgentStateChangedEvent) GetPreviousState() AgentState {
+func (x *TranscriptEntry) GetTimestamp() *timestamppb.Timestamp {
 	if x != nil {
-		return x.PreviousState
+		return x.Timestamp
 	}
-	return AgentState_AGENT_STATE_UNSPECIFIED
+	return nil
 }
 
-func (x *AgentStateChangedEvent) GetNewState() AgentState {
+func (x *TranscriptEntry) GetType() TranscriptEntryType {
 	if x != nil {
-		return x.NewState
+		return x.Type
 	}
-	return AgentState_AGENT_STATE_UNSPECIFIED
+	return TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_UNSPECIFIED
 }
 
-func (x *AgentStateChangedEvent) GetReason() string {
+func (x *TranscriptEntry) GetContent() string {
 	if x != nil {
-		return x.Reason
+		return x.Content
 	}
 	return ""
 }
 
-type AgentOutputEvent struct {
+func (x *TranscriptEntry) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *TranscriptEntry) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type StreamTranscriptRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Output text (may be partial line).
-	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
-	// Whether this is stderr.
-	IsStderr      bool `protobuf:"varint,2,opt,name=is_stderr,json=isStderr,proto3" json:"is_stderr,omitempty"`
+	// Agent ID.
+	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Resume from cursor (optional).
+	Cursor        string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentOutputEvent) Reset() {
-	*x = AgentOutputEvent{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[21]
+func (x *StreamTranscriptRequest) Reset() {
+	*x = StreamTranscriptRequest{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentOutputEvent) String() string {
+func (x *StreamTranscriptRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentOutputEvent) ProtoMessage() {}
+func (*StreamTranscriptRequest) ProtoMessage() {}
 
-func (x *AgentOutputEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[21]
+func (x *StreamTranscriptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2010,54 +3176,54 @@ func (x *AgentOutputEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentOutputEvent.ProtoReflect.Descriptor instead.
-func (*AgentOutputEvent) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use StreamTranscriptRequest.ProtoReflect.Descriptor instead.
+func (*StreamTranscriptRequest) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *AgentOutputEvent) GetText() string {
+func (x *StreamTranscriptRequest) GetAgentId() string {
 	if x != nil {
-		return x.Text
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *AgentOutputEvent) GetIsStderr() bool {
+func (x *StreamTranscriptRequest) GetCursor() string {
 	if x != nil {
-		return x.IsStderr
+		return x.Cursor
 	}
-	return false
+	return ""
 }
 
-type ApprovalRequestedEvent struct {
+type StreamTranscriptResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Approval request ID.
-	ApprovalId string `protobuf:"bytes,1,opt,name=approval_id,json=approvalId,proto3" json:"approval_id,omitempty"`
-	// What action needs approval.
-	Action string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
-	// Details about the action.
-	Details string `protobuf:"bytes,3,opt,name=details,proto3" json:"details,omitempty"`
-	// Risk level assessment.
-	RiskLevel     string `protobuf:"bytes,4,opt,name=risk_level,json=riskLevel,proto3" json:"risk_level,omitempty"`
+	// Entries in this chunk.
+	Entries []*TranscriptEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	// Cursor for resumption.
+	Cursor string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Whether entries between the requested cursor and this chunk were
+	// evicted by the daemon's per-agent transcript limit; the chunk starts at
+	// the oldest entry still available.
+	Truncated     bool `protobuf:"varint,3,opt,name=truncated,proto3" json:"truncated,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ApprovalRequestedEvent) Reset() {
-	*x = ApprovalRequestedEvent{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[22]
+func (x *StreamTranscriptResponse) Reset() {
+	*x = StreamTranscriptResponse{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ApprovalRequestedEvent) String() string {
+func (x *StreamTranscriptResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ApprovalRequestedEvent) ProtoMessage() {}
+func (*StreamTranscriptResponse) ProtoMessage() {}
 
-func (x *ApprovalRequestedEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[22]
+func (x *StreamTranscriptResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2068,66 +3234,59 @@ func (x *ApprovalRequestedEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ApprovalRequestedEvent.ProtoReflect.Descriptor instead.
-func (*ApprovalRequestedEvent) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{22}
-}
-
-func (x *ApprovalRequestedEvent) GetApprovalId() string {
-	if x != nil {
-		return x.ApprovalId
-	}
-	return ""
+// Deprecated: Use StreamTranscriptResponse.ProtoReflect.Descriptor instead.
+func (*StreamTranscriptResponse) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *ApprovalRequestedEvent) GetAction() string {
+func (x *StreamTranscriptResponse) GetEntries() []*TranscriptEntry {
 	if x != nil {
-		return x.Action
+		return x.Entries
 	}
-	return ""
+	return nil
 }
 
-func (x *ApprovalRequestedEvent) GetDetails() string {
+func (x *StreamTranscriptResponse) GetCursor() string {
 	if x != nil {
-		return x.Details
+		return x.Cursor
 	}
 	return ""
 }
 
-func (x *ApprovalRequestedEvent) GetRiskLevel() string {
+func (x *StreamTranscriptResponse) GetTruncated() bool {
 	if x != nil {
-		return x.RiskLevel
+		return x.Truncated
 	}
-	return ""
+	return false
 }
 
-type ApprovalResolvedEvent struct {
+type CompactTranscriptsRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Approval request ID.
-	ApprovalId string `protobuf:"bytes,1,opt,name=approval_id,json=approvalId,proto3" json:"approval_id,omitempty"`
-	// Whether it was approved.
-	Approved bool `protobuf:"varint,2,opt,name=approved,proto3" json:"approved,omitempty"`
-	// Who/what resolved it.
-	ResolvedBy    string `protobuf:"bytes,3,opt,name=resolved_by,json=resolvedBy,proto3" json:"resolved_by,omitempty"`
+	// Compact OUTPUT entries older than this.
+	OlderThan *durationpb.Duration `protobuf:"bytes,1,opt,name=older_than,json=olderThan,proto3" json:"older_than,omitempty"`
+	// Agent whose transcript to compact (optional, default all agents).
+	AgentId string `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// Report what would be compacted without changing any transcript.
+	DryRun        bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ApprovalResolvedEvent) Reset() {
-	*x = ApprovalResolvedEvent{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[23]
+func (x *CompactTranscriptsRequest) Reset() {
+	*x = CompactTranscriptsRequest{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ApprovalResolvedEvent) String() string {
+func (x *CompactTranscriptsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ApprovalResolvedEvent) ProtoMessage() {}
+func (*CompactTranscriptsRequest) ProtoMessage() {}
 
-func (x *ApprovalResolvedEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[23]
+func (x *CompactTranscriptsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2138,59 +3297,61 @@ func (x *ApprovalResolvedEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ApprovalResolvedEvent.ProtoReflect.Descriptor instead.
-func (*ApprovalResolvedEvent) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use CompactTranscriptsRequest.ProtoReflect.Descriptor instead.
+func (*CompactTranscriptsRequest) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *ApprovalResolvedEvent) GetApprovalId() string {
+func (x *CompactTranscriptsRequest) GetOlderThan() *durationpb.Duration {
 	if x != nil {
-		return x.ApprovalId
+		return x.OlderThan
 	}
-	return ""
+	return nil
 }
 
-func (x *ApprovalResolvedEvent) GetApproved() bool {
+func (x *CompactTranscriptsRequest) GetAgentId() string {
 	if x != nil {
-		return x.Approved
+		return x.AgentId
 	}
-	return false
+	return ""
 }
 
-func (x *ApprovalResolvedEvent) GetResolvedBy() string {
+func (x *CompactTranscriptsRequest) GetDryRun() bool {
 	if x != nil {
-		return x.ResolvedBy
+		return x.DryRun
 	}
-	return ""
+	return false
 }
 
-type ErrorEvent struct {
+type CompactTranscriptsResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Error code.
-	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
-	// Error message.
-	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	// Whether this is recoverable.
-	Recoverable   bool `protobuf:"varint,3,opt,name=recoverable,proto3" json:"recoverable,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// Agents whose transcripts were compacted.
+	Agents int32 `protobuf:"varint,1,opt,name=agents,proto3" json:"agents,omitempty"`
+	// OUTPUT entries replaced.
+	EntriesCompacted int32 `protobuf:"varint,2,opt,name=entries_compacted,json=entriesCompacted,proto3" json:"entries_compacted,omitempty"`
+	// SUMMARY entries written in their place.
+	Summaries int32 `protobuf:"varint,3,opt,name=summaries,proto3" json:"summaries,omitempty"`
+	// Content bytes of the replaced entries.
+	BytesCompacted int64 `protobuf:"varint,4,opt,name=bytes_compacted,json=bytesCompacted,proto3" json:"bytes_compacted,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *ErrorEvent) Reset() {
-	*x = ErrorEvent{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[24]
+func (x *CompactTranscriptsResponse) Reset() {
+	*x = CompactTranscriptsResponse{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ErrorEvent) String() string {
+func (x *CompactTranscriptsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ErrorEvent) ProtoMessage() {}
+func (*CompactTranscriptsResponse) ProtoMessage() {}
 
-func (x *ErrorEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[24]
+func (x *CompactTranscriptsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2201,64 +3362,60 @@ func (x *ErrorEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ErrorEvent.ProtoReflect.Descriptor instead.
-func (*ErrorEvent) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use CompactTranscriptsResponse.ProtoReflect.Descriptor instead.
+func (*CompactTranscriptsResponse) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *ErrorEvent) GetCode() string {
+func (x *CompactTranscriptsResponse) GetAgents() int32 {
 	if x != nil {
-		return x.Code
+		return x.Agents
 	}
-	return ""
+	return 0
 }
 
-func (x *ErrorEvent) GetMessage() string {
+func (x *CompactTranscriptsResponse) GetEntriesCompacted() int32 {
 	if x != nil {
-		return x.Message
+		return x.EntriesCompacted
 	}
-	return ""
+	return 0
 }
 
-func (x *ErrorEvent) GetRecoverable() bool {
+func (x *CompactTranscriptsResponse) GetSummaries() int32 {
 	if x != nil {
-		return x.Recoverable
+		return x.Summaries
 	}
-	return false
+	return 0
 }
 
-// ResourceViolationEvent is emitted when an agent exceeds resource limits.
-type ResourceViolationEvent struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Type of resource violated.
-	ResourceType ResourceType `protobuf:"varint,1,opt,name=resource_type,json=resourceType,proto3,enum=swarmd.v1.ResourceType" json:"resource_type,omitempty"`
-	// Current value.
-	CurrentValue float64 `protobuf:"fixed64,2,opt,name=current_value,json=currentValue,proto3" json:"current_value,omitempty"`
-	// Limit value.
-	LimitValue float64 `protobuf:"fixed64,3,opt,name=limit_value,json=limitValue,proto3" json:"limit_value,omitempty"`
-	// Number of consecutive violations.
-	ViolationCount int32 `protobuf:"varint,4,opt,name=violation_count,json=violationCount,proto3" json:"violation_count,omitempty"`
-	// Action taken in response.
-	ActionTaken   ResourceLimitAction `protobuf:"varint,5,opt,name=action_taken,json=actionTaken,proto3,enum=swarmd.v1.ResourceLimitAction" json:"action_taken,omitempty"`
+func (x *CompactTranscriptsResponse) GetBytesCompacted() int64 {
+	if x != nil {
+		return x.BytesCompacted
+	}
+	return 0
+}
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ResourceViolationEvent) Reset() {
-	*x = ResourceViolationEvent{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[25]
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ResourceViolationEvent) String() string {
+func (x *GetStatusRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResourceViolationEvent) ProtoMessage() {}
+func (*GetStatusRequest) ProtoMessage() {}
 
-func (x *ResourceViolationEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[25]
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2269,71 +3426,91 @@ func (x *ResourceViolationEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResourceViolationEvent.ProtoReflect.Descriptor instead.
-func (*ResourceViolationEvent) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *ResourceViolationEvent) GetResourceType() ResourceType {
-	if x != nil {
-		return x.ResourceType
-	}
-	return ResourceType_RESOURCE_TYPE_UNSPECIFIED
+type GetStatusResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Daemon status details.
+	Status        *DaemonStatus `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ResourceViolationEvent) GetCurrentValue() float64 {
-	if x != nil {
-		return x.CurrentValue
-	}
-	return 0
+func (x *GetStatusResponse) Reset() {
+	*x = GetStatusResponse{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *ResourceViolationEvent) GetLimitValue() float64 {
-	if x != nil {
-		return x.LimitValue
-	}
-	return 0
+func (x *GetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *ResourceViolationEvent) GetViolationCount() int32 {
+func (*GetStatusResponse) ProtoMessage() {}
+
+func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[40]
 	if x != nil {
-		return x.ViolationCount
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *ResourceViolationEvent) GetActionTaken() ResourceLimitAction {
+// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetStatusResponse) GetStatus() *DaemonStatus {
 	if x != nil {
-		return x.ActionTaken
+		return x.Status
 	}
-	return ResourceLimitAction_RESOURCE_LIMIT_ACTION_UNSPECIFIED
+	return nil
 }
 
-type PaneContentChangedEvent struct {
+type DaemonStatus struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// New content hash.
-	ContentHash string `protobuf:"bytes,1,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
-	// Number of lines changed.
-	LinesChanged  int32 `protobuf:"varint,2,opt,name=lines_changed,json=linesChanged,proto3" json:"lines_changed,omitempty"`
+	// Daemon version.
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Node hostname.
+	Hostname string `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	// When the daemon started.
+	StartedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	// Current uptime.
+	Uptime *durationpb.Duration `protobuf:"bytes,4,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	// Number of managed agents.
+	AgentCount int32 `protobuf:"varint,5,opt,name=agent_count,json=agentCount,proto3" json:"agent_count,omitempty"`
+	// Resource usage.
+	Resources *ResourceUsage `protobuf:"bytes,6,opt,name=resources,proto3" json:"resources,omitempty"`
+	// Health status.
+	Health        *HealthStatus `protobuf:"bytes,7,opt,name=health,proto3" json:"health,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PaneContentChangedEvent) Reset() {
-	*x = PaneContentChangedEvent{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[26]
+func (x *DaemonStatus) Reset() {
+	*x = DaemonStatus{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PaneContentChangedEvent) String() string {
+func (x *DaemonStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PaneContentChangedEvent) ProtoMessage() {}
+func (*DaemonStatus) ProtoMessage() {}
 
-func (x *PaneContentChangedEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[26]
+func (x *DaemonStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2344,54 +3521,89 @@ func (x *PaneContentChangedEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PaneContentChangedEvent.ProtoReflect.Descriptor instead.
-func (*PaneContentChangedEvent) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use DaemonStatus.ProtoReflect.Descriptor instead.
+func (*DaemonStatus) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *PaneContentChangedEvent) GetContentHash() string {
+func (x *DaemonStatus) GetVersion() string {
 	if x != nil {
-		return x.ContentHash
+		return x.Version
 	}
 	return ""
 }
 
-func (x *PaneContentChangedEvent) GetLinesChanged() int32 {
+func (x *DaemonStatus) GetHostname() string {
 	if x != nil {
-		return x.LinesChanged
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *DaemonStatus) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *DaemonStatus) GetUptime() *durationpb.Duration {
+	if x != nil {
+		return x.Uptime
+	}
+	return nil
+}
+
+func (x *DaemonStatus) GetAgentCount() int32 {
+	if x != nil {
+		return x.AgentCount
 	}
 	return 0
 }
 
-type GetTranscriptRequest struct {
+func (x *DaemonStatus) GetResources() *ResourceUsage {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *DaemonStatus) GetHealth() *HealthStatus {
+	if x != nil {
+		return x.Health
+	}
+	return nil
+}
+
+type ResourceUsage struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Agent ID.
-	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	// Start time (optional, defaults to session start).
-	StartTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
-	// End time (optional, defaults to now).
-	EndTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
-	// Maximum number of entries to return.
-	Limit         int32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	// CPU usage percentage.
+	CpuPercent float64 `protobuf:"fixed64,1,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	// Memory usage in bytes.
+	MemoryBytes int64 `protobuf:"varint,2,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	// Memory limit in bytes (0 = no limit).
+	MemoryLimitBytes int64 `protobuf:"varint,3,opt,name=memory_limit_bytes,json=memoryLimitBytes,proto3" json:"memory_limit_bytes,omitempty"`
+	// Number of open file descriptors.
+	OpenFds       int32 `protobuf:"varint,4,opt,name=open_fds,json=openFds,proto3" json:"open_fds,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTranscriptRequest) Reset() {
-	*x = GetTranscriptRequest{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[27]
+func (x *ResourceUsage) Reset() {
+	*x = ResourceUsage{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTranscriptRequest) String() string {
+func (x *ResourceUsage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTranscriptRequest) ProtoMessage() {}
+func (*ResourceUsage) ProtoMessage() {}
 
-func (x *GetTranscriptRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[27]
+func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2402,68 +3614,64 @@ func (x *GetTranscriptRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTranscriptRequest.ProtoReflect.Descriptor instead.
-func (*GetTranscriptRequest) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{27}
+// Deprecated: Use ResourceUsage.ProtoReflect.Descriptor instead.
+func (*ResourceUsage) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *GetTranscriptRequest) GetAgentId() string {
+func (x *ResourceUsage) GetCpuPercent() float64 {
 	if x != nil {
-		return x.AgentId
+		return x.CpuPercent
 	}
-	return ""
+	return 0
 }
 
-func (x *GetTranscriptRequest) GetStartTime() *timestamppb.Timestamp {
+func (x *ResourceUsage) GetMemoryBytes() int64 {
 	if x != nil {
-		return x.StartTime
+		return x.MemoryBytes
 	}
-	return nil
+	return 0
 }
 
-func (x *GetTranscriptRequest) GetEndTime() *timestamppb.Timestamp {
+func (x *ResourceUsage) GetMemoryLimitBytes() int64 {
 	if x != nil {
-		return x.EndTime
+		return x.MemoryLimitBytes
 	}
-	return nil
+	return 0
 }
 
-func (x *GetTranscriptRequest) GetLimit() int32 {
+func (x *ResourceUsage) GetOpenFds() int32 {
 	if x != nil {
-		return x.Limit
+		return x.OpenFds
 	}
 	return 0
 }
 
-type GetTranscriptResponse struct {
+type HealthStatus struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Agent ID.
-	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	// Transcript entries.
-	Entries []*TranscriptEntry `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
-	// Whether there are more entries.
-	HasMore bool `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
-	// Cursor for pagination.
-	NextCursor    string `protobuf:"bytes,4,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	// Overall health.
+	Health Health `protobuf:"varint,1,opt,name=health,proto3,enum=swarmd.v1.Health" json:"health,omitempty"`
+	// Component health checks.
+	Checks        []*HealthCheck `protobuf:"bytes,2,rep,name=checks,proto3" json:"checks,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTranscriptResponse) Reset() {
-	*x = GetTranscriptResponse{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[28]
+func (x *HealthStatus) Reset() {
+	*x = HealthStatus{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTranscriptResponse) String() string {
+func (x *HealthStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTranscriptResponse) ProtoMessage() {}
+func (*HealthStatus) ProtoMessage() {}
 
-func (x *GetTranscriptResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[28]
+func (x *HealthStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2474,68 +3682,54 @@ func (x *GetTranscriptResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTranscriptResponse.ProtoReflect.Descriptor instead.
-func (*GetTranscriptResponse) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{28}
+// Deprecated: Use HealthStatus.ProtoReflect.Descriptor instead.
+func (*HealthStatus) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *GetTranscriptResponse) GetAgentId() string {
+func (x *HealthStatus) GetHealth() Health {
 	if x != nil {
-		return x.AgentId
+		return x.Health
 	}
-	return ""
+	return Health_HEALTH_UNSPECIFIED
 }
 
-func (x *GetTranscriptResponse) GetEntries() []*TranscriptEntry {
+func (x *HealthStatus) GetChecks() []*HealthCheck {
 	if x != nil {
-		return x.Entries
+		return x.Checks
 	}
 	return nil
 }
 
-func (x *GetTranscriptResponse) GetHasMore() bool {
-	if x != nil {
-		return x.HasMore
-	}
-	return false
-}
-
-func (x *GetTranscriptResponse) GetNextCursor() string {
-	if x != nil {
-		return x.NextCursor
-	}
-	return ""
-}
-
-type TranscriptEntry struct {
+type HealthCheck struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Entry timestamp.
-	Timestamp *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	// Entry type.
-	Type TranscriptEntryType `protobuf:"varint,2,opt,name=type,proto3,enum=swarmd.v1.TranscriptEntryType" json:"type,omitempty"`
-	// Content.
-	Content string `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
-	// Associated metadata.
-	Metadata      map[string]string `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Component name.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Component health.
+	Health Health `protobuf:"varint,2,opt,name=health,proto3,enum=swarmd.v1.Health" json:"health,omitempty"`
+	// Status message.
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// Last check time.
+	LastCheck     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_check,json=lastCheck,proto3" json:"last_check,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TranscriptEntry) Reset() {
-	*x = TranscriptEntry{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[29]
+func (x *HealthCheck) Reset() {
+	*x = HealthCheck{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TranscriptEntry) String() string {
+func (x *HealthCheck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TranscriptEntry) ProtoMessage() {}
+func (*HealthCheck) ProtoMessage() {}
 
-func (x *TranscriptEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[29]
+func (x *HealthCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2546,64 +3740,60 @@ func (x *TranscriptEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TranscriptEntry.ProtoReflect.Descriptor instead.
-func (*TranscriptEntry) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{29}
+// Deprecated: Use HealthCheck.ProtoReflect.Descriptor instead.
+func (*HealthCheck) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *TranscriptEntry) GetTimestamp() *timestamppb.Timestamp {
+func (x *HealthCheck) GetName() string {
 	if x != nil {
-		return x.Timestamp
+		return x.Name
 	}
-	return nil
+	return ""
 }
 
-func (x *TranscriptEntry) GetType() TranscriptEntryType {
+func (x *HealthCheck) GetHealth() Health {
 	if x != nil {
-		return x.Type
+		return x.Health
 	}
-	return TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_UNSPECIFIED
+	return Health_HEALTH_UNSPECIFIED
 }
 
-func (x *TranscriptEntry) GetContent() string {
+func (x *HealthCheck) GetMessage() string {
 	if x != nil {
-		return x.Content
+		return x.Message
 	}
 	return ""
 }
 
-func (x *TranscriptEntry) GetMetadata() map[string]string {
+func (x *HealthCheck) GetLastCheck() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Metadata
+		return x.LastCheck
 	}
 	return nil
 }
 
-type StreamTranscriptRequest struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Agent ID.
-	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	// Resume from cursor (optional).
-	Cursor        string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+type PingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StreamTranscriptRequest) Reset() {
-	*x = StreamTranscriptRequest{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[30]
+func (x *PingRequest) Reset() {
+	*x = PingRequest{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StreamTranscriptRequest) String() string {
+func (x *PingRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamTranscriptRequest) ProtoMessage() {}
+func (*PingRequest) ProtoMessage() {}
 
-func (x *StreamTranscriptRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[30]
+func (x *PingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2614,50 +3804,36 @@ func (x *StreamTranscriptRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamTranscriptRequest.ProtoReflect.Descriptor instead.
-func (*StreamTranscriptRequest) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{30}
-}
-
-func (x *StreamTranscriptRequest) GetAgentId() string {
-	if x != nil {
-		return x.AgentId
-	}
-	return ""
-}
-
-func (x *StreamTranscriptRequest) GetCursor() string {
-	if x != nil {
-		return x.Cursor
-	}
-	return ""
+// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{45}
 }
 
-type StreamTranscriptResponse struct {
+type PingResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Entries in this chunk.
-	Entries []*TranscriptEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
-	// Cursor for resumption.
-	Cursor        string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Echo back timestamp.
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Daemon version.
+	Version       string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StreamTranscriptResponse) Reset() {
-	*x = StreamTranscriptResponse{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[31]
+func (x *PingResponse) Reset() {
+	*x = PingResponse{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StreamTranscriptResponse) String() string {
+func (x *PingResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamTranscriptResponse) ProtoMessage() {}
+func (*PingResponse) ProtoMessage() {}
 
-func (x *StreamTranscriptResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[31]
+func (x *PingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2668,46 +3844,46 @@ func (x *StreamTranscriptResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamTranscriptResponse.ProtoReflect.Descriptor instead.
-func (*StreamTranscriptResponse) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{31}
+// Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
+func (*PingResponse) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *StreamTranscriptResponse) GetEntries() []*TranscriptEntry {
+func (x *PingResponse) GetTimestamp() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Entries
+		return x.Timestamp
 	}
 	return nil
 }
 
-func (x *StreamTranscriptResponse) GetCursor() string {
+func (x *PingResponse) GetVersion() string {
 	if x != nil {
-		return x.Cursor
+		return x.Version
 	}
 	return ""
 }
 
-type GetStatusRequest struct {
+type DumpStateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetStatusRequest) Reset() {
-	*x = GetStatusRequest{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[32]
+func (x *DumpStateRequest) Reset() {
+	*x = DumpStateRequest{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStatusRequest) String() string {
+func (x *DumpStateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStatusRequest) ProtoMessage() {}
+func (*DumpStateRequest) ProtoMessage() {}
 
-func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[32]
+func (x *DumpStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2718,91 +3894,148 @@ func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
-func (*GetStatusRequest) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{32}
+// Deprecated: Use DumpStateRequest.ProtoReflect.Descriptor instead.
+func (*DumpStateRequest) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{47}
 }
 
-type GetStatusResponse struct {
+type DumpStateResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Daemon status details.
-	Status        *DaemonStatus `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// When the snapshot was taken.
+	CapturedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=captured_at,json=capturedAt,proto3" json:"captured_at,omitempty"`
+	// Daemon build information.
+	Build *BuildInfo `protobuf:"bytes,2,opt,name=build,proto3" json:"build,omitempty"`
+	// Current uptime.
+	Uptime *durationpb.Duration `protobuf:"bytes,3,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	// Managed agents.
+	Agents []*AgentDebugSummary `protobuf:"bytes,4,rep,name=agents,proto3" json:"agents,omitempty"`
+	// Number of open StreamEvents subscriptions.
+	EventSubscribers int32 `protobuf:"varint,5,opt,name=event_subscribers,json=eventSubscribers,proto3" json:"event_subscribers,omitempty"`
+	// Number of events held for cursor replay.
+	StoredEvents int32 `protobuf:"varint,6,opt,name=stored_events,json=storedEvents,proto3" json:"stored_events,omitempty"`
+	// Most recent health check results (unset until a check has run).
+	Health *HealthStatus `protobuf:"bytes,7,opt,name=health,proto3" json:"health,omitempty"`
+	// Rate limiter counters.
+	RateLimiter   *RateLimiterDebug `protobuf:"bytes,8,opt,name=rate_limiter,json=rateLimiter,proto3" json:"rate_limiter,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetStatusResponse) Reset() {
-	*x = GetStatusResponse{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[33]
+func (x *DumpStateResponse) Reset() {
+	*x = DumpStateResponse{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStatusResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *DumpStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpStateResponse) ProtoMessage() {}
+
+func (x *DumpStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpStateResponse.ProtoReflect.Descriptor instead.
+func (*DumpStateResponse) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *DumpStateResponse) GetCapturedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CapturedAt
+	}
+	return nil
+}
+
+func (x *DumpStateResponse) GetBuild() *BuildInfo {
+	if x != nil {
+		return x.Build
+	}
+	return nil
+}
+
+func (x *DumpStateResponse) GetUptime() *durationpb.Duration {
+	if x != nil {
+		return x.Uptime
+	}
+	return nil
+}
+
+func (x *DumpStateResponse) GetAgents() []*AgentDebugSummary {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *DumpStateResponse) GetEventSubscribers() int32 {
+	if x != nil {
+		return x.EventSubscribers
+	}
+	return 0
 }
 
-func (*GetStatusResponse) ProtoMessage() {}
-
-func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[33]
+func (x *DumpStateResponse) GetStoredEvents() int32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.StoredEvents
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
-func (*GetStatusResponse) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{33}
+func (x *DumpStateResponse) GetHealth() *HealthStatus {
+	if x != nil {
+		return x.Health
+	}
+	return nil
 }
 
-func (x *GetStatusResponse) GetStatus() *DaemonStatus {
+func (x *DumpStateResponse) GetRateLimiter() *RateLimiterDebug {
 	if x != nil {
-		return x.Status
+		return x.RateLimiter
 	}
 	return nil
 }
 
-type DaemonStatus struct {
+type BuildInfo struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Daemon version.
 	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
-	// Node hostname.
-	Hostname string `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	// When the daemon started.
-	StartedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	// Current uptime.
-	Uptime *durationpb.Duration `protobuf:"bytes,4,opt,name=uptime,proto3" json:"uptime,omitempty"`
-	// Number of managed agents.
-	AgentCount int32 `protobuf:"varint,5,opt,name=agent_count,json=agentCount,proto3" json:"agent_count,omitempty"`
-	// Resource usage.
-	Resources *ResourceUsage `protobuf:"bytes,6,opt,name=resources,proto3" json:"resources,omitempty"`
-	// Health status.
-	Health        *HealthStatus `protobuf:"bytes,7,opt,name=health,proto3" json:"health,omitempty"`
+	// Source commit.
+	Commit string `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	// Build date.
+	Built string `protobuf:"bytes,3,opt,name=built,proto3" json:"built,omitempty"`
+	// Go runtime version.
+	GoVersion     string `protobuf:"bytes,4,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DaemonStatus) Reset() {
-	*x = DaemonStatus{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[34]
+func (x *BuildInfo) Reset() {
+	*x = BuildInfo{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DaemonStatus) String() string {
+func (x *BuildInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DaemonStatus) ProtoMessage() {}
+func (*BuildInfo) ProtoMessage() {}
 
-func (x *DaemonStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[34]
+func (x *BuildInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2813,89 +4046,78 @@ func (x *DaemonStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DaemonStatus.ProtoReflect.Descriptor instead.
-func (*DaemonStatus) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{34}
+// Deprecated: Use BuildInfo.ProtoReflect.Descriptor instead.
+func (*BuildInfo) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{49}
 }
 
-func (x *DaemonStatus) GetVersion() string {
+func (x *BuildInfo) GetVersion() string {
 	if x != nil {
 		return x.Version
 	}
 	return ""
 }
 
-func (x *DaemonStatus) GetHostname() string {
+func (x *BuildInfo) GetCommit() string {
 	if x != nil {
-		return x.Hostname
+		return x.Commit
 	}
 	return ""
 }
 
-func (x *DaemonStatus) GetStartedAt() *timestamppb.Timestamp {
-	if x != nil {
-		return x.StartedAt
-	}
-	return nil
-}
-
-func (x *DaemonStatus) GetUptime() *durationpb.Duration {
-	if x != nil {
-		return x.Uptime
-	}
-	return nil
-}
-
-func (x *DaemonStatus) GetAgentCount() int32 {
-	if x != nil {
-		return x.AgentCount
-	}
-	return 0
-}
-
-func (x *DaemonStatus) GetResources() *ResourceUsage {
+func (x *BuildInfo) GetBuilt() string {
 	if x != nil {
-		return x.Resources
+		return x.Built
 	}
-	return nil
+	return ""
 }
 
-func (x *DaemonStatus) GetHealth() *HealthStatus {
+func (x *BuildInfo) GetGoVersion() string {
 	if x != nil {
-		return x.Health
+		return x.GoVersion
 	}
-	return nil
+	return ""
 }
 
-type ResourceUsage struct {
+type AgentDebugSummary struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// CPU usage percentage.
-	CpuPercent float64 `protobuf:"fixed64,1,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
-	// Memory usage in bytes.
-	MemoryBytes int64 `protobuf:"varint,2,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
-	// Memory limit in bytes (0 = no limit).
-	MemoryLimitBytes int64 `protobuf:"varint,3,opt,name=memory_limit_bytes,json=memoryLimitBytes,proto3" json:"memory_limit_bytes,omitempty"`
-	// Number of open file descriptors.
-	OpenFds       int32 `protobuf:"varint,4,opt,name=open_fds,json=openFds,proto3" json:"open_fds,omitempty"`
+	// Agent ID.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Workspace ID.
+	WorkspaceId string `protobuf:"bytes,2,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	// Current state.
+	State AgentState `protobuf:"varint,3,opt,name=state,proto3,enum=swarmd.v1.AgentState" json:"state,omitempty"`
+	// Tmux pane ID.
+	PaneId string `protobuf:"bytes,4,opt,name=pane_id,json=paneId,proto3" json:"pane_id,omitempty"`
+	// Number of transcript entries held.
+	TranscriptEntries int32 `protobuf:"varint,5,opt,name=transcript_entries,json=transcriptEntries,proto3" json:"transcript_entries,omitempty"`
+	// Last activity time.
+	LastActivityAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_activity_at,json=lastActivityAt,proto3" json:"last_activity_at,omitempty"`
+	// Open StreamPaneUpdates streams.
+	PaneStreams int32 `protobuf:"varint,7,opt,name=pane_streams,json=paneStreams,proto3" json:"pane_streams,omitempty"`
+	// Open StreamTranscript streams.
+	TranscriptStreams int32 `protobuf:"varint,8,opt,name=transcript_streams,json=transcriptStreams,proto3" json:"transcript_streams,omitempty"`
+	// Open StreamEvents subscriptions filtered to this agent.
+	EventStreams  int32 `protobuf:"varint,9,opt,name=event_streams,json=eventStreams,proto3" json:"event_streams,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ResourceUsage) Reset() {
-	*x = ResourceUsage{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[35]
+func (x *AgentDebugSummary) Reset() {
+	*x = AgentDebugSummary{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ResourceUsage) String() string {
+func (x *AgentDebugSummary) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResourceUsage) ProtoMessage() {}
+func (*AgentDebugSummary) ProtoMessage() {}
 
-func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[35]
+func (x *AgentDebugSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2906,64 +4128,101 @@ func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResourceUsage.ProtoReflect.Descriptor instead.
-func (*ResourceUsage) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{35}
+// Deprecated: Use AgentDebugSummary.ProtoReflect.Descriptor instead.
+func (*AgentDebugSummary) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{50}
 }
 
-func (x *ResourceUsage) GetCpuPercent() float64 {
+func (x *AgentDebugSummary) GetId() string {
 	if x != nil {
-		return x.CpuPercent
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AgentDebugSummary) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+func (x *AgentDebugSummary) GetState() AgentState {
+	if x != nil {
+		return x.State
+	}
+	return AgentState_AGENT_STATE_UNSPECIFIED
+}
+
+func (x *AgentDebugSummary) GetPaneId() string {
+	if x != nil {
+		return x.PaneId
+	}
+	return ""
+}
+
+func (x *AgentDebugSummary) GetTranscriptEntries() int32 {
+	if x != nil {
+		return x.TranscriptEntries
 	}
 	return 0
 }
 
-func (x *ResourceUsage) GetMemoryBytes() int64 {
+func (x *AgentDebugSummary) GetLastActivityAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.MemoryBytes
+		return x.LastActivityAt
+	}
+	return nil
+}
+
+func (x *AgentDebugSummary) GetPaneStreams() int32 {
+	if x != nil {
+		return x.PaneStreams
 	}
 	return 0
 }
 
-func (x *ResourceUsage) GetMemoryLimitBytes() int64 {
+func (x *AgentDebugSummary) GetTranscriptStreams() int32 {
 	if x != nil {
-		return x.MemoryLimitBytes
+		return x.TranscriptStreams
 	}
 	return 0
 }
 
-func (x *ResourceUsage) GetOpenFds() int32 {
+func (x *AgentDebugSummary) GetEventStreams() int32 {
 	if x != nil {
-		return x.OpenFds
+		return x.EventStreams
 	}
 	return 0
 }
 
-type HealthStatus struct {
+type RateLimiterDebug struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Overall health.
-	Health Health `protobuf:"varint,1,opt,name=health,proto3,enum=swarmd.v1.Health" json:"health,omitempty"`
-	// Component health checks.
-	Checks        []*HealthCheck `protobuf:"bytes,2,rep,name=checks,proto3" json:"checks,omitempty"`
+	// Whether rate limiting is enforced.
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// Per-method counters.
+	Methods []*RateLimitCounter `protobuf:"bytes,2,rep,name=methods,proto3" json:"methods,omitempty"`
+	// Global counter (unset when no global limit is configured).
+	Global        *RateLimitCounter `protobuf:"bytes,3,opt,name=global,proto3" json:"global,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthStatus) Reset() {
-	*x = HealthStatus{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[36]
+func (x *RateLimiterDebug) Reset() {
+	*x = RateLimiterDebug{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthStatus) String() string {
+func (x *RateLimiterDebug) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthStatus) ProtoMessage() {}
+func (*RateLimiterDebug) ProtoMessage() {}
 
-func (x *HealthStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[36]
+func (x *RateLimiterDebug) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2974,54 +4233,61 @@ func (x *HealthStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthStatus.ProtoReflect.Descriptor instead.
-func (*HealthStatus) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{36}
+// Deprecated: Use RateLimiterDebug.ProtoReflect.Descriptor instead.
+func (*RateLimiterDebug) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{51}
 }
 
-func (x *HealthStatus) GetHealth() Health {
+func (x *RateLimiterDebug) GetEnabled() bool {
 	if x != nil {
-		return x.Health
+		return x.Enabled
 	}
-	return Health_HEALTH_UNSPECIFIED
+	return false
 }
 
-func (x *HealthStatus) GetChecks() []*HealthCheck {
+func (x *RateLimiterDebug) GetMethods() []*RateLimitCounter {
 	if x != nil {
-		return x.Checks
+		return x.Methods
 	}
 	return nil
 }
 
-type HealthCheck struct {
+func (x *RateLimiterDebug) GetGlobal() *RateLimitCounter {
+	if x != nil {
+		return x.Global
+	}
+	return nil
+}
+
+type RateLimitCounter struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Component name.
-	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	// Component health.
-	Health Health `protobuf:"varint,2,opt,name=health,proto3,enum=swarmd.v1.Health" json:"health,omitempty"`
-	// Status message.
-	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	// Last check time.
-	LastCheck     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_check,json=lastCheck,proto3" json:"last_check,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// Full gRPC method name, or "global".
+	Method string `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	// Tokens currently available.
+	Available float64 `protobuf:"fixed64,2,opt,name=available,proto3" json:"available,omitempty"`
+	// Requests seen.
+	TotalRequests int64 `protobuf:"varint,3,opt,name=total_requests,json=totalRequests,proto3" json:"total_requests,omitempty"`
+	// Requests denied.
+	DeniedRequests int64 `protobuf:"varint,4,opt,name=denied_requests,json=deniedRequests,proto3" json:"denied_requests,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *HealthCheck) Reset() {
-	*x = HealthCheck{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[37]
+func (x *RateLimitCounter) Reset() {
+	*x = RateLimitCounter{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheck) String() string {
+func (x *RateLimitCounter) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheck) ProtoMessage() {}
+func (*RateLimitCounter) ProtoMessage() {}
 
-func (x *HealthCheck) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[37]
+func (x *RateLimitCounter) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3032,60 +4298,60 @@ func (x *HealthCheck) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheck.ProtoReflect.Descriptor instead.
-func (*HealthCheck) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{37}
+// Deprecated: Use RateLimitCounter.ProtoReflect.Descriptor instead.
+func (*RateLimitCounter) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{52}
 }
 
-func (x *HealthCheck) GetName() string {
+func (x *RateLimitCounter) GetMethod() string {
 	if x != nil {
-		return x.Name
+		return x.Method
 	}
 	return ""
 }
 
-func (x *HealthCheck) GetHealth() Health {
+func (x *RateLimitCounter) GetAvailable() float64 {
 	if x != nil {
-		return x.Health
+		return x.Available
 	}
-	return Health_HEALTH_UNSPECIFIED
+	return 0
 }
 
-func (x *HealthCheck) GetMessage() string {
+func (x *RateLimitCounter) GetTotalRequests() int64 {
 	if x != nil {
-		return x.Message
+		return x.TotalRequests
 	}
-	return ""
+	return 0
 }
 
-func (x *HealthCheck) GetLastCheck() *timestamppb.Timestamp {
+func (x *RateLimitCounter) GetDeniedRequests() int64 {
 	if x != nil {
-		return x.LastCheck
+		return x.DeniedRequests
 	}
-	return nil
+	return 0
 }
 
-type PingRequest struct {
+type ReloadConfigRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PingRequest) Reset() {
-	*x = PingRequest{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[38]
+func (x *ReloadConfigRequest) Reset() {
+	*x = ReloadConfigRequest{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PingRequest) String() string {
+func (x *ReloadConfigRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PingRequest) ProtoMessage() {}
+func (*ReloadConfigRequest) ProtoMessage() {}
 
-func (x *PingRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[38]
+func (x *ReloadConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3096,36 +4362,38 @@ func (x *PingRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
-func (*PingRequest) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{38}
+// Deprecated: Use ReloadConfigRequest.ProtoReflect.Descriptor instead.
+func (*ReloadConfigRequest) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{53}
 }
 
-type PingResponse struct {
+type ReloadConfigResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Echo back timestamp.
-	Timestamp *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	// Daemon version.
-	Version       string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *PingResponse) Reset() {
-	*x = PingResponse{}
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[39]
+	// Config file that was read (empty when only defaults and the
+	// environment apply).
+	ConfigFile string `protobuf:"bytes,1,opt,name=config_file,json=configFile,proto3" json:"config_file,omitempty"`
+	// Settings that changed but only take effect on restart, such as
+	// "database.path".
+	RestartRequired []string `protobuf:"bytes,2,rep,name=restart_required,json=restartRequired,proto3" json:"restart_required,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ReloadConfigResponse) Reset() {
+	*x = ReloadConfigResponse{}
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PingResponse) String() string {
+func (x *ReloadConfigResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PingResponse) ProtoMessage() {}
+func (*ReloadConfigResponse) ProtoMessage() {}
 
-func (x *PingResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_swarmd_v1_swarmd_proto_msgTypes[39]
+func (x *ReloadConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swarmd_v1_swarmd_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3136,30 +4404,30 @@ func (x *PingResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
-func (*PingResponse) Descriptor() ([]byte, []int) {
-	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{39}
+// Deprecated: Use ReloadConfigResponse.ProtoReflect.Descriptor instead.
+func (*ReloadConfigResponse) Descriptor() ([]byte, []int) {
+	return file_swarmd_v1_swarmd_proto_rawDescGZIP(), []int{54}
 }
 
-func (x *PingResponse) GetTimestamp() *timestamppb.Timestamp {
+func (x *ReloadConfigResponse) GetConfigFile() string {
 	if x != nil {
-		return x.Timestamp
+		return x.ConfigFile
 	}
-	return nil
+	return ""
 }
 
-func (x *PingResponse) GetVersion() string {
+func (x *ReloadConfigResponse) GetRestartRequired() []string {
 	if x != nil {
-		return x.Version
+		return x.RestartRequired
 	}
-	return ""
+	return nil
 }
 
 var File_swarmd_v1_swarmd_proto protoreflect.FileDescriptor
 
 const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"\n" +
-	"\x16swarmd/v1/swarmd.proto\x12\tswarmd.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"\x92\x03\n" +
+	"\x16swarmd/v1/swarmd.proto\x12\tswarmd.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"\x9d\x04\n" +
 	"\x11SpawnAgentRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12!\n" +
 	"\fworkspace_id\x18\x02 \x01(\tR\vworkspaceId\x12\x18\n" +
@@ -3170,7 +4438,14 @@ const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"workingDir\x12!\n" +
 	"\fsession_name\x18\a \x01(\tR\vsessionName\x12\x18\n" +
 	"\aadapter\x18\b \x01(\tR\aadapter\x12B\n" +
-	"\x0fresource_limits\x18\t \x01(\v2\x19.swarmd.v1.ResourceLimitsR\x0eresourceLimits\x1a6\n" +
+	"\x0fresource_limits\x18\t \x01(\v2\x19.swarmd.v1.ResourceLimitsR\x0eresourceLimits\x120\n" +
+	"\x14attach_existing_pane\x18\n" +
+	" \x01(\bR\x12attachExistingPane\x12\x17\n" +
+	"\apane_id\x18\v \x01(\tR\x06paneId\x12\x1d\n" +
+	"\n" +
+	"pane_width\x18\f \x01(\x05R\tpaneWidth\x12\x1f\n" +
+	"\vpane_height\x18\r \x01(\x05R\n" +
+	"paneHeight\x1a6\n" +
 	"\bEnvEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x89\x02\n" +
@@ -3188,24 +4463,27 @@ const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"\x05force\x18\x02 \x01(\bR\x05force\x12<\n" +
 	"\fgrace_period\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\vgracePeriod\"-\n" +
 	"\x11KillAgentResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"t\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x92\x01\n" +
 	"\x10SendInputRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
 	"\x04text\x18\x02 \x01(\tR\x04text\x12\x1d\n" +
 	"\n" +
 	"send_enter\x18\x03 \x01(\bR\tsendEnter\x12\x12\n" +
-	"\x04keys\x18\x04 \x03(\tR\x04keys\"-\n" +
+	"\x04keys\x18\x04 \x03(\tR\x04keys\x12\x1c\n" +
+	"\tsensitive\x18\x05 \x01(\bR\tsensitive\"-\n" +
 	"\x11SendInputResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\"e\n" +
 	"\x11ListAgentsRequest\x12!\n" +
 	"\fworkspace_id\x18\x01 \x01(\tR\vworkspaceId\x12-\n" +
 	"\x06states\x18\x02 \x03(\x0e2\x15.swarmd.v1.AgentStateR\x06states\">\n" +
 	"\x12ListAgentsResponse\x12(\n" +
-	"\x06agents\x18\x01 \x03(\v2\x10.swarmd.v1.AgentR\x06agents\",\n" +
+	"\x06agents\x18\x01 \x03(\v2\x10.swarmd.v1.AgentR\x06agents\"\xb3\x01\n" +
 	"\x0fGetAgentRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\":\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12O\n" +
+	"\x19wait_for_state_other_than\x18\x02 \x01(\x0e2\x15.swarmd.v1.AgentStateR\x15waitForStateOtherThan\x124\n" +
+	"\bmax_wait\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\amaxWait\":\n" +
 	"\x10GetAgentResponse\x12&\n" +
-	"\x05agent\x18\x01 \x01(\v2\x10.swarmd.v1.AgentR\x05agent\"\xf4\x03\n" +
+	"\x05agent\x18\x01 \x01(\v2\x10.swarmd.v1.AgentR\x05agent\"\xab\x05\n" +
 	"\x05Agent\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
 	"\fworkspace_id\x18\x02 \x01(\tR\vworkspaceId\x12+\n" +
@@ -3220,7 +4498,11 @@ const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"\fcontent_hash\x18\n" +
 	" \x01(\tR\vcontentHash\x12B\n" +
 	"\x0fresource_limits\x18\v \x01(\v2\x19.swarmd.v1.ResourceLimitsR\x0eresourceLimits\x12D\n" +
-	"\x0eresource_usage\x18\f \x01(\v2\x1d.swarmd.v1.AgentResourceUsageR\rresourceUsage\"\xea\x01\n" +
+	"\x0eresource_usage\x18\f \x01(\v2\x1d.swarmd.v1.AgentResourceUsageR\rresourceUsage\x12\x1b\n" +
+	"\tcost_date\x18\r \x01(\tR\bcostDate\x12*\n" +
+	"\x11actual_cost_cents\x18\x0e \x01(\x03R\x0factualCostCents\x120\n" +
+	"\x14estimated_cost_cents\x18\x0f \x01(\x03R\x12estimatedCostCents\x12:\n" +
+	"\x19estimated_working_seconds\x18\x10 \x01(\x03R\x17estimatedWorkingSeconds\"\xea\x01\n" +
 	"\x12AgentResourceUsage\x12\x1f\n" +
 	"\vcpu_percent\x18\x01 \x01(\x01R\n" +
 	"cpuPercent\x12!\n" +
@@ -3228,11 +4510,14 @@ const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"\x11peak_memory_bytes\x18\x03 \x01(\x03R\x0fpeakMemoryBytes\x12'\n" +
 	"\x0fviolation_count\x18\x04 \x01(\x05R\x0eviolationCount\x12;\n" +
 	"\vmeasured_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
-	"measuredAt\"\x7f\n" +
+	"measuredAt\"\x8b\x02\n" +
 	"\x12CapturePaneRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x128\n" +
 	"\x18include_escape_sequences\x18\x02 \x01(\bR\x16includeEscapeSequences\x12\x14\n" +
-	"\x05lines\x18\x03 \x01(\x05R\x05lines\"\xf3\x01\n" +
+	"\x05lines\x18\x03 \x01(\x05R\x05lines\x12#\n" +
+	"\rsince_pattern\x18\x04 \x01(\tR\fsincePattern\x12/\n" +
+	"\x14wait_for_change_from\x18\x05 \x01(\tR\x11waitForChangeFrom\x124\n" +
+	"\bmax_wait\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\amaxWait\"\xfa\x02\n" +
 	"\x13CapturePaneResponse\x12\x18\n" +
 	"\acontent\x18\x01 \x01(\tR\acontent\x12!\n" +
 	"\fcontent_hash\x18\x02 \x01(\tR\vcontentHash\x12\x14\n" +
@@ -3241,19 +4526,51 @@ const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"\bcursor_x\x18\x05 \x01(\x05R\acursorX\x12\x19\n" +
 	"\bcursor_y\x18\x06 \x01(\x05R\acursorY\x12;\n" +
 	"\vcaptured_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
-	"capturedAt\"\xc4\x01\n" +
+	"capturedAt\x12\x1d\n" +
+	"\n" +
+	"line_count\x18\b \x01(\x05R\tlineCount\x12\x1f\n" +
+	"\vtotal_lines\x18\t \x01(\x05R\n" +
+	"totalLines\x12\x1c\n" +
+	"\ttruncated\x18\n" +
+	" \x01(\bR\ttruncated\x12'\n" +
+	"\x0fpattern_matched\x18\v \x01(\bR\x0epatternMatched\"\x82\x02\n" +
 	"\x18StreamPaneUpdatesRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12<\n" +
 	"\fmin_interval\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\vminInterval\x12&\n" +
 	"\x0flast_known_hash\x18\x03 \x01(\tR\rlastKnownHash\x12'\n" +
-	"\x0finclude_content\x18\x04 \x01(\bR\x0eincludeContent\"\x85\x02\n" +
+	"\x0finclude_content\x18\x04 \x01(\bR\x0eincludeContent\x12<\n" +
+	"\fmax_interval\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\vmaxInterval\"\x86\x03\n" +
 	"\x19StreamPaneUpdatesResponse\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12!\n" +
 	"\fcontent_hash\x18\x02 \x01(\tR\vcontentHash\x12\x18\n" +
 	"\acontent\x18\x03 \x01(\tR\acontent\x12\x18\n" +
 	"\achanged\x18\x04 \x01(\bR\achanged\x128\n" +
 	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12<\n" +
-	"\x0edetected_state\x18\x06 \x01(\x0e2\x15.swarmd.v1.AgentStateR\rdetectedState\"\x9b\x01\n" +
+	"\x0edetected_state\x18\x06 \x01(\x0e2\x15.swarmd.v1.AgentStateR\rdetectedState\x12>\n" +
+	"\rpoll_interval\x18\a \x01(\v2\x19.google.protobuf.DurationR\fpollInterval\x12%\n" +
+	"\x0estate_detector\x18\b \x01(\tR\rstateDetector\x12\x18\n" +
+	"\aremoved\x18\t \x01(\bR\aremoved\"\xb3\x03\n" +
+	"\x1dStreamWorkspaceUpdatesRequest\x12!\n" +
+	"\fworkspace_id\x18\x01 \x01(\tR\vworkspaceId\x12\x1b\n" +
+	"\tagent_ids\x18\x02 \x03(\tR\bagentIds\x12<\n" +
+	"\fmin_interval\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\vminInterval\x12i\n" +
+	"\x11last_known_hashes\x18\x04 \x03(\v2=.swarmd.v1.StreamWorkspaceUpdatesRequest.LastKnownHashesEntryR\x0flastKnownHashes\x12'\n" +
+	"\x0finclude_content\x18\x05 \x01(\bR\x0eincludeContent\x12<\n" +
+	"\fmax_interval\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\vmaxInterval\x1aB\n" +
+	"\x14LastKnownHashesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\\\n" +
+	"\x11ResizePaneRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x05R\x06height\"B\n" +
+	"\x12ResizePaneResponse\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\x05R\x06height\"H\n" +
+	"\x14SetPaneLayoutRequest\x12\x18\n" +
+	"\asession\x18\x01 \x01(\tR\asession\x12\x16\n" +
+	"\x06layout\x18\x02 \x01(\tR\x06layout\"\x17\n" +
+	"\x15SetPaneLayoutResponse\"\x9b\x01\n" +
 	"\x13StreamEventsRequest\x12\x16\n" +
 	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12*\n" +
 	"\x05types\x18\x02 \x03(\x0e2\x14.swarmd.v1.EventTypeR\x05types\x12\x1b\n" +
@@ -3310,33 +4627,48 @@ const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"\faction_taken\x18\x05 \x01(\x0e2\x1e.swarmd.v1.ResourceLimitActionR\vactionTaken\"a\n" +
 	"\x17PaneContentChangedEvent\x12!\n" +
 	"\fcontent_hash\x18\x01 \x01(\tR\vcontentHash\x12#\n" +
-	"\rlines_changed\x18\x02 \x01(\x05R\flinesChanged\"\xb9\x01\n" +
+	"\rlines_changed\x18\x02 \x01(\x05R\flinesChanged\"\xd1\x01\n" +
 	"\x14GetTranscriptRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x129\n" +
 	"\n" +
 	"start_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
 	"\bend_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x14\n" +
-	"\x05limit\x18\x04 \x01(\x05R\x05limit\"\xa4\x01\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x05 \x01(\tR\x06cursor\"\xeb\x01\n" +
 	"\x15GetTranscriptResponse\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x124\n" +
 	"\aentries\x18\x02 \x03(\v2\x1a.swarmd.v1.TranscriptEntryR\aentries\x12\x19\n" +
 	"\bhas_more\x18\x03 \x01(\bR\ahasMore\x12\x1f\n" +
 	"\vnext_cursor\x18\x04 \x01(\tR\n" +
-	"nextCursor\"\x9c\x02\n" +
+	"nextCursor\x12\x1c\n" +
+	"\ttruncated\x18\x05 \x01(\bR\ttruncated\x12'\n" +
+	"\x0fearliest_cursor\x18\x06 \x01(\tR\x0eearliestCursor\"\xac\x02\n" +
 	"\x0fTranscriptEntry\x128\n" +
 	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x122\n" +
 	"\x04type\x18\x02 \x01(\x0e2\x1e.swarmd.v1.TranscriptEntryTypeR\x04type\x12\x18\n" +
 	"\acontent\x18\x03 \x01(\tR\acontent\x12D\n" +
-	"\bmetadata\x18\x04 \x03(\v2(.swarmd.v1.TranscriptEntry.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\x04 \x03(\v2(.swarmd.v1.TranscriptEntry.MetadataEntryR\bmetadata\x12\x0e\n" +
+	"\x02id\x18\x05 \x01(\x03R\x02id\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"L\n" +
 	"\x17StreamTranscriptRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
-	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"h\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"\x86\x01\n" +
 	"\x18StreamTranscriptResponse\x124\n" +
 	"\aentries\x18\x01 \x03(\v2\x1a.swarmd.v1.TranscriptEntryR\aentries\x12\x16\n" +
-	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"\x12\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x1c\n" +
+	"\ttruncated\x18\x03 \x01(\bR\ttruncated\"\x89\x01\n" +
+	"\x19CompactTranscriptsRequest\x128\n" +
+	"\n" +
+	"older_than\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\tolderThan\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\"\xa8\x01\n" +
+	"\x1aCompactTranscriptsResponse\x12\x16\n" +
+	"\x06agents\x18\x01 \x01(\x05R\x06agents\x12+\n" +
+	"\x11entries_compacted\x18\x02 \x01(\x05R\x10entriesCompacted\x12\x1c\n" +
+	"\tsummaries\x18\x03 \x01(\x05R\tsummaries\x12'\n" +
+	"\x0fbytes_compacted\x18\x04 \x01(\x03R\x0ebytesCompacted\"\x12\n" +
 	"\x10GetStatusRequest\"D\n" +
 	"\x11GetStatusResponse\x12/\n" +
 	"\x06status\x18\x01 \x01(\v2\x17.swarmd.v1.DaemonStatusR\x06status\"\xbc\x02\n" +
@@ -3368,7 +4700,48 @@ const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"\vPingRequest\"b\n" +
 	"\fPingResponse\x128\n" +
 	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x18\n" +
-	"\aversion\x18\x02 \x01(\tR\aversion*\xa0\x01\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\"\x12\n" +
+	"\x10DumpStateRequest\"\xa8\x03\n" +
+	"\x11DumpStateResponse\x12;\n" +
+	"\vcaptured_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"capturedAt\x12*\n" +
+	"\x05build\x18\x02 \x01(\v2\x14.swarmd.v1.BuildInfoR\x05build\x121\n" +
+	"\x06uptime\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x06uptime\x124\n" +
+	"\x06agents\x18\x04 \x03(\v2\x1c.swarmd.v1.AgentDebugSummaryR\x06agents\x12+\n" +
+	"\x11event_subscribers\x18\x05 \x01(\x05R\x10eventSubscribers\x12#\n" +
+	"\rstored_events\x18\x06 \x01(\x05R\fstoredEvents\x12/\n" +
+	"\x06health\x18\a \x01(\v2\x17.swarmd.v1.HealthStatusR\x06health\x12>\n" +
+	"\frate_limiter\x18\b \x01(\v2\x1b.swarmd.v1.RateLimiterDebugR\vrateLimiter\"r\n" +
+	"\tBuildInfo\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06commit\x18\x02 \x01(\tR\x06commit\x12\x14\n" +
+	"\x05built\x18\x03 \x01(\tR\x05built\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x04 \x01(\tR\tgoVersion\"\xf8\x02\n" +
+	"\x11AgentDebugSummary\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fworkspace_id\x18\x02 \x01(\tR\vworkspaceId\x12+\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x15.swarmd.v1.AgentStateR\x05state\x12\x17\n" +
+	"\apane_id\x18\x04 \x01(\tR\x06paneId\x12-\n" +
+	"\x12transcript_entries\x18\x05 \x01(\x05R\x11transcriptEntries\x12D\n" +
+	"\x10last_activity_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x0elastActivityAt\x12!\n" +
+	"\fpane_streams\x18\a \x01(\x05R\vpaneStreams\x12-\n" +
+	"\x12transcript_streams\x18\b \x01(\x05R\x11transcriptStreams\x12#\n" +
+	"\revent_streams\x18\t \x01(\x05R\feventStreams\"\x98\x01\n" +
+	"\x10RateLimiterDebug\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x125\n" +
+	"\amethods\x18\x02 \x03(\v2\x1b.swarmd.v1.RateLimitCounterR\amethods\x123\n" +
+	"\x06global\x18\x03 \x01(\v2\x1b.swarmd.v1.RateLimitCounterR\x06global\"\x98\x01\n" +
+	"\x10RateLimitCounter\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x1c\n" +
+	"\tavailable\x18\x02 \x01(\x01R\tavailable\x12%\n" +
+	"\x0etotal_requests\x18\x03 \x01(\x03R\rtotalRequests\x12'\n" +
+	"\x0fdenied_requests\x18\x04 \x01(\x03R\x0edeniedRequests\"\x15\n" +
+	"\x13ReloadConfigRequest\"b\n" +
+	"\x14ReloadConfigResponse\x12\x1f\n" +
+	"\vconfig_file\x18\x01 \x01(\tR\n" +
+	"configFile\x12)\n" +
+	"\x10restart_required\x18\x02 \x03(\tR\x0frestartRequired*\xa0\x01\n" +
 	"\x13ResourceLimitAction\x12%\n" +
 	"!RESOURCE_LIMIT_ACTION_UNSPECIFIED\x10\x00\x12\x1e\n" +
 	"\x1aRESOURCE_LIMIT_ACTION_WARN\x10\x01\x12\"\n" +
@@ -3397,7 +4770,7 @@ const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"\fResourceType\x12\x1d\n" +
 	"\x19RESOURCE_TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
 	"\x11RESOURCE_TYPE_CPU\x10\x01\x12\x18\n" +
-	"\x14RESOURCE_TYPE_MEMORY\x10\x02*\x94\x02\n" +
+	"\x14RESOURCE_TYPE_MEMORY\x10\x02*\xb7\x02\n" +
 	"\x13TranscriptEntryType\x12%\n" +
 	"!TRANSCRIPT_ENTRY_TYPE_UNSPECIFIED\x10\x00\x12!\n" +
 	"\x1dTRANSCRIPT_ENTRY_TYPE_COMMAND\x10\x01\x12 \n" +
@@ -3405,12 +4778,13 @@ const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"\x1bTRANSCRIPT_ENTRY_TYPE_ERROR\x10\x03\x12&\n" +
 	"\"TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE\x10\x04\x12\"\n" +
 	"\x1eTRANSCRIPT_ENTRY_TYPE_APPROVAL\x10\x05\x12$\n" +
-	" TRANSCRIPT_ENTRY_TYPE_USER_INPUT\x10\x06*_\n" +
+	" TRANSCRIPT_ENTRY_TYPE_USER_INPUT\x10\x06\x12!\n" +
+	"\x1dTRANSCRIPT_ENTRY_TYPE_SUMMARY\x10\a*_\n" +
 	"\x06Health\x12\x16\n" +
 	"\x12HEALTH_UNSPECIFIED\x10\x00\x12\x12\n" +
 	"\x0eHEALTH_HEALTHY\x10\x01\x12\x13\n" +
 	"\x0fHEALTH_DEGRADED\x10\x02\x12\x14\n" +
-	"\x10HEALTH_UNHEALTHY\x10\x032\xb1\a\n" +
+	"\x10HEALTH_UNHEALTHY\x10\x032\xb8\v\n" +
 	"\rSwarmdService\x12I\n" +
 	"\n" +
 	"SpawnAgent\x12\x1c.swarmd.v1.SpawnAgentRequest\x1a\x1d.swarmd.v1.SpawnAgentResponse\x12F\n" +
@@ -3420,12 +4794,19 @@ const file_swarmd_v1_swarmd_proto_rawDesc = "" +
 	"ListAgents\x12\x1c.swarmd.v1.ListAgentsRequest\x1a\x1d.swarmd.v1.ListAgentsResponse\x12C\n" +
 	"\bGetAgent\x12\x1a.swarmd.v1.GetAgentRequest\x1a\x1b.swarmd.v1.GetAgentResponse\x12L\n" +
 	"\vCapturePane\x12\x1d.swarmd.v1.CapturePaneRequest\x1a\x1e.swarmd.v1.CapturePaneResponse\x12`\n" +
-	"\x11StreamPaneUpdates\x12#.swarmd.v1.StreamPaneUpdatesRequest\x1a$.swarmd.v1.StreamPaneUpdatesResponse0\x01\x12Q\n" +
+	"\x11StreamPaneUpdates\x12#.swarmd.v1.StreamPaneUpdatesRequest\x1a$.swarmd.v1.StreamPaneUpdatesResponse0\x01\x12j\n" +
+	"\x16StreamWorkspaceUpdates\x12(.swarmd.v1.StreamWorkspaceUpdatesRequest\x1a$.swarmd.v1.StreamPaneUpdatesResponse0\x01\x12I\n" +
+	"\n" +
+	"ResizePane\x12\x1c.swarmd.v1.ResizePaneRequest\x1a\x1d.swarmd.v1.ResizePaneResponse\x12R\n" +
+	"\rSetPaneLayout\x12\x1f.swarmd.v1.SetPaneLayoutRequest\x1a .swarmd.v1.SetPaneLayoutResponse\x12Q\n" +
 	"\fStreamEvents\x12\x1e.swarmd.v1.StreamEventsRequest\x1a\x1f.swarmd.v1.StreamEventsResponse0\x01\x12R\n" +
 	"\rGetTranscript\x12\x1f.swarmd.v1.GetTranscriptRequest\x1a .swarmd.v1.GetTranscriptResponse\x12]\n" +
-	"\x10StreamTranscript\x12\".swarmd.v1.StreamTranscriptRequest\x1a#.swarmd.v1.StreamTranscriptResponse0\x01\x12F\n" +
+	"\x10StreamTranscript\x12\".swarmd.v1.StreamTranscriptRequest\x1a#.swarmd.v1.StreamTranscriptResponse0\x01\x12a\n" +
+	"\x12CompactTranscripts\x12$.swarmd.v1.CompactTranscriptsRequest\x1a%.swarmd.v1.CompactTranscriptsResponse\x12F\n" +
 	"\tGetStatus\x12\x1b.swarmd.v1.GetStatusRequest\x1a\x1c.swarmd.v1.GetStatusResponse\x127\n" +
-	"\x04Ping\x12\x16.swarmd.v1.PingRequest\x1a\x17.swarmd.v1.PingResponseB\x92\x01\n" +
+	"\x04Ping\x12\x16.swarmd.v1.PingRequest\x1a\x17.swarmd.v1.PingResponse\x12F\n" +
+	"\tDumpState\x12\x1b.swarmd.v1.DumpStateRequest\x1a\x1c.swarmd.v1.DumpStateResponse\x12O\n" +
+	"\fReloadConfig\x12\x1e.swarmd.v1.ReloadConfigRequest\x1a\x1f.swarmd.v1.ReloadConfigResponseB\x92\x01\n" +
 	"\rcom.swarmd.v1B\vSwarmdProtoP\x01Z/github.com/opencode-ai/swarm/swarmd/v1;swarmdv1\xa2\x02\x03SXX\xaa\x02\tSwarmd.V1\xca\x02\tSwarmd\\V1\xe2\x02\x15Swarmd\\V1\\GPBMetadata\xea\x02\n" +
 	"Swarmd::V1b\x06proto3"
 
@@ -3442,140 +4823,187 @@ func file_swarmd_v1_swarmd_proto_rawDescGZIP() []byte {
 }
 
 var file_swarmd_v1_swarmd_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
-var file_swarmd_v1_swarmd_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
+var file_swarmd_v1_swarmd_proto_msgTypes = make([]protoimpl.MessageInfo, 58)
 var file_swarmd_v1_swarmd_proto_goTypes = []any{
-	(ResourceLimitAction)(0),          // 0: swarmd.v1.ResourceLimitAction
-	(AgentState)(0),                   // 1: swarmd.v1.AgentState
-	(EventType)(0),                    // 2: swarmd.v1.EventType
-	(ResourceType)(0),                 // 3: swarmd.v1.ResourceType
-	(TranscriptEntryType)(0),          // 4: swarmd.v1.TranscriptEntryType
-	(Health)(0),                       // 5: swarmd.v1.Health
-	(*SpawnAgentRequest)(nil),         // 6: swarmd.v1.SpawnAgentRequest
-	(*ResourceLimits)(nil),            // 7: swarmd.v1.ResourceLimits
-	(*SpawnAgentResponse)(nil),        // 8: swarmd.v1.SpawnAgentResponse
-	(*KillAgentRequest)(nil),          // 9: swarmd.v1.KillAgentRequest
-	(*KillAgentResponse)(nil),         // 10: swarmd.v1.KillAgentResponse
-	(*SendInputRequest)(nil),          // 11: swarmd.v1.SendInputRequest
-	(*SendInputResponse)(nil),         // 12: swarmd.v1.SendInputResponse
-	(*ListAgentsRequest)(nil),         // 13: swarmd.v1.ListAgentsRequest
-	(*ListAgentsResponse)(nil),        // 14: swarmd.v1.ListAgentsResponse
-	(*GetAgentRequest)(nil),           // 15: swarmd.v1.GetAgentRequest
-	(*GetAgentResponse)(nil),          // 16: swarmd.v1.GetAgentResponse
-	(*Agent)(nil),                     // 17: swarmd.v1.Agent
-	(*AgentResourceUsage)(nil),        // 18: swarmd.v1.AgentResourceUsage
-	(*CapturePaneRequest)(nil),        // 19: swarmd.v1.CapturePaneRequest
-	(*CapturePaneResponse)(nil),       // 20: swarmd.v1.CapturePaneResponse
-	(*StreamPaneUpdatesRequest)(nil),  // 21: swarmd.v1.StreamPaneUpdatesRequest
-	(*StreamPaneUpdatesResponse)(nil), // 22: swarmd.v1.StreamPaneUpdatesResponse
-	(*StreamEventsRequest)(nil),       // 23: swarmd.v1.StreamEventsRequest
-	(*StreamEventsResponse)(nil),      // 24: swarmd.v1.StreamEventsResponse
-	(*Event)(nil),                     // 25: swarmd.v1.Event
-	(*AgentStateChangedEvent)(nil),    // 26: swarmd.v1.AgentStateChangedEvent
-	(*AgentOutputEvent)(nil),          // 27: swarmd.v1.AgentOutputEvent
-	(*ApprovalRequestedEvent)(nil),    // 28: swarmd.v1.ApprovalRequestedEvent
-	(*ApprovalResolvedEvent)(nil),     // 29: swarmd.v1.ApprovalResolvedEvent
-	(*ErrorEvent)(nil),                // 30: swarmd.v1.ErrorEvent
-	(*ResourceViolationEvent)(nil),    // 31: swarmd.v1.ResourceViolationEvent
-	(*PaneContentChangedEvent)(nil),   // 32: swarmd.v1.PaneContentChangedEvent
-	(*GetTranscriptRequest)(nil),      // 33: swarmd.v1.GetTranscriptRequest
-	(*GetTranscriptResponse)(nil),     // 34: swarmd.v1.GetTranscriptResponse
-	(*TranscriptEntry)(nil),           // 35: swarmd.v1.TranscriptEntry
-	(*StreamTranscriptRequest)(nil),   // 36: swarmd.v1.StreamTranscriptRequest
-	(*StreamTranscriptResponse)(nil),  // 37: swarmd.v1.StreamTranscriptResponse
-	(*GetStatusRequest)(nil),          // 38: swarmd.v1.GetStatusRequest
-	(*GetStatusResponse)(nil),         // 39: swarmd.v1.GetStatusResponse
-	(*DaemonStatus)(nil),              // 40: swarmd.v1.DaemonStatus
-	(*ResourceUsage)(nil),             // 41: swarmd.v1.ResourceUsage
-	(*HealthStatus)(nil),              // 42: swarmd.v1.HealthStatus
-	(*HealthCheck)(nil),               // 43: swarmd.v1.HealthCheck
-	(*PingRequest)(nil),               // 44: swarmd.v1.PingRequest
-	(*PingResponse)(nil),              // 45: swarmd.v1.PingResponse
-	nil,                               // 46: swarmd.v1.SpawnAgentRequest.EnvEntry
-	nil,                               // 47: swarmd.v1.TranscriptEntry.MetadataEntry
-	(*durationpb.Duration)(nil),       // 48: google.protobuf.Duration
-	(*timestamppb.Timestamp)(nil),     // 49: google.protobuf.Timestamp
+	(ResourceLimitAction)(0),              // 0: swarmd.v1.ResourceLimitAction
+	(AgentState)(0),                       // 1: swarmd.v1.AgentState
+	(EventType)(0),                        // 2: swarmd.v1.EventType
+	(ResourceType)(0),                     // 3: swarmd.v1.ResourceType
+	(TranscriptEntryType)(0),              // 4: swarmd.v1.TranscriptEntryType
+	(Health)(0),                           // 5: swarmd.v1.Health
+	(*SpawnAgentRequest)(nil),             // 6: swarmd.v1.SpawnAgentRequest
+	(*ResourceLimits)(nil),                // 7: swarmd.v1.ResourceLimits
+	(*SpawnAgentResponse)(nil),            // 8: swarmd.v1.SpawnAgentResponse
+	(*KillAgentRequest)(nil),              // 9: swarmd.v1.KillAgentRequest
+	(*KillAgentResponse)(nil),             // 10: swarmd.v1.KillAgentResponse
+	(*SendInputRequest)(nil),              // 11: swarmd.v1.SendInputRequest
+	(*SendInputResponse)(nil),             // 12: swarmd.v1.SendInputResponse
+	(*ListAgentsRequest)(nil),             // 13: swarmd.v1.ListAgentsRequest
+	(*ListAgentsResponse)(nil),            // 14: swarmd.v1.ListAgentsResponse
+	(*GetAgentRequest)(nil),               // 15: swarmd.v1.GetAgentRequest
+	(*GetAgentResponse)(nil),              // 16: swarmd.v1.GetAgentResponse
+	(*Agent)(nil),                         // 17: swarmd.v1.Agent
+	(*AgentResourceUsage)(nil),            // 18: swarmd.v1.AgentResourceUsage
+	(*CapturePaneRequest)(nil),            // 19: swarmd.v1.CapturePaneRequest
+	(*CapturePaneResponse)(nil),           // 20: swarmd.v1.CapturePaneResponse
+	(*StreamPaneUpdatesRequest)(nil),      // 21: swarmd.v1.StreamPaneUpdatesRequest
+	(*StreamPaneUpdatesResponse)(nil),     // 22: swarmd.v1.StreamPaneUpdatesResponse
+	(*StreamWorkspaceUpdatesRequest)(nil), // 23: swarmd.v1.StreamWorkspaceUpdatesRequest
+	(*ResizePaneRequest)(nil),             // 24: swarmd.v1.ResizePaneRequest
+	(*ResizePaneResponse)(nil),            // 25: swarmd.v1.ResizePaneResponse
+	(*SetPaneLayoutRequest)(nil),          // 26: swarmd.v1.SetPaneLayoutRequest
+	(*SetPaneLayoutResponse)(nil),         // 27: swarmd.v1.SetPaneLayoutResponse
+	(*StreamEventsRequest)(nil),           // 28: swarmd.v1.StreamEventsRequest
+	(*StreamEventsResponse)(nil),          // 29: swarmd.v1.StreamEventsResponse
+	(*Event)(nil),                         // 30: swarmd.v1.Event
+	(*AgentStateChangedEvent)(nil),        // 31: swarmd.v1.AgentStateChangedEvent
+	(*AgentOutputEvent)(nil),              // 32: swarmd.v1.AgentOutputEvent
+	(*ApprovalRequestedEvent)(nil),        // 33: swarmd.v1.ApprovalRequestedEvent
+	(*ApprovalResolvedEvent)(nil),         // 34: swarmd.v1.ApprovalResolvedEvent
+	(*ErrorEvent)(nil),                    // 35: swarmd.v1.ErrorEvent
+	(*ResourceViolationEvent)(nil),        // 36: swarmd.v1.ResourceViolationEvent
+	(*PaneContentChangedEvent)(nil),       // 37: swarmd.v1.PaneContentChangedEvent
+	(*GetTranscriptRequest)(nil),          // 38: swarmd.v1.GetTranscriptRequest
+	(*GetTranscriptResponse)(nil),         // 39: swarmd.v1.GetTranscriptResponse
+	(*TranscriptEntry)(nil),               // 40: swarmd.v1.TranscriptEntry
+	(*StreamTranscriptRequest)(nil),       // 41: swarmd.v1.StreamTranscriptRequest
+	(*StreamTranscriptResponse)(nil),      // 42: swarmd.v1.StreamTranscriptResponse
+	(*CompactTranscriptsRequest)(nil),     // 43: swarmd.v1.CompactTranscriptsRequest
+	(*CompactTranscriptsResponse)(nil),    // 44: swarmd.v1.CompactTranscriptsResponse
+	(*GetStatusRequest)(nil),              // 45: swarmd.v1.GetStatusRequest
+	(*GetStatusResponse)(nil),             // 46: swarmd.v1.GetStatusResponse
+	(*DaemonStatus)(nil),                  // 47: swarmd.v1.DaemonStatus
+	(*ResourceUsage)(nil),                 // 48: swarmd.v1.ResourceUsage
+	(*HealthStatus)(nil),                  // 49: swarmd.v1.HealthStatus
+	(*HealthCheck)(nil),                   // 50: swarmd.v1.HealthCheck
+	(*PingRequest)(nil),                   // 51: swarmd.v1.PingRequest
+	(*PingResponse)(nil),                  // 52: swarmd.v1.PingResponse
+	(*DumpStateRequest)(nil),              // 53: swarmd.v1.DumpStateRequest
+	(*DumpStateResponse)(nil),             // 54: swarmd.v1.DumpStateResponse
+	(*BuildInfo)(nil),                     // 55: swarmd.v1.BuildInfo
+	(*AgentDebugSummary)(nil),             // 56: swarmd.v1.AgentDebugSummary
+	(*RateLimiterDebug)(nil),              // 57: swarmd.v1.RateLimiterDebug
+	(*RateLimitCounter)(nil),              // 58: swarmd.v1.RateLimitCounter
+	(*ReloadConfigRequest)(nil),           // 59: swarmd.v1.ReloadConfigRequest
+	(*ReloadConfigResponse)(nil),          // 60: swarmd.v1.ReloadConfigResponse
+	nil,                                   // 61: swarmd.v1.SpawnAgentRequest.EnvEntry
+	nil,                                   // 62: swarmd.v1.StreamWorkspaceUpdatesRequest.LastKnownHashesEntry
+	nil,                                   // 63: swarmd.v1.TranscriptEntry.MetadataEntry
+	(*durationpb.Duration)(nil),           // 64: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),         // 65: google.protobuf.Timestamp
 }
 var file_swarmd_v1_swarmd_proto_depIdxs = []int32{
-	46, // 0: swarmd.v1.SpawnAgentRequest.env:type_name -> swarmd.v1.SpawnAgentRequest.EnvEntry
+	61, // 0: swarmd.v1.SpawnAgentRequest.env:type_name -> swarmd.v1.SpawnAgentRequest.EnvEntry
 	7,  // 1: swarmd.v1.SpawnAgentRequest.resource_limits:type_name -> swarmd.v1.ResourceLimits
 	0,  // 2: swarmd.v1.ResourceLimits.action:type_name -> swarmd.v1.ResourceLimitAction
-	48, // 3: swarmd.v1.ResourceLimits.grace_period:type_name -> google.protobuf.Duration
+	64, // 3: swarmd.v1.ResourceLimits.grace_period:type_name -> google.protobuf.Duration
 	17, // 4: swarmd.v1.SpawnAgentResponse.agent:type_name -> swarmd.v1.Agent
-	48, // 5: swarmd.v1.KillAgentRequest.grace_period:type_name -> google.protobuf.Duration
+	64, // 5: swarmd.v1.KillAgentRequest.grace_period:type_name -> google.protobuf.Duration
 	1,  // 6: swarmd.v1.ListAgentsRequest.states:type_name -> swarmd.v1.AgentState
 	17, // 7: swarmd.v1.ListAgentsResponse.agents:type_name -> swarmd.v1.Agent
-	17, // 8: swarmd.v1.GetAgentResponse.agent:type_name -> swarmd.v1.Agent
-	1,  // 9: swarmd.v1.Agent.state:type_name -> swarmd.v1.AgentState
-	49, // 10: swarmd.v1.Agent.spawned_at:type_name -> google.protobuf.Timestamp
-	49, // 11: swarmd.v1.Agent.last_activity_at:type_name -> google.protobuf.Timestamp
-	7,  // 12: swarmd.v1.Agent.resource_limits:type_name -> swarmd.v1.ResourceLimits
-	18, // 13: swarmd.v1.Agent.resource_usage:type_name -> swarmd.v1.AgentResourceUsage
-	49, // 14: swarmd.v1.AgentResourceUsage.measured_at:type_name -> google.protobuf.Timestamp
-	49, // 15: swarmd.v1.CapturePaneResponse.captured_at:type_name -> google.protobuf.Timestamp
-	48, // 16: swarmd.v1.StreamPaneUpdatesRequest.min_interval:type_name -> google.protobuf.Duration
-	49, // 17: swarmd.v1.StreamPaneUpdatesResponse.timestamp:type_name -> google.protobuf.Timestamp
-	1,  // 18: swarmd.v1.StreamPaneUpdatesResponse.detected_state:type_name -> swarmd.v1.AgentState
-	2,  // 19: swarmd.v1.StreamEventsRequest.types:type_name -> swarmd.v1.EventType
-	25, // 20: swarmd.v1.StreamEventsResponse.event:type_name -> swarmd.v1.Event
-	2,  // 21: swarmd.v1.Event.type:type_name -> swarmd.v1.EventType
-	49, // 22: swarmd.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
-	26, // 23: swarmd.v1.Event.agent_state_changed:type_name -> swarmd.v1.AgentStateChangedEvent
-	27, // 24: swarmd.v1.Event.agent_output:type_name -> swarmd.v1.AgentOutputEvent
-	28, // 25: swarmd.v1.Event.approval_requested:type_name -> swarmd.v1.ApprovalRequestedEvent
-	29, // 26: swarmd.v1.Event.approval_resolved:type_name -> swarmd.v1.ApprovalResolvedEvent
-	30, // 27: swarmd.v1.Event.error:type_name -> swarmd.v1.ErrorEvent
-	32, // 28: swarmd.v1.Event.pane_content_changed:type_name -> swarmd.v1.PaneContentChangedEvent
-	31, // 29: swarmd.v1.Event.resource_violation:type_name -> swarmd.v1.ResourceViolationEvent
-	1,  // 30: swarmd.v1.AgentStateChangedEvent.previous_state:type_name -> swarmd.v1.AgentState
-	1,  // 31: swarmd.v1.AgentStateChangedEvent.new_state:type_name -> swarmd.v1.AgentState
-	3,  // 32: swarmd.v1.ResourceViolationEvent.resource_type:type_name -> swarmd.v1.ResourceType
-	0,  // 33: swarmd.v1.ResourceViolationEvent.action_taken:type_name -> swarmd.v1.ResourceLimitAction
-	49, // 34: swarmd.v1.GetTranscriptRequest.start_time:type_name -> google.protobuf.Timestamp
-	49, // 35: swarmd.v1.GetTranscriptRequest.end_time:type_name -> google.protobuf.Timestamp
-	35, // 36: swarmd.v1.GetTranscriptResponse.entries:type_name -> swarmd.v1.TranscriptEntry
-	49, // 37: swarmd.v1.TranscriptEntry.timestamp:type_name -> google.protobuf.Timestamp
-	4,  // 38: swarmd.v1.TranscriptEntry.type:type_name -> swarmd.v1.TranscriptEntryType
-	47, // 39: swarmd.v1.TranscriptEntry.metadata:type_name -> swarmd.v1.TranscriptEntry.MetadataEntry
-	35, // 40: swarmd.v1.StreamTranscriptResponse.entries:type_name -> swarmd.v1.TranscriptEntry
-	40, // 41: swarmd.v1.GetStatusResponse.status:type_name -> swarmd.v1.DaemonStatus
-	49, // 42: swarmd.v1.DaemonStatus.started_at:type_name -> google.protobuf.Timestamp
-	48, // 43: swarmd.v1.DaemonStatus.uptime:type_name -> google.protobuf.Duration
-	41, // 44: swarmd.v1.DaemonStatus.resources:type_name -> swarmd.v1.ResourceUsage
-	42, // 45: swarmd.v1.DaemonStatus.health:type_name -> swarmd.v1.HealthStatus
-	5,  // 46: swarmd.v1.HealthStatus.health:type_name -> swarmd.v1.Health
-	43, // 47: swarmd.v1.HealthStatus.checks:type_name -> swarmd.v1.HealthCheck
-	5,  // 48: swarmd.v1.HealthCheck.health:type_name -> swarmd.v1.Health
-	49, // 49: swarmd.v1.HealthCheck.last_check:type_name -> google.protobuf.Timestamp
-	49, // 50: swarmd.v1.PingResponse.timestamp:type_name -> google.protobuf.Timestamp
-	6,  // 51: swarmd.v1.SwarmdService.SpawnAgent:input_type -> swarmd.v1.SpawnAgentRequest
-	9,  // 52: swarmd.v1.SwarmdService.KillAgent:input_type -> swarmd.v1.KillAgentRequest
-	11, // 53: swarmd.v1.SwarmdService.SendInput:input_type -> swarmd.v1.SendInputRequest
-	13, // 54: swarmd.v1.SwarmdService.ListAgents:input_type -> swarmd.v1.ListAgentsRequest
-	15, // 55: swarmd.v1.SwarmdService.GetAgent:input_type -> swarmd.v1.GetAgentRequest
-	19, // 56: swarmd.v1.SwarmdService.CapturePane:input_type -> swarmd.v1.CapturePaneRequest
-	21, // 57: swarmd.v1.SwarmdService.StreamPaneUpdates:input_type -> swarmd.v1.StreamPaneUpdatesRequest
-	23, // 58: swarmd.v1.SwarmdService.StreamEvents:input_type -> swarmd.v1.StreamEventsRequest
-	33, // 59: swarmd.v1.SwarmdService.GetTranscript:input_type -> swarmd.v1.GetTranscriptRequest
-	36, // 60: swarmd.v1.SwarmdService.StreamTranscript:input_type -> swarmd.v1.StreamTranscriptRequest
-	38, // 61: swarmd.v1.SwarmdService.GetStatus:input_type -> swarmd.v1.GetStatusRequest
-	44, // 62: swarmd.v1.SwarmdService.Ping:input_type -> swarmd.v1.PingRequest
-	8,  // 63: swarmd.v1.SwarmdService.SpawnAgent:output_type -> swarmd.v1.SpawnAgentResponse
-	10, // 64: swarmd.v1.SwarmdService.KillAgent:output_type -> swarmd.v1.KillAgentResponse
-	12, // 65: swarmd.v1.SwarmdService.SendInput:output_type -> swarmd.v1.SendInputResponse
-	14, // 66: swarmd.v1.SwarmdService.ListAgents:output_type -> swarmd.v1.ListAgentsResponse
-	16, // 67: swarmd.v1.SwarmdService.GetAgent:output_type -> swarmd.v1.GetAgentResponse
-	20, // 68: swarmd.v1.SwarmdService.CapturePane:output_type -> swarmd.v1.CapturePaneResponse
-	22, // 69: swarmd.v1.SwarmdService.StreamPaneUpdates:output_type -> swarmd.v1.StreamPaneUpdatesResponse
-	24, // 70: swarmd.v1.SwarmdService.StreamEvents:output_type -> swarmd.v1.StreamEventsResponse
-	34, // 71: swarmd.v1.SwarmdService.GetTranscript:output_type -> swarmd.v1.GetTranscriptResponse
-	37, // 72: swarmd.v1.SwarmdService.StreamTranscript:output_type -> swarmd.v1.StreamTranscriptResponse
-	39, // 73: swarmd.v1.SwarmdService.GetStatus:output_type -> swarmd.v1.GetStatusResponse
-	45, // 74: swarmd.v1.SwarmdService.Ping:output_type -> swarmd.v1.PingResponse
-	63, // [63:75] is the sub-list for method output_type
-	51, // [51:63] is the sub-list for method input_type
-	51, // [51:51] is the sub-list for extension type_name
-	51, // [51:51] is the sub-list for extension extendee
-	0,  // [0:51] is the sub-list for field type_name
+	1,  // 8: swarmd.v1.GetAgentRequest.wait_for_state_other_than:type_name -> swarmd.v1.AgentState
+	64, // 9: swarmd.v1.GetAgentRequest.max_wait:type_name -> google.protobuf.Duration
+	17, // 10: swarmd.v1.GetAgentResponse.agent:type_name -> swarmd.v1.Agent
+	1,  // 11: swarmd.v1.Agent.state:type_name -> swarmd.v1.AgentState
+	65, // 12: swarmd.v1.Agent.spawned_at:type_name -> google.protobuf.Timestamp
+	65, // 13: swarmd.v1.Agent.last_activity_at:type_name -> google.protobuf.Timestamp
+	7,  // 14: swarmd.v1.Agent.resource_limits:type_name -> swarmd.v1.ResourceLimits
+	18, // 15: swarmd.v1.Agent.resource_usage:type_name -> swarmd.v1.AgentResourceUsage
+	65, // 16: swarmd.v1.AgentResourceUsage.measured_at:type_name -> google.protobuf.Timestamp
+	64, // 17: swarmd.v1.CapturePaneRequest.max_wait:type_name -> google.protobuf.Duration
+	65, // 18: swarmd.v1.CapturePaneResponse.captured_at:type_name -> google.protobuf.Timestamp
+	64, // 19: swarmd.v1.StreamPaneUpdatesRequest.min_interval:type_name -> google.protobuf.Duration
+	64, // 20: swarmd.v1.StreamPaneUpdatesRequest.max_interval:type_name -> google.protobuf.Duration
+	65, // 21: swarmd.v1.StreamPaneUpdatesResponse.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 22: swarmd.v1.StreamPaneUpdatesResponse.detected_state:type_name -> swarmd.v1.AgentState
+	64, // 23: swarmd.v1.StreamPaneUpdatesResponse.poll_interval:type_name -> google.protobuf.Duration
+	64, // 24: swarmd.v1.StreamWorkspaceUpdatesRequest.min_interval:type_name -> google.protobuf.Duration
+	62, // 25: swarmd.v1.StreamWorkspaceUpdatesRequest.last_known_hashes:type_name -> swarmd.v1.StreamWorkspaceUpdatesRequest.LastKnownHashesEntry
+	64, // 26: swarmd.v1.StreamWorkspaceUpdatesRequest.max_interval:type_name -> google.protobuf.Duration
+	2,  // 27: swarmd.v1.StreamEventsRequest.types:type_name -> swarmd.v1.EventType
+	30, // 28: swarmd.v1.StreamEventsResponse.event:type_name -> swarmd.v1.Event
+	2,  // 29: swarmd.v1.Event.type:type_name -> swarmd.v1.EventType
+	65, // 30: swarmd.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	31, // 31: swarmd.v1.Event.agent_state_changed:type_name -> swarmd.v1.AgentStateChangedEvent
+	32, // 32: swarmd.v1.Event.agent_output:type_name -> swarmd.v1.AgentOutputEvent
+	33, // 33: swarmd.v1.Event.approval_requested:type_name -> swarmd.v1.ApprovalRequestedEvent
+	34, // 34: swarmd.v1.Event.approval_resolved:type_name -> swarmd.v1.ApprovalResolvedEvent
+	35, // 35: swarmd.v1.Event.error:type_name -> swarmd.v1.ErrorEvent
+	37, // 36: swarmd.v1.Event.pane_content_changed:type_name -> swarmd.v1.PaneContentChangedEvent
+	36, // 37: swarmd.v1.Event.resource_violation:type_name -> swarmd.v1.ResourceViolationEvent
+	1,  // 38: swarmd.v1.AgentStateChangedEvent.previous_state:type_name -> swarmd.v1.AgentState
+	1,  // 39: swarmd.v1.AgentStateChangedEvent.new_state:type_name -> swarmd.v1.AgentState
+	3,  // 40: swarmd.v1.ResourceViolationEvent.resource_type:type_name -> swarmd.v1.ResourceType
+	0,  // 41: swarmd.v1.ResourceViolationEvent.action_taken:type_name -> swarmd.v1.ResourceLimitAction
+	65, // 42: swarmd.v1.GetTranscriptRequest.start_time:type_name -> google.protobuf.Timestamp
+	65, // 43: swarmd.v1.GetTranscriptRequest.end_time:type_name -> google.protobuf.Timestamp
+	40, // 44: swarmd.v1.GetTranscriptResponse.entries:type_name -> swarmd.v1.TranscriptEntry
+	65, // 45: swarmd.v1.TranscriptEntry.timestamp:type_name -> google.protobuf.Timestamp
+	4,  // 46: swarmd.v1.TranscriptEntry.type:type_name -> swarmd.v1.TranscriptEntryType
+	63, // 47: swarmd.v1.TranscriptEntry.metadata:type_name -> swarmd.v1.TranscriptEntry.MetadataEntry
+	40, // 48: swarmd.v1.StreamTranscriptResponse.entries:type_name -> swarmd.v1.TranscriptEntry
+	64, // 49: swarmd.v1.CompactTranscriptsRequest.older_than:type_name -> google.protobuf.Duration
+	47, // 50: swarmd.v1.GetStatusResponse.status:type_name -> swarmd.v1.DaemonStatus
+	65, // 51: swarmd.v1.DaemonStatus.started_at:type_name -> google.protobuf.Timestamp
+	64, // 52: swarmd.v1.DaemonStatus.uptime:type_name -> google.protobuf.Duration
+	48, // 53: swarmd.v1.DaemonStatus.resources:type_name -> swarmd.v1.ResourceUsage
+	49, // 54: swarmd.v1.DaemonStatus.health:type_name -> swarmd.v1.HealthStatus
+	5,  // 55: swarmd.v1.HealthStatus.health:type_name -> swarmd.v1.Health
+	50, // 56: swarmd.v1.HealthStatus.checks:type_name -> swarmd.v1.HealthCheck
+	5,  // 57: swarmd.v1.HealthCheck.health:type_name -> swarmd.v1.Health
+	65, // 58: swarmd.v1.HealthCheck.last_check:type_name -> google.protobuf.Timestamp
+	65, // 59: swarmd.v1.PingResponse.timestamp:type_name -> google.protobuf.Timestamp
+	65, // 60: swarmd.v1.DumpStateResponse.captured_at:type_name -> google.protobuf.Timestamp
+	55, // 61: swarmd.v1.DumpStateResponse.build:type_name -> swarmd.v1.BuildInfo
+	64, // 62: swarmd.v1.DumpStateResponse.uptime:type_name -> google.protobuf.Duration
+	56, // 63: swarmd.v1.DumpStateResponse.agents:type_name -> swarmd.v1.AgentDebugSummary
+	49, // 64: swarmd.v1.DumpStateResponse.health:type_name -> swarmd.v1.HealthStatus
+	57, // 65: swarmd.v1.DumpStateResponse.rate_limiter:type_name -> swarmd.v1.RateLimiterDebug
+	1,  // 66: swarmd.v1.AgentDebugSummary.state:type_name -> swarmd.v1.AgentState
+	65, // 67: swarmd.v1.AgentDebugSummary.last_activity_at:type_name -> google.protobuf.Timestamp
+	58, // 68: swarmd.v1.RateLimiterDebug.methods:type_name -> swarmd.v1.RateLimitCounter
+	58, // 69: swarmd.v1.RateLimiterDebug.global:type_name -> swarmd.v1.RateLimitCounter
+	6,  // 70: swarmd.v1.SwarmdService.SpawnAgent:input_type -> swarmd.v1.SpawnAgentRequest
+	9,  // 71: swarmd.v1.SwarmdService.KillAgent:input_type -> swarmd.v1.KillAgentRequest
+	11, // 72: swarmd.v1.SwarmdService.SendInput:input_type -> swarmd.v1.SendInputRequest
+	13, // 73: swarmd.v1.SwarmdService.ListAgents:input_type -> swarmd.v1.ListAgentsRequest
+	15, // 74: swarmd.v1.SwarmdService.GetAgent:input_type -> swarmd.v1.GetAgentRequest
+	19, // 75: swarmd.v1.SwarmdService.CapturePane:input_type -> swarmd.v1.CapturePaneRequest
+	21, // 76: swarmd.v1.SwarmdService.StreamPaneUpdates:input_type -> swarmd.v1.StreamPaneUpdatesRequest
+	23, // 77: swarmd.v1.SwarmdService.StreamWorkspaceUpdates:input_type -> swarmd.v1.StreamWorkspaceUpdatesRequest
+	24, // 78: swarmd.v1.SwarmdService.ResizePane:input_type -> swarmd.v1.ResizePaneRequest
+	26, // 79: swarmd.v1.SwarmdService.SetPaneLayout:input_type -> swarmd.v1.SetPaneLayoutRequest
+	28, // 80: swarmd.v1.SwarmdService.StreamEvents:input_type -> swarmd.v1.StreamEventsRequest
+	38, // 81: swarmd.v1.SwarmdService.GetTranscript:input_type -> swarmd.v1.GetTranscriptRequest
+	41, // 82: swarmd.v1.SwarmdService.StreamTranscript:input_type -> swarmd.v1.StreamTranscriptRequest
+	43, // 83: swarmd.v1.SwarmdService.CompactTranscripts:input_type -> swarmd.v1.CompactTranscriptsRequest
+	45, // 84: swarmd.v1.SwarmdService.GetStatus:input_type -> swarmd.v1.GetStatusRequest
+	51, // 85: swarmd.v1.SwarmdService.Ping:input_type -> swarmd.v1.PingRequest
+	53, // 86: swarmd.v1.SwarmdService.DumpState:input_type -> swarmd.v1.DumpStateRequest
+	59, // 87: swarmd.v1.SwarmdService.ReloadConfig:input_type -> swarmd.v1.ReloadConfigRequest
+	8,  // 88: swarmd.v1.SwarmdService.SpawnAgent:output_type -> swarmd.v1.SpawnAgentResponse
+	10, // 89: swarmd.v1.SwarmdService.KillAgent:output_type -> swarmd.v1.KillAgentResponse
+	12, // 90: swarmd.v1.SwarmdService.SendInput:output_type -> swarmd.v1.SendInputResponse
+	14, // 91: swarmd.v1.SwarmdService.ListAgents:output_type -> swarmd.v1.ListAgentsResponse
+	16, // 92: swarmd.v1.SwarmdService.GetAgent:output_type -> swarmd.v1.GetAgentResponse
+	20, // 93: swarmd.v1.SwarmdService.CapturePane:output_type -> swarmd.v1.CapturePaneResponse
+	22, // 94: swarmd.v1.SwarmdService.StreamPaneUpdates:output_type -> swarmd.v1.StreamPaneUpdatesResponse
+	22, // 95: swarmd.v1.SwarmdService.StreamWorkspaceUpdates:output_type -> swarmd.v1.StreamPaneUpdatesResponse
+	25, // 96: swarmd.v1.SwarmdService.ResizePane:output_type -> swarmd.v1.ResizePaneResponse
+	27, // 97: swarmd.v1.SwarmdService.SetPaneLayout:output_type -> swarmd.v1.SetPaneLayoutResponse
+	29, // 98: swarmd.v1.SwarmdService.StreamEvents:output_type -> swarmd.v1.StreamEventsResponse
+	39, // 99: swarmd.v1.SwarmdService.GetTranscript:output_type -> swarmd.v1.GetTranscriptResponse
+	42, // 100: swarmd.v1.SwarmdService.StreamTranscript:output_type -> swarmd.v1.StreamTranscriptResponse
+	44, // 101: swarmd.v1.SwarmdService.CompactTranscripts:output_type -> swarmd.v1.CompactTranscriptsResponse
+	46, // 102: swarmd.v1.SwarmdService.GetStatus:output_type -> swarmd.v1.GetStatusResponse
+	52, // 103: swarmd.v1.SwarmdService.Ping:output_type -> swarmd.v1.PingResponse
+	54, // 104: swarmd.v1.SwarmdService.DumpState:output_type -> swarmd.v1.DumpStateResponse
+	60, // 105: swarmd.v1.SwarmdService.ReloadConfig:output_type -> swarmd.v1.ReloadConfigResponse
+	88, // [88:106] is the sub-list for method output_type
+	70, // [70:88] is the sub-list for method input_type
+	70, // [70:70] is the sub-list for extension type_name
+	70, // [70:70] is the sub-list for extension extendee
+	0,  // [0:70] is the sub-list for field type_name
 }
 
 func init() { file_swarmd_v1_swarmd_proto_init() }
@@ -3583,7 +5011,7 @@ func file_swarmd_v1_swarmd_proto_init() {
 	if File_swarmd_v1_swarmd_proto != nil {
 		return
 	}
-	file_swarmd_v1_swarmd_proto_msgTypes[19].OneofWrappers = []any{
+	file_swarmd_v1_swarmd_proto_msgTypes[24].OneofWrappers = []any{
 		(*Event_AgentStateChanged)(nil),
 		(*Event_AgentOutput)(nil),
 		(*Event_ApprovalRequested)(nil),
@@ -3598,7 +5026,7 @@ func file_swarmd_v1_swarmd_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_swarmd_v1_swarmd_proto_rawDesc), len(file_swarmd_v1_swarmd_proto_rawDesc)),
 			NumEnums:      6,
-			NumMessages:   42,
+			NumMessages:   58,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -22,18 +22,24 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	SwarmdService_SpawnAgent_FullMethodName        = "/swarmd.v1.SwarmdService/SpawnAgent"
-	SwarmdService_KillAgent_FullMethodName         = "/swarmd.v1.SwarmdService/KillAgent"
-	SwarmdService_SendInput_FullMethodName         = "/swarmd.v1.SwarmdService/SendInput"
-	SwarmdService_ListAgents_FullMethodName        = "/swarmd.v1.SwarmdService/ListAgents"
-	SwarmdService_GetAgent_FullMethodName          = "/swarmd.v1.SwarmdService/GetAgent"
-	SwarmdService_CapturePane_FullMethodName       = "/swarmd.v1.SwarmdService/CapturePane"
-	SwarmdService_StreamPaneUpdates_FullMethodName = "/swarmd.v1.SwarmdService/StreamPaneUpdates"
-	SwarmdService_StreamEvents_FullMethodName      = "/swarmd.v1.SwarmdService/StreamEvents"
-	SwarmdService_GetTranscript_FullMethodName     = "/swarmd.v1.SwarmdService/GetTranscript"
-	SwarmdService_StreamTranscript_FullMethodName  = "/swarmd.v1.SwarmdService/StreamTranscript"
-	SwarmdService_GetStatus_FullMethodName         = "/swarmd.v1.SwarmdService/GetStatus"
-	SwarmdService_Ping_FullMethodName              = "/swarmd.v1.SwarmdService/Ping"
+	SwarmdService_SpawnAgent_FullMethodName             = "/swarmd.v1.SwarmdService/SpawnAgent"
+	SwarmdService_KillAgent_FullMethodName              = "/swarmd.v1.SwarmdService/KillAgent"
+	SwarmdService_SendInput_FullMethodName              = "/swarmd.v1.SwarmdService/SendInput"
+	SwarmdService_ListAgents_FullMethodName             = "/swarmd.v1.SwarmdService/ListAgents"
+	SwarmdService_GetAgent_FullMethodName               = "/swarmd.v1.SwarmdService/GetAgent"
+	SwarmdService_CapturePane_FullMethodName            = "/swarmd.v1.SwarmdService/CapturePane"
+	SwarmdService_StreamPaneUpdates_FullMethodName      = "/swarmd.v1.SwarmdService/StreamPaneUpdates"
+	SwarmdService_StreamWorkspaceUpdates_FullMethodName = "/swarmd.v1.SwarmdService/StreamWorkspaceUpdates"
+	SwarmdService_ResizePane_FullMethodName             = "/swarmd.v1.SwarmdService/ResizePane"
+	SwarmdService_SetPaneLayout_FullMethodName          = "/swarmd.v1.SwarmdService/SetPaneLayout"
+	SwarmdService_StreamEvents_FullMethodName           = "/swarmd.v1.SwarmdService/StreamEvents"
+	SwarmdService_GetTranscript_FullMethodName          = "/swarmd.v1.SwarmdService/GetTranscript"
+	SwarmdService_StreamTranscript_FullMethodName       = "/swarmd.v1.SwarmdService/StreamTranscript"
+	SwarmdService_CompactTranscripts_FullMethodName     = "/swarmd.v1.SwarmdService/CompactTranscripts"
+	SwarmdService_GetStatus_FullMethodName              = "/swarmd.v1.SwarmdService/GetStatus"
+	SwarmdService_Ping_FullMethodName                   = "/swarmd.v1.SwarmdService/Ping"
+	SwarmdService_DumpState_FullMethodName              = "/swarmd.v1.SwarmdService/DumpState"
+	SwarmdService_ReloadConfig_FullMethodName           = "/swarmd.v1.SwarmdService/ReloadConfig"
 )
 
 // SwarmdServiceClient is the client API for SwarmdService service.
@@ -55,6 +61,15 @@ type SwarmdServiceClient interface {
 	// StreamPaneUpdates streams pane content changes in real-time.
 	// Uses content hashing to only send deltas.
 	StreamPaneUpdates(ctx context.Context, in *StreamPaneUpdatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamPaneUpdatesResponse], error)
+	// StreamWorkspaceUpdates streams the pane changes of a set of agents,
+	// such as a workspace's, over one stream. Agents spawned into the set
+	// join the stream and killed agents leave it.
+	StreamWorkspaceUpdates(ctx context.Context, in *StreamWorkspaceUpdatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamPaneUpdatesResponse], error)
+	// ResizePane sets the size of an agent's pane. Panes split too small
+	// wrap their content, which breaks capture and state detection.
+	ResizePane(ctx context.Context, in *ResizePaneRequest, opts ...grpc.CallOption) (*ResizePaneResponse, error)
+	// SetPaneLayout arranges the panes of a tmux session with a layout.
+	SetPaneLayout(ctx context.Context, in *SetPaneLayoutRequest, opts ...grpc.CallOption) (*SetPaneLayoutResponse, error)
 	// StreamEvents provides a real-time stream of daemon events.
 	// Supports cursor-based replay for missed events.
 	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamEventsResponse], error)
@@ -62,10 +77,21 @@ type SwarmdServiceClient interface {
 	GetTranscript(ctx context.Context, in *GetTranscriptRequest, opts ...grpc.CallOption) (*GetTranscriptResponse, error)
 	// StreamTranscript streams transcript updates in real-time.
 	StreamTranscript(ctx context.Context, in *StreamTranscriptRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTranscriptResponse], error)
+	// CompactTranscripts replaces runs of old OUTPUT entries with a single
+	// SUMMARY entry each, keeping all other entries.
+	CompactTranscripts(ctx context.Context, in *CompactTranscriptsRequest, opts ...grpc.CallOption) (*CompactTranscriptsResponse, error)
 	// GetStatus returns daemon health and resource usage.
 	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
 	// Ping is a simple health check.
 	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	// DumpState returns a sanitized snapshot of the daemon's internal state.
+	// It is disabled unless the daemon is started with a debug token, which
+	// callers send as a bearer token in the authorization metadata.
+	DumpState(ctx context.Context, in *DumpStateRequest, opts ...grpc.CallOption) (*DumpStateResponse, error)
+	// ReloadConfig re-reads the daemon's config file and applies it to the
+	// running daemon. An invalid config is rejected as a whole and the
+	// running config stays active.
+	ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadConfigResponse, error)
 }
 
 type swarmdServiceClient struct {
@@ -155,9 +181,48 @@ func (c *swarmdServiceClient) StreamPaneUpdates(ctx context.Context, in *StreamP
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type SwarmdService_StreamPaneUpdatesClient = grpc.ServerStreamingClient[StreamPaneUpdatesResponse]
 
+func (c *swarmdServiceClient) StreamWorkspaceUpdates(ctx context.Context, in *StreamWorkspaceUpdatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamPaneUpdatesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SwarmdService_ServiceDesc.Streams[1], SwarmdService_StreamWorkspaceUpdates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamWorkspaceUpdatesRequest, StreamPaneUpdatesResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SwarmdService_StreamWorkspaceUpdatesClient = grpc.ServerStreamingClient[StreamPaneUpdatesResponse]
+
+func (c *swarmdServiceClient) ResizePane(ctx context.Context, in *ResizePaneRequest, opts ...grpc.CallOption) (*ResizePaneResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResizePaneResponse)
+	err := c.cc.Invoke(ctx, SwarmdService_ResizePane_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swarmdServiceClient) SetPaneLayout(ctx context.Context, in *SetPaneLayoutRequest, opts ...grpc.CallOption) (*SetPaneLayoutResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetPaneLayoutResponse)
+	err := c.cc.Invoke(ctx, SwarmdService_SetPaneLayout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *swarmdServiceClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamEventsResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &SwarmdService_ServiceDesc.Streams[1], SwarmdService_StreamEvents_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &SwarmdService_ServiceDesc.Streams[2], SwarmdService_StreamEvents_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -186,7 +251,7 @@ func (c *swarmdServiceClient) GetTranscript(ctx context.Context, in *GetTranscri
 
 func (c *swarmdServiceClient) StreamTranscript(ctx context.Context, in *StreamTranscriptRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTranscriptResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &SwarmdService_ServiceDesc.Streams[2], SwarmdService_StreamTranscript_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &SwarmdService_ServiceDesc.Streams[3], SwarmdService_StreamTranscript_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -203,6 +268,16 @@ func (c *swarmdServiceClient) StreamTranscript(ctx context.Context, in *StreamTr
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type SwarmdService_StreamTranscriptClient = grpc.ServerStreamingClient[StreamTranscriptResponse]
 
+func (c *swarmdServiceClient) CompactTranscripts(ctx context.Context, in *CompactTranscriptsRequest, opts ...grpc.CallOption) (*CompactTranscriptsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompactTranscriptsResponse)
+	err := c.cc.Invoke(ctx, SwarmdService_CompactTranscripts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *swarmdServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetStatusResponse)
@@ -223,6 +298,26 @@ func (c *swarmdServiceClient) Ping(ctx context.Context, in *PingRequest, opts ..
 	return out, nil
 }
 
+func (c *swarmdServiceClient) DumpState(ctx context.Context, in *DumpStateRequest, opts ...grpc.CallOption) (*DumpStateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DumpStateResponse)
+	err := c.cc.Invoke(ctx, SwarmdService_DumpState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swarmdServiceClient) ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReloadConfigResponse)
+	err := c.cc.Invoke(ctx, SwarmdService_ReloadConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SwarmdServiceServer is the server API for SwarmdService service.
 // All implementations must embed UnimplementedSwarmdServiceServer
 // for forward compatibility.
@@ -242,6 +337,15 @@ type SwarmdServiceServer interface {
 	// StreamPaneUpdates streams pane content changes in real-time.
 	// Uses content hashing to only send deltas.
 	StreamPaneUpdates(*StreamPaneUpdatesRequest, grpc.ServerStreamingServer[StreamPaneUpdatesResponse]) error
+	// StreamWorkspaceUpdates streams the pane changes of a set of agents,
+	// such as a workspace's, over one stream. Agents spawned into the set
+	// join the stream and killed agents leave it.
+	StreamWorkspaceUpdates(*StreamWorkspaceUpdatesRequest, grpc.ServerStreamingServer[StreamPaneUpdatesResponse]) error
+	// ResizePane sets the size of an agent's pane. Panes split too small
+	// wrap their content, which breaks capture and state detection.
+	ResizePane(context.Context, *ResizePaneRequest) (*ResizePaneResponse, error)
+	// SetPaneLayout arranges the panes of a tmux session with a layout.
+	SetPaneLayout(context.Context, *SetPaneLayoutRequest) (*SetPaneLayoutResponse, error)
 	// StreamEvents provides a real-time stream of daemon events.
 	// Supports cursor-based replay for missed events.
 	StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[StreamEventsResponse]) error
@@ -249,10 +353,21 @@ type SwarmdServiceServer interface {
 	GetTranscript(context.Context, *GetTranscriptRequest) (*GetTranscriptResponse, error)
 	// StreamTranscript streams transcript updates in real-time.
 	StreamTranscript(*StreamTranscriptRequest, grpc.ServerStreamingServer[StreamTranscriptResponse]) error
+	// CompactTranscripts replaces runs of old OUTPUT entries with a single
+	// SUMMARY entry each, keeping all other entries.
+	CompactTranscripts(context.Context, *CompactTranscriptsRequest) (*CompactTranscriptsResponse, error)
 	// GetStatus returns daemon health and resource usage.
 	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
 	// Ping is a simple health check.
 	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	// DumpState returns a sanitized snapshot of the daemon's internal state.
+	// It is disabled unless the daemon is started with a debug token, which
+	// callers send as a bearer token in the authorization metadata.
+	DumpState(context.Context, *DumpStateRequest) (*DumpStateResponse, error)
+	// ReloadConfig re-reads the daemon's config file and applies it to the
+	// running daemon. An invalid config is rejected as a whole and the
+	// running config stays active.
+	ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadConfigResponse, error)
 	mustEmbedUnimplementedSwarmdServiceServer()
 }
 
@@ -284,6 +399,15 @@ func (UnimplementedSwarmdServiceServer) CapturePane(context.Context, *CapturePan
 func (UnimplementedSwarmdServiceServer) StreamPaneUpdates(*StreamPaneUpdatesRequest, grpc.ServerStreamingServer[StreamPaneUpdatesResponse]) error {
 	return status.Error(codes.Unimplemented, "method StreamPaneUpdates not implemented")
 }
+func (UnimplementedSwarmdServiceServer) StreamWorkspaceUpdates(*StreamWorkspaceUpdatesRequest, grpc.ServerStreamingServer[StreamPaneUpdatesResponse]) error {
+	return status.Error(codes.Unimplemented, "method StreamWorkspaceUpdates not implemented")
+}
+func (UnimplementedSwarmdServiceServer) ResizePane(context.Context, *ResizePaneRequest) (*ResizePaneResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResizePane not implemented")
+}
+func (UnimplementedSwarmdServiceServer) SetPaneLayout(context.Context, *SetPaneLayoutRequest) (*SetPaneLayoutResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetPaneLayout not implemented")
+}
 func (UnimplementedSwarmdServiceServer) StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[StreamEventsResponse]) error {
 	return status.Error(codes.Unimplemented, "method StreamEvents not implemented")
 }
@@ -293,12 +417,21 @@ func (UnimplementedSwarmdServiceServer) GetTranscript(context.Context, *GetTrans
 func (UnimplementedSwarmdServiceServer) StreamTranscript(*StreamTranscriptRequest, grpc.ServerStreamingServer[StreamTranscriptResponse]) error {
 	return status.Error(codes.Unimplemented, "method StreamTranscript not implemented")
 }
+func (UnimplementedSwarmdServiceServer) CompactTranscripts(context.Context, *CompactTranscriptsRequest) (*CompactTranscriptsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CompactTranscripts not implemented")
+}
 func (UnimplementedSwarmdServiceServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetStatus not implemented")
 }
 func (UnimplementedSwarmdServiceServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Ping not implemented")
 }
+func (UnimplementedSwarmdServiceServer) DumpState(context.Context, *DumpStateRequest) (*DumpStateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DumpState not implemented")
+}
+func (UnimplementedSwarmdServiceServer) ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReloadConfig not implemented")
+}
 func (UnimplementedSwarmdServiceServer) mustEmbedUnimplementedSwarmdServiceServer() {}
 func (UnimplementedSwarmdServiceServer) testEmbeddedByValue()                       {}
 
@@ -439,6 +572,53 @@ func _SwarmdService_StreamPaneUpdates_Handler(srv interface{}, stream grpc.Serve
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type SwarmdService_StreamPaneUpdatesServer = grpc.ServerStreamingServer[StreamPaneUpdatesResponse]
 
+func _SwarmdService_StreamWorkspaceUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamWorkspaceUpdatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SwarmdServiceServer).StreamWorkspaceUpdates(m, &grpc.GenericServerStream[StreamWorkspaceUpdatesRequest, StreamPaneUpdatesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SwarmdService_StreamWorkspaceUpdatesServer = grpc.ServerStreamingServer[StreamPaneUpdatesResponse]
+
+func _SwarmdService_ResizePane_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResizePaneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwarmdServiceServer).ResizePane(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwarmdService_ResizePane_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwarmdServiceServer).ResizePane(ctx, req.(*ResizePaneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwarmdService_SetPaneLayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPaneLayoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwarmdServiceServer).SetPaneLayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwarmdService_SetPaneLayout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwarmdServiceServer).SetPaneLayout(ctx, req.(*SetPaneLayoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SwarmdService_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(StreamEventsRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -479,6 +659,24 @@ func _SwarmdService_StreamTranscript_Handler(srv interface{}, stream grpc.Server
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type SwarmdService_StreamTranscriptServer = grpc.ServerStreamingServer[StreamTranscriptResponse]
 
+func _SwarmdService_CompactTranscripts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactTranscriptsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwarmdServiceServer).CompactTranscripts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwarmdService_CompactTranscripts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwarmdServiceServer).CompactTranscripts(ctx, req.(*CompactTranscriptsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SwarmdService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetStatusRequest)
 	if err := dec(in); err != nil {
@@ -515,6 +713,42 @@ func _SwarmdService_Ping_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SwarmdService_DumpState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwarmdServiceServer).DumpState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwarmdService_DumpState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwarmdServiceServer).DumpState(ctx, req.(*DumpStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwarmdService_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwarmdServiceServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwarmdService_ReloadConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwarmdServiceServer).ReloadConfig(ctx, req.(*ReloadConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SwarmdService_ServiceDesc is the grpc.ServiceDesc for SwarmdService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -546,10 +780,22 @@ var SwarmdService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CapturePane",
 			Handler:    _SwarmdService_CapturePane_Handler,
 		},
+		{
+			MethodName: "ResizePane",
+			Handler:    _SwarmdService_ResizePane_Handler,
+		},
+		{
+			MethodName: "SetPaneLayout",
+			Handler:    _SwarmdService_SetPaneLayout_Handler,
+		},
 		{
 			MethodName: "GetTranscript",
 			Handler:    _SwarmdService_GetTranscript_Handler,
 		},
+		{
+			MethodName: "CompactTranscripts",
+			Handler:    _SwarmdService_CompactTranscripts_Handler,
+		},
 		{
 			MethodName: "GetStatus",
 			Handler:    _SwarmdService_GetStatus_Handler,
@@ -558,6 +804,14 @@ var SwarmdService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Ping",
 			Handler:    _SwarmdService_Ping_Handler,
 		},
+		{
+			MethodName: "DumpState",
+			Handler:    _SwarmdService_DumpState_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _SwarmdService_ReloadConfig_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -565,6 +819,11 @@ var SwarmdService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _SwarmdService_StreamPaneUpdates_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamWorkspaceUpdates",
+			Handler:       _SwarmdService_StreamWorkspaceUpdates_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "StreamEvents",
 			Handler:       _SwarmdService_StreamEvents_Handler,
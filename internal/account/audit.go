@@ -0,0 +1,22 @@
+package account
+
+import (
+	"github.com/opencode-ai/swarm/internal/audit"
+)
+
+// Audited operation names.
+const (
+	AuditAddAccount    = "add_account"
+	AuditDeleteAccount = "delete_account"
+	AuditSetCooldown   = "set_cooldown"
+	AuditClearCooldown = "clear_cooldown"
+	AuditRotateAccount = "rotate_account"
+)
+
+// WithAuditRecorder records the service's mutating operations. Expired
+// cooldowns cleared by the sweep are bookkeeping and are not recorded.
+func WithAuditRecorder(recorder *audit.Recorder) ServiceOption {
+	return func(s *Service) {
+		s.auditor = recorder
+	}
+}
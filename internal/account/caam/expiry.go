@@ -0,0 +1,121 @@
+package caam
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// expiryKeys lists, per caam provider, the JSON keys its auth files record
+// an access token's expiry under. Keys are matched at any depth, since the
+// CLIs nest tokens differently across versions (claude's .credentials.json
+// keeps them under claudeAiOauth, codex's auth.json under tokens).
+var expiryKeys = map[string][]string{
+	"claude": {"expires_at", "expiresAt"},
+	"codex":  {"expires_at", "expiresAt", "expiry"},
+	"gemini": {"expiry", "expiry_date", "token_expiry", "expires_at"},
+}
+
+// defaultExpiryKeys covers providers caam knows that swarm does not.
+var defaultExpiryKeys = []string{"expires_at", "expiresAt", "expiry", "expiry_date"}
+
+// CredentialExpiry returns the soonest token expiry recorded in the
+// profile's auth files, or nil if none records one. Files that are not
+// JSON or hold no recognizable expiry are skipped.
+func (p *Profile) CredentialExpiry() *time.Time {
+	var soonest *time.Time
+	for _, name := range p.AuthFiles {
+		data, err := os.ReadFile(p.GetAuthFilePath(name))
+		if err != nil {
+			continue
+		}
+		expiry, ok := ParseAuthExpiry(p.Provider, data)
+		if !ok {
+			continue
+		}
+		if soonest == nil || expiry.Before(*soonest) {
+			soonest = &expiry
+		}
+	}
+	return soonest
+}
+
+// ParseAuthExpiry returns the soonest expiry in a caam provider's auth
+// file. Values may be RFC 3339 timestamps, or Unix times in seconds or
+// milliseconds as numbers or strings.
+func ParseAuthExpiry(provider string, data []byte) (time.Time, bool) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return time.Time{}, false
+	}
+
+	keys, ok := expiryKeys[strings.ToLower(provider)]
+	if !ok {
+		keys = defaultExpiryKeys
+	}
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	var soonest time.Time
+	var walk func(value any)
+	walk = func(value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			for key, child := range v {
+				if wanted[key] {
+					if t, ok := parseExpiryValue(child); ok && (soonest.IsZero() || t.Before(soonest)) {
+						soonest = t
+					}
+					continue
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(doc)
+	return soonest, !soonest.IsZero()
+}
+
+// unixMillisThreshold separates Unix seconds from milliseconds: as seconds
+// it is in the year 5138, as milliseconds in 1973.
+const unixMillisThreshold = 1e11
+
+func parseExpiryValue(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case float64:
+		return unixExpiry(v)
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return time.Time{}, false
+		}
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return unixExpiry(n)
+		}
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC(), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func unixExpiry(n float64) (time.Time, bool) {
+	if n <= 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return time.Time{}, false
+	}
+	if n >= unixMillisThreshold {
+		return time.UnixMilli(int64(n)).UTC(), true
+	}
+	return time.Unix(int64(n), 0).UTC(), true
+}
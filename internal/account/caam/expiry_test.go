@@ -0,0 +1,127 @@
+package caam
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAuthExpiry(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		data     string
+		want     time.Time
+		ok       bool
+	}{
+		{
+			name:     "claude oauth in milliseconds",
+			provider: "claude",
+			data:     `{"claudeAiOauth": {"accessToken": "x", "expiresAt": 1791982800000}}`,
+			want:     time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "codex nested RFC 3339",
+			provider: "codex",
+			data:     `{"tokens": {"access_token": "x", "expires_at": "2026-10-14T15:30:00Z"}}`,
+			want:     time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "gemini expiry_date",
+			provider: "gemini",
+			data:     `{"access_token": "x", "expiry_date": 1791986400000}`,
+			want:     time.Date(2026, 10, 14, 14, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "gemini expiry with offset",
+			provider: "gemini",
+			data:     `{"token": {"expiry": "2026-10-14T16:00:00+02:00"}}`,
+			want:     time.Date(2026, 10, 14, 14, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "unix seconds as a string",
+			provider: "codex",
+			data:     `{"expires_at": "1791982800"}`,
+			want:     time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "soonest of several tokens",
+			provider: "claude",
+			data:     `{"accounts": [{"expiresAt": 1791986400000}, {"expiresAt": 1791982800000}]}`,
+			want:     time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "other providers' keys are ignored",
+			provider: "claude",
+			data:     `{"expiry_date": 1791986400000}`,
+		},
+		{
+			name:     "no expiry recorded",
+			provider: "codex",
+			data:     `{"OPENAI_API_KEY": "sk-x"}`,
+		},
+		{
+			name:     "unparseable value",
+			provider: "gemini",
+			data:     `{"expiry": "tomorrow"}`,
+		},
+		{
+			name:     "not JSON",
+			provider: "claude",
+			data:     `token=abc`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseAuthExpiry(tt.provider, []byte(tt.data))
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Fatalf("ParseAuthExpiry() = %s, %v; want %s, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseVaultCredentialExpiry(t *testing.T) {
+	config, err := ParseVault("testdata/vault")
+	if err != nil {
+		t.Fatalf("ParseVault failed: %v", err)
+	}
+
+	want := map[string]time.Time{
+		"alice@example.com": time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC),
+		"bob@example.com":   time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC),
+		"carol@example.com": time.Date(2026, 10, 14, 14, 0, 0, 0, time.UTC),
+	}
+	for _, profile := range config.Profiles {
+		expected, ok := want[profile.Email]
+		if !ok {
+			if profile.ExpiresAt != nil {
+				t.Errorf("%s: expected no expiry, got %s", profile.Email, profile.ExpiresAt)
+			}
+			continue
+		}
+		if profile.ExpiresAt == nil || !profile.ExpiresAt.Equal(expected) {
+			t.Errorf("%s: expected expiry %s, got %v", profile.Email, expected, profile.ExpiresAt)
+		}
+		account := profile.ToSwarmAccount()
+		if account.CredentialExpiresAt == nil || !account.CredentialExpiresAt.Equal(expected) {
+			t.Errorf("%s: expected account expiry %s, got %v", profile.Email, expected, account.CredentialExpiresAt)
+		}
+	}
+
+	profile, err := ParseProfile("testdata/vault", "gemini", "carol@example.com")
+	if err != nil {
+		t.Fatalf("ParseProfile failed: %v", err)
+	}
+	if profile.ExpiresAt == nil || !profile.ExpiresAt.Equal(want["carol@example.com"]) {
+		t.Fatalf("expected ParseProfile to read the expiry, got %v", profile.ExpiresAt)
+	}
+	if _, err := ParseProfile("testdata/vault", "gemini", "nobody@example.com"); err == nil {
+		t.Fatal("expected an error for a missing profile")
+	}
+}
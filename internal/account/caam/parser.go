@@ -55,6 +55,10 @@ type Profile struct {
 
 	// AuthFiles lists the auth file names present in the profile.
 	AuthFiles []string
+
+	// ExpiresAt is the soonest token expiry found in the auth files, if
+	// any records one.
+	ExpiresAt *time.Time
 }
 
 // VaultConfig represents a parsed caam vault.
@@ -110,38 +114,10 @@ func ParseVault(vaultPath string) (*VaultConfig, error) {
 				continue
 			}
 
-			email := profile.Name()
-			profilePath := filepath.Join(providerPath, email)
-
-			p := &Profile{
-				Provider:  providerName,
-				Email:     email,
-				Path:      profilePath,
-				AuthFiles: make([]string, 0),
-			}
-
-			// Parse meta.json if present
-			metaPath := filepath.Join(profilePath, "meta.json")
-			if metaData, err := os.ReadFile(metaPath); err == nil {
-				var meta ProfileMeta
-				if err := json.Unmarshal(metaData, &meta); err == nil {
-					p.Meta = &meta
-				}
-			}
-
-			// List auth files
-			files, err := os.ReadDir(profilePath)
+			p, err := parseProfile(filepath.Join(providerPath, profile.Name()), providerName, profile.Name())
 			if err != nil {
 				continue
 			}
-
-			for _, f := range files {
-				if f.IsDir() || f.Name() == "meta.json" {
-					continue
-				}
-				p.AuthFiles = append(p.AuthFiles, f.Name())
-			}
-
 			config.Profiles = append(config.Profiles, p)
 		}
 	}
@@ -149,6 +125,56 @@ func ParseVault(vaultPath string) (*VaultConfig, error) {
 	return config, nil
 }
 
+// ParseProfile reads a single profile from a caam vault.
+func ParseProfile(vaultPath, provider, email string) (*Profile, error) {
+	profilePath := filepath.Join(vaultPath, provider, email)
+	info, err := os.Stat(profilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("caam profile not found: %s/%s", provider, email)
+		}
+		return nil, fmt.Errorf("failed to access caam profile: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("caam profile path is not a directory: %s/%s", provider, email)
+	}
+	return parseProfile(profilePath, provider, email)
+}
+
+func parseProfile(profilePath, provider, email string) (*Profile, error) {
+	p := &Profile{
+		Provider:  provider,
+		Email:     email,
+		Path:      profilePath,
+		AuthFiles: make([]string, 0),
+	}
+
+	// Parse meta.json if present
+	metaPath := filepath.Join(profilePath, "meta.json")
+	if metaData, err := os.ReadFile(metaPath); err == nil {
+		var meta ProfileMeta
+		if err := json.Unmarshal(metaData, &meta); err == nil {
+			p.Meta = &meta
+		}
+	}
+
+	// List auth files
+	files, err := os.ReadDir(profilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read caam profile: %w", err)
+	}
+
+	for _, f := range files {
+		if f.IsDir() || f.Name() == "meta.json" {
+			continue
+		}
+		p.AuthFiles = append(p.AuthFiles, f.Name())
+	}
+
+	p.ExpiresAt = p.CredentialExpiry()
+	return p, nil
+}
+
 // ToSwarmAccount converts a caam profile to a Swarm Account model.
 func (p *Profile) ToSwarmAccount() *models.Account {
 	provider := mapCaamProvider(p.Provider)
@@ -168,6 +194,11 @@ func (p *Profile) ToSwarmAccount() *models.Account {
 		UpdatedAt:     time.Now().UTC(),
 	}
 
+	if p.ExpiresAt != nil {
+		expiresAt := *p.ExpiresAt
+		account.CredentialExpiresAt = &expiresAt
+	}
+
 	// If we have metadata with backup time, use that as created time
 	if p.Meta != nil && !p.Meta.BackedUpAt.IsZero() {
 		account.CreatedAt = p.Meta.BackedUpAt
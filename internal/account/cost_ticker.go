@@ -0,0 +1,400 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// CostEventTypes are the events a CostTicker reads.
+var CostEventTypes = []models.EventType{
+	models.EventTypeAgentSpawned,
+	models.EventTypeAgentStateChanged,
+	models.EventTypeAgentTerminated,
+	models.EventTypeUsageRecorded,
+}
+
+// CostTicker keeps a running figure of what each agent has cost today: the
+// usage recorded for it by usage.recorded events, and an estimate for the
+// time it has spent working since, at its type's hourly rate. Recorded
+// usage resets the estimate, so the two never cover the same work.
+//
+// Totals reset at midnight in the ticker's time zone, when the closed day's
+// final figures are handed to the rollover hook. Readers never close a day,
+// so a feed can finish reading the events of one first: Roll closes it, as
+// does Handle on an event of a later day. A CostTicker is safe for
+// concurrent use.
+type CostTicker struct {
+	clock    clock.Clock
+	loc      *time.Location
+	rollover func(date string, costs []models.AgentCost)
+
+	mu       sync.Mutex
+	rates    map[models.AgentType]int64
+	dayStart time.Time
+	agents   map[string]*agentTick
+}
+
+// agentTick is one agent's running figures for the current day.
+type agentTick struct {
+	agentType   models.AgentType
+	actualCents int64
+
+	// seen is set once a state of the agent's has been observed.
+	seen       bool
+	terminated bool
+
+	// working is set while the agent works; workingSince is when the part
+	// of its current stretch with no usage recorded for it began.
+	working      bool
+	workingSince time.Time
+
+	// unrecorded is the finished working time since usage was last
+	// recorded.
+	unrecorded time.Duration
+}
+
+// CostTickerOption configures a CostTicker.
+type CostTickerOption func(*CostTicker)
+
+// WithCostClock sets the time source for the running estimate and for
+// midnight.
+func WithCostClock(c clock.Clock) CostTickerOption {
+	return func(t *CostTicker) {
+		t.clock = c
+	}
+}
+
+// WithCostLocation sets the time zone whose midnight resets the totals
+// (default: local time).
+func WithCostLocation(loc *time.Location) CostTickerOption {
+	return func(t *CostTicker) {
+		t.loc = loc
+	}
+}
+
+// WithCostRollover sets a hook called with the final figures of each day
+// the ticker closes. It runs outside the ticker's lock.
+func WithCostRollover(fn func(date string, costs []models.AgentCost)) CostTickerOption {
+	return func(t *CostTicker) {
+		t.rollover = fn
+	}
+}
+
+// NewCostTicker creates a ticker estimating working time at rates cents per
+// hour, by agent type.
+func NewCostTicker(rates map[models.AgentType]int64, opts ...CostTickerOption) *CostTicker {
+	t := &CostTicker{
+		rates:  rates,
+		agents: make(map[string]*agentTick),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.clock = clock.OrReal(t.clock)
+	if t.loc == nil {
+		t.loc = time.Local
+	}
+	t.dayStart = startOfDay(t.clock.Now(), t.loc)
+	return t
+}
+
+// SetRates replaces the hourly rates, such as after a config reload.
+func (t *CostTicker) SetRates(rates map[models.AgentType]int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rates = rates
+}
+
+// DayStart returns the start of the day the ticker is totaling.
+func (t *CostTicker) DayStart() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dayStart
+}
+
+// Attach subscribes the ticker to an in-process event bus.
+func (t *CostTicker) Attach(publisher events.Publisher) error {
+	return publisher.Subscribe("cost-ticker", events.Filter{EventTypes: CostEventTypes}, t.Handle)
+}
+
+// Track registers an existing agent, so its rate is known before any event
+// of its is seen. An agent working now with no state change seen is
+// counted as working since the start of the day.
+func (t *CostTicker) Track(agent *models.Agent) {
+	if agent == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tick := t.tickLocked(agent.ID)
+	tick.agentType = agent.Type
+	if !tick.seen {
+		tick.seen = true
+		if agent.State == models.AgentStateWorking {
+			tick.working = true
+			tick.workingSince = t.dayStart
+		}
+	}
+}
+
+// Handle applies one event; events of other types are ignored. Times are
+// taken from the events, so replaying a day's events rebuilds its figures.
+func (t *CostTicker) Handle(event *models.Event) {
+	if event == nil {
+		return
+	}
+	t.mu.Lock()
+	closed := t.rollLocked(event.Timestamp)
+	at := event.Timestamp
+	if at.Before(t.dayStart) {
+		at = t.dayStart
+	}
+
+	switch event.Type {
+	case models.EventTypeAgentSpawned:
+		var p models.AgentSpawnedPayload
+		_ = json.Unmarshal(event.Payload, &p)
+		tick := t.tickLocked(event.EntityID)
+		if p.Type != "" {
+			tick.agentType = p.Type
+		}
+		tick.seen = true
+	case models.EventTypeAgentStateChanged:
+		var p models.StateChangedPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			break
+		}
+		tick := t.tickLocked(event.EntityID)
+		if !tick.seen && p.OldState == models.AgentStateWorking {
+			// It was already working when the day's events begin.
+			tick.working = true
+			tick.workingSince = t.dayStart
+		}
+		tick.seen = true
+		tick.setWorking(p.NewState == models.AgentStateWorking, at)
+	case models.EventTypeAgentTerminated:
+		tick := t.tickLocked(event.EntityID)
+		tick.seen = true
+		tick.terminated = true
+		tick.setWorking(false, at)
+	case models.EventTypeUsageRecorded:
+		var p models.UsageRecordedPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			break
+		}
+		agentID := p.AgentID
+		if agentID == "" && event.EntityType == models.EntityTypeAgent {
+			agentID = event.EntityID
+		}
+		recordedAt := p.RecordedAt
+		if recordedAt.IsZero() {
+			recordedAt = event.Timestamp
+		}
+		if agentID == "" || recordedAt.Before(t.dayStart) {
+			break
+		}
+		tick := t.tickLocked(agentID)
+		tick.actualCents += p.CostCents
+		tick.unrecorded = 0
+		if tick.working && tick.workingSince.Before(at) {
+			tick.workingSince = at
+		}
+	}
+	t.mu.Unlock()
+	t.emit(closed)
+}
+
+// Roll closes the current day if midnight has passed.
+func (t *CostTicker) Roll() {
+	t.mu.Lock()
+	closed := t.rollLocked(t.clock.Now())
+	t.mu.Unlock()
+	t.emit(closed)
+}
+
+// Cost returns an agent's figures so far today, and false when the ticker
+// has seen nothing of the agent.
+func (t *CostTicker) Cost(agentID string) (models.AgentCost, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tick, ok := t.agents[agentID]
+	if !ok {
+		return models.AgentCost{}, false
+	}
+	return t.costLocked(agentID, tick, t.nowLocked()), true
+}
+
+// Costs returns the figures so far today of every agent seen, by ID.
+func (t *CostTicker) Costs() []models.AgentCost {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.costsLocked(t.nowLocked())
+}
+
+// closedDay is a day's final figures, waiting for the rollover hook.
+type closedDay struct {
+	date  string
+	costs []models.AgentCost
+}
+
+// rollLocked starts the day containing now if the current one is over,
+// returning the closed day's figures as of its midnight.
+func (t *CostTicker) rollLocked(now time.Time) *closedDay {
+	start := startOfDay(now, t.loc)
+	if !start.After(t.dayStart) {
+		return nil
+	}
+	end := t.dayStart.AddDate(0, 0, 1)
+	closed := &closedDay{date: t.dayStart.Format("2006-01-02"), costs: t.costsLocked(end)}
+
+	for id, tick := range t.agents {
+		if tick.terminated {
+			delete(t.agents, id)
+			continue
+		}
+		tick.actualCents = 0
+		tick.unrecorded = 0
+		if tick.working {
+			tick.workingSince = start
+		}
+	}
+	t.dayStart = start
+	return closed
+}
+
+// nowLocked is the current time, held at the end of a day not yet closed.
+func (t *CostTicker) nowLocked() time.Time {
+	now := t.clock.Now()
+	if end := t.dayStart.AddDate(0, 0, 1); now.After(end) {
+		return end
+	}
+	return now
+}
+
+func (t *CostTicker) emit(closed *closedDay) {
+	if closed == nil || t.rollover == nil {
+		return
+	}
+	t.rollover(closed.date, closed.costs)
+}
+
+func (t *CostTicker) tickLocked(agentID string) *agentTick {
+	tick, ok := t.agents[agentID]
+	if !ok {
+		tick = &agentTick{}
+		t.agents[agentID] = tick
+	}
+	return tick
+}
+
+func (t *CostTicker) costsLocked(now time.Time) []models.AgentCost {
+	costs := make([]models.AgentCost, 0, len(t.agents))
+	for id, tick := range t.agents {
+		costs = append(costs, t.costLocked(id, tick, now))
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		return costs[i].AgentID < costs[j].AgentID
+	})
+	return costs
+}
+
+// costLocked reports tick's figures as of now. Partial cents of the
+// estimate are rounded up, as forecasts round them.
+func (t *CostTicker) costLocked(agentID string, tick *agentTick, now time.Time) models.AgentCost {
+	working := tick.unrecorded
+	if tick.working && now.After(tick.workingSince) {
+		working += now.Sub(tick.workingSince)
+	}
+	seconds := int64(working / time.Second)
+	rate := t.rates[tick.agentType]
+	return models.AgentCost{
+		AgentID:     agentID,
+		AgentType:   tick.agentType,
+		Date:        t.dayStart.Format("2006-01-02"),
+		ActualCents: tick.actualCents,
+		Estimated: models.CostEstimate{
+			Cents:            (seconds*rate + 3599) / 3600,
+			WorkingSeconds:   seconds,
+			CostPerHourCents: rate,
+		},
+	}
+}
+
+// setWorking starts or ends a working stretch at at.
+func (tick *agentTick) setWorking(working bool, at time.Time) {
+	switch {
+	case working && !tick.working:
+		tick.workingSince = at
+	case !working && tick.working && at.After(tick.workingSince):
+		tick.unrecorded += at.Sub(tick.workingSince)
+	}
+	tick.working = working
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// CostSources are the repositories a CostTicker is loaded from.
+type CostSources struct {
+	Events *db.EventRepository
+	Agents *db.AgentRepository
+}
+
+// LoadCostTicker replays the day's events from the event log into ticker
+// and tracks the agents that exist, limited to one agent when agentID is
+// set. It returns the ID of the last event read, from which a caller can
+// keep following the log.
+func LoadCostTicker(ctx context.Context, src CostSources, ticker *CostTicker, agentID string) (string, error) {
+	if src.Events == nil || src.Agents == nil {
+		return "", fmt.Errorf("event and agent repositories are required")
+	}
+	since := ticker.DayStart()
+	query := db.EventQuery{Since: &since}
+	if agentID != "" {
+		query.EntityType = entityTypePtr(models.EntityTypeAgent)
+		query.EntityID = &agentID
+	}
+
+	var cursor string
+	it := src.Events.Iterate(ctx, query, 0)
+	for it.Next() {
+		event := it.Event()
+		ticker.Handle(event)
+		cursor = event.ID
+	}
+	if err := it.Err(); err != nil {
+		return "", fmt.Errorf("failed to read events: %w", err)
+	}
+
+	if agentID != "" {
+		agent, err := src.Agents.Get(ctx, agentID)
+		if err != nil {
+			return "", err
+		}
+		ticker.Track(agent)
+		return cursor, nil
+	}
+	agents, err := src.Agents.List(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list agents: %w", err)
+	}
+	for _, agent := range agents {
+		ticker.Track(agent)
+	}
+	return cursor, nil
+}
+
+func entityTypePtr(t models.EntityType) *models.EntityType {
+	return &t
+}
@@ -0,0 +1,181 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func costEvent(t *testing.T, eventType models.EventType, agentID string, at time.Time, payload any) *models.Event {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return &models.Event{
+		Type:       eventType,
+		Timestamp:  at,
+		EntityType: models.EntityTypeAgent,
+		EntityID:   agentID,
+		Payload:    raw,
+	}
+}
+
+func stateEvent(t *testing.T, agentID string, at time.Time, from, to models.AgentState) *models.Event {
+	return costEvent(t, models.EventTypeAgentStateChanged, agentID, at, models.StateChangedPayload{OldState: from, NewState: to})
+}
+
+func usageEvent(t *testing.T, agentID string, at time.Time, cents int64) *models.Event {
+	return costEvent(t, models.EventTypeUsageRecorded, agentID, at, models.UsageRecordedPayload{AgentID: agentID, CostCents: cents, RecordedAt: at})
+}
+
+func TestCostTickerKeepsEstimateApartFromUsage(t *testing.T) {
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	ticker := NewCostTicker(map[models.AgentType]int64{models.AgentTypeClaudeCode: 360},
+		WithCostClock(fake), WithCostLocation(time.UTC))
+	ticker.Track(&models.Agent{ID: "agent-1", Type: models.AgentTypeClaudeCode, State: models.AgentStateIdle})
+
+	ticker.Handle(stateEvent(t, "agent-1", start, models.AgentStateIdle, models.AgentStateWorking))
+	fake.Advance(10 * time.Minute)
+	cost, ok := ticker.Cost("agent-1")
+	if !ok || cost.ActualCents != 0 || cost.Estimated.WorkingSeconds != 600 || cost.Estimated.Cents != 60 {
+		t.Fatalf("expected ten minutes estimated at 360c/h, got %+v (%v)", cost, ok)
+	}
+
+	// Recorded usage covers the work so far; only later work is estimated.
+	ticker.Handle(usageEvent(t, "agent-1", fake.Now(), 45))
+	fake.Advance(5 * time.Minute)
+	cost, _ = ticker.Cost("agent-1")
+	if cost.ActualCents != 45 || cost.Estimated.WorkingSeconds != 300 || cost.Estimated.Cents != 30 {
+		t.Fatalf("expected 45c recorded and five minutes estimated, got %+v", cost)
+	}
+
+	ticker.Handle(stateEvent(t, "agent-1", fake.Now(), models.AgentStateWorking, models.AgentStateIdle))
+	fake.Advance(time.Hour)
+	cost, _ = ticker.Cost("agent-1")
+	if cost.Estimated.WorkingSeconds != 300 {
+		t.Fatalf("expected idle time not estimated, got %+v", cost)
+	}
+
+	if _, ok := ticker.Cost("agent-unknown"); ok {
+		t.Fatalf("expected no figures for an agent never seen")
+	}
+
+	raw, err := json.Marshal(cost)
+	if err != nil {
+		t.Fatalf("marshal cost: %v", err)
+	}
+	for _, want := range []string{`"actual_cents":45`, `"estimated":{"cents":30,"working_seconds":300,"cost_per_hour_cents":360}`} {
+		if !strings.Contains(string(raw), want) {
+			t.Fatalf("expected %s in %s", want, raw)
+		}
+	}
+}
+
+func TestCostTickerConcurrentUse(t *testing.T) {
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	ticker := NewCostTicker(map[models.AgentType]int64{models.AgentTypeCodex: 300},
+		WithCostClock(fake), WithCostLocation(time.UTC))
+
+	publisher := events.NewInMemoryPublisher()
+	if err := ticker.Attach(publisher); err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+
+	const agents, records = 8, 50
+	var wg sync.WaitGroup
+	for i := 0; i < agents; i++ {
+		agentID := "agent-" + string(rune('a'+i))
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			publisher.Publish(context.Background(), stateEvent(t, agentID, start, models.AgentStateIdle, models.AgentStateWorking))
+			for j := 0; j < records; j++ {
+				publisher.Publish(context.Background(), usageEvent(t, agentID, start, 2))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < records; j++ {
+				ticker.Cost(agentID)
+				ticker.Costs()
+			}
+		}()
+	}
+	wg.Wait()
+
+	costs := ticker.Costs()
+	if len(costs) != agents {
+		t.Fatalf("expected %d agents, got %d", agents, len(costs))
+	}
+	for _, cost := range costs {
+		if cost.ActualCents != 2*records {
+			t.Errorf("%s: expected %dc recorded, got %d", cost.AgentID, 2*records, cost.ActualCents)
+		}
+	}
+}
+
+func TestCostTickerMidnightRollover(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	start := time.Date(2026, 10, 14, 23, 30, 0, 0, loc)
+	fake := clock.NewFake(start)
+
+	var closedDate string
+	var closed []models.AgentCost
+	ticker := NewCostTicker(map[models.AgentType]int64{models.AgentTypeClaudeCode: 360},
+		WithCostClock(fake),
+		WithCostLocation(loc),
+		WithCostRollover(func(date string, costs []models.AgentCost) {
+			closedDate, closed = date, costs
+		}),
+	)
+	ticker.Track(&models.Agent{ID: "agent-working", Type: models.AgentTypeClaudeCode, State: models.AgentStateIdle})
+	ticker.Track(&models.Agent{ID: "agent-gone", Type: models.AgentTypeClaudeCode, State: models.AgentStateIdle})
+	ticker.Handle(stateEvent(t, "agent-working", start, models.AgentStateIdle, models.AgentStateWorking))
+	ticker.Handle(usageEvent(t, "agent-gone", start, 120))
+	ticker.Handle(costEvent(t, models.EventTypeAgentTerminated, "agent-gone", start, nil))
+
+	// Forty-five minutes later the day has ended 30 minutes in.
+	fake.Advance(45 * time.Minute)
+	ticker.Roll()
+
+	if closedDate != "2026-10-14" || len(closed) != 2 {
+		t.Fatalf("expected the 14th closed with two agents, got %q %+v", closedDate, closed)
+	}
+	byID := map[string]models.AgentCost{}
+	for _, cost := range closed {
+		byID[cost.AgentID] = cost
+	}
+	if got := byID["agent-working"]; got.Estimated.WorkingSeconds != 1800 || got.Estimated.Cents != 180 {
+		t.Fatalf("expected the working agent's day to end at midnight, got %+v", got)
+	}
+	if got := byID["agent-gone"]; got.ActualCents != 120 {
+		t.Fatalf("expected the terminated agent's usage in the closed day, got %+v", got)
+	}
+
+	if got := ticker.DayStart(); !got.Equal(time.Date(2026, 10, 15, 0, 0, 0, 0, loc)) {
+		t.Fatalf("expected the 15th started, got %v", got)
+	}
+	costs := ticker.Costs()
+	if len(costs) != 1 {
+		t.Fatalf("expected the terminated agent dropped, got %+v", costs)
+	}
+	if got := costs[0]; got.Date != "2026-10-15" || got.ActualCents != 0 || got.Estimated.WorkingSeconds != 900 {
+		t.Fatalf("expected the totals reset with the agent still working, got %+v", got)
+	}
+
+	closedDate = ""
+	ticker.Roll()
+	if closedDate != "" {
+		t.Fatalf("expected a day to close once, closed %q again", closedDate)
+	}
+}
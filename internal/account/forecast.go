@@ -0,0 +1,187 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// TypeProjection is the projected rest-of-day cost of the running agents of
+// one type.
+type TypeProjection struct {
+	Type             models.AgentType `json:"type"`
+	Running          int              `json:"running"`
+	CostPerHourCents int64            `json:"cost_per_hour_cents"`
+	ProjectedCents   int64            `json:"projected_cents"`
+}
+
+// Forecast projects the total cost of the current UTC day: what has been
+// spent so far plus what the running agents are estimated to cost until
+// midnight.
+type Forecast struct {
+	// WorkspaceID is set when the forecast covers one workspace.
+	WorkspaceID string `json:"workspace_id,omitempty"`
+
+	Date           string  `json:"date"`
+	HoursRemaining float64 `json:"hours_remaining"`
+
+	// ActualCents is the cost recorded today.
+	ActualCents int64 `json:"actual_cents"`
+
+	// RunningCents is the estimated cost of running agents until midnight.
+	RunningCents int64 `json:"running_cents"`
+
+	// ProjectedCents is ActualCents plus RunningCents.
+	ProjectedCents int64 `json:"projected_cents"`
+
+	// CeilingCents is the configured daily ceiling (0 = none).
+	CeilingCents int64 `json:"ceiling_cents,omitempty"`
+
+	Running []TypeProjection `json:"running,omitempty"`
+}
+
+// OverBudget reports whether the projection exceeds a configured ceiling.
+func (f *Forecast) OverBudget() bool {
+	return f.CeilingCents > 0 && f.ProjectedCents > f.CeilingCents
+}
+
+// RunningAgents returns the number of running agents counted.
+func (f *Forecast) RunningAgents() int {
+	total := 0
+	for _, projection := range f.Running {
+		total += projection.Running
+	}
+	return total
+}
+
+// Breakdown describes how the projection was reached, such as "$4.20 spent
+// today + $6.00 for 2 running agents (2 claude-code at $3.00/h) over the
+// next 1.0h".
+func (f *Forecast) Breakdown() string {
+	summary := FormatCents(f.ActualCents) + " spent today"
+	if len(f.Running) == 0 {
+		return summary + ", no running agents"
+	}
+	parts := make([]string, 0, len(f.Running))
+	for _, projection := range f.Running {
+		parts = append(parts, fmt.Sprintf("%d %s at %s/h", projection.Running, projection.Type, FormatCents(projection.CostPerHourCents)))
+	}
+	return fmt.Sprintf("%s + %s for %d running agents (%s) over the next %.1fh",
+		summary, FormatCents(f.RunningCents), f.RunningAgents(), strings.Join(parts, ", "), f.HoursRemaining)
+}
+
+// FormatCents renders a cost in cents as dollars, e.g. "$12.40".
+func FormatCents(cents int64) string {
+	return fmt.Sprintf("$%.2f", float64(cents)/100)
+}
+
+// ProjectDailyCost projects the cost of the UTC day containing now from the
+// cost recorded so far and the number of running agents per type, each
+// costing rates[type] cents per hour until midnight. Types without a rate
+// are listed at no cost. Partial cents are rounded up.
+func ProjectDailyCost(now time.Time, actualCents int64, running map[models.AgentType]int, rates map[models.AgentType]int64) *Forecast {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	remaining := int64(midnight.Sub(now) / time.Second)
+
+	forecast := &Forecast{
+		Date:           now.Format("2006-01-02"),
+		HoursRemaining: float64(remaining) / 3600,
+		ActualCents:    actualCents,
+	}
+	for agentType, count := range running {
+		if count <= 0 {
+			continue
+		}
+		rate := rates[agentType]
+		projected := (int64(count)*rate*remaining + 3599) / 3600
+		forecast.Running = append(forecast.Running, TypeProjection{
+			Type:             agentType,
+			Running:          count,
+			CostPerHourCents: rate,
+			ProjectedCents:   projected,
+		})
+		forecast.RunningCents += projected
+	}
+	sort.Slice(forecast.Running, func(i, j int) bool {
+		return forecast.Running[i].Type < forecast.Running[j].Type
+	})
+	forecast.ProjectedCents = forecast.ActualCents + forecast.RunningCents
+	return forecast
+}
+
+// ForecastSources are the repositories a forecast reads from.
+type ForecastSources struct {
+	Usage  *db.UsageRepository
+	Agents *db.AgentRepository
+}
+
+// ForecastOptions selects what a forecast covers.
+type ForecastOptions struct {
+	// Workspace limits the forecast to one workspace; nil covers all.
+	Workspace *models.Workspace
+
+	// Spawning counts one more running agent of this type, so a spawn can
+	// be checked before it happens.
+	Spawning models.AgentType
+}
+
+// BuildForecast projects today's cost from recorded usage and the agents
+// running now, using the budget rates and ceilings in cfg. Stopped, paused,
+// and errored agents are not counted as running.
+func BuildForecast(ctx context.Context, src ForecastSources, cfg *config.Config, opts ForecastOptions, now time.Time) (*Forecast, error) {
+	if src.Usage == nil || src.Agents == nil {
+		return nil, fmt.Errorf("usage and agent repositories are required")
+	}
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var (
+		summary *models.UsageSummary
+		agents  []*models.Agent
+		err     error
+	)
+	if opts.Workspace != nil {
+		summary, err = src.Usage.SummarizeByWorkspace(ctx, opts.Workspace.ID, &dayStart, nil)
+	} else {
+		summary, err = src.Usage.SummarizeAll(ctx, &dayStart, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.Workspace != nil {
+		agents, err = src.Agents.ListByWorkspace(ctx, opts.Workspace.ID)
+	} else {
+		agents, err = src.Agents.List(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	running := make(map[models.AgentType]int)
+	for _, agent := range agents {
+		switch agent.State {
+		case models.AgentStateStopped, models.AgentStatePaused, models.AgentStateError, models.AgentStateHibernated:
+			continue
+		}
+		running[agent.Type]++
+	}
+	if opts.Spawning != "" {
+		running[opts.Spawning]++
+	}
+
+	forecast := ProjectDailyCost(now, summary.TotalCostCents, running, cfg.Budget.CostPerHour())
+	if opts.Workspace != nil {
+		forecast.WorkspaceID = opts.Workspace.ID
+		forecast.CeilingCents = cfg.DailyBudgetForWorkspace(opts.Workspace)
+	} else {
+		forecast.CeilingCents = cfg.Budget.DailyCeilingCents
+	}
+	return forecast, nil
+}
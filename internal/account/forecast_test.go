@@ -0,0 +1,217 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestProjectDailyCost(t *testing.T) {
+	rates := map[models.AgentType]int64{
+		models.AgentTypeClaudeCode: 300,
+		models.AgentTypeCodex:      200,
+	}
+	day := func(hour, minute, second int) time.Time {
+		return time.Date(2026, 10, 14, hour, minute, second, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name          string
+		now           time.Time
+		actual        int64
+		running       map[models.AgentType]int
+		wantHours     float64
+		wantRunning   int64
+		wantProjected int64
+		wantTypes     []TypeProjection
+	}{
+		{
+			name:      "no history and nothing running",
+			now:       day(0, 0, 0),
+			wantHours: 24,
+		},
+		{
+			name:          "first agent of the day",
+			now:           day(0, 0, 0),
+			running:       map[models.AgentType]int{models.AgentTypeClaudeCode: 1},
+			wantHours:     24,
+			wantRunning:   7200,
+			wantProjected: 7200,
+			wantTypes:     []TypeProjection{{Type: models.AgentTypeClaudeCode, Running: 1, CostPerHourCents: 300, ProjectedCents: 7200}},
+		},
+		{
+			name:          "partial day adds to recorded cost",
+			now:           day(18, 0, 0),
+			actual:        1250,
+			running:       map[models.AgentType]int{models.AgentTypeClaudeCode: 1},
+			wantHours:     6,
+			wantRunning:   1800,
+			wantProjected: 3050,
+			wantTypes:     []TypeProjection{{Type: models.AgentTypeClaudeCode, Running: 1, CostPerHourCents: 300, ProjectedCents: 1800}},
+		},
+		{
+			name:   "many agents of several types",
+			now:    day(23, 30, 0),
+			actual: 9000,
+			running: map[models.AgentType]int{
+				models.AgentTypeCodex:      2,
+				models.AgentTypeClaudeCode: 3,
+				models.AgentTypeGeneric:    4,
+				models.AgentTypeGemini:     0,
+			},
+			wantHours:     0.5,
+			wantRunning:   650,
+			wantProjected: 9650,
+			wantTypes: []TypeProjection{
+				{Type: models.AgentTypeClaudeCode, Running: 3, CostPerHourCents: 300, ProjectedCents: 450},
+				{Type: models.AgentTypeCodex, Running: 2, CostPerHourCents: 200, ProjectedCents: 200},
+				{Type: models.AgentTypeGeneric, Running: 4},
+			},
+		},
+		{
+			name:          "partial cents round up",
+			now:           day(23, 59, 59),
+			actual:        10,
+			running:       map[models.AgentType]int{models.AgentTypeCodex: 1},
+			wantHours:     1.0 / 3600,
+			wantRunning:   1,
+			wantProjected: 11,
+			wantTypes:     []TypeProjection{{Type: models.AgentTypeCodex, Running: 1, CostPerHourCents: 200, ProjectedCents: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ProjectDailyCost(tt.now, tt.actual, tt.running, rates)
+			if got.Date != "2026-10-14" {
+				t.Errorf("Date = %q, want 2026-10-14", got.Date)
+			}
+			if got.HoursRemaining != tt.wantHours {
+				t.Errorf("HoursRemaining = %v, want %v", got.HoursRemaining, tt.wantHours)
+			}
+			if got.ActualCents != tt.actual || got.RunningCents != tt.wantRunning || got.ProjectedCents != tt.wantProjected {
+				t.Errorf("got actual=%d running=%d projected=%d, want %d/%d/%d",
+					got.ActualCents, got.RunningCents, got.ProjectedCents, tt.actual, tt.wantRunning, tt.wantProjected)
+			}
+			if len(got.Running) != len(tt.wantTypes) {
+				t.Fatalf("Running = %+v, want %+v", got.Running, tt.wantTypes)
+			}
+			for i := range tt.wantTypes {
+				if got.Running[i] != tt.wantTypes[i] {
+					t.Errorf("Running[%d] = %+v, want %+v", i, got.Running[i], tt.wantTypes[i])
+				}
+			}
+			if got.OverBudget() {
+				t.Errorf("expected no ceiling to never be over budget")
+			}
+		})
+	}
+}
+
+func TestForecastBreakdown(t *testing.T) {
+	now := time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC)
+	forecast := ProjectDailyCost(now, 420, map[models.AgentType]int{models.AgentTypeClaudeCode: 2}, map[models.AgentType]int64{models.AgentTypeClaudeCode: 300})
+	want := "$4.20 spent today + $6.00 for 2 running agents (2 claude-code at $3.00/h) over the next 1.0h"
+	if got := forecast.Breakdown(); got != want {
+		t.Fatalf("Breakdown = %q, want %q", got, want)
+	}
+
+	forecast.CeilingCents = 1000
+	if !forecast.OverBudget() {
+		t.Fatalf("expected $10.20 over a $10.00 ceiling")
+	}
+	forecast.CeilingCents = 1020
+	if forecast.OverBudget() {
+		t.Fatalf("expected a projection equal to the ceiling to be within budget")
+	}
+}
+
+func TestBuildForecast(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, SSHBackend: models.SSHBackendAuto, Status: models.NodeStatusOnline}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("create node: %v", err)
+	}
+	wsRepo := db.NewWorkspaceRepository(database)
+	alpha := &models.Workspace{NodeID: node.ID, Name: "alpha", RepoPath: "/alpha", TmuxSession: "alpha"}
+	beta := &models.Workspace{NodeID: node.ID, Name: "beta", RepoPath: "/beta", TmuxSession: "beta"}
+	for _, ws := range []*models.Workspace{alpha, beta} {
+		if err := wsRepo.Create(ctx, ws); err != nil {
+			t.Fatalf("create workspace: %v", err)
+		}
+	}
+
+	agentRepo := db.NewAgentRepository(database)
+	seed := func(ws *models.Workspace, agentType models.AgentType, state models.AgentState, pane string) *models.Agent {
+		agent := &models.Agent{WorkspaceID: ws.ID, Type: agentType, TmuxPane: pane, State: state}
+		if err := agentRepo.Create(ctx, agent); err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+		return agent
+	}
+	spender := seed(alpha, models.AgentTypeClaudeCode, models.AgentStateWorking, "%1")
+	seed(alpha, models.AgentTypeClaudeCode, models.AgentStateStopped, "%2")
+	seed(alpha, models.AgentTypeCodex, models.AgentStatePaused, "%3")
+	other := seed(beta, models.AgentTypeCodex, models.AgentStateIdle, "%4")
+
+	accountRepo := db.NewAccountRepository(database)
+	acct := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "work"}
+	if err := accountRepo.Create(ctx, acct); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	now := time.Now().UTC()
+	usageRepo := db.NewUsageRepository(database)
+	for _, record := range []*models.UsageRecord{
+		{AccountID: acct.ID, AgentID: spender.ID, Provider: models.ProviderAnthropic, CostCents: 300, RecordedAt: now},
+		{AccountID: acct.ID, AgentID: other.ID, Provider: models.ProviderAnthropic, CostCents: 50, RecordedAt: now},
+		{AccountID: acct.ID, AgentID: spender.ID, Provider: models.ProviderAnthropic, CostCents: 9999, RecordedAt: now.Add(-48 * time.Hour)},
+	} {
+		if err := usageRepo.Create(ctx, record); err != nil {
+			t.Fatalf("create usage: %v", err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Budget.DailyCeilingCents = 5000
+	cfg.WorkspaceOverrides = []config.WorkspaceOverrideConfig{{Name: "alpha", DailyBudgetCents: 800}}
+	src := ForecastSources{Usage: usageRepo, Agents: agentRepo}
+
+	global, err := BuildForecast(ctx, src, cfg, ForecastOptions{}, now)
+	if err != nil {
+		t.Fatalf("BuildForecast: %v", err)
+	}
+	if global.ActualCents != 350 || global.CeilingCents != 5000 || global.WorkspaceID != "" {
+		t.Fatalf("unexpected global forecast %+v", global)
+	}
+	if global.RunningAgents() != 2 {
+		t.Fatalf("expected the working and idle agents to count, got %+v", global.Running)
+	}
+
+	scoped, err := BuildForecast(ctx, src, cfg, ForecastOptions{Workspace: alpha, Spawning: models.AgentTypeCodex}, now)
+	if err != nil {
+		t.Fatalf("BuildForecast: %v", err)
+	}
+	if scoped.ActualCents != 300 || scoped.CeilingCents != 800 || scoped.WorkspaceID != alpha.ID {
+		t.Fatalf("unexpected workspace forecast %+v", scoped)
+	}
+	if len(scoped.Running) != 2 || scoped.Running[0].Type != models.AgentTypeClaudeCode || scoped.Running[1].Type != models.AgentTypeCodex || scoped.Running[1].Running != 1 {
+		t.Fatalf("expected one running claude-code plus the spawning codex, got %+v", scoped.Running)
+	}
+	if !strings.Contains(scoped.Breakdown(), "$3.00 spent today") {
+		t.Fatalf("unexpected breakdown %q", scoped.Breakdown())
+	}
+}
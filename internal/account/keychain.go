@@ -0,0 +1,47 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrKeychainUnavailable is returned when no OS keychain can be reached.
+var ErrKeychainUnavailable = errors.New("os keychain is not available")
+
+// Keychain looks up generic passwords in the OS keychain. Each platform
+// provides its own implementation: the macOS Keychain, libsecret on Linux,
+// and a fallback that always reports ErrKeychainUnavailable elsewhere.
+type Keychain interface {
+	Lookup(ctx context.Context, service, account string) (string, error)
+}
+
+// KeychainResolver resolves keychain: references of the form
+// "service/account", e.g. "keychain:swarm/anthropic-work".
+type KeychainResolver struct {
+	// Keychain is the store to read from; nil means the platform keychain.
+	Keychain Keychain
+}
+
+// Resolve looks the secret up in the keychain.
+func (k KeychainResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	service, account, ok := strings.Cut(ref, "/")
+	if !ok || service == "" || account == "" {
+		return "", errors.New("invalid keychain credential reference format: expected service/account")
+	}
+
+	keychain := k.Keychain
+	if keychain == nil {
+		keychain = systemKeychain()
+	}
+	secret, err := keychain.Lookup(ctx, service, account)
+	if err != nil {
+		return "", fmt.Errorf("keychain lookup %s: %w", ref, err)
+	}
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", fmt.Errorf("keychain entry %s is empty", ref)
+	}
+	return secret, nil
+}
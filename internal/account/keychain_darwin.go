@@ -0,0 +1,27 @@
+//go:build darwin
+
+package account
+
+import (
+	"context"
+	"os/exec"
+)
+
+// darwinKeychain reads generic passwords from the macOS Keychain with the
+// security tool.
+type darwinKeychain struct{}
+
+func systemKeychain() Keychain {
+	return darwinKeychain{}
+}
+
+func (darwinKeychain) Lookup(ctx context.Context, service, account string) (string, error) {
+	if _, err := exec.LookPath("security"); err != nil {
+		return "", ErrKeychainUnavailable
+	}
+	out, err := exec.CommandContext(ctx, "security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
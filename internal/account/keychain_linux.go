@@ -0,0 +1,28 @@
+//go:build linux
+
+package account
+
+import (
+	"context"
+	"os/exec"
+)
+
+// secretServiceKeychain reads from the Secret Service (GNOME Keyring,
+// KWallet) through libsecret's secret-tool, matching items stored with
+// "secret-tool store service <service> account <account>".
+type secretServiceKeychain struct{}
+
+func systemKeychain() Keychain {
+	return secretServiceKeychain{}
+}
+
+func (secretServiceKeychain) Lookup(ctx context.Context, service, account string) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", ErrKeychainUnavailable
+	}
+	out, err := exec.CommandContext(ctx, "secret-tool", "lookup", "service", service, "account", account).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
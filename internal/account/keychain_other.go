@@ -0,0 +1,16 @@
+//go:build !darwin && !linux
+
+package account
+
+import "context"
+
+// noopKeychain is used on platforms without a supported keychain.
+type noopKeychain struct{}
+
+func systemKeychain() Keychain {
+	return noopKeychain{}
+}
+
+func (noopKeychain) Lookup(context.Context, string, string) (string, error) {
+	return "", ErrKeychainUnavailable
+}
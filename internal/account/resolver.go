@@ -0,0 +1,262 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/redact"
+)
+
+// DefaultExecTimeout bounds how long an exec: credential command may run.
+const DefaultExecTimeout = 10 * time.Second
+
+// ErrUnknownScheme is returned for a credential reference whose scheme has
+// no registered resolver.
+var ErrUnknownScheme = errors.New("unknown credential reference scheme")
+
+// schemePattern matches the scheme part of a credential reference.
+var schemePattern = regexp.MustCompile(`^([a-z][a-z0-9]*):`)
+
+// Resolver resolves the part of a credential reference after its scheme
+// to the secret it names.
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ResolverFunc adapts a function to a Resolver.
+type ResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f.
+func (f ResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// Resolvers maps credential reference schemes to their resolvers.
+type Resolvers struct {
+	mu      sync.RWMutex
+	schemes map[string]Resolver
+}
+
+// NewResolvers creates an empty resolver registry.
+func NewResolvers() *Resolvers {
+	return &Resolvers{schemes: make(map[string]Resolver)}
+}
+
+// DefaultResolvers returns a registry with the built-in schemes: env, file,
+// vault, caam, exec, keychain, and literal.
+func DefaultResolvers() *Resolvers {
+	r := NewResolvers()
+	r.Register("env", ResolverFunc(resolveEnv))
+	r.Register("file", ResolverFunc(resolveFile))
+	r.Register("vault", ResolverFunc(func(_ context.Context, ref string) (string, error) {
+		return resolveVaultCredential(ref)
+	}))
+	r.Register("caam", ResolverFunc(func(_ context.Context, ref string) (string, error) {
+		return resolveCaamCredential(ref)
+	}))
+	r.Register("exec", ExecResolver{})
+	r.Register("keychain", KeychainResolver{})
+	r.Register("literal", ResolverFunc(func(_ context.Context, ref string) (string, error) {
+		return ref, nil
+	}))
+	return r
+}
+
+// Register sets the resolver for scheme, replacing any existing one.
+func (r *Resolvers) Register(scheme string, resolver Resolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemes[scheme] = resolver
+}
+
+// Schemes returns the registered schemes in sorted order.
+func (r *Resolvers) Schemes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	schemes := make([]string, 0, len(r.schemes))
+	for scheme := range r.schemes {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Resolve resolves a credential reference through the resolver for its
+// scheme. $VAR and ${VAR} are read from the environment, and a reference
+// without a scheme is used as a literal value. The secret is registered
+// with the redaction layer before it is returned.
+func (r *Resolvers) Resolve(ctx context.Context, credentialRef string) (string, error) {
+	if credentialRef == "" {
+		return "", errors.New("empty credential reference")
+	}
+
+	var (
+		secret string
+		err    error
+	)
+	switch scheme, ref, ok := splitScheme(credentialRef); {
+	case strings.HasPrefix(credentialRef, "$"):
+		secret, err = resolveDollarEnv(credentialRef)
+	case ok:
+		resolver, found := r.lookup(scheme)
+		if !found {
+			return "", fmt.Errorf("%w %q", ErrUnknownScheme, scheme)
+		}
+		secret, err = resolver.Resolve(ctx, ref)
+	default:
+		secret = credentialRef
+	}
+	if err != nil {
+		return "", err
+	}
+
+	redact.RegisterSecret(secret)
+	return secret, nil
+}
+
+// Validate checks that a credential reference names a registered scheme
+// and, for exec: references, a command that can be found. It does not
+// resolve the secret.
+func (r *Resolvers) Validate(credentialRef string) error {
+	if strings.TrimSpace(credentialRef) == "" {
+		return errors.New("empty credential reference")
+	}
+	scheme, ref, ok := splitScheme(credentialRef)
+	if !ok || strings.HasPrefix(credentialRef, "$") {
+		return nil
+	}
+	if _, found := r.lookup(scheme); !found {
+		return fmt.Errorf("%w %q (known: %s)", ErrUnknownScheme, scheme, strings.Join(r.Schemes(), ", "))
+	}
+	if scheme == "exec" {
+		return ValidateExecCommand(ref)
+	}
+	return nil
+}
+
+func (r *Resolvers) lookup(scheme string) (Resolver, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	resolver, ok := r.schemes[scheme]
+	return resolver, ok
+}
+
+func splitScheme(credentialRef string) (scheme, ref string, ok bool) {
+	m := schemePattern.FindStringSubmatch(credentialRef)
+	if m == nil {
+		return "", credentialRef, false
+	}
+	return m[1], credentialRef[len(m[0]):], true
+}
+
+func resolveEnv(_ context.Context, envVar string) (string, error) {
+	value := os.Getenv(envVar)
+	if value == "" {
+		return "", errors.New("environment variable " + envVar + " is not set")
+	}
+	return value, nil
+}
+
+func resolveDollarEnv(credentialRef string) (string, error) {
+	envVar := strings.TrimPrefix(credentialRef, "$")
+	if strings.HasPrefix(envVar, "{") && strings.HasSuffix(envVar, "}") {
+		envVar = strings.TrimPrefix(envVar, "{")
+		envVar = strings.TrimSuffix(envVar, "}")
+	}
+	envVar = strings.TrimSpace(envVar)
+	if envVar == "" {
+		return "", errors.New("environment variable reference is empty")
+	}
+	return resolveEnv(context.Background(), envVar)
+}
+
+func resolveFile(_ context.Context, filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", errors.New("failed to read credential file: " + err.Error())
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// ExecResolver resolves exec: references by running the command through
+// sh -c and taking its trimmed stdout as the secret, e.g.
+// "exec:op read op://dev/anthropic/key". The output is never logged.
+type ExecResolver struct {
+	// Timeout bounds the command; zero means DefaultExecTimeout.
+	Timeout time.Duration
+}
+
+// Resolve runs command and returns its output.
+func (e ExecResolver) Resolve(ctx context.Context, command string) (string, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return "", errors.New("exec credential command is empty")
+	}
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExecTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// A killed shell can leave children holding the pipes open.
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("credential command timed out after %s", timeout)
+		}
+		if msg := firstLine(stderr.String()); msg != "" {
+			return "", fmt.Errorf("credential command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("credential command failed: %w", err)
+	}
+
+	secret := strings.TrimSpace(stdout.String())
+	if secret == "" {
+		return "", errors.New("credential command printed nothing")
+	}
+	return secret, nil
+}
+
+// ValidateExecCommand checks that an exec: command is set and that the
+// program it starts with is on PATH.
+func ValidateExecCommand(command string) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return errors.New("exec credential command is empty")
+	}
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		return fmt.Errorf("credential command %q not found: %w", fields[0], err)
+	}
+	return nil
+}
+
+// firstLine returns the first non-empty line of s, capped at 200 bytes.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > 200 {
+			line = line[:200]
+		}
+		return line
+	}
+	return ""
+}
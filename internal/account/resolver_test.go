@@ -0,0 +1,124 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/redact"
+)
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	return path
+}
+
+func TestExecResolver(t *testing.T) {
+	script := writeScript(t, `echo "  sk-exec-$1-0123456789  "`)
+
+	got, err := ResolveCredential("exec:" + script + " work")
+	if err != nil {
+		t.Fatalf("ResolveCredential: %v", err)
+	}
+	if got != "sk-exec-work-0123456789" {
+		t.Fatalf("expected trimmed stdout, got %q", got)
+	}
+	if out := redact.Default().Redact("key is " + got); strings.Contains(out, got) {
+		t.Fatalf("resolved secret was not registered for redaction: %q", out)
+	}
+
+	failing := writeScript(t, `echo "not signed in" >&2; exit 3`)
+	if _, err := ResolveCredential("exec:" + failing); err == nil || !strings.Contains(err.Error(), "not signed in") {
+		t.Fatalf("expected the command's stderr in the error, got %v", err)
+	}
+
+	silent := writeScript(t, `true`)
+	if _, err := ResolveCredential("exec:" + silent); err == nil {
+		t.Fatal("expected an error for a command that prints nothing")
+	}
+}
+
+func TestExecResolverTimeout(t *testing.T) {
+	script := writeScript(t, `sleep 5; echo too-late-secret`)
+
+	start := time.Now()
+	_, err := ExecResolver{Timeout: 100 * time.Millisecond}.Resolve(context.Background(), script)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("timeout took %s", elapsed)
+	}
+}
+
+type fakeKeychain map[string]string
+
+func (f fakeKeychain) Lookup(_ context.Context, service, account string) (string, error) {
+	secret, ok := f[service+"/"+account]
+	if !ok {
+		return "", errors.New("item not found")
+	}
+	return secret, nil
+}
+
+func TestResolversDispatch(t *testing.T) {
+	t.Setenv("RESOLVER_TEST_KEY", "from-env")
+	ctx := context.Background()
+
+	r := DefaultResolvers()
+	r.Register("keychain", KeychainResolver{Keychain: fakeKeychain{"swarm/work": "from-keychain\n"}})
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "env:RESOLVER_TEST_KEY", want: "from-env"},
+		{ref: "${RESOLVER_TEST_KEY}", want: "from-env"},
+		{ref: "keychain:swarm/work", want: "from-keychain"},
+		{ref: "literal:abc:def", want: "abc:def"},
+		{ref: "sk-plain-value", want: "sk-plain-value"},
+	}
+	for _, tt := range tests {
+		got, err := r.Resolve(ctx, tt.ref)
+		if err != nil {
+			t.Fatalf("Resolve(%q): %v", tt.ref, err)
+		}
+		if got != tt.want {
+			t.Fatalf("Resolve(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+
+	if _, err := r.Resolve(ctx, "keychain:swarm/missing"); err == nil {
+		t.Fatal("expected an error for a missing keychain item")
+	}
+	if _, err := r.Resolve(ctx, "keychain:no-account"); err == nil {
+		t.Fatal("expected an error for a malformed keychain reference")
+	}
+	if _, err := r.Resolve(ctx, "pass:anthropic/work"); !errors.Is(err, ErrUnknownScheme) {
+		t.Fatalf("expected ErrUnknownScheme, got %v", err)
+	}
+	if err := r.Validate("pass:anthropic/work"); !errors.Is(err, ErrUnknownScheme) {
+		t.Fatalf("expected Validate to reject an unknown scheme, got %v", err)
+	}
+	if err := r.Validate("exec:definitely-not-a-command-xyz read"); err == nil {
+		t.Fatal("expected Validate to reject a missing exec command")
+	}
+	if err := r.Validate("exec:sh -c 'echo hi'"); err != nil {
+		t.Fatalf("Validate(exec:sh): %v", err)
+	}
+
+	r.Register("pass", ResolverFunc(func(_ context.Context, ref string) (string, error) {
+		return "pass-" + ref, nil
+	}))
+	if got, err := r.Resolve(ctx, "pass:anthropic/work"); err != nil || got != "pass-anthropic/work" {
+		t.Fatalf("custom scheme resolved to %q (%v)", got, err)
+	}
+}
@@ -12,11 +12,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/opencode-ai/swarm/internal/account/caam"
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/clock"
 	"github.com/opencode-ai/swarm/internal/config"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/events"
 	"github.com/opencode-ai/swarm/internal/logging"
 	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/store"
 	"github.com/opencode-ai/swarm/internal/vault"
 	"github.com/rs/zerolog"
 )
@@ -34,17 +38,35 @@ type Service struct {
 	mu              sync.RWMutex
 	accounts        map[string]*models.Account
 	defaultCooldown time.Duration
-	repo            *db.AccountRepository
+	repo            store.AccountStore
 	publisher       events.Publisher
+	clock           clock.Clock
 	logger          zerolog.Logger
 	vaultPath       string // Path to the native credential vault
+	caamVaultPath   string // Path to the caam vault caam: references point into
+	resolvers       *Resolvers
+	auditor         *audit.Recorder
+
+	// expiryWarning is how long before credentials expire that an account
+	// is warned about and ranked last for rotation.
+	expiryWarning time.Duration
+
+	// cooldowns tracks cooldowns applied by this service on the monotonic
+	// clock so expiry is unaffected by wall-clock jumps.
+	cooldowns map[string]cooldownDeadline
+}
+
+// cooldownDeadline pairs an account's CooldownUntil with its monotonic deadline.
+type cooldownDeadline struct {
+	until    time.Time
+	deadline clock.Deadline
 }
 
 // ServiceOption configures an account Service.
 type ServiceOption func(*Service)
 
 // WithRepository configures a repository for persistence.
-func WithRepository(repo *db.AccountRepository) ServiceOption {
+func WithRepository(repo store.AccountStore) ServiceOption {
 	return func(s *Service) {
 		s.repo = repo
 	}
@@ -65,6 +87,37 @@ func WithVaultPath(path string) ServiceOption {
 	}
 }
 
+// WithCaamVaultPath configures the caam vault credential expiry is read
+// from. If not set, caam.DefaultVaultPath is used.
+func WithCaamVaultPath(path string) ServiceOption {
+	return func(s *Service) {
+		s.caamVaultPath = path
+	}
+}
+
+// WithCredentialExpiryWarning overrides scheduler.credential_expiry_warning.
+// Zero disables expiry warnings and the rotation preference.
+func WithCredentialExpiryWarning(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.expiryWarning = d
+	}
+}
+
+// WithResolvers configures the credential reference resolvers used when
+// injecting credentials. If not set, DefaultResolvers is used.
+func WithResolvers(resolvers *Resolvers) ServiceOption {
+	return func(s *Service) {
+		s.resolvers = resolvers
+	}
+}
+
+// WithClock configures the time source used for cooldown tracking.
+func WithClock(c clock.Clock) ServiceOption {
+	return func(s *Service) {
+		s.clock = c
+	}
+}
+
 // NewService creates a new account service from config.
 func NewService(cfg *config.Config, opts ...ServiceOption) *Service {
 	s := &Service{
@@ -72,10 +125,17 @@ func NewService(cfg *config.Config, opts ...ServiceOption) *Service {
 		defaultCooldown: cfg.Scheduler.DefaultCooldownDuration,
 		logger:          logging.Component("account"),
 		vaultPath:       vault.DefaultVaultPath(),
+		caamVaultPath:   caam.DefaultVaultPath(),
+		expiryWarning:   cfg.Scheduler.CredentialExpiryWarning,
+		cooldowns:       make(map[string]cooldownDeadline),
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.clock = clock.OrReal(s.clock)
+	if s.resolvers == nil {
+		s.resolvers = DefaultResolvers()
+	}
 
 	// Load accounts from config
 	for _, acct := range cfg.Accounts {
@@ -85,8 +145,8 @@ func NewService(cfg *config.Config, opts ...ServiceOption) *Service {
 			ProfileName:   acct.ProfileName,
 			CredentialRef: acct.CredentialRef,
 			IsActive:      acct.IsActive,
-			CreatedAt:     time.Now().UTC(),
-			UpdatedAt:     time.Now().UTC(),
+			CreatedAt:     s.clock.Now().UTC(),
+			UpdatedAt:     s.clock.Now().UTC(),
 		}
 		s.accounts[account.ID] = account
 	}
@@ -95,10 +155,16 @@ func NewService(cfg *config.Config, opts ...ServiceOption) *Service {
 }
 
 // AddAccount adds a new account to the service.
-func (s *Service) AddAccount(ctx context.Context, account *models.Account) error {
+func (s *Service) AddAccount(ctx context.Context, account *models.Account) (err error) {
 	if account == nil {
 		return errors.New("account is nil")
 	}
+	defer func() {
+		s.auditor.Record(ctx, AuditAddAccount, models.EntityTypeAccount, account.ID, audit.Params(
+			"provider", string(account.Provider),
+			"profile", account.ProfileName,
+		), err)
+	}()
 	if err := account.Validate(); err != nil {
 		return err
 	}
@@ -114,7 +180,7 @@ func (s *Service) AddAccount(ctx context.Context, account *models.Account) error
 		return ErrAccountAlreadyExists
 	}
 
-	now := time.Now().UTC()
+	now := s.clock.Now().UTC()
 	if account.CreatedAt.IsZero() {
 		account.CreatedAt = now
 	}
@@ -138,7 +204,8 @@ func (s *Service) GetAccount(ctx context.Context, id string) (*models.Account, e
 }
 
 // DeleteAccount removes an account by ID.
-func (s *Service) DeleteAccount(ctx context.Context, id string) error {
+func (s *Service) DeleteAccount(ctx context.Context, id string) (err error) {
+	defer func() { s.auditor.Record(ctx, AuditDeleteAccount, models.EntityTypeAccount, id, nil, err) }()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -161,7 +228,7 @@ func (s *Service) GetNextAvailable(ctx context.Context, provider models.Provider
 
 	var candidates []*models.Account
 	for _, account := range s.accounts {
-		if account.Provider == provider && account.IsAvailable() {
+		if account.Provider == provider && s.isAvailableLocked(account) {
 			candidates = append(candidates, account)
 		}
 	}
@@ -170,7 +237,7 @@ func (s *Service) GetNextAvailable(ctx context.Context, provider models.Provider
 		return nil, ErrNoAvailableAccount
 	}
 
-	return selectLeastRecentlyUsed(candidates), nil
+	return SelectForRotation(candidates, s.clock.Now(), s.expiryWarning), nil
 }
 
 // Get retrieves an account by ID.
@@ -204,7 +271,7 @@ func (s *Service) ListAvailable(ctx context.Context) []*models.Account {
 
 	var available []*models.Account
 	for _, account := range s.accounts {
-		if account.IsAvailable() {
+		if s.isAvailableLocked(account) {
 			available = append(available, account)
 		}
 	}
@@ -235,21 +302,49 @@ func (s *Service) IsOnCooldown(ctx context.Context, id string) (bool, time.Durat
 		return false, 0, ErrAccountNotFound
 	}
 
-	if account.IsOnCooldown() {
-		return true, account.CooldownRemaining(), nil
+	if remaining := s.cooldownRemainingLocked(account); remaining > 0 {
+		return true, remaining, nil
 	}
 	return false, 0, nil
 }
 
+// cooldownRemainingLocked returns how long an account stays on cooldown.
+// Cooldowns applied by this service use their monotonic deadline; cooldowns
+// loaded from config or persistence fall back to the wall clock.
+// Callers must hold s.mu.
+func (s *Service) cooldownRemainingLocked(account *models.Account) time.Duration {
+	if account.CooldownUntil == nil {
+		return 0
+	}
+	if tracked, ok := s.cooldowns[account.ID]; ok && tracked.until.Equal(*account.CooldownUntil) {
+		return tracked.deadline.Remaining(s.clock)
+	}
+	if remaining := account.CooldownUntil.Sub(s.clock.Now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// isAvailableLocked reports whether an account is active and off cooldown.
+// Callers must hold s.mu.
+func (s *Service) isAvailableLocked(account *models.Account) bool {
+	return account.IsActive && s.cooldownRemainingLocked(account) == 0
+}
+
 // SetCooldown puts an account on cooldown.
 func (s *Service) SetCooldown(ctx context.Context, id string, duration time.Duration) error {
 	_, err := s.applyCooldown(ctx, id, duration, true)
+	s.auditor.Record(ctx, AuditSetCooldown, models.EntityTypeAccount, id, audit.Params("duration", duration.String()), err)
 	return err
 }
 
 // SetCooldownForRateLimit applies the default cooldown after a rate limit.
 func (s *Service) SetCooldownForRateLimit(ctx context.Context, id, reason string) error {
 	account, err := s.applyCooldown(ctx, id, s.defaultCooldown, true)
+	s.auditor.Record(ctx, AuditSetCooldown, models.EntityTypeAccount, id, audit.Params(
+		"duration", s.defaultCooldown.String(),
+		"reason", reason,
+	), err)
 	if err != nil {
 		return err
 	}
@@ -270,10 +365,11 @@ func (s *Service) applyCooldown(ctx context.Context, id string, duration time.Du
 		return nil, ErrAccountNotFound
 	}
 
-	now := time.Now().UTC()
+	now := s.clock.Now().UTC()
 	cooldownUntil := now.Add(duration)
 	account.CooldownUntil = &cooldownUntil
 	account.UpdatedAt = now
+	s.cooldowns[id] = cooldownDeadline{until: cooldownUntil, deadline: clock.NewDeadline(s.clock, duration)}
 
 	if incrementRateLimit {
 		if account.UsageStats == nil {
@@ -302,6 +398,12 @@ func (s *Service) applyCooldown(ctx context.Context, id string, duration time.Du
 
 // ClearCooldown removes cooldown from an account.
 func (s *Service) ClearCooldown(ctx context.Context, id string) error {
+	err := s.clearCooldown(ctx, id)
+	s.auditor.Record(ctx, AuditClearCooldown, models.EntityTypeAccount, id, nil, err)
+	return err
+}
+
+func (s *Service) clearCooldown(ctx context.Context, id string) error {
 	s.mu.Lock()
 	account, exists := s.accounts[id]
 	if !exists {
@@ -310,7 +412,8 @@ func (s *Service) ClearCooldown(ctx context.Context, id string) error {
 	}
 	hadCooldown := account.CooldownUntil != nil
 	account.CooldownUntil = nil
-	account.UpdatedAt = time.Now().UTC()
+	account.UpdatedAt = s.clock.Now().UTC()
+	delete(s.cooldowns, id)
 	snapshot := cloneAccount(account)
 	s.mu.Unlock()
 
@@ -335,11 +438,10 @@ func (s *Service) SweepExpiredCooldowns(ctx context.Context) (int, error) {
 		return 0, err
 	}
 
-	now := time.Now().UTC()
 	s.mu.RLock()
 	var expired []string
 	for id, account := range s.accounts {
-		if account.CooldownUntil != nil && now.After(*account.CooldownUntil) {
+		if account.CooldownUntil != nil && s.cooldownRemainingLocked(account) == 0 {
 			expired = append(expired, id)
 		}
 	}
@@ -348,7 +450,7 @@ func (s *Service) SweepExpiredCooldowns(ctx context.Context) (int, error) {
 	var cleared int
 	var firstErr error
 	for _, id := range expired {
-		if err := s.ClearCooldown(ctx, id); err != nil {
+		if err := s.clearCooldown(ctx, id); err != nil {
 			if firstErr == nil {
 				firstErr = err
 			}
@@ -361,24 +463,149 @@ func (s *Service) SweepExpiredCooldowns(ctx context.Context) (int, error) {
 	return cleared, firstErr
 }
 
-// StartCooldownMonitor launches a background loop that clears expired cooldowns.
+// SweepCredentialExpiry re-reads the credential expiry of caam-backed
+// accounts and publishes account.credential_expiring once for each account
+// whose credentials expire within the warning threshold. An account is
+// warned about again only after its expiry changes.
+// Returns the number of accounts warned about and the first error encountered.
+func (s *Service) SweepCredentialExpiry(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.RLock()
+	refs := make(map[string]string)
+	for id, account := range s.accounts {
+		if ref, ok := strings.CutPrefix(account.CredentialRef, "caam:"); ok {
+			refs[id] = ref
+		}
+	}
+	s.mu.RUnlock()
+
+	var firstErr error
+	for id, ref := range refs {
+		provider, email, ok := strings.Cut(ref, "/")
+		if !ok {
+			continue
+		}
+		profile, err := caam.ParseProfile(s.caamVaultPath, provider, email)
+		if err != nil {
+			s.logger.Debug().Err(err).Str("account_id", id).Msg("failed to read caam profile expiry")
+			continue
+		}
+		if err := s.setCredentialExpiry(ctx, id, profile.ExpiresAt); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if s.expiryWarning <= 0 {
+		return 0, firstErr
+	}
+
+	now := s.clock.Now().UTC()
+	s.mu.Lock()
+	var due []*models.Account
+	for _, account := range s.accounts {
+		remaining, known := account.CredentialExpiresIn(now)
+		if known && remaining <= s.expiryWarning && account.CredentialExpiryWarnedAt == nil {
+			warnedAt := now
+			account.CredentialExpiryWarnedAt = &warnedAt
+			due = append(due, cloneAccount(account))
+		}
+	}
+	s.mu.Unlock()
+
+	var warned int
+	for _, account := range due {
+		if s.repo != nil {
+			// The repository arbitrates between processes sweeping the
+			// same accounts, so each expiry is warned about once.
+			marked, err := s.repo.MarkCredentialExpiryWarned(ctx, account.ID, now)
+			if err != nil && !errors.Is(err, db.ErrAccountNotFound) {
+				s.unmarkCredentialExpiryWarned(account.ID)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if err == nil && !marked {
+				continue
+			}
+		}
+		s.logger.Warn().
+			Str("account_id", account.ID).
+			Time("expires_at", *account.CredentialExpiresAt).
+			Msg("account credentials expiring")
+		s.publishCredentialExpiringEvent(ctx, account, now)
+		warned++
+	}
+
+	return warned, firstErr
+}
+
+// setCredentialExpiry records a refreshed expiry, clearing the warning mark
+// when it changed.
+func (s *Service) setCredentialExpiry(ctx context.Context, id string, expiresAt *time.Time) error {
+	s.mu.Lock()
+	account, exists := s.accounts[id]
+	if !exists {
+		s.mu.Unlock()
+		return ErrAccountNotFound
+	}
+	if sameTime(account.CredentialExpiresAt, expiresAt) {
+		s.mu.Unlock()
+		return nil
+	}
+	account.CredentialExpiresAt = expiresAt
+	account.CredentialExpiryWarnedAt = nil
+	account.UpdatedAt = s.clock.Now().UTC()
+	s.mu.Unlock()
+
+	if s.repo != nil {
+		if err := s.repo.SetCredentialExpiry(ctx, id, expiresAt); err != nil && !errors.Is(err, db.ErrAccountNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) unmarkCredentialExpiryWarned(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if account, exists := s.accounts[id]; exists {
+		account.CredentialExpiryWarnedAt = nil
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// StartCooldownMonitor launches a background loop that clears expired
+// cooldowns and checks for expiring credentials.
 func (s *Service) StartCooldownMonitor(ctx context.Context, interval time.Duration) {
 	if interval <= 0 {
 		interval = time.Second
 	}
 
 	go func() {
-		ticker := time.NewTicker(interval)
+		ticker := s.clock.NewTicker(interval)
 		defer ticker.Stop()
 
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
+			case <-ticker.C():
 				if _, err := s.SweepExpiredCooldowns(ctx); err != nil && !errors.Is(err, context.Canceled) {
 					s.logger.Warn().Err(err).Msg("cooldown sweep failed")
 				}
+				if _, err := s.SweepCredentialExpiry(ctx); err != nil && !errors.Is(err, context.Canceled) {
+					s.logger.Warn().Err(err).Msg("credential expiry sweep failed")
+				}
 			}
 		}
 	}()
@@ -391,7 +618,7 @@ func (s *Service) GetAvailable(ctx context.Context, provider models.Provider) (*
 	defer s.mu.RUnlock()
 
 	for _, account := range s.accounts {
-		if account.Provider == provider && account.IsAvailable() {
+		if account.Provider == provider && s.isAvailableLocked(account) {
 			return account, nil
 		}
 	}
@@ -407,7 +634,19 @@ func (s *Service) RotateAccount(ctx context.Context, currentID string) (*models.
 // RotateAccountForAgent finds an alternative account and emits a rotation event.
 // If agentID is provided, it will be included in the event payload.
 // The reason describes why the rotation occurred (e.g., "cooldown", "rate_limit").
-func (s *Service) RotateAccountForAgent(ctx context.Context, currentID, agentID, reason string) (*models.Account, error) {
+func (s *Service) RotateAccountForAgent(ctx context.Context, currentID, agentID, reason string) (next *models.Account, err error) {
+	defer func() {
+		var to string
+		if next != nil {
+			to = next.ID
+		}
+		s.auditor.Record(ctx, AuditRotateAccount, models.EntityTypeAccount, currentID, audit.Params(
+			"to_account_id", to,
+			"agent_id", agentID,
+			"reason", reason,
+		), err)
+	}()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -421,7 +660,7 @@ func (s *Service) RotateAccountForAgent(ctx context.Context, currentID, agentID,
 	for _, account := range s.accounts {
 		if account.ID != currentID &&
 			account.Provider == current.Provider &&
-			account.IsAvailable() {
+			s.isAvailableLocked(account) {
 			candidates = append(candidates, account)
 		}
 	}
@@ -430,7 +669,7 @@ func (s *Service) RotateAccountForAgent(ctx context.Context, currentID, agentID,
 		return nil, ErrNoAvailableAccount
 	}
 
-	next := selectLeastRecentlyUsed(candidates)
+	next = SelectForRotation(candidates, s.clock.Now(), s.expiryWarning)
 	s.logger.Info().
 		Str("from_account", currentID).
 		Str("to_account", next.ID).
@@ -461,7 +700,7 @@ func (s *Service) RecordUsage(ctx context.Context, id string, tokens int64, cost
 		account.UsageStats = &models.UsageStats{}
 	}
 
-	now := time.Now().UTC()
+	now := s.clock.Now().UTC()
 	account.UsageStats.TotalTokens += tokens
 	account.UsageStats.TotalCostCents += costCents
 	account.UsageStats.RequestCount++
@@ -506,10 +745,13 @@ func (s *Service) CheckAndWaitCooldown(ctx context.Context, id string, waitMax t
 		Dur("wait_time", remaining).
 		Msg("waiting for cooldown to expire")
 
+	timer := s.clock.NewTimer(remaining)
+	defer timer.Stop()
+
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
-	case <-time.After(remaining):
+	case <-timer.C():
 		// Cooldown should be over
 		account, err := s.Get(ctx, id)
 		if err != nil {
@@ -533,69 +775,18 @@ func ProviderEnvVar(provider models.Provider) string {
 	}
 }
 
-// ResolveCredential resolves a credential reference to its actual value.
-// Credential references can be:
+// ResolveCredential resolves a credential reference to its actual value
+// through DefaultResolvers. Credential references can be:
 //   - env:VAR_NAME - reads from environment variable VAR_NAME
 //   - $VAR_NAME or ${VAR_NAME} - reads from environment variable VAR_NAME
 //   - file:/path/to/file - reads from file
+//   - exec:command - runs command and reads its stdout
+//   - keychain:service/account - reads from the OS keychain
 //   - vault:adapter/profile - reads from native Swarm vault (recommended)
 //   - caam:provider/email - reads from legacy caam vault (deprecated)
-//   - literal value - used as-is (not recommended for production)
+//   - literal:value or a value without a scheme - used as-is (not recommended for production)
 func ResolveCredential(credentialRef string) (string, error) {
-	if credentialRef == "" {
-		return "", errors.New("empty credential reference")
-	}
-
-	// Check for env: prefix
-	if strings.HasPrefix(credentialRef, "env:") {
-		envVar := strings.TrimPrefix(credentialRef, "env:")
-		value := os.Getenv(envVar)
-		if value == "" {
-			return "", errors.New("environment variable " + envVar + " is not set")
-		}
-		return value, nil
-	}
-
-	// Check for $VAR or ${VAR} env var reference
-	if strings.HasPrefix(credentialRef, "$") {
-		envVar := strings.TrimPrefix(credentialRef, "$")
-		if strings.HasPrefix(envVar, "{") && strings.HasSuffix(envVar, "}") {
-			envVar = strings.TrimPrefix(envVar, "{")
-			envVar = strings.TrimSuffix(envVar, "}")
-		}
-		envVar = strings.TrimSpace(envVar)
-		if envVar == "" {
-			return "", errors.New("environment variable reference is empty")
-		}
-		value := os.Getenv(envVar)
-		if value == "" {
-			return "", errors.New("environment variable " + envVar + " is not set")
-		}
-		return value, nil
-	}
-
-	// Check for file: prefix
-	if strings.HasPrefix(credentialRef, "file:") {
-		filePath := strings.TrimPrefix(credentialRef, "file:")
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			return "", errors.New("failed to read credential file: " + err.Error())
-		}
-		return strings.TrimSpace(string(data)), nil
-	}
-
-	// Check for vault: prefix (native Swarm vault - recommended)
-	if strings.HasPrefix(credentialRef, "vault:") {
-		return resolveVaultCredential(strings.TrimPrefix(credentialRef, "vault:"))
-	}
-
-	// Check for caam: prefix (coding_agent_account_manager vault - deprecated)
-	if strings.HasPrefix(credentialRef, "caam:") {
-		return resolveCaamCredential(strings.TrimPrefix(credentialRef, "caam:"))
-	}
-
-	// Treat as literal value (for backwards compatibility)
-	return credentialRef, nil
+	return DefaultResolvers().Resolve(context.Background(), credentialRef)
 }
 
 // resolveVaultCredential resolves a credential from the native Swarm vault.
@@ -835,6 +1026,34 @@ func (s *Service) publishRotationEvent(ctx context.Context, agentID, oldAccountI
 	})
 }
 
+func (s *Service) publishCredentialExpiringEvent(ctx context.Context, account *models.Account, now time.Time) {
+	if s.publisher == nil {
+		return
+	}
+	if account == nil || account.CredentialExpiresAt == nil {
+		return
+	}
+
+	payload, err := json.Marshal(models.CredentialExpiringPayload{
+		AccountID:        account.ID,
+		Provider:         account.Provider,
+		ExpiresAt:        account.CredentialExpiresAt.UTC(),
+		RemainingSeconds: max(int(account.CredentialExpiresAt.Sub(now).Seconds()), 0),
+		ThresholdSeconds: int(s.expiryWarning.Seconds()),
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("account_id", account.ID).Msg("failed to marshal credential expiring payload")
+		return
+	}
+
+	s.publisher.Publish(ctx, &models.Event{
+		Type:       models.EventTypeCredentialExpiring,
+		EntityType: models.EntityTypeAccount,
+		EntityID:   account.ID,
+		Payload:    payload,
+	})
+}
+
 func cloneAccount(account *models.Account) *models.Account {
 	if account == nil {
 		return nil
@@ -853,6 +1072,14 @@ func cloneAccount(account *models.Account) *models.Account {
 		}
 		cloned.UsageStats = &usage
 	}
+	if account.CredentialExpiresAt != nil {
+		expiresAt := *account.CredentialExpiresAt
+		cloned.CredentialExpiresAt = &expiresAt
+	}
+	if account.CredentialExpiryWarnedAt != nil {
+		warnedAt := *account.CredentialExpiryWarnedAt
+		cloned.CredentialExpiryWarnedAt = &warnedAt
+	}
 	return &cloned
 }
 
@@ -860,24 +1087,29 @@ func cloneAccount(account *models.Account) *models.Account {
 // This can be passed to agent spawn to inject the correct API key.
 func (s *Service) GetCredentialEnv(ctx context.Context, accountID string) (map[string]string, error) {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	account, exists := s.accounts[accountID]
+	var provider models.Provider
+	var credentialRef string
+	if exists {
+		provider, credentialRef = account.Provider, account.CredentialRef
+	}
+	s.mu.RUnlock()
 	if !exists {
 		return nil, ErrAccountNotFound
 	}
 
-	envVar := ProviderEnvVar(account.Provider)
+	envVar := ProviderEnvVar(provider)
 	if envVar == "" {
 		// Custom provider - no standard env var
 		s.logger.Debug().
 			Str("account_id", accountID).
-			Str("provider", string(account.Provider)).
+			Str("provider", string(provider)).
 			Msg("no standard env var for provider")
 		return map[string]string{}, nil
 	}
 
-	credential, err := ResolveCredential(account.CredentialRef)
+	// Resolve outside the lock: exec: and keychain: references can block.
+	credential, err := s.resolvers.Resolve(ctx, credentialRef)
 	if err != nil {
 		return nil, err
 	}
@@ -905,12 +1137,29 @@ func MergeEnv(base map[string]string, credentials map[string]string) map[string]
 	return result
 }
 
-func selectLeastRecentlyUsed(accounts []*models.Account) *models.Account {
+// SelectForRotation picks the account to rotate to: the least recently
+// used, passing over accounts whose credentials expire within warning.
+// Those come last, the one with the most validity left first. It sorts
+// accounts in place.
+func SelectForRotation(accounts []*models.Account, now time.Time, warning time.Duration) *models.Account {
 	if len(accounts) == 0 {
 		return nil
 	}
 
+	expiring := func(account *models.Account) (time.Duration, bool) {
+		remaining, known := account.CredentialExpiresIn(now)
+		return remaining, known && warning > 0 && remaining <= warning
+	}
 	sort.Slice(accounts, func(i, j int) bool {
+		leftRemaining, leftExpiring := expiring(accounts[i])
+		rightRemaining, rightExpiring := expiring(accounts[j])
+		if leftExpiring != rightExpiring {
+			return rightExpiring
+		}
+		if leftExpiring && leftRemaining != rightRemaining {
+			return leftRemaining > rightRemaining
+		}
+
 		left := lastUsedTime(accounts[i])
 		right := lastUsedTime(accounts[j])
 		if left.Equal(right) {
@@ -963,7 +1212,7 @@ func (s *Service) SyncFromVault(ctx context.Context) (int, error) {
 		if existing, exists := s.accounts[accountID]; exists {
 			// Update existing account
 			existing.CredentialRef = credentialRef
-			existing.UpdatedAt = time.Now().UTC()
+			existing.UpdatedAt = s.clock.Now().UTC()
 			s.mu.Unlock()
 			continue
 		}
@@ -976,7 +1225,7 @@ func (s *Service) SyncFromVault(ctx context.Context) (int, error) {
 			CredentialRef: credentialRef,
 			IsActive:      true,
 			CreatedAt:     profile.CreatedAt,
-			UpdatedAt:     time.Now().UTC(),
+			UpdatedAt:     s.clock.Now().UTC(),
 		}
 		s.accounts[accountID] = account
 		s.mu.Unlock()
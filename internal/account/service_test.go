@@ -6,9 +6,12 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/clock"
 	"github.com/opencode-ai/swarm/internal/config"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/events"
@@ -384,10 +387,112 @@ func TestService_SweepExpiredCooldowns(t *testing.T) {
 	}
 }
 
+func TestService_MutationsAreAudited(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	repo := db.NewAccountRepository(database)
+	service := NewService(config.DefaultConfig(), WithRepository(repo), WithAuditRecorder(audit.New(database, audit.WithActor("dana"))))
+	account := &models.Account{ID: "acct-1", Provider: models.ProviderAnthropic, ProfileName: "acct-1", CredentialRef: "env:ANTHROPIC_API_KEY", IsActive: true}
+	if err := repo.Create(ctx, account); err != nil {
+		t.Fatalf("failed to create account in repo: %v", err)
+	}
+	if err := service.AddAccount(ctx, account); err != nil {
+		t.Fatalf("AddAccount failed: %v", err)
+	}
+	if err := service.SetCooldown(ctx, account.ID, time.Minute); err != nil {
+		t.Fatalf("SetCooldown failed: %v", err)
+	}
+	if err := service.ClearCooldown(ctx, account.ID); err != nil {
+		t.Fatalf("ClearCooldown failed: %v", err)
+	}
+	if err := service.SetCooldownForRateLimit(ctx, account.ID, "429 from provider"); err != nil {
+		t.Fatalf("SetCooldownForRateLimit failed: %v", err)
+	}
+	if _, err := service.RotateAccount(ctx, account.ID); !errors.Is(err, ErrNoAvailableAccount) {
+		t.Fatalf("expected ErrNoAvailableAccount, got %v", err)
+	}
+	if err := service.DeleteAccount(ctx, account.ID); err != nil {
+		t.Fatalf("DeleteAccount failed: %v", err)
+	}
+	if err := service.DeleteAccount(ctx, account.ID); !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("expected ErrAccountNotFound, got %v", err)
+	}
+
+	records, err := db.NewAuditRepository(database).Query(ctx, db.AuditQuery{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	want := []string{AuditAddAccount, AuditSetCooldown, AuditClearCooldown, AuditSetCooldown, AuditRotateAccount, AuditDeleteAccount, AuditDeleteAccount}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d audit records, got %d: %+v", len(want), len(records), records)
+	}
+	for i, op := range want {
+		record := records[len(records)-1-i]
+		if record.Operation != op || record.EntityID != account.ID || record.EntityType != models.EntityTypeAccount {
+			t.Errorf("record %d: got %s on %s, want %s on %s", i, record.Operation, record.EntityID, op, account.ID)
+		}
+	}
+	if limited := records[3]; limited.Parameters["reason"] != "429 from provider" {
+		t.Errorf("expected the rate limit reason recorded, got %+v", limited.Parameters)
+	}
+	if records[0].Result != models.AuditResultFailure {
+		t.Errorf("expected the second delete recorded as a failure, got %+v", records[0])
+	}
+}
+
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
 
+func TestService_CooldownSurvivesWallClockJumps(t *testing.T) {
+	cfg := config.DefaultConfig()
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	service := NewService(cfg, WithClock(fake))
+	ctx := context.Background()
+
+	account := &models.Account{
+		Provider:      models.ProviderOpenAI,
+		ProfileName:   "primary",
+		CredentialRef: "env:OPENAI_API_KEY",
+		IsActive:      true,
+	}
+	if err := service.AddAccount(ctx, account); err != nil {
+		t.Fatalf("AddAccount failed: %v", err)
+	}
+	if err := service.SetCooldown(ctx, account.ID, 10*time.Minute); err != nil {
+		t.Fatalf("SetCooldown failed: %v", err)
+	}
+
+	fake.Jump(2 * time.Hour)
+	onCooldown, remaining, err := service.IsOnCooldown(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("IsOnCooldown failed: %v", err)
+	}
+	if !onCooldown || remaining != 10*time.Minute {
+		t.Fatalf("expected 10m cooldown after forward jump, got %v/%v", onCooldown, remaining)
+	}
+	if cleared, _ := service.SweepExpiredCooldowns(ctx); cleared != 0 {
+		t.Fatalf("expected no cooldowns cleared after wall jump, got %d", cleared)
+	}
+
+	fake.Jump(-4 * time.Hour)
+	fake.Advance(10 * time.Minute)
+	if cleared, err := service.SweepExpiredCooldowns(ctx); err != nil || cleared != 1 {
+		t.Fatalf("expected 1 cooldown cleared, got %d (err=%v)", cleared, err)
+	}
+	if _, err := service.GetAvailable(ctx, models.ProviderOpenAI); err != nil {
+		t.Fatalf("expected account available after cooldown, got %v", err)
+	}
+}
+
 func TestService_RotateAccountForAgent_EmitsEvent(t *testing.T) {
 	cfg := config.DefaultConfig()
 	ctx := context.Background()
@@ -762,3 +867,142 @@ func TestAdapterToProvider(t *testing.T) {
 		})
 	}
 }
+
+func writeCaamCredentials(t *testing.T, vaultPath, email string, expiresAt time.Time) {
+	t.Helper()
+	dir := filepath.Join(vaultPath, "claude", email)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed to create caam profile: %v", err)
+	}
+	data := []byte(`{"claudeAiOauth": {"accessToken": "x", "expiresAt": ` + strconv.FormatInt(expiresAt.UnixMilli(), 10) + `}}`)
+	if err := os.WriteFile(filepath.Join(dir, ".credentials.json"), data, 0o600); err != nil {
+		t.Fatalf("failed to write caam credentials: %v", err)
+	}
+}
+
+func TestService_SweepCredentialExpiry(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	repo := db.NewAccountRepository(database)
+
+	vaultPath := t.TempDir()
+	writeCaamCredentials(t, vaultPath, "alice@example.com", now.Add(2*time.Hour))
+	writeCaamCredentials(t, vaultPath, "bob@example.com", now.Add(72*time.Hour))
+
+	newService := func(publisher *testPublisher) *Service {
+		service := NewService(cfg, WithRepository(repo), WithPublisher(publisher), WithClock(fake), WithCaamVaultPath(vaultPath))
+		for _, email := range []string{"alice@example.com", "bob@example.com"} {
+			account := &models.Account{
+				ID:            email,
+				Provider:      models.ProviderAnthropic,
+				ProfileName:   email,
+				CredentialRef: "caam:claude/" + email,
+				IsActive:      true,
+			}
+			if err := service.AddAccount(ctx, account); err != nil {
+				t.Fatalf("AddAccount failed: %v", err)
+			}
+		}
+		return service
+	}
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		if err := repo.Create(ctx, &models.Account{ID: email, Provider: models.ProviderAnthropic, ProfileName: email, CredentialRef: "caam:claude/" + email, IsActive: true}); err != nil {
+			t.Fatalf("failed to create account in repo: %v", err)
+		}
+	}
+
+	publisher := &testPublisher{}
+	service := newService(publisher)
+	for i := 0; i < 2; i++ {
+		if _, err := service.SweepCredentialExpiry(ctx); err != nil {
+			t.Fatalf("SweepCredentialExpiry failed: %v", err)
+		}
+	}
+	if len(publisher.events) != 1 {
+		t.Fatalf("expected 1 event across two sweeps, got %d", len(publisher.events))
+	}
+	event := publisher.events[0]
+	var payload models.CredentialExpiringPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if event.Type != models.EventTypeCredentialExpiring || payload.AccountID != "alice@example.com" ||
+		payload.RemainingSeconds != 7200 || payload.ThresholdSeconds != 86400 {
+		t.Fatalf("unexpected event %s: %+v", event.Type, payload)
+	}
+	stored, err := repo.Get(ctx, "bob@example.com")
+	if err != nil {
+		t.Fatalf("failed to get account from repo: %v", err)
+	}
+	if stored.CredentialExpiresAt == nil || !stored.CredentialExpiresAt.Equal(now.Add(72*time.Hour)) {
+		t.Fatalf("expected the expiry persisted, got %v", stored.CredentialExpiresAt)
+	}
+
+	// Another process sweeping the same accounts does not warn again.
+	other := &testPublisher{}
+	if warned, err := newService(other).SweepCredentialExpiry(ctx); err != nil || warned != 0 || len(other.events) != 0 {
+		t.Fatalf("expected no second warning, got %d (err=%v)", warned, err)
+	}
+
+	// Refreshed credentials are warned about once they near expiry in turn.
+	writeCaamCredentials(t, vaultPath, "alice@example.com", now.Add(3*time.Hour))
+	if warned, err := service.SweepCredentialExpiry(ctx); err != nil || warned != 1 {
+		t.Fatalf("expected a warning for the new expiry, got %d (err=%v)", warned, err)
+	}
+	if len(publisher.events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(publisher.events))
+	}
+}
+
+func TestService_RotationPrefersLongerValidity(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	service := NewService(cfg, WithClock(clock.NewFake(now)))
+
+	accounts := []*models.Account{
+		{ProfileName: "current"},
+		// Least recently used, but expiring within the warning threshold.
+		{ProfileName: "expiring-soon", CredentialExpiresAt: timePtr(now.Add(1 * time.Hour)), UsageStats: &models.UsageStats{LastUsed: timePtr(now.Add(-3 * time.Hour))}},
+		{ProfileName: "expiring-later", CredentialExpiresAt: timePtr(now.Add(6 * time.Hour)), UsageStats: &models.UsageStats{LastUsed: timePtr(now.Add(-2 * time.Hour))}},
+		{ProfileName: "valid", CredentialExpiresAt: timePtr(now.Add(72 * time.Hour)), UsageStats: &models.UsageStats{LastUsed: timePtr(now.Add(-1 * time.Hour))}},
+	}
+	for _, acct := range accounts {
+		acct.Provider = models.ProviderAnthropic
+		acct.CredentialRef = "env:ANTHROPIC_API_KEY"
+		acct.IsActive = true
+		if err := service.AddAccount(ctx, acct); err != nil {
+			t.Fatalf("AddAccount failed: %v", err)
+		}
+	}
+
+	for _, want := range []string{"valid", "expiring-later", "expiring-soon"} {
+		got, err := service.RotateAccount(ctx, "current")
+		if err != nil {
+			t.Fatalf("RotateAccount failed: %v", err)
+		}
+		if got.ProfileName != want {
+			t.Fatalf("expected profile %s, got %s", want, got.ProfileName)
+		}
+		if err := service.SetCooldown(ctx, got.ID, time.Hour); err != nil {
+			t.Fatalf("SetCooldown failed: %v", err)
+		}
+	}
+
+	// Without a threshold, rotation is plain least recently used.
+	got := SelectForRotation([]*models.Account{accounts[3], accounts[1]}, now, 0)
+	if got.ProfileName != "expiring-soon" {
+		t.Fatalf("expected least recently used with warnings disabled, got %s", got.ProfileName)
+	}
+}
@@ -0,0 +1,210 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// StatusState summarizes whether an account can take work right now.
+type StatusState string
+
+const (
+	// StatusAvailable means the account is active and not cooling down.
+	StatusAvailable StatusState = "available"
+	// StatusCooldown means the account is active but on cooldown.
+	StatusCooldown StatusState = "cooldown"
+	// StatusInactive means the account is disabled.
+	StatusInactive StatusState = "inactive"
+)
+
+// Status is a point-in-time dashboard view of one account.
+type Status struct {
+	AccountID   string          `json:"account_id"`
+	Provider    models.Provider `json:"provider"`
+	ProfileName string          `json:"profile_name"`
+	IsActive    bool            `json:"is_active"`
+	State       StatusState     `json:"state"`
+
+	// CooldownUntil is set while the account is cooling down.
+	CooldownUntil            *time.Time `json:"cooldown_until,omitempty"`
+	CooldownRemainingSeconds int64      `json:"cooldown_remaining_seconds"`
+
+	// Rotations24h and Rotations7d count account.rotated events in which
+	// the account was either the old or the new account.
+	Rotations24h int `json:"rotations_24h"`
+	Rotations7d  int `json:"rotations_7d"`
+
+	LastUsed *time.Time `json:"last_used,omitempty"`
+
+	// CredentialExpiresAt is when the account's credentials expire, if
+	// known; CredentialExpiresInSeconds is negative once they have.
+	CredentialExpiresAt        *time.Time `json:"credential_expires_at,omitempty"`
+	CredentialExpiresInSeconds int64      `json:"credential_expires_in_seconds,omitempty"`
+
+	// Today's totals (UTC day) from the daily usage cache.
+	TodayTokens    int64 `json:"today_tokens"`
+	TodayCostCents int64 `json:"today_cost_cents"`
+	TodayRequests  int64 `json:"today_requests"`
+}
+
+// StatusSources are the repositories a status report reads from.
+type StatusSources struct {
+	Accounts *db.AccountRepository
+	Events   *db.EventRepository
+	Usage    *db.UsageRepository
+}
+
+// BuildStatus reports every account (optionally one provider's) as of now,
+// sorted with the soonest-available account first: available accounts in
+// least-recently-used order, then cooling accounts by cooldown end, then
+// inactive accounts. Today's usage cache rows are refreshed before reading.
+func BuildStatus(ctx context.Context, src StatusSources, provider *models.Provider, now time.Time) ([]*Status, error) {
+	if src.Accounts == nil || src.Events == nil || src.Usage == nil {
+		return nil, fmt.Errorf("account, event, and usage repositories are required")
+	}
+	now = now.UTC()
+
+	accounts, err := src.Accounts.List(ctx, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	statuses := make([]*Status, 0, len(accounts))
+	byID := make(map[string]*Status, len(accounts))
+	// Rotation payloads may reference an account by ID or by profile name.
+	byRef := make(map[string]*Status, 2*len(accounts))
+	lastUsed := make(map[*Status]time.Time, len(accounts))
+	for _, account := range accounts {
+		status := newStatus(account, now)
+		statuses = append(statuses, status)
+		byID[account.ID] = status
+		byRef[account.ID] = status
+		if account.ProfileName != "" {
+			if _, taken := byRef[account.ProfileName]; !taken {
+				byRef[account.ProfileName] = status
+			}
+		}
+		lastUsed[status] = lastUsedTime(account)
+	}
+
+	if err := countRotations(ctx, src.Events, byRef, now); err != nil {
+		return nil, err
+	}
+
+	today := now.Format("2006-01-02")
+	for _, account := range accounts {
+		if err := src.Usage.UpdateDailyCache(ctx, account.ID, today, account.Provider); err != nil {
+			return nil, err
+		}
+	}
+	daily, err := src.Usage.GetDailyCache(ctx, today)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range daily {
+		status, ok := byID[row.AccountID]
+		if !ok {
+			continue
+		}
+		status.TodayTokens += row.TotalTokens
+		status.TodayCostCents += row.CostCents
+		status.TodayRequests += row.RequestCount
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if ra, rb := stateRank(a.State), stateRank(b.State); ra != rb {
+			return ra < rb
+		}
+		switch a.State {
+		case StatusAvailable:
+			if la, lb := lastUsed[a], lastUsed[b]; !la.Equal(lb) {
+				return la.Before(lb)
+			}
+		case StatusCooldown:
+			if !a.CooldownUntil.Equal(*b.CooldownUntil) {
+				return a.CooldownUntil.Before(*b.CooldownUntil)
+			}
+		}
+		return a.ProfileName < b.ProfileName
+	})
+
+	return statuses, nil
+}
+
+func newStatus(account *models.Account, now time.Time) *Status {
+	status := &Status{
+		AccountID:   account.ID,
+		Provider:    account.Provider,
+		ProfileName: account.ProfileName,
+		IsActive:    account.IsActive,
+		State:       StatusAvailable,
+	}
+	if account.UsageStats != nil && account.UsageStats.LastUsed != nil {
+		used := account.UsageStats.LastUsed.UTC()
+		status.LastUsed = &used
+	}
+	if remaining, known := account.CredentialExpiresIn(now); known {
+		expiresAt := account.CredentialExpiresAt.UTC()
+		status.CredentialExpiresAt = &expiresAt
+		status.CredentialExpiresInSeconds = int64(remaining.Seconds())
+	}
+	if account.CooldownUntil != nil && account.CooldownUntil.After(now) {
+		until := account.CooldownUntil.UTC()
+		status.CooldownUntil = &until
+		status.CooldownRemainingSeconds = int64(until.Sub(now).Seconds())
+		status.State = StatusCooldown
+	}
+	if !account.IsActive {
+		status.State = StatusInactive
+	}
+	return status
+}
+
+func countRotations(ctx context.Context, repo *db.EventRepository, byRef map[string]*Status, now time.Time) error {
+	eventType := models.EventTypeAccountRotated
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+	dayAgo := now.Add(-24 * time.Hour)
+
+	it := repo.Iterate(ctx, db.EventQuery{Type: &eventType, Since: &weekAgo}, 0)
+	for it.Next() {
+		event := it.Event()
+		var payload models.AccountRotatedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			continue
+		}
+		involved := make(map[*Status]bool, 2)
+		for _, ref := range []string{payload.OldAccountID, payload.NewAccountID} {
+			if status, ok := byRef[ref]; ok {
+				involved[status] = true
+			}
+		}
+		for status := range involved {
+			status.Rotations7d++
+			if !event.Timestamp.Before(dayAgo) {
+				status.Rotations24h++
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("failed to read rotation events: %w", err)
+	}
+	return nil
+}
+
+func stateRank(state StatusState) int {
+	switch state {
+	case StatusAvailable:
+		return 0
+	case StatusCooldown:
+		return 1
+	default:
+		return 2
+	}
+}
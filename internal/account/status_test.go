@@ -0,0 +1,127 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestBuildStatus(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	at := func(d time.Duration) *time.Time {
+		ts := now.Add(d)
+		return &ts
+	}
+
+	accountRepo := db.NewAccountRepository(database)
+	seedAccounts := []*models.Account{
+		{Provider: models.ProviderAnthropic, ProfileName: "late", IsActive: true, CooldownUntil: at(2 * time.Hour)},
+		{Provider: models.ProviderAnthropic, ProfileName: "fresh", IsActive: true,
+			UsageStats: &models.UsageStats{LastUsed: at(-time.Minute)}},
+		{Provider: models.ProviderAnthropic, ProfileName: "soon", IsActive: true, CooldownUntil: at(10 * time.Minute)},
+		{Provider: models.ProviderAnthropic, ProfileName: "stale", IsActive: true,
+			UsageStats: &models.UsageStats{LastUsed: at(-3 * time.Hour)}},
+		{Provider: models.ProviderAnthropic, ProfileName: "disabled", IsActive: false},
+		{Provider: models.ProviderOpenAI, ProfileName: "openai", IsActive: true},
+	}
+	byProfile := make(map[string]*models.Account)
+	for _, account := range seedAccounts {
+		if err := accountRepo.Create(ctx, account); err != nil {
+			t.Fatalf("create account %s: %v", account.ProfileName, err)
+		}
+		byProfile[account.ProfileName] = account
+	}
+
+	rotation := func(ts time.Duration, oldRef, newRef string) *models.Event {
+		payload, _ := json.Marshal(models.AccountRotatedPayload{AgentID: "agent-1", OldAccountID: oldRef, NewAccountID: newRef, Reason: "rate_limit"})
+		return &models.Event{Type: models.EventTypeAccountRotated, EntityType: models.EntityTypeAccount, EntityID: newRef,
+			Timestamp: now.Add(ts), Payload: payload}
+	}
+	stale, soon := byProfile["stale"], byProfile["soon"]
+	seedEvents := []*models.Event{
+		rotation(-time.Hour, soon.ID, stale.ID),
+		// Profile names are accepted as account references.
+		rotation(-2*time.Hour, "soon", "late"),
+		rotation(-3*24*time.Hour, soon.ID, stale.ID),
+		// Outside the 7d window.
+		rotation(-8*24*time.Hour, soon.ID, stale.ID),
+	}
+	if err := db.NewEventRepository(database).CreateBatch(ctx, seedEvents); err != nil {
+		t.Fatalf("seed events: %v", err)
+	}
+
+	usageRepo := db.NewUsageRepository(database)
+	for _, record := range []*models.UsageRecord{
+		{AccountID: stale.ID, Provider: models.ProviderAnthropic, InputTokens: 1000, OutputTokens: 500, CostCents: 12, RecordedAt: now},
+		{AccountID: stale.ID, Provider: models.ProviderAnthropic, InputTokens: 100, CostCents: 3, RecordedAt: now},
+		{AccountID: stale.ID, Provider: models.ProviderAnthropic, InputTokens: 9000, CostCents: 90, RecordedAt: now.Add(-48 * time.Hour)},
+	} {
+		if err := usageRepo.Create(ctx, record); err != nil {
+			t.Fatalf("create usage: %v", err)
+		}
+	}
+
+	provider := models.ProviderAnthropic
+	statuses, err := BuildStatus(ctx, StatusSources{Accounts: accountRepo, Events: db.NewEventRepository(database), Usage: usageRepo}, &provider, now)
+	if err != nil {
+		t.Fatalf("BuildStatus: %v", err)
+	}
+
+	wantOrder := []string{"stale", "fresh", "soon", "late", "disabled"}
+	if len(statuses) != len(wantOrder) {
+		t.Fatalf("expected %d statuses, got %d", len(wantOrder), len(statuses))
+	}
+	for i, status := range statuses {
+		if status.ProfileName != wantOrder[i] {
+			var got []string
+			for _, s := range statuses {
+				got = append(got, s.ProfileName)
+			}
+			t.Fatalf("order = %v, want %v", got, wantOrder)
+		}
+	}
+
+	byName := make(map[string]*Status)
+	for _, status := range statuses {
+		byName[status.ProfileName] = status
+	}
+
+	if got := byName["soon"]; got.State != StatusCooldown || got.CooldownRemainingSeconds != 600 || !got.CooldownUntil.Equal(now.Add(10*time.Minute)) {
+		t.Errorf("soon: unexpected cooldown %+v", got)
+	}
+	if got := byName["disabled"]; got.State != StatusInactive || got.IsActive {
+		t.Errorf("disabled: unexpected state %+v", got)
+	}
+	if got := byName["fresh"]; got.State != StatusAvailable || got.LastUsed == nil || !got.LastUsed.Equal(now.Add(-time.Minute)) {
+		t.Errorf("fresh: unexpected status %+v", got)
+	}
+
+	rotations := map[string][2]int{"soon": {2, 3}, "stale": {1, 2}, "late": {1, 1}, "fresh": {0, 0}}
+	for name, want := range rotations {
+		got := byName[name]
+		if got.Rotations24h != want[0] || got.Rotations7d != want[1] {
+			t.Errorf("%s: rotations 24h/7d = %d/%d, want %d/%d", name, got.Rotations24h, got.Rotations7d, want[0], want[1])
+		}
+	}
+
+	if got := byName["stale"]; got.TodayTokens != 1600 || got.TodayCostCents != 15 || got.TodayRequests != 2 {
+		t.Errorf("stale: unexpected today totals %+v", got)
+	}
+	if got := byName["fresh"]; got.TodayTokens != 0 || got.TodayRequests != 0 {
+		t.Errorf("fresh: expected no usage today, got %+v", got)
+	}
+}
@@ -0,0 +1,508 @@
+package account
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// usageImportNamespace seeds the deterministic IDs of imported usage
+// records. Changing it would make every previously imported row import
+// again.
+var usageImportNamespace = uuid.MustParse("5b0f6c57-6f4e-4f39-9a43-3c7a2d1e8b90")
+
+// UsageExportRow is one usage row read from a provider billing export.
+// Record carries everything but the account, which is attributed on import.
+type UsageExportRow struct {
+	// Line is the CSV line, or the position of the result in a JSON export.
+	Line int
+
+	// Key identifies the row among all of the provider's exports: the
+	// usage date plus the dimensions the export breaks usage down by.
+	Key string
+
+	// APIKey is the API key name or ID the usage was billed to, when the
+	// export has one.
+	APIKey string
+
+	Record models.UsageRecord
+}
+
+// UsageExportIssue reports a row that could not be imported.
+type UsageExportIssue struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// UsageExport is a parsed billing export. Malformed rows are reported in
+// Issues and left out of Rows; they do not stop the rest of the file.
+type UsageExport struct {
+	Provider models.Provider
+	Rows     []UsageExportRow
+	Issues   []UsageExportIssue
+}
+
+// ParseUsageExport reads a billing export: the Anthropic Console usage CSV,
+// or the JSON pages of OpenAI's organization usage API.
+func ParseUsageExport(provider models.Provider, r io.Reader) (*UsageExport, error) {
+	switch provider {
+	case models.ProviderAnthropic:
+		return parseAnthropicUsageCSV(r)
+	case models.ProviderOpenAI:
+		return parseOpenAIUsageJSON(r)
+	default:
+		return nil, fmt.Errorf("usage import does not support provider %q (use anthropic or openai)", provider)
+	}
+}
+
+// UsageRecordID returns the ID an imported export row is stored under.
+func UsageRecordID(provider models.Provider, key string) string {
+	return uuid.NewSHA1(usageImportNamespace, []byte(string(provider)+"\x00"+key)).String()
+}
+
+// Anthropic CSV columns, by the header names (and aliases) they appear under.
+var (
+	anthropicDateColumns    = []string{"usage_date_utc", "date"}
+	anthropicModelColumns   = []string{"model", "model_version"}
+	anthropicAPIKeyColumns  = []string{"api_key", "api_key_name", "api_key_id"}
+	anthropicInputColumns   = []string{"input_tokens", "uncached_input_tokens"}
+	anthropicCacheColumns   = []string{"cache_creation_input_tokens", "cache_read_input_tokens"}
+	anthropicOutputColumns  = []string{"output_tokens"}
+	anthropicCostColumns    = []string{"cost_usd", "cost"}
+	anthropicRequestColumns = []string{"requests", "request_count"}
+
+	// anthropicKeyColumns break usage down within a day; with the date
+	// they make a row's key.
+	anthropicKeyColumns = []string{"workspace", "api_key", "api_key_name", "api_key_id", "model", "model_version", "usage_type", "context_window", "service_tier"}
+)
+
+// csvColumns maps lower-cased CSV header names to their index.
+type csvColumns map[string]int
+
+// find returns the index of the first alias present, or -1.
+func (c csvColumns) find(aliases ...string) int {
+	for _, alias := range aliases {
+		if i, ok := c[alias]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseAnthropicUsageCSV(r io.Reader) (*UsageExport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("usage export is empty")
+		}
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(csvColumns, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	required := []struct {
+		name    string
+		aliases []string
+	}{
+		{"usage_date_utc", anthropicDateColumns},
+		{"model", anthropicModelColumns},
+		{"input_tokens", anthropicInputColumns},
+		{"output_tokens", anthropicOutputColumns},
+	}
+	for _, column := range required {
+		if columns.find(column.aliases...) < 0 {
+			return nil, fmt.Errorf("usage export is missing the %s column", column.name)
+		}
+	}
+
+	export := &UsageExport{Provider: models.ProviderAnthropic}
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("failed to read usage export: %w", err)
+			}
+			export.Issues = append(export.Issues, UsageExportIssue{Line: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if len(fields) != len(header) {
+			export.Issues = append(export.Issues, UsageExportIssue{Line: line, Message: fmt.Sprintf("expected %d fields, got %d", len(header), len(fields))})
+			continue
+		}
+		row, err := anthropicUsageRow(columns, fields)
+		if err != nil {
+			export.Issues = append(export.Issues, UsageExportIssue{Line: line, Message: err.Error()})
+			continue
+		}
+		row.Line = line
+		export.Rows = append(export.Rows, row)
+	}
+	return export, nil
+}
+
+func anthropicUsageRow(columns csvColumns, fields []string) (UsageExportRow, error) {
+	field := func(aliases ...string) string {
+		if i := columns.find(aliases...); i >= 0 {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	recordedAt, err := parseExportDate(field(anthropicDateColumns...))
+	if err != nil {
+		return UsageExportRow{}, err
+	}
+	input, err := parseExportTokens("input_tokens", field(anthropicInputColumns...))
+	if err != nil {
+		return UsageExportRow{}, err
+	}
+	for _, name := range anthropicCacheColumns {
+		cached, err := parseExportTokens(name, field(name))
+		if err != nil {
+			return UsageExportRow{}, err
+		}
+		input += cached
+	}
+	output, err := parseExportTokens("output_tokens", field(anthropicOutputColumns...))
+	if err != nil {
+		return UsageExportRow{}, err
+	}
+	costCents, err := parseExportDollars(field(anthropicCostColumns...))
+	if err != nil {
+		return UsageExportRow{}, err
+	}
+	requests, err := parseExportTokens("requests", field(anthropicRequestColumns...))
+	if err != nil {
+		return UsageExportRow{}, err
+	}
+
+	key := []string{recordedAt.Format(time.DateOnly)}
+	for _, name := range anthropicKeyColumns {
+		if i, ok := columns[name]; ok {
+			key = append(key, name+"="+strings.TrimSpace(fields[i]))
+		}
+	}
+	apiKey := field(anthropicAPIKeyColumns...)
+	return UsageExportRow{
+		Key:    strings.Join(key, "|"),
+		APIKey: apiKey,
+		Record: models.UsageRecord{
+			Provider:     models.ProviderAnthropic,
+			Model:        field(anthropicModelColumns...),
+			InputTokens:  input,
+			OutputTokens: output,
+			TotalTokens:  input + output,
+			CostCents:    costCents,
+			RequestCount: requests,
+			RecordedAt:   recordedAt,
+			Metadata:     importMetadata(apiKey, "workspace", field("workspace")),
+		},
+	}, nil
+}
+
+// openAIUsagePage is a page of bucketed results from OpenAI's organization
+// usage (completions) or costs API.
+type openAIUsagePage struct {
+	Data []struct {
+		StartTime int64               `json:"start_time"`
+		Results   []openAIUsageResult `json:"results"`
+	} `json:"data"`
+}
+
+type openAIUsageResult struct {
+	Object            string  `json:"object"`
+	Model             *string `json:"model"`
+	ProjectID         *string `json:"project_id"`
+	APIKeyID          *string `json:"api_key_id"`
+	UserID            *string `json:"user_id"`
+	Batch             *bool   `json:"batch"`
+	LineItem          *string `json:"line_item"`
+	InputTokens       *int64  `json:"input_tokens"`
+	OutputTokens      *int64  `json:"output_tokens"`
+	NumModelRequests  *int64  `json:"num_model_requests"`
+	InputCachedTokens *int64  `json:"input_cached_tokens"`
+	Amount            *struct {
+		Value    json.Number `json:"value"`
+		Currency string      `json:"currency"`
+	} `json:"amount"`
+}
+
+func parseOpenAIUsageJSON(r io.Reader) (*UsageExport, error) {
+	export := &UsageExport{Provider: models.ProviderOpenAI}
+
+	// Exports are one page, or several pages saved as an array or one
+	// after another.
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var pages []openAIUsagePage
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse usage export: %w", err)
+		}
+		if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "[") {
+			var many []openAIUsagePage
+			if err := json.Unmarshal(raw, &many); err != nil {
+				return nil, fmt.Errorf("failed to parse usage export: %w", err)
+			}
+			pages = append(pages, many...)
+			continue
+		}
+		var page openAIUsagePage
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse usage export: %w", err)
+		}
+		pages = append(pages, page)
+	}
+	if len(pages) == 0 {
+		return nil, errors.New("usage export is empty")
+	}
+
+	position := 0
+	for _, page := range pages {
+		for _, bucket := range page.Data {
+			for _, result := range bucket.Results {
+				position++
+				row, err := openAIUsageRow(bucket.StartTime, result)
+				if err != nil {
+					export.Issues = append(export.Issues, UsageExportIssue{Line: position, Message: err.Error()})
+					continue
+				}
+				row.Line = position
+				export.Rows = append(export.Rows, row)
+			}
+		}
+	}
+	return export, nil
+}
+
+func openAIUsageRow(startTime int64, result openAIUsageResult) (UsageExportRow, error) {
+	if startTime <= 0 {
+		return UsageExportRow{}, errors.New("bucket has no start_time")
+	}
+	if result.InputTokens == nil && result.OutputTokens == nil && result.Amount == nil {
+		return UsageExportRow{}, fmt.Errorf("result %q has neither token counts nor an amount", result.Object)
+	}
+	value := func(v *int64) int64 {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	text := func(v *string) string {
+		if v == nil {
+			return ""
+		}
+		return *v
+	}
+	input, output := value(result.InputTokens), value(result.OutputTokens)
+	if input < 0 || output < 0 || value(result.NumModelRequests) < 0 {
+		return UsageExportRow{}, errors.New("token and request counts must be non-negative")
+	}
+
+	var costCents int64
+	if result.Amount != nil {
+		if currency := strings.ToLower(result.Amount.Currency); currency != "" && currency != "usd" {
+			return UsageExportRow{}, fmt.Errorf("unsupported currency %q", result.Amount.Currency)
+		}
+		cents, err := parseExportDollars(result.Amount.Value.String())
+		if err != nil {
+			return UsageExportRow{}, err
+		}
+		costCents = cents
+	}
+
+	recordedAt := time.Unix(startTime, 0).UTC()
+	key := []string{
+		strconv.FormatInt(startTime, 10),
+		"object=" + result.Object,
+		"model=" + text(result.Model),
+		"project_id=" + text(result.ProjectID),
+		"api_key_id=" + text(result.APIKeyID),
+		"user_id=" + text(result.UserID),
+		"line_item=" + text(result.LineItem),
+	}
+	if result.Batch != nil {
+		key = append(key, "batch="+strconv.FormatBool(*result.Batch))
+	}
+	row := UsageExportRow{
+		Key:    strings.Join(key, "|"),
+		APIKey: text(result.APIKeyID),
+		Record: models.UsageRecord{
+			Provider:     models.ProviderOpenAI,
+			Model:        text(result.Model),
+			InputTokens:  input,
+			OutputTokens: output,
+			TotalTokens:  input + output,
+			CostCents:    costCents,
+			RequestCount: value(result.NumModelRequests),
+			RecordedAt:   recordedAt,
+		},
+	}
+	row.Record.Metadata = importMetadata(row.APIKey, "project_id", text(result.ProjectID))
+	return row, nil
+}
+
+func importMetadata(apiKey, scopeName, scope string) map[string]string {
+	metadata := map[string]string{"source": "billing_export"}
+	if apiKey != "" {
+		metadata["api_key"] = apiKey
+	}
+	if scope != "" {
+		metadata[scopeName] = scope
+	}
+	return metadata
+}
+
+func parseExportDate(value string) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, time.RFC3339, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid usage date %q", value)
+}
+
+// parseExportTokens parses a non-negative count; empty counts as zero.
+func parseExportTokens(name, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(value, ",", ""), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return n, nil
+}
+
+// parseExportDollars converts a USD amount to cents, rounding to the
+// nearest cent; empty counts as zero.
+func parseExportDollars(value string) (int64, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "$")
+	if value == "" {
+		return 0, nil
+	}
+	dollars, err := strconv.ParseFloat(value, 64)
+	if err != nil || dollars < 0 || math.IsInf(dollars, 0) || math.IsNaN(dollars) {
+		return 0, fmt.Errorf("invalid cost %q", value)
+	}
+	return int64(math.Round(dollars * 100)), nil
+}
+
+// UsageImportSummary reports what an import did with each row.
+type UsageImportSummary struct {
+	Provider models.Provider `json:"provider"`
+	Rows     int             `json:"rows"`
+
+	// Imported rows were new; Skipped rows had been imported before.
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+
+	// Unmatched rows could not be attributed to an account.
+	Unmatched int `json:"unmatched"`
+
+	// Invalid rows were malformed.
+	Invalid int `json:"invalid"`
+
+	ImportedCostCents int64 `json:"imported_cost_cents"`
+
+	// UnmatchedKeys lists the API keys of unmatched rows.
+	UnmatchedKeys []string           `json:"unmatched_keys,omitempty"`
+	Issues        []UsageExportIssue `json:"issues,omitempty"`
+
+	// UnknownModels lists the imported models missing from the provider's
+	// model catalog. They are imported like any other.
+	UnknownModels []string `json:"unknown_models,omitempty"`
+}
+
+// ImportUsage stores the rows of an export as usage records. attribute
+// returns the account ID for a row's API key, or "" to leave the row
+// unmatched. Each row is stored under UsageRecordID, so rows imported
+// before are skipped rather than counted twice. The daily usage cache rows
+// of the days imported into are recomputed, so backfilled days read right
+// from the cache at once.
+func ImportUsage(ctx context.Context, repo *db.UsageRepository, export *UsageExport, attribute func(apiKey string) string) (summary *UsageImportSummary, err error) {
+	summary = &UsageImportSummary{
+		Provider: export.Provider,
+		Rows:     len(export.Rows) + len(export.Issues),
+		Invalid:  len(export.Issues),
+		Issues:   export.Issues,
+	}
+	unmatched := make(map[string]bool)
+	unknownModels := make(map[string]bool)
+	touched := make(map[importedDay]bool)
+	defer func() {
+		for day := range touched {
+			if cacheErr := repo.UpdateDailyCache(ctx, day.accountID, day.date, day.provider); cacheErr != nil && err == nil {
+				err = cacheErr
+			}
+		}
+	}()
+	for _, row := range export.Rows {
+		accountID := attribute(row.APIKey)
+		if accountID == "" {
+			summary.Unmatched++
+			unmatched[row.APIKey] = true
+			continue
+		}
+
+		record := row.Record
+		record.ID = UsageRecordID(export.Provider, row.Key)
+		record.AccountID = accountID
+		inserted, err := repo.CreateIfAbsent(ctx, &record)
+		if err != nil {
+			return summary, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+		if !inserted {
+			summary.Skipped++
+			continue
+		}
+		summary.Imported++
+		summary.ImportedCostCents += record.CostCents
+		touched[importedDay{record.AccountID, record.RecordedAt.UTC().Format("2006-01-02"), record.Provider}] = true
+		if !models.IsKnownModel(record.Provider, record.Model) {
+			unknownModels[record.Model] = true
+		}
+	}
+	for key := range unmatched {
+		summary.UnmatchedKeys = append(summary.UnmatchedKeys, key)
+	}
+	sort.Strings(summary.UnmatchedKeys)
+	for model := range unknownModels {
+		summary.UnknownModels = append(summary.UnknownModels, model)
+	}
+	sort.Strings(summary.UnknownModels)
+	return summary, nil
+}
+
+// importedDay is a daily usage cache row an import added records to.
+type importedDay struct {
+	accountID string
+	date      string
+	provider  models.Provider
+}
@@ -0,0 +1,275 @@
+package account
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func parseFixture(t *testing.T, provider models.Provider, name string) *UsageExport {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	defer file.Close()
+	export, err := ParseUsageExport(provider, file)
+	if err != nil {
+		t.Fatalf("ParseUsageExport: %v", err)
+	}
+	return export
+}
+
+func TestParseUsageExport(t *testing.T) {
+	type wantRow struct {
+		line      int
+		apiKey    string
+		model     string
+		input     int64
+		output    int64
+		costCents int64
+		requests  int64
+		date      string
+	}
+	tests := []struct {
+		name       string
+		provider   models.Provider
+		fixture    string
+		wantRows   []wantRow
+		wantIssues map[int]string
+	}{
+		{
+			name:     "anthropic csv",
+			provider: models.ProviderAnthropic,
+			fixture:  "anthropic_usage.csv",
+			wantRows: []wantRow{
+				{line: 2, apiKey: "swarm-ci-4f2a", model: "claude-sonnet-4-5", input: 145000, output: 30000, costCents: 82, date: "2026-03-01"},
+				{line: 3, apiKey: "swarm-dev-9b1c", model: "claude-sonnet-4-5", input: 40000, output: 10000, costCents: 27, date: "2026-03-01"},
+				{line: 4, apiKey: "swarm-dev-9b1c", model: "claude-haiku-4-5", input: 1000000, output: 200000, costCents: 100, date: "2026-03-01"},
+				{line: 9, apiKey: "legacy-key-0000", model: "claude-opus-4-1", input: 5000, output: 1000, costCents: 15, date: "2026-03-02"},
+			},
+			wantIssues: map[int]string{
+				5: `invalid input_tokens "not-a-number"`,
+				6: "expected 10 fields, got 8",
+				7: `invalid usage date "03/02/2026"`,
+				8: `invalid cost "-2.00"`,
+			},
+		},
+		{
+			name:     "openai json",
+			provider: models.ProviderOpenAI,
+			fixture:  "openai_usage.json",
+			wantRows: []wantRow{
+				{line: 1, apiKey: "key_swarmci7d3e", model: "gpt-5", input: 52000, output: 8000, requests: 41, date: "2026-03-01"},
+				{line: 2, apiKey: "key_other0001", model: "gpt-5-mini", input: 3000, output: 700, requests: 2, date: "2026-03-01"},
+				{line: 3, costCents: 1235, date: "2026-03-02"},
+			},
+			wantIssues: map[int]string{
+				4: "must be non-negative",
+				5: `unsupported currency "eur"`,
+				6: "neither token counts nor an amount",
+				7: "no start_time",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export := parseFixture(t, tt.provider, tt.fixture)
+			if len(export.Rows) != len(tt.wantRows) {
+				t.Fatalf("expected %d rows, got %d (issues %+v)", len(tt.wantRows), len(export.Rows), export.Issues)
+			}
+			keys := make(map[string]bool)
+			for i, want := range tt.wantRows {
+				row := export.Rows[i]
+				record := row.Record
+				if row.Line != want.line || row.APIKey != want.apiKey || record.Model != want.model ||
+					record.InputTokens != want.input || record.OutputTokens != want.output ||
+					record.TotalTokens != want.input+want.output || record.CostCents != want.costCents ||
+					record.RequestCount != want.requests || record.RecordedAt.Format(time.DateOnly) != want.date {
+					t.Errorf("row %d: got line %d key %q %+v, want %+v", i, row.Line, row.APIKey, record, want)
+				}
+				if record.Provider != tt.provider || record.Metadata["source"] != "billing_export" {
+					t.Errorf("row %d: expected provider and source set, got %+v", i, record)
+				}
+				if keys[row.Key] {
+					t.Errorf("row %d: duplicate key %q", i, row.Key)
+				}
+				keys[row.Key] = true
+			}
+
+			if len(export.Issues) != len(tt.wantIssues) {
+				t.Fatalf("expected %d issues, got %+v", len(tt.wantIssues), export.Issues)
+			}
+			for _, issue := range export.Issues {
+				want, ok := tt.wantIssues[issue.Line]
+				if !ok || !strings.Contains(issue.Message, want) {
+					t.Errorf("line %d: got %q, want %q", issue.Line, issue.Message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseUsageExportRejectsUnreadableFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider models.Provider
+		input    string
+		wantErr  string
+	}{
+		{name: "empty csv", provider: models.ProviderAnthropic, input: "", wantErr: "empty"},
+		{name: "missing column", provider: models.ProviderAnthropic, input: "usage_date_utc,model,input_tokens\n", wantErr: "output_tokens column"},
+		{name: "empty json", provider: models.ProviderOpenAI, input: "  ", wantErr: "empty"},
+		{name: "broken json", provider: models.ProviderOpenAI, input: `{"data": [`, wantErr: "failed to parse"},
+		{name: "unsupported provider", provider: models.ProviderGoogle, input: "", wantErr: "does not support"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseUsageExport(tt.provider, strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseUsageExportKeysAreStable(t *testing.T) {
+	first := parseFixture(t, models.ProviderOpenAI, "openai_usage.json")
+
+	// Several pages saved as an array read the same rows under the same keys.
+	data, err := os.ReadFile(filepath.Join("testdata", "openai_usage.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	second, err := ParseUsageExport(models.ProviderOpenAI, strings.NewReader("["+string(data)+"]"))
+	if err != nil {
+		t.Fatalf("ParseUsageExport: %v", err)
+	}
+	if len(second.Rows) != len(first.Rows) {
+		t.Fatalf("expected %d rows, got %d", len(first.Rows), len(second.Rows))
+	}
+	for i := range first.Rows {
+		if first.Rows[i].Key != second.Rows[i].Key {
+			t.Errorf("row %d: key changed from %q to %q", i, first.Rows[i].Key, second.Rows[i].Key)
+		}
+		if UsageRecordID(models.ProviderOpenAI, first.Rows[i].Key) == UsageRecordID(models.ProviderAnthropic, first.Rows[i].Key) {
+			t.Errorf("row %d: expected record IDs to differ by provider", i)
+		}
+	}
+}
+
+func TestImportUsageIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	acct := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "ci"}
+	if err := db.NewAccountRepository(database).Create(ctx, acct); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	repo := db.NewUsageRepository(database)
+
+	export := parseFixture(t, models.ProviderAnthropic, "anthropic_usage.csv")
+	attribute := func(apiKey string) string {
+		if strings.HasPrefix(apiKey, "swarm-") {
+			return acct.ID
+		}
+		return ""
+	}
+
+	first, err := ImportUsage(ctx, repo, export, attribute)
+	if err != nil {
+		t.Fatalf("ImportUsage: %v", err)
+	}
+	if first.Rows != 8 || first.Imported != 3 || first.Skipped != 0 || first.Unmatched != 1 || first.Invalid != 4 || first.ImportedCostCents != 209 {
+		t.Fatalf("unexpected first import %+v", first)
+	}
+	if len(first.UnmatchedKeys) != 1 || first.UnmatchedKeys[0] != "legacy-key-0000" {
+		t.Errorf("expected the unmatched key listed, got %v", first.UnmatchedKeys)
+	}
+	totals, err := repo.SummarizeAll(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("SummarizeAll: %v", err)
+	}
+
+	second, err := ImportUsage(ctx, repo, parseFixture(t, models.ProviderAnthropic, "anthropic_usage.csv"), attribute)
+	if err != nil {
+		t.Fatalf("ImportUsage: %v", err)
+	}
+	if second.Imported != 0 || second.Skipped != first.Imported || second.Unmatched != first.Unmatched || second.Invalid != first.Invalid {
+		t.Fatalf("expected the second import to skip every row, got %+v", second)
+	}
+	again, err := repo.SummarizeAll(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("SummarizeAll: %v", err)
+	}
+	if *again != *totals {
+		t.Fatalf("expected re-importing to leave usage unchanged, got %+v then %+v", totals, again)
+	}
+	if totals.TotalCostCents != 209 || totals.RecordCount != 3 {
+		t.Errorf("unexpected totals %+v", totals)
+	}
+}
+
+func TestImportUsageRefreshesBackfilledDays(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	acct := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "ci"}
+	if err := db.NewAccountRepository(database).Create(ctx, acct); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	repo := db.NewUsageRepository(database)
+
+	// Usage agents reported on a day the export also covers, cached before
+	// the import.
+	reported := &models.UsageRecord{AccountID: acct.ID, Provider: models.ProviderAnthropic, InputTokens: 1000, CostCents: 50, RecordedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)}
+	if err := repo.Create(ctx, reported); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.RefreshDailyCache(ctx, time.Now()); err != nil {
+		t.Fatalf("RefreshDailyCache: %v", err)
+	}
+
+	attribute := func(string) string { return acct.ID }
+	if _, err := ImportUsage(ctx, repo, parseFixture(t, models.ProviderAnthropic, "anthropic_usage.csv"), attribute); err != nil {
+		t.Fatalf("ImportUsage: %v", err)
+	}
+
+	for _, date := range []string{"2026-03-01", "2026-03-02"} {
+		from, _ := time.Parse("2006-01-02", date)
+		until := from.AddDate(0, 0, 1)
+		raw, err := repo.SummarizeByAccount(ctx, acct.ID, &from, &until)
+		if err != nil {
+			t.Fatalf("SummarizeByAccount: %v", err)
+		}
+		cached, err := repo.SummarizeDailyCache(ctx, acct.ID, "", date, until.Format("2006-01-02"))
+		if err != nil {
+			t.Fatalf("SummarizeDailyCache: %v", err)
+		}
+		if cached.TotalCostCents != raw.TotalCostCents || cached.TotalTokens != raw.TotalTokens || cached.RecordCount != raw.RecordCount {
+			t.Errorf("%s: expected the cache to match the records %+v, got %+v", date, raw, cached)
+		}
+		if raw.RecordCount < 1 {
+			t.Errorf("%s: expected imported records", date)
+		}
+	}
+}
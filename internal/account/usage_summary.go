@@ -0,0 +1,158 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/store"
+)
+
+// UsageScope selects the usage a summary covers: one account, one
+// provider, or all usage when both are empty.
+type UsageScope struct {
+	AccountID string
+	Provider  models.Provider
+}
+
+// UsageReport is a usage summary and, when it was read from the daily
+// usage cache, what the cache covered.
+type UsageReport struct {
+	models.UsageSummary
+
+	// Cached is set when whole days were read from the daily usage cache.
+	Cached bool `json:"cached"`
+
+	// CacheFrom and CacheUntil bound the days read from the cache
+	// (YYYY-MM-DD, CacheUntil exclusive). An empty CacheFrom means every
+	// day before CacheUntil.
+	CacheFrom  string `json:"cache_from,omitempty"`
+	CacheUntil string `json:"cache_until,omitempty"`
+
+	// CacheRefreshedAt is when the cache was last refreshed; usage stored
+	// since then is missing from the cached days until the next refresh.
+	CacheRefreshedAt *time.Time `json:"cache_refreshed_at,omitempty"`
+}
+
+// SummarizeUsage returns the usage in scope recorded in [since, until),
+// either bound being optional. With cached set, the whole UTC days of the
+// range are read from the daily usage cache and only the partial days at
+// either end from the usage records; an open end runs to now. A fresh
+// cache gives the same figures as the records.
+func SummarizeUsage(ctx context.Context, repo store.UsageStore, scope UsageScope, since, until *time.Time, cached bool, now time.Time) (*UsageReport, error) {
+	if scope.AccountID != "" && scope.Provider != "" {
+		return nil, fmt.Errorf("usage can be summarized by account or by provider, not both")
+	}
+
+	report := &UsageReport{}
+	if !cached {
+		summary, err := summarizeRecords(ctx, repo, scope, since, until)
+		if err != nil {
+			return nil, err
+		}
+		report.UsageSummary = *summary
+		return report, nil
+	}
+
+	// Whole days run from the first midnight at or after since to the last
+	// midnight at or before until.
+	var firstDay *time.Time
+	if since != nil {
+		day := startOfDay(*since, time.UTC)
+		if day.Before(since.UTC()) {
+			day = day.AddDate(0, 0, 1)
+		}
+		firstDay = &day
+	}
+	end := now
+	if until != nil {
+		end = *until
+	}
+	lastDay := startOfDay(end, time.UTC)
+
+	if firstDay != nil && !firstDay.Before(lastDay) {
+		// No whole day in range.
+		summary, err := summarizeRecords(ctx, repo, scope, since, until)
+		if err != nil {
+			return nil, err
+		}
+		report.UsageSummary = *summary
+		return report, nil
+	}
+
+	var parts []*models.UsageSummary
+	if since != nil && since.Before(*firstDay) {
+		head, err := summarizeRecords(ctx, repo, scope, since, firstDay)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, head)
+	}
+
+	report.Cached = true
+	if firstDay != nil {
+		report.CacheFrom = firstDay.Format("2006-01-02")
+	}
+	report.CacheUntil = lastDay.Format("2006-01-02")
+	days, err := repo.SummarizeDailyCache(ctx, scope.AccountID, scope.Provider, report.CacheFrom, report.CacheUntil)
+	if err != nil {
+		return nil, err
+	}
+	parts = append(parts, days)
+
+	if until == nil || until.After(lastDay) {
+		tail, err := summarizeRecords(ctx, repo, scope, &lastDay, until)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, tail)
+	}
+
+	refreshedAt, err := repo.DailyCacheRefreshedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.CacheRefreshedAt = refreshedAt
+
+	report.UsageSummary = models.UsageSummary{
+		AccountID: scope.AccountID,
+		Provider:  scope.Provider,
+		Period:    usagePeriod(scope),
+	}
+	if since != nil {
+		report.PeriodStart = *since
+	}
+	if until != nil {
+		report.PeriodEnd = *until
+	}
+	for _, part := range parts {
+		report.InputTokens += part.InputTokens
+		report.OutputTokens += part.OutputTokens
+		report.TotalTokens += part.TotalTokens
+		report.TotalCostCents += part.TotalCostCents
+		report.RequestCount += part.RequestCount
+		report.RecordCount += part.RecordCount
+	}
+	return report, nil
+}
+
+// summarizeRecords summarizes the usage records in scope.
+func summarizeRecords(ctx context.Context, repo store.UsageStore, scope UsageScope, since, until *time.Time) (*models.UsageSummary, error) {
+	switch {
+	case scope.AccountID != "":
+		return repo.SummarizeByAccount(ctx, scope.AccountID, since, until)
+	case scope.Provider != "":
+		return repo.SummarizeByProvider(ctx, scope.Provider, since, until)
+	default:
+		return repo.SummarizeAll(ctx, since, until)
+	}
+}
+
+// usagePeriod is the Period the record summaries report for scope.
+func usagePeriod(scope UsageScope) string {
+	if scope.AccountID == "" && scope.Provider == "" {
+		return "all"
+	}
+	return "custom"
+}
@@ -0,0 +1,112 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestSummarizeUsageCachedMatchesRecords(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	accounts := db.NewAccountRepository(database)
+	primary := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "primary"}
+	secondary := &models.Account{Provider: models.ProviderOpenAI, ProfileName: "secondary"}
+	for _, acct := range []*models.Account{primary, secondary} {
+		if err := accounts.Create(ctx, acct); err != nil {
+			t.Fatalf("create account: %v", err)
+		}
+	}
+	repo := db.NewUsageRepository(database)
+
+	// Usage every six hours over four days, so every range has partial days.
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 16; i++ {
+		acct := primary
+		if i%3 == 0 {
+			acct = secondary
+		}
+		record := &models.UsageRecord{
+			AccountID:    acct.ID,
+			Provider:     acct.Provider,
+			InputTokens:  int64(100 * (i + 1)),
+			OutputTokens: int64(10 * (i + 1)),
+			CostCents:    int64(i + 1),
+			RecordedAt:   start.Add(time.Duration(i)*6*time.Hour + 30*time.Minute),
+		}
+		if err := repo.Create(ctx, record); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	now := start.AddDate(0, 0, 3).Add(20 * time.Hour)
+	if _, err := repo.RefreshDailyCache(ctx, now); err != nil {
+		t.Fatalf("RefreshDailyCache: %v", err)
+	}
+
+	at := func(day, hour int) *time.Time {
+		ts := start.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour)
+		return &ts
+	}
+	tests := []struct {
+		name       string
+		scope      UsageScope
+		since      *time.Time
+		until      *time.Time
+		wantCached bool
+	}{
+		{name: "whole days", since: at(1, 0), until: at(3, 0), wantCached: true},
+		{name: "partial ends", since: at(0, 3), until: at(3, 9), wantCached: true},
+		{name: "open start", until: at(2, 15), wantCached: true},
+		{name: "open end", since: at(1, 4), wantCached: true},
+		{name: "unbounded", wantCached: true},
+		{name: "within one day", since: at(1, 2), until: at(1, 20)},
+		{name: "across one midnight", since: at(1, 12), until: at(2, 12)},
+		{name: "account", scope: UsageScope{AccountID: primary.ID}, since: at(0, 7), until: at(3, 1), wantCached: true},
+		{name: "provider", scope: UsageScope{Provider: models.ProviderOpenAI}, since: at(0, 7), wantCached: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := SummarizeUsage(ctx, repo, tt.scope, tt.since, tt.until, false, now)
+			if err != nil {
+				t.Fatalf("SummarizeUsage: %v", err)
+			}
+			cached, err := SummarizeUsage(ctx, repo, tt.scope, tt.since, tt.until, true, now)
+			if err != nil {
+				t.Fatalf("SummarizeUsage cached: %v", err)
+			}
+
+			if raw.Cached {
+				t.Errorf("expected the raw summary not to use the cache")
+			}
+			if cached.Cached != tt.wantCached {
+				t.Errorf("expected cached %v, got %v", tt.wantCached, cached.Cached)
+			}
+			if cached.UsageSummary != raw.UsageSummary {
+				t.Errorf("expected the cached summary to match the records\nraw:    %+v\ncached: %+v", raw.UsageSummary, cached.UsageSummary)
+			}
+			if raw.RecordCount == 0 {
+				t.Errorf("expected usage in range")
+			}
+			if tt.wantCached && (cached.CacheRefreshedAt == nil || !cached.CacheRefreshedAt.Equal(now)) {
+				t.Errorf("expected the cache refresh time reported, got %v", cached.CacheRefreshedAt)
+			}
+		})
+	}
+}
+
+func TestSummarizeUsageRejectsAccountAndProvider(t *testing.T) {
+	scope := UsageScope{AccountID: "acct", Provider: models.ProviderAnthropic}
+	if _, err := SummarizeUsage(context.Background(), nil, scope, nil, nil, true, time.Now()); err == nil {
+		t.Fatalf("expected an error for a scope with both account and provider")
+	}
+}
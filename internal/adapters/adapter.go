@@ -25,6 +25,13 @@ type SpawnOptions struct {
 
 	// ApprovalPolicy is the effective approval policy for the agent.
 	ApprovalPolicy string
+
+	// Model selects the model the CLI should use (empty = CLI default).
+	Model string
+
+	// Resume continues the most recent session on disk, such as one
+	// restored from a checkpoint, instead of starting a new one.
+	Resume bool
 }
 
 // StateReason describes why an adapter reported a state.
@@ -72,6 +79,19 @@ type UsageMetricsExtractor interface {
 	ExtractUsageMetrics(screen string) (*models.UsageMetrics, bool, error)
 }
 
+// ModelEnvProvider is implemented by adapters whose CLI reads the model
+// from an environment variable instead of a flag.
+type ModelEnvProvider interface {
+	ModelEnvVar() string
+}
+
+// SessionProvider is implemented by adapters whose CLI keeps session
+// state on disk. Patterns are globs that may use the placeholders
+// documented in the checkpoint package.
+type SessionProvider interface {
+	SessionPatterns() []string
+}
+
 // DiffMetadataExtractor allows adapters to extract diff metadata from screen output.
 type DiffMetadataExtractor interface {
 	ExtractDiffMetadata(screen string) (*models.DiffMetadata, bool, error)
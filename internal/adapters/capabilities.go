@@ -0,0 +1,250 @@
+package adapters
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Capabilities declares what the parts of swarm that handle an agent CLI
+// without driving it through the adapter need to know: the spawn probe,
+// pane detection, the agent runner, swarmd, and checkpoints. A zero field
+// means the CLI declares nothing and the consumer keeps its own default.
+type Capabilities struct {
+	// Command is the command line the CLI is started with.
+	Command string
+
+	// Binary is the executable probed before spawn. Empty skips the probe.
+	Binary string
+
+	// InstallHint tells users how to install a missing CLI.
+	InstallHint string
+
+	// VersionArgs make the CLI print its version.
+	VersionArgs []string
+
+	// VersionPattern extracts the version from the CLI's version output;
+	// its first group is the version.
+	VersionPattern *regexp.Regexp
+
+	// ModelFlag is the flag that selects a model, such as "--model".
+	ModelFlag string
+
+	// ModelEnvVar is the environment variable that selects a model.
+	ModelEnvVar string
+
+	// PromptRegex matches output that ends in the CLI's input prompt.
+	PromptRegex *regexp.Regexp
+
+	// BusyRegex matches output of a CLI at work.
+	BusyRegex *regexp.Regexp
+
+	// IdleIndicators and BusyIndicators are the strings screen detection
+	// looks for; a ready CLI shows one of the idle indicators.
+	IdleIndicators []string
+	BusyIndicators []string
+
+	// RateLimitPatterns are strings in the output when the provider is
+	// rate limiting the CLI.
+	RateLimitPatterns []string
+
+	// SessionPatterns are globs matching the CLI's session state on disk.
+	SessionPatterns []string
+
+	// ResumeArgs make the CLI continue its most recent session.
+	ResumeArgs []string
+
+	// Signatures identify the CLI in a pane's command or output.
+	Signatures []string
+}
+
+// DefaultVersionPattern matches versions in output such as
+// "1.0.17 (Claude Code)", "codex-cli 0.20.0", or "opencode v0.3.58".
+var DefaultVersionPattern = regexp.MustCompile(`v?(\d+\.\d+(?:\.\d+)?(?:[-+][0-9A-Za-z.-]+)?)`)
+
+// VersionCommand returns the shell command printing the CLI's version, or
+// an empty string for CLIs that are not probed.
+func (c Capabilities) VersionCommand() string {
+	if c.Binary == "" {
+		return ""
+	}
+	return strings.Join(append([]string{c.Binary}, c.VersionArgs...), " ")
+}
+
+// ParseVersion extracts the version from the output of VersionCommand.
+// Output without a recognizable version yields its first line.
+func (c Capabilities) ParseVersion(output string) string {
+	pattern := c.VersionPattern
+	if pattern == nil {
+		pattern = DefaultVersionPattern
+	}
+	output = strings.TrimSpace(output)
+	if match := pattern.FindStringSubmatch(output); len(match) > 1 {
+		return match[1]
+	}
+	line, _, _ := strings.Cut(output, "\n")
+	return strings.TrimSpace(line)
+}
+
+// CapabilityProvider is implemented by adapters that declare Capabilities.
+type CapabilityProvider interface {
+	Capabilities() Capabilities
+}
+
+// Capabilities returns what the adapter declares about its CLI.
+func (a *GenericAdapter) Capabilities() Capabilities {
+	return Capabilities{
+		Command:           a.command,
+		Binary:            a.binary(),
+		InstallHint:       a.installHint,
+		VersionArgs:       a.versionArgs,
+		VersionPattern:    a.versionPattern,
+		ModelFlag:         a.modelFlag,
+		ModelEnvVar:       a.modelEnvVar,
+		PromptRegex:       a.promptRegex,
+		BusyRegex:         a.busyRegex,
+		IdleIndicators:    a.idleIndicators,
+		BusyIndicators:    a.busyIndicators,
+		RateLimitPatterns: a.rateLimitIndicators,
+		SessionPatterns:   a.sessionPatterns,
+		ResumeArgs:        a.resumeArgs,
+		Signatures:        a.signatures,
+	}
+}
+
+// CapabilitiesOf returns what adapter declares, or zero Capabilities for
+// nil and adapters that declare nothing.
+func CapabilitiesOf(adapter AgentAdapter) Capabilities {
+	if provider, ok := adapter.(CapabilityProvider); ok {
+		return provider.Capabilities()
+	}
+	return Capabilities{}
+}
+
+// CapabilitiesFor returns the capabilities of the default registry's
+// adapter for agentType.
+func CapabilitiesFor(agentType models.AgentType) Capabilities {
+	return CapabilitiesOf(GetByAgentType(agentType))
+}
+
+// Overrides replaces individual capabilities of a registered adapter, such
+// as from configuration. Empty fields keep the adapter's own value.
+type Overrides struct {
+	Command           string
+	InstallHint       string
+	VersionArgs       []string
+	ModelFlag         string
+	ModelEnvVar       string
+	PromptRegex       string
+	BusyRegex         string
+	IdleIndicators    []string
+	BusyIndicators    []string
+	RateLimitPatterns []string
+}
+
+// overridable is implemented by adapters built on a GenericAdapter.
+type overridable interface {
+	generic() *GenericAdapter
+}
+
+func (a *GenericAdapter) generic() *GenericAdapter {
+	return a
+}
+
+// Override applies o to the adapter registered as name. It is meant for
+// startup, before adapters are in use.
+func (r *Registry) Override(name string, o Overrides) error {
+	adapter := r.Get(name)
+	if adapter == nil {
+		return fmt.Errorf("adapter %q not registered", name)
+	}
+	target, ok := adapter.(overridable)
+	if !ok {
+		return fmt.Errorf("adapter %q does not support overrides", name)
+	}
+
+	var promptRegex, busyRegex *regexp.Regexp
+	var err error
+	if o.PromptRegex != "" {
+		if promptRegex, err = regexp.Compile(o.PromptRegex); err != nil {
+			return fmt.Errorf("adapter %q: invalid prompt regex: %w", name, err)
+		}
+	}
+	if o.BusyRegex != "" {
+		if busyRegex, err = regexp.Compile(o.BusyRegex); err != nil {
+			return fmt.Errorf("adapter %q: invalid busy regex: %w", name, err)
+		}
+	}
+
+	a := target.generic()
+	if o.Command != "" {
+		oldBinary := a.binary()
+		a.command = o.Command
+		// Follow the new executable unless signatures were chosen
+		// explicitly.
+		if len(a.signatures) == 1 && a.signatures[0] == oldBinary {
+			a.signatures = []string{a.binary()}
+		}
+	}
+	if o.InstallHint != "" {
+		a.installHint = o.InstallHint
+	}
+	if len(o.VersionArgs) > 0 {
+		a.versionArgs = o.VersionArgs
+	}
+	if o.ModelFlag != "" {
+		a.modelFlag = o.ModelFlag
+	}
+	if o.ModelEnvVar != "" {
+		a.modelEnvVar = o.ModelEnvVar
+	}
+	if promptRegex != nil {
+		a.promptRegex = promptRegex
+	}
+	if busyRegex != nil {
+		a.busyRegex = busyRegex
+	}
+	if len(o.IdleIndicators) > 0 {
+		a.idleIndicators = o.IdleIndicators
+	}
+	if len(o.BusyIndicators) > 0 {
+		a.busyIndicators = o.BusyIndicators
+	}
+	if len(o.RateLimitPatterns) > 0 {
+		a.rateLimitIndicators = o.RateLimitPatterns
+	}
+	return nil
+}
+
+// ApplyOverrides sets the overrides, keyed by adapter name, of the default
+// registry. The overrides are applied to fresh built-in adapters, so
+// calling it again, such as when the daemon reloads its config, undoes
+// overrides that were dropped. Adapters registered with RegisterAdapter
+// under other names are kept. When any override is invalid the registry is
+// left unchanged.
+func ApplyOverrides(overrides map[string]Overrides) error {
+	next := NewRegistry()
+	RegisterBuiltinAdapters(next)
+	for _, adapter := range DefaultRegistry.List() {
+		if next.Get(adapter.Name()) == nil {
+			next.MustRegister(adapter)
+		}
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := next.Override(name, overrides[name]); err != nil {
+			return err
+		}
+	}
+
+	DefaultRegistry.swap(next)
+	return nil
+}
@@ -0,0 +1,259 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// detectScreens is the screen corpus of testdata/detect.golden.
+var detectScreens = []string{
+	"",
+	"claude> ",
+	"opencode> ctrl+p commands",
+	"codex> ",
+	"gemini> ",
+	"$ ",
+	"⠋ Thinking...",
+	"Error: boom",
+	"Rate limit exceeded, try again later",
+	"429 Too Many Requests",
+	"quota exceeded",
+	"Do you want to proceed? [y/n]",
+	"Processing files",
+	"session started",
+	"some random output",
+	"▣ build",
+	`{"type":"system","subtype":"init"}`,
+}
+
+// TestBuiltinDetectionGolden pins the state and readiness every built-in
+// adapter reports for a fixed screen corpus. The golden file was recorded
+// before the adapters declared capabilities and must not change when they
+// are refactored.
+func TestBuiltinDetectionGolden(t *testing.T) {
+	want, err := os.ReadFile(filepath.Join("testdata", "detect.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got strings.Builder
+	for _, name := range []string{"opencode", "claude-code", "codex", "gemini", "generic"} {
+		adapter := Get(name)
+		for _, screen := range detectScreens {
+			state, reason, err := adapter.DetectState(screen, nil)
+			if err != nil {
+				t.Fatalf("%s: DetectState(%q) failed: %v", name, screen, err)
+			}
+			ready, err := adapter.DetectReady(screen)
+			if err != nil {
+				t.Fatalf("%s: DetectReady(%q) failed: %v", name, screen, err)
+			}
+			fmt.Fprintf(&got, "%s|%q|%s|%s|%v\n", name, screen, state, reason.Confidence, ready)
+		}
+	}
+
+	gotLines := strings.Split(got.String(), "\n")
+	wantLines := strings.Split(string(want), "\n")
+	if len(gotLines) != len(wantLines) {
+		t.Fatalf("expected %d detection lines, got %d", len(wantLines), len(gotLines))
+	}
+	for i := range wantLines {
+		if gotLines[i] != wantLines[i] {
+			t.Errorf("detection changed:\nwant %s\ngot  %s", wantLines[i], gotLines[i])
+		}
+	}
+}
+
+func TestBuiltinCapabilities(t *testing.T) {
+	tests := []struct {
+		agentType   models.AgentType
+		binary      string
+		hint        string
+		modelFlag   string
+		modelEnvVar string
+	}{
+		{models.AgentTypeOpenCode, "opencode", "curl -fsSL https://opencode.ai/install | bash", "--model", ""},
+		{models.AgentTypeClaudeCode, "claude", "npm install -g @anthropic-ai/claude-code", "--model", ""},
+		{models.AgentTypeCodex, "codex", "npm install -g @openai/codex", "--model", ""},
+		{models.AgentTypeGemini, "gemini", "npm install -g @google/gemini-cli", "", "GEMINI_MODEL"},
+		{models.AgentTypeGeneric, "", "", "", ""},
+	}
+	for _, tt := range tests {
+		caps := CapabilitiesFor(tt.agentType)
+		if caps.Binary != tt.binary || caps.InstallHint != tt.hint || caps.ModelFlag != tt.modelFlag || caps.ModelEnvVar != tt.modelEnvVar {
+			t.Errorf("%s: unexpected capabilities %+v", tt.agentType, caps)
+		}
+		wantVersion := ""
+		if tt.binary != "" {
+			wantVersion = tt.binary + " --version"
+		}
+		if got := caps.VersionCommand(); got != wantVersion {
+			t.Errorf("%s: VersionCommand() = %q, want %q", tt.agentType, got, wantVersion)
+		}
+		if caps.PromptRegex != nil || caps.BusyRegex != nil {
+			t.Errorf("%s: built-ins should leave prompt and busy regexes to consumers", tt.agentType)
+		}
+	}
+}
+
+func TestDetectAgentType(t *testing.T) {
+	tests := []struct {
+		text      string
+		want      models.AgentType
+		signature string
+	}{
+		{"opencode --hostname 127.0.0.1", models.AgentTypeOpenCode, "opencode"},
+		{"node /usr/bin/Claude", models.AgentTypeClaudeCode, "claude"},
+		{"codex exec", models.AgentTypeCodex, "codex"},
+		{"welcome to gemini", models.AgentTypeGemini, "gemini"},
+		{"aider --model x", models.AgentTypeGeneric, "aider"},
+		// Registration order decides between several signatures.
+		{"opencode and claude", models.AgentTypeOpenCode, "opencode"},
+	}
+	for _, tt := range tests {
+		got, signature, ok := DetectAgentType(tt.text)
+		if !ok || got != tt.want || signature != tt.signature {
+			t.Errorf("DetectAgentType(%q) = %q, %q, %v; want %q, %q", tt.text, got, signature, ok, tt.want, tt.signature)
+		}
+	}
+	if _, _, ok := DetectAgentType("bash"); ok {
+		t.Error("expected no agent type for a plain shell")
+	}
+}
+
+func TestRegisterAdapterToy(t *testing.T) {
+	restore := RegisterAdapter(NewGenericAdapter("toy", "toy-cli --fast",
+		WithModelFlag("-m"),
+		WithInstallHint("brew install toy"),
+		WithRateLimitIndicators("slow down"),
+	))
+	t.Cleanup(restore)
+
+	if !IsRegistered("toy") {
+		t.Fatal("expected toy to be registered")
+	}
+	cmd, args := GetByAgentType("toy").SpawnCommand(SpawnOptions{Model: "big"})
+	if cmd != "toy-cli" || strings.Join(args, " ") != "--fast -m big" {
+		t.Fatalf("unexpected spawn command %s %v", cmd, args)
+	}
+	if got, signature, ok := DetectAgentType("running toy-cli"); !ok || got != "toy" || signature != "toy-cli" {
+		t.Fatalf("expected toy detected by its executable, got %q %q %v", got, signature, ok)
+	}
+	if state, _, _ := Get("toy").DetectState("please slow down", nil); state != models.AgentStateRateLimited {
+		t.Fatalf("expected the toy's rate limit pattern to apply, got %s", state)
+	}
+	if got := CapabilitiesFor("toy").VersionCommand(); got != "toy-cli --version" {
+		t.Fatalf("VersionCommand() = %q", got)
+	}
+
+	// Replacing a built-in and restoring it keeps its place in the order.
+	restoreCodex := RegisterAdapter(NewGenericAdapter("codex", "my-codex"))
+	if CapabilitiesFor(models.AgentTypeCodex).Binary != "my-codex" {
+		t.Fatal("expected codex replaced")
+	}
+	restoreCodex()
+	if CapabilitiesFor(models.AgentTypeCodex).Binary != "codex" {
+		t.Fatal("expected the built-in codex restored")
+	}
+	restore()
+	if IsRegistered("toy") {
+		t.Fatal("expected toy unregistered after restore")
+	}
+	if names := Names(); !reflect.DeepEqual(names, []string{"opencode", "claude-code", "codex", "gemini", "generic"}) {
+		t.Fatalf("unexpected registration order %v", names)
+	}
+}
+
+func TestRegistryOverride(t *testing.T) {
+	r := NewRegistry()
+	RegisterBuiltinAdapters(r)
+
+	err := r.Override("claude-code", Overrides{
+		Command:           "claude-beta --verbose",
+		ModelFlag:         "--model-name",
+		PromptRegex:       `claude>\s*$`,
+		RateLimitPatterns: []string{"overloaded"},
+	})
+	if err != nil {
+		t.Fatalf("Override failed: %v", err)
+	}
+
+	adapter := r.Get("claude-code")
+	cmd, args := adapter.SpawnCommand(SpawnOptions{Model: "opus", ApprovalPolicy: "permissive"})
+	if cmd != "claude-beta" || strings.Join(args, " ") != "--verbose --model-name opus --permission-mode dontAsk" {
+		t.Fatalf("unexpected spawn command %s %v", cmd, args)
+	}
+	caps := CapabilitiesOf(adapter)
+	if caps.Binary != "claude-beta" || !reflect.DeepEqual(caps.Signatures, []string{"claude-beta"}) {
+		t.Fatalf("expected the probe and detection to follow the command, got %+v", caps)
+	}
+	if caps.PromptRegex == nil || !caps.PromptRegex.MatchString("claude> ") {
+		t.Fatalf("expected the prompt regex override, got %v", caps.PromptRegex)
+	}
+	if state, _, _ := adapter.DetectState("API overloaded", nil); state != models.AgentStateRateLimited {
+		t.Fatalf("expected the rate limit override to apply, got %s", state)
+	}
+	// Fields left empty keep the adapter's own values.
+	if !reflect.DeepEqual(caps.ResumeArgs, []string{"--continue"}) || caps.InstallHint == "" {
+		t.Fatalf("expected untouched capabilities kept, got %+v", caps)
+	}
+
+	if err := r.Override("missing", Overrides{}); err == nil {
+		t.Fatal("expected an error for an unknown adapter")
+	}
+	if err := r.Override("codex", Overrides{BusyRegex: "("}); err == nil {
+		t.Fatal("expected an error for an invalid regex")
+	}
+}
+
+func TestCapabilitiesParseVersion(t *testing.T) {
+	caps := Capabilities{VersionPattern: regexp.MustCompile(`build (\d+)`)}
+	if got := caps.ParseVersion("toy build 42\n"); got != "42" {
+		t.Fatalf("ParseVersion = %q, want 42", got)
+	}
+	if got := (Capabilities{}).ParseVersion("codex-cli 0.20.0"); got != "0.20.0" {
+		t.Fatalf("ParseVersion = %q, want 0.20.0", got)
+	}
+}
+
+func TestApplyOverridesReplacesPrevious(t *testing.T) {
+	restore := RegisterAdapter(NewGenericAdapter("toy", "toy-cli"))
+	t.Cleanup(func() {
+		restore()
+		_ = ApplyOverrides(nil)
+	})
+
+	if err := ApplyOverrides(map[string]Overrides{"codex": {Command: "my-codex"}}); err != nil {
+		t.Fatalf("ApplyOverrides failed: %v", err)
+	}
+	if got := CapabilitiesFor(models.AgentTypeCodex).Binary; got != "my-codex" {
+		t.Fatalf("expected the codex override, got %q", got)
+	}
+
+	// Dropping an override restores the built-in.
+	if err := ApplyOverrides(map[string]Overrides{"gemini": {ModelEnvVar: "MODEL"}}); err != nil {
+		t.Fatalf("ApplyOverrides failed: %v", err)
+	}
+	if got := CapabilitiesFor(models.AgentTypeCodex).Binary; got != "codex" {
+		t.Fatalf("expected the built-in codex back, got %q", got)
+	}
+	if !IsRegistered("toy") {
+		t.Fatal("expected registered adapters kept")
+	}
+
+	// An invalid override leaves the registry as it was.
+	err := ApplyOverrides(map[string]Overrides{"codex": {Command: "other"}, "gemini": {BusyRegex: "("}})
+	if err == nil {
+		t.Fatal("expected an error for an invalid regex")
+	}
+	if CapabilitiesFor(models.AgentTypeCodex).Binary != "codex" || CapabilitiesFor(models.AgentTypeGemini).ModelEnvVar != "MODEL" {
+		t.Fatal("expected the registry unchanged after a rejected override")
+	}
+}
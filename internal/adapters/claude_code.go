@@ -23,6 +23,10 @@ func NewClaudeCodeAdapter() *claudeCodeAdapter {
 	base := NewGenericAdapter(
 		string(models.AgentTypeClaudeCode),
 		"claude",
+		WithModelFlag("--model"),
+		WithSessionPatterns("{home}/.claude/projects/{claude_project}"),
+		WithResumeArgs("--continue"),
+		WithInstallHint("npm install -g @anthropic-ai/claude-code"),
 		WithIdleIndicators(
 			"claude>",
 			"❯",
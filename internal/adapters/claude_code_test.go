@@ -81,6 +81,21 @@ func TestClaudeCodeAdapter_SpawnCommandPermissive(t *testing.T) {
 	}
 }
 
+func TestClaudeCodeAdapter_SessionResume(t *testing.T) {
+	adapter := NewClaudeCodeAdapter()
+
+	if got := adapter.SessionPatterns(); len(got) != 1 || got[0] != "{home}/.claude/projects/{claude_project}" {
+		t.Fatalf("unexpected session patterns %v", got)
+	}
+	_, args := adapter.SpawnCommand(SpawnOptions{Model: "opus", Resume: true})
+	if len(args) != 3 || args[2] != "--continue" {
+		t.Fatalf("expected --continue after the model, got %v", args)
+	}
+	if _, args := adapter.SpawnCommand(SpawnOptions{}); len(args) != 0 {
+		t.Fatalf("expected no args without resume, got %v", args)
+	}
+}
+
 func containsArgsPair(args []string, flag, value string) bool {
 	for i := 0; i+1 < len(args); i++ {
 		if args[i] == flag && args[i+1] == value {
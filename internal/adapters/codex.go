@@ -16,6 +16,9 @@ func NewCodexAdapter() *codexAdapter {
 	base := NewGenericAdapter(
 		string(models.AgentTypeCodex),
 		"codex",
+		WithModelFlag("--model"),
+		WithSessionPatterns("{home}/.codex/sessions"),
+		WithInstallHint("npm install -g @openai/codex"),
 		WithIdleIndicators(
 			"codex>",
 			">",
@@ -43,8 +46,10 @@ func (a *codexAdapter) Tier() models.AdapterTier {
 
 // SpawnCommand returns the command and args to launch Codex CLI.
 func (a *codexAdapter) SpawnCommand(opts SpawnOptions) (cmd string, args []string) {
-	cmd = "codex"
-	args = []string{}
+	cmd, args = a.commandParts()
+	if opts.Resume {
+		args = append(args, "resume", "--last")
+	}
 
 	// Handle approval policy
 	if opts.ApprovalPolicy != "" {
@@ -61,6 +66,8 @@ func (a *codexAdapter) SpawnCommand(opts SpawnOptions) (cmd string, args []strin
 		}
 	}
 
+	args = append(args, a.modelArgs(opts.Model)...)
+
 	return cmd, args
 }
 
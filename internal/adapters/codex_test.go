@@ -24,6 +24,7 @@ func TestCodexAdapter_SpawnCommand(t *testing.T) {
 	tests := []struct {
 		name           string
 		approvalPolicy string
+		resume         bool
 		wantArgs       []string
 	}{
 		{
@@ -51,11 +52,17 @@ func TestCodexAdapter_SpawnCommand(t *testing.T) {
 			approvalPolicy: "PERMISSIVE",
 			wantArgs:       []string{"--full-auto"},
 		},
+		{
+			name:           "resume last session",
+			approvalPolicy: "permissive",
+			resume:         true,
+			wantArgs:       []string{"resume", "--last", "--full-auto"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			opts := SpawnOptions{ApprovalPolicy: tt.approvalPolicy}
+			opts := SpawnOptions{ApprovalPolicy: tt.approvalPolicy, Resume: tt.resume}
 			cmd, args := adapter.SpawnCommand(opts)
 
 			if cmd != "codex" {
@@ -16,6 +16,8 @@ func NewGeminiAdapter() *geminiAdapter {
 	base := NewGenericAdapter(
 		string(models.AgentTypeGemini),
 		"gemini",
+		WithModelEnvVar("GEMINI_MODEL"),
+		WithInstallHint("npm install -g @google/gemini-cli"),
 		WithIdleIndicators(
 			"gemini>",
 			">",
@@ -44,8 +46,7 @@ func (a *geminiAdapter) Tier() models.AdapterTier {
 
 // SpawnCommand returns the command and args to launch Gemini CLI.
 func (a *geminiAdapter) SpawnCommand(opts SpawnOptions) (cmd string, args []string) {
-	cmd = "gemini"
-	args = []string{}
+	cmd, args = a.commandParts()
 
 	// Handle approval policy using --approval-mode
 	if opts.ApprovalPolicy != "" {
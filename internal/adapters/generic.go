@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/opencode-ai/swarm/internal/models"
@@ -20,6 +21,37 @@ type GenericAdapter struct {
 
 	// busyIndicators are strings that suggest the agent is working
 	busyIndicators []string
+
+	// modelFlag is the CLI flag that selects a model (e.g. "--model")
+	modelFlag string
+
+	// modelEnvVar is the environment variable that selects a model
+	modelEnvVar string
+
+	// sessionPatterns are globs matching the CLI's session state on disk
+	sessionPatterns []string
+
+	// resumeArgs continue the most recent session
+	resumeArgs []string
+
+	// installHint tells users how to install the CLI
+	installHint string
+
+	// versionArgs make the CLI print its version
+	versionArgs []string
+
+	// versionPattern extracts the version from that output
+	versionPattern *regexp.Regexp
+
+	// promptRegex and busyRegex match the CLI's prompt and activity in raw output
+	promptRegex *regexp.Regexp
+	busyRegex   *regexp.Regexp
+
+	// rateLimitIndicators are strings that suggest the provider is rate limiting
+	rateLimitIndicators []string
+
+	// signatures are strings identifying the CLI in a pane's command or output
+	signatures []string
 }
 
 // GenericAdapterOption configures a GenericAdapter.
@@ -39,11 +71,94 @@ func WithBusyIndicators(indicators ...string) GenericAdapterOption {
 	}
 }
 
+// WithModelFlag sets the CLI flag used to pass a model.
+func WithModelFlag(flag string) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.modelFlag = flag
+	}
+}
+
+// WithModelEnvVar sets the environment variable used to pass a model.
+func WithModelEnvVar(name string) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.modelEnvVar = name
+	}
+}
+
+// WithSessionPatterns sets the globs matching the CLI's session state.
+func WithSessionPatterns(patterns ...string) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.sessionPatterns = patterns
+	}
+}
+
+// WithResumeArgs sets the args that make the CLI continue its most recent
+// session.
+func WithResumeArgs(args ...string) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.resumeArgs = args
+	}
+}
+
+// WithInstallHint sets how users install the CLI when the spawn probe
+// cannot find it.
+func WithInstallHint(hint string) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.installHint = hint
+	}
+}
+
+// WithVersionArgs sets the args that make the CLI print its version.
+func WithVersionArgs(args ...string) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.versionArgs = args
+	}
+}
+
+// WithVersionPattern sets the regex whose first group is the version in
+// the CLI's version output.
+func WithVersionPattern(pattern *regexp.Regexp) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.versionPattern = pattern
+	}
+}
+
+// WithPromptRegex sets the regex matching output that ends in the CLI's
+// input prompt.
+func WithPromptRegex(pattern *regexp.Regexp) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.promptRegex = pattern
+	}
+}
+
+// WithBusyRegex sets the regex matching output of a CLI at work.
+func WithBusyRegex(pattern *regexp.Regexp) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.busyRegex = pattern
+	}
+}
+
+// WithRateLimitIndicators sets custom rate limit indicators.
+func WithRateLimitIndicators(indicators ...string) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.rateLimitIndicators = indicators
+	}
+}
+
+// WithSignatures sets the strings that identify the CLI in a pane's
+// command or output. The default is the command's executable.
+func WithSignatures(signatures ...string) GenericAdapterOption {
+	return func(a *GenericAdapter) {
+		a.signatures = signatures
+	}
+}
+
 // NewGenericAdapter creates a new generic adapter.
 func NewGenericAdapter(name, command string, opts ...GenericAdapterOption) *GenericAdapter {
 	a := &GenericAdapter{
-		name:    name,
-		command: command,
+		name:        name,
+		command:     command,
+		versionArgs: []string{"--version"},
 		// Default indicators - common patterns across CLI agents
 		idleIndicators: []string{
 			"❯", ">", "$", "%", // common prompts
@@ -59,11 +174,17 @@ func NewGenericAdapter(name, command string, opts ...GenericAdapterOption) *Gene
 			"...",
 			"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏", // spinner chars
 		},
+		rateLimitIndicators: []string{"rate limit", "too many requests", "quota exceeded", "try again later"},
 	}
 
 	for _, opt := range opts {
 		opt(a)
 	}
+	if a.signatures == nil {
+		if binary := a.binary(); binary != "" {
+			a.signatures = []string{binary}
+		}
+	}
 
 	return a
 }
@@ -80,15 +201,58 @@ func (a *GenericAdapter) Tier() models.AdapterTier {
 
 // SpawnCommand returns the command and args to launch the agent.
 func (a *GenericAdapter) SpawnCommand(opts SpawnOptions) (cmd string, args []string) {
-	// Simple command parsing - split on spaces
-	// For more complex commands, adapters should override this
+	cmd, args = a.commandParts()
+	if cmd == "" {
+		return a.command, nil
+	}
+	args = append(args, a.modelArgs(opts.Model)...)
+	if opts.Resume {
+		args = append(args, a.resumeArgs...)
+	}
+	return cmd, args
+}
+
+// commandParts splits the configured command on spaces. For more complex
+// commands, adapters should override SpawnCommand.
+func (a *GenericAdapter) commandParts() (cmd string, args []string) {
 	parts := strings.Fields(a.command)
 	if len(parts) == 0 {
-		return a.command, nil
+		return "", nil
 	}
 	return parts[0], parts[1:]
 }
 
+// binary returns the executable the command runs.
+func (a *GenericAdapter) binary() string {
+	cmd, _ := a.commandParts()
+	return cmd
+}
+
+// SupportsModel reports whether a model can be passed to the CLI.
+func (a *GenericAdapter) SupportsModel() bool {
+	return a.modelFlag != "" || a.modelEnvVar != ""
+}
+
+// ModelEnvVar returns the environment variable that selects a model, if any.
+func (a *GenericAdapter) ModelEnvVar() string {
+	return a.modelEnvVar
+}
+
+// SessionPatterns returns the globs matching the CLI's session state.
+func (a *GenericAdapter) SessionPatterns() []string {
+	return a.sessionPatterns
+}
+
+// modelArgs returns the flag and value selecting model, or nil when no
+// model is requested or the CLI takes it from the environment.
+func (a *GenericAdapter) modelArgs(model string) []string {
+	model = strings.TrimSpace(model)
+	if model == "" || a.modelFlag == "" {
+		return nil
+	}
+	return []string{a.modelFlag, model}
+}
+
 // DetectReady reports whether the agent is ready based on screen output.
 func (a *GenericAdapter) DetectReady(screen string) (bool, error) {
 	lower := strings.ToLower(screen)
@@ -128,9 +292,8 @@ func (a *GenericAdapter) DetectState(screen string, meta any) (models.AgentState
 	}
 
 	// Check for rate limit indicators
-	rateLimitIndicators := []string{"rate limit", "too many requests", "quota exceeded", "try again later"}
-	for _, indicator := range rateLimitIndicators {
-		if strings.Contains(lower, indicator) {
+	for _, indicator := range a.rateLimitIndicators {
+		if strings.Contains(lower, strings.ToLower(indicator)) {
 			return models.AgentStateRateLimited, StateReason{
 				Reason:     "rate limit indicator detected",
 				Confidence: models.StateConfidenceMedium,
@@ -236,6 +399,7 @@ func GenericFallbackAdapter() *GenericAdapter {
 	return NewGenericAdapter(
 		string(models.AgentTypeGeneric),
 		"",
+		WithSignatures("aider"),
 	)
 }
 
@@ -0,0 +1,86 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Model check errors. Both are advisory: callers should warn and continue,
+// since provider catalogs change faster than this list.
+var (
+	ErrUnknownModel     = errors.New("unknown model")
+	ErrModelUnsupported = errors.New("adapter does not support model selection")
+)
+
+// modelCatalog lists, per agent type, exact model aliases and name prefixes
+// the CLI is known to accept.
+type modelCatalog struct {
+	aliases  []string
+	prefixes []string
+	// qualified requires provider/model form.
+	qualified bool
+}
+
+var knownModels = map[models.AgentType]modelCatalog{
+	models.AgentTypeClaudeCode: {
+		aliases:  []string{"default", "sonnet", "opus", "haiku", "opusplan"},
+		prefixes: []string{"claude-"},
+	},
+	models.AgentTypeCodex: {
+		prefixes: []string{"gpt-", "o1", "o3", "o4", "codex-"},
+	},
+	models.AgentTypeGemini: {
+		prefixes: []string{"gemini-"},
+	},
+	models.AgentTypeOpenCode: {
+		qualified: true,
+	},
+}
+
+// modelSupporter is implemented by adapters that can pass a model to the CLI.
+type modelSupporter interface {
+	SupportsModel() bool
+}
+
+// CheckModel reports whether model looks valid for the agent type. It
+// returns ErrModelUnsupported when the adapter cannot pass a model at all
+// and ErrUnknownModel when the name is not in the known catalog.
+func CheckModel(agentType models.AgentType, model string) error {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return nil
+	}
+
+	adapter := GetByAgentType(agentType)
+	if supporter, ok := adapter.(modelSupporter); !ok || !supporter.SupportsModel() {
+		return fmt.Errorf("%w: %s", ErrModelUnsupported, agentType)
+	}
+
+	catalog, ok := knownModels[agentType]
+	if !ok {
+		return nil
+	}
+
+	lower := strings.ToLower(model)
+	if catalog.qualified {
+		provider, name, found := strings.Cut(lower, "/")
+		if found && provider != "" && name != "" {
+			return nil
+		}
+		return fmt.Errorf("%w: %s expects provider/model, got %q", ErrUnknownModel, agentType, model)
+	}
+	for _, alias := range catalog.aliases {
+		if lower == alias {
+			return nil
+		}
+	}
+	for _, prefix := range catalog.prefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q for %s", ErrUnknownModel, model, agentType)
+}
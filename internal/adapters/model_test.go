@@ -0,0 +1,63 @@
+package adapters
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestCheckModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		agentType models.AgentType
+		model     string
+		wantErr   error
+	}{
+		{name: "empty", agentType: models.AgentTypeClaudeCode, model: ""},
+		{name: "claude alias", agentType: models.AgentTypeClaudeCode, model: "Sonnet"},
+		{name: "claude full name", agentType: models.AgentTypeClaudeCode, model: "claude-opus-4-1"},
+		{name: "claude unknown", agentType: models.AgentTypeClaudeCode, model: "gpt-5", wantErr: ErrUnknownModel},
+		{name: "codex", agentType: models.AgentTypeCodex, model: "gpt-5-codex"},
+		{name: "gemini", agentType: models.AgentTypeGemini, model: "gemini-2.5-pro"},
+		{name: "opencode qualified", agentType: models.AgentTypeOpenCode, model: "anthropic/claude-sonnet-4"},
+		{name: "opencode bare", agentType: models.AgentTypeOpenCode, model: "sonnet", wantErr: ErrUnknownModel},
+		{name: "generic unsupported", agentType: models.AgentTypeGeneric, model: "anything", wantErr: ErrModelUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckModel(tt.agentType, tt.model)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSpawnCommandModelArgs(t *testing.T) {
+	for _, adapter := range []AgentAdapter{ClaudeCodeAdapter(), CodexAdapter(), OpenCodeAdapter()} {
+		_, args := adapter.SpawnCommand(SpawnOptions{Model: "m1"})
+		if !containsArgsPair(args, "--model", "m1") {
+			t.Fatalf("%s: expected --model m1 in args, got %v", adapter.Name(), args)
+		}
+	}
+
+	gemini := GeminiAdapter()
+	_, args := gemini.SpawnCommand(SpawnOptions{Model: "gemini-2.5-pro"})
+	for _, arg := range args {
+		if arg == "--model" {
+			t.Fatalf("expected gemini model via env, got args %v", args)
+		}
+	}
+	provider, ok := gemini.(ModelEnvProvider)
+	if !ok || provider.ModelEnvVar() != "GEMINI_MODEL" {
+		t.Fatal("expected gemini adapter to select model via GEMINI_MODEL")
+	}
+}
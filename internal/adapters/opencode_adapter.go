@@ -11,6 +11,10 @@ func NewOpenCodeAdapter() *openCodeAdapter {
 	base := NewGenericAdapter(
 		string(models.AgentTypeOpenCode),
 		"opencode --hostname 127.0.0.1",
+		WithModelFlag("--model"),
+		WithSessionPatterns("{home}/.local/share/opencode/storage"),
+		WithResumeArgs("--continue"),
+		WithInstallHint("curl -fsSL https://opencode.ai/install | bash"),
 		WithIdleIndicators(
 			"opencode>",
 			"waiting for input",
@@ -3,15 +3,18 @@ package adapters
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/opencode-ai/swarm/internal/models"
 )
 
-// Registry manages registered agent adapters.
+// Registry manages registered agent adapters. Adapters are listed in the
+// order they were registered.
 type Registry struct {
 	mu       sync.RWMutex
 	adapters map[string]AgentAdapter
+	order    []string
 }
 
 // NewRegistry creates a new adapter registry.
@@ -33,6 +36,7 @@ func (r *Registry) Register(adapter AgentAdapter) error {
 	}
 
 	r.adapters[name] = adapter
+	r.order = append(r.order, name)
 	return nil
 }
 
@@ -63,9 +67,9 @@ func (r *Registry) List() []AgentAdapter {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	adapters := make([]AgentAdapter, 0, len(r.adapters))
-	for _, adapter := range r.adapters {
-		adapters = append(adapters, adapter)
+	adapters := make([]AgentAdapter, 0, len(r.order))
+	for _, name := range r.order {
+		adapters = append(adapters, r.adapters[name])
 	}
 	return adapters
 }
@@ -75,11 +79,7 @@ func (r *Registry) Names() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	names := make([]string, 0, len(r.adapters))
-	for name := range r.adapters {
-		names = append(names, name)
-	}
-	return names
+	return append([]string(nil), r.order...)
 }
 
 // Unregister removes an adapter from the registry.
@@ -90,11 +90,72 @@ func (r *Registry) Unregister(name string) bool {
 
 	if _, exists := r.adapters[name]; exists {
 		delete(r.adapters, name)
+		r.removeFromOrder(name)
 		return true
 	}
 	return false
 }
 
+// Replace registers adapter, taking the place of any adapter with the same
+// name. It returns a function that restores the previous registration.
+func (r *Registry) Replace(adapter AgentAdapter) (restore func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := adapter.Name()
+	previous, existed := r.adapters[name]
+	if !existed {
+		r.order = append(r.order, name)
+	}
+	r.adapters[name] = adapter
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if existed {
+			r.adapters[name] = previous
+			return
+		}
+		delete(r.adapters, name)
+		r.removeFromOrder(name)
+	}
+}
+
+// swap replaces the registrations of r with those of next.
+func (r *Registry) swap(next *Registry) {
+	next.mu.RLock()
+	adapters, order := next.adapters, next.order
+	next.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adapters, r.order = adapters, order
+}
+
+func (r *Registry) removeFromOrder(name string) {
+	for i, registered := range r.order {
+		if registered == name {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// DetectAgentType returns the agent type of the first adapter, in
+// registration order, whose signature text contains, along with that
+// signature. text is matched case-insensitively.
+func (r *Registry) DetectAgentType(text string) (models.AgentType, string, bool) {
+	lower := strings.ToLower(text)
+	for _, adapter := range r.List() {
+		for _, signature := range CapabilitiesOf(adapter).Signatures {
+			if signature != "" && strings.Contains(lower, strings.ToLower(signature)) {
+				return models.AgentType(adapter.Name()), signature, true
+			}
+		}
+	}
+	return "", "", false
+}
+
 // DefaultRegistry is the global adapter registry.
 var DefaultRegistry = NewRegistry()
 
@@ -108,6 +169,15 @@ func MustRegister(adapter AgentAdapter) {
 	DefaultRegistry.MustRegister(adapter)
 }
 
+// RegisterAdapter makes adapter available to every part of swarm that
+// handles agent CLIs: spawning, the spawn probe, state and pane detection,
+// the agent runner, and swarmd. An adapter with the same name, including a
+// built-in, is replaced. The returned function restores the previous
+// registration.
+func RegisterAdapter(adapter AgentAdapter) (restore func()) {
+	return DefaultRegistry.Replace(adapter)
+}
+
 // Get retrieves an adapter from the default registry by name.
 func Get(name string) AgentAdapter {
 	return DefaultRegistry.Get(name)
@@ -127,3 +197,15 @@ func List() []AgentAdapter {
 func Names() []string {
 	return DefaultRegistry.Names()
 }
+
+// DetectAgentType identifies an agent CLI by signature using the default
+// registry.
+func DetectAgentType(text string) (models.AgentType, string, bool) {
+	return DefaultRegistry.DetectAgentType(text)
+}
+
+// IsRegistered reports whether the default registry has an adapter for
+// agentType.
+func IsRegistered(agentType models.AgentType) bool {
+	return DefaultRegistry.GetByAgentType(agentType) != nil
+}
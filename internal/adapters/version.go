@@ -0,0 +1,112 @@
+package adapters
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionPattern finds the version within CLI output or a recorded
+// version: dot-separated numbers with an optional pre-release and build.
+var versionPattern = regexp.MustCompile(`(\d+(?:\.\d+)*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?`)
+
+// cliVersion is a parsed CLI version.
+type cliVersion struct {
+	parts      []int
+	prerelease []string
+}
+
+func parseCLIVersion(s string) (cliVersion, bool) {
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return cliVersion{}, false
+	}
+	var v cliVersion
+	for _, part := range strings.Split(match[1], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return cliVersion{}, false
+		}
+		v.parts = append(v.parts, n)
+	}
+	if match[2] != "" {
+		v.prerelease = strings.Split(match[2], ".")
+	}
+	return v, true
+}
+
+// IsVersion reports whether s holds a version that CompareVersions orders
+// by number rather than as text.
+func IsVersion(s string) bool {
+	_, ok := parseCLIVersion(s)
+	return ok
+}
+
+// CompareVersions orders two CLI versions, returning a negative number when
+// a is older than b, zero when they are the same release, and a positive
+// number when a is newer. It reads versions as CLIs print them: with a
+// leading "v" or surrounding text ("codex-cli 0.20.0", "1.0.17 (Claude
+// Code)"), with two to four numbers (1.2 is 1.2.0), and with semver
+// pre-releases, which come before their release. Build metadata is
+// ignored. Versions without a number are compared as text.
+func CompareVersions(a, b string) int {
+	va, okA := parseCLIVersion(a)
+	vb, okB := parseCLIVersion(b)
+	if !okA || !okB {
+		return strings.Compare(strings.TrimSpace(a), strings.TrimSpace(b))
+	}
+
+	for i := 0; i < max(len(va.parts), len(vb.parts)); i++ {
+		var pa, pb int
+		if i < len(va.parts) {
+			pa = va.parts[i]
+		}
+		if i < len(vb.parts) {
+			pb = vb.parts[i]
+		}
+		if pa != pb {
+			return compareInts(pa, pb)
+		}
+	}
+
+	switch {
+	case len(va.prerelease) == 0 && len(vb.prerelease) == 0:
+		return 0
+	case len(va.prerelease) == 0:
+		return 1
+	case len(vb.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < min(len(va.prerelease), len(vb.prerelease)); i++ {
+		if c := comparePrerelease(va.prerelease[i], vb.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(va.prerelease), len(vb.prerelease))
+}
+
+// comparePrerelease orders pre-release identifiers as semver does:
+// numbers by value and before words, words alphabetically.
+func comparePrerelease(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInts(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
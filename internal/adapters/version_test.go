@@ -0,0 +1,38 @@
+package adapters
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.0.17 (Claude Code)", b: "1.0.17", want: 0},
+		{a: "codex-cli 0.20.0", b: "0.20.0", want: 0},
+		{a: "opencode v0.3.58", b: "0.3.58", want: 0},
+		{a: "v1.0.17", b: "1.0.17", want: 0},
+		{a: "1.2", b: "1.2.0", want: 0},
+		{a: "0.3.58+build.5", b: "0.3.58", want: 0},
+		{a: "1.0.17 (Claude Code)", b: "1.0.20 (Claude Code)", want: -1},
+		{a: "v1.10.0", b: "v1.9.9", want: 1},
+		{a: "2.0.0", b: "1.99.99", want: 1},
+		{a: "1.0.17.1", b: "1.0.17", want: 1},
+		{a: "codex-cli 0.20.0-alpha.2", b: "codex-cli 0.20.0", want: -1},
+		{a: "0.20.0-alpha.10", b: "0.20.0-alpha.2", want: 1},
+		{a: "1.0.0-alpha", b: "1.0.0-alpha.1", want: -1},
+		{a: "1.0.0-beta", b: "1.0.0-alpha.5", want: 1},
+		{a: "1.0.0-rc.1", b: "1.0.0-12", want: 1},
+		{a: "gemini 0.1.9\nexperimental build", b: "0.1.10", want: -1},
+		{a: "toy build 42", b: "41", want: 1},
+		{a: "unknown", b: "unknown", want: 0},
+		{a: "dev", b: "unknown", want: -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := CompareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
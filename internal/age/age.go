@@ -0,0 +1,463 @@
+// Package age implements the age v1 file format (age-encryption.org/v1)
+// for X25519 recipients, which is what Swarm uses to encrypt config files.
+// Output interoperates with the age and rage tools; passphrase (scrypt)
+// and SSH recipients are not supported.
+package age
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	intro           = "age-encryption.org/v1\n"
+	stanzaPrefix    = "-> "
+	footerPrefix    = "---"
+	x25519Label     = "age-encryption.org/v1/X25519"
+	armorType       = "AGE ENCRYPTED FILE"
+	fileKeySize     = 16
+	streamNonceSize = 16
+	chunkSize       = 64 * 1024
+	bodyColumns     = 64
+
+	identityHRP  = "AGE-SECRET-KEY-"
+	recipientHRP = "age"
+)
+
+var (
+	// ErrNoIdentityMatch is returned by Decrypt when none of the
+	// identities can unwrap the file key.
+	ErrNoIdentityMatch = errors.New("age: no identity matched any of the recipients")
+
+	// ErrMalformed is returned for input that is not a valid age file.
+	ErrMalformed = errors.New("age: malformed encrypted file")
+)
+
+var b64 = base64.RawStdEncoding.Strict()
+
+// Recipient is an X25519 public key that files can be encrypted to.
+type Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// ParseRecipient parses an "age1..." recipient string.
+func ParseRecipient(s string) (*Recipient, error) {
+	hrp, data, err := bech32Decode(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("age: invalid recipient: %w", err)
+	}
+	if hrp != recipientHRP {
+		return nil, fmt.Errorf("age: invalid recipient: unexpected prefix %q", hrp)
+	}
+	key, err := ecdh.X25519().NewPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("age: invalid recipient: %w", err)
+	}
+	return &Recipient{key: key}, nil
+}
+
+// String returns the "age1..." encoding of the recipient.
+func (r *Recipient) String() string {
+	s, _ := bech32Encode(recipientHRP, r.key.Bytes())
+	return s
+}
+
+// Identity is an X25519 private key that can decrypt files encrypted to
+// its recipient.
+type Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// GenerateIdentity creates a new random identity.
+func GenerateIdentity() (*Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{key: key}, nil
+}
+
+// ParseIdentity parses an "AGE-SECRET-KEY-1..." identity string.
+func ParseIdentity(s string) (*Identity, error) {
+	hrp, data, err := bech32Decode(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("age: invalid identity: %w", err)
+	}
+	if hrp != strings.ToLower(identityHRP) {
+		return nil, fmt.Errorf("age: invalid identity: unexpected prefix %q", hrp)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("age: invalid identity: %w", err)
+	}
+	return &Identity{key: key}, nil
+}
+
+// ParseIdentities parses an identity file as written by age-keygen: one
+// identity per line, with blank lines and # comments ignored.
+func ParseIdentities(r io.Reader) ([]*Identity, error) {
+	var ids []*Identity
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		id, err := ParseIdentity(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("age: no identities found")
+	}
+	return ids, nil
+}
+
+// Recipient returns the public key matching the identity.
+func (i *Identity) Recipient() *Recipient {
+	return &Recipient{key: i.key.PublicKey()}
+}
+
+// String returns the "AGE-SECRET-KEY-1..." encoding of the identity.
+func (i *Identity) String() string {
+	s, _ := bech32Encode(identityHRP, i.key.Bytes())
+	return strings.ToUpper(s)
+}
+
+// IsEncrypted reports whether data looks like an age file, binary or
+// armored.
+func IsEncrypted(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return bytes.HasPrefix(trimmed, []byte(intro)) || bytes.HasPrefix(trimmed, []byte("-----BEGIN "+armorType+"-----"))
+}
+
+// Armor wraps a binary age file in the ASCII armor used by age -a.
+func Armor(data []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: armorType, Bytes: data})
+}
+
+// Encrypt encrypts plaintext to the recipients and returns a binary age file.
+func Encrypt(plaintext []byte, recipients ...*Recipient) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("age: no recipients")
+	}
+	fileKey := make([]byte, fileKeySize)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	header.WriteString(intro)
+	for _, r := range recipients {
+		share, body, err := wrapX25519(fileKey, r)
+		if err != nil {
+			return nil, err
+		}
+		header.WriteString(stanzaPrefix + "X25519 " + b64.EncodeToString(share) + "\n")
+		writeBody(&header, body)
+	}
+	header.WriteString(footerPrefix)
+	mac, err := headerMAC(fileKey, header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	header.WriteString(" " + b64.EncodeToString(mac) + "\n")
+
+	nonce := make([]byte, streamNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	payload, err := sealStream(fileKey, nonce, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	out := header.Bytes()
+	out = append(out, nonce...)
+	return append(out, payload...), nil
+}
+
+// Decrypt decrypts a binary or armored age file with the first identity
+// that matches one of its recipients.
+func Decrypt(data []byte, identities ...*Identity) ([]byte, error) {
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); bytes.HasPrefix(trimmed, []byte("-----BEGIN ")) {
+		block, _ := pem.Decode(trimmed)
+		if block == nil || block.Type != armorType {
+			return nil, fmt.Errorf("%w: invalid armor", ErrMalformed)
+		}
+		data = block.Bytes
+	}
+
+	stanzas, headerLen, mac, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileKey []byte
+	for _, s := range stanzas {
+		if s.kind != "X25519" {
+			continue
+		}
+		for _, id := range identities {
+			if key, ok := unwrapX25519(s, id); ok {
+				fileKey = key
+				break
+			}
+		}
+		if fileKey != nil {
+			break
+		}
+	}
+	if fileKey == nil {
+		return nil, ErrNoIdentityMatch
+	}
+
+	want, err := headerMAC(fileKey, data[:headerLen])
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, want) {
+		return nil, fmt.Errorf("%w: header MAC mismatch", ErrMalformed)
+	}
+
+	rest := data[headerLen+len(" ")+b64.EncodedLen(len(mac))+len("\n"):]
+	if len(rest) < streamNonceSize {
+		return nil, fmt.Errorf("%w: missing payload nonce", ErrMalformed)
+	}
+	return openStream(fileKey, rest[:streamNonceSize], rest[streamNonceSize:])
+}
+
+// stanza is one recipient entry from an age header.
+type stanza struct {
+	kind string
+	args []string
+	body []byte
+}
+
+// parseHeader returns the header's stanzas, the length of the header up
+// to and including "---" (the MAC input), and the decoded MAC.
+func parseHeader(data []byte) ([]stanza, int, []byte, error) {
+	if !bytes.HasPrefix(data, []byte(intro)) {
+		return nil, 0, nil, fmt.Errorf("%w: unsupported version or not an age file", ErrMalformed)
+	}
+	pos := len(intro)
+	nextLine := func() (string, bool) {
+		end := bytes.IndexByte(data[pos:], '\n')
+		if end < 0 {
+			return "", false
+		}
+		line := string(data[pos : pos+end])
+		pos += end + 1
+		return line, true
+	}
+
+	var stanzas []stanza
+	for {
+		start := pos
+		line, ok := nextLine()
+		if !ok {
+			return nil, 0, nil, fmt.Errorf("%w: truncated header", ErrMalformed)
+		}
+		if strings.HasPrefix(line, footerPrefix+" ") {
+			mac, err := b64.DecodeString(strings.TrimPrefix(line, footerPrefix+" "))
+			if err != nil || len(mac) != sha256.Size {
+				return nil, 0, nil, fmt.Errorf("%w: invalid header MAC", ErrMalformed)
+			}
+			return stanzas, start + len(footerPrefix), mac, nil
+		}
+		if !strings.HasPrefix(line, stanzaPrefix) {
+			return nil, 0, nil, fmt.Errorf("%w: unexpected header line %q", ErrMalformed, line)
+		}
+		fields := strings.Split(strings.TrimPrefix(line, stanzaPrefix), " ")
+		if len(fields) == 0 || fields[0] == "" {
+			return nil, 0, nil, fmt.Errorf("%w: empty stanza type", ErrMalformed)
+		}
+
+		s := stanza{kind: fields[0], args: fields[1:]}
+		for {
+			bodyLine, ok := nextLine()
+			if !ok {
+				return nil, 0, nil, fmt.Errorf("%w: truncated stanza body", ErrMalformed)
+			}
+			chunk, err := b64.DecodeString(bodyLine)
+			if err != nil || len(bodyLine) > bodyColumns {
+				return nil, 0, nil, fmt.Errorf("%w: invalid stanza body", ErrMalformed)
+			}
+			s.body = append(s.body, chunk...)
+			if len(bodyLine) < bodyColumns {
+				break
+			}
+		}
+		stanzas = append(stanzas, s)
+	}
+}
+
+// writeBody writes a stanza body as base64 wrapped at 64 columns. The last
+// line is always shorter than 64 columns, so it may be empty.
+func writeBody(w *bytes.Buffer, body []byte) {
+	encoded := b64.EncodeToString(body)
+	for len(encoded) >= bodyColumns {
+		w.WriteString(encoded[:bodyColumns] + "\n")
+		encoded = encoded[bodyColumns:]
+	}
+	w.WriteString(encoded + "\n")
+}
+
+func wrapX25519(fileKey []byte, r *Recipient) (share, body []byte, err error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	shared, err := ephemeral.ECDH(r.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	share = ephemeral.PublicKey().Bytes()
+	wrapKey, err := hkdf.Key(sha256.New, shared, append(append([]byte{}, share...), r.key.Bytes()...), x25519Label, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := chacha20poly1305.New(wrapKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return share, aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), fileKey, nil), nil
+}
+
+func unwrapX25519(s stanza, id *Identity) ([]byte, bool) {
+	if len(s.args) != 1 || len(s.body) != fileKeySize+chacha20poly1305.Overhead {
+		return nil, false
+	}
+	share, err := b64.DecodeString(s.args[0])
+	if err != nil {
+		return nil, false
+	}
+	peer, err := ecdh.X25519().NewPublicKey(share)
+	if err != nil {
+		return nil, false
+	}
+	shared, err := id.key.ECDH(peer)
+	if err != nil {
+		return nil, false
+	}
+	salt := append(append([]byte{}, share...), id.key.PublicKey().Bytes()...)
+	wrapKey, err := hkdf.Key(sha256.New, shared, salt, x25519Label, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, false
+	}
+	aead, err := chacha20poly1305.New(wrapKey)
+	if err != nil {
+		return nil, false
+	}
+	fileKey, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), s.body, nil)
+	if err != nil {
+		return nil, false
+	}
+	return fileKey, true
+}
+
+func headerMAC(fileKey, header []byte) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, fileKey, nil, "header", sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(header)
+	return h.Sum(nil), nil
+}
+
+// chunkNonce is the STREAM nonce: an 11-byte big-endian counter followed
+// by a flag byte set on the last chunk.
+func chunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func payloadAEAD(fileKey, nonce []byte) (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, fileKey, nonce, "payload", chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
+
+func sealStream(fileKey, nonce, plaintext []byte) ([]byte, error) {
+	aead, err := payloadAEAD(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	counter := uint64(0)
+	for {
+		n := len(plaintext)
+		if n > chunkSize {
+			n = chunkSize
+		}
+		last := n == len(plaintext)
+		out = aead.Seal(out, chunkNonce(counter, last), plaintext[:n], nil)
+		if last {
+			return out, nil
+		}
+		plaintext = plaintext[n:]
+		counter++
+	}
+}
+
+func openStream(fileKey, nonce, ciphertext []byte) ([]byte, error) {
+	aead, err := payloadAEAD(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	const sealedChunk = chunkSize + chacha20poly1305.Overhead
+	var out []byte
+	counter := uint64(0)
+	for {
+		n := len(ciphertext)
+		if n > sealedChunk {
+			n = sealedChunk
+		}
+		last := n == len(ciphertext)
+		if n < chacha20poly1305.Overhead {
+			return nil, fmt.Errorf("%w: truncated payload", ErrMalformed)
+		}
+		chunk, err := aead.Open(nil, chunkNonce(counter, last), ciphertext[:n], nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: payload authentication failed", ErrMalformed)
+		}
+		if last && len(chunk) == 0 && counter > 0 {
+			return nil, fmt.Errorf("%w: empty final chunk", ErrMalformed)
+		}
+		out = append(out, chunk...)
+		if last {
+			return out, nil
+		}
+		ciphertext = ciphertext[n:]
+		counter++
+	}
+}
@@ -0,0 +1,115 @@
+package age
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBech32Vectors(t *testing.T) {
+	// Valid checksums from BIP 173.
+	for _, s := range []string{
+		"A12UEL5L",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+	} {
+		if _, _, err := bech32Decode(s); err != nil {
+			t.Errorf("bech32Decode(%q) error = %v", s, err)
+		}
+	}
+	for _, s := range []string{"A12uEL5L", "a12uel5m", "pzry9x0s0muk"} {
+		if _, _, err := bech32Decode(s); err == nil {
+			t.Errorf("bech32Decode(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestKeyEncodingRoundTrip(t *testing.T) {
+	id, err := GenerateIdentity()
+	if err != nil {
+		t.Fatalf("GenerateIdentity() error = %v", err)
+	}
+	if !strings.HasPrefix(id.String(), "AGE-SECRET-KEY-1") || !strings.HasPrefix(id.Recipient().String(), "age1") {
+		t.Fatalf("unexpected key encodings %q / %q", id.String(), id.Recipient().String())
+	}
+
+	parsed, err := ParseIdentity(id.String())
+	if err != nil {
+		t.Fatalf("ParseIdentity() error = %v", err)
+	}
+	if parsed.Recipient().String() != id.Recipient().String() {
+		t.Fatal("parsed identity has a different recipient")
+	}
+	if _, err := ParseRecipient(id.Recipient().String()); err != nil {
+		t.Fatalf("ParseRecipient() error = %v", err)
+	}
+	// Published in the age README.
+	known := "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"
+	if r, err := ParseRecipient(known); err != nil || r.String() != known {
+		t.Fatalf("ParseRecipient(%q) = %v, %v", known, r, err)
+	}
+	if _, err := ParseRecipient(id.String()); err == nil {
+		t.Fatal("expected an identity string to be rejected as a recipient")
+	}
+
+	file := "# created: 2026-01-01\n# public key: " + id.Recipient().String() + "\n" + id.String() + "\n"
+	ids, err := ParseIdentities(strings.NewReader(file))
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("ParseIdentities() = %d identities, error = %v", len(ids), err)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	id, _ := GenerateIdentity()
+	other, _ := GenerateIdentity()
+
+	sizes := []int{0, 1, 100, chunkSize, chunkSize + 1, 2*chunkSize + 7}
+	for _, size := range sizes {
+		plaintext := bytes.Repeat([]byte("s"), size)
+		encrypted, err := Encrypt(plaintext, other.Recipient(), id.Recipient())
+		if err != nil {
+			t.Fatalf("Encrypt(%d bytes) error = %v", size, err)
+		}
+		if !IsEncrypted(encrypted) {
+			t.Fatalf("IsEncrypted() = false for %d byte file", size)
+		}
+		for _, data := range [][]byte{encrypted, Armor(encrypted)} {
+			got, err := Decrypt(data, id)
+			if err != nil {
+				t.Fatalf("Decrypt(%d bytes) error = %v", size, err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Fatalf("Decrypt(%d bytes) returned %d bytes", size, len(got))
+			}
+		}
+	}
+}
+
+func TestDecryptFailures(t *testing.T) {
+	id, _ := GenerateIdentity()
+	wrong, _ := GenerateIdentity()
+
+	encrypted, err := Encrypt([]byte("token: secret"), id.Recipient())
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	if _, err := Decrypt(encrypted, wrong); !errors.Is(err, ErrNoIdentityMatch) {
+		t.Fatalf("expected ErrNoIdentityMatch with the wrong identity, got %v", err)
+	}
+
+	tampered := append([]byte(nil), encrypted...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := Decrypt(tampered, id); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected ErrMalformed for a tampered payload, got %v", err)
+	}
+
+	truncated := encrypted[:len(encrypted)-17]
+	if _, err := Decrypt(truncated, id); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected ErrMalformed for a truncated payload, got %v", err)
+	}
+
+	if _, err := Decrypt([]byte("logging:\n  level: debug\n"), id); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected ErrMalformed for plaintext, got %v", err)
+	}
+}
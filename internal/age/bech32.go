@@ -0,0 +1,123 @@
+package age
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Bech32 (BIP 173) encoding for age keys. age does not enforce the
+// 90 character limit, so neither does this implementation.
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Generator = []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// convertBits regroups data from frombits-wide to tobits-wide groups.
+func convertBits(data []byte, frombits, tobits uint, pad bool) ([]byte, error) {
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<tobits - 1
+	out := make([]byte, 0, len(data)*int(frombits)/int(tobits)+1)
+	for _, value := range data {
+		if uint32(value)>>frombits != 0 {
+			return nil, errors.New("invalid data range")
+		}
+		acc = acc<<frombits | uint32(value)
+		bits += frombits
+		for bits >= tobits {
+			bits -= tobits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(tobits-bits)&maxv))
+		}
+	} else if bits >= frombits || acc<<(tobits-bits)&maxv != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	return out, nil
+}
+
+// bech32Encode encodes data under hrp, in lowercase.
+func bech32Encode(hrp string, data []byte) (string, error) {
+	values, err := convertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	hrp = strings.ToLower(hrp)
+	poly := bech32Polymod(append(append(bech32HRPExpand(hrp), values...), 0, 0, 0, 0, 0, 0)) ^ 1
+
+	var b strings.Builder
+	b.WriteString(hrp)
+	b.WriteByte('1')
+	for _, v := range values {
+		b.WriteByte(bech32Charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		b.WriteByte(bech32Charset[(poly>>uint(5*(5-i)))&31])
+	}
+	return b.String(), nil
+}
+
+// bech32Decode returns the lowercase HRP and data of s, which must not
+// mix cases.
+func bech32Decode(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, errors.New("separator '1' at invalid position")
+	}
+	hrp := s[:pos]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, fmt.Errorf("invalid character in human-readable part: %q", hrp[i])
+		}
+	}
+	values := make([]byte, 0, len(s)-pos-1)
+	for i := pos + 1; i < len(s); i++ {
+		idx := strings.IndexByte(bech32Charset, s[i])
+		if idx < 0 {
+			return "", nil, fmt.Errorf("invalid character in data part: %q", s[i])
+		}
+		values = append(values, byte(idx))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), values...)) != 1 {
+		return "", nil, errors.New("invalid checksum")
+	}
+	data, err := convertBits(values[:len(values)-6], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/fsperm"
 	"github.com/opencode-ai/swarm/internal/models"
 )
 
@@ -70,13 +71,13 @@ func (s *Service) archiveAgentLogs(ctx context.Context, agent *models.Agent, tra
 		return
 	}
 	if strings.TrimSpace(agent.ID) == "" {
-		s.logger.Warn().Msg("agent archive skipped: missing agent id")
+		s.logger.Warn().Ctx(ctx).Msg("agent archive skipped: missing agent id")
 		return
 	}
 
 	archiveDir := filepath.Join(s.archiveDir, agent.ID)
-	if err := os.MkdirAll(archiveDir, 0755); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to create archive directory")
+	if err := fsperm.MkdirAll(archiveDir); err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to create archive directory")
 		return
 	}
 
@@ -124,12 +125,12 @@ func (s *Service) archiveAgentLogs(ctx context.Context, agent *models.Agent, tra
 	filename := archiveFilename(payload.ArchivedAt)
 	path := filepath.Join(archiveDir, filename)
 	if err := writeArchive(path, payload); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to write agent archive")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to write agent archive")
 		return
 	}
 
 	if err := compressOldArchives(archiveDir, path, s.archiveAfter); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to compress old archives")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to compress old archives")
 	}
 }
 
@@ -175,7 +176,7 @@ func writeArchive(path string, payload *agentArchive) error {
 		return fmt.Errorf("archive payload is required")
 	}
 
-	file, err := os.Create(path)
+	file, err := fsperm.Create(path)
 	if err != nil {
 		return err
 	}
@@ -241,7 +242,7 @@ func gzipFile(path string) error {
 		return nil
 	}
 
-	dest, err := os.Create(gzipPath)
+	dest, err := fsperm.Create(gzipPath)
 	if err != nil {
 		return err
 	}
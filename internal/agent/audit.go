@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Audited operation names.
+const (
+	AuditSpawnAgent        = "spawn_agent"
+	AuditKillAgent         = "kill_agent"
+	AuditSendMessage       = "send_message"
+	AuditInterruptAgent    = "interrupt_agent"
+	AuditPauseAgent        = "pause_agent"
+	AuditResumeAgent       = "resume_agent"
+	AuditRestartAgent      = "restart_agent"
+	AuditMoveAgent         = "move_agent"
+	AuditRestoreCheckpoint = "restore_checkpoint"
+	AuditHibernateAgent    = "hibernate_agent"
+	AuditWakeAgent         = "wake_agent"
+)
+
+// WithAuditRecorder records the service's mutating operations.
+func WithAuditRecorder(recorder *audit.Recorder) ServiceOption {
+	return func(s *Service) {
+		s.auditor = recorder
+	}
+}
+
+func (s *Service) audit(ctx context.Context, operation, agentID string, params map[string]string, err error) {
+	s.auditor.Record(ctx, operation, models.EntityTypeAgent, agentID, params, err)
+}
+
+func boolParam(v bool) string {
+	return strconv.FormatBool(v)
+}
@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func TestMutationsAreAudited(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	env.service.auditor = audit.New(env.database, audit.WithActor("dana"))
+
+	secret := "sk-ant-REDACTED"
+	agent, err := env.service.SpawnAgent(ctx, SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	if err := env.service.SendMessage(ctx, agent.ID, "export ANTHROPIC_API_KEY="+secret, &SendMessageOptions{SkipIdleCheck: true}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if err := env.service.PauseAgent(ctx, agent.ID, time.Minute); err != nil {
+		t.Fatalf("PauseAgent failed: %v", err)
+	}
+	if err := env.service.ResumeAgent(ctx, agent.ID); err != nil {
+		t.Fatalf("ResumeAgent failed: %v", err)
+	}
+	if err := env.service.InterruptAgent(ctx, agent.ID); err != nil {
+		t.Fatalf("InterruptAgent failed: %v", err)
+	}
+	if _, err := env.service.TerminateAgent(ctx, agent.ID, TerminateOptions{DryRun: true}); err != nil {
+		t.Fatalf("dry-run TerminateAgent failed: %v", err)
+	}
+	if _, err := env.service.TerminateAgent(ctx, agent.ID, TerminateOptions{}); err != nil {
+		t.Fatalf("TerminateAgent failed: %v", err)
+	}
+	if err := env.service.PauseAgent(ctx, agent.ID, 0); err == nil {
+		t.Fatal("expected pausing a terminated agent to fail")
+	}
+
+	records, err := db.NewAuditRepository(env.database).Query(ctx, db.AuditQuery{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	want := []string{AuditSpawnAgent, AuditSendMessage, AuditPauseAgent, AuditResumeAgent, AuditInterruptAgent, AuditKillAgent, AuditPauseAgent}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d audit records, got %d: %+v", len(want), len(records), records)
+	}
+	for i, op := range want {
+		record := records[len(records)-1-i]
+		if record.Operation != op || record.EntityID != agent.ID || record.Actor != "dana" {
+			t.Errorf("record %d: got %s on %s by %s, want %s on %s", i, record.Operation, record.EntityID, record.Actor, op, agent.ID)
+		}
+	}
+	if send := records[len(records)-2]; strings.Contains(send.Parameters["message"], secret) || send.Parameters["message"] == "" {
+		t.Errorf("expected redacted message parameter, got %q", send.Parameters["message"])
+	}
+	if failed := records[0]; failed.Result != models.AuditResultFailure || failed.Error == "" {
+		t.Errorf("expected the failed pause recorded as a failure, got %+v", failed)
+	}
+}
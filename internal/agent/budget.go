@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrBudgetExceeded is returned when a spawn would push the projected daily
+// cost over a budget ceiling.
+var ErrBudgetExceeded = errors.New("projected daily cost exceeds budget")
+
+// budgetGuard holds what SpawnAgent needs to forecast daily cost.
+type budgetGuard struct {
+	cfg     *config.Config
+	sources account.ForecastSources
+}
+
+// checkBudget forecasts today's cost with the new agent running, against the
+// global ceiling and then the workspace's own. A spawn over either ceiling
+// is refused unless opts.OverrideBudget is set or budget.mode is warn; a
+// budget.exceeded event is recorded whichever way it goes.
+func (s *Service) checkBudget(ctx context.Context, ws *models.Workspace, opts SpawnOptions) error {
+	if s.budget == nil {
+		return nil
+	}
+	cfg := s.budget.cfg
+	now := time.Now()
+
+	scopes := []struct {
+		name      string
+		ceiling   int64
+		workspace *models.Workspace
+	}{
+		{"global", cfg.Budget.DailyCeilingCents, nil},
+		{"workspace", cfg.DailyBudgetForWorkspace(ws), ws},
+	}
+	for _, scope := range scopes {
+		if scope.ceiling <= 0 {
+			continue
+		}
+		forecast, err := account.BuildForecast(ctx, s.budget.sources, cfg, account.ForecastOptions{
+			Workspace: scope.workspace,
+			Spawning:  opts.Type,
+		}, now)
+		if err != nil {
+			return fmt.Errorf("failed to forecast daily cost: %w", err)
+		}
+		if !forecast.OverBudget() {
+			continue
+		}
+
+		action := models.BudgetActionRefused
+		switch {
+		case opts.OverrideBudget:
+			action = models.BudgetActionOverridden
+		case cfg.Budget.Mode == config.BudgetModeWarn:
+			action = models.BudgetActionWarned
+		}
+		s.publishEntityEvent(ctx, models.EventTypeBudgetExceeded, models.EntityTypeWorkspace, ws.ID, models.BudgetExceededPayload{
+			Scope:          scope.name,
+			AgentType:      opts.Type,
+			ProjectedCents: forecast.ProjectedCents,
+			CeilingCents:   forecast.CeilingCents,
+			Action:         action,
+		})
+
+		subject := "all workspaces"
+		if scope.workspace != nil {
+			subject = "workspace " + ws.Name
+		}
+		detail := fmt.Sprintf("%s would reach %s against a %s daily budget: %s",
+			subject, account.FormatCents(forecast.ProjectedCents), account.FormatCents(forecast.CeilingCents), forecast.Breakdown())
+		if action == models.BudgetActionRefused {
+			return fmt.Errorf("%w: %s (pass --override-budget to spawn anyway)", ErrBudgetExceeded, detail)
+		}
+		s.logger.Warn().
+			Ctx(ctx).
+			Str("workspace_id", ws.ID).
+			Str("scope", scope.name).
+			Str("action", action).
+			Int64("projected_cents", forecast.ProjectedCents).
+			Int64("ceiling_cents", forecast.CeilingCents).
+			Msg("spawn exceeds projected daily budget: " + detail)
+	}
+	return nil
+}
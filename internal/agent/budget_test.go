@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+// newBudgetTestEnv returns a spawn env whose workspace already spent $100
+// today through one running agent, with budget events collected.
+func newBudgetTestEnv(t *testing.T, cfg *config.Config) (*spawnTestEnv, *[]models.BudgetExceededPayload) {
+	t.Helper()
+	ctx := context.Background()
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+
+	spender := seedMoveAgent(t, env)
+	acct := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "work"}
+	if err := db.NewAccountRepository(env.database).Create(ctx, acct); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	usageRepo := db.NewUsageRepository(env.database)
+	if err := usageRepo.Create(ctx, &models.UsageRecord{
+		AccountID: acct.ID, AgentID: spender.ID, Provider: models.ProviderAnthropic, CostCents: 10000,
+	}); err != nil {
+		t.Fatalf("failed to record usage: %v", err)
+	}
+
+	var exceeded []models.BudgetExceededPayload
+	publisher := events.NewInMemoryPublisher()
+	if err := publisher.Subscribe("budget", events.Filter{}, func(event *models.Event) {
+		if event.Type != models.EventTypeBudgetExceeded {
+			return
+		}
+		var payload models.BudgetExceededPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			t.Errorf("failed to decode budget event: %v", err)
+		}
+		if event.EntityType != models.EntityTypeWorkspace || event.EntityID != env.workspaceID {
+			t.Errorf("expected budget event on the workspace, got %s %s", event.EntityType, event.EntityID)
+		}
+		exceeded = append(exceeded, payload)
+	}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	WithPublisher(publisher)(env.service)
+	WithBudget(cfg, account.ForecastSources{
+		Usage:  usageRepo,
+		Agents: db.NewAgentRepository(env.database),
+	})(env.service)
+	return env, &exceeded
+}
+
+func budgetSpawnOptions(env *spawnTestEnv) SpawnOptions {
+	return SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	}
+}
+
+func budgetConfig(mode string, workspaceCents int64) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Budget.Mode = mode
+	cfg.WorkspaceOverrides = []config.WorkspaceOverrideConfig{{Name: "ws", DailyBudgetCents: workspaceCents}}
+	return cfg
+}
+
+func TestSpawnAgentRefusedOverBudget(t *testing.T) {
+	env, exceeded := newBudgetTestEnv(t, budgetConfig(config.BudgetModeRefuse, 5000))
+	before := env.panes(t)
+
+	_, err := env.service.SpawnAgent(context.Background(), budgetSpawnOptions(env))
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	for _, want := range []string{"workspace ws would reach", "$50.00 daily budget", "$100.00 spent today", "2 claude-code at $3.00/h", "--override-budget"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got %v", want, err)
+		}
+	}
+	if got := env.panes(t); got != before {
+		t.Fatalf("expected no pane for a refused spawn, got %s (was %s)", got, before)
+	}
+	if len(*exceeded) != 1 || (*exceeded)[0].Action != models.BudgetActionRefused || (*exceeded)[0].Scope != "workspace" {
+		t.Fatalf("expected one refused workspace event, got %+v", *exceeded)
+	}
+	if (*exceeded)[0].CeilingCents != 5000 || (*exceeded)[0].ProjectedCents <= 10000 {
+		t.Fatalf("unexpected projection in event %+v", (*exceeded)[0])
+	}
+}
+
+func TestSpawnAgentRefusedOverGlobalBudget(t *testing.T) {
+	cfg := budgetConfig(config.BudgetModeRefuse, 0)
+	cfg.WorkspaceOverrides = nil
+	cfg.Budget.DailyCeilingCents = 8000
+	env, exceeded := newBudgetTestEnv(t, cfg)
+
+	_, err := env.service.SpawnAgent(context.Background(), budgetSpawnOptions(env))
+	if !errors.Is(err, ErrBudgetExceeded) || !strings.Contains(err.Error(), "all workspaces would reach") {
+		t.Fatalf("expected the global ceiling to refuse the spawn, got %v", err)
+	}
+	if len(*exceeded) != 1 || (*exceeded)[0].Scope != "global" {
+		t.Fatalf("expected one global event, got %+v", *exceeded)
+	}
+}
+
+func TestSpawnAgentWarnsOverBudget(t *testing.T) {
+	env, exceeded := newBudgetTestEnv(t, budgetConfig(config.BudgetModeWarn, 5000))
+
+	if _, err := env.service.SpawnAgent(context.Background(), budgetSpawnOptions(env)); err != nil {
+		t.Fatalf("expected warn mode to spawn, got %v", err)
+	}
+	if len(*exceeded) != 1 || (*exceeded)[0].Action != models.BudgetActionWarned {
+		t.Fatalf("expected one warned event, got %+v", *exceeded)
+	}
+}
+
+func TestSpawnAgentOverrideBudget(t *testing.T) {
+	env, exceeded := newBudgetTestEnv(t, budgetConfig(config.BudgetModeRefuse, 5000))
+
+	opts := budgetSpawnOptions(env)
+	opts.OverrideBudget = true
+	if _, err := env.service.SpawnAgent(context.Background(), opts); err != nil {
+		t.Fatalf("expected override to spawn, got %v", err)
+	}
+	if len(*exceeded) != 1 || (*exceeded)[0].Action != models.BudgetActionOverridden {
+		t.Fatalf("expected one overridden event, got %+v", *exceeded)
+	}
+}
+
+func TestSpawnAgentWithinBudget(t *testing.T) {
+	// $100 spent plus at most 48 agent-hours at $3.00/h stays under $250.
+	env, exceeded := newBudgetTestEnv(t, budgetConfig(config.BudgetModeRefuse, 25000))
+
+	if _, err := env.service.SpawnAgent(context.Background(), budgetSpawnOptions(env)); err != nil {
+		t.Fatalf("expected spawn within budget, got %v", err)
+	}
+	if len(*exceeded) != 0 {
+		t.Fatalf("expected no budget events, got %+v", *exceeded)
+	}
+}
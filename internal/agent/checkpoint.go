@@ -0,0 +1,265 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/checkpoint"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+// checkpointPause bounds how long an agent stays paused while its session
+// files are archived, so a failed resume does not leave it paused for good.
+const checkpointPause = time.Minute
+
+// Checkpoint errors.
+var (
+	ErrCheckpointsDisabled = errors.New("checkpoints are not configured")
+	ErrNoSessionPatterns   = errors.New("no session patterns for agent type")
+)
+
+type checkpointer struct {
+	store     *checkpoint.Store
+	overrides map[models.AgentType][]string
+}
+
+// WithCheckpoints enables session checkpoints stored in store. overrides
+// replaces the adapter's session patterns for the agent types it lists.
+func WithCheckpoints(store *checkpoint.Store, overrides map[models.AgentType][]string) ServiceOption {
+	return func(s *Service) {
+		s.checkpoints = &checkpointer{store: store, overrides: overrides}
+	}
+}
+
+// Checkpoints returns the configured checkpoint store, or nil.
+func (s *Service) Checkpoints() *checkpoint.Store {
+	if s == nil || s.checkpoints == nil {
+		return nil
+	}
+	return s.checkpoints.store
+}
+
+// SessionPatterns returns the globs matching the session state of agents
+// of type agentType: the configured override, or else the adapter's own.
+func (s *Service) SessionPatterns(agentType models.AgentType) []string {
+	if s.checkpoints != nil {
+		if patterns := s.checkpoints.overrides[agentType]; len(patterns) > 0 {
+			return patterns
+		}
+	}
+	if provider, ok := adapters.GetByAgentType(agentType).(adapters.SessionProvider); ok {
+		return provider.SessionPatterns()
+	}
+	return nil
+}
+
+// Checkpoint archives an agent's session files under name, or a name
+// derived from the agent ID and time when it is empty. The agent is paused
+// while the files are read so the scheduler sends it nothing mid-archive,
+// and resumed afterwards unless it was already paused. Session files are
+// read on this machine, so agents on swarmd nodes are refused.
+func (s *Service) Checkpoint(ctx context.Context, id, name string) (*checkpoint.Checkpoint, error) {
+	if s.checkpoints == nil {
+		return nil, ErrCheckpointsDisabled
+	}
+	agent, err := s.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if agent.RemoteAgentID != "" {
+		return nil, fmt.Errorf("checkpoints are %w", ErrRemoteAgent)
+	}
+	patterns := s.SessionPatterns(agent.Type)
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("%w %s", ErrNoSessionPatterns, agent.Type)
+	}
+	ws, err := s.checkpointWorkspace(ctx, agent.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", shortAgentID(agent.ID), time.Now().UTC().Format("20060102-150405"))
+	}
+	if _, err := s.checkpoints.store.Get(name); err == nil {
+		return nil, fmt.Errorf("%w: %s", checkpoint.ErrExists, name)
+	}
+
+	if agent.State != models.AgentStatePaused {
+		if err := s.PauseAgent(ctx, agent.ID, checkpointPause); err != nil {
+			return nil, fmt.Errorf("failed to pause agent: %w", err)
+		}
+		defer func() {
+			if err := s.ResumeAgent(ctx, agent.ID); err != nil {
+				s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to resume agent after checkpoint")
+			}
+		}()
+	}
+
+	cp := &checkpoint.Checkpoint{
+		Name:        name,
+		AgentID:     agent.ID,
+		AgentType:   agent.Type,
+		WorkspaceID: agent.WorkspaceID,
+		Vars:        sessionVars(agent.Metadata.Environment, ws),
+		Patterns:    patterns,
+	}
+	if err := s.checkpoints.store.Create(cp); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("agent_id", agent.ID).
+		Str("checkpoint", cp.Name).
+		Int("files", cp.Files).
+		Msg("agent session checkpoint saved")
+
+	s.publishEvent(ctx, models.EventTypeAgentCheckpointed, agent.ID, models.AgentCheckpointPayload{
+		Checkpoint: cp.Name,
+		Files:      cp.Files,
+	})
+	return cp, nil
+}
+
+// RestoreCheckpointOptions selects what a checkpoint is restored into.
+type RestoreCheckpointOptions struct {
+	// Checkpoint is the name of the checkpoint to restore.
+	Checkpoint string
+
+	// AgentID restarts this agent on the checkpoint. It must be of the
+	// checkpoint's agent type.
+	AgentID string
+
+	// WorkspaceID spawns a new agent in this workspace when AgentID is
+	// empty.
+	WorkspaceID string
+}
+
+// RestoreCheckpoint writes a checkpoint's session files back and starts
+// an agent CLI that resumes the session. An existing agent is terminated
+// first, so the CLI is not running while its files are replaced, and is
+// respawned with the options it had. Restoring into another workspace
+// rewrites the old paths in text session files; the result lists
+// warnings for files that could not be rewritten.
+func (s *Service) RestoreCheckpoint(ctx context.Context, opts RestoreCheckpointOptions) (restored *models.Agent, _ *checkpoint.RestoreResult, err error) {
+	defer func() {
+		id := opts.AgentID
+		if restored != nil {
+			id = restored.ID
+		}
+		s.audit(ctx, AuditRestoreCheckpoint, id, audit.Params("checkpoint", opts.Checkpoint, "workspace_id", opts.WorkspaceID), err)
+	}()
+	if s.checkpoints == nil {
+		return nil, nil, ErrCheckpointsDisabled
+	}
+	cp, err := s.checkpoints.store.Get(opts.Checkpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	spawn := SpawnOptions{WorkspaceID: opts.WorkspaceID, Type: cp.AgentType, Resume: true}
+	var existing *models.Agent
+	if opts.AgentID != "" {
+		existing, err = s.GetAgent(ctx, opts.AgentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if existing.RemoteAgentID != "" {
+			return nil, nil, fmt.Errorf("checkpoints are %w", ErrRemoteAgent)
+		}
+		if existing.Type != cp.AgentType {
+			return nil, nil, fmt.Errorf("checkpoint %s is of a %s agent, not %s", cp.Name, cp.AgentType, existing.Type)
+		}
+		spawn.WorkspaceID = existing.WorkspaceID
+		spawn.AccountID = existing.AccountID
+		spawn.Environment = existing.Metadata.Environment
+		spawn.ApprovalPolicy = existing.Metadata.ApprovalPolicy
+		spawn.Model = existing.Metadata.Model
+	}
+	ws, err := s.checkpointWorkspace(ctx, spawn.WorkspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if existing != nil {
+		if _, err := s.TerminateAgent(ctx, existing.ID, TerminateOptions{}); err != nil {
+			if errors.Is(err, tmux.ErrPaneStillAlive) {
+				return nil, nil, err
+			}
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", existing.ID).Msg("failed to terminate agent during restore")
+		}
+	}
+
+	result, err := s.checkpoints.store.Restore(cp, sessionVars(spawn.Environment, ws))
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to restore checkpoint %s: %w", cp.Name, err)
+	}
+	for _, warning := range result.Warnings {
+		s.logger.Warn().Ctx(ctx).Str("checkpoint", cp.Name).Msg(warning)
+	}
+
+	agent, err := s.SpawnAgent(ctx, spawn)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to spawn agent: %w", err)
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("agent_id", agent.ID).
+		Str("checkpoint", cp.Name).
+		Int("files", result.Files).
+		Int("rewritten", result.Rewritten).
+		Msg("agent restored from checkpoint")
+
+	s.publishEvent(ctx, models.EventTypeAgentRestored, agent.ID, models.AgentCheckpointPayload{
+		Checkpoint: cp.Name,
+		Files:      result.Files,
+		Warnings:   len(result.Warnings),
+	})
+	return agent, result, nil
+}
+
+// checkpointWorkspace loads a workspace whose session files can be read
+// and written on this machine.
+func (s *Service) checkpointWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	ws, err := s.workspaceService.GetWorkspace(ctx, id)
+	if err != nil {
+		if errors.Is(err, workspace.ErrWorkspaceNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	daemon, err := s.daemonForNode(ctx, ws.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	if daemon != nil {
+		return nil, fmt.Errorf("checkpoints are %w", ErrRemoteAgent)
+	}
+	return ws, nil
+}
+
+// sessionVars returns where an agent with env in ws keeps its session: the
+// HOME it was given, or else this user's, and the workspace repo.
+func sessionVars(env map[string]string, ws *models.Workspace) checkpoint.Vars {
+	home := env["HOME"]
+	if home == "" {
+		home, _ = os.UserHomeDir()
+	}
+	return checkpoint.Vars{Home: home, WorkDir: ws.RepoPath}
+}
+
+func shortAgentID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
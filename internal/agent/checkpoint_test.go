@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/checkpoint"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+// writeClaudeSession creates a Claude Code session file for workDir under
+// home and returns its path.
+func writeClaudeSession(t *testing.T, home, workDir, content string) string {
+	t.Helper()
+	dir := filepath.Join(home, ".claude", "projects", strings.ReplaceAll(workDir, "/", "-"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "session.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckpointPausesWhileArchiving(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t)
+	agent := seedMoveAgent(t, env)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeClaudeSession(t, home, "/repo", `{"cwd":"/repo"}`)
+
+	if _, err := env.service.Checkpoint(ctx, agent.ID, "first"); !errors.Is(err, ErrCheckpointsDisabled) {
+		t.Fatalf("expected ErrCheckpointsDisabled, got %v", err)
+	}
+
+	store := checkpoint.NewStore(t.TempDir())
+	WithCheckpoints(store, nil)(env.service)
+
+	// Record, at each lifecycle event, whether the archive existed yet.
+	var sequence []string
+	publisher := events.NewInMemoryPublisher()
+	if err := publisher.Subscribe("checkpoints", events.Filter{}, func(event *models.Event) {
+		_, err := os.Stat(store.ArchivePath("first"))
+		sequence = append(sequence, string(event.Type)+" archived="+strconv.FormatBool(err == nil))
+	}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	WithPublisher(publisher)(env.service)
+
+	cp, err := env.service.Checkpoint(ctx, agent.ID, "first")
+	if err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	if cp.Files != 1 || cp.AgentType != models.AgentTypeClaudeCode || cp.Vars.Home != home || cp.Vars.WorkDir != "/repo" {
+		t.Fatalf("unexpected checkpoint %+v", cp)
+	}
+	want := []string{
+		"agent.paused archived=false",
+		"agent.checkpointed archived=true",
+		"agent.resumed archived=true",
+	}
+	if strings.Join(sequence, "; ") != strings.Join(want, "; ") {
+		t.Fatalf("expected %v, got %v", want, sequence)
+	}
+	stored, err := db.NewAgentRepository(env.database).Get(ctx, agent.ID)
+	if err != nil || stored.State != models.AgentStateIdle {
+		t.Fatalf("expected the agent resumed, got %v (%v)", stored, err)
+	}
+
+	// A name is used once, and an agent paused beforehand stays paused.
+	if _, err := env.service.Checkpoint(ctx, agent.ID, "first"); !errors.Is(err, checkpoint.ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if err := env.service.PauseAgent(ctx, agent.ID, 0); err != nil {
+		t.Fatalf("PauseAgent failed: %v", err)
+	}
+	sequence = nil
+	if _, err := env.service.Checkpoint(ctx, agent.ID, ""); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	if len(sequence) != 1 || !strings.HasPrefix(sequence[0], "agent.checkpointed") {
+		t.Fatalf("expected only a checkpoint event for a paused agent, got %v", sequence)
+	}
+	stored, _ = db.NewAgentRepository(env.database).Get(ctx, agent.ID)
+	if stored.State != models.AgentStatePaused {
+		t.Fatalf("expected the agent to stay paused, got %s", stored.State)
+	}
+}
+
+func TestRestoreCheckpointIntoNewWorkspace(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []string
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		gotArgs = term.Args()
+		term.Print("claude> ")
+	}))
+	agent := seedMoveAgent(t, env)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeClaudeSession(t, home, "/repo", `{"cwd":"/repo/cmd"}`)
+
+	store := checkpoint.NewStore(t.TempDir())
+	WithCheckpoints(store, nil)(env.service)
+	if _, err := env.service.Checkpoint(ctx, agent.ID, "move"); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+
+	other := createMoveWorkspace(t, env, env.nodeID, "other")
+	if _, err := env.tmux.Run("new-session", "-d", "-s", "other", "-c", "/other"); err != nil {
+		t.Fatalf("failed to seed tmux: %v", err)
+	}
+	restored, result, err := env.service.RestoreCheckpoint(ctx, RestoreCheckpointOptions{Checkpoint: "move", WorkspaceID: other.ID})
+	if err != nil {
+		t.Fatalf("RestoreCheckpoint failed: %v", err)
+	}
+	if restored.WorkspaceID != other.ID || restored.Type != models.AgentTypeClaudeCode {
+		t.Fatalf("unexpected restored agent %+v", restored)
+	}
+	if result.Files != 1 || result.Rewritten != 1 || len(result.Warnings) != 0 {
+		t.Fatalf("unexpected restore result %+v", result)
+	}
+	if strings.Join(gotArgs, " ") != "--continue" {
+		t.Fatalf("expected the CLI to resume its session, got %v", gotArgs)
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".claude", "projects", "-other", "session.jsonl"))
+	if err != nil || string(data) != `{"cwd":"/other/cmd"}` {
+		t.Fatalf("expected the session under the new project with paths rewritten, got %q (%v)", data, err)
+	}
+}
+
+func TestRestoreCheckpointRejectsOtherAgentType(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t)
+	agent := seedMoveAgent(t, env)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeClaudeSession(t, home, "/repo", "{}")
+
+	store := checkpoint.NewStore(t.TempDir())
+	WithCheckpoints(store, nil)(env.service)
+	if _, err := env.service.Checkpoint(ctx, agent.ID, "claude"); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+
+	codex := &models.Agent{WorkspaceID: env.workspaceID, Type: models.AgentTypeCodex, TmuxPane: "%9", State: models.AgentStateIdle}
+	if err := db.NewAgentRepository(env.database).Create(ctx, codex); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	if _, _, err := env.service.RestoreCheckpoint(ctx, RestoreCheckpointOptions{Checkpoint: "claude", AgentID: codex.ID}); err == nil || !strings.Contains(err.Error(), "not codex") {
+		t.Fatalf("expected an agent type mismatch, got %v", err)
+	}
+	if _, _, err := env.service.RestoreCheckpoint(ctx, RestoreCheckpointOptions{Checkpoint: "missing", AgentID: codex.ID}); !errors.Is(err, checkpoint.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
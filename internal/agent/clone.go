@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ClonedAgent maps a source agent to the agent spawned in its place.
+type ClonedAgent struct {
+	SourceID string           `json:"source_id"`
+	AgentID  string           `json:"agent_id,omitempty"`
+	Type     models.AgentType `json:"type"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// CloneAgents spawns a fresh copy of every agent in the source workspace into
+// the target workspace. Only spawn configuration (type, account, approval
+// policy, environment) is carried over; runtime state and queues are not.
+// Spawn failures are recorded per agent and do not stop the remaining clones.
+func (s *Service) CloneAgents(ctx context.Context, sourceWorkspaceID, targetWorkspaceID string) ([]ClonedAgent, error) {
+	if sourceWorkspaceID == targetWorkspaceID {
+		return nil, fmt.Errorf("source and target workspace must differ")
+	}
+
+	sources, err := s.repo.ListByWorkspace(ctx, sourceWorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source agents: %w", err)
+	}
+
+	results := make([]ClonedAgent, 0, len(sources))
+	for _, source := range sources {
+		result := ClonedAgent{SourceID: source.ID, Type: source.Type}
+
+		spawned, err := s.SpawnAgent(ctx, cloneSpawnOptions(source, targetWorkspaceID))
+		if err != nil {
+			result.Error = err.Error()
+			s.logger.Warn().Ctx(ctx).Err(err).
+				Str("source_agent_id", source.ID).
+				Str("workspace_id", targetWorkspaceID).
+				Msg("failed to clone agent")
+		} else {
+			result.AgentID = spawned.ID
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// cloneSpawnOptions derives spawn options for a copy of source in workspaceID.
+func cloneSpawnOptions(source *models.Agent, workspaceID string) SpawnOptions {
+	opts := splitEnvironment(source.Metadata)
+	opts.WorkspaceID = workspaceID
+	opts.Type = source.Type
+	opts.AccountID = source.AccountID
+	opts.ApprovalPolicy = source.Metadata.ApprovalPolicy
+	opts.Model = source.Metadata.Model
+	return opts
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+func TestCloneSpawnOptionsCopiesConfigOnly(t *testing.T) {
+	pausedUntil := time.Now().Add(time.Hour)
+	source := &models.Agent{
+		ID:          "agent-src",
+		WorkspaceID: "ws-src",
+		Type:        models.AgentTypeClaudeCode,
+		AccountID:   "work",
+		State:       models.AgentStatePaused,
+		QueueLength: 4,
+		PausedUntil: &pausedUntil,
+		Metadata: models.AgentMetadata{
+			ApprovalPolicy: "strict",
+			Model:          "opus",
+			Environment:    map[string]string{"FOO": "bar"},
+			StartCommand:   "claude",
+			PID:            1234,
+		},
+	}
+
+	opts := cloneSpawnOptions(source, "ws-dst")
+	if opts.WorkspaceID != "ws-dst" || opts.Type != source.Type || opts.AccountID != "work" || opts.ApprovalPolicy != "strict" || opts.Model != "opus" {
+		t.Fatalf("unexpected spawn options %+v", opts)
+	}
+	if opts.InitialPrompt != "" {
+		t.Fatalf("expected no initial prompt, got %q", opts.InitialPrompt)
+	}
+	if opts.Environment["FOO"] != "bar" {
+		t.Fatalf("expected environment to be copied, got %v", opts.Environment)
+	}
+
+	opts.Environment["FOO"] = "changed"
+	if source.Metadata.Environment["FOO"] != "bar" {
+		t.Fatal("expected environment copy to be independent of the source")
+	}
+}
+
+func TestCloneAgentsRecordsPerAgentFailures(t *testing.T) {
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusUnknown, SSHBackend: models.SSHBackendAuto}
+	if err := nodeRepo.Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	wsRepo := db.NewWorkspaceRepository(database)
+	source := &models.Workspace{NodeID: localNode.ID, Name: "src", RepoPath: "/tmp/src", TmuxSession: "src"}
+	if err := wsRepo.Create(ctx, source); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	agentRepo := db.NewAgentRepository(database)
+	for _, agentType := range []models.AgentType{models.AgentTypeOpenCode, models.AgentTypeCodex} {
+		a := &models.Agent{
+			WorkspaceID: source.ID,
+			Type:        agentType,
+			TmuxPane:    "src:0." + string(agentType),
+			State:       models.AgentStateIdle,
+			StateInfo:   models.StateInfo{State: models.AgentStateIdle, Confidence: models.StateConfidenceHigh},
+		}
+		if err := agentRepo.Create(ctx, a); err != nil {
+			t.Fatalf("failed to create agent: %v", err)
+		}
+	}
+
+	wsService := workspace.NewService(wsRepo, node.NewService(nodeRepo), agentRepo)
+	service := NewService(agentRepo, db.NewQueueRepository(database), wsService, nil, nil)
+
+	if _, err := service.CloneAgents(ctx, source.ID, source.ID); err == nil {
+		t.Fatal("expected error when cloning into the source workspace")
+	}
+
+	// The target workspace does not exist, so every spawn fails before
+	// touching tmux; the failures must be reported per source agent.
+	results, err := service.CloneAgents(ctx, source.ID, "ws-missing")
+	if err != nil {
+		t.Fatalf("CloneAgents returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.SourceID == "" || result.AgentID != "" {
+			t.Fatalf("unexpected result %+v", result)
+		}
+		if !strings.Contains(result.Error, ErrWorkspaceNotFound.Error()) {
+			t.Fatalf("expected workspace not found error, got %q", result.Error)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// daemonDispatcher routes agent operations for workspaces on daemon-backed
+// nodes to the node's swarmd, keeping one client per node.
+type daemonDispatcher struct {
+	nodes *db.NodeRepository
+	opts  []swarmd.ClientOption
+
+	mu      sync.Mutex
+	clients map[string]*swarmd.Client
+}
+
+// WithDaemonNodes routes spawn, input, capture, and kill for workspaces on
+// daemon-backed nodes through the node's swarmd instead of local tmux.
+func WithDaemonNodes(nodes *db.NodeRepository, opts ...swarmd.ClientOption) ServiceOption {
+	return func(s *Service) {
+		s.daemons = &daemonDispatcher{
+			nodes:   nodes,
+			opts:    opts,
+			clients: make(map[string]*swarmd.Client),
+		}
+	}
+}
+
+// client returns the swarmd client for nodeID, or nil if the node is not
+// daemon-backed.
+func (d *daemonDispatcher) client(ctx context.Context, nodeID string) (*swarmd.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if client, ok := d.clients[nodeID]; ok {
+		return client, nil
+	}
+
+	n, err := d.nodes.Get(ctx, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	if !n.IsDaemon() {
+		return nil, nil
+	}
+	client, err := node.DialDaemon(ctx, n, d.opts...)
+	if err != nil {
+		return nil, err
+	}
+	d.clients[nodeID] = client
+	return client, nil
+}
+
+// daemonForNode returns the swarmd client for agents on nodeID, or nil
+// when they run on local tmux.
+func (s *Service) daemonForNode(ctx context.Context, nodeID string) (*swarmd.Client, error) {
+	if s.daemons == nil || nodeID == "" {
+		return nil, nil
+	}
+	return s.daemons.client(ctx, nodeID)
+}
+
+// daemonForAgent returns the swarmd client managing a remote agent.
+func (s *Service) daemonForAgent(ctx context.Context, agent *models.Agent) (*swarmd.Client, error) {
+	if s.daemons == nil {
+		return nil, fmt.Errorf("agent %s runs on a swarmd node but daemon nodes are not configured", agent.ID)
+	}
+	ws, err := s.workspaceService.GetWorkspace(ctx, agent.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	client, err := s.daemons.client(ctx, ws.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, fmt.Errorf("node %s is no longer daemon-backed", ws.NodeID)
+	}
+	return client, nil
+}
+
+// spawnOnDaemon starts an agent through a node's swarmd. The agent row is
+// kept here with the daemon's agent ID and pane. Account credentials are
+// sent as the request's env rather than inlined into the command, so
+// neither the command nor the agent row holds them.
+func (s *Service) spawnOnDaemon(ctx context.Context, client *swarmd.Client, ws *models.Workspace, workDir string, opts SpawnOptions, resolvedEnv []models.EnvVar) (*models.Agent, error) {
+	credentials := credentialsOf(opts.Environment, resolvedEnv)
+	opts.Environment = withoutCredentials(opts.Environment, resolvedEnv)
+	startCmd := s.buildStartCommand(opts)
+	if startCmd == "" {
+		return nil, fmt.Errorf("%w: no start command for agent type %s", ErrSpawnFailed, opts.Type)
+	}
+
+	agentID := uuid.New().String()
+	resp, err := client.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{
+		AgentId:     agentID,
+		WorkspaceId: ws.ID,
+		Command:     startCmd,
+		Env:         credentials,
+		WorkingDir:  workDir,
+		SessionName: ws.TmuxSession,
+		Adapter:     string(opts.Type),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: swarmd spawn failed: %v", ErrSpawnFailed, err)
+	}
+
+	agent := &models.Agent{
+		ID:            agentID,
+		WorkspaceID:   ws.ID,
+		Type:          opts.Type,
+		TmuxPane:      resp.GetPaneId(),
+		TmuxSession:   ws.TmuxSession,
+		RemoteAgentID: agentID,
+		AccountID:     opts.AccountID,
+		State:         models.AgentStateStarting,
+		StateInfo: models.StateInfo{
+			State:      models.AgentStateStarting,
+			Confidence: models.StateConfidenceHigh,
+			Reason:     "Agent spawned on swarmd, awaiting startup",
+			DetectedAt: time.Now().UTC(),
+		},
+		Metadata: models.AgentMetadata{
+			Model:          opts.Model,
+			Environment:    opts.Environment,
+			ResolvedEnv:    resolvedEnv,
+			ApprovalPolicy: opts.ApprovalPolicy,
+			StartCommand:   startCmd,
+			Ephemeral:      opts.Ephemeral,
+		},
+	}
+	if err := s.repo.Create(ctx, agent); err != nil {
+		_, _ = client.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: agentID, Force: true})
+		if errors.Is(err, db.ErrAgentAlreadyExists) {
+			return nil, ErrAgentAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+
+	if opts.InitialPrompt != "" {
+		time.Sleep(500 * time.Millisecond)
+		if _, err := client.SendInput(ctx, &swarmdv1.SendInputRequest{AgentId: agentID, Text: opts.InitialPrompt, SendEnter: true}); err != nil {
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to send initial prompt")
+		}
+	}
+
+	if err := s.waitForReady(ctx, agent, opts); err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("agent failed to reach ready state")
+		s.markAgentError(ctx, agent, err.Error(), models.StateConfidenceLow, nil)
+		s.cleanupSpawnFailure(ctx, agent)
+		return nil, fmt.Errorf("%w: %v", ErrSpawnFailed, err)
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("agent_id", agent.ID).
+		Str("workspace_id", ws.ID).
+		Str("node_id", ws.NodeID).
+		Str("type", string(opts.Type)).
+		Str("pane", agent.TmuxPane).
+		Msg("agent spawned on swarmd")
+
+	s.publishEvent(ctx, models.EventTypeAgentSpawned, agent.ID, models.AgentSpawnedPayload{
+		WorkspaceID: agent.WorkspaceID,
+		Type:        agent.Type,
+		AccountID:   agent.AccountID,
+	})
+
+	return agent, nil
+}
+
+// capturePane captures an agent's pane from local tmux or its swarmd.
+func (s *Service) capturePane(ctx context.Context, agent *models.Agent, history bool) (string, error) {
+	if agent.RemoteAgentID == "" {
+		return s.tmuxClient.CapturePane(ctx, agent.TmuxPane, history)
+	}
+	client, err := s.daemonForAgent(ctx, agent)
+	if err != nil {
+		return "", err
+	}
+	req := &swarmdv1.CapturePaneRequest{AgentId: agent.RemoteAgentID}
+	if history {
+		req.Lines = -1
+	}
+	resp, err := client.CapturePane(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.GetContent(), nil
+}
+
+// sendRemoteInput sends text or keys to a remote agent's pane.
+func (s *Service) sendRemoteInput(ctx context.Context, agent *models.Agent, req *swarmdv1.SendInputRequest) error {
+	client, err := s.daemonForAgent(ctx, agent)
+	if err != nil {
+		return err
+	}
+	req.AgentId = agent.RemoteAgentID
+	_, err = client.SendInput(ctx, req)
+	return err
+}
+
+// killRemoteAgent stops a remote agent. An agent the daemon no longer
+// knows about is already gone.
+func (s *Service) killRemoteAgent(ctx context.Context, agent *models.Agent, force bool) error {
+	client, err := s.daemonForAgent(ctx, agent)
+	if err != nil {
+		return err
+	}
+	_, err = client.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: agent.RemoteAgentID, Force: force})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+// sendRemoteMessage types message into a remote agent's pane and submits
+// it. Multi-line messages are bracketed as a paste so newlines do not
+// submit early.
+func (s *Service) sendRemoteMessage(ctx context.Context, agent *models.Agent, message string, sensitive bool) error {
+	if strings.Contains(message, "\n") || strings.Contains(message, "\r") {
+		message = pasteStartSequence + normalizeNewlines(message) + pasteEndSequence
+	}
+	return s.sendRemoteInput(ctx, agent, &swarmdv1.SendInputRequest{Text: message, SendEnter: true, Sensitive: sensitive})
+}
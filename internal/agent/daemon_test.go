@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+	"github.com/opencode-ai/swarm/internal/swarmd/swarmdtest"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/rs/zerolog"
+)
+
+// newDaemonTestService returns a service whose workspace, "remote", is on
+// a node served by a swarmd running its agents in remote.
+func newDaemonTestService(t *testing.T, env *spawnTestEnv, remote *tmuxtest.Server, accounts *account.Service) (*Service, *models.Workspace, *models.Node, *swarmdtest.Daemon) {
+	t.Helper()
+	ctx := context.Background()
+	daemon := swarmdtest.Serve(t, swarmd.NewServer(zerolog.Nop(), swarmd.WithTmuxClient(tmux.NewClient(remote))), "secret")
+
+	nodeRepo := db.NewNodeRepository(env.database)
+	gpu := &models.Node{Name: "gpu", DaemonEndpoint: swarmdtest.Endpoint, DaemonToken: "secret", Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := nodeRepo.Create(ctx, gpu); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	wsRepo := db.NewWorkspaceRepository(env.database)
+	ws := &models.Workspace{NodeID: gpu.ID, Name: "remote", RepoPath: "/srv/repo", TmuxSession: "remote"}
+	if err := wsRepo.Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	agentRepo := db.NewAgentRepository(env.database)
+	service := NewService(agentRepo, db.NewQueueRepository(env.database),
+		workspace.NewService(wsRepo, node.NewService(nodeRepo), agentRepo), accounts, tmux.NewClient(env.tmux),
+		WithDaemonNodes(nodeRepo, daemon.ClientOptions()...))
+	return service, ws, gpu, daemon
+}
+
+func TestSpawnAndTerminateOnDaemonNode(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t)
+
+	remote := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+		term.OnInput(func(line string) {
+			term.Print("got: " + line + "\n")
+		})
+	}))
+	service, ws, gpu, daemon := newDaemonTestService(t, env, remote, nil)
+	agentRepo := service.repo
+
+	agent, err := service.SpawnAgent(ctx, SpawnOptions{
+		WorkspaceID:       ws.ID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	if agent.State != models.AgentStateIdle || agent.RemoteAgentID != agent.ID {
+		t.Fatalf("expected an idle agent recording its daemon ID, got %s with remote ID %q", agent.State, agent.RemoteAgentID)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected no local pane for a daemon agent, got %s", got)
+	}
+	if _, err := remote.Run("has-session", "-t", "remote"); err != nil {
+		t.Fatalf("expected the daemon to create the workspace session: %v", err)
+	}
+
+	if err := service.SendMessage(ctx, agent.ID, "fix the build", nil); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	state, err := service.GetAgentState(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("GetAgentState failed: %v", err)
+	}
+	if !state.PaneActive || !strings.Contains(state.LastOutput, "got: fix the build") {
+		t.Fatalf("expected the message in the remote pane, got %+v", state)
+	}
+
+	if _, err := service.TerminateAgent(ctx, agent.ID, TerminateOptions{}); err != nil {
+		t.Fatalf("TerminateAgent failed: %v", err)
+	}
+	client, err := node.DialDaemon(ctx, gpu, daemon.ClientOptions()...)
+	if err != nil {
+		t.Fatalf("DialDaemon failed: %v", err)
+	}
+	defer client.Close()
+	listed, err := client.ListAgents(ctx, &swarmdv1.ListAgentsRequest{})
+	if err != nil {
+		t.Fatalf("ListAgents failed: %v", err)
+	}
+	if len(listed.GetAgents()) != 0 {
+		t.Fatalf("expected the daemon to drop the agent, got %v", listed.GetAgents())
+	}
+	if _, err := agentRepo.Get(ctx, agent.ID); err == nil {
+		t.Fatalf("expected the agent row to be deleted")
+	}
+}
+
+func TestSpawnOnDaemonNodeKeepsCredentialsOut(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t)
+	t.Setenv("SWARM_DAEMON_TEST_KEY", "sk-ant-daemon-secret")
+
+	accounts := account.NewService(config.DefaultConfig())
+	acct := &models.Account{ID: "acct-1", Provider: models.ProviderAnthropic, ProfileName: "primary", CredentialRef: "env:SWARM_DAEMON_TEST_KEY", IsActive: true}
+	if err := accounts.AddAccount(ctx, acct); err != nil {
+		t.Fatalf("failed to add account: %v", err)
+	}
+	if err := db.NewAccountRepository(env.database).Create(ctx, acct); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	remote := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	service, ws, _, _ := newDaemonTestService(t, env, remote, accounts)
+
+	agent, err := service.SpawnAgent(ctx, SpawnOptions{
+		WorkspaceID:       ws.ID,
+		Type:              models.AgentTypeClaudeCode,
+		AccountID:         acct.ID,
+		Environment:       map[string]string{"REGION": "eu"},
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+
+	stored, err := service.repo.Get(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("failed to get agent: %v", err)
+	}
+	meta := stored.Metadata
+	if strings.Contains(meta.StartCommand, "sk-ant-daemon-secret") {
+		t.Fatalf("expected the credential kept out of the start command, got %q", meta.StartCommand)
+	}
+	if !reflect.DeepEqual(meta.Environment, map[string]string{"REGION": "eu"}) {
+		t.Fatalf("expected only the flag variables stored, got %v", meta.Environment)
+	}
+	var key *models.EnvVar
+	for i := range meta.ResolvedEnv {
+		if meta.ResolvedEnv[i].Name == "ANTHROPIC_API_KEY" {
+			key = &meta.ResolvedEnv[i]
+		}
+	}
+	if key == nil || key.Source != models.EnvSourceAccount || !key.Secret || strings.Contains(key.Value, "sk-ant-daemon-secret") {
+		t.Fatalf("expected the credential recorded as a redacted account variable, got %+v", meta.ResolvedEnv)
+	}
+
+	// The daemon still receives the credential, as the request's env.
+	if cmds := strings.Join(remote.Commands(), "\n"); !strings.Contains(cmds, "ANTHROPIC_API_KEY") {
+		t.Fatalf("expected the credential exported in the remote pane, got %q", cmds)
+	}
+}
@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/redact"
+)
+
+// accountCredentialLabel marks account credentials no redaction rule
+// recognizes.
+const accountCredentialLabel = "account_credential"
+
+// EnvLayer is one source of variables for an agent's spawn environment.
+type EnvLayer struct {
+	Source models.EnvSource
+	Vars   map[string]string
+}
+
+// spawnEnvLayers returns the environment layers of a spawn in increasing
+// precedence. Account credentials come last so a stale key recorded with
+// the agent never shadows the account's current one.
+func spawnEnvLayers(opts SpawnOptions, credentials map[string]string) []EnvLayer {
+	return []EnvLayer{
+		{Source: models.EnvSourceWorkspace, Vars: opts.WorkspaceEnvironment},
+		{Source: models.EnvSourceTemplate, Vars: opts.TemplateEnvironment},
+		{Source: models.EnvSourceFlag, Vars: opts.Environment},
+		{Source: models.EnvSourceAccount, Vars: credentials},
+	}
+}
+
+// ResolveEnvironment merges layers in order, later layers taking
+// precedence, and returns the merged environment along with a record of it
+// sorted by name. In the record each variable names the layer that set it;
+// account credentials and values the default redaction rules match are
+// replaced with their markers, and every entry keeps a hash of the real
+// value.
+func ResolveEnvironment(layers ...EnvLayer) (map[string]string, []models.EnvVar) {
+	env := make(map[string]string)
+	vars := make(map[string]*models.EnvVar)
+	for _, layer := range layers {
+		for name, value := range layer.Vars {
+			env[name] = value
+			v, ok := vars[name]
+			if !ok {
+				v = &models.EnvVar{Name: name}
+				vars[name] = v
+			} else {
+				v.Overrides = append(v.Overrides, v.Source)
+			}
+			v.Source = layer.Source
+			v.Value, v.Secret = redactEnvValue(name, value, layer.Source)
+			v.Hash = EnvValueHash(value)
+		}
+	}
+	if len(env) == 0 {
+		return nil, nil
+	}
+
+	resolved := make([]models.EnvVar, 0, len(vars))
+	for _, v := range vars {
+		resolved = append(resolved, *v)
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].Name < resolved[j].Name })
+	return env, resolved
+}
+
+// EnvValueHash returns the hash ResolveEnvironment records for value.
+func EnvValueHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// redactEnvValue returns the value to record for a variable and whether it
+// was redacted. The name is redacted along with the value so rules keyed on
+// names like *_TOKEN apply.
+func redactEnvValue(name, value string, source models.EnvSource) (string, bool) {
+	pair := name + "=" + value
+	if redacted := redact.Default().Redact(pair); redacted != pair {
+		return strings.TrimPrefix(redacted, name+"="), true
+	}
+	if source == models.EnvSourceAccount {
+		return redact.Marker(accountCredentialLabel), true
+	}
+	return value, false
+}
+
+// splitEnvironment divides a recorded environment back into the layers it
+// was resolved from, for respawning an agent. Account credentials are left
+// out since the spawn injects them afresh; variables with no record count
+// as flags.
+func splitEnvironment(meta models.AgentMetadata) SpawnOptions {
+	sources := make(map[string]models.EnvSource, len(meta.ResolvedEnv))
+	for _, v := range meta.ResolvedEnv {
+		sources[v.Name] = v.Source
+	}
+
+	var opts SpawnOptions
+	for name, value := range meta.Environment {
+		var layer *map[string]string
+		switch sources[name] {
+		case models.EnvSourceAccount:
+			continue
+		case models.EnvSourceWorkspace:
+			layer = &opts.WorkspaceEnvironment
+		case models.EnvSourceTemplate:
+			layer = &opts.TemplateEnvironment
+		default:
+			layer = &opts.Environment
+		}
+		if *layer == nil {
+			*layer = make(map[string]string)
+		}
+		(*layer)[name] = value
+	}
+	return opts
+}
+
+// withoutCredentials returns env less the variables resolved from the
+// account, which are not stored with the agent.
+func withoutCredentials(env map[string]string, resolved []models.EnvVar) map[string]string {
+	stored := make(map[string]string, len(env))
+	for _, v := range resolved {
+		if v.Source != models.EnvSourceAccount {
+			stored[v.Name] = env[v.Name]
+		}
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+	return stored
+}
+
+// credentialsOf returns the variables of env resolved from the account.
+func credentialsOf(env map[string]string, resolved []models.EnvVar) map[string]string {
+	var creds map[string]string
+	for _, v := range resolved {
+		if v.Source != models.EnvSourceAccount {
+			continue
+		}
+		if creds == nil {
+			creds = make(map[string]string)
+		}
+		creds[v.Name] = env[v.Name]
+	}
+	return creds
+}
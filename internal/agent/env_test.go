@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/redact"
+)
+
+func TestResolveEnvironmentPrecedence(t *testing.T) {
+	env, resolved := ResolveEnvironment(spawnEnvLayers(SpawnOptions{
+		WorkspaceEnvironment: map[string]string{"REGION": "eu", "LOG_LEVEL": "info"},
+		TemplateEnvironment:  map[string]string{"LOG_LEVEL": "debug"},
+		Environment:          map[string]string{"LOG_LEVEL": "trace", "ANTHROPIC_API_KEY": "from-flag"},
+	}, map[string]string{"ANTHROPIC_API_KEY": "from-account"})...)
+
+	want := map[string]string{"REGION": "eu", "LOG_LEVEL": "trace", "ANTHROPIC_API_KEY": "from-account"}
+	if !reflect.DeepEqual(env, want) {
+		t.Fatalf("unexpected merged environment %v", env)
+	}
+
+	if len(resolved) != 3 {
+		t.Fatalf("expected 3 variables, got %+v", resolved)
+	}
+	key, level, region := resolved[0], resolved[1], resolved[2]
+	if key.Name != "ANTHROPIC_API_KEY" || level.Name != "LOG_LEVEL" || region.Name != "REGION" {
+		t.Fatalf("expected variables sorted by name, got %+v", resolved)
+	}
+	if key.Source != models.EnvSourceAccount || !reflect.DeepEqual(key.Overrides, []models.EnvSource{models.EnvSourceFlag}) {
+		t.Fatalf("unexpected account variable %+v", key)
+	}
+	if level.Source != models.EnvSourceFlag || level.Value != "trace" || level.Secret ||
+		!reflect.DeepEqual(level.Overrides, []models.EnvSource{models.EnvSourceWorkspace, models.EnvSourceTemplate}) {
+		t.Fatalf("unexpected flag variable %+v", level)
+	}
+	if region.Source != models.EnvSourceWorkspace || region.Overrides != nil || region.Hash != EnvValueHash("eu") {
+		t.Fatalf("unexpected workspace variable %+v", region)
+	}
+
+	if env, resolved := ResolveEnvironment(spawnEnvLayers(SpawnOptions{}, nil)...); env != nil || resolved != nil {
+		t.Fatalf("expected nothing for no variables, got %v %v", env, resolved)
+	}
+}
+
+func TestResolveEnvironmentRedaction(t *testing.T) {
+	_, resolved := ResolveEnvironment(
+		EnvLayer{Source: models.EnvSourceFlag, Vars: map[string]string{
+			"GITHUB_TOKEN": "ghp_abcdefghijklmnop",
+			"OPENAI_BASE":  "sk-proj-abcdefghijklmnopqrstuvwxyz",
+			"EDITOR":       "vim",
+		}},
+		EnvLayer{Source: models.EnvSourceAccount, Vars: map[string]string{"CUSTOM_CRED": "short"}},
+	)
+
+	got := make(map[string]models.EnvVar, len(resolved))
+	for _, v := range resolved {
+		got[v.Name] = v
+	}
+	for name, marker := range map[string]string{
+		"GITHUB_TOKEN": redact.Marker("env_secret"),
+		"OPENAI_BASE":  redact.Marker("openai_key"),
+		"CUSTOM_CRED":  redact.Marker(accountCredentialLabel),
+	} {
+		if v := got[name]; !v.Secret || v.Value != marker {
+			t.Errorf("%s: expected %s, got %+v", name, marker, v)
+		}
+	}
+	if v := got["EDITOR"]; v.Secret || v.Value != "vim" {
+		t.Errorf("expected EDITOR unredacted, got %+v", v)
+	}
+	if got["GITHUB_TOKEN"].Hash != EnvValueHash("ghp_abcdefghijklmnop") {
+		t.Error("expected the hash of the real value kept for a redacted variable")
+	}
+}
+
+func TestSplitEnvironment(t *testing.T) {
+	layers := SpawnOptions{
+		WorkspaceEnvironment: map[string]string{"REGION": "eu"},
+		TemplateEnvironment:  map[string]string{"ROLE": "reviewer"},
+		Environment:          map[string]string{"DEBUG": "1"},
+	}
+	env, resolved := ResolveEnvironment(spawnEnvLayers(layers, map[string]string{"ANTHROPIC_API_KEY": "sk"})...)
+	meta := models.AgentMetadata{Environment: withoutCredentials(env, resolved), ResolvedEnv: resolved}
+	if _, ok := meta.Environment["ANTHROPIC_API_KEY"]; ok {
+		t.Fatal("expected account credentials left out of the stored environment")
+	}
+
+	split := splitEnvironment(meta)
+	if !reflect.DeepEqual(split.WorkspaceEnvironment, layers.WorkspaceEnvironment) ||
+		!reflect.DeepEqual(split.TemplateEnvironment, layers.TemplateEnvironment) ||
+		!reflect.DeepEqual(split.Environment, layers.Environment) {
+		t.Fatalf("expected the layers restored, got %+v", split)
+	}
+
+	// Agents recorded before sources were tracked treat everything as flags.
+	split = splitEnvironment(models.AgentMetadata{Environment: map[string]string{"HOME": "/h"}})
+	if split.Environment["HOME"] != "/h" || split.WorkspaceEnvironment != nil {
+		t.Fatalf("unexpected split of an unrecorded environment %+v", split)
+	}
+}
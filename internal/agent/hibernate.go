@@ -0,0 +1,244 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrAgentNotHibernated is returned when waking an agent that is not
+// hibernated.
+var ErrAgentNotHibernated = errors.New("agent is not hibernated")
+
+// Wake reasons recorded on agent.woken events.
+const (
+	WakeReasonManual  = "woken by user"
+	WakeReasonQueue   = "queue item waiting"
+	WakeReasonMessage = "message sent"
+)
+
+// HibernateAgent frees the pane of an idle agent with nothing queued: its
+// session is checkpointed, the pane killed, and the agent left hibernated
+// until WakeAgent restores it. The agent keeps its ID and queue, so work
+// queued for it while it sleeps wakes it. Reason is recorded on the
+// agent.hibernated event. Hibernating a hibernated agent does nothing.
+func (s *Service) HibernateAgent(ctx context.Context, id, reason string) (hibernated *models.Agent, err error) {
+	defer func() { s.audit(ctx, AuditHibernateAgent, id, audit.Params("reason", reason), err) }()
+
+	agent, err := s.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if agent.State == models.AgentStateHibernated {
+		return agent, nil
+	}
+	if err := s.checkHibernatable(ctx, agent); err != nil {
+		return nil, err
+	}
+
+	cp, err := s.Checkpoint(ctx, id, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkpoint agent: %w", err)
+	}
+
+	// The checkpoint paused and resumed the agent; work may have arrived
+	// in between.
+	agent, err = s.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkHibernatable(ctx, agent); err != nil {
+		return nil, err
+	}
+
+	_ = s.stopPipe(ctx, agent)
+	if err := s.tmuxClient.KillPaneVerified(ctx, agent.TmuxPane, false); err != nil {
+		return nil, fmt.Errorf("failed to kill pane: %w", err)
+	}
+	s.stopEventWatcher(id)
+	if err := s.paneMap.UnregisterAgent(id); err != nil && !errors.Is(err, ErrAgentNotFound) {
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Msg("failed to unregister pane mapping")
+	}
+
+	now := time.Now().UTC()
+	agent.Metadata.Hibernation = &models.HibernationInfo{
+		Checkpoint:   cp.Name,
+		Pane:         agent.TmuxPane,
+		HibernatedAt: now,
+	}
+	// As for terminated agents, the pane ID is released for reuse.
+	agent.TmuxPane = agent.ID
+	agent.State = models.AgentStateHibernated
+	agent.StateInfo = models.StateInfo{
+		State:      models.AgentStateHibernated,
+		Confidence: models.StateConfidenceHigh,
+		Reason:     "Hibernated: " + reason,
+		DetectedAt: now,
+	}
+	if err := s.repo.Update(ctx, agent); err != nil {
+		return nil, fmt.Errorf("failed to update agent: %w", err)
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("agent_id", id).
+		Str("checkpoint", cp.Name).
+		Str("reason", reason).
+		Msg("agent hibernated")
+
+	s.publishEvent(ctx, models.EventTypeAgentHibernated, id, models.AgentHibernationPayload{
+		Checkpoint: cp.Name,
+		Reason:     reason,
+	})
+	return agent, nil
+}
+
+// checkHibernatable refuses agents that are busy or have work queued.
+func (s *Service) checkHibernatable(ctx context.Context, agent *models.Agent) error {
+	if agent.State != models.AgentStateIdle {
+		return fmt.Errorf("%w: current state is %s", ErrAgentNotIdle, agent.State)
+	}
+	if s.queueRepo == nil {
+		return nil
+	}
+	pending, err := s.queueRepo.Count(ctx, agent.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count queue items: %w", err)
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d queue item(s) pending", ErrAgentNotIdle, pending)
+	}
+	return nil
+}
+
+// WakeAgent brings a hibernated agent back: its checkpointed session files
+// are restored, a new pane spawned in its workspace, and the CLI started
+// on the restored session. It returns once the agent is ready. Reason is
+// recorded on the agent.woken event.
+//
+// An agent that fails to start is left in the error state with its
+// hibernation checkpoint recorded, so it can still be restored by hand.
+func (s *Service) WakeAgent(ctx context.Context, id, reason string) (woken *models.Agent, err error) {
+	defer func() { s.audit(ctx, AuditWakeAgent, id, audit.Params("reason", reason), err) }()
+
+	agent, err := s.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	hibernation := agent.Metadata.Hibernation
+	if agent.State != models.AgentStateHibernated || hibernation == nil {
+		return nil, fmt.Errorf("%w: current state is %s", ErrAgentNotHibernated, agent.State)
+	}
+	if s.checkpoints == nil {
+		return nil, ErrCheckpointsDisabled
+	}
+	cp, err := s.checkpoints.store.Get(hibernation.Checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := s.checkpointWorkspace(ctx, agent.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.checkpoints.store.Restore(cp, sessionVars(agent.Metadata.Environment, ws))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore checkpoint %s: %w", cp.Name, err)
+	}
+	for _, warning := range result.Warnings {
+		s.logger.Warn().Ctx(ctx).Str("checkpoint", cp.Name).Msg(warning)
+	}
+
+	opts := splitEnvironment(agent.Metadata)
+	env, _ := ResolveEnvironment(spawnEnvLayers(opts, s.credentialEnv(ctx, agent.AccountID))...)
+	opts = SpawnOptions{
+		WorkspaceID:    agent.WorkspaceID,
+		Type:           agent.Type,
+		AccountID:      agent.AccountID,
+		Environment:    env,
+		WorkingDir:     ws.RepoPath,
+		ApprovalPolicy: agent.Metadata.ApprovalPolicy,
+		Model:          agent.Metadata.Model,
+		Resume:         true,
+	}
+
+	paneID, err := s.splitAgentPane(ctx, ws, ws.RepoPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to create pane: %v", ErrSpawnFailed, err)
+	}
+
+	now := time.Now().UTC()
+	agent.TmuxPane = paneID
+	agent.TmuxSession = ws.TmuxSession
+	agent.State = models.AgentStateStarting
+	agent.StateInfo = models.StateInfo{
+		State:      models.AgentStateStarting,
+		Confidence: models.StateConfidenceHigh,
+		Reason:     "Waking from hibernation",
+		DetectedAt: now,
+	}
+	agent.LastActivity = &now
+	agent.Metadata.StartCommand = s.buildStartCommand(opts)
+	if err := s.repo.Update(ctx, agent); err != nil {
+		_ = s.tmuxClient.KillPane(ctx, paneID)
+		return nil, fmt.Errorf("failed to update agent for wake: %w", err)
+	}
+	if err := s.paneMap.Register(agent.ID, paneID, paneID); err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to register pane mapping")
+	}
+
+	if startCmd := agent.Metadata.StartCommand; startCmd != "" {
+		if err := s.tmuxClient.SendKeys(ctx, paneID, startCmd, true, true); err != nil {
+			wakeErr := fmt.Errorf("failed to send start command: %w", err)
+			s.markAgentError(ctx, agent, wakeErr.Error(), models.StateConfidenceLow, nil)
+			s.cleanupRestartFailure(ctx, agent)
+			return nil, fmt.Errorf("%w: %v", ErrSpawnFailed, wakeErr)
+		}
+	}
+	if err := s.waitForReady(ctx, agent, opts); err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("agent failed to reach ready state after wake")
+		s.markAgentError(context.WithoutCancel(ctx), agent, err.Error(), models.StateConfidenceLow, nil)
+		s.cleanupRestartFailure(ctx, agent)
+		return nil, fmt.Errorf("%w: %w", ErrSpawnFailed, err)
+	}
+
+	agent.Metadata.Hibernation = nil
+	if err := s.repo.Update(ctx, agent); err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to clear agent hibernation")
+	}
+	s.startEventWatcher(ctx, agent)
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("agent_id", agent.ID).
+		Str("checkpoint", cp.Name).
+		Str("pane", paneID).
+		Str("reason", reason).
+		Msg("agent woken")
+
+	s.publishEvent(ctx, models.EventTypeAgentWoken, agent.ID, models.AgentHibernationPayload{
+		Checkpoint: cp.Name,
+		Reason:     reason,
+	})
+	return agent, nil
+}
+
+// credentialEnv returns the environment variables of an account's
+// credentials, or nil when there are none to inject.
+func (s *Service) credentialEnv(ctx context.Context, accountID string) map[string]string {
+	if accountID == "" || s.accountService == nil {
+		return nil
+	}
+	env, err := s.accountService.GetCredentialEnv(ctx, accountID)
+	if err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).
+			Str("account_id", accountID).
+			Msg("failed to resolve account credentials, continuing without injection")
+		return nil
+	}
+	return env
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/checkpoint"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func TestHibernateAndWakeAgent(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []string
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		gotArgs = term.Args()
+		term.Print("claude> ")
+	}))
+	agent := seedMoveAgent(t, env)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	session := writeClaudeSession(t, home, "/repo", `{"cwd":"/repo"}`)
+
+	if _, err := env.service.HibernateAgent(ctx, agent.ID, "idle for 24h"); !errors.Is(err, ErrCheckpointsDisabled) {
+		t.Fatalf("expected ErrCheckpointsDisabled, got %v", err)
+	}
+	WithCheckpoints(checkpoint.NewStore(t.TempDir()), nil)(env.service)
+
+	var sequence []string
+	publisher := events.NewInMemoryPublisher()
+	if err := publisher.Subscribe("hibernation", events.Filter{}, func(event *models.Event) {
+		sequence = append(sequence, string(event.Type))
+	}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	WithPublisher(publisher)(env.service)
+
+	hibernated, err := env.service.HibernateAgent(ctx, agent.ID, "idle for 24h")
+	if err != nil {
+		t.Fatalf("HibernateAgent failed: %v", err)
+	}
+	hib := hibernated.Metadata.Hibernation
+	if hibernated.State != models.AgentStateHibernated || hib == nil || hib.Pane != agent.TmuxPane || hibernated.TmuxPane != agent.ID {
+		t.Fatalf("unexpected hibernated agent %+v", hibernated)
+	}
+	if exists, _ := env.service.paneExists(ctx, agent.TmuxPane); exists {
+		t.Fatal("expected the agent's pane to be killed")
+	}
+	if again, err := env.service.HibernateAgent(ctx, agent.ID, "again"); err != nil || again.Metadata.Hibernation.Checkpoint != hib.Checkpoint {
+		t.Fatalf("expected hibernating again to do nothing, got %v", err)
+	}
+
+	// The session is brought back from the checkpoint, not left on disk.
+	if err := os.Remove(session); err != nil {
+		t.Fatal(err)
+	}
+	woken, err := env.service.WakeAgent(ctx, agent.ID, WakeReasonManual)
+	if err != nil {
+		t.Fatalf("WakeAgent failed: %v", err)
+	}
+	if woken.ID != agent.ID || woken.State != models.AgentStateIdle || woken.Metadata.Hibernation != nil || woken.TmuxPane == agent.ID {
+		t.Fatalf("unexpected woken agent %+v", woken)
+	}
+	if strings.Join(gotArgs, " ") != "--continue" {
+		t.Fatalf("expected the CLI to resume its session, got %v", gotArgs)
+	}
+	if data, err := os.ReadFile(session); err != nil || string(data) != `{"cwd":"/repo"}` {
+		t.Fatalf("expected the session restored, got %q (%v)", data, err)
+	}
+	stored, err := db.NewAgentRepository(env.database).Get(ctx, agent.ID)
+	if err != nil || stored.State != models.AgentStateIdle || stored.TmuxPane != woken.TmuxPane || stored.Metadata.Hibernation != nil {
+		t.Fatalf("expected the woken agent stored, got %+v (%v)", stored, err)
+	}
+
+	want := []string{"agent.paused", "agent.checkpointed", "agent.resumed", "agent.hibernated", "agent.woken"}
+	if strings.Join(sequence, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected events %v, got %v", want, sequence)
+	}
+	if _, err := env.service.WakeAgent(ctx, agent.ID, WakeReasonManual); !errors.Is(err, ErrAgentNotHibernated) {
+		t.Fatalf("expected ErrAgentNotHibernated, got %v", err)
+	}
+}
+
+func TestHibernateAgentRefusesQueuedWork(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t)
+	agent := seedMoveAgent(t, env)
+	WithCheckpoints(checkpoint.NewStore(t.TempDir()), nil)(env.service)
+
+	item := &models.QueueItem{AgentID: agent.ID, Type: models.QueueItemTypeMessage, Status: models.QueueItemStatusPending, Payload: []byte(`{"text":"next"}`)}
+	if err := db.NewQueueRepository(env.database).Enqueue(ctx, agent.ID, item); err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+	if _, err := env.service.HibernateAgent(ctx, agent.ID, "idle"); !errors.Is(err, ErrAgentNotIdle) || !strings.Contains(err.Error(), "1 queue item(s) pending") {
+		t.Fatalf("expected a pending queue to block hibernation, got %v", err)
+	}
+	stored, _ := db.NewAgentRepository(env.database).Get(ctx, agent.ID)
+	if stored.State != models.AgentStateIdle {
+		t.Fatalf("expected the agent left idle, got %s", stored.State)
+	}
+}
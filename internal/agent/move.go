@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+// ErrCrossNodeMove is returned when the target workspace is on another node.
+var ErrCrossNodeMove = errors.New("cannot move agent to a workspace on another node")
+
+// MoveAgent re-parents an agent into the target workspace. Its pane moves
+// into the target workspace's tmux session with the process still running,
+// and its queue and history stay attached since they are keyed by agent ID.
+// The agent row is updated first and restored if the pane move fails.
+func (s *Service) MoveAgent(ctx context.Context, id, targetWorkspaceID string) (_ *models.Agent, err error) {
+	defer func() { s.audit(ctx, AuditMoveAgent, id, audit.Params("workspace_id", targetWorkspaceID), err) }()
+	agent, err := s.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if agent.WorkspaceID == targetWorkspaceID {
+		return agent, nil
+	}
+	if agent.RemoteAgentID != "" {
+		return nil, fmt.Errorf("moving is %w", ErrRemoteAgent)
+	}
+
+	source, err := s.workspaceService.GetWorkspace(ctx, agent.WorkspaceID)
+	if err != nil {
+		if errors.Is(err, workspace.ErrWorkspaceNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	target, err := s.workspaceService.GetWorkspace(ctx, targetWorkspaceID)
+	if err != nil {
+		if errors.Is(err, workspace.ErrWorkspaceNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if source.NodeID != target.NodeID {
+		return nil, fmt.Errorf("%w: %s is on node %s, agent is on node %s", ErrCrossNodeMove, target.Name, target.NodeID, source.NodeID)
+	}
+	if target.TmuxSession == "" {
+		return nil, fmt.Errorf("target workspace %s has no tmux session", target.Name)
+	}
+
+	if err := s.tmuxClient.EnsureSession(ctx, target.TmuxSession, target.RepoPath); err != nil {
+		return nil, fmt.Errorf("failed to create target session: %w", err)
+	}
+
+	previous := *agent
+	agent.WorkspaceID = target.ID
+	agent.TmuxSession = target.TmuxSession
+	if err := s.repo.Update(ctx, agent); err != nil {
+		return nil, fmt.Errorf("failed to update agent: %w", err)
+	}
+
+	// Prefer the agents window, as spawn does, falling back to the session.
+	moveTarget := fmt.Sprintf("%s:%s", target.TmuxSession, tmux.AgentWindowName)
+	err = s.tmuxClient.MovePane(ctx, agent.TmuxPane, moveTarget)
+	if err != nil && !errors.Is(err, tmux.ErrPaneNotFound) {
+		s.logger.Debug().Ctx(ctx).Err(err).Str("target", moveTarget).Msg("failed to move into agents window, falling back to session")
+		err = s.tmuxClient.MovePane(ctx, agent.TmuxPane, target.TmuxSession)
+	}
+	if err != nil {
+		if rollbackErr := s.repo.Update(ctx, &previous); rollbackErr != nil {
+			s.logger.Error().Ctx(ctx).Err(rollbackErr).Str("agent_id", agent.ID).Msg("failed to roll back agent move")
+		}
+		if errors.Is(err, tmux.ErrPaneNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrPaneNotFound, previous.TmuxPane)
+		}
+		return nil, fmt.Errorf("failed to move agent pane: %w", err)
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("agent_id", agent.ID).
+		Str("from_workspace_id", source.ID).
+		Str("to_workspace_id", target.ID).
+		Str("pane", agent.TmuxPane).
+		Msg("agent moved")
+
+	s.publishEvent(ctx, models.EventTypeAgentMoved, agent.ID, models.AgentMovedPayload{
+		FromWorkspaceID: source.ID,
+		ToWorkspaceID:   target.ID,
+		TmuxPane:        agent.TmuxPane,
+	})
+
+	return agent, nil
+}
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// seedMoveAgent creates an agent row for a fresh pane in the env's workspace.
+func seedMoveAgent(t *testing.T, env *spawnTestEnv) *models.Agent {
+	t.Helper()
+	paneID, err := env.tmux.Run("split-window", "-t", "ws:agents", "-P", "-F", "#{pane_id}")
+	if err != nil {
+		t.Fatalf("failed to split pane: %v", err)
+	}
+	agent := &models.Agent{
+		WorkspaceID: env.workspaceID,
+		Type:        models.AgentTypeClaudeCode,
+		TmuxPane:    strings.TrimSpace(paneID),
+		State:       models.AgentStateIdle,
+		StateInfo:   models.StateInfo{State: models.AgentStateIdle, Confidence: models.StateConfidenceHigh},
+	}
+	if err := db.NewAgentRepository(env.database).Create(context.Background(), agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	return agent
+}
+
+func createMoveWorkspace(t *testing.T, env *spawnTestEnv, nodeID, name string) *models.Workspace {
+	t.Helper()
+	ws := &models.Workspace{NodeID: nodeID, Name: name, RepoPath: "/" + name, TmuxSession: name}
+	if err := db.NewWorkspaceRepository(env.database).Create(context.Background(), ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	return ws
+}
+
+func TestMoveAgent(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t)
+	agent := seedMoveAgent(t, env)
+	item := &models.QueueItem{AgentID: agent.ID, Type: models.QueueItemTypeMessage, Status: models.QueueItemStatusPending, Payload: []byte(`{"text":"keep me"}`)}
+	if err := db.NewQueueRepository(env.database).Enqueue(ctx, agent.ID, item); err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+	if err := env.tmux.Print(agent.TmuxPane, "mid-task output\n"); err != nil {
+		t.Fatalf("failed to print: %v", err)
+	}
+
+	// The target session does not exist yet and is created on demand.
+	target := createMoveWorkspace(t, env, env.nodeID, "promoted")
+	moved, err := env.service.MoveAgent(ctx, agent.ID, target.ID)
+	if err != nil {
+		t.Fatalf("MoveAgent failed: %v", err)
+	}
+	if moved.WorkspaceID != target.ID || moved.TmuxPane != agent.TmuxPane || moved.TmuxSession != "promoted" {
+		t.Fatalf("unexpected moved agent: workspace %s pane %s", moved.WorkspaceID, moved.TmuxPane)
+	}
+
+	out, err := env.tmux.Run("list-panes", "-s", "-t", "promoted", "-F", "#{pane_id}")
+	if err != nil || !strings.Contains(out, agent.TmuxPane) {
+		t.Fatalf("expected pane %s in target session, got %q (err=%v)", agent.TmuxPane, out, err)
+	}
+	if screen, _ := env.tmux.Capture(agent.TmuxPane); !strings.Contains(screen, "mid-task output") {
+		t.Fatalf("expected pane content to survive the move, got %q", screen)
+	}
+
+	stored, err := db.NewAgentRepository(env.database).Get(ctx, agent.ID)
+	if err != nil || stored.WorkspaceID != target.ID {
+		t.Fatalf("expected stored agent in target workspace, got %+v (err=%v)", stored, err)
+	}
+	items, err := db.NewQueueRepository(env.database).List(ctx, agent.ID)
+	if err != nil || len(items) != 1 || items[0].ID != item.ID {
+		t.Fatalf("expected queue to move with the agent, got %+v (err=%v)", items, err)
+	}
+}
+
+func TestMoveAgentRollsBackWhenPaneMoveFails(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t)
+	agent := seedMoveAgent(t, env)
+	target := createMoveWorkspace(t, env, env.nodeID, "other")
+
+	if _, err := env.tmux.Run("kill-pane", "-t", agent.TmuxPane); err != nil {
+		t.Fatalf("failed to kill pane: %v", err)
+	}
+
+	_, err := env.service.MoveAgent(ctx, agent.ID, target.ID)
+	if !errors.Is(err, ErrPaneNotFound) {
+		t.Fatalf("expected ErrPaneNotFound, got %v", err)
+	}
+	stored, err := db.NewAgentRepository(env.database).Get(ctx, agent.ID)
+	if err != nil || stored.WorkspaceID != env.workspaceID {
+		t.Fatalf("expected agent to stay in source workspace, got %+v (err=%v)", stored, err)
+	}
+}
+
+func TestMoveAgentRejectsCrossNode(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t)
+	agent := seedMoveAgent(t, env)
+
+	remote := &models.Node{Name: "remote", SSHTarget: "user@remote", Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(env.database).Create(ctx, remote); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	target := createMoveWorkspace(t, env, remote.ID, "elsewhere")
+
+	_, err := env.service.MoveAgent(ctx, agent.ID, target.ID)
+	if !errors.Is(err, ErrCrossNodeMove) {
+		t.Fatalf("expected ErrCrossNodeMove, got %v", err)
+	}
+	if got := env.panes(t); !strings.Contains(got, agent.TmuxPane) {
+		t.Fatalf("expected pane to stay in source session, got %s", got)
+	}
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// peekPollInterval is how often a local pane is re-captured while a peek
+// waits for it to change. Agents on swarmd nodes long-poll their daemon
+// instead.
+const peekPollInterval = 250 * time.Millisecond
+
+// PeekOptions selects what PeekAgent captures and whether it waits for a
+// change.
+type PeekOptions struct {
+	// Lines is the number of lines to capture; 0 is the visible pane.
+	Lines int
+
+	// WaitChangeFrom, when set to a content hash, holds the peek until
+	// the pane's hash differs from it or MaxWait elapses.
+	WaitChangeFrom string
+
+	// MaxWait bounds the wait for a change.
+	MaxWait time.Duration
+}
+
+// PaneView is a capture of an agent's pane.
+type PaneView struct {
+	AgentID     string `json:"agent_id"`
+	Content     string `json:"content"`
+	ContentHash string `json:"content_hash"`
+
+	// Changed is false when a wait for a change timed out.
+	Changed bool `json:"changed"`
+
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// PeekAgent captures an agent's pane, from local tmux or its swarmd. With
+// opts.WaitChangeFrom it returns once the content hash differs from it, or
+// with Changed false when opts.MaxWait elapses first.
+func (s *Service) PeekAgent(ctx context.Context, id string, opts PeekOptions) (*PaneView, error) {
+	agent, err := s.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Lines < 0 {
+		return nil, fmt.Errorf("lines must not be negative, got %d", opts.Lines)
+	}
+
+	deadline := time.Now().Add(opts.MaxWait)
+	for {
+		var content string
+		if agent.RemoteAgentID == "" {
+			content, err = s.captureLocalLines(ctx, agent.TmuxPane, opts.Lines)
+		} else {
+			content, err = s.captureRemoteLines(ctx, agent, opts, time.Until(deadline))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture pane: %w", err)
+		}
+
+		view := &PaneView{
+			AgentID:     agent.ID,
+			Content:     content,
+			ContentHash: tmux.HashSnapshot(content),
+			CapturedAt:  time.Now().UTC(),
+		}
+		view.Changed = view.ContentHash != opts.WaitChangeFrom
+		if opts.WaitChangeFrom == "" || view.Changed || !time.Now().Before(deadline) {
+			return view, nil
+		}
+
+		if agent.RemoteAgentID == "" {
+			timer := time.NewTimer(min(peekPollInterval, time.Until(deadline)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+func (s *Service) captureLocalLines(ctx context.Context, pane string, lines int) (string, error) {
+	if lines > 0 {
+		content, _, err := s.tmuxClient.CapturePaneRange(ctx, pane, lines)
+		return content, err
+	}
+	return s.tmuxClient.CapturePane(ctx, pane, false)
+}
+
+// captureRemoteLines captures through the agent's swarmd, long-polling for
+// up to wait when opts asks for a change. The daemon caps each poll, so the
+// caller polls again until its own deadline.
+func (s *Service) captureRemoteLines(ctx context.Context, agent *models.Agent, opts PeekOptions, wait time.Duration) (string, error) {
+	client, err := s.daemonForAgent(ctx, agent)
+	if err != nil {
+		return "", err
+	}
+	req := &swarmdv1.CapturePaneRequest{AgentId: agent.RemoteAgentID, Lines: int32(opts.Lines)}
+	if opts.WaitChangeFrom != "" && wait > 0 {
+		req.WaitForChangeFrom = opts.WaitChangeFrom
+		req.MaxWait = durationpb.New(wait)
+	}
+	resp, err := client.CapturePane(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.GetContent(), nil
+}
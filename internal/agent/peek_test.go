@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func TestPeekAgentWaitsForChange(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	ctx := context.Background()
+	spawned, err := env.service.SpawnAgent(ctx, SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+
+	current, err := env.service.PeekAgent(ctx, spawned.ID, PeekOptions{})
+	if err != nil || !strings.Contains(current.Content, "claude>") || current.ContentHash == "" {
+		t.Fatalf("PeekAgent() = %+v, %v", current, err)
+	}
+
+	stale, err := env.service.PeekAgent(ctx, spawned.ID, PeekOptions{WaitChangeFrom: "stale-hash", MaxWait: time.Minute})
+	if err != nil || !stale.Changed || stale.ContentHash != current.ContentHash {
+		t.Fatalf("expected a stale hash to return at once, got %+v, %v", stale, err)
+	}
+
+	unchanged, err := env.service.PeekAgent(ctx, spawned.ID, PeekOptions{WaitChangeFrom: current.ContentHash, MaxWait: 20 * time.Millisecond})
+	if err != nil || unchanged.Changed {
+		t.Fatalf("expected the wait to time out unchanged, got %+v, %v", unchanged, err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = env.tmux.Print(spawned.TmuxPane, "\nworking")
+	}()
+	changed, err := env.service.PeekAgent(ctx, spawned.ID, PeekOptions{WaitChangeFrom: current.ContentHash, MaxWait: 5 * time.Second})
+	if err != nil || !changed.Changed || !strings.Contains(changed.Content, "working") {
+		t.Fatalf("expected the change made during the wait, got %+v, %v", changed, err)
+	}
+}
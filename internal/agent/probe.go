@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+// ErrAdapterUnavailable is returned when an agent CLI is missing or does not
+// respond to --version on the workspace's node.
+var ErrAdapterUnavailable = errors.New("agent CLI unavailable")
+
+const (
+	// DefaultProbeTimeout bounds a single --version probe.
+	DefaultProbeTimeout = 5 * time.Second
+
+	// DefaultProbeTTL is how long a successful probe is reused.
+	DefaultProbeTTL = 5 * time.Minute
+)
+
+// ParseCLIVersion extracts the version from `<cli> --version` output, such
+// as "1.0.17 (Claude Code)", "codex-cli 0.20.0", or "opencode v0.3.58".
+// Output without a recognizable version yields its first line.
+func ParseCLIVersion(output string) string {
+	return adapters.Capabilities{}.ParseVersion(output)
+}
+
+// SpawnProbe checks that an agent CLI is installed before a pane is created
+// for it. Successful results are cached per node and agent type.
+type SpawnProbe struct {
+	exec    tmux.Executor
+	timeout time.Duration
+	ttl     time.Duration
+	clock   clock.Clock
+
+	mu    sync.Mutex
+	cache map[string]probeResult
+}
+
+type probeResult struct {
+	version  string
+	probedAt time.Time
+}
+
+// SpawnProbeOption configures a SpawnProbe.
+type SpawnProbeOption func(*SpawnProbe)
+
+// WithProbeTimeout sets the timeout for each --version call.
+func WithProbeTimeout(timeout time.Duration) SpawnProbeOption {
+	return func(p *SpawnProbe) {
+		p.timeout = timeout
+	}
+}
+
+// WithProbeTTL sets how long successful probes are cached.
+func WithProbeTTL(ttl time.Duration) SpawnProbeOption {
+	return func(p *SpawnProbe) {
+		p.ttl = ttl
+	}
+}
+
+// WithProbeClock sets the clock used for cache expiry.
+func WithProbeClock(c clock.Clock) SpawnProbeOption {
+	return func(p *SpawnProbe) {
+		p.clock = c
+	}
+}
+
+// NewSpawnProbe creates a probe that runs commands through exec, which
+// should target the same node as the agent panes.
+func NewSpawnProbe(exec tmux.Executor, opts ...SpawnProbeOption) *SpawnProbe {
+	p := &SpawnProbe{
+		exec:    exec,
+		timeout: DefaultProbeTimeout,
+		ttl:     DefaultProbeTTL,
+		cache:   make(map[string]probeResult),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.clock = clock.OrReal(p.clock)
+	return p
+}
+
+// Probe returns the CLI version for agentType on nodeID. It returns an empty
+// version for agent types whose adapter declares no binary, such as generic
+// agents running arbitrary commands, and ErrAdapterUnavailable with the
+// adapter's install hint when the CLI cannot be run.
+func (p *SpawnProbe) Probe(ctx context.Context, nodeID string, agentType models.AgentType) (string, error) {
+	caps := adapters.CapabilitiesFor(agentType)
+	command := caps.VersionCommand()
+	if command == "" {
+		return "", nil
+	}
+
+	key := nodeID + "|" + string(agentType)
+	now := p.clock.Now()
+
+	p.mu.Lock()
+	cached, hit := p.cache[key]
+	p.mu.Unlock()
+	if hit && now.Sub(cached.probedAt) < p.ttl {
+		return cached.version, nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	stdout, stderr, err := p.exec.Exec(probeCtx, command)
+	if err != nil {
+		p.Invalidate(nodeID, agentType)
+		detail := lastNonEmptyLine(string(stderr))
+		if detail == "" {
+			detail = err.Error()
+		}
+		if caps.InstallHint == "" {
+			return "", fmt.Errorf("%w: %s failed (%s)", ErrAdapterUnavailable, command, detail)
+		}
+		return "", fmt.Errorf("%w: %s failed (%s); install with: %s", ErrAdapterUnavailable, command, detail, caps.InstallHint)
+	}
+
+	output := string(stdout)
+	if strings.TrimSpace(output) == "" {
+		output = string(stderr)
+	}
+	version := caps.ParseVersion(output)
+
+	p.mu.Lock()
+	p.cache[key] = probeResult{version: version, probedAt: now}
+	p.mu.Unlock()
+	return version, nil
+}
+
+// Invalidate drops the cached probe for agentType on nodeID.
+func (p *SpawnProbe) Invalidate(nodeID string, agentType models.AgentType) {
+	p.mu.Lock()
+	delete(p.cache, nodeID+"|"+string(agentType))
+	p.mu.Unlock()
+}
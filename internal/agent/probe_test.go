@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+// probeExec answers --version commands from a table and counts calls.
+type probeExec struct {
+	outputs map[string]string
+	calls   int
+}
+
+func (e *probeExec) Exec(ctx context.Context, cmd string) ([]byte, []byte, error) {
+	e.calls++
+	binary := strings.TrimSuffix(cmd, " --version")
+	if out, ok := e.outputs[binary]; ok {
+		return []byte(out), nil, nil
+	}
+	return nil, []byte("sh: 1: " + binary + ": not found\n"), errors.New("exit status 127")
+}
+
+func TestParseCLIVersion(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"claude", "1.0.17 (Claude Code)\n", "1.0.17"},
+		{"opencode", "0.3.58\n", "0.3.58"},
+		{"opencode prefixed", "opencode v0.4.1\n", "0.4.1"},
+		{"codex", "codex-cli 0.20.0\n", "0.20.0"},
+		{"gemini prerelease", "0.1.13-nightly.20250801\n", "0.1.13-nightly.20250801"},
+		{"unrecognized", "development build\nmore", "development build"},
+	}
+	for _, tc := range cases {
+		if got := ParseCLIVersion(tc.output); got != tc.want {
+			t.Errorf("%s: ParseCLIVersion(%q) = %q, want %q", tc.name, tc.output, got, tc.want)
+		}
+	}
+}
+
+func TestSpawnProbeMissingBinary(t *testing.T) {
+	probe := NewSpawnProbe(&probeExec{})
+
+	_, err := probe.Probe(context.Background(), "node-1", models.AgentTypeCodex)
+	if !errors.Is(err, ErrAdapterUnavailable) {
+		t.Fatalf("expected ErrAdapterUnavailable, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "codex: not found") || !strings.Contains(err.Error(), "npm install -g @openai/codex") {
+		t.Fatalf("expected stderr and install hint in error, got %v", err)
+	}
+
+	version, err := probe.Probe(context.Background(), "node-1", models.AgentTypeGeneric)
+	if err != nil || version != "" {
+		t.Fatalf("expected generic agents to skip the probe, got %q, %v", version, err)
+	}
+}
+
+func TestSpawnProbeCachesPerNodeAndType(t *testing.T) {
+	exec := &probeExec{outputs: map[string]string{"claude": "1.0.17 (Claude Code)"}}
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	probe := NewSpawnProbe(exec, WithProbeClock(fake), WithProbeTTL(time.Minute))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		version, err := probe.Probe(ctx, "node-1", models.AgentTypeClaudeCode)
+		if err != nil || version != "1.0.17" {
+			t.Fatalf("probe %d: got %q, %v", i, version, err)
+		}
+	}
+	if exec.calls != 1 {
+		t.Fatalf("expected cache hits after the first probe, got %d execs", exec.calls)
+	}
+
+	if _, err := probe.Probe(ctx, "node-2", models.AgentTypeClaudeCode); err != nil {
+		t.Fatalf("probe on second node: %v", err)
+	}
+	if exec.calls != 2 {
+		t.Fatalf("expected a separate probe per node, got %d execs", exec.calls)
+	}
+
+	fake.Advance(time.Minute)
+	if _, err := probe.Probe(ctx, "node-1", models.AgentTypeClaudeCode); err != nil {
+		t.Fatalf("probe after expiry: %v", err)
+	}
+	if exec.calls != 3 {
+		t.Fatalf("expected expired entry to be re-probed, got %d execs", exec.calls)
+	}
+
+	delete(exec.outputs, "claude")
+	probe.Invalidate("node-1", models.AgentTypeClaudeCode)
+	if _, err := probe.Probe(ctx, "node-1", models.AgentTypeClaudeCode); !errors.Is(err, ErrAdapterUnavailable) {
+		t.Fatalf("expected invalidated entry to be re-probed and fail, got %v", err)
+	}
+}
+
+func TestSpawnAgentProbesCLI(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+
+	env.service.probe = NewSpawnProbe(&probeExec{})
+	_, err := env.service.SpawnAgent(context.Background(), SpawnOptions{
+		WorkspaceID: env.workspaceID,
+		Type:        models.AgentTypeClaudeCode,
+	})
+	if !errors.Is(err, ErrAdapterUnavailable) {
+		t.Fatalf("expected missing CLI to fail the spawn, got %v", err)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected no pane to be created, got %s", got)
+	}
+
+	env.service.probe = NewSpawnProbe(&probeExec{outputs: map[string]string{"claude": "1.0.17 (Claude Code)"}})
+	agent, err := env.service.SpawnAgent(context.Background(), SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	if agent.Metadata.CLIVersion != "1.0.17" {
+		t.Fatalf("expected CLI version in metadata, got %q", agent.Metadata.CLIVersion)
+	}
+}
@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/fsperm"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/recording"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+// Recording errors.
+var (
+	ErrRecordingDisabled = errors.New("recording is not configured")
+	ErrAlreadyRecording  = errors.New("agent is already being recorded")
+	ErrNotRecording      = errors.New("agent is not being recorded")
+	ErrRecordingNotFound = errors.New("no recording found for agent")
+)
+
+// Terminal size used when the pane size cannot be read.
+const (
+	defaultRecordingWidth  = 200
+	defaultRecordingHeight = 50
+)
+
+// RecordingSink returns the shell command that tmux pipes an agent's pane
+// output into. The command must write a cast file to castPath.
+type RecordingSink func(castPath string, width, height int) string
+
+// RecordingPath returns the cast file used for an agent's recording.
+func (s *Service) RecordingPath(agentID string) string {
+	if s == nil || s.recordingDir == "" {
+		return ""
+	}
+	return filepath.Join(s.recordingDir, agentID+".cast")
+}
+
+// FindRecording returns the cast file for an agent, or ErrRecordingNotFound.
+// Recordings outlive the agent, so the agent record is not required.
+func (s *Service) FindRecording(agentID string) (string, error) {
+	path := s.RecordingPath(agentID)
+	if path == "" {
+		return "", ErrRecordingDisabled
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrRecordingNotFound
+		}
+		return "", err
+	}
+	return path, nil
+}
+
+// StartRecording pipes the agent's pane into a new cast file, replacing any
+// previous recording for the agent.
+func (s *Service) StartRecording(ctx context.Context, id string) (*models.RecordingInfo, error) {
+	agent, err := s.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if agent.Metadata.Recording != nil {
+		return nil, ErrAlreadyRecording
+	}
+	if err := s.startRecording(ctx, agent); err != nil {
+		return nil, err
+	}
+	return agent.Metadata.Recording, nil
+}
+
+// StopRecording stops piping the agent's pane. The cast file is kept.
+func (s *Service) StopRecording(ctx context.Context, id string) (*models.RecordingInfo, error) {
+	agent, err := s.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	info := agent.Metadata.Recording
+	if info == nil {
+		return nil, ErrNotRecording
+	}
+
+	// A vanished pane has already closed its pipe.
+	if err := s.stopPipe(ctx, agent); err != nil && !errors.Is(err, tmux.ErrPaneNotFound) {
+		return nil, err
+	}
+	agent.Metadata.Recording = nil
+	if err := s.repo.Update(ctx, agent); err != nil {
+		return nil, fmt.Errorf("failed to update agent: %w", err)
+	}
+
+	s.logger.Info().Ctx(ctx).Str("agent_id", id).Str("path", info.Path).Msg("recording stopped")
+	return info, nil
+}
+
+func (s *Service) startRecording(ctx context.Context, agent *models.Agent) error {
+	if s.recordingDir == "" || s.recordingSink == nil {
+		return ErrRecordingDisabled
+	}
+	if s.tmuxClient == nil {
+		return fmt.Errorf("tmux client not configured")
+	}
+	if strings.TrimSpace(agent.TmuxPane) == "" {
+		return fmt.Errorf("agent %s has no tmux pane", agent.ID)
+	}
+	if err := fsperm.MkdirAll(s.recordingDir); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	width, height, err := s.tmuxClient.PaneSize(ctx, agent.TmuxPane)
+	if err != nil || width <= 0 || height <= 0 {
+		s.logger.Debug().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("pane size unavailable, using default")
+		width, height = defaultRecordingWidth, defaultRecordingHeight
+	}
+
+	path := s.RecordingPath(agent.ID)
+	for n := 1; n <= recording.DefaultMaxSegments; n++ {
+		_ = os.Remove(recording.SegmentPath(path, n))
+	}
+	if err := s.tmuxClient.PipePane(ctx, agent.TmuxPane, s.recordingSink(path, width, height)); err != nil {
+		return fmt.Errorf("failed to pipe pane: %w", err)
+	}
+
+	agent.Metadata.Recording = &models.RecordingInfo{Path: path, StartedAt: time.Now().UTC()}
+	if err := s.repo.Update(ctx, agent); err != nil {
+		_ = s.tmuxClient.PipePane(ctx, agent.TmuxPane, "")
+		agent.Metadata.Recording = nil
+		return fmt.Errorf("failed to update agent: %w", err)
+	}
+
+	s.logger.Info().Ctx(ctx).Str("agent_id", agent.ID).Str("path", path).Msg("recording started")
+	return nil
+}
+
+// stopPipe closes the pane pipe of a recorded agent so the sink flushes and
+// exits. It is a no-op for agents that are not being recorded.
+func (s *Service) stopPipe(ctx context.Context, agent *models.Agent) error {
+	if agent.Metadata.Recording == nil || s.tmuxClient == nil || agent.TmuxPane == "" {
+		return nil
+	}
+	if err := s.tmuxClient.PipePane(ctx, agent.TmuxPane, ""); err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to stop pane pipe")
+		return fmt.Errorf("failed to stop pane pipe: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func TestRecordingLifecycle(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	dir := t.TempDir()
+	WithRecording(dir, func(path string, width, height int) string {
+		return fmt.Sprintf("sink %s %dx%d", path, width, height)
+	})(env.service)
+
+	agent, err := env.service.SpawnAgent(ctx, SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		Record:            true,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+
+	castPath := filepath.Join(dir, agent.ID+".cast")
+	if agent.Metadata.Recording == nil || agent.Metadata.Recording.Path != castPath {
+		t.Fatalf("expected recording to %s, got %+v", castPath, agent.Metadata.Recording)
+	}
+	pipe, err := env.tmux.PipeCommand(agent.TmuxPane)
+	if err != nil {
+		t.Fatalf("PipeCommand failed: %v", err)
+	}
+	if !strings.HasPrefix(pipe, "sink "+castPath+" ") {
+		t.Fatalf("expected pane piped into sink, got %q", pipe)
+	}
+
+	if _, err := env.service.StartRecording(ctx, agent.ID); !errors.Is(err, ErrAlreadyRecording) {
+		t.Fatalf("expected ErrAlreadyRecording, got %v", err)
+	}
+
+	if _, err := env.service.StopRecording(ctx, agent.ID); err != nil {
+		t.Fatalf("StopRecording failed: %v", err)
+	}
+	if pipe, _ := env.tmux.PipeCommand(agent.TmuxPane); pipe != "" {
+		t.Fatalf("expected pipe to be closed, got %q", pipe)
+	}
+	stored, err := env.service.GetAgent(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("GetAgent failed: %v", err)
+	}
+	if stored.Metadata.Recording != nil {
+		t.Fatalf("expected recording metadata to be cleared, got %+v", stored.Metadata.Recording)
+	}
+	if _, err := env.service.StopRecording(ctx, agent.ID); !errors.Is(err, ErrNotRecording) {
+		t.Fatalf("expected ErrNotRecording, got %v", err)
+	}
+
+	// Terminating a recorded agent closes the pipe before the pane goes.
+	if _, err := env.service.StartRecording(ctx, agent.ID); err != nil {
+		t.Fatalf("StartRecording failed: %v", err)
+	}
+	env.tmux.ResetCommands()
+	if _, err := env.service.TerminateAgent(ctx, agent.ID, TerminateOptions{}); err != nil {
+		t.Fatalf("TerminateAgent failed: %v", err)
+	}
+	var sequence []string
+	for _, cmd := range env.tmux.Commands() {
+		if strings.Contains(cmd, "pipe-pane") || strings.Contains(cmd, "kill-pane") {
+			sequence = append(sequence, cmd)
+		}
+	}
+	want := []string{
+		fmt.Sprintf("tmux pipe-pane -t '%s'", agent.TmuxPane),
+		fmt.Sprintf("tmux kill-pane -t '%s'", agent.TmuxPane),
+	}
+	if strings.Join(sequence, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected pipe closed before kill, got %q", sequence)
+	}
+}
+
+func TestStartRecordingRequiresConfiguration(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	agent, err := env.service.SpawnAgent(context.Background(), SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+
+	if _, err := env.service.StartRecording(context.Background(), agent.ID); !errors.Is(err, ErrRecordingDisabled) {
+		t.Fatalf("expected ErrRecordingDisabled, got %v", err)
+	}
+	if _, err := env.service.FindRecording(agent.ID); !errors.Is(err, ErrRecordingDisabled) {
+		t.Fatalf("expected ErrRecordingDisabled, got %v", err)
+	}
+}
@@ -0,0 +1,337 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+var (
+	// ErrRunTimeout is returned when a one-shot run does not finish in time.
+	ErrRunTimeout = errors.New("run timed out")
+
+	// ErrRunFailed is returned when a one-shot run's agent fails or exits
+	// before answering.
+	ErrRunFailed = errors.New("run failed")
+)
+
+const (
+	defaultRunTimeout      = 15 * time.Minute
+	defaultRunPollInterval = 500 * time.Millisecond
+	defaultRunSettle       = 2 * time.Second
+	runCleanupTimeout      = 30 * time.Second
+
+	// runTailLines is how much of the bottom of the pane decides the
+	// agent's state; the response above it may mention anything.
+	runTailLines = 3
+)
+
+// RunOptions configures a one-shot run.
+type RunOptions struct {
+	// Spawn configures the ephemeral agent. Ephemeral and InitialPrompt
+	// are set by Run.
+	Spawn SpawnOptions
+
+	// Prompt is sent once the agent is ready.
+	Prompt string
+
+	// Timeout bounds the whole run, spawn included. If zero, defaults to
+	// 15 minutes.
+	Timeout time.Duration
+
+	// PollInterval controls how often the pane is captured.
+	// If zero, defaults to 500 milliseconds.
+	PollInterval time.Duration
+
+	// Settle is how long the pane must stay unchanged at the agent's
+	// prompt before the response is taken as complete.
+	// If zero, defaults to 2 seconds.
+	Settle time.Duration
+}
+
+// RunResult is the outcome of a one-shot run.
+type RunResult struct {
+	AgentID     string `json:"agent_id"`
+	WorkspaceID string `json:"workspace_id"`
+	// Response is the agent's answer. On timeout it holds whatever had
+	// been printed so far.
+	Response   string    `json:"response"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Run spawns an ephemeral agent, sends it a prompt, waits for it to return
+// to its prompt and returns its response. The agent is terminated however
+// the run ends, including when ctx is cancelled.
+//
+// A run that does not finish within the timeout ends with ErrRunTimeout,
+// one whose agent errors or exits with ErrRunFailed, and a cancelled run
+// with ctx.Err().
+func (s *Service) Run(ctx context.Context, opts RunOptions) (*RunResult, error) {
+	if strings.TrimSpace(opts.Prompt) == "" {
+		return nil, fmt.Errorf("prompt is required")
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultRunTimeout
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultRunPollInterval
+	}
+	settle := opts.Settle
+	if settle <= 0 {
+		settle = defaultRunSettle
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	spawn := opts.Spawn
+	spawn.Ephemeral = true
+	spawn.InitialPrompt = ""
+	startedAt := time.Now().UTC()
+	agent, err := s.SpawnAgent(runCtx, spawn)
+	if err != nil {
+		return nil, runError(ctx, runCtx, fmt.Errorf("failed to spawn agent: %w", err))
+	}
+	defer s.terminateEphemeral(ctx, agent.ID)
+
+	result := &RunResult{
+		AgentID:     agent.ID,
+		WorkspaceID: agent.WorkspaceID,
+		StartedAt:   startedAt,
+	}
+	finish := func(response string) {
+		result.FinishedAt = time.Now().UTC()
+		result.Response = response
+	}
+
+	before, err := s.capturePane(runCtx, agent, true)
+	if err != nil {
+		finish("")
+		return result, runError(ctx, runCtx, fmt.Errorf("failed to capture pane: %w", err))
+	}
+	if err := s.SendMessage(runCtx, agent.ID, opts.Prompt, &SendMessageOptions{SkipIdleCheck: true}); err != nil {
+		finish("")
+		return result, runError(ctx, runCtx, fmt.Errorf("failed to send prompt: %w", err))
+	}
+
+	adapter := adapters.GetByAgentType(agent.Type)
+	if adapter == nil {
+		adapter = adapters.GenericFallbackAdapter()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last string
+	var changedAt time.Time
+	sawWorking := false
+	for {
+		select {
+		case <-runCtx.Done():
+			finish(ExtractResponse(before, last, opts.Prompt))
+			return result, runError(ctx, runCtx, runCtx.Err())
+		case <-ticker.C:
+		}
+
+		output, err := s.capturePane(runCtx, agent, true)
+		if err != nil {
+			if agent.RemoteAgentID == "" {
+				if exists, checkErr := s.paneExists(runCtx, agent.TmuxPane); checkErr == nil && !exists {
+					finish(ExtractResponse(before, last, opts.Prompt))
+					return result, fmt.Errorf("%w: agent pane %s exited before answering", ErrRunFailed, agent.TmuxPane)
+				}
+			}
+			s.logger.Debug().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to capture pane during run")
+			continue
+		}
+		now := time.Now()
+		if output != last {
+			last = output
+			changedAt = now
+		}
+
+		state, reason, err := detectRunState(adapter, output, agent.Metadata)
+		if err != nil {
+			continue
+		}
+		if state == models.AgentStateWorking {
+			sawWorking = true
+			continue
+		}
+
+		// Until the agent has visibly picked the prompt up, the pane still
+		// shows the prompt it was typed at.
+		response := ExtractResponse(before, output, opts.Prompt)
+		if output == before || (!sawWorking && response == "") || now.Sub(changedAt) < settle {
+			continue
+		}
+
+		switch state {
+		case models.AgentStateIdle:
+			finish(response)
+			return result, nil
+		case models.AgentStateError:
+			finish(response)
+			return result, fmt.Errorf("%w: %s", ErrRunFailed, reason.Reason)
+		}
+	}
+}
+
+// detectRunState reads the agent's state from the bottom of the pane. An
+// agent back at its prompt is idle whatever the response above it says.
+func detectRunState(adapter adapters.AgentAdapter, output string, meta models.AgentMetadata) (models.AgentState, adapters.StateReason, error) {
+	if line := lastNonEmptyLine(output); line != "" {
+		if state, reason, err := adapter.DetectState(line, meta); err == nil && state == models.AgentStateIdle {
+			return state, reason, nil
+		}
+	}
+	tail, _ := tmux.LastLines(output, runTailLines)
+	return adapter.DetectState(tail, meta)
+}
+
+// runError classifies an error ending a run: the run's own deadline is a
+// timeout, the caller's cancellation is passed through, and anything else
+// is a failure.
+func runError(ctx, runCtx context.Context, err error) error {
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w: %v", ErrRunTimeout, err)
+	default:
+		return fmt.Errorf("%w: %v", ErrRunFailed, err)
+	}
+}
+
+// terminateEphemeral tears down a run's agent, even once ctx is cancelled.
+func (s *Service) terminateEphemeral(ctx context.Context, id string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), runCleanupTimeout)
+	defer cancel()
+	if _, err := s.TerminateAgent(ctx, id, TerminateOptions{}); err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Msg("failed to terminate ephemeral agent")
+	}
+}
+
+var runBarePrompts = map[string]bool{
+	">": true, "❯": true, "›": true, "claude>": true, "$": true,
+}
+
+var runResponseMarkers = []string{"⏺ ", "● "}
+
+// ExtractResponse returns the agent's answer to prompt from pane captures
+// taken before the prompt was sent and after the agent finished. It drops
+// the echoed prompt, the agent's status lines and the input box and hints
+// it redraws below the answer.
+func ExtractResponse(before, after, prompt string) string {
+	lines := strings.Split(strings.ReplaceAll(after, "\r\n", "\n"), "\n")
+
+	// Skip what was already on screen. If the history scrolled, nothing
+	// lines up and the whole capture is searched for the prompt instead.
+	beforeLines := strings.Split(strings.ReplaceAll(before, "\r\n", "\n"), "\n")
+	common := 0
+	for common < len(lines) && common < len(beforeLines) && lines[common] == beforeLines[common] {
+		common++
+	}
+	lines = lines[common:]
+
+	if echo := lastNonEmptyLine(prompt); echo != "" {
+		for i, line := range lines {
+			if strings.Contains(line, echo) {
+				lines = lines[i+1:]
+				break
+			}
+		}
+	}
+
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if !isRunStatusLine(line) {
+			kept = append(kept, strings.TrimRight(line, " \t"))
+		}
+	}
+	for len(kept) > 0 && isRunChromeLine(kept[len(kept)-1]) {
+		kept = kept[:len(kept)-1]
+	}
+	for len(kept) > 0 && strings.TrimSpace(kept[0]) == "" {
+		kept = kept[1:]
+	}
+
+	for i, line := range kept {
+		trimmed := strings.TrimLeft(line, " ")
+		for _, marker := range runResponseMarkers {
+			if strings.HasPrefix(trimmed, marker) {
+				indent := len(line) - len(trimmed)
+				kept[i] = strings.Repeat(" ", indent+2) + strings.TrimPrefix(trimmed, marker)
+				break
+			}
+		}
+	}
+	return strings.TrimSpace(dedent(kept))
+}
+
+// isRunStatusLine reports lines an agent shows while it works, such as
+// "⠙ Thinking… (esc to interrupt)".
+func isRunStatusLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+	if strings.Contains(strings.ToLower(trimmed), "esc to interrupt") {
+		return true
+	}
+	first := []rune(trimmed)[0]
+	return (first >= '⠀' && first <= '⣿') || strings.ContainsRune("✻✽✶✳✢", first)
+}
+
+// isRunChromeLine reports lines an agent redraws below its answer: blank
+// lines, input box borders, a bare prompt and key hints.
+func isRunChromeLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || runBarePrompts[trimmed] {
+		return true
+	}
+	if strings.Contains(trimmed, "for shortcuts") {
+		return true
+	}
+	inner := strings.TrimSpace(strings.Trim(trimmed, "│|"))
+	if inner != trimmed && (inner == "" || runBarePrompts[inner]) {
+		return true
+	}
+	return strings.IndexFunc(trimmed, func(r rune) bool {
+		return !strings.ContainsRune("─━═╭╮╰╯┌┐└┘├┤│ ", r)
+	}) < 0
+}
+
+// dedent joins lines after removing the indentation they all share.
+func dedent(lines []string) string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i, line := range lines {
+			if len(line) >= indent {
+				lines[i] = line[indent:]
+			} else {
+				lines[i] = strings.TrimLeftFunc(line, unicode.IsSpace)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,277 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func testRunOptions(env *spawnTestEnv, prompt string) RunOptions {
+	return RunOptions{
+		Spawn: SpawnOptions{
+			WorkspaceID:       env.workspaceID,
+			Type:              models.AgentTypeClaudeCode,
+			ReadyTimeout:      2 * time.Second,
+			ReadyPollInterval: 5 * time.Millisecond,
+		},
+		Prompt:       prompt,
+		Timeout:      5 * time.Second,
+		PollInterval: 5 * time.Millisecond,
+		Settle:       40 * time.Millisecond,
+	}
+}
+
+// claudeProgram answers every prompt with reply after a spell of thinking.
+func claudeProgram(reply string) tmuxtest.Program {
+	return func(term *tmuxtest.Terminal) {
+		term.Print("Welcome to Claude Code\n\nclaude> ")
+		term.OnInput(func(line string) {
+			term.Print("⠙ Thinking… (esc to interrupt)\n")
+			term.PrintAfter(30*time.Millisecond, reply+"\n\nclaude> ")
+		})
+	}
+}
+
+func TestRunReturnsResponse(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", claudeProgram("⏺ The build fails because go.sum is stale.\n  Run go mod tidy to fix it.")))
+
+	result, err := env.service.Run(context.Background(), testRunOptions(env, "Why does the build fail?"))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := "The build fails because go.sum is stale.\nRun go mod tidy to fix it."
+	if result.Response != want {
+		t.Fatalf("expected response %q, got %q", want, result.Response)
+	}
+	if result.FinishedAt.Before(result.StartedAt) {
+		t.Fatalf("expected the run timed, got %+v", result)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected the agent pane killed, got %s", got)
+	}
+	if _, err := env.service.GetAgent(context.Background(), result.AgentID); !errors.Is(err, ErrServiceAgentNotFound) {
+		t.Fatalf("expected the agent terminated, got %v", err)
+	}
+}
+
+func TestRunTimesOut(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+		term.OnInput(func(line string) {
+			term.Print("⏺ Looking into it\n⠙ Thinking… (esc to interrupt)\n")
+		})
+	}))
+
+	opts := testRunOptions(env, "Refactor everything")
+	opts.Timeout = 200 * time.Millisecond
+	result, err := env.service.Run(context.Background(), opts)
+	if !errors.Is(err, ErrRunTimeout) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if result == nil || result.Response != "Looking into it" {
+		t.Fatalf("expected the partial response, got %+v", result)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected the agent pane killed on timeout, got %s", got)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+		term.OnInput(func(line string) {
+			term.Print("⠙ Thinking… (esc to interrupt)\n")
+		})
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(150*time.Millisecond, cancel)
+	_, err := env.service.Run(ctx, testRunOptions(env, "Refactor everything"))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected the agent pane killed on cancel, got %s", got)
+	}
+}
+
+func TestRunCancelledDuringSpawn(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", tmuxtest.Script(tmuxtest.Step{Output: "Loading..."})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := env.service.Run(ctx, testRunOptions(env, "Hello")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected the half-spawned pane killed, got %s", got)
+	}
+}
+
+func TestRunFailsWhenAgentErrors(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+		term.OnInput(func(line string) {
+			term.Print("⠙ Thinking… (esc to interrupt)\n")
+			term.PrintAfter(20*time.Millisecond, "Error: invalid API key\n")
+		})
+	}))
+
+	result, err := env.service.Run(context.Background(), testRunOptions(env, "Hello"))
+	if !errors.Is(err, ErrRunFailed) {
+		t.Fatalf("expected a failure, got %v", err)
+	}
+	if result.Response != "Error: invalid API key" {
+		t.Fatalf("expected the error captured, got %q", result.Response)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected the agent pane killed on failure, got %s", got)
+	}
+}
+
+func TestRunFailsWhenAgentExits(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+		term.OnInput(func(line string) {
+			term.Print("⠙ Thinking… (esc to interrupt)\n")
+		})
+	}))
+
+	time.AfterFunc(150*time.Millisecond, func() { _, _ = env.tmux.Run("kill-pane", "-t", "%2") })
+	result, err := env.service.Run(context.Background(), testRunOptions(env, "Hello"))
+	if !errors.Is(err, ErrRunFailed) {
+		t.Fatalf("expected a failure once the pane is gone, got %v", err)
+	}
+	if _, err := env.service.GetAgent(context.Background(), result.AgentID); !errors.Is(err, ErrServiceAgentNotFound) {
+		t.Fatalf("expected the agent terminated, got %v", err)
+	}
+}
+
+func TestEphemeralAgentsAreHiddenFromLists(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", claudeProgram("ok")))
+	ctx := context.Background()
+
+	opts := testRunOptions(env, "").Spawn
+	opts.Ephemeral = true
+	agent, err := env.service.SpawnAgent(ctx, opts)
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	if !agent.Metadata.Ephemeral {
+		t.Fatal("expected the agent marked ephemeral")
+	}
+
+	listed, err := env.service.ListAgents(ctx, ListAgentsOptions{WorkspaceID: env.workspaceID})
+	if err != nil {
+		t.Fatalf("ListAgents failed: %v", err)
+	}
+	if len(listed) != 0 {
+		t.Fatalf("expected ephemeral agents hidden, got %d", len(listed))
+	}
+	listed, err = env.service.ListAgents(ctx, ListAgentsOptions{WorkspaceID: env.workspaceID, IncludeEphemeral: true})
+	if err != nil {
+		t.Fatalf("ListAgents failed: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != agent.ID {
+		t.Fatalf("expected the ephemeral agent listed on request, got %d agents", len(listed))
+	}
+}
+
+func TestExtractResponse(t *testing.T) {
+	const welcome = "Welcome to Claude Code\n\nclaude> "
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		prompt string
+		want   string
+	}{
+		{
+			name:   "plain answer at the prompt",
+			before: welcome,
+			after:  welcome + "What is 2+2?\n4\n\nclaude> ",
+			prompt: "What is 2+2?",
+			want:   "4",
+		},
+		{
+			name:   "status lines and markers",
+			before: welcome,
+			after: welcome + "Summarize the diff\n" +
+				"⠙ Thinking… (esc to interrupt)\n" +
+				"✻ Reading files… (12s · esc to interrupt)\n" +
+				"⏺ The diff renames the config loader.\n" +
+				"  Callers are updated in three packages.\n" +
+				"\n" +
+				"⏺ Tests were added for the new name.\n" +
+				"\n" +
+				"claude> ",
+			prompt: "Summarize the diff",
+			want: "The diff renames the config loader.\n" +
+				"Callers are updated in three packages.\n" +
+				"\n" +
+				"Tests were added for the new name.",
+		},
+		{
+			name:   "input box and hints below the answer",
+			before: "╭──────────────╮\n│ >            │\n╰──────────────╯\n  ? for shortcuts",
+			after: "> List the packages\n" +
+				"\n" +
+				"● There are two packages:\n" +
+				"  - internal/agent\n" +
+				"  - internal/cli\n" +
+				"\n" +
+				"╭──────────────╮\n" +
+				"│ >            │\n" +
+				"╰──────────────╯\n" +
+				"  ? for shortcuts\n",
+			prompt: "List the packages",
+			want:   "There are two packages:\n- internal/agent\n- internal/cli",
+		},
+		{
+			name:   "multi-line prompt echoes its last line last",
+			before: welcome,
+			after:  welcome + "Review this:\nfunc f() {}\n⏺ It has no body.\n\n❯ ",
+			prompt: "Review this:\nfunc f() {}\n",
+			want:   "It has no body.",
+		},
+		{
+			name:   "scrolled history falls back to the prompt echo",
+			before: "old line 1\nold line 2\nclaude> ",
+			after:  "old line 2\nclaude> Explain\nIt is a cache.\nclaude> ",
+			prompt: "Explain",
+			want:   "It is a cache.",
+		},
+		{
+			name:   "indented code keeps its relative indentation",
+			before: welcome,
+			after:  welcome + "Show a loop\n⏺ Like this:\n\n    for i := range n {\n        use(i)\n    }\n\nclaude> ",
+			prompt: "Show a loop",
+			want:   "Like this:\n\n  for i := range n {\n      use(i)\n  }",
+		},
+		{
+			name:   "still thinking",
+			before: welcome,
+			after:  welcome + "Explain\n⠙ Thinking… (esc to interrupt)\n",
+			prompt: "Explain",
+			want:   "",
+		},
+		{
+			name:   "prompt not answered yet",
+			before: welcome,
+			after:  welcome + "Explain",
+			prompt: "Explain",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractResponse(tt.before, tt.after, tt.prompt); got != tt.want {
+				t.Fatalf("ExtractResponse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
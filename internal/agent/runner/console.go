@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// pipeConsole is the console of a process run on plain pipes: its input
+// is written to stdin, and stdout and stderr are read interleaved.
+type pipeConsole struct {
+	stdin  *os.File
+	output *os.File
+}
+
+// startPipeConsole starts cmd with its stdin and its combined stdout and
+// stderr on pipes. Reads return io.EOF once the process, and anything it
+// left holding the pipe, has exited.
+func startPipeConsole(cmd *exec.Cmd) (*pipeConsole, error) {
+	inRead, inWrite, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	outRead, outWrite, err := os.Pipe()
+	if err != nil {
+		inRead.Close()
+		inWrite.Close()
+		return nil, err
+	}
+
+	cmd.Stdin = inRead
+	cmd.Stdout = outWrite
+	cmd.Stderr = outWrite
+	err = cmd.Start()
+
+	// The child holds its own copies; closing ours lets reads end at exit.
+	inRead.Close()
+	outWrite.Close()
+	if err != nil {
+		inWrite.Close()
+		outRead.Close()
+		return nil, err
+	}
+	return &pipeConsole{stdin: inWrite, output: outRead}, nil
+}
+
+func (c *pipeConsole) Read(p []byte) (int, error) {
+	return c.output.Read(p)
+}
+
+func (c *pipeConsole) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+func (c *pipeConsole) Close() error {
+	return errors.Join(c.stdin.Close(), c.output.Close())
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package runner
+
+import (
+	"io"
+	"os/exec"
+
+	"github.com/creack/pty"
+)
+
+// startConsole starts cmd on a new PTY.
+func startConsole(cmd *exec.Cmd) (io.ReadWriteCloser, error) {
+	return pty.Start(cmd)
+}
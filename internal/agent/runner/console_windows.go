@@ -0,0 +1,15 @@
+//go:build windows
+
+package runner
+
+import (
+	"io"
+	"os/exec"
+)
+
+// startConsole starts cmd on plain pipes: the PTY library has no ConPTY
+// support, so agents see no terminal and run as if their output were
+// redirected.
+func startConsole(cmd *exec.Cmd) (io.ReadWriteCloser, error) {
+	return startPipeConsole(cmd)
+}
@@ -15,8 +15,11 @@ import (
 	"sync"
 	"time"
 
-	"github.com/creack/pty"
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/clock"
 	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/redact"
 )
 
 var (
@@ -61,11 +64,22 @@ type RunnerEvent struct {
 }
 
 // Runner manages a PTY-wrapped agent CLI process and emits structured events.
+// Where no PTY is available, as on Windows, the process runs on plain pipes.
 type Runner struct {
 	WorkspaceID string
 	AgentID     string
 	Command     []string
 
+	// Dir is the command's working directory (default: the runner's).
+	Dir string
+
+	// Env holds KEY=value pairs added to the runner's own environment.
+	Env []string
+
+	// Adapter names the agent type whose declared prompt and busy regexes
+	// apply when PromptRegex or BusyRegex is unset.
+	Adapter string
+
 	PromptRegex *regexp.Regexp
 	BusyRegex   *regexp.Regexp
 
@@ -74,18 +88,24 @@ type Runner struct {
 	TailBytes         int
 
 	EventSink     EventSink
+	Redactor      *redact.Redactor
 	ControlReader io.Reader
 	OutputWriter  io.Writer
-	Now           func() time.Time
+	Clock         clock.Clock
 
-	pty    *os.File
-	cmd    *exec.Cmd
-	output *LineRing
+	console  io.ReadWriteCloser
+	cmd      *exec.Cmd
+	output   *LineRing
+	parent   context.Context
+	runCtx   context.Context
+	cancel   context.CancelFunc
+	outputCh chan error
 
 	stateMu      sync.Mutex
 	ready        bool
-	pausedUntil  time.Time
+	pausedUntil  clock.Deadline
 	lastActivity time.Time
+	lastActiveAt time.Duration
 
 	writeMu sync.Mutex
 }
@@ -161,39 +181,58 @@ func DefaultBusyRegex() *regexp.Regexp {
 
 // Run starts the agent command, captures output, and emits events until exit.
 func (r *Runner) Run(ctx context.Context) error {
+	if err := r.Start(ctx); err != nil {
+		return err
+	}
+	return r.Wait()
+}
+
+// Start starts the agent command and begins capturing its output. Wait
+// must be called to release it once the command exits.
+func (r *Runner) Start(ctx context.Context) error {
 	if err := r.validate(); err != nil {
 		return err
 	}
 
 	r.applyDefaults()
-	logger := logging.Component("agent-runner")
 
 	r.cmd = exec.CommandContext(ctx, r.Command[0], r.Command[1:]...)
-	r.cmd.Env = os.Environ()
+	r.cmd.Env = append(os.Environ(), r.Env...)
+	r.cmd.Dir = r.Dir
 
-	ptyFile, err := pty.Start(r.cmd)
+	console, err := startConsole(r.cmd)
 	if err != nil {
-		return fmt.Errorf("start pty: %w", err)
+		return fmt.Errorf("start console: %w", err)
 	}
-	r.pty = ptyFile
-	defer r.pty.Close()
+	r.console = console
 
 	r.setLastActivity(r.now())
 
-	runCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	r.parent = ctx
+	r.runCtx, r.cancel = context.WithCancel(ctx)
 
-	outputErrCh := make(chan error, 1)
-	go r.readOutput(runCtx, outputErrCh)
+	r.outputCh = make(chan error, 1)
+	go r.readOutput(r.runCtx, r.outputCh)
 
 	if r.ControlReader != nil {
-		go r.controlLoop(runCtx)
+		go r.controlLoop(r.runCtx)
 	}
 
-	go r.heartbeatLoop(runCtx)
+	go r.heartbeatLoop(r.runCtx)
+	return nil
+}
 
-	err = r.cmd.Wait()
-	cancel()
+// Wait waits for the command started by Start to exit and emits its exit
+// event.
+func (r *Runner) Wait() error {
+	if r.cancel == nil {
+		return fmt.Errorf("runner has not started")
+	}
+	defer r.console.Close()
+	logger := logging.Component("agent-runner")
+
+	err := r.cmd.Wait()
+	r.cancel()
 
 	exitCode := 0
 	exitErr := ""
@@ -206,7 +245,7 @@ func (r *Runner) Run(ctx context.Context) error {
 		}
 	}
 
-	r.emit(runCtx, EventTypeExit, ExitData{
+	r.emit(r.runCtx, EventTypeExit, ExitData{
 		ExitCode: exitCode,
 		Error:    exitErr,
 	})
@@ -219,13 +258,13 @@ func (r *Runner) Run(ctx context.Context) error {
 
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
-			return ctx.Err()
+			return r.parent.Err()
 		}
 		return err
 	}
 
 	select {
-	case outputErr := <-outputErrCh:
+	case outputErr := <-r.outputCh:
 		if outputErr != nil && !errors.Is(outputErr, io.EOF) {
 			logger.Warn().Err(outputErr).Msg("output reader error")
 		}
@@ -235,12 +274,20 @@ func (r *Runner) Run(ctx context.Context) error {
 	return nil
 }
 
+// PID returns the agent process's ID, or 0 before Start.
+func (r *Runner) PID() int {
+	if r == nil || r.cmd == nil || r.cmd.Process == nil {
+		return 0
+	}
+	return r.cmd.Process.Pid
+}
+
 // SendInput writes a message to the agent process.
 func (r *Runner) SendInput(ctx context.Context, text string) error {
 	if r == nil {
 		return fmt.Errorf("runner is nil")
 	}
-	if r.pty == nil {
+	if r.console == nil {
 		return fmt.Errorf("runner has not started")
 	}
 
@@ -253,15 +300,10 @@ func (r *Runner) SendInput(ctx context.Context, text string) error {
 		payload += "\n"
 	}
 
-	r.writeMu.Lock()
-	_, err := io.WriteString(r.pty, payload)
-	r.writeMu.Unlock()
-	if err != nil {
-		return fmt.Errorf("write input: %w", err)
+	if err := r.write(payload); err != nil {
+		return err
 	}
-
-	r.setLastActivity(r.now())
-	preview, truncated := truncateText(text, maxEventLineLength)
+	preview, truncated := truncateText(r.Redactor.Redact(text), maxEventLineLength)
 	if preview != "" || text == "" {
 		r.emit(ctx, EventTypeInputSent, InputSentData{Text: preview, Truncated: truncated})
 	}
@@ -273,6 +315,38 @@ func (r *Runner) SendInput(ctx context.Context, text string) error {
 	return nil
 }
 
+// SendKeys writes data to the agent process as typed, without adding a
+// newline or emitting an input event. It is how control keys such as
+// Escape reach the agent.
+func (r *Runner) SendKeys(data string) error {
+	if r == nil {
+		return fmt.Errorf("runner is nil")
+	}
+	return r.write(data)
+}
+
+// Interrupt sends the agent process Ctrl+C. Under a PTY that interrupts
+// it; on plain pipes most programs ignore it, and only killing stops them.
+func (r *Runner) Interrupt() error {
+	return r.SendKeys("\x03")
+}
+
+func (r *Runner) write(data string) error {
+	if r.console == nil {
+		return fmt.Errorf("runner has not started")
+	}
+
+	r.writeMu.Lock()
+	_, err := io.WriteString(r.console, data)
+	r.writeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("write input: %w", err)
+	}
+
+	r.setLastActivity(r.now())
+	return nil
+}
+
 func (r *Runner) validate() error {
 	if strings.TrimSpace(r.WorkspaceID) == "" {
 		return ErrMissingWorkspaceID
@@ -296,31 +370,42 @@ func (r *Runner) applyDefaults() {
 	if r.TailBytes <= 0 {
 		r.TailBytes = defaultTailBytes
 	}
+	caps := adapters.CapabilitiesFor(models.AgentType(r.Adapter))
+	if r.PromptRegex == nil {
+		r.PromptRegex = caps.PromptRegex
+	}
 	if r.PromptRegex == nil {
 		r.PromptRegex = defaultPromptRegex
 	}
+	if r.BusyRegex == nil {
+		r.BusyRegex = caps.BusyRegex
+	}
 	if r.BusyRegex == nil {
 		r.BusyRegex = defaultBusyRegex
 	}
 	if r.EventSink == nil {
 		r.EventSink = NoopSink{}
 	}
+	if r.Redactor == nil {
+		r.Redactor = redact.Default()
+	}
 	if r.OutputWriter == nil {
 		r.OutputWriter = io.Discard
 	}
-	if r.Now == nil {
-		r.Now = time.Now
+	if r.Clock == nil {
+		r.Clock = clock.Real()
 	}
 	if r.output == nil {
 		r.output = NewLineRing(r.TailLines)
 	}
 }
 
+func (r *Runner) clock() clock.Clock {
+	return clock.OrReal(r.Clock)
+}
+
 func (r *Runner) now() time.Time {
-	if r.Now != nil {
-		return r.Now().UTC()
-	}
-	return time.Now().UTC()
+	return r.clock().Now().UTC()
 }
 
 func (r *Runner) readOutput(ctx context.Context, errCh chan<- error) {
@@ -330,7 +415,7 @@ func (r *Runner) readOutput(ctx context.Context, errCh chan<- error) {
 	tail := make([]byte, 0, r.TailBytes)
 
 	for {
-		n, err := r.pty.Read(buf)
+		n, err := r.console.Read(buf)
 		if n > 0 {
 			chunk := buf[:n]
 			if _, writeErr := r.OutputWriter.Write(chunk); writeErr != nil {
@@ -370,6 +455,8 @@ func (r *Runner) readOutput(ctx context.Context, errCh chan<- error) {
 }
 
 func (r *Runner) handleLine(ctx context.Context, line string) {
+	// Redact once so both the heartbeat tail and the event carry the same text.
+	line = r.Redactor.Redact(line)
 	r.output.Add(line)
 	preview, truncated := truncateText(line, maxEventLineLength)
 	r.emit(ctx, EventTypeOutputLine, OutputLineData{Line: preview, Truncated: truncated})
@@ -417,16 +504,19 @@ func (r *Runner) isReady() bool {
 func (r *Runner) setLastActivity(ts time.Time) {
 	r.stateMu.Lock()
 	r.lastActivity = ts
+	r.lastActiveAt = r.clock().Monotonic()
 	r.stateMu.Unlock()
 }
 
-func (r *Runner) getLastActivity() time.Time {
+// getLastActivity returns the wall time of the last activity and how long ago
+// it was, measured on the monotonic clock.
+func (r *Runner) getLastActivity() (time.Time, time.Duration) {
 	r.stateMu.Lock()
 	defer r.stateMu.Unlock()
-	return r.lastActivity
+	return r.lastActivity, clock.Since(r.clock(), r.lastActiveAt)
 }
 
-func (r *Runner) setPausedUntil(until time.Time) {
+func (r *Runner) setPausedUntil(until clock.Deadline) {
 	r.stateMu.Lock()
 	if until.After(r.pausedUntil) {
 		r.pausedUntil = until
@@ -434,7 +524,7 @@ func (r *Runner) setPausedUntil(until time.Time) {
 	r.stateMu.Unlock()
 }
 
-func (r *Runner) getPausedUntil() time.Time {
+func (r *Runner) getPausedUntil() clock.Deadline {
 	r.stateMu.Lock()
 	defer r.stateMu.Unlock()
 	return r.pausedUntil
@@ -443,19 +533,15 @@ func (r *Runner) getPausedUntil() time.Time {
 func (r *Runner) waitForResume(ctx context.Context) error {
 	for {
 		until := r.getPausedUntil()
-		if until.IsZero() {
-			return nil
-		}
-		now := r.now()
-		if !until.After(now) {
+		if until.Expired(r.clock()) {
 			return nil
 		}
-		timer := time.NewTimer(time.Until(until))
+		timer := r.clock().NewTimer(until.Remaining(r.clock()))
 		select {
 		case <-ctx.Done():
 			timer.Stop()
 			return ctx.Err()
-		case <-timer.C:
+		case <-timer.C():
 		}
 	}
 }
@@ -465,17 +551,15 @@ func (r *Runner) heartbeatLoop(ctx context.Context) {
 		return
 	}
 
-	ticker := time.NewTicker(r.HeartbeatInterval)
+	ticker := r.clock().NewTicker(r.HeartbeatInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			last := r.getLastActivity()
-			now := r.now()
-			idleFor := now.Sub(last)
+		case <-ticker.C():
+			last, idleFor := r.getLastActivity()
 			tail := truncateLines(r.output.Snapshot(), maxEventLineLength)
 			r.emit(ctx, EventTypeHeartbeat, HeartbeatData{
 				LastActivity: last,
@@ -525,7 +609,7 @@ func (r *Runner) handleControl(ctx context.Context, cmd ControlCommand, raw stri
 			text = strings.TrimSpace(cmd.Message)
 		}
 		if text == "" {
-			r.emit(ctx, EventTypeControlError, ControlErrorData{Error: "send_message requires text", Raw: raw})
+			r.emit(ctx, EventTypeControlError, ControlErrorData{Error: "send_message requires text", Raw: r.Redactor.Redact(raw)})
 			return
 		}
 		if err := r.SendInput(ctx, text); err != nil {
@@ -534,25 +618,26 @@ func (r *Runner) handleControl(ctx context.Context, cmd ControlCommand, raw stri
 	case "pause":
 		dur, err := parseDuration(cmd.Duration)
 		if err != nil {
-			r.emit(ctx, EventTypeControlError, ControlErrorData{Error: err.Error(), Raw: raw})
+			r.emit(ctx, EventTypeControlError, ControlErrorData{Error: err.Error(), Raw: r.Redactor.Redact(raw)})
 			return
 		}
-		until := r.now().Add(dur)
+		until := clock.NewDeadline(r.clock(), dur)
 		r.setPausedUntil(until)
-		r.emit(ctx, EventTypePause, ControlData{Action: "pause", Duration: dur.String(), Until: until.Format(time.RFC3339)})
+		r.emit(ctx, EventTypePause, ControlData{Action: "pause", Duration: dur.String(), Until: until.Time().UTC().Format(time.RFC3339)})
 	case "cooldown":
-		until, err := parseCooldown(cmd.Until, cmd.Duration, r.now())
+		dur, err := parseCooldown(cmd.Until, cmd.Duration, r.now())
 		if err != nil {
-			r.emit(ctx, EventTypeControlError, ControlErrorData{Error: err.Error(), Raw: raw})
+			r.emit(ctx, EventTypeControlError, ControlErrorData{Error: err.Error(), Raw: r.Redactor.Redact(raw)})
 			return
 		}
+		until := clock.NewDeadline(r.clock(), dur)
 		r.setPausedUntil(until)
-		r.emit(ctx, EventTypeCooldown, ControlData{Action: "cooldown", Until: until.Format(time.RFC3339)})
+		r.emit(ctx, EventTypeCooldown, ControlData{Action: "cooldown", Until: until.Time().UTC().Format(time.RFC3339)})
 	case "swap_account":
 		accountID := strings.TrimSpace(cmd.AccountID)
 		r.emit(ctx, EventTypeSwapAccount, ControlData{Action: "swap_account", AccountID: accountID})
 	default:
-		r.emit(ctx, EventTypeControlError, ControlErrorData{Error: "unknown control command", Raw: raw})
+		r.emit(ctx, EventTypeControlError, ControlErrorData{Error: "unknown control command", Raw: r.Redactor.Redact(raw)})
 		logger.Warn().Str("command", cmdType).Msg("unknown control command")
 	}
 }
@@ -615,27 +700,29 @@ func parseDuration(value string) (time.Duration, error) {
 	return dur, nil
 }
 
-func parseCooldown(until, duration string, now time.Time) (time.Time, error) {
+// parseCooldown returns the cooldown length. An absolute until is converted to
+// a duration against now once, so the resulting deadline can be tracked on the
+// monotonic clock.
+func parseCooldown(until, duration string, now time.Time) (time.Duration, error) {
 	if strings.TrimSpace(until) != "" {
 		timeValue, err := time.Parse(time.RFC3339, until)
-		if err == nil {
-			return timeValue.UTC(), nil
+		if err != nil {
+			timeValue, err = time.Parse(time.RFC3339Nano, until)
 		}
-		timeValue, err = time.Parse(time.RFC3339Nano, until)
 		if err == nil {
-			return timeValue.UTC(), nil
+			return timeValue.Sub(now), nil
 		}
 	}
 
 	if strings.TrimSpace(duration) != "" {
 		dur, err := parseDuration(duration)
 		if err != nil {
-			return time.Time{}, err
+			return 0, err
 		}
-		return now.Add(dur), nil
+		return dur, nil
 	}
 
-	return time.Time{}, fmt.Errorf("cooldown requires until or duration")
+	return 0, fmt.Errorf("cooldown requires until or duration")
 }
 
 func splitLines(buffer []byte) ([]string, []byte) {
@@ -665,13 +752,7 @@ func containsNonWhitespace(data []byte) bool {
 }
 
 func truncateText(value string, max int) (string, bool) {
-	if max <= 0 {
-		return value, false
-	}
-	if len(value) <= max {
-		return value, false
-	}
-	return value[:max], true
+	return redact.TruncateHead(value, max)
 }
 
 func truncateLines(lines []string, max int) []string {
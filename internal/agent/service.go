@@ -3,6 +3,7 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
@@ -10,12 +11,19 @@ import (
 	"strings"
 	"time"
 
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
 	"github.com/opencode-ai/swarm/internal/account"
 	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/config"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/events"
 	"github.com/opencode-ai/swarm/internal/logging"
 	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/quiethours"
+	"github.com/opencode-ai/swarm/internal/shell"
+	"github.com/opencode-ai/swarm/internal/snapshot"
+	"github.com/opencode-ai/swarm/internal/store"
 	"github.com/opencode-ai/swarm/internal/tmux"
 	"github.com/opencode-ai/swarm/internal/workspace"
 	"github.com/rs/zerolog"
@@ -31,23 +39,37 @@ var (
 	ErrTerminateFailed      = errors.New("failed to terminate agent")
 	ErrSendFailed           = errors.New("failed to send message to agent")
 	ErrAgentNotIdle         = errors.New("agent is not idle")
+	ErrWorkspacePaused      = errors.New("workspace is paused")
+	ErrWorkspaceDraining    = errors.New("workspace is draining")
+	ErrRemoteAgent          = errors.New("not supported for agents on swarmd nodes")
 )
 
 // Service manages agent lifecycle operations.
 type Service struct {
-	repo             *db.AgentRepository
-	queueRepo        *db.QueueRepository
-	portRepo         *db.PortRepository
-	workspaceService *workspace.Service
-	accountService   *account.Service
-	tmuxClient       *tmux.Client
-	eventRepo        *db.EventRepository
-	archiveDir       string
-	archiveAfter     time.Duration
-	paneMap          *PaneMap
-	publisher        events.Publisher
-	logger           zerolog.Logger
-	eventWatcher     *adapters.OpenCodeEventWatcher
+	repo                store.AgentStore
+	queueRepo           store.QueueStore
+	portRepo            *db.PortRepository
+	workspaceService    *workspace.Service
+	accountService      *account.Service
+	tmuxClient          *tmux.Client
+	eventRepo           store.EventStore
+	archiveDir          string
+	archiveAfter        time.Duration
+	recordingDir        string
+	recordingSink       RecordingSink
+	snapshots           *snapshot.Store
+	checkpoints         *checkpointer
+	paneMap             *PaneMap
+	publisher           events.Publisher
+	logger              zerolog.Logger
+	eventWatcher        *adapters.OpenCodeEventWatcher
+	probe               *SpawnProbe
+	budget              *budgetGuard
+	minCLIVersions      map[string]string
+	blockOutdatedSpawns bool
+	daemons             *daemonDispatcher
+	replenish           func(workspaceID string)
+	auditor             *audit.Recorder
 }
 
 // ServiceOption configures an AgentService.
@@ -60,8 +82,8 @@ func WithPublisher(publisher events.Publisher) ServiceOption {
 	}
 }
 
-// WithEventRepository configures an event repository for archiving.
-func WithEventRepository(repo *db.EventRepository) ServiceOption {
+// WithEventRepository configures an event store for archiving.
+func WithEventRepository(repo store.EventStore) ServiceOption {
 	return func(s *Service) {
 		s.eventRepo = repo
 	}
@@ -81,6 +103,15 @@ func WithArchiveAfter(duration time.Duration) ServiceOption {
 	}
 }
 
+// WithRecording enables pane recording. Cast files are stored under dir and
+// sink builds the shell command tmux pipes pane output into.
+func WithRecording(dir string, sink RecordingSink) ServiceOption {
+	return func(s *Service) {
+		s.recordingDir = strings.TrimSpace(dir)
+		s.recordingSink = sink
+	}
+}
+
 // WithPortRepository configures a port repository for OpenCode port allocation.
 func WithPortRepository(repo *db.PortRepository) ServiceOption {
 	return func(s *Service) {
@@ -95,10 +126,25 @@ func WithEventWatcher(watcher *adapters.OpenCodeEventWatcher) ServiceOption {
 	}
 }
 
+// WithSpawnProbe checks that the agent CLI is installed before each spawn.
+func WithSpawnProbe(probe *SpawnProbe) ServiceOption {
+	return func(s *Service) {
+		s.probe = probe
+	}
+}
+
+// WithBudget checks each spawn against the daily cost ceilings in cfg,
+// projecting cost from the usage and agents in sources.
+func WithBudget(cfg *config.Config, sources account.ForecastSources) ServiceOption {
+	return func(s *Service) {
+		s.budget = &budgetGuard{cfg: cfg, sources: sources}
+	}
+}
+
 // NewService creates a new AgentService.
 func NewService(
-	repo *db.AgentRepository,
-	queueRepo *db.QueueRepository,
+	repo store.AgentStore,
+	queueRepo store.QueueStore,
 	workspaceService *workspace.Service,
 	accountService *account.Service,
 	tmuxClient *tmux.Client,
@@ -137,7 +183,21 @@ type SpawnOptions struct {
 	// ApprovalPolicy is the effective approval policy for this agent.
 	ApprovalPolicy string
 
-	// Environment contains optional environment variable overrides.
+	// Model selects the model passed to the agent CLI (empty = CLI default).
+	// Unknown names are passed through with a warning.
+	Model string
+
+	// WorkspaceEnvironment holds the workspace's configured default
+	// environment variables.
+	WorkspaceEnvironment map[string]string
+
+	// TemplateEnvironment holds environment variables from the recipe the
+	// agent is spawned from.
+	TemplateEnvironment map[string]string
+
+	// Environment contains optional environment variable overrides. They
+	// take precedence over workspace and template variables; account
+	// credentials take precedence over all three.
 	Environment map[string]string
 
 	// WorkingDir is an optional working directory override.
@@ -151,15 +211,66 @@ type SpawnOptions struct {
 	// ReadyPollInterval controls how often to poll for readiness.
 	// If zero, defaults to 250 milliseconds.
 	ReadyPollInterval time.Duration
+
+	// Record starts a pane recording before the agent CLI is launched.
+	Record bool
+
+	// Tags label the agent for filtering, e.g. by 'swarm ws broadcast'.
+	Tags []string
+
+	// OverrideBudget spawns even when the projected daily cost would
+	// exceed a budget ceiling.
+	OverrideBudget bool
+
+	// Standby marks the agent as a warm standby, held idle until claimed.
+	Standby bool
+
+	// Resume starts the CLI on its most recent session on disk, such as
+	// one restored from a checkpoint.
+	Resume bool
+
+	// Ephemeral marks a one-shot agent that is terminated once its prompt
+	// completes; it is hidden from ListAgents unless IncludeEphemeral is set.
+	Ephemeral bool
+
+	// QuietHours is the agent's own quiet hours spec, overriding its
+	// workspace's (see internal/quiethours).
+	QuietHours string
 }
 
 // SpawnAgent creates a new agent in a workspace.
-func (s *Service) SpawnAgent(ctx context.Context, opts SpawnOptions) (*models.Agent, error) {
+func (s *Service) SpawnAgent(ctx context.Context, opts SpawnOptions) (spawned *models.Agent, err error) {
+	defer func() {
+		var id string
+		if spawned != nil {
+			id = spawned.ID
+		}
+		s.audit(ctx, AuditSpawnAgent, id, audit.Params(
+			"workspace_id", opts.WorkspaceID,
+			"type", string(opts.Type),
+			"model", opts.Model,
+			"account_id", opts.AccountID,
+			"initial_prompt", opts.InitialPrompt,
+		), err)
+	}()
 	s.logger.Debug().
+		Ctx(ctx).
 		Str("workspace_id", opts.WorkspaceID).
 		Str("type", string(opts.Type)).
 		Msg("spawning agent")
 
+	opts.Model = strings.TrimSpace(opts.Model)
+	if err := adapters.CheckModel(opts.Type, opts.Model); err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).
+			Str("type", string(opts.Type)).
+			Str("model", opts.Model).
+			Msg("model not recognized, passing through")
+	}
+	opts.QuietHours = strings.TrimSpace(opts.QuietHours)
+	if _, err := quiethours.ParseSchedule(opts.QuietHours); err != nil {
+		return nil, fmt.Errorf("invalid quiet hours: %w", err)
+	}
+
 	// Validate workspace exists
 	ws, err := s.workspaceService.GetWorkspace(ctx, opts.WorkspaceID)
 	if err != nil {
@@ -168,6 +279,37 @@ func (s *Service) SpawnAgent(ctx context.Context, opts SpawnOptions) (*models.Ag
 		}
 		return nil, fmt.Errorf("failed to get workspace: %w", err)
 	}
+	if ws.IsPaused(time.Now()) {
+		return nil, fmt.Errorf("%w: %s (resume it with 'swarm ws resume %s')", ErrWorkspacePaused, ws.Name, ws.Name)
+	}
+	if ws.Draining {
+		return nil, fmt.Errorf("%w: %s (undrain it with 'swarm ws undrain %s')", ErrWorkspaceDraining, ws.Name, ws.Name)
+	}
+	if err := s.checkBudget(ctx, ws, opts); err != nil {
+		return nil, err
+	}
+	daemon, err := s.daemonForNode(ctx, ws.NodeID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSpawnFailed, err)
+	}
+
+	// Fail fast when the CLI is missing rather than spawning a pane that
+	// only shows "command not found".
+	var cliVersion string
+	var probedAt *time.Time
+	if s.probe != nil && daemon == nil {
+		cliVersion, err = s.probe.Probe(ctx, ws.NodeID, opts.Type)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkCLIVersion(opts.Type, cliVersion); err != nil {
+			return nil, err
+		}
+		if cliVersion != "" {
+			now := time.Now().UTC()
+			probedAt = &now
+		}
+	}
 
 	// Determine working directory
 	workDir := opts.WorkingDir
@@ -176,37 +318,37 @@ func (s *Service) SpawnAgent(ctx context.Context, opts SpawnOptions) (*models.Ag
 	}
 
 	// Inject account credentials into environment
+	var credEnv map[string]string
 	if opts.AccountID != "" && s.accountService != nil {
-		credEnv, err := s.accountService.GetCredentialEnv(ctx, opts.AccountID)
+		credEnv, err = s.accountService.GetCredentialEnv(ctx, opts.AccountID)
 		if err != nil {
-			s.logger.Warn().Err(err).
+			s.logger.Warn().Ctx(ctx).Err(err).
 				Str("account_id", opts.AccountID).
 				Msg("failed to resolve account credentials, continuing without injection")
 		} else if len(credEnv) > 0 {
-			opts.Environment = account.MergeEnv(opts.Environment, credEnv)
 			s.logger.Debug().
+				Ctx(ctx).
 				Str("account_id", opts.AccountID).
 				Int("env_vars", len(credEnv)).
 				Msg("injected account credentials")
 		}
 	}
+	env, resolvedEnv := ResolveEnvironment(spawnEnvLayers(opts, credEnv)...)
+	storedEnv := withoutCredentials(env, resolvedEnv)
+	opts.Environment = env
 
-	// Create a new pane in the workspace's tmux session (prefer the agents window).
-	splitTarget := ws.TmuxSession
-	if ws.TmuxSession != "" {
-		splitTarget = fmt.Sprintf("%s:%s", ws.TmuxSession, tmux.AgentWindowName)
-	}
-	paneID, err := s.tmuxClient.SplitWindow(ctx, splitTarget, false, workDir)
-	if err != nil && splitTarget != ws.TmuxSession {
-		s.logger.Debug().Err(err).Str("target", splitTarget).Msg("failed to split agents window, falling back to session")
-		paneID, err = s.tmuxClient.SplitWindow(ctx, ws.TmuxSession, false, workDir)
+	if daemon != nil {
+		return s.spawnOnDaemon(ctx, daemon, ws, workDir, opts, resolvedEnv)
 	}
+
+	paneID, err := s.splitAgentPane(ctx, ws, workDir)
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to create pane: %v", ErrSpawnFailed, err)
 	}
 
-	// Use the global pane ID directly as the target.
-	// Pane IDs like %123 are globally unique in tmux and work as-is.
+	// Store the pane ID rather than a session:window.pane target. Pane IDs
+	// like %123 are globally unique in tmux and survive panes being moved
+	// or windows renumbered.
 	paneTarget := paneID
 
 	// Create agent record
@@ -214,7 +356,9 @@ func (s *Service) SpawnAgent(ctx context.Context, opts SpawnOptions) (*models.Ag
 		WorkspaceID: opts.WorkspaceID,
 		Type:        opts.Type,
 		TmuxPane:    paneTarget,
+		TmuxSession: ws.TmuxSession,
 		AccountID:   opts.AccountID,
+		Standby:     opts.Standby,
 		State:       models.AgentStateStarting,
 		StateInfo: models.StateInfo{
 			State:      models.AgentStateStarting,
@@ -223,8 +367,16 @@ func (s *Service) SpawnAgent(ctx context.Context, opts SpawnOptions) (*models.Ag
 			DetectedAt: time.Now().UTC(),
 		},
 		Metadata: models.AgentMetadata{
-			Environment:    opts.Environment,
-			ApprovalPolicy: opts.ApprovalPolicy,
+			Model:               opts.Model,
+			Environment:         storedEnv,
+			ResolvedEnv:         resolvedEnv,
+			ApprovalPolicy:      opts.ApprovalPolicy,
+			CLIVersion:          cliVersion,
+			InstalledCLIVersion: cliVersion,
+			CLIVersionCheckedAt: probedAt,
+			Tags:                models.NormalizeTags(opts.Tags),
+			Ephemeral:           opts.Ephemeral,
+			QuietHours:          opts.QuietHours,
 		},
 	}
 
@@ -242,6 +394,7 @@ func (s *Service) SpawnAgent(ctx context.Context, opts SpawnOptions) (*models.Ag
 			Port: port,
 		}
 		s.logger.Debug().
+			Ctx(ctx).
 			Str("workspace_id", opts.WorkspaceID).
 			Int("port", port).
 			Msg("allocated port for OpenCode agent")
@@ -262,7 +415,13 @@ func (s *Service) SpawnAgent(ctx context.Context, opts SpawnOptions) (*models.Ag
 
 	// Register pane mapping
 	if err := s.paneMap.Register(agent.ID, paneID, paneTarget); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to register pane mapping")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to register pane mapping")
+	}
+
+	if opts.Record {
+		if err := s.startRecording(ctx, agent); err != nil {
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to start recording")
+		}
 	}
 
 	// Start the agent CLI in the pane
@@ -270,7 +429,7 @@ func (s *Service) SpawnAgent(ctx context.Context, opts SpawnOptions) (*models.Ag
 	if startCmd != "" {
 		if err := s.tmuxClient.SendKeys(ctx, paneTarget, startCmd, true, true); err != nil {
 			spawnErr := fmt.Errorf("failed to send start command: %w", err)
-			s.logger.Warn().Err(spawnErr).Str("agent_id", agent.ID).Msg("agent spawn failed")
+			s.logger.Warn().Ctx(ctx).Err(spawnErr).Str("agent_id", agent.ID).Msg("agent spawn failed")
 			s.markAgentError(ctx, agent, spawnErr.Error(), models.StateConfidenceLow, nil)
 			s.cleanupSpawnFailure(ctx, agent)
 			return nil, fmt.Errorf("%w: %v", ErrSpawnFailed, spawnErr)
@@ -281,36 +440,65 @@ func (s *Service) SpawnAgent(ctx context.Context, opts SpawnOptions) (*models.Ag
 	// Send initial prompt if provided
 	if opts.InitialPrompt != "" {
 		// Wait a bit for the agent to start
-		time.Sleep(500 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+		case <-time.After(500 * time.Millisecond):
+		}
 		if err := s.tmuxClient.SendKeys(ctx, paneTarget, opts.InitialPrompt, true, true); err != nil {
-			s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to send initial prompt")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to send initial prompt")
 		}
 	}
 
 	// Wait for agent to reach ready/idle state
 	if err := s.waitForReady(ctx, agent, opts); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("agent failed to reach ready state")
-		s.markAgentError(ctx, agent, err.Error(), models.StateConfidenceLow, nil)
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("agent failed to reach ready state")
+		// Record the failure even when the wait was cancelled.
+		s.markAgentError(context.WithoutCancel(ctx), agent, err.Error(), models.StateConfidenceLow, nil)
 		s.cleanupSpawnFailure(ctx, agent)
-		return nil, fmt.Errorf("%w: %v", ErrSpawnFailed, err)
+		// A cancelled wait says nothing about the CLI, so keep its probe.
+		if s.probe != nil && ctx.Err() == nil {
+			s.probe.Invalidate(ws.NodeID, opts.Type)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrSpawnFailed, err)
 	}
 
 	s.logger.Info().
+		Ctx(ctx).
 		Str("agent_id", agent.ID).
 		Str("workspace_id", opts.WorkspaceID).
 		Str("type", string(opts.Type)).
 		Str("pane", paneTarget).
+		Str("cli_version", cliVersion).
 		Msg("agent spawned")
 
 	// Start SSE event watcher for OpenCode agents
 	s.startEventWatcher(ctx, agent)
 
 	// Emit event
-	s.publishEvent(ctx, models.EventTypeAgentSpawned, agent.ID, nil)
+	s.publishEvent(ctx, models.EventTypeAgentSpawned, agent.ID, models.AgentSpawnedPayload{
+		WorkspaceID: agent.WorkspaceID,
+		Type:        agent.Type,
+		AccountID:   agent.AccountID,
+	})
 
 	return agent, nil
 }
 
+// splitAgentPane creates a pane for an agent in the workspace's tmux
+// session, preferring its agents window, and returns the pane ID.
+func (s *Service) splitAgentPane(ctx context.Context, ws *models.Workspace, workDir string) (string, error) {
+	splitTarget := ws.TmuxSession
+	if ws.TmuxSession != "" {
+		splitTarget = fmt.Sprintf("%s:%s", ws.TmuxSession, tmux.AgentWindowName)
+	}
+	paneID, err := s.tmuxClient.SplitWindow(ctx, splitTarget, false, workDir)
+	if err != nil && splitTarget != ws.TmuxSession {
+		s.logger.Debug().Ctx(ctx).Err(err).Str("target", splitTarget).Msg("failed to split agents window, falling back to session")
+		paneID, err = s.tmuxClient.SplitWindow(ctx, ws.TmuxSession, false, workDir)
+	}
+	return paneID, err
+}
+
 func (s *Service) waitForReady(ctx context.Context, agent *models.Agent, opts SpawnOptions) error {
 	if agent == nil {
 		return fmt.Errorf("agent is nil")
@@ -356,23 +544,27 @@ func (s *Service) waitForReady(ctx context.Context, agent *models.Agent, opts Sp
 		case <-ticker.C:
 		}
 
-		output, err := s.tmuxClient.CapturePane(ctx, agent.TmuxPane, false)
+		output, err := s.capturePane(ctx, agent, false)
 		if err != nil {
 			lastCaptureErr = err
+			if agent.RemoteAgentID != "" {
+				s.logger.Debug().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to capture remote pane while waiting for ready")
+				continue
+			}
 			exists, checkErr := s.paneExists(ctx, agent.TmuxPane)
 			if checkErr != nil {
-				s.logger.Debug().Err(checkErr).Str("agent_id", agent.ID).Msg("failed to confirm pane existence while waiting for ready")
+				s.logger.Debug().Ctx(ctx).Err(checkErr).Str("agent_id", agent.ID).Msg("failed to confirm pane existence while waiting for ready")
 			} else if !exists {
 				return fmt.Errorf("agent pane %s missing; agent likely exited before ready", agent.TmuxPane)
 			}
-			s.logger.Debug().Err(err).Str("agent_id", agent.ID).Msg("failed to capture pane while waiting for ready")
+			s.logger.Debug().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to capture pane while waiting for ready")
 			continue
 		}
 		lastOutput = output
 
 		ready, err := adapter.DetectReady(output)
 		if err != nil {
-			s.logger.Debug().Err(err).Str("agent_id", agent.ID).Msg("ready detection failed")
+			s.logger.Debug().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("ready detection failed")
 		}
 		if ready {
 			s.markAgentState(ctx, agent, models.AgentStateIdle, "Agent ready", models.StateConfidenceMedium, nil)
@@ -411,7 +603,7 @@ func (s *Service) markAgentState(ctx context.Context, agent *models.Agent, state
 	agent.LastActivity = &now
 
 	if err := s.repo.Update(ctx, agent); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to update agent state")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to update agent state")
 	}
 }
 
@@ -419,28 +611,34 @@ func (s *Service) cleanupSpawnFailure(ctx context.Context, agent *models.Agent)
 	if agent == nil {
 		return
 	}
+	// A spawn cancelled mid-way (e.g. Ctrl+C) must still tear down its pane.
+	ctx = context.WithoutCancel(ctx)
 
-	if agent.TmuxPane != "" {
+	if agent.RemoteAgentID != "" {
+		if err := s.killRemoteAgent(ctx, agent, true); err != nil {
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to kill remote agent after spawn failure")
+		}
+	} else if agent.TmuxPane != "" {
 		if err := s.tmuxClient.KillPane(ctx, agent.TmuxPane); err != nil {
-			s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to kill pane after spawn failure")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to kill pane after spawn failure")
 		}
 	}
 
 	if s.queueRepo != nil {
 		if _, err := s.queueRepo.Clear(ctx, agent.ID); err != nil {
-			s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to clear queue after spawn failure")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to clear queue after spawn failure")
 		}
 	}
 
 	// Release allocated port for OpenCode agents
 	if s.portRepo != nil && agent.Metadata.OpenCode != nil && agent.Metadata.OpenCode.Port > 0 {
 		if _, err := s.portRepo.ReleaseByAgent(ctx, agent.ID); err != nil {
-			s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to release port after spawn failure")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to release port after spawn failure")
 		}
 	}
 
 	if err := s.paneMap.UnregisterAgent(agent.ID); err != nil && !errors.Is(err, ErrAgentNotFound) {
-		s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to unregister pane mapping after spawn failure")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to unregister pane mapping after spawn failure")
 	}
 }
 
@@ -451,12 +649,12 @@ func (s *Service) cleanupRestartFailure(ctx context.Context, agent *models.Agent
 
 	if agent.TmuxPane != "" {
 		if err := s.tmuxClient.KillPane(ctx, agent.TmuxPane); err != nil {
-			s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to kill pane after restart failure")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to kill pane after restart failure")
 		}
 	}
 
 	if err := s.paneMap.UnregisterAgent(agent.ID); err != nil && !errors.Is(err, ErrAgentNotFound) {
-		s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to unregister pane mapping after restart failure")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to unregister pane mapping after restart failure")
 	}
 }
 
@@ -600,6 +798,12 @@ type ListAgentsOptions struct {
 
 	// IncludeQueueLength includes queue length in results.
 	IncludeQueueLength bool
+
+	// IncludeDeleted includes terminated agents.
+	IncludeDeleted bool
+
+	// IncludeEphemeral includes one-shot agents spawned by Run.
+	IncludeEphemeral bool
 }
 
 // ListAgents returns agents matching the options.
@@ -607,20 +811,35 @@ func (s *Service) ListAgents(ctx context.Context, opts ListAgentsOptions) ([]*mo
 	var agents []*models.Agent
 	var err error
 
+	var query []db.AgentQueryOption
+	if opts.IncludeDeleted {
+		query = append(query, db.IncludeDeleted())
+	}
+
 	if opts.WorkspaceID != "" {
-		agents, err = s.repo.ListByWorkspace(ctx, opts.WorkspaceID)
+		agents, err = s.repo.ListByWorkspace(ctx, opts.WorkspaceID, query...)
 	} else if opts.State != nil {
-		agents, err = s.repo.ListByState(ctx, *opts.State)
+		agents, err = s.repo.ListByState(ctx, *opts.State, query...)
 	} else if opts.IncludeQueueLength {
-		agents, err = s.repo.ListWithQueueLength(ctx)
+		agents, err = s.repo.ListWithQueueLength(ctx, query...)
 	} else {
-		agents, err = s.repo.List(ctx)
+		agents, err = s.repo.List(ctx, query...)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to list agents: %w", err)
 	}
 
+	if !opts.IncludeEphemeral {
+		kept := agents[:0]
+		for _, agent := range agents {
+			if !agent.Metadata.Ephemeral {
+				kept = append(kept, agent)
+			}
+		}
+		agents = kept
+	}
+
 	return agents, nil
 }
 
@@ -658,6 +877,18 @@ type AgentStateResult struct {
 
 	// QueueLength is the number of pending queue items.
 	QueueLength int
+
+	// QueueStats are the agent's queue wait and throughput metrics, if
+	// any have been recorded. The service leaves it for callers to fill.
+	QueueStats *models.QueueWaitStats `json:",omitempty"`
+
+	// LatestNote is the newest note left on the agent, if any. The
+	// service leaves it for callers to fill.
+	LatestNote *models.Note `json:",omitempty"`
+
+	// Cost is what the agent has cost so far today, recorded and estimated
+	// apart. The service leaves it for callers to fill.
+	Cost *models.AgentCost `json:",omitempty"`
 }
 
 // GetAgentState retrieves comprehensive state for an agent.
@@ -672,6 +903,15 @@ func (s *Service) GetAgentState(ctx context.Context, id string) (*AgentStateResu
 		QueueLength: agent.QueueLength,
 	}
 
+	if agent.RemoteAgentID != "" {
+		output, err := s.capturePane(ctx, agent, false)
+		if err == nil {
+			result.PaneActive = true
+			result.LastOutput = output
+		}
+		return result, nil
+	}
+
 	// Check if pane is active
 	if agent.TmuxPane != "" {
 		exists, err := s.paneExists(ctx, agent.TmuxPane)
@@ -690,8 +930,9 @@ func (s *Service) GetAgentState(ctx context.Context, id string) (*AgentStateResu
 }
 
 // InterruptAgent sends an interrupt signal to an agent.
-func (s *Service) InterruptAgent(ctx context.Context, id string) error {
-	s.logger.Debug().Str("agent_id", id).Msg("interrupting agent")
+func (s *Service) InterruptAgent(ctx context.Context, id string) (err error) {
+	defer func() { s.audit(ctx, AuditInterruptAgent, id, nil, err) }()
+	s.logger.Debug().Ctx(ctx).Str("agent_id", id).Msg("interrupting agent")
 
 	agent, err := s.GetAgent(ctx, id)
 	if err != nil {
@@ -703,7 +944,12 @@ func (s *Service) InterruptAgent(ctx context.Context, id string) error {
 	}
 
 	// Send Ctrl+C to the pane
-	if err := s.tmuxClient.SendInterrupt(ctx, agent.TmuxPane); err != nil {
+	if agent.RemoteAgentID != "" {
+		err = s.sendRemoteInput(ctx, agent, &swarmdv1.SendInputRequest{Keys: []string{"C-c"}})
+	} else {
+		err = s.tmuxClient.SendInterrupt(ctx, agent.TmuxPane)
+	}
+	if err != nil {
 		return fmt.Errorf("%w: %v", ErrInterruptFailed, err)
 	}
 
@@ -719,16 +965,23 @@ func (s *Service) InterruptAgent(ctx context.Context, id string) error {
 	agent.LastActivity = &now
 
 	if err := s.repo.Update(ctx, agent); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", id).Msg("failed to update agent state after interrupt")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Msg("failed to update agent state after interrupt")
 	}
 
-	s.logger.Info().Str("agent_id", id).Msg("agent interrupted")
+	s.logger.Info().Ctx(ctx).Str("agent_id", id).Msg("agent interrupted")
 	return nil
 }
 
 // RestartAgent restarts an agent by terminating and respawning it.
-func (s *Service) RestartAgent(ctx context.Context, id string) (*models.Agent, error) {
-	s.logger.Debug().Str("agent_id", id).Msg("restarting agent")
+func (s *Service) RestartAgent(ctx context.Context, id string) (restarted *models.Agent, err error) {
+	defer func() {
+		var newID string
+		if restarted != nil {
+			newID = restarted.ID
+		}
+		s.audit(ctx, AuditRestartAgent, id, audit.Params("new_agent_id", newID), err)
+	}()
+	s.logger.Debug().Ctx(ctx).Str("agent_id", id).Msg("restarting agent")
 
 	// Get current agent
 	agent, err := s.GetAgent(ctx, id)
@@ -737,17 +990,29 @@ func (s *Service) RestartAgent(ctx context.Context, id string) (*models.Agent, e
 	}
 
 	// Remember spawn options
-	opts := SpawnOptions{
-		WorkspaceID:    agent.WorkspaceID,
-		Type:           agent.Type,
-		AccountID:      agent.AccountID,
-		Environment:    agent.Metadata.Environment,
-		ApprovalPolicy: agent.Metadata.ApprovalPolicy,
+	opts := splitEnvironment(agent.Metadata)
+	opts.WorkspaceID = agent.WorkspaceID
+	opts.Type = agent.Type
+	opts.AccountID = agent.AccountID
+	opts.ApprovalPolicy = agent.Metadata.ApprovalPolicy
+	opts.Model = agent.Metadata.Model
+	opts.Tags = agent.Metadata.Tags
+	opts.QuietHours = agent.Metadata.QuietHours
+
+	// Probe the CLI afresh, so the new agent runs what is installed now.
+	if s.probe != nil {
+		if ws, err := s.workspaceService.GetWorkspace(ctx, agent.WorkspaceID); err == nil {
+			s.probe.Invalidate(ws.NodeID, agent.Type)
+		}
 	}
 
 	// Terminate the existing agent
-	if err := s.TerminateAgent(ctx, id); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", id).Msg("failed to terminate agent during restart")
+	if _, err := s.TerminateAgent(ctx, id, TerminateOptions{}); err != nil {
+		// A surviving pane would run alongside its replacement.
+		if errors.Is(err, tmux.ErrPaneStillAlive) {
+			return nil, err
+		}
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Msg("failed to terminate agent during restart")
 	}
 
 	// Spawn a new agent with the same options
@@ -757,6 +1022,7 @@ func (s *Service) RestartAgent(ctx context.Context, id string) (*models.Agent, e
 	}
 
 	s.logger.Info().
+		Ctx(ctx).
 		Str("old_agent_id", id).
 		Str("new_agent_id", newAgent.ID).
 		Msg("agent restarted")
@@ -767,11 +1033,24 @@ func (s *Service) RestartAgent(ctx context.Context, id string) (*models.Agent, e
 	return newAgent, nil
 }
 
-// RestartAgentWithAccount restarts an agent using a new account without clearing the queue.
-func (s *Service) RestartAgentWithAccount(ctx context.Context, id, accountID string) (*models.Agent, error) {
+// RestartAgentWithAccount restarts an agent using a new account without
+// clearing the queue. With dryRun, the agent, its workspace, and the
+// account's credentials are checked and the agent is returned as it is.
+func (s *Service) RestartAgentWithAccount(ctx context.Context, id, accountID string, dryRun bool) (restarted *models.Agent, err error) {
+	if !dryRun {
+		defer func() {
+			var newID string
+			if restarted != nil {
+				newID = restarted.ID
+			}
+			s.audit(ctx, AuditRestartAgent, id, audit.Params("account_id", accountID, "new_agent_id", newID), err)
+		}()
+	}
 	s.logger.Debug().
+		Ctx(ctx).
 		Str("agent_id", id).
 		Str("account_id", accountID).
+		Bool("dry_run", dryRun).
 		Msg("restarting agent with new account")
 
 	if accountID == "" {
@@ -782,6 +1061,9 @@ func (s *Service) RestartAgentWithAccount(ctx context.Context, id, accountID str
 	if err != nil {
 		return nil, err
 	}
+	if agent.RemoteAgentID != "" {
+		return nil, fmt.Errorf("account rotation is %w", ErrRemoteAgent)
+	}
 
 	ws, err := s.workspaceService.GetWorkspace(ctx, agent.WorkspaceID)
 	if err != nil {
@@ -793,39 +1075,35 @@ func (s *Service) RestartAgentWithAccount(ctx context.Context, id, accountID str
 
 	workDir := ws.RepoPath
 
-	env := agent.Metadata.Environment
+	opts := splitEnvironment(agent.Metadata)
+	var credEnv map[string]string
 	if s.accountService != nil {
-		credEnv, err := s.accountService.GetCredentialEnv(ctx, accountID)
+		credEnv, err = s.accountService.GetCredentialEnv(ctx, accountID)
 		if err != nil {
-			s.logger.Warn().Err(err).
+			s.logger.Warn().Ctx(ctx).Err(err).
 				Str("account_id", accountID).
 				Msg("failed to resolve account credentials, continuing without injection")
 		} else if len(credEnv) > 0 {
-			env = account.MergeEnv(env, credEnv)
 			s.logger.Debug().
+				Ctx(ctx).
 				Str("account_id", accountID).
 				Int("env_vars", len(credEnv)).
 				Msg("injected account credentials")
 		}
 	}
+	if dryRun {
+		return agent, nil
+	}
 
 	if agent.TmuxPane != "" {
 		_ = s.tmuxClient.SendInterrupt(ctx, agent.TmuxPane)
 		time.Sleep(100 * time.Millisecond)
 		if err := s.tmuxClient.KillPane(ctx, agent.TmuxPane); err != nil {
-			s.logger.Warn().Err(err).Str("agent_id", id).Msg("failed to kill pane during restart")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Msg("failed to kill pane during restart")
 		}
 	}
 
-	splitTarget := ws.TmuxSession
-	if ws.TmuxSession != "" {
-		splitTarget = fmt.Sprintf("%s:%s", ws.TmuxSession, tmux.AgentWindowName)
-	}
-	paneID, err := s.tmuxClient.SplitWindow(ctx, splitTarget, false, workDir)
-	if err != nil && splitTarget != ws.TmuxSession {
-		s.logger.Debug().Err(err).Str("target", splitTarget).Msg("failed to split agents window, falling back to session")
-		paneID, err = s.tmuxClient.SplitWindow(ctx, ws.TmuxSession, false, workDir)
-	}
+	paneID, err := s.splitAgentPane(ctx, ws, workDir)
 	if err != nil {
 		s.markAgentError(ctx, agent, err.Error(), models.StateConfidenceLow, nil)
 		return nil, fmt.Errorf("%w: failed to create pane: %v", ErrSpawnFailed, err)
@@ -836,6 +1114,7 @@ func (s *Service) RestartAgentWithAccount(ctx context.Context, id, accountID str
 
 	now := time.Now().UTC()
 	agent.TmuxPane = paneTarget
+	agent.TmuxSession = ws.TmuxSession
 	agent.AccountID = accountID
 	agent.State = models.AgentStateStarting
 	agent.StateInfo = models.StateInfo{
@@ -844,7 +1123,9 @@ func (s *Service) RestartAgentWithAccount(ctx context.Context, id, accountID str
 		Reason:     "Agent restarting with new account",
 		DetectedAt: now,
 	}
-	agent.Metadata.Environment = env
+	env, resolvedEnv := ResolveEnvironment(spawnEnvLayers(opts, credEnv)...)
+	agent.Metadata.Environment = withoutCredentials(env, resolvedEnv)
+	agent.Metadata.ResolvedEnv = resolvedEnv
 	agent.LastActivity = &now
 
 	if err := s.repo.Update(ctx, agent); err != nil {
@@ -853,41 +1134,43 @@ func (s *Service) RestartAgentWithAccount(ctx context.Context, id, accountID str
 	}
 
 	if err := s.paneMap.Register(agent.ID, paneID, paneTarget); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to register pane mapping")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to register pane mapping")
 	}
 
-	opts := SpawnOptions{
+	opts = SpawnOptions{
 		WorkspaceID:    agent.WorkspaceID,
 		Type:           agent.Type,
 		AccountID:      accountID,
 		Environment:    env,
 		WorkingDir:     workDir,
 		ApprovalPolicy: agent.Metadata.ApprovalPolicy,
+		Model:          agent.Metadata.Model,
 	}
 
 	startCmd := s.buildStartCommand(opts)
 	if startCmd != "" {
 		if err := s.tmuxClient.SendKeys(ctx, paneTarget, startCmd, true, true); err != nil {
 			spawnErr := fmt.Errorf("failed to send start command: %w", err)
-			s.logger.Warn().Err(spawnErr).Str("agent_id", agent.ID).Msg("agent restart failed")
+			s.logger.Warn().Ctx(ctx).Err(spawnErr).Str("agent_id", agent.ID).Msg("agent restart failed")
 			s.markAgentError(ctx, agent, spawnErr.Error(), models.StateConfidenceLow, nil)
 			s.cleanupRestartFailure(ctx, agent)
 			return nil, fmt.Errorf("%w: %v", ErrSpawnFailed, spawnErr)
 		}
 		agent.Metadata.StartCommand = startCmd
 		if err := s.repo.Update(ctx, agent); err != nil {
-			s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("failed to store restart start command")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to store restart start command")
 		}
 	}
 
 	if err := s.waitForReady(ctx, agent, opts); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", agent.ID).Msg("agent failed to reach ready state after restart")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("agent failed to reach ready state after restart")
 		s.markAgentError(ctx, agent, err.Error(), models.StateConfidenceLow, nil)
 		s.cleanupRestartFailure(ctx, agent)
 		return nil, fmt.Errorf("%w: %v", ErrSpawnFailed, err)
 	}
 
 	s.logger.Info().
+		Ctx(ctx).
 		Str("agent_id", agent.ID).
 		Str("account_id", accountID).
 		Msg("agent restarted with new account")
@@ -897,25 +1180,90 @@ func (s *Service) RestartAgentWithAccount(ctx context.Context, id, accountID str
 	return agent, nil
 }
 
-// TerminateAgent stops and removes an agent.
-func (s *Service) TerminateAgent(ctx context.Context, id string) error {
-	s.logger.Debug().Str("agent_id", id).Msg("terminating agent")
+// TerminateOptions controls how an agent is terminated.
+type TerminateOptions struct {
+	// Force kills a pane that survives kill-pane by its process and then by
+	// its whole window.
+	Force bool
 
-	// Stop SSE event watcher first
-	s.stopEventWatcher(id)
+	// DryRun resolves the agent and returns the plan without stopping it.
+	DryRun bool
+}
+
+// TerminatePlan describes what terminating an agent does.
+type TerminatePlan struct {
+	AgentID     string `json:"agent_id"`
+	WorkspaceID string `json:"workspace_id"`
+	// Pane is the tmux pane that is killed; empty for remote agents.
+	Pane string `json:"pane,omitempty"`
+	// RemoteAgentID is the swarmd agent that is killed instead of a pane.
+	RemoteAgentID string `json:"remote_agent_id,omitempty"`
+	// QueueItems is the number of pending queue items cleared.
+	QueueItems  int  `json:"queue_items"`
+	ArchiveLogs bool `json:"archive_logs"`
+	Force       bool `json:"force"`
+	DryRun      bool `json:"dry_run"`
+}
+
+// TerminateAgent stops and removes an agent. The agent record is deleted
+// only once its pane is confirmed gone; if the pane survives, the agent is
+// marked as errored and the returned error wraps tmux.ErrPaneStillAlive.
+// The plan is returned on success, and is all that happens with
+// opts.DryRun.
+func (s *Service) TerminateAgent(ctx context.Context, id string, opts TerminateOptions) (_ *TerminatePlan, err error) {
+	if !opts.DryRun {
+		defer func() { s.audit(ctx, AuditKillAgent, id, audit.Params("force", boolParam(opts.Force)), err) }()
+	}
+	s.logger.Debug().Ctx(ctx).Str("agent_id", id).Bool("force", opts.Force).Bool("dry_run", opts.DryRun).Msg("terminating agent")
 
 	agent, err := s.GetAgent(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	archiveEnabled := s.archiveEnabled()
+	plan := &TerminatePlan{
+		AgentID:       agent.ID,
+		WorkspaceID:   agent.WorkspaceID,
+		RemoteAgentID: agent.RemoteAgentID,
+		ArchiveLogs:   archiveEnabled,
+		Force:         opts.Force,
+		DryRun:        opts.DryRun,
+	}
+	if agent.RemoteAgentID == "" {
+		plan.Pane = agent.TmuxPane
+	}
+	if s.queueRepo != nil {
+		pending, err := s.queueRepo.Count(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count queue items: %w", err)
+		}
+		plan.QueueItems = pending
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+
 	var transcript string
 	var transcriptAt time.Time
 	var transcriptErr error
 
 	// Send interrupt first to gracefully stop
-	if agent.TmuxPane != "" {
+	if agent.RemoteAgentID != "" {
+		_ = s.sendRemoteInput(ctx, agent, &swarmdv1.SendInputRequest{Keys: []string{"C-c"}})
+		time.Sleep(100 * time.Millisecond)
+
+		if archiveEnabled {
+			transcriptAt = time.Now().UTC()
+			transcript, transcriptErr = s.capturePane(ctx, agent, true)
+		}
+
+		if err := s.killRemoteAgent(ctx, agent, opts.Force); err != nil {
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Bool("force", opts.Force).Msg("remote agent survived termination")
+			s.markAgentError(ctx, agent, fmt.Sprintf("terminate failed: %v", err), models.StateConfidenceHigh, nil)
+			return nil, fmt.Errorf("%w: %w", ErrTerminateFailed, err)
+		}
+	} else if agent.TmuxPane != "" {
 		if s.tmuxClient == nil {
 			if archiveEnabled {
 				transcriptErr = errors.New("tmux client not configured")
@@ -929,54 +1277,60 @@ func (s *Service) TerminateAgent(ctx context.Context, id string) error {
 			transcript, transcriptAt, transcriptErr = s.captureTranscript(ctx, agent.TmuxPane)
 		}
 
-		// Kill the pane
+		_ = s.stopPipe(ctx, agent)
+
+		// Kill the pane, keeping the record if the process survives so it
+		// is never left running with nothing pointing at it.
 		if s.tmuxClient != nil {
-			if err := s.tmuxClient.KillPane(ctx, agent.TmuxPane); err != nil {
-				s.logger.Warn().Err(err).Str("agent_id", id).Msg("failed to kill pane")
+			if err := s.tmuxClient.KillPaneVerified(ctx, agent.TmuxPane, opts.Force); err != nil {
+				s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Bool("force", opts.Force).Msg("agent pane survived termination")
+				s.markAgentError(ctx, agent, fmt.Sprintf("terminate failed: %v", err), models.StateConfidenceHigh, nil)
+				return nil, fmt.Errorf("%w: %w", ErrTerminateFailed, err)
 			}
 		}
 	}
 
+	s.stopEventWatcher(id)
 	s.archiveAgentLogs(ctx, agent, transcript, transcriptAt, transcriptErr)
 
 	// Clear the agent's queue
 	if s.queueRepo != nil {
 		cleared, err := s.queueRepo.Clear(ctx, id)
 		if err != nil {
-			s.logger.Warn().Err(err).Str("agent_id", id).Msg("failed to clear queue")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Msg("failed to clear queue")
 		} else if cleared > 0 {
-			s.logger.Debug().Int("cleared", cleared).Str("agent_id", id).Msg("cleared queue items")
+			s.logger.Debug().Ctx(ctx).Int("cleared", cleared).Str("agent_id", id).Msg("cleared queue items")
 		}
 	}
 
 	// Unregister pane mapping
 	if err := s.paneMap.UnregisterAgent(id); err != nil && !errors.Is(err, ErrAgentNotFound) {
-		s.logger.Warn().Err(err).Str("agent_id", id).Msg("failed to unregister pane mapping")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Msg("failed to unregister pane mapping")
 	}
 
 	// Release allocated port for OpenCode agents
 	if s.portRepo != nil && agent.Metadata.OpenCode != nil && agent.Metadata.OpenCode.Port > 0 {
 		if released, err := s.portRepo.ReleaseByAgent(ctx, id); err != nil {
-			s.logger.Warn().Err(err).Str("agent_id", id).Msg("failed to release port on termination")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Msg("failed to release port on termination")
 		} else if released > 0 {
-			s.logger.Debug().Str("agent_id", id).Int("released", released).Msg("released port allocation")
+			s.logger.Debug().Ctx(ctx).Str("agent_id", id).Int("released", released).Msg("released port allocation")
 		}
 	}
 
-	// Delete agent from database
+	// Mark the agent terminated; the row stays for history until pruned
 	if err := s.repo.Delete(ctx, id); err != nil {
 		if errors.Is(err, db.ErrAgentNotFound) {
-			return ErrServiceAgentNotFound
+			return nil, ErrServiceAgentNotFound
 		}
-		return fmt.Errorf("%w: %v", ErrTerminateFailed, err)
+		return nil, fmt.Errorf("%w: %v", ErrTerminateFailed, err)
 	}
 
-	s.logger.Info().Str("agent_id", id).Msg("agent terminated")
+	s.logger.Info().Ctx(ctx).Str("agent_id", id).Msg("agent terminated")
 
 	// Emit event
 	s.publishEvent(ctx, models.EventTypeAgentTerminated, id, nil)
 
-	return nil
+	return plan, nil
 }
 
 // UpdateAgentState updates an agent's state.
@@ -999,24 +1353,31 @@ func (s *Service) UpdateAgentState(ctx context.Context, id string, state models.
 	return s.repo.Update(ctx, agent)
 }
 
-// PauseAgent pauses an agent for a duration.
-func (s *Service) PauseAgent(ctx context.Context, id string, duration time.Duration) error {
+// PauseAgent pauses an agent for a duration. A duration of zero or less
+// pauses it until ResumeAgent is called.
+func (s *Service) PauseAgent(ctx context.Context, id string, duration time.Duration) (err error) {
+	defer func() { s.audit(ctx, AuditPauseAgent, id, audit.Params("duration", duration.String()), err) }()
 	agent, err := s.GetAgent(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	now := time.Now().UTC()
-	pausedUntil := now.Add(duration)
+	reason := "Paused until resumed"
+	agent.PausedUntil = nil
+	if duration > 0 {
+		pausedUntil := now.Add(duration)
+		reason = fmt.Sprintf("Paused until %s", pausedUntil.Format(time.RFC3339))
+		agent.PausedUntil = &pausedUntil
+	}
 
 	agent.State = models.AgentStatePaused
 	agent.StateInfo = models.StateInfo{
 		State:      models.AgentStatePaused,
 		Confidence: models.StateConfidenceHigh,
-		Reason:     fmt.Sprintf("Paused until %s", pausedUntil.Format(time.RFC3339)),
+		Reason:     reason,
 		DetectedAt: now,
 	}
-	agent.PausedUntil = &pausedUntil
 
 	if err := s.repo.Update(ctx, agent); err != nil {
 		return err
@@ -1029,7 +1390,8 @@ func (s *Service) PauseAgent(ctx context.Context, id string, duration time.Durat
 }
 
 // ResumeAgent resumes a paused agent.
-func (s *Service) ResumeAgent(ctx context.Context, id string) error {
+func (s *Service) ResumeAgent(ctx context.Context, id string) (err error) {
+	defer func() { s.audit(ctx, AuditResumeAgent, id, nil, err) }()
 	agent, err := s.GetAgent(ctx, id)
 	if err != nil {
 		return err
@@ -1072,12 +1434,23 @@ func (s *Service) buildStartCommand(opts SpawnOptions) string {
 		InitialPrompt:  opts.InitialPrompt,
 		Environment:    opts.Environment,
 		ApprovalPolicy: opts.ApprovalPolicy,
+		Model:          opts.Model,
+		Resume:         opts.Resume,
 	})
 	if cmd == "" {
 		return ""
 	}
 
-	envPrefix := formatEnvPrefix(opts.Environment)
+	env := opts.Environment
+	if provider, ok := adapter.(adapters.ModelEnvProvider); ok && opts.Model != "" && provider.ModelEnvVar() != "" {
+		env = make(map[string]string, len(opts.Environment)+1)
+		for key, value := range opts.Environment {
+			env[key] = value
+		}
+		env[provider.ModelEnvVar()] = opts.Model
+	}
+
+	envPrefix := formatEnvPrefix(env)
 	return envPrefix + joinCommand(cmd, args)
 }
 
@@ -1096,7 +1469,7 @@ func formatEnvPrefix(env map[string]string) string {
 	for _, key := range keys {
 		builder.WriteString(key)
 		builder.WriteString("=")
-		builder.WriteString(shellEscape(env[key]))
+		builder.WriteString(shell.Quote(env[key]))
 		builder.WriteString(" ")
 	}
 	return builder.String()
@@ -1108,17 +1481,13 @@ func joinCommand(cmd string, args []string) string {
 	}
 
 	parts := make([]string, 0, len(args)+1)
-	parts = append(parts, shellEscape(cmd))
+	parts = append(parts, shell.Quote(cmd))
 	for _, arg := range args {
-		parts = append(parts, shellEscape(arg))
+		parts = append(parts, shell.Quote(arg))
 	}
 	return strings.Join(parts, " ")
 }
 
-func shellEscape(value string) string {
-	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", "'\\''"))
-}
-
 // GetPaneMap returns the pane mapping registry.
 func (s *Service) GetPaneMap() *PaneMap {
 	return s.paneMap
@@ -1142,6 +1511,11 @@ type SendMessageOptions struct {
 	// RetryBackoff is the initial backoff duration between retries.
 	// Doubles on each retry. Defaults to 100ms.
 	RetryBackoff time.Duration
+
+	// Sensitive marks the message as a secret, such as a token for a login
+	// prompt. It is never logged, and swarmd records a marker in the
+	// transcript instead of it and of the output that may echo it.
+	Sensitive bool
 }
 
 const (
@@ -1153,14 +1527,20 @@ const (
 // SendMessage sends a message to an agent.
 // By default, it verifies the agent is in an idle state before sending.
 // The message is sent via the adapter for proper formatting.
-func (s *Service) SendMessage(ctx context.Context, id, message string, opts *SendMessageOptions) error {
+func (s *Service) SendMessage(ctx context.Context, id, message string, opts *SendMessageOptions) (err error) {
 	if opts == nil {
 		opts = &SendMessageOptions{}
 	}
 
+	logged := message
+	if opts.Sensitive {
+		logged = "[sensitive input]"
+	}
+	defer func() { s.audit(ctx, AuditSendMessage, id, audit.Params("message", logged), err) }()
 	s.logger.Debug().
+		Ctx(ctx).
 		Str("agent_id", id).
-		Str("message", message).
+		Str("message", logged).
 		Msg("sending message to agent")
 
 	agent, err := s.GetAgent(ctx, id)
@@ -1168,6 +1548,13 @@ func (s *Service) SendMessage(ctx context.Context, id, message string, opts *Sen
 		return err
 	}
 
+	// A hibernated agent is woken to take the message.
+	if agent.State == models.AgentStateHibernated {
+		if agent, err = s.WakeAgent(ctx, id, WakeReasonMessage); err != nil {
+			return fmt.Errorf("failed to wake agent: %w", err)
+		}
+	}
+
 	// Verify agent is idle (unless skipped)
 	if !opts.SkipIdleCheck && agent.State != models.AgentStateIdle {
 		return fmt.Errorf("%w: current state is %s", ErrAgentNotIdle, agent.State)
@@ -1189,7 +1576,7 @@ func (s *Service) SendMessage(ctx context.Context, id, message string, opts *Sen
 	agent.LastActivity = &now
 
 	if err := s.repo.Update(ctx, agent); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", id).Msg("failed to update agent state before send")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", id).Msg("failed to update agent state before send")
 	}
 
 	// Send the message via tmux with optional retry
@@ -1211,19 +1598,23 @@ func (s *Service) SendMessage(ctx context.Context, id, message string, opts *Sen
 			break
 		}
 
-		// Check if pane is still active
-		paneActive, err := s.paneExists(ctx, agent.TmuxPane)
-		if err != nil {
-			lastErr = err
-			break
-		}
-		if !paneActive {
-			lastErr = fmt.Errorf("pane is dead or inaccessible")
-			break // Don't retry if pane is dead
+		// Check if pane is still active; the daemon checks its own panes
+		if agent.RemoteAgentID == "" {
+			paneActive, err := s.paneExists(ctx, agent.TmuxPane)
+			if err != nil {
+				lastErr = err
+				break
+			}
+			if !paneActive {
+				lastErr = fmt.Errorf("pane is dead or inaccessible")
+				break // Don't retry if pane is dead
+			}
 		}
 
 		// Send the message
-		if strings.Contains(message, "\n") || strings.Contains(message, "\r") {
+		if agent.RemoteAgentID != "" {
+			lastErr = s.sendRemoteMessage(ctx, agent, message, opts.Sensitive)
+		} else if strings.Contains(message, "\n") || strings.Contains(message, "\r") {
 			lastErr = s.sendMultilineMessage(ctx, agent.TmuxPane, message, opts)
 		} else if opts.WaitForStable {
 			stableRounds := opts.StableRounds
@@ -1242,6 +1633,7 @@ func (s *Service) SendMessage(ctx context.Context, id, message string, opts *Sen
 		// Log retry attempt
 		if attempt < maxAttempts {
 			s.logger.Warn().
+				Ctx(ctx).
 				Err(lastErr).
 				Str("agent_id", id).
 				Int("attempt", attempt).
@@ -1268,6 +1660,7 @@ func (s *Service) SendMessage(ctx context.Context, id, message string, opts *Sen
 	}
 
 	s.logger.Info().
+		Ctx(ctx).
 		Str("agent_id", id).
 		Msg("message sent to agent")
 
@@ -1335,16 +1728,30 @@ func sleepWithContext(ctx context.Context, delay time.Duration) bool {
 	}
 }
 
-// publishEvent publishes an event if a publisher is configured.
+// publishEvent publishes an agent event if a publisher is configured.
 func (s *Service) publishEvent(ctx context.Context, eventType models.EventType, agentID string, payload any) {
+	s.publishEntityEvent(ctx, eventType, models.EntityTypeAgent, agentID, payload)
+}
+
+// publishEntityEvent publishes an event about any entity if a publisher is
+// configured.
+func (s *Service) publishEntityEvent(ctx context.Context, eventType models.EventType, entityType models.EntityType, entityID string, payload any) {
 	if s.publisher == nil {
 		return
 	}
 
 	event := &models.Event{
 		Type:       eventType,
-		EntityType: models.EntityTypeAgent,
-		EntityID:   agentID,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			s.logger.Warn().Ctx(ctx).Err(err).Str("event_type", string(eventType)).Msg("failed to marshal event payload")
+		} else {
+			event.Payload = data
+		}
 	}
 
 	s.publisher.Publish(ctx, event)
@@ -1361,11 +1768,13 @@ func (s *Service) startEventWatcher(ctx context.Context, agent *models.Agent) {
 
 	if err := s.eventWatcher.WatchAgent(ctx, agent); err != nil {
 		s.logger.Warn().
+			Ctx(ctx).
 			Err(err).
 			Str("agent_id", agent.ID).
 			Msg("failed to start SSE event watcher")
 	} else {
 		s.logger.Debug().
+			Ctx(ctx).
 			Str("agent_id", agent.ID).
 			Msg("started SSE event watcher for OpenCode agent")
 	}
@@ -0,0 +1,391 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+func TestBuildStartCommandPassesModel(t *testing.T) {
+	s := &Service{}
+
+	tests := []struct {
+		name      string
+		agentType models.AgentType
+		want      string
+	}{
+		{name: "claude flag", agentType: models.AgentTypeClaudeCode, want: "'--model' 'test-model'"},
+		{name: "codex flag", agentType: models.AgentTypeCodex, want: "'--model' 'test-model'"},
+		{name: "opencode flag", agentType: models.AgentTypeOpenCode, want: "'--model' 'test-model'"},
+		{name: "gemini env", agentType: models.AgentTypeGemini, want: "GEMINI_MODEL='test-model' "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := s.buildStartCommand(SpawnOptions{Type: tt.agentType, Model: "test-model"})
+			if !strings.Contains(cmd, tt.want) {
+				t.Fatalf("expected %q in start command, got %q", tt.want, cmd)
+			}
+		})
+	}
+
+	cmd := s.buildStartCommand(SpawnOptions{Type: models.AgentTypeClaudeCode})
+	if strings.Contains(cmd, "--model") {
+		t.Fatalf("expected no model flag without a model, got %q", cmd)
+	}
+}
+
+// TestBuildStartCommandGolden pins the start command of every built-in
+// adapter. The expected commands were recorded before the adapters
+// declared capabilities and must not change when they are refactored.
+func TestBuildStartCommandGolden(t *testing.T) {
+	s := &Service{}
+	all := SpawnOptions{Model: "m1", ApprovalPolicy: "permissive", Resume: true, Environment: map[string]string{"HOME": "/h"}}
+	variants := []SpawnOptions{
+		{},
+		{Model: "m1"},
+		{ApprovalPolicy: "permissive"},
+		{ApprovalPolicy: "strict"},
+		{ApprovalPolicy: "default"},
+		{Resume: true},
+		all,
+	}
+	golden := map[models.AgentType][]string{
+		models.AgentTypeOpenCode: {
+			`'opencode' '--hostname' '127.0.0.1'`,
+			`'opencode' '--hostname' '127.0.0.1' '--model' 'm1'`,
+			`'opencode' '--hostname' '127.0.0.1'`,
+			`'opencode' '--hostname' '127.0.0.1'`,
+			`'opencode' '--hostname' '127.0.0.1'`,
+			`'opencode' '--hostname' '127.0.0.1' '--continue'`,
+			`HOME='/h' 'opencode' '--hostname' '127.0.0.1' '--model' 'm1' '--continue'`,
+		},
+		models.AgentTypeClaudeCode: {
+			`'claude'`,
+			`'claude' '--model' 'm1'`,
+			`'claude' '--permission-mode' 'dontAsk'`,
+			`'claude'`,
+			`'claude'`,
+			`'claude' '--continue'`,
+			`HOME='/h' 'claude' '--model' 'm1' '--continue' '--permission-mode' 'dontAsk'`,
+		},
+		models.AgentTypeCodex: {
+			`'codex'`,
+			`'codex' '--model' 'm1'`,
+			`'codex' '--full-auto'`,
+			`'codex' '--ask-for-approval' 'untrusted'`,
+			`'codex' '--ask-for-approval' 'on-request'`,
+			`'codex' 'resume' '--last'`,
+			`HOME='/h' 'codex' 'resume' '--last' '--full-auto' '--model' 'm1'`,
+		},
+		models.AgentTypeGemini: {
+			`'gemini'`,
+			`GEMINI_MODEL='m1' 'gemini'`,
+			`'gemini' '--approval-mode' 'yolo'`,
+			`'gemini' '--approval-mode' 'default'`,
+			`'gemini' '--approval-mode' 'auto_edit'`,
+			`'gemini'`,
+			`GEMINI_MODEL='m1' HOME='/h' 'gemini' '--approval-mode' 'yolo'`,
+		},
+		models.AgentTypeGeneric: {"", "", "", "", "", "", ""},
+	}
+
+	for agentType, want := range golden {
+		for i, opts := range variants {
+			opts.Type = agentType
+			if got := s.buildStartCommand(opts); got != want[i] {
+				t.Errorf("%s %+v: start command %q, want %q", agentType, opts, got, want[i])
+			}
+		}
+	}
+}
+
+// TestSpawnToyAdapter registers a new CLI with one call and spawns it: the
+// start command, the probe, and readiness all come from the registration.
+func TestSpawnToyAdapter(t *testing.T) {
+	t.Cleanup(adapters.RegisterAdapter(adapters.NewGenericAdapter("toy", "toy-cli --fast",
+		adapters.WithModelFlag("-m"),
+		adapters.WithIdleIndicators("toy>"),
+	)))
+
+	var gotArgs []string
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("toy-cli", func(term *tmuxtest.Terminal) {
+		gotArgs = term.Args()
+		term.Print("toy> ")
+	}))
+	env.service.probe = NewSpawnProbe(&probeExec{outputs: map[string]string{"toy-cli": "toy-cli 2.1.0"}})
+
+	agent, err := env.service.SpawnAgent(context.Background(), SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              "toy",
+		Model:             "big",
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	if strings.Join(gotArgs, " ") != "--fast -m big" {
+		t.Fatalf("unexpected toy args %v", gotArgs)
+	}
+	if agent.Metadata.CLIVersion != "2.1.0" {
+		t.Fatalf("expected the toy's version probed, got %q", agent.Metadata.CLIVersion)
+	}
+}
+
+// spawnTestEnv is an agent service whose workspace session lives on a fake
+// tmux server.
+type spawnTestEnv struct {
+	service     *Service
+	tmux        *tmuxtest.Server
+	database    *db.DB
+	nodeID      string
+	workspaceID string
+}
+
+func newSpawnTestEnv(t *testing.T, opts ...tmuxtest.Option) *spawnTestEnv {
+	t.Helper()
+
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := nodeRepo.Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	wsRepo := db.NewWorkspaceRepository(database)
+	ws := &models.Workspace{NodeID: localNode.ID, Name: "ws", RepoPath: "/repo", TmuxSession: "ws"}
+	if err := wsRepo.Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	srv := tmuxtest.NewServer(opts...)
+	for _, args := range [][]string{
+		{"new-session", "-d", "-s", "ws", "-c", "/repo"},
+		{"new-window", "-t", "ws", "-n", tmux.AgentWindowName, "-c", "/repo"},
+	} {
+		if _, err := srv.Run(args...); err != nil {
+			t.Fatalf("failed to seed tmux: %v", err)
+		}
+	}
+
+	agentRepo := db.NewAgentRepository(database)
+	wsService := workspace.NewService(wsRepo, node.NewService(nodeRepo), agentRepo)
+	service := NewService(agentRepo, db.NewQueueRepository(database), wsService, nil, tmux.NewClient(srv))
+	return &spawnTestEnv{service: service, tmux: srv, database: database, nodeID: localNode.ID, workspaceID: ws.ID}
+}
+
+func (e *spawnTestEnv) panes(t *testing.T) string {
+	t.Helper()
+	out, err := e.tmux.Run("list-panes", "-s", "-t", "ws", "-F", "#{pane_id}")
+	if err != nil {
+		t.Fatalf("failed to list panes: %v", err)
+	}
+	return strings.Join(strings.Fields(out), ",")
+}
+
+func TestSpawnAgentWaitsForReady(t *testing.T) {
+	var gotArgs []string
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		gotArgs = term.Args()
+		term.Print("Loading...\n")
+		term.PrintAfter(20*time.Millisecond, "claude> ")
+	}))
+
+	agent, err := env.service.SpawnAgent(context.Background(), SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		Model:             "opus",
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	if agent.State != models.AgentStateIdle || agent.TmuxPane != "%2" {
+		t.Fatalf("expected idle agent in %%2, got %s in %q", agent.State, agent.TmuxPane)
+	}
+	if strings.Join(gotArgs, " ") != "--model opus" {
+		t.Fatalf("expected model flag to reach the CLI, got %v", gotArgs)
+	}
+	if got := env.panes(t); got != "%0,%1,%2" {
+		t.Fatalf("expected agent pane to stay open, got %s", got)
+	}
+}
+
+func TestSpawnAgentFailsWhenNeverReady(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", tmuxtest.Script(tmuxtest.Step{Output: "Loading..."})))
+
+	_, err := env.service.SpawnAgent(context.Background(), SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      50 * time.Millisecond,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrSpawnFailed) || !strings.Contains(err.Error(), "last output: Loading...") {
+		t.Fatalf("expected readiness timeout with last output, got %v", err)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected failed agent pane to be killed, got %s", got)
+	}
+}
+
+func TestSpawnAgentRejectsDrainingWorkspace(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", tmuxtest.Script(tmuxtest.Step{Output: "claude> "})))
+	if err := db.NewWorkspaceRepository(env.database).SetDraining(context.Background(), env.workspaceID, true); err != nil {
+		t.Fatalf("SetDraining failed: %v", err)
+	}
+
+	_, err := env.service.SpawnAgent(context.Background(), SpawnOptions{
+		WorkspaceID: env.workspaceID,
+		Type:        models.AgentTypeClaudeCode,
+	})
+	if !errors.Is(err, ErrWorkspaceDraining) {
+		t.Fatalf("expected ErrWorkspaceDraining, got %v", err)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected no pane to be created, got %s", got)
+	}
+}
+
+func TestSpawnAgentFailsOnErrorBeforeReady(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("Error: invalid API key\n")
+		term.Exit()
+	}))
+
+	_, err := env.service.SpawnAgent(context.Background(), SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrSpawnFailed) || !strings.Contains(err.Error(), "agent reported error before ready") {
+		t.Fatalf("expected early error to fail the spawn, got %v", err)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected failed agent pane to be killed, got %s", got)
+	}
+}
+
+func TestTerminateAgentKeepsRecordWhenPaneSurvives(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	agent, err := env.service.SpawnAgent(ctx, SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+
+	env.tmux.FailCommand("kill-pane", "operation not permitted")
+	_, err = env.service.TerminateAgent(ctx, agent.ID, TerminateOptions{})
+	if !errors.Is(err, ErrTerminateFailed) || !errors.Is(err, tmux.ErrPaneStillAlive) {
+		t.Fatalf("expected ErrPaneStillAlive, got %v", err)
+	}
+	stored, err := env.service.GetAgent(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("expected the agent record to be kept, got %v", err)
+	}
+	if stored.State != models.AgentStateError || !strings.Contains(stored.StateInfo.Reason, "terminate failed") {
+		t.Fatalf("expected error state with the kill failure, got %s (%q)", stored.State, stored.StateInfo.Reason)
+	}
+	if got := env.panes(t); got != "%0,%1,%2" {
+		t.Fatalf("expected agent pane to survive, got %s", got)
+	}
+
+	// Force does not kill the window while other panes share it.
+	if _, err := env.service.TerminateAgent(ctx, agent.ID, TerminateOptions{Force: true}); !errors.Is(err, tmux.ErrPaneStillAlive) {
+		t.Fatalf("expected ErrPaneStillAlive with a shared window, got %v", err)
+	}
+	if got := env.panes(t); got != "%0,%1,%2" {
+		t.Fatalf("expected the shared window to survive, got %s", got)
+	}
+
+	// Alone in its window, force falls back to killing the window.
+	env.tmux.FailCommand("kill-pane", "")
+	if _, err := env.tmux.Run("kill-pane", "-t", "%1"); err != nil {
+		t.Fatalf("kill-pane failed: %v", err)
+	}
+	env.tmux.FailCommand("kill-pane", "operation not permitted")
+	if _, err := env.service.TerminateAgent(ctx, agent.ID, TerminateOptions{Force: true}); err != nil {
+		t.Fatalf("forced TerminateAgent failed: %v", err)
+	}
+	if _, err := env.service.GetAgent(ctx, agent.ID); !errors.Is(err, ErrServiceAgentNotFound) {
+		t.Fatalf("expected agent record removed, got %v", err)
+	}
+	if got := env.panes(t); got != "%0" {
+		t.Fatalf("expected agent window killed, got %s", got)
+	}
+}
+
+func TestTerminateAgentDryRun(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	agent, err := env.service.SpawnAgent(ctx, SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	item := &models.QueueItem{Type: models.QueueItemTypeMessage, Payload: json.RawMessage(`{"text":"hello"}`)}
+	if err := db.NewQueueRepository(env.database).Enqueue(ctx, agent.ID, item); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	commands := len(env.tmux.Commands())
+	plan, err := env.service.TerminateAgent(ctx, agent.ID, TerminateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry-run TerminateAgent failed: %v", err)
+	}
+	if !plan.DryRun || plan.Pane != agent.TmuxPane || plan.QueueItems != 1 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	if got := len(env.tmux.Commands()); got != commands {
+		t.Fatalf("expected no tmux commands on a dry run, got %v", env.tmux.Commands()[commands:])
+	}
+	if _, err := env.service.GetAgent(ctx, agent.ID); err != nil {
+		t.Fatalf("expected the agent kept on a dry run, got %v", err)
+	}
+
+	done, err := env.service.TerminateAgent(ctx, agent.ID, TerminateOptions{})
+	if err != nil {
+		t.Fatalf("TerminateAgent failed: %v", err)
+	}
+	done.DryRun = true
+	if *done != *plan {
+		t.Fatalf("expected the real run to match the plan, got %+v, planned %+v", done, plan)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected the planned pane killed, got %s", got)
+	}
+}
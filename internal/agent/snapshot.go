@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/snapshot"
+)
+
+// ErrSnapshotsDisabled is returned when no snapshot store is configured.
+var ErrSnapshotsDisabled = errors.New("snapshots are not configured")
+
+// SnapshotOptions controls a pane snapshot.
+type SnapshotOptions struct {
+	// History captures the pane's full scrollback instead of the visible screen.
+	History bool
+
+	// Note is stored with the snapshot.
+	Note string
+}
+
+// WithSnapshots enables pane snapshots stored in store.
+func WithSnapshots(store *snapshot.Store) ServiceOption {
+	return func(s *Service) {
+		s.snapshots = store
+	}
+}
+
+// Snapshots returns the configured snapshot store, or nil.
+func (s *Service) Snapshots() *snapshot.Store {
+	if s == nil {
+		return nil
+	}
+	return s.snapshots
+}
+
+// Snapshot captures an agent's pane into the snapshot store along with the
+// state detected from it, and records an agent.snapshot event referencing
+// the content hash.
+func (s *Service) Snapshot(ctx context.Context, id string, opts SnapshotOptions) (*snapshot.Snapshot, error) {
+	if s.snapshots == nil {
+		return nil, ErrSnapshotsDisabled
+	}
+	agent, err := s.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if agent.TmuxPane == "" {
+		return nil, fmt.Errorf("agent %s has no tmux pane", agent.ID)
+	}
+
+	content, err := s.capturePane(ctx, agent, opts.History)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture pane: %w", err)
+	}
+
+	state := agent.State
+	adapter := adapters.GetByAgentType(agent.Type)
+	if adapter == nil {
+		adapter = adapters.GenericFallbackAdapter()
+	}
+	if detected, _, err := adapter.DetectState(content, agent.Metadata); err == nil && detected != "" {
+		state = detected
+	}
+
+	snap := &snapshot.Snapshot{
+		AgentID:     agent.ID,
+		WorkspaceID: agent.WorkspaceID,
+		History:     opts.History,
+		State:       state,
+		Note:        opts.Note,
+	}
+	if err := s.snapshots.Save(snap, content); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("agent_id", agent.ID).
+		Str("snapshot_id", snap.ID).
+		Str("hash", snap.Hash).
+		Msg("agent pane snapshot saved")
+
+	s.publishEvent(ctx, models.EventTypeAgentSnapshot, agent.ID, models.AgentSnapshotPayload{
+		SnapshotID: snap.ID,
+		Hash:       snap.Hash,
+		State:      state,
+		Note:       opts.Note,
+	})
+	return snap, nil
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/snapshot"
+)
+
+func TestSnapshotAgentPane(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t)
+	agent := seedMoveAgent(t, env)
+	if err := env.tmux.Print(agent.TmuxPane, "claude> working on it\n"); err != nil {
+		t.Fatalf("failed to print: %v", err)
+	}
+
+	if _, err := env.service.Snapshot(ctx, agent.ID, SnapshotOptions{}); !errors.Is(err, ErrSnapshotsDisabled) {
+		t.Fatalf("expected ErrSnapshotsDisabled, got %v", err)
+	}
+
+	var payloads []models.AgentSnapshotPayload
+	publisher := events.NewInMemoryPublisher()
+	if err := publisher.Subscribe("snapshots", events.Filter{}, func(event *models.Event) {
+		if event.Type != models.EventTypeAgentSnapshot {
+			return
+		}
+		var payload models.AgentSnapshotPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			t.Errorf("failed to decode snapshot event: %v", err)
+		}
+		payloads = append(payloads, payload)
+	}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	store := snapshot.NewStore(t.TempDir())
+	WithPublisher(publisher)(env.service)
+	WithSnapshots(store)(env.service)
+
+	snap, err := env.service.Snapshot(ctx, agent.ID, SnapshotOptions{History: true, Note: "before refactor"})
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if snap.AgentID != agent.ID || snap.WorkspaceID != env.workspaceID || !snap.History || snap.Note != "before refactor" || snap.State == "" {
+		t.Fatalf("unexpected snapshot metadata %+v", snap)
+	}
+	data, err := os.ReadFile(store.BlobPath(snap.Hash))
+	if err != nil || !strings.Contains(string(data), "claude> working on it") {
+		t.Fatalf("expected the pane content in the blob, got %q (%v)", data, err)
+	}
+	if len(payloads) != 1 || payloads[0].SnapshotID != snap.ID || payloads[0].Hash != snap.Hash {
+		t.Fatalf("expected one agent.snapshot event referencing the hash, got %+v", payloads)
+	}
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrNoStandbyAgent is returned when a workspace has no idle standby agent
+// to claim.
+var ErrNoStandbyAgent = errors.New("no standby agent available")
+
+// WithStandbyReplenish calls fn with the workspace ID each time a standby
+// agent is claimed, so its pool can be refilled. fn must not block.
+func WithStandbyReplenish(fn func(workspaceID string)) ServiceOption {
+	return func(s *Service) {
+		s.replenish = fn
+	}
+}
+
+// SpawnStandby spawns an idle standby agent for a workspace's pool. It is
+// not given work until claimed with ClaimStandby.
+func (s *Service) SpawnStandby(ctx context.Context, workspaceID string, agentType models.AgentType, model string) (*models.Agent, error) {
+	return s.SpawnAgent(ctx, SpawnOptions{
+		WorkspaceID: workspaceID,
+		Type:        agentType,
+		Model:       model,
+		Standby:     true,
+	})
+}
+
+// ClaimStandby takes an idle standby agent of agentType (any type when
+// empty) in a workspace for incoming work. The claim is atomic, so two
+// callers never get the same agent. The workspace's pool is refilled in
+// the background.
+func (s *Service) ClaimStandby(ctx context.Context, workspaceID string, agentType models.AgentType) (*models.Agent, error) {
+	agent, err := s.repo.ClaimStandby(ctx, workspaceID, agentType)
+	if err != nil {
+		if errors.Is(err, db.ErrNoStandbyAgent) {
+			return nil, ErrNoStandbyAgent
+		}
+		return nil, fmt.Errorf("failed to claim standby agent: %w", err)
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("agent_id", agent.ID).
+		Str("workspace_id", workspaceID).
+		Str("type", string(agent.Type)).
+		Msg("standby agent claimed")
+
+	s.publishEvent(ctx, models.EventTypeAgentClaimed, agent.ID, models.AgentClaimedPayload{
+		WorkspaceID: workspaceID,
+		Type:        agent.Type,
+	})
+	if s.replenish != nil {
+		s.replenish(workspaceID)
+	}
+	return agent, nil
+}
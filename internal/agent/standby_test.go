@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func TestSpawnAndClaimStandby(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+
+	var replenished []string
+	WithStandbyReplenish(func(workspaceID string) {
+		replenished = append(replenished, workspaceID)
+	})(env.service)
+	var claimed []string
+	publisher := events.NewInMemoryPublisher()
+	if err := publisher.Subscribe("claims", events.Filter{}, func(event *models.Event) {
+		if event.Type == models.EventTypeAgentClaimed {
+			claimed = append(claimed, event.EntityID)
+		}
+	}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	WithPublisher(publisher)(env.service)
+
+	standby, err := env.service.SpawnStandby(ctx, env.workspaceID, models.AgentTypeClaudeCode, "")
+	if err != nil {
+		t.Fatalf("SpawnStandby failed: %v", err)
+	}
+	stored, err := db.NewAgentRepository(env.database).Get(ctx, standby.ID)
+	if err != nil {
+		t.Fatalf("failed to get agent: %v", err)
+	}
+	if !stored.Standby || stored.State != models.AgentStateIdle {
+		t.Fatalf("expected an idle standby agent, got standby=%v state=%s", stored.Standby, stored.State)
+	}
+
+	if _, err := env.service.ClaimStandby(ctx, env.workspaceID, models.AgentTypeCodex); !errors.Is(err, ErrNoStandbyAgent) {
+		t.Fatalf("expected ErrNoStandbyAgent for another type, got %v", err)
+	}
+	got, err := env.service.ClaimStandby(ctx, env.workspaceID, models.AgentTypeClaudeCode)
+	if err != nil {
+		t.Fatalf("ClaimStandby failed: %v", err)
+	}
+	if got.ID != standby.ID || got.Standby {
+		t.Fatalf("expected %s to be claimed, got %s (standby=%v)", standby.ID, got.ID, got.Standby)
+	}
+	if len(claimed) != 1 || claimed[0] != standby.ID {
+		t.Fatalf("expected one agent.standby_claimed event, got %v", claimed)
+	}
+	if len(replenished) != 1 || replenished[0] != env.workspaceID {
+		t.Fatalf("expected the workspace pool to be replenished, got %v", replenished)
+	}
+}
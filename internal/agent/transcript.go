@@ -0,0 +1,214 @@
+package agent
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrNoArchivedTranscript is returned for a terminated agent without an
+// archive holding its transcript.
+var ErrNoArchivedTranscript = errors.New("no archived transcript for agent")
+
+// Transcript sources reported by TranscriptRecord.Source.
+const (
+	TranscriptSourceDaemon  = "daemon"
+	TranscriptSourceArchive = "archive"
+	TranscriptSourcePane    = "pane"
+)
+
+// transcriptPageSize is how many entries are requested from swarmd at a time.
+const transcriptPageSize = 500
+
+// TranscriptRecord is one transcript entry. Transcripts read from an
+// archive or a pane capture have one record per line.
+type TranscriptRecord struct {
+	Source    string            `json:"source"`
+	ID        int64             `json:"id,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+	Type      string            `json:"type,omitempty"`
+	Content   string            `json:"content"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// TranscriptIterator yields an agent's transcript oldest first, a page at a
+// time.
+type TranscriptIterator struct {
+	fetch   func() ([]*TranscriptRecord, bool, error)
+	page    []*TranscriptRecord
+	pos     int
+	current *TranscriptRecord
+	done    bool
+	err     error
+}
+
+// Next advances to the next record, fetching a new page when needed.
+func (it *TranscriptIterator) Next() bool {
+	for it.err == nil {
+		if it.pos < len(it.page) {
+			it.current = it.page[it.pos]
+			it.pos++
+			return true
+		}
+		if it.done {
+			return false
+		}
+		page, more, err := it.fetch()
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.pos, it.done = page, 0, !more
+	}
+	return false
+}
+
+// Record returns the current record.
+func (it *TranscriptIterator) Record() *TranscriptRecord {
+	return it.current
+}
+
+// Err returns the error that stopped iteration, if any.
+func (it *TranscriptIterator) Err() error {
+	return it.err
+}
+
+// Transcript returns an iterator over an agent's full transcript. A live
+// swarmd agent's transcript is paged from its daemon, a terminated agent's
+// is read from its latest archive, and otherwise the pane's scrollback is
+// captured from tmux.
+func (s *Service) Transcript(ctx context.Context, agent *models.Agent) (*TranscriptIterator, error) {
+	if agent.IsTerminated() {
+		return s.archivedTranscript(agent.ID)
+	}
+
+	if agent.RemoteAgentID != "" {
+		client, err := s.daemonForAgent(ctx, agent)
+		if err != nil {
+			return nil, err
+		}
+		cursor := ""
+		return &TranscriptIterator{fetch: func() ([]*TranscriptRecord, bool, error) {
+			resp, err := client.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{
+				AgentId: agent.RemoteAgentID,
+				Limit:   transcriptPageSize,
+				Cursor:  cursor,
+			})
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to get transcript: %w", err)
+			}
+			records := make([]*TranscriptRecord, 0, len(resp.GetEntries()))
+			for _, entry := range resp.GetEntries() {
+				records = append(records, &TranscriptRecord{
+					Source:    TranscriptSourceDaemon,
+					ID:        entry.GetId(),
+					Timestamp: entry.GetTimestamp().AsTime(),
+					Type:      transcriptEntryTypeName(entry.GetType()),
+					Content:   entry.GetContent(),
+					Metadata:  entry.GetMetadata(),
+				})
+			}
+			cursor = resp.GetNextCursor()
+			return records, resp.GetHasMore() && cursor != "", nil
+		}}, nil
+	}
+
+	if agent.TmuxPane == "" {
+		return nil, fmt.Errorf("agent %s has no tmux pane", agent.ID)
+	}
+	content, capturedAt, err := s.captureTranscript(ctx, agent.TmuxPane)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture pane: %w", err)
+	}
+	return lineTranscript(TranscriptSourcePane, content, capturedAt), nil
+}
+
+// archivedTranscript reads the transcript kept by the newest archive of
+// agentID.
+func (s *Service) archivedTranscript(agentID string) (*TranscriptIterator, error) {
+	if !s.archiveEnabled() {
+		return nil, fmt.Errorf("%w %s: agent archives are not configured", ErrNoArchivedTranscript, agentID)
+	}
+	dir := filepath.Join(s.archiveDir, agentID)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, "archive-") && (strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.gz")) {
+			names = append(names, name)
+		}
+	}
+	// Archive names start with their timestamp, so the newest sorts last.
+	sort.Strings(names)
+	for i := len(names) - 1; i >= 0; i-- {
+		payload, err := readArchive(filepath.Join(dir, names[i]))
+		if err != nil {
+			return nil, err
+		}
+		if payload.Transcript != nil && payload.Transcript.Content != "" {
+			return lineTranscript(TranscriptSourceArchive, payload.Transcript.Content, payload.Transcript.CapturedAt), nil
+		}
+	}
+	return nil, fmt.Errorf("%w %s", ErrNoArchivedTranscript, agentID)
+}
+
+func readArchive(path string) (*agentArchive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", filepath.Base(path), err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var payload agentArchive
+	if err := json.NewDecoder(r).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", filepath.Base(path), err)
+	}
+	return &payload, nil
+}
+
+// lineTranscript yields captured pane content one line per record.
+func lineTranscript(source, content string, capturedAt time.Time) *TranscriptIterator {
+	if content == "" {
+		return &TranscriptIterator{done: true}
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	records := make([]*TranscriptRecord, 0, len(lines))
+	for _, line := range lines {
+		records = append(records, &TranscriptRecord{Source: source, Timestamp: capturedAt, Content: line})
+	}
+	return &TranscriptIterator{page: records, done: true}
+}
+
+// transcriptEntryTypeName returns the lower-case name of a transcript entry
+// type, e.g. "output" for TRANSCRIPT_ENTRY_TYPE_OUTPUT.
+func transcriptEntryTypeName(t swarmdv1.TranscriptEntryType) string {
+	if t == swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_UNSPECIFIED {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(t.String(), "TRANSCRIPT_ENTRY_TYPE_"))
+}
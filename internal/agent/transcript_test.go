@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestTranscriptReadsNewestArchiveOfTerminatedAgent(t *testing.T) {
+	dir := t.TempDir()
+	service := &Service{archiveDir: dir, logger: logging.Component("test")}
+	deletedAt := time.Now().UTC()
+	agent := &models.Agent{ID: "agent-1", WorkspaceID: "ws-1", Type: models.AgentTypeOpenCode, DeletedAt: &deletedAt}
+
+	service.archiveAgentLogs(context.Background(), agent, "first\n", time.Now().UTC(), nil)
+	service.archiveAgentLogs(context.Background(), agent, "second\nthird\n", time.Now().UTC(), nil)
+
+	if got := transcriptLines(t, service, agent); got != "second|third" {
+		t.Fatalf("expected the newest archive, got %q", got)
+	}
+
+	// Only the compressed older archive is left.
+	matches, _ := filepath.Glob(filepath.Join(dir, agent.ID, "*.json"))
+	for _, path := range matches {
+		os.Remove(path)
+	}
+	if got := transcriptLines(t, service, agent); got != "first" {
+		t.Fatalf("expected the gzipped archive, got %q", got)
+	}
+
+	if _, err := service.Transcript(context.Background(), &models.Agent{ID: "agent-2", DeletedAt: &deletedAt}); !errors.Is(err, ErrNoArchivedTranscript) {
+		t.Fatalf("expected ErrNoArchivedTranscript, got %v", err)
+	}
+}
+
+func transcriptLines(t *testing.T, service *Service, agent *models.Agent) string {
+	t.Helper()
+	it, err := service.Transcript(context.Background(), agent)
+	if err != nil {
+		t.Fatalf("Transcript failed: %v", err)
+	}
+	var lines []string
+	for it.Next() {
+		record := it.Record()
+		if record.Source != TranscriptSourceArchive {
+			t.Fatalf("unexpected source %q", record.Source)
+		}
+		lines = append(lines, record.Content)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	return strings.Join(lines, "|")
+}
@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+// PaneCheck reports where an agent's stored pane target resolves now.
+type PaneCheck struct {
+	AgentID     string `json:"agent_id"`
+	WorkspaceID string `json:"workspace_id"`
+	tmux.PaneResolution
+
+	// Fixed is set when the stored target was rewritten to the pane ID.
+	Fixed bool `json:"fixed,omitempty"`
+}
+
+// VerifyPanes resolves the stored pane of every non-stopped agent, or of
+// one workspace's agents, and reports panes that moved to another session
+// or vanished. With fix, agents whose pane is in place but stored as a
+// positional target (or without its session) are rewritten to the pane ID
+// and session; drifted agents are only reported.
+func (s *Service) VerifyPanes(ctx context.Context, workspaceID string, fix bool) ([]PaneCheck, error) {
+	var (
+		agents []*models.Agent
+		err    error
+	)
+	if workspaceID != "" {
+		agents, err = s.repo.ListByWorkspace(ctx, workspaceID)
+	} else {
+		agents, err = s.repo.List(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	sessions := make(map[string]string)
+	checks := make([]PaneCheck, 0, len(agents))
+	for _, agent := range agents {
+		// Panes on swarmd nodes are tracked by their daemon.
+		if agent.State == models.AgentStateStopped || agent.TmuxPane == "" || agent.RemoteAgentID != "" {
+			continue
+		}
+
+		session := agent.TmuxSession
+		if session == "" {
+			cached, ok := sessions[agent.WorkspaceID]
+			if !ok {
+				if ws, err := s.workspaceService.GetWorkspace(ctx, agent.WorkspaceID); err == nil {
+					cached = ws.TmuxSession
+				}
+				sessions[agent.WorkspaceID] = cached
+			}
+			session = cached
+		}
+
+		res, err := s.tmuxClient.ResolvePane(ctx, session, agent.TmuxPane)
+		if err != nil {
+			return checks, fmt.Errorf("failed to resolve pane for agent %s: %w", agent.ID, err)
+		}
+		check := PaneCheck{AgentID: agent.ID, WorkspaceID: agent.WorkspaceID, PaneResolution: *res}
+
+		stale := res.PaneID != agent.TmuxPane || agent.TmuxSession != res.Session
+		if fix && res.Drift == tmux.PaneInPlace && stale {
+			agent.TmuxPane = res.PaneID
+			agent.TmuxSession = res.Session
+			if err := s.repo.Update(ctx, agent); err != nil {
+				return checks, fmt.Errorf("failed to update agent %s: %w", agent.ID, err)
+			}
+			check.Fixed = true
+			s.logger.Info().
+				Ctx(ctx).
+				Str("agent_id", agent.ID).
+				Str("old_target", res.Target).
+				Str("tmux_pane", agent.TmuxPane).
+				Str("tmux_session", agent.TmuxSession).
+				Msg("agent pane target normalized")
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+func TestVerifyPanes(t *testing.T) {
+	ctx := context.Background()
+	env := newSpawnTestEnv(t)
+	repo := db.NewAgentRepository(env.database)
+
+	stayed := seedMoveAgent(t, env)
+	moved := seedMoveAgent(t, env)
+	vanished := seedMoveAgent(t, env)
+	positional := seedMoveAgent(t, env)
+
+	if _, err := env.tmux.Run("new-session", "-d", "-s", "elsewhere", "-c", "/repo"); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	if _, err := env.tmux.Run("move-pane", "-s", moved.TmuxPane, "-t", "elsewhere"); err != nil {
+		t.Fatalf("failed to move pane: %v", err)
+	}
+	if _, err := env.tmux.Run("kill-pane", "-t", vanished.TmuxPane); err != nil {
+		t.Fatalf("failed to kill pane: %v", err)
+	}
+
+	// Store a legacy positional target for the last pane in ws:agents.
+	panes := panesIn(t, env, "ws:1")
+	positionalID := panes[len(panes)-1]
+	if positionalID != positional.TmuxPane {
+		t.Fatalf("expected %s last in ws:agents, got %v", positional.TmuxPane, panes)
+	}
+	positional.TmuxPane = fmt.Sprintf("ws:1.%d", len(panes)-1)
+	if err := repo.Update(ctx, positional); err != nil {
+		t.Fatalf("failed to store positional target: %v", err)
+	}
+
+	checks, err := env.service.VerifyPanes(ctx, "", false)
+	if err != nil {
+		t.Fatalf("VerifyPanes failed: %v", err)
+	}
+	byAgent := make(map[string]PaneCheck, len(checks))
+	for _, check := range checks {
+		byAgent[check.AgentID] = check
+	}
+
+	if got := byAgent[stayed.ID]; got.Drift != tmux.PaneInPlace || got.Session != "ws" || got.PaneID != stayed.TmuxPane {
+		t.Errorf("stayed: unexpected check %+v", got)
+	}
+	if got := byAgent[moved.ID]; got.Drift != tmux.PaneMoved || got.Session != "elsewhere" || got.ExpectedSession != "ws" {
+		t.Errorf("moved: unexpected check %+v", got)
+	}
+	if got := byAgent[vanished.ID]; got.Drift != tmux.PaneVanished || got.PaneID != "" {
+		t.Errorf("vanished: unexpected check %+v", got)
+	}
+	if got := byAgent[positional.ID]; got.Drift != tmux.PaneInPlace || got.PaneID != positionalID || got.Fixed {
+		t.Errorf("positional: unexpected check %+v", got)
+	}
+
+	// Only in-place agents are normalized; drifted ones are left alone.
+	checks, err = env.service.VerifyPanes(ctx, env.workspaceID, true)
+	if err != nil {
+		t.Fatalf("VerifyPanes with fix failed: %v", err)
+	}
+	for _, check := range checks {
+		if check.Fixed != (check.AgentID == stayed.ID || check.AgentID == positional.ID) {
+			t.Errorf("agent %s: fixed = %v for drift %s", check.AgentID, check.Fixed, check.Drift)
+		}
+	}
+	stored, err := repo.Get(ctx, positional.ID)
+	if err != nil || stored.TmuxPane != positionalID || stored.TmuxSession != "ws" {
+		t.Fatalf("expected positional target rewritten to %s in ws, got %+v (err=%v)", positionalID, stored, err)
+	}
+	stored, err = repo.Get(ctx, moved.ID)
+	if err != nil || stored.TmuxPane != moved.TmuxPane || stored.TmuxSession != "" {
+		t.Fatalf("expected moved agent untouched, got %+v (err=%v)", stored, err)
+	}
+}
+
+func panesIn(t *testing.T, env *spawnTestEnv, window string) []string {
+	t.Helper()
+	out, err := env.tmux.Run("list-panes", "-t", window, "-F", "#{pane_id}")
+	if err != nil {
+		t.Fatalf("failed to list panes: %v", err)
+	}
+	return strings.Fields(out)
+}
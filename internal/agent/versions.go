@@ -0,0 +1,202 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrCLIOutdated is returned when the installed agent CLI is older than the
+// configured minimum and outdated spawns are blocked.
+var ErrCLIOutdated = errors.New("agent CLI older than the configured minimum")
+
+// WithCLIVersionPolicy checks the CLI version probed at each spawn against
+// the per-type minimums in cfg. An outdated CLI is refused when
+// agent_defaults.block_outdated_spawns is set and logged otherwise.
+func WithCLIVersionPolicy(cfg *config.Config) ServiceOption {
+	return func(s *Service) {
+		s.minCLIVersions = cfg.AgentDefaults.MinCLIVersions
+		s.blockOutdatedSpawns = cfg.AgentDefaults.BlockOutdatedSpawns
+	}
+}
+
+// checkCLIVersion compares the probed version of agentType with its
+// configured minimum.
+func (s *Service) checkCLIVersion(agentType models.AgentType, version string) error {
+	minimum := s.minCLIVersions[string(agentType)]
+	if minimum == "" || version == "" || adapters.CompareVersions(version, minimum) >= 0 {
+		return nil
+	}
+	if s.blockOutdatedSpawns {
+		return fmt.Errorf("%w: %s %s is installed, %s or newer is required", ErrCLIOutdated, agentType, version, minimum)
+	}
+	s.logger.Warn().
+		Str("type", string(agentType)).
+		Str("version", version).
+		Str("minimum", minimum).
+		Msg("agent CLI is older than the configured minimum")
+	return nil
+}
+
+// RefreshCLIVersions probes the installed CLI of each live agent, once per
+// node and agent type, and records it with the time of the check. Agents
+// keep the version they were started with, so a newer install shows up as
+// drift until they are restarted. Agents on swarmd nodes and types without
+// a version command are skipped. It returns the number of agents updated.
+func (s *Service) RefreshCLIVersions(ctx context.Context, agents []*models.Agent) (int, error) {
+	if s.probe == nil {
+		return 0, nil
+	}
+
+	nodes := make(map[string]string)
+	installed := make(map[string]string)
+	now := time.Now().UTC()
+	updated := 0
+	for _, a := range agents {
+		if a.IsTerminated() || a.State == models.AgentStateHibernated {
+			continue
+		}
+		if adapters.CapabilitiesFor(a.Type).VersionCommand() == "" {
+			continue
+		}
+
+		nodeID, ok := nodes[a.WorkspaceID]
+		if !ok {
+			ws, err := s.workspaceService.GetWorkspace(ctx, a.WorkspaceID)
+			if err != nil {
+				return updated, fmt.Errorf("failed to get workspace: %w", err)
+			}
+			daemon, err := s.daemonForNode(ctx, ws.NodeID)
+			if err != nil {
+				return updated, err
+			}
+			if daemon == nil {
+				nodeID = ws.NodeID
+			}
+			nodes[a.WorkspaceID] = nodeID
+		}
+		if nodeID == "" {
+			continue
+		}
+
+		key := nodeID + "|" + string(a.Type)
+		version, ok := installed[key]
+		if !ok {
+			s.probe.Invalidate(nodeID, a.Type)
+			var err error
+			version, err = s.probe.Probe(ctx, nodeID, a.Type)
+			if err != nil {
+				s.logger.Warn().Ctx(ctx).Err(err).Str("node_id", nodeID).Str("type", string(a.Type)).Msg("failed to probe agent CLI version")
+			}
+			installed[key] = version
+		}
+		if version == "" {
+			continue
+		}
+
+		current, err := s.repo.Get(ctx, a.ID)
+		if err != nil {
+			return updated, fmt.Errorf("failed to get agent: %w", err)
+		}
+		current.Metadata.InstalledCLIVersion = version
+		current.Metadata.CLIVersionCheckedAt = &now
+		if err := s.repo.Update(ctx, current); err != nil {
+			return updated, fmt.Errorf("failed to update agent: %w", err)
+		}
+		a.Metadata = current.Metadata
+		updated++
+	}
+	return updated, nil
+}
+
+// CLIVersionReport is the CLI versions agents of one type are running.
+type CLIVersionReport struct {
+	Type models.AgentType `json:"type"`
+
+	// Installed is the version last probed on the node, if any.
+	Installed string `json:"installed,omitempty"`
+
+	// Minimum is the configured minimum version, if any.
+	Minimum string `json:"minimum,omitempty"`
+
+	// Versions are the running versions, newest first.
+	Versions []CLIVersionGroup `json:"versions"`
+}
+
+// CLIVersionGroup is the agents of one type running the same CLI version.
+type CLIVersionGroup struct {
+	Version string `json:"version"`
+
+	// Current is set when this is the installed version, or, without a
+	// probe, the newest version running.
+	Current bool `json:"current"`
+
+	AgentIDs []string `json:"agent_ids"`
+}
+
+// BuildCLIVersionReport groups live agents by type and the CLI version they
+// run. Agents started without a probe are grouped under "unknown".
+func BuildCLIVersionReport(agents []*models.Agent, minimums map[string]string) []CLIVersionReport {
+	byType := make(map[models.AgentType]*CLIVersionReport)
+	groups := make(map[models.AgentType]map[string]*CLIVersionGroup)
+	checked := make(map[models.AgentType]time.Time)
+	for _, a := range agents {
+		if a.IsTerminated() || a.State == models.AgentStateHibernated {
+			continue
+		}
+		report, ok := byType[a.Type]
+		if !ok {
+			report = &CLIVersionReport{Type: a.Type, Minimum: minimums[string(a.Type)]}
+			byType[a.Type] = report
+			groups[a.Type] = make(map[string]*CLIVersionGroup)
+		}
+		if at := a.Metadata.CLIVersionCheckedAt; a.Metadata.InstalledCLIVersion != "" && at != nil && at.After(checked[a.Type]) {
+			report.Installed = a.Metadata.InstalledCLIVersion
+			checked[a.Type] = *at
+		}
+
+		version := a.Metadata.CLIVersion
+		if version == "" {
+			version = "unknown"
+		}
+		group, ok := groups[a.Type][version]
+		if !ok {
+			group = &CLIVersionGroup{Version: version}
+			groups[a.Type][version] = group
+		}
+		group.AgentIDs = append(group.AgentIDs, a.ID)
+	}
+
+	reports := make([]CLIVersionReport, 0, len(byType))
+	for agentType, report := range byType {
+		for _, group := range groups[agentType] {
+			sort.Strings(group.AgentIDs)
+			report.Versions = append(report.Versions, *group)
+		}
+		sort.Slice(report.Versions, func(i, j int) bool {
+			a, b := report.Versions[i].Version, report.Versions[j].Version
+			if a == "unknown" || b == "unknown" {
+				return b == "unknown" && a != "unknown"
+			}
+			return adapters.CompareVersions(a, b) > 0
+		})
+
+		current := report.Installed
+		if current == "" && report.Versions[0].Version != "unknown" {
+			current = report.Versions[0].Version
+		}
+		for i := range report.Versions {
+			report.Versions[i].Current = current != "" && report.Versions[i].Version != "unknown" &&
+				adapters.CompareVersions(report.Versions[i].Version, current) == 0
+		}
+		reports = append(reports, *report)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Type < reports[j].Type })
+	return reports
+}
@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func TestSpawnAgentCLIVersionPolicy(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	cfg := config.DefaultConfig()
+	cfg.AgentDefaults.MinCLIVersions = map[string]string{"claude-code": "1.0.20"}
+	cfg.AgentDefaults.BlockOutdatedSpawns = true
+	WithCLIVersionPolicy(cfg)(env.service)
+	env.service.probe = NewSpawnProbe(&probeExec{outputs: map[string]string{"claude": "1.0.17 (Claude Code)"}})
+
+	opts := SpawnOptions{
+		WorkspaceID:       env.workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      2 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	}
+	_, err := env.service.SpawnAgent(context.Background(), opts)
+	if !errors.Is(err, ErrCLIOutdated) {
+		t.Fatalf("expected an outdated CLI to be refused, got %v", err)
+	}
+	if got := env.panes(t); got != "%0,%1" {
+		t.Fatalf("expected no pane to be created, got %s", got)
+	}
+
+	env.service.blockOutdatedSpawns = false
+	agent, err := env.service.SpawnAgent(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("expected an outdated CLI to only warn, got %v", err)
+	}
+	if agent.Metadata.InstalledCLIVersion != "1.0.17" || agent.Metadata.CLIVersionCheckedAt == nil {
+		t.Fatalf("expected the probe to be recorded, got %+v", agent.Metadata)
+	}
+}
+
+func TestRefreshCLIVersions(t *testing.T) {
+	env := newSpawnTestEnv(t, tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	exec := &probeExec{outputs: map[string]string{"claude": "1.0.17 (Claude Code)"}}
+	env.service.probe = NewSpawnProbe(exec)
+	ctx := context.Background()
+
+	var ids []string
+	for range 2 {
+		agent, err := env.service.SpawnAgent(ctx, SpawnOptions{
+			WorkspaceID:       env.workspaceID,
+			Type:              models.AgentTypeClaudeCode,
+			ReadyTimeout:      2 * time.Second,
+			ReadyPollInterval: 5 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatalf("SpawnAgent failed: %v", err)
+		}
+		ids = append(ids, agent.ID)
+	}
+
+	exec.outputs["claude"] = "1.0.20 (Claude Code)"
+	calls := exec.calls
+	agents, err := env.service.ListAgents(ctx, ListAgentsOptions{WorkspaceID: env.workspaceID})
+	if err != nil {
+		t.Fatalf("ListAgents failed: %v", err)
+	}
+	updated, err := env.service.RefreshCLIVersions(ctx, agents)
+	if err != nil || updated != 2 {
+		t.Fatalf("expected both agents to be updated, got %d, %v", updated, err)
+	}
+	if exec.calls != calls+1 {
+		t.Fatalf("expected one probe for the node and type, got %d", exec.calls-calls)
+	}
+
+	for _, id := range ids {
+		stored, err := env.service.GetAgent(ctx, id)
+		if err != nil {
+			t.Fatalf("GetAgent failed: %v", err)
+		}
+		if stored.Metadata.CLIVersion != "1.0.17" || stored.Metadata.InstalledCLIVersion != "1.0.20" {
+			t.Fatalf("expected running 1.0.17 with 1.0.20 installed, got %+v", stored.Metadata)
+		}
+	}
+
+	reports := BuildCLIVersionReport(agents, map[string]string{"claude-code": "1.0.18"})
+	if len(reports) != 1 || reports[0].Installed != "1.0.20" || reports[0].Minimum != "1.0.18" {
+		t.Fatalf("unexpected report: %+v", reports)
+	}
+	if versions := reports[0].Versions; len(versions) != 1 || versions[0].Version != "1.0.17" || versions[0].Current || len(versions[0].AgentIDs) != 2 {
+		t.Fatalf("expected both agents behind the installed version, got %+v", versions)
+	}
+}
+
+func TestBuildCLIVersionReport(t *testing.T) {
+	checked := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	agents := []*models.Agent{
+		{ID: "c", Type: models.AgentTypeCodex, Metadata: models.AgentMetadata{CLIVersion: "0.19.0"}},
+		{ID: "b", Type: models.AgentTypeCodex, Metadata: models.AgentMetadata{CLIVersion: "0.20.0"}},
+		{ID: "a", Type: models.AgentTypeCodex, Metadata: models.AgentMetadata{CLIVersion: "0.20.0"}},
+		{ID: "d", Type: models.AgentTypeCodex},
+		{ID: "e", Type: models.AgentTypeClaudeCode, Metadata: models.AgentMetadata{
+			CLIVersion: "1.0.17", InstalledCLIVersion: "1.0.17", CLIVersionCheckedAt: &checked,
+		}},
+		{ID: "f", Type: models.AgentTypeClaudeCode, State: models.AgentStateHibernated, Metadata: models.AgentMetadata{CLIVersion: "1.0.10"}},
+	}
+
+	reports := BuildCLIVersionReport(agents, nil)
+	if len(reports) != 2 || reports[0].Type != models.AgentTypeClaudeCode || reports[1].Type != models.AgentTypeCodex {
+		t.Fatalf("expected a report per type, got %+v", reports)
+	}
+	if versions := reports[0].Versions; len(versions) != 1 || !versions[0].Current || reports[0].Installed != "1.0.17" {
+		t.Fatalf("expected the installed claude-code version to be current, got %+v", reports[0])
+	}
+
+	codex := reports[1].Versions
+	want := []struct {
+		version string
+		current bool
+		ids     int
+	}{{"0.20.0", true, 2}, {"0.19.0", false, 1}, {"unknown", false, 1}}
+	if len(codex) != len(want) {
+		t.Fatalf("unexpected codex versions: %+v", codex)
+	}
+	for i, w := range want {
+		if codex[i].Version != w.version || codex[i].Current != w.current || len(codex[i].AgentIDs) != w.ids {
+			t.Errorf("version %d: got %+v, want %+v", i, codex[i], w)
+		}
+	}
+	if codex[0].AgentIDs[0] != "a" {
+		t.Errorf("expected agent IDs sorted, got %v", codex[0].AgentIDs)
+	}
+}
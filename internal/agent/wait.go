@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrAgentTerminalState indicates the agent reached a terminal state that was
+// not one of the states being waited for.
+var ErrAgentTerminalState = errors.New("agent reached terminal state")
+
+const (
+	waitInitialInterval = 100 * time.Millisecond
+	waitMaxInterval     = 2 * time.Second
+)
+
+var waitSubscriptionSeq atomic.Int64
+
+// TerminalStateError describes the terminal state an agent settled in.
+type TerminalStateError struct {
+	Agent *models.Agent
+	State models.AgentState
+}
+
+func (e *TerminalStateError) Error() string {
+	return fmt.Sprintf("agent %s reached terminal state %s", e.Agent.ID, e.State)
+}
+
+// Unwrap allows errors.Is(err, ErrAgentTerminalState).
+func (e *TerminalStateError) Unwrap() error {
+	return ErrAgentTerminalState
+}
+
+// IsTerminalState reports whether an agent in this state will not make
+// further progress without operator intervention.
+func IsTerminalState(state models.AgentState) bool {
+	return state == models.AgentStateError || state == models.AgentStateStopped
+}
+
+// WaitForState blocks until the agent reaches one of the given states.
+//
+// It polls the repository with adaptive backoff, and when the service has an
+// event publisher it also wakes up on agent events. The wait ends with a
+// *TerminalStateError if the agent reaches a terminal state that is not a
+// target, and with ctx.Err() when the context is done. An agent record that
+// disappears (terminated) is treated as reaching the stopped state.
+func (s *Service) WaitForState(ctx context.Context, id string, states ...models.AgentState) (*models.Agent, error) {
+	if len(states) == 0 {
+		return nil, fmt.Errorf("at least one target state is required")
+	}
+
+	targets := make(map[models.AgentState]bool, len(states))
+	for _, state := range states {
+		targets[state] = true
+	}
+
+	wake, unsubscribe := s.subscribeAgentEvents(id)
+	defer unsubscribe()
+
+	interval := waitInitialInterval
+	var last *models.Agent
+	for {
+		agent, err := s.GetAgent(ctx, id)
+		if err != nil {
+			if last == nil || !errors.Is(err, ErrServiceAgentNotFound) {
+				return nil, err
+			}
+			gone := *last
+			gone.State = models.AgentStateStopped
+			agent = &gone
+		}
+
+		if targets[agent.State] {
+			return agent, nil
+		}
+		if IsTerminalState(agent.State) {
+			return agent, &TerminalStateError{Agent: agent, State: agent.State}
+		}
+
+		if last != nil && last.State != agent.State {
+			interval = waitInitialInterval
+		} else if last != nil {
+			interval *= 2
+			if interval > waitMaxInterval {
+				interval = waitMaxInterval
+			}
+		}
+		last = agent
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, ctx.Err()
+		case <-wake:
+			timer.Stop()
+			interval = waitInitialInterval
+		case <-timer.C:
+		}
+	}
+}
+
+// subscribeAgentEvents returns a channel that is signaled whenever an event
+// for the agent is published in-process.
+func (s *Service) subscribeAgentEvents(agentID string) (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+	if s.publisher == nil {
+		return wake, func() {}
+	}
+
+	subID := fmt.Sprintf("agent-wait-%s-%d", agentID, waitSubscriptionSeq.Add(1))
+	filter := events.Filter{
+		EntityTypes: []models.EntityType{models.EntityTypeAgent},
+		EntityID:    agentID,
+	}
+	err := s.publisher.Subscribe(subID, filter, func(*models.Event) {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		s.logger.Debug().Err(err).Str("agent_id", agentID).Msg("failed to subscribe to agent events; polling only")
+		return wake, func() {}
+	}
+
+	return wake, func() { _ = s.publisher.Unsubscribe(subID) }
+}
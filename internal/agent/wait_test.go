@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func newWaitTestService(t *testing.T, state models.AgentState) (*Service, *db.AgentRepository, *models.Agent) {
+	t.Helper()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	node := &models.Node{
+		Name:       "wait-node",
+		SSHBackend: models.SSHBackendAuto,
+		Status:     models.NodeStatusUnknown,
+		IsLocal:    true,
+	}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{
+		ID:          "ws-wait",
+		NodeID:      node.ID,
+		Name:        "wait",
+		RepoPath:    "/tmp/wait",
+		TmuxSession: "wait-session",
+	}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	agentRepo := db.NewAgentRepository(database)
+	agent := &models.Agent{
+		ID:          "agent-wait",
+		WorkspaceID: ws.ID,
+		Type:        models.AgentTypeOpenCode,
+		TmuxPane:    "wait-session:0.1",
+		State:       state,
+		StateInfo:   models.StateInfo{State: state, Confidence: models.StateConfidenceHigh},
+	}
+	if err := agentRepo.Create(ctx, agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	service := NewService(agentRepo, db.NewQueueRepository(database), nil, nil, nil)
+	return service, agentRepo, agent
+}
+
+func stepAgentStates(t *testing.T, repo *db.AgentRepository, agent *models.Agent, states ...models.AgentState) {
+	t.Helper()
+	go func() {
+		for _, state := range states {
+			time.Sleep(50 * time.Millisecond)
+			agent.State = state
+			agent.StateInfo.State = state
+			_ = repo.Update(context.Background(), agent)
+		}
+	}()
+}
+
+func TestWaitForStateReachesTarget(t *testing.T) {
+	service, repo, agent := newWaitTestService(t, models.AgentStateWorking)
+	stepAgentStates(t, repo, agent, models.AgentStateRateLimited, models.AgentStateIdle)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got, err := service.WaitForState(ctx, agent.ID, models.AgentStateIdle)
+	if err != nil {
+		t.Fatalf("WaitForState returned error: %v", err)
+	}
+	if got.State != models.AgentStateIdle {
+		t.Fatalf("expected idle, got %s", got.State)
+	}
+}
+
+func TestWaitForStateAnyOf(t *testing.T) {
+	service, repo, agent := newWaitTestService(t, models.AgentStateWorking)
+	stepAgentStates(t, repo, agent, models.AgentStateAwaitingApproval)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got, err := service.WaitForState(ctx, agent.ID, models.AgentStateIdle, models.AgentStateAwaitingApproval)
+	if err != nil {
+		t.Fatalf("WaitForState returned error: %v", err)
+	}
+	if got.State != models.AgentStateAwaitingApproval {
+		t.Fatalf("expected awaiting_approval, got %s", got.State)
+	}
+}
+
+func TestWaitForStateTerminalState(t *testing.T) {
+	service, repo, agent := newWaitTestService(t, models.AgentStateWorking)
+	stepAgentStates(t, repo, agent, models.AgentStateError)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got, err := service.WaitForState(ctx, agent.ID, models.AgentStateIdle)
+	if !errors.Is(err, ErrAgentTerminalState) {
+		t.Fatalf("expected ErrAgentTerminalState, got %v", err)
+	}
+	var terminal *TerminalStateError
+	if !errors.As(err, &terminal) || terminal.State != models.AgentStateError {
+		t.Fatalf("expected terminal error state, got %v", err)
+	}
+	if got == nil || got.State != models.AgentStateError {
+		t.Fatalf("expected final snapshot in error state, got %+v", got)
+	}
+}
+
+func TestWaitForStateTimeout(t *testing.T) {
+	service, _, agent := newWaitTestService(t, models.AgentStateWorking)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	got, err := service.WaitForState(ctx, agent.ID, models.AgentStateIdle)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if got == nil || got.State != models.AgentStateWorking {
+		t.Fatalf("expected last snapshot in working state, got %+v", got)
+	}
+}
+
+func TestWaitForStateAgentRemoved(t *testing.T) {
+	service, repo, agent := newWaitTestService(t, models.AgentStateWorking)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = repo.Delete(context.Background(), agent.ID)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got, err := service.WaitForState(ctx, agent.ID, models.AgentStateStopped)
+	if err != nil {
+		t.Fatalf("WaitForState returned error: %v", err)
+	}
+	if got.State != models.AgentStateStopped {
+		t.Fatalf("expected stopped, got %s", got.State)
+	}
+}
+
+func TestWaitForStateUnknownAgent(t *testing.T) {
+	service, _, _ := newWaitTestService(t, models.AgentStateWorking)
+
+	_, err := service.WaitForState(context.Background(), "missing", models.AgentStateIdle)
+	if !errors.Is(err, ErrServiceAgentNotFound) {
+		t.Fatalf("expected ErrServiceAgentNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package agentmail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AgentProfile is an agent registered with a project.
+type AgentProfile struct {
+	Name         string `json:"name"`
+	Program      string `json:"program"`
+	Model        string `json:"model"`
+	LastActiveTS string `json:"last_active_ts"`
+}
+
+// ListAgents returns the agents registered with the project.
+func (c *Client) ListAgents(ctx context.Context, project string) ([]AgentProfile, error) {
+	data, err := c.ReadResource(ctx, AgentsURI(project))
+	if err != nil {
+		return nil, err
+	}
+
+	// The server wraps the list with the project; accept a bare list too.
+	var wrapped struct {
+		Agents []AgentProfile `json:"agents"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err == nil {
+		return wrapped.Agents, nil
+	}
+	var agents []AgentProfile
+	if err := json.Unmarshal(data, &agents); err != nil {
+		return nil, fmt.Errorf("parse agent mail agents: %w", err)
+	}
+	return agents, nil
+}
+
+// AgentsURI returns the resource URI listing a project's agents.
+func AgentsURI(project string) string {
+	return fmt.Sprintf("resource://agents/%s", url.PathEscape(strings.TrimSpace(project)))
+}
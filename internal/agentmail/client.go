@@ -0,0 +1,190 @@
+// Package agentmail provides a minimal MCP client for the Agent Mail server,
+// used for advisory file reservations shared between agents.
+package agentmail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultURL is the default Agent Mail MCP endpoint.
+	DefaultURL = "http://127.0.0.1:8765/mcp/"
+
+	// DefaultTimeout is the default per-request timeout.
+	DefaultTimeout = 5 * time.Second
+)
+
+// Client talks JSON-RPC to an Agent Mail MCP server over HTTP.
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the given endpoint. Empty url and
+// non-positive timeout fall back to DefaultURL and DefaultTimeout.
+func NewClient(url string, timeout time.Duration) *Client {
+	if strings.TrimSpace(url) == "" {
+		url = DefaultURL
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Client{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+type mcpResourceRequest struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      string            `json:"id"`
+	Method  string            `json:"method"`
+	Params  mcpResourceParams `json:"params"`
+}
+
+type mcpResourceParams struct {
+	URI string `json:"uri"`
+}
+
+type mcpResourceResponse struct {
+	Result mcpResourceResult `json:"result"`
+	Error  *mcpResponseError `json:"error"`
+}
+
+type mcpResourceResult struct {
+	Contents []mcpResourceContent `json:"contents"`
+}
+
+type mcpResourceContent struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type mcpResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type mcpToolRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      string        `json:"id"`
+	Method  string        `json:"method"`
+	Params  mcpToolParams `json:"params"`
+}
+
+type mcpToolParams struct {
+	Name      string      `json:"name"`
+	Arguments interface{} `json:"arguments"`
+}
+
+type mcpToolResponse struct {
+	Result json.RawMessage   `json:"result"`
+	Error  *mcpResponseError `json:"error"`
+}
+
+// ReadResource reads an MCP resource and returns its first text content.
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]byte, error) {
+	request := mcpResourceRequest{
+		JSONRPC: "2.0",
+		ID:      requestID(),
+		Method:  "resources/read",
+		Params: mcpResourceParams{
+			URI: uri,
+		},
+	}
+
+	var response mcpResourceResponse
+	if err := c.post(ctx, request, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("mcp error %d: %s", response.Error.Code, response.Error.Message)
+	}
+	if len(response.Result.Contents) == 0 {
+		return nil, errors.New("empty mcp resource response")
+	}
+	content := response.Result.Contents[0]
+	if strings.TrimSpace(content.Text) == "" {
+		return nil, errors.New("empty mcp resource content")
+	}
+	return []byte(content.Text), nil
+}
+
+// CallTool invokes an MCP tool and decodes its result into out (if non-nil).
+func (c *Client) CallTool(ctx context.Context, name string, args interface{}, out interface{}) error {
+	request := mcpToolRequest{
+		JSONRPC: "2.0",
+		ID:      requestID(),
+		Method:  "tools/call",
+		Params: mcpToolParams{
+			Name:      name,
+			Arguments: args,
+		},
+	}
+
+	var response mcpToolResponse
+	if err := c.post(ctx, request, &response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return fmt.Errorf("mcp error %d: %s", response.Error.Code, response.Error.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	if len(response.Result) == 0 {
+		return errors.New("empty mcp tool response")
+	}
+	if err := json.Unmarshal(response.Result, out); err != nil {
+		return fmt.Errorf("parse mcp tool response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) post(ctx context.Context, request any, response any) error {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("encode mcp request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("build mcp request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("call mcp server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("decode mcp response: %w", err)
+	}
+	return nil
+}
+
+func requestID() string {
+	return fmt.Sprintf("swarm-%d", time.Now().UnixNano())
+}
+
+// ParseTime parses an Agent Mail timestamp. Empty input yields the zero time.
+func ParseTime(value string) (time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
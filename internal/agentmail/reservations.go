@@ -0,0 +1,199 @@
+package agentmail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// FileReservation is an advisory lock held on a path pattern.
+type FileReservation struct {
+	ID          int64  `json:"id"`
+	Agent       string `json:"agent"`
+	PathPattern string `json:"path_pattern"`
+	Exclusive   bool   `json:"exclusive"`
+	Reason      string `json:"reason"`
+	CreatedTS   string `json:"created_ts"`
+	ExpiresTS   string `json:"expires_ts"`
+	ReleasedTS  string `json:"released_ts"`
+}
+
+// ClaimResult is the outcome of a reservation request.
+type ClaimResult struct {
+	Granted   []ReservationGrant    `json:"granted"`
+	Conflicts []ReservationConflict `json:"conflicts"`
+}
+
+// ReservationGrant describes a reservation that was granted.
+type ReservationGrant struct {
+	ID          int64  `json:"id"`
+	PathPattern string `json:"path_pattern"`
+	Exclusive   bool   `json:"exclusive"`
+	Reason      string `json:"reason"`
+	ExpiresTS   string `json:"expires_ts"`
+}
+
+// ReservationConflict lists the holders blocking a requested path.
+type ReservationConflict struct {
+	Path    string              `json:"path"`
+	Holders []ReservationHolder `json:"holders"`
+}
+
+// ReservationHolder is another agent's reservation that conflicts.
+type ReservationHolder struct {
+	ID          int64  `json:"id"`
+	Agent       string `json:"agent"`
+	PathPattern string `json:"path_pattern"`
+	Exclusive   bool   `json:"exclusive"`
+	ExpiresTS   string `json:"expires_ts"`
+}
+
+// ReleaseResult is the outcome of a release request.
+type ReleaseResult struct {
+	Released   int    `json:"released"`
+	ReleasedAt string `json:"released_at"`
+}
+
+// ClaimRequest describes paths to reserve for an agent.
+type ClaimRequest struct {
+	Project    string
+	Agent      string
+	Paths      []string
+	TTLSeconds int
+	Exclusive  bool
+	Reason     string
+}
+
+// ClaimPaths reserves paths for an agent. Conflicts are reported in the
+// result rather than as an error.
+func (c *Client) ClaimPaths(ctx context.Context, req ClaimRequest) (*ClaimResult, error) {
+	args := map[string]interface{}{
+		"project_key": req.Project,
+		"agent_name":  req.Agent,
+		"paths":       req.Paths,
+		"ttl_seconds": req.TTLSeconds,
+		"exclusive":   req.Exclusive,
+	}
+	if strings.TrimSpace(req.Reason) != "" {
+		args["reason"] = req.Reason
+	}
+
+	var result ClaimResult
+	if err := c.CallTool(ctx, "file_reservation_paths", args, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ForceReleaseConflicts force-releases every reservation holding one of the
+// conflicting paths, notifying the previous holders.
+func (c *Client) ForceReleaseConflicts(ctx context.Context, project, agentName string, conflicts []ReservationConflict, reason string) error {
+	ids := make([]int64, 0)
+	for _, conflict := range conflicts {
+		for _, holder := range conflict.Holders {
+			if holder.ID == 0 {
+				continue
+			}
+			ids = append(ids, holder.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return errors.New("no lock IDs available for force release")
+	}
+
+	for _, id := range ids {
+		args := map[string]interface{}{
+			"project_key":         project,
+			"agent_name":          agentName,
+			"file_reservation_id": id,
+			"notify_previous":     true,
+		}
+		if strings.TrimSpace(reason) != "" {
+			args["note"] = reason
+		}
+		if err := c.CallTool(ctx, "force_release_file_reservation", args, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReleasePaths releases an agent's reservations by path and/or ID. With
+// neither, all of the agent's reservations are released.
+func (c *Client) ReleasePaths(ctx context.Context, project, agentName string, paths []string, lockIDs []int) (*ReleaseResult, error) {
+	args := map[string]interface{}{
+		"project_key": project,
+		"agent_name":  agentName,
+	}
+	if len(paths) > 0 {
+		args["paths"] = paths
+	}
+	if len(lockIDs) > 0 {
+		args["file_reservation_ids"] = lockIDs
+	}
+
+	var result ReleaseResult
+	if err := c.CallTool(ctx, "release_file_reservations", args, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListReservations returns the project's file reservations.
+func (c *Client) ListReservations(ctx context.Context, project string, activeOnly bool) ([]FileReservation, error) {
+	data, err := c.ReadResource(ctx, ReservationsURI(project, activeOnly))
+	if err != nil {
+		return nil, err
+	}
+
+	var claims []FileReservation
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, fmt.Errorf("parse agent mail locks: %w", err)
+	}
+
+	return claims, nil
+}
+
+// ReservationsURI returns the resource URI listing a project's reservations.
+func ReservationsURI(project string, activeOnly bool) string {
+	return fmt.Sprintf(
+		"resource://file_reservations/%s?active_only=%t",
+		url.PathEscape(strings.TrimSpace(project)),
+		activeOnly,
+	)
+}
+
+// MatchesPathPattern reports whether a path and a reservation pattern
+// overlap, treating either side as a glob.
+func MatchesPathPattern(pathValue, pattern string) bool {
+	pathValue = NormalizePath(pathValue)
+	pattern = NormalizePath(pattern)
+	if pathValue == "" || pattern == "" {
+		return false
+	}
+	if pathValue == pattern {
+		return true
+	}
+	if ok, _ := path.Match(pattern, pathValue); ok {
+		return true
+	}
+	if ok, _ := path.Match(pathValue, pattern); ok {
+		return true
+	}
+	return false
+}
+
+// NormalizePath trims a path and converts separators to forward slashes.
+func NormalizePath(value string) string {
+	value = strings.TrimSpace(value)
+	value = strings.ReplaceAll(value, "\\", "/")
+	return value
+}
@@ -0,0 +1,15 @@
+package agentmail
+
+import "testing"
+
+func TestMatchesPathPattern(t *testing.T) {
+	if !MatchesPathPattern("src/main.go", "src/*.go") {
+		t.Fatalf("expected pattern match")
+	}
+	if !MatchesPathPattern("src/main.go", "src/main.go") {
+		t.Fatalf("expected exact match")
+	}
+	if MatchesPathPattern("src/main.go", "docs/*.md") {
+		t.Fatalf("did not expect match")
+	}
+}
@@ -0,0 +1,384 @@
+// Package approval turns agent approval prompts into approval records that
+// can be resolved by a human or by workspace approval rules.
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/redact"
+	"github.com/opencode-ai/swarm/internal/state"
+	"github.com/rs/zerolog"
+)
+
+// Common service errors.
+var (
+	ErrApprovalNotFound   = errors.New("approval not found")
+	ErrApprovalNotPending = errors.New("approval is not pending")
+	ErrNoPane             = errors.New("agent has no tmux pane")
+)
+
+const (
+	// DefaultApproveInput is sent to the pane when approving without --input.
+	DefaultApproveInput = "y"
+
+	// DefaultDenyInput is sent to the pane when denying without --input.
+	DefaultDenyInput = "n"
+
+	// ResolvedByPolicy marks approvals resolved by an approval rule.
+	ResolvedByPolicy = "policy"
+
+	// defaultExcerptLines is how many trailing pane lines are kept as the
+	// prompt excerpt.
+	defaultExcerptLines = 15
+)
+
+// PaneClient captures and drives agent panes. *tmux.Client satisfies it.
+type PaneClient interface {
+	CapturePane(ctx context.Context, target string, history bool) (string, error)
+	SendKeys(ctx context.Context, target, keys string, literal, enter bool) error
+}
+
+// ResolveOptions controls how an approval is resolved.
+type ResolveOptions struct {
+	// Input is sent to the agent pane. Defaults to "y" for approve and "n"
+	// for deny.
+	Input string
+
+	// NoEnter skips pressing Enter after Input, for single-key prompts.
+	NoEnter bool
+
+	// ResolvedBy records who resolved the approval. Defaults to
+	// Resolver(ctx).
+	ResolvedBy string
+}
+
+// Resolver returns who an approval resolved under ctx is recorded as
+// resolved by: the audit actor ctx carries, or else the current OS user.
+func Resolver(ctx context.Context) string {
+	if actor := audit.ActorFromContext(ctx); actor != "" {
+		return actor
+	}
+	return audit.LocalActor()
+}
+
+// Service records approval prompts and resolves them.
+type Service struct {
+	repo      *db.ApprovalRepository
+	agentRepo *db.AgentRepository
+	wsRepo    *db.WorkspaceRepository
+	panes     PaneClient
+	cfg       *config.Config
+	publisher events.Publisher
+	redactor  *redact.Redactor
+	logger    zerolog.Logger
+	now       func() time.Time
+}
+
+// ServiceOption configures a Service.
+type ServiceOption func(*Service)
+
+// WithPublisher sets the event publisher for the service.
+func WithPublisher(publisher events.Publisher) ServiceOption {
+	return func(s *Service) {
+		s.publisher = publisher
+	}
+}
+
+// WithConfig enables approval rules from cfg. Rules are resolved per
+// workspace; without a workspace repository only agent defaults apply.
+func WithConfig(cfg *config.Config, wsRepo *db.WorkspaceRepository) ServiceOption {
+	return func(s *Service) {
+		s.cfg = cfg
+		s.wsRepo = wsRepo
+	}
+}
+
+// WithRedactor sets the redactor applied to captured prompt excerpts.
+func WithRedactor(redactor *redact.Redactor) ServiceOption {
+	return func(s *Service) {
+		s.redactor = redactor
+	}
+}
+
+// NewService creates a new approval Service.
+func NewService(repo *db.ApprovalRepository, agentRepo *db.AgentRepository, panes PaneClient, opts ...ServiceOption) *Service {
+	s := &Service{
+		repo:      repo,
+		agentRepo: agentRepo,
+		panes:     panes,
+		redactor:  redact.Default(),
+		logger:    logging.Component("approval"),
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// OnStateChange implements state.Subscriber.
+func (s *Service) OnStateChange(change state.StateChange) {
+	if _, err := s.HandleStateChange(context.Background(), change); err != nil {
+		s.logger.Warn().Err(err).Str("agent_id", change.AgentID).Msg("failed to record approval request")
+	}
+}
+
+// HandleStateChange records an approval request when an agent enters the
+// awaiting-approval state, then applies any matching approval rule. It
+// returns nil when the change is not an approval transition or the agent
+// already has a pending approval.
+func (s *Service) HandleStateChange(ctx context.Context, change state.StateChange) (*models.Approval, error) {
+	if change.CurrentState != models.AgentStateAwaitingApproval || change.PreviousState == models.AgentStateAwaitingApproval {
+		return nil, nil
+	}
+
+	agent, err := s.agentRepo.Get(ctx, change.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent: %w", err)
+	}
+
+	pending, err := s.repo.ListPendingByAgent(ctx, agent.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		return nil, nil
+	}
+
+	detectedAt := change.Timestamp
+	if detectedAt.IsZero() {
+		detectedAt = s.now()
+	}
+
+	details := models.PromptApprovalDetails{
+		PromptExcerpt: s.captureExcerpt(ctx, agent),
+		Pane:          agent.TmuxPane,
+		Reason:        change.StateInfo.Reason,
+		DetectedAt:    detectedAt.UTC(),
+	}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode approval details: %w", err)
+	}
+
+	approval := &models.Approval{
+		AgentID:        agent.ID,
+		RequestType:    models.ApprovalRequestTypePrompt,
+		RequestDetails: raw,
+		Status:         models.ApprovalStatusPending,
+	}
+	if err := s.repo.Create(ctx, approval); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, models.EventTypeApprovalRequested, approval, details.PromptExcerpt, "")
+	s.logger.Info().
+		Str("approval_id", approval.ID).
+		Str("agent_id", agent.ID).
+		Msg("approval requested")
+
+	rule, ok := s.matchRule(ctx, agent, details.PromptExcerpt)
+	if !ok {
+		return approval, nil
+	}
+
+	opts := ResolveOptions{ResolvedBy: ResolvedByPolicy}
+	switch strings.ToLower(strings.TrimSpace(rule.Action)) {
+	case config.ApprovalRuleActionApprove:
+		return s.Approve(ctx, approval.ID, opts)
+	case config.ApprovalRuleActionDeny:
+		return s.Deny(ctx, approval.ID, opts)
+	default:
+		return approval, nil
+	}
+}
+
+// Get returns an approval by ID.
+func (s *Service) Get(ctx context.Context, id string) (*models.Approval, error) {
+	approval, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrApprovalNotFound) {
+			return nil, ErrApprovalNotFound
+		}
+		return nil, err
+	}
+	return approval, nil
+}
+
+// List returns approvals in the given status, or all approvals when status
+// is empty.
+func (s *Service) List(ctx context.Context, status models.ApprovalStatus) ([]*models.Approval, error) {
+	return s.repo.List(ctx, status)
+}
+
+// Approve sends the approval input to the agent pane and marks the
+// approval approved.
+func (s *Service) Approve(ctx context.Context, id string, opts ResolveOptions) (*models.Approval, error) {
+	if opts.Input == "" {
+		opts.Input = DefaultApproveInput
+	}
+	return s.resolve(ctx, id, models.ApprovalStatusApproved, opts)
+}
+
+// Deny sends the denial input to the agent pane and marks the approval
+// denied.
+func (s *Service) Deny(ctx context.Context, id string, opts ResolveOptions) (*models.Approval, error) {
+	if opts.Input == "" {
+		opts.Input = DefaultDenyInput
+	}
+	return s.resolve(ctx, id, models.ApprovalStatusDenied, opts)
+}
+
+func (s *Service) resolve(ctx context.Context, id string, status models.ApprovalStatus, opts ResolveOptions) (*models.Approval, error) {
+	approval, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != models.ApprovalStatusPending {
+		return nil, fmt.Errorf("%w: %s is %s", ErrApprovalNotPending, approval.ID, approval.Status)
+	}
+
+	agent, err := s.agentRepo.Get(ctx, approval.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent: %w", err)
+	}
+	if agent.TmuxPane == "" || s.panes == nil {
+		return nil, ErrNoPane
+	}
+
+	if err := s.panes.SendKeys(ctx, agent.TmuxPane, opts.Input, true, !opts.NoEnter); err != nil {
+		return nil, fmt.Errorf("failed to send input to agent: %w", err)
+	}
+
+	resolvedBy := opts.ResolvedBy
+	if resolvedBy == "" {
+		resolvedBy = Resolver(ctx)
+	}
+	if err := s.repo.UpdateStatus(ctx, approval.ID, status, resolvedBy); err != nil {
+		return nil, err
+	}
+
+	resolvedAt := s.now()
+	approval.Status = status
+	approval.ResolvedBy = resolvedBy
+	approval.ResolvedAt = &resolvedAt
+
+	eventType := models.EventTypeApprovalApproved
+	if status == models.ApprovalStatusDenied {
+		eventType = models.EventTypeApprovalDenied
+	}
+	s.publish(ctx, eventType, approval, "", opts.Input)
+	s.logger.Info().
+		Str("approval_id", approval.ID).
+		Str("agent_id", approval.AgentID).
+		Str("status", string(status)).
+		Str("resolved_by", resolvedBy).
+		Msg("approval resolved")
+
+	return approval, nil
+}
+
+// captureExcerpt returns the trailing lines of the agent pane, redacted.
+// Capture failures are logged and yield an empty excerpt so the approval is
+// still recorded.
+func (s *Service) captureExcerpt(ctx context.Context, agent *models.Agent) string {
+	if s.panes == nil || agent.TmuxPane == "" {
+		return ""
+	}
+
+	content, err := s.panes.CapturePane(ctx, agent.TmuxPane, false)
+	if err != nil {
+		s.logger.Debug().Err(err).Str("agent_id", agent.ID).Msg("failed to capture approval prompt")
+		return ""
+	}
+
+	excerpt := PromptExcerpt(content, defaultExcerptLines)
+	if s.redactor != nil {
+		excerpt = s.redactor.Redact(excerpt)
+	}
+	return excerpt
+}
+
+// matchRule finds the approval rule for the agent's workspace that applies
+// to the prompt, honoring the global kill switch.
+func (s *Service) matchRule(ctx context.Context, agent *models.Agent, excerpt string) (config.ApprovalRule, bool) {
+	if s.cfg == nil || s.cfg.AgentDefaults.DisableAutoApproval {
+		return config.ApprovalRule{}, false
+	}
+
+	var ws *models.Workspace
+	if s.wsRepo != nil {
+		found, err := s.wsRepo.Get(ctx, agent.WorkspaceID)
+		if err != nil {
+			s.logger.Debug().Err(err).Str("agent_id", agent.ID).Msg("failed to load workspace for approval rules")
+		} else {
+			ws = found
+		}
+	}
+
+	return s.cfg.ApprovalPolicyForWorkspace(ws).Match(models.ApprovalRequestTypePrompt, excerpt)
+}
+
+func (s *Service) publish(ctx context.Context, eventType models.EventType, approval *models.Approval, excerpt, input string) {
+	if s.publisher == nil {
+		return
+	}
+
+	payload := models.ApprovalPayload{
+		ApprovalID:    approval.ID,
+		AgentID:       approval.AgentID,
+		RequestType:   approval.RequestType,
+		Status:        approval.Status,
+		PromptExcerpt: excerpt,
+		Input:         input,
+		ResolvedBy:    approval.ResolvedBy,
+	}
+
+	event := &models.Event{
+		Type:       eventType,
+		EntityType: models.EntityTypeAgent,
+		EntityID:   approval.AgentID,
+	}
+	if data, err := json.Marshal(payload); err == nil {
+		event.Payload = data
+	}
+
+	s.publisher.Publish(ctx, event)
+}
+
+// PromptExcerpt returns the last maxLines lines of pane content, ignoring
+// trailing blank lines.
+func PromptExcerpt(content string, maxLines int) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	lines = lines[:end]
+
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
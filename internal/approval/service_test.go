@@ -0,0 +1,311 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+)
+
+const approvalPane = `Editing internal/foo.go
+
+Claude wants to run: go test ./...
+
+Do you want to run this command? [y/n]
+
+
+`
+
+type sentKeys struct {
+	target string
+	keys   string
+	enter  bool
+}
+
+type fakePanes struct {
+	mu      sync.Mutex
+	content string
+	sent    []sentKeys
+}
+
+func (f *fakePanes) CapturePane(ctx context.Context, target string, history bool) (string, error) {
+	return f.content, nil
+}
+
+func (f *fakePanes) SendKeys(ctx context.Context, target, keys string, literal, enter bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, sentKeys{target: target, keys: keys, enter: enter})
+	return nil
+}
+
+type approvalTestEnv struct {
+	repo      *db.ApprovalRepository
+	agent     *models.Agent
+	workspace *models.Workspace
+	panes     *fakePanes
+	database  *db.DB
+}
+
+func newApprovalTestEnv(t *testing.T) *approvalTestEnv {
+	t.Helper()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "alpha", RepoPath: "/tmp/alpha", TmuxSession: "alpha"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agent := &models.Agent{
+		WorkspaceID: ws.ID,
+		Type:        models.AgentTypeClaudeCode,
+		TmuxPane:    "alpha:0.1",
+		State:       models.AgentStateAwaitingApproval,
+		StateInfo:   models.StateInfo{State: models.AgentStateAwaitingApproval, Confidence: models.StateConfidenceHigh},
+	}
+	if err := db.NewAgentRepository(database).Create(ctx, agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	return &approvalTestEnv{
+		repo:      db.NewApprovalRepository(database),
+		agent:     agent,
+		workspace: ws,
+		panes:     &fakePanes{content: approvalPane},
+		database:  database,
+	}
+}
+
+func (e *approvalTestEnv) service(opts ...ServiceOption) *Service {
+	return NewService(e.repo, db.NewAgentRepository(e.database), e.panes, opts...)
+}
+
+func (e *approvalTestEnv) approvalChange() state.StateChange {
+	return state.StateChange{
+		AgentID:       e.agent.ID,
+		PreviousState: models.AgentStateWorking,
+		CurrentState:  models.AgentStateAwaitingApproval,
+		StateInfo:     models.StateInfo{State: models.AgentStateAwaitingApproval, Reason: "Approval prompt detected"},
+		Timestamp:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestHandleStateChangeRecordsApproval(t *testing.T) {
+	env := newApprovalTestEnv(t)
+	publisher := events.NewInMemoryPublisher()
+	var published []*models.Event
+	var mu sync.Mutex
+	if err := publisher.Subscribe("test", events.Filter{}, func(event *models.Event) {
+		mu.Lock()
+		published = append(published, event)
+		mu.Unlock()
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	service := env.service(WithPublisher(publisher))
+	ctx := context.Background()
+
+	approval, err := service.HandleStateChange(ctx, env.approvalChange())
+	if err != nil {
+		t.Fatalf("HandleStateChange failed: %v", err)
+	}
+	if approval == nil || approval.Status != models.ApprovalStatusPending || approval.RequestType != models.ApprovalRequestTypePrompt {
+		t.Fatalf("expected pending prompt approval, got %+v", approval)
+	}
+
+	stored, err := service.Get(ctx, approval.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	var details models.PromptApprovalDetails
+	if err := json.Unmarshal(stored.RequestDetails, &details); err != nil {
+		t.Fatalf("decode details: %v", err)
+	}
+	if !strings.HasSuffix(details.PromptExcerpt, "Do you want to run this command? [y/n]") {
+		t.Fatalf("unexpected excerpt %q", details.PromptExcerpt)
+	}
+	if details.Pane != env.agent.TmuxPane || !details.DetectedAt.Equal(env.approvalChange().Timestamp) {
+		t.Fatalf("unexpected details %+v", details)
+	}
+	if len(env.panes.sent) != 0 {
+		t.Fatalf("expected no keys without rules, got %+v", env.panes.sent)
+	}
+
+	mu.Lock()
+	if len(published) != 1 || published[0].Type != models.EventTypeApprovalRequested || published[0].EntityID != env.agent.ID {
+		t.Fatalf("expected approval.requested event, got %+v", published)
+	}
+	mu.Unlock()
+
+	// A repeated transition while the approval is pending must not duplicate it.
+	again, err := service.HandleStateChange(ctx, env.approvalChange())
+	if err != nil || again != nil {
+		t.Fatalf("expected duplicate transition to be ignored, got %+v, %v", again, err)
+	}
+
+	// Non-approval transitions are ignored.
+	change := env.approvalChange()
+	change.CurrentState = models.AgentStateIdle
+	if got, err := service.HandleStateChange(ctx, change); err != nil || got != nil {
+		t.Fatalf("expected idle transition to be ignored, got %+v, %v", got, err)
+	}
+
+	pending, err := service.List(ctx, models.ApprovalStatusPending)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending approval, got %d", len(pending))
+	}
+}
+
+func TestApproveAndDenySendKeys(t *testing.T) {
+	env := newApprovalTestEnv(t)
+	service := env.service()
+	ctx := context.Background()
+
+	approval, err := service.HandleStateChange(ctx, env.approvalChange())
+	if err != nil {
+		t.Fatalf("HandleStateChange failed: %v", err)
+	}
+
+	approved, err := service.Approve(ctx, approval.ID, ResolveOptions{})
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if approved.Status != models.ApprovalStatusApproved || approved.ResolvedBy != audit.LocalActor() || approved.ResolvedAt == nil {
+		t.Fatalf("unexpected resolved approval %+v", approved)
+	}
+	want := sentKeys{target: env.agent.TmuxPane, keys: "y", enter: true}
+	if len(env.panes.sent) != 1 || env.panes.sent[0] != want {
+		t.Fatalf("expected %+v sent, got %+v", want, env.panes.sent)
+	}
+
+	if _, err := service.Deny(ctx, approval.ID, ResolveOptions{}); !errors.Is(err, ErrApprovalNotPending) {
+		t.Fatalf("expected ErrApprovalNotPending, got %v", err)
+	}
+	if _, err := service.Approve(ctx, "missing", ResolveOptions{}); !errors.Is(err, ErrApprovalNotFound) {
+		t.Fatalf("expected ErrApprovalNotFound, got %v", err)
+	}
+
+	// A second prompt is denied with custom input and no Enter.
+	change := env.approvalChange()
+	second, err := service.HandleStateChange(ctx, change)
+	if err != nil || second == nil {
+		t.Fatalf("expected second approval, got %+v, %v", second, err)
+	}
+	denied, err := service.Deny(ctx, second.ID, ResolveOptions{Input: "3", NoEnter: true, ResolvedBy: "alice"})
+	if err != nil {
+		t.Fatalf("Deny failed: %v", err)
+	}
+	if denied.Status != models.ApprovalStatusDenied || denied.ResolvedBy != "alice" {
+		t.Fatalf("unexpected denied approval %+v", denied)
+	}
+	want = sentKeys{target: env.agent.TmuxPane, keys: "3", enter: false}
+	if len(env.panes.sent) != 2 || env.panes.sent[1] != want {
+		t.Fatalf("expected %+v sent, got %+v", want, env.panes.sent)
+	}
+
+	// Without ResolvedBy, the audit actor of the context resolves it.
+	third, err := service.HandleStateChange(ctx, env.approvalChange())
+	if err != nil || third == nil {
+		t.Fatalf("expected third approval, got %+v, %v", third, err)
+	}
+	approved, err = service.Approve(audit.ContextWithActor(ctx, "token:3f9a2c1e"), third.ID, ResolveOptions{})
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if approved.ResolvedBy != "token:3f9a2c1e" {
+		t.Fatalf("expected the context's actor recorded, got %q", approved.ResolvedBy)
+	}
+}
+
+func TestHandleStateChangeAutoApproval(t *testing.T) {
+	env := newApprovalTestEnv(t)
+	ctx := context.Background()
+
+	cfg := config.DefaultConfig()
+	cfg.WorkspaceOverrides = []config.WorkspaceOverrideConfig{{
+		Name:           env.workspace.Name,
+		ApprovalPolicy: config.ApprovalPolicyCustom,
+		ApprovalRules: []config.ApprovalRule{
+			{RequestType: "prompt", Action: config.ApprovalRuleActionApprove, Pattern: `run: go test`},
+		},
+	}}
+	service := env.service(WithConfig(cfg, db.NewWorkspaceRepository(env.database)))
+
+	approval, err := service.HandleStateChange(ctx, env.approvalChange())
+	if err != nil {
+		t.Fatalf("HandleStateChange failed: %v", err)
+	}
+	if approval.Status != models.ApprovalStatusApproved || approval.ResolvedBy != ResolvedByPolicy {
+		t.Fatalf("expected policy approval, got %+v", approval)
+	}
+	if len(env.panes.sent) != 1 || env.panes.sent[0].keys != DefaultApproveInput {
+		t.Fatalf("expected approve input sent, got %+v", env.panes.sent)
+	}
+
+	// A prompt that does not match stays pending.
+	env.panes.content = "Claude wants to run: git push --force\nDo you want to run this command? [y/n]"
+	approval, err = service.HandleStateChange(ctx, env.approvalChange())
+	if err != nil {
+		t.Fatalf("HandleStateChange failed: %v", err)
+	}
+	if approval.Status != models.ApprovalStatusPending || len(env.panes.sent) != 1 {
+		t.Fatalf("expected unmatched prompt to stay pending, got %+v (sent %+v)", approval, env.panes.sent)
+	}
+}
+
+func TestHandleStateChangeAutoApprovalKillSwitch(t *testing.T) {
+	env := newApprovalTestEnv(t)
+
+	cfg := config.DefaultConfig()
+	cfg.AgentDefaults.ApprovalPolicy = config.ApprovalPolicyCustom
+	cfg.AgentDefaults.ApprovalRules = []config.ApprovalRule{{RequestType: "*", Action: config.ApprovalRuleActionApprove}}
+	cfg.AgentDefaults.DisableAutoApproval = true
+	service := env.service(WithConfig(cfg, nil))
+
+	approval, err := service.HandleStateChange(context.Background(), env.approvalChange())
+	if err != nil {
+		t.Fatalf("HandleStateChange failed: %v", err)
+	}
+	if approval.Status != models.ApprovalStatusPending || len(env.panes.sent) != 0 {
+		t.Fatalf("expected kill switch to leave approval pending, got %+v (sent %+v)", approval, env.panes.sent)
+	}
+}
+
+func TestPromptExcerpt(t *testing.T) {
+	content := "one\ntwo\n\nthree\nfour  \n\n\n"
+	if got := PromptExcerpt(content, 2); got != "three\nfour" {
+		t.Fatalf("unexpected excerpt %q", got)
+	}
+	if got := PromptExcerpt(content, 0); got != "one\ntwo\n\nthree\nfour" {
+		t.Fatalf("unexpected full excerpt %q", got)
+	}
+	if got := PromptExcerpt("a\n\nb", 2); got != "b" {
+		t.Fatalf("expected leading blank lines to be trimmed, got %q", got)
+	}
+}
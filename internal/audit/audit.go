@@ -0,0 +1,204 @@
+// Package audit records mutating operations to the audit log.
+//
+// Services call Record once an operation has finished, whether it
+// succeeded or not. Writes are synchronous, but a failed write never fails
+// the operation: it is logged instead, at most once per interval so a
+// broken database cannot flood the log.
+package audit
+
+import (
+	"context"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/redact"
+	"github.com/opencode-ai/swarm/internal/trace"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultWarnInterval is how often write failures are logged.
+	DefaultWarnInterval = time.Minute
+
+	// MaxParameterBytes caps each recorded parameter value, so a long
+	// message stays a preview rather than a copy.
+	MaxParameterBytes = 512
+)
+
+// Recorder writes audit records. A nil Recorder records nothing, so
+// services can call it unconditionally.
+type Recorder struct {
+	repo         *db.AuditRepository
+	redactor     *redact.Redactor
+	actor        string
+	clock        clock.Clock
+	logger       zerolog.Logger
+	warnInterval time.Duration
+
+	mu         sync.Mutex
+	lastWarn   time.Time
+	suppressed int
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithRedactor sets the redactor applied to parameters and errors.
+func WithRedactor(redactor *redact.Redactor) Option {
+	return func(r *Recorder) {
+		if redactor != nil {
+			r.redactor = redactor
+		}
+	}
+}
+
+// WithActor sets the actor recorded when the context carries none.
+func WithActor(actor string) Option {
+	return func(r *Recorder) {
+		if actor != "" {
+			r.actor = actor
+		}
+	}
+}
+
+// WithClock sets the clock used for timestamps and failure throttling.
+func WithClock(c clock.Clock) Option {
+	return func(r *Recorder) {
+		r.clock = clock.OrReal(c)
+	}
+}
+
+// WithWarnInterval sets how often write failures are logged.
+func WithWarnInterval(d time.Duration) Option {
+	return func(r *Recorder) {
+		r.warnInterval = d
+	}
+}
+
+// New creates a Recorder writing to database. The default actor is the
+// current OS user.
+func New(database *db.DB, opts ...Option) *Recorder {
+	r := &Recorder{
+		repo:         db.NewAuditRepository(database),
+		redactor:     redact.Default(),
+		actor:        LocalActor(),
+		clock:        clock.Real(),
+		logger:       logging.Component("audit"),
+		warnInterval: DefaultWarnInterval,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// LocalActor returns the name of the current OS user.
+func LocalActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
+
+// Params builds a parameter map from key/value pairs, leaving out empty
+// values.
+func Params(kv ...string) map[string]string {
+	params := make(map[string]string, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] != "" {
+			params[kv[i]] = kv[i+1]
+		}
+	}
+	return params
+}
+
+type actorKey struct{}
+
+// ContextWithActor returns a context whose operations are attributed to
+// actor instead of the recorder's default.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set on ctx, if any.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// Record writes an audit record for operation on the given entity. opErr
+// is the operation's result; nil records success. Record never fails: a
+// write error is logged and dropped.
+func (r *Recorder) Record(ctx context.Context, operation string, entityType models.EntityType, entityID string, params map[string]string, opErr error) {
+	if r == nil {
+		return
+	}
+
+	record := &models.AuditRecord{
+		Timestamp:  r.clock.Now().UTC(),
+		Actor:      r.actor,
+		Operation:  operation,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Parameters: r.parameters(params),
+		Result:     models.AuditResultSuccess,
+		TraceID:    trace.FromContext(ctx),
+	}
+	if actor := ActorFromContext(ctx); actor != "" {
+		record.Actor = actor
+	}
+	if opErr != nil {
+		record.Result = models.AuditResultFailure
+		record.Error = r.redactor.Redact(opErr.Error())
+	}
+
+	// The operation's context may already be cancelled; the record is
+	// still wanted.
+	if err := r.repo.Create(context.WithoutCancel(ctx), record); err != nil {
+		r.warn(record, err)
+	}
+}
+
+// parameters redacts params and then truncates each value, so a cut
+// never splits a secret into a prefix that no longer matches.
+func (r *Recorder) parameters(params map[string]string) map[string]string {
+	out := r.redactor.RedactMap(params)
+	for k, v := range out {
+		if truncated, cut := redact.TruncateHead(v, MaxParameterBytes); cut {
+			out[k] = truncated + "..."
+		}
+	}
+	return out
+}
+
+// warn logs a failed write, throttled to one line per warnInterval.
+func (r *Recorder) warn(record *models.AuditRecord, err error) {
+	r.mu.Lock()
+	now := r.clock.Now()
+	if !r.lastWarn.IsZero() && now.Sub(r.lastWarn) < r.warnInterval {
+		r.suppressed++
+		r.mu.Unlock()
+		return
+	}
+	suppressed := r.suppressed
+	r.lastWarn = now
+	r.suppressed = 0
+	r.mu.Unlock()
+
+	r.logger.Error().
+		Err(err).
+		Str("operation", record.Operation).
+		Str("entity_id", record.EntityID).
+		Str("actor", record.Actor).
+		Int("suppressed", suppressed).
+		Msg("failed to write audit record; operation was not audited")
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/trace"
+	"github.com/rs/zerolog"
+)
+
+const testSecret = "sk-ant-REDACTED"
+
+func openTestDB(t *testing.T) *db.DB {
+	t.Helper()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := database.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func TestRecord(t *testing.T) {
+	database := openTestDB(t)
+	fake := clock.NewFake(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	recorder := New(database, WithActor("dana"), WithClock(fake))
+
+	ctx := trace.WithID(context.Background(), "trace-1")
+	recorder.Record(ctx, "send_message", models.EntityTypeAgent, "agent-1", map[string]string{"text": "use " + testSecret}, nil)
+	recorder.Record(ContextWithActor(ctx, "token:ab12"), "kill_agent", models.EntityTypeAgent, "agent-1", nil, errors.New("kill failed for "+testSecret))
+
+	records, err := db.NewAuditRepository(database).Query(context.Background(), db.AuditQuery{})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	kill, send := records[0], records[1]
+	if send.Actor != "dana" || send.Result != models.AuditResultSuccess || send.TraceID != "trace-1" || !send.Timestamp.Equal(fake.Now()) {
+		t.Errorf("unexpected send record %+v", send)
+	}
+	if strings.Contains(send.Parameters["text"], testSecret) {
+		t.Errorf("secret not redacted from parameters: %q", send.Parameters["text"])
+	}
+	if kill.Actor != "token:ab12" || kill.Result != models.AuditResultFailure || kill.Error == "" || strings.Contains(kill.Error, testSecret) {
+		t.Errorf("unexpected kill record %+v", kill)
+	}
+}
+
+func TestRecordFailureIsLoggedNotReturned(t *testing.T) {
+	database := openTestDB(t)
+	fake := clock.NewFake(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	recorder := New(database, WithClock(fake))
+	var logs bytes.Buffer
+	recorder.logger = zerolog.New(&logs)
+
+	// Break the writer; Record must still return normally.
+	database.Close()
+	for i := 0; i < 3; i++ {
+		recorder.Record(context.Background(), "spawn_agent", models.EntityTypeAgent, "agent-1", nil, nil)
+	}
+	if n := strings.Count(logs.String(), "\n"); n != 1 {
+		t.Fatalf("expected one throttled log line, got %d: %s", n, logs.String())
+	}
+
+	fake.Advance(DefaultWarnInterval)
+	recorder.Record(context.Background(), "spawn_agent", models.EntityTypeAgent, "agent-1", nil, nil)
+	if !strings.Contains(logs.String(), `"suppressed":2`) {
+		t.Fatalf("expected suppressed count in second log line: %s", logs.String())
+	}
+
+	var nilRecorder *Recorder
+	nilRecorder.Record(context.Background(), "spawn_agent", models.EntityTypeAgent, "agent-1", nil, nil)
+}
@@ -0,0 +1,256 @@
+// Package bundle writes and reads agent bundles: a tar.gz archive holding
+// one JSONL file per section of an agent's data and a manifest describing
+// them.
+//
+// Sections are streamed row by row through the redactor into spool files,
+// so memory stays bounded however large a section is; the archive is
+// assembled once every section has been counted, with the manifest as its
+// first member so it can be read without unpacking the rest.
+package bundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/redact"
+)
+
+const (
+	// FormatVersion is the version of the bundle layout and manifest.
+	FormatVersion = 1
+
+	// ManifestName is the archive member holding the manifest.
+	ManifestName = "manifest.json"
+)
+
+// ErrNotBundle is returned when an archive does not start with a manifest.
+var ErrNotBundle = errors.New("not an agent bundle")
+
+// Rows yields the rows of a section one at a time.
+type Rows interface {
+	Next() bool
+	Row() any
+	Err() error
+}
+
+// Section is one JSONL member of a bundle.
+type Section struct {
+	// Name is the section name; the member is written as <name>.jsonl.
+	Name string
+	// Schema is the version of the row layout.
+	Schema int
+	// Rows yields the section's rows. An error from Rows fails the bundle.
+	Rows Rows
+	// Stamp returns the time and trace ID of a row, zero or empty when it
+	// has none. It may be nil.
+	Stamp func(row any) (time.Time, string)
+	// Unavailable records why the section's source could not be read. The
+	// section is written empty and the reason noted in the manifest.
+	Unavailable error
+}
+
+// SectionInfo describes a section in the manifest.
+type SectionInfo struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Schema int    `json:"schema_version"`
+	Count  int    `json:"count"`
+	Bytes  int64  `json:"bytes"`
+	Error  string `json:"error,omitempty"`
+}
+
+// TimeRange is the span of the row timestamps in a bundle.
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Manifest describes a bundle.
+type Manifest struct {
+	Version         int           `json:"version"`
+	CreatedAt       time.Time     `json:"created_at"`
+	AgentID         string        `json:"agent_id"`
+	SwarmVersion    string        `json:"swarm_version,omitempty"`
+	DBSchemaVersion int           `json:"db_schema_version,omitempty"`
+	RedactionRules  []string      `json:"redaction_rules"`
+	Sections        []SectionInfo `json:"sections"`
+	TimeRange       *TimeRange    `json:"time_range,omitempty"`
+	TraceIDs        []string      `json:"trace_ids"`
+}
+
+// Section returns the manifest entry for name, or nil.
+func (m *Manifest) Section(name string) *SectionInfo {
+	for i := range m.Sections {
+		if m.Sections[i].Name == name {
+			return &m.Sections[i]
+		}
+	}
+	return nil
+}
+
+// Write streams sections into a tar.gz written to w. The caller fills in
+// the identifying fields of manifest; Write sets the version, redaction
+// rules, section entries, time range, and trace IDs. Every row is redacted
+// with redactor before it is written.
+func Write(w io.Writer, manifest *Manifest, sections []Section, redactor *redact.Redactor) error {
+	manifest.Version = FormatVersion
+	manifest.RedactionRules = redactor.Labels()
+	if manifest.RedactionRules == nil {
+		manifest.RedactionRules = []string{}
+	}
+	manifest.Sections = make([]SectionInfo, 0, len(sections))
+	manifest.TimeRange = nil
+
+	traceIDs := make(map[string]struct{})
+	spools := make([]*os.File, 0, len(sections))
+	defer func() {
+		for _, spool := range spools {
+			spool.Close()
+			os.Remove(spool.Name())
+		}
+	}()
+
+	for _, section := range sections {
+		spool, err := os.CreateTemp("", "swarm-bundle-*.jsonl")
+		if err != nil {
+			return fmt.Errorf("failed to create spool file: %w", err)
+		}
+		spools = append(spools, spool)
+
+		info := SectionInfo{Name: section.Name, File: section.Name + ".jsonl", Schema: section.Schema}
+		if section.Unavailable != nil {
+			info.Error = section.Unavailable.Error()
+		} else if err := spoolSection(spool, section, redactor, &info, manifest, traceIDs); err != nil {
+			return fmt.Errorf("failed to write %s: %w", section.Name, err)
+		}
+		manifest.Sections = append(manifest.Sections, info)
+	}
+
+	manifest.TraceIDs = make([]string, 0, len(traceIDs))
+	for id := range traceIDs {
+		manifest.TraceIDs = append(manifest.TraceIDs, id)
+	}
+	sort.Strings(manifest.TraceIDs)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if err := writeHeader(tw, ManifestName, int64(len(data)), manifest.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	for i, spool := range spools {
+		info := manifest.Sections[i]
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := writeHeader(tw, info.File, info.Bytes, manifest.CreatedAt); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, spool); err != nil {
+			return fmt.Errorf("failed to write %s: %w", info.File, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// spoolSection writes the redacted rows of section to spool as JSONL.
+func spoolSection(spool *os.File, section Section, redactor *redact.Redactor, info *SectionInfo, manifest *Manifest, traceIDs map[string]struct{}) error {
+	buf := bufio.NewWriter(spool)
+	for section.Rows.Next() {
+		row := section.Rows.Row()
+		if section.Stamp != nil {
+			at, traceID := section.Stamp(row)
+			if !at.IsZero() {
+				at = at.UTC()
+				if manifest.TimeRange == nil {
+					manifest.TimeRange = &TimeRange{Start: at, End: at}
+				} else if at.Before(manifest.TimeRange.Start) {
+					manifest.TimeRange.Start = at
+				} else if at.After(manifest.TimeRange.End) {
+					manifest.TimeRange.End = at
+				}
+			}
+			if traceID != "" {
+				traceIDs[traceID] = struct{}{}
+			}
+		}
+
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if data, err = redactor.RedactJSON(data); err != nil {
+			return err
+		}
+		data = append(data, '\n')
+		if _, err := buf.Write(data); err != nil {
+			return err
+		}
+		info.Count++
+		info.Bytes += int64(len(data))
+	}
+	if err := section.Rows.Err(); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+func writeHeader(tw *tar.Writer, name string, size int64, modTime time.Time) error {
+	return tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  modTime,
+		Typeflag: tar.TypeReg,
+		Format:   tar.FormatPAX,
+	})
+}
+
+// ReadManifest reads the manifest from the start of a bundle without
+// reading the sections.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotBundle, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotBundle, err)
+	}
+	if header.Name != ManifestName {
+		return nil, fmt.Errorf("%w: first member is %q", ErrNotBundle, header.Name)
+	}
+
+	var manifest Manifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", manifest.Version)
+	}
+	return &manifest, nil
+}
@@ -0,0 +1,158 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/redact"
+)
+
+type testRow struct {
+	At      time.Time `json:"at"`
+	TraceID string    `json:"trace_id,omitempty"`
+	Text    string    `json:"text"`
+}
+
+type sliceRows struct {
+	rows []testRow
+	pos  int
+}
+
+func (r *sliceRows) Next() bool {
+	if r.pos >= len(r.rows) {
+		return false
+	}
+	r.pos++
+	return true
+}
+
+func (r *sliceRows) Row() any   { return r.rows[r.pos-1] }
+func (r *sliceRows) Err() error { return nil }
+
+func stampTestRow(row any) (time.Time, string) {
+	r := row.(testRow)
+	return r.At, r.TraceID
+}
+
+func TestWriteAndReadManifest(t *testing.T) {
+	secret := "sk-ant-REDACTED"
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	sections := []Section{
+		{
+			Name:   "lines",
+			Schema: 2,
+			Rows: &sliceRows{rows: []testRow{
+				{At: start.Add(time.Hour), TraceID: "trace-b", Text: "first"},
+				{At: start, TraceID: "trace-a", Text: "uses " + secret},
+				{At: start.Add(2 * time.Hour), TraceID: "trace-a", Text: "last"},
+			}},
+			Stamp: stampTestRow,
+		},
+		{Name: "empty", Schema: 1, Rows: &sliceRows{}},
+		{Name: "missing", Schema: 1, Unavailable: errors.New("not configured")},
+	}
+
+	var buf bytes.Buffer
+	manifest := &Manifest{CreatedAt: start.Add(3 * time.Hour), AgentID: "agent-1"}
+	if err := Write(&buf, manifest, sections, redact.Default()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	members := readMembers(t, buf.Bytes())
+	wantNames := []string{ManifestName, "lines.jsonl", "empty.jsonl", "missing.jsonl"}
+	if len(members) != len(wantNames) {
+		t.Fatalf("expected %d members, got %d", len(wantNames), len(members))
+	}
+	for i, name := range wantNames {
+		if members[i].name != name {
+			t.Errorf("member %d = %q, want %q", i, members[i].name, name)
+		}
+		if strings.Contains(members[i].data, secret) {
+			t.Errorf("secret found in %s", members[i].name)
+		}
+	}
+	if lines := strings.Count(members[1].data, "\n"); lines != 3 {
+		t.Errorf("expected 3 lines in lines.jsonl, got %d", lines)
+	}
+	if !strings.Contains(members[1].data, redact.Marker("anthropic_key")) {
+		t.Errorf("expected redaction marker in lines.jsonl: %s", members[1].data)
+	}
+
+	got, err := ReadManifest(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if got.Version != FormatVersion || got.AgentID != "agent-1" {
+		t.Errorf("unexpected manifest: %+v", got)
+	}
+	lines := got.Section("lines")
+	if lines == nil || lines.Count != 3 || lines.Schema != 2 || lines.Bytes != int64(len(members[1].data)) {
+		t.Errorf("unexpected lines section: %+v", lines)
+	}
+	if missing := got.Section("missing"); missing == nil || missing.Error != "not configured" || missing.Count != 0 {
+		t.Errorf("unexpected missing section: %+v", missing)
+	}
+	if got.TimeRange == nil || !got.TimeRange.Start.Equal(start) || !got.TimeRange.End.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("unexpected time range: %+v", got.TimeRange)
+	}
+	if strings.Join(got.TraceIDs, ",") != "trace-a,trace-b" {
+		t.Errorf("unexpected trace IDs: %v", got.TraceIDs)
+	}
+	if len(got.RedactionRules) == 0 {
+		t.Error("expected redaction rules in manifest")
+	}
+}
+
+func TestReadManifestRejectsOtherArchives(t *testing.T) {
+	if _, err := ReadManifest(strings.NewReader("plain text")); !errors.Is(err, ErrNotBundle) {
+		t.Errorf("expected ErrNotBundle for non-gzip input, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "other.txt", Mode: 0644, Size: 2}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte("hi"))
+	tw.Close()
+	gz.Close()
+	if _, err := ReadManifest(&buf); !errors.Is(err, ErrNotBundle) {
+		t.Errorf("expected ErrNotBundle for archive without manifest, got %v", err)
+	}
+}
+
+type member struct {
+	name string
+	data string
+}
+
+func readMembers(t *testing.T, data []byte) []member {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	var members []member
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return members
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("read %s: %v", header.Name, err)
+		}
+		members = append(members, member{name: header.Name, data: string(content)})
+	}
+}
@@ -0,0 +1,129 @@
+package checkpoint
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// writeArchive writes files to w as a gzipped tarball and returns the
+// total size of their contents.
+func writeArchive(w io.Writer, files []sessionFile) (int64, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	var total int64
+	for _, file := range files {
+		hdr, err := tar.FileInfoHeader(file.info, "")
+		if err != nil {
+			return 0, err
+		}
+		hdr.Name = file.archiveName()
+		f, err := os.Open(file.path)
+		if err != nil {
+			return 0, err
+		}
+		// Session files may grow while they are archived; only the size
+		// recorded in the header is copied.
+		if err := tw.WriteHeader(hdr); err != nil {
+			_ = f.Close()
+			return 0, err
+		}
+		n, err := io.CopyN(tw, f, hdr.Size)
+		_ = f.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to archive %s: %w", file.path, err)
+		}
+		total += n
+	}
+
+	if err := tw.Close(); err != nil {
+		return 0, err
+	}
+	if err := gz.Close(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// restorePath maps an archive entry to where it is written for vars,
+// refusing entries that would land outside their pattern's base.
+func restorePath(cp *Checkpoint, vars Vars, name string) (string, error) {
+	index, rel, hasRel := strings.Cut(name, "/")
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i >= len(cp.Patterns) {
+		return "", fmt.Errorf("checkpoint %s has an unexpected entry %q", cp.Name, name)
+	}
+	base := patternBase(vars.Expand(cp.Patterns[i]))
+	if !hasRel {
+		return base, nil
+	}
+	rel = filepath.Clean(filepath.FromSlash(rel))
+	if rel == "." || filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("checkpoint %s has an unsafe entry %q", cp.Name, name)
+	}
+	return filepath.Join(base, rel), nil
+}
+
+type pathRewrite struct {
+	old, new []byte
+}
+
+// pathRewrites lists the path replacements needed to move a session from
+// one set of vars to another, longest old path first so a working
+// directory inside the home directory is rewritten as a whole.
+func pathRewrites(from, to Vars) []pathRewrite {
+	var rewrites []pathRewrite
+	add := func(old, new string) {
+		if old != "" && old != new {
+			rewrites = append(rewrites, pathRewrite{old: []byte(old), new: []byte(new)})
+		}
+	}
+	add(from.WorkDir, to.WorkDir)
+	add(from.Home, to.Home)
+	sort.SliceStable(rewrites, func(i, j int) bool {
+		return len(rewrites[i].old) > len(rewrites[j].old)
+	})
+	return rewrites
+}
+
+// rewritePaths applies rewrites to text data. Binary data is returned as
+// it is, with a warning when it mentions a path that needed rewriting.
+func rewritePaths(data []byte, rewrites []pathRewrite) ([]byte, bool, string) {
+	var found []string
+	for _, rw := range rewrites {
+		if bytes.Contains(data, rw.old) {
+			found = append(found, string(rw.old))
+		}
+	}
+	if len(found) == 0 {
+		return data, false, ""
+	}
+	if !isText(data) {
+		return data, false, "binary file mentions " + strings.Join(found, ", ") + "; paths were not rewritten"
+	}
+
+	// Replace through placeholders so a new path containing an old one is
+	// not rewritten twice.
+	placeholders := make([][]byte, len(rewrites))
+	for i, rw := range rewrites {
+		placeholders[i] = []byte(fmt.Sprintf("\x00swarm-checkpoint-%d\x00", i))
+		data = bytes.ReplaceAll(data, rw.old, placeholders[i])
+	}
+	for i, rw := range rewrites {
+		data = bytes.ReplaceAll(data, placeholders[i], rw.new)
+	}
+	return data, true, ""
+}
+
+func isText(data []byte) bool {
+	return utf8.Valid(data) && bytes.IndexByte(data, 0) < 0
+}
@@ -0,0 +1,131 @@
+package checkpoint
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Vars are the values substituted into session patterns. Patterns may use
+// {home} for the agent's home directory, {workdir} for its working
+// directory, and {claude_project} for the directory name Claude Code
+// derives from the working directory under ~/.claude/projects.
+type Vars struct {
+	Home    string `json:"home"`
+	WorkDir string `json:"workdir"`
+}
+
+// Expand substitutes v into pattern.
+func (v Vars) Expand(pattern string) string {
+	replacer := strings.NewReplacer(
+		"{home}", v.Home,
+		"{workdir}", v.WorkDir,
+		"{claude_project}", claudeProjectDir(v.WorkDir),
+	)
+	return filepath.Clean(replacer.Replace(pattern))
+}
+
+// claudeProjectDir mirrors how Claude Code names a project's session
+// directory: every character that is not a letter or digit becomes '-'.
+func claudeProjectDir(workDir string) string {
+	var b strings.Builder
+	for _, r := range workDir {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// patternBase returns the path archived files are relative to: the parent
+// of the first path element with a glob, or the whole path when there is
+// none. Restoring under a new base relocates the files.
+func patternBase(expanded string) string {
+	parts := strings.Split(filepath.ToSlash(expanded), "/")
+	for i, part := range parts {
+		if strings.ContainsAny(part, "*?[") {
+			base := strings.Join(parts[:i], "/")
+			if base == "" && strings.HasPrefix(expanded, "/") {
+				base = "/"
+			}
+			return filepath.FromSlash(base)
+		}
+	}
+	return expanded
+}
+
+// sessionFile is a file matched by one of a checkpoint's patterns.
+type sessionFile struct {
+	pattern int
+	rel     string
+	path    string
+	info    fs.FileInfo
+}
+
+// archiveName is the file's name inside the archive: the index of the
+// pattern that matched it and its path relative to the pattern's base.
+// A pattern naming a single file leaves only the index.
+func (f sessionFile) archiveName() string {
+	return filepath.ToSlash(filepath.Join(strconv.Itoa(f.pattern), f.rel))
+}
+
+// matchSessionFiles returns the regular files matched by patterns, with
+// matched directories walked recursively. Symlinks are skipped, and a
+// file matched by several patterns is kept once.
+func matchSessionFiles(patterns []string, vars Vars) ([]sessionFile, error) {
+	seen := make(map[string]bool)
+	var files []sessionFile
+	for i, pattern := range patterns {
+		expanded := vars.Expand(pattern)
+		base := patternBase(expanded)
+		matches, err := filepath.Glob(expanded)
+		if err != nil {
+			return nil, &PatternError{Pattern: pattern, Err: err}
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !entry.Type().IsRegular() || seen[path] {
+					return nil
+				}
+				info, err := entry.Info()
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(base, path)
+				if err != nil {
+					return err
+				}
+				seen[path] = true
+				files = append(files, sessionFile{pattern: i, rel: rel, path: path, info: info})
+				return nil
+			})
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// PatternError reports a session pattern that is not a valid glob.
+type PatternError struct {
+	Pattern string
+	Err     error
+}
+
+func (e *PatternError) Error() string {
+	return "invalid session pattern " + e.Pattern + ": " + e.Err.Error()
+}
+
+func (e *PatternError) Unwrap() error {
+	return e.Err
+}
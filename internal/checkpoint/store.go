@@ -0,0 +1,309 @@
+// Package checkpoint archives the on-disk session state of agent CLIs so
+// an agent can be restored with its conversation after a restart, a node
+// change, or a move to another workspace.
+//
+// Which files make up a session is given per agent type as glob patterns;
+// see Vars for the placeholders they may use. Each checkpoint is a gzipped
+// tarball with a metadata entry beside it.
+package checkpoint
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/fsperm"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Checkpoint errors.
+var (
+	ErrNotFound    = errors.New("checkpoint not found")
+	ErrExists      = errors.New("checkpoint already exists")
+	ErrNoFiles     = errors.New("no session files match the agent's session patterns")
+	ErrInvalidName = errors.New("checkpoint names may only contain letters, digits, '.', '_' and '-'")
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Checkpoint is the metadata recorded for one archive.
+type Checkpoint struct {
+	Name        string           `json:"name"`
+	AgentID     string           `json:"agent_id"`
+	AgentType   models.AgentType `json:"agent_type"`
+	WorkspaceID string           `json:"workspace_id,omitempty"`
+	Vars        Vars             `json:"vars"`
+	Patterns    []string         `json:"patterns"`
+	Files       int              `json:"files"`
+	Bytes       int64            `json:"bytes"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// RestoreResult reports what a restore wrote.
+type RestoreResult struct {
+	Files     int      `json:"files"`
+	Bytes     int64    `json:"bytes"`
+	Rewritten int      `json:"rewritten"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// Store keeps checkpoints under a directory:
+//
+//	<name>.tar.gz  session files
+//	<name>.json    metadata
+type Store struct {
+	dir   string
+	clock clock.Clock
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithClock sets the clock used for creation times.
+func WithClock(c clock.Clock) Option {
+	return func(s *Store) {
+		s.clock = c
+	}
+}
+
+// NewStore returns a store rooted at dir.
+func NewStore(dir string, opts ...Option) *Store {
+	s := &Store{dir: dir}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.clock = clock.OrReal(s.clock)
+	return s
+}
+
+// Dir returns the store's root directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// ArchivePath returns where the files of checkpoint name are stored.
+func (s *Store) ArchivePath(name string) string {
+	return filepath.Join(s.dir, name+".tar.gz")
+}
+
+func (s *Store) metaPath(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// Create archives the files matched by cp.Patterns under cp.Vars and
+// records cp, filling in its file count, size, and creation time. Names
+// are never reused; ErrExists is returned for a name already taken.
+func (s *Store) Create(cp *Checkpoint) error {
+	if cp == nil || strings.TrimSpace(cp.AgentID) == "" {
+		return fmt.Errorf("checkpoint agent id is required")
+	}
+	if !namePattern.MatchString(cp.Name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, cp.Name)
+	}
+	if _, err := os.Stat(s.metaPath(cp.Name)); err == nil {
+		return fmt.Errorf("%w: %s", ErrExists, cp.Name)
+	}
+
+	files, err := matchSessionFiles(cp.Patterns, cp.Vars)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return ErrNoFiles
+	}
+
+	if err := fsperm.MkdirAll(s.dir); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := writeArchive(tmp, files)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.ArchivePath(cp.Name)); err != nil {
+		return err
+	}
+
+	cp.Files = len(files)
+	cp.Bytes = size
+	if cp.CreatedAt.IsZero() {
+		cp.CreatedAt = s.clock.Now().UTC()
+	}
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.metaPath(cp.Name), append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint metadata: %w", err)
+	}
+	return nil
+}
+
+// List returns checkpoints oldest first, only those of agentID if it is set.
+func (s *Store) List(agentID string) ([]*Checkpoint, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Checkpoint{}, nil
+		}
+		return nil, err
+	}
+
+	checkpoints := make([]*Checkpoint, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		cp, err := s.readMeta(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if agentID != "" && cp.AgentID != agentID {
+			continue
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	sort.Slice(checkpoints, func(i, j int) bool {
+		if !checkpoints[i].CreatedAt.Equal(checkpoints[j].CreatedAt) {
+			return checkpoints[i].CreatedAt.Before(checkpoints[j].CreatedAt)
+		}
+		return checkpoints[i].Name < checkpoints[j].Name
+	})
+	return checkpoints, nil
+}
+
+// Get returns the checkpoint called name.
+func (s *Store) Get(name string) (*Checkpoint, error) {
+	name = strings.TrimSpace(name)
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	cp, err := s.readMeta(s.metaPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, err
+	}
+	return cp, nil
+}
+
+// Restore extracts cp's files for an agent running with vars. Each file is
+// written under its pattern's base as expanded with vars, so a checkpoint
+// taken in one workspace lands where the agent in another will look for
+// it. When the home or working directory changed, occurrences of the old
+// paths in text files are rewritten to the new ones; binary files that
+// mention an old path are restored as they are, with a warning.
+func (s *Store) Restore(cp *Checkpoint, vars Vars) (*RestoreResult, error) {
+	f, err := os.Open(s.ArchivePath(cp.Name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: archive for %s missing", ErrNotFound, cp.Name)
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint archive: %w", err)
+	}
+	defer gz.Close()
+
+	rewrites := pathRewrites(cp.Vars, vars)
+	result := &RestoreResult{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to read checkpoint archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		dest, err := restorePath(cp, vars, hdr.Name)
+		if err != nil {
+			return result, err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s from checkpoint: %w", hdr.Name, err)
+		}
+
+		data, rewritten, warning := rewritePaths(data, rewrites)
+		if rewritten {
+			result.Rewritten++
+		}
+		if warning != "" {
+			result.Warnings = append(result.Warnings, dest+": "+warning)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return result, err
+		}
+		if err := os.WriteFile(dest, data, os.FileMode(hdr.Mode).Perm()); err != nil {
+			return result, err
+		}
+		if !hdr.ModTime.IsZero() {
+			_ = os.Chtimes(dest, hdr.ModTime, hdr.ModTime)
+		}
+		result.Files++
+		result.Bytes += int64(len(data))
+	}
+	return result, nil
+}
+
+func (s *Store) readMeta(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint metadata %s: %w", filepath.Base(path), err)
+	}
+	return &cp, nil
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames
+// it into place, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := fsperm.MkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,262 @@
+package checkpoint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// writeFixture creates files under root, keyed by slash-separated path.
+func writeFixture(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readTree returns the files under root, keyed by slash-separated path.
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestExpand(t *testing.T) {
+	vars := Vars{Home: "/home/dev", WorkDir: "/src/my_app"}
+	tests := map[string]string{
+		"{home}/.claude/projects/{claude_project}": "/home/dev/.claude/projects/-src-my-app",
+		"{workdir}/.swarm/../.session":             "/src/my_app/.session",
+		"{home}/.codex/sessions/*/*.jsonl":         "/home/dev/.codex/sessions/*/*.jsonl",
+	}
+	for pattern, want := range tests {
+		if got := vars.Expand(pattern); got != want {
+			t.Errorf("Expand(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestMatchSessionFiles(t *testing.T) {
+	home := t.TempDir()
+	writeFixture(t, home, map[string]string{
+		".codex/sessions/2026/01/a.jsonl": "a",
+		".codex/sessions/2026/02/b.jsonl": "b",
+		".codex/sessions/2026/02/b.txt":   "skip",
+		".codex/config.toml":              "config",
+		".codex/history.jsonl":            "history",
+	})
+	if err := os.Symlink(filepath.Join(home, ".codex/config.toml"), filepath.Join(home, ".codex/sessions/link.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns := []string{
+		"{home}/.codex/sessions/*/*/*.jsonl",
+		"{home}/.codex/history.jsonl",
+		"{home}/.codex/sessions",
+		"{home}/.missing/*",
+	}
+	files, err := matchSessionFiles(patterns, Vars{Home: home})
+	if err != nil {
+		t.Fatalf("matchSessionFiles failed: %v", err)
+	}
+
+	var got []string
+	for _, f := range files {
+		got = append(got, f.archiveName())
+	}
+	sort.Strings(got)
+	// The whole sessions directory is walked for the third pattern, minus
+	// files the first already took; the symlink is skipped.
+	want := []string{
+		"0/2026/01/a.jsonl",
+		"0/2026/02/b.jsonl",
+		"1",
+		"2/2026/02/b.txt",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("matched %v, want %v", got, want)
+	}
+
+	if _, err := matchSessionFiles([]string{"{home}/["}, Vars{Home: home}); err == nil {
+		t.Fatal("expected an error for a malformed pattern")
+	}
+}
+
+func TestCreateAndRestoreRoundTrip(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC))
+	store := NewStore(t.TempDir(), WithClock(fake))
+
+	home := t.TempDir()
+	vars := Vars{Home: home, WorkDir: "/src/api"}
+	fixture := map[string]string{
+		"session-1.jsonl":      `{"cwd":"/src/api","msg":"hi"}`,
+		"nested/session.jsonl": "second",
+	}
+	writeFixture(t, filepath.Join(home, ".claude", "projects", "-src-api"), fixture)
+
+	cp := &Checkpoint{
+		Name:      "before-refactor",
+		AgentID:   "agent-1",
+		AgentType: models.AgentTypeClaudeCode,
+		Vars:      vars,
+		Patterns:  []string{"{home}/.claude/projects/{claude_project}"},
+	}
+	if err := store.Create(cp); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if cp.Files != 2 || cp.Bytes != int64(len(fixture["session-1.jsonl"])+len("second")) || !cp.CreatedAt.Equal(fake.Now()) {
+		t.Fatalf("unexpected checkpoint %+v", cp)
+	}
+	if _, err := os.Stat(store.ArchivePath("before-refactor")); err != nil {
+		t.Fatalf("expected archive: %v", err)
+	}
+	if err := store.Create(&Checkpoint{Name: "before-refactor", AgentID: "agent-1", Vars: vars, Patterns: cp.Patterns}); !errors.Is(err, ErrExists) {
+		t.Fatalf("expected ErrExists for a reused name, got %v", err)
+	}
+
+	got, err := store.Get("before-refactor")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, cp) {
+		t.Fatalf("Get returned %+v, want %+v", got, cp)
+	}
+
+	// Restore into the same place after the session was lost.
+	if err := os.RemoveAll(filepath.Join(home, ".claude")); err != nil {
+		t.Fatal(err)
+	}
+	result, err := store.Restore(got, vars)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.Files != 2 || result.Rewritten != 0 || len(result.Warnings) != 0 {
+		t.Fatalf("unexpected restore result %+v", result)
+	}
+	if restored := readTree(t, filepath.Join(home, ".claude", "projects", "-src-api")); !reflect.DeepEqual(restored, fixture) {
+		t.Fatalf("restored %v, want %v", restored, fixture)
+	}
+}
+
+func TestRestoreRewritesPaths(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	oldHome := t.TempDir()
+	oldVars := Vars{Home: oldHome, WorkDir: "/src/api"}
+	writeFixture(t, filepath.Join(oldHome, ".claude", "projects", "-src-api"), map[string]string{
+		"session.jsonl": `{"cwd":"/src/api/cmd","config":"` + oldHome + `/.claude.json"}`,
+		"state.bin":     "\x00\x01/src/api\x00",
+	})
+
+	cp := &Checkpoint{
+		Name:     "move",
+		AgentID:  "agent-1",
+		Vars:     oldVars,
+		Patterns: []string{"{home}/.claude/projects/{claude_project}"},
+	}
+	if err := store.Create(cp); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	newHome := t.TempDir()
+	newVars := Vars{Home: newHome, WorkDir: "/src/api-v2"}
+	result, err := store.Restore(cp, newVars)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.Files != 2 || result.Rewritten != 1 {
+		t.Fatalf("unexpected restore result %+v", result)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "state.bin") {
+		t.Fatalf("expected a warning for the binary file, got %v", result.Warnings)
+	}
+
+	restored := readTree(t, filepath.Join(newHome, ".claude", "projects", "-src-api-v2"))
+	want := `{"cwd":"/src/api-v2/cmd","config":"` + newHome + `/.claude.json"}`
+	if restored["session.jsonl"] != want {
+		t.Fatalf("session.jsonl = %q, want %q", restored["session.jsonl"], want)
+	}
+	if restored["state.bin"] != "\x00\x01/src/api\x00" {
+		t.Fatalf("expected the binary file unchanged, got %q", restored["state.bin"])
+	}
+}
+
+func TestCreateErrors(t *testing.T) {
+	store := NewStore(t.TempDir())
+	vars := Vars{Home: t.TempDir()}
+
+	if err := store.Create(&Checkpoint{Name: "../escape", AgentID: "agent-1", Vars: vars}); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("expected ErrInvalidName, got %v", err)
+	}
+	if err := store.Create(&Checkpoint{Name: "empty", AgentID: "agent-1", Vars: vars, Patterns: []string{"{home}/none/*"}}); !errors.Is(err, ErrNoFiles) {
+		t.Fatalf("expected ErrNoFiles, got %v", err)
+	}
+	if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRestorePathRejectsTraversal(t *testing.T) {
+	cp := &Checkpoint{Name: "bad", Patterns: []string{"{home}/.codex/sessions"}}
+	vars := Vars{Home: "/home/dev"}
+
+	if got, err := restorePath(cp, vars, "0/a.jsonl"); err != nil || got != "/home/dev/.codex/sessions/a.jsonl" {
+		t.Fatalf("restorePath = %q, %v", got, err)
+	}
+	for _, name := range []string{"0/../../etc/passwd", "1/a.jsonl", "x/a", "0/"} {
+		if _, err := restorePath(cp, vars, name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestListFiltersByAgent(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC))
+	store := NewStore(t.TempDir(), WithClock(fake))
+	home := t.TempDir()
+	writeFixture(t, home, map[string]string{"s/a": "a"})
+
+	for _, c := range []struct{ name, agent string }{{"b", "agent-1"}, {"a", "agent-2"}, {"c", "agent-1"}} {
+		if err := store.Create(&Checkpoint{Name: c.name, AgentID: c.agent, Vars: Vars{Home: home}, Patterns: []string{"{home}/s"}}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		fake.Advance(time.Second)
+	}
+
+	all, err := store.List("")
+	if err != nil || len(all) != 3 || all[0].Name != "b" || all[2].Name != "c" {
+		t.Fatalf("List returned %v, %v", all, err)
+	}
+	mine, err := store.List("agent-1")
+	if err != nil || len(mine) != 2 {
+		t.Fatalf("List(agent-1) returned %v, %v", mine, err)
+	}
+}
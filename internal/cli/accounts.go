@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -17,6 +16,7 @@ import (
 	"github.com/opencode-ai/swarm/internal/account"
 	"github.com/opencode-ai/swarm/internal/account/caam"
 	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/audit"
 	"github.com/opencode-ai/swarm/internal/config"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/models"
@@ -27,155 +27,162 @@ import (
 	"golang.org/x/term"
 )
 
-var (
-	accountsListProvider  string
-	accountsCooldownUntil string
-	accountsRotateReason  string
-
-	// accounts add flags
-	accountsAddProvider      string
-	accountsAddProfile       string
-	accountsAddCredential    string
-	accountsAddCredentialRef string
-	accountsAddEnvVar        string
-	accountsAddSkipTest      bool
-	accountsAddForce         bool
-
-	// accounts import-caam flags
-	accountsImportCaamPath     string
-	accountsImportCaamProvider string
-	accountsImportCaamDryRun   bool
-)
+func newAccountsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "accounts",
+		Short: "Manage accounts",
+		Long:  "Manage provider accounts and profiles used by agents.",
+	}
 
-func init() {
-	rootCmd.AddCommand(accountsCmd)
-	accountsCmd.AddCommand(accountsListCmd)
-	accountsCmd.AddCommand(accountsAddCmd)
-	accountsCmd.AddCommand(accountsCooldownCmd)
-	accountsCmd.AddCommand(accountsRotateCmd)
-	accountsCmd.AddCommand(accountsImportCaamCmd)
-
-	accountsCooldownCmd.AddCommand(accountsCooldownListCmd)
-	accountsCooldownCmd.AddCommand(accountsCooldownSetCmd)
-	accountsCooldownCmd.AddCommand(accountsCooldownClearCmd)
-
-	accountsListCmd.Flags().StringVar(&accountsListProvider, "provider", "", "filter by provider (anthropic, openai, google, custom)")
-	accountsCooldownSetCmd.Flags().StringVar(&accountsCooldownUntil, "until", "", "cooldown end time (RFC3339 or duration like 30m)")
-	_ = accountsCooldownSetCmd.MarkFlagRequired("until")
-	accountsRotateCmd.Flags().StringVar(&accountsRotateReason, "reason", "manual", "reason for account rotation")
-
-	// accounts add flags
-	accountsAddCmd.Flags().StringVar(&accountsAddProvider, "provider", "", "provider type (anthropic, openai, google, custom)")
-	accountsAddCmd.Flags().StringVar(&accountsAddProfile, "profile", "", "profile name for the account")
-	accountsAddCmd.Flags().StringVar(&accountsAddCredentialRef, "credential-ref", "", "credential reference (env:VAR, $VAR, file:/path)")
-	accountsAddCmd.Flags().StringVar(&accountsAddCredential, "credential", "", "API key value (stored in a local file)")
-	accountsAddCmd.Flags().StringVar(&accountsAddEnvVar, "env-var", "", "environment variable containing the credential")
-	accountsAddCmd.Flags().BoolVar(&accountsAddSkipTest, "skip-test", false, "skip credential validation")
-	accountsAddCmd.Flags().BoolVar(&accountsAddForce, "force", false, "overwrite stored credential file if it exists")
-
-	// accounts import-caam flags
-	accountsImportCaamCmd.Flags().StringVar(&accountsImportCaamPath, "path", "", "path to caam vault directory")
-	accountsImportCaamCmd.Flags().StringVar(&accountsImportCaamProvider, "provider", "", "filter by provider (claude, codex, gemini)")
-	accountsImportCaamCmd.Flags().BoolVar(&accountsImportCaamDryRun, "dry-run", false, "show what would be imported without making changes")
+	cmd.AddCommand(newAccountsListCmd())
+	cmd.AddCommand(newAccountsAddCmd())
+	cmd.AddCommand(newAccountsCooldownCmd())
+	cmd.AddCommand(newAccountsRotateCmd())
+	cmd.AddCommand(newAccountsImportCaamCmd())
+	cmd.AddCommand(newAccountsStatusCmd())
+	return cmd
 }
 
-var accountsCmd = &cobra.Command{
-	Use:   "accounts",
-	Short: "Manage accounts",
-	Long:  "Manage provider accounts and profiles used by agents.",
+// accountsAddFlags holds the flags of 'swarm accounts add'.
+type accountsAddFlags struct {
+	provider      string
+	providerName  string
+	profile       string
+	credentialRef string
+	credential    string
+	envVar        string
+	execCmd       string
+	skipTest      bool
+	force         bool
 }
 
-var accountsAddCmd = &cobra.Command{
-	Use:   "add",
-	Short: "Add a new account",
-	Long:  "Add a new provider account with credentials. Prompts interactively or accepts flags.",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		reader := bufio.NewReader(os.Stdin)
-
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+func newAccountsAddCmd() *cobra.Command {
+	var flags accountsAddFlags
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a new account",
+		Long: `Add a new provider account with credentials. Prompts interactively or accepts flags.
+
+--exec-cmd stores an exec: reference: the command is run through sh at
+spawn time (10s timeout) and its stdout is used as the key, so secrets can
+stay in a password manager such as 1Password ('op read ...') or pass
+('pass show ...'). keychain:service/account references read the macOS
+Keychain or, on Linux, the Secret Service via secret-tool.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			reader := bufio.NewReader(os.Stdin)
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		repo := db.NewAccountRepository(database)
+			repo := db.NewAccountRepository(database)
 
-		// Get provider
-		provider, err := getAccountProvider(reader)
-		if err != nil {
-			return err
-		}
+			// Get provider
+			provider, err := getAccountProvider(opts, flags, reader)
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(flags.providerName) != "" && provider != models.ProviderCustom {
+				return invalidInputError("--provider-name requires --provider custom")
+			}
 
-		// Get profile name
-		profile, err := getAccountProfile(reader, provider)
-		if err != nil {
-			return err
-		}
+			// Get profile name
+			profile, err := getAccountProfile(opts, flags, reader, provider)
+			if err != nil {
+				return err
+			}
 
-		// Check if profile already exists
-		existing, _ := findAccountByProfile(ctx, repo, provider, profile)
-		if existing != nil {
-			return fmt.Errorf("account profile %q already exists", profile)
-		}
+			// Check if profile already exists
+			existing, _ := findAccountByProfile(ctx, repo, provider, profile)
+			if existing != nil {
+				return conflictError("account profile %q already exists", profile)
+			}
 
-		// Get credential reference
-		credentialRef, err := getAccountCredential(reader, provider, profile, accountsAddForce)
-		if err != nil {
-			return err
-		}
+			// Get credential reference
+			credentialRef, err := getAccountCredential(opts, flags, reader, provider, profile, flags.force)
+			if err != nil {
+				return err
+			}
 
-		// Validate credential
-		if !accountsAddSkipTest {
-			if err := validateCredential(provider, credentialRef); err != nil {
-				if !IsInteractive() {
-					return fmt.Errorf("credential validation failed: %w", err)
-				}
-				confirm, promptErr := promptConfirm(reader, fmt.Sprintf("Credential validation failed (%v). Continue anyway? [y/N]: ", err))
-				if promptErr != nil {
-					return promptErr
-				}
-				if !confirm {
-					return fmt.Errorf("credential validation failed: %w", err)
+			// Validate credential
+			if !flags.skipTest {
+				if err := validateCredential(provider, credentialRef); err != nil {
+					if !opts.IsInteractive() {
+						return invalidInputError("credential validation failed: %w", err)
+					}
+					confirm, promptErr := promptConfirm(reader, fmt.Sprintf("Credential validation failed (%v). Continue anyway? [y/N]: ", err))
+					if promptErr != nil {
+						return promptErr
+					}
+					if !confirm {
+						return invalidInputError("credential validation failed: %w", err)
+					}
 				}
+				fmt.Fprintln(opts.Err, "Credential validated successfully.")
 			}
-			fmt.Fprintln(os.Stderr, "Credential validated successfully.")
-		}
 
-		// Create account
-		account := &models.Account{
-			Provider:      provider,
-			ProfileName:   profile,
-			CredentialRef: credentialRef,
-			IsActive:      true,
-			CreatedAt:     time.Now().UTC(),
-			UpdatedAt:     time.Now().UTC(),
-		}
+			// Create account
+			account := &models.Account{
+				Provider:      provider,
+				ProviderName:  flags.providerName,
+				ProfileName:   profile,
+				CredentialRef: credentialRef,
+				IsActive:      true,
+				CreatedAt:     time.Now().UTC(),
+				UpdatedAt:     time.Now().UTC(),
+			}
 
-		if err := repo.Create(ctx, account); err != nil {
-			return fmt.Errorf("failed to create account: %w", err)
-		}
+			err = repo.Create(ctx, account)
+			auditAccountAdded(ctx, database, account, err)
+			if err != nil {
+				return wrapServiceError(err, "failed to create account")
+			}
 
-		// Reload to get ID
-		created, err := findAccountByProfile(ctx, repo, provider, profile)
-		if err != nil {
-			return fmt.Errorf("failed to load created account: %w", err)
-		}
+			// Reload to get ID
+			created, err := findAccountByProfile(ctx, repo, provider, profile)
+			if err != nil {
+				return wrapServiceError(err, "failed to load created account")
+			}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, created)
-		}
+			if opts.Structured() {
+				return opts.WriteOutput(created)
+			}
 
-		fmt.Fprintf(os.Stdout, "Account %q created successfully (ID: %s)\n", profile, created.ID)
-		return nil
-	},
+			fmt.Fprintf(opts.Out, "Account %q created successfully (ID: %s)\n", profile, created.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.provider, "provider", "", "provider type (anthropic, openai, google, custom)")
+	cmd.Flags().StringVar(&flags.providerName, "provider-name", "", "name of the provider of a custom account")
+	cmd.Flags().StringVar(&flags.profile, "profile", "", "profile name for the account")
+	cmd.Flags().StringVar(&flags.credentialRef, "credential-ref", "", "credential reference (env:VAR, $VAR, file:/path, exec:cmd, keychain:service/account, vault:adapter/profile)")
+	cmd.Flags().StringVar(&flags.credential, "credential", "", "API key value (stored in a local file)")
+	cmd.Flags().StringVar(&flags.envVar, "env-var", "", "environment variable containing the credential")
+	cmd.Flags().StringVar(&flags.execCmd, "exec-cmd", "", "command that prints the credential, e.g. 'op read op://dev/anthropic/key'")
+	cmd.Flags().BoolVar(&flags.skipTest, "skip-test", false, "skip credential validation")
+	cmd.Flags().BoolVar(&flags.force, "force", false, "overwrite stored credential file if it exists")
+	return cmd
+}
+
+// accountsImportCaamFlags holds the flags of 'swarm accounts import-caam'.
+type accountsImportCaamFlags struct {
+	path     string
+	provider string
+	dryRun   bool
 }
 
-var accountsImportCaamCmd = &cobra.Command{
-	Use:        "import-caam",
-	Short:      "Import accounts from caam vault (DEPRECATED)",
-	Deprecated: "Use 'swarm vault' commands instead. The native vault provides better integration.",
-	Long: `DEPRECATED: Import accounts from a coding_agent_account_manager (caam) vault.
+func newAccountsImportCaamCmd() *cobra.Command {
+	var flags accountsImportCaamFlags
+	cmd := &cobra.Command{
+		Use:        "import-caam",
+		Short:      "Import accounts from caam vault (DEPRECATED)",
+		Deprecated: "Use 'swarm vault' commands instead. The native vault provides better integration.",
+		Long: `DEPRECATED: Import accounts from a coding_agent_account_manager (caam) vault.
 
 This command is deprecated. Use the native Swarm vault instead:
   swarm vault backup <adapter> <profile>  # Save current auth
@@ -190,129 +197,140 @@ Use --path to specify a different location.
 
 Credential references are created using the caam: prefix, which allows
 Swarm to resolve credentials from the caam vault at runtime.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		reader := bufio.NewReader(os.Stdin)
-
-		// Determine vault path
-		vaultPath := accountsImportCaamPath
-		if vaultPath == "" {
-			vaultPath = caam.DefaultVaultPath()
-		}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			reader := bufio.NewReader(os.Stdin)
+
+			// Determine vault path
+			vaultPath := flags.path
+			if vaultPath == "" {
+				vaultPath = caam.DefaultVaultPath()
+			}
 
-		// Parse the vault
-		vault, err := caam.ParseVault(vaultPath)
-		if err != nil {
-			return fmt.Errorf("failed to parse caam vault: %w", err)
-		}
+			// Parse the vault
+			vault, err := caam.ParseVault(vaultPath)
+			if err != nil {
+				return wrapServiceError(err, "failed to parse caam vault")
+			}
 
-		// Filter by provider if specified
-		var profiles []*caam.Profile
-		if accountsImportCaamProvider != "" {
-			profiles = vault.GetProfilesByProvider(accountsImportCaamProvider)
-		} else {
-			profiles = vault.Profiles
-		}
+			// Filter by provider if specified
+			var profiles []*caam.Profile
+			if flags.provider != "" {
+				profiles = vault.GetProfilesByProvider(flags.provider)
+			} else {
+				profiles = vault.Profiles
+			}
 
-		// Filter to valid profiles only
-		var validProfiles []*caam.Profile
-		for _, p := range profiles {
-			if p.IsValid() {
-				validProfiles = append(validProfiles, p)
+			// Filter to valid profiles only
+			var validProfiles []*caam.Profile
+			for _, p := range profiles {
+				if p.IsValid() {
+					validProfiles = append(validProfiles, p)
+				}
 			}
-		}
 
-		if len(validProfiles) == 0 {
-			fmt.Fprintln(os.Stdout, "No valid profiles found in caam vault.")
-			return nil
-		}
+			if len(validProfiles) == 0 {
+				fmt.Fprintln(opts.Out, "No valid profiles found in caam vault.")
+				return nil
+			}
 
-		// Dry run - just show what would be imported
-		if accountsImportCaamDryRun {
-			return showCaamImportPreview(validProfiles)
-		}
+			// Dry run - just show what would be imported
+			if flags.dryRun {
+				return showCaamImportPreview(opts, validProfiles)
+			}
 
-		// Open database
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			// Open database
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		repo := db.NewAccountRepository(database)
+			repo := db.NewAccountRepository(database)
 
-		// Import each profile
-		var imported, skipped, failed int
-		var results []CaamImportResult
+			// Import each profile
+			var imported, skipped, failed int
+			var results []CaamImportResult
 
-		for _, profile := range validProfiles {
-			account := profile.ToSwarmAccount()
+			for _, profile := range validProfiles {
+				account := profile.ToSwarmAccount()
 
-			// Check if already exists
-			existing, _ := findAccountByProfile(ctx, repo, account.Provider, account.ProfileName)
-			if existing != nil {
-				skipped++
-				results = append(results, CaamImportResult{
-					Provider:    string(account.Provider),
-					ProfileName: account.ProfileName,
-					Status:      "skipped",
-					Reason:      "already exists",
-				})
-				continue
-			}
-
-			// Confirm import if interactive
-			if IsInteractive() && !IsJSONOutput() && !IsJSONLOutput() {
-				confirm, err := promptConfirm(reader, fmt.Sprintf("Import %s/%s? [y/N]: ", profile.Provider, profile.Email))
-				if err != nil {
-					return err
-				}
-				if !confirm {
+				// Check if already exists
+				existing, _ := findAccountByProfile(ctx, repo, account.Provider, account.ProfileName)
+				if existing != nil {
+					// Re-importing still picks up refreshed credentials.
+					_ = repo.SetCredentialExpiry(ctx, existing.ID, profile.ExpiresAt)
 					skipped++
 					results = append(results, CaamImportResult{
 						Provider:    string(account.Provider),
 						ProfileName: account.ProfileName,
 						Status:      "skipped",
-						Reason:      "user declined",
+						Reason:      "already exists",
 					})
 					continue
 				}
-			}
 
-			// Create the account
-			if err := repo.Create(ctx, account); err != nil {
-				failed++
+				// Confirm import if interactive
+				if opts.IsInteractive() && !opts.JSON && !opts.JSONL {
+					confirm, err := promptConfirm(reader, fmt.Sprintf("Import %s/%s? [y/N]: ", profile.Provider, profile.Email))
+					if err != nil {
+						return err
+					}
+					if !confirm {
+						skipped++
+						results = append(results, CaamImportResult{
+							Provider:    string(account.Provider),
+							ProfileName: account.ProfileName,
+							Status:      "skipped",
+							Reason:      "user declined",
+						})
+						continue
+					}
+				}
+
+				// Create the account
+				err := repo.Create(ctx, account)
+				auditAccountAdded(ctx, database, account, err)
+				if err != nil {
+					failed++
+					results = append(results, CaamImportResult{
+						Provider:    string(account.Provider),
+						ProfileName: account.ProfileName,
+						Status:      "failed",
+						Reason:      err.Error(),
+					})
+					continue
+				}
+
+				imported++
 				results = append(results, CaamImportResult{
 					Provider:    string(account.Provider),
 					ProfileName: account.ProfileName,
-					Status:      "failed",
-					Reason:      err.Error(),
+					Status:      "imported",
 				})
-				continue
 			}
 
-			imported++
-			results = append(results, CaamImportResult{
-				Provider:    string(account.Provider),
-				ProfileName: account.ProfileName,
-				Status:      "imported",
-			})
-		}
+			// Output results
+			if opts.Structured() {
+				return opts.WriteOutput(CaamImportSummary{
+					VaultPath: vaultPath,
+					Imported:  imported,
+					Skipped:   skipped,
+					Failed:    failed,
+					Results:   results,
+				})
+			}
 
-		// Output results
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, CaamImportSummary{
-				VaultPath: vaultPath,
-				Imported:  imported,
-				Skipped:   skipped,
-				Failed:    failed,
-				Results:   results,
-			})
-		}
+			fmt.Fprintf(opts.Out, "Import complete: %d imported, %d skipped, %d failed\n", imported, skipped, failed)
+			return nil
+		},
+	}
 
-		fmt.Fprintf(os.Stdout, "Import complete: %d imported, %d skipped, %d failed\n", imported, skipped, failed)
-		return nil
-	},
+	cmd.Flags().StringVar(&flags.path, "path", "", "path to caam vault directory")
+	cmd.Flags().StringVar(&flags.provider, "provider", "", "filter by provider (claude, codex, gemini)")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "show what would be imported without making changes")
+	return cmd
 }
 
 // CaamImportResult represents the result of importing a single profile.
@@ -333,8 +351,8 @@ type CaamImportSummary struct {
 }
 
 // showCaamImportPreview displays what would be imported without making changes.
-func showCaamImportPreview(profiles []*caam.Profile) error {
-	if IsJSONOutput() || IsJSONLOutput() {
+func showCaamImportPreview(opts Options, profiles []*caam.Profile) error {
+	if opts.Structured() {
 		var previews []map[string]string
 		for _, p := range profiles {
 			previews = append(previews, map[string]string{
@@ -344,13 +362,13 @@ func showCaamImportPreview(profiles []*caam.Profile) error {
 				"credential_ref": p.ToSwarmAccount().CredentialRef,
 			})
 		}
-		return WriteOutput(os.Stdout, previews)
+		return opts.WriteOutput(previews)
 	}
 
-	fmt.Fprintln(os.Stdout, "Profiles that would be imported:")
-	fmt.Fprintln(os.Stdout)
+	fmt.Fprintln(opts.Out, "Profiles that would be imported:")
+	fmt.Fprintln(opts.Out)
 
-	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	writer := tabwriter.NewWriter(opts.Out, 0, 8, 2, ' ', 0)
 	fmt.Fprintln(writer, "CAAM PROVIDER\tEMAIL\tSWARM PROVIDER\tCREDENTIAL REF")
 	for _, p := range profiles {
 		account := p.ToSwarmAccount()
@@ -363,27 +381,27 @@ func showCaamImportPreview(profiles []*caam.Profile) error {
 	}
 	writer.Flush()
 
-	fmt.Fprintln(os.Stdout)
-	fmt.Fprintf(os.Stdout, "Total: %d profiles\n", len(profiles))
-	fmt.Fprintln(os.Stdout, "Run without --dry-run to import.")
+	fmt.Fprintln(opts.Out)
+	fmt.Fprintf(opts.Out, "Total: %d profiles\n", len(profiles))
+	fmt.Fprintln(opts.Out, "Run without --dry-run to import.")
 	return nil
 }
 
 // getAccountProvider prompts for or returns the provider.
-func getAccountProvider(reader *bufio.Reader) (models.Provider, error) {
-	if accountsAddProvider != "" {
-		return parseProvider(accountsAddProvider)
+func getAccountProvider(opts Options, flags accountsAddFlags, reader *bufio.Reader) (models.Provider, error) {
+	if flags.provider != "" {
+		return models.ParseProvider(flags.provider)
 	}
 
-	if IsNonInteractive() {
-		return "", fmt.Errorf("--provider is required in non-interactive mode")
+	if opts.IsNonInteractive() {
+		return "", invalidInputError("--provider is required in non-interactive mode")
 	}
 
-	fmt.Fprintln(os.Stderr, "Select provider:")
-	fmt.Fprintln(os.Stderr, "  1) anthropic")
-	fmt.Fprintln(os.Stderr, "  2) openai")
-	fmt.Fprintln(os.Stderr, "  3) google")
-	fmt.Fprintln(os.Stderr, "  4) custom")
+	fmt.Fprintln(opts.Err, "Select provider:")
+	fmt.Fprintln(opts.Err, "  1) anthropic")
+	fmt.Fprintln(opts.Err, "  2) openai")
+	fmt.Fprintln(opts.Err, "  3) google")
+	fmt.Fprintln(opts.Err, "  4) custom")
 	choice, err := promptLine(reader, "Provider [1-4]: ")
 	if err != nil {
 		return "", err
@@ -399,18 +417,18 @@ func getAccountProvider(reader *bufio.Reader) (models.Provider, error) {
 	case "4", "custom":
 		return models.ProviderCustom, nil
 	default:
-		return "", fmt.Errorf("invalid provider selection %q", choice)
+		return "", invalidInputError("invalid provider selection %q", choice)
 	}
 }
 
 // getAccountProfile prompts for or returns the profile name.
-func getAccountProfile(reader *bufio.Reader, provider models.Provider) (string, error) {
-	if accountsAddProfile != "" {
-		return strings.TrimSpace(accountsAddProfile), nil
+func getAccountProfile(opts Options, flags accountsAddFlags, reader *bufio.Reader, provider models.Provider) (string, error) {
+	if flags.profile != "" {
+		return strings.TrimSpace(flags.profile), nil
 	}
 
-	if IsNonInteractive() {
-		return "", fmt.Errorf("--profile is required in non-interactive mode")
+	if opts.IsNonInteractive() {
+		return "", invalidInputError("--profile is required in non-interactive mode")
 	}
 
 	defaultProfile := "default"
@@ -425,36 +443,49 @@ func getAccountProfile(reader *bufio.Reader, provider models.Provider) (string,
 }
 
 // getAccountCredential prompts for or returns the credential reference.
-func getAccountCredential(reader *bufio.Reader, provider models.Provider, profile string, force bool) (string, error) {
-	if accountsAddCredentialRef != "" {
-		return strings.TrimSpace(accountsAddCredentialRef), nil
+func getAccountCredential(opts Options, flags accountsAddFlags, reader *bufio.Reader, provider models.Provider, profile string, force bool) (string, error) {
+	if flags.credentialRef != "" {
+		ref := strings.TrimSpace(flags.credentialRef)
+		if err := account.DefaultResolvers().Validate(ref); err != nil {
+			return "", invalidInputError("invalid --credential-ref: %w", err)
+		}
+		return ref, nil
+	}
+
+	if flags.execCmd != "" {
+		command := strings.TrimSpace(flags.execCmd)
+		if err := account.ValidateExecCommand(command); err != nil {
+			return "", invalidInputError("invalid --exec-cmd: %w", err)
+		}
+		return "exec:" + command, nil
 	}
 
-	if accountsAddEnvVar != "" {
-		envVar := strings.TrimSpace(accountsAddEnvVar)
+	if flags.envVar != "" {
+		envVar := strings.TrimSpace(flags.envVar)
 		if !strings.HasPrefix(envVar, "env:") {
 			envVar = "env:" + envVar
 		}
 		return envVar, nil
 	}
 
-	if accountsAddCredential != "" {
-		path, err := storeCredentialSecret(provider, profile, accountsAddCredential, force)
+	if flags.credential != "" {
+		path, err := storeCredentialSecret(provider, profile, flags.credential, force)
 		if err != nil {
 			return "", err
 		}
 		return "file:" + path, nil
 	}
 
-	if IsNonInteractive() {
-		return "", fmt.Errorf("--credential-ref, --credential, or --env-var is required in non-interactive mode")
+	if opts.IsNonInteractive() {
+		return "", invalidInputError("--credential-ref, --credential, --env-var, or --exec-cmd is required in non-interactive mode")
 	}
 
-	fmt.Fprintln(os.Stderr, "Credential source:")
-	fmt.Fprintln(os.Stderr, "  1) Environment variable (recommended)")
-	fmt.Fprintln(os.Stderr, "  2) Existing file")
-	fmt.Fprintln(os.Stderr, "  3) Enter secret now (stored in local file)")
-	choice, err := promptLine(reader, "Choice [1-3]: ")
+	fmt.Fprintln(opts.Err, "Credential source:")
+	fmt.Fprintln(opts.Err, "  1) Environment variable (recommended)")
+	fmt.Fprintln(opts.Err, "  2) Existing file")
+	fmt.Fprintln(opts.Err, "  3) Enter secret now (stored in local file)")
+	fmt.Fprintln(opts.Err, "  4) Command that prints the secret (password manager)")
+	choice, err := promptLine(reader, "Choice [1-4]: ")
 	if err != nil {
 		return "", err
 	}
@@ -470,7 +501,7 @@ func getAccountCredential(reader *bufio.Reader, provider models.Provider, profil
 			envChoice = envDefault
 		}
 		if envChoice == "" {
-			return "", fmt.Errorf("environment variable name is required")
+			return "", invalidInputError("environment variable name is required")
 		}
 		return "env:" + envChoice, nil
 	case "2", "file":
@@ -479,14 +510,14 @@ func getAccountCredential(reader *bufio.Reader, provider models.Provider, profil
 			return "", err
 		}
 		if path == "" {
-			return "", fmt.Errorf("credential file path is required")
+			return "", invalidInputError("credential file path is required")
 		}
 		info, err := os.Stat(path)
 		if err != nil {
-			return "", fmt.Errorf("failed to read credential file: %w", err)
+			return "", wrapServiceError(err, "failed to read credential file")
 		}
 		if info.IsDir() {
-			return "", fmt.Errorf("credential file path is a directory")
+			return "", invalidInputError("credential file path is a directory")
 		}
 		return "file:" + path, nil
 	case "3", "secret", "enter":
@@ -499,18 +530,27 @@ func getAccountCredential(reader *bufio.Reader, provider models.Provider, profil
 			return "", err
 		}
 		if secret == "" {
-			return "", fmt.Errorf("API key is required")
+			return "", invalidInputError("API key is required")
 		}
 		if secret != confirm {
-			return "", fmt.Errorf("API key confirmation does not match")
+			return "", invalidInputError("API key confirmation does not match")
 		}
 		path, err := storeCredentialSecret(provider, profile, secret, force)
 		if err != nil {
 			return "", err
 		}
 		return "file:" + path, nil
+	case "4", "exec", "command":
+		command, err := promptLine(reader, "Command: ")
+		if err != nil {
+			return "", err
+		}
+		if err := account.ValidateExecCommand(command); err != nil {
+			return "", invalidInputError("%v", err)
+		}
+		return "exec:" + strings.TrimSpace(command), nil
 	default:
-		return "", fmt.Errorf("invalid credential selection %q", choice)
+		return "", invalidInputError("invalid credential selection %q", choice)
 	}
 }
 
@@ -530,21 +570,21 @@ func validateCredential(provider models.Provider, credentialRef string) error {
 		return err
 	}
 	if strings.TrimSpace(apiKey) == "" {
-		return fmt.Errorf("credential is empty")
+		return invalidInputError("credential is empty")
 	}
 
 	switch provider {
 	case models.ProviderAnthropic:
 		if !strings.HasPrefix(apiKey, "sk-ant-") {
-			return fmt.Errorf("invalid Anthropic API key format (expected sk-ant-...)")
+			return invalidInputError("invalid Anthropic API key format (expected sk-ant-...)")
 		}
 	case models.ProviderOpenAI:
 		if !strings.HasPrefix(apiKey, "sk-") {
-			return fmt.Errorf("invalid OpenAI API key format (expected sk-...)")
+			return invalidInputError("invalid OpenAI API key format (expected sk-...)")
 		}
 	case models.ProviderGoogle:
 		if len(apiKey) < 10 {
-			return fmt.Errorf("API key appears too short")
+			return invalidInputError("API key appears too short")
 		}
 	case models.ProviderCustom:
 	}
@@ -578,7 +618,7 @@ func promptSecret(prompt string) (string, error) {
 func storeCredentialSecret(provider models.Provider, profile, secret string, force bool) (string, error) {
 	secret = strings.TrimSpace(secret)
 	if secret == "" {
-		return "", fmt.Errorf("credential is empty")
+		return "", invalidInputError("credential is empty")
 	}
 	cfg := GetConfig()
 	if cfg == nil {
@@ -586,28 +626,28 @@ func storeCredentialSecret(provider models.Provider, profile, secret string, for
 	}
 	dir := filepath.Join(cfg.Global.DataDir, "credentials")
 	if err := os.MkdirAll(dir, 0700); err != nil {
-		return "", fmt.Errorf("failed to create credentials directory: %w", err)
+		return "", wrapServiceError(err, "failed to create credentials directory")
 	}
 
 	filename := fmt.Sprintf("%s_%s.key", sanitizeCredentialPart(string(provider)), sanitizeCredentialPart(profile))
 	path := filepath.Join(dir, filename)
 	if _, err := os.Stat(path); err == nil && !force {
-		return "", fmt.Errorf("credential file already exists (use --force to overwrite)")
+		return "", conflictError("credential file already exists (use --force to overwrite)")
 	} else if err != nil && !os.IsNotExist(err) {
-		return "", fmt.Errorf("failed to check credential file: %w", err)
+		return "", wrapServiceError(err, "failed to check credential file")
 	}
 
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
 	if err != nil {
-		return "", fmt.Errorf("failed to write credential file: %w", err)
+		return "", wrapServiceError(err, "failed to write credential file")
 	}
 	defer file.Close()
 
 	if _, err := file.WriteString(secret); err != nil {
-		return "", fmt.Errorf("failed to write credential file: %w", err)
+		return "", wrapServiceError(err, "failed to write credential file")
 	}
 	if err := file.Chmod(0600); err != nil {
-		return "", fmt.Errorf("failed to set credential file permissions: %w", err)
+		return "", wrapServiceError(err, "failed to set credential file permissions")
 	}
 	return path, nil
 }
@@ -648,289 +688,383 @@ func promptConfirm(reader *bufio.Reader, prompt string) (bool, error) {
 	}
 }
 
-var accountsCooldownCmd = &cobra.Command{
-	Use:   "cooldown",
-	Short: "Manage account cooldowns",
-	Long:  "List, set, or clear account cooldown windows.",
-}
+func newAccountsCooldownCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cooldown",
+		Short: "Manage account cooldowns",
+		Long:  "List, set, or clear account cooldown windows.",
+	}
 
-var accountsListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List accounts",
-	Long:  "List available provider accounts and their status.",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+	cmd.AddCommand(newAccountsCooldownListCmd())
+	cmd.AddCommand(newAccountsCooldownSetCmd())
+	cmd.AddCommand(newAccountsCooldownClearCmd())
+	return cmd
+}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+// accountsListFlags holds the flags of 'swarm accounts list'.
+type accountsListFlags struct {
+	provider string
+	filter   string
+}
 
-		repo := db.NewAccountRepository(database)
+func newAccountsListCmd() *cobra.Command {
+	var flags accountsListFlags
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List accounts",
+		Long: `List available provider accounts and their status.
+
+--filter keeps the accounts matching an expression over their fields:
+provider, profile, status (active, cooldown, inactive), id, and age (time
+since the account was added). See 'swarm agent list --help' for the
+grammar.`,
+		Example: `  swarm accounts list --filter 'provider=anthropic and status!=cooldown'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			filter, err := listFilterFlag(flags.filter, accountFilterFields)
+			if err != nil {
+				return err
+			}
 
-		var provider *models.Provider
-		if strings.TrimSpace(accountsListProvider) != "" {
-			parsed, err := parseProvider(accountsListProvider)
+			database, err := openDatabase()
 			if err != nil {
 				return err
 			}
-			provider = &parsed
-		}
+			defer database.Close()
 
-		accounts, err := repo.List(ctx, provider)
-		if err != nil {
-			return fmt.Errorf("failed to list accounts: %w", err)
-		}
+			repo := db.NewAccountRepository(database)
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, accounts)
-		}
+			var provider *models.Provider
+			if strings.TrimSpace(flags.provider) != "" {
+				parsed, err := models.ParseProvider(flags.provider)
+				if err != nil {
+					return err
+				}
+				provider = &parsed
+			} else if name, ok := filter.Pushdown("provider"); ok {
+				parsed, err := models.ParseProvider(name)
+				if err != nil {
+					return err
+				}
+				provider = &parsed
+			}
 
-		if len(accounts) == 0 {
-			fmt.Fprintln(os.Stdout, "No accounts found.")
-			return nil
-		}
+			accounts, err := repo.List(ctx, provider)
+			if err != nil {
+				return wrapServiceError(err, "failed to list accounts")
+			}
+			if filter != nil {
+				now := time.Now()
+				matched := accounts[:0]
+				for _, account := range accounts {
+					if filter.Match(accountFilterRecord(account), now) {
+						matched = append(matched, account)
+					}
+				}
+				accounts = matched
+			}
 
-		writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
-		fmt.Fprintln(writer, "PROVIDER\tPROFILE\tSTATUS\tCOOLDOWN")
-		for _, account := range accounts {
-			fmt.Fprintf(
-				writer,
-				"%s\t%s\t%s\t%s\n",
-				account.Provider,
-				account.ProfileName,
-				formatAccountStatus(account),
-				formatAccountCooldown(account),
-			)
-		}
-		return writer.Flush()
-	},
+			if opts.Structured() {
+				return opts.WriteOutput(accounts)
+			}
+
+			if len(accounts) == 0 {
+				fmt.Fprintln(opts.Out, "No accounts found.")
+				return nil
+			}
+
+			now := time.Now()
+			warning := credentialExpiryWarning()
+			writer := tabwriter.NewWriter(opts.Out, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(writer, "PROVIDER\tPROFILE\tSTATUS\tCOOLDOWN\tEXPIRES")
+			for _, account := range accounts {
+				fmt.Fprintf(
+					writer,
+					"%s\t%s\t%s\t%s\t%s\n",
+					formatAccountProvider(account),
+					account.ProfileName,
+					formatAccountStatus(account),
+					formatAccountCooldown(account),
+					formatCredentialExpiry(account.CredentialExpiresAt, now, warning),
+				)
+			}
+			return writer.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.provider, "provider", "", "filter by provider (anthropic, openai, google, custom)")
+	addFilterFlag(cmd, &flags.filter, "provider=anthropic and status!=cooldown")
+	return cmd
+}
+
+// accountsRotateFlags holds the flags of 'swarm accounts rotate'.
+type accountsRotateFlags struct {
+	reason string
+	dryRun bool
 }
 
-var accountsRotateCmd = &cobra.Command{
-	Use:   "rotate <agent-id>",
-	Short: "Rotate an agent to a new account",
-	Long:  "Select the next available account for the agent's provider and restart the agent.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+func newAccountsRotateCmd() *cobra.Command {
+	var flags accountsRotateFlags
+	cmd := &cobra.Command{
+		Use:   "rotate <agent-id>",
+		Short: "Rotate an agent to a new account",
+		Long: `Select the next available account for the agent's provider and restart the agent.
+
+With --dry-run, the account is selected and the restart checked, but the
+agent keeps running on its current account.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			agentRepo := db.NewAgentRepository(database)
+			accountRepo := db.NewAccountRepository(database)
+			eventRepo := db.NewEventRepository(database)
+			nodeRepo := db.NewNodeRepository(database)
+			wsRepo := db.NewWorkspaceRepository(database)
+			queueRepo := db.NewQueueRepository(database)
 
-		agentRepo := db.NewAgentRepository(database)
-		accountRepo := db.NewAccountRepository(database)
-		eventRepo := db.NewEventRepository(database)
-		nodeRepo := db.NewNodeRepository(database)
-		wsRepo := db.NewWorkspaceRepository(database)
-		queueRepo := db.NewQueueRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
 
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			agentInfo, err := findAgent(ctx, agentRepo, args[0])
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(agentInfo.AccountID) == "" {
+				return notFoundError("agent %s has no account assigned", agentInfo.ID)
+			}
 
-		agentInfo, err := findAgent(ctx, agentRepo, args[0])
-		if err != nil {
-			return err
-		}
-		if strings.TrimSpace(agentInfo.AccountID) == "" {
-			return fmt.Errorf("agent %s has no account assigned", agentInfo.ID)
-		}
+			currentAccount, err := findAccount(ctx, accountRepo, agentInfo.AccountID)
+			if err != nil {
+				return err
+			}
 
-		currentAccount, err := findAccount(ctx, accountRepo, agentInfo.AccountID)
-		if err != nil {
-			return err
-		}
+			rotationMode := accountIDModeByAgent(agentInfo.AccountID, currentAccount)
+			nextAccount, err := selectNextAccount(ctx, accountRepo, currentAccount, rotationMode)
+			if err != nil {
+				return err
+			}
 
-		rotationMode := accountIDModeByAgent(agentInfo.AccountID, currentAccount)
-		nextAccount, err := selectNextAccount(ctx, accountRepo, currentAccount, rotationMode)
-		if err != nil {
-			return err
-		}
+			accountService, err := buildAccountService(ctx, accountRepo, rotationMode, database)
+			if err != nil {
+				return err
+			}
 
-		accountService, err := buildAccountService(ctx, accountRepo, rotationMode, database)
-		if err != nil {
-			return err
-		}
+			tmuxClient := tmux.NewLocalClient()
+			agentService := agent.NewService(agentRepo, queueRepo, wsService, accountService, tmuxClient, agentServiceOptions(database)...)
 
-		tmuxClient := tmux.NewLocalClient()
-		agentService := agent.NewService(agentRepo, queueRepo, wsService, accountService, tmuxClient, agentServiceOptions(database)...)
+			newAccountID := accountIDForMode(nextAccount, rotationMode)
+			updatedAgent, err := agentService.RestartAgentWithAccount(ctx, agentInfo.ID, newAccountID, flags.dryRun)
+			if err != nil {
+				return err
+			}
 
-		newAccountID := accountIDForMode(nextAccount, rotationMode)
-		updatedAgent, err := agentService.RestartAgentWithAccount(ctx, agentInfo.ID, newAccountID)
-		if err != nil {
-			return err
-		}
+			if !flags.dryRun {
+				newAuditRecorder(database).Record(ctx, account.AuditRotateAccount, models.EntityTypeAccount, agentInfo.AccountID, audit.Params(
+					"to_account_id", newAccountID,
+					"agent_id", agentInfo.ID,
+					"reason", flags.reason,
+				), nil)
+				if err := recordAccountRotation(ctx, eventRepo, agentInfo.ID, agentInfo.AccountID, newAccountID, flags.reason); err != nil {
+					return err
+				}
+			}
 
-		if err := recordAccountRotation(ctx, eventRepo, agentInfo.ID, agentInfo.AccountID, newAccountID, accountsRotateReason); err != nil {
-			return err
-		}
+			result := AccountRotationResult{
+				AgentID:      updatedAgent.ID,
+				OldAccountID: agentInfo.AccountID,
+				NewAccountID: newAccountID,
+				Provider:     currentAccount.Provider,
+				Reason:       flags.reason,
+				Timestamp:    time.Now().UTC(),
+				DryRun:       flags.dryRun,
+			}
 
-		result := AccountRotationResult{
-			AgentID:      updatedAgent.ID,
-			OldAccountID: agentInfo.AccountID,
-			NewAccountID: newAccountID,
-			Provider:     currentAccount.Provider,
-			Reason:       accountsRotateReason,
-			Timestamp:    time.Now().UTC(),
-		}
+			if opts.Structured() {
+				return opts.WriteOutput(result)
+			}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, result)
-		}
+			if flags.dryRun {
+				rows := [][]string{{shortID(result.AgentID), string(result.Provider), result.OldAccountID, result.NewAccountID}}
+				if err := opts.writeTable([]string{"AGENT", "PROVIDER", "FROM", "TO"}, rows); err != nil {
+					return err
+				}
+				printDryRunNote(opts.Out)
+				return nil
+			}
+			fmt.Fprintf(opts.Out, "Rotated agent %s from %s to %s\n", updatedAgent.ID, agentInfo.AccountID, newAccountID)
+			return nil
+		},
+	}
 
-		fmt.Fprintf(os.Stdout, "Rotated agent %s from %s to %s\n", updatedAgent.ID, agentInfo.AccountID, newAccountID)
-		return nil
-	},
+	cmd.Flags().StringVar(&flags.reason, "reason", "manual", "reason for account rotation")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "show which account would be selected without restarting the agent")
+	return cmd
 }
 
-var accountsCooldownListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List account cooldowns",
-	Long:  "List accounts with active or expired cooldown timestamps.",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+func newAccountsCooldownListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List account cooldowns",
+		Long:  "List accounts with active or expired cooldown timestamps.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		repo := db.NewAccountRepository(database)
-		accounts, err := repo.List(ctx, nil)
-		if err != nil {
-			return fmt.Errorf("failed to list accounts: %w", err)
-		}
+			repo := db.NewAccountRepository(database)
+			accounts, err := repo.List(ctx, nil)
+			if err != nil {
+				return wrapServiceError(err, "failed to list accounts")
+			}
 
-		cooldownAccounts := filterAccountsWithCooldown(accounts)
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, cooldownAccounts)
-		}
+			cooldownAccounts := filterAccountsWithCooldown(accounts)
+			if opts.Structured() {
+				return opts.WriteOutput(cooldownAccounts)
+			}
 
-		if len(cooldownAccounts) == 0 {
-			fmt.Fprintln(os.Stdout, "No accounts on cooldown.")
-			return nil
-		}
+			if len(cooldownAccounts) == 0 {
+				fmt.Fprintln(opts.Out, "No accounts on cooldown.")
+				return nil
+			}
 
-		writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
-		fmt.Fprintln(writer, "PROVIDER\tPROFILE\tSTATUS\tCOOLDOWN\tUNTIL")
-		for _, account := range cooldownAccounts {
-			until := "-"
-			if account.CooldownUntil != nil {
-				until = account.CooldownUntil.UTC().Format(time.RFC3339)
+			writer := tabwriter.NewWriter(opts.Out, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(writer, "PROVIDER\tPROFILE\tSTATUS\tCOOLDOWN\tUNTIL")
+			for _, account := range cooldownAccounts {
+				until := "-"
+				if account.CooldownUntil != nil {
+					until = account.CooldownUntil.UTC().Format(time.RFC3339)
+				}
+				fmt.Fprintf(
+					writer,
+					"%s\t%s\t%s\t%s\t%s\n",
+					formatAccountProvider(account),
+					account.ProfileName,
+					formatAccountStatus(account),
+					formatAccountCooldown(account),
+					until,
+				)
 			}
-			fmt.Fprintf(
-				writer,
-				"%s\t%s\t%s\t%s\t%s\n",
-				account.Provider,
-				account.ProfileName,
-				formatAccountStatus(account),
-				formatAccountCooldown(account),
-				until,
-			)
-		}
-		return writer.Flush()
-	},
+			return writer.Flush()
+		},
+	}
+	return cmd
 }
 
-var accountsCooldownSetCmd = &cobra.Command{
-	Use:   "set <account>",
-	Short: "Set an account cooldown",
-	Long:  "Set a cooldown for an account until a specific time or for a duration.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-
-		until, err := parseCooldownUntil(accountsCooldownUntil)
-		if err != nil {
-			return err
-		}
+// accountsCooldownSetFlags holds the flags of 'swarm accounts cooldown set'.
+type accountsCooldownSetFlags struct {
+	until string
+}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+func newAccountsCooldownSetCmd() *cobra.Command {
+	var flags accountsCooldownSetFlags
+	cmd := &cobra.Command{
+		Use:   "set <account>",
+		Short: "Set an account cooldown",
+		Long:  "Set a cooldown for an account until a specific time or for a duration.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			until, err := parseCooldownUntil(flags.until)
+			if err != nil {
+				return err
+			}
 
-		repo := db.NewAccountRepository(database)
-		account, err := findAccount(ctx, repo, args[0])
-		if err != nil {
-			return err
-		}
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		if err := repo.SetCooldown(ctx, account.ID, until); err != nil {
-			return fmt.Errorf("failed to set cooldown: %w", err)
-		}
+			repo := db.NewAccountRepository(database)
+			account, err := findAccount(ctx, repo, args[0])
+			if err != nil {
+				return err
+			}
 
-		updated, err := repo.Get(ctx, account.ID)
-		if err != nil {
-			return fmt.Errorf("failed to load updated account: %w", err)
-		}
+			err = repo.SetCooldown(ctx, account.ID, until)
+			auditAccountCooldown(ctx, database, account.ID, &until, err)
+			if err != nil {
+				return wrapServiceError(err, "failed to set cooldown")
+			}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, updated)
-		}
+			updated, err := repo.Get(ctx, account.ID)
+			if err != nil {
+				return wrapServiceError(err, "failed to load updated account")
+			}
 
-		fmt.Fprintf(os.Stdout, "Cooldown set for %s until %s\n", updated.ProfileName, updated.CooldownUntil.UTC().Format(time.RFC3339))
-		return nil
-	},
-}
+			if opts.Structured() {
+				return opts.WriteOutput(updated)
+			}
 
-var accountsCooldownClearCmd = &cobra.Command{
-	Use:   "clear <account>",
-	Short: "Clear an account cooldown",
-	Long:  "Remove the cooldown timestamp from an account.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+			fmt.Fprintf(opts.Out, "Cooldown set for %s until %s\n", updated.ProfileName, updated.CooldownUntil.UTC().Format(time.RFC3339))
+			return nil
+		},
+	}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+	cmd.Flags().StringVar(&flags.until, "until", "", "cooldown end time (RFC3339 or duration like 30m)")
+	_ = cmd.MarkFlagRequired("until")
+	return cmd
+}
 
-		repo := db.NewAccountRepository(database)
-		account, err := findAccount(ctx, repo, args[0])
-		if err != nil {
-			return err
-		}
+func newAccountsCooldownClearCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear <account>",
+		Short: "Clear an account cooldown",
+		Long:  "Remove the cooldown timestamp from an account.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		if err := repo.ClearCooldown(ctx, account.ID); err != nil {
-			return fmt.Errorf("failed to clear cooldown: %w", err)
-		}
+			repo := db.NewAccountRepository(database)
+			account, err := findAccount(ctx, repo, args[0])
+			if err != nil {
+				return err
+			}
 
-		updated, err := repo.Get(ctx, account.ID)
-		if err != nil {
-			return fmt.Errorf("failed to load updated account: %w", err)
-		}
+			err = repo.ClearCooldown(ctx, account.ID)
+			auditAccountCooldown(ctx, database, account.ID, nil, err)
+			if err != nil {
+				return wrapServiceError(err, "failed to clear cooldown")
+			}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, updated)
-		}
+			updated, err := repo.Get(ctx, account.ID)
+			if err != nil {
+				return wrapServiceError(err, "failed to load updated account")
+			}
 
-		fmt.Fprintf(os.Stdout, "Cooldown cleared for %s\n", updated.ProfileName)
-		return nil
-	},
-}
+			if opts.Structured() {
+				return opts.WriteOutput(updated)
+			}
 
-func parseProvider(value string) (models.Provider, error) {
-	switch strings.ToLower(strings.TrimSpace(value)) {
-	case string(models.ProviderAnthropic):
-		return models.ProviderAnthropic, nil
-	case string(models.ProviderOpenAI):
-		return models.ProviderOpenAI, nil
-	case string(models.ProviderGoogle):
-		return models.ProviderGoogle, nil
-	case string(models.ProviderCustom):
-		return models.ProviderCustom, nil
-	default:
-		return "", fmt.Errorf("invalid provider: %s", value)
+			fmt.Fprintf(opts.Out, "Cooldown cleared for %s\n", updated.ProfileName)
+			return nil
+		},
 	}
+	return cmd
 }
 
 type AccountRotationResult struct {
@@ -940,6 +1074,8 @@ type AccountRotationResult struct {
 	Provider     models.Provider `json:"provider"`
 	Reason       string          `json:"reason"`
 	Timestamp    time.Time       `json:"timestamp"`
+	// DryRun is true when the rotation was only planned.
+	DryRun bool `json:"dry_run"`
 }
 
 type accountIDMode int
@@ -981,7 +1117,7 @@ func buildAccountService(ctx context.Context, repo *db.AccountRepository, mode a
 
 	accounts, err := repo.List(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load accounts: %w", err)
+		return nil, wrapServiceError(err, "failed to load accounts")
 	}
 
 	for _, acct := range accounts {
@@ -993,7 +1129,7 @@ func buildAccountService(ctx context.Context, repo *db.AccountRepository, mode a
 			clone.ID = clone.ProfileName
 		}
 		if err := svc.AddAccount(ctx, &clone); err != nil && !errors.Is(err, account.ErrAccountAlreadyExists) {
-			return nil, fmt.Errorf("failed to register account %s: %w", clone.ProfileName, err)
+			return nil, wrapServiceError(err, "failed to register account %s", clone.ProfileName)
 		}
 	}
 
@@ -1002,13 +1138,13 @@ func buildAccountService(ctx context.Context, repo *db.AccountRepository, mode a
 
 func selectNextAccount(ctx context.Context, repo *db.AccountRepository, current *models.Account, mode accountIDMode) (*models.Account, error) {
 	if current == nil {
-		return nil, fmt.Errorf("current account is required")
+		return nil, invalidInputError("current account is required")
 	}
 
 	provider := current.Provider
 	accounts, err := repo.List(ctx, &provider)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list accounts: %w", err)
+		return nil, wrapServiceError(err, "failed to list accounts")
 	}
 
 	var candidates []*models.Account
@@ -1027,34 +1163,38 @@ func selectNextAccount(ctx context.Context, repo *db.AccountRepository, current
 	}
 
 	if len(candidates) == 0 {
-		return nil, fmt.Errorf("no available accounts to rotate for provider %s", provider)
+		return nil, unavailableError("no available accounts to rotate for provider %s", provider)
 	}
 
-	return selectLeastRecentlyUsedAccount(candidates), nil
+	return account.SelectForRotation(candidates, time.Now(), credentialExpiryWarning()), nil
 }
 
-func selectLeastRecentlyUsedAccount(accounts []*models.Account) *models.Account {
-	if len(accounts) == 0 {
-		return nil
+// credentialExpiryWarning returns scheduler.credential_expiry_warning.
+func credentialExpiryWarning() time.Duration {
+	if cfg := GetConfig(); cfg != nil {
+		return cfg.Scheduler.CredentialExpiryWarning
 	}
+	return config.DefaultConfig().Scheduler.CredentialExpiryWarning
+}
 
-	sort.Slice(accounts, func(i, j int) bool {
-		left := accountLastUsed(accounts[i])
-		right := accountLastUsed(accounts[j])
-		if left.Equal(right) {
-			return accounts[i].ProfileName < accounts[j].ProfileName
-		}
-		return left.Before(right)
-	})
-
-	return accounts[0]
+// auditAccountAdded records an account created directly in the repository.
+func auditAccountAdded(ctx context.Context, database *db.DB, acct *models.Account, err error) {
+	newAuditRecorder(database).Record(ctx, account.AuditAddAccount, models.EntityTypeAccount, acct.ID, audit.Params(
+		"provider", string(acct.Provider),
+		"profile", acct.ProfileName,
+	), err)
 }
 
-func accountLastUsed(account *models.Account) time.Time {
-	if account == nil || account.UsageStats == nil || account.UsageStats.LastUsed == nil {
-		return time.Time{}
+// auditAccountCooldown records a cooldown set until the given time, or
+// cleared when until is nil.
+func auditAccountCooldown(ctx context.Context, database *db.DB, accountID string, until *time.Time, err error) {
+	if until == nil {
+		newAuditRecorder(database).Record(ctx, account.AuditClearCooldown, models.EntityTypeAccount, accountID, nil, err)
+		return
 	}
-	return account.UsageStats.LastUsed.UTC()
+	newAuditRecorder(database).Record(ctx, account.AuditSetCooldown, models.EntityTypeAccount, accountID, audit.Params(
+		"until", until.UTC().Format(time.RFC3339),
+	), err)
 }
 
 func recordAccountRotation(ctx context.Context, repo *db.EventRepository, agentID, oldAccountID, newAccountID, reason string) error {
@@ -1072,7 +1212,7 @@ func recordAccountRotation(ctx context.Context, repo *db.EventRepository, agentI
 		Reason:       reason,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal rotation payload: %w", err)
+		return wrapServiceError(err, "failed to marshal rotation payload")
 	}
 
 	event := &models.Event{
@@ -1083,12 +1223,39 @@ func recordAccountRotation(ctx context.Context, repo *db.EventRepository, agentI
 	}
 
 	if err := repo.Create(ctx, event); err != nil {
-		return fmt.Errorf("failed to record rotation event: %w", err)
+		return wrapServiceError(err, "failed to record rotation event")
 	}
 
 	return nil
 }
 
+// accountFilterFields are the fields accounts list --filter can compare.
+var accountFilterFields = filterFields{
+	"provider": filterText,
+	"profile":  filterText,
+	"status":   filterText,
+	"id":       filterText,
+	"age":      filterAge,
+}
+
+// formatAccountProvider labels a custom account with its provider name.
+func formatAccountProvider(account *models.Account) string {
+	if account.ProviderName != "" {
+		return fmt.Sprintf("%s (%s)", account.Provider, account.ProviderName)
+	}
+	return string(account.Provider)
+}
+
+func accountFilterRecord(account *models.Account) filterRecord {
+	r := newFilterRecord()
+	r.setText("provider", string(account.Provider))
+	r.setText("profile", account.ProfileName)
+	r.setText("status", formatAccountStatus(account))
+	r.setText("id", account.ID)
+	r.setTime("age", &account.CreatedAt)
+	return r
+}
+
 func formatAccountStatus(account *models.Account) string {
 	if !account.IsActive {
 		return "inactive"
@@ -1113,10 +1280,37 @@ func formatAccountCooldown(account *models.Account) string {
 	return remaining.Round(time.Second).String()
 }
 
+// formatCredentialExpiry renders when credentials expire ("in 2h"),
+// flagged with a "!" once within the warning threshold.
+func formatCredentialExpiry(expiresAt *time.Time, now time.Time, warning time.Duration) string {
+	if expiresAt == nil {
+		return "-"
+	}
+	remaining := expiresAt.Sub(now)
+	if remaining <= 0 {
+		return "expired !"
+	}
+	var text string
+	switch {
+	case remaining < time.Minute:
+		text = "in <1m"
+	case remaining < time.Hour:
+		text = fmt.Sprintf("in %dm", int(remaining.Minutes()))
+	case remaining < 48*time.Hour:
+		text = fmt.Sprintf("in %dh", int(remaining.Hours()))
+	default:
+		text = fmt.Sprintf("in %dd", int(remaining.Hours()/24))
+	}
+	if warning > 0 && remaining <= warning {
+		text += " !"
+	}
+	return text
+}
+
 func parseCooldownUntil(value string) (time.Time, error) {
 	value = strings.TrimSpace(value)
 	if value == "" {
-		return time.Time{}, fmt.Errorf("cooldown time is required")
+		return time.Time{}, invalidInputError("cooldown time is required")
 	}
 
 	if dur, err := parseDurationWithDays(value); err == nil {
@@ -1136,7 +1330,7 @@ func parseCooldownUntil(value string) (time.Time, error) {
 		return t.UTC(), nil
 	}
 
-	return time.Time{}, fmt.Errorf("invalid time format: %q (use duration like '30m' or timestamp like '2024-01-15T10:30:00Z')", value)
+	return time.Time{}, invalidInputError("invalid time format: %q (use duration like '30m' or timestamp like '2024-01-15T10:30:00Z')", value)
 }
 
 func filterAccountsWithCooldown(accounts []*models.Account) []*models.Account {
@@ -1163,5 +1357,5 @@ func findAccountByProfile(ctx context.Context, repo *db.AccountRepository, provi
 			return acct, nil
 		}
 	}
-	return nil, fmt.Errorf("account %q for provider %s not found", profile, provider)
+	return nil, notFoundError("account %q for provider %s not found", profile, provider)
 }
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestAccountsAddExecCmd(t *testing.T) {
+	useTestDatabase(t)
+
+	script := filepath.Join(t.TempDir(), "key.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho sk-ant-REDACTED\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	if code, err := runCommand(t, newAccountsAddCmd(), "--provider", "anthropic", "--profile", "missing", "--exec-cmd", "no-such-password-manager read key"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected an unknown command to be rejected, got exit %d: %v", code, err)
+	}
+	if code, err := runCommand(t, newAccountsAddCmd(), "--provider", "anthropic", "--profile", "typo", "--credential-ref", "exce:"+script); code != ExitCodeInvalidInput {
+		t.Fatalf("expected an unknown scheme to be rejected, got exit %d: %v", code, err)
+	}
+
+	var created models.Account
+	out := runJSONCommand(t, newAccountsAddCmd(), "--provider", "anthropic", "--profile", "op", "--exec-cmd", script)
+	if err := json.Unmarshal(out, &created); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if created.CredentialRef != "exec:"+script {
+		t.Fatalf("unexpected credential ref %q", created.CredentialRef)
+	}
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// accountTimelineWidth is the number of cells in the cooldown timeline bar.
+const accountTimelineWidth = 12
+
+// accountsStatusFlags holds the flags of 'swarm accounts status'.
+type accountsStatusFlags struct {
+	provider string
+}
+
+func newAccountsStatusCmd() *cobra.Command {
+	var flags accountsStatusFlags
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show account availability, rotations, and today's usage",
+		Long: `Show a rate-limit dashboard for provider accounts.
+
+Accounts are listed soonest-available first. The timeline bar shows each
+cooldown's remaining time relative to the longest one listed. Rotation counts
+include rotations to and from the account; today's totals are for the
+current UTC day.`,
+		Example: `  swarm accounts status
+  swarm accounts status --provider anthropic
+  swarm accounts status --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			var provider *models.Provider
+			if strings.TrimSpace(flags.provider) != "" {
+				parsed, err := models.ParseProvider(flags.provider)
+				if err != nil {
+					return err
+				}
+				provider = &parsed
+			}
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			now := time.Now().UTC()
+			statuses, err := account.BuildStatus(ctx, account.StatusSources{
+				Accounts: db.NewAccountRepository(database),
+				Events:   db.NewEventRepository(database),
+				Usage:    db.NewUsageRepository(database),
+			}, provider, now)
+			if err != nil {
+				return wrapServiceError(err, "failed to build account status")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(statuses)
+			}
+
+			if len(statuses) == 0 {
+				fmt.Fprintln(opts.Out, "No accounts found.")
+				return nil
+			}
+
+			var longest int64
+			for _, status := range statuses {
+				if status.CooldownRemainingSeconds > longest {
+					longest = status.CooldownRemainingSeconds
+				}
+			}
+
+			warning := credentialExpiryWarning()
+			rows := make([][]string, 0, len(statuses))
+			for _, status := range statuses {
+				lastUsed := "-"
+				if status.LastUsed != nil {
+					lastUsed = formatRelativeTime(*status.LastUsed)
+				}
+				rows = append(rows, []string{
+					string(status.Provider),
+					status.ProfileName,
+					string(status.State),
+					formatAccountTimeline(status, longest),
+					formatCredentialExpiry(status.CredentialExpiresAt, now, warning),
+					fmt.Sprintf("%d", status.Rotations24h),
+					fmt.Sprintf("%d", status.Rotations7d),
+					lastUsed,
+					fmt.Sprintf("%d", status.TodayTokens),
+					fmt.Sprintf("$%.2f", float64(status.TodayCostCents)/100),
+				})
+			}
+			return opts.writeTable([]string{"PROVIDER", "PROFILE", "STATE", "COOLDOWN", "EXPIRES", "ROT 24H", "ROT 7D", "LAST USED", "TOKENS TODAY", "COST TODAY"}, rows)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.provider, "provider", "", "filter by provider (anthropic, openai, google, custom)")
+	return cmd
+}
+
+// formatAccountTimeline renders the remaining cooldown as a bar scaled to
+// longest (seconds) followed by the local end time, or "ready".
+func formatAccountTimeline(status *account.Status, longest int64) string {
+	if status.CooldownUntil == nil {
+		if status.State == account.StatusInactive {
+			return "-"
+		}
+		return "ready"
+	}
+	filled := accountTimelineWidth
+	if longest > 0 {
+		filled = int((status.CooldownRemainingSeconds*accountTimelineWidth + longest - 1) / longest)
+	}
+	if filled < 1 {
+		filled = 1
+	}
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", accountTimelineWidth-filled)
+	return fmt.Sprintf("[%s] until %s", bar, status.CooldownUntil.Local().Format("15:04"))
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestAccountsStatusTable(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	until := time.Now().UTC().Add(30 * time.Minute)
+	repo := db.NewAccountRepository(database)
+	for _, account := range []*models.Account{
+		{Provider: models.ProviderAnthropic, ProfileName: "cooling", CredentialRef: "env:A", IsActive: true, CooldownUntil: &until},
+		{Provider: models.ProviderAnthropic, ProfileName: "ready", CredentialRef: "env:B", IsActive: true},
+		{Provider: models.ProviderOpenAI, ProfileName: "other", CredentialRef: "env:C", IsActive: true},
+	} {
+		if err := repo.Create(ctx, account); err != nil {
+			t.Fatalf("create account: %v", err)
+		}
+	}
+
+	out := captureStdout(t, func() {
+		if code, err := runCommand(t, newAccountsStatusCmd(), "--provider", "anthropic"); code != 0 {
+			t.Errorf("accounts status failed with exit %d: %v", code, err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows:\n%s", out)
+	}
+	if !strings.Contains(lines[1], "ready") || !strings.Contains(lines[1], "available") {
+		t.Fatalf("expected the available account first:\n%s", out)
+	}
+	if want := "[############] until " + until.Local().Format("15:04"); !strings.Contains(lines[2], want) {
+		t.Fatalf("expected cooldown timeline %q:\n%s", want, out)
+	}
+}
@@ -14,118 +14,88 @@ import (
 	"strings"
 	"time"
 
+	"github.com/opencode-ai/swarm/internal/adapters"
 	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/approval"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/models"
 	"github.com/opencode-ai/swarm/internal/node"
 	"github.com/opencode-ai/swarm/internal/queue"
+	"github.com/opencode-ai/swarm/internal/quiethours"
 	"github.com/opencode-ai/swarm/internal/tmux"
 	"github.com/opencode-ai/swarm/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
-var (
-	// agent spawn flags
-	agentSpawnWorkspace string
-	agentSpawnType      string
-	agentSpawnCount     int
-	agentSpawnProfile   string
-	agentSpawnPrompt    string
-	agentSpawnNoWait    bool
-
-	// agent list flags
-	agentListWorkspace string
-	agentListState     string
-
-	// agent terminate flags
-	agentTerminateForce bool
-
-	// agent pause flags
-	agentPauseDuration string
-
-	// agent send flags
-	agentSendSkipIdle bool
-	agentSendFile     string
-	agentSendStdin    bool
-	agentSendEditor   bool
-
-	// agent queue flags
-	agentQueueFile       string
-	agentQueuePauseAfter int
-
-	// agent approve flags
-	agentApproveAll  bool
-	agentApproveDeny bool
-)
-
-func init() {
-	rootCmd.AddCommand(agentCmd)
-	agentCmd.AddCommand(agentSpawnCmd)
-	agentCmd.AddCommand(agentListCmd)
-	agentCmd.AddCommand(agentStatusCmd)
-	agentCmd.AddCommand(agentTerminateCmd)
-	agentCmd.AddCommand(agentInterruptCmd)
-	agentCmd.AddCommand(agentPauseCmd)
-	agentCmd.AddCommand(agentResumeCmd)
-	agentCmd.AddCommand(agentSendCmd)
-	agentCmd.AddCommand(agentRestartCmd)
-	agentCmd.AddCommand(agentQueueCmd)
-	agentCmd.AddCommand(agentApproveCmd)
-
-	// Spawn flags
-	agentSpawnCmd.Flags().StringVarP(&agentSpawnWorkspace, "workspace", "w", "", "workspace name or ID (uses context if not set)")
-	agentSpawnCmd.Flags().StringVarP(&agentSpawnType, "type", "t", "opencode", "agent type (opencode, claude-code, codex, gemini, generic)")
-	agentSpawnCmd.Flags().IntVarP(&agentSpawnCount, "count", "n", 1, "number of agents to spawn")
-	agentSpawnCmd.Flags().StringVarP(&agentSpawnProfile, "profile", "p", "", "account profile to use")
-	agentSpawnCmd.Flags().StringVar(&agentSpawnPrompt, "prompt", "", "initial prompt to send after spawn")
-	agentSpawnCmd.Flags().BoolVar(&agentSpawnNoWait, "no-wait", false, "don't wait for agent to be ready")
-
-	// List flags
-	agentListCmd.Flags().StringVarP(&agentListWorkspace, "workspace", "w", "", "filter by workspace (uses context if not set)")
-	agentListCmd.Flags().StringVar(&agentListState, "state", "", "filter by state (working, idle, paused, error, etc.)")
-
-	// Terminate flags
-	agentTerminateCmd.Flags().BoolVarP(&agentTerminateForce, "force", "f", false, "force termination")
-
-	// Pause flags
-	agentPauseCmd.Flags().StringVarP(&agentPauseDuration, "duration", "d", "5m", "pause duration (e.g., 30s, 5m, 1h)")
-
-	// Send flags
-	agentSendCmd.Flags().BoolVar(&agentSendSkipIdle, "skip-idle-check", false, "send even if agent is not idle")
-	agentSendCmd.Flags().StringVarP(&agentSendFile, "file", "f", "", "read message from file")
-	agentSendCmd.Flags().BoolVar(&agentSendStdin, "stdin", false, "read message from stdin")
-	agentSendCmd.Flags().BoolVar(&agentSendEditor, "editor", false, "compose message in $EDITOR")
-	_ = agentSendCmd.Flags().MarkDeprecated("skip-idle-check", "this command now queues messages; use 'swarm inject --force' for immediate dispatch")
-
-	// Queue flags
-	agentQueueCmd.Flags().StringVarP(&agentQueueFile, "file", "f", "", "file containing prompts (one per line)")
-	agentQueueCmd.Flags().IntVar(&agentQueuePauseAfter, "pause-after", 0, "insert 60s pause after every N messages (0 = no pauses)")
-
-	// Approve flags
-	agentApproveCmd.Flags().BoolVar(&agentApproveAll, "all", false, "approve or deny all pending approvals for the agent")
-	agentApproveCmd.Flags().BoolVar(&agentApproveDeny, "deny", false, "deny pending approvals instead of approving")
-}
-
-var agentCmd = &cobra.Command{
-	Use:   "agent",
-	Short: "Manage agents",
-	Long: `Manage AI coding agents running in workspaces.
+func newAgentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Manage agents",
+		Long: `Manage AI coding agents running in workspaces.
 
 Agents are instances of AI coding CLIs (opencode, claude-code, etc.) running
 in tmux panes within workspaces.`,
+	}
+
+	cmd.AddCommand(newAgentSpawnCmd())
+	cmd.AddCommand(newAgentListCmd())
+	cmd.AddCommand(newAgentStatusCmd())
+	cmd.AddCommand(newAgentTerminateCmd())
+	cmd.AddCommand(newAgentInterruptCmd())
+	cmd.AddCommand(newAgentPeekCmd())
+	cmd.AddCommand(newAgentPauseCmd())
+	cmd.AddCommand(newAgentResumeCmd())
+	cmd.AddCommand(newAgentSendCmd())
+	cmd.AddCommand(newAgentRestartCmd())
+	cmd.AddCommand(newAgentQueueCmd())
+	cmd.AddCommand(newAgentApproveCmd())
+	cmd.AddCommand(newAgentBundleCmd())
+	cmd.AddCommand(newAgentCheckpointCmd())
+	cmd.AddCommand(newAgentRestoreCmd())
+	cmd.AddCommand(newAgentDrainCmd())
+	cmd.AddCommand(newAgentUndrainCmd())
+	cmd.AddCommand(newAgentEnvCmd())
+	cmd.AddCommand(newAgentMoveCmd())
+	cmd.AddCommand(newAgentRecordCmd())
+	cmd.AddCommand(newAgentReplayCmd())
+	cmd.AddCommand(newAgentSnapshotCmd())
+	cmd.AddCommand(newAgentTranscriptPushCmd())
+	cmd.AddCommand(newAgentVerifyPanesCmd())
+	cmd.AddCommand(newAgentVersionsCmd())
+	cmd.AddCommand(newAgentWaitCmd())
+	cmd.AddCommand(newAgentWakeCmd())
+	return cmd
 }
 
-var agentSpawnCmd = &cobra.Command{
-	Use:   "spawn",
-	Short: "Spawn a new agent",
-	Long: `Spawn one or more AI coding agents in a workspace.
+// agentSpawnFlags holds the flags of 'swarm agent spawn'.
+type agentSpawnFlags struct {
+	workspace string
+	agentType string
+	count     int
+	profile   string
+	prompt    string
+	noWait    bool
+	model     string
+	record    bool
+	override  bool
+	env       []string
+	tags      []string
+	quiet     string
+}
+
+func newAgentSpawnCmd() *cobra.Command {
+	var flags agentSpawnFlags
+	cmd := &cobra.Command{
+		Use:   "spawn",
+		Short: "Spawn a new agent",
+		Long: `Spawn one or more AI coding agents in a workspace.
 
 The agent will be started in a new tmux pane in the workspace's session.
 
 If --workspace is not specified, the workspace is resolved from:
 1. Current directory (if in a workspace's git repo)
 2. Stored context (set with 'swarm use <workspace>')`,
-	Example: `  # Spawn in current workspace (from directory or context)
+		Example: `  # Spawn in current workspace (from directory or context)
   swarm agent spawn
 
   # Spawn a single opencode agent
@@ -135,654 +105,957 @@ If --workspace is not specified, the workspace is resolved from:
   swarm agent spawn -w my-project -t claude-code -n 3 -p work-account
 
   # Spawn with an initial prompt
-  swarm agent spawn -w my-project --prompt "Fix all linting errors"`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+  swarm agent spawn -w my-project --prompt "Fix all linting errors"
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+  # Spawn a claude-code agent on a specific model
+  swarm agent spawn -t claude-code --model opus`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
 
-		// Create services
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		tmuxClient := tmux.NewLocalClient()
-		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+			// Create services
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
 
-		// Resolve workspace from flag, directory, or stored context
-		resolved, err := RequireWorkspaceContext(ctx, wsRepo, agentSpawnWorkspace)
-		if err != nil {
-			return err
-		}
-		ws, err := wsRepo.Get(ctx, resolved.WorkspaceID)
-		if err != nil {
-			return fmt.Errorf("failed to get workspace: %w", err)
-		}
+			tmuxClient := tmux.NewLocalClient()
+			agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
 
-		approvalPolicy := ""
-		if cfg := GetConfig(); cfg != nil {
-			approvalPolicy = cfg.ApprovalPolicyForWorkspace(ws).Mode
-		}
+			// Resolve workspace from flag, directory, or stored context
+			resolved, err := RequireWorkspaceContext(ctx, wsRepo, flags.workspace)
+			if err != nil {
+				return err
+			}
+			ws, err := wsRepo.Get(ctx, resolved.WorkspaceID)
+			if err != nil {
+				return wrapServiceError(err, "failed to get workspace")
+			}
 
-		// Parse agent type
-		agentType := models.AgentType(agentSpawnType)
-		switch agentType {
-		case models.AgentTypeOpenCode, models.AgentTypeClaudeCode,
-			models.AgentTypeCodex, models.AgentTypeGemini, models.AgentTypeGeneric:
-			// Valid
-		default:
-			return fmt.Errorf("invalid agent type: %s", agentSpawnType)
-		}
+			approvalPolicy := ""
+			if cfg := GetConfig(); cfg != nil {
+				approvalPolicy = cfg.ApprovalPolicyForWorkspace(ws).Mode
+			}
+
+			// Parse agent type
+			agentType := models.AgentType(flags.agentType)
+			if !adapters.IsRegistered(agentType) {
+				return invalidInputError("invalid agent type: %s", flags.agentType)
+			}
 
-		// Spawn agents
-		var agents []*models.Agent
-		for i := 0; i < agentSpawnCount; i++ {
-			opts := agent.SpawnOptions{
+			model := resolveSpawnModel(ws, agentType, flags.model)
+			env, err := parseEnvFlags(flags.env)
+			if err != nil {
+				return invalidInputError("%v", err)
+			}
+			if _, err := quiethours.ParseSchedule(flags.quiet); err != nil {
+				return invalidInputError("invalid --quiet-hours: %v", err)
+			}
+
+			// Spawn agents
+			spawnOpts := agent.SpawnOptions{
 				WorkspaceID:    ws.ID,
 				Type:           agentType,
-				AccountID:      agentSpawnProfile,
-				InitialPrompt:  agentSpawnPrompt,
+				AccountID:      flags.profile,
+				InitialPrompt:  flags.prompt,
 				ApprovalPolicy: approvalPolicy,
+				Model:          model,
+				Record:         flags.record,
+				OverrideBudget: flags.override,
+				Tags:           flags.tags,
+				QuietHours:     flags.quiet,
+
+				WorkspaceEnvironment: workspaceSpawnEnv(ws),
+				Environment:          env,
 			}
 			// If --no-wait is set, use a very short timeout to skip waiting
-			if agentSpawnNoWait {
-				opts.ReadyTimeout = 1 * time.Millisecond
+			if flags.noWait {
+				spawnOpts.ReadyTimeout = 1 * time.Millisecond
 			}
 
-			step := startProgress(fmt.Sprintf("Spawning agent %d/%d", i+1, agentSpawnCount))
-			a, err := agentService.SpawnAgent(ctx, opts)
+			agents, err := spawnAgents(ctx, opts, agentService, flags.count, spawnOpts)
 			if err != nil {
-				step.Fail(err)
-				if len(agents) > 0 {
-					// Partial success
-					if !IsJSONOutput() && !IsJSONLOutput() {
-						fmt.Printf("Spawned %d/%d agents before error: %v\n", len(agents), agentSpawnCount, err)
+				if len(agents) == 0 {
+					return wrapServiceError(err, "failed to spawn agent")
+				}
+				if ctx.Err() != nil {
+					// Interrupted: keep the agents already running and say so.
+					if !opts.JSON && !opts.JSONL {
+						fmt.Fprintf(opts.Err, "Spawned %d/%d agents before stopping:\n", len(agents), flags.count)
+						for _, a := range agents {
+							fmt.Fprintf(opts.Err, "  %s (%s) - %s\n", a.ID, a.Type, a.State)
+						}
 					}
-					break
+					return wrapServiceError(err, "failed to spawn agent %d/%d", len(agents)+1, flags.count)
+				}
+				// Partial success
+				if !opts.JSON && !opts.JSONL {
+					fmt.Fprintf(opts.Out, "Spawned %d/%d agents before error: %v\n", len(agents), flags.count, err)
 				}
-				return fmt.Errorf("failed to spawn agent: %w", err)
 			}
-			step.Done()
-			agents = append(agents, a)
-		}
 
-		if len(agents) > 0 && ws.TmuxSession != "" {
-			layoutManager := tmux.NewLayoutManager(
-				tmuxClient,
-				tmux.WithLayoutPreset(tmux.LayoutPresetTiled),
-				tmux.WithLayoutWindow(tmux.AgentWindowName),
-			)
-			if err := layoutManager.Balance(ctx, ws.TmuxSession); err != nil && !IsJSONOutput() && !IsJSONLOutput() {
-				fmt.Fprintf(os.Stderr, "Warning: failed to rebalance agent panes: %v\n", err)
+			if len(agents) > 0 && ws.TmuxSession != "" {
+				layoutManager := tmux.NewLayoutManager(
+					tmuxClient,
+					tmux.WithLayoutPreset(tmux.LayoutPresetTiled),
+					tmux.WithLayoutWindow(tmux.AgentWindowName),
+				)
+				if err := layoutManager.Balance(ctx, ws.TmuxSession); err != nil && !opts.JSON && !opts.JSONL {
+					fmt.Fprintf(opts.Err, "Warning: failed to rebalance agent panes: %v\n", err)
+				}
 			}
-		}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			if len(agents) == 1 {
-				return WriteOutput(os.Stdout, agents[0])
+			if opts.Structured() {
+				if len(agents) == 1 {
+					return opts.WriteOutput(agents[0])
+				}
+				return opts.WriteOutput(agents)
 			}
-			return WriteOutput(os.Stdout, agents)
-		}
 
-		if len(agents) == 1 {
-			a := agents[0]
-			fmt.Printf("Agent spawned:\n")
-			fmt.Printf("  ID:        %s\n", a.ID)
-			fmt.Printf("  Type:      %s\n", a.Type)
-			fmt.Printf("  Workspace: %s\n", ws.Name)
-			fmt.Printf("  Pane:      %s\n", a.TmuxPane)
-			fmt.Printf("  State:     %s\n", a.State)
-			if a.AccountID != "" {
-				fmt.Printf("  Profile:   %s\n", a.AccountID)
+			if len(agents) == 1 {
+				a := agents[0]
+				fmt.Fprintf(opts.Out, "Agent spawned:\n")
+				fmt.Fprintf(opts.Out, "  ID:        %s\n", a.ID)
+				fmt.Fprintf(opts.Out, "  Type:      %s\n", a.Type)
+				fmt.Fprintf(opts.Out, "  Workspace: %s\n", ws.Name)
+				fmt.Fprintf(opts.Out, "  Pane:      %s\n", a.TmuxPane)
+				fmt.Fprintf(opts.Out, "  State:     %s\n", a.State)
+				if a.Metadata.Model != "" {
+					fmt.Fprintf(opts.Out, "  Model:     %s\n", a.Metadata.Model)
+				}
+				if a.AccountID != "" {
+					fmt.Fprintf(opts.Out, "  Profile:   %s\n", a.AccountID)
+				}
+			} else {
+				fmt.Fprintf(opts.Out, "Spawned %d agents:\n", len(agents))
+				for _, a := range agents {
+					fmt.Fprintf(opts.Out, "  %s (%s) - %s\n", a.ID, a.Type, a.State)
+				}
 			}
-		} else {
-			fmt.Printf("Spawned %d agents:\n", len(agents))
+
+			// Print next steps
+			agentIDs := make([]string, 0, len(agents))
 			for _, a := range agents {
-				fmt.Printf("  %s (%s) - %s\n", a.ID, a.Type, a.State)
+				agentIDs = append(agentIDs, a.ID)
 			}
-		}
+			opts.printNextSteps(HintContext{
+				Action:        "spawn",
+				AgentIDs:      agentIDs,
+				WorkspaceID:   ws.ID,
+				WorkspaceName: ws.Name,
+			})
 
-		// Print next steps
-		agentIDs := make([]string, 0, len(agents))
-		for _, a := range agents {
-			agentIDs = append(agentIDs, a.ID)
-		}
-		PrintNextSteps(HintContext{
-			Action:        "spawn",
-			AgentIDs:      agentIDs,
-			WorkspaceID:   ws.ID,
-			WorkspaceName: ws.Name,
-		})
+			return nil
+		},
+	}
 
-		return nil
-	},
+	cmd.Flags().StringVarP(&flags.workspace, "workspace", "w", "", "workspace name or ID (uses context if not set)")
+	cmd.Flags().StringVarP(&flags.agentType, "type", "t", "opencode", "agent type (opencode, claude-code, codex, gemini, generic)")
+	cmd.Flags().IntVarP(&flags.count, "count", "n", 1, "number of agents to spawn")
+	cmd.Flags().StringVarP(&flags.profile, "profile", "p", "", "account profile to use")
+	cmd.Flags().StringVar(&flags.prompt, "prompt", "", "initial prompt to send after spawn")
+	cmd.Flags().BoolVar(&flags.noWait, "no-wait", false, "don't wait for agent to be ready")
+	cmd.Flags().StringVar(&flags.model, "model", "", "model to pass to the agent CLI (overrides config default)")
+	cmd.Flags().BoolVar(&flags.record, "record", false, "record the agent's pane for 'swarm agent replay'")
+	cmd.Flags().BoolVar(&flags.override, "override-budget", false, "spawn even if the projected daily cost exceeds the budget")
+	cmd.Flags().StringArrayVar(&flags.env, "env", nil, "environment variable KEY=VALUE for the agent (repeatable)")
+	cmd.Flags().StringSliceVar(&flags.tags, "tag", nil, "tag the agent for 'swarm ws broadcast --tag' (repeatable)")
+	cmd.Flags().StringVar(&flags.quiet, "quiet-hours", "", `quiet hours overriding the workspace's, e.g. "mon-fri 22:00-07:00 Europe/Oslo" or "none"`)
+	_ = cmd.RegisterFlagCompletionFunc("workspace", completeWorkspaceNames)
+	return cmd
+}
+
+// agentListFlags holds the flags of 'swarm agent list'.
+type agentListFlags struct {
+	workspace string
+	state     string
+	all       bool
+	stats     bool
+	filter    string
 }
 
-var agentListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List agents",
-	Long: `List all agents managed by Swarm.
+func newAgentListCmd() *cobra.Command {
+	var flags agentListFlags
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List agents",
+		Long: `List all agents managed by Swarm.
 
 If --workspace is not specified, filters by workspace from context (if set).
-Use --workspace="" to list all agents across workspaces.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+Use --workspace="" to list all agents across workspaces.
+
+With --stats, each agent also shows how many queue items have been
+dispatched to it, the p50/p95 time they waited in the queue, and how many
+were dispatched in the last hour.
+
+--filter keeps the agents matching an expression over their fields:
+state (also "blocked" for agents that cannot take work), type, workspace
+(name or ID), tag, account, queue (queued items), age (time since spawn),
+and last_activity (time since last activity). Comparisons (=, !=, <, <=,
+>, >=, in [...], not in [...]) combine with and, or, not, and
+parentheses. A filter naming a workspace replaces the context's.`,
+		Example: `  swarm agent list --filter 'state=idle and queue>0 and age>2h'
+  swarm agent list --filter 'state in [idle, blocked] and not tag=backend'
+  swarm agent list --filter 'type=codex or last_activity>1d'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAgentList(cmd, flags)
+		},
+	}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+	cmd.Flags().StringVarP(&flags.workspace, "workspace", "w", "", "filter by workspace (uses context if not set)")
+	cmd.Flags().StringVar(&flags.state, "state", "", "filter by state (working, idle, paused, error, etc.)")
+	cmd.Flags().BoolVar(&flags.all, "all", false, "include terminated and ephemeral agents")
+	cmd.Flags().BoolVar(&flags.stats, "stats", false, "include queue wait percentiles and throughput")
+	addFilterFlag(cmd, &flags.filter, "state=idle and queue>0 and age>2h")
+	_ = cmd.RegisterFlagCompletionFunc("workspace", completeWorkspaceNames)
+	return cmd
+}
+
+// runAgentList lists agents for agent list and its ps alias.
+func runAgentList(cmd *cobra.Command, flags agentListFlags) error {
+	opts := commandOptions(cmd)
+	ctx := cmd.Context()
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+	filter, err := listFilterFlag(flags.filter, agentFilterFields)
+	if err != nil {
+		return err
+	}
 
-		tmuxClient := tmux.NewLocalClient()
-		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	nodeRepo := db.NewNodeRepository(database)
+	nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+	wsRepo := db.NewWorkspaceRepository(database)
+	agentRepo := db.NewAgentRepository(database)
+	queueRepo := db.NewQueueRepository(database)
+	wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+
+	tmuxClient := tmux.NewLocalClient()
+	agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+
+	// Build options
+	listOpts := agent.ListAgentsOptions{
+		IncludeQueueLength: true,
+		IncludeDeleted:     flags.all,
+		IncludeEphemeral:   flags.all,
+	}
 
-		// Build options
-		opts := agent.ListAgentsOptions{
-			IncludeQueueLength: true,
+	// Use context resolution for workspace filter (optional - just for filtering)
+	if flags.workspace != "" {
+		ws, err := findWorkspace(ctx, wsRepo, flags.workspace)
+		if err != nil {
+			return err
 		}
-
-		// Use context resolution for workspace filter (optional - just for filtering)
-		if agentListWorkspace != "" {
-			ws, err := findWorkspace(ctx, wsRepo, agentListWorkspace)
-			if err != nil {
-				return err
-			}
-			opts.WorkspaceID = ws.ID
-		} else if !cmd.Flags().Changed("workspace") {
-			// Use context if flag wasn't explicitly set
-			resolved, _ := ResolveWorkspaceContext(ctx, wsRepo, "")
-			if resolved != nil && resolved.WorkspaceID != "" {
-				opts.WorkspaceID = resolved.WorkspaceID
-			}
+		listOpts.WorkspaceID = ws.ID
+	} else if name, ok := filter.Pushdown("workspace"); ok {
+		ws, err := findWorkspace(ctx, wsRepo, name)
+		if err != nil {
+			return err
+		}
+		listOpts.WorkspaceID = ws.ID
+	} else if !cmd.Flags().Changed("workspace") {
+		// Use context if flag wasn't explicitly set
+		resolved, _ := ResolveWorkspaceContext(ctx, wsRepo, "")
+		if resolved != nil && resolved.WorkspaceID != "" {
+			listOpts.WorkspaceID = resolved.WorkspaceID
 		}
+	}
 
-		if agentListState != "" {
-			state := models.AgentState(agentListState)
-			opts.State = &state
+	if flags.state != "" {
+		state := models.AgentState(flags.state)
+		listOpts.State = &state
+	} else if name, ok := filter.Pushdown("state"); ok && !strings.EqualFold(name, agentStateBlocked) {
+		state := models.AgentState(strings.ToLower(name))
+		listOpts.State = &state
+	}
+
+	agents, err := agentService.ListAgents(ctx, listOpts)
+	if err != nil {
+		return wrapServiceError(err, "failed to list agents")
+	}
+	if filter != nil {
+		if agents, err = filterAgents(ctx, wsRepo, agents, filter); err != nil {
+			return err
 		}
+	}
 
-		agents, err := agentService.ListAgents(ctx, opts)
+	var queueStats map[string]models.QueueWaitStats
+	if flags.stats {
+		queueStats, err = loadQueueStats(ctx, database)
 		if err != nil {
-			return fmt.Errorf("failed to list agents: %w", err)
+			return wrapServiceError(err, "failed to load queue metrics")
 		}
+	}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, agents)
+	if opts.Structured() {
+		if flags.stats {
+			views := make([]agentStatsView, 0, len(agents))
+			for _, a := range agents {
+				stats, ok := queueStats[a.ID]
+				if !ok {
+					stats.AgentID = a.ID
+				}
+				views = append(views, agentStatsView{Agent: a, QueueStats: stats})
+			}
+			return opts.WriteOutput(views)
 		}
+		return opts.WriteOutput(agents)
+	}
 
-		if len(agents) == 0 {
-			fmt.Println("No agents found")
-			return nil
-		}
+	if len(agents) == 0 {
+		fmt.Fprintln(opts.Out, "No agents found")
+		return nil
+	}
 
-		rows := make([][]string, 0, len(agents))
-		for _, a := range agents {
-			workspaceID := "-"
-			if a.WorkspaceID != "" {
-				workspaceID = shortID(a.WorkspaceID)
-			}
-			pane := a.TmuxPane
-			if pane == "" {
-				pane = "-"
-			}
-			rows = append(rows, []string{
-				shortID(a.ID),
-				string(a.Type),
-				formatAgentState(a.State),
-				workspaceID,
-				pane,
-				fmt.Sprintf("%d", a.QueueLength),
-			})
+	// Stuck agents need a human; list them first under a summary.
+	agents = stuckAgentsFirst(agents)
+	writeStuckSummary(opts.Out, agents)
+
+	rows := make([][]string, 0, len(agents))
+	for _, a := range agents {
+		workspaceID := "-"
+		if a.WorkspaceID != "" {
+			workspaceID = shortID(a.WorkspaceID)
+		}
+		pane := a.TmuxPane
+		if pane == "" {
+			pane = "-"
+		}
+		state := formatAgentState(a.State)
+		if a.IsTerminated() {
+			state = formatStatusLabel("END", "terminated")
+		} else if hib := a.Metadata.Hibernation; a.State == models.AgentStateHibernated && hib != nil {
+			// A hibernated agent has no pane; show how long it has slept.
+			label, color := statusLabelForAgent(a.State)
+			state = opts.colorize(formatStatusLabel(label, "hibernated "+formatRelativeTime(hib.HibernatedAt)), color)
+			pane = "-"
+		}
+		row := []string{
+			shortID(a.ID),
+			string(a.Type),
+			state,
+			workspaceID,
+			pane,
+			fmt.Sprintf("%d", a.QueueLength),
+		}
+		if flags.stats {
+			stats := queueStats[a.ID]
+			row = append(row,
+				fmt.Sprintf("%d", stats.Dispatched),
+				formatWaitMs(stats.WaitP50Ms, stats.Samples),
+				formatWaitMs(stats.WaitP95Ms, stats.Samples),
+				fmt.Sprintf("%d", stats.PerHour),
+			)
 		}
-		return writeTable(os.Stdout, []string{"ID", "TYPE", "STATE", "WORKSPACE", "PANE", "QUEUE"}, rows)
-	},
+		rows = append(rows, row)
+	}
+	headers := []string{"ID", "TYPE", "STATE", "WORKSPACE", "PANE", "QUEUE"}
+	if flags.stats {
+		headers = append(headers, "DISPATCHED", "WAIT P50", "WAIT P95", "PER HOUR")
+	}
+	return opts.writeTable(headers, rows)
 }
 
-var agentStatusCmd = &cobra.Command{
-	Use:   "status <agent-id>",
-	Short: "Show agent status",
-	Long:  "Display detailed status for an agent including state, queue, and recent activity.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		agentID := args[0]
+func newAgentStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "status <agent-id>",
+		Aliases: []string{"show"},
+		Short:   "Show agent status",
+		Long:    "Display detailed status for an agent including state, queue, queue wait metrics, cost so far today, and recent activity.",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			agentID := args[0]
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			tmuxClient := tmux.NewLocalClient()
+			agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
 
-		tmuxClient := tmux.NewLocalClient()
-		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+			resolved, err := findAgent(ctx, agentRepo, agentID)
+			if err != nil {
+				return err
+			}
 
-		resolved, err := findAgent(ctx, agentRepo, agentID)
-		if err != nil {
-			return err
-		}
+			stateResult, err := agentService.GetAgentState(ctx, resolved.ID)
+			if err != nil {
+				if errors.Is(err, agent.ErrServiceAgentNotFound) {
+					return notFoundError("agent '%s' not found", resolved.ID)
+				}
+				return wrapServiceError(err, "failed to get agent status")
+			}
 
-		stateResult, err := agentService.GetAgentState(ctx, resolved.ID)
-		if err != nil {
-			if errors.Is(err, agent.ErrServiceAgentNotFound) {
-				return fmt.Errorf("agent '%s' not found", resolved.ID)
+			if record, err := db.NewQueueMetricsRepository(database).Get(ctx, resolved.ID); err == nil {
+				stats := queue.Summarize(record, time.Now())
+				stateResult.QueueStats = &stats
+			} else if !errors.Is(err, db.ErrQueueMetricsNotFound) {
+				return wrapServiceError(err, "failed to load queue metrics")
+			}
+			if stateResult.LatestNote, err = latestNote(ctx, database, models.EntityTypeAgent, resolved.ID); err != nil {
+				return err
+			}
+			ticker, err := loadCostTicker(ctx, database, resolved.ID)
+			if err != nil {
+				return err
+			}
+			if cost, ok := ticker.Cost(resolved.ID); ok {
+				stateResult.Cost = &cost
 			}
-			return fmt.Errorf("failed to get agent status: %w", err)
-		}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, stateResult)
-		}
+			if opts.Structured() {
+				return opts.WriteOutput(stateResult)
+			}
 
-		a := stateResult.Agent
-		fmt.Printf("Agent: %s\n", a.ID)
-		fmt.Printf("Type:  %s\n", a.Type)
-		fmt.Printf("State: %s\n", formatAgentState(a.State))
-		fmt.Printf("  Confidence: %s\n", a.StateInfo.Confidence)
-		fmt.Printf("  Reason:     %s\n", a.StateInfo.Reason)
-		if len(a.StateInfo.Evidence) > 0 {
-			fmt.Printf("  Evidence:\n")
-			for _, e := range a.StateInfo.Evidence {
-				fmt.Printf("    - %s\n", e)
+			a := stateResult.Agent
+			fmt.Fprintf(opts.Out, "Agent: %s\n", a.ID)
+			fmt.Fprintf(opts.Out, "Type:  %s\n", a.Type)
+			fmt.Fprintf(opts.Out, "State: %s\n", formatAgentState(a.State))
+			if stateResult.LatestNote != nil {
+				fmt.Fprintf(opts.Out, "Note:  %s\n", formatNote(stateResult.LatestNote))
 			}
-		}
-		fmt.Println()
+			if f := a.Metadata.Failure; f != nil {
+				fmt.Fprintf(opts.Out, "  Failure:    %s (%s)\n", f.Summary, f.Category)
+				fmt.Fprintf(opts.Out, "  Severity:   %s\n", f.Severity)
+				fmt.Fprintf(opts.Out, "  Action:     %s\n", f.Action)
+				if f.Excerpt != "" {
+					fmt.Fprintf(opts.Out, "  Output:     %s\n", f.Excerpt)
+				}
+			}
+			fmt.Fprintf(opts.Out, "  Confidence: %s\n", a.StateInfo.Confidence)
+			fmt.Fprintf(opts.Out, "  Reason:     %s\n", a.StateInfo.Reason)
+			if len(a.StateInfo.Evidence) > 0 {
+				fmt.Fprintf(opts.Out, "  Evidence:\n")
+				for _, e := range a.StateInfo.Evidence {
+					fmt.Fprintf(opts.Out, "    - %s\n", e)
+				}
+			}
+			fmt.Fprintln(opts.Out)
 
-		fmt.Printf("Workspace: %s\n", a.WorkspaceID)
-		fmt.Printf("Pane:      %s\n", a.TmuxPane)
-		fmt.Printf("Pane Active: %v\n", stateResult.PaneActive)
-		fmt.Println()
+			fmt.Fprintf(opts.Out, "Workspace: %s\n", a.WorkspaceID)
+			fmt.Fprintf(opts.Out, "Pane:      %s\n", a.TmuxPane)
+			fmt.Fprintf(opts.Out, "Pane Active: %v\n", stateResult.PaneActive)
+			fmt.Fprintln(opts.Out)
 
-		if a.AccountID != "" {
-			fmt.Printf("Profile: %s\n", a.AccountID)
-		}
+			if a.AccountID != "" {
+				fmt.Fprintf(opts.Out, "Profile: %s\n", a.AccountID)
+			}
 
-		fmt.Printf("Queue Length: %d\n", stateResult.QueueLength)
+			fmt.Fprintf(opts.Out, "Queue Length: %d\n", stateResult.QueueLength)
+			if stateResult.QueueStats != nil {
+				fmt.Fprintf(opts.Out, "Queue Wait:   %s\n", formatQueueStats(*stateResult.QueueStats))
+			}
+			if stateResult.Cost != nil {
+				fmt.Fprintf(opts.Out, "Cost Today:   %s\n", formatAgentCost(*stateResult.Cost))
+			}
 
-		if a.LastActivity != nil {
-			fmt.Printf("Last Activity: %s\n", a.LastActivity.Format(time.RFC3339))
-		}
+			if a.LastActivity != nil {
+				fmt.Fprintf(opts.Out, "Last Activity: %s\n", a.LastActivity.Format(time.RFC3339))
+			}
 
-		if a.PausedUntil != nil {
-			fmt.Printf("Paused Until: %s\n", a.PausedUntil.Format(time.RFC3339))
-		}
+			if a.PausedUntil != nil {
+				fmt.Fprintf(opts.Out, "Paused Until: %s\n", a.PausedUntil.Format(time.RFC3339))
+			}
+
+			var workspaceQuietHours string
+			if ws, err := wsRepo.Get(ctx, a.WorkspaceID); err == nil {
+				workspaceQuietHours = ws.QuietHours
+			}
+			if quiet := quietHoursStatus(time.Now(), a.Metadata.QuietHours, workspaceQuietHours); quiet != "" {
+				fmt.Fprintf(opts.Out, "Dispatch:     %s\n", quiet)
+			}
 
-		fmt.Printf("\nCreated: %s\n", a.CreatedAt.Format(time.RFC3339))
+			fmt.Fprintf(opts.Out, "\nCreated: %s\n", a.CreatedAt.Format(time.RFC3339))
 
-		if stateResult.LastOutput != "" {
-			fmt.Printf("\nLast Output (truncated):\n")
-			output := stateResult.LastOutput
-			if len(output) > 500 {
-				output = output[len(output)-500:]
+			if stateResult.LastOutput != "" {
+				fmt.Fprintf(opts.Out, "\nLast Output (truncated):\n")
+				output := stateResult.LastOutput
+				if len(output) > 500 {
+					output = output[len(output)-500:]
+				}
+				fmt.Fprintln(opts.Out, output)
 			}
-			fmt.Println(output)
-		}
 
-		return nil
-	},
+			return nil
+		},
+	}
+	return cmd
 }
 
-var agentTerminateCmd = &cobra.Command{
-	Use:     "terminate <agent-id>",
-	Aliases: []string{"kill", "rm"},
-	Short:   "Terminate an agent",
-	Long:    "Stop and remove an agent. This kills the tmux pane and removes the agent record.",
-	Args:    cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		agentID := args[0]
-
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+// agentTerminateFlags holds the flags of 'swarm agent terminate'.
+type agentTerminateFlags struct {
+	force  bool
+	dryRun bool
+}
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+func newAgentTerminateCmd() *cobra.Command {
+	var flags agentTerminateFlags
+	cmd := &cobra.Command{
+		Use:     "terminate <agent-id>",
+		Aliases: []string{"kill", "rm"},
+		Short:   "Terminate an agent",
+		Long: `Stop and remove an agent. This kills the tmux pane and removes the agent record.
+
+The record is removed only once the pane is confirmed gone. If the pane
+survives, the agent is kept and marked as error; --force then kills the
+pane's process and, failing that, its whole window.
+
+With --dry-run, the agent is resolved and the pane that would be killed
+and the queue items that would be cleared are shown; nothing is changed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			agentID := args[0]
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		tmuxClient := tmux.NewLocalClient()
-		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
 
-		resolved, err := findAgent(ctx, agentRepo, agentID)
-		if err != nil {
-			return err
-		}
+			tmuxClient := tmux.NewLocalClient()
+			agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
 
-		// Confirm destructive action
-		impact := "This will kill the tmux pane and remove the agent record."
-		if !ConfirmDestructiveAction("agent", resolved.ID, impact) {
-			fmt.Fprintln(os.Stderr, "Cancelled.")
-			return nil
-		}
+			resolved, err := findAgent(ctx, agentRepo, agentID)
+			if err != nil {
+				return err
+			}
 
-		step := startProgress("Terminating agent")
-		if err := agentService.TerminateAgent(ctx, resolved.ID); err != nil {
-			step.Fail(err)
-			if errors.Is(err, agent.ErrServiceAgentNotFound) {
-				return fmt.Errorf("agent '%s' not found", resolved.ID)
+			terminateOpts := agent.TerminateOptions{Force: flags.force, DryRun: flags.dryRun}
+			if terminateOpts.DryRun {
+				plan, err := agentService.TerminateAgent(ctx, resolved.ID, terminateOpts)
+				if err != nil {
+					return wrapServiceError(err, "failed to plan agent termination")
+				}
+				if opts.Structured() {
+					return opts.WriteOutput(plan)
+				}
+				if err := writeTerminatePlans(opts, []*agent.TerminatePlan{plan}); err != nil {
+					return err
+				}
+				printDryRunNote(opts.Out)
+				return nil
 			}
-			return fmt.Errorf("failed to terminate agent: %w", err)
-		}
-		step.Done()
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, map[string]any{
-				"terminated": true,
-				"agent_id":   resolved.ID,
-			})
-		}
+			// Confirm destructive action
+			impact := "This will kill the tmux pane and remove the agent record."
+			if !opts.ConfirmDestructiveAction("agent", resolved.ID, impact) {
+				fmt.Fprintln(opts.Err, "Cancelled.")
+				return nil
+			}
 
-		fmt.Printf("Agent '%s' terminated\n", resolved.ID)
-		return nil
-	},
-}
+			step := opts.startProgress("Terminating agent")
+			if _, err := agentService.TerminateAgent(ctx, resolved.ID, terminateOpts); err != nil {
+				step.Fail(err)
+				if errors.Is(err, agent.ErrServiceAgentNotFound) {
+					return notFoundError("agent '%s' not found", resolved.ID)
+				}
+				if errors.Is(err, tmux.ErrPaneStillAlive) && !opts.JSON && !opts.JSONL {
+					fmt.Fprintf(opts.Err, "The agent's pane %s is still running, so its record was kept and marked as error.\n", resolved.TmuxPane)
+					if flags.force {
+						fmt.Fprintf(opts.Err, "Inspect it with 'tmux list-panes -a' and stop the process by hand, then run 'swarm agent terminate %s' again.\n", shortID(resolved.ID))
+					} else {
+						fmt.Fprintf(opts.Err, "Retry with 'swarm agent terminate %s --force' to kill its process and window.\n", shortID(resolved.ID))
+					}
+				}
+				return wrapServiceError(err, "failed to terminate agent")
+			}
+			step.Done()
 
-var agentInterruptCmd = &cobra.Command{
-	Use:   "interrupt <agent-id>",
-	Short: "Interrupt an agent",
-	Long:  "Send Ctrl+C to an agent to interrupt its current operation.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		agentID := args[0]
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{
+					"terminated": true,
+					"agent_id":   resolved.ID,
+				})
+			}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			fmt.Fprintf(opts.Out, "Agent '%s' terminated\n", resolved.ID)
+			return nil
+		},
+	}
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "if the pane survives kill-pane, kill its process and then its window if the pane is alone in it")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "show what would be killed and cleared without doing it")
+	return cmd
+}
 
-		tmuxClient := tmux.NewLocalClient()
-		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+func newAgentInterruptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "interrupt <agent-id>",
+		Short: "Interrupt an agent",
+		Long:  "Send Ctrl+C to an agent to interrupt its current operation.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			agentID := args[0]
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		resolved, err := findAgent(ctx, agentRepo, agentID)
-		if err != nil {
-			return err
-		}
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+
+			tmuxClient := tmux.NewLocalClient()
+			agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
 
-		if err := agentService.InterruptAgent(ctx, resolved.ID); err != nil {
-			if errors.Is(err, agent.ErrServiceAgentNotFound) {
-				return fmt.Errorf("agent '%s' not found", resolved.ID)
+			resolved, err := findAgent(ctx, agentRepo, agentID)
+			if err != nil {
+				return err
 			}
-			return fmt.Errorf("failed to interrupt agent: %w", err)
-		}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, map[string]any{
-				"interrupted": true,
-				"agent_id":    resolved.ID,
-			})
-		}
+			if err := agentService.InterruptAgent(ctx, resolved.ID); err != nil {
+				if errors.Is(err, agent.ErrServiceAgentNotFound) {
+					return notFoundError("agent '%s' not found", resolved.ID)
+				}
+				return wrapServiceError(err, "failed to interrupt agent")
+			}
 
-		fmt.Printf("Agent '%s' interrupted\n", resolved.ID)
-		return nil
-	},
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{
+					"interrupted": true,
+					"agent_id":    resolved.ID,
+				})
+			}
+
+			fmt.Fprintf(opts.Out, "Agent '%s' interrupted\n", resolved.ID)
+			return nil
+		},
+	}
+	return cmd
 }
 
-var agentPauseCmd = &cobra.Command{
-	Use:   "pause <agent-id>",
-	Short: "Pause an agent",
-	Long:  "Pause an agent for a specified duration. The scheduler will skip paused agents.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		agentID := args[0]
+// agentPauseFlags holds the flags of 'swarm agent pause'.
+type agentPauseFlags struct {
+	duration string
+}
 
-		duration, err := time.ParseDuration(agentPauseDuration)
-		if err != nil {
-			return fmt.Errorf("invalid duration: %w", err)
-		}
+func newAgentPauseCmd() *cobra.Command {
+	var flags agentPauseFlags
+	cmd := &cobra.Command{
+		Use:   "pause <agent-id>",
+		Short: "Pause an agent",
+		Long:  "Pause an agent for a specified duration. The scheduler will skip paused agents.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			agentID := args[0]
+
+			duration, err := time.ParseDuration(flags.duration)
+			if err != nil {
+				return invalidInputError("invalid duration: %w", err)
+			}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
 
-		tmuxClient := tmux.NewLocalClient()
-		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+			tmuxClient := tmux.NewLocalClient()
+			agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
 
-		resolved, err := findAgent(ctx, agentRepo, agentID)
-		if err != nil {
-			return err
-		}
+			resolved, err := findAgent(ctx, agentRepo, agentID)
+			if err != nil {
+				return err
+			}
 
-		if err := agentService.PauseAgent(ctx, resolved.ID, duration); err != nil {
-			if errors.Is(err, agent.ErrServiceAgentNotFound) {
-				return fmt.Errorf("agent '%s' not found", resolved.ID)
+			if err := agentService.PauseAgent(ctx, resolved.ID, duration); err != nil {
+				if errors.Is(err, agent.ErrServiceAgentNotFound) {
+					return notFoundError("agent '%s' not found", resolved.ID)
+				}
+				return wrapServiceError(err, "failed to pause agent")
 			}
-			return fmt.Errorf("failed to pause agent: %w", err)
-		}
 
-		pausedUntil := time.Now().Add(duration)
+			pausedUntil := time.Now().Add(duration)
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, map[string]any{
-				"paused":       true,
-				"agent_id":     resolved.ID,
-				"duration":     duration.String(),
-				"paused_until": pausedUntil.Format(time.RFC3339),
-			})
-		}
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{
+					"paused":       true,
+					"agent_id":     resolved.ID,
+					"duration":     duration.String(),
+					"paused_until": pausedUntil.Format(time.RFC3339),
+				})
+			}
 
-		fmt.Printf("Agent '%s' paused until %s\n", resolved.ID, pausedUntil.Format(time.RFC3339))
-		return nil
-	},
+			fmt.Fprintf(opts.Out, "Agent '%s' paused until %s\n", resolved.ID, pausedUntil.Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.duration, "duration", "d", "5m", "pause duration (e.g., 30s, 5m, 1h)")
+	return cmd
 }
 
-var agentResumeCmd = &cobra.Command{
-	Use:   "resume <agent-id>",
-	Short: "Resume a paused agent",
-	Long:  "Resume an agent that was previously paused.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		agentID := args[0]
+func newAgentResumeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume <agent-id>",
+		Short: "Resume a paused agent",
+		Long:  "Resume an agent that was previously paused.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			agentID := args[0]
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			tmuxClient := tmux.NewLocalClient()
+			agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
 
-		tmuxClient := tmux.NewLocalClient()
-		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+			resolved, err := findAgent(ctx, agentRepo, agentID)
+			if err != nil {
+				return err
+			}
 
-		resolved, err := findAgent(ctx, agentRepo, agentID)
-		if err != nil {
-			return err
-		}
+			if err := agentService.ResumeAgent(ctx, resolved.ID); err != nil {
+				if errors.Is(err, agent.ErrServiceAgentNotFound) {
+					return notFoundError("agent '%s' not found", resolved.ID)
+				}
+				return wrapServiceError(err, "failed to resume agent")
+			}
 
-		if err := agentService.ResumeAgent(ctx, resolved.ID); err != nil {
-			if errors.Is(err, agent.ErrServiceAgentNotFound) {
-				return fmt.Errorf("agent '%s' not found", resolved.ID)
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{
+					"resumed":  true,
+					"agent_id": resolved.ID,
+				})
 			}
-			return fmt.Errorf("failed to resume agent: %w", err)
-		}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, map[string]any{
-				"resumed":  true,
-				"agent_id": resolved.ID,
-			})
-		}
+			fmt.Fprintf(opts.Out, "Agent '%s' resumed\n", resolved.ID)
+			return nil
+		},
+	}
+	return cmd
+}
 
-		fmt.Printf("Agent '%s' resumed\n", resolved.ID)
-		return nil
-	},
+// agentSendFlags holds the flags of 'swarm agent send'.
+type agentSendFlags struct {
+	skipIdle  bool
+	file      string
+	stdin     bool
+	editor    bool
+	sensitive bool
 }
 
-var agentSendCmd = &cobra.Command{
-	Use:        "send <agent-id> [message]",
-	Short:      "Queue a message for an agent (DEPRECATED: use 'swarm send')",
-	Deprecated: "Use 'swarm send' for queue-based dispatch. This command is an alias.",
-	Long: `DEPRECATED: Use 'swarm send' for queue-based dispatch.
+func newAgentSendCmd() *cobra.Command {
+	var flags agentSendFlags
+	cmd := &cobra.Command{
+		Use:        "send <agent-id> [message]",
+		Short:      "Queue a message for an agent (DEPRECATED: use 'swarm send')",
+		Deprecated: "Use 'swarm send' for queue-based dispatch. This command is an alias.",
+		Long: `DEPRECATED: Use 'swarm send' for queue-based dispatch.
 
 This command now queues messages instead of immediate injection.
 For immediate dispatch, use 'swarm send --immediate' or 'swarm inject'.
 
-Provide the message inline, or use --file, --stdin, or --editor to send multi-line input.`,
-	Example: `  # Recommended: use 'swarm send' directly
+Provide the message inline, or use --file, --stdin, or --editor to send multi-line input.
+
+--sensitive sends a secret, such as a token for a login prompt, straight to
+the agent instead of queueing it. It is read from a masked prompt, or from
+stdin when stdin is not a terminal, so it never appears in shell history.
+The transcript records "[sensitive input]" in its place and leaves out the
+pane output while it may show the secret.`,
+		Example: `  # Recommended: use 'swarm send' directly
   swarm send abc123 "Fix the lint errors"
 
   # Legacy alias (now queued)
   swarm agent send abc123 "Fix the lint errors"
 
   # Send a multi-line message from a file
-  swarm agent send abc123 --file prompt.txt`,
-	Args: cobra.MinimumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		agentID := args[0]
+  swarm agent send abc123 --file prompt.txt
+
+  # Answer a login prompt without recording the token
+  swarm agent send abc123 --sensitive`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			agentID := args[0]
+
+			if flags.sensitive {
+				return sendSensitiveInput(ctx, opts, flags, agentID, args)
+			}
 
-		message, err := resolveSendMessage(args)
-		if err != nil {
-			return err
-		}
+			message, err := resolveSendMessage(flags, args)
+			if err != nil {
+				return err
+			}
 
-		if agentSendSkipIdle && !IsJSONOutput() && !IsJSONLOutput() {
-			fmt.Fprintln(os.Stderr, "Warning: --skip-idle-check is ignored; 'swarm agent send' now queues messages.")
-		}
+			if flags.skipIdle && !opts.JSON && !opts.JSONL {
+				fmt.Fprintln(opts.Err, "Warning: --skip-idle-check is ignored; 'swarm agent send' now queues messages.")
+			}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		queueService := queue.NewService(queueRepo)
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			queueService := queue.NewService(queueRepo, queue.WithAuditRecorder(newAuditRecorder(database)))
 
-		resolved, err := findAgent(ctx, agentRepo, agentID)
-		if err != nil {
-			return err
-		}
+			resolved, err := findAgent(ctx, agentRepo, agentID)
+			if err != nil {
+				return err
+			}
 
-		result := enqueueMessage(ctx, queueService, queueRepo, resolved, message, queueOptions{})
-		results := []sendResult{result}
+			result := enqueueMessage(ctx, queueService, queueRepo, resolved, message, queueOptions{})
+			results := []sendResult{result}
 
-		if err := writeQueueResults(message, results, queueOptions{}); err != nil {
-			return err
-		}
+			if err := opts.writeQueueResults(message, results, queueOptions{}); err != nil {
+				return err
+			}
 
-		if result.Error == "" {
-			PrintNextSteps(HintContext{
-				Action:   "send",
-				AgentIDs: []string{resolved.ID},
-			})
-		}
+			if result.Error == "" {
+				opts.printNextSteps(HintContext{
+					Action:   "send",
+					AgentIDs: []string{resolved.ID},
+				})
+			}
 
-		return nil
-	},
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.skipIdle, "skip-idle-check", false, "send even if agent is not idle")
+	cmd.Flags().StringVarP(&flags.file, "file", "f", "", "read message from file")
+	cmd.Flags().BoolVar(&flags.stdin, "stdin", false, "read message from stdin")
+	cmd.Flags().BoolVar(&flags.editor, "editor", false, "compose message in $EDITOR")
+	cmd.Flags().BoolVar(&flags.sensitive, "sensitive", false, "send a secret read from a masked prompt, keeping it out of the queue and transcript")
+	_ = cmd.Flags().MarkDeprecated("skip-idle-check", "this command now queues messages; use 'swarm inject --force' for immediate dispatch")
+	return cmd
 }
 
-var agentRestartCmd = &cobra.Command{
-	Use:   "restart <agent-id>",
-	Short: "Restart an agent",
-	Long:  "Terminate and respawn an agent with the same configuration.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		agentID := args[0]
+func newAgentRestartCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restart <agent-id>",
+		Short: "Restart an agent",
+		Long:  "Terminate and respawn an agent with the same configuration.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			agentID := args[0]
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			tmuxClient := tmux.NewLocalClient()
+			agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
 
-		tmuxClient := tmux.NewLocalClient()
-		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+			resolved, err := findAgent(ctx, agentRepo, agentID)
+			if err != nil {
+				return err
+			}
 
-		resolved, err := findAgent(ctx, agentRepo, agentID)
-		if err != nil {
-			return err
-		}
+			newAgent, err := agentService.RestartAgent(ctx, resolved.ID)
+			if err != nil {
+				if errors.Is(err, agent.ErrServiceAgentNotFound) {
+					return notFoundError("agent '%s' not found", resolved.ID)
+				}
+				return wrapServiceError(err, "failed to restart agent")
+			}
 
-		newAgent, err := agentService.RestartAgent(ctx, resolved.ID)
-		if err != nil {
-			if errors.Is(err, agent.ErrServiceAgentNotFound) {
-				return fmt.Errorf("agent '%s' not found", resolved.ID)
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{
+					"restarted":    true,
+					"old_agent_id": resolved.ID,
+					"new_agent":    newAgent,
+				})
 			}
-			return fmt.Errorf("failed to restart agent: %w", err)
-		}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, map[string]any{
-				"restarted":    true,
-				"old_agent_id": resolved.ID,
-				"new_agent":    newAgent,
-			})
-		}
+			fmt.Fprintf(opts.Out, "Agent restarted:\n")
+			fmt.Fprintf(opts.Out, "  Old ID: %s\n", resolved.ID)
+			fmt.Fprintf(opts.Out, "  New ID: %s\n", newAgent.ID)
+			fmt.Fprintf(opts.Out, "  Pane:   %s\n", newAgent.TmuxPane)
+			fmt.Fprintf(opts.Out, "  State:  %s\n", newAgent.State)
+			return nil
+		},
+	}
+	return cmd
+}
 
-		fmt.Printf("Agent restarted:\n")
-		fmt.Printf("  Old ID: %s\n", resolved.ID)
-		fmt.Printf("  New ID: %s\n", newAgent.ID)
-		fmt.Printf("  Pane:   %s\n", newAgent.TmuxPane)
-		fmt.Printf("  State:  %s\n", newAgent.State)
-		return nil
-	},
+// agentQueueFlags holds the flags of 'swarm agent queue'.
+type agentQueueFlags struct {
+	file       string
+	pauseAfter int
 }
 
-var agentQueueCmd = &cobra.Command{
-	Use:   "queue <agent-id> [messages...]",
-	Short: "Queue messages for an agent",
-	Long: `Queue one or more messages for an agent.
+func newAgentQueueCmd() *cobra.Command {
+	var flags agentQueueFlags
+	cmd := &cobra.Command{
+		Use:   "queue <agent-id> [messages...]",
+		Short: "Queue messages for an agent",
+		Long: `Queue one or more messages for an agent.
 
 Messages can be provided as arguments or from a file (one per line).
 Special markers in the file:
@@ -790,7 +1063,7 @@ Special markers in the file:
   #PAUSE:120      - Insert a 120s pause
   #                - Comment line (ignored)
   (blank lines)   - Ignored`,
-	Example: `  # Queue a single message
+		Example: `  # Queue a single message
   swarm agent queue abc123 "Fix the bug"
 
   # Queue multiple messages
@@ -801,231 +1074,291 @@ Special markers in the file:
 
   # Auto-insert pauses every 5 messages
   swarm agent queue abc123 --file prompts.txt --pause-after 5`,
-	Args: cobra.MinimumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		agentID := args[0]
-
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
-
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		queueService := queue.NewService(queueRepo)
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			agentID := args[0]
 
-		// Verify agent exists
-		a, err := findAgent(ctx, agentRepo, agentID)
-		if err != nil {
-			return err
-		}
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		// Collect messages
-		var messages []string
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			queueService := queue.NewService(queueRepo, queue.WithAuditRecorder(newAuditRecorder(database)))
 
-		if agentQueueFile != "" {
-			// Read from file
-			file, err := os.Open(agentQueueFile)
+			// Verify agent exists
+			a, err := findAgent(ctx, agentRepo, agentID)
 			if err != nil {
-				return fmt.Errorf("failed to open file: %w", err)
+				return err
 			}
-			defer file.Close()
 
-			scanner := bufio.NewScanner(file)
-			for scanner.Scan() {
-				line := strings.TrimSpace(scanner.Text())
-				if line == "" {
-					continue // Skip blank lines
+			// Collect messages
+			var messages []string
+
+			if flags.file != "" {
+				// Read from file
+				file, err := os.Open(flags.file)
+				if err != nil {
+					return wrapServiceError(err, "failed to open file")
 				}
-				if strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#PAUSE") {
-					continue // Skip comments (but not pause markers)
+				defer file.Close()
+
+				scanner := bufio.NewScanner(file)
+				for scanner.Scan() {
+					line := strings.TrimSpace(scanner.Text())
+					if line == "" {
+						continue // Skip blank lines
+					}
+					if strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#PAUSE") {
+						continue // Skip comments (but not pause markers)
+					}
+					messages = append(messages, line)
+				}
+				if err := scanner.Err(); err != nil {
+					return wrapServiceError(err, "failed to read file")
 				}
-				messages = append(messages, line)
 			}
-			if err := scanner.Err(); err != nil {
-				return fmt.Errorf("failed to read file: %w", err)
+
+			// Add CLI arguments as messages
+			if len(args) > 1 {
+				messages = append(messages, args[1:]...)
 			}
-		}
 
-		// Add CLI arguments as messages
-		if len(args) > 1 {
-			messages = append(messages, args[1:]...)
-		}
+			if len(messages) == 0 {
+				return invalidInputError("no messages to queue (provide arguments or use --file)")
+			}
 
-		if len(messages) == 0 {
-			return errors.New("no messages to queue (provide arguments or use --file)")
-		}
+			// Build queue items
+			var items []*models.QueueItem
+			messageCount := 0
+
+			for _, msg := range messages {
+				// Check for pause markers
+				if strings.HasPrefix(msg, "#PAUSE") {
+					pauseSecs := 60 // default
+					if strings.HasPrefix(msg, "#PAUSE:") {
+						var duration int
+						if _, err := fmt.Sscanf(msg, "#PAUSE:%d", &duration); err == nil && duration > 0 {
+							pauseSecs = duration
+						}
+					}
 
-		// Build queue items
-		var items []*models.QueueItem
-		messageCount := 0
-
-		for _, msg := range messages {
-			// Check for pause markers
-			if strings.HasPrefix(msg, "#PAUSE") {
-				pauseSecs := 60 // default
-				if strings.HasPrefix(msg, "#PAUSE:") {
-					var duration int
-					if _, err := fmt.Sscanf(msg, "#PAUSE:%d", &duration); err == nil && duration > 0 {
-						pauseSecs = duration
+					payload := models.PausePayload{
+						DurationSeconds: pauseSecs,
+						Reason:          "scheduled pause from queue file",
 					}
+					payloadBytes, _ := json.Marshal(payload)
+
+					items = append(items, &models.QueueItem{
+						AgentID: a.ID,
+						Type:    models.QueueItemTypePause,
+						Status:  models.QueueItemStatusPending,
+						Payload: payloadBytes,
+					})
+					continue
 				}
 
-				payload := models.PausePayload{
-					DurationSeconds: pauseSecs,
-					Reason:          "scheduled pause from queue file",
-				}
+				// Regular message
+				payload := models.MessagePayload{Text: msg}
 				payloadBytes, _ := json.Marshal(payload)
 
 				items = append(items, &models.QueueItem{
 					AgentID: a.ID,
-					Type:    models.QueueItemTypePause,
+					Type:    models.QueueItemTypeMessage,
 					Status:  models.QueueItemStatusPending,
 					Payload: payloadBytes,
 				})
-				continue
-			}
 
-			// Regular message
-			payload := models.MessagePayload{Text: msg}
-			payloadBytes, _ := json.Marshal(payload)
+				messageCount++
 
-			items = append(items, &models.QueueItem{
-				AgentID: a.ID,
-				Type:    models.QueueItemTypeMessage,
-				Status:  models.QueueItemStatusPending,
-				Payload: payloadBytes,
-			})
+				// Insert auto-pause if configured
+				if flags.pauseAfter > 0 && messageCount%flags.pauseAfter == 0 {
+					pausePayload := models.PausePayload{
+						DurationSeconds: 60,
+						Reason:          fmt.Sprintf("auto-pause after %d messages", flags.pauseAfter),
+					}
+					pauseBytes, _ := json.Marshal(pausePayload)
+
+					items = append(items, &models.QueueItem{
+						AgentID: a.ID,
+						Type:    models.QueueItemTypePause,
+						Status:  models.QueueItemStatusPending,
+						Payload: pauseBytes,
+					})
+				}
+			}
 
-			messageCount++
+			// Enqueue all items
+			if err := queueService.Enqueue(ctx, a.ID, items...); err != nil {
+				return wrapServiceError(err, "failed to enqueue items")
+			}
 
-			// Insert auto-pause if configured
-			if agentQueuePauseAfter > 0 && messageCount%agentQueuePauseAfter == 0 {
-				pausePayload := models.PausePayload{
-					DurationSeconds: 60,
-					Reason:          fmt.Sprintf("auto-pause after %d messages", agentQueuePauseAfter),
+			// Count item types for output
+			msgCount := 0
+			pauseCount := 0
+			for _, item := range items {
+				switch item.Type {
+				case models.QueueItemTypeMessage:
+					msgCount++
+				case models.QueueItemTypePause:
+					pauseCount++
 				}
-				pauseBytes, _ := json.Marshal(pausePayload)
+			}
 
-				items = append(items, &models.QueueItem{
-					AgentID: a.ID,
-					Type:    models.QueueItemTypePause,
-					Status:  models.QueueItemStatusPending,
-					Payload: pauseBytes,
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{
+					"queued":      true,
+					"agent_id":    a.ID,
+					"messages":    msgCount,
+					"pauses":      pauseCount,
+					"total_items": len(items),
 				})
 			}
-		}
-
-		// Enqueue all items
-		if err := queueService.Enqueue(ctx, a.ID, items...); err != nil {
-			return fmt.Errorf("failed to enqueue items: %w", err)
-		}
 
-		// Count item types for output
-		msgCount := 0
-		pauseCount := 0
-		for _, item := range items {
-			switch item.Type {
-			case models.QueueItemTypeMessage:
-				msgCount++
-			case models.QueueItemTypePause:
-				pauseCount++
+			fmt.Fprintf(opts.Out, "Queued %d items for agent '%s':\n", len(items), truncate(a.ID, 12))
+			fmt.Fprintf(opts.Out, "  Messages: %d\n", msgCount)
+			if pauseCount > 0 {
+				fmt.Fprintf(opts.Out, "  Pauses:   %d\n", pauseCount)
 			}
-		}
+			return nil
+		},
+	}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, map[string]any{
-				"queued":      true,
-				"agent_id":    a.ID,
-				"messages":    msgCount,
-				"pauses":      pauseCount,
-				"total_items": len(items),
-			})
-		}
+	cmd.Flags().StringVarP(&flags.file, "file", "f", "", "file containing prompts (one per line)")
+	cmd.Flags().IntVar(&flags.pauseAfter, "pause-after", 0, "insert 60s pause after every N messages (0 = no pauses)")
+	return cmd
+}
 
-		fmt.Printf("Queued %d items for agent '%s':\n", len(items), truncate(a.ID, 12))
-		fmt.Printf("  Messages: %d\n", msgCount)
-		if pauseCount > 0 {
-			fmt.Printf("  Pauses:   %d\n", pauseCount)
-		}
-		return nil
-	},
+// agentApproveFlags holds the flags of 'swarm agent approve'.
+type agentApproveFlags struct {
+	all  bool
+	deny bool
 }
 
-var agentApproveCmd = &cobra.Command{
-	Use:   "approve <agent-id>",
-	Short: "Approve or deny pending approvals",
-	Long: `Handle pending approvals for an agent.
+func newAgentApproveCmd() *cobra.Command {
+	var flags agentApproveFlags
+	cmd := &cobra.Command{
+		Use:   "approve <agent-id>",
+		Short: "Approve or deny pending approvals",
+		Long: `Handle pending approvals for an agent.
 
 By default, this approves pending requests. Use --deny to deny instead.
 Use --all to apply the action to every pending approval.`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		agentRepo := db.NewAgentRepository(database)
-		approvalRepo := db.NewApprovalRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			approvalRepo := db.NewApprovalRepository(database)
 
-		agentRecord, err := findAgent(ctx, agentRepo, args[0])
-		if err != nil {
-			return err
-		}
+			agentRecord, err := findAgent(ctx, agentRepo, args[0])
+			if err != nil {
+				return err
+			}
 
-		pending, err := approvalRepo.ListPendingByAgent(ctx, agentRecord.ID)
-		if err != nil {
-			return fmt.Errorf("failed to list pending approvals: %w", err)
-		}
+			pending, err := approvalRepo.ListPendingByAgent(ctx, agentRecord.ID)
+			if err != nil {
+				return wrapServiceError(err, "failed to list pending approvals")
+			}
 
-		if len(pending) == 0 {
-			if IsJSONOutput() || IsJSONLOutput() {
-				return WriteOutput(os.Stdout, pending)
+			if len(pending) == 0 {
+				if opts.Structured() {
+					return opts.WriteOutput(pending)
+				}
+				fmt.Fprintf(opts.Out, "No pending approvals for agent %s.\n", agentRecord.ID)
+				return nil
 			}
-			fmt.Printf("No pending approvals for agent %s.\n", agentRecord.ID)
-			return nil
-		}
 
-		if len(pending) > 1 && !agentApproveAll {
-			return fmt.Errorf("agent has %d pending approvals; use --all to process all", len(pending))
-		}
+			if len(pending) > 1 && !flags.all {
+				return conflictError("agent has %d pending approvals; use --all to process all", len(pending))
+			}
 
-		action := models.ApprovalStatusApproved
-		if agentApproveDeny {
-			action = models.ApprovalStatusDenied
-		}
+			action := models.ApprovalStatusApproved
+			if flags.deny {
+				action = models.ApprovalStatusDenied
+			}
 
-		resolvedAt := time.Now().UTC()
-		updated := make([]*models.Approval, 0, len(pending))
+			resolvedAt := time.Now().UTC()
+			resolvedBy := approval.Resolver(ctx)
+			updated := make([]*models.Approval, 0, len(pending))
 
-		for _, approval := range pending {
-			if err := approvalRepo.UpdateStatus(ctx, approval.ID, action, "user"); err != nil {
-				return fmt.Errorf("failed to update approval %s: %w", approval.ID, err)
+			for _, approval := range pending {
+				if err := approvalRepo.UpdateStatus(ctx, approval.ID, action, resolvedBy); err != nil {
+					return wrapServiceError(err, "failed to update approval %s", approval.ID)
+				}
+				approval.Status = action
+				approval.ResolvedBy = resolvedBy
+				approval.ResolvedAt = &resolvedAt
+				updated = append(updated, approval)
 			}
-			approval.Status = action
-			approval.ResolvedBy = "user"
-			approval.ResolvedAt = &resolvedAt
-			updated = append(updated, approval)
-		}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, updated)
-		}
+			if opts.Structured() {
+				return opts.WriteOutput(updated)
+			}
+
+			for _, approval := range updated {
+				fmt.Fprintf(opts.Out, "Approval %s: %s\n", approval.ID, approval.RequestType)
+				fmt.Fprintf(opts.Out, "  Status: %s\n", approval.Status)
+				fmt.Fprintf(opts.Out, "  Details:\n%s\n", formatApprovalDetails(approval.RequestDetails))
+			}
 
-		for _, approval := range updated {
-			fmt.Printf("Approval %s: %s\n", approval.ID, approval.RequestType)
-			fmt.Printf("  Status: %s\n", approval.Status)
-			fmt.Printf("  Details:\n%s\n", formatApprovalDetails(approval.RequestDetails))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.all, "all", false, "approve or deny all pending approvals for the agent")
+	cmd.Flags().BoolVar(&flags.deny, "deny", false, "deny pending approvals instead of approving")
+	return cmd
+}
+
+// resolveSpawnModel returns the model to spawn with: the flag value when set,
+// otherwise the configured default for the workspace and agent type. The
+// agent service warns about names the adapter does not recognize.
+func resolveSpawnModel(ws *models.Workspace, agentType models.AgentType, flagValue string) string {
+	model := strings.TrimSpace(flagValue)
+	if model == "" {
+		if cfg := GetConfig(); cfg != nil {
+			model = cfg.ModelForWorkspace(ws, agentType)
 		}
+	}
+	return model
+}
 
-		return nil
-	},
+// workspaceSpawnEnv returns the configured default environment for agents
+// spawned in ws.
+func workspaceSpawnEnv(ws *models.Workspace) map[string]string {
+	if cfg := GetConfig(); cfg != nil {
+		return cfg.EnvironmentForWorkspace(ws)
+	}
+	return nil
+}
+
+// parseEnvFlags parses repeated KEY=VALUE flags. The value may be empty.
+func parseEnvFlags(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	env := make(map[string]string, len(values))
+	for _, raw := range values {
+		key, value, ok := strings.Cut(raw, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --env %q (expected KEY=VALUE)", raw)
+		}
+		env[key] = value
+	}
+	return env, nil
 }
 
 func formatApprovalDetails(details json.RawMessage) string {
@@ -1041,8 +1374,8 @@ func formatApprovalDetails(details json.RawMessage) string {
 	return pretty.String()
 }
 
-func resolveSendMessage(args []string) (string, error) {
-	return resolveMessage(args, agentSendFile, agentSendStdin, agentSendEditor)
+func resolveSendMessage(flags agentSendFlags, args []string) (string, error) {
+	return resolveMessage(args, flags.file, flags.stdin, flags.editor)
 }
 
 func resolveMessage(args []string, file string, stdin bool, editor bool) (string, error) {
@@ -1062,10 +1395,10 @@ func resolveMessage(args []string, file string, stdin bool, editor bool) (string
 	}
 
 	if sourceCount == 0 {
-		return "", errors.New("message required (provide <message>, --file, --stdin, or --editor)")
+		return "", invalidInputError("message required (provide <message>, --file, --stdin, or --editor)")
 	}
 	if sourceCount > 1 {
-		return "", errors.New("choose only one message source: <message>, --file, --stdin, or --editor")
+		return "", invalidInputError("choose only one message source: <message>, --file, --stdin, or --editor")
 	}
 
 	var message string
@@ -1086,7 +1419,7 @@ func resolveMessage(args []string, file string, stdin bool, editor bool) (string
 		return "", err
 	}
 	if strings.TrimSpace(message) == "" {
-		return "", errors.New("message is empty (provide content via <message>, --file, --stdin, or --editor)")
+		return "", invalidInputError("message is empty (provide content via <message>, --file, --stdin, or --editor)")
 	}
 
 	return message, nil
@@ -1095,10 +1428,10 @@ func resolveMessage(args []string, file string, stdin bool, editor bool) (string
 func readMessageFromFile(path string) (string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to read message file %q: %w", path, err)
+		return "", wrapServiceError(err, "failed to read message file %q", path)
 	}
 	if len(data) == 0 {
-		return "", fmt.Errorf("message file %q is empty (add content or use --editor/--stdin)", path)
+		return "", invalidInputError("message file %q is empty (add content or use --editor/--stdin)", path)
 	}
 	return string(data), nil
 }
@@ -1106,10 +1439,10 @@ func readMessageFromFile(path string) (string, error) {
 func readMessageFromStdin() (string, error) {
 	data, err := io.ReadAll(os.Stdin)
 	if err != nil {
-		return "", fmt.Errorf("failed to read stdin: %w", err)
+		return "", wrapServiceError(err, "failed to read stdin")
 	}
 	if len(data) == 0 {
-		return "", errors.New("stdin was empty (pipe a message or use --file/--editor)")
+		return "", invalidInputError("stdin was empty (pipe a message or use --file/--editor)")
 	}
 	return string(data), nil
 }
@@ -1120,21 +1453,21 @@ func readMessageFromEditor() (string, error) {
 		editor = os.Getenv("VISUAL")
 	}
 	if editor == "" {
-		return "", errors.New("EDITOR is not set (set $EDITOR or use --file/--stdin)")
+		return "", invalidInputError("EDITOR is not set (set $EDITOR or use --file/--stdin)")
 	}
 
 	parts := strings.Fields(editor)
 	if len(parts) == 0 {
-		return "", errors.New("EDITOR is empty")
+		return "", invalidInputError("EDITOR is empty")
 	}
 
 	tmpFile, err := os.CreateTemp("", "swarm-agent-send-*.txt")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
+		return "", wrapServiceError(err, "failed to create temp file")
 	}
 	tmpPath := tmpFile.Name()
 	if err := tmpFile.Close(); err != nil {
-		return "", fmt.Errorf("failed to close temp file: %w", err)
+		return "", wrapServiceError(err, "failed to close temp file")
 	}
 	defer os.Remove(tmpPath)
 
@@ -1144,15 +1477,69 @@ func readMessageFromEditor() (string, error) {
 	editorCmd.Stderr = os.Stderr
 
 	if err := editorCmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to run editor %q: %w", parts[0], err)
+		return "", wrapServiceError(err, "failed to run editor %q", parts[0])
 	}
 
 	data, err := os.ReadFile(tmpPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read editor output: %w", err)
+		return "", wrapServiceError(err, "failed to read editor output")
 	}
 	if len(data) == 0 {
-		return "", errors.New("editor output was empty (save content before exiting)")
+		return "", invalidInputError("editor output was empty (save content before exiting)")
 	}
 	return string(data), nil
 }
+
+// agentStateBlocked matches agents that cannot accept work in --filter
+// state comparisons, alongside their actual state.
+const agentStateBlocked = "blocked"
+
+// agentFilterFields are the fields agent list --filter can compare.
+var agentFilterFields = filterFields{
+	"state":         filterText,
+	"type":          filterText,
+	"workspace":     filterText,
+	"tag":           filterText,
+	"account":       filterText,
+	"queue":         filterNumber,
+	"age":           filterAge,
+	"last_activity": filterAge,
+}
+
+// filterAgents keeps the agents matching filter. Workspace names are
+// looked up so filters can name workspaces as well as IDs.
+func filterAgents(ctx context.Context, wsRepo *db.WorkspaceRepository, agents []*models.Agent, filter *listFilter) ([]*models.Agent, error) {
+	workspaces, err := wsRepo.List(ctx)
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list workspaces")
+	}
+	names := make(map[string]string, len(workspaces))
+	for _, ws := range workspaces {
+		names[ws.ID] = ws.Name
+	}
+
+	now := time.Now()
+	matched := make([]*models.Agent, 0, len(agents))
+	for _, a := range agents {
+		if filter.Match(agentFilterRecord(a, names[a.WorkspaceID]), now) {
+			matched = append(matched, a)
+		}
+	}
+	return matched, nil
+}
+
+func agentFilterRecord(a *models.Agent, workspaceName string) filterRecord {
+	r := newFilterRecord()
+	r.setText("state", string(a.State))
+	if a.IsBlocked() {
+		r.setText("state", agentStateBlocked)
+	}
+	r.setText("type", string(a.Type))
+	r.setText("workspace", a.WorkspaceID, workspaceName)
+	r.setText("tag", a.Metadata.Tags...)
+	r.setText("account", a.AccountID)
+	r.numbers["queue"] = float64(a.QueueLength)
+	r.setTime("age", &a.CreatedAt)
+	r.setTime("last_activity", a.LastActivity)
+	return r
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// agentSpawner spawns agents. agent.Service implements it.
+type agentSpawner interface {
+	SpawnAgent(ctx context.Context, opts agent.SpawnOptions) (*models.Agent, error)
+}
+
+// agentTerminator terminates agents. agent.Service implements it.
+type agentTerminator interface {
+	TerminateAgent(ctx context.Context, id string, opts agent.TerminateOptions) (*agent.TerminatePlan, error)
+}
+
+// spawnAgents spawns count agents one after another, reporting each as a
+// progress step. It stops at the first failure, or when ctx is cancelled,
+// and returns the agents spawned so far: an agent cancelled while it
+// starts up is cleaned up by the spawn, the ones before it are kept.
+func spawnAgents(ctx context.Context, opts Options, spawner agentSpawner, count int, spawnOpts agent.SpawnOptions) ([]*models.Agent, error) {
+	agents := make([]*models.Agent, 0, count)
+	for i := 0; i < count; i++ {
+		if err := ctx.Err(); err != nil {
+			return agents, err
+		}
+		step := opts.startProgress(fmt.Sprintf("Spawning agent %d/%d", i+1, count))
+		a, err := spawner.SpawnAgent(ctx, spawnOpts)
+		if err != nil {
+			step.Fail(err)
+			return agents, err
+		}
+		step.Done()
+		agents = append(agents, a)
+	}
+	return agents, nil
+}
+
+// terminateAgents terminates agents in order, reporting each as a
+// progress step and in the result. An agent that fails to terminate does
+// not stop the others. Cancelling ctx stops it before the next agent,
+// skipping the rest and returning ctx's error; the agent being terminated
+// at that moment is still terminated in full, so none is left with its
+// pane killed but its record in place.
+func terminateAgents(ctx context.Context, opts Options, terminator agentTerminator, agents []*models.Agent, terminateOpts agent.TerminateOptions) (*bulkResult, error) {
+	result := &bulkResult{}
+	for i, a := range agents {
+		if err := ctx.Err(); err != nil {
+			for _, rest := range agents[i:] {
+				result.skip(bulkItem{ID: rest.ID, Action: "terminate"}, "interrupted")
+			}
+			return result, err
+		}
+		step := opts.startProgress(fmt.Sprintf("Terminating agent %d/%d", i+1, len(agents)))
+		_, err := terminator.TerminateAgent(context.WithoutCancel(ctx), a.ID, terminateOpts)
+		if err != nil {
+			step.Fail(err)
+			err = wrapServiceError(err, "failed to terminate agent %s", a.ID)
+		} else {
+			step.Done()
+		}
+		result.record(bulkItem{ID: a.ID, Action: "terminate"}, err)
+	}
+	return result, nil
+}
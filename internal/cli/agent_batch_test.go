@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"go.uber.org/goleak"
+)
+
+func TestSpawnAgentsInterruptedDuringReadiness(t *testing.T) {
+	out := captureProgress(t)
+	signals := make(chan os.Signal, 2)
+
+	// The first agent comes up; the second is still loading when Ctrl+C
+	// arrives.
+	var started int
+	srv := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		started++
+		if started == 1 {
+			term.Print("claude> ")
+			return
+		}
+		term.Print("Loading...\n")
+		signals <- os.Interrupt
+	}))
+	service, workspaceID := newBatchSpawnService(t, srv)
+
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	ctx, scope := watchSignals(context.Background(), signals)
+	defer scope.stop()
+
+	agents, err := spawnAgents(ctx, Options{}, service, 3, agent.SpawnOptions{
+		WorkspaceID:       workspaceID,
+		Type:              models.AgentTypeClaudeCode,
+		ReadyTimeout:      5 * time.Second,
+		ReadyPollInterval: 5 * time.Millisecond,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the spawn cancelled, got %v", err)
+	}
+	if len(agents) != 1 || agents[0].TmuxPane != "%2" {
+		t.Fatalf("expected the first agent kept, got %+v", agents)
+	}
+	if started != 2 {
+		t.Fatalf("expected no spawn after the interrupt, got %d started", started)
+	}
+
+	panes, err := srv.Run("list-panes", "-s", "-t", "ws", "-F", "#{pane_id}")
+	if err != nil {
+		t.Fatalf("failed to list panes: %v", err)
+	}
+	if got := strings.Join(strings.Fields(panes), ","); got != "%0,%1,%2" {
+		t.Fatalf("expected the interrupted agent's pane killed, got %s", got)
+	}
+
+	progress := out.String()
+	if !strings.Contains(progress, "Spawning agent 1/3... done") || !strings.Contains(progress, "Spawning agent 2/3... interrupted\n") {
+		t.Fatalf("expected partial progress reported, got %q", progress)
+	}
+	if strings.Contains(progress, "3/3") {
+		t.Fatalf("expected the third spawn never started, got %q", progress)
+	}
+}
+
+// fakeTerminator records terminations, running onCall during each and
+// failing the agents in failures.
+type fakeTerminator struct {
+	calls    []string
+	onCall   func(n int)
+	failures map[string]error
+}
+
+func (f *fakeTerminator) TerminateAgent(ctx context.Context, id string, opts agent.TerminateOptions) (*agent.TerminatePlan, error) {
+	f.calls = append(f.calls, id)
+	if f.onCall != nil {
+		f.onCall(len(f.calls))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := f.failures[id]; err != nil {
+		return nil, err
+	}
+	return &agent.TerminatePlan{AgentID: id}, nil
+}
+
+func TestTerminateAgentsInterrupted(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	out := captureProgress(t)
+
+	signals := make(chan os.Signal, 2)
+	ctx, scope := watchSignals(context.Background(), signals)
+	defer scope.stop()
+
+	terminator := &fakeTerminator{onCall: func(n int) {
+		if n == 2 {
+			signals <- os.Interrupt
+			<-ctx.Done()
+		}
+	}}
+	agents := []*models.Agent{{ID: "agent-1"}, {ID: "agent-2"}, {ID: "agent-3"}}
+
+	result, err := terminateAgents(ctx, Options{}, terminator, agents, agent.TerminateOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the loop cancelled, got %v", err)
+	}
+	if result.Succeeded != 2 || strings.Join(terminator.calls, ",") != "agent-1,agent-2" {
+		t.Fatalf("expected the in-flight termination finished and no more, got %d killed of %v", result.Succeeded, terminator.calls)
+	}
+	if result.Skipped != 1 || result.Items[2].ID != "agent-3" || result.Items[2].Error != "interrupted" {
+		t.Fatalf("expected the third agent reported skipped, got %+v", result.Items)
+	}
+
+	progress := out.String()
+	if !strings.Contains(progress, "Terminating agent 1/3... done") || !strings.Contains(progress, "Terminating agent 2/3... interrupted\n") {
+		t.Fatalf("expected partial progress reported, got %q", progress)
+	}
+	if strings.Contains(progress, "3/3") {
+		t.Fatalf("expected the third agent left alone, got %q", progress)
+	}
+}
+
+// newBatchSpawnService returns an agent service whose workspace session
+// "ws" lives on srv.
+func newBatchSpawnService(t *testing.T, srv *tmuxtest.Server) (*agent.Service, string) {
+	t.Helper()
+
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := nodeRepo.Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	wsRepo := db.NewWorkspaceRepository(database)
+	ws := &models.Workspace{NodeID: localNode.ID, Name: "ws", RepoPath: "/repo", TmuxSession: "ws"}
+	if err := wsRepo.Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"new-session", "-d", "-s", "ws", "-c", "/repo"},
+		{"new-window", "-t", "ws", "-n", tmux.AgentWindowName, "-c", "/repo"},
+	} {
+		if _, err := srv.Run(args...); err != nil {
+			t.Fatalf("failed to seed tmux: %v", err)
+		}
+	}
+
+	agentRepo := db.NewAgentRepository(database)
+	wsService := workspace.NewService(wsRepo, node.NewService(nodeRepo), agentRepo)
+	return agent.NewService(agentRepo, db.NewQueueRepository(database), wsService, nil, tmux.NewClient(srv)), ws.ID
+}
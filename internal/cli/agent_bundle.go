@@ -0,0 +1,389 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/bundle"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/recording"
+	"github.com/opencode-ai/swarm/internal/redact"
+	"github.com/opencode-ai/swarm/internal/snapshot"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// bundleSectionSchema is the row layout version of every bundle section.
+const bundleSectionSchema = 1
+
+// agentBundleFlags holds the flags of 'swarm agent bundle'.
+type agentBundleFlags struct {
+	output string
+}
+
+func newAgentBundleCmd() *cobra.Command {
+	var flags agentBundleFlags
+	cmd := &cobra.Command{
+		Use:   "bundle <agent-id>",
+		Short: "Export everything recorded about an agent",
+		Long: `Write a tar.gz bundle with one JSONL file per kind of data recorded
+about an agent: the agent record, its full transcript, its events and state
+transitions, usage records, dispatch history, notes, snapshots, and pane
+recording.
+
+A manifest.json at the start of the bundle lists each file with its row
+count, size, and schema version, along with the database schema version,
+the redaction rules applied, the time range covered, and the trace IDs seen.
+Every row is redacted before it is written. A source that cannot be read,
+such as a swarmd node that is down, is left empty and its error noted in the
+manifest.
+
+Terminated agents can be bundled by full ID; their transcript comes from
+the archive written when they were terminated. Print a bundle's manifest
+with 'swarm bundle inspect'.`,
+		Example: `  swarm agent bundle abc123
+  swarm agent bundle abc123 --output agent-bundle.tar.gz`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentRepo := db.NewAgentRepository(database)
+			wsService := workspace.NewService(db.NewWorkspaceRepository(database), node.NewService(db.NewNodeRepository(database)), agentRepo)
+			agentService := agent.NewService(agentRepo, nil, wsService, nil, tmux.NewLocalClient(), agentServiceOptions(database)...)
+
+			target, err := findAgent(ctx, agentRepo, args[0], db.IncludeDeleted())
+			if err != nil {
+				return err
+			}
+
+			redactor := redact.Default()
+			if cfg := GetConfig(); cfg != nil {
+				if redactor, err = redact.FromConfig(cfg.Redaction); err != nil {
+					return fmt.Errorf("invalid redaction config: %w", err)
+				}
+			}
+
+			path := strings.TrimSpace(flags.output)
+			if path == "" {
+				path = fmt.Sprintf("agent-%s-bundle.tar.gz", shortID(target.ID))
+			}
+
+			manifest := &bundle.Manifest{
+				CreatedAt:    time.Now().UTC(),
+				AgentID:      target.ID,
+				SwarmVersion: rootCmd.Version,
+			}
+			if version, err := database.SchemaVersion(ctx); err == nil {
+				manifest.DBSchemaVersion = version
+			}
+
+			sections := agentBundleSections(ctx, database, agentService, target)
+			if err := writeBundleFile(path, manifest, sections, redactor); err != nil {
+				return fmt.Errorf("failed to write bundle: %w", err)
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(bundleView{Manifest: *manifest, Path: path})
+			}
+			rows := 0
+			for _, section := range manifest.Sections {
+				rows += section.Count
+			}
+			fmt.Fprintf(opts.Out, "Wrote bundle of agent %s (%d sections, %d rows)\n", shortID(target.ID), len(manifest.Sections), rows)
+			fmt.Fprintf(opts.Out, "Path: %s\n", path)
+			for _, section := range manifest.Sections {
+				if section.Error != "" {
+					fmt.Fprintf(opts.Err, "Warning: %s unavailable: %s\n", section.Name, section.Error)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.output, "output", "o", "", "bundle file to write (default agent-<id>-bundle.tar.gz)")
+	return cmd
+}
+
+// agentBundleSections returns the sections of an agent bundle, in the
+// order they are written.
+func agentBundleSections(ctx context.Context, database *db.DB, agentService *agent.Service, target *models.Agent) []bundle.Section {
+	eventRepo := db.NewEventRepository(database)
+	entityType := models.EntityTypeAgent
+	stateChanged := models.EventTypeAgentStateChanged
+
+	sections := []bundle.Section{
+		{Name: "agent", Rows: &sliceRows{rows: []any{target}}, Stamp: stampAgent},
+	}
+
+	transcript := bundle.Section{Name: "transcript", Stamp: stampTranscript}
+	if it, err := agentService.Transcript(ctx, target); err != nil {
+		transcript.Unavailable = err
+	} else {
+		transcript.Rows = &transcriptRows{it: it}
+	}
+	sections = append(sections, transcript)
+
+	sections = append(sections,
+		bundle.Section{
+			Name:  "events",
+			Rows:  newEventRows(eventRepo.Iterate(ctx, db.EventQuery{EntityType: &entityType, EntityID: &target.ID}, exportEventsPageSize), nil),
+			Stamp: stampEvent,
+		},
+		bundle.Section{
+			Name:  "state_transitions",
+			Rows:  newEventRows(eventRepo.Iterate(ctx, db.EventQuery{EntityType: &entityType, EntityID: &target.ID, Type: &stateChanged}, exportEventsPageSize), nil),
+			Stamp: stampEvent,
+		},
+		bundle.Section{
+			Name:  "usage",
+			Rows:  &usageRows{it: db.NewUsageRepository(database).Iterate(ctx, models.UsageQuery{AgentID: &target.ID}, exportEventsPageSize)},
+			Stamp: stampUsage,
+		},
+	)
+
+	dispatches := bundle.Section{Name: "dispatches", Stamp: stampQueueItem}
+	if items, err := db.NewQueueRepository(database).List(ctx, target.ID); err != nil {
+		dispatches.Unavailable = err
+	} else {
+		rows := make([]any, len(items))
+		for i, item := range items {
+			rows[i] = item
+		}
+		dispatches.Rows = &sliceRows{rows: rows}
+	}
+	sections = append(sections, dispatches)
+
+	notes := bundle.Section{Name: "notes", Stamp: stampNote}
+	if list, err := db.NewNoteRepository(database).ListByEntity(ctx, models.EntityTypeAgent, target.ID); err != nil {
+		notes.Unavailable = err
+	} else {
+		rows := make([]any, len(list))
+		for i, note := range list {
+			rows[i] = note
+		}
+		notes.Rows = &sliceRows{rows: rows}
+	}
+	sections = append(sections, notes)
+
+	snapshots := bundle.Section{Name: "snapshots", Stamp: stampSnapshot}
+	if store := agentService.Snapshots(); store == nil {
+		snapshots.Unavailable = agent.ErrSnapshotsDisabled
+	} else if list, err := store.List(target.ID); err != nil {
+		snapshots.Unavailable = err
+	} else {
+		snapshots.Rows = &snapshotRows{store: store, snaps: list}
+	}
+	sections = append(sections, snapshots)
+
+	// An agent that was never recorded has an empty recording section.
+	cast := bundle.Section{Name: "recording", Rows: &sliceRows{}}
+	if path, err := agentService.FindRecording(target.ID); err == nil {
+		cast.Rows = newRecordingRows(path)
+	} else if !errors.Is(err, agent.ErrRecordingNotFound) && !errors.Is(err, agent.ErrRecordingDisabled) {
+		cast.Unavailable = err
+	}
+	sections = append(sections, cast)
+
+	for i := range sections {
+		sections[i].Schema = bundleSectionSchema
+	}
+	return sections
+}
+
+// writeBundleFile writes a bundle next to path and renames it into place
+// once complete, so a failed bundle leaves nothing behind.
+func writeBundleFile(path string, manifest *bundle.Manifest, sections []bundle.Section, redactor *redact.Redactor) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := bundle.Write(file, manifest, sections, redactor); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// sliceRows yields rows already in memory.
+type sliceRows struct {
+	rows []any
+	pos  int
+}
+
+func (r *sliceRows) Next() bool {
+	if r.pos >= len(r.rows) {
+		return false
+	}
+	r.pos++
+	return true
+}
+
+func (r *sliceRows) Row() any   { return r.rows[r.pos-1] }
+func (r *sliceRows) Err() error { return nil }
+
+type transcriptRows struct {
+	it *agent.TranscriptIterator
+}
+
+func (r *transcriptRows) Next() bool { return r.it.Next() }
+func (r *transcriptRows) Row() any   { return r.it.Record() }
+func (r *transcriptRows) Err() error { return r.it.Err() }
+
+// bundleSnapshot is a snapshot row with its captured content.
+type bundleSnapshot struct {
+	snapshot.Snapshot
+	Content string `json:"content"`
+	Error   string `json:"error,omitempty"`
+}
+
+// snapshotRows loads one snapshot's content at a time.
+type snapshotRows struct {
+	store   *snapshot.Store
+	snaps   []*snapshot.Snapshot
+	pos     int
+	current *bundleSnapshot
+}
+
+func (r *snapshotRows) Next() bool {
+	if r.pos >= len(r.snaps) {
+		return false
+	}
+	snap := r.snaps[r.pos]
+	r.pos++
+	r.current = &bundleSnapshot{Snapshot: *snap}
+	content, err := r.store.Content(snap)
+	if err != nil {
+		r.current.Error = err.Error()
+	}
+	r.current.Content = content
+	return true
+}
+
+func (r *snapshotRows) Row() any   { return r.current }
+func (r *snapshotRows) Err() error { return nil }
+
+// bundleCastRow is a line of a recording: the header that starts each
+// segment, or one event. Time is the offset in seconds from the start of
+// the segment.
+type bundleCastRow struct {
+	Segment int               `json:"segment"`
+	Header  *recording.Header `json:"header,omitempty"`
+	Time    float64           `json:"time,omitempty"`
+	Type    string            `json:"type,omitempty"`
+	Data    string            `json:"data,omitempty"`
+}
+
+// recordingRows yields the rows of every segment of a recording, oldest
+// segment first. One segment is read at a time; segments are bounded by
+// the recorder's rotation size.
+type recordingRows struct {
+	path     string
+	segments []int
+	rows     []bundleCastRow
+	pos      int
+	err      error
+}
+
+func newRecordingRows(path string) *recordingRows {
+	segments := []int{0}
+	for n := 1; ; n++ {
+		if _, err := os.Stat(recording.SegmentPath(path, n)); err != nil {
+			break
+		}
+		segments = append([]int{n}, segments...)
+	}
+	return &recordingRows{path: path, segments: segments}
+}
+
+func (r *recordingRows) Next() bool {
+	for r.err == nil {
+		if r.pos < len(r.rows) {
+			r.pos++
+			return true
+		}
+		if len(r.segments) == 0 {
+			return false
+		}
+		n := r.segments[0]
+		r.segments = r.segments[1:]
+		r.rows, r.err = readCastSegment(recording.SegmentPath(r.path, n), n)
+		r.pos = 0
+	}
+	return false
+}
+
+func (r *recordingRows) Row() any   { return &r.rows[r.pos-1] }
+func (r *recordingRows) Err() error { return r.err }
+
+func readCastSegment(path string, n int) ([]bundleCastRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	cast, err := recording.ReadCast(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	rows := make([]bundleCastRow, 0, len(cast.Events)+1)
+	rows = append(rows, bundleCastRow{Segment: n, Header: &cast.Header})
+	for _, event := range cast.Events {
+		rows = append(rows, bundleCastRow{Segment: n, Time: event.Time.Seconds(), Type: event.Type, Data: event.Data})
+	}
+	return rows, nil
+}
+
+func stampAgent(row any) (time.Time, string) {
+	return row.(*models.Agent).CreatedAt, ""
+}
+
+func stampTranscript(row any) (time.Time, string) {
+	return row.(*agent.TranscriptRecord).Timestamp, ""
+}
+
+func stampEvent(row any) (time.Time, string) {
+	event := row.(*models.Event)
+	return event.Timestamp, event.TraceID
+}
+
+func stampUsage(row any) (time.Time, string) {
+	return row.(*models.UsageRecord).RecordedAt, ""
+}
+
+func stampQueueItem(row any) (time.Time, string) {
+	item := row.(*models.QueueItem)
+	return item.CreatedAt, item.TraceID
+}
+
+func stampNote(row any) (time.Time, string) {
+	return row.(*models.Note).CreatedAt, ""
+}
+
+func stampSnapshot(row any) (time.Time, string) {
+	return row.(*bundleSnapshot).CapturedAt, ""
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/recording"
+	"github.com/opencode-ai/swarm/internal/snapshot"
+)
+
+func TestAgentBundle(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	target := seedQueueAgent(t, database)
+
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	previous := appConfig
+	appConfig = cfg
+	t.Cleanup(func() { appConfig = previous })
+
+	// A synthetic key seeded into every source; none of it may reach the
+	// bundle.
+	secret := "sk-ant-REDACTED"
+
+	events := db.NewEventRepository(database)
+	for _, event := range []*models.Event{
+		{Type: models.EventTypeAgentStateChanged, EntityType: models.EntityTypeAgent, EntityID: target.ID, TraceID: "trace-1", Payload: json.RawMessage(`{"old_state":"working","new_state":"idle","reason":"saw ` + secret + `"}`)},
+		{Type: models.EventTypeMessageDispatched, EntityType: models.EntityTypeAgent, EntityID: target.ID, TraceID: "trace-2", Metadata: map[string]string{"prompt": "use " + secret}},
+		{Type: models.EventTypeMessageDispatched, EntityType: models.EntityTypeAgent, EntityID: "other-agent"},
+	} {
+		if err := events.Create(ctx, event); err != nil {
+			t.Fatalf("create event: %v", err)
+		}
+	}
+
+	account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "primary"}
+	if err := db.NewAccountRepository(database).Create(ctx, account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		record := &models.UsageRecord{AccountID: account.ID, AgentID: target.ID, Provider: models.ProviderAnthropic, InputTokens: int64(10 * (i + 1)), RecordedAt: time.Now().UTC()}
+		if err := db.NewUsageRepository(database).Create(ctx, record); err != nil {
+			t.Fatalf("create usage record: %v", err)
+		}
+	}
+
+	item := &models.QueueItem{Type: models.QueueItemTypeMessage, Payload: json.RawMessage(`{"text":"export ANTHROPIC_API_KEY=` + secret + `"}`)}
+	if err := db.NewQueueRepository(database).Enqueue(ctx, target.ID, item); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	note := &models.Note{EntityType: models.EntityTypeAgent, EntityID: target.ID, Text: "key was " + secret}
+	if err := db.NewNoteRepository(database).Create(ctx, note); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+
+	store := snapshot.NewStore(snapshotDir(cfg))
+	if err := store.Save(&snapshot.Snapshot{AgentID: target.ID}, "claude> "+secret+"\n"); err != nil {
+		t.Fatalf("save snapshot: %v", err)
+	}
+
+	castPath := filepath.Join(cfg.Global.DataDir, "recordings", target.ID+".cast")
+	writeTestCast(t, recording.SegmentPath(castPath, 1), "older output\r\n")
+	writeTestCast(t, castPath, "token "+secret+"\r\n")
+
+	archiveDir := filepath.Join(cfg.Global.DataDir, "archives", "agents", target.ID)
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	archive := `{"version":1,"transcript":{"captured_at":"2026-01-02T03:04:05Z","history":true,"content":"line one\nexport ANTHROPIC_API_KEY=` + secret + `\nline three\n"}}`
+	if err := os.WriteFile(filepath.Join(archiveDir, "archive-20260102-030405-1.json"), []byte(archive), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.NewAgentRepository(database).Delete(ctx, target.ID); err != nil {
+		t.Fatalf("terminate agent: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "agent-bundle.tar.gz")
+	var view bundleView
+	out := runJSONCommand(t, newAgentBundleCmd(), target.ID, "--output", path)
+	if err := json.Unmarshal(out, &view); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if view.Path != path || view.AgentID != target.ID || view.DBSchemaVersion == 0 {
+		t.Fatalf("unexpected bundle view %+v", view)
+	}
+
+	wantCounts := map[string]int{
+		"agent":             1,
+		"transcript":        3,
+		"events":            2,
+		"state_transitions": 1,
+		"usage":             2,
+		"dispatches":        1,
+		"notes":             1,
+		"snapshots":         1,
+		"recording":         4,
+	}
+	members := readBundleMembers(t, path)
+	if len(members) != len(wantCounts)+1 || members[0].name != "manifest.json" {
+		t.Fatalf("unexpected members %v", memberNames(members))
+	}
+	for _, m := range members {
+		if strings.Contains(m.data, secret) {
+			t.Errorf("secret marker found in %s", m.name)
+		}
+		if m.name == "manifest.json" {
+			continue
+		}
+		name := strings.TrimSuffix(m.name, ".jsonl")
+		section := view.Section(name)
+		if section == nil {
+			t.Errorf("member %s missing from manifest", m.name)
+			continue
+		}
+		lines := strings.Count(m.data, "\n")
+		if section.Count != wantCounts[name] || lines != section.Count {
+			t.Errorf("%s: manifest count %d, %d lines, want %d", name, section.Count, lines, wantCounts[name])
+		}
+		if section.Error != "" {
+			t.Errorf("%s: unexpected error %q", name, section.Error)
+		}
+	}
+	if strings.Join(view.TraceIDs, ",") != "trace-1,trace-2" {
+		t.Errorf("unexpected trace IDs %v", view.TraceIDs)
+	}
+	if view.TimeRange == nil || view.TimeRange.Start.After(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("expected time range to cover the archived transcript, got %+v", view.TimeRange)
+	}
+
+	var inspected bundleView
+	out = runJSONCommand(t, bundleInspectCmd, path)
+	if err := json.Unmarshal(out, &inspected); err != nil {
+		t.Fatalf("decode inspect output: %v\n%s", err, out)
+	}
+	if inspected.AgentID != target.ID || len(inspected.Sections) != len(wantCounts) {
+		t.Fatalf("unexpected inspect output %+v", inspected)
+	}
+
+	if code, _ := runCommand(t, bundleInspectCmd, castPath); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d inspecting a non-bundle, got %d", ExitCodeInvalidInput, code)
+	}
+	if code, _ := runCommand(t, bundleInspectCmd, filepath.Join(t.TempDir(), "missing.tar.gz")); code != ExitCodeNotFound {
+		t.Fatalf("expected exit %d for a missing bundle, got %d", ExitCodeNotFound, code)
+	}
+}
+
+func writeTestCast(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	w, err := recording.NewWriter(file, recording.Header{Width: 80, Height: 24})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteEvent(recording.Event{Time: time.Second, Type: recording.EventOutput, Data: data}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type bundleMember struct {
+	name string
+	data string
+}
+
+func readBundleMembers(t *testing.T, path string) []bundleMember {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open bundle: %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	var members []bundleMember
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return members
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("read %s: %v", header.Name, err)
+		}
+		members = append(members, bundleMember{name: header.Name, data: string(data)})
+	}
+}
+
+func memberNames(members []bundleMember) []string {
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = m.name
+	}
+	return names
+}
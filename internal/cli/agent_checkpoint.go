@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/checkpoint"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+func checkpointDir(cfg *config.Config) string {
+	return filepath.Join(cfg.Global.DataDir, "checkpoints")
+}
+
+// checkpointView is the JSON output for a checkpoint.
+type checkpointView struct {
+	checkpoint.Checkpoint
+	Path string `json:"path"`
+}
+
+// restoreView is the JSON output for a restored agent.
+type restoreView struct {
+	Agent      *models.Agent             `json:"agent"`
+	Checkpoint string                    `json:"checkpoint"`
+	Result     *checkpoint.RestoreResult `json:"result"`
+}
+
+func newCheckpointAgentService(database *db.DB) (*agent.Service, *db.AgentRepository, *db.WorkspaceRepository) {
+	agentRepo := db.NewAgentRepository(database)
+	wsRepo := db.NewWorkspaceRepository(database)
+	wsService := workspace.NewService(wsRepo, node.NewService(db.NewNodeRepository(database)), agentRepo)
+	agentService := agent.NewService(agentRepo, db.NewQueueRepository(database), wsService, nil, tmux.NewLocalClient(), agentServiceOptions(database)...)
+	return agentService, agentRepo, wsRepo
+}
+
+// agentCheckpointFlags holds the flags of 'swarm agent checkpoint'.
+type agentCheckpointFlags struct {
+	name string
+}
+
+func newAgentCheckpointCmd() *cobra.Command {
+	var flags agentCheckpointFlags
+	cmd := &cobra.Command{
+		Use:   "checkpoint <agent-id>",
+		Short: "Archive an agent's session files",
+		Long: `Archive the files an agent CLI keeps its session in, so the agent can
+later be restored with its conversation by 'swarm agent restore'.
+
+The agent is paused while the files are read and resumed afterwards. Which
+files are archived depends on the agent type; see session_paths under
+agent_defaults in the configuration. Checkpoints are stored as gzipped
+tarballs under the data directory and names cannot be reused.
+
+Session files are read on this machine, so agents on swarmd nodes cannot
+be checkpointed.`,
+		Example: `  swarm agent checkpoint abc123
+  swarm agent checkpoint abc123 --name before-refactor`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentService, agentRepo, _ := newCheckpointAgentService(database)
+			resolved, err := findAgent(ctx, agentRepo, args[0])
+			if err != nil {
+				return err
+			}
+
+			cp, err := agentService.Checkpoint(ctx, resolved.ID, strings.TrimSpace(flags.name))
+			if err != nil {
+				return wrapServiceError(err, "failed to checkpoint agent %s", shortID(resolved.ID))
+			}
+
+			path := agentService.Checkpoints().ArchivePath(cp.Name)
+			if opts.Structured() {
+				return opts.WriteOutput(checkpointView{Checkpoint: *cp, Path: path})
+			}
+			fmt.Fprintf(opts.Out, "Saved checkpoint %s of agent %s (%d files, %d bytes)\n", cp.Name, shortID(resolved.ID), cp.Files, cp.Bytes)
+			fmt.Fprintf(opts.Out, "Path: %s\n", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.name, "name", "", "checkpoint name (default: agent ID and time)")
+	return cmd
+}
+
+// agentRestoreFlags holds the flags of 'swarm agent restore'.
+type agentRestoreFlags struct {
+	checkpoint string
+	new        bool
+	workspace  string
+}
+
+func newAgentRestoreCmd() *cobra.Command {
+	var flags agentRestoreFlags
+	cmd := &cobra.Command{
+		Use:   "restore [agent-id]",
+		Short: "Restore an agent from a checkpoint",
+		Long: `Write a checkpoint's session files back and start the agent CLI on the
+restored session.
+
+Given an agent, the agent is stopped, its files restored, and it is
+respawned with the same options. With --new and --workspace a new agent of
+the checkpoint's type is spawned in that workspace instead.
+
+When the workspace or home directory differs from the checkpoint's, paths
+in text session files are rewritten to the new ones. Binary files that
+mention an old path are restored unchanged and reported as warnings.`,
+		Example: `  swarm agent restore abc123 --checkpoint before-refactor
+  swarm agent restore --new --workspace api-v2 --checkpoint before-refactor`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			if flags.new {
+				if len(args) > 0 || strings.TrimSpace(flags.workspace) == "" {
+					return invalidInputError("--new takes --workspace instead of an agent ID")
+				}
+			} else {
+				if len(args) != 1 {
+					return invalidInputError("agent ID required (or use --new with --workspace)")
+				}
+				if flags.workspace != "" {
+					return invalidInputError("--workspace requires --new")
+				}
+			}
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentService, agentRepo, wsRepo := newCheckpointAgentService(database)
+			restoreOpts := agent.RestoreCheckpointOptions{Checkpoint: flags.checkpoint}
+			if flags.new {
+				ws, err := findWorkspace(ctx, wsRepo, flags.workspace)
+				if err != nil {
+					return err
+				}
+				restoreOpts.WorkspaceID = ws.ID
+			} else {
+				resolved, err := findAgent(ctx, agentRepo, args[0])
+				if err != nil {
+					return err
+				}
+				restoreOpts.AgentID = resolved.ID
+			}
+
+			restored, result, err := agentService.RestoreCheckpoint(ctx, restoreOpts)
+			if err != nil {
+				return wrapServiceError(err, "failed to restore checkpoint %s", flags.checkpoint)
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(restoreView{Agent: restored, Checkpoint: flags.checkpoint, Result: result})
+			}
+			fmt.Fprintf(opts.Out, "Restored agent %s from checkpoint %s (%d files, %d rewritten)\n", shortID(restored.ID), flags.checkpoint, result.Files, result.Rewritten)
+			for _, warning := range result.Warnings {
+				fmt.Fprintf(opts.Err, "warning: %s\n", warning)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.checkpoint, "checkpoint", "", "name of the checkpoint to restore")
+	cmd.Flags().BoolVar(&flags.new, "new", false, "spawn a new agent instead of restarting one")
+	cmd.Flags().StringVarP(&flags.workspace, "workspace", "w", "", "workspace for the new agent (with --new)")
+	_ = cmd.MarkFlagRequired("checkpoint")
+	return cmd
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/schema"
+)
+
+func TestAgentCheckpointCommand(t *testing.T) {
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.AgentDefaults.SessionPaths = map[string][]string{"opencode": {"{home}/sessions/{claude_project}"}}
+	previous := appConfig
+	appConfig = cfg
+	t.Cleanup(func() { appConfig = previous })
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sessionDir := filepath.Join(home, "sessions", "-repo")
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sessionDir, "s.json"), []byte(`{"cwd":"/repo"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runJSONCommand(t, newAgentCheckpointCmd(), agent.ID, "--name", "first")
+	doc, err := outputSchema("checkpoint")
+	if err != nil {
+		t.Fatalf("outputSchema: %v", err)
+	}
+	if err := schema.Validate(doc, out); err != nil {
+		t.Fatalf("output does not match schema: %v\n%s", err, out)
+	}
+	var view checkpointView
+	if err := json.Unmarshal(out, &view); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if view.Name != "first" || view.Files != 1 || view.Path != filepath.Join(checkpointDir(cfg), "first.tar.gz") {
+		t.Fatalf("unexpected checkpoint view %+v", view)
+	}
+	if _, err := os.Stat(view.Path); err != nil {
+		t.Fatalf("expected the archive on disk: %v", err)
+	}
+
+	if code, _ := runCommand(t, newAgentCheckpointCmd(), agent.ID, "--name", "first"); code != ExitCodeConflict {
+		t.Fatalf("expected a conflict exit for a reused name, got %d", code)
+	}
+	if code, _ := runCommand(t, newAgentRestoreCmd(), "--checkpoint", "first", "--workspace", "ws"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected invalid input for --workspace without --new, got %d", code)
+	}
+}
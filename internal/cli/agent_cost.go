@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// loadCostTicker replays today's events into a cost ticker at the
+// configured budget rates, limited to one agent when agentID is set.
+func loadCostTicker(ctx context.Context, database *db.DB, agentID string) (*account.CostTicker, error) {
+	budget := config.DefaultConfig().Budget
+	if cfg := GetConfig(); cfg != nil {
+		budget = cfg.Budget
+	}
+	loc, err := budget.CostLocation()
+	if err != nil {
+		return nil, invalidInputError("%v", err)
+	}
+
+	ticker := account.NewCostTicker(budget.CostPerHour(), account.WithCostLocation(loc))
+	src := account.CostSources{Events: db.NewEventRepository(database), Agents: db.NewAgentRepository(database)}
+	if _, err := account.LoadCostTicker(ctx, src, ticker, agentID); err != nil {
+		return nil, wrapServiceError(err, "failed to load agent costs")
+	}
+	return ticker, nil
+}
+
+// formatAgentCost renders an agent's cost today on one line for 'agent
+// status', keeping the estimate apart from recorded usage.
+func formatAgentCost(cost models.AgentCost) string {
+	line := fmt.Sprintf("%s recorded", formatCents(cost.ActualCents))
+	if cost.Estimated.WorkingSeconds > 0 {
+		line += fmt.Sprintf(", ~%s estimated (%s working not yet recorded)",
+			formatCents(cost.Estimated.Cents), formatWorkingSeconds(cost.Estimated.WorkingSeconds))
+	}
+	return line
+}
+
+// formatEstimatedCents renders an estimate with its "~" marker, or "-"
+// when there is no unrecorded working time.
+func formatEstimatedCents(estimate models.CostEstimate) string {
+	if estimate.WorkingSeconds == 0 {
+		return "-"
+	}
+	return "~" + formatCents(estimate.Cents)
+}
+
+func formatCents(cents int64) string {
+	return fmt.Sprintf("$%.2f", float64(cents)/100)
+}
+
+func formatWorkingSeconds(seconds int64) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+// agentDrainFlags holds the flags of 'swarm agent drain'.
+type agentDrainFlags struct {
+	wait    bool
+	timeout time.Duration
+}
+
+func newAgentDrainCmd() *cobra.Command {
+	var flags agentDrainFlags
+	cmd := &cobra.Command{
+		Use:   "drain <agent-id>",
+		Short: "Stop an agent accepting new queue items",
+		Long: `Mark an agent draining. The scheduler keeps dispatching what is already
+in its queue, but new messages and conditionals are rejected until the
+agent is undrained.
+
+With --wait, block until the queue is empty and the agent is idle, then
+emit an agent.drained event.
+
+Exit codes:
+  0: Agent is draining (or drained, with --wait)
+  8: Timeout reached with --wait`,
+		Example: `  swarm agent drain abc123
+  swarm agent drain abc123 --wait --timeout 30m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentRepo := db.NewAgentRepository(database)
+			resolved, err := findAgent(ctx, agentRepo, args[0])
+			if err != nil {
+				return err
+			}
+
+			if !resolved.Draining {
+				if err := agentRepo.SetDraining(ctx, resolved.ID, true); err != nil {
+					return wrapServiceError(err, "failed to drain agent")
+				}
+				resolved.Draining = true
+			}
+
+			if !flags.wait {
+				if opts.Structured() {
+					return opts.WriteOutput(resolved)
+				}
+				fmt.Fprintf(opts.Out, "Agent %s is draining\n", shortID(resolved.ID))
+				return nil
+			}
+
+			if !opts.JSON && !opts.JSONL {
+				fmt.Fprintf(opts.Err, "Waiting for agent %s to drain...\n", shortID(resolved.ID))
+			}
+			return waitForDrain(ctx, opts, database, []string{resolved.ID}, flags.timeout)
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.wait, "wait", false, "block until the queue is empty and the agent is idle")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", time.Hour, "maximum time to wait with --wait (0 = no limit)")
+	return cmd
+}
+
+func newAgentUndrainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undrain <agent-id>",
+		Short: "Let a draining agent accept queue items again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentRepo := db.NewAgentRepository(database)
+			resolved, err := findAgent(ctx, agentRepo, args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := agentRepo.SetDraining(ctx, resolved.ID, false); err != nil {
+				return wrapServiceError(err, "failed to undrain agent")
+			}
+			resolved.Draining = false
+
+			if opts.Structured() {
+				return opts.WriteOutput(resolved)
+			}
+			fmt.Fprintf(opts.Out, "Agent %s accepts queue items again\n", shortID(resolved.ID))
+			return nil
+		},
+	}
+	return cmd
+}
+
+// drainWaitResult is the JSON shape emitted by drain --wait.
+type drainWaitResult struct {
+	AgentIDs     []string `json:"agent_ids"`
+	Drained      bool     `json:"drained"`
+	Reason       string   `json:"reason,omitempty"`
+	WaitDuration string   `json:"wait_duration"`
+	WaitMs       int64    `json:"wait_ms"`
+}
+
+// waitForDrain blocks until agentIDs have worked off their queues,
+// publishing an agent.drained event for each as it finishes. A timeout
+// exits with the same code as agent wait.
+func waitForDrain(ctx context.Context, opts Options, database *db.DB, agentIDs []string, timeout time.Duration) error {
+	publisher := newEventPublisher(database)
+	waiter := queue.NewDrainWaiter(
+		queue.RepositoryDrainProbe(db.NewQueueRepository(database), db.NewAgentRepository(database)),
+		queue.WithDrainedFunc(func(agentID string) {
+			if publisher != nil {
+				publisher.Publish(ctx, &models.Event{
+					Type:       models.EventTypeAgentDrained,
+					EntityType: models.EntityTypeAgent,
+					EntityID:   agentID,
+				})
+			}
+		}),
+	)
+
+	start := time.Now()
+	waitErr := waiter.Wait(ctx, agentIDs, timeout)
+	elapsed := time.Since(start)
+
+	result := drainWaitResult{
+		AgentIDs:     agentIDs,
+		Drained:      waitErr == nil,
+		WaitDuration: elapsed.Round(time.Millisecond).String(),
+		WaitMs:       elapsed.Milliseconds(),
+	}
+	switch {
+	case waitErr == nil:
+	case errors.Is(waitErr, queue.ErrDrainTimeout):
+		result.Reason = "timeout"
+	default:
+		return fmt.Errorf("failed to wait for drain: %w", waitErr)
+	}
+
+	if opts.Structured() {
+		if err := opts.WriteOutput(result); err != nil {
+			return err
+		}
+	} else if waitErr != nil {
+		fmt.Fprintf(opts.Err, "Timeout after %s: %v\n", result.WaitDuration, waitErr)
+	} else {
+		fmt.Fprintf(opts.Out, "Drained %d agent(s) (waited %s)\n", len(agentIDs), result.WaitDuration)
+	}
+
+	if waitErr != nil {
+		return &ExitError{Code: agentWaitExitTimeout, Err: waitErr, Printed: true}
+	}
+	return nil
+}
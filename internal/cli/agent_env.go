@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// agentEnvView is the JSON output of agent env.
+type agentEnvView struct {
+	AgentID     string          `json:"agent_id"`
+	Environment []models.EnvVar `json:"environment"`
+}
+
+// envCheckView is the JSON output of agent env --check.
+type envCheckView struct {
+	AgentID string           `json:"agent_id"`
+	Name    string           `json:"name"`
+	Set     bool             `json:"set"`
+	Source  models.EnvSource `json:"source,omitempty"`
+	// ComparedWith describes where the current value was read from.
+	ComparedWith string `json:"compared_with,omitempty"`
+	Matches      bool   `json:"matches"`
+}
+
+// agentEnvFlags holds the flags of 'swarm agent env'.
+type agentEnvFlags struct {
+	check string
+}
+
+func newAgentEnvCmd() *cobra.Command {
+	var flags agentEnvFlags
+	cmd := &cobra.Command{
+		Use:   "env <agent-id>",
+		Short: "Show the environment an agent was spawned with",
+		Long: `Show the environment variables an agent was spawned with, each with the
+source that set it: workspace (workspace_overrides environment), template
+(a recipe), flag (agent spawn --env), or account (injected credentials).
+Later sources win in that order; OVERRIDES lists the sources a variable
+shadowed.
+
+Secret values are shown as redaction markers. --check VAR compares the
+recorded value's hash with the value VAR would get now, from the agent's
+account for account variables and from this shell's environment otherwise,
+and exits non-zero when they differ.`,
+		Example: `  swarm agent env abc123
+  swarm agent env abc123 --check ANTHROPIC_API_KEY`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			a, err := findAgent(ctx, db.NewAgentRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+
+			if name := strings.TrimSpace(flags.check); name != "" {
+				return runEnvCheck(ctx, opts, db.NewAccountRepository(database), a, name)
+			}
+
+			env := a.Metadata.ResolvedEnv
+			if opts.Structured() {
+				if env == nil {
+					env = []models.EnvVar{}
+				}
+				return opts.WriteOutput(agentEnvView{AgentID: a.ID, Environment: env})
+			}
+			if len(env) == 0 {
+				fmt.Fprintf(opts.Out, "No environment recorded for agent %s\n", shortID(a.ID))
+				return nil
+			}
+			rows := make([][]string, 0, len(env))
+			for _, v := range env {
+				overrides := make([]string, len(v.Overrides))
+				for i, source := range v.Overrides {
+					overrides[i] = string(source)
+				}
+				rows = append(rows, []string{v.Name, string(v.Source), v.Value, strings.Join(overrides, ",")})
+			}
+			return opts.writeTable([]string{"NAME", "SOURCE", "VALUE", "OVERRIDES"}, rows)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.check, "check", "", "verify VAR was spawned with its current value, without printing it")
+	return cmd
+}
+
+func runEnvCheck(ctx context.Context, opts Options, accountRepo *db.AccountRepository, a *models.Agent, name string) error {
+	view := envCheckView{AgentID: a.ID, Name: name}
+	var recorded *models.EnvVar
+	for i := range a.Metadata.ResolvedEnv {
+		if a.Metadata.ResolvedEnv[i].Name == name {
+			recorded = &a.Metadata.ResolvedEnv[i]
+			break
+		}
+	}
+	if recorded != nil {
+		view.Set = true
+		view.Source = recorded.Source
+		current, from, err := currentEnvValue(ctx, accountRepo, a, *recorded)
+		if err != nil {
+			return err
+		}
+		view.ComparedWith = from
+		view.Matches = current != nil && agent.EnvValueHash(*current) == recorded.Hash
+	}
+
+	if opts.Structured() {
+		return opts.WriteOutput(view)
+	}
+	switch {
+	case !view.Set:
+		return notFoundError("%s was not set when agent %s was spawned", name, shortID(a.ID))
+	case !view.Matches:
+		return fmt.Errorf("%s (%s) does not match %s", name, view.Source, view.ComparedWith)
+	}
+	fmt.Fprintf(opts.Out, "%s (%s) matches %s\n", name, view.Source, view.ComparedWith)
+	return nil
+}
+
+// currentEnvValue returns the value v would get if the agent were spawned
+// now, or nil when it would be unset, and describes where it was read.
+func currentEnvValue(ctx context.Context, accountRepo *db.AccountRepository, a *models.Agent, v models.EnvVar) (*string, string, error) {
+	if v.Source == models.EnvSourceAccount && a.AccountID != "" {
+		acct, err := accountRepo.Get(ctx, a.AccountID)
+		if err != nil {
+			return nil, "", wrapServiceError(err, "failed to load account %s", a.AccountID)
+		}
+		from := fmt.Sprintf("account %s", acct.ProfileName)
+		if account.ProviderEnvVar(acct.Provider) != v.Name {
+			return nil, from, nil
+		}
+		value, err := account.ResolveCredential(acct.CredentialRef)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to resolve credential of account %s: %w", acct.ProfileName, err)
+		}
+		return &value, from, nil
+	}
+
+	value, ok := os.LookupEnv(v.Name)
+	if !ok {
+		return nil, "the local environment", nil
+	}
+	return &value, "the local environment", nil
+}
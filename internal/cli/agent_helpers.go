@@ -5,14 +5,24 @@ import (
 	"path/filepath"
 
 	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/checkpoint"
 	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/snapshot"
+	"github.com/opencode-ai/swarm/internal/tmux"
 )
 
+// spawnProbe is shared by every agent service in the process so batch
+// spawns reuse one --version check per agent type. Agent panes are local,
+// so the probe runs locally too.
+var spawnProbe = agent.NewSpawnProbe(&tmux.LocalExecutor{})
+
 func agentServiceOptions(database *db.DB) []agent.ServiceOption {
-	opts := []agent.ServiceOption{}
+	opts := []agent.ServiceOption{agent.WithSpawnProbe(spawnProbe)}
 
 	if database != nil {
 		opts = append(opts, agent.WithEventRepository(db.NewEventRepository(database)))
+		opts = append(opts, agent.WithDaemonNodes(db.NewNodeRepository(database)))
+		opts = append(opts, agent.WithAuditRecorder(newAuditRecorder(database)))
 	}
 	if publisher := newEventPublisher(database); publisher != nil {
 		opts = append(opts, agent.WithPublisher(publisher))
@@ -21,6 +31,14 @@ func agentServiceOptions(database *db.DB) []agent.ServiceOption {
 	if cfg := GetConfig(); cfg != nil {
 		archiveDir := filepath.Join(cfg.Global.DataDir, "archives", "agents")
 		opts = append(opts, agent.WithArchiveDir(archiveDir))
+		recordingDir := filepath.Join(cfg.Global.DataDir, "recordings")
+		opts = append(opts, agent.WithRecording(recordingDir, recordingSinkCommand))
+		opts = append(opts, agent.WithSnapshots(snapshot.NewStore(snapshotDir(cfg))))
+		opts = append(opts, agent.WithCheckpoints(checkpoint.NewStore(checkpointDir(cfg)), cfg.SessionPathOverrides()))
+		opts = append(opts, agent.WithCLIVersionPolicy(cfg))
+		if database != nil {
+			opts = append(opts, agent.WithBudget(cfg, budgetSources(database)))
+		}
 	}
 
 	return opts
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// agentMoveFlags holds the flags of 'swarm agent move'.
+type agentMoveFlags struct {
+	workspace string
+}
+
+func newAgentMoveCmd() *cobra.Command {
+	var flags agentMoveFlags
+	cmd := &cobra.Command{
+		Use:   "move <agent-id>",
+		Short: "Move an agent to another workspace",
+		Long: `Move a running agent into another workspace on the same node.
+
+The agent's pane moves into the target workspace's tmux session (created if
+missing) with its process still running. Its queue and history move with it.`,
+		Example: `  swarm agent move abc123 --workspace new-layout`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			nodeService := node.NewService(db.NewNodeRepository(database), node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			agentService := agent.NewService(agentRepo, db.NewQueueRepository(database), wsService, nil, tmux.NewLocalClient(), agentServiceOptions(database)...)
+
+			resolved, err := findAgent(ctx, agentRepo, args[0])
+			if err != nil {
+				return err
+			}
+			target, err := findWorkspace(ctx, wsRepo, flags.workspace)
+			if err != nil {
+				return err
+			}
+			from := resolved.WorkspaceID
+
+			moved, err := agentService.MoveAgent(ctx, resolved.ID, target.ID)
+			if err != nil {
+				return wrapServiceError(err, "failed to move agent")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(moved)
+			}
+
+			if from == target.ID {
+				fmt.Fprintf(opts.Out, "Agent %s is already in workspace %s\n", shortID(moved.ID), target.Name)
+				return nil
+			}
+			fmt.Fprintf(opts.Out, "Moved agent %s to workspace %s (pane %s)\n", shortID(moved.ID), target.Name, moved.TmuxPane)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.workspace, "workspace", "w", "", "target workspace name or ID")
+	_ = cmd.MarkFlagRequired("workspace")
+	return cmd
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/spf13/cobra"
+)
+
+// agentPeekFlags holds the flags of 'swarm agent peek'.
+type agentPeekFlags struct {
+	lines      int
+	waitChange bool
+	sinceHash  string
+	maxWait    time.Duration
+}
+
+func newAgentPeekCmd() *cobra.Command {
+	var flags agentPeekFlags
+	cmd := &cobra.Command{
+		Use:   "peek <agent-id>",
+		Short: "Print an agent's pane, optionally waiting for it to change",
+		Long: `Print the current content of an agent's pane.
+
+With --wait-change the command holds until the pane differs from
+--since-hash, or from its content when the command started, and then
+prints the new content. Agents on swarmd nodes are long-polled through
+their daemon, so a script can loop on 'agent peek --wait-change --json',
+passing each content_hash back as --since-hash, instead of polling.
+
+When --max-wait passes without a change the unchanged pane is printed, and
+the JSON output has "changed": false.`,
+		Example: `  swarm agent peek abc123
+  swarm agent peek abc123 --wait-change --max-wait 5m
+  swarm agent peek abc123 --wait-change --since-hash "$HASH" --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			if flags.lines < 0 {
+				return invalidInputError("--lines must not be negative, got %d", flags.lines)
+			}
+			if flags.sinceHash != "" && !flags.waitChange {
+				return invalidInputError("--since-hash requires --wait-change")
+			}
+			if flags.waitChange && flags.maxWait <= 0 {
+				return invalidInputError("--max-wait must be positive")
+			}
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentService, agentRepo, _ := newCheckpointAgentService(database)
+			resolved, err := findAgent(ctx, agentRepo, args[0])
+			if err != nil {
+				return err
+			}
+
+			peek := agent.PeekOptions{Lines: flags.lines}
+			if flags.waitChange {
+				peek.WaitChangeFrom = flags.sinceHash
+				if peek.WaitChangeFrom == "" {
+					current, err := agentService.PeekAgent(ctx, resolved.ID, peek)
+					if err != nil {
+						return wrapServiceError(err, "failed to capture agent %s", shortID(resolved.ID))
+					}
+					peek.WaitChangeFrom = current.ContentHash
+				}
+				peek.MaxWait = flags.maxWait
+			}
+
+			view, err := agentService.PeekAgent(ctx, resolved.ID, peek)
+			if err != nil {
+				return wrapServiceError(err, "failed to capture agent %s", shortID(resolved.ID))
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(view)
+			}
+			fmt.Fprintln(opts.Out, strings.TrimRight(view.Content, "\n"))
+			if flags.waitChange && !view.Changed {
+				fmt.Fprintf(opts.Err, "No change within %s.\n", flags.maxWait)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&flags.lines, "lines", "n", 0, "capture the last N lines (0 = visible pane)")
+	cmd.Flags().BoolVar(&flags.waitChange, "wait-change", false, "wait for the pane to change before printing it")
+	cmd.Flags().StringVar(&flags.sinceHash, "since-hash", "", "content hash to wait for a change from (default: the pane now)")
+	cmd.Flags().DurationVar(&flags.maxWait, "max-wait", time.Minute, "longest to wait for a change")
+	return cmd
+}
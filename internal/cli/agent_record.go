@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/recording"
+	"github.com/opencode-ai/swarm/internal/shell"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+func newAgentRecordCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Record agent panes",
+		Long: `Record everything an agent's pane prints, with timing, to an
+asciinema v2 cast file under the data directory.
+
+Recordings stop automatically when the agent is terminated and are kept
+for 'swarm agent replay'. Cast files rotate once they reach the size cap.`,
+	}
+
+	cmd.AddCommand(newAgentRecordStartCmd())
+	cmd.AddCommand(newAgentRecordStopCmd())
+	cmd.AddCommand(newAgentRecordSinkCmd())
+	return cmd
+}
+
+func newAgentRecordStartCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start <agent-id>",
+		Short: "Start recording an agent's pane",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAgentRecord(cmd.Context(), commandOptions(cmd), args[0], true)
+		},
+	}
+	return cmd
+}
+
+func newAgentRecordStopCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop <agent-id>",
+		Short: "Stop recording an agent's pane",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAgentRecord(cmd.Context(), commandOptions(cmd), args[0], false)
+		},
+	}
+	return cmd
+}
+
+// agentRecordSinkFlags holds the flags of 'swarm agent record sink'.
+type agentRecordSinkFlags struct {
+	output   string
+	width    int
+	height   int
+	maxBytes int64
+}
+
+// agentRecordSinkCmd is the process tmux pipe-pane feeds; it is not meant
+// to be run by hand.
+func newAgentRecordSinkCmd() *cobra.Command {
+	var flags agentRecordSinkFlags
+	cmd := &cobra.Command{
+		Use:    "sink",
+		Short:  "Write piped pane output to a cast file",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return recording.Record(os.Stdin, recording.RecorderOptions{
+				Path:        flags.output,
+				Width:       flags.width,
+				Height:      flags.height,
+				MaxBytes:    flags.maxBytes,
+				MaxSegments: recording.DefaultMaxSegments,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.output, "output", "", "cast file to write")
+	cmd.Flags().IntVar(&flags.width, "width", 80, "terminal width")
+	cmd.Flags().IntVar(&flags.height, "height", 24, "terminal height")
+	cmd.Flags().Int64Var(&flags.maxBytes, "max-bytes", recording.DefaultMaxBytes, "rotate the cast file after this many bytes")
+	_ = cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+// agentReplayFlags holds the flags of 'swarm agent replay'.
+type agentReplayFlags struct {
+	speed   string
+	export  string
+	maxIdle time.Duration
+}
+
+func newAgentReplayCmd() *cobra.Command {
+	var flags agentReplayFlags
+	cmd := &cobra.Command{
+		Use:   "replay <agent-id>",
+		Short: "Replay an agent's recorded pane",
+		Long: `Play back an agent's recording in the terminal using its recorded
+timing, or export the cast file for asciinema and other players.
+
+Recordings outlive their agents; pass the full agent ID for terminated agents.`,
+		Example: `  # Replay at double speed
+  swarm agent replay abc123 --speed 2x
+
+  # Skip long pauses
+  swarm agent replay abc123 --max-idle 2s
+
+  # Export for asciinema
+  swarm agent replay abc123 --export session.cast`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			speed, err := recording.ParseSpeed(flags.speed)
+			if err != nil {
+				return invalidInputError("%v", err)
+			}
+
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentRepo := db.NewAgentRepository(database)
+			agentService := agent.NewService(agentRepo, nil, nil, nil, nil, agentServiceOptions(database)...)
+
+			agentID := args[0]
+			resolved, err := findAgent(ctx, agentRepo, agentID)
+			switch {
+			case err == nil:
+				agentID = resolved.ID
+			case !errors.Is(err, ErrNotFound):
+				return err
+			}
+
+			path, err := agentService.FindRecording(agentID)
+			if errors.Is(err, agent.ErrRecordingNotFound) {
+				return notFoundError("no recording found for agent %s (start one with 'swarm agent record start')", agentID)
+			}
+			if err != nil {
+				return wrapServiceError(err, "failed to find recording")
+			}
+
+			if flags.export != "" {
+				if err := copyFile(path, flags.export); err != nil {
+					return wrapServiceError(err, "failed to export recording")
+				}
+				if opts.Structured() {
+					return opts.WriteOutput(map[string]any{
+						"agent_id": agentID,
+						"source":   path,
+						"path":     flags.export,
+					})
+				}
+				fmt.Fprintf(opts.Out, "Exported recording of agent %s to %s\n", shortID(agentID), flags.export)
+				return nil
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				return wrapServiceError(err, "failed to open recording")
+			}
+			cast, err := recording.ReadCast(file)
+			_ = file.Close()
+			if err != nil {
+				return wrapServiceError(err, "failed to read recording %s", path)
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{
+					"agent_id":         agentID,
+					"path":             path,
+					"width":            cast.Header.Width,
+					"height":           cast.Header.Height,
+					"events":           len(cast.Events),
+					"duration_seconds": cast.Duration().Seconds(),
+				})
+			}
+
+			err = recording.Play(ctx, opts.Out, cast, recording.PlayOptions{Speed: speed, MaxIdle: flags.maxIdle})
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.speed, "speed", "1x", "playback speed multiplier (e.g., 2x, 0.5x)")
+	cmd.Flags().StringVar(&flags.export, "export", "", "write the cast file to this path instead of playing it")
+	cmd.Flags().DurationVar(&flags.maxIdle, "max-idle", 0, "cap pauses between output at this duration (0 = keep recorded timing)")
+	return cmd
+}
+
+func runAgentRecord(ctx context.Context, opts Options, idOrPrefix string, start bool) error {
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	agentRepo := db.NewAgentRepository(database)
+	agentService := agent.NewService(agentRepo, nil, nil, nil, tmux.NewLocalClient(), agentServiceOptions(database)...)
+
+	resolved, err := findAgent(ctx, agentRepo, idOrPrefix)
+	if err != nil {
+		return err
+	}
+
+	var info *models.RecordingInfo
+	if start {
+		info, err = agentService.StartRecording(ctx, resolved.ID)
+		if err != nil {
+			return wrapServiceError(err, "failed to start recording for agent %s", shortID(resolved.ID))
+		}
+	} else {
+		info, err = agentService.StopRecording(ctx, resolved.ID)
+		if err != nil {
+			return wrapServiceError(err, "failed to stop recording for agent %s", shortID(resolved.ID))
+		}
+	}
+
+	if opts.Structured() {
+		return opts.WriteOutput(map[string]any{
+			"agent_id":   resolved.ID,
+			"recording":  start,
+			"path":       info.Path,
+			"started_at": info.StartedAt,
+		})
+	}
+	if start {
+		fmt.Fprintf(opts.Out, "Recording agent %s to %s\n", shortID(resolved.ID), info.Path)
+	} else {
+		fmt.Fprintf(opts.Out, "Stopped recording agent %s (%s)\n", shortID(resolved.ID), info.Path)
+	}
+	return nil
+}
+
+// recordingSinkCommand is the agent.RecordingSink used by the CLI: it pipes
+// pane output back into this binary's hidden record sink command.
+func recordingSinkCommand(castPath string, width, height int) string {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "swarm"
+	}
+	return fmt.Sprintf("%s agent record sink --output %s --width %d --height %d",
+		shell.Quote(exe), shell.Quote(castPath), width, height)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if dir := filepath.Dir(dst); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"golang.org/x/term"
+)
+
+// sendSensitiveInput sends a secret to an agent for agent send --sensitive.
+// It bypasses the queue, which would store the secret, and the secret
+// never comes from argv, so it stays out of shell history.
+func sendSensitiveInput(ctx context.Context, opts Options, flags agentSendFlags, agentID string, args []string) error {
+	if len(args) > 1 || flags.file != "" || flags.editor {
+		return invalidInputError("--sensitive reads the message from a masked prompt or stdin; do not pass it as an argument, --file, or --editor")
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	agentRepo := db.NewAgentRepository(database)
+	resolved, err := findAgent(ctx, agentRepo, agentID)
+	if err != nil {
+		return err
+	}
+
+	secret, err := readSensitiveInput()
+	if err != nil {
+		return err
+	}
+
+	nodeService := node.NewService(db.NewNodeRepository(database), node.WithPublisher(newEventPublisher(database)))
+	wsService := workspace.NewService(db.NewWorkspaceRepository(database), nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+	agentService := agent.NewService(agentRepo, db.NewQueueRepository(database), wsService, nil, tmux.NewLocalClient(), agentServiceOptions(database)...)
+
+	if err := agentService.SendMessage(ctx, resolved.ID, secret, &agent.SendMessageOptions{
+		SkipIdleCheck: true,
+		Sensitive:     true,
+	}); err != nil {
+		if errors.Is(err, agent.ErrServiceAgentNotFound) {
+			return notFoundError("agent '%s' not found", resolved.ID)
+		}
+		return wrapServiceError(err, "failed to send sensitive input")
+	}
+
+	if opts.Structured() {
+		return opts.WriteOutput(map[string]any{
+			"sent":           true,
+			"agent_id":       resolved.ID,
+			"sensitive":      true,
+			"bypassed_queue": true,
+		})
+	}
+	fmt.Fprintf(opts.Out, "✓ Sent sensitive input to agent %s (not queued or recorded)\n", shortID(resolved.ID))
+	return nil
+}
+
+// readSensitiveInput reads one line from a masked prompt, or from stdin
+// when it is not a terminal, e.g. piped from a password manager.
+func readSensitiveInput() (string, error) {
+	var secret string
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		value, err := promptSecret("Sensitive input (not echoed): ")
+		if err != nil {
+			return "", fmt.Errorf("failed to read sensitive input: %w", err)
+		}
+		secret = value
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("failed to read sensitive input: %w", err)
+		}
+		secret = strings.TrimSpace(line)
+	}
+	if secret == "" {
+		return "", invalidInputError("sensitive input is empty")
+	}
+	return secret, nil
+}
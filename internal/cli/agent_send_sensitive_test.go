@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+)
+
+func TestAgentSendSensitiveKeepsSecretOffArgvAndQueue(t *testing.T) {
+	database := useTestDatabase(t)
+	target := seedQueueAgent(t, database)
+
+	code, err := runCommand(t, newAgentSendCmd(), target.ID, "ghp_s3cr3t", "--sensitive")
+	if code != ExitCodeInvalidInput || err == nil || !strings.Contains(err.Error(), "masked prompt") {
+		t.Fatalf("expected a secret on the command line rejected, got exit %d: %v", code, err)
+	}
+
+	// Nothing to read on stdin.
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	writer.Close()
+	previous := os.Stdin
+	os.Stdin = reader
+	t.Cleanup(func() { os.Stdin = previous; reader.Close() })
+
+	code, err = runCommand(t, newAgentSendCmd(), target.ID, "--sensitive")
+	if code != ExitCodeInvalidInput || err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Fatalf("expected empty sensitive input rejected, got exit %d: %v", code, err)
+	}
+
+	items, err := db.NewQueueRepository(database).List(context.Background(), target.ID)
+	if err != nil {
+		t.Fatalf("list queue: %v", err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("expected nothing queued, got %d items", len(items))
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// agentSnapshotFlags holds the flags of 'swarm agent snapshot'.
+type agentSnapshotFlags struct {
+	history bool
+	note    string
+}
+
+func newAgentSnapshotCmd() *cobra.Command {
+	var flags agentSnapshotFlags
+	cmd := &cobra.Command{
+		Use:   "snapshot <agent-id>",
+		Short: "Save a snapshot of an agent's pane",
+		Long: `Capture an agent's pane and store it under the data directory, keyed
+by the sha256 of its content, with the agent, workspace, capture time, note,
+and the state detected from the capture.
+
+Identical captures share stored content. An agent.snapshot event records
+the hash, so the snapshot shows up in the agent's history. Browse snapshots
+with 'swarm snapshot list' and 'swarm snapshot show'.`,
+		Example: `  swarm agent snapshot abc123 --note "before the refactor"
+  swarm agent snapshot abc123 --history`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentRepo := db.NewAgentRepository(database)
+			wsService := workspace.NewService(db.NewWorkspaceRepository(database), node.NewService(db.NewNodeRepository(database)), agentRepo)
+			agentService := agent.NewService(agentRepo, nil, wsService, nil, tmux.NewLocalClient(), agentServiceOptions(database)...)
+
+			resolved, err := findAgent(ctx, agentRepo, args[0])
+			if err != nil {
+				return err
+			}
+
+			snap, err := agentService.Snapshot(ctx, resolved.ID, agent.SnapshotOptions{
+				History: flags.history,
+				Note:    flags.note,
+			})
+			if err != nil {
+				return wrapServiceError(err, "failed to snapshot agent %s", shortID(resolved.ID))
+			}
+
+			path := agentService.Snapshots().BlobPath(snap.Hash)
+			if opts.Structured() {
+				return opts.WriteOutput(snapshotView{Snapshot: *snap, Path: path})
+			}
+			fmt.Fprintf(opts.Out, "Saved snapshot %s of agent %s (%s)\n", snap.ID, shortID(resolved.ID), formatAgentState(snap.State))
+			fmt.Fprintf(opts.Out, "Path: %s\n", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.history, "history", false, "capture the full scrollback, not just the visible screen")
+	cmd.Flags().StringVar(&flags.note, "note", "", "note to store with the snapshot")
+	return cmd
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/queue"
+)
+
+// agentStatsView is an agent with its queue wait and throughput metrics,
+// emitted by 'agent list --stats'.
+type agentStatsView struct {
+	*models.Agent
+	QueueStats models.QueueWaitStats `json:"queue_stats"`
+}
+
+// loadQueueStats summarizes the saved queue metrics of every agent as of
+// now, keyed by agent ID.
+func loadQueueStats(ctx context.Context, database *db.DB) (map[string]models.QueueWaitStats, error) {
+	records, err := db.NewQueueMetricsRepository(database).List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	stats := make(map[string]models.QueueWaitStats, len(records))
+	for _, r := range records {
+		stats[r.AgentID] = queue.Summarize(r, now)
+	}
+	return stats, nil
+}
+
+// formatWaitMs renders a wait in milliseconds, or "-" without samples.
+func formatWaitMs(ms int64, samples int) string {
+	if samples == 0 {
+		return "-"
+	}
+	return formatDuration(time.Duration(ms) * time.Millisecond)
+}
+
+// formatQueueStats renders queue metrics on one line for 'agent status'.
+func formatQueueStats(stats models.QueueWaitStats) string {
+	if stats.Dispatched == 0 {
+		return "no dispatches recorded"
+	}
+	return fmt.Sprintf("p50 %s, p95 %s (%d dispatched, %d in the last hour)",
+		formatWaitMs(stats.WaitP50Ms, stats.Samples),
+		formatWaitMs(stats.WaitP95Ms, stats.Samples),
+		stats.Dispatched, stats.PerHour)
+}
@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/schema"
+)
+
+func TestAgentListAll(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	terminated := seedQueueAgent(t, database)
+	agentRepo := db.NewAgentRepository(database)
+	live := &models.Agent{WorkspaceID: terminated.WorkspaceID, Type: models.AgentTypeCodex, TmuxPane: "swarm-repo:0.2", State: models.AgentStateIdle}
+	if err := agentRepo.Create(ctx, live); err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	if err := agentRepo.Delete(ctx, terminated.ID); err != nil {
+		t.Fatalf("terminate agent: %v", err)
+	}
+
+	var agents []*models.Agent
+	if err := json.Unmarshal(runJSONCommand(t, newAgentListCmd()), &agents); err != nil {
+		t.Fatalf("decode agents: %v", err)
+	}
+	if len(agents) != 1 || agents[0].ID != live.ID {
+		t.Fatalf("expected only the live agent by default, got %+v", agents)
+	}
+
+	if err := json.Unmarshal(runJSONCommand(t, newAgentListCmd(), "--all"), &agents); err != nil {
+		t.Fatalf("decode agents: %v", err)
+	}
+	if len(agents) != 2 {
+		t.Fatalf("expected terminated agents with --all, got %+v", agents)
+	}
+
+	text := captureStdout(t, func() {
+		if code, err := runCommand(t, newAgentListCmd(), "--all"); code != 0 {
+			t.Errorf("agent list failed with exit %d: %v", code, err)
+		}
+	})
+	var marked bool
+	for _, line := range strings.Split(string(text), "\n") {
+		if strings.HasPrefix(line, shortID(terminated.ID)) {
+			marked = strings.Contains(line, "terminated")
+		}
+	}
+	if !marked {
+		t.Fatalf("expected the terminated agent marked:\n%s", text)
+	}
+}
+
+func TestAgentListFilter(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	idle := seedQueueAgent(t, database)
+	agentRepo := db.NewAgentRepository(database)
+	working := &models.Agent{WorkspaceID: idle.WorkspaceID, Type: models.AgentTypeCodex, TmuxPane: "swarm-repo:0.2", State: models.AgentStateWorking}
+	if err := agentRepo.Create(ctx, working); err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	var agents []*models.Agent
+	if err := json.Unmarshal(runJSONCommand(t, newAgentListCmd(), "--filter", "state=working or type=opencode and queue>0"), &agents); err != nil {
+		t.Fatalf("decode agents: %v", err)
+	}
+	if len(agents) != 1 || agents[0].ID != working.ID {
+		t.Fatalf("expected only the working agent, got %+v", agents)
+	}
+
+	code, err := runCommand(t, newAgentListCmd(), "--filter", "state=idle and")
+	if code != ExitCodeInvalidInput || err == nil || !strings.Contains(err.Error(), "column 15") {
+		t.Fatalf("expected an invalid filter rejected with its position, got exit %d: %v", code, err)
+	}
+}
+
+func TestAgentEnv(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	acct := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "work", CredentialRef: "env:TEST_AGENT_ENV_KEY", IsActive: true}
+	if err := db.NewAccountRepository(database).Create(ctx, acct); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	t.Setenv("TEST_AGENT_ENV_KEY", "sk-ant-current")
+	t.Setenv("REGION", "eu")
+
+	a := seedQueueAgent(t, database)
+	a.AccountID = acct.ID
+	_, a.Metadata.ResolvedEnv = agent.ResolveEnvironment(
+		agent.EnvLayer{Source: models.EnvSourceWorkspace, Vars: map[string]string{"REGION": "eu"}},
+		agent.EnvLayer{Source: models.EnvSourceAccount, Vars: map[string]string{"ANTHROPIC_API_KEY": "sk-ant-current"}},
+	)
+	agentRepo := db.NewAgentRepository(database)
+	if err := agentRepo.Update(ctx, a); err != nil {
+		t.Fatalf("update agent: %v", err)
+	}
+
+	var view agentEnvView
+	if err := json.Unmarshal(runJSONCommand(t, newAgentEnvCmd(), a.ID), &view); err != nil {
+		t.Fatalf("decode env: %v", err)
+	}
+	if len(view.Environment) != 2 || view.Environment[0].Source != models.EnvSourceAccount || !view.Environment[0].Secret {
+		t.Fatalf("unexpected environment %+v", view.Environment)
+	}
+	text := captureStdout(t, func() {
+		if code, err := runCommand(t, newAgentEnvCmd(), a.ID); code != 0 {
+			t.Errorf("agent env failed with exit %d: %v", code, err)
+		}
+	})
+	if strings.Contains(string(text), "sk-ant-current") || !strings.Contains(string(text), "workspace") {
+		t.Fatalf("unexpected env table:\n%s", text)
+	}
+
+	// Both variables match their current sources.
+	for _, name := range []string{"ANTHROPIC_API_KEY", "REGION"} {
+		var check envCheckView
+		if err := json.Unmarshal(runJSONCommand(t, newAgentEnvCmd(), a.ID, "--check", name), &check); err != nil {
+			t.Fatalf("decode check: %v", err)
+		}
+		if !check.Set || !check.Matches {
+			t.Fatalf("expected %s to match, got %+v", name, check)
+		}
+	}
+
+	// A rotated key no longer matches and fails the command.
+	t.Setenv("TEST_AGENT_ENV_KEY", "sk-ant-rotated")
+	captureStdout(t, func() {
+		if code, _ := runCommand(t, newAgentEnvCmd(), a.ID, "--check", "ANTHROPIC_API_KEY"); code != ExitCodeError {
+			t.Errorf("expected a mismatch to exit %d, got %d", ExitCodeError, code)
+		}
+		if code, _ := runCommand(t, newAgentEnvCmd(), a.ID, "--check", "UNSET_VAR"); code != ExitCodeNotFound {
+			t.Errorf("expected an unset variable to exit %d, got %d", ExitCodeNotFound, code)
+		}
+	})
+}
+
+func TestAgentListStats(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+	a := seedQueueAgent(t, database)
+
+	now := time.Now().UTC()
+	record := &models.AgentQueueMetrics{
+		AgentID:          a.ID,
+		Dispatched:       12,
+		WaitSamplesMs:    []int64{500, 1500, 2500, 90000},
+		RecentDispatches: []time.Time{now.Add(-3 * time.Hour), now.Add(-10 * time.Minute), now.Add(-time.Minute)},
+	}
+	if err := db.NewQueueMetricsRepository(database).Upsert(ctx, []*models.AgentQueueMetrics{record}); err != nil {
+		t.Fatalf("save metrics: %v", err)
+	}
+
+	out := runJSONCommand(t, newAgentListCmd(), "--stats")
+	var views []agentStatsView
+	if err := json.Unmarshal(out, &views); err != nil {
+		t.Fatalf("decode agents: %v", err)
+	}
+	doc, err := outputSchema("agent-stats")
+	if err != nil {
+		t.Fatalf("outputSchema: %v", err)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(out, &items); err != nil || len(items) != 1 {
+		t.Fatalf("expected a one-item JSON array (%v):\n%s", err, out)
+	}
+	if err := schema.Validate(doc, items[0]); err != nil {
+		t.Fatalf("output does not match schema: %v\n%s", err, items[0])
+	}
+	if len(views) != 1 || views[0].ID != a.ID {
+		t.Fatalf("unexpected agents %+v", views)
+	}
+	stats := views[0].QueueStats
+	if stats.Dispatched != 12 || stats.WaitP50Ms != 1500 || stats.WaitP95Ms != 90000 || stats.PerHour != 2 {
+		t.Fatalf("unexpected queue stats %+v", stats)
+	}
+
+	text := captureStdout(t, func() {
+		if code, err := runCommand(t, newAgentListCmd(), "--stats"); code != 0 {
+			t.Errorf("agent list failed with exit %d: %v", code, err)
+		}
+	})
+	if !strings.Contains(string(text), "WAIT P95") || !strings.Contains(string(text), "1m30s") {
+		t.Fatalf("expected queue stats columns:\n%s", text)
+	}
+
+	text = captureStdout(t, func() {
+		if code, err := runCommand(t, newAgentStatusCmd(), a.ID); code != 0 {
+			t.Errorf("agent status failed with exit %d: %v", code, err)
+		}
+	})
+	if !strings.Contains(string(text), "Queue Wait:   p50 1.5s, p95 1m30s (12 dispatched, 2 in the last hour)") {
+		t.Fatalf("expected queue wait line:\n%s", text)
+	}
+}
+
+func TestAgentCostJSONKeepsEstimateApart(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+	a := seedQueueAgent(t, database)
+
+	now := time.Now()
+	since := now.Add(-10 * time.Minute)
+	if midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local); since.Before(midnight) {
+		since = midnight
+	}
+	state, _ := json.Marshal(models.StateChangedPayload{OldState: models.AgentStateIdle, NewState: models.AgentStateWorking})
+	usage, _ := json.Marshal(models.UsageRecordedPayload{AgentID: a.ID, CostCents: 45, RecordedAt: since})
+	eventRepo := db.NewEventRepository(database)
+	for _, event := range []*models.Event{
+		{Type: models.EventTypeAgentStateChanged, Timestamp: since, EntityType: models.EntityTypeAgent, EntityID: a.ID, Payload: state},
+		{Type: models.EventTypeUsageRecorded, Timestamp: since, EntityType: models.EntityTypeAgent, EntityID: a.ID, Payload: usage},
+	} {
+		if err := eventRepo.Create(ctx, event); err != nil {
+			t.Fatalf("create event: %v", err)
+		}
+	}
+
+	var rows []struct {
+		AgentID     string          `json:"agent_id"`
+		ActualCents int64           `json:"actual_cents"`
+		Estimated   json.RawMessage `json:"estimated"`
+	}
+	if err := json.Unmarshal(runJSONCommand(t, topCmd), &rows); err != nil {
+		t.Fatalf("decode top: %v", err)
+	}
+	if len(rows) != 1 || rows[0].AgentID != a.ID || rows[0].ActualCents != 45 {
+		t.Fatalf("unexpected top rows %+v", rows)
+	}
+	var estimate models.CostEstimate
+	if err := json.Unmarshal(rows[0].Estimated, &estimate); err != nil || estimate.CostPerHourCents != 200 {
+		t.Fatalf("expected the estimate reported on its own at the opencode rate, got %s (%v)", rows[0].Estimated, err)
+	}
+
+	var status agent.AgentStateResult
+	if err := json.Unmarshal(runJSONCommand(t, newAgentStatusCmd(), a.ID), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if status.Cost == nil || status.Cost.ActualCents != 45 || status.Cost.Estimated.CostPerHourCents != 200 {
+		t.Fatalf("expected recorded and estimated cost apart in agent status, got %+v", status.Cost)
+	}
+}
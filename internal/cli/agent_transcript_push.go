@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+func newAgentTranscriptPushCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transcript-push",
+		Short: "Push agent transcripts to a webhook",
+		Long: `Have swarmd POST an agent's new transcript entries to an external URL as
+they are recorded.
+
+Entries are sent in JSON batches, signed with HMAC-SHA256 in the
+X-Swarm-Signature header when a secret is set. A batch that fails or gets a
+non-2xx response is retried with backoff; the subscription's cursor only
+moves once the receiver acknowledges a batch, so swarmd resumes where it
+left off after a restart. Delivery is at least once: receivers should
+ignore entries they have seen, using the X-Swarm-Delivery header.
+
+When the agent is terminated the subscription sends a last batch marked
+final and completes.`,
+	}
+
+	cmd.AddCommand(newAgentTranscriptPushAddCmd())
+	cmd.AddCommand(newAgentTranscriptPushListCmd())
+	cmd.AddCommand(newAgentTranscriptPushRemoveCmd())
+	return cmd
+}
+
+// agentTranscriptPushAddFlags holds the flags of 'swarm agent transcript-push add'.
+type agentTranscriptPushAddFlags struct {
+	url    string
+	types  []string
+	secret string
+}
+
+func newAgentTranscriptPushAddCmd() *cobra.Command {
+	var flags agentTranscriptPushAddFlags
+	cmd := &cobra.Command{
+		Use:   "add <agent-id>",
+		Short: "Push an agent's transcript to a URL",
+		Example: `  swarm agent transcript-push add abc123 --url https://hooks.example.com/swarm
+  swarm agent transcript-push add abc123 --url https://hooks.example.com/swarm --types output,state_change --secret "$HOOK_SECRET"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			entryTypes := make([]string, 0, len(flags.types))
+			for _, entryType := range flags.types {
+				if entryType = strings.ToLower(strings.TrimSpace(entryType)); entryType != "" {
+					entryTypes = append(entryTypes, entryType)
+				}
+			}
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentInfo, err := findAgent(ctx, db.NewAgentRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+
+			push := &models.TranscriptPush{
+				AgentID:    agentInfo.ID,
+				URL:        strings.TrimSpace(flags.url),
+				EntryTypes: entryTypes,
+				Secret:     flags.secret,
+			}
+			if err := push.Validate(); err != nil {
+				return invalidInputError("%v", err)
+			}
+			if err := db.NewTranscriptPushRepository(database).Create(ctx, push); err != nil {
+				return wrapServiceError(err, "failed to create transcript push")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(push)
+			}
+			fmt.Fprintf(opts.Out, "Pushing transcript of agent %s to %s (subscription %s)\n", shortID(agentInfo.ID), push.URL, shortID(push.ID))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.url, "url", "", "http or https URL to POST batches to")
+	cmd.Flags().StringSliceVar(&flags.types, "types", nil, "push only these entry types ("+strings.Join(models.TranscriptEntryTypes, ", ")+")")
+	cmd.Flags().StringVar(&flags.secret, "secret", "", "sign each batch with HMAC-SHA256 using this secret")
+	_ = cmd.MarkFlagRequired("url")
+	return cmd
+}
+
+func newAgentTranscriptPushListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls [agent-id]",
+		Aliases: []string{"list"},
+		Short:   "List transcript push subscriptions",
+		Long: `List transcript push subscriptions, for one agent or for every agent.
+
+LAG is the number of entries recorded but not yet acknowledged, as of the
+last time swarmd looked.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentID := ""
+			if len(args) == 1 {
+				agentInfo, err := findAgent(ctx, db.NewAgentRepository(database), args[0])
+				if err != nil {
+					return err
+				}
+				agentID = agentInfo.ID
+			}
+
+			pushes, err := db.NewTranscriptPushRepository(database).List(ctx, agentID)
+			if err != nil {
+				return wrapServiceError(err, "failed to list transcript pushes")
+			}
+
+			if opts.Structured() {
+				if pushes == nil {
+					pushes = []*models.TranscriptPush{}
+				}
+				return opts.WriteOutput(transcriptPushResults(pushes))
+			}
+			if len(pushes) == 0 {
+				fmt.Fprintln(opts.Out, "No transcript push subscriptions found")
+				return nil
+			}
+
+			rows := make([][]string, 0, len(pushes))
+			for _, push := range pushes {
+				entryTypes := "all"
+				if len(push.EntryTypes) > 0 {
+					entryTypes = strings.Join(push.EntryTypes, ",")
+				}
+				lastDelivery := "-"
+				if push.LastDeliveredAt != nil {
+					lastDelivery = formatRelativeTime(*push.LastDeliveredAt)
+				}
+				lastError := "-"
+				if push.LastError != "" {
+					lastError = fmt.Sprintf("%s (%d failures)", truncate(push.LastError, 40), push.Failures)
+				}
+				rows = append(rows, []string{
+					shortID(push.ID),
+					shortID(push.AgentID),
+					truncate(push.URL, 40),
+					entryTypes,
+					string(push.Status),
+					fmt.Sprintf("%d", push.Lag()),
+					fmt.Sprintf("%d", push.Delivered),
+					lastDelivery,
+					lastError,
+				})
+			}
+			return opts.writeTable([]string{"ID", "AGENT", "URL", "TYPES", "STATUS", "LAG", "DELIVERED", "LAST DELIVERY", "ERROR"}, rows)
+		},
+	}
+	return cmd
+}
+
+func newAgentTranscriptPushRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove <subscription-id>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a transcript push subscription",
+		Long:    "Remove a transcript push subscription. swarmd stops pushing within a second; a batch already in flight may still arrive.",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			repo := db.NewTranscriptPushRepository(database)
+			push, err := findTranscriptPush(ctx, repo, args[0])
+			if err != nil {
+				return err
+			}
+			if err := repo.Delete(ctx, push.ID); err != nil {
+				return wrapServiceError(err, "failed to remove transcript push")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{"removed": true, "subscription_id": push.ID})
+			}
+			fmt.Fprintf(opts.Out, "Removed transcript push %s\n", shortID(push.ID))
+			return nil
+		},
+	}
+	return cmd
+}
+
+// transcriptPushResult is a subscription as listed, with its lag.
+type transcriptPushResult struct {
+	*models.TranscriptPush
+	Lag int64 `json:"lag"`
+}
+
+func transcriptPushResults(pushes []*models.TranscriptPush) []transcriptPushResult {
+	results := make([]transcriptPushResult, 0, len(pushes))
+	for _, push := range pushes {
+		results = append(results, transcriptPushResult{TranscriptPush: push, Lag: push.Lag()})
+	}
+	return results
+}
+
+func findTranscriptPush(ctx context.Context, repo *db.TranscriptPushRepository, idOrPrefix string) (*models.TranscriptPush, error) {
+	idOrPrefix = strings.TrimSpace(idOrPrefix)
+	if idOrPrefix == "" {
+		return nil, invalidInputError("subscription ID required")
+	}
+
+	push, err := repo.Get(ctx, idOrPrefix)
+	if err == nil {
+		return push, nil
+	}
+	if !errors.Is(err, db.ErrTranscriptPushNotFound) {
+		return nil, wrapServiceError(err, "failed to get transcript push")
+	}
+
+	pushes, err := repo.List(ctx, "")
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list transcript pushes")
+	}
+	matches := make([]*models.TranscriptPush, 0)
+	for _, candidate := range pushes {
+		if strings.HasPrefix(candidate.ID, idOrPrefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, notFoundError("transcript push '%s' not found", idOrPrefix)
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, match := range matches {
+			ids = append(ids, shortID(match.ID))
+		}
+		return nil, invalidInputError("transcript push '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrPrefix, strings.Join(ids, ", "))
+	}
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+)
+
+func TestAgentTranscriptPushCommands(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+	repo := db.NewTranscriptPushRepository(database)
+
+	captureStdout(t, func() {
+		if code, err := runCommand(t, newAgentTranscriptPushAddCmd(), shortID(agent.ID), "--url", "https://hooks.example.com/swarm", "--types", "Output, state_change", "--secret", "s3cret"); code != 0 {
+			t.Fatalf("transcript-push add failed with exit %d: %v", code, err)
+		}
+	})
+
+	pushes, err := repo.List(ctx, agent.ID)
+	if err != nil || len(pushes) != 1 {
+		t.Fatalf("expected one subscription, got %d (%v)", len(pushes), err)
+	}
+	push := pushes[0]
+	if push.URL != "https://hooks.example.com/swarm" || push.Secret != "s3cret" || strings.Join(push.EntryTypes, ",") != "output,state_change" {
+		t.Fatalf("unexpected subscription %+v", push)
+	}
+	if err := repo.RecordFailure(ctx, push.ID, 7, "receiver returned status 502"); err != nil {
+		t.Fatalf("record failure: %v", err)
+	}
+
+	out := string(captureStdout(t, func() {
+		if code, err := runCommand(t, newAgentTranscriptPushListCmd(), shortID(agent.ID)); code != 0 {
+			t.Fatalf("transcript-push ls failed with exit %d: %v", code, err)
+		}
+	}))
+	for _, want := range []string{shortID(push.ID), "output,state_change", "active", "7", "status 502 (1 failures)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected the listing to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "s3cret") {
+		t.Errorf("expected the secret left out of the listing, got:\n%s", out)
+	}
+
+	var listed []map[string]any
+	if err := json.Unmarshal(runJSONCommand(t, newAgentTranscriptPushListCmd()), &listed); err != nil {
+		t.Fatalf("decode listing: %v", err)
+	}
+	if len(listed) != 1 || listed[0]["lag"] != float64(7) || listed[0]["secret"] != nil {
+		t.Fatalf("unexpected JSON listing %+v", listed)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "relative url", args: []string{agent.ID, "--url", "/hooks"}, want: ExitCodeInvalidInput},
+		{name: "unknown type", args: []string{agent.ID, "--url", "https://hooks.example.com", "--types", "thoughts"}, want: ExitCodeInvalidInput},
+		{name: "unknown agent", args: []string{"nope", "--url", "https://hooks.example.com"}, want: ExitCodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, err := runCommand(t, newAgentTranscriptPushAddCmd(), tt.args...); code != tt.want {
+				t.Fatalf("expected exit %d, got %d: %v", tt.want, code, err)
+			}
+		})
+	}
+
+	if code, err := runCommand(t, newAgentTranscriptPushRemoveCmd(), "nope"); code != ExitCodeNotFound {
+		t.Fatalf("expected removing an unknown subscription to be not found, got %d: %v", code, err)
+	}
+	captureStdout(t, func() {
+		if code, err := runCommand(t, newAgentTranscriptPushRemoveCmd(), shortID(push.ID)); code != 0 {
+			t.Fatalf("transcript-push rm failed with exit %d: %v", code, err)
+		}
+	})
+	if pushes, err := repo.List(ctx, ""); err != nil || len(pushes) != 0 {
+		t.Fatalf("expected the subscription removed, got %d (%v)", len(pushes), err)
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// verifyPanesTmuxClient builds the tmux client panes are resolved with;
+// tests replace it with a fake.
+var verifyPanesTmuxClient = tmux.NewLocalClient
+
+// agentVerifyPanesFlags holds the flags of 'swarm agent verify-panes'.
+type agentVerifyPanesFlags struct {
+	workspace string
+	fix       bool
+}
+
+func newAgentVerifyPanesCmd() *cobra.Command {
+	var flags agentVerifyPanesFlags
+	cmd := &cobra.Command{
+		Use:   "verify-panes",
+		Short: "Report agents whose tmux panes moved or vanished",
+		Long: `Resolve every agent's stored tmux pane against the live tmux server and
+report panes that moved to another session or no longer exist.
+
+Agents are tracked by tmux pane ID (%12), which survives windows being
+rearranged. Agents recorded before that may still hold a positional
+session:window.pane target; --fix rewrites those to the pane ID they
+currently resolve to. Moved and vanished panes are only reported: move the
+agent back with 'swarm agent move' or terminate it.`,
+		Example: `  swarm agent verify-panes
+  swarm agent verify-panes --workspace my-project --fix`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			nodeService := node.NewService(db.NewNodeRepository(database), node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			agentService := agent.NewService(agentRepo, db.NewQueueRepository(database), wsService, nil, verifyPanesTmuxClient(), agentServiceOptions(database)...)
+
+			workspaceID := ""
+			if flags.workspace != "" {
+				ws, err := findWorkspace(ctx, wsRepo, flags.workspace)
+				if err != nil {
+					return err
+				}
+				workspaceID = ws.ID
+			}
+
+			checks, err := agentService.VerifyPanes(ctx, workspaceID, flags.fix)
+			if err != nil {
+				return wrapServiceError(err, "failed to verify agent panes")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(checks)
+			}
+
+			if len(checks) == 0 {
+				fmt.Fprintln(opts.Out, "No agents to check.")
+				return nil
+			}
+
+			drifted, fixed := 0, 0
+			rows := make([][]string, 0, len(checks))
+			for _, check := range checks {
+				if check.Drift != tmux.PaneInPlace {
+					drifted++
+				}
+				if check.Fixed {
+					fixed++
+				}
+				rows = append(rows, []string{
+					shortID(check.AgentID),
+					check.Target,
+					check.ExpectedSession,
+					string(check.Drift),
+					paneCheckDetail(check),
+				})
+			}
+			if err := opts.writeTable([]string{"AGENT", "PANE", "SESSION", "STATUS", "DETAIL"}, rows); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(opts.Out, "\n%d agents checked, %d drifted", len(checks), drifted)
+			if flags.fix {
+				fmt.Fprintf(opts.Out, ", %d fixed", fixed)
+			}
+			fmt.Fprintln(opts.Out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.workspace, "workspace", "w", "", "only check agents in this workspace")
+	cmd.Flags().BoolVar(&flags.fix, "fix", false, "rewrite in-place positional targets to pane IDs")
+	return cmd
+}
+
+func paneCheckDetail(check agent.PaneCheck) string {
+	switch {
+	case check.Drift == tmux.PaneMoved:
+		return fmt.Sprintf("pane %s is now in session %s", check.PaneID, check.Session)
+	case check.Drift == tmux.PaneVanished:
+		return "pane no longer exists"
+	case check.Fixed:
+		return "stored as " + check.PaneID
+	case check.PaneID != check.Target:
+		return fmt.Sprintf("positional target, currently %s (use --fix)", check.PaneID)
+	}
+	return ""
+}
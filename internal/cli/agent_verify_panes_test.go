@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func TestAgentVerifyPanesFixesPositionalTarget(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	srv := tmuxtest.NewServer()
+	client := tmux.NewClient(srv)
+	previous := verifyPanesTmuxClient
+	verifyPanesTmuxClient = func() *tmux.Client { return client }
+	t.Cleanup(func() { verifyPanesTmuxClient = previous })
+
+	// The seeded agent holds the legacy target swarm-repo:0.1.
+	seeded := seedQueueAgent(t, database)
+	if err := client.NewSession(ctx, "swarm-repo", "/repo"); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	paneID, err := client.SplitWindow(ctx, "swarm-repo:0", false, "/repo")
+	if err != nil {
+		t.Fatalf("SplitWindow: %v", err)
+	}
+
+	var checks []agent.PaneCheck
+	out := runJSONCommand(t, newAgentVerifyPanesCmd(), "--fix")
+	if err := json.Unmarshal(out, &checks); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if len(checks) != 1 || checks[0].Drift != tmux.PaneInPlace || checks[0].PaneID != paneID || !checks[0].Fixed {
+		t.Fatalf("unexpected checks: %+v", checks)
+	}
+
+	stored, err := db.NewAgentRepository(database).Get(ctx, seeded.ID)
+	if err != nil || stored.TmuxPane != paneID || stored.TmuxSession != "swarm-repo" {
+		t.Fatalf("expected agent stored as %s in swarm-repo, got %+v (err=%v)", paneID, stored, err)
+	}
+}
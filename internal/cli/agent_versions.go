@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// agentVersionsFlags holds the flags of 'swarm agent versions'.
+type agentVersionsFlags struct {
+	workspace string
+}
+
+func newAgentVersionsCmd() *cobra.Command {
+	var flags agentVersionsFlags
+	cmd := &cobra.Command{
+		Use:   "versions",
+		Short: "Report the agent CLI versions agents are running",
+		Long: `Group running agents by type and the CLI version they were started with,
+next to the version installed on their node now.
+
+The installed CLI is probed when the report runs. Agents keep the version
+they were started with until they are restarted, so after an upgrade the
+older versions are listed until every agent has been restarted. Agents of
+one type running different versions in a workspace also raise a
+version_drift alert in 'swarm ws status'.
+
+Set agent_defaults.min_cli_versions to flag installed versions that are
+too old; with agent_defaults.block_outdated_spawns, spawns are refused
+until the CLI is upgraded.`,
+		Example: `  swarm agent versions
+  swarm agent versions --workspace my-project --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentService, _, _ := newCheckpointAgentService(database)
+
+			workspaceID := ""
+			if flags.workspace != "" {
+				ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), flags.workspace)
+				if err != nil {
+					return err
+				}
+				workspaceID = ws.ID
+			}
+
+			agents, err := agentService.ListAgents(ctx, agent.ListAgentsOptions{WorkspaceID: workspaceID})
+			if err != nil {
+				return wrapServiceError(err, "failed to list agents")
+			}
+			if _, err := agentService.RefreshCLIVersions(ctx, agents); err != nil {
+				return wrapServiceError(err, "failed to check agent CLI versions")
+			}
+
+			var minimums map[string]string
+			if cfg := GetConfig(); cfg != nil {
+				minimums = cfg.AgentDefaults.MinCLIVersions
+			}
+			reports := agent.BuildCLIVersionReport(agents, minimums)
+
+			if opts.Structured() {
+				return opts.WriteOutput(reports)
+			}
+
+			if len(reports) == 0 {
+				fmt.Fprintln(opts.Out, "No running agents.")
+				return nil
+			}
+
+			rows := make([][]string, 0, len(reports))
+			for _, report := range reports {
+				for _, group := range report.Versions {
+					rows = append(rows, []string{
+						string(report.Type),
+						cliVersionCell(opts, report, group),
+						strconv.Itoa(len(group.AgentIDs)),
+						shortIDs(group.AgentIDs),
+					})
+				}
+			}
+			if err := opts.writeTable([]string{"TYPE", "VERSION", "COUNT", "AGENT IDS"}, rows); err != nil {
+				return err
+			}
+
+			for _, report := range reports {
+				for _, line := range cliVersionNotes(report) {
+					fmt.Fprintln(opts.Out, line)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.workspace, "workspace", "w", "", "only report agents in this workspace")
+	return cmd
+}
+
+// cliVersionCell marks the installed version green and older ones yellow.
+func cliVersionCell(opts Options, report agent.CLIVersionReport, group agent.CLIVersionGroup) string {
+	switch {
+	case group.Current && report.Installed != "":
+		return opts.colorize(group.Version+" (installed)", colorGreen)
+	case group.Current:
+		return group.Version
+	case group.Version == "unknown":
+		return group.Version
+	}
+	return opts.colorize(group.Version, colorYellow)
+}
+
+// cliVersionNotes points out installed versions no agent runs yet and
+// installed versions below the configured minimum.
+func cliVersionNotes(report agent.CLIVersionReport) []string {
+	var notes []string
+	if report.Installed == "" {
+		return notes
+	}
+	running := false
+	for _, group := range report.Versions {
+		running = running || (group.Current && group.Version != "unknown")
+	}
+	if !running {
+		notes = append(notes, fmt.Sprintf("%s %s is installed; restart agents to switch to it", report.Type, report.Installed))
+	}
+	if report.Minimum != "" && adapters.CompareVersions(report.Installed, report.Minimum) < 0 {
+		notes = append(notes, fmt.Sprintf("%s %s is installed, older than the minimum %s", report.Type, report.Installed, report.Minimum))
+	}
+	return notes
+}
+
+func shortIDs(ids []string) string {
+	short := make([]string, len(ids))
+	for i, id := range ids {
+		short[i] = shortID(id)
+	}
+	return strings.Join(short, ",")
+}
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes for the outcomes of agent wait, kept clear of the error
+// category codes.
+const (
+	agentWaitExitTimeout  = 8
+	agentWaitExitTerminal = 9
+)
+
+// agentWaitFlags holds the flags of 'swarm agent wait'.
+type agentWaitFlags struct {
+	states  string
+	anyOf   []string
+	timeout time.Duration
+}
+
+func newAgentWaitCmd() *cobra.Command {
+	var flags agentWaitFlags
+	cmd := &cobra.Command{
+		Use:   "wait <agent-id>",
+		Short: "Wait until an agent reaches a target state",
+		Long: `Block until an agent reaches one of the target states.
+
+State names: idle, working, starting, paused, rate_limited, stopped,
+failed (alias: error), waiting_approval (alias: awaiting_approval).
+
+Exit codes:
+  0: Target state reached
+  1-6: Error, by category (2: invalid flags, 3: agent not found)
+  8: Timeout reached
+  9: Agent reached a terminal state (failed/stopped) that was not a target`,
+		Example: `  # Wait for an agent to become idle
+  swarm agent wait abc123 --for idle
+
+  # Wait for idle or approval, up to 5 minutes
+  swarm agent wait abc123 --for idle --any-of waiting_approval --timeout 5m
+
+  # Emit the final snapshot as JSON
+  swarm agent wait abc123 --for idle --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			targets, err := parseAgentWaitStates(flags.states, flags.anyOf)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			agentService := agent.NewService(agentRepo, queueRepo, nil, nil, nil, agentServiceOptions(database)...)
+
+			resolved, err := findAgent(ctx, agentRepo, args[0])
+			if err != nil {
+				return err
+			}
+
+			waitCtx := ctx
+			if flags.timeout > 0 {
+				var cancel context.CancelFunc
+				waitCtx, cancel = context.WithTimeout(ctx, flags.timeout)
+				defer cancel()
+			}
+
+			if !opts.JSON && !opts.JSONL {
+				fmt.Fprintf(opts.Err, "Waiting for agent %s to reach %s...\n", shortID(resolved.ID), formatAgentWaitStates(targets))
+			}
+
+			start := time.Now()
+			final, waitErr := agentService.WaitForState(waitCtx, resolved.ID, targets...)
+			elapsed := time.Since(start)
+
+			result := agentWaitResult{
+				AgentID:      resolved.ID,
+				Targets:      targets,
+				Reached:      waitErr == nil,
+				Agent:        final,
+				WaitDuration: elapsed.Round(time.Millisecond).String(),
+				WaitMs:       elapsed.Milliseconds(),
+			}
+			if final != nil {
+				result.State = final.State
+			}
+
+			exitCode := 0
+			var terminal *agent.TerminalStateError
+			switch {
+			case waitErr == nil:
+			case errors.As(waitErr, &terminal):
+				result.Reason = "terminal_state"
+				exitCode = agentWaitExitTerminal
+			case errors.Is(waitErr, context.DeadlineExceeded):
+				result.Reason = "timeout"
+				exitCode = agentWaitExitTimeout
+			default:
+				return fmt.Errorf("failed to wait for agent: %w", waitErr)
+			}
+
+			if opts.Structured() {
+				if err := opts.WriteOutput(result); err != nil {
+					return err
+				}
+			} else {
+				switch result.Reason {
+				case "":
+					fmt.Fprintf(opts.Out, "Agent %s is %s (waited %s)\n", shortID(resolved.ID), formatAgentState(result.State), result.WaitDuration)
+				case "timeout":
+					fmt.Fprintf(opts.Err, "Timeout after %s; agent %s is %s\n", result.WaitDuration, shortID(resolved.ID), formatAgentState(result.State))
+				case "terminal_state":
+					fmt.Fprintf(opts.Err, "Agent %s reached terminal state %s\n", shortID(resolved.ID), formatAgentState(result.State))
+				}
+			}
+
+			if exitCode != 0 {
+				return &ExitError{Code: exitCode, Err: waitErr, Printed: true}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.states, "for", "", "target state (idle, stopped, failed, waiting_approval, ...)")
+	cmd.Flags().StringSliceVar(&flags.anyOf, "any-of", nil, "additional target states (comma-separated)")
+	cmd.Flags().DurationVarP(&flags.timeout, "timeout", "t", 10*time.Minute, "maximum wait time (0 = no limit)")
+	return cmd
+}
+
+// agentWaitResult is the JSON shape emitted by agent wait.
+type agentWaitResult struct {
+	AgentID      string              `json:"agent_id"`
+	Targets      []models.AgentState `json:"targets"`
+	Reached      bool                `json:"reached"`
+	State        models.AgentState   `json:"state,omitempty"`
+	Reason       string              `json:"reason,omitempty"`
+	WaitDuration string              `json:"wait_duration"`
+	WaitMs       int64               `json:"wait_ms"`
+	Agent        *models.Agent       `json:"agent,omitempty"`
+}
+
+// parseAgentWaitStates combines --for and --any-of into target states.
+func parseAgentWaitStates(primary string, anyOf []string) ([]models.AgentState, error) {
+	names := make([]string, 0, len(anyOf)+1)
+	if strings.TrimSpace(primary) != "" {
+		names = append(names, primary)
+	}
+	names = append(names, anyOf...)
+	if len(names) == 0 {
+		return nil, errors.New("--for or --any-of is required")
+	}
+
+	seen := make(map[models.AgentState]bool, len(names))
+	states := make([]models.AgentState, 0, len(names))
+	for _, name := range names {
+		state, err := parseAgentWaitState(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[state] {
+			continue
+		}
+		seen[state] = true
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func parseAgentWaitState(name string) (models.AgentState, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	normalized = strings.ReplaceAll(normalized, "-", "_")
+	switch normalized {
+	case "failed", "error":
+		return models.AgentStateError, nil
+	case "waiting_approval", "awaiting_approval":
+		return models.AgentStateAwaitingApproval, nil
+	case "idle", "working", "starting", "paused", "rate_limited", "stopped":
+		return models.AgentState(normalized), nil
+	default:
+		return "", fmt.Errorf("invalid state %q; valid states: idle, working, starting, paused, rate_limited, stopped, failed, waiting_approval", name)
+	}
+}
+
+func formatAgentWaitStates(states []models.AgentState) string {
+	parts := make([]string, len(states))
+	for i, state := range states {
+		parts[i] = string(state)
+	}
+	return strings.Join(parts, " or ")
+}
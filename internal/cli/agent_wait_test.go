@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestParseAgentWaitStates(t *testing.T) {
+	states, err := parseAgentWaitStates("failed", []string{"waiting-approval", "idle", "error"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []models.AgentState{models.AgentStateError, models.AgentStateAwaitingApproval, models.AgentStateIdle}
+	if len(states) != len(want) {
+		t.Fatalf("expected %v, got %v", want, states)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, states)
+		}
+	}
+
+	if _, err := parseAgentWaitStates("", nil); err == nil {
+		t.Fatal("expected error when no state is given")
+	}
+	if _, err := parseAgentWaitStates("sleeping", nil); err == nil {
+		t.Fatal("expected error for unknown state")
+	}
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/spf13/cobra"
+)
+
+func newAgentWakeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wake <agent-id>",
+		Short: "Wake a hibernated agent",
+		Long: `Wake an agent hibernated for being idle past its workspace's
+hibernate-after threshold (see 'swarm ws set --hibernate-after').
+
+The session files checkpointed when the agent hibernated are restored, a
+new pane is spawned in its workspace, and the agent CLI resumes the
+session. The command returns once the agent is ready. The agent keeps its
+ID and queue.
+
+Agents also wake on their own when a message is sent to them or, with the
+scheduler running, when items are queued for them.`,
+		Example: `  swarm agent wake abc123`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			agentService, agentRepo, _ := newCheckpointAgentService(database)
+			resolved, err := findAgent(ctx, agentRepo, args[0])
+			if err != nil {
+				return err
+			}
+
+			woken, err := agentService.WakeAgent(ctx, resolved.ID, agent.WakeReasonManual)
+			if err != nil {
+				return wrapServiceError(err, "failed to wake agent %s", shortID(resolved.ID))
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(woken)
+			}
+			fmt.Fprintf(opts.Out, "Woke agent %s (pane %s)\n", shortID(woken.ID), woken.TmuxPane)
+			return nil
+		},
+	}
+	return cmd
+}
@@ -2,11 +2,9 @@
 package cli
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/opencode-ai/swarm/internal/agent"
 	"github.com/opencode-ai/swarm/internal/db"
@@ -25,13 +23,14 @@ var (
 	upProfile string
 	upNoTmux  bool
 	upNode    string
+	upModel   string
 )
 
 func init() {
 	// Register fast aliases at root level
 	rootCmd.AddCommand(upCmd)
-	rootCmd.AddCommand(lsCmd)
-	rootCmd.AddCommand(psCmd)
+	rootCmd.AddCommand(newLsCmd())
+	rootCmd.AddCommand(newPsCmd())
 
 	// up flags
 	upCmd.Flags().IntVarP(&upAgents, "agents", "n", 1, "number of agents to spawn")
@@ -40,13 +39,7 @@ func init() {
 	upCmd.Flags().StringVar(&upProfile, "profile", "", "account profile to use")
 	upCmd.Flags().BoolVar(&upNoTmux, "no-tmux", false, "don't create tmux session")
 	upCmd.Flags().StringVar(&upNode, "node", "", "node name or ID (default: local)")
-
-	// ls inherits from ws list
-	lsCmd.Flags().StringVar(&wsListNode, "node", "", "filter by node")
-
-	// ps inherits from agent list
-	psCmd.Flags().StringVar(&agentListState, "state", "", "filter by state")
-	psCmd.Flags().StringVarP(&agentListWorkspace, "workspace", "w", "", "filter by workspace")
+	upCmd.Flags().StringVar(&upModel, "model", "", "model to pass to the agent CLI (overrides config default)")
 }
 
 var upCmd = &cobra.Command{
@@ -71,7 +64,7 @@ spawns one or more agents. This is the fastest way to get started.`,
   # Use a specific profile
   swarm up --profile work`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -86,7 +79,7 @@ spawns one or more agents. This is the fastest way to get started.`,
 		agentRepo := db.NewAgentRepository(database)
 		queueRepo := db.NewQueueRepository(database)
 		eventRepo := db.NewEventRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithEventRepository(eventRepo), workspace.WithPublisher(newEventPublisher(database)))
+		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithEventRepository(eventRepo), workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
 		tmuxClient := tmux.NewLocalClient()
 		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
 
@@ -99,10 +92,7 @@ spawns one or more agents. This is the fastest way to get started.`,
 			}
 			repoPath = cwd
 		}
-		repoPath, err = filepath.Abs(repoPath)
-		if err != nil {
-			return fmt.Errorf("failed to resolve path: %w", err)
-		}
+		repoPath = workspace.CanonicalRepoPath(repoPath)
 
 		// Resolve node
 		nodeID := ""
@@ -127,10 +117,13 @@ spawns one or more agents. This is the fastest way to get started.`,
 		} else {
 			// Create workspace
 			step := startProgress("Creating workspace")
+			// Adopt a workspace created for the same path by a concurrent
+			// `swarm up` rather than failing the second caller.
 			input := workspace.CreateWorkspaceInput{
 				NodeID:            nodeID,
 				RepoPath:          repoPath,
 				CreateTmuxSession: !upNoTmux,
+				AdoptExisting:     true,
 			}
 			ws, err = wsService.CreateWorkspace(ctx, input)
 			if err != nil {
@@ -147,14 +140,18 @@ spawns one or more agents. This is the fastest way to get started.`,
 		}
 
 		// Spawn agents
+		agentType := models.AgentType(upType)
+		model := resolveSpawnModel(ws, agentType, upModel)
 		spawnedAgents := make([]*models.Agent, 0, upAgents)
 		for i := 0; i < upAgents; i++ {
 			step := startProgress(fmt.Sprintf("Spawning agent %d/%d", i+1, upAgents))
 
-			agentType := models.AgentType(upType)
 			opts := agent.SpawnOptions{
 				WorkspaceID: ws.ID,
 				Type:        agentType,
+				Model:       model,
+
+				WorkspaceEnvironment: workspaceSpawnEnv(ws),
 			}
 
 			a, err := agentService.SpawnAgent(ctx, opts)
@@ -208,23 +205,40 @@ spawns one or more agents. This is the fastest way to get started.`,
 	},
 }
 
-var lsCmd = &cobra.Command{
-	Use:     "ls",
-	Short:   "List workspaces (alias: ws list)",
-	Aliases: []string{"workspaces"},
-	Long:    `List all workspaces. This is an alias for 'swarm ws list'.`,
-	Example: `  swarm ls
+func newLsCmd() *cobra.Command {
+	// ls inherits from ws list
+	var flags wsListFlags
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Short:   "List workspaces (alias: ws list)",
+		Aliases: []string{"workspaces"},
+		Long:    `List all workspaces. This is an alias for 'swarm ws list'.`,
+		Example: `  swarm ls
   swarm ls --node prod`,
-	RunE: wsListCmd.RunE,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWsList(cmd, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.node, "node", "", "filter by node")
+	return cmd
 }
 
-var psCmd = &cobra.Command{
-	Use:     "ps",
-	Short:   "List agents (alias: agent list)",
-	Aliases: []string{"agents"},
-	Long:    `List all agents. This is an alias for 'swarm agent list'.`,
-	Example: `  swarm ps
+func newPsCmd() *cobra.Command {
+	// ps inherits from agent list
+	var flags agentListFlags
+	cmd := &cobra.Command{
+		Use:     "ps",
+		Short:   "List agents (alias: agent list)",
+		Aliases: []string{"agents"},
+		Long:    `List all agents. This is an alias for 'swarm agent list'.`,
+		Example: `  swarm ps
   swarm ps --state idle
   swarm ps -w my-project`,
-	RunE: agentListCmd.RunE,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAgentList(cmd, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.state, "state", "", "filter by state")
+	cmd.Flags().StringVarP(&flags.workspace, "workspace", "w", "", "filter by workspace")
+	return cmd
 }
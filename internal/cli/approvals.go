@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/approval"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var (
+	approvalsListStatus string
+	approvalsListAgent  string
+	approvalsApproveIn  string
+	approvalsDenyIn     string
+	approvalsNoEnter    bool
+)
+
+func init() {
+	rootCmd.AddCommand(approvalsCmd)
+	approvalsCmd.AddCommand(approvalsListCmd)
+	approvalsCmd.AddCommand(approvalsApproveCmd)
+	approvalsCmd.AddCommand(approvalsDenyCmd)
+
+	approvalsListCmd.Flags().StringVar(&approvalsListStatus, "status", "pending", "filter by status (pending, approved, denied, expired, all)")
+	approvalsListCmd.Flags().StringVar(&approvalsListAgent, "agent", "", "filter by agent")
+
+	for _, cmd := range []*cobra.Command{approvalsApproveCmd, approvalsDenyCmd} {
+		cmd.Flags().BoolVar(&approvalsNoEnter, "no-enter", false, "don't press Enter after the input")
+	}
+	approvalsApproveCmd.Flags().StringVar(&approvalsApproveIn, "input", approval.DefaultApproveInput, "keys to send to the agent pane")
+	approvalsDenyCmd.Flags().StringVar(&approvalsDenyIn, "input", approval.DefaultDenyInput, "keys to send to the agent pane")
+}
+
+var approvalsCmd = &cobra.Command{
+	Use:     "approvals",
+	Aliases: []string{"approval"},
+	Short:   "Review and resolve agent approval prompts",
+	Long: `Review and resolve approval prompts detected in agent panes.
+
+When an agent's state becomes waiting_approval, the prompt is recorded as a
+pending approval. Approving or denying sends the chosen keys to the agent's
+pane and records who resolved it.
+
+Approval rules with a pattern (regex on the captured prompt) resolve matching
+prompts automatically when the workspace uses the custom approval policy.
+Set agent_defaults.disable_auto_approval to turn all rules off.`,
+}
+
+var approvalsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List approval requests",
+	Example: `  swarm approvals list
+  swarm approvals list --status all --agent abc123`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, err := parseApprovalStatus(approvalsListStatus)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		agentRepo := db.NewAgentRepository(database)
+		service := approval.NewService(db.NewApprovalRepository(database), agentRepo, nil)
+
+		approvals, err := service.List(ctx, status)
+		if err != nil {
+			return fmt.Errorf("failed to list approvals: %w", err)
+		}
+
+		if approvalsListAgent != "" {
+			agentRecord, err := findAgent(ctx, agentRepo, approvalsListAgent)
+			if err != nil {
+				return err
+			}
+			filtered := approvals[:0]
+			for _, a := range approvals {
+				if a.AgentID == agentRecord.ID {
+					filtered = append(filtered, a)
+				}
+			}
+			approvals = filtered
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, approvals)
+		}
+
+		if len(approvals) == 0 {
+			fmt.Println("No approvals found")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(approvals))
+		for _, a := range approvals {
+			resolvedBy := a.ResolvedBy
+			if resolvedBy == "" {
+				resolvedBy = "-"
+			}
+			rows = append(rows, []string{
+				shortID(a.ID),
+				shortID(a.AgentID),
+				string(a.Status),
+				formatRelativeTime(a.CreatedAt),
+				resolvedBy,
+				truncate(approvalPromptLine(a), 60),
+			})
+		}
+		return writeTable(os.Stdout, []string{"ID", "AGENT", "STATUS", "DETECTED", "RESOLVED BY", "PROMPT"}, rows)
+	},
+}
+
+var approvalsApproveCmd = &cobra.Command{
+	Use:   "approve <approval-id>",
+	Short: "Approve a pending request and send input to the agent",
+	Example: `  swarm approvals approve 1a2b3c
+  swarm approvals approve 1a2b3c --input 1 --no-enter`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApprovalResolve(cmd.Context(), args[0], models.ApprovalStatusApproved, approvalsApproveIn)
+	},
+}
+
+var approvalsDenyCmd = &cobra.Command{
+	Use:   "deny <approval-id>",
+	Short: "Deny a pending request and send input to the agent",
+	Example: `  swarm approvals deny 1a2b3c
+  swarm approvals deny 1a2b3c --input "no, use the staging database"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApprovalResolve(cmd.Context(), args[0], models.ApprovalStatusDenied, approvalsDenyIn)
+	},
+}
+
+func runApprovalResolve(ctx context.Context, idOrPrefix string, status models.ApprovalStatus, input string) error {
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	service := approval.NewService(
+		db.NewApprovalRepository(database),
+		db.NewAgentRepository(database),
+		tmux.NewLocalClient(),
+		approval.WithPublisher(newEventPublisher(database)),
+	)
+
+	target, err := findApproval(ctx, service, idOrPrefix)
+	if err != nil {
+		return err
+	}
+
+	opts := approval.ResolveOptions{
+		Input:   input,
+		NoEnter: approvalsNoEnter,
+	}
+
+	var resolved *models.Approval
+	if status == models.ApprovalStatusDenied {
+		resolved, err = service.Deny(ctx, target.ID, opts)
+	} else {
+		resolved, err = service.Approve(ctx, target.ID, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if IsJSONOutput() || IsJSONLOutput() {
+		return WriteOutput(os.Stdout, resolved)
+	}
+
+	fmt.Printf("Approval %s %s (sent %q to agent %s)\n", shortID(resolved.ID), resolved.Status, opts.Input, shortID(resolved.AgentID))
+	return nil
+}
+
+// findApproval resolves an approval by full ID or unique prefix.
+func findApproval(ctx context.Context, service *approval.Service, idOrPrefix string) (*models.Approval, error) {
+	idOrPrefix = strings.TrimSpace(idOrPrefix)
+	if idOrPrefix == "" {
+		return nil, errors.New("approval ID required")
+	}
+
+	found, err := service.Get(ctx, idOrPrefix)
+	if err == nil {
+		return found, nil
+	}
+	if !errors.Is(err, approval.ErrApprovalNotFound) {
+		return nil, fmt.Errorf("failed to get approval: %w", err)
+	}
+
+	approvals, err := service.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approvals: %w", err)
+	}
+
+	var matches []*models.Approval
+	for _, a := range approvals {
+		if strings.HasPrefix(a.ID, idOrPrefix) {
+			matches = append(matches, a)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, fmt.Errorf("approval '%s' not found", idOrPrefix)
+	default:
+		return nil, fmt.Errorf("approval '%s' is ambiguous (%d matches); use a longer prefix or full ID", idOrPrefix, len(matches))
+	}
+}
+
+func parseApprovalStatus(value string) (models.ApprovalStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "all":
+		return "", nil
+	case string(models.ApprovalStatusPending):
+		return models.ApprovalStatusPending, nil
+	case string(models.ApprovalStatusApproved):
+		return models.ApprovalStatusApproved, nil
+	case string(models.ApprovalStatusDenied):
+		return models.ApprovalStatusDenied, nil
+	case string(models.ApprovalStatusExpired):
+		return models.ApprovalStatusExpired, nil
+	default:
+		return "", fmt.Errorf("invalid status %q; valid: pending, approved, denied, expired, all", value)
+	}
+}
+
+// approvalPromptLine returns the last line of a prompt approval's excerpt,
+// which is usually the question itself.
+func approvalPromptLine(a *models.Approval) string {
+	if a.RequestType != models.ApprovalRequestTypePrompt {
+		return string(a.RequestType)
+	}
+	var details models.PromptApprovalDetails
+	if err := json.Unmarshal(a.RequestDetails, &details); err != nil {
+		return "-"
+	}
+	excerpt := strings.TrimSpace(details.PromptExcerpt)
+	if excerpt == "" {
+		return "-"
+	}
+	if idx := strings.LastIndex(excerpt, "\n"); idx >= 0 {
+		excerpt = excerpt[idx+1:]
+	}
+	return strings.TrimSpace(excerpt)
+}
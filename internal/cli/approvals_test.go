@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestParseApprovalStatus(t *testing.T) {
+	tests := map[string]models.ApprovalStatus{
+		"":         "",
+		"all":      "",
+		"pending":  models.ApprovalStatusPending,
+		"Approved": models.ApprovalStatusApproved,
+		"denied":   models.ApprovalStatusDenied,
+		"expired":  models.ApprovalStatusExpired,
+	}
+	for input, want := range tests {
+		got, err := parseApprovalStatus(input)
+		if err != nil {
+			t.Fatalf("parseApprovalStatus(%q) returned error: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("parseApprovalStatus(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	if _, err := parseApprovalStatus("maybe"); err == nil {
+		t.Fatal("expected error for invalid status")
+	}
+}
+
+func TestApprovalPromptLine(t *testing.T) {
+	details, err := json.Marshal(models.PromptApprovalDetails{
+		PromptExcerpt: "Claude wants to run: go test\nDo you want to run this command? [y/n]",
+	})
+	if err != nil {
+		t.Fatalf("marshal details: %v", err)
+	}
+
+	prompt := &models.Approval{RequestType: models.ApprovalRequestTypePrompt, RequestDetails: details}
+	if got := approvalPromptLine(prompt); got != "Do you want to run this command? [y/n]" {
+		t.Fatalf("unexpected prompt line %q", got)
+	}
+
+	other := &models.Approval{RequestType: "file_write", RequestDetails: json.RawMessage(`{}`)}
+	if got := approvalPromptLine(other); got != "file_write" {
+		t.Fatalf("expected request type for non-prompt approvals, got %q", got)
+	}
+}
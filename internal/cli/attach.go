@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"syscall"
+	"time"
 
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/swarmd"
 	"github.com/opencode-ai/swarm/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -17,6 +20,10 @@ var (
 	attachSelect bool
 )
 
+// attachWaitDelay bounds how long a cancelled attach waits for the tmux
+// client to exit before it is killed.
+const attachWaitDelay = 5 * time.Second
+
 func init() {
 	rootCmd.AddCommand(attachCmd)
 
@@ -48,7 +55,7 @@ If no target is specified:
   swarm attach --select`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -108,6 +115,9 @@ func attachToAgent(ctx context.Context, wsService *workspace.Service, wsRepo *db
 	if paneID == "" {
 		return fmt.Errorf("agent has no tmux pane")
 	}
+	if swarmd.IsRunnerPane(paneID) {
+		return invalidInputError("agent runs under its node's runner execution backend, which has no tmux pane to attach to")
+	}
 
 	// Get the workspace to find the session
 	ws, err := wsRepo.Get(ctx, workspaceID)
@@ -132,13 +142,7 @@ func attachToAgent(ctx context.Context, wsService *workspace.Service, wsRepo *db
 		})
 	}
 
-	// Execute tmux attach
-	tmuxCmd := exec.CommandContext(ctx, "sh", "-c", attachCmd)
-	tmuxCmd.Stdin = os.Stdin
-	tmuxCmd.Stdout = os.Stdout
-	tmuxCmd.Stderr = os.Stderr
-
-	return tmuxCmd.Run()
+	return runAttachCommand(ctx, attachCmd)
 }
 
 // attachToWorkspace attaches to a workspace's tmux session.
@@ -155,13 +159,7 @@ func attachToWorkspace(ctx context.Context, wsService *workspace.Service, worksp
 		})
 	}
 
-	// Execute tmux attach
-	tmuxCmd := exec.CommandContext(ctx, "sh", "-c", attachCmd)
-	tmuxCmd.Stdin = os.Stdin
-	tmuxCmd.Stdout = os.Stdout
-	tmuxCmd.Stderr = os.Stderr
-
-	return tmuxCmd.Run()
+	return runAttachCommand(ctx, attachCmd)
 }
 
 // interactiveAttach prompts for selection when no target is specified.
@@ -206,3 +204,19 @@ func interactiveAttach(ctx context.Context, wsRepo *db.WorkspaceRepository, agen
 
 	return nil
 }
+
+// runAttachCommand runs a tmux attach command on the terminal. When ctx is
+// cancelled the tmux client is sent SIGTERM, which detaches it and leaves
+// the session running.
+func runAttachCommand(ctx context.Context, attachCmd string) error {
+	tmuxCmd := exec.CommandContext(ctx, "sh", "-c", attachCmd)
+	tmuxCmd.Stdin = os.Stdin
+	tmuxCmd.Stdout = os.Stdout
+	tmuxCmd.Stderr = os.Stderr
+	tmuxCmd.Cancel = func() error {
+		return tmuxCmd.Process.Signal(syscall.SIGTERM)
+	}
+	tmuxCmd.WaitDelay = attachWaitDelay
+
+	return tmuxCmd.Run()
+}
@@ -2,7 +2,6 @@
 package cli
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -20,6 +19,7 @@ func init() {
 	auditCmd.Flags().StringVar(&auditActionTypes, "action", "", "alias for --type")
 	auditCmd.Flags().StringVar(&auditEntityType, "entity-type", "", "filter by entity type (node, workspace, agent, queue, account, system)")
 	auditCmd.Flags().StringVar(&auditEntityID, "entity-id", "", "filter by entity ID")
+	auditCmd.Flags().StringVar(&auditTraceID, "trace", "", "filter by trace ID")
 	auditCmd.Flags().StringVar(&auditUntil, "until", "", "filter events before a time (same format as --since)")
 	auditCmd.Flags().StringVar(&auditCursor, "cursor", "", "start after this event ID")
 	auditCmd.Flags().IntVar(&auditLimit, "limit", 100, "max number of events to return")
@@ -30,6 +30,7 @@ var (
 	auditActionTypes string
 	auditEntityType  string
 	auditEntityID    string
+	auditTraceID     string
 	auditUntil       string
 	auditCursor      string
 	auditLimit       int
@@ -39,13 +40,15 @@ var auditCmd = &cobra.Command{
 	Use:   "audit",
 	Short: "View the Swarm audit log",
 	Long: `View the audit log with filters for time range, entity, and action.
+The record of who made each mutating operation is in 'swarm audit list'.
 
 Examples:
   swarm audit --since 1h
   swarm audit --type agent.state_changed --entity-type agent
-  swarm audit --action message.dispatched --limit 200`,
+  swarm audit --action message.dispatched --limit 200
+  swarm audit --trace 3f9a2c1e8b7d4a60`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -86,10 +89,11 @@ Examples:
 		}
 
 		query := db.EventQuery{
-			Cursor: auditCursor,
-			Since:  since,
-			Until:  until,
-			Limit:  auditLimit,
+			Cursor:  auditCursor,
+			Since:   since,
+			Until:   until,
+			Limit:   auditLimit,
+			TraceID: strings.TrimSpace(auditTraceID),
 		}
 
 		if strings.TrimSpace(auditEntityType) != "" {
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditListActor      string
+	auditListOperation  string
+	auditListEntityType string
+	auditListEntityID   string
+	auditListUntil      string
+	auditListLimit      int
+)
+
+func init() {
+	auditCmd.AddCommand(auditListCmd)
+
+	auditListCmd.Flags().StringVar(&auditListActor, "actor", "", "filter by actor (OS user, or token:<fingerprint> for daemon callers)")
+	auditListCmd.Flags().StringVar(&auditListOperation, "op", "", "filter by operation (e.g. kill_agent, send_message)")
+	auditListCmd.Flags().StringVar(&auditListEntityType, "entity-type", "", "filter by entity type (workspace, agent, queue, account, system)")
+	auditListCmd.Flags().StringVar(&auditListEntityID, "entity-id", "", "filter by entity ID")
+	auditListCmd.Flags().StringVar(&auditListUntil, "until", "", "filter records before a time (same format as --since)")
+	auditListCmd.Flags().IntVar(&auditListLimit, "limit", 100, "max number of records to return")
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audited mutating operations",
+	Long: `List the records of the mutating operations made through the CLI and the
+daemon, newest first. Each names who acted, on what, with which
+parameters (secrets redacted), and whether it succeeded.`,
+	Example: `  swarm audit list --since 7d
+  swarm audit list --actor alice --op kill_agent
+  swarm audit list --entity-type account --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		since, err := GetSinceTime()
+		if err != nil {
+			return invalidInputError("invalid --since value: %v", err)
+		}
+		until, err := ParseSince(auditListUntil)
+		if err != nil {
+			return invalidInputError("invalid --until value: %v", err)
+		}
+		if since != nil && until != nil && since.After(*until) {
+			return invalidInputError("--since must be before --until")
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		limit := auditListLimit
+		if limit <= 0 {
+			limit = 100
+		}
+		records, err := db.NewAuditRepository(database).Query(ctx, db.AuditQuery{
+			Actor:      strings.TrimSpace(auditListActor),
+			Operation:  strings.TrimSpace(auditListOperation),
+			EntityType: models.EntityType(strings.TrimSpace(auditListEntityType)),
+			EntityID:   strings.TrimSpace(auditListEntityID),
+			Since:      since,
+			Until:      until,
+			Limit:      limit,
+		})
+		if err != nil {
+			return wrapServiceError(err, "failed to query audit log")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, records)
+		}
+		if len(records) == 0 {
+			fmt.Fprintln(os.Stdout, "No audit records matched the current filters.")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(records))
+		for _, record := range records {
+			entity := string(record.EntityType)
+			if record.EntityID != "" {
+				entity = strings.TrimSpace(fmt.Sprintf("%s %s", entity, shortID(record.EntityID)))
+			}
+			rows = append(rows, []string{
+				record.Timestamp.UTC().Format("2006-01-02 15:04:05"),
+				record.Actor,
+				record.Operation,
+				entity,
+				string(record.Result),
+				auditRecordDetails(record),
+			})
+		}
+		return writeTable(os.Stdout, []string{"TIME", "ACTOR", "OPERATION", "ENTITY", "RESULT", "DETAILS"}, rows)
+	},
+}
+
+// auditRecordDetails summarizes a record's parameters on one line, or its
+// error when the operation failed.
+func auditRecordDetails(record *models.AuditRecord) string {
+	if record.Result == models.AuditResultFailure && record.Error != "" {
+		return truncate(record.Error, 60)
+	}
+	names := make([]string, 0, len(record.Parameters))
+	for name := range record.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, record.Parameters[name]))
+	}
+	return truncate(strings.Join(parts, " "), 60)
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/trace"
+)
+
+func TestAuditTraceFilter(t *testing.T) {
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+
+	// The command's trace follows the event it publishes into the log.
+	drain := newAgentDrainCmd()
+	drain.SetContext(trace.WithID(context.Background(), "cli-trace-1"))
+	runJSONCommand(t, drain, agent.ID, "--wait", "--timeout", "5s")
+
+	other := &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: agent.ID}
+	if err := db.NewEventRepository(database).Create(trace.WithID(context.Background(), "other-trace"), other); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+
+	var events []*models.Event
+	out := runJSONCommand(t, auditCmd, "--trace", "cli-trace-1")
+	if err := json.Unmarshal(out, &events); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if len(events) != 1 || events[0].Type != models.EventTypeAgentDrained || events[0].TraceID != "cli-trace-1" {
+		t.Fatalf("expected only the drain event of the traced command, got %s", out)
+	}
+}
+
+func TestAuditListFilters(t *testing.T) {
+	database := useTestDatabase(t)
+	seeded := seedQueueAgent(t, database)
+
+	runJSONCommand(t, newAgentPauseCmd(), seeded.ID, "--duration", "1m")
+	runJSONCommand(t, newAgentResumeCmd(), seeded.ID)
+	other := &models.AuditRecord{Actor: "alice", Operation: "kill_agent", EntityType: models.EntityTypeAgent, EntityID: "other", Result: models.AuditResultSuccess}
+	if err := db.NewAuditRepository(database).Create(context.Background(), other); err != nil {
+		t.Fatalf("create audit record: %v", err)
+	}
+
+	var records []*models.AuditRecord
+	out := runJSONCommand(t, auditListCmd, "--entity-id", seeded.ID)
+	if err := json.Unmarshal(out, &records); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if len(records) != 2 || records[0].Operation != "resume_agent" || records[1].Operation != "pause_agent" {
+		t.Fatalf("expected the resume and pause of the agent, newest first, got %s", out)
+	}
+	if records[1].Parameters["duration"] != "1m0s" || records[1].Actor == "" {
+		t.Fatalf("expected the pause recorded with its actor and duration, got %+v", records[1])
+	}
+
+	records = nil
+	out = runJSONCommand(t, auditListCmd, "--actor", "alice", "--op", "kill_agent")
+	if err := json.Unmarshal(out, &records); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if len(records) != 1 || records[0].EntityID != "other" {
+		t.Fatalf("expected only alice's kill, got %s", out)
+	}
+}
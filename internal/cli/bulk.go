@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+)
+
+// bulkStatus is the outcome of a bulk operation for one item.
+type bulkStatus string
+
+const (
+	bulkOK      bulkStatus = "ok"
+	bulkFailed  bulkStatus = "failed"
+	bulkSkipped bulkStatus = "skipped"
+)
+
+// bulkItem is what a bulk operation did to one item.
+type bulkItem struct {
+	ID     string     `json:"id"`
+	Name   string     `json:"name,omitempty"`
+	Action string     `json:"action"`
+	Status bulkStatus `json:"status"`
+	Error  string     `json:"error,omitempty"`
+
+	// err is the failure, kept for its category.
+	err error
+}
+
+// bulkResult collects the outcome of a command acting on several items.
+// Commands carry on past an item that fails and report every item at the
+// end, so a failure never hides what else succeeded.
+type bulkResult struct {
+	Items     []bulkItem `json:"items"`
+	Succeeded int        `json:"succeeded"`
+	Failed    int        `json:"failed"`
+	Skipped   int        `json:"skipped"`
+}
+
+// record adds an item that succeeded when err is nil and failed otherwise.
+func (r *bulkResult) record(item bulkItem, err error) {
+	if err != nil {
+		item.Status, item.Error, item.err = bulkFailed, err.Error(), err
+		r.Failed++
+	} else {
+		item.Status = bulkOK
+		r.Succeeded++
+	}
+	r.Items = append(r.Items, item)
+}
+
+// skip adds an item left alone, with why.
+func (r *bulkResult) skip(item bulkItem, reason string) {
+	item.Status, item.Error = bulkSkipped, reason
+	r.Skipped++
+	r.Items = append(r.Items, item)
+}
+
+// ok reports whether every item succeeded.
+func (r *bulkResult) ok() bool {
+	return r.Failed == 0 && r.Skipped == 0
+}
+
+// writeTable writes one row per item and a summary line.
+func (r *bulkResult) writeTable(opts Options) error {
+	rows := make([][]string, 0, len(r.Items))
+	for _, item := range r.Items {
+		name := item.Name
+		if name == "" {
+			name = shortID(item.ID)
+		}
+		errText := item.Error
+		if errText == "" {
+			errText = "-"
+		}
+		rows = append(rows, []string{name, item.Action, string(item.Status), errText})
+	}
+	if err := opts.writeTable([]string{"ID", "ACTION", "STATUS", "ERROR"}, rows); err != nil {
+		return err
+	}
+	out := opts.stdout()
+	fmt.Fprintf(out, "\n%d of %d succeeded", r.Succeeded, len(r.Items))
+	if r.Failed > 0 {
+		fmt.Fprintf(out, ", %d failed", r.Failed)
+	}
+	if r.Skipped > 0 {
+		fmt.Fprintf(out, ", %d skipped", r.Skipped)
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// Err returns nil when every item succeeded. Otherwise it returns an
+// ExitError marked printed, since the items were reported already: with
+// ExitCodePartialFailure when some items succeeded, or, when none did,
+// the exit code of the failures' category if they all share one.
+func (r *bulkResult) Err() error {
+	if r.ok() {
+		return nil
+	}
+	err := fmt.Errorf("%d of %d failed", len(r.Items)-r.Succeeded, len(r.Items))
+	if r.Succeeded > 0 {
+		return &ExitError{Code: ExitCodePartialFailure, Err: err, Printed: true}
+	}
+
+	if category := r.failureCategory(); category != nil {
+		err = withCategory(category, err)
+	}
+	return &ExitError{Code: exitCodeFromError(err), Err: err, Printed: true}
+}
+
+// failureCategory returns the category every failed item shares, or nil if
+// none failed or the failures differ. Skipped items are left out: they were
+// skipped because of the failures.
+func (r *bulkResult) failureCategory() error {
+	var category error
+	for _, item := range r.Items {
+		if item.Status != bulkFailed {
+			continue
+		}
+		itemCategory := classifyCategory(item.err)
+		if category != nil && itemCategory != category {
+			return nil
+		}
+		category = itemCategory
+	}
+	return category
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestBulkResultExitCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []error
+		want    int
+	}{
+		{name: "all ok", results: []error{nil, nil}, want: 0},
+		{name: "partial failure", results: []error{nil, notFoundError("agent 'b' not found"), nil}, want: ExitCodePartialFailure},
+		{name: "total failure", results: []error{notFoundError("agent 'a' not found"), notFoundError("agent 'b' not found")}, want: ExitCodeNotFound},
+		{name: "total failure of mixed categories", results: []error{notFoundError("agent 'a' not found"), conflictError("agent 'b' is busy")}, want: ExitCodeError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &bulkResult{}
+			for i, err := range tt.results {
+				result.record(bulkItem{ID: string(rune('a' + i)), Action: "terminate"}, err)
+			}
+			if got := exitCodeFromError(result.Err()); got != tt.want {
+				t.Fatalf("expected exit %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTerminateAgentsContinuesPastFailures(t *testing.T) {
+	terminator := &fakeTerminator{failures: map[string]error{"agent-2": errors.New("pane is gone")}}
+	agents := []*models.Agent{{ID: "agent-1"}, {ID: "agent-2"}, {ID: "agent-3"}}
+
+	result, err := terminateAgents(context.Background(), Options{}, terminator, agents, agent.TerminateOptions{})
+	if err != nil {
+		t.Fatalf("terminateAgents: %v", err)
+	}
+	if strings.Join(terminator.calls, ",") != "agent-1,agent-2,agent-3" {
+		t.Fatalf("expected every agent attempted, got %v", terminator.calls)
+	}
+	if result.Succeeded != 2 || result.Failed != 1 {
+		t.Fatalf("expected 2 ok and 1 failed, got %+v", result)
+	}
+	if item := result.Items[1]; item.Status != bulkFailed || !strings.Contains(item.Error, "pane is gone") {
+		t.Fatalf("expected agent-2 reported failed, got %+v", item)
+	}
+	if got := exitCodeFromError(result.Err()); got != ExitCodePartialFailure {
+		t.Fatalf("expected partial failure exit, got %d", got)
+	}
+
+	var out bytes.Buffer
+	if err := result.writeTable(Options{Out: &out}); err != nil {
+		t.Fatalf("writeTable: %v", err)
+	}
+	if !strings.Contains(out.String(), "pane is gone") || !strings.HasSuffix(out.String(), "2 of 3 succeeded, 1 failed\n") {
+		t.Fatalf("unexpected table:\n%s", out.String())
+	}
+}
+
+func TestWorkspaceRemoveReportsEachWorkspace(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	a := seedQueueAgent(t, database)
+	wsRepo := db.NewWorkspaceRepository(database)
+
+	var code int
+	out := captureStdout(t, func() {
+		code, _ = runCommand(t, newWsRemoveCmd(), "--json", "--force", "missing", a.WorkspaceID)
+	})
+	if code != ExitCodePartialFailure {
+		t.Fatalf("expected partial failure exit, got %d\n%s", code, out)
+	}
+	var result wsRemoveResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if len(result.Items) != 2 || result.Items[0].Status != bulkFailed || result.Items[1].Status != bulkOK || result.Items[1].ID != a.WorkspaceID {
+		t.Fatalf("unexpected items %+v", result.Items)
+	}
+	if _, err := wsRepo.Get(ctx, a.WorkspaceID); !errors.Is(err, db.ErrWorkspaceNotFound) {
+		t.Fatalf("expected the workspace removed past the failure, got %v", err)
+	}
+
+	out = captureStdout(t, func() {
+		code, _ = runCommand(t, newWsRemoveCmd(), "missing", "also-missing")
+	})
+	if code != ExitCodeNotFound {
+		t.Fatalf("expected not-found exit, got %d\n%s", code, out)
+	}
+	if !strings.Contains(string(out), "0 of 2 succeeded, 2 failed") {
+		t.Fatalf("expected a summary line, got:\n%s", out)
+	}
+}
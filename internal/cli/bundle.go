@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/bundle"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleInspectCmd)
+}
+
+// bundleView is a bundle's manifest with the file it was read from or
+// written to.
+type bundleView struct {
+	bundle.Manifest
+	Path string `json:"path"`
+}
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Inspect agent bundles",
+	Long:  `Inspect bundles written by 'swarm agent bundle'.`,
+}
+
+var bundleInspectCmd = &cobra.Command{
+	Use:   "inspect <file>",
+	Short: "Print a bundle's manifest",
+	Long: `Print the manifest of a bundle written by 'swarm agent bundle': the
+agent, when and by which version it was written, the schema versions, the
+redaction rules applied, the time range and trace IDs covered, and each
+file with its row count and size. Only the manifest is read.`,
+	Example: `  swarm bundle inspect agent-bundle.tar.gz
+  swarm bundle inspect agent-bundle.tar.gz --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		file, err := os.Open(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return notFoundError("bundle %s not found", path)
+			}
+			return err
+		}
+		defer file.Close()
+
+		manifest, err := bundle.ReadManifest(file)
+		if err != nil {
+			if errors.Is(err, bundle.ErrNotBundle) {
+				return invalidInputError("%s is not an agent bundle", path)
+			}
+			return err
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, bundleView{Manifest: *manifest, Path: path})
+		}
+		return writeBundleManifest(manifest)
+	},
+}
+
+func writeBundleManifest(manifest *bundle.Manifest) error {
+	fmt.Printf("Agent:      %s\n", manifest.AgentID)
+	fmt.Printf("Created:    %s\n", manifest.CreatedAt.Format(time.RFC3339))
+	if manifest.SwarmVersion != "" {
+		fmt.Printf("Swarm:      %s\n", manifest.SwarmVersion)
+	}
+	fmt.Printf("Format:     v%d (database schema %d)\n", manifest.Version, manifest.DBSchemaVersion)
+	if len(manifest.RedactionRules) > 0 {
+		fmt.Printf("Redaction:  %s\n", strings.Join(manifest.RedactionRules, ", "))
+	} else {
+		fmt.Println("Redaction:  disabled")
+	}
+	if manifest.TimeRange != nil {
+		fmt.Printf("Time range: %s - %s\n", manifest.TimeRange.Start.Format(time.RFC3339), manifest.TimeRange.End.Format(time.RFC3339))
+	}
+	fmt.Printf("Trace IDs:  %d\n\n", len(manifest.TraceIDs))
+
+	rows := make([][]string, 0, len(manifest.Sections))
+	for _, section := range manifest.Sections {
+		rows = append(rows, []string{
+			section.File,
+			strconv.Itoa(section.Count),
+			formatBytes(section.Bytes),
+			strconv.Itoa(section.Schema),
+			section.Error,
+		})
+	}
+	return writeTable(os.Stdout, []string{"FILE", "ROWS", "SIZE", "SCHEMA", "ERROR"}, rows)
+}
@@ -13,10 +13,14 @@ const (
 )
 
 func colorEnabled() bool {
-	if IsJSONOutput() || IsJSONLOutput() {
+	return rootOptions().colorEnabled()
+}
+
+func (o Options) colorEnabled() bool {
+	if o.Structured() {
 		return false
 	}
-	if noColor {
+	if o.NoColor {
 		return false
 	}
 	if _, ok := os.LookupEnv("NO_COLOR"); ok {
@@ -26,7 +30,11 @@ func colorEnabled() bool {
 }
 
 func colorize(text, color string) string {
-	if !colorEnabled() || color == "" {
+	return rootOptions().colorize(text, color)
+}
+
+func (o Options) colorize(text, color string) string {
+	if !o.colorEnabled() || color == "" {
 		return text
 	}
 	return color + text + colorReset
@@ -0,0 +1,143 @@
+// Package cli provides the cancellation and deadline shared by every command.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ExitCodeInterrupted is the exit code of a command stopped by SIGINT or
+// SIGTERM, following the shell convention of 128 + SIGINT.
+const ExitCodeInterrupted = 130
+
+// Cancellation causes of a command stopped by a signal or by --timeout.
+var (
+	errInterrupted = errors.New("interrupted")
+	errTimedOut    = errors.New("timed out")
+)
+
+// forceExit ends the process when a second signal arrives while a command
+// is still cleaning up after the first.
+var forceExit = func() { os.Exit(ExitCodeInterrupted) }
+
+type commandScopeKey struct{}
+
+// commandScope owns the context every command runs under. The first SIGINT
+// or SIGTERM cancels it so the command can stop and clean up; a second one
+// exits at once. A deadline set through --timeout cancels it the same way.
+type commandScope struct {
+	cancel   context.CancelCauseFunc
+	done     chan struct{}
+	stopOnce sync.Once
+	stopSigs func()
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+// newCommandContext returns a context cancelled on SIGINT or SIGTERM, and
+// a function that stops watching for signals and releases the context.
+func newCommandContext(parent context.Context) (context.Context, func()) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	ctx, scope := watchSignals(parent, signals)
+	scope.stopSigs = func() { signal.Stop(signals) }
+	return ctx, scope.stop
+}
+
+// watchSignals returns a context cancelled when the first signal arrives
+// on signals, after the open progress step is failed so no output lands on
+// its line; a second signal calls forceExit.
+func watchSignals(parent context.Context, signals <-chan os.Signal) (context.Context, *commandScope) {
+	ctx, cancel := context.WithCancelCause(parent)
+	scope := &commandScope{cancel: cancel, done: make(chan struct{})}
+	ctx = context.WithValue(ctx, commandScopeKey{}, scope)
+
+	go func() {
+		select {
+		case <-signals:
+		case <-scope.done:
+			return
+		}
+		failOpenProgress(errInterrupted)
+		progressNote("Stopping; press Ctrl+C again to quit immediately.")
+		scope.cancel(errInterrupted)
+
+		select {
+		case <-signals:
+			forceExit()
+		case <-scope.done:
+		}
+	}()
+	return ctx, scope
+}
+
+// setTimeout cancels the command once d has passed.
+func (s *commandScope) setTimeout(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	cause := fmt.Errorf("%w after %s (--timeout)", errTimedOut, d)
+	s.timer = time.AfterFunc(d, func() {
+		failOpenProgress(cause)
+		s.cancel(cause)
+	})
+}
+
+// stop releases the scope: it stops watching for signals and the deadline,
+// fails any progress step the command left open, and cancels the context.
+func (s *commandScope) stop() {
+	s.stopOnce.Do(func() {
+		if s.stopSigs != nil {
+			s.stopSigs()
+		}
+		close(s.done)
+		s.mu.Lock()
+		if s.timer != nil {
+			s.timer.Stop()
+		}
+		s.mu.Unlock()
+		failOpenProgress(nil)
+		s.cancel(context.Canceled)
+	})
+}
+
+// applyCommandTimeout applies the --timeout deadline to the command's
+// context. Commands with a --timeout flag of their own (agent wait, run,
+// ...) shadow the global flag and apply theirs instead.
+func applyCommandTimeout(cmd *cobra.Command, timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	if scope, ok := cmd.Context().Value(commandScopeKey{}).(*commandScope); ok {
+		scope.setTimeout(timeout)
+	}
+}
+
+// commandError reports an error returned by a command whose context was
+// cancelled as the interrupt or timeout that caused it.
+func commandError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	cause := context.Cause(ctx)
+	if errors.Is(cause, errInterrupted) {
+		return &ExitError{Code: ExitCodeInterrupted, Err: fmt.Errorf("interrupted: %w", err)}
+	}
+	if errors.Is(cause, errTimedOut) {
+		// Classify as unavailable even when err already has a category,
+		// such as the internal error of a cancelled service call.
+		return &categorizedError{category: ErrUnavailable, err: fmt.Errorf("%v: %w", cause, err)}
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+// captureProgress sends progress output to a buffer for the test.
+func captureProgress(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := progressOut
+	progressOut = &buf
+	t.Cleanup(func() {
+		progressOut = previous
+		openProgress = nil
+	})
+	return &buf
+}
+
+func TestWatchSignals(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	out := captureProgress(t)
+
+	forced := make(chan struct{})
+	previous := forceExit
+	forceExit = func() { close(forced) }
+	t.Cleanup(func() { forceExit = previous })
+
+	signals := make(chan os.Signal, 2)
+	ctx, scope := watchSignals(context.Background(), signals)
+	defer scope.stop()
+
+	startProgress("Spawning agent 1/2")
+	signals <- os.Interrupt
+	<-ctx.Done()
+	if cause := context.Cause(ctx); !errors.Is(cause, errInterrupted) {
+		t.Fatalf("expected the context cancelled as interrupted, got %v", cause)
+	}
+	if !strings.Contains(out.String(), "Spawning agent 1/2... interrupted\n") {
+		t.Fatalf("expected the open step failed as interrupted, got %q", out.String())
+	}
+
+	signals <- os.Interrupt
+	select {
+	case <-forced:
+	case <-time.After(time.Second):
+		t.Fatal("expected the second signal to force an exit")
+	}
+}
+
+func TestCommandScopeStopFailsOpenProgress(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	out := captureProgress(t)
+
+	ctx, scope := watchSignals(context.Background(), make(chan os.Signal))
+	step := startProgress("Importing workspace")
+	scope.stop()
+	scope.stop()
+	step.Done()
+
+	if ctx.Err() == nil {
+		t.Fatal("expected stop to cancel the context")
+	}
+	if out.String() != "Importing workspace... failed\n" {
+		t.Fatalf("expected the step left open failed once, got %q", out.String())
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	captureProgress(t)
+
+	ctx, scope := watchSignals(context.Background(), make(chan os.Signal))
+	defer scope.stop()
+	scope.setTimeout(10 * time.Millisecond)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the deadline to cancel the context")
+	}
+	err := commandError(ctx, wrapServiceError(ctx.Err(), "failed to list agents"))
+	if !errors.Is(err, ErrUnavailable) || !strings.Contains(err.Error(), "timed out after 10ms (--timeout)") {
+		t.Fatalf("expected an unavailable timeout error, got %v", err)
+	}
+	if code := exitCodeFromError(err); code != ExitCodeUnavailable {
+		t.Fatalf("expected exit code %d, got %d", ExitCodeUnavailable, code)
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	failure := errors.New("failed to spawn agent")
+
+	if err := commandError(context.Background(), failure); err != failure {
+		t.Fatalf("expected errors of live commands unchanged, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errInterrupted)
+	err := commandError(ctx, failure)
+	if code := exitCodeFromError(err); code != ExitCodeInterrupted {
+		t.Fatalf("expected exit code %d, got %d", ExitCodeInterrupted, code)
+	}
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || !errors.Is(exitErr.Err, failure) || err.Error() != "interrupted: failed to spawn agent" {
+		t.Fatalf("expected the interrupted error to wrap the failure, got %v", err)
+	}
+
+	if err := commandError(ctx, nil); err != nil {
+		t.Fatalf("expected commands that finished despite the interrupt to succeed, got %v", err)
+	}
+}
@@ -46,19 +46,7 @@ func registerDynamicCompletions() {
 	nodeRefreshCmd.ValidArgsFunction = completeNodeNames
 	nodeExecCmd.ValidArgsFunction = completeNodeNames
 
-	wsStatusCmd.ValidArgsFunction = completeWorkspaceNames
-	wsBeadsStatusCmd.ValidArgsFunction = completeWorkspaceNames
-	wsAttachCmd.ValidArgsFunction = completeWorkspaceNames
-	wsRemoveCmd.ValidArgsFunction = completeWorkspaceNames
-	wsKillCmd.ValidArgsFunction = completeWorkspaceNames
-	wsRefreshCmd.ValidArgsFunction = completeWorkspaceNames
-
-	_ = wsCreateCmd.RegisterFlagCompletionFunc("node", completeNodeNames)
-	_ = wsImportCmd.RegisterFlagCompletionFunc("node", completeNodeNames)
-	_ = wsListCmd.RegisterFlagCompletionFunc("node", completeNodeNames)
-
-	_ = agentSpawnCmd.RegisterFlagCompletionFunc("workspace", completeWorkspaceNames)
-	_ = agentListCmd.RegisterFlagCompletionFunc("workspace", completeWorkspaceNames)
+	// The workspace and agent commands register theirs as they are built.
 }
 
 func completeNodeNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -0,0 +1,276 @@
+// Package cli provides config inspection and encryption commands.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/age"
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Audited config operations.
+const (
+	auditEncryptConfig = "encrypt_config"
+	auditDecryptConfig = "decrypt_config"
+)
+
+var (
+	configShowEffective bool
+
+	configEncryptOutput     string
+	configEncryptRecipients []string
+	configEncryptIdentity   string
+
+	configDecryptOutput   string
+	configDecryptIdentity string
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configEncryptCmd)
+	configCmd.AddCommand(configDecryptCmd)
+
+	configShowCmd.Flags().BoolVar(&configShowEffective, "effective", false, "show merged settings from defaults, config files, and environment")
+
+	configEncryptCmd.Flags().StringVarP(&configEncryptOutput, "output", "o", "", "output path (default: <name>.enc.<ext> next to the input)")
+	configEncryptCmd.Flags().StringArrayVarP(&configEncryptRecipients, "recipient", "r", nil, "age recipient (age1...) to encrypt to (repeatable)")
+	configEncryptCmd.Flags().StringVarP(&configEncryptIdentity, "identity", "i", "", "age identity file to encrypt to when no recipient is given (default: encryption.identity_file)")
+
+	configDecryptCmd.Flags().StringVarP(&configDecryptOutput, "output", "o", "", "write plaintext to this path instead of stdout")
+	configDecryptCmd.Flags().StringVarP(&configDecryptIdentity, "identity", "i", "", "age identity file (default: encryption.identity_file)")
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and encrypt Swarm config",
+	Long: `Inspect the loaded configuration and manage encrypted config files.
+
+An age-encrypted config.enc.yaml next to config.yaml is decrypted at load
+time and merged over it, so shared settings with secrets (for example
+account profiles with literal credentials) can be committed safely.`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the config files in use",
+	Long: `Show the config files in use, or with --effective the merged settings.
+
+Settings that came from an encrypted config are always shown redacted.`,
+	Example: `  swarm config show
+  swarm config show --effective
+  swarm config show --effective --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loader, err := loadedConfig()
+		if err != nil {
+			return err
+		}
+
+		if configShowEffective {
+			settings := loader.EffectiveSettings()
+			if IsJSONOutput() || IsJSONLOutput() {
+				return WriteOutput(os.Stdout, settings)
+			}
+			data, err := yaml.Marshal(settings)
+			if err != nil {
+				return fmt.Errorf("failed to format settings: %w", err)
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+
+		files := map[string]string{
+			"config_file":           loader.ConfigFileUsed(),
+			"encrypted_config_file": loader.EncryptedConfigFileUsed(),
+			"identity_file":         configIdentityFile(""),
+		}
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, files)
+		}
+		for _, row := range [][2]string{
+			{"Config file", files["config_file"]},
+			{"Encrypted config", files["encrypted_config_file"]},
+			{"Identity file", files["identity_file"]},
+		} {
+			value := row[1]
+			if value == "" {
+				value = "(none)"
+			}
+			fmt.Printf("%-18s %s\n", row[0]+":", value)
+		}
+		return nil
+	},
+}
+
+var configEncryptCmd = &cobra.Command{
+	Use:   "encrypt <file>",
+	Short: "Encrypt a config file with age",
+	Long: `Encrypt a config file with age, writing ASCII-armored output.
+
+Without --recipient the file is encrypted to the identities in the identity
+file; if that file does not exist, a new identity is generated there.`,
+	Example: `  swarm config encrypt secrets.yaml -o config.enc.yaml
+  swarm config encrypt config.yaml -r age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input := args[0]
+		plaintext, err := os.ReadFile(input)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", input, err)
+		}
+		if age.IsEncrypted(plaintext) {
+			return invalidInputError("%s is already encrypted", input)
+		}
+
+		output := configEncryptOutput
+		if output == "" {
+			output = config.EncryptedConfigPath(input)
+		}
+		if output == input {
+			return invalidInputError("output path must differ from the input; use --output")
+		}
+
+		recipients, generated, err := configRecipients(configEncryptRecipients, configEncryptIdentity)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(recipients))
+		for _, r := range recipients {
+			names = append(names, r.String())
+		}
+		encrypted, err := config.EncryptConfig(plaintext, recipients...)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", input, err)
+		}
+		err = os.WriteFile(output, encrypted, 0644)
+		auditLocal(cmd.Context(), auditEncryptConfig, models.EntityTypeSystem, output, audit.Params(
+			"input", input,
+			"recipients", strings.Join(names, ","),
+		), err)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, map[string]any{
+				"input":              input,
+				"output":             output,
+				"recipients":         names,
+				"generated_identity": generated,
+			})
+		}
+		if generated != "" {
+			fmt.Fprintf(os.Stderr, "Generated age identity at %s; keep it secret and share it with teammates who need to decrypt.\n", generated)
+		}
+		fmt.Printf("Encrypted %s to %s for %s\n", input, output, strings.Join(names, ", "))
+		return nil
+	},
+}
+
+var configDecryptCmd = &cobra.Command{
+	Use:   "decrypt <file>",
+	Short: "Decrypt an age-encrypted config file",
+	Example: `  swarm config decrypt config.enc.yaml
+  swarm config decrypt config.enc.yaml -o /tmp/config.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input := args[0]
+		plaintext, err := config.DecryptConfigFile(input, configIdentityFile(configDecryptIdentity))
+		auditLocal(cmd.Context(), auditDecryptConfig, models.EntityTypeSystem, input, audit.Params("output", configDecryptOutput), err)
+		if err != nil {
+			if errors.Is(err, age.ErrMalformed) {
+				return invalidInputError("%v", err)
+			}
+			return err
+		}
+
+		if configDecryptOutput == "" {
+			_, err := os.Stdout.Write(plaintext)
+			return err
+		}
+		// The plaintext holds secrets, so only the owner may read it.
+		if err := os.WriteFile(configDecryptOutput, plaintext, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", configDecryptOutput, err)
+		}
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, map[string]string{"input": input, "output": configDecryptOutput})
+		}
+		fmt.Printf("Decrypted %s to %s\n", input, configDecryptOutput)
+		return nil
+	},
+}
+
+// loadedConfig returns the loader used at startup, loading the config
+// again if the command runs without one.
+func loadedConfig() (*config.Loader, error) {
+	if configLoader != nil {
+		return configLoader, nil
+	}
+	loader := config.NewLoader()
+	if cfgFile := rootOptions().ConfigFile; cfgFile != "" {
+		loader.SetConfigFile(cfgFile)
+	}
+	if _, err := loader.Load(); err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
+
+// configIdentityFile returns override, or the configured identity file.
+func configIdentityFile(override string) string {
+	if override != "" {
+		return override
+	}
+	cfg := GetConfig()
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	return cfg.Encryption.IdentityFile
+}
+
+// configRecipients parses explicit recipients, or falls back to the
+// identity file's recipients, generating an identity if there is none.
+// It returns the path of a generated identity file, if any.
+func configRecipients(explicit []string, identityOverride string) ([]*age.Recipient, string, error) {
+	if len(explicit) > 0 {
+		recipients := make([]*age.Recipient, 0, len(explicit))
+		for _, value := range explicit {
+			r, err := age.ParseRecipient(value)
+			if err != nil {
+				return nil, "", invalidInputError("%v", err)
+			}
+			recipients = append(recipients, r)
+		}
+		return recipients, "", nil
+	}
+
+	identityFile := configIdentityFile(identityOverride)
+	if os.Getenv(config.AgeKeyEnvVar) == "" {
+		if _, err := os.Stat(identityFile); errors.Is(err, os.ErrNotExist) {
+			id, err := config.GenerateIdentityFile(identityFile, time.Now().UTC())
+			if err != nil {
+				return nil, "", err
+			}
+			return []*age.Recipient{id.Recipient()}, identityFile, nil
+		}
+	}
+
+	ids, err := config.LoadIdentities(identityFile)
+	if err != nil {
+		return nil, "", err
+	}
+	recipients := make([]*age.Recipient, 0, len(ids))
+	for _, id := range ids {
+		recipients = append(recipients, id.Recipient())
+	}
+	return recipients, "", nil
+}
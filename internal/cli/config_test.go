@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/config"
+)
+
+func TestConfigEncryptDecryptRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	identity := filepath.Join(dir, "age.key")
+	input := filepath.Join(dir, "config.yaml")
+	secretConfig := "accounts:\n  - provider: anthropic\n    profile_name: shared\n    credential_ref: sk-ant-literal-secret\n"
+	if err := os.WriteFile(input, []byte(secretConfig), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	if code, err := runCommand(t, configEncryptCmd, input, "--identity", identity); code != 0 {
+		t.Fatalf("config encrypt failed with exit %d: %v", code, err)
+	}
+	encrypted, err := os.ReadFile(filepath.Join(dir, "config.enc.yaml"))
+	if err != nil {
+		t.Fatalf("expected encrypted output: %v", err)
+	}
+	if strings.Contains(string(encrypted), "sk-ant-literal-secret") {
+		t.Fatal("encrypted output contains the plaintext secret")
+	}
+	if _, err := os.Stat(identity); err != nil {
+		t.Fatalf("expected a generated identity file: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if code, err := runCommand(t, configDecryptCmd, filepath.Join(dir, "config.enc.yaml"), "--identity", identity); code != 0 {
+			t.Errorf("config decrypt failed with exit %d: %v", code, err)
+		}
+	})
+	if string(out) != secretConfig {
+		t.Fatalf("decrypted config = %q, want %q", out, secretConfig)
+	}
+
+	other := filepath.Join(dir, "other.key")
+	if _, err := config.GenerateIdentityFile(other, time.Now()); err != nil {
+		t.Fatalf("GenerateIdentityFile() error = %v", err)
+	}
+	if code, _ := runCommand(t, configDecryptCmd, filepath.Join(dir, "config.enc.yaml"), "--identity", other); code == 0 {
+		t.Fatal("expected decrypt with the wrong identity to fail")
+	}
+}
+
+func TestConfigShowEffectiveRedactsEncryptedSettings(t *testing.T) {
+	dir := t.TempDir()
+	identity := filepath.Join(dir, "age.key")
+	id, err := config.GenerateIdentityFile(identity, time.Now())
+	if err != nil {
+		t.Fatalf("GenerateIdentityFile() error = %v", err)
+	}
+	encrypted, err := config.EncryptConfig([]byte("accounts:\n  - provider: anthropic\n    profile_name: shared\n    credential_ref: sk-ant-literal-secret\n"), id.Recipient())
+	if err != nil {
+		t.Fatalf("EncryptConfig() error = %v", err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("encryption:\n  identity_file: "+identity+"\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.enc.yaml"), encrypted, 0644); err != nil {
+		t.Fatalf("write encrypted config: %v", err)
+	}
+
+	loader := config.NewLoader()
+	loader.SetConfigFile(configPath)
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	previous := configLoader
+	configLoader = loader
+	t.Cleanup(func() { configLoader = previous })
+
+	for _, args := range [][]string{{"--effective"}, {"--effective", "--json"}} {
+		out := captureStdout(t, func() {
+			if code, err := runCommand(t, configShowCmd, args...); code != 0 {
+				t.Errorf("config show %v failed with exit %d: %v", args, code, err)
+			}
+		})
+		if strings.Contains(string(out), "sk-ant-literal-secret") {
+			t.Fatalf("config show %v leaked a decrypted secret:\n%s", args, out)
+		}
+		if !strings.Contains(string(out), config.RedactedValue) {
+			t.Fatalf("config show %v did not redact encrypted settings:\n%s", args, out)
+		}
+	}
+}
@@ -102,7 +102,7 @@ Examples:
 		}
 		defer database.Close()
 
-		bgCtx := context.Background()
+		bgCtx := cmd.Context()
 
 		// Handle --workspace flag
 		if useWorkspace != "" {
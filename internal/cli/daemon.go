@@ -0,0 +1,345 @@
+// Package cli provides daemon inspection commands.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// daemonDebugTimeout bounds connecting to swarmd and fetching its state.
+const daemonDebugTimeout = 10 * time.Second
+
+var (
+	daemonDebugAddr  string
+	daemonDebugToken string
+
+	daemonReloadAddr string
+)
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonDebugCmd)
+	daemonCmd.AddCommand(daemonReloadCmd)
+
+	defaultAddr := fmt.Sprintf("%s:%d", swarmd.DefaultHost, swarmd.DefaultPort)
+	daemonDebugCmd.Flags().StringVar(&daemonDebugAddr, "addr", defaultAddr, "swarmd host:port")
+	daemonDebugCmd.Flags().StringVar(&daemonDebugToken, "token", "", "debug token (default $"+swarmd.DebugTokenEnv+")")
+
+	daemonReloadCmd.Flags().StringVar(&daemonReloadAddr, "addr", defaultAddr, "swarmd host:port")
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Inspect and control the swarmd daemon",
+	Long:  "Inspect and control a running swarmd daemon.",
+}
+
+// daemonReloadView is the JSON output of a config reload.
+type daemonReloadView struct {
+	ConfigFile      string   `json:"config_file"`
+	RestartRequired []string `json:"restart_required"`
+}
+
+var daemonReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload swarmd's configuration",
+	Long: `Make a running swarmd re-read its config file and apply it without a
+restart, as it does by itself when the file changes.
+
+Rate limits, redaction patterns, adapter overrides, recurring schedule
+settings, and standby pools take effect on their next cycle. A config that
+does not load or validate is rejected as a whole and the daemon keeps
+running with its current config. Settings read only at startup, such as
+the database path, data directory, and logging, are listed as needing a
+restart. The listen address is set by swarmd's flags and cannot be
+reloaded.`,
+	Example: `  swarm daemon reload
+  swarm daemon reload --addr 127.0.0.1:55051`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), daemonDebugTimeout)
+		defer cancel()
+
+		client, err := swarmd.Dial(ctx, daemonReloadAddr, swarmd.WithAuthToken(os.Getenv(swarmd.AuthTokenEnv)))
+		if err != nil {
+			return wrapServiceError(err, "failed to connect to swarmd")
+		}
+		defer client.Close()
+
+		resp, err := client.ReloadConfig(ctx)
+		if err != nil {
+			switch status.Code(err) {
+			case codes.InvalidArgument:
+				return invalidInputError("%s", status.Convert(err).Message())
+			case codes.FailedPrecondition:
+				return conflictError("swarmd cannot reload its config: restart it instead")
+			case codes.Unauthenticated:
+				return invalidInputError("swarmd rejected the auth token")
+			}
+			return wrapServiceError(err, "failed to reload swarmd config")
+		}
+
+		view := daemonReloadView{
+			ConfigFile:      resp.GetConfigFile(),
+			RestartRequired: append([]string{}, resp.GetRestartRequired()...),
+		}
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, view)
+		}
+		if view.ConfigFile != "" {
+			fmt.Printf("Reloaded swarmd config from %s\n", view.ConfigFile)
+		} else {
+			fmt.Println("Reloaded swarmd config (no config file, defaults and environment only)")
+		}
+		if len(view.RestartRequired) > 0 {
+			fmt.Fprintf(os.Stderr, "warning: restart swarmd to apply: %s\n", strings.Join(view.RestartRequired, ", "))
+		}
+		return nil
+	},
+}
+
+var daemonDebugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Dump swarmd internal state",
+	Long: `Dump a running swarmd's internal state for debugging.
+
+The daemon must be started with --debug-endpoint and the debug token in
+$SWARMD_DEBUG_TOKEN; pass the same token with --token or the environment.
+The dump holds agent IDs, states, panes, and counters only. Agent
+environment, commands, pane content, and transcript text are never
+included.
+
+For a remote node, forward its swarmd port first with 'swarm node tunnel'.`,
+	Example: `  # Show local daemon state
+  SWARMD_DEBUG_TOKEN=secret swarm daemon debug
+
+  # Dump a tunneled daemon as JSON
+  swarm daemon debug --addr 127.0.0.1:55051 --token secret --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token := strings.TrimSpace(daemonDebugToken)
+		if token == "" {
+			token = strings.TrimSpace(os.Getenv(swarmd.DebugTokenEnv))
+		}
+		if token == "" {
+			return invalidInputError("debug token required: pass --token or set %s", swarmd.DebugTokenEnv)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), daemonDebugTimeout)
+		defer cancel()
+
+		// A daemon requiring auth reads the token from the same variable.
+		client, err := swarmd.Dial(ctx, daemonDebugAddr, swarmd.WithAuthToken(os.Getenv(swarmd.AuthTokenEnv)))
+		if err != nil {
+			return wrapServiceError(err, "failed to connect to swarmd")
+		}
+		defer client.Close()
+
+		dump, err := client.DumpState(ctx, token)
+		if err != nil {
+			switch status.Code(err) {
+			case codes.PermissionDenied:
+				return conflictError("debug endpoint is disabled: restart swarmd with --debug-endpoint")
+			case codes.Unauthenticated:
+				return invalidInputError("swarmd rejected the debug or auth token")
+			}
+			return wrapServiceError(err, "failed to dump swarmd state")
+		}
+
+		view := newDaemonStateView(dump)
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, view)
+		}
+		return writeDaemonState(view)
+	},
+}
+
+// daemonStateView is the CLI form of a swarmd state dump.
+type daemonStateView struct {
+	CapturedAt       time.Time              `json:"captured_at"`
+	Build            daemonBuildView        `json:"build"`
+	UptimeSeconds    int64                  `json:"uptime_seconds"`
+	Agents           []daemonAgentView      `json:"agents"`
+	EventSubscribers int                    `json:"event_subscribers"`
+	StoredEvents     int                    `json:"stored_events"`
+	Health           *daemonHealthView      `json:"health,omitempty"`
+	RateLimiter      *daemonRateLimiterView `json:"rate_limiter,omitempty"`
+}
+
+type daemonBuildView struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Built     string `json:"built"`
+	GoVersion string `json:"go_version"`
+}
+
+type daemonAgentView struct {
+	ID                string    `json:"id"`
+	WorkspaceID       string    `json:"workspace_id"`
+	State             string    `json:"state"`
+	PaneID            string    `json:"pane_id"`
+	TranscriptEntries int       `json:"transcript_entries"`
+	LastActivityAt    time.Time `json:"last_activity_at"`
+	PaneStreams       int       `json:"pane_streams"`
+	TranscriptStreams int       `json:"transcript_streams"`
+	EventStreams      int       `json:"event_streams"`
+}
+
+type daemonHealthView struct {
+	Health string                  `json:"health"`
+	Checks []daemonHealthCheckView `json:"checks"`
+}
+
+type daemonHealthCheckView struct {
+	Name      string    `json:"name"`
+	Health    string    `json:"health"`
+	Message   string    `json:"message"`
+	LastCheck time.Time `json:"last_check"`
+}
+
+type daemonRateLimiterView struct {
+	Enabled bool                    `json:"enabled"`
+	Methods []daemonRateCounterView `json:"methods"`
+	Global  *daemonRateCounterView  `json:"global,omitempty"`
+}
+
+type daemonRateCounterView struct {
+	Method         string  `json:"method"`
+	Available      float64 `json:"available"`
+	TotalRequests  int64   `json:"total_requests"`
+	DeniedRequests int64   `json:"denied_requests"`
+}
+
+func newDaemonStateView(dump *swarmdv1.DumpStateResponse) daemonStateView {
+	build := dump.GetBuild()
+	view := daemonStateView{
+		CapturedAt: dump.GetCapturedAt().AsTime(),
+		Build: daemonBuildView{
+			Version:   build.GetVersion(),
+			Commit:    build.GetCommit(),
+			Built:     build.GetBuilt(),
+			GoVersion: build.GetGoVersion(),
+		},
+		UptimeSeconds:    int64(dump.GetUptime().AsDuration().Seconds()),
+		Agents:           make([]daemonAgentView, 0, len(dump.GetAgents())),
+		EventSubscribers: int(dump.GetEventSubscribers()),
+		StoredEvents:     int(dump.GetStoredEvents()),
+	}
+	for _, agent := range dump.GetAgents() {
+		view.Agents = append(view.Agents, daemonAgentView{
+			ID:                agent.GetId(),
+			WorkspaceID:       agent.GetWorkspaceId(),
+			State:             enumLabel(agent.GetState().String(), "AGENT_STATE_"),
+			PaneID:            agent.GetPaneId(),
+			TranscriptEntries: int(agent.GetTranscriptEntries()),
+			LastActivityAt:    agent.GetLastActivityAt().AsTime(),
+			PaneStreams:       int(agent.GetPaneStreams()),
+			TranscriptStreams: int(agent.GetTranscriptStreams()),
+			EventStreams:      int(agent.GetEventStreams()),
+		})
+	}
+	if health := dump.GetHealth(); health != nil {
+		view.Health = &daemonHealthView{Health: enumLabel(health.GetHealth().String(), "HEALTH_")}
+		for _, check := range health.GetChecks() {
+			view.Health.Checks = append(view.Health.Checks, daemonHealthCheckView{
+				Name:      check.GetName(),
+				Health:    enumLabel(check.GetHealth().String(), "HEALTH_"),
+				Message:   check.GetMessage(),
+				LastCheck: check.GetLastCheck().AsTime(),
+			})
+		}
+	}
+	if rl := dump.GetRateLimiter(); rl != nil {
+		view.RateLimiter = &daemonRateLimiterView{Enabled: rl.GetEnabled()}
+		for _, counter := range rl.GetMethods() {
+			view.RateLimiter.Methods = append(view.RateLimiter.Methods, newDaemonRateCounterView(counter))
+		}
+		if global := rl.GetGlobal(); global != nil {
+			counter := newDaemonRateCounterView(global)
+			view.RateLimiter.Global = &counter
+		}
+	}
+	return view
+}
+
+func newDaemonRateCounterView(counter *swarmdv1.RateLimitCounter) daemonRateCounterView {
+	return daemonRateCounterView{
+		Method:         counter.GetMethod(),
+		Available:      counter.GetAvailable(),
+		TotalRequests:  counter.GetTotalRequests(),
+		DeniedRequests: counter.GetDeniedRequests(),
+	}
+}
+
+// enumLabel turns a proto enum name like AGENT_STATE_IDLE into "idle".
+func enumLabel(name, prefix string) string {
+	return strings.ToLower(strings.TrimPrefix(name, prefix))
+}
+
+func writeDaemonState(view daemonStateView) error {
+	out := os.Stdout
+	fmt.Fprintf(out, "swarmd %s (commit %s, built %s, %s)\n", view.Build.Version, view.Build.Commit, view.Build.Built, view.Build.GoVersion)
+	fmt.Fprintf(out, "Uptime: %s\n", time.Duration(view.UptimeSeconds)*time.Second)
+
+	if view.Health == nil {
+		fmt.Fprintln(out, "Health: not checked yet")
+	} else {
+		fmt.Fprintf(out, "Health: %s\n", view.Health.Health)
+		rows := make([][]string, 0, len(view.Health.Checks))
+		for _, check := range view.Health.Checks {
+			rows = append(rows, []string{"  " + check.Name, check.Health, check.Message, formatRelativeTime(check.LastCheck)})
+		}
+		if err := writeTable(out, nil, rows); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "Event subscribers: %d (%d events stored)\n", view.EventSubscribers, view.StoredEvents)
+
+	if rl := view.RateLimiter; rl != nil {
+		fmt.Fprintf(out, "Rate limiting: %s\n", formatYesNo(rl.Enabled))
+		counters := rl.Methods
+		if rl.Global != nil {
+			counters = append([]daemonRateCounterView{*rl.Global}, counters...)
+		}
+		rows := make([][]string, 0, len(counters))
+		for _, counter := range counters {
+			rows = append(rows, []string{
+				"  " + counter.Method,
+				fmt.Sprintf("%d", counter.TotalRequests),
+				fmt.Sprintf("%d", counter.DeniedRequests),
+				fmt.Sprintf("%.1f", counter.Available),
+			})
+		}
+		if err := writeTable(out, []string{"  METHOD", "REQUESTS", "DENIED", "AVAILABLE"}, rows); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(out)
+	if len(view.Agents) == 0 {
+		fmt.Fprintln(out, "No agents.")
+		return nil
+	}
+	fmt.Fprintf(out, "Agents (%d):\n", len(view.Agents))
+	rows := make([][]string, 0, len(view.Agents))
+	for _, agent := range view.Agents {
+		rows = append(rows, []string{
+			"  " + shortID(agent.ID),
+			agent.State,
+			agent.PaneID,
+			fmt.Sprintf("%d", agent.TranscriptEntries),
+			fmt.Sprintf("%d/%d/%d", agent.PaneStreams, agent.TranscriptStreams, agent.EventStreams),
+			formatRelativeTime(agent.LastActivityAt),
+		})
+	}
+	return writeTable(out, []string{"  ID", "STATE", "PANE", "TRANSCRIPT", "STREAMS (PANE/TX/EVENT)", "LAST ACTIVE"}, rows)
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+)
+
+func startDebugDaemon(t *testing.T, token string) string {
+	t.Helper()
+	return startDaemonServer(t, swarmd.NewServer(zerolog.Nop(), swarmd.WithDebugToken(token), swarmd.WithVersion("1.2.3")))
+}
+
+func startDaemonServer(t *testing.T, server *swarmd.Server) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	server.SetRateLimiter(swarmd.NewRateLimiter())
+	swarmdv1.RegisterSwarmdServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	return listener.Addr().String()
+}
+
+func TestDaemonDebugJSON(t *testing.T) {
+	addr := startDebugDaemon(t, "tok")
+
+	out := runJSONCommand(t, daemonDebugCmd, "--addr", addr, "--token", "tok")
+	var view daemonStateView
+	if err := json.Unmarshal(out, &view); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if view.Build.Version != "1.2.3" || view.Agents == nil || view.RateLimiter == nil || !view.RateLimiter.Enabled {
+		t.Fatalf("unexpected state dump: %+v", view)
+	}
+}
+
+func TestDaemonDebugRejectsBadToken(t *testing.T) {
+	addr := startDebugDaemon(t, "tok")
+	t.Setenv(swarmd.DebugTokenEnv, "")
+
+	if code, err := runCommand(t, daemonDebugCmd, "--addr", addr); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d without a token, got %d (err=%v)", ExitCodeInvalidInput, code, err)
+	}
+	if code, err := runCommand(t, daemonDebugCmd, "--addr", addr, "--token", "wrong"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d with a bad token, got %d (err=%v)", ExitCodeInvalidInput, code, err)
+	}
+}
+
+func TestDaemonReloadJSON(t *testing.T) {
+	server := swarmd.NewServer(zerolog.Nop())
+	server.SetReloader(func() (*swarmd.ReloadResult, error) {
+		return &swarmd.ReloadResult{ConfigFile: "/etc/swarm/config.yaml", RestartRequired: []string{"database.path"}}, nil
+	})
+	addr := startDaemonServer(t, server)
+
+	out := runJSONCommand(t, daemonReloadCmd, "--addr", addr)
+	var view daemonReloadView
+	if err := json.Unmarshal(out, &view); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if view.ConfigFile != "/etc/swarm/config.yaml" || !reflect.DeepEqual(view.RestartRequired, []string{"database.path"}) {
+		t.Fatalf("unexpected reload output: %+v", view)
+	}
+}
+
+func TestDaemonReloadRejectedConfig(t *testing.T) {
+	server := swarmd.NewServer(zerolog.Nop())
+	addr := startDaemonServer(t, server)
+
+	if code, err := runCommand(t, daemonReloadCmd, "--addr", addr); code != ExitCodeConflict {
+		t.Fatalf("expected exit %d without reload support, got %d (err=%v)", ExitCodeConflict, code, err)
+	}
+
+	server.SetReloader(func() (*swarmd.ReloadResult, error) {
+		return nil, errors.New("config validation failed")
+	})
+	if code, err := runCommand(t, daemonReloadCmd, "--addr", addr); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d for a rejected config, got %d (err=%v)", ExitCodeInvalidInput, code, err)
+	}
+}
@@ -0,0 +1,88 @@
+// Package cli provides database maintenance commands.
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var dbNormalizeProvidersDryRun bool
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbNormalizeProvidersCmd)
+
+	dbNormalizeProvidersCmd.Flags().BoolVar(&dbNormalizeProvidersDryRun, "dry-run", false, "report what would change without changing the database")
+}
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Maintain the swarm database",
+}
+
+var dbNormalizeProvidersCmd = &cobra.Command{
+	Use:   "normalize-providers",
+	Short: "Fix provider names stored on accounts and usage",
+	Long: `Normalize the provider names of custom accounts and usage records.
+
+Custom provider names are lower-cased and trimmed, and custom rows named
+after a known provider or alias ("Claude", "openai-api", "gemini") move to
+that provider so their usage aggregates with it. Daily usage totals of the
+affected days are recomputed. An account that would collide with an
+existing profile of the same provider is reported and left as it is.
+
+Models missing from their provider's catalog are listed as warnings only.`,
+	Example: `  swarm db normalize-providers --dry-run
+  swarm db normalize-providers`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		report, err := database.NormalizeProviders(ctx, dbNormalizeProvidersDryRun)
+		if err != nil {
+			return wrapServiceError(err, "failed to normalize providers")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, report)
+		}
+
+		verb := "Rewrote"
+		if report.DryRun {
+			verb = "Would rewrite"
+		}
+		if len(report.Changes) == 0 {
+			fmt.Println("Providers are already normalized")
+		}
+		for _, change := range report.Changes {
+			fmt.Printf("%s %d %s: %s -> %s\n", verb, change.Rows, change.Table,
+				formatProviderPair(string(change.FromProvider), change.FromName),
+				formatProviderPair(string(change.ToProvider), change.ToName))
+			if change.Conflicts > 0 {
+				fmt.Printf("  %d left as they are: a profile with the same name already exists under %s\n", change.Conflicts, change.ToProvider)
+			}
+		}
+		for _, name := range report.CustomNames {
+			fmt.Printf("Kept custom provider %q on %d %s\n", name.Name, name.Rows, name.Table)
+		}
+		for _, model := range report.UnknownModels {
+			fmt.Printf("Warning: %d usage records use %s, not a known %s model\n", model.Rows, model.Model, model.Provider)
+		}
+		return nil
+	},
+}
+
+func formatProviderPair(provider, name string) string {
+	if name == "" {
+		return provider
+	}
+	return fmt.Sprintf("%s (%q)", provider, name)
+}
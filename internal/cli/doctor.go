@@ -13,6 +13,7 @@ import (
 
 	"github.com/opencode-ai/swarm/internal/config"
 	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/fsperm"
 	"github.com/opencode-ai/swarm/internal/node"
 	"github.com/spf13/cobra"
 )
@@ -52,8 +53,11 @@ type DoctorSummary struct {
 	Skipped  int `json:"skipped"`
 }
 
+var doctorFixPermissions bool
+
 func init() {
 	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorFixPermissions, "fix-permissions", false, "remove group and other access from the data directory, database, and vault")
 }
 
 var doctorCmd = &cobra.Command{
@@ -65,11 +69,16 @@ Checks include:
 - Dependencies: tmux, opencode, ssh, git
 - Configuration: config file, database, migrations
 - Nodes: connectivity and health
-- Accounts: vault access and profiles`,
+- Accounts: vault access and profiles
+- Permissions: the data directory, database, and vault are owner-only
+
+With --fix-permissions, paths open to other users are tightened before
+they are checked. Paths owned by another user are reported, not changed.`,
 	Example: `  swarm doctor
-  swarm doctor --json`,
+  swarm doctor --json
+  swarm doctor --fix-permissions`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		checks := make([]DoctorCheck, 0)
 
@@ -83,6 +92,9 @@ Checks include:
 		dbChecks, database := checkDatabaseHealth()
 		checks = append(checks, dbChecks...)
 
+		// Permission checks
+		checks = append(checks, checkPermissionHealth(doctorFixPermissions)...)
+
 		// Node checks (only if DB is available)
 		if database != nil {
 			checks = append(checks, checkNodes(ctx, database)...)
@@ -241,7 +253,7 @@ func checkConfiguration() []DoctorCheck {
 		})
 	} else if os.IsNotExist(err) {
 		// Try to create it
-		if err := os.MkdirAll(dataDir, 0755); err != nil {
+		if err := fsperm.MkdirAll(dataDir); err != nil {
 			checks = append(checks, DoctorCheck{
 				Category: "config",
 				Name:     "data_directory",
@@ -351,6 +363,81 @@ func checkDatabaseHealth() ([]DoctorCheck, *db.DB) {
 	return checks, database
 }
 
+// checkPermissionHealth reports data and vault paths open to other users or
+// owned by one. With fix, group and other access is removed first.
+func checkPermissionHealth(fix bool) []DoctorCheck {
+	if !fsperm.Supported {
+		return []DoctorCheck{{
+			Category: "permissions",
+			Name:     "owner_only",
+			Status:   DoctorSkip,
+			Details:  "not checked on this platform",
+		}}
+	}
+	if appConfig == nil {
+		return []DoctorCheck{{
+			Category: "permissions",
+			Name:     "owner_only",
+			Status:   DoctorSkip,
+			Details:  "configuration not loaded",
+		}}
+	}
+
+	checks := make([]DoctorCheck, 0)
+	paths := permissionPaths()
+	problems := fsperm.Check(paths...)
+	if fix && len(problems) > 0 {
+		// Paths Fix cannot tighten show up again in the re-check.
+		_ = fsperm.Fix(problems)
+		remaining := fsperm.Check(paths...)
+		left := make(map[string]bool, len(remaining))
+		for _, p := range remaining {
+			left[p.Path] = true
+		}
+		for _, p := range problems {
+			if !left[p.Path] {
+				checks = append(checks, DoctorCheck{
+					Category: "permissions",
+					Name:     "owner_only",
+					Status:   DoctorPass,
+					Details:  fmt.Sprintf("%s tightened from %04o", p.Path, p.Mode),
+				})
+			}
+		}
+		problems = remaining
+	}
+
+	for _, p := range problems {
+		check := DoctorCheck{
+			Category: "permissions",
+			Name:     "owner_only",
+			Status:   DoctorWarn,
+			Details:  p.String(),
+		}
+		switch {
+		case p.ForeignOwner:
+			check.Status = DoctorFail
+			check.Error = p.String() + "; change its owner with chown"
+		case fix:
+			check.Status = DoctorFail
+			check.Error = p.String() + "; could not remove group and other access"
+		default:
+			check.Details += " (run 'swarm doctor --fix-permissions')"
+		}
+		checks = append(checks, check)
+	}
+
+	if len(checks) == 0 {
+		checks = append(checks, DoctorCheck{
+			Category: "permissions",
+			Name:     "owner_only",
+			Status:   DoctorPass,
+			Details:  "data directory, database, and vault are owner-only",
+		})
+	}
+	return checks
+}
+
 func checkNodes(ctx context.Context, database *db.DB) []DoctorCheck {
 	checks := make([]DoctorCheck, 0)
 
@@ -460,7 +547,7 @@ func printDoctorReport(report *DoctorReport) {
 	fmt.Println()
 
 	// Group by category
-	categories := []string{"dependencies", "config", "database", "nodes"}
+	categories := []string{"dependencies", "config", "database", "permissions", "nodes"}
 	categoryChecks := make(map[string][]DoctorCheck)
 	for _, c := range report.Checks {
 		categoryChecks[c.Category] = append(categoryChecks[c.Category], c)
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+// writeTerminatePlans prints the agents a terminate would stop.
+func writeTerminatePlans(opts Options, plans []*agent.TerminatePlan) error {
+	rows := make([][]string, 0, len(plans))
+	for _, plan := range plans {
+		target := plan.Pane
+		if plan.RemoteAgentID != "" {
+			target = "swarmd " + plan.RemoteAgentID
+		}
+		if target == "" {
+			target = "-"
+		}
+		rows = append(rows, []string{
+			shortID(plan.AgentID),
+			target,
+			strconv.Itoa(plan.QueueItems),
+			formatYesNo(plan.ArchiveLogs),
+		})
+	}
+	return opts.writeTable([]string{"AGENT", "KILL", "QUEUE ITEMS CLEARED", "ARCHIVE LOGS"}, rows)
+}
+
+// printDryRunNote tells the user that a plan was shown instead of applied.
+func printDryRunNote(out io.Writer) {
+	fmt.Fprintln(out, "\nDry run; re-run without --dry-run to apply.")
+}
+
+// workspaceRemovalView is the JSON output of ws remove/kill --dry-run.
+type workspaceRemovalView struct {
+	*workspace.RemovalPlan
+	// AgentRecords is the number of agents in the workspace; remove orphans
+	// them and kill terminates them as listed in Agents.
+	AgentRecords int                    `json:"agent_records"`
+	Agents       []*agent.TerminatePlan `json:"agents,omitempty"`
+}
+
+// writeRemovalPlan prints the plan of a workspace remove or kill.
+func writeRemovalPlan(opts Options, plan *workspace.RemovalPlan, agentRecords int, agents []*agent.TerminatePlan) error {
+	if opts.Structured() {
+		return opts.WriteOutput(workspaceRemovalView{RemovalPlan: plan, AgentRecords: agentRecords, Agents: agents})
+	}
+
+	session := plan.TmuxSession
+	switch {
+	case session == "":
+		session = "-"
+	case plan.KillSession:
+		session += " (killed)"
+	default:
+		session += " (left running)"
+	}
+	rows := [][]string{{plan.Name, shortID(plan.WorkspaceID), session, strconv.Itoa(agentRecords)}}
+	if err := opts.writeTable([]string{"WORKSPACE", "ID", "TMUX SESSION", "AGENTS"}, rows); err != nil {
+		return err
+	}
+	if len(agents) > 0 {
+		fmt.Fprintln(opts.Out)
+		if err := writeTerminatePlans(opts, agents); err != nil {
+			return err
+		}
+	}
+	printDryRunNote(opts.Out)
+	return nil
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestQueueClearDryRun(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	a := seedQueueAgent(t, database)
+	queueRepo := db.NewQueueRepository(database)
+
+	for _, text := range []string{"first", "second"} {
+		item := &models.QueueItem{Type: models.QueueItemTypeMessage, Payload: json.RawMessage(`{"text":"` + text + `"}`)}
+		if err := queueRepo.Enqueue(ctx, a.ID, item); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	var plan queueClearView
+	out := runJSONCommand(t, newQueueClearCmd(), a.ID, "--dry-run")
+	if err := json.Unmarshal(out, &plan); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if !plan.DryRun || plan.Cleared != 2 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	if pending, err := queueRepo.Count(ctx, a.ID); err != nil || pending != 2 {
+		t.Fatalf("dry run changed the queue: %d pending (%v)", pending, err)
+	}
+
+	var result queueClearView
+	out = runJSONCommand(t, newQueueClearCmd(), a.ID)
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if result.DryRun || result.Cleared != plan.Cleared {
+		t.Fatalf("clear reported %+v, plan was %+v", result, plan)
+	}
+	if pending, err := queueRepo.Count(ctx, a.ID); err != nil || pending != 0 {
+		t.Fatalf("expected an empty queue, got %d pending (%v)", pending, err)
+	}
+}
+
+func TestAgentTerminateDryRun(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	a := seedQueueAgent(t, database)
+
+	item := &models.QueueItem{Type: models.QueueItemTypeMessage, Payload: json.RawMessage(`{"text":"hi"}`)}
+	if err := db.NewQueueRepository(database).Enqueue(ctx, a.ID, item); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	var plan agent.TerminatePlan
+	out := runJSONCommand(t, newAgentTerminateCmd(), a.ID[:8], "--dry-run")
+	if err := json.Unmarshal(out, &plan); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if !plan.DryRun || plan.AgentID != a.ID || plan.Pane != a.TmuxPane || plan.QueueItems != 1 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+
+	if _, err := db.NewAgentRepository(database).Get(ctx, a.ID); err != nil {
+		t.Fatalf("dry run removed the agent: %v", err)
+	}
+}
+
+func TestWorkspaceRemoveDryRun(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	a := seedQueueAgent(t, database)
+	wsRepo := db.NewWorkspaceRepository(database)
+
+	var plan workspaceRemovalView
+	out := runJSONCommand(t, newWsRemoveCmd(), a.WorkspaceID, "--dry-run", "--force")
+	if err := json.Unmarshal(out, &plan); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if !plan.DryRun || plan.WorkspaceID != a.WorkspaceID || plan.KillSession {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	if _, err := wsRepo.Get(ctx, a.WorkspaceID); err != nil {
+		t.Fatalf("dry run removed the workspace: %v", err)
+	}
+
+	runJSONCommand(t, newWsRemoveCmd(), a.WorkspaceID, "--force")
+	if _, err := wsRepo.Get(ctx, a.WorkspaceID); !errors.Is(err, db.ErrWorkspaceNotFound) {
+		t.Fatalf("expected the workspace to be removed, got %v", err)
+	}
+}
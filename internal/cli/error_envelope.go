@@ -2,9 +2,9 @@
 package cli
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 )
 
@@ -15,10 +15,12 @@ type ErrorEnvelope struct {
 
 // ErrorPayload carries structured error details.
 type ErrorPayload struct {
-	Code    string         `json:"code"`
-	Message string         `json:"message"`
-	Hint    string         `json:"hint,omitempty"`
-	Details map[string]any `json:"details,omitempty"`
+	Code     string         `json:"code"`
+	Category string         `json:"category,omitempty"`
+	ExitCode int            `json:"exit_code"`
+	Message  string         `json:"message"`
+	Hint     string         `json:"hint,omitempty"`
+	Details  map[string]any `json:"details,omitempty"`
 }
 
 // ExitError carries an exit code and whether output was already printed.
@@ -36,17 +38,31 @@ func (e *ExitError) Error() string {
 }
 
 func handleCLIError(err error) error {
+	return writeCLIError(rootOptions(), err)
+}
+
+// writeCLIError reports err and returns it as an ExitError. With
+// --json-errors the envelope goes to stderr as a single JSON line; with
+// --json/--jsonl it goes to stdout; otherwise the message goes to stderr.
+// Both streams are those of opts.
+func writeCLIError(opts Options, err error) error {
 	if err == nil {
 		return nil
 	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) && exitErr.Printed {
+		return exitErr
+	}
 
 	exitCode := exitCodeFromError(err)
 
-	if IsJSONOutput() || IsJSONLOutput() {
-		envelope := buildErrorEnvelope(err)
-		_ = WriteOutput(os.Stdout, envelope)
-	} else {
-		fmt.Fprintln(os.Stderr, err.Error())
+	switch {
+	case opts.JSONErrors:
+		_ = json.NewEncoder(opts.stderr()).Encode(buildErrorEnvelope(err))
+	case opts.Structured():
+		_ = opts.WriteOutput(buildErrorEnvelope(err))
+	default:
+		fmt.Fprintln(opts.stderr(), err.Error())
 	}
 
 	return &ExitError{
@@ -58,12 +74,15 @@ func handleCLIError(err error) error {
 
 func buildErrorEnvelope(err error) ErrorEnvelope {
 	code, message, hint, details, _ := classifyError(err)
+	category, _, _ := categoryInfo(classifyCategory(err))
 	return ErrorEnvelope{
 		Error: ErrorPayload{
-			Code:    code,
-			Message: message,
-			Hint:    hint,
-			Details: details,
+			Code:     code,
+			Category: category,
+			ExitCode: exitCodeFromError(err),
+			Message:  message,
+			Hint:     hint,
+			Details:  details,
 		},
 	}
 }
@@ -81,7 +100,7 @@ func exitCodeFromError(err error) int {
 }
 
 func classifyError(err error) (code, message, hint string, details map[string]any, exitCode int) {
-	exitCode = 1
+	exitCode = ExitCodeError
 	if err == nil {
 		return "ERR_UNKNOWN", "", "", nil, exitCode
 	}
@@ -101,9 +120,43 @@ func classifyError(err error) (code, message, hint string, details map[string]an
 				"next_step": preflight.NextStep,
 			}
 		}
-		return code, message, hint, details, 2
+		return code, message, hint, details, ExitCodeUnavailable
+	}
+
+	code, hint, details = classifyMessage(message)
+
+	category := classifyCategory(err)
+	if category == nil {
+		return code, message, hint, details, exitCode
+	}
+
+	// Keep the more specific message-derived code when it agrees with the
+	// category (e.g. ERR_AMBIGUOUS or ERR_EXISTS); otherwise use the
+	// category's own code.
+	_, categoryCode, categoryExit := categoryInfo(category)
+	if messageCategory(code) != category {
+		code, hint, details = categoryCode, "", nil
+	}
+	return code, message, hint, details, categoryExit
+}
+
+// classifyCategory returns the error category for err, falling back to the
+// message heuristics when no sentinel or explicit category applies.
+func classifyCategory(err error) error {
+	var preflight *PreflightError
+	if errors.As(err, &preflight) {
+		return ErrUnavailable
+	}
+	if category := errorCategory(err); category != nil {
+		return category
 	}
+	code, _, _ := classifyMessage(err.Error())
+	return messageCategory(code)
+}
 
+// classifyMessage derives an error code, hint, and details from the error
+// text for errors that carry no category.
+func classifyMessage(message string) (code, hint string, details map[string]any) {
 	lower := strings.ToLower(message)
 
 	switch {
@@ -124,21 +177,38 @@ func classifyError(err error) (code, message, hint string, details map[string]an
 		}
 	case strings.Contains(lower, "already exists"):
 		code = "ERR_EXISTS"
-	case strings.Contains(lower, "unknown flag"):
+	case strings.Contains(lower, "unknown flag") || strings.Contains(lower, "unknown shorthand flag"):
 		code = "ERR_INVALID_FLAG"
-	case strings.Contains(lower, "invalid") || strings.Contains(lower, "required") || strings.Contains(lower, "usage") || strings.Contains(lower, "must"):
+	case strings.Contains(lower, "invalid") || strings.Contains(lower, "required") || strings.Contains(lower, "usage") || strings.Contains(lower, "must") ||
+		strings.Contains(lower, "unknown command") || strings.Contains(lower, "arg(s)"):
 		code = "ERR_INVALID"
 	case strings.Contains(lower, "permission denied") || strings.Contains(lower, "timeout") || strings.Contains(lower, "connection"):
-		code = "ERR_OPERATION_FAILED"
-		exitCode = 2
+		code = "ERR_UNAVAILABLE"
 	case strings.Contains(lower, "failed to") || strings.Contains(lower, "unable to"):
 		code = "ERR_OPERATION_FAILED"
-		exitCode = 2
 	default:
 		code = "ERR_UNKNOWN"
 	}
 
-	return code, message, hint, details, exitCode
+	return code, hint, details
+}
+
+// messageCategory maps a message-derived code to its category.
+func messageCategory(code string) error {
+	switch code {
+	case "ERR_NOT_FOUND":
+		return ErrNotFound
+	case "ERR_EXISTS", "ERR_CONFLICT":
+		return ErrConflict
+	case "ERR_AMBIGUOUS", "ERR_INVALID_FLAG", "ERR_INVALID":
+		return ErrInvalidInput
+	case "ERR_UNAVAILABLE":
+		return ErrUnavailable
+	case "ERR_OPERATION_FAILED", "ERR_INTERNAL":
+		return ErrInternal
+	default:
+		return nil
+	}
 }
 
 func inferResourceAndID(lower, original string) (string, string) {
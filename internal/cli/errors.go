@@ -0,0 +1,285 @@
+// Package cli provides the CLI error taxonomy and exit codes.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/checkpoint"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/queue"
+	"github.com/opencode-ai/swarm/internal/snapshot"
+	"github.com/opencode-ai/swarm/internal/ssh"
+	"github.com/opencode-ai/swarm/internal/task"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error categories. Every error returned by a command is classified into
+// one of these (or left unclassified), which selects its exit code.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrUnavailable  = errors.New("unavailable")
+	ErrInternal     = errors.New("internal error")
+)
+
+// Exit codes by error category. Commands with their own outcome codes,
+// such as agent wait, return an ExitError and bypass this mapping.
+const (
+	ExitCodeError        = 1
+	ExitCodeInvalidInput = 2
+	ExitCodeNotFound     = 3
+	ExitCodeConflict     = 4
+	ExitCodeUnavailable  = 5
+	ExitCodeInternal     = 6
+
+	// ExitCodePartialFailure is returned by commands acting on several
+	// items when some of them failed and others succeeded.
+	ExitCodePartialFailure = 7
+)
+
+// errorCategories lists each category with its exit code and the names
+// used in structured error output.
+var errorCategories = []struct {
+	category error
+	name     string
+	code     string
+	exitCode int
+}{
+	{ErrNotFound, "not_found", "ERR_NOT_FOUND", ExitCodeNotFound},
+	{ErrConflict, "conflict", "ERR_CONFLICT", ExitCodeConflict},
+	{ErrInvalidInput, "invalid_input", "ERR_INVALID", ExitCodeInvalidInput},
+	{ErrUnavailable, "unavailable", "ERR_UNAVAILABLE", ExitCodeUnavailable},
+	{ErrInternal, "internal", "ERR_INTERNAL", ExitCodeInternal},
+}
+
+// sentinelCategories maps repository and service sentinel errors to
+// categories so they translate without each command checking for them.
+var sentinelCategories = []struct {
+	err      error
+	category error
+}{
+	// Not found
+	{db.ErrAgentNotFound, ErrNotFound},
+	{db.ErrWorkspaceNotFound, ErrNotFound},
+	{db.ErrNodeNotFound, ErrNotFound},
+	{db.ErrAccountNotFound, ErrNotFound},
+	{db.ErrQueueItemNotFound, ErrNotFound},
+	{db.ErrApprovalNotFound, ErrNotFound},
+	{db.ErrEventNotFound, ErrNotFound},
+	{db.ErrUsageRecordNotFound, ErrNotFound},
+	{db.ErrTaskNotFound, ErrNotFound},
+	{db.ErrReviewNotFound, ErrNotFound},
+	{db.ErrNoteNotFound, ErrNotFound},
+	{db.ErrTranscriptPushNotFound, ErrNotFound},
+	{agent.ErrServiceAgentNotFound, ErrNotFound},
+	{agent.ErrAgentNotFound, ErrNotFound},
+	{agent.ErrWorkspaceNotFound, ErrNotFound},
+	{agent.ErrPaneNotFound, ErrNotFound},
+	{agent.ErrRecordingNotFound, ErrNotFound},
+	{snapshot.ErrNotFound, ErrNotFound},
+	{checkpoint.ErrNotFound, ErrNotFound},
+	{workspace.ErrWorkspaceNotFound, ErrNotFound},
+	{workspace.ErrNodeNotFound, ErrNotFound},
+	{node.ErrNodeNotFound, ErrNotFound},
+	{node.ErrAgentNotFound, ErrNotFound},
+	{account.ErrAccountNotFound, ErrNotFound},
+	{queue.ErrQueueItemNotFound, ErrNotFound},
+	{task.ErrTaskNotFound, ErrNotFound},
+	{tmux.ErrSessionNotFound, ErrNotFound},
+	{tmux.ErrPaneNotFound, ErrNotFound},
+
+	// Conflict
+	{db.ErrAgentAlreadyExists, ErrConflict},
+	{db.ErrWorkspaceAlreadyExists, ErrConflict},
+	{db.ErrNodeAlreadyExists, ErrConflict},
+	{db.ErrReviewAlreadyExists, ErrConflict},
+	{db.ErrAccountAlreadyExists, ErrConflict},
+	{db.ErrPortAlreadyAllocated, ErrConflict},
+	{db.ErrLocked, ErrConflict},
+	{db.ErrDaemonRunning, ErrConflict},
+	{db.ErrIrreversibleMigration, ErrConflict},
+	{db.ErrMigrationChecksumMismatch, ErrConflict},
+	{agent.ErrAgentAlreadyExists, ErrConflict},
+	{agent.ErrAgentNotIdle, ErrConflict},
+	{agent.ErrWorkspacePaused, ErrConflict},
+	{agent.ErrWorkspaceDraining, ErrConflict},
+	{agent.ErrBudgetExceeded, ErrConflict},
+	{agent.ErrCLIOutdated, ErrConflict},
+	{agent.ErrMappingConflict, ErrConflict},
+	{agent.ErrAlreadyRecording, ErrConflict},
+	{agent.ErrNotRecording, ErrConflict},
+	{agent.ErrAgentNotHibernated, ErrConflict},
+	{checkpoint.ErrExists, ErrConflict},
+	{workspace.ErrWorkspaceAlreadyExists, ErrConflict},
+	{workspace.ErrWorkspacePaused, ErrConflict},
+	{workspace.ErrWorkspaceNotPaused, ErrConflict},
+	{workspace.ErrWorkspaceDraining, ErrConflict},
+	{workspace.ErrWorkspaceNotDraining, ErrConflict},
+	{queue.ErrAgentDraining, ErrConflict},
+	{node.ErrNodeAlreadyExists, ErrConflict},
+	{account.ErrAccountAlreadyExists, ErrConflict},
+	{tmux.ErrSessionExists, ErrConflict},
+	{tmux.ErrPaneStillAlive, ErrConflict},
+
+	// Invalid input
+	{models.ErrInvalidNodeName, ErrInvalidInput},
+	{models.ErrInvalidSSHTarget, ErrInvalidInput},
+	{models.ErrInvalidWorkspaceNode, ErrInvalidInput},
+	{models.ErrInvalidRepoPath, ErrInvalidInput},
+	{models.ErrInvalidTmuxSession, ErrInvalidInput},
+	{models.ErrInvalidAgentWorkspace, ErrInvalidInput},
+	{models.ErrInvalidAgentType, ErrInvalidInput},
+	{models.ErrInvalidTmuxPane, ErrInvalidInput},
+	{models.ErrInvalidQueueItem, ErrInvalidInput},
+	{task.ErrNoItems, ErrInvalidInput},
+	{models.ErrInvalidProvider, ErrInvalidInput},
+	{models.ErrUnknownProvider, ErrInvalidInput},
+	{models.ErrInvalidProviderName, ErrInvalidInput},
+	{models.ErrInvalidProfileName, ErrInvalidInput},
+	{workspace.ErrRepoValidationFailed, ErrInvalidInput},
+	{node.ErrInvalidSSHTarget, ErrInvalidInput},
+	{node.ErrInvalidEndpoint, ErrInvalidInput},
+	{node.ErrInvalidLabel, ErrInvalidInput},
+	{node.ErrUnknownPlacementStrategy, ErrInvalidInput},
+	{agent.ErrCrossNodeMove, ErrInvalidInput},
+	{agent.ErrRemoteAgent, ErrInvalidInput},
+	{snapshot.ErrAmbiguous, ErrInvalidInput},
+	{checkpoint.ErrInvalidName, ErrInvalidInput},
+	{checkpoint.ErrNoFiles, ErrInvalidInput},
+	{agent.ErrNoSessionPatterns, ErrInvalidInput},
+	{tmux.ErrInvalidSessionName, ErrInvalidInput},
+
+	// Unavailable
+	{account.ErrNoAvailableAccount, ErrUnavailable},
+	{agent.ErrRecordingDisabled, ErrUnavailable},
+	{agent.ErrSnapshotsDisabled, ErrUnavailable},
+	{agent.ErrCheckpointsDisabled, ErrUnavailable},
+	{agent.ErrAdapterUnavailable, ErrUnavailable},
+	{account.ErrAccountOnCooldown, ErrUnavailable},
+	{node.ErrConnectionFailed, ErrUnavailable},
+	{node.ErrNoConnection, ErrUnavailable},
+	{node.ErrDaemonNotFound, ErrUnavailable},
+	{node.ErrSwarmdUnavailable, ErrUnavailable},
+	{node.ErrNoPlacementCandidates, ErrUnavailable},
+	{db.ErrNoAvailablePorts, ErrUnavailable},
+	{ssh.ErrSSHAgentUnavailable, ErrUnavailable},
+	{ssh.ErrHostKeyRejected, ErrUnavailable},
+	{context.DeadlineExceeded, ErrUnavailable},
+}
+
+// categorizedError attaches an error category to an error while keeping
+// both reachable through errors.Is.
+type categorizedError struct {
+	category error
+	err      error
+}
+
+func (e *categorizedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *categorizedError) Unwrap() []error {
+	return []error{e.err, e.category}
+}
+
+// withCategory tags err with category unless it is already classified.
+func withCategory(category, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errorCategory(err) != nil {
+		return err
+	}
+	return &categorizedError{category: category, err: err}
+}
+
+func notFoundError(format string, args ...any) error {
+	return &categorizedError{category: ErrNotFound, err: fmt.Errorf(format, args...)}
+}
+
+func conflictError(format string, args ...any) error {
+	return &categorizedError{category: ErrConflict, err: fmt.Errorf(format, args...)}
+}
+
+func invalidInputError(format string, args ...any) error {
+	return &categorizedError{category: ErrInvalidInput, err: fmt.Errorf(format, args...)}
+}
+
+func unavailableError(format string, args ...any) error {
+	return &categorizedError{category: ErrUnavailable, err: fmt.Errorf(format, args...)}
+}
+
+// wrapServiceError adds context to an error returned by a service or
+// repository, as fmt.Errorf("...: %w") would, and classifies it. Errors
+// that match no known sentinel are treated as internal failures.
+func wrapServiceError(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	wrapped := fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+	if errorCategory(err) != nil {
+		return wrapped
+	}
+	return &categorizedError{category: ErrInternal, err: wrapped}
+}
+
+// errorCategory returns the category of err: an explicit category, a known
+// sentinel anywhere in its chain, or a gRPC status from the daemon client.
+// It returns nil for unclassified errors.
+func errorCategory(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, c := range errorCategories {
+		if errors.Is(err, c.category) {
+			return c.category
+		}
+	}
+	for _, s := range sentinelCategories {
+		if errors.Is(err, s.err) {
+			return s.category
+		}
+	}
+	if st, ok := status.FromError(err); ok && st.Code() != codes.OK {
+		return grpcCategory(st.Code())
+	}
+	return nil
+}
+
+func grpcCategory(code codes.Code) error {
+	switch code {
+	case codes.NotFound:
+		return ErrNotFound
+	case codes.AlreadyExists, codes.FailedPrecondition, codes.Aborted:
+		return ErrConflict
+	case codes.InvalidArgument, codes.OutOfRange:
+		return ErrInvalidInput
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return ErrUnavailable
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		return ErrInternal
+	default:
+		return nil
+	}
+}
+
+// categoryInfo returns the structured-output name, default error code, and
+// exit code for a category.
+func categoryInfo(category error) (name, code string, exitCode int) {
+	for _, c := range errorCategories {
+		if c.category == category {
+			return c.name, c.code, c.exitCode
+		}
+	}
+	return "", "ERR_UNKNOWN", ExitCodeError
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// useTestDatabase points command database access at a migrated file-backed
+// database and returns it for seeding.
+func useTestDatabase(t *testing.T) *db.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "swarm.db")
+
+	database, err := db.Open(db.Config{Path: path})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := database.Migrate(context.Background()); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	setDatabaseOpener(t, func(bool) (*db.DB, error) {
+		return db.Open(db.Config{Path: path})
+	})
+	return database
+}
+
+func setDatabaseOpener(t *testing.T, opener func(bool) (*db.DB, error)) {
+	t.Helper()
+	previous := databaseOpener
+	databaseOpener = opener
+	t.Cleanup(func() { databaseOpener = previous })
+}
+
+// runCommand parses args into cmd and runs it the way cobra's Execute
+// would, without the root pre-run hooks, returning the reported exit code.
+// A command built on its own is first given a root of its own, which holds
+// the global flags.
+func runCommand(t *testing.T, cmd *cobra.Command, args ...string) (int, error) {
+	t.Helper()
+	if !cmd.HasParent() {
+		newRootCmd().AddCommand(cmd)
+	}
+	resetFlags(cmd)
+	t.Cleanup(func() { resetFlags(cmd) })
+	if cmd.Context() == nil {
+		cmd.SetContext(context.Background())
+	}
+
+	err := cmd.ParseFlags(args)
+	if err != nil {
+		err = cmd.FlagErrorFunc()(cmd, err)
+	}
+	if err == nil {
+		err = cmd.ValidateArgs(cmd.Flags().Args())
+	}
+	if err == nil {
+		err = cmd.ValidateRequiredFlags()
+	}
+	if err == nil {
+		err = cmd.RunE(cmd, cmd.Flags().Args())
+	}
+	if err == nil {
+		return 0, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	opts := commandOptions(cmd)
+	opts.Out, opts.Err = &stdout, &stderr
+	return exitCodeFromError(writeCLIError(opts, err)), err
+}
+
+func resetFlags(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
+
+func TestCommandExitCodesByCategory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalid input", func(t *testing.T) {
+		useTestDatabase(t)
+		code, err := runCommand(t, newQueueListCmd(), "--status", "bogus")
+		if code != ExitCodeInvalidInput {
+			t.Fatalf("expected exit %d, got %d (err=%v)", ExitCodeInvalidInput, code, err)
+		}
+
+		code, err = runCommand(t, newQueueListCmd(), "--bogus")
+		if code != ExitCodeInvalidInput {
+			t.Fatalf("expected exit %d for unknown flag, got %d (err=%v)", ExitCodeInvalidInput, code, err)
+		}
+
+		code, err = runCommand(t, newAgentStatusCmd())
+		if code != ExitCodeInvalidInput {
+			t.Fatalf("expected exit %d for missing arg, got %d (err=%v)", ExitCodeInvalidInput, code, err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		useTestDatabase(t)
+		code, err := runCommand(t, newAgentStatusCmd(), "missing")
+		if code != ExitCodeNotFound {
+			t.Fatalf("expected exit %d, got %d (err=%v)", ExitCodeNotFound, code, err)
+		}
+
+		code, err = runCommand(t, newQueueListCmd(), "--agent", "missing")
+		if code != ExitCodeNotFound {
+			t.Fatalf("expected exit %d, got %d (err=%v)", ExitCodeNotFound, code, err)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		database := useTestDatabase(t)
+		account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "work", CredentialRef: "env:ANTHROPIC_API_KEY"}
+		if err := db.NewAccountRepository(database).Create(ctx, account); err != nil {
+			t.Fatalf("create account: %v", err)
+		}
+
+		code, err := runCommand(t, newAccountsAddCmd(), "--provider", "anthropic", "--profile", "work")
+		if code != ExitCodeConflict {
+			t.Fatalf("expected exit %d, got %d (err=%v)", ExitCodeConflict, code, err)
+		}
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		setDatabaseOpener(t, func(bool) (*db.DB, error) {
+			return nil, errors.New("unable to open database file")
+		})
+		code, err := runCommand(t, newAgentListCmd())
+		if code != ExitCodeUnavailable {
+			t.Fatalf("expected exit %d, got %d (err=%v)", ExitCodeUnavailable, code, err)
+		}
+	})
+
+	t.Run("internal", func(t *testing.T) {
+		database := useTestDatabase(t)
+		agent := seedQueueAgent(t, database)
+		if _, err := database.ExecContext(ctx, "DROP TABLE queue_items"); err != nil {
+			t.Fatalf("drop table: %v", err)
+		}
+
+		code, err := runCommand(t, newQueueListCmd(), "--agent", agent.ID)
+		if code != ExitCodeInternal {
+			t.Fatalf("expected exit %d, got %d (err=%v)", ExitCodeInternal, code, err)
+		}
+	})
+}
+
+func seedQueueAgent(t *testing.T, database *db.DB) *models.Agent {
+	t.Helper()
+	ctx := context.Background()
+
+	n := &models.Node{Name: "local", IsLocal: true, SSHBackend: models.SSHBackendAuto, Status: models.NodeStatusOnline}
+	if err := db.NewNodeRepository(database).Create(ctx, n); err != nil {
+		t.Fatalf("create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: n.ID, RepoPath: "/repo", TmuxSession: "swarm-repo"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	agent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-repo:0.1", State: models.AgentStateIdle}
+	if err := db.NewAgentRepository(database).Create(ctx, agent); err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	return agent
+}
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "repository sentinel", err: fmt.Errorf("lookup: %w", db.ErrAgentNotFound), want: ErrNotFound},
+		{name: "wrapped conflict sentinel", err: wrapServiceError(db.ErrWorkspaceAlreadyExists, "failed to create workspace"), want: ErrConflict},
+		{name: "unknown service error", err: wrapServiceError(errors.New("disk I/O error"), "failed to list agents"), want: ErrInternal},
+		{name: "grpc not found", err: status.Error(codes.NotFound, "agent not found"), want: ErrNotFound},
+		{name: "grpc unavailable", err: status.Error(codes.Unavailable, "connection refused"), want: ErrUnavailable},
+		{name: "grpc invalid argument", err: status.Error(codes.InvalidArgument, "bad pane"), want: ErrInvalidInput},
+		{name: "explicit category wins", err: withCategory(ErrConflict, invalidInputError("bad")), want: ErrInvalidInput},
+		{name: "unclassified", err: errors.New("boom"), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCategory(tt.err); got != tt.want {
+				t.Fatalf("errorCategory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONErrorsWritesEnvelopeToStderr(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := writeCLIError(Options{JSONErrors: true, Out: &stdout, Err: &stderr}, notFoundError("agent 'abc' not found"))
+	if exitCodeFromError(err) != ExitCodeNotFound {
+		t.Fatalf("expected exit %d, got %d", ExitCodeNotFound, exitCodeFromError(err))
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("expected nothing on stdout, got %q", stdout.String())
+	}
+	if strings.Count(stderr.String(), "\n") != 1 {
+		t.Fatalf("expected a single JSON line, got %q", stderr.String())
+	}
+
+	var envelope ErrorEnvelope
+	if err := json.Unmarshal(stderr.Bytes(), &envelope); err != nil {
+		t.Fatalf("invalid JSON on stderr: %v", err)
+	}
+	got := envelope.Error
+	if got.Code != "ERR_NOT_FOUND" || got.Category != "not_found" || got.ExitCode != ExitCodeNotFound || got.Message != "agent 'abc' not found" {
+		t.Fatalf("unexpected error payload %+v", got)
+	}
+	if got.Details["resource"] != "agent" || got.Details["id"] != "abc" {
+		t.Fatalf("expected resource details, got %+v", got.Details)
+	}
+}
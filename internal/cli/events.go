@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+	eventsCmd.AddCommand(eventsRepublishCmd)
+}
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Manage the event log",
+	Long: `Manage the event log. To read events, use swarm watch or swarm export
+events.`,
+}
+
+// eventsRepublishResult is the JSON output of swarm events republish.
+type eventsRepublishResult struct {
+	Since         time.Time `json:"since"`
+	Requeued      int64     `json:"requeued"`
+	BridgeEnabled bool      `json:"bridge_enabled"`
+}
+
+var eventsRepublishCmd = &cobra.Command{
+	Use:   "republish",
+	Short: "Publish past events to the event bridge again",
+	Long: `Queue the events recorded since --since (default: the last hour) for the
+event bridge to publish again, whether or not they were published before.
+Use it after a message queue outage outlasted the consumers' retention, or
+to backfill a new consumer.
+
+swarmd publishes the queued events on its next outbox check. Consumers
+should deduplicate on the event ID.`,
+	Example: `  swarm events republish --since 30m
+  swarm events republish --since 2026-01-15T09:00:00Z`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		since, err := GetSinceTime()
+		if err != nil {
+			return invalidInputError("invalid --since value: %v", err)
+		}
+		if since == nil {
+			hourAgo := time.Now().UTC().Add(-time.Hour)
+			since = &hourAgo
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		count, err := db.NewEventRepository(database).Requeue(ctx, *since)
+		if err != nil {
+			return wrapServiceError(err, "failed to requeue events")
+		}
+
+		enabled := appConfig != nil && appConfig.EventBridge.Enabled
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, eventsRepublishResult{Since: *since, Requeued: count, BridgeEnabled: enabled})
+		}
+		if !enabled {
+			fmt.Fprintln(os.Stderr, "Warning: event_bridge is not enabled; the events wait until it is.")
+		}
+		fmt.Printf("✓ Queued %d events since %s for publishing\n", count, since.Local().Format(time.RFC3339))
+		return nil
+	},
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestEventsRepublishRequeuesSince(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	repo := db.NewEventRepository(database)
+
+	old := &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: "agent-1", Timestamp: time.Now().UTC().Add(-2 * time.Hour)}
+	recent := &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: "agent-2"}
+	for _, event := range []*models.Event{old, recent} {
+		if err := repo.Create(ctx, event); err != nil {
+			t.Fatalf("create event: %v", err)
+		}
+	}
+
+	var result eventsRepublishResult
+	out := runJSONCommand(t, eventsRepublishCmd, "--since", "90m")
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("decode output %q: %v", out, err)
+	}
+	if result.Requeued != 1 || result.BridgeEnabled {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	pending, err := repo.ListUnpublished(ctx, 10)
+	if err != nil {
+		t.Fatalf("list unpublished: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != recent.ID {
+		t.Fatalf("expected only the recent event queued, got %+v", pending)
+	}
+
+	if code, _ := runCommand(t, eventsRepublishCmd, "--since", "yesterday-ish"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d for a bad --since, got %d", ExitCodeInvalidInput, code)
+	}
+}
@@ -31,7 +31,7 @@ Examples:
   swarm explain               # Explain context agent`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -212,6 +212,16 @@ func buildAgentExplanation(agent *models.Agent, queueItems []*models.QueueItem)
 			fmt.Sprintf("Check agent status: swarm agent status %s", shortID(agent.ID)),
 			fmt.Sprintf("Restart agent: swarm agent restart %s", shortID(agent.ID)))
 
+	case models.AgentStateStuck:
+		explanation.BlockReasons = append(explanation.BlockReasons, "agent is working but has produced no output")
+		if agent.StateInfo.Reason != "" {
+			explanation.BlockReasons = append(explanation.BlockReasons, agent.StateInfo.Reason)
+		}
+		explanation.Suggestions = append(explanation.Suggestions,
+			fmt.Sprintf("Check the pane: swarm agent status %s", shortID(agent.ID)),
+			fmt.Sprintf("Interrupt agent: swarm agent interrupt %s", shortID(agent.ID)),
+			fmt.Sprintf("Restart agent: swarm agent restart %s", shortID(agent.ID)))
+
 	case models.AgentStatePaused:
 		explanation.BlockReasons = append(explanation.BlockReasons, "agent is paused")
 		if agent.PausedUntil != nil {
@@ -227,6 +237,11 @@ func buildAgentExplanation(agent *models.Agent, queueItems []*models.QueueItem)
 			fmt.Sprintf("Wait for completion: swarm wait --agent %s --until idle", shortID(agent.ID)),
 			fmt.Sprintf("Queue a message: swarm send %s \"your message\"", shortID(agent.ID)))
 
+	case models.AgentStateHibernated:
+		explanation.Suggestions = append(explanation.Suggestions,
+			fmt.Sprintf("Wake agent: swarm agent wake %s", shortID(agent.ID)),
+			"Queue a message - the scheduler wakes the agent to dispatch it")
+
 	case models.AgentStateIdle:
 		if explanation.QueueStatus.PendingItems > 0 {
 			explanation.Suggestions = append(explanation.Suggestions,
@@ -353,6 +368,16 @@ func buildQueueItemExplanation(item *models.QueueItem, agent *models.Agent) *Que
 			explanation.Condition = &payload
 			explanation.Content = truncateString(payload.Message, 100)
 		}
+	case models.QueueItemTypeCommand:
+		var payload models.CommandPayload
+		if err := json.Unmarshal(item.Payload, &payload); err == nil {
+			explanation.Content = truncateString("$ "+payload.Command, 100)
+		}
+	case models.QueueItemTypeReview:
+		var payload models.ReviewPayload
+		if err := json.Unmarshal(item.Payload, &payload); err == nil {
+			explanation.Content = truncateString("review "+payload.Source(), 100)
+		}
 	}
 
 	// Determine if blocked and why
@@ -383,7 +408,7 @@ func buildQueueItemExplanation(item *models.QueueItem, agent *models.Agent) *Que
 			explanation.Suggestions = append(explanation.Suggestions,
 				fmt.Sprintf("Resume agent: swarm agent resume %s", shortID(agent.ID)))
 
-		case models.AgentStateError, models.AgentStateStopped:
+		case models.AgentStateError, models.AgentStateStopped, models.AgentStateStuck:
 			explanation.IsBlocked = true
 			explanation.BlockReasons = append(explanation.BlockReasons,
 				fmt.Sprintf("agent is in %s state", agent.State))
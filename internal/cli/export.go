@@ -7,7 +7,6 @@ import (
 	"os"
 	"strings"
 	"text/tabwriter"
-	"time"
 
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/models"
@@ -23,6 +22,14 @@ func init() {
 	exportEventsCmd.Flags().StringVar(&exportEventsTypes, "type", "", "filter by event type (comma-separated)")
 	exportEventsCmd.Flags().StringVar(&exportEventsUntil, "until", "", "filter events before a time (same format as --since)")
 	exportEventsCmd.Flags().StringVar(&exportEventsAgent, "agent", "", "filter by agent ID")
+	exportEventsCmd.Flags().StringVar(&exportEventsTrace, "trace", "", "filter by trace ID")
+
+	exportCmd.AddCommand(exportUsageCmd)
+	exportUsageCmd.Flags().StringVar(&exportUsageAccount, "account", "", "filter by account ID")
+	exportUsageCmd.Flags().StringVar(&exportUsageAgent, "agent", "", "filter by agent ID")
+	exportUsageCmd.Flags().StringVar(&exportUsageProvider, "provider", "", "filter by provider (anthropic, openai, google, custom)")
+	exportUsageCmd.Flags().StringVar(&exportUsageModel, "model", "", "filter by model")
+	exportUsageCmd.Flags().StringVar(&exportUsageUntil, "until", "", "filter records before a time (same format as --since)")
 }
 
 var exportCmd = &cobra.Command{
@@ -36,7 +43,7 @@ var exportStatusCmd = &cobra.Command{
 	Short: "Export full status",
 	Long:  "Export full status as JSON: nodes, workspaces, agents, queues, alerts.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -72,6 +79,7 @@ var (
 	exportEventsTypes string
 	exportEventsUntil string
 	exportEventsAgent string
+	exportEventsTrace string
 )
 
 const exportEventsPageSize = 500
@@ -79,13 +87,18 @@ const exportEventsPageSize = 500
 var exportEventsCmd = &cobra.Command{
 	Use:   "events",
 	Short: "Export events",
-	Long:  "Export the event log as JSON or JSONL, optionally filtered by type, time range, or agent.",
+	Long: `Export the event log as JSON or JSONL, optionally filtered by type, time range, agent, or trace.
+
+With --watch the log is streamed as JSONL. Add --format pretty for colored
+one-line summaries, or --format template='{{.Type}} {{.EntityID}}' for a
+custom line per event.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := MustBeJSONLForWatch(); err != nil {
+		watchOut, onStatus, err := watchEventOutput(os.Stdout, os.Stderr)
+		if err != nil {
 			return err
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -101,6 +114,7 @@ var exportEventsCmd = &cobra.Command{
 		}
 
 		agentID := strings.TrimSpace(exportEventsAgent)
+		traceID := strings.TrimSpace(exportEventsTrace)
 		var entityTypes []models.EntityType
 		if agentID != "" {
 			entityTypes = []models.EntityType{models.EntityTypeAgent}
@@ -123,24 +137,52 @@ var exportEventsCmd = &cobra.Command{
 			if until != nil {
 				return fmt.Errorf("--until cannot be used with --watch")
 			}
-			return StreamEventsWithReplay(ctx, eventRepo, os.Stdout, since, eventTypes, entityTypes, agentID)
+			config := DefaultStreamConfig()
+			config.EventTypes = eventTypes
+			config.EntityTypes = entityTypes
+			config.EntityID = agentID
+			config.TraceID = traceID
+			if onStatus != nil {
+				config.Reconnect.OnStatusChange = onStatus
+			}
+			if since != nil {
+				config.Since = since
+				config.IncludeExisting = true
+			}
+			return NewEventStreamer(eventRepo, watchOut, config).Stream(ctx)
 		}
 
-		if IsJSONLOutput() {
-			return streamExportEvents(ctx, eventRepo, since, until, eventTypes, entityTypes, agentID)
+		query := db.EventQuery{Since: since, Until: until, TraceID: traceID}
+		if len(eventTypes) == 1 {
+			eventType := eventTypes[0]
+			query.Type = &eventType
 		}
+		if len(entityTypes) == 1 {
+			entityType := entityTypes[0]
+			query.EntityType = &entityType
+			if agentID != "" {
+				query.EntityID = &agentID
+			}
+		}
+		events := newEventRows(eventRepo.Iterate(ctx, query, exportEventsPageSize), eventTypes)
 
-		events, err := collectExportEvents(ctx, eventRepo, since, until, eventTypes, entityTypes, agentID)
-		if err != nil {
-			return err
+		if IsJSONOutput() || IsJSONLOutput() {
+			if err := WriteOutputStream(os.Stdout, events); err != nil {
+				return fmt.Errorf("failed to export events: %w", err)
+			}
+			return nil
 		}
 
-		if IsJSONOutput() {
-			return WriteOutput(os.Stdout, events)
+		count := 0
+		for events.Next() {
+			count++
+		}
+		if err := events.Err(); err != nil {
+			return fmt.Errorf("failed to query events: %w", err)
 		}
 
 		writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
-		fmt.Fprintf(writer, "Events:\t%d\n", len(events))
+		fmt.Fprintf(writer, "Events:\t%d\n", count)
 		if err := writer.Flush(); err != nil {
 			return err
 		}
@@ -150,93 +192,131 @@ var exportEventsCmd = &cobra.Command{
 	},
 }
 
-func streamExportEvents(
-	ctx context.Context,
-	repo *db.EventRepository,
-	since *time.Time,
-	until *time.Time,
-	eventTypes []models.EventType,
-	entityTypes []models.EntityType,
-	entityID string,
-) error {
-	return exportEventsPaginated(ctx, repo, since, until, eventTypes, entityTypes, entityID, func(events []*models.Event) error {
-		if len(events) == 0 {
-			return nil
+// eventRows adapts an event iterator to RowIterator, applying the
+// multi-type filter the query itself cannot express.
+type eventRows struct {
+	it      *db.EventIterator
+	allowed map[models.EventType]struct{}
+}
+
+func newEventRows(it *db.EventIterator, eventTypes []models.EventType) *eventRows {
+	rows := &eventRows{it: it}
+	if len(eventTypes) > 1 {
+		rows.allowed = make(map[models.EventType]struct{}, len(eventTypes))
+		for _, t := range eventTypes {
+			rows.allowed[t] = struct{}{}
 		}
-		return WriteOutput(os.Stdout, events)
-	})
+	}
+	return rows
 }
 
-func collectExportEvents(
-	ctx context.Context,
-	repo *db.EventRepository,
-	since *time.Time,
-	until *time.Time,
-	eventTypes []models.EventType,
-	entityTypes []models.EntityType,
-	entityID string,
-) ([]*models.Event, error) {
-	var collected []*models.Event
-	err := exportEventsPaginated(ctx, repo, since, until, eventTypes, entityTypes, entityID, func(events []*models.Event) error {
-		if len(events) == 0 {
-			return nil
+func (r *eventRows) Next() bool {
+	for r.it.Next() {
+		if r.allowed == nil {
+			return true
 		}
-		collected = append(collected, events...)
-		return nil
-	})
-	return collected, err
+		if _, ok := r.allowed[r.it.Event().Type]; ok {
+			return true
+		}
+	}
+	return false
 }
 
-func exportEventsPaginated(
-	ctx context.Context,
-	repo *db.EventRepository,
-	since *time.Time,
-	until *time.Time,
-	eventTypes []models.EventType,
-	entityTypes []models.EntityType,
-	entityID string,
-	handle func([]*models.Event) error,
-) error {
-	var cursor string
-	for {
-		query := db.EventQuery{
-			Cursor: cursor,
-			Since:  since,
-			Until:  until,
-			Limit:  exportEventsPageSize,
+func (r *eventRows) Row() any   { return r.it.Event() }
+func (r *eventRows) Err() error { return r.it.Err() }
+
+var (
+	exportUsageAccount  string
+	exportUsageAgent    string
+	exportUsageProvider string
+	exportUsageModel    string
+	exportUsageUntil    string
+)
+
+var exportUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Export usage records",
+	Long:  "Export recorded token usage as JSON or JSONL, newest first, optionally filtered by account, agent, provider, model, or time range.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		query := models.UsageQuery{}
+		if value := strings.TrimSpace(exportUsageAccount); value != "" {
+			query.AccountID = &value
 		}
-		if len(eventTypes) == 1 {
-			eventType := eventTypes[0]
-			query.Type = &eventType
+		if value := strings.TrimSpace(exportUsageAgent); value != "" {
+			query.AgentID = &value
 		}
-		if len(entityTypes) == 1 {
-			entityType := entityTypes[0]
-			query.EntityType = &entityType
-			if strings.TrimSpace(entityID) != "" {
-				query.EntityID = &entityID
+		if value := strings.TrimSpace(exportUsageModel); value != "" {
+			query.Model = &value
+		}
+		if strings.TrimSpace(exportUsageProvider) != "" {
+			provider, err := models.ParseProvider(exportUsageProvider)
+			if err != nil {
+				return err
 			}
+			query.Provider = &provider
 		}
 
-		page, err := repo.Query(ctx, query)
+		since, err := GetSinceTime()
 		if err != nil {
-			return fmt.Errorf("failed to query events: %w", err)
+			return invalidInputError("invalid --since value: %v", err)
 		}
+		until, err := ParseSince(exportUsageUntil)
+		if err != nil {
+			return invalidInputError("invalid --until value: %v", err)
+		}
+		if since != nil && until != nil && since.After(*until) {
+			return invalidInputError("--since must be before --until")
+		}
+		query.Since, query.Until = since, until
 
-		events := filterEventsByType(page.Events, eventTypes)
-		if err := handle(events); err != nil {
+		database, err := openDatabase()
+		if err != nil {
 			return err
 		}
+		defer database.Close()
+
+		records := &usageRows{it: db.NewUsageRepository(database).Iterate(ctx, query, exportEventsPageSize)}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			if err := WriteOutputStream(os.Stdout, records); err != nil {
+				return fmt.Errorf("failed to export usage: %w", err)
+			}
+			return nil
+		}
 
-		if page.NextCursor == "" {
-			break
+		count := 0
+		var tokens int64
+		for records.Next() {
+			count++
+			tokens += records.it.Record().TotalTokens
+		}
+		if err := records.Err(); err != nil {
+			return fmt.Errorf("failed to query usage records: %w", err)
 		}
-		cursor = page.NextCursor
-		since = nil
-	}
 
-	return nil
+		writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintf(writer, "Usage records:\t%d\n", count)
+		fmt.Fprintf(writer, "Total tokens:\t%d\n", tokens)
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Println("Use --json or --jsonl for full export output.")
+		return nil
+	},
+}
+
+// usageRows adapts a usage iterator to RowIterator.
+type usageRows struct {
+	it *db.UsageIterator
 }
 
+func (r *usageRows) Next() bool { return r.it.Next() }
+func (r *usageRows) Row() any   { return r.it.Record() }
+func (r *usageRows) Err() error { return r.it.Err() }
+
 func parseEventTypes(raw string) ([]models.EventType, error) {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
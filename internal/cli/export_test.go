@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestExportUsageCommand(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+
+	account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "primary"}
+	if err := db.NewAccountRepository(database).Create(ctx, account); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	record := &models.UsageRecord{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 10, RecordedAt: time.Now().UTC()}
+	if err := db.NewUsageRepository(database).Create(ctx, record); err != nil {
+		t.Fatalf("failed to create usage record: %v", err)
+	}
+
+	if code, err := runCommand(t, exportUsageCmd, "--account", account.ID, "--provider", "anthropic"); code != 0 {
+		t.Fatalf("export usage failed with exit %d: %v", code, err)
+	}
+	if code, _ := runCommand(t, exportUsageCmd, "--provider", "bogus"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d for bad provider, got %d", ExitCodeInvalidInput, code)
+	}
+	if code, _ := runCommand(t, exportEventsCmd, "--type", "agent.spawned,agent.stuck"); code != 0 {
+		t.Fatalf("export events failed with exit %d", code)
+	}
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+)
+
+// newFailureClassifier builds the failure classifier from the configured
+// agent_defaults.failure_rules followed by the built-in rules.
+func newFailureClassifier() *state.FailureClassifier {
+	agentDefaults := config.DefaultConfig().AgentDefaults
+	if cfg := GetConfig(); cfg != nil {
+		agentDefaults = cfg.AgentDefaults
+	}
+
+	rules := make([]state.FailureRule, 0, len(agentDefaults.FailureRules))
+	for _, r := range agentDefaults.FailureRules {
+		rule, err := state.NewFailureRule(
+			models.AgentType(r.AgentType),
+			r.Pattern,
+			models.FailureCategory(r.Category),
+			models.FailureSeverity(r.Severity),
+			models.FailureAction(r.Action),
+			r.Summary,
+		)
+		if err != nil {
+			// Config validation rejects these; skip rather than fail the UI.
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return state.NewFailureClassifier(rules...)
+}
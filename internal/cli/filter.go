@@ -0,0 +1,532 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// filterKind is how a field's values are compared.
+type filterKind int
+
+const (
+	filterText filterKind = iota
+	filterNumber
+	filterAge
+)
+
+// filterFields maps the fields a list can be filtered on to their kinds.
+type filterFields map[string]filterKind
+
+func (f filterFields) names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// filterRecord holds the field values of one listed item.
+type filterRecord struct {
+	text    map[string][]string
+	numbers map[string]float64
+	times   map[string]time.Time
+}
+
+func newFilterRecord() filterRecord {
+	return filterRecord{
+		text:    make(map[string][]string),
+		numbers: make(map[string]float64),
+		times:   make(map[string]time.Time),
+	}
+}
+
+// setText sets a text field, dropping empty values.
+func (r filterRecord) setText(field string, values ...string) {
+	for _, value := range values {
+		if value != "" {
+			r.text[field] = append(r.text[field], value)
+		}
+	}
+}
+
+// setTime sets an age field from a timestamp, leaving it unset for nil.
+func (r filterRecord) setTime(field string, t *time.Time) {
+	if t != nil {
+		r.times[field] = *t
+	}
+}
+
+// filterError is a --filter expression that does not parse. Column is the
+// 1-based position of the offending token.
+type filterError struct {
+	Column  int
+	Message string
+}
+
+func (e *filterError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+// listFilter is a parsed --filter expression, a small boolean expression
+// over the fields of listed items:
+//
+//	state=idle and queue>0 and age>2h
+//	state in [idle, blocked] or not tag=backend
+//
+// A comparison is a field, an operator, and a value: = (or ==), !=, <, <=,
+// >, >=, or in / not in with a bracketed list. Comparisons combine with
+// and, or, and not (&&, ||, and ! also work), in that order of precedence
+// from loosest to tightest, and group with parentheses. Values are bare
+// words or quoted strings.
+//
+// How a value is compared depends on its field's kind. Text fields compare
+// case-insensitively with = and !=; a field with several values, such as
+// tag, matches = when any of them does. Number fields take every operator.
+// Age fields hold the time since a timestamp and compare with durations
+// (30s, 2h, 1.5d) using <, <=, > and >=; they never match when the
+// timestamp is unset.
+//
+// A nil filter matches everything.
+type listFilter struct {
+	root filterNode
+}
+
+// parseListFilter parses expr against the fields of a list. An empty
+// expression gives a nil filter.
+func parseListFilter(expr string, fields filterFields) (*listFilter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens, fields: fields}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+	return &listFilter{root: root}, nil
+}
+
+// Match reports whether the record passes the filter.
+func (f *listFilter) Match(r filterRecord, now time.Time) bool {
+	if f == nil {
+		return true
+	}
+	return f.root.match(r, now)
+}
+
+// Pushdown returns the value a top-level conjunct requires text field to
+// equal, so the list can be narrowed before it is fetched. The filter is
+// still applied afterwards, so pushing down never changes the result.
+func (f *listFilter) Pushdown(field string) (string, bool) {
+	if f == nil {
+		return "", false
+	}
+	for _, node := range conjuncts(f.root) {
+		cmp, ok := node.(*filterCompare)
+		if !ok || cmp.field != field || cmp.kind != filterText || len(cmp.values) != 1 {
+			continue
+		}
+		if cmp.op == "=" || cmp.op == "in" {
+			return cmp.values[0], true
+		}
+	}
+	return "", false
+}
+
+// conjuncts flattens the and-chain at the top of an expression.
+func conjuncts(node filterNode) []filterNode {
+	if and, ok := node.(*filterBinary); ok && and.op == "and" {
+		return append(conjuncts(and.left), conjuncts(and.right)...)
+	}
+	return []filterNode{node}
+}
+
+// filterNode is a node of a parsed filter expression.
+type filterNode interface {
+	match(r filterRecord, now time.Time) bool
+}
+
+// filterBinary is an and or an or of two expressions.
+type filterBinary struct {
+	op          string
+	left, right filterNode
+}
+
+func (b *filterBinary) match(r filterRecord, now time.Time) bool {
+	if b.op == "and" {
+		return b.left.match(r, now) && b.right.match(r, now)
+	}
+	return b.left.match(r, now) || b.right.match(r, now)
+}
+
+// filterNot negates an expression.
+type filterNot struct {
+	expr filterNode
+}
+
+func (n *filterNot) match(r filterRecord, now time.Time) bool {
+	return !n.expr.match(r, now)
+}
+
+// filterCompare compares a field against one value or, for in and not in,
+// a list of values. Values are parsed for the field's kind.
+type filterCompare struct {
+	field     string
+	kind      filterKind
+	op        string
+	values    []string
+	numbers   []float64
+	durations []time.Duration
+}
+
+func (c *filterCompare) match(r filterRecord, now time.Time) bool {
+	switch c.kind {
+	case filterNumber:
+		current := r.numbers[c.field]
+		if c.op == "in" || c.op == "not in" {
+			return slices.Contains(c.numbers, current) == (c.op == "in")
+		}
+		return compareFilterValues(current, c.op, c.numbers[0])
+	case filterAge:
+		t, ok := r.times[c.field]
+		if !ok || t.IsZero() {
+			return false
+		}
+		return compareFilterValues(float64(now.Sub(t)), c.op, float64(c.durations[0]))
+	default:
+		matched := false
+		for _, have := range r.text[c.field] {
+			for _, want := range c.values {
+				if strings.EqualFold(have, want) {
+					matched = true
+				}
+			}
+		}
+		if c.op == "!=" || c.op == "not in" {
+			return !matched
+		}
+		return matched
+	}
+}
+
+func compareFilterValues(current float64, op string, target float64) bool {
+	switch op {
+	case "=":
+		return current == target
+	case "!=":
+		return current != target
+	case "<":
+		return current < target
+	case "<=":
+		return current <= target
+	case ">":
+		return current > target
+	default:
+		return current >= target
+	}
+}
+
+// filterOperators are the comparison operators each kind accepts.
+var filterOperators = map[filterKind][]string{
+	filterText:   {"=", "!=", "in", "not in"},
+	filterNumber: {"=", "!=", "<", "<=", ">", ">=", "in", "not in"},
+	filterAge:    {"<", "<=", ">", ">="},
+}
+
+type filterTokenKind int
+
+const (
+	tokEOF filterTokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+)
+
+type filterToken struct {
+	kind   filterTokenKind
+	text   string
+	column int
+}
+
+func (t filterToken) String() string {
+	if t.kind == tokEOF {
+		return "end of filter"
+	}
+	return strconv.Quote(t.text)
+}
+
+// keyword reports whether the token is the given keyword, ignoring case.
+func (t filterToken) keyword(word string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, word)
+}
+
+// filterSymbols are the characters that end a bare word.
+const filterSymbols = "()[],=!<>&|'\""
+
+func lexFilter(expr string) ([]filterToken, error) {
+	runes := []rune(expr)
+	var tokens []filterToken
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		column := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == '[' || r == ']' || r == ',':
+			kind := map[rune]filterTokenKind{'(': tokLParen, ')': tokRParen, '[': tokLBracket, ']': tokRBracket, ',': tokComma}[r]
+			tokens = append(tokens, filterToken{kind: kind, text: string(r), column: column})
+			i++
+		case r == '\'' || r == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, &filterError{Column: column, Message: "unterminated string"}
+			}
+			tokens = append(tokens, filterToken{kind: tokString, text: b.String(), column: column})
+			i = j + 1
+		case strings.ContainsRune("=!<>&|", r):
+			op := string(r)
+			if i+1 < len(runes) {
+				if pair := op + string(runes[i+1]); slices.Contains([]string{"==", "!=", "<=", ">=", "&&", "||"}, pair) {
+					op = pair
+				}
+			}
+			i += len(op)
+			switch op {
+			case "&", "|":
+				return nil, &filterError{Column: column, Message: fmt.Sprintf("unexpected %q (use %s%s)", op, op, op)}
+			case "==":
+				op = "="
+			}
+			tokens = append(tokens, filterToken{kind: tokOp, text: op, column: column})
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(filterSymbols, runes[j]) {
+				j++
+			}
+			tokens = append(tokens, filterToken{kind: tokWord, text: string(runes[i:j]), column: column})
+			i = j
+		}
+	}
+	return append(tokens, filterToken{kind: tokEOF, column: len(runes) + 1}), nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+	fields filterFields
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *filterParser) errorf(tok filterToken, format string, args ...any) error {
+	return &filterError{Column: tok.column, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.keyword("or") || (tok.kind == tokOp && tok.text == "||"); tok = p.peek() {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterBinary{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.keyword("and") || (tok.kind == tokOp && tok.text == "&&"); tok = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterBinary{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	tok := p.peek()
+	if tok.keyword("not") || (tok.kind == tokOp && tok.text == "!") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterNot{expr: expr}, nil
+	}
+	if tok.kind == tokLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "expected \")\", got %s", closing)
+		}
+		return expr, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *filterParser) parseCompare() (filterNode, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != tokWord || isFilterKeyword(fieldTok.text) {
+		return nil, p.errorf(fieldTok, "expected a field, got %s", fieldTok)
+	}
+	field := strings.ToLower(fieldTok.text)
+	kind, ok := p.fields[field]
+	if !ok {
+		return nil, p.errorf(fieldTok, "unknown field %q (fields: %s)", fieldTok.text, strings.Join(p.fields.names(), ", "))
+	}
+
+	opTok := p.next()
+	var op string
+	switch {
+	case opTok.kind == tokOp && !slices.Contains([]string{"!", "&&", "||"}, opTok.text):
+		op = opTok.text
+	case opTok.keyword("in"):
+		op = "in"
+	case opTok.keyword("not") && p.peek().keyword("in"):
+		p.next()
+		op = "not in"
+	default:
+		return nil, p.errorf(opTok, "expected an operator after %s, got %s", field, opTok)
+	}
+	if !slices.Contains(filterOperators[kind], op) {
+		return nil, p.errorf(opTok, "%s compares with %s, not %s", field, strings.Join(filterOperators[kind], ", "), op)
+	}
+
+	var valueToks []filterToken
+	if op == "in" || op == "not in" {
+		if open := p.next(); open.kind != tokLBracket {
+			return nil, p.errorf(open, "expected \"[\" after %s, got %s", op, open)
+		}
+		for {
+			valueTok, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			valueToks = append(valueToks, valueTok)
+			sep := p.next()
+			if sep.kind == tokRBracket {
+				break
+			}
+			if sep.kind != tokComma {
+				return nil, p.errorf(sep, "expected \",\" or \"]\", got %s", sep)
+			}
+		}
+	} else {
+		valueTok, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		valueToks = []filterToken{valueTok}
+	}
+
+	cmp := &filterCompare{field: field, kind: kind, op: op}
+	for _, tok := range valueToks {
+		switch kind {
+		case filterNumber:
+			n, err := strconv.ParseFloat(tok.text, 64)
+			if err != nil {
+				return nil, p.errorf(tok, "invalid number %q for %s", tok.text, field)
+			}
+			cmp.numbers = append(cmp.numbers, n)
+		case filterAge:
+			d, err := parseFilterDuration(tok.text)
+			if err != nil {
+				return nil, p.errorf(tok, "invalid duration %q for %s", tok.text, field)
+			}
+			cmp.durations = append(cmp.durations, d)
+		}
+		cmp.values = append(cmp.values, tok.text)
+	}
+	return cmp, nil
+}
+
+func (p *filterParser) parseValue() (filterToken, error) {
+	tok := p.next()
+	if tok.kind == tokString || (tok.kind == tokWord && !isFilterKeyword(tok.text)) {
+		return tok, nil
+	}
+	return tok, p.errorf(tok, "expected a value, got %s", tok)
+}
+
+func isFilterKeyword(word string) bool {
+	switch strings.ToLower(word) {
+	case "and", "or", "not", "in":
+		return true
+	}
+	return false
+}
+
+// parseFilterDuration parses a Go duration, also accepting days (1.5d).
+func parseFilterDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// addFilterFlag adds the --filter flag of list commands.
+func addFilterFlag(cmd *cobra.Command, target *string, example string) {
+	cmd.Flags().StringVar(target, "filter", "", fmt.Sprintf("filter expression, e.g. %q", example))
+}
+
+// listFilterFlag parses a --filter value for a list with the given fields.
+func listFilterFlag(expr string, fields filterFields) (*listFilter, error) {
+	filter, err := parseListFilter(expr, fields)
+	if err != nil {
+		return nil, invalidInputError("invalid --filter: %v", err)
+	}
+	return filter, nil
+}
@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+var filterTestNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func filterTestAgents() []*models.Agent {
+	ago := func(d time.Duration) *time.Time {
+		t := filterTestNow.Add(-d)
+		return &t
+	}
+	return []*models.Agent{
+		{ID: "a1", WorkspaceID: "ws-1", Type: models.AgentTypeClaudeCode, State: models.AgentStateIdle, QueueLength: 2,
+			CreatedAt: *ago(3 * time.Hour), LastActivity: ago(time.Hour), Metadata: models.AgentMetadata{Tags: []string{"backend", "db"}}},
+		{ID: "a2", WorkspaceID: "ws-1", Type: models.AgentTypeCodex, State: models.AgentStateIdle,
+			CreatedAt: *ago(30 * time.Minute), LastActivity: ago(time.Minute), AccountID: "acct-1"},
+		{ID: "a3", WorkspaceID: "ws-2", Type: models.AgentTypeCodex, State: models.AgentStateWorking, QueueLength: 5,
+			CreatedAt: *ago(48 * time.Hour), Metadata: models.AgentMetadata{Tags: []string{"frontend"}}},
+		{ID: "a4", WorkspaceID: "ws-2", Type: models.AgentTypeClaudeCode, State: models.AgentStateRateLimited, QueueLength: 1,
+			CreatedAt: *ago(5 * time.Hour), LastActivity: ago(26 * time.Hour)},
+	}
+}
+
+var filterTestWorkspaces = map[string]string{"ws-1": "api", "ws-2": "web"}
+
+// matchAgentIDs returns the IDs of the agents passing expr, joined by
+// spaces.
+func matchAgentIDs(t *testing.T, expr string, agents []*models.Agent) string {
+	t.Helper()
+	filter, err := parseListFilter(expr, agentFilterFields)
+	if err != nil {
+		t.Fatalf("parse %q: %v", expr, err)
+	}
+	var ids []string
+	for _, a := range agents {
+		if filter.Match(agentFilterRecord(a, filterTestWorkspaces[a.WorkspaceID]), filterTestNow) {
+			ids = append(ids, a.ID)
+		}
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestListFilterMatch(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "", want: "a1 a2 a3 a4"},
+		{expr: "state=idle and queue>0 and age>2h", want: "a1"},
+		{expr: "STATE == Idle", want: "a1 a2"},
+		{expr: "state != idle", want: "a3 a4"},
+		{expr: "state in [idle, blocked]", want: "a1 a2 a4"},
+		{expr: "state not in [idle, working]", want: "a4"},
+		{expr: "state=blocked", want: "a4"},
+		{expr: "state=rate_limited", want: "a4"},
+		{expr: "type=codex", want: "a2 a3"},
+		{expr: "workspace=web", want: "a3 a4"},
+		{expr: "workspace=ws-1", want: "a1 a2"},
+		{expr: "tag=db", want: "a1"},
+		{expr: "tag in [frontend, db]", want: "a1 a3"},
+		{expr: "tag != backend", want: "a2 a3 a4"},
+		{expr: "account=acct-1", want: "a2"},
+		{expr: "queue >= 2", want: "a1 a3"},
+		{expr: "queue in [1, 5]", want: "a3 a4"},
+		{expr: "queue=0", want: "a2"},
+		{expr: "age < 1h", want: "a2"},
+		{expr: "age > 1.5d", want: "a3"},
+		{expr: "last_activity > 1d", want: "a4"},
+		// An agent that has never been active matches no last_activity comparison.
+		{expr: "last_activity < 1000d", want: "a1 a2 a4"},
+		{expr: "workspace = 'web' && !(type = \"codex\")", want: "a4"},
+	}
+
+	agents := filterTestAgents()
+	for _, tt := range tests {
+		if got := matchAgentIDs(t, tt.expr, agents); got != tt.want {
+			t.Errorf("%q matched %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestListFilterPrecedence(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		// and binds tighter than or.
+		{expr: "type=codex or state=idle and queue>0", want: "a1 a2 a3"},
+		{expr: "(type=codex or state=idle) and queue>0", want: "a1 a3"},
+		// not binds tighter than and.
+		{expr: "not state=idle and type=codex", want: "a3"},
+		{expr: "not (state=idle and type=codex)", want: "a1 a3 a4"},
+		{expr: "not not type=codex", want: "a2 a3"},
+		// A chain of ors matches any of its terms.
+		{expr: "queue=5 or queue=1 or tag=db", want: "a1 a3 a4"},
+		{expr: "state=idle and (tag=db or account=acct-1) and age<4h", want: "a1 a2"},
+	}
+
+	agents := filterTestAgents()
+	for _, tt := range tests {
+		if got := matchAgentIDs(t, tt.expr, agents); got != tt.want {
+			t.Errorf("%q matched %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestListFilterSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column int
+		want   string
+	}{
+		{expr: "status=idle", column: 1, want: `unknown field "status"`},
+		{expr: "state=idle and queue>", column: 22, want: "expected a value"},
+		{expr: "state>idle", column: 6, want: "state compares with =, !=, in, not in, not >"},
+		{expr: "age=2h", column: 4, want: "age compares with <, <=, >, >=, not ="},
+		{expr: "queue>lots", column: 7, want: "invalid number"},
+		{expr: "age>soon", column: 5, want: "invalid duration"},
+		{expr: "state='idle", column: 7, want: "unterminated string"},
+		{expr: "state in [idle, blocked", column: 24, want: "expected"},
+		{expr: "state in idle", column: 10, want: `expected "[" after in`},
+		{expr: "(state=idle", column: 12, want: `expected ")"`},
+		{expr: "state=idle)", column: 11, want: "unexpected"},
+		{expr: "state=idle type=codex", column: 12, want: "unexpected"},
+		{expr: "state=idle & queue>0", column: 12, want: "&&"},
+		{expr: "state=and", column: 7, want: "expected a value"},
+		{expr: "state idle", column: 7, want: "expected an operator"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseListFilter(tt.expr, agentFilterFields)
+		var ferr *filterError
+		if !errors.As(err, &ferr) {
+			t.Errorf("%q: expected a filter error, got %v", tt.expr, err)
+			continue
+		}
+		if ferr.Column != tt.column || !strings.Contains(ferr.Message, tt.want) {
+			t.Errorf("%q: got %q, want column %d containing %q", tt.expr, err, tt.column, tt.want)
+		}
+	}
+
+	_, err := parseListFilter("status=idle", agentFilterFields)
+	if err == nil || !strings.Contains(err.Error(), "account, age, last_activity") {
+		t.Errorf("expected unknown field errors to list the fields, got %v", err)
+	}
+	if _, err := listFilterFlag("queue>", agentFilterFields); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "invalid --filter: column 7") {
+		t.Errorf("expected an invalid input error with the column, got %v", err)
+	}
+}
+
+func TestListFilterPushdown(t *testing.T) {
+	tests := []struct {
+		expr  string
+		field string
+		want  string
+		ok    bool
+	}{
+		{expr: "state=idle", field: "state", want: "idle", ok: true},
+		{expr: "queue>0 and state=idle and age>2h", field: "state", want: "idle", ok: true},
+		{expr: "state in [working]", field: "state", want: "working", ok: true},
+		{expr: "(workspace=api and queue>0) and type=codex", field: "workspace", want: "api", ok: true},
+		{expr: "state=idle", field: "workspace"},
+		{expr: "state in [idle, working]", field: "state"},
+		{expr: "state!=idle", field: "state"},
+		{expr: "state not in [idle]", field: "state"},
+		{expr: "state=idle or type=codex", field: "state"},
+		{expr: "not state=idle", field: "state"},
+		{expr: "type=codex and (state=idle or queue>0)", field: "state"},
+		{expr: "", field: "state"},
+	}
+
+	for _, tt := range tests {
+		filter, err := parseListFilter(tt.expr, agentFilterFields)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.expr, err)
+		}
+		got, ok := filter.Pushdown(tt.field)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%q pushdown of %s = %q, %v; want %q, %v", tt.expr, tt.field, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestListFilterPushdownEquivalence checks that narrowing the list by a
+// pushed-down value before filtering gives the same agents as filtering
+// the whole list.
+func TestListFilterPushdownEquivalence(t *testing.T) {
+	exprs := []string{
+		"state=idle",
+		"state=IDLE and queue>0",
+		"state in [working] or state=idle",
+		"queue>0 and workspace=web",
+		"workspace=api and state=idle and not tag=backend",
+		"state=idle and (workspace=web or type=codex)",
+		"state=blocked and queue>0",
+	}
+
+	agents := filterTestAgents()
+	for _, expr := range exprs {
+		filter, err := parseListFilter(expr, agentFilterFields)
+		if err != nil {
+			t.Fatalf("parse %q: %v", expr, err)
+		}
+
+		narrowed := agents
+		if state, ok := filter.Pushdown("state"); ok && !strings.EqualFold(state, agentStateBlocked) {
+			narrowed = keepAgents(narrowed, func(a *models.Agent) bool { return strings.EqualFold(string(a.State), state) })
+		}
+		if name, ok := filter.Pushdown("workspace"); ok {
+			narrowed = keepAgents(narrowed, func(a *models.Agent) bool {
+				return a.WorkspaceID == name || filterTestWorkspaces[a.WorkspaceID] == name
+			})
+		}
+
+		if full, pushed := matchAgentIDs(t, expr, agents), matchAgentIDs(t, expr, narrowed); full != pushed {
+			t.Errorf("%q: pushdown changed the result from %q to %q", expr, full, pushed)
+		}
+	}
+}
+
+func keepAgents(agents []*models.Agent, keep func(*models.Agent) bool) []*models.Agent {
+	var kept []*models.Agent
+	for _, a := range agents {
+		if keep(a) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+func TestWorkspaceAndAccountFilterRecords(t *testing.T) {
+	created := filterTestNow.Add(-10 * 24 * time.Hour)
+	ws := &models.Workspace{ID: "ws-1", Name: "api", NodeID: "node-1", Status: models.WorkspaceStatusActive, RepoPath: "/src/api", AgentCount: 0, CreatedAt: created}
+	filter, err := parseListFilter("status=active and agents=0 and age>7d and node=gpu", wsFilterFields)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !filter.Match(wsFilterRecord(ws, "gpu"), filterTestNow) {
+		t.Fatal("expected the workspace to match")
+	}
+	if filter.Match(wsFilterRecord(ws, "local"), filterTestNow) {
+		t.Fatal("expected another node not to match")
+	}
+
+	cooldown := time.Now().Add(time.Hour)
+	account := &models.Account{ID: "acct-1", Provider: models.ProviderAnthropic, ProfileName: "work", IsActive: true, CooldownUntil: &cooldown}
+	filter, err = parseListFilter("provider=anthropic and status!=cooldown", accountFilterFields)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if filter.Match(accountFilterRecord(account), filterTestNow) {
+		t.Fatal("expected the account on cooldown not to match")
+	}
+	account.CooldownUntil = nil
+	if !filter.Match(accountFilterRecord(account), filterTestNow) {
+		t.Fatal("expected the active account to match")
+	}
+}
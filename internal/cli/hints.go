@@ -1,10 +1,7 @@
 // Package cli provides actionable next-step hints for CLI commands.
 package cli
 
-import (
-	"fmt"
-	"os"
-)
+import "fmt"
 
 // HintContext provides context for generating relevant next steps.
 type HintContext struct {
@@ -36,7 +33,11 @@ type HintContext struct {
 // PrintNextSteps prints contextual next steps after a successful command.
 // Does nothing if JSON output is enabled.
 func PrintNextSteps(ctx HintContext) {
-	if IsJSONOutput() || IsJSONLOutput() {
+	rootOptions().printNextSteps(ctx)
+}
+
+func (o Options) printNextSteps(ctx HintContext) {
+	if o.Structured() {
 		return
 	}
 
@@ -45,10 +46,11 @@ func PrintNextSteps(ctx HintContext) {
 		return
 	}
 
-	fmt.Fprintln(os.Stdout)
-	fmt.Fprintln(os.Stdout, "Next steps:")
+	out := o.stdout()
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Next steps:")
 	for _, hint := range hints {
-		fmt.Fprintf(os.Stdout, "  %s\n", hint)
+		fmt.Fprintf(out, "  %s\n", hint)
 	}
 }
 
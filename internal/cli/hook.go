@@ -17,6 +17,7 @@ var (
 	hookCommand  string
 	hookURL      string
 	hookHeaders  []string
+	hookTemplate string
 	hookTypes    string
 	hookEntity   string
 	hookEntityID string
@@ -31,6 +32,7 @@ func init() {
 	hookOnEventCmd.Flags().StringVar(&hookCommand, "cmd", "", "command to execute for matching events")
 	hookOnEventCmd.Flags().StringVar(&hookURL, "url", "", "webhook URL to POST matching events")
 	hookOnEventCmd.Flags().StringSliceVar(&hookHeaders, "header", nil, "webhook header (key=value)")
+	hookOnEventCmd.Flags().StringVar(&hookTemplate, "template", "", "Go text/template for the webhook body, over the event JSON")
 	hookOnEventCmd.Flags().StringVar(&hookTypes, "type", "", "filter by event type (comma-separated)")
 	hookOnEventCmd.Flags().StringVar(&hookEntity, "entity-type", "", "filter by entity type (node, workspace, agent, queue, account, system)")
 	hookOnEventCmd.Flags().StringVar(&hookEntityID, "entity-id", "", "filter by entity ID")
@@ -58,6 +60,14 @@ that publish events.`,
 		if (command == "") == (url == "") {
 			return fmt.Errorf("exactly one of --cmd or --url is required")
 		}
+		if hookTemplate != "" {
+			if url == "" {
+				return fmt.Errorf("--template requires --url")
+			}
+			if _, err := hooks.ParseTemplate(hookTemplate); err != nil {
+				return err
+			}
+		}
 
 		if hookTimeout != "" {
 			if hookTimeout != "0" {
@@ -87,6 +97,7 @@ that publish events.`,
 			Command:     command,
 			URL:         url,
 			Headers:     headers,
+			Template:    hookTemplate,
 			EventTypes:  eventTypes,
 			EntityTypes: entityTypes,
 			EntityID:    strings.TrimSpace(hookEntityID),
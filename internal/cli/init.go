@@ -250,13 +250,13 @@ func runMigrations() initResult {
 		return result
 	}
 
-	if applied == 0 {
+	if len(applied) == 0 {
 		result.status = "skipped"
 		result.message = fmt.Sprintf("already at version %d", version)
 	} else {
 		newVersion, _ := database.SchemaVersion(ctx)
 		result.status = "done"
-		result.message = fmt.Sprintf("applied %d migration(s), now at version %d", applied, newVersion)
+		result.message = fmt.Sprintf("applied %d migration(s), now at version %d", len(applied), newVersion)
 	}
 
 	return result
@@ -2,7 +2,6 @@
 package cli
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -63,7 +62,7 @@ Non-idle agents require confirmation (use --force to skip).`,
   swarm inject abc123 --editor`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 		agentID := args[0]
 
 		// Resolve message - use inject-specific flags
@@ -132,7 +131,7 @@ Non-idle agents require confirmation (use --force to skip).`,
 // isAgentReadyForInject returns true if the agent is in a state that can safely receive input.
 func isAgentReadyForInject(a *models.Agent) bool {
 	switch a.State {
-	case models.AgentStateIdle, models.AgentStateStopped, models.AgentStateStarting:
+	case models.AgentStateIdle, models.AgentStateStopped, models.AgentStateStarting, models.AgentStateHibernated:
 		return true
 	default:
 		return false
@@ -161,6 +160,9 @@ func confirmInject(a *models.Agent) bool {
 	case models.AgentStateError:
 		fmt.Println("  The agent is in an error state.")
 		fmt.Println("  Consider using 'swarm agent restart' first.")
+	case models.AgentStateStuck:
+		fmt.Println("  The agent has produced no output for a while and may be hung.")
+		fmt.Println("  Consider using 'swarm agent interrupt' or 'swarm agent restart' first.")
 	}
 
 	fmt.Println()
@@ -10,7 +10,12 @@ import (
 
 // IsNonInteractive reports whether prompts should be skipped and defaults used.
 func IsNonInteractive() bool {
-	if nonInteractive {
+	return rootOptions().IsNonInteractive()
+}
+
+// IsNonInteractive reports whether prompts should be skipped and defaults used.
+func (o Options) IsNonInteractive() bool {
+	if o.NonInteractive {
 		return true
 	}
 	if _, ok := os.LookupEnv("SWARM_NON_INTERACTIVE"); ok {
@@ -21,20 +26,30 @@ func IsNonInteractive() bool {
 
 // IsInteractive reports whether the session can prompt for user input.
 func IsInteractive() bool {
-	return !IsNonInteractive()
+	return rootOptions().IsInteractive()
+}
+
+// IsInteractive reports whether the session can prompt for user input.
+func (o Options) IsInteractive() bool {
+	return !o.IsNonInteractive()
 }
 
 // SkipConfirmation reports whether confirmation prompts should be skipped.
 // Returns true if --yes, --non-interactive, --json, or --jsonl is set,
 // or if there's no TTY.
 func SkipConfirmation() bool {
-	if yesFlag {
+	return rootOptions().SkipConfirmation()
+}
+
+// SkipConfirmation reports whether confirmation prompts should be skipped.
+func (o Options) SkipConfirmation() bool {
+	if o.Yes {
 		return true
 	}
-	if IsNonInteractive() {
+	if o.IsNonInteractive() {
 		return true
 	}
-	if IsJSONOutput() || IsJSONLOutput() {
+	if o.Structured() {
 		return true
 	}
 	return false
@@ -51,15 +66,21 @@ func SkipConfirmation() bool {
 //	    return nil // User cancelled
 //	}
 func ConfirmAction(resourceType, resourceID, impact string) bool {
-	if SkipConfirmation() {
+	return rootOptions().ConfirmAction(resourceType, resourceID, impact)
+}
+
+// ConfirmAction prompts on the options' error stream, as the package
+// function does.
+func (o Options) ConfirmAction(resourceType, resourceID, impact string) bool {
+	if o.SkipConfirmation() {
 		return true
 	}
 
-	fmt.Fprintf(os.Stderr, "\nAbout to %s %s: %s\n", getActionVerb(resourceType), resourceType, resourceID)
+	fmt.Fprintf(o.stderr(), "\nAbout to %s %s: %s\n", getActionVerb(resourceType), resourceType, resourceID)
 	if impact != "" {
-		fmt.Fprintf(os.Stderr, "Impact: %s\n", impact)
+		fmt.Fprintf(o.stderr(), "Impact: %s\n", impact)
 	}
-	fmt.Fprintf(os.Stderr, "\nContinue? [y/N]: ")
+	fmt.Fprintf(o.stderr(), "\nContinue? [y/N]: ")
 
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
@@ -74,16 +95,22 @@ func ConfirmAction(resourceType, resourceID, impact string) bool {
 // ConfirmDestructiveAction is like ConfirmAction but emphasizes the destructive nature.
 // Use this for irreversible operations like delete, kill, or terminate.
 func ConfirmDestructiveAction(resourceType, resourceID, impact string) bool {
-	if SkipConfirmation() {
+	return rootOptions().ConfirmDestructiveAction(resourceType, resourceID, impact)
+}
+
+// ConfirmDestructiveAction prompts on the options' error stream, as the
+// package function does.
+func (o Options) ConfirmDestructiveAction(resourceType, resourceID, impact string) bool {
+	if o.SkipConfirmation() {
 		return true
 	}
 
-	fmt.Fprintf(os.Stderr, "\n⚠️  WARNING: Destructive action\n")
-	fmt.Fprintf(os.Stderr, "About to %s %s: %s\n", getActionVerb(resourceType), resourceType, resourceID)
+	fmt.Fprintf(o.stderr(), "\n⚠️  WARNING: Destructive action\n")
+	fmt.Fprintf(o.stderr(), "About to %s %s: %s\n", getActionVerb(resourceType), resourceType, resourceID)
 	if impact != "" {
-		fmt.Fprintf(os.Stderr, "Impact: %s\n", impact)
+		fmt.Fprintf(o.stderr(), "Impact: %s\n", impact)
 	}
-	fmt.Fprintf(os.Stderr, "\nThis action cannot be undone. Continue? [y/N]: ")
+	fmt.Fprintf(o.stderr(), "\nThis action cannot be undone. Continue? [y/N]: ")
 
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
@@ -95,6 +122,33 @@ func ConfirmDestructiveAction(resourceType, resourceID, impact string) bool {
 	return response == "y" || response == "yes"
 }
 
+// requireConfirmation prompts for an action too risky to assume consent for.
+// Only --yes skips the prompt: where SkipConfirmation would answer yes on the
+// user's behalf (no TTY, --non-interactive, --json), it fails at once with
+// guidance naming the flags that approve the action instead.
+func requireConfirmation(prompt, guidance string) (bool, error) {
+	return rootOptions().requireConfirmation(prompt, guidance)
+}
+
+func (o Options) requireConfirmation(prompt, guidance string) (bool, error) {
+	if o.Yes {
+		return true, nil
+	}
+	if o.IsNonInteractive() || o.Structured() {
+		return false, invalidInputError("%q needs confirmation, which cannot be prompted for here; %s", prompt, guidance)
+	}
+
+	fmt.Fprintf(o.stderr(), "%s [y/N]: ", prompt)
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, nil
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
+
 // getActionVerb returns an appropriate verb for the resource type.
 func getActionVerb(resourceType string) string {
 	switch resourceType {
@@ -104,6 +158,10 @@ func getActionVerb(resourceType string) string {
 		return "destroy"
 	case "agent":
 		return "terminate"
+	case "queue":
+		return "clear"
+	case "tmux sessions":
+		return "kill"
 	default:
 		return "delete"
 	}
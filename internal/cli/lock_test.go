@@ -1,7 +1,21 @@
 // Package cli provides tests for lock CLI helpers.
 package cli
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agentmail"
+	"github.com/opencode-ai/swarm/internal/db"
+)
 
 func TestParseLockIDs(t *testing.T) {
 	ids, err := parseLockIDs([]string{"1,2", "3"})
@@ -17,14 +31,130 @@ func TestParseLockIDs(t *testing.T) {
 	}
 }
 
-func TestMatchesPathPattern(t *testing.T) {
-	if !matchesPathPattern("src/main.go", "src/*.go") {
-		t.Fatalf("expected pattern match")
+// stubAgentMail is an Agent Mail MCP server holding one set of conflicting
+// locks, which force releases and claims act on.
+type stubAgentMail struct {
+	mu       sync.Mutex
+	holders  []agentmail.ReservationHolder
+	agents   []agentmail.AgentProfile
+	released []int64
+}
+
+func (s *stubAgentMail) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Method string `json:"method"`
+		Params struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+			URI       string         `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result any
+	switch {
+	case req.Method == "resources/read" && strings.HasPrefix(req.Params.URI, "resource://agents/"):
+		text, _ := json.Marshal(map[string]any{"agents": s.agents})
+		result = map[string]any{"contents": []map[string]string{{"text": string(text)}}}
+	case req.Params.Name == "file_reservation_paths" && len(s.holders) > 0:
+		result = agentmail.ClaimResult{Conflicts: []agentmail.ReservationConflict{{Path: "src/main.go", Holders: s.holders}}}
+	case req.Params.Name == "file_reservation_paths":
+		result = agentmail.ClaimResult{Granted: []agentmail.ReservationGrant{{ID: 99, PathPattern: "src/main.go", Exclusive: true}}}
+	case req.Params.Name == "force_release_file_reservation":
+		id := int64(req.Params.Arguments["file_reservation_id"].(float64))
+		s.released = append(s.released, id)
+		s.holders = slices.DeleteFunc(s.holders, func(h agentmail.ReservationHolder) bool { return h.ID == id })
+		result = map[string]any{}
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": result})
+}
+
+func useStubAgentMail(t *testing.T, holders ...agentmail.ReservationHolder) *stubAgentMail {
+	t.Helper()
+	stub := &stubAgentMail{holders: holders}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	t.Setenv("SWARM_AGENT_MAIL_URL", server.URL)
+	t.Setenv("SWARM_AGENT_MAIL_PROJECT", "/repo")
+	t.Setenv("SWARM_AGENT_MAIL_AGENT", "GreenCastle")
+	return stub
+}
+
+func TestLockClaimForceIfStale(t *testing.T) {
+	now := time.Now().UTC()
+	expired := agentmail.ReservationHolder{ID: 1, Agent: "BlueLake", ExpiresTS: now.Add(-2 * time.Hour).Format(time.RFC3339)}
+	active := agentmail.ReservationHolder{ID: 2, Agent: "RedStone", ExpiresTS: now.Add(time.Hour).Format(time.RFC3339)}
+
+	t.Run("expired holder", func(t *testing.T) {
+		useTestDatabase(t)
+		stub := useStubAgentMail(t, expired)
+		if _, err := runCommand(t, newLockClaimCmd(), "--path", "src/main.go", "--force-if-expired-within", "0s"); err != nil {
+			t.Fatalf("lock claim error = %v", err)
+		}
+		if !slices.Equal(stub.released, []int64{1}) {
+			t.Fatalf("expected the expired lock released, got %v", stub.released)
+		}
+	})
+
+	t.Run("active holder", func(t *testing.T) {
+		useTestDatabase(t)
+		stub := useStubAgentMail(t, expired, active)
+		stub.agents = []agentmail.AgentProfile{{Name: "RedStone", LastActiveTS: now.Add(-5 * time.Minute).Format(time.RFC3339)}}
+		_, err := runCommand(t, newLockClaimCmd(), "--path", "src/main.go", "--force-if-expired-within", "0s", "--force-if-idle", "1h")
+		if !errors.Is(err, ErrConflict) {
+			t.Fatalf("expected a conflict refusal, got %v", err)
+		}
+		if len(stub.released) != 0 {
+			t.Fatalf("expected nothing released, got %v", stub.released)
+		}
+	})
+
+	t.Run("idle swarm agent", func(t *testing.T) {
+		database := useTestDatabase(t)
+		agent := seedQueueAgent(t, database)
+		lastActivity := now.Add(-3 * time.Hour)
+		agent.LastActivity = &lastActivity
+		if err := db.NewAgentRepository(database).Update(context.Background(), agent); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		held := active
+		held.Agent = agent.ID
+		stub := useStubAgentMail(t, held)
+		if _, err := runCommand(t, newLockClaimCmd(), "--path", "src/main.go", "--force-if-idle", "1h"); err != nil {
+			t.Fatalf("lock claim error = %v", err)
+		}
+		if !slices.Equal(stub.released, []int64{2}) {
+			t.Fatalf("expected the idle agent's lock released, got %v", stub.released)
+		}
+	})
+}
+
+func TestLockClaimForceConfirmation(t *testing.T) {
+	active := agentmail.ReservationHolder{ID: 2, Agent: "RedStone", ExpiresTS: time.Now().Add(time.Hour).Format(time.RFC3339)}
+
+	// Without a TTY to prompt on, --force fails at once instead of waiting
+	// on stdin or assuming consent.
+	stub := useStubAgentMail(t, active)
+	if _, err := runCommand(t, newLockClaimCmd(), "--path", "src/main.go", "--force"); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("expected guidance to pass --yes, got %v", err)
 	}
-	if !matchesPathPattern("src/main.go", "src/main.go") {
-		t.Fatalf("expected exact match")
+	if len(stub.released) != 0 {
+		t.Fatalf("expected nothing released, got %v", stub.released)
+	}
+
+	if _, err := runCommand(t, newLockClaimCmd(), "--path", "src/main.go", "--force", "--yes"); err != nil {
+		t.Fatalf("lock claim error = %v", err)
 	}
-	if matchesPathPattern("src/main.go", "docs/*.md") {
-		t.Fatalf("did not expect match")
+	if !slices.Equal(stub.released, []int64{2}) {
+		t.Fatalf("expected the lock released, got %v", stub.released)
 	}
 }
@@ -3,16 +3,23 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/swarmd"
 	"github.com/opencode-ai/swarm/internal/tmux"
 	"github.com/opencode-ai/swarm/internal/workspace"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 var (
@@ -21,6 +28,8 @@ var (
 	logSince    string
 	logNoFollow bool
 	logRaw      bool
+
+	logSincePattern string
 )
 
 func init() {
@@ -28,6 +37,8 @@ func init() {
 
 	logCmd.Flags().BoolVarP(&logFollow, "follow", "f", false, "follow log output (continuous)")
 	logCmd.Flags().IntVarP(&logLast, "last", "n", 0, "show last N lines (0 = all visible)")
+	logCmd.Flags().IntVar(&logLast, "lines", 0, "show last N lines (same as --last)")
+	logCmd.Flags().StringVar(&logSincePattern, "since-pattern", "", "show only output after the last line matching this regex")
 	logCmd.Flags().StringVar(&logSince, "since", "", "show logs since duration (e.g., 1h, 30m)")
 	logCmd.Flags().BoolVar(&logNoFollow, "no-follow", false, "don't follow, just show current content")
 	logCmd.Flags().BoolVar(&logRaw, "raw", false, "show raw output without formatting")
@@ -39,7 +50,9 @@ var logCmd = &cobra.Command{
 	Long: `View an agent's terminal output (transcript).
 
 By default, shows the current visible content. Use --follow to
-continuously stream new output, or --last N to show the last N lines.
+continuously stream new output, or --lines N to show the last N lines.
+--since-pattern shows only what follows the last line matching a regex,
+such as the agent's prompt; without --lines it searches the full history.
 
 Sources:
 - Tmux pane capture (primary)
@@ -48,7 +61,10 @@ Sources:
   swarm log
 
   # Show last 50 lines
-  swarm log abc123 --last 50
+  swarm log abc123 --lines 50
+
+  # Show output since the last shell prompt
+  swarm log abc123 --since-pattern '^\$ '
 
   # Follow output continuously
   swarm log abc123 --follow
@@ -60,7 +76,7 @@ Sources:
   swarm log abc123 --raw`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -101,22 +117,73 @@ Sources:
 			return fmt.Errorf("agent %s has no tmux pane", shortID(agent.ID))
 		}
 
-		tmuxClient := tmux.NewLocalClient()
-
-		// Determine mode
-		if logFollow {
-			return followLog(ctx, tmuxClient, agent.TmuxPane, agent.ID)
+		var since *regexp.Regexp
+		if logSincePattern != "" {
+			since, err = regexp.Compile(logSincePattern)
+			if err != nil {
+				return fmt.Errorf("invalid --since-pattern: %w", err)
+			}
 		}
 
-		// Single capture
-		includeHistory := logLast > 0 || logSince != ""
-		content, err := tmuxClient.CapturePane(ctx, agent.TmuxPane, includeHistory)
-		if err != nil {
-			return fmt.Errorf("failed to capture pane: %w", err)
+		includeHistory := logLast > 0 || logSince != "" || since != nil
+		var content string
+		matched := false
+		if agent.RemoteAgentID != "" {
+			// Agents on swarmd nodes are captured through their daemon.
+			ws, err := wsRepo.Get(ctx, agent.WorkspaceID)
+			if err != nil {
+				return fmt.Errorf("failed to get workspace: %w", err)
+			}
+			n, err := nodeRepo.Get(ctx, ws.NodeID)
+			if err != nil {
+				return fmt.Errorf("failed to get node: %w", err)
+			}
+			client, err := node.DialDaemon(ctx, n)
+			if err != nil {
+				return wrapServiceError(err, "failed to connect to swarmd")
+			}
+			defer client.Close()
+
+			if logFollow {
+				return followDaemonLog(ctx, client, agent.RemoteAgentID, agent.ID)
+			}
+			req := &swarmdv1.CapturePaneRequest{AgentId: agent.RemoteAgentID, SincePattern: logSincePattern}
+			switch {
+			case logLast > 0:
+				req.Lines = int32(logLast)
+			case includeHistory:
+				req.Lines = -1
+			}
+			resp, err := client.CapturePane(ctx, req)
+			if err != nil {
+				return fmt.Errorf("failed to capture pane: %w", err)
+			}
+			content = resp.GetContent()
+			matched = resp.GetPatternMatched()
+		} else {
+			tmuxClient := tmux.NewLocalClient()
+
+			// Determine mode
+			if logFollow {
+				return followLog(ctx, tmuxClient, agent.TmuxPane, agent.ID)
+			}
+
+			// Single capture
+			if logLast > 0 {
+				content, _, err = tmuxClient.CapturePaneRange(ctx, agent.TmuxPane, logLast)
+			} else {
+				content, err = tmuxClient.CapturePane(ctx, agent.TmuxPane, includeHistory)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to capture pane: %w", err)
+			}
+			if since != nil {
+				content, matched = tmux.ContentSince(content, since)
+			}
 		}
 
 		// Apply filters
-		lines := strings.Split(content, "\n")
+		lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
 
 		if logLast > 0 && len(lines) > logLast {
 			lines = lines[len(lines)-logLast:]
@@ -124,13 +191,17 @@ Sources:
 
 		// Output
 		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, map[string]any{
+			out := map[string]any{
 				"agent_id":    agent.ID,
 				"tmux_pane":   agent.TmuxPane,
 				"lines":       len(lines),
 				"content":     strings.Join(lines, "\n"),
 				"captured_at": time.Now().Format(time.RFC3339),
-			})
+			}
+			if since != nil {
+				out["pattern_matched"] = matched
+			}
+			return WriteOutput(os.Stdout, out)
 		}
 
 		if logRaw {
@@ -177,35 +248,63 @@ func followLog(ctx context.Context, tmuxClient *tmux.Client, paneID, agentID str
 			// (could use hash for efficiency, but content comparison is fine for now)
 			currentHash := fmt.Sprintf("%d", len(content))
 			if content != lastContent || currentHash != lastHash {
-				// Find new lines
-				if lastContent == "" {
-					// First capture - show all
-					if logRaw {
-						fmt.Print(content)
-					} else {
-						fmt.Print(content)
-					}
-				} else {
-					// Find delta - show only new content
-					// Simple approach: if content changed, show the difference
-					if len(content) > len(lastContent) && strings.HasPrefix(content, lastContent) {
-						// Content was appended
-						newContent := content[len(lastContent):]
-						fmt.Print(newContent)
-					} else if content != lastContent {
-						// Content changed significantly - show all
-						// (this happens when terminal scrolls)
-						if !logRaw {
-							fmt.Print("\033[2J\033[H") // Clear screen
-							fmt.Printf("=== Refreshed at %s ===\n\n", time.Now().Format("15:04:05"))
-						}
-						fmt.Print(content)
-					}
-				}
-
+				printLogDelta(lastContent, content)
 				lastContent = content
 				lastHash = currentHash
 			}
 		}
 	}
 }
+
+// followDaemonLog streams pane updates for an agent on a swarmd node. The
+// stream resumes from the last content seen if the daemon restarts.
+func followDaemonLog(ctx context.Context, client *swarmd.Client, remoteAgentID, agentID string) error {
+	if !IsJSONOutput() && !IsJSONLOutput() {
+		fmt.Printf("=== Following agent %s (swarmd) - Ctrl+C to stop ===\n\n", shortID(agentID))
+	}
+
+	var lastContent string
+	err := client.FollowPaneUpdates(ctx, &swarmdv1.StreamPaneUpdatesRequest{
+		AgentId:        remoteAgentID,
+		IncludeContent: true,
+		MinInterval:    durationpb.New(500 * time.Millisecond),
+	}, func(update *swarmdv1.StreamPaneUpdatesResponse) error {
+		if update.GetChanged() {
+			printLogDelta(lastContent, update.GetContent())
+			lastContent = update.GetContent()
+		}
+		return nil
+	})
+	if status.Code(err) == codes.NotFound {
+		fmt.Println("\n=== Pane closed ===")
+		return nil
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// printLogDelta prints what changed between two captures of a pane.
+func printLogDelta(lastContent, content string) {
+	if lastContent == "" {
+		// First capture - show all
+		fmt.Print(content)
+		return
+	}
+
+	// Find delta - show only new content
+	// Simple approach: if content changed, show the difference
+	if len(content) > len(lastContent) && strings.HasPrefix(content, lastContent) {
+		// Content was appended
+		fmt.Print(content[len(lastContent):])
+	} else if content != lastContent {
+		// Content changed significantly - show all
+		// (this happens when terminal scrolls)
+		if !logRaw {
+			fmt.Print("\033[2J\033[H") // Clear screen
+			fmt.Printf("=== Refreshed at %s ===\n\n", time.Now().Format("15:04:05"))
+		}
+		fmt.Print(content)
+	}
+}
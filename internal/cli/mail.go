@@ -2,7 +2,6 @@
 package cli
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -101,6 +100,7 @@ You can also provide the body via --file or --stdin.`,
   cat message.md | swarm mail send --to agent-a1 --subject "Task handoff" --stdin`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		cfg, backend, err := resolveMailConfig()
 		if err != nil {
 			return err
@@ -147,7 +147,7 @@ You can also provide the body via --file or --stdin.`,
 		switch backend {
 		case mailBackendMCP:
 			client := newMailMCPClient(cfg)
-			if err := client.SendMessage(context.Background(), req); err != nil {
+			if err := client.SendMessage(ctx, req); err != nil {
 				return err
 			}
 
@@ -172,7 +172,7 @@ You can also provide the body via --file or --stdin.`,
 			}
 			defer store.Close()
 
-			ids, err := store.SendLocal(context.Background(), req)
+			ids, err := store.SendLocal(ctx, req)
 			if err != nil {
 				return err
 			}
@@ -209,6 +209,7 @@ By default, reads the inbox for --agent or SWARM_AGENT_MAIL_AGENT.`,
   swarm mail inbox --agent agent-a1 --since 1h`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		cfg, backend, err := resolveMailConfig()
 		if err != nil {
 			return err
@@ -232,7 +233,7 @@ By default, reads the inbox for --agent or SWARM_AGENT_MAIL_AGENT.`,
 			defer store.Close()
 
 			client := newMailMCPClient(cfg)
-			messages, err = client.FetchInbox(context.Background(), mailInboxRequest{
+			messages, err = client.FetchInbox(ctx, mailInboxRequest{
 				Project: cfg.Project,
 				Agent:   cfg.Agent,
 				Limit:   cfg.Limit,
@@ -243,7 +244,7 @@ By default, reads the inbox for --agent or SWARM_AGENT_MAIL_AGENT.`,
 			}
 
 			if len(messages) > 0 {
-				statuses, err := store.LoadStatus(context.Background(), cfg.Project, cfg.Agent, collectMessageIDs(messages))
+				statuses, err := store.LoadStatus(ctx, cfg.Project, cfg.Agent, collectMessageIDs(messages))
 				if err != nil {
 					return err
 				}
@@ -256,7 +257,7 @@ By default, reads the inbox for --agent or SWARM_AGENT_MAIL_AGENT.`,
 			}
 			defer store.Close()
 
-			messages, err = store.ListLocal(context.Background(), cfg.Project, cfg.Agent, since, mailUnread, cfg.Limit)
+			messages, err = store.ListLocal(ctx, cfg.Project, cfg.Agent, since, mailUnread, cfg.Limit)
 			if err != nil {
 				return err
 			}
@@ -301,6 +302,7 @@ Use --agent to specify which mailbox to read from.`,
 	Example: `  swarm mail read m-001 --agent agent-a1`,
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		cfg, backend, err := resolveMailConfig()
 		if err != nil {
 			return err
@@ -329,7 +331,7 @@ Use --agent to specify which mailbox to read from.`,
 			defer store.Close()
 
 			client := newMailMCPClient(cfg)
-			message, err = client.ReadMessage(context.Background(), mailReadRequest{
+			message, err = client.ReadMessage(ctx, mailReadRequest{
 				Project:   cfg.Project,
 				Agent:     cfg.Agent,
 				MessageID: messageID,
@@ -341,14 +343,14 @@ Use --agent to specify which mailbox to read from.`,
 			}
 
 			now := time.Now().UTC()
-			if err := client.MarkRead(context.Background(), mailStatusRequest{
+			if err := client.MarkRead(ctx, mailStatusRequest{
 				Project:   cfg.Project,
 				Agent:     cfg.Agent,
 				MessageID: messageID,
 			}); err != nil {
 				fmt.Fprintf(os.Stderr, "warning: failed to mark read in MCP: %v\n", err)
 			}
-			if err := store.MarkRead(context.Background(), cfg.Project, cfg.Agent, messageID, now); err != nil {
+			if err := store.MarkRead(ctx, cfg.Project, cfg.Agent, messageID, now); err != nil {
 				return err
 			}
 			message.ReadAt = &now
@@ -359,12 +361,12 @@ Use --agent to specify which mailbox to read from.`,
 			}
 			defer store.Close()
 
-			message, err = store.GetLocal(context.Background(), cfg.Project, cfg.Agent, messageID)
+			message, err = store.GetLocal(ctx, cfg.Project, cfg.Agent, messageID)
 			if err != nil {
 				return err
 			}
 			now := time.Now().UTC()
-			if err := store.MarkRead(context.Background(), cfg.Project, cfg.Agent, messageID, now); err != nil {
+			if err := store.MarkRead(ctx, cfg.Project, cfg.Agent, messageID, now); err != nil {
 				return err
 			}
 			message.ReadAt = &now
@@ -402,6 +404,7 @@ var mailAckCmd = &cobra.Command{
 	Example: `  swarm mail ack m-001 --agent agent-a1`,
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		cfg, backend, err := resolveMailConfig()
 		if err != nil {
 			return err
@@ -426,14 +429,14 @@ var mailAckCmd = &cobra.Command{
 			defer store.Close()
 
 			client := newMailMCPClient(cfg)
-			if err := client.Acknowledge(context.Background(), mailStatusRequest{
+			if err := client.Acknowledge(ctx, mailStatusRequest{
 				Project:   cfg.Project,
 				Agent:     cfg.Agent,
 				MessageID: messageID,
 			}); err != nil {
 				return err
 			}
-			if err := store.MarkAck(context.Background(), cfg.Project, cfg.Agent, messageID, now); err != nil {
+			if err := store.MarkAck(ctx, cfg.Project, cfg.Agent, messageID, now); err != nil {
 				return err
 			}
 		case mailBackendLocal:
@@ -443,7 +446,7 @@ var mailAckCmd = &cobra.Command{
 			}
 			defer store.Close()
 
-			if err := store.MarkAck(context.Background(), cfg.Project, cfg.Agent, messageID, now); err != nil {
+			if err := store.MarkAck(ctx, cfg.Project, cfg.Agent, messageID, now); err != nil {
 				return err
 			}
 		default:
@@ -0,0 +1,136 @@
+// Package cli provides maintenance commands.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var (
+	compactTranscriptsAddr      string
+	compactTranscriptsOlderThan string
+	compactTranscriptsAgent     string
+	compactTranscriptsDryRun    bool
+)
+
+func init() {
+	rootCmd.AddCommand(maintenanceCmd)
+	maintenanceCmd.AddCommand(compactTranscriptsCmd)
+
+	defaultAddr := fmt.Sprintf("%s:%d", swarmd.DefaultHost, swarmd.DefaultPort)
+	compactTranscriptsCmd.Flags().StringVar(&compactTranscriptsAddr, "addr", defaultAddr, "swarmd host:port")
+	compactTranscriptsCmd.Flags().StringVar(&compactTranscriptsOlderThan, "older-than", "7d", "compact output older than this (e.g., 12h, 7d)")
+	compactTranscriptsCmd.Flags().StringVar(&compactTranscriptsAgent, "agent", "", "compact only this agent's transcript")
+	compactTranscriptsCmd.Flags().BoolVar(&compactTranscriptsDryRun, "dry-run", false, "report what would be compacted without changing transcripts")
+}
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Run maintenance jobs",
+	Long:  "Run swarm maintenance jobs on demand instead of waiting for their schedule.",
+}
+
+// compactTranscriptsView is the JSON output of maintenance compact-transcripts.
+type compactTranscriptsView struct {
+	OlderThan        string `json:"older_than"`
+	AgentID          string `json:"agent_id,omitempty"`
+	Agents           int32  `json:"agents"`
+	EntriesCompacted int32  `json:"entries_compacted"`
+	Summaries        int32  `json:"summaries"`
+	BytesCompacted   int64  `json:"bytes_compacted"`
+	DryRun           bool   `json:"dry_run"`
+}
+
+var compactTranscriptsCmd = &cobra.Command{
+	Use:   "compact-transcripts",
+	Short: "Summarize old transcript output",
+	Long: `Make a running swarmd replace each run of transcript OUTPUT entries older
+than --older-than with a single SUMMARY entry. A summary records how many
+entries and bytes it replaced, the time range they spanned, and the first
+and last few lines of output. Commands, user input, state changes, and
+errors are kept as they are.
+
+swarmd runs the same job on the schedule set by
+daemon.transcript_compaction in the config. With --dry-run, swarmd reports
+what it would compact and leaves the transcripts as they are.`,
+	Example: `  swarm maintenance compact-transcripts
+  swarm maintenance compact-transcripts --dry-run
+  swarm maintenance compact-transcripts --older-than 24h --agent abc123`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, err := parseDurationWithDays(strings.TrimSpace(compactTranscriptsOlderThan))
+		if err != nil || olderThan < 0 {
+			return invalidInputError("invalid --older-than %q (use a duration like 12h or 7d)", compactTranscriptsOlderThan)
+		}
+		agentID := strings.TrimSpace(compactTranscriptsAgent)
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), daemonDebugTimeout)
+		defer cancel()
+
+		client, err := swarmd.Dial(ctx, compactTranscriptsAddr, swarmd.WithAuthToken(os.Getenv(swarmd.AuthTokenEnv)))
+		if err != nil {
+			return wrapServiceError(err, "failed to connect to swarmd")
+		}
+		defer client.Close()
+
+		resp, err := client.CompactTranscripts(ctx, &swarmdv1.CompactTranscriptsRequest{
+			OlderThan: durationpb.New(olderThan),
+			AgentId:   agentID,
+			DryRun:    compactTranscriptsDryRun,
+		})
+		if err != nil {
+			switch status.Code(err) {
+			case codes.NotFound:
+				return notFoundError("agent %s is not running on swarmd", agentID)
+			case codes.InvalidArgument:
+				return invalidInputError("%s", status.Convert(err).Message())
+			case codes.Unauthenticated:
+				return invalidInputError("swarmd rejected the auth token")
+			}
+			return wrapServiceError(err, "failed to compact transcripts")
+		}
+
+		view := compactTranscriptsView{
+			OlderThan:        olderThan.String(),
+			AgentID:          agentID,
+			Agents:           resp.GetAgents(),
+			EntriesCompacted: resp.GetEntriesCompacted(),
+			Summaries:        resp.GetSummaries(),
+			BytesCompacted:   resp.GetBytesCompacted(),
+			DryRun:           compactTranscriptsDryRun,
+		}
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, view)
+		}
+		if view.EntriesCompacted == 0 {
+			fmt.Printf("No transcript output older than %s to compact\n", view.OlderThan)
+			return nil
+		}
+		if view.DryRun {
+			rows := [][]string{{
+				strconv.Itoa(int(view.Agents)),
+				strconv.Itoa(int(view.EntriesCompacted)),
+				strconv.FormatInt(view.BytesCompacted, 10),
+				strconv.Itoa(int(view.Summaries)),
+			}}
+			if err := writeTable(os.Stdout, []string{"AGENTS", "OUTPUT ENTRIES", "BYTES", "SUMMARIES"}, rows); err != nil {
+				return err
+			}
+			printDryRunNote(os.Stdout)
+			return nil
+		}
+		fmt.Printf("Compacted %d output entries (%d bytes) into %d summaries across %d agents\n",
+			view.EntriesCompacted, view.BytesCompacted, view.Summaries, view.Agents)
+		return nil
+	},
+}
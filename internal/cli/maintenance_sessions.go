@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/sessiongc"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcSessionsKill      bool
+	gcSessionsOlderThan string
+)
+
+var gcSessionsTmuxClient = tmux.NewLocalClient
+
+func init() {
+	maintenanceCmd.AddCommand(gcSessionsCmd)
+
+	gcSessionsCmd.Flags().BoolVar(&gcSessionsKill, "kill", false, "kill the unreferenced sessions after confirmation")
+	gcSessionsCmd.Flags().StringVar(&gcSessionsOlderThan, "older-than", "", "only sessions idle longer than this (e.g., 24h, 7d)")
+}
+
+var gcSessionsCmd = &cobra.Command{
+	Use:   "gc-sessions",
+	Short: "Find tmux sessions left behind by crashed runs",
+	Long: `Report tmux sessions that follow the swarm naming convention
+(<workspace_defaults.tmux_prefix>-...) but are referenced by no workspace and
+no agent, with their pane count and how long they have been idle.
+
+A session is referenced when a workspace records it, including an imported
+one, when an agent names it, or when an agent's pane lives in it. Referenced
+sessions and sessions outside the naming convention are never selected.
+
+With --kill, the unreferenced sessions are killed after confirmation. swarmd
+runs the same check on the schedule set by daemon.session_gc in the config,
+reporting only unless daemon.session_gc.kill is set.`,
+	Example: `  swarm maintenance gc-sessions
+  swarm maintenance gc-sessions --older-than 24h
+  swarm maintenance gc-sessions --kill --older-than 24h`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		var olderThan time.Duration
+		if value := strings.TrimSpace(gcSessionsOlderThan); value != "" {
+			parsed, err := parseDurationWithDays(value)
+			if err != nil || parsed < 0 {
+				return invalidInputError("invalid --older-than %q (use a duration like 12h or 7d)", gcSessionsOlderThan)
+			}
+			olderThan = parsed
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		collector := sessiongc.New(database, gcSessionsTmuxClient(), logger)
+
+		opts := sessiongc.Options{OlderThan: olderThan}
+		if appConfig != nil {
+			opts.Prefix = appConfig.WorkspaceDefaults.TmuxPrefix
+		}
+		report, err := collector.Collect(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to check tmux sessions: %w", err)
+		}
+
+		if gcSessionsKill && len(report.Candidates) > 0 {
+			names := make([]string, 0, len(report.Candidates))
+			for _, candidate := range report.Candidates {
+				names = append(names, candidate.Name)
+			}
+			if !IsJSONOutput() && !IsJSONLOutput() {
+				if err := writeGCSessionsTable(report); err != nil {
+					return err
+				}
+			}
+			impact := fmt.Sprintf("This will kill %d tmux session(s) and any processes still running in them.", len(names))
+			if !ConfirmDestructiveAction("tmux sessions", strings.Join(names, ", "), impact) {
+				fmt.Fprintln(os.Stderr, "Cancelled.")
+				return nil
+			}
+
+			// Re-check at kill time so a session claimed since the report
+			// is left alone.
+			opts.Kill = true
+			opts.Only = names
+			report, err = collector.Collect(ctx, opts)
+			if err != nil {
+				return fmt.Errorf("failed to kill tmux sessions: %w", err)
+			}
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, report)
+		}
+
+		if len(report.Candidates) == 0 {
+			fmt.Printf("Checked %d session(s); no unreferenced sessions found.\n", report.Checked)
+			return nil
+		}
+		if !report.Kill {
+			if err := writeGCSessionsTable(report); err != nil {
+				return err
+			}
+			fmt.Println("\nRe-run with --kill to kill these sessions.")
+			return nil
+		}
+
+		failed := 0
+		for _, candidate := range report.Candidates {
+			if candidate.Error != "" {
+				fmt.Fprintf(os.Stderr, "failed to kill %s: %s\n", candidate.Name, candidate.Error)
+				failed++
+				continue
+			}
+			fmt.Printf("✓ Killed tmux session %s\n", candidate.Name)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d session(s) could not be killed", failed)
+		}
+		return nil
+	},
+}
+
+func writeGCSessionsTable(report *sessiongc.Report) error {
+	rows := make([][]string, 0, len(report.Candidates))
+	for _, candidate := range report.Candidates {
+		idle := "unknown"
+		if !candidate.LastActivity.IsZero() {
+			idle = candidate.Idle.String()
+		}
+		rows = append(rows, []string{
+			candidate.Name,
+			strconv.Itoa(candidate.Windows),
+			strconv.Itoa(candidate.Panes),
+			idle,
+		})
+	}
+	return writeTable(os.Stdout, []string{"SESSION", "WINDOWS", "PANES", "IDLE"}, rows)
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/sessiongc"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+)
+
+func TestMaintenanceCompactTranscripts(t *testing.T) {
+	addr := startDaemonServer(t, swarmd.NewServer(zerolog.Nop()))
+
+	out := runJSONCommand(t, compactTranscriptsCmd, "--addr", addr, "--older-than", "2d", "--agent", "")
+	var view compactTranscriptsView
+	if err := json.Unmarshal(out, &view); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if view.OlderThan != "48h0m0s" || view.EntriesCompacted != 0 {
+		t.Fatalf("unexpected compaction output: %+v", view)
+	}
+
+	out = runJSONCommand(t, compactTranscriptsCmd, "--addr", addr, "--agent", "", "--dry-run")
+	view = compactTranscriptsView{}
+	if err := json.Unmarshal(out, &view); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if !view.DryRun {
+		t.Fatalf("expected a dry-run view, got %+v", view)
+	}
+
+	if code, err := runCommand(t, compactTranscriptsCmd, "--addr", addr, "--agent", "missing"); code != ExitCodeNotFound {
+		t.Fatalf("expected exit %d for an unknown agent, got %d (err=%v)", ExitCodeNotFound, code, err)
+	}
+	if code, err := runCommand(t, compactTranscriptsCmd, "--addr", addr, "--agent", "", "--older-than", "soon"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d for a bad age, got %d (err=%v)", ExitCodeInvalidInput, code, err)
+	}
+}
+
+func TestMaintenanceGCSessions(t *testing.T) {
+	database := useTestDatabase(t)
+	seedQueueAgent(t, database)
+
+	// Sessions made a day ago on the fake server's clock.
+	srv := tmuxtest.NewServer(tmuxtest.WithClock(clock.NewFake(time.Now().Add(-24 * time.Hour))))
+	for _, name := range []string{"swarm-repo", "swarm-crashed-ab12", "notes"} {
+		if _, err := srv.Run("new-session", "-d", "-s", name); err != nil {
+			t.Fatalf("new-session %s: %v", name, err)
+		}
+	}
+	previous := gcSessionsTmuxClient
+	gcSessionsTmuxClient = func() *tmux.Client { return tmux.NewClient(srv) }
+	t.Cleanup(func() { gcSessionsTmuxClient = previous })
+
+	var report sessiongc.Report
+	out := runJSONCommand(t, gcSessionsCmd, "--older-than", "1h")
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if report.Kill || report.Checked != 2 || report.Referenced != 1 || len(report.Candidates) != 1 || report.Candidates[0].Name != "swarm-crashed-ab12" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if candidate := report.Candidates[0]; candidate.Panes != 1 || candidate.Idle < 23*time.Hour {
+		t.Fatalf("unexpected candidate: %+v", candidate)
+	}
+
+	report = sessiongc.Report{}
+	out = runJSONCommand(t, gcSessionsCmd, "--kill", "--older-than", "2d")
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if report.Recent != 1 || len(report.Candidates) != 0 {
+		t.Fatalf("expected the session too recent to kill, got %+v", report)
+	}
+
+	report = sessiongc.Report{}
+	out = runJSONCommand(t, gcSessionsCmd, "--kill", "--older-than", "")
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if len(report.Candidates) != 1 || !report.Candidates[0].Killed {
+		t.Fatalf("expected the crashed session killed, got %+v", report)
+	}
+	for _, name := range []string{"swarm-repo", "notes"} {
+		if _, err := srv.Run("has-session", "-t", name); err != nil {
+			t.Fatalf("expected %s left alone: %v", name, err)
+		}
+	}
+	if _, err := srv.Run("has-session", "-t", "swarm-crashed-ab12"); err == nil {
+		t.Fatal("expected swarm-crashed-ab12 killed")
+	}
+
+	if code, err := runCommand(t, gcSessionsCmd, "--older-than", "soon"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d for a bad age, got %d (err=%v)", ExitCodeInvalidInput, code, err)
+	}
+}
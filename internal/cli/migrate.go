@@ -15,6 +15,7 @@ import (
 var (
 	migrateSteps   int
 	migrateVersion int
+	migrateDryRun  bool
 )
 
 func init() {
@@ -29,7 +30,9 @@ func init() {
 
 	// Flags
 	migrateDownCmd.Flags().IntVarP(&migrateSteps, "steps", "n", 1, "number of migrations to roll back")
-	migrateUpCmd.Flags().IntVar(&migrateVersion, "to", 0, "migrate to specific version (0 = latest)")
+	migrateUpCmd.Flags().IntVar(&migrateVersion, "to", 0, "migrate up or down to specific version (0 = latest)")
+	migrateUpCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "show the migrations that would run without applying them")
+	migrateDownCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "show the migrations that would be rolled back without running them")
 }
 
 var migrateCmd = &cobra.Command{
@@ -47,9 +50,18 @@ Commands:
 var migrateUpCmd = &cobra.Command{
 	Use:   "up",
 	Short: "Apply pending migrations",
-	Long:  `Apply all pending database migrations, or migrate to a specific version.`,
+	Long: `Apply all pending database migrations, or migrate to a specific version.
+
+Migrations take a lock next to the database, so a second swarm process
+waits for them (up to 30s) instead of migrating at the same time. --to
+may roll back and, like 'migrate down', refuses to run while swarmd is
+running. --dry-run lists the migrations that would run, with a summary of
+their SQL, and changes nothing.
+
+Applied migrations whose files have changed since fail the command; see
+'migrate status'.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabaseNoMigrate()
 		if err != nil {
@@ -57,36 +69,44 @@ var migrateUpCmd = &cobra.Command{
 		}
 		defer database.Close()
 
-		if migrateVersion > 0 {
-			// Migrate to specific version
-			if err := database.MigrateTo(ctx, migrateVersion); err != nil {
-				return fmt.Errorf("migration failed: %w", err)
+		if migrateDryRun {
+			var steps []db.MigrationStep
+			if migrateVersion > 0 {
+				steps, err = database.PlanMigrateTo(ctx, migrateVersion)
+			} else {
+				steps, err = database.PlanMigrateUp(ctx)
 			}
-			cmd.Printf("Migrated to version %d\n", migrateVersion)
-		} else {
-			// Apply all pending
-			applied, err := database.MigrateUp(ctx)
 			if err != nil {
 				return fmt.Errorf("migration failed: %w", err)
 			}
-
-			if applied == 0 {
-				cmd.Println("No pending migrations")
-			} else {
-				cmd.Printf("Applied %d migration(s)\n", applied)
-			}
+			return printMigrationPlan(cmd, steps, "No pending migrations")
 		}
 
-		return nil
+		var runs []db.MigrationRun
+		if migrateVersion > 0 {
+			// Migrate to specific version
+			runs, err = database.MigrateTo(ctx, migrateVersion)
+		} else {
+			// Apply all pending
+			runs, err = database.MigrateUp(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+		return printMigrationRuns(cmd, runs, "No pending migrations")
 	},
 }
 
 var migrateDownCmd = &cobra.Command{
 	Use:   "down",
 	Short: "Roll back migrations",
-	Long:  `Roll back the last N migrations (default: 1).`,
+	Long: `Roll back the last N migrations (default: 1).
+
+Refuses to run while swarmd is running; stop it first. Migrations without
+down SQL are irreversible: rolling back past one fails before anything is
+rolled back.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabaseNoMigrate()
 		if err != nil {
@@ -94,18 +114,19 @@ var migrateDownCmd = &cobra.Command{
 		}
 		defer database.Close()
 
-		rolledBack, err := database.MigrateDown(ctx, migrateSteps)
-		if err != nil {
-			return fmt.Errorf("rollback failed: %w", err)
+		if migrateDryRun {
+			steps, err := database.PlanMigrateDown(ctx, migrateSteps)
+			if err != nil {
+				return fmt.Errorf("rollback failed: %w", err)
+			}
+			return printMigrationPlan(cmd, steps, "No migrations to roll back")
 		}
 
-		if rolledBack == 0 {
-			cmd.Println("No migrations to roll back")
-		} else {
-			cmd.Printf("Rolled back %d migration(s)\n", rolledBack)
+		runs, err := database.MigrateDown(ctx, migrateSteps)
+		if err != nil {
+			return fmt.Errorf("rollback failed: %w", err)
 		}
-
-		return nil
+		return printMigrationRuns(cmd, runs, "No migrations to roll back")
 	},
 }
 
@@ -114,7 +135,7 @@ var migrateStatusCmd = &cobra.Command{
 	Short: "Show migration status",
 	Long:  `Display the status of all migrations.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabaseNoMigrate()
 		if err != nil {
@@ -127,15 +148,15 @@ var migrateStatusCmd = &cobra.Command{
 			return fmt.Errorf("failed to get migration status: %w", err)
 		}
 
-		if jsonOutput {
+		if IsJSONOutput() {
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
 			return enc.Encode(status)
 		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "VERSION\tDESCRIPTION\tSTATUS\tAPPLIED AT")
-		fmt.Fprintln(w, "-------\t-----------\t------\t----------")
+		fmt.Fprintln(w, "VERSION\tDESCRIPTION\tSTATUS\tAPPLIED AT\tDOWN")
+		fmt.Fprintln(w, "-------\t-----------\t------\t----------\t----")
 
 		for _, s := range status {
 			statusStr := "pending"
@@ -144,7 +165,14 @@ var migrateStatusCmd = &cobra.Command{
 				statusStr = "applied"
 				appliedAt = s.AppliedAt
 			}
-			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Description, statusStr, appliedAt)
+			if s.Modified {
+				statusStr = "modified"
+			}
+			down := "yes"
+			if s.Irreversible {
+				down = "no"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", s.Version, s.Description, statusStr, appliedAt, down)
 		}
 
 		return w.Flush()
@@ -156,7 +184,7 @@ var migrateVersionCmd = &cobra.Command{
 	Short: "Show current schema version",
 	Long:  `Display the current database schema version.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabaseNoMigrate()
 		if err != nil {
@@ -169,7 +197,7 @@ var migrateVersionCmd = &cobra.Command{
 			return fmt.Errorf("failed to get schema version: %w", err)
 		}
 
-		if jsonOutput {
+		if IsJSONOutput() {
 			enc := json.NewEncoder(os.Stdout)
 			return enc.Encode(map[string]int{"version": version})
 		}
@@ -179,13 +207,69 @@ var migrateVersionCmd = &cobra.Command{
 	},
 }
 
+// printMigrationPlan prints the steps a dry run would run, with their SQL
+// summaries, or none when there are none.
+func printMigrationPlan(cmd *cobra.Command, steps []db.MigrationStep, none string) error {
+	if IsJSONOutput() {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(steps)
+	}
+
+	if len(steps) == 0 {
+		cmd.Println(none)
+		return nil
+	}
+	verb := "apply"
+	if steps[0].Direction == db.MigrationDown {
+		verb = "roll back"
+	}
+	cmd.Printf("Would %s %d migration(s):\n", verb, len(steps))
+	for _, step := range steps {
+		cmd.Printf("  %03d %s\n", step.Version, step.Description)
+		for _, line := range step.Summary {
+			cmd.Printf("        %s\n", line)
+		}
+	}
+	return nil
+}
+
+// printMigrationRuns prints the steps that ran, or none when none did.
+func printMigrationRuns(cmd *cobra.Command, runs []db.MigrationRun, none string) error {
+	if IsJSONOutput() {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(runs)
+	}
+
+	if len(runs) == 0 {
+		cmd.Println(none)
+		return nil
+	}
+	verb := "Applied"
+	if runs[0].Direction == db.MigrationDown {
+		verb = "Rolled back"
+	}
+	cmd.Printf("%s %d migration(s):\n", verb, len(runs))
+	for _, run := range runs {
+		cmd.Printf("  %03d %s (%s)\n", run.Version, run.Description, formatDuration(run.Duration))
+	}
+	return nil
+}
+
+// databaseOpener opens the database for commands. Tests replace it to
+// inject a prepared or failing database.
+var databaseOpener = openDatabaseWithMigration
+
 // openDatabase opens the database using the current configuration.
 func openDatabase() (*db.DB, error) {
-	return openDatabaseWithMigration(true)
+	database, err := databaseOpener(true)
+	return database, withCategory(ErrUnavailable, err)
 }
 
 func openDatabaseNoMigrate() (*db.DB, error) {
-	return openDatabaseWithMigration(false)
+	database, err := databaseOpener(false)
+	return database, withCategory(ErrUnavailable, err)
 }
 
 func openDatabaseWithMigration(autoMigrate bool) (*db.DB, error) {
@@ -197,6 +281,8 @@ func openDatabaseWithMigration(autoMigrate bool) (*db.DB, error) {
 		Path:          appConfig.DatabasePath(),
 		MaxOpenConns:  10,
 		BusyTimeoutMs: 5000,
+		EventOutbox:   appConfig.EventBridge.Enabled,
+		Redactor:      configuredRedactor(),
 	}
 
 	database, err := db.Open(cfg)
@@ -236,7 +322,7 @@ func autoMigrateDatabase(database *db.DB) error {
 		return fmt.Errorf("auto-migrate failed: %w", err)
 	}
 
-	if applied > 0 {
+	if len(applied) > 0 {
 		afterVersion := beforeVersion
 		if version, err := database.SchemaVersion(ctx); err == nil {
 			afterVersion = version
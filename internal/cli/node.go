@@ -26,12 +26,20 @@ var (
 	nodeAddLocal   bool
 	nodeAddKeyPath string
 	nodeAddNoTest  bool
+	nodeAddDaemon  string
+	nodeAddToken   string
 
 	// Node remove flags
 	nodeRemoveForce bool
 
 	// Node exec flags
 	nodeExecTimeout int
+
+	// Node label flags
+	nodeLabelRemove []string
+
+	// Node status flags
+	nodeStatusInterval time.Duration
 )
 
 func init() {
@@ -42,7 +50,9 @@ func init() {
 	nodeCmd.AddCommand(nodeBootstrapCmd)
 	nodeCmd.AddCommand(nodeDoctorCmd)
 	nodeCmd.AddCommand(nodeRefreshCmd)
+	nodeCmd.AddCommand(nodeStatusCmd)
 	nodeCmd.AddCommand(nodeExecCmd)
+	nodeCmd.AddCommand(nodeLabelCmd)
 
 	// List flags
 	nodeListCmd.Flags().StringVar(&nodeStatus, "status", "", "filter by status (online, offline, unknown)")
@@ -53,6 +63,8 @@ func init() {
 	nodeAddCmd.Flags().BoolVar(&nodeAddLocal, "local", false, "mark as local node (no SSH)")
 	nodeAddCmd.Flags().StringVar(&nodeAddKeyPath, "key", "", "path to SSH private key")
 	nodeAddCmd.Flags().BoolVar(&nodeAddNoTest, "no-test", false, "skip connection test")
+	nodeAddCmd.Flags().StringVar(&nodeAddDaemon, "daemon", "", "swarmd endpoint (host:port) to manage agents through")
+	nodeAddCmd.Flags().StringVar(&nodeAddToken, "token", "", "auth token for --daemon (the daemon's SWARMD_AUTH_TOKEN)")
 	nodeAddCmd.MarkFlagRequired("name")
 
 	// Remove flags
@@ -60,6 +72,12 @@ func init() {
 
 	// Exec flags
 	nodeExecCmd.Flags().IntVar(&nodeExecTimeout, "timeout", 60, "command timeout in seconds")
+
+	// Status flags
+	nodeStatusCmd.Flags().DurationVar(&nodeStatusInterval, "interval", 10*time.Second, "how often --watch checks the node")
+
+	// Label flags
+	nodeLabelCmd.Flags().StringSliceVar(&nodeLabelRemove, "remove", nil, "label key to remove (repeatable)")
 }
 
 var nodeCmd = &cobra.Command{
@@ -68,7 +86,8 @@ var nodeCmd = &cobra.Command{
 	Long: `Manage nodes in the swarm cluster.
 
 Nodes are machines (local or remote) where agent workspaces can run.
-Remote nodes are accessed via SSH.`,
+Remote nodes are accessed via SSH, or through a swarmd daemon already
+running on the machine.`,
 }
 
 var nodeListCmd = &cobra.Command{
@@ -76,7 +95,7 @@ var nodeListCmd = &cobra.Command{
 	Short: "List nodes",
 	Long:  "List all registered nodes in the swarm.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -102,6 +121,9 @@ var nodeListCmd = &cobra.Command{
 			rows := make([][]string, 0, len(nodes))
 			for _, n := range nodes {
 				sshTarget := n.SSHTarget
+				if n.IsDaemon() {
+					sshTarget = "swarmd:" + n.DaemonEndpoint
+				}
 				if sshTarget == "" {
 					sshTarget = "-"
 				}
@@ -112,9 +134,10 @@ var nodeListCmd = &cobra.Command{
 					formatYesNo(n.IsLocal),
 					sshTarget,
 					fmt.Sprintf("%d", n.AgentCount),
+					formatNodeLabels(n.Labels),
 				})
 			}
-			return writeTable(os.Stdout, []string{"NAME", "ID", "STATUS", "LOCAL", "SSH", "AGENTS"}, rows)
+			return writeTable(os.Stdout, []string{"NAME", "ID", "STATUS", "LOCAL", "SSH", "AGENTS", "LABELS"}, rows)
 		}
 
 		return WriteOutput(os.Stdout, nodes)
@@ -132,6 +155,9 @@ For remote nodes, provide the SSH target:
 For local nodes:
   swarm node add --name localhost --local
 
+For machines running swarmd, agents are spawned and driven over gRPC:
+  swarm node add --name gpu --daemon gpu.internal:50051 --token secret
+
 By default, the connection is tested before adding. Use --no-test to skip.`,
 	Example: `  # Add a remote node
   swarm node add --name prod-server --ssh ubuntu@192.168.1.100
@@ -140,17 +166,26 @@ By default, the connection is tested before adding. Use --no-test to skip.`,
   swarm node add --name staging --ssh deploy@staging.example.com:2222 --key ~/.ssh/staging_key
 
   # Add the local machine
-  swarm node add --name localhost --local`,
+  swarm node add --name localhost --local
+
+  # Add a machine running swarmd with SWARMD_AUTH_TOKEN set
+  swarm node add --name gpu --daemon gpu.internal:50051 --token secret`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// Validate flags
-		if !nodeAddLocal && nodeAddSSH == "" {
-			return errors.New("either --ssh or --local is required")
+		if !nodeAddLocal && nodeAddSSH == "" && nodeAddDaemon == "" {
+			return errors.New("one of --ssh, --local, or --daemon is required")
 		}
 		if nodeAddLocal && nodeAddSSH != "" {
 			return errors.New("--ssh and --local are mutually exclusive")
 		}
+		if nodeAddDaemon != "" && (nodeAddLocal || nodeAddSSH != "") {
+			return errors.New("--daemon cannot be combined with --ssh or --local")
+		}
+		if nodeAddToken != "" && nodeAddDaemon == "" {
+			return errors.New("--token requires --daemon")
+		}
 
 		database, err := openDatabase()
 		if err != nil {
@@ -167,6 +202,9 @@ By default, the connection is tested before adding. Use --no-test to skip.`,
 			SSHTarget:  nodeAddSSH,
 			SSHKeyPath: nodeAddKeyPath,
 			Status:     models.NodeStatusUnknown,
+
+			DaemonEndpoint: nodeAddDaemon,
+			DaemonToken:    nodeAddToken,
 		}
 
 		testConnection := !nodeAddNoTest && !nodeAddLocal
@@ -188,6 +226,9 @@ By default, the connection is tested before adding. Use --no-test to skip.`,
 		fmt.Printf("Node '%s' added successfully (ID: %s)\n", n.Name, n.ID)
 		if testConnection {
 			fmt.Println("Connection test: PASSED")
+			if n.Metadata.SwarmdVersion != "" {
+				fmt.Printf("  swarmd: %s\n", n.Metadata.SwarmdVersion)
+			}
 			if n.Metadata.TmuxVersion != "" {
 				fmt.Printf("  tmux: %s\n", n.Metadata.TmuxVersion)
 			}
@@ -209,7 +250,7 @@ If the node has active workspaces, you must use --force to remove it.
 This does not stop agents running on the node.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 		nameOrID := args[0]
 
 		database, err := openDatabase()
@@ -258,6 +299,74 @@ This does not stop agents running on the node.`,
 	},
 }
 
+var nodeLabelCmd = &cobra.Command{
+	Use:   "label <name-or-id> [key=value...]",
+	Short: "Set or remove node labels",
+	Long: `Set or remove labels on a node.
+
+Labels are key=value tags used to constrain workspace placement with
+ws create --require-label, or to pin placement with workspace_defaults.pin_labels.
+With no labels or --remove, the current labels are shown.`,
+	Example: `  # Mark a node as having a GPU
+  swarm node label gpu-box gpu=true
+
+  # Change one label and drop another
+  swarm node label gpu-box zone=eu --remove rack`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		set, err := node.ParseLabels(args[1:])
+		if err != nil {
+			return err
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		repo := db.NewNodeRepository(database)
+		service := node.NewService(repo, node.WithPublisher(newEventPublisher(database)))
+
+		n, err := findNode(ctx, service, args[0])
+		if err != nil {
+			return err
+		}
+
+		if len(set) > 0 || len(nodeLabelRemove) > 0 {
+			if n.Labels == nil {
+				n.Labels = make(map[string]string, len(set))
+			}
+			for key, value := range set {
+				n.Labels[key] = value
+			}
+			for _, key := range nodeLabelRemove {
+				delete(n.Labels, strings.TrimSpace(key))
+			}
+			if err := service.UpdateNode(ctx, n); err != nil {
+				return fmt.Errorf("failed to update node labels: %w", err)
+			}
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			labels := n.Labels
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			return WriteOutput(os.Stdout, map[string]any{
+				"node_id": n.ID,
+				"name":    n.Name,
+				"labels":  labels,
+			})
+		}
+
+		fmt.Printf("Node '%s' labels: %s\n", n.Name, formatNodeLabels(n.Labels))
+		return nil
+	},
+}
+
 var nodeBootstrapCmd = &cobra.Command{
 	Use:   "bootstrap <name-or-id>",
 	Short: "Bootstrap a node with swarm dependencies",
@@ -270,7 +379,7 @@ This command SSHs to the node and installs:
 For local nodes, uses the local package manager.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 		nameOrID := args[0]
 
 		database, err := openDatabase()
@@ -353,7 +462,7 @@ Checks include:
   - Agent CLI availability (opencode, claude, codex, gemini)`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 		nameOrID := args[0]
 
 		database, err := openDatabase()
@@ -428,7 +537,7 @@ var nodeRefreshCmd = &cobra.Command{
 
 If no node is specified, refreshes all nodes.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -437,7 +546,7 @@ If no node is specified, refreshes all nodes.`,
 		defer database.Close()
 
 		repo := db.NewNodeRepository(database)
-		service := node.NewService(repo, node.WithPublisher(newEventPublisher(database)))
+		service := node.NewService(repo, append(nodeMetricsOptions(), node.WithPublisher(newEventPublisher(database)))...)
 
 		var nodesToRefresh []*models.Node
 
@@ -503,6 +612,196 @@ If no node is specified, refreshes all nodes.`,
 	},
 }
 
+var nodeStatusCmd = &cobra.Command{
+	Use:   "status <name-or-id>",
+	Short: "Show node status",
+	Long: `Test connectivity to a node, update its status, and show the result.
+
+For daemon-backed nodes this pings swarmd and reports its version, uptime,
+agent count, and health.
+
+For nodes reached over SSH or locally, the same connection reads load
+averages, free memory, free disk on node_defaults.metrics_disk_path, and tmux
+session and pane counts. They are stored with the node and feed the
+least-loaded placement strategy; a node with less free disk than
+node_defaults.min_disk_free_percent is marked degraded. When the node cannot
+be reached the last stored metrics are shown, marked stale once they are
+older than two health check intervals.
+
+With --watch the node is checked again every --interval.`,
+	Example: `  swarm node status prod
+  swarm node status prod --watch --interval 30s`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		repo := db.NewNodeRepository(database)
+		service := node.NewService(repo, append(nodeMetricsOptions(), node.WithPublisher(newEventPublisher(database)))...)
+
+		n, err := findNode(ctx, service, args[0])
+		if err != nil {
+			return err
+		}
+		if !IsWatchMode() {
+			return showNodeStatus(ctx, service, n)
+		}
+
+		if nodeStatusInterval <= 0 {
+			return invalidInputError("--interval must be positive")
+		}
+		ticker := time.NewTicker(nodeStatusInterval)
+		defer ticker.Stop()
+		for {
+			if err := showNodeStatus(ctx, service, n); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+			if !IsJSONOutput() && !IsJSONLOutput() {
+				fmt.Println()
+			}
+		}
+	},
+}
+
+// nodeStatusView is the output of node status.
+type nodeStatusView struct {
+	NodeID         string              `json:"node_id"`
+	Name           string              `json:"name"`
+	Status         models.NodeStatus   `json:"status"`
+	LatencyMs      int64               `json:"latency_ms,omitempty"`
+	Error          string              `json:"error,omitempty"`
+	DaemonEndpoint string              `json:"daemon_endpoint,omitempty"`
+	Daemon         *node.DaemonStatus  `json:"daemon,omitempty"`
+	Metrics        *models.NodeMetrics `json:"metrics,omitempty"`
+	// MetricsStale is set when Metrics are from an earlier check.
+	MetricsStale bool `json:"metrics_stale,omitempty"`
+}
+
+// showNodeStatus refreshes n and prints its status.
+func showNodeStatus(ctx context.Context, service *node.Service, n *models.Node) error {
+	result, err := service.RefreshNodeStatus(ctx, n.ID)
+	if err != nil {
+		return wrapServiceError(err, "failed to refresh node status")
+	}
+
+	view := nodeStatusView{
+		NodeID:         n.ID,
+		Name:           n.Name,
+		Status:         models.NodeStatusOffline,
+		DaemonEndpoint: n.DaemonEndpoint,
+		Error:          result.Error,
+		Daemon:         result.Daemon,
+		Metrics:        result.Metrics,
+	}
+	if result.Success {
+		view.Status = models.NodeStatusOnline
+		view.LatencyMs = result.Latency.Milliseconds()
+	}
+	if view.Metrics == nil {
+		// Show what the last successful check reported.
+		if stored, err := service.GetNode(ctx, n.ID); err == nil && stored.Metrics != nil {
+			view.Metrics = stored.Metrics
+			view.MetricsStale = time.Since(stored.Metrics.CollectedAt) > nodeMetricsStaleAfter()
+		}
+	}
+
+	if IsJSONOutput() || IsJSONLOutput() {
+		return WriteOutput(os.Stdout, view)
+	}
+
+	fmt.Printf("%s: %s", view.Name, formatNodeStatus(view.Status))
+	if result.Success {
+		fmt.Printf(" (latency: %dms)\n", view.LatencyMs)
+	} else {
+		fmt.Printf(" (%s)\n", view.Error)
+	}
+	if d := view.Daemon; d != nil {
+		fmt.Printf("  swarmd:   %s at %s\n", d.Version, view.DaemonEndpoint)
+		if d.Hostname != "" {
+			fmt.Printf("  hostname: %s\n", d.Hostname)
+		}
+		fmt.Printf("  uptime:   %s\n", time.Duration(d.UptimeSeconds)*time.Second)
+		fmt.Printf("  agents:   %d\n", d.AgentCount)
+		fmt.Printf("  health:   %s\n", d.Health)
+	}
+	if m := view.Metrics; m != nil {
+		writeNodeMetrics(m, view.MetricsStale)
+	}
+	return nil
+}
+
+func writeNodeMetrics(m *models.NodeMetrics, stale bool) {
+	load := fmt.Sprintf("%.2f %.2f %.2f", m.Load1, m.Load5, m.Load15)
+	if m.CPUs > 0 {
+		load += fmt.Sprintf(" (%d CPUs)", m.CPUs)
+	}
+	fmt.Printf("  load:     %s\n", load)
+	if m.MemTotalBytes > 0 {
+		fmt.Printf("  memory:   %s free of %s\n", formatBytes(m.MemFreeBytes), formatBytes(m.MemTotalBytes))
+	}
+	if m.DiskTotalBytes > 0 {
+		fmt.Printf("  disk:     %s free of %s (%.1f%%) on %s\n",
+			formatBytes(m.DiskFreeBytes), formatBytes(m.DiskTotalBytes), m.DiskFreePercent(), m.DiskPath)
+	}
+	fmt.Printf("  tmux:     %d sessions, %d panes\n", m.TmuxSessions, m.TmuxPanes)
+	collected := "collected " + formatRelativeTime(m.CollectedAt)
+	if stale {
+		collected += " (stale)"
+	}
+	fmt.Printf("  metrics:  %s\n", collected)
+	for _, reason := range m.Degraded {
+		fmt.Printf("  degraded: %s\n", reason)
+	}
+}
+
+// nodeMetricsOptions configures node metrics collection from the config.
+func nodeMetricsOptions() []node.ServiceOption {
+	cfg := GetConfig()
+	if cfg == nil {
+		return nil
+	}
+	return []node.ServiceOption{
+		node.WithMetricsDiskPath(cfg.NodeDefaults.MetricsDiskPath),
+		node.WithMinDiskFreePercent(cfg.NodeDefaults.MinDiskFreePercent),
+	}
+}
+
+// nodeMetricsStaleAfter is how old stored metrics may be before node status
+// marks them stale.
+func nodeMetricsStaleAfter() time.Duration {
+	if cfg := GetConfig(); cfg != nil && cfg.NodeDefaults.HealthCheckInterval > 0 {
+		return 2 * cfg.NodeDefaults.HealthCheckInterval
+	}
+	return 2 * time.Minute
+}
+
+func formatBytes(value int64) string {
+	const unit = 1024
+	if value < unit {
+		return fmt.Sprintf("%d B", value)
+	}
+	div, exp := int64(unit), 0
+	for n := value / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	suffixes := []string{"KB", "MB", "GB", "TB", "PB", "EB"}
+	if exp >= len(suffixes) {
+		exp = len(suffixes) - 1
+	}
+	return fmt.Sprintf("%.1f %s", float64(value)/float64(div), suffixes[exp])
+}
+
 var nodeExecCmd = &cobra.Command{
 	Use:   "exec <name-or-id> -- <command>",
 	Short: "Execute command on node",
@@ -526,7 +825,7 @@ The command must be specified after the -- separator.`,
 	Args:               cobra.MinimumNArgs(1),
 	DisableFlagParsing: false,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// Find the -- separator
 		dashIdx := -1
@@ -630,3 +929,10 @@ The command must be specified after the -- separator.`,
 		return nil
 	},
 }
+
+func formatNodeLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	return node.FormatLabels(labels)
+}
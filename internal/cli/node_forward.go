@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net"
 	"os"
-	"os/signal"
 	"strconv"
 	"strings"
 
@@ -46,8 +45,7 @@ The local bind defaults to 127.0.0.1 for safety.`,
   swarm node forward prod-server --local-host 0.0.0.0 --local-port 9090 --remote 127.0.0.1:9090`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-		defer stop()
+		ctx := cmd.Context()
 
 		nameOrID := args[0]
 		remoteHost, remotePort, err := parseForwardTarget(nodeForwardRemote)
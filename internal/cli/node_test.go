@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/schema"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+)
+
+func startAuthDaemon(t *testing.T, token string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	unary, stream := swarmd.AuthInterceptors(token)
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(unary), grpc.ChainStreamInterceptor(stream))
+	swarmdv1.RegisterSwarmdServiceServer(grpcServer, swarmd.NewServer(zerolog.Nop(), swarmd.WithVersion("2.0.0")))
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	return listener.Addr().String()
+}
+
+func TestNodeAddDaemonAndStatus(t *testing.T) {
+	useTestDatabase(t)
+	addr := startAuthDaemon(t, "tok")
+
+	if code, err := runCommand(t, nodeAddCmd, "--name", "gpu", "--daemon", addr, "--token", "wrong"); code == 0 {
+		t.Fatalf("expected a wrong token to fail the connection test, got %v", err)
+	}
+	captureStdout(t, func() {
+		if code, err := runCommand(t, nodeAddCmd, "--name", "gpu", "--daemon", addr, "--token", "tok"); code != 0 {
+			t.Errorf("node add --daemon failed with exit %d: %v", code, err)
+		}
+	})
+
+	out := runJSONCommand(t, nodeStatusCmd, "gpu")
+	doc, err := outputSchema("node-status")
+	if err != nil {
+		t.Fatalf("outputSchema: %v", err)
+	}
+	if err := schema.Validate(doc, out); err != nil {
+		t.Fatalf("output does not match schema: %v\n%s", err, out)
+	}
+	var view nodeStatusView
+	if err := json.Unmarshal(out, &view); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if view.Status != "online" || view.DaemonEndpoint != addr || view.Daemon == nil || view.Daemon.Version != "2.0.0" {
+		t.Fatalf("expected the daemon's status, got %+v", view)
+	}
+}
+
+func TestNodeAddDaemonFlagConflicts(t *testing.T) {
+	useTestDatabase(t)
+
+	for _, args := range [][]string{
+		{"--name", "gpu", "--daemon", "gpu:50051", "--local"},
+		{"--name", "gpu", "--daemon", "gpu:50051", "--ssh", "user@gpu"},
+		{"--name", "gpu", "--ssh", "user@gpu", "--token", "tok"},
+	} {
+		if code, _ := runCommand(t, nodeAddCmd, args...); code == 0 {
+			t.Errorf("expected node add %v to fail", args)
+		}
+	}
+	if code, err := runCommand(t, nodeAddCmd, "--name", "gpu", "--daemon", "gpu", "--no-test"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d for an endpoint without a port, got %d (err=%v)", ExitCodeInvalidInput, code, err)
+	}
+}
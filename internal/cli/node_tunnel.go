@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/signal"
 
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/node"
@@ -45,8 +44,7 @@ This is a convenience wrapper around port forwarding using the swarmd defaults.`
   swarm node tunnel prod-server --remote 127.0.0.1:60000`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-		defer stop()
+		ctx := cmd.Context()
 
 		nameOrID := args[0]
 		remoteHost, remotePort, err := parseForwardTarget(nodeTunnelRemote)
@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+	noteCmd.AddCommand(noteAddCmd)
+	noteCmd.AddCommand(noteListCmd)
+	noteCmd.AddCommand(noteRmCmd)
+}
+
+var noteCmd = &cobra.Command{
+	Use:   "note",
+	Short: "Leave notes on workspaces and agents",
+	Long: `Leave free-text notes on workspaces and agents, for yourself or whoever
+picks the swarm up next ("retrying the flaky migration task, don't kill it").
+
+The newest note shows in agent status and ws status, and adding one
+publishes a note.added event to the workspace feed. Notes outlive what they
+are about: the notes of a terminated agent or removed workspace can still
+be listed.`,
+}
+
+var noteAddCmd = &cobra.Command{
+	Use:   "add <agent|ws> <id> <text>",
+	Short: "Add a note to a workspace or agent",
+	Long: `Add a note to a workspace or agent. Workspaces are named by name, ID, or
+prefix; agents by ID or ID prefix, including terminated agents. The author
+is taken from $USER.`,
+	Example: `  swarm note add agent abc123 "retrying the flaky migration task, don't kill it"
+  swarm note add ws my-project "frozen until the release branch is cut"`,
+	Args: cobra.MinimumNArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		text := strings.TrimSpace(strings.Join(args[2:], " "))
+		if text == "" {
+			return invalidInputError("note text required")
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		note := &models.Note{Author: os.Getenv("USER"), Text: text}
+		switch strings.ToLower(args[0]) {
+		case "agent":
+			target, err := findAgent(ctx, db.NewAgentRepository(database), args[1], db.IncludeDeleted())
+			if err != nil {
+				return err
+			}
+			note.EntityType, note.EntityID = models.EntityTypeAgent, target.ID
+		case "ws", "workspace":
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), args[1])
+			if err != nil {
+				return err
+			}
+			note.EntityType, note.EntityID = models.EntityTypeWorkspace, ws.ID
+		default:
+			return invalidInputError("unknown note target %q (use agent or ws)", args[0])
+		}
+
+		if err := db.NewNoteRepository(database).Create(ctx, note); err != nil {
+			return wrapServiceError(err, "failed to add note")
+		}
+
+		if publisher := newEventPublisher(database); publisher != nil {
+			payload, err := json.Marshal(models.NoteAddedPayload{NoteID: note.ID, Author: note.Author, Text: note.Text})
+			if err != nil {
+				return wrapServiceError(err, "failed to marshal note payload")
+			}
+			publisher.Publish(ctx, &models.Event{
+				Type:       models.EventTypeNoteAdded,
+				EntityType: note.EntityType,
+				EntityID:   note.EntityID,
+				Payload:    payload,
+			})
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, note)
+		}
+		fmt.Printf("✓ Added note %s to %s %s\n", shortID(note.ID), note.EntityType, shortID(note.EntityID))
+		return nil
+	},
+}
+
+var noteListCmd = &cobra.Command{
+	Use:   "list <id>",
+	Short: "List the notes on a workspace or agent",
+	Long: `List the notes on a workspace or agent, oldest first. The ID is resolved
+as a workspace first, then as an agent, including terminated agents. The
+full ID of a removed workspace or agent still lists its notes.`,
+	Example: `  swarm note list my-project
+  swarm note list abc123 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		notes, err := listEntityNotes(ctx, database, args[0])
+		if err != nil {
+			return err
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, notes)
+		}
+
+		if len(notes) == 0 {
+			fmt.Println("No notes found")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(notes))
+		for _, note := range notes {
+			author := note.Author
+			if author == "" {
+				author = "-"
+			}
+			rows = append(rows, []string{
+				shortID(note.ID),
+				string(note.EntityType),
+				author,
+				formatRelativeTime(note.CreatedAt),
+				strings.Join(strings.Fields(note.Text), " "),
+			})
+		}
+		return writeTable(os.Stdout, []string{"ID", "ON", "AUTHOR", "ADDED", "NOTE"}, rows)
+	},
+}
+
+var noteRmCmd = &cobra.Command{
+	Use:     "rm <note-id>",
+	Aliases: []string{"remove"},
+	Short:   "Remove a note",
+	Long:    "Remove a note, named by its ID or a unique ID prefix.",
+	Example: `  swarm note rm 3f2a9c1d`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		repo := db.NewNoteRepository(database)
+		note, err := findNote(ctx, repo, args[0])
+		if err != nil {
+			return err
+		}
+		if err := repo.Delete(ctx, note.ID); err != nil {
+			return wrapServiceError(err, "failed to remove note")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, note)
+		}
+		fmt.Printf("✓ Removed note %s from %s %s\n", shortID(note.ID), note.EntityType, shortID(note.EntityID))
+		return nil
+	},
+}
+
+// listEntityNotes lists the notes on the workspace or agent named by
+// idOrName. Names that resolve to neither fall back to notes stored under
+// that exact ID, so removed entities stay readable.
+func listEntityNotes(ctx context.Context, database *db.DB, idOrName string) ([]*models.Note, error) {
+	repo := db.NewNoteRepository(database)
+
+	ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), idOrName)
+	if err == nil {
+		return listNotes(ctx, repo, models.EntityTypeWorkspace, ws.ID)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	agent, err := findAgent(ctx, db.NewAgentRepository(database), idOrName, db.IncludeDeleted())
+	if err == nil {
+		return listNotes(ctx, repo, models.EntityTypeAgent, agent.ID)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	notes := make([]*models.Note, 0)
+	for _, entityType := range []models.EntityType{models.EntityTypeWorkspace, models.EntityTypeAgent} {
+		found, err := listNotes(ctx, repo, entityType, idOrName)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, found...)
+	}
+	if len(notes) == 0 {
+		return nil, notFoundError("no workspace or agent '%s' found", idOrName)
+	}
+	return notes, nil
+}
+
+func listNotes(ctx context.Context, repo *db.NoteRepository, entityType models.EntityType, entityID string) ([]*models.Note, error) {
+	notes, err := repo.ListByEntity(ctx, entityType, entityID)
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list notes")
+	}
+	if notes == nil {
+		notes = make([]*models.Note, 0)
+	}
+	return notes, nil
+}
+
+// latestNote returns the newest note on a workspace or agent, or nil
+// when it has none.
+func latestNote(ctx context.Context, database *db.DB, entityType models.EntityType, entityID string) (*models.Note, error) {
+	note, err := db.NewNoteRepository(database).Latest(ctx, entityType, entityID)
+	if errors.Is(err, db.ErrNoteNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to load notes")
+	}
+	return note, nil
+}
+
+// formatNote renders a note on one line for status output.
+func formatNote(note *models.Note) string {
+	line := fmt.Sprintf("%s (%s", strings.Join(strings.Fields(note.Text), " "), formatRelativeTime(note.CreatedAt))
+	if note.Author != "" {
+		line += " by " + note.Author
+	}
+	return line + ")"
+}
+
+func findNote(ctx context.Context, repo *db.NoteRepository, idOrPrefix string) (*models.Note, error) {
+	idOrPrefix = strings.TrimSpace(idOrPrefix)
+	if idOrPrefix == "" {
+		return nil, invalidInputError("note ID required")
+	}
+
+	note, err := repo.Get(ctx, idOrPrefix)
+	if err == nil {
+		return note, nil
+	}
+	if !errors.Is(err, db.ErrNoteNotFound) {
+		return nil, wrapServiceError(err, "failed to get note")
+	}
+
+	notes, err := repo.List(ctx)
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list notes")
+	}
+	matches := make([]*models.Note, 0)
+	for _, candidate := range notes {
+		if strings.HasPrefix(candidate.ID, idOrPrefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, notFoundError("note '%s' not found", idOrPrefix)
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, match := range matches {
+			ids = append(ids, shortID(match.ID))
+		}
+		return nil, invalidInputError("note '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrPrefix, strings.Join(ids, ", "))
+	}
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestNoteAddResolvesEntities(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+	t.Setenv("USER", "dana")
+
+	agentRepo := db.NewAgentRepository(database)
+	for _, id := range []string{"f00d-1", "f00d-2"} {
+		if err := agentRepo.Create(ctx, &models.Agent{ID: id, WorkspaceID: agent.WorkspaceID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-repo:0." + id, State: models.AgentStateIdle}); err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+	}
+
+	captureStdout(t, func() {
+		if code, err := runCommand(t, noteAddCmd, "agent", shortID(agent.ID), "retrying the flaky migration task,", "don't kill it"); code != 0 {
+			t.Errorf("note add agent failed with exit %d: %v", code, err)
+		}
+		if code, err := runCommand(t, noteAddCmd, "ws", shortID(agent.WorkspaceID), "frozen until the release"); code != 0 {
+			t.Errorf("note add ws failed with exit %d: %v", code, err)
+		}
+	})
+
+	notes := db.NewNoteRepository(database)
+	agentNotes, err := notes.ListByEntity(ctx, models.EntityTypeAgent, agent.ID)
+	if err != nil {
+		t.Fatalf("list notes: %v", err)
+	}
+	if len(agentNotes) != 1 || agentNotes[0].Author != "dana" || agentNotes[0].Text != "retrying the flaky migration task, don't kill it" {
+		t.Fatalf("expected the agent note by $USER, got %+v", agentNotes)
+	}
+	if wsNotes, err := notes.ListByEntity(ctx, models.EntityTypeWorkspace, agent.WorkspaceID); err != nil || len(wsNotes) != 1 {
+		t.Fatalf("expected one workspace note, got %d, %v", len(wsNotes), err)
+	}
+
+	noteAdded := models.EventTypeNoteAdded
+	page, err := db.NewEventRepository(database).Query(ctx, db.EventQuery{Type: &noteAdded})
+	if err != nil {
+		t.Fatalf("query events: %v", err)
+	}
+	if len(page.Events) != 2 {
+		t.Fatalf("expected a note.added event per note, got %d", len(page.Events))
+	}
+	for _, event := range page.Events {
+		if event.EntityType != models.EntityTypeAgent {
+			continue
+		}
+		var payload models.NoteAddedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil || event.EntityID != agent.ID || payload.NoteID != agentNotes[0].ID || payload.Author != "dana" {
+			t.Fatalf("unexpected agent note event %+v: %v", event, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "ambiguous agent prefix", args: []string{"agent", "f00d", "x"}, want: ExitCodeInvalidInput},
+		{name: "unknown agent", args: []string{"agent", "nope", "x"}, want: ExitCodeNotFound},
+		{name: "unknown workspace", args: []string{"ws", "nope", "x"}, want: ExitCodeNotFound},
+		{name: "unknown target kind", args: []string{"node", "local", "x"}, want: ExitCodeInvalidInput},
+		{name: "blank text", args: []string{"agent", agent.ID, " "}, want: ExitCodeInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, err := runCommand(t, noteAddCmd, tt.args...); code != tt.want {
+				t.Fatalf("expected exit %d, got %d: %v", tt.want, code, err)
+			}
+		})
+	}
+}
+
+func TestNoteListKeepsRemovedEntities(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+
+	notes := db.NewNoteRepository(database)
+	agentNote := &models.Note{EntityType: models.EntityTypeAgent, EntityID: agent.ID, Text: "retrying the flaky migration task"}
+	wsNote := &models.Note{EntityType: models.EntityTypeWorkspace, EntityID: agent.WorkspaceID, Text: "frozen until the release"}
+	for _, note := range []*models.Note{agentNote, wsNote} {
+		if err := notes.Create(ctx, note); err != nil {
+			t.Fatalf("create note: %v", err)
+		}
+	}
+
+	listNoteIDs := func(t *testing.T, id string) []string {
+		t.Helper()
+		var listed []*models.Note
+		if err := json.Unmarshal(runJSONCommand(t, noteListCmd, id), &listed); err != nil {
+			t.Fatalf("decode notes: %v", err)
+		}
+		ids := make([]string, 0, len(listed))
+		for _, note := range listed {
+			ids = append(ids, note.ID)
+		}
+		return ids
+	}
+
+	// A terminated agent still resolves by prefix.
+	if err := db.NewAgentRepository(database).Delete(ctx, agent.ID); err != nil {
+		t.Fatalf("delete agent: %v", err)
+	}
+	if ids := listNoteIDs(t, shortID(agent.ID)); len(ids) != 1 || ids[0] != agentNote.ID {
+		t.Fatalf("expected the terminated agent's note, got %v", ids)
+	}
+
+	// A workspace resolves by prefix, and once removed by its full ID.
+	if ids := listNoteIDs(t, shortID(agent.WorkspaceID)); len(ids) != 1 || ids[0] != wsNote.ID {
+		t.Fatalf("expected the workspace note, got %v", ids)
+	}
+	if err := db.NewWorkspaceRepository(database).Delete(ctx, agent.WorkspaceID); err != nil {
+		t.Fatalf("delete workspace: %v", err)
+	}
+	if ids := listNoteIDs(t, agent.WorkspaceID); len(ids) != 1 || ids[0] != wsNote.ID {
+		t.Fatalf("expected the removed workspace's note, got %v", ids)
+	}
+	if code, err := runCommand(t, noteListCmd, "nope"); code != ExitCodeNotFound {
+		t.Fatalf("expected not found for an unknown ID, got exit %d: %v", code, err)
+	}
+
+	captureStdout(t, func() {
+		if code, err := runCommand(t, noteRmCmd, shortID(wsNote.ID)); code != 0 {
+			t.Errorf("note rm failed with exit %d: %v", code, err)
+		}
+	})
+	if _, err := notes.Get(ctx, wsNote.ID); err == nil {
+		t.Fatal("expected the note removed")
+	}
+	if code, err := runCommand(t, noteRmCmd, wsNote.ID); code != ExitCodeNotFound {
+		t.Fatalf("expected not found removing twice, got exit %d: %v", code, err)
+	}
+}
@@ -0,0 +1,109 @@
+// Package cli provides the per-invocation options shared by every command.
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Options are the global flags of one command invocation and the streams
+// it writes to. They are resolved from the invocation's own root command,
+// so commands that read them instead of package state can run side by
+// side, each with its own flags and output.
+type Options struct {
+	ConfigFile     string
+	JSON           bool
+	JSONL          bool
+	JSONErrors     bool
+	JSONEnvelope   bool
+	Watch          bool
+	Since          string
+	WatchFormat    string
+	Verbose        bool
+	NoColor        bool
+	NoProgress     bool
+	NonInteractive bool
+	Yes            bool
+	Timeout        time.Duration
+	Width          int
+	Columns        []string
+
+	// Out and Err receive the command's output and diagnostics.
+	Out io.Writer
+	Err io.Writer
+}
+
+type optionsKey struct{}
+
+// resolveOptions reads the global flags from cmd's root command, where
+// they are declared. Flags a subcommand shadows with one of its own (such
+// as 'log --since' or 'agent wait --timeout') keep their global defaults.
+func resolveOptions(cmd *cobra.Command) Options {
+	flags := cmd.Root().PersistentFlags()
+	opts := Options{Out: cmd.OutOrStdout(), Err: cmd.ErrOrStderr()}
+	opts.ConfigFile, _ = flags.GetString("config")
+	opts.JSON, _ = flags.GetBool("json")
+	opts.JSONL, _ = flags.GetBool("jsonl")
+	opts.JSONErrors, _ = flags.GetBool("json-errors")
+	opts.JSONEnvelope, _ = flags.GetBool("json-envelope")
+	opts.Watch, _ = flags.GetBool("watch")
+	opts.Since, _ = flags.GetString("since")
+	opts.WatchFormat, _ = flags.GetString("format")
+	opts.Verbose, _ = flags.GetBool("verbose")
+	opts.NoColor, _ = flags.GetBool("no-color")
+	opts.NoProgress, _ = flags.GetBool("no-progress")
+	opts.NonInteractive, _ = flags.GetBool("non-interactive")
+	opts.Yes, _ = flags.GetBool("yes")
+	opts.Timeout, _ = flags.GetDuration("timeout")
+	opts.Width, _ = flags.GetInt("width")
+	opts.Columns, _ = flags.GetStringSlice("columns")
+	return opts
+}
+
+// withOptions returns ctx carrying opts for the command run under it.
+func withOptions(ctx context.Context, opts Options) context.Context {
+	return context.WithValue(ctx, optionsKey{}, opts)
+}
+
+// commandOptions returns the options of cmd's invocation: those the root
+// pre-run resolved into its context, or resolved now for a command run
+// without it.
+func commandOptions(cmd *cobra.Command) Options {
+	if ctx := cmd.Context(); ctx != nil {
+		if opts, ok := ctx.Value(optionsKey{}).(Options); ok {
+			return opts
+		}
+	}
+	return resolveOptions(cmd)
+}
+
+// rootOptions resolves the options of the process's root command, for
+// commands that do not take theirs from commandOptions yet.
+func rootOptions() Options {
+	return resolveOptions(rootCmd)
+}
+
+// Structured reports whether --json or --jsonl output is requested.
+func (o Options) Structured() bool {
+	return o.JSON || o.JSONL
+}
+
+// stdout returns Out, or os.Stdout for options built without streams.
+func (o Options) stdout() io.Writer {
+	if o.Out == nil {
+		return os.Stdout
+	}
+	return o.Out
+}
+
+// stderr returns Err, or os.Stderr for options built without streams.
+func (o Options) stderr() io.Writer {
+	if o.Err == nil {
+		return os.Stderr
+	}
+	return o.Err
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// setRootFlag sets a global flag on rootCmd, for code that still reads the
+// root's flags, and restores it when the test ends.
+func setRootFlag(t *testing.T, name, value string) {
+	t.Helper()
+	flag := rootCmd.PersistentFlags().Lookup(name)
+	if flag == nil {
+		t.Fatalf("no global flag --%s", name)
+	}
+	previous, changed := flag.Value.String(), flag.Changed
+	if err := flag.Value.Set(value); err != nil {
+		t.Fatalf("set --%s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		_ = flag.Value.Set(previous)
+		flag.Changed = changed
+	})
+}
+
+// optionsCommandFiles build their commands per invocation and take the
+// global flags from commandOptions. New command files belong here.
+var optionsCommandFiles = []string{
+	"accounts.go", "accounts_status.go",
+	"agent.go", "agent_batch.go", "agent_bundle.go", "agent_checkpoint.go", "agent_cost.go",
+	"agent_drain.go", "agent_env.go", "agent_helpers.go", "agent_move.go", "agent_peek.go",
+	"agent_record.go", "agent_send_sensitive.go", "agent_snapshot.go", "agent_stats.go",
+	"agent_transcript_push.go", "agent_verify_panes.go", "agent_versions.go", "agent_wait.go",
+	"agent_wake.go",
+	"lock.go",
+	"providers.go",
+	"queue.go", "queue_add.go", "queue_batch.go", "queue_clear.go",
+	"snapshot.go",
+	"workspace.go", "workspace_bootstrap.go", "workspace_broadcast.go", "workspace_clone.go",
+	"workspace_drain.go", "workspace_feed.go", "workspace_layout.go", "workspace_pause.go",
+	"workspace_sessions.go", "workspace_set.go",
+}
+
+// optionsCommandVars are the package variables those files may declare:
+// lookup tables and the seams tests replace, none of them flag state.
+var optionsCommandVars = map[string]bool{
+	"accountFilterFields":   true,
+	"agentFilterFields":     true,
+	"wsFilterFields":        true,
+	"spawnProbe":            true,
+	"layoutTmuxClient":      true,
+	"verifyPanesTmuxClient": true,
+}
+
+// rootOptionReaders read the options of the process's root command rather
+// than those of the invocation.
+var rootOptionReaders = map[string]bool{
+	"rootOptions": true, "IsJSONOutput": true, "IsJSONLOutput": true, "IsWatchMode": true,
+	"GetWatchFormat": true, "IsVerbose": true, "GetSinceFlag": true, "GetSinceTime": true,
+	"MustBeJSONLForWatch": true, "NewFormatter": true, "WriteOutput": true, "WriteOutputStream": true,
+	"PrintNextSteps": true, "writeQueueResults": true, "startProgress": true, "progressNote": true,
+	"progressEnabled": true, "IsNonInteractive": true, "IsInteractive": true, "SkipConfirmation": true,
+	"ConfirmAction": true, "ConfirmDestructiveAction": true, "requireConfirmation": true,
+	"colorEnabled": true, "watchEventOutput": true, "writeTable": true,
+}
+
+func TestCommandFilesKeepNoFlagState(t *testing.T) {
+	fset := token.NewFileSet()
+	for _, name := range optionsCommandFiles {
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, ident := range spec.(*ast.ValueSpec).Names {
+					if !optionsCommandVars[ident.Name] {
+						t.Errorf("%s: package variable %s; commands keep their state in the constructor", fset.Position(ident.Pos()), ident.Name)
+					}
+				}
+			}
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			switch fn := call.Fun.(type) {
+			case *ast.Ident:
+				if rootOptionReaders[fn.Name] {
+					t.Errorf("%s: %s reads the root command's options; use commandOptions", fset.Position(fn.Pos()), fn.Name)
+				}
+			case *ast.SelectorExpr:
+				if pkg, ok := fn.X.(*ast.Ident); ok && pkg.Name == "fmt" && strings.HasPrefix(fn.Sel.Name, "Print") {
+					t.Errorf("%s: fmt.%s writes to the process's stdout; write to opts.Out", fset.Position(fn.Pos()), fn.Sel.Name)
+				}
+			}
+			return true
+		})
+	}
+}
+
+func TestCommandsRunInParallel(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+	account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "primary", CredentialRef: "env:ANTHROPIC_API_KEY", IsActive: true}
+	if err := db.NewAccountRepository(database).Create(ctx, account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		cmd   func() *cobra.Command
+		args  []string
+		json  bool
+		wants string
+	}{
+		{name: "agent list json", cmd: newAgentListCmd, args: []string{"--json"}, json: true, wants: agent.ID},
+		{name: "agent list", cmd: newAgentListCmd, wants: shortID(agent.ID)},
+		{name: "ws list json", cmd: newWsListCmd, args: []string{"--json"}, json: true, wants: agent.WorkspaceID},
+		{name: "ws list", cmd: newWsListCmd, wants: "swarm-repo"},
+		{name: "accounts list json", cmd: newAccountsListCmd, args: []string{"--json"}, json: true, wants: account.ID},
+		{name: "accounts list", cmd: newAccountsListCmd, wants: "primary"},
+		{name: "queue list json", cmd: newQueueListCmd, args: []string{"--json", "--agent", agent.ID}, json: true},
+		{name: "queue list", cmd: newQueueListCmd, args: []string{"--agent", agent.ID}},
+	}
+	for _, tt := range tests {
+		for _, run := range []string{"a", "b"} {
+			t.Run(tt.name+" "+run, func(t *testing.T) {
+				t.Parallel()
+
+				cmd := tt.cmd()
+				var stdout bytes.Buffer
+				cmd.SetOut(&stdout)
+				cmd.SetErr(&bytes.Buffer{})
+				if code, err := runCommand(t, cmd, tt.args...); code != 0 {
+					t.Fatalf("exit %d: %v", code, err)
+				}
+
+				out := stdout.String()
+				if tt.json != json.Valid(stdout.Bytes()) {
+					t.Fatalf("expected JSON output %v, got:\n%s", tt.json, out)
+				}
+				if !strings.Contains(out, tt.wants) {
+					t.Fatalf("expected %q in output:\n%s", tt.wants, out)
+				}
+			})
+		}
+	}
+}
@@ -2,30 +2,45 @@
 package cli
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"syscall"
+
+	"github.com/opencode-ai/swarm/internal/schema"
 )
 
 // Formatter renders command output as human-readable, JSON, or JSONL.
 type Formatter struct {
-	out   io.Writer
-	json  bool
-	jsonl bool
+	out      io.Writer
+	json     bool
+	jsonl    bool
+	envelope bool
 }
 
-// NewFormatter builds a formatter using the current CLI flags.
+// NewFormatter builds a formatter using the root command's flags.
 func NewFormatter(out io.Writer) *Formatter {
+	return rootOptions().formatter(out)
+}
+
+// formatter builds a formatter writing to out as the options ask.
+func (o Options) formatter(out io.Writer) *Formatter {
 	return &Formatter{
-		out:   out,
-		json:  IsJSONOutput(),
-		jsonl: IsJSONLOutput(),
+		out:      out,
+		json:     o.JSON,
+		jsonl:    o.JSONL,
+		envelope: o.JSONEnvelope,
 	}
 }
 
 // Write formats and writes output based on CLI flags.
 func (f *Formatter) Write(value any) error {
+	if f.envelope && (f.json || f.jsonl) {
+		value = wrapEnvelope(value, f.jsonl)
+	}
 	if f.jsonl {
 		return writeJSONL(f.out, value)
 	}
@@ -40,6 +55,148 @@ func WriteOutput(out io.Writer, value any) error {
 	return NewFormatter(out).Write(value)
 }
 
+// WriteOutput formats value to the options' output stream.
+func (o Options) WriteOutput(value any) error {
+	return o.formatter(o.stdout()).Write(value)
+}
+
+// RowIterator yields rows for WriteOutputStream one at a time.
+type RowIterator interface {
+	Next() bool
+	Row() any
+	Err() error
+}
+
+// streamFlushRows is how many rows WriteOutputStream buffers between flushes.
+const streamFlushRows = 64
+
+// WriteOutputStream writes rows as the iterator produces them instead of
+// collecting them first: one line per row for JSONL, an incrementally
+// written array for JSON. A reader that goes away (EPIPE, closed pipe)
+// ends the stream without an error.
+func WriteOutputStream(out io.Writer, it RowIterator) error {
+	return rootOptions().writeOutputStream(out, it)
+}
+
+// WriteOutputStream streams rows to the options' output stream, as the
+// package function does.
+func (o Options) WriteOutputStream(it RowIterator) error {
+	return o.writeOutputStream(o.stdout(), it)
+}
+
+func (o Options) writeOutputStream(out io.Writer, it RowIterator) error {
+	err := writeStream(out, it, o.JSON, o.JSONL, o.JSONEnvelope)
+	if isBrokenPipe(err) {
+		return nil
+	}
+	return err
+}
+
+func writeStream(out io.Writer, it RowIterator, asJSON, asJSONL, envelope bool) error {
+	// JSON output is an array, or an envelope holding one; the text around
+	// the rows matches what writeJSON would produce for the whole slice.
+	open, indent, tail := "[", "", "]\n"
+	if envelope {
+		if asJSONL {
+			it = envelopeRows{it}
+		} else {
+			open = fmt.Sprintf("{\n  \"schema_version\": %q,\n  \"data\": [", schema.Version)
+			indent, tail = "  ", "]\n}\n"
+		}
+	}
+
+	buf := bufio.NewWriter(out)
+	if asJSON && !asJSONL {
+		if _, err := buf.WriteString(open); err != nil {
+			return err
+		}
+	}
+
+	rows := 0
+	for it.Next() {
+		var err error
+		switch {
+		case asJSONL:
+			err = writeJSONLine(buf, it.Row())
+		case asJSON:
+			err = writeJSONArrayElement(buf, it.Row(), indent, rows == 0)
+		default:
+			err = writeHuman(buf, it.Row())
+		}
+		if err != nil {
+			return err
+		}
+		rows++
+		if rows%streamFlushRows == 0 {
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		// Emit what was already produced before reporting the failure.
+		_ = buf.Flush()
+		return err
+	}
+
+	if asJSON && !asJSONL {
+		if rows > 0 {
+			tail = "\n" + indent + tail
+		}
+		if _, err := buf.WriteString(tail); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+func writeJSONArrayElement(out io.Writer, value any, indent string, first bool) error {
+	data, err := json.MarshalIndent(value, indent+"  ", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	sep := ",\n"
+	if first {
+		sep = "\n"
+	}
+	_, err = fmt.Fprint(out, sep, indent, "  ", string(data))
+	return err
+}
+
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrClosedPipe)
+}
+
+// OutputEnvelope wraps JSON output with the schema version it conforms to
+// when --json-envelope is set.
+type OutputEnvelope struct {
+	SchemaVersion string `json:"schema_version"`
+	Data          any    `json:"data"`
+}
+
+// wrapEnvelope wraps value, or each element of a slice for JSONL so every
+// line carries the version.
+func wrapEnvelope(value any, perElement bool) any {
+	val := reflect.ValueOf(value)
+	if perElement && val.IsValid() && (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) {
+		wrapped := make([]OutputEnvelope, val.Len())
+		for i := range wrapped {
+			wrapped[i] = OutputEnvelope{SchemaVersion: schema.Version, Data: val.Index(i).Interface()}
+		}
+		return wrapped
+	}
+	return OutputEnvelope{SchemaVersion: schema.Version, Data: value}
+}
+
+// envelopeRows wraps each streamed row in an OutputEnvelope.
+type envelopeRows struct {
+	RowIterator
+}
+
+func (r envelopeRows) Row() any {
+	return OutputEnvelope{SchemaVersion: schema.Version, Data: r.RowIterator.Row()}
+}
+
 func writeJSON(out io.Writer, value any) error {
 	data, err := json.MarshalIndent(value, "", "  ")
 	if err != nil {
@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"bufio"
 	"bytes"
+	"io"
 	"testing"
 )
 
@@ -11,16 +13,8 @@ type sampleOutput struct {
 }
 
 func TestFormatterJSON(t *testing.T) {
-	t.Cleanup(func() {
-		jsonOutput = false
-		jsonlOutput = false
-	})
-
-	jsonOutput = true
-	jsonlOutput = false
-
 	var buf bytes.Buffer
-	formatter := NewFormatter(&buf)
+	formatter := Options{JSON: true}.formatter(&buf)
 	if err := formatter.Write(sampleOutput{Name: "alpha", Count: 2}); err != nil {
 		t.Fatalf("Write failed: %v", err)
 	}
@@ -32,16 +26,8 @@ func TestFormatterJSON(t *testing.T) {
 }
 
 func TestFormatterJSONL(t *testing.T) {
-	t.Cleanup(func() {
-		jsonOutput = false
-		jsonlOutput = false
-	})
-
-	jsonOutput = false
-	jsonlOutput = true
-
 	var buf bytes.Buffer
-	formatter := NewFormatter(&buf)
+	formatter := Options{JSONL: true}.formatter(&buf)
 	payload := []sampleOutput{
 		{Name: "alpha", Count: 1},
 		{Name: "beta", Count: 2},
@@ -57,16 +43,8 @@ func TestFormatterJSONL(t *testing.T) {
 }
 
 func TestFormatterHuman(t *testing.T) {
-	t.Cleanup(func() {
-		jsonOutput = false
-		jsonlOutput = false
-	})
-
-	jsonOutput = false
-	jsonlOutput = false
-
 	var buf bytes.Buffer
-	formatter := NewFormatter(&buf)
+	formatter := Options{}.formatter(&buf)
 	if err := formatter.Write("hello"); err != nil {
 		t.Fatalf("Write failed: %v", err)
 	}
@@ -75,3 +53,88 @@ func TestFormatterHuman(t *testing.T) {
 		t.Fatalf("unexpected human output: %q", buf.String())
 	}
 }
+
+// countingRows produces n rows on demand and records how far the writer
+// lagged behind production.
+type countingRows struct {
+	n        int
+	produced int
+	out      *lineCounter
+	maxLag   int
+}
+
+func (c *countingRows) Next() bool {
+	if c.produced >= c.n {
+		return false
+	}
+	if lag := c.produced - c.out.lines; lag > c.maxLag {
+		c.maxLag = lag
+	}
+	c.produced++
+	return true
+}
+
+func (c *countingRows) Row() any   { return sampleOutput{Name: "row", Count: c.produced} }
+func (c *countingRows) Err() error { return nil }
+
+type lineCounter struct {
+	lines int
+}
+
+func (w *lineCounter) Write(p []byte) (int, error) {
+	w.lines += bytes.Count(p, []byte("\n"))
+	return len(p), nil
+}
+
+func TestWriteOutputStreamJSONLBoundedBuffer(t *testing.T) {
+	out := &lineCounter{}
+	rows := &countingRows{n: 10000, out: out}
+	if err := (Options{JSONL: true}).writeOutputStream(out, rows); err != nil {
+		t.Fatalf("WriteOutputStream failed: %v", err)
+	}
+
+	if out.lines != rows.n {
+		t.Fatalf("expected %d lines, got %d", rows.n, out.lines)
+	}
+	if rows.maxLag > streamFlushRows {
+		t.Fatalf("writer lagged %d rows behind the iterator, want at most %d", rows.maxLag, streamFlushRows)
+	}
+}
+
+func TestWriteOutputStreamJSON(t *testing.T) {
+	opts := Options{JSON: true}
+	var buf bytes.Buffer
+	if err := opts.writeOutputStream(&buf, &countingRows{n: 2, out: &lineCounter{}}); err != nil {
+		t.Fatalf("WriteOutputStream failed: %v", err)
+	}
+	var expected bytes.Buffer
+	_ = writeJSON(&expected, []sampleOutput{{Name: "row", Count: 1}, {Name: "row", Count: 2}})
+	if buf.String() != expected.String() {
+		t.Fatalf("streamed JSON differs from WriteOutput:\n%s\nwant:\n%s", buf.String(), expected.String())
+	}
+
+	buf.Reset()
+	if err := opts.writeOutputStream(&buf, &countingRows{out: &lineCounter{}}); err != nil {
+		t.Fatalf("WriteOutputStream failed: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Fatalf("unexpected empty JSON output: %q", buf.String())
+	}
+}
+
+func TestWriteOutputStreamStopsOnClosedPipe(t *testing.T) {
+	reader, writer := io.Pipe()
+	go func() {
+		// Behave like `| head -n 1`: read one line, then hang up.
+		_, _ = bufio.NewReader(reader).ReadString('\n')
+		_ = reader.Close()
+	}()
+
+	rows := &countingRows{n: 1000000, out: &lineCounter{}}
+	if err := (Options{JSONL: true}).writeOutputStream(writer, rows); err != nil {
+		t.Fatalf("expected closed pipe to end the stream cleanly, got %v", err)
+	}
+	if rows.produced >= rows.n {
+		t.Fatalf("expected iteration to stop early, produced all %d rows", rows.produced)
+	}
+}
@@ -11,8 +11,10 @@ import (
 	"strings"
 
 	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/fsperm"
 	"github.com/opencode-ai/swarm/internal/models"
 	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/vault"
 	"github.com/opencode-ai/swarm/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -49,10 +51,13 @@ func runPreflight(cmd *cobra.Command) error {
 		return nil
 	}
 
-	ctx := context.Background()
+	ctx := cmd.Context()
 
-	maybeWarnMissingConfig()
+	maybeWarnMissingConfig(commandOptions(cmd))
 
+	if err := checkPermissions(cmd); err != nil {
+		return err
+	}
 	if err := checkTmux(cmd); err != nil {
 		return err
 	}
@@ -71,6 +76,30 @@ func runPreflight(cmd *cobra.Command) error {
 	return nil
 }
 
+// checkPermissions warns when the data directory, database, or vault is
+// open to other users or owned by one, and refuses to run with
+// global.strict_permissions. 'swarm doctor' reports and fixes them itself.
+func checkPermissions(cmd *cobra.Command) error {
+	if appConfig == nil || strings.HasPrefix(cmd.CommandPath(), "swarm doctor") {
+		return nil
+	}
+	if err := fsperm.Verify(logger, appConfig.Global.StrictPermissions, permissionPaths()...); err != nil {
+		return &PreflightError{
+			Message:  "data files are not owner-only",
+			Hint:     "Remove group and other access, or unset global.strict_permissions to only warn",
+			NextStep: "swarm doctor --fix-permissions",
+			Err:      err,
+		}
+	}
+	return nil
+}
+
+// permissionPaths returns the data and vault paths that must be
+// owner-only.
+func permissionPaths() []string {
+	return append(appConfig.PermissionPaths(), vault.PermissionPaths(getVaultPath())...)
+}
+
 func maybeAutoImportWorkspaces(ctx context.Context) {
 	if appConfig == nil || !appConfig.WorkspaceDefaults.AutoImportExisting {
 		return
@@ -90,7 +119,7 @@ func maybeAutoImportWorkspaces(ctx context.Context) {
 	nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
 	wsRepo := db.NewWorkspaceRepository(database)
 	agentRepo := db.NewAgentRepository(database)
-	wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+	wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
 
 	report, err := wsService.RecoverOrphanedSessions(ctx, "", appConfig.WorkspaceDefaults.TmuxPrefix)
 	if err != nil {
@@ -183,27 +212,31 @@ func shouldRunPreflight(cmd *cobra.Command) bool {
 		return false
 	case strings.HasPrefix(path, "swarm vault"):
 		return false
+	case strings.HasPrefix(path, "swarm config"):
+		return false
+	case strings.HasPrefix(path, "swarm agent record sink"):
+		return false
 	}
 
 	return true
 }
 
-func maybeWarnMissingConfig() {
-	if IsJSONOutput() || IsJSONLOutput() {
+func maybeWarnMissingConfig(opts Options) {
+	if opts.Structured() {
 		return
 	}
 	if configLoader == nil {
 		return
 	}
-	if cfgFile != "" {
+	if opts.ConfigFile != "" {
 		return
 	}
 	if configLoader.ConfigFileUsed() != "" {
 		return
 	}
 
-	fmt.Fprintln(os.Stderr, "Warning: no config file found.")
-	fmt.Fprintln(os.Stderr, "Hint: run `swarm init` to create a config file.")
+	fmt.Fprintln(opts.stderr(), "Warning: no config file found.")
+	fmt.Fprintln(opts.stderr(), "Hint: run `swarm init` to create a config file.")
 }
 
 func checkTmux(cmd *cobra.Command) error {
@@ -240,9 +273,11 @@ func requiresTmux(cmd *cobra.Command) bool {
 		case "list", "refresh":
 			return false
 		case "create":
-			return !wsCreateNoTmux
+			noTmux, _ := cmd.Flags().GetBool("no-tmux")
+			return !noTmux
 		case "remove":
-			return wsRemoveDestroy
+			destroy, _ := cmd.Flags().GetBool("destroy")
+			return destroy
 		default:
 			return true
 		}
@@ -333,11 +368,11 @@ func checkWorkspacePath(cmd *cobra.Command) error {
 	if cmd.Name() != "create" {
 		return nil
 	}
-	if wsCreatePath == "" {
+	path, _ := cmd.Flags().GetString("path")
+	if path == "" {
 		return nil
 	}
 
-	path := wsCreatePath
 	if path == "." {
 		if cwd, err := os.Getwd(); err == nil {
 			path = cwd
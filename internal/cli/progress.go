@@ -3,10 +3,22 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sync"
 	"time"
 )
 
+// progressOut receives progress output.
+var progressOut io.Writer = os.Stderr
+
+// progressMu guards progress output and openProgress, the step whose line
+// is still waiting for its outcome.
+var (
+	progressMu   sync.Mutex
+	openProgress *progressStep
+)
+
 type progressStep struct {
 	label   string
 	started time.Time
@@ -14,40 +26,90 @@ type progressStep struct {
 }
 
 func startProgress(label string) *progressStep {
-	if !progressEnabled() {
+	return rootOptions().startProgress(label)
+}
+
+func (o Options) startProgress(label string) *progressStep {
+	if !o.progressEnabled() {
 		return nil
 	}
-	fmt.Fprintf(os.Stderr, "%s... ", label)
-	return &progressStep{
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	fmt.Fprintf(progressOut, "%s... ", label)
+	openProgress = &progressStep{
 		label:   label,
 		started: time.Now(),
 		enabled: true,
 	}
+	return openProgress
 }
 
 func (p *progressStep) Done() {
+	p.finish(func() string {
+		return fmt.Sprintf("done (%s)", formatDuration(time.Since(p.started)))
+	})
+}
+
+func (p *progressStep) Fail(err error) {
+	p.finish(func() string {
+		if err != nil {
+			return fmt.Sprintf("failed: %v", err)
+		}
+		return "failed"
+	})
+}
+
+// finish ends the step's line with its outcome, unless the step was
+// already ended, as when the command is interrupted while it runs.
+func (p *progressStep) finish(outcome func() string) {
 	if p == nil || !p.enabled {
 		return
 	}
-	fmt.Fprintf(os.Stderr, "done (%s)\n", formatDuration(time.Since(p.started)))
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	if openProgress != p {
+		return
+	}
+	openProgress = nil
+	fmt.Fprintln(progressOut, outcome())
 }
 
-func (p *progressStep) Fail(err error) {
-	if p == nil || !p.enabled {
+// failOpenProgress ends the line of the step still running, if any, with
+// cause, such as the interrupt that cancelled it.
+func failOpenProgress(cause error) {
+	progressMu.Lock()
+	p := openProgress
+	progressMu.Unlock()
+	if cause == nil {
+		p.Fail(nil)
 		return
 	}
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed: %v\n", err)
+	p.finish(cause.Error)
+}
+
+// progressNote prints a line of progress output between steps.
+func progressNote(note string) {
+	rootOptions().progressNote(note)
+}
+
+func (o Options) progressNote(note string) {
+	if !o.progressEnabled() {
 		return
 	}
-	fmt.Fprintln(os.Stderr, "failed")
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	fmt.Fprintln(progressOut, note)
 }
 
 func progressEnabled() bool {
-	if IsJSONOutput() || IsJSONLOutput() {
+	return rootOptions().progressEnabled()
+}
+
+func (o Options) progressEnabled() bool {
+	if o.Structured() {
 		return false
 	}
-	if noProgress {
+	if o.NoProgress {
 		return false
 	}
 	if _, ok := os.LookupEnv("SWARM_NO_PROGRESS"); ok {
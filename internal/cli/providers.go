@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/providerhealth"
+	"github.com/spf13/cobra"
+)
+
+// providerEventsPageSize is how many provider health events are read per
+// page when replaying the event log.
+const providerEventsPageSize = 500
+
+func newProvidersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "providers",
+		Short: "Inspect AI providers",
+		Long:  "Inspect the AI providers swarm's accounts use.",
+	}
+
+	cmd.AddCommand(newProvidersStatusCmd())
+	return cmd
+}
+
+// providersStatusFlags holds the flags of 'swarm providers status'.
+type providersStatusFlags struct {
+	probe bool
+}
+
+func newProvidersStatusCmd() *cobra.Command {
+	var flags providersStatusFlags
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the health of each provider",
+		Long: `Show whether each provider with an active account is healthy, degraded,
+or down.
+
+swarmd probes providers every daemon.provider_health.interval and records each
+change in the event log; this reads the latest. Providers never probed show as
+unknown. --probe checks every provider now instead, once, without recording
+the result. While a provider is down the scheduler holds dispatch to its
+agents.`,
+		Example: `  swarm providers status
+  swarm providers status --probe
+  swarm providers status --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			var statuses []providerhealth.Status
+			if flags.probe {
+				statuses, err = probeProviders(ctx, database)
+			} else {
+				statuses, err = recordedProviderHealth(ctx, database)
+			}
+			if err != nil {
+				return wrapServiceError(err, "failed to get provider health")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(statuses)
+			}
+
+			if len(statuses) == 0 {
+				fmt.Fprintln(opts.Out, "No providers with active accounts.")
+				return nil
+			}
+
+			rows := make([][]string, 0, len(statuses))
+			for _, status := range statuses {
+				since := "-"
+				if !status.Since.IsZero() {
+					since = formatRelativeTime(status.Since)
+				}
+				reason := status.Reason
+				if reason == "" {
+					reason = "-"
+				}
+				rows = append(rows, []string{string(status.Provider), string(status.Health), since, reason})
+			}
+			return opts.writeTable([]string{"PROVIDER", "HEALTH", "SINCE", "REASON"}, rows)
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.probe, "probe", false, "probe every provider now instead of reading the last recorded health")
+	return cmd
+}
+
+// recordedProviderHealth replays the provider health changes in the event
+// log, adding providers with active accounts never probed as unknown.
+func recordedProviderHealth(ctx context.Context, database *db.DB) ([]providerhealth.Status, error) {
+	eventType := models.EventTypeProviderHealthChanged
+	it := db.NewEventRepository(database).Iterate(ctx, db.EventQuery{Type: &eventType}, providerEventsPageSize)
+	var recorded []*models.Event
+	for it.Next() {
+		recorded = append(recorded, it.Event())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	statuses := providerhealth.Replay(recorded)
+
+	accounts, err := db.NewAccountRepository(database).List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[models.Provider]bool, len(statuses))
+	for _, status := range statuses {
+		known[status.Provider] = true
+	}
+	for _, acct := range accounts {
+		if !acct.IsActive || known[acct.Provider] {
+			continue
+		}
+		known[acct.Provider] = true
+		statuses = append(statuses, providerhealth.Status{Provider: acct.Provider, Health: models.ProviderHealthUnknown})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Provider < statuses[j].Provider })
+	return statuses, nil
+}
+
+// probeProviders probes every provider once with the configured settings.
+func probeProviders(ctx context.Context, database *db.DB) ([]providerhealth.Status, error) {
+	cfg := GetConfig()
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	prober := providerhealth.New(db.NewAccountRepository(database), providerhealth.SettingsFromConfig(cfg.Daemon.ProviderHealth))
+	return prober.Probe(ctx)
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/providerhealth"
+)
+
+func TestProvidersStatusReplaysEventLog(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	for _, account := range []*models.Account{
+		{Provider: models.ProviderAnthropic, ProfileName: "primary", CredentialRef: "env:A", IsActive: true},
+		{Provider: models.ProviderOpenAI, ProfileName: "other", CredentialRef: "env:B", IsActive: true},
+		{Provider: models.ProviderGoogle, ProfileName: "off", CredentialRef: "env:C"},
+	} {
+		if err := db.NewAccountRepository(database).Create(ctx, account); err != nil {
+			t.Fatalf("create account: %v", err)
+		}
+	}
+	eventRepo := db.NewEventRepository(database)
+	for i, health := range []models.ProviderHealth{models.ProviderHealthHealthy, models.ProviderHealthDown} {
+		payload, _ := json.Marshal(models.ProviderHealthChangedPayload{Provider: models.ProviderAnthropic, Health: health, Reason: "API returned HTTP 503"})
+		if err := eventRepo.Create(ctx, &models.Event{
+			Type:       models.EventTypeProviderHealthChanged,
+			Timestamp:  time.Now().UTC().Add(time.Duration(i-2) * time.Minute),
+			EntityType: models.EntityTypeSystem,
+			EntityID:   string(models.ProviderAnthropic),
+			Payload:    payload,
+		}); err != nil {
+			t.Fatalf("create event: %v", err)
+		}
+	}
+
+	cmd := newProvidersStatusCmd()
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	if code, err := runCommand(t, cmd); code != 0 {
+		t.Fatalf("providers status failed with exit %d: %v", code, err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows:\n%s", stdout.String())
+	}
+	if !strings.Contains(lines[1], "anthropic") || !strings.Contains(lines[1], "down") || !strings.Contains(lines[1], "HTTP 503") {
+		t.Fatalf("expected anthropic down:\n%s", stdout.String())
+	}
+	if !strings.Contains(lines[2], "openai") || !strings.Contains(lines[2], "unknown") {
+		t.Fatalf("expected openai unknown:\n%s", stdout.String())
+	}
+
+	cmd = newProvidersStatusCmd()
+	stdout.Reset()
+	cmd.SetOut(&stdout)
+	if code, err := runCommand(t, cmd, "--json"); code != 0 {
+		t.Fatalf("providers status --json failed with exit %d: %v", code, err)
+	}
+	var statuses []providerhealth.Status
+	if err := json.Unmarshal(stdout.Bytes(), &statuses); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout.String())
+	}
+	if len(statuses) != 2 || statuses[0].Health != models.ProviderHealthDown {
+		t.Fatalf("unexpected statuses %+v", statuses)
+	}
+}
@@ -1,11 +1,15 @@
 package cli
 
 import (
+	"context"
 	"strings"
 
+	"github.com/opencode-ai/swarm/internal/audit"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/events"
 	"github.com/opencode-ai/swarm/internal/hooks"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/redact"
 )
 
 func newEventPublisher(database *db.DB) events.Publisher {
@@ -24,3 +28,41 @@ func newEventPublisher(database *db.DB) events.Publisher {
 
 	return publisher
 }
+
+// newAuditRecorder records mutations made from this process as the local
+// OS user, redacting parameters with the configured rules.
+func newAuditRecorder(database *db.DB) *audit.Recorder {
+	if database == nil {
+		return nil
+	}
+
+	return audit.New(database, audit.WithRedactor(configuredRedactor()))
+}
+
+// configuredRedactor returns the redactor built from the configured rules,
+// or the default one when there is no config or it is invalid.
+func configuredRedactor() *redact.Redactor {
+	redactor := redact.Default()
+	if cfg := GetConfig(); cfg != nil {
+		configured, err := redact.FromConfig(cfg.Redaction)
+		if err != nil {
+			logger.Warn().Err(err).Msg("invalid redaction config; redacting with the default rules")
+		} else {
+			redactor = configured
+		}
+	}
+	return redactor
+}
+
+// auditLocal records an operation made by a command that does not
+// otherwise touch the database. Being unable to open it is logged and
+// never fails the command.
+func auditLocal(ctx context.Context, operation string, entityType models.EntityType, entityID string, params map[string]string, opErr error) {
+	database, err := openDatabase()
+	if err != nil {
+		logger.Error().Err(err).Str("operation", operation).Msg("failed to open the audit log; operation was not audited")
+		return
+	}
+	defer database.Close()
+	newAuditRecorder(database).Record(ctx, operation, entityType, entityID, params, opErr)
+}
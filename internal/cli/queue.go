@@ -4,9 +4,7 @@ package cli
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 	"time"
@@ -17,129 +15,137 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var (
-	queueAgent  string
-	queueStatus string
-	queueLimit  int
-	queueAll    bool
-)
-
-func init() {
-	rootCmd.AddCommand(queueCmd)
-	queueCmd.AddCommand(queueListCmd)
+func newQueueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Manage agent queues",
+		Long:  "Inspect and manage queued messages for agents.",
+	}
 
-	queueListCmd.Flags().StringVarP(&queueAgent, "agent", "a", "", "filter by agent ID or prefix")
-	queueListCmd.Flags().StringVar(&queueStatus, "status", "", "filter by status (pending, blocked, dispatched, completed, failed, skipped)")
-	queueListCmd.Flags().IntVarP(&queueLimit, "limit", "n", 20, "max items to show per agent (0 = unlimited)")
-	queueListCmd.Flags().BoolVar(&queueAll, "all", false, "show all items including completed")
+	cmd.AddCommand(newQueueListCmd())
+	cmd.AddCommand(newQueueAddCmd())
+	cmd.AddCommand(newQueueClearCmd())
+	return cmd
 }
 
-var queueCmd = &cobra.Command{
-	Use:   "queue",
-	Short: "Manage agent queues",
-	Long:  "Inspect and manage queued messages for agents.",
+// queueListFlags holds the flags of 'swarm queue ls'.
+type queueListFlags struct {
+	agent  string
+	status string
+	limit  int
+	all    bool
 }
 
-var queueListCmd = &cobra.Command{
-	Use:     "ls",
-	Aliases: []string{"list"},
-	Short:   "List queue items",
-	Long: `List queued items and their dispatch status.
+func newQueueListCmd() *cobra.Command {
+	var flags queueListFlags
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List queue items",
+		Long: `List queued items and their dispatch status.
 
 By default, this lists queues for agents in the current workspace context.
 Use --agent to target a specific agent.`,
-	Args: cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-
-		statusFilter, err := normalizeQueueStatus(queueStatus)
-		if err != nil {
-			return err
-		}
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
-
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		wsRepo := db.NewWorkspaceRepository(database)
+			statusFilter, err := normalizeQueueStatus(flags.status)
+			if err != nil {
+				return err
+			}
 
-		agents, err := resolveQueueAgents(ctx, agentRepo, wsRepo, queueAgent)
-		if err != nil {
-			return err
-		}
-		if len(agents) == 0 {
-			if IsJSONOutput() || IsJSONLOutput() {
-				return WriteOutput(os.Stdout, []queueListAgent{})
+			database, err := openDatabase()
+			if err != nil {
+				return err
 			}
-			fmt.Println("No agents found")
-			return nil
-		}
+			defer database.Close()
 
-		output := make([]queueListAgent, 0, len(agents))
-		anyRows := false
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			wsRepo := db.NewWorkspaceRepository(database)
 
-		for _, a := range agents {
-			items, err := queueRepo.List(ctx, a.ID)
+			agents, err := resolveQueueAgents(ctx, agentRepo, wsRepo, flags.agent)
 			if err != nil {
-				return fmt.Errorf("failed to list queue for agent %s: %w", a.ID, err)
+				return err
 			}
-
-			view := buildQueueView(a, items, statusFilter, queueAll, queueLimit)
-			if len(view) == 0 {
-				continue
+			if len(agents) == 0 {
+				if opts.Structured() {
+					return opts.WriteOutput([]queueListAgent{})
+				}
+				fmt.Fprintln(opts.Out, "No agents found")
+				return nil
 			}
-			anyRows = true
 
-			output = append(output, queueListAgent{
-				AgentID:   a.ID,
-				AgentType: a.Type,
-				Items:     view,
-			})
-		}
+			output := make([]queueListAgent, 0, len(agents))
+			anyRows := false
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, output)
-		}
+			for _, a := range agents {
+				items, err := queueRepo.List(ctx, a.ID)
+				if err != nil {
+					return wrapServiceError(err, "failed to list queue for agent %s", a.ID)
+				}
 
-		if !anyRows {
-			fmt.Println("No queue items found")
-			return nil
-		}
+				view := buildQueueView(a, items, statusFilter, flags.all, flags.limit)
+				if len(view) == 0 {
+					continue
+				}
+				anyRows = true
 
-		for i, agentView := range output {
-			if i > 0 {
-				fmt.Println()
+				output = append(output, queueListAgent{
+					AgentID:   a.ID,
+					AgentType: a.Type,
+					Items:     view,
+				})
 			}
-			fmt.Printf("QUEUE FOR AGENT %s (%d items)\n\n", shortID(agentView.AgentID), len(agentView.Items))
 
-			rows := make([][]string, 0, len(agentView.Items))
-			for _, item := range agentView.Items {
-				blockReason := item.BlockReason
-				if blockReason == "" {
-					blockReason = "-"
-				}
+			if opts.Structured() {
+				return opts.WriteOutput(output)
+			}
 
-				rows = append(rows, []string{
-					fmt.Sprintf("%d", item.Item.Position),
-					string(item.Item.Type),
-					item.DisplayStatus,
-					blockReason,
-					item.Preview,
-					formatRelativeTime(item.Item.CreatedAt),
-				})
+			if !anyRows {
+				fmt.Fprintln(opts.Out, "No queue items found")
+				return nil
 			}
 
-			if err := writeTable(os.Stdout, []string{"POS", "TYPE", "STATUS", "BLOCK REASON", "CONTENT", "CREATED"}, rows); err != nil {
-				return err
+			for i, agentView := range output {
+				if i > 0 {
+					fmt.Fprintln(opts.Out)
+				}
+				fmt.Fprintf(opts.Out, "QUEUE FOR AGENT %s (%d items)\n\n", shortID(agentView.AgentID), len(agentView.Items))
+
+				rows := make([][]string, 0, len(agentView.Items))
+				for _, item := range agentView.Items {
+					blockReason := item.BlockReason
+					if blockReason == "" {
+						blockReason = "-"
+					}
+
+					rows = append(rows, []string{
+						fmt.Sprintf("%d", item.Item.Position),
+						string(item.Item.Type),
+						item.DisplayStatus,
+						blockReason,
+						item.Preview,
+						formatRelativeTime(item.Item.CreatedAt),
+					})
+				}
+
+				if err := opts.writeTable([]string{"POS", "TYPE", "STATUS", "BLOCK REASON", "CONTENT", "CREATED"}, rows); err != nil {
+					return err
+				}
 			}
-		}
 
-		return nil
-	},
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.agent, "agent", "a", "", "filter by agent ID or prefix")
+	cmd.Flags().StringVar(&flags.status, "status", "", "filter by status (pending, blocked, in_flight, dispatched, completed, failed, skipped)")
+	cmd.Flags().IntVarP(&flags.limit, "limit", "n", 20, "max items to show per agent (0 = unlimited)")
+	cmd.Flags().BoolVar(&flags.all, "all", false, "show all items including completed")
+	return cmd
 }
 
 type queueListAgent struct {
@@ -171,7 +177,7 @@ func resolveQueueAgents(ctx context.Context, agentRepo *db.AgentRepository, wsRe
 	if resolved != nil && resolved.WorkspaceID != "" {
 		agents, err := agentRepo.ListByWorkspace(ctx, resolved.WorkspaceID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list agents: %w", err)
+			return nil, wrapServiceError(err, "failed to list agents")
 		}
 		sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
 		return agents, nil
@@ -179,7 +185,7 @@ func resolveQueueAgents(ctx context.Context, agentRepo *db.AgentRepository, wsRe
 
 	agents, err := agentRepo.List(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list agents: %w", err)
+		return nil, wrapServiceError(err, "failed to list agents")
 	}
 	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
 	return agents, nil
@@ -302,6 +308,8 @@ func agentStateBlockReason(agent *models.Agent) string {
 		return "paused"
 	case models.AgentStateRateLimited:
 		return "cooldown"
+	case models.AgentStateStuck:
+		return "stuck"
 	case models.AgentStateWorking, models.AgentStateStarting, models.AgentStateAwaitingApproval:
 		return "busy"
 	case models.AgentStateError, models.AgentStateStopped:
@@ -329,7 +337,7 @@ func includeQueueItem(item *models.QueueItem, displayStatus string, statusFilter
 	if displayStatus == "pending" || displayStatus == "blocked" {
 		return true
 	}
-	return item.Status == models.QueueItemStatusDispatched
+	return item.Status == models.QueueItemStatusInFlight || item.Status == models.QueueItemStatusDispatched
 }
 
 func normalizeQueueStatus(status string) (string, error) {
@@ -339,10 +347,10 @@ func normalizeQueueStatus(status string) (string, error) {
 	}
 
 	switch status {
-	case "pending", "blocked", "dispatched", "completed", "failed", "skipped":
+	case "pending", "blocked", "in_flight", "dispatched", "completed", "failed", "skipped":
 		return status, nil
 	default:
-		return "", errors.New("invalid status (use pending, blocked, dispatched, completed, failed, or skipped)")
+		return "", invalidInputError("invalid status (use pending, blocked, in_flight, dispatched, completed, failed, or skipped)")
 	}
 }
 
@@ -370,6 +378,18 @@ func queueItemPreview(item *models.QueueItem) string {
 			return "invalid conditional payload"
 		}
 		return payload.Message
+	case models.QueueItemTypeCommand:
+		var payload models.CommandPayload
+		if err := json.Unmarshal(item.Payload, &payload); err != nil {
+			return "invalid command payload"
+		}
+		return "$ " + payload.Command
+	case models.QueueItemTypeReview:
+		var payload models.ReviewPayload
+		if err := json.Unmarshal(item.Payload, &payload); err != nil {
+			return "invalid review payload"
+		}
+		return "review " + payload.Source()
 	default:
 		return "unknown queue item"
 	}
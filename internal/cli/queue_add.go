@@ -0,0 +1,384 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+// queueAddFlags holds the flags of 'swarm queue add'.
+type queueAddFlags struct {
+	command       string
+	then          string
+	workDir       string
+	timeout       int
+	maxOutput     int
+	exitCode      bool
+	failOnNonZero bool
+	front         bool
+	message       string
+	workspace     string
+	anyAgent      bool
+	agentType     string
+	batchFile     string
+	ignoreQuiet   bool
+}
+
+func newQueueAddCmd() *cobra.Command {
+	var flags queueAddFlags
+	cmd := &cobra.Command{
+		Use:   "add [agent-id]",
+		Short: "Queue a command or message for an agent",
+		Long: `Queue a command item, or a message with --message. When the scheduler
+dispatches a command item, the command runs in the workspace on its node,
+and the output is sent to the agent as a message, after the --then text.
+
+Output beyond --max-output-bytes is cut from the start, keeping the end.
+A non-zero exit is reported in the message unless --fail-on-nonzero is set.
+
+Command items only run in workspaces that enable them with a
+workspace_overrides entry setting queue_commands: true.
+
+With --workspace and --any-agent the item goes to the workspace's queue
+instead of one agent's. The scheduler hands it to the first idle agent
+with nothing queued, or else claims a standby agent from the workspace's
+standby pool, in the order items were queued.
+
+With --batch-file the items listed in a YAML file are queued for one agent
+as a single batch: every item is validated first, they land at adjacent
+positions in file order, and either all of them are queued or none are.
+The scheduler cannot dispatch between them while they are being added.
+With --front the batch goes ahead of the agent's pending items, right
+after the item in flight.
+
+Nothing is dispatched to an agent during its quiet hours (see 'swarm ws
+set --quiet-hours'); --ignore-quiet-hours lets urgent items through.
+
+  items:
+    - type: message
+      message: "Refactor the parser"
+    - type: pause
+      duration: 5m
+      reason: let CI finish
+    - type: conditional
+      when: idle
+      message: "Now update the tests"
+    - type: command
+      command: go test ./...
+      then: "summarize the failures:"
+      timeout: 600`,
+		Example: `  swarm queue add abc123 --command "go test ./..." --then "summarize the failures:"
+  swarm queue add abc123 --command "make lint" --timeout 120 --front
+  swarm queue add --workspace api --any-agent --message "fix the flaky login test"
+  swarm queue add --workspace api --any-agent --agent-type codex --command "make lint"
+  swarm queue add abc123 --batch-file plan.yaml --front
+  swarm queue add abc123 --message "prod is down, check the logs" --ignore-quiet-hours`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			if flags.batchFile != "" {
+				if flags.command != "" || flags.message != "" {
+					return invalidInputError("--batch-file cannot be combined with --command or --message")
+				}
+				if flags.anyAgent || flags.workspace != "" || flags.agentType != "" {
+					return invalidInputError("--batch-file queues for one agent; it cannot be used with --any-agent")
+				}
+				if len(args) != 1 {
+					return invalidInputError("agent ID required with --batch-file")
+				}
+				return queueAddBatch(ctx, opts, flags, args[0], flags.batchFile)
+			}
+
+			if (flags.command == "") == (flags.message == "") {
+				return invalidInputError("set exactly one of --command or --message")
+			}
+			if flags.anyAgent {
+				if len(args) > 0 || strings.TrimSpace(flags.workspace) == "" {
+					return invalidInputError("--any-agent takes --workspace instead of an agent ID")
+				}
+				if flags.front {
+					return invalidInputError("--front cannot be used with --any-agent")
+				}
+				if flags.ignoreQuiet {
+					return invalidInputError("--ignore-quiet-hours cannot be used with --any-agent")
+				}
+				if flags.agentType != "" && !adapters.IsRegistered(models.AgentType(flags.agentType)) {
+					return invalidInputError("invalid agent type: %s", flags.agentType)
+				}
+			} else {
+				if len(args) != 1 {
+					return invalidInputError("agent ID required (or use --workspace with --any-agent)")
+				}
+				if flags.workspace != "" || flags.agentType != "" {
+					return invalidInputError("--workspace and --agent-type require --any-agent")
+				}
+			}
+
+			if flags.message != "" {
+				return queueAddItem(ctx, opts, flags, args, models.QueueItemTypeMessage, models.MessagePayload{Text: flags.message}, flags.message)
+			}
+
+			payload := models.CommandPayload{
+				Command:         strings.TrimSpace(flags.command),
+				WorkDir:         strings.TrimSpace(flags.workDir),
+				TimeoutSeconds:  flags.timeout,
+				Prefix:          flags.then,
+				MaxOutputBytes:  flags.maxOutput,
+				IncludeExitCode: flags.exitCode,
+				FailOnNonZero:   flags.failOnNonZero,
+			}
+			if err := payload.Validate(); err != nil {
+				return invalidInputError("%v", err)
+			}
+			return queueAddItem(ctx, opts, flags, args, models.QueueItemTypeCommand, payload, "$ "+payload.Command)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.command, "command", "", "shell command to run when the item is dispatched")
+	cmd.Flags().StringVar(&flags.then, "then", "", "text sent before the command output")
+	cmd.Flags().StringVar(&flags.workDir, "workdir", "", "directory to run the command in (default: workspace repo path)")
+	cmd.Flags().IntVar(&flags.timeout, "timeout", models.DefaultCommandTimeoutSeconds, "seconds before the command is killed")
+	cmd.Flags().IntVar(&flags.maxOutput, "max-output-bytes", models.DefaultCommandMaxOutputBytes, "keep at most this many bytes from the end of the output")
+	cmd.Flags().BoolVar(&flags.exitCode, "include-exit-code", true, "append the exit code to the message")
+	cmd.Flags().BoolVar(&flags.failOnNonZero, "fail-on-nonzero", false, "fail the item instead of sending output when the command exits non-zero")
+	cmd.Flags().BoolVar(&flags.front, "front", false, "insert at front of queue")
+	cmd.Flags().StringVarP(&flags.message, "message", "m", "", "message to queue instead of a command")
+	cmd.Flags().StringVarP(&flags.workspace, "workspace", "w", "", "workspace whose queue to add to (with --any-agent)")
+	cmd.Flags().BoolVar(&flags.anyAgent, "any-agent", false, "queue for the first available agent in --workspace")
+	cmd.Flags().StringVar(&flags.agentType, "agent-type", "", "only assign to agents of this type (with --any-agent)")
+	cmd.Flags().StringVar(&flags.batchFile, "batch-file", "", "YAML file of items to queue together, in order")
+	cmd.Flags().BoolVar(&flags.ignoreQuiet, "ignore-quiet-hours", false, "dispatch even during the agent's quiet hours")
+	return cmd
+}
+
+// queueAddItem queues the item for the agent in args, or in the workspace
+// queue when --any-agent is set. preview is shown in the confirmation.
+func queueAddItem(ctx context.Context, opts Options, flags queueAddFlags, args []string, itemType models.QueueItemType, payload any, preview string) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	wsRepo := db.NewWorkspaceRepository(database)
+	var target *models.Agent
+	var ws *models.Workspace
+	if flags.anyAgent {
+		ws, err = findWorkspace(ctx, wsRepo, flags.workspace)
+		if err != nil {
+			return err
+		}
+	} else {
+		target, err = findAgent(ctx, db.NewAgentRepository(database), args[0])
+		if err != nil {
+			return err
+		}
+		ws, err = wsRepo.Get(ctx, target.WorkspaceID)
+		if err != nil {
+			return wrapServiceError(err, "failed to load workspace for agent %s", shortID(target.ID))
+		}
+	}
+	if itemType == models.QueueItemTypeCommand {
+		if err := checkQueueCommandsEnabled(ws); err != nil {
+			return err
+		}
+	}
+
+	if flags.anyAgent {
+		return queueAddWorkspaceItem(ctx, opts, flags, database, ws, itemType, payloadBytes, preview)
+	}
+
+	item := &models.QueueItem{
+		AgentID: target.ID,
+		Type:    itemType,
+		Status:  models.QueueItemStatusPending,
+		Payload: payloadBytes,
+
+		IgnoreQuietHours: flags.ignoreQuiet,
+	}
+
+	queueService := queue.NewService(db.NewQueueRepository(database), queue.WithAuditRecorder(newAuditRecorder(database)))
+	if flags.front {
+		err = queueService.InsertAt(ctx, target.ID, 0, item)
+	} else {
+		err = queueService.Enqueue(ctx, target.ID, item)
+	}
+	if err != nil {
+		return wrapServiceError(err, "failed to queue %s for agent %s", itemType, shortID(target.ID))
+	}
+
+	position := 0
+	items, _ := queueService.List(ctx, target.ID)
+	for i, qi := range items {
+		if qi.ID == item.ID {
+			position = i + 1
+			break
+		}
+	}
+
+	if opts.Structured() {
+		return opts.WriteOutput(sendResult{
+			AgentID:  target.ID,
+			ItemID:   item.ID,
+			Position: position,
+			ItemType: string(item.Type),
+		})
+	}
+	fmt.Fprintf(opts.Out, "✓ Queued %s for agent %s at position #%d\n", itemType, shortID(target.ID), position)
+	fmt.Fprintf(opts.Out, "  %s\n", truncateMessage(preview, 60))
+	return nil
+}
+
+// checkQueueCommandsEnabled rejects command items for workspaces that have
+// not opted in.
+func checkQueueCommandsEnabled(ws *models.Workspace) error {
+	if cfg := GetConfig(); cfg != nil && cfg.QueueCommandsEnabled(ws) {
+		return nil
+	}
+	name := ws.Name
+	if name == "" {
+		name = shortID(ws.ID)
+	}
+	return invalidInputError("command items are disabled for workspace %s (set queue_commands in a workspace_overrides entry)", name)
+}
+
+// queueBatchResult is the JSON output for a --batch-file enqueue.
+type queueBatchResult struct {
+	AgentID   string   `json:"agent_id"`
+	ItemIDs   []string `json:"item_ids"`
+	Positions []int    `json:"positions"`
+	Front     bool     `json:"front"`
+}
+
+// queueAddBatch queues every item in a batch file for one agent, atomically.
+func queueAddBatch(ctx context.Context, opts Options, flags queueAddFlags, agentRef, path string) error {
+	items, err := loadQueueBatch(path)
+	if err != nil {
+		return invalidInputError("%v", err)
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	target, err := findAgent(ctx, db.NewAgentRepository(database), agentRef)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		item.IgnoreQuietHours = flags.ignoreQuiet
+	}
+	hasCommand := slices.ContainsFunc(items, func(item *models.QueueItem) bool {
+		return item.Type == models.QueueItemTypeCommand
+	})
+	if hasCommand {
+		ws, err := db.NewWorkspaceRepository(database).Get(ctx, target.WorkspaceID)
+		if err != nil {
+			return wrapServiceError(err, "failed to load workspace for agent %s", shortID(target.ID))
+		}
+		if err := checkQueueCommandsEnabled(ws); err != nil {
+			return err
+		}
+	}
+
+	queueService := queue.NewService(db.NewQueueRepository(database), queue.WithAuditRecorder(newAuditRecorder(database)))
+	if flags.front {
+		err = queueService.EnqueueBatchAtHead(ctx, target.ID, items...)
+	} else {
+		err = queueService.EnqueueBatch(ctx, target.ID, items...)
+	}
+	if err != nil {
+		return wrapServiceError(err, "failed to queue batch for agent %s", shortID(target.ID))
+	}
+
+	result := queueBatchResult{AgentID: target.ID, Front: flags.front}
+	positions := make(map[string]int)
+	queued, _ := queueService.List(ctx, target.ID)
+	for i, qi := range queued {
+		positions[qi.ID] = i + 1
+	}
+	for _, item := range items {
+		result.ItemIDs = append(result.ItemIDs, item.ID)
+		result.Positions = append(result.Positions, positions[item.ID])
+	}
+
+	if opts.Structured() {
+		return opts.WriteOutput(result)
+	}
+	fmt.Fprintf(opts.Out, "✓ Queued %d items for agent %s\n", len(items), shortID(target.ID))
+	for i, item := range items {
+		fmt.Fprintf(opts.Out, "  #%d %s: %s\n", result.Positions[i], item.Type, truncateMessage(queueItemPreview(item), 60))
+	}
+	return nil
+}
+
+// workspaceQueueResult is the JSON output for an item added to a
+// workspace queue.
+type workspaceQueueResult struct {
+	WorkspaceID string `json:"workspace_id"`
+	ItemID      string `json:"item_id"`
+	ItemType    string `json:"item_type"`
+	AgentType   string `json:"agent_type,omitempty"`
+	Position    int    `json:"position"`
+}
+
+func queueAddWorkspaceItem(ctx context.Context, opts Options, flags queueAddFlags, database *db.DB, ws *models.Workspace, itemType models.QueueItemType, payload []byte, preview string) error {
+	if ws.Draining {
+		return conflictError("workspace %s is draining and accepts no new queue items (undrain it with 'swarm ws undrain %s')", ws.Name, ws.Name)
+	}
+	item := &models.WorkspaceQueueItem{
+		WorkspaceID: ws.ID,
+		AgentType:   models.AgentType(flags.agentType),
+		Type:        itemType,
+		Payload:     payload,
+	}
+	repo := db.NewWorkspaceQueueRepository(database)
+	if err := repo.Enqueue(ctx, item); err != nil {
+		return wrapServiceError(err, "failed to queue %s for workspace %s", itemType, ws.Name)
+	}
+
+	position := 0
+	items, _ := repo.ListByWorkspace(ctx, ws.ID)
+	for _, wi := range items {
+		if wi.Status == models.WorkspaceQueueItemStatusPending {
+			position++
+		}
+		if wi.ID == item.ID {
+			break
+		}
+	}
+
+	if opts.Structured() {
+		return opts.WriteOutput(workspaceQueueResult{
+			WorkspaceID: ws.ID,
+			ItemID:      item.ID,
+			ItemType:    string(item.Type),
+			AgentType:   string(item.AgentType),
+			Position:    position,
+		})
+	}
+	name := ws.Name
+	if name == "" {
+		name = shortID(ws.ID)
+	}
+	fmt.Fprintf(opts.Out, "✓ Queued %s for any agent in workspace %s at position #%d\n", itemType, name, position)
+	fmt.Fprintf(opts.Out, "  %s\n", truncateMessage(preview, 60))
+	return nil
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestQueueAddCommand(t *testing.T) {
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+
+	cfg := config.DefaultConfig()
+	previous := appConfig
+	appConfig = cfg
+	t.Cleanup(func() { appConfig = previous })
+
+	if code, err := runCommand(t, newQueueAddCmd(), agent.ID, "--command", "go test ./..."); code != ExitCodeInvalidInput {
+		t.Fatalf("expected commands to be disabled by default, got exit %d: %v", code, err)
+	}
+
+	cfg.WorkspaceOverrides = []config.WorkspaceOverrideConfig{{RepoPath: "/repo", QueueCommands: true}}
+	if code, err := runCommand(t, newQueueAddCmd(), agent.ID, "--command", "go test ./...", "--timeout", "7200"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected an out-of-range timeout to be rejected, got exit %d: %v", code, err)
+	}
+
+	var result sendResult
+	out := runJSONCommand(t, newQueueAddCmd(), agent.ID[:8], "--command", "go test ./...", "--then", "summarize the failures:", "--fail-on-nonzero")
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if result.ItemType != string(models.QueueItemTypeCommand) || result.Position != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	items, err := db.NewQueueRepository(database).List(context.Background(), agent.ID)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected one queued item, got %d (%v)", len(items), err)
+	}
+	payload, err := items[0].GetCommandPayload()
+	if err != nil {
+		t.Fatalf("GetCommandPayload: %v", err)
+	}
+	want := models.CommandPayload{
+		Command:         "go test ./...",
+		TimeoutSeconds:  models.DefaultCommandTimeoutSeconds,
+		Prefix:          "summarize the failures:",
+		MaxOutputBytes:  models.DefaultCommandMaxOutputBytes,
+		IncludeExitCode: true,
+		FailOnNonZero:   true,
+	}
+	if *payload != want {
+		t.Fatalf("payload = %+v, want %+v", *payload, want)
+	}
+	if preview := queueItemPreview(items[0]); preview != "$ go test ./..." {
+		t.Fatalf("unexpected preview %q", preview)
+	}
+}
+
+func TestQueueAddAnyAgent(t *testing.T) {
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+	ctx := context.Background()
+	ws, err := db.NewWorkspaceRepository(database).Get(ctx, agent.WorkspaceID)
+	if err != nil {
+		t.Fatalf("get workspace: %v", err)
+	}
+
+	previous := appConfig
+	appConfig = config.DefaultConfig()
+	t.Cleanup(func() { appConfig = previous })
+
+	for _, args := range [][]string{
+		{"--any-agent", "--message", "hi"},
+		{agent.ID, "--any-agent", "--workspace", ws.ID, "--message", "hi"},
+		{"--workspace", ws.ID, "--message", "hi"},
+		{"--workspace", ws.ID, "--any-agent", "--message", "hi", "--command", "true"},
+		{"--workspace", ws.ID, "--any-agent", "--message", "hi", "--agent-type", "robot"},
+		{"--workspace", ws.ID, "--any-agent", "--command", "make lint"},
+	} {
+		if code, err := runCommand(t, newQueueAddCmd(), args...); code != ExitCodeInvalidInput {
+			t.Fatalf("expected %v to be rejected, got exit %d: %v", args, code, err)
+		}
+	}
+
+	var results []workspaceQueueResult
+	for _, text := range []string{"first", "second"} {
+		var result workspaceQueueResult
+		out := runJSONCommand(t, newQueueAddCmd(), "--workspace", ws.ID, "--any-agent", "--agent-type", "opencode", "--message", text)
+		if err := json.Unmarshal(out, &result); err != nil {
+			t.Fatalf("decode output: %v\n%s", err, out)
+		}
+		results = append(results, result)
+	}
+	if results[0].Position != 1 || results[1].Position != 2 || results[1].WorkspaceID != ws.ID || results[1].AgentType != "opencode" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	items, err := db.NewWorkspaceQueueRepository(database).ListPending(ctx)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("expected two pending workspace items, got %d (%v)", len(items), err)
+	}
+	if items[0].ID != results[0].ItemID || items[0].Type != models.QueueItemTypeMessage || string(items[0].Payload) != `{"text":"first"}` {
+		t.Fatalf("unexpected workspace item %+v", items[0])
+	}
+	if queued, _ := db.NewQueueRepository(database).List(ctx, agent.ID); len(queued) != 0 {
+		t.Fatalf("expected nothing on the agent's own queue, got %d", len(queued))
+	}
+
+	captureStdout(t, func() {
+		if code, err := runCommand(t, newQueueAddCmd(), agent.ID, "--message", "direct"); code != 0 {
+			t.Errorf("expected a direct message item, got exit %d: %v", code, err)
+		}
+	})
+}
+
+func TestQueueAddBatchFile(t *testing.T) {
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+	ctx := context.Background()
+	queueRepo := db.NewQueueRepository(database)
+
+	previous := appConfig
+	appConfig = config.DefaultConfig()
+	t.Cleanup(func() { appConfig = previous })
+
+	writeBatch := func(body string) string {
+		path := filepath.Join(t.TempDir(), "plan.yaml")
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatalf("write batch file: %v", err)
+		}
+		return path
+	}
+
+	existing := &models.QueueItem{Type: models.QueueItemTypeMessage, Payload: json.RawMessage(`{"text":"already queued"}`)}
+	if err := queueRepo.Enqueue(ctx, agent.ID, existing); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	// One bad item rejects the whole file before anything is queued.
+	for name, body := range map[string]string{
+		"invalid pause":    "items:\n  - type: message\n    message: step one\n  - type: pause\n    duration: soon\n",
+		"unknown type":     "items:\n  - type: message\n    message: step one\n  - type: launch\n",
+		"unknown field":    "items:\n  - type: message\n    mesage: typo\n",
+		"empty message":    "items:\n  - type: message\n  - type: pause\n    duration: 1m\n",
+		"no items":         "items: []\n",
+		"command disabled": "items:\n  - type: message\n    message: step one\n  - type: command\n    command: go test ./...\n",
+	} {
+		if code, err := runCommand(t, newQueueAddCmd(), agent.ID, "--batch-file", writeBatch(body)); code != ExitCodeInvalidInput {
+			t.Fatalf("%s: expected invalid input, got exit %d: %v", name, code, err)
+		}
+	}
+	if items, _ := queueRepo.List(ctx, agent.ID); len(items) != 1 {
+		t.Fatalf("expected rejected files to queue nothing, got %d items", len(items))
+	}
+
+	plan := writeBatch(`items:
+  - type: message
+    message: refactor the parser
+  - type: pause
+    duration: 5m
+    reason: let CI finish
+  - type: conditional
+    when: idle
+    message: now update the tests
+`)
+	var result queueBatchResult
+	out := runJSONCommand(t, newQueueAddCmd(), agent.ID, "--batch-file", plan, "--front")
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if len(result.ItemIDs) != 3 || !result.Front || result.Positions[0] != 1 || result.Positions[2] != 3 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	items, err := queueRepo.List(ctx, agent.ID)
+	if err != nil || len(items) != 4 {
+		t.Fatalf("expected four queued items, got %d (%v)", len(items), err)
+	}
+	wantTypes := []models.QueueItemType{models.QueueItemTypeMessage, models.QueueItemTypePause, models.QueueItemTypeConditional, models.QueueItemTypeMessage}
+	for i, item := range items {
+		if item.Type != wantTypes[i] {
+			t.Fatalf("item %d: type %s, want %s", i, item.Type, wantTypes[i])
+		}
+	}
+	if items[3].ID != existing.ID {
+		t.Fatalf("expected the batch ahead of the existing item")
+	}
+	pause, err := items[1].GetPausePayload()
+	if err != nil || pause.DurationSeconds != 300 || pause.Reason != "let CI finish" {
+		t.Fatalf("unexpected pause payload %+v (%v)", pause, err)
+	}
+
+	if code, err := runCommand(t, newQueueAddCmd(), agent.ID, "--batch-file", plan, "--message", "hi"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected --batch-file with --message to be rejected, got exit %d: %v", code, err)
+	}
+}
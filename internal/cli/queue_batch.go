@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/sequences"
+	"gopkg.in/yaml.v3"
+)
+
+// queueBatchFile is the --batch-file format: typed queue items, in order.
+type queueBatchFile struct {
+	Items []queueBatchEntry `yaml:"items"`
+}
+
+type queueBatchEntry struct {
+	Type models.QueueItemType `yaml:"type"`
+
+	// message, and the text a conditional item sends
+	Message string `yaml:"message,omitempty"`
+
+	// pause
+	Duration string `yaml:"duration,omitempty"`
+	Reason   string `yaml:"reason,omitempty"`
+
+	// conditional; when takes the same values as sequence steps
+	When       string `yaml:"when,omitempty"`
+	Expression string `yaml:"expression,omitempty"`
+
+	// command
+	Command         string `yaml:"command,omitempty"`
+	Then            string `yaml:"then,omitempty"`
+	WorkDir         string `yaml:"workdir,omitempty"`
+	Timeout         int    `yaml:"timeout,omitempty"`
+	MaxOutputBytes  int    `yaml:"max_output_bytes,omitempty"`
+	IncludeExitCode *bool  `yaml:"include_exit_code,omitempty"`
+	FailOnNonZero   bool   `yaml:"fail_on_nonzero,omitempty"`
+}
+
+// loadQueueBatch reads and validates a batch file. Nothing is returned
+// unless every item is valid, so a bad entry never leaves half a plan
+// queued.
+func loadQueueBatch(path string) ([]*models.QueueItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read batch file: %w", err)
+	}
+
+	var file queueBatchFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse batch file %s: %w", path, err)
+	}
+	if len(file.Items) == 0 {
+		return nil, fmt.Errorf("batch file %s has no items", path)
+	}
+
+	items := make([]*models.QueueItem, 0, len(file.Items))
+	for i, entry := range file.Items {
+		item, err := entry.queueItem()
+		if err == nil {
+			err = item.Validate()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("batch file %s item %d (%s): %w", path, i+1, entry.Type, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (e queueBatchEntry) queueItem() (*models.QueueItem, error) {
+	var payload any
+	switch e.Type {
+	case models.QueueItemTypeMessage:
+		payload = models.MessagePayload{Text: e.Message}
+	case models.QueueItemTypePause:
+		duration, err := time.ParseDuration(strings.TrimSpace(e.Duration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pause duration %q: %w", e.Duration, err)
+		}
+		payload = models.PausePayload{
+			DurationSeconds: int(duration.Round(time.Second).Seconds()),
+			Reason:          e.Reason,
+		}
+	case models.QueueItemTypeConditional:
+		conditionType, expression, err := sequences.ParseCondition(e.When, e.Expression)
+		if err != nil {
+			return nil, err
+		}
+		payload = models.ConditionalPayload{
+			ConditionType: conditionType,
+			Expression:    expression,
+			Message:       e.Message,
+		}
+	case models.QueueItemTypeCommand:
+		command := models.CommandPayload{
+			Command:         strings.TrimSpace(e.Command),
+			WorkDir:         strings.TrimSpace(e.WorkDir),
+			TimeoutSeconds:  e.Timeout,
+			Prefix:          e.Then,
+			MaxOutputBytes:  e.MaxOutputBytes,
+			IncludeExitCode: true,
+			FailOnNonZero:   e.FailOnNonZero,
+		}
+		if command.TimeoutSeconds == 0 {
+			command.TimeoutSeconds = models.DefaultCommandTimeoutSeconds
+		}
+		if command.MaxOutputBytes == 0 {
+			command.MaxOutputBytes = models.DefaultCommandMaxOutputBytes
+		}
+		if e.IncludeExitCode != nil {
+			command.IncludeExitCode = *e.IncludeExitCode
+		}
+		payload = command
+	case "":
+		return nil, fmt.Errorf("type is required (message, pause, conditional, or command)")
+	default:
+		return nil, fmt.Errorf("unknown item type %q (use message, pause, conditional, or command)", e.Type)
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	return &models.QueueItem{
+		Type:    e.Type,
+		Status:  models.QueueItemStatusPending,
+		Payload: payloadBytes,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+// queueClearView is the JSON output of queue clear.
+type queueClearView struct {
+	AgentID string              `json:"agent_id"`
+	Cleared int                 `json:"cleared"`
+	Items   []*models.QueueItem `json:"items"`
+	DryRun  bool                `json:"dry_run"`
+}
+
+// queueClearFlags holds the flags of 'swarm queue clear'.
+type queueClearFlags struct {
+	dryRun bool
+}
+
+func newQueueClearCmd() *cobra.Command {
+	var flags queueClearFlags
+	cmd := &cobra.Command{
+		Use:   "clear <agent-id>",
+		Short: "Remove the pending items from an agent's queue",
+		Long: `Remove every pending item from an agent's queue. Items already
+dispatched or finished are kept. With --dry-run, the items that would be
+removed are listed and left queued.`,
+		Example: `  swarm queue clear abc123 --dry-run
+  swarm queue clear abc123 --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			resolved, err := findAgent(ctx, db.NewAgentRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+
+			queueService := queue.NewService(db.NewQueueRepository(database), queue.WithAuditRecorder(newAuditRecorder(database)))
+			if !flags.dryRun {
+				pending, err := queueService.ClearPending(ctx, resolved.ID, true)
+				if err != nil {
+					return wrapServiceError(err, "failed to list queue")
+				}
+				impact := fmt.Sprintf("This will remove %d pending queue item(s).", len(pending))
+				if len(pending) > 0 && !opts.ConfirmDestructiveAction("queue", resolved.ID, impact) {
+					fmt.Fprintln(opts.Err, "Cancelled.")
+					return nil
+				}
+			}
+
+			items, err := queueService.ClearPending(ctx, resolved.ID, flags.dryRun)
+			if err != nil {
+				return wrapServiceError(err, "failed to clear queue")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(queueClearView{AgentID: resolved.ID, Cleared: len(items), Items: items, DryRun: flags.dryRun})
+			}
+			if len(items) == 0 {
+				fmt.Fprintf(opts.Out, "No pending items in the queue of agent %s\n", shortID(resolved.ID))
+				return nil
+			}
+			if !flags.dryRun {
+				fmt.Fprintf(opts.Out, "Cleared %d item(s) from the queue of agent %s\n", len(items), shortID(resolved.ID))
+				return nil
+			}
+
+			rows := make([][]string, 0, len(items))
+			for _, item := range items {
+				rows = append(rows, []string{
+					fmt.Sprintf("%d", item.Position),
+					shortID(item.ID),
+					string(item.Type),
+					queueItemPreview(item),
+				})
+			}
+			if err := opts.writeTable([]string{"POS", "ID", "TYPE", "CONTENT"}, rows); err != nil {
+				return err
+			}
+			printDryRunNote(opts.Out)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "show the items that would be cleared without removing them")
+	return cmd
+}
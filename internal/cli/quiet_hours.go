@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/quiethours"
+)
+
+// globalQuietHours returns the configured scheduler.quiet_hours.
+func globalQuietHours() string {
+	if cfg := GetConfig(); cfg != nil {
+		return cfg.Scheduler.QuietHours
+	}
+	return ""
+}
+
+// quietHoursStatus describes the quiet hours in effect now, such as
+// "quiet hours until 08:00 CET", for specs ordered from the most specific
+// setting to the least; the global setting is appended. It returns "" when
+// none apply.
+func quietHoursStatus(now time.Time, specs ...string) string {
+	schedule, err := quiethours.Resolve(append(specs, globalQuietHours())...)
+	if err != nil {
+		return ""
+	}
+	until, quiet := schedule.Until(now)
+	if !quiet {
+		return ""
+	}
+	layout := "15:04 MST"
+	if until.Sub(now) >= 24*time.Hour {
+		layout = "Mon 15:04 MST"
+	}
+	return "quiet hours until " + until.Format(layout)
+}
@@ -2,7 +2,6 @@
 package cli
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -22,6 +21,7 @@ import (
 var (
 	recipeRunWorkspace string
 	recipeRunDryRun    bool
+	recipeRunModel     string
 )
 
 func init() {
@@ -32,6 +32,7 @@ func init() {
 
 	recipeRunCmd.Flags().StringVarP(&recipeRunWorkspace, "workspace", "w", "", "workspace to spawn agents in")
 	recipeRunCmd.Flags().BoolVar(&recipeRunDryRun, "dry-run", false, "show what would be spawned without doing it")
+	recipeRunCmd.Flags().StringVar(&recipeRunModel, "model", "", "model for every agent (overrides recipe and config)")
 }
 
 var recipeCmd = &cobra.Command{
@@ -165,7 +166,7 @@ optional initial templates/sequences to run on each agent.`,
   swarm recipe run baseline`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 		name := args[0]
 
 		cwd, err := os.Getwd()
@@ -235,10 +236,19 @@ optional initial templates/sequences to run on each agent.`,
 		profileIndex := 0
 
 		for _, spec := range recipe.Agents {
+			modelFlag := recipeRunModel
+			if modelFlag == "" {
+				modelFlag = spec.Model
+			}
+			model := resolveSpawnModel(ws, spec.Type, modelFlag)
 			for i := 0; i < spec.Count; i++ {
 				opts := agent.SpawnOptions{
 					WorkspaceID: ws.ID,
 					Type:        spec.Type,
+					Model:       model,
+
+					WorkspaceEnvironment: workspaceSpawnEnv(ws),
+					TemplateEnvironment:  spec.Environment,
 				}
 
 				// Handle profile assignment
@@ -330,6 +340,9 @@ func showDryRun(recipe *recipes.Recipe, ws *models.Workspace) error {
 		if spec.ProfileRotation != "" {
 			fmt.Printf(" (rotation: %s)", spec.ProfileRotation)
 		}
+		if spec.Model != "" {
+			fmt.Printf(" (model: %s)", spec.Model)
+		}
 		fmt.Println()
 		if spec.InitialTemplate != "" {
 			fmt.Printf("      → Run template: %s\n", spec.InitialTemplate)
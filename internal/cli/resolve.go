@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"sort"
 	"strings"
 
@@ -15,6 +14,7 @@ import (
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/models"
 	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/workspace"
 )
 
 const maxSuggestions = 5
@@ -29,7 +29,7 @@ func shortID(id string) string {
 
 func findNode(ctx context.Context, service *node.Service, idOrName string) (*models.Node, error) {
 	if strings.TrimSpace(idOrName) == "" {
-		return nil, errors.New("node name or ID required")
+		return nil, invalidInputError("node name or ID required")
 	}
 
 	n, err := service.GetNodeByName(ctx, idOrName)
@@ -37,7 +37,7 @@ func findNode(ctx context.Context, service *node.Service, idOrName string) (*mod
 		return n, nil
 	}
 	if !errors.Is(err, node.ErrNodeNotFound) {
-		return nil, fmt.Errorf("failed to get node: %w", err)
+		return nil, wrapServiceError(err, "failed to get node")
 	}
 
 	n, err = service.GetNode(ctx, idOrName)
@@ -45,12 +45,12 @@ func findNode(ctx context.Context, service *node.Service, idOrName string) (*mod
 		return n, nil
 	}
 	if !errors.Is(err, node.ErrNodeNotFound) {
-		return nil, fmt.Errorf("failed to get node: %w", err)
+		return nil, wrapServiceError(err, "failed to get node")
 	}
 
 	nodes, err := service.ListNodes(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list nodes: %w", err)
+		return nil, wrapServiceError(err, "failed to list nodes")
 	}
 
 	matches := matchNodes(nodes, idOrName)
@@ -58,19 +58,19 @@ func findNode(ctx context.Context, service *node.Service, idOrName string) (*mod
 		return matches[0], nil
 	}
 	if len(matches) > 1 {
-		return nil, fmt.Errorf("node '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrName, formatNodeMatches(matches))
+		return nil, invalidInputError("node '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrName, formatNodeMatches(matches))
 	}
 	if len(nodes) == 0 {
-		return nil, fmt.Errorf("node '%s' not found (no nodes registered yet)", idOrName)
+		return nil, notFoundError("node '%s' not found (no nodes registered yet)", idOrName)
 	}
 
 	example := fmt.Sprintf("Example input: '%s' or '%s'", nodes[0].Name, shortID(nodes[0].ID))
-	return nil, fmt.Errorf("node '%s' not found. %s", idOrName, example)
+	return nil, notFoundError("node '%s' not found. %s", idOrName, example)
 }
 
 func findWorkspace(ctx context.Context, repo *db.WorkspaceRepository, idOrName string) (*models.Workspace, error) {
 	if strings.TrimSpace(idOrName) == "" {
-		return nil, errors.New("workspace name or ID required")
+		return nil, invalidInputError("workspace name or ID required")
 	}
 
 	ws, err := repo.GetByName(ctx, idOrName)
@@ -78,7 +78,7 @@ func findWorkspace(ctx context.Context, repo *db.WorkspaceRepository, idOrName s
 		return ws, nil
 	}
 	if !errors.Is(err, db.ErrWorkspaceNotFound) {
-		return nil, fmt.Errorf("failed to get workspace: %w", err)
+		return nil, wrapServiceError(err, "failed to get workspace")
 	}
 
 	ws, err = repo.Get(ctx, idOrName)
@@ -86,12 +86,12 @@ func findWorkspace(ctx context.Context, repo *db.WorkspaceRepository, idOrName s
 		return ws, nil
 	}
 	if !errors.Is(err, db.ErrWorkspaceNotFound) {
-		return nil, fmt.Errorf("failed to get workspace: %w", err)
+		return nil, wrapServiceError(err, "failed to get workspace")
 	}
 
 	workspaces, err := repo.List(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+		return nil, wrapServiceError(err, "failed to list workspaces")
 	}
 
 	matches := matchWorkspaces(workspaces, idOrName)
@@ -99,32 +99,34 @@ func findWorkspace(ctx context.Context, repo *db.WorkspaceRepository, idOrName s
 		return matches[0], nil
 	}
 	if len(matches) > 1 {
-		return nil, fmt.Errorf("workspace '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrName, formatWorkspaceMatches(matches))
+		return nil, invalidInputError("workspace '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrName, formatWorkspaceMatches(matches))
 	}
 	if len(workspaces) == 0 {
-		return nil, fmt.Errorf("workspace '%s' not found (no workspaces registered yet)", idOrName)
+		return nil, notFoundError("workspace '%s' not found (no workspaces registered yet)", idOrName)
 	}
 
 	example := fmt.Sprintf("Example input: '%s' or '%s'", workspaces[0].Name, shortID(workspaces[0].ID))
-	return nil, fmt.Errorf("workspace '%s' not found. %s", idOrName, example)
+	return nil, notFoundError("workspace '%s' not found. %s", idOrName, example)
 }
 
-func findAgent(ctx context.Context, repo *db.AgentRepository, idOrPrefix string) (*models.Agent, error) {
+// findAgent resolves an agent by ID or ID prefix. Options such as
+// db.IncludeDeleted widen the agents considered.
+func findAgent(ctx context.Context, repo *db.AgentRepository, idOrPrefix string, opts ...db.AgentQueryOption) (*models.Agent, error) {
 	if strings.TrimSpace(idOrPrefix) == "" {
-		return nil, errors.New("agent ID required")
+		return nil, invalidInputError("agent ID required")
 	}
 
-	agent, err := repo.Get(ctx, idOrPrefix)
+	agent, err := repo.Get(ctx, idOrPrefix, opts...)
 	if err == nil {
 		return agent, nil
 	}
 	if !errors.Is(err, db.ErrAgentNotFound) {
-		return nil, fmt.Errorf("failed to get agent: %w", err)
+		return nil, wrapServiceError(err, "failed to get agent")
 	}
 
-	agents, err := repo.List(ctx)
+	agents, err := repo.List(ctx, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list agents: %w", err)
+		return nil, wrapServiceError(err, "failed to list agents")
 	}
 
 	matches := matchAgents(agents, idOrPrefix)
@@ -132,19 +134,19 @@ func findAgent(ctx context.Context, repo *db.AgentRepository, idOrPrefix string)
 		return matches[0], nil
 	}
 	if len(matches) > 1 {
-		return nil, fmt.Errorf("agent '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrPrefix, formatAgentMatches(matches))
+		return nil, invalidInputError("agent '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrPrefix, formatAgentMatches(matches))
 	}
 	if len(agents) == 0 {
-		return nil, fmt.Errorf("agent '%s' not found (no agents registered yet)", idOrPrefix)
+		return nil, notFoundError("agent '%s' not found (no agents registered yet)", idOrPrefix)
 	}
 
 	example := fmt.Sprintf("Example input: '%s'", shortID(agents[0].ID))
-	return nil, fmt.Errorf("agent '%s' not found. %s", idOrPrefix, example)
+	return nil, notFoundError("agent '%s' not found. %s", idOrPrefix, example)
 }
 
 func findAccount(ctx context.Context, repo *db.AccountRepository, idOrProfile string) (*models.Account, error) {
 	if strings.TrimSpace(idOrProfile) == "" {
-		return nil, errors.New("account ID or profile name required")
+		return nil, invalidInputError("account ID or profile name required")
 	}
 
 	account, err := repo.Get(ctx, idOrProfile)
@@ -152,12 +154,12 @@ func findAccount(ctx context.Context, repo *db.AccountRepository, idOrProfile st
 		return account, nil
 	}
 	if !errors.Is(err, db.ErrAccountNotFound) {
-		return nil, fmt.Errorf("failed to get account: %w", err)
+		return nil, wrapServiceError(err, "failed to get account")
 	}
 
 	accounts, err := repo.List(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list accounts: %w", err)
+		return nil, wrapServiceError(err, "failed to list accounts")
 	}
 
 	matches := matchAccounts(accounts, idOrProfile)
@@ -165,14 +167,14 @@ func findAccount(ctx context.Context, repo *db.AccountRepository, idOrProfile st
 		return matches[0], nil
 	}
 	if len(matches) > 1 {
-		return nil, fmt.Errorf("account '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrProfile, formatAccountMatches(matches))
+		return nil, invalidInputError("account '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrProfile, formatAccountMatches(matches))
 	}
 	if len(accounts) == 0 {
-		return nil, fmt.Errorf("account '%s' not found (no accounts configured yet)", idOrProfile)
+		return nil, notFoundError("account '%s' not found (no accounts configured yet)", idOrProfile)
 	}
 
 	example := fmt.Sprintf("Example input: '%s' or '%s'", accounts[0].ProfileName, shortID(accounts[0].ID))
-	return nil, fmt.Errorf("account '%s' not found. %s", idOrProfile, example)
+	return nil, notFoundError("account '%s' not found. %s", idOrProfile, example)
 }
 
 func matchNodes(nodes []*models.Node, query string) []*models.Node {
@@ -443,7 +445,7 @@ func ResolveAgentContext(ctx context.Context, repo *db.AgentRepository, explicit
 		}
 		// Verify agent belongs to workspace if specified
 		if workspaceID != "" && agent.WorkspaceID != workspaceID {
-			return nil, fmt.Errorf("agent %s does not belong to workspace %s", explicitFlag, workspaceID)
+			return nil, invalidInputError("agent %s does not belong to workspace %s", explicitFlag, workspaceID)
 		}
 		result.AgentID = agent.ID
 		result.Source = "flag"
@@ -481,7 +483,7 @@ func RequireWorkspaceContext(ctx context.Context, repo *db.WorkspaceRepository,
 		return nil, err
 	}
 	if resolved.WorkspaceID == "" {
-		return nil, errors.New("workspace required: use --workspace flag, run from a workspace directory, or set context with 'swarm use <workspace>'")
+		return nil, invalidInputError("workspace required: use --workspace flag, run from a workspace directory, or set context with 'swarm use <workspace>'")
 	}
 	return resolved, nil
 }
@@ -494,7 +496,7 @@ func RequireAgentContext(ctx context.Context, repo *db.AgentRepository, explicit
 		return nil, err
 	}
 	if resolved.AgentID == "" {
-		return nil, errors.New("agent required: provide agent ID as argument or set context with 'swarm use --agent <agent>'")
+		return nil, invalidInputError("agent required: provide agent ID as argument or set context with 'swarm use --agent <agent>'")
 	}
 	return resolved, nil
 }
@@ -513,11 +515,7 @@ func detectWorkspaceFromCwd(ctx context.Context, repo *db.WorkspaceRepository) *
 		return nil
 	}
 
-	// Normalize path
-	gitRoot, err = filepath.Abs(gitRoot)
-	if err != nil {
-		return nil
-	}
+	gitRoot = workspace.CanonicalRepoPath(gitRoot)
 
 	// Look for a workspace with matching repo path
 	workspaces, err := repo.List(ctx)
@@ -529,11 +527,7 @@ func detectWorkspaceFromCwd(ctx context.Context, repo *db.WorkspaceRepository) *
 		if ws.RepoPath == "" {
 			continue
 		}
-		wsPath, err := filepath.Abs(ws.RepoPath)
-		if err != nil {
-			continue
-		}
-		if wsPath == gitRoot {
+		if workspace.CanonicalRepoPath(ws.RepoPath) == gitRoot {
 			return ws
 		}
 	}
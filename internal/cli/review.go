@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reviewRequestAgent        string
+	reviewRequestRange        string
+	reviewRequestPatch        string
+	reviewRequestPaths        []string
+	reviewRequestExclude      []string
+	reviewRequestMaxDiffBytes int
+	reviewRequestInstructions string
+	reviewRequestFront        bool
+)
+
+func init() {
+	rootCmd.AddCommand(reviewCmd)
+	reviewCmd.AddCommand(reviewRequestCmd)
+	reviewCmd.AddCommand(reviewShowCmd)
+
+	reviewRequestCmd.Flags().StringVarP(&reviewRequestAgent, "agent", "a", "", "agent to review the diff (required)")
+	reviewRequestCmd.Flags().StringVar(&reviewRequestRange, "range", "", "git revision range to review (e.g. main..HEAD)")
+	reviewRequestCmd.Flags().StringVar(&reviewRequestPatch, "patch", "", "patch file on the workspace's node to review instead of a range")
+	reviewRequestCmd.Flags().StringSliceVar(&reviewRequestPaths, "path", nil, "only review files matching this pattern (repeatable)")
+	reviewRequestCmd.Flags().StringSliceVar(&reviewRequestExclude, "exclude", nil, "leave out files matching this pattern (repeatable)")
+	reviewRequestCmd.Flags().IntVar(&reviewRequestMaxDiffBytes, "max-diff-bytes", 0, "send at most this many bytes of diff (default: review.max_diff_bytes)")
+	reviewRequestCmd.Flags().StringVar(&reviewRequestInstructions, "instructions", "", "extra instructions for the reviewer")
+	reviewRequestCmd.Flags().BoolVar(&reviewRequestFront, "front", false, "insert at front of queue")
+	_ = reviewRequestCmd.MarkFlagRequired("agent")
+}
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Ask agents to review diffs and read their verdicts",
+	Long: `Queue a diff for an agent to review and read the verdict it gives.
+
+When the scheduler dispatches a review item, it takes the diff with git in
+the agent's workspace, sends it with the review prompt, and records the
+review as awaiting a verdict. Once the agent goes idle again, its answer
+is read from the pane and the fenced JSON verdict block the prompt asks
+for is parsed and stored. Answers without a readable verdict are kept as
+unparsed, with the raw text.`,
+}
+
+var reviewRequestCmd = &cobra.Command{
+	Use:   "request",
+	Short: "Queue a diff for an agent to review",
+	Long: `Queue a review item for an agent. The diff is taken when the item is
+dispatched, so the range is resolved against the workspace's git then.
+
+--path and --exclude take globs matched against the whole path or, without
+a "/", the file name, or a directory containing the file. Files are kept
+whole up to --max-diff-bytes; the prompt lists the files left out.`,
+	Example: `  swarm review request --agent abc123 --range main..HEAD
+  swarm review request --agent abc123 --range HEAD~3..HEAD --path internal/db --exclude '*_test.go'
+  swarm review request --agent abc123 --patch ../fix.patch --instructions "focus on error handling"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		payload := models.ReviewPayload{
+			Range:        strings.TrimSpace(reviewRequestRange),
+			PatchFile:    strings.TrimSpace(reviewRequestPatch),
+			Paths:        reviewRequestPaths,
+			Exclude:      reviewRequestExclude,
+			MaxDiffBytes: reviewRequestMaxDiffBytes,
+			Instructions: strings.TrimSpace(reviewRequestInstructions),
+		}
+		if err := payload.Validate(); err != nil {
+			return invalidInputError("%v", err)
+		}
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode payload: %w", err)
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		target, err := findAgent(ctx, db.NewAgentRepository(database), reviewRequestAgent)
+		if err != nil {
+			return err
+		}
+
+		item := &models.QueueItem{
+			AgentID: target.ID,
+			Type:    models.QueueItemTypeReview,
+			Status:  models.QueueItemStatusPending,
+			Payload: payloadBytes,
+		}
+		queueService := queue.NewService(db.NewQueueRepository(database), queue.WithAuditRecorder(newAuditRecorder(database)))
+		if reviewRequestFront {
+			err = queueService.InsertAt(ctx, target.ID, 0, item)
+		} else {
+			err = queueService.Enqueue(ctx, target.ID, item)
+		}
+		if err != nil {
+			return wrapServiceError(err, "failed to queue review for agent %s", shortID(target.ID))
+		}
+
+		record := &models.Review{
+			QueueItemID: item.ID,
+			AgentID:     target.ID,
+			Range:       payload.Range,
+			PatchFile:   payload.PatchFile,
+		}
+		if err := db.NewReviewRepository(database).Create(ctx, record); err != nil {
+			return wrapServiceError(err, "failed to record review")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, record)
+		}
+		fmt.Printf("✓ Queued review %s of %s for agent %s\n", shortID(record.ID), payload.Source(), shortID(target.ID))
+		fmt.Printf("  Read the verdict with: swarm review show %s\n", shortID(record.ID))
+		return nil
+	},
+}
+
+var reviewShowCmd = &cobra.Command{
+	Use:   "show <review-id>",
+	Short: "Show a review and its verdict",
+	Long: `Show a review: what was sent, its status, and the agent's verdict and
+comments. The review can be named by its ID or its queue item's ID, or a
+prefix of either. Unparsed reviews show the agent's raw answer.`,
+	Example: `  swarm review show 3f2a9c1d
+  swarm review show 3f2a9c1d --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		record, err := findReview(ctx, db.NewReviewRepository(database), args[0])
+		if err != nil {
+			return err
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, record)
+		}
+		printReview(record)
+		return nil
+	},
+}
+
+func printReview(record *models.Review) {
+	source := record.Range
+	if record.PatchFile != "" {
+		source = record.PatchFile
+	}
+	fmt.Printf("Review %s of %s\n", shortID(record.ID), source)
+	fmt.Printf("  Agent:   %s\n", shortID(record.AgentID))
+	fmt.Printf("  Status:  %s\n", record.Status)
+	if record.DispatchedAt != nil {
+		diff := fmt.Sprintf("%d files, %d bytes", record.DiffFiles, record.DiffBytes)
+		if record.Truncated {
+			diff += " (truncated)"
+		}
+		fmt.Printf("  Diff:    %s\n", diff)
+	}
+	if record.Verdict != "" {
+		fmt.Printf("  Verdict: %s\n", record.Verdict)
+	}
+	if record.Summary != "" {
+		fmt.Printf("  Summary: %s\n", record.Summary)
+	}
+	if record.Error != "" {
+		fmt.Printf("  Error:   %s\n", record.Error)
+	}
+
+	if len(record.Comments) > 0 {
+		fmt.Printf("\nComments (%d):\n", len(record.Comments))
+		for _, comment := range record.Comments {
+			location := comment.Path
+			if location != "" && comment.Line > 0 {
+				location = fmt.Sprintf("%s:%d", location, comment.Line)
+			}
+			if location != "" {
+				fmt.Printf("  - %s: %s\n", location, comment.Body)
+			} else {
+				fmt.Printf("  - %s\n", comment.Body)
+			}
+		}
+	}
+
+	if record.Status == models.ReviewStatusUnparsed && record.RawOutput != "" {
+		fmt.Printf("\nRaw answer:\n%s\n", record.RawOutput)
+	}
+}
+
+func findReview(ctx context.Context, repo *db.ReviewRepository, idOrPrefix string) (*models.Review, error) {
+	idOrPrefix = strings.TrimSpace(idOrPrefix)
+	if idOrPrefix == "" {
+		return nil, invalidInputError("review ID required")
+	}
+
+	record, err := repo.Get(ctx, idOrPrefix)
+	if err == nil {
+		return record, nil
+	}
+	if !errors.Is(err, db.ErrReviewNotFound) {
+		return nil, wrapServiceError(err, "failed to get review")
+	}
+
+	reviews, err := repo.List(ctx, "")
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list reviews")
+	}
+	matches := make([]*models.Review, 0)
+	for _, candidate := range reviews {
+		if strings.HasPrefix(candidate.ID, idOrPrefix) || strings.HasPrefix(candidate.QueueItemID, idOrPrefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, notFoundError("review '%s' not found", idOrPrefix)
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, match := range matches {
+			ids = append(ids, shortID(match.ID))
+		}
+		return nil, invalidInputError("review '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrPrefix, strings.Join(ids, ", "))
+	}
+}
@@ -2,30 +2,19 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/opencode-ai/swarm/internal/adapters"
 	"github.com/opencode-ai/swarm/internal/config"
 	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/trace"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 )
 
 var (
-	// Global flags
-	cfgFile        string
-	jsonOutput     bool
-	jsonlOutput    bool
-	watchMode      bool
-	sinceDur       string
-	verbose        bool
-	noColor        bool
-	noProgress     bool
-	nonInteractive bool
-	yesFlag        bool
-	logLevel       string
-	logFormat      string
-
 	// Global config loader and config
 	configLoader *config.Loader
 	appConfig    *config.Config
@@ -33,10 +22,16 @@ var (
 )
 
 // rootCmd represents the base command when called without any subcommands
-var rootCmd = &cobra.Command{
-	Use:   "swarm",
-	Short: "Control plane for AI coding agents",
-	Long: `Swarm is a control plane for running and supervising AI coding agents
+var rootCmd = newRootCmd()
+
+// newRootCmd builds the base command with the global flags, which each
+// command reads through its Options rather than package state. Subcommands
+// are added to rootCmd by init.
+func newRootCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "swarm",
+		Short: "Control plane for AI coding agents",
+		Long: `Swarm is a control plane for running and supervising AI coding agents
 across multiple repositories and servers.
 
 It provides:
@@ -46,42 +41,64 @@ It provides:
   - Multi-account orchestration with cooldown management
 
 Run 'swarm' without arguments to launch the TUI dashboard.`,
-	// Default action is to launch TUI
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return runTUI()
-	},
+		// Default action is to launch TUI
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTUI(commandOptions(cmd))
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			opts := resolveOptions(cmd)
+			cmd.SetContext(withOptions(cmd.Context(), opts))
+			applyCommandTimeout(cmd, opts.Timeout)
+			return runPreflight(cmd)
+		},
+	}
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withCategory(ErrInvalidInput, err)
+	})
+
+	// Global flags
+	flags := cmd.PersistentFlags()
+	flags.String("config", "", "config file (default is $HOME/.config/swarm/config.yaml)")
+	flags.Bool("json", false, "output in JSON format")
+	flags.Bool("jsonl", false, "output in JSON Lines format (for streaming)")
+	flags.Bool("json-errors", false, "print errors as a JSON object on stderr")
+	flags.Bool("json-envelope", false, "wrap --json/--jsonl output in {\"schema_version\", \"data\"}")
+	flags.Bool("watch", false, "watch for changes and stream updates")
+	flags.String("since", "", "replay events since duration (e.g., 1h, 30m, 24h) or timestamp")
+	flags.String("format", "", "render --watch event streams as jsonl (default), pretty, or template='<go template>'")
+	flags.BoolP("verbose", "v", false, "enable verbose output")
+	flags.Bool("no-color", false, "disable colored output")
+	flags.Bool("no-progress", false, "disable progress output")
+	flags.Bool("non-interactive", false, "run without prompts, use defaults")
+	flags.BoolP("yes", "y", false, "skip confirmation prompts")
+	flags.String("log-level", "", "override logging level (debug, info, warn, error)")
+	flags.String("log-format", "", "override logging format (json, console)")
+	flags.Duration("timeout", 0, "abort the command after this long (e.g. 30s, 5m; 0 = no limit)")
+	flags.Int("width", 0, "fit tables to this many columns (default: the terminal width; output that is not a terminal is not narrowed)")
+	flags.StringSlice("columns", nil, "show only these table columns, by header (e.g. name,status)")
+	return cmd
 }
 
-// Execute runs the root command
+// Execute runs the root command. Each invocation starts a trace, or joins
+// the one named by SWARM_TRACE_ID, that follows its work into events,
+// dispatches and daemon calls. The command's context is cancelled on
+// SIGINT or SIGTERM, or when --timeout passes.
 func Execute(version, commit, date string) error {
 	rootCmd.Version = formatVersion(version, commit, date)
 	rootCmd.SilenceErrors = true
 	rootCmd.SilenceUsage = true
-	if err := rootCmd.Execute(); err != nil {
-		return handleCLIError(err)
+
+	ctx, stop := newCommandContext(trace.Start(context.Background()))
+	defer stop()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		return handleCLIError(commandError(ctx, err))
 	}
 	return nil
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
-	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		return runPreflight(cmd)
-	}
-
-	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/swarm/config.yaml)")
-	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
-	rootCmd.PersistentFlags().BoolVar(&jsonlOutput, "jsonl", false, "output in JSON Lines format (for streaming)")
-	rootCmd.PersistentFlags().BoolVar(&watchMode, "watch", false, "watch for changes and stream updates")
-	rootCmd.PersistentFlags().StringVar(&sinceDur, "since", "", "replay events since duration (e.g., 1h, 30m, 24h) or timestamp")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
-	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output")
-	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "disable progress output")
-	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "run without prompts, use defaults")
-	rootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "skip confirmation prompts")
-	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "override logging level (debug, info, warn, error)")
-	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "override logging format (json, console)")
+	rootCmd.AddCommand(newAccountsCmd(), newAgentCmd(), newLockCmd(), newProvidersCmd(), newQueueCmd(), newSnapshotCmd(), newWsCmd())
 }
 
 // initConfig loads configuration using Viper with proper precedence:
@@ -90,7 +107,7 @@ func initConfig() {
 	configLoader = config.NewLoader()
 
 	// Set explicit config file if provided via CLI flag
-	if cfgFile != "" {
+	if cfgFile := rootOptions().ConfigFile; cfgFile != "" {
 		configLoader.SetConfigFile(cfgFile)
 	}
 
@@ -104,6 +121,11 @@ func initConfig() {
 
 	applyCLIOverrides()
 
+	if err := adapters.ApplyOverrides(appConfig.AdapterOverrides()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error applying adapter overrides: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Initialize logging based on config
 	initLogging()
 
@@ -122,13 +144,13 @@ func applyCLIOverrides() {
 	flags := rootCmd.PersistentFlags()
 
 	if flags.Changed("log-level") {
-		appConfig.Logging.Level = logLevel
-	} else if verbose {
+		appConfig.Logging.Level, _ = flags.GetString("log-level")
+	} else if verbose, _ := flags.GetBool("verbose"); verbose {
 		appConfig.Logging.Level = "debug"
 	}
 
 	if flags.Changed("log-format") {
-		appConfig.Logging.Format = logFormat
+		appConfig.Logging.Format, _ = flags.GetString("log-format")
 	}
 }
 
@@ -160,29 +182,36 @@ func GetLogger() zerolog.Logger {
 	return logger
 }
 
-// IsJSONOutput returns true if JSON output mode is enabled.
+// IsJSONOutput returns true if JSON output mode is enabled. Like the other
+// accessors below it reads the root command's flags; commands that build
+// their own tree use commandOptions instead.
 func IsJSONOutput() bool {
-	return jsonOutput
+	return rootOptions().JSON
 }
 
 // IsJSONLOutput returns true if JSONL output mode is enabled.
 func IsJSONLOutput() bool {
-	return jsonlOutput
+	return rootOptions().JSONL
 }
 
 // IsWatchMode returns true if watch mode is enabled.
 func IsWatchMode() bool {
-	return watchMode
+	return rootOptions().Watch
+}
+
+// GetWatchFormat returns the --format value for --watch event streams.
+func GetWatchFormat() string {
+	return rootOptions().WatchFormat
 }
 
 // IsVerbose returns true if verbose mode is enabled.
 func IsVerbose() bool {
-	return verbose
+	return rootOptions().Verbose
 }
 
 // GetSinceFlag returns the raw --since flag value.
 func GetSinceFlag() string {
-	return sinceDur
+	return rootOptions().Since
 }
 
 func formatVersion(version, commit, date string) string {
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes for run beyond the generic 0/1/2.
+const (
+	runExitTimeout = 3
+	runExitFailed  = 4
+)
+
+// runTmuxClient builds the tmux client ephemeral agents are spawned with;
+// tests replace it with a fake server.
+var runTmuxClient = tmux.NewLocalClient
+
+var (
+	runWorkspace string
+	runType      string
+	runPrompt    string
+	runModel     string
+	runTimeout   time.Duration
+	runOutput    string
+)
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().StringVarP(&runWorkspace, "workspace", "w", "", "workspace name or ID (uses context if not set)")
+	runCmd.Flags().StringVarP(&runType, "type", "t", string(models.AgentTypeClaudeCode), "agent type (opencode, claude-code, codex, gemini, generic)")
+	runCmd.Flags().StringVarP(&runPrompt, "prompt", "p", "", "prompt to run ('-' for stdin)")
+	runCmd.Flags().StringVar(&runModel, "model", "", "model to run the agent with (default: workspace or CLI default)")
+	runCmd.Flags().DurationVar(&runTimeout, "timeout", 15*time.Minute, "maximum time for the whole run, spawn included")
+	runCmd.Flags().StringVarP(&runOutput, "output", "o", "", "write the response to a file instead of stdout")
+	_ = runCmd.MarkFlagRequired("prompt")
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a prompt on a one-shot agent",
+	Long: `Spawn an ephemeral agent, send it a prompt, wait for it to finish and
+print its response, then terminate the agent.
+
+The agent is hidden from 'swarm agent list' unless --all is given, and its
+pane is killed however the run ends, including on Ctrl+C and on timeout.
+
+Exit codes:
+  0: The agent answered
+  1: Error (invalid flags, workspace not found, interrupted)
+  3: Timeout reached
+  4: The agent failed or exited before answering`,
+	Example: `  # Ask a question and print the answer
+  swarm run --workspace api --prompt "Why does TestLogin fail?"
+
+  # Save the answer, giving up after five minutes
+  swarm run -w api -p "Summarize the open TODOs" --timeout 5m --output todos.md
+
+  # Read the prompt from stdin
+  git diff | swarm run -w api -t codex -p -`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prompt, err := readRunPrompt(runPrompt)
+		if err != nil {
+			return err
+		}
+		if runTimeout <= 0 {
+			return invalidInputError("--timeout must be positive")
+		}
+		agentType := models.AgentType(runType)
+		if !adapters.IsRegistered(agentType) {
+			return invalidInputError("invalid agent type: %s", runType)
+		}
+
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		nodeRepo := db.NewNodeRepository(database)
+		wsRepo := db.NewWorkspaceRepository(database)
+		agentRepo := db.NewAgentRepository(database)
+		queueRepo := db.NewQueueRepository(database)
+		wsService := workspace.NewService(wsRepo, node.NewService(nodeRepo), agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, runTmuxClient(), agentServiceOptions(database)...)
+
+		resolved, err := RequireWorkspaceContext(ctx, wsRepo, runWorkspace)
+		if err != nil {
+			return err
+		}
+		ws, err := wsRepo.Get(ctx, resolved.WorkspaceID)
+		if err != nil {
+			return wrapServiceError(err, "failed to get workspace")
+		}
+
+		approvalPolicy := ""
+		if cfg := GetConfig(); cfg != nil {
+			approvalPolicy = cfg.ApprovalPolicyForWorkspace(ws).Mode
+		}
+
+		if !IsJSONOutput() && !IsJSONLOutput() {
+			fmt.Fprintf(os.Stderr, "Running %s agent in workspace %s...\n", agentType, ws.Name)
+		}
+
+		result, runErr := agentService.Run(ctx, agent.RunOptions{
+			Spawn: agent.SpawnOptions{
+				WorkspaceID:          ws.ID,
+				Type:                 agentType,
+				ApprovalPolicy:       approvalPolicy,
+				Model:                resolveSpawnModel(ws, agentType, runModel),
+				WorkspaceEnvironment: workspaceSpawnEnv(ws),
+			},
+			Prompt:  prompt,
+			Timeout: runTimeout,
+		})
+
+		view := runView{Status: "completed"}
+		if result != nil {
+			view.RunResult = *result
+		}
+		exitCode := 0
+		switch {
+		case runErr == nil:
+		case errors.Is(runErr, agent.ErrRunTimeout):
+			view.Status, view.Error = "timeout", runErr.Error()
+			exitCode = runExitTimeout
+		case errors.Is(runErr, agent.ErrRunFailed):
+			view.Status, view.Error = "failed", runErr.Error()
+			exitCode = runExitFailed
+		case errors.Is(runErr, context.Canceled):
+			return fmt.Errorf("run interrupted; agent terminated")
+		default:
+			return wrapServiceError(runErr, "failed to run prompt")
+		}
+
+		if runErr == nil && runOutput != "" {
+			if err := os.WriteFile(runOutput, []byte(view.Response+"\n"), 0o644); err != nil {
+				return fmt.Errorf("failed to write response: %w", err)
+			}
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			if err := WriteOutput(os.Stdout, view); err != nil {
+				return err
+			}
+		} else if runErr != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", runErr)
+		} else if runOutput == "" {
+			fmt.Println(view.Response)
+		} else {
+			fmt.Fprintf(os.Stderr, "Response written to %s\n", runOutput)
+		}
+
+		if exitCode != 0 {
+			return &ExitError{Code: exitCode, Err: runErr, Printed: true}
+		}
+		return nil
+	},
+}
+
+// runView is the JSON shape emitted by run.
+type runView struct {
+	agent.RunResult
+	// Status is completed, timeout or failed.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func readRunPrompt(value string) (string, error) {
+	if value == "-" {
+		return readMessageFromStdin()
+	}
+	if strings.TrimSpace(value) == "" {
+		return "", invalidInputError("--prompt must not be empty")
+	}
+	return value, nil
+}
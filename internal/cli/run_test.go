@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func TestRun(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	srv := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+		term.OnInput(func(line string) {
+			if line == "Never finish" {
+				term.Print("⠙ Thinking… (esc to interrupt)\n")
+				return
+			}
+			term.Print("⠙ Thinking… (esc to interrupt)\n")
+			term.PrintAfter(20*time.Millisecond, "⏺ Use a mutex.\n\nclaude> ")
+		})
+	}))
+	client := tmux.NewClient(srv)
+	previous := runTmuxClient
+	runTmuxClient = func() *tmux.Client { return client }
+	t.Cleanup(func() { runTmuxClient = previous })
+
+	seeded := seedQueueAgent(t, database)
+	ws, err := db.NewWorkspaceRepository(database).Get(ctx, seeded.WorkspaceID)
+	if err != nil {
+		t.Fatalf("get workspace: %v", err)
+	}
+	if err := client.NewSession(ctx, ws.TmuxSession, "/repo"); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if err := client.NewWindow(ctx, ws.TmuxSession, tmux.AgentWindowName, "/repo"); err != nil {
+		t.Fatalf("NewWindow: %v", err)
+	}
+	panes := func() int {
+		list, err := client.ListPanes(ctx, ws.TmuxSession+":"+tmux.AgentWindowName)
+		if err != nil {
+			t.Fatalf("ListPanes: %v", err)
+		}
+		return len(list)
+	}
+
+	output := filepath.Join(t.TempDir(), "result.txt")
+	if code, err := runCommand(t, runCmd, "--workspace", ws.ID, "--prompt", "How do I fix the race?", "--output", output); code != 0 {
+		t.Fatalf("run failed with exit %d: %v", code, err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if string(data) != "Use a mutex.\n" {
+		t.Fatalf("expected the response written, got %q", data)
+	}
+	if got := panes(); got != 1 {
+		t.Fatalf("expected the agent pane killed, got %d panes", got)
+	}
+
+	if code, _ := runCommand(t, runCmd, "--workspace", ws.ID, "--prompt", "Never finish", "--timeout", "300ms"); code != runExitTimeout {
+		t.Fatalf("expected exit %d on timeout, got %d", runExitTimeout, code)
+	}
+	if got := panes(); got != 1 {
+		t.Fatalf("expected the agent pane killed on timeout, got %d panes", got)
+	}
+
+	if code, _ := runCommand(t, runCmd, "--workspace", ws.ID, "--prompt", " "); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d for an empty prompt, got %d", ExitCodeInvalidInput, code)
+	}
+}
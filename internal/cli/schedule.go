@@ -0,0 +1,403 @@
+// Package cli provides recurring schedule commands.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/cron"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+// schedulePreviewRuns is how many upcoming runs schedule add previews.
+const schedulePreviewRuns = 3
+
+var (
+	scheduleAgent     string
+	scheduleWorkspace string
+	scheduleCron      string
+	scheduleMessage   string
+	scheduleTemplate  string
+	scheduleVars      []string
+	scheduleDisabled  bool
+)
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+
+	scheduleCmd.AddCommand(scheduleAddCmd)
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+	scheduleCmd.AddCommand(scheduleEnableCmd)
+	scheduleCmd.AddCommand(scheduleDisableCmd)
+
+	scheduleAddCmd.Flags().StringVarP(&scheduleAgent, "agent", "a", "", "target agent ID or prefix")
+	scheduleAddCmd.Flags().StringVarP(&scheduleWorkspace, "workspace", "w", "", "target every agent in a workspace (ID or name)")
+	scheduleAddCmd.Flags().StringVar(&scheduleCron, "cron", "", "5-field cron expression (minute hour day-of-month month day-of-week)")
+	scheduleAddCmd.Flags().StringVarP(&scheduleMessage, "message", "m", "", "message to enqueue on each run")
+	scheduleAddCmd.Flags().StringVar(&scheduleTemplate, "template", "", "render the message from a template")
+	scheduleAddCmd.Flags().StringSliceVar(&scheduleVars, "var", nil, "template variable (key=value)")
+	scheduleAddCmd.Flags().BoolVar(&scheduleDisabled, "disabled", false, "create the schedule disabled")
+}
+
+var scheduleCmd = &cobra.Command{
+	Use:     "schedule",
+	Aliases: []string{"schedules"},
+	Short:   "Manage recurring messages",
+	Long: `Enqueue a message on a cron schedule, for one agent or for every agent
+in a workspace.
+
+Schedules are run by swarmd. Cron expressions are evaluated in the
+scheduler.schedule_timezone config setting (local time by default). A run
+that falls in a daylight-saving gap happens when the clocks resume; a
+repeated hour runs once. Runs missed while swarmd was down follow
+scheduler.schedule_catch_up: "skip" drops them, "run_once" enqueues the
+message once.`,
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a recurring schedule",
+	Example: `  swarm schedule add --agent abc123 --cron "0 9 * * 1-5" --message "Summarize yesterday's commits"
+  swarm schedule add --workspace my-project --cron "@hourly" --template commit`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		hasAgent := strings.TrimSpace(scheduleAgent) != ""
+		hasWorkspace := strings.TrimSpace(scheduleWorkspace) != ""
+		if hasAgent == hasWorkspace {
+			return invalidInputError("exactly one of --agent or --workspace is required")
+		}
+		hasMessage := strings.TrimSpace(scheduleMessage) != ""
+		hasTemplate := strings.TrimSpace(scheduleTemplate) != ""
+		if hasMessage == hasTemplate {
+			return invalidInputError("exactly one of --message or --template is required")
+		}
+		if len(scheduleVars) > 0 && !hasTemplate {
+			return invalidInputError("--var requires --template")
+		}
+
+		expr, err := cron.Parse(scheduleCron)
+		if err != nil {
+			return invalidInputError("invalid --cron: %v", err)
+		}
+		loc, err := scheduleLocation()
+		if err != nil {
+			return err
+		}
+		runs := expr.NextN(time.Now().In(loc), schedulePreviewRuns)
+		if len(runs) == 0 {
+			return invalidInputError("--cron %q never runs", scheduleCron)
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		wsRepo := db.NewWorkspaceRepository(database)
+		schedule := &models.Schedule{
+			Cron:    expr.String(),
+			Message: scheduleMessage,
+			Enabled: !scheduleDisabled,
+		}
+
+		// Templates are rendered from the target's repository.
+		var projectWorkspaceID string
+		if hasAgent {
+			agentInfo, err := findAgent(ctx, db.NewAgentRepository(database), scheduleAgent)
+			if err != nil {
+				return err
+			}
+			schedule.AgentID = agentInfo.ID
+			projectWorkspaceID = agentInfo.WorkspaceID
+		} else {
+			ws, err := findWorkspace(ctx, wsRepo, scheduleWorkspace)
+			if err != nil {
+				return err
+			}
+			schedule.WorkspaceID = ws.ID
+			projectWorkspaceID = ws.ID
+		}
+
+		if hasTemplate {
+			projectDir := ""
+			if ws, err := wsRepo.Get(ctx, projectWorkspaceID); err == nil {
+				projectDir = ws.RepoPath
+			}
+			message, name, err := renderScheduleTemplate(projectDir, scheduleTemplate, scheduleVars)
+			if err != nil {
+				return err
+			}
+			schedule.Message = message
+			schedule.Template = name
+		}
+
+		if schedule.Enabled {
+			next := runs[0].UTC()
+			schedule.NextRunAt = &next
+		}
+		if err := db.NewScheduleRepository(database).Create(ctx, schedule); err != nil {
+			return wrapServiceError(err, "failed to create schedule")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, scheduleAddResult{Schedule: schedule, NextRuns: runs})
+		}
+
+		fmt.Printf("Created schedule %s (%s)\n", shortID(schedule.ID), scheduleTargetLabel(schedule))
+		if !schedule.Enabled {
+			fmt.Println("Schedule is disabled; enable it with 'swarm schedule enable'.")
+		}
+		fmt.Println("Next runs:")
+		for _, run := range runs {
+			fmt.Printf("  %s\n", run.Format("Mon 2006-01-02 15:04 MST"))
+		}
+		return nil
+	},
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List schedules",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		schedules, err := db.NewScheduleRepository(database).List(ctx)
+		if err != nil {
+			return wrapServiceError(err, "failed to list schedules")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			if schedules == nil {
+				schedules = []*models.Schedule{}
+			}
+			return WriteOutput(os.Stdout, schedules)
+		}
+		if len(schedules) == 0 {
+			fmt.Println("No schedules found")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(schedules))
+		for _, schedule := range schedules {
+			next := "-"
+			if schedule.Enabled && schedule.NextRunAt != nil {
+				next = formatTimeUntil(*schedule.NextRunAt)
+			}
+			last := "-"
+			if schedule.LastRunAt != nil {
+				last = formatRelativeTime(*schedule.LastRunAt)
+			}
+			rows = append(rows, []string{
+				shortID(schedule.ID),
+				scheduleTargetLabel(schedule),
+				schedule.Cron,
+				formatYesNo(schedule.Enabled),
+				next,
+				last,
+				truncate(schedule.Message, 40),
+			})
+		}
+		return writeTable(os.Stdout, []string{"ID", "TARGET", "CRON", "ENABLED", "NEXT RUN", "LAST RUN", "MESSAGE"}, rows)
+	},
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:     "remove <schedule-id>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a schedule",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		repo := db.NewScheduleRepository(database)
+		schedule, err := findSchedule(ctx, repo, args[0])
+		if err != nil {
+			return err
+		}
+		if err := repo.Delete(ctx, schedule.ID); err != nil {
+			return wrapServiceError(err, "failed to remove schedule")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, map[string]any{"removed": true, "schedule_id": schedule.ID})
+		}
+		fmt.Printf("Removed schedule %s\n", shortID(schedule.ID))
+		return nil
+	},
+}
+
+var scheduleEnableCmd = &cobra.Command{
+	Use:   "enable <schedule-id>",
+	Short: "Enable a schedule",
+	Long:  "Enable a schedule. Its next run is computed from now, so runs that passed while it was disabled are not caught up.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setScheduleEnabled(cmd.Context(), args[0], true)
+	},
+}
+
+var scheduleDisableCmd = &cobra.Command{
+	Use:   "disable <schedule-id>",
+	Short: "Disable a schedule",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setScheduleEnabled(cmd.Context(), args[0], false)
+	},
+}
+
+type scheduleAddResult struct {
+	Schedule *models.Schedule `json:"schedule"`
+	NextRuns []time.Time      `json:"next_runs"`
+}
+
+func setScheduleEnabled(ctx context.Context, idOrPrefix string, enabled bool) error {
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	repo := db.NewScheduleRepository(database)
+	schedule, err := findSchedule(ctx, repo, idOrPrefix)
+	if err != nil {
+		return err
+	}
+
+	loc, err := scheduleLocation()
+	if err != nil {
+		return err
+	}
+	var next *time.Time
+	if enabled {
+		expr, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			return invalidInputError("schedule %s has an invalid cron expression: %v", shortID(schedule.ID), err)
+		}
+		t := expr.Next(time.Now().In(loc))
+		if t.IsZero() {
+			return invalidInputError("schedule %s never runs", shortID(schedule.ID))
+		}
+		t = t.UTC()
+		next = &t
+	}
+	if err := repo.SetEnabled(ctx, schedule.ID, enabled, next); err != nil {
+		return wrapServiceError(err, "failed to update schedule")
+	}
+	schedule.Enabled = enabled
+	schedule.NextRunAt = next
+
+	if IsJSONOutput() || IsJSONLOutput() {
+		return WriteOutput(os.Stdout, schedule)
+	}
+	if enabled {
+		fmt.Printf("Enabled schedule %s (next run %s)\n", shortID(schedule.ID), next.In(loc).Format("Mon 2006-01-02 15:04 MST"))
+	} else {
+		fmt.Printf("Disabled schedule %s\n", shortID(schedule.ID))
+	}
+	return nil
+}
+
+// scheduleLocation returns the time zone schedules are evaluated in, matching
+// the daemon's setting.
+func scheduleLocation() (*time.Location, error) {
+	cfg := GetConfig()
+	if cfg == nil {
+		return time.Local, nil
+	}
+	loc, err := cfg.Scheduler.ScheduleLocation()
+	if err != nil {
+		return nil, invalidInputError("invalid scheduler.schedule_timezone: %v", err)
+	}
+	return loc, nil
+}
+
+func renderScheduleTemplate(projectDir, name string, rawVars []string) (string, string, error) {
+	templatesList, err := templates.LoadTemplatesFromSearchPaths(projectDir)
+	if err != nil {
+		return "", "", err
+	}
+	tmpl := findTemplateByName(templatesList, name)
+	if tmpl == nil {
+		return "", "", notFoundError("template %q not found", name)
+	}
+	vars, err := parseTemplateVars(rawVars)
+	if err != nil {
+		return "", "", invalidInputError("%v", err)
+	}
+	message, err := templates.RenderTemplate(tmpl, vars)
+	if err != nil {
+		return "", "", invalidInputError("%v", err)
+	}
+	return message, tmpl.Name, nil
+}
+
+func scheduleTargetLabel(schedule *models.Schedule) string {
+	if schedule.WorkspaceID != "" {
+		return "ws " + shortID(schedule.WorkspaceID)
+	}
+	return "agent " + shortID(schedule.AgentID)
+}
+
+func findSchedule(ctx context.Context, repo *db.ScheduleRepository, idOrPrefix string) (*models.Schedule, error) {
+	idOrPrefix = strings.TrimSpace(idOrPrefix)
+	if idOrPrefix == "" {
+		return nil, invalidInputError("schedule ID required")
+	}
+
+	schedule, err := repo.Get(ctx, idOrPrefix)
+	if err == nil {
+		return schedule, nil
+	}
+	if !errors.Is(err, db.ErrScheduleNotFound) {
+		return nil, wrapServiceError(err, "failed to get schedule")
+	}
+
+	schedules, err := repo.List(ctx)
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list schedules")
+	}
+	matches := make([]*models.Schedule, 0)
+	for _, candidate := range schedules {
+		if strings.HasPrefix(candidate.ID, idOrPrefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, notFoundError("schedule '%s' not found", idOrPrefix)
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, match := range matches {
+			ids = append(ids, shortID(match.ID))
+		}
+		return nil, invalidInputError("schedule '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrPrefix, strings.Join(ids, ", "))
+	}
+}
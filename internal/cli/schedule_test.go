@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+)
+
+func TestScheduleAddListLifecycle(t *testing.T) {
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+	ctx := context.Background()
+
+	out := captureStdout(t, func() {
+		if code, err := runCommand(t, scheduleAddCmd, "--agent", agent.ID, "--cron", "0 9 * * 1-5", "--message", "standup"); code != 0 {
+			t.Errorf("schedule add failed with exit %d: %v", code, err)
+		}
+	})
+	if !strings.Contains(string(out), "Next runs:") || strings.Count(string(out), " 09:00 ") != 3 {
+		t.Fatalf("expected a preview of 3 runs at 09:00:\n%s", out)
+	}
+
+	repo := db.NewScheduleRepository(database)
+	schedules, err := repo.List(ctx)
+	if err != nil || len(schedules) != 1 {
+		t.Fatalf("expected 1 schedule, got %d (%v)", len(schedules), err)
+	}
+	schedule := schedules[0]
+	if !schedule.Enabled || schedule.NextRunAt == nil || !schedule.NextRunAt.After(time.Now()) {
+		t.Fatalf("unexpected schedule: %+v", schedule)
+	}
+
+	out = captureStdout(t, func() {
+		if code, err := runCommand(t, scheduleListCmd); code != 0 {
+			t.Errorf("schedule ls failed with exit %d: %v", code, err)
+		}
+	})
+	if !strings.Contains(string(out), shortID(schedule.ID)) || !strings.Contains(string(out), "0 9 * * 1-5") {
+		t.Fatalf("expected schedule in list:\n%s", out)
+	}
+
+	prefix := schedule.ID[:8]
+	captureStdout(t, func() {
+		if code, err := runCommand(t, scheduleDisableCmd, prefix); code != 0 {
+			t.Errorf("schedule disable failed with exit %d: %v", code, err)
+		}
+	})
+	got, _ := repo.Get(ctx, schedule.ID)
+	if got.Enabled || got.NextRunAt != nil {
+		t.Fatalf("expected disabled schedule without a next run: %+v", got)
+	}
+
+	captureStdout(t, func() {
+		if code, err := runCommand(t, scheduleEnableCmd, prefix); code != 0 {
+			t.Errorf("schedule enable failed with exit %d: %v", code, err)
+		}
+	})
+	got, _ = repo.Get(ctx, schedule.ID)
+	if !got.Enabled || got.NextRunAt == nil || !got.NextRunAt.After(time.Now()) {
+		t.Fatalf("expected enabled schedule with a future next run: %+v", got)
+	}
+
+	captureStdout(t, func() {
+		if code, err := runCommand(t, scheduleRemoveCmd, prefix); code != 0 {
+			t.Errorf("schedule remove failed with exit %d: %v", code, err)
+		}
+	})
+	if code, _ := runCommand(t, scheduleRemoveCmd, prefix); code != ExitCodeNotFound {
+		t.Fatalf("expected exit %d removing a missing schedule, got %d", ExitCodeNotFound, code)
+	}
+}
+
+func TestScheduleAddWorkspaceJSON(t *testing.T) {
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+
+	out := runJSONCommand(t, scheduleAddCmd, "--workspace", agent.WorkspaceID, "--cron", "@hourly", "--message", "commit")
+	var result scheduleAddResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if result.Schedule.WorkspaceID != agent.WorkspaceID || result.Schedule.AgentID != "" {
+		t.Fatalf("expected a workspace target: %+v", result.Schedule)
+	}
+	if len(result.NextRuns) != 3 || result.NextRuns[1].Sub(result.NextRuns[0]) != time.Hour {
+		t.Fatalf("expected 3 hourly runs, got %v", result.NextRuns)
+	}
+	if !result.Schedule.NextRunAt.Equal(result.NextRuns[0]) {
+		t.Fatalf("next run %v does not match preview %v", result.Schedule.NextRunAt, result.NextRuns[0])
+	}
+}
+
+func TestScheduleAddRejectsInvalidInput(t *testing.T) {
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+
+	cases := map[string][]string{
+		"bad cron":    {"--agent", agent.ID, "--cron", "0 25 * * *", "--message", "hi"},
+		"short cron":  {"--agent", agent.ID, "--cron", "0 9 *", "--message", "hi"},
+		"never runs":  {"--agent", agent.ID, "--cron", "0 0 30 2 *", "--message", "hi"},
+		"no target":   {"--cron", "@daily", "--message", "hi"},
+		"two targets": {"--agent", agent.ID, "--workspace", agent.WorkspaceID, "--cron", "@daily", "--message", "hi"},
+		"no message":  {"--agent", agent.ID, "--cron", "@daily"},
+		"var no tmpl": {"--agent", agent.ID, "--cron", "@daily", "--message", "hi", "--var", "a=b"},
+	}
+	for name, args := range cases {
+		if code, err := runCommand(t, scheduleAddCmd, args...); code != ExitCodeInvalidInput {
+			t.Errorf("%s: expected exit %d, got %d (err=%v)", name, ExitCodeInvalidInput, code, err)
+		}
+	}
+}
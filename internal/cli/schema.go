@@ -0,0 +1,134 @@
+// Package cli provides the schema command for JSON output contracts.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+// outputType is a JSON output type with a published schema.
+type outputType struct {
+	Name        string
+	Description string
+	Type        reflect.Type
+}
+
+// outputTypes lists the types major commands emit with --json. List
+// commands emit an array of the type (one per line with --jsonl).
+var outputTypes = []outputType{
+	{"account", "accounts list/add, accounts cooldown set/clear", reflect.TypeOf(models.Account{})},
+	{"account-status", "accounts status", reflect.TypeOf(account.Status{})},
+	{"agent", "agent spawn/list/move", reflect.TypeOf(models.Agent{})},
+	{"agent-env", "agent env", reflect.TypeOf(agentEnvView{})},
+	{"agent-env-check", "agent env --check", reflect.TypeOf(envCheckView{})},
+	{"agent-stats", "agent list --stats", reflect.TypeOf(agentStatsView{})},
+	{"audit-record", "audit list", reflect.TypeOf(models.AuditRecord{})},
+	{"bundle", "agent bundle, bundle inspect", reflect.TypeOf(bundleView{})},
+	{"checkpoint", "agent checkpoint", reflect.TypeOf(checkpointView{})},
+	{"cli-versions", "agent versions", reflect.TypeOf(agent.CLIVersionReport{})},
+	{"compact-transcripts", "maintenance compact-transcripts", reflect.TypeOf(compactTranscriptsView{})},
+	{"cost-forecast", "usage forecast", reflect.TypeOf(account.Forecast{})},
+	{"daemon-state", "daemon debug", reflect.TypeOf(daemonStateView{})},
+	{"daemon-reload", "daemon reload", reflect.TypeOf(daemonReloadView{})},
+	{"event", "export events, audit", reflect.TypeOf(models.Event{})},
+	{"export-status", "export status", reflect.TypeOf(ExportStatus{})},
+	{"node", "node list", reflect.TypeOf(models.Node{})},
+	{"node-status", "node status", reflect.TypeOf(nodeStatusView{})},
+	{"note", "note add/list/rm", reflect.TypeOf(models.Note{})},
+	{"pane", "agent peek", reflect.TypeOf(agent.PaneView{})},
+	{"pane-check", "agent verify-panes", reflect.TypeOf(agent.PaneCheck{})},
+	{"queue-clear", "queue clear", reflect.TypeOf(queueClearView{})},
+	{"queue-item", "queue entries in export status", reflect.TypeOf(models.QueueItem{})},
+	{"rotation-result", "accounts rotate", reflect.TypeOf(AccountRotationResult{})},
+	{"run-result", "run", reflect.TypeOf(runView{})},
+	{"schedule", "schedule ls/enable/disable", reflect.TypeOf(models.Schedule{})},
+	{"snapshot", "agent snapshot, snapshot list/show", reflect.TypeOf(snapshotView{})},
+	{"task", "task create/show/ls", reflect.TypeOf(taskView{})},
+	{"terminate-plan", "agent terminate --dry-run", reflect.TypeOf(agent.TerminatePlan{})},
+	{"usage-import", "usage import", reflect.TypeOf(account.UsageImportSummary{})},
+	{"usage-record", "export usage", reflect.TypeOf(models.UsageRecord{})},
+	{"usage-summary", "usage summary", reflect.TypeOf(account.UsageReport{})},
+	{"workspace", "ws create/import/list", reflect.TypeOf(models.Workspace{})},
+}
+
+func findOutputType(name string) (outputType, bool) {
+	for _, t := range outputTypes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return outputType{}, false
+}
+
+// outputSchema generates the schema document for a named output type.
+func outputSchema(name string) (*schema.Schema, error) {
+	t, ok := findOutputType(name)
+	if !ok {
+		names := make([]string, 0, len(outputTypes))
+		for _, t := range outputTypes {
+			names = append(names, t.Name)
+		}
+		sort.Strings(names)
+		return nil, notFoundError("unknown output type %q (available: %v)", name, names)
+	}
+	return schema.Generate(t.Name, t.Type), nil
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [type]",
+	Short: "Print JSON Schemas for --json output",
+	Long: `Print the JSON Schema for a --json output type, or list the available types.
+
+Schemas are generated from the output structs, so they always match this
+build. The schema_version field changes only when an output type changes
+incompatibly; pass --json-envelope to any command to get it alongside data.`,
+	Example: `  swarm schema
+  swarm schema agent > agent.schema.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			doc, err := outputSchema(args[0])
+			if err != nil {
+				return err
+			}
+			// A schema is JSON whatever the output mode.
+			return writeJSON(os.Stdout, doc)
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			type entry struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+			}
+			entries := make([]entry, 0, len(outputTypes))
+			for _, t := range outputTypes {
+				entries = append(entries, entry{Name: t.Name, Description: t.Description})
+			}
+			return WriteOutput(os.Stdout, map[string]any{
+				"schema_version": schema.Version,
+				"types":          entries,
+			})
+		}
+
+		fmt.Printf("Schema version %s\n\n", schema.Version)
+		writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(writer, "TYPE\tUSED BY")
+		for _, t := range outputTypes {
+			fmt.Fprintf(writer, "%s\t%s\n", t.Name, t.Description)
+		}
+		return writer.Flush()
+	},
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+// captureStdout runs fn with os.Stdout redirected and returns what it wrote.
+func captureStdout(t *testing.T, fn func()) []byte {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	previous := os.Stdout
+	os.Stdout = writer
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		done <- data
+	}()
+
+	defer func() { os.Stdout = previous }()
+	fn()
+	_ = writer.Close()
+	return <-done
+}
+
+// runJSONCommand runs cmd with --json and returns its stdout. The flag is
+// passed as an argument because runCommand resets inherited flags too.
+func runJSONCommand(t *testing.T, cmd *cobra.Command, args ...string) []byte {
+	t.Helper()
+	var code int
+	var err error
+	out := captureStdout(t, func() { code, err = runCommand(t, cmd, append([]string{"--json"}, args...)...) })
+	if code != 0 {
+		t.Fatalf("%s failed with exit %d: %v", cmd.Name(), code, err)
+	}
+	return out
+}
+
+// TestJSONOutputMatchesSchemas validates real command output, from seeded
+// data, against the published schemas so an incompatible change to an
+// output type fails here before it breaks downstream tooling.
+func TestJSONOutputMatchesSchemas(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+
+	account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "primary", CredentialRef: "env:ANTHROPIC_API_KEY", IsActive: true}
+	if err := db.NewAccountRepository(database).Create(ctx, account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	usage := &models.UsageRecord{AccountID: account.ID, AgentID: agent.ID, Provider: models.ProviderAnthropic, Model: "opus", InputTokens: 10, OutputTokens: 5, RecordedAt: time.Now().UTC()}
+	if err := db.NewUsageRepository(database).Create(ctx, usage); err != nil {
+		t.Fatalf("create usage: %v", err)
+	}
+	event := &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: agent.ID, Payload: json.RawMessage(`{"note":"seeded"}`)}
+	if err := db.NewEventRepository(database).Create(ctx, event); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	record := &models.AuditRecord{Actor: "dana", Operation: "kill_agent", EntityType: models.EntityTypeAgent, EntityID: agent.ID,
+		Parameters: map[string]string{"force": "false"}, Result: models.AuditResultSuccess}
+	if err := db.NewAuditRepository(database).Create(ctx, record); err != nil {
+		t.Fatalf("create audit record: %v", err)
+	}
+	if code, err := runCommand(t, taskCreateCmd, "--agent", agent.ID, "--title", "schema", "-m", "hello"); code != 0 {
+		t.Fatalf("task create failed with exit %d: %v", code, err)
+	}
+	captureStdout(t, func() {
+		if code, err := runCommand(t, scheduleAddCmd, "--agent", agent.ID, "--cron", "@daily", "-m", "hello"); code != 0 {
+			t.Errorf("schedule add failed with exit %d: %v", code, err)
+		}
+	})
+
+	cases := []struct {
+		typeName string
+		cmd      *cobra.Command
+		list     bool
+	}{
+		{"agent", newAgentListCmd(), true},
+		{"workspace", newWsListCmd(), true},
+		{"node", nodeListCmd, true},
+		{"account", newAccountsListCmd(), true},
+		{"account-status", newAccountsStatusCmd(), true},
+		{"event", exportEventsCmd, true},
+		{"audit-record", auditListCmd, true},
+		{"usage-record", exportUsageCmd, true},
+		{"task", taskListCmd, true},
+		{"schedule", scheduleListCmd, true},
+		{"export-status", exportStatusCmd, false},
+		{"cost-forecast", usageForecastCmd, false},
+		{"usage-summary", usageSummaryCmd, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.typeName, func(t *testing.T) {
+			doc, err := outputSchema(tc.typeName)
+			if err != nil {
+				t.Fatalf("outputSchema: %v", err)
+			}
+			out := runJSONCommand(t, tc.cmd)
+
+			if !tc.list {
+				if err := schema.Validate(doc, out); err != nil {
+					t.Fatalf("output does not match schema: %v\n%s", err, out)
+				}
+				return
+			}
+			var items []json.RawMessage
+			if err := json.Unmarshal(out, &items); err != nil {
+				t.Fatalf("expected a JSON array: %v\n%s", err, out)
+			}
+			if len(items) == 0 {
+				t.Fatalf("expected seeded rows in output")
+			}
+			for _, item := range items {
+				if err := schema.Validate(doc, item); err != nil {
+					t.Fatalf("output does not match schema: %v\n%s", err, item)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONEnvelope(t *testing.T) {
+	database := useTestDatabase(t)
+	seedQueueAgent(t, database)
+
+	out := runJSONCommand(t, newWsListCmd(), "--json-envelope")
+	var envelope struct {
+		SchemaVersion string            `json:"schema_version"`
+		Data          []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(out, &envelope); err != nil {
+		t.Fatalf("expected envelope: %v\n%s", err, out)
+	}
+	if envelope.SchemaVersion != schema.Version || len(envelope.Data) != 1 {
+		t.Fatalf("unexpected envelope: %s", out)
+	}
+
+	// Streamed output produces the same envelope text as buffered output.
+	var streamed, buffered bytes.Buffer
+	rows := &countingRows{n: 2, out: &lineCounter{}}
+	if err := writeStream(&streamed, rows, true, false, true); err != nil {
+		t.Fatalf("writeStream: %v", err)
+	}
+	_ = writeJSON(&buffered, wrapEnvelope([]sampleOutput{{Name: "row", Count: 1}, {Name: "row", Count: 2}}, false))
+	if streamed.String() != buffered.String() {
+		t.Fatalf("streamed envelope differs:\n%s\nwant:\n%s", streamed.String(), buffered.String())
+	}
+}
+
+func TestSchemaCommand(t *testing.T) {
+	out := captureStdout(t, func() {
+		if code, err := runCommand(t, schemaCmd, "agent"); code != 0 {
+			t.Errorf("schema agent failed with exit %d: %v", code, err)
+		}
+	})
+	if !strings.Contains(string(out), `"schema_version": "`+schema.Version+`"`) || !strings.Contains(string(out), `"models.Agent"`) {
+		t.Fatalf("unexpected schema output:\n%s", out)
+	}
+
+	if code, _ := runCommand(t, schemaCmd, "bogus"); code != ExitCodeNotFound {
+		t.Fatalf("expected exit %d for unknown type, got %d", ExitCodeNotFound, code)
+	}
+}
@@ -30,6 +30,7 @@ var (
 	sendFile      string
 	sendStdin     bool
 	sendEditor    bool
+	sendLocks     []string
 )
 
 func init() {
@@ -45,6 +46,7 @@ func init() {
 	sendCmd.Flags().StringVarP(&sendFile, "file", "f", "", "read message from file")
 	sendCmd.Flags().BoolVar(&sendStdin, "stdin", false, "read message from stdin")
 	sendCmd.Flags().BoolVar(&sendEditor, "editor", false, "compose message in $EDITOR")
+	sendCmd.Flags().StringSliceVar(&sendLocks, "lock", nil, "path or glob to reserve via Agent Mail while the task runs (repeatable)")
 
 	_ = sendCmd.Flags().MarkDeprecated("immediate", "use 'swarm inject' for immediate tmux injection")
 	_ = sendCmd.Flags().MarkDeprecated("skip-idle-check", "use 'swarm inject --force' to bypass idle checks")
@@ -87,7 +89,7 @@ If no agent is specified, uses the agent from current context.`,
   # Compose in $EDITOR
   swarm send abc123 --editor`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -103,7 +105,7 @@ If no agent is specified, uses the agent from current context.`,
 		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
 		_ = wsService // for future use with --all
 
-		queueService := queue.NewService(queueRepo)
+		queueService := queue.NewService(queueRepo, queue.WithAuditRecorder(newAuditRecorder(database)))
 
 		if sendImmediate {
 			if sendWhenIdle {
@@ -115,6 +117,9 @@ If no agent is specified, uses the agent from current context.`,
 			if sendAfter != "" {
 				return errors.New("--after cannot be used with --immediate")
 			}
+			if len(sendLocks) > 0 {
+				return errors.New("--lock cannot be used with --immediate")
+			}
 			if cmd.Flags().Changed("priority") {
 				return errors.New("--priority cannot be used with --immediate")
 			}
@@ -235,9 +240,10 @@ type sendResult struct {
 }
 
 type queueOptions struct {
-	Front    bool
-	WhenIdle bool
-	AfterID  string
+	Front     bool
+	WhenIdle  bool
+	AfterID   string
+	LockPaths []string
 }
 
 func resolveQueueOptions(cmd *cobra.Command) (queueOptions, error) {
@@ -249,11 +255,15 @@ func resolveQueueOptions(cmd *cobra.Command) (queueOptions, error) {
 	if sendAfter != "" && sendFront {
 		return queueOptions{}, errors.New("--after cannot be used with --front")
 	}
+	if len(sendLocks) > 0 && sendWhenIdle {
+		return queueOptions{}, errors.New("--lock cannot be used with --when-idle")
+	}
 
 	opts := queueOptions{
-		Front:    sendFront,
-		WhenIdle: sendWhenIdle,
-		AfterID:  sendAfter,
+		Front:     sendFront,
+		WhenIdle:  sendWhenIdle,
+		AfterID:   sendAfter,
+		LockPaths: sendLocks,
 	}
 
 	if !opts.Front && opts.AfterID == "" && priority == "high" {
@@ -281,7 +291,7 @@ func normalizePriority(priority string) (string, error) {
 }
 
 func enqueueMessage(ctx context.Context, queueService *queue.Service, queueRepo *db.QueueRepository, agent *models.Agent, message string, opts queueOptions) sendResult {
-	item, err := buildQueueItem(agent.ID, message, opts.WhenIdle)
+	item, err := buildQueueItem(agent.ID, message, opts.WhenIdle, opts.LockPaths)
 	if err != nil {
 		return sendResult{AgentID: agent.ID, Error: err.Error()}
 	}
@@ -326,7 +336,7 @@ func enqueueMessage(ctx context.Context, queueService *queue.Service, queueRepo
 	}
 }
 
-func buildQueueItem(agentID, message string, whenIdle bool) (*models.QueueItem, error) {
+func buildQueueItem(agentID, message string, whenIdle bool, lockPaths []string) (*models.QueueItem, error) {
 	if whenIdle {
 		payload := models.ConditionalPayload{
 			ConditionType: models.ConditionTypeWhenIdle,
@@ -341,7 +351,7 @@ func buildQueueItem(agentID, message string, whenIdle bool) (*models.QueueItem,
 		}, nil
 	}
 
-	payload := models.MessagePayload{Text: message}
+	payload := models.MessagePayload{Text: message, LockPaths: lockPaths}
 	payloadBytes, _ := json.Marshal(payload)
 	return &models.QueueItem{
 		AgentID: agentID,
@@ -352,17 +362,22 @@ func buildQueueItem(agentID, message string, whenIdle bool) (*models.QueueItem,
 }
 
 func writeQueueResults(message string, results []sendResult, opts queueOptions) error {
-	if IsJSONOutput() || IsJSONLOutput() {
-		return WriteOutput(os.Stdout, map[string]any{
+	return rootOptions().writeQueueResults(message, results, opts)
+}
+
+func (o Options) writeQueueResults(message string, results []sendResult, opts queueOptions) error {
+	if o.Structured() {
+		return o.WriteOutput(map[string]any{
 			"queued":  true,
 			"results": results,
 			"message": truncateMessage(message, 100),
 		})
 	}
 
+	out := o.stdout()
 	for _, r := range results {
 		if r.Error != "" {
-			fmt.Printf("✗ Failed to queue for agent %s: %s\n", shortID(r.AgentID), r.Error)
+			fmt.Fprintf(out, "✗ Failed to queue for agent %s: %s\n", shortID(r.AgentID), r.Error)
 			continue
 		}
 
@@ -376,11 +391,11 @@ func writeQueueResults(message string, results []sendResult, opts queueOptions)
 		if r.ItemType == string(models.QueueItemTypeConditional) {
 			typeStr = " (when idle)"
 		}
-		fmt.Printf("✓ Queued for agent %s at position %s%s\n", shortID(r.AgentID), positionStr, typeStr)
+		fmt.Fprintf(out, "✓ Queued for agent %s at position %s%s\n", shortID(r.AgentID), positionStr, typeStr)
 	}
 
 	if len(results) == 1 && results[0].Error == "" {
-		fmt.Printf("  \"%s\"\n", truncateMessage(message, 60))
+		fmt.Fprintf(out, "  \"%s\"\n", truncateMessage(message, 60))
 	}
 
 	return nil
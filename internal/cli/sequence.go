@@ -2,7 +2,6 @@
 package cli
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -51,7 +50,7 @@ var sequenceListCmd = &cobra.Command{
 	Short:   "List sequences",
 	Args:    cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -102,7 +101,7 @@ var sequenceShowCmd = &cobra.Command{
 	Short: "Show sequence details",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -236,7 +235,7 @@ var sequenceRunCmd = &cobra.Command{
 	Short: "Queue a sequence",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -247,7 +246,7 @@ var sequenceRunCmd = &cobra.Command{
 		wsRepo := db.NewWorkspaceRepository(database)
 		agentRepo := db.NewAgentRepository(database)
 		queueRepo := db.NewQueueRepository(database)
-		queueService := queue.NewService(queueRepo)
+		queueService := queue.NewService(queueRepo, queue.WithAuditRecorder(newAuditRecorder(database)))
 
 		projectDir := resolveTemplateProjectDir(ctx, wsRepo)
 		sequencesList, err := sequences.LoadSequencesFromSearchPaths(projectDir)
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+// snapshotView is a snapshot's metadata with where its content is stored.
+// Content is only set by 'snapshot show'.
+type snapshotView struct {
+	snapshot.Snapshot
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+}
+
+func snapshotDir(cfg *config.Config) string {
+	return filepath.Join(cfg.Global.DataDir, "snapshots")
+}
+
+func openSnapshotStore() (*snapshot.Store, error) {
+	cfg := GetConfig()
+	if cfg == nil || cfg.Global.DataDir == "" {
+		return nil, agent.ErrSnapshotsDisabled
+	}
+	return snapshot.NewStore(snapshotDir(cfg)), nil
+}
+
+func newSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Browse agent pane snapshots",
+		Long: `Browse pane snapshots saved with 'swarm agent snapshot'.
+
+Snapshots older than 30 days, and the oldest ones once stored content
+exceeds 256 MiB, are pruned whenever a new snapshot is saved.`,
+	}
+
+	cmd.AddCommand(newSnapshotListCmd(), newSnapshotShowCmd())
+	return cmd
+}
+
+// snapshotListFlags holds the flags of 'swarm snapshot list'.
+type snapshotListFlags struct {
+	agent string
+}
+
+func newSnapshotListCmd() *cobra.Command {
+	var flags snapshotListFlags
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List snapshots",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+
+			store, err := openSnapshotStore()
+			if err != nil {
+				return wrapServiceError(err, "failed to open snapshots")
+			}
+
+			agentID := strings.TrimSpace(flags.agent)
+			if agentID != "" {
+				agentID, err = resolveSnapshotAgent(cmd.Context(), agentID)
+				if err != nil {
+					return err
+				}
+			}
+
+			snaps, err := store.List(agentID)
+			if err != nil {
+				return wrapServiceError(err, "failed to list snapshots")
+			}
+
+			if opts.Structured() {
+				views := make([]snapshotView, 0, len(snaps))
+				for _, snap := range snaps {
+					views = append(views, snapshotView{Snapshot: *snap, Path: store.BlobPath(snap.Hash)})
+				}
+				return opts.WriteOutput(views)
+			}
+
+			if len(snaps) == 0 {
+				fmt.Fprintln(opts.Out, "No snapshots found")
+				return nil
+			}
+			rows := make([][]string, 0, len(snaps))
+			for _, snap := range snaps {
+				note := snap.Note
+				if note == "" {
+					note = "-"
+				}
+				rows = append(rows, []string{
+					shortID(snap.ID),
+					shortID(snap.AgentID),
+					snap.CapturedAt.Local().Format("2006-01-02 15:04:05"),
+					formatAgentState(snap.State),
+					snap.Hash[:12],
+					fmt.Sprintf("%d", snap.Size),
+					note,
+				})
+			}
+			return opts.writeTable([]string{"ID", "AGENT", "CAPTURED", "STATE", "HASH", "BYTES", "NOTE"}, rows)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.agent, "agent", "", "only list snapshots of this agent")
+	return cmd
+}
+
+func newSnapshotShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <snapshot-id>",
+		Short: "Print a snapshot's pane content",
+		Long: `Print the pane content captured by a snapshot. The ID may be a unique
+prefix. With --json, the metadata is printed along with the content.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+
+			store, err := openSnapshotStore()
+			if err != nil {
+				return wrapServiceError(err, "failed to open snapshots")
+			}
+
+			snap, err := store.Get(args[0])
+			if err != nil {
+				return wrapServiceError(err, "failed to find snapshot")
+			}
+			content, err := store.Content(snap)
+			if err != nil {
+				return wrapServiceError(err, "failed to read snapshot %s", shortID(snap.ID))
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(snapshotView{Snapshot: *snap, Path: store.BlobPath(snap.Hash), Content: content})
+			}
+			fmt.Fprint(opts.Out, content)
+			if content != "" && !strings.HasSuffix(content, "\n") {
+				fmt.Fprintln(opts.Out)
+			}
+			return nil
+		},
+	}
+}
+
+// resolveSnapshotAgent expands an agent ID prefix. Snapshots outlive their
+// agents, so an ID with no agent row is used as given.
+func resolveSnapshotAgent(ctx context.Context, idOrPrefix string) (string, error) {
+	database, err := openDatabase()
+	if err != nil {
+		return "", err
+	}
+	defer database.Close()
+
+	resolved, err := findAgent(ctx, db.NewAgentRepository(database), idOrPrefix)
+	switch {
+	case err == nil:
+		return resolved.ID, nil
+	case errors.Is(err, ErrNotFound):
+		return idOrPrefix, nil
+	default:
+		return "", err
+	}
+}
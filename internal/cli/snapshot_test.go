@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/schema"
+	"github.com/opencode-ai/swarm/internal/snapshot"
+)
+
+func TestSnapshotListAndShow(t *testing.T) {
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	previous := appConfig
+	appConfig = cfg
+	t.Cleanup(func() { appConfig = previous })
+
+	store := snapshot.NewStore(snapshotDir(cfg))
+	first := &snapshot.Snapshot{AgentID: agent.ID, Note: "first"}
+	if err := store.Save(first, "claude> one\n"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Save(&snapshot.Snapshot{AgentID: "other-agent"}, "claude> two\n"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	doc, err := outputSchema("snapshot")
+	if err != nil {
+		t.Fatalf("outputSchema: %v", err)
+	}
+	var listed []json.RawMessage
+	out := runJSONCommand(t, newSnapshotListCmd(), "--agent", agent.ID[:8])
+	if err := json.Unmarshal(out, &listed); err != nil || len(listed) != 1 {
+		t.Fatalf("expected one snapshot for the agent, got %s (%v)", out, err)
+	}
+	if err := schema.Validate(doc, listed[0]); err != nil {
+		t.Fatalf("output does not match schema: %v\n%s", err, listed[0])
+	}
+
+	show := newSnapshotShowCmd()
+	var view snapshotView
+	out = runJSONCommand(t, show, first.ID[:8])
+	if err := json.Unmarshal(out, &view); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if view.ID != first.ID || view.Content != "claude> one\n" || view.Path != store.BlobPath(first.Hash) {
+		t.Fatalf("unexpected snapshot view %+v", view)
+	}
+
+	text := captureStdout(t, func() {
+		if code, err := runCommand(t, show, first.ID); code != 0 {
+			t.Errorf("snapshot show failed with exit %d: %v", code, err)
+		}
+	})
+	if !strings.Contains(string(text), "claude> one") {
+		t.Fatalf("expected the pane content, got %q", text)
+	}
+	if code, _ := runCommand(t, show, "missing"); code != ExitCodeNotFound {
+		t.Fatalf("expected exit %d for an unknown snapshot, got %d", ExitCodeNotFound, code)
+	}
+}
@@ -5,10 +5,8 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/signal"
 	"sort"
 	"strings"
-	"syscall"
 	"text/tabwriter"
 	"time"
 
@@ -36,7 +34,7 @@ var statusCmd = &cobra.Command{
 			return err
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		if IsWatchMode() {
 			return streamStatus(ctx)
 		}
@@ -92,19 +90,6 @@ func streamStatus(ctx context.Context) error {
 	}
 	defer database.Close()
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		select {
-		case <-sigChan:
-			cancel()
-		case <-ctx.Done():
-		}
-	}()
-
 	ticker := time.NewTicker(statusWatchInterval)
 	defer ticker.Stop()
 
@@ -254,6 +239,8 @@ func formatAgentStateCounts(counts map[models.AgentState]int) string {
 		models.AgentStatePaused,
 		models.AgentStateStarting,
 		models.AgentStateStopped,
+		models.AgentStateStuck,
+		models.AgentStateHibernated,
 	}
 
 	parts := make([]string, 0, len(order))
@@ -57,6 +57,10 @@ func statusLabelForAgent(state models.AgentState) (string, string) {
 		return "WARN", colorMagenta
 	case models.AgentStateError:
 		return "ERR", colorRed
+	case models.AgentStateStuck:
+		return "STUCK", colorRed
+	case models.AgentStateHibernated:
+		return "SLEEP", colorCyan
 	default:
 		return "WARN", colorYellow
 	}
@@ -0,0 +1,112 @@
+// Package cli provides stuck agent detection wiring.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/state"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+// newStuckDetector builds stuck detection from agent_defaults. Recovery
+// goes through the agent service so restarts are recorded like manual ones.
+func newStuckDetector(database *db.DB, tmuxClient *tmux.Client) *state.StuckDetector {
+	agentDefaults := config.DefaultConfig().AgentDefaults
+	if cfg := GetConfig(); cfg != nil {
+		agentDefaults = cfg.AgentDefaults
+	}
+
+	nodeService := node.NewService(db.NewNodeRepository(database), node.WithPublisher(newEventPublisher(database)))
+	agentRepo := db.NewAgentRepository(database)
+	wsService := workspace.NewService(db.NewWorkspaceRepository(database), nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+	agentService := agent.NewService(agentRepo, db.NewQueueRepository(database), wsService, nil, tmuxClient, agentServiceOptions(database)...)
+
+	recoverer := &stuckRecoverer{tmux: tmuxClient, agents: agentService}
+	return state.NewStuckDetector(stuckConfig(agentDefaults), state.WithRecoverer(recoverer))
+}
+
+// stuckConfig converts validated agent defaults into detector settings.
+func stuckConfig(agentDefaults config.AgentConfig) state.StuckConfig {
+	cfg := state.StuckConfig{
+		Timeout:      agentDefaults.StuckTimeout,
+		TypeTimeouts: make(map[models.AgentType]time.Duration, len(agentDefaults.StuckTimeouts)),
+	}
+	for agentType, timeout := range agentDefaults.StuckTimeouts {
+		cfg.TypeTimeouts[models.AgentType(agentType)] = timeout
+	}
+	for _, step := range agentDefaults.StuckEscalation {
+		action, err := state.ParseRecoveryAction(step.Action)
+		if err != nil {
+			continue
+		}
+		cfg.Escalation = append(cfg.Escalation, state.EscalationStep{Action: action, After: step.After})
+	}
+	return cfg
+}
+
+// stuckRecoverer runs escalation steps against an agent's pane.
+type stuckRecoverer struct {
+	tmux   *tmux.Client
+	agents *agent.Service
+}
+
+func (r *stuckRecoverer) Recover(ctx context.Context, a *models.Agent, action state.RecoveryAction) error {
+	switch action {
+	case state.RecoveryEnter:
+		return r.tmux.SendKeys(ctx, a.TmuxPane, "Enter", false, false)
+	case state.RecoveryInterrupt:
+		return r.agents.InterruptAgent(ctx, a.ID)
+	case state.RecoveryRestart:
+		_, err := r.agents.RestartAgent(ctx, a.ID)
+		return err
+	default:
+		return fmt.Errorf("unknown recovery action %q", action)
+	}
+}
+
+// stuckAgentsFirst moves stuck agents ahead of the rest, keeping order.
+func stuckAgentsFirst(agents []*models.Agent) []*models.Agent {
+	sorted := append([]*models.Agent(nil), agents...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].State == models.AgentStateStuck && sorted[j].State != models.AgentStateStuck
+	})
+	return sorted
+}
+
+// writeStuckSummary prints one line per stuck agent with how to recover it.
+func writeStuckSummary(w io.Writer, agents []*models.Agent) {
+	stuck := make([]*models.Agent, 0)
+	for _, a := range agents {
+		if a.State == models.AgentStateStuck {
+			stuck = append(stuck, a)
+		}
+	}
+	if len(stuck) == 0 {
+		return
+	}
+
+	noun := "agents"
+	if len(stuck) == 1 {
+		noun = "agent"
+	}
+	fmt.Fprintln(w, colorize(fmt.Sprintf("%d %s stuck:", len(stuck), noun), colorRed))
+	for _, a := range stuck {
+		lastOutput := "unknown"
+		if a.LastOutputAt != nil {
+			lastOutput = formatRelativeTime(*a.LastOutputAt)
+		}
+		fmt.Fprintf(w, "  %s  last output %s  %s\n", shortID(a.ID), lastOutput, orDash(a.StateInfo.Reason))
+		fmt.Fprintf(w, "    swarm agent interrupt %s  |  swarm agent restart %s\n", shortID(a.ID), shortID(a.ID))
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+)
+
+func TestStuckAgentsListedFirst(t *testing.T) {
+	lastOutput := time.Now().UTC().Add(-15 * time.Minute)
+	agents := []*models.Agent{
+		{ID: "aaaaaaaa-working", State: models.AgentStateWorking},
+		{ID: "bbbbbbbb-stuck", State: models.AgentStateStuck, LastOutputAt: &lastOutput,
+			StateInfo: models.StateInfo{Reason: "no output for 15m0s while working"}},
+		{ID: "cccccccc-idle", State: models.AgentStateIdle},
+	}
+
+	sorted := stuckAgentsFirst(agents)
+	if sorted[0].ID != "bbbbbbbb-stuck" || sorted[1].ID != "aaaaaaaa-working" || sorted[2].ID != "cccccccc-idle" {
+		t.Fatalf("unexpected order: %s, %s, %s", sorted[0].ID, sorted[1].ID, sorted[2].ID)
+	}
+
+	var out bytes.Buffer
+	writeStuckSummary(&out, sorted)
+	text := out.String()
+	for _, want := range []string{"1 agent stuck", shortID("bbbbbbbb-stuck"), "no output for 15m0s", "swarm agent restart"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected summary to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	out.Reset()
+	writeStuckSummary(&out, agents[:1])
+	if out.Len() != 0 {
+		t.Fatalf("expected no summary without stuck agents, got %q", out.String())
+	}
+}
+
+func TestStuckConfigFromAgentDefaults(t *testing.T) {
+	defaults := config.DefaultConfig().AgentDefaults
+	defaults.StuckTimeouts = map[string]time.Duration{"codex": 20 * time.Minute}
+	defaults.StuckEscalation = []config.StuckEscalationStep{
+		{Action: "enter"},
+		{Action: "restart", After: 5 * time.Minute},
+	}
+
+	cfg := stuckConfig(defaults)
+	if cfg.Timeout != 10*time.Minute {
+		t.Fatalf("expected 10m timeout, got %s", cfg.Timeout)
+	}
+	if cfg.TypeTimeouts[models.AgentTypeCodex] != 20*time.Minute {
+		t.Fatalf("expected codex override, got %v", cfg.TypeTimeouts)
+	}
+	want := []state.EscalationStep{{Action: state.RecoveryEnter}, {Action: state.RecoveryRestart, After: 5 * time.Minute}}
+	if len(cfg.Escalation) != len(want) || cfg.Escalation[0] != want[0] || cfg.Escalation[1] != want[1] {
+		t.Fatalf("unexpected escalation: %+v", cfg.Escalation)
+	}
+}
@@ -4,21 +4,323 @@ package cli
 import (
 	"fmt"
 	"io"
+	"os"
+	"regexp"
 	"strings"
 	"text/tabwriter"
+	"unicode/utf8"
+
+	"golang.org/x/term"
 )
 
 const tablePadding = 2
 
+// Column priorities decide which columns a table drops first when the
+// terminal is too narrow for all of them.
+const (
+	columnPriorityLow = iota + 1
+	columnPriorityNormal
+	columnPriorityHigh
+	columnPriorityKey
+)
+
+// tableColumn is how a table column may be narrowed to fit the terminal.
+type tableColumn struct {
+	// Priority orders the columns to drop; the lowest goes first.
+	Priority int
+	// Flexible columns are shortened with a middle ellipsis, down to
+	// MinWidth, before any column is dropped.
+	Flexible bool
+	// MinWidth is the narrowest a flexible column is shortened to. It
+	// defaults to the width of the header.
+	MinWidth int
+	// MaxWidth, when set, caps the column on any terminal.
+	MaxWidth int
+}
+
+// tableColumns registers the columns of the list commands by header.
+// Headers not listed here are kept at normal priority and never shortened.
+var tableColumns = map[string]tableColumn{
+	"ID":           {Priority: columnPriorityKey},
+	"NAME":         {Priority: columnPriorityKey, Flexible: true, MinWidth: 12},
+	"AGENT":        {Priority: columnPriorityKey},
+	"LOCK-ID":      {Priority: columnPriorityKey},
+	"POS":          {Priority: columnPriorityKey},
+	"PROVIDER":     {Priority: columnPriorityKey},
+	"PROFILE":      {Priority: columnPriorityKey, Flexible: true, MinWidth: 12},
+	"FILE":         {Priority: columnPriorityKey, Flexible: true, MinWidth: 16},
+	"WORKSPACE":    {Priority: columnPriorityHigh, Flexible: true, MinWidth: 12},
+	"STATUS":       {Priority: columnPriorityHigh},
+	"STATE":        {Priority: columnPriorityHigh},
+	"HEALTH":       {Priority: columnPriorityHigh},
+	"RESULT":       {Priority: columnPriorityHigh},
+	"TYPE":         {Priority: columnPriorityHigh},
+	"TITLE":        {Priority: columnPriorityHigh, Flexible: true, MinWidth: 16},
+	"SUBJECT":      {Priority: columnPriorityHigh, Flexible: true, MinWidth: 16},
+	"PATH":         {Priority: columnPriorityNormal, Flexible: true, MinWidth: 16, MaxWidth: 40},
+	"CONTENT":      {Priority: columnPriorityNormal, Flexible: true, MinWidth: 16},
+	"MESSAGE":      {Priority: columnPriorityNormal, Flexible: true, MinWidth: 16},
+	"PROMPT":       {Priority: columnPriorityNormal, Flexible: true, MinWidth: 16},
+	"NOTE":         {Priority: columnPriorityNormal, Flexible: true, MinWidth: 16},
+	"ERROR":        {Priority: columnPriorityNormal, Flexible: true, MinWidth: 16},
+	"REASON":       {Priority: columnPriorityNormal, Flexible: true, MinWidth: 12},
+	"COOLDOWN":     {Priority: columnPriorityNormal},
+	"NODE":         {Priority: columnPriorityLow, Flexible: true, MinWidth: 8},
+	"PANE":         {Priority: columnPriorityLow},
+	"SESSION":      {Priority: columnPriorityLow, Flexible: true, MinWidth: 10},
+	"URL":          {Priority: columnPriorityLow, Flexible: true, MinWidth: 16},
+	"DETAIL":       {Priority: columnPriorityLow, Flexible: true, MinWidth: 16},
+	"DETAILS":      {Priority: columnPriorityLow, Flexible: true, MinWidth: 16},
+	"DESCRIPTION":  {Priority: columnPriorityLow, Flexible: true, MinWidth: 16},
+	"BLOCK REASON": {Priority: columnPriorityLow, Flexible: true, MinWidth: 12},
+	"LABELS":       {Priority: columnPriorityLow, Flexible: true, MinWidth: 10},
+	"TAGS":         {Priority: columnPriorityLow, Flexible: true, MinWidth: 10},
+	"CREATED":      {Priority: columnPriorityLow},
+	"LAST USED":    {Priority: columnPriorityLow},
+}
+
+// tableLayout is the room a table is rendered into.
+type tableLayout struct {
+	// Width is the terminal width; zero leaves every column at full width.
+	Width int
+	// Columns, when set, names the only columns to show, by header. They
+	// are shortened to fit but never dropped.
+	Columns []string
+}
+
+// tableLayout returns the layout for a table written to out: the --width
+// and --columns flags, else the width of the terminal out writes to.
+// Output that is not a terminal is not narrowed, so pipes and scripts get
+// whole rows.
+func (o Options) tableLayout(out io.Writer) tableLayout {
+	layout := tableLayout{Width: o.Width, Columns: o.Columns}
+	if layout.Width <= 0 {
+		layout.Width = terminalWidth(out)
+	}
+	return layout
+}
+
+func terminalWidth(out io.Writer) int {
+	file, ok := out.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(file.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// writeTable writes a table to out, fitted to the root command's layout.
 func writeTable(out io.Writer, headers []string, rows [][]string) error {
+	return renderTable(out, rootOptions().tableLayout(out), headers, rows)
+}
+
+// writeTable writes a table to Out, fitted to the invocation's layout.
+func (o Options) writeTable(headers []string, rows [][]string) error {
+	out := o.stdout()
+	return renderTable(out, o.tableLayout(out), headers, rows)
+}
+
+// renderTable writes rows under headers with the columns fitted to layout:
+// flexible columns are shortened first, then the lowest-priority columns
+// are dropped, with a note saying how many, until the table fits.
+func renderTable(out io.Writer, layout tableLayout, headers []string, rows [][]string) error {
+	fit, err := fitTable(layout, headers, rows)
+	if err != nil {
+		return err
+	}
+
 	writer := tabwriter.NewWriter(out, 0, 0, tablePadding, ' ', tabwriter.StripEscape)
 	if len(headers) > 0 {
-		fmt.Fprintln(writer, strings.Join(headers, "\t"))
+		fmt.Fprintln(writer, strings.Join(fit.cells(headers), "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(fit.cells(row), "\t"))
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	switch fit.hidden {
+	case 0:
+	case 1:
+		fmt.Fprintln(out, "…1 column hidden, use --columns or widen terminal")
+	default:
+		fmt.Fprintf(out, "…%d columns hidden, use --columns or widen terminal\n", fit.hidden)
+	}
+	return nil
+}
+
+// tableFit is the columns a table keeps and the width of each.
+type tableFit struct {
+	keep   []int
+	widths []int
+	hidden int
+}
+
+// cells returns the kept cells of row, shortened to their column widths.
+func (f tableFit) cells(row []string) []string {
+	cells := make([]string, len(f.keep))
+	for i, col := range f.keep {
+		if col < len(row) {
+			cells[i] = truncateMiddle(row[col], f.widths[i])
+		}
 	}
+	return cells
+}
+
+func fitTable(layout tableLayout, headers []string, rows [][]string) (tableFit, error) {
+	count := len(headers)
 	for _, row := range rows {
-		fmt.Fprintln(writer, strings.Join(row, "\t"))
+		count = max(count, len(row))
+	}
+
+	at := func(row []string, col int) string {
+		if col < len(row) {
+			return row[col]
+		}
+		return ""
+	}
+	specs := make([]tableColumn, count)
+	natural := make([]int, count)
+	for col := range count {
+		header := at(headers, col)
+		spec, ok := tableColumns[strings.TrimSpace(header)]
+		if !ok {
+			spec = tableColumn{Priority: columnPriorityNormal}
+		}
+		spec.MinWidth = max(spec.MinWidth, displayWidth(header))
+		specs[col] = spec
+
+		width := displayWidth(header)
+		for _, row := range rows {
+			width = max(width, displayWidth(at(row, col)))
+		}
+		if spec.MaxWidth > 0 {
+			width = min(width, max(spec.MaxWidth, spec.MinWidth))
+		}
+		natural[col] = width
+	}
+
+	// --columns picks by header, so a table without headers shows all.
+	explicit := len(layout.Columns) > 0 && len(headers) > 0
+	keep := make([]int, 0, count)
+	if explicit {
+		for col := range count {
+			if wantsColumn(layout.Columns, at(headers, col)) {
+				keep = append(keep, col)
+			}
+		}
+		if len(keep) == 0 {
+			names := make([]string, 0, len(headers))
+			for _, header := range headers {
+				names = append(names, strings.ToLower(strings.TrimSpace(header)))
+			}
+			return tableFit{}, invalidInputError("none of --columns %s are in this table (columns: %s)",
+				strings.Join(layout.Columns, ","), strings.Join(names, ", "))
+		}
+	} else {
+		for col := range count {
+			keep = append(keep, col)
+		}
+	}
+
+	minWidth := func(col int) int {
+		if specs[col].Flexible {
+			return min(natural[col], specs[col].MinWidth)
+		}
+		return natural[col]
+	}
+	total := func(width func(int) int) int {
+		sum := tablePadding * (len(keep) - 1)
+		for _, col := range keep {
+			sum += width(col)
+		}
+		return sum
+	}
+
+	hidden := 0
+	if layout.Width > 0 && !explicit {
+		// Drop the lowest-priority column, the rightmost of equals, until
+		// the rest fit at their narrowest.
+		for len(keep) > 1 && total(minWidth) > layout.Width {
+			drop := len(keep) - 1
+			for i := len(keep) - 2; i >= 0; i-- {
+				if specs[keep[i]].Priority < specs[keep[drop]].Priority {
+					drop = i
+				}
+			}
+			keep = append(keep[:drop], keep[drop+1:]...)
+			hidden++
+		}
+	}
+
+	widths := make([]int, len(keep))
+	for i, col := range keep {
+		widths[i] = natural[col]
+	}
+	if layout.Width > 0 {
+		// Take the excess from the widest flexible column, one cell at a
+		// time, so the flexible columns end up as even as their content
+		// allows.
+		excess := total(func(col int) int { return natural[col] }) - layout.Width
+		for excess > 0 {
+			widest := -1
+			for i, col := range keep {
+				if widths[i] > minWidth(col) && (widest < 0 || widths[i] > widths[widest]) {
+					widest = i
+				}
+			}
+			if widest < 0 {
+				break
+			}
+			widths[widest]--
+			excess--
+		}
+	}
+
+	return tableFit{keep: keep, widths: widths, hidden: hidden}, nil
+}
+
+// wantsColumn reports whether header is one of the --columns names, which
+// match headers case-insensitively with '-' or '_' for spaces.
+func wantsColumn(columns []string, header string) bool {
+	header = strings.ToLower(strings.TrimSpace(header))
+	for _, name := range columns {
+		name = strings.NewReplacer("-", " ", "_", " ").Replace(strings.ToLower(strings.TrimSpace(name)))
+		if name == header || name == strings.ReplaceAll(header, "-", " ") {
+			return true
+		}
+	}
+	return false
+}
+
+var ansiSequence = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// displayWidth is the width s takes in a terminal, not counting colors.
+func displayWidth(s string) int {
+	if strings.IndexByte(s, '\x1b') >= 0 {
+		s = ansiSequence.ReplaceAllString(s, "")
+	}
+	return utf8.RuneCountInString(s)
+}
+
+// truncateMiddle shortens s to width by replacing its middle with an
+// ellipsis, keeping the longer half at the end, where paths and names
+// tend to differ.
+func truncateMiddle(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	runes := []rune(ansiSequence.ReplaceAllString(s, ""))
+	if width <= 1 {
+		return string([]rune("…")[:width])
 	}
-	return writer.Flush()
+	head := (width - 1) / 2
+	tail := width - 1 - head
+	return string(runes[:head]) + "…" + string(runes[len(runes)-tail:])
 }
 
 func formatYesNo(value bool) string {
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderTableFitsWidth(t *testing.T) {
+	headers := []string{"NAME", "ID", "NODE", "PATH", "STATUS"}
+	rows := [][]string{
+		{"alpha", "ab12cd34", "local", "/home/dev/src/github.com/acme/service-api", "active"},
+		{"beta-workspace", "ef56gh78", "build-01", "/srv/repo", "idle"},
+	}
+
+	tests := []struct {
+		name   string
+		layout tableLayout
+		want   []string
+	}{
+		{
+			name: "unlimited caps the path",
+			want: []string{
+				"NAME            ID        NODE      PATH                                      STATUS",
+				"alpha           ab12cd34  local     /home/dev/src/githu…com/acme/service-api  active",
+				"beta-workspace  ef56gh78  build-01  /srv/repo                                 idle",
+			},
+		},
+		{
+			name:   "exact fit",
+			layout: tableLayout{Width: 84},
+			want: []string{
+				"NAME            ID        NODE      PATH                                      STATUS",
+				"alpha           ab12cd34  local     /home/dev/src/githu…com/acme/service-api  active",
+				"beta-workspace  ef56gh78  build-01  /srv/repo                                 idle",
+			},
+		},
+		{
+			name:   "one short of a fit",
+			layout: tableLayout{Width: 83},
+			want: []string{
+				"NAME            ID        NODE      PATH                                     STATUS",
+				"alpha           ab12cd34  local     /home/dev/src/githu…om/acme/service-api  active",
+				"beta-workspace  ef56gh78  build-01  /srv/repo                                idle",
+			},
+		},
+		{
+			name:   "path shortened to its minimum",
+			layout: tableLayout{Width: 60},
+			want: []string{
+				"NAME            ID        NODE      PATH              STATUS",
+				"alpha           ab12cd34  local     /home/d…vice-api  active",
+				"beta-workspace  ef56gh78  build-01  /srv/repo         idle",
+			},
+		},
+		{
+			name:   "lowest priority dropped at an exact fit",
+			layout: tableLayout{Width: 50},
+			want: []string{
+				"NAME            ID        PATH              STATUS",
+				"alpha           ab12cd34  /home/d…vice-api  active",
+				"beta-workspace  ef56gh78  /srv/repo         idle",
+				"…1 column hidden, use --columns or widen terminal",
+			},
+		},
+		{
+			name:   "name shortened once the path is at its minimum",
+			layout: tableLayout{Width: 49},
+			want: []string{
+				"NAME           ID        PATH              STATUS",
+				"alpha          ab12cd34  /home/d…vice-api  active",
+				"beta-w…kspace  ef56gh78  /srv/repo         idle",
+				"…1 column hidden, use --columns or widen terminal",
+			},
+		},
+		{
+			name:   "narrow keeps the key column",
+			layout: tableLayout{Width: 20},
+			want: []string{
+				"NAME",
+				"alpha",
+				"beta-workspace",
+				"…4 columns hidden, use --columns or widen terminal",
+			},
+		},
+		{
+			name:   "very narrow shortens the last column to its minimum",
+			layout: tableLayout{Width: 5},
+			want: []string{
+				"NAME",
+				"alpha",
+				"beta-…kspace",
+				"…4 columns hidden, use --columns or widen terminal",
+			},
+		},
+		{
+			name:   "columns pick and order by the table",
+			layout: tableLayout{Columns: []string{"status", "Name"}},
+			want: []string{
+				"NAME            STATUS",
+				"alpha           active",
+				"beta-workspace  idle",
+			},
+		},
+		{
+			name:   "columns are shortened but not dropped",
+			layout: tableLayout{Width: 30, Columns: []string{"name", "path", "status"}},
+			want: []string{
+				"NAME          PATH              STATUS",
+				"alpha         /home/d…vice-api  active",
+				"beta-…kspace  /srv/repo         idle",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := renderTable(&out, tt.layout, headers, rows); err != nil {
+				t.Fatalf("renderTable: %v", err)
+			}
+			got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			for i := range got {
+				got[i] = strings.TrimRight(got[i], " ")
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("unexpected table:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if tt.layout.Width > 0 && len(tt.layout.Columns) == 0 {
+				for _, line := range got {
+					if strings.HasPrefix(line, "…") {
+						continue
+					}
+					if width := displayWidth(line); width > max(tt.layout.Width, 12) {
+						t.Errorf("line %q is %d wide", line, width)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTableColumnSets(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  tableLayout
+		headers []string
+		rows    [][]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "unregistered columns are never shortened",
+			layout:  tableLayout{Width: 10},
+			headers: []string{"AGENTS", "BYTES"},
+			rows:    [][]string{{"12", "1048576"}},
+			want:    "AGENTS\n12\n…1 column hidden, use --columns or widen terminal\n",
+		},
+		{
+			name:   "headerless tables ignore columns",
+			layout: tableLayout{Columns: []string{"name"}},
+			rows:   [][]string{{"Status:", "running"}},
+			want:   "Status:  running\n",
+		},
+		{
+			name:    "headers with spaces match dashes",
+			layout:  tableLayout{Columns: []string{"last-used"}},
+			headers: []string{"PROFILE", "LAST USED"},
+			rows:    [][]string{{"primary", "2m ago"}},
+			want:    "LAST USED\n2m ago\n",
+		},
+		{
+			name:    "colors do not count toward the width",
+			layout:  tableLayout{Width: 20},
+			headers: []string{"ID", "STATUS"},
+			rows:    [][]string{{"ab12cd34", colorGreen + "active" + colorReset}},
+			want:    "ID        STATUS\nab12cd34  " + colorGreen + "active" + colorReset + "\n",
+		},
+		{
+			name:    "unknown columns",
+			layout:  tableLayout{Columns: []string{"owner"}},
+			headers: []string{"ID", "STATUS"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := renderTable(&out, tt.layout, tt.headers, tt.rows)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "id, status") {
+					t.Fatalf("expected an error naming the columns, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renderTable: %v", err)
+			}
+			if out.String() != tt.want {
+				t.Fatalf("unexpected table:\n%q\nwant:\n%q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{in: "service-api", width: 11, want: "service-api"},
+		{in: "service-api", width: 10, want: "serv…e-api"},
+		{in: "service-api", width: 3, want: "s…i"},
+		{in: "service-api", width: 2, want: "…i"},
+		{in: "service-api", width: 1, want: "…"},
+		{in: "service-api", width: 0, want: ""},
+		{in: "añoñoño-ñ", width: 5, want: "añ…-ñ"},
+	}
+	for _, tt := range tests {
+		if got := truncateMiddle(tt.in, tt.width); got != tt.want {
+			t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
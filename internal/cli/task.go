@@ -0,0 +1,345 @@
+// Package cli provides task tracking commands.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/task"
+	"github.com/spf13/cobra"
+)
+
+var (
+	taskAgent    string
+	taskTitle    string
+	taskMessages []string
+
+	taskListAgent  string
+	taskListStatus string
+	taskListLimit  int
+)
+
+func init() {
+	rootCmd.AddCommand(taskCmd)
+	taskCmd.AddCommand(taskCreateCmd)
+	taskCmd.AddCommand(taskListCmd)
+	taskCmd.AddCommand(taskShowCmd)
+
+	taskCreateCmd.Flags().StringVarP(&taskAgent, "agent", "a", "", "agent to queue the task for (defaults to the current context)")
+	taskCreateCmd.Flags().StringVarP(&taskTitle, "title", "t", "", "task title")
+	taskCreateCmd.Flags().StringArrayVarP(&taskMessages, "message", "m", nil, "message to queue (repeatable, sent in order)")
+	_ = taskCreateCmd.MarkFlagRequired("title")
+	_ = taskCreateCmd.MarkFlagRequired("message")
+
+	taskListCmd.Flags().StringVarP(&taskListAgent, "agent", "a", "", "filter by agent ID or prefix")
+	taskListCmd.Flags().StringVar(&taskListStatus, "status", "", "filter by status (pending, running, completed, failed)")
+	taskListCmd.Flags().IntVarP(&taskListLimit, "limit", "n", 20, "max tasks to show (0 = unlimited)")
+}
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Track multi-message tasks",
+	Long: `Group queued messages into a task and follow it as a unit.
+
+A task starts running when its first message is dispatched, completes when
+its last message has been dispatched and the agent returns to idle, and
+fails if any of its messages fails after all retries. The remaining
+messages of a failed task are skipped.`,
+}
+
+var taskCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Queue messages as a task",
+	Example: `  swarm task create --agent abc123 --title "Add retries" \
+    --message "Add retry support to the HTTP client" \
+    --message "Write tests for the retry logic" \
+    --message "Update the README"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		title := strings.TrimSpace(taskTitle)
+		if title == "" {
+			return invalidInputError("--title is required")
+		}
+		items := make([]*models.QueueItem, 0, len(taskMessages))
+		for _, message := range taskMessages {
+			if strings.TrimSpace(message) == "" {
+				return invalidInputError("--message cannot be empty")
+			}
+			item, err := buildQueueItem("", message, false, nil)
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		agentRepo := db.NewAgentRepository(database)
+		resolved, err := RequireAgentContext(ctx, agentRepo, taskAgent, "")
+		if err != nil {
+			return err
+		}
+		agentInfo, err := findAgent(ctx, agentRepo, resolved.AgentID)
+		if err != nil {
+			return err
+		}
+
+		taskService := newTaskService(database)
+		t := &models.Task{
+			AgentID:     agentInfo.ID,
+			WorkspaceID: agentInfo.WorkspaceID,
+			Title:       title,
+		}
+		if err := taskService.Create(ctx, t, items...); err != nil {
+			return wrapServiceError(err, "failed to create task")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, taskView{Task: t, Items: items})
+		}
+		fmt.Printf("✓ Created task %s for agent %s (%d messages queued)\n", shortID(t.ID), shortID(agentInfo.ID), len(items))
+		fmt.Printf("  %s\n", t.Title)
+		return nil
+	},
+}
+
+var taskListCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List tasks",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		status, err := normalizeTaskStatus(taskListStatus)
+		if err != nil {
+			return err
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		filter := db.TaskFilter{Status: status, Limit: taskListLimit}
+		if strings.TrimSpace(taskListAgent) != "" {
+			agentInfo, err := findAgent(ctx, db.NewAgentRepository(database), taskListAgent)
+			if err != nil {
+				return err
+			}
+			filter.AgentID = agentInfo.ID
+		}
+
+		taskService := newTaskService(database)
+		tasks, err := taskService.List(ctx, filter)
+		if err != nil {
+			return wrapServiceError(err, "failed to list tasks")
+		}
+
+		views := make([]taskView, 0, len(tasks))
+		for _, t := range tasks {
+			items, err := taskService.Items(ctx, t.ID)
+			if err != nil {
+				return wrapServiceError(err, "failed to list items for task %s", t.ID)
+			}
+			views = append(views, newTaskView(t, items))
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, views)
+		}
+		if len(views) == 0 {
+			fmt.Println("No tasks found")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(views))
+		for _, view := range views {
+			rows = append(rows, []string{
+				shortID(view.Task.ID),
+				truncate(view.Task.Title, 40),
+				shortID(view.Task.AgentID),
+				string(view.Task.Status),
+				fmt.Sprintf("%d/%d", view.Sent, len(view.Items)),
+				formatTaskDuration(view.Task),
+				formatRelativeTime(view.Task.CreatedAt),
+			})
+		}
+		return writeTable(os.Stdout, []string{"ID", "TITLE", "AGENT", "STATUS", "SENT", "DURATION", "CREATED"}, rows)
+	},
+}
+
+var taskShowCmd = &cobra.Command{
+	Use:   "show <task-id>",
+	Short: "Show a task and its messages",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		taskService := newTaskService(database)
+		t, err := findTask(ctx, taskService, args[0])
+		if err != nil {
+			return err
+		}
+		items, err := taskService.Items(ctx, t.ID)
+		if err != nil {
+			return wrapServiceError(err, "failed to list items for task %s", t.ID)
+		}
+		view := newTaskView(t, items)
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, view)
+		}
+
+		fmt.Printf("Task:      %s\n", t.ID)
+		fmt.Printf("Title:     %s\n", t.Title)
+		fmt.Printf("Agent:     %s\n", t.AgentID)
+		fmt.Printf("Status:    %s\n", t.Status)
+		fmt.Printf("Created:   %s\n", t.CreatedAt.Format(time.RFC3339))
+		if t.StartedAt != nil {
+			fmt.Printf("Started:   %s\n", t.StartedAt.Format(time.RFC3339))
+		}
+		if t.CompletedAt != nil {
+			fmt.Printf("Finished:  %s\n", t.CompletedAt.Format(time.RFC3339))
+		}
+		fmt.Printf("Duration:  %s\n", formatTaskDuration(t))
+		if t.Error != "" {
+			fmt.Printf("Error:     %s\n", t.Error)
+		}
+		fmt.Println()
+
+		rows := make([][]string, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, []string{
+				fmt.Sprintf("%d", item.Position),
+				string(item.Status),
+				fmt.Sprintf("%d", item.Attempts),
+				formatItemOffset(t, item.DispatchedAt),
+				truncate(queueItemPreview(item), 50),
+				orDash(item.Error),
+			})
+		}
+		return writeTable(os.Stdout, []string{"POS", "STATUS", "ATTEMPTS", "SENT AT", "CONTENT", "ERROR"}, rows)
+	},
+}
+
+// taskView is the JSON shape for tasks in list and show output.
+type taskView struct {
+	Task            *models.Task        `json:"task"`
+	Items           []*models.QueueItem `json:"items"`
+	Sent            int                 `json:"sent"`
+	DurationSeconds float64             `json:"duration_seconds"`
+}
+
+func newTaskView(t *models.Task, items []*models.QueueItem) taskView {
+	sent := 0
+	for _, item := range items {
+		if item.DispatchedAt != nil {
+			sent++
+		}
+	}
+	return taskView{
+		Task:            t,
+		Items:           items,
+		Sent:            sent,
+		DurationSeconds: t.Duration(time.Now().UTC()).Seconds(),
+	}
+}
+
+func newTaskService(database *db.DB) *task.Service {
+	return task.NewService(db.NewTaskRepository(database), db.NewQueueRepository(database))
+}
+
+func findTask(ctx context.Context, service *task.Service, idOrPrefix string) (*models.Task, error) {
+	idOrPrefix = strings.TrimSpace(idOrPrefix)
+	if idOrPrefix == "" {
+		return nil, invalidInputError("task ID required")
+	}
+
+	t, err := service.Get(ctx, idOrPrefix)
+	if err == nil {
+		return t, nil
+	}
+	if !errors.Is(err, task.ErrTaskNotFound) {
+		return nil, wrapServiceError(err, "failed to get task")
+	}
+
+	tasks, err := service.List(ctx, db.TaskFilter{})
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list tasks")
+	}
+	matches := make([]*models.Task, 0)
+	for _, candidate := range tasks {
+		if strings.HasPrefix(candidate.ID, idOrPrefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, notFoundError("task '%s' not found", idOrPrefix)
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, match := range matches {
+			ids = append(ids, shortID(match.ID))
+		}
+		return nil, invalidInputError("task '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrPrefix, strings.Join(ids, ", "))
+	}
+}
+
+func normalizeTaskStatus(status string) (models.TaskStatus, error) {
+	status = strings.TrimSpace(strings.ToLower(status))
+	switch models.TaskStatus(status) {
+	case "":
+		return "", nil
+	case models.TaskStatusPending, models.TaskStatusRunning, models.TaskStatusCompleted, models.TaskStatusFailed:
+		return models.TaskStatus(status), nil
+	default:
+		return "", invalidInputError("invalid status (use pending, running, completed, or failed)")
+	}
+}
+
+func formatTaskDuration(t *models.Task) string {
+	if t.StartedAt == nil {
+		return "-"
+	}
+	return formatDuration(t.Duration(time.Now().UTC()))
+}
+
+// formatItemOffset formats when an item was sent relative to the task start.
+func formatItemOffset(t *models.Task, at *time.Time) string {
+	if at == nil {
+		return "-"
+	}
+	if t.StartedAt == nil || at.Before(*t.StartedAt) {
+		return at.Format(time.RFC3339)
+	}
+	return "+" + formatDuration(at.Sub(*t.StartedAt))
+}
+
+func orDash(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+	return value
+}
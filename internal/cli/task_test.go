@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestTaskCommands(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+
+	code, err := runCommand(t, taskCreateCmd, "--agent", agent.ID, "--title", "refactor", "-m", "first, with a comma", "-m", "second")
+	if code != 0 {
+		t.Fatalf("task create failed with exit %d: %v", code, err)
+	}
+
+	tasks, err := db.NewTaskRepository(database).List(ctx, db.TaskFilter{AgentID: agent.ID})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "refactor" || tasks[0].Status != models.TaskStatusPending {
+		t.Fatalf("unexpected tasks: %+v", tasks)
+	}
+	items, err := db.NewQueueRepository(database).ListByTask(ctx, tasks[0].ID)
+	if err != nil {
+		t.Fatalf("failed to list task items: %v", err)
+	}
+	if len(items) != 2 || queueItemPreview(items[0]) != "first, with a comma" {
+		t.Fatalf("unexpected task items: %+v", items)
+	}
+
+	if code, err := runCommand(t, taskShowCmd, tasks[0].ID[:8]); code != 0 {
+		t.Fatalf("task show by prefix failed with exit %d: %v", code, err)
+	}
+	if code, err := runCommand(t, taskListCmd, "--agent", agent.ID, "--status", "pending"); code != 0 {
+		t.Fatalf("task ls failed with exit %d: %v", code, err)
+	}
+
+	if code, _ := runCommand(t, taskShowCmd, "missing"); code != ExitCodeNotFound {
+		t.Fatalf("expected exit %d for unknown task, got %d", ExitCodeNotFound, code)
+	}
+	if code, _ := runCommand(t, taskListCmd, "--status", "bogus"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d for bad status, got %d", ExitCodeInvalidInput, code)
+	}
+	if code, _ := runCommand(t, taskCreateCmd, "--agent", agent.ID, "--title", "empty"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d without messages, got %d", ExitCodeInvalidInput, code)
+	}
+}
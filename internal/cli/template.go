@@ -53,7 +53,7 @@ var templateListCmd = &cobra.Command{
 	Short:   "List templates",
 	Args:    cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -104,7 +104,7 @@ var templateShowCmd = &cobra.Command{
 	Short: "Show template details",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -236,7 +236,7 @@ var templateRunCmd = &cobra.Command{
 	Short: "Queue a template message",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		database, err := openDatabase()
 		if err != nil {
@@ -247,7 +247,7 @@ var templateRunCmd = &cobra.Command{
 		wsRepo := db.NewWorkspaceRepository(database)
 		agentRepo := db.NewAgentRepository(database)
 		queueRepo := db.NewQueueRepository(database)
-		queueService := queue.NewService(queueRepo)
+		queueService := queue.NewService(queueRepo, queue.WithAuditRecorder(newAuditRecorder(database)))
 
 		projectDir := resolveTemplateProjectDir(ctx, wsRepo)
 		templatesList, err := templates.LoadTemplatesFromSearchPaths(projectDir)
@@ -0,0 +1,174 @@
+// Package cli provides the agent cost dashboard.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	topWorkspace string
+	topInterval  time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+
+	topCmd.Flags().StringVarP(&topWorkspace, "workspace", "w", "", "only show agents in this workspace")
+	topCmd.Flags().DurationVar(&topInterval, "interval", 0, "refresh every interval until interrupted (e.g., 5s)")
+}
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show what each agent has cost today",
+	Long: `Show each agent's cost so far today, most expensive first.
+
+ACTUAL is usage recorded for the agent. ESTIMATED is working time with no
+usage recorded for it yet, at budget.cost_per_hour_cents for the agent's
+type; it is never included in ACTUAL. Totals reset at midnight in
+budget.cost_timezone. Usage imported from billing exports is not attributed
+to agents and is not shown here.`,
+	Example: `  swarm top
+  swarm top --interval 5s
+  swarm top --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if topInterval < 0 {
+			return invalidInputError("--interval must not be negative")
+		}
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		var workspaceID string
+		if topWorkspace != "" {
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), topWorkspace)
+			if err != nil {
+				return err
+			}
+			workspaceID = ws.ID
+		}
+
+		for {
+			rows, err := buildTopRows(ctx, database, workspaceID)
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() || IsJSONLOutput() {
+				if err := WriteOutput(os.Stdout, rows); err != nil {
+					return err
+				}
+			} else {
+				if topInterval > 0 {
+					fmt.Print("\033[2J\033[H") // Clear screen
+				}
+				if err := writeTopHuman(os.Stdout, rows); err != nil {
+					return err
+				}
+			}
+			if topInterval == 0 {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(topInterval):
+			}
+		}
+	},
+}
+
+// topRow is one agent's line in 'swarm top'.
+type topRow struct {
+	models.AgentCost
+	State       models.AgentState `json:"state,omitempty"`
+	WorkspaceID string            `json:"workspace_id,omitempty"`
+
+	// Terminated is set for agents terminated since the day began.
+	Terminated bool `json:"terminated,omitempty"`
+}
+
+// buildTopRows loads today's costs for the agents that exist and those
+// terminated today, limited to one workspace when workspaceID is set.
+func buildTopRows(ctx context.Context, database *db.DB, workspaceID string) ([]topRow, error) {
+	ticker, err := loadCostTicker(ctx, database, "")
+	if err != nil {
+		return nil, err
+	}
+	agents, err := db.NewAgentRepository(database).List(ctx)
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list agents")
+	}
+	byID := make(map[string]*models.Agent, len(agents))
+	for _, a := range agents {
+		byID[a.ID] = a
+	}
+
+	rows := make([]topRow, 0, len(agents))
+	for _, cost := range ticker.Costs() {
+		row := topRow{AgentCost: cost, Terminated: true}
+		if a, ok := byID[cost.AgentID]; ok {
+			row = topRow{AgentCost: cost, State: a.State, WorkspaceID: a.WorkspaceID}
+		}
+		if workspaceID != "" && row.WorkspaceID != workspaceID {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].ActualCents != rows[j].ActualCents {
+			return rows[i].ActualCents > rows[j].ActualCents
+		}
+		return rows[i].Estimated.Cents > rows[j].Estimated.Cents
+	})
+	return rows, nil
+}
+
+func writeTopHuman(out io.Writer, rows []topRow) error {
+	if len(rows) == 0 {
+		fmt.Fprintln(out, "No agent costs today")
+		return nil
+	}
+
+	var actual, estimated int64
+	tableRows := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		actual += row.ActualCents
+		estimated += row.Estimated.Cents
+		working := "-"
+		if row.Estimated.WorkingSeconds > 0 {
+			working = formatWorkingSeconds(row.Estimated.WorkingSeconds)
+		}
+		state := "terminated"
+		if !row.Terminated {
+			state = formatAgentState(row.State)
+		}
+		tableRows = append(tableRows, []string{
+			shortID(row.AgentID),
+			orDash(string(row.AgentType)),
+			state,
+			working,
+			formatCents(row.ActualCents),
+			formatEstimatedCents(row.Estimated),
+		})
+	}
+	fmt.Fprintf(out, "Agent costs for %s\n\n", rows[0].Date)
+	if err := writeTable(out, []string{"AGENT", "TYPE", "STATE", "UNRECORDED", "ACTUAL", "ESTIMATED"}, tableRows); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\nTotal: %s recorded, ~%s estimated (not included in recorded)\n", formatCents(actual), formatCents(estimated))
+	return nil
+}
@@ -2,16 +2,20 @@
 package cli
 
 import (
+	"context"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/approval"
 	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/review"
 	"github.com/opencode-ai/swarm/internal/state"
 	"github.com/opencode-ai/swarm/internal/tmux"
 	"github.com/opencode-ai/swarm/internal/tui"
+	"github.com/opencode-ai/swarm/internal/workspace"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -25,12 +29,12 @@ var uiCmd = &cobra.Command{
 	Short: "Launch the Swarm TUI",
 	Long:  "Launch the Swarm terminal user interface (TUI).",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runTUI()
+		return runTUI(commandOptions(cmd))
 	},
 }
 
-func runTUI() error {
-	if IsNonInteractive() {
+func runTUI(opts Options) error {
+	if opts.IsNonInteractive() {
 		return &PreflightError{
 			Message:  "TUI requires an interactive terminal",
 			Hint:     "Run without --non-interactive and with a TTY, or use CLI subcommands",
@@ -52,7 +56,41 @@ func runTUI() error {
 	registry := adapters.NewRegistry()
 
 	// Create and start state engine
-	stateEngine := state.NewEngine(agentRepo, eventRepo, tmuxClient, registry)
+	stateEngine := state.NewEngine(agentRepo, eventRepo, tmuxClient, registry,
+		state.WithStuckDetection(newStuckDetector(database, tmuxClient)),
+		state.WithFailureClassifier(newFailureClassifier()))
+
+	// Record approval prompts as agents enter waiting_approval
+	approvalService := approval.NewService(
+		db.NewApprovalRepository(database),
+		agentRepo,
+		tmuxClient,
+		approval.WithPublisher(newEventPublisher(database)),
+		approval.WithConfig(GetConfig(), db.NewWorkspaceRepository(database)),
+	)
+	if err := stateEngine.Subscribe("approvals", approvalService); err != nil {
+		return err
+	}
+
+	// Record review verdicts as reviewing agents finish answering
+	reviewCollector := review.NewCollector(
+		db.NewReviewRepository(database),
+		agentRepo,
+		tmuxClient,
+		review.WithPublisher(newEventPublisher(database)),
+	)
+	if err := stateEngine.Subscribe("reviews", reviewCollector); err != nil {
+		return err
+	}
+
+	// Publish workspace.idle once a workspace's work is finished
+	idleEvaluator := workspace.NewIdleEvaluator(database, newEventPublisher(database))
+	if err := stateEngine.Subscribe("workspace-idle", idleEvaluator); err != nil {
+		return err
+	}
+	idleCtx, stopIdle := context.WithCancel(context.Background())
+	defer stopIdle()
+	go idleEvaluator.Run(idleCtx)
 
 	// Build TUI config from app config
 	tuiConfig := tui.Config{
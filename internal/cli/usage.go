@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var usageForecastWorkspace string
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+	usageCmd.AddCommand(usageForecastCmd)
+
+	usageForecastCmd.Flags().StringVarP(&usageForecastWorkspace, "workspace", "w", "", "forecast one workspace against its own budget")
+}
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Inspect cost and token usage",
+	Long:  "Inspect recorded cost and token usage. Use 'swarm export usage' for raw records.",
+}
+
+var usageForecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Project today's cost against the daily budget",
+	Long: `Project the cost of the current UTC day: what has been recorded today plus
+what the running agents are estimated to cost until midnight, using
+budget.cost_per_hour_cents. This is the projection 'swarm agent spawn' checks
+against budget.daily_ceiling_cents and per-workspace daily_budget_cents.`,
+	Example: `  swarm usage forecast
+  swarm usage forecast --workspace my-project --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		opts := account.ForecastOptions{}
+		if usageForecastWorkspace != "" {
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), usageForecastWorkspace)
+			if err != nil {
+				return err
+			}
+			opts.Workspace = ws
+		}
+
+		forecast, err := account.BuildForecast(ctx, budgetSources(database), budgetConfig(), opts, time.Now())
+		if err != nil {
+			return wrapServiceError(err, "failed to forecast daily cost")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, forecast)
+		}
+
+		scope := "all workspaces"
+		if opts.Workspace != nil {
+			scope = "workspace " + opts.Workspace.Name
+		}
+		fmt.Printf("Forecast for %s (%s), %.1fh remaining\n\n", forecast.Date, scope, forecast.HoursRemaining)
+		fmt.Printf("Spent today:  %s\n", account.FormatCents(forecast.ActualCents))
+		fmt.Printf("Running:      %s (%d agents)\n", account.FormatCents(forecast.RunningCents), forecast.RunningAgents())
+		fmt.Printf("Projected:    %s\n", account.FormatCents(forecast.ProjectedCents))
+		fmt.Printf("Budget:       %s\n", formatForecastBudget(forecast))
+
+		if len(forecast.Running) == 0 {
+			return nil
+		}
+		rows := make([][]string, 0, len(forecast.Running))
+		for _, projection := range forecast.Running {
+			rows = append(rows, []string{
+				string(projection.Type),
+				fmt.Sprintf("%d", projection.Running),
+				account.FormatCents(projection.CostPerHourCents) + "/h",
+				account.FormatCents(projection.ProjectedCents),
+			})
+		}
+		fmt.Println()
+		return writeTable(os.Stdout, []string{"TYPE", "RUNNING", "RATE", "UNTIL MIDNIGHT"}, rows)
+	},
+}
+
+// budgetSources returns the repositories cost forecasts read from.
+func budgetSources(database *db.DB) account.ForecastSources {
+	return account.ForecastSources{
+		Usage:  db.NewUsageRepository(database),
+		Agents: db.NewAgentRepository(database),
+	}
+}
+
+// budgetConfig returns the loaded config, or the defaults when none is.
+func budgetConfig() *config.Config {
+	if cfg := GetConfig(); cfg != nil {
+		return cfg
+	}
+	return config.DefaultConfig()
+}
+
+func formatForecastBudget(forecast *account.Forecast) string {
+	if forecast.CeilingCents <= 0 {
+		return "none"
+	}
+	percent := float64(forecast.ProjectedCents) / float64(forecast.CeilingCents) * 100
+	summary := fmt.Sprintf("%s (%.0f%% projected)", account.FormatCents(forecast.CeilingCents), percent)
+	if forecast.OverBudget() {
+		summary += ", over budget"
+	}
+	return summary
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageImportProvider string
+	usageImportFile     string
+	usageImportAccount  string
+	usageImportKeyMap   []string
+)
+
+func init() {
+	usageCmd.AddCommand(usageImportCmd)
+
+	usageImportCmd.Flags().StringVar(&usageImportProvider, "provider", "", "provider the export is from (anthropic, openai)")
+	usageImportCmd.Flags().StringVarP(&usageImportFile, "file", "f", "", "billing export to import ('-' for stdin)")
+	usageImportCmd.Flags().StringVar(&usageImportAccount, "account", "", "account (ID or profile) for rows no --key-map entry matches")
+	usageImportCmd.Flags().StringArrayVar(&usageImportKeyMap, "key-map", nil, "attribute rows whose API key ends in SUFFIX to ACCOUNT, as SUFFIX=ACCOUNT (repeatable)")
+	_ = usageImportCmd.MarkFlagRequired("provider")
+	_ = usageImportCmd.MarkFlagRequired("file")
+}
+
+var usageImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import usage from a provider billing export",
+	Long: `Import usage from a provider billing export, to account for usage that
+agents' output never showed: agents run outside swarm, retries inside the
+CLIs, and so on.
+
+Supported exports:
+  anthropic  the Console usage CSV (usage_date_utc, model, input_tokens,
+             output_tokens, and optionally api_key, workspace, cache token
+             columns and cost_usd)
+  openai     the JSON pages of the organization usage or costs API
+
+Each row is attributed to the account of the --key-map entry whose suffix
+its API key ends in (the longest such suffix), or else to --account. Rows
+neither matches are reported as unmatched and not imported.
+
+Every row is stored under an ID derived from the provider and the row's
+date and breakdown, so importing the same export again, or an export that
+overlaps one imported before, skips the rows already imported.`,
+	Example: `  swarm usage import --provider anthropic --file usage.csv --account primary
+  swarm usage import --provider openai --file usage.json --key-map 7d3e=ci --key-map 0001=dev`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		provider, err := models.ParseProvider(usageImportProvider)
+		if err != nil {
+			return err
+		}
+		export, err := readUsageExport(provider, usageImportFile)
+		if err != nil {
+			return err
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		attribute, err := usageImportAttribution(ctx, db.NewAccountRepository(database), provider, usageImportKeyMap, usageImportAccount)
+		if err != nil {
+			return err
+		}
+		summary, err := account.ImportUsage(ctx, db.NewUsageRepository(database), export, attribute)
+		if err != nil {
+			return wrapServiceError(err, "failed to import usage")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, summary)
+		}
+		writeUsageImportSummary(os.Stdout, summary)
+		return nil
+	},
+}
+
+func readUsageExport(provider models.Provider, path string) (*account.UsageExport, error) {
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, invalidInputError("failed to open usage export: %v", err)
+		}
+		defer file.Close()
+		input = file
+	}
+	export, err := account.ParseUsageExport(provider, input)
+	if err != nil {
+		return nil, invalidInputError("%v", err)
+	}
+	return export, nil
+}
+
+// usageImportAttribution resolves --key-map and --account to the accounts
+// rows are attributed to.
+func usageImportAttribution(ctx context.Context, repo *db.AccountRepository, provider models.Provider, keyMap []string, fallback string) (func(apiKey string) string, error) {
+	resolve := func(idOrProfile string) (string, error) {
+		acct, err := findAccount(ctx, repo, idOrProfile)
+		if err != nil {
+			return "", err
+		}
+		if acct.Provider != provider {
+			return "", invalidInputError("account %s is a %s account, not %s", acct.ProfileName, acct.Provider, provider)
+		}
+		return acct.ID, nil
+	}
+
+	suffixes := make(map[string]string, len(keyMap))
+	for _, entry := range keyMap {
+		suffix, target, ok := strings.Cut(entry, "=")
+		suffix, target = strings.TrimSpace(suffix), strings.TrimSpace(target)
+		if !ok || suffix == "" || target == "" {
+			return nil, invalidInputError("invalid --key-map %q (use SUFFIX=ACCOUNT)", entry)
+		}
+		accountID, err := resolve(target)
+		if err != nil {
+			return nil, err
+		}
+		suffixes[suffix] = accountID
+	}
+
+	fallbackID := ""
+	if fallback != "" {
+		accountID, err := resolve(fallback)
+		if err != nil {
+			return nil, err
+		}
+		fallbackID = accountID
+	}
+
+	return func(apiKey string) string {
+		best, accountID := "", fallbackID
+		for suffix, id := range suffixes {
+			if apiKey != "" && strings.HasSuffix(apiKey, suffix) && len(suffix) > len(best) {
+				best, accountID = suffix, id
+			}
+		}
+		return accountID
+	}, nil
+}
+
+func writeUsageImportSummary(out io.Writer, summary *account.UsageImportSummary) {
+	fmt.Fprintf(out, "Imported %d of %d %s rows (%s)\n", summary.Imported, summary.Rows, summary.Provider, account.FormatCents(summary.ImportedCostCents))
+	if summary.Skipped > 0 {
+		fmt.Fprintf(out, "Skipped:   %d already imported\n", summary.Skipped)
+	}
+	if summary.Unmatched > 0 {
+		keys := make([]string, 0, len(summary.UnmatchedKeys))
+		for _, key := range summary.UnmatchedKeys {
+			if key == "" {
+				key = "(no key)"
+			}
+			keys = append(keys, key)
+		}
+		fmt.Fprintf(out, "Unmatched: %d (API keys: %s; use --key-map or --account)\n", summary.Unmatched, strings.Join(keys, ", "))
+	}
+	if summary.Invalid > 0 {
+		fmt.Fprintf(out, "Invalid:   %d\n", summary.Invalid)
+		for _, issue := range summary.Issues {
+			fmt.Fprintf(out, "  line %d: %s\n", issue.Line, issue.Message)
+		}
+	}
+	if len(summary.UnknownModels) > 0 {
+		fmt.Fprintf(out, "Warning: %s not known %s models (imported anyway)\n", strings.Join(summary.UnknownModels, ", "), summary.Provider)
+	}
+}
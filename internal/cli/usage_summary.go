@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageSummaryAccount  string
+	usageSummaryProvider string
+	usageSummaryUntil    string
+	usageSummaryCached   bool
+)
+
+func init() {
+	usageCmd.AddCommand(usageSummaryCmd)
+
+	usageSummaryCmd.Flags().StringVar(&usageSummaryAccount, "account", "", "summarize one account (ID or profile)")
+	usageSummaryCmd.Flags().StringVar(&usageSummaryProvider, "provider", "", "summarize one provider")
+	usageSummaryCmd.Flags().StringVar(&usageSummaryUntil, "until", "", "summarize usage before this time (duration or timestamp)")
+	usageSummaryCmd.Flags().BoolVar(&usageSummaryCached, "cached", false, "read whole days from the daily usage cache")
+}
+
+var usageSummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize recorded token usage and cost",
+	Long: `Summarize the token usage and cost recorded between --since and --until,
+for all accounts or for one account or provider.
+
+With --cached, the whole UTC days of the range are read from the daily usage
+cache the daemon refreshes, and only the partial days at either end from the
+usage records, which is much faster over long ranges. The output shows when
+the cache was last refreshed; usage stored since then is missing from the
+cached days until the next refresh.`,
+	Example: `  swarm usage summary --since 24h
+  swarm usage summary --account primary --since 2026-01-01 --cached
+  swarm usage summary --provider anthropic --cached --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		since, err := GetSinceTime()
+		if err != nil {
+			return fmt.Errorf("invalid --since value: %w", err)
+		}
+		until, err := ParseSince(usageSummaryUntil)
+		if err != nil {
+			return fmt.Errorf("invalid --until value: %w", err)
+		}
+		if since != nil && until != nil && since.After(*until) {
+			return fmt.Errorf("--since must be before --until")
+		}
+		if usageSummaryAccount != "" && usageSummaryProvider != "" {
+			return invalidInputError("--account and --provider cannot be combined")
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		var scope account.UsageScope
+		if usageSummaryAccount != "" {
+			acct, err := findAccount(ctx, db.NewAccountRepository(database), usageSummaryAccount)
+			if err != nil {
+				return err
+			}
+			scope.AccountID = acct.ID
+		}
+		if usageSummaryProvider != "" {
+			provider, err := models.ParseProvider(usageSummaryProvider)
+			if err != nil {
+				return err
+			}
+			scope.Provider = provider
+		}
+
+		report, err := account.SummarizeUsage(ctx, db.NewUsageRepository(database), scope, since, until, usageSummaryCached, time.Now())
+		if err != nil {
+			return wrapServiceError(err, "failed to summarize usage")
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, report)
+		}
+
+		fmt.Printf("Input tokens:   %d\n", report.InputTokens)
+		fmt.Printf("Output tokens:  %d\n", report.OutputTokens)
+		fmt.Printf("Total tokens:   %d\n", report.TotalTokens)
+		fmt.Printf("Cost:           %s\n", account.FormatCents(report.TotalCostCents))
+		fmt.Printf("Requests:       %d\n", report.RequestCount)
+		fmt.Printf("Records:        %d\n", report.RecordCount)
+		if usageSummaryCached {
+			fmt.Println()
+			fmt.Println(formatUsageCacheStatus(report))
+		}
+		return nil
+	},
+}
+
+// formatUsageCacheStatus describes what a usage summary read from the
+// daily usage cache and how fresh the cache was.
+func formatUsageCacheStatus(report *account.UsageReport) string {
+	if !report.Cached {
+		return "Cache: not used (no whole day in range)"
+	}
+	days := "before " + report.CacheUntil
+	if report.CacheFrom != "" {
+		days = report.CacheFrom + " to " + report.CacheUntil
+	}
+	refreshed := "never refreshed"
+	if report.CacheRefreshedAt != nil {
+		refreshed = fmt.Sprintf("refreshed %s (%s)", report.CacheRefreshedAt.Local().Format(time.RFC3339), formatRelativeTime(*report.CacheRefreshedAt))
+	}
+	return fmt.Sprintf("Cache: days %s, %s", days, refreshed)
+}
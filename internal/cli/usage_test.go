@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/schema"
+)
+
+func TestUsageForecast(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	agent := seedQueueAgent(t, database)
+
+	acct := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "primary"}
+	if err := db.NewAccountRepository(database).Create(ctx, acct); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	usage := &models.UsageRecord{AccountID: acct.ID, AgentID: agent.ID, Provider: models.ProviderAnthropic, CostCents: 1500, RecordedAt: time.Now().UTC()}
+	if err := db.NewUsageRepository(database).Create(ctx, usage); err != nil {
+		t.Fatalf("create usage: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.WorkspaceOverrides = []config.WorkspaceOverrideConfig{{WorkspaceID: agent.WorkspaceID, DailyBudgetCents: 1000}}
+	previous := appConfig
+	appConfig = cfg
+	t.Cleanup(func() { appConfig = previous })
+
+	var forecast account.Forecast
+	if err := json.Unmarshal(runJSONCommand(t, usageForecastCmd, "--workspace", agent.WorkspaceID), &forecast); err != nil {
+		t.Fatalf("expected a forecast: %v", err)
+	}
+	if forecast.WorkspaceID != agent.WorkspaceID || forecast.ActualCents != 1500 || forecast.CeilingCents != 1000 {
+		t.Fatalf("unexpected forecast %+v", forecast)
+	}
+	if len(forecast.Running) != 1 || forecast.Running[0].Type != models.AgentTypeOpenCode || forecast.Running[0].CostPerHourCents != 200 {
+		t.Fatalf("expected the idle opencode agent at its default rate, got %+v", forecast.Running)
+	}
+
+	out := captureStdout(t, func() {
+		if code, err := runCommand(t, usageForecastCmd, "--workspace", agent.WorkspaceID); code != 0 {
+			t.Errorf("usage forecast failed with exit %d: %v", code, err)
+		}
+	})
+	for _, want := range []string{"Spent today:  $15.00", "$10.00", "over budget", "opencode"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestUsageImport(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	accounts := db.NewAccountRepository(database)
+	for _, profile := range []string{"ci", "dev"} {
+		if err := accounts.Create(ctx, &models.Account{Provider: models.ProviderAnthropic, ProfileName: profile}); err != nil {
+			t.Fatalf("create account: %v", err)
+		}
+	}
+	if err := accounts.Create(ctx, &models.Account{Provider: models.ProviderOpenAI, ProfileName: "openai-main"}); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	fixture := filepath.Join("..", "account", "testdata", "anthropic_usage.csv")
+	args := []string{"--provider", "anthropic", "--file", fixture, "--key-map", "4f2a=ci", "--key-map", "9b1c=dev"}
+	importOnce := func() (account.UsageImportSummary, models.UsageSummary) {
+		t.Helper()
+		out := runJSONCommand(t, usageImportCmd, args...)
+		doc, err := outputSchema("usage-import")
+		if err != nil {
+			t.Fatalf("outputSchema: %v", err)
+		}
+		if err := schema.Validate(doc, out); err != nil {
+			t.Fatalf("output does not match schema: %v\n%s", err, out)
+		}
+		var summary account.UsageImportSummary
+		if err := json.Unmarshal(out, &summary); err != nil {
+			t.Fatalf("expected an import summary: %v\n%s", err, out)
+		}
+		totals, err := db.NewUsageRepository(database).SummarizeAll(ctx, nil, nil)
+		if err != nil {
+			t.Fatalf("SummarizeAll: %v", err)
+		}
+		return summary, *totals
+	}
+
+	first, firstTotals := importOnce()
+	if first.Imported != 3 || first.Unmatched != 1 || first.Invalid != 4 {
+		t.Fatalf("unexpected first import %+v", first)
+	}
+	second, secondTotals := importOnce()
+	if second.Imported != 0 || second.Skipped != 3 || second.Unmatched != 1 || second.Invalid != 4 {
+		t.Fatalf("expected the second import to skip every row, got %+v", second)
+	}
+	if firstTotals != secondTotals {
+		t.Fatalf("expected identical usage after re-importing, got %+v then %+v", firstTotals, secondTotals)
+	}
+
+	ci, err := findAccountByProfile(ctx, accounts, models.ProviderAnthropic, "ci")
+	if err != nil {
+		t.Fatalf("find account: %v", err)
+	}
+	ciUsage, err := db.NewUsageRepository(database).SummarizeByAccount(ctx, ci.ID, nil, nil)
+	if err != nil {
+		t.Fatalf("SummarizeByAccount: %v", err)
+	}
+	if ciUsage.RecordCount != 1 || ciUsage.TotalCostCents != 82 {
+		t.Errorf("expected the ci key's row attributed to ci, got %+v", ciUsage)
+	}
+
+	// --account picks up the rows no key map entry matched.
+	out := captureStdout(t, func() {
+		if code, err := runCommand(t, usageImportCmd, append(args, "--account", "dev")...); code != 0 {
+			t.Errorf("usage import failed with exit %d: %v", code, err)
+		}
+	})
+	for _, want := range []string{"Imported 1 of 8 anthropic rows ($0.15)", "Skipped:   3 already imported", "line 6: expected 10 fields, got 8"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	for name, badArgs := range map[string][]string{
+		"bad key map":       {"--provider", "anthropic", "--file", fixture, "--key-map", "4f2a"},
+		"wrong provider":    {"--provider", "anthropic", "--file", fixture, "--account", "openai-main"},
+		"missing file":      {"--provider", "anthropic", "--file", filepath.Join(t.TempDir(), "nope.csv")},
+		"unknown provider":  {"--provider", "acme", "--file", fixture},
+		"unreadable export": {"--provider", "openai", "--file", fixture},
+	} {
+		if code, err := runCommand(t, usageImportCmd, badArgs...); code != ExitCodeInvalidInput {
+			t.Errorf("%s: expected exit %d, got %d (%v)", name, ExitCodeInvalidInput, code, err)
+		}
+	}
+}
@@ -328,7 +328,7 @@ Examples:
 		}
 
 		// Confirm deletion
-		if !vaultDeleteForce && !yesFlag && IsInteractive() {
+		if !vaultDeleteForce && !rootOptions().Yes && IsInteractive() {
 			fmt.Fprintf(os.Stderr, "Delete profile %q for %s? [y/N]: ", profileName, adapter)
 			var response string
 			fmt.Scanln(&response)
@@ -517,7 +517,7 @@ Examples:
 		}
 
 		// Confirm clear
-		if !vaultClearForce && !yesFlag && IsInteractive() {
+		if !vaultClearForce && !rootOptions().Yes && IsInteractive() {
 			fmt.Fprintf(os.Stderr, "Remove %d auth file(s) for %s? [y/N]: ", len(existing), adapter)
 			var response string
 			fmt.Scanln(&response)
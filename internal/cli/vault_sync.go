@@ -18,6 +18,7 @@ import (
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/models"
 	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/shell"
 	"github.com/opencode-ai/swarm/internal/ssh"
 	"github.com/opencode-ai/swarm/internal/vault"
 	"github.com/spf13/cobra"
@@ -59,7 +60,7 @@ Examples:
   swarm vault push node-1 --all`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runVaultSync(args[0], vaultSyncPush)
+		return runVaultSync(cmd.Context(), args[0], vaultSyncPush)
 	},
 }
 
@@ -73,7 +74,7 @@ Examples:
   swarm vault pull node-1 --all`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runVaultSync(args[0], vaultSyncPull)
+		return runVaultSync(cmd.Context(), args[0], vaultSyncPull)
 	},
 }
 
@@ -100,13 +101,11 @@ type vaultSyncOutput struct {
 	DryRun    bool     `json:"dry_run,omitempty"`
 }
 
-func runVaultSync(nodeName string, direction vaultSyncDirection) error {
+func runVaultSync(ctx context.Context, nodeName string, direction vaultSyncDirection) error {
 	if vaultSyncProfile != "" && vaultSyncAll {
 		return errors.New("use either --profile or --all, not both")
 	}
 
-	ctx := context.Background()
-
 	database, err := openDatabase()
 	if err != nil {
 		return err
@@ -418,7 +417,7 @@ func fetchRemoteArchive(ctx context.Context, executor ssh.Executor, remoteProfil
 	args := archivePaths(profiles)
 	remoteArgs := make([]string, 0, len(args))
 	for _, arg := range args {
-		remoteArgs = append(remoteArgs, shellEscape(arg))
+		remoteArgs = append(remoteArgs, shell.Quote(arg))
 	}
 	cmd := fmt.Sprintf("tar -czf - -C %s -- %s", shellEscapePath(remoteProfilesPath), strings.Join(remoteArgs, " "))
 	stdout, stderr, err := executor.Exec(ctx, cmd)
@@ -636,7 +635,7 @@ func confirmVaultOverwrite(location string, nodeName string, conflicts []vaultPr
 }
 
 func shouldForceOverwrite() bool {
-	return vaultSyncForce || yesFlag
+	return vaultSyncForce || rootOptions().Yes
 }
 
 func canPromptVaultSelection() bool {
@@ -720,10 +719,6 @@ func remoteDirExists(ctx context.Context, executor ssh.Executor, remotePath stri
 	return false, fmt.Errorf("failed to check remote path: %w (stderr: %s)", err, strings.TrimSpace(string(stderr)))
 }
 
-func shellEscape(value string) string {
-	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", "'\\''"))
-}
-
 func shellEscapePath(value string) string {
 	if value == "" {
 		return "''"
@@ -734,7 +729,7 @@ func shellEscapePath(value string) string {
 	if strings.HasPrefix(value, "$HOME") && isSafePath(value) {
 		return value
 	}
-	return shellEscape(value)
+	return shell.Quote(value)
 }
 
 func isSafePath(value string) bool {
@@ -79,7 +79,7 @@ Exit codes:
   # Quiet mode (no output)
   swarm wait --agent abc123 --until idle --quiet`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		// Validate condition
 		validConditions := []string{
@@ -253,15 +253,21 @@ func checkWaitCondition(
 		if err != nil {
 			return false, "", fmt.Errorf("failed to check queue: %w", err)
 		}
-		pending := 0
+		pending, inFlight := 0, 0
 		for _, item := range items {
-			if item.Status == models.QueueItemStatusPending {
+			switch item.Status {
+			case models.QueueItemStatusPending:
 				pending++
+			case models.QueueItemStatusInFlight:
+				inFlight++
 			}
 		}
-		if pending == 0 {
+		if pending == 0 && inFlight == 0 {
 			return true, "queue empty", nil
 		}
+		if inFlight > 0 {
+			return false, fmt.Sprintf("queue: %d pending, %d in flight", pending, inFlight), nil
+		}
 		return false, fmt.Sprintf("queue: %d pending", pending), nil
 
 	case WaitConditionCooldownOver:
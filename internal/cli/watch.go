@@ -7,9 +7,8 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/signal"
+	"slices"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/opencode-ai/swarm/internal/db"
@@ -24,6 +23,9 @@ const (
 	ConnectionStatusConnected ConnectionStatus = "connected"
 	// ConnectionStatusReconnecting indicates the stream is attempting to reconnect.
 	ConnectionStatusReconnecting ConnectionStatus = "reconnecting"
+	// ConnectionStatusDegraded indicates the database is too busy to poll,
+	// so the stream is backing off until writers let go of it.
+	ConnectionStatusDegraded ConnectionStatus = "degraded"
 	// ConnectionStatusDisconnected indicates the stream has permanently disconnected.
 	ConnectionStatusDisconnected ConnectionStatus = "disconnected"
 )
@@ -37,6 +39,7 @@ type ReconnectConfig struct {
 	Enabled bool
 
 	// MaxAttempts is the maximum number of reconnection attempts (0 = unlimited).
+	// Polls refused by a busy database do not count.
 	MaxAttempts int
 
 	// InitialBackoff is the initial delay before first retry.
@@ -65,9 +68,14 @@ func DefaultReconnectConfig() ReconnectConfig {
 
 // StreamConfig configures event streaming behavior.
 type StreamConfig struct {
-	// PollInterval is how often to check for new events.
+	// PollInterval is how often to check for new events. A full batch is
+	// followed by another poll at once, until the backlog is drained.
 	PollInterval time.Duration
 
+	// MaxPollInterval is how far polling backs off, with jitter, while
+	// polls keep coming back empty (0 = always poll at PollInterval).
+	MaxPollInterval time.Duration
+
 	// EventTypes filters to specific event types (nil = all).
 	EventTypes []models.EventType
 
@@ -77,6 +85,19 @@ type StreamConfig struct {
 	// EntityID filters to a specific entity.
 	EntityID string
 
+	// EntityIDs filters to any of these entities (nil = all).
+	EntityIDs []string
+
+	// TraceID filters to events recorded under one trace.
+	TraceID string
+
+	// Match, if set, drops events it returns false for after the query
+	// filters have been applied.
+	Match func(*models.Event) bool
+
+	// Format, if set, renders each event as one line instead of JSONL.
+	Format func(*models.Event) string
+
 	// Since streams events after this timestamp.
 	Since *time.Time
 
@@ -88,69 +109,85 @@ type StreamConfig struct {
 
 	// Reconnect configures automatic reconnection behavior.
 	Reconnect ReconnectConfig
+
+	// Poller, if set, serves the stream from polls shared with the other
+	// watchers subscribed to it instead of polling on its own. Streams
+	// that replay history with IncludeExisting always poll on their own.
+	Poller *SharedPoller
 }
 
 // DefaultStreamConfig returns sensible defaults for streaming.
 func DefaultStreamConfig() StreamConfig {
 	return StreamConfig{
 		PollInterval:    500 * time.Millisecond,
+		MaxPollInterval: 5 * time.Second,
 		IncludeExisting: false,
 		BatchSize:       100,
 		Reconnect:       DefaultReconnectConfig(),
 	}
 }
 
+// EventQuerier is the part of db.EventRepository event streams read from.
+type EventQuerier interface {
+	Query(ctx context.Context, q db.EventQuery) (*db.EventPage, error)
+}
+
 // EventStreamer streams events to an output writer in JSONL format.
 type EventStreamer struct {
-	repo   *db.EventRepository
+	repo   EventQuerier
 	out    io.Writer
 	config StreamConfig
 	logger func(string, ...any)
+
+	// sleep waits between polls; jitter spreads backed-off delays.
+	sleep  func(context.Context, time.Duration) error
+	jitter func(time.Duration) time.Duration
 }
 
 // NewEventStreamer creates a new event streamer.
-func NewEventStreamer(repo *db.EventRepository, out io.Writer, config StreamConfig) *EventStreamer {
+func NewEventStreamer(repo EventQuerier, out io.Writer, config StreamConfig) *EventStreamer {
+	config = withStreamDefaults(config)
+	return &EventStreamer{
+		repo:   repo,
+		out:    out,
+		config: config,
+		logger: verboseLogger,
+		sleep:  sleepWithContext,
+		jitter: pollJitter,
+	}
+}
+
+// withStreamDefaults fills in the polling settings config leaves unset.
+func withStreamDefaults(config StreamConfig) StreamConfig {
 	if config.PollInterval == 0 {
 		config.PollInterval = 500 * time.Millisecond
 	}
 	if config.BatchSize == 0 {
 		config.BatchSize = 100
 	}
-	return &EventStreamer{
-		repo:   repo,
-		out:    out,
-		config: config,
-		logger: func(format string, args ...any) {
-			if IsVerbose() {
-				fmt.Fprintf(os.Stderr, format+"\n", args...)
-			}
-		},
+	if config.MaxPollInterval < config.PollInterval {
+		config.MaxPollInterval = config.PollInterval
 	}
+	return config
 }
 
-// Stream starts streaming events until the context is cancelled.
-// Returns nil on graceful shutdown (Ctrl+C), error otherwise.
+// verboseLogger logs to stderr with --verbose.
+func verboseLogger(format string, args ...any) {
+	if rootOptions().Verbose {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+// Stream starts streaming events until the context is cancelled, as the
+// command's context is on Ctrl+C.
+// Returns nil on graceful shutdown, error otherwise.
 // If reconnection is enabled, temporary errors will trigger automatic retries.
 func (s *EventStreamer) Stream(ctx context.Context) error {
-	// Set up signal handling for graceful shutdown
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		select {
-		case <-sigChan:
-			s.logger("Received interrupt, shutting down...")
-			cancel()
-		case <-ctx.Done():
-		}
-	}()
+	if s.config.Poller != nil && !s.config.IncludeExisting {
+		return s.streamShared(ctx)
+	}
 
-	// Initialize cursor
-	var cursor string
 	var since *time.Time
-
 	if s.config.IncludeExisting {
 		// Start from the beginning or specified time
 		since = s.config.Since
@@ -160,83 +197,65 @@ func (s *EventStreamer) Stream(ctx context.Context) error {
 		since = &now
 	}
 
-	ticker := time.NewTicker(s.config.PollInterval)
-	defer ticker.Stop()
-
 	s.logger("Starting event stream (poll interval: %v)", s.config.PollInterval)
+	loop := &pollLoop{
+		config: s.config,
+		fetch:  s.poll,
+		deliver: func(events []*models.Event) error {
+			for _, event := range events {
+				if err := s.writeEvent(event); err != nil {
+					return fmt.Errorf("failed to write event: %w", err)
+				}
+			}
+			return nil
+		},
+		notify: s.notifyStatus,
+		sleep:  s.sleep,
+		jitter: s.jitter,
+		logger: s.logger,
+	}
 	s.notifyStatus(ConnectionStatusConnected, 0, 0, nil)
+	return loop.run(ctx, since)
+}
 
-	// Track reconnection state
-	var consecutiveErrors int
-	var currentBackoff time.Duration
+// streamShared streams the events of the shared poller that match the
+// stream's filters.
+func (s *EventStreamer) streamShared(ctx context.Context) error {
+	sub := s.config.Poller.subscribe(s.matches)
+	defer s.config.Poller.unsubscribe(sub)
 
+	s.logger("Starting event stream (shared poller)")
+	s.notifyStatus(ConnectionStatusConnected, 0, 0, nil)
 	for {
 		select {
 		case <-ctx.Done():
 			s.notifyStatus(ConnectionStatusDisconnected, 0, 0, nil)
 			return nil
-		case <-ticker.C:
-			events, nextCursor, err := s.poll(ctx, cursor, since)
-			if err != nil {
-				if ctx.Err() != nil {
-					s.notifyStatus(ConnectionStatusDisconnected, 0, 0, nil)
-					return nil // Context cancelled, graceful shutdown
-				}
-
-				// Handle error with reconnection logic
-				if !s.config.Reconnect.Enabled {
-					s.notifyStatus(ConnectionStatusDisconnected, 0, 0, err)
-					return fmt.Errorf("failed to poll events: %w", err)
+		case msg := <-sub.updates:
+			if msg.status != "" {
+				s.notifyStatus(msg.status, msg.attempt, msg.nextRetry, msg.err)
+				if msg.status == ConnectionStatusDisconnected && msg.err != nil {
+					return fmt.Errorf("failed to poll events: %w", msg.err)
 				}
-
-				consecutiveErrors++
-
-				// Check max attempts
-				if s.config.Reconnect.MaxAttempts > 0 && consecutiveErrors > s.config.Reconnect.MaxAttempts {
-					s.notifyStatus(ConnectionStatusDisconnected, consecutiveErrors, 0, err)
-					return fmt.Errorf("max reconnection attempts (%d) exceeded: %w", s.config.Reconnect.MaxAttempts, err)
-				}
-
-				// Calculate backoff
-				currentBackoff = s.calculateBackoff(consecutiveErrors, currentBackoff)
-				s.notifyStatus(ConnectionStatusReconnecting, consecutiveErrors, currentBackoff, err)
-				s.logger("Poll failed (attempt %d), retrying in %v: %v", consecutiveErrors, currentBackoff, err)
-
-				// Wait before retrying
-				if err := s.sleepWithContext(ctx, currentBackoff); err != nil {
-					s.notifyStatus(ConnectionStatusDisconnected, consecutiveErrors, 0, nil)
-					return nil // Context cancelled during backoff
-				}
-
 				continue
 			}
-
-			// Reset error state on successful poll
-			if consecutiveErrors > 0 {
-				s.logger("Reconnected successfully after %d attempts", consecutiveErrors)
-				s.notifyStatus(ConnectionStatusConnected, 0, 0, nil)
-				consecutiveErrors = 0
-				currentBackoff = 0
-			}
-
-			for _, event := range events {
+			for _, event := range msg.events {
 				if err := s.writeEvent(event); err != nil {
 					return fmt.Errorf("failed to write event: %w", err)
 				}
 			}
-
-			if nextCursor != "" {
-				cursor = nextCursor
-				since = nil // Use cursor-based pagination after first batch
-			}
 		}
 	}
 }
 
 // calculateBackoff computes the next backoff duration using exponential backoff.
 func (s *EventStreamer) calculateBackoff(attempt int, current time.Duration) time.Duration {
-	rc := s.config.Reconnect
+	return reconnectBackoff(s.config.Reconnect, current)
+}
 
+// reconnectBackoff returns the delay after current before the next
+// reconnection attempt.
+func reconnectBackoff(rc ReconnectConfig, current time.Duration) time.Duration {
 	if current == 0 {
 		return rc.InitialBackoff
 	}
@@ -250,7 +269,7 @@ func (s *EventStreamer) calculateBackoff(attempt int, current time.Duration) tim
 }
 
 // sleepWithContext sleeps for the given duration or until context is cancelled.
-func (s *EventStreamer) sleepWithContext(ctx context.Context, d time.Duration) error {
+func sleepWithContext(ctx context.Context, d time.Duration) error {
 	timer := time.NewTimer(d)
 	defer timer.Stop()
 
@@ -270,7 +289,7 @@ func (s *EventStreamer) notifyStatus(status ConnectionStatus, attempt int, nextR
 }
 
 // poll fetches the next batch of events.
-func (s *EventStreamer) poll(ctx context.Context, cursor string, since *time.Time) ([]*models.Event, string, error) {
+func (s *EventStreamer) poll(ctx context.Context, cursor string, since *time.Time) (pollPage, error) {
 	query := db.EventQuery{
 		Cursor: cursor,
 		Since:  since,
@@ -287,48 +306,53 @@ func (s *EventStreamer) poll(ctx context.Context, cursor string, since *time.Tim
 	if s.config.EntityID != "" {
 		query.EntityID = &s.config.EntityID
 	}
+	query.EntityIDs = s.config.EntityIDs
+	query.TraceID = s.config.TraceID
 
-	page, err := s.repo.Query(ctx, query)
+	page, err := queryPage(ctx, s.repo, query)
 	if err != nil {
-		return nil, "", err
+		return pollPage{}, err
 	}
 
-	// Filter by multiple event types if specified
+	// Filter by multiple event or entity types, and by Match
 	var filtered []*models.Event
-	if len(s.config.EventTypes) > 1 {
-		typeSet := make(map[models.EventType]bool)
-		for _, t := range s.config.EventTypes {
-			typeSet[t] = true
+	for _, e := range page.events {
+		if s.matches(e) {
+			filtered = append(filtered, e)
 		}
-		for _, e := range page.Events {
-			if typeSet[e.Type] {
-				filtered = append(filtered, e)
-			}
-		}
-	} else {
-		filtered = page.Events
 	}
+	page.events = filtered
+	return page, nil
+}
 
-	// Filter by multiple entity types if specified
-	if len(s.config.EntityTypes) > 1 {
-		typeSet := make(map[models.EntityType]bool)
-		for _, t := range s.config.EntityTypes {
-			typeSet[t] = true
-		}
-		var refiltered []*models.Event
-		for _, e := range filtered {
-			if typeSet[e.EntityType] {
-				refiltered = append(refiltered, e)
-			}
-		}
-		filtered = refiltered
+// matches reports whether event passes every filter of the stream. poll
+// leaves single-valued filters to the query; shared streams need them all.
+func (s *EventStreamer) matches(event *models.Event) bool {
+	c := s.config
+	if len(c.EventTypes) > 0 && !slices.Contains(c.EventTypes, event.Type) {
+		return false
 	}
-
-	return filtered, page.NextCursor, nil
+	if len(c.EntityTypes) > 0 && !slices.Contains(c.EntityTypes, event.EntityType) {
+		return false
+	}
+	if c.EntityID != "" && event.EntityID != c.EntityID {
+		return false
+	}
+	if len(c.EntityIDs) > 0 && !slices.Contains(c.EntityIDs, event.EntityID) {
+		return false
+	}
+	if c.TraceID != "" && event.TraceID != c.TraceID {
+		return false
+	}
+	return c.Match == nil || c.Match(event)
 }
 
-// writeEvent writes a single event as JSONL.
+// writeEvent writes a single event as JSONL, or with the configured Format.
 func (s *EventStreamer) writeEvent(event *models.Event) error {
+	if s.config.Format != nil {
+		_, err := fmt.Fprintln(s.out, s.config.Format(event))
+		return err
+	}
 	data, err := json.Marshal(event)
 	if err != nil {
 		return err
@@ -368,21 +392,32 @@ func WatchHelper(ctx context.Context, repo *db.EventRepository, entityType model
 	if !IsWatchMode() {
 		return nil
 	}
+	out, onStatus, err := watchEventOutput(os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
 
 	config := DefaultStreamConfig()
 	config.EntityTypes = []models.EntityType{entityType}
 	if entityID != "" {
 		config.EntityID = entityID
 	}
+	if onStatus != nil {
+		config.Reconnect.OnStatusChange = onStatus
+	}
 
-	streamer := NewEventStreamer(repo, os.Stdout, config)
+	streamer := NewEventStreamer(repo, out, config)
 	return streamer.Stream(ctx)
 }
 
 // MustBeJSONLForWatch ensures JSONL mode is used with --watch.
 // Returns an error if --watch is used without --jsonl.
 func MustBeJSONLForWatch() error {
-	if IsWatchMode() && !IsJSONLOutput() {
+	return rootOptions().mustBeJSONLForWatch()
+}
+
+func (o Options) mustBeJSONLForWatch() error {
+	if o.Watch && !o.JSONL {
 		return fmt.Errorf("--watch requires --jsonl output format")
 	}
 	return nil
@@ -458,7 +493,12 @@ func parseDurationWithDays(s string) (time.Duration, error) {
 // GetSinceTime parses the --since flag and returns the corresponding time.
 // Returns nil if --since was not specified.
 func GetSinceTime() (*time.Time, error) {
-	return ParseSince(GetSinceFlag())
+	return rootOptions().SinceTime()
+}
+
+// SinceTime parses the options' --since value.
+func (o Options) SinceTime() (*time.Time, error) {
+	return ParseSince(o.Since)
 }
 
 // StreamEventsWithReplay streams events starting from a specific timestamp.
@@ -494,6 +534,11 @@ func WatchHelperWithSince(ctx context.Context, repo *db.EventRepository, entityT
 		return nil
 	}
 
+	out, onStatus, err := watchEventOutput(os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+
 	since, err := GetSinceTime()
 	if err != nil {
 		return fmt.Errorf("invalid --since value: %w", err)
@@ -504,12 +549,15 @@ func WatchHelperWithSince(ctx context.Context, repo *db.EventRepository, entityT
 	if entityID != "" {
 		config.EntityID = entityID
 	}
+	if onStatus != nil {
+		config.Reconnect.OnStatusChange = onStatus
+	}
 
 	if since != nil {
 		config.Since = since
 		config.IncludeExisting = true
 	}
 
-	streamer := NewEventStreamer(repo, os.Stdout, config)
+	streamer := NewEventStreamer(repo, out, config)
 	return streamer.Stream(ctx)
 }
@@ -0,0 +1,363 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// emptyPollsBeforeBackoff is how many polls in a row must come back empty
+// before polling slows down.
+const emptyPollsBeforeBackoff = 3
+
+// pollPage is one poll of the event log.
+type pollPage struct {
+	// events are the events read that the stream wants.
+	events []*models.Event
+
+	// cursor resumes after the last event read, or is the poll's own
+	// cursor when nothing was read.
+	cursor string
+
+	// read counts the events read, wanted or not.
+	read int
+
+	// full is set when the batch was full, so more events are waiting.
+	full bool
+}
+
+// queryPage runs q and returns the page with its resume cursor.
+func queryPage(ctx context.Context, repo EventQuerier, q db.EventQuery) (pollPage, error) {
+	page, err := repo.Query(ctx, q)
+	if err != nil {
+		return pollPage{}, err
+	}
+	result := pollPage{
+		events: page.Events,
+		cursor: q.Cursor,
+		read:   len(page.Events),
+		full:   page.NextCursor != "",
+	}
+	if n := len(page.Events); n > 0 {
+		result.cursor = page.Events[n-1].ID
+	}
+	return result, nil
+}
+
+// pollBackoff decides how long to wait before the next poll. A full batch
+// is followed by another poll at once; polls that keep coming back empty
+// back off, with jitter, to the max poll interval; and polls refused by a
+// busy database back off faster still, so watchers get out of the way of
+// the writers holding it.
+type pollBackoff struct {
+	interval    time.Duration
+	maxInterval time.Duration
+	reconnect   ReconnectConfig
+	jitter      func(time.Duration) time.Duration
+
+	emptyPolls int
+	idleDelay  time.Duration
+	busyPolls  int
+	busyDelay  time.Duration
+}
+
+func newPollBackoff(config StreamConfig, jitter func(time.Duration) time.Duration) *pollBackoff {
+	return &pollBackoff{
+		interval:    config.PollInterval,
+		maxInterval: config.MaxPollInterval,
+		reconnect:   config.Reconnect,
+		jitter:      jitter,
+	}
+}
+
+// next returns the delay after a successful poll.
+func (b *pollBackoff) next(page pollPage) time.Duration {
+	b.busyPolls, b.busyDelay = 0, 0
+	if page.read > 0 {
+		b.emptyPolls, b.idleDelay = 0, 0
+		if page.full {
+			return 0
+		}
+		return b.interval
+	}
+
+	b.emptyPolls++
+	if b.emptyPolls < emptyPollsBeforeBackoff || b.maxInterval <= b.interval {
+		return b.interval
+	}
+	if b.idleDelay == 0 {
+		b.idleDelay = b.interval
+	}
+	b.idleDelay = min(2*b.idleDelay, b.maxInterval)
+	return max(b.jitter(b.idleDelay), b.interval)
+}
+
+// busy returns the delay after a poll the database refused as busy. It
+// starts at the larger of the initial reconnect backoff and four poll
+// intervals, and grows by the square of the reconnect multiplier up to
+// the max reconnect backoff.
+func (b *pollBackoff) busy() time.Duration {
+	b.busyPolls++
+	if b.busyDelay == 0 {
+		b.busyDelay = max(b.reconnect.InitialBackoff, 4*b.interval)
+	} else {
+		multiplier := b.reconnect.BackoffMultiplier
+		if multiplier <= 1 {
+			multiplier = 2
+		}
+		b.busyDelay = time.Duration(float64(b.busyDelay) * multiplier * multiplier)
+	}
+	if b.reconnect.MaxBackoff > 0 && b.busyDelay > b.reconnect.MaxBackoff {
+		b.busyDelay = b.reconnect.MaxBackoff
+	}
+	return b.jitter(b.busyDelay)
+}
+
+// pollJitter returns a delay between half of d and d, so watchers that
+// backed off together do not poll in lockstep.
+func pollJitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// pollLoop polls the event log until its context ends or it gives up,
+// handing each page to deliver and reporting connection changes to notify.
+type pollLoop struct {
+	config  StreamConfig
+	fetch   func(ctx context.Context, cursor string, since *time.Time) (pollPage, error)
+	deliver func([]*models.Event) error
+	notify  StatusCallback
+	sleep   func(context.Context, time.Duration) error
+	jitter  func(time.Duration) time.Duration
+	logger  func(string, ...any)
+}
+
+// run polls from since, which is dropped for the cursor once events have
+// been read. Returns nil once ctx ends.
+func (l *pollLoop) run(ctx context.Context, since *time.Time) error {
+	backoff := newPollBackoff(l.config, l.jitter)
+	var cursor string
+
+	// Track reconnection state
+	var consecutiveErrors int
+	var currentBackoff time.Duration
+
+	delay := l.config.PollInterval
+	for {
+		if err := l.sleep(ctx, delay); err != nil {
+			l.notify(ConnectionStatusDisconnected, consecutiveErrors, 0, nil)
+			return nil // Context cancelled
+		}
+
+		page, err := l.fetch(ctx, cursor, since)
+		if err != nil {
+			if ctx.Err() != nil {
+				l.notify(ConnectionStatusDisconnected, 0, 0, nil)
+				return nil // Context cancelled, graceful shutdown
+			}
+
+			// A busy database is contention, not a lost connection: back
+			// off without counting it against the reconnect attempts.
+			if db.IsBusyError(err) {
+				delay = backoff.busy()
+				l.notify(ConnectionStatusDegraded, backoff.busyPolls, delay, err)
+				l.logger("Database busy (attempt %d), polling again in %v: %v", backoff.busyPolls, delay, err)
+				continue
+			}
+
+			// Handle error with reconnection logic
+			if !l.config.Reconnect.Enabled {
+				l.notify(ConnectionStatusDisconnected, 0, 0, err)
+				return fmt.Errorf("failed to poll events: %w", err)
+			}
+
+			consecutiveErrors++
+
+			// Check max attempts
+			if l.config.Reconnect.MaxAttempts > 0 && consecutiveErrors > l.config.Reconnect.MaxAttempts {
+				l.notify(ConnectionStatusDisconnected, consecutiveErrors, 0, err)
+				return fmt.Errorf("max reconnection attempts (%d) exceeded: %w", l.config.Reconnect.MaxAttempts, err)
+			}
+
+			currentBackoff = reconnectBackoff(l.config.Reconnect, currentBackoff)
+			l.notify(ConnectionStatusReconnecting, consecutiveErrors, currentBackoff, err)
+			l.logger("Poll failed (attempt %d), retrying in %v: %v", consecutiveErrors, currentBackoff, err)
+			delay = currentBackoff
+			continue
+		}
+
+		// Reset error state on successful poll
+		if consecutiveErrors > 0 || backoff.busyPolls > 0 {
+			l.logger("Reconnected successfully after %d attempts", consecutiveErrors+backoff.busyPolls)
+			l.notify(ConnectionStatusConnected, 0, 0, nil)
+			consecutiveErrors = 0
+			currentBackoff = 0
+		}
+
+		if err := l.deliver(page.events); err != nil {
+			return err
+		}
+
+		if page.cursor != "" {
+			cursor = page.cursor
+			since = nil // Use cursor-based pagination after first batch
+		}
+		delay = backoff.next(page)
+	}
+}
+
+// SharedPoller polls the event log once for every in-process watcher
+// subscribed to it through StreamConfig.Poller, and hands each the events
+// matching its own filters. It polls from when the first watcher
+// subscribes until the last one leaves, so watchers see the events that
+// arrive while they are subscribed.
+type SharedPoller struct {
+	repo   EventQuerier
+	config StreamConfig
+	logger func(string, ...any)
+	sleep  func(context.Context, time.Duration) error
+	jitter func(time.Duration) time.Duration
+
+	mu   sync.Mutex
+	subs map[*pollSubscriber]struct{}
+	stop context.CancelFunc // stops the running poll loop, if any
+}
+
+// pollSubscriber is one watcher of a SharedPoller.
+type pollSubscriber struct {
+	match   func(*models.Event) bool
+	updates chan pollUpdate
+	done    chan struct{}
+}
+
+// pollUpdate is a batch of events or a connection status change.
+type pollUpdate struct {
+	events    []*models.Event
+	status    ConnectionStatus
+	attempt   int
+	nextRetry time.Duration
+	err       error
+}
+
+// NewSharedPoller creates a poller for watchers to share. Its polling
+// settings (PollInterval, MaxPollInterval, BatchSize, and Reconnect) come
+// from config; the filters are each watcher's own.
+func NewSharedPoller(repo EventQuerier, config StreamConfig) *SharedPoller {
+	return &SharedPoller{
+		repo:   repo,
+		config: withStreamDefaults(config),
+		logger: verboseLogger,
+		sleep:  sleepWithContext,
+		jitter: pollJitter,
+		subs:   make(map[*pollSubscriber]struct{}),
+	}
+}
+
+// subscribe adds a watcher of the events match accepts, starting the poll
+// loop if none is running.
+func (p *SharedPoller) subscribe(match func(*models.Event) bool) *pollSubscriber {
+	sub := &pollSubscriber{
+		match:   match,
+		updates: make(chan pollUpdate, 16),
+		done:    make(chan struct{}),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subs[sub] = struct{}{}
+	if p.stop == nil {
+		ctx, stop := context.WithCancel(context.Background())
+		p.stop = stop
+		go p.run(ctx, stop)
+	}
+	return sub
+}
+
+// unsubscribe removes sub, stopping the poll loop with the last watcher.
+func (p *SharedPoller) unsubscribe(sub *pollSubscriber) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.subs[sub]; !ok {
+		return
+	}
+	delete(p.subs, sub)
+	close(sub.done)
+	if len(p.subs) == 0 && p.stop != nil {
+		p.stop()
+		p.stop = nil
+	}
+}
+
+func (p *SharedPoller) run(ctx context.Context, stop context.CancelFunc) {
+	now := time.Now().UTC()
+	loop := &pollLoop{
+		config: p.config,
+		fetch: func(ctx context.Context, cursor string, since *time.Time) (pollPage, error) {
+			return queryPage(ctx, p.repo, db.EventQuery{Cursor: cursor, Since: since, Limit: p.config.BatchSize})
+		},
+		deliver: func(events []*models.Event) error {
+			if len(events) > 0 {
+				p.broadcast(ctx, pollUpdate{events: events})
+			}
+			return nil
+		},
+		notify: func(status ConnectionStatus, attempt int, nextRetry time.Duration, err error) {
+			p.broadcast(ctx, pollUpdate{status: status, attempt: attempt, nextRetry: nextRetry, err: err})
+		},
+		sleep:  p.sleep,
+		jitter: p.jitter,
+		logger: p.logger,
+	}
+	_ = loop.run(ctx, &now)
+
+	// A loop that gave up leaves the next subscriber to start a new one.
+	p.mu.Lock()
+	if ctx.Err() == nil {
+		stop()
+		p.stop = nil
+	}
+	p.mu.Unlock()
+}
+
+// broadcast hands each subscriber the events of u it matches, or u's
+// status change. Subscribers that joined after ctx's loop was stopped are
+// left alone.
+func (p *SharedPoller) broadcast(ctx context.Context, u pollUpdate) {
+	p.mu.Lock()
+	if ctx.Err() != nil {
+		p.mu.Unlock()
+		return
+	}
+	subs := make([]*pollSubscriber, 0, len(p.subs))
+	for sub := range p.subs {
+		subs = append(subs, sub)
+	}
+	p.mu.Unlock()
+
+	for _, sub := range subs {
+		update := u
+		if u.status == "" {
+			update.events = nil
+			for _, event := range u.events {
+				if sub.match(event) {
+					update.events = append(update.events, event)
+				}
+			}
+			if len(update.events) == 0 {
+				continue
+			}
+		}
+		select {
+		case sub.updates <- update:
+		case <-sub.done:
+		}
+	}
+}
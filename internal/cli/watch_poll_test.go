@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// pollStep is one scripted answer to an event query.
+type pollStep struct {
+	events []*models.Event
+	more   bool
+	err    error
+}
+
+// scriptedEvents answers event queries from a script, recording them. The
+// last step's answer repeats, and served is closed once the script has been
+// played through. Queries wait for release, if set.
+type scriptedEvents struct {
+	mu      sync.Mutex
+	steps   []pollStep
+	queries []db.EventQuery
+	release chan struct{}
+	served  chan struct{}
+}
+
+func newScriptedEvents(steps ...pollStep) *scriptedEvents {
+	return &scriptedEvents{steps: steps, served: make(chan struct{})}
+}
+
+func (r *scriptedEvents) Query(ctx context.Context, q db.EventQuery) (*db.EventPage, error) {
+	if r.release != nil {
+		<-r.release
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = append(r.queries, q)
+	step := r.steps[0]
+	switch {
+	case len(r.steps) > 1:
+		r.steps = r.steps[1:]
+	case !isClosed(r.served):
+		close(r.served)
+	}
+	if step.err != nil {
+		return nil, step.err
+	}
+	page := &db.EventPage{Events: step.events}
+	if step.more {
+		page.NextCursor = step.events[len(step.events)-1].ID
+	}
+	return page, nil
+}
+
+func (r *scriptedEvents) cursors() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cursors := make([]string, len(r.queries))
+	for i, q := range r.queries {
+		cursors[i] = q.Cursor
+	}
+	return cursors
+}
+
+func testEvent(id string, eventType models.EventType, entityType models.EntityType) *models.Event {
+	return &models.Event{ID: id, Type: eventType, EntityType: entityType, EntityID: id, Timestamp: time.Now().UTC()}
+}
+
+func TestEventStreamer_AdaptivePolling(t *testing.T) {
+	busy := errors.New("database is locked")
+	repo := newScriptedEvents(
+		pollStep{events: []*models.Event{testEvent("e1", models.EventTypeAgentSpawned, models.EntityTypeAgent), testEvent("e2", models.EventTypeAgentSpawned, models.EntityTypeAgent)}, more: true},
+		pollStep{events: []*models.Event{testEvent("e3", models.EventTypeAgentSpawned, models.EntityTypeAgent)}},
+		pollStep{}, pollStep{}, pollStep{}, pollStep{}, pollStep{}, pollStep{},
+		pollStep{err: busy},
+		pollStep{err: busy},
+		pollStep{},
+	)
+
+	var statuses []ConnectionStatus
+	var buf bytes.Buffer
+	config := DefaultStreamConfig()
+	config.PollInterval = 100 * time.Millisecond
+	config.MaxPollInterval = 800 * time.Millisecond
+	config.Reconnect = ReconnectConfig{
+		Enabled:           true,
+		MaxAttempts:       1,
+		InitialBackoff:    time.Second,
+		MaxBackoff:        30 * time.Second,
+		BackoffMultiplier: 2,
+		OnStatusChange: func(status ConnectionStatus, attempt int, nextRetry time.Duration, err error) {
+			statuses = append(statuses, status)
+		},
+	}
+
+	streamer := NewEventStreamer(repo, &buf, config)
+	streamer.jitter = func(d time.Duration) time.Duration { return d }
+	var delays []time.Duration
+	streamer.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		select {
+		case <-repo.served:
+			return context.Canceled
+		default:
+			return nil
+		}
+	}
+
+	if err := streamer.Stream(context.Background()); err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	ms := time.Millisecond
+	want := []time.Duration{
+		100 * ms,           // first poll
+		0,                  // full batch: poll again at once
+		100 * ms,           // partial batch
+		100 * ms, 100 * ms, // empty, not yet backing off
+		200 * ms, 400 * ms, 800 * ms, // backing off to the max
+		800 * ms,                     // held at the max
+		time.Second, 4 * time.Second, // busy database
+		800 * ms, // recovered, still idle
+	}
+	if !slices.Equal(delays, want) {
+		t.Fatalf("expected delays %v, got %v", want, delays)
+	}
+
+	wantStatuses := []ConnectionStatus{
+		ConnectionStatusConnected,
+		ConnectionStatusDegraded, ConnectionStatusDegraded,
+		ConnectionStatusConnected,
+		ConnectionStatusDisconnected,
+	}
+	if !slices.Equal(statuses, wantStatuses) {
+		t.Fatalf("expected statuses %v, got %v", wantStatuses, statuses)
+	}
+
+	// Busy polls do not count against MaxAttempts, and the cursor advances
+	// past partial batches so nothing is delivered twice.
+	if lines := strings.Count(buf.String(), "\n"); lines != 3 {
+		t.Fatalf("expected 3 events written, got %d:\n%s", lines, buf.String())
+	}
+	cursors := repo.cursors()
+	if cursors[1] != "e2" || cursors[2] != "e3" || cursors[len(cursors)-1] != "e3" {
+		t.Fatalf("expected polls to resume after the last event read, got %v", cursors)
+	}
+}
+
+func TestEventStreamer_PollErrorGivesUp(t *testing.T) {
+	repo := newScriptedEvents(pollStep{err: errors.New("disk I/O error")})
+
+	config := DefaultStreamConfig()
+	config.Reconnect.MaxAttempts = 2
+	streamer := NewEventStreamer(repo, &bytes.Buffer{}, config)
+	streamer.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	err := streamer.Stream(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "max reconnection attempts (2) exceeded") {
+		t.Fatalf("expected max attempts error, got %v", err)
+	}
+	if got := len(repo.cursors()); got != 3 {
+		t.Fatalf("expected 3 polls, got %d", got)
+	}
+}
+
+func TestSharedPoller_FansOutToWatchers(t *testing.T) {
+	repo := newScriptedEvents(
+		pollStep{events: []*models.Event{
+			testEvent("e1", models.EventTypeAgentSpawned, models.EntityTypeAgent),
+			testEvent("e2", models.EventTypeNodeOnline, models.EntityTypeNode),
+			testEvent("e3", models.EventTypeAgentStateChanged, models.EntityTypeAgent),
+			testEvent("e4", models.EventTypeAgentSpawned, models.EntityTypeAgent),
+		}},
+		pollStep{},
+	)
+	repo.release = make(chan struct{})
+
+	config := DefaultStreamConfig()
+	config.PollInterval = time.Millisecond
+	poller := NewSharedPoller(repo, config)
+
+	spawned := config
+	spawned.Poller = poller
+	spawned.EventTypes = []models.EventType{models.EventTypeAgentSpawned}
+	agents := config
+	agents.Poller = poller
+	agents.EntityTypes = []models.EntityType{models.EntityTypeAgent}
+	agents.EntityIDs = []string{"e3", "e4"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	outputs := []*syncBuffer{{}, {}}
+	var wg sync.WaitGroup
+	for i, cfg := range []StreamConfig{spawned, agents} {
+		streamer := NewEventStreamer(repo, outputs[i], cfg)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := streamer.Stream(ctx); err != nil {
+				t.Errorf("Stream() error = %v", err)
+			}
+		}()
+	}
+
+	waitFor(t, func() bool {
+		poller.mu.Lock()
+		defer poller.mu.Unlock()
+		return len(poller.subs) == 2
+	})
+	close(repo.release)
+	waitFor(t, func() bool {
+		return strings.Count(outputs[0].String(), "\n") == 2 && strings.Count(outputs[1].String(), "\n") == 2
+	})
+	<-repo.served
+	cancel()
+	wg.Wait()
+
+	for i, want := range [][]string{{"e1", "e4"}, {"e3", "e4"}} {
+		var got []string
+		for _, line := range strings.Split(strings.TrimSpace(outputs[i].String()), "\n") {
+			for _, id := range []string{"e1", "e2", "e3", "e4"} {
+				if strings.Contains(line, `"`+id+`"`) {
+					got = append(got, id)
+				}
+			}
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("watcher %d: expected events %v, got %v", i, want, got)
+		}
+	}
+
+	// One unfiltered poll serves both watchers, and the poller stops with
+	// its last watcher.
+	repo.mu.Lock()
+	for _, q := range repo.queries {
+		if q.Type != nil || q.EntityType != nil || len(q.EntityIDs) > 0 {
+			t.Errorf("expected unfiltered shared polls, got %+v", q)
+		}
+	}
+	repo.mu.Unlock()
+	poller.mu.Lock()
+	defer poller.mu.Unlock()
+	if len(poller.subs) != 0 || poller.stop != nil {
+		t.Fatal("expected the poller to stop with its last watcher")
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to write from one goroutine while the
+// test reads it from another.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
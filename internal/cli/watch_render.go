@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// EventRenderer turns a streamed event into one line of human output.
+type EventRenderer interface {
+	Render(event *models.Event) (string, error)
+}
+
+// Watch --format values. The default, jsonl, leaves the stream untouched.
+const (
+	watchFormatJSONL    = "jsonl"
+	watchFormatPretty   = "pretty"
+	watchFormatTemplate = "template="
+)
+
+// ParseWatchFormat returns the renderer for a --format value, or nil for
+// the default JSONL stream.
+func ParseWatchFormat(spec string) (EventRenderer, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "" || spec == watchFormatJSONL:
+		return nil, nil
+	case spec == watchFormatPretty:
+		return prettyRenderer{}, nil
+	case strings.HasPrefix(spec, watchFormatTemplate):
+		return newTemplateRenderer(strings.TrimPrefix(spec, watchFormatTemplate))
+	default:
+		return nil, fmt.Errorf("unknown --format %q (use jsonl, pretty, or template='<go template>')", spec)
+	}
+}
+
+// watchEventColors is the color for each event type in pretty output.
+// Types missing here are rendered uncolored.
+var watchEventColors = map[models.EventType]string{
+	models.EventTypeNodeOnline:   colorGreen,
+	models.EventTypeNodeOffline:  colorRed,
+	models.EventTypeNodeAdded:    colorCyan,
+	models.EventTypeNodeRemoved:  colorYellow,
+	models.EventTypeNodeDegraded: colorYellow,
+
+	models.EventTypeWorkspaceCreated:   colorGreen,
+	models.EventTypeWorkspaceImported:  colorGreen,
+	models.EventTypeWorkspaceDestroyed: colorRed,
+	models.EventTypeWorkspaceUnmanaged: colorYellow,
+	models.EventTypeWorkspacePaused:    colorYellow,
+	models.EventTypeWorkspaceResumed:   colorGreen,
+	models.EventTypeWorkspaceIdle:      colorCyan,
+
+	models.EventTypeAgentSpawned:      colorGreen,
+	models.EventTypeAgentStateChanged: colorCyan,
+	models.EventTypeAgentRestarted:    colorYellow,
+	models.EventTypeAgentTerminated:   colorRed,
+	models.EventTypeAgentPaused:       colorYellow,
+	models.EventTypeAgentResumed:      colorGreen,
+	models.EventTypeAgentStuck:        colorRed,
+	models.EventTypeAgentRecovery:     colorYellow,
+	models.EventTypeAgentMoved:        colorCyan,
+	models.EventTypeAgentSnapshot:     colorCyan,
+	models.EventTypeAgentClaimed:      colorCyan,
+	models.EventTypeAgentCheckpointed: colorCyan,
+	models.EventTypeAgentRestored:     colorGreen,
+	models.EventTypeAgentDrained:      colorYellow,
+	models.EventTypeAgentHibernated:   colorYellow,
+	models.EventTypeAgentWoken:        colorGreen,
+
+	models.EventTypeMessageQueued:     colorCyan,
+	models.EventTypeMessageDispatched: colorCyan,
+	models.EventTypeMessageCompleted:  colorGreen,
+	models.EventTypeMessageFailed:     colorRed,
+	models.EventTypeMessageRecovered:  colorYellow,
+
+	models.EventTypeQueueItemDispatched: colorCyan,
+
+	models.EventTypeApprovalRequested: colorMagenta,
+	models.EventTypeApprovalApproved:  colorGreen,
+	models.EventTypeApprovalDenied:    colorRed,
+
+	models.EventTypeReviewCompleted: colorMagenta,
+
+	models.EventTypeNoteAdded: colorCyan,
+
+	models.EventTypeRateLimitDetected:  colorYellow,
+	models.EventTypeCooldownStarted:    colorYellow,
+	models.EventTypeCooldownEnded:      colorGreen,
+	models.EventTypeAccountRotated:     colorMagenta,
+	models.EventTypeCredentialExpiring: colorYellow,
+
+	models.EventTypeProviderHealthChanged: colorYellow,
+
+	models.EventTypeBudgetExceeded: colorRed,
+	models.EventTypeUsageRecorded:  colorCyan,
+
+	models.EventTypeError:   colorRed,
+	models.EventTypeWarning: colorYellow,
+}
+
+// prettyTypeWidth pads event types so summaries line up.
+const prettyTypeWidth = 21
+
+// prettyRenderer renders an event as its relative time, colored type, and
+// the same summary the workspace feed shows.
+type prettyRenderer struct {
+	names events.Names
+}
+
+func (r prettyRenderer) Render(event *models.Event) (string, error) {
+	eventType := fmt.Sprintf("%-*s", prettyTypeWidth, event.Type)
+	return fmt.Sprintf("%-8s  %s  %s",
+		formatRelativeTime(event.Timestamp),
+		colorize(eventType, watchEventColors[event.Type]),
+		events.Summarize(event, r.names),
+	), nil
+}
+
+// watchEventView is what a --format template sees: the event with its
+// payload decoded, plus the summary pretty output would show.
+type watchEventView struct {
+	ID         string
+	Timestamp  time.Time
+	Type       models.EventType
+	EntityType models.EntityType
+	EntityID   string
+	Payload    map[string]any
+	Metadata   map[string]string
+	TraceID    string
+	Summary    string
+}
+
+// templateRenderer renders events with a user template. It gets no
+// functions beyond the text/template builtins, and the view has no
+// methods, so a template can only read the event.
+type templateRenderer struct {
+	tmpl *template.Template
+}
+
+// newTemplateRenderer parses text and renders it once against an empty
+// event, so unknown fields fail up front rather than on every event.
+func newTemplateRenderer(text string) (*templateRenderer, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("--format template= needs a template, e.g. template='{{.Type}} {{.EntityID}}'")
+	}
+	tmpl, err := template.New("watch").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, watchEventView{}); err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return &templateRenderer{tmpl: tmpl}, nil
+}
+
+func (r *templateRenderer) Render(event *models.Event) (string, error) {
+	view := watchEventView{
+		ID:         event.ID,
+		Timestamp:  event.Timestamp,
+		Type:       event.Type,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		Metadata:   event.Metadata,
+		TraceID:    event.TraceID,
+		Summary:    events.Summarize(event, events.Names{}),
+	}
+	if len(event.Payload) > 0 {
+		// Payloads that are not objects are left out rather than failing.
+		_ = json.Unmarshal(event.Payload, &view.Payload)
+	}
+	var out strings.Builder
+	if err := r.tmpl.Execute(&out, view); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// renderSink sits between an EventStreamer and its output. It decodes the
+// JSONL the streamer writes and writes each event rendered instead. Events
+// that fail to render are reported on errOut and skipped.
+type renderSink struct {
+	out      io.Writer
+	errOut   io.Writer
+	renderer EventRenderer
+	pending  []byte
+}
+
+func newRenderSink(out, errOut io.Writer, renderer EventRenderer) *renderSink {
+	return &renderSink{out: out, errOut: errOut, renderer: renderer}
+}
+
+func (s *renderSink) Write(p []byte) (int, error) {
+	s.pending = append(s.pending, p...)
+	for {
+		i := bytes.IndexByte(s.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := s.pending[:i]
+		s.pending = s.pending[i+1:]
+		if err := s.renderLine(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+func (s *renderSink) renderLine(line []byte) error {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+	var event models.Event
+	if err := json.Unmarshal(line, &event); err != nil {
+		fmt.Fprintf(s.errOut, "swarm: skipping undecodable event: %v\n", err)
+		return nil
+	}
+	text, err := s.renderer.Render(&event)
+	if err != nil {
+		fmt.Fprintf(s.errOut, "swarm: cannot render %s event %s: %v\n", event.Type, event.ID, err)
+		return nil
+	}
+	_, err = fmt.Fprintln(s.out, text)
+	return err
+}
+
+// watchStatusNotices reports stream reconnects on errOut, so they stay out
+// of the rendered output. The initial connect and a clean shutdown are not
+// reported, and a busy database only once until polling recovers.
+func watchStatusNotices(errOut io.Writer) StatusCallback {
+	reconnecting, degraded := false, false
+	return func(status ConnectionStatus, attempt int, nextRetry time.Duration, err error) {
+		switch status {
+		case ConnectionStatusReconnecting:
+			reconnecting = true
+			fmt.Fprintf(errOut, "swarm: event stream lost (%v); retrying in %s (attempt %d)\n", err, nextRetry, attempt)
+		case ConnectionStatusDegraded:
+			if !degraded {
+				degraded = true
+				fmt.Fprintf(errOut, "swarm: database busy; event stream backing off (next poll in %s)\n", nextRetry)
+			}
+		case ConnectionStatusConnected:
+			if reconnecting {
+				fmt.Fprintln(errOut, "swarm: event stream reconnected")
+			} else if degraded {
+				fmt.Fprintln(errOut, "swarm: event stream caught up")
+			}
+			reconnecting, degraded = false, false
+		case ConnectionStatusDisconnected:
+			if err != nil {
+				fmt.Fprintf(errOut, "swarm: event stream disconnected: %v\n", err)
+			}
+		}
+	}
+}
+
+// watchEventOutput validates --format for an event stream and returns the
+// writer the streamer should write to, with the status callback to use.
+// For the default JSONL stream it returns out and a nil callback, and
+// --watch still requires --jsonl.
+func watchEventOutput(out, errOut io.Writer) (io.Writer, StatusCallback, error) {
+	return rootOptions().watchEventOutput(out, errOut)
+}
+
+func (o Options) watchEventOutput(out, errOut io.Writer) (io.Writer, StatusCallback, error) {
+	renderer, err := ParseWatchFormat(o.WatchFormat)
+	if err != nil {
+		return nil, nil, invalidInputError("%v", err)
+	}
+	if renderer == nil {
+		return out, nil, o.mustBeJSONLForWatch()
+	}
+	if !o.Watch {
+		return nil, nil, invalidInputError("--format applies only to --watch streams")
+	}
+	if o.Structured() {
+		return nil, nil, invalidInputError("--format %s cannot be combined with --json or --jsonl", strings.TrimSpace(o.WatchFormat))
+	}
+	return newRenderSink(out, errOut, renderer), watchStatusNotices(errOut), nil
+}
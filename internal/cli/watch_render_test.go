@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestPrettyRendererRendersRegisteredTypes(t *testing.T) {
+	setRootFlag(t, "no-color", "false")
+	t.Setenv("NO_COLOR", "")
+	os.Unsetenv("NO_COLOR")
+
+	for eventType, color := range watchEventColors {
+		t.Run(string(eventType), func(t *testing.T) {
+			event := &models.Event{
+				ID:         "evt-1",
+				Timestamp:  time.Now().UTC(),
+				Type:       eventType,
+				EntityType: models.EntityTypeAgent,
+				EntityID:   "agent-0123456789",
+				Payload:    json.RawMessage(`{}`),
+			}
+			line, err := prettyRenderer{}.Render(event)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if !strings.HasPrefix(line, "just now") {
+				t.Errorf("expected a relative timestamp, got %q", line)
+			}
+			if !strings.Contains(line, color+string(eventType)) {
+				t.Errorf("expected %s colored, got %q", eventType, line)
+			}
+			if summary := events.Summarize(event, events.Names{}); !strings.HasSuffix(line, summary) {
+				t.Errorf("expected the summary %q, got %q", summary, line)
+			}
+			if strings.Contains(line, "\n") {
+				t.Errorf("expected a single line, got %q", line)
+			}
+		})
+	}
+}
+
+func TestPrettyRendererWithoutColor(t *testing.T) {
+	setRootFlag(t, "no-color", "true")
+
+	for _, eventType := range []models.EventType{models.EventTypeAgentStuck, "custom.unregistered"} {
+		line, err := prettyRenderer{}.Render(&models.Event{
+			Timestamp: time.Now().Add(-5 * time.Minute),
+			Type:      eventType,
+			EntityID:  "agent-1",
+		})
+		if err != nil {
+			t.Fatalf("Render: %v", err)
+		}
+		if strings.Contains(line, "\x1b[") {
+			t.Errorf("expected no escape codes, got %q", line)
+		}
+		if !strings.HasPrefix(line, "5m ago") || !strings.Contains(line, string(eventType)) {
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	renderer, err := ParseWatchFormat(`template={{.Type}} {{.EntityID}} {{.Payload.agent_id}} {{index .Metadata "source"}}`)
+	if err != nil {
+		t.Fatalf("ParseWatchFormat: %v", err)
+	}
+	line, err := renderer.Render(&models.Event{
+		Type:     models.EventTypeAccountRotated,
+		EntityID: "acct-1",
+		Payload:  json.RawMessage(`{"agent_id":"agent-7"}`),
+		Metadata: map[string]string{"source": "scheduler"},
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if want := "account.rotated acct-1 agent-7 scheduler"; line != want {
+		t.Fatalf("expected %q, got %q", want, line)
+	}
+}
+
+func TestTemplateRendererExecError(t *testing.T) {
+	renderer, err := ParseWatchFormat(`template={{range .Payload.items}}{{.name}}{{end}}`)
+	if err != nil {
+		t.Fatalf("ParseWatchFormat: %v", err)
+	}
+	if _, err := renderer.Render(&models.Event{Payload: json.RawMessage(`{"items":[1]}`)}); err == nil {
+		t.Fatal("expected an error reading a field of a number")
+	}
+}
+
+func TestParseWatchFormat(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr string
+	}{
+		{spec: "", want: "<nil>"},
+		{spec: "jsonl", want: "<nil>"},
+		{spec: "pretty", want: "cli.prettyRenderer"},
+		{spec: "template={{.Type}}", want: "*cli.templateRenderer"},
+		{spec: "table", wantErr: "unknown --format"},
+		{spec: "template=", wantErr: "needs a template"},
+		{spec: "template={{.Type", wantErr: "invalid --format template"},
+		{spec: "template={{.Nope}}", wantErr: "can't evaluate field Nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			renderer, err := ParseWatchFormat(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWatchFormat: %v", err)
+			}
+			if got := fmt.Sprintf("%T", renderer); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+type failingRenderer struct{}
+
+func (failingRenderer) Render(event *models.Event) (string, error) {
+	if event.Type == models.EventTypeError {
+		return "", errors.New("boom")
+	}
+	return string(event.Type) + " " + event.EntityID, nil
+}
+
+func TestRenderSink(t *testing.T) {
+	var out, errOut bytes.Buffer
+	sink := newRenderSink(&out, &errOut, failingRenderer{})
+
+	first, _ := json.Marshal(&models.Event{ID: "1", Type: models.EventTypeAgentSpawned, EntityID: "a1"})
+	second, _ := json.Marshal(&models.Event{ID: "2", Type: models.EventTypeError, EntityID: "a2"})
+	third, _ := json.Marshal(&models.Event{ID: "3", Type: models.EventTypeAgentTerminated, EntityID: "a3"})
+	stream := string(first) + "\n" + string(second) + "\nnot json\n" + string(third) + "\n"
+
+	// Split writes mid-line, as a buffered writer might.
+	for _, chunk := range []string{stream[:10], stream[10:40], stream[40:]} {
+		if n, err := sink.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Write: n=%d err=%v", n, err)
+		}
+	}
+
+	if want := "agent.spawned a1\nagent.terminated a3\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+	if !strings.Contains(errOut.String(), "cannot render error event 2: boom") {
+		t.Errorf("expected the render error reported, got %q", errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "skipping undecodable event") {
+		t.Errorf("expected the bad line reported, got %q", errOut.String())
+	}
+}
+
+func TestWatchStatusNotices(t *testing.T) {
+	var errOut bytes.Buffer
+	notify := watchStatusNotices(&errOut)
+
+	notify(ConnectionStatusConnected, 0, 0, nil)
+	if errOut.Len() != 0 {
+		t.Fatalf("expected the initial connect to be quiet, got %q", errOut.String())
+	}
+
+	notify(ConnectionStatusReconnecting, 1, 2*time.Second, errors.New("database is locked"))
+	notify(ConnectionStatusConnected, 0, 0, nil)
+	notify(ConnectionStatusDegraded, 1, time.Second, errors.New("database is locked"))
+	notify(ConnectionStatusDegraded, 2, 4*time.Second, errors.New("database is locked"))
+	notify(ConnectionStatusConnected, 0, 0, nil)
+	notify(ConnectionStatusDisconnected, 0, 0, nil)
+
+	want := "swarm: event stream lost (database is locked); retrying in 2s (attempt 1)\n" +
+		"swarm: event stream reconnected\n" +
+		"swarm: database busy; event stream backing off (next poll in 1s)\n" +
+		"swarm: event stream caught up\n"
+	if errOut.String() != want {
+		t.Fatalf("expected %q, got %q", want, errOut.String())
+	}
+}
+
+func TestWatchEventOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		watch    bool
+		jsonl    bool
+		format   string
+		wantSink bool
+		wantErr  bool
+	}{
+		{name: "jsonl stream", watch: true, jsonl: true},
+		{name: "watch needs jsonl by default", watch: true, wantErr: true},
+		{name: "pretty stream", watch: true, format: "pretty", wantSink: true},
+		{name: "template stream", watch: true, format: "template={{.Type}}", wantSink: true},
+		{name: "pretty with jsonl", watch: true, jsonl: true, format: "pretty", wantErr: true},
+		{name: "pretty without watch", format: "pretty", wantErr: true},
+		{name: "bad format", watch: true, format: "xml", wantErr: true},
+		{name: "no watch", wantSink: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Watch: tt.watch, JSONL: tt.jsonl, WatchFormat: tt.format}
+
+			var stdout bytes.Buffer
+			out, onStatus, err := opts.watchEventOutput(&stdout, &bytes.Buffer{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("watchEventOutput: %v", err)
+			}
+			_, isSink := out.(*renderSink)
+			if isSink != tt.wantSink || (onStatus != nil) != tt.wantSink {
+				t.Fatalf("expected sink=%v, got %T (status callback %v)", tt.wantSink, out, onStatus != nil)
+			}
+		})
+	}
+}
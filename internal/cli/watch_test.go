@@ -96,17 +96,17 @@ func TestEventStreamer_Poll(t *testing.T) {
 
 	// Poll should return up to BatchSize events
 	past := time.Now().Add(-1 * time.Hour)
-	events, cursor, err := streamer.poll(ctx, "", &past)
+	page, err := streamer.poll(ctx, "", &past)
 	if err != nil {
 		t.Fatalf("poll failed: %v", err)
 	}
 
-	if len(events) != 2 {
-		t.Errorf("expected 2 events, got %d", len(events))
+	if len(page.events) != 2 {
+		t.Errorf("expected 2 events, got %d", len(page.events))
 	}
 
-	if cursor == "" {
-		t.Error("expected non-empty cursor for pagination")
+	if page.cursor == "" || !page.full {
+		t.Error("expected a full page with a cursor for pagination")
 	}
 }
 
@@ -144,10 +144,11 @@ func TestEventStreamer_FilterByEntityType(t *testing.T) {
 	streamer := NewEventStreamer(repo, &buf, config)
 
 	past := time.Now().Add(-1 * time.Hour)
-	events, _, err := streamer.poll(ctx, "", &past)
+	page, err := streamer.poll(ctx, "", &past)
 	if err != nil {
 		t.Fatalf("poll failed: %v", err)
 	}
+	events := page.events
 
 	if len(events) != 1 {
 		t.Errorf("expected 1 event, got %d", len(events))
@@ -198,14 +199,6 @@ func TestDefaultStreamConfig(t *testing.T) {
 }
 
 func TestMustBeJSONLForWatch(t *testing.T) {
-	// Save original values
-	origWatch := watchMode
-	origJSONL := jsonlOutput
-	defer func() {
-		watchMode = origWatch
-		jsonlOutput = origJSONL
-	}()
-
 	tests := []struct {
 		name      string
 		watch     bool
@@ -220,10 +213,7 @@ func TestMustBeJSONLForWatch(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			watchMode = tt.watch
-			jsonlOutput = tt.jsonl
-
-			err := MustBeJSONLForWatch()
+			err := Options{Watch: tt.watch, JSONL: tt.jsonl}.mustBeJSONLForWatch()
 			if tt.wantError && err == nil {
 				t.Error("expected error but got nil")
 			}
@@ -7,13 +7,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/opencode-ai/swarm/internal/agent"
 	"github.com/opencode-ai/swarm/internal/beads"
+	"github.com/opencode-ai/swarm/internal/config"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/models"
 	"github.com/opencode-ai/swarm/internal/node"
@@ -22,411 +24,630 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var (
-	// ws create flags
-	wsCreatePath    string
-	wsCreateNode    string
-	wsCreateName    string
-	wsCreateSession string
-	wsCreateNoTmux  bool
-
-	// ws import flags
-	wsImportSession  string
-	wsImportNode     string
-	wsImportName     string
-	wsImportRepoPath string
-
-	// ws list flags
-	wsListNode   string
-	wsListStatus string
-
-	// ws remove flags
-	wsRemoveForce   bool
-	wsRemoveDestroy bool
-
-	// ws kill flags
-	wsKillForce bool
-)
+func newWsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ws",
+		Aliases: []string{"workspace"},
+		Short:   "Manage workspaces",
+		Long: `Manage Swarm workspaces.
 
-func init() {
-	rootCmd.AddCommand(wsCmd)
-	wsCmd.AddCommand(wsCreateCmd)
-	wsCmd.AddCommand(wsImportCmd)
-	wsCmd.AddCommand(wsListCmd)
-	wsCmd.AddCommand(wsStatusCmd)
-	wsCmd.AddCommand(wsBeadsStatusCmd)
-	wsCmd.AddCommand(wsAttachCmd)
-	wsCmd.AddCommand(wsRemoveCmd)
-	wsCmd.AddCommand(wsKillCmd)
-	wsCmd.AddCommand(wsRefreshCmd)
-
-	// Create flags
-	wsCreateCmd.Flags().StringVar(&wsCreatePath, "path", "", "repository path (required)")
-	wsCreateCmd.Flags().StringVar(&wsCreateNode, "node", "", "node name or ID (default: local)")
-	wsCreateCmd.Flags().StringVar(&wsCreateName, "name", "", "workspace name (default: derived from path)")
-	wsCreateCmd.Flags().StringVar(&wsCreateSession, "session", "", "tmux session name (default: auto-generated)")
-	wsCreateCmd.Flags().BoolVar(&wsCreateNoTmux, "no-tmux", false, "don't create tmux session")
-	wsCreateCmd.MarkFlagRequired("path")
-
-	// Import flags
-	wsImportCmd.Flags().StringVar(&wsImportSession, "session", "", "tmux session name (required)")
-	wsImportCmd.Flags().StringVar(&wsImportNode, "node", "", "node name or ID (required)")
-	wsImportCmd.Flags().StringVar(&wsImportName, "name", "", "workspace name (default: session name)")
-	wsImportCmd.Flags().StringVar(&wsImportRepoPath, "repo-path", "", "repository path override (use when multiple repos are detected)")
-	wsImportCmd.MarkFlagRequired("session")
-	wsImportCmd.MarkFlagRequired("node")
-
-	// List flags
-	wsListCmd.Flags().StringVar(&wsListNode, "node", "", "filter by node")
-	wsListCmd.Flags().StringVar(&wsListStatus, "status", "", "filter by status (active, archived)")
-
-	// Remove flags
-	wsRemoveCmd.Flags().BoolVarP(&wsRemoveForce, "force", "f", false, "force removal even with active agents")
-	wsRemoveCmd.Flags().BoolVar(&wsRemoveDestroy, "destroy", false, "also kill the tmux session")
-
-	// Kill flags
-	wsKillCmd.Flags().BoolVarP(&wsKillForce, "force", "f", false, "force kill even with active agents")
-}
+A workspace represents a repository with an associated tmux session where agents run.`,
+	}
 
-var wsCmd = &cobra.Command{
-	Use:     "ws",
-	Aliases: []string{"workspace"},
-	Short:   "Manage workspaces",
-	Long: `Manage Swarm workspaces.
+	cmd.AddCommand(newWsCreateCmd())
+	cmd.AddCommand(newWsImportCmd())
+	cmd.AddCommand(newWsListCmd())
+	cmd.AddCommand(newWsStatusCmd())
+	cmd.AddCommand(newWsBeadsStatusCmd())
+	cmd.AddCommand(newWsAttachCmd())
+	cmd.AddCommand(newWsRemoveCmd())
+	cmd.AddCommand(newWsKillCmd())
+	cmd.AddCommand(newWsRefreshCmd())
+	cmd.AddCommand(newWsBootstrapCmd())
+	cmd.AddCommand(newWsBroadcastCmd())
+	cmd.AddCommand(newWsCloneCmd())
+	cmd.AddCommand(newWsDrainCmd())
+	cmd.AddCommand(newWsUndrainCmd())
+	cmd.AddCommand(newWsFeedCmd())
+	cmd.AddCommand(newWsLayoutCmd())
+	cmd.AddCommand(newWsPauseCmd())
+	cmd.AddCommand(newWsResumeCmd())
+	cmd.AddCommand(newWsRepairSessionsCmd())
+	cmd.AddCommand(newWsSetCmd())
+	return cmd
+}
 
-A workspace represents a repository with an associated tmux session where agents run.`,
+// wsCreateFlags holds the flags of 'swarm ws create'.
+type wsCreateFlags struct {
+	path               string
+	node               string
+	name               string
+	session            string
+	noTmux             bool
+	placement          string
+	requireLabel       []string
+	clone              string
+	branch             string
+	depth              int
+	bootstrap          bool
+	bootstrapOverwrite bool
 }
 
-var wsCreateCmd = &cobra.Command{
-	Use:   "create",
-	Short: "Create a new workspace",
-	Long: `Create a new workspace for a repository.
+func newWsCreateCmd() *cobra.Command {
+	var flags wsCreateFlags
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a new workspace",
+		Long: `Create a new workspace for a repository.
 
-By default, a tmux session is created in the repository directory.`,
-	Example: `  # Create workspace for current directory
+By default, a tmux session is created in the repository directory.
+
+With --clone, the repository is first cloned into --path on the workspace's
+node, using that node's git and SSH credentials. A path that already holds a
+clone of the same remote is used as is.
+
+With --bootstrap, or workspace_defaults.bootstrap set, the files in
+workspace_defaults.bootstrap_files are then written into the repository
+(see ws bootstrap). Files that already exist are kept unless
+--bootstrap-overwrite is given.`,
+		Example: `  # Create workspace for current directory
   swarm ws create --path .
 
   # Create with custom name
   swarm ws create --path /home/user/myproject --name my-project
 
   # Create on a specific node
-  swarm ws create --path /data/repos/api --node prod-server`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+  swarm ws create --path /data/repos/api --node prod-server
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
-
-		// Resolve services
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		eventRepo := db.NewEventRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithEventRepository(eventRepo), workspace.WithPublisher(newEventPublisher(database)))
-
-		// Resolve node ID if name provided
-		nodeID := ""
-		if wsCreateNode != "" {
-			n, err := findNode(ctx, nodeService, wsCreateNode)
+  # Place on the online node with the fewest agents
+  swarm ws create --path /data/repos/api --node auto
+
+  # Place only on GPU nodes, rotating between them
+  swarm ws create --path /data/repos/api --require-label gpu=true --placement round-robin
+
+  # Clone a repository onto a fresh node and create the workspace
+  swarm ws create --clone git@github.com:org/repo.git --branch main --depth 1 --path /data/repos/repo --node prod-server
+
+  # Create and write the configured AGENTS.md and prompt files
+  swarm ws create --path . --bootstrap`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			clone, err := wsCreateCloneInput(flags)
 			if err != nil {
 				return err
 			}
-			nodeID = n.ID
-		}
-
-		input := workspace.CreateWorkspaceInput{
-			NodeID:            nodeID,
-			RepoPath:          wsCreatePath,
-			Name:              wsCreateName,
-			TmuxSession:       wsCreateSession,
-			CreateTmuxSession: !wsCreateNoTmux,
-		}
 
-		step := startProgress("Creating workspace")
-		ws, err := wsService.CreateWorkspace(ctx, input)
-		if err != nil {
-			step.Fail(err)
-			if errors.Is(err, workspace.ErrWorkspaceAlreadyExists) {
-				return fmt.Errorf("workspace already exists for this path")
+			defaults := config.DefaultConfig().WorkspaceDefaults
+			if cfg := GetConfig(); cfg != nil {
+				defaults = cfg.WorkspaceDefaults
 			}
-			if errors.Is(err, workspace.ErrRepoValidationFailed) {
-				return fmt.Errorf("invalid repository path: %w", err)
+			bootstrap := defaults.Bootstrap
+			if cmd.Flags().Changed("bootstrap") {
+				bootstrap = flags.bootstrap
+			}
+			var bootstrapFiles []workspace.BootstrapFile
+			if bootstrap || flags.bootstrapOverwrite {
+				// Load the files first so a missing source fails before
+				// anything is created.
+				if bootstrapFiles, err = bootstrapFileInputs(GetConfig()); err != nil {
+					return err
+				}
 			}
-			return fmt.Errorf("failed to create workspace: %w", err)
-		}
-		step.Done()
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, ws)
-		}
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		fmt.Printf("Workspace created:\n")
-		fmt.Printf("  ID:      %s\n", ws.ID)
-		fmt.Printf("  Name:    %s\n", ws.Name)
-		fmt.Printf("  Path:    %s\n", ws.RepoPath)
-		fmt.Printf("  Session: %s\n", ws.TmuxSession)
-		if ws.GitInfo != nil && ws.GitInfo.Branch != "" {
-			fmt.Printf("  Branch:  %s\n", ws.GitInfo.Branch)
-		}
+			// Resolve services
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			eventRepo := db.NewEventRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithEventRepository(eventRepo), workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
 
-		if !wsCreateNoTmux {
-			fmt.Printf("\nAttach with: tmux attach -t %s\n", ws.TmuxSession)
-		}
+			nodeID, placement, err := resolveWsCreateNode(ctx, nodeService, flags.node, flags.placement, flags.requireLabel, defaults)
+			if err != nil {
+				return err
+			}
 
-		return nil
-	},
-}
+			input := workspace.CreateWorkspaceInput{
+				NodeID:            nodeID,
+				Placement:         placement,
+				RepoPath:          flags.path,
+				Name:              flags.name,
+				TmuxSession:       flags.session,
+				CreateTmuxSession: !flags.noTmux,
+				Clone:             clone,
+			}
 
-var wsImportCmd = &cobra.Command{
-	Use:   "import",
-	Short: "Import an existing tmux session",
-	Long: `Import an existing tmux session as a workspace.
+			label := "Creating workspace"
+			if clone != nil {
+				label = fmt.Sprintf("Cloning %s and creating workspace", clone.URL)
+			}
+			step := opts.startProgress(label)
+			ws, err := wsService.CreateWorkspace(ctx, input)
+			if err != nil {
+				step.Fail(err)
+				if errors.Is(err, workspace.ErrWorkspaceAlreadyExists) {
+					return conflictError("workspace already exists for this path")
+				}
+				if errors.Is(err, workspace.ErrClonePathInUse) {
+					return conflictError("%v", err)
+				}
+				if errors.Is(err, workspace.ErrCloneFailed) {
+					return err
+				}
+				if errors.Is(err, workspace.ErrRepoValidationFailed) {
+					return fmt.Errorf("invalid repository path: %w", err)
+				}
+				return wrapServiceError(err, "failed to create workspace")
+			}
+			step.Done()
 
-This allows Swarm to manage agents in sessions created outside of Swarm.`,
-	Example: `  swarm ws import --session my-project --node localhost`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+			if bootstrapFiles != nil {
+				result, err := bootstrapWorkspace(ctx, opts, wsService, ws, bootstrapFiles, flags.bootstrapOverwrite)
+				if err != nil {
+					return fmt.Errorf("workspace '%s' was created, but %w (retry with: swarm ws bootstrap %s)", ws.Name, err, ws.ID)
+				}
+				ws.Bootstrap = result
+			}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			if opts.Structured() {
+				return opts.WriteOutput(ws)
+			}
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		eventRepo := db.NewEventRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithEventRepository(eventRepo), workspace.WithPublisher(newEventPublisher(database)))
+			fmt.Fprintf(opts.Out, "Workspace created:\n")
+			fmt.Fprintf(opts.Out, "  ID:      %s\n", ws.ID)
+			fmt.Fprintf(opts.Out, "  Name:    %s\n", ws.Name)
+			fmt.Fprintf(opts.Out, "  Path:    %s\n", ws.RepoPath)
+			fmt.Fprintf(opts.Out, "  Session: %s\n", ws.TmuxSession)
+			if ws.GitInfo != nil && ws.GitInfo.Branch != "" {
+				fmt.Fprintf(opts.Out, "  Branch:  %s\n", ws.GitInfo.Branch)
+			}
+			if ws.Placement != nil {
+				fmt.Fprintf(opts.Out, "  Placed:  %s (%s)\n", ws.Placement.Strategy, ws.Placement.Reason)
+			}
+			if ws.Bootstrap != nil {
+				fmt.Fprintf(opts.Out, "  Bootstrap:\n")
+				printBootstrapFiles(opts.Out, ws.Bootstrap)
+			}
 
-		// Resolve node
-		n, err := findNode(ctx, nodeService, wsImportNode)
-		if err != nil {
-			return err
-		}
+			if !flags.noTmux {
+				fmt.Fprintf(opts.Out, "\nAttach with: tmux attach -t %s\n", ws.TmuxSession)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.path, "path", "", "repository path (required)")
+	cmd.Flags().StringVar(&flags.node, "node", "", "node name or ID, or \"auto\" to place automatically (default: workspace_defaults.default_node, else local)")
+	cmd.Flags().StringVar(&flags.name, "name", "", "workspace name (default: derived from path)")
+	cmd.Flags().StringVar(&flags.session, "session", "", "tmux session name (default: auto-generated)")
+	cmd.Flags().BoolVar(&flags.noTmux, "no-tmux", false, "don't create tmux session")
+	cmd.Flags().StringVar(&flags.placement, "placement", "", "placement strategy for --node auto: least-agents, round-robin, pinned-by-label, least-loaded (default: workspace_defaults.placement)")
+	cmd.Flags().StringSliceVar(&flags.requireLabel, "require-label", nil, "only place on nodes with this label (key=value, repeatable; implies --node auto)")
+	cmd.Flags().StringVar(&flags.clone, "clone", "", "clone this git remote into --path on the node first")
+	cmd.Flags().StringVar(&flags.branch, "branch", "", "branch to check out with --clone (default: the remote's default branch)")
+	cmd.Flags().IntVar(&flags.depth, "depth", 0, "shallow clone depth with --clone (default: full history)")
+	cmd.Flags().BoolVar(&flags.bootstrap, "bootstrap", false, "write workspace_defaults.bootstrap_files into the repo (default: workspace_defaults.bootstrap)")
+	cmd.Flags().BoolVar(&flags.bootstrapOverwrite, "bootstrap-overwrite", false, "replace existing files when bootstrapping (implies --bootstrap)")
+	cmd.MarkFlagRequired("path")
+	_ = cmd.RegisterFlagCompletionFunc("node", completeNodeNames)
+	return cmd
+}
 
-		input := workspace.ImportWorkspaceInput{
-			NodeID:      n.ID,
-			TmuxSession: wsImportSession,
-			Name:        wsImportName,
-			RepoPath:    wsImportRepoPath,
+// wsCreateCloneInput returns the clone requested by the ws create flags, or
+// nil without --clone.
+func wsCreateCloneInput(flags wsCreateFlags) (*workspace.RepoClone, error) {
+	url := strings.TrimSpace(flags.clone)
+	if url == "" {
+		if flags.branch != "" || flags.depth != 0 {
+			return nil, invalidInputError("--branch and --depth require --clone")
 		}
+		return nil, nil
+	}
+	if flags.depth < 0 {
+		return nil, invalidInputError("--depth must be >= 0")
+	}
+	if !filepath.IsAbs(flags.path) {
+		return nil, invalidInputError("--path must be absolute with --clone, got %q", flags.path)
+	}
+	return &workspace.RepoClone{URL: url, Branch: strings.TrimSpace(flags.branch), Depth: flags.depth}, nil
+}
 
-		step := startProgress("Importing workspace")
-		ws, err := wsService.ImportWorkspace(ctx, input)
-		if err != nil {
-			var ambiguous *workspace.AmbiguousRepoRootError
-			if errors.As(err, &ambiguous) {
-				step.Fail(err)
-				if wsImportRepoPath != "" {
-					return fmt.Errorf("failed to import workspace: %w", err)
-				}
-				if !IsInteractive() || IsJSONOutput() || IsJSONLOutput() {
-					return fmt.Errorf("ambiguous repository roots detected: %s (use --repo-path to select)", strings.Join(ambiguous.Roots, ", "))
-				}
+// resolveWsCreateNode turns the ws create node flags into either a node ID
+// or a placement request. Label or strategy flags without --node imply auto.
+func resolveWsCreateNode(ctx context.Context, nodeService *node.Service, nodeFlag, strategyFlag string, requireLabels []string, defaults config.WorkspaceConfig) (string, *node.PlacementRequest, error) {
+	nodeFlag = strings.TrimSpace(nodeFlag)
+	if nodeFlag == "" && (strategyFlag != "" || len(requireLabels) > 0) {
+		nodeFlag = "auto"
+	}
+	if nodeFlag == "" {
+		nodeFlag = strings.TrimSpace(defaults.DefaultNode)
+	}
 
-				selection, selectErr := promptRepoRootSelection(ambiguous.Roots)
-				if selectErr != nil {
-					return selectErr
-				}
-				input.RepoPath = selection
-				step = startProgress("Importing workspace")
-				ws, err = wsService.ImportWorkspace(ctx, input)
-			}
+	if nodeFlag != "auto" {
+		if strategyFlag != "" || len(requireLabels) > 0 {
+			return "", nil, invalidInputError("--placement and --require-label cannot be combined with --node %s", nodeFlag)
 		}
+		if nodeFlag == "" {
+			return "", nil, nil
+		}
+		n, err := findNode(ctx, nodeService, nodeFlag)
 		if err != nil {
-			step.Fail(err)
-			if errors.Is(err, workspace.ErrWorkspaceAlreadyExists) {
-				return fmt.Errorf("workspace already exists for this session")
-			}
-			if errors.Is(err, workspace.ErrRepoValidationFailed) {
-				return fmt.Errorf("invalid repository path: %w", err)
-			}
-			return fmt.Errorf("failed to import workspace: %w", err)
+			return "", nil, err
 		}
-		step.Done()
+		return n.ID, nil, nil
+	}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, ws)
-		}
+	labels, err := node.ParseLabels(requireLabels)
+	if err != nil {
+		return "", nil, err
+	}
+	strategy := models.PlacementStrategy(strategyFlag)
+	if strategy == "" {
+		strategy = defaults.Placement
+	}
+	if !strategy.IsValid() {
+		return "", nil, invalidInputError("invalid placement %q (use least-agents, round-robin, pinned-by-label, least-loaded)", strategy)
+	}
 
-		fmt.Printf("Workspace imported:\n")
-		fmt.Printf("  ID:      %s\n", ws.ID)
-		fmt.Printf("  Name:    %s\n", ws.Name)
-		fmt.Printf("  Path:    %s\n", ws.RepoPath)
-		fmt.Printf("  Session: %s\n", ws.TmuxSession)
+	return "", &node.PlacementRequest{
+		Strategy:      strategy,
+		RequireLabels: labels,
+		PinLabels:     defaults.PinLabels,
+	}, nil
+}
 
-		return nil
-	},
+// wsImportFlags holds the flags of 'swarm ws import'.
+type wsImportFlags struct {
+	session  string
+	node     string
+	name     string
+	repoPath string
 }
 
-var wsListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List workspaces",
-	Long:  "List all workspaces managed by Swarm.",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+func newWsImportCmd() *cobra.Command {
+	var flags wsImportFlags
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import an existing tmux session",
+		Long: `Import an existing tmux session as a workspace.
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+This allows Swarm to manage agents in sessions created outside of Swarm.`,
+		Example: `  swarm ws import --session my-project --node localhost`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		// Build options
-		opts := workspace.ListWorkspacesOptions{
-			IncludeAgentCounts: true,
-		}
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			eventRepo := db.NewEventRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithEventRepository(eventRepo), workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
 
-		if wsListNode != "" {
 			// Resolve node
-			n, err := findNode(ctx, nodeService, wsListNode)
+			n, err := findNode(ctx, nodeService, flags.node)
 			if err != nil {
 				return err
 			}
-			opts.NodeID = n.ID
-		}
 
-		if wsListStatus != "" {
-			status := models.WorkspaceStatus(wsListStatus)
-			opts.Status = &status
-		}
+			input := workspace.ImportWorkspaceInput{
+				NodeID:      n.ID,
+				TmuxSession: flags.session,
+				Name:        flags.name,
+				RepoPath:    flags.repoPath,
+			}
 
-		workspaces, err := wsService.ListWorkspaces(ctx, opts)
-		if err != nil {
-			return fmt.Errorf("failed to list workspaces: %w", err)
-		}
+			step := opts.startProgress("Importing workspace")
+			ws, err := wsService.ImportWorkspace(ctx, input)
+			if err != nil {
+				var ambiguous *workspace.AmbiguousRepoRootError
+				if errors.As(err, &ambiguous) {
+					step.Fail(err)
+					if flags.repoPath != "" {
+						return invalidInputError("failed to import workspace: %w", err)
+					}
+					if !opts.IsInteractive() || opts.Structured() {
+						return invalidInputError("ambiguous repository roots detected: %s (use --repo-path to select)", strings.Join(ambiguous.Roots, ", "))
+					}
+
+					selection, selectErr := promptRepoRootSelection(ambiguous.Roots)
+					if selectErr != nil {
+						return selectErr
+					}
+					input.RepoPath = selection
+					step = opts.startProgress("Importing workspace")
+					ws, err = wsService.ImportWorkspace(ctx, input)
+				}
+			}
+			if err != nil {
+				step.Fail(err)
+				if errors.Is(err, workspace.ErrWorkspaceAlreadyExists) {
+					return conflictError("workspace already exists for this session")
+				}
+				if errors.Is(err, workspace.ErrRepoValidationFailed) {
+					return fmt.Errorf("invalid repository path: %w", err)
+				}
+				return wrapServiceError(err, "failed to import workspace")
+			}
+			step.Done()
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, workspaces)
-		}
+			if opts.Structured() {
+				return opts.WriteOutput(ws)
+			}
+
+			fmt.Fprintf(opts.Out, "Workspace imported:\n")
+			fmt.Fprintf(opts.Out, "  ID:      %s\n", ws.ID)
+			fmt.Fprintf(opts.Out, "  Name:    %s\n", ws.Name)
+			fmt.Fprintf(opts.Out, "  Path:    %s\n", ws.RepoPath)
+			fmt.Fprintf(opts.Out, "  Session: %s\n", ws.TmuxSession)
 
-		if len(workspaces) == 0 {
-			fmt.Println("No workspaces found")
 			return nil
-		}
+		},
+	}
 
-		nodeLabels := make(map[string]string)
-		if nodes, err := nodeService.ListNodes(ctx, nil); err == nil {
-			for _, n := range nodes {
-				if n != nil && n.Name != "" {
-					nodeLabels[n.ID] = n.Name
-				}
-			}
-		}
+	cmd.Flags().StringVar(&flags.session, "session", "", "tmux session name (required)")
+	cmd.Flags().StringVar(&flags.node, "node", "", "node name or ID (required)")
+	cmd.Flags().StringVar(&flags.name, "name", "", "workspace name (default: session name)")
+	cmd.Flags().StringVar(&flags.repoPath, "repo-path", "", "repository path override (use when multiple repos are detected)")
+	cmd.MarkFlagRequired("session")
+	cmd.MarkFlagRequired("node")
+	_ = cmd.RegisterFlagCompletionFunc("node", completeNodeNames)
+	return cmd
+}
 
-		rows := make([][]string, 0, len(workspaces))
-		for _, ws := range workspaces {
-			nodeLabel := nodeLabels[ws.NodeID]
-			if nodeLabel == "" {
-				nodeLabel = shortID(ws.NodeID)
-			}
-
-			rows = append(rows, []string{
-				ws.Name,
-				shortID(ws.ID),
-				nodeLabel,
-				truncatePath(ws.RepoPath, 40),
-				formatWorkspaceStatus(ws.Status),
-				fmt.Sprintf("%d", ws.AgentCount),
-				ws.TmuxSession,
-			})
-		}
+// wsListFlags holds the flags of 'swarm ws list'.
+type wsListFlags struct {
+	node   string
+	status string
+	filter string
+}
 
-		return writeTable(os.Stdout, []string{"NAME", "ID", "NODE", "PATH", "STATUS", "AGENTS", "SESSION"}, rows)
-	},
+func newWsListCmd() *cobra.Command {
+	var flags wsListFlags
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List workspaces",
+		Long: `List all workspaces managed by Swarm.
+
+--filter keeps the workspaces matching an expression over their fields:
+name, id, node (name or ID), status, path, agents (agent count), and age
+(time since creation). See 'swarm agent list --help' for the grammar.`,
+		Example: `  swarm ws list --filter 'status=active and agents=0 and age>7d'
+  swarm ws list --filter 'node in [local, gpu-box]'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWsList(cmd, flags)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.node, "node", "", "filter by node")
+	cmd.Flags().StringVar(&flags.status, "status", "", "filter by status (active, archived)")
+	addFilterFlag(cmd, &flags.filter, "status=active and agents=0")
+	_ = cmd.RegisterFlagCompletionFunc("node", completeNodeNames)
+	return cmd
 }
 
-var wsStatusCmd = &cobra.Command{
-	Use:   "status <id-or-name>",
-	Short: "Show workspace status",
-	Long:  "Display detailed status for a workspace including git info and agent states.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		idOrName := args[0]
+// runWsList lists workspaces for ws list and its ls alias.
+func runWsList(cmd *cobra.Command, flags wsListFlags) error {
+	opts := commandOptions(cmd)
+	ctx := cmd.Context()
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+	filter, err := listFilterFlag(flags.filter, wsFilterFields)
+	if err != nil {
+		return err
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	nodeRepo := db.NewNodeRepository(database)
+	nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+	wsRepo := db.NewWorkspaceRepository(database)
+	agentRepo := db.NewAgentRepository(database)
+	wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+	// Build options
+	listOpts := workspace.ListWorkspacesOptions{
+		IncludeAgentCounts: true,
+	}
 
-		// Find workspace
-		ws, err := findWorkspace(ctx, wsRepo, idOrName)
+	if flags.node != "" {
+		// Resolve node
+		n, err := findNode(ctx, nodeService, flags.node)
 		if err != nil {
 			return err
 		}
-
-		status, err := wsService.GetWorkspaceStatus(ctx, ws.ID)
+		listOpts.NodeID = n.ID
+	} else if name, ok := filter.Pushdown("node"); ok {
+		n, err := findNode(ctx, nodeService, name)
 		if err != nil {
-			return fmt.Errorf("failed to get workspace status: %w", err)
+			return err
 		}
+		listOpts.NodeID = n.ID
+	}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, status)
-		}
+	if flags.status != "" {
+		status := models.WorkspaceStatus(flags.status)
+		listOpts.Status = &status
+	} else if name, ok := filter.Pushdown("status"); ok {
+		status := models.WorkspaceStatus(strings.ToLower(name))
+		listOpts.Status = &status
+	}
 
-		// Pretty print
-		fmt.Printf("Workspace: %s (%s)\n", status.Workspace.Name, status.Workspace.ID)
-		fmt.Printf("Path:      %s\n", status.Workspace.RepoPath)
-		fmt.Printf("Session:   %s\n", status.Workspace.TmuxSession)
-		fmt.Printf("Status:    %s\n", formatWorkspaceStatus(status.Workspace.Status))
-		fmt.Printf("Beads:     %v\n", status.BeadsDetected)
-		fmt.Printf("Agent Mail: %v\n", status.AgentMailDetected)
-		fmt.Println()
-
-		fmt.Printf("Node Online:   %v\n", status.NodeOnline)
-		fmt.Printf("Tmux Active:   %v\n", status.TmuxActive)
-		fmt.Println()
-
-		if status.GitInfo != nil {
-			fmt.Printf("Git:\n")
-			fmt.Printf("  Branch:   %s\n", status.GitInfo.Branch)
-			if status.GitInfo.LastCommit != "" {
-				fmt.Printf("  Commit:   %s\n", truncate(status.GitInfo.LastCommit, 12))
-			}
-			if status.GitInfo.RemoteURL != "" {
-				fmt.Printf("  Remote:   %s\n", status.GitInfo.RemoteURL)
-			}
-			fmt.Println()
-		}
+	workspaces, err := wsService.ListWorkspaces(ctx, listOpts)
+	if err != nil {
+		return wrapServiceError(err, "failed to list workspaces")
+	}
 
-		fmt.Printf("Agents:\n")
-		fmt.Printf("  Active:  %d\n", status.ActiveAgents)
-		fmt.Printf("  Idle:    %d\n", status.IdleAgents)
-		fmt.Printf("  Blocked: %d\n", status.BlockedAgents)
-		if status.Pulse != nil && status.Pulse.Sparkline != "" {
-			fmt.Printf("  Pulse:   %s (last %dm)\n", status.Pulse.Sparkline, status.Pulse.WindowMinutes)
+	nodeLabels := make(map[string]string)
+	if nodes, err := nodeService.ListNodes(ctx, nil); err == nil {
+		for _, n := range nodes {
+			if n != nil && n.Name != "" {
+				nodeLabels[n.ID] = n.Name
+			}
 		}
+	}
 
-		if len(status.Alerts) > 0 {
-			fmt.Println()
-			fmt.Printf("Alerts:\n")
-			for _, alert := range status.Alerts {
-				fmt.Printf("  [%s] %s (agent: %s)\n", alert.Severity, alert.Message, alert.AgentID)
+	if filter != nil {
+		now := time.Now()
+		matched := workspaces[:0]
+		for _, ws := range workspaces {
+			if filter.Match(wsFilterRecord(ws, nodeLabels[ws.NodeID]), now) {
+				matched = append(matched, ws)
 			}
 		}
+		workspaces = matched
+	}
+
+	if opts.Structured() {
+		return opts.WriteOutput(workspaces)
+	}
 
+	if len(workspaces) == 0 {
+		fmt.Fprintln(opts.Out, "No workspaces found")
 		return nil
-	},
+	}
+
+	rows := make([][]string, 0, len(workspaces))
+	for _, ws := range workspaces {
+		nodeLabel := nodeLabels[ws.NodeID]
+		if nodeLabel == "" {
+			nodeLabel = shortID(ws.NodeID)
+		}
+
+		rows = append(rows, []string{
+			ws.Name,
+			shortID(ws.ID),
+			nodeLabel,
+			ws.RepoPath,
+			formatWorkspaceStatus(ws.Status),
+			fmt.Sprintf("%d", ws.AgentCount),
+			ws.TmuxSession,
+		})
+	}
+
+	return opts.writeTable([]string{"NAME", "ID", "NODE", "PATH", "STATUS", "AGENTS", "SESSION"}, rows)
+}
+
+func newWsStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "status <id-or-name>",
+		Short:             "Show workspace status",
+		Long:              "Display detailed status for a workspace including git info and agent states.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeWorkspaceNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			idOrName := args[0]
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
+
+			// Find workspace
+			ws, err := findWorkspace(ctx, wsRepo, idOrName)
+			if err != nil {
+				return err
+			}
+
+			status, err := wsService.GetWorkspaceStatus(ctx, ws.ID)
+			if err != nil {
+				return wrapServiceError(err, "failed to get workspace status")
+			}
+			if status.LatestNote, err = latestNote(ctx, database, models.EntityTypeWorkspace, ws.ID); err != nil {
+				return err
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(status)
+			}
+
+			// Pretty print
+			if status.Paused {
+				fmt.Fprintf(opts.Out, "%s\n\n", formatWorkspacePause(status.Workspace.Pause))
+			}
+			fmt.Fprintf(opts.Out, "Workspace: %s (%s)\n", status.Workspace.Name, status.Workspace.ID)
+			fmt.Fprintf(opts.Out, "Path:      %s\n", status.Workspace.RepoPath)
+			fmt.Fprintf(opts.Out, "Session:   %s\n", status.Workspace.TmuxSession)
+			fmt.Fprintf(opts.Out, "Status:    %s\n", formatWorkspaceStatus(status.Workspace.Status))
+			if quiet := quietHoursStatus(time.Now(), status.Workspace.QuietHours); quiet != "" {
+				fmt.Fprintf(opts.Out, "Dispatch:  %s\n", quiet)
+			}
+			if after := status.Workspace.HibernateAfter(); after > 0 {
+				fmt.Fprintf(opts.Out, "Hibernate: after %s idle\n", after)
+			}
+			if status.LatestNote != nil {
+				fmt.Fprintf(opts.Out, "Note:      %s\n", formatNote(status.LatestNote))
+			}
+			fmt.Fprintf(opts.Out, "Beads:     %v\n", status.BeadsDetected)
+			fmt.Fprintf(opts.Out, "Agent Mail: %v\n", status.AgentMailDetected)
+			fmt.Fprintln(opts.Out)
+
+			fmt.Fprintf(opts.Out, "Node Online:   %v\n", status.NodeOnline)
+			fmt.Fprintf(opts.Out, "Tmux Active:   %v\n", status.TmuxActive)
+			if status.TmuxActive {
+				fmt.Fprintf(opts.Out, "Pane Zoomed:   %s\n", formatYesNo(status.PaneZoomed))
+			}
+			fmt.Fprintln(opts.Out)
+
+			if status.GitInfo != nil {
+				fmt.Fprintf(opts.Out, "Git:\n")
+				fmt.Fprintf(opts.Out, "  Branch:   %s\n", status.GitInfo.Branch)
+				if status.GitInfo.LastCommit != "" {
+					fmt.Fprintf(opts.Out, "  Commit:   %s\n", truncate(status.GitInfo.LastCommit, 12))
+				}
+				if status.GitInfo.RemoteURL != "" {
+					fmt.Fprintf(opts.Out, "  Remote:   %s\n", status.GitInfo.RemoteURL)
+				}
+				fmt.Fprintln(opts.Out)
+			}
+
+			fmt.Fprintf(opts.Out, "Agents:\n")
+			fmt.Fprintf(opts.Out, "  Active:  %d\n", status.ActiveAgents)
+			fmt.Fprintf(opts.Out, "  Idle:    %d\n", status.IdleAgents)
+			fmt.Fprintf(opts.Out, "  Blocked: %d\n", status.BlockedAgents)
+			if status.Pulse != nil && status.Pulse.Sparkline != "" {
+				fmt.Fprintf(opts.Out, "  Pulse:   %s (last %dm)\n", status.Pulse.Sparkline, status.Pulse.WindowMinutes)
+			}
+
+			if len(status.Alerts) > 0 {
+				fmt.Fprintln(opts.Out)
+				fmt.Fprintf(opts.Out, "Alerts:\n")
+				for _, alert := range status.Alerts {
+					fmt.Fprintf(opts.Out, "  [%s] %s (agent: %s)\n", alert.Severity, alert.Message, alert.AgentID)
+				}
+			}
+
+			return nil
+		},
+	}
+	return cmd
 }
 
 type beadsStatusReport struct {
@@ -438,366 +659,533 @@ type beadsStatusReport struct {
 	Total          int                 `json:"total"`
 }
 
-var wsBeadsStatusCmd = &cobra.Command{
-	Use:   "beads-status <id-or-name>",
-	Short: "Show beads issue status for a workspace",
-	Long:  "Export beads issue status from the workspace .beads/issues.jsonl file.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		idOrName := args[0]
-
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+func newWsBeadsStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "beads-status <id-or-name>",
+		Short:             "Show beads issue status for a workspace",
+		Long:              "Export beads issue status from the workspace .beads/issues.jsonl file.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeWorkspaceNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			idOrName := args[0]
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		wsRepo := db.NewWorkspaceRepository(database)
-		ws, err := findWorkspace(ctx, wsRepo, idOrName)
-		if err != nil {
-			return err
-		}
+			wsRepo := db.NewWorkspaceRepository(database)
+			ws, err := findWorkspace(ctx, wsRepo, idOrName)
+			if err != nil {
+				return err
+			}
 
-		issuesPath := beads.IssuesPath(ws.RepoPath)
-		issues, err := beads.LoadIssues(issuesPath)
-		if err != nil {
-			if errors.Is(err, os.ErrNotExist) {
-				return fmt.Errorf("no beads issues file found at %s", issuesPath)
+			issuesPath := beads.IssuesPath(ws.RepoPath)
+			issues, err := beads.LoadIssues(issuesPath)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return notFoundError("no beads issues file found at %s", issuesPath)
+				}
+				return wrapServiceError(err, "failed to read beads issues")
 			}
-			return fmt.Errorf("failed to read beads issues: %w", err)
-		}
 
-		summaries := beads.Summaries(issues)
-		sort.Slice(summaries, func(i, j int) bool {
-			left := summaries[i]
-			right := summaries[j]
+			summaries := beads.Summaries(issues)
+			sort.Slice(summaries, func(i, j int) bool {
+				left := summaries[i]
+				right := summaries[j]
 
-			if beadsStatusRank(left.Status) != beadsStatusRank(right.Status) {
-				return beadsStatusRank(left.Status) < beadsStatusRank(right.Status)
-			}
-			if left.Priority != right.Priority {
-				return left.Priority < right.Priority
+				if beadsStatusRank(left.Status) != beadsStatusRank(right.Status) {
+					return beadsStatusRank(left.Status) < beadsStatusRank(right.Status)
+				}
+				if left.Priority != right.Priority {
+					return left.Priority < right.Priority
+				}
+				return left.ID < right.ID
+			})
+
+			statusCounts, priorityCounts := summarizeBeadsCounts(summaries)
+			report := beadsStatusReport{
+				Workspace:      ws,
+				IssuesPath:     issuesPath,
+				Tasks:          summaries,
+				StatusCounts:   statusCounts,
+				PriorityCounts: priorityCounts,
+				Total:          len(summaries),
 			}
-			return left.ID < right.ID
-		})
 
-		statusCounts, priorityCounts := summarizeBeadsCounts(summaries)
-		report := beadsStatusReport{
-			Workspace:      ws,
-			IssuesPath:     issuesPath,
-			Tasks:          summaries,
-			StatusCounts:   statusCounts,
-			PriorityCounts: priorityCounts,
-			Total:          len(summaries),
-		}
+			if opts.Structured() {
+				return opts.WriteOutput(report)
+			}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, report)
-		}
+			fmt.Fprintf(opts.Out, "Workspace: %s (%s)\n", ws.Name, ws.ID)
+			fmt.Fprintf(opts.Out, "Path:      %s\n", ws.RepoPath)
+			fmt.Fprintf(opts.Out, "Beads:     %s\n", issuesPath)
+			fmt.Fprintf(opts.Out, "Total:     %d\n", report.Total)
+			fmt.Fprintf(opts.Out, "Status:    open=%d in_progress=%d closed=%d\n",
+				statusCounts["open"], statusCounts["in_progress"], statusCounts["closed"])
+			fmt.Fprintf(opts.Out, "Priority:  P0=%d P1=%d P2=%d P3=%d P4=%d\n",
+				priorityCounts[0], priorityCounts[1], priorityCounts[2], priorityCounts[3], priorityCounts[4])
+			fmt.Fprintln(opts.Out)
+
+			if len(summaries) == 0 {
+				fmt.Fprintln(opts.Out, "No beads issues found.")
+				return nil
+			}
 
-		fmt.Printf("Workspace: %s (%s)\n", ws.Name, ws.ID)
-		fmt.Printf("Path:      %s\n", ws.RepoPath)
-		fmt.Printf("Beads:     %s\n", issuesPath)
-		fmt.Printf("Total:     %d\n", report.Total)
-		fmt.Printf("Status:    open=%d in_progress=%d closed=%d\n",
-			statusCounts["open"], statusCounts["in_progress"], statusCounts["closed"])
-		fmt.Printf("Priority:  P0=%d P1=%d P2=%d P3=%d P4=%d\n",
-			priorityCounts[0], priorityCounts[1], priorityCounts[2], priorityCounts[3], priorityCounts[4])
-		fmt.Println()
-
-		if len(summaries) == 0 {
-			fmt.Println("No beads issues found.")
-			return nil
-		}
+			rows := make([][]string, 0, len(summaries))
+			for _, task := range summaries {
+				rows = append(rows, []string{
+					task.ID,
+					task.Status,
+					fmt.Sprintf("P%d", task.Priority),
+					task.IssueType,
+					task.Title,
+				})
+			}
 
-		rows := make([][]string, 0, len(summaries))
-		for _, task := range summaries {
-			rows = append(rows, []string{
-				task.ID,
-				task.Status,
-				fmt.Sprintf("P%d", task.Priority),
-				task.IssueType,
-				task.Title,
-			})
-		}
+			return opts.writeTable([]string{"ID", "STATUS", "PRIORITY", "TYPE", "TITLE"}, rows)
+		},
+	}
+	return cmd
+}
 
-		return writeTable(os.Stdout, []string{"ID", "STATUS", "PRIORITY", "TYPE", "TITLE"}, rows)
-	},
+// wsAttachFlags holds the flags of 'swarm ws attach'.
+type wsAttachFlags struct {
+	layout string
 }
 
-var wsAttachCmd = &cobra.Command{
-	Use:   "attach <id-or-name>",
-	Short: "Attach to workspace tmux session",
-	Long:  "Attach to the tmux session for a workspace.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		idOrName := args[0]
+func newWsAttachCmd() *cobra.Command {
+	var flags wsAttachFlags
+	cmd := &cobra.Command{
+		Use:   "attach <id-or-name>",
+		Short: "Attach to workspace tmux session",
+		Long: `Attach to the tmux session for a workspace.
+
+With --layout, the session is arranged first: grid tiles the agent panes,
+and focus:<agent> selects that agent's pane and zooms it.`,
+		Example: `  swarm ws attach my-project
+  swarm ws attach my-project --layout grid
+  swarm ws attach my-project --layout focus:3f2a`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeWorkspaceNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			idOrName := args[0]
+
+			var layout *workspace.Layout
+			if flags.layout != "" {
+				parsed, err := workspace.ParseLayout(flags.layout)
+				if err != nil {
+					return invalidInputError("%v", err)
+				}
+				layout = &parsed
+			}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
 
-		// Find workspace
-		ws, err := findWorkspace(ctx, wsRepo, idOrName)
-		if err != nil {
-			return err
-		}
+			// Find workspace
+			ws, err := findWorkspace(ctx, wsRepo, idOrName)
+			if err != nil {
+				return err
+			}
 
-		step := startProgress("Preparing tmux attach")
-		attachCmd, err := wsService.AttachWorkspace(ctx, ws.ID)
-		if err != nil {
-			step.Fail(err)
-			return fmt.Errorf("failed to get attach command: %w", err)
-		}
-		step.Done()
+			step := opts.startProgress("Preparing tmux attach")
+			attachCmd, err := wsService.AttachWorkspace(ctx, ws.ID)
+			if err != nil {
+				step.Fail(err)
+				return wrapServiceError(err, "failed to get attach command")
+			}
+			if layout != nil {
+				if _, err := applyWorkspaceLayout(ctx, database, ws, *layout); err != nil {
+					step.Fail(err)
+					return err
+				}
+			}
+			step.Done()
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, map[string]string{
-				"workspace_id": ws.ID,
-				"command":      attachCmd,
-			})
-		}
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]string{
+					"workspace_id": ws.ID,
+					"command":      attachCmd,
+				})
+			}
+
+			return runAttachCommand(ctx, attachCmd)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.layout, "layout", "", "apply a layout before attaching: grid or focus:<agent>")
+	return cmd
+}
 
-		// Execute tmux attach
-		tmuxCmd := exec.CommandContext(ctx, "sh", "-c", attachCmd)
-		tmuxCmd.Stdin = os.Stdin
-		tmuxCmd.Stdout = os.Stdout
-		tmuxCmd.Stderr = os.Stderr
+// wsRemoveResult is the JSON output of 'swarm ws remove'.
+type wsRemoveResult struct {
+	bulkResult
+	Destroyed bool `json:"destroyed"`
+}
 
-		return tmuxCmd.Run()
-	},
+// wsRemoveFlags holds the flags of 'swarm ws remove'.
+type wsRemoveFlags struct {
+	force   bool
+	destroy bool
+	dryRun  bool
 }
 
-var wsRemoveCmd = &cobra.Command{
-	Use:     "remove <id-or-name>",
-	Aliases: []string{"rm", "delete"},
-	Short:   "Remove a workspace",
-	Long: `Remove a workspace from Swarm.
+func newWsRemoveCmd() *cobra.Command {
+	var flags wsRemoveFlags
+	cmd := &cobra.Command{
+		Use:     "remove <id-or-name>...",
+		Aliases: []string{"rm", "delete"},
+		Short:   "Remove workspaces",
+		Long: `Remove workspaces from Swarm.
 
 By default, this only removes the Swarm record. The tmux session is left running.
-Use --destroy to also kill the tmux session.`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		idOrName := args[0]
+Use --destroy to also kill the tmux session. --dry-run shows what would be
+removed without doing it, for a single workspace.
+
+Each workspace is removed on its own: one that fails does not stop the rest,
+and every workspace is reported with its outcome.`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeWorkspaceNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			if flags.dryRun && len(args) > 1 {
+				return invalidInputError("--dry-run takes a single workspace")
+			}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
 
-		// Find workspace
-		ws, err := findWorkspace(ctx, wsRepo, idOrName)
-		if err != nil {
-			return err
-		}
+			if flags.dryRun {
+				ws, err := findWorkspace(ctx, wsRepo, args[0])
+				if err != nil {
+					return err
+				}
+				if ws.AgentCount > 0 && !flags.force {
+					return conflictError("workspace has %d agents; use --force to remove anyway", ws.AgentCount)
+				}
+				var plan *workspace.RemovalPlan
+				if flags.destroy {
+					plan, err = wsService.DestroyWorkspace(ctx, ws.ID, true)
+				} else {
+					plan, err = wsService.UnmanageWorkspace(ctx, ws.ID, true)
+				}
+				if err != nil {
+					return wrapServiceError(err, "failed to plan workspace removal")
+				}
+				return writeRemovalPlan(opts, plan, ws.AgentCount, nil)
+			}
 
-		// Check for active agents
-		if ws.AgentCount > 0 && !wsRemoveForce {
-			return fmt.Errorf("workspace has %d agents; use --force to remove anyway", ws.AgentCount)
-		}
+			action := "remove"
+			if flags.destroy {
+				action = "destroy"
+			}
+			result := &bulkResult{}
 
-		// Confirm destructive action
-		var impact string
-		if wsRemoveDestroy {
-			impact = "This will remove the workspace record and kill the tmux session."
-		} else {
-			impact = "This will remove the workspace record. The tmux session will be left running."
-		}
-		if ws.AgentCount > 0 {
-			impact += fmt.Sprintf(" %d agent(s) will be orphaned.", ws.AgentCount)
-		}
-		if !ConfirmDestructiveAction("workspace", ws.Name, impact) {
-			fmt.Fprintln(os.Stderr, "Cancelled.")
-			return nil
-		}
+			// Resolve every workspace first, so one confirmation covers them.
+			var targets []*models.Workspace
+			orphaned := 0
+			for _, idOrName := range args {
+				ws, err := findWorkspace(ctx, wsRepo, idOrName)
+				if err != nil {
+					result.record(bulkItem{ID: idOrName, Name: idOrName, Action: action}, err)
+					continue
+				}
+				if ws.AgentCount > 0 && !flags.force {
+					result.record(bulkItem{ID: ws.ID, Name: ws.Name, Action: action},
+						conflictError("workspace has %d agents; use --force to remove anyway", ws.AgentCount))
+					continue
+				}
+				targets = append(targets, ws)
+				orphaned += ws.AgentCount
+			}
 
-		if wsRemoveDestroy {
-			if err := wsService.DestroyWorkspace(ctx, ws.ID); err != nil {
-				return fmt.Errorf("failed to destroy workspace: %w", err)
+			if len(targets) > 0 {
+				var impact string
+				if flags.destroy {
+					impact = "This will remove the workspace record and kill the tmux session."
+				} else {
+					impact = "This will remove the workspace record. The tmux session will be left running."
+				}
+				if orphaned > 0 {
+					impact += fmt.Sprintf(" %d agent(s) will be orphaned.", orphaned)
+				}
+				names := make([]string, 0, len(targets))
+				for _, ws := range targets {
+					names = append(names, ws.Name)
+				}
+				if !opts.ConfirmDestructiveAction("workspace", strings.Join(names, ", "), impact) {
+					fmt.Fprintln(opts.Err, "Cancelled.")
+					return nil
+				}
 			}
-		} else {
-			if err := wsService.UnmanageWorkspace(ctx, ws.ID); err != nil {
-				return fmt.Errorf("failed to remove workspace: %w", err)
+
+			for _, ws := range targets {
+				if flags.destroy {
+					_, err = wsService.DestroyWorkspace(ctx, ws.ID, false)
+				} else {
+					_, err = wsService.UnmanageWorkspace(ctx, ws.ID, false)
+				}
+				if err != nil {
+					err = wrapServiceError(err, "failed to %s workspace", action)
+				}
+				result.record(bulkItem{ID: ws.ID, Name: ws.Name, Action: action}, err)
 			}
-		}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, map[string]any{
-				"removed":      true,
-				"workspace_id": ws.ID,
-				"name":         ws.Name,
-				"destroyed":    wsRemoveDestroy,
-			})
-		}
+			if opts.Structured() {
+				if err := opts.WriteOutput(wsRemoveResult{bulkResult: *result, Destroyed: flags.destroy}); err != nil {
+					return err
+				}
+				return result.Err()
+			}
 
-		if wsRemoveDestroy {
-			fmt.Printf("Workspace '%s' destroyed (tmux session killed)\n", ws.Name)
-		} else {
-			fmt.Printf("Workspace '%s' removed (tmux session left running)\n", ws.Name)
-		}
+			if err := result.writeTable(opts); err != nil {
+				return err
+			}
+			if result.Succeeded > 0 && !flags.destroy {
+				fmt.Fprintln(opts.Out, "tmux sessions were left running; use --destroy to kill them.")
+			}
+			return result.Err()
+		},
+	}
 
-		return nil
-	},
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "force removal even with active agents")
+	cmd.Flags().BoolVar(&flags.destroy, "destroy", false, "also kill the tmux session")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "show what would be removed without doing it")
+	return cmd
 }
 
-var wsKillCmd = &cobra.Command{
-	Use:     "kill <id-or-name>",
-	Aliases: []string{"destroy"},
-	Short:   "Destroy a workspace",
-	Long: `Destroy a workspace by terminating agents, killing the tmux session,
-and removing the Swarm record.`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		idOrName := args[0]
-
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+// wsKillResult is the JSON output of 'swarm ws kill'.
+type wsKillResult struct {
+	bulkResult
+	Destroyed    bool   `json:"destroyed"`
+	WorkspaceID  string `json:"workspace_id"`
+	Name         string `json:"name"`
+	AgentsKilled int    `json:"agents_killed"`
+}
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		queueRepo := db.NewQueueRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+// wsKillFlags holds the flags of 'swarm ws kill'.
+type wsKillFlags struct {
+	force  bool
+	dryRun bool
+}
 
-		tmuxClient := tmux.NewLocalClient()
-		agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+func newWsKillCmd() *cobra.Command {
+	var flags wsKillFlags
+	cmd := &cobra.Command{
+		Use:     "kill <id-or-name>",
+		Aliases: []string{"destroy"},
+		Short:   "Destroy a workspace",
+		Long: `Destroy a workspace by terminating agents, killing the tmux session,
+and removing the Swarm record. --dry-run shows the agents, panes, and
+session that would be killed without doing it.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeWorkspaceNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+			idOrName := args[0]
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
 
-		ws, err := findWorkspace(ctx, wsRepo, idOrName)
-		if err != nil {
-			return err
-		}
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
 
-		agents, err := agentRepo.ListByWorkspace(ctx, ws.ID)
-		if err != nil {
-			return fmt.Errorf("failed to list agents: %w", err)
-		}
+			tmuxClient := tmux.NewLocalClient()
+			agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
 
-		if len(agents) > 0 && !wsKillForce {
-			return fmt.Errorf("workspace has %d agents; use --force to kill anyway", len(agents))
-		}
+			ws, err := findWorkspace(ctx, wsRepo, idOrName)
+			if err != nil {
+				return err
+			}
 
-		// Confirm destructive action
-		impact := "This will terminate all agents, kill the tmux session, and remove the workspace."
-		if len(agents) > 0 {
-			impact = fmt.Sprintf("This will terminate %d agent(s), kill the tmux session, and remove the workspace.", len(agents))
-		}
-		if !ConfirmDestructiveAction("workspace", ws.Name, impact) {
-			fmt.Fprintln(os.Stderr, "Cancelled.")
-			return nil
-		}
+			agents, err := agentRepo.ListByWorkspace(ctx, ws.ID)
+			if err != nil {
+				return wrapServiceError(err, "failed to list agents")
+			}
 
-		for _, agentRecord := range agents {
-			if err := agentService.TerminateAgent(ctx, agentRecord.ID); err != nil {
-				return fmt.Errorf("failed to terminate agent %s: %w", agentRecord.ID, err)
+			if len(agents) > 0 && !flags.force {
+				return conflictError("workspace has %d agents; use --force to kill anyway", len(agents))
 			}
-		}
 
-		if err := wsService.DestroyWorkspace(ctx, ws.ID); err != nil {
-			return fmt.Errorf("failed to destroy workspace: %w", err)
-		}
+			if flags.dryRun {
+				agentPlans := make([]*agent.TerminatePlan, 0, len(agents))
+				for _, agentRecord := range agents {
+					plan, err := agentService.TerminateAgent(ctx, agentRecord.ID, agent.TerminateOptions{DryRun: true})
+					if err != nil {
+						return wrapServiceError(err, "failed to plan termination of agent %s", agentRecord.ID)
+					}
+					agentPlans = append(agentPlans, plan)
+				}
+				plan, err := wsService.DestroyWorkspace(ctx, ws.ID, true)
+				if err != nil {
+					return wrapServiceError(err, "failed to plan workspace destroy")
+				}
+				return writeRemovalPlan(opts, plan, len(agents), agentPlans)
+			}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, map[string]any{
-				"destroyed":     true,
-				"workspace_id":  ws.ID,
-				"name":          ws.Name,
-				"agents_killed": len(agents),
-			})
-		}
+			// Confirm destructive action
+			impact := "This will terminate all agents, kill the tmux session, and remove the workspace."
+			if len(agents) > 0 {
+				impact = fmt.Sprintf("This will terminate %d agent(s), kill the tmux session, and remove the workspace.", len(agents))
+			}
+			if !opts.ConfirmDestructiveAction("workspace", ws.Name, impact) {
+				fmt.Fprintln(opts.Err, "Cancelled.")
+				return nil
+			}
 
-		fmt.Printf("Workspace '%s' destroyed (killed %d agents)\n", ws.Name, len(agents))
-		return nil
-	},
-}
+			result, err := terminateAgents(ctx, opts, agentService, agents, agent.TerminateOptions{})
+			if err == nil {
+				err = ctx.Err()
+			}
+			if err != nil {
+				if len(agents) > 0 && !opts.JSON && !opts.JSONL {
+					fmt.Fprintf(opts.Err, "Terminated %d/%d agents; workspace '%s' was kept.\n", result.Succeeded, len(agents), ws.Name)
+				}
+				return err
+			}
 
-var wsRefreshCmd = &cobra.Command{
-	Use:   "refresh [id-or-name]",
-	Short: "Refresh workspace git info",
-	Long:  "Refresh git information for a workspace or all workspaces.",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+			// A workspace is only destroyed once all its agents are gone.
+			wsItem := bulkItem{ID: ws.ID, Name: ws.Name, Action: "destroy"}
+			killed := result.Succeeded
+			if result.Failed > 0 {
+				result.skip(wsItem, "agents still running")
+			} else {
+				_, err = wsService.DestroyWorkspace(ctx, ws.ID, false)
+				if err != nil {
+					err = wrapServiceError(err, "failed to destroy workspace")
+				}
+				result.record(wsItem, err)
+			}
 
-		database, err := openDatabase()
-		if err != nil {
-			return err
-		}
-		defer database.Close()
+			if opts.Structured() {
+				if err := opts.WriteOutput(wsKillResult{
+					bulkResult:   *result,
+					Destroyed:    result.ok(),
+					WorkspaceID:  ws.ID,
+					Name:         ws.Name,
+					AgentsKilled: killed,
+				}); err != nil {
+					return err
+				}
+				return result.Err()
+			}
 
-		nodeRepo := db.NewNodeRepository(database)
-		nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
-		wsRepo := db.NewWorkspaceRepository(database)
-		agentRepo := db.NewAgentRepository(database)
-		wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)))
+			if err := result.writeTable(opts); err != nil {
+				return err
+			}
+			return result.Err()
+		},
+	}
 
-		var workspaces []*models.Workspace
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "force kill even with active agents")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "show what would be killed without doing it")
+	return cmd
+}
 
-		if len(args) > 0 {
-			ws, err := findWorkspace(ctx, wsRepo, args[0])
+func newWsRefreshCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "refresh [id-or-name]",
+		Short:             "Refresh workspace git info",
+		Long:              "Refresh git information for a workspace or all workspaces.",
+		ValidArgsFunction: completeWorkspaceNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
 			if err != nil {
 				return err
 			}
-			workspaces = []*models.Workspace{ws}
-		} else {
-			list, err := wsService.ListWorkspaces(ctx, workspace.ListWorkspacesOptions{})
-			if err != nil {
-				return fmt.Errorf("failed to list workspaces: %w", err)
-			}
-			workspaces = list
-		}
+			defer database.Close()
 
-		results := make([]map[string]any, 0, len(workspaces))
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
 
-		for _, ws := range workspaces {
-			gitInfo, err := wsService.RefreshGitInfo(ctx, ws.ID)
-			r := map[string]any{
-				"workspace_id": ws.ID,
-				"name":         ws.Name,
-			}
-			if err != nil {
-				r["error"] = err.Error()
+			var workspaces []*models.Workspace
+
+			if len(args) > 0 {
+				ws, err := findWorkspace(ctx, wsRepo, args[0])
+				if err != nil {
+					return err
+				}
+				workspaces = []*models.Workspace{ws}
 			} else {
-				r["git_info"] = gitInfo
+				list, err := wsService.ListWorkspaces(ctx, workspace.ListWorkspacesOptions{})
+				if err != nil {
+					return wrapServiceError(err, "failed to list workspaces")
+				}
+				workspaces = list
 			}
-			results = append(results, r)
 
-			if !IsJSONOutput() && !IsJSONLOutput() {
+			results := make([]map[string]any, 0, len(workspaces))
+
+			for _, ws := range workspaces {
+				gitInfo, err := wsService.RefreshGitInfo(ctx, ws.ID)
+				r := map[string]any{
+					"workspace_id": ws.ID,
+					"name":         ws.Name,
+				}
 				if err != nil {
-					fmt.Printf("%s: error - %v\n", ws.Name, err)
+					r["error"] = err.Error()
 				} else {
-					fmt.Printf("%s: %s\n", ws.Name, gitInfo.Branch)
+					r["git_info"] = gitInfo
+				}
+				results = append(results, r)
+
+				if !opts.JSON && !opts.JSONL {
+					if err != nil {
+						fmt.Fprintf(opts.Out, "%s: error - %v\n", ws.Name, err)
+					} else {
+						fmt.Fprintf(opts.Out, "%s: %s\n", ws.Name, gitInfo.Branch)
+					}
 				}
 			}
-		}
 
-		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, results)
-		}
+			if opts.Structured() {
+				return opts.WriteOutput(results)
+			}
 
-		return nil
-	},
+			return nil
+		},
+	}
+	return cmd
 }
 
 func beadsStatusRank(status string) int {
@@ -829,7 +1217,7 @@ func summarizeBeadsCounts(tasks []beads.TaskSummary) (map[string]int, map[int]in
 
 func promptRepoRootSelection(roots []string) (string, error) {
 	if len(roots) == 0 {
-		return "", errors.New("no repository roots to select")
+		return "", invalidInputError("no repository roots to select")
 	}
 
 	fmt.Fprintln(os.Stderr, "Multiple repository roots detected. Select one:")
@@ -847,20 +1235,12 @@ func promptRepoRootSelection(roots []string) (string, error) {
 	choice := strings.TrimSpace(line)
 	index, err := strconv.Atoi(choice)
 	if err != nil || index < 1 || index > len(roots) {
-		return "", fmt.Errorf("invalid selection %q", choice)
+		return "", invalidInputError("invalid selection %q", choice)
 	}
 
 	return roots[index-1], nil
 }
 
-// truncatePath truncates a path for display.
-func truncatePath(path string, maxLen int) string {
-	if len(path) <= maxLen {
-		return path
-	}
-	return "..." + path[len(path)-maxLen+3:]
-}
-
 // truncate truncates a string.
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -868,3 +1248,26 @@ func truncate(s string, maxLen int) string {
 	}
 	return s[:maxLen] + "..."
 }
+
+// wsFilterFields are the fields ws list --filter can compare.
+var wsFilterFields = filterFields{
+	"name":   filterText,
+	"id":     filterText,
+	"node":   filterText,
+	"status": filterText,
+	"path":   filterText,
+	"agents": filterNumber,
+	"age":    filterAge,
+}
+
+func wsFilterRecord(ws *models.Workspace, nodeName string) filterRecord {
+	r := newFilterRecord()
+	r.setText("name", ws.Name)
+	r.setText("id", ws.ID)
+	r.setText("node", ws.NodeID, nodeName)
+	r.setText("status", string(ws.Status))
+	r.setText("path", ws.RepoPath)
+	r.numbers["agents"] = float64(ws.AgentCount)
+	r.setTime("age", &ws.CreatedAt)
+	return r
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// wsBootstrapFlags holds the flags of 'swarm ws bootstrap'.
+type wsBootstrapFlags struct {
+	overwrite bool
+}
+
+func newWsBootstrapCmd() *cobra.Command {
+	var flags wsBootstrapFlags
+	cmd := &cobra.Command{
+		Use:   "bootstrap <id-or-name>",
+		Short: "Write the configured bootstrap files into a workspace's repo",
+		Long: `Write workspace_defaults.bootstrap_files into the workspace's repository.
+
+Each file is a Go template rendered with the workspace's .ID, .Name, .Path,
+.Branch and .Node, so an AGENTS.md or prompt scaffold can name the
+workspace it belongs to. Files that already exist are left alone unless
+--overwrite is given. ws create --bootstrap does the same right after
+creating a workspace.`,
+		Example: `  swarm ws bootstrap my-project
+  swarm ws bootstrap my-project --overwrite --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			files, err := bootstrapFileInputs(GetConfig())
+			if err != nil {
+				return err
+			}
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			wsService, _ := newWorkspacePauseServices(database)
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+
+			result, err := bootstrapWorkspace(ctx, opts, wsService, ws, files, flags.overwrite)
+			if err != nil {
+				return err
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(result)
+			}
+			fmt.Fprintf(opts.Out, "Workspace '%s' bootstrapped:\n", ws.Name)
+			printBootstrapFiles(opts.Out, result)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.overwrite, "overwrite", false, "replace files that already exist in the repo")
+	return cmd
+}
+
+// bootstrapFileInputs loads the configured bootstrap files, reading each
+// source file; relative sources are resolved against global.config_dir.
+// No files configured is an invalid input.
+func bootstrapFileInputs(cfg *config.Config) ([]workspace.BootstrapFile, error) {
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	configured := cfg.WorkspaceDefaults.BootstrapFiles
+	if len(configured) == 0 {
+		return nil, invalidInputError("no bootstrap files configured (set workspace_defaults.bootstrap_files)")
+	}
+
+	files := make([]workspace.BootstrapFile, 0, len(configured))
+	for _, f := range configured {
+		content := f.Content
+		if f.Source != "" {
+			source := f.Source
+			if !filepath.IsAbs(source) {
+				source = filepath.Join(cfg.Global.ConfigDir, source)
+			}
+			data, err := os.ReadFile(source)
+			if err != nil {
+				return nil, invalidInputError("failed to read bootstrap source for %s: %v", f.Path, err)
+			}
+			content = string(data)
+		}
+		files = append(files, workspace.BootstrapFile{Path: f.Path, Content: content})
+	}
+	return files, nil
+}
+
+// bootstrapWorkspace writes files into ws's repo as a progress step.
+func bootstrapWorkspace(ctx context.Context, opts Options, wsService *workspace.Service, ws *models.Workspace, files []workspace.BootstrapFile, overwrite bool) (*models.WorkspaceBootstrap, error) {
+	step := opts.startProgress(fmt.Sprintf("Writing %d bootstrap files", len(files)))
+	result, err := wsService.BootstrapWorkspace(ctx, ws.ID, files, overwrite)
+	if err != nil {
+		step.Fail(err)
+		if errors.Is(err, workspace.ErrBootstrapPathEscapes) || errors.Is(err, workspace.ErrNoBootstrapFiles) {
+			return nil, invalidInputError("%v", err)
+		}
+		return nil, wrapServiceError(err, "failed to bootstrap workspace")
+	}
+	step.Done()
+	return result, nil
+}
+
+func printBootstrapFiles(out io.Writer, result *models.WorkspaceBootstrap) {
+	for _, f := range result.Files {
+		note := ""
+		if f.Status == models.BootstrapFileSkipped {
+			note = " (already exists)"
+		}
+		fmt.Fprintf(out, "  %-12s %s%s\n", f.Status, f.Path, note)
+	}
+}
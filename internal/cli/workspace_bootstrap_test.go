@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestWorkspaceBootstrap(t *testing.T) {
+	database := useTestDatabase(t)
+	a := seedQueueAgent(t, database)
+	captureProgress(t)
+	ctx := context.Background()
+
+	wsRepo := db.NewWorkspaceRepository(database)
+	ws, err := wsRepo.Get(ctx, a.WorkspaceID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	ws.RepoPath = t.TempDir()
+	if err := wsRepo.Update(ctx, ws); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	configDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(configDir, "start.md"), []byte("Start in {{.Path}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	previous := appConfig
+	appConfig = config.DefaultConfig()
+	appConfig.Global.ConfigDir = configDir
+	t.Cleanup(func() { appConfig = previous })
+
+	if _, err := runCommand(t, newWsBootstrapCmd(), ws.ID); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input without bootstrap files, got %v", err)
+	}
+
+	appConfig.WorkspaceDefaults.BootstrapFiles = []config.BootstrapFileConfig{
+		{Path: "AGENTS.md", Content: "# {{.Name}}"},
+		{Path: "prompts/start.md", Source: "start.md"},
+	}
+	if code, err := runCommand(t, newWsBootstrapCmd(), ws.ID); err != nil {
+		t.Fatalf("ws bootstrap exited %d: %v", code, err)
+	}
+	data, err := os.ReadFile(filepath.Join(ws.RepoPath, "prompts", "start.md"))
+	if err != nil || string(data) != "Start in "+ws.RepoPath {
+		t.Fatalf("expected the source rendered into the repo, got %q (%v)", data, err)
+	}
+
+	stored, err := wsRepo.Get(ctx, ws.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if stored.Bootstrap == nil || len(stored.Bootstrap.Files) != 2 || stored.Bootstrap.Files[0].Status != models.BootstrapFileWritten {
+		t.Fatalf("expected the bootstrap recorded, got %+v", stored.Bootstrap)
+	}
+
+	appConfig.WorkspaceDefaults.BootstrapFiles[1].Source = "missing.md"
+	if _, err := runCommand(t, newWsBootstrapCmd(), ws.ID); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input for a missing source, got %v", err)
+	}
+}
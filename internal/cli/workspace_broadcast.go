@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/queue"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+// wsBroadcastFlags holds the flags of 'swarm ws broadcast'.
+type wsBroadcastFlags struct {
+	message  string
+	template bool
+	state    string
+	tags     []string
+	queue    bool
+	direct   bool
+}
+
+func newWsBroadcastCmd() *cobra.Command {
+	var flags wsBroadcastFlags
+	cmd := &cobra.Command{
+		Use:   "broadcast <id-or-name>",
+		Short: "Send a message to every agent in a workspace",
+		Long: `Send the same message to every agent in a workspace, optionally
+narrowed by state and tags.
+
+By default one queue item is enqueued per agent, so the scheduler delivers
+it when the agent is idle. With --direct the message is sent to the pane
+right away; agents that are not idle are reported as failed.
+
+With --template the message is rendered per agent as a Go template with
+these fields:
+
+  {{.AgentName}}  the agent's short ID
+  {{.AgentID}}    the agent's full ID
+  {{.AgentType}}  the agent type (claude-code, codex, ...)
+  {{.Tags}}       the agent's tags, comma-separated
+
+One agent failing does not stop the others; each one's result is reported.`,
+		Example: `  swarm ws broadcast my-project --message "Pull latest main before continuing"
+  swarm ws broadcast my-project --state idle --tag backend \
+    --template --message "{{.AgentName}} ({{.AgentType}}): rebase onto main"
+  swarm ws broadcast my-project --direct --message "Commit your work"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			if strings.TrimSpace(flags.message) == "" {
+				return invalidInputError("--message must not be empty")
+			}
+			filter := broadcastFilter{
+				State: models.AgentState(strings.TrimSpace(flags.state)),
+				Tags:  flags.tags,
+			}
+			msg, err := parseBroadcastMessage(flags.message, flags.template)
+			if err != nil {
+				return invalidInputError("%v", err)
+			}
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+			agentRepo := db.NewAgentRepository(database)
+			agents, err := agentRepo.ListByWorkspace(ctx, ws.ID)
+			if err != nil {
+				return wrapServiceError(err, "failed to list agents")
+			}
+			targets := filter.Select(agents)
+			if len(targets) == 0 {
+				return notFoundError("no agents in workspace '%s' match the filters", ws.Name)
+			}
+
+			var deliver func(a *models.Agent, message string) broadcastResult
+			if flags.direct {
+				wsService, _ := newWorkspacePauseServices(database)
+				agentService := agent.NewService(agentRepo, db.NewQueueRepository(database), wsService, nil, tmux.NewLocalClient(), agentServiceOptions(database)...)
+				deliver = func(a *models.Agent, message string) broadcastResult {
+					if err := agentService.SendMessage(ctx, a.ID, message, nil); err != nil {
+						return broadcastResult{Status: broadcastFailed, Error: err.Error()}
+					}
+					return broadcastResult{Status: broadcastSent}
+				}
+			} else {
+				queueRepo := db.NewQueueRepository(database)
+				queueService := queue.NewService(queueRepo, queue.WithAuditRecorder(newAuditRecorder(database)))
+				deliver = func(a *models.Agent, message string) broadcastResult {
+					r := enqueueMessage(ctx, queueService, queueRepo, a, message, queueOptions{})
+					if r.Error != "" {
+						return broadcastResult{Status: broadcastFailed, Error: r.Error}
+					}
+					return broadcastResult{Status: broadcastQueued, ItemID: r.ItemID}
+				}
+			}
+
+			results := make([]broadcastResult, 0, len(targets))
+			for _, a := range targets {
+				message, err := msg.Render(a)
+				var result broadcastResult
+				if err != nil {
+					result = broadcastResult{Status: broadcastFailed, Error: err.Error()}
+				} else {
+					result = deliver(a, message)
+				}
+				result.AgentID = a.ID
+				result.AgentType = a.Type
+				results = append(results, result)
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{
+					"workspace_id": ws.ID,
+					"direct":       flags.direct,
+					"results":      results,
+				})
+			}
+
+			rows := make([][]string, 0, len(results))
+			failed := 0
+			for _, r := range results {
+				if r.Status == broadcastFailed {
+					failed++
+				}
+				rows = append(rows, []string{shortID(r.AgentID), string(r.AgentType), string(r.Status), r.Error})
+			}
+			if err := opts.writeTable([]string{"AGENT", "TYPE", "RESULT", "ERROR"}, rows); err != nil {
+				return err
+			}
+			fmt.Fprintf(opts.Out, "\n%d of %d agents in '%s' reached", len(results)-failed, len(results), ws.Name)
+			if failed > 0 {
+				fmt.Fprintf(opts.Out, ", %d failed", failed)
+			}
+			fmt.Fprintln(opts.Out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.message, "message", "m", "", "message to send to each agent")
+	cmd.Flags().BoolVar(&flags.template, "template", false, "render the message per agent as a Go template")
+	cmd.Flags().StringVar(&flags.state, "state", "", "only agents in this state (idle, working, etc.)")
+	cmd.Flags().StringSliceVar(&flags.tags, "tag", nil, "only agents with this tag (repeatable; all must match)")
+	cmd.Flags().BoolVar(&flags.queue, "queue", false, "enqueue one item per agent for the scheduler (default)")
+	cmd.Flags().BoolVar(&flags.direct, "direct", false, "send to idle agents immediately instead of queueing")
+	_ = cmd.MarkFlagRequired("message")
+	cmd.MarkFlagsMutuallyExclusive("queue", "direct")
+	return cmd
+}
+
+// broadcastStatus is the outcome of a broadcast for one agent.
+type broadcastStatus string
+
+const (
+	broadcastQueued broadcastStatus = "queued"
+	broadcastSent   broadcastStatus = "sent"
+	broadcastFailed broadcastStatus = "failed"
+)
+
+type broadcastResult struct {
+	AgentID   string           `json:"agent_id"`
+	AgentType models.AgentType `json:"agent_type"`
+	Status    broadcastStatus  `json:"status"`
+	ItemID    string           `json:"item_id,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// broadcastFilter narrows a workspace's agents to the broadcast targets.
+type broadcastFilter struct {
+	// State keeps only agents in this state; empty keeps all.
+	State models.AgentState
+	// Tags keeps only agents carrying every tag.
+	Tags []string
+}
+
+// Select returns the live agents that match the filter, in input order.
+func (f broadcastFilter) Select(agents []*models.Agent) []*models.Agent {
+	var out []*models.Agent
+	for _, a := range agents {
+		if a.IsTerminated() {
+			continue
+		}
+		if f.State != "" && a.State != f.State {
+			continue
+		}
+		if !a.HasTags(f.Tags) {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// broadcastTemplateData is everything a broadcast template can see.
+type broadcastTemplateData struct {
+	AgentName string
+	AgentID   string
+	AgentType models.AgentType
+	Tags      string
+}
+
+// broadcastMessage is a broadcast message, rendered per agent when it is
+// a template.
+type broadcastMessage struct {
+	text string
+	tmpl *template.Template
+}
+
+// parseBroadcastMessage parses text as a template when asTemplate is set.
+// The template gets no functions, and unknown fields fail to render.
+func parseBroadcastMessage(text string, asTemplate bool) (*broadcastMessage, error) {
+	msg := &broadcastMessage{text: text}
+	if !asTemplate {
+		return msg, nil
+	}
+	tmpl, err := template.New("broadcast").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message template: %w", err)
+	}
+	msg.tmpl = tmpl
+	return msg, nil
+}
+
+// Render returns the message for a.
+func (m *broadcastMessage) Render(a *models.Agent) (string, error) {
+	if m.tmpl == nil {
+		return m.text, nil
+	}
+	var out strings.Builder
+	err := m.tmpl.Execute(&out, broadcastTemplateData{
+		AgentName: shortID(a.ID),
+		AgentID:   a.ID,
+		AgentType: a.Type,
+		Tags:      strings.Join(a.Metadata.Tags, ","),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render message: %w", err)
+	}
+	if strings.TrimSpace(out.String()) == "" {
+		return "", errors.New("rendered message is empty")
+	}
+	return out.String(), nil
+}
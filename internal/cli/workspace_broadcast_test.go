@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestBroadcastFilterSelect(t *testing.T) {
+	deleted := time.Now()
+	agents := []*models.Agent{
+		{ID: "a", State: models.AgentStateIdle, Metadata: models.AgentMetadata{Tags: []string{"backend", "api"}}},
+		{ID: "b", State: models.AgentStateWorking, Metadata: models.AgentMetadata{Tags: []string{"backend"}}},
+		{ID: "c", State: models.AgentStateIdle, Metadata: models.AgentMetadata{Tags: []string{"frontend"}}},
+		{ID: "d", State: models.AgentStateIdle, Metadata: models.AgentMetadata{Tags: []string{"backend"}}, DeletedAt: &deleted},
+	}
+
+	tests := []struct {
+		name   string
+		filter broadcastFilter
+		want   string
+	}{
+		{name: "no filter", filter: broadcastFilter{}, want: "abc"},
+		{name: "state", filter: broadcastFilter{State: models.AgentStateIdle}, want: "ac"},
+		{name: "tag", filter: broadcastFilter{Tags: []string{" Backend "}}, want: "ab"},
+		{name: "all tags", filter: broadcastFilter{Tags: []string{"backend", "api"}}, want: "a"},
+		{name: "state and tag", filter: broadcastFilter{State: models.AgentStateWorking, Tags: []string{"backend"}}, want: "b"},
+		{name: "no match", filter: broadcastFilter{Tags: []string{"ops"}}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got strings.Builder
+			for _, a := range tt.filter.Select(agents) {
+				got.WriteString(a.ID)
+			}
+			if got.String() != tt.want {
+				t.Fatalf("Select() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestBroadcastMessageRender(t *testing.T) {
+	a := &models.Agent{ID: "0123456789abcdef", Type: models.AgentTypeCodex, Metadata: models.AgentMetadata{Tags: []string{"backend", "api"}}}
+
+	plain, err := parseBroadcastMessage("hi {{.AgentName}}", false)
+	if err != nil {
+		t.Fatalf("parse plain: %v", err)
+	}
+	if got, _ := plain.Render(a); got != "hi {{.AgentName}}" {
+		t.Fatalf("expected a plain message to be sent as is, got %q", got)
+	}
+
+	msg, err := parseBroadcastMessage("{{.AgentName}} ({{.AgentType}}, {{.Tags}}): pull main", true)
+	if err != nil {
+		t.Fatalf("parse template: %v", err)
+	}
+	got, err := msg.Render(a)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if want := shortID(a.ID) + " (codex, backend,api): pull main"; got != want {
+		t.Fatalf("Render() = %q, want %q", got, want)
+	}
+
+	if _, err := parseBroadcastMessage("{{.AgentName", true); err == nil {
+		t.Fatal("expected a parse error")
+	}
+	if _, err := parseBroadcastMessage(`{{env "HOME"}}`, true); err == nil {
+		t.Fatal("expected functions to be unavailable")
+	}
+	unknown, _ := parseBroadcastMessage("{{.Account}}", true)
+	if _, err := unknown.Render(a); err == nil {
+		t.Fatal("expected an unknown field to fail")
+	}
+	empty, _ := parseBroadcastMessage(`{{if false}}x{{end}}`, true)
+	if _, err := empty.Render(a); err == nil {
+		t.Fatal("expected an empty rendered message to fail")
+	}
+}
+
+func TestWorkspaceBroadcastQueuesPerAgent(t *testing.T) {
+	ctx := context.Background()
+	database := useTestDatabase(t)
+	seeded := seedQueueAgent(t, database)
+	agentRepo := db.NewAgentRepository(database)
+	ws, err := db.NewWorkspaceRepository(database).Get(ctx, seeded.WorkspaceID)
+	if err != nil {
+		t.Fatalf("get workspace: %v", err)
+	}
+
+	seeded.Metadata.Tags = []string{"backend"}
+	if err := agentRepo.Update(ctx, seeded); err != nil {
+		t.Fatalf("update agent: %v", err)
+	}
+	backendBusy := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeCodex, TmuxPane: "swarm-repo:0.2", State: models.AgentStateWorking,
+		Metadata: models.AgentMetadata{Tags: []string{"backend"}}}
+	frontend := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeClaudeCode, TmuxPane: "swarm-repo:0.3", State: models.AgentStateIdle,
+		Metadata: models.AgentMetadata{Tags: []string{"frontend"}}}
+	for _, a := range []*models.Agent{backendBusy, frontend} {
+		if err := agentRepo.Create(ctx, a); err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+	}
+
+	out := runJSONCommand(t, newWsBroadcastCmd(), ws.ID, "--tag", "backend", "--template", "--message", "{{.AgentType}}: pull latest main")
+	var resp struct {
+		Results []broadcastResult `json:"results"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected results for the two backend agents, got %+v", resp.Results)
+	}
+	for _, r := range resp.Results {
+		if r.Status != broadcastQueued || r.ItemID == "" {
+			t.Fatalf("expected a queued item, got %+v", r)
+		}
+	}
+
+	queueRepo := db.NewQueueRepository(database)
+	for _, tc := range []struct {
+		agent *models.Agent
+		want  string
+	}{
+		{seeded, "opencode: pull latest main"},
+		{backendBusy, "codex: pull latest main"},
+		{frontend, ""},
+	} {
+		items, err := queueRepo.List(ctx, tc.agent.ID)
+		if err != nil {
+			t.Fatalf("list queue: %v", err)
+		}
+		if tc.want == "" {
+			if len(items) != 0 {
+				t.Fatalf("expected no items for excluded agent %s, got %d", tc.agent.ID, len(items))
+			}
+			continue
+		}
+		if len(items) != 1 {
+			t.Fatalf("expected one item for %s, got %d", tc.agent.ID, len(items))
+		}
+		payload, err := items[0].GetMessagePayload()
+		if err != nil || payload.Text != tc.want {
+			t.Fatalf("expected message %q, got %+v (%v)", tc.want, payload, err)
+		}
+	}
+
+	// One agent refusing the item does not stop the rest.
+	if err := agentRepo.SetDraining(ctx, backendBusy.ID, true); err != nil {
+		t.Fatalf("update agent: %v", err)
+	}
+	out = runJSONCommand(t, newWsBroadcastCmd(), ws.ID, "--tag", "backend", "--message", "again")
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	statuses := map[string]broadcastStatus{}
+	for _, r := range resp.Results {
+		statuses[r.AgentID] = r.Status
+	}
+	if statuses[seeded.ID] != broadcastQueued || statuses[backendBusy.ID] != broadcastFailed {
+		t.Fatalf("expected one queued and one failed, got %+v", resp.Results)
+	}
+
+	if code, _ := runCommand(t, newWsBroadcastCmd(), ws.ID, "--tag", "ops", "--message", "hi"); code != ExitCodeNotFound {
+		t.Fatalf("expected not found when no agent matches, got exit %d", code)
+	}
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// wsCloneFlags holds the flags of 'swarm ws clone'.
+type wsCloneFlags struct {
+	path       string
+	worktree   string
+	name       string
+	session    string
+	noTmux     bool
+	withAgents bool
+}
+
+func newWsCloneCmd() *cobra.Command {
+	var flags wsCloneFlags
+	cmd := &cobra.Command{
+		Use:   "clone <source-workspace>",
+		Short: "Duplicate a workspace into a new directory or worktree",
+		Long: `Create a new workspace from an existing one.
+
+The clone lives on the same node as the source. Use --path to point at an
+existing checkout, or --worktree to create a git worktree of the source repo
+(placed next to it unless --path is given).
+
+With --with-agents, each agent in the source workspace is spawned again in
+the clone with the same type, account, approval policy, and environment.
+Runtime state and queued messages are not copied.`,
+		Example: `  # Clone into an existing checkout
+  swarm ws clone my-project --path /home/user/my-project-copy
+
+  # Clone into a new worktree on branch experiment
+  swarm ws clone my-project --worktree experiment
+
+  # Clone and re-spawn the same agents
+  swarm ws clone my-project --worktree experiment --with-agents --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			if err := validateWsCloneFlags(flags.path, flags.worktree); err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			nodeRepo := db.NewNodeRepository(database)
+			nodeService := node.NewService(nodeRepo, node.WithPublisher(newEventPublisher(database)))
+			wsRepo := db.NewWorkspaceRepository(database)
+			agentRepo := db.NewAgentRepository(database)
+			queueRepo := db.NewQueueRepository(database)
+			eventRepo := db.NewEventRepository(database)
+			wsService := workspace.NewService(wsRepo, nodeService, agentRepo, workspace.WithEventRepository(eventRepo), workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
+
+			source, err := findWorkspace(ctx, wsRepo, args[0])
+			if err != nil {
+				return err
+			}
+
+			step := opts.startProgress("Cloning workspace")
+			clone, err := wsService.CloneWorkspace(ctx, workspace.CloneWorkspaceInput{
+				SourceID:          source.ID,
+				RepoPath:          flags.path,
+				WorktreeBranch:    flags.worktree,
+				Name:              flags.name,
+				TmuxSession:       flags.session,
+				CreateTmuxSession: !flags.noTmux,
+			})
+			if err != nil {
+				step.Fail(err)
+				if errors.Is(err, workspace.ErrWorkspaceAlreadyExists) {
+					return fmt.Errorf("target is already a workspace: %w", err)
+				}
+				if errors.Is(err, workspace.ErrRepoValidationFailed) {
+					return fmt.Errorf("invalid repository path: %w", err)
+				}
+				return fmt.Errorf("failed to clone workspace: %w", err)
+			}
+			step.Done()
+
+			result := wsCloneResult{
+				SourceID:  source.ID,
+				Workspace: clone,
+				AgentMap:  map[string]string{},
+			}
+
+			if flags.withAgents {
+				tmuxClient := tmux.NewLocalClient()
+				agentService := agent.NewService(agentRepo, queueRepo, wsService, nil, tmuxClient, agentServiceOptions(database)...)
+
+				step := opts.startProgress("Re-spawning agents")
+				cloned, err := agentService.CloneAgents(ctx, source.ID, clone.ID)
+				if err != nil {
+					step.Fail(err)
+					return fmt.Errorf("workspace %s created but failed to clone agents: %w", clone.Name, err)
+				}
+				step.Done()
+
+				result.Agents = cloned
+				for _, a := range cloned {
+					if a.AgentID != "" {
+						result.AgentMap[a.SourceID] = a.AgentID
+					}
+				}
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(result)
+			}
+
+			fmt.Fprintf(opts.Out, "Workspace cloned from %s:\n", source.Name)
+			fmt.Fprintf(opts.Out, "  ID:      %s\n", clone.ID)
+			fmt.Fprintf(opts.Out, "  Name:    %s\n", clone.Name)
+			fmt.Fprintf(opts.Out, "  Path:    %s\n", clone.RepoPath)
+			fmt.Fprintf(opts.Out, "  Session: %s\n", clone.TmuxSession)
+			if clone.GitInfo != nil && clone.GitInfo.Branch != "" {
+				fmt.Fprintf(opts.Out, "  Branch:  %s\n", clone.GitInfo.Branch)
+			}
+
+			if flags.withAgents {
+				fmt.Fprintf(opts.Out, "\nAgents (%d/%d spawned):\n", len(result.AgentMap), len(result.Agents))
+				for _, a := range result.Agents {
+					if a.Error != "" {
+						fmt.Fprintf(opts.Out, "  %s -> failed: %s\n", shortID(a.SourceID), a.Error)
+						continue
+					}
+					fmt.Fprintf(opts.Out, "  %s -> %s (%s)\n", shortID(a.SourceID), shortID(a.AgentID), a.Type)
+				}
+			}
+
+			if !flags.noTmux {
+				fmt.Fprintf(opts.Out, "\nAttach with: tmux attach -t %s\n", clone.TmuxSession)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.path, "path", "", "target directory for the new workspace")
+	cmd.Flags().StringVar(&flags.worktree, "worktree", "", "create the target as a git worktree on this branch")
+	cmd.Flags().StringVar(&flags.name, "name", "", "workspace name (default: derived from path or branch)")
+	cmd.Flags().StringVar(&flags.session, "session", "", "tmux session name (default: auto-generated)")
+	cmd.Flags().BoolVar(&flags.noTmux, "no-tmux", false, "don't create tmux session")
+	cmd.Flags().BoolVar(&flags.withAgents, "with-agents", false, "re-spawn the source workspace's agents in the clone")
+	return cmd
+}
+
+// wsCloneResult is the JSON shape emitted by ws clone.
+type wsCloneResult struct {
+	SourceID  string              `json:"source_id"`
+	Workspace *models.Workspace   `json:"workspace"`
+	AgentMap  map[string]string   `json:"agent_map"`
+	Agents    []agent.ClonedAgent `json:"agents,omitempty"`
+}
+
+func validateWsCloneFlags(path, worktree string) error {
+	if strings.TrimSpace(path) == "" && strings.TrimSpace(worktree) == "" {
+		return errors.New("--path or --worktree is required")
+	}
+	if strings.HasPrefix(strings.TrimSpace(worktree), "-") {
+		return fmt.Errorf("invalid worktree branch %q", worktree)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package cli
+
+import "testing"
+
+func TestValidateWsCloneFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		worktree string
+		wantErr  bool
+	}{
+		{name: "neither", wantErr: true},
+		{name: "blank", path: "  ", worktree: " ", wantErr: true},
+		{name: "path only", path: "/tmp/copy"},
+		{name: "worktree only", worktree: "experiment"},
+		{name: "both", path: "/tmp/copy", worktree: "experiment"},
+		{name: "flag-like branch", worktree: "-x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWsCloneFlags(tt.path, tt.worktree)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateWsCloneFlags(%q, %q) error = %v, wantErr %v", tt.path, tt.worktree, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// wsDrainFlags holds the flags of 'swarm ws drain'.
+type wsDrainFlags struct {
+	wait    bool
+	timeout time.Duration
+}
+
+func newWsDrainCmd() *cobra.Command {
+	var flags wsDrainFlags
+	cmd := &cobra.Command{
+		Use:   "drain <id-or-name>",
+		Short: "Drain every agent in a workspace",
+		Long: `Mark a workspace and all of its agents draining. The agents finish what
+is already queued but accept no new queue items, and no new agents are
+spawned into the workspace until it is undrained.
+
+With --wait, block until every agent has worked off its queue and is
+idle. An agent.drained event is emitted for each agent as it finishes.
+A workspace that is already draining is simply waited on.`,
+		Example: `  swarm ws drain my-project
+  swarm ws drain my-project --wait --timeout 2h`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			wsService, _ := newWorkspacePauseServices(database)
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+
+			_, agentIDs, err := wsService.DrainWorkspace(ctx, ws.ID)
+			switch {
+			case err == nil:
+			case errors.Is(err, workspace.ErrWorkspaceDraining) && flags.wait:
+				agents, listErr := db.NewAgentRepository(database).ListByWorkspace(ctx, ws.ID)
+				if listErr != nil {
+					return wrapServiceError(listErr, "failed to list agents")
+				}
+				for _, a := range agents {
+					agentIDs = append(agentIDs, a.ID)
+				}
+			default:
+				return wrapServiceError(err, "failed to drain workspace")
+			}
+
+			if !flags.wait {
+				if opts.Structured() {
+					return opts.WriteOutput(map[string]any{
+						"draining":     true,
+						"workspace_id": ws.ID,
+						"agent_ids":    agentIDs,
+					})
+				}
+				fmt.Fprintf(opts.Out, "Workspace '%s' draining (%d agents)\n", ws.Name, len(agentIDs))
+				return nil
+			}
+
+			if !opts.JSON && !opts.JSONL {
+				fmt.Fprintf(opts.Err, "Waiting for %d agents in workspace '%s' to drain...\n", len(agentIDs), ws.Name)
+			}
+			return waitForDrain(ctx, opts, database, agentIDs, flags.timeout)
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.wait, "wait", false, "block until every agent's queue is empty and the agents are idle")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", time.Hour, "maximum time to wait with --wait (0 = no limit)")
+	return cmd
+}
+
+func newWsUndrainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undrain <id-or-name>",
+		Short: "Undrain a workspace and its agents",
+		Long: `Clear the drain of a workspace and every agent in it, including agents
+that were drained on their own with 'swarm agent drain'.`,
+		Example: `  swarm ws undrain my-project`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			wsService, _ := newWorkspacePauseServices(database)
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+
+			agentIDs, err := wsService.UndrainWorkspace(ctx, ws.ID)
+			if err != nil {
+				return wrapServiceError(err, "failed to undrain workspace")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{
+					"undrained":    true,
+					"workspace_id": ws.ID,
+					"agent_ids":    agentIDs,
+				})
+			}
+			fmt.Fprintf(opts.Out, "Workspace '%s' undrained (%d agents)\n", ws.Name, len(agentIDs))
+			return nil
+		},
+	}
+	return cmd
+}
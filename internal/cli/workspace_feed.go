@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// defaultFeedWindow is how far back ws feed looks without --since.
+const defaultFeedWindow = 24 * time.Hour
+
+// wsFeedFlags holds the flags of 'swarm ws feed'.
+type wsFeedFlags struct {
+	follow bool
+	trace  string
+}
+
+func newWsFeedCmd() *cobra.Command {
+	var flags wsFeedFlags
+	cmd := &cobra.Command{
+		Use:   "feed <workspace>",
+		Short: "Show recent activity in a workspace",
+		Long: `Show one time-ordered feed of what happened in a workspace: agent spawns
+and state changes, message dispatches, approvals, notes, and account
+rotations for the workspace's agents.
+
+Activity from the last 24 hours is shown unless --since is given. With
+--follow, new activity is streamed as it is recorded. With --trace, only
+the activity of one command (see SWARM_TRACE_ID) is shown.`,
+		Example: `  swarm ws feed my-project
+  swarm ws feed my-project --since 1h
+  swarm ws feed my-project --follow
+  swarm ws feed my-project --jsonl --follow
+  swarm ws feed my-project --trace 3f9a2c1e8b7d4a60`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			since, err := opts.SinceTime()
+			if err != nil {
+				return invalidInputError("invalid --since value: %v", err)
+			}
+			if since == nil {
+				start := time.Now().UTC().Add(-defaultFeedWindow)
+				since = &start
+			}
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+			feed, err := newWorkspaceFeed(ctx, database, ws)
+			if err != nil {
+				return err
+			}
+
+			traceID := strings.TrimSpace(flags.trace)
+			eventRepo := db.NewEventRepository(database)
+			if flags.follow {
+				// Agents spawned while following join the feed, so the
+				// stream is not limited to the entities known now.
+				config := DefaultStreamConfig()
+				config.Match = feed.follows
+				config.Format = func(event *models.Event) string {
+					return feed.line(event, opts.Structured())
+				}
+				config.Since = since
+				config.TraceID = traceID
+				config.IncludeExisting = true
+				return NewEventStreamer(eventRepo, opts.Out, config).Stream(ctx)
+			}
+
+			it := eventRepo.Iterate(ctx, db.EventQuery{EntityIDs: feed.entityIDs, Since: since, TraceID: traceID}, 0)
+			var entries []workspaceFeedEntry
+			for it.Next() {
+				if event := it.Event(); feed.matches(event) {
+					entries = append(entries, feed.entry(event))
+				}
+			}
+			if err := it.Err(); err != nil {
+				return wrapServiceError(err, "failed to query workspace activity")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(entries)
+			}
+			if len(entries) == 0 {
+				fmt.Fprintf(opts.Out, "No activity in workspace '%s' since %s.\n", ws.Name, since.Local().Format("2006-01-02 15:04"))
+				return nil
+			}
+			for _, entry := range entries {
+				fmt.Fprintln(opts.Out, formatFeedLine(entry))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&flags.follow, "follow", "f", false, "keep streaming new activity")
+	cmd.Flags().StringVar(&flags.trace, "trace", "", "show only activity recorded under this trace ID")
+	return cmd
+}
+
+// workspaceFeedEntry is one line of the workspace feed.
+type workspaceFeedEntry struct {
+	Timestamp  time.Time         `json:"timestamp"`
+	Type       models.EventType  `json:"type"`
+	EntityType models.EntityType `json:"entity_type"`
+	EntityID   string            `json:"entity_id"`
+	Summary    string            `json:"summary"`
+}
+
+// workspaceFeed holds what a workspace's feed is filtered to and the names
+// its summaries use.
+type workspaceFeed struct {
+	workspaceID string
+	agents      map[string]*models.Agent
+	// entityIDs is pushed down to the event query: the workspace, its
+	// agents, and all accounts, since rotations are recorded against the
+	// account they rotated to.
+	entityIDs []string
+	names     events.Names
+}
+
+func newWorkspaceFeed(ctx context.Context, database *db.DB, ws *models.Workspace) (*workspaceFeed, error) {
+	// Terminated agents keep their events, so the feed still names them.
+	agents, err := db.NewAgentRepository(database).ListByWorkspace(ctx, ws.ID, db.IncludeDeleted())
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list agents")
+	}
+	accounts, err := db.NewAccountRepository(database).List(ctx, nil)
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list accounts")
+	}
+
+	feed := &workspaceFeed{
+		workspaceID: ws.ID,
+		agents:      make(map[string]*models.Agent, len(agents)),
+		entityIDs:   []string{ws.ID},
+	}
+	for _, agent := range agents {
+		feed.agents[agent.ID] = agent
+		feed.entityIDs = append(feed.entityIDs, agent.ID)
+	}
+	accountNames := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		accountNames[account.ID] = account.ProfileName
+		feed.entityIDs = append(feed.entityIDs, account.ID)
+	}
+
+	feed.names = events.Names{
+		Account: func(id string) string { return accountNames[id] },
+		Workspace: func(id string) string {
+			if id == ws.ID {
+				return ws.Name
+			}
+			return ""
+		},
+	}
+	return feed, nil
+}
+
+// matches drops account events that are not about one of the workspace's
+// agents; the query already limits everything else to the workspace.
+func (f *workspaceFeed) matches(event *models.Event) bool {
+	if event == nil {
+		return false
+	}
+	if event.EntityType != models.EntityTypeAccount {
+		return true
+	}
+	var payload struct {
+		AgentID string `json:"agent_id"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return false
+	}
+	_, ok := f.agents[payload.AgentID]
+	return ok
+}
+
+// follows is matches for --follow, whose stream is not limited to the
+// feed's entities: an agent.spawned event for the workspace adds its agent
+// to the feed, and events of entities outside the feed are dropped here.
+func (f *workspaceFeed) follows(event *models.Event) bool {
+	if event == nil {
+		return false
+	}
+	if event.Type == models.EventTypeAgentSpawned && event.EntityType == models.EntityTypeAgent {
+		var payload models.AgentSpawnedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err == nil && payload.WorkspaceID == f.workspaceID {
+			if _, ok := f.agents[event.EntityID]; !ok {
+				f.agents[event.EntityID] = &models.Agent{ID: event.EntityID, WorkspaceID: f.workspaceID, Type: payload.Type}
+			}
+		}
+	}
+	switch event.EntityType {
+	case models.EntityTypeAccount:
+		return f.matches(event)
+	case models.EntityTypeWorkspace:
+		return event.EntityID == f.workspaceID
+	}
+	_, ok := f.agents[event.EntityID]
+	return ok
+}
+
+func (f *workspaceFeed) entry(event *models.Event) workspaceFeedEntry {
+	return workspaceFeedEntry{
+		Timestamp:  event.Timestamp,
+		Type:       event.Type,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		Summary:    events.Summarize(event, f.names),
+	}
+}
+
+// line renders an event for --follow, as JSON when structured is set.
+func (f *workspaceFeed) line(event *models.Event, structured bool) string {
+	entry := f.entry(event)
+	if structured {
+		data, _ := json.Marshal(entry)
+		return string(data)
+	}
+	return formatFeedLine(entry)
+}
+
+func formatFeedLine(entry workspaceFeedEntry) string {
+	return fmt.Sprintf("%s  %s", entry.Timestamp.Local().Format("2006-01-02 15:04:05"), entry.Summary)
+}
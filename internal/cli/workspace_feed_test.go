@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestWsFeedMergesWorkspaceActivity(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	agent := seedQueueAgent(t, database)
+	wsRepo := db.NewWorkspaceRepository(database)
+	ws, err := wsRepo.Get(ctx, agent.WorkspaceID)
+	if err != nil {
+		t.Fatalf("get workspace: %v", err)
+	}
+	other := &models.Workspace{NodeID: ws.NodeID, RepoPath: "/other", TmuxSession: "swarm-other"}
+	if err := wsRepo.Create(ctx, other); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	agentRepo := db.NewAgentRepository(database)
+	outsider := &models.Agent{WorkspaceID: other.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-other:0.1", State: models.AgentStateIdle}
+	if err := agentRepo.Create(ctx, outsider); err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "anthropic-work", CredentialRef: "env:WORK_KEY", IsActive: true}
+	if err := db.NewAccountRepository(database).Create(ctx, account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	payload := func(v any) json.RawMessage {
+		data, _ := json.Marshal(v)
+		return data
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	seed := []*models.Event{
+		{Type: models.EventTypeAccountRotated, EntityType: models.EntityTypeAccount, EntityID: account.ID, Timestamp: now.Add(-10 * time.Minute),
+			Payload: payload(models.AccountRotatedPayload{AgentID: agent.ID, NewAccountID: account.ID, Reason: "rate_limit"})},
+		{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: agent.ID, Timestamp: now.Add(-30 * time.Minute),
+			Payload: payload(models.AgentSpawnedPayload{WorkspaceID: ws.ID, Type: models.AgentTypeClaudeCode})},
+		{Type: models.EventTypeWorkspaceCreated, EntityType: models.EntityTypeWorkspace, EntityID: ws.ID, Timestamp: now.Add(-40 * time.Minute)},
+		{Type: models.EventTypeAgentStateChanged, EntityType: models.EntityTypeAgent, EntityID: agent.ID, Timestamp: now.Add(-20 * time.Minute),
+			Payload: payload(models.StateChangedPayload{OldState: models.AgentStateIdle, NewState: models.AgentStateWorking})},
+		// Not part of the workspace feed.
+		{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: outsider.ID, Timestamp: now.Add(-25 * time.Minute)},
+		{Type: models.EventTypeAccountRotated, EntityType: models.EntityTypeAccount, EntityID: account.ID, Timestamp: now.Add(-5 * time.Minute),
+			Payload: payload(models.AccountRotatedPayload{AgentID: outsider.ID, NewAccountID: account.ID})},
+		{Type: models.EventTypeWorkspaceCreated, EntityType: models.EntityTypeWorkspace, EntityID: other.ID, Timestamp: now.Add(-15 * time.Minute)},
+		// Older than the default 24h window.
+		{Type: models.EventTypeAgentRestarted, EntityType: models.EntityTypeAgent, EntityID: agent.ID, Timestamp: now.Add(-48 * time.Hour)},
+	}
+	if err := db.NewEventRepository(database).CreateBatch(ctx, seed); err != nil {
+		t.Fatalf("seed events: %v", err)
+	}
+
+	out := runJSONCommand(t, newWsFeedCmd(), ws.ID)
+	var entries []workspaceFeedEntry
+	if err := json.Unmarshal(out, &entries); err != nil {
+		t.Fatalf("decode feed: %v\n%s", err, out)
+	}
+	wantTypes := []models.EventType{
+		models.EventTypeWorkspaceCreated,
+		models.EventTypeAgentSpawned,
+		models.EventTypeAgentStateChanged,
+		models.EventTypeAccountRotated,
+	}
+	if len(entries) != len(wantTypes) {
+		t.Fatalf("expected %d feed entries, got %d: %+v", len(wantTypes), len(entries), entries)
+	}
+	for i, entry := range entries {
+		if entry.Type != wantTypes[i] {
+			t.Fatalf("entry %d type = %s, want %s", i, entry.Type, wantTypes[i])
+		}
+		if i > 0 && entry.Timestamp.Before(entries[i-1].Timestamp) {
+			t.Fatalf("feed is not time-ordered: %+v", entries)
+		}
+	}
+	if want := "spawned claude-code agent '" + shortID(agent.ID) + "'"; entries[1].Summary != want {
+		t.Fatalf("spawn summary = %q, want %q", entries[1].Summary, want)
+	}
+	if want := "agent '" + shortID(agent.ID) + "' rotated to anthropic-work (rate limit)"; entries[3].Summary != want {
+		t.Fatalf("rotation summary = %q, want %q", entries[3].Summary, want)
+	}
+
+	text := captureStdout(t, func() {
+		if code, err := runCommand(t, newWsFeedCmd(), ws.ID, "--since", "35m"); code != 0 {
+			t.Errorf("ws feed failed with exit %d: %v", code, err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(string(text)), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "spawned claude-code agent '"+shortID(agent.ID)+"'") {
+		t.Fatalf("unexpected text feed since 35m:\n%s", text)
+	}
+}
+
+func TestWsFeedResolvesTerminatedAgents(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	agent := seedQueueAgent(t, database)
+	now := time.Now().UTC().Truncate(time.Second)
+	seed := []*models.Event{
+		{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: agent.ID, Timestamp: now.Add(-20 * time.Minute)},
+		{Type: models.EventTypeAgentTerminated, EntityType: models.EntityTypeAgent, EntityID: agent.ID, Timestamp: now.Add(-10 * time.Minute)},
+	}
+	if err := db.NewEventRepository(database).CreateBatch(ctx, seed); err != nil {
+		t.Fatalf("seed events: %v", err)
+	}
+	if err := db.NewAgentRepository(database).Delete(ctx, agent.ID); err != nil {
+		t.Fatalf("terminate agent: %v", err)
+	}
+
+	out := runJSONCommand(t, newWsFeedCmd(), agent.WorkspaceID)
+	var entries []workspaceFeedEntry
+	if err := json.Unmarshal(out, &entries); err != nil {
+		t.Fatalf("decode feed: %v\n%s", err, out)
+	}
+	if len(entries) != 2 || entries[1].Type != models.EventTypeAgentTerminated {
+		t.Fatalf("expected the terminated agent's events in the feed, got %+v", entries)
+	}
+}
+
+func TestWsFeedFollowAddsSpawnedAgents(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	agent := seedQueueAgent(t, database)
+	ws, err := db.NewWorkspaceRepository(database).Get(ctx, agent.WorkspaceID)
+	if err != nil {
+		t.Fatalf("get workspace: %v", err)
+	}
+	feed, err := newWorkspaceFeed(ctx, database, ws)
+	if err != nil {
+		t.Fatalf("newWorkspaceFeed: %v", err)
+	}
+
+	spawned := func(agentID, workspaceID string) *models.Event {
+		data, _ := json.Marshal(models.AgentSpawnedPayload{WorkspaceID: workspaceID, Type: models.AgentTypeClaudeCode})
+		return &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: agentID, Payload: data}
+	}
+	stateChanged := func(agentID string) *models.Event {
+		return &models.Event{Type: models.EventTypeAgentStateChanged, EntityType: models.EntityTypeAgent, EntityID: agentID}
+	}
+
+	if !feed.follows(stateChanged(agent.ID)) {
+		t.Fatal("expected the workspace's existing agent followed")
+	}
+	if feed.follows(stateChanged("late")) {
+		t.Fatal("expected an unknown agent dropped")
+	}
+	if !feed.follows(spawned("late", ws.ID)) || !feed.follows(stateChanged("late")) {
+		t.Fatal("expected an agent spawned into the workspace to join the feed")
+	}
+	if feed.follows(spawned("elsewhere", "ws-other")) || feed.follows(stateChanged("elsewhere")) {
+		t.Fatal("expected an agent spawned into another workspace dropped")
+	}
+}
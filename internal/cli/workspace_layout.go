@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// layoutTmuxClient builds the tmux client layouts are applied with;
+// tests replace it with a fake.
+var layoutTmuxClient = tmux.NewLocalClient
+
+func newWsLayoutCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "layout <id-or-name> grid|focus:<agent>",
+		Short: "Arrange a workspace's tmux panes",
+		Long: `Apply a layout preset to a workspace's tmux session without attaching.
+
+  grid            tile the agent panes evenly
+  focus:<agent>   select the agent's pane and zoom it (agent ID or prefix)`,
+		Example: `  swarm ws layout my-project grid
+  swarm ws layout my-project focus:3f2a`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			layout, err := workspace.ParseLayout(args[1])
+			if err != nil {
+				return invalidInputError("%v", err)
+			}
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			wsRepo := db.NewWorkspaceRepository(database)
+			ws, err := findWorkspace(ctx, wsRepo, args[0])
+			if err != nil {
+				return err
+			}
+
+			focus, err := applyWorkspaceLayout(ctx, database, ws, layout)
+			if err != nil {
+				return err
+			}
+
+			if opts.Structured() {
+				result := map[string]string{
+					"workspace_id": ws.ID,
+					"layout":       string(layout.Preset),
+				}
+				if focus != nil {
+					result["agent_id"] = focus.ID
+				}
+				return opts.WriteOutput(result)
+			}
+			if focus != nil {
+				fmt.Fprintf(opts.Out, "Focused agent %s in workspace '%s'\n", shortID(focus.ID), ws.Name)
+			} else {
+				fmt.Fprintf(opts.Out, "Applied %s layout to workspace '%s'\n", layout.Preset, ws.Name)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// applyWorkspaceLayout resolves the layout's agent within ws and applies
+// the layout, returning the focused agent (nil for grid).
+func applyWorkspaceLayout(ctx context.Context, database *db.DB, ws *models.Workspace, layout workspace.Layout) (*models.Agent, error) {
+	agentRepo := db.NewAgentRepository(database)
+
+	var focus *models.Agent
+	if layout.Preset == workspace.LayoutFocus {
+		agent, err := findWorkspaceAgent(ctx, agentRepo, ws, layout.Agent)
+		if err != nil {
+			return nil, err
+		}
+		focus = agent
+	}
+
+	nodeService := node.NewService(db.NewNodeRepository(database))
+	wsService := workspace.NewService(db.NewWorkspaceRepository(database), nodeService, agentRepo,
+		workspace.WithTmuxClientFactory(layoutTmuxClient))
+	if err := wsService.ApplyLayout(ctx, ws, layout, focus); err != nil {
+		return nil, wrapServiceError(err, "failed to apply layout")
+	}
+	return focus, nil
+}
+
+// findWorkspaceAgent resolves an agent ID or prefix among ws's agents.
+func findWorkspaceAgent(ctx context.Context, repo *db.AgentRepository, ws *models.Workspace, idOrPrefix string) (*models.Agent, error) {
+	agents, err := repo.ListByWorkspace(ctx, ws.ID)
+	if err != nil {
+		return nil, wrapServiceError(err, "failed to list agents")
+	}
+
+	matches := matchAgents(agents, idOrPrefix)
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) > 1 {
+		return nil, invalidInputError("agent '%s' is ambiguous; matches: %s (use a longer prefix or full ID)", idOrPrefix, formatAgentMatches(matches))
+	}
+	if len(agents) == 0 {
+		return nil, notFoundError("agent '%s' not found in workspace '%s' (it has no agents)", idOrPrefix, ws.Name)
+	}
+	example := fmt.Sprintf("Example input: '%s'", shortID(agents[0].ID))
+	return nil, notFoundError("agent '%s' not found in workspace '%s'. %s", idOrPrefix, ws.Name, example)
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func TestWsLayoutFocusAndGrid(t *testing.T) {
+	database := useTestDatabase(t)
+	ctx := context.Background()
+
+	srv := tmuxtest.NewServer()
+	client := tmux.NewClient(srv)
+	previous := layoutTmuxClient
+	layoutTmuxClient = func() *tmux.Client { return client }
+	t.Cleanup(func() { layoutTmuxClient = previous })
+
+	agent := seedQueueAgent(t, database)
+	ws, err := db.NewWorkspaceRepository(database).Get(ctx, agent.WorkspaceID)
+	if err != nil {
+		t.Fatalf("get workspace: %v", err)
+	}
+	if err := client.NewSession(ctx, ws.TmuxSession, "/repo"); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if err := client.NewWindow(ctx, ws.TmuxSession, tmux.AgentWindowName, "/repo"); err != nil {
+		t.Fatalf("NewWindow: %v", err)
+	}
+	paneID, err := client.SplitWindow(ctx, ws.TmuxSession+":"+tmux.AgentWindowName, false, "/repo")
+	if err != nil {
+		t.Fatalf("SplitWindow: %v", err)
+	}
+	agent.TmuxPane = paneID
+	if err := db.NewAgentRepository(database).Update(ctx, agent); err != nil {
+		t.Fatalf("update agent: %v", err)
+	}
+
+	zoomed := func() bool {
+		panes, err := client.ListPanes(ctx, ws.TmuxSession)
+		if err != nil {
+			t.Fatalf("ListPanes: %v", err)
+		}
+		for _, pane := range panes {
+			if pane.ID == paneID {
+				return pane.Active && pane.Zoomed
+			}
+		}
+		t.Fatalf("agent pane %s not in the current window: %+v", paneID, panes)
+		return false
+	}
+
+	if code, err := runCommand(t, newWsLayoutCmd(), ws.ID, "focus:"+shortID(agent.ID)); code != 0 {
+		t.Fatalf("ws layout focus failed with exit %d: %v", code, err)
+	}
+	if !zoomed() {
+		t.Fatal("expected the agent pane to be selected and zoomed")
+	}
+
+	if code, err := runCommand(t, newWsLayoutCmd(), ws.ID, "grid"); code != 0 {
+		t.Fatalf("ws layout grid failed with exit %d: %v", code, err)
+	}
+	if zoomed() {
+		t.Fatal("expected grid to unzoom the agents window")
+	}
+
+	if code, _ := runCommand(t, newWsLayoutCmd(), ws.ID, "focus:missing"); code != ExitCodeNotFound {
+		t.Fatalf("expected exit %d for a missing agent, got %d", ExitCodeNotFound, code)
+	}
+	if code, _ := runCommand(t, newWsLayoutCmd(), ws.ID, "stack"); code != ExitCodeInvalidInput {
+		t.Fatalf("expected exit %d for an unknown layout, got %d", ExitCodeInvalidInput, code)
+	}
+}
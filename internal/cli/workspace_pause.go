@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// wsPauseFlags holds the flags of 'swarm ws pause'.
+type wsPauseFlags struct {
+	duration time.Duration
+}
+
+func newWsPauseCmd() *cobra.Command {
+	var flags wsPauseFlags
+	cmd := &cobra.Command{
+		Use:   "pause <id-or-name>",
+		Short: "Pause a workspace and its agents",
+		Long: `Pause every running agent in a workspace and block new agents from
+being spawned into it until the workspace is resumed.
+
+Agents that are already paused are left alone and stay paused when the
+workspace is resumed. With --for, the pause lapses on its own and the
+scheduler resumes the agents once it passes.`,
+		Example: `  swarm ws pause my-project
+  swarm ws pause my-project --for 2h`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			if flags.duration < 0 {
+				return invalidInputError("--for must be positive")
+			}
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			wsService, controls := newWorkspacePauseServices(database)
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+
+			paused, err := wsService.PauseWorkspace(ctx, ws.ID, flags.duration, controls)
+			if err != nil {
+				return wrapServiceError(err, "failed to pause workspace")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(paused)
+			}
+
+			fmt.Fprintf(opts.Out, "Workspace '%s' paused (%d agents)", paused.Name, len(paused.Pause.AgentIDs))
+			if paused.Pause.Until != nil {
+				fmt.Fprintf(opts.Out, " until %s", paused.Pause.Until.Local().Format(time.RFC3339))
+			}
+			fmt.Fprintln(opts.Out)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&flags.duration, "for", 0, "resume automatically after this long (default: until 'swarm ws resume')")
+	return cmd
+}
+
+func newWsResumeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume <id-or-name>",
+		Short: "Resume a paused workspace",
+		Long: `Resume a paused workspace and the agents its pause cascaded to.
+
+Agents that were paused before the workspace was stay paused; resume them
+with 'swarm agent resume'.`,
+		Example: `  swarm ws resume my-project`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			wsService, controls := newWorkspacePauseServices(database)
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+
+			resumed, err := wsService.ResumeWorkspace(ctx, ws.ID, controls)
+			if err != nil {
+				return wrapServiceError(err, "failed to resume workspace")
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(map[string]any{
+					"resumed":        true,
+					"workspace_id":   ws.ID,
+					"agents_resumed": resumed,
+				})
+			}
+
+			fmt.Fprintf(opts.Out, "Workspace '%s' resumed (%d agents)\n", ws.Name, len(resumed))
+			return nil
+		},
+	}
+	return cmd
+}
+
+// newWorkspacePauseServices builds the workspace service and the agent
+// controls a workspace pause cascades to. The CLI runs no scheduler, so
+// the agents' paused state is what keeps work from being dispatched.
+func newWorkspacePauseServices(database *db.DB) (*workspace.Service, workspace.PauseControls) {
+	nodeService := node.NewService(db.NewNodeRepository(database), node.WithPublisher(newEventPublisher(database)))
+	agentRepo := db.NewAgentRepository(database)
+	wsService := workspace.NewService(db.NewWorkspaceRepository(database), nodeService, agentRepo, workspace.WithPublisher(newEventPublisher(database)), workspace.WithAuditRecorder(newAuditRecorder(database)))
+	agentService := agent.NewService(agentRepo, db.NewQueueRepository(database), wsService, nil, tmux.NewLocalClient(), agentServiceOptions(database)...)
+	return wsService, workspace.PauseControls{Agents: agentService}
+}
+
+// formatWorkspacePause renders the banner shown for a paused workspace.
+func formatWorkspacePause(pause *models.WorkspacePause) string {
+	if pause == nil {
+		return ""
+	}
+	if pause.Until == nil {
+		return fmt.Sprintf("PAUSED since %s (until resumed)", pause.PausedAt.Local().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("PAUSED until %s", pause.Until.Local().Format(time.RFC3339))
+}
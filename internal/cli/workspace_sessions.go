@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// wsRepairSessionsFlags holds the flags of 'swarm ws repair-sessions'.
+type wsRepairSessionsFlags struct {
+	dryRun bool
+}
+
+func newWsRepairSessionsCmd() *cobra.Command {
+	var flags wsRepairSessionsFlags
+	cmd := &cobra.Command{
+		Use:   "repair-sessions",
+		Short: "Fix colliding or unsafe workspace tmux session names",
+		Long: `Detect workspaces whose tmux session names collide or cannot be targeted
+reliably, and give each a unique <prefix>-<name>-<id> session name.
+
+Names containing '.' or ':' are renamed in tmux as well, and agent pane targets
+are updated to match. When two workspaces on a node resolve to the same live
+session, the oldest keeps it; the others get new names, and their existing
+agents stay in the shared session until restarted.`,
+		Example: `  swarm ws repair-sessions --dry-run
+  swarm ws repair-sessions --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			nodeService := node.NewService(db.NewNodeRepository(database))
+			wsService := workspace.NewService(db.NewWorkspaceRepository(database), nodeService, db.NewAgentRepository(database))
+
+			report, err := wsService.RepairSessions(ctx, flags.dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to repair sessions: %w", err)
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(report)
+			}
+
+			if len(report.Repairs) == 0 {
+				fmt.Fprintf(opts.Out, "Checked %d workspace(s); no session problems found.\n", report.Checked)
+				return nil
+			}
+
+			rows := make([][]string, 0, len(report.Repairs))
+			failed := 0
+			for _, repair := range report.Repairs {
+				status := "planned"
+				switch {
+				case repair.Error != "":
+					status = "failed: " + repair.Error
+					failed++
+				case repair.Applied && repair.Renamed:
+					status = fmt.Sprintf("renamed (%d agents)", repair.AgentsUpdated)
+				case repair.Applied:
+					status = "updated"
+				}
+				reason := string(repair.Reason)
+				if repair.SharedWith != "" {
+					reason = fmt.Sprintf("%s with %s", reason, repair.SharedWith)
+				}
+				rows = append(rows, []string{
+					repair.WorkspaceName,
+					repair.OldSession,
+					repair.NewSession,
+					reason,
+					status,
+				})
+			}
+			if err := opts.writeTable([]string{"WORKSPACE", "OLD SESSION", "NEW SESSION", "REASON", "STATUS"}, rows); err != nil {
+				return err
+			}
+
+			if report.DryRun {
+				fmt.Fprintln(opts.Out, "\nDry run; re-run without --dry-run to apply.")
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d session repair(s) failed", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "show planned renames without applying them")
+	return cmd
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/quiethours"
+	"github.com/spf13/cobra"
+)
+
+// wsSetFlags holds the flags of 'swarm ws set'.
+type wsSetFlags struct {
+	quietHours     string
+	hibernateAfter time.Duration
+}
+
+func newWsSetCmd() *cobra.Command {
+	var flags wsSetFlags
+	cmd := &cobra.Command{
+		Use:   "set <id-or-name>",
+		Short: "Change workspace settings",
+		Long: `Change the settings of a workspace.
+
+--quiet-hours sets recurring windows during which the scheduler dispatches
+nothing to the workspace's agents; their items stay queued until the
+window ends. Each window is [days] HH:MM-HH:MM [time zone]; separate
+several with ";". The workspace setting replaces scheduler.quiet_hours,
+"none" turns quiet hours off, and an empty value inherits the global
+setting again.
+
+--hibernate-after lets the scheduler hibernate agents that have been idle
+with an empty queue for longer than the given duration: their session is
+checkpointed and their pane killed. A hibernated agent wakes when items
+are queued for it, when a message is sent to it, or with 'swarm agent
+wake'. 0 turns hibernation off.`,
+		Example: `  swarm ws set my-project --quiet-hours "mon-fri 22:00-07:00 Europe/Oslo"
+  swarm ws set my-project --quiet-hours "mon-fri 22:00-07:00 UTC; sat,sun 00:00-24:00 UTC"
+  swarm ws set my-project --quiet-hours none
+  swarm ws set my-project --quiet-hours ""
+  swarm ws set my-project --hibernate-after 24h
+  swarm ws set my-project --hibernate-after 0`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := commandOptions(cmd)
+			ctx := cmd.Context()
+
+			setQuietHours := cmd.Flags().Changed("quiet-hours")
+			setHibernateAfter := cmd.Flags().Changed("hibernate-after")
+			if !setQuietHours && !setHibernateAfter {
+				return invalidInputError("nothing to set (use --quiet-hours or --hibernate-after)")
+			}
+			var schedule quiethours.Schedule
+			if setQuietHours {
+				var err error
+				if schedule, err = quiethours.ParseSchedule(flags.quietHours); err != nil {
+					return invalidInputError("invalid --quiet-hours: %v", err)
+				}
+			}
+			if setHibernateAfter && (flags.hibernateAfter < 0 || (flags.hibernateAfter > 0 && flags.hibernateAfter < time.Second)) {
+				return invalidInputError("--hibernate-after must be 0 or at least 1s, got %s", flags.hibernateAfter)
+			}
+
+			database, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			wsService, _ := newWorkspacePauseServices(database)
+			ws, err := findWorkspace(ctx, db.NewWorkspaceRepository(database), args[0])
+			if err != nil {
+				return err
+			}
+
+			if setQuietHours {
+				if ws, err = wsService.SetQuietHours(ctx, ws.ID, flags.quietHours); err != nil {
+					return wrapServiceError(err, "failed to set quiet hours")
+				}
+			}
+			if setHibernateAfter {
+				if ws, err = wsService.SetHibernateAfter(ctx, ws.ID, flags.hibernateAfter); err != nil {
+					return wrapServiceError(err, "failed to set hibernate after")
+				}
+			}
+
+			if opts.Structured() {
+				return opts.WriteOutput(ws)
+			}
+			if setQuietHours {
+				printQuietHours(opts.Out, ws, schedule)
+			}
+			if setHibernateAfter {
+				if after := ws.HibernateAfter(); after > 0 {
+					fmt.Fprintf(opts.Out, "Workspace '%s' hibernates agents idle for %s\n", ws.Name, after)
+				} else {
+					fmt.Fprintf(opts.Out, "Workspace '%s' hibernation off\n", ws.Name)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.quietHours, "quiet-hours", "", `recurring windows without dispatch, e.g. "mon-fri 22:00-07:00 Europe/Oslo" ("none" = off, "" = inherit)`)
+	cmd.Flags().DurationVar(&flags.hibernateAfter, "hibernate-after", 0, "hibernate agents idle with an empty queue for this long, e.g. 24h (0 = off)")
+	return cmd
+}
+
+func printQuietHours(out io.Writer, ws *models.Workspace, schedule quiethours.Schedule) {
+	if ws.QuietHours == "" {
+		fmt.Fprintf(out, "Workspace '%s' quiet hours cleared (inherits scheduler.quiet_hours)\n", ws.Name)
+		return
+	}
+	fmt.Fprintf(out, "Workspace '%s' quiet hours: %s\n", ws.Name, schedule)
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+)
+
+func TestWorkspaceSetQuietHours(t *testing.T) {
+	database := useTestDatabase(t)
+	a := seedQueueAgent(t, database)
+	wsRepo := db.NewWorkspaceRepository(database)
+
+	if code, err := runCommand(t, newWsSetCmd(), a.WorkspaceID, "--quiet-hours", "mon-fri 22:00-07:00 Europe/Oslo"); err != nil {
+		t.Fatalf("ws set exited %d: %v", code, err)
+	}
+	ws, err := wsRepo.Get(context.Background(), a.WorkspaceID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if ws.QuietHours != "mon-fri 22:00-07:00 Europe/Oslo" {
+		t.Fatalf("expected quiet hours stored, got %q", ws.QuietHours)
+	}
+
+	for _, args := range [][]string{
+		{a.WorkspaceID, "--quiet-hours", "mon-fri 25:00-07:00"},
+		{a.WorkspaceID, "--quiet-hours", "weekdays 22:00-07:00"},
+		{a.WorkspaceID},
+	} {
+		if _, err := runCommand(t, newWsSetCmd(), args...); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ws set %v: expected invalid input, got %v", args, err)
+		}
+	}
+
+	if code, err := runCommand(t, newWsSetCmd(), a.WorkspaceID, "--quiet-hours", ""); err != nil {
+		t.Fatalf("ws set exited %d: %v", code, err)
+	}
+	if ws, err = wsRepo.Get(context.Background(), a.WorkspaceID); err != nil || ws.QuietHours != "" {
+		t.Fatalf("expected quiet hours cleared, got %q (%v)", ws.QuietHours, err)
+	}
+}
+
+func TestWorkspaceSetHibernateAfter(t *testing.T) {
+	database := useTestDatabase(t)
+	a := seedQueueAgent(t, database)
+	wsRepo := db.NewWorkspaceRepository(database)
+
+	if code, err := runCommand(t, newWsSetCmd(), a.WorkspaceID, "--hibernate-after", "24h"); err != nil {
+		t.Fatalf("ws set exited %d: %v", code, err)
+	}
+	ws, err := wsRepo.Get(context.Background(), a.WorkspaceID)
+	if err != nil || ws.HibernateAfter() != 24*time.Hour {
+		t.Fatalf("expected hibernate after stored, got %+v (%v)", ws, err)
+	}
+
+	for _, value := range []string{"-1h", "500ms", "soon"} {
+		if _, err := runCommand(t, newWsSetCmd(), a.WorkspaceID, "--hibernate-after", value); err == nil {
+			t.Errorf("ws set --hibernate-after %s: expected an error", value)
+		}
+	}
+
+	if code, err := runCommand(t, newWsSetCmd(), a.WorkspaceID, "--hibernate-after", "0"); err != nil {
+		t.Fatalf("ws set exited %d: %v", code, err)
+	}
+	if ws, err = wsRepo.Get(context.Background(), a.WorkspaceID); err != nil || ws.HibernateAfterSeconds != 0 {
+		t.Fatalf("expected hibernation off, got %+v (%v)", ws, err)
+	}
+}
+
+func TestQuietHoursStatus(t *testing.T) {
+	previous := appConfig
+	appConfig = config.DefaultConfig()
+	appConfig.Scheduler.QuietHours = "daily 22:00-07:00 UTC"
+	t.Cleanup(func() { appConfig = previous })
+
+	night := time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC)
+	if got := quietHoursStatus(night); got != "quiet hours until 07:00 UTC" {
+		t.Errorf("expected the global quiet hours, got %q", got)
+	}
+	if got := quietHoursStatus(night, "none"); got != "" {
+		t.Errorf("expected a workspace setting of none to win, got %q", got)
+	}
+	if got := quietHoursStatus(night, "", "wed 22:00-02:00 Europe/Oslo"); got != "quiet hours until 02:00 CEST" {
+		t.Errorf("expected the workspace window, got %q", got)
+	}
+	if got := quietHoursStatus(night, "daily 00:00-24:00 UTC"); !strings.HasPrefix(got, "quiet hours until Thu ") {
+		t.Errorf("expected a far end to name its day, got %q", got)
+	}
+	if got := quietHoursStatus(night.Add(9 * time.Hour)); got != "" {
+		t.Errorf("expected no quiet hours in the morning, got %q", got)
+	}
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+func TestResolveWsCreateNodeAutoSkipsOfflineNodes(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	defer database.Close()
+
+	nodeRepo := db.NewNodeRepository(database)
+	wsRepo := db.NewWorkspaceRepository(database)
+	agentRepo := db.NewAgentRepository(database)
+	nodeService := node.NewService(nodeRepo)
+	wsService := workspace.NewService(wsRepo, nodeService, agentRepo)
+
+	nodes := map[string]*models.Node{}
+	for _, n := range []*models.Node{
+		{Name: "local", IsLocal: true, SSHBackend: models.SSHBackendAuto, Status: models.NodeStatusOnline},
+		{Name: "spare", SSHTarget: "user@spare", SSHBackend: models.SSHBackendAuto, Status: models.NodeStatusOffline, Labels: map[string]string{"gpu": "true"}},
+		{Name: "gpu", SSHTarget: "user@gpu", SSHBackend: models.SSHBackendAuto, Status: models.NodeStatusOnline, Labels: map[string]string{"gpu": "true"}},
+	} {
+		if err := nodeRepo.Create(ctx, n); err != nil {
+			t.Fatalf("create node %s: %v", n.Name, err)
+		}
+		nodes[n.Name] = n
+	}
+
+	// Both online nodes run agents; the offline one is empty and must still lose.
+	for name, count := range map[string]int{"local": 2, "gpu": 1} {
+		ws := &models.Workspace{NodeID: nodes[name].ID, RepoPath: "/existing/" + name, TmuxSession: "existing-" + name}
+		if err := wsRepo.Create(ctx, ws); err != nil {
+			t.Fatalf("create workspace: %v", err)
+		}
+		for i := 0; i < count; i++ {
+			agent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: ws.TmuxSession + ":0." + string(rune('0'+i)), State: models.AgentStateIdle}
+			if err := agentRepo.Create(ctx, agent); err != nil {
+				t.Fatalf("create agent: %v", err)
+			}
+		}
+	}
+
+	defaults := config.DefaultConfig().WorkspaceDefaults
+	create := func(t *testing.T, nodeFlag, strategyFlag string, labels []string, defaults config.WorkspaceConfig) *models.Workspace {
+		t.Helper()
+		nodeID, placement, err := resolveWsCreateNode(ctx, nodeService, nodeFlag, strategyFlag, labels, defaults)
+		if err != nil {
+			t.Fatalf("resolveWsCreateNode failed: %v", err)
+		}
+		ws, err := wsService.CreateWorkspace(ctx, workspace.CreateWorkspaceInput{
+			NodeID:    nodeID,
+			Placement: placement,
+			RepoPath:  t.TempDir(),
+		})
+		if err != nil {
+			t.Fatalf("CreateWorkspace failed: %v", err)
+		}
+		return ws
+	}
+
+	t.Run("auto", func(t *testing.T) {
+		ws := create(t, "auto", "", nil, defaults)
+		if ws.NodeID != nodes["gpu"].ID {
+			t.Fatalf("expected placement on gpu, got node %s", ws.NodeID)
+		}
+		if ws.Placement == nil || ws.Placement.Strategy != models.PlacementLeastAgents || !strings.Contains(ws.Placement.Reason, "skipped 1 offline") {
+			t.Fatalf("unexpected placement %+v", ws.Placement)
+		}
+
+		stored, err := wsRepo.Get(ctx, ws.ID)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		data, err := json.Marshal(stored)
+		if err != nil {
+			t.Fatalf("marshal failed: %v", err)
+		}
+		if !strings.Contains(string(data), `"placement":{"strategy":"least-agents","reason":`) {
+			t.Fatalf("expected placement in JSON output, got %s", data)
+		}
+	})
+
+	t.Run("require label implies auto", func(t *testing.T) {
+		ws := create(t, "", "", []string{"gpu=true"}, defaults)
+		if ws.NodeID != nodes["gpu"].ID || ws.Placement == nil {
+			t.Fatalf("expected labeled placement on gpu, got node %s placement %+v", ws.NodeID, ws.Placement)
+		}
+	})
+
+	t.Run("default node from config", func(t *testing.T) {
+		cfg := defaults
+		cfg.DefaultNode = "auto"
+		cfg.Placement = models.PlacementPinnedByLabel
+		cfg.PinLabels = map[string]string{"gpu": "true"}
+		ws := create(t, "", "", nil, cfg)
+		if ws.NodeID != nodes["gpu"].ID || ws.Placement.Strategy != models.PlacementPinnedByLabel {
+			t.Fatalf("expected pinned placement on gpu, got node %s placement %+v", ws.NodeID, ws.Placement)
+		}
+	})
+
+	t.Run("explicit node", func(t *testing.T) {
+		ws := create(t, "spare", "", nil, defaults)
+		if ws.NodeID != nodes["spare"].ID || ws.Placement != nil {
+			t.Fatalf("expected unplaced workspace on spare, got node %s placement %+v", ws.NodeID, ws.Placement)
+		}
+	})
+
+	t.Run("no online label match", func(t *testing.T) {
+		if err := nodeRepo.UpdateStatus(ctx, nodes["gpu"].ID, models.NodeStatusOffline); err != nil {
+			t.Fatalf("UpdateStatus failed: %v", err)
+		}
+		_, placement, err := resolveWsCreateNode(ctx, nodeService, "auto", "", []string{"gpu=true"}, defaults)
+		if err != nil {
+			t.Fatalf("resolveWsCreateNode failed: %v", err)
+		}
+		_, err = wsService.CreateWorkspace(ctx, workspace.CreateWorkspaceInput{Placement: placement, RepoPath: t.TempDir()})
+		if err == nil || !strings.Contains(err.Error(), "2 offline") {
+			t.Fatalf("expected offline nodes to be excluded, got %v", err)
+		}
+	})
+}
+
+func TestResolveWsCreateNodeFlagErrors(t *testing.T) {
+	defaults := config.DefaultConfig().WorkspaceDefaults
+
+	tests := []struct {
+		name     string
+		nodeFlag string
+		strategy string
+		labels   []string
+	}{
+		{name: "labels with explicit node", nodeFlag: "local", labels: []string{"gpu=true"}},
+		{name: "strategy with explicit node", nodeFlag: "local", strategy: "round-robin"},
+		{name: "unknown strategy", nodeFlag: "auto", strategy: "random"},
+		{name: "malformed label", labels: []string{"gpu"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := resolveWsCreateNode(context.Background(), nil, tt.nodeFlag, tt.strategy, tt.labels, defaults); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+
+	nodeID, placement, err := resolveWsCreateNode(context.Background(), nil, "", "", nil, defaults)
+	if err != nil || nodeID != "" || placement != nil {
+		t.Fatalf("expected local default, got %q %+v (err=%v)", nodeID, placement, err)
+	}
+}
+
+func TestWsCreateCloneInput(t *testing.T) {
+	clone, err := wsCreateCloneInput(wsCreateFlags{clone: "git@github.com:org/repo.git", path: "/data/repos/repo", branch: "main", depth: 1})
+	if err != nil || clone == nil || *clone != (workspace.RepoClone{URL: "git@github.com:org/repo.git", Branch: "main", Depth: 1}) {
+		t.Fatalf("unexpected clone %+v (err=%v)", clone, err)
+	}
+
+	if clone, err := wsCreateCloneInput(wsCreateFlags{path: "."}); err != nil || clone != nil {
+		t.Fatalf("expected no clone without --clone, got %+v (err=%v)", clone, err)
+	}
+
+	for _, tt := range []struct {
+		clone, path, branch string
+		depth               int
+	}{
+		{"", ".", "main", 0},
+		{"", ".", "", 1},
+		{"git@github.com:org/repo.git", "repo", "", 0},
+		{"git@github.com:org/repo.git", "/data/repos/repo", "", -1},
+	} {
+		if _, err := wsCreateCloneInput(wsCreateFlags{clone: tt.clone, path: tt.path, branch: tt.branch, depth: tt.depth}); err == nil {
+			t.Errorf("expected invalid input for %+v", tt)
+		}
+	}
+}
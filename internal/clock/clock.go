@@ -0,0 +1,141 @@
+// Package clock provides an injectable time source for deadline and timer
+// logic.
+//
+// Wall-clock readings (Now) are for timestamps that get displayed or
+// persisted. Anything that decides "has this pause/cooldown/backoff expired"
+// should use a Deadline, which is measured on the clock's monotonic timeline
+// and is therefore unaffected by NTP steps or laptop sleep adjusting the wall
+// clock. Note that time.Time.UTC() strips Go's monotonic reading, so values
+// produced by time.Now().UTC() must never be used for such comparisons.
+package clock
+
+import "time"
+
+// Clock is a source of wall time, monotonic time, and timers.
+type Clock interface {
+	// Now returns the current wall-clock time.
+	Now() time.Time
+
+	// Monotonic returns the elapsed time on the clock's monotonic timeline.
+	// It never goes backwards and is only meaningful relative to other
+	// readings from the same clock.
+	Monotonic() time.Duration
+
+	// NewTimer creates a timer that fires once after d of monotonic time.
+	NewTimer(d time.Duration) Timer
+
+	// NewTicker creates a ticker that fires every d of monotonic time.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer mirrors the subset of *time.Timer used by callers.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker mirrors the subset of *time.Ticker used by callers.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real returns the clock backed by the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+// OrReal returns c, or the real clock when c is nil.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real()
+	}
+	return c
+}
+
+// realBase anchors Monotonic; time.Since uses the monotonic reading it carries.
+var realBase = time.Now()
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Monotonic() time.Duration { return time.Since(realBase) }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// Since returns the monotonic time elapsed since start, a prior reading of
+// c.Monotonic().
+func Since(c Clock, start time.Duration) time.Duration {
+	return c.Monotonic() - start
+}
+
+// Deadline is an instant on a clock's monotonic timeline. The zero value
+// means "no deadline".
+type Deadline struct {
+	at   time.Duration
+	wall time.Time
+}
+
+// NewDeadline returns the deadline d from now on c.
+func NewDeadline(c Clock, d time.Duration) Deadline {
+	return Deadline{at: c.Monotonic() + d, wall: c.Now().Add(d)}
+}
+
+// DeadlineAt converts a wall-clock instant into a deadline on c. The
+// conversion happens once, so later wall-clock jumps do not move it.
+func DeadlineAt(c Clock, t time.Time) Deadline {
+	return NewDeadline(c, t.Sub(c.Now()))
+}
+
+// IsZero reports whether the deadline is unset.
+func (d Deadline) IsZero() bool {
+	return d.wall.IsZero()
+}
+
+// Time returns the wall-clock estimate of the deadline, for display and
+// persistence only.
+func (d Deadline) Time() time.Time {
+	return d.wall
+}
+
+// Remaining returns how long until the deadline on c, or zero if it passed.
+func (d Deadline) Remaining(c Clock) time.Duration {
+	remaining := d.at - c.Monotonic()
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Expired reports whether the deadline has been reached on c. An unset
+// deadline is always expired.
+func (d Deadline) Expired(c Clock) bool {
+	return d.IsZero() || c.Monotonic() >= d.at
+}
+
+// After reports whether d falls later than other. An unset deadline is
+// earlier than any set one.
+func (d Deadline) After(other Deadline) bool {
+	if d.IsZero() {
+		return false
+	}
+	if other.IsZero() {
+		return true
+	}
+	return d.at > other.at
+}
@@ -0,0 +1,97 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeadlineIgnoresWallClockJumps(t *testing.T) {
+	fake := NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	deadline := NewDeadline(fake, 5*time.Minute)
+
+	fake.Jump(2 * time.Hour)
+	if deadline.Expired(fake) {
+		t.Fatal("deadline expired after forward wall-clock jump")
+	}
+
+	fake.Jump(-4 * time.Hour)
+	if got := deadline.Remaining(fake); got != 5*time.Minute {
+		t.Fatalf("remaining after backward jump = %v, want 5m", got)
+	}
+
+	fake.Advance(5 * time.Minute)
+	if !deadline.Expired(fake) {
+		t.Fatal("deadline not expired after 5m of monotonic time")
+	}
+	if got := deadline.Remaining(fake); got != 0 {
+		t.Fatalf("remaining after expiry = %v, want 0", got)
+	}
+}
+
+func TestDeadlineAtConvertsOnce(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+	deadline := DeadlineAt(fake, start.Add(time.Minute))
+
+	fake.Jump(-time.Hour)
+	fake.Advance(time.Minute)
+	if !deadline.Expired(fake) {
+		t.Fatal("expected deadline to expire after one minute despite wall jump")
+	}
+}
+
+func TestDeadlineZeroValue(t *testing.T) {
+	fake := NewFake(time.Now())
+	var zero Deadline
+	if !zero.IsZero() || !zero.Expired(fake) {
+		t.Fatal("zero deadline should be unset and expired")
+	}
+	if zero.After(NewDeadline(fake, 0)) {
+		t.Fatal("zero deadline should not be after a set deadline")
+	}
+	if !NewDeadline(fake, time.Second).After(zero) {
+		t.Fatal("set deadline should be after zero deadline")
+	}
+}
+
+func TestFakeTimerAndTicker(t *testing.T) {
+	fake := NewFake(time.Now())
+	timer := fake.NewTimer(time.Second)
+	ticker := fake.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	fake.BlockUntil(2)
+	fake.Advance(500 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	fake.Advance(500 * time.Millisecond)
+	select {
+	case <-timer.C():
+	default:
+		t.Fatal("timer did not fire")
+	}
+	select {
+	case <-ticker.C():
+	default:
+		t.Fatal("ticker did not fire")
+	}
+
+	fake.Advance(time.Second)
+	select {
+	case <-ticker.C():
+	default:
+		t.Fatal("ticker did not fire on second interval")
+	}
+
+	if timer.Stop() {
+		t.Fatal("Stop on fired timer should report false")
+	}
+	timer.Reset(time.Second)
+	if !timer.Stop() {
+		t.Fatal("Stop on reset timer should report true")
+	}
+}
@@ -0,0 +1,169 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a manually driven Clock for tests. Advance moves both the wall and
+// monotonic timelines and fires due timers; Jump moves only the wall clock,
+// simulating an NTP step or resume from sleep.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	wall    time.Time
+	mono    time.Duration
+	waiters []*fakeWaiter
+}
+
+// NewFake returns a fake clock whose wall time starts at start.
+func NewFake(start time.Time) *Fake {
+	f := &Fake{wall: start}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake wall-clock time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.wall
+}
+
+// Monotonic returns the fake monotonic reading.
+func (f *Fake) Monotonic() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.mono
+}
+
+// Advance moves time forward by d and fires any timers that became due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.mono += d
+	f.wall = f.wall.Add(d)
+
+	active := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.at <= f.mono {
+			select {
+			case w.ch <- f.wall:
+			default:
+			}
+			if w.period <= 0 {
+				w.active = false
+				continue
+			}
+			for w.at <= f.mono {
+				w.at += w.period
+			}
+		}
+		active = append(active, w)
+	}
+	f.waiters = active
+}
+
+// Jump moves only the wall clock by d, which may be negative. Timers and
+// deadlines are unaffected.
+func (f *Fake) Jump(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.wall = f.wall.Add(d)
+}
+
+// BlockUntil waits until at least n timers or tickers are pending, so tests
+// can Advance only after the code under test has armed its timer.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// NewTimer creates a fake timer.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.addWaiter(d, 0)
+}
+
+// NewTicker creates a fake ticker.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return fakeTicker{f.addWaiter(d, d)}
+}
+
+func (f *Fake) addWaiter(d, period time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &fakeWaiter{
+		clock:  f,
+		ch:     make(chan time.Time, 1),
+		period: period,
+	}
+	f.armLocked(w, d)
+	return w
+}
+
+func (f *Fake) armLocked(w *fakeWaiter, d time.Duration) {
+	w.at = f.mono + d
+	if d <= 0 && w.period <= 0 {
+		select {
+		case w.ch <- f.wall:
+		default:
+		}
+		return
+	}
+	w.active = true
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+}
+
+func (f *Fake) removeLocked(w *fakeWaiter) bool {
+	if !w.active {
+		return false
+	}
+	w.active = false
+	for i, existing := range f.waiters {
+		if existing == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+type fakeWaiter struct {
+	clock  *Fake
+	ch     chan time.Time
+	at     time.Duration
+	period time.Duration
+	active bool
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.removeLocked(w)
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	wasActive := w.clock.removeLocked(w)
+	w.clock.armLocked(w, d)
+	return wasActive
+}
+
+type fakeTicker struct{ w *fakeWaiter }
+
+func (t fakeTicker) C() <-chan time.Time { return t.w.ch }
+func (t fakeTicker) Stop()               { t.w.Stop() }
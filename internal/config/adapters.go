@@ -0,0 +1,24 @@
+package config
+
+import "github.com/opencode-ai/swarm/internal/adapters"
+
+// AdapterOverrides returns agent_defaults.adapters keyed by adapter name,
+// for adapters.ApplyOverrides.
+func (c *Config) AdapterOverrides() map[string]adapters.Overrides {
+	overrides := make(map[string]adapters.Overrides, len(c.AgentDefaults.Adapters))
+	for name, adapter := range c.AgentDefaults.Adapters {
+		overrides[name] = adapters.Overrides{
+			Command:           adapter.Command,
+			InstallHint:       adapter.InstallHint,
+			VersionArgs:       adapter.VersionArgs,
+			ModelFlag:         adapter.ModelFlag,
+			ModelEnvVar:       adapter.ModelEnv,
+			PromptRegex:       adapter.PromptRegex,
+			BusyRegex:         adapter.BusyRegex,
+			IdleIndicators:    adapter.IdleIndicators,
+			BusyIndicators:    adapter.BusyIndicators,
+			RateLimitPatterns: adapter.RateLimitPatterns,
+		}
+	}
+	return overrides
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdapterOverrides(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.AdapterOverrides(); len(got) != 0 {
+		t.Fatalf("expected no overrides by default, got %v", got)
+	}
+
+	cfg.AgentDefaults.Adapters = map[string]AdapterConfig{
+		"claude-code": {Command: "claude-beta", ModelEnv: "CLAUDE_MODEL", PromptRegex: `claude>\s*$`, RateLimitPatterns: []string{"overloaded"}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected adapter overrides to validate: %v", err)
+	}
+	got := cfg.AdapterOverrides()["claude-code"]
+	if got.Command != "claude-beta" || got.ModelEnvVar != "CLAUDE_MODEL" || got.PromptRegex != `claude>\s*$` || len(got.RateLimitPatterns) != 1 {
+		t.Fatalf("unexpected claude-code overrides %+v", got)
+	}
+}
+
+func TestValidateAdapterOverrides(t *testing.T) {
+	tests := map[string]struct {
+		adapters map[string]AdapterConfig
+		want     string
+	}{
+		"unknown type": {map[string]AdapterConfig{"vim": {Command: "vim"}}, "unknown agent type"},
+		"bad prompt":   {map[string]AdapterConfig{"codex": {PromptRegex: "("}}, "prompt_regex is invalid"},
+		"bad busy":     {map[string]AdapterConfig{"codex": {BusyRegex: "[a"}}, "busy_regex is invalid"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.AgentDefaults.Adapters = tt.adapters
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/opencode-ai/swarm/internal/models"
@@ -59,6 +60,31 @@ func (c *Config) ApprovalPolicyForWorkspace(ws *models.Workspace) ResolvedApprov
 	return base
 }
 
+// Match returns the first rule that applies to an approval request of the
+// given type whose prompt text matches the rule pattern. Rules are only
+// consulted for the custom policy.
+func (p ResolvedApprovalPolicy) Match(requestType models.ApprovalRequestType, text string) (ApprovalRule, bool) {
+	if p.Mode != ApprovalPolicyCustom {
+		return ApprovalRule{}, false
+	}
+
+	for _, rule := range p.Rules {
+		ruleType := strings.TrimSpace(rule.RequestType)
+		if ruleType != "*" && !strings.EqualFold(ruleType, string(requestType)) {
+			continue
+		}
+		if pattern := strings.TrimSpace(rule.Pattern); pattern != "" {
+			re, err := regexp.Compile(pattern)
+			if err != nil || !re.MatchString(text) {
+				continue
+			}
+		}
+		return rule, true
+	}
+
+	return ApprovalRule{}, false
+}
+
 func (w WorkspaceOverrideConfig) matchesWorkspace(ws *models.Workspace) bool {
 	if ws == nil {
 		return false
@@ -127,6 +153,11 @@ func validateApprovalPolicy(path string, policy string, rules []ApprovalRule) er
 		default:
 			return fmt.Errorf("%s.approval_rules[%d].action must be approve, deny, or prompt", path, i)
 		}
+		if pattern := strings.TrimSpace(rule.Pattern); pattern != "" {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("%s.approval_rules[%d].pattern is invalid: %w", path, i, err)
+			}
+		}
 	}
 
 	return nil
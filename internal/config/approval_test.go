@@ -100,6 +100,19 @@ func TestApprovalPolicyValidation(t *testing.T) {
 			},
 			wantErr: "approval_rules[0].action",
 		},
+		{
+			name: "override invalid rule pattern",
+			mutate: func(cfg *Config) {
+				cfg.WorkspaceOverrides = []WorkspaceOverrideConfig{{
+					Name:           "alpha",
+					ApprovalPolicy: ApprovalPolicyCustom,
+					ApprovalRules: []ApprovalRule{
+						{RequestType: "*", Action: ApprovalRuleActionApprove, Pattern: "("},
+					},
+				}}
+			},
+			wantErr: "approval_rules[0].pattern",
+		},
 	}
 
 	for _, tt := range tests {
@@ -132,3 +145,33 @@ func TestApprovalPolicyRulesWithoutMode(t *testing.T) {
 		t.Fatalf("unexpected validation error: %v", err)
 	}
 }
+
+func TestResolvedApprovalPolicyMatch(t *testing.T) {
+	policy := ResolvedApprovalPolicy{
+		Mode: ApprovalPolicyCustom,
+		Rules: []ApprovalRule{
+			{RequestType: "prompt", Action: ApprovalRuleActionDeny, Pattern: `rm -rf`},
+			{RequestType: "prompt", Action: ApprovalRuleActionApprove, Pattern: `(?i)run.*go test`},
+			{RequestType: "file_write", Action: ApprovalRuleActionApprove},
+		},
+	}
+
+	rule, ok := policy.Match(models.ApprovalRequestTypePrompt, "Do you want to run `go test ./...`? [y/n]")
+	if !ok || rule.Action != ApprovalRuleActionApprove {
+		t.Fatalf("expected approve rule, got %+v (matched=%v)", rule, ok)
+	}
+
+	rule, ok = policy.Match(models.ApprovalRequestTypePrompt, "Run rm -rf build? [y/n]")
+	if !ok || rule.Action != ApprovalRuleActionDeny {
+		t.Fatalf("expected first matching rule to deny, got %+v (matched=%v)", rule, ok)
+	}
+
+	if _, ok := policy.Match(models.ApprovalRequestTypePrompt, "Push to origin? [y/n]"); ok {
+		t.Fatal("expected no rule to match")
+	}
+
+	policy.Mode = ApprovalPolicyStrict
+	if _, ok := policy.Match(models.ApprovalRequestTypePrompt, "Do you want to run go test? [y/n]"); ok {
+		t.Fatal("expected rules to be ignored outside the custom policy")
+	}
+}
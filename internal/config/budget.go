@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// DailyBudgetForWorkspace returns the daily cost ceiling for a workspace in
+// cents: the first matching workspace override that sets one wins. Zero
+// means the workspace has no ceiling of its own.
+func (c *Config) DailyBudgetForWorkspace(ws *models.Workspace) int64 {
+	if ws == nil {
+		return 0
+	}
+	for _, override := range c.WorkspaceOverrides {
+		if override.DailyBudgetCents > 0 && override.matchesWorkspace(ws) {
+			return override.DailyBudgetCents
+		}
+	}
+	return 0
+}
+
+// CostPerHour returns budget.cost_per_hour_cents keyed by agent type.
+func (c BudgetConfig) CostPerHour() map[models.AgentType]int64 {
+	rates := make(map[models.AgentType]int64, len(c.CostPerHourCents))
+	for agentType, cents := range c.CostPerHourCents {
+		rates[models.AgentType(agentType)] = cents
+	}
+	return rates
+}
+
+// CostLocation returns the time zone whose days the cost ticker totals.
+func (c BudgetConfig) CostLocation() (*time.Location, error) {
+	if c.CostTimezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.CostTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("budget.cost_timezone: %w", err)
+	}
+	return loc, nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestDailyBudgetForWorkspace(t *testing.T) {
+	cfg := DefaultConfig()
+	ws := &models.Workspace{ID: "ws-1", Name: "alpha", RepoPath: "/tmp/alpha"}
+
+	if cents := cfg.DailyBudgetForWorkspace(ws); cents != 0 {
+		t.Fatalf("expected no workspace budget by default, got %d", cents)
+	}
+
+	cfg.WorkspaceOverrides = []WorkspaceOverrideConfig{
+		{Name: "alpha", ApprovalPolicy: ApprovalPolicyPermissive},
+		{RepoPath: "/tmp/*", DailyBudgetCents: 2500},
+		{Name: "alpha", DailyBudgetCents: 900},
+	}
+	if cents := cfg.DailyBudgetForWorkspace(ws); cents != 2500 {
+		t.Fatalf("expected first override with a budget to win, got %d", cents)
+	}
+	if cents := cfg.DailyBudgetForWorkspace(nil); cents != 0 {
+		t.Fatalf("expected no budget without a workspace, got %d", cents)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected budget-only override to validate: %v", err)
+	}
+}
+
+func TestValidateBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"negative ceiling", func(c *Config) { c.Budget.DailyCeilingCents = -1 }, "budget.daily_ceiling_cents"},
+		{"unknown mode", func(c *Config) { c.Budget.Mode = "block" }, "budget.mode"},
+		{"unknown agent type", func(c *Config) { c.Budget.CostPerHourCents["cursor"] = 100 }, "unknown agent type"},
+		{"negative rate", func(c *Config) { c.Budget.CostPerHourCents["codex"] = -5 }, "cost_per_hour_cents.codex"},
+		{"negative workspace budget", func(c *Config) {
+			c.WorkspaceOverrides = []WorkspaceOverrideConfig{{Name: "alpha", DailyBudgetCents: -1}}
+		}, "daily_budget_cents"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/opencode-ai/swarm/internal/fsperm"
 )
 
 // Context represents the current CLI context (selected workspace/agent).
@@ -166,7 +168,7 @@ func (s *ContextStore) Save(ctx *Context) error {
 
 	// Ensure directory exists
 	dir := filepath.Dir(s.path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := fsperm.MkdirAll(dir); err != nil {
 		return fmt.Errorf("failed to create context directory: %w", err)
 	}
 
@@ -175,7 +177,7 @@ func (s *ContextStore) Save(ctx *Context) error {
 		return fmt.Errorf("failed to serialize context: %w", err)
 	}
 
-	if err := os.WriteFile(s.path, data, 0644); err != nil {
+	if err := fsperm.WriteFile(s.path, data); err != nil {
 		return fmt.Errorf("failed to write context file: %w", err)
 	}
 
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDaemonConfigFromFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+daemon:
+  config_watch_interval: 0s
+  schedule_interval: 1m
+  transcript_compaction:
+    older_than: 72h
+  session_gc:
+    kill: true
+  transcript_backfill_max_bytes: 65536
+  provider_health:
+    interval: 30s
+    endpoints:
+      anthropic:
+        status_page: none
+  rate_limits:
+    global:
+      requests_per_second: 50
+      burst: 100
+    methods:
+      SpawnAgent:
+        requests_per_second: 1
+        burst: 2
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	daemon := cfg.Daemon
+	if daemon.ConfigWatchInterval != 0 || daemon.ScheduleInterval != time.Minute || daemon.StandbyInterval != 30*time.Second {
+		t.Fatalf("unexpected daemon intervals %+v", daemon)
+	}
+	if daemon.TranscriptCompaction != (TranscriptCompactionConfig{OlderThan: 72 * time.Hour, Interval: time.Hour}) {
+		t.Fatalf("unexpected transcript compaction %+v", daemon.TranscriptCompaction)
+	}
+	if daemon.SessionGC != (SessionGCConfig{Interval: time.Hour, OlderThan: 24 * time.Hour, Kill: true}) {
+		t.Fatalf("unexpected session gc %+v", daemon.SessionGC)
+	}
+	health := daemon.ProviderHealth
+	if health.Interval != 30*time.Second || health.FailureThreshold != 2 || health.RecoveryThreshold != 3 || !health.StatusPages {
+		t.Fatalf("unexpected provider health %+v", health)
+	}
+	if got := health.Endpoints["anthropic"]; got != (ProviderEndpointConfig{StatusPage: ProviderEndpointNone}) {
+		t.Fatalf("unexpected anthropic endpoints %+v", got)
+	}
+	if daemon.TranscriptBackfillMaxBytes != 65536 {
+		t.Fatalf("unexpected transcript backfill cap %d", daemon.TranscriptBackfillMaxBytes)
+	}
+	if !daemon.RateLimits.Enabled {
+		t.Fatal("expected rate limiting enabled by default")
+	}
+	if daemon.RateLimits.Global != (RateLimit{RequestsPerSecond: 50, Burst: 100}) {
+		t.Fatalf("unexpected global limit %+v", daemon.RateLimits.Global)
+	}
+	// Map keys are read lowercased.
+	if got := daemon.RateLimits.Methods["spawnagent"]; got != (RateLimit{RequestsPerSecond: 1, Burst: 2}) {
+		t.Fatalf("unexpected SpawnAgent limit %+v", got)
+	}
+}
+
+func TestValidateDaemon(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"negative watch interval", func(c *Config) { c.Daemon.ConfigWatchInterval = -time.Second }, "daemon.config_watch_interval"},
+		{"fast schedule checks", func(c *Config) { c.Daemon.ScheduleInterval = time.Millisecond }, "daemon.schedule_interval"},
+		{"fast standby checks", func(c *Config) { c.Daemon.StandbyInterval = 0 }, "daemon.standby_interval"},
+		{"negative compaction age", func(c *Config) {
+			c.Daemon.TranscriptCompaction.OlderThan = -time.Hour
+		}, "daemon.transcript_compaction.older_than"},
+		{"fast compaction", func(c *Config) {
+			c.Daemon.TranscriptCompaction.Interval = time.Second
+		}, "daemon.transcript_compaction.interval"},
+		{"fast session gc", func(c *Config) {
+			c.Daemon.SessionGC.Interval = time.Second
+		}, "daemon.session_gc.interval"},
+		{"negative session gc age", func(c *Config) {
+			c.Daemon.SessionGC.OlderThan = -time.Hour
+		}, "daemon.session_gc.older_than"},
+		{"negative backfill cap", func(c *Config) {
+			c.Daemon.TranscriptBackfillMaxBytes = -1
+		}, "daemon.transcript_backfill_max_bytes"},
+		{"fast provider probes", func(c *Config) {
+			c.Daemon.ProviderHealth.Interval = time.Second
+		}, "daemon.provider_health.interval"},
+		{"zero recovery threshold", func(c *Config) {
+			c.Daemon.ProviderHealth.RecoveryThreshold = 0
+		}, "daemon.provider_health.recovery_threshold"},
+		{"unprobeable provider endpoint", func(c *Config) {
+			c.Daemon.ProviderHealth.Endpoints = map[string]ProviderEndpointConfig{"custom": {API: "https://llm.internal/v1/models"}}
+		}, "daemon.provider_health.endpoints.custom"},
+		{"relative provider endpoint", func(c *Config) {
+			c.Daemon.ProviderHealth.Endpoints = map[string]ProviderEndpointConfig{"openai": {StatusPage: "/status.json"}}
+		}, "daemon.provider_health.endpoints.openai.status_page"},
+		{"unknown execution backend", func(c *Config) {
+			c.Daemon.ExecutionBackend = "screen"
+		}, "daemon.execution_backend"},
+		{"negative agent retention", func(c *Config) { c.AgentRetention.MaxAge = -time.Hour }, "agent_retention.max_age"},
+		{"fast agent pruning", func(c *Config) { c.AgentRetention.CleanupInterval = time.Second }, "agent_retention.cleanup_interval"},
+		{"unbounded audit retention", func(c *Config) { c.AuditRetention.MaxAge = 0 }, "audit_retention"},
+		{"fast audit pruning", func(c *Config) { c.AuditRetention.CleanupInterval = time.Second }, "audit_retention.cleanup_interval"},
+		{"negative usage retention", func(c *Config) { c.UsageRetention.MaxAge = -time.Hour }, "usage_retention.max_age"},
+		{"fast usage cache refresh", func(c *Config) { c.UsageRetention.CleanupInterval = time.Second }, "usage_retention.cleanup_interval"},
+		{"global without burst", func(c *Config) {
+			c.Daemon.RateLimits.Global = RateLimit{RequestsPerSecond: 10}
+		}, "daemon.rate_limits.global.burst"},
+		{"empty method limit", func(c *Config) {
+			c.Daemon.RateLimits.Methods = map[string]RateLimit{"SpawnAgent": {}}
+		}, "daemon.rate_limits.methods.SpawnAgent"},
+		{"zero method rate", func(c *Config) {
+			c.Daemon.RateLimits.Methods = map[string]RateLimit{"KillAgent": {Burst: 5}}
+		}, "daemon.rate_limits.methods.KillAgent.requests_per_second"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/age"
+	"github.com/spf13/viper"
+)
+
+// Encrypted config files let teams commit settings that include secrets,
+// such as account profiles with literal credentials. They are age-encrypted
+// YAML or JSON named like config.enc.yaml. An encrypted file next to the
+// plaintext config is decrypted at load time and merged over it; one passed
+// with --config is loaded on its own.
+
+// AgeKeyEnvVar holds age identities directly, taking precedence over
+// encryption.identity_file. Useful in CI where no key file exists.
+const AgeKeyEnvVar = "SWARM_AGE_KEY"
+
+// RedactedValue replaces settings from an encrypted config in
+// EffectiveSettings.
+const RedactedValue = "[REDACTED:encrypted]"
+
+var encryptedSuffixes = []string{".enc.yaml", ".enc.yml", ".enc.json"}
+
+// IsEncryptedConfigPath reports whether path names an encrypted config file.
+func IsEncryptedConfigPath(path string) bool {
+	for _, suffix := range encryptedSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// EncryptedConfigPath returns the encrypted companion path for a plaintext
+// config file: config.yaml becomes config.enc.yaml.
+func EncryptedConfigPath(path string) string {
+	if IsEncryptedConfigPath(path) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".enc" + ext
+}
+
+// LoadIdentities returns the age identities from AgeKeyEnvVar if set, or
+// else from identityFile.
+func LoadIdentities(identityFile string) ([]*age.Identity, error) {
+	if keys := os.Getenv(AgeKeyEnvVar); strings.TrimSpace(keys) != "" {
+		ids, err := age.ParseIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", AgeKeyEnvVar, err)
+		}
+		return ids, nil
+	}
+
+	path := expandTilde(identityFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("age identity file %s not found (set encryption.identity_file or %s)", path, AgeKeyEnvVar)
+		}
+		return nil, fmt.Errorf("failed to read age identity file: %w", err)
+	}
+	ids, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid age identity file %s: %w", path, err)
+	}
+	return ids, nil
+}
+
+// DecryptConfigFile reads and decrypts an encrypted config file.
+func DecryptConfigFile(path, identityFile string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encrypted config: %w", err)
+	}
+	ids, err := LoadIdentities(identityFile)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := age.Decrypt(data, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return plaintext, nil
+}
+
+// EncryptConfig encrypts a plaintext config to the recipients, armored so
+// the result can be committed and diffed as text.
+func EncryptConfig(plaintext []byte, recipients ...*age.Recipient) ([]byte, error) {
+	encrypted, err := age.Encrypt(plaintext, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	return age.Armor(encrypted), nil
+}
+
+// GenerateIdentityFile creates a new age identity and writes it to path in
+// age-keygen format, readable only by the owner. It refuses to overwrite
+// an existing file.
+func GenerateIdentityFile(path string, now time.Time) (*age.Identity, error) {
+	id, err := age.GenerateIdentity()
+	if err != nil {
+		return nil, err
+	}
+	path = expandTilde(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create identity directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity file: %w", err)
+	}
+	_, err = fmt.Fprintf(file, "# created: %s\n# public key: %s\n%s\n", now.Format(time.RFC3339), id.Recipient(), id)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write identity file: %w", err)
+	}
+	return id, nil
+}
+
+// readEncryptedConfig loads an explicitly requested encrypted config file.
+func (l *Loader) readEncryptedConfig(path string) error {
+	settings, plaintext, err := l.decryptSettings(path)
+	if err != nil {
+		return err
+	}
+	if err := l.v.ReadConfig(bytes.NewReader(plaintext)); err != nil {
+		return err
+	}
+	l.encryptedFile = path
+	l.encryptedSettings = settings
+	return nil
+}
+
+// loadEncryptedCompanion merges the encrypted file next to the plaintext
+// config, if there is one. A config marked encrypted: true must have one.
+func (l *Loader) loadEncryptedCompanion() error {
+	if l.encryptedFile != "" {
+		return nil
+	}
+
+	var path string
+	if used := l.v.ConfigFileUsed(); used != "" {
+		base := strings.TrimSuffix(used, filepath.Ext(used))
+		for _, suffix := range encryptedSuffixes {
+			if _, err := os.Stat(base + suffix); err == nil {
+				path = base + suffix
+				break
+			}
+		}
+	}
+	if path == "" {
+		if l.v.GetBool("encrypted") {
+			return fmt.Errorf("config is marked encrypted but no encrypted config file was found next to %s", l.v.ConfigFileUsed())
+		}
+		return nil
+	}
+
+	settings, plaintext, err := l.decryptSettings(path)
+	if err != nil {
+		return err
+	}
+	if err := l.v.MergeConfig(bytes.NewReader(plaintext)); err != nil {
+		return fmt.Errorf("failed to merge encrypted config %s: %w", path, err)
+	}
+	l.encryptedFile = path
+	l.encryptedSettings = settings
+	return nil
+}
+
+// decryptSettings decrypts path and parses it on its own, so the settings
+// it contributes are known for redaction.
+func (l *Loader) decryptSettings(path string) (map[string]interface{}, []byte, error) {
+	plaintext, err := DecryptConfigFile(path, l.v.GetString("encryption.identity_file"))
+	if err != nil {
+		return nil, nil, err
+	}
+	// JSON is valid YAML, so both formats parse as YAML.
+	parsed := viper.New()
+	parsed.SetConfigType("yaml")
+	if err := parsed.ReadConfig(bytes.NewReader(plaintext)); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse decrypted config %s: %w", path, err)
+	}
+	return parsed.AllSettings(), plaintext, nil
+}
+
+// EncryptedConfigFileUsed returns the encrypted config that was decrypted,
+// or "" if none.
+func (l *Loader) EncryptedConfigFileUsed() string {
+	return l.encryptedFile
+}
+
+// EffectiveSettings returns the merged settings (defaults, config files,
+// and environment) with every value that came from an encrypted config
+// replaced by RedactedValue. Use it wherever settings are displayed or
+// written out, so decrypted secrets never leave memory.
+func (l *Loader) EffectiveSettings() map[string]interface{} {
+	settings := l.v.AllSettings()
+	redactSettings(settings, l.encryptedSettings)
+	return settings
+}
+
+// redactSettings redacts the values in settings at every key present in
+// secret, descending into maps both sides share.
+func redactSettings(settings, secret map[string]interface{}) {
+	for key, secretValue := range secret {
+		value, ok := settings[key]
+		if !ok {
+			continue
+		}
+		nestedSecret, secretIsMap := secretValue.(map[string]interface{})
+		nested, isMap := value.(map[string]interface{})
+		if secretIsMap && isMap {
+			redactSettings(nested, nestedSecret)
+			continue
+		}
+		settings[key] = redactValue(value)
+	}
+}
+
+// redactValue replaces every scalar in value, keeping the shape of maps
+// and lists so the output still shows what is configured.
+func redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			out[k] = redactValue(v)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(typed))
+		for i, v := range typed {
+			out[i] = redactValue(v)
+		}
+		return out
+	case nil:
+		return nil
+	default:
+		return RedactedValue
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/age"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+const encryptedFixture = `
+accounts:
+  - provider: anthropic
+    profile_name: shared
+    credential_ref: sk-ant-literal-secret
+    is_active: true
+logging:
+  format: json
+`
+
+// writeEncryptedConfig creates an identity in dir and writes config.yaml
+// plus config.enc.yaml encrypted to that identity.
+func writeEncryptedConfig(t *testing.T, dir, plaintext string) (configPath, identityPath string) {
+	t.Helper()
+	identityPath = filepath.Join(dir, "age.key")
+	id, err := GenerateIdentityFile(identityPath, time.Now())
+	if err != nil {
+		t.Fatalf("GenerateIdentityFile() error = %v", err)
+	}
+	encrypted, err := EncryptConfig([]byte(encryptedFixture), id.Recipient())
+	if err != nil {
+		t.Fatalf("EncryptConfig() error = %v", err)
+	}
+	if strings.Contains(string(encrypted), "sk-ant-literal-secret") {
+		t.Fatal("encrypted config contains the plaintext secret")
+	}
+
+	configPath = filepath.Join(dir, "config.yaml")
+	content := plaintext + "\nencryption:\n  identity_file: " + identityPath + "\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(EncryptedConfigPath(configPath), encrypted, 0644); err != nil {
+		t.Fatalf("write encrypted config: %v", err)
+	}
+	return configPath, identityPath
+}
+
+func TestLoadMergesEncryptedConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath, _ := writeEncryptedConfig(t, dir, "logging:\n  level: debug\n  format: console\n")
+
+	loader := NewLoader()
+	loader.SetConfigFile(configPath)
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(cfg.Accounts) != 1 || cfg.Accounts[0].CredentialRef != "sk-ant-literal-secret" || cfg.Accounts[0].Provider != models.ProviderAnthropic {
+		t.Fatalf("expected decrypted account, got %+v", cfg.Accounts)
+	}
+	if cfg.Logging.Level != "debug" || cfg.Logging.Format != "json" {
+		t.Fatalf("expected encrypted settings merged over plaintext, got %+v", cfg.Logging)
+	}
+	if got := loader.EncryptedConfigFileUsed(); got != filepath.Join(dir, "config.enc.yaml") {
+		t.Fatalf("EncryptedConfigFileUsed() = %q", got)
+	}
+
+	data, err := json.Marshal(loader.EffectiveSettings())
+	if err != nil {
+		t.Fatalf("marshal settings: %v", err)
+	}
+	if strings.Contains(string(data), "sk-ant-literal-secret") {
+		t.Fatalf("effective settings leak a decrypted secret: %s", data)
+	}
+	if !strings.Contains(string(data), `"level":"debug"`) || !strings.Contains(string(data), `"credential_ref":"`+RedactedValue+`"`) {
+		t.Fatalf("expected plaintext settings kept and encrypted ones redacted: %s", data)
+	}
+}
+
+func TestLoadEncryptedConfigDirectly(t *testing.T) {
+	dir := t.TempDir()
+	configPath, identityPath := writeEncryptedConfig(t, dir, "")
+	t.Setenv("SWARM_ENCRYPTION_IDENTITY_FILE", identityPath)
+
+	cfg, err := LoadFromFile(EncryptedConfigPath(configPath))
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	if len(cfg.Accounts) != 1 || cfg.Accounts[0].ProfileName != "shared" {
+		t.Fatalf("expected decrypted account, got %+v", cfg.Accounts)
+	}
+}
+
+func TestLoadEncryptedConfigFromEnvKey(t *testing.T) {
+	dir := t.TempDir()
+	configPath, identityPath := writeEncryptedConfig(t, dir, "")
+	key, err := os.ReadFile(identityPath)
+	if err != nil {
+		t.Fatalf("read identity: %v", err)
+	}
+	if err := os.Remove(identityPath); err != nil {
+		t.Fatalf("remove identity: %v", err)
+	}
+	t.Setenv(AgeKeyEnvVar, string(key))
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	if len(cfg.Accounts) != 1 {
+		t.Fatalf("expected decrypted account, got %+v", cfg.Accounts)
+	}
+}
+
+func TestLoadEncryptedConfigWrongIdentity(t *testing.T) {
+	dir := t.TempDir()
+	configPath, _ := writeEncryptedConfig(t, dir, "")
+	wrong, err := age.GenerateIdentity()
+	if err != nil {
+		t.Fatalf("GenerateIdentity() error = %v", err)
+	}
+	t.Setenv(AgeKeyEnvVar, wrong.String())
+
+	_, err = LoadFromFile(configPath)
+	if err == nil || !strings.Contains(err.Error(), "no identity matched") {
+		t.Fatalf("expected decryption failure with the wrong identity, got %v", err)
+	}
+}
+
+func TestLoadEncryptedMarkerRequiresCompanion(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("encrypted: true\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_, err := LoadFromFile(configPath)
+	if err == nil || !strings.Contains(err.Error(), "marked encrypted") {
+		t.Fatalf("expected missing encrypted config error, got %v", err)
+	}
+}
+
+func TestGenerateIdentityFileRefusesOverwrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "age.key")
+	if _, err := GenerateIdentityFile(path, time.Now()); err != nil {
+		t.Fatalf("GenerateIdentityFile() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat identity: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("identity file mode = %v, want 0600", info.Mode().Perm())
+	}
+	if _, err := GenerateIdentityFile(path, time.Now()); err == nil {
+		t.Fatal("expected an existing identity file not to be overwritten")
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// EnvironmentForWorkspace returns the default environment variables of the
+// workspace overrides matching ws. Where several set the same variable the
+// first wins. Names are upper-cased, since config keys are read
+// case-insensitively.
+func (c *Config) EnvironmentForWorkspace(ws *models.Workspace) map[string]string {
+	if ws == nil {
+		return nil
+	}
+	var env map[string]string
+	for _, override := range c.WorkspaceOverrides {
+		if !override.matchesWorkspace(ws) {
+			continue
+		}
+		for name, value := range override.Environment {
+			name = strings.ToUpper(strings.TrimSpace(name))
+			if _, ok := env[name]; ok || name == "" {
+				continue
+			}
+			if env == nil {
+				env = make(map[string]string)
+			}
+			env[name] = value
+		}
+	}
+	return env
+}
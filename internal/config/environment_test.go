@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestEnvironmentForWorkspace(t *testing.T) {
+	cfg := DefaultConfig()
+	ws := &models.Workspace{ID: "ws-1", Name: "alpha", RepoPath: "/tmp/alpha"}
+
+	if env := cfg.EnvironmentForWorkspace(ws); env != nil {
+		t.Fatalf("expected no environment, got %v", env)
+	}
+
+	cfg.WorkspaceOverrides = []WorkspaceOverrideConfig{
+		{Name: "alpha", Environment: map[string]string{"log_level": "debug"}},
+		{Name: "beta", Environment: map[string]string{"REGION": "eu"}},
+		{RepoPath: "/tmp/*", Environment: map[string]string{"LOG_LEVEL": "info", "REGION": "us"}},
+	}
+
+	env := cfg.EnvironmentForWorkspace(ws)
+	if len(env) != 2 || env["LOG_LEVEL"] != "debug" || env["REGION"] != "us" {
+		t.Fatalf("unexpected environment %v", env)
+	}
+	if env := cfg.EnvironmentForWorkspace(nil); env != nil {
+		t.Fatalf("expected no environment without a workspace, got %v", env)
+	}
+}
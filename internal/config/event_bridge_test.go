@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventBridgeConfigFromFile(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.EventBridge.Enabled || cfg.EventBridge.SubjectPrefix != "swarm.events" {
+		t.Fatalf("unexpected default event bridge config %+v", cfg.EventBridge)
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+event_bridge:
+  enabled: true
+  url: nats://bus.internal:4222
+  subject_prefix: ops.swarm
+  poll_interval: 250ms
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	t.Setenv("SWARM_EVENT_BRIDGE_BATCH_SIZE", "50")
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	bridge := cfg.EventBridge
+	if !bridge.Enabled || bridge.URL != "nats://bus.internal:4222" || bridge.SubjectPrefix != "ops.swarm" || bridge.PollInterval != 250*time.Millisecond {
+		t.Fatalf("unexpected event bridge config %+v", bridge)
+	}
+	if bridge.Backend != "nats" || bridge.BufferSize != 1000 {
+		t.Fatalf("expected unset fields to keep their defaults, got %+v", bridge)
+	}
+	if bridge.BatchSize != 50 {
+		t.Fatalf("expected the environment to override batch_size, got %d", bridge.BatchSize)
+	}
+}
+
+func TestValidateEventBridge(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"unknown backend", func(c *Config) { c.EventBridge.Backend = "kafka" }, "event_bridge.backend"},
+		{"no url", func(c *Config) { c.EventBridge.URL = " " }, "event_bridge.url"},
+		{"wildcard prefix", func(c *Config) { c.EventBridge.SubjectPrefix = "swarm.>" }, "event_bridge.subject_prefix"},
+		{"empty buffer", func(c *Config) { c.EventBridge.BufferSize = 0 }, "event_bridge.buffer_size"},
+		{"empty batch", func(c *Config) { c.EventBridge.BatchSize = 0 }, "event_bridge.batch_size"},
+		{"fast polling", func(c *Config) { c.EventBridge.PollInterval = time.Millisecond }, "event_bridge.poll_interval"},
+		{"no timeout", func(c *Config) { c.EventBridge.PublishTimeout = 0 }, "event_bridge.publish_timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.EventBridge.Enabled = true
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Disabled, the settings are not checked.
+	cfg := DefaultConfig()
+	cfg.EventBridge.URL = ""
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected a disabled bridge to validate, got %v", err)
+	}
+}
@@ -13,6 +13,11 @@ import (
 type Loader struct {
 	v          *viper.Viper
 	configFile string
+
+	// encryptedFile is the encrypted config that was decrypted, if any,
+	// and encryptedSettings its decrypted settings, kept for redaction.
+	encryptedFile     string
+	encryptedSettings map[string]interface{}
 }
 
 // NewLoader creates a new configuration loader.
@@ -44,6 +49,11 @@ func (l *Loader) Load() (*Config, error) {
 		}
 	}
 
+	// Merge the encrypted companion over the plaintext config
+	if err := l.loadEncryptedCompanion(); err != nil {
+		return nil, err
+	}
+
 	// Unmarshal into config struct
 	if err := l.v.Unmarshal(cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
@@ -84,6 +94,10 @@ func expandPaths(cfg *Config) {
 	cfg.Logging.File = expandTilde(cfg.Logging.File)
 	cfg.NodeDefaults.SSHKeyPath = expandTilde(cfg.NodeDefaults.SSHKeyPath)
 	cfg.EventRetention.ArchiveDir = expandTilde(cfg.EventRetention.ArchiveDir)
+	cfg.Encryption.IdentityFile = expandTilde(cfg.Encryption.IdentityFile)
+	for i := range cfg.WorkspaceDefaults.BootstrapFiles {
+		cfg.WorkspaceDefaults.BootstrapFiles[i].Source = expandTilde(cfg.WorkspaceDefaults.BootstrapFiles[i].Source)
+	}
 }
 
 // setupViper configures Viper with defaults and environment bindings.
@@ -124,6 +138,7 @@ func (l *Loader) setDefaults(cfg *Config) {
 	v.SetDefault("global.data_dir", cfg.Global.DataDir)
 	v.SetDefault("global.config_dir", cfg.Global.ConfigDir)
 	v.SetDefault("global.auto_register_local_node", cfg.Global.AutoRegisterLocalNode)
+	v.SetDefault("global.strict_permissions", cfg.Global.StrictPermissions)
 
 	// Database
 	v.SetDefault("database.path", cfg.Database.Path)
@@ -141,11 +156,16 @@ func (l *Loader) setDefaults(cfg *Config) {
 	v.SetDefault("node_defaults.ssh_timeout", cfg.NodeDefaults.SSHTimeout)
 	v.SetDefault("node_defaults.ssh_key_path", cfg.NodeDefaults.SSHKeyPath)
 	v.SetDefault("node_defaults.health_check_interval", cfg.NodeDefaults.HealthCheckInterval)
+	v.SetDefault("node_defaults.metrics_disk_path", cfg.NodeDefaults.MetricsDiskPath)
+	v.SetDefault("node_defaults.min_disk_free_percent", cfg.NodeDefaults.MinDiskFreePercent)
 
 	// Workspace defaults
 	v.SetDefault("workspace_defaults.tmux_prefix", cfg.WorkspaceDefaults.TmuxPrefix)
 	v.SetDefault("workspace_defaults.default_agent_type", string(cfg.WorkspaceDefaults.DefaultAgentType))
 	v.SetDefault("workspace_defaults.auto_import_existing", cfg.WorkspaceDefaults.AutoImportExisting)
+	v.SetDefault("workspace_defaults.default_node", cfg.WorkspaceDefaults.DefaultNode)
+	v.SetDefault("workspace_defaults.placement", string(cfg.WorkspaceDefaults.Placement))
+	v.SetDefault("workspace_defaults.bootstrap", cfg.WorkspaceDefaults.Bootstrap)
 
 	// Agent defaults
 	v.SetDefault("agent_defaults.default_type", string(cfg.AgentDefaults.DefaultType))
@@ -153,6 +173,7 @@ func (l *Loader) setDefaults(cfg *Config) {
 	v.SetDefault("agent_defaults.idle_timeout", cfg.AgentDefaults.IdleTimeout)
 	v.SetDefault("agent_defaults.transcript_buffer_size", cfg.AgentDefaults.TranscriptBufferSize)
 	v.SetDefault("agent_defaults.approval_policy", cfg.AgentDefaults.ApprovalPolicy)
+	v.SetDefault("agent_defaults.stuck_timeout", cfg.AgentDefaults.StuckTimeout)
 
 	// Scheduler
 	v.SetDefault("scheduler.dispatch_interval", cfg.Scheduler.DispatchInterval)
@@ -160,18 +181,84 @@ func (l *Loader) setDefaults(cfg *Config) {
 	v.SetDefault("scheduler.retry_backoff", cfg.Scheduler.RetryBackoff)
 	v.SetDefault("scheduler.default_cooldown_duration", cfg.Scheduler.DefaultCooldownDuration)
 	v.SetDefault("scheduler.auto_rotate_on_rate_limit", cfg.Scheduler.AutoRotateOnRateLimit)
+	v.SetDefault("scheduler.credential_expiry_warning", cfg.Scheduler.CredentialExpiryWarning)
+	v.SetDefault("scheduler.in_flight_timeout", cfg.Scheduler.InFlightTimeout)
+	v.SetDefault("scheduler.schedule_timezone", cfg.Scheduler.ScheduleTimezone)
+	v.SetDefault("scheduler.schedule_catch_up", cfg.Scheduler.ScheduleCatchUp)
+	v.SetDefault("scheduler.quiet_hours", cfg.Scheduler.QuietHours)
+	v.SetDefault("scheduler.file_lock_failure", cfg.Scheduler.FileLockFailure)
+
+	// Daemon
+	v.SetDefault("daemon.config_watch_interval", cfg.Daemon.ConfigWatchInterval)
+	v.SetDefault("daemon.schedule_interval", cfg.Daemon.ScheduleInterval)
+	v.SetDefault("daemon.standby_interval", cfg.Daemon.StandbyInterval)
+	v.SetDefault("daemon.rate_limits.enabled", cfg.Daemon.RateLimits.Enabled)
+	v.SetDefault("daemon.transcript_compaction.older_than", cfg.Daemon.TranscriptCompaction.OlderThan)
+	v.SetDefault("daemon.transcript_compaction.interval", cfg.Daemon.TranscriptCompaction.Interval)
+	v.SetDefault("daemon.transcript_store.enabled", cfg.Daemon.TranscriptStore.Enabled)
+	v.SetDefault("daemon.transcript_store.max_age", cfg.Daemon.TranscriptStore.MaxAge)
+	v.SetDefault("daemon.transcript_store.max_entries", cfg.Daemon.TranscriptStore.MaxEntries)
+	v.SetDefault("daemon.transcript_store.cleanup_interval", cfg.Daemon.TranscriptStore.CleanupInterval)
+	v.SetDefault("daemon.session_gc.interval", cfg.Daemon.SessionGC.Interval)
+	v.SetDefault("daemon.session_gc.older_than", cfg.Daemon.SessionGC.OlderThan)
+	v.SetDefault("daemon.session_gc.kill", cfg.Daemon.SessionGC.Kill)
+	v.SetDefault("daemon.transcript_backfill_max_bytes", cfg.Daemon.TranscriptBackfillMaxBytes)
+	v.SetDefault("daemon.transcript_max_entries", cfg.Daemon.TranscriptMaxEntries)
+	v.SetDefault("daemon.transcript_max_bytes", cfg.Daemon.TranscriptMaxBytes)
+	v.SetDefault("daemon.sensitive_input_window", cfg.Daemon.SensitiveInputWindow)
+	v.SetDefault("daemon.pane_poll_max_interval", cfg.Daemon.PanePollMaxInterval)
+	v.SetDefault("daemon.execution_backend", cfg.Daemon.ExecutionBackend)
+
+	// Event bridge
+	v.SetDefault("event_bridge.enabled", cfg.EventBridge.Enabled)
+	v.SetDefault("event_bridge.backend", cfg.EventBridge.Backend)
+	v.SetDefault("event_bridge.url", cfg.EventBridge.URL)
+	v.SetDefault("event_bridge.subject_prefix", cfg.EventBridge.SubjectPrefix)
+	v.SetDefault("event_bridge.buffer_size", cfg.EventBridge.BufferSize)
+	v.SetDefault("event_bridge.batch_size", cfg.EventBridge.BatchSize)
+	v.SetDefault("event_bridge.poll_interval", cfg.EventBridge.PollInterval)
+	v.SetDefault("event_bridge.publish_timeout", cfg.EventBridge.PublishTimeout)
+
+	// Audit retention
+	v.SetDefault("audit_retention.enabled", cfg.AuditRetention.Enabled)
+	v.SetDefault("audit_retention.max_age", cfg.AuditRetention.MaxAge)
+	v.SetDefault("audit_retention.max_count", cfg.AuditRetention.MaxCount)
+	v.SetDefault("audit_retention.cleanup_interval", cfg.AuditRetention.CleanupInterval)
+	v.SetDefault("audit_retention.batch_size", cfg.AuditRetention.BatchSize)
+
+	// Usage retention
+	v.SetDefault("usage_retention.max_age", cfg.UsageRetention.MaxAge)
+	v.SetDefault("usage_retention.cleanup_interval", cfg.UsageRetention.CleanupInterval)
+
+	// Review
+	v.SetDefault("review.max_diff_bytes", cfg.Review.MaxDiffBytes)
+
+	// Budget
+	v.SetDefault("budget.daily_ceiling_cents", cfg.Budget.DailyCeilingCents)
+	v.SetDefault("budget.mode", cfg.Budget.Mode)
+	v.SetDefault("budget.cost_timezone", cfg.Budget.CostTimezone)
 
 	// TUI
 	v.SetDefault("tui.refresh_interval", cfg.TUI.RefreshInterval)
 	v.SetDefault("tui.theme", cfg.TUI.Theme)
 	v.SetDefault("tui.show_timestamps", cfg.TUI.ShowTimestamps)
 	v.SetDefault("tui.compact_mode", cfg.TUI.CompactMode)
+
+	// Redaction
+	v.SetDefault("redaction.enabled", cfg.Redaction.Enabled)
+
+	// Encryption
+	v.SetDefault("encrypted", cfg.Encrypted)
+	v.SetDefault("encryption.identity_file", cfg.Encryption.IdentityFile)
 }
 
 // loadConfigFile attempts to load the configuration file.
 func (l *Loader) loadConfigFile() error {
 	if l.configFile != "" {
 		l.v.SetConfigFile(l.configFile)
+		if IsEncryptedConfigPath(l.configFile) {
+			return l.readEncryptedConfig(l.configFile)
+		}
 	}
 
 	if err := l.v.ReadInConfig(); err != nil {
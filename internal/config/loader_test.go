@@ -3,7 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
 )
 
 func TestLoadDefault(t *testing.T) {
@@ -104,12 +108,157 @@ func TestValidation(t *testing.T) {
 		t.Error("Expected validation error for max_connections = 0")
 	}
 
+	// The postgres backend is not wired up yet; unknown backends are rejected
+	cfg = DefaultConfig()
+	cfg.Database.Backend = DatabaseBackendPostgres
+	cfg.Database.PostgresDSN = "postgres://swarm@localhost/swarm"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for postgres backend")
+	}
+	cfg.Database.Backend = "mysql"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for unknown database backend")
+	}
+
+	// A stored transcript is pruned at least every minute
+	cfg = DefaultConfig()
+	cfg.Daemon.TranscriptStore.Enabled = true
+	cfg.Daemon.TranscriptStore.CleanupInterval = time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for transcript_store.cleanup_interval < 1m")
+	}
+
 	// Reset and test invalid polling interval
 	cfg = DefaultConfig()
 	cfg.AgentDefaults.StatePollingInterval = 0
 	if err := cfg.Validate(); err == nil {
 		t.Error("Expected validation error for state_polling_interval = 0")
 	}
+
+	// Invalid redaction pattern
+	cfg = DefaultConfig()
+	cfg.Redaction.Patterns = []RedactionPattern{{Label: "bad", Pattern: "("}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for invalid redaction pattern")
+	}
+
+	// Pinned placement needs pin labels
+	cfg = DefaultConfig()
+	cfg.WorkspaceDefaults.Placement = models.PlacementPinnedByLabel
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for pinned-by-label without pin_labels")
+	}
+	cfg.WorkspaceDefaults.PinLabels = map[string]string{"role": "build"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Pinned placement with labels failed validation: %v", err)
+	}
+
+	// Bootstrap files stay inside the repo and have one source of content
+	for _, file := range []BootstrapFileConfig{
+		{Path: "../AGENTS.md", Content: "x"},
+		{Path: "/etc/AGENTS.md", Content: "x"},
+		{Path: "AGENTS.md"},
+		{Path: "AGENTS.md", Content: "x", Source: "agents.md"},
+		{Path: "AGENTS.md", Content: "{{.Name"},
+	} {
+		cfg = DefaultConfig()
+		cfg.WorkspaceDefaults.BootstrapFiles = []BootstrapFileConfig{file}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected validation error for bootstrap file %+v", file)
+		}
+	}
+	cfg = DefaultConfig()
+	cfg.WorkspaceDefaults.Bootstrap = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for bootstrap without bootstrap_files")
+	}
+	cfg.WorkspaceDefaults.BootstrapFiles = []BootstrapFileConfig{
+		{Path: "AGENTS.md", Content: "# {{.Name}}"},
+		{Path: "prompts/start.md", Source: "start.md"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Bootstrap files failed validation: %v", err)
+	}
+
+	// Unknown stuck recovery action
+	cfg = DefaultConfig()
+	cfg.AgentDefaults.StuckEscalation = []StuckEscalationStep{{Action: "reboot"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for unknown stuck_escalation action")
+	}
+
+	// Minimum CLI versions need a known agent type and a version
+	cfg = DefaultConfig()
+	cfg.AgentDefaults.MinCLIVersions = map[string]string{"claude-code": "v1.0.17"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Valid min_cli_versions failed validation: %v", err)
+	}
+	cfg.AgentDefaults.MinCLIVersions = map[string]string{"aider": "0.80.0"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for unknown min_cli_versions agent type")
+	}
+	cfg.AgentDefaults.MinCLIVersions = map[string]string{"codex": "latest"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for min_cli_versions without a version")
+	}
+
+	// Failure rules need a valid pattern and a known category
+	cfg = DefaultConfig()
+	cfg.AgentDefaults.FailureRules = []FailureRule{{Pattern: "sandbox denied", Category: "task", Action: "wait"}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Valid failure rule failed validation: %v", err)
+	}
+	cfg.AgentDefaults.FailureRules = []FailureRule{{Pattern: "(", Category: "task"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for invalid failure_rules pattern")
+	}
+	cfg.AgentDefaults.FailureRules = []FailureRule{{Pattern: "disk full", Category: "disk"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for unknown failure_rules category")
+	}
+	cfg.AgentDefaults.FailureRules = []FailureRule{{AgentType: "aider", Pattern: "x", Category: "task"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for unknown failure_rules agent_type")
+	}
+}
+
+func TestStuckDetectionFromFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+agent_defaults:
+  stuck_timeouts:
+    codex: 20m
+  stuck_escalation:
+    - action: enter
+    - action: restart
+      after: 5m
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+
+	if cfg.AgentDefaults.StuckTimeout != 10*time.Minute {
+		t.Errorf("Expected default stuck_timeout = 10m, got %s", cfg.AgentDefaults.StuckTimeout)
+	}
+	if cfg.AgentDefaults.StuckTimeouts["codex"] != 20*time.Minute {
+		t.Errorf("Expected stuck_timeouts.codex = 20m, got %v", cfg.AgentDefaults.StuckTimeouts)
+	}
+	want := []StuckEscalationStep{{Action: "enter"}, {Action: "restart", After: 5 * time.Minute}}
+	if len(cfg.AgentDefaults.StuckEscalation) != len(want) {
+		t.Fatalf("Expected %d escalation steps, got %+v", len(want), cfg.AgentDefaults.StuckEscalation)
+	}
+	for i, step := range want {
+		if cfg.AgentDefaults.StuckEscalation[i] != step {
+			t.Errorf("Expected step %d = %+v, got %+v", i, step, cfg.AgentDefaults.StuckEscalation[i])
+		}
+	}
 }
 
 func TestConfigFileNotFound(t *testing.T) {
@@ -167,6 +316,14 @@ func TestEnsureDirectories(t *testing.T) {
 	if _, err := os.Stat(cfg.Global.ConfigDir); os.IsNotExist(err) {
 		t.Error("ConfigDir was not created")
 	}
+
+	if runtime.GOOS != "windows" {
+		for _, dir := range []string{cfg.Global.DataDir, cfg.Global.ConfigDir} {
+			if info, err := os.Stat(dir); err == nil && info.Mode().Perm() != 0o700 {
+				t.Errorf("%s has mode %04o, want 0700", dir, info.Mode().Perm())
+			}
+		}
+	}
 }
 
 func TestTUIThemeValidation(t *testing.T) {
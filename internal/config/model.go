@@ -0,0 +1,25 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ModelForWorkspace resolves the default model for an agent type. The first
+// matching workspace override with an entry for the type wins; otherwise
+// agent_defaults.models applies. Empty means the CLI's own default.
+func (c *Config) ModelForWorkspace(ws *models.Workspace, agentType models.AgentType) string {
+	if ws != nil {
+		for _, override := range c.WorkspaceOverrides {
+			if !override.matchesWorkspace(ws) {
+				continue
+			}
+			if model := strings.TrimSpace(override.Models[string(agentType)]); model != "" {
+				return model
+			}
+		}
+	}
+
+	return strings.TrimSpace(c.AgentDefaults.Models[string(agentType)])
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestModelForWorkspace(t *testing.T) {
+	cfg := DefaultConfig()
+	ws := &models.Workspace{ID: "ws-1", Name: "alpha", RepoPath: "/tmp/alpha"}
+
+	if model := cfg.ModelForWorkspace(ws, models.AgentTypeClaudeCode); model != "" {
+		t.Fatalf("expected no default model, got %q", model)
+	}
+
+	cfg.AgentDefaults.Models = map[string]string{
+		string(models.AgentTypeClaudeCode): "sonnet",
+		string(models.AgentTypeCodex):      "gpt-5-codex",
+	}
+	cfg.WorkspaceOverrides = []WorkspaceOverrideConfig{
+		{Name: "beta", Models: map[string]string{string(models.AgentTypeClaudeCode): "haiku"}},
+		{RepoPath: "/tmp/*", Models: map[string]string{string(models.AgentTypeClaudeCode): "opus"}},
+	}
+
+	if model := cfg.ModelForWorkspace(ws, models.AgentTypeClaudeCode); model != "opus" {
+		t.Fatalf("expected workspace override opus, got %q", model)
+	}
+	if model := cfg.ModelForWorkspace(ws, models.AgentTypeCodex); model != "gpt-5-codex" {
+		t.Fatalf("expected default codex model, got %q", model)
+	}
+	if model := cfg.ModelForWorkspace(nil, models.AgentTypeClaudeCode); model != "sonnet" {
+		t.Fatalf("expected default model without workspace, got %q", model)
+	}
+	if model := cfg.ModelForWorkspace(ws, models.AgentTypeGemini); model != "" {
+		t.Fatalf("expected no gemini model, got %q", model)
+	}
+}
@@ -0,0 +1,18 @@
+package config
+
+import "github.com/opencode-ai/swarm/internal/models"
+
+// QueueCommandsEnabled reports whether command queue items may run for
+// agents in a workspace. They are off unless a matching workspace override
+// sets queue_commands.
+func (c *Config) QueueCommandsEnabled(ws *models.Workspace) bool {
+	if ws == nil {
+		return false
+	}
+	for _, override := range c.WorkspaceOverrides {
+		if override.QueueCommands && override.matchesWorkspace(ws) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestQueueCommandsEnabled(t *testing.T) {
+	cfg := DefaultConfig()
+	ws := &models.Workspace{ID: "ws-1", Name: "alpha", RepoPath: "/tmp/alpha"}
+
+	if cfg.QueueCommandsEnabled(ws) {
+		t.Fatal("expected queue commands to be disabled by default")
+	}
+
+	cfg.WorkspaceOverrides = []WorkspaceOverrideConfig{
+		{Name: "beta", QueueCommands: true},
+		{Name: "alpha", ApprovalPolicy: ApprovalPolicyPermissive},
+	}
+	if cfg.QueueCommandsEnabled(ws) {
+		t.Fatal("expected queue commands to stay disabled without a matching override")
+	}
+
+	cfg.WorkspaceOverrides = append(cfg.WorkspaceOverrides, WorkspaceOverrideConfig{RepoPath: "/tmp/*", QueueCommands: true})
+	if !cfg.QueueCommandsEnabled(ws) {
+		t.Fatal("expected a matching override to enable queue commands")
+	}
+	if cfg.QueueCommandsEnabled(nil) {
+		t.Fatal("expected queue commands to be disabled without a workspace")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected queue_commands-only override to validate: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestReviewConfigFromFile(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Review.MaxDiffBytes != models.DefaultReviewMaxDiffBytes || cfg.Review.PromptTemplate != "" {
+		t.Fatalf("unexpected default review config %+v", cfg.Review)
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+review:
+  max_diff_bytes: 4096
+  prompt_template: |
+    Review {{.Source}}:
+    {{.Diff}}
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	if cfg.Review.MaxDiffBytes != 4096 || !strings.HasPrefix(cfg.Review.PromptTemplate, "Review {{.Source}}:") {
+		t.Fatalf("unexpected review config %+v", cfg.Review)
+	}
+}
+
+func TestValidateReview(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"negative max diff bytes", func(c *Config) { c.Review.MaxDiffBytes = -1 }, "review.max_diff_bytes"},
+		{"unparseable template", func(c *Config) { c.Review.PromptTemplate = "{{.Diff" }, "review.prompt_template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
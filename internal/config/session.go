@@ -0,0 +1,13 @@
+package config
+
+import "github.com/opencode-ai/swarm/internal/models"
+
+// SessionPathOverrides returns agent_defaults.session_paths keyed by agent
+// type. Types without an entry keep their adapter's default patterns.
+func (c *Config) SessionPathOverrides() map[models.AgentType][]string {
+	overrides := make(map[models.AgentType][]string, len(c.AgentDefaults.SessionPaths))
+	for agentType, patterns := range c.AgentDefaults.SessionPaths {
+		overrides[models.AgentType(agentType)] = patterns
+	}
+	return overrides
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestSessionPathOverrides(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.SessionPathOverrides(); len(got) != 0 {
+		t.Fatalf("expected no overrides by default, got %v", got)
+	}
+
+	cfg.AgentDefaults.SessionPaths = map[string][]string{"codex": {"{home}/.codex/sessions/*/*/*.jsonl"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected session paths to validate: %v", err)
+	}
+	got := cfg.SessionPathOverrides()[models.AgentTypeCodex]
+	if len(got) != 1 || got[0] != "{home}/.codex/sessions/*/*/*.jsonl" {
+		t.Fatalf("unexpected codex patterns %v", got)
+	}
+}
+
+func TestValidateSessionPaths(t *testing.T) {
+	tests := map[string]struct {
+		paths map[string][]string
+		want  string
+	}{
+		"unknown type": {map[string][]string{"vim": {"{home}/x"}}, "unknown agent type"},
+		"empty":        {map[string][]string{"codex": {" "}}, "must not be empty"},
+		"bad glob":     {map[string][]string{"codex": {"{home}/["}}, "not a valid glob"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.AgentDefaults.SessionPaths = tt.paths
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import "github.com/opencode-ai/swarm/internal/models"
+
+// StandbyPoolsForWorkspace returns the standby pools configured for a
+// workspace by the first matching override that defines any.
+func (c *Config) StandbyPoolsForWorkspace(ws *models.Workspace) []StandbyPoolConfig {
+	if ws == nil {
+		return nil
+	}
+	for _, override := range c.WorkspaceOverrides {
+		if len(override.Standby) > 0 && override.matchesWorkspace(ws) {
+			return override.Standby
+		}
+	}
+	return nil
+}
+
+// MaxAgentsForWorkspace returns the cap on agents standby pools may bring a
+// workspace up to, or 0 when there is none.
+func (c *Config) MaxAgentsForWorkspace(ws *models.Workspace) int {
+	if ws == nil {
+		return 0
+	}
+	for _, override := range c.WorkspaceOverrides {
+		if override.MaxAgents > 0 && override.matchesWorkspace(ws) {
+			return override.MaxAgents
+		}
+	}
+	return 0
+}
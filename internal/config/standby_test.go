@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestStandbyPoolsForWorkspace(t *testing.T) {
+	cfg := DefaultConfig()
+	ws := &models.Workspace{ID: "ws-1", Name: "alpha", RepoPath: "/tmp/alpha"}
+
+	if pools := cfg.StandbyPoolsForWorkspace(ws); len(pools) != 0 {
+		t.Fatalf("expected no standby pools by default, got %v", pools)
+	}
+
+	cfg.WorkspaceOverrides = []WorkspaceOverrideConfig{
+		{Name: "beta", Standby: []StandbyPoolConfig{{AgentType: "codex", Count: 1}}},
+		{Name: "alpha", MaxAgents: 4, QueueCommands: true},
+		{RepoPath: "/tmp/*", Standby: []StandbyPoolConfig{{AgentType: "opencode", Count: 2, Model: "fast"}}},
+		{Name: "alpha", Standby: []StandbyPoolConfig{{AgentType: "gemini", Count: 1}}},
+	}
+	pools := cfg.StandbyPoolsForWorkspace(ws)
+	if len(pools) != 1 || pools[0].AgentType != "opencode" || pools[0].Count != 2 || pools[0].Model != "fast" {
+		t.Fatalf("expected the first matching pool list, got %v", pools)
+	}
+	if got := cfg.MaxAgentsForWorkspace(ws); got != 4 {
+		t.Fatalf("expected max agents 4, got %d", got)
+	}
+	if cfg.StandbyPoolsForWorkspace(nil) != nil || cfg.MaxAgentsForWorkspace(nil) != 0 {
+		t.Fatal("expected no pools or cap without a workspace")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected standby overrides to validate: %v", err)
+	}
+}
+
+func TestValidateStandbyPools(t *testing.T) {
+	tests := []struct {
+		name string
+		pool StandbyPoolConfig
+		want string
+	}{
+		{"unknown type", StandbyPoolConfig{AgentType: "robot", Count: 1}, "agent_type"},
+		{"zero count", StandbyPoolConfig{AgentType: "opencode"}, "count"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.WorkspaceOverrides = []WorkspaceOverrideConfig{{Name: "alpha", Standby: []StandbyPoolConfig{tt.pool}}}
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), "standby[0]."+tt.want) {
+				t.Fatalf("expected standby[0].%s error, got %v", tt.want, err)
+			}
+		})
+	}
+}
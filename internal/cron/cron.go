@@ -0,0 +1,269 @@
+// Package cron parses standard 5-field cron expressions and computes their
+// run times.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchYears bounds Next for expressions that can never match, such as
+// February 30th.
+const maxSearchYears = 5
+
+// Expression is a parsed cron expression:
+//
+//	minute hour day-of-month month day-of-week
+//
+// Fields accept *, values, ranges (a-b), lists (a,b) and steps (*/n, a-b/n,
+// a/n). Months and weekdays accept three-letter names; weekday 7 is Sunday.
+// When both day fields are restricted a day matches if either does, as in
+// Vixie cron. The macros @yearly, @annually, @monthly, @weekly, @daily,
+// @midnight, and @hourly are also accepted.
+type Expression struct {
+	source string
+
+	minute, hour, dom, month, dow bitset
+
+	// domStar and dowStar record fields written as "*" (with or without a
+	// step), which do not take part in the either-day-matches rule.
+	domStar, dowStar bool
+}
+
+type bitset uint64
+
+func (b bitset) has(n int) bool {
+	return b&(1<<uint(n)) != 0
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day-of-month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{name: "day-of-week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a 5-field cron expression or macro.
+func Parse(expr string) (*Expression, error) {
+	source := strings.TrimSpace(expr)
+	spec := source
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := macros[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron macro %q", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields (minute hour day-of-month month day-of-week), got %d", source, len(fields))
+	}
+
+	e := &Expression{source: source}
+	var err error
+	if e.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if e.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if e.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if e.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if e.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	// Sunday may be written as 0 or 7.
+	if e.dow.has(7) {
+		e.dow |= 1
+	}
+	e.domStar = strings.HasPrefix(fields[2], "*")
+	e.dowStar = strings.HasPrefix(fields[4], "*")
+	return e, nil
+}
+
+// String returns the expression as it was written.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Next returns the first run time strictly after after, evaluated on the
+// wall clock of after's location. It returns the zero time if the
+// expression matches nothing in the next five years.
+//
+// Daylight saving transitions follow the wall clock: a time skipped when
+// clocks spring forward runs the same distance past the jump (02:30 becomes
+// 03:30 when 02:00 jumps to 03:00), and a time repeated when clocks fall
+// back runs once, at its first occurrence.
+func (e *Expression) Next(after time.Time) time.Time {
+	loc := after.Location()
+
+	// Search on a zone-free copy of the wall clock so DST never shifts the
+	// fields being matched.
+	wall := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, time.UTC).Add(time.Minute)
+	limit := wall.AddDate(maxSearchYears, 0, 0)
+
+	for wall.Before(limit) {
+		if !e.month.has(int(wall.Month())) {
+			wall = time.Date(wall.Year(), wall.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !e.dayMatches(wall) {
+			wall = time.Date(wall.Year(), wall.Month(), wall.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !e.hour.has(wall.Hour()) {
+			wall = wall.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !e.minute.has(wall.Minute()) {
+			wall = wall.Add(time.Minute)
+			continue
+		}
+
+		if t := resolveWall(wall, loc); t.After(after) {
+			return t
+		}
+		wall = wall.Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+// NextN returns the next n run times after after.
+func (e *Expression) NextN(after time.Time, n int) []time.Time {
+	times := make([]time.Time, 0, n)
+	for len(times) < n {
+		next := e.Next(after)
+		if next.IsZero() {
+			break
+		}
+		times = append(times, next)
+		after = next
+	}
+	return times
+}
+
+func (e *Expression) dayMatches(wall time.Time) bool {
+	domOK := e.dom.has(wall.Day())
+	dowOK := e.dow.has(int(wall.Weekday()))
+	if !e.domStar && !e.dowStar {
+		return domOK || dowOK
+	}
+	return domOK && dowOK
+}
+
+// resolveWall converts a wall-clock time in loc to an instant. time.Date
+// resolves a wall time inside a spring-forward gap to before the gap; it is
+// moved forward by the gap instead.
+func resolveWall(wall time.Time, loc *time.Location) time.Time {
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, loc)
+	if t.Hour() == wall.Hour() && t.Minute() == wall.Minute() {
+		return t
+	}
+	_, offset := t.Zone()
+	return wall.Add(-time.Duration(offset) * time.Second).In(loc)
+}
+
+func (f field) parse(spec string) (bitset, error) {
+	var set bitset
+	for _, part := range strings.Split(spec, ",") {
+		bits, err := f.parsePart(part)
+		if err != nil {
+			return 0, fmt.Errorf("%s field %q: %w", f.name, spec, err)
+		}
+		set |= bits
+	}
+	return set, nil
+}
+
+func (f field) parsePart(part string) (bitset, error) {
+	if part == "" {
+		return 0, fmt.Errorf("empty list item")
+	}
+
+	rangeSpec, stepSpec, hasStep := strings.Cut(part, "/")
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepSpec)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid step %q", stepSpec)
+		}
+		step = n
+	}
+
+	var lo, hi int
+	switch {
+	case rangeSpec == "*":
+		lo, hi = f.min, f.max
+	case strings.Contains(rangeSpec, "-"):
+		loSpec, hiSpec, _ := strings.Cut(rangeSpec, "-")
+		var err error
+		if lo, err = f.value(loSpec); err != nil {
+			return 0, err
+		}
+		if hi, err = f.value(hiSpec); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("range %d-%d is reversed", lo, hi)
+		}
+	default:
+		value, err := f.value(rangeSpec)
+		if err != nil {
+			return 0, err
+		}
+		lo, hi = value, value
+		// "a/n" means every n starting at a.
+		if hasStep {
+			hi = f.max
+		}
+	}
+
+	var set bitset
+	for v := lo; v <= hi; v += step {
+		set |= 1 << uint(v)
+	}
+	return set, nil
+}
+
+func (f field) value(spec string) (int, error) {
+	if n, ok := f.names[strings.ToLower(spec)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(spec)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", spec)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, f.min, f.max)
+	}
+	return n, nil
+}
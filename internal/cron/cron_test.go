@@ -0,0 +1,183 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // fixed zone data for the DST cases
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "must have 5 fields"},
+		{"* * * *", "must have 5 fields"},
+		{"* * * * * *", "must have 5 fields"},
+		{"60 * * * *", "minute field"},
+		{"* 24 * * *", "hour field"},
+		{"* * 0 * *", "day-of-month field"},
+		{"* * 32 * *", "out of range 1-31"},
+		{"* * * 13 *", "month field"},
+		{"* * * * 8", "day-of-week field"},
+		{"5-1 * * * *", "reversed"},
+		{"* * * dec-feb *", "reversed"},
+		{"*/0 * * * *", "invalid step"},
+		{"*/x * * * *", "invalid step"},
+		{"1,,2 * * * *", "empty list item"},
+		{"abc * * * *", "invalid value"},
+		{"-5 * * * *", "invalid value"},
+		{"* * * foo *", "invalid value"},
+		{"@every 5m", "unknown cron macro"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if err == nil {
+			t.Errorf("Parse(%q): expected error", tt.expr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestNextUTC(t *testing.T) {
+	// 2026-10-14 is a Wednesday.
+	base := time.Date(2026, 10, 14, 8, 30, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		year := 2026
+		if month < time.October || (month == time.October && day < 14) {
+			year = 2027
+		}
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"* * * * *", base, at(10, 14, 8, 31)},
+		{"* * * * *", base.Add(59 * time.Second), at(10, 14, 8, 31)},
+		{"0 9 * * 1-5", base, at(10, 14, 9, 0)},
+		{"0 9 * * 1-5", at(10, 14, 9, 0), at(10, 15, 9, 0)},
+		{"0 9 * * 1-5", at(10, 16, 9, 0), at(10, 19, 9, 0)}, // Friday -> Monday
+		{"0 9 * * mon-fri", at(10, 16, 9, 0), at(10, 19, 9, 0)},
+		{"*/15 * * * *", base, at(10, 14, 8, 45)},
+		{"5/20 * * * *", base, at(10, 14, 8, 45)},
+		{"0-10/5 * * * *", base, at(10, 14, 9, 0)},
+		{"1,2,40 * * * *", base, at(10, 14, 8, 40)},
+		{"30 8 * * *", base, at(10, 15, 8, 30)},
+		{"0 0 1 * *", base, at(11, 1, 0, 0)},
+		{"0 0 31 * *", base, at(10, 31, 0, 0)},
+		{"0 0 31 * *", at(10, 31, 0, 0), at(12, 31, 0, 0)}, // November has 30 days
+		{"0 0 29 2 *", base, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * jan *", base, at(1, 1, 12, 0)},
+		{"0 12 * nov-dec *", base, at(11, 1, 12, 0)},
+		{"0 0 * * 0", base, at(10, 18, 0, 0)},
+		{"0 0 * * 7", base, at(10, 18, 0, 0)},
+		{"0 0 * * sun", base, at(10, 18, 0, 0)},
+		// Both day fields restricted: either matches.
+		{"0 0 1 * 5", base, at(10, 16, 0, 0)},
+		{"0 0 15 * 1", base, at(10, 15, 0, 0)},
+		// A starred day field with a step still requires both to match.
+		{"0 0 */2 * 1", base, at(10, 19, 0, 0)},
+		{"@hourly", base, at(10, 14, 9, 0)},
+		{"@daily", base, at(10, 15, 0, 0)},
+		{"@midnight", base, at(10, 15, 0, 0)},
+		{"@weekly", base, at(10, 18, 0, 0)},
+		{"@monthly", base, at(11, 1, 0, 0)},
+		{"@yearly", base, at(1, 1, 0, 0)},
+		{"@ANNUALLY", base, at(1, 1, 0, 0)},
+		{"59 23 31 12 *", base, at(12, 31, 23, 59)},
+		{"0 0 30 2 *", base, time.Time{}}, // never
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got := e.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next(%s) = %s, want %s", tt.expr, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load zone: %v", err)
+	}
+	local := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, ny)
+	}
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	// Clocks spring forward at 2026-03-08 02:00 EST (07:00 UTC) and fall
+	// back at 2026-11-01 02:00 EDT (06:00 UTC).
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"daily across spring forward keeps wall time", "0 9 * * *", local(3, 7, 9, 0), utc(3, 8, 13, 0)},
+		{"daily across fall back keeps wall time", "0 9 * * *", local(10, 31, 9, 0), utc(11, 1, 14, 0)},
+		{"skipped time runs past the jump", "30 2 * * *", local(3, 8, 0, 0), utc(3, 8, 7, 30)},
+		{"skipped time on the hour runs at the jump", "0 2 * * *", local(3, 8, 1, 59), utc(3, 8, 7, 0)},
+		{"skipped day resumes next day", "30 2 * * *", utc(3, 8, 7, 30).In(ny), utc(3, 9, 6, 30)},
+		{"hourly skips the missing hour", "0 * * * *", local(3, 8, 1, 0), utc(3, 8, 7, 0)},
+		{"hourly after the jump", "0 * * * *", utc(3, 8, 7, 0).In(ny), utc(3, 8, 8, 0)},
+		{"repeated time runs at its first occurrence", "30 1 * * *", local(11, 1, 0, 0), utc(11, 1, 5, 30)},
+		{"repeated time does not run twice", "30 1 * * *", utc(11, 1, 5, 30).In(ny), utc(11, 2, 6, 30)},
+		{"minutely in the repeated hour stops after the first pass", "*/15 * * * *", utc(11, 1, 5, 45).In(ny), utc(11, 1, 7, 0)},
+		{"second pass of the repeated hour", "*/15 * * * *", utc(11, 1, 6, 10).In(ny), utc(11, 1, 7, 0)},
+		{"weekday 9am across spring forward", "0 9 * * 1-5", local(3, 6, 9, 0), utc(3, 9, 13, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			got := e.Next(tt.after)
+			if !got.Equal(tt.want) {
+				t.Fatalf("Next(%s) = %s (%s), want %s", tt.after, got.UTC(), got, tt.want)
+			}
+			if got.Location() != ny {
+				t.Fatalf("Next returned location %s, want %s", got.Location(), ny)
+			}
+		})
+	}
+}
+
+func TestNextN(t *testing.T) {
+	e, err := Parse("0 9 * * 1-5")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// Friday 2026-10-16 10:00.
+	got := e.NextN(time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC), 3)
+	want := []time.Time{
+		time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("NextN returned %d times, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("NextN[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	never, _ := Parse("0 0 30 2 *")
+	if times := never.NextN(time.Now(), 3); len(times) != 0 {
+		t.Errorf("expected no run times, got %v", times)
+	}
+}
@@ -31,6 +31,7 @@ func NewAccountRepository(db *DB) *AccountRepository {
 
 // Create adds a new account to the database.
 func (r *AccountRepository) Create(ctx context.Context, account *models.Account) error {
+	normalizeAccountProvider(account)
 	if err := account.Validate(); err != nil {
 		return fmt.Errorf("invalid account: %w", err)
 	}
@@ -67,8 +68,9 @@ func (r *AccountRepository) Create(ctx context.Context, account *models.Account)
 	_, err := r.db.ExecContext(ctx, `
 		INSERT INTO accounts (
 			id, provider, profile_name, credential_ref, is_active,
-			cooldown_until, usage_stats_json, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			cooldown_until, usage_stats_json, created_at, updated_at,
+			credential_expires_at, credential_expiry_warned_at, provider_name
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		account.ID,
 		string(account.Provider),
@@ -79,6 +81,9 @@ func (r *AccountRepository) Create(ctx context.Context, account *models.Account)
 		usageStatsJSON,
 		account.CreatedAt.Format(time.RFC3339),
 		account.UpdatedAt.Format(time.RFC3339),
+		formatAccountTimePtr(account.CredentialExpiresAt),
+		formatAccountTimePtr(account.CredentialExpiryWarnedAt),
+		nullString(account.ProviderName),
 	)
 
 	if err != nil {
@@ -100,7 +105,8 @@ func (r *AccountRepository) List(ctx context.Context, provider *models.Provider)
 		rows, err = r.db.QueryContext(ctx, `
 			SELECT 
 				id, provider, profile_name, credential_ref, is_active,
-				cooldown_until, usage_stats_json, created_at, updated_at
+				cooldown_until, usage_stats_json, created_at, updated_at,
+			credential_expires_at, credential_expiry_warned_at, provider_name
 			FROM accounts
 			WHERE provider = ?
 			ORDER BY profile_name
@@ -109,7 +115,8 @@ func (r *AccountRepository) List(ctx context.Context, provider *models.Provider)
 		rows, err = r.db.QueryContext(ctx, `
 			SELECT 
 				id, provider, profile_name, credential_ref, is_active,
-				cooldown_until, usage_stats_json, created_at, updated_at
+				cooldown_until, usage_stats_json, created_at, updated_at,
+			credential_expires_at, credential_expiry_warned_at, provider_name
 			FROM accounts
 			ORDER BY provider, profile_name
 		`)
@@ -141,7 +148,8 @@ func (r *AccountRepository) Get(ctx context.Context, id string) (*models.Account
 	row := r.db.QueryRowContext(ctx, `
 		SELECT
 			id, provider, profile_name, credential_ref, is_active,
-			cooldown_until, usage_stats_json, created_at, updated_at
+			cooldown_until, usage_stats_json, created_at, updated_at,
+			credential_expires_at, credential_expiry_warned_at, provider_name
 		FROM accounts
 		WHERE id = ?
 	`, id)
@@ -151,6 +159,7 @@ func (r *AccountRepository) Get(ctx context.Context, id string) (*models.Account
 
 // Update modifies an existing account.
 func (r *AccountRepository) Update(ctx context.Context, account *models.Account) error {
+	normalizeAccountProvider(account)
 	if err := account.Validate(); err != nil {
 		return fmt.Errorf("invalid account: %w", err)
 	}
@@ -181,6 +190,9 @@ func (r *AccountRepository) Update(ctx context.Context, account *models.Account)
 			is_active = ?,
 			cooldown_until = ?,
 			usage_stats_json = ?,
+			credential_expires_at = ?,
+			credential_expiry_warned_at = ?,
+			provider_name = ?,
 			updated_at = ?
 		WHERE id = ?
 	`,
@@ -190,6 +202,9 @@ func (r *AccountRepository) Update(ctx context.Context, account *models.Account)
 		boolToInt(account.IsActive),
 		cooldownUntil,
 		usageStatsJSON,
+		formatAccountTimePtr(account.CredentialExpiresAt),
+		formatAccountTimePtr(account.CredentialExpiryWarnedAt),
+		nullString(account.ProviderName),
 		account.UpdatedAt.Format(time.RFC3339),
 		account.ID,
 	)
@@ -287,6 +302,67 @@ func (r *AccountRepository) ClearCooldown(ctx context.Context, id string) error
 	return nil
 }
 
+// SetCredentialExpiry records when an account's credentials expire. A
+// changed expiry clears credential_expiry_warned_at, so refreshed
+// credentials are warned about again before they in turn expire.
+func (r *AccountRepository) SetCredentialExpiry(ctx context.Context, id string, expiresAt *time.Time) error {
+	now := time.Now().UTC()
+	value := formatAccountTimePtr(expiresAt)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE accounts SET
+			credential_expiry_warned_at = CASE
+				WHEN credential_expires_at IS ? THEN credential_expiry_warned_at
+				ELSE NULL
+			END,
+			credential_expires_at = ?,
+			updated_at = ?
+		WHERE id = ?
+	`, value, value, now.Format(time.RFC3339), id)
+
+	if err != nil {
+		return fmt.Errorf("failed to set credential expiry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAccountNotFound
+	}
+
+	return nil
+}
+
+// MarkCredentialExpiryWarned records that an account's upcoming expiry was
+// warned about. It reports false if the account was already marked, so
+// concurrent callers warn once between them.
+func (r *AccountRepository) MarkCredentialExpiryWarned(ctx context.Context, id string, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE accounts SET
+			credential_expiry_warned_at = ?
+		WHERE id = ? AND credential_expiry_warned_at IS NULL
+	`, at.UTC().Format(time.RFC3339), id)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to mark credential expiry warned: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		if _, err := r.Get(ctx, id); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
+
 // GetNextAvailable returns the next available account for a provider.
 func (r *AccountRepository) GetNextAvailable(ctx context.Context, provider models.Provider) (*models.Account, error) {
 	if provider == "" {
@@ -297,7 +373,8 @@ func (r *AccountRepository) GetNextAvailable(ctx context.Context, provider model
 	row := r.db.QueryRowContext(ctx, `
 		SELECT
 			id, provider, profile_name, credential_ref, is_active,
-			cooldown_until, usage_stats_json, created_at, updated_at
+			cooldown_until, usage_stats_json, created_at, updated_at,
+			credential_expires_at, credential_expiry_warned_at, provider_name
 		FROM accounts
 		WHERE provider = ?
 			AND is_active = 1
@@ -316,6 +393,7 @@ func (r *AccountRepository) scanAccount(row *sql.Row) (*models.Account, error) {
 	var cooldownUntil sql.NullString
 	var usageStatsJSON sql.NullString
 	var createdAt, updatedAt string
+	var expiresAt, expiryWarnedAt, providerName sql.NullString
 
 	err := row.Scan(
 		&account.ID,
@@ -327,6 +405,9 @@ func (r *AccountRepository) scanAccount(row *sql.Row) (*models.Account, error) {
 		&usageStatsJSON,
 		&createdAt,
 		&updatedAt,
+		&expiresAt,
+		&expiryWarnedAt,
+		&providerName,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -335,7 +416,8 @@ func (r *AccountRepository) scanAccount(row *sql.Row) (*models.Account, error) {
 		return nil, fmt.Errorf("failed to scan account: %w", err)
 	}
 
-	if err := r.populateAccountFields(&account, provider, isActive, cooldownUntil, usageStatsJSON, createdAt, updatedAt); err != nil {
+	account.ProviderName = providerName.String
+	if err := r.populateAccountFields(&account, provider, isActive, cooldownUntil, usageStatsJSON, createdAt, updatedAt, expiresAt, expiryWarnedAt); err != nil {
 		return nil, err
 	}
 
@@ -349,6 +431,7 @@ func (r *AccountRepository) scanAccountFromRows(rows *sql.Rows) (*models.Account
 	var cooldownUntil sql.NullString
 	var usageStatsJSON sql.NullString
 	var createdAt, updatedAt string
+	var expiresAt, expiryWarnedAt, providerName sql.NullString
 
 	if err := rows.Scan(
 		&account.ID,
@@ -360,11 +443,15 @@ func (r *AccountRepository) scanAccountFromRows(rows *sql.Rows) (*models.Account
 		&usageStatsJSON,
 		&createdAt,
 		&updatedAt,
+		&expiresAt,
+		&expiryWarnedAt,
+		&providerName,
 	); err != nil {
 		return nil, fmt.Errorf("failed to scan account: %w", err)
 	}
 
-	if err := r.populateAccountFields(&account, provider, isActive, cooldownUntil, usageStatsJSON, createdAt, updatedAt); err != nil {
+	account.ProviderName = providerName.String
+	if err := r.populateAccountFields(&account, provider, isActive, cooldownUntil, usageStatsJSON, createdAt, updatedAt, expiresAt, expiryWarnedAt); err != nil {
 		return nil, err
 	}
 
@@ -379,6 +466,8 @@ func (r *AccountRepository) populateAccountFields(
 	usageStatsJSON sql.NullString,
 	createdAt string,
 	updatedAt string,
+	expiresAt sql.NullString,
+	expiryWarnedAt sql.NullString,
 ) error {
 	account.Provider = models.Provider(provider)
 	account.IsActive = isActive != 0
@@ -411,9 +500,42 @@ func (r *AccountRepository) populateAccountFields(
 	account.CreatedAt = createdParsed
 	account.UpdatedAt = updatedParsed
 
+	if account.CredentialExpiresAt, err = parseAccountTimePtr(expiresAt); err != nil {
+		return fmt.Errorf("failed to parse credential_expires_at: %w", err)
+	}
+	if account.CredentialExpiryWarnedAt, err = parseAccountTimePtr(expiryWarnedAt); err != nil {
+		return fmt.Errorf("failed to parse credential_expiry_warned_at: %w", err)
+	}
+
 	return nil
 }
 
+// normalizeAccountProvider normalizes the case and whitespace of the
+// account's provider and custom provider name before it is validated.
+func normalizeAccountProvider(account *models.Account) {
+	account.Provider = models.NormalizeProvider(account.Provider)
+	account.ProviderName = models.NormalizeProviderName(account.ProviderName)
+}
+
+func formatAccountTimePtr(value *time.Time) *string {
+	if value == nil {
+		return nil
+	}
+	s := value.UTC().Format(time.RFC3339)
+	return &s
+}
+
+func parseAccountTimePtr(value sql.NullString) (*time.Time, error) {
+	if !value.Valid || value.String == "" {
+		return nil, nil
+	}
+	parsed, err := parseAccountTime(value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
 func parseAccountTime(value string) (time.Time, error) {
 	if value == "" {
 		return time.Time{}, fmt.Errorf("empty time value")
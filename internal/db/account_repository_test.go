@@ -249,3 +249,63 @@ func TestAccountRepository_GetNextAvailable(t *testing.T) {
 		t.Fatalf("expected ErrInvalidProvider, got %v", err)
 	}
 }
+
+func TestAccountRepository_CredentialExpiry(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	expires := time.Now().UTC().Add(2 * time.Hour).Truncate(time.Second)
+	account := &models.Account{
+		Provider:            models.ProviderAnthropic,
+		ProfileName:         "expiring",
+		CredentialRef:       "caam:claude/work@example.com",
+		IsActive:            true,
+		CredentialExpiresAt: &expires,
+	}
+	if err := repo.Create(ctx, account); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	got, err := repo.Get(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.CredentialExpiresAt == nil || !got.CredentialExpiresAt.Equal(expires) {
+		t.Fatalf("expected expiry %s, got %v", expires, got.CredentialExpiresAt)
+	}
+
+	marked, err := repo.MarkCredentialExpiryWarned(ctx, account.ID, time.Now())
+	if err != nil || !marked {
+		t.Fatalf("expected the first mark to win, got %v, %v", marked, err)
+	}
+	marked, err = repo.MarkCredentialExpiryWarned(ctx, account.ID, time.Now())
+	if err != nil || marked {
+		t.Fatalf("expected the second mark to lose, got %v, %v", marked, err)
+	}
+
+	// The same expiry keeps the mark; a new one clears it.
+	if err := repo.SetCredentialExpiry(ctx, account.ID, &expires); err != nil {
+		t.Fatalf("SetCredentialExpiry failed: %v", err)
+	}
+	if got, _ := repo.Get(ctx, account.ID); got.CredentialExpiryWarnedAt == nil {
+		t.Fatal("expected the warning kept for an unchanged expiry")
+	}
+	renewed := expires.Add(24 * time.Hour)
+	if err := repo.SetCredentialExpiry(ctx, account.ID, &renewed); err != nil {
+		t.Fatalf("SetCredentialExpiry failed: %v", err)
+	}
+	got, _ = repo.Get(ctx, account.ID)
+	if got.CredentialExpiryWarnedAt != nil {
+		t.Fatal("expected the warning cleared for a renewed expiry")
+	}
+	if !got.CredentialExpiresAt.Equal(renewed) {
+		t.Fatalf("expected expiry %s, got %v", renewed, got.CredentialExpiresAt)
+	}
+
+	if _, err := repo.MarkCredentialExpiryWarned(ctx, "missing", time.Now()); !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("expected ErrAccountNotFound, got %v", err)
+	}
+}
@@ -17,6 +17,7 @@ import (
 var (
 	ErrAgentNotFound      = errors.New("agent not found")
 	ErrAgentAlreadyExists = errors.New("agent with this workspace and pane already exists")
+	ErrNoStandbyAgent     = errors.New("no standby agent available")
 )
 
 // AgentRepository handles agent persistence.
@@ -28,6 +29,44 @@ type agentExecer interface {
 	ExecContext(context.Context, string, ...any) (sql.Result, error)
 }
 
+// AgentQueryOption adjusts which agents a query returns.
+type AgentQueryOption func(*agentQuery)
+
+type agentQuery struct {
+	includeDeleted bool
+}
+
+// IncludeDeleted makes a query also return terminated agents, which are
+// excluded by default.
+func IncludeDeleted() AgentQueryOption {
+	return func(q *agentQuery) {
+		q.includeDeleted = true
+	}
+}
+
+// IncludesDeleted reports whether opts make a query return terminated
+// agents, for other stores honouring the options.
+func IncludesDeleted(opts []AgentQueryOption) bool {
+	var q agentQuery
+	for _, opt := range opts {
+		opt(&q)
+	}
+	return q.includeDeleted
+}
+
+// liveAgentFilter returns the condition excluding terminated agents, for
+// the agents table under alias (empty for none), or "1 = 1" when opts
+// include them.
+func liveAgentFilter(alias string, opts []AgentQueryOption) string {
+	if IncludesDeleted(opts) {
+		return "1 = 1"
+	}
+	if alias != "" {
+		alias += "."
+	}
+	return alias + "deleted_at IS NULL"
+}
+
 // NewAgentRepository creates a new AgentRepository.
 func NewAgentRepository(db *DB) *AgentRepository {
 	return &AgentRepository{db: db}
@@ -65,19 +104,22 @@ func (r *AgentRepository) Create(ctx context.Context, agent *models.Agent) error
 	stateDetectedAt := timePtrFrom(agent.StateInfo.DetectedAt)
 	pausedUntil := stringTimePtr(agent.PausedUntil)
 	lastActivity := stringTimePtr(agent.LastActivity)
+	lastOutput := stringTimePtr(agent.LastOutputAt)
 
 	_, err = r.db.ExecContext(ctx, `
 		INSERT INTO agents (
-			id, workspace_id, type, tmux_pane, account_id,
+			id, workspace_id, type, tmux_pane, tmux_session, remote_agent_id, account_id,
 			state, state_confidence, state_reason, state_detected_at,
-			paused_until, last_activity_at, metadata_json,
+			paused_until, last_activity_at, last_output_at, metadata_json, standby,
 			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		agent.ID,
 		agent.WorkspaceID,
 		string(agent.Type),
 		agent.TmuxPane,
+		agent.TmuxSession,
+		agent.RemoteAgentID,
 		accountID,
 		string(agent.State),
 		string(agent.StateInfo.Confidence),
@@ -85,7 +127,9 @@ func (r *AgentRepository) Create(ctx context.Context, agent *models.Agent) error
 		stateDetectedAt,
 		pausedUntil,
 		lastActivity,
+		lastOutput,
 		string(metadataJSON),
+		boolToInt(agent.Standby),
 		agent.CreatedAt.Format(time.RFC3339),
 		agent.UpdatedAt.Format(time.RFC3339),
 	)
@@ -99,29 +143,30 @@ func (r *AgentRepository) Create(ctx context.Context, agent *models.Agent) error
 	return nil
 }
 
-// Get retrieves an agent by ID.
-func (r *AgentRepository) Get(ctx context.Context, id string) (*models.Agent, error) {
+// Get retrieves an agent by ID. Terminated agents are ErrAgentNotFound
+// unless opts include them.
+func (r *AgentRepository) Get(ctx context.Context, id string, opts ...AgentQueryOption) (*models.Agent, error) {
 	row := r.db.QueryRowContext(ctx, `
 		SELECT
-			id, workspace_id, type, tmux_pane, account_id,
+			id, workspace_id, type, tmux_pane, tmux_session, remote_agent_id, account_id,
 			state, state_confidence, state_reason, state_detected_at,
-			paused_until, last_activity_at, metadata_json,
-			created_at, updated_at
-		FROM agents WHERE id = ?
-	`, id)
+			paused_until, last_activity_at, last_output_at, metadata_json, standby, draining,
+			created_at, updated_at, deleted_at, terminal_state, terminal_pane
+		FROM agents WHERE id = ? AND `+liveAgentFilter("", opts), id)
 
 	return r.scanAgent(row)
 }
 
 // List retrieves all agents.
-func (r *AgentRepository) List(ctx context.Context) ([]*models.Agent, error) {
+func (r *AgentRepository) List(ctx context.Context, opts ...AgentQueryOption) ([]*models.Agent, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT
-			id, workspace_id, type, tmux_pane, account_id,
+			id, workspace_id, type, tmux_pane, tmux_session, remote_agent_id, account_id,
 			state, state_confidence, state_reason, state_detected_at,
-			paused_until, last_activity_at, metadata_json,
-			created_at, updated_at
-		FROM agents ORDER BY created_at
+			paused_until, last_activity_at, last_output_at, metadata_json, standby, draining,
+			created_at, updated_at, deleted_at, terminal_state, terminal_pane
+		FROM agents WHERE `+liveAgentFilter("", opts)+`
+		ORDER BY created_at
 	`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query agents: %w", err)
@@ -132,14 +177,14 @@ func (r *AgentRepository) List(ctx context.Context) ([]*models.Agent, error) {
 }
 
 // ListByWorkspace retrieves agents for a specific workspace.
-func (r *AgentRepository) ListByWorkspace(ctx context.Context, workspaceID string) ([]*models.Agent, error) {
+func (r *AgentRepository) ListByWorkspace(ctx context.Context, workspaceID string, opts ...AgentQueryOption) ([]*models.Agent, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT
-			id, workspace_id, type, tmux_pane, account_id,
+			id, workspace_id, type, tmux_pane, tmux_session, remote_agent_id, account_id,
 			state, state_confidence, state_reason, state_detected_at,
-			paused_until, last_activity_at, metadata_json,
-			created_at, updated_at
-		FROM agents WHERE workspace_id = ?
+			paused_until, last_activity_at, last_output_at, metadata_json, standby, draining,
+			created_at, updated_at, deleted_at, terminal_state, terminal_pane
+		FROM agents WHERE workspace_id = ? AND `+liveAgentFilter("", opts)+`
 		ORDER BY created_at
 	`, workspaceID)
 	if err != nil {
@@ -151,14 +196,14 @@ func (r *AgentRepository) ListByWorkspace(ctx context.Context, workspaceID strin
 }
 
 // ListByState retrieves agents with a specific state.
-func (r *AgentRepository) ListByState(ctx context.Context, state models.AgentState) ([]*models.Agent, error) {
+func (r *AgentRepository) ListByState(ctx context.Context, state models.AgentState, opts ...AgentQueryOption) ([]*models.Agent, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT
-			id, workspace_id, type, tmux_pane, account_id,
+			id, workspace_id, type, tmux_pane, tmux_session, remote_agent_id, account_id,
 			state, state_confidence, state_reason, state_detected_at,
-			paused_until, last_activity_at, metadata_json,
-			created_at, updated_at
-		FROM agents WHERE state = ?
+			paused_until, last_activity_at, last_output_at, metadata_json, standby, draining,
+			created_at, updated_at, deleted_at, terminal_state, terminal_pane
+		FROM agents WHERE state = ? AND `+liveAgentFilter("", opts)+`
 		ORDER BY created_at
 	`, string(state))
 	if err != nil {
@@ -170,18 +215,19 @@ func (r *AgentRepository) ListByState(ctx context.Context, state models.AgentSta
 }
 
 // ListWithQueueLength retrieves all agents with queue length counts.
-func (r *AgentRepository) ListWithQueueLength(ctx context.Context) ([]*models.Agent, error) {
+func (r *AgentRepository) ListWithQueueLength(ctx context.Context, opts ...AgentQueryOption) ([]*models.Agent, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT
-			a.id, a.workspace_id, a.type, a.tmux_pane, a.account_id,
+			a.id, a.workspace_id, a.type, a.tmux_pane, a.tmux_session, a.remote_agent_id, a.account_id,
 			a.state, a.state_confidence, a.state_reason, a.state_detected_at,
-			a.paused_until, a.last_activity_at, a.metadata_json,
-			a.created_at, a.updated_at,
+			a.paused_until, a.last_activity_at, a.last_output_at, a.metadata_json, a.standby, a.draining,
+			a.created_at, a.updated_at, a.deleted_at, a.terminal_state, a.terminal_pane,
 			COUNT(q.id) AS queue_length
 		FROM agents a
 		LEFT JOIN queue_items q
 			ON q.agent_id = a.id
 			AND q.status = 'pending'
+		WHERE `+liveAgentFilter("a", opts)+`
 		GROUP BY a.id
 		ORDER BY a.created_at
 	`)
@@ -247,12 +293,15 @@ func (r *AgentRepository) updateWithExecutor(ctx context.Context, execer agentEx
 	stateDetectedAt := timePtrFrom(agent.StateInfo.DetectedAt)
 	pausedUntil := stringTimePtr(agent.PausedUntil)
 	lastActivity := stringTimePtr(agent.LastActivity)
+	lastOutput := stringTimePtr(agent.LastOutputAt)
 
 	result, err := execer.ExecContext(ctx, `
 		UPDATE agents SET
 			workspace_id = ?,
 			type = ?,
 			tmux_pane = ?,
+			tmux_session = ?,
+			remote_agent_id = ?,
 			account_id = ?,
 			state = ?,
 			state_confidence = ?,
@@ -260,13 +309,16 @@ func (r *AgentRepository) updateWithExecutor(ctx context.Context, execer agentEx
 			state_detected_at = ?,
 			paused_until = ?,
 			last_activity_at = ?,
+			last_output_at = ?,
 			metadata_json = ?,
 			updated_at = ?
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`,
 		agent.WorkspaceID,
 		string(agent.Type),
 		agent.TmuxPane,
+		agent.TmuxSession,
+		agent.RemoteAgentID,
 		accountID,
 		string(agent.State),
 		string(agent.StateInfo.Confidence),
@@ -274,6 +326,7 @@ func (r *AgentRepository) updateWithExecutor(ctx context.Context, execer agentEx
 		stateDetectedAt,
 		pausedUntil,
 		lastActivity,
+		lastOutput,
 		string(metadataJSON),
 		agent.UpdatedAt.Format(time.RFC3339),
 		agent.ID,
@@ -297,8 +350,44 @@ func (r *AgentRepository) updateWithExecutor(ctx context.Context, execer agentEx
 	return nil
 }
 
-// Delete removes an agent by ID.
+// Delete marks an agent terminated. The row is kept, so history that
+// refers to the agent still resolves it, and queries leave it out unless
+// asked with IncludeDeleted. The agent is stopped, its last state kept as
+// TerminalState, and its pane released for reuse. Terminating an agent
+// twice is ErrAgentNotFound.
 func (r *AgentRepository) Delete(ctx context.Context, id string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE agents SET
+			deleted_at = ?,
+			terminal_state = state,
+			terminal_pane = tmux_pane,
+			tmux_pane = id,
+			state = ?,
+			state_reason = 'terminated',
+			standby = 0,
+			updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, now, string(models.AgentStateStopped), now, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete agent: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAgentNotFound
+	}
+
+	return nil
+}
+
+// HardDelete removes an agent row, terminated or not, together with the
+// rows that cascade from it. It is meant for administrative cleanup.
+func (r *AgentRepository) HardDelete(ctx context.Context, id string) error {
 	result, err := r.db.ExecContext(ctx, "DELETE FROM agents WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("failed to delete agent: %w", err)
@@ -316,19 +405,123 @@ func (r *AgentRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// PruneDeleted hard-deletes agents terminated before cutoff. Their queue
+// items, transcripts, and other dependent rows cascade; workspace queue
+// items assigned to them, which carry no foreign key, are deleted too.
+// It returns the number of agents removed.
+func (r *AgentRepository) PruneDeleted(ctx context.Context, cutoff time.Time) (int, error) {
+	var pruned int64
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		before := cutoff.UTC().Format(time.RFC3339)
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM workspace_queue_items
+			WHERE agent_id IN (SELECT id FROM agents WHERE deleted_at IS NOT NULL AND deleted_at < ?)
+		`, before); err != nil {
+			return fmt.Errorf("failed to delete workspace queue leftovers: %w", err)
+		}
+		result, err := tx.ExecContext(ctx, `
+			DELETE FROM agents WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		`, before)
+		if err != nil {
+			return fmt.Errorf("failed to prune terminated agents: %w", err)
+		}
+		pruned, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(pruned), nil
+}
+
+// SetDraining sets whether an agent is draining its queue.
+func (r *AgentRepository) SetDraining(ctx context.Context, id string, draining bool) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE agents SET draining = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, boolToInt(draining), time.Now().UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("failed to update agent draining: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAgentNotFound
+	}
+
+	return nil
+}
+
+// ClaimStandby takes the oldest idle standby agent of agentType (any type
+// when empty) in a workspace that is not draining and clears its standby
+// flag. The flag is
+// cleared with a conditional update, so concurrent claims never take the
+// same agent. ErrNoStandbyAgent is returned when none is left.
+func (r *AgentRepository) ClaimStandby(ctx context.Context, workspaceID string, agentType models.AgentType) (*models.Agent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id FROM agents
+		WHERE workspace_id = ? AND standby = 1 AND draining = 0 AND state = ? AND (? = '' OR type = ?) AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`, workspaceID, string(models.AgentStateIdle), string(agentType), string(agentType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query standby agents: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan standby agent: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("error iterating standby agents: %w", err)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		result, err := r.db.ExecContext(ctx, `
+			UPDATE agents SET standby = 0, updated_at = ? WHERE id = ? AND standby = 1
+		`, time.Now().UTC().Format(time.RFC3339), id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim standby agent: %w", err)
+		}
+		claimed, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if claimed == 1 {
+			return r.Get(ctx, id)
+		}
+	}
+	return nil, ErrNoStandbyAgent
+}
+
 func (r *AgentRepository) scanAgent(row *sql.Row) (*models.Agent, error) {
 	var agent models.Agent
 	var agentType, state, confidence string
 	var accountID, stateReason, stateDetectedAt sql.NullString
-	var pausedUntil, lastActivity sql.NullString
+	var pausedUntil, lastActivity, lastOutput sql.NullString
 	var metadataJSON sql.NullString
 	var createdAt, updatedAt string
+	var deleted deletedAgentFields
 
 	err := row.Scan(
 		&agent.ID,
 		&agent.WorkspaceID,
 		&agentType,
 		&agent.TmuxPane,
+		&agent.TmuxSession,
+		&agent.RemoteAgentID,
 		&accountID,
 		&state,
 		&confidence,
@@ -336,9 +529,15 @@ func (r *AgentRepository) scanAgent(row *sql.Row) (*models.Agent, error) {
 		&stateDetectedAt,
 		&pausedUntil,
 		&lastActivity,
+		&lastOutput,
 		&metadataJSON,
+		&agent.Standby,
+		&agent.Draining,
 		&createdAt,
 		&updatedAt,
+		&deleted.deletedAt,
+		&deleted.terminalState,
+		&deleted.terminalPane,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -347,7 +546,8 @@ func (r *AgentRepository) scanAgent(row *sql.Row) (*models.Agent, error) {
 		return nil, fmt.Errorf("failed to scan agent: %w", err)
 	}
 
-	populateAgentFields(&agent, agentType, state, confidence, accountID, stateReason, stateDetectedAt, pausedUntil, lastActivity, metadataJSON, createdAt, updatedAt)
+	populateAgentFields(&agent, agentType, state, confidence, accountID, stateReason, stateDetectedAt, pausedUntil, lastActivity, lastOutput, metadataJSON, createdAt, updatedAt)
+	deleted.populate(&agent)
 	return &agent, nil
 }
 
@@ -358,15 +558,18 @@ func (r *AgentRepository) scanAgents(rows *sql.Rows) ([]*models.Agent, error) {
 		var agent models.Agent
 		var agentType, state, confidence string
 		var accountID, stateReason, stateDetectedAt sql.NullString
-		var pausedUntil, lastActivity sql.NullString
+		var pausedUntil, lastActivity, lastOutput sql.NullString
 		var metadataJSON sql.NullString
 		var createdAt, updatedAt string
+		var deleted deletedAgentFields
 
 		err := rows.Scan(
 			&agent.ID,
 			&agent.WorkspaceID,
 			&agentType,
 			&agent.TmuxPane,
+			&agent.TmuxSession,
+			&agent.RemoteAgentID,
 			&accountID,
 			&state,
 			&confidence,
@@ -374,15 +577,22 @@ func (r *AgentRepository) scanAgents(rows *sql.Rows) ([]*models.Agent, error) {
 			&stateDetectedAt,
 			&pausedUntil,
 			&lastActivity,
+			&lastOutput,
 			&metadataJSON,
+			&agent.Standby,
+			&agent.Draining,
 			&createdAt,
 			&updatedAt,
+			&deleted.deletedAt,
+			&deleted.terminalState,
+			&deleted.terminalPane,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan agent: %w", err)
 		}
 
-		populateAgentFields(&agent, agentType, state, confidence, accountID, stateReason, stateDetectedAt, pausedUntil, lastActivity, metadataJSON, createdAt, updatedAt)
+		populateAgentFields(&agent, agentType, state, confidence, accountID, stateReason, stateDetectedAt, pausedUntil, lastActivity, lastOutput, metadataJSON, createdAt, updatedAt)
+		deleted.populate(&agent)
 		agents = append(agents, &agent)
 	}
 
@@ -400,9 +610,10 @@ func (r *AgentRepository) scanAgentsWithQueueLength(rows *sql.Rows) ([]*models.A
 		var agent models.Agent
 		var agentType, state, confidence string
 		var accountID, stateReason, stateDetectedAt sql.NullString
-		var pausedUntil, lastActivity sql.NullString
+		var pausedUntil, lastActivity, lastOutput sql.NullString
 		var metadataJSON sql.NullString
 		var createdAt, updatedAt string
+		var deleted deletedAgentFields
 		var queueLength int
 
 		err := rows.Scan(
@@ -410,6 +621,8 @@ func (r *AgentRepository) scanAgentsWithQueueLength(rows *sql.Rows) ([]*models.A
 			&agent.WorkspaceID,
 			&agentType,
 			&agent.TmuxPane,
+			&agent.TmuxSession,
+			&agent.RemoteAgentID,
 			&accountID,
 			&state,
 			&confidence,
@@ -417,16 +630,23 @@ func (r *AgentRepository) scanAgentsWithQueueLength(rows *sql.Rows) ([]*models.A
 			&stateDetectedAt,
 			&pausedUntil,
 			&lastActivity,
+			&lastOutput,
 			&metadataJSON,
+			&agent.Standby,
+			&agent.Draining,
 			&createdAt,
 			&updatedAt,
+			&deleted.deletedAt,
+			&deleted.terminalState,
+			&deleted.terminalPane,
 			&queueLength,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan agent with queue length: %w", err)
 		}
 
-		populateAgentFields(&agent, agentType, state, confidence, accountID, stateReason, stateDetectedAt, pausedUntil, lastActivity, metadataJSON, createdAt, updatedAt)
+		populateAgentFields(&agent, agentType, state, confidence, accountID, stateReason, stateDetectedAt, pausedUntil, lastActivity, lastOutput, metadataJSON, createdAt, updatedAt)
+		deleted.populate(&agent)
 		agent.QueueLength = queueLength
 		agents = append(agents, &agent)
 	}
@@ -438,7 +658,7 @@ func (r *AgentRepository) scanAgentsWithQueueLength(rows *sql.Rows) ([]*models.A
 	return agents, nil
 }
 
-func populateAgentFields(agent *models.Agent, agentType, state, confidence string, accountID, stateReason, stateDetectedAt, pausedUntil, lastActivity, metadataJSON sql.NullString, createdAt, updatedAt string) {
+func populateAgentFields(agent *models.Agent, agentType, state, confidence string, accountID, stateReason, stateDetectedAt, pausedUntil, lastActivity, lastOutput, metadataJSON sql.NullString, createdAt, updatedAt string) {
 	agent.Type = models.AgentType(agentType)
 	agent.State = models.AgentState(state)
 	if accountID.Valid {
@@ -466,6 +686,11 @@ func populateAgentFields(agent *models.Agent, agentType, state, confidence strin
 			agent.LastActivity = &t
 		}
 	}
+	if lastOutput.Valid {
+		if t, err := time.Parse(time.RFC3339, lastOutput.String); err == nil {
+			agent.LastOutputAt = &t
+		}
+	}
 
 	if metadataJSON.Valid && metadataJSON.String != "" {
 		if err := json.Unmarshal([]byte(metadataJSON.String), &agent.Metadata); err != nil {
@@ -481,6 +706,22 @@ func populateAgentFields(agent *models.Agent, agentType, state, confidence strin
 	}
 }
 
+// deletedAgentFields holds the soft-delete columns of a scanned agent.
+type deletedAgentFields struct {
+	deletedAt, terminalState, terminalPane sql.NullString
+}
+
+func (d deletedAgentFields) populate(agent *models.Agent) {
+	if !d.deletedAt.Valid {
+		return
+	}
+	if t, err := time.Parse(time.RFC3339, d.deletedAt.String); err == nil {
+		agent.DeletedAt = &t
+	}
+	agent.TerminalState = models.AgentState(d.terminalState.String)
+	agent.TmuxPane = d.terminalPane.String
+}
+
 func normalizeAgentState(agent *models.Agent) (models.AgentState, models.StateConfidence) {
 	state := agent.State
 	if state == "" {
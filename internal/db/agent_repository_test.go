@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"sync"
 	"testing"
 	"time"
 
@@ -312,3 +313,261 @@ func TestAgentRepository_UpdateWithEventAtomic(t *testing.T) {
 		t.Fatalf("expected 1 event, got %d", len(events))
 	}
 }
+
+func TestAgentRepository_StuckStateAndLastOutput(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewAgentRepository(db)
+	ws := createTestWorkspace(t, db)
+
+	agent := &models.Agent{
+		WorkspaceID: ws.ID,
+		Type:        models.AgentTypeClaudeCode,
+		TmuxPane:    "swarm:0.2",
+		State:       models.AgentStateWorking,
+	}
+	if err := repo.Create(ctx, agent); err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	insertQueueItem(t, db, agent.ID, models.QueueItemStatusPending, 1)
+
+	lastOutput := time.Now().UTC().Add(-15 * time.Minute).Truncate(time.Second)
+	agent.State = models.AgentStateStuck
+	agent.StateInfo.Reason = "no output for 15m"
+	agent.LastOutputAt = &lastOutput
+	if err := repo.Update(ctx, agent); err != nil {
+		t.Fatalf("update agent to stuck: %v", err)
+	}
+
+	stuck, err := repo.ListByState(ctx, models.AgentStateStuck)
+	if err != nil {
+		t.Fatalf("list stuck agents: %v", err)
+	}
+	if len(stuck) != 1 || stuck[0].ID != agent.ID {
+		t.Fatalf("expected the stuck agent, got %+v", stuck)
+	}
+	if stuck[0].LastOutputAt == nil || !stuck[0].LastOutputAt.Equal(lastOutput) {
+		t.Fatalf("expected last output %v, got %v", lastOutput, stuck[0].LastOutputAt)
+	}
+
+	withQueue, err := repo.ListWithQueueLength(ctx)
+	if err != nil {
+		t.Fatalf("list with queue length: %v", err)
+	}
+	if len(withQueue) != 1 || withQueue[0].QueueLength != 1 {
+		t.Fatalf("expected queue item to survive the schema change, got %+v", withQueue)
+	}
+}
+
+func TestAgentRepository_ClaimStandby(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewAgentRepository(db)
+	ws := createTestWorkspace(t, db)
+
+	working := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-test:0.1", State: models.AgentStateWorking, Standby: true}
+	other := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeClaudeCode, TmuxPane: "swarm-test:0.2", State: models.AgentStateIdle, Standby: true}
+	standby := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-test:0.3", State: models.AgentStateIdle, Standby: true}
+	for _, a := range []*models.Agent{working, other, standby} {
+		if err := repo.Create(ctx, a); err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+	}
+
+	claimed, err := repo.ClaimStandby(ctx, ws.ID, models.AgentTypeOpenCode)
+	if err != nil {
+		t.Fatalf("ClaimStandby: %v", err)
+	}
+	if claimed.ID != standby.ID || claimed.Standby {
+		t.Fatalf("expected idle opencode standby %s to be claimed, got %s (standby=%v)", standby.ID, claimed.ID, claimed.Standby)
+	}
+	if _, err := repo.ClaimStandby(ctx, ws.ID, models.AgentTypeOpenCode); err != ErrNoStandbyAgent {
+		t.Fatalf("expected ErrNoStandbyAgent, got %v", err)
+	}
+
+	claimed, err = repo.ClaimStandby(ctx, ws.ID, "")
+	if err != nil {
+		t.Fatalf("ClaimStandby any type: %v", err)
+	}
+	if claimed.ID != other.ID {
+		t.Fatalf("expected %s to be claimed for any type, got %s", other.ID, claimed.ID)
+	}
+}
+
+func TestAgentRepository_ClaimStandbyRace(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewAgentRepository(db)
+	ws := createTestWorkspace(t, db)
+	standby := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-test:0.1", State: models.AgentStateIdle, Standby: true}
+	if err := repo.Create(ctx, standby); err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	const claimers = 8
+	var wg sync.WaitGroup
+	results := make(chan error, claimers)
+	for i := 0; i < claimers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.ClaimStandby(ctx, ws.ID, models.AgentTypeOpenCode)
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var won int
+	for err := range results {
+		switch err {
+		case nil:
+			won++
+		case ErrNoStandbyAgent:
+		default:
+			t.Fatalf("unexpected claim error: %v", err)
+		}
+	}
+	if won != 1 {
+		t.Fatalf("expected exactly one claim to win, got %d", won)
+	}
+}
+
+func TestAgentRepository_SoftDelete(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewAgentRepository(db)
+	wsRepo := NewWorkspaceRepository(db)
+	ws := createTestWorkspace(t, db)
+
+	agent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-test:0.1", State: models.AgentStateWorking}
+	if err := repo.Create(ctx, agent); err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	if err := repo.Delete(ctx, agent.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// Default queries leave the terminated agent out.
+	if _, err := repo.Get(ctx, agent.ID); err != ErrAgentNotFound {
+		t.Fatalf("expected ErrAgentNotFound, got %v", err)
+	}
+	for name, list := range map[string]func(...AgentQueryOption) ([]*models.Agent, error){
+		"List": func(opts ...AgentQueryOption) ([]*models.Agent, error) {
+			return repo.List(ctx, opts...)
+		},
+		"ListWithQueueLength": func(opts ...AgentQueryOption) ([]*models.Agent, error) {
+			return repo.ListWithQueueLength(ctx, opts...)
+		},
+		"ListByWorkspace": func(opts ...AgentQueryOption) ([]*models.Agent, error) {
+			return repo.ListByWorkspace(ctx, ws.ID, opts...)
+		},
+		"ListByState": func(opts ...AgentQueryOption) ([]*models.Agent, error) {
+			return repo.ListByState(ctx, models.AgentStateStopped, opts...)
+		},
+	} {
+		agents, err := list()
+		if err != nil || len(agents) != 0 {
+			t.Fatalf("%s: expected no agents, got %d (%v)", name, len(agents), err)
+		}
+		agents, err = list(IncludeDeleted())
+		if err != nil || len(agents) != 1 || !agents[0].IsTerminated() {
+			t.Fatalf("%s with IncludeDeleted: expected the terminated agent, got %d (%v)", name, len(agents), err)
+		}
+	}
+	if count, err := wsRepo.GetAgentCount(ctx, ws.ID); err != nil || count != 0 {
+		t.Fatalf("expected terminated agents left out of workspace counts, got %d (%v)", count, err)
+	}
+
+	terminated, err := repo.Get(ctx, agent.ID, IncludeDeleted())
+	if err != nil {
+		t.Fatalf("Get with IncludeDeleted: %v", err)
+	}
+	if terminated.DeletedAt == nil || terminated.TerminalState != models.AgentStateWorking ||
+		terminated.State != models.AgentStateStopped || terminated.TmuxPane != "swarm-test:0.1" {
+		t.Fatalf("unexpected terminated agent %+v", terminated)
+	}
+
+	// The pane is free for a new agent, and the terminated one stays put.
+	reuse := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-test:0.1", State: models.AgentStateIdle}
+	if err := repo.Create(ctx, reuse); err != nil {
+		t.Fatalf("expected the pane to be reusable, got %v", err)
+	}
+	if err := repo.Update(ctx, terminated); err != ErrAgentNotFound {
+		t.Fatalf("expected updates to a terminated agent to fail, got %v", err)
+	}
+	if err := repo.Delete(ctx, agent.ID); err != ErrAgentNotFound {
+		t.Fatalf("expected a second Delete to fail, got %v", err)
+	}
+
+	if err := repo.HardDelete(ctx, agent.ID); err != nil {
+		t.Fatalf("HardDelete: %v", err)
+	}
+	if _, err := repo.Get(ctx, agent.ID, IncludeDeleted()); err != ErrAgentNotFound {
+		t.Fatalf("expected the row removed, got %v", err)
+	}
+}
+
+func TestAgentRepository_PruneDeleted(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewAgentRepository(db)
+	wsQueue := NewWorkspaceQueueRepository(db)
+	ws := createTestWorkspace(t, db)
+
+	old := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-test:0.1", State: models.AgentStateIdle}
+	recent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-test:0.2", State: models.AgentStateIdle}
+	live := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-test:0.3", State: models.AgentStateIdle}
+	for _, a := range []*models.Agent{old, recent, live} {
+		if err := repo.Create(ctx, a); err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+	}
+
+	item := &models.WorkspaceQueueItem{WorkspaceID: ws.ID, Type: models.QueueItemTypeMessage, Payload: json.RawMessage(`{"text":"hi"}`)}
+	if err := wsQueue.Enqueue(ctx, item); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := wsQueue.Assign(ctx, item.ID, old.ID); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+
+	for _, a := range []*models.Agent{old, recent} {
+		if err := repo.Delete(ctx, a.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE agents SET deleted_at = ? WHERE id = ?",
+		time.Now().Add(-48*time.Hour).UTC().Format(time.RFC3339), old.ID); err != nil {
+		t.Fatalf("backdate termination: %v", err)
+	}
+
+	pruned, err := repo.PruneDeleted(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("PruneDeleted: %v", err)
+	}
+	if pruned != 1 {
+		t.Fatalf("expected 1 agent pruned, got %d", pruned)
+	}
+	if _, err := repo.Get(ctx, old.ID, IncludeDeleted()); err != ErrAgentNotFound {
+		t.Fatalf("expected the old agent pruned, got %v", err)
+	}
+	if _, err := wsQueue.Get(ctx, item.ID); err == nil {
+		t.Fatal("expected the pruned agent's workspace queue item deleted")
+	}
+	for _, a := range []*models.Agent{recent, live} {
+		if _, err := repo.Get(ctx, a.ID, IncludeDeleted()); err != nil {
+			t.Fatalf("expected agent %s kept, got %v", a.ID, err)
+		}
+	}
+}
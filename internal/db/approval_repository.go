@@ -69,6 +69,49 @@ func (r *ApprovalRepository) Create(ctx context.Context, approval *models.Approv
 	return nil
 }
 
+// Get retrieves an approval by ID.
+func (r *ApprovalRepository) Get(ctx context.Context, id string) (*models.Approval, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			id, agent_id, request_type, request_details_json,
+			status, created_at, resolved_at, resolved_by
+		FROM approvals
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approval: %w", err)
+	}
+	defer rows.Close()
+
+	approvals, err := r.scanApprovals(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(approvals) == 0 {
+		return nil, ErrApprovalNotFound
+	}
+	return approvals[0], nil
+}
+
+// List lists approvals, optionally filtered by status. An empty status
+// returns approvals in every status.
+func (r *ApprovalRepository) List(ctx context.Context, status models.ApprovalStatus) ([]*models.Approval, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			id, agent_id, request_type, request_details_json,
+			status, created_at, resolved_at, resolved_by
+		FROM approvals
+		WHERE ? = '' OR status = ?
+		ORDER BY created_at
+	`, string(status), string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approvals: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanApprovals(rows)
+}
+
 // ListPendingByAgent lists pending approvals for a single agent.
 func (r *ApprovalRepository) ListPendingByAgent(ctx context.Context, agentID string) ([]*models.Approval, error) {
 	rows, err := r.db.QueryContext(ctx, `
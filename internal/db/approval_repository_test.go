@@ -87,4 +87,37 @@ func TestApprovalRepository_CreateListUpdate(t *testing.T) {
 	if len(pending) != 0 {
 		t.Fatalf("expected 0 pending approvals after update, got %d", len(pending))
 	}
+
+	got, err := repo.Get(ctx, approval.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != models.ApprovalStatusApproved || got.ResolvedBy != "user" || got.ResolvedAt == nil {
+		t.Fatalf("expected resolved approval, got %+v", got)
+	}
+	if _, err := repo.Get(ctx, "missing"); err != ErrApprovalNotFound {
+		t.Fatalf("expected ErrApprovalNotFound, got %v", err)
+	}
+
+	all, err := repo.List(ctx, "")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("expected 1 approval, got %d", len(all))
+	}
+	approved, err := repo.List(ctx, models.ApprovalStatusApproved)
+	if err != nil {
+		t.Fatalf("List approved failed: %v", err)
+	}
+	if len(approved) != 1 {
+		t.Fatalf("expected 1 approved approval, got %d", len(approved))
+	}
+	pending, err = repo.List(ctx, models.ApprovalStatusPending)
+	if err != nil {
+		t.Fatalf("List pending failed: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected 0 pending approvals, got %d", len(pending))
+	}
 }
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+const auditColumns = `id, timestamp, actor, operation, entity_type, entity_id, parameters_json, result, error, trace_id`
+
+// AuditQuery filters audit records. Zero fields match everything.
+type AuditQuery struct {
+	Actor      string
+	Operation  string
+	EntityType models.EntityType
+	EntityID   string
+	Since      *time.Time // Records at or after this time (inclusive)
+	Until      *time.Time // Records before this time (exclusive)
+	Limit      int        // Max results to return (0 = all)
+}
+
+// AuditRepository handles audit record persistence.
+type AuditRepository struct {
+	db *DB
+}
+
+// NewAuditRepository creates a new AuditRepository.
+func NewAuditRepository(db *DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create appends a record to the audit log.
+func (r *AuditRepository) Create(ctx context.Context, record *models.AuditRecord) error {
+	if record.Actor == "" || record.Operation == "" {
+		return fmt.Errorf("audit record actor and operation are required")
+	}
+	if record.Result != models.AuditResultSuccess && record.Result != models.AuditResultFailure {
+		return fmt.Errorf("invalid audit result %q", record.Result)
+	}
+	if record.ID == "" {
+		record.ID = uuid.New().String()
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now().UTC()
+	}
+
+	var params any
+	if len(record.Parameters) > 0 {
+		data, err := json.Marshal(record.Parameters)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit parameters: %w", err)
+		}
+		params = string(data)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO audit_log (`+auditColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		record.ID,
+		record.Timestamp.UTC().Format(time.RFC3339),
+		record.Actor,
+		record.Operation,
+		string(record.EntityType),
+		record.EntityID,
+		params,
+		string(record.Result),
+		record.Error,
+		record.TraceID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit record: %w", err)
+	}
+	return nil
+}
+
+// Query returns the records matching q, newest first.
+func (r *AuditRepository) Query(ctx context.Context, q AuditQuery) ([]*models.AuditRecord, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE 1=1`
+	var args []any
+	if q.Actor != "" {
+		query += ` AND actor = ?`
+		args = append(args, q.Actor)
+	}
+	if q.Operation != "" {
+		query += ` AND operation = ?`
+		args = append(args, q.Operation)
+	}
+	if q.EntityType != "" {
+		query += ` AND entity_type = ?`
+		args = append(args, string(q.EntityType))
+	}
+	if q.EntityID != "" {
+		query += ` AND entity_id = ?`
+		args = append(args, q.EntityID)
+	}
+	if q.Since != nil {
+		query += ` AND timestamp >= ?`
+		args = append(args, q.Since.UTC().Format(time.RFC3339))
+	}
+	if q.Until != nil {
+		query += ` AND timestamp < ?`
+		args = append(args, q.Until.UTC().Format(time.RFC3339))
+	}
+	query += ` ORDER BY timestamp DESC, rowid DESC`
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, q.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*models.AuditRecord{}
+	for rows.Next() {
+		record, err := scanAuditRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit records: %w", err)
+	}
+	return records, nil
+}
+
+// Count returns the number of audit records.
+func (r *AuditRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count audit records: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteOlderThan deletes up to limit records older than before.
+// Returns the number of records deleted.
+func (r *AuditRepository) DeleteOlderThan(ctx context.Context, before time.Time, limit int) (int64, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM audit_log WHERE id IN (
+			SELECT id FROM audit_log WHERE timestamp < ? ORDER BY timestamp LIMIT ?
+		)
+	`, before.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old audit records: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// DeleteExcess deletes up to limit of the oldest records beyond maxCount.
+// Returns the number of records deleted.
+func (r *AuditRepository) DeleteExcess(ctx context.Context, maxCount int, limit int) (int64, error) {
+	if maxCount <= 0 {
+		return 0, nil
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	total, err := r.Count(ctx)
+	if err != nil {
+		return 0, err
+	}
+	excess := total - int64(maxCount)
+	if excess <= 0 {
+		return 0, nil
+	}
+	if excess > int64(limit) {
+		excess = int64(limit)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM audit_log WHERE id IN (
+			SELECT id FROM audit_log ORDER BY timestamp, rowid LIMIT ?
+		)
+	`, excess)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete excess audit records: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func scanAuditRecord(rows *sql.Rows) (*models.AuditRecord, error) {
+	var record models.AuditRecord
+	var timestamp, entityType, result string
+	var params sql.NullString
+	if err := rows.Scan(&record.ID, &timestamp, &record.Actor, &record.Operation, &entityType, &record.EntityID, &params, &result, &record.Error, &record.TraceID); err != nil {
+		return nil, fmt.Errorf("failed to scan audit record: %w", err)
+	}
+
+	parsed, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse audit timestamp: %w", err)
+	}
+	record.Timestamp = parsed
+	record.EntityType = models.EntityType(entityType)
+	record.Result = models.AuditResult(result)
+	if params.Valid && params.String != "" {
+		if err := json.Unmarshal([]byte(params.String), &record.Parameters); err != nil {
+			return nil, fmt.Errorf("failed to parse audit parameters: %w", err)
+		}
+	}
+	return &record, nil
+}
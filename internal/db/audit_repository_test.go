@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestAuditRepository_CreateAndQuery(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewAuditRepository(db)
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	records := []*models.AuditRecord{
+		{Timestamp: now.Add(-48 * time.Hour), Actor: "dana", Operation: "spawn_agent", EntityType: models.EntityTypeAgent, EntityID: "agent-1", Parameters: map[string]string{"type": "opencode"}, Result: models.AuditResultSuccess},
+		{Timestamp: now.Add(-time.Hour), Actor: "dana", Operation: "kill_agent", EntityType: models.EntityTypeAgent, EntityID: "agent-1", Result: models.AuditResultFailure, Error: "agent busy", TraceID: "trace-1"},
+		{Timestamp: now, Actor: "token:ab12", Operation: "kill_agent", EntityType: models.EntityTypeAgent, EntityID: "agent-2", Result: models.AuditResultSuccess},
+	}
+	for _, record := range records {
+		if err := repo.Create(ctx, record); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	if records[0].ID == "" {
+		t.Fatal("expected an ID assigned")
+	}
+
+	all, err := repo.Query(ctx, AuditQuery{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(all) != 3 || all[0].ID != records[2].ID || all[2].ID != records[0].ID {
+		t.Fatalf("expected records newest first, got %+v", all)
+	}
+	if all[2].Parameters["type"] != "opencode" || all[1].Error != "agent busy" || all[1].TraceID != "trace-1" {
+		t.Fatalf("fields not round-tripped: %+v %+v", all[2], all[1])
+	}
+
+	since := now.Add(-2 * time.Hour)
+	kills, err := repo.Query(ctx, AuditQuery{Actor: "dana", Operation: "kill_agent", Since: &since})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(kills) != 1 || kills[0].ID != records[1].ID {
+		t.Fatalf("expected only dana's kill, got %+v", kills)
+	}
+
+	if err := repo.Create(ctx, &models.AuditRecord{Actor: "dana", Operation: "kill_agent", Result: "maybe"}); err == nil {
+		t.Fatal("expected an invalid result to be rejected")
+	}
+}
+
+func TestAuditRepository_Retention(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewAuditRepository(db)
+	now := time.Now().UTC()
+
+	for i := 0; i < 5; i++ {
+		record := &models.AuditRecord{Timestamp: now.Add(-time.Duration(5-i) * 24 * time.Hour), Actor: "dana", Operation: "send_message", Result: models.AuditResultSuccess}
+		if err := repo.Create(ctx, record); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	deleted, err := repo.DeleteOlderThan(ctx, now.Add(-3*24*time.Hour-time.Hour), 0)
+	if err != nil {
+		t.Fatalf("DeleteOlderThan failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 old records deleted, got %d", deleted)
+	}
+
+	deleted, err = repo.DeleteExcess(ctx, 2, 0)
+	if err != nil {
+		t.Fatalf("DeleteExcess failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected 1 excess record deleted, got %d", deleted)
+	}
+	count, err := repo.Count(ctx)
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 records left, got %d", count)
+	}
+}
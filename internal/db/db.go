@@ -7,11 +7,15 @@ import (
 	"embed"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	_ "modernc.org/sqlite" // Pure Go SQLite driver
 
+	"github.com/opencode-ai/swarm/internal/fsperm"
 	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/redact"
 	"github.com/rs/zerolog"
 )
 
@@ -21,8 +25,12 @@ var migrationsFS embed.FS
 // DB wraps the SQLite database connection.
 type DB struct {
 	*sql.DB
-	mu     sync.RWMutex
-	logger zerolog.Logger
+	mu          sync.RWMutex
+	logger      zerolog.Logger
+	path        string
+	lockTimeout time.Duration
+	eventOutbox bool
+	redactor    *redact.Redactor
 }
 
 // Config contains database configuration.
@@ -35,6 +43,18 @@ type Config struct {
 
 	// BusyTimeoutMs is the busy timeout in milliseconds.
 	BusyTimeoutMs int
+
+	// LockTimeout bounds how long migrations and maintenance wait for
+	// another process's lock. Zero means DefaultLockTimeout.
+	LockTimeout time.Duration
+
+	// EventOutbox records every new event in the event_outbox table, in
+	// the event's transaction, for the event bridge to publish.
+	EventOutbox bool
+
+	// Redactor scrubs secrets from event payloads and metadata before they
+	// are written. Nil means redact.Default().
+	Redactor *redact.Redactor
 }
 
 // DefaultConfig returns the default database configuration.
@@ -42,6 +62,7 @@ func DefaultConfig() Config {
 	return Config{
 		MaxOpenConns:  10,
 		BusyTimeoutMs: 5000,
+		LockTimeout:   DefaultLockTimeout,
 	}
 }
 
@@ -57,6 +78,14 @@ func Open(cfg Config) (*DB, error) {
 		// Directory creation should be handled by config.EnsureDirectories
 	}
 
+	// SQLite creates the database with the umask's mode and its WAL and
+	// shared-memory files with the database's, so create it owner-only.
+	if cfg.Path != ":memory:" && !strings.HasPrefix(cfg.Path, "file:") {
+		if err := fsperm.EnsureFile(cfg.Path); err != nil {
+			return nil, fmt.Errorf("failed to create database: %w", err)
+		}
+	}
+
 	// Build connection string with pragmas
 	dsn := fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(ON)&_pragma=synchronous(NORMAL)",
 		cfg.Path, cfg.BusyTimeoutMs)
@@ -77,9 +106,23 @@ func Open(cfg Config) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	lockTimeout := cfg.LockTimeout
+	if lockTimeout <= 0 {
+		lockTimeout = DefaultLockTimeout
+	}
+
+	redactor := cfg.Redactor
+	if redactor == nil {
+		redactor = redact.Default()
+	}
+
 	return &DB{
-		DB:     db,
-		logger: logging.Component("db"),
+		DB:          db,
+		logger:      logging.Component("db"),
+		path:        cfg.Path,
+		lockTimeout: lockTimeout,
+		eventOutbox: cfg.EventOutbox,
+		redactor:    redactor,
 	}, nil
 }
 
@@ -95,8 +138,9 @@ func OpenInMemory() (*DB, error) {
 	db.SetMaxIdleConns(1)
 
 	return &DB{
-		DB:     db,
-		logger: logging.Component("db"),
+		DB:       db,
+		logger:   logging.Component("db"),
+		redactor: redact.Default(),
 	}, nil
 }
 
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ListUnpublished returns up to limit events in the outbox that have not
+// been published yet, in the order they were recorded.
+func (r *EventRepository) ListUnpublished(ctx context.Context, limit int) ([]*models.Event, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT e.id, e.timestamp, e.type, e.entity_type, e.entity_id, e.payload_json, e.metadata_json, e.trace_id
+		FROM event_outbox o
+		JOIN events e ON e.id = o.event_id
+		WHERE o.published_at IS NULL
+		ORDER BY o.rowid
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unpublished events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.Event
+	for rows.Next() {
+		event, err := r.scanEventFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unpublished events: %w", err)
+	}
+
+	return events, nil
+}
+
+// CountUnpublished returns how many events in the outbox await publication.
+func (r *EventRepository) CountUnpublished(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM event_outbox WHERE published_at IS NULL`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unpublished events: %w", err)
+	}
+	return count, nil
+}
+
+// MarkPublished records the events as published.
+func (r *EventRepository) MarkPublished(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders, args := outboxIDArgs(ids)
+	args = append([]any{time.Now().UTC().Format(time.RFC3339)}, args...)
+
+	_, err := r.db.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE event_outbox SET published_at = ?, last_error = NULL
+		WHERE event_id IN (%s)
+	`, placeholders), args...)
+	if err != nil {
+		return fmt.Errorf("failed to mark events published: %w", err)
+	}
+	return nil
+}
+
+// RecordPublishFailure counts a failed attempt to publish the events and
+// keeps the error. They stay in the outbox to be retried.
+func (r *EventRepository) RecordPublishFailure(ctx context.Context, ids []string, cause error) error {
+	if len(ids) == 0 || cause == nil {
+		return nil
+	}
+	placeholders, args := outboxIDArgs(ids)
+	args = append([]any{cause.Error()}, args...)
+
+	_, err := r.db.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE event_outbox SET attempts = attempts + 1, last_error = ?
+		WHERE event_id IN (%s) AND published_at IS NULL
+	`, placeholders), args...)
+	if err != nil {
+		return fmt.Errorf("failed to record publish failure: %w", err)
+	}
+	return nil
+}
+
+// Requeue puts every event recorded at or after since back in the outbox
+// as unpublished, including events recorded while the outbox was off.
+// It returns how many events were queued.
+func (r *EventRepository) Requeue(ctx context.Context, since time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO event_outbox (event_id, created_at)
+		SELECT id, ? FROM events WHERE timestamp >= ? ORDER BY timestamp, rowid
+		ON CONFLICT(event_id) DO UPDATE SET published_at = NULL, attempts = 0, last_error = NULL
+	`, time.Now().UTC().Format(time.RFC3339), since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue events: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get requeued count: %w", err)
+	}
+	return count, nil
+}
+
+func outboxIDArgs(ids []string) (string, []any) {
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ","), args
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func setupOutboxDB(t *testing.T) *DB {
+	t.Helper()
+	db := setupTestDB(t)
+	db.eventOutbox = true
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func outboxEvent(entityID string) *models.Event {
+	return &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: entityID}
+}
+
+func TestEventOutbox_WrittenWithEvent(t *testing.T) {
+	ctx := context.Background()
+	db := setupOutboxDB(t)
+	repo := NewEventRepository(db)
+
+	first, second := outboxEvent("agent-1"), outboxEvent("agent-2")
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := repo.CreateBatch(ctx, []*models.Event{second}); err != nil {
+		t.Fatalf("CreateBatch failed: %v", err)
+	}
+
+	pending, err := repo.ListUnpublished(ctx, 0)
+	if err != nil {
+		t.Fatalf("ListUnpublished failed: %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != first.ID || pending[1].ID != second.ID {
+		t.Fatalf("expected both events pending in order, got %+v", pending)
+	}
+
+	// A batch that fails part way leaves neither events nor outbox rows.
+	err = repo.CreateBatch(ctx, []*models.Event{outboxEvent("agent-3"), {Type: models.EventTypeAgentSpawned}})
+	if err == nil {
+		t.Fatal("expected the invalid batch rejected")
+	}
+	if count, err := repo.CountUnpublished(ctx); err != nil || count != 2 {
+		t.Fatalf("expected the failed batch rolled back, got %d pending, %v", count, err)
+	}
+	if total, err := repo.Count(ctx); err != nil || total != 2 {
+		t.Fatalf("expected 2 events, got %d, %v", total, err)
+	}
+
+	// A state change and its event commit together, outbox row included.
+	agent := createTestAgent(t, db, createTestWorkspace(t, db))
+	agent.State = models.AgentStateStopped
+	changed := &models.Event{Type: models.EventTypeAgentStateChanged, EntityType: models.EntityTypeAgent, EntityID: agent.ID}
+	if err := NewAgentRepository(db).UpdateWithEvent(ctx, agent, changed, repo); err != nil {
+		t.Fatalf("UpdateWithEvent failed: %v", err)
+	}
+	if count, err := repo.CountUnpublished(ctx); err != nil || count != 3 {
+		t.Fatalf("expected the state change in the outbox, got %d pending, %v", count, err)
+	}
+
+	// Without the outbox, events are recorded alone.
+	db.eventOutbox = false
+	if err := repo.Create(ctx, outboxEvent("agent-4")); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if count, err := repo.CountUnpublished(ctx); err != nil || count != 3 {
+		t.Fatalf("expected no outbox row with the outbox off, got %d pending, %v", count, err)
+	}
+}
+
+func TestEventOutbox_PublishAndRequeue(t *testing.T) {
+	ctx := context.Background()
+	db := setupOutboxDB(t)
+	repo := NewEventRepository(db)
+
+	old := outboxEvent("agent-1")
+	old.Timestamp = time.Now().UTC().Add(-2 * time.Hour)
+	recent := outboxEvent("agent-2")
+	for _, event := range []*models.Event{old, recent} {
+		if err := repo.Create(ctx, event); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	if err := repo.RecordPublishFailure(ctx, []string{old.ID, recent.ID}, errors.New("connection refused")); err != nil {
+		t.Fatalf("RecordPublishFailure failed: %v", err)
+	}
+	var attempts int
+	var lastError string
+	if err := db.QueryRowContext(ctx, `SELECT attempts, last_error FROM event_outbox WHERE event_id = ?`, old.ID).Scan(&attempts, &lastError); err != nil {
+		t.Fatalf("query outbox: %v", err)
+	}
+	if attempts != 1 || lastError != "connection refused" {
+		t.Fatalf("expected the failure recorded, got %d attempts, %q", attempts, lastError)
+	}
+
+	if err := repo.MarkPublished(ctx, []string{old.ID}); err != nil {
+		t.Fatalf("MarkPublished failed: %v", err)
+	}
+	pending, err := repo.ListUnpublished(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListUnpublished failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != recent.ID {
+		t.Fatalf("expected only the recent event pending, got %+v", pending)
+	}
+	if err := repo.MarkPublished(ctx, []string{recent.ID}); err != nil {
+		t.Fatalf("MarkPublished failed: %v", err)
+	}
+
+	// Requeueing the last hour leaves the older event published, and picks
+	// up events recorded without the outbox.
+	db.eventOutbox = false
+	untracked := outboxEvent("agent-3")
+	if err := repo.Create(ctx, untracked); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	count, err := repo.Requeue(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Requeue failed: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 events requeued, got %d", count)
+	}
+	pending, err = repo.ListUnpublished(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListUnpublished failed: %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != recent.ID || pending[1].ID != untracked.ID {
+		t.Fatalf("expected the recent events pending again, got %+v", pending)
+	}
+
+	// Retention deleting an event takes its outbox row along.
+	if _, err := repo.DeleteByIDs(ctx, []string{recent.ID}); err != nil {
+		t.Fatalf("DeleteByIDs failed: %v", err)
+	}
+	if count, err := repo.CountUnpublished(ctx); err != nil || count != 1 {
+		t.Fatalf("expected the deleted event gone from the outbox, got %d, %v", count, err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/trace"
 )
 
 // Event repository errors.
@@ -39,6 +40,8 @@ type EventQuery struct {
 	Type       *models.EventType  // Filter by event type
 	EntityType *models.EntityType // Filter by entity type
 	EntityID   *string            // Filter by entity ID
+	EntityIDs  []string           // Filter to any of these entity IDs
+	TraceID    string             // Filter by trace ID
 	Since      *time.Time         // Events at or after this time (inclusive)
 	Until      *time.Time         // Events before this time (exclusive)
 	Cursor     string             // Pagination cursor (event ID)
@@ -62,6 +65,12 @@ func (r *EventRepository) Append(ctx context.Context, event *models.Event) error
 
 // Create appends a new event to the event log.
 func (r *EventRepository) Create(ctx context.Context, event *models.Event) error {
+	if r.db.eventOutbox {
+		// The outbox row must commit with the event or not at all.
+		return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+			return r.createWithExecutor(ctx, tx, event)
+		})
+	}
 	return r.createWithExecutor(ctx, r.db, event)
 }
 
@@ -73,6 +82,22 @@ func (r *EventRepository) CreateWithTx(ctx context.Context, tx *sql.Tx, event *m
 	return r.createWithExecutor(ctx, tx, event)
 }
 
+// CreateBatch appends several events in a single transaction. Either all
+// events are written or none are.
+func (r *EventRepository) CreateBatch(ctx context.Context, events []*models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		for _, event := range events {
+			if err := r.createWithExecutor(ctx, tx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *EventRepository) createWithExecutor(ctx context.Context, execer eventExecer, event *models.Event) error {
 	if event.Type == "" {
 		return fmt.Errorf("event type is required")
@@ -87,12 +112,17 @@ func (r *EventRepository) createWithExecutor(ctx context.Context, execer eventEx
 	if event.ID == "" {
 		event.ID = uuid.New().String()
 	}
+	if event.TraceID == "" {
+		event.TraceID = trace.FromContext(ctx)
+	}
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now().UTC()
 	} else {
 		event.Timestamp = event.Timestamp.UTC()
 	}
 
+	r.redactEvent(event)
+
 	var payloadJSON *string
 	if len(event.Payload) > 0 {
 		s := string(event.Payload)
@@ -111,8 +141,8 @@ func (r *EventRepository) createWithExecutor(ctx context.Context, execer eventEx
 
 	_, err := execer.ExecContext(ctx, `
 		INSERT INTO events (
-			id, timestamp, type, entity_type, entity_id, payload_json, metadata_json
-		) VALUES (?, ?, ?, ?, ?, ?, ?)
+			id, timestamp, type, entity_type, entity_id, payload_json, metadata_json, trace_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		event.ID,
 		event.Timestamp.Format(time.RFC3339),
@@ -121,18 +151,45 @@ func (r *EventRepository) createWithExecutor(ctx context.Context, execer eventEx
 		event.EntityID,
 		payloadJSON,
 		metadataJSON,
+		nullString(event.TraceID),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert event: %w", err)
 	}
 
+	if r.db.eventOutbox {
+		if _, err := execer.ExecContext(ctx, `
+			INSERT INTO event_outbox (event_id, created_at) VALUES (?, ?)
+		`, event.ID, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			return fmt.Errorf("failed to insert event into outbox: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// redactEvent scrubs secrets from the string values of event's payload and
+// metadata. A payload is only re-encoded when its text holds a match, so
+// payloads without secrets are stored as given; one that is not valid JSON
+// is redacted as plain text.
+func (r *EventRepository) redactEvent(event *models.Event) {
+	redactor := r.db.redactor
+	if len(event.Payload) > 0 {
+		if text := redactor.Redact(string(event.Payload)); text != string(event.Payload) {
+			payload, err := redactor.RedactJSON(event.Payload)
+			if err != nil {
+				payload = []byte(text)
+			}
+			event.Payload = payload
+		}
+	}
+	event.Metadata = redactor.RedactMap(event.Metadata)
+}
+
 // Get retrieves an event by ID.
 func (r *EventRepository) Get(ctx context.Context, id string) (*models.Event, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json
+		SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json, trace_id
 		FROM events WHERE id = ?
 	`, id)
 
@@ -147,7 +204,7 @@ func (r *EventRepository) Query(ctx context.Context, q EventQuery) (*EventPage,
 	}
 
 	// Build query dynamically
-	query := `SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json FROM events WHERE 1=1`
+	query := `SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json, trace_id FROM events WHERE 1=1`
 	args := []any{}
 
 	if q.Type != nil {
@@ -162,6 +219,18 @@ func (r *EventRepository) Query(ctx context.Context, q EventQuery) (*EventPage,
 		query += ` AND entity_id = ?`
 		args = append(args, *q.EntityID)
 	}
+	if len(q.EntityIDs) > 0 {
+		placeholders := make([]string, len(q.EntityIDs))
+		for i, id := range q.EntityIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		query += fmt.Sprintf(` AND entity_id IN (%s)`, strings.Join(placeholders, ","))
+	}
+	if q.TraceID != "" {
+		query += ` AND trace_id = ?`
+		args = append(args, q.TraceID)
+	}
 	if q.Since != nil {
 		query += ` AND timestamp >= ?`
 		args = append(args, q.Since.UTC().Format(time.RFC3339))
@@ -210,6 +279,90 @@ func (r *EventRepository) Query(ctx context.Context, q EventQuery) (*EventPage,
 	return page, nil
 }
 
+// DefaultIterateBatchSize is the page size iterators use when none is given.
+const DefaultIterateBatchSize = 500
+
+// EventQueryFunc runs one page of an event query, as Query does.
+type EventQueryFunc func(ctx context.Context, q EventQuery) (*EventPage, error)
+
+// EventIterator yields query results one event at a time, holding only the
+// current batch in memory.
+type EventIterator struct {
+	ctx       context.Context
+	fetch     EventQueryFunc
+	query     EventQuery
+	remaining int
+	batch     []*models.Event
+	pos       int
+	current   *models.Event
+	done      bool
+	err       error
+}
+
+// Iterate returns an iterator over events matching q in timestamp order.
+// Rows are fetched batchSize at a time; q.Limit caps the total (0 = all).
+func (r *EventRepository) Iterate(ctx context.Context, q EventQuery, batchSize int) *EventIterator {
+	return NewEventIterator(ctx, r.Query, q, batchSize)
+}
+
+// NewEventIterator returns an iterator paging through fetch's results, for
+// event stores other than EventRepository.
+func NewEventIterator(ctx context.Context, fetch EventQueryFunc, q EventQuery, batchSize int) *EventIterator {
+	if batchSize <= 0 {
+		batchSize = DefaultIterateBatchSize
+	}
+	it := &EventIterator{ctx: ctx, fetch: fetch, query: q, remaining: q.Limit}
+	it.query.Limit = batchSize
+	return it
+}
+
+// Next advances to the next event, fetching a new batch when needed.
+func (it *EventIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos >= len(it.batch) {
+		if it.done {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		page, err := it.fetch(it.ctx, it.query)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.batch, it.pos = page.Events, 0
+		it.query.Cursor = page.NextCursor
+		it.done = page.NextCursor == ""
+		if len(it.batch) == 0 {
+			return false
+		}
+	}
+	it.current = it.batch[it.pos]
+	it.batch[it.pos] = nil
+	it.pos++
+	if it.remaining > 0 {
+		it.remaining--
+		if it.remaining == 0 {
+			it.done, it.batch = true, nil
+		}
+	}
+	return true
+}
+
+// Event returns the event at the current position.
+func (it *EventIterator) Event() *models.Event {
+	return it.current
+}
+
+// Err returns the first error hit while iterating.
+func (it *EventIterator) Err() error {
+	return it.err
+}
+
 // ListByEntity retrieves events for an entity, ordered by timestamp.
 func (r *EventRepository) ListByEntity(ctx context.Context, entityType models.EntityType, entityID string, limit int) ([]*models.Event, error) {
 	if limit <= 0 {
@@ -217,7 +370,7 @@ func (r *EventRepository) ListByEntity(ctx context.Context, entityType models.En
 	}
 
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json
+		SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json, trace_id
 		FROM events
 		WHERE entity_type = ? AND entity_id = ?
 		ORDER BY timestamp
@@ -248,6 +401,7 @@ func (r *EventRepository) scanEvent(row *sql.Row) (*models.Event, error) {
 	var timestamp, eventType, entityType string
 	var payloadJSON sql.NullString
 	var metadataJSON sql.NullString
+	var traceID sql.NullString
 
 	err := row.Scan(
 		&event.ID,
@@ -257,6 +411,7 @@ func (r *EventRepository) scanEvent(row *sql.Row) (*models.Event, error) {
 		&event.EntityID,
 		&payloadJSON,
 		&metadataJSON,
+		&traceID,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -267,6 +422,7 @@ func (r *EventRepository) scanEvent(row *sql.Row) (*models.Event, error) {
 
 	event.Type = models.EventType(eventType)
 	event.EntityType = models.EntityType(entityType)
+	event.TraceID = traceID.String
 
 	if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
 		event.Timestamp = t
@@ -289,6 +445,7 @@ func (r *EventRepository) scanEventFromRows(rows *sql.Rows) (*models.Event, erro
 	var timestamp, eventType, entityType string
 	var payloadJSON sql.NullString
 	var metadataJSON sql.NullString
+	var traceID sql.NullString
 
 	if err := rows.Scan(
 		&event.ID,
@@ -298,12 +455,14 @@ func (r *EventRepository) scanEventFromRows(rows *sql.Rows) (*models.Event, erro
 		&event.EntityID,
 		&payloadJSON,
 		&metadataJSON,
+		&traceID,
 	); err != nil {
 		return nil, fmt.Errorf("failed to scan event: %w", err)
 	}
 
 	event.Type = models.EventType(eventType)
 	event.EntityType = models.EntityType(entityType)
+	event.TraceID = traceID.String
 
 	if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
 		event.Timestamp = t
@@ -422,7 +581,7 @@ func (r *EventRepository) ListOlderThan(ctx context.Context, before time.Time, l
 	}
 
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json
+		SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json, trace_id
 		FROM events
 		WHERE timestamp < ?
 		ORDER BY timestamp
@@ -456,7 +615,7 @@ func (r *EventRepository) ListOldest(ctx context.Context, limit int) ([]*models.
 	}
 
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json
+		SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json, trace_id
 		FROM events
 		ORDER BY timestamp
 		LIMIT ?
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/trace"
 )
 
 func TestEventRepositoryAppendAndQuery(t *testing.T) {
@@ -167,6 +169,46 @@ func TestEventRepositoryTimeRange(t *testing.T) {
 	}
 }
 
+func TestEventRepositoryEntityIDs(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repo := NewEventRepository(database)
+	base := time.Now().UTC().Truncate(time.Second)
+	for i, id := range []string{"agent-1", "agent-2", "agent-3", "ws-1"} {
+		event := &models.Event{
+			Type:       models.EventTypeAgentSpawned,
+			EntityType: models.EntityTypeAgent,
+			EntityID:   id,
+			Timestamp:  base.Add(time.Duration(i) * time.Second),
+		}
+		if err := repo.Append(ctx, event); err != nil {
+			t.Fatalf("Append %s: %v", id, err)
+		}
+	}
+
+	page, err := repo.Query(ctx, EventQuery{EntityIDs: []string{"ws-1", "agent-1", "agent-3"}})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	var got []string
+	for _, event := range page.Events {
+		got = append(got, event.EntityID)
+	}
+	if len(got) != 3 || got[0] != "agent-1" || got[1] != "agent-3" || got[2] != "ws-1" {
+		t.Fatalf("expected agent-1, agent-3, ws-1 in order, got %v", got)
+	}
+}
+
 func TestEventRepositoryValidation(t *testing.T) {
 	ctx := context.Background()
 
@@ -187,3 +229,205 @@ func TestEventRepositoryValidation(t *testing.T) {
 		t.Fatalf("expected ErrInvalidEvent, got %v", err)
 	}
 }
+
+func TestEventRepositoryCreateBatch(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repo := NewEventRepository(database)
+
+	batch := []*models.Event{
+		{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: "agent-1"},
+		{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: "agent-2"},
+	}
+	if err := repo.CreateBatch(ctx, batch); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	if count, err := repo.Count(ctx); err != nil || count != 2 {
+		t.Fatalf("expected 2 events, got %d (err=%v)", count, err)
+	}
+
+	// An invalid event rolls back the whole batch.
+	bad := []*models.Event{
+		{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: "agent-3"},
+		{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent},
+	}
+	if err := repo.CreateBatch(ctx, bad); err == nil {
+		t.Fatal("expected error for invalid event in batch")
+	}
+	if count, err := repo.Count(ctx); err != nil || count != 2 {
+		t.Fatalf("expected batch to roll back, got %d events (err=%v)", count, err)
+	}
+}
+
+func TestEventRepositoryIterate(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repo := NewEventRepository(database)
+	base := time.Now().UTC().Truncate(time.Second)
+	for i := 0; i < 7; i++ {
+		event := &models.Event{
+			Type:       models.EventTypeAgentSpawned,
+			EntityType: models.EntityTypeAgent,
+			EntityID:   "agent-1",
+			Timestamp:  base.Add(time.Duration(i/2) * time.Second),
+		}
+		if err := repo.Append(ctx, event); err != nil {
+			t.Fatalf("Append %d: %v", i, err)
+		}
+	}
+
+	it := repo.Iterate(ctx, EventQuery{}, 3)
+	seen := make(map[string]bool)
+	for it.Next() {
+		if seen[it.Event().ID] {
+			t.Fatalf("event %s yielded twice", it.Event().ID)
+		}
+		seen[it.Event().ID] = true
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterate: %v", err)
+	}
+	if len(seen) != 7 {
+		t.Fatalf("expected 7 events across batches, got %d", len(seen))
+	}
+
+	limited := repo.Iterate(ctx, EventQuery{Limit: 4}, 3)
+	count := 0
+	for limited.Next() {
+		count++
+	}
+	if count != 4 {
+		t.Fatalf("expected limit to cap iteration at 4, got %d", count)
+	}
+}
+
+func TestEventRepositoryTraceID(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repo := NewEventRepository(database)
+	traced := &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: "agent-1"}
+	if err := repo.Create(trace.WithID(ctx, "run-1"), traced); err != nil {
+		t.Fatalf("Create traced: %v", err)
+	}
+	explicit := &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: "agent-2", TraceID: "run-2"}
+	if err := repo.Create(trace.WithID(ctx, "run-1"), explicit); err != nil {
+		t.Fatalf("Create explicit: %v", err)
+	}
+	untraced := &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: "agent-3"}
+	if err := repo.Create(ctx, untraced); err != nil {
+		t.Fatalf("Create untraced: %v", err)
+	}
+
+	page, err := repo.Query(ctx, EventQuery{TraceID: "run-1"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(page.Events) != 1 || page.Events[0].EntityID != "agent-1" || page.Events[0].TraceID != "run-1" {
+		t.Fatalf("expected only agent-1 under run-1, got %+v", page.Events)
+	}
+
+	all, err := repo.Query(ctx, EventQuery{})
+	if err != nil {
+		t.Fatalf("Query all: %v", err)
+	}
+	traces := make(map[string]string)
+	for _, event := range all.Events {
+		traces[event.EntityID] = event.TraceID
+	}
+	if len(traces) != 3 || traces["agent-2"] != "run-2" || traces["agent-3"] != "" {
+		t.Fatalf("expected the explicit trace kept and none on the untraced event, got %v", traces)
+	}
+}
+
+func TestEventRepositoryRedactsPayloadText(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	const secret = "sk-ant-REDACTED"
+	repo := NewEventRepository(database)
+	event := &models.Event{
+		Type:       models.EventTypeAgentStateChanged,
+		EntityType: models.EntityTypeAgent,
+		EntityID:   "agent-1",
+		Payload:    json.RawMessage(`{"new_state":"error","reason":"auth failed for key ` + secret + `","confidence":0.9}`),
+		Metadata:   map[string]string{"last_line": "export ANTHROPIC_API_KEY=" + secret},
+	}
+	if err := repo.Create(ctx, event); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	clean := &models.Event{
+		Type:       models.EventTypeAgentStateChanged,
+		EntityType: models.EntityTypeAgent,
+		EntityID:   "agent-2",
+		Payload:    json.RawMessage(`{"reason": "idle"}`),
+	}
+	if err := repo.Create(ctx, clean); err != nil {
+		t.Fatalf("Create clean: %v", err)
+	}
+
+	got, err := repo.Get(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if strings.Contains(string(got.Payload), secret) || strings.Contains(got.Metadata["last_line"], secret) {
+		t.Fatalf("expected the secret redacted, got payload %s and metadata %v", got.Payload, got.Metadata)
+	}
+	var payload struct {
+		NewState   string  `json:"new_state"`
+		Reason     string  `json:"reason"`
+		Confidence float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal(got.Payload, &payload); err != nil {
+		t.Fatalf("expected the redacted payload to stay JSON: %v", err)
+	}
+	if payload.NewState != "error" || payload.Confidence != 0.9 || payload.Reason != "auth failed for key [REDACTED:anthropic_key]" {
+		t.Fatalf("expected only the secret replaced, got %+v", payload)
+	}
+
+	got, err = repo.Get(ctx, clean.ID)
+	if err != nil {
+		t.Fatalf("Get clean: %v", err)
+	}
+	if string(got.Payload) != `{"reason": "idle"}` {
+		t.Fatalf("expected a payload without secrets stored as given, got %s", got.Payload)
+	}
+}
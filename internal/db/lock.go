@@ -0,0 +1,236 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLockTimeout is how long migrations and maintenance operations wait
+// for another swarm process to release the database lock.
+const DefaultLockTimeout = 30 * time.Second
+
+// lockPollInterval is how often a contended lock is retried.
+const lockPollInterval = 50 * time.Millisecond
+
+var (
+	// ErrLocked is returned when another process holds a lock past the wait.
+	ErrLocked = errors.New("database is locked by another swarm process")
+
+	// ErrDaemonRunning is returned by maintenance operations that must not
+	// run while swarmd has the database open.
+	ErrDaemonRunning = errors.New("swarmd is running")
+)
+
+// LockMode selects a shared or an exclusive file lock.
+type LockMode int
+
+const (
+	// LockShared may be held by several processes at once.
+	LockShared LockMode = iota
+	// LockExclusive excludes every other holder.
+	LockExclusive
+)
+
+// LockHolder describes the process that last took a lock, as recorded in
+// the lock file.
+type LockHolder struct {
+	PID       int
+	Operation string
+	Since     time.Time
+	// Alive reports whether PID is still running. A crashed holder's lock
+	// is released by the kernel, so a dead PID is just a leftover record.
+	Alive bool
+}
+
+// errLockContended is returned by tryLockFile when another open file holds
+// a conflicting lock.
+var errLockContended = errors.New("lock is held elsewhere")
+
+// LockedError is returned when a lock could not be taken in time.
+type LockedError struct {
+	Path   string
+	Holder *LockHolder
+}
+
+func (e *LockedError) Error() string {
+	if e.Holder != nil && e.Holder.Alive && e.Holder.Operation != "" {
+		return fmt.Sprintf("another swarm process (pid %d) is %s; retry once it finishes", e.Holder.PID, e.Holder.Operation)
+	}
+	return fmt.Sprintf("another swarm process holds %s; retry once it finishes", e.Path)
+}
+
+func (e *LockedError) Unwrap() error { return ErrLocked }
+
+// FileLock is an advisory lock on a file: flock(2), or LockFileEx on
+// Windows. Locks are per open file, so two FileLocks in one process contend
+// like two processes would.
+type FileLock struct {
+	path string
+	file *os.File
+	// also is released with this lock.
+	also *FileLock
+}
+
+// AcquireLock takes a lock on path, creating the file if needed, and
+// records the calling process and operation in it. A contended lock is
+// retried until timeout passes or ctx is done; a zero timeout tries once.
+func AcquireLock(ctx context.Context, path string, mode LockMode, operation string, timeout time.Duration) (*FileLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLockFile(file, mode)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLockContended) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if !time.Now().Before(deadline) {
+			file.Close()
+			return nil, &LockedError{Path: path, Holder: ReadLockHolder(path)}
+		}
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	lock := &FileLock{path: path, file: file}
+	if err := lock.record(operation); err != nil {
+		lock.Release()
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Release drops the lock. It is safe to call more than once.
+func (l *FileLock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	if alsoErr := l.also.Release(); err == nil {
+		err = alsoErr
+	}
+	return err
+}
+
+// Path returns the lock file path.
+func (l *FileLock) Path() string {
+	return l.path
+}
+
+func (l *FileLock) record(operation string) error {
+	line := fmt.Sprintf("%d\n%s\n%s\n", os.Getpid(), operation, time.Now().UTC().Format(time.RFC3339))
+	if err := l.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if _, err := l.file.WriteAt([]byte(line), 0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
+
+// ReadLockHolder returns the holder recorded in a lock file, or nil if it
+// has none.
+func ReadLockHolder(path string) *LockHolder {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(string(data), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil || pid <= 0 {
+		return nil
+	}
+	holder := &LockHolder{PID: pid, Alive: processAlive(pid)}
+	if len(lines) > 1 {
+		holder.Operation = strings.TrimSpace(lines[1])
+	}
+	if len(lines) > 2 {
+		holder.Since, _ = time.Parse(time.RFC3339, strings.TrimSpace(lines[2]))
+	}
+	return holder
+}
+
+// LockPath returns the path of the lock file that serializes migrations
+// and maintenance, or "" for an in-memory database.
+func (db *DB) LockPath() string {
+	if db.path == "" {
+		return ""
+	}
+	return db.path + ".lock"
+}
+
+// DaemonLockPath returns the path of the lock file swarmd holds shared
+// while it runs, or "" for an in-memory database.
+func (db *DB) DaemonLockPath() string {
+	if db.path == "" {
+		return ""
+	}
+	return db.path + ".daemon.lock"
+}
+
+// Lock takes the exclusive migration and maintenance lock, waiting up to
+// the configured lock timeout. In-memory databases are not locked and get
+// a nil lock, which is safe to release.
+func (db *DB) Lock(ctx context.Context, operation string) (*FileLock, error) {
+	if db.LockPath() == "" {
+		return nil, nil
+	}
+	return AcquireLock(ctx, db.LockPath(), LockExclusive, operation, db.lockTimeout)
+}
+
+// LockForMaintenance takes the exclusive lock for an operation that must
+// not run under a live swarmd, such as rolling back migrations. It fails
+// with ErrDaemonRunning while swarmd holds its daemon lock, and keeps
+// swarmd from starting until the returned lock is released.
+func (db *DB) LockForMaintenance(ctx context.Context, operation string) (*FileLock, error) {
+	if db.DaemonLockPath() == "" {
+		return nil, nil
+	}
+	daemonLock, err := AcquireLock(ctx, db.DaemonLockPath(), LockExclusive, operation, 0)
+	if err != nil {
+		var locked *LockedError
+		if errors.As(err, &locked) {
+			if holder := locked.Holder; holder != nil && holder.Alive {
+				return nil, fmt.Errorf("%w (pid %d); stop it before %s", ErrDaemonRunning, holder.PID, operation)
+			}
+			return nil, fmt.Errorf("%w; stop it before %s", ErrDaemonRunning, operation)
+		}
+		return nil, err
+	}
+	lock, err := db.Lock(ctx, operation)
+	if err != nil {
+		daemonLock.Release()
+		return nil, err
+	}
+	lock.also = daemonLock
+	return lock, nil
+}
+
+// HoldDaemonLock takes the shared daemon lock that swarmd holds for as
+// long as it runs, so maintenance commands refuse to proceed. It waits up
+// to the configured lock timeout for a maintenance operation to finish.
+func (db *DB) HoldDaemonLock(ctx context.Context) (*FileLock, error) {
+	if db.DaemonLockPath() == "" {
+		return nil, nil
+	}
+	return AcquireLock(ctx, db.DaemonLockPath(), LockShared, "running swarmd", db.lockTimeout)
+}
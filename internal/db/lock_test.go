@@ -0,0 +1,240 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcquireLockContention(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "swarm.db.lock")
+
+	first, err := AcquireLock(ctx, path, LockExclusive, "migrating the database", 0)
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+
+	_, err = AcquireLock(ctx, path, LockExclusive, "migrating the database", 100*time.Millisecond)
+	var locked *LockedError
+	if !errors.As(err, &locked) || !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected a LockedError, got %v", err)
+	}
+	if locked.Holder == nil || locked.Holder.PID != os.Getpid() || !locked.Holder.Alive {
+		t.Fatalf("expected this process as the holder, got %+v", locked.Holder)
+	}
+	if !strings.Contains(err.Error(), "is migrating the database; retry") {
+		t.Fatalf("unexpected error message %q", err)
+	}
+
+	// A waiter gets the lock as soon as it is released.
+	acquired := make(chan error, 1)
+	go func() {
+		second, err := AcquireLock(ctx, path, LockExclusive, "second", 5*time.Second)
+		if err == nil {
+			err = second.Release()
+		}
+		acquired <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if err := <-acquired; err != nil {
+		t.Fatalf("waiting AcquireLock: %v", err)
+	}
+	if err := first.Release(); err != nil {
+		t.Fatalf("second Release: %v", err)
+	}
+}
+
+func TestAcquireLockSharedExcludesExclusive(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "swarm.db.daemon.lock")
+
+	a, err := AcquireLock(ctx, path, LockShared, "running swarmd", 0)
+	if err != nil {
+		t.Fatalf("AcquireLock shared: %v", err)
+	}
+	defer a.Release()
+	b, err := AcquireLock(ctx, path, LockShared, "running swarmd", 0)
+	if err != nil {
+		t.Fatalf("expected a second shared holder, got %v", err)
+	}
+	defer b.Release()
+
+	if _, err := AcquireLock(ctx, path, LockExclusive, "rolling back migrations", 0); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected shared holders to exclude an exclusive lock, got %v", err)
+	}
+}
+
+func TestAcquireLockIgnoresDeadHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swarm.db.lock")
+
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("run true: %v", err)
+	}
+	record := []byte(strings.Join([]string{
+		strconv.Itoa(cmd.Process.Pid), "migrating the database", time.Now().UTC().Format(time.RFC3339),
+	}, "\n"))
+	if err := os.WriteFile(path, record, 0o600); err != nil {
+		t.Fatalf("write lock file: %v", err)
+	}
+
+	holder := ReadLockHolder(path)
+	if holder == nil || holder.PID != cmd.Process.Pid || holder.Alive {
+		t.Fatalf("expected a dead holder record, got %+v", holder)
+	}
+
+	lock, err := AcquireLock(context.Background(), path, LockExclusive, "migrating the database", 0)
+	if err != nil {
+		t.Fatalf("expected a leftover lock file not to block, got %v", err)
+	}
+	defer lock.Release()
+	if holder := ReadLockHolder(path); holder == nil || holder.PID != os.Getpid() {
+		t.Fatalf("expected the new holder recorded, got %+v", holder)
+	}
+}
+
+// TestLockHelperProcess holds the lock named by SWARM_LOCK_HELPER until it
+// is killed. It is run as a subprocess by TestAcquireLockReleasedOnCrash.
+func TestLockHelperProcess(t *testing.T) {
+	path := os.Getenv("SWARM_LOCK_HELPER")
+	if path == "" {
+		t.Skip("helper process")
+	}
+	if _, err := AcquireLock(context.Background(), path, LockExclusive, "migrating the database", 0); err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+	os.Stdout.WriteString("locked\n")
+	time.Sleep(time.Minute)
+}
+
+func TestAcquireLockReleasedOnCrash(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "swarm.db.lock")
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLockHelperProcess$")
+	cmd.Env = append(os.Environ(), "SWARM_LOCK_HELPER="+path)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start helper: %v", err)
+	}
+	defer cmd.Process.Kill()
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "locked\n" {
+		t.Fatalf("helper did not take the lock: %q (%v)", line, err)
+	}
+
+	_, err = AcquireLock(ctx, path, LockExclusive, "migrating the database", 0)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Holder == nil || locked.Holder.PID != cmd.Process.Pid {
+		t.Fatalf("expected the helper reported as holder, got %v", err)
+	}
+
+	if err := cmd.Process.Kill(); err != nil {
+		t.Fatalf("kill helper: %v", err)
+	}
+	_ = cmd.Wait()
+
+	lock, err := AcquireLock(ctx, path, LockExclusive, "migrating the database", time.Second)
+	if err != nil {
+		t.Fatalf("expected the lock back after the holder died, got %v", err)
+	}
+	lock.Release()
+}
+
+func TestConcurrentMigrateUpSerializes(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "swarm.db")
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+
+	const processes = 4
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		applied int
+		errs    []error
+	)
+	for i := 0; i < processes; i++ {
+		// Separate handles stand in for separate swarm processes.
+		database, err := Open(Config{Path: path, MaxOpenConns: 4, BusyTimeoutMs: 5000})
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer database.Close()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runs, err := database.MigrateUp(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			applied += len(runs)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		t.Fatalf("concurrent MigrateUp failed: %v", errs)
+	}
+	if applied != len(migrations) {
+		t.Fatalf("expected %d migrations applied once in total, got %d", len(migrations), applied)
+	}
+
+	database, err := Open(Config{Path: path})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close()
+	var rows int
+	if err := database.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_version").Scan(&rows); err != nil {
+		t.Fatalf("count schema_version: %v", err)
+	}
+	if rows != len(migrations) {
+		t.Fatalf("expected %d schema_version rows, got %d", len(migrations), rows)
+	}
+}
+
+func TestMigrateDownRefusesWhileDaemonRuns(t *testing.T) {
+	ctx := context.Background()
+	database, err := Open(Config{Path: filepath.Join(t.TempDir(), "swarm.db")})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	daemonLock, err := database.HoldDaemonLock(ctx)
+	if err != nil {
+		t.Fatalf("HoldDaemonLock: %v", err)
+	}
+	if _, err := database.MigrateDown(ctx, 1); !errors.Is(err, ErrDaemonRunning) {
+		t.Fatalf("expected ErrDaemonRunning, got %v", err)
+	}
+
+	daemonLock.Release()
+	if runs, err := database.MigrateDown(ctx, 1); err != nil || len(runs) != 1 {
+		t.Fatalf("expected one rollback once swarmd stopped, got %d (%v)", len(runs), err)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package db
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockFile takes a flock(2) lock on file without waiting.
+func tryLockFile(file *os.File, mode LockMode) error {
+	how := syscall.LOCK_SH
+	if mode == LockExclusive {
+		how = syscall.LOCK_EX
+	}
+	err := syscall.Flock(int(file.Fd()), how|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLockContended
+	}
+	return err
+}
+
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
+
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,52 @@
+//go:build windows
+
+package db
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for a process
+// that is still running (STILL_ACTIVE).
+const stillActive = 259
+
+// lockOffsetHigh places the locked byte at 4 GiB, past anything written to
+// the lock file. LockFileEx locks are mandatory, so locking the holder
+// record itself would keep ReadLockHolder from reading it.
+const lockOffsetHigh = 1
+
+// tryLockFile takes a LockFileEx lock on file without waiting.
+func tryLockFile(file *os.File, mode LockMode) error {
+	flags := uint32(windows.LOCKFILE_FAIL_IMMEDIATELY)
+	if mode == LockExclusive {
+		flags |= windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	ol := &windows.Overlapped{OffsetHigh: lockOffsetHigh}
+	err := windows.LockFileEx(windows.Handle(file.Fd()), flags, 0, 1, 0, ol)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLockContended
+	}
+	return err
+}
+
+func unlockFile(file *os.File) error {
+	ol := &windows.Overlapped{OffsetHigh: lockOffsetHigh}
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, ol)
+}
+
+func processAlive(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// A process we may not query is still running.
+		return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	}
+	defer windows.CloseHandle(handle)
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
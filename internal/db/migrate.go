@@ -3,37 +3,88 @@ package db
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/fs"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// Migration represents a single database migration.
+// Migration represents a single database migration. A migration with no
+// down SQL, from a missing or comment-only down file, is irreversible: the
+// schema cannot be rolled back past it.
 type Migration struct {
-	Version     int
-	Description string
-	UpSQL       string
-	DownSQL     string
+	Version      int
+	Description  string
+	UpSQL        string
+	DownSQL      string
+	Checksum     string
+	Irreversible bool
 }
 
 // MigrationStatus represents the status of a migration.
 type MigrationStatus struct {
-	Version     int
-	Description string
-	Applied     bool
-	AppliedAt   string
+	Version      int
+	Description  string
+	Applied      bool
+	AppliedAt    string
+	Irreversible bool
+
+	// Modified is set when the migration's up SQL no longer matches the
+	// checksum recorded when it was applied.
+	Modified bool
 }
 
+// MigrationDirection says whether a migration step applies or rolls back.
+type MigrationDirection string
+
+const (
+	MigrationUp   MigrationDirection = "up"
+	MigrationDown MigrationDirection = "down"
+)
+
+// MigrationStep is one migration to apply or roll back.
+type MigrationStep struct {
+	Version     int                `json:"version"`
+	Description string             `json:"description"`
+	Direction   MigrationDirection `json:"direction"`
+	Checksum    string             `json:"checksum"`
+
+	// Summary lists the statements the step runs, one line each.
+	Summary []string `json:"summary"`
+}
+
+// MigrationRun is a migration step that ran.
+type MigrationRun struct {
+	MigrationStep
+	Duration time.Duration `json:"duration"`
+}
+
+var (
+	// ErrIrreversibleMigration is returned for a rollback past a migration
+	// that has no down SQL. Nothing is rolled back.
+	ErrIrreversibleMigration = errors.New("irreversible migration")
+
+	// ErrMigrationChecksumMismatch is returned when an applied migration's
+	// up SQL has changed since it was applied.
+	ErrMigrationChecksumMismatch = errors.New("migration checksum mismatch")
+)
+
 // migrationFilePattern matches migration filenames like "001_initial_schema.up.sql"
 var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
 
+// migrationSource holds the migrations directory. Tests replace it.
+var migrationSource fs.FS = migrationsFS
+
 // loadMigrations reads all migrations from the embedded filesystem.
 func loadMigrations() ([]Migration, error) {
-	entries, err := migrationsFS.ReadDir("migrations")
+	entries, err := fs.ReadDir(migrationSource, "migrations")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
 	}
@@ -84,7 +135,7 @@ func loadMigrations() ([]Migration, error) {
 		}
 
 		if files.upFile != "" {
-			content, err := fs.ReadFile(migrationsFS, "migrations/"+files.upFile)
+			content, err := fs.ReadFile(migrationSource, "migrations/"+files.upFile)
 			if err != nil {
 				return nil, fmt.Errorf("failed to read %s: %w", files.upFile, err)
 			}
@@ -92,13 +143,15 @@ func loadMigrations() ([]Migration, error) {
 		}
 
 		if files.downFile != "" {
-			content, err := fs.ReadFile(migrationsFS, "migrations/"+files.downFile)
+			content, err := fs.ReadFile(migrationSource, "migrations/"+files.downFile)
 			if err != nil {
 				return nil, fmt.Errorf("failed to read %s: %w", files.downFile, err)
 			}
 			m.DownSQL = string(content)
 		}
 
+		m.Checksum = migrationChecksum(m.UpSQL)
+		m.Irreversible = !hasSQL(m.DownSQL)
 		migrations = append(migrations, m)
 	}
 
@@ -109,159 +162,312 @@ func loadMigrations() ([]Migration, error) {
 	return migrations, nil
 }
 
-// MigrateUp applies all pending migrations.
-func (db *DB) MigrateUp(ctx context.Context) (int, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
+// migrationChecksum is the SHA-256 of up SQL, with line endings normalized
+// so a checkout that converts them does not count as an edit.
+func migrationChecksum(upSQL string) string {
+	sum := sha256.Sum256([]byte(strings.ReplaceAll(upSQL, "\r\n", "\n")))
+	return hex.EncodeToString(sum[:])
+}
 
-	if err := db.ensureSchemaVersionTable(ctx); err != nil {
-		return 0, err
+// hasSQL reports whether sql holds anything besides comments and blank
+// lines.
+func hasSQL(sql string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return true
+		}
 	}
+	return false
+}
 
-	migrations, err := loadMigrations()
-	if err != nil {
-		return 0, err
+// sqlStatementStart matches the unindented first line of a statement;
+// trigger bodies and continuation lines are indented.
+var sqlStatementStart = regexp.MustCompile(`(?i)^(CREATE|DROP|ALTER|INSERT|UPDATE|DELETE|PRAGMA|REPLACE)\b`)
+
+// summarizeSQL returns the first line of each statement in sql, cut at its
+// column list, for dry runs. Comments and blank lines are skipped.
+func summarizeSQL(sql string) []string {
+	var summary []string
+	for _, line := range strings.Split(strings.ReplaceAll(sql, "\r\n", "\n"), "\n") {
+		if !sqlStatementStart.MatchString(line) {
+			continue
+		}
+		if idx := strings.IndexAny(line, "(;"); idx > 0 {
+			line = line[:idx]
+		}
+		line = strings.Join(strings.Fields(line), " ")
+		if len(line) > 80 {
+			line = line[:77] + "..."
+		}
+		summary = append(summary, line)
 	}
+	return summary
+}
 
-	currentVersion, err := db.getCurrentVersion(ctx)
+// MigrateUp applies all pending migrations and returns those it applied.
+// Processes sharing the database file apply them one at a time under the
+// database lock. Applied migrations whose files have since been edited
+// fail it with ErrMigrationChecksumMismatch.
+func (db *DB) MigrateUp(ctx context.Context) ([]MigrationRun, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	migrations, err := loadMigrations()
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	if err := db.verifyChecksums(ctx, migrations); err != nil {
+		return nil, err
 	}
 
-	applied := 0
-	for _, m := range migrations {
-		if m.Version <= currentVersion {
-			continue
-		}
+	// Most opens find nothing to apply; only those that do take the lock.
+	if current, err := db.getCurrentVersion(ctx); err == nil && !hasPending(migrations, current) {
+		return nil, nil
+	}
 
-		if m.UpSQL == "" {
-			return applied, fmt.Errorf("migration %d has no up SQL", m.Version)
-		}
+	lock, err := db.Lock(ctx, "migrating the database")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
 
-		if err := db.applyMigrationTx(ctx, m.Version, m.Description, m.UpSQL); err != nil {
-			return applied, fmt.Errorf("migration %d failed: %w", m.Version, err)
-		}
+	if err := db.ensureSchemaVersionTable(ctx); err != nil {
+		return nil, err
+	}
 
-		db.logger.Info().
-			Int("version", m.Version).
-			Str("description", m.Description).
-			Msg("applied migration")
-		applied++
+	// Re-read under the lock: another process may have migrated meanwhile.
+	currentVersion, err := db.getCurrentVersion(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return applied, nil
+	steps, err := planMigrations(migrations, currentVersion, latestVersion(migrations))
+	if err != nil {
+		return nil, err
+	}
+	return db.runMigrations(ctx, migrations, steps)
 }
 
-// MigrateDown rolls back the last n migrations.
-func (db *DB) MigrateDown(ctx context.Context, steps int) (int, error) {
+// MigrateDown rolls back the last n migrations and returns those it rolled
+// back. It refuses to run while swarmd holds the database, and rolls back
+// nothing if one of them is irreversible.
+func (db *DB) MigrateDown(ctx context.Context, steps int) ([]MigrationRun, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
+	lock, err := db.LockForMaintenance(ctx, "rolling back migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
 	if err := db.ensureSchemaVersionTable(ctx); err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	migrations, err := loadMigrations()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	currentVersion, err := db.getCurrentVersion(ctx)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	if currentVersion == 0 {
-		return 0, nil // Nothing to roll back
+	plan, err := planMigrations(migrations, currentVersion, rollbackTarget(migrations, currentVersion, steps))
+	if err != nil {
+		return nil, err
 	}
+	return db.runMigrations(ctx, migrations, plan)
+}
 
-	// Find migrations to roll back (in reverse order)
-	var toRollback []Migration
-	for i := len(migrations) - 1; i >= 0 && len(toRollback) < steps; i-- {
-		if migrations[i].Version <= currentVersion {
-			toRollback = append(toRollback, migrations[i])
-		}
+// MigrateTo migrates up or down to a specific version and returns the
+// steps it ran. Since that may roll back, it refuses to run while swarmd
+// holds the database.
+func (db *DB) MigrateTo(ctx context.Context, targetVersion int) ([]MigrationRun, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	lock, err := db.LockForMaintenance(ctx, "migrating the database")
+	if err != nil {
+		return nil, err
 	}
+	defer lock.Release()
 
-	rolledBack := 0
-	for _, m := range toRollback {
-		if m.DownSQL == "" {
-			return rolledBack, fmt.Errorf("migration %d has no down SQL", m.Version)
-		}
+	if err := db.ensureSchemaVersionTable(ctx); err != nil {
+		return nil, err
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
 
-		if err := db.rollbackMigrationTx(ctx, m.Version, m.DownSQL); err != nil {
-			return rolledBack, fmt.Errorf("rollback of migration %d failed: %w", m.Version, err)
+	currentVersion, err := db.getCurrentVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if targetVersion > currentVersion {
+		if err := db.verifyChecksums(ctx, migrations); err != nil {
+			return nil, err
 		}
+	}
 
-		db.logger.Info().
-			Int("version", m.Version).
-			Str("description", m.Description).
-			Msg("rolled back migration")
-		rolledBack++
+	plan, err := planMigrations(migrations, currentVersion, targetVersion)
+	if err != nil {
+		return nil, err
 	}
+	return db.runMigrations(ctx, migrations, plan)
+}
 
-	return rolledBack, nil
+// PlanMigrateUp returns the steps MigrateUp would run, without running
+// them or taking any lock.
+func (db *DB) PlanMigrateUp(ctx context.Context) ([]MigrationStep, error) {
+	return db.planFromCurrent(ctx, func(migrations []Migration, current int) int {
+		return latestVersion(migrations)
+	})
 }
 
-// MigrateTo migrates to a specific version.
-func (db *DB) MigrateTo(ctx context.Context, targetVersion int) error {
-	db.mu.Lock()
-	defer db.mu.Unlock()
+// PlanMigrateDown returns the steps MigrateDown would run.
+func (db *DB) PlanMigrateDown(ctx context.Context, steps int) ([]MigrationStep, error) {
+	return db.planFromCurrent(ctx, func(migrations []Migration, current int) int {
+		return rollbackTarget(migrations, current, steps)
+	})
+}
 
-	if err := db.ensureSchemaVersionTable(ctx); err != nil {
-		return err
-	}
+// PlanMigrateTo returns the steps MigrateTo would run.
+func (db *DB) PlanMigrateTo(ctx context.Context, targetVersion int) ([]MigrationStep, error) {
+	return db.planFromCurrent(ctx, func([]Migration, int) int {
+		return targetVersion
+	})
+}
+
+func (db *DB) planFromCurrent(ctx context.Context, target func([]Migration, int) int) ([]MigrationStep, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
 	migrations, err := loadMigrations()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	// A database never migrated has no schema_version table yet, and a dry
+	// run does not create one.
 	currentVersion, err := db.getCurrentVersion(ctx)
 	if err != nil {
-		return err
-	}
-
-	if targetVersion == currentVersion {
-		return nil
+		if !isMissingTable(err) {
+			return nil, err
+		}
+		currentVersion = 0
 	}
+	return planMigrations(migrations, currentVersion, target(migrations, currentVersion))
+}
 
-	if targetVersion > currentVersion {
-		// Migrate up
+// planMigrations returns the steps from current to target: the pending
+// ups in order, or the downs in reverse. A rollback past an irreversible
+// migration fails with ErrIrreversibleMigration.
+func planMigrations(migrations []Migration, current, target int) ([]MigrationStep, error) {
+	var steps []MigrationStep
+	if target >= current {
 		for _, m := range migrations {
-			if m.Version <= currentVersion || m.Version > targetVersion {
+			if m.Version <= current || m.Version > target {
 				continue
 			}
-
-			if err := db.applyMigrationTx(ctx, m.Version, m.Description, m.UpSQL); err != nil {
-				return fmt.Errorf("migration %d failed: %w", m.Version, err)
+			if m.UpSQL == "" {
+				return nil, fmt.Errorf("migration %d has no up SQL", m.Version)
 			}
+			steps = append(steps, newMigrationStep(m, MigrationUp))
+		}
+		return steps, nil
+	}
 
-			db.logger.Info().
-				Int("version", m.Version).
-				Str("description", m.Description).
-				Msg("applied migration")
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version <= target || m.Version > current {
+			continue
 		}
-	} else {
-		// Migrate down
-		for i := len(migrations) - 1; i >= 0; i-- {
-			m := migrations[i]
-			if m.Version <= targetVersion || m.Version > currentVersion {
-				continue
-			}
+		if m.Irreversible {
+			return nil, fmt.Errorf("%w: migration %d (%s) has no down SQL, so the schema cannot be rolled back below version %d",
+				ErrIrreversibleMigration, m.Version, m.Description, m.Version)
+		}
+		steps = append(steps, newMigrationStep(m, MigrationDown))
+	}
+	return steps, nil
+}
 
-			if err := db.rollbackMigrationTx(ctx, m.Version, m.DownSQL); err != nil {
-				return fmt.Errorf("rollback of migration %d failed: %w", m.Version, err)
-			}
+func newMigrationStep(m Migration, direction MigrationDirection) MigrationStep {
+	sql := m.UpSQL
+	if direction == MigrationDown {
+		sql = m.DownSQL
+	}
+	return MigrationStep{
+		Version:     m.Version,
+		Description: m.Description,
+		Direction:   direction,
+		Checksum:    m.Checksum,
+		Summary:     summarizeSQL(sql),
+	}
+}
+
+// latestVersion returns the newest migration's version.
+func latestVersion(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
 
-			db.logger.Info().
-				Int("version", m.Version).
-				Str("description", m.Description).
-				Msg("rolled back migration")
+// rollbackTarget returns the version left after rolling back the last n
+// migrations applied up to current.
+func rollbackTarget(migrations []Migration, current, n int) int {
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Version > current {
+			continue
+		}
+		if n <= 0 {
+			return migrations[i].Version
 		}
+		n--
 	}
+	return 0
+}
 
-	return nil
+// runMigrations runs steps in order, each in its own transaction, and
+// returns those that ran.
+func (db *DB) runMigrations(ctx context.Context, migrations []Migration, steps []MigrationStep) ([]MigrationRun, error) {
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	var runs []MigrationRun
+	for _, step := range steps {
+		m := byVersion[step.Version]
+		started := time.Now()
+		if step.Direction == MigrationUp {
+			if err := db.applyMigrationTx(ctx, m, started); err != nil {
+				return runs, fmt.Errorf("migration %d failed: %w", m.Version, err)
+			}
+		} else {
+			if err := db.rollbackMigrationTx(ctx, m, started); err != nil {
+				return runs, fmt.Errorf("rollback of migration %d failed: %w", m.Version, err)
+			}
+		}
+		run := MigrationRun{MigrationStep: step, Duration: time.Since(started)}
+		runs = append(runs, run)
+
+		msg := "applied migration"
+		if step.Direction == MigrationDown {
+			msg = "rolled back migration"
+		}
+		db.logger.Info().
+			Int("version", m.Version).
+			Str("description", m.Description).
+			Dur("duration", run.Duration).
+			Msg(msg)
+	}
+	return runs, nil
 }
 
 // MigrationStatus returns the status of all migrations.
@@ -294,16 +500,28 @@ func (db *DB) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
 		}
 		applied[version] = appliedAt
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	checksums, err := db.appliedChecksums(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	var status []MigrationStatus
 	for _, m := range migrations {
 		s := MigrationStatus{
-			Version:     m.Version,
-			Description: m.Description,
+			Version:      m.Version,
+			Description:  m.Description,
+			Irreversible: m.Irreversible,
 		}
 		if appliedAt, ok := applied[m.Version]; ok {
 			s.Applied = true
 			s.AppliedAt = appliedAt
+			if checksum, ok := checksums[m.Version]; ok && checksum != m.Checksum {
+				s.Modified = true
+			}
 		}
 		status = append(status, s)
 	}
@@ -311,14 +529,93 @@ func (db *DB) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
 	return status, nil
 }
 
-// ensureSchemaVersionTable creates the schema_version table if it doesn't exist.
+// verifyChecksums fails with ErrMigrationChecksumMismatch if an applied
+// migration's up SQL has changed since it ran. Migrations applied before
+// runs were logged have no checksum to check.
+func (db *DB) verifyChecksums(ctx context.Context, migrations []Migration) error {
+	checksums, err := db.appliedChecksums(ctx)
+	if err != nil {
+		return err
+	}
+
+	var modified []string
+	for _, m := range migrations {
+		if checksum, ok := checksums[m.Version]; ok && checksum != m.Checksum {
+			modified = append(modified, fmt.Sprintf("%d (%s)", m.Version, m.Description))
+		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("%w: migration %s changed since it was applied; restore the original file or add a new migration instead",
+			ErrMigrationChecksumMismatch, strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// appliedChecksums returns the checksum each applied migration was last
+// applied with, by version.
+func (db *DB) appliedChecksums(ctx context.Context) (map[int]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT l.version, l.checksum
+		FROM migrations_log l
+		JOIN schema_version v ON v.version = l.version
+		WHERE l.id = (
+			SELECT MAX(id) FROM migrations_log
+			WHERE version = l.version AND direction = 'up'
+		)
+	`)
+	if err != nil {
+		if isMissingTable(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query migrations_log: %w", err)
+	}
+	defer rows.Close()
+
+	checksums := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, fmt.Errorf("failed to scan migrations_log row: %w", err)
+		}
+		checksums[version] = checksum
+	}
+	return checksums, rows.Err()
+}
+
+// isMissingTable reports whether err is SQLite's error for a table that
+// does not exist.
+func isMissingTable(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "no such table")
+}
+
+// hasPending reports whether any migration is newer than version.
+func hasPending(migrations []Migration, version int) bool {
+	for _, m := range migrations {
+		if m.Version > version {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureSchemaVersionTable creates the schema_version table, and the
+// migrations_log table recording every migration run, if they don't exist.
 func (db *DB) ensureSchemaVersionTable(ctx context.Context) error {
 	_, err := db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS schema_version (
 			version INTEGER PRIMARY KEY,
 			applied_at TEXT NOT NULL DEFAULT (datetime('now')),
 			description TEXT
-		)
+		);
+		CREATE TABLE IF NOT EXISTS migrations_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			version INTEGER NOT NULL,
+			direction TEXT NOT NULL CHECK (direction IN ('up', 'down')),
+			checksum TEXT NOT NULL,
+			duration_ms INTEGER NOT NULL,
+			ran_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+		);
 	`)
 	return err
 }
@@ -330,8 +627,8 @@ func (db *DB) getCurrentVersion(ctx context.Context) (int, error) {
 	return version, err
 }
 
-// applyMigrationTx applies a migration in a transaction.
-func (db *DB) applyMigrationTx(ctx context.Context, version int, description, sql string) error {
+// applyMigrationTx applies a migration in a transaction, logging the run.
+func (db *DB) applyMigrationTx(ctx context.Context, m Migration, started time.Time) error {
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -339,22 +636,26 @@ func (db *DB) applyMigrationTx(ctx context.Context, version int, description, sq
 	defer tx.Rollback()
 
 	// Execute migration SQL
-	if _, err := tx.ExecContext(ctx, sql); err != nil {
+	if _, err := tx.ExecContext(ctx, m.UpSQL); err != nil {
 		return fmt.Errorf("failed to execute migration SQL: %w", err)
 	}
 
 	// Record migration
 	if _, err := tx.ExecContext(ctx,
 		"INSERT INTO schema_version (version, description) VALUES (?, ?)",
-		version, description); err != nil {
+		m.Version, m.Description); err != nil {
 		return fmt.Errorf("failed to record migration: %w", err)
 	}
+	if err := logMigrationRun(ctx, tx, m, MigrationUp, started); err != nil {
+		return err
+	}
 
 	return tx.Commit()
 }
 
-// rollbackMigrationTx rolls back a migration in a transaction.
-func (db *DB) rollbackMigrationTx(ctx context.Context, version int, sql string) error {
+// rollbackMigrationTx rolls back a migration in a transaction, logging the
+// run.
+func (db *DB) rollbackMigrationTx(ctx context.Context, m Migration, started time.Time) error {
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -362,19 +663,31 @@ func (db *DB) rollbackMigrationTx(ctx context.Context, version int, sql string)
 	defer tx.Rollback()
 
 	// Execute rollback SQL
-	if _, err := tx.ExecContext(ctx, sql); err != nil {
+	if _, err := tx.ExecContext(ctx, m.DownSQL); err != nil {
 		return fmt.Errorf("failed to execute rollback SQL: %w", err)
 	}
 
 	// Remove migration record
 	if _, err := tx.ExecContext(ctx,
-		"DELETE FROM schema_version WHERE version = ?", version); err != nil {
+		"DELETE FROM schema_version WHERE version = ?", m.Version); err != nil {
 		return fmt.Errorf("failed to remove migration record: %w", err)
 	}
+	if err := logMigrationRun(ctx, tx, m, MigrationDown, started); err != nil {
+		return err
+	}
 
 	return tx.Commit()
 }
 
+func logMigrationRun(ctx context.Context, tx *sql.Tx, m Migration, direction MigrationDirection, started time.Time) error {
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO migrations_log (version, direction, checksum, duration_ms) VALUES (?, ?, ?, ?)",
+		m.Version, string(direction), m.Checksum, time.Since(started).Milliseconds()); err != nil {
+		return fmt.Errorf("failed to log migration: %w", err)
+	}
+	return nil
+}
+
 // CreateMigration generates new migration file templates.
 func CreateMigration(name string) (upPath, downPath string, err error) {
 	// This would normally write to the filesystem, but since we use embed.FS,
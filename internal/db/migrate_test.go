@@ -1,8 +1,13 @@
 package db
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestMigrateUp(t *testing.T) {
@@ -20,7 +25,7 @@ func TestMigrateUp(t *testing.T) {
 		t.Fatalf("MigrateUp failed: %v", err)
 	}
 
-	if applied == 0 {
+	if len(applied) == 0 {
 		t.Error("expected at least one migration to be applied")
 	}
 
@@ -50,8 +55,8 @@ func TestMigrateUp(t *testing.T) {
 		t.Fatalf("second MigrateUp failed: %v", err)
 	}
 
-	if applied != 0 {
-		t.Errorf("expected 0 migrations on second run, got %d", applied)
+	if len(applied) != 0 {
+		t.Errorf("expected 0 migrations on second run, got %d", len(applied))
 	}
 }
 
@@ -76,8 +81,8 @@ func TestMigrateDown(t *testing.T) {
 		t.Fatalf("MigrateDown failed: %v", err)
 	}
 
-	if rolledBack != 1 {
-		t.Errorf("expected 1 migration rolled back, got %d", rolledBack)
+	if len(rolledBack) != 1 {
+		t.Errorf("expected 1 migration rolled back, got %d", len(rolledBack))
 	}
 
 	migrations, err := loadMigrations()
@@ -116,7 +121,7 @@ func TestMigrateTo(t *testing.T) {
 	defer database.Close()
 
 	// Migrate to version 1
-	err = database.MigrateTo(ctx, 1)
+	_, err = database.MigrateTo(ctx, 1)
 	if err != nil {
 		t.Fatalf("MigrateTo(1) failed: %v", err)
 	}
@@ -131,7 +136,7 @@ func TestMigrateTo(t *testing.T) {
 	}
 
 	// Migrate back to 0
-	err = database.MigrateTo(ctx, 0)
+	_, err = database.MigrateTo(ctx, 0)
 	if err != nil {
 		t.Fatalf("MigrateTo(0) failed: %v", err)
 	}
@@ -255,3 +260,317 @@ func TestMigrateTablesCreated(t *testing.T) {
 		}
 	}
 }
+
+func TestMigrateAgentPaneIDs(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateTo(ctx, 11); err != nil {
+		t.Fatalf("MigrateTo(11) failed: %v", err)
+	}
+
+	for _, stmt := range []string{
+		`INSERT INTO nodes (id, name) VALUES ('node-1', 'local')`,
+		`INSERT INTO workspaces (id, name, node_id, repo_path, tmux_session) VALUES ('ws-1', 'demo', 'node-1', '/repo', 'swarm-demo')`,
+		`INSERT INTO agents (id, workspace_id, type, tmux_pane) VALUES
+			('agent-bare', 'ws-1', 'opencode', '%3'),
+			('agent-prefixed', 'ws-1', 'opencode', 'other:%7'),
+			('agent-positional', 'ws-1', 'opencode', 'swarm-demo:1.2')`,
+	} {
+		if _, err := database.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("seed failed: %v", err)
+		}
+	}
+
+	if _, err := database.MigrateTo(ctx, 12); err != nil {
+		t.Fatalf("MigrateTo(12) failed: %v", err)
+	}
+
+	want := map[string][2]string{
+		"agent-bare":       {"%3", "swarm-demo"},
+		"agent-prefixed":   {"%7", "other"},
+		"agent-positional": {"swarm-demo:1.2", "swarm-demo"},
+	}
+	for id, expected := range want {
+		var pane, session string
+		if err := database.QueryRowContext(ctx, "SELECT tmux_pane, tmux_session FROM agents WHERE id = ?", id).Scan(&pane, &session); err != nil {
+			t.Fatalf("failed to read %s: %v", id, err)
+		}
+		if pane != expected[0] || session != expected[1] {
+			t.Errorf("%s: got pane %q session %q, want %q %q", id, pane, session, expected[0], expected[1])
+		}
+	}
+}
+
+func TestMigrateWorkspacePathCollisions(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateTo(ctx, 21); err != nil {
+		t.Fatalf("MigrateTo(21) failed: %v", err)
+	}
+
+	for _, stmt := range []string{
+		`INSERT INTO nodes (id, name) VALUES ('node-1', 'local'), ('node-2', 'remote')`,
+		`INSERT INTO workspaces (id, name, node_id, repo_path, tmux_session) VALUES
+			('ws-bare', 'bare', 'node-1', '/repo', 'swarm-bare'),
+			('ws-slash', 'slash', 'node-1', '/repo/', 'swarm-slash'),
+			('ws-slashes', 'slashes', 'node-1', '/repo//', 'swarm-slashes'),
+			('ws-other', 'other', 'node-1', '/other/', 'swarm-other'),
+			('ws-other-slashes', 'other-slashes', 'node-1', '/other//', 'swarm-other-slashes'),
+			('ws-remote', 'remote', 'node-2', '/repo/', 'swarm-remote')`,
+	} {
+		if _, err := database.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("seed failed: %v", err)
+		}
+	}
+
+	if _, err := database.MigrateTo(ctx, 22); err != nil {
+		t.Fatalf("MigrateTo(22) failed: %v", err)
+	}
+
+	want := map[string][2]string{
+		"ws-bare":          {"/repo", "active"},
+		"ws-slash":         {"/repo#duplicate-ws-slash", "error"},
+		"ws-slashes":       {"/repo#duplicate-ws-slashes", "error"},
+		"ws-other":         {"/other", "active"},
+		"ws-other-slashes": {"/other#duplicate-ws-other-slashes", "error"},
+		"ws-remote":        {"/repo", "active"},
+	}
+	for id, expected := range want {
+		var repoPath, status string
+		if err := database.QueryRowContext(ctx, "SELECT repo_path, status FROM workspaces WHERE id = ?", id).Scan(&repoPath, &status); err != nil {
+			t.Fatalf("failed to read %s: %v", id, err)
+		}
+		if repoPath != expected[0] || status != expected[1] {
+			t.Errorf("%s: got path %q status %q, want %q %q", id, repoPath, status, expected[0], expected[1])
+		}
+	}
+
+	if _, err := database.ExecContext(ctx, `INSERT INTO workspaces (id, name, node_id, repo_path, tmux_session) VALUES ('ws-new', 'new', 'node-1', '/repo/', 'swarm-new')`); err == nil {
+		t.Fatal("expected a trailing-slash duplicate to be rejected")
+	}
+}
+
+func TestMigrateAdapterAgentTypes(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+	for _, stmt := range []string{
+		`INSERT INTO nodes (id, name) VALUES ('node-1', 'local')`,
+		`INSERT INTO workspaces (id, name, node_id, repo_path, tmux_session) VALUES ('ws-1', 'demo', 'node-1', '/repo', 'swarm-demo')`,
+		`INSERT INTO agents (id, workspace_id, type, tmux_pane) VALUES ('agent-toy', 'ws-1', 'toy', '%3')`,
+	} {
+		if _, err := database.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("expected any agent type accepted: %v", err)
+		}
+	}
+
+	if _, err := database.MigrateTo(ctx, 15); err != nil {
+		t.Fatalf("MigrateTo(15) failed: %v", err)
+	}
+	var agentType string
+	if err := database.QueryRowContext(ctx, "SELECT type FROM agents WHERE id = 'agent-toy'").Scan(&agentType); err != nil || agentType != "generic" {
+		t.Fatalf("expected the toy agent downgraded to generic, got %q (%v)", agentType, err)
+	}
+	if _, err := database.ExecContext(ctx, `INSERT INTO agents (id, workspace_id, type, tmux_pane) VALUES ('agent-2', 'ws-1', 'toy', '%4')`); err == nil {
+		t.Fatal("expected the type CHECK restored")
+	}
+}
+
+func TestMigrateRoundTripPreservesData(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	defer database.Close()
+
+	applied, err := database.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+	latest := applied[len(applied)-1].Version
+	for _, stmt := range []string{
+		`INSERT INTO nodes (id, name) VALUES ('node-1', 'local')`,
+		`INSERT INTO workspaces (id, name, node_id, repo_path, tmux_session) VALUES ('ws-1', 'demo', 'node-1', '/repo', 'swarm-demo')`,
+		`INSERT INTO agents (id, workspace_id, type, tmux_pane) VALUES ('agent-1', 'ws-1', 'opencode', '%3')`,
+	} {
+		if _, err := database.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("seed failed: %v", err)
+		}
+	}
+
+	rolledBack, err := database.MigrateDown(ctx, 3)
+	if err != nil {
+		t.Fatalf("MigrateDown failed: %v", err)
+	}
+	if len(rolledBack) != 3 || rolledBack[0].Version != latest || rolledBack[0].Direction != MigrationDown {
+		t.Fatalf("expected the last 3 migrations rolled back newest first, got %+v", rolledBack)
+	}
+	reapplied, err := database.MigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+	if len(reapplied) != 3 || reapplied[2].Version != latest {
+		t.Fatalf("expected the 3 migrations reapplied, got %+v", reapplied)
+	}
+
+	var name, pane string
+	if err := database.QueryRowContext(ctx, `
+		SELECT w.name, a.tmux_pane FROM agents a JOIN workspaces w ON w.id = a.workspace_id WHERE a.id = 'agent-1'
+	`).Scan(&name, &pane); err != nil || name != "demo" || pane != "%3" {
+		t.Fatalf("expected the rows to survive the round trip, got %q %q (%v)", name, pane, err)
+	}
+
+	var logged int
+	if err := database.QueryRowContext(ctx, "SELECT COUNT(*) FROM migrations_log").Scan(&logged); err != nil {
+		t.Fatalf("failed to count migrations_log: %v", err)
+	}
+	if want := len(applied) + 6; logged != want {
+		t.Fatalf("expected %d logged runs, got %d", want, logged)
+	}
+}
+
+// useMigrations replaces the embedded migrations with files for the rest
+// of the test.
+func useMigrations(t *testing.T, files fstest.MapFS) {
+	t.Helper()
+	previous := migrationSource
+	migrationSource = files
+	t.Cleanup(func() { migrationSource = previous })
+}
+
+func widgetMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"migrations/001_widgets.up.sql":        {Data: []byte("CREATE TABLE widgets (\n    id INTEGER PRIMARY KEY,\n    name TEXT\n);\n")},
+		"migrations/001_widgets.down.sql":      {Data: []byte("DROP TABLE widgets;\n")},
+		"migrations/002_widget_color.up.sql":   {Data: []byte("ALTER TABLE widgets ADD COLUMN color TEXT;\n")},
+		"migrations/002_widget_color.down.sql": {Data: []byte("-- Dropping the column would lose every color.\n")},
+		"migrations/003_gadgets.up.sql":        {Data: []byte("-- Gadgets\nCREATE TABLE gadgets (id INTEGER PRIMARY KEY);\nCREATE INDEX idx_gadgets ON gadgets(id);\n")},
+		"migrations/003_gadgets.down.sql":      {Data: []byte("DROP TABLE gadgets;\n")},
+	}
+}
+
+func TestMigrateDownStopsAtIrreversibleMigration(t *testing.T) {
+	useMigrations(t, widgetMigrations())
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+
+	if _, err := database.MigrateDown(ctx, 2); !errors.Is(err, ErrIrreversibleMigration) {
+		t.Fatalf("expected ErrIrreversibleMigration, got %v", err)
+	}
+	if _, err := database.PlanMigrateTo(ctx, 0); !errors.Is(err, ErrIrreversibleMigration) {
+		t.Fatalf("expected the dry run blocked too, got %v", err)
+	}
+	// Nothing was rolled back, not even the reversible migration 3.
+	if version, err := database.SchemaVersion(ctx); err != nil || version != 3 {
+		t.Fatalf("expected version 3, got %d (%v)", version, err)
+	}
+
+	if _, err := database.MigrateDown(ctx, 1); err != nil {
+		t.Fatalf("MigrateDown(1) failed: %v", err)
+	}
+	if _, err := database.MigrateTo(ctx, 1); !errors.Is(err, ErrIrreversibleMigration) {
+		t.Fatalf("expected ErrIrreversibleMigration, got %v", err)
+	}
+
+	status, err := database.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	if status[0].Irreversible || !status[1].Irreversible {
+		t.Fatalf("expected only migration 2 irreversible, got %+v", status)
+	}
+}
+
+func TestMigrateDetectsEditedMigrations(t *testing.T) {
+	files := widgetMigrations()
+	useMigrations(t, files)
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateTo(ctx, 2); err != nil {
+		t.Fatalf("MigrateTo(2) failed: %v", err)
+	}
+
+	// Line endings converted by a checkout are not an edit.
+	files["migrations/001_widgets.up.sql"].Data = bytes.ReplaceAll(files["migrations/001_widgets.up.sql"].Data, []byte("\n"), []byte("\r\n"))
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+
+	files["migrations/002_widget_color.up.sql"].Data = []byte("ALTER TABLE widgets ADD COLUMN colour TEXT;\n")
+	_, err = database.MigrateUp(ctx)
+	if !errors.Is(err, ErrMigrationChecksumMismatch) || !strings.Contains(err.Error(), "2 (widget color)") {
+		t.Fatalf("expected a checksum mismatch for migration 2, got %v", err)
+	}
+
+	status, err := database.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	if status[0].Modified || !status[1].Modified {
+		t.Fatalf("expected only migration 2 modified, got %+v", status)
+	}
+}
+
+func TestPlanMigrateUp(t *testing.T) {
+	useMigrations(t, widgetMigrations())
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	defer database.Close()
+
+	steps, err := database.PlanMigrateUp(ctx)
+	if err != nil {
+		t.Fatalf("PlanMigrateUp failed: %v", err)
+	}
+	if len(steps) != 3 || steps[0].Direction != MigrationUp {
+		t.Fatalf("expected 3 steps up, got %+v", steps)
+	}
+	if want := []string{"CREATE TABLE gadgets", "CREATE INDEX idx_gadgets ON gadgets"}; !slices.Equal(steps[2].Summary, want) {
+		t.Fatalf("expected summary %q, got %q", want, steps[2].Summary)
+	}
+
+	// A dry run creates nothing, not even the schema_version table.
+	if _, err := database.SchemaVersion(ctx); err == nil {
+		t.Fatal("expected no schema_version table after a dry run")
+	}
+}
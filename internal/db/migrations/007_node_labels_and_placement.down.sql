@@ -0,0 +1,9 @@
+-- Migration: 007_node_labels_and_placement (DOWN)
+-- Description: Remove node labels and workspace placement records
+-- Created: 2025-12-28
+
+-- Rebuilding workspaces would cascade into agents, so drop the columns in
+-- place; the bundled SQLite supports DROP COLUMN for unindexed columns.
+ALTER TABLE workspaces DROP COLUMN placement_json;
+
+ALTER TABLE nodes DROP COLUMN labels_json;
@@ -0,0 +1,9 @@
+-- Migration: 007_node_labels_and_placement (UP)
+-- Description: Add node labels and workspace placement records
+-- Created: 2025-12-28
+
+ALTER TABLE nodes
+ADD COLUMN labels_json TEXT;
+
+ALTER TABLE workspaces
+ADD COLUMN placement_json TEXT;
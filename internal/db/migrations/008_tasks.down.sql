@@ -0,0 +1,10 @@
+-- Migration: 008_tasks (DOWN)
+-- Description: Remove tasks
+-- Created: 2025-12-29
+
+DROP INDEX IF EXISTS idx_queue_items_task_id;
+ALTER TABLE queue_items DROP COLUMN task_id;
+
+DROP INDEX IF EXISTS idx_tasks_workspace_id;
+DROP INDEX IF EXISTS idx_tasks_agent_status;
+DROP TABLE IF EXISTS tasks;
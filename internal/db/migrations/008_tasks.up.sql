@@ -0,0 +1,28 @@
+-- Migration: 008_tasks (UP)
+-- Description: Add tasks grouping queue items
+-- Created: 2025-12-29
+
+-- ============================================================================
+-- TASKS TABLE
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tasks (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    workspace_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    error_message TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    started_at TEXT,
+    completed_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_agent_status ON tasks(agent_id, status);
+CREATE INDEX IF NOT EXISTS idx_tasks_workspace_id ON tasks(workspace_id);
+
+-- Plain column so the down migration can drop it in place; tasks and their
+-- items are both removed with the agent.
+ALTER TABLE queue_items
+ADD COLUMN task_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_queue_items_task_id ON queue_items(task_id);
@@ -0,0 +1,15 @@
+-- Migration: 009_agent_stuck_state (DOWN)
+-- Description: Remove agent output times and the stuck state
+-- Created: 2025-12-30
+
+UPDATE agents SET state = 'working' WHERE state = 'stuck';
+
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_schema
+SET sql = replace(sql, '''starting'', ''stopped'', ''stuck'')', '''starting'', ''stopped'')')
+WHERE type = 'table' AND name = 'agents';
+
+PRAGMA writable_schema = RESET;
+
+ALTER TABLE agents DROP COLUMN last_output_at;
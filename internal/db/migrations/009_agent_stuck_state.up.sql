@@ -0,0 +1,17 @@
+-- Migration: 009_agent_stuck_state (UP)
+-- Description: Track agent output times and allow the stuck state
+-- Created: 2025-12-30
+
+-- Recreating agents to widen the state CHECK would cascade-delete every
+-- dependent row, so the stored table definition is edited in place. The
+-- ALTER TABLE afterwards bumps the schema cookie so open connections
+-- reload the new constraint.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_schema
+SET sql = replace(sql, '''starting'', ''stopped'')', '''starting'', ''stopped'', ''stuck'')')
+WHERE type = 'table' AND name = 'agents';
+
+PRAGMA writable_schema = RESET;
+
+ALTER TABLE agents ADD COLUMN last_output_at TEXT;
@@ -0,0 +1,6 @@
+-- Migration: 010_schedules (DOWN)
+-- Description: Remove recurring schedules
+-- Created: 2026-10-14
+
+DROP INDEX IF EXISTS idx_schedules_due;
+DROP TABLE IF EXISTS schedules;
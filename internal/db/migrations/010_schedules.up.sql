@@ -0,0 +1,23 @@
+-- Migration: 010_schedules (UP)
+-- Description: Add recurring cron schedules that enqueue queue items
+-- Created: 2026-10-14
+
+-- ============================================================================
+-- SCHEDULES TABLE
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS schedules (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT REFERENCES agents(id) ON DELETE CASCADE,
+    workspace_id TEXT REFERENCES workspaces(id) ON DELETE CASCADE,
+    cron_expr TEXT NOT NULL,
+    message TEXT NOT NULL,
+    template TEXT,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    last_run_at TEXT,
+    next_run_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    CHECK ((agent_id IS NULL) != (workspace_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_due ON schedules(enabled, next_run_at);
@@ -0,0 +1,5 @@
+-- Migration: 011_workspace_pause (DOWN)
+-- Description: Remove workspace-level pauses
+-- Created: 2026-10-14
+
+ALTER TABLE workspaces DROP COLUMN pause_json;
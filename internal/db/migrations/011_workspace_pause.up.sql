@@ -0,0 +1,5 @@
+-- Migration: 011_workspace_pause (UP)
+-- Description: Track workspace-level pauses
+-- Created: 2026-10-14
+
+ALTER TABLE workspaces ADD COLUMN pause_json TEXT;
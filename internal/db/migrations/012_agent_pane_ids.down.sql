@@ -0,0 +1,6 @@
+-- Migration: 012_agent_pane_ids (DOWN)
+-- Description: Drop the agent pane session column
+-- Created: 2026-10-14
+
+-- Bare pane IDs remain valid tmux targets, so tmux_pane is left as is.
+ALTER TABLE agents DROP COLUMN tmux_session;
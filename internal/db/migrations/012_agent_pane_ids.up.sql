@@ -0,0 +1,23 @@
+-- Migration: 012_agent_pane_ids (UP)
+-- Description: Store agent panes as tmux pane IDs with their session
+-- Created: 2026-10-14
+
+ALTER TABLE agents ADD COLUMN tmux_session TEXT NOT NULL DEFAULT '';
+
+-- Split "session:%12" and "session:window.pane" targets into their session.
+UPDATE agents
+SET tmux_session = substr(tmux_pane, 1, instr(tmux_pane, ':') - 1)
+WHERE instr(tmux_pane, ':') > 1;
+
+-- Keep only the pane ID where the target already carries one. Positional
+-- targets stay as they are until 'swarm agent verify-panes --fix' resolves
+-- them against the live tmux server.
+UPDATE agents
+SET tmux_pane = substr(tmux_pane, instr(tmux_pane, ':') + 1)
+WHERE instr(tmux_pane, ':') > 0
+  AND substr(tmux_pane, instr(tmux_pane, ':') + 1) LIKE '\%%' ESCAPE '\';
+
+-- Bare pane IDs live in their workspace's session.
+UPDATE agents
+SET tmux_session = COALESCE((SELECT w.tmux_session FROM workspaces w WHERE w.id = agents.workspace_id), '')
+WHERE tmux_session = '';
@@ -0,0 +1,8 @@
+-- Migration: 013_daemon_nodes (DOWN)
+-- Description: Drop the swarmd endpoint and remote agent ID columns
+-- Created: 2026-10-14
+
+ALTER TABLE agents DROP COLUMN remote_agent_id;
+
+ALTER TABLE nodes DROP COLUMN daemon_token;
+ALTER TABLE nodes DROP COLUMN daemon_endpoint;
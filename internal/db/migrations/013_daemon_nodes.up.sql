@@ -0,0 +1,8 @@
+-- Migration: 013_daemon_nodes (UP)
+-- Description: Store swarmd endpoints on nodes and remote agent IDs on agents
+-- Created: 2026-10-14
+
+ALTER TABLE nodes ADD COLUMN daemon_endpoint TEXT NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN daemon_token TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE agents ADD COLUMN remote_agent_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,36 @@
+-- Migration: 014_queue_command_items (UP)
+-- Description: Allow command queue items
+-- Created: 2026-10-14
+
+-- SQLite cannot alter a CHECK constraint; rebuild the table with 'command'.
+CREATE TABLE queue_items_new (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('message', 'pause', 'conditional', 'command')),
+    position INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'dispatched', 'completed', 'failed', 'skipped')),
+    payload_json TEXT NOT NULL,
+    error_message TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    dispatched_at TEXT,
+    completed_at TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    task_id TEXT
+);
+
+INSERT INTO queue_items_new (
+    id, agent_id, type, position, status, payload_json, error_message,
+    created_at, dispatched_at, completed_at, attempts, task_id
+)
+SELECT
+    id, agent_id, type, position, status, payload_json, error_message,
+    created_at, dispatched_at, completed_at, attempts, task_id
+FROM queue_items;
+
+DROP TABLE queue_items;
+ALTER TABLE queue_items_new RENAME TO queue_items;
+
+CREATE INDEX IF NOT EXISTS idx_queue_items_agent_id ON queue_items(agent_id);
+CREATE INDEX IF NOT EXISTS idx_queue_items_status ON queue_items(status);
+CREATE INDEX IF NOT EXISTS idx_queue_items_position ON queue_items(agent_id, position);
+CREATE INDEX IF NOT EXISTS idx_queue_items_task_id ON queue_items(task_id);
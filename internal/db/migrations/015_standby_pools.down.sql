@@ -0,0 +1,10 @@
+-- Migration: 015_standby_pools (DOWN)
+-- Description: Remove standby agents and workspace-level queues
+-- Created: 2026-10-14
+
+DROP INDEX IF EXISTS idx_workspace_queue_workspace;
+DROP INDEX IF EXISTS idx_workspace_queue_pending;
+DROP TABLE IF EXISTS workspace_queue_items;
+
+DROP INDEX IF EXISTS idx_agents_standby;
+ALTER TABLE agents DROP COLUMN standby;
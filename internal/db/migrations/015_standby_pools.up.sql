@@ -0,0 +1,30 @@
+-- Migration: 015_standby_pools (UP)
+-- Description: Mark warm standby agents and add workspace-level queues
+-- Created: 2026-10-14
+
+-- Standby agents are kept idle for work assigned from their workspace's
+-- queue. Claiming one clears the flag.
+ALTER TABLE agents ADD COLUMN standby INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_agents_standby ON agents(workspace_id, standby);
+
+-- ============================================================================
+-- WORKSPACE QUEUE ITEMS TABLE
+-- ============================================================================
+-- Items queued for any eligible agent in a workspace. The scheduler moves
+-- each into an agent's queue_items once an agent is available.
+CREATE TABLE IF NOT EXISTS workspace_queue_items (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    agent_type TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL,
+    payload_json TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'assigned')),
+    agent_id TEXT,
+    queue_item_id TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    assigned_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_queue_pending ON workspace_queue_items(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_workspace_queue_workspace ON workspace_queue_items(workspace_id);
@@ -0,0 +1,15 @@
+-- Migration: 016_adapter_agent_types (DOWN)
+-- Description: Restrict agents to the built-in adapter types
+-- Created: 2026-10-14
+
+UPDATE agents SET type = 'generic' WHERE type NOT IN ('opencode', 'claude-code', 'codex', 'gemini', 'generic');
+
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_schema
+SET sql = replace(sql, 'type TEXT NOT NULL,', 'type TEXT NOT NULL CHECK (type IN (''opencode'', ''claude-code'', ''codex'', ''gemini'', ''generic'')),')
+WHERE type = 'table' AND name = 'agents';
+
+PRAGMA writable_schema = RESET;
+
+DROP INDEX IF EXISTS idx_agents_type;
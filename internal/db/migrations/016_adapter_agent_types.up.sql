@@ -0,0 +1,17 @@
+-- Migration: 016_adapter_agent_types (UP)
+-- Description: Allow agents of any registered adapter type
+-- Created: 2026-10-14
+
+-- Agent types come from the adapter registry, which can grow at runtime,
+-- so the type CHECK is dropped. As in 009, the stored table definition is
+-- edited in place to keep dependent rows; creating the index afterwards
+-- bumps the schema cookie so open connections reload the definition.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_schema
+SET sql = replace(sql, 'type TEXT NOT NULL CHECK (type IN (''opencode'', ''claude-code'', ''codex'', ''gemini'', ''generic''))', 'type TEXT NOT NULL')
+WHERE type = 'table' AND name = 'agents';
+
+PRAGMA writable_schema = RESET;
+
+CREATE INDEX IF NOT EXISTS idx_agents_type ON agents(type);
@@ -0,0 +1,10 @@
+-- Migration: 017_agent_soft_delete (DOWN)
+-- Description: Delete terminated agents and drop the soft-delete columns
+-- Created: 2026-10-14
+
+DELETE FROM agents WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_agents_deleted_at;
+ALTER TABLE agents DROP COLUMN terminal_pane;
+ALTER TABLE agents DROP COLUMN terminal_state;
+ALTER TABLE agents DROP COLUMN deleted_at;
@@ -0,0 +1,14 @@
+-- Migration: 017_agent_soft_delete (UP)
+-- Description: Keep terminated agents for history instead of deleting them
+-- Created: 2026-10-14
+
+-- Terminated agents keep their row so events, usage, and dispatch history
+-- still resolve them; the retention pruner deletes them later. The pane a
+-- terminated agent held moves to terminal_pane and tmux_pane is set to the
+-- agent's ID, so UNIQUE(workspace_id, tmux_pane) does not block the pane
+-- from being reused.
+ALTER TABLE agents ADD COLUMN deleted_at TEXT;
+ALTER TABLE agents ADD COLUMN terminal_state TEXT;
+ALTER TABLE agents ADD COLUMN terminal_pane TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_agents_deleted_at ON agents(deleted_at);
@@ -0,0 +1,5 @@
+-- Migration: 018_node_metrics (DOWN)
+-- Description: Drop node resource metrics
+-- Created: 2026-10-14
+
+ALTER TABLE nodes DROP COLUMN metrics_json;
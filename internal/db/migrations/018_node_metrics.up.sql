@@ -0,0 +1,6 @@
+-- Migration: 018_node_metrics (UP)
+-- Description: Store the resource metrics of each node's last probe
+-- Created: 2026-10-14
+
+-- JSON-encoded models.NodeMetrics; NULL until a probe has reported.
+ALTER TABLE nodes ADD COLUMN metrics_json TEXT;
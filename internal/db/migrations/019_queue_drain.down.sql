@@ -0,0 +1,6 @@
+-- Migration: 019_queue_drain (DOWN)
+-- Description: Remove queue drain mode
+-- Created: 2026-10-14
+
+ALTER TABLE workspaces DROP COLUMN draining;
+ALTER TABLE agents DROP COLUMN draining;
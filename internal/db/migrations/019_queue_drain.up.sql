@@ -0,0 +1,8 @@
+-- Migration: 019_queue_drain (UP)
+-- Description: Let agents and workspaces drain their queues
+-- Created: 2026-10-14
+
+-- A draining agent finishes what is already queued but accepts no new
+-- queue items. A draining workspace also rejects new agents.
+ALTER TABLE agents ADD COLUMN draining INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE workspaces ADD COLUMN draining INTEGER NOT NULL DEFAULT 0;
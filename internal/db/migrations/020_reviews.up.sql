@@ -0,0 +1,63 @@
+-- Migration: 020_reviews (UP)
+-- Description: Allow review queue items and record their verdicts
+-- Created: 2026-10-14
+
+-- SQLite cannot alter a CHECK constraint; rebuild the table with 'review'.
+CREATE TABLE queue_items_new (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('message', 'pause', 'conditional', 'command', 'review')),
+    position INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'dispatched', 'completed', 'failed', 'skipped')),
+    payload_json TEXT NOT NULL,
+    error_message TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    dispatched_at TEXT,
+    completed_at TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    task_id TEXT
+);
+
+INSERT INTO queue_items_new (
+    id, agent_id, type, position, status, payload_json, error_message,
+    created_at, dispatched_at, completed_at, attempts, task_id
+)
+SELECT
+    id, agent_id, type, position, status, payload_json, error_message,
+    created_at, dispatched_at, completed_at, attempts, task_id
+FROM queue_items;
+
+DROP TABLE queue_items;
+ALTER TABLE queue_items_new RENAME TO queue_items;
+
+CREATE INDEX IF NOT EXISTS idx_queue_items_agent_id ON queue_items(agent_id);
+CREATE INDEX IF NOT EXISTS idx_queue_items_status ON queue_items(status);
+CREATE INDEX IF NOT EXISTS idx_queue_items_position ON queue_items(agent_id, position);
+CREATE INDEX IF NOT EXISTS idx_queue_items_task_id ON queue_items(task_id);
+
+-- ============================================================================
+-- REVIEWS TABLE
+-- ============================================================================
+-- One row per review queue item: what was sent and the verdict the agent
+-- gave. queue_item_id is not a foreign key so the verdict outlives the item.
+CREATE TABLE IF NOT EXISTS reviews (
+    id TEXT PRIMARY KEY,
+    queue_item_id TEXT NOT NULL UNIQUE,
+    agent_id TEXT NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    range_spec TEXT NOT NULL DEFAULT '',
+    patch_file TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'awaiting_verdict', 'completed', 'unparsed', 'failed')),
+    verdict TEXT NOT NULL DEFAULT '',
+    summary TEXT NOT NULL DEFAULT '',
+    comments_json TEXT,
+    raw_output TEXT NOT NULL DEFAULT '',
+    error_message TEXT NOT NULL DEFAULT '',
+    diff_files INTEGER NOT NULL DEFAULT 0,
+    diff_bytes INTEGER NOT NULL DEFAULT 0,
+    truncated INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    dispatched_at TEXT,
+    completed_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_agent_status ON reviews(agent_id, status);
@@ -0,0 +1,7 @@
+-- Migration: 021_trace_ids (DOWN)
+-- Description: Remove trace IDs from events and queue items
+-- Created: 2026-10-14
+
+DROP INDEX IF EXISTS idx_events_trace_id;
+ALTER TABLE queue_items DROP COLUMN trace_id;
+ALTER TABLE events DROP COLUMN trace_id;
@@ -0,0 +1,10 @@
+-- Migration: 021_trace_ids (UP)
+-- Description: Record the trace ID of the flow that created events and queue items
+-- Created: 2026-10-14
+
+-- A queue item keeps the trace of the command that queued it, so its
+-- dispatch is recorded under the same trace.
+ALTER TABLE events ADD COLUMN trace_id TEXT;
+ALTER TABLE queue_items ADD COLUMN trace_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_events_trace_id ON events(trace_id) WHERE trace_id IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Migration: 022_workspace_path_unique (DOWN)
+-- Description: Drop the trimmed repo path uniqueness index
+-- Created: 2026-10-14
+
+DROP INDEX IF EXISTS idx_workspaces_node_canonical_path;
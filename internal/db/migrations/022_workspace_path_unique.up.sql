@@ -0,0 +1,26 @@
+-- Migration: 022_workspace_path_unique (UP)
+-- Description: Reject workspaces whose repo paths differ only by a trailing slash
+-- Created: 2026-10-14
+
+-- Repo paths are canonicalized before they are stored. Rows written by
+-- older builds may still end in a slash, which let "/repo" and "/repo/"
+-- both pass UNIQUE(node_id, repo_path). Where such rows collide, the one
+-- with the shortest path is kept; the others get their id appended to the
+-- path and are marked as errored so they can be inspected and removed.
+UPDATE workspaces
+SET repo_path = rtrim(repo_path, '/') || '#duplicate-' || id,
+    status = 'error'
+WHERE EXISTS (
+    SELECT 1 FROM workspaces other
+    WHERE other.node_id = workspaces.node_id
+      AND rtrim(other.repo_path, '/') = rtrim(workspaces.repo_path, '/')
+      AND length(other.repo_path) < length(workspaces.repo_path)
+);
+
+-- Every remaining path is alone on its node once trimmed.
+UPDATE workspaces
+SET repo_path = rtrim(repo_path, '/')
+WHERE repo_path LIKE '%_/';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspaces_node_canonical_path
+    ON workspaces(node_id, rtrim(repo_path, '/'));
@@ -0,0 +1,5 @@
+-- Migration: 023_queue_metrics (DOWN)
+-- Description: Remove persisted queue metrics
+-- Created: 2026-10-14
+
+DROP TABLE IF EXISTS agent_queue_metrics;
@@ -0,0 +1,13 @@
+-- Migration: 023_queue_metrics (UP)
+-- Description: Persist per-agent queue wait and throughput aggregates
+-- Created: 2026-10-14
+
+-- One row per agent: its dispatch count, the reservoir of recent queue
+-- waits the percentiles come from, and the dispatch times of the last hour.
+CREATE TABLE IF NOT EXISTS agent_queue_metrics (
+    agent_id TEXT PRIMARY KEY REFERENCES agents(id) ON DELETE CASCADE,
+    dispatched INTEGER NOT NULL DEFAULT 0,
+    wait_samples_json TEXT NOT NULL DEFAULT '[]',
+    recent_dispatches_json TEXT NOT NULL DEFAULT '[]',
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
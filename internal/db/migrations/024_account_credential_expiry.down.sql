@@ -0,0 +1,6 @@
+-- Migration: 024_account_credential_expiry (DOWN)
+-- Description: Remove account credential expiry tracking
+-- Created: 2026-10-14
+
+ALTER TABLE accounts DROP COLUMN credential_expiry_warned_at;
+ALTER TABLE accounts DROP COLUMN credential_expires_at;
@@ -0,0 +1,8 @@
+-- Migration: 024_account_credential_expiry (UP)
+-- Description: Track when account credentials expire
+-- Created: 2026-10-14
+
+-- credential_expiry_warned_at records the warning raised for the current
+-- expiry, so each expiry is warned about once.
+ALTER TABLE accounts ADD COLUMN credential_expires_at TEXT;
+ALTER TABLE accounts ADD COLUMN credential_expiry_warned_at TEXT;
@@ -0,0 +1,5 @@
+-- Migration: 025_notes (DOWN)
+-- Description: Remove notes
+-- Created: 2026-10-14
+
+DROP TABLE IF EXISTS notes;
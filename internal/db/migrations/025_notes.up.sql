@@ -0,0 +1,16 @@
+-- Migration: 025_notes (UP)
+-- Description: Free-form notes on workspaces and agents
+-- Created: 2026-10-14
+
+-- entity_id has no foreign key so notes stay readable after their
+-- workspace is removed or their agent is terminated and pruned.
+CREATE TABLE IF NOT EXISTS notes (
+    id TEXT PRIMARY KEY,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('workspace', 'agent')),
+    entity_id TEXT NOT NULL,
+    author TEXT NOT NULL DEFAULT '',
+    text TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notes_entity ON notes(entity_type, entity_id, created_at);
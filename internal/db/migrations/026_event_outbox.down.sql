@@ -0,0 +1,6 @@
+-- Migration: 026_event_outbox (DOWN)
+-- Description: Remove the event bridge outbox
+-- Created: 2026-10-14
+
+DROP INDEX IF EXISTS idx_event_outbox_published;
+DROP TABLE IF EXISTS event_outbox;
@@ -0,0 +1,16 @@
+-- Migration: 026_event_outbox (UP)
+-- Description: Outbox of events awaiting publication to the event bridge
+-- Created: 2026-10-14
+
+-- One row per event recorded while the event bridge is enabled, written in
+-- the event's transaction. swarmd publishes rows with no published_at and
+-- sets it once the message queue has confirmed them.
+CREATE TABLE IF NOT EXISTS event_outbox (
+    event_id TEXT PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    published_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_outbox_published ON event_outbox(published_at);
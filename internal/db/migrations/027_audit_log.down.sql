@@ -0,0 +1,5 @@
+-- Migration: 027_audit_log (DOWN)
+-- Description: Remove the audit log
+-- Created: 2026-10-14
+
+DROP TABLE IF EXISTS audit_log;
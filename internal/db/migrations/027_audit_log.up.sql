@@ -0,0 +1,21 @@
+-- Migration: 027_audit_log (UP)
+-- Description: Audit log of mutating operations
+-- Created: 2026-10-14
+
+-- entity_id has no foreign key so records outlive what they are about.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id TEXT PRIMARY KEY,
+    timestamp TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    entity_type TEXT NOT NULL DEFAULT '',
+    entity_id TEXT NOT NULL DEFAULT '',
+    parameters_json TEXT,
+    result TEXT NOT NULL CHECK (result IN ('success', 'failure')),
+    error TEXT NOT NULL DEFAULT '',
+    trace_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_timestamp ON audit_log(timestamp);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, timestamp);
+CREATE INDEX IF NOT EXISTS idx_audit_log_operation ON audit_log(operation, timestamp);
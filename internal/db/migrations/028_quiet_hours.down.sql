@@ -0,0 +1,6 @@
+-- Migration: 028_quiet_hours (DOWN)
+-- Description: Remove quiet hours
+-- Created: 2026-10-14
+
+ALTER TABLE queue_items DROP COLUMN ignore_quiet_hours;
+ALTER TABLE workspaces DROP COLUMN quiet_hours;
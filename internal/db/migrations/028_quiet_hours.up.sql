@@ -0,0 +1,9 @@
+-- Migration: 028_quiet_hours (UP)
+-- Description: Recurring quiet hours for workspaces and queue items that ignore them
+-- Created: 2026-10-14
+
+-- A workspace's quiet hours spec (see internal/quiethours); NULL inherits
+-- the global setting and "none" turns quiet hours off.
+ALTER TABLE workspaces ADD COLUMN quiet_hours TEXT;
+-- Items flagged ignore_quiet_hours are dispatched during quiet hours.
+ALTER TABLE queue_items ADD COLUMN ignore_quiet_hours INTEGER NOT NULL DEFAULT 0;
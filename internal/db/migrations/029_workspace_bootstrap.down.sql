@@ -0,0 +1,5 @@
+-- Migration: 029_workspace_bootstrap (DOWN)
+-- Description: Remove workspace bootstrap records
+-- Created: 2026-10-14
+
+ALTER TABLE workspaces DROP COLUMN bootstrap_json;
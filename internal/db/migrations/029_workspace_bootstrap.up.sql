@@ -0,0 +1,7 @@
+-- Migration: 029_workspace_bootstrap (UP)
+-- Description: Record the bootstrap files written into a workspace's repository
+-- Created: 2026-10-14
+
+-- The last bootstrap applied (JSON models.WorkspaceBootstrap); NULL if the
+-- workspace was never bootstrapped.
+ALTER TABLE workspaces ADD COLUMN bootstrap_json TEXT;
@@ -0,0 +1,16 @@
+-- Migration: 030_agent_hibernation (DOWN)
+-- Description: Remove the hibernated agent state and workspace hibernation
+-- Created: 2026-10-14
+
+-- Hibernated agents have no pane left to run in.
+UPDATE agents SET state = 'stopped' WHERE state = 'hibernated';
+
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_schema
+SET sql = replace(sql, '''stopped'', ''stuck'', ''hibernated'')', '''stopped'', ''stuck'')')
+WHERE type = 'table' AND name = 'agents';
+
+PRAGMA writable_schema = RESET;
+
+ALTER TABLE workspaces DROP COLUMN hibernate_after_seconds;
@@ -0,0 +1,17 @@
+-- Migration: 030_agent_hibernation (UP)
+-- Description: Allow the hibernated agent state and per-workspace hibernation
+-- Created: 2026-10-14
+
+-- As in 009, the agents state CHECK is widened by editing the stored table
+-- definition; the ALTER TABLE below bumps the schema cookie.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_schema
+SET sql = replace(sql, '''stopped'', ''stuck'')', '''stopped'', ''stuck'', ''hibernated'')')
+WHERE type = 'table' AND name = 'agents';
+
+PRAGMA writable_schema = RESET;
+
+-- Seconds an agent may sit idle with an empty queue before it is
+-- hibernated; NULL never hibernates the workspace's agents.
+ALTER TABLE workspaces ADD COLUMN hibernate_after_seconds INTEGER;
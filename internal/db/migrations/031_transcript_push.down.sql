@@ -0,0 +1,7 @@
+-- Migration: 031_transcript_push (DOWN)
+-- Description: Remove transcript push subscriptions
+-- Created: 2026-10-14
+
+DROP INDEX IF EXISTS idx_transcript_pushes_status;
+DROP INDEX IF EXISTS idx_transcript_pushes_agent;
+DROP TABLE IF EXISTS transcript_pushes;
@@ -0,0 +1,28 @@
+-- Migration: 031_transcript_push (UP)
+-- Description: Add transcript push subscriptions followed by swarmd
+-- Created: 2026-10-14
+
+-- ============================================================================
+-- TRANSCRIPT PUSH SUBSCRIPTIONS TABLE
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS transcript_pushes (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    entry_types_json TEXT,
+    secret TEXT,
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed')),
+    stream TEXT,
+    cursor INTEGER NOT NULL DEFAULT 0,
+    head INTEGER NOT NULL DEFAULT 0,
+    delivered INTEGER NOT NULL DEFAULT 0,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    last_delivered_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    completed_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_transcript_pushes_agent ON transcript_pushes(agent_id);
+CREATE INDEX IF NOT EXISTS idx_transcript_pushes_status ON transcript_pushes(status);
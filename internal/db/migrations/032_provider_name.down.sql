@@ -0,0 +1,6 @@
+-- Migration: 032_provider_name (DOWN)
+-- Description: Remove custom provider names
+-- Created: 2026-10-14
+
+ALTER TABLE usage_records DROP COLUMN provider_name;
+ALTER TABLE accounts DROP COLUMN provider_name;
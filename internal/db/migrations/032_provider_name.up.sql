@@ -0,0 +1,6 @@
+-- Migration: 032_provider_name (UP)
+-- Description: Name the provider of custom accounts and usage records
+-- Created: 2026-10-14
+
+ALTER TABLE accounts ADD COLUMN provider_name TEXT;
+ALTER TABLE usage_records ADD COLUMN provider_name TEXT;
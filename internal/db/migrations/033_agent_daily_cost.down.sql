@@ -0,0 +1,6 @@
+-- Migration: 033_agent_daily_cost (DOWN)
+-- Description: Remove the agent daily cost cache
+-- Created: 2026-10-14
+
+DROP INDEX IF EXISTS idx_agent_daily_cost_cache_date;
+DROP TABLE IF EXISTS agent_daily_cost_cache;
@@ -0,0 +1,22 @@
+-- Migration: 033_agent_daily_cost (UP)
+-- Description: Cache each agent's final daily cost from the cost ticker
+-- Created: 2026-10-14
+
+-- ============================================================================
+-- AGENT DAILY COST CACHE TABLE
+-- ============================================================================
+-- Agents are deleted when terminated, so agent_id is not a foreign key:
+-- the day's figure outlives the agent.
+CREATE TABLE IF NOT EXISTS agent_daily_cost_cache (
+    agent_id TEXT NOT NULL,
+    date TEXT NOT NULL,  -- YYYY-MM-DD in budget.cost_timezone
+    agent_type TEXT,
+    actual_cents INTEGER NOT NULL DEFAULT 0,
+    estimated_cents INTEGER NOT NULL DEFAULT 0,
+    estimated_working_seconds INTEGER NOT NULL DEFAULT 0,
+    cost_per_hour_cents INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (agent_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_daily_cost_cache_date ON agent_daily_cost_cache(date);
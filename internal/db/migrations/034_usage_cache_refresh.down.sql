@@ -0,0 +1,7 @@
+-- Migration: 034_usage_cache_refresh (DOWN)
+-- Description: Stop tracking daily usage cache refreshes
+-- Created: 2026-10-14
+
+DROP TABLE IF EXISTS usage_cache_state;
+DROP INDEX IF EXISTS idx_usage_records_created_at;
+ALTER TABLE usage_records DROP COLUMN created_at;
@@ -0,0 +1,19 @@
+-- Migration: 034_usage_cache_refresh (UP)
+-- Description: Track which usage records the daily usage cache has seen
+-- Created: 2026-10-14
+
+-- created_at is when a record was stored, unlike recorded_at, which
+-- imports backfill; records older than this migration have none and are
+-- picked up by the first refresh, which rebuilds the cache.
+ALTER TABLE usage_records ADD COLUMN created_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_usage_records_created_at ON usage_records(created_at);
+
+-- ============================================================================
+-- USAGE CACHE STATE TABLE
+-- ============================================================================
+-- A single row holding the high-water mark of the last cache refresh.
+CREATE TABLE IF NOT EXISTS usage_cache_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    refreshed_at TEXT NOT NULL
+);
@@ -0,0 +1,43 @@
+-- Migration: 035_queue_in_flight (UP)
+-- Description: Keep dequeued items in flight until their dispatch is recorded
+-- Created: 2026-10-14
+
+-- SQLite cannot alter a CHECK constraint; rebuild the table with
+-- 'in_flight' and the dispatcher that claimed each item.
+CREATE TABLE queue_items_new (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('message', 'pause', 'conditional', 'command', 'review')),
+    position INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'in_flight', 'dispatched', 'completed', 'failed', 'skipped')),
+    payload_json TEXT NOT NULL,
+    error_message TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    dispatched_at TEXT,
+    completed_at TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    task_id TEXT,
+    trace_id TEXT,
+    ignore_quiet_hours INTEGER NOT NULL DEFAULT 0,
+    dispatcher_id TEXT
+);
+
+INSERT INTO queue_items_new (
+    id, agent_id, type, position, status, payload_json, error_message,
+    created_at, dispatched_at, completed_at, attempts, task_id, trace_id,
+    ignore_quiet_hours
+)
+SELECT
+    id, agent_id, type, position, status, payload_json, error_message,
+    created_at, dispatched_at, completed_at, attempts, task_id, trace_id,
+    ignore_quiet_hours
+FROM queue_items;
+
+DROP TABLE queue_items;
+ALTER TABLE queue_items_new RENAME TO queue_items;
+
+CREATE INDEX IF NOT EXISTS idx_queue_items_agent_id ON queue_items(agent_id);
+CREATE INDEX IF NOT EXISTS idx_queue_items_status ON queue_items(status);
+CREATE INDEX IF NOT EXISTS idx_queue_items_position ON queue_items(agent_id, position);
+CREATE INDEX IF NOT EXISTS idx_queue_items_task_id ON queue_items(task_id);
+CREATE INDEX IF NOT EXISTS idx_queue_items_in_flight ON queue_items(dispatched_at) WHERE status = 'in_flight';
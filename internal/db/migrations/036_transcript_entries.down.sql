@@ -0,0 +1,6 @@
+-- Migration: 036_transcript_entries (DOWN)
+-- Description: Remove persisted transcript entries
+-- Created: 2026-10-14
+
+DROP INDEX IF EXISTS idx_transcript_entries_timestamp;
+DROP TABLE IF EXISTS transcript_entries;
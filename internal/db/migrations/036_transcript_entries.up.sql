@@ -0,0 +1,21 @@
+-- Migration: 036_transcript_entries (UP)
+-- Description: Persist swarmd transcript entries across daemon restarts
+-- Created: 2026-10-14
+
+-- ============================================================================
+-- TRANSCRIPT ENTRIES TABLE
+-- ============================================================================
+-- agent_id has no foreign key: swarmd agents need not be registered here,
+-- and transcripts outlive the agents they belong to.
+CREATE TABLE IF NOT EXISTS transcript_entries (
+    agent_id TEXT NOT NULL,
+    entry_id INTEGER NOT NULL,
+    end_id INTEGER NOT NULL DEFAULT 0,
+    timestamp TEXT NOT NULL,
+    type TEXT NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    metadata_json TEXT,
+    PRIMARY KEY (agent_id, entry_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transcript_entries_timestamp ON transcript_entries(timestamp);
@@ -48,6 +48,11 @@ func (r *NodeRepository) Create(ctx context.Context, node *models.Node) error {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	labelsJSON, err := marshalLabels(node.Labels)
+	if err != nil {
+		return err
+	}
+
 	var lastSeen *string
 	if node.LastSeen != nil {
 		s := node.LastSeen.Format(time.RFC3339)
@@ -59,8 +64,8 @@ func (r *NodeRepository) Create(ctx context.Context, node *models.Node) error {
 			id, name, ssh_target, ssh_backend, ssh_key_path,
 			ssh_agent_forwarding, ssh_proxy_jump, ssh_control_master,
 			ssh_control_path, ssh_control_persist, ssh_timeout_seconds,
-			status, is_local, last_seen_at, metadata_json, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			status, is_local, last_seen_at, metadata_json, labels_json, daemon_endpoint, daemon_token, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		node.ID,
 		node.Name,
@@ -77,6 +82,9 @@ func (r *NodeRepository) Create(ctx context.Context, node *models.Node) error {
 		boolToInt(node.IsLocal),
 		lastSeen,
 		string(metadataJSON),
+		labelsJSON,
+		node.DaemonEndpoint,
+		node.DaemonToken,
 		node.CreatedAt.Format(time.RFC3339),
 		node.UpdatedAt.Format(time.RFC3339),
 	)
@@ -98,7 +106,8 @@ func (r *NodeRepository) Get(ctx context.Context, id string) (*models.Node, erro
 			id, name, ssh_target, ssh_backend, ssh_key_path,
 			ssh_agent_forwarding, ssh_proxy_jump, ssh_control_master, ssh_control_path,
 			ssh_control_persist, ssh_timeout_seconds, status,
-			is_local, last_seen_at, metadata_json, created_at, updated_at
+			is_local, last_seen_at, metadata_json, labels_json, daemon_endpoint, daemon_token, created_at, updated_at,
+			metrics_json
 		FROM nodes WHERE id = ?
 	`, id)
 
@@ -112,7 +121,8 @@ func (r *NodeRepository) GetByName(ctx context.Context, name string) (*models.No
 			id, name, ssh_target, ssh_backend, ssh_key_path,
 			ssh_agent_forwarding, ssh_proxy_jump, ssh_control_master, ssh_control_path,
 			ssh_control_persist, ssh_timeout_seconds, status,
-			is_local, last_seen_at, metadata_json, created_at, updated_at
+			is_local, last_seen_at, metadata_json, labels_json, daemon_endpoint, daemon_token, created_at, updated_at,
+			metrics_json
 		FROM nodes WHERE name = ?
 	`, name)
 
@@ -130,7 +140,8 @@ func (r *NodeRepository) List(ctx context.Context, status *models.NodeStatus) ([
 				id, name, ssh_target, ssh_backend, ssh_key_path,
 				ssh_agent_forwarding, ssh_proxy_jump, ssh_control_master, ssh_control_path,
 				ssh_control_persist, ssh_timeout_seconds, status,
-				is_local, last_seen_at, metadata_json, created_at, updated_at
+				is_local, last_seen_at, metadata_json, labels_json, daemon_endpoint, daemon_token, created_at, updated_at,
+				metrics_json
 			FROM nodes WHERE status = ?
 			ORDER BY name
 		`, string(*status))
@@ -140,7 +151,8 @@ func (r *NodeRepository) List(ctx context.Context, status *models.NodeStatus) ([
 				id, name, ssh_target, ssh_backend, ssh_key_path,
 				ssh_agent_forwarding, ssh_proxy_jump, ssh_control_master, ssh_control_path,
 				ssh_control_persist, ssh_timeout_seconds, status,
-				is_local, last_seen_at, metadata_json, created_at, updated_at
+				is_local, last_seen_at, metadata_json, labels_json, daemon_endpoint, daemon_token, created_at, updated_at,
+				metrics_json
 			FROM nodes ORDER BY name
 		`)
 	}
@@ -179,6 +191,11 @@ func (r *NodeRepository) Update(ctx context.Context, node *models.Node) error {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	labelsJSON, err := marshalLabels(node.Labels)
+	if err != nil {
+		return err
+	}
+
 	var lastSeen *string
 	if node.LastSeen != nil {
 		s := node.LastSeen.Format(time.RFC3339)
@@ -201,6 +218,9 @@ func (r *NodeRepository) Update(ctx context.Context, node *models.Node) error {
 			is_local = ?,
 			last_seen_at = ?,
 			metadata_json = ?,
+			labels_json = ?,
+			daemon_endpoint = ?,
+			daemon_token = ?,
 			updated_at = ?
 		WHERE id = ?
 	`,
@@ -218,6 +238,9 @@ func (r *NodeRepository) Update(ctx context.Context, node *models.Node) error {
 		boolToInt(node.IsLocal),
 		lastSeen,
 		string(metadataJSON),
+		labelsJSON,
+		node.DaemonEndpoint,
+		node.DaemonToken,
 		node.UpdatedAt.Format(time.RFC3339),
 		node.ID,
 	)
@@ -285,13 +308,39 @@ func (r *NodeRepository) UpdateStatus(ctx context.Context, id string, status mod
 	return nil
 }
 
+// UpdateMetrics stores the resource metrics of a node's last probe.
+func (r *NodeRepository) UpdateMetrics(ctx context.Context, id string, metrics *models.NodeMetrics) error {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE nodes SET metrics_json = ? WHERE id = ?
+	`, string(data), id)
+	if err != nil {
+		return fmt.Errorf("failed to update node metrics: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNodeNotFound
+	}
+
+	return nil
+}
+
 // GetAgentCount returns the number of agents on a node.
 func (r *NodeRepository) GetAgentCount(ctx context.Context, nodeID string) (int, error) {
 	var count int
 	err := r.db.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM agents a
 		JOIN workspaces w ON a.workspace_id = w.id
-		WHERE w.node_id = ?
+		WHERE w.node_id = ? AND a.deleted_at IS NULL
 	`, nodeID).Scan(&count)
 
 	if err != nil {
@@ -301,6 +350,57 @@ func (r *NodeRepository) GetAgentCount(ctx context.Context, nodeID string) (int,
 	return count, nil
 }
 
+// AgentCountsByNode returns the number of live (not stopped) agents on each
+// node. Nodes without live agents are omitted.
+func (r *NodeRepository) AgentCountsByNode(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT w.node_id, COUNT(a.id) FROM agents a
+		JOIN workspaces w ON a.workspace_id = w.id
+		WHERE a.state != 'stopped' AND a.deleted_at IS NULL
+		GROUP BY w.node_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count agents by node: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var nodeID string
+		var count int
+		if err := rows.Scan(&nodeID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan agent count: %w", err)
+		}
+		counts[nodeID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating agent counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// LatestWorkspaceNodeID returns the node of the most recently created
+// workspace, or "" if there are no workspaces.
+func (r *NodeRepository) LatestWorkspaceNodeID(ctx context.Context) (string, error) {
+	var nodeID string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT node_id FROM workspaces
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT 1
+	`).Scan(&nodeID)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to query latest workspace node: %w", err)
+	}
+
+	return nodeID, nil
+}
+
 // scanNode scans a single node from a row.
 func (r *NodeRepository) scanNode(row *sql.Row) (*models.Node, error) {
 	var node models.Node
@@ -309,7 +409,7 @@ func (r *NodeRepository) scanNode(row *sql.Row) (*models.Node, error) {
 	var agentForwarding int
 	var proxyJump, controlMaster, controlPath, controlPersist sql.NullString
 	var timeoutSeconds sql.NullInt64
-	var lastSeen, metadataJSON sql.NullString
+	var lastSeen, metadataJSON, labelsJSON, metricsJSON sql.NullString
 	var createdAt, updatedAt string
 
 	err := row.Scan(
@@ -328,8 +428,12 @@ func (r *NodeRepository) scanNode(row *sql.Row) (*models.Node, error) {
 		&isLocal,
 		&lastSeen,
 		&metadataJSON,
+		&labelsJSON,
+		&node.DaemonEndpoint,
+		&node.DaemonToken,
 		&createdAt,
 		&updatedAt,
+		&metricsJSON,
 	)
 
 	if err != nil {
@@ -372,6 +476,18 @@ func (r *NodeRepository) scanNode(row *sql.Row) (*models.Node, error) {
 		}
 	}
 
+	if labelsJSON.Valid && labelsJSON.String != "" {
+		if err := json.Unmarshal([]byte(labelsJSON.String), &node.Labels); err != nil {
+			r.db.logger.Warn().Err(err).Str("node_id", node.ID).Msg("failed to parse node labels")
+		}
+	}
+
+	if metricsJSON.Valid && metricsJSON.String != "" {
+		if err := json.Unmarshal([]byte(metricsJSON.String), &node.Metrics); err != nil {
+			r.db.logger.Warn().Err(err).Str("node_id", node.ID).Msg("failed to parse node metrics")
+		}
+	}
+
 	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
 		node.CreatedAt = t
 	}
@@ -390,7 +506,7 @@ func (r *NodeRepository) scanNodeFromRows(rows *sql.Rows) (*models.Node, error)
 	var agentForwarding int
 	var proxyJump, controlMaster, controlPath, controlPersist sql.NullString
 	var timeoutSeconds sql.NullInt64
-	var lastSeen, metadataJSON sql.NullString
+	var lastSeen, metadataJSON, labelsJSON, metricsJSON sql.NullString
 	var createdAt, updatedAt string
 
 	err := rows.Scan(
@@ -409,8 +525,12 @@ func (r *NodeRepository) scanNodeFromRows(rows *sql.Rows) (*models.Node, error)
 		&isLocal,
 		&lastSeen,
 		&metadataJSON,
+		&labelsJSON,
+		&node.DaemonEndpoint,
+		&node.DaemonToken,
 		&createdAt,
 		&updatedAt,
+		&metricsJSON,
 	)
 
 	if err != nil {
@@ -450,6 +570,18 @@ func (r *NodeRepository) scanNodeFromRows(rows *sql.Rows) (*models.Node, error)
 		}
 	}
 
+	if labelsJSON.Valid && labelsJSON.String != "" {
+		if err := json.Unmarshal([]byte(labelsJSON.String), &node.Labels); err != nil {
+			r.db.logger.Warn().Err(err).Str("node_id", node.ID).Msg("failed to parse node labels")
+		}
+	}
+
+	if metricsJSON.Valid && metricsJSON.String != "" {
+		if err := json.Unmarshal([]byte(metricsJSON.String), &node.Metrics); err != nil {
+			r.db.logger.Warn().Err(err).Str("node_id", node.ID).Msg("failed to parse node metrics")
+		}
+	}
+
 	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
 		node.CreatedAt = t
 	}
@@ -462,6 +594,18 @@ func (r *NodeRepository) scanNodeFromRows(rows *sql.Rows) (*models.Node, error)
 
 // Helper functions
 
+func marshalLabels(labels map[string]string) (*string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal labels: %w", err)
+	}
+	s := string(data)
+	return &s, nil
+}
+
 func boolToInt(b bool) int {
 	if b {
 		return 1
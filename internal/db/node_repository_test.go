@@ -291,6 +291,57 @@ func TestNodeRepository_UpdateStatus(t *testing.T) {
 	}
 }
 
+func TestNodeRepository_UpdateMetrics(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewNodeRepository(db)
+	ctx := context.Background()
+
+	node := &models.Node{
+		Name:       "metrics-node",
+		SSHTarget:  "user@host.example.com",
+		SSHBackend: models.SSHBackendAuto,
+		Status:     models.NodeStatusOnline,
+	}
+	if err := repo.Create(ctx, node); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	metrics := &models.NodeMetrics{
+		CollectedAt:    time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		CPUs:           8,
+		Load1:          1.5,
+		DiskPath:       "/home/dev",
+		DiskTotalBytes: 1000,
+		DiskFreeBytes:  50,
+		TmuxPanes:      3,
+		Degraded:       []string{"disk free 5.0% below 10%"},
+	}
+	if err := repo.UpdateMetrics(ctx, node.ID, metrics); err != nil {
+		t.Fatalf("UpdateMetrics failed: %v", err)
+	}
+
+	// Updating the node keeps its metrics.
+	node.SSHTarget = "user@other.example.com"
+	if err := repo.Update(ctx, node); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	nodes, err := repo.List(ctx, nil)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	got := nodes[0].Metrics
+	if got == nil || !got.CollectedAt.Equal(metrics.CollectedAt) || got.CPUs != 8 || got.DiskPath != "/home/dev" || len(got.Degraded) != 1 {
+		t.Fatalf("unexpected metrics %+v", got)
+	}
+
+	if err := repo.UpdateMetrics(ctx, "missing", metrics); err != ErrNodeNotFound {
+		t.Fatalf("expected ErrNodeNotFound, got %v", err)
+	}
+}
+
 func TestNodeRepository_DuplicateName(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -350,6 +401,45 @@ func TestNodeRepository_LocalNode(t *testing.T) {
 	}
 }
 
+func TestNodeRepository_DaemonNode(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewNodeRepository(db)
+	ctx := context.Background()
+
+	node := &models.Node{
+		Name:           "gpu-box",
+		DaemonEndpoint: "gpu-box:50051",
+		DaemonToken:    "secret",
+		SSHBackend:     models.SSHBackendAuto,
+		Status:         models.NodeStatusUnknown,
+	}
+	if err := repo.Create(ctx, node); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	retrieved, err := repo.GetByName(ctx, "gpu-box")
+	if err != nil {
+		t.Fatalf("GetByName failed: %v", err)
+	}
+	if !retrieved.IsDaemon() || retrieved.DaemonEndpoint != "gpu-box:50051" || retrieved.DaemonToken != "secret" {
+		t.Fatalf("expected daemon endpoint and token to round-trip, got %+v", retrieved)
+	}
+
+	retrieved.DaemonToken = "rotated"
+	if err := repo.Update(ctx, retrieved); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	nodes, err := repo.List(ctx, nil)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].DaemonToken != "rotated" {
+		t.Fatalf("expected the updated token to be listed, got %+v", nodes)
+	}
+}
+
 func TestNodeRepository_NotFound(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
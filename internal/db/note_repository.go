@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Note repository errors.
+var ErrNoteNotFound = errors.New("note not found")
+
+const noteColumns = `id, entity_type, entity_id, author, text, created_at`
+
+// NoteRepository handles note persistence.
+type NoteRepository struct {
+	db *DB
+}
+
+// NewNoteRepository creates a new NoteRepository.
+func NewNoteRepository(db *DB) *NoteRepository {
+	return &NoteRepository{db: db}
+}
+
+// Create adds a new note to the database.
+func (r *NoteRepository) Create(ctx context.Context, note *models.Note) error {
+	if note.EntityType != models.EntityTypeWorkspace && note.EntityType != models.EntityTypeAgent {
+		return fmt.Errorf("note entity type must be workspace or agent, got %q", note.EntityType)
+	}
+	if note.EntityID == "" {
+		return fmt.Errorf("note entity id is required")
+	}
+	if strings.TrimSpace(note.Text) == "" {
+		return fmt.Errorf("note text is required")
+	}
+
+	if note.ID == "" {
+		note.ID = uuid.New().String()
+	}
+	if note.CreatedAt.IsZero() {
+		note.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO notes (`+noteColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		note.ID,
+		string(note.EntityType),
+		note.EntityID,
+		note.Author,
+		note.Text,
+		note.CreatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert note: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a note by ID.
+func (r *NoteRepository) Get(ctx context.Context, id string) (*models.Note, error) {
+	notes, err := r.query(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return nil, ErrNoteNotFound
+	}
+	return notes[0], nil
+}
+
+// List lists every note, oldest first.
+func (r *NoteRepository) List(ctx context.Context) ([]*models.Note, error) {
+	return r.query(ctx, `ORDER BY created_at, rowid`)
+}
+
+// ListByEntity lists the notes on one workspace or agent, oldest first.
+func (r *NoteRepository) ListByEntity(ctx context.Context, entityType models.EntityType, entityID string) ([]*models.Note, error) {
+	return r.query(ctx, `WHERE entity_type = ? AND entity_id = ? ORDER BY created_at, rowid`, string(entityType), entityID)
+}
+
+// Latest returns the newest note on a workspace or agent.
+func (r *NoteRepository) Latest(ctx context.Context, entityType models.EntityType, entityID string) (*models.Note, error) {
+	notes, err := r.query(ctx, `WHERE entity_type = ? AND entity_id = ? ORDER BY created_at DESC, rowid DESC LIMIT 1`, string(entityType), entityID)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return nil, ErrNoteNotFound
+	}
+	return notes[0], nil
+}
+
+// Delete removes a note.
+func (r *NoteRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM notes WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNoteNotFound
+	}
+	return nil
+}
+
+func (r *NoteRepository) query(ctx context.Context, clause string, args ...any) ([]*models.Note, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+noteColumns+` FROM notes `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []*models.Note
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notes: %w", err)
+	}
+	return notes, nil
+}
+
+func scanNote(rows *sql.Rows) (*models.Note, error) {
+	var note models.Note
+	var entityType, createdAt string
+	if err := rows.Scan(&note.ID, &entityType, &note.EntityID, &note.Author, &note.Text, &createdAt); err != nil {
+		return nil, fmt.Errorf("failed to scan note: %w", err)
+	}
+	note.EntityType = models.EntityType(entityType)
+
+	created, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	note.CreatedAt = created
+	return &note, nil
+}
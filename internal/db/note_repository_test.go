@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestNoteRepository_CRUD(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewNoteRepository(db)
+	at := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	first := &models.Note{EntityType: models.EntityTypeAgent, EntityID: "agent-1", Author: "dana", Text: "retrying the flaky migration task", CreatedAt: at}
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if first.ID == "" {
+		t.Fatal("expected an ID assigned")
+	}
+	// A second note in the same second is still the latest.
+	second := &models.Note{EntityType: models.EntityTypeAgent, EntityID: "agent-1", Text: "don't kill it", CreatedAt: at}
+	if err := repo.Create(ctx, second); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	got, err := repo.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if *got != *first {
+		t.Fatalf("expected %+v, got %+v", first, got)
+	}
+	latest, err := repo.Latest(ctx, models.EntityTypeAgent, "agent-1")
+	if err != nil || latest.ID != second.ID {
+		t.Fatalf("expected the second note latest, got %+v, %v", latest, err)
+	}
+	notes, err := repo.ListByEntity(ctx, models.EntityTypeAgent, "agent-1")
+	if err != nil {
+		t.Fatalf("ListByEntity failed: %v", err)
+	}
+	if len(notes) != 2 || notes[0].ID != first.ID || notes[1].ID != second.ID {
+		t.Fatalf("expected both notes oldest first, got %+v", notes)
+	}
+
+	if err := repo.Delete(ctx, second.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := repo.Delete(ctx, second.ID); !errors.Is(err, ErrNoteNotFound) {
+		t.Fatalf("expected ErrNoteNotFound deleting twice, got %v", err)
+	}
+	if _, err := repo.Get(ctx, second.ID); !errors.Is(err, ErrNoteNotFound) {
+		t.Fatalf("expected ErrNoteNotFound, got %v", err)
+	}
+	if latest, err := repo.Latest(ctx, models.EntityTypeAgent, "agent-1"); err != nil || latest.ID != first.ID {
+		t.Fatalf("expected the first note latest again, got %+v, %v", latest, err)
+	}
+
+	for _, invalid := range []*models.Note{
+		{EntityType: models.EntityTypeNode, EntityID: "node-1", Text: "x"},
+		{EntityType: models.EntityTypeAgent, Text: "x"},
+		{EntityType: models.EntityTypeAgent, EntityID: "agent-1", Text: "  "},
+	} {
+		if err := repo.Create(ctx, invalid); err == nil {
+			t.Fatalf("expected %+v rejected", invalid)
+		}
+	}
+}
+
+func TestNoteRepository_EntityTypeScoping(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewNoteRepository(db)
+
+	// The same ID on a workspace and an agent keeps separate notes.
+	wsNote := &models.Note{EntityType: models.EntityTypeWorkspace, EntityID: "shared-id", Text: "workspace note"}
+	agentNote := &models.Note{EntityType: models.EntityTypeAgent, EntityID: "shared-id", Text: "agent note"}
+	for _, note := range []*models.Note{wsNote, agentNote} {
+		if err := repo.Create(ctx, note); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	notes, err := repo.ListByEntity(ctx, models.EntityTypeWorkspace, "shared-id")
+	if err != nil {
+		t.Fatalf("ListByEntity failed: %v", err)
+	}
+	if len(notes) != 1 || notes[0].ID != wsNote.ID {
+		t.Fatalf("expected only the workspace note, got %+v", notes)
+	}
+	latest, err := repo.Latest(ctx, models.EntityTypeAgent, "shared-id")
+	if err != nil || latest.ID != agentNote.ID {
+		t.Fatalf("expected the agent note, got %+v, %v", latest, err)
+	}
+	if _, err := repo.Latest(ctx, models.EntityTypeWorkspace, "other-id"); !errors.Is(err, ErrNoteNotFound) {
+		t.Fatalf("expected ErrNoteNotFound without notes, got %v", err)
+	}
+
+	// Notes outlive the agent they are about.
+	agent := createTestAgent(t, db, createTestWorkspace(t, db))
+	if err := repo.Create(ctx, &models.Note{EntityType: models.EntityTypeAgent, EntityID: agent.ID, Text: "about to go"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := NewAgentRepository(db).HardDelete(ctx, agent.ID); err != nil {
+		t.Fatalf("HardDelete failed: %v", err)
+	}
+	if notes, err := repo.ListByEntity(ctx, models.EntityTypeAgent, agent.ID); err != nil || len(notes) != 1 {
+		t.Fatalf("expected the note kept after the agent is deleted, got %d notes, %v", len(notes), err)
+	}
+
+	all, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 notes, got %d", len(all))
+	}
+}
@@ -248,14 +248,14 @@ func (r *PortRepository) IsPortAvailable(ctx context.Context, nodeID string, por
 	return count == 0, nil
 }
 
-// CleanupExpired releases allocations for agents that no longer exist.
-// This handles orphaned allocations from crashed agents.
+// CleanupExpired releases allocations for agents that no longer exist or
+// have been terminated. This handles orphaned allocations from crashed agents.
 func (r *PortRepository) CleanupExpired(ctx context.Context) (int, error) {
 	// Delete allocations where the agent no longer exists
 	result, err := r.db.ExecContext(ctx, `
 		DELETE FROM port_allocations 
 		WHERE agent_id IS NOT NULL 
-		AND agent_id NOT IN (SELECT id FROM agents)
+		AND agent_id NOT IN (SELECT id FROM agents WHERE deleted_at IS NULL)
 	`)
 	if err != nil {
 		return 0, fmt.Errorf("failed to cleanup expired allocations: %w", err)
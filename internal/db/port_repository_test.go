@@ -363,7 +363,7 @@ func TestPortRepository_CleanupExpired(t *testing.T) {
 	}
 
 	// Delete the agent - ON DELETE CASCADE automatically removes the port allocation
-	if err := agentRepo.Delete(ctx, agent.ID); err != nil {
+	if err := agentRepo.HardDelete(ctx, agent.ID); err != nil {
 		t.Fatalf("delete agent: %v", err)
 	}
 
@@ -0,0 +1,368 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ProviderChange is a provider and custom provider name pair rewritten by
+// NormalizeProviders.
+type ProviderChange struct {
+	// Table is "accounts" or "usage_records".
+	Table        string          `json:"table"`
+	FromProvider models.Provider `json:"from_provider"`
+	FromName     string          `json:"from_name,omitempty"`
+	ToProvider   models.Provider `json:"to_provider"`
+	ToName       string          `json:"to_name,omitempty"`
+
+	// Rows is the number of rows rewritten.
+	Rows int64 `json:"rows"`
+
+	// Conflicts counts the accounts left as they are because an account
+	// with the same profile name already exists under ToProvider.
+	Conflicts int64 `json:"conflicts,omitempty"`
+}
+
+// ProviderNameCount is a custom provider name that is not a known
+// provider, and how many rows of a table use it.
+type ProviderNameCount struct {
+	Table string `json:"table"`
+	Name  string `json:"name"`
+	Rows  int64  `json:"rows"`
+}
+
+// ProviderModelCount is a model recorded in usage records that is not in
+// its provider's model catalog.
+type ProviderModelCount struct {
+	Provider models.Provider `json:"provider"`
+	Model    string          `json:"model"`
+	Rows     int64           `json:"rows"`
+}
+
+// ProviderNormalizationReport describes what NormalizeProviders changed,
+// or would change in a dry run.
+type ProviderNormalizationReport struct {
+	DryRun  bool             `json:"dry_run"`
+	Changes []ProviderChange `json:"changes"`
+
+	// CustomNames are the custom provider names kept because they are not
+	// known providers.
+	CustomNames []ProviderNameCount `json:"custom_names"`
+
+	// UnknownModels are models outside their provider's catalog. They are
+	// only reported.
+	UnknownModels []ProviderModelCount `json:"unknown_models"`
+}
+
+// NormalizeProviders rewrites the custom provider names of accounts and
+// usage records written without the checks Create applies: names are
+// lower-cased and trimmed, and custom rows whose name is a known provider
+// or alias ("Claude", "openai-api") move to that provider. Daily usage
+// cache rows of the affected days are recomputed. With dryRun the changes
+// are made in a transaction that is rolled back, so the report is exact.
+func (db *DB) NormalizeProviders(ctx context.Context, dryRun bool) (*ProviderNormalizationReport, error) {
+	if !dryRun {
+		lock, err := db.Lock(ctx, "normalizing providers")
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Release()
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	report := &ProviderNormalizationReport{
+		DryRun:        dryRun,
+		Changes:       []ProviderChange{},
+		CustomNames:   []ProviderNameCount{},
+		UnknownModels: []ProviderModelCount{},
+	}
+	if err := normalizeAccountProviders(ctx, tx, report); err != nil {
+		return nil, err
+	}
+	if err := normalizeUsageProviders(ctx, tx, report); err != nil {
+		return nil, err
+	}
+	if err := reportUnknownModels(ctx, tx, report); err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// normalizedProvider returns the provider and custom name a stored pair
+// should have.
+func normalizedProvider(provider models.Provider, name string) (models.Provider, string) {
+	name = models.NormalizeProviderName(name)
+	if provider != models.ProviderCustom {
+		// Only custom rows carry a name.
+		return provider, ""
+	}
+	if known, err := models.ParseProvider(name); err == nil && known != models.ProviderCustom {
+		return known, ""
+	}
+	return provider, name
+}
+
+func normalizeAccountProviders(ctx context.Context, tx *sql.Tx, report *ProviderNormalizationReport) error {
+	type accountRow struct {
+		id, profile, name string
+		provider          models.Provider
+	}
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, provider, provider_name, profile_name FROM accounts
+		WHERE provider_name IS NOT NULL
+		ORDER BY provider, provider_name, profile_name
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query accounts: %w", err)
+	}
+	var accounts []accountRow
+	for rows.Next() {
+		var row accountRow
+		var provider string
+		if err := rows.Scan(&row.id, &provider, &row.name, &row.profile); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan account: %w", err)
+		}
+		row.provider = models.Provider(provider)
+		accounts = append(accounts, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating accounts: %w", err)
+	}
+
+	changes := make(map[ProviderChange]*ProviderChange)
+	var order []ProviderChange
+	customNames := make(map[string]int64)
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, account := range accounts {
+		toProvider, toName := normalizedProvider(account.provider, account.name)
+		if toProvider == models.ProviderCustom && toName != "" {
+			customNames[toName]++
+		}
+		if toProvider == account.provider && toName == account.name {
+			continue
+		}
+
+		key := ProviderChange{Table: "accounts", FromProvider: account.provider, FromName: account.name, ToProvider: toProvider, ToName: toName}
+		change, ok := changes[key]
+		if !ok {
+			change = &ProviderChange{Table: key.Table, FromProvider: key.FromProvider, FromName: key.FromName, ToProvider: key.ToProvider, ToName: key.ToName}
+			changes[key] = change
+			order = append(order, key)
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE accounts SET provider = ?, provider_name = ?, updated_at = ? WHERE id = ?
+		`, string(toProvider), nullString(toName), now, account.id)
+		if err != nil {
+			if isUniqueConstraintError(err) {
+				change.Conflicts++
+				continue
+			}
+			return fmt.Errorf("failed to update account %s: %w", account.id, err)
+		}
+		change.Rows++
+	}
+
+	for _, key := range order {
+		report.Changes = append(report.Changes, *changes[key])
+	}
+	report.CustomNames = append(report.CustomNames, sortedNameCounts("accounts", customNames)...)
+	return nil
+}
+
+func normalizeUsageProviders(ctx context.Context, tx *sql.Tx, report *ProviderNormalizationReport) error {
+	type usageGroup struct {
+		provider models.Provider
+		name     string
+		rows     int64
+	}
+	rows, err := tx.QueryContext(ctx, `
+		SELECT provider, provider_name, COUNT(*) FROM usage_records
+		WHERE provider_name IS NOT NULL
+		GROUP BY provider, provider_name
+		ORDER BY provider, provider_name
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query usage records: %w", err)
+	}
+	var groups []usageGroup
+	for rows.Next() {
+		var group usageGroup
+		var provider string
+		if err := rows.Scan(&provider, &group.name, &group.rows); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan usage records: %w", err)
+		}
+		group.provider = models.Provider(provider)
+		groups = append(groups, group)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating usage records: %w", err)
+	}
+
+	customNames := make(map[string]int64)
+	for _, group := range groups {
+		toProvider, toName := normalizedProvider(group.provider, group.name)
+		if toProvider == models.ProviderCustom && toName != "" {
+			customNames[toName] += group.rows
+		}
+		if toProvider == group.provider && toName == group.name {
+			continue
+		}
+
+		// The daily cache is keyed by provider, so the days these rows
+		// fall on are recomputed under both providers.
+		days, err := usageDays(ctx, tx, group.provider, group.name)
+		if err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, `
+			UPDATE usage_records SET provider = ?, provider_name = ?
+			WHERE provider = ? AND provider_name = ?
+		`, string(toProvider), nullString(toName), string(group.provider), group.name)
+		if err != nil {
+			return fmt.Errorf("failed to update usage records: %w", err)
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		for _, day := range days {
+			for _, provider := range []models.Provider{group.provider, toProvider} {
+				if err := recomputeDailyCache(ctx, tx, day[0], day[1], provider); err != nil {
+					return err
+				}
+			}
+		}
+
+		report.Changes = append(report.Changes, ProviderChange{
+			Table:        "usage_records",
+			FromProvider: group.provider,
+			FromName:     group.name,
+			ToProvider:   toProvider,
+			ToName:       toName,
+			Rows:         updated,
+		})
+	}
+
+	report.CustomNames = append(report.CustomNames, sortedNameCounts("usage_records", customNames)...)
+	return nil
+}
+
+// usageDays returns the (account ID, date) pairs of the usage records with
+// provider and name.
+func usageDays(ctx context.Context, tx *sql.Tx, provider models.Provider, name string) ([][2]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT account_id, date(recorded_at) FROM usage_records
+		WHERE provider = ? AND provider_name = ?
+	`, string(provider), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage days: %w", err)
+	}
+	defer rows.Close()
+
+	var days [][2]string
+	for rows.Next() {
+		var day [2]string
+		if err := rows.Scan(&day[0], &day[1]); err != nil {
+			return nil, fmt.Errorf("failed to scan usage day: %w", err)
+		}
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage days: %w", err)
+	}
+	return days, nil
+}
+
+// recomputeDailyCache rebuilds one daily usage cache row, as
+// UpdateDailyCache does, dropping it when no usage is left.
+func recomputeDailyCache(ctx context.Context, tx *sql.Tx, accountID, date string, provider models.Provider) error {
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM daily_usage_cache WHERE account_id = ? AND date = ? AND provider = ?
+	`, accountID, date, string(provider)); err != nil {
+		return fmt.Errorf("failed to clear daily cache: %w", err)
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO daily_usage_cache (
+			account_id, date, provider,
+			input_tokens, output_tokens, total_tokens,
+			cost_cents, request_count, record_count, updated_at
+		)
+		SELECT
+			account_id,
+			date(recorded_at),
+			provider,
+			COALESCE(SUM(input_tokens), 0),
+			COALESCE(SUM(output_tokens), 0),
+			COALESCE(SUM(total_tokens), 0),
+			COALESCE(SUM(cost_cents), 0),
+			COALESCE(SUM(request_count), 0),
+			COUNT(*),
+			datetime('now')
+		FROM usage_records
+		WHERE account_id = ? AND date(recorded_at) = ? AND provider = ?
+		GROUP BY account_id, date(recorded_at), provider
+	`, accountID, date, string(provider))
+	if err != nil {
+		return fmt.Errorf("failed to update daily cache: %w", err)
+	}
+	return nil
+}
+
+func reportUnknownModels(ctx context.Context, tx *sql.Tx, report *ProviderNormalizationReport) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT provider, model, COUNT(*) FROM usage_records
+		WHERE model IS NOT NULL AND model != ''
+		GROUP BY provider, model
+		ORDER BY provider, model
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query usage models: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var count ProviderModelCount
+		var provider string
+		if err := rows.Scan(&provider, &count.Model, &count.Rows); err != nil {
+			return fmt.Errorf("failed to scan usage model: %w", err)
+		}
+		count.Provider = models.Provider(provider)
+		if !models.IsKnownModel(count.Provider, count.Model) {
+			report.UnknownModels = append(report.UnknownModels, count)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating usage models: %w", err)
+	}
+	return nil
+}
+
+func sortedNameCounts(table string, counts map[string]int64) []ProviderNameCount {
+	names := make([]ProviderNameCount, 0, len(counts))
+	for name, rows := range counts {
+		names = append(names, ProviderNameCount{Table: table, Name: name, Rows: rows})
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i].Name < names[j].Name })
+	return names
+}
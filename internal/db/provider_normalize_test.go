@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestProviderWriteBoundaries(t *testing.T) {
+	ctx := context.Background()
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	accounts := NewAccountRepository(database)
+	usage := NewUsageRepository(database)
+
+	account := &models.Account{Provider: " Anthropic ", ProfileName: "work"}
+	if err := accounts.Create(ctx, account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	if account.Provider != models.ProviderAnthropic {
+		t.Fatalf("expected the provider normalized, got %q", account.Provider)
+	}
+	custom := &models.Account{Provider: models.ProviderCustom, ProviderName: " Mistral", ProfileName: "work"}
+	if err := accounts.Create(ctx, custom); err != nil {
+		t.Fatalf("create custom account: %v", err)
+	}
+	if got, err := accounts.Get(ctx, custom.ID); err != nil || got.ProviderName != "mistral" {
+		t.Fatalf("expected the custom provider name stored normalized, got %+v (%v)", got, err)
+	}
+
+	rejected := []*models.Account{
+		{Provider: "anthropoc", ProfileName: "typo"},
+		{Provider: "claude", ProfileName: "alias"},
+		{Provider: models.ProviderOpenAI, ProviderName: "azure", ProfileName: "named"},
+		{Provider: models.ProviderCustom, ProviderName: "Claude", ProfileName: "known"},
+	}
+	for _, a := range rejected {
+		if err := accounts.Create(ctx, a); err == nil {
+			t.Errorf("expected account %+v rejected", a)
+		}
+	}
+	account.Provider = "antropic"
+	if err := accounts.Update(ctx, account); !errors.Is(err, models.ErrUnknownProvider) {
+		t.Fatalf("expected the update rejected as an unknown provider, got %v", err)
+	}
+
+	if err := usage.Create(ctx, &models.UsageRecord{AccountID: custom.ID, Provider: "CUSTOM", ProviderName: "Mistral", Model: "mistral-large"}); err != nil {
+		t.Fatalf("create custom usage: %v", err)
+	}
+	err = usage.Create(ctx, &models.UsageRecord{AccountID: custom.ID, Provider: "anthropoc", InputTokens: 10})
+	if !errors.Is(err, ErrInvalidUsageRecord) || !errors.Is(err, models.ErrUnknownProvider) {
+		t.Fatalf("expected the typo rejected as an invalid usage record, got %v", err)
+	}
+	page, err := usage.Query(ctx, models.UsageQuery{AccountID: &custom.ID})
+	if err != nil || len(page) != 1 || page[0].Provider != models.ProviderCustom || page[0].ProviderName != "mistral" {
+		t.Fatalf("expected only the custom usage stored, got %+v (%v)", page, err)
+	}
+}
+
+func TestNormalizeProviders(t *testing.T) {
+	ctx := context.Background()
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// Rows written by hand or before provider names were checked.
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := database.ExecContext(ctx, query, args...); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	account := func(id, provider, name, profile string) {
+		exec(`INSERT INTO accounts (id, provider, provider_name, profile_name, credential_ref, is_active, created_at, updated_at)
+			VALUES (?, ?, ?, ?, '', 1, ?, ?)`, id, provider, nullString(name), profile, now, now)
+	}
+	account("acct-claude", "custom", "Claude", "team")
+	account("acct-openai", "custom", " OpenAI-API ", "ci")
+	account("acct-clash", "custom", "claude", "work")
+	account("acct-work", "anthropic", "", "work")
+	account("acct-mistral", "custom", "Mistral ", "eu")
+	usage := func(id, accountID, provider, name, model string, cost int) {
+		exec(`INSERT INTO usage_records (id, account_id, provider, provider_name, model, input_tokens, output_tokens, total_tokens, cost_cents, request_count, recorded_at)
+			VALUES (?, ?, ?, ?, ?, 10, 10, 20, ?, 1, ?)`, id, accountID, provider, nullString(name), model, cost, now)
+	}
+	usage("u1", "acct-claude", "custom", "Claude", "claude-sonnet-4", 100)
+	usage("u2", "acct-claude", "custom", "Claude", "claude-sonnet-4", 50)
+	usage("u3", "acct-openai", "custom", " OpenAI-API ", "gpt-4o", 30)
+	usage("u4", "acct-mistral", "custom", "Mistral ", "mistral-large", 5)
+	usage("u5", "acct-work", "anthropic", "", "claude-opus-4", 7)
+	usage("u6", "acct-work", "anthropic", "", "sonnet-next", 1)
+	usage("u7", "acct-openai", "openai", "", "text-embedding-3", 2)
+
+	usageRepo := NewUsageRepository(database)
+	if err := usageRepo.UpdateDailyCache(ctx, "acct-claude", "2026-10-01", models.ProviderCustom); err != nil {
+		t.Fatalf("update daily cache: %v", err)
+	}
+
+	dry, err := database.NormalizeProviders(ctx, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(dry.Changes) == 0 || !dry.DryRun {
+		t.Fatalf("expected the dry run to report changes, got %+v", dry)
+	}
+	if got, err := NewAccountRepository(database).Get(ctx, "acct-claude"); err != nil || got.Provider != models.ProviderCustom {
+		t.Fatalf("expected the dry run to leave accounts alone, got %+v (%v)", got, err)
+	}
+
+	report, err := database.NormalizeProviders(ctx, false)
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if len(report.Changes) != len(dry.Changes) {
+		t.Fatalf("expected the dry run to match the run, got %+v and %+v", dry.Changes, report.Changes)
+	}
+
+	accounts := NewAccountRepository(database)
+	wantAccounts := map[string][2]string{
+		"acct-claude":  {"anthropic", ""},
+		"acct-openai":  {"openai", ""},
+		"acct-clash":   {"custom", "claude"}, // anthropic/work exists
+		"acct-work":    {"anthropic", ""},
+		"acct-mistral": {"custom", "mistral"},
+	}
+	for id, want := range wantAccounts {
+		got, err := accounts.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("get %s: %v", id, err)
+		}
+		if string(got.Provider) != want[0] || got.ProviderName != want[1] {
+			t.Errorf("%s: got %s/%q, want %s/%q", id, got.Provider, got.ProviderName, want[0], want[1])
+		}
+	}
+	var clash *ProviderChange
+	for i, change := range report.Changes {
+		if change.Table == "accounts" && change.FromName == "claude" {
+			clash = &report.Changes[i]
+		}
+	}
+	if clash == nil || clash.Conflicts != 1 || clash.Rows != 0 {
+		t.Fatalf("expected the clashing account reported as a conflict, got %+v", report.Changes)
+	}
+
+	anthropic := models.ProviderAnthropic
+	records, err := usageRepo.Query(ctx, models.UsageQuery{Provider: &anthropic})
+	if err != nil || len(records) != 4 {
+		t.Fatalf("expected the Claude usage to aggregate with anthropic, got %d (%v)", len(records), err)
+	}
+	summary, err := usageRepo.SummarizeByProvider(ctx, models.ProviderAnthropic, nil, nil)
+	if err != nil || summary.TotalCostCents != 158 {
+		t.Fatalf("expected the anthropic cost to include the folded rows, got %+v (%v)", summary, err)
+	}
+	if got, err := usageRepo.Get(ctx, "u4"); err != nil || got.Provider != models.ProviderCustom || got.ProviderName != "mistral" {
+		t.Fatalf("expected the custom usage kept under its normalized name, got %+v (%v)", got, err)
+	}
+
+	cache, err := usageRepo.GetDailyCache(ctx, "2026-10-01")
+	if err != nil {
+		t.Fatalf("get daily cache: %v", err)
+	}
+	for _, day := range cache {
+		if day.AccountID == "acct-claude" && day.Provider == models.ProviderCustom {
+			t.Fatalf("expected the stale custom cache row dropped, got %+v", day)
+		}
+	}
+	var folded *models.DailyUsage
+	for _, day := range cache {
+		if day.AccountID == "acct-claude" && day.Provider == models.ProviderAnthropic {
+			folded = day
+		}
+	}
+	if folded == nil || folded.CostCents != 150 {
+		t.Fatalf("expected the cache recomputed under anthropic, got %+v", cache)
+	}
+
+	// The clashing account is reported as a conflict, not as a custom name.
+	wantNames := map[string]int64{"accounts/mistral": 1, "usage_records/mistral": 1}
+	if len(report.CustomNames) != len(wantNames) {
+		t.Fatalf("unexpected custom names %+v", report.CustomNames)
+	}
+	for _, name := range report.CustomNames {
+		if wantNames[name.Table+"/"+name.Name] != name.Rows {
+			t.Errorf("unexpected custom name %+v", name)
+		}
+	}
+	if len(report.UnknownModels) != 1 || report.UnknownModels[0].Model != "text-embedding-3" {
+		t.Fatalf("expected only the embedding model flagged, got %+v", report.UnknownModels)
+	}
+
+	again, err := database.NormalizeProviders(ctx, false)
+	if err != nil {
+		t.Fatalf("normalize again: %v", err)
+	}
+	if len(again.Changes) != 1 || again.Changes[0].Conflicts != 1 {
+		t.Fatalf("expected only the conflict left on a second run, got %+v", again.Changes)
+	}
+}
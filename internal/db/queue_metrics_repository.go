@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrQueueMetricsNotFound is returned when an agent has no saved metrics.
+var ErrQueueMetricsNotFound = errors.New("queue metrics not found")
+
+// QueueMetricsRepository handles persistence of per-agent queue metrics.
+type QueueMetricsRepository struct {
+	db *DB
+}
+
+// NewQueueMetricsRepository creates a new QueueMetricsRepository.
+func NewQueueMetricsRepository(db *DB) *QueueMetricsRepository {
+	return &QueueMetricsRepository{db: db}
+}
+
+// Upsert saves metrics, replacing any saved for the same agents. Metrics
+// for agents that no longer exist are skipped.
+func (r *QueueMetricsRepository) Upsert(ctx context.Context, metrics []*models.AgentQueueMetrics) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		for _, m := range metrics {
+			waits, err := json.Marshal(nonNilInts(m.WaitSamplesMs))
+			if err != nil {
+				return fmt.Errorf("failed to marshal wait samples: %w", err)
+			}
+			recent, err := json.Marshal(nonNilTimes(m.RecentDispatches))
+			if err != nil {
+				return fmt.Errorf("failed to marshal recent dispatches: %w", err)
+			}
+			updatedAt := m.UpdatedAt
+			if updatedAt.IsZero() {
+				updatedAt = time.Now().UTC()
+			}
+
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO agent_queue_metrics (
+					agent_id, dispatched, wait_samples_json, recent_dispatches_json, updated_at
+				)
+				SELECT ?, ?, ?, ?, ?
+				WHERE EXISTS (SELECT 1 FROM agents WHERE id = ?)
+				ON CONFLICT(agent_id) DO UPDATE SET
+					dispatched = excluded.dispatched,
+					wait_samples_json = excluded.wait_samples_json,
+					recent_dispatches_json = excluded.recent_dispatches_json,
+					updated_at = excluded.updated_at
+			`,
+				m.AgentID,
+				m.Dispatched,
+				string(waits),
+				string(recent),
+				updatedAt.UTC().Format(time.RFC3339),
+				m.AgentID,
+			); err != nil {
+				return fmt.Errorf("failed to save queue metrics for %s: %w", m.AgentID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Get retrieves the saved metrics of an agent.
+func (r *QueueMetricsRepository) Get(ctx context.Context, agentID string) (*models.AgentQueueMetrics, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT agent_id, dispatched, wait_samples_json, recent_dispatches_json, updated_at
+		FROM agent_queue_metrics WHERE agent_id = ?
+	`, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queue metrics: %w", err)
+	}
+	defer rows.Close()
+
+	metrics, err := scanQueueMetrics(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) == 0 {
+		return nil, ErrQueueMetricsNotFound
+	}
+	return metrics[0], nil
+}
+
+// List retrieves the saved metrics of every agent.
+func (r *QueueMetricsRepository) List(ctx context.Context) ([]*models.AgentQueueMetrics, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT agent_id, dispatched, wait_samples_json, recent_dispatches_json, updated_at
+		FROM agent_queue_metrics ORDER BY agent_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queue metrics: %w", err)
+	}
+	defer rows.Close()
+
+	return scanQueueMetrics(rows)
+}
+
+func scanQueueMetrics(rows *sql.Rows) ([]*models.AgentQueueMetrics, error) {
+	var metrics []*models.AgentQueueMetrics
+	for rows.Next() {
+		var (
+			m                  models.AgentQueueMetrics
+			waits, recent, upd string
+		)
+		if err := rows.Scan(&m.AgentID, &m.Dispatched, &waits, &recent, &upd); err != nil {
+			return nil, fmt.Errorf("failed to scan queue metrics: %w", err)
+		}
+		if err := json.Unmarshal([]byte(waits), &m.WaitSamplesMs); err != nil {
+			return nil, fmt.Errorf("failed to parse wait samples: %w", err)
+		}
+		if err := json.Unmarshal([]byte(recent), &m.RecentDispatches); err != nil {
+			return nil, fmt.Errorf("failed to parse recent dispatches: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, upd); err == nil {
+			m.UpdatedAt = t
+		}
+		metrics = append(metrics, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate queue metrics: %w", err)
+	}
+	return metrics, nil
+}
+
+func nonNilInts(v []int64) []int64 {
+	if v == nil {
+		return []int64{}
+	}
+	return v
+}
+
+func nonNilTimes(v []time.Time) []time.Time {
+	if v == nil {
+		return []time.Time{}
+	}
+	return v
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestQueueMetricsRepository_RoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	repo := NewQueueMetricsRepository(db)
+
+	if _, err := repo.Get(ctx, agent.ID); !errors.Is(err, ErrQueueMetricsNotFound) {
+		t.Fatalf("expected ErrQueueMetricsNotFound, got %v", err)
+	}
+
+	dispatchedAt := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+	saved := &models.AgentQueueMetrics{
+		AgentID:          agent.ID,
+		Dispatched:       3,
+		WaitSamplesMs:    []int64{120, 80, 4000},
+		RecentDispatches: []time.Time{dispatchedAt, dispatchedAt.Add(time.Minute)},
+		UpdatedAt:        dispatchedAt.Add(2 * time.Minute),
+	}
+	gone := &models.AgentQueueMetrics{AgentID: "no-such-agent", Dispatched: 1}
+	if err := repo.Upsert(ctx, []*models.AgentQueueMetrics{saved, gone}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	got, err := repo.Get(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Dispatched != 3 || len(got.WaitSamplesMs) != 3 || got.WaitSamplesMs[2] != 4000 {
+		t.Fatalf("unexpected metrics %+v", got)
+	}
+	if len(got.RecentDispatches) != 2 || !got.RecentDispatches[1].Equal(dispatchedAt.Add(time.Minute)) {
+		t.Fatalf("unexpected recent dispatches %v", got.RecentDispatches)
+	}
+	if !got.UpdatedAt.Equal(saved.UpdatedAt) {
+		t.Fatalf("expected updated_at %s, got %s", saved.UpdatedAt, got.UpdatedAt)
+	}
+
+	saved.Dispatched = 4
+	saved.WaitSamplesMs = nil
+	if err := repo.Upsert(ctx, []*models.AgentQueueMetrics{saved}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	all, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 1 || all[0].Dispatched != 4 || len(all[0].WaitSamplesMs) != 0 {
+		t.Fatalf("expected one updated row, got %+v", all)
+	}
+
+	if err := NewAgentRepository(db).HardDelete(ctx, agent.ID); err != nil {
+		t.Fatalf("delete agent: %v", err)
+	}
+	if all, err := repo.List(ctx); err != nil || len(all) != 0 {
+		t.Fatalf("expected metrics to be removed with the agent, got %d rows (%v)", len(all), err)
+	}
+}
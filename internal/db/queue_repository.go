@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/trace"
 )
 
 // Queue repository errors.
@@ -29,86 +30,130 @@ func NewQueueRepository(db *DB) *QueueRepository {
 	return &QueueRepository{db: db}
 }
 
-// Enqueue adds one or more items to an agent's queue.
+// Enqueue adds one or more items to the end of an agent's queue, as one
+// batch.
 func (r *QueueRepository) Enqueue(ctx context.Context, agentID string, items ...*models.QueueItem) error {
+	return r.EnqueueBatch(ctx, agentID, false, items...)
+}
+
+// EnqueueBatch adds items to an agent's queue in one transaction, at
+// contiguous positions in the order given. Every item is validated before
+// anything is written, and either all of them are queued or none are, so a
+// concurrent Dequeue never sees part of a batch.
+//
+// With atHead the batch goes before the agent's waiting items, right after
+// whatever is already dispatched; otherwise it goes at the end.
+func (r *QueueRepository) EnqueueBatch(ctx context.Context, agentID string, atHead bool, items ...*models.QueueItem) error {
 	if len(items) == 0 {
 		return nil
 	}
-
-	// Get the current max position for this agent
-	maxPos, err := r.getMaxPosition(ctx, agentID)
-	if err != nil {
-		return err
-	}
-
-	now := time.Now().UTC()
-
 	for i, item := range items {
 		if err := item.Validate(); err != nil {
 			return fmt.Errorf("invalid queue item at index %d: %w", i, err)
 		}
+	}
 
-		if item.ID == "" {
-			item.ID = uuid.New().String()
+	return r.db.TransactionWithRetry(ctx, 0, 0, func(tx *sql.Tx) error {
+		// Take the write lock before reading positions. A transaction that
+		// reads first and then writes cannot wait out another writer, so
+		// SQLite fails it with SQLITE_BUSY instead of applying the busy
+		// timeout.
+		if _, err := tx.ExecContext(ctx, `UPDATE queue_items SET position = position WHERE 0`); err != nil {
+			return fmt.Errorf("failed to lock queue: %w", err)
 		}
 
-		item.AgentID = agentID
-		item.CreatedAt = now
-		item.Position = maxPos + i + 1
+		first, err := batchStartPosition(ctx, tx, agentID, atHead)
+		if err != nil {
+			return err
+		}
+		if atHead {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE queue_items
+				SET position = position + ?
+				WHERE agent_id = ? AND position >= ?
+			`, len(items), agentID, first); err != nil {
+				return fmt.Errorf("failed to shift items: %w", err)
+			}
+		}
 
-		if item.Status == "" {
-			item.Status = models.QueueItemStatusPending
+		now := time.Now().UTC()
+		for i, item := range items {
+			if item.ID == "" {
+				item.ID = uuid.New().String()
+			}
+			item.AgentID = agentID
+			item.CreatedAt = now
+			item.Position = first + i
+			if item.Status == "" {
+				item.Status = models.QueueItemStatusPending
+			}
+			if err := writeQueueItem(ctx, tx, item); err != nil {
+				return err
+			}
 		}
-		_, err := r.db.ExecContext(ctx, `
-			INSERT INTO queue_items (
-				id, agent_id, type, position, status, attempts, payload_json,
-				error_message, created_at, dispatched_at, completed_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`,
-			item.ID,
-			item.AgentID,
-			string(item.Type),
-			item.Position,
-			string(item.Status),
-			item.Attempts,
-			string(item.Payload),
-			item.Error,
-			item.CreatedAt.Format(time.RFC3339),
-			stringTimePtr(item.DispatchedAt),
-			stringTimePtr(item.CompletedAt),
-		)
+		return nil
+	})
+}
 
-		if err != nil {
-			return fmt.Errorf("failed to insert queue item: %w", err)
+// batchStartPosition returns the position a batch starts at: the first
+// waiting item's position for the head, or one past the last item.
+func batchStartPosition(ctx context.Context, tx *sql.Tx, agentID string, atHead bool) (int, error) {
+	if atHead {
+		var head sql.NullInt64
+		if err := tx.QueryRowContext(ctx, `
+			SELECT MIN(position) FROM queue_items
+			WHERE agent_id = ? AND status = ?
+		`, agentID, string(models.QueueItemStatusPending)).Scan(&head); err != nil {
+			return 0, fmt.Errorf("failed to get queue head: %w", err)
+		}
+		if head.Valid {
+			return int(head.Int64), nil
 		}
 	}
 
-	return nil
+	var maxPos sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT MAX(position) FROM queue_items WHERE agent_id = ?
+	`, agentID).Scan(&maxPos); err != nil {
+		return 0, fmt.Errorf("failed to get max position: %w", err)
+	}
+	return int(maxPos.Int64) + 1, nil
 }
 
-// Dequeue removes and returns the next pending item from the queue.
-func (r *QueueRepository) Dequeue(ctx context.Context, agentID string) (*models.QueueItem, error) {
-	item, err := r.Peek(ctx, agentID)
-	if err != nil {
-		return nil, err
-	}
+// Dequeue claims the next pending item for dispatcherID, marking it in
+// flight, and returns it. The dispatcher moves it on with ReleaseInFlight
+// once the send was attempted. The item is claimed with a conditional
+// update, so concurrent dequeues never return the same item: one that loses
+// the race moves on to the next.
+func (r *QueueRepository) Dequeue(ctx context.Context, agentID, dispatcherID string) (*models.QueueItem, error) {
+	for {
+		item, err := r.Peek(ctx, agentID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Update the item status to dispatched
-	now := time.Now().UTC()
-	_, err = r.db.ExecContext(ctx, `
-		UPDATE queue_items 
-		SET status = ?, dispatched_at = ?
-		WHERE id = ?
-	`, string(models.QueueItemStatusDispatched), now.Format(time.RFC3339), item.ID)
+		now := time.Now().UTC()
+		result, err := r.db.ExecContext(ctx, `
+			UPDATE queue_items
+			SET status = ?, dispatched_at = ?, dispatcher_id = ?
+			WHERE id = ? AND status = ?
+		`, string(models.QueueItemStatusInFlight), now.Format(time.RFC3339), nullString(dispatcherID), item.ID, string(models.QueueItemStatusPending))
+		if err != nil {
+			return nil, fmt.Errorf("failed to update queue item status: %w", err)
+		}
+		claimed, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if claimed == 0 {
+			continue
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to update queue item status: %w", err)
+		item.Status = models.QueueItemStatusInFlight
+		item.DispatchedAt = &now
+		item.DispatcherID = dispatcherID
+		return item, nil
 	}
-
-	item.Status = models.QueueItemStatusDispatched
-	item.DispatchedAt = &now
-
-	return item, nil
 }
 
 // Peek returns the next pending item without removing it.
@@ -116,7 +161,8 @@ func (r *QueueRepository) Peek(ctx context.Context, agentID string) (*models.Que
 	row := r.db.QueryRowContext(ctx, `
 		SELECT 
 			id, agent_id, type, position, status, attempts, payload_json,
-			error_message, created_at, dispatched_at, completed_at
+			error_message, created_at, dispatched_at, completed_at, task_id, trace_id,
+			ignore_quiet_hours, dispatcher_id
 		FROM queue_items
 		WHERE agent_id = ? AND status = ?
 		ORDER BY position ASC
@@ -139,7 +185,8 @@ func (r *QueueRepository) List(ctx context.Context, agentID string) ([]*models.Q
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT 
 			id, agent_id, type, position, status, attempts, payload_json,
-			error_message, created_at, dispatched_at, completed_at
+			error_message, created_at, dispatched_at, completed_at, task_id, trace_id,
+			ignore_quiet_hours, dispatcher_id
 		FROM queue_items
 		WHERE agent_id = ?
 		ORDER BY position ASC
@@ -158,7 +205,8 @@ func (r *QueueRepository) ListPending(ctx context.Context, agentID string) ([]*m
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT 
 			id, agent_id, type, position, status, attempts, payload_json,
-			error_message, created_at, dispatched_at, completed_at
+			error_message, created_at, dispatched_at, completed_at, task_id, trace_id,
+			ignore_quiet_hours, dispatcher_id
 		FROM queue_items
 		WHERE agent_id = ? AND status = ?
 		ORDER BY position ASC
@@ -172,6 +220,47 @@ func (r *QueueRepository) ListPending(ctx context.Context, agentID string) ([]*m
 	return r.scanQueueItems(rows)
 }
 
+// ListByTask returns the queue items belonging to a task in queue order.
+func (r *QueueRepository) ListByTask(ctx context.Context, taskID string) ([]*models.QueueItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			id, agent_id, type, position, status, attempts, payload_json,
+			error_message, created_at, dispatched_at, completed_at, task_id, trace_id,
+			ignore_quiet_hours, dispatcher_id
+		FROM queue_items
+		WHERE task_id = ?
+		ORDER BY position ASC
+	`, taskID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task queue items: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanQueueItems(rows)
+}
+
+// SkipPendingByTask marks a task's pending items as skipped with reason so
+// they are never dispatched. Returns the number of items skipped.
+func (r *QueueRepository) SkipPendingByTask(ctx context.Context, taskID, reason string) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE queue_items
+		SET status = ?, error_message = ?
+		WHERE task_id = ? AND status = ?
+	`, string(models.QueueItemStatusSkipped), reason, taskID, string(models.QueueItemStatusPending))
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to skip task queue items: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rows), nil
+}
+
 // Reorder updates the position of items in the queue.
 func (r *QueueRepository) Reorder(ctx context.Context, agentID string, itemIDs []string) error {
 	if len(itemIDs) == 0 {
@@ -290,27 +379,8 @@ func (r *QueueRepository) InsertAt(ctx context.Context, agentID string, position
 		item.Status = models.QueueItemStatusPending
 	}
 
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO queue_items (
-			id, agent_id, type, position, status, attempts, payload_json,
-			error_message, created_at, dispatched_at, completed_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`,
-		item.ID,
-		item.AgentID,
-		string(item.Type),
-		item.Position,
-		string(item.Status),
-		item.Attempts,
-		string(item.Payload),
-		item.Error,
-		item.CreatedAt.Format(time.RFC3339),
-		stringTimePtr(item.DispatchedAt),
-		stringTimePtr(item.CompletedAt),
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to insert queue item: %w", err)
+	if err := writeQueueItem(ctx, tx, item); err != nil {
+		return err
 	}
 
 	return tx.Commit()
@@ -340,7 +410,8 @@ func (r *QueueRepository) Get(ctx context.Context, id string) (*models.QueueItem
 	row := r.db.QueryRowContext(ctx, `
 		SELECT 
 			id, agent_id, type, position, status, attempts, payload_json,
-			error_message, created_at, dispatched_at, completed_at
+			error_message, created_at, dispatched_at, completed_at, task_id, trace_id,
+			ignore_quiet_hours, dispatcher_id
 		FROM queue_items WHERE id = ?
 	`, id)
 
@@ -379,6 +450,54 @@ func (r *QueueRepository) UpdateStatus(ctx context.Context, id string, status mo
 	return nil
 }
 
+// ReleaseInFlight moves an in-flight item to status with the given attempt
+// count and error. It reports false, changing nothing, when the item is not
+// in flight, so a dispatcher finishing late cannot undo a recovery and a
+// recovery cannot undo a finished dispatch.
+func (r *QueueRepository) ReleaseInFlight(ctx context.Context, id string, status models.QueueItemStatus, attempts int, errorMsg string) (bool, error) {
+	var completedAt *string
+	if status == models.QueueItemStatusCompleted || status == models.QueueItemStatusFailed {
+		s := time.Now().UTC().Format(time.RFC3339)
+		completedAt = &s
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE queue_items
+		SET status = ?, attempts = ?, error_message = ?, completed_at = ?
+		WHERE id = ? AND status = ?
+	`, string(status), attempts, errorMsg, completedAt, id, string(models.QueueItemStatusInFlight))
+	if err != nil {
+		return false, fmt.Errorf("failed to release queue item: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ListInFlight returns the items of every agent claimed before cutoff and
+// still in flight, oldest claim first.
+func (r *QueueRepository) ListInFlight(ctx context.Context, cutoff time.Time) ([]*models.QueueItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			id, agent_id, type, position, status, attempts, payload_json,
+			error_message, created_at, dispatched_at, completed_at, task_id, trace_id,
+			ignore_quiet_hours, dispatcher_id
+		FROM queue_items
+		WHERE status = ? AND dispatched_at < ?
+		ORDER BY dispatched_at ASC
+	`, string(models.QueueItemStatusInFlight), cutoff.UTC().Format(time.RFC3339))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query in-flight queue items: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanQueueItems(rows)
+}
+
 // UpdateAttempts updates the dispatch attempt count for a queue item.
 func (r *QueueRepository) UpdateAttempts(ctx context.Context, id string, attempts int) error {
 	if attempts < 0 {
@@ -421,22 +540,65 @@ func (r *QueueRepository) Count(ctx context.Context, agentID string) (int, error
 	return count, nil
 }
 
-// getMaxPosition returns the current maximum position for an agent's queue.
-func (r *QueueRepository) getMaxPosition(ctx context.Context, agentID string) (int, error) {
-	var maxPos sql.NullInt64
+// AgentDraining reports whether an agent is draining its queue. An unknown
+// agent is not draining.
+func (r *QueueRepository) AgentDraining(ctx context.Context, agentID string) (bool, error) {
+	var draining bool
 	err := r.db.QueryRowContext(ctx, `
-		SELECT MAX(position) FROM queue_items WHERE agent_id = ?
-	`, agentID).Scan(&maxPos)
-
+		SELECT draining FROM agents WHERE id = ?
+	`, agentID).Scan(&draining)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
 	if err != nil {
-		return 0, fmt.Errorf("failed to get max position: %w", err)
+		return false, fmt.Errorf("failed to read agent draining: %w", err)
 	}
 
-	if !maxPos.Valid {
-		return 0, nil
+	return draining, nil
+}
+
+// execer is implemented by both *DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// writeQueueItem inserts a fully populated queue item.
+func writeQueueItem(ctx context.Context, exec execer, item *models.QueueItem) error {
+	var taskID *string
+	if item.TaskID != "" {
+		taskID = &item.TaskID
+	}
+	if item.TraceID == "" {
+		item.TraceID = trace.FromContext(ctx)
 	}
 
-	return int(maxPos.Int64), nil
+	_, err := exec.ExecContext(ctx, `
+		INSERT INTO queue_items (
+			id, agent_id, type, position, status, attempts, payload_json,
+			error_message, created_at, dispatched_at, completed_at, task_id, trace_id,
+			ignore_quiet_hours, dispatcher_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		item.ID,
+		item.AgentID,
+		string(item.Type),
+		item.Position,
+		string(item.Status),
+		item.Attempts,
+		string(item.Payload),
+		item.Error,
+		item.CreatedAt.Format(time.RFC3339),
+		stringTimePtr(item.DispatchedAt),
+		stringTimePtr(item.CompletedAt),
+		taskID,
+		nullString(item.TraceID),
+		boolToInt(item.IgnoreQuietHours),
+		nullString(item.DispatcherID),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert queue item: %w", err)
+	}
+	return nil
 }
 
 // scanQueueItem scans a single queue item from a row.
@@ -447,7 +609,7 @@ func (r *QueueRepository) scanQueueItem(row *sql.Row) (*models.QueueItem, error)
 	var payloadJSON string
 	var errorMsg sql.NullString
 	var createdAt string
-	var dispatchedAt, completedAt sql.NullString
+	var dispatchedAt, completedAt, taskID, traceID, dispatcherID sql.NullString
 
 	err := row.Scan(
 		&item.ID,
@@ -461,6 +623,10 @@ func (r *QueueRepository) scanQueueItem(row *sql.Row) (*models.QueueItem, error)
 		&createdAt,
 		&dispatchedAt,
 		&completedAt,
+		&taskID,
+		&traceID,
+		&item.IgnoreQuietHours,
+		&dispatcherID,
 	)
 
 	if err != nil {
@@ -478,6 +644,11 @@ func (r *QueueRepository) scanQueueItem(row *sql.Row) (*models.QueueItem, error)
 	if errorMsg.Valid {
 		item.Error = errorMsg.String
 	}
+	if taskID.Valid {
+		item.TaskID = taskID.String
+	}
+	item.TraceID = traceID.String
+	item.DispatcherID = dispatcherID.String
 
 	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
 		item.CreatedAt = t
@@ -507,7 +678,7 @@ func (r *QueueRepository) scanQueueItems(rows *sql.Rows) ([]*models.QueueItem, e
 		var payloadJSON string
 		var errorMsg sql.NullString
 		var createdAt string
-		var dispatchedAt, completedAt sql.NullString
+		var dispatchedAt, completedAt, taskID, traceID, dispatcherID sql.NullString
 
 		err := rows.Scan(
 			&item.ID,
@@ -521,6 +692,10 @@ func (r *QueueRepository) scanQueueItems(rows *sql.Rows) ([]*models.QueueItem, e
 			&createdAt,
 			&dispatchedAt,
 			&completedAt,
+			&taskID,
+			&traceID,
+			&item.IgnoreQuietHours,
+			&dispatcherID,
 		)
 
 		if err != nil {
@@ -535,6 +710,11 @@ func (r *QueueRepository) scanQueueItems(rows *sql.Rows) ([]*models.QueueItem, e
 		if errorMsg.Valid {
 			item.Error = errorMsg.String
 		}
+		if taskID.Valid {
+			item.TaskID = taskID.String
+		}
+		item.TraceID = traceID.String
+		item.DispatcherID = dispatcherID.String
 
 		if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
 			item.CreatedAt = t
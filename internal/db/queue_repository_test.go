@@ -3,10 +3,16 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/trace"
 )
 
 func createTestAgent(t *testing.T, db *DB, ws *models.Workspace) *models.Agent {
@@ -53,6 +59,7 @@ func TestQueueRepository_EnqueuePeekDequeue(t *testing.T) {
 
 	item1 := newMessageItem(t, "first")
 	item2 := newMessageItem(t, "second")
+	item2.IgnoreQuietHours = true
 
 	if err := repo.Enqueue(ctx, agent.ID, item1, item2); err != nil {
 		t.Fatalf("Enqueue failed: %v", err)
@@ -85,12 +92,12 @@ func TestQueueRepository_EnqueuePeekDequeue(t *testing.T) {
 		t.Fatalf("expected first payload, got %q", payload.Text)
 	}
 
-	dequeued, err := repo.Dequeue(ctx, agent.ID)
+	dequeued, err := repo.Dequeue(ctx, agent.ID, "test")
 	if err != nil {
 		t.Fatalf("Dequeue failed: %v", err)
 	}
-	if dequeued.Status != models.QueueItemStatusDispatched {
-		t.Fatalf("expected dispatched status, got %q", dequeued.Status)
+	if dequeued.Status != models.QueueItemStatusInFlight {
+		t.Fatalf("expected in_flight status, got %q", dequeued.Status)
 	}
 	if dequeued.DispatchedAt == nil {
 		t.Fatalf("expected dispatched_at set")
@@ -103,6 +110,13 @@ func TestQueueRepository_EnqueuePeekDequeue(t *testing.T) {
 	if count != 1 {
 		t.Fatalf("expected 1 pending item, got %d", count)
 	}
+
+	if peeked.IgnoreQuietHours {
+		t.Fatal("expected the first item to keep quiet hours")
+	}
+	if next, err := repo.Peek(ctx, agent.ID); err != nil || !next.IgnoreQuietHours {
+		t.Fatalf("expected the second item to ignore quiet hours, got %+v (%v)", next, err)
+	}
 }
 
 func TestQueueRepository_Reorder(t *testing.T) {
@@ -217,6 +231,183 @@ func TestQueueRepository_InsertAt(t *testing.T) {
 	}
 }
 
+func TestQueueRepository_EnqueueBatchAtHead(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	repo := NewQueueRepository(db)
+	ctx := context.Background()
+
+	inFlight := newMessageItem(t, "in flight")
+	waiting := newMessageItem(t, "waiting")
+	if err := repo.Enqueue(ctx, agent.ID, inFlight, waiting); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if _, err := repo.Dequeue(ctx, agent.ID, "test"); err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+
+	first := newMessageItem(t, "batch 1")
+	second := newMessageItem(t, "batch 2")
+	if err := repo.EnqueueBatch(ctx, agent.ID, true, first, second); err != nil {
+		t.Fatalf("EnqueueBatch failed: %v", err)
+	}
+
+	items, err := repo.List(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	want := []string{inFlight.ID, first.ID, second.ID, waiting.ID}
+	if len(items) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(items))
+	}
+	for i, item := range items {
+		if item.ID != want[i] || item.Position != i+1 {
+			t.Fatalf("item %d: got %s at position %d, want %s at %d", i, item.ID, item.Position, want[i], i+1)
+		}
+	}
+
+	next, err := repo.Peek(ctx, agent.ID)
+	if err != nil || next.ID != first.ID {
+		t.Fatalf("expected the batch next, got %v (%v)", next, err)
+	}
+}
+
+func TestQueueRepository_EnqueueBatchAllOrNothing(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	repo := NewQueueRepository(db)
+	ctx := context.Background()
+
+	invalid := &models.QueueItem{Type: models.QueueItemTypePause, Payload: json.RawMessage(`{"duration_seconds":0}`)}
+	err := repo.EnqueueBatch(ctx, agent.ID, false, newMessageItem(t, "one"), invalid, newMessageItem(t, "three"))
+	if err == nil || !strings.Contains(err.Error(), "index 1") {
+		t.Fatalf("expected the invalid item reported, got %v", err)
+	}
+
+	count, err := repo.Count(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected nothing queued from a failed batch, got %d items", count)
+	}
+}
+
+func TestQueueRepository_EnqueueBatchConcurrentDispatch(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(Config{Path: filepath.Join(t.TempDir(), "swarm.db"), MaxOpenConns: 4, BusyTimeoutMs: 5000})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	repo := NewQueueRepository(db)
+
+	const (
+		batches   = 20
+		batchSize = 3
+	)
+	batchOf := make(map[string]int, batches*batchSize)
+	var batchItems [batches][]*models.QueueItem
+	for b := range batchItems {
+		for i := 0; i < batchSize; i++ {
+			item := newMessageItem(t, fmt.Sprintf("batch %d step %d", b, i))
+			batchOf[item.ID] = b
+			batchItems[b] = append(batchItems[b], item)
+		}
+	}
+
+	done := make(chan struct{})
+	observed := make(chan error, 1)
+	go func() {
+		// Stands in for the scheduler: dispatch whatever is queued and
+		// check that every batch is all there or not there at all.
+		defer close(observed)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			_, _ = repo.Dequeue(ctx, agent.ID, "test")
+			items, err := repo.List(ctx, agent.ID)
+			if err != nil {
+				observed <- err
+				return
+			}
+			seen := make(map[int]int)
+			for _, item := range items {
+				seen[batchOf[item.ID]]++
+			}
+			for b, n := range seen {
+				if n != batchSize {
+					observed <- fmt.Errorf("observed %d of %d items of batch %d", n, batchSize, b)
+					return
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for b := range batchItems {
+		wg.Add(1)
+		go func(b int) {
+			defer wg.Done()
+			if err := repo.EnqueueBatch(ctx, agent.ID, b%2 == 1, batchItems[b]...); err != nil {
+				t.Errorf("EnqueueBatch %d: %v", b, err)
+			}
+		}(b)
+	}
+	wg.Wait()
+	close(done)
+	if err := <-observed; err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := repo.List(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(items) != batches*batchSize {
+		t.Fatalf("expected %d items, got %d", batches*batchSize, len(items))
+	}
+	// Each batch keeps its order. A head batch may land between the
+	// dispatched and waiting items of a batch already being worked through,
+	// but the waiting items of a batch are never split.
+	next := make(map[int]int, batches)
+	var pending []int
+	for _, item := range items {
+		b := batchOf[item.ID]
+		if item.ID != batchItems[b][next[b]].ID {
+			t.Fatalf("batch %d is out of order at position %d", b, item.Position)
+		}
+		next[b]++
+		if item.Status == models.QueueItemStatusPending {
+			pending = append(pending, b)
+		}
+	}
+	finished := make(map[int]bool, batches)
+	for i, b := range pending {
+		if finished[b] {
+			t.Fatalf("waiting items of batch %d are split", b)
+		}
+		if i+1 < len(pending) && pending[i+1] != b {
+			finished[b] = true
+		}
+	}
+}
+
 func TestQueueRepository_ClearUpdateStatusAndRemove(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -295,3 +486,108 @@ func TestQueueRepository_UpdateAttempts(t *testing.T) {
 		t.Fatalf("expected attempts 2, got %d", updated.Attempts)
 	}
 }
+
+func TestQueueRepository_TraceID(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	repo := NewQueueRepository(db)
+	ctx := trace.WithID(context.Background(), "run-1")
+
+	if err := repo.Enqueue(ctx, agent.ID, newMessageItem(t, "first")); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := repo.Enqueue(context.Background(), agent.ID, newMessageItem(t, "second")); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	first, err := repo.Dequeue(context.Background(), agent.ID, "test")
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if first.TraceID != "run-1" {
+		t.Fatalf("expected the enqueuing trace on the item, got %q", first.TraceID)
+	}
+	second, err := repo.Dequeue(context.Background(), agent.ID, "test")
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if second.TraceID != "" {
+		t.Fatalf("expected no trace on an untraced item, got %q", second.TraceID)
+	}
+}
+
+func TestQueueRepository_InFlight(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	repo := NewQueueRepository(db)
+	ctx := context.Background()
+
+	first := newMessageItem(t, "first")
+	second := newMessageItem(t, "second")
+	if err := repo.Enqueue(ctx, agent.ID, first, second); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	claimed, err := repo.Dequeue(ctx, agent.ID, "host/1")
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if claimed.ID != first.ID || claimed.Status != models.QueueItemStatusInFlight || claimed.DispatcherID != "host/1" || claimed.DispatchedAt == nil {
+		t.Fatalf("expected the first item claimed in flight, got %+v", claimed)
+	}
+
+	// An in-flight item is neither pending nor dispatchable again.
+	if count, err := repo.Count(ctx, agent.ID); err != nil || count != 1 {
+		t.Fatalf("expected 1 pending item, got %d (%v)", count, err)
+	}
+	if peeked, err := repo.Peek(ctx, agent.ID); err != nil || peeked.ID != second.ID {
+		t.Fatalf("expected Peek to skip the in-flight item, got %+v (%v)", peeked, err)
+	}
+	items, err := repo.List(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(items) != 2 || items[0].Status != models.QueueItemStatusInFlight {
+		t.Fatalf("expected List to include the in-flight item, got %+v", items)
+	}
+
+	since := *claimed.DispatchedAt
+	if stuck, err := repo.ListInFlight(ctx, since); err != nil || len(stuck) != 0 {
+		t.Fatalf("expected nothing in flight before the claim, got %d (%v)", len(stuck), err)
+	}
+	stuck, err := repo.ListInFlight(ctx, since.Add(time.Second))
+	if err != nil {
+		t.Fatalf("ListInFlight failed: %v", err)
+	}
+	if len(stuck) != 1 || stuck[0].ID != first.ID || stuck[0].DispatcherID != "host/1" {
+		t.Fatalf("expected the claimed item in flight, got %+v", stuck)
+	}
+
+	released, err := repo.ReleaseInFlight(ctx, first.ID, models.QueueItemStatusDispatched, 0, "")
+	if err != nil || !released {
+		t.Fatalf("expected the item released, got %v (%v)", released, err)
+	}
+	got, err := repo.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != models.QueueItemStatusDispatched {
+		t.Fatalf("expected the item dispatched, got %q", got.Status)
+	}
+
+	// Only an item still in flight is released, so a late recovery does not
+	// undo the dispatch.
+	released, err = repo.ReleaseInFlight(ctx, first.ID, models.QueueItemStatusPending, 1, "timed out")
+	if err != nil || released {
+		t.Fatalf("expected a dispatched item left alone, got %v (%v)", released, err)
+	}
+	if stuck, err := repo.ListInFlight(ctx, since.Add(time.Hour)); err != nil || len(stuck) != 0 {
+		t.Fatalf("expected nothing left in flight, got %d (%v)", len(stuck), err)
+	}
+}
@@ -0,0 +1,293 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Review repository errors.
+var (
+	ErrReviewNotFound      = errors.New("review not found")
+	ErrReviewAlreadyExists = errors.New("review already exists for queue item")
+)
+
+const reviewColumns = `
+	id, queue_item_id, agent_id, range_spec, patch_file, status, verdict,
+	summary, comments_json, raw_output, error_message, diff_files,
+	diff_bytes, truncated, created_at, dispatched_at, completed_at`
+
+// ReviewRepository handles review persistence.
+type ReviewRepository struct {
+	db *DB
+}
+
+// NewReviewRepository creates a new ReviewRepository.
+func NewReviewRepository(db *DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Create adds a new review to the database.
+func (r *ReviewRepository) Create(ctx context.Context, review *models.Review) error {
+	if review.QueueItemID == "" {
+		return fmt.Errorf("review queue item id is required")
+	}
+	if review.AgentID == "" {
+		return fmt.Errorf("review agent id is required")
+	}
+
+	if review.ID == "" {
+		review.ID = uuid.New().String()
+	}
+	if review.Status == "" {
+		review.Status = models.ReviewStatusPending
+	}
+	review.CreatedAt = time.Now().UTC()
+
+	comments, err := marshalReviewComments(review.Comments)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO reviews (`+reviewColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		review.ID,
+		review.QueueItemID,
+		review.AgentID,
+		review.Range,
+		review.PatchFile,
+		string(review.Status),
+		string(review.Verdict),
+		review.Summary,
+		comments,
+		review.RawOutput,
+		review.Error,
+		review.DiffFiles,
+		review.DiffBytes,
+		boolToInt(review.Truncated),
+		review.CreatedAt.Format(time.RFC3339),
+		stringTimePtr(review.DispatchedAt),
+		stringTimePtr(review.CompletedAt),
+	)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return ErrReviewAlreadyExists
+		}
+		return fmt.Errorf("failed to insert review: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves a review by ID.
+func (r *ReviewRepository) Get(ctx context.Context, id string) (*models.Review, error) {
+	return r.getOne(ctx, "id = ?", id)
+}
+
+// GetByQueueItem retrieves the review for a review queue item.
+func (r *ReviewRepository) GetByQueueItem(ctx context.Context, queueItemID string) (*models.Review, error) {
+	return r.getOne(ctx, "queue_item_id = ?", queueItemID)
+}
+
+// FindAwaitingVerdict returns the most recently sent review that agentID
+// has not answered yet.
+func (r *ReviewRepository) FindAwaitingVerdict(ctx context.Context, agentID string) (*models.Review, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews
+		WHERE agent_id = ? AND status = 'awaiting_verdict'
+		ORDER BY dispatched_at DESC
+		LIMIT 1
+	`, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews, err := r.scanReviews(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, ErrReviewNotFound
+	}
+	return reviews[0], nil
+}
+
+// List lists reviews oldest first, optionally for a single agent. An empty
+// agentID returns every review.
+func (r *ReviewRepository) List(ctx context.Context, agentID string) ([]*models.Review, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews
+		WHERE ? = '' OR agent_id = ?
+		ORDER BY created_at
+	`, agentID, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanReviews(rows)
+}
+
+// Update saves the status, verdict, and diff details of a review.
+func (r *ReviewRepository) Update(ctx context.Context, review *models.Review) error {
+	comments, err := marshalReviewComments(review.Comments)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE reviews
+		SET status = ?, verdict = ?, summary = ?, comments_json = ?,
+			raw_output = ?, error_message = ?, diff_files = ?, diff_bytes = ?,
+			truncated = ?, dispatched_at = ?, completed_at = ?
+		WHERE id = ?
+	`,
+		string(review.Status),
+		string(review.Verdict),
+		review.Summary,
+		comments,
+		review.RawOutput,
+		review.Error,
+		review.DiffFiles,
+		review.DiffBytes,
+		boolToInt(review.Truncated),
+		stringTimePtr(review.DispatchedAt),
+		stringTimePtr(review.CompletedAt),
+		review.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update review: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrReviewNotFound
+	}
+	return nil
+}
+
+func (r *ReviewRepository) getOne(ctx context.Context, where string, arg string) (*models.Review, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews
+		WHERE `+where, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review: %w", err)
+	}
+	defer rows.Close()
+
+	reviews, err := r.scanReviews(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, ErrReviewNotFound
+	}
+	return reviews[0], nil
+}
+
+func (r *ReviewRepository) scanReviews(rows *sql.Rows) ([]*models.Review, error) {
+	var reviews []*models.Review
+	for rows.Next() {
+		review, err := r.scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+func (r *ReviewRepository) scanReview(rows *sql.Rows) (*models.Review, error) {
+	var review models.Review
+	var status, verdict, createdAt string
+	var comments, dispatchedAt, completedAt sql.NullString
+	var truncated int
+
+	if err := rows.Scan(
+		&review.ID,
+		&review.QueueItemID,
+		&review.AgentID,
+		&review.Range,
+		&review.PatchFile,
+		&status,
+		&verdict,
+		&review.Summary,
+		&comments,
+		&review.RawOutput,
+		&review.Error,
+		&review.DiffFiles,
+		&review.DiffBytes,
+		&truncated,
+		&createdAt,
+		&dispatchedAt,
+		&completedAt,
+	); err != nil {
+		return nil, fmt.Errorf("failed to scan review: %w", err)
+	}
+
+	review.Status = models.ReviewStatus(status)
+	review.Verdict = models.ReviewVerdict(verdict)
+	review.Truncated = truncated != 0
+
+	if comments.Valid && comments.String != "" {
+		if err := json.Unmarshal([]byte(comments.String), &review.Comments); err != nil {
+			return nil, fmt.Errorf("failed to decode review comments: %w", err)
+		}
+	}
+
+	created, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	review.CreatedAt = created
+
+	for _, field := range []struct {
+		value sql.NullString
+		dest  **time.Time
+		name  string
+	}{
+		{dispatchedAt, &review.DispatchedAt, "dispatched_at"},
+		{completedAt, &review.CompletedAt, "completed_at"},
+	} {
+		if !field.value.Valid || field.value.String == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, field.value.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", field.name, err)
+		}
+		*field.dest = &parsed
+	}
+
+	return &review, nil
+}
+
+func marshalReviewComments(comments []models.ReviewComment) (*string, error) {
+	if len(comments) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(comments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode review comments: %w", err)
+	}
+	encoded := string(data)
+	return &encoded, nil
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestReviewRepository_CreateFindUpdate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	agent := createTestAgent(t, db, createTestWorkspace(t, db))
+	repo := NewReviewRepository(db)
+
+	first := &models.Review{QueueItemID: "item-1", AgentID: agent.ID, Range: "main..HEAD"}
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if first.ID == "" || first.Status != models.ReviewStatusPending {
+		t.Fatalf("expected an ID and pending status, got %+v", first)
+	}
+	if err := repo.Create(ctx, &models.Review{QueueItemID: "item-1", AgentID: agent.ID, Range: "x"}); !errors.Is(err, ErrReviewAlreadyExists) {
+		t.Fatalf("expected ErrReviewAlreadyExists for a second review of an item, got %v", err)
+	}
+	if _, err := repo.FindAwaitingVerdict(ctx, agent.ID); !errors.Is(err, ErrReviewNotFound) {
+		t.Fatalf("expected no review awaiting a verdict, got %v", err)
+	}
+
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	first.Status = models.ReviewStatusAwaitingVerdict
+	first.DispatchedAt = &older
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	second := &models.Review{
+		QueueItemID:  "item-2",
+		AgentID:      agent.ID,
+		PatchFile:    "fix.patch",
+		Status:       models.ReviewStatusAwaitingVerdict,
+		DispatchedAt: &newer,
+	}
+	if err := repo.Create(ctx, second); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	awaiting, err := repo.FindAwaitingVerdict(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("FindAwaitingVerdict failed: %v", err)
+	}
+	if awaiting.ID != second.ID {
+		t.Fatalf("expected the latest dispatched review, got %s", awaiting.ID)
+	}
+
+	completed := newer.Add(time.Minute)
+	second.Status = models.ReviewStatusCompleted
+	second.Verdict = models.ReviewVerdictChanges
+	second.Summary = "needs a test"
+	second.Comments = []models.ReviewComment{{Path: "a.go", Line: 4, Body: "check err"}}
+	second.DiffFiles = 2
+	second.DiffBytes = 1234
+	second.Truncated = true
+	second.CompletedAt = &completed
+	if err := repo.Update(ctx, second); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	stored, err := repo.GetByQueueItem(ctx, "item-2")
+	if err != nil {
+		t.Fatalf("GetByQueueItem failed: %v", err)
+	}
+	if stored.Verdict != models.ReviewVerdictChanges || len(stored.Comments) != 1 || stored.Comments[0].Line != 4 {
+		t.Fatalf("unexpected stored review %+v", stored)
+	}
+	if stored.DiffFiles != 2 || stored.DiffBytes != 1234 || !stored.Truncated || stored.CompletedAt == nil || !stored.CompletedAt.Equal(completed) {
+		t.Fatalf("unexpected stored diff stats %+v", stored)
+	}
+
+	reviews, err := repo.List(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(reviews) != 2 || reviews[0].ID != first.ID {
+		t.Fatalf("expected both reviews oldest first, got %d", len(reviews))
+	}
+	if _, err := repo.Get(ctx, "missing"); !errors.Is(err, ErrReviewNotFound) {
+		t.Fatalf("expected ErrReviewNotFound, got %v", err)
+	}
+}
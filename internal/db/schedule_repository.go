@@ -0,0 +1,263 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrScheduleNotFound is returned when a schedule does not exist.
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// ScheduleRepository handles recurring schedule persistence.
+type ScheduleRepository struct {
+	db *DB
+}
+
+// NewScheduleRepository creates a new ScheduleRepository.
+func NewScheduleRepository(db *DB) *ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+const scheduleColumns = `
+	id, agent_id, workspace_id, cron_expr, message, template, enabled,
+	last_run_at, next_run_at, created_at, updated_at`
+
+// Create inserts a new schedule.
+func (r *ScheduleRepository) Create(ctx context.Context, schedule *models.Schedule) error {
+	if err := schedule.Validate(); err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	if schedule.ID == "" {
+		schedule.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO schedules (`+scheduleColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		schedule.ID,
+		nullString(schedule.AgentID),
+		nullString(schedule.WorkspaceID),
+		schedule.Cron,
+		schedule.Message,
+		nullString(schedule.Template),
+		boolToInt(schedule.Enabled),
+		stringTimePtr(schedule.LastRunAt),
+		stringTimePtr(schedule.NextRunAt),
+		schedule.CreatedAt.Format(time.RFC3339),
+		schedule.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert schedule: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a schedule by ID.
+func (r *ScheduleRepository) Get(ctx context.Context, id string) (*models.Schedule, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+scheduleColumns+` FROM schedules WHERE id = ?`, id)
+	schedule, err := scanSchedule(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("failed to scan schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// List returns all schedules, oldest first.
+func (r *ScheduleRepository) List(ctx context.Context) ([]*models.Schedule, error) {
+	return r.query(ctx, `SELECT `+scheduleColumns+` FROM schedules ORDER BY created_at, id`)
+}
+
+// ListDue returns enabled schedules whose next run is at or before now,
+// earliest first.
+func (r *ScheduleRepository) ListDue(ctx context.Context, now time.Time) ([]*models.Schedule, error) {
+	return r.query(ctx, `
+		SELECT `+scheduleColumns+` FROM schedules
+		WHERE enabled = 1 AND next_run_at IS NOT NULL AND next_run_at <= ?
+		ORDER BY next_run_at, id
+	`, now.UTC().Format(time.RFC3339))
+}
+
+// SetEnabled enables or disables a schedule and replaces its next run.
+func (r *ScheduleRepository) SetEnabled(ctx context.Context, id string, enabled bool, nextRun *time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE schedules SET enabled = ?, next_run_at = ?, updated_at = ? WHERE id = ?
+	`, boolToInt(enabled), stringTimePtr(nextRun), time.Now().UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return requireScheduleRow(result)
+}
+
+// Delete removes a schedule.
+func (r *ScheduleRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM schedules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	return requireScheduleRow(result)
+}
+
+// Advance handles a due run in one transaction: it moves the schedule from
+// due to next, records ranAt as the last run when set, and appends items to
+// their agents' queues. The move only applies while the stored next run
+// still equals due, so a run is handled at most once even across restarts
+// or concurrent runners; Advance reports false when another caller got
+// there first, in which case nothing is written.
+func (r *ScheduleRepository) Advance(ctx context.Context, id string, due time.Time, next, ranAt *time.Time, items []*models.QueueItem) (bool, error) {
+	for i, item := range items {
+		if err := item.Validate(); err != nil {
+			return false, fmt.Errorf("invalid queue item at index %d: %w", i, err)
+		}
+	}
+
+	advanced := false
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		result, err := tx.ExecContext(ctx, `
+			UPDATE schedules
+			SET next_run_at = ?, last_run_at = COALESCE(?, last_run_at), updated_at = ?
+			WHERE id = ? AND enabled = 1 AND next_run_at = ?
+		`,
+			stringTimePtr(next),
+			stringTimePtr(ranAt),
+			now.Format(time.RFC3339),
+			id,
+			due.UTC().Format(time.RFC3339),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to advance schedule: %w", err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rows == 0 {
+			return nil
+		}
+		advanced = true
+
+		positions := make(map[string]int)
+		for _, item := range items {
+			pos, ok := positions[item.AgentID]
+			if !ok {
+				var maxPos sql.NullInt64
+				if err := tx.QueryRowContext(ctx, `
+					SELECT MAX(position) FROM queue_items WHERE agent_id = ?
+				`, item.AgentID).Scan(&maxPos); err != nil {
+					return fmt.Errorf("failed to get max position: %w", err)
+				}
+				pos = int(maxPos.Int64)
+			}
+			pos++
+			positions[item.AgentID] = pos
+
+			if item.ID == "" {
+				item.ID = uuid.New().String()
+			}
+			item.CreatedAt = now
+			item.Position = pos
+			if item.Status == "" {
+				item.Status = models.QueueItemStatusPending
+			}
+			if err := writeQueueItem(ctx, tx, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return advanced, nil
+}
+
+func (r *ScheduleRepository) query(ctx context.Context, query string, args ...any) ([]*models.Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []*models.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func requireScheduleRow(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrScheduleNotFound
+	}
+	return nil
+}
+
+func scanSchedule(row rowScanner) (*models.Schedule, error) {
+	var schedule models.Schedule
+	var agentID, workspaceID, template, lastRunAt, nextRunAt sql.NullString
+	var enabled int
+	var createdAt, updatedAt string
+
+	if err := row.Scan(
+		&schedule.ID,
+		&agentID,
+		&workspaceID,
+		&schedule.Cron,
+		&schedule.Message,
+		&template,
+		&enabled,
+		&lastRunAt,
+		&nextRunAt,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	schedule.AgentID = agentID.String
+	schedule.WorkspaceID = workspaceID.String
+	schedule.Template = template.String
+	schedule.Enabled = enabled != 0
+	if lastRunAt.Valid {
+		if t, err := time.Parse(time.RFC3339, lastRunAt.String); err == nil {
+			schedule.LastRunAt = &t
+		}
+	}
+	if nextRunAt.Valid {
+		if t, err := time.Parse(time.RFC3339, nextRunAt.String); err == nil {
+			schedule.NextRunAt = &t
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+		schedule.CreatedAt = t
+	}
+	if t, err := time.Parse(time.RFC3339, updatedAt); err == nil {
+		schedule.UpdatedAt = t
+	}
+	return &schedule, nil
+}
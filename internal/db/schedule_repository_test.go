@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestScheduleRepository_CRUD(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	repo := NewScheduleRepository(db)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, &models.Schedule{Cron: "* * * * *", Message: "hi"}); err == nil {
+		t.Fatal("expected error for a schedule without a target")
+	}
+	if err := repo.Create(ctx, &models.Schedule{AgentID: agent.ID, WorkspaceID: ws.ID, Cron: "* * * * *", Message: "hi"}); err == nil {
+		t.Fatal("expected error for a schedule with two targets")
+	}
+
+	next := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	schedule := &models.Schedule{AgentID: agent.ID, Cron: "0 9 * * 1-5", Message: "standup", Template: "standup", Enabled: true, NextRunAt: &next}
+	if err := repo.Create(ctx, schedule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	broadcast := &models.Schedule{WorkspaceID: ws.ID, Cron: "@hourly", Message: "commit", Enabled: true, NextRunAt: &next}
+	if err := repo.Create(ctx, broadcast); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	got, err := repo.Get(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.AgentID != agent.ID || got.WorkspaceID != "" || got.Template != "standup" || !got.Enabled || !got.NextRunAt.Equal(next) || got.LastRunAt != nil {
+		t.Fatalf("unexpected schedule: %+v", got)
+	}
+
+	all, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 schedules, got %d", len(all))
+	}
+
+	if err := repo.SetEnabled(ctx, broadcast.ID, false, nil); err != nil {
+		t.Fatalf("SetEnabled failed: %v", err)
+	}
+	due, err := repo.ListDue(ctx, next)
+	if err != nil {
+		t.Fatalf("ListDue failed: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != schedule.ID {
+		t.Fatalf("expected only the enabled schedule to be due, got %+v", due)
+	}
+	if due, _ := repo.ListDue(ctx, next.Add(-time.Second)); len(due) != 0 {
+		t.Fatalf("expected nothing due before next run, got %d", len(due))
+	}
+
+	if err := repo.Delete(ctx, schedule.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := repo.Get(ctx, schedule.ID); !errors.Is(err, ErrScheduleNotFound) {
+		t.Fatalf("expected ErrScheduleNotFound, got %v", err)
+	}
+	if err := repo.SetEnabled(ctx, schedule.ID, true, nil); !errors.Is(err, ErrScheduleNotFound) {
+		t.Fatalf("expected ErrScheduleNotFound, got %v", err)
+	}
+}
+
+func TestScheduleRepository_AdvanceOnce(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	queueRepo := NewQueueRepository(db)
+	repo := NewScheduleRepository(db)
+	ctx := context.Background()
+
+	if err := queueRepo.Enqueue(ctx, agent.ID, newMessageItem(t, "existing")); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	due := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	schedule := &models.Schedule{AgentID: agent.ID, Cron: "0 9 * * *", Message: "standup", Enabled: true, NextRunAt: &due}
+	if err := repo.Create(ctx, schedule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	next := due.Add(24 * time.Hour)
+	ranAt := due.Add(5 * time.Second)
+	item := newMessageItem(t, "standup")
+	item.AgentID = agent.ID
+	ok, err := repo.Advance(ctx, schedule.ID, due, &next, &ranAt, []*models.QueueItem{item})
+	if err != nil || !ok {
+		t.Fatalf("Advance = %v, %v; want true", ok, err)
+	}
+
+	// A second runner holding the same due time loses the race and writes
+	// nothing.
+	dup := newMessageItem(t, "standup")
+	dup.AgentID = agent.ID
+	ok, err = repo.Advance(ctx, schedule.ID, due, &next, &ranAt, []*models.QueueItem{dup})
+	if err != nil || ok {
+		t.Fatalf("second Advance = %v, %v; want false", ok, err)
+	}
+
+	items, err := queueRepo.List(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(items) != 2 || items[1].ID != item.ID || items[1].Position != 2 {
+		t.Fatalf("expected the scheduled item appended once, got %+v", items)
+	}
+
+	got, err := repo.Get(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !got.NextRunAt.Equal(next) || got.LastRunAt == nil || !got.LastRunAt.Equal(ranAt) {
+		t.Fatalf("unexpected schedule after advance: %+v", got)
+	}
+
+	// Skipping a run advances without touching last_run_at or the queue.
+	later := next.Add(24 * time.Hour)
+	ok, err = repo.Advance(ctx, schedule.ID, next, &later, nil, nil)
+	if err != nil || !ok {
+		t.Fatalf("skip Advance = %v, %v; want true", ok, err)
+	}
+	got, _ = repo.Get(ctx, schedule.ID)
+	if !got.NextRunAt.Equal(later) || !got.LastRunAt.Equal(ranAt) {
+		t.Fatalf("unexpected schedule after skip: %+v", got)
+	}
+}
@@ -25,6 +25,7 @@ CREATE TABLE IF NOT EXISTS nodes (
     is_local INTEGER NOT NULL DEFAULT 0,
     last_seen_at TEXT,  -- ISO8601 timestamp
     metadata_json TEXT,  -- JSON blob for NodeMetadata
+    labels_json TEXT,  -- JSON object of placement labels
     created_at TEXT NOT NULL DEFAULT (datetime('now')),
     updated_at TEXT NOT NULL DEFAULT (datetime('now'))
 );
@@ -44,6 +45,7 @@ CREATE TABLE IF NOT EXISTS workspaces (
     tmux_session TEXT NOT NULL,
     status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive', 'error')),
     git_info_json TEXT,  -- JSON blob for GitInfo
+    placement_json TEXT,  -- JSON blob for WorkspacePlacement
     created_at TEXT NOT NULL DEFAULT (datetime('now')),
     updated_at TEXT NOT NULL DEFAULT (datetime('now')),
     UNIQUE(node_id, repo_path),
@@ -85,12 +87,13 @@ CREATE TABLE IF NOT EXISTS agents (
     type TEXT NOT NULL CHECK (type IN ('opencode', 'claude-code', 'codex', 'gemini', 'generic')),
     tmux_pane TEXT NOT NULL,  -- session:window.pane format
     account_id TEXT REFERENCES accounts(id) ON DELETE SET NULL,
-    state TEXT NOT NULL DEFAULT 'starting' CHECK (state IN ('working', 'idle', 'awaiting_approval', 'rate_limited', 'error', 'paused', 'starting', 'stopped')),
+    state TEXT NOT NULL DEFAULT 'starting' CHECK (state IN ('working', 'idle', 'awaiting_approval', 'rate_limited', 'error', 'paused', 'starting', 'stopped', 'stuck')),
     state_confidence TEXT NOT NULL DEFAULT 'low' CHECK (state_confidence IN ('high', 'medium', 'low')),
     state_reason TEXT,
     state_detected_at TEXT,
     paused_until TEXT,  -- ISO8601 timestamp for auto-resume
     last_activity_at TEXT,
+    last_output_at TEXT,  -- last pane output change, for stuck detection
     metadata_json TEXT,  -- JSON blob for AgentMetadata
     created_at TEXT NOT NULL DEFAULT (datetime('now')),
     updated_at TEXT NOT NULL DEFAULT (datetime('now')),
@@ -117,12 +120,54 @@ CREATE TABLE IF NOT EXISTS queue_items (
     error_message TEXT,
     created_at TEXT NOT NULL DEFAULT (datetime('now')),
     dispatched_at TEXT,
-    completed_at TEXT
+    completed_at TEXT,
+    task_id TEXT  -- optional tasks(id) this item belongs to
 );
 
 CREATE INDEX IF NOT EXISTS idx_queue_items_agent_id ON queue_items(agent_id);
 CREATE INDEX IF NOT EXISTS idx_queue_items_status ON queue_items(status);
 CREATE INDEX IF NOT EXISTS idx_queue_items_position ON queue_items(agent_id, position);
+CREATE INDEX IF NOT EXISTS idx_queue_items_task_id ON queue_items(task_id);
+
+-- ============================================================================
+-- TASKS TABLE
+-- Groups queue items into a unit of work with its own status
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tasks (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    workspace_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    error_message TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    started_at TEXT,
+    completed_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_agent_status ON tasks(agent_id, status);
+CREATE INDEX IF NOT EXISTS idx_tasks_workspace_id ON tasks(workspace_id);
+
+-- ============================================================================
+-- SCHEDULES TABLE
+-- Recurring cron schedules that enqueue a message for an agent or workspace
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS schedules (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT REFERENCES agents(id) ON DELETE CASCADE,
+    workspace_id TEXT REFERENCES workspaces(id) ON DELETE CASCADE,
+    cron_expr TEXT NOT NULL,
+    message TEXT NOT NULL,
+    template TEXT,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    last_run_at TEXT,
+    next_run_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    CHECK ((agent_id IS NULL) != (workspace_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_due ON schedules(enabled, next_run_at);
 
 -- ============================================================================
 -- EVENTS TABLE
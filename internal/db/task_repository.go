@@ -0,0 +1,249 @@
+// Package db provides SQLite database access for Swarm.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrTaskNotFound is returned when a task does not exist.
+var ErrTaskNotFound = errors.New("task not found")
+
+// TaskFilter narrows task listings. Zero values match everything.
+type TaskFilter struct {
+	AgentID     string
+	WorkspaceID string
+	Status      models.TaskStatus
+	Limit       int
+}
+
+// TaskRepository handles task persistence.
+type TaskRepository struct {
+	db *DB
+}
+
+// NewTaskRepository creates a new TaskRepository.
+func NewTaskRepository(db *DB) *TaskRepository {
+	return &TaskRepository{db: db}
+}
+
+// Create inserts a task and appends its items to the agent's queue in one
+// transaction, so a task is never visible without its items.
+func (r *TaskRepository) Create(ctx context.Context, task *models.Task, items ...*models.QueueItem) error {
+	if task.Status == "" {
+		task.Status = models.TaskStatusPending
+	}
+	if err := task.Validate(); err != nil {
+		return fmt.Errorf("invalid task: %w", err)
+	}
+	for i, item := range items {
+		if err := item.Validate(); err != nil {
+			return fmt.Errorf("invalid queue item at index %d: %w", i, err)
+		}
+	}
+
+	if task.ID == "" {
+		task.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	task.CreatedAt = now
+
+	return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO tasks (
+				id, agent_id, workspace_id, title, status, error_message,
+				created_at, started_at, completed_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			task.ID,
+			task.AgentID,
+			task.WorkspaceID,
+			task.Title,
+			string(task.Status),
+			task.Error,
+			task.CreatedAt.Format(time.RFC3339),
+			stringTimePtr(task.StartedAt),
+			stringTimePtr(task.CompletedAt),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert task: %w", err)
+		}
+
+		var maxPos sql.NullInt64
+		if err := tx.QueryRowContext(ctx, `
+			SELECT MAX(position) FROM queue_items WHERE agent_id = ?
+		`, task.AgentID).Scan(&maxPos); err != nil {
+			return fmt.Errorf("failed to get max position: %w", err)
+		}
+
+		for i, item := range items {
+			if item.ID == "" {
+				item.ID = uuid.New().String()
+			}
+			item.AgentID = task.AgentID
+			item.TaskID = task.ID
+			item.CreatedAt = now
+			item.Position = int(maxPos.Int64) + i + 1
+			if item.Status == "" {
+				item.Status = models.QueueItemStatusPending
+			}
+			if err := writeQueueItem(ctx, tx, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get retrieves a task by ID.
+func (r *TaskRepository) Get(ctx context.Context, id string) (*models.Task, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT
+			id, agent_id, workspace_id, title, status, error_message,
+			created_at, started_at, completed_at
+		FROM tasks WHERE id = ?
+	`, id)
+
+	task, err := scanTask(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to scan task: %w", err)
+	}
+	return task, nil
+}
+
+// List returns tasks matching filter, newest first.
+func (r *TaskRepository) List(ctx context.Context, filter TaskFilter) ([]*models.Task, error) {
+	query := `
+		SELECT
+			id, agent_id, workspace_id, title, status, error_message,
+			created_at, started_at, completed_at
+		FROM tasks`
+
+	var conditions []string
+	var args []any
+	if filter.AgentID != "" {
+		conditions = append(conditions, "agent_id = ?")
+		args = append(args, filter.AgentID)
+	}
+	if filter.WorkspaceID != "" {
+		conditions = append(conditions, "workspace_id = ?")
+		args = append(args, filter.WorkspaceID)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, string(filter.Status))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// Update persists a task's status, error, and timestamps.
+func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
+	if err := task.Validate(); err != nil {
+		return fmt.Errorf("invalid task: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE tasks
+		SET title = ?, status = ?, error_message = ?, started_at = ?, completed_at = ?
+		WHERE id = ?
+	`,
+		task.Title,
+		string(task.Status),
+		task.Error,
+		stringTimePtr(task.StartedAt),
+		stringTimePtr(task.CompletedAt),
+		task.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row rowScanner) (*models.Task, error) {
+	var task models.Task
+	var status, createdAt string
+	var errorMsg, startedAt, completedAt sql.NullString
+
+	if err := row.Scan(
+		&task.ID,
+		&task.AgentID,
+		&task.WorkspaceID,
+		&task.Title,
+		&status,
+		&errorMsg,
+		&createdAt,
+		&startedAt,
+		&completedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	task.Status = models.TaskStatus(status)
+	if errorMsg.Valid {
+		task.Error = errorMsg.String
+	}
+	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+		task.CreatedAt = t
+	}
+	if startedAt.Valid {
+		if t, err := time.Parse(time.RFC3339, startedAt.String); err == nil {
+			task.StartedAt = &t
+		}
+	}
+	if completedAt.Valid {
+		if t, err := time.Parse(time.RFC3339, completedAt.String); err == nil {
+			task.CompletedAt = &t
+		}
+	}
+	return &task, nil
+}
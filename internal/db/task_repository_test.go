@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestTaskRepository_CreateWithItems(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	queueRepo := NewQueueRepository(db)
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+
+	// An unrelated item already in the queue keeps its place.
+	if err := queueRepo.Enqueue(ctx, agent.ID, newMessageItem(t, "before")); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	task := &models.Task{AgentID: agent.ID, WorkspaceID: ws.ID, Title: "refactor"}
+	if err := repo.Create(ctx, task, newMessageItem(t, "one"), newMessageItem(t, "two")); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if task.ID == "" || task.Status != models.TaskStatusPending {
+		t.Fatalf("expected pending task with id, got %+v", task)
+	}
+
+	items, err := queueRepo.ListByTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("ListByTask failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 task items, got %d", len(items))
+	}
+	if items[0].Position != 2 || items[1].Position != 3 {
+		t.Fatalf("expected positions 2,3, got %d,%d", items[0].Position, items[1].Position)
+	}
+	for _, item := range items {
+		if item.TaskID != task.ID {
+			t.Fatalf("expected task id %s, got %q", task.ID, item.TaskID)
+		}
+	}
+
+	all, err := queueRepo.List(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(all) != 3 || all[0].TaskID != "" {
+		t.Fatalf("expected untouched first item, got %+v", all[0])
+	}
+}
+
+func TestTaskRepository_CreateRollsBackOnInvalidItem(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+
+	bad := &models.QueueItem{Type: models.QueueItemTypeMessage}
+	task := &models.Task{AgentID: agent.ID, WorkspaceID: ws.ID, Title: "broken"}
+	if err := repo.Create(ctx, task, newMessageItem(t, "ok"), bad); err == nil {
+		t.Fatal("expected invalid item error")
+	}
+
+	tasks, err := repo.List(ctx, TaskFilter{AgentID: agent.ID})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Fatalf("expected no tasks, got %d", len(tasks))
+	}
+}
+
+func TestTaskRepository_UpdateGetList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+
+	first := &models.Task{AgentID: agent.ID, WorkspaceID: ws.ID, Title: "first"}
+	second := &models.Task{AgentID: agent.ID, WorkspaceID: ws.ID, Title: "second"}
+	for _, task := range []*models.Task{first, second} {
+		if err := repo.Create(ctx, task, newMessageItem(t, task.Title)); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	started := time.Now().UTC().Truncate(time.Second)
+	finished := started.Add(3 * time.Second)
+	first.Status = models.TaskStatusFailed
+	first.Error = "boom"
+	first.StartedAt = &started
+	first.CompletedAt = &finished
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	got, err := repo.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != models.TaskStatusFailed || got.Error != "boom" {
+		t.Fatalf("unexpected task after update: %+v", got)
+	}
+	if got.StartedAt == nil || !got.StartedAt.Equal(started) || got.CompletedAt == nil || !got.CompletedAt.Equal(finished) {
+		t.Fatalf("unexpected timestamps: %v %v", got.StartedAt, got.CompletedAt)
+	}
+
+	failed, err := repo.List(ctx, TaskFilter{AgentID: agent.ID, Status: models.TaskStatusFailed})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(failed) != 1 || failed[0].ID != first.ID {
+		t.Fatalf("expected only the failed task, got %+v", failed)
+	}
+
+	all, err := repo.List(ctx, TaskFilter{WorkspaceID: ws.ID})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(all))
+	}
+
+	if _, err := repo.Get(ctx, "missing"); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+	if err := repo.Update(ctx, &models.Task{ID: "missing", AgentID: agent.ID, WorkspaceID: ws.ID, Title: "x", Status: models.TaskStatusRunning}); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound on update, got %v", err)
+	}
+}
+
+func TestQueueRepository_SkipPendingByTask(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	queueRepo := NewQueueRepository(db)
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+
+	task := &models.Task{AgentID: agent.ID, WorkspaceID: ws.ID, Title: "batch"}
+	if err := repo.Create(ctx, task, newMessageItem(t, "one"), newMessageItem(t, "two")); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := queueRepo.Dequeue(ctx, agent.ID, "test"); err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+
+	skipped, err := queueRepo.SkipPendingByTask(ctx, task.ID, "task failed")
+	if err != nil {
+		t.Fatalf("SkipPendingByTask failed: %v", err)
+	}
+	if skipped != 1 {
+		t.Fatalf("expected 1 skipped item, got %d", skipped)
+	}
+
+	items, err := queueRepo.ListByTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("ListByTask failed: %v", err)
+	}
+	if items[0].Status != models.QueueItemStatusInFlight {
+		t.Fatalf("expected in-flight item untouched, got %s", items[0].Status)
+	}
+	if items[1].Status != models.QueueItemStatusSkipped || items[1].Error != "task failed" {
+		t.Fatalf("expected skipped item, got %s %q", items[1].Status, items[1].Error)
+	}
+}
@@ -43,7 +43,7 @@ func withRetry(ctx context.Context, maxAttempts int, baseBackoff time.Duration,
 		}
 
 		attempt++
-		if !isBusyError(err) || attempt >= maxAttempts {
+		if !IsBusyError(err) || attempt >= maxAttempts {
 			return err
 		}
 
@@ -55,7 +55,9 @@ func withRetry(ctx context.Context, maxAttempts int, baseBackoff time.Duration,
 	}
 }
 
-func isBusyError(err error) bool {
+// IsBusyError reports whether err is SQLite refusing a statement because
+// another connection holds the lock it needs.
+func IsBusyError(err error) bool {
 	if err == nil {
 		return false
 	}
@@ -0,0 +1,280 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrTranscriptPushNotFound is returned when a transcript push
+// subscription does not exist.
+var ErrTranscriptPushNotFound = errors.New("transcript push subscription not found")
+
+// TranscriptPushRepository handles transcript push subscription
+// persistence.
+type TranscriptPushRepository struct {
+	db *DB
+}
+
+// NewTranscriptPushRepository creates a new TranscriptPushRepository.
+func NewTranscriptPushRepository(db *DB) *TranscriptPushRepository {
+	return &TranscriptPushRepository{db: db}
+}
+
+const transcriptPushColumns = `
+	id, agent_id, url, entry_types_json, secret, status, stream, cursor,
+	head, delivered, failures, last_error, last_delivered_at, created_at,
+	updated_at, completed_at`
+
+// Create inserts a new subscription, active and starting at the beginning
+// of the agent's transcript.
+func (r *TranscriptPushRepository) Create(ctx context.Context, push *models.TranscriptPush) error {
+	if err := push.Validate(); err != nil {
+		return fmt.Errorf("invalid transcript push: %w", err)
+	}
+
+	if push.ID == "" {
+		push.ID = uuid.New().String()
+	}
+	push.Status = models.TranscriptPushActive
+	now := time.Now().UTC()
+	push.CreatedAt = now
+	push.UpdatedAt = now
+
+	var entryTypes sql.NullString
+	if len(push.EntryTypes) > 0 {
+		data, err := json.Marshal(push.EntryTypes)
+		if err != nil {
+			return fmt.Errorf("failed to marshal entry types: %w", err)
+		}
+		entryTypes = sql.NullString{String: string(data), Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO transcript_pushes (id, agent_id, url, entry_types_json, secret, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		push.ID,
+		push.AgentID,
+		push.URL,
+		entryTypes,
+		nullString(push.Secret),
+		string(push.Status),
+		now.Format(time.RFC3339),
+		now.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert transcript push: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a subscription by ID.
+func (r *TranscriptPushRepository) Get(ctx context.Context, id string) (*models.TranscriptPush, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+transcriptPushColumns+` FROM transcript_pushes WHERE id = ?`, id)
+	push, err := scanTranscriptPush(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTranscriptPushNotFound
+		}
+		return nil, fmt.Errorf("failed to scan transcript push: %w", err)
+	}
+	return push, nil
+}
+
+// List returns the subscriptions for agentID, or every subscription when
+// agentID is empty, oldest first.
+func (r *TranscriptPushRepository) List(ctx context.Context, agentID string) ([]*models.TranscriptPush, error) {
+	if agentID == "" {
+		return r.query(ctx, `SELECT `+transcriptPushColumns+` FROM transcript_pushes ORDER BY created_at, id`)
+	}
+	return r.query(ctx, `
+		SELECT `+transcriptPushColumns+` FROM transcript_pushes
+		WHERE agent_id = ?
+		ORDER BY created_at, id
+	`, agentID)
+}
+
+// ListActive returns the subscriptions swarmd should follow, oldest first.
+func (r *TranscriptPushRepository) ListActive(ctx context.Context) ([]*models.TranscriptPush, error) {
+	return r.query(ctx, `
+		SELECT `+transcriptPushColumns+` FROM transcript_pushes
+		WHERE status = ?
+		ORDER BY created_at, id
+	`, string(models.TranscriptPushActive))
+}
+
+// Delete removes a subscription.
+func (r *TranscriptPushRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM transcript_pushes WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete transcript push: %w", err)
+	}
+	return requireTranscriptPushRow(result)
+}
+
+// Advance records a batch acknowledged at deliveredAt: the cursor moves to
+// cursor in stream, delivered entries are counted, and the failure count
+// and error are cleared. A nil deliveredAt moves the cursor past entries
+// that were not pushed without counting a delivery. Head is the agent's
+// next transcript ID as seen when the batch was read.
+func (r *TranscriptPushRepository) Advance(ctx context.Context, id, stream string, cursor, head int64, delivered int, deliveredAt *time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE transcript_pushes
+		SET stream = ?, cursor = ?, head = ?, delivered = delivered + ?,
+			failures = CASE WHEN ? IS NULL THEN failures ELSE 0 END,
+			last_error = CASE WHEN ? IS NULL THEN last_error ELSE NULL END,
+			last_delivered_at = COALESCE(?, last_delivered_at),
+			updated_at = ?
+		WHERE id = ? AND status = ?
+	`,
+		nullString(stream),
+		cursor,
+		head,
+		delivered,
+		stringTimePtr(deliveredAt),
+		stringTimePtr(deliveredAt),
+		stringTimePtr(deliveredAt),
+		time.Now().UTC().Format(time.RFC3339),
+		id,
+		string(models.TranscriptPushActive),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to advance transcript push: %w", err)
+	}
+	return requireTranscriptPushRow(result)
+}
+
+// RecordFailure counts a failed delivery attempt and keeps its error.
+// Head is the agent's next transcript ID as seen for the attempt.
+func (r *TranscriptPushRepository) RecordFailure(ctx context.Context, id string, head int64, message string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE transcript_pushes
+		SET head = ?, failures = failures + 1, last_error = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, head, message, time.Now().UTC().Format(time.RFC3339), id, string(models.TranscriptPushActive))
+	if err != nil {
+		return fmt.Errorf("failed to record transcript push failure: %w", err)
+	}
+	return requireTranscriptPushRow(result)
+}
+
+// Complete records the final batch acknowledged at completedAt, with
+// delivered entries in it, and stops the subscription.
+func (r *TranscriptPushRepository) Complete(ctx context.Context, id, stream string, cursor int64, delivered int, completedAt time.Time) error {
+	at := completedAt.UTC().Format(time.RFC3339)
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE transcript_pushes
+		SET status = ?, stream = ?, cursor = ?, head = ?, delivered = delivered + ?,
+			failures = 0, last_error = NULL, last_delivered_at = ?,
+			completed_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`,
+		string(models.TranscriptPushCompleted),
+		nullString(stream),
+		cursor,
+		cursor,
+		delivered,
+		at,
+		at,
+		time.Now().UTC().Format(time.RFC3339),
+		id,
+		string(models.TranscriptPushActive),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete transcript push: %w", err)
+	}
+	return requireTranscriptPushRow(result)
+}
+
+func (r *TranscriptPushRepository) query(ctx context.Context, query string, args ...any) ([]*models.TranscriptPush, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transcript pushes: %w", err)
+	}
+	defer rows.Close()
+
+	var pushes []*models.TranscriptPush
+	for rows.Next() {
+		push, err := scanTranscriptPush(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transcript push: %w", err)
+		}
+		pushes = append(pushes, push)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transcript pushes: %w", err)
+	}
+	return pushes, nil
+}
+
+func requireTranscriptPushRow(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrTranscriptPushNotFound
+	}
+	return nil
+}
+
+func scanTranscriptPush(row rowScanner) (*models.TranscriptPush, error) {
+	var push models.TranscriptPush
+	var entryTypes, secret, stream, lastError, lastDeliveredAt, completedAt sql.NullString
+	var status, createdAt, updatedAt string
+
+	if err := row.Scan(
+		&push.ID,
+		&push.AgentID,
+		&push.URL,
+		&entryTypes,
+		&secret,
+		&status,
+		&stream,
+		&push.Cursor,
+		&push.Head,
+		&push.Delivered,
+		&push.Failures,
+		&lastError,
+		&lastDeliveredAt,
+		&createdAt,
+		&updatedAt,
+		&completedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if entryTypes.Valid && entryTypes.String != "" {
+		if err := json.Unmarshal([]byte(entryTypes.String), &push.EntryTypes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal entry types: %w", err)
+		}
+	}
+	push.Secret = secret.String
+	push.Status = models.TranscriptPushStatus(status)
+	push.Stream = stream.String
+	push.LastError = lastError.String
+	if lastDeliveredAt.Valid {
+		if t, err := time.Parse(time.RFC3339, lastDeliveredAt.String); err == nil {
+			push.LastDeliveredAt = &t
+		}
+	}
+	if completedAt.Valid {
+		if t, err := time.Parse(time.RFC3339, completedAt.String); err == nil {
+			push.CompletedAt = &t
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+		push.CreatedAt = t
+	}
+	if t, err := time.Parse(time.RFC3339, updatedAt); err == nil {
+		push.UpdatedAt = t
+	}
+	return &push, nil
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+const transcriptColumns = `agent_id, entry_id, end_id, timestamp, type, content, metadata_json`
+
+// TranscriptListOptions filters the entries ListByAgent returns. Zero
+// fields match everything.
+type TranscriptListOptions struct {
+	Cursor int64      // Entries covering IDs at or after this one
+	Before int64      // Entries with IDs below this one (0 = no bound)
+	Since  *time.Time // Entries at or after this time (inclusive)
+	Until  *time.Time // Entries at or before this time (inclusive)
+	Limit  int        // Max results to return (0 = all)
+}
+
+// TranscriptRepository handles persistence of swarmd transcript entries.
+type TranscriptRepository struct {
+	db *DB
+}
+
+// NewTranscriptRepository creates a new TranscriptRepository.
+func NewTranscriptRepository(db *DB) *TranscriptRepository {
+	return &TranscriptRepository{db: db}
+}
+
+// Create stores an entry. An entry with the same agent and ID replaces the
+// stored one.
+func (r *TranscriptRepository) Create(ctx context.Context, entry *models.TranscriptEntry) error {
+	if entry.AgentID == "" {
+		return fmt.Errorf("transcript entry agent id is required")
+	}
+	if entry.Type == "" {
+		return fmt.Errorf("transcript entry type is required")
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+
+	var metadata any
+	if len(entry.Metadata) > 0 {
+		data, err := json.Marshal(entry.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal transcript metadata: %w", err)
+		}
+		metadata = string(data)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO transcript_entries (`+transcriptColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		entry.AgentID,
+		entry.EntryID,
+		entry.EndID,
+		entry.Timestamp.UTC().Format(time.RFC3339),
+		entry.Type,
+		entry.Content,
+		metadata,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert transcript entry: %w", err)
+	}
+	return nil
+}
+
+// ListByAgent returns the stored entries of agentID matching opts, oldest
+// first.
+func (r *TranscriptRepository) ListByAgent(ctx context.Context, agentID string, opts TranscriptListOptions) ([]*models.TranscriptEntry, error) {
+	query := `SELECT ` + transcriptColumns + ` FROM transcript_entries WHERE agent_id = ? AND max(entry_id, end_id) >= ?`
+	args := []any{agentID, opts.Cursor}
+	if opts.Before > 0 {
+		query += ` AND entry_id < ?`
+		args = append(args, opts.Before)
+	}
+	if opts.Since != nil {
+		query += ` AND timestamp >= ?`
+		args = append(args, opts.Since.UTC().Format(time.RFC3339))
+	}
+	if opts.Until != nil {
+		query += ` AND timestamp <= ?`
+		args = append(args, opts.Until.UTC().Format(time.RFC3339))
+	}
+	query += ` ORDER BY entry_id`
+	if opts.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transcript entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.TranscriptEntry{}
+	for rows.Next() {
+		entry, err := scanTranscriptEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transcript entries: %w", err)
+	}
+	return entries, nil
+}
+
+// NextID returns the ID the next entry of agentID's transcript takes: one
+// past the last stored ID, or zero when none is stored.
+func (r *TranscriptRepository) NextID(ctx context.Context, agentID string) (int64, error) {
+	var last sql.NullInt64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT max(max(entry_id, end_id)) FROM transcript_entries WHERE agent_id = ?
+	`, agentID).Scan(&last)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query last transcript entry: %w", err)
+	}
+	if !last.Valid {
+		return 0, nil
+	}
+	return last.Int64 + 1, nil
+}
+
+// DeleteOlderThan deletes up to limit entries, of any agent, older than
+// before. Returns the number of entries deleted.
+func (r *TranscriptRepository) DeleteOlderThan(ctx context.Context, before time.Time, limit int) (int64, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM transcript_entries WHERE rowid IN (
+			SELECT rowid FROM transcript_entries WHERE timestamp < ? ORDER BY timestamp LIMIT ?
+		)
+	`, before.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old transcript entries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// DeleteExcess deletes up to limit of the oldest entries of each agent
+// beyond its newest maxEntries. Returns the number of entries deleted.
+func (r *TranscriptRepository) DeleteExcess(ctx context.Context, maxEntries int, limit int) (int64, error) {
+	if maxEntries <= 0 {
+		return 0, nil
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM transcript_entries WHERE rowid IN (
+			SELECT rowid FROM (
+				SELECT rowid, row_number() OVER (PARTITION BY agent_id ORDER BY entry_id DESC) AS rank
+				FROM transcript_entries
+			) WHERE rank > ? LIMIT ?
+		)
+	`, maxEntries, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete excess transcript entries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func scanTranscriptEntry(rows *sql.Rows) (*models.TranscriptEntry, error) {
+	var entry models.TranscriptEntry
+	var timestamp string
+	var metadata sql.NullString
+	if err := rows.Scan(&entry.AgentID, &entry.EntryID, &entry.EndID, &timestamp, &entry.Type, &entry.Content, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to scan transcript entry: %w", err)
+	}
+	if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+		entry.Timestamp = t
+	}
+	if metadata.Valid && metadata.String != "" {
+		if err := json.Unmarshal([]byte(metadata.String), &entry.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transcript metadata: %w", err)
+		}
+	}
+	return &entry, nil
+}
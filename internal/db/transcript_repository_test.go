@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestTranscriptRepository_CreateAndList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewTranscriptRepository(db)
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	next, err := repo.NextID(ctx, "agent-1")
+	if err != nil {
+		t.Fatalf("NextID failed: %v", err)
+	}
+	if next != 0 {
+		t.Fatalf("expected 0 for an empty transcript, got %d", next)
+	}
+
+	entries := []*models.TranscriptEntry{
+		{AgentID: "agent-1", EntryID: 0, Timestamp: now, Type: "command", Content: "claude", Metadata: map[string]string{"event": "spawn"}},
+		{AgentID: "agent-1", EntryID: 1, EndID: 4, Timestamp: now.Add(time.Minute), Type: "summary", Content: "[4 output entries compacted]"},
+		{AgentID: "agent-1", EntryID: 5, Timestamp: now.Add(2 * time.Minute), Type: "output", Content: "done"},
+		{AgentID: "agent-2", EntryID: 0, Timestamp: now, Type: "command", Content: "codex"},
+	}
+	for _, entry := range entries {
+		if err := repo.Create(ctx, entry); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	if err := repo.Create(ctx, &models.TranscriptEntry{EntryID: 9, Type: "output"}); err == nil {
+		t.Fatal("expected an entry without an agent to be rejected")
+	}
+
+	all, err := repo.ListByAgent(ctx, "agent-1", TranscriptListOptions{})
+	if err != nil {
+		t.Fatalf("ListByAgent failed: %v", err)
+	}
+	if len(all) != 3 || all[0].EntryID != 0 || all[2].EntryID != 5 {
+		t.Fatalf("expected agent-1's entries in ID order, got %+v", all)
+	}
+	if all[0].Metadata["event"] != "spawn" || all[1].LastID() != 4 || !all[2].Timestamp.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("fields not round-tripped: %+v %+v %+v", all[0], all[1], all[2])
+	}
+
+	// A cursor inside a summary's range still returns the summary.
+	page, err := repo.ListByAgent(ctx, "agent-1", TranscriptListOptions{Cursor: 3, Before: 5})
+	if err != nil {
+		t.Fatalf("ListByAgent failed: %v", err)
+	}
+	if len(page) != 1 || page[0].EntryID != 1 {
+		t.Fatalf("expected only the summary, got %+v", page)
+	}
+
+	since := now.Add(30 * time.Second)
+	page, err = repo.ListByAgent(ctx, "agent-1", TranscriptListOptions{Since: &since, Limit: 1})
+	if err != nil {
+		t.Fatalf("ListByAgent failed: %v", err)
+	}
+	if len(page) != 1 || page[0].EntryID != 1 {
+		t.Fatalf("expected the first entry since the cutoff, got %+v", page)
+	}
+
+	if next, err = repo.NextID(ctx, "agent-1"); err != nil || next != 6 {
+		t.Fatalf("expected next ID 6, got %d (%v)", next, err)
+	}
+}
+
+func TestTranscriptRepository_Retention(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewTranscriptRepository(db)
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	for _, agentID := range []string{"agent-1", "agent-2"} {
+		for i := 0; i < 5; i++ {
+			entry := &models.TranscriptEntry{AgentID: agentID, EntryID: int64(i), Timestamp: now.Add(time.Duration(i) * time.Hour), Type: "output"}
+			if err := repo.Create(ctx, entry); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+	}
+
+	deleted, err := repo.DeleteOlderThan(ctx, now.Add(90*time.Minute), 100)
+	if err != nil {
+		t.Fatalf("DeleteOlderThan failed: %v", err)
+	}
+	if deleted != 4 {
+		t.Fatalf("expected 4 entries older than the cutoff deleted, got %d", deleted)
+	}
+
+	deleted, err = repo.DeleteExcess(ctx, 2, 100)
+	if err != nil {
+		t.Fatalf("DeleteExcess failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected one excess entry per agent deleted, got %d", deleted)
+	}
+	for _, agentID := range []string{"agent-1", "agent-2"} {
+		left, err := repo.ListByAgent(ctx, agentID, TranscriptListOptions{})
+		if err != nil {
+			t.Fatalf("ListByAgent failed: %v", err)
+		}
+		if len(left) != 2 || left[0].EntryID != 3 || left[1].EntryID != 4 {
+			t.Fatalf("expected %s's newest 2 entries kept, got %+v", agentID, left)
+		}
+	}
+
+	// Retention never moves the next ID back.
+	if next, err := repo.NextID(ctx, "agent-1"); err != nil || next != 5 {
+		t.Fatalf("expected next ID 5, got %d (%v)", next, err)
+	}
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// dailyCacheRefreshOverlap is how far before the last refresh an
+// incremental refresh looks for new records. Records are stamped before
+// they commit, so a record committed just after a refresh read the table
+// can carry an earlier created_at; re-reading the last minute of records
+// catches it at the cost of recomputing a few rows twice.
+const dailyCacheRefreshOverlap = time.Minute
+
+// RefreshDailyCache brings the daily usage cache up to date as of now,
+// recomputing the (account, date, provider) rows of the records stored
+// since the last refresh, whatever day they were recorded on. The first
+// refresh rebuilds the whole cache. Returns the number of rows recomputed.
+func (r *UsageRepository) RefreshDailyCache(ctx context.Context, now time.Time) (int, error) {
+	refreshed := 0
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		var last string
+		err := tx.QueryRowContext(ctx, `SELECT refreshed_at FROM usage_cache_state WHERE id = 1`).Scan(&last)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to read cache refresh state: %w", err)
+		}
+
+		var rows *sql.Rows
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM daily_usage_cache`); err != nil {
+				return fmt.Errorf("failed to clear daily cache: %w", err)
+			}
+			rows, err = tx.QueryContext(ctx, `
+				SELECT DISTINCT account_id, date(recorded_at), provider FROM usage_records
+			`)
+		} else {
+			since, parseErr := time.Parse(time.RFC3339, last)
+			if parseErr != nil {
+				return fmt.Errorf("invalid cache refresh state %q: %w", last, parseErr)
+			}
+			rows, err = tx.QueryContext(ctx, `
+				SELECT DISTINCT account_id, date(recorded_at), provider FROM usage_records
+				WHERE created_at >= ?
+			`, since.Add(-dailyCacheRefreshOverlap).UTC().Format(time.RFC3339))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query touched usage days: %w", err)
+		}
+		var touched [][3]string
+		for rows.Next() {
+			var key [3]string
+			if err := rows.Scan(&key[0], &key[1], &key[2]); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan usage day: %w", err)
+			}
+			touched = append(touched, key)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return fmt.Errorf("error iterating usage days: %w", err)
+		}
+		rows.Close()
+
+		for _, key := range touched {
+			if err := recomputeDailyCache(ctx, tx, key[0], key[1], models.Provider(key[2])); err != nil {
+				return err
+			}
+		}
+		refreshed = len(touched)
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO usage_cache_state (id, refreshed_at) VALUES (1, ?)
+			ON CONFLICT(id) DO UPDATE SET refreshed_at = excluded.refreshed_at
+		`, now.UTC().Format(time.RFC3339)); err != nil {
+			return fmt.Errorf("failed to save cache refresh state: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return refreshed, nil
+}
+
+// DailyCacheRefreshedAt returns when the daily usage cache was last
+// refreshed, or nil if it never was.
+func (r *UsageRepository) DailyCacheRefreshedAt(ctx context.Context) (*time.Time, error) {
+	var refreshedAt string
+	err := r.db.QueryRowContext(ctx, `SELECT refreshed_at FROM usage_cache_state WHERE id = 1`).Scan(&refreshedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache refresh state: %w", err)
+	}
+	t, err := time.Parse(time.RFC3339, refreshedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache refresh state %q: %w", refreshedAt, err)
+	}
+	return &t, nil
+}
+
+// SummarizeDailyCache returns the cached usage of the whole days from
+// fromDate up to but excluding toDate (YYYY-MM-DD; an empty fromDate has
+// no lower bound), for one account and one provider when they are set.
+func (r *UsageRepository) SummarizeDailyCache(ctx context.Context, accountID string, provider models.Provider, fromDate, toDate string) (*models.UsageSummary, error) {
+	var summary models.UsageSummary
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(input_tokens), 0),
+			COALESCE(SUM(output_tokens), 0),
+			COALESCE(SUM(total_tokens), 0),
+			COALESCE(SUM(cost_cents), 0),
+			COALESCE(SUM(request_count), 0),
+			COALESCE(SUM(record_count), 0)
+		FROM daily_usage_cache
+		WHERE (? = '' OR account_id = ?) AND (? = '' OR provider = ?)
+			AND (? = '' OR date >= ?) AND date < ?
+	`, accountID, accountID, string(provider), string(provider), fromDate, fromDate, toDate).Scan(
+		&summary.InputTokens,
+		&summary.OutputTokens,
+		&summary.TotalTokens,
+		&summary.TotalCostCents,
+		&summary.RequestCount,
+		&summary.RecordCount,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize daily cache: %w", err)
+	}
+	summary.AccountID = accountID
+	summary.Provider = provider
+	return &summary, nil
+}
+
+// DeleteDailyCacheBefore removes the daily usage cache rows of the days
+// before date (YYYY-MM-DD). Returns the number of rows deleted.
+func (r *UsageRepository) DeleteDailyCacheBefore(ctx context.Context, date string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM daily_usage_cache WHERE date < ?`, date)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old daily cache rows: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted count: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,225 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func setupUsageCacheDB(t *testing.T) (*DB, *UsageRepository, *models.Account) {
+	t.Helper()
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "cache"}
+	if err := NewAccountRepository(database).Create(ctx, account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	return database, NewUsageRepository(database), account
+}
+
+func cachedCost(t *testing.T, repo *UsageRepository, date string) int64 {
+	t.Helper()
+	rows, err := repo.GetDailyCache(context.Background(), date)
+	if err != nil {
+		t.Fatalf("GetDailyCache: %v", err)
+	}
+	var cost int64
+	for _, row := range rows {
+		cost += row.CostCents
+	}
+	return cost
+}
+
+func TestUsageRepositoryRefreshDailyCacheIncremental(t *testing.T) {
+	ctx := context.Background()
+	database, repo, account := setupUsageCacheDB(t)
+
+	now := time.Now().UTC()
+	today := now.Format("2006-01-02")
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	for _, r := range []*models.UsageRecord{
+		{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 100, CostCents: 4, RecordedAt: now},
+		{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 200, CostCents: 6, RecordedAt: now.AddDate(0, 0, -1)},
+	} {
+		if err := repo.Create(ctx, r); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	refreshedAt, err := repo.DailyCacheRefreshedAt(ctx)
+	if err != nil {
+		t.Fatalf("DailyCacheRefreshedAt: %v", err)
+	}
+	if refreshedAt != nil {
+		t.Fatalf("expected a never-refreshed cache, got %v", refreshedAt)
+	}
+
+	// The first refresh rebuilds every day.
+	first := now.Add(-time.Hour)
+	n, err := repo.RefreshDailyCache(ctx, first)
+	if err != nil {
+		t.Fatalf("RefreshDailyCache: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 rows rebuilt, got %d", n)
+	}
+	if got := cachedCost(t, repo, today); got != 4 {
+		t.Errorf("expected today cached at 4, got %d", got)
+	}
+	if got := cachedCost(t, repo, yesterday); got != 6 {
+		t.Errorf("expected yesterday cached at 6, got %d", got)
+	}
+	refreshedAt, err = repo.DailyCacheRefreshedAt(ctx)
+	if err != nil {
+		t.Fatalf("DailyCacheRefreshedAt: %v", err)
+	}
+	if refreshedAt == nil || !refreshedAt.Equal(first.Truncate(time.Second)) {
+		t.Fatalf("expected the refresh time recorded, got %v", refreshedAt)
+	}
+
+	// Age the records past the high-water mark and tamper with yesterday's
+	// row: a refresh that recomputed it would undo the change.
+	if _, err := database.ExecContext(ctx, `UPDATE usage_records SET created_at = ?`, now.Add(-2*time.Hour).Format(time.RFC3339)); err != nil {
+		t.Fatalf("age records: %v", err)
+	}
+	if _, err := database.ExecContext(ctx, `UPDATE daily_usage_cache SET cost_cents = 999 WHERE date = ?`, yesterday); err != nil {
+		t.Fatalf("tamper cache: %v", err)
+	}
+
+	// A record backfilled into an older day is picked up by the next
+	// refresh along with the new usage of today.
+	backfillDay := now.AddDate(0, 0, -10)
+	for _, r := range []*models.UsageRecord{
+		{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 50, CostCents: 3, RecordedAt: now},
+		{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 70, CostCents: 7, RecordedAt: backfillDay},
+	} {
+		if err := repo.Create(ctx, r); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	n, err = repo.RefreshDailyCache(ctx, now)
+	if err != nil {
+		t.Fatalf("RefreshDailyCache: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected only the 2 touched rows recomputed, got %d", n)
+	}
+	if got := cachedCost(t, repo, today); got != 7 {
+		t.Errorf("expected today recomputed to 7, got %d", got)
+	}
+	if got := cachedCost(t, repo, backfillDay.Format("2006-01-02")); got != 7 {
+		t.Errorf("expected the backfilled day cached at 7, got %d", got)
+	}
+	if got := cachedCost(t, repo, yesterday); got != 999 {
+		t.Errorf("expected untouched yesterday left alone, got %d", got)
+	}
+}
+
+func TestUsageRepositoryDeleteRecomputesDailyCache(t *testing.T) {
+	ctx := context.Background()
+	_, repo, account := setupUsageCacheDB(t)
+
+	now := time.Now().UTC()
+	today := now.Format("2006-01-02")
+	kept := &models.UsageRecord{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 10, CostCents: 2, RecordedAt: now}
+	deleted := &models.UsageRecord{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 20, CostCents: 5, RecordedAt: now}
+	for _, r := range []*models.UsageRecord{kept, deleted} {
+		if err := repo.Create(ctx, r); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if _, err := repo.RefreshDailyCache(ctx, now); err != nil {
+		t.Fatalf("RefreshDailyCache: %v", err)
+	}
+
+	if err := repo.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got := cachedCost(t, repo, today); got != 2 {
+		t.Errorf("expected the cache recomputed to 2, got %d", got)
+	}
+
+	if err := repo.Delete(ctx, kept.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	rows, err := repo.GetDailyCache(ctx, today)
+	if err != nil {
+		t.Fatalf("GetDailyCache: %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("expected the emptied day removed from the cache, got %+v", rows)
+	}
+}
+
+func TestUsageRepositorySummarizeDailyCache(t *testing.T) {
+	ctx := context.Background()
+	_, repo, account := setupUsageCacheDB(t)
+
+	other := &models.Account{Provider: models.ProviderOpenAI, ProfileName: "other"}
+	if err := NewAccountRepository(repo.db).Create(ctx, other); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, r := range []*models.UsageRecord{
+		{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 10, CostCents: 1, RecordedAt: day},
+		{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 20, CostCents: 2, RecordedAt: day.AddDate(0, 0, 1)},
+		{AccountID: other.ID, Provider: models.ProviderOpenAI, InputTokens: 40, CostCents: 4, RecordedAt: day.AddDate(0, 0, 2)},
+	} {
+		if err := repo.Create(ctx, r); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if _, err := repo.RefreshDailyCache(ctx, time.Now()); err != nil {
+		t.Fatalf("RefreshDailyCache: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		accountID string
+		provider  models.Provider
+		from, to  string
+		wantCost  int64
+		wantCount int64
+	}{
+		{name: "all days", to: "2026-03-13", wantCost: 7, wantCount: 3},
+		{name: "bounded", from: "2026-03-11", to: "2026-03-12", wantCost: 2, wantCount: 1},
+		{name: "account", accountID: account.ID, to: "2026-03-13", wantCost: 3, wantCount: 2},
+		{name: "provider", provider: models.ProviderOpenAI, to: "2026-03-13", wantCost: 4, wantCount: 1},
+		{name: "until exclusive", to: "2026-03-10", wantCost: 0, wantCount: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := repo.SummarizeDailyCache(ctx, tt.accountID, tt.provider, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("SummarizeDailyCache: %v", err)
+			}
+			if summary.TotalCostCents != tt.wantCost || summary.RecordCount != tt.wantCount {
+				t.Errorf("expected cost %d over %d records, got %+v", tt.wantCost, tt.wantCount, summary)
+			}
+		})
+	}
+
+	deleted, err := repo.DeleteDailyCacheBefore(ctx, "2026-03-12")
+	if err != nil {
+		t.Fatalf("DeleteDailyCacheBefore: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 cache rows deleted, got %d", deleted)
+	}
+	if got := cachedCost(t, repo, "2026-03-12"); got != 4 {
+		t.Errorf("expected the later day kept, got %d", got)
+	}
+}
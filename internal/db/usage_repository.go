@@ -32,8 +32,28 @@ func NewUsageRepository(db *DB) *UsageRepository {
 
 // Create inserts a new usage record.
 func (r *UsageRepository) Create(ctx context.Context, record *models.UsageRecord) error {
+	_, err := r.insert(ctx, record, "")
+	return err
+}
+
+// CreateIfAbsent inserts a usage record unless one with the same ID already
+// exists, reporting whether it was inserted. Importers give records
+// deterministic IDs so that loading the same data twice is a no-op.
+func (r *UsageRepository) CreateIfAbsent(ctx context.Context, record *models.UsageRecord) (bool, error) {
+	if record.ID == "" {
+		return false, ErrInvalidUsageRecord
+	}
+	return r.insert(ctx, record, "ON CONFLICT(id) DO NOTHING")
+}
+
+func (r *UsageRepository) insert(ctx context.Context, record *models.UsageRecord, onConflict string) (bool, error) {
 	if record.AccountID == "" || record.Provider == "" {
-		return ErrInvalidUsageRecord
+		return false, ErrInvalidUsageRecord
+	}
+	record.Provider = models.NormalizeProvider(record.Provider)
+	record.ProviderName = models.NormalizeProviderName(record.ProviderName)
+	if err := record.Validate(); err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidUsageRecord, err)
 	}
 
 	if record.ID == "" {
@@ -53,19 +73,19 @@ func (r *UsageRepository) Create(ctx context.Context, record *models.UsageRecord
 	if record.Metadata != nil {
 		data, err := json.Marshal(record.Metadata)
 		if err != nil {
-			return fmt.Errorf("failed to marshal metadata: %w", err)
+			return false, fmt.Errorf("failed to marshal metadata: %w", err)
 		}
 		s := string(data)
 		metadataJSON = &s
 	}
 
-	_, err := r.db.ExecContext(ctx, `
+	result, err := r.db.ExecContext(ctx, `
 		INSERT INTO usage_records (
 			id, account_id, agent_id, session_id, provider, model,
 			input_tokens, output_tokens, total_tokens, cost_cents,
-			request_count, recorded_at, metadata_json
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`,
+			request_count, recorded_at, metadata_json, provider_name, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`+onConflict,
 		record.ID,
 		record.AccountID,
 		nullString(record.AgentID),
@@ -79,12 +99,18 @@ func (r *UsageRepository) Create(ctx context.Context, record *models.UsageRecord
 		record.RequestCount,
 		record.RecordedAt.UTC().Format(time.RFC3339),
 		metadataJSON,
+		nullString(record.ProviderName),
+		time.Now().UTC().Format(time.RFC3339),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to insert usage record: %w", err)
+		return false, fmt.Errorf("failed to insert usage record: %w", err)
 	}
 
-	return nil
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get inserted count: %w", err)
+	}
+	return inserted > 0, nil
 }
 
 // Get retrieves a usage record by ID.
@@ -92,7 +118,7 @@ func (r *UsageRepository) Get(ctx context.Context, id string) (*models.UsageReco
 	row := r.db.QueryRowContext(ctx, `
 		SELECT id, account_id, agent_id, session_id, provider, model,
 			input_tokens, output_tokens, total_tokens, cost_cents,
-			request_count, recorded_at, metadata_json
+			request_count, recorded_at, metadata_json, provider_name
 		FROM usage_records WHERE id = ?
 	`, id)
 
@@ -106,10 +132,39 @@ func (r *UsageRepository) Query(ctx context.Context, q models.UsageQuery) ([]*mo
 		limit = 100
 	}
 
-	query := `SELECT id, account_id, agent_id, session_id, provider, model,
+	query, args := usageQuerySQL(q)
+	query += ` ORDER BY recorded_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*models.UsageRecord
+	for rows.Next() {
+		record, err := r.scanUsageRecordFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage records: %w", err)
+	}
+
+	return records, nil
+}
+
+const usageSelectColumns = `SELECT id, account_id, agent_id, session_id, provider, model,
 		input_tokens, output_tokens, total_tokens, cost_cents,
-		request_count, recorded_at, metadata_json
+		request_count, recorded_at, metadata_json, provider_name
 		FROM usage_records WHERE 1=1`
+
+// usageQuerySQL builds the filtered usage select without ordering or limit.
+func usageQuerySQL(q models.UsageQuery) (string, []any) {
+	query := usageSelectColumns
 	args := []any{}
 
 	if q.AccountID != nil {
@@ -141,44 +196,131 @@ func (r *UsageRepository) Query(ctx context.Context, q models.UsageQuery) ([]*mo
 		args = append(args, q.Until.UTC().Format(time.RFC3339))
 	}
 
-	query += ` ORDER BY recorded_at DESC LIMIT ?`
-	args = append(args, limit)
+	return query, args
+}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+// UsageIterator yields usage records newest first, holding only the
+// current batch in memory.
+type UsageIterator struct {
+	ctx       context.Context
+	repo      *UsageRepository
+	query     models.UsageQuery
+	batchSize int
+	remaining int
+	batch     []*models.UsageRecord
+	pos       int
+	current   *models.UsageRecord
+	last      *models.UsageRecord
+	done      bool
+	err       error
+}
+
+// Iterate returns an iterator over usage records matching q, newest first.
+// Rows are fetched batchSize at a time; q.Limit caps the total (0 = all).
+func (r *UsageRepository) Iterate(ctx context.Context, q models.UsageQuery, batchSize int) *UsageIterator {
+	if batchSize <= 0 {
+		batchSize = DefaultIterateBatchSize
+	}
+	return &UsageIterator{ctx: ctx, repo: r, query: q, batchSize: batchSize, remaining: q.Limit}
+}
+
+// Next advances to the next record, fetching a new batch when needed.
+func (it *UsageIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos >= len(it.batch) {
+		if it.done {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+		if len(it.batch) == 0 {
+			return false
+		}
+	}
+	it.current = it.batch[it.pos]
+	it.batch[it.pos] = nil
+	it.pos++
+	it.last = it.current
+	if it.remaining > 0 {
+		it.remaining--
+		if it.remaining == 0 {
+			it.done, it.batch = true, nil
+		}
+	}
+	return true
+}
+
+// fetch loads the batch after the last record returned, keyed on
+// (recorded_at, id) so equal timestamps are neither skipped nor repeated.
+func (it *UsageIterator) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err
+	}
+	query, args := usageQuerySQL(it.query)
+	if it.last != nil {
+		query += ` AND (recorded_at, id) < (?, ?)`
+		args = append(args, it.last.RecordedAt.UTC().Format(time.RFC3339), it.last.ID)
+	}
+	query += ` ORDER BY recorded_at DESC, id DESC LIMIT ?`
+	args = append(args, it.batchSize)
+
+	rows, err := it.repo.db.QueryContext(it.ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query usage records: %w", err)
+		return fmt.Errorf("failed to query usage records: %w", err)
 	}
 	defer rows.Close()
 
-	var records []*models.UsageRecord
+	batch := make([]*models.UsageRecord, 0, it.batchSize)
 	for rows.Next() {
-		record, err := r.scanUsageRecordFromRows(rows)
+		record, err := it.repo.scanUsageRecordFromRows(rows)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		records = append(records, record)
+		batch = append(batch, record)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating usage records: %w", err)
+		return fmt.Errorf("error iterating usage records: %w", err)
 	}
 
-	return records, nil
+	it.batch, it.pos = batch, 0
+	it.done = len(batch) < it.batchSize
+	return nil
+}
+
+// Record returns the record at the current position.
+func (it *UsageIterator) Record() *models.UsageRecord {
+	return it.current
 }
 
-// Delete removes a usage record by ID.
+// Err returns the first error hit while iterating.
+func (it *UsageIterator) Err() error {
+	return it.err
+}
+
+// Delete removes a usage record by ID and recomputes the daily usage
+// cache row it counted toward.
 func (r *UsageRepository) Delete(ctx context.Context, id string) error {
-	result, err := r.db.ExecContext(ctx, `DELETE FROM usage_records WHERE id = ?`, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete usage record: %w", err)
-	}
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-	if affected == 0 {
-		return ErrUsageRecordNotFound
-	}
-	return nil
+	return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		var accountID, date, provider string
+		err := tx.QueryRowContext(ctx, `
+			SELECT account_id, date(recorded_at), provider FROM usage_records WHERE id = ?
+		`, id).Scan(&accountID, &date, &provider)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUsageRecordNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get usage record: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM usage_records WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete usage record: %w", err)
+		}
+		return recomputeDailyCache(ctx, tx, accountID, date, models.Provider(provider))
+	})
 }
 
 // SummarizeByAccount returns aggregated usage for an account.
@@ -273,6 +415,54 @@ func (r *UsageRepository) SummarizeByProvider(ctx context.Context, provider mode
 	return &summary, nil
 }
 
+// SummarizeByWorkspace returns aggregated usage recorded by the agents of a
+// workspace, including agents that have been terminated. Records without an
+// agent, or whose agent has been pruned, are not attributed to any workspace.
+func (r *UsageRepository) SummarizeByWorkspace(ctx context.Context, workspaceID string, since, until *time.Time) (*models.UsageSummary, error) {
+	query := `SELECT 
+		COALESCE(SUM(input_tokens), 0) as input_tokens,
+		COALESCE(SUM(output_tokens), 0) as output_tokens,
+		COALESCE(SUM(total_tokens), 0) as total_tokens,
+		COALESCE(SUM(cost_cents), 0) as cost_cents,
+		COALESCE(SUM(request_count), 0) as request_count,
+		COUNT(*) as record_count
+		FROM usage_records
+		WHERE agent_id IN (SELECT id FROM agents WHERE workspace_id = ?)`
+	args := []any{workspaceID}
+
+	if since != nil {
+		query += ` AND recorded_at >= ?`
+		args = append(args, since.UTC().Format(time.RFC3339))
+	}
+	if until != nil {
+		query += ` AND recorded_at < ?`
+		args = append(args, until.UTC().Format(time.RFC3339))
+	}
+
+	var summary models.UsageSummary
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&summary.InputTokens,
+		&summary.OutputTokens,
+		&summary.TotalTokens,
+		&summary.TotalCostCents,
+		&summary.RequestCount,
+		&summary.RecordCount,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize usage: %w", err)
+	}
+
+	summary.Period = "custom"
+	if since != nil {
+		summary.PeriodStart = *since
+	}
+	if until != nil {
+		summary.PeriodEnd = *until
+	}
+
+	return &summary, nil
+}
+
 // SummarizeAll returns aggregated usage for all accounts.
 func (r *UsageRepository) SummarizeAll(ctx context.Context, since, until *time.Time) (*models.UsageSummary, error) {
 	query := `SELECT 
@@ -464,6 +654,126 @@ func (r *UsageRepository) UpdateDailyCache(ctx context.Context, accountID, date
 	return nil
 }
 
+// GetDailyCache returns the cached daily usage rows for a date (YYYY-MM-DD)
+// across all accounts.
+func (r *UsageRepository) GetDailyCache(ctx context.Context, date string) ([]*models.DailyUsage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			account_id, date, provider,
+			input_tokens, output_tokens, total_tokens,
+			cost_cents, request_count
+		FROM daily_usage_cache
+		WHERE date = ?
+		ORDER BY account_id, provider
+	`, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily cache: %w", err)
+	}
+	defer rows.Close()
+
+	var dailyUsage []*models.DailyUsage
+	for rows.Next() {
+		var du models.DailyUsage
+		var provider string
+		if err := rows.Scan(
+			&du.AccountID,
+			&du.Date,
+			&provider,
+			&du.InputTokens,
+			&du.OutputTokens,
+			&du.TotalTokens,
+			&du.CostCents,
+			&du.RequestCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan daily cache: %w", err)
+		}
+		du.Provider = models.Provider(provider)
+		dailyUsage = append(dailyUsage, &du)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily cache: %w", err)
+	}
+
+	return dailyUsage, nil
+}
+
+// UpsertAgentDailyCosts saves agents' final figures for a day from the cost
+// ticker, replacing any saved for the same agent and day.
+func (r *UsageRepository) UpsertAgentDailyCosts(ctx context.Context, costs []models.AgentCost) error {
+	if len(costs) == 0 {
+		return nil
+	}
+	return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		for _, cost := range costs {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO agent_daily_cost_cache (
+					agent_id, date, agent_type, actual_cents,
+					estimated_cents, estimated_working_seconds, cost_per_hour_cents, updated_at
+				) VALUES (?, ?, ?, ?, ?, ?, ?, datetime('now'))
+				ON CONFLICT(agent_id, date) DO UPDATE SET
+					agent_type = excluded.agent_type,
+					actual_cents = excluded.actual_cents,
+					estimated_cents = excluded.estimated_cents,
+					estimated_working_seconds = excluded.estimated_working_seconds,
+					cost_per_hour_cents = excluded.cost_per_hour_cents,
+					updated_at = excluded.updated_at
+			`,
+				cost.AgentID,
+				cost.Date,
+				nullString(string(cost.AgentType)),
+				cost.ActualCents,
+				cost.Estimated.Cents,
+				cost.Estimated.WorkingSeconds,
+				cost.Estimated.CostPerHourCents,
+			); err != nil {
+				return fmt.Errorf("failed to save daily cost for %s: %w", cost.AgentID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetAgentDailyCosts returns the saved agent figures for a date
+// (YYYY-MM-DD), by agent ID.
+func (r *UsageRepository) GetAgentDailyCosts(ctx context.Context, date string) ([]models.AgentCost, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT agent_id, date, agent_type, actual_cents,
+			estimated_cents, estimated_working_seconds, cost_per_hour_cents
+		FROM agent_daily_cost_cache
+		WHERE date = ?
+		ORDER BY agent_id
+	`, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent daily costs: %w", err)
+	}
+	defer rows.Close()
+
+	var costs []models.AgentCost
+	for rows.Next() {
+		var (
+			cost      models.AgentCost
+			agentType sql.NullString
+		)
+		if err := rows.Scan(
+			&cost.AgentID,
+			&cost.Date,
+			&agentType,
+			&cost.ActualCents,
+			&cost.Estimated.Cents,
+			&cost.Estimated.WorkingSeconds,
+			&cost.Estimated.CostPerHourCents,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan agent daily cost: %w", err)
+		}
+		cost.AgentType = models.AgentType(agentType.String)
+		costs = append(costs, cost)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating agent daily costs: %w", err)
+	}
+	return costs, nil
+}
+
 // DeleteOlderThan removes usage records older than the given time.
 func (r *UsageRepository) DeleteOlderThan(ctx context.Context, before time.Time, limit int) (int64, error) {
 	if limit <= 0 {
@@ -488,7 +798,7 @@ func (r *UsageRepository) DeleteOlderThan(ctx context.Context, before time.Time,
 
 func (r *UsageRepository) scanUsageRecord(row *sql.Row) (*models.UsageRecord, error) {
 	var record models.UsageRecord
-	var agentID, sessionID, model, metadataJSON sql.NullString
+	var agentID, sessionID, model, metadataJSON, providerName sql.NullString
 	var provider, recordedAt string
 
 	err := row.Scan(
@@ -505,6 +815,7 @@ func (r *UsageRepository) scanUsageRecord(row *sql.Row) (*models.UsageRecord, er
 		&record.RequestCount,
 		&recordedAt,
 		&metadataJSON,
+		&providerName,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -514,6 +825,7 @@ func (r *UsageRepository) scanUsageRecord(row *sql.Row) (*models.UsageRecord, er
 	}
 
 	record.Provider = models.Provider(provider)
+	record.ProviderName = providerName.String
 	if agentID.Valid {
 		record.AgentID = agentID.String
 	}
@@ -537,7 +849,7 @@ func (r *UsageRepository) scanUsageRecord(row *sql.Row) (*models.UsageRecord, er
 
 func (r *UsageRepository) scanUsageRecordFromRows(rows *sql.Rows) (*models.UsageRecord, error) {
 	var record models.UsageRecord
-	var agentID, sessionID, model, metadataJSON sql.NullString
+	var agentID, sessionID, model, metadataJSON, providerName sql.NullString
 	var provider, recordedAt string
 
 	if err := rows.Scan(
@@ -554,11 +866,13 @@ func (r *UsageRepository) scanUsageRecordFromRows(rows *sql.Rows) (*models.Usage
 		&record.RequestCount,
 		&recordedAt,
 		&metadataJSON,
+		&providerName,
 	); err != nil {
 		return nil, fmt.Errorf("failed to scan usage record: %w", err)
 	}
 
 	record.Provider = models.Provider(provider)
+	record.ProviderName = providerName.String
 	if agentID.Valid {
 		record.AgentID = agentID.String
 	}
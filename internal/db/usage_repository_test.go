@@ -255,6 +255,68 @@ func TestUsageRepositorySummarizeAll(t *testing.T) {
 	}
 }
 
+func TestUsageRepositorySummarizeByWorkspace(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	ws := createTestWorkspace(t, database)
+	agent := createTestAgent(t, database, ws)
+
+	accountRepo := NewAccountRepository(database)
+	account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "test"}
+	if err := accountRepo.Create(ctx, account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	repo := NewUsageRepository(database)
+	now := time.Now().UTC()
+	records := []*models.UsageRecord{
+		{AccountID: account.ID, AgentID: agent.ID, Provider: models.ProviderAnthropic, CostCents: 10, RecordedAt: now},
+		{AccountID: account.ID, AgentID: agent.ID, Provider: models.ProviderAnthropic, CostCents: 7, RecordedAt: now},
+		{AccountID: account.ID, AgentID: agent.ID, Provider: models.ProviderAnthropic, CostCents: 40, RecordedAt: now.Add(-48 * time.Hour)},
+		{AccountID: account.ID, Provider: models.ProviderAnthropic, CostCents: 100, RecordedAt: now},
+	}
+	for _, r := range records {
+		if err := repo.Create(ctx, r); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	// Terminated agents keep their usage attributed to the workspace.
+	if err := NewAgentRepository(database).Delete(ctx, agent.ID); err != nil {
+		t.Fatalf("Delete agent: %v", err)
+	}
+
+	since := now.Add(-time.Hour)
+	summary, err := repo.SummarizeByWorkspace(ctx, ws.ID, &since, nil)
+	if err != nil {
+		t.Fatalf("SummarizeByWorkspace: %v", err)
+	}
+	if summary.TotalCostCents != 17 {
+		t.Errorf("expected TotalCostCents 17, got %d", summary.TotalCostCents)
+	}
+	if summary.RecordCount != 2 {
+		t.Errorf("expected RecordCount 2, got %d", summary.RecordCount)
+	}
+
+	summary, err = repo.SummarizeByWorkspace(ctx, "other", nil, nil)
+	if err != nil {
+		t.Fatalf("SummarizeByWorkspace: %v", err)
+	}
+	if summary.RecordCount != 0 {
+		t.Errorf("expected no records for another workspace, got %d", summary.RecordCount)
+	}
+}
+
 func TestUsageRepositoryGetDailyUsage(t *testing.T) {
 	ctx := context.Background()
 
@@ -314,6 +376,68 @@ func TestUsageRepositoryGetDailyUsage(t *testing.T) {
 	}
 }
 
+func TestUsageRepositoryDailyCache(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	accountRepo := NewAccountRepository(database)
+	account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "test"}
+	if err := accountRepo.Create(ctx, account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	repo := NewUsageRepository(database)
+
+	now := time.Now().UTC()
+	records := []*models.UsageRecord{
+		{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 100, OutputTokens: 50, CostCents: 4, RecordedAt: now},
+		{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 200, CostCents: 6, RecordedAt: now},
+		{AccountID: account.ID, Provider: models.ProviderAnthropic, InputTokens: 900, RecordedAt: now.Add(-48 * time.Hour)},
+	}
+	for _, r := range records {
+		if err := repo.Create(ctx, r); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	today := now.Format("2006-01-02")
+	if err := repo.UpdateDailyCache(ctx, account.ID, today, models.ProviderAnthropic); err != nil {
+		t.Fatalf("UpdateDailyCache: %v", err)
+	}
+
+	cached, err := repo.GetDailyCache(ctx, today)
+	if err != nil {
+		t.Fatalf("GetDailyCache: %v", err)
+	}
+	if len(cached) != 1 {
+		t.Fatalf("expected 1 cached row, got %d", len(cached))
+	}
+	got := cached[0]
+	if got.AccountID != account.ID || got.Date != today || got.Provider != models.ProviderAnthropic {
+		t.Errorf("unexpected cache key: %+v", got)
+	}
+	if got.TotalTokens != 350 || got.CostCents != 10 || got.RequestCount != 2 {
+		t.Errorf("unexpected cache totals: %+v", got)
+	}
+
+	empty, err := repo.GetDailyCache(ctx, now.Add(-24*time.Hour).Format("2006-01-02"))
+	if err != nil {
+		t.Fatalf("GetDailyCache: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Errorf("expected no cached rows for an uncached day, got %d", len(empty))
+	}
+}
+
 func TestUsageRepositoryGetTopAccountsByUsage(t *testing.T) {
 	ctx := context.Background()
 
@@ -412,6 +536,52 @@ func TestUsageRepositoryDelete(t *testing.T) {
 	}
 }
 
+func TestUsageRepositoryCreateIfAbsent(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	accountRepo := NewAccountRepository(database)
+	account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "test"}
+	if err := accountRepo.Create(ctx, account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	repo := NewUsageRepository(database)
+
+	if _, err := repo.CreateIfAbsent(ctx, &models.UsageRecord{AccountID: account.ID, Provider: models.ProviderAnthropic}); err != ErrInvalidUsageRecord {
+		t.Fatalf("expected an ID to be required, got %v", err)
+	}
+
+	record := &models.UsageRecord{ID: "export-row-1", AccountID: account.ID, Provider: models.ProviderAnthropic, CostCents: 40}
+	inserted, err := repo.CreateIfAbsent(ctx, record)
+	if err != nil || !inserted {
+		t.Fatalf("expected the first insert to succeed, got %v (%v)", inserted, err)
+	}
+
+	again := &models.UsageRecord{ID: "export-row-1", AccountID: account.ID, Provider: models.ProviderAnthropic, CostCents: 99}
+	inserted, err = repo.CreateIfAbsent(ctx, again)
+	if err != nil || inserted {
+		t.Fatalf("expected the duplicate to be skipped, got %v (%v)", inserted, err)
+	}
+
+	stored, err := repo.Get(ctx, "export-row-1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if stored.CostCents != 40 {
+		t.Errorf("expected the original record kept, got cost %d", stored.CostCents)
+	}
+}
+
 func TestUsageRepositoryDeleteOlderThan(t *testing.T) {
 	ctx := context.Background()
 
@@ -522,3 +692,66 @@ func TestUsageRecordValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestUsageRepositoryIterate(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := database.MigrateUp(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	account := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "account1"}
+	if err := NewAccountRepository(database).Create(ctx, account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	repo := NewUsageRepository(database)
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := 0; i < 7; i++ {
+		record := &models.UsageRecord{
+			AccountID:   account.ID,
+			Provider:    models.ProviderAnthropic,
+			InputTokens: int64(i),
+			RecordedAt:  now.Add(-time.Duration(i/2) * time.Minute),
+		}
+		if err := repo.Create(ctx, record); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	it := repo.Iterate(ctx, models.UsageQuery{AccountID: &account.ID}, 2)
+	seen := make(map[string]bool)
+	var previous time.Time
+	for it.Next() {
+		record := it.Record()
+		if seen[record.ID] {
+			t.Fatalf("record %s yielded twice", record.ID)
+		}
+		if !previous.IsZero() && record.RecordedAt.After(previous) {
+			t.Fatalf("expected newest first, got %v after %v", record.RecordedAt, previous)
+		}
+		seen[record.ID] = true
+		previous = record.RecordedAt
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterate: %v", err)
+	}
+	if len(seen) != 7 {
+		t.Fatalf("expected 7 records across batches, got %d", len(seen))
+	}
+
+	limited := repo.Iterate(ctx, models.UsageQuery{Limit: 3}, 2)
+	count := 0
+	for limited.Next() {
+		count++
+	}
+	if count != 3 {
+		t.Fatalf("expected limit to cap iteration at 3, got %d", count)
+	}
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Workspace queue repository errors.
+var (
+	ErrWorkspaceQueueItemNotFound = errors.New("workspace queue item not found")
+	ErrWorkspaceQueueItemAssigned = errors.New("workspace queue item already assigned")
+)
+
+// WorkspaceQueueRepository handles workspace-level queue persistence.
+type WorkspaceQueueRepository struct {
+	db *DB
+}
+
+// NewWorkspaceQueueRepository creates a new WorkspaceQueueRepository.
+func NewWorkspaceQueueRepository(db *DB) *WorkspaceQueueRepository {
+	return &WorkspaceQueueRepository{db: db}
+}
+
+const workspaceQueueColumns = `
+	id, workspace_id, agent_type, type, payload_json, status,
+	agent_id, queue_item_id, created_at, assigned_at`
+
+// Enqueue adds an item to its workspace's queue.
+func (r *WorkspaceQueueRepository) Enqueue(ctx context.Context, item *models.WorkspaceQueueItem) error {
+	if err := item.Validate(); err != nil {
+		return fmt.Errorf("invalid workspace queue item: %w", err)
+	}
+
+	if item.ID == "" {
+		item.ID = uuid.New().String()
+	}
+	item.Status = models.WorkspaceQueueItemStatusPending
+	item.CreatedAt = time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO workspace_queue_items (`+workspaceQueueColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, NULL, NULL, ?, NULL)
+	`,
+		item.ID,
+		item.WorkspaceID,
+		string(item.AgentType),
+		string(item.Type),
+		string(item.Payload),
+		string(item.Status),
+		item.CreatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert workspace queue item: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a workspace queue item by ID.
+func (r *WorkspaceQueueRepository) Get(ctx context.Context, id string) (*models.WorkspaceQueueItem, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+workspaceQueueColumns+` FROM workspace_queue_items WHERE id = ?`, id)
+	item, err := scanWorkspaceQueueItem(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkspaceQueueItemNotFound
+		}
+		return nil, fmt.Errorf("failed to scan workspace queue item: %w", err)
+	}
+	return item, nil
+}
+
+// ListPending returns pending items across all workspaces, oldest first.
+func (r *WorkspaceQueueRepository) ListPending(ctx context.Context) ([]*models.WorkspaceQueueItem, error) {
+	return r.query(ctx, `
+		SELECT `+workspaceQueueColumns+` FROM workspace_queue_items
+		WHERE status = ?
+		ORDER BY created_at, rowid
+	`, string(models.WorkspaceQueueItemStatusPending))
+}
+
+// ListByWorkspace returns all items queued for a workspace, oldest first.
+func (r *WorkspaceQueueRepository) ListByWorkspace(ctx context.Context, workspaceID string) ([]*models.WorkspaceQueueItem, error) {
+	return r.query(ctx, `
+		SELECT `+workspaceQueueColumns+` FROM workspace_queue_items
+		WHERE workspace_id = ?
+		ORDER BY created_at, rowid
+	`, workspaceID)
+}
+
+// Assign moves a pending item to the end of an agent's queue in one
+// transaction and returns the queue item created for it. The item is only
+// taken while still pending, so it is assigned at most once;
+// ErrWorkspaceQueueItemAssigned is returned when another caller got there
+// first.
+func (r *WorkspaceQueueRepository) Assign(ctx context.Context, id, agentID string) (*models.QueueItem, error) {
+	item, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != models.WorkspaceQueueItemStatusPending {
+		return nil, ErrWorkspaceQueueItemAssigned
+	}
+
+	queued := item.QueueItem(agentID)
+	queued.ID = uuid.New().String()
+
+	err = r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		result, err := tx.ExecContext(ctx, `
+			UPDATE workspace_queue_items
+			SET status = ?, agent_id = ?, queue_item_id = ?, assigned_at = ?
+			WHERE id = ? AND status = ?
+		`,
+			string(models.WorkspaceQueueItemStatusAssigned),
+			agentID,
+			queued.ID,
+			now.Format(time.RFC3339),
+			id,
+			string(models.WorkspaceQueueItemStatusPending),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to assign workspace queue item: %w", err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rows == 0 {
+			return ErrWorkspaceQueueItemAssigned
+		}
+
+		var maxPos sql.NullInt64
+		if err := tx.QueryRowContext(ctx, `
+			SELECT MAX(position) FROM queue_items WHERE agent_id = ?
+		`, agentID).Scan(&maxPos); err != nil {
+			return fmt.Errorf("failed to get max position: %w", err)
+		}
+		queued.Position = int(maxPos.Int64) + 1
+		queued.CreatedAt = now
+		return writeQueueItem(ctx, tx, queued)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return queued, nil
+}
+
+func (r *WorkspaceQueueRepository) query(ctx context.Context, query string, args ...any) ([]*models.WorkspaceQueueItem, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace queue items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*models.WorkspaceQueueItem
+	for rows.Next() {
+		item, err := scanWorkspaceQueueItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workspace queue item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workspace queue items: %w", err)
+	}
+	return items, nil
+}
+
+func scanWorkspaceQueueItem(row interface{ Scan(...any) error }) (*models.WorkspaceQueueItem, error) {
+	var item models.WorkspaceQueueItem
+	var agentType, itemType, payload, status, createdAt string
+	var agentID, queueItemID, assignedAt sql.NullString
+
+	if err := row.Scan(
+		&item.ID,
+		&item.WorkspaceID,
+		&agentType,
+		&itemType,
+		&payload,
+		&status,
+		&agentID,
+		&queueItemID,
+		&createdAt,
+		&assignedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	item.AgentType = models.AgentType(agentType)
+	item.Type = models.QueueItemType(itemType)
+	item.Payload = []byte(payload)
+	item.Status = models.WorkspaceQueueItemStatus(status)
+	item.AgentID = agentID.String
+	item.QueueItemID = queueItemID.String
+	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+		item.CreatedAt = t
+	}
+	if assignedAt.Valid {
+		if t, err := time.Parse(time.RFC3339, assignedAt.String); err == nil {
+			item.AssignedAt = &t
+		}
+	}
+	return &item, nil
+}
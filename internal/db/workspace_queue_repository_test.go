@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func newWorkspaceQueueItem(t *testing.T, ws *models.Workspace, text string) *models.WorkspaceQueueItem {
+	t.Helper()
+
+	payload, err := json.Marshal(models.MessagePayload{Text: text})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return &models.WorkspaceQueueItem{
+		WorkspaceID: ws.ID,
+		Type:        models.QueueItemTypeMessage,
+		Payload:     payload,
+	}
+}
+
+func TestWorkspaceQueueRepository_EnqueueAndListPending(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewWorkspaceQueueRepository(db)
+	ws := createTestWorkspace(t, db)
+
+	var ids []string
+	for _, text := range []string{"first", "second", "third"} {
+		item := newWorkspaceQueueItem(t, ws, text)
+		if err := repo.Enqueue(ctx, item); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+
+	pending, err := repo.ListPending(ctx)
+	if err != nil {
+		t.Fatalf("ListPending: %v", err)
+	}
+	if len(pending) != len(ids) {
+		t.Fatalf("expected %d pending items, got %d", len(ids), len(pending))
+	}
+	for i, item := range pending {
+		if item.ID != ids[i] {
+			t.Fatalf("pending[%d] = %s, want %s", i, item.ID, ids[i])
+		}
+		if item.Status != models.WorkspaceQueueItemStatusPending {
+			t.Fatalf("expected pending status, got %s", item.Status)
+		}
+	}
+
+	if err := repo.Enqueue(ctx, &models.WorkspaceQueueItem{Type: models.QueueItemTypeMessage}); err == nil {
+		t.Fatal("expected an item without a workspace to be rejected")
+	}
+}
+
+func TestWorkspaceQueueRepository_Assign(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewWorkspaceQueueRepository(db)
+	queueRepo := NewQueueRepository(db)
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+	insertQueueItem(t, db, agent.ID, models.QueueItemStatusPending, 4)
+
+	item := newWorkspaceQueueItem(t, ws, "hello")
+	if err := repo.Enqueue(ctx, item); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	queued, err := repo.Assign(ctx, item.ID, agent.ID)
+	if err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	if queued.Position != 5 || queued.AgentID != agent.ID {
+		t.Fatalf("expected item at position 5 for %s, got %d for %s", agent.ID, queued.Position, queued.AgentID)
+	}
+	stored, err := queueRepo.Get(ctx, queued.ID)
+	if err != nil {
+		t.Fatalf("Get queue item: %v", err)
+	}
+	if stored.Type != models.QueueItemTypeMessage || string(stored.Payload) != string(item.Payload) {
+		t.Fatalf("queue item does not match workspace item: %+v", stored)
+	}
+
+	got, err := repo.Get(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Status != models.WorkspaceQueueItemStatusAssigned || got.AgentID != agent.ID || got.QueueItemID != queued.ID || got.AssignedAt == nil {
+		t.Fatalf("expected item to record its assignment, got %+v", got)
+	}
+	if _, err := repo.Assign(ctx, item.ID, agent.ID); err != ErrWorkspaceQueueItemAssigned {
+		t.Fatalf("expected ErrWorkspaceQueueItemAssigned, got %v", err)
+	}
+	pending, err := repo.ListPending(ctx)
+	if err != nil {
+		t.Fatalf("ListPending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending items, got %d", len(pending))
+	}
+}
+
+func TestWorkspaceQueueRepository_AssignRace(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewWorkspaceQueueRepository(db)
+	ws := createTestWorkspace(t, db)
+	agent := createTestAgent(t, db, ws)
+
+	item := newWorkspaceQueueItem(t, ws, "hello")
+	if err := repo.Enqueue(ctx, item); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	const assigners = 8
+	var wg sync.WaitGroup
+	results := make(chan error, assigners)
+	for i := 0; i < assigners; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Assign(ctx, item.ID, agent.ID)
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var won int
+	for err := range results {
+		switch err {
+		case nil:
+			won++
+		case ErrWorkspaceQueueItemAssigned:
+		default:
+			t.Fatalf("unexpected assign error: %v", err)
+		}
+	}
+	if won != 1 {
+		t.Fatalf("expected exactly one assignment, got %d", won)
+	}
+	var count int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM queue_items WHERE agent_id = ?`, agent.ID).Scan(&count); err != nil {
+		t.Fatalf("count queue items: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected one queue item, got %d", count)
+	}
+}
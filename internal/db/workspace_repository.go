@@ -61,11 +61,21 @@ func (r *WorkspaceRepository) Create(ctx context.Context, workspace *models.Work
 		gitInfoJSON = &s
 	}
 
+	var placementJSON *string
+	if workspace.Placement != nil {
+		data, err := json.Marshal(workspace.Placement)
+		if err != nil {
+			return fmt.Errorf("failed to marshal placement: %w", err)
+		}
+		s := string(data)
+		placementJSON = &s
+	}
+
 	_, err := r.db.ExecContext(ctx, `
 		INSERT INTO workspaces (
 			id, name, node_id, repo_path, tmux_session, status,
-			git_info_json, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			git_info_json, placement_json, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		workspace.ID,
 		workspace.Name,
@@ -74,6 +84,7 @@ func (r *WorkspaceRepository) Create(ctx context.Context, workspace *models.Work
 		workspace.TmuxSession,
 		string(workspace.Status),
 		gitInfoJSON,
+		placementJSON,
 		workspace.CreatedAt.Format(time.RFC3339),
 		workspace.UpdatedAt.Format(time.RFC3339),
 	)
@@ -93,20 +104,21 @@ func (r *WorkspaceRepository) Get(ctx context.Context, id string) (*models.Works
 	row := r.db.QueryRowContext(ctx, `
 		SELECT 
 			id, name, node_id, repo_path, tmux_session, status,
-			git_info_json, created_at, updated_at
+			git_info_json, placement_json, pause_json, draining, quiet_hours, hibernate_after_seconds, bootstrap_json, created_at, updated_at
 		FROM workspaces WHERE id = ?
 	`, id)
 
 	return r.scanWorkspace(row)
 }
 
-// GetByNodeAndPath retrieves a workspace by node ID and repo path.
+// GetByNodeAndPath retrieves a workspace by node ID and repo path. A
+// trailing slash on either path is ignored, matching the uniqueness index.
 func (r *WorkspaceRepository) GetByNodeAndPath(ctx context.Context, nodeID, repoPath string) (*models.Workspace, error) {
 	row := r.db.QueryRowContext(ctx, `
 		SELECT 
 			id, name, node_id, repo_path, tmux_session, status,
-			git_info_json, created_at, updated_at
-		FROM workspaces WHERE node_id = ? AND repo_path = ?
+			git_info_json, placement_json, pause_json, draining, quiet_hours, hibernate_after_seconds, bootstrap_json, created_at, updated_at
+		FROM workspaces WHERE node_id = ? AND rtrim(repo_path, '/') = rtrim(?, '/')
 	`, nodeID, repoPath)
 
 	return r.scanWorkspace(row)
@@ -117,7 +129,7 @@ func (r *WorkspaceRepository) GetByTmuxSession(ctx context.Context, nodeID, sess
 	row := r.db.QueryRowContext(ctx, `
 		SELECT 
 			id, name, node_id, repo_path, tmux_session, status,
-			git_info_json, created_at, updated_at
+			git_info_json, placement_json, pause_json, draining, quiet_hours, hibernate_after_seconds, bootstrap_json, created_at, updated_at
 		FROM workspaces WHERE node_id = ? AND tmux_session = ?
 	`, nodeID, sessionName)
 
@@ -129,7 +141,7 @@ func (r *WorkspaceRepository) GetByName(ctx context.Context, name string) (*mode
 	row := r.db.QueryRowContext(ctx, `
 		SELECT 
 			id, name, node_id, repo_path, tmux_session, status,
-			git_info_json, created_at, updated_at
+			git_info_json, placement_json, pause_json, draining, quiet_hours, hibernate_after_seconds, bootstrap_json, created_at, updated_at
 		FROM workspaces WHERE name = ?
 	`, name)
 
@@ -141,7 +153,7 @@ func (r *WorkspaceRepository) List(ctx context.Context) ([]*models.Workspace, er
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT 
 			id, name, node_id, repo_path, tmux_session, status,
-			git_info_json, created_at, updated_at
+			git_info_json, placement_json, pause_json, draining, quiet_hours, hibernate_after_seconds, bootstrap_json, created_at, updated_at
 		FROM workspaces ORDER BY name
 	`)
 	if err != nil {
@@ -157,7 +169,7 @@ func (r *WorkspaceRepository) ListByNode(ctx context.Context, nodeID string) ([]
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT 
 			id, name, node_id, repo_path, tmux_session, status,
-			git_info_json, created_at, updated_at
+			git_info_json, placement_json, pause_json, draining, quiet_hours, hibernate_after_seconds, bootstrap_json, created_at, updated_at
 		FROM workspaces WHERE node_id = ? ORDER BY name
 	`, nodeID)
 	if err != nil {
@@ -173,7 +185,7 @@ func (r *WorkspaceRepository) ListByStatus(ctx context.Context, status models.Wo
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT 
 			id, name, node_id, repo_path, tmux_session, status,
-			git_info_json, created_at, updated_at
+			git_info_json, placement_json, pause_json, draining, quiet_hours, hibernate_after_seconds, bootstrap_json, created_at, updated_at
 		FROM workspaces WHERE status = ? ORDER BY name
 	`, string(status))
 	if err != nil {
@@ -189,14 +201,14 @@ func (r *WorkspaceRepository) ListWithAgentCounts(ctx context.Context) ([]*model
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT 
 			w.id, w.name, w.node_id, w.repo_path, w.tmux_session, w.status,
-			w.git_info_json, w.created_at, w.updated_at,
+			w.git_info_json, w.placement_json, w.pause_json, w.draining, w.quiet_hours, w.hibernate_after_seconds, w.bootstrap_json, w.created_at, w.updated_at,
 			COUNT(a.id) as agent_count,
 			COALESCE(SUM(CASE WHEN a.state IN ('working', 'starting') THEN 1 ELSE 0 END), 0) as working,
-			COALESCE(SUM(CASE WHEN a.state IN ('idle', 'stopped') THEN 1 ELSE 0 END), 0) as idle,
+			COALESCE(SUM(CASE WHEN a.state IN ('idle', 'stopped', 'hibernated') THEN 1 ELSE 0 END), 0) as idle,
 			COALESCE(SUM(CASE WHEN a.state IN ('awaiting_approval', 'rate_limited', 'paused') THEN 1 ELSE 0 END), 0) as blocked,
 			COALESCE(SUM(CASE WHEN a.state = 'error' THEN 1 ELSE 0 END), 0) as error
 		FROM workspaces w
-		LEFT JOIN agents a ON w.id = a.workspace_id
+		LEFT JOIN agents a ON w.id = a.workspace_id AND a.deleted_at IS NULL
 		GROUP BY w.id
 		ORDER BY w.name
 	`)
@@ -326,6 +338,144 @@ func (r *WorkspaceRepository) UpdateGitInfo(ctx context.Context, id string, gitI
 	return nil
 }
 
+// SetPause stores a workspace's pause state; nil clears it.
+func (r *WorkspaceRepository) SetPause(ctx context.Context, id string, pause *models.WorkspacePause) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	var pauseJSON *string
+	if pause != nil {
+		data, err := json.Marshal(pause)
+		if err != nil {
+			return fmt.Errorf("failed to marshal pause: %w", err)
+		}
+		s := string(data)
+		pauseJSON = &s
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workspaces SET pause_json = ?, updated_at = ?
+		WHERE id = ?
+	`, pauseJSON, now, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to update workspace pause: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetDraining sets whether a workspace is draining.
+func (r *WorkspaceRepository) SetDraining(ctx context.Context, id string, draining bool) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workspaces SET draining = ?, updated_at = ?
+		WHERE id = ?
+	`, boolToInt(draining), time.Now().UTC().Format(time.RFC3339), id)
+
+	if err != nil {
+		return fmt.Errorf("failed to update workspace draining: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetBootstrap records the bootstrap files last applied to a workspace.
+func (r *WorkspaceRepository) SetBootstrap(ctx context.Context, id string, bootstrap *models.WorkspaceBootstrap) error {
+	data, err := json.Marshal(bootstrap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bootstrap: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workspaces SET bootstrap_json = ?, updated_at = ?
+		WHERE id = ?
+	`, string(data), time.Now().UTC().Format(time.RFC3339), id)
+
+	if err != nil {
+		return fmt.Errorf("failed to update workspace bootstrap: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetQuietHours sets a workspace's quiet hours spec. An empty spec clears
+// it, so the workspace inherits the global setting.
+func (r *WorkspaceRepository) SetQuietHours(ctx context.Context, id, spec string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workspaces SET quiet_hours = ?, updated_at = ?
+		WHERE id = ?
+	`, nullString(spec), time.Now().UTC().Format(time.RFC3339), id)
+
+	if err != nil {
+		return fmt.Errorf("failed to update workspace quiet hours: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetHibernateAfter sets how many seconds a workspace's agents may sit
+// idle before they are hibernated. Zero turns hibernation off.
+func (r *WorkspaceRepository) SetHibernateAfter(ctx context.Context, id string, seconds int) error {
+	var value any
+	if seconds > 0 {
+		value = seconds
+	}
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workspaces SET hibernate_after_seconds = ?, updated_at = ?
+		WHERE id = ?
+	`, value, time.Now().UTC().Format(time.RFC3339), id)
+
+	if err != nil {
+		return fmt.Errorf("failed to update workspace hibernation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
 // Delete removes a workspace by ID.
 func (r *WorkspaceRepository) Delete(ctx context.Context, id string) error {
 	result, err := r.db.ExecContext(ctx, "DELETE FROM workspaces WHERE id = ?", id)
@@ -349,7 +499,7 @@ func (r *WorkspaceRepository) Delete(ctx context.Context, id string) error {
 func (r *WorkspaceRepository) GetAgentCount(ctx context.Context, workspaceID string) (int, error) {
 	var count int
 	err := r.db.QueryRowContext(ctx, `
-		SELECT COUNT(*) FROM agents WHERE workspace_id = ?
+		SELECT COUNT(*) FROM agents WHERE workspace_id = ? AND deleted_at IS NULL
 	`, workspaceID).Scan(&count)
 
 	if err != nil {
@@ -373,7 +523,8 @@ func (r *WorkspaceRepository) Count(ctx context.Context) (int, error) {
 func (r *WorkspaceRepository) scanWorkspace(row *sql.Row) (*models.Workspace, error) {
 	var workspace models.Workspace
 	var status string
-	var gitInfoJSON sql.NullString
+	var gitInfoJSON, placementJSON, pauseJSON, quietHours, bootstrapJSON sql.NullString
+	var hibernateAfter sql.NullInt64
 	var createdAt, updatedAt string
 
 	err := row.Scan(
@@ -384,6 +535,12 @@ func (r *WorkspaceRepository) scanWorkspace(row *sql.Row) (*models.Workspace, er
 		&workspace.TmuxSession,
 		&status,
 		&gitInfoJSON,
+		&placementJSON,
+		&pauseJSON,
+		&workspace.Draining,
+		&quietHours,
+		&hibernateAfter,
+		&bootstrapJSON,
 		&createdAt,
 		&updatedAt,
 	)
@@ -406,6 +563,20 @@ func (r *WorkspaceRepository) scanWorkspace(row *sql.Row) (*models.Workspace, er
 		}
 	}
 
+	if placementJSON.Valid && placementJSON.String != "" {
+		var placement models.WorkspacePlacement
+		if err := json.Unmarshal([]byte(placementJSON.String), &placement); err != nil {
+			r.db.logger.Warn().Err(err).Str("workspace_id", workspace.ID).Msg("failed to parse placement")
+		} else {
+			workspace.Placement = &placement
+		}
+	}
+
+	r.decodePause(&workspace, pauseJSON)
+	workspace.QuietHours = quietHours.String
+	workspace.HibernateAfterSeconds = int(hibernateAfter.Int64)
+	r.decodeBootstrap(&workspace, bootstrapJSON)
+
 	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
 		workspace.CreatedAt = t
 	}
@@ -423,7 +594,8 @@ func (r *WorkspaceRepository) scanWorkspaces(rows *sql.Rows) ([]*models.Workspac
 	for rows.Next() {
 		var workspace models.Workspace
 		var status string
-		var gitInfoJSON sql.NullString
+		var gitInfoJSON, placementJSON, pauseJSON, quietHours, bootstrapJSON sql.NullString
+		var hibernateAfter sql.NullInt64
 		var createdAt, updatedAt string
 
 		err := rows.Scan(
@@ -434,6 +606,12 @@ func (r *WorkspaceRepository) scanWorkspaces(rows *sql.Rows) ([]*models.Workspac
 			&workspace.TmuxSession,
 			&status,
 			&gitInfoJSON,
+			&placementJSON,
+			&pauseJSON,
+			&workspace.Draining,
+			&quietHours,
+			&hibernateAfter,
+			&bootstrapJSON,
 			&createdAt,
 			&updatedAt,
 		)
@@ -453,6 +631,20 @@ func (r *WorkspaceRepository) scanWorkspaces(rows *sql.Rows) ([]*models.Workspac
 			}
 		}
 
+		if placementJSON.Valid && placementJSON.String != "" {
+			var placement models.WorkspacePlacement
+			if err := json.Unmarshal([]byte(placementJSON.String), &placement); err != nil {
+				r.db.logger.Warn().Err(err).Str("workspace_id", workspace.ID).Msg("failed to parse placement")
+			} else {
+				workspace.Placement = &placement
+			}
+		}
+
+		r.decodePause(&workspace, pauseJSON)
+		workspace.QuietHours = quietHours.String
+		workspace.HibernateAfterSeconds = int(hibernateAfter.Int64)
+		r.decodeBootstrap(&workspace, bootstrapJSON)
+
 		if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
 			workspace.CreatedAt = t
 		}
@@ -477,7 +669,8 @@ func (r *WorkspaceRepository) scanWorkspacesWithCounts(rows *sql.Rows) ([]*model
 	for rows.Next() {
 		var workspace models.Workspace
 		var status string
-		var gitInfoJSON sql.NullString
+		var gitInfoJSON, placementJSON, pauseJSON, quietHours, bootstrapJSON sql.NullString
+		var hibernateAfter sql.NullInt64
 		var createdAt, updatedAt string
 
 		err := rows.Scan(
@@ -488,6 +681,12 @@ func (r *WorkspaceRepository) scanWorkspacesWithCounts(rows *sql.Rows) ([]*model
 			&workspace.TmuxSession,
 			&status,
 			&gitInfoJSON,
+			&placementJSON,
+			&pauseJSON,
+			&workspace.Draining,
+			&quietHours,
+			&hibernateAfter,
+			&bootstrapJSON,
 			&createdAt,
 			&updatedAt,
 			&workspace.AgentCount,
@@ -512,6 +711,20 @@ func (r *WorkspaceRepository) scanWorkspacesWithCounts(rows *sql.Rows) ([]*model
 			}
 		}
 
+		if placementJSON.Valid && placementJSON.String != "" {
+			var placement models.WorkspacePlacement
+			if err := json.Unmarshal([]byte(placementJSON.String), &placement); err != nil {
+				r.db.logger.Warn().Err(err).Str("workspace_id", workspace.ID).Msg("failed to parse placement")
+			} else {
+				workspace.Placement = &placement
+			}
+		}
+
+		r.decodePause(&workspace, pauseJSON)
+		workspace.QuietHours = quietHours.String
+		workspace.HibernateAfterSeconds = int(hibernateAfter.Int64)
+		r.decodeBootstrap(&workspace, bootstrapJSON)
+
 		if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
 			workspace.CreatedAt = t
 		}
@@ -528,3 +741,29 @@ func (r *WorkspaceRepository) scanWorkspacesWithCounts(rows *sql.Rows) ([]*model
 
 	return workspaces, nil
 }
+
+// decodePause parses a stored pause into workspace.
+func (r *WorkspaceRepository) decodePause(workspace *models.Workspace, pauseJSON sql.NullString) {
+	if !pauseJSON.Valid || pauseJSON.String == "" {
+		return
+	}
+	var pause models.WorkspacePause
+	if err := json.Unmarshal([]byte(pauseJSON.String), &pause); err != nil {
+		r.db.logger.Warn().Err(err).Str("workspace_id", workspace.ID).Msg("failed to parse pause")
+		return
+	}
+	workspace.Pause = &pause
+}
+
+// decodeBootstrap parses a stored bootstrap record into workspace.
+func (r *WorkspaceRepository) decodeBootstrap(workspace *models.Workspace, bootstrapJSON sql.NullString) {
+	if !bootstrapJSON.Valid || bootstrapJSON.String == "" {
+		return
+	}
+	var bootstrap models.WorkspaceBootstrap
+	if err := json.Unmarshal([]byte(bootstrapJSON.String), &bootstrap); err != nil {
+		r.db.logger.Warn().Err(err).Str("workspace_id", workspace.ID).Msg("failed to parse bootstrap")
+		return
+	}
+	workspace.Bootstrap = &bootstrap
+}
@@ -13,7 +13,7 @@ func (r *WorkspaceRepository) GetAgentStateCounts(ctx context.Context, workspace
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT state, COUNT(*)
 		FROM agents
-		WHERE workspace_id = ?
+		WHERE workspace_id = ? AND deleted_at IS NULL
 		GROUP BY state
 	`, workspaceID)
 	if err != nil {
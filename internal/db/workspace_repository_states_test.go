@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/opencode-ai/swarm/internal/models"
@@ -43,3 +44,22 @@ func TestWorkspaceRepository_GetAgentStateCounts(t *testing.T) {
 		t.Fatalf("expected 1 error, got %d", counts[models.AgentStateError])
 	}
 }
+
+func TestWorkspaceRepository_PathUniqueIgnoresTrailingSlash(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	ws := createTestWorkspace(t, db)
+	repo := NewWorkspaceRepository(db)
+
+	dup := &models.Workspace{NodeID: ws.NodeID, RepoPath: ws.RepoPath + "/", TmuxSession: "swarm-test-dup"}
+	if err := repo.Create(ctx, dup); !errors.Is(err, ErrWorkspaceAlreadyExists) {
+		t.Fatalf("expected ErrWorkspaceAlreadyExists, got %v", err)
+	}
+
+	found, err := repo.GetByNodeAndPath(ctx, ws.NodeID, ws.RepoPath+"/")
+	if err != nil || found.ID != ws.ID {
+		t.Fatalf("expected lookup with a trailing slash to find %s, got %v (%v)", ws.ID, found, err)
+	}
+}
@@ -0,0 +1,318 @@
+package eventbridge
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// Bridge defaults, used when the matching option is unset.
+const (
+	DefaultSubjectPrefix  = "swarm.events"
+	DefaultBufferSize     = 1000
+	DefaultBatchSize      = 100
+	DefaultPollInterval   = time.Second
+	DefaultPublishTimeout = 10 * time.Second
+
+	// maxRetryBackoff caps the wait between attempts while the queue is
+	// unreachable.
+	maxRetryBackoff = time.Minute
+)
+
+// Stats describes a bridge's deliveries since it started.
+type Stats struct {
+	Published int64 `json:"published"`
+	Failures  int64 `json:"failures"`
+	// Overflowed counts events the in-memory buffer had no room for;
+	// they are published from the outbox instead.
+	Overflowed int64 `json:"overflowed"`
+	// Backlog is the number of events in the outbox awaiting
+	// publication, as of the last check.
+	Backlog         int64      `json:"backlog"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastPublishedAt *time.Time `json:"last_published_at,omitempty"`
+}
+
+// Bridge publishes events from the outbox, and from an in-process event
+// bus it is attached to, to an external message queue.
+type Bridge struct {
+	repo      *db.EventRepository
+	dial      DialFunc
+	clock     clock.Clock
+	logger    zerolog.Logger
+	prefix    string
+	batchSize int
+	interval  time.Duration
+	timeout   time.Duration
+
+	// buffer holds events from the attached bus until they are published.
+	// It never blocks the bus: events that do not fit wait in the outbox.
+	buffer chan *models.Event
+	conn   Publisher
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// Option configures a Bridge.
+type Option func(*Bridge)
+
+// WithSubjectPrefix sets the prefix of the subjects events are published
+// on.
+func WithSubjectPrefix(prefix string) Option {
+	return func(b *Bridge) {
+		b.prefix = prefix
+	}
+}
+
+// WithBufferSize bounds the events from the attached bus held in memory.
+func WithBufferSize(n int) Option {
+	return func(b *Bridge) {
+		if n > 0 {
+			b.buffer = make(chan *models.Event, n)
+		}
+	}
+}
+
+// WithBatchSize sets how many events are published per confirmation.
+func WithBatchSize(n int) Option {
+	return func(b *Bridge) {
+		b.batchSize = n
+	}
+}
+
+// WithPollInterval sets how often the outbox is checked for new events.
+func WithPollInterval(d time.Duration) Option {
+	return func(b *Bridge) {
+		b.interval = d
+	}
+}
+
+// WithPublishTimeout bounds connecting and confirming one batch.
+func WithPublishTimeout(d time.Duration) Option {
+	return func(b *Bridge) {
+		b.timeout = d
+	}
+}
+
+// WithClock sets the time source for polling and retry backoff.
+func WithClock(c clock.Clock) Option {
+	return func(b *Bridge) {
+		b.clock = c
+	}
+}
+
+// New creates a bridge publishing the outbox of database through
+// connections from dial. database should be opened with EventOutbox, or
+// only events from the attached bus and swarm events republish reach the
+// queue.
+func New(database *db.DB, dial DialFunc, logger zerolog.Logger, opts ...Option) *Bridge {
+	b := &Bridge{
+		repo:      db.NewEventRepository(database),
+		dial:      dial,
+		logger:    logger,
+		prefix:    DefaultSubjectPrefix,
+		batchSize: DefaultBatchSize,
+		interval:  DefaultPollInterval,
+		timeout:   DefaultPublishTimeout,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.clock = clock.OrReal(b.clock)
+	if b.buffer == nil {
+		b.buffer = make(chan *models.Event, DefaultBufferSize)
+	}
+	if b.batchSize <= 0 {
+		b.batchSize = DefaultBatchSize
+	}
+	if b.interval <= 0 {
+		b.interval = DefaultPollInterval
+	}
+	if b.timeout <= 0 {
+		b.timeout = DefaultPublishTimeout
+	}
+	return b
+}
+
+// Attach subscribes the bridge to an in-process event bus, so its events
+// are published without waiting for the next outbox check.
+func (b *Bridge) Attach(publisher events.Publisher) error {
+	return publisher.Subscribe("eventbridge", events.Filter{}, func(event *models.Event) {
+		select {
+		case b.buffer <- event:
+		default:
+			b.mu.Lock()
+			b.stats.Overflowed++
+			b.mu.Unlock()
+		}
+	})
+}
+
+// Stats returns the bridge's delivery counters.
+func (b *Bridge) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// Run publishes until ctx is canceled: buffered events as they arrive,
+// and the outbox at once and then every poll interval. While publishing
+// fails, attempts back off up to a minute.
+func (b *Bridge) Run(ctx context.Context) {
+	ticker := b.clock.NewTicker(b.interval)
+	defer ticker.Stop()
+	defer b.disconnect()
+
+	var backoff time.Duration
+	var retryAt time.Time
+	attempt := func(publish func(context.Context) error) {
+		if b.clock.Now().Before(retryAt) {
+			return
+		}
+		if err := publish(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			backoff = min(max(2*backoff, b.interval), maxRetryBackoff)
+			retryAt = b.clock.Now().Add(backoff)
+			b.logger.Warn().Err(err).
+				Int64("backlog", b.Stats().Backlog).
+				Dur("retry_in", backoff).
+				Msg("event bridge publish failed")
+			return
+		}
+		if backoff > 0 {
+			b.logger.Info().Msg("event bridge publishing again")
+		}
+		backoff, retryAt = 0, time.Time{}
+	}
+
+	for {
+		attempt(b.Drain)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case event := <-b.buffer:
+			attempt(func(ctx context.Context) error {
+				return b.publishBuffered(ctx, event)
+			})
+		}
+	}
+}
+
+// Drain publishes the outbox until it is empty or publishing fails.
+func (b *Bridge) Drain(ctx context.Context) error {
+	for {
+		pending, err := b.repo.ListUnpublished(ctx, b.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			b.setBacklog(0)
+			return nil
+		}
+		if err := b.publish(ctx, pending); err != nil {
+			if backlog, countErr := b.repo.CountUnpublished(ctx); countErr == nil {
+				b.setBacklog(backlog)
+			}
+			return err
+		}
+		if len(pending) < b.batchSize {
+			b.setBacklog(0)
+			return nil
+		}
+	}
+}
+
+// publishBuffered publishes first along with whatever else is waiting in
+// the buffer, up to a batch.
+func (b *Bridge) publishBuffered(ctx context.Context, first *models.Event) error {
+	batch := []*models.Event{first}
+collect:
+	for len(batch) < b.batchSize {
+		select {
+		case event := <-b.buffer:
+			batch = append(batch, event)
+		default:
+			break collect
+		}
+	}
+	return b.publish(ctx, batch)
+}
+
+// publish sends events, waits for the queue to confirm them, and marks
+// them published. Nothing is marked unless the whole batch is confirmed.
+func (b *Bridge) publish(ctx context.Context, batch []*models.Event) error {
+	ids := make([]string, len(batch))
+	for i, event := range batch {
+		ids[i] = event.ID
+	}
+
+	if err := b.send(ctx, batch); err != nil {
+		b.disconnect()
+		b.mu.Lock()
+		b.stats.Failures++
+		b.stats.LastError = err.Error()
+		b.mu.Unlock()
+		if recordErr := b.repo.RecordPublishFailure(ctx, ids, err); recordErr != nil {
+			b.logger.Warn().Err(recordErr).Msg("failed to record event publish failure")
+		}
+		return err
+	}
+
+	if err := b.repo.MarkPublished(ctx, ids); err != nil {
+		// The queue has the events; they will be published again.
+		return err
+	}
+	now := b.clock.Now()
+	b.mu.Lock()
+	b.stats.Published += int64(len(batch))
+	b.stats.LastPublishedAt = &now
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *Bridge) send(ctx context.Context, batch []*models.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	if b.conn == nil {
+		conn, err := b.dial(ctx)
+		if err != nil {
+			return err
+		}
+		b.conn = conn
+	}
+	for _, event := range batch {
+		data, err := encodeEvent(event)
+		if err != nil {
+			return err
+		}
+		if err := b.conn.Publish(ctx, Subject(b.prefix, event.Type), data); err != nil {
+			return fmt.Errorf("failed to publish event %s: %w", event.ID, err)
+		}
+	}
+	return b.conn.Flush(ctx)
+}
+
+func (b *Bridge) disconnect() {
+	if b.conn != nil {
+		_ = b.conn.Close()
+		b.conn = nil
+	}
+}
+
+func (b *Bridge) setBacklog(n int64) {
+	b.mu.Lock()
+	b.stats.Backlog = n
+	b.mu.Unlock()
+}
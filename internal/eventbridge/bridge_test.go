@@ -0,0 +1,263 @@
+package eventbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// fakeQueue records what its connections publish. Messages count as
+// received once flushed.
+type fakeQueue struct {
+	mu       sync.Mutex
+	dials    int
+	received map[string]int
+	subjects []string
+	// failAfter makes the next connection drop after that many publishes.
+	failAfter int
+	// onFlush runs after a successful flush.
+	onFlush func()
+}
+
+type fakeConn struct {
+	queue     *fakeQueue
+	pending   []string
+	failAfter int
+}
+
+func newFakeQueue() *fakeQueue {
+	return &fakeQueue{received: make(map[string]int), failAfter: -1}
+}
+
+func (q *fakeQueue) dial(ctx context.Context) (Publisher, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.dials++
+	conn := &fakeConn{queue: q, failAfter: q.failAfter}
+	q.failAfter = -1
+	return conn, nil
+}
+
+func (q *fakeQueue) count(id string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.received[id]
+}
+
+func (c *fakeConn) Publish(ctx context.Context, subject string, data []byte) error {
+	if c.failAfter == 0 {
+		return errors.New("connection reset by peer")
+	}
+	c.failAfter--
+	var event models.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return err
+	}
+	c.queue.mu.Lock()
+	c.queue.subjects = append(c.queue.subjects, subject)
+	c.queue.mu.Unlock()
+	c.pending = append(c.pending, event.ID)
+	return nil
+}
+
+func (c *fakeConn) Flush(ctx context.Context) error {
+	c.queue.mu.Lock()
+	for _, id := range c.pending {
+		c.queue.received[id]++
+	}
+	onFlush := c.queue.onFlush
+	c.queue.mu.Unlock()
+	c.pending = nil
+	if onFlush != nil {
+		onFlush()
+	}
+	return nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func openOutboxDB(t *testing.T, path string) *db.DB {
+	t.Helper()
+	database, err := db.Open(db.Config{Path: path, EventOutbox: true})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := database.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return database
+}
+
+func recordEvents(t *testing.T, database *db.DB, n int) []*models.Event {
+	t.Helper()
+	repo := db.NewEventRepository(database)
+	recorded := make([]*models.Event, n)
+	for i := range recorded {
+		recorded[i] = &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: "agent-1"}
+		if err := repo.Create(context.Background(), recorded[i]); err != nil {
+			t.Fatalf("record event: %v", err)
+		}
+	}
+	return recorded
+}
+
+func TestBridge_DrainPublishesBacklog(t *testing.T) {
+	ctx := context.Background()
+	database := openOutboxDB(t, filepath.Join(t.TempDir(), "swarm.db"))
+	defer database.Close()
+	// Recorded while no bridge was running.
+	recorded := recordEvents(t, database, 5)
+
+	queue := newFakeQueue()
+	bridge := New(database, queue.dial, zerolog.Nop(), WithBatchSize(2), WithSubjectPrefix("ops.swarm"))
+	if err := bridge.Drain(ctx); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	for _, event := range recorded {
+		if got := queue.count(event.ID); got != 1 {
+			t.Fatalf("expected event %s published once, got %d", event.ID, got)
+		}
+	}
+	if queue.subjects[0] != "ops.swarm.agent.spawned" {
+		t.Fatalf("unexpected subject %q", queue.subjects[0])
+	}
+	stats := bridge.Stats()
+	if stats.Published != 5 || stats.Backlog != 0 || stats.LastPublishedAt == nil {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if pending, _ := db.NewEventRepository(database).CountUnpublished(ctx); pending != 0 {
+		t.Fatalf("expected the outbox drained, got %d pending", pending)
+	}
+}
+
+func TestBridge_CrashBeforeMarkRepublishes(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "swarm.db")
+	database := openOutboxDB(t, path)
+	recorded := recordEvents(t, database, 3)
+
+	// The process dies after the queue confirms the batch but before the
+	// outbox records it.
+	queue := newFakeQueue()
+	queue.onFlush = func() { database.Close() }
+	if err := New(database, queue.dial, zerolog.Nop()).Drain(ctx); err == nil {
+		t.Fatal("expected marking the batch to fail")
+	}
+
+	queue.onFlush = nil
+	reopened := openOutboxDB(t, path)
+	defer reopened.Close()
+	if err := New(reopened, queue.dial, zerolog.Nop()).Drain(ctx); err != nil {
+		t.Fatalf("Drain after restart failed: %v", err)
+	}
+	for _, event := range recorded {
+		if got := queue.count(event.ID); got != 2 {
+			t.Fatalf("expected event %s published again after the crash, got %d", event.ID, got)
+		}
+	}
+}
+
+func TestBridge_RetriesAfterConnectionDrop(t *testing.T) {
+	ctx := context.Background()
+	database := openOutboxDB(t, filepath.Join(t.TempDir(), "swarm.db"))
+	defer database.Close()
+	recorded := recordEvents(t, database, 4)
+	repo := db.NewEventRepository(database)
+
+	queue := newFakeQueue()
+	queue.failAfter = 2
+	bridge := New(database, queue.dial, zerolog.Nop())
+	if err := bridge.Drain(ctx); err == nil {
+		t.Fatal("expected the dropped connection to fail the drain")
+	}
+	stats := bridge.Stats()
+	if stats.Failures != 1 || stats.Backlog != 4 || stats.LastError == "" {
+		t.Fatalf("expected the failure and backlog reported, got %+v", stats)
+	}
+	for _, event := range recorded {
+		if queue.count(event.ID) != 0 {
+			t.Fatalf("expected nothing confirmed before the drop, got event %s", event.ID)
+		}
+	}
+
+	if err := bridge.Drain(ctx); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if queue.dials != 2 {
+		t.Fatalf("expected the retry to reconnect, got %d dials", queue.dials)
+	}
+	for _, event := range recorded {
+		if got := queue.count(event.ID); got != 1 {
+			t.Fatalf("expected event %s published once, got %d", event.ID, got)
+		}
+	}
+	if pending, _ := repo.CountUnpublished(ctx); pending != 0 {
+		t.Fatalf("expected the outbox drained, got %d pending", pending)
+	}
+}
+
+func TestBridge_AttachedBus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	database := openOutboxDB(t, filepath.Join(t.TempDir(), "swarm.db"))
+	defer database.Close()
+	bus := events.NewInMemoryPublisher(events.WithRepository(db.NewEventRepository(database)))
+
+	// With no room in the buffer, bus events wait in the outbox.
+	queue := newFakeQueue()
+	bridge := New(database, queue.dial, zerolog.Nop(), WithBufferSize(1), WithPollInterval(time.Hour))
+	if err := bridge.Attach(bus); err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	overflow := make([]*models.Event, 3)
+	for i := range overflow {
+		overflow[i] = &models.Event{Type: models.EventTypeAgentSpawned, EntityType: models.EntityTypeAgent, EntityID: "agent-1"}
+		bus.Publish(ctx, overflow[i])
+	}
+	if got := bridge.Stats().Overflowed; got != 2 {
+		t.Fatalf("expected 2 events past the buffer, got %d", got)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		bridge.Run(ctx)
+		close(done)
+	}()
+
+	// The poll interval is an hour, so this arrives through the bus.
+	prompt := &models.Event{Type: models.EventTypeAgentStateChanged, EntityType: models.EntityTypeAgent, EntityID: "agent-1"}
+	waitFor(t, func() bool {
+		for _, event := range overflow {
+			if queue.count(event.ID) == 0 {
+				return false
+			}
+		}
+		return true
+	})
+	bus.Publish(ctx, prompt)
+	waitFor(t, func() bool { return queue.count(prompt.ID) > 0 })
+
+	cancel()
+	<-done
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for delivery")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
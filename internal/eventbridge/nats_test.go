@@ -0,0 +1,174 @@
+package eventbridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeNATSServer speaks enough of the NATS protocol to accept publishes.
+type fakeNATSServer struct {
+	t        *testing.T
+	listener net.Listener
+
+	mu       sync.Mutex
+	connects []natsConnect
+	messages map[string][]string
+	// rejectSubject answers a PUB on it with -ERR.
+	rejectSubject string
+}
+
+func newFakeNATSServer(t *testing.T) *fakeNATSServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeNATSServer{t: t, listener: listener, messages: make(map[string][]string)}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeNATSServer) url(userinfo string) string {
+	if userinfo != "" {
+		userinfo += "@"
+	}
+	return "nats://" + userinfo + s.listener.Addr().String()
+}
+
+func (s *fakeNATSServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeNATSServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprint(conn, "INFO {\"server_id\":\"fake\",\"max_payload\":1048576}\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "CONNECT":
+			var connect natsConnect
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "CONNECT ")), &connect); err != nil {
+				s.t.Errorf("bad CONNECT %q: %v", line, err)
+				return
+			}
+			s.mu.Lock()
+			s.connects = append(s.connects, connect)
+			s.mu.Unlock()
+		case "PUB":
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			s.mu.Lock()
+			reject := fields[1] == s.rejectSubject
+			if !reject {
+				s.messages[fields[1]] = append(s.messages[fields[1]], string(payload[:size]))
+			}
+			s.mu.Unlock()
+			if reject {
+				fmt.Fprint(conn, "-ERR 'Permissions Violation for Publish'\r\n")
+			}
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		}
+	}
+}
+
+func (s *fakeNATSServer) received(subject string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages[subject]...)
+}
+
+func TestNATSPublisher_PublishAndFlush(t *testing.T) {
+	server := newFakeNATSServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	p, err := DialNATS(ctx, server.url("bridge:secret"))
+	if err != nil {
+		t.Fatalf("DialNATS failed: %v", err)
+	}
+	defer p.Close()
+
+	if err := p.Publish(ctx, "swarm.events.agent.spawned", []byte(`{"id":"1"}`)); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := p.Publish(ctx, "swarm.events.agent.spawned", []byte(`{"id":"2"}`)); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := p.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	got := server.received("swarm.events.agent.spawned")
+	if len(got) != 2 || got[0] != `{"id":"1"}` || got[1] != `{"id":"2"}` {
+		t.Fatalf("expected both messages received by the flush, got %q", got)
+	}
+	server.mu.Lock()
+	connect := server.connects[0]
+	server.mu.Unlock()
+	if connect.User != "bridge" || connect.Pass != "secret" || connect.Verbose {
+		t.Fatalf("unexpected CONNECT %+v", connect)
+	}
+}
+
+func TestNATSPublisher_Errors(t *testing.T) {
+	server := newFakeNATSServer(t)
+	server.rejectSubject = "swarm.events.denied"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	p, err := DialNATS(ctx, server.url("s3cr3t"))
+	if err != nil {
+		t.Fatalf("DialNATS failed: %v", err)
+	}
+	defer p.Close()
+	server.mu.Lock()
+	token := server.connects[0].AuthToken
+	server.mu.Unlock()
+	if token != "s3cr3t" {
+		t.Fatalf("expected a bare userinfo sent as a token, got %q", token)
+	}
+
+	if err := p.Publish(ctx, "swarm.events.denied", []byte("{}")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := p.Flush(ctx); err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Fatalf("expected the server's error from Flush, got %v", err)
+	}
+	if err := p.Publish(ctx, "bad subject", nil); err == nil {
+		t.Fatal("expected a subject with spaces rejected")
+	}
+
+	for _, rawURL := range []string{"http://localhost:4222", "nats://", "::"} {
+		if _, err := DialNATS(ctx, rawURL); err == nil || !strings.Contains(err.Error(), "invalid NATS URL") {
+			t.Fatalf("expected %q rejected, got %v", rawURL, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package eventbridge publishes swarm events to an external message queue.
+//
+// Events reach the queue through the outbox: while the bridge is enabled,
+// every event is recorded with an outbox row in the same transaction, and a
+// Bridge publishes the rows and marks them published once the queue has
+// confirmed them. Delivery is at least once: an event may be published
+// again after a failure or restart, and events are not guaranteed to
+// arrive in order, so consumers should deduplicate on the event ID.
+package eventbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Publisher delivers messages to an external message queue.
+type Publisher interface {
+	// Publish sends data on subject. It may return before the server has
+	// received the message.
+	Publish(ctx context.Context, subject string, data []byte) error
+
+	// Flush returns once the server has accepted everything published so
+	// far, and an error if it may not have.
+	Flush(ctx context.Context) error
+
+	// Close closes the connection.
+	Close() error
+}
+
+// DialFunc connects a Publisher.
+type DialFunc func(ctx context.Context) (Publisher, error)
+
+// NewDialer returns the DialFunc for the backend configured in cfg.
+func NewDialer(cfg config.EventBridgeConfig) (DialFunc, error) {
+	switch cfg.Backend {
+	case "nats":
+		url := cfg.URL
+		return func(ctx context.Context) (Publisher, error) {
+			return DialNATS(ctx, url)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported event bridge backend %q", cfg.Backend)
+	}
+}
+
+// Subject returns the subject events of eventType are published on.
+func Subject(prefix string, eventType models.EventType) string {
+	return prefix + "." + string(eventType)
+}
+
+// encodeEvent returns the message published for event: the event as JSON,
+// as swarm export events writes it, trace ID included.
+func encodeEvent(event *models.Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+	return data, nil
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/trace"
 )
 
 // EventHandler is a callback function invoked when an event matches a subscription.
@@ -123,6 +124,9 @@ func (p *InMemoryPublisher) Publish(ctx context.Context, event *models.Event) {
 	if event == nil {
 		return
 	}
+	if event.TraceID == "" {
+		event.TraceID = trace.FromContext(ctx)
+	}
 
 	// Persist to repository if configured
 	if p.repo != nil {
@@ -152,6 +156,9 @@ func (p *InMemoryPublisher) PublishAsync(ctx context.Context, event *models.Even
 	if event == nil {
 		return
 	}
+	if event.TraceID == "" {
+		event.TraceID = trace.FromContext(ctx)
+	}
 
 	// Persist to repository if configured
 	if p.repo != nil {
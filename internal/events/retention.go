@@ -11,9 +11,10 @@ import (
 	"time"
 
 	"github.com/opencode-ai/swarm/internal/config"
-	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/fsperm"
 	"github.com/opencode-ai/swarm/internal/logging"
 	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/store"
 	"github.com/rs/zerolog"
 )
 
@@ -21,7 +22,7 @@ import (
 type RetentionService struct {
 	cfg     *config.EventRetentionConfig
 	dataDir string
-	repo    *db.EventRepository
+	repo    store.EventStore
 	logger  zerolog.Logger
 	stopCh  chan struct{}
 	wg      sync.WaitGroup
@@ -44,7 +45,7 @@ type RetentionStats struct {
 }
 
 // NewRetentionService creates a new retention service.
-func NewRetentionService(cfg *config.Config, repo *db.EventRepository) *RetentionService {
+func NewRetentionService(cfg *config.Config, repo store.EventStore) *RetentionService {
 	logger := logging.Component("retention")
 
 	return &RetentionService{
@@ -333,7 +334,7 @@ func (s *RetentionService) archiveEvents(events []*models.Event) error {
 	}
 
 	// Ensure archive directory exists
-	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+	if err := fsperm.MkdirAll(archiveDir); err != nil {
 		return fmt.Errorf("failed to create archive directory: %w", err)
 	}
 
@@ -350,7 +351,7 @@ func (s *RetentionService) archiveEvents(events []*models.Event) error {
 		filepath := filepath.Join(archiveDir, filename)
 
 		// Open file for appending
-		file, err := os.OpenFile(filepath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		file, err := fsperm.OpenFile(filepath, os.O_CREATE|os.O_APPEND|os.O_WRONLY)
 		if err != nil {
 			return fmt.Errorf("failed to open archive file: %w", err)
 		}
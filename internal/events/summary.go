@@ -0,0 +1,374 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Names resolves entity IDs to display names for event summaries. Any nil
+// resolver, or one returning "", falls back to a short ID.
+type Names struct {
+	Agent     func(id string) string
+	Account   func(id string) string
+	Workspace func(id string) string
+	Node      func(id string) string
+}
+
+func (n Names) resolve(fn func(string) string, id string) string {
+	if fn != nil {
+		if name := fn(id); name != "" {
+			return name
+		}
+	}
+	return shortID(id)
+}
+
+func (n Names) agent(id string) string {
+	if id == "" {
+		return "agent"
+	}
+	return "agent '" + n.resolve(n.Agent, id) + "'"
+}
+
+func (n Names) account(id string) string { return n.resolve(n.Account, id) }
+
+func (n Names) workspace(id string) string { return "workspace '" + n.resolve(n.Workspace, id) + "'" }
+
+func (n Names) node(id string) string { return "node '" + n.resolve(n.Node, id) + "'" }
+
+// Summarize returns a one-line human summary of an event, such as
+// "spawned claude-code agent 'parser-fix'" or "agent 'parser-fix' rotated to
+// anthropic-work (rate limit)". Payloads that fail to decode still produce a
+// summary, just with fewer details.
+func Summarize(event *models.Event, names Names) string {
+	if event == nil {
+		return ""
+	}
+
+	switch event.Type {
+	case models.EventTypeAgentSpawned:
+		var p models.AgentSpawnedPayload
+		decode(event, &p)
+		if p.Type != "" {
+			return fmt.Sprintf("spawned %s %s", p.Type, names.agent(event.EntityID))
+		}
+		return "spawned " + names.agent(event.EntityID)
+	case models.EventTypeAgentStateChanged:
+		var p models.StateChangedPayload
+		decode(event, &p)
+		summary := fmt.Sprintf("%s %s -> %s", names.agent(event.EntityID), p.OldState, p.NewState)
+		return withDetail(summary, p.Reason)
+	case models.EventTypeAgentRestarted:
+		return names.agent(event.EntityID) + " restarted"
+	case models.EventTypeAgentTerminated:
+		return names.agent(event.EntityID) + " terminated"
+	case models.EventTypeAgentPaused:
+		return names.agent(event.EntityID) + " paused"
+	case models.EventTypeAgentResumed:
+		return names.agent(event.EntityID) + " resumed"
+	case models.EventTypeAgentStuck:
+		var p models.AgentStuckPayload
+		decode(event, &p)
+		silent := time.Duration(p.SilentSeconds * float64(time.Second)).Round(time.Second)
+		return fmt.Sprintf("%s stuck, silent for %s", names.agent(event.EntityID), silent)
+	case models.EventTypeAgentRecovery:
+		var p models.AgentRecoveryPayload
+		decode(event, &p)
+		summary := fmt.Sprintf("%s recovery step %d: %s", names.agent(event.EntityID), p.Step, p.Action)
+		if p.Error != "" {
+			return summary + " failed: " + truncate(p.Error, 60)
+		}
+		return summary
+	case models.EventTypeAgentMoved:
+		var p models.AgentMovedPayload
+		decode(event, &p)
+		return fmt.Sprintf("moved %s from %s to %s", names.agent(event.EntityID), names.workspace(p.FromWorkspaceID), names.workspace(p.ToWorkspaceID))
+	case models.EventTypeAgentClaimed:
+		return "claimed standby " + names.agent(event.EntityID)
+	case models.EventTypeAgentCheckpointed:
+		var p models.AgentCheckpointPayload
+		decode(event, &p)
+		return fmt.Sprintf("checkpoint %s of %s (%d files)", p.Checkpoint, names.agent(event.EntityID), p.Files)
+	case models.EventTypeAgentDrained:
+		return names.agent(event.EntityID) + " drained its queue"
+	case models.EventTypeAgentHibernated:
+		var p models.AgentHibernationPayload
+		decode(event, &p)
+		return withDetail(names.agent(event.EntityID)+" hibernated", p.Reason)
+	case models.EventTypeAgentWoken:
+		var p models.AgentHibernationPayload
+		decode(event, &p)
+		return withDetail(names.agent(event.EntityID)+" woken", p.Reason)
+	case models.EventTypeAgentRestored:
+		var p models.AgentCheckpointPayload
+		decode(event, &p)
+		return fmt.Sprintf("restored %s from checkpoint %s", names.agent(event.EntityID), p.Checkpoint)
+	case models.EventTypeAgentSnapshot:
+		var p models.AgentSnapshotPayload
+		decode(event, &p)
+		return withDetail(fmt.Sprintf("snapshot %s of %s", shortID(p.SnapshotID), names.agent(event.EntityID)), truncate(p.Note, 60))
+
+	case models.EventTypeMessageQueued:
+		var p models.MessageQueuedPayload
+		decode(event, &p)
+		return fmt.Sprintf("queued %s for %s", itemType(p.ItemType), names.agent(agentOf(event, p.AgentID)))
+	case models.EventTypeMessageDispatched:
+		var p models.MessageDispatchedPayload
+		decode(event, &p)
+		summary := fmt.Sprintf("sent %s to %s", itemType(p.ItemType), names.agent(agentOf(event, p.AgentID)))
+		if p.Message != "" {
+			return fmt.Sprintf("%s: %q", summary, truncate(p.Message, 60))
+		}
+		return summary
+	case models.EventTypeMessageCompleted:
+		var p models.MessageCompletedPayload
+		decode(event, &p)
+		return withDetail(fmt.Sprintf("%s finished %s", names.agent(agentOf(event, p.AgentID)), itemType(p.ItemType)), inDuration(p.Duration))
+	case models.EventTypeMessageFailed:
+		var p models.MessageFailedPayload
+		decode(event, &p)
+		return fmt.Sprintf("%s to %s failed after %d attempt(s): %s", itemType(p.ItemType), names.agent(agentOf(event, p.AgentID)), p.Attempts, truncate(p.Error, 60))
+	case models.EventTypeMessageRecovered:
+		var p models.MessageRecoveredPayload
+		decode(event, &p)
+		summary := fmt.Sprintf("%s to %s left in flight", itemType(p.ItemType), names.agent(agentOf(event, p.AgentID)))
+		if p.Action == models.InFlightDeadLettered {
+			return fmt.Sprintf("%s, dead-lettered after %d attempt(s)", summary, p.Attempts)
+		}
+		return summary + ", re-queued"
+	case models.EventTypeQueueItemDispatched:
+		var p models.QueueItemDispatchedPayload
+		decode(event, &p)
+		item := itemType(p.ItemType)
+		if p.QueueItemID != "" {
+			item += " " + shortID(p.QueueItemID)
+		}
+		summary := fmt.Sprintf("dispatched %s to %s", item, names.agent(agentOf(event, p.AgentID)))
+		if !p.Success {
+			if p.Duration != "" {
+				summary += " failed after " + p.Duration
+			} else {
+				summary += " failed"
+			}
+			return fmt.Sprintf("%s: %s", summary, truncate(p.Error, 60))
+		}
+		return withDetail(summary, inDuration(p.Duration))
+
+	case models.EventTypeApprovalRequested, models.EventTypeApprovalApproved, models.EventTypeApprovalDenied:
+		var p models.ApprovalPayload
+		decode(event, &p)
+		agent := names.agent(agentOf(event, p.AgentID))
+		switch event.Type {
+		case models.EventTypeApprovalRequested:
+			return withDetail(agent+" is waiting for approval", truncate(p.PromptExcerpt, 60))
+		case models.EventTypeApprovalApproved:
+			return withBy("approved "+agent, p.ResolvedBy)
+		default:
+			return withBy("denied "+agent, p.ResolvedBy)
+		}
+
+	case models.EventTypeReviewCompleted:
+		var p models.ReviewCompletedPayload
+		decode(event, &p)
+		agent := names.agent(agentOf(event, p.AgentID))
+		review := "review " + shortID(p.ReviewID)
+		switch {
+		case p.Verdict == models.ReviewVerdictApprove:
+			return fmt.Sprintf("%s approved %s", agent, review)
+		case p.Verdict == models.ReviewVerdictChanges:
+			return fmt.Sprintf("%s requested changes on %s (%d comments)", agent, review, p.Comments)
+		default:
+			return fmt.Sprintf("%s answered %s without a readable verdict", agent, review)
+		}
+
+	case models.EventTypeNoteAdded:
+		var p models.NoteAddedPayload
+		decode(event, &p)
+		subject := names.agent(event.EntityID)
+		if event.EntityType == models.EntityTypeWorkspace {
+			subject = names.workspace(event.EntityID)
+		}
+		return withBy("note on "+subject, p.Author) + ": " + truncate(p.Text, 60)
+
+	case models.EventTypeRateLimitDetected:
+		var p models.RateLimitPayload
+		decode(event, &p)
+		summary := fmt.Sprintf("rate limit on %s", names.account(accountOf(event, p.AccountID)))
+		if p.CooldownSeconds > 0 {
+			summary += fmt.Sprintf(", cooling down %s", time.Duration(p.CooldownSeconds)*time.Second)
+		}
+		return withDetail(summary, p.Reason)
+	case models.EventTypeCooldownStarted:
+		return names.account(event.EntityID) + " cooldown started"
+	case models.EventTypeCooldownEnded:
+		return names.account(event.EntityID) + " cooldown ended"
+	case models.EventTypeAccountRotated:
+		var p models.AccountRotatedPayload
+		decode(event, &p)
+		newAccount := p.NewAccountID
+		if newAccount == "" {
+			newAccount = event.EntityID
+		}
+		subject := "account"
+		if p.AgentID != "" {
+			subject = names.agent(p.AgentID)
+		}
+		summary := fmt.Sprintf("%s rotated to %s", subject, names.account(newAccount))
+		return withDetail(summary, strings.ReplaceAll(p.Reason, "_", " "))
+	case models.EventTypeCredentialExpiring:
+		var p models.CredentialExpiringPayload
+		decode(event, &p)
+		account := names.account(accountOf(event, p.AccountID))
+		if p.RemainingSeconds <= 0 {
+			return account + " credentials expired"
+		}
+		return fmt.Sprintf("%s credentials expire in %s", account, time.Duration(p.RemainingSeconds)*time.Second)
+
+	case models.EventTypeProviderHealthChanged:
+		var p models.ProviderHealthChangedPayload
+		decode(event, &p)
+		provider := string(p.Provider)
+		if provider == "" {
+			provider = event.EntityID
+		}
+		switch {
+		case p.Health == "":
+			return "provider " + provider + " health changed"
+		case p.Health == models.ProviderHealthHealthy && p.Previous.Worse(models.ProviderHealthHealthy):
+			return fmt.Sprintf("provider %s recovered (was %s)", provider, p.Previous)
+		}
+		return withDetail(fmt.Sprintf("provider %s %s", provider, p.Health), p.Reason)
+
+	case models.EventTypeWorkspaceCreated:
+		return names.workspace(event.EntityID) + " created"
+	case models.EventTypeWorkspaceImported:
+		return names.workspace(event.EntityID) + " imported"
+	case models.EventTypeWorkspaceDestroyed:
+		return names.workspace(event.EntityID) + " destroyed"
+	case models.EventTypeWorkspaceUnmanaged:
+		return names.workspace(event.EntityID) + " unmanaged"
+	case models.EventTypeWorkspacePaused:
+		return names.workspace(event.EntityID) + " paused"
+	case models.EventTypeWorkspaceResumed:
+		return names.workspace(event.EntityID) + " resumed"
+	case models.EventTypeWorkspaceIdle:
+		return names.workspace(event.EntityID) + " idle, all work finished"
+	case models.EventTypeWorkspaceBootstrapped:
+		var p models.WorkspaceBootstrappedPayload
+		decode(event, &p)
+		written := 0
+		for _, f := range p.Files {
+			if f.Status != models.BootstrapFileSkipped {
+				written++
+			}
+		}
+		return fmt.Sprintf("%s bootstrapped, %d/%d files written", names.workspace(event.EntityID), written, len(p.Files))
+
+	case models.EventTypeBudgetExceeded:
+		var p models.BudgetExceededPayload
+		decode(event, &p)
+		summary := fmt.Sprintf("%s spawn in %s over %s budget", p.Action, names.workspace(event.EntityID), p.Scope)
+		if p.CeilingCents > 0 {
+			summary += fmt.Sprintf(" ($%.2f > $%.2f)", float64(p.ProjectedCents)/100, float64(p.CeilingCents)/100)
+		}
+		return summary
+	case models.EventTypeUsageRecorded:
+		var p models.UsageRecordedPayload
+		decode(event, &p)
+		subject := names.account(p.AccountID)
+		if p.AgentID != "" {
+			subject = names.agent(p.AgentID)
+		}
+		return withDetail(fmt.Sprintf("%s used $%.2f", subject, float64(p.CostCents)/100), p.Model)
+
+	case models.EventTypeNodeOnline:
+		return names.node(event.EntityID) + " online"
+	case models.EventTypeNodeOffline:
+		return names.node(event.EntityID) + " offline"
+	case models.EventTypeNodeAdded:
+		return names.node(event.EntityID) + " added"
+	case models.EventTypeNodeRemoved:
+		return names.node(event.EntityID) + " removed"
+	case models.EventTypeNodeDegraded:
+		var p models.NodeDegradedPayload
+		decode(event, &p)
+		return withDetail(names.node(event.EntityID)+" degraded", strings.Join(p.Reasons, "; "))
+
+	case models.EventTypeError, models.EventTypeWarning:
+		var p models.ErrorPayload
+		decode(event, &p)
+		return withDetail(fmt.Sprintf("%s on %s %s", event.Type, event.EntityType, shortID(event.EntityID)), truncate(p.Error, 80))
+	}
+
+	return fmt.Sprintf("%s on %s %s", event.Type, event.EntityType, shortID(event.EntityID))
+}
+
+func decode(event *models.Event, payload any) {
+	if len(event.Payload) > 0 {
+		_ = json.Unmarshal(event.Payload, payload)
+	}
+}
+
+// agentOf returns the payload's agent ID, or the event entity if the event
+// is about an agent.
+func agentOf(event *models.Event, payloadID string) string {
+	if payloadID == "" && event.EntityType == models.EntityTypeAgent {
+		return event.EntityID
+	}
+	return payloadID
+}
+
+func accountOf(event *models.Event, payloadID string) string {
+	if payloadID == "" {
+		return event.EntityID
+	}
+	return payloadID
+}
+
+func itemType(t models.QueueItemType) string {
+	if t == "" || t == models.QueueItemTypeMessage {
+		return "message"
+	}
+	return strings.ReplaceAll(string(t), "_", " ")
+}
+
+func inDuration(d string) string {
+	if d == "" {
+		return ""
+	}
+	return "in " + d
+}
+
+func withDetail(summary, detail string) string {
+	if detail == "" {
+		return summary
+	}
+	return summary + " (" + detail + ")"
+}
+
+func withBy(summary, by string) string {
+	if by == "" {
+		return summary
+	}
+	return summary + " by " + by
+}
+
+func shortID(id string) string {
+	const limit = 8
+	if len(id) <= limit {
+		return id
+	}
+	return id[:limit]
+}
+
+func truncate(s string, maxLen int) string {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\n", " "))
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
@@ -0,0 +1,176 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestSummarize(t *testing.T) {
+	names := Names{
+		Agent: func(id string) string {
+			if id == "agent-with-a-long-id" {
+				return "parser-fix"
+			}
+			return ""
+		},
+		Account: func(id string) string {
+			if id == "acct-2" {
+				return "anthropic-work"
+			}
+			return ""
+		},
+	}
+	payload := func(v any) json.RawMessage {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("marshal payload: %v", err)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name  string
+		event *models.Event
+		want  string
+	}{
+		{
+			name: "agent spawned",
+			event: &models.Event{
+				Type:       models.EventTypeAgentSpawned,
+				EntityType: models.EntityTypeAgent,
+				EntityID:   "agent-with-a-long-id",
+				Payload:    payload(models.AgentSpawnedPayload{Type: models.AgentTypeClaudeCode}),
+			},
+			want: "spawned claude-code agent 'parser-fix'",
+		},
+		{
+			name: "agent snapshot",
+			event: &models.Event{
+				Type:       models.EventTypeAgentSnapshot,
+				EntityType: models.EntityTypeAgent,
+				EntityID:   "agent-with-a-long-id",
+				Payload:    payload(models.AgentSnapshotPayload{SnapshotID: "0f3c9a12-7b44", Hash: "abc", Note: "before refactor"}),
+			},
+			want: "snapshot 0f3c9a12 of agent 'parser-fix' (before refactor)",
+		},
+		{
+			name: "account rotated",
+			event: &models.Event{
+				Type:       models.EventTypeAccountRotated,
+				EntityType: models.EntityTypeAccount,
+				EntityID:   "acct-2",
+				Payload:    payload(models.AccountRotatedPayload{AgentID: "agent-with-a-long-id", OldAccountID: "acct-1", NewAccountID: "acct-2", Reason: "rate_limit"}),
+			},
+			want: "agent 'parser-fix' rotated to anthropic-work (rate limit)",
+		},
+		{
+			name: "credential expiring",
+			event: &models.Event{
+				Type:       models.EventTypeCredentialExpiring,
+				EntityType: models.EntityTypeAccount,
+				EntityID:   "acct-2",
+				Payload:    payload(models.CredentialExpiringPayload{AccountID: "acct-2", RemainingSeconds: 7200, ThresholdSeconds: 86400}),
+			},
+			want: "anthropic-work credentials expire in 2h0m0s",
+		},
+		{
+			name: "provider down",
+			event: &models.Event{
+				Type:       models.EventTypeProviderHealthChanged,
+				EntityType: models.EntityTypeSystem,
+				EntityID:   "anthropic",
+				Payload:    payload(models.ProviderHealthChangedPayload{Provider: models.ProviderAnthropic, Previous: models.ProviderHealthHealthy, Health: models.ProviderHealthDown, Reason: "API returned HTTP 529"}),
+			},
+			want: "provider anthropic down (API returned HTTP 529)",
+		},
+		{
+			name: "provider recovered",
+			event: &models.Event{
+				Type:       models.EventTypeProviderHealthChanged,
+				EntityType: models.EntityTypeSystem,
+				EntityID:   "anthropic",
+				Payload:    payload(models.ProviderHealthChangedPayload{Provider: models.ProviderAnthropic, Previous: models.ProviderHealthDown, Health: models.ProviderHealthHealthy}),
+			},
+			want: "provider anthropic recovered (was down)",
+		},
+		{
+			name: "note added",
+			event: &models.Event{
+				Type:       models.EventTypeNoteAdded,
+				EntityType: models.EntityTypeAgent,
+				EntityID:   "agent-with-a-long-id",
+				Payload:    payload(models.NoteAddedPayload{NoteID: "note-1", Author: "dana", Text: "retrying the flaky migration task,\ndon't kill it"}),
+			},
+			want: "note on agent 'parser-fix' by dana: retrying the flaky migration task, don't kill it",
+		},
+		{
+			name: "state changed falls back to a short ID",
+			event: &models.Event{
+				Type:       models.EventTypeAgentStateChanged,
+				EntityType: models.EntityTypeAgent,
+				EntityID:   "0123456789abcdef",
+				Payload:    payload(models.StateChangedPayload{OldState: models.AgentStateIdle, NewState: models.AgentStateWorking}),
+			},
+			want: "agent '01234567' idle -> working",
+		},
+		{
+			name: "successful dispatch",
+			event: &models.Event{
+				Type:       models.EventTypeQueueItemDispatched,
+				EntityType: models.EntityTypeAgent,
+				EntityID:   "agent-with-a-long-id",
+				Payload:    payload(models.QueueItemDispatchedPayload{QueueItemID: "3f9a2c1e-77b0", ItemType: models.QueueItemTypeMessage, Success: true, Duration: "12ms"}),
+			},
+			want: "dispatched message 3f9a2c1e to agent 'parser-fix' (in 12ms)",
+		},
+		{
+			name: "failed dispatch",
+			event: &models.Event{
+				Type:       models.EventTypeQueueItemDispatched,
+				EntityType: models.EntityTypeAgent,
+				EntityID:   "agent-with-a-long-id",
+				Payload:    payload(models.QueueItemDispatchedPayload{ItemType: models.QueueItemTypeMessage, Duration: "2s", Error: "pane gone"}),
+			},
+			want: "dispatched message to agent 'parser-fix' failed after 2s: pane gone",
+		},
+		{
+			name: "budget exceeded",
+			event: &models.Event{
+				Type:       models.EventTypeBudgetExceeded,
+				EntityType: models.EntityTypeWorkspace,
+				EntityID:   "ws-1",
+				Payload:    payload(models.BudgetExceededPayload{Scope: "workspace", ProjectedCents: 1240, CeilingCents: 1000, Action: models.BudgetActionRefused}),
+			},
+			want: "refused spawn in workspace 'ws-1' over workspace budget ($12.40 > $10.00)",
+		},
+		{
+			name: "malformed payload",
+			event: &models.Event{
+				Type:       models.EventTypeAgentSpawned,
+				EntityType: models.EntityTypeAgent,
+				EntityID:   "agent-1",
+				Payload:    json.RawMessage(`{`),
+			},
+			want: "spawned agent 'agent-1'",
+		},
+		{
+			name: "unknown type",
+			event: &models.Event{
+				Type:       models.EventType("custom.thing"),
+				EntityType: models.EntityTypeSystem,
+				EntityID:   "system",
+			},
+			want: "custom.thing on system system",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.event, names); got != tt.want {
+				t.Fatalf("Summarize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
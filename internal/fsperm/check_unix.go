@@ -0,0 +1,65 @@
+//go:build !windows
+
+package fsperm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Supported reports whether Check and Fix inspect permissions on this
+// platform.
+const Supported = true
+
+// currentUID is the user paths are expected to belong to.
+var currentUID = os.Geteuid
+
+// Check stats each path and returns those open to other users or owned by
+// one. Missing paths are skipped; Swarm creates them owner-only. Only the
+// paths themselves are checked: nothing under an owner-only directory is
+// reachable by other users.
+func Check(paths ...string) []Problem {
+	var problems []Problem
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		problem := Problem{Path: path, Mode: info.Mode().Perm()}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != currentUID() {
+			problem.ForeignOwner = true
+			problem.OwnerUID = int(stat.Uid)
+		}
+		if problem.ForeignOwner || problem.Loose() {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// Fix removes group and other access from each loose path. Paths owned by
+// another user cannot be fixed this way and are returned as errors.
+func Fix(problems []Problem) error {
+	var errs []error
+	for _, p := range problems {
+		if p.ForeignOwner {
+			errs = append(errs, fmt.Errorf("%s: owned by uid %d, change its owner with chown", p.Path, p.OwnerUID))
+			continue
+		}
+		if !p.Loose() {
+			continue
+		}
+		if err := os.Chmod(p.Path, p.Mode&^groupOtherBits); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
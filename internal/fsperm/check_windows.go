@@ -0,0 +1,17 @@
+//go:build windows
+
+package fsperm
+
+// Supported reports whether Check and Fix inspect permissions on this
+// platform. Windows controls access with ACLs, which are not checked.
+const Supported = false
+
+// Check returns no problems on Windows.
+func Check(paths ...string) []Problem {
+	return nil
+}
+
+// Fix does nothing on Windows.
+func Fix(problems []Problem) error {
+	return nil
+}
@@ -0,0 +1,131 @@
+// Package fsperm creates Swarm's data files with owner-only permissions and
+// checks existing ones for looser modes or a foreign owner.
+package fsperm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+// Modes of the directories and files Swarm creates.
+const (
+	DirMode  os.FileMode = 0o700
+	FileMode os.FileMode = 0o600
+)
+
+// groupOtherBits are the permission bits a data path must not have.
+const groupOtherBits os.FileMode = 0o077
+
+// ErrInsecure is returned by Verify in strict mode when a path is open to
+// other users or owned by one.
+var ErrInsecure = errors.New("insecure permissions")
+
+// Problem is a path open to other users or owned by one.
+type Problem struct {
+	Path string
+
+	// Mode holds the permission bits found.
+	Mode os.FileMode
+
+	// ForeignOwner is set when the path is owned by OwnerUID rather than
+	// the user Swarm runs as.
+	ForeignOwner bool
+	OwnerUID     int
+}
+
+// Loose reports whether group or other users have any access.
+func (p Problem) Loose() bool {
+	return p.Mode&groupOtherBits != 0
+}
+
+// String describes the problem.
+func (p Problem) String() string {
+	if p.ForeignOwner {
+		return fmt.Sprintf("%s is owned by uid %d, not the current user", p.Path, p.OwnerUID)
+	}
+	return fmt.Sprintf("%s has mode %04o, allowing access by other users", p.Path, p.Mode)
+}
+
+// Verify checks paths at startup and logs each problem as a warning. With
+// strict set, any problem fails with ErrInsecure instead.
+func Verify(logger zerolog.Logger, strict bool, paths ...string) error {
+	problems := Check(paths...)
+	if len(problems) == 0 {
+		return nil
+	}
+	if strict {
+		msg := problems[0].String()
+		if len(problems) > 1 {
+			msg = fmt.Sprintf("%s (and %d more)", msg, len(problems)-1)
+		}
+		return fmt.Errorf("%w: %s", ErrInsecure, msg)
+	}
+	for _, p := range problems {
+		logger.Warn().Str("path", p.Path).Msg(p.String() + "; run 'swarm doctor --fix-permissions'")
+	}
+	return nil
+}
+
+// MkdirAll creates dir and any missing parents with DirMode. A directory
+// that already exists is left as it is.
+func MkdirAll(dir string) error {
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return nil
+	}
+	if err := os.MkdirAll(dir, DirMode); err != nil {
+		return err
+	}
+	// MkdirAll applies the umask; set the mode outright.
+	return os.Chmod(dir, DirMode)
+}
+
+// OpenFile opens path like os.OpenFile. With os.O_CREATE the file is set to
+// FileMode, whatever the umask and whether or not it existed.
+func OpenFile(path string, flag int) (*os.File, error) {
+	file, err := os.OpenFile(path, flag, FileMode)
+	if err != nil {
+		return nil, err
+	}
+	if flag&os.O_CREATE != 0 {
+		if err := file.Chmod(FileMode); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// Create creates or truncates path with FileMode.
+func Create(path string) (*os.File, error) {
+	return OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC)
+}
+
+// WriteFile writes data to path, creating it with FileMode.
+func WriteFile(path string, data []byte) error {
+	file, err := OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// EnsureFile creates path empty with FileMode when it does not exist, so a
+// file another library creates later, such as a SQLite database, starts
+// out owner-only. An existing file is left as it is.
+func EnsureFile(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	file, err := OpenFile(path, os.O_RDWR|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,192 @@
+//go:build !windows
+
+package fsperm
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func mode(t *testing.T, path string) os.FileMode {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat %s: %v", path, err)
+	}
+	return info.Mode().Perm()
+}
+
+func TestCheckAndFix(t *testing.T) {
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "data")
+	dbPath := filepath.Join(dataDir, "swarm.db")
+	privateDir := filepath.Join(root, "vault")
+	privateFile := filepath.Join(privateDir, "vault.salt")
+	if err := os.Mkdir(dataDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dbPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(privateDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(privateFile, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Unix socket paths are limited to about 100 bytes, shorter than
+	// some temp dirs.
+	socketDir, err := os.MkdirTemp("", "fsperm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+	socketPath := filepath.Join(socketDir, "events.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	if err := os.Chmod(socketPath, 0o777); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{dataDir, dbPath, privateDir, privateFile, socketPath, filepath.Join(root, "missing"), dataDir}
+	problems := Check(paths...)
+	if len(problems) != 3 {
+		t.Fatalf("expected the data dir, database and socket, got %+v", problems)
+	}
+	for i, want := range []string{dataDir, dbPath, socketPath} {
+		if problems[i].Path != want || !problems[i].Loose() || problems[i].ForeignOwner {
+			t.Errorf("problem %d: got %+v, want loose %s", i, problems[i], want)
+		}
+	}
+	if !strings.Contains(problems[0].String(), "mode 0755") {
+		t.Errorf("unexpected description %q", problems[0].String())
+	}
+
+	if err := Fix(problems); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+	if got := Check(paths...); len(got) != 0 {
+		t.Fatalf("expected no problems after Fix, got %+v", got)
+	}
+	for path, want := range map[string]os.FileMode{dataDir: 0o700, dbPath: 0o600, socketPath: 0o700} {
+		if got := mode(t, path); got != want {
+			t.Errorf("%s: mode %04o, want %04o", path, got, want)
+		}
+	}
+}
+
+func TestCheckForeignOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swarm.db")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	uid := os.Geteuid()
+	currentUID = func() int { return uid + 1 }
+	t.Cleanup(func() { currentUID = os.Geteuid })
+
+	problems := Check(path)
+	if len(problems) != 1 || !problems[0].ForeignOwner || problems[0].OwnerUID != uid || problems[0].Loose() {
+		t.Fatalf("expected a foreign owner, got %+v", problems)
+	}
+	if err := Fix(problems); err == nil || !strings.Contains(err.Error(), "chown") {
+		t.Fatalf("expected Fix to refuse a foreign owner, got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	if err := os.Mkdir(dir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(zerolog.Nop(), false, dir); err != nil {
+		t.Fatalf("expected a warning only, got %v", err)
+	}
+	if err := Verify(zerolog.Nop(), true, dir); !errors.Is(err, ErrInsecure) {
+		t.Fatalf("expected ErrInsecure in strict mode, got %v", err)
+	}
+	if err := os.Chmod(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(zerolog.Nop(), true, dir); err != nil {
+		t.Fatalf("expected no error for an owner-only dir, got %v", err)
+	}
+}
+
+func TestCreateIgnoresUmask(t *testing.T) {
+	old := syscall.Umask(0)
+	t.Cleanup(func() { syscall.Umask(old) })
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "a", "b")
+	if err := MkdirAll(dir); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	written := filepath.Join(dir, "context.yaml")
+	if err := WriteFile(written, []byte("x")); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	created := filepath.Join(dir, "events.jsonl")
+	file, err := Create(created)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	_ = file.Close()
+	ensured := filepath.Join(dir, "swarm.db")
+	if err := EnsureFile(ensured); err != nil {
+		t.Fatalf("EnsureFile: %v", err)
+	}
+
+	// A file left behind with a loose mode is tightened when reopened for
+	// writing.
+	appended := filepath.Join(dir, "archive.jsonl")
+	if err := os.WriteFile(appended, nil, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	file, err = OpenFile(appended, os.O_CREATE|os.O_APPEND|os.O_WRONLY)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	_ = file.Close()
+
+	for path, want := range map[string]os.FileMode{
+		dir:      DirMode,
+		written:  FileMode,
+		created:  FileMode,
+		ensured:  FileMode,
+		appended: FileMode,
+	} {
+		if got := mode(t, path); got != want {
+			t.Errorf("%s: mode %04o, want %04o", path, got, want)
+		}
+	}
+
+	// Existing directories and files are left alone.
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(ensured, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := MkdirAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureFile(ensured); err != nil {
+		t.Fatal(err)
+	}
+	if got := mode(t, dir); got != 0o755 {
+		t.Errorf("existing dir changed to %04o", got)
+	}
+	if got := mode(t, ensured); got != 0o644 {
+		t.Errorf("existing file changed to %04o", got)
+	}
+}
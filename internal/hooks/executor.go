@@ -86,6 +86,12 @@ func (e *Executor) sendWebhook(ctx context.Context, hook Hook, event *models.Eve
 	if err != nil {
 		return err
 	}
+	if hook.Template != "" {
+		payload, err = renderTemplate(hook.Template, payload)
+		if err != nil {
+			return err
+		}
+	}
 
 	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
 	if err != nil {
@@ -27,6 +27,10 @@ type Hook struct {
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
 
+	// Template, when set, renders the webhook body instead of posting the
+	// event JSON; see ParseTemplate.
+	Template string `json:"template,omitempty"`
+
 	EventTypes  []models.EventType  `json:"event_types,omitempty"`
 	EntityTypes []models.EntityType `json:"entity_types,omitempty"`
 	EntityID    string              `json:"entity_id,omitempty"`
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/opencode-ai/swarm/internal/fsperm"
 )
 
 type hookFile struct {
@@ -94,7 +96,7 @@ func (s *Store) saveLocked(hooks []Hook) error {
 	}
 
 	dir := filepath.Dir(s.path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := fsperm.MkdirAll(dir); err != nil {
 		return fmt.Errorf("failed to create hook store directory: %w", err)
 	}
 
@@ -0,0 +1,48 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+)
+
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON, so strings are quoted and escaped for a
+	// JSON body.
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// ParseTemplate parses a webhook body template. Templates use Go
+// text/template syntax over the event as it is serialized to JSON, so
+// fields go by their JSON names: {{.type}}, {{.entity_id}}, {{.payload.name}}.
+// The json function quotes a value, as in {"text": {{json .type}}}.
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("webhook").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderTemplate renders text over the event JSON in eventJSON.
+func renderTemplate(text string, eventJSON []byte) ([]byte, error) {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(eventJSON, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode event for template: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	return out.Bytes(), nil
+}
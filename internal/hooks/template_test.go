@@ -0,0 +1,59 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	event := &models.Event{
+		Type:       models.EventTypeWorkspaceIdle,
+		EntityType: models.EntityTypeWorkspace,
+		EntityID:   "ws-1",
+		Payload:    json.RawMessage(`{"name":"api \"main\"","agents":2}`),
+	}
+	eventJSON, err := marshalEvent(event)
+	if err != nil {
+		t.Fatalf("marshalEvent: %v", err)
+	}
+
+	slack := `{"text": {{json (printf "%s: %s (%v agents)" .type .payload.name .payload.agents)}}}`
+	out, err := renderTemplate(slack, eventJSON)
+	if err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(out, &body); err != nil {
+		t.Fatalf("expected valid JSON, got %s: %v", out, err)
+	}
+	if want := `workspace.idle: api "main" (2 agents)`; body["text"] != want {
+		t.Fatalf("text = %q, want %q", body["text"], want)
+	}
+
+	if _, err := ParseTemplate("{{.type"); err == nil {
+		t.Fatal("expected a malformed template to be rejected")
+	}
+}
+
+func TestSendWebhookUsesTemplate(t *testing.T) {
+	var got []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	hook := Hook{Kind: KindWebhook, URL: server.URL, Template: `{"text": {{json .entity_id}}}`}
+	event := &models.Event{Type: models.EventTypeWorkspaceCreated, EntityType: models.EntityTypeWorkspace, EntityID: "ws-1"}
+	if err := NewExecutor().Execute(context.Background(), hook, event); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if string(got) != `{"text": "ws-1"}` {
+		t.Fatalf("unexpected webhook body %s", got)
+	}
+}
@@ -75,7 +75,7 @@ func Init(cfg Config) {
 		ctx = ctx.Caller()
 	}
 
-	Logger = ctx.Logger()
+	Logger = ctx.Logger().Hook(TraceHook{})
 }
 
 // parseLevel converts a string level to zerolog.Level.
@@ -103,12 +103,14 @@ func WithContext(ctx context.Context, logger zerolog.Logger) context.Context {
 	return context.WithValue(ctx, loggerKey, logger)
 }
 
-// FromContext returns the logger from the context, or the global logger.
+// FromContext returns the logger from the context, or the global logger,
+// bound to ctx so its lines carry ctx's trace ID.
 func FromContext(ctx context.Context) zerolog.Logger {
-	if logger, ok := ctx.Value(loggerKey).(zerolog.Logger); ok {
-		return logger
+	logger := Logger
+	if l, ok := ctx.Value(loggerKey).(zerolog.Logger); ok {
+		logger = l
 	}
-	return Logger
+	return logger.With().Ctx(ctx).Logger()
 }
 
 // With creates a child logger with additional fields.
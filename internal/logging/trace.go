@@ -0,0 +1,21 @@
+package logging
+
+import (
+	"github.com/opencode-ai/swarm/internal/trace"
+	"github.com/rs/zerolog"
+)
+
+// TraceIDField is the log field holding the trace ID.
+const TraceIDField = "trace_id"
+
+// TraceHook adds the trace ID of an event's context to the log line. Log
+// calls pass their context with Ctx(ctx), or inherit the logger's. The
+// global Logger has it; add it to loggers built with zerolog.New.
+type TraceHook struct{}
+
+// Run implements zerolog.Hook.
+func (TraceHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if id := trace.FromContext(e.GetCtx()); id != "" {
+		e.Str(TraceIDField, id)
+	}
+}
@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/trace"
+	"github.com/rs/zerolog"
+)
+
+func TestTraceHook(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Hook(TraceHook{})
+	ctx := trace.WithID(context.Background(), "run-1")
+
+	logger.Info().Ctx(ctx).Msg("explicit")
+	inherited := FromContext(WithContext(ctx, logger))
+	inherited.Info().Msg("inherited")
+	logger.Info().Msg("untraced")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 log lines, got %q", buf.String())
+	}
+	for _, line := range lines[:2] {
+		if !strings.Contains(line, `"trace_id":"run-1"`) {
+			t.Errorf("expected trace ID on %s", line)
+		}
+	}
+	if strings.Contains(lines[2], "trace_id") {
+		t.Errorf("expected no trace ID on %s", lines[2])
+	}
+}
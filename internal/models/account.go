@@ -22,6 +22,9 @@ type Account struct {
 	// Provider identifies the AI provider.
 	Provider Provider `json:"provider"`
 
+	// ProviderName names the provider of a ProviderCustom account.
+	ProviderName string `json:"provider_name,omitempty"`
+
 	// ProfileName is the human-friendly name for this account.
 	ProfileName string `json:"profile_name"`
 
@@ -37,6 +40,15 @@ type Account struct {
 	// UsageStats contains usage information for this account.
 	UsageStats *UsageStats `json:"usage_stats,omitempty"`
 
+	// CredentialExpiresAt is when the account's credential (e.g. an OAuth
+	// token in a caam profile) expires, if known.
+	CredentialExpiresAt *time.Time `json:"credential_expires_at,omitempty"`
+
+	// CredentialExpiryWarnedAt is when an expiry warning was last raised
+	// for the current CredentialExpiresAt. It is cleared when the expiry
+	// changes.
+	CredentialExpiryWarnedAt *time.Time `json:"credential_expiry_warned_at,omitempty"`
+
 	// CreatedAt is when the account was added.
 	CreatedAt time.Time `json:"created_at"`
 
@@ -157,9 +169,7 @@ func CheckLimits(usage *UsageSummary, limits *UsageLimits) *UsageLimitStatus {
 // Validate checks if the account configuration is valid.
 func (a *Account) Validate() error {
 	validation := &ValidationErrors{}
-	if a.Provider == "" {
-		validation.Add("provider", ErrInvalidProvider)
-	}
+	validateProvider(validation, a.Provider, a.ProviderName)
 	if a.ProfileName == "" {
 		validation.Add("profile_name", ErrInvalidProfileName)
 	}
@@ -182,6 +192,15 @@ func (a *Account) CooldownRemaining() time.Duration {
 	return time.Until(*a.CooldownUntil)
 }
 
+// CredentialExpiresIn returns how long until the account's credential
+// expires (negative once it has), and false if the expiry is unknown.
+func (a *Account) CredentialExpiresIn(now time.Time) (time.Duration, bool) {
+	if a.CredentialExpiresAt == nil {
+		return 0, false
+	}
+	return a.CredentialExpiresAt.Sub(now), true
+}
+
 // IsAvailable returns true if the account can be used.
 func (a *Account) IsAvailable() bool {
 	return a.IsActive && !a.IsOnCooldown()
@@ -2,6 +2,8 @@ package models
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -17,6 +19,8 @@ const (
 	AgentStatePaused           AgentState = "paused"
 	AgentStateStarting         AgentState = "starting"
 	AgentStateStopped          AgentState = "stopped"
+	AgentStateStuck            AgentState = "stuck"
+	AgentStateHibernated       AgentState = "hibernated"
 )
 
 // StateConfidence indicates how confident Swarm is about the detected state.
@@ -64,9 +68,17 @@ type Agent struct {
 	// Type identifies the agent CLI being used.
 	Type AgentType `json:"type"`
 
-	// TmuxPane is the tmux pane target (session:window.pane).
+	// TmuxPane is the tmux pane ID (e.g. "%12"). Pane IDs stay fixed when
+	// panes are moved or windows renumbered, unlike session:window.pane.
 	TmuxPane string `json:"tmux_pane"`
 
+	// TmuxSession is the tmux session the pane is expected to live in.
+	TmuxSession string `json:"tmux_session,omitempty"`
+
+	// RemoteAgentID is the agent's ID on its node's swarmd, for agents on
+	// daemon-backed nodes. TmuxPane is then a pane on that node.
+	RemoteAgentID string `json:"remote_agent_id,omitempty"`
+
 	// AccountID references the account profile being used.
 	AccountID string `json:"account_id,omitempty"`
 
@@ -82,9 +94,21 @@ type Agent struct {
 	// LastActivity is the timestamp of the last detected activity.
 	LastActivity *time.Time `json:"last_activity,omitempty"`
 
+	// LastOutputAt is when the agent's pane output last changed. Unlike
+	// LastActivity, input sent to the agent does not bump it.
+	LastOutputAt *time.Time `json:"last_output_at,omitempty"`
+
 	// PausedUntil is when the agent will auto-resume (if paused).
 	PausedUntil *time.Time `json:"paused_until,omitempty"`
 
+	// Standby marks a warm agent kept idle for work from its workspace's
+	// queue. It is cleared when the agent is claimed.
+	Standby bool `json:"standby,omitempty"`
+
+	// Draining marks an agent that finishes its queued work but accepts no
+	// new queue items.
+	Draining bool `json:"draining,omitempty"`
+
 	// Metadata contains additional agent information.
 	Metadata AgentMetadata `json:"metadata,omitempty"`
 
@@ -93,6 +117,18 @@ type Agent struct {
 
 	// UpdatedAt is when the agent was last updated.
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// DeletedAt is when the agent was terminated. Terminated agents are
+	// kept for history until agent retention prunes them.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// TerminalState is the state the agent was in when it was terminated.
+	TerminalState AgentState `json:"terminal_state,omitempty"`
+}
+
+// IsTerminated reports whether the agent has been terminated.
+func (a *Agent) IsTerminated() bool {
+	return a.DeletedAt != nil
 }
 
 // StateInfo contains detailed information about the current state.
@@ -124,9 +160,24 @@ type AgentMetadata struct {
 	// StartCommand is the command used to spawn the agent.
 	StartCommand string `json:"start_command,omitempty"`
 
+	// CLIVersion is the agent CLI version reported at spawn (if probed).
+	CLIVersion string `json:"cli_version,omitempty"`
+
+	// InstalledCLIVersion is the version of the CLI installed on the node
+	// when it was last probed, which differs from CLIVersion once the CLI
+	// is upgraded under a running agent.
+	InstalledCLIVersion string `json:"installed_cli_version,omitempty"`
+
+	// CLIVersionCheckedAt is when InstalledCLIVersion was probed.
+	CLIVersionCheckedAt *time.Time `json:"cli_version_checked_at,omitempty"`
+
 	// Environment contains environment variable overrides.
 	Environment map[string]string `json:"environment,omitempty"`
 
+	// ResolvedEnv records the environment the agent was spawned with, each
+	// variable annotated with its source and secrets redacted.
+	ResolvedEnv []EnvVar `json:"resolved_env,omitempty"`
+
 	// ApprovalPolicy captures the effective approval policy for the agent.
 	ApprovalPolicy string `json:"approval_policy,omitempty"`
 
@@ -142,6 +193,161 @@ type AgentMetadata struct {
 	// OpenCode contains connection details for OpenCode server integration.
 	// Only populated for agents of type AgentTypeOpenCode.
 	OpenCode *OpenCodeConnection `json:"opencode,omitempty"`
+
+	// Recording is set while the agent's pane is being recorded.
+	Recording *RecordingInfo `json:"recording,omitempty"`
+
+	// Failure classifies the error an agent in the error state hit.
+	Failure *FailureClassification `json:"failure,omitempty"`
+
+	// Tags are free-form labels set at spawn.
+	Tags []string `json:"tags,omitempty"`
+
+	// Ephemeral marks a one-shot agent spawned by 'swarm run', hidden from
+	// agent lists by default.
+	Ephemeral bool `json:"ephemeral,omitempty"`
+
+	// QuietHours is the agent's own quiet hours spec, overriding its
+	// workspace's and the global setting; "none" turns quiet hours off.
+	QuietHours string `json:"quiet_hours,omitempty"`
+
+	// Hibernation is set while the agent is hibernated.
+	Hibernation *HibernationInfo `json:"hibernation,omitempty"`
+}
+
+// HibernationInfo records how a hibernated agent is woken.
+type HibernationInfo struct {
+	// Checkpoint is the session checkpoint restored on wake.
+	Checkpoint string `json:"checkpoint"`
+
+	// Pane is the pane the agent held before it was hibernated.
+	Pane string `json:"pane,omitempty"`
+
+	// HibernatedAt is when the agent was hibernated.
+	HibernatedAt time.Time `json:"hibernated_at"`
+}
+
+// NormalizeTags trims and lowercases tags, dropping empty and duplicate ones.
+func NormalizeTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
+
+// HasTags reports whether the agent carries every one of tags.
+func (a *Agent) HasTags(tags []string) bool {
+	for _, tag := range NormalizeTags(tags) {
+		if !slices.Contains(a.Metadata.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// EnvSource names where a spawn environment variable came from.
+type EnvSource string
+
+const (
+	EnvSourceWorkspace EnvSource = "workspace"
+	EnvSourceTemplate  EnvSource = "template"
+	EnvSourceFlag      EnvSource = "flag"
+	EnvSourceAccount   EnvSource = "account"
+)
+
+// EnvVar is one variable of the environment an agent was spawned with.
+type EnvVar struct {
+	Name string `json:"name"`
+
+	// Value is the variable's value, or its redaction marker when Secret.
+	Value string `json:"value"`
+
+	// Source is the source whose value won.
+	Source EnvSource `json:"source"`
+
+	// Overrides lists the lower-precedence sources that also set the
+	// variable.
+	Overrides []EnvSource `json:"overrides,omitempty"`
+
+	// Secret is set when Value was redacted.
+	Secret bool `json:"secret,omitempty"`
+
+	// Hash is the hex SHA-256 of the real value, so it can be compared
+	// without being revealed.
+	Hash string `json:"hash"`
+}
+
+// FailureCategory says what kind of error an agent failed with.
+type FailureCategory string
+
+const (
+	FailureCategoryAuth          FailureCategory = "auth"
+	FailureCategoryRateLimit     FailureCategory = "rate_limit"
+	FailureCategoryContextLength FailureCategory = "context_length"
+	FailureCategoryNetwork       FailureCategory = "network"
+	FailureCategoryTask          FailureCategory = "task"
+	FailureCategoryUnknown       FailureCategory = "unknown"
+)
+
+// FailureSeverity says how likely an agent is to recover on its own.
+type FailureSeverity string
+
+const (
+	// FailureSeverityCritical failures need a human or a new account.
+	FailureSeverityCritical FailureSeverity = "critical"
+	// FailureSeverityError failures need the task or session changed.
+	FailureSeverityError FailureSeverity = "error"
+	// FailureSeverityTransient failures usually clear on a retry.
+	FailureSeverityTransient FailureSeverity = "transient"
+)
+
+// FailureAction is the remedy suggested for a failure.
+type FailureAction string
+
+const (
+	FailureActionRotateAccount  FailureAction = "rotate_account"
+	FailureActionWait           FailureAction = "wait"
+	FailureActionRestart        FailureAction = "restart"
+	FailureActionCompactContext FailureAction = "compact_context"
+	FailureActionInspect        FailureAction = "inspect"
+)
+
+// FailureClassification is the result of matching an agent's pane output
+// against the failure rules.
+type FailureClassification struct {
+	Category FailureCategory `json:"category"`
+	Severity FailureSeverity `json:"severity"`
+	Action   FailureAction   `json:"action"`
+
+	// Summary is a short description of the failure.
+	Summary string `json:"summary"`
+
+	// Excerpt is the redacted output line that matched.
+	Excerpt string `json:"excerpt,omitempty"`
+
+	// ClassifiedAt is when the output was classified.
+	ClassifiedAt time.Time `json:"classified_at"`
+}
+
+// String renders the classification as a one-line reason.
+func (f *FailureClassification) String() string {
+	return fmt.Sprintf("%s failure: %s (%s; suggested action: %s)", f.Category, f.Summary, f.Severity, f.Action)
+}
+
+// RecordingInfo describes an active pane recording.
+type RecordingInfo struct {
+	// Path is the cast file the pane output is written to.
+	Path string `json:"path"`
+
+	// StartedAt is when recording began.
+	StartedAt time.Time `json:"started_at"`
 }
 
 // UsageMetrics contains usage metrics captured from an agent runtime.
@@ -301,7 +507,7 @@ func (a *Agent) IsActive() bool {
 // IsBlocked returns true if the agent is blocked and cannot accept work.
 func (a *Agent) IsBlocked() bool {
 	switch a.State {
-	case AgentStateAwaitingApproval, AgentStateRateLimited, AgentStateError, AgentStatePaused:
+	case AgentStateAwaitingApproval, AgentStateRateLimited, AgentStateError, AgentStatePaused, AgentStateStuck:
 		return true
 	default:
 		return false
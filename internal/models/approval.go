@@ -19,6 +19,26 @@ const (
 // ApprovalRequestType describes the kind of approval requested.
 type ApprovalRequestType string
 
+const (
+	// ApprovalRequestTypePrompt is an interactive prompt detected in an agent pane.
+	ApprovalRequestTypePrompt ApprovalRequestType = "prompt"
+)
+
+// PromptApprovalDetails is the request_details payload for prompt approvals.
+type PromptApprovalDetails struct {
+	// PromptExcerpt is the pane content surrounding the prompt.
+	PromptExcerpt string `json:"prompt_excerpt"`
+
+	// Pane is the tmux pane the prompt was captured from.
+	Pane string `json:"pane,omitempty"`
+
+	// Reason is the state detector's explanation for the approval state.
+	Reason string `json:"reason,omitempty"`
+
+	// DetectedAt is when the approval state was detected.
+	DetectedAt time.Time `json:"detected_at"`
+}
+
 // Approval captures an approval request from an agent.
 type Approval struct {
 	// ID is the unique identifier for the approval.
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// AuditResult is the outcome of an audited operation.
+type AuditResult string
+
+const (
+	AuditResultSuccess AuditResult = "success"
+	AuditResultFailure AuditResult = "failure"
+)
+
+// AuditRecord records who performed a mutating operation, on what, and
+// how it ended. Audit records are kept apart from the event stream.
+type AuditRecord struct {
+	// ID is the unique identifier for the record.
+	ID string `json:"id"`
+
+	// Timestamp is when the operation finished.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Actor is who performed the operation: the OS user for local
+	// commands, or the auth token subject for swarmd calls.
+	Actor string `json:"actor"`
+
+	// Operation names what was done, e.g. spawn_agent or kill_agent.
+	Operation string `json:"operation"`
+
+	// EntityType and EntityID identify what the operation acted on. They
+	// are empty for operations on no single entity.
+	EntityType EntityType `json:"entity_type,omitempty"`
+	EntityID   string     `json:"entity_id,omitempty"`
+
+	// Parameters are the operation's inputs, with secrets redacted.
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// Result is whether the operation succeeded.
+	Result AuditResult `json:"result"`
+
+	// Error is the failure message, with secrets redacted.
+	Error string `json:"error,omitempty"`
+
+	// TraceID correlates the record with the events of the same command
+	// or RPC.
+	TraceID string `json:"trace_id,omitempty"`
+}
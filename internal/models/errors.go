@@ -6,7 +6,7 @@ import "errors"
 var (
 	// Node errors
 	ErrInvalidNodeName  = errors.New("node name is required")
-	ErrInvalidSSHTarget = errors.New("SSH target is required for remote nodes")
+	ErrInvalidSSHTarget = errors.New("SSH target or daemon endpoint is required for remote nodes")
 
 	// Workspace errors
 	ErrInvalidWorkspaceNode = errors.New("workspace must be associated with a node")
@@ -23,6 +23,8 @@ var (
 	ErrEmptyQueue       = errors.New("queue is empty")
 
 	// Account errors
-	ErrInvalidProvider    = errors.New("provider is required")
-	ErrInvalidProfileName = errors.New("profile name is required")
+	ErrInvalidProvider     = errors.New("provider is required")
+	ErrUnknownProvider     = errors.New("unknown provider")
+	ErrInvalidProviderName = errors.New("invalid provider name")
+	ErrInvalidProfileName  = errors.New("profile name is required")
 )
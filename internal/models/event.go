@@ -11,16 +11,21 @@ type EventType string
 
 const (
 	// Node events
-	EventTypeNodeOnline  EventType = "node.online"
-	EventTypeNodeOffline EventType = "node.offline"
-	EventTypeNodeAdded   EventType = "node.added"
-	EventTypeNodeRemoved EventType = "node.removed"
+	EventTypeNodeOnline   EventType = "node.online"
+	EventTypeNodeOffline  EventType = "node.offline"
+	EventTypeNodeAdded    EventType = "node.added"
+	EventTypeNodeRemoved  EventType = "node.removed"
+	EventTypeNodeDegraded EventType = "node.degraded"
 
 	// Workspace events
-	EventTypeWorkspaceCreated   EventType = "workspace.created"
-	EventTypeWorkspaceImported  EventType = "workspace.imported"
-	EventTypeWorkspaceDestroyed EventType = "workspace.destroyed"
-	EventTypeWorkspaceUnmanaged EventType = "workspace.unmanaged"
+	EventTypeWorkspaceCreated      EventType = "workspace.created"
+	EventTypeWorkspaceImported     EventType = "workspace.imported"
+	EventTypeWorkspaceDestroyed    EventType = "workspace.destroyed"
+	EventTypeWorkspaceUnmanaged    EventType = "workspace.unmanaged"
+	EventTypeWorkspacePaused       EventType = "workspace.paused"
+	EventTypeWorkspaceResumed      EventType = "workspace.resumed"
+	EventTypeWorkspaceIdle         EventType = "workspace.idle"
+	EventTypeWorkspaceBootstrapped EventType = "workspace.bootstrapped"
 
 	// Agent events
 	EventTypeAgentSpawned      EventType = "agent.spawned"
@@ -29,23 +34,51 @@ const (
 	EventTypeAgentTerminated   EventType = "agent.terminated"
 	EventTypeAgentPaused       EventType = "agent.paused"
 	EventTypeAgentResumed      EventType = "agent.resumed"
+	EventTypeAgentStuck        EventType = "agent.stuck"
+	EventTypeAgentRecovery     EventType = "agent.recovery"
+	EventTypeAgentMoved        EventType = "agent.moved"
+	EventTypeAgentSnapshot     EventType = "agent.snapshot"
+	EventTypeAgentClaimed      EventType = "agent.standby_claimed"
+	EventTypeAgentCheckpointed EventType = "agent.checkpointed"
+	EventTypeAgentRestored     EventType = "agent.restored"
+	EventTypeAgentDrained      EventType = "agent.drained"
+	EventTypeAgentHibernated   EventType = "agent.hibernated"
+	EventTypeAgentWoken        EventType = "agent.woken"
 
 	// Message events
 	EventTypeMessageQueued     EventType = "message.queued"
 	EventTypeMessageDispatched EventType = "message.dispatched"
 	EventTypeMessageCompleted  EventType = "message.completed"
 	EventTypeMessageFailed     EventType = "message.failed"
+	EventTypeMessageRecovered  EventType = "message.recovered"
+
+	// Scheduler events
+	EventTypeQueueItemDispatched EventType = "queue.item_dispatched"
 
 	// Approval events
 	EventTypeApprovalRequested EventType = "approval.requested"
 	EventTypeApprovalApproved  EventType = "approval.approved"
 	EventTypeApprovalDenied    EventType = "approval.denied"
 
+	// Review events
+	EventTypeReviewCompleted EventType = "review.completed"
+
+	// Note events
+	EventTypeNoteAdded EventType = "note.added"
+
 	// Account events
-	EventTypeRateLimitDetected EventType = "rate_limit.detected"
-	EventTypeCooldownStarted   EventType = "cooldown.started"
-	EventTypeCooldownEnded     EventType = "cooldown.ended"
-	EventTypeAccountRotated    EventType = "account.rotated"
+	EventTypeRateLimitDetected  EventType = "rate_limit.detected"
+	EventTypeCooldownStarted    EventType = "cooldown.started"
+	EventTypeCooldownEnded      EventType = "cooldown.ended"
+	EventTypeAccountRotated     EventType = "account.rotated"
+	EventTypeCredentialExpiring EventType = "account.credential_expiring"
+
+	// Provider events
+	EventTypeProviderHealthChanged EventType = "provider.health_changed"
+
+	// Budget events
+	EventTypeBudgetExceeded EventType = "budget.exceeded"
+	EventTypeUsageRecorded  EventType = "usage.recorded"
 
 	// System events
 	EventTypeError   EventType = "error"
@@ -86,6 +119,10 @@ type Event struct {
 
 	// Metadata contains additional context.
 	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// TraceID correlates the event with the command or RPC that caused
+	// it.
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 // Validate checks if the event is valid.
@@ -103,12 +140,113 @@ func (e *Event) Validate() error {
 	return validation.Err()
 }
 
+// NodeDegradedPayload is the payload for node.degraded events.
+type NodeDegradedPayload struct {
+	Reasons []string `json:"reasons"`
+}
+
+// WorkspacePayload is the payload for workspace lifecycle events.
+type WorkspacePayload struct {
+	Name        string `json:"name"`
+	NodeID      string `json:"node_id"`
+	RepoPath    string `json:"repo_path"`
+	TmuxSession string `json:"tmux_session,omitempty"`
+}
+
+// WorkspaceBootstrappedPayload is the payload for workspace.bootstrapped
+// events.
+type WorkspaceBootstrappedPayload struct {
+	Name  string                `json:"name"`
+	Files []BootstrapFileResult `json:"files"`
+}
+
+// WorkspaceIdlePayload is the payload for workspace.idle events, recorded
+// when the last working agent of a workspace has gone idle with nothing
+// left queued.
+type WorkspaceIdlePayload struct {
+	Name   string `json:"name"`
+	Agents int    `json:"agents"`
+	// IdleSince is when the last working agent went idle.
+	IdleSince time.Time `json:"idle_since"`
+}
+
+// AgentSpawnedPayload is the payload for agent.spawned events.
+type AgentSpawnedPayload struct {
+	WorkspaceID string    `json:"workspace_id"`
+	Type        AgentType `json:"type"`
+	AccountID   string    `json:"account_id,omitempty"`
+}
+
 // StateChangedPayload is the payload for agent.state_changed events.
 type StateChangedPayload struct {
-	OldState   AgentState      `json:"old_state"`
-	NewState   AgentState      `json:"new_state"`
-	Confidence StateConfidence `json:"confidence"`
-	Reason     string          `json:"reason"`
+	OldState   AgentState             `json:"old_state"`
+	NewState   AgentState             `json:"new_state"`
+	Confidence StateConfidence        `json:"confidence"`
+	Reason     string                 `json:"reason"`
+	Failure    *FailureClassification `json:"failure,omitempty"`
+}
+
+// AgentStuckPayload is the payload for agent.stuck events.
+type AgentStuckPayload struct {
+	LastOutputAt  time.Time `json:"last_output_at"`
+	SilentSeconds float64   `json:"silent_seconds"`
+	Reason        string    `json:"reason"`
+}
+
+// AgentMovedPayload is the payload for agent.moved events.
+type AgentMovedPayload struct {
+	FromWorkspaceID string `json:"from_workspace_id"`
+	ToWorkspaceID   string `json:"to_workspace_id"`
+	TmuxPane        string `json:"tmux_pane"`
+}
+
+// AgentClaimedPayload is the payload for agent.standby_claimed events.
+type AgentClaimedPayload struct {
+	WorkspaceID string    `json:"workspace_id"`
+	Type        AgentType `json:"type"`
+}
+
+// AgentSnapshotPayload is the payload for agent.snapshot events.
+type AgentSnapshotPayload struct {
+	SnapshotID string     `json:"snapshot_id"`
+	Hash       string     `json:"hash"`
+	State      AgentState `json:"state,omitempty"`
+	Note       string     `json:"note,omitempty"`
+}
+
+// AgentCheckpointPayload is the payload for agent.checkpointed and
+// agent.restored events.
+type AgentCheckpointPayload struct {
+	Checkpoint string `json:"checkpoint"`
+	Files      int    `json:"files"`
+	Warnings   int    `json:"warnings,omitempty"`
+}
+
+// AgentHibernationPayload is the payload for agent.hibernated and
+// agent.woken events.
+type AgentHibernationPayload struct {
+	Checkpoint string `json:"checkpoint"`
+
+	// Reason says what hibernated or woke the agent.
+	Reason string `json:"reason"`
+}
+
+// AgentRecoveryPayload is the payload for agent.recovery events.
+type AgentRecoveryPayload struct {
+	Action string `json:"action"`
+	Step   int    `json:"step"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ApprovalPayload is the payload for approval.* events.
+type ApprovalPayload struct {
+	ApprovalID    string              `json:"approval_id"`
+	AgentID       string              `json:"agent_id"`
+	RequestType   ApprovalRequestType `json:"request_type"`
+	Status        ApprovalStatus      `json:"status"`
+	PromptExcerpt string              `json:"prompt_excerpt,omitempty"`
+	Input         string              `json:"input,omitempty"`
+	ResolvedBy    string              `json:"resolved_by,omitempty"`
 }
 
 // MessageQueuedPayload is the payload for message.queued events.
@@ -134,6 +272,24 @@ type MessageCompletedPayload struct {
 	Duration    string        `json:"duration"`
 }
 
+// ReviewCompletedPayload is the payload for review.completed events.
+type ReviewCompletedPayload struct {
+	ReviewID    string        `json:"review_id"`
+	QueueItemID string        `json:"queue_item_id"`
+	AgentID     string        `json:"agent_id"`
+	Status      ReviewStatus  `json:"status"`
+	Verdict     ReviewVerdict `json:"verdict,omitempty"`
+	Comments    int           `json:"comments"`
+}
+
+// NoteAddedPayload is the payload for note.added events, recorded against
+// the workspace or agent the note is about.
+type NoteAddedPayload struct {
+	NoteID string `json:"note_id"`
+	Author string `json:"author,omitempty"`
+	Text   string `json:"text"`
+}
+
 // MessageFailedPayload is the payload for message.failed events.
 type MessageFailedPayload struct {
 	QueueItemID string        `json:"queue_item_id"`
@@ -143,6 +299,35 @@ type MessageFailedPayload struct {
 	Attempts    int           `json:"attempts"`
 }
 
+// In-flight recovery actions.
+const (
+	InFlightRequeued     = "requeued"
+	InFlightDeadLettered = "dead_lettered"
+)
+
+// MessageRecoveredPayload is the payload for message.recovered events,
+// recorded when an item left in flight by a dispatcher that never recorded
+// its send is re-queued or dead-lettered.
+type MessageRecoveredPayload struct {
+	QueueItemID   string        `json:"queue_item_id"`
+	ItemType      QueueItemType `json:"item_type"`
+	AgentID       string        `json:"agent_id"`
+	DispatcherID  string        `json:"dispatcher_id,omitempty"`
+	InFlightSince *time.Time    `json:"in_flight_since,omitempty"`
+	Action        string        `json:"action"`
+	Attempts      int           `json:"attempts"`
+}
+
+// QueueItemDispatchedPayload is the payload for queue.item_dispatched events.
+type QueueItemDispatchedPayload struct {
+	AgentID     string        `json:"agent_id"`
+	QueueItemID string        `json:"queue_item_id"`
+	ItemType    QueueItemType `json:"item_type"`
+	Success     bool          `json:"success"`
+	Duration    string        `json:"duration"`
+	Error       string        `json:"error,omitempty"`
+}
+
 // RateLimitPayload is the payload for rate_limit.detected events.
 type RateLimitPayload struct {
 	AccountID       string   `json:"account_id"`
@@ -159,6 +344,60 @@ type AccountRotatedPayload struct {
 	Reason       string `json:"reason"`
 }
 
+// CredentialExpiringPayload is the payload for account.credential_expiring
+// events.
+type CredentialExpiringPayload struct {
+	AccountID        string    `json:"account_id"`
+	Provider         Provider  `json:"provider"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RemainingSeconds int       `json:"remaining_seconds"`
+	ThresholdSeconds int       `json:"threshold_seconds"`
+}
+
+// ProviderHealthChangedPayload is the payload for provider.health_changed
+// events, recorded against the system entity with the provider as its ID.
+type ProviderHealthChangedPayload struct {
+	Provider Provider       `json:"provider"`
+	Previous ProviderHealth `json:"previous"`
+	Health   ProviderHealth `json:"health"`
+	Reason   string         `json:"reason,omitempty"`
+}
+
+// Budget actions recorded on budget.exceeded events.
+const (
+	BudgetActionRefused    = "refused"
+	BudgetActionWarned     = "warned"
+	BudgetActionOverridden = "overridden"
+)
+
+// BudgetExceededPayload is the payload for budget.exceeded events, recorded
+// when a spawn would push the projected daily cost over a ceiling.
+type BudgetExceededPayload struct {
+	// Scope is "global" or "workspace".
+	Scope          string    `json:"scope"`
+	AgentType      AgentType `json:"agent_type"`
+	ProjectedCents int64     `json:"projected_cents"`
+	CeilingCents   int64     `json:"ceiling_cents"`
+	Action         string    `json:"action"`
+}
+
+// UsageRecordedPayload is the payload for usage.recorded events, recorded
+// against the agent that incurred the cost when it is known (the account
+// otherwise): when an adapter reports an agent's cost going up, and for
+// imported usage records.
+type UsageRecordedPayload struct {
+	AccountID   string    `json:"account_id,omitempty"`
+	AgentID     string    `json:"agent_id,omitempty"`
+	Provider    Provider  `json:"provider,omitempty"`
+	Model       string    `json:"model,omitempty"`
+	TotalTokens int64     `json:"total_tokens,omitempty"`
+	CostCents   int64     `json:"cost_cents"`
+	RecordedAt  time.Time `json:"recorded_at"`
+	// Source is where the figure came from, such as an adapter's usage
+	// source ("opencode.stats") or "import".
+	Source string `json:"source,omitempty"`
+}
+
 // ErrorPayload is the payload for error events.
 type ErrorPayload struct {
 	Error      string `json:"error"`
@@ -84,6 +84,14 @@ type Node struct {
 	// This is set during connection tests and updated dynamically.
 	SwarmdAvailable bool `json:"swarmd_available,omitempty"`
 
+	// DaemonEndpoint is the host:port of the node's swarmd. When set, the
+	// node is daemon-backed: agent operations go to swarmd over gRPC
+	// instead of SSH and tmux.
+	DaemonEndpoint string `json:"daemon_endpoint,omitempty"`
+
+	// DaemonToken is the bearer token swarmd requires (SWARMD_AUTH_TOKEN).
+	DaemonToken string `json:"-"`
+
 	// Status is the current connection status.
 	Status NodeStatus `json:"status"`
 
@@ -96,9 +104,15 @@ type Node struct {
 	// AgentCount is the number of agents currently running on this node.
 	AgentCount int `json:"agent_count"`
 
+	// Labels are free-form key/value tags used to constrain placement.
+	Labels map[string]string `json:"labels,omitempty"`
+
 	// Metadata contains additional node information.
 	Metadata NodeMetadata `json:"metadata,omitempty"`
 
+	// Metrics is the resource usage reported by the last connection test.
+	Metrics *NodeMetrics `json:"metrics,omitempty"`
+
 	// CreatedAt is when the node was added to Swarm.
 	CreatedAt time.Time `json:"created_at"`
 
@@ -127,14 +141,68 @@ type NodeMetadata struct {
 	SwarmdStatus string `json:"swarmd_status,omitempty"`
 }
 
+// NodeMetrics is a snapshot of a node's load, memory, disk, and tmux usage.
+type NodeMetrics struct {
+	// CollectedAt is when the metrics were read.
+	CollectedAt time.Time `json:"collected_at"`
+
+	// CPUs is the number of online processors.
+	CPUs int `json:"cpus,omitempty"`
+
+	// Load1, Load5, and Load15 are the load averages.
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+
+	// MemTotalBytes and MemFreeBytes describe physical memory. Free
+	// memory counts what can be reclaimed without swapping.
+	MemTotalBytes int64 `json:"mem_total_bytes,omitempty"`
+	MemFreeBytes  int64 `json:"mem_free_bytes,omitempty"`
+
+	// DiskPath is the filesystem path disk usage was read for.
+	DiskPath       string `json:"disk_path,omitempty"`
+	DiskTotalBytes int64  `json:"disk_total_bytes,omitempty"`
+	DiskFreeBytes  int64  `json:"disk_free_bytes,omitempty"`
+
+	// TmuxSessions and TmuxPanes count the node's tmux server contents.
+	TmuxSessions int `json:"tmux_sessions"`
+	TmuxPanes    int `json:"tmux_panes"`
+
+	// Degraded lists why the node is short of resources, if it is.
+	Degraded []string `json:"degraded,omitempty"`
+}
+
+// DiskFreePercent returns free disk space as a percentage of the total,
+// or -1 when disk usage is unknown.
+func (m *NodeMetrics) DiskFreePercent() float64 {
+	if m.DiskTotalBytes <= 0 {
+		return -1
+	}
+	return float64(m.DiskFreeBytes) / float64(m.DiskTotalBytes) * 100
+}
+
+// LoadPerCPU returns the one-minute load average divided by the processor
+// count, or the raw load average when the count is unknown.
+func (m *NodeMetrics) LoadPerCPU() float64 {
+	if m.CPUs <= 0 {
+		return m.Load1
+	}
+	return m.Load1 / float64(m.CPUs)
+}
+
 // Validate checks if the node configuration is valid.
 func (n *Node) Validate() error {
 	validation := &ValidationErrors{}
 	if n.Name == "" {
 		validation.Add("name", ErrInvalidNodeName)
 	}
-	if !n.IsLocal && n.SSHTarget == "" {
+	if !n.IsLocal && n.SSHTarget == "" && n.DaemonEndpoint == "" {
 		validation.Add("ssh_target", ErrInvalidSSHTarget)
 	}
 	return validation.Err()
 }
+
+// IsDaemon reports whether the node is reached through its swarmd daemon.
+func (n *Node) IsDaemon() bool {
+	return n.DaemonEndpoint != ""
+}
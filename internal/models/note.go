@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Note is a free-form remark an operator left on a workspace or agent for
+// others to read. Notes outlive the entity they are about.
+type Note struct {
+	// ID is the unique identifier for the note.
+	ID string `json:"id"`
+
+	// EntityType is what the note is about: workspace or agent.
+	EntityType EntityType `json:"entity_type"`
+
+	// EntityID references the workspace or agent.
+	EntityID string `json:"entity_id"`
+
+	// Author is who wrote the note, from $USER.
+	Author string `json:"author,omitempty"`
+
+	// Text is the note itself.
+	Text string `json:"text"`
+
+	// CreatedAt is when the note was written.
+	CreatedAt time.Time `json:"created_at"`
+}
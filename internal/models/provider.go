@@ -0,0 +1,149 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Providers lists the providers swarm knows, in display order.
+var Providers = []Provider{ProviderAnthropic, ProviderOpenAI, ProviderGoogle, ProviderCustom}
+
+// providerAliases maps the other names providers go by (CLI and vault
+// adapter names, API product names) to the provider.
+var providerAliases = map[string]Provider{
+	"claude":        ProviderAnthropic,
+	"claude-code":   ProviderAnthropic,
+	"anthropic-api": ProviderAnthropic,
+	"openai-api":    ProviderOpenAI,
+	"codex":         ProviderOpenAI,
+	"chatgpt":       ProviderOpenAI,
+	"gpt":           ProviderOpenAI,
+	"gemini":        ProviderGoogle,
+	"google-ai":     ProviderGoogle,
+	"vertex":        ProviderGoogle,
+	"vertex-ai":     ProviderGoogle,
+}
+
+// ParseProvider parses a provider name, ignoring case and surrounding
+// whitespace and accepting known aliases ("claude", "openai-api", ...).
+// An unknown name returns an error wrapping ErrUnknownProvider; providers
+// swarm does not know are recorded as ProviderCustom with a ProviderName.
+func ParseProvider(value string) (Provider, error) {
+	normalized := NormalizeProvider(Provider(value))
+	if normalized.IsValid() {
+		return normalized, nil
+	}
+	if provider, ok := providerAliases[string(normalized)]; ok {
+		return provider, nil
+	}
+	return "", fmt.Errorf("%w %q (valid: %s; use custom with a provider name for others)", ErrUnknownProvider, value, providerList())
+}
+
+// NormalizeProvider lower-cases p and trims surrounding whitespace without
+// resolving aliases. Writes store providers normalized this way and reject
+// anything that is then not IsValid.
+func NormalizeProvider(p Provider) Provider {
+	return Provider(strings.ToLower(strings.TrimSpace(string(p))))
+}
+
+// IsValid reports whether p is one of Providers, exactly.
+func (p Provider) IsValid() bool {
+	switch p {
+	case ProviderAnthropic, ProviderOpenAI, ProviderGoogle, ProviderCustom:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProviderHealth is whether a provider's API is usable, as last probed.
+type ProviderHealth string
+
+const (
+	// ProviderHealthUnknown is the health of a provider not probed yet.
+	ProviderHealthUnknown  ProviderHealth = "unknown"
+	ProviderHealthHealthy  ProviderHealth = "healthy"
+	ProviderHealthDegraded ProviderHealth = "degraded"
+	ProviderHealthDown     ProviderHealth = "down"
+)
+
+// Worse reports whether h is worse than other. Unknown is not worse than
+// anything.
+func (h ProviderHealth) Worse(other ProviderHealth) bool {
+	return h.rank() > other.rank()
+}
+
+func (h ProviderHealth) rank() int {
+	switch h {
+	case ProviderHealthHealthy:
+		return 1
+	case ProviderHealthDegraded:
+		return 2
+	case ProviderHealthDown:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// NormalizeProviderName lower-cases a custom provider name and trims
+// surrounding whitespace.
+func NormalizeProviderName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// validateProvider adds the errors for a provider and custom provider name
+// pair to validation.
+func validateProvider(validation *ValidationErrors, provider Provider, providerName string) {
+	switch {
+	case provider == "":
+		validation.Add("provider", ErrInvalidProvider)
+	case !provider.IsValid():
+		validation.Add("provider", fmt.Errorf("%w %q (valid: %s)", ErrUnknownProvider, provider, providerList()))
+	}
+	if providerName == "" {
+		return
+	}
+	if provider != ProviderCustom {
+		validation.Add("provider_name", fmt.Errorf("%w: only the custom provider takes a provider name", ErrInvalidProviderName))
+		return
+	}
+	if known, err := ParseProvider(providerName); err == nil && known != ProviderCustom {
+		validation.Add("provider_name", fmt.Errorf("%w: %q is the %s provider; use it instead of custom", ErrInvalidProviderName, providerName, known))
+	}
+}
+
+func providerList() string {
+	names := make([]string, len(Providers))
+	for i, p := range Providers {
+		names[i] = string(p)
+	}
+	return strings.Join(names, ", ")
+}
+
+// knownModelPrefixes is the catalog of model families per provider. Model
+// IDs gain dated and versioned variants faster than swarm releases, so
+// families are matched by prefix, and the catalog is only used for
+// warnings: an unknown model is never rejected.
+var knownModelPrefixes = map[Provider][]string{
+	ProviderAnthropic: {"claude-", "opus", "sonnet", "haiku"},
+	ProviderOpenAI:    {"gpt-", "o1", "o3", "o4", "codex", "chatgpt-"},
+	ProviderGoogle:    {"gemini-", "gemma-"},
+}
+
+// IsKnownModel reports whether model belongs to one of provider's known
+// model families. It is true for an empty model and for providers without
+// a catalog, such as ProviderCustom.
+func IsKnownModel(provider Provider, model string) bool {
+	prefixes, ok := knownModelPrefixes[provider]
+	model = strings.ToLower(strings.TrimSpace(model))
+	if !ok || model == "" {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
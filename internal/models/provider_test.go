@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseProvider(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Provider
+	}{
+		{value: "anthropic", want: ProviderAnthropic},
+		{value: " Anthropic ", want: ProviderAnthropic},
+		{value: "OPENAI", want: ProviderOpenAI},
+		{value: "google", want: ProviderGoogle},
+		{value: "custom", want: ProviderCustom},
+		{value: "claude", want: ProviderAnthropic},
+		{value: "Claude-Code", want: ProviderAnthropic},
+		{value: "openai-api", want: ProviderOpenAI},
+		{value: "codex", want: ProviderOpenAI},
+		{value: "gemini", want: ProviderGoogle},
+		{value: "vertex-ai", want: ProviderGoogle},
+	}
+	for _, tt := range tests {
+		got, err := ParseProvider(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseProvider(%q) = %q, %v; want %q", tt.value, got, err, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "anthropoc", "open ai", "mistral"} {
+		if _, err := ParseProvider(value); !errors.Is(err, ErrUnknownProvider) {
+			t.Errorf("ParseProvider(%q): expected ErrUnknownProvider, got %v", value, err)
+		}
+	}
+}
+
+func TestProviderValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     Provider
+		providerName string
+		want         error
+	}{
+		{name: "known", provider: ProviderOpenAI},
+		{name: "custom with a name", provider: ProviderCustom, providerName: "mistral"},
+		{name: "custom without a name", provider: ProviderCustom},
+		{name: "missing", want: ErrInvalidProvider},
+		// Writes take canonical providers only; aliases are resolved by
+		// ParseProvider before they get here.
+		{name: "alias", provider: "claude", want: ErrUnknownProvider},
+		{name: "typo", provider: "anthropoc", want: ErrUnknownProvider},
+		{name: "name on a known provider", provider: ProviderAnthropic, providerName: "claude", want: ErrInvalidProviderName},
+		{name: "custom named after a known provider", provider: ProviderCustom, providerName: "gemini", want: ErrInvalidProviderName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &Account{Provider: tt.provider, ProviderName: tt.providerName, ProfileName: "work"}
+			record := &UsageRecord{AccountID: "acct-1", Provider: tt.provider, ProviderName: tt.providerName}
+			for _, err := range []error{account.Validate(), record.Validate()} {
+				if tt.want == nil && err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if tt.want != nil && !errors.Is(err, tt.want) {
+					t.Fatalf("expected %v, got %v", tt.want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestIsKnownModel(t *testing.T) {
+	tests := []struct {
+		provider Provider
+		model    string
+		want     bool
+	}{
+		{provider: ProviderAnthropic, model: "claude-sonnet-4-5-20250929", want: true},
+		{provider: ProviderAnthropic, model: "Claude-3-Opus", want: true},
+		{provider: ProviderOpenAI, model: "gpt-4o-mini", want: true},
+		{provider: ProviderOpenAI, model: "o3-pro", want: true},
+		{provider: ProviderGoogle, model: "gemini-2.5-pro", want: true},
+		{provider: ProviderAnthropic, model: "gpt-4o"},
+		{provider: ProviderOpenAI, model: "claude-3-opus"},
+		{provider: ProviderGoogle, model: "palm-2"},
+		// No model and providers without a catalog are never flagged.
+		{provider: ProviderAnthropic, model: "", want: true},
+		{provider: ProviderCustom, model: "mistral-large", want: true},
+	}
+	for _, tt := range tests {
+		if got := IsKnownModel(tt.provider, tt.model); got != tt.want {
+			t.Errorf("IsKnownModel(%s, %q) = %v, want %v", tt.provider, tt.model, got, tt.want)
+		}
+	}
+}
@@ -14,13 +14,18 @@ const (
 	QueueItemTypeMessage     QueueItemType = "message"
 	QueueItemTypePause       QueueItemType = "pause"
 	QueueItemTypeConditional QueueItemType = "conditional"
+	QueueItemTypeCommand     QueueItemType = "command"
+	QueueItemTypeReview      QueueItemType = "review"
 )
 
-// QueueItemStatus represents the status of a queue item.
+// QueueItemStatus represents the status of a queue item. Dequeuing moves
+// an item from pending to in_flight; once the send is attempted it becomes
+// dispatched, or pending again for a retry, or failed.
 type QueueItemStatus string
 
 const (
 	QueueItemStatusPending    QueueItemStatus = "pending"
+	QueueItemStatusInFlight   QueueItemStatus = "in_flight"
 	QueueItemStatusDispatched QueueItemStatus = "dispatched"
 	QueueItemStatusCompleted  QueueItemStatus = "completed"
 	QueueItemStatusFailed     QueueItemStatus = "failed"
@@ -35,6 +40,13 @@ type QueueItem struct {
 	// AgentID references the agent this item belongs to.
 	AgentID string `json:"agent_id"`
 
+	// TaskID references the task this item is part of, if any.
+	TaskID string `json:"task_id,omitempty"`
+
+	// TraceID is the trace of the command that queued the item, carried
+	// on to its dispatch.
+	TraceID string `json:"trace_id,omitempty"`
+
 	// Type specifies the item type.
 	Type QueueItemType `json:"type"`
 
@@ -57,14 +69,21 @@ type QueueItem struct {
 	// CreatedAt is when the item was queued.
 	CreatedAt time.Time `json:"created_at"`
 
-	// DispatchedAt is when the item was sent (if dispatched).
+	// DispatchedAt is when a dispatcher last claimed the item.
 	DispatchedAt *time.Time `json:"dispatched_at,omitempty"`
 
+	// DispatcherID identifies the scheduler that last claimed the item.
+	DispatcherID string `json:"dispatcher_id,omitempty"`
+
 	// CompletedAt is when the item completed (if finished).
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 
 	// Error contains error details (if failed).
 	Error string `json:"error,omitempty"`
+
+	// IgnoreQuietHours lets the item be dispatched during the agent's
+	// quiet hours.
+	IgnoreQuietHours bool `json:"ignore_quiet_hours,omitempty"`
 }
 
 // MessagePayload is the payload for message queue items.
@@ -75,6 +94,10 @@ type MessagePayload struct {
 	// IsPermissionResponse indicates this message is a response to a permission prompt.
 	// When true, this message can be dispatched to agents in AwaitingApproval state.
 	IsPermissionResponse bool `json:"is_permission_response,omitempty"`
+
+	// LockPaths lists path patterns the scheduler reserves via Agent Mail
+	// before dispatch and releases once the agent is idle again.
+	LockPaths []string `json:"lock_paths,omitempty"`
 }
 
 // Validate checks if the message payload is valid.
@@ -104,6 +127,142 @@ func (p PausePayload) Validate() error {
 	return validation.Err()
 }
 
+// Command item limits.
+const (
+	// DefaultCommandTimeoutSeconds applies when a command item sets no timeout.
+	DefaultCommandTimeoutSeconds = 300
+
+	// MaxCommandTimeoutSeconds caps how long a command item may run.
+	MaxCommandTimeoutSeconds = 3600
+
+	// DefaultCommandMaxOutputBytes is how much command output is sent to the
+	// agent when a command item sets no limit.
+	DefaultCommandMaxOutputBytes = 16 << 10
+)
+
+// CommandPayload is the payload for command queue items: a shell command
+// run on the workspace's node whose output is sent to the agent.
+type CommandPayload struct {
+	// Command is the shell command to run.
+	Command string `json:"command"`
+
+	// WorkDir is where the command runs (default: the workspace repo path).
+	WorkDir string `json:"work_dir,omitempty"`
+
+	// TimeoutSeconds kills the command after this long
+	// (default: DefaultCommandTimeoutSeconds).
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+
+	// Prefix is sent before the command output, e.g. "summarize the failures:".
+	Prefix string `json:"prefix,omitempty"`
+
+	// MaxOutputBytes truncates the output sent to the agent
+	// (default: DefaultCommandMaxOutputBytes).
+	MaxOutputBytes int `json:"max_output_bytes,omitempty"`
+
+	// IncludeExitCode adds the command's exit code to the message.
+	IncludeExitCode bool `json:"include_exit_code,omitempty"`
+
+	// FailOnNonZero fails the item instead of sending the output when the
+	// command exits non-zero.
+	FailOnNonZero bool `json:"fail_on_nonzero,omitempty"`
+}
+
+// Timeout returns the command timeout, applying the default.
+func (p CommandPayload) Timeout() time.Duration {
+	if p.TimeoutSeconds <= 0 {
+		return DefaultCommandTimeoutSeconds * time.Second
+	}
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
+// OutputLimit returns the output byte limit, applying the default.
+func (p CommandPayload) OutputLimit() int {
+	if p.MaxOutputBytes <= 0 {
+		return DefaultCommandMaxOutputBytes
+	}
+	return p.MaxOutputBytes
+}
+
+// Validate checks if the command payload is valid.
+func (p CommandPayload) Validate() error {
+	validation := &ValidationErrors{}
+	if strings.TrimSpace(p.Command) == "" {
+		validation.AddMessage("command", "command is required")
+	}
+	if strings.ContainsRune(p.Command, 0) {
+		validation.AddMessage("command", "command must not contain NUL bytes")
+	}
+	if p.TimeoutSeconds < 0 || p.TimeoutSeconds > MaxCommandTimeoutSeconds {
+		validation.AddMessage("timeout_seconds", fmt.Sprintf("timeout_seconds must be between 0 and %d", MaxCommandTimeoutSeconds))
+	}
+	if p.MaxOutputBytes < 0 {
+		validation.AddMessage("max_output_bytes", "max_output_bytes must be greater than or equal to 0")
+	}
+	if strings.ContainsAny(p.WorkDir, "\x00\n") {
+		validation.AddMessage("work_dir", "work_dir must be a single path")
+	}
+	return validation.Err()
+}
+
+// DefaultReviewMaxDiffBytes is how much of a diff a review item sends when
+// neither the item nor the config sets a limit.
+const DefaultReviewMaxDiffBytes = 64 << 10
+
+// ReviewPayload is the payload for review queue items: a diff taken from
+// the workspace's git, sent to the agent with a review prompt, whose
+// verdict is recorded as a Review.
+type ReviewPayload struct {
+	// Range is the git revision range to diff, e.g. "main..HEAD".
+	Range string `json:"range,omitempty"`
+
+	// PatchFile is a patch file on the workspace's node to review instead
+	// of a range, relative to the workspace repo path.
+	PatchFile string `json:"patch_file,omitempty"`
+
+	// Paths limits the diff to files matching these patterns.
+	Paths []string `json:"paths,omitempty"`
+
+	// Exclude drops files matching these patterns from the diff.
+	Exclude []string `json:"exclude,omitempty"`
+
+	// MaxDiffBytes caps the diff sent to the agent (default: the config's
+	// review.max_diff_bytes).
+	MaxDiffBytes int `json:"max_diff_bytes,omitempty"`
+
+	// Instructions are added to the review prompt.
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// Source describes what the review covers: the range or the patch file.
+func (p ReviewPayload) Source() string {
+	if p.PatchFile != "" {
+		return p.PatchFile
+	}
+	return p.Range
+}
+
+// Validate checks if the review payload is valid.
+func (p ReviewPayload) Validate() error {
+	validation := &ValidationErrors{}
+	switch {
+	case strings.TrimSpace(p.Range) == "" && strings.TrimSpace(p.PatchFile) == "":
+		validation.AddMessage("range", "range or patch_file is required")
+	case p.Range != "" && p.PatchFile != "":
+		validation.AddMessage("range", "range and patch_file cannot both be set")
+	}
+	if strings.HasPrefix(p.Range, "-") || strings.ContainsAny(p.Range, " \t\n\x00") {
+		validation.AddMessage("range", "range must be a single git revision range")
+	}
+	if strings.ContainsAny(p.PatchFile, "\n\x00") {
+		validation.AddMessage("patch_file", "patch_file must be a single path")
+	}
+	if p.MaxDiffBytes < 0 {
+		validation.AddMessage("max_diff_bytes", "max_diff_bytes must be greater than or equal to 0")
+	}
+	return validation.Err()
+}
+
 // ConditionType specifies the type of condition gate.
 type ConditionType string
 
@@ -177,6 +336,20 @@ func (q *QueueItem) Validate() error {
 				break
 			}
 			validation.Add("payload", payload.Validate())
+		case QueueItemTypeCommand:
+			var payload CommandPayload
+			if err := json.Unmarshal(q.Payload, &payload); err != nil {
+				validation.AddMessage("payload", fmt.Sprintf("invalid command payload: %v", err))
+				break
+			}
+			validation.Add("payload", payload.Validate())
+		case QueueItemTypeReview:
+			var payload ReviewPayload
+			if err := json.Unmarshal(q.Payload, &payload); err != nil {
+				validation.AddMessage("payload", fmt.Sprintf("invalid review payload: %v", err))
+				break
+			}
+			validation.Add("payload", payload.Validate())
 		default:
 			validation.AddMessage("type", fmt.Sprintf("unknown queue item type %q", q.Type))
 		}
@@ -220,3 +393,90 @@ func (q *QueueItem) GetConditionalPayload() (*ConditionalPayload, error) {
 	}
 	return &payload, nil
 }
+
+// GetCommandPayload extracts the command payload.
+func (q *QueueItem) GetCommandPayload() (*CommandPayload, error) {
+	if q.Type != QueueItemTypeCommand {
+		return nil, ErrInvalidQueueItem
+	}
+	var payload CommandPayload
+	if err := json.Unmarshal(q.Payload, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// GetReviewPayload extracts the review payload.
+func (q *QueueItem) GetReviewPayload() (*ReviewPayload, error) {
+	if q.Type != QueueItemTypeReview {
+		return nil, ErrInvalidQueueItem
+	}
+	var payload ReviewPayload
+	if err := json.Unmarshal(q.Payload, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// WorkspaceQueueItemStatus represents the status of a workspace queue item.
+type WorkspaceQueueItemStatus string
+
+const (
+	// WorkspaceQueueItemStatusPending items wait for an eligible agent.
+	WorkspaceQueueItemStatusPending WorkspaceQueueItemStatus = "pending"
+	// WorkspaceQueueItemStatusAssigned items were moved to an agent's queue.
+	WorkspaceQueueItemStatusAssigned WorkspaceQueueItemStatus = "assigned"
+)
+
+// WorkspaceQueueItem is work queued for whichever agent in a workspace is
+// available first. Once assigned it becomes a QueueItem in that agent's
+// queue.
+type WorkspaceQueueItem struct {
+	// ID is the unique identifier for the item.
+	ID string `json:"id"`
+
+	// WorkspaceID is the workspace whose agents may take the item.
+	WorkspaceID string `json:"workspace_id"`
+
+	// AgentType restricts the item to agents of this type (empty = any).
+	AgentType AgentType `json:"agent_type,omitempty"`
+
+	// Type and Payload become the assigned QueueItem's type and payload.
+	Type    QueueItemType   `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+
+	// Status is pending until the item is assigned.
+	Status WorkspaceQueueItemStatus `json:"status"`
+
+	// AgentID and QueueItemID record where the item was assigned.
+	AgentID     string `json:"agent_id,omitempty"`
+	QueueItemID string `json:"queue_item_id,omitempty"`
+
+	// CreatedAt is when the item was queued.
+	CreatedAt time.Time `json:"created_at"`
+
+	// AssignedAt is when the item was moved to an agent's queue.
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+}
+
+// QueueItem returns the agent queue item the workspace item becomes when
+// assigned to agentID.
+func (w *WorkspaceQueueItem) QueueItem(agentID string) *QueueItem {
+	return &QueueItem{
+		AgentID: agentID,
+		Type:    w.Type,
+		Status:  QueueItemStatusPending,
+		Payload: w.Payload,
+	}
+}
+
+// Validate checks the item's workspace and that its payload is valid for
+// its type.
+func (w *WorkspaceQueueItem) Validate() error {
+	validation := &ValidationErrors{}
+	if w.WorkspaceID == "" {
+		validation.AddMessage("workspace_id", "workspace_id is required")
+	}
+	validation.Add("", w.QueueItem("").Validate())
+	return validation.Err()
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// AgentQueueMetrics is the persisted form of an agent's queue wait and
+// throughput history, kept so the aggregates survive restarts.
+type AgentQueueMetrics struct {
+	// AgentID is the agent the metrics belong to.
+	AgentID string `json:"agent_id"`
+
+	// Dispatched is the number of items dispatched to the agent.
+	Dispatched int64 `json:"dispatched"`
+
+	// WaitSamplesMs are the most recent queue waits, oldest first.
+	WaitSamplesMs []int64 `json:"wait_samples_ms"`
+
+	// RecentDispatches are the dispatch times within the throughput window.
+	RecentDispatches []time.Time `json:"recent_dispatches"`
+
+	// UpdatedAt is when the metrics were last saved.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QueueWaitStats summarizes how long an agent's items wait before dispatch
+// and how many it works off.
+type QueueWaitStats struct {
+	// AgentID is the agent the stats belong to.
+	AgentID string `json:"agent_id"`
+
+	// Dispatched is the number of items dispatched to the agent.
+	Dispatched int64 `json:"dispatched"`
+
+	// WaitP50Ms is the median queue wait over the recent samples.
+	WaitP50Ms int64 `json:"wait_p50_ms"`
+
+	// WaitP95Ms is the 95th percentile queue wait over the recent samples.
+	WaitP95Ms int64 `json:"wait_p95_ms"`
+
+	// Samples is the number of waits the percentiles are computed from.
+	Samples int `json:"samples"`
+
+	// PerHour is the number of items dispatched in the last hour.
+	PerHour int `json:"per_hour"`
+}
@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+// ReviewStatus represents the lifecycle state of a review.
+type ReviewStatus string
+
+const (
+	// ReviewStatusPending reviews are queued and not yet sent to the agent.
+	ReviewStatusPending ReviewStatus = "pending"
+	// ReviewStatusAwaitingVerdict reviews were sent and wait for the agent
+	// to answer.
+	ReviewStatusAwaitingVerdict ReviewStatus = "awaiting_verdict"
+	// ReviewStatusCompleted reviews have a parsed verdict.
+	ReviewStatusCompleted ReviewStatus = "completed"
+	// ReviewStatusUnparsed reviews were answered without a readable verdict;
+	// the raw answer is kept.
+	ReviewStatusUnparsed ReviewStatus = "unparsed"
+	// ReviewStatusFailed reviews could not be sent.
+	ReviewStatusFailed ReviewStatus = "failed"
+)
+
+// ReviewVerdict is an agent's decision on a diff.
+type ReviewVerdict string
+
+const (
+	ReviewVerdictApprove ReviewVerdict = "approve"
+	ReviewVerdictChanges ReviewVerdict = "changes"
+)
+
+// ReviewComment is a single remark in a review verdict.
+type ReviewComment struct {
+	// Path is the file the comment is about, if any.
+	Path string `json:"path,omitempty"`
+
+	// Line is the line in Path the comment is about, if any.
+	Line int `json:"line,omitempty"`
+
+	// Body is the comment text.
+	Body string `json:"body"`
+}
+
+// Review is a diff sent to an agent by a review queue item and the verdict
+// it gave.
+type Review struct {
+	// ID is the unique identifier for the review.
+	ID string `json:"id"`
+
+	// QueueItemID references the review queue item.
+	QueueItemID string `json:"queue_item_id"`
+
+	// AgentID references the reviewing agent.
+	AgentID string `json:"agent_id"`
+
+	// Range is the git revision range reviewed, if any.
+	Range string `json:"range,omitempty"`
+
+	// PatchFile is the patch file reviewed, if any.
+	PatchFile string `json:"patch_file,omitempty"`
+
+	// Status is the current review status.
+	Status ReviewStatus `json:"status"`
+
+	// Verdict is the agent's decision, once parsed.
+	Verdict ReviewVerdict `json:"verdict,omitempty"`
+
+	// Summary is the agent's overall remark, if it gave one.
+	Summary string `json:"summary,omitempty"`
+
+	// Comments are the agent's remarks on the diff.
+	Comments []ReviewComment `json:"comments,omitempty"`
+
+	// RawOutput is the agent's answer as captured from its pane.
+	RawOutput string `json:"raw_output,omitempty"`
+
+	// Error explains why the verdict could not be parsed or the review
+	// could not be sent.
+	Error string `json:"error,omitempty"`
+
+	// DiffFiles is the number of files in the diff sent.
+	DiffFiles int `json:"diff_files,omitempty"`
+
+	// DiffBytes is the size of the diff before it was capped.
+	DiffBytes int `json:"diff_bytes,omitempty"`
+
+	// Truncated reports whether the diff was capped before it was sent.
+	Truncated bool `json:"truncated,omitempty"`
+
+	// CreatedAt is when the review was requested.
+	CreatedAt time.Time `json:"created_at"`
+
+	// DispatchedAt is when the diff was sent to the agent.
+	DispatchedAt *time.Time `json:"dispatched_at,omitempty"`
+
+	// CompletedAt is when the agent's answer was recorded.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// IsFinished reports whether the review has reached a final status.
+func (r *Review) IsFinished() bool {
+	switch r.Status {
+	case ReviewStatusCompleted, ReviewStatusUnparsed, ReviewStatusFailed:
+		return true
+	}
+	return false
+}
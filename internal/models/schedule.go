@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Schedule is a recurring message enqueued on a cron expression, for one
+// agent or for every agent in a workspace.
+type Schedule struct {
+	// ID is the unique identifier for the schedule.
+	ID string `json:"id"`
+
+	// AgentID targets a single agent. Exactly one of AgentID and
+	// WorkspaceID is set.
+	AgentID string `json:"agent_id,omitempty"`
+
+	// WorkspaceID targets every agent in the workspace at run time.
+	WorkspaceID string `json:"workspace_id,omitempty"`
+
+	// Cron is the 5-field cron expression.
+	Cron string `json:"cron"`
+
+	// Message is the text enqueued on each run.
+	Message string `json:"message"`
+
+	// Template names the template Message was rendered from, if any.
+	Template string `json:"template,omitempty"`
+
+	// Enabled reports whether the schedule runs.
+	Enabled bool `json:"enabled"`
+
+	// LastRunAt is when the schedule last enqueued its message.
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+
+	// NextRunAt is the next due run. It is advanced each time the run is
+	// handled, whether it enqueued or was skipped.
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+
+	// CreatedAt is when the schedule was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is when the schedule was last updated.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks if the schedule is valid. The cron expression itself is
+// parsed by callers that evaluate it.
+func (s *Schedule) Validate() error {
+	validation := &ValidationErrors{}
+	hasAgent := strings.TrimSpace(s.AgentID) != ""
+	hasWorkspace := strings.TrimSpace(s.WorkspaceID) != ""
+	if hasAgent == hasWorkspace {
+		validation.AddMessage("target", "exactly one of agent_id or workspace_id is required")
+	}
+	if strings.TrimSpace(s.Cron) == "" {
+		validation.AddMessage("cron", "cron expression is required")
+	}
+	if strings.TrimSpace(s.Message) == "" {
+		validation.AddMessage("message", "message is required")
+	}
+	return validation.Err()
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// TaskStatus represents the lifecycle state of a task.
+type TaskStatus string
+
+const (
+	TaskStatusPending   TaskStatus = "pending"
+	TaskStatusRunning   TaskStatus = "running"
+	TaskStatusCompleted TaskStatus = "completed"
+	TaskStatusFailed    TaskStatus = "failed"
+)
+
+// IsTerminal reports whether the task has finished.
+func (s TaskStatus) IsTerminal() bool {
+	return s == TaskStatusCompleted || s == TaskStatusFailed
+}
+
+// Task groups queue items sent to one agent into a unit of work.
+type Task struct {
+	// ID is the unique identifier for the task.
+	ID string `json:"id"`
+
+	// AgentID references the agent the task's items are queued for.
+	AgentID string `json:"agent_id"`
+
+	// WorkspaceID references the agent's workspace.
+	WorkspaceID string `json:"workspace_id"`
+
+	// Title describes the task.
+	Title string `json:"title"`
+
+	// Status is the current task status.
+	Status TaskStatus `json:"status"`
+
+	// Error contains failure details (if failed).
+	Error string `json:"error,omitempty"`
+
+	// CreatedAt is when the task was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// StartedAt is when the first item was dispatched.
+	StartedAt *time.Time `json:"started_at,omitempty"`
+
+	// CompletedAt is when the task completed or failed.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Duration returns how long the task has been running, or ran if it has
+// finished. It is zero for tasks that have not started.
+func (t *Task) Duration(now time.Time) time.Duration {
+	if t == nil || t.StartedAt == nil {
+		return 0
+	}
+	end := now
+	if t.CompletedAt != nil {
+		end = *t.CompletedAt
+	}
+	if end.Before(*t.StartedAt) {
+		return 0
+	}
+	return end.Sub(*t.StartedAt)
+}
+
+// Validate checks if the task is valid.
+func (t *Task) Validate() error {
+	validation := &ValidationErrors{}
+	if strings.TrimSpace(t.AgentID) == "" {
+		validation.AddMessage("agent_id", "agent_id is required")
+	}
+	if strings.TrimSpace(t.WorkspaceID) == "" {
+		validation.AddMessage("workspace_id", "workspace_id is required")
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		validation.AddMessage("title", "title is required")
+	}
+	switch t.Status {
+	case "", TaskStatusPending, TaskStatusRunning, TaskStatusCompleted, TaskStatusFailed:
+	default:
+		validation.AddMessage("status", "invalid task status")
+	}
+	return validation.Err()
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// TranscriptEntry is an entry of an agent's swarmd transcript kept in the
+// database, so the transcript outlives the daemon and the agent.
+type TranscriptEntry struct {
+	// AgentID is the agent whose transcript holds the entry.
+	AgentID string `json:"agent_id"`
+
+	// EntryID is the entry's ID within the transcript. IDs increase
+	// monotonically and serve as cursors.
+	EntryID int64 `json:"entry_id"`
+
+	// EndID is the last ID a summary entry stands for; zero otherwise.
+	EndID int64 `json:"end_id,omitempty"`
+
+	// Timestamp is when the entry was recorded.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Type is one of TranscriptEntryTypes.
+	Type string `json:"type"`
+
+	// Content is the redacted entry text.
+	Content string `json:"content"`
+
+	// Metadata holds the entry's redacted metadata.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// LastID returns the last transcript ID the entry covers.
+func (e *TranscriptEntry) LastID() int64 {
+	if e.EndID > e.EntryID {
+		return e.EndID
+	}
+	return e.EntryID
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TranscriptPushStatus is the lifecycle state of a transcript push
+// subscription.
+type TranscriptPushStatus string
+
+const (
+	// TranscriptPushActive subscriptions are followed by swarmd.
+	TranscriptPushActive TranscriptPushStatus = "active"
+
+	// TranscriptPushCompleted subscriptions have delivered the transcript
+	// of an agent that terminated, ending with a final batch.
+	TranscriptPushCompleted TranscriptPushStatus = "completed"
+)
+
+// TranscriptEntryTypes are the names of the swarmd transcript entry types a
+// push subscription can select.
+var TranscriptEntryTypes = []string{
+	"command",
+	"output",
+	"error",
+	"state_change",
+	"approval",
+	"user_input",
+	"summary",
+}
+
+// TranscriptPush is a subscription that has swarmd POST an agent's new
+// transcript entries to an external URL as they are recorded.
+type TranscriptPush struct {
+	// ID is the unique identifier for the subscription.
+	ID string `json:"id"`
+
+	// AgentID is the agent whose transcript is pushed.
+	AgentID string `json:"agent_id"`
+
+	// URL receives the batches of entries.
+	URL string `json:"url"`
+
+	// EntryTypes limits the entries pushed to these types (see
+	// TranscriptEntryTypes); empty pushes every entry.
+	EntryTypes []string `json:"entry_types,omitempty"`
+
+	// Secret signs each batch with HMAC-SHA256 when set. It is never
+	// included in output.
+	Secret string `json:"-"`
+
+	// Status is the subscription's lifecycle state.
+	Status TranscriptPushStatus `json:"status"`
+
+	// Stream identifies the transcript Cursor points into. swarmd keeps
+	// transcripts in memory, so an agent adopted again after a restart
+	// starts a new one.
+	Stream string `json:"stream,omitempty"`
+
+	// Cursor is the ID of the next transcript entry to push; every entry
+	// before it has been acknowledged by the receiver.
+	Cursor int64 `json:"cursor"`
+
+	// Head is the ID the agent's next transcript entry will take, as last
+	// seen by swarmd.
+	Head int64 `json:"head"`
+
+	// Delivered counts the entries acknowledged by the receiver.
+	Delivered int64 `json:"delivered"`
+
+	// Failures counts the attempts that failed since the last delivery.
+	Failures int `json:"failures"`
+
+	// LastError is the error of the last failed attempt, cleared on
+	// delivery.
+	LastError string `json:"last_error,omitempty"`
+
+	// LastDeliveredAt is when a batch was last acknowledged.
+	LastDeliveredAt *time.Time `json:"last_delivered_at,omitempty"`
+
+	// CreatedAt is when the subscription was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is when the subscription was last updated.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// CompletedAt is when the final batch was acknowledged.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Validate checks if the subscription is valid.
+func (p *TranscriptPush) Validate() error {
+	validation := &ValidationErrors{}
+	if strings.TrimSpace(p.AgentID) == "" {
+		validation.AddMessage("agent_id", "agent_id is required")
+	}
+	if u, err := url.Parse(p.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		validation.AddMessage("url", "url must be an absolute http or https URL")
+	}
+	for _, entryType := range p.EntryTypes {
+		if !slices.Contains(TranscriptEntryTypes, entryType) {
+			validation.AddMessage("entry_types", "unknown entry type "+entryType+" (valid: "+strings.Join(TranscriptEntryTypes, ", ")+")")
+		}
+	}
+	return validation.Err()
+}
+
+// Lag is the number of transcript entries recorded but not yet pushed, as
+// of the last time swarmd looked.
+func (p *TranscriptPush) Lag() int64 {
+	if p.Status != TranscriptPushActive || p.Head < p.Cursor {
+		return 0
+	}
+	return p.Head - p.Cursor
+}
+
+// Pushes reports whether entries of entryType are pushed.
+func (p *TranscriptPush) Pushes(entryType string) bool {
+	return len(p.EntryTypes) == 0 || slices.Contains(p.EntryTypes, entryType)
+}
@@ -21,6 +21,9 @@ type UsageRecord struct {
 	// Provider is the AI provider for this usage.
 	Provider Provider `json:"provider"`
 
+	// ProviderName names the provider of ProviderCustom usage.
+	ProviderName string `json:"provider_name,omitempty"`
+
 	// Model is the model used (e.g., "claude-3-opus", "gpt-4").
 	Model string `json:"model,omitempty"`
 
@@ -109,6 +112,41 @@ type DailyUsage struct {
 	RequestCount int64 `json:"request_count"`
 }
 
+// AgentCost is what an agent has cost on one day, kept by the cost ticker.
+// Recorded usage and the estimate for work with none recorded yet are
+// reported apart and never summed into one figure.
+type AgentCost struct {
+	// AgentID is the agent the cost is for.
+	AgentID string `json:"agent_id"`
+
+	// AgentType is the agent's type, which sets its hourly rate.
+	AgentType AgentType `json:"agent_type,omitempty"`
+
+	// Date is the day (YYYY-MM-DD) in the ticker's time zone.
+	Date string `json:"date"`
+
+	// ActualCents is the usage cost recorded for the agent that day.
+	ActualCents int64 `json:"actual_cents"`
+
+	// Estimated is the estimate for the agent's working time that no usage
+	// has been recorded for yet.
+	Estimated CostEstimate `json:"estimated"`
+}
+
+// CostEstimate is a cost estimated from time spent working at an hourly
+// rate, not from recorded usage.
+type CostEstimate struct {
+	// Cents is WorkingSeconds at CostPerHourCents, rounded up.
+	Cents int64 `json:"cents"`
+
+	// WorkingSeconds is the time spent working since usage was last
+	// recorded for the agent that day.
+	WorkingSeconds int64 `json:"working_seconds"`
+
+	// CostPerHourCents is the rate the estimate uses.
+	CostPerHourCents int64 `json:"cost_per_hour_cents"`
+}
+
 // UsageQuery defines filters for querying usage.
 type UsageQuery struct {
 	// AccountID filters by account.
@@ -142,9 +180,7 @@ func (r *UsageRecord) Validate() error {
 	if r.AccountID == "" {
 		validation.AddMessage("account_id", "account_id is required")
 	}
-	if r.Provider == "" {
-		validation.AddMessage("provider", "provider is required")
-	}
+	validateProvider(validation, r.Provider, r.ProviderName)
 	if r.TotalTokens < 0 {
 		validation.AddMessage("total_tokens", "total_tokens must be non-negative")
 	}
@@ -46,6 +46,28 @@ type Workspace struct {
 	// Alerts contains current alerts for this workspace.
 	Alerts []Alert `json:"alerts,omitempty"`
 
+	// Placement records how the node was chosen, if it was chosen automatically.
+	Placement *WorkspacePlacement `json:"placement,omitempty"`
+
+	// Pause is set while work in the workspace is paused.
+	Pause *WorkspacePause `json:"pause,omitempty"`
+
+	// Draining is set while the workspace's agents drain their queues. No
+	// new agents are spawned into a draining workspace.
+	Draining bool `json:"draining,omitempty"`
+
+	// QuietHours is the workspace's quiet hours spec, during which the
+	// scheduler dispatches nothing to its agents. Empty inherits the global
+	// setting; "none" turns quiet hours off.
+	QuietHours string `json:"quiet_hours,omitempty"`
+
+	// HibernateAfterSeconds hibernates the workspace's agents once they
+	// have sat idle with an empty queue this long; zero never does.
+	HibernateAfterSeconds int `json:"hibernate_after_seconds,omitempty"`
+
+	// Bootstrap records the bootstrap files last written into the repo.
+	Bootstrap *WorkspaceBootstrap `json:"bootstrap,omitempty"`
+
 	// CreatedAt is when the workspace was created.
 	CreatedAt time.Time `json:"created_at"`
 
@@ -53,6 +75,103 @@ type Workspace struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// PlacementStrategy selects a node for a new workspace.
+type PlacementStrategy string
+
+const (
+	// PlacementLeastAgents picks the node running the fewest live agents.
+	PlacementLeastAgents PlacementStrategy = "least-agents"
+	// PlacementRoundRobin rotates through nodes in name order.
+	PlacementRoundRobin PlacementStrategy = "round-robin"
+	// PlacementPinnedByLabel always picks the first node carrying the pin labels.
+	PlacementPinnedByLabel PlacementStrategy = "pinned-by-label"
+	// PlacementLeastLoaded picks the node with the lowest reported load per CPU.
+	PlacementLeastLoaded PlacementStrategy = "least-loaded"
+)
+
+// PlacementStrategies lists the supported placement strategies.
+var PlacementStrategies = []PlacementStrategy{
+	PlacementLeastAgents,
+	PlacementRoundRobin,
+	PlacementPinnedByLabel,
+	PlacementLeastLoaded,
+}
+
+// IsValid reports whether the strategy is supported.
+func (p PlacementStrategy) IsValid() bool {
+	for _, strategy := range PlacementStrategies {
+		if p == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkspacePlacement records an automatic node selection.
+type WorkspacePlacement struct {
+	// Strategy is the placement strategy that chose the node.
+	Strategy PlacementStrategy `json:"strategy"`
+
+	// Reason explains why the node was chosen.
+	Reason string `json:"reason"`
+}
+
+// WorkspacePause records a workspace-level pause.
+type WorkspacePause struct {
+	// PausedAt is when the workspace was paused.
+	PausedAt time.Time `json:"paused_at"`
+
+	// Until ends the pause automatically; nil pauses until resumed.
+	Until *time.Time `json:"until,omitempty"`
+
+	// AgentIDs lists the agents the pause paused. Agents that were already
+	// paused are left out, so resuming the workspace leaves them paused.
+	AgentIDs []string `json:"agent_ids,omitempty"`
+}
+
+// BootstrapFileStatus is what bootstrapping did with one file.
+type BootstrapFileStatus string
+
+const (
+	BootstrapFileWritten     BootstrapFileStatus = "written"
+	BootstrapFileOverwritten BootstrapFileStatus = "overwritten"
+	BootstrapFileSkipped     BootstrapFileStatus = "skipped"
+)
+
+// WorkspaceBootstrap records a bootstrap of a workspace's repository.
+type WorkspaceBootstrap struct {
+	// AppliedAt is when the files were written.
+	AppliedAt time.Time `json:"applied_at"`
+
+	// Files lists each configured file and what was done with it.
+	Files []BootstrapFileResult `json:"files"`
+}
+
+// BootstrapFileResult is the outcome for one bootstrap file.
+type BootstrapFileResult struct {
+	// Path is relative to the repository root.
+	Path string `json:"path"`
+
+	// Status is skipped when the file already existed and overwriting
+	// was not requested.
+	Status BootstrapFileStatus `json:"status"`
+}
+
+// IsPaused reports whether the workspace is paused at now. A timed pause
+// lapses once its Until time passes.
+func (w *Workspace) IsPaused(now time.Time) bool {
+	if w.Pause == nil {
+		return false
+	}
+	return w.Pause.Until == nil || now.Before(*w.Pause.Until)
+}
+
+// HibernateAfter returns how long the workspace's agents may sit idle
+// before they are hibernated, or zero when they never are.
+func (w *Workspace) HibernateAfter() time.Duration {
+	return time.Duration(w.HibernateAfterSeconds) * time.Second
+}
+
 // AgentStats contains the breakdown of agents by state.
 type AgentStats struct {
 	Working int `json:"working"`
@@ -103,7 +222,9 @@ const (
 	AlertTypeCooldown       AlertType = "cooldown"
 	AlertTypeError          AlertType = "error"
 	AlertTypeRateLimit      AlertType = "rate_limit"
+	AlertTypeStuck          AlertType = "stuck"
 	AlertTypeUsageLimit     AlertType = "usage_limit"
+	AlertTypeVersionDrift   AlertType = "version_drift"
 )
 
 // Alert represents a notification requiring attention.
@@ -365,7 +365,7 @@ func (c *Client) SpawnAgent(ctx context.Context, req *SpawnAgentRequest) (*Spawn
 func (c *Client) spawnAgentSSH(ctx context.Context, req *SpawnAgentRequest) (*SpawnAgentResponse, error) {
 	sessionName := req.SessionName
 	if sessionName == "" {
-		sessionName = fmt.Sprintf("swarm-%s", req.WorkspaceID)
+		sessionName = tmux.SessionName(tmux.DefaultSessionPrefix, "", req.WorkspaceID)
 	}
 
 	workDir := req.WorkingDir
@@ -415,7 +415,9 @@ func (c *Client) spawnAgentSSH(ctx context.Context, req *SpawnAgentRequest) (*Sp
 	}, nil
 }
 
-// KillAgent terminates an agent.
+// KillAgent terminates an agent. Without swarmd it returns
+// tmux.ErrPaneStillAlive if the pane survives; force then falls back to
+// killing the pane's process and window.
 func (c *Client) KillAgent(ctx context.Context, agentID string, paneID string, force bool) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -437,7 +439,7 @@ func (c *Client) KillAgent(ctx context.Context, agentID string, paneID string, f
 		time.Sleep(500 * time.Millisecond)
 	}
 
-	return c.tmuxClient.KillPane(ctx, paneID)
+	return c.tmuxClient.KillPaneVerified(ctx, paneID, force)
 }
 
 // SendInput sends text or keys to an agent.
@@ -458,8 +460,7 @@ func (c *Client) SendInput(ctx context.Context, agentID string, paneID string, t
 	// Use SSH/tmux
 	// Send special keys first
 	for _, key := range keys {
-		keyCmd := fmt.Sprintf("tmux send-keys -t %s %s", paneID, key)
-		if _, _, err := c.sshExecutor.Exec(ctx, keyCmd); err != nil {
+		if err := c.tmuxClient.SendKeys(ctx, paneID, key, false, false); err != nil {
 			return fmt.Errorf("failed to send key %q: %w", key, err)
 		}
 	}
@@ -0,0 +1,98 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+)
+
+// DaemonStatus summarizes what a daemon-backed node's swarmd reports about
+// itself.
+type DaemonStatus struct {
+	Version       string `json:"version"`
+	Hostname      string `json:"hostname,omitempty"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+	AgentCount    int    `json:"agent_count"`
+	Health        string `json:"health"`
+}
+
+// WithDaemonClientOptions adds options used when dialing daemon-backed nodes.
+func WithDaemonClientOptions(opts ...swarmd.ClientOption) ServiceOption {
+	return func(s *Service) {
+		s.daemonOpts = append(s.daemonOpts, opts...)
+	}
+}
+
+// DialDaemon connects to the swarmd of a daemon-backed node, sending the
+// node's auth token on every call.
+func DialDaemon(ctx context.Context, node *models.Node, opts ...swarmd.ClientOption) (*swarmd.Client, error) {
+	if node == nil || !node.IsDaemon() {
+		return nil, fmt.Errorf("%w: node has no daemon endpoint", ErrDaemonNotFound)
+	}
+	opts = append([]swarmd.ClientOption{swarmd.WithAuthToken(node.DaemonToken)}, opts...)
+	return swarmd.Dial(ctx, node.DaemonEndpoint, opts...)
+}
+
+// testDaemon pings a daemon-backed node's swarmd and reads its status.
+func (s *Service) testDaemon(ctx context.Context, node *models.Node) (*ConnectionResult, error) {
+	client, err := DialDaemon(ctx, node, s.daemonOpts...)
+	if err != nil {
+		return &ConnectionResult{Success: false, Error: err.Error()}, nil
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, s.DefaultTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.Ping(ctx); err != nil {
+		return &ConnectionResult{
+			Success: false,
+			Error:   fmt.Sprintf("swarmd ping failed: %v", err),
+		}, nil
+	}
+	result := &ConnectionResult{Success: true, Latency: time.Since(start)}
+
+	resp, err := client.GetStatus(ctx)
+	if err != nil {
+		return &ConnectionResult{
+			Success: false,
+			Error:   fmt.Sprintf("swarmd status failed: %v", err),
+		}, nil
+	}
+	status := resp.GetStatus()
+	result.Daemon = &DaemonStatus{
+		Version:       status.GetVersion(),
+		Hostname:      status.GetHostname(),
+		UptimeSeconds: int64(status.GetUptime().AsDuration() / time.Second),
+		AgentCount:    int(status.GetAgentCount()),
+		Health:        strings.ToLower(strings.TrimPrefix(status.GetHealth().GetHealth().String(), "HEALTH_")),
+	}
+	result.Metadata = models.NodeMetadata{
+		Hostname:      status.GetHostname(),
+		SwarmdVersion: status.GetVersion(),
+		SwarmdStatus:  "running",
+	}
+	return result, nil
+}
+
+// validateDaemonEndpoint checks that endpoint is a host:port pair.
+func validateDaemonEndpoint(endpoint string) error {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		return fmt.Errorf("missing host")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port: %s", port)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+	"github.com/opencode-ai/swarm/internal/swarmd/swarmdtest"
+	"github.com/rs/zerolog"
+)
+
+func setupDaemonService(t *testing.T) *Service {
+	t.Helper()
+	testDB, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { testDB.Close() })
+	if err := testDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	daemon := swarmdtest.Serve(t, swarmd.NewServer(zerolog.Nop(), swarmd.WithVersion("9.9.9")), "secret")
+	return NewService(db.NewNodeRepository(testDB),
+		WithDefaultTimeout(5*time.Second),
+		WithDaemonClientOptions(daemon.ClientOptions()...),
+	)
+}
+
+func TestAddDaemonNode(t *testing.T) {
+	service := setupDaemonService(t)
+	ctx := context.Background()
+
+	n := &models.Node{Name: "gpu", DaemonEndpoint: swarmdtest.Endpoint, DaemonToken: "secret"}
+	if err := service.AddNode(ctx, n, true); err != nil {
+		t.Fatalf("AddNode() error = %v", err)
+	}
+	if n.Status != models.NodeStatusOnline || n.Metadata.SwarmdVersion != "9.9.9" || n.Metadata.SwarmdStatus != "running" {
+		t.Fatalf("expected an online node reporting its swarmd, got %+v", n)
+	}
+
+	result, err := service.RefreshNodeStatus(ctx, n.ID)
+	if err != nil {
+		t.Fatalf("RefreshNodeStatus() error = %v", err)
+	}
+	if !result.Success || result.Daemon == nil || result.Daemon.Version != "9.9.9" || result.Daemon.Health == "" {
+		t.Fatalf("expected the daemon status in the refresh, got %+v", result)
+	}
+}
+
+func TestAddDaemonNodeRejected(t *testing.T) {
+	service := setupDaemonService(t)
+	ctx := context.Background()
+
+	wrongToken := &models.Node{Name: "gpu", DaemonEndpoint: swarmdtest.Endpoint, DaemonToken: "nope"}
+	if err := service.AddNode(ctx, wrongToken, true); !errors.Is(err, ErrConnectionFailed) {
+		t.Fatalf("expected a wrong token to fail the connection test, got %v", err)
+	}
+
+	badEndpoint := &models.Node{Name: "gpu", DaemonEndpoint: "gpu"}
+	if err := service.AddNode(ctx, badEndpoint, false); !errors.Is(err, ErrInvalidEndpoint) {
+		t.Fatalf("expected ErrInvalidEndpoint, got %v", err)
+	}
+
+	untested := &models.Node{Name: "gpu", DaemonEndpoint: "gpu:50051"}
+	if err := service.AddNode(ctx, untested, false); err != nil {
+		t.Fatalf("expected an untested daemon node without an SSH target to be added, got %v", err)
+	}
+	if _, err := service.ExecCommand(ctx, untested, "true"); err == nil {
+		t.Fatalf("expected exec on a daemon node without an SSH target to fail")
+	}
+}
@@ -0,0 +1,224 @@
+package node
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/shell"
+)
+
+// DefaultMinDiskFreePercent is the free disk share below which a node is
+// marked degraded.
+const DefaultMinDiskFreePercent = 10
+
+// ErrNoMetrics is returned when probe output holds no usable metrics.
+var ErrNoMetrics = errors.New("no metrics in probe output")
+
+// metricsSection introduces each part of the metrics probe output.
+const metricsSection = "--- "
+
+// metricsCommand returns the single shell command that reads a node's load,
+// memory, disk, and tmux usage. Each part of its output starts with a
+// "--- name" line. Linux and macOS expose the same figures through
+// different tools, so each part tries the Linux source first; a part that
+// fails on both is left empty.
+func metricsCommand(diskPath string) string {
+	root := `"$HOME"`
+	if diskPath != "" {
+		root = shell.Quote(diskPath)
+	}
+	parts := []string{
+		"echo '--- cpus'", "(nproc 2>/dev/null || sysctl -n hw.ncpu 2>/dev/null)",
+		"echo '--- load'", "(cat /proc/loadavg 2>/dev/null || sysctl -n vm.loadavg 2>/dev/null)",
+		"echo '--- mem'", "(cat /proc/meminfo 2>/dev/null || { sysctl -n hw.memsize 2>/dev/null; vm_stat 2>/dev/null; })",
+		"echo '--- root'", "echo " + root,
+		"echo '--- disk'", "df -Pk " + root + " 2>/dev/null",
+		"echo '--- tmux'", "tmux list-panes -a -F '#{session_name}' 2>/dev/null",
+		"true",
+	}
+	return strings.Join(parts, "; ")
+}
+
+// collectMetrics runs the metrics probe over executor.
+func collectMetrics(ctx context.Context, executor execer, diskPath string) (*models.NodeMetrics, error) {
+	stdout, stderr, err := executor.Exec(ctx, metricsCommand(diskPath))
+	if err != nil {
+		return nil, fmt.Errorf("metrics probe failed: %w (stderr: %s)", err, strings.TrimSpace(string(stderr)))
+	}
+	metrics, err := ParseNodeMetrics(string(stdout))
+	if err != nil {
+		return nil, err
+	}
+	metrics.CollectedAt = time.Now().UTC()
+	return metrics, nil
+}
+
+// ParseNodeMetrics parses the output of the metrics probe, as printed on
+// Linux or macOS. Parts that are missing or unreadable are left zero; it
+// fails only when neither load, memory, nor disk could be read.
+func ParseNodeMetrics(output string) (*models.NodeMetrics, error) {
+	sections := splitSections(output)
+	metrics := &models.NodeMetrics{}
+
+	if lines := sections["cpus"]; len(lines) > 0 {
+		metrics.CPUs, _ = strconv.Atoi(strings.TrimSpace(lines[0]))
+	}
+	loadOK := parseLoad(sections["load"], metrics)
+	memOK := parseMemory(sections["mem"], metrics)
+	if lines := sections["root"]; len(lines) > 0 {
+		metrics.DiskPath = strings.TrimSpace(lines[0])
+	}
+	diskOK := parseDisk(sections["disk"], metrics)
+	parseTmux(sections["tmux"], metrics)
+
+	if !loadOK && !memOK && !diskOK {
+		return nil, ErrNoMetrics
+	}
+	return metrics, nil
+}
+
+func splitSections(output string) map[string][]string {
+	sections := make(map[string][]string)
+	current := ""
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, metricsSection); ok {
+			current = strings.TrimSpace(name)
+			sections[current] = nil
+			continue
+		}
+		if current != "" && strings.TrimSpace(line) != "" {
+			sections[current] = append(sections[current], line)
+		}
+	}
+	return sections
+}
+
+// parseLoad reads /proc/loadavg ("0.52 0.58 0.59 1/467 12345") or sysctl
+// vm.loadavg ("{ 1.82 2.01 2.10 }").
+func parseLoad(lines []string, m *models.NodeMetrics) bool {
+	if len(lines) == 0 {
+		return false
+	}
+	fields := strings.Fields(strings.Trim(strings.TrimSpace(lines[0]), "{}"))
+	if len(fields) < 3 {
+		return false
+	}
+	var loads [3]float64
+	for i := range loads {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return false
+		}
+		loads[i] = v
+	}
+	m.Load1, m.Load5, m.Load15 = loads[0], loads[1], loads[2]
+	return true
+}
+
+// vmStatPageSize matches the header of macOS vm_stat output.
+var vmStatPageSize = regexp.MustCompile(`page size of (\d+) bytes`)
+
+// parseMemory reads /proc/meminfo, or the hw.memsize byte count followed by
+// vm_stat output on macOS.
+func parseMemory(lines []string, m *models.NodeMetrics) bool {
+	if len(lines) == 0 {
+		return false
+	}
+
+	if strings.HasPrefix(lines[0], "MemTotal:") {
+		kb := make(map[string]int64)
+		for _, line := range lines {
+			name, rest, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			fields := strings.Fields(rest)
+			if len(fields) == 0 {
+				continue
+			}
+			if v, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+				kb[name] = v
+			}
+		}
+		m.MemTotalBytes = kb["MemTotal"] * 1024
+		if available, ok := kb["MemAvailable"]; ok {
+			m.MemFreeBytes = available * 1024
+		} else {
+			m.MemFreeBytes = (kb["MemFree"] + kb["Buffers"] + kb["Cached"]) * 1024
+		}
+		return m.MemTotalBytes > 0
+	}
+
+	total, err := strconv.ParseInt(strings.TrimSpace(lines[0]), 10, 64)
+	if err != nil {
+		return false
+	}
+	m.MemTotalBytes = total
+
+	var pageSize, freePages int64
+	for _, line := range lines[1:] {
+		if match := vmStatPageSize.FindStringSubmatch(line); match != nil {
+			pageSize, _ = strconv.ParseInt(match[1], 10, 64)
+			continue
+		}
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch name {
+		case "Pages free", "Pages inactive", "Pages speculative":
+			if v, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(rest), "."), 10, 64); err == nil {
+				freePages += v
+			}
+		}
+	}
+	m.MemFreeBytes = freePages * pageSize
+	return true
+}
+
+// dfLine matches a POSIX df -Pk row; the filesystem and mount point may
+// contain spaces.
+var dfLine = regexp.MustCompile(`^(.+?)\s+(\d+)\s+(\d+)\s+(\d+)\s+\d+%\s+(.+)$`)
+
+func parseDisk(lines []string, m *models.NodeMetrics) bool {
+	for _, line := range lines {
+		match := dfLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		total, _ := strconv.ParseInt(match[2], 10, 64)
+		free, _ := strconv.ParseInt(match[4], 10, 64)
+		m.DiskTotalBytes, m.DiskFreeBytes = total*1024, free*1024
+		if m.DiskPath == "" {
+			m.DiskPath = match[5]
+		}
+		return true
+	}
+	return false
+}
+
+func parseTmux(lines []string, m *models.NodeMetrics) {
+	sessions := make(map[string]bool)
+	for _, line := range lines {
+		sessions[strings.TrimSpace(line)] = true
+	}
+	m.TmuxPanes, m.TmuxSessions = len(lines), len(sessions)
+}
+
+// degradedReasons returns why a node with metrics m is short of resources.
+func degradedReasons(m *models.NodeMetrics, minDiskFreePercent float64) []string {
+	var reasons []string
+	if free := m.DiskFreePercent(); free >= 0 && free < minDiskFreePercent {
+		reasons = append(reasons, fmt.Sprintf("disk free %.1f%% on %s is below %g%%", free, m.DiskPath, minDiskFreePercent))
+	}
+	return reasons
+}
@@ -0,0 +1,190 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+const linuxMetricsOutput = `--- cpus
+8
+--- load
+0.52 0.58 0.59 1/467 12345
+--- mem
+MemTotal:       16303584 kB
+MemFree:         1024000 kB
+MemAvailable:    8151792 kB
+Buffers:          402112 kB
+Cached:          5203448 kB
+SwapCached:            0 kB
+Active:          7003512 kB
+--- root
+/home/dev
+--- disk
+Filesystem     1024-blocks      Used Available Capacity Mounted on
+/dev/nvme0n1p2   479596204 401421404  53738260      89% /
+--- tmux
+swarm-api
+swarm-api
+swarm-api
+swarm-web
+`
+
+const macOSMetricsOutput = `--- cpus
+10
+--- load
+{ 1.82 2.01 2.10 }
+--- mem
+17179869184
+Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                                5000.
+Pages active:                            300000.
+Pages inactive:                          200000.
+Pages speculative:                         3000.
+Pages throttled:                              0.
+Pages wired down:                        150000.
+--- root
+/Users/dev
+--- disk
+Filesystem     1024-blocks      Used Available Capacity  Mounted on
+/dev/disk3s5     482797652 254254780 217615436    54%    /System/Volumes/Data
+--- tmux
+`
+
+func TestParseNodeMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    models.NodeMetrics
+		wantErr error
+	}{
+		{
+			name:   "linux",
+			output: linuxMetricsOutput,
+			want: models.NodeMetrics{
+				CPUs: 8, Load1: 0.52, Load5: 0.58, Load15: 0.59,
+				MemTotalBytes: 16303584 * 1024, MemFreeBytes: 8151792 * 1024,
+				DiskPath: "/home/dev", DiskTotalBytes: 479596204 * 1024, DiskFreeBytes: 53738260 * 1024,
+				TmuxSessions: 2, TmuxPanes: 4,
+			},
+		},
+		{
+			name: "linux without MemAvailable",
+			output: `--- load
+0.00 0.01 0.05 1/120 999
+--- mem
+MemTotal:        2048000 kB
+MemFree:          100000 kB
+Buffers:           20000 kB
+Cached:           300000 kB
+`,
+			want: models.NodeMetrics{Load15: 0.05, Load5: 0.01, MemTotalBytes: 2048000 * 1024, MemFreeBytes: 420000 * 1024},
+		},
+		{
+			name:   "macos",
+			output: macOSMetricsOutput,
+			want: models.NodeMetrics{
+				CPUs: 10, Load1: 1.82, Load5: 2.01, Load15: 2.10,
+				MemTotalBytes: 17179869184, MemFreeBytes: (5000 + 200000 + 3000) * 16384,
+				DiskPath: "/Users/dev", DiskTotalBytes: 482797652 * 1024, DiskFreeBytes: 217615436 * 1024,
+			},
+		},
+		{
+			name: "mount point with spaces",
+			output: `--- disk
+Filesystem 1024-blocks Used Available Capacity Mounted on
+/dev/sdb1 1000 900 100 90% /mnt/data disk
+`,
+			want: models.NodeMetrics{DiskPath: "/mnt/data disk", DiskTotalBytes: 1000 * 1024, DiskFreeBytes: 100 * 1024},
+		},
+		{
+			name:    "empty",
+			output:  "",
+			wantErr: ErrNoMetrics,
+		},
+		{
+			name: "every probe failed",
+			output: `--- cpus
+--- load
+--- mem
+--- root
+/home/dev
+--- disk
+--- tmux
+`,
+			wantErr: ErrNoMetrics,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNodeMetrics(tt.output)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v (%+v)", tt.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseNodeMetrics failed: %v", err)
+			}
+			if got.CPUs != tt.want.CPUs || got.Load1 != tt.want.Load1 || got.Load5 != tt.want.Load5 || got.Load15 != tt.want.Load15 {
+				t.Errorf("cpus/load: got %d %v %v %v, want %d %v %v %v",
+					got.CPUs, got.Load1, got.Load5, got.Load15, tt.want.CPUs, tt.want.Load1, tt.want.Load5, tt.want.Load15)
+			}
+			if got.MemTotalBytes != tt.want.MemTotalBytes || got.MemFreeBytes != tt.want.MemFreeBytes {
+				t.Errorf("memory: got %d/%d, want %d/%d", got.MemFreeBytes, got.MemTotalBytes, tt.want.MemFreeBytes, tt.want.MemTotalBytes)
+			}
+			if got.DiskPath != tt.want.DiskPath || got.DiskTotalBytes != tt.want.DiskTotalBytes || got.DiskFreeBytes != tt.want.DiskFreeBytes {
+				t.Errorf("disk: got %d/%d on %q, want %d/%d on %q",
+					got.DiskFreeBytes, got.DiskTotalBytes, got.DiskPath, tt.want.DiskFreeBytes, tt.want.DiskTotalBytes, tt.want.DiskPath)
+			}
+			if got.TmuxSessions != tt.want.TmuxSessions || got.TmuxPanes != tt.want.TmuxPanes {
+				t.Errorf("tmux: got %d sessions %d panes, want %d %d", got.TmuxSessions, got.TmuxPanes, tt.want.TmuxSessions, tt.want.TmuxPanes)
+			}
+		})
+	}
+}
+
+func TestDegradedReasons(t *testing.T) {
+	m, err := ParseNodeMetrics(linuxMetricsOutput)
+	if err != nil {
+		t.Fatalf("ParseNodeMetrics failed: %v", err)
+	}
+
+	// 53738260 of 479596204 blocks free is about 11.2%.
+	if reasons := degradedReasons(m, DefaultMinDiskFreePercent); len(reasons) != 0 {
+		t.Fatalf("expected no reasons at 10%%, got %v", reasons)
+	}
+	reasons := degradedReasons(m, 15)
+	if len(reasons) != 1 || reasons[0] != "disk free 11.2% on /home/dev is below 15%" {
+		t.Fatalf("unexpected reasons %v", reasons)
+	}
+	if reasons := degradedReasons(&models.NodeMetrics{}, 15); len(reasons) != 0 {
+		t.Fatalf("expected unknown disk to be ignored, got %v", reasons)
+	}
+}
+
+func TestCollectMetrics(t *testing.T) {
+	executor := &fakeExecutor{results: map[string]execResult{
+		metricsCommand("/srv/it's here"): {stdout: linuxMetricsOutput},
+	}}
+	if cmd := metricsCommand("/srv/it's here"); !strings.Contains(cmd, `df -Pk '/srv/it'\''s here'`) {
+		t.Fatalf("expected the disk path quoted, got %s", cmd)
+	}
+
+	m, err := collectMetrics(context.Background(), executor, "/srv/it's here")
+	if err != nil {
+		t.Fatalf("collectMetrics failed: %v", err)
+	}
+	if m.CollectedAt.IsZero() || m.TmuxPanes != 4 {
+		t.Fatalf("unexpected metrics %+v", m)
+	}
+
+	if _, err := collectMetrics(context.Background(), &fakeExecutor{}, ""); err == nil {
+		t.Fatal("expected an error when the probe fails")
+	}
+}
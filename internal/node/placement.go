@@ -0,0 +1,272 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Placement errors.
+var (
+	ErrUnknownPlacementStrategy = errors.New("unknown placement strategy")
+	ErrNoPlacementCandidates    = errors.New("no node satisfies placement constraints")
+	ErrInvalidLabel             = errors.New("invalid label")
+)
+
+// PlacementRequest describes how to choose a node for a new workspace.
+type PlacementRequest struct {
+	// Strategy selects among eligible nodes. Defaults to least-agents.
+	Strategy models.PlacementStrategy
+
+	// RequireLabels restricts candidates to nodes carrying all of these labels.
+	RequireLabels map[string]string
+
+	// PinLabels identifies the pinned node for the pinned-by-label strategy.
+	PinLabels map[string]string
+
+	// LastNodeID is the node chosen for the previous workspace, used by
+	// round-robin to pick the next one. Service.PlaceWorkspace fills it in.
+	LastNodeID string
+}
+
+// PlacementCandidate is a node considered for placement.
+type PlacementCandidate struct {
+	Node       *models.Node
+	AgentCount int
+}
+
+// Placement is the outcome of node selection.
+type Placement struct {
+	Node     *models.Node
+	Strategy models.PlacementStrategy
+	Reason   string
+}
+
+// Record returns the placement as stored on the workspace.
+func (p *Placement) Record() *models.WorkspacePlacement {
+	return &models.WorkspacePlacement{Strategy: p.Strategy, Reason: p.Reason}
+}
+
+// SelectNode picks a node from candidates according to req. Only online
+// nodes carrying every required label are chosen; nodes offline or not yet
+// checked are skipped. Selection is
+// deterministic: ties are broken by node name.
+func SelectNode(req PlacementRequest, candidates []PlacementCandidate) (*Placement, error) {
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = models.PlacementLeastAgents
+	}
+	if !strategy.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPlacementStrategy, strategy)
+	}
+	if strategy == models.PlacementPinnedByLabel && len(req.PinLabels) == 0 {
+		return nil, fmt.Errorf("%s strategy requires pin labels", strategy)
+	}
+
+	eligible := make([]PlacementCandidate, 0, len(candidates))
+	offline, unknown, unlabeled := 0, 0, 0
+	for _, candidate := range candidates {
+		switch {
+		case candidate.Node.Status == models.NodeStatusOffline:
+			offline++
+		case candidate.Node.Status != models.NodeStatusOnline:
+			unknown++
+		case !HasLabels(candidate.Node, req.RequireLabels):
+			unlabeled++
+		default:
+			eligible = append(eligible, candidate)
+		}
+	}
+	sort.Slice(eligible, func(i, j int) bool {
+		return eligible[i].Node.Name < eligible[j].Node.Name
+	})
+
+	skipped := skippedSummary(offline, unknown, unlabeled, req.RequireLabels)
+	if len(eligible) == 0 {
+		if skipped == "" {
+			return nil, ErrNoPlacementCandidates
+		}
+		return nil, fmt.Errorf("%w (%s)", ErrNoPlacementCandidates, skipped)
+	}
+
+	var chosen PlacementCandidate
+	var reason string
+	switch strategy {
+	case models.PlacementLeastAgents:
+		chosen = eligible[0]
+		for _, candidate := range eligible[1:] {
+			if candidate.AgentCount < chosen.AgentCount {
+				chosen = candidate
+			}
+		}
+		reason = fmt.Sprintf("fewest live agents (%d) of %d eligible nodes", chosen.AgentCount, len(eligible))
+
+	case models.PlacementRoundRobin:
+		chosen = eligible[0]
+		previous := ""
+		for _, candidate := range candidates {
+			if candidate.Node.ID == req.LastNodeID {
+				previous = candidate.Node.Name
+				break
+			}
+		}
+		if previous != "" {
+			for _, candidate := range eligible {
+				if candidate.Node.Name > previous {
+					chosen = candidate
+					break
+				}
+			}
+			reason = fmt.Sprintf("next of %d eligible nodes after %s", len(eligible), previous)
+		} else {
+			reason = fmt.Sprintf("first of %d eligible nodes", len(eligible))
+		}
+
+	case models.PlacementPinnedByLabel:
+		found := false
+		for _, candidate := range eligible {
+			if HasLabels(candidate.Node, req.PinLabels) {
+				chosen, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: no eligible node labeled %s", ErrNoPlacementCandidates, FormatLabels(req.PinLabels))
+		}
+		reason = fmt.Sprintf("pinned by label %s", FormatLabels(req.PinLabels))
+
+	case models.PlacementLeastLoaded:
+		chosen = eligible[0]
+		for _, candidate := range eligible[1:] {
+			if lessLoaded(candidate.Node, chosen.Node) {
+				chosen = candidate
+			}
+		}
+		switch m := chosen.Node.Metrics; {
+		case m == nil:
+			reason = fmt.Sprintf("no eligible node of %d reports metrics; chose by name", len(eligible))
+		case len(m.Degraded) > 0:
+			reason = fmt.Sprintf("lowest load per CPU (%.2f) of %d eligible nodes, all degraded", m.LoadPerCPU(), len(eligible))
+		default:
+			reason = fmt.Sprintf("lowest load per CPU (%.2f) of %d eligible nodes", m.LoadPerCPU(), len(eligible))
+		}
+	}
+
+	if skipped != "" {
+		reason += "; skipped " + skipped
+	}
+
+	return &Placement{Node: chosen.Node, Strategy: strategy, Reason: reason}, nil
+}
+
+// lessLoaded reports whether a should be preferred over b by the
+// least-loaded strategy: healthy nodes before degraded ones, nodes with
+// metrics before nodes without, then by load per CPU.
+func lessLoaded(a, b *models.Node) bool {
+	if a.Metrics == nil || b.Metrics == nil {
+		return a.Metrics != nil && b.Metrics == nil
+	}
+	aDegraded, bDegraded := len(a.Metrics.Degraded) > 0, len(b.Metrics.Degraded) > 0
+	if aDegraded != bDegraded {
+		return bDegraded
+	}
+	return a.Metrics.LoadPerCPU() < b.Metrics.LoadPerCPU()
+}
+
+// PlaceWorkspace selects a node for a new workspace using live agent counts
+// and the stored node status.
+func (s *Service) PlaceWorkspace(ctx context.Context, req PlacementRequest) (*Placement, error) {
+	nodes, err := s.repo.List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	counts, err := s.repo.AgentCountsByNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.LastNodeID == "" {
+		req.LastNodeID, err = s.repo.LatestWorkspaceNodeID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	candidates := make([]PlacementCandidate, 0, len(nodes))
+	for _, node := range nodes {
+		node.AgentCount = counts[node.ID]
+		candidates = append(candidates, PlacementCandidate{Node: node, AgentCount: counts[node.ID]})
+	}
+
+	placement, err := SelectNode(req, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("node_id", placement.Node.ID).
+		Str("strategy", string(placement.Strategy)).
+		Str("reason", placement.Reason).
+		Msg("workspace placed")
+
+	return placement, nil
+}
+
+// HasLabels reports whether node carries every label in want.
+func HasLabels(node *models.Node, want map[string]string) bool {
+	for key, value := range want {
+		if got, ok := node.Labels[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLabels parses key=value pairs into a label map.
+func ParseLabels(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w %q: expected key=value", ErrInvalidLabel, pair)
+		}
+		labels[key] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
+
+// FormatLabels renders labels as sorted, comma-separated key=value pairs.
+func FormatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func skippedSummary(offline, unknown, unlabeled int, required map[string]string) string {
+	var parts []string
+	if offline > 0 {
+		parts = append(parts, fmt.Sprintf("%d offline", offline))
+	}
+	if unknown > 0 {
+		parts = append(parts, fmt.Sprintf("%d with unknown status", unknown))
+	}
+	if unlabeled > 0 {
+		parts = append(parts, fmt.Sprintf("%d without %s", unlabeled, FormatLabels(required)))
+	}
+	return strings.Join(parts, ", ")
+}
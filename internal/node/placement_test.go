@@ -0,0 +1,327 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func candidate(id string, status models.NodeStatus, agents int, labels map[string]string) PlacementCandidate {
+	return PlacementCandidate{
+		Node:       &models.Node{ID: id, Name: id, Status: status, Labels: labels},
+		AgentCount: agents,
+	}
+}
+
+func loaded(c PlacementCandidate, cpus int, load1 float64, degraded ...string) PlacementCandidate {
+	c.Node.Metrics = &models.NodeMetrics{CPUs: cpus, Load1: load1, Degraded: degraded}
+	return c
+}
+
+func TestSelectNode(t *testing.T) {
+	gpu := map[string]string{"gpu": "true"}
+	online := models.NodeStatusOnline
+	offline := models.NodeStatusOffline
+	unknown := models.NodeStatusUnknown
+
+	tests := []struct {
+		name       string
+		req        PlacementRequest
+		candidates []PlacementCandidate
+		wantNode   string
+		wantErr    error
+		wantReason []string
+	}{
+		{
+			name:       "default strategy is least agents",
+			candidates: []PlacementCandidate{candidate("a", online, 3, nil), candidate("b", online, 1, nil), candidate("c", online, 2, nil)},
+			wantNode:   "b",
+			wantReason: []string{"fewest live agents (1) of 3 eligible nodes"},
+		},
+		{
+			name:       "least agents breaks ties by name",
+			req:        PlacementRequest{Strategy: models.PlacementLeastAgents},
+			candidates: []PlacementCandidate{candidate("c", online, 1, nil), candidate("a", online, 1, nil), candidate("b", online, 4, nil)},
+			wantNode:   "a",
+		},
+		{
+			name:       "least agents skips offline nodes",
+			req:        PlacementRequest{Strategy: models.PlacementLeastAgents},
+			candidates: []PlacementCandidate{candidate("idle", offline, 0, nil), candidate("busy", online, 5, nil)},
+			wantNode:   "busy",
+			wantReason: []string{"skipped 1 offline"},
+		},
+		{
+			name:       "unknown status is skipped",
+			req:        PlacementRequest{Strategy: models.PlacementLeastAgents},
+			candidates: []PlacementCandidate{candidate("new", unknown, 0, nil), candidate("old", online, 2, nil)},
+			wantNode:   "old",
+			wantReason: []string{"skipped 1 with unknown status"},
+		},
+		{
+			name:       "required labels filter candidates",
+			req:        PlacementRequest{Strategy: models.PlacementLeastAgents, RequireLabels: gpu},
+			candidates: []PlacementCandidate{candidate("cpu", online, 0, nil), candidate("gpu1", online, 3, gpu), candidate("gpu2", online, 2, map[string]string{"gpu": "true", "zone": "eu"})},
+			wantNode:   "gpu2",
+			wantReason: []string{"of 2 eligible nodes", "skipped 1 without gpu=true"},
+		},
+		{
+			name:       "required label value must match",
+			req:        PlacementRequest{RequireLabels: gpu},
+			candidates: []PlacementCandidate{candidate("a", online, 0, map[string]string{"gpu": "false"})},
+			wantErr:    ErrNoPlacementCandidates,
+		},
+		{
+			name:       "round robin without history takes first by name",
+			req:        PlacementRequest{Strategy: models.PlacementRoundRobin},
+			candidates: []PlacementCandidate{candidate("b", online, 0, nil), candidate("a", online, 9, nil)},
+			wantNode:   "a",
+			wantReason: []string{"first of 2 eligible nodes"},
+		},
+		{
+			name:       "round robin advances past last node",
+			req:        PlacementRequest{Strategy: models.PlacementRoundRobin, LastNodeID: "a"},
+			candidates: []PlacementCandidate{candidate("a", online, 0, nil), candidate("b", online, 9, nil), candidate("c", online, 0, nil)},
+			wantNode:   "b",
+			wantReason: []string{"after a"},
+		},
+		{
+			name:       "round robin wraps around",
+			req:        PlacementRequest{Strategy: models.PlacementRoundRobin, LastNodeID: "c"},
+			candidates: []PlacementCandidate{candidate("a", online, 0, nil), candidate("b", online, 0, nil), candidate("c", online, 0, nil)},
+			wantNode:   "a",
+		},
+		{
+			name:       "round robin continues after a node that went offline",
+			req:        PlacementRequest{Strategy: models.PlacementRoundRobin, LastNodeID: "b"},
+			candidates: []PlacementCandidate{candidate("a", online, 0, nil), candidate("b", offline, 0, nil), candidate("c", online, 0, nil)},
+			wantNode:   "c",
+		},
+		{
+			name:       "round robin skips offline successor",
+			req:        PlacementRequest{Strategy: models.PlacementRoundRobin, LastNodeID: "a"},
+			candidates: []PlacementCandidate{candidate("a", online, 0, nil), candidate("b", offline, 0, nil), candidate("c", online, 0, nil)},
+			wantNode:   "c",
+		},
+		{
+			name:       "round robin with unknown last node starts over",
+			req:        PlacementRequest{Strategy: models.PlacementRoundRobin, LastNodeID: "gone"},
+			candidates: []PlacementCandidate{candidate("b", online, 0, nil), candidate("a", online, 0, nil)},
+			wantNode:   "a",
+		},
+		{
+			name:       "pinned by label picks matching node",
+			req:        PlacementRequest{Strategy: models.PlacementPinnedByLabel, PinLabels: map[string]string{"role": "build"}},
+			candidates: []PlacementCandidate{candidate("a", online, 0, nil), candidate("b", online, 7, map[string]string{"role": "build"})},
+			wantNode:   "b",
+			wantReason: []string{"pinned by label role=build"},
+		},
+		{
+			name:       "pinned by label ignores offline pin",
+			req:        PlacementRequest{Strategy: models.PlacementPinnedByLabel, PinLabels: map[string]string{"role": "build"}},
+			candidates: []PlacementCandidate{candidate("a", online, 0, nil), candidate("b", offline, 0, map[string]string{"role": "build"})},
+			wantErr:    ErrNoPlacementCandidates,
+		},
+		{
+			name:       "pinned by label combines with required labels",
+			req:        PlacementRequest{Strategy: models.PlacementPinnedByLabel, PinLabels: map[string]string{"role": "build"}, RequireLabels: gpu},
+			candidates: []PlacementCandidate{candidate("a", online, 0, map[string]string{"role": "build"}), candidate("b", online, 0, map[string]string{"role": "build", "gpu": "true"})},
+			wantNode:   "b",
+		},
+		{
+			name:       "pinned by label requires pin labels",
+			req:        PlacementRequest{Strategy: models.PlacementPinnedByLabel},
+			candidates: []PlacementCandidate{candidate("a", online, 0, nil)},
+			wantErr:    errors.New("pinned-by-label strategy requires pin labels"),
+		},
+		{
+			name:       "least loaded compares load per CPU",
+			req:        PlacementRequest{Strategy: models.PlacementLeastLoaded},
+			candidates: []PlacementCandidate{loaded(candidate("small", online, 0, nil), 2, 1.5), loaded(candidate("big", online, 9, nil), 16, 4)},
+			wantNode:   "big",
+			wantReason: []string{"lowest load per CPU (0.25) of 2 eligible nodes"},
+		},
+		{
+			name:       "least loaded prefers healthy nodes",
+			req:        PlacementRequest{Strategy: models.PlacementLeastLoaded},
+			candidates: []PlacementCandidate{loaded(candidate("full", online, 0, nil), 8, 0, "disk free 2.0% on / is below 10%"), loaded(candidate("ok", online, 0, nil), 8, 6)},
+			wantNode:   "ok",
+		},
+		{
+			name:       "least loaded prefers nodes with metrics",
+			req:        PlacementRequest{Strategy: models.PlacementLeastLoaded},
+			candidates: []PlacementCandidate{candidate("a", online, 0, nil), loaded(candidate("b", online, 0, nil), 1, 3)},
+			wantNode:   "b",
+		},
+		{
+			name:       "least loaded without metrics takes first by name",
+			req:        PlacementRequest{Strategy: models.PlacementLeastLoaded},
+			candidates: []PlacementCandidate{candidate("b", online, 0, nil), candidate("a", online, 0, nil)},
+			wantNode:   "a",
+			wantReason: []string{"no eligible node of 2 reports metrics"},
+		},
+		{
+			name:       "least loaded when every node is degraded",
+			req:        PlacementRequest{Strategy: models.PlacementLeastLoaded},
+			candidates: []PlacementCandidate{loaded(candidate("a", online, 0, nil), 4, 2, "low disk"), loaded(candidate("b", online, 0, nil), 4, 1, "low disk")},
+			wantNode:   "b",
+			wantReason: []string{"all degraded"},
+		},
+		{
+			name:       "unknown strategy",
+			req:        PlacementRequest{Strategy: "random"},
+			candidates: []PlacementCandidate{candidate("a", online, 0, nil)},
+			wantErr:    ErrUnknownPlacementStrategy,
+		},
+		{
+			name:    "no candidates",
+			wantErr: ErrNoPlacementCandidates,
+		},
+		{
+			name:       "all nodes offline",
+			candidates: []PlacementCandidate{candidate("a", offline, 0, nil), candidate("b", offline, 0, nil)},
+			wantErr:    ErrNoPlacementCandidates,
+			wantReason: []string{"2 offline"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement, err := SelectNode(tt.req, tt.candidates)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("expected error %v, got placement on %s", tt.wantErr, placement.Node.Name)
+				}
+				if !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				for _, want := range tt.wantReason {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("expected error %q to mention %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectNode failed: %v", err)
+			}
+			if placement.Node.Name != tt.wantNode {
+				t.Errorf("expected node %s, got %s (%s)", tt.wantNode, placement.Node.Name, placement.Reason)
+			}
+			wantStrategy := tt.req.Strategy
+			if wantStrategy == "" {
+				wantStrategy = models.PlacementLeastAgents
+			}
+			if placement.Strategy != wantStrategy {
+				t.Errorf("expected strategy %s, got %s", wantStrategy, placement.Strategy)
+			}
+			for _, want := range tt.wantReason {
+				if !strings.Contains(placement.Reason, want) {
+					t.Errorf("expected reason %q to mention %q", placement.Reason, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels([]string{"gpu=true", " zone = eu ", "empty="})
+	if err != nil {
+		t.Fatalf("ParseLabels failed: %v", err)
+	}
+	if len(labels) != 3 || labels["gpu"] != "true" || labels["zone"] != "eu" || labels["empty"] != "" {
+		t.Fatalf("unexpected labels %v", labels)
+	}
+	if got := FormatLabels(labels); got != "empty=,gpu=true,zone=eu" {
+		t.Errorf("FormatLabels = %q", got)
+	}
+
+	for _, bad := range []string{"gpu", "=true"} {
+		if _, err := ParseLabels([]string{bad}); !errors.Is(err, ErrInvalidLabel) {
+			t.Errorf("expected ErrInvalidLabel for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestPlaceWorkspace(t *testing.T) {
+	ctx := context.Background()
+	testDB, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	defer testDB.Close()
+	if err := testDB.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(testDB)
+	wsRepo := db.NewWorkspaceRepository(testDB)
+	agentRepo := db.NewAgentRepository(testDB)
+	service := NewService(nodeRepo)
+
+	nodes := map[string]*models.Node{}
+	for _, n := range []*models.Node{
+		{Name: "alpha", IsLocal: true, SSHBackend: models.SSHBackendAuto, Status: models.NodeStatusOnline},
+		{Name: "beta", SSHTarget: "user@beta", SSHBackend: models.SSHBackendAuto, Status: models.NodeStatusOnline, Labels: map[string]string{"gpu": "true"}},
+		{Name: "gamma", SSHTarget: "user@gamma", SSHBackend: models.SSHBackendAuto, Status: models.NodeStatusOffline},
+	} {
+		if err := nodeRepo.Create(ctx, n); err != nil {
+			t.Fatalf("create node %s: %v", n.Name, err)
+		}
+		nodes[n.Name] = n
+	}
+
+	// alpha runs two live agents, beta one live and two stopped ones.
+	addAgents := func(node string, states ...models.AgentState) {
+		ws := &models.Workspace{NodeID: nodes[node].ID, RepoPath: "/repo/" + node, TmuxSession: "swarm-" + node}
+		if err := wsRepo.Create(ctx, ws); err != nil {
+			t.Fatalf("create workspace: %v", err)
+		}
+		for i, state := range states {
+			agent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: ws.TmuxSession + ":0." + string(rune('0'+i)), State: state}
+			if err := agentRepo.Create(ctx, agent); err != nil {
+				t.Fatalf("create agent: %v", err)
+			}
+		}
+	}
+	addAgents("alpha", models.AgentStateWorking, models.AgentStateIdle)
+	addAgents("beta", models.AgentStateIdle, models.AgentStateStopped, models.AgentStateStopped)
+
+	counts, err := nodeRepo.AgentCountsByNode(ctx)
+	if err != nil {
+		t.Fatalf("AgentCountsByNode failed: %v", err)
+	}
+	if counts[nodes["alpha"].ID] != 2 || counts[nodes["beta"].ID] != 1 || counts[nodes["gamma"].ID] != 0 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+
+	placement, err := service.PlaceWorkspace(ctx, PlacementRequest{})
+	if err != nil {
+		t.Fatalf("PlaceWorkspace failed: %v", err)
+	}
+	if placement.Node.Name != "beta" || !strings.Contains(placement.Reason, "skipped 1 offline") {
+		t.Errorf("expected beta with offline skip, got %s (%s)", placement.Node.Name, placement.Reason)
+	}
+
+	// The latest workspace is on beta, so round-robin moves on to gamma,
+	// which is offline, and wraps to alpha.
+	placement, err = service.PlaceWorkspace(ctx, PlacementRequest{Strategy: models.PlacementRoundRobin})
+	if err != nil {
+		t.Fatalf("PlaceWorkspace failed: %v", err)
+	}
+	if placement.Node.Name != "alpha" {
+		t.Errorf("expected round-robin to wrap to alpha, got %s (%s)", placement.Node.Name, placement.Reason)
+	}
+
+	placement, err = service.PlaceWorkspace(ctx, PlacementRequest{RequireLabels: map[string]string{"gpu": "true"}})
+	if err != nil {
+		t.Fatalf("PlaceWorkspace failed: %v", err)
+	}
+	if placement.Node.Name != "beta" || placement.Node.Labels["gpu"] != "true" {
+		t.Errorf("expected labeled beta, got %s", placement.Node.Name)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/opencode-ai/swarm/internal/logging"
 	"github.com/opencode-ai/swarm/internal/models"
 	"github.com/opencode-ai/swarm/internal/ssh"
+	"github.com/opencode-ai/swarm/internal/swarmd"
 	"github.com/rs/zerolog"
 )
 
@@ -24,6 +25,7 @@ var (
 	ErrNodeAlreadyExists = errors.New("node already exists")
 	ErrInvalidSSHTarget  = errors.New("invalid SSH target format")
 	ErrConnectionFailed  = errors.New("connection test failed")
+	ErrInvalidEndpoint   = errors.New("invalid daemon endpoint")
 )
 
 // Service manages Swarm nodes.
@@ -32,6 +34,14 @@ type Service struct {
 	publisher events.Publisher
 	logger    zerolog.Logger
 
+	// daemonOpts are added when dialing daemon-backed nodes.
+	daemonOpts []swarmd.ClientOption
+
+	// metricsDiskPath is where disk usage is measured; empty means the
+	// connecting user's home directory.
+	metricsDiskPath    string
+	minDiskFreePercent float64
+
 	// DefaultTimeout is the default timeout for SSH operations.
 	DefaultTimeout time.Duration
 }
@@ -53,12 +63,30 @@ func WithPublisher(publisher events.Publisher) ServiceOption {
 	}
 }
 
+// WithMetricsDiskPath sets the path on each node whose filesystem usage is
+// reported in its metrics.
+func WithMetricsDiskPath(path string) ServiceOption {
+	return func(s *Service) {
+		s.metricsDiskPath = path
+	}
+}
+
+// WithMinDiskFreePercent sets the free disk share below which a node is
+// marked degraded.
+func WithMinDiskFreePercent(percent float64) ServiceOption {
+	return func(s *Service) {
+		s.minDiskFreePercent = percent
+	}
+}
+
 // NewService creates a new NodeService.
 func NewService(repo *db.NodeRepository, opts ...ServiceOption) *Service {
 	s := &Service{
 		repo:           repo,
 		logger:         logging.Component("node"),
 		DefaultTimeout: 30 * time.Second,
+
+		minDiskFreePercent: DefaultMinDiskFreePercent,
 	}
 
 	for _, opt := range opts {
@@ -76,6 +104,11 @@ func (s *Service) AddNode(ctx context.Context, node *models.Node, testConnection
 			return fmt.Errorf("%w: %v", ErrInvalidSSHTarget, err)
 		}
 	}
+	if node.IsDaemon() {
+		if err := validateDaemonEndpoint(node.DaemonEndpoint); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidEndpoint, err)
+		}
+	}
 
 	// Set defaults
 	if node.SSHBackend == "" {
@@ -110,6 +143,7 @@ func (s *Service) AddNode(ctx context.Context, node *models.Node, testConnection
 		Str("node_id", node.ID).
 		Str("name", node.Name).
 		Bool("is_local", node.IsLocal).
+		Str("daemon_endpoint", node.DaemonEndpoint).
 		Msg("node added")
 
 	// Emit event
@@ -246,10 +280,21 @@ type ConnectionResult struct {
 	Latency  time.Duration
 	Error    string
 	Metadata models.NodeMetadata
+
+	// Metrics is the node's resource usage, when it could be read.
+	Metrics *models.NodeMetrics
+
+	// Daemon is the swarmd status of a daemon-backed node.
+	Daemon *DaemonStatus
 }
 
-// TestConnection tests SSH connectivity to a node.
+// TestConnection tests connectivity to a node: over SSH, or through swarmd
+// Ping and GetStatus for daemon-backed nodes.
 func (s *Service) TestConnection(ctx context.Context, node *models.Node) (*ConnectionResult, error) {
+	if node.IsDaemon() {
+		return s.testDaemon(ctx, node)
+	}
+
 	executor, err := s.executorForNode(node)
 	if err != nil {
 		return &ConnectionResult{
@@ -288,6 +333,14 @@ func (s *Service) TestConnection(ctx context.Context, node *models.Node) (*Conne
 		result.Metadata.Platform = "local"
 	}
 
+	metrics, err := collectMetrics(ctx, executor, s.metricsDiskPath)
+	if err != nil {
+		s.logger.Debug().Err(err).Str("node", node.Name).Msg("failed to collect node metrics")
+	} else {
+		metrics.Degraded = degradedReasons(metrics, s.minDiskFreePercent)
+		result.Metrics = metrics
+	}
+
 	return result, nil
 }
 
@@ -295,6 +348,9 @@ func (s *Service) executorForNode(node *models.Node) (ssh.Executor, error) {
 	if node.IsLocal {
 		return ssh.NewLocalExecutor(), nil
 	}
+	if node.SSHTarget == "" && node.IsDaemon() {
+		return nil, fmt.Errorf("node %s is reached through swarmd and has no SSH target", node.Name)
+	}
 
 	// Parse SSH target
 	user, host, port := ParseSSHTarget(node.SSHTarget)
@@ -346,6 +402,17 @@ func (s *Service) RefreshNodeStatus(ctx context.Context, id string) (*Connection
 		return nil, fmt.Errorf("failed to update node status: %w", err)
 	}
 
+	if result.Metrics != nil {
+		if err := s.repo.UpdateMetrics(ctx, id, result.Metrics); err != nil {
+			return nil, err
+		}
+		wasDegraded := node.Metrics != nil && len(node.Metrics.Degraded) > 0
+		if len(result.Metrics.Degraded) > 0 && !wasDegraded {
+			s.logger.Warn().Str("node_id", id).Strs("reasons", result.Metrics.Degraded).Msg("node degraded")
+			s.publishEvent(ctx, models.EventTypeNodeDegraded, id, models.NodeDegradedPayload{Reasons: result.Metrics.Degraded})
+		}
+	}
+
 	// Emit status change event
 	if oldStatus != newStatus {
 		if newStatus == models.NodeStatusOnline {
@@ -495,9 +562,13 @@ func (s *Service) ExecCommand(ctx context.Context, node *models.Node, cmd string
 		result.Error = execErr.Error()
 		// Try to extract exit code from error
 		var exitErr *ssh.ExitError
-		if errors.As(execErr, &exitErr) {
+		var cmdErr *ssh.ExecError
+		switch {
+		case errors.As(execErr, &cmdErr):
+			result.ExitCode = cmdErr.ExitCode
+		case errors.As(execErr, &exitErr):
 			result.ExitCode = exitErr.Code
-		} else {
+		default:
 			result.ExitCode = 1
 		}
 	}
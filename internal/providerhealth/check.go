@@ -0,0 +1,160 @@
+package providerhealth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+var defaultHTTPClient = &http.Client{}
+
+// anthropicVersion is the API version sent with Anthropic pings.
+const anthropicVersion = "2023-06-01"
+
+// check probes one provider with acct's credential. It reports false when
+// no check produced a result, e.g. when only the status page is probed and
+// it could not be read.
+func (p *Prober) check(ctx context.Context, settings Settings, acct *models.Account, endpoints Endpoints) (Observation, bool) {
+	var observations []Observation
+	if endpoints.API != "" {
+		credential, err := p.resolvers.Resolve(ctx, acct.CredentialRef)
+		if err != nil {
+			// Any answer shows whether the API is up; a rejected
+			// credential still does.
+			p.logger.Debug().Err(err).Str("account_id", acct.ID).Msg("failed to resolve credential for provider probe; pinging without it")
+			credential = ""
+		}
+		observations = append(observations, p.pingAPI(ctx, settings, acct.Provider, endpoints.API, credential))
+	}
+	if settings.StatusPages && endpoints.StatusPage != "" {
+		obs, err := p.readStatusPage(ctx, settings, endpoints.StatusPage)
+		if err != nil {
+			p.logger.Debug().Err(err).Str("provider", string(acct.Provider)).Msg("failed to read provider status page")
+		} else {
+			observations = append(observations, obs)
+		}
+	}
+	if len(observations) == 0 {
+		return Observation{}, false
+	}
+
+	worst := observations[0]
+	for _, obs := range observations[1:] {
+		if obs.Health.Worse(worst.Health) {
+			worst = obs
+		}
+	}
+	return worst, true
+}
+
+// pingAPI sends one cheap authenticated request. Any response below 500
+// means the API is up, even one rejecting the credential or rate limiting
+// the account; server errors and unreachable APIs mean it is down.
+func (p *Prober) pingAPI(ctx context.Context, settings Settings, provider models.Provider, url, credential string) Observation {
+	ctx, cancel := withTimeout(ctx, settings.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Observation{Health: models.ProviderHealthDown, Reason: fmt.Sprintf("invalid API URL: %v", err)}
+	}
+	authorize(req, provider, credential)
+
+	start := p.clock.Monotonic()
+	resp, err := p.client.Do(req)
+	latency := clock.Since(p.clock, start)
+	if err != nil {
+		return Observation{Health: models.ProviderHealthDown, Reason: fmt.Sprintf("API unreachable: %v", err)}
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return Observation{Health: models.ProviderHealthDown, Reason: fmt.Sprintf("API returned HTTP %d", resp.StatusCode)}
+	case settings.DegradedLatency > 0 && latency > settings.DegradedLatency:
+		return Observation{Health: models.ProviderHealthDegraded, Reason: fmt.Sprintf("API responded in %s", latency.Round(time.Millisecond))}
+	default:
+		return Observation{Health: models.ProviderHealthHealthy}
+	}
+}
+
+// authorize adds credential to req the way provider's API expects it.
+func authorize(req *http.Request, provider models.Provider, credential string) {
+	switch provider {
+	case models.ProviderAnthropic:
+		req.Header.Set("anthropic-version", anthropicVersion)
+		if credential != "" {
+			req.Header.Set("x-api-key", credential)
+		}
+	case models.ProviderGoogle:
+		if credential != "" {
+			req.Header.Set("x-goog-api-key", credential)
+		}
+	default:
+		if credential != "" {
+			req.Header.Set("Authorization", "Bearer "+credential)
+		}
+	}
+}
+
+// statusPageSummary is the part of a Statuspage status.json read.
+type statusPageSummary struct {
+	Status struct {
+		Indicator   string `json:"indicator"`
+		Description string `json:"description"`
+	} `json:"status"`
+}
+
+// readStatusPage reads a status page summary. A minor incident or
+// maintenance degrades the provider; a major or critical one takes it
+// down.
+func (p *Prober) readStatusPage(ctx context.Context, settings Settings, url string) (Observation, error) {
+	ctx, cancel := withTimeout(ctx, settings.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Observation{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Observation{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Observation{}, fmt.Errorf("status page returned HTTP %d", resp.StatusCode)
+	}
+
+	var summary statusPageSummary
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&summary); err != nil {
+		return Observation{}, fmt.Errorf("failed to decode status page: %w", err)
+	}
+
+	reason := "status page: " + strings.TrimSpace(summary.Status.Description)
+	switch strings.ToLower(summary.Status.Indicator) {
+	case "none":
+		return Observation{Health: models.ProviderHealthHealthy}, nil
+	case "minor", "maintenance":
+		return Observation{Health: models.ProviderHealthDegraded, Reason: reason}, nil
+	case "major", "critical":
+		return Observation{Health: models.ProviderHealthDown, Reason: reason}, nil
+	default:
+		return Observation{}, fmt.Errorf("unknown status page indicator %q", summary.Status.Indicator)
+	}
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
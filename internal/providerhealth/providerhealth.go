@@ -0,0 +1,387 @@
+// Package providerhealth tracks whether the AI providers swarm's accounts
+// use are up.
+//
+// A Prober pings each provider's API with the credential of one of its
+// active accounts and, optionally, reads the provider's public status
+// page. A provider's health only changes once several probes in a row
+// agree, so one slow or failed request does not flap it, and every change
+// is recorded as a provider.health_changed event.
+package providerhealth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// Endpoints are the URLs probed for one provider. An empty URL skips that
+// check.
+type Endpoints struct {
+	API        string
+	StatusPage string
+}
+
+// DefaultEndpoints are the URLs probed for the providers swarm knows.
+// Custom providers have none and are not probed.
+var DefaultEndpoints = map[models.Provider]Endpoints{
+	models.ProviderAnthropic: {
+		API:        "https://api.anthropic.com/v1/models",
+		StatusPage: "https://status.anthropic.com/api/v2/status.json",
+	},
+	models.ProviderOpenAI: {
+		API:        "https://api.openai.com/v1/models",
+		StatusPage: "https://status.openai.com/api/v2/status.json",
+	},
+	models.ProviderGoogle: {
+		API: "https://generativelanguage.googleapis.com/v1beta/models",
+	},
+}
+
+// Settings control probing.
+type Settings struct {
+	// Timeout bounds each request.
+	Timeout time.Duration
+	// DegradedLatency is how slow an API response may be before the
+	// provider is degraded (0 = never).
+	DegradedLatency time.Duration
+	// FailureThreshold and RecoveryThreshold are how many probes in a row
+	// must find a provider worse or better off before its health changes.
+	FailureThreshold  int
+	RecoveryThreshold int
+	// StatusPages also reads each provider's status page.
+	StatusPages bool
+	// Endpoints are the URLs probed per provider.
+	Endpoints map[models.Provider]Endpoints
+}
+
+// SettingsFromConfig returns the settings of cfg, with its endpoints laid
+// over DefaultEndpoints.
+func SettingsFromConfig(cfg config.ProviderHealthConfig) Settings {
+	settings := Settings{
+		Timeout:           cfg.Timeout,
+		DegradedLatency:   cfg.DegradedLatency,
+		FailureThreshold:  cfg.FailureThreshold,
+		RecoveryThreshold: cfg.RecoveryThreshold,
+		StatusPages:       cfg.StatusPages,
+		Endpoints:         make(map[models.Provider]Endpoints, len(DefaultEndpoints)),
+	}
+	for provider, endpoints := range DefaultEndpoints {
+		settings.Endpoints[provider] = endpoints
+	}
+	for name, override := range cfg.Endpoints {
+		provider, err := models.ParseProvider(name)
+		if err != nil {
+			continue
+		}
+		endpoints := settings.Endpoints[provider]
+		endpoints.API = overrideURL(endpoints.API, override.API)
+		endpoints.StatusPage = overrideURL(endpoints.StatusPage, override.StatusPage)
+		settings.Endpoints[provider] = endpoints
+	}
+	return settings
+}
+
+func overrideURL(builtin, override string) string {
+	switch override {
+	case "":
+		return builtin
+	case config.ProviderEndpointNone:
+		return ""
+	default:
+		return override
+	}
+}
+
+// Accounts lists accounts. db.AccountRepository implements it.
+type Accounts interface {
+	List(ctx context.Context, provider *models.Provider) ([]*models.Account, error)
+}
+
+// Status is a provider's health as last reported.
+type Status struct {
+	Provider models.Provider       `json:"provider"`
+	Health   models.ProviderHealth `json:"health"`
+	// Since is when the provider's health became Health.
+	Since     time.Time `json:"since,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// Observation is the outcome of one probe of a provider.
+type Observation struct {
+	Health models.ProviderHealth
+	Reason string
+}
+
+// Prober probes providers and keeps their health. It is safe for
+// concurrent use.
+type Prober struct {
+	accounts  Accounts
+	resolvers *account.Resolvers
+	client    *http.Client
+	publisher events.Publisher
+	clock     clock.Clock
+	logger    zerolog.Logger
+
+	mu       sync.Mutex
+	settings Settings
+	trackers map[models.Provider]*tracker
+}
+
+// Option configures a Prober.
+type Option func(*Prober)
+
+// WithClock sets the time source latency and health changes are measured
+// against.
+func WithClock(c clock.Clock) Option {
+	return func(p *Prober) {
+		p.clock = c
+	}
+}
+
+// WithHTTPClient sets the client requests are sent with.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Prober) {
+		p.client = client
+	}
+}
+
+// WithPublisher records health changes through publisher.
+func WithPublisher(publisher events.Publisher) Option {
+	return func(p *Prober) {
+		p.publisher = publisher
+	}
+}
+
+// WithResolvers sets how account credentials are resolved. If not set,
+// account.DefaultResolvers is used.
+func WithResolvers(resolvers *account.Resolvers) Option {
+	return func(p *Prober) {
+		p.resolvers = resolvers
+	}
+}
+
+// WithLogger sets the logger probe failures and health changes go to.
+func WithLogger(logger zerolog.Logger) Option {
+	return func(p *Prober) {
+		p.logger = logger
+	}
+}
+
+// New creates a prober for the providers of accounts.
+func New(accounts Accounts, settings Settings, opts ...Option) *Prober {
+	p := &Prober{
+		accounts: accounts,
+		settings: settings,
+		logger:   zerolog.Nop(),
+		trackers: make(map[models.Provider]*tracker),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.clock = clock.OrReal(p.clock)
+	if p.resolvers == nil {
+		p.resolvers = account.DefaultResolvers()
+	}
+	if p.client == nil {
+		p.client = defaultHTTPClient
+	}
+	return p
+}
+
+// Reconfigure replaces the prober's settings. Health already tracked is
+// kept.
+func (p *Prober) Reconfigure(settings Settings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.settings = settings
+}
+
+func (p *Prober) currentSettings() Settings {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.settings
+}
+
+// Probe checks every provider with an active account and endpoints to
+// probe, applies the results, and returns the health of all providers
+// tracked.
+func (p *Prober) Probe(ctx context.Context) ([]Status, error) {
+	settings := p.currentSettings()
+	accounts, err := p.accounts.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	probed := make(map[models.Provider]bool)
+	for _, acct := range accounts {
+		if !acct.IsActive || probed[acct.Provider] {
+			continue
+		}
+		endpoints, ok := settings.Endpoints[acct.Provider]
+		if !ok || (endpoints.API == "" && (endpoints.StatusPage == "" || !settings.StatusPages)) {
+			continue
+		}
+		probed[acct.Provider] = true
+
+		obs, ok := p.check(ctx, settings, acct, endpoints)
+		if !ok {
+			continue
+		}
+		p.apply(ctx, settings, acct.Provider, obs)
+	}
+	return p.Statuses(), nil
+}
+
+// apply feeds an observation into the provider's hysteresis and records a
+// resulting health change.
+func (p *Prober) apply(ctx context.Context, settings Settings, provider models.Provider, obs Observation) {
+	p.mu.Lock()
+	t, ok := p.trackers[provider]
+	if !ok {
+		t = &tracker{status: Status{Provider: provider, Health: models.ProviderHealthUnknown}}
+		p.trackers[provider] = t
+	}
+	previous, changed := t.observe(obs, p.clock.Now().UTC(), settings.FailureThreshold, settings.RecoveryThreshold)
+	status := t.status
+	p.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	logEvent := p.logger.Info()
+	if status.Health.Worse(models.ProviderHealthHealthy) {
+		logEvent = p.logger.Warn()
+	}
+	logEvent.Str("provider", string(provider)).
+		Str("previous", string(previous)).
+		Str("health", string(status.Health)).
+		Str("reason", status.Reason).
+		Msg("provider health changed")
+
+	if p.publisher == nil {
+		return
+	}
+	payload, _ := json.Marshal(models.ProviderHealthChangedPayload{
+		Provider: provider,
+		Previous: previous,
+		Health:   status.Health,
+		Reason:   status.Reason,
+	})
+	p.publisher.Publish(ctx, &models.Event{
+		Type:       models.EventTypeProviderHealthChanged,
+		Timestamp:  status.Since,
+		EntityType: models.EntityTypeSystem,
+		EntityID:   string(provider),
+		Payload:    payload,
+	})
+}
+
+// Status returns the provider's health, unknown if it was never probed.
+func (p *Prober) Status(provider models.Provider) Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.trackers[provider]; ok {
+		return t.status
+	}
+	return Status{Provider: provider, Health: models.ProviderHealthUnknown}
+}
+
+// Statuses returns the health of every provider probed, by provider.
+func (p *Prober) Statuses() []Status {
+	p.mu.Lock()
+	statuses := make([]Status, 0, len(p.trackers))
+	for _, t := range p.trackers {
+		statuses = append(statuses, t.status)
+	}
+	p.mu.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Provider < statuses[j].Provider })
+	return statuses
+}
+
+// IsDown reports whether the provider's API is down.
+func (p *Prober) IsDown(provider models.Provider) bool {
+	return p.Status(provider).Health == models.ProviderHealthDown
+}
+
+// tracker applies hysteresis to one provider's observations.
+type tracker struct {
+	status Status
+	// streak counts the observations in a row on the same side of
+	// status.Health; worse tells which side.
+	streak int
+	worse  bool
+}
+
+// observe records obs and reports the previous health if it changed the
+// provider's. The first observation is taken as is; after that a change
+// needs failureThreshold observations in a row worse than the current
+// health, or recoveryThreshold better, and moves to the latest.
+func (t *tracker) observe(obs Observation, now time.Time, failureThreshold, recoveryThreshold int) (models.ProviderHealth, bool) {
+	t.status.CheckedAt = now
+	if obs.Health == t.status.Health {
+		t.streak = 0
+		t.status.Reason = obs.Reason
+		return "", false
+	}
+
+	if t.status.Health != models.ProviderHealthUnknown {
+		worse := obs.Health.Worse(t.status.Health)
+		if t.streak == 0 || worse != t.worse {
+			t.streak, t.worse = 0, worse
+		}
+		t.streak++
+		threshold := recoveryThreshold
+		if worse {
+			threshold = failureThreshold
+		}
+		if t.streak < threshold {
+			return "", false
+		}
+	}
+
+	previous := t.status.Health
+	t.status.Health, t.status.Reason, t.status.Since = obs.Health, obs.Reason, now
+	t.streak = 0
+	return previous, true
+}
+
+// Replay returns the last health recorded for each provider in events,
+// which are provider.health_changed events in the order they were
+// recorded.
+func Replay(recorded []*models.Event) []Status {
+	latest := make(map[models.Provider]Status)
+	for _, event := range recorded {
+		if event.Type != models.EventTypeProviderHealthChanged {
+			continue
+		}
+		var payload models.ProviderHealthChangedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			continue
+		}
+		provider := payload.Provider
+		if provider == "" {
+			provider = models.Provider(event.EntityID)
+		}
+		latest[provider] = Status{Provider: provider, Health: payload.Health, Since: event.Timestamp, Reason: payload.Reason}
+	}
+
+	statuses := make([]Status, 0, len(latest))
+	for _, status := range latest {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Provider < statuses[j].Provider })
+	return statuses
+}
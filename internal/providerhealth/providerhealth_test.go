@@ -0,0 +1,257 @@
+package providerhealth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+type staticAccounts []*models.Account
+
+func (a staticAccounts) List(ctx context.Context, provider *models.Provider) ([]*models.Account, error) {
+	return a, nil
+}
+
+// fakeProvider serves a provider API whose status code and latency tests
+// change between probes.
+type fakeProvider struct {
+	status  atomic.Int32
+	delay   atomic.Int64
+	clock   *clock.Fake
+	apiKeys chan string
+}
+
+func newFakeProvider(t *testing.T, clk *clock.Fake) (*fakeProvider, *httptest.Server) {
+	t.Helper()
+	fp := &fakeProvider{clock: clk, apiKeys: make(chan string, 16)}
+	fp.status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case fp.apiKeys <- r.Header.Get("x-api-key"):
+		default:
+		}
+		fp.clock.Advance(time.Duration(fp.delay.Load()))
+		w.WriteHeader(int(fp.status.Load()))
+	}))
+	t.Cleanup(server.Close)
+	return fp, server
+}
+
+func testSettings(api, statusPage string) Settings {
+	return Settings{
+		Timeout:           5 * time.Second,
+		DegradedLatency:   2 * time.Second,
+		FailureThreshold:  2,
+		RecoveryThreshold: 3,
+		StatusPages:       statusPage != "",
+		Endpoints: map[models.Provider]Endpoints{
+			models.ProviderAnthropic: {API: api, StatusPage: statusPage},
+		},
+	}
+}
+
+func anthropicAccounts() staticAccounts {
+	return staticAccounts{
+		{ID: "acct-1", Provider: models.ProviderAnthropic, CredentialRef: "literal:sk-test", IsActive: true},
+	}
+}
+
+func probe(t *testing.T, p *Prober) models.ProviderHealth {
+	t.Helper()
+	if _, err := p.Probe(context.Background()); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	return p.Status(models.ProviderAnthropic).Health
+}
+
+func TestProberHysteresis(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	fp, server := newFakeProvider(t, clk)
+	publisher := events.NewInMemoryPublisher()
+	var changes []models.ProviderHealthChangedPayload
+	if err := publisher.Subscribe("health", events.Filter{EventTypes: []models.EventType{models.EventTypeProviderHealthChanged}}, func(event *models.Event) {
+		var payload models.ProviderHealthChangedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		changes = append(changes, payload)
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	p := New(anthropicAccounts(), testSettings(server.URL, ""), WithClock(clk), WithHTTPClient(server.Client()), WithPublisher(publisher))
+
+	if got := probe(t, p); got != models.ProviderHealthHealthy {
+		t.Fatalf("first probe: expected healthy, got %s", got)
+	}
+	if key := <-fp.apiKeys; key != "sk-test" {
+		t.Fatalf("expected the account's credential, got %q", key)
+	}
+
+	fp.status.Store(http.StatusServiceUnavailable)
+	if got := probe(t, p); got != models.ProviderHealthHealthy {
+		t.Fatalf("one failure should not take the provider down, got %s", got)
+	}
+	if got := probe(t, p); got != models.ProviderHealthDown {
+		t.Fatalf("expected down after two failures, got %s", got)
+	}
+	if !p.IsDown(models.ProviderAnthropic) {
+		t.Fatal("expected IsDown")
+	}
+
+	fp.status.Store(http.StatusUnauthorized)
+	for i := 0; i < 2; i++ {
+		if got := probe(t, p); got != models.ProviderHealthDown {
+			t.Fatalf("probe %d: recovery needs three answers, got %s", i+1, got)
+		}
+	}
+	if got := probe(t, p); got != models.ProviderHealthHealthy {
+		t.Fatalf("expected a rejected credential to count as healthy, got %s", got)
+	}
+
+	want := []models.ProviderHealthChangedPayload{
+		{Provider: models.ProviderAnthropic, Previous: models.ProviderHealthUnknown, Health: models.ProviderHealthHealthy},
+		{Provider: models.ProviderAnthropic, Previous: models.ProviderHealthHealthy, Health: models.ProviderHealthDown, Reason: "API returned HTTP 503"},
+		{Provider: models.ProviderAnthropic, Previous: models.ProviderHealthDown, Health: models.ProviderHealthHealthy},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d health changes, got %+v", len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("change %d: expected %+v, got %+v", i, want[i], changes[i])
+		}
+	}
+}
+
+func TestProberStreakResetsOnRecovery(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	fp, server := newFakeProvider(t, clk)
+	p := New(anthropicAccounts(), testSettings(server.URL, ""), WithClock(clk), WithHTTPClient(server.Client()))
+
+	probe(t, p)
+	for i := 0; i < 3; i++ {
+		fp.status.Store(http.StatusBadGateway)
+		probe(t, p)
+		fp.status.Store(http.StatusOK)
+		probe(t, p)
+	}
+	if got := p.Status(models.ProviderAnthropic).Health; got != models.ProviderHealthHealthy {
+		t.Fatalf("alternating failures should not take the provider down, got %s", got)
+	}
+}
+
+func TestProberDegradedLatency(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	fp, server := newFakeProvider(t, clk)
+	fp.delay.Store(int64(3 * time.Second))
+	p := New(anthropicAccounts(), testSettings(server.URL, ""), WithClock(clk), WithHTTPClient(server.Client()))
+
+	if got := probe(t, p); got != models.ProviderHealthDegraded {
+		t.Fatalf("expected degraded, got %s", got)
+	}
+	if reason := p.Status(models.ProviderAnthropic).Reason; reason != "API responded in 3s" {
+		t.Fatalf("unexpected reason %q", reason)
+	}
+}
+
+func TestProberStatusPage(t *testing.T) {
+	tests := []struct {
+		indicator string
+		want      models.ProviderHealth
+	}{
+		{indicator: "none", want: models.ProviderHealthHealthy},
+		{indicator: "minor", want: models.ProviderHealthDegraded},
+		{indicator: "major", want: models.ProviderHealthDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.indicator, func(t *testing.T) {
+			clk := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+			_, api := newFakeProvider(t, clk)
+			page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"status":{"indicator":"` + tt.indicator + `","description":"Elevated errors"}}`))
+			}))
+			t.Cleanup(page.Close)
+
+			p := New(anthropicAccounts(), testSettings(api.URL, page.URL), WithClock(clk))
+			if got := probe(t, p); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProberSkipsInactiveAndUnprobedProviders(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	_, server := newFakeProvider(t, clk)
+	accounts := staticAccounts{
+		{ID: "acct-1", Provider: models.ProviderAnthropic, CredentialRef: "literal:sk-test"},
+		{ID: "acct-2", Provider: models.Provider("acme"), CredentialRef: "literal:sk-acme", IsActive: true},
+	}
+	p := New(accounts, testSettings(server.URL, ""), WithClock(clk), WithHTTPClient(server.Client()))
+
+	statuses, err := p.Probe(context.Background())
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if len(statuses) != 0 {
+		t.Fatalf("expected no providers probed, got %+v", statuses)
+	}
+}
+
+func TestSettingsFromConfig(t *testing.T) {
+	cfg := config.DefaultConfig().Daemon.ProviderHealth
+	cfg.Endpoints = map[string]config.ProviderEndpointConfig{
+		"anthropic": {StatusPage: config.ProviderEndpointNone},
+		"openai":    {API: "https://proxy.example.com/v1/models"},
+	}
+	settings := SettingsFromConfig(cfg)
+
+	if got := settings.Endpoints[models.ProviderAnthropic]; got.StatusPage != "" || got.API != DefaultEndpoints[models.ProviderAnthropic].API {
+		t.Fatalf("unexpected anthropic endpoints %+v", got)
+	}
+	if got := settings.Endpoints[models.ProviderOpenAI]; got.API != "https://proxy.example.com/v1/models" || got.StatusPage != DefaultEndpoints[models.ProviderOpenAI].StatusPage {
+		t.Fatalf("unexpected openai endpoints %+v", got)
+	}
+	if DefaultEndpoints[models.ProviderAnthropic].StatusPage == "" {
+		t.Fatal("overrides must not change the defaults")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	event := func(offset time.Duration, payload models.ProviderHealthChangedPayload) *models.Event {
+		data, _ := json.Marshal(payload)
+		return &models.Event{
+			Type:       models.EventTypeProviderHealthChanged,
+			Timestamp:  at.Add(offset),
+			EntityType: models.EntityTypeSystem,
+			EntityID:   string(payload.Provider),
+			Payload:    data,
+		}
+	}
+	statuses := Replay([]*models.Event{
+		event(0, models.ProviderHealthChangedPayload{Provider: models.ProviderOpenAI, Health: models.ProviderHealthHealthy}),
+		event(time.Minute, models.ProviderHealthChangedPayload{Provider: models.ProviderAnthropic, Health: models.ProviderHealthDown, Reason: "API returned HTTP 503"}),
+		event(2*time.Minute, models.ProviderHealthChangedPayload{Provider: models.ProviderOpenAI, Health: models.ProviderHealthDegraded}),
+	})
+
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 providers, got %+v", statuses)
+	}
+	if statuses[0].Provider != models.ProviderAnthropic || statuses[0].Health != models.ProviderHealthDown || !statuses[0].Since.Equal(at.Add(time.Minute)) {
+		t.Fatalf("unexpected anthropic status %+v", statuses[0])
+	}
+	if statuses[1].Provider != models.ProviderOpenAI || statuses[1].Health != models.ProviderHealthDegraded {
+		t.Fatalf("unexpected openai status %+v", statuses[1])
+	}
+}
@@ -0,0 +1,38 @@
+package queue
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Audited operation names.
+const (
+	AuditEnqueue = "enqueue"
+	AuditInsert  = "insert_queue_item"
+	AuditRemove  = "remove_queue_item"
+	AuditReorder = "reorder_queue"
+	AuditClear   = "clear_queue"
+)
+
+// WithAuditRecorder records the service's mutating operations.
+func WithAuditRecorder(recorder *audit.Recorder) ServiceOption {
+	return func(s *Service) {
+		s.auditor = recorder
+	}
+}
+
+// itemParams describes queued items without their payloads, which are
+// recorded by the events of their dispatch.
+func itemParams(items []*models.QueueItem, kv ...string) map[string]string {
+	types := make([]string, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			types = append(types, string(item.Type))
+		}
+	}
+	kv = append(kv, "items", strconv.Itoa(len(items)), "types", strings.Join(types, ","))
+	return audit.Params(kv...)
+}
@@ -0,0 +1,153 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// DefaultDrainPollInterval is how often a DrainWaiter checks on agents.
+const DefaultDrainPollInterval = 2 * time.Second
+
+// ErrDrainTimeout is returned when agents are still working off their
+// queues once a drain wait times out.
+var ErrDrainTimeout = errors.New("timed out waiting for queues to drain")
+
+// DrainProgress is what a draining agent has left to do.
+type DrainProgress struct {
+	// Pending is the number of items still waiting in its queue.
+	Pending int
+	// State is the agent's current state.
+	State models.AgentState
+}
+
+// Done reports whether the agent has finished draining: its queue is empty
+// and it is idle, or stopped or hibernated with nothing left to run.
+func (p DrainProgress) Done() bool {
+	switch p.State {
+	case models.AgentStateIdle, models.AgentStateStopped, models.AgentStateHibernated:
+		return p.Pending == 0
+	default:
+		return false
+	}
+}
+
+// DrainProbe reports the drain progress of an agent.
+type DrainProbe func(ctx context.Context, agentID string) (DrainProgress, error)
+
+// RepositoryDrainProbe reads drain progress from the database.
+func RepositoryDrainProbe(queueRepo *db.QueueRepository, agentRepo *db.AgentRepository) DrainProbe {
+	return func(ctx context.Context, agentID string) (DrainProgress, error) {
+		agent, err := agentRepo.Get(ctx, agentID)
+		if err != nil {
+			return DrainProgress{}, err
+		}
+		pending, err := queueRepo.Count(ctx, agentID)
+		if err != nil {
+			return DrainProgress{}, err
+		}
+		return DrainProgress{Pending: pending, State: agent.State}, nil
+	}
+}
+
+// DrainWaiter waits for draining agents to work off their queues.
+type DrainWaiter struct {
+	probe     DrainProbe
+	clock     clock.Clock
+	interval  time.Duration
+	onDrained func(agentID string)
+}
+
+// DrainWaiterOption configures a DrainWaiter.
+type DrainWaiterOption func(*DrainWaiter)
+
+// WithDrainClock sets the time source for polling and the timeout.
+func WithDrainClock(c clock.Clock) DrainWaiterOption {
+	return func(w *DrainWaiter) {
+		w.clock = c
+	}
+}
+
+// WithDrainPollInterval sets how often agents are checked.
+func WithDrainPollInterval(d time.Duration) DrainWaiterOption {
+	return func(w *DrainWaiter) {
+		w.interval = d
+	}
+}
+
+// WithDrainedFunc calls fn once for each agent as it finishes draining.
+func WithDrainedFunc(fn func(agentID string)) DrainWaiterOption {
+	return func(w *DrainWaiter) {
+		w.onDrained = fn
+	}
+}
+
+// NewDrainWaiter creates a waiter reading progress through probe.
+func NewDrainWaiter(probe DrainProbe, opts ...DrainWaiterOption) *DrainWaiter {
+	w := &DrainWaiter{
+		probe:    probe,
+		interval: DefaultDrainPollInterval,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	w.clock = clock.OrReal(w.clock)
+	if w.interval <= 0 {
+		w.interval = DefaultDrainPollInterval
+	}
+	return w
+}
+
+// Wait blocks until every agent in agentIDs has finished draining. A
+// positive timeout ends the wait with ErrDrainTimeout; an agent that
+// cannot be checked ends it with that error. Agents that are gone count as
+// drained.
+func (w *DrainWaiter) Wait(ctx context.Context, agentIDs []string, timeout time.Duration) error {
+	remaining := make(map[string]bool, len(agentIDs))
+	for _, id := range agentIDs {
+		remaining[id] = true
+	}
+
+	var deadline clock.Deadline
+	if timeout > 0 {
+		deadline = clock.NewDeadline(w.clock, timeout)
+	}
+	ticker := w.clock.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, id := range agentIDs {
+			if !remaining[id] {
+				continue
+			}
+			progress, err := w.probe(ctx, id)
+			if err != nil && !errors.Is(err, db.ErrAgentNotFound) {
+				return fmt.Errorf("failed to check agent %s: %w", id, err)
+			}
+			if err == nil && !progress.Done() {
+				continue
+			}
+			delete(remaining, id)
+			if w.onDrained != nil {
+				w.onDrained(id)
+			}
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		if !deadline.IsZero() && deadline.Expired(w.clock) {
+			return fmt.Errorf("%w: %d of %d agents still busy", ErrDrainTimeout, len(remaining), len(agentIDs))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
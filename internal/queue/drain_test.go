@@ -0,0 +1,113 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// scriptedProbe reports the next entry of progress on each poll of agent
+// "a", holding on the last one, and signals polled after every poll.
+type scriptedProbe struct {
+	progress []DrainProgress
+	polls    int
+	polled   chan struct{}
+}
+
+func newScriptedProbe(progress ...DrainProgress) *scriptedProbe {
+	return &scriptedProbe{progress: progress, polled: make(chan struct{}, 16)}
+}
+
+func (p *scriptedProbe) probe(ctx context.Context, agentID string) (DrainProgress, error) {
+	switch agentID {
+	case "a":
+	case "gone":
+		return DrainProgress{}, db.ErrAgentNotFound
+	default:
+		return DrainProgress{State: models.AgentStateIdle}, nil
+	}
+	i := p.polls
+	if i >= len(p.progress) {
+		i = len(p.progress) - 1
+	}
+	p.polls++
+	p.polled <- struct{}{}
+	return p.progress[i], nil
+}
+
+// runDrainWait runs Wait in the background and advances the clock one poll
+// interval after each poll until it returns. The ticker is created before
+// the first poll and buffers its tick, so no tick is lost.
+func runDrainWait(t *testing.T, fake *clock.Fake, probe *scriptedProbe, waiter *DrainWaiter, agentIDs []string, timeout time.Duration) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- waiter.Wait(context.Background(), agentIDs, timeout) }()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-probe.polled:
+			fake.Advance(time.Second)
+		case <-time.After(5 * time.Second):
+			t.Fatal("drain wait did not finish")
+		}
+	}
+}
+
+func TestDrainWaiter_WaitsForQueueToEmpty(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	probe := newScriptedProbe(
+		DrainProgress{Pending: 2, State: models.AgentStateWorking},
+		DrainProgress{Pending: 1, State: models.AgentStateWorking},
+		DrainProgress{Pending: 0, State: models.AgentStateWorking},
+		DrainProgress{Pending: 0, State: models.AgentStateIdle},
+	)
+	var drained []string
+	waiter := NewDrainWaiter(probe.probe,
+		WithDrainClock(fake),
+		WithDrainPollInterval(time.Second),
+		WithDrainedFunc(func(agentID string) { drained = append(drained, agentID) }),
+	)
+
+	if err := runDrainWait(t, fake, probe, waiter, []string{"a", "b", "gone"}, time.Minute); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if probe.polls != 4 {
+		t.Fatalf("expected 4 polls until idle with an empty queue, got %d", probe.polls)
+	}
+	if len(drained) != 3 || drained[0] != "b" || drained[1] != "gone" || drained[2] != "a" {
+		t.Fatalf("expected each agent reported drained once, got %v", drained)
+	}
+}
+
+func TestDrainWaiter_TimesOut(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	probe := newScriptedProbe(DrainProgress{Pending: 3, State: models.AgentStateWorking})
+	waiter := NewDrainWaiter(probe.probe, WithDrainClock(fake), WithDrainPollInterval(time.Second))
+
+	err := runDrainWait(t, fake, probe, waiter, []string{"a", "b"}, 5*time.Second)
+	if !errors.Is(err, ErrDrainTimeout) {
+		t.Fatalf("expected ErrDrainTimeout, got %v", err)
+	}
+	if err.Error() != ErrDrainTimeout.Error()+": 1 of 2 agents still busy" {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if probe.polls != 6 {
+		t.Fatalf("expected polls until the timeout passed, got %d", probe.polls)
+	}
+}
+
+func TestDrainWaiter_ProbeError(t *testing.T) {
+	waiter := NewDrainWaiter(func(ctx context.Context, agentID string) (DrainProgress, error) {
+		return DrainProgress{}, errors.New("database is locked")
+	})
+	if err := waiter.Wait(context.Background(), []string{"a"}, 0); err == nil {
+		t.Fatal("expected the probe error to end the wait")
+	}
+}
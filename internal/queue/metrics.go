@@ -0,0 +1,326 @@
+package queue
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// Queue metrics defaults.
+const (
+	// DefaultWaitReservoirSize is how many recent waits each agent keeps
+	// for its percentiles.
+	DefaultWaitReservoirSize = 256
+
+	// DefaultMetricsFlushInterval is how often changed metrics are saved.
+	DefaultMetricsFlushInterval = time.Minute
+
+	// ThroughputWindow is the window dispatches per hour are counted over.
+	ThroughputWindow = time.Hour
+
+	// metricsFlushTimeout bounds the final save when Run stops.
+	metricsFlushTimeout = 5 * time.Second
+)
+
+// MetricsStore persists queue metrics. db.QueueMetricsRepository
+// implements it.
+type MetricsStore interface {
+	List(ctx context.Context) ([]*models.AgentQueueMetrics, error)
+	Upsert(ctx context.Context, metrics []*models.AgentQueueMetrics) error
+}
+
+// Metrics keeps rolling per-agent queue wait and throughput aggregates in
+// memory. Each agent holds a fixed-size reservoir of its most recent waits,
+// from which p50/p95 are computed, and the dispatch times within the last
+// hour. It is safe for concurrent use.
+type Metrics struct {
+	store         MetricsStore
+	size          int
+	flushInterval time.Duration
+	clock         clock.Clock
+	logger        zerolog.Logger
+
+	mu     sync.Mutex
+	agents map[string]*agentMetrics
+	dirty  map[string]bool
+}
+
+type agentMetrics struct {
+	dispatched int64
+	waits      []int64 // ring buffer of waits in ms
+	next       int     // next slot to overwrite once waits is full
+	recent     []time.Time
+}
+
+// MetricsOption configures Metrics.
+type MetricsOption func(*Metrics)
+
+// WithMetricsStore persists the metrics through store while Run is active.
+func WithMetricsStore(store MetricsStore) MetricsOption {
+	return func(m *Metrics) {
+		m.store = store
+	}
+}
+
+// WithReservoirSize sets how many recent waits are kept per agent.
+func WithReservoirSize(n int) MetricsOption {
+	return func(m *Metrics) {
+		m.size = n
+	}
+}
+
+// WithMetricsFlushInterval sets how often changed metrics are saved.
+func WithMetricsFlushInterval(d time.Duration) MetricsOption {
+	return func(m *Metrics) {
+		m.flushInterval = d
+	}
+}
+
+// WithMetricsClock sets the time source for the throughput window.
+func WithMetricsClock(c clock.Clock) MetricsOption {
+	return func(m *Metrics) {
+		m.clock = c
+	}
+}
+
+// NewMetrics creates an empty metrics aggregate.
+func NewMetrics(opts ...MetricsOption) *Metrics {
+	m := &Metrics{
+		size:          DefaultWaitReservoirSize,
+		flushInterval: DefaultMetricsFlushInterval,
+		logger:        logging.Component("queue-metrics"),
+		agents:        make(map[string]*agentMetrics),
+		dirty:         make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.size <= 0 {
+		m.size = DefaultWaitReservoirSize
+	}
+	if m.flushInterval <= 0 {
+		m.flushInterval = DefaultMetricsFlushInterval
+	}
+	m.clock = clock.OrReal(m.clock)
+	return m
+}
+
+// Observe records a dispatch to agentID after its item waited wait.
+func (m *Metrics) Observe(agentID string, wait time.Duration) {
+	if wait < 0 {
+		wait = 0
+	}
+	now := m.clock.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a := m.agent(agentID)
+	a.dispatched++
+	a.addWait(wait.Milliseconds(), m.size)
+	a.recent = append(pruneBefore(a.recent, now.Add(-ThroughputWindow)), now)
+	m.dirty[agentID] = true
+}
+
+// Stats returns the aggregates for one agent.
+func (m *Metrics) Stats(agentID string) (models.QueueWaitStats, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.agents[agentID]
+	if !ok {
+		return models.QueueWaitStats{AgentID: agentID}, false
+	}
+	return Summarize(a.record(agentID), m.clock.Now()), true
+}
+
+// All returns the aggregates for every agent, ordered by agent ID.
+func (m *Metrics) All() []models.QueueWaitStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	stats := make([]models.QueueWaitStats, 0, len(m.agents))
+	for id, a := range m.agents {
+		stats = append(stats, Summarize(a.record(id), now))
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].AgentID < stats[j].AgentID })
+	return stats
+}
+
+// Load merges persisted metrics in. Waits already observed in memory are
+// kept as the most recent.
+func (m *Metrics) Load(records []*models.AgentQueueMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := m.clock.Now().Add(-ThroughputWindow)
+	for _, r := range records {
+		if r == nil || r.AgentID == "" {
+			continue
+		}
+		live := m.agents[r.AgentID]
+		a := &agentMetrics{dispatched: r.Dispatched}
+		for _, wait := range r.WaitSamplesMs {
+			a.addWait(wait, m.size)
+		}
+		a.recent = pruneBefore(append([]time.Time(nil), r.RecentDispatches...), cutoff)
+		if live != nil {
+			a.dispatched += live.dispatched
+			for _, wait := range live.ordered() {
+				a.addWait(wait, m.size)
+			}
+			a.recent = append(a.recent, live.recent...)
+		}
+		m.agents[r.AgentID] = a
+	}
+}
+
+// Run loads the metrics from the store, then saves the agents that changed
+// on every flush interval until ctx is cancelled, and once more on the way
+// out. Without a store it returns immediately.
+func (m *Metrics) Run(ctx context.Context) {
+	if m.store == nil {
+		return
+	}
+	if records, err := m.store.List(ctx); err != nil {
+		m.logger.Warn().Err(err).Msg("failed to load queue metrics")
+	} else {
+		m.Load(records)
+	}
+
+	ticker := m.clock.NewTicker(m.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), metricsFlushTimeout)
+			m.flushAndLog(flushCtx)
+			cancel()
+			return
+		case <-ticker.C():
+			m.flushAndLog(ctx)
+		}
+	}
+}
+
+// Flush saves the agents whose metrics changed since the last flush. On
+// failure they stay marked so the next flush retries them.
+func (m *Metrics) Flush(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	now := m.clock.Now().UTC()
+	records := make([]*models.AgentQueueMetrics, 0, len(m.dirty))
+	for id := range m.dirty {
+		a, ok := m.agents[id]
+		if !ok {
+			continue
+		}
+		a.recent = pruneBefore(a.recent, now.Add(-ThroughputWindow))
+		record := a.record(id)
+		record.UpdatedAt = now
+		records = append(records, record)
+	}
+	m.dirty = make(map[string]bool)
+	m.mu.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+	if err := m.store.Upsert(ctx, records); err != nil {
+		m.mu.Lock()
+		for _, r := range records {
+			m.dirty[r.AgentID] = true
+		}
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (m *Metrics) flushAndLog(ctx context.Context) {
+	if err := m.Flush(ctx); err != nil {
+		m.logger.Warn().Err(err).Msg("failed to save queue metrics")
+	}
+}
+
+func (m *Metrics) agent(agentID string) *agentMetrics {
+	a, ok := m.agents[agentID]
+	if !ok {
+		a = &agentMetrics{}
+		m.agents[agentID] = a
+	}
+	return a
+}
+
+func (a *agentMetrics) addWait(ms int64, size int) {
+	if len(a.waits) < size {
+		a.waits = append(a.waits, ms)
+		return
+	}
+	a.waits[a.next] = ms
+	a.next = (a.next + 1) % size
+}
+
+// ordered returns the waits oldest first.
+func (a *agentMetrics) ordered() []int64 {
+	out := make([]int64, 0, len(a.waits))
+	out = append(out, a.waits[a.next:]...)
+	return append(out, a.waits[:a.next]...)
+}
+
+func (a *agentMetrics) record(agentID string) *models.AgentQueueMetrics {
+	return &models.AgentQueueMetrics{
+		AgentID:          agentID,
+		Dispatched:       a.dispatched,
+		WaitSamplesMs:    a.ordered(),
+		RecentDispatches: append([]time.Time(nil), a.recent...),
+	}
+}
+
+// Summarize computes the wait percentiles and hourly throughput of a
+// metrics record as of now.
+func Summarize(record *models.AgentQueueMetrics, now time.Time) models.QueueWaitStats {
+	stats := models.QueueWaitStats{AgentID: record.AgentID, Dispatched: record.Dispatched}
+
+	waits := append([]int64(nil), record.WaitSamplesMs...)
+	sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+	stats.Samples = len(waits)
+	stats.WaitP50Ms = percentile(waits, 50)
+	stats.WaitP95Ms = percentile(waits, 95)
+
+	cutoff := now.Add(-ThroughputWindow)
+	for _, at := range record.RecentDispatches {
+		if at.After(cutoff) {
+			stats.PerHour++
+		}
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of sorted values.
+func percentile(sorted []int64, p int) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// pruneBefore drops the leading times at or before cutoff; times are
+// appended in order, so the rest are newer.
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,153 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestMetricsAggregates(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	m := NewMetrics(WithMetricsClock(fake), WithReservoirSize(20))
+
+	// Waits of 1s..20s, one dispatch every 5 minutes.
+	for i := 1; i <= 20; i++ {
+		m.Observe("a", time.Duration(i)*time.Second)
+		fake.Advance(5 * time.Minute)
+	}
+
+	stats, ok := m.Stats("a")
+	if !ok {
+		t.Fatal("expected stats for agent a")
+	}
+	if stats.Dispatched != 20 || stats.Samples != 20 {
+		t.Fatalf("unexpected counts %+v", stats)
+	}
+	if stats.WaitP50Ms != 10_000 || stats.WaitP95Ms != 19_000 {
+		t.Fatalf("expected p50 10s and p95 19s, got %+v", stats)
+	}
+	// Dispatches at minutes 45..95 fall within the hour before minute 100.
+	if stats.PerHour != 11 {
+		t.Fatalf("expected 11 dispatches in the last hour, got %d", stats.PerHour)
+	}
+
+	// Overflowing the reservoir keeps only the most recent waits.
+	for i := 0; i < 20; i++ {
+		m.Observe("a", 100*time.Millisecond)
+	}
+	stats, _ = m.Stats("a")
+	if stats.Dispatched != 40 || stats.Samples != 20 || stats.WaitP95Ms != 100 {
+		t.Fatalf("expected the reservoir to hold only the new waits, got %+v", stats)
+	}
+
+	if _, ok := m.Stats("unknown"); ok {
+		t.Fatal("expected no stats for an agent that was never dispatched to")
+	}
+
+	m.Observe("b", -time.Second)
+	all := m.All()
+	if len(all) != 2 || all[0].AgentID != "a" || all[1].AgentID != "b" || all[1].WaitP50Ms != 0 {
+		t.Fatalf("unexpected All() %+v", all)
+	}
+}
+
+func TestMetricsLoadMergesLiveWaits(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	m := NewMetrics(WithMetricsClock(fake), WithReservoirSize(4))
+
+	m.Observe("a", 9*time.Second)
+	m.Load([]*models.AgentQueueMetrics{{
+		AgentID:          "a",
+		Dispatched:       10,
+		WaitSamplesMs:    []int64{1000, 2000, 3000, 4000},
+		RecentDispatches: []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Minute)},
+	}})
+
+	stats, _ := m.Stats("a")
+	if stats.Dispatched != 11 || stats.PerHour != 2 {
+		t.Fatalf("unexpected merged stats %+v", stats)
+	}
+	// The oldest saved wait makes room for the live one.
+	if stats.Samples != 4 || stats.WaitP50Ms != 3000 || stats.WaitP95Ms != 9000 {
+		t.Fatalf("unexpected merged waits %+v", stats)
+	}
+}
+
+// memoryMetricsStore is a MetricsStore backed by a map.
+type memoryMetricsStore struct {
+	mu      sync.Mutex
+	records map[string]*models.AgentQueueMetrics
+	fail    bool
+	upserts int
+}
+
+func (s *memoryMetricsStore) List(context.Context) ([]*models.AgentQueueMetrics, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*models.AgentQueueMetrics, 0, len(s.records))
+	for _, r := range s.records {
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func (s *memoryMetricsStore) Upsert(_ context.Context, metrics []*models.AgentQueueMetrics) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("disk full")
+	}
+	s.upserts++
+	for _, r := range metrics {
+		s.records[r.AgentID] = r
+	}
+	return nil
+}
+
+func TestMetricsFlushRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	store := &memoryMetricsStore{records: make(map[string]*models.AgentQueueMetrics), fail: true}
+	m := NewMetrics(WithMetricsClock(fake), WithMetricsStore(store))
+
+	m.Observe("a", 2*time.Second)
+	m.Observe("a", 4*time.Second)
+	if err := m.Flush(ctx); err == nil {
+		t.Fatal("expected the store error from Flush")
+	}
+
+	store.fail = false
+	if err := m.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if err := m.Flush(ctx); err != nil || store.upserts != 1 {
+		t.Fatalf("expected an unchanged flush to skip the store, got %d upserts (%v)", store.upserts, err)
+	}
+
+	restored := NewMetrics(WithMetricsClock(fake), WithMetricsStore(store))
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		restored.Run(runCtx)
+		close(done)
+	}()
+	fake.BlockUntil(1)
+
+	stats, ok := restored.Stats("a")
+	if !ok || stats.Dispatched != 2 || stats.WaitP50Ms != 2000 || stats.WaitP95Ms != 4000 || stats.PerHour != 2 {
+		t.Fatalf("unexpected restored stats %+v", stats)
+	}
+
+	restored.Observe("a", time.Second)
+	cancel()
+	<-done
+	if got := store.records["a"].Dispatched; got != 3 {
+		t.Fatalf("expected the final flush to save 3 dispatches, got %d", got)
+	}
+}
@@ -5,10 +5,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/opencode-ai/swarm/internal/audit"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/logging"
 	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/store"
 	"github.com/rs/zerolog"
 )
 
@@ -16,47 +21,93 @@ import (
 var (
 	ErrQueueItemNotFound = errors.New("queue item not found")
 	ErrQueueEmpty        = errors.New("queue is empty")
+	ErrAgentDraining     = errors.New("agent is draining")
 )
 
 // QueueService defines the queue operations for agents.
 type QueueService interface {
 	Enqueue(ctx context.Context, agentID string, items ...*models.QueueItem) error
-	Dequeue(ctx context.Context, agentID string) (*models.QueueItem, error)
+	EnqueueBatch(ctx context.Context, agentID string, items ...*models.QueueItem) error
+	EnqueueBatchAtHead(ctx context.Context, agentID string, items ...*models.QueueItem) error
+	Dequeue(ctx context.Context, agentID, dispatcherID string) (*models.QueueItem, error)
 	Peek(ctx context.Context, agentID string) (*models.QueueItem, error)
 	List(ctx context.Context, agentID string) ([]*models.QueueItem, error)
+	ListInFlight(ctx context.Context, cutoff time.Time) ([]*models.QueueItem, error)
 	Reorder(ctx context.Context, agentID string, ordering []string) error
 	Clear(ctx context.Context, agentID string) (int, error)
 	InsertAt(ctx context.Context, agentID string, position int, item *models.QueueItem) error
 	Remove(ctx context.Context, itemID string) error
 	UpdateStatus(ctx context.Context, itemID string, status models.QueueItemStatus, errorMsg string) error
+	ReleaseInFlight(ctx context.Context, itemID string, status models.QueueItemStatus, attempts int, errorMsg string) (bool, error)
 	UpdateAttempts(ctx context.Context, itemID string, attempts int) error
 }
 
 // Service implements QueueService using a QueueRepository.
 type Service struct {
-	repo   *db.QueueRepository
-	logger zerolog.Logger
+	repo    store.QueueStore
+	logger  zerolog.Logger
+	auditor *audit.Recorder
 }
 
+// ServiceOption configures a QueueService.
+type ServiceOption func(*Service)
+
 // NewService creates a new QueueService.
-func NewService(repo *db.QueueRepository) *Service {
-	return &Service{
+func NewService(repo store.QueueStore, opts ...ServiceOption) *Service {
+	s := &Service{
 		repo:   repo,
 		logger: logging.Component("queue"),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Enqueue adds items to the agent queue.
-func (s *Service) Enqueue(ctx context.Context, agentID string, items ...*models.QueueItem) error {
+// Enqueue adds items to the agent queue. A draining agent rejects them with
+// ErrAgentDraining.
+func (s *Service) Enqueue(ctx context.Context, agentID string, items ...*models.QueueItem) (err error) {
+	defer func() { s.auditor.Record(ctx, AuditEnqueue, models.EntityTypeAgent, agentID, itemParams(items), err) }()
+	if err := s.checkDraining(ctx, agentID, items...); err != nil {
+		return err
+	}
 	if err := s.repo.Enqueue(ctx, agentID, items...); err != nil {
 		return fmt.Errorf("failed to enqueue items: %w", err)
 	}
 	return nil
 }
 
-// Dequeue removes and returns the next pending item.
-func (s *Service) Dequeue(ctx context.Context, agentID string) (*models.QueueItem, error) {
-	item, err := s.repo.Dequeue(ctx, agentID)
+// EnqueueBatch adds items to the end of the agent queue atomically, at
+// contiguous positions: either every item is queued or none is, and the
+// scheduler never dispatches from a half-written batch. A draining agent
+// rejects them with ErrAgentDraining.
+func (s *Service) EnqueueBatch(ctx context.Context, agentID string, items ...*models.QueueItem) error {
+	return s.enqueueBatch(ctx, agentID, false, items)
+}
+
+// EnqueueBatchAtHead is EnqueueBatch, but puts the batch ahead of the
+// agent's pending items, right after the item in flight.
+func (s *Service) EnqueueBatchAtHead(ctx context.Context, agentID string, items ...*models.QueueItem) error {
+	return s.enqueueBatch(ctx, agentID, true, items)
+}
+
+func (s *Service) enqueueBatch(ctx context.Context, agentID string, atHead bool, items []*models.QueueItem) (err error) {
+	defer func() {
+		s.auditor.Record(ctx, AuditEnqueue, models.EntityTypeAgent, agentID, itemParams(items, "batch", "true", "at_head", strconv.FormatBool(atHead)), err)
+	}()
+	if err := s.checkDraining(ctx, agentID, items...); err != nil {
+		return err
+	}
+	if err := s.repo.EnqueueBatch(ctx, agentID, atHead, items...); err != nil {
+		return fmt.Errorf("failed to enqueue batch: %w", err)
+	}
+	return nil
+}
+
+// Dequeue claims the next pending item for dispatcherID and returns it in
+// flight.
+func (s *Service) Dequeue(ctx context.Context, agentID, dispatcherID string) (*models.QueueItem, error) {
+	item, err := s.repo.Dequeue(ctx, agentID, dispatcherID)
 	if err != nil {
 		if errors.Is(err, db.ErrQueueEmpty) {
 			return nil, ErrQueueEmpty
@@ -88,7 +139,10 @@ func (s *Service) List(ctx context.Context, agentID string) ([]*models.QueueItem
 }
 
 // Reorder updates queue ordering based on the provided item IDs.
-func (s *Service) Reorder(ctx context.Context, agentID string, ordering []string) error {
+func (s *Service) Reorder(ctx context.Context, agentID string, ordering []string) (err error) {
+	defer func() {
+		s.auditor.Record(ctx, AuditReorder, models.EntityTypeAgent, agentID, audit.Params("ordering", strings.Join(ordering, ",")), err)
+	}()
 	if err := s.repo.Reorder(ctx, agentID, ordering); err != nil {
 		return fmt.Errorf("failed to reorder queue: %w", err)
 	}
@@ -99,13 +153,47 @@ func (s *Service) Reorder(ctx context.Context, agentID string, ordering []string
 func (s *Service) Clear(ctx context.Context, agentID string) (int, error) {
 	removed, err := s.repo.Clear(ctx, agentID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to clear queue: %w", err)
+		err = fmt.Errorf("failed to clear queue: %w", err)
+	}
+	s.auditor.Record(ctx, AuditClear, models.EntityTypeAgent, agentID, audit.Params("removed", strconv.Itoa(removed)), err)
+	if err != nil {
+		return 0, err
 	}
 	return removed, nil
 }
 
-// InsertAt inserts an item at a specific position.
-func (s *Service) InsertAt(ctx context.Context, agentID string, position int, item *models.QueueItem) error {
+// ClearPending removes the pending items of an agent's queue and returns
+// them. With dryRun, the items are returned and left queued.
+func (s *Service) ClearPending(ctx context.Context, agentID string, dryRun bool) ([]*models.QueueItem, error) {
+	items, err := s.List(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]*models.QueueItem, 0, len(items))
+	for _, item := range items {
+		if item.Status == models.QueueItemStatusPending {
+			pending = append(pending, item)
+		}
+	}
+	if dryRun || len(pending) == 0 {
+		return pending, nil
+	}
+	if _, err := s.Clear(ctx, agentID); err != nil {
+		return nil, err
+	}
+	s.logger.Info().Str("agent_id", agentID).Int("cleared", len(pending)).Msg("queue cleared")
+	return pending, nil
+}
+
+// InsertAt inserts an item at a specific position. A draining agent rejects
+// it with ErrAgentDraining.
+func (s *Service) InsertAt(ctx context.Context, agentID string, position int, item *models.QueueItem) (err error) {
+	defer func() {
+		s.auditor.Record(ctx, AuditInsert, models.EntityTypeAgent, agentID, itemParams([]*models.QueueItem{item}, "position", strconv.Itoa(position)), err)
+	}()
+	if err := s.checkDraining(ctx, agentID, item); err != nil {
+		return err
+	}
 	if err := s.repo.InsertAt(ctx, agentID, position, item); err != nil {
 		return fmt.Errorf("failed to insert queue item: %w", err)
 	}
@@ -113,7 +201,8 @@ func (s *Service) InsertAt(ctx context.Context, agentID string, position int, it
 }
 
 // Remove deletes an item by ID.
-func (s *Service) Remove(ctx context.Context, itemID string) error {
+func (s *Service) Remove(ctx context.Context, itemID string) (err error) {
+	defer func() { s.auditor.Record(ctx, AuditRemove, models.EntityTypeQueue, itemID, nil, err) }()
 	if err := s.repo.Remove(ctx, itemID); err != nil {
 		if errors.Is(err, db.ErrQueueItemNotFound) {
 			return ErrQueueItemNotFound
@@ -134,6 +223,25 @@ func (s *Service) UpdateStatus(ctx context.Context, itemID string, status models
 	return nil
 }
 
+// ListInFlight returns the items claimed before cutoff and still in flight.
+func (s *Service) ListInFlight(ctx context.Context, cutoff time.Time) ([]*models.QueueItem, error) {
+	items, err := s.repo.ListInFlight(ctx, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list in-flight items: %w", err)
+	}
+	return items, nil
+}
+
+// ReleaseInFlight moves an in-flight item to status, reporting false when
+// it was no longer in flight.
+func (s *Service) ReleaseInFlight(ctx context.Context, itemID string, status models.QueueItemStatus, attempts int, errorMsg string) (bool, error) {
+	released, err := s.repo.ReleaseInFlight(ctx, itemID, status, attempts, errorMsg)
+	if err != nil {
+		return false, fmt.Errorf("failed to release queue item: %w", err)
+	}
+	return released, nil
+}
+
 // UpdateAttempts updates the attempt count for a queue item.
 func (s *Service) UpdateAttempts(ctx context.Context, itemID string, attempts int) error {
 	if err := s.repo.UpdateAttempts(ctx, itemID, attempts); err != nil {
@@ -145,4 +253,29 @@ func (s *Service) UpdateAttempts(ctx context.Context, itemID string, attempts in
 	return nil
 }
 
+// checkDraining returns ErrAgentDraining when agentID is draining and items
+// would add work to its queue. Pause items only hold back work already
+// queued, as after a rate limit, so they are still accepted.
+func (s *Service) checkDraining(ctx context.Context, agentID string, items ...*models.QueueItem) error {
+	addsWork := false
+	for _, item := range items {
+		if item.Type != models.QueueItemTypePause {
+			addsWork = true
+			break
+		}
+	}
+	if !addsWork {
+		return nil
+	}
+
+	draining, err := s.repo.AgentDraining(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	if draining {
+		return fmt.Errorf("%w: %s accepts no new queue items (undrain it with 'swarm agent undrain %s')", ErrAgentDraining, agentID, agentID)
+	}
+	return nil
+}
+
 var _ QueueService = (*Service)(nil)
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/opencode-ai/swarm/internal/audit"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/models"
 )
@@ -114,12 +116,12 @@ func TestService_EnqueuePeekDequeue(t *testing.T) {
 		t.Fatalf("expected peeked item ID to be set")
 	}
 
-	dequeued, err := service.Dequeue(ctx, agent.ID)
+	dequeued, err := service.Dequeue(ctx, agent.ID, "test")
 	if err != nil {
 		t.Fatalf("Dequeue failed: %v", err)
 	}
-	if dequeued.Status != models.QueueItemStatusDispatched {
-		t.Fatalf("expected dispatched status, got %q", dequeued.Status)
+	if dequeued.Status != models.QueueItemStatusInFlight || dequeued.DispatcherID != "test" {
+		t.Fatalf("expected the item in flight for the dispatcher, got %q under %q", dequeued.Status, dequeued.DispatcherID)
 	}
 
 	_, err = service.Peek(ctx, agent.ID)
@@ -187,6 +189,67 @@ func TestService_InsertReorderClearRemove(t *testing.T) {
 	}
 }
 
+func TestService_MutationsAreAudited(t *testing.T) {
+	_, testDB, cleanup := setupTestService(t)
+	defer cleanup()
+
+	service := NewService(db.NewQueueRepository(testDB), WithAuditRecorder(audit.New(testDB, audit.WithActor("dana"))))
+	ws := createTestWorkspace(t, testDB)
+	agent := createTestAgent(t, testDB, ws)
+	ctx := context.Background()
+
+	secret := "sk-ant-REDACTED"
+	item := newMessageItem(t, "export ANTHROPIC_API_KEY="+secret)
+	if err := service.Enqueue(ctx, agent.ID, item); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := service.EnqueueBatch(ctx, agent.ID, newMessageItem(t, "a"), newMessageItem(t, "b")); err != nil {
+		t.Fatalf("EnqueueBatch failed: %v", err)
+	}
+	if err := service.InsertAt(ctx, agent.ID, 1, newMessageItem(t, "c")); err != nil {
+		t.Fatalf("InsertAt failed: %v", err)
+	}
+	if err := service.Remove(ctx, item.ID); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := service.ClearPending(ctx, agent.ID, false); err != nil {
+		t.Fatalf("ClearPending failed: %v", err)
+	}
+	if err := service.Remove(ctx, item.ID); err == nil {
+		t.Fatal("expected removing a removed item to fail")
+	}
+	// Scheduler bookkeeping is not audited.
+	if _, err := service.Dequeue(ctx, agent.ID, "test"); !errors.Is(err, ErrQueueEmpty) {
+		t.Fatalf("expected empty queue, got %v", err)
+	}
+
+	records, err := db.NewAuditRepository(testDB).Query(ctx, db.AuditQuery{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	want := []string{AuditEnqueue, AuditEnqueue, AuditInsert, AuditRemove, AuditClear, AuditRemove}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d audit records, got %d: %+v", len(want), len(records), records)
+	}
+	for i, op := range want {
+		record := records[len(records)-1-i]
+		if record.Operation != op || record.Actor != "dana" {
+			t.Errorf("record %d: got %s by %s, want %s", i, record.Operation, record.Actor, op)
+		}
+		for _, v := range record.Parameters {
+			if strings.Contains(v, secret) {
+				t.Errorf("record %d leaks the queued payload: %+v", i, record.Parameters)
+			}
+		}
+	}
+	if batch := records[4]; batch.Parameters["items"] != "2" || batch.Parameters["batch"] != "true" {
+		t.Errorf("unexpected batch parameters %+v", batch.Parameters)
+	}
+	if failed := records[0]; failed.Result != models.AuditResultFailure || failed.EntityID != item.ID {
+		t.Errorf("expected the failed remove recorded, got %+v", failed)
+	}
+}
+
 func TestService_UpdateStatus(t *testing.T) {
 	service, testDB, cleanup := setupTestService(t)
 	defer cleanup()
@@ -289,7 +352,7 @@ func TestService_Dequeue_Empty(t *testing.T) {
 	ctx := context.Background()
 
 	// Dequeue from empty queue
-	_, err := service.Dequeue(ctx, agent.ID)
+	_, err := service.Dequeue(ctx, agent.ID, "test")
 	if err == nil {
 		t.Fatal("expected error for empty queue")
 	}
@@ -297,3 +360,88 @@ func TestService_Dequeue_Empty(t *testing.T) {
 		t.Errorf("expected ErrQueueEmpty, got %v", err)
 	}
 }
+
+func TestService_DrainingAgentRejectsNewItems(t *testing.T) {
+	service, testDB, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ws := createTestWorkspace(t, testDB)
+	agent := createTestAgent(t, testDB, ws)
+	agentRepo := db.NewAgentRepository(testDB)
+	ctx := context.Background()
+
+	if err := service.Enqueue(ctx, agent.ID, newMessageItem(t, "queued before drain")); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := agentRepo.SetDraining(ctx, agent.ID, true); err != nil {
+		t.Fatalf("SetDraining failed: %v", err)
+	}
+
+	if err := service.Enqueue(ctx, agent.ID, newMessageItem(t, "late")); !errors.Is(err, ErrAgentDraining) {
+		t.Fatalf("expected ErrAgentDraining from Enqueue, got %v", err)
+	}
+	if err := service.InsertAt(ctx, agent.ID, 0, newMessageItem(t, "late")); !errors.Is(err, ErrAgentDraining) {
+		t.Fatalf("expected ErrAgentDraining from InsertAt, got %v", err)
+	}
+	if err := service.EnqueueBatchAtHead(ctx, agent.ID, newMessageItem(t, "late")); !errors.Is(err, ErrAgentDraining) {
+		t.Fatalf("expected ErrAgentDraining from EnqueueBatchAtHead, got %v", err)
+	}
+	pause := &models.QueueItem{Type: models.QueueItemTypePause, Payload: json.RawMessage(`{"duration_seconds":60}`)}
+	if err := service.Enqueue(ctx, agent.ID, pause); err != nil {
+		t.Fatalf("expected pause items to be accepted while draining, got %v", err)
+	}
+
+	dequeued, err := service.Dequeue(ctx, agent.ID, "test")
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	var payload models.MessagePayload
+	if err := json.Unmarshal(dequeued.Payload, &payload); err != nil || payload.Text != "queued before drain" {
+		t.Fatalf("expected the existing item to dispatch, got %s", dequeued.Payload)
+	}
+
+	if err := agentRepo.SetDraining(ctx, agent.ID, false); err != nil {
+		t.Fatalf("SetDraining failed: %v", err)
+	}
+	if err := service.Enqueue(ctx, agent.ID, newMessageItem(t, "after undrain")); err != nil {
+		t.Fatalf("Enqueue after undrain failed: %v", err)
+	}
+}
+
+func TestService_ClearPendingDryRun(t *testing.T) {
+	service, testDB, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ws := createTestWorkspace(t, testDB)
+	agent := createTestAgent(t, testDB, ws)
+	ctx := context.Background()
+
+	if err := service.Enqueue(ctx, agent.ID, newMessageItem(t, "one"), newMessageItem(t, "two"), newMessageItem(t, "three")); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if _, err := service.Dequeue(ctx, agent.ID, "test"); err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+
+	planned, err := service.ClearPending(ctx, agent.ID, true)
+	if err != nil {
+		t.Fatalf("ClearPending dry run failed: %v", err)
+	}
+	if len(planned) != 2 {
+		t.Fatalf("expected the 2 pending items planned, got %d", len(planned))
+	}
+	if items, _ := service.List(ctx, agent.ID); len(items) != 3 {
+		t.Fatalf("dry run must leave the queue alone, got %d items", len(items))
+	}
+
+	cleared, err := service.ClearPending(ctx, agent.ID, false)
+	if err != nil {
+		t.Fatalf("ClearPending failed: %v", err)
+	}
+	if len(cleared) != len(planned) || cleared[0].ID != planned[0].ID || cleared[1].ID != planned[1].ID {
+		t.Fatalf("expected the planned items cleared, got %v", cleared)
+	}
+	if items, _ := service.List(ctx, agent.ID); len(items) != 1 || items[0].Status != models.QueueItemStatusInFlight {
+		t.Fatalf("expected only the in-flight item left, got %v", items)
+	}
+}
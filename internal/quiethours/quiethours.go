@@ -0,0 +1,301 @@
+// Package quiethours parses and evaluates recurring quiet hours: weekly
+// windows during which the scheduler holds queued work.
+package quiethours
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+)
+
+// None is the spec that turns quiet hours off, overriding a less specific
+// setting.
+const None = "none"
+
+const minutesPerDay = 24 * 60
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Window is one recurring quiet period:
+//
+//	[days] HH:MM-HH:MM [time zone]
+//
+// Days are three-letter weekday names, ranges (mon-fri, fri-mon) and lists
+// of either (mon,wed,fri), or "daily"; without days the window recurs every
+// day. A window whose end is not after its start spans midnight and
+// belongs to the day it starts on, so "fri 22:00-07:00" ends Saturday
+// morning. The end may be written 24:00. Times are wall-clock times in
+// the IANA time zone given, or the local time zone, so a window keeps its
+// hours across daylight-saving changes.
+type Window struct {
+	source     string
+	days       [7]bool
+	start, end int // minutes after midnight
+	loc        *time.Location
+}
+
+// Parse parses a single window.
+func Parse(spec string) (Window, error) {
+	fields := strings.Fields(spec)
+	source := strings.Join(fields, " ")
+	if len(fields) == 0 || len(fields) > 3 {
+		return Window{}, fmt.Errorf("quiet hours %q must be [days] HH:MM-HH:MM [time zone]", source)
+	}
+
+	w := Window{source: source, loc: time.Local}
+	if strings.Contains(fields[0], ":") {
+		for day := range w.days {
+			w.days[day] = true
+		}
+	} else {
+		days, err := parseDays(fields[0])
+		if err != nil {
+			return Window{}, fmt.Errorf("quiet hours %q: %w", source, err)
+		}
+		w.days = days
+		fields = fields[1:]
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		return Window{}, fmt.Errorf("quiet hours %q must be [days] HH:MM-HH:MM [time zone]", source)
+	}
+
+	var err error
+	if w.start, w.end, err = parseTimeRange(fields[0]); err != nil {
+		return Window{}, fmt.Errorf("quiet hours %q: %w", source, err)
+	}
+	if len(fields) == 2 {
+		if w.loc, err = time.LoadLocation(fields[1]); err != nil {
+			return Window{}, fmt.Errorf("quiet hours %q: unknown time zone %q", source, fields[1])
+		}
+	}
+	return w, nil
+}
+
+func parseDays(spec string) ([7]bool, error) {
+	var days [7]bool
+	if strings.EqualFold(spec, "daily") {
+		for day := range days {
+			days[day] = true
+		}
+		return days, nil
+	}
+	for _, item := range strings.Split(strings.ToLower(spec), ",") {
+		if item == "" {
+			return days, fmt.Errorf("empty day in %q", spec)
+		}
+		first, last, isRange := strings.Cut(item, "-")
+		if !isRange {
+			last = first
+		}
+		from, ok := weekdays[first]
+		if !ok {
+			return days, fmt.Errorf("invalid day %q (use mon, tue, ... or daily)", first)
+		}
+		to, ok := weekdays[last]
+		if !ok {
+			return days, fmt.Errorf("invalid day %q (use mon, tue, ... or daily)", last)
+		}
+		// Ranges may wrap past Sunday, as in fri-mon.
+		for day := from; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func parseTimeRange(spec string) (start, end int, err error) {
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid time range %q (want HH:MM-HH:MM)", spec)
+	}
+	if start, err = parseClock(from); err != nil {
+		return 0, 0, err
+	}
+	if start == minutesPerDay {
+		return 0, 0, fmt.Errorf("start time %q must be before 24:00", from)
+	}
+	if end, err = parseClock(to); err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("time range %q is empty", spec)
+	}
+	return start, end, nil
+}
+
+// parseClock parses HH:MM into minutes after midnight, allowing 24:00.
+func parseClock(spec string) (int, error) {
+	invalid := fmt.Errorf("invalid time %q (want HH:MM)", spec)
+	h, m, ok := strings.Cut(spec, ":")
+	if !ok || len(h) < 1 || len(h) > 2 || len(m) != 2 || strings.ContainsAny(spec, "+-") {
+		return 0, invalid
+	}
+	hour, err := strconv.Atoi(h)
+	if err != nil || hour > 24 {
+		return 0, invalid
+	}
+	minute, err := strconv.Atoi(m)
+	if err != nil || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, invalid
+	}
+	return hour*60 + minute, nil
+}
+
+// String returns the window as it was written, with spacing normalized.
+func (w Window) String() string {
+	return w.source
+}
+
+// Location returns the time zone the window is evaluated in.
+func (w Window) Location() *time.Location {
+	return w.loc
+}
+
+func (w Window) spansMidnight() bool {
+	return w.end <= w.start
+}
+
+// Contains reports whether t falls inside an occurrence of the window.
+func (w Window) Contains(t time.Time) bool {
+	local := t.In(w.loc)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+	if !w.spansMidnight() {
+		return w.days[day] && minute >= w.start && minute < w.end
+	}
+	yesterday := (day + 6) % 7
+	return (w.days[day] && minute >= w.start) || (w.days[yesterday] && minute < w.end)
+}
+
+// endAfter returns when the occurrence containing t ends.
+func (w Window) endAfter(t time.Time) time.Time {
+	local := t.In(w.loc)
+	day := local.Day()
+	if w.spansMidnight() && local.Hour()*60+local.Minute() >= w.start {
+		day++
+	}
+	end := time.Date(local.Year(), local.Month(), day, w.end/60, w.end%60, 0, 0, w.loc)
+	if w.end == minutesPerDay || end.Hour()*60+end.Minute() == w.end {
+		return end
+	}
+
+	// The end falls in a daylight-saving gap, so the window closes when the
+	// clocks jump past it; search for that instant.
+	lo, hi := t, end
+	for hi.Sub(lo) > time.Millisecond {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if w.Contains(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi.Truncate(time.Second).In(w.loc)
+}
+
+// Schedule is a set of windows. The zero Schedule has no quiet hours.
+type Schedule struct {
+	windows []Window
+}
+
+// ParseSchedule parses windows separated by semicolons, e.g.
+// "mon-fri 22:00-07:00 Europe/Oslo; sat,sun 00:00-24:00 Europe/Oslo".
+// An empty spec and "none" parse to the zero Schedule.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || strings.EqualFold(spec, None) {
+		return Schedule{}, nil
+	}
+	var s Schedule
+	for _, part := range strings.Split(spec, ";") {
+		w, err := Parse(part)
+		if err != nil {
+			return Schedule{}, err
+		}
+		s.windows = append(s.windows, w)
+	}
+	return s, nil
+}
+
+// Resolve parses the first non-empty spec. Listing specs from the most
+// specific setting to the least lets an agent override its workspace and
+// a workspace the global default, including with "none".
+func Resolve(specs ...string) (Schedule, error) {
+	for _, spec := range specs {
+		if strings.TrimSpace(spec) != "" {
+			return ParseSchedule(spec)
+		}
+	}
+	return Schedule{}, nil
+}
+
+// IsZero reports whether the schedule has no windows.
+func (s Schedule) IsZero() bool {
+	return len(s.windows) == 0
+}
+
+// Windows returns the schedule's windows.
+func (s Schedule) Windows() []Window {
+	return s.windows
+}
+
+// String returns the schedule's spec, or "none" when it has no windows.
+func (s Schedule) String() string {
+	if s.IsZero() {
+		return None
+	}
+	parts := make([]string, len(s.windows))
+	for i, w := range s.windows {
+		parts[i] = w.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Until reports whether t falls in quiet hours and, if so, when they end.
+// Windows that overlap or follow one another without a gap count as one
+// quiet period.
+func (s Schedule) Until(t time.Time) (time.Time, bool) {
+	until, quiet := t, false
+	// Bounded so a schedule covering the whole week still returns.
+	for i := 0; i < 8*len(s.windows); i++ {
+		next := until
+		for _, w := range s.windows {
+			if !w.Contains(until) {
+				continue
+			}
+			if end := w.endAfter(until); end.After(next) {
+				next = end
+			}
+		}
+		if !next.After(until) {
+			break
+		}
+		until, quiet = next, true
+	}
+	return until, quiet
+}
+
+// Evaluator checks schedules against a clock.
+type Evaluator struct {
+	clock clock.Clock
+}
+
+// NewEvaluator creates an evaluator reading the time from c, or the real
+// clock when c is nil.
+func NewEvaluator(c clock.Clock) *Evaluator {
+	return &Evaluator{clock: clock.OrReal(c)}
+}
+
+// Until reports whether s is in quiet hours now and, if so, when they end.
+func (e *Evaluator) Until(s Schedule) (time.Time, bool) {
+	return s.Until(e.clock.Now())
+}
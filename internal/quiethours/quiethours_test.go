@@ -0,0 +1,325 @@
+package quiethours
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // fixed zone data for the DST cases
+
+	"github.com/opencode-ai/swarm/internal/clock"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec  string
+		days  string // weekdays in the window, Sunday first
+		start int
+		end   int
+		zone  string
+	}{
+		{"mon-fri 22:00-07:00 Europe/Oslo", "-MTWTF-", 22 * 60, 7 * 60, "Europe/Oslo"},
+		{"  MON-FRI   22:00-07:00  ", "-MTWTF-", 22 * 60, 7 * 60, "Local"},
+		{"sat,sun 00:00-24:00 UTC", "S-----S", 0, 24 * 60, "UTC"},
+		{"fri-mon 12:30-13:45", "SM---FS", 12*60 + 30, 13*60 + 45, "Local"},
+		{"mon,wed-thu,sat 9:00-17:00", "-M-WT-S", 9 * 60, 17 * 60, "Local"},
+		{"daily 23:00-06:00 America/New_York", "SMTWTFS", 23 * 60, 6 * 60, "America/New_York"},
+		{"12:00-13:00", "SMTWTFS", 12 * 60, 13 * 60, "Local"},
+		{"12:00-13:00 Asia/Tokyo", "SMTWTFS", 12 * 60, 13 * 60, "Asia/Tokyo"},
+		{"sun 00:00-00:01", "S------", 0, 1, "Local"},
+		{"wed 18:00-00:00", "---W---", 18 * 60, 0, "Local"},
+	}
+	for _, tt := range tests {
+		w, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.spec, err)
+			continue
+		}
+		var days strings.Builder
+		for day, in := range w.days {
+			if in {
+				days.WriteByte("SMTWTFS"[day])
+			} else {
+				days.WriteByte('-')
+			}
+		}
+		if days.String() != tt.days || w.start != tt.start || w.end != tt.end || w.Location().String() != tt.zone {
+			t.Errorf("Parse(%q) = days %s %d-%d %s, want days %s %d-%d %s", tt.spec,
+				days.String(), w.start, w.end, w.Location(), tt.days, tt.start, tt.end, tt.zone)
+		}
+		if w.String() != strings.Join(strings.Fields(tt.spec), " ") {
+			t.Errorf("Parse(%q).String() = %q", tt.spec, w.String())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"", "must be [days] HH:MM-HH:MM"},
+		{"mon-fri", "must be [days] HH:MM-HH:MM"},
+		{"mon-fri 22:00-07:00 Europe/Oslo extra", "must be [days] HH:MM-HH:MM"},
+		{"22:00-07:00 UTC extra", "must be [days] HH:MM-HH:MM"},
+		{"weekdays 22:00-07:00", `invalid day "weekdays"`},
+		{"mon-frx 22:00-07:00", `invalid day "frx"`},
+		{"monday 22:00-07:00", `invalid day "monday"`},
+		{"mon,,fri 22:00-07:00", "empty day"},
+		{"mon 22:00", "invalid time range"},
+		{"mon 22-07", "invalid time"},
+		{"mon 25:00-07:00", `invalid time "25:00"`},
+		{"mon 22:60-07:00", `invalid time "22:60"`},
+		{"mon 22:0-07:00", `invalid time "22:0"`},
+		{"mon 123:00-07:00", `invalid time "123:00"`},
+		{"mon 24:30-07:00", `invalid time "24:30"`},
+		{"mon +1:00-07:00", `invalid time "+1:00"`},
+		{"mon 24:00-07:00", "must be before 24:00"},
+		{"mon 07:00-07:00", "is empty"},
+		{"mon 22:00-07:00 Mars/Olympus", "unknown time zone"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.spec)
+		if err == nil {
+			t.Errorf("Parse(%q): expected error", tt.spec)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.spec, err, tt.want)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	for _, spec := range []string{"", "  ", "none", "NONE"} {
+		s, err := ParseSchedule(spec)
+		if err != nil || !s.IsZero() {
+			t.Errorf("ParseSchedule(%q) = %v, %v; want no quiet hours", spec, s, err)
+		}
+	}
+
+	s, err := ParseSchedule("mon-fri 22:00-07:00 UTC;sat,sun  00:00-24:00 UTC")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+	if len(s.Windows()) != 2 || s.String() != "mon-fri 22:00-07:00 UTC; sat,sun 00:00-24:00 UTC" {
+		t.Errorf("unexpected schedule %q", s)
+	}
+
+	if _, err := ParseSchedule("mon 22:00-07:00; tue 25:00-07:00"); err == nil || !strings.Contains(err.Error(), "25:00") {
+		t.Errorf("expected the bad window reported, got %v", err)
+	}
+	if _, err := ParseSchedule("mon 22:00-07:00;"); err == nil {
+		t.Error("expected an empty window to fail")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []string
+		want  string
+	}{
+		{"nothing set", []string{"", "", ""}, "none"},
+		{"global only", []string{"", "", "daily 22:00-07:00 UTC"}, "daily 22:00-07:00 UTC"},
+		{"workspace beats global", []string{"", "sat,sun 00:00-24:00 UTC", "daily 22:00-07:00 UTC"}, "sat,sun 00:00-24:00 UTC"},
+		{"agent beats workspace", []string{"mon 09:00-10:00 UTC", "sat,sun 00:00-24:00 UTC", "daily 22:00-07:00 UTC"}, "mon 09:00-10:00 UTC"},
+		{"none overrides", []string{"none", "sat,sun 00:00-24:00 UTC", "daily 22:00-07:00 UTC"}, "none"},
+	}
+	for _, tt := range tests {
+		s, err := Resolve(tt.specs...)
+		if err != nil {
+			t.Errorf("%s: Resolve() error = %v", tt.name, err)
+			continue
+		}
+		if s.String() != tt.want {
+			t.Errorf("%s: Resolve() = %q, want %q", tt.name, s, tt.want)
+		}
+	}
+
+	if _, err := Resolve("mon 25:00-07:00", "daily 22:00-07:00 UTC"); err == nil {
+		t.Error("expected an invalid specific setting to fail rather than fall through")
+	}
+}
+
+func TestContains(t *testing.T) {
+	oslo := mustLocation(t, "Europe/Oslo")
+	// 2026-10-12 is a Monday; Oslo is on CEST (UTC+2) until 2026-10-25.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, oslo)
+	}
+
+	tests := []struct {
+		spec string
+		at   time.Time
+		want bool
+	}{
+		// A window spanning midnight belongs to the day it starts on.
+		{"mon-fri 22:00-07:00 Europe/Oslo", at(12, 21, 59), false},
+		{"mon-fri 22:00-07:00 Europe/Oslo", at(12, 22, 0), true},
+		{"mon-fri 22:00-07:00 Europe/Oslo", at(12, 23, 59), true},
+		{"mon-fri 22:00-07:00 Europe/Oslo", at(13, 0, 0), true},
+		{"mon-fri 22:00-07:00 Europe/Oslo", at(13, 6, 59), true},
+		{"mon-fri 22:00-07:00 Europe/Oslo", at(13, 7, 0), false},
+		{"mon-fri 22:00-07:00 Europe/Oslo", at(12, 3, 0), false},   // Sunday night is not quiet
+		{"mon-fri 22:00-07:00 Europe/Oslo", at(17, 3, 0), true},    // Friday night runs into Saturday
+		{"mon-fri 22:00-07:00 Europe/Oslo", at(17, 22, 30), false}, // Saturday evening
+		{"mon-fri 22:00-07:00 Europe/Oslo", at(18, 3, 0), false},
+		// Same-day windows include their start and exclude their end.
+		{"sat,sun 00:00-24:00 Europe/Oslo", at(17, 0, 0), true},
+		{"sat,sun 00:00-24:00 Europe/Oslo", at(18, 23, 59), true},
+		{"sat,sun 00:00-24:00 Europe/Oslo", at(19, 0, 0), false},
+		{"sat,sun 00:00-24:00 Europe/Oslo", at(16, 23, 59), false},
+		{"wed 12:00-13:00 Europe/Oslo", at(14, 12, 30), true},
+		{"wed 12:00-13:00 Europe/Oslo", at(14, 13, 0), false},
+		{"wed 12:00-13:00 Europe/Oslo", at(15, 12, 30), false},
+		// Ending at midnight spans into the next day for no time at all.
+		{"wed 18:00-00:00 Europe/Oslo", at(14, 23, 59), true},
+		{"wed 18:00-00:00 Europe/Oslo", at(15, 0, 0), false},
+		// Day ranges wrap past Sunday.
+		{"fri-mon 12:00-13:00 Europe/Oslo", at(12, 12, 0), true},
+		{"fri-mon 12:00-13:00 Europe/Oslo", at(13, 12, 0), false},
+		{"fri-mon 12:00-13:00 Europe/Oslo", at(18, 12, 0), true},
+		// Wall-clock times are read in the window's zone, not the caller's.
+		{"wed 12:00-13:00 Europe/Oslo", time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC), true},
+		{"wed 12:00-13:00 UTC", time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC), false},
+		{"wed 23:00-01:00 America/New_York", time.Date(2026, 10, 15, 3, 30, 0, 0, time.UTC), true},
+		{"wed 23:00-01:00 America/New_York", time.Date(2026, 10, 15, 5, 30, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		w, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.spec, err)
+		}
+		if got := w.Contains(tt.at); got != tt.want {
+			t.Errorf("%q.Contains(%s) = %v, want %v", tt.spec, tt.at.Format("Mon 15:04 MST"), got, tt.want)
+		}
+	}
+}
+
+func TestContainsAcrossDST(t *testing.T) {
+	oslo := mustLocation(t, "Europe/Oslo")
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		at   time.Time
+		want bool
+	}{
+		// Clocks go back from 03:00 CEST to 02:00 CET on 2026-10-25; the
+		// window keeps its wall-clock hours on either side.
+		{"before fall back", "daily 22:00-07:00 Europe/Oslo", utc(10, 24, 19, 59), false},
+		{"start before fall back", "daily 22:00-07:00 Europe/Oslo", utc(10, 24, 20, 0), true},
+		{"end after fall back", "daily 22:00-07:00 Europe/Oslo", utc(10, 25, 5, 59), true},
+		{"past end after fall back", "daily 22:00-07:00 Europe/Oslo", utc(10, 25, 6, 0), false},
+		{"first 02:30", "sun 02:00-03:00 Europe/Oslo", utc(10, 25, 0, 30), true},
+		{"repeated 02:30", "sun 02:00-03:00 Europe/Oslo", utc(10, 25, 1, 30), true},
+		{"after repeated hour", "sun 02:00-03:00 Europe/Oslo", utc(10, 25, 2, 0), false},
+		// Clocks jump from 02:00 CET to 03:00 CEST on 2026-03-29.
+		{"start after spring forward", "daily 22:00-07:00 Europe/Oslo", utc(3, 28, 21, 0), true},
+		{"end after spring forward", "daily 22:00-07:00 Europe/Oslo", utc(3, 29, 4, 59), true},
+		{"past end after spring forward", "daily 22:00-07:00 Europe/Oslo", utc(3, 29, 5, 0), false},
+		{"skipped hour", "sun 02:00-03:00 Europe/Oslo", utc(3, 29, 1, 0), false},
+		{"just before skipped hour", "sun 01:00-02:30 Europe/Oslo", utc(3, 29, 0, 59), true},
+	}
+	for _, tt := range tests {
+		w, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.spec, err)
+		}
+		if got := w.Contains(tt.at); got != tt.want {
+			t.Errorf("%s: %q.Contains(%s) = %v, want %v", tt.name, tt.spec, tt.at.In(oslo).Format("Jan 2 15:04 MST"), got, tt.want)
+		}
+	}
+}
+
+func TestScheduleUntil(t *testing.T) {
+	oslo := mustLocation(t, "Europe/Oslo")
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		spec  string
+		at    time.Time
+		quiet bool
+		until string // in Oslo time
+	}{
+		{"outside", "mon-fri 22:00-07:00 Europe/Oslo", utc(10, 14, 12, 0), false, ""},
+		{"before midnight", "mon-fri 22:00-07:00 Europe/Oslo", utc(10, 14, 21, 0), true, "Oct 15 07:00 CEST"},
+		{"after midnight", "mon-fri 22:00-07:00 Europe/Oslo", utc(10, 15, 1, 0), true, "Oct 15 07:00 CEST"},
+		{"ends at midnight", "sat,sun 00:00-24:00 Europe/Oslo", utc(10, 18, 12, 0), true, "Oct 19 00:00 CEST"},
+		{"adjacent windows merge", "fri 22:00-24:00 Europe/Oslo; sat 00:00-09:00 Europe/Oslo", utc(10, 16, 21, 0), true, "Oct 17 09:00 CEST"},
+		{"overlapping windows merge", "daily 22:00-07:00 Europe/Oslo; sat,sun 06:00-10:00 Europe/Oslo", utc(10, 17, 1, 0), true, "Oct 17 10:00 CEST"},
+		{"latest overlapping end", "daily 22:00-02:00 Europe/Oslo; daily 23:00-03:00 Europe/Oslo", utc(10, 14, 22, 0), true, "Oct 15 03:00 CEST"},
+		{"fall back", "daily 22:00-07:00 Europe/Oslo", utc(10, 24, 22, 0), true, "Oct 25 07:00 CET"},
+		{"spring forward", "daily 22:00-07:00 Europe/Oslo", utc(3, 28, 22, 0), true, "Mar 29 07:00 CEST"},
+		// 02:30 never happens on 2026-03-29; the window closes at the jump.
+		{"end skipped by spring forward", "sun 01:00-02:30 Europe/Oslo", utc(3, 29, 0, 30), true, "Mar 29 03:00 CEST"},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) error = %v", tt.spec, err)
+		}
+		until, quiet := s.Until(tt.at)
+		if quiet != tt.quiet {
+			t.Errorf("%s: Until() quiet = %v, want %v", tt.name, quiet, tt.quiet)
+			continue
+		}
+		if !quiet {
+			continue
+		}
+		if got := until.In(oslo).Format("Jan 2 15:04 MST"); got != tt.until {
+			t.Errorf("%s: Until() = %s, want %s", tt.name, got, tt.until)
+		}
+		if s.Windows()[0].Contains(until) && len(s.Windows()) == 1 {
+			t.Errorf("%s: Until() = %s is still inside the window", tt.name, until)
+		}
+	}
+
+	always, err := ParseSchedule("daily 00:00-24:00 UTC")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+	if until, quiet := always.Until(utc(10, 14, 12, 0)); !quiet || !until.After(utc(10, 20, 0, 0)) {
+		t.Errorf("expected a schedule covering every day to stay quiet for days, got %s, %v", until, quiet)
+	}
+	if _, quiet := (Schedule{}).Until(utc(10, 14, 12, 0)); quiet {
+		t.Error("expected the zero schedule never to be quiet")
+	}
+}
+
+func TestEvaluatorUsesClock(t *testing.T) {
+	s, err := ParseSchedule("daily 22:00-07:00 UTC")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+	fake := clock.NewFake(time.Date(2026, 10, 14, 21, 0, 0, 0, time.UTC))
+	evaluator := NewEvaluator(fake)
+
+	if _, quiet := evaluator.Until(s); quiet {
+		t.Fatal("expected no quiet hours at 21:00")
+	}
+	fake.Advance(2 * time.Hour)
+	until, quiet := evaluator.Until(s)
+	if !quiet || !until.Equal(time.Date(2026, 10, 15, 7, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected quiet hours until 07:00, got %s, %v", until, quiet)
+	}
+	fake.Advance(8 * time.Hour)
+	if _, quiet := evaluator.Until(s); quiet {
+		t.Fatal("expected quiet hours over at 07:00")
+	}
+}
@@ -44,6 +44,7 @@ type Recipe struct {
 type AgentSpec struct {
 	Count           int               `yaml:"count"`
 	Type            models.AgentType  `yaml:"type"`
+	Model           string            `yaml:"model,omitempty"`
 	Profile         string            `yaml:"profile,omitempty"`
 	ProfileRotation string            `yaml:"profile_rotation,omitempty"` // round-robin, random, balanced
 	InitialSequence string            `yaml:"initial_sequence,omitempty"`
@@ -0,0 +1,177 @@
+// Package recording captures agent pane output as asciinema v2 cast files
+// and plays recordings back with their original timing.
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CastVersion is the asciinema cast format version written and read.
+const CastVersion = 2
+
+// EventOutput is the cast event type for terminal output.
+const EventOutput = "o"
+
+// ErrInvalidCast is returned when a cast file cannot be parsed.
+var ErrInvalidCast = errors.New("invalid cast file")
+
+// Header is the first line of a cast file.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is a single timed chunk of terminal output.
+type Event struct {
+	// Time is the offset from the start of the recording.
+	Time time.Duration
+	Type string
+	Data string
+}
+
+// Cast is a parsed recording.
+type Cast struct {
+	Header Header
+	Events []Event
+}
+
+// Duration returns the offset of the last event.
+func (c *Cast) Duration() time.Duration {
+	if c == nil || len(c.Events) == 0 {
+		return 0
+	}
+	return c.Events[len(c.Events)-1].Time
+}
+
+// Writer writes a cast file: a JSON header line followed by one JSON array
+// per event.
+type Writer struct {
+	w    io.Writer
+	size int64
+}
+
+// NewWriter writes header to w and returns a writer for its events.
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	if header.Version == 0 {
+		header.Version = CastVersion
+	}
+	if header.Width <= 0 || header.Height <= 0 {
+		return nil, fmt.Errorf("invalid terminal size %dx%d", header.Width, header.Height)
+	}
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cast header: %w", err)
+	}
+
+	cw := &Writer{w: w}
+	if err := cw.writeLine(data); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// WriteEvent appends an event line.
+func (w *Writer) WriteEvent(event Event) error {
+	if event.Type == "" {
+		event.Type = EventOutput
+	}
+	eventType, err := json.Marshal(event.Type)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	line := make([]byte, 0, len(data)+32)
+	line = append(line, '[')
+	line = strconv.AppendFloat(line, event.Time.Seconds(), 'f', 6, 64)
+	line = append(line, ", "...)
+	line = append(line, eventType...)
+	line = append(line, ", "...)
+	line = append(line, data...)
+	line = append(line, ']')
+	return w.writeLine(line)
+}
+
+// Size returns the number of bytes written so far, including the header.
+func (w *Writer) Size() int64 {
+	return w.size
+}
+
+func (w *Writer) writeLine(line []byte) error {
+	n, err := w.w.Write(append(line, '\n'))
+	w.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write cast: %w", err)
+	}
+	return nil
+}
+
+// ReadCast parses a cast file. Event types other than output are kept so
+// exports stay lossless; playback ignores them.
+func ReadCast(r io.Reader) (*Cast, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidCast)
+	}
+
+	var cast Cast
+	if err := json.Unmarshal(scanner.Bytes(), &cast.Header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidCast, err)
+	}
+	if cast.Header.Version != CastVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidCast, cast.Header.Version)
+	}
+
+	line := 1
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		var fields []json.RawMessage
+		if err := json.Unmarshal([]byte(raw), &fields); err != nil || len(fields) != 3 {
+			return nil, fmt.Errorf("%w: line %d: expected [time, type, data]", ErrInvalidCast, line)
+		}
+		var seconds float64
+		var event Event
+		if err := json.Unmarshal(fields[0], &seconds); err != nil || seconds < 0 || math.IsInf(seconds, 0) {
+			return nil, fmt.Errorf("%w: line %d: invalid time", ErrInvalidCast, line)
+		}
+		if err := json.Unmarshal(fields[1], &event.Type); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid type", ErrInvalidCast, line)
+		}
+		if err := json.Unmarshal(fields[2], &event.Data); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid data", ErrInvalidCast, line)
+		}
+		event.Time = time.Duration(seconds * float64(time.Second))
+		cast.Events = append(cast.Events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &cast, nil
+}
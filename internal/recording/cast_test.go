@@ -0,0 +1,93 @@
+package recording
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterFormat(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Width: 80, Height: 24, Timestamp: 1700000000, Title: "agent"})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.WriteEvent(Event{Time: 0, Data: "$ ls\r\n"}); err != nil {
+		t.Fatalf("WriteEvent failed: %v", err)
+	}
+	if err := w.WriteEvent(Event{Time: 1500 * time.Millisecond, Data: "\x1b[32mok\x1b[0m \"done\""}); err != nil {
+		t.Fatalf("WriteEvent failed: %v", err)
+	}
+
+	want := `{"version":2,"width":80,"height":24,"timestamp":1700000000,"title":"agent"}
+[0.000000, "o", "$ ls\r\n"]
+[1.500000, "o", "\u001b[32mok\u001b[0m \"done\""]
+`
+	if buf.String() != want {
+		t.Fatalf("unexpected cast:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	if w.Size() != int64(buf.Len()) {
+		t.Fatalf("Size() = %d, want %d", w.Size(), buf.Len())
+	}
+}
+
+func TestWriterRejectsMissingSize(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, Header{}); err == nil {
+		t.Fatal("expected error for zero terminal size")
+	}
+}
+
+func TestReadCastRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Width: 100, Height: 30})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	events := []Event{
+		{Time: 0, Type: EventOutput, Data: "héllo"},
+		{Time: 250 * time.Millisecond, Type: "m", Data: "marker"},
+		{Time: 2 * time.Second, Type: EventOutput, Data: "\r\nbye"},
+	}
+	for _, event := range events {
+		if err := w.WriteEvent(event); err != nil {
+			t.Fatalf("WriteEvent failed: %v", err)
+		}
+	}
+
+	cast, err := ReadCast(&buf)
+	if err != nil {
+		t.Fatalf("ReadCast failed: %v", err)
+	}
+	if cast.Header.Version != CastVersion || cast.Header.Width != 100 || cast.Header.Height != 30 {
+		t.Fatalf("unexpected header %+v", cast.Header)
+	}
+	if len(cast.Events) != len(events) {
+		t.Fatalf("expected %d events, got %d", len(events), len(cast.Events))
+	}
+	for i, event := range events {
+		if cast.Events[i] != event {
+			t.Fatalf("event %d = %+v, want %+v", i, cast.Events[i], event)
+		}
+	}
+	if cast.Duration() != 2*time.Second {
+		t.Fatalf("Duration() = %s", cast.Duration())
+	}
+}
+
+func TestReadCastErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":       "",
+		"bad header":  "not json\n",
+		"old version": `{"version":1,"width":80,"height":24}` + "\n",
+		"bad event":   `{"version":2,"width":80,"height":24}` + "\n[0.1, \"o\"]\n",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadCast(strings.NewReader(input)); !errors.Is(err, ErrInvalidCast) {
+				t.Fatalf("expected ErrInvalidCast, got %v", err)
+			}
+		})
+	}
+}
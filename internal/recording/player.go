@@ -0,0 +1,77 @@
+package recording
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+)
+
+// PlayOptions configures Play.
+type PlayOptions struct {
+	// Speed multiplies playback speed. Values <= 0 mean 1.
+	Speed float64
+
+	// MaxIdle caps the pause between events (before speed is applied).
+	// Zero keeps the recorded gaps.
+	MaxIdle time.Duration
+
+	// Clock paces playback. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// Play writes the output events of cast to w using their recorded timing.
+func Play(ctx context.Context, w io.Writer, cast *Cast, opts PlayOptions) error {
+	if cast == nil {
+		return nil
+	}
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	clk := clock.OrReal(opts.Clock)
+
+	var last time.Duration
+	for _, event := range cast.Events {
+		if event.Type != EventOutput {
+			continue
+		}
+
+		gap := event.Time - last
+		last = event.Time
+		if opts.MaxIdle > 0 && gap > opts.MaxIdle {
+			gap = opts.MaxIdle
+		}
+		if delay := time.Duration(float64(gap) / speed); delay > 0 {
+			timer := clk.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C():
+			}
+		}
+
+		if _, err := io.WriteString(w, event.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseSpeed parses a playback speed such as "2", "2x", or "0.5x".
+func ParseSpeed(value string) (float64, error) {
+	raw := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), "x")
+	if raw == "" {
+		return 1, nil
+	}
+	speed, err := strconv.ParseFloat(raw, 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("invalid speed %q (use a positive multiplier like 2x or 0.5x)", value)
+	}
+	return speed, nil
+}
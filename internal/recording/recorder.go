@@ -0,0 +1,196 @@
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/fsperm"
+)
+
+const (
+	// DefaultMaxBytes caps a single cast file before it is rotated.
+	DefaultMaxBytes int64 = 50 * 1024 * 1024
+
+	// DefaultMaxSegments is how many rotated cast files are kept.
+	DefaultMaxSegments = 2
+
+	readBufferSize = 32 * 1024
+)
+
+// RecorderOptions configures Record.
+type RecorderOptions struct {
+	// Path is the active cast file. Rotated segments are stored next to it
+	// (see SegmentPath).
+	Path string
+
+	// Width and Height are the terminal size written to the header.
+	Width  int
+	Height int
+
+	// Title is an optional header title.
+	Title string
+
+	// MaxBytes caps the active file. When exceeded, the file is rotated
+	// and a new recording starts with a fresh header.
+	MaxBytes int64
+
+	// MaxSegments is the number of rotated files to keep.
+	MaxSegments int
+
+	// Clock supplies event timing. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// SegmentPath returns the path of the nth rotated segment of a cast file,
+// e.g. agent.cast -> agent.1.cast. Segment 0 is the active file.
+func SegmentPath(path string, n int) string {
+	if n <= 0 {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(path, ext), n, ext)
+}
+
+// Record reads terminal output from r until EOF and writes it to a cast
+// file, truncating any previous recording at opts.Path.
+func Record(r io.Reader, opts RecorderOptions) error {
+	if strings.TrimSpace(opts.Path) == "" {
+		return errors.New("recording path is required")
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if opts.MaxSegments < 0 {
+		opts.MaxSegments = 0
+	}
+	clk := clock.OrReal(opts.Clock)
+
+	if err := fsperm.MkdirAll(filepath.Dir(opts.Path)); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	rec := &recorder{opts: opts, clock: clk}
+	if err := rec.open(); err != nil {
+		return err
+	}
+	defer rec.close()
+
+	buf := make([]byte, readBufferSize)
+	var pending []byte
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			pending = append(pending, buf[:n]...)
+			complete := completeUTF8(pending)
+			if complete > 0 {
+				if err := rec.write(string(pending[:complete])); err != nil {
+					return err
+				}
+				pending = append(pending[:0], pending[complete:]...)
+			}
+		}
+		if readErr != nil {
+			if len(pending) > 0 {
+				if err := rec.write(string(pending)); err != nil {
+					return err
+				}
+			}
+			if errors.Is(readErr, io.EOF) {
+				return nil
+			}
+			return readErr
+		}
+	}
+}
+
+type recorder struct {
+	opts   RecorderOptions
+	clock  clock.Clock
+	file   *os.File
+	writer *Writer
+	start  time.Duration
+}
+
+func (r *recorder) open() error {
+	file, err := fsperm.OpenFile(r.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	writer, err := NewWriter(file, Header{
+		Width:     r.opts.Width,
+		Height:    r.opts.Height,
+		Timestamp: r.clock.Now().Unix(),
+		Title:     r.opts.Title,
+	})
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	r.file = file
+	r.writer = writer
+	r.start = r.clock.Monotonic()
+	return nil
+}
+
+func (r *recorder) close() {
+	if r.file != nil {
+		_ = r.file.Close()
+		r.file = nil
+	}
+}
+
+func (r *recorder) write(data string) error {
+	// Rotate lazily so the active file never ends up holding only a header.
+	if r.writer.Size() >= r.opts.MaxBytes {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	event := Event{Time: clock.Since(r.clock, r.start), Type: EventOutput, Data: data}
+	return r.writer.WriteEvent(event)
+}
+
+// rotate shifts the active file into the segment list, dropping the oldest
+// segment, and starts a new active file.
+func (r *recorder) rotate() error {
+	r.close()
+
+	if r.opts.MaxSegments == 0 {
+		return r.open()
+	}
+
+	_ = os.Remove(SegmentPath(r.opts.Path, r.opts.MaxSegments))
+	for n := r.opts.MaxSegments - 1; n >= 0; n-- {
+		from := SegmentPath(r.opts.Path, n)
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		if err := os.Rename(from, SegmentPath(r.opts.Path, n+1)); err != nil {
+			return fmt.Errorf("failed to rotate recording: %w", err)
+		}
+	}
+	return r.open()
+}
+
+// completeUTF8 returns the length of the longest prefix of data that does
+// not end in a truncated UTF-8 sequence, so multi-byte characters split
+// across reads are not mangled.
+func completeUTF8(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(data[i]) {
+			continue
+		}
+		if utf8.FullRune(data[i:]) {
+			return len(data)
+		}
+		return i
+	}
+	return len(data)
+}
@@ -0,0 +1,116 @@
+package recording
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+)
+
+// steppedReader returns one chunk per Read, advancing the clock before each.
+type steppedReader struct {
+	chunks []string
+	clock  *clock.Fake
+	step   time.Duration
+}
+
+func (r *steppedReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	r.clock.Advance(r.step)
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func readCastFile(t *testing.T, path string) *Cast {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer file.Close()
+	cast, err := ReadCast(file)
+	if err != nil {
+		t.Fatalf("ReadCast %s: %v", path, err)
+	}
+	return cast
+}
+
+func TestRecordTiming(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	path := filepath.Join(t.TempDir(), "agents", "a1.cast")
+
+	// The multi-byte rune is split across reads and must arrive whole.
+	reader := &steppedReader{chunks: []string{"hello ", "wor\xe2\x82", "\xac\r\n"}, clock: clk, step: 500 * time.Millisecond}
+	if err := Record(reader, RecorderOptions{Path: path, Width: 80, Height: 24, Clock: clk}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	cast := readCastFile(t, path)
+	if cast.Header.Timestamp != 1700000000 || cast.Header.Width != 80 {
+		t.Fatalf("unexpected header %+v", cast.Header)
+	}
+	want := []Event{
+		{Time: 500 * time.Millisecond, Type: EventOutput, Data: "hello "},
+		{Time: time.Second, Type: EventOutput, Data: "wor"},
+		{Time: 1500 * time.Millisecond, Type: EventOutput, Data: "€\r\n"},
+	}
+	if len(cast.Events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), cast.Events)
+	}
+	for i := range want {
+		if cast.Events[i] != want[i] {
+			t.Fatalf("event %d = %+v, want %+v", i, cast.Events[i], want[i])
+		}
+	}
+}
+
+func TestRecordRotatesAtSizeCap(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	path := filepath.Join(t.TempDir(), "a1.cast")
+
+	chunks := make([]string, 10)
+	for i := range chunks {
+		chunks[i] = strings.Repeat(string(rune('a'+i)), 40)
+	}
+	reader := &steppedReader{chunks: chunks, clock: clk, step: time.Second}
+	// Header (~50 bytes) plus two 60-byte events exceed the cap.
+	opts := RecorderOptions{Path: path, Width: 80, Height: 24, MaxBytes: 150, MaxSegments: 2, Clock: clk}
+	if err := Record(reader, opts); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	if _, err := os.Stat(SegmentPath(path, 3)); !os.IsNotExist(err) {
+		t.Fatalf("expected at most 2 rotated segments, stat err=%v", err)
+	}
+
+	// Newest data is in the active file; each segment holds the two
+	// chunks before it, with timing restarted when it was opened.
+	for n, first := range map[int]string{0: "i", 1: "g", 2: "e"} {
+		cast := readCastFile(t, SegmentPath(path, n))
+		if len(cast.Events) != 2 {
+			t.Fatalf("segment %d: expected 2 events, got %d", n, len(cast.Events))
+		}
+		if !strings.HasPrefix(cast.Events[0].Data, first) {
+			t.Fatalf("segment %d: expected first chunk %q, got %q", n, first, cast.Events[0].Data)
+		}
+		if cast.Events[0].Time != 0 || cast.Events[1].Time != time.Second {
+			t.Fatalf("segment %d: unexpected timing %+v", n, cast.Events)
+		}
+	}
+}
+
+func TestSegmentPath(t *testing.T) {
+	if got := SegmentPath("/data/a1.cast", 0); got != "/data/a1.cast" {
+		t.Fatalf("SegmentPath(0) = %s", got)
+	}
+	if got := SegmentPath("/data/a1.cast", 2); got != "/data/a1.2.cast" {
+		t.Fatalf("SegmentPath(2) = %s", got)
+	}
+}
@@ -0,0 +1,341 @@
+// Package redact scrubs secrets from agent output before it is persisted.
+//
+// A Redactor is compiled once at startup from the redaction config and then
+// shared by the daemon transcript path, the agent runner event stream, and
+// event payloads. Matches are replaced with a "[REDACTED:<label>]" marker so
+// the surrounding context stays readable.
+//
+// Secrets that Swarm itself resolves, such as account credentials, are
+// registered with RegisterSecret and replaced verbatim by every Redactor in
+// the process, whether or not they match a rule.
+//
+// Limitations: redaction operates on the text it is handed. Content is
+// redacted before it is truncated (for example the 4KB transcript tail), so a
+// secret that straddles the truncation boundary is still caught, and the
+// truncation helpers never cut through a marker. A secret that is split
+// across separate writes (a PTY chunk boundary mid-line, or a pane line that
+// tmux hard-wraps) cannot be matched and will be stored as-is.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opencode-ai/swarm/internal/config"
+)
+
+// markerPrefix starts every replacement marker.
+const markerPrefix = "[REDACTED:"
+
+// secretLabel is the marker label for registered secrets.
+const secretLabel = "credential"
+
+// minSecretLength is the shortest value RegisterSecret accepts; shorter
+// values would mangle unrelated output.
+const minSecretLength = 8
+
+// secretGroup is the named capture group that limits replacement to part of
+// a match. Patterns without it are replaced in full.
+const secretGroup = "secret"
+
+// Rule is a labeled secret pattern.
+type Rule struct {
+	// Label is used in the replacement marker.
+	Label string
+	// Pattern is the regular expression to match.
+	Pattern string
+}
+
+// DefaultRules returns the built-in secret patterns, in evaluation order.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Label: "anthropic_key", Pattern: `sk-ant-[A-Za-z0-9_-]{20,}`},
+		{Label: "openai_key", Pattern: `sk-(?:proj-)?[A-Za-z0-9_-]{20,}`},
+		{Label: "aws_access_key", Pattern: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+		{Label: "aws_secret_key", Pattern: `(?i)aws_secret_access_key["']?\s*[=:]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`},
+		{Label: "bearer_token", Pattern: `(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/=-]{16,})`},
+		{Label: "env_secret", Pattern: `\b[A-Z_][A-Z0-9_]*(?:KEY|TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIALS?)\s*=\s*["']?(?P<secret>[^\s"']{8,})`},
+	}
+}
+
+type compiledRule struct {
+	label    string
+	re       *regexp.Regexp
+	groupIdx int // index of the secret group, or 0 for the whole match
+}
+
+// Redactor replaces secret matches with labeled markers.
+// A nil Redactor is valid and leaves content unchanged.
+type Redactor struct {
+	rules []compiledRule
+}
+
+// New compiles the given rules into a Redactor.
+func New(rules []Rule) (*Redactor, error) {
+	r := &Redactor{rules: make([]compiledRule, 0, len(rules))}
+	for _, rule := range rules {
+		label := strings.TrimSpace(rule.Label)
+		if label == "" {
+			return nil, fmt.Errorf("redaction rule label is required")
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redaction rule %q: %w", label, err)
+		}
+		groupIdx := re.SubexpIndex(secretGroup)
+		if groupIdx < 0 {
+			groupIdx = 0
+		}
+		r.rules = append(r.rules, compiledRule{label: label, re: re, groupIdx: groupIdx})
+	}
+	return r, nil
+}
+
+// FromConfig builds a Redactor from the redaction config section.
+// Disabled default labels are skipped and custom patterns are appended after
+// the defaults. When redaction is disabled, the returned Redactor is a no-op.
+func FromConfig(cfg config.RedactionConfig) (*Redactor, error) {
+	if !cfg.Enabled {
+		return &Redactor{}, nil
+	}
+
+	disabled := make(map[string]bool, len(cfg.DisabledPatterns))
+	for _, label := range cfg.DisabledPatterns {
+		disabled[strings.TrimSpace(label)] = true
+	}
+
+	var rules []Rule
+	for _, rule := range DefaultRules() {
+		if !disabled[rule.Label] {
+			rules = append(rules, rule)
+		}
+	}
+	for _, pattern := range cfg.Patterns {
+		if pattern.Disabled || disabled[pattern.Label] {
+			continue
+		}
+		rules = append(rules, Rule{Label: pattern.Label, Pattern: pattern.Pattern})
+	}
+
+	return New(rules)
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultRedactor *Redactor
+)
+
+// Default returns a shared Redactor built from DefaultRules.
+func Default() *Redactor {
+	defaultOnce.Do(func() {
+		r, err := New(DefaultRules())
+		if err != nil {
+			panic(fmt.Sprintf("redact: invalid default rules: %v", err))
+		}
+		defaultRedactor = r
+	})
+	return defaultRedactor
+}
+
+var (
+	secretsMu sync.RWMutex
+	secrets   = make(map[string]struct{})
+)
+
+// RegisterSecret records a resolved secret so that it is redacted from all
+// content passed through a Redactor, regardless of the configured rules.
+// Values shorter than eight bytes are ignored.
+func RegisterSecret(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minSecretLength {
+		return
+	}
+	secretsMu.Lock()
+	secrets[value] = struct{}{}
+	secretsMu.Unlock()
+}
+
+// redactSecrets replaces every registered secret in s, longest first so a
+// secret that contains another is replaced whole.
+func redactSecrets(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	if len(secrets) == 0 {
+		return s
+	}
+	values := make([]string, 0, len(secrets))
+	for value := range secrets {
+		if strings.Contains(s, value) {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		s = strings.ReplaceAll(s, value, Marker(secretLabel))
+	}
+	return s
+}
+
+// Marker returns the replacement marker for a label.
+func Marker(label string) string {
+	return markerPrefix + label + "]"
+}
+
+// Labels returns the labels of the active rules, in evaluation order.
+func (r *Redactor) Labels() []string {
+	if r == nil {
+		return nil
+	}
+	labels := make([]string, len(r.rules))
+	for i, rule := range r.rules {
+		labels[i] = rule.label
+	}
+	return labels
+}
+
+// Redact replaces all secret matches in s.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	s = redactSecrets(s)
+	for _, rule := range r.rules {
+		s = rule.apply(s)
+	}
+	return s
+}
+
+// RedactLines redacts each line in place and returns the slice.
+func (r *Redactor) RedactLines(lines []string) []string {
+	if r == nil {
+		return lines
+	}
+	for i, line := range lines {
+		lines[i] = r.Redact(line)
+	}
+	return lines
+}
+
+// RedactMap returns a copy of m with every value redacted.
+func (r *Redactor) RedactMap(m map[string]string) map[string]string {
+	if r == nil || m == nil {
+		return m
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = r.Redact(v)
+	}
+	return out
+}
+
+// RedactJSON redacts every string value in a JSON document and returns the
+// re-encoded document. Object keys are left as they are. Redacting the
+// decoded strings rather than the raw text keeps escape sequences intact
+// and catches secrets that only match once unescaped.
+func (r *Redactor) RedactJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(r.redactValue(value))
+}
+
+func (r *Redactor) redactValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return r.Redact(v)
+	case []any:
+		for i, item := range v {
+			v[i] = r.redactValue(item)
+		}
+	case map[string]any:
+		for key, item := range v {
+			v[key] = r.redactValue(item)
+		}
+	}
+	return value
+}
+
+func (c compiledRule) apply(s string) string {
+	matches := c.re.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	marker := Marker(c.label)
+	var b strings.Builder
+	b.Grow(len(s))
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*c.groupIdx], m[2*c.groupIdx+1]
+		if start < 0 || start < last {
+			continue
+		}
+		// Skip values that an earlier rule already replaced.
+		if strings.HasPrefix(s[start:], markerPrefix) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(marker)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// TruncateTail keeps at most max trailing bytes of s. If the cut would land
+// inside a redaction marker, the partial marker is dropped as well.
+func TruncateTail(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	cut := len(s) - max
+	if _, end, ok := markerAt(s, cut); ok {
+		cut = end
+	}
+	return s[cut:]
+}
+
+// TruncateHead keeps at most max leading bytes of s. If the cut would land
+// inside a redaction marker, the partial marker is dropped as well.
+func TruncateHead(s string, max int) (string, bool) {
+	if max <= 0 || len(s) <= max {
+		return s, false
+	}
+	cut := max
+	if start, _, ok := markerAt(s, cut); ok {
+		cut = start
+	}
+	return s[:cut], true
+}
+
+// markerAt reports the bounds of a marker that strictly contains offset.
+func markerAt(s string, offset int) (start, end int, ok bool) {
+	limit := offset + len(markerPrefix) - 1
+	if limit > len(s) {
+		limit = len(s)
+	}
+	start = strings.LastIndex(s[:limit], markerPrefix)
+	if start >= offset {
+		start = strings.LastIndex(s[:offset], markerPrefix)
+	}
+	if start < 0 {
+		return 0, 0, false
+	}
+	closeIdx := strings.IndexByte(s[start:], ']')
+	if closeIdx < 0 {
+		return 0, 0, false
+	}
+	end = start + closeIdx + 1
+	if end <= offset {
+		return 0, 0, false
+	}
+	return start, end, true
+}
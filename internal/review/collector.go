@@ -0,0 +1,189 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/redact"
+	"github.com/opencode-ai/swarm/internal/state"
+	"github.com/rs/zerolog"
+)
+
+// maxRawOutputBytes is how much of an agent's answer is stored with its
+// review.
+const maxRawOutputBytes = 32 << 10
+
+// PaneClient captures agent panes. *tmux.Client satisfies it.
+type PaneClient interface {
+	CapturePane(ctx context.Context, target string, history bool) (string, error)
+}
+
+// Collector records the verdicts of reviews as the reviewing agents
+// finish answering.
+type Collector struct {
+	repo      *db.ReviewRepository
+	agentRepo *db.AgentRepository
+	panes     PaneClient
+	publisher events.Publisher
+	redactor  *redact.Redactor
+	logger    zerolog.Logger
+	now       func() time.Time
+}
+
+// CollectorOption configures a Collector.
+type CollectorOption func(*Collector)
+
+// WithPublisher sets the publisher for review.completed events.
+func WithPublisher(publisher events.Publisher) CollectorOption {
+	return func(c *Collector) {
+		c.publisher = publisher
+	}
+}
+
+// WithRedactor sets the redactor applied to stored answers.
+func WithRedactor(redactor *redact.Redactor) CollectorOption {
+	return func(c *Collector) {
+		c.redactor = redactor
+	}
+}
+
+// NewCollector creates a Collector reading answers through panes.
+func NewCollector(repo *db.ReviewRepository, agentRepo *db.AgentRepository, panes PaneClient, opts ...CollectorOption) *Collector {
+	c := &Collector{
+		repo:      repo,
+		agentRepo: agentRepo,
+		panes:     panes,
+		redactor:  redact.Default(),
+		logger:    logging.Component("review"),
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// OnStateChange implements state.Subscriber.
+func (c *Collector) OnStateChange(change state.StateChange) {
+	if _, err := c.HandleStateChange(context.Background(), change); err != nil {
+		c.logger.Warn().Err(err).Str("agent_id", change.AgentID).Msg("failed to record review verdict")
+	}
+}
+
+// HandleStateChange records the verdict of the review an agent was sent
+// once the agent goes idle again. It returns nil when the change is not a
+// return to idle or the agent has no review awaiting a verdict.
+func (c *Collector) HandleStateChange(ctx context.Context, change state.StateChange) (*models.Review, error) {
+	if change.CurrentState != models.AgentStateIdle || change.PreviousState == models.AgentStateIdle {
+		return nil, nil
+	}
+
+	review, err := c.repo.FindAwaitingVerdict(ctx, change.AgentID)
+	if err != nil {
+		if errors.Is(err, db.ErrReviewNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// The agent was idle when the review was sent; a change detected
+	// before then is the end of its previous task.
+	if review.DispatchedAt != nil && !change.Timestamp.IsZero() && change.Timestamp.Before(*review.DispatchedAt) {
+		return nil, nil
+	}
+
+	agent, err := c.agentRepo.Get(ctx, change.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent: %w", err)
+	}
+	if agent.TmuxPane == "" {
+		return nil, fmt.Errorf("agent %s has no tmux pane", agent.ID)
+	}
+	output, err := c.panes.CapturePane(ctx, agent.TmuxPane, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture pane: %w", err)
+	}
+	return c.Record(ctx, review, output)
+}
+
+// Record parses the agent's answer from captured pane output and stores
+// it with the review. An answer without a readable verdict is stored as
+// unparsed with the reason, so it can still be read with review show.
+func (c *Collector) Record(ctx context.Context, review *models.Review, output string) (*models.Review, error) {
+	answer := c.redactor.Redact(Answer(output, review.ID))
+	now := c.now()
+
+	review.CompletedAt = &now
+	review.RawOutput = tail(answer, maxRawOutputBytes)
+	verdict, err := ParseVerdict(answer)
+	if err != nil {
+		review.Status = models.ReviewStatusUnparsed
+		review.Error = err.Error()
+		review.Verdict = ""
+		review.Summary = ""
+		review.Comments = nil
+	} else {
+		review.Status = models.ReviewStatusCompleted
+		review.Error = ""
+		review.Verdict = verdict.Verdict
+		review.Summary = verdict.Summary
+		review.Comments = verdict.Comments
+	}
+
+	if err := c.repo.Update(ctx, review); err != nil {
+		return nil, fmt.Errorf("failed to store review verdict: %w", err)
+	}
+
+	c.logger.Info().
+		Str("review_id", review.ID).
+		Str("agent_id", review.AgentID).
+		Str("status", string(review.Status)).
+		Str("verdict", string(review.Verdict)).
+		Msg("review answered")
+	c.publish(ctx, review)
+	return review, nil
+}
+
+func (c *Collector) publish(ctx context.Context, review *models.Review) {
+	if c.publisher == nil {
+		return
+	}
+	data, err := json.Marshal(models.ReviewCompletedPayload{
+		ReviewID:    review.ID,
+		QueueItemID: review.QueueItemID,
+		AgentID:     review.AgentID,
+		Status:      review.Status,
+		Verdict:     review.Verdict,
+		Comments:    len(review.Comments),
+	})
+	if err != nil {
+		c.logger.Warn().Err(err).Msg("failed to marshal review payload")
+		return
+	}
+	c.publisher.Publish(ctx, &models.Event{
+		Type:       models.EventTypeReviewCompleted,
+		EntityType: models.EntityTypeAgent,
+		EntityID:   review.AgentID,
+		Payload:    data,
+	})
+}
+
+// tail keeps the last limit bytes of s, where the verdict is, without
+// splitting a rune.
+func tail(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	start := len(s) - limit
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return s[start:]
+}
@@ -0,0 +1,222 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+)
+
+type fakePanes struct {
+	content  string
+	captures int
+}
+
+func (f *fakePanes) CapturePane(ctx context.Context, target string, history bool) (string, error) {
+	f.captures++
+	return f.content, nil
+}
+
+type collectorTestEnv struct {
+	repo     *db.ReviewRepository
+	agents   *db.AgentRepository
+	agent    *models.Agent
+	review   *models.Review
+	panes    *fakePanes
+	mu       sync.Mutex
+	events   []*models.Event
+	database *db.DB
+}
+
+func newCollectorTestEnv(t *testing.T) *collectorTestEnv {
+	t.Helper()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "alpha", RepoPath: "/tmp/alpha", TmuxSession: "alpha"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agent := &models.Agent{
+		WorkspaceID: ws.ID,
+		Type:        models.AgentTypeClaudeCode,
+		TmuxPane:    "alpha:0.1",
+		State:       models.AgentStateIdle,
+		StateInfo:   models.StateInfo{State: models.AgentStateIdle, Confidence: models.StateConfidenceHigh},
+	}
+	agents := db.NewAgentRepository(database)
+	if err := agents.Create(ctx, agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	repo := db.NewReviewRepository(database)
+	dispatched := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	review := &models.Review{
+		QueueItemID:  "item-1",
+		AgentID:      agent.ID,
+		Range:        "main..HEAD",
+		Status:       models.ReviewStatusAwaitingVerdict,
+		DispatchedAt: &dispatched,
+	}
+	if err := repo.Create(ctx, review); err != nil {
+		t.Fatalf("failed to create review: %v", err)
+	}
+
+	return &collectorTestEnv{repo: repo, agents: agents, agent: agent, review: review, panes: &fakePanes{}, database: database}
+}
+
+func (e *collectorTestEnv) collector(t *testing.T) *Collector {
+	t.Helper()
+	publisher := events.NewInMemoryPublisher()
+	if err := publisher.Subscribe("test", events.Filter{}, func(event *models.Event) {
+		e.mu.Lock()
+		e.events = append(e.events, event)
+		e.mu.Unlock()
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	return NewCollector(e.repo, e.agents, e.panes, WithPublisher(publisher))
+}
+
+func (e *collectorTestEnv) idleChange(at time.Time) state.StateChange {
+	return state.StateChange{
+		AgentID:       e.agent.ID,
+		PreviousState: models.AgentStateWorking,
+		CurrentState:  models.AgentStateIdle,
+		Timestamp:     at,
+	}
+}
+
+func (e *collectorTestEnv) pane(answer string) {
+	e.panes.content = "> Please review the following diff\n" + EndMarker(e.review.ID) + "\n\n" + answer + "\n"
+}
+
+func TestHandleStateChangeRecordsVerdict(t *testing.T) {
+	env := newCollectorTestEnv(t)
+	collector := env.collector(t)
+	ctx := context.Background()
+	env.pane("Two issues.\n```json\n{\"verdict\": \"changes\", \"summary\": \"needs work\", \"comments\": [{\"path\": \"a.go\", \"line\": 3, \"body\": \"leaks\"}, \"add tests\"]}\n```")
+
+	recorded, err := collector.HandleStateChange(ctx, env.idleChange(env.review.DispatchedAt.Add(time.Minute)))
+	if err != nil {
+		t.Fatalf("HandleStateChange failed: %v", err)
+	}
+	if recorded == nil {
+		t.Fatal("expected the review to be recorded")
+	}
+
+	stored, err := env.repo.Get(ctx, env.review.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.Status != models.ReviewStatusCompleted || stored.Verdict != models.ReviewVerdictChanges || stored.Summary != "needs work" {
+		t.Fatalf("unexpected review %+v", stored)
+	}
+	if len(stored.Comments) != 2 || stored.Comments[0].Path != "a.go" || stored.Comments[0].Line != 3 || stored.CompletedAt == nil {
+		t.Fatalf("unexpected comments %+v", stored.Comments)
+	}
+	if strings.Contains(stored.RawOutput, "Please review") {
+		t.Fatalf("expected only the answer stored, got %q", stored.RawOutput)
+	}
+
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	if len(env.events) != 1 || env.events[0].Type != models.EventTypeReviewCompleted || env.events[0].EntityID != env.agent.ID {
+		t.Fatalf("expected a review.completed event, got %+v", env.events)
+	}
+
+	// The review is finished; the next idle change leaves it alone.
+	if again, err := collector.HandleStateChange(ctx, env.idleChange(time.Now())); err != nil || again != nil {
+		t.Fatalf("expected no review awaiting a verdict, got %+v, %v", again, err)
+	}
+}
+
+func TestRecordStoresMalformedVerdictAsUnparsed(t *testing.T) {
+	env := newCollectorTestEnv(t)
+	collector := env.collector(t)
+	ctx := context.Background()
+
+	answer := "I think this is fine overall.\n```json\n{\"verdict\": \"approve\", \"summary\": \"cut off mid"
+	env.pane(answer)
+	recorded, err := collector.HandleStateChange(ctx, env.idleChange(time.Time{}))
+	if err != nil {
+		t.Fatalf("HandleStateChange failed: %v", err)
+	}
+	if recorded == nil {
+		t.Fatal("expected the review to be recorded")
+	}
+
+	stored, err := env.repo.Get(ctx, env.review.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.Status != models.ReviewStatusUnparsed || stored.Verdict != "" || stored.RawOutput != answer {
+		t.Fatalf("expected an unparsed review keeping the raw answer, got %+v", stored)
+	}
+	if !strings.Contains(stored.Error, ErrMalformedVerdict.Error()) {
+		t.Fatalf("expected the parse error stored, got %q", stored.Error)
+	}
+
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	if len(env.events) != 1 || env.events[0].Type != models.EventTypeReviewCompleted {
+		t.Fatalf("expected a review.completed event for an unparsed answer, got %+v", env.events)
+	}
+}
+
+func TestHandleStateChangeIgnoresOtherChanges(t *testing.T) {
+	env := newCollectorTestEnv(t)
+	collector := env.collector(t)
+	ctx := context.Background()
+	env.pane(`{"verdict": "approve"}`)
+
+	working := env.idleChange(time.Now())
+	working.CurrentState = models.AgentStateWorking
+	stale := env.idleChange(env.review.DispatchedAt.Add(-time.Second))
+	stillIdle := env.idleChange(time.Now())
+	stillIdle.PreviousState = models.AgentStateIdle
+
+	for _, change := range []state.StateChange{working, stale, stillIdle} {
+		if recorded, err := collector.HandleStateChange(ctx, change); err != nil || recorded != nil {
+			t.Fatalf("expected %s -> %s to be ignored, got %+v, %v", change.PreviousState, change.CurrentState, recorded, err)
+		}
+	}
+	if env.panes.captures != 0 {
+		t.Fatalf("expected no pane captures, got %d", env.panes.captures)
+	}
+	stored, err := env.repo.Get(ctx, env.review.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.Status != models.ReviewStatusAwaitingVerdict {
+		t.Fatalf("expected the review still awaiting a verdict, got %s", stored.Status)
+	}
+}
+
+func TestTail(t *testing.T) {
+	if got := tail("héllo", 4); got != "llo" {
+		t.Fatalf("expected the tail cut at a rune start, got %q", got)
+	}
+	if got := tail("short", 10); got != "short" {
+		t.Fatalf("expected a short string kept, got %q", got)
+	}
+}
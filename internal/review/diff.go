@@ -0,0 +1,236 @@
+// Package review presents diffs to agents for review queue items and
+// turns the agents' answers into recorded verdicts.
+package review
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/shell"
+)
+
+// FileDiff is the part of a diff that changes one file.
+type FileDiff struct {
+	// Path is the file's path in the repo, or "" if the diff names none.
+	Path string
+
+	// Text is the file's diff, headers included.
+	Text string
+}
+
+// Diff is the diff a review sends to the agent.
+type Diff struct {
+	// Text is the diff as sent.
+	Text string
+
+	// Files lists the files included in Text.
+	Files []string
+
+	// Omitted lists the files left out to respect the size cap.
+	Omitted []string
+
+	// Bytes is the size of the filtered diff before it was capped.
+	Bytes int
+
+	// Truncated reports whether anything was left out or cut.
+	Truncated bool
+}
+
+// Command returns the shell command that prints the diff a review payload
+// refers to: git diff of its range, or its patch file, from repoPath.
+func Command(repoPath string, payload *models.ReviewPayload) string {
+	if payload.PatchFile != "" {
+		return fmt.Sprintf("cd %s && cat -- %s", shell.Quote(repoPath), shell.Quote(payload.PatchFile))
+	}
+	return fmt.Sprintf("cd %s && git --no-pager diff --no-color --no-ext-diff %s --", shell.Quote(repoPath), shell.Quote(payload.Range))
+}
+
+// Build splits output into files, keeps those matching the payload's path
+// filters, and caps the result at maxBytes (no cap if maxBytes <= 0).
+func Build(output string, payload *models.ReviewPayload, maxBytes int) *Diff {
+	files := Filter(SplitFiles(output), payload.Paths, payload.Exclude)
+	return capFiles(files, maxBytes)
+}
+
+// SplitFiles splits a git diff or a plain unified diff into its files.
+// Text before the first file, such as a commit message in a format-patch
+// file, stays with the first file.
+func SplitFiles(diff string) []FileDiff {
+	if strings.TrimSpace(diff) == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(diff, "\n")
+	git := strings.HasPrefix(diff, "diff --git ") || strings.Contains(diff, "\ndiff --git ")
+
+	var starts []int
+	for i, line := range lines {
+		switch {
+		case git:
+			if strings.HasPrefix(line, "diff --git ") {
+				starts = append(starts, i)
+			}
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			starts = append(starts, i)
+		}
+	}
+	if len(starts) == 0 {
+		return []FileDiff{{Text: diff}}
+	}
+
+	files := make([]FileDiff, 0, len(starts))
+	for n, start := range starts {
+		from, to := start, len(lines)
+		if n == 0 {
+			from = 0
+		}
+		if n+1 < len(starts) {
+			to = starts[n+1]
+		}
+		files = append(files, FileDiff{
+			Path: diffPath(lines[start:to]),
+			Text: strings.Join(lines[from:to], ""),
+		})
+	}
+	return files
+}
+
+// diffPath finds the path of the file a diff section changes: the new
+// name, or the old one for deletions.
+func diffPath(lines []string) string {
+	var header, old string
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			header = line
+		case strings.HasPrefix(line, "--- "):
+			old = cutPath(strings.TrimPrefix(line, "--- "))
+		case strings.HasPrefix(line, "+++ "):
+			if p := cutPath(strings.TrimPrefix(line, "+++ ")); p != "" {
+				return p
+			}
+			return old
+		case strings.HasPrefix(line, "@@"):
+			// Hunks follow the headers; stop before reading diff content.
+			return old
+		}
+	}
+	if old != "" {
+		return old
+	}
+	if i := strings.LastIndex(header, " b/"); i >= 0 {
+		return header[i+len(" b/"):]
+	}
+	return ""
+}
+
+// cutPath strips the a/ or b/ prefix and any trailing timestamp from a
+// ---/+++ file name. /dev/null yields "".
+func cutPath(name string) string {
+	name, _, _ = strings.Cut(name, "\t")
+	if name == "/dev/null" {
+		return ""
+	}
+	if rest, ok := strings.CutPrefix(name, "a/"); ok {
+		return rest
+	}
+	if rest, ok := strings.CutPrefix(name, "b/"); ok {
+		return rest
+	}
+	return name
+}
+
+// Filter keeps the files matching any of include (all files when include
+// is empty) and none of exclude. See matchPath for the pattern syntax.
+// Files whose path is unknown are always kept.
+func Filter(files []FileDiff, include, exclude []string) []FileDiff {
+	if len(include) == 0 && len(exclude) == 0 {
+		return files
+	}
+	kept := make([]FileDiff, 0, len(files))
+	for _, file := range files {
+		if file.Path != "" {
+			if len(include) > 0 && !matchAny(include, file.Path) {
+				continue
+			}
+			if matchAny(exclude, file.Path) {
+				continue
+			}
+		}
+		kept = append(kept, file)
+	}
+	return kept
+}
+
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if matchPath(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath reports whether p matches pattern: a path.Match glob against
+// the whole path, a glob without "/" against the file name, or a
+// directory ("internal/db" or "internal/db/") containing the file.
+func matchPath(pattern, p string) bool {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+	if pattern == "" {
+		return false
+	}
+	if dir := strings.TrimSuffix(pattern, "/"); strings.HasPrefix(p, dir+"/") {
+		return true
+	}
+	if ok, _ := path.Match(pattern, p); ok {
+		return true
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(p))
+		return ok
+	}
+	return false
+}
+
+// capFiles joins files up to maxBytes. Files are kept whole while they
+// fit; the rest are listed as omitted. A first file larger than the cap
+// is cut at the last line end that fits so the agent still sees part of
+// the change.
+func capFiles(files []FileDiff, maxBytes int) *Diff {
+	diff := &Diff{}
+	for _, file := range files {
+		diff.Bytes += len(file.Text)
+	}
+
+	var b strings.Builder
+	for i, file := range files {
+		if maxBytes > 0 && b.Len()+len(file.Text) > maxBytes {
+			if i == 0 {
+				cut := file.Text[:maxBytes]
+				if end := strings.LastIndexByte(cut, '\n'); end >= 0 {
+					cut = cut[:end+1]
+				}
+				b.WriteString(cut)
+				diff.Files = append(diff.Files, fileLabel(file))
+				diff.Truncated = true
+				continue
+			}
+			diff.Omitted = append(diff.Omitted, fileLabel(file))
+			diff.Truncated = true
+			continue
+		}
+		b.WriteString(file.Text)
+		diff.Files = append(diff.Files, fileLabel(file))
+	}
+	diff.Text = b.String()
+	return diff
+}
+
+func fileLabel(file FileDiff) string {
+	if file.Path == "" {
+		return "(unnamed)"
+	}
+	return file.Path
+}
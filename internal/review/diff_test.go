@@ -0,0 +1,141 @@
+package review
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+const gitDiff = `diff --git a/internal/db/repo.go b/internal/db/repo.go
+index 1111111..2222222 100644
+--- a/internal/db/repo.go
++++ b/internal/db/repo.go
+@@ -1,3 +1,3 @@
+-old
++new
+diff --git a/internal/db/repo_test.go b/internal/db/repo_test.go
+index 3333333..4444444 100644
+--- a/internal/db/repo_test.go
++++ b/internal/db/repo_test.go
+@@ -1 +1 @@
+-a
++b
+diff --git a/docs/gone.md b/docs/gone.md
+deleted file mode 100644
+index 5555555..0000000
+--- a/docs/gone.md
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+
+func paths(files []FileDiff) []string {
+	out := make([]string, 0, len(files))
+	for _, file := range files {
+		out = append(out, file.Path)
+	}
+	return out
+}
+
+func TestSplitFiles(t *testing.T) {
+	files := SplitFiles(gitDiff)
+	if got, want := paths(files), []string{"internal/db/repo.go", "internal/db/repo_test.go", "docs/gone.md"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("paths = %v, want %v", got, want)
+	}
+	var joined strings.Builder
+	for _, file := range files {
+		joined.WriteString(file.Text)
+	}
+	if joined.String() != gitDiff {
+		t.Fatal("expected the files to add up to the whole diff")
+	}
+
+	patch := "Subject: fix it\n\n--- old/main.go\t2026-01-01\n+++ new/main.go\t2026-01-02\n@@ -1 +1 @@\n-x\n+y\n--- a/README\n+++ b/README\n@@ -1 +1 @@\n-- list item\n+- item\n"
+	files = SplitFiles(patch)
+	if got, want := paths(files), []string{"new/main.go", "README"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("plain patch paths = %v, want %v", got, want)
+	}
+	if !strings.HasPrefix(files[0].Text, "Subject: fix it") {
+		t.Fatalf("expected the preamble to stay with the first file, got %q", files[0].Text)
+	}
+
+	if files := SplitFiles("  \n"); files != nil {
+		t.Fatalf("expected no files for an empty diff, got %+v", files)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	files := SplitFiles(gitDiff)
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{"no filters", nil, nil, []string{"internal/db/repo.go", "internal/db/repo_test.go", "docs/gone.md"}},
+		{"directory", []string{"internal/db/"}, nil, []string{"internal/db/repo.go", "internal/db/repo_test.go"}},
+		{"basename glob", nil, []string{"*_test.go"}, []string{"internal/db/repo.go", "docs/gone.md"}},
+		{"full path glob", []string{"docs/*.md"}, nil, []string{"docs/gone.md"}},
+		{"include and exclude", []string{"./internal"}, []string{"internal/db/repo.go"}, []string{"internal/db/repo_test.go"}},
+		{"no match", []string{"cmd"}, nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paths(Filter(files, tt.include, tt.exclude)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Filter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCapsDiff(t *testing.T) {
+	files := SplitFiles(gitDiff)
+	first, second := len(files[0].Text), len(files[1].Text)
+	payload := &models.ReviewPayload{Range: "main..HEAD"}
+
+	diff := Build(gitDiff, payload, 0)
+	if diff.Truncated || diff.Text != gitDiff || diff.Bytes != len(gitDiff) {
+		t.Fatalf("expected no cap without a limit, got %+v", diff)
+	}
+
+	// Files are kept whole; a later file that does not fit is omitted
+	// even when a smaller one after it would.
+	diff = Build(gitDiff, payload, first+second-1)
+	if !diff.Truncated || diff.Bytes != len(gitDiff) {
+		t.Fatalf("expected a truncated diff, got %+v", diff)
+	}
+	if !reflect.DeepEqual(diff.Files, []string{"internal/db/repo.go", "docs/gone.md"}) ||
+		!reflect.DeepEqual(diff.Omitted, []string{"internal/db/repo_test.go"}) {
+		t.Fatalf("unexpected files %v, omitted %v", diff.Files, diff.Omitted)
+	}
+	if len(diff.Text) > first+second-1 || strings.Contains(diff.Text, "repo_test.go") {
+		t.Fatalf("expected the omitted file cut from the text, got %q", diff.Text)
+	}
+
+	// A first file over the cap is cut at a line end.
+	diff = Build(gitDiff, payload, 60)
+	if !diff.Truncated || len(diff.Text) > 60 || !strings.HasSuffix(diff.Text, "\n") {
+		t.Fatalf("expected the first file cut at a line end, got %q", diff.Text)
+	}
+	if diff.Files[0] != "internal/db/repo.go" || len(diff.Omitted) != 2 {
+		t.Fatalf("unexpected files %v, omitted %v", diff.Files, diff.Omitted)
+	}
+
+	diff = Build(gitDiff, &models.ReviewPayload{Range: "main..HEAD", Exclude: []string{"docs"}}, 0)
+	if diff.Bytes != first+second || len(diff.Files) != 2 {
+		t.Fatalf("expected filtered bytes to be counted, got %+v", diff)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	got := Command("/srv/it's", &models.ReviewPayload{Range: "main..HEAD"})
+	if want := `cd '/srv/it'\''s' && git --no-pager diff --no-color --no-ext-diff 'main..HEAD' --`; got != want {
+		t.Fatalf("Command = %s, want %s", got, want)
+	}
+	got = Command("/srv/repo", &models.ReviewPayload{PatchFile: "../fix.patch"})
+	if want := `cd '/srv/repo' && cat -- '../fix.patch'`; got != want {
+		t.Fatalf("Command = %s, want %s", got, want)
+	}
+}
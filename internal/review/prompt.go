@@ -0,0 +1,78 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultPromptTemplate is the review prompt used when the config sets no
+// review.prompt_template.
+const DefaultPromptTemplate = `Please review the following diff of {{.Source}} ({{len .Files}} files).
+{{- if .Truncated}}
+The diff was cut to fit{{if .Omitted}}; these files are not shown:{{range .Omitted}} {{.}}{{end}}{{end}}.
+{{- end}}
+{{- if .Instructions}}
+
+{{.Instructions}}
+{{- end}}
+
+` + "```diff" + `
+{{.Diff}}
+` + "```" + `
+
+When you are done, end your answer with your verdict as a fenced JSON block
+with "verdict" set to "approve" or "changes", an optional "summary", and
+"comments" listing objects with "path", "line", and "body".`
+
+// PromptData is what a review prompt template sees.
+type PromptData struct {
+	// ReviewID identifies the review.
+	ReviewID string
+
+	// Source is the range or patch file under review.
+	Source string
+
+	// Files lists the files in Diff.
+	Files []string
+
+	// Omitted lists files left out of Diff by the size cap.
+	Omitted []string
+
+	// Truncated reports whether Diff was capped.
+	Truncated bool
+
+	// Diff is the diff text.
+	Diff string
+
+	// Instructions are the review item's extra instructions.
+	Instructions string
+}
+
+// RenderPrompt renders the review prompt from tmpl, or from
+// DefaultPromptTemplate when tmpl is blank, and ends it with the review's
+// end marker so the answer can be told apart from the prompt.
+func RenderPrompt(tmpl string, data PromptData) (string, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		tmpl = DefaultPromptTemplate
+	}
+	parsed, err := template.New("review").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid review prompt template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := parsed.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render review prompt: %w", err)
+	}
+	prompt := strings.TrimRight(b.String(), "\n")
+	return prompt + "\n\n" + EndMarker(data.ReviewID), nil
+}
+
+// EndMarker is the last line of a review prompt.
+func EndMarker(reviewID string) string {
+	if len(reviewID) > 8 {
+		reviewID = reviewID[:8]
+	}
+	return "[end of swarm review " + reviewID + "]"
+}
@@ -0,0 +1,173 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Verdict parsing errors.
+var (
+	ErrNoVerdict        = errors.New("no verdict found in the agent's answer")
+	ErrMalformedVerdict = errors.New("malformed verdict")
+)
+
+// Verdict is the decision an agent gave on a diff.
+type Verdict struct {
+	Verdict  models.ReviewVerdict
+	Summary  string
+	Comments []models.ReviewComment
+}
+
+// Answer returns the part of captured pane output that follows the
+// review's prompt. Output without the prompt's end marker, such as a pane
+// that scrolled past it, is returned whole.
+func Answer(output, reviewID string) string {
+	marker := EndMarker(reviewID)
+	if i := strings.LastIndex(output, marker); i >= 0 {
+		output = output[i+len(marker):]
+	}
+	return strings.TrimSpace(output)
+}
+
+var (
+	fencedBlock   = regexp.MustCompile("(?s)```[A-Za-z0-9_-]*[ \t]*\r?\n(.*?)```")
+	trailingComma = regexp.MustCompile(`,(\s*[}\]])`)
+)
+
+// ParseVerdict finds the verdict in an agent's answer: the last fenced
+// block mentioning "verdict", or failing that the last bare JSON object
+// that does. Line breaks from pane wrapping and trailing commas are
+// tolerated, as are common spellings of the two verdicts. It returns
+// ErrNoVerdict if there is nothing to parse and ErrMalformedVerdict if
+// the block cannot be read.
+func ParseVerdict(answer string) (*Verdict, error) {
+	candidate := ""
+	for _, match := range fencedBlock.FindAllStringSubmatch(answer, -1) {
+		if strings.Contains(strings.ToLower(match[1]), `"verdict"`) {
+			candidate = match[1]
+		}
+	}
+	if candidate == "" {
+		i := strings.LastIndex(strings.ToLower(answer), `"verdict"`)
+		if i < 0 {
+			return nil, ErrNoVerdict
+		}
+		start := strings.LastIndex(answer[:i], "{")
+		if start < 0 {
+			return nil, fmt.Errorf("%w: no JSON object around the verdict", ErrMalformedVerdict)
+		}
+		candidate = answer[start:]
+	}
+
+	raw, err := decodeRawVerdict(candidate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedVerdict, err)
+	}
+	verdict, ok := normalizeVerdict(raw.Verdict)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown verdict %q (want approve or changes)", ErrMalformedVerdict, raw.Verdict)
+	}
+
+	result := &Verdict{Verdict: verdict, Summary: strings.TrimSpace(raw.Summary)}
+	for _, comment := range raw.Comments {
+		if parsed, ok := parseComment(comment); ok {
+			result.Comments = append(result.Comments, parsed)
+		}
+	}
+	return result, nil
+}
+
+type rawVerdict struct {
+	Verdict  string            `json:"verdict"`
+	Summary  string            `json:"summary"`
+	Comments []json.RawMessage `json:"comments"`
+}
+
+// decodeRawVerdict decodes the first JSON object in text, retrying with
+// wrapped lines joined and trailing commas dropped.
+func decodeRawVerdict(text string) (rawVerdict, error) {
+	text = strings.TrimSpace(text)
+	joined := strings.Join(strings.Fields(strings.ReplaceAll(text, "\r", "")), " ")
+	unwrapped := strings.ReplaceAll(strings.ReplaceAll(text, "\r", ""), "\n", "")
+
+	var firstErr error
+	for _, attempt := range []string{text, unwrapped, joined} {
+		for _, candidate := range []string{attempt, trailingComma.ReplaceAllString(attempt, "$1")} {
+			var raw rawVerdict
+			err := json.NewDecoder(strings.NewReader(candidate)).Decode(&raw)
+			if err == nil {
+				return raw, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return rawVerdict{}, firstErr
+}
+
+func normalizeVerdict(verdict string) (models.ReviewVerdict, bool) {
+	switch strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(strings.TrimSpace(verdict))) {
+	case "approve", "approved", "accept", "accepted", "lgtm":
+		return models.ReviewVerdictApprove, true
+	case "changes", "request_changes", "changes_requested", "reject", "rejected":
+		return models.ReviewVerdictChanges, true
+	}
+	return "", false
+}
+
+// parseComment reads a comment given as a string or as an object with
+// path (or file), line, and body (or comment, message).
+func parseComment(raw json.RawMessage) (models.ReviewComment, bool) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		text = strings.TrimSpace(text)
+		return models.ReviewComment{Body: text}, text != ""
+	}
+
+	var obj struct {
+		Path    string          `json:"path"`
+		File    string          `json:"file"`
+		Line    json.RawMessage `json:"line"`
+		Body    string          `json:"body"`
+		Comment string          `json:"comment"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return models.ReviewComment{}, false
+	}
+	comment := models.ReviewComment{
+		Path: firstNonEmpty(obj.Path, obj.File),
+		Line: parseLine(obj.Line),
+		Body: strings.TrimSpace(firstNonEmpty(obj.Body, obj.Comment, obj.Message)),
+	}
+	return comment, comment.Body != ""
+}
+
+// parseLine reads a line number given as a number or a string like "12".
+func parseLine(raw json.RawMessage) int {
+	if len(raw) == 0 {
+		return 0
+	}
+	text := strings.Trim(string(raw), `"`)
+	line, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || line < 0 {
+		return 0
+	}
+	return line
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package review
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestParseVerdict(t *testing.T) {
+	fence := "```"
+	tests := []struct {
+		name     string
+		answer   string
+		verdict  models.ReviewVerdict
+		summary  string
+		comments []models.ReviewComment
+		wantErr  error
+	}{
+		{
+			name: "fenced block",
+			answer: "Looks mostly fine.\n\n" + fence + "json\n" +
+				`{"verdict": "changes", "summary": "one bug", "comments": [{"path": "internal/db/repo.go", "line": 12, "body": "check the error"}]}` +
+				"\n" + fence + "\n",
+			verdict:  models.ReviewVerdictChanges,
+			summary:  "one bug",
+			comments: []models.ReviewComment{{Path: "internal/db/repo.go", Line: 12, Body: "check the error"}},
+		},
+		{
+			name: "last fenced verdict wins",
+			answer: fence + "json\n{\"verdict\": \"changes\"}\n" + fence + "\nOn second thought:\n" +
+				fence + "\n{\"verdict\": \"approve\"}\n" + fence + "\n" + fence + "go\nfunc main() {}\n" + fence,
+			verdict: models.ReviewVerdictApprove,
+		},
+		{
+			name:    "bare object",
+			answer:  `Verdict below. {"verdict": "LGTM", "summary": "ship it"} Thanks!`,
+			verdict: models.ReviewVerdictApprove,
+			summary: "ship it",
+		},
+		{
+			name: "wrapped lines and trailing commas",
+			answer: fence + "json\n{\n  \"verdict\": \"request-changes\",\n  \"summary\": \"the retry loop never swe\nars off\",\n" +
+				"  \"comments\": [\n    \"add a test\",\n  ],\n}\n" + fence,
+			verdict:  models.ReviewVerdictChanges,
+			summary:  "the retry loop never swears off",
+			comments: []models.ReviewComment{{Body: "add a test"}},
+		},
+		{
+			name:     "comment spellings",
+			answer:   `{"verdict": "approved", "comments": [{"file": "a.go", "line": "7", "comment": "nit"}, {"message": "  "}, 42, ""]}`,
+			verdict:  models.ReviewVerdictApprove,
+			comments: []models.ReviewComment{{Path: "a.go", Line: 7, Body: "nit"}},
+		},
+		{
+			name:    "no verdict",
+			answer:  "I looked at it and it seems fine to me.",
+			wantErr: ErrNoVerdict,
+		},
+		{
+			name:    "unknown verdict",
+			answer:  fence + "json\n{\"verdict\": \"maybe\"}\n" + fence,
+			wantErr: ErrMalformedVerdict,
+		},
+		{
+			name:    "not JSON",
+			answer:  fence + "\nverdict: approve\n\"verdict\" is approve\n" + fence,
+			wantErr: ErrMalformedVerdict,
+		},
+		{
+			name:    "cut off",
+			answer:  `{"verdict": "approve", "summary": "fine`,
+			wantErr: ErrMalformedVerdict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVerdict(tt.answer)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v (%+v)", tt.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseVerdict failed: %v", err)
+			}
+			if got.Verdict != tt.verdict || got.Summary != tt.summary {
+				t.Fatalf("got verdict %q summary %q, want %q %q", got.Verdict, got.Summary, tt.verdict, tt.summary)
+			}
+			if !reflect.DeepEqual(got.Comments, tt.comments) {
+				t.Fatalf("comments = %+v, want %+v", got.Comments, tt.comments)
+			}
+		})
+	}
+}
+
+func TestAnswerFollowsPrompt(t *testing.T) {
+	prompt, err := RenderPrompt("", PromptData{
+		ReviewID: "0123456789abcdef",
+		Source:   "main..HEAD",
+		Files:    []string{"a.go"},
+		Diff:     "diff --git a/a.go b/a.go",
+	})
+	if err != nil {
+		t.Fatalf("RenderPrompt failed: %v", err)
+	}
+	if !strings.HasSuffix(prompt, "[end of swarm review 01234567]") {
+		t.Fatalf("expected the prompt to end with its marker, got %q", prompt)
+	}
+
+	output := "> " + prompt + "\n\nThe change is fine.\n{\"verdict\": \"approve\"}\n"
+	answer := Answer(output, "0123456789abcdef")
+	if answer != "The change is fine.\n{\"verdict\": \"approve\"}" {
+		t.Fatalf("unexpected answer %q", answer)
+	}
+	if got := Answer("no marker here", "0123456789abcdef"); got != "no marker here" {
+		t.Fatalf("expected output without a marker to be kept whole, got %q", got)
+	}
+
+	if _, err := RenderPrompt("{{.Nope", PromptData{}); err == nil {
+		t.Fatal("expected an invalid template to fail")
+	}
+}
@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/shell"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+// ErrCommandItemsDisabled is returned when a command item is dispatched for
+// a workspace that does not allow them.
+var ErrCommandItemsDisabled = errors.New("command queue items are disabled")
+
+// commandRunner holds what the scheduler needs to run command items.
+type commandRunner struct {
+	config     *config.Config
+	workspaces *workspace.Service
+	nodes      *node.Service
+}
+
+// WithCommandItems lets the scheduler dispatch command queue items. Each
+// command runs on the agent's workspace node, only for workspaces where cfg
+// enables queue_commands, and its output is sent to the agent as a message.
+// Without this option command items fail with ErrCommandItemsDisabled.
+func WithCommandItems(cfg *config.Config, workspaces *workspace.Service, nodes *node.Service) Option {
+	return func(s *Scheduler) {
+		s.commands = &commandRunner{config: cfg, workspaces: workspaces, nodes: nodes}
+	}
+}
+
+// commandResult is the outcome of running a command item.
+type commandResult struct {
+	Output   string
+	ExitCode int
+	TimedOut bool
+}
+
+// dispatchCommand runs a command item and sends its output to the agent.
+// The command gets its own timeout rather than the dispatch timeout, and
+// holds the agent's dispatch slot while it runs.
+func (s *Scheduler) dispatchCommand(ctx context.Context, agentID string, item *models.QueueItem) error {
+	payload, err := item.GetCommandPayload()
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal command payload: %w", err)
+	}
+	if s.commands == nil {
+		return ErrCommandItemsDisabled
+	}
+
+	agentModel, err := s.agentService.GetAgent(ctx, agentID)
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+	ws, err := s.commands.workspaces.GetWorkspace(ctx, agentModel.WorkspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if s.commands.config == nil || !s.commands.config.QueueCommandsEnabled(ws) {
+		return fmt.Errorf("%w for workspace %s", ErrCommandItemsDisabled, workspaceLabel(ws))
+	}
+	nodeModel, err := s.commands.nodes.GetNode(ctx, ws.NodeID)
+	if err != nil {
+		return fmt.Errorf("failed to get node: %w", err)
+	}
+
+	workDir := payload.WorkDir
+	if workDir == "" {
+		workDir = ws.RepoPath
+	}
+
+	base := s.ctx
+	if base == nil {
+		base = context.Background()
+	}
+	result, err := s.runCommand(base, nodeModel, workDir, payload)
+	if err != nil {
+		return err
+	}
+	if payload.FailOnNonZero && (result.TimedOut || result.ExitCode != 0) {
+		return fmt.Errorf("command %s", describeCommandExit(result, payload))
+	}
+
+	sendCtx, cancel := context.WithTimeout(base, s.config.DispatchTimeout)
+	defer cancel()
+	text := renderCommandMessage(payload, result)
+	if err := s.agentService.SendMessage(sendCtx, agentID, text, &agent.SendMessageOptions{}); err != nil {
+		return fmt.Errorf("failed to send command output: %w", err)
+	}
+	return nil
+}
+
+// runCommand runs the payload's command in workDir on n, with stderr merged
+// into stdout so the agent sees output in the order it was written.
+func (s *Scheduler) runCommand(base context.Context, n *models.Node, workDir string, payload *models.CommandPayload) (commandResult, error) {
+	ctx, cancel := context.WithTimeout(base, payload.Timeout())
+	defer cancel()
+
+	script := fmt.Sprintf("cd %s && {\n%s\n} 2>&1", shell.Quote(workDir), payload.Command)
+	res, err := s.commands.nodes.ExecCommand(ctx, n, script)
+	if err != nil {
+		return commandResult{}, fmt.Errorf("failed to run command: %w", err)
+	}
+
+	result := commandResult{
+		Output:   res.Stdout + res.Stderr,
+		ExitCode: res.ExitCode,
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.TimedOut = true
+	} else if base.Err() != nil {
+		return commandResult{}, fmt.Errorf("command interrupted: %w", base.Err())
+	}
+	return result, nil
+}
+
+// renderCommandMessage builds the message sent to the agent: the prefix,
+// the command line, its output (keeping the end when it is too long), and
+// optionally how it exited.
+func renderCommandMessage(payload *models.CommandPayload, result commandResult) string {
+	var b strings.Builder
+	if prefix := strings.TrimSpace(payload.Prefix); prefix != "" {
+		b.WriteString(prefix)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("$ ")
+	b.WriteString(strings.TrimSpace(payload.Command))
+	b.WriteString("\n")
+
+	output, dropped := truncateOutput(result.Output, payload.OutputLimit())
+	if dropped > 0 {
+		fmt.Fprintf(&b, "[... %d bytes of output truncated ...]\n", dropped)
+	}
+	b.WriteString(output)
+	if output != "" && !strings.HasSuffix(output, "\n") {
+		b.WriteString("\n")
+	}
+
+	if payload.IncludeExitCode || result.TimedOut {
+		b.WriteString("[")
+		b.WriteString(describeCommandExit(result, payload))
+		b.WriteString("]")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// truncateOutput keeps at most limit bytes from the end of output, where
+// test runners and compilers put their summaries, without splitting a rune.
+// It returns the kept output and how many bytes were dropped.
+func truncateOutput(output string, limit int) (string, int) {
+	if limit <= 0 || len(output) <= limit {
+		return output, 0
+	}
+	start := len(output) - limit
+	for start < len(output) && !utf8.RuneStart(output[start]) {
+		start++
+	}
+	return output[start:], start
+}
+
+func describeCommandExit(result commandResult, payload *models.CommandPayload) string {
+	if result.TimedOut {
+		return fmt.Sprintf("timed out after %s", payload.Timeout())
+	}
+	return fmt.Sprintf("exit code %d", result.ExitCode)
+}
+
+func workspaceLabel(ws *models.Workspace) string {
+	if ws.Name != "" {
+		return ws.Name
+	}
+	return ws.ID
+}
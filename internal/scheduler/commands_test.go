@@ -0,0 +1,246 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+// commandFixture is a scheduler with one idle agent in a workspace on the
+// local node, whose repo path is a temp dir.
+type commandFixture struct {
+	sched      *Scheduler
+	queue      *trackingQueueService
+	srv        *tmuxtest.Server
+	agentID    string
+	repo       string
+	database   *db.DB
+	config     *config.Config
+	workspaces *workspace.Service
+	nodes      *node.Service
+}
+
+func newCommandFixture(t *testing.T, enabled bool) *commandFixture {
+	t.Helper()
+
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	workspaceRepo := db.NewWorkspaceRepository(database)
+	agentRepo := db.NewAgentRepository(database)
+
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := nodeRepo.Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	repo := t.TempDir()
+	ws := &models.Workspace{NodeID: localNode.ID, Name: "alpha", RepoPath: repo, TmuxSession: "session"}
+	if err := workspaceRepo.Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agentModel := &models.Agent{
+		WorkspaceID: ws.ID,
+		Type:        models.AgentTypeOpenCode,
+		TmuxPane:    "session:0.0",
+		State:       models.AgentStateIdle,
+		QueueLength: 1,
+		StateInfo: models.StateInfo{
+			State:      models.AgentStateIdle,
+			Confidence: models.StateConfidenceHigh,
+			DetectedAt: time.Now().UTC(),
+		},
+	}
+	if err := agentRepo.Create(ctx, agentModel); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	if enabled {
+		cfg.WorkspaceOverrides = []config.WorkspaceOverrideConfig{{Name: "alpha", QueueCommands: true}}
+	}
+
+	srv := newDispatchTmux(t)
+	nodeSvc := node.NewService(nodeRepo)
+	wsSvc := workspace.NewService(workspaceRepo, nodeSvc, agentRepo)
+	agentSvc := agent.NewService(agentRepo, nil, nil, nil, tmux.NewClient(srv))
+
+	queueSvc := newTrackingQueueService()
+	schedCfg := DefaultConfig()
+	schedCfg.MaxRetries = 0
+	sched := New(schedCfg, agentSvc, queueSvc, nil, nil, WithCommandItems(cfg, wsSvc, nodeSvc))
+	sched.ctx = context.Background()
+
+	return &commandFixture{
+		sched:      sched,
+		queue:      queueSvc,
+		srv:        srv,
+		agentID:    agentModel.ID,
+		repo:       repo,
+		database:   database,
+		config:     cfg,
+		workspaces: wsSvc,
+		nodes:      nodeSvc,
+	}
+}
+
+func (f *commandFixture) dispatch(t *testing.T, payload models.CommandPayload) {
+	t.Helper()
+	f.dispatchItem(t, "cmd-1", models.QueueItemTypeCommand, payload)
+}
+
+// dispatchItem queues an item of itemType with payload and dispatches it.
+func (f *commandFixture) dispatchItem(t *testing.T, id string, itemType models.QueueItemType, payload any) {
+	t.Helper()
+	raw, _ := json.Marshal(payload)
+	item := &models.QueueItem{
+		ID:        id,
+		Type:      itemType,
+		Status:    models.QueueItemStatusPending,
+		Payload:   raw,
+		CreatedAt: time.Now(),
+	}
+	if err := f.queue.Enqueue(context.Background(), f.agentID, item); err != nil {
+		t.Fatalf("failed to enqueue item: %v", err)
+	}
+	f.sched.dispatchToAgent(f.agentID)
+}
+
+// failure returns the error recorded for the item, or "" if it succeeded.
+func (f *commandFixture) failure() string {
+	for _, update := range f.queue.statusUpdates {
+		if update.status == models.QueueItemStatusFailed {
+			return update.errorMsg
+		}
+	}
+	return ""
+}
+
+func TestDispatchCommand_SendsOutput(t *testing.T) {
+	f := newCommandFixture(t, true)
+
+	f.dispatch(t, models.CommandPayload{
+		Command:         "pwd; echo oops >&2; exit 3",
+		Prefix:          "summarize the failures:",
+		IncludeExitCode: true,
+	})
+
+	if msg := f.failure(); msg != "" {
+		t.Fatalf("expected a non-zero exit to be sent, not failed: %s", msg)
+	}
+	for _, want := range []string{"summarize the failures:", "$ pwd; echo oops", f.repo, "oops", "[exit code 3]"} {
+		if !paneShows(t, f.srv, want) {
+			t.Fatalf("expected pane to show %q, got %v", want, f.srv.Commands())
+		}
+	}
+}
+
+func TestDispatchCommand_FailOnNonZero(t *testing.T) {
+	f := newCommandFixture(t, true)
+
+	f.dispatch(t, models.CommandPayload{Command: "exit 2", FailOnNonZero: true})
+
+	if msg := f.failure(); !strings.Contains(msg, "exit code 2") {
+		t.Fatalf("expected the item to fail with the exit code, got %q", msg)
+	}
+	if paneShows(t, f.srv, "$ exit 2") {
+		t.Fatal("expected no message for a failed command")
+	}
+}
+
+func TestDispatchCommand_TimeoutKillsCommand(t *testing.T) {
+	f := newCommandFixture(t, true)
+
+	start := time.Now()
+	f.dispatch(t, models.CommandPayload{Command: "echo started; sleep 30; echo $((6*7))", TimeoutSeconds: 1})
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("expected the command to be killed at its timeout, took %s", elapsed)
+	}
+	if !paneShows(t, f.srv, "started") || !paneShows(t, f.srv, "[timed out after 1s]") {
+		t.Fatalf("expected partial output and a timeout note, got %v", f.srv.Commands())
+	}
+	if paneShows(t, f.srv, "42") {
+		t.Fatal("expected the command to be killed before finishing")
+	}
+}
+
+func TestDispatchCommand_TruncatesOutput(t *testing.T) {
+	f := newCommandFixture(t, true)
+
+	f.dispatch(t, models.CommandPayload{Command: "seq 1 5000", MaxOutputBytes: 10})
+
+	if !paneShows(t, f.srv, "bytes of output truncated") || !paneShows(t, f.srv, "5000") {
+		t.Fatalf("expected the tail of the output with a truncation note, got %v", f.srv.Commands())
+	}
+	if paneShows(t, f.srv, "4990") {
+		t.Fatal("expected earlier output to be dropped")
+	}
+}
+
+func TestDispatchCommand_DisabledByDefault(t *testing.T) {
+	f := newCommandFixture(t, false)
+
+	f.dispatch(t, models.CommandPayload{Command: "touch ran"})
+
+	if msg := f.failure(); !strings.Contains(msg, ErrCommandItemsDisabled.Error()) {
+		t.Fatalf("expected the item to fail as disabled, got %q", msg)
+	}
+	if paneShows(t, f.srv, "$ touch ran") {
+		t.Fatal("expected no message for a disabled command")
+	}
+}
+
+func TestDispatchCommand_RequiresOption(t *testing.T) {
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, nil)
+	defer cleanup()
+
+	raw, _ := json.Marshal(models.CommandPayload{Command: "true"})
+	item := &models.QueueItem{ID: "cmd-1", Type: models.QueueItemTypeCommand, Payload: raw}
+
+	sched := New(DefaultConfig(), agentSvc, newTrackingQueueService(), nil, nil)
+	if err := sched.dispatchCommand(context.Background(), agentID, item); err != ErrCommandItemsDisabled {
+		t.Fatalf("expected ErrCommandItemsDisabled without WithCommandItems, got %v", err)
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		limit   int
+		want    string
+		dropped int
+	}{
+		{"under limit", "hello", 10, "hello", 0},
+		{"keeps tail", "line1\nline2\n", 6, "line2\n", 6},
+		{"rune boundary", "aé", 1, "", 3},
+		{"whole rune kept", "aéb", 3, "éb", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dropped := truncateOutput(tt.output, tt.limit)
+			if got != tt.want || dropped != tt.dropped {
+				t.Fatalf("truncateOutput(%q, %d) = %q, %d; want %q, %d", tt.output, tt.limit, got, dropped, tt.want, tt.dropped)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// Dispatch recorder defaults.
+const (
+	DefaultRecorderBatchSize     = 50
+	DefaultRecorderFlushInterval = time.Second
+	DefaultRecorderMaxPending    = 5000
+
+	// recorderFlushTimeout bounds the final flush when the recorder stops.
+	recorderFlushTimeout = 5 * time.Second
+)
+
+// EventBatchWriter persists a batch of events. db.EventRepository
+// implements it.
+type EventBatchWriter interface {
+	CreateBatch(ctx context.Context, events []*models.Event) error
+}
+
+// DispatchRecorder persists scheduler dispatch events to the event log as
+// queue.item_dispatched events. Events are buffered and written in batches
+// so a burst of dispatches does not cost one insert each.
+type DispatchRecorder struct {
+	writer        EventBatchWriter
+	batchSize     int
+	flushInterval time.Duration
+	maxPending    int
+	clock         clock.Clock
+	logger        zerolog.Logger
+
+	mu      sync.Mutex
+	pending []DispatchEvent
+	dropped int64
+	flushCh chan struct{}
+}
+
+// RecorderOption configures a DispatchRecorder.
+type RecorderOption func(*DispatchRecorder)
+
+// WithBatchSize sets how many pending events trigger an immediate flush.
+func WithBatchSize(n int) RecorderOption {
+	return func(r *DispatchRecorder) {
+		r.batchSize = n
+	}
+}
+
+// WithFlushInterval sets how often pending events are flushed.
+func WithFlushInterval(d time.Duration) RecorderOption {
+	return func(r *DispatchRecorder) {
+		r.flushInterval = d
+	}
+}
+
+// WithMaxPending caps the number of buffered events. Events recorded while
+// the buffer is full are dropped and counted.
+func WithMaxPending(n int) RecorderOption {
+	return func(r *DispatchRecorder) {
+		r.maxPending = n
+	}
+}
+
+// WithRecorderClock sets the time source for the flush ticker.
+func WithRecorderClock(c clock.Clock) RecorderOption {
+	return func(r *DispatchRecorder) {
+		r.clock = c
+	}
+}
+
+// NewDispatchRecorder creates a recorder that writes to writer.
+func NewDispatchRecorder(writer EventBatchWriter, opts ...RecorderOption) *DispatchRecorder {
+	r := &DispatchRecorder{
+		writer:        writer,
+		batchSize:     DefaultRecorderBatchSize,
+		flushInterval: DefaultRecorderFlushInterval,
+		maxPending:    DefaultRecorderMaxPending,
+		clock:         clock.Real(),
+		logger:        logging.Component("dispatch-recorder"),
+		flushCh:       make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = DefaultRecorderBatchSize
+	}
+	if r.flushInterval <= 0 {
+		r.flushInterval = DefaultRecorderFlushInterval
+	}
+	if r.maxPending < r.batchSize {
+		r.maxPending = r.batchSize
+	}
+	r.clock = clock.OrReal(r.clock)
+
+	return r
+}
+
+// Record buffers a dispatch event for persistence. It never blocks.
+func (r *DispatchRecorder) Record(event DispatchEvent) {
+	r.mu.Lock()
+	if len(r.pending) >= r.maxPending {
+		r.dropped++
+		r.mu.Unlock()
+		return
+	}
+	r.pending = append(r.pending, event)
+	full := len(r.pending) >= r.batchSize
+	r.mu.Unlock()
+
+	if full {
+		select {
+		case r.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run flushes buffered events on every interval or full batch until ctx is
+// cancelled, then performs a final flush.
+func (r *DispatchRecorder) Run(ctx context.Context) {
+	ticker := r.clock.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), recorderFlushTimeout)
+			r.flushAndLog(flushCtx)
+			cancel()
+			return
+		case <-ticker.C():
+			r.flushAndLog(ctx)
+		case <-r.flushCh:
+			r.flushAndLog(ctx)
+		}
+	}
+}
+
+// Flush writes all buffered events in batches. Events from a failed batch
+// are dropped so a broken database cannot grow the buffer without bound.
+func (r *DispatchRecorder) Flush(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		n := len(r.pending)
+		if n == 0 {
+			r.mu.Unlock()
+			return nil
+		}
+		if n > r.batchSize {
+			n = r.batchSize
+		}
+		batch := make([]DispatchEvent, n)
+		copy(batch, r.pending[:n])
+		r.pending = r.pending[n:]
+		r.mu.Unlock()
+
+		events := make([]*models.Event, 0, len(batch))
+		for _, dispatch := range batch {
+			events = append(events, dispatchEventToModel(dispatch))
+		}
+		if err := r.writer.CreateBatch(ctx, events); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *DispatchRecorder) flushAndLog(ctx context.Context) {
+	if err := r.Flush(ctx); err != nil {
+		r.logger.Warn().Err(err).Msg("failed to persist dispatch events")
+	}
+
+	r.mu.Lock()
+	dropped := r.dropped
+	r.dropped = 0
+	r.mu.Unlock()
+	if dropped > 0 {
+		r.logger.Warn().Int64("dropped", dropped).Msg("dispatch event buffer full, events dropped")
+	}
+}
+
+// dispatchEventToModel converts a DispatchEvent into a queue.item_dispatched
+// event keyed by agent.
+func dispatchEventToModel(dispatch DispatchEvent) *models.Event {
+	payload, _ := json.Marshal(models.QueueItemDispatchedPayload{
+		AgentID:     dispatch.AgentID,
+		QueueItemID: dispatch.ItemID,
+		ItemType:    dispatch.ItemType,
+		Success:     dispatch.Success,
+		Duration:    dispatch.Duration.String(),
+		Error:       dispatch.Error,
+	})
+
+	return &models.Event{
+		Timestamp:  dispatch.Timestamp,
+		Type:       models.EventTypeQueueItemDispatched,
+		EntityType: models.EntityTypeAgent,
+		EntityID:   dispatch.AgentID,
+		Payload:    payload,
+		TraceID:    dispatch.TraceID,
+	}
+}
@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+type failingDequeueQueueService struct {
+	*trackingQueueService
+}
+
+func (m *failingDequeueQueueService) Dequeue(ctx context.Context, agentID, dispatcherID string) (*models.QueueItem, error) {
+	return nil, errors.New("queue unavailable")
+}
+
+type countingBatchWriter struct {
+	batches [][]*models.Event
+}
+
+func (w *countingBatchWriter) CreateBatch(ctx context.Context, events []*models.Event) error {
+	w.batches = append(w.batches, events)
+	return nil
+}
+
+func newEventRepoForTest(t *testing.T) *db.EventRepository {
+	t.Helper()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(context.Background()); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+	return db.NewEventRepository(database)
+}
+
+func dispatchedEvents(t *testing.T, repo *db.EventRepository) []models.QueueItemDispatchedPayload {
+	t.Helper()
+
+	eventType := models.EventTypeQueueItemDispatched
+	page, err := repo.Query(context.Background(), db.EventQuery{Type: &eventType, Limit: 100})
+	if err != nil {
+		t.Fatalf("failed to query events: %v", err)
+	}
+
+	payloads := make([]models.QueueItemDispatchedPayload, 0, len(page.Events))
+	for _, event := range page.Events {
+		if event.EntityType != models.EntityTypeAgent {
+			t.Fatalf("expected agent entity, got %q", event.EntityType)
+		}
+		var payload models.QueueItemDispatchedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		if payload.AgentID != event.EntityID {
+			t.Fatalf("payload agent %q does not match entity %q", payload.AgentID, event.EntityID)
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads
+}
+
+func TestDispatchRecorder_PersistsSuccessfulAndFailedDispatches(t *testing.T) {
+	eventRepo := newEventRepoForTest(t)
+	recorder := NewDispatchRecorder(eventRepo)
+
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, tmux.NewClient(newDispatchTmux(t)))
+	defer cleanup()
+
+	queueSvc := newTrackingQueueService()
+	if err := queueSvc.Enqueue(context.Background(), agentID, makeMessageItem("item-1", "hello")); err != nil {
+		t.Fatalf("failed to enqueue item: %v", err)
+	}
+
+	sched := New(DefaultConfig(), agentSvc, queueSvc, nil, nil, WithDispatchRecorder(recorder))
+	sched.ctx = context.Background()
+	sched.dispatchToAgent(agentID)
+
+	// The first dispatch leaves the agent busy; skip the idle check so the
+	// second attempt reaches the failing dequeue.
+	cfg := DefaultConfig()
+	cfg.IdleStateRequired = false
+	failing := New(cfg, agentSvc, &failingDequeueQueueService{newTrackingQueueService()}, nil, nil, WithDispatchRecorder(recorder))
+	failing.ctx = context.Background()
+	failing.dispatchToAgent(agentID)
+
+	if err := recorder.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	payloads := dispatchedEvents(t, eventRepo)
+	if len(payloads) != 2 {
+		t.Fatalf("expected 2 dispatch events, got %d", len(payloads))
+	}
+
+	// Both events share a second, so the log order is not meaningful here.
+	ok, failed := payloads[0], payloads[1]
+	if !ok.Success {
+		ok, failed = failed, ok
+	}
+	if !ok.Success || ok.QueueItemID != "item-1" || ok.ItemType != models.QueueItemTypeMessage || ok.Error != "" {
+		t.Fatalf("unexpected successful dispatch payload: %+v", ok)
+	}
+	if _, err := time.ParseDuration(ok.Duration); err != nil {
+		t.Fatalf("expected parseable duration, got %q", ok.Duration)
+	}
+
+	if failed.Success || failed.QueueItemID != "" || failed.Error == "" {
+		t.Fatalf("unexpected failed dispatch payload: %+v", failed)
+	}
+}
+
+func TestDispatchRecorder_BatchesWrites(t *testing.T) {
+	writer := &countingBatchWriter{}
+	recorder := NewDispatchRecorder(writer, WithBatchSize(2), WithMaxPending(3))
+
+	for i := 0; i < 5; i++ {
+		recorder.Record(DispatchEvent{AgentID: "agent-1", ItemID: "item", Success: true})
+	}
+	if err := recorder.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	// Three events fit in the buffer; they are written as batches of 2 and 1.
+	if len(writer.batches) != 2 || len(writer.batches[0]) != 2 || len(writer.batches[1]) != 1 {
+		t.Fatalf("unexpected batches: %d", len(writer.batches))
+	}
+}
+
+func TestDispatchRecorder_FlushesOnStop(t *testing.T) {
+	eventRepo := newEventRepoForTest(t)
+	recorder := NewDispatchRecorder(eventRepo, WithFlushInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		recorder.Run(ctx)
+		close(done)
+	}()
+
+	recorder.Record(DispatchEvent{AgentID: "agent-1", ItemID: "item-1", Success: true, Timestamp: time.Now()})
+	cancel()
+	<-done
+
+	if got := len(dispatchedEvents(t, eventRepo)); got != 1 {
+		t.Fatalf("expected final flush to persist 1 event, got %d", got)
+	}
+}
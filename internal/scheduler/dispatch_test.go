@@ -9,10 +9,12 @@ import (
 	"time"
 
 	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/clock"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/models"
 	"github.com/opencode-ai/swarm/internal/queue"
 	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
 )
 
 type dispatchStatusUpdate struct {
@@ -33,6 +35,7 @@ type trackingQueueService struct {
 	dequeueCalls  int
 	insertCalls   []dispatchInsertCall
 	statusUpdates []dispatchStatusUpdate
+	releases      []dispatchStatusUpdate
 }
 
 func newTrackingQueueService() *trackingQueueService {
@@ -48,7 +51,18 @@ func (m *trackingQueueService) Enqueue(ctx context.Context, agentID string, item
 	return nil
 }
 
-func (m *trackingQueueService) Dequeue(ctx context.Context, agentID string) (*models.QueueItem, error) {
+func (m *trackingQueueService) EnqueueBatch(ctx context.Context, agentID string, items ...*models.QueueItem) error {
+	return m.Enqueue(ctx, agentID, items...)
+}
+
+func (m *trackingQueueService) EnqueueBatchAtHead(ctx context.Context, agentID string, items ...*models.QueueItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queues[agentID] = append(append([]*models.QueueItem{}, items...), m.queues[agentID]...)
+	return nil
+}
+
+func (m *trackingQueueService) Dequeue(ctx context.Context, agentID, dispatcherID string) (*models.QueueItem, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.dequeueCalls++
@@ -130,6 +144,21 @@ func (m *trackingQueueService) UpdateStatus(ctx context.Context, itemID string,
 	return nil
 }
 
+func (m *trackingQueueService) ListInFlight(ctx context.Context, cutoff time.Time) ([]*models.QueueItem, error) {
+	return nil, nil
+}
+
+func (m *trackingQueueService) ReleaseInFlight(ctx context.Context, itemID string, status models.QueueItemStatus, attempts int, errorMsg string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.releases = append(m.releases, dispatchStatusUpdate{
+		itemID:   itemID,
+		status:   status,
+		errorMsg: errorMsg,
+	})
+	return true, nil
+}
+
 func (m *trackingQueueService) UpdateAttempts(ctx context.Context, itemID string, attempts int) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -150,30 +179,25 @@ func (m *trackingQueueService) queueLength(agentID string) int {
 	return len(m.queues[agentID])
 }
 
-type dispatchExecutor struct {
-	mu       sync.Mutex
-	commands []string
-}
-
-func (d *dispatchExecutor) Exec(ctx context.Context, cmd string) ([]byte, []byte, error) {
-	d.mu.Lock()
-	d.commands = append(d.commands, cmd)
-	d.mu.Unlock()
-
-	switch {
-	case strings.Contains(cmd, "has-session"):
-		return nil, nil, nil
-	case strings.Contains(cmd, "list-panes"):
-		return []byte("%1|0|0|/tmp|1|bash\n"), nil, nil
-	default:
-		return nil, nil, nil
+// newDispatchTmux returns a fake tmux server holding the pane used by
+// setupAgentServiceForDispatch.
+func newDispatchTmux(t *testing.T) *tmuxtest.Server {
+	t.Helper()
+	srv := tmuxtest.NewServer()
+	if _, err := srv.Run("new-session", "-d", "-s", "session", "-c", "/tmp/repo"); err != nil {
+		t.Fatalf("failed to create tmux session: %v", err)
 	}
+	return srv
 }
 
-func (d *dispatchExecutor) Commands() []string {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	return append([]string(nil), d.commands...)
+// paneShows reports whether the pane holds text.
+func paneShows(t *testing.T, srv *tmuxtest.Server, text string) bool {
+	t.Helper()
+	content, err := srv.Capture("session:0.0")
+	if err != nil {
+		t.Fatalf("failed to capture pane: %v", err)
+	}
+	return strings.Contains(content, text)
 }
 
 func makeMessageItem(id, text string) *models.QueueItem {
@@ -215,6 +239,26 @@ func makeConditionalItem(id string, payload models.ConditionalPayload) *models.Q
 func setupAgentServiceForDispatch(t *testing.T, state models.AgentState, queueLength int, tmuxClient *tmux.Client) (*agent.Service, string, func()) {
 	t.Helper()
 
+	now := time.Now().UTC()
+	agentModel := &models.Agent{
+		Type:        models.AgentTypeOpenCode,
+		State:       state,
+		QueueLength: queueLength,
+		StateInfo: models.StateInfo{
+			State:      state,
+			Confidence: models.StateConfidenceHigh,
+			Reason:     "test setup",
+			DetectedAt: now,
+		},
+	}
+	return setupDispatchAgent(t, agentModel, tmuxClient)
+}
+
+// setupDispatchAgent creates agentModel, with any accounts it uses, in the
+// pane newDispatchTmux creates.
+func setupDispatchAgent(t *testing.T, agentModel *models.Agent, tmuxClient *tmux.Client, accounts ...*models.Account) (*agent.Service, string, func()) {
+	t.Helper()
+
 	ctx := context.Background()
 	database, err := db.OpenInMemory()
 	if err != nil {
@@ -251,20 +295,16 @@ func setupAgentServiceForDispatch(t *testing.T, state models.AgentState, queueLe
 		t.Fatalf("failed to create workspace: %v", err)
 	}
 
-	now := time.Now().UTC()
-	agentModel := &models.Agent{
-		WorkspaceID: workspace.ID,
-		Type:        models.AgentTypeOpenCode,
-		TmuxPane:    "session:0.0",
-		State:       state,
-		QueueLength: queueLength,
-		StateInfo: models.StateInfo{
-			State:      state,
-			Confidence: models.StateConfidenceHigh,
-			Reason:     "test setup",
-			DetectedAt: now,
-		},
+	accountRepo := db.NewAccountRepository(database)
+	for _, acct := range accounts {
+		if err := accountRepo.Create(ctx, acct); err != nil {
+			_ = database.Close()
+			t.Fatalf("failed to create account: %v", err)
+		}
 	}
+
+	agentModel.WorkspaceID = workspace.ID
+	agentModel.TmuxPane = "session:0.0"
 	if err := agentRepo.Create(ctx, agentModel); err != nil {
 		_ = database.Close()
 		t.Fatalf("failed to create agent: %v", err)
@@ -278,8 +318,8 @@ func setupAgentServiceForDispatch(t *testing.T, state models.AgentState, queueLe
 }
 
 func TestScheduler_DispatchToAgent_MessageItemSends(t *testing.T) {
-	exec := &dispatchExecutor{}
-	tmuxClient := tmux.NewClient(exec)
+	srv := newDispatchTmux(t)
+	tmuxClient := tmux.NewClient(srv)
 
 	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, tmuxClient)
 	defer cleanup()
@@ -298,16 +338,11 @@ func TestScheduler_DispatchToAgent_MessageItemSends(t *testing.T) {
 		t.Fatalf("expected queue to be empty, got %d", got)
 	}
 
-	commands := exec.Commands()
-	found := false
-	for _, cmd := range commands {
-		if strings.Contains(cmd, "send-keys") && strings.Contains(cmd, "hello") {
-			found = true
-			break
-		}
+	if !paneShows(t, srv, "hello") {
+		t.Fatalf("expected message to be typed into the pane, got %v", srv.Commands())
 	}
-	if !found {
-		t.Fatalf("expected send-keys command with message, got %v", commands)
+	if len(queueSvc.releases) != 1 || queueSvc.releases[0].itemID != "item-1" || queueSvc.releases[0].status != models.QueueItemStatusDispatched {
+		t.Fatalf("expected the sent item released as dispatched, got %+v", queueSvc.releases)
 	}
 }
 
@@ -413,3 +448,110 @@ func TestScheduler_DispatchConditional_SkipsAfterMaxEvaluations(t *testing.T) {
 		t.Fatalf("expected max evaluations error, got %q", update.errorMsg)
 	}
 }
+
+func pausedAgentSnapshot(t *testing.T, agentSvc *agent.Service, agentID string, until time.Time) []*models.Agent {
+	t.Helper()
+	agentModel, err := agentSvc.GetAgent(context.Background(), agentID)
+	if err != nil {
+		t.Fatalf("failed to fetch agent: %v", err)
+	}
+	agentModel.State = models.AgentStatePaused
+	agentModel.PausedUntil = &until
+	return []*models.Agent{agentModel}
+}
+
+func requireAgentState(t *testing.T, agentSvc *agent.Service, agentID string, want models.AgentState) {
+	t.Helper()
+	agentModel, err := agentSvc.GetAgent(context.Background(), agentID)
+	if err != nil {
+		t.Fatalf("failed to fetch agent: %v", err)
+	}
+	if agentModel.State != want {
+		t.Fatalf("expected agent state %s, got %s", want, agentModel.State)
+	}
+}
+
+func TestScheduler_CheckAutoResume_FakeClock(t *testing.T) {
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStatePaused, 0, nil)
+	defer cleanup()
+
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	sched := New(DefaultConfig(), agentSvc, nil, nil, nil, WithClock(fake))
+	agents := pausedAgentSnapshot(t, agentSvc, agentID, fake.Now().Add(5*time.Minute))
+
+	sched.checkAutoResume(context.Background(), agents)
+	requireAgentState(t, agentSvc, agentID, models.AgentStatePaused)
+
+	fake.Advance(5*time.Minute + time.Second)
+	sched.checkAutoResume(context.Background(), agents)
+	requireAgentState(t, agentSvc, agentID, models.AgentStateIdle)
+}
+
+func TestScheduler_CheckAutoResume_WallClockJumps(t *testing.T) {
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStatePaused, 0, nil)
+	defer cleanup()
+
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	sched := New(DefaultConfig(), agentSvc, nil, nil, nil, WithClock(fake))
+	agents := pausedAgentSnapshot(t, agentSvc, agentID, fake.Now().Add(5*time.Minute))
+
+	// First observation converts PausedUntil into a monotonic deadline.
+	sched.checkAutoResume(context.Background(), agents)
+
+	// Laptop wakes from sleep: wall clock jumps forward, resume must not fire.
+	fake.Jump(2 * time.Hour)
+	sched.checkAutoResume(context.Background(), agents)
+	requireAgentState(t, agentSvc, agentID, models.AgentStatePaused)
+
+	// NTP steps the clock back: the pause must not be stretched out.
+	fake.Jump(-4 * time.Hour)
+	fake.Advance(5*time.Minute + time.Second)
+	sched.checkAutoResume(context.Background(), agents)
+	requireAgentState(t, agentSvc, agentID, models.AgentStateIdle)
+}
+
+func TestScheduler_RetryBackoff_WallClockJumps(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	sched := New(DefaultConfig(), nil, nil, nil, nil, WithClock(fake))
+	sched.setRetryAfter("agent-1", clock.NewDeadline(fake, 10*time.Second))
+
+	fake.Jump(time.Hour)
+	if !sched.isRetryBackoffActive("agent-1") {
+		t.Fatal("expected backoff to survive forward wall-clock jump")
+	}
+
+	fake.Jump(-2 * time.Hour)
+	fake.Advance(10 * time.Second)
+	if sched.isRetryBackoffActive("agent-1") {
+		t.Fatal("expected backoff to expire after 10s of monotonic time")
+	}
+}
+
+func TestScheduler_DispatchToAgent_ContinuesItemTrace(t *testing.T) {
+	srv := newDispatchTmux(t)
+	tmuxClient := tmux.NewClient(srv)
+
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, tmuxClient)
+	defer cleanup()
+
+	queueSvc := newTrackingQueueService()
+	item := makeMessageItem("item-1", "hello")
+	item.TraceID = "cli-trace-1"
+	if err := queueSvc.Enqueue(context.Background(), agentID, item); err != nil {
+		t.Fatalf("failed to enqueue item: %v", err)
+	}
+
+	sched := New(DefaultConfig(), agentSvc, queueSvc, nil, nil)
+	sched.ctx = context.Background()
+
+	sched.dispatchToAgent(agentID)
+
+	select {
+	case event := <-sched.DispatchEvents():
+		if !event.Success || event.TraceID != "cli-trace-1" {
+			t.Fatalf("expected a successful dispatch under the item's trace, got %+v", event)
+		}
+	default:
+		t.Fatal("expected a dispatch event")
+	}
+}
@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+)
+
+// handleFailure runs the recovery matching an agent's failure category.
+// Auth failures rotate the account, rate limits are handled like the
+// rate-limited state, and network failures restart the agent at most once
+// per TransientRestartBackoff. Other categories need a person to inspect.
+func (s *Scheduler) handleFailure(change state.StateChange) {
+	switch change.Failure.Category {
+	case models.FailureCategoryRateLimit:
+		s.handleRateLimit(change)
+	case models.FailureCategoryAuth:
+		s.rotateAfterAuthFailure(change)
+	case models.FailureCategoryNetwork:
+		s.restartAfterTransientFailure(change)
+	}
+}
+
+func (s *Scheduler) failureContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+func (s *Scheduler) rotateAfterAuthFailure(change state.StateChange) {
+	if s.accountService == nil || s.agentService == nil {
+		return
+	}
+	ctx := s.failureContext()
+
+	agentInfo, err := s.agentService.GetAgent(ctx, change.AgentID)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("agent_id", change.AgentID).Msg("failed to load agent for auth failure handling")
+		return
+	}
+	if agentInfo.AccountID == "" {
+		s.logger.Debug().Str("agent_id", change.AgentID).Msg("agent has no account; cannot rotate after auth failure")
+		return
+	}
+
+	fromAccount := agentInfo.AccountID
+	if err := s.accountService.SetCooldownForRateLimit(ctx, fromAccount, change.Failure.String()); err != nil {
+		s.logger.Warn().Err(err).Str("agent_id", change.AgentID).Str("account_id", fromAccount).
+			Msg("failed to set account cooldown after auth failure")
+	}
+
+	rotated, err := s.accountService.RotateAccountForAgent(ctx, fromAccount, change.AgentID, "auth_failure")
+	if err != nil {
+		s.logger.Warn().Err(err).Str("agent_id", change.AgentID).Str("account_id", fromAccount).
+			Msg("auth failure: no other account available")
+		return
+	}
+	if _, err := s.agentService.RestartAgentWithAccount(ctx, change.AgentID, rotated.ID, false); err != nil {
+		s.logger.Warn().Err(err).Str("agent_id", change.AgentID).Str("from_account", fromAccount).
+			Str("to_account", rotated.ID).Msg("failed to restart agent with rotated account")
+		return
+	}
+
+	s.logger.Info().Str("agent_id", change.AgentID).Str("from_account", fromAccount).
+		Str("to_account", rotated.ID).Msg("rotated account and restarted agent after auth failure")
+}
+
+func (s *Scheduler) restartAfterTransientFailure(change state.StateChange) {
+	if s.agentService == nil {
+		return
+	}
+	if !s.claimFailureRestart(change.AgentID) {
+		s.logger.Info().Str("agent_id", change.AgentID).
+			Msg("transient failure within restart backoff; leaving agent for inspection")
+		return
+	}
+
+	if _, err := s.agentService.RestartAgent(s.failureContext(), change.AgentID); err != nil {
+		s.logger.Warn().Err(err).Str("agent_id", change.AgentID).Msg("failed to restart agent after transient failure")
+		return
+	}
+	s.logger.Info().Str("agent_id", change.AgentID).
+		Str("failure", change.Failure.Summary).Msg("restarted agent after transient failure")
+}
+
+// claimFailureRestart reports whether agentID may be restarted now, and if
+// so starts its backoff.
+func (s *Scheduler) claimFailureRestart(agentID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if until, ok := s.failureRestarts[agentID]; ok && !until.Expired(s.clock) {
+		return false
+	}
+	s.failureRestarts[agentID] = clock.NewDeadline(s.clock, s.config.TransientRestartBackoff)
+	return true
+}
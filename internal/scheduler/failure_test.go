@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+)
+
+func TestClaimFailureRestartBacksOff(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	cfg := DefaultConfig()
+	cfg.TransientRestartBackoff = 10 * time.Minute
+	sched := New(cfg, nil, nil, nil, nil, WithClock(fake))
+
+	if !sched.claimFailureRestart("agent-1") {
+		t.Fatal("expected the first restart to be allowed")
+	}
+	if sched.claimFailureRestart("agent-1") {
+		t.Fatal("expected a second restart within the backoff to be refused")
+	}
+	if !sched.claimFailureRestart("agent-2") {
+		t.Fatal("expected the backoff to be per agent")
+	}
+
+	fake.Advance(10 * time.Minute)
+	if !sched.claimFailureRestart("agent-1") {
+		t.Fatal("expected a restart once the backoff passed")
+	}
+}
+
+func TestOnStateChange_RateLimitFailurePausesAgent(t *testing.T) {
+	queueSvc := newMockQueueService()
+	sched := New(DefaultConfig(), nil, queueSvc, nil, nil)
+
+	sched.onStateChange(state.StateChange{
+		AgentID:      "agent-1",
+		CurrentState: models.AgentStateError,
+		StateInfo:    models.StateInfo{Reason: "rate_limit failure: quota exhausted"},
+		Failure:      &models.FailureClassification{Category: models.FailureCategoryRateLimit},
+	})
+
+	items, err := queueSvc.List(context.Background(), "agent-1")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Type != models.QueueItemTypePause {
+		t.Fatalf("expected a cooldown pause, got %+v", items)
+	}
+
+	// Task failures are left for a person to inspect.
+	sched.onStateChange(state.StateChange{
+		AgentID:      "agent-2",
+		CurrentState: models.AgentStateError,
+		Failure:      &models.FailureClassification{Category: models.FailureCategoryTask},
+	})
+	if items, _ := queueSvc.List(context.Background(), "agent-2"); len(items) != 0 {
+		t.Fatalf("expected no recovery for a task failure, got %+v", items)
+	}
+}
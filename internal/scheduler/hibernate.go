@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// hibernation holds what the scheduler needs to hibernate idle agents.
+type hibernation struct {
+	workspaces WorkspaceLookup
+
+	// idleSince holds the monotonic time each agent was first seen idle
+	// with an empty queue.
+	idleSince map[string]time.Duration
+}
+
+// WithHibernation hibernates agents left idle with an empty queue for
+// longer than their workspace's hibernate-after threshold, looked up
+// through workspaces. Hibernated agents are woken when items are queued
+// for them, before those are dispatched.
+func WithHibernation(workspaces WorkspaceLookup) Option {
+	return func(s *Scheduler) {
+		s.hibernation = &hibernation{
+			workspaces: workspaces,
+			idleSince:  make(map[string]time.Duration),
+		}
+	}
+}
+
+// checkHibernation hibernates agents idle past their workspace's
+// threshold. Idle time counts from the first tick that sees the agent idle
+// with nothing queued, so it restarts with the scheduler.
+func (s *Scheduler) checkHibernation(ctx context.Context, agents []*models.Agent) {
+	thresholds := make(map[string]time.Duration)
+	idle := make(map[string]struct{}, len(agents))
+
+	for _, a := range agents {
+		if a.State != models.AgentStateIdle || a.QueueLength > 0 {
+			continue
+		}
+		idle[a.ID] = struct{}{}
+		since := s.idleSinceFor(a.ID)
+
+		after, ok := thresholds[a.WorkspaceID]
+		if !ok {
+			after = s.hibernateAfter(ctx, a.WorkspaceID)
+			thresholds[a.WorkspaceID] = after
+		}
+		if after <= 0 || clock.Since(s.clock, since) < after {
+			continue
+		}
+		s.hibernate(ctx, a.ID, after)
+	}
+
+	s.pruneIdleSince(idle)
+}
+
+// hibernateAfter returns the workspace's hibernation threshold, or zero
+// when hibernation is off.
+func (s *Scheduler) hibernateAfter(ctx context.Context, workspaceID string) time.Duration {
+	ws, err := s.hibernation.workspaces.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("workspace_id", workspaceID).Msg("failed to get workspace for hibernation")
+		return 0
+	}
+	return ws.HibernateAfter()
+}
+
+// hibernate hibernates an agent unless a dispatch to it is in progress. An
+// agent that cannot be hibernated has its idle time restarted, so it is
+// not retried every tick.
+func (s *Scheduler) hibernate(ctx context.Context, agentID string, after time.Duration) {
+	if !s.tryLockAgentDispatch(agentID) {
+		return
+	}
+	defer s.unlockAgentDispatch(agentID)
+
+	reason := fmt.Sprintf("idle for %s", after)
+	if _, err := s.agentService.HibernateAgent(ctx, agentID, reason); err != nil {
+		s.logger.Warn().Err(err).Str("agent_id", agentID).Msg("failed to hibernate agent")
+		s.resetIdleSince(agentID)
+		return
+	}
+	s.logger.Info().Str("agent_id", agentID).Dur("idle", after).Msg("hibernated idle agent")
+}
+
+// wakeForDispatch wakes a hibernated agent so its queued items can be
+// dispatched. It reports whether dispatch can go ahead; agents that fail
+// to wake are backed off for the retry interval.
+func (s *Scheduler) wakeForDispatch(agentID string) bool {
+	a, err := s.agentService.GetAgent(s.ctx, agentID)
+	if err != nil || a.State != models.AgentStateHibernated {
+		return true
+	}
+
+	// Waking waits for the CLI to be ready, which can outlast a dispatch.
+	if _, err := s.agentService.WakeAgent(s.ctx, agentID, agent.WakeReasonQueue); err != nil && !errors.Is(err, agent.ErrAgentNotHibernated) {
+		s.logger.Warn().Err(err).Str("agent_id", agentID).Msg("failed to wake agent for dispatch")
+		s.setRetryAfter(agentID, clock.NewDeadline(s.clock, s.config.RetryBackoff))
+		return false
+	}
+	return true
+}
+
+func (s *Scheduler) idleSinceFor(agentID string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	since, ok := s.hibernation.idleSince[agentID]
+	if !ok {
+		since = s.clock.Monotonic()
+		s.hibernation.idleSince[agentID] = since
+	}
+	return since
+}
+
+func (s *Scheduler) resetIdleSince(agentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hibernation.idleSince[agentID] = s.clock.Monotonic()
+}
+
+// pruneIdleSince forgets agents that are no longer idle.
+func (s *Scheduler) pruneIdleSince(idle map[string]struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for agentID := range s.hibernation.idleSince {
+		if _, ok := idle[agentID]; !ok {
+			delete(s.hibernation.idleSince, agentID)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/checkpoint"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/queue"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+func TestScheduler_HibernatesIdleAgentAndWakesForQueuedItem(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := nodeRepo.Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	wsRepo := db.NewWorkspaceRepository(database)
+	ws := &models.Workspace{NodeID: localNode.ID, Name: "ws", RepoPath: "/repo", TmuxSession: "ws"}
+	if err := wsRepo.Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	if err := wsRepo.SetHibernateAfter(ctx, ws.ID, 3600); err != nil {
+		t.Fatalf("failed to set hibernate after: %v", err)
+	}
+
+	srv := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	for _, args := range [][]string{
+		{"new-session", "-d", "-s", "ws", "-c", "/repo"},
+		{"new-window", "-t", "ws", "-n", tmux.AgentWindowName, "-c", "/repo"},
+	} {
+		if _, err := srv.Run(args...); err != nil {
+			t.Fatalf("failed to seed tmux: %v", err)
+		}
+	}
+	paneID, err := srv.Run("split-window", "-t", "ws:agents", "-P", "-F", "#{pane_id}")
+	if err != nil {
+		t.Fatalf("failed to split pane: %v", err)
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sessionDir := filepath.Join(home, ".claude", "projects", "-repo")
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sessionDir, "session.jsonl"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	agentRepo := db.NewAgentRepository(database)
+	queueRepo := db.NewQueueRepository(database)
+	wsService := workspace.NewService(wsRepo, node.NewService(nodeRepo), agentRepo)
+	agentSvc := agent.NewService(agentRepo, queueRepo, wsService, nil, tmux.NewClient(srv),
+		agent.WithCheckpoints(checkpoint.NewStore(t.TempDir()), nil))
+	seeded := &models.Agent{
+		WorkspaceID: ws.ID,
+		Type:        models.AgentTypeClaudeCode,
+		TmuxPane:    strings.TrimSpace(paneID),
+		State:       models.AgentStateIdle,
+		StateInfo:   models.StateInfo{State: models.AgentStateIdle, Confidence: models.StateConfidenceHigh},
+	}
+	if err := agentRepo.Create(ctx, seeded); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	queueSvc := queue.NewService(queueRepo)
+	sched := New(DefaultConfig(), agentSvc, queueSvc, nil, nil, WithClock(fake), WithHibernation(wsService))
+	sched.ctx = ctx
+
+	// Idle time counts from the first tick that sees the agent idle.
+	sched.tick()
+	fake.Advance(59 * time.Minute)
+	sched.tick()
+	requireAgentState(t, agentSvc, seeded.ID, models.AgentStateIdle)
+
+	fake.Advance(time.Minute)
+	sched.tick()
+	requireAgentState(t, agentSvc, seeded.ID, models.AgentStateHibernated)
+
+	if err := queueSvc.Enqueue(ctx, seeded.ID, makeMessageItem("", "hello after sleep")); err != nil {
+		t.Fatalf("failed to enqueue item: %v", err)
+	}
+	sched.tick()
+	sched.wg.Wait()
+
+	woken, err := agentSvc.GetAgent(ctx, seeded.ID)
+	if err != nil {
+		t.Fatalf("GetAgent failed: %v", err)
+	}
+	if woken.State != models.AgentStateWorking || woken.Metadata.Hibernation != nil || woken.TmuxPane == seeded.TmuxPane {
+		t.Fatalf("expected the agent woken into a new pane and working, got %+v", woken)
+	}
+	content, err := srv.Capture(woken.TmuxPane)
+	if err != nil {
+		t.Fatalf("failed to capture pane: %v", err)
+	}
+	if !strings.Contains(content, "hello after sleep") {
+		t.Fatalf("expected the queued message dispatched to the woken agent, got %q", content)
+	}
+	if pending, err := queueRepo.Count(ctx, seeded.ID); err != nil || pending != 0 {
+		t.Fatalf("expected the queue drained, got %d (%v)", pending, err)
+	}
+	if stuck, err := queueRepo.ListInFlight(ctx, time.Now().Add(time.Hour)); err != nil || len(stuck) != 0 {
+		t.Fatalf("expected nothing left in flight, got %d (%v)", len(stuck), err)
+	}
+}
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/trace"
+)
+
+// WithDispatcherID sets the ID the scheduler claims queue items under.
+// It defaults to the host name and process ID.
+func WithDispatcherID(id string) Option {
+	return func(s *Scheduler) {
+		if id != "" {
+			s.dispatcherID = id
+		}
+	}
+}
+
+func defaultDispatcherID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	return fmt.Sprintf("%s/%d", host, os.Getpid())
+}
+
+// markDispatched records that an in-flight item reached its agent. An item
+// the dispatch already moved on, such as a skipped conditional, is left as
+// it is.
+func (s *Scheduler) markDispatched(ctx context.Context, item *models.QueueItem) {
+	if _, err := s.queueService.ReleaseInFlight(ctx, item.ID, models.QueueItemStatusDispatched, item.Attempts, ""); err != nil {
+		s.logger.Warn().
+			Ctx(ctx).
+			Err(err).
+			Str("agent_id", item.AgentID).
+			Str("item_id", item.ID).
+			Msg("failed to record dispatch")
+	}
+}
+
+// recoverInFlight settles the items that have been in flight longer than
+// InFlightTimeout. Their dispatcher crashed or lost the database between
+// claiming them and recording the send, so whether the agent received them
+// is unknown. Each counts as a failed attempt: it is re-queued while it has
+// retries left and dead-lettered as failed once it has none.
+func (s *Scheduler) recoverInFlight(ctx context.Context) {
+	if s.queueService == nil {
+		return
+	}
+	now := s.clock.Now().UTC()
+	items, err := s.queueService.ListInFlight(ctx, now.Add(-s.config.InFlightTimeout))
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to list in-flight queue items")
+		return
+	}
+	for _, item := range items {
+		if err := s.recoverItem(ctx, item); err != nil {
+			s.logger.Warn().
+				Err(err).
+				Str("agent_id", item.AgentID).
+				Str("item_id", item.ID).
+				Msg("failed to recover in-flight queue item")
+		}
+	}
+}
+
+func (s *Scheduler) recoverItem(ctx context.Context, item *models.QueueItem) error {
+	if item.TraceID != "" {
+		ctx = trace.WithID(ctx, item.TraceID)
+	}
+
+	attempts := item.Attempts + 1
+	action, status := models.InFlightRequeued, models.QueueItemStatusPending
+	if attempts > s.config.MaxRetries {
+		action, status = models.InFlightDeadLettered, models.QueueItemStatusFailed
+	}
+
+	reason := "left in flight by dispatcher " + item.DispatcherID
+	if item.DispatchedAt != nil {
+		reason += " since " + item.DispatchedAt.UTC().Format(time.RFC3339)
+	}
+	reason += "; delivery unknown"
+
+	released, err := s.queueService.ReleaseInFlight(ctx, item.ID, status, attempts, reason)
+	if err != nil {
+		return err
+	}
+	if !released {
+		// Its dispatcher recorded the send after all.
+		return nil
+	}
+
+	s.logger.Warn().
+		Ctx(ctx).
+		Str("agent_id", item.AgentID).
+		Str("item_id", item.ID).
+		Str("dispatcher_id", item.DispatcherID).
+		Str("action", action).
+		Int("attempt", attempts).
+		Int("max_retries", s.config.MaxRetries).
+		Msg("recovered queue item left in flight")
+
+	s.publishEvent(ctx, models.EventTypeMessageRecovered, models.EntityTypeQueue, item.ID, models.MessageRecoveredPayload{
+		QueueItemID:   item.ID,
+		ItemType:      item.Type,
+		AgentID:       item.AgentID,
+		DispatcherID:  item.DispatcherID,
+		InFlightSince: item.DispatchedAt,
+		Action:        action,
+		Attempts:      attempts,
+	})
+
+	if status == models.QueueItemStatusFailed {
+		s.trackFailed(ctx, item, reason)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/queue"
+)
+
+func TestScheduler_StartRecoversItemsLeftInFlight(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: localNode.ID, Name: "ws", RepoPath: "/repo", TmuxSession: "ws"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agentModel := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "ws:0.1", State: models.AgentStateIdle}
+	if err := db.NewAgentRepository(database).Create(ctx, agentModel); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	queueRepo := db.NewQueueRepository(database)
+	queueSvc := queue.NewService(queueRepo)
+	retried := makeMessageItem("retried", "first")
+	exhausted := makeMessageItem("exhausted", "second")
+	exhausted.Attempts = DefaultConfig().MaxRetries
+	fresh := makeMessageItem("fresh", "third")
+	if err := queueSvc.Enqueue(ctx, agentModel.ID, retried, exhausted, fresh); err != nil {
+		t.Fatalf("failed to enqueue items: %v", err)
+	}
+
+	// A dispatcher claims two items and crashes before recording either send.
+	for range 2 {
+		if _, err := queueSvc.Dequeue(ctx, agentModel.ID, "crashed/1"); err != nil {
+			t.Fatalf("Dequeue failed: %v", err)
+		}
+	}
+	stale := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	if _, err := database.ExecContext(ctx, `UPDATE queue_items SET dispatched_at = ? WHERE status = 'in_flight'`, stale); err != nil {
+		t.Fatalf("failed to age in-flight items: %v", err)
+	}
+	// Another dispatcher claimed the last item only a moment ago.
+	if _, err := queueSvc.Dequeue(ctx, agentModel.ID, "live/2"); err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+
+	eventRepo := db.NewEventRepository(database)
+	publisher := events.NewInMemoryPublisher(events.WithRepository(eventRepo))
+	sched := New(DefaultConfig(), nil, queueSvc, nil, nil,
+		WithClock(clock.NewFake(time.Now())), WithPublisher(publisher), WithDispatcherID("restarted/3"))
+	if err := sched.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := sched.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	got, err := queueRepo.Get(ctx, "retried")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != models.QueueItemStatusPending || got.Attempts != 1 || !strings.Contains(got.Error, "crashed/1") {
+		t.Fatalf("expected the item re-queued after a counted attempt, got %+v", got)
+	}
+
+	got, err = queueRepo.Get(ctx, "exhausted")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != models.QueueItemStatusFailed || got.Attempts != DefaultConfig().MaxRetries+1 || got.CompletedAt == nil {
+		t.Fatalf("expected the item out of retries dead-lettered, got %+v", got)
+	}
+
+	got, err = queueRepo.Get(ctx, "fresh")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != models.QueueItemStatusInFlight || got.DispatcherID != "live/2" {
+		t.Fatalf("expected the recently claimed item left in flight, got %+v", got)
+	}
+
+	eventType := models.EventTypeMessageRecovered
+	page, err := eventRepo.Query(ctx, db.EventQuery{Type: &eventType, Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to query events: %v", err)
+	}
+	actions := make(map[string]string)
+	for _, event := range page.Events {
+		var payload models.MessageRecoveredPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		if payload.DispatcherID != "crashed/1" || payload.InFlightSince == nil {
+			t.Fatalf("expected the crashed dispatcher in the payload, got %+v", payload)
+		}
+		actions[payload.QueueItemID] = payload.Action
+	}
+	if len(actions) != 2 || actions["retried"] != models.InFlightRequeued || actions["exhausted"] != models.InFlightDeadLettered {
+		t.Fatalf("expected a recovery event per stuck item, got %v", actions)
+	}
+}
+
+func TestScheduler_RecoverItemSkipsItemReleasedMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	eventRepo := newEventRepoForTest(t)
+	publisher := events.NewInMemoryPublisher(events.WithRepository(eventRepo))
+	sched := New(DefaultConfig(), nil, &releasedQueueService{newTrackingQueueService()}, nil, nil, WithPublisher(publisher))
+
+	item := makeMessageItem("item-1", "hello")
+	item.DispatcherID = "host/1"
+	if err := sched.recoverItem(ctx, item); err != nil {
+		t.Fatalf("recoverItem failed: %v", err)
+	}
+
+	eventType := models.EventTypeMessageRecovered
+	page, err := eventRepo.Query(ctx, db.EventQuery{Type: &eventType, Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to query events: %v", err)
+	}
+	if len(page.Events) != 0 {
+		t.Fatalf("expected no recovery recorded for an item its dispatcher released, got %d events", len(page.Events))
+	}
+}
+
+// releasedQueueService reports every in-flight item as already released,
+// as if its dispatcher recorded the send just before recovery.
+type releasedQueueService struct {
+	*trackingQueueService
+}
+
+func (m *releasedQueueService) ReleaseInFlight(ctx context.Context, itemID string, status models.QueueItemStatus, attempts int, errorMsg string) (bool, error) {
+	return false, nil
+}
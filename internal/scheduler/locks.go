@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agentmail"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+	"github.com/rs/zerolog"
+)
+
+// File lock errors.
+var (
+	ErrFileLockConflict    = errors.New("file lock conflict")
+	ErrFileLockUnavailable = errors.New("file lock server unavailable")
+)
+
+// LockFailurePolicy decides how dispatch proceeds when the Agent Mail
+// server cannot be reached.
+type LockFailurePolicy string
+
+const (
+	// LockFailureWarn logs a warning and dispatches without locks.
+	LockFailureWarn LockFailurePolicy = "warn"
+	// LockFailureBlock re-queues the item until the server is reachable.
+	LockFailureBlock LockFailurePolicy = "block"
+)
+
+// FileLockConfig configures Agent Mail file locks around dispatches.
+type FileLockConfig struct {
+	// Project is the Agent Mail project key (usually the repo root).
+	Project string
+
+	// TTL bounds how long locks are held if the agent never returns to idle.
+	// Default: 30 minutes.
+	TTL time.Duration
+
+	// RetryBackoff is how long to wait before retrying a blocked item.
+	// Default: 10 seconds.
+	RetryBackoff time.Duration
+
+	// OnUnavailable is the policy when the server errors. Default: warn.
+	OnUnavailable LockFailurePolicy
+}
+
+// DefaultFileLockConfig returns the default file lock settings.
+func DefaultFileLockConfig() FileLockConfig {
+	return FileLockConfig{
+		TTL:           30 * time.Minute,
+		RetryBackoff:  10 * time.Second,
+		OnUnavailable: LockFailureWarn,
+	}
+}
+
+// FileLockConfigFor returns the default file lock settings for project,
+// with the failure policy set by cfg's scheduler.file_lock_failure.
+func FileLockConfigFor(cfg *config.Config, project string) FileLockConfig {
+	lockConfig := DefaultFileLockConfig()
+	lockConfig.Project = project
+	if cfg != nil && cfg.Scheduler.FileLockFailure == config.FileLockFailureBlock {
+		lockConfig.OnUnavailable = LockFailureBlock
+	}
+	return lockConfig
+}
+
+// WithFileLocks reserves MessagePayload.LockPaths through client before
+// dispatching, on behalf of the receiving agent. Locks are released when the
+// agent returns to idle, when the dispatch fails, or after cfg.TTL.
+func WithFileLocks(client *agentmail.Client, cfg FileLockConfig) Option {
+	return func(s *Scheduler) {
+		s.locks = newFileLocker(client, cfg)
+	}
+}
+
+// heldLocks tracks the reservation taken for one dispatched item.
+type heldLocks struct {
+	itemID     string
+	paths      []string
+	acquiredAt time.Time
+	expires    clock.Deadline
+}
+
+// fileLocker claims and releases Agent Mail reservations per agent.
+type fileLocker struct {
+	client *agentmail.Client
+	config FileLockConfig
+
+	mu   sync.Mutex
+	held map[string]heldLocks
+}
+
+func newFileLocker(client *agentmail.Client, cfg FileLockConfig) *fileLocker {
+	defaults := DefaultFileLockConfig()
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaults.TTL
+	}
+	if cfg.TTL < time.Minute {
+		cfg.TTL = time.Minute
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaults.RetryBackoff
+	}
+	if cfg.OnUnavailable != LockFailureBlock {
+		cfg.OnUnavailable = LockFailureWarn
+	}
+	return &fileLocker{
+		client: client,
+		config: cfg,
+		held:   make(map[string]heldLocks),
+	}
+}
+
+// acquire claims paths for the agent. It returns ErrFileLockConflict when
+// another agent holds one of them, and ErrFileLockUnavailable when the
+// server fails under the block policy. Locks the agent still holds for an
+// earlier item are released first: the agent only takes a new item once it
+// is done with the last, even if its idle change was missed.
+func (l *fileLocker) acquire(ctx context.Context, c clock.Clock, logger zerolog.Logger, agentID, itemID string, paths []string) error {
+	l.release(ctx, logger, agentID, "superseded by "+itemID)
+
+	result, err := l.client.ClaimPaths(ctx, agentmail.ClaimRequest{
+		Project:    l.config.Project,
+		Agent:      agentID,
+		Paths:      paths,
+		TTLSeconds: int(l.config.TTL.Round(time.Second).Seconds()),
+		Exclusive:  true,
+		Reason:     "swarm dispatch " + itemID,
+	})
+	if err != nil {
+		if l.config.OnUnavailable == LockFailureBlock {
+			return fmt.Errorf("%w: %v", ErrFileLockUnavailable, err)
+		}
+		logger.Warn().
+			Err(err).
+			Str("agent_id", agentID).
+			Str("item_id", itemID).
+			Msg("file lock server unavailable, dispatching without locks")
+		return nil
+	}
+
+	if len(result.Conflicts) > 0 {
+		// Drop any partial grant so the other holder can finish.
+		if len(result.Granted) > 0 {
+			if _, err := l.client.ReleasePaths(ctx, l.config.Project, agentID, paths, nil); err != nil {
+				logger.Warn().Err(err).Str("agent_id", agentID).Msg("failed to release partial file locks")
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrFileLockConflict, describeConflicts(result.Conflicts))
+	}
+
+	l.mu.Lock()
+	l.held[agentID] = heldLocks{
+		itemID:     itemID,
+		paths:      paths,
+		acquiredAt: c.Now().UTC(),
+		expires:    clock.NewDeadline(c, l.config.TTL),
+	}
+	l.mu.Unlock()
+	return nil
+}
+
+// release drops the agent's locks, if any.
+func (l *fileLocker) release(ctx context.Context, logger zerolog.Logger, agentID, reason string) {
+	l.mu.Lock()
+	held, ok := l.held[agentID]
+	delete(l.held, agentID)
+	l.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if _, err := l.client.ReleasePaths(ctx, l.config.Project, agentID, held.paths, nil); err != nil {
+		logger.Warn().
+			Err(err).
+			Str("agent_id", agentID).
+			Str("item_id", held.itemID).
+			Msg("failed to release file locks; they expire with their TTL")
+		return
+	}
+	logger.Debug().
+		Str("agent_id", agentID).
+		Str("item_id", held.itemID).
+		Str("reason", reason).
+		Msg("file locks released")
+}
+
+// releaseOnIdle releases locks when the agent goes idle after the task that
+// took them was dispatched.
+func (l *fileLocker) releaseOnIdle(ctx context.Context, logger zerolog.Logger, change state.StateChange) {
+	if change.CurrentState != models.AgentStateIdle {
+		return
+	}
+
+	l.mu.Lock()
+	held, ok := l.held[change.AgentID]
+	l.mu.Unlock()
+	if !ok || change.Timestamp.Before(held.acquiredAt) {
+		return
+	}
+
+	l.release(ctx, logger, change.AgentID, "agent idle")
+}
+
+// releaseExpired releases locks held past their TTL.
+func (l *fileLocker) releaseExpired(ctx context.Context, c clock.Clock, logger zerolog.Logger) {
+	l.mu.Lock()
+	expired := make([]string, 0)
+	for agentID, held := range l.held {
+		if held.expires.Expired(c) {
+			expired = append(expired, agentID)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, agentID := range expired {
+		l.release(ctx, logger, agentID, "ttl expired")
+	}
+}
+
+// lockPathsFor returns the paths a message item asks to reserve.
+func lockPathsFor(item *models.QueueItem) []string {
+	if item == nil || item.Type != models.QueueItemTypeMessage {
+		return nil
+	}
+	var payload models.MessagePayload
+	if err := json.Unmarshal(item.Payload, &payload); err != nil {
+		return nil
+	}
+	paths := make([]string, 0, len(payload.LockPaths))
+	for _, p := range payload.LockPaths {
+		if p = agentmail.NormalizePath(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+func describeConflicts(conflicts []agentmail.ReservationConflict) string {
+	parts := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		holders := make([]string, 0, len(conflict.Holders))
+		for _, holder := range conflict.Holders {
+			holders = append(holders, holder.Agent)
+		}
+		if len(holders) == 0 {
+			parts = append(parts, conflict.Path)
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s held by %s", conflict.Path, strings.Join(holders, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
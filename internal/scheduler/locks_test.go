@@ -0,0 +1,241 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agentmail"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/rs/zerolog"
+)
+
+type stubToolCall struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// stubAgentMail is an MCP server answering file reservation tool calls.
+type stubAgentMail struct {
+	mu        sync.Mutex
+	calls     []stubToolCall
+	conflicts []agentmail.ReservationConflict
+}
+
+func (s *stubAgentMail) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Params stubToolCall `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, request.Params)
+	conflicts := s.conflicts
+	s.mu.Unlock()
+
+	var result any
+	switch request.Params.Name {
+	case "file_reservation_paths":
+		claim := agentmail.ClaimResult{Conflicts: conflicts}
+		if len(conflicts) == 0 {
+			claim.Granted = []agentmail.ReservationGrant{{ID: 1, PathPattern: "src/*.go", Exclusive: true}}
+		}
+		result = claim
+	case "release_file_reservations":
+		result = agentmail.ReleaseResult{Released: 1}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": "1", "result": result})
+}
+
+func (s *stubAgentMail) toolCalls(name string) []stubToolCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := make([]stubToolCall, 0)
+	for _, call := range s.calls {
+		if call.Name == name {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func makeLockedMessageItem(id, text string, paths ...string) *models.QueueItem {
+	item := makeMessageItem(id, text)
+	item.Payload, _ = json.Marshal(models.MessagePayload{Text: text, LockPaths: paths})
+	return item
+}
+
+func TestScheduler_FileLocks_ClaimAndReleaseOnIdle(t *testing.T) {
+	stub := &stubAgentMail{}
+	mail := httptest.NewServer(stub)
+	defer mail.Close()
+
+	srv := newDispatchTmux(t)
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, tmux.NewClient(srv))
+	defer cleanup()
+
+	queueSvc := newTrackingQueueService()
+	_ = queueSvc.Enqueue(context.Background(), agentID, makeLockedMessageItem("item-1", "refactor", "src/*.go"))
+
+	sched := New(DefaultConfig(), agentSvc, queueSvc, nil, nil,
+		WithFileLocks(agentmail.NewClient(mail.URL, time.Second), FileLockConfig{Project: "/repo"}))
+	sched.ctx = context.Background()
+
+	sched.dispatchToAgent(agentID)
+
+	if !paneShows(t, srv, "refactor") {
+		t.Fatalf("expected message to be dispatched, got %v", srv.Commands())
+	}
+	claims := stub.toolCalls("file_reservation_paths")
+	if len(claims) != 1 {
+		t.Fatalf("expected 1 claim, got %d", len(claims))
+	}
+	if claims[0].Arguments["agent_name"] != agentID || claims[0].Arguments["project_key"] != "/repo" {
+		t.Fatalf("unexpected claim arguments: %v", claims[0].Arguments)
+	}
+
+	// Going busy does not release; going idle afterwards does.
+	sched.onStateChange(state.StateChange{AgentID: agentID, PreviousState: models.AgentStateIdle, CurrentState: models.AgentStateWorking, Timestamp: time.Now()})
+	if got := len(stub.toolCalls("release_file_reservations")); got != 0 {
+		t.Fatalf("expected no release while working, got %d", got)
+	}
+	sched.onStateChange(state.StateChange{AgentID: agentID, PreviousState: models.AgentStateWorking, CurrentState: models.AgentStateIdle, Timestamp: time.Now()})
+	releases := stub.toolCalls("release_file_reservations")
+	if len(releases) != 1 {
+		t.Fatalf("expected 1 release on idle, got %d", len(releases))
+	}
+	if releases[0].Arguments["agent_name"] != agentID {
+		t.Fatalf("unexpected release arguments: %v", releases[0].Arguments)
+	}
+
+	// A second idle notification has nothing left to release.
+	sched.onStateChange(state.StateChange{AgentID: agentID, PreviousState: models.AgentStateWorking, CurrentState: models.AgentStateIdle, Timestamp: time.Now()})
+	if got := len(stub.toolCalls("release_file_reservations")); got != 1 {
+		t.Fatalf("expected release to happen once, got %d", got)
+	}
+}
+
+func TestScheduler_FileLocks_ConflictRequeues(t *testing.T) {
+	stub := &stubAgentMail{conflicts: []agentmail.ReservationConflict{{
+		Path:    "src/*.go",
+		Holders: []agentmail.ReservationHolder{{ID: 9, Agent: "other-agent"}},
+	}}}
+	mail := httptest.NewServer(stub)
+	defer mail.Close()
+
+	srv := newDispatchTmux(t)
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, tmux.NewClient(srv))
+	defer cleanup()
+
+	queueSvc := newTrackingQueueService()
+	_ = queueSvc.Enqueue(context.Background(), agentID, makeLockedMessageItem("item-1", "refactor", "src/*.go"))
+
+	sched := New(DefaultConfig(), agentSvc, queueSvc, nil, nil,
+		WithFileLocks(agentmail.NewClient(mail.URL, time.Second), FileLockConfig{Project: "/repo"}))
+	sched.ctx = context.Background()
+
+	sched.dispatchToAgent(agentID)
+
+	if paneShows(t, srv, "refactor") {
+		t.Fatal("expected dispatch to be deferred on lock conflict")
+	}
+	if len(queueSvc.statusUpdates) != 1 || queueSvc.statusUpdates[0].status != models.QueueItemStatusPending {
+		t.Fatalf("expected item re-queued as pending, got %+v", queueSvc.statusUpdates)
+	}
+	if !strings.Contains(queueSvc.statusUpdates[0].errorMsg, "other-agent") {
+		t.Fatalf("expected conflict holder in status message, got %q", queueSvc.statusUpdates[0].errorMsg)
+	}
+	if !sched.isRetryBackoffActive(agentID) {
+		t.Fatal("expected backoff after lock conflict")
+	}
+}
+
+func TestScheduler_FileLocks_ServerUnavailablePolicy(t *testing.T) {
+	mail := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer mail.Close()
+
+	tests := []struct {
+		name     string
+		policy   string
+		wantSent bool
+	}{
+		{name: "warn proceeds", policy: config.FileLockFailureWarn, wantSent: true},
+		{name: "block requeues", policy: config.FileLockFailureBlock, wantSent: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newDispatchTmux(t)
+			agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, tmux.NewClient(srv))
+			defer cleanup()
+
+			queueSvc := newTrackingQueueService()
+			_ = queueSvc.Enqueue(context.Background(), agentID, makeLockedMessageItem("item-1", "refactor", "src/*.go"))
+
+			cfg := config.DefaultConfig()
+			cfg.Scheduler.FileLockFailure = tt.policy
+			sched := New(DefaultConfig(), agentSvc, queueSvc, nil, nil,
+				WithFileLocks(agentmail.NewClient(mail.URL, time.Second), FileLockConfigFor(cfg, "/repo")))
+			sched.ctx = context.Background()
+
+			sched.dispatchToAgent(agentID)
+
+			if got := paneShows(t, srv, "refactor"); got != tt.wantSent {
+				t.Fatalf("expected sent=%v, got %v", tt.wantSent, got)
+			}
+			if !tt.wantSent && !sched.isRetryBackoffActive(agentID) {
+				t.Fatal("expected backoff while lock server is down")
+			}
+		})
+	}
+}
+
+func TestFileLocker_AcquireReleasesPreviousClaim(t *testing.T) {
+	stub := &stubAgentMail{}
+	mail := httptest.NewServer(stub)
+	defer mail.Close()
+
+	ctx := context.Background()
+	logger := zerolog.Nop()
+	locker := newFileLocker(agentmail.NewClient(mail.URL, time.Second), FileLockConfig{Project: "/repo"})
+
+	// Two dispatches without an idle change in between.
+	if err := locker.acquire(ctx, clock.Real(), logger, "agent-1", "item-1", []string{"src/a.go"}); err != nil {
+		t.Fatalf("first acquire failed: %v", err)
+	}
+	if err := locker.acquire(ctx, clock.Real(), logger, "agent-1", "item-2", []string{"src/b.go"}); err != nil {
+		t.Fatalf("second acquire failed: %v", err)
+	}
+
+	releases := stub.toolCalls("release_file_reservations")
+	if len(releases) != 1 {
+		t.Fatalf("expected the first claim released, got %d releases", len(releases))
+	}
+	if paths, _ := releases[0].Arguments["paths"].([]any); len(paths) != 1 || paths[0] != "src/a.go" {
+		t.Fatalf("expected src/a.go released, got %v", releases[0].Arguments["paths"])
+	}
+
+	// The next idle change releases what the second item took.
+	locker.releaseOnIdle(ctx, logger, state.StateChange{AgentID: "agent-1", CurrentState: models.AgentStateIdle, Timestamp: time.Now()})
+	releases = stub.toolCalls("release_file_reservations")
+	if len(releases) != 2 {
+		t.Fatalf("expected the second claim released on idle, got %d releases", len(releases))
+	}
+	if paths, _ := releases[1].Arguments["paths"].([]any); len(paths) != 1 || paths[0] != "src/b.go" {
+		t.Fatalf("expected src/b.go released, got %v", releases[1].Arguments["paths"])
+	}
+}
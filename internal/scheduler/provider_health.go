@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ProviderHealth reports which providers are down. providerhealth.Prober
+// implements it.
+type ProviderHealth interface {
+	IsDown(provider models.Provider) bool
+}
+
+// agentTypeProviders are the providers of agent CLIs that talk to one
+// provider only, for agents without an account.
+var agentTypeProviders = map[models.AgentType]models.Provider{
+	models.AgentTypeClaudeCode: models.ProviderAnthropic,
+	models.AgentTypeCodex:      models.ProviderOpenAI,
+	models.AgentTypeGemini:     models.ProviderGoogle,
+}
+
+// WithProviderHealth holds dispatch to agents whose provider is down;
+// their items stay queued until it recovers. Rate limits reported while
+// the provider is down do not put the agent's account on cooldown, since
+// rotating to another account of the same provider would not help.
+func WithProviderHealth(health ProviderHealth) Option {
+	return func(s *Scheduler) {
+		s.providerHealth = health
+	}
+}
+
+// agentProvider returns the provider the agent talks to: its account's,
+// or the one its agent type implies.
+func (s *Scheduler) agentProvider(ctx context.Context, a *models.Agent) (models.Provider, bool) {
+	if a.AccountID != "" && s.accountService != nil {
+		if acct, err := s.accountService.Get(ctx, a.AccountID); err == nil {
+			return acct.Provider, true
+		}
+	}
+	provider, ok := agentTypeProviders[a.Type]
+	return provider, ok
+}
+
+// providerDown reports whether the agent's provider is down.
+func (s *Scheduler) providerDown(ctx context.Context, a *models.Agent) (models.Provider, bool) {
+	if s.providerHealth == nil {
+		return "", false
+	}
+	provider, ok := s.agentProvider(ctx, a)
+	if !ok {
+		return "", false
+	}
+	return provider, s.providerHealth.IsDown(provider)
+}
+
+// holdForProviderOutage reports whether dispatch to the agent waits for its
+// provider to recover.
+func (s *Scheduler) holdForProviderOutage(ctx context.Context, a *models.Agent) bool {
+	provider, down := s.providerDown(ctx, a)
+	if !down {
+		return false
+	}
+
+	s.statsMu.Lock()
+	s.stats.ProviderOutageSkips++
+	s.statsMu.Unlock()
+
+	s.logger.Debug().
+		Str("agent_id", a.ID).
+		Str("provider", string(provider)).
+		Msg("provider down, skipping dispatch")
+	return true
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+type fakeProviderHealth struct {
+	mu   sync.Mutex
+	down map[models.Provider]bool
+}
+
+func (h *fakeProviderHealth) IsDown(provider models.Provider) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.down[provider]
+}
+
+func (h *fakeProviderHealth) set(provider models.Provider, down bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.down[provider] = down
+}
+
+func newProviderHealthAgent(accountID string) *models.Agent {
+	return &models.Agent{
+		Type:      models.AgentTypeClaudeCode,
+		AccountID: accountID,
+		State:     models.AgentStateIdle,
+		StateInfo: models.StateInfo{
+			State:      models.AgentStateIdle,
+			Confidence: models.StateConfidenceHigh,
+			DetectedAt: time.Now().UTC(),
+		},
+	}
+}
+
+func TestScheduler_ProviderOutageHoldsDispatch(t *testing.T) {
+	srv := newDispatchTmux(t)
+	agentSvc, agentID, cleanup := setupDispatchAgent(t, newProviderHealthAgent(""), tmux.NewClient(srv))
+	defer cleanup()
+
+	queueSvc := newTrackingQueueService()
+	if err := queueSvc.Enqueue(context.Background(), agentID, makeMessageItem("item-1", "hello")); err != nil {
+		t.Fatalf("failed to enqueue item: %v", err)
+	}
+
+	health := &fakeProviderHealth{down: map[models.Provider]bool{models.ProviderAnthropic: true}}
+	sched := New(DefaultConfig(), agentSvc, queueSvc, nil, nil, WithProviderHealth(health))
+	sched.ctx = context.Background()
+
+	sched.dispatchToAgent(agentID)
+	if got := queueSvc.queueLength(agentID); got != 1 {
+		t.Fatalf("expected the item to stay queued during the outage, got %d queued", got)
+	}
+	if got := sched.Stats().ProviderOutageSkips; got != 1 {
+		t.Fatalf("expected 1 provider outage skip, got %d", got)
+	}
+
+	health.set(models.ProviderAnthropic, false)
+	sched.dispatchToAgent(agentID)
+	if got := queueSvc.queueLength(agentID); got != 0 {
+		t.Fatalf("expected the item dispatched once the provider recovered, got %d queued", got)
+	}
+	if !paneShows(t, srv, "hello") {
+		t.Fatal("expected the message sent to the pane")
+	}
+	if got := sched.Stats().ProviderOutageSkips; got != 1 {
+		t.Fatalf("expected no further skips, got %d", got)
+	}
+}
+
+func TestScheduler_ProviderOutageSkipsRateLimitCooldown(t *testing.T) {
+	ctx := context.Background()
+	acct := &models.Account{ID: "acct-1", Provider: models.ProviderOpenAI, ProfileName: "primary", CredentialRef: "env:OPENAI_API_KEY", IsActive: true}
+	agentSvc, agentID, cleanup := setupDispatchAgent(t, newProviderHealthAgent(acct.ID), nil, acct)
+	defer cleanup()
+
+	accountSvc := account.NewService(config.DefaultConfig())
+	if err := accountSvc.AddAccount(ctx, acct); err != nil {
+		t.Fatalf("failed to add account: %v", err)
+	}
+
+	// The account's provider wins over the one the agent type implies.
+	health := &fakeProviderHealth{down: map[models.Provider]bool{models.ProviderOpenAI: true}}
+	queueSvc := newMockQueueService()
+	sched := New(DefaultConfig(), agentSvc, queueSvc, nil, accountSvc, WithProviderHealth(health))
+	sched.ctx = ctx
+
+	change := state.StateChange{AgentID: agentID, StateInfo: models.StateInfo{Reason: "rate limited"}}
+	sched.handleRateLimit(change)
+	if onCooldown, _, err := accountSvc.IsOnCooldown(ctx, acct.ID); err != nil || onCooldown {
+		t.Fatalf("expected no cooldown while the provider is down, got %v, %v", onCooldown, err)
+	}
+	if items, _ := queueSvc.List(ctx, agentID); len(items) != 1 || items[0].Type != models.QueueItemTypePause {
+		t.Fatalf("expected the agent still paused, got %+v", items)
+	}
+
+	health.set(models.ProviderOpenAI, false)
+	sched.handleRateLimit(change)
+	if onCooldown, _, err := accountSvc.IsOnCooldown(ctx, acct.ID); err != nil || !onCooldown {
+		t.Fatalf("expected a cooldown once the provider is up, got %v, %v", onCooldown, err)
+	}
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/queue"
+	"github.com/opencode-ai/swarm/internal/quiethours"
+)
+
+// WorkspaceLookup returns workspaces by ID. workspace.Service implements it.
+type WorkspaceLookup interface {
+	GetWorkspace(ctx context.Context, id string) (*models.Workspace, error)
+}
+
+// quietHours holds what the scheduler needs to resolve an agent's quiet
+// hours.
+type quietHours struct {
+	global     string
+	workspaces WorkspaceLookup
+	evaluator  *quiethours.Evaluator
+}
+
+// WithQuietHours holds dispatch to agents during their quiet hours; their
+// items stay queued until the quiet hours end. An agent's own spec wins
+// over its workspace's, looked up through workspaces when non-nil, which
+// wins over the global spec. Items flagged IgnoreQuietHours are
+// dispatched regardless.
+func WithQuietHours(global string, workspaces WorkspaceLookup) Option {
+	return func(s *Scheduler) {
+		s.quietHours = &quietHours{global: global, workspaces: workspaces}
+	}
+}
+
+// quietHoursUntil reports whether the agent is in quiet hours and, if so,
+// when they end.
+func (s *Scheduler) quietHoursUntil(ctx context.Context, a *models.Agent) (time.Time, bool) {
+	var workspaceSpec string
+	if s.quietHours.workspaces != nil && a.Metadata.QuietHours == "" {
+		ws, err := s.quietHours.workspaces.GetWorkspace(ctx, a.WorkspaceID)
+		if err != nil {
+			s.logger.Warn().Err(err).Str("agent_id", a.ID).Str("workspace_id", a.WorkspaceID).Msg("failed to get workspace for quiet hours")
+		} else {
+			workspaceSpec = ws.QuietHours
+		}
+	}
+
+	schedule, err := quiethours.Resolve(a.Metadata.QuietHours, workspaceSpec, s.quietHours.global)
+	if err != nil {
+		// Specs are validated when set; an unreadable one holds nothing.
+		s.logger.Warn().Err(err).Str("agent_id", a.ID).Msg("invalid quiet hours, ignoring")
+		return time.Time{}, false
+	}
+	return s.quietHours.evaluator.Until(schedule)
+}
+
+// holdForQuietHours reports whether dispatch to the agent waits for its
+// quiet hours to end. The head item may override them.
+func (s *Scheduler) holdForQuietHours(ctx context.Context, a *models.Agent) bool {
+	if s.quietHours == nil {
+		return false
+	}
+	until, quiet := s.quietHoursUntil(ctx, a)
+	if !quiet {
+		return false
+	}
+
+	head, err := s.queueService.Peek(ctx, a.ID)
+	if err != nil {
+		if !errors.Is(err, queue.ErrQueueEmpty) {
+			s.logger.Warn().Err(err).Str("agent_id", a.ID).Msg("failed to peek queue during quiet hours")
+		}
+		return true
+	}
+	if head.IgnoreQuietHours {
+		return false
+	}
+
+	s.statsMu.Lock()
+	s.stats.QuietHoursSkips++
+	s.statsMu.Unlock()
+
+	s.logger.Debug().
+		Str("agent_id", a.ID).
+		Time("until", until).
+		Msg("quiet hours, skipping dispatch")
+	return true
+}
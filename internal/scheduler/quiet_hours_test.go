@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+type stubWorkspaceLookup struct {
+	quietHours string
+}
+
+func (l stubWorkspaceLookup) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	return &models.Workspace{ID: id, QuietHours: l.quietHours}, nil
+}
+
+func TestScheduler_QuietHoursHoldDispatch(t *testing.T) {
+	// 2026-10-14 23:00 UTC is inside the nightly window.
+	night := time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		workspace string
+		ignore    bool
+		at        time.Time
+		held      bool
+	}{
+		{name: "global window holds", at: night, held: true},
+		{name: "outside the window", at: night.Add(8 * time.Hour), held: false},
+		{name: "item ignores quiet hours", ignore: true, at: night, held: false},
+		{name: "workspace turns quiet hours off", workspace: "none", at: night, held: false},
+		{name: "workspace window wins", workspace: "daily 06:00-09:00 UTC", at: night.Add(8 * time.Hour), held: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newDispatchTmux(t)
+			agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, tmux.NewClient(srv))
+			defer cleanup()
+
+			item := makeMessageItem("item-1", "hello")
+			item.IgnoreQuietHours = tt.ignore
+			queueSvc := newTrackingQueueService()
+			if err := queueSvc.Enqueue(context.Background(), agentID, item); err != nil {
+				t.Fatalf("failed to enqueue item: %v", err)
+			}
+
+			sched := New(DefaultConfig(), agentSvc, queueSvc, nil, nil,
+				WithClock(clock.NewFake(tt.at)),
+				WithQuietHours("daily 22:00-07:00 UTC", stubWorkspaceLookup{quietHours: tt.workspace}))
+			sched.ctx = context.Background()
+
+			sched.dispatchToAgent(agentID)
+
+			wantQueued, wantSkips := 0, int64(0)
+			if tt.held {
+				wantQueued, wantSkips = 1, 1
+			}
+			if got := queueSvc.queueLength(agentID); got != wantQueued {
+				t.Errorf("expected %d items still queued, got %d", wantQueued, got)
+			}
+			if got := sched.Stats().QuietHoursSkips; got != wantSkips {
+				t.Errorf("expected %d quiet hours skips, got %d", wantSkips, got)
+			}
+		})
+	}
+}
+
+func TestScheduler_AgentQuietHoursOverrideWorkspace(t *testing.T) {
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, nil)
+	defer cleanup()
+
+	sched := New(DefaultConfig(), agentSvc, newTrackingQueueService(), nil, nil,
+		WithClock(clock.NewFake(time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC))),
+		WithQuietHours("", stubWorkspaceLookup{quietHours: "daily 12:00-13:00 UTC"}))
+
+	a := &models.Agent{ID: agentID, Metadata: models.AgentMetadata{QuietHours: "none"}}
+	if _, quiet := sched.quietHoursUntil(context.Background(), a); quiet {
+		t.Error("expected the agent's own setting to override its workspace")
+	}
+	a.Metadata.QuietHours = ""
+	until, quiet := sched.quietHoursUntil(context.Background(), a)
+	if !quiet || !until.Equal(time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the workspace's quiet hours until 13:00, got %s, %v", until, quiet)
+	}
+}
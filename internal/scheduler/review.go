@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/review"
+	"github.com/opencode-ai/swarm/internal/workspace"
+)
+
+// ErrReviewItemsDisabled is returned when a review item is dispatched by a
+// scheduler without WithReviewItems.
+var ErrReviewItemsDisabled = errors.New("review queue items are disabled")
+
+// reviewDiffTimeout bounds how long taking a review's diff may take.
+const reviewDiffTimeout = time.Minute
+
+// reviewRunner holds what the scheduler needs to send review items.
+type reviewRunner struct {
+	config     *config.Config
+	workspaces *workspace.Service
+	nodes      *node.Service
+	repo       *db.ReviewRepository
+}
+
+// WithReviewItems lets the scheduler dispatch review queue items. The diff
+// is taken with git on the agent's workspace node and sent with the review
+// prompt from cfg, and the review is recorded in repo as awaiting a
+// verdict, which a review.Collector records once the agent answers.
+// Without this option review items fail with ErrReviewItemsDisabled.
+func WithReviewItems(cfg *config.Config, workspaces *workspace.Service, nodes *node.Service, repo *db.ReviewRepository) Option {
+	return func(s *Scheduler) {
+		s.reviews = &reviewRunner{config: cfg, workspaces: workspaces, nodes: nodes, repo: repo}
+	}
+}
+
+// dispatchReview takes the diff a review item refers to and sends it to
+// the agent with the review prompt. A review that cannot be sent is
+// marked failed; a retry that succeeds sends it again.
+func (s *Scheduler) dispatchReview(ctx context.Context, agentID string, item *models.QueueItem) error {
+	payload, err := item.GetReviewPayload()
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal review payload: %w", err)
+	}
+	if s.reviews == nil {
+		return ErrReviewItemsDisabled
+	}
+
+	record, err := s.reviewRecord(ctx, agentID, item, payload)
+	if err != nil {
+		return err
+	}
+	if err := s.sendReview(ctx, agentID, payload, record); err != nil {
+		record.Status = models.ReviewStatusFailed
+		record.Error = err.Error()
+		if updateErr := s.reviews.repo.Update(context.Background(), record); updateErr != nil {
+			s.logger.Warn().Err(updateErr).Str("review_id", record.ID).Msg("failed to mark review failed")
+		}
+		return err
+	}
+	return nil
+}
+
+// reviewRecord returns the review recorded for item, creating it for
+// items queued without one.
+func (s *Scheduler) reviewRecord(ctx context.Context, agentID string, item *models.QueueItem, payload *models.ReviewPayload) (*models.Review, error) {
+	record, err := s.reviews.repo.GetByQueueItem(ctx, item.ID)
+	if err == nil {
+		return record, nil
+	}
+	if !errors.Is(err, db.ErrReviewNotFound) {
+		return nil, fmt.Errorf("failed to load review: %w", err)
+	}
+	record = &models.Review{
+		QueueItemID: item.ID,
+		AgentID:     agentID,
+		Range:       payload.Range,
+		PatchFile:   payload.PatchFile,
+	}
+	if err := s.reviews.repo.Create(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to record review: %w", err)
+	}
+	return record, nil
+}
+
+func (s *Scheduler) sendReview(ctx context.Context, agentID string, payload *models.ReviewPayload, record *models.Review) error {
+	agentModel, err := s.agentService.GetAgent(ctx, agentID)
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+	ws, err := s.reviews.workspaces.GetWorkspace(ctx, agentModel.WorkspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace: %w", err)
+	}
+	nodeModel, err := s.reviews.nodes.GetNode(ctx, ws.NodeID)
+	if err != nil {
+		return fmt.Errorf("failed to get node: %w", err)
+	}
+
+	base := s.ctx
+	if base == nil {
+		base = context.Background()
+	}
+	diff, err := s.reviewDiff(base, nodeModel, ws.RepoPath, payload)
+	if err != nil {
+		return err
+	}
+	if len(diff.Files) == 0 {
+		return fmt.Errorf("no changes to review in %s", payload.Source())
+	}
+
+	var cfg config.ReviewConfig
+	if s.reviews.config != nil {
+		cfg = s.reviews.config.Review
+	}
+	prompt, err := review.RenderPrompt(cfg.PromptTemplate, review.PromptData{
+		ReviewID:     record.ID,
+		Source:       payload.Source(),
+		Files:        diff.Files,
+		Omitted:      diff.Omitted,
+		Truncated:    diff.Truncated,
+		Diff:         strings.TrimRight(diff.Text, "\n"),
+		Instructions: strings.TrimSpace(payload.Instructions),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Record the review as sent first, so an agent answering quickly
+	// still finds it awaiting a verdict.
+	now := s.clock.Now().UTC()
+	record.Status = models.ReviewStatusAwaitingVerdict
+	record.DispatchedAt = &now
+	record.CompletedAt = nil
+	record.Error = ""
+	record.DiffFiles = len(diff.Files)
+	record.DiffBytes = diff.Bytes
+	record.Truncated = diff.Truncated
+	if err := s.reviews.repo.Update(ctx, record); err != nil {
+		return fmt.Errorf("failed to update review: %w", err)
+	}
+
+	sendCtx, cancel := context.WithTimeout(base, s.config.DispatchTimeout)
+	defer cancel()
+	if err := s.agentService.SendMessage(sendCtx, agentID, prompt, &agent.SendMessageOptions{}); err != nil {
+		return fmt.Errorf("failed to send review: %w", err)
+	}
+	return nil
+}
+
+// reviewDiff takes the payload's diff on n and caps it at the item's limit,
+// falling back to the config's and then the default.
+func (s *Scheduler) reviewDiff(base context.Context, n *models.Node, repoPath string, payload *models.ReviewPayload) (*review.Diff, error) {
+	ctx, cancel := context.WithTimeout(base, reviewDiffTimeout)
+	defer cancel()
+
+	res, err := s.reviews.nodes.ExecCommand(ctx, n, review.Command(repoPath, payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to take diff: %w", err)
+	}
+	if res.ExitCode != 0 {
+		output := strings.TrimSpace(res.Stderr)
+		if output == "" {
+			output = strings.TrimSpace(res.Stdout)
+		}
+		output, _ = truncateOutput(output, 512)
+		return nil, fmt.Errorf("failed to take diff of %s (exit code %d): %s", payload.Source(), res.ExitCode, output)
+	}
+
+	maxBytes := payload.MaxDiffBytes
+	if maxBytes == 0 && s.reviews.config != nil {
+		maxBytes = s.reviews.config.Review.MaxDiffBytes
+	}
+	if maxBytes == 0 {
+		maxBytes = models.DefaultReviewMaxDiffBytes
+	}
+	return review.Build(res.Stdout, payload, maxBytes), nil
+}
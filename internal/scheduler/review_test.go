@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/review"
+)
+
+// newReviewFixture is a command fixture whose scheduler sends review
+// items, with a git repo of two commits in the workspace.
+func newReviewFixture(t *testing.T) *commandFixture {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	f := newCommandFixture(t, false)
+	WithReviewItems(f.config, f.workspaces, f.nodes, db.NewReviewRepository(f.database))(f.sched)
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = f.repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(f.repo, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	git("init", "-q")
+	write("main.go", "package main\n")
+	write("notes.md", "notes\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	write("main.go", "package main\n\nfunc helper() int { return 42 }\n")
+	write("notes.md", strings.Repeat("more notes\n", 200))
+	git("commit", "-q", "-am", "change")
+	return f
+}
+
+func (f *commandFixture) review(t *testing.T, itemID string) *models.Review {
+	t.Helper()
+	record, err := db.NewReviewRepository(f.database).GetByQueueItem(context.Background(), itemID)
+	if err != nil {
+		t.Fatalf("failed to load review: %v", err)
+	}
+	return record
+}
+
+func TestDispatchReview_SendsDiff(t *testing.T) {
+	f := newReviewFixture(t)
+
+	f.dispatchItem(t, "review-1", models.QueueItemTypeReview, models.ReviewPayload{
+		Range:        "HEAD~1..HEAD",
+		Exclude:      []string{"*.md"},
+		Instructions: "focus on naming",
+	})
+
+	if msg := f.failure(); msg != "" {
+		t.Fatalf("expected the review to be sent, got %s", msg)
+	}
+	record := f.review(t, "review-1")
+	if record.Status != models.ReviewStatusAwaitingVerdict || record.DispatchedAt == nil {
+		t.Fatalf("expected the review awaiting a verdict, got %+v", record)
+	}
+	if record.DiffFiles != 1 || record.Truncated || record.AgentID != f.agentID {
+		t.Fatalf("unexpected diff stats %+v", record)
+	}
+	for _, want := range []string{"review the following diff of HEAD~1..HEAD", "focus on naming", "+func helper() int", review.EndMarker(record.ID)} {
+		if !paneShows(t, f.srv, want) {
+			t.Fatalf("expected pane to show %q, got %v", want, f.srv.Commands())
+		}
+	}
+	if paneShows(t, f.srv, "more notes") {
+		t.Fatal("expected the excluded file left out")
+	}
+}
+
+func TestDispatchReview_CapsDiff(t *testing.T) {
+	f := newReviewFixture(t)
+
+	f.dispatchItem(t, "review-1", models.QueueItemTypeReview, models.ReviewPayload{Range: "HEAD~1..HEAD", MaxDiffBytes: 400})
+
+	if msg := f.failure(); msg != "" {
+		t.Fatalf("expected the review to be sent, got %s", msg)
+	}
+	record := f.review(t, "review-1")
+	if !record.Truncated || record.DiffFiles != 1 || record.DiffBytes <= 400 {
+		t.Fatalf("expected a capped diff, got %+v", record)
+	}
+	if !paneShows(t, f.srv, "these files are not shown: notes.md") {
+		t.Fatalf("expected the omitted file named, got %v", f.srv.Commands())
+	}
+}
+
+func TestDispatchReview_BadRangeFails(t *testing.T) {
+	f := newReviewFixture(t)
+
+	f.dispatchItem(t, "review-1", models.QueueItemTypeReview, models.ReviewPayload{Range: "nope..HEAD"})
+
+	if msg := f.failure(); !strings.Contains(msg, "failed to take diff of nope..HEAD") {
+		t.Fatalf("expected the item to fail on the diff, got %q", msg)
+	}
+	if record := f.review(t, "review-1"); record.Status != models.ReviewStatusFailed || record.Error == "" {
+		t.Fatalf("expected the review marked failed, got %+v", record)
+	}
+	if paneShows(t, f.srv, "review the following diff") {
+		t.Fatal("expected no prompt for a failed review")
+	}
+}
+
+func TestDispatchReview_RequiresOption(t *testing.T) {
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, nil)
+	defer cleanup()
+
+	raw, _ := json.Marshal(models.ReviewPayload{Range: "main..HEAD"})
+	item := &models.QueueItem{ID: "review-1", Type: models.QueueItemTypeReview, Payload: raw}
+
+	sched := New(DefaultConfig(), agentSvc, newTrackingQueueService(), nil, nil)
+	if err := sched.dispatchReview(context.Background(), agentID, item); err != ErrReviewItemsDisabled {
+		t.Fatalf("expected ErrReviewItemsDisabled without WithReviewItems, got %v", err)
+	}
+}
@@ -13,11 +13,15 @@ import (
 
 	"github.com/opencode-ai/swarm/internal/account"
 	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/events"
 	"github.com/opencode-ai/swarm/internal/logging"
 	"github.com/opencode-ai/swarm/internal/models"
 	"github.com/opencode-ai/swarm/internal/queue"
+	"github.com/opencode-ai/swarm/internal/quiethours"
 	"github.com/opencode-ai/swarm/internal/state"
+	"github.com/opencode-ai/swarm/internal/trace"
 	"github.com/rs/zerolog"
 )
 
@@ -66,6 +70,17 @@ type Config struct {
 	// DefaultCooldownDuration is the default pause duration after rate limiting.
 	// Default: 5 minutes.
 	DefaultCooldownDuration time.Duration
+
+	// TransientRestartBackoff is the minimum time between restarts of an
+	// agent that failed with a network error.
+	// Default: 10 minutes.
+	TransientRestartBackoff time.Duration
+
+	// InFlightTimeout is how long an item may stay in flight before it is
+	// taken to be abandoned by a dispatcher that crashed, and re-queued or
+	// dead-lettered. It must outlast DispatchTimeout.
+	// Default: 5 minutes.
+	InFlightTimeout time.Duration
 }
 
 // DefaultConfig returns sensible default configuration.
@@ -79,6 +94,8 @@ func DefaultConfig() Config {
 		MaxRetries:              3,
 		RetryBackoff:            5 * time.Second,
 		DefaultCooldownDuration: 5 * time.Minute,
+		TransientRestartBackoff: 10 * time.Minute,
+		InFlightTimeout:         5 * time.Minute,
 	}
 }
 
@@ -104,6 +121,12 @@ type DispatchEvent struct {
 
 	// Duration is how long the dispatch took.
 	Duration time.Duration
+
+	// Wait is how long the item sat queued before the dispatch.
+	Wait time.Duration
+
+	// TraceID is the trace the dispatch ran under.
+	TraceID string
 }
 
 // SchedulerStats contains scheduler statistics.
@@ -131,6 +154,14 @@ type SchedulerStats struct {
 
 	// PausedAgents is the count of currently paused agents.
 	PausedAgents int
+
+	// QuietHoursSkips is the number of dispatches held because the agent
+	// was in quiet hours.
+	QuietHoursSkips int64
+
+	// ProviderOutageSkips is the number of dispatches held because the
+	// agent's provider was down.
+	ProviderOutageSkips int64
 }
 
 // Scheduler manages message dispatch to agents.
@@ -141,6 +172,19 @@ type Scheduler struct {
 	stateEngine    *state.Engine
 	accountService *account.Service
 	publisher      events.Publisher
+	recorder       *DispatchRecorder
+	queueMetrics   *queue.Metrics
+	locks          *fileLocker
+	workspaceQueue *db.WorkspaceQueueRepository
+	tasks          TaskTracker
+	commands       *commandRunner
+	reviews        *reviewRunner
+	quietHours     *quietHours
+	providerHealth ProviderHealth
+	hibernation    *hibernation
+	versionChecks  *versionChecks
+	dispatcherID   string
+	clock          clock.Clock
 	logger         zerolog.Logger
 
 	// Runtime state
@@ -153,7 +197,16 @@ type Scheduler struct {
 	dispatchSem  chan struct{}
 	scheduleNow  chan string // channel to trigger immediate dispatch for an agent
 	pausedAgents map[string]struct{}
-	retryAfter   map[string]time.Time
+	retryAfter   map[string]clock.Deadline
+
+	// failureRestarts holds when each agent may next be restarted after
+	// a transient failure.
+	failureRestarts map[string]clock.Deadline
+
+	// pauseDeadlines tracks agent PausedUntil values converted to monotonic
+	// deadlines when first observed, so auto-resume is immune to wall-clock
+	// jumps while the scheduler runs.
+	pauseDeadlines map[string]pauseDeadline
 
 	// Per-agent dispatch locks to prevent concurrent dispatch to the same agent.
 	// Key: agentID, Value: mutex for that agent's dispatch operations.
@@ -175,6 +228,36 @@ func WithPublisher(publisher events.Publisher) Option {
 	}
 }
 
+// WithDispatchRecorder persists every dispatch through recorder. The
+// recorder runs while the scheduler is started and flushes on Stop.
+func WithDispatchRecorder(recorder *DispatchRecorder) Option {
+	return func(s *Scheduler) {
+		s.recorder = recorder
+	}
+}
+
+// WithQueueMetrics feeds queue wait and throughput of every successful
+// dispatch into metrics. Metrics with a store are loaded and saved while
+// the scheduler is started.
+func WithQueueMetrics(metrics *queue.Metrics) Option {
+	return func(s *Scheduler) {
+		s.queueMetrics = metrics
+	}
+}
+
+// WithClock sets the time source used for ticks, backoff, and auto-resume.
+func WithClock(c clock.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = c
+	}
+}
+
+// pauseDeadline pairs a persisted PausedUntil with its monotonic deadline.
+type pauseDeadline struct {
+	pausedUntil time.Time
+	deadline    clock.Deadline
+}
+
 // New creates a new Scheduler.
 func New(config Config, agentService *agent.Service, queueService queue.QueueService, stateEngine *state.Engine, accountService *account.Service, opts ...Option) *Scheduler {
 	if config.TickInterval <= 0 {
@@ -195,24 +278,38 @@ func New(config Config, agentService *agent.Service, queueService queue.QueueSer
 	if config.DefaultCooldownDuration <= 0 {
 		config.DefaultCooldownDuration = DefaultConfig().DefaultCooldownDuration
 	}
+	if config.TransientRestartBackoff <= 0 {
+		config.TransientRestartBackoff = DefaultConfig().TransientRestartBackoff
+	}
+	if config.InFlightTimeout <= config.DispatchTimeout {
+		config.InFlightTimeout = max(DefaultConfig().InFlightTimeout, 2*config.DispatchTimeout)
+	}
 
 	s := &Scheduler{
-		config:         config,
-		agentService:   agentService,
-		queueService:   queueService,
-		stateEngine:    stateEngine,
-		accountService: accountService,
-		logger:         logging.Component("scheduler"),
-		dispatchSem:    make(chan struct{}, config.MaxConcurrentDispatches),
-		scheduleNow:    make(chan string, 100),
-		pausedAgents:   make(map[string]struct{}),
-		retryAfter:     make(map[string]time.Time),
-		dispatchCh:     make(chan DispatchEvent, 100),
+		config:          config,
+		agentService:    agentService,
+		queueService:    queueService,
+		stateEngine:     stateEngine,
+		accountService:  accountService,
+		logger:          logging.Component("scheduler"),
+		dispatchSem:     make(chan struct{}, config.MaxConcurrentDispatches),
+		scheduleNow:     make(chan string, 100),
+		pausedAgents:    make(map[string]struct{}),
+		retryAfter:      make(map[string]clock.Deadline),
+		failureRestarts: make(map[string]clock.Deadline),
+		pauseDeadlines:  make(map[string]pauseDeadline),
+		dispatcherID:    defaultDispatcherID(),
+		clock:           clock.Real(),
+		dispatchCh:      make(chan DispatchEvent, 100),
 	}
 
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.clock = clock.OrReal(s.clock)
+	if s.quietHours != nil {
+		s.quietHours.evaluator = quiethours.NewEvaluator(s.clock)
+	}
 
 	return s
 }
@@ -230,7 +327,7 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	s.running = true
 	s.paused = false
 
-	now := time.Now().UTC()
+	now := s.clock.Now().UTC()
 	s.statsMu.Lock()
 	s.stats.Running = true
 	s.stats.Paused = false
@@ -242,10 +339,29 @@ func (s *Scheduler) Start(ctx context.Context) error {
 		Int("max_concurrent", s.config.MaxConcurrentDispatches).
 		Msg("scheduler starting")
 
+	// Settle what a previous run left in flight before dispatching again
+	s.recoverInFlight(s.ctx)
+
 	// Start the main scheduling loop
 	s.wg.Add(1)
 	go s.runLoop()
 
+	if s.recorder != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.recorder.Run(s.ctx)
+		}()
+	}
+
+	if s.queueMetrics != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.queueMetrics.Run(s.ctx)
+		}()
+	}
+
 	// Subscribe to state changes for auto-dispatch on idle
 	if s.stateEngine != nil {
 		if err := s.stateEngine.SubscribeFunc("scheduler", s.onStateChange); err != nil {
@@ -266,16 +382,17 @@ func (s *Scheduler) Stop() error {
 
 	s.logger.Info().Msg("scheduler stopping")
 
-	// Unsubscribe from state changes
-	if s.stateEngine != nil {
-		_ = s.stateEngine.Unsubscribe("scheduler")
-	}
-
 	// Cancel the context and wait for goroutines
 	s.cancel()
 	s.running = false
 	s.mu.Unlock()
 
+	// Unsubscribe outside the lock: it waits for an in-flight
+	// onStateChange, which reads s.mu.
+	if s.stateEngine != nil {
+		_ = s.stateEngine.Unsubscribe("scheduler")
+	}
+
 	// Wait for all goroutines to finish
 	s.wg.Wait()
 
@@ -393,7 +510,7 @@ func (s *Scheduler) IsAgentPaused(agentID string) bool {
 	return paused
 }
 
-func (s *Scheduler) setRetryAfter(agentID string, until time.Time) {
+func (s *Scheduler) setRetryAfter(agentID string, until clock.Deadline) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.retryAfter[agentID] = until
@@ -413,7 +530,7 @@ func (s *Scheduler) isRetryBackoffActive(agentID string) bool {
 	if !ok {
 		return false
 	}
-	if time.Now().UTC().Before(until) {
+	if !until.Expired(s.clock) {
 		return true
 	}
 
@@ -448,8 +565,19 @@ func (s *Scheduler) Stats() SchedulerStats {
 	return s.stats
 }
 
-// DispatchEvents returns the channel of dispatch events.
-// Consumers should read from this channel to receive dispatch notifications.
+// QueueStats returns per-agent queue wait percentiles and throughput, or
+// nil when the scheduler has no queue metrics.
+func (s *Scheduler) QueueStats() []models.QueueWaitStats {
+	if s.queueMetrics == nil {
+		return nil
+	}
+	return s.queueMetrics.All()
+}
+
+// DispatchEvents returns the channel of dispatch events for in-process
+// consumers. Sends are non-blocking, so events are dropped when nobody reads;
+// the queue.item_dispatched events written by a DispatchRecorder are the
+// canonical record.
 func (s *Scheduler) DispatchEvents() <-chan DispatchEvent {
 	return s.dispatchCh
 }
@@ -458,14 +586,19 @@ func (s *Scheduler) DispatchEvents() <-chan DispatchEvent {
 func (s *Scheduler) runLoop() {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(s.config.TickInterval)
+	ticker := s.clock.NewTicker(s.config.TickInterval)
 	defer ticker.Stop()
+	recovery := s.clock.NewTicker(s.config.InFlightTimeout)
+	defer recovery.Stop()
 
 	for {
 		select {
 		case <-s.ctx.Done():
 			return
 
+		case <-recovery.C():
+			s.recoverInFlight(s.ctx)
+
 		case agentID := <-s.scheduleNow:
 			// Immediate dispatch request
 			s.mu.RLock()
@@ -476,7 +609,7 @@ func (s *Scheduler) runLoop() {
 				s.tryDispatch(agentID)
 			}
 
-		case <-ticker.C:
+		case <-ticker.C():
 			// Regular tick
 			s.mu.RLock()
 			paused := s.paused
@@ -507,6 +640,22 @@ func (s *Scheduler) tick() {
 		s.checkAutoResume(ctx, agents)
 	}
 
+	if s.locks != nil {
+		s.locks.releaseExpired(ctx, s.clock, s.logger)
+	}
+
+	if s.hibernation != nil {
+		s.checkHibernation(ctx, agents)
+	}
+
+	if s.versionChecks != nil {
+		s.checkCLIVersions(ctx, agents)
+	}
+
+	if s.workspaceQueue != nil {
+		agents = s.assignWorkspaceItems(ctx, agents)
+	}
+
 	// Find eligible agents and dispatch
 	for _, a := range agents {
 		if s.isEligibleForDispatch(a) {
@@ -517,11 +666,12 @@ func (s *Scheduler) tick() {
 
 // checkAutoResume checks for agents that should auto-resume.
 func (s *Scheduler) checkAutoResume(ctx context.Context, agents []*models.Agent) {
-	now := time.Now().UTC()
+	seen := make(map[string]struct{}, len(agents))
 
 	for _, a := range agents {
 		if a.State == models.AgentStatePaused && a.PausedUntil != nil {
-			if now.After(*a.PausedUntil) {
+			seen[a.ID] = struct{}{}
+			if s.pauseDeadlineFor(a.ID, *a.PausedUntil).Expired(s.clock) {
 				s.logger.Debug().
 					Str("agent_id", a.ID).
 					Time("paused_until", *a.PausedUntil).
@@ -530,12 +680,41 @@ func (s *Scheduler) checkAutoResume(ctx context.Context, agents []*models.Agent)
 				if err := s.agentService.ResumeAgent(ctx, a.ID); err != nil {
 					s.logger.Warn().Err(err).Str("agent_id", a.ID).Msg("failed to auto-resume agent")
 				} else {
+					delete(seen, a.ID)
 					// Also resume in scheduler
 					s.ResumeAgent(a.ID)
 				}
 			}
 		}
 	}
+
+	s.prunePauseDeadlines(seen)
+}
+
+// pauseDeadlineFor returns the monotonic deadline for an agent's PausedUntil.
+// The wall-clock value is converted only when it is first seen or changes.
+func (s *Scheduler) pauseDeadlineFor(agentID string, pausedUntil time.Time) clock.Deadline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tracked, ok := s.pauseDeadlines[agentID]; ok && tracked.pausedUntil.Equal(pausedUntil) {
+		return tracked.deadline
+	}
+	deadline := clock.DeadlineAt(s.clock, pausedUntil)
+	s.pauseDeadlines[agentID] = pauseDeadline{pausedUntil: pausedUntil, deadline: deadline}
+	return deadline
+}
+
+// prunePauseDeadlines drops tracked deadlines for agents that are no longer paused.
+func (s *Scheduler) prunePauseDeadlines(paused map[string]struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for agentID := range s.pauseDeadlines {
+		if _, ok := paused[agentID]; !ok {
+			delete(s.pauseDeadlines, agentID)
+		}
+	}
 }
 
 // isEligibleForDispatch checks if an agent is eligible for dispatch.
@@ -556,8 +735,9 @@ func (s *Scheduler) isEligibleForDispatch(a *models.Agent) bool {
 		return false
 	}
 
-	// If idle state is required, check for idle
-	if s.config.IdleStateRequired && a.State != models.AgentStateIdle {
+	// If idle state is required, check for idle. Hibernated agents are
+	// woken before dispatch.
+	if s.config.IdleStateRequired && a.State != models.AgentStateIdle && a.State != models.AgentStateHibernated {
 		return false
 	}
 
@@ -600,26 +780,33 @@ func (s *Scheduler) tryDispatch(agentID string) {
 		defer func() { <-s.dispatchSem }()
 		defer s.unlockAgentDispatch(agentID)
 
+		if s.hibernation != nil && !s.wakeForDispatch(agentID) {
+			return
+		}
 		s.dispatchToAgent(agentID)
 	}()
 }
 
-// dispatchToAgent dispatches the next queue item to an agent.
+// dispatchToAgent dispatches the next queue item to an agent. The
+// dispatch runs under the trace of the command that queued the item, or a
+// trace of its own.
 func (s *Scheduler) dispatchToAgent(agentID string) {
 	ctx, cancel := context.WithTimeout(s.ctx, s.config.DispatchTimeout)
 	defer cancel()
+	ctx = trace.Ensure(ctx, "")
 
 	// Guard against nil queue service
 	if s.queueService == nil {
 		return
 	}
 
-	startTime := time.Now()
+	startTime := s.clock.Now()
+	startMono := s.clock.Monotonic()
 	var event *DispatchEvent
 
 	defer func() {
 		if event != nil {
-			event.Duration = time.Since(startTime)
+			event.Duration = clock.Since(s.clock, startMono)
 			s.recordDispatch(*event)
 		}
 	}()
@@ -631,7 +818,7 @@ func (s *Scheduler) dispatchToAgent(agentID string) {
 	}
 
 	var agentInfo *models.Agent
-	if s.config.IdleStateRequired || s.accountService != nil {
+	if s.config.IdleStateRequired || s.accountService != nil || s.quietHours != nil || s.providerHealth != nil {
 		var err error
 		agentInfo, err = s.agentService.GetAgent(ctx, agentID)
 		if err != nil {
@@ -648,6 +835,14 @@ func (s *Scheduler) dispatchToAgent(agentID string) {
 		return
 	}
 
+	if agentInfo != nil && s.holdForQuietHours(ctx, agentInfo) {
+		return
+	}
+
+	if agentInfo != nil && s.holdForProviderOutage(ctx, agentInfo) {
+		return
+	}
+
 	// Check account cooldown before dequeuing
 	if s.accountService != nil && agentInfo != nil && agentInfo.AccountID != "" {
 		onCooldown, remaining, err := s.accountService.IsOnCooldown(ctx, agentInfo.AccountID)
@@ -680,7 +875,7 @@ func (s *Scheduler) dispatchToAgent(agentID string) {
 			}
 
 			fromAccount := agentInfo.AccountID
-			if _, err := s.agentService.RestartAgentWithAccount(ctx, agentID, rotated.ID); err != nil {
+			if _, err := s.agentService.RestartAgentWithAccount(ctx, agentID, rotated.ID, false); err != nil {
 				s.logger.Warn().
 					Err(err).
 					Str("agent_id", agentID).
@@ -700,7 +895,7 @@ func (s *Scheduler) dispatchToAgent(agentID string) {
 	}
 
 	// Get the next item from the queue
-	item, err := s.queueService.Dequeue(ctx, agentID)
+	item, err := s.queueService.Dequeue(ctx, agentID, s.dispatcherID)
 	if err != nil {
 		if errors.Is(err, queue.ErrQueueEmpty) {
 			// No items to dispatch, not an error - don't record
@@ -711,10 +906,14 @@ func (s *Scheduler) dispatchToAgent(agentID string) {
 			Timestamp: startTime,
 			Success:   false,
 			Error:     fmt.Sprintf("failed to dequeue: %v", err),
+			TraceID:   trace.FromContext(ctx),
 		}
-		s.logger.Error().Err(err).Str("agent_id", agentID).Msg("failed to dequeue item")
+		s.logger.Error().Ctx(ctx).Err(err).Str("agent_id", agentID).Msg("failed to dequeue item")
 		return
 	}
+	if item.TraceID != "" {
+		ctx = trace.WithID(ctx, item.TraceID)
+	}
 
 	// Initialize event now that we have an item
 	event = &DispatchEvent{
@@ -722,6 +921,16 @@ func (s *Scheduler) dispatchToAgent(agentID string) {
 		Timestamp: startTime,
 		ItemID:    item.ID,
 		ItemType:  item.Type,
+		TraceID:   trace.FromContext(ctx),
+		Wait:      queueWait(item, startTime),
+	}
+
+	// Reserve requested files before the agent starts on the task
+	if err := s.acquireFileLocks(ctx, agentID, item); err != nil {
+		event.Success = false
+		event.Error = err.Error()
+		s.deferForFileLocks(ctx, agentID, item, err)
+		return
 	}
 
 	// Publish message.dispatched event
@@ -739,6 +948,10 @@ func (s *Scheduler) dispatchToAgent(agentID string) {
 		err = s.dispatchPause(ctx, agentID, item)
 	case models.QueueItemTypeConditional:
 		err = s.dispatchConditional(ctx, agentID, item)
+	case models.QueueItemTypeCommand:
+		err = s.dispatchCommand(ctx, agentID, item)
+	case models.QueueItemTypeReview:
+		err = s.dispatchReview(ctx, agentID, item)
 	default:
 		err = fmt.Errorf("unknown item type: %s", item.Type)
 	}
@@ -747,6 +960,7 @@ func (s *Scheduler) dispatchToAgent(agentID string) {
 		event.Success = false
 		event.Error = err.Error()
 		s.logger.Error().
+			Ctx(ctx).
 			Err(err).
 			Str("agent_id", agentID).
 			Str("item_id", item.ID).
@@ -762,8 +976,15 @@ func (s *Scheduler) dispatchToAgent(agentID string) {
 			Attempts:    item.Attempts + 1,
 		})
 
-		if retryErr := s.handleDispatchFailure(context.Background(), agentID, item, err); retryErr != nil {
+		// Outlive the dispatch timeout, but stay on the dispatch's trace.
+		cleanupCtx := trace.WithID(context.Background(), trace.FromContext(ctx))
+		if s.locks != nil {
+			s.locks.release(cleanupCtx, s.logger, agentID, "dispatch failed")
+		}
+
+		if retryErr := s.handleDispatchFailure(cleanupCtx, agentID, item, err); retryErr != nil {
 			s.logger.Warn().
+				Ctx(ctx).
 				Err(retryErr).
 				Str("agent_id", agentID).
 				Str("item_id", item.ID).
@@ -772,18 +993,22 @@ func (s *Scheduler) dispatchToAgent(agentID string) {
 	} else {
 		event.Success = true
 		s.clearRetryAfter(agentID)
+		s.markDispatched(trace.WithID(context.Background(), trace.FromContext(ctx)), item)
 		s.logger.Info().
+			Ctx(ctx).
 			Str("agent_id", agentID).
 			Str("item_id", item.ID).
 			Str("item_type", string(item.Type)).
 			Msg("dispatch successful")
 
+		s.trackDispatched(ctx, item)
+
 		// Publish message.completed event
 		s.publishEvent(ctx, models.EventTypeMessageCompleted, models.EntityTypeQueue, item.ID, models.MessageCompletedPayload{
 			QueueItemID: item.ID,
 			ItemType:    item.Type,
 			AgentID:     agentID,
-			Duration:    time.Since(startTime).String(),
+			Duration:    clock.Since(s.clock, startMono).String(),
 		})
 	}
 }
@@ -855,6 +1080,8 @@ func (s *Scheduler) dispatchConditional(ctx context.Context, agentID string, ite
 		if err := s.queueService.UpdateStatus(ctx, item.ID, models.QueueItemStatusSkipped, "max evaluations exceeded"); err != nil {
 			s.logger.Warn().Err(err).Str("item_id", item.ID).Msg("failed to mark conditional as skipped")
 		}
+		// The item was blocked, not done: its task cannot complete.
+		s.trackFailed(ctx, item, "condition not met after max evaluations")
 		return nil
 	}
 
@@ -867,7 +1094,7 @@ func (s *Scheduler) dispatchConditional(ctx context.Context, agentID string, ite
 	condCtx := ConditionContext{
 		Agent:       agentInfo,
 		QueueLength: agentInfo.QueueLength,
-		Now:         time.Now().UTC(),
+		Now:         s.clock.Now().UTC(),
 	}
 
 	// Evaluate the condition using the new evaluator
@@ -920,6 +1147,41 @@ func (s *Scheduler) dispatchConditional(ctx context.Context, agentID string, ite
 	return nil
 }
 
+// acquireFileLocks reserves the item's LockPaths for the agent, if file
+// locks are configured and the item requests any.
+func (s *Scheduler) acquireFileLocks(ctx context.Context, agentID string, item *models.QueueItem) error {
+	if s.locks == nil {
+		return nil
+	}
+	paths := lockPathsFor(item)
+	if len(paths) == 0 {
+		return nil
+	}
+	return s.locks.acquire(ctx, s.clock, s.logger, agentID, item.ID, paths)
+}
+
+// deferForFileLocks puts an item blocked on file locks back in the queue
+// and backs the agent off. Lock waits do not count as dispatch attempts.
+func (s *Scheduler) deferForFileLocks(ctx context.Context, agentID string, item *models.QueueItem, lockErr error) {
+	backoff := s.locks.config.RetryBackoff
+	if err := s.queueService.UpdateStatus(ctx, item.ID, models.QueueItemStatusPending, lockErr.Error()); err != nil {
+		s.logger.Warn().
+			Err(err).
+			Str("agent_id", agentID).
+			Str("item_id", item.ID).
+			Msg("failed to re-queue item blocked on file locks")
+		return
+	}
+
+	s.setRetryAfter(agentID, clock.NewDeadline(s.clock, backoff))
+	s.logger.Info().
+		Err(lockErr).
+		Str("agent_id", agentID).
+		Str("item_id", item.ID).
+		Dur("backoff", backoff).
+		Msg("dispatch deferred until file locks are free")
+}
+
 func (s *Scheduler) handleDispatchFailure(ctx context.Context, agentID string, item *models.QueueItem, dispatchErr error) error {
 	if s.queueService == nil || item == nil {
 		return nil
@@ -940,7 +1202,7 @@ func (s *Scheduler) handleDispatchFailure(ctx context.Context, agentID string, i
 		}
 
 		backoff := s.retryBackoff(attempts)
-		s.setRetryAfter(agentID, time.Now().UTC().Add(backoff))
+		s.setRetryAfter(agentID, clock.NewDeadline(s.clock, backoff))
 		s.logger.Warn().
 			Str("agent_id", agentID).
 			Str("item_id", item.ID).
@@ -965,6 +1227,8 @@ func (s *Scheduler) handleDispatchFailure(ctx context.Context, agentID string, i
 		Int("attempt", attempts).
 		Int("max_retries", maxRetries).
 		Msg("dispatch failed; max retries exceeded")
+
+	s.trackFailed(ctx, item, dispatchErr.Error())
 	return nil
 }
 
@@ -984,6 +1248,14 @@ func (s *Scheduler) retryBackoff(attempt int) time.Duration {
 	return backoff
 }
 
+// queueWait is how long item has been queued as of now.
+func queueWait(item *models.QueueItem, now time.Time) time.Duration {
+	if item.CreatedAt.IsZero() || now.Before(item.CreatedAt) {
+		return 0
+	}
+	return now.Sub(item.CreatedAt)
+}
+
 // recordDispatch records a dispatch event in stats.
 func (s *Scheduler) recordDispatch(event DispatchEvent) {
 	// Update stats
@@ -998,6 +1270,14 @@ func (s *Scheduler) recordDispatch(event DispatchEvent) {
 	s.stats.LastDispatchAt = &now
 	s.statsMu.Unlock()
 
+	if s.queueMetrics != nil && event.Success && event.ItemID != "" {
+		s.queueMetrics.Observe(event.AgentID, event.Wait)
+	}
+
+	if s.recorder != nil {
+		s.recorder.Record(event)
+	}
+
 	// Send to dispatch channel (non-blocking)
 	select {
 	case s.dispatchCh <- event:
@@ -1029,8 +1309,14 @@ func (s *Scheduler) publishEvent(ctx context.Context, eventType models.EventType
 
 // onStateChange handles agent state change notifications.
 func (s *Scheduler) onStateChange(change state.StateChange) {
-	// When an agent becomes idle, try to dispatch
+	// When an agent becomes idle, its task is done: free its file locks,
+	// complete its tracked task, and try to dispatch
 	if change.CurrentState == models.AgentStateIdle {
+		if s.locks != nil {
+			s.locks.releaseOnIdle(s.ctx, s.logger, change)
+		}
+		s.trackIdle(s.ctx, change)
+
 		s.logger.Debug().
 			Str("agent_id", change.AgentID).
 			Str("from_state", string(change.PreviousState)).
@@ -1042,6 +1328,10 @@ func (s *Scheduler) onStateChange(change state.StateChange) {
 	if change.CurrentState == models.AgentStateRateLimited {
 		s.handleRateLimit(change)
 	}
+
+	if change.CurrentState == models.AgentStateError && change.Failure != nil {
+		s.handleFailure(change)
+	}
 }
 
 func (s *Scheduler) handleRateLimit(change state.StateChange) {
@@ -1075,6 +1365,13 @@ func (s *Scheduler) handleRateLimit(change state.StateChange) {
 		s.logger.Debug().Str("agent_id", change.AgentID).Msg("agent has no account; skipping cooldown")
 		return
 	}
+	if provider, down := s.providerDown(ctx, agentInfo); down {
+		s.logger.Info().
+			Str("agent_id", change.AgentID).
+			Str("provider", string(provider)).
+			Msg("provider down; not rotating accounts after rate limit")
+		return
+	}
 
 	if err := s.accountService.SetCooldownForRateLimit(ctx, agentInfo.AccountID, change.StateInfo.Reason); err != nil {
 		s.logger.Warn().
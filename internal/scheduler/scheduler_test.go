@@ -68,7 +68,18 @@ func (m *mockQueueService) Enqueue(ctx context.Context, agentID string, items ..
 	return nil
 }
 
-func (m *mockQueueService) Dequeue(ctx context.Context, agentID string) (*models.QueueItem, error) {
+func (m *mockQueueService) EnqueueBatch(ctx context.Context, agentID string, items ...*models.QueueItem) error {
+	return m.Enqueue(ctx, agentID, items...)
+}
+
+func (m *mockQueueService) EnqueueBatchAtHead(ctx context.Context, agentID string, items ...*models.QueueItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queues[agentID] = append(append([]*models.QueueItem{}, items...), m.queues[agentID]...)
+	return nil
+}
+
+func (m *mockQueueService) Dequeue(ctx context.Context, agentID, dispatcherID string) (*models.QueueItem, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.dequeueCalls++
@@ -141,6 +152,14 @@ func (m *mockQueueService) UpdateStatus(ctx context.Context, itemID string, stat
 	return nil
 }
 
+func (m *mockQueueService) ListInFlight(ctx context.Context, cutoff time.Time) ([]*models.QueueItem, error) {
+	return nil, nil
+}
+
+func (m *mockQueueService) ReleaseInFlight(ctx context.Context, itemID string, status models.QueueItemStatus, attempts int, errorMsg string) (bool, error) {
+	return true, nil
+}
+
 func (m *mockQueueService) UpdateAttempts(ctx context.Context, itemID string, attempts int) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -671,6 +690,36 @@ func TestScheduler_RecordDispatch(t *testing.T) {
 	}
 }
 
+func TestScheduler_RecordDispatchFeedsQueueMetrics(t *testing.T) {
+	sched := New(DefaultConfig(), nil, nil, nil, nil, WithQueueMetrics(queue.NewMetrics()))
+
+	sched.recordDispatch(DispatchEvent{AgentID: "agent-1", ItemID: "item-1", Success: true, Wait: 3 * time.Second})
+	sched.recordDispatch(DispatchEvent{AgentID: "agent-1", ItemID: "item-2", Success: true, Wait: time.Second})
+	sched.recordDispatch(DispatchEvent{AgentID: "agent-1", ItemID: "item-3", Success: false, Wait: time.Minute})
+	sched.recordDispatch(DispatchEvent{AgentID: "agent-2", Success: true})
+
+	stats := sched.QueueStats()
+	if len(stats) != 1 {
+		t.Fatalf("expected stats for one agent, got %+v", stats)
+	}
+	if stats[0].Dispatched != 2 || stats[0].WaitP50Ms != 1000 || stats[0].WaitP95Ms != 3000 || stats[0].PerHour != 2 {
+		t.Fatalf("unexpected queue stats %+v", stats[0])
+	}
+}
+
+func TestQueueWait(t *testing.T) {
+	now := time.Now()
+	if got := queueWait(&models.QueueItem{CreatedAt: now.Add(-90 * time.Second)}, now); got != 90*time.Second {
+		t.Fatalf("expected 90s wait, got %s", got)
+	}
+	if got := queueWait(&models.QueueItem{}, now); got != 0 {
+		t.Fatalf("expected no wait without a creation time, got %s", got)
+	}
+	if got := queueWait(&models.QueueItem{CreatedAt: now.Add(time.Minute)}, now); got != 0 {
+		t.Fatalf("expected no wait for a clock-skewed item, got %s", got)
+	}
+}
+
 func TestScheduler_ScheduleNow_Running(t *testing.T) {
 	cfg := Config{
 		TickInterval:            100 * time.Millisecond,
@@ -1009,8 +1058,7 @@ func TestScheduler_ConcurrentDispatchPrevention(t *testing.T) {
 	// Multiple concurrent tryDispatch calls for the same agent should result
 	// in only one actual dispatch.
 
-	exec := &dispatchExecutor{}
-	tmuxClient := tmux.NewClient(exec)
+	tmuxClient := tmux.NewClient(newDispatchTmux(t))
 
 	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 5, tmuxClient)
 	defer cleanup()
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+)
+
+// TaskTracker receives the dispatch outcomes that drive task status.
+// task.Service implements it.
+type TaskTracker interface {
+	// ItemDispatched is called after an item was dispatched successfully.
+	ItemDispatched(ctx context.Context, item *models.QueueItem) error
+
+	// ItemFailed is called once an item has exhausted its retries, or a
+	// conditional item is skipped because its condition never held.
+	ItemFailed(ctx context.Context, item *models.QueueItem, reason string) error
+
+	// AgentIdle is called when the state engine reports the agent idle.
+	AgentIdle(ctx context.Context, agentID string, at time.Time) error
+}
+
+// WithTaskTracker reports dispatches, permanent failures, and idle
+// transitions to tracker so tasks advance with their queue items.
+func WithTaskTracker(tracker TaskTracker) Option {
+	return func(s *Scheduler) {
+		s.tasks = tracker
+	}
+}
+
+func (s *Scheduler) trackDispatched(ctx context.Context, item *models.QueueItem) {
+	if s.tasks == nil || item.TaskID == "" {
+		return
+	}
+	if err := s.tasks.ItemDispatched(ctx, item); err != nil {
+		s.logger.Warn().Err(err).Str("item_id", item.ID).Str("task_id", item.TaskID).Msg("failed to update task after dispatch")
+	}
+}
+
+func (s *Scheduler) trackFailed(ctx context.Context, item *models.QueueItem, reason string) {
+	if s.tasks == nil || item.TaskID == "" {
+		return
+	}
+	if err := s.tasks.ItemFailed(ctx, item, reason); err != nil {
+		s.logger.Warn().Err(err).Str("item_id", item.ID).Str("task_id", item.TaskID).Msg("failed to fail task")
+	}
+}
+
+func (s *Scheduler) trackIdle(ctx context.Context, change state.StateChange) {
+	if s.tasks == nil {
+		return
+	}
+	if err := s.tasks.AgentIdle(ctx, change.AgentID, change.Timestamp); err != nil {
+		s.logger.Warn().Err(err).Str("agent_id", change.AgentID).Msg("failed to check task completion")
+	}
+}
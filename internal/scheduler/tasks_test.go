@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+// recordingTracker records the task notifications the scheduler sends.
+type recordingTracker struct {
+	mu         sync.Mutex
+	dispatched []string
+	failed     []string
+	idle       []string
+}
+
+func (r *recordingTracker) ItemDispatched(ctx context.Context, item *models.QueueItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dispatched = append(r.dispatched, item.ID)
+	return nil
+}
+
+func (r *recordingTracker) ItemFailed(ctx context.Context, item *models.QueueItem, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed = append(r.failed, item.ID+": "+reason)
+	return nil
+}
+
+func (r *recordingTracker) AgentIdle(ctx context.Context, agentID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.idle = append(r.idle, agentID)
+	return nil
+}
+
+func TestScheduler_TaskTracker_Dispatched(t *testing.T) {
+	srv := newDispatchTmux(t)
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 1, tmux.NewClient(srv))
+	defer cleanup()
+
+	tracked := makeMessageItem("item-1", "hello")
+	tracked.TaskID = "task-1"
+	queueSvc := newTrackingQueueService()
+	if err := queueSvc.Enqueue(context.Background(), agentID, tracked); err != nil {
+		t.Fatalf("failed to enqueue item: %v", err)
+	}
+
+	tracker := &recordingTracker{}
+	sched := New(DefaultConfig(), agentSvc, queueSvc, nil, nil, WithTaskTracker(tracker))
+	sched.ctx = context.Background()
+
+	sched.dispatchToAgent(agentID)
+
+	if len(tracker.dispatched) != 1 || tracker.dispatched[0] != "item-1" {
+		t.Fatalf("expected the task item reported, got %v", tracker.dispatched)
+	}
+}
+
+func TestScheduler_TaskTracker_FailedAfterMaxRetries(t *testing.T) {
+	queueSvc := newMockQueueService()
+	agentID := "agent-1"
+	item := createMessageItem("item-1", "hello")
+	item.TaskID = "task-1"
+	if err := queueSvc.Enqueue(context.Background(), agentID, item); err != nil {
+		t.Fatalf("failed to enqueue item: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 1
+	tracker := &recordingTracker{}
+	sched := New(cfg, nil, queueSvc, nil, nil, WithTaskTracker(tracker))
+
+	dispatchErr := fmt.Errorf("dispatch error")
+	if err := sched.handleDispatchFailure(context.Background(), agentID, item, dispatchErr); err != nil {
+		t.Fatalf("handleDispatchFailure failed: %v", err)
+	}
+	if len(tracker.failed) != 0 {
+		t.Fatalf("expected no task failure while retries remain, got %v", tracker.failed)
+	}
+
+	if err := sched.handleDispatchFailure(context.Background(), agentID, item, dispatchErr); err != nil {
+		t.Fatalf("handleDispatchFailure failed: %v", err)
+	}
+	if len(tracker.failed) != 1 || tracker.failed[0] != "item-1: dispatch error" {
+		t.Fatalf("expected task failure after max retries, got %v", tracker.failed)
+	}
+}
+
+func TestScheduler_TaskTracker_FailedWhenConditionalSkipped(t *testing.T) {
+	agentSvc, agentID, cleanup := setupAgentServiceForDispatch(t, models.AgentStateIdle, 0, nil)
+	defer cleanup()
+
+	item := makeConditionalItem("cond-1", models.ConditionalPayload{ConditionType: models.ConditionTypeWhenIdle, Message: "hello"})
+	item.TaskID = "task-1"
+	item.EvaluationCount = MaxConditionalEvaluations
+	queueSvc := newTrackingQueueService()
+	if err := queueSvc.Enqueue(context.Background(), agentID, item); err != nil {
+		t.Fatalf("failed to enqueue item: %v", err)
+	}
+
+	tracker := &recordingTracker{}
+	sched := New(DefaultConfig(), agentSvc, queueSvc, nil, nil, WithTaskTracker(tracker))
+	sched.ctx = context.Background()
+
+	sched.dispatchToAgent(agentID)
+
+	if len(tracker.failed) != 1 || tracker.failed[0] != "cond-1: condition not met after max evaluations" {
+		t.Fatalf("expected the blocked item to fail its task, got %v", tracker.failed)
+	}
+}
+
+func TestScheduler_TaskTracker_AgentIdle(t *testing.T) {
+	tracker := &recordingTracker{}
+	sched := New(DefaultConfig(), nil, newMockQueueService(), nil, nil, WithTaskTracker(tracker))
+	sched.ctx = context.Background()
+
+	sched.onStateChange(state.StateChange{AgentID: "agent-1", PreviousState: models.AgentStateWorking, CurrentState: models.AgentStateWorking})
+	sched.onStateChange(state.StateChange{AgentID: "agent-1", PreviousState: models.AgentStateWorking, CurrentState: models.AgentStateIdle, Timestamp: time.Now()})
+
+	if len(tracker.idle) != 1 || tracker.idle[0] != "agent-1" {
+		t.Fatalf("expected one idle notification, got %v", tracker.idle)
+	}
+}
@@ -297,8 +297,9 @@ func checkEligibilityWithItem(
 		goto checkAccountCooldown
 	}
 
-	// Check idle requirement (skip for permission responses handled above)
-	if config.IdleStateRequired && agent.State != models.AgentStateIdle {
+	// Check idle requirement (skip for permission responses handled above).
+	// Hibernated agents are woken before dispatch.
+	if config.IdleStateRequired && agent.State != models.AgentStateIdle && agent.State != models.AgentStateHibernated {
 		return true, BlockReasonNotIdle
 	}
 
@@ -348,6 +349,12 @@ func processQueueItem(
 
 	case models.QueueItemTypeConditional:
 		actions = append(actions, processConditionalItem(agent, item, now, config)...)
+
+	case models.QueueItemTypeCommand:
+		actions = append(actions, processCommandItem(agent, item))
+
+	case models.QueueItemTypeReview:
+		actions = append(actions, processReviewItem(agent, item))
 	}
 
 	return actions
@@ -368,6 +375,38 @@ func processMessageItem(agent AgentSnapshot, item QueueItemSnapshot) TickAction
 	}
 }
 
+// processCommandItem creates a dispatch action for a command item. The
+// message is the command line; its output is only known once it runs.
+func processCommandItem(agent AgentSnapshot, item QueueItemSnapshot) TickAction {
+	var payload models.CommandPayload
+	_ = json.Unmarshal(item.Payload, &payload)
+
+	return TickAction{
+		Type:     ActionTypeDispatch,
+		AgentID:  agent.ID,
+		ItemID:   item.ID,
+		ItemType: models.QueueItemTypeCommand,
+		Message:  "$ " + payload.Command,
+		Reason:   "command ready to run",
+	}
+}
+
+// processReviewItem creates a dispatch action for a review item. The
+// message names what is reviewed; the diff is only taken once it runs.
+func processReviewItem(agent AgentSnapshot, item QueueItemSnapshot) TickAction {
+	var payload models.ReviewPayload
+	_ = json.Unmarshal(item.Payload, &payload)
+
+	return TickAction{
+		Type:     ActionTypeDispatch,
+		AgentID:  agent.ID,
+		ItemID:   item.ID,
+		ItemType: models.QueueItemTypeReview,
+		Message:  "review " + payload.Source(),
+		Reason:   "review ready to send",
+	}
+}
+
 // processPauseItem creates a pause action.
 func processPauseItem(agent AgentSnapshot, item QueueItemSnapshot) TickAction {
 	var payload models.PausePayload
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/opencode-ai/swarm/internal/clock"
 	"github.com/opencode-ai/swarm/internal/models"
 )
 
@@ -353,8 +354,11 @@ func TestTick_PauseItem(t *testing.T) {
 }
 
 func TestTick_AutoResume(t *testing.T) {
-	now := time.Now().UTC()
-	pastTime := now.Add(-5 * time.Minute)
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	pausedUntil := fake.Now().Add(5 * time.Minute)
+	fake.Advance(5*time.Minute + time.Second)
+	now := fake.Now()
+	pastTime := pausedUntil
 
 	input := TickInput{
 		Agents: []AgentSnapshot{{
@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// DefaultVersionCheckInterval is how often agent CLI versions are re-probed.
+const DefaultVersionCheckInterval = 15 * time.Minute
+
+// versionChecks holds when agent CLI versions are next re-probed.
+type versionChecks struct {
+	interval time.Duration
+	next     clock.Deadline
+}
+
+// WithVersionChecks re-probes the installed agent CLI of live agents every
+// interval, so upgrades on a node show up as version drift. A non-positive
+// interval uses DefaultVersionCheckInterval.
+func WithVersionChecks(interval time.Duration) Option {
+	return func(s *Scheduler) {
+		if interval <= 0 {
+			interval = DefaultVersionCheckInterval
+		}
+		s.versionChecks = &versionChecks{interval: interval}
+	}
+}
+
+// checkCLIVersions re-probes agent CLI versions once the interval since the
+// last check has passed. The first tick checks straight away.
+func (s *Scheduler) checkCLIVersions(ctx context.Context, agents []*models.Agent) {
+	checks := s.versionChecks
+	if !checks.next.IsZero() && !checks.next.Expired(s.clock) {
+		return
+	}
+	checks.next = clock.NewDeadline(s.clock, checks.interval)
+
+	updated, err := s.agentService.RefreshCLIVersions(ctx, agents)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to check agent CLI versions")
+		return
+	}
+	s.logger.Debug().Int("agents", updated).Msg("checked agent CLI versions")
+}
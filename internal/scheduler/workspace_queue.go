@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// WithWorkspaceQueue lets the scheduler assign items from workspace-level
+// queues (swarm queue add --any-agent) to agents in their workspace.
+func WithWorkspaceQueue(repo *db.WorkspaceQueueRepository) Option {
+	return func(s *Scheduler) {
+		s.workspaceQueue = repo
+	}
+}
+
+// assignWorkspaceItems moves pending workspace items, oldest first, onto
+// the queue of the first eligible agent: an idle agent with nothing
+// queued, or else a standby agent claimed for the item. Once an item in a
+// workspace cannot be placed, later items in that workspace wait too, so
+// items are handed out in the order they were queued. It returns agents
+// with any claimed standby agents added and queue lengths updated.
+func (s *Scheduler) assignWorkspaceItems(ctx context.Context, agents []*models.Agent) []*models.Agent {
+	pending, err := s.workspaceQueue.ListPending(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to list workspace queue items")
+		return agents
+	}
+
+	blocked := make(map[string]bool)
+	for _, item := range pending {
+		if blocked[item.WorkspaceID] {
+			continue
+		}
+
+		target := s.idleAgentFor(agents, item)
+		if target == nil {
+			claimed, err := s.agentService.ClaimStandby(ctx, item.WorkspaceID, item.AgentType)
+			if err != nil {
+				if !errors.Is(err, agent.ErrNoStandbyAgent) {
+					s.logger.Warn().Err(err).Str("workspace_id", item.WorkspaceID).Msg("failed to claim standby agent")
+				}
+				blocked[item.WorkspaceID] = true
+				continue
+			}
+			target = claimed
+			agents = replaceAgent(agents, claimed)
+		}
+
+		queued, err := s.workspaceQueue.Assign(ctx, item.ID, target.ID)
+		if err != nil {
+			if !errors.Is(err, db.ErrWorkspaceQueueItemAssigned) {
+				s.logger.Warn().Err(err).Str("item_id", item.ID).Msg("failed to assign workspace queue item")
+				blocked[item.WorkspaceID] = true
+			}
+			continue
+		}
+		target.QueueLength++
+
+		s.logger.Info().
+			Str("item_id", item.ID).
+			Str("workspace_id", item.WorkspaceID).
+			Str("agent_id", target.ID).
+			Str("queue_item_id", queued.ID).
+			Msg("workspace queue item assigned")
+	}
+	return agents
+}
+
+// idleAgentFor returns the first agent in the item's workspace that is idle
+// with an empty queue and matches its agent type. Standby agents are left
+// for ClaimStandby so their pool is refilled, and draining agents take no
+// new work.
+func (s *Scheduler) idleAgentFor(agents []*models.Agent, item *models.WorkspaceQueueItem) *models.Agent {
+	for _, a := range agents {
+		if a.WorkspaceID != item.WorkspaceID || a.Standby || a.Draining {
+			continue
+		}
+		if item.AgentType != "" && a.Type != item.AgentType {
+			continue
+		}
+		if a.State != models.AgentStateIdle || a.QueueLength > 0 {
+			continue
+		}
+		if s.IsAgentPaused(a.ID) || s.isRetryBackoffActive(a.ID) {
+			continue
+		}
+		return a
+	}
+	return nil
+}
+
+// replaceAgent swaps in updated for the agent with the same ID, appending
+// it when it is not listed.
+func replaceAgent(agents []*models.Agent, updated *models.Agent) []*models.Agent {
+	for i, a := range agents {
+		if a.ID == updated.ID {
+			agents[i] = updated
+			return agents
+		}
+	}
+	return append(agents, updated)
+}
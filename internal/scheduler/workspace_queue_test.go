@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/agent"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/queue"
+)
+
+func TestAssignWorkspaceItems(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	workspaceRepo := db.NewWorkspaceRepository(database)
+	ws := &models.Workspace{NodeID: localNode.ID, Name: "alpha", RepoPath: "/repo", TmuxSession: "alpha"}
+	other := &models.Workspace{NodeID: localNode.ID, Name: "beta", RepoPath: "/beta", TmuxSession: "beta"}
+	for _, w := range []*models.Workspace{ws, other} {
+		if err := workspaceRepo.Create(ctx, w); err != nil {
+			t.Fatalf("failed to create workspace: %v", err)
+		}
+	}
+
+	agentRepo := db.NewAgentRepository(database)
+	panes := 0
+	newAgent := func(workspaceID string, state models.AgentState, standby bool) *models.Agent {
+		panes++
+		a := &models.Agent{WorkspaceID: workspaceID, Type: models.AgentTypeOpenCode, TmuxPane: fmt.Sprintf("alpha:0.%d", panes), State: state, Standby: standby}
+		if err := agentRepo.Create(ctx, a); err != nil {
+			t.Fatalf("failed to create agent: %v", err)
+		}
+		return a
+	}
+	newAgent(ws.ID, models.AgentStateWorking, false)
+	standby := newAgent(ws.ID, models.AgentStateIdle, true)
+	idle := newAgent(ws.ID, models.AgentStateIdle, false)
+	elsewhere := newAgent(other.ID, models.AgentStateIdle, false)
+
+	workspaceQueue := db.NewWorkspaceQueueRepository(database)
+	enqueue := func(workspaceID, text string) *models.WorkspaceQueueItem {
+		payload, _ := json.Marshal(models.MessagePayload{Text: text})
+		item := &models.WorkspaceQueueItem{WorkspaceID: workspaceID, Type: models.QueueItemTypeMessage, Payload: payload}
+		if err := workspaceQueue.Enqueue(ctx, item); err != nil {
+			t.Fatalf("failed to enqueue: %v", err)
+		}
+		return item
+	}
+	first := enqueue(ws.ID, "first")
+	second := enqueue(ws.ID, "second")
+	third := enqueue(ws.ID, "third")
+	beta := enqueue(other.ID, "beta")
+
+	queueRepo := db.NewQueueRepository(database)
+	agentSvc := agent.NewService(agentRepo, queueRepo, nil, nil, nil)
+	sched := New(DefaultConfig(), agentSvc, queue.NewService(queueRepo), nil, nil, WithWorkspaceQueue(workspaceQueue))
+
+	agents, err := agentSvc.ListAgents(ctx, agent.ListAgentsOptions{IncludeQueueLength: true})
+	if err != nil {
+		t.Fatalf("failed to list agents: %v", err)
+	}
+	agents = sched.assignWorkspaceItems(ctx, agents)
+
+	assignedTo := func(item *models.WorkspaceQueueItem) string {
+		got, err := workspaceQueue.Get(ctx, item.ID)
+		if err != nil {
+			t.Fatalf("failed to get item: %v", err)
+		}
+		return got.AgentID
+	}
+	// The idle agent takes the oldest item, a standby the next, and the
+	// third waits since every agent now has work.
+	if got := assignedTo(first); got != idle.ID {
+		t.Fatalf("expected the first item to go to the idle agent, got %q", got)
+	}
+	if got := assignedTo(second); got != standby.ID {
+		t.Fatalf("expected the second item to claim the standby agent, got %q", got)
+	}
+	if got := assignedTo(third); got != "" {
+		t.Fatalf("expected the third item to stay pending, got %q", got)
+	}
+	if got := assignedTo(beta); got != elsewhere.ID {
+		t.Fatalf("expected the other workspace's item to go to its agent, got %q", got)
+	}
+
+	for _, a := range agents {
+		switch a.ID {
+		case idle.ID, standby.ID, elsewhere.ID:
+			if a.QueueLength != 1 {
+				t.Fatalf("expected agent %s to have one queued item, got %d", a.ID, a.QueueLength)
+			}
+		}
+		if a.ID == standby.ID && a.Standby {
+			t.Fatal("expected the claimed agent to leave standby")
+		}
+	}
+	items, err := queueRepo.List(ctx, standby.ID)
+	if err != nil {
+		t.Fatalf("failed to list queue: %v", err)
+	}
+	if len(items) != 1 || string(items[0].Payload) != string(second.Payload) {
+		t.Fatalf("expected the standby agent's queue to hold the second item, got %+v", items)
+	}
+}
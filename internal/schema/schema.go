@@ -0,0 +1,227 @@
+// Package schema generates JSON Schema documents for Swarm's JSON output
+// and validates output against them.
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Version is the version of Swarm's JSON output contract. Bump it when an
+// output type changes incompatibly (a field is removed, renamed, or
+// changes type).
+const Version = "1"
+
+// Draft is the JSON Schema dialect generated documents declare.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema. Only the keywords the
+// generator emits are modeled.
+type Schema struct {
+	Draft         string             `json:"$schema,omitempty"`
+	Title         string             `json:"title,omitempty"`
+	Description   string             `json:"description,omitempty"`
+	SchemaVersion string             `json:"schema_version,omitempty"`
+	Ref           string             `json:"$ref,omitempty"`
+	Type          []string           `json:"-"`
+	Format        string             `json:"format,omitempty"`
+	Properties    map[string]*Schema `json:"properties,omitempty"`
+	Required      []string           `json:"required,omitempty"`
+	Items         *Schema            `json:"items,omitempty"`
+	AnyOf         []*Schema          `json:"anyOf,omitempty"`
+	// AdditionalProperties is nil (allowed), a schema for map values, or
+	// Closed for structs.
+	AdditionalProperties *Schema            `json:"-"`
+	Closed               bool               `json:"-"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// MarshalJSON writes type as a string when there is one and
+// additionalProperties as false for closed objects.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	out := struct {
+		*plain
+		Type                 any `json:"type,omitempty"`
+		AdditionalProperties any `json:"additionalProperties,omitempty"`
+	}{plain: (*plain)(s)}
+
+	switch len(s.Type) {
+	case 0:
+	case 1:
+		out.Type = s.Type[0]
+	default:
+		out.Type = s.Type
+	}
+	if s.Closed {
+		out.AdditionalProperties = false
+	} else if s.AdditionalProperties != nil {
+		out.AdditionalProperties = s.AdditionalProperties
+	}
+	return json.Marshal(out)
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	durationType  = reflect.TypeOf(time.Duration(0))
+	rawType       = reflect.TypeOf(json.RawMessage(nil))
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generate builds the schema for values of type t as encoding/json would
+// marshal them. Named struct types are emitted once under $defs and
+// referenced, so recursive types terminate.
+func Generate(title string, t reflect.Type) *Schema {
+	g := &generator{defs: make(map[string]*Schema)}
+	root := g.schemaFor(t)
+	root.Draft = Draft
+	root.Title = title
+	root.SchemaVersion = Version
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+	return root
+}
+
+type generator struct {
+	defs map[string]*Schema
+}
+
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: []string{"string"}, Format: "date-time"}
+	case t == rawType:
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && (t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType)):
+		// Custom JSON encodings can produce anything.
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && t.Kind() != reflect.String && t.Implements(textType):
+		return &Schema{Type: []string{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schemaFor(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: []string{"boolean"}}
+	case reflect.String:
+		return &Schema{Type: []string{"string"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t == durationType {
+			return &Schema{Type: []string{"integer"}, Description: "duration in nanoseconds"}
+		}
+		return &Schema{Type: []string{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: []string{"number"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nullable(&Schema{Type: []string{"string"}, Format: "byte"})
+		}
+		return nullable(&Schema{Type: []string{"array"}, Items: g.schemaFor(t.Elem())})
+	case reflect.Array:
+		return &Schema{Type: []string{"array"}, Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return nullable(&Schema{Type: []string{"object"}, AdditionalProperties: g.schemaFor(t.Elem())})
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := defName(t)
+		if _, ok := g.defs[name]; !ok {
+			// Reserve the name before recursing so self-references resolve.
+			g.defs[name] = &Schema{}
+			*g.defs[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/$defs/" + name}
+	default:
+		// Interfaces and anything else encoding/json accepts.
+		return &Schema{}
+	}
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: []string{"object"}, Properties: make(map[string]*Schema), Closed: true}
+	g.addFields(s, t)
+	sort.Strings(s.Required)
+	return s
+}
+
+// addFields adds t's JSON fields to s, flattening untagged embedded
+// structs the way encoding/json does.
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(s, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = g.schemaFor(field.Type)
+		if !hasOption(opts, "omitempty") && !hasOption(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func hasOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// nullable lets s also match null, as nil pointers, slices, and maps
+// marshal to null.
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AnyOf: []*Schema{s, {Type: []string{"null"}}}}
+	}
+	if len(s.Type) == 0 {
+		return s
+	}
+	for _, typ := range s.Type {
+		if typ == "null" {
+			return s
+		}
+	}
+	s.Type = append(s.Type, "null")
+	return s
+}
+
+func defName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	if pkg == "" {
+		return t.Name()
+	}
+	return pkg + "." + t.Name()
+}
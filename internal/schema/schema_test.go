@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sampleBase struct {
+	ID string `json:"id"`
+}
+
+type sampleNode struct {
+	sampleBase
+	Name     string            `json:"name"`
+	Count    int               `json:"count,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Created  time.Time         `json:"created_at"`
+	Updated  *time.Time        `json:"updated_at,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Children []*sampleNode     `json:"children"`
+	Payload  json.RawMessage   `json:"payload,omitempty"`
+	Timeout  time.Duration     `json:"timeout"`
+	secret   string
+	Skipped  string `json:"-"`
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate("node", reflect.TypeOf(sampleNode{}))
+	if s.SchemaVersion != Version || s.Draft != Draft || s.Title != "node" {
+		t.Fatalf("unexpected document header: %+v", s)
+	}
+
+	def := s.Defs["schema.sampleNode"]
+	if def == nil || s.Ref != "#/$defs/schema.sampleNode" {
+		t.Fatalf("expected named struct under $defs, got ref %q defs %v", s.Ref, s.Defs)
+	}
+	want := []string{"children", "created_at", "id", "name", "ratio", "timeout"}
+	if !reflect.DeepEqual(def.Required, want) {
+		t.Fatalf("required = %v, want %v", def.Required, want)
+	}
+	for _, name := range []string{"secret", "Skipped", "sampleBase"} {
+		if _, ok := def.Properties[name]; ok {
+			t.Fatalf("unexpected property %q", name)
+		}
+	}
+	if got := def.Properties["created_at"].Format; got != "date-time" {
+		t.Fatalf("expected date-time format, got %q", got)
+	}
+	if items := def.Properties["children"].Items; items == nil || len(items.AnyOf) != 2 {
+		t.Fatalf("expected nullable self-reference for children, got %+v", items)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("marshal schema: %v", err)
+	}
+	for _, fragment := range []string{`"additionalProperties":false`, `"type":["object","null"]`, `"schema_version":"1"`} {
+		if !strings.Contains(string(data), fragment) {
+			t.Fatalf("expected %s in %s", fragment, data)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := Generate("node", reflect.TypeOf(sampleNode{}))
+
+	value := sampleNode{
+		sampleBase: sampleBase{ID: "n1"},
+		Name:       "root",
+		Created:    time.Now().UTC(),
+		Labels:     map[string]string{"env": "prod"},
+		Children:   []*sampleNode{{sampleBase: sampleBase{ID: "n2"}, Created: time.Now().UTC()}},
+		Payload:    json.RawMessage(`{"anything":[1,2]}`),
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := Validate(s, data); err != nil {
+		t.Fatalf("expected marshaled value to validate: %v", err)
+	}
+
+	cases := []struct {
+		doc  string
+		want string
+	}{
+		{`{"id":"x","name":"y","ratio":1,"timeout":0,"children":null}`, `missing required property "created_at"`},
+		{`{"id":"x","name":"y","ratio":1,"timeout":0,"children":null,"created_at":"2024-01-01T00:00:00Z","renamed":1}`, "$.renamed: unexpected property"},
+		{`{"id":"x","name":3,"ratio":1,"timeout":0,"children":null,"created_at":"2024-01-01T00:00:00Z"}`, "$.name: expected string, got integer"},
+		{`{"id":"x","name":"y","ratio":1,"timeout":0,"children":null,"created_at":"yesterday"}`, "invalid date-time"},
+		{`{"id":"x","name":"y","ratio":1,"timeout":0.5,"children":null,"created_at":"2024-01-01T00:00:00Z"}`, "$.timeout: expected integer, got number"},
+	}
+	for _, tc := range cases {
+		err := Validate(s, []byte(tc.doc))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Validate(%s) = %v, want error containing %q", tc.doc, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Validate checks a JSON document against a schema produced by Generate.
+// It understands only the keywords Generate emits and reports the first
+// mismatch with its JSON path.
+func Validate(s *Schema, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	v := &validator{defs: s.Defs}
+	return v.validate(s, value, "$")
+}
+
+type validator struct {
+	defs map[string]*Schema
+}
+
+func (v *validator) validate(s *Schema, value any, path string) error {
+	if s.Ref != "" {
+		target, ok := v.defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		if !ok {
+			return fmt.Errorf("%s: unresolved reference %s", path, s.Ref)
+		}
+		return v.validate(target, value, path)
+	}
+
+	if len(s.AnyOf) > 0 {
+		var errs []string
+		for _, option := range s.AnyOf {
+			err := v.validate(option, value, path)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err.Error())
+		}
+		return fmt.Errorf("%s: matches no allowed schema (%s)", path, strings.Join(errs, "; "))
+	}
+
+	if len(s.Type) > 0 {
+		actual := jsonType(value)
+		if !typeAllowed(s.Type, actual) {
+			return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(s.Type, " or "), actual)
+		}
+	}
+
+	switch typed := value.(type) {
+	case string:
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, typed); err != nil {
+				return fmt.Errorf("%s: invalid date-time %q", path, typed)
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range typed {
+				if err := v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := typed[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := path + "." + key
+			if prop, ok := s.Properties[key]; ok {
+				if err := v.validate(prop, typed[key], child); err != nil {
+					return err
+				}
+				continue
+			}
+			if s.Closed {
+				return fmt.Errorf("%s: unexpected property", child)
+			}
+			if s.AdditionalProperties != nil {
+				if err := v.validate(s.AdditionalProperties, typed[key], child); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func jsonType(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := typed.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func typeAllowed(allowed []string, actual string) bool {
+	for _, typ := range allowed {
+		if typ == actual || (typ == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
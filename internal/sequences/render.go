@@ -139,7 +139,14 @@ func defaultValue(def string, value any) string {
 }
 
 func conditionTypeFromStep(step SequenceStep) (models.ConditionType, string, error) {
-	whenRaw := strings.TrimSpace(step.When)
+	return ParseCondition(step.When, step.Expression)
+}
+
+// ParseCondition maps a conditional step's when value (idle, after-cooldown,
+// after-previous, queue-empty, custom, or expr:<expression>) and optional
+// expression to a condition type and the expression it carries.
+func ParseCondition(when, expression string) (models.ConditionType, string, error) {
+	whenRaw := strings.TrimSpace(when)
 	whenLower := strings.ToLower(whenRaw)
 
 	switch {
@@ -163,18 +170,18 @@ func conditionTypeFromStep(step SequenceStep) (models.ConditionType, string, err
 	case "idle", "when-idle", "whenidle":
 		return models.ConditionTypeWhenIdle, "", nil
 	case "after-cooldown", "cooldown", "cooldown-over", "aftercooldown":
-		return models.ConditionTypeAfterCooldown, strings.TrimSpace(step.Expression), nil
+		return models.ConditionTypeAfterCooldown, strings.TrimSpace(expression), nil
 	case "after-previous", "afterprevious":
 		return models.ConditionTypeAfterPrevious, "", nil
 	case "queue-empty", "queueempty":
 		return models.ConditionTypeCustomExpression, "queue_length == 0", nil
 	case "custom", "expression", "expr":
-		expr := strings.TrimSpace(step.Expression)
+		expr := strings.TrimSpace(expression)
 		if expr == "" {
 			return "", "", fmt.Errorf("conditional expression is required")
 		}
 		return models.ConditionTypeCustomExpression, expr, nil
 	default:
-		return "", "", fmt.Errorf("unknown conditional when %q", step.When)
+		return "", "", fmt.Errorf("unknown conditional when %q", when)
 	}
 }
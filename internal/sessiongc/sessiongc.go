@@ -0,0 +1,221 @@
+// Package sessiongc finds tmux sessions left behind by crashed swarm runs.
+//
+// A session is a candidate when it follows the swarm naming convention but
+// no workspace or agent refers to it. Sessions outside the convention and
+// referenced sessions are never selected.
+package sessiongc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/rs/zerolog"
+)
+
+// Options controls a Collect run.
+type Options struct {
+	// Prefix is the session naming convention prefix; sessions not named
+	// "<prefix>-..." are never considered. Defaults to
+	// tmux.DefaultSessionPrefix.
+	Prefix string
+	// OlderThan skips sessions with activity more recent than this. Zero
+	// considers every unreferenced session.
+	OlderThan time.Duration
+	// Kill kills the unreferenced sessions instead of only reporting them.
+	Kill bool
+	// Only limits the run to these session names, e.g. the ones a user
+	// confirmed from an earlier report. Empty considers every session.
+	Only []string
+}
+
+// Candidate describes one unreferenced tmux session.
+type Candidate struct {
+	Name    string `json:"name"`
+	Windows int    `json:"windows"`
+	Panes   int    `json:"panes"`
+	// LastActivity is zero when tmux did not report session activity.
+	LastActivity time.Time     `json:"last_activity,omitempty"`
+	Idle         time.Duration `json:"idle"`
+	Killed       bool          `json:"killed"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// Report summarizes a Collect run.
+type Report struct {
+	Kill bool `json:"kill"`
+	// Checked counts live sessions matching the naming convention.
+	Checked    int `json:"checked"`
+	Referenced int `json:"referenced"`
+	// Recent counts unreferenced sessions left alone by OlderThan.
+	Recent     int         `json:"recent"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+// Collector cross-references live tmux sessions against the workspaces
+// and agents in the database.
+type Collector struct {
+	workspaces *db.WorkspaceRepository
+	agents     *db.AgentRepository
+	tmux       *tmux.Client
+	clock      clock.Clock
+	logger     zerolog.Logger
+}
+
+// Option configures a Collector.
+type Option func(*Collector)
+
+// WithClock sets the time source idle times are measured against.
+func WithClock(c clock.Clock) Option {
+	return func(col *Collector) {
+		col.clock = c
+	}
+}
+
+// New creates a collector checking the sessions of client against
+// database.
+func New(database *db.DB, client *tmux.Client, logger zerolog.Logger, opts ...Option) *Collector {
+	c := &Collector{
+		workspaces: db.NewWorkspaceRepository(database),
+		agents:     db.NewAgentRepository(database),
+		tmux:       client,
+		logger:     logger,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.clock = clock.OrReal(c.clock)
+	return c
+}
+
+// Collect finds live tmux sessions that follow the naming convention but
+// are referenced by no workspace row and no agent. A session is referenced
+// when a workspace records it, an agent names it, or an agent's pane lives
+// in it. With opts.Kill the unreferenced sessions are killed; otherwise
+// they are only reported.
+func (c *Collector) Collect(ctx context.Context, opts Options) (*Report, error) {
+	prefix := strings.TrimSpace(opts.Prefix)
+	if prefix == "" {
+		prefix = tmux.DefaultSessionPrefix
+	}
+	prefixFilter := tmux.SanitizeSessionName(prefix)
+	if prefixFilter == "" {
+		return nil, fmt.Errorf("tmux prefix has no valid characters: %q", opts.Prefix)
+	}
+	prefixFilter += "-"
+
+	referenced, panes, err := c.references(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := c.tmux.ListSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Name < sessions[j].Name })
+
+	now := c.clock.Now()
+	report := &Report{Kill: opts.Kill, Candidates: []Candidate{}}
+	for _, session := range sessions {
+		name := strings.TrimSpace(session.Name)
+		if name == "" || !strings.HasPrefix(strings.ToLower(name), prefixFilter) {
+			continue
+		}
+		if len(opts.Only) > 0 && !slices.Contains(opts.Only, name) {
+			continue
+		}
+		report.Checked++
+		if referenced[tmux.NormalizeSessionName(name)] {
+			report.Referenced++
+			continue
+		}
+
+		sessionPanes, err := c.tmux.ListSessionPanes(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list panes for session %s: %w", name, err)
+		}
+		if sharesPane(sessionPanes, panes) {
+			report.Referenced++
+			continue
+		}
+
+		candidate := Candidate{
+			Name:         name,
+			Windows:      session.WindowCount,
+			Panes:        len(sessionPanes),
+			LastActivity: session.Activity,
+		}
+		if !session.Activity.IsZero() {
+			candidate.Idle = now.Sub(session.Activity).Truncate(time.Second)
+		}
+		// Without reported activity the idle time is unknown, so an age
+		// filter keeps the session.
+		if opts.OlderThan > 0 && (session.Activity.IsZero() || candidate.Idle < opts.OlderThan) {
+			report.Recent++
+			continue
+		}
+
+		if opts.Kill {
+			if err := c.tmux.KillSessionIfExists(ctx, name); err != nil {
+				candidate.Error = err.Error()
+			} else {
+				candidate.Killed = true
+				c.logger.Info().Str("session", name).Dur("idle", candidate.Idle).Msg("killed unreferenced tmux session")
+			}
+		}
+		report.Candidates = append(report.Candidates, candidate)
+	}
+
+	return report, nil
+}
+
+// references returns the normalized session names recorded on workspace
+// and agent rows, and the pane IDs agents are bound to.
+func (c *Collector) references(ctx context.Context) (map[string]bool, map[string]bool, error) {
+	workspaces, err := c.workspaces.List(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	agents, err := c.agents.List(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	sessions := make(map[string]bool, len(workspaces))
+	panes := make(map[string]bool, len(agents))
+	for _, ws := range workspaces {
+		if ws.TmuxSession != "" {
+			sessions[tmux.NormalizeSessionName(ws.TmuxSession)] = true
+		}
+	}
+	for _, agent := range agents {
+		if agent.TmuxSession != "" {
+			sessions[tmux.NormalizeSessionName(agent.TmuxSession)] = true
+		}
+		switch {
+		case tmux.IsPaneID(agent.TmuxPane):
+			panes[agent.TmuxPane] = true
+		case strings.Contains(agent.TmuxPane, ":"):
+			// A legacy "session:window.pane" target.
+			session, _, _ := strings.Cut(agent.TmuxPane, ":")
+			sessions[tmux.NormalizeSessionName(session)] = true
+		}
+	}
+	return sessions, panes, nil
+}
+
+func sharesPane(sessionPanes []tmux.Pane, panes map[string]bool) bool {
+	for _, pane := range sessionPanes {
+		if panes[pane.ID] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package sessiongc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/rs/zerolog"
+)
+
+// gcExecutor fakes a tmux server whose sessions have fixed panes and
+// activity times.
+type gcExecutor struct {
+	sessions []gcSession
+	commands []string
+}
+
+type gcSession struct {
+	name     string
+	activity time.Time
+	panes    []string
+}
+
+func (e *gcExecutor) Exec(ctx context.Context, cmd string) ([]byte, []byte, error) {
+	e.commands = append(e.commands, cmd)
+
+	fields := strings.Fields(cmd)
+	switch {
+	case strings.HasPrefix(cmd, "tmux list-sessions"):
+		var out strings.Builder
+		for _, sess := range e.sessions {
+			fmt.Fprintf(&out, "%s|1|%d\n", sess.name, sess.activity.Unix())
+		}
+		return []byte(out.String()), nil, nil
+	case strings.HasPrefix(cmd, "tmux list-panes -t "):
+		name := strings.Trim(fields[3], "'")
+		var out strings.Builder
+		for _, sess := range e.sessions {
+			if sess.name != name {
+				continue
+			}
+			for i, pane := range sess.panes {
+				fmt.Fprintf(&out, "%s|0|%d|/tmp|0|0|bash\n", pane, i)
+			}
+		}
+		return []byte(out.String()), nil, nil
+	case strings.HasPrefix(cmd, "tmux kill-session -t "):
+		name := strings.Trim(fields[3], "'")
+		for i, sess := range e.sessions {
+			if sess.name == name {
+				e.sessions = append(e.sessions[:i], e.sessions[i+1:]...)
+				break
+			}
+		}
+		return nil, nil, nil
+	default:
+		return nil, nil, nil
+	}
+}
+
+func (e *gcExecutor) killed() []string {
+	var names []string
+	for _, cmd := range e.commands {
+		if strings.HasPrefix(cmd, "tmux kill-session -t ") {
+			names = append(names, strings.Trim(strings.Fields(cmd)[3], "'"))
+		}
+	}
+	return names
+}
+
+func openTestDB(t *testing.T) (*db.DB, *models.Node) {
+	t.Helper()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(context.Background()); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusUnknown, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(context.Background(), localNode); err != nil {
+		t.Fatalf("failed to create local node: %v", err)
+	}
+	return database, localNode
+}
+
+func TestCollect(t *testing.T) {
+	database, localNode := openTestDB(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-48 * time.Hour)
+
+	exec := &gcExecutor{sessions: []gcSession{
+		{name: "swarm-crashed-ab12", activity: old, panes: []string{"%1", "%2"}},
+		{name: "swarm-recent-cd34", activity: now.Add(-time.Hour), panes: []string{"%3"}},
+		{name: "swarm-imported", activity: old, panes: []string{"%4"}},
+		{name: "swarm-bound-pane", activity: old, panes: []string{"%5", "%9"}},
+		{name: "swarm-bound-session", activity: old, panes: []string{"%6"}},
+		{name: "swarm-legacy", activity: old, panes: []string{"%7"}},
+		{name: "notes", activity: old, panes: []string{"%8"}},
+	}}
+	collector := New(database, tmux.NewClient(exec), zerolog.Nop(), WithClock(clock.NewFake(now)))
+
+	ws := &models.Workspace{ID: "ws-imported", Name: "imported", NodeID: localNode.ID, RepoPath: "/repos/imported", TmuxSession: "swarm-imported", Status: models.WorkspaceStatusActive}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agents := []*models.Agent{
+		{WorkspaceID: ws.ID, TmuxPane: "%9"},
+		{WorkspaceID: ws.ID, TmuxPane: "%40", TmuxSession: "swarm-bound-session"},
+		{WorkspaceID: ws.ID, TmuxPane: "swarm-legacy:0.0"},
+	}
+	agentRepo := db.NewAgentRepository(database)
+	for _, a := range agents {
+		a.Type = models.AgentTypeOpenCode
+		a.State = models.AgentStateIdle
+		a.StateInfo = models.StateInfo{State: models.AgentStateIdle, Confidence: models.StateConfidenceHigh}
+		if err := agentRepo.Create(ctx, a); err != nil {
+			t.Fatalf("failed to create agent: %v", err)
+		}
+	}
+
+	report, err := collector.Collect(ctx, Options{})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if report.Checked != 6 || report.Referenced != 4 {
+		t.Fatalf("expected 6 checked and 4 referenced, got %+v", report)
+	}
+	if len(report.Candidates) != 2 || report.Candidates[0].Name != "swarm-crashed-ab12" || report.Candidates[1].Name != "swarm-recent-cd34" {
+		t.Fatalf("unexpected candidates: %+v", report.Candidates)
+	}
+	crashed := report.Candidates[0]
+	if crashed.Panes != 2 || crashed.Idle != 48*time.Hour || !crashed.LastActivity.Equal(old) || crashed.Killed {
+		t.Fatalf("unexpected crashed session report: %+v", crashed)
+	}
+	if killed := exec.killed(); len(killed) != 0 {
+		t.Fatalf("report-only run killed %v", killed)
+	}
+
+	report, err = collector.Collect(ctx, Options{OlderThan: 24 * time.Hour, Kill: true})
+	if err != nil {
+		t.Fatalf("Collect kill failed: %v", err)
+	}
+	if report.Recent != 1 || len(report.Candidates) != 1 || !report.Candidates[0].Killed {
+		t.Fatalf("expected only the old crashed session killed, got %+v", report)
+	}
+	if killed := exec.killed(); len(killed) != 1 || killed[0] != "swarm-crashed-ab12" {
+		t.Fatalf("expected only swarm-crashed-ab12 killed, got %v", killed)
+	}
+}
+
+func TestCollectCustomPrefix(t *testing.T) {
+	database, _ := openTestDB(t)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	exec := &gcExecutor{sessions: []gcSession{
+		{name: "swarm-left", activity: now.Add(-time.Hour), panes: []string{"%1"}},
+		{name: "team-left", activity: now.Add(-time.Hour), panes: []string{"%2"}},
+	}}
+	collector := New(database, tmux.NewClient(exec), zerolog.Nop(), WithClock(clock.NewFake(now)))
+
+	report, err := collector.Collect(context.Background(), Options{Prefix: "team"})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if report.Checked != 1 || len(report.Candidates) != 1 || report.Candidates[0].Name != "team-left" {
+		t.Fatalf("expected only team-left considered, got %+v", report)
+	}
+}
@@ -0,0 +1,10 @@
+// Package shell quotes values for the POSIX shell command lines swarm runs
+// in tmux panes and on remote nodes.
+package shell
+
+import "strings"
+
+// Quote quotes s as a single POSIX shell word.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,24 @@
+package shell
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "''"},
+		{"simple", "'simple'"},
+		{"with spaces", "'with spaces'"},
+		{"with'quote", "'with'\\''quote'"},
+		{"$variable", "'$variable'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Quote(tt.input); got != tt.want {
+				t.Errorf("Quote(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+// Package snapshot stores captured agent pane content by its sha256 hash,
+// with a metadata entry per capture.
+//
+// Identical captures share one blob; each capture still gets its own
+// metadata entry, so a snapshot referenced in a bug report keeps its note
+// and capture time even when the content repeats.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/fsperm"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+const (
+	// DefaultMaxBytes caps the total size of stored blobs.
+	DefaultMaxBytes int64 = 256 << 20
+
+	// DefaultMaxAge is how long snapshots are kept.
+	DefaultMaxAge = 30 * 24 * time.Hour
+)
+
+// Snapshot errors.
+var (
+	ErrNotFound  = errors.New("snapshot not found")
+	ErrAmbiguous = errors.New("snapshot id prefix is ambiguous")
+	ErrCorrupt   = errors.New("snapshot content does not match its hash")
+)
+
+// Snapshot is the metadata recorded for one capture.
+type Snapshot struct {
+	ID          string            `json:"id"`
+	Hash        string            `json:"hash"`
+	Size        int64             `json:"size"`
+	AgentID     string            `json:"agent_id"`
+	WorkspaceID string            `json:"workspace_id,omitempty"`
+	CapturedAt  time.Time         `json:"captured_at"`
+	History     bool              `json:"history"`
+	State       models.AgentState `json:"state,omitempty"`
+	Note        string            `json:"note,omitempty"`
+}
+
+// PruneResult reports what a prune removed.
+type PruneResult struct {
+	Snapshots int   `json:"snapshots"`
+	Blobs     int   `json:"blobs"`
+	Bytes     int64 `json:"bytes"`
+}
+
+// Store keeps snapshots under a directory:
+//
+//	blobs/<hash[:2]>/<hash>  pane content
+//	meta/<id>.json           one entry per capture
+type Store struct {
+	dir      string
+	maxBytes int64
+	maxAge   time.Duration
+	clock    clock.Clock
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithMaxBytes caps the total blob size. The oldest snapshots are pruned
+// past the cap; zero disables it.
+func WithMaxBytes(n int64) Option {
+	return func(s *Store) {
+		s.maxBytes = n
+	}
+}
+
+// WithMaxAge prunes snapshots older than d; zero disables it.
+func WithMaxAge(d time.Duration) Option {
+	return func(s *Store) {
+		s.maxAge = d
+	}
+}
+
+// WithClock sets the clock used for capture times and age pruning.
+func WithClock(c clock.Clock) Option {
+	return func(s *Store) {
+		s.clock = c
+	}
+}
+
+// NewStore returns a store rooted at dir.
+func NewStore(dir string, opts ...Option) *Store {
+	s := &Store{
+		dir:      dir,
+		maxBytes: DefaultMaxBytes,
+		maxAge:   DefaultMaxAge,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.clock = clock.OrReal(s.clock)
+	return s
+}
+
+// Dir returns the store's root directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// BlobPath returns where content with hash is stored.
+func (s *Store) BlobPath(hash string) string {
+	prefix := hash
+	if len(prefix) > 2 {
+		prefix = prefix[:2]
+	}
+	return filepath.Join(s.dir, "blobs", prefix, hash)
+}
+
+func (s *Store) metaDir() string {
+	return filepath.Join(s.dir, "meta")
+}
+
+// Save stores content and records snap for it, filling in its ID, hash,
+// size, and capture time. Content already stored is not written again.
+// The store is pruned afterwards.
+func (s *Store) Save(snap *Snapshot, content string) error {
+	if snap == nil || strings.TrimSpace(snap.AgentID) == "" {
+		return fmt.Errorf("snapshot agent id is required")
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	snap.Hash = hex.EncodeToString(sum[:])
+	snap.Size = int64(len(content))
+	if snap.ID == "" {
+		snap.ID = uuid.New().String()
+	}
+	if snap.CapturedAt.IsZero() {
+		snap.CapturedAt = s.clock.Now().UTC()
+	}
+
+	if err := s.writeBlob(snap.Hash, content); err != nil {
+		return fmt.Errorf("failed to write snapshot blob: %w", err)
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(s.metaDir(), snap.ID+".json"), append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write snapshot metadata: %w", err)
+	}
+
+	_, err = s.Prune()
+	return err
+}
+
+func (s *Store) writeBlob(hash, content string) error {
+	path := s.BlobPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	return writeFileAtomic(path, []byte(content))
+}
+
+// List returns snapshots oldest first, only those of agentID if it is set.
+func (s *Store) List(agentID string) ([]*Snapshot, error) {
+	entries, err := os.ReadDir(s.metaDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Snapshot{}, nil
+		}
+		return nil, err
+	}
+
+	snaps := make([]*Snapshot, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		snap, err := s.readMeta(filepath.Join(s.metaDir(), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if agentID != "" && snap.AgentID != agentID {
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+	sort.Slice(snaps, func(i, j int) bool {
+		if !snaps[i].CapturedAt.Equal(snaps[j].CapturedAt) {
+			return snaps[i].CapturedAt.Before(snaps[j].CapturedAt)
+		}
+		return snaps[i].ID < snaps[j].ID
+	})
+	return snaps, nil
+}
+
+// Get returns the snapshot with id, or the only one whose ID starts with it.
+func (s *Store) Get(id string) (*Snapshot, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, ErrNotFound
+	}
+	snap, err := s.readMeta(filepath.Join(s.metaDir(), filepath.Base(id)+".json"))
+	if err == nil {
+		return snap, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	snaps, err := s.List("")
+	if err != nil {
+		return nil, err
+	}
+	var match *Snapshot
+	for _, candidate := range snaps {
+		if !strings.HasPrefix(candidate.ID, id) {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("%w: %s", ErrAmbiguous, id)
+		}
+		match = candidate
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return match, nil
+}
+
+// Content returns the pane content captured by snap.
+func (s *Store) Content(snap *Snapshot) (string, error) {
+	data, err := os.ReadFile(s.BlobPath(snap.Hash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: blob %s missing", ErrNotFound, snap.Hash)
+		}
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != snap.Hash {
+		return "", fmt.Errorf("%w: %s", ErrCorrupt, snap.ID)
+	}
+	return string(data), nil
+}
+
+// Prune drops snapshots older than the age cap, then the oldest snapshots
+// until the blobs they reference fit the size cap, and finally deletes
+// blobs no snapshot references. The newest snapshot is always kept.
+func (s *Store) Prune() (*PruneResult, error) {
+	snaps, err := s.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]int)
+	sizes := make(map[string]int64)
+	var total int64
+	for _, snap := range snaps {
+		if refs[snap.Hash] == 0 {
+			sizes[snap.Hash] = snap.Size
+			total += snap.Size
+		}
+		refs[snap.Hash]++
+	}
+
+	result := &PruneResult{}
+	cutoff := s.clock.Now().Add(-s.maxAge)
+	for i, snap := range snaps {
+		if i == len(snaps)-1 {
+			break
+		}
+		expired := s.maxAge > 0 && snap.CapturedAt.Before(cutoff)
+		oversize := s.maxBytes > 0 && total > s.maxBytes
+		if !expired && !oversize {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.metaDir(), snap.ID+".json")); err != nil && !os.IsNotExist(err) {
+			return result, err
+		}
+		result.Snapshots++
+		refs[snap.Hash]--
+		if refs[snap.Hash] == 0 {
+			total -= sizes[snap.Hash]
+		}
+	}
+
+	blobs, err := filepath.Glob(filepath.Join(s.dir, "blobs", "*", "*"))
+	if err != nil {
+		return result, err
+	}
+	for _, path := range blobs {
+		// Skip referenced blobs and writes still in progress.
+		name := filepath.Base(path)
+		if refs[name] > 0 || strings.HasPrefix(name, ".") {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return result, err
+		}
+		result.Blobs++
+		result.Bytes += info.Size()
+	}
+	return result, nil
+}
+
+func (s *Store) readMeta(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("invalid snapshot metadata %s: %w", filepath.Base(path), err)
+	}
+	return &snap, nil
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames
+// it into place, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := fsperm.MkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
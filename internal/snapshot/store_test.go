@@ -0,0 +1,169 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestSaveLayoutAndDedup(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	store := NewStore(t.TempDir(), WithClock(fake))
+
+	first := &Snapshot{AgentID: "agent-1", WorkspaceID: "ws-1", History: true, State: models.AgentStateIdle, Note: "before"}
+	if err := store.Save(first, "claude> hello\n"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	// sha256("claude> hello\n")
+	const hash = "bf8f4f35db6d9068212a987582f245e0175eed6152d72a511d8e82448694d226"
+	if first.Hash != hash || first.Size != 14 || first.ID == "" || !first.CapturedAt.Equal(fake.Now()) {
+		t.Fatalf("unexpected snapshot %+v", first)
+	}
+	blob := filepath.Join(store.Dir(), "blobs", "bf", hash)
+	if data, err := os.ReadFile(blob); err != nil || string(data) != "claude> hello\n" {
+		t.Fatalf("expected content at %s, got %q (%v)", blob, data, err)
+	}
+	if _, err := os.Stat(filepath.Join(store.Dir(), "meta", first.ID+".json")); err != nil {
+		t.Fatalf("expected metadata file: %v", err)
+	}
+
+	fake.Advance(time.Minute)
+	second := &Snapshot{AgentID: "agent-1", Note: "after"}
+	if err := store.Save(second, "claude> hello\n"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if second.Hash != first.Hash || second.ID == first.ID {
+		t.Fatalf("expected identical content to share a blob under a new entry, got %+v and %+v", first, second)
+	}
+	blobs, _ := filepath.Glob(filepath.Join(store.Dir(), "blobs", "*", "*"))
+	if len(blobs) != 1 {
+		t.Fatalf("expected one blob, got %v", blobs)
+	}
+
+	got, err := store.Get(second.ID[:8])
+	if err != nil || got.Note != "after" {
+		t.Fatalf("expected Get by prefix to find the second snapshot, got %+v (%v)", got, err)
+	}
+	content, err := store.Content(got)
+	if err != nil || content != "claude> hello\n" {
+		t.Fatalf("Content() = %q, %v", content, err)
+	}
+	if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := os.WriteFile(blob, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Content(got); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt for a changed blob, got %v", err)
+	}
+}
+
+func TestListFiltersAndOrders(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	store := NewStore(t.TempDir(), WithClock(fake))
+
+	if snaps, err := store.List(""); err != nil || len(snaps) != 0 {
+		t.Fatalf("expected an empty store to list nothing, got %v (%v)", snaps, err)
+	}
+	for _, tc := range []struct{ agent, content string }{
+		{"agent-1", "one"}, {"agent-2", "two"}, {"agent-1", "three"},
+	} {
+		if err := store.Save(&Snapshot{AgentID: tc.agent}, tc.content); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		fake.Advance(time.Second)
+	}
+
+	all, err := store.List("")
+	if err != nil || len(all) != 3 {
+		t.Fatalf("expected three snapshots, got %v (%v)", all, err)
+	}
+	mine, err := store.List("agent-1")
+	if err != nil || len(mine) != 2 || !mine[0].CapturedAt.Before(mine[1].CapturedAt) {
+		t.Fatalf("expected agent-1's snapshots oldest first, got %v (%v)", mine, err)
+	}
+}
+
+func TestPruneSizeCap(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	store := NewStore(t.TempDir(), WithClock(fake), WithMaxBytes(10))
+
+	save := func(content string) *Snapshot {
+		t.Helper()
+		snap := &Snapshot{AgentID: "agent-1"}
+		if err := store.Save(snap, content); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		fake.Advance(time.Second)
+		return snap
+	}
+	oldest := save("aaaa")
+	save("bbbb")
+	// A repeat of existing content adds no bytes, so nothing is pruned.
+	save("aaaa")
+	if snaps, _ := store.List(""); len(snaps) != 3 {
+		t.Fatalf("expected three snapshots under the cap, got %d", len(snaps))
+	}
+
+	newest := save("cccccc")
+	snaps, err := store.List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, snap := range snaps {
+		if snap.ID == oldest.ID {
+			t.Fatalf("expected the oldest snapshot to be pruned")
+		}
+	}
+	if snaps[len(snaps)-1].ID != newest.ID {
+		t.Fatalf("expected the newest snapshot to be kept")
+	}
+	if _, err := os.Stat(store.BlobPath(oldest.Hash)); err != nil {
+		t.Fatalf("expected a blob still referenced by a newer snapshot to stay: %v", err)
+	}
+
+	// Oversized content is kept as the newest snapshot; older blobs go.
+	huge := save(strings.Repeat("x", 32))
+	blobs, _ := filepath.Glob(filepath.Join(store.Dir(), "blobs", "*", "*"))
+	if len(blobs) != 1 || filepath.Base(blobs[0]) != huge.Hash {
+		t.Fatalf("expected only the newest blob to remain, got %v", blobs)
+	}
+}
+
+func TestPruneMaxAge(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	store := NewStore(t.TempDir(), WithClock(fake), WithMaxAge(time.Hour), WithMaxBytes(0))
+
+	old := &Snapshot{AgentID: "agent-1"}
+	if err := store.Save(old, "old"); err != nil {
+		t.Fatal(err)
+	}
+	fake.Advance(time.Minute)
+	if err := store.Save(&Snapshot{AgentID: "agent-1"}, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if snaps, _ := store.List(""); len(snaps) != 2 {
+		t.Fatalf("expected both snapshots within the age cap, got %d", len(snaps))
+	}
+	fake.Advance(2 * time.Hour)
+
+	result, err := store.Prune()
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.Snapshots != 1 || result.Blobs != 1 || result.Bytes != 3 {
+		t.Fatalf("expected the expired snapshot and its blob pruned, got %+v", result)
+	}
+	snaps, _ := store.List("")
+	if len(snaps) != 1 || snaps[0].ID == old.ID {
+		t.Fatalf("expected only the newest snapshot to survive, got %v", snaps)
+	}
+}
@@ -5,8 +5,13 @@ import (
 	"context"
 	"io"
 	"os/exec"
+	"time"
 )
 
+// localKillGrace is how long a cancelled command's output pipes are kept
+// open after its process group is killed.
+const localKillGrace = time.Second
+
 // LocalExecutor runs commands directly on the local machine.
 type LocalExecutor struct{}
 
@@ -40,7 +45,13 @@ func (e *LocalExecutor) exec(ctx context.Context, cmd string, stdin io.Reader) (
 	command := exec.CommandContext(ctx, "sh", "-c", cmd)
 	if stdin != nil {
 		command.Stdin = stdin
+	} else {
+		// Run in its own process group so cancellation kills the commands the
+		// shell started too, not just the shell. Interactive commands stay in
+		// the foreground group so they can read the terminal.
+		killProcessGroupOnCancel(command)
 	}
+	command.WaitDelay = localKillGrace
 
 	var stdoutBuf bytes.Buffer
 	var stderrBuf bytes.Buffer
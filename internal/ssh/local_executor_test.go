@@ -5,6 +5,7 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLocalExecutorExec(t *testing.T) {
@@ -23,6 +24,26 @@ func TestLocalExecutorExec(t *testing.T) {
 	}
 }
 
+func TestLocalExecutorExecCancelKillsChildren(t *testing.T) {
+	t.Parallel()
+
+	exec := NewLocalExecutor()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	stdout, _, err := exec.Exec(ctx, "echo started; sleep 10; echo finished")
+	if err == nil {
+		t.Fatalf("expected an error for a cancelled command")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected the command to be killed promptly, took %s", elapsed)
+	}
+	if string(stdout) != "started\n" {
+		t.Fatalf("expected output up to the kill, got %q", string(stdout))
+	}
+}
+
 func TestLocalExecutorExecInteractive(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package ssh
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroupOnCancel starts command in a process group of its own
+// and makes cancellation kill the whole group.
+func killProcessGroupOnCancel(command *exec.Cmd) {
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	command.Cancel = func() error {
+		return syscall.Kill(-command.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package ssh
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroupOnCancel starts command in a process group of its own.
+// Windows has no signal for a whole group, so cancellation kills only the
+// shell, as exec.CommandContext does by default.
+func killProcessGroupOnCancel(command *exec.Cmd) {
+	command.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -39,6 +39,9 @@ type StateChange struct {
 
 	// Timestamp is when the change was detected.
 	Timestamp time.Time
+
+	// Failure classifies the output of an agent entering the error state.
+	Failure *models.FailureClassification
 }
 
 // Subscriber receives state change notifications.
@@ -80,6 +83,9 @@ type DetectionResult struct {
 
 	// ProcessStats contains process resource metrics when available.
 	ProcessStats *models.ProcessStats
+
+	// Failure classifies the pane output when State is error.
+	Failure *models.FailureClassification
 }
 
 // Engine manages agent state detection and notifications.
@@ -88,23 +94,63 @@ type Engine struct {
 	eventRepo      *db.EventRepository
 	tmuxClient     *tmux.Client
 	registry       *adapters.Registry
-	subscribers    map[string]Subscriber
+	subscribers    map[string]*subscription
+	subscriberBuf  int
 	statsCollector *ProcessStatsCollector
+	stuck          *StuckDetector
+	failures       *FailureClassifier
 	mu             sync.RWMutex
 	logger         zerolog.Logger
 }
 
+// EngineOption configures an Engine.
+type EngineOption func(*Engine)
+
+// WithStuckDetection flags working agents that stop producing output,
+// using detector to track output and run recovery.
+func WithStuckDetection(detector *StuckDetector) EngineOption {
+	return func(e *Engine) {
+		e.stuck = detector
+	}
+}
+
+// WithSubscriberBuffer sets how many undelivered state changes each
+// subscriber may queue before the oldest are dropped.
+func WithSubscriberBuffer(n int) EngineOption {
+	return func(e *Engine) {
+		if n > 0 {
+			e.subscriberBuf = n
+		}
+	}
+}
+
+// WithFailureClassifier sets the classifier used to diagnose agents that
+// enter the error state. The default uses DefaultFailureRules.
+func WithFailureClassifier(classifier *FailureClassifier) EngineOption {
+	return func(e *Engine) {
+		if classifier != nil {
+			e.failures = classifier
+		}
+	}
+}
+
 // NewEngine creates a new StateEngine.
-func NewEngine(repo *db.AgentRepository, eventRepo *db.EventRepository, tmuxClient *tmux.Client, registry *adapters.Registry) *Engine {
-	return &Engine{
+func NewEngine(repo *db.AgentRepository, eventRepo *db.EventRepository, tmuxClient *tmux.Client, registry *adapters.Registry, opts ...EngineOption) *Engine {
+	e := &Engine{
 		repo:           repo,
 		eventRepo:      eventRepo,
 		tmuxClient:     tmuxClient,
 		registry:       registry,
-		subscribers:    make(map[string]Subscriber),
+		subscribers:    make(map[string]*subscription),
+		subscriberBuf:  DefaultSubscriberBuffer,
 		statsCollector: NewProcessStatsCollector(),
+		failures:       NewFailureClassifier(),
 		logger:         logging.Component("state-engine"),
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 // GetState retrieves the current state for an agent.
@@ -146,6 +192,12 @@ func (e *Engine) UpdateState(ctx context.Context, agentID string, state models.A
 
 // UpdateStateWithStats updates an agent's state with optional process stats.
 func (e *Engine) UpdateStateWithStats(ctx context.Context, agentID string, state models.AgentState, info models.StateInfo, usage *models.UsageMetrics, diff *models.DiffMetadata, stats *models.ProcessStats) error {
+	return e.updateState(ctx, agentID, state, info, usage, diff, stats, nil, nil)
+}
+
+// updateState persists a state update; lastOutput and failure are recorded
+// when set. Leaving the error state clears the stored failure.
+func (e *Engine) updateState(ctx context.Context, agentID string, state models.AgentState, info models.StateInfo, usage *models.UsageMetrics, diff *models.DiffMetadata, stats *models.ProcessStats, lastOutput *time.Time, failure *models.FailureClassification) error {
 	agent, err := e.repo.Get(ctx, agentID)
 	if err != nil {
 		if errors.Is(err, db.ErrAgentNotFound) {
@@ -161,7 +213,12 @@ func (e *Engine) UpdateStateWithStats(ctx context.Context, agentID string, state
 	agent.StateInfo = info
 	now := time.Now().UTC()
 	agent.LastActivity = &now
+	var usageEvent *models.Event
 	if usage != nil {
+		usageEvent, err = buildUsageRecordedEvent(agent, agent.Metadata.UsageMetrics, usage, now)
+		if err != nil {
+			return err
+		}
 		agent.Metadata.UsageMetrics = usage
 	}
 	if diff != nil {
@@ -170,9 +227,17 @@ func (e *Engine) UpdateStateWithStats(ctx context.Context, agentID string, state
 	if stats != nil {
 		agent.Metadata.ProcessStats = stats
 	}
+	if lastOutput != nil {
+		agent.LastOutputAt = lastOutput
+	}
+	if state != models.AgentStateError {
+		agent.Metadata.Failure = nil
+	} else if failure != nil {
+		agent.Metadata.Failure = failure
+	}
 
 	if previousState != state && e.eventRepo != nil {
-		event, err := buildStateChangeEvent(agentID, previousState, state, info, now)
+		event, err := buildStateChangeEvent(agentID, previousState, state, info, agent.Metadata.Failure, now)
 		if err != nil {
 			return err
 		}
@@ -182,6 +247,11 @@ func (e *Engine) UpdateStateWithStats(ctx context.Context, agentID string, state
 	} else if err := e.repo.Update(ctx, agent); err != nil {
 		return err
 	}
+	if usageEvent != nil && e.eventRepo != nil {
+		if err := e.eventRepo.Create(ctx, usageEvent); err != nil {
+			e.logger.Warn().Err(err).Str("agent_id", agentID).Msg("failed to record usage event")
+		}
+	}
 
 	// Notify subscribers if state changed
 	if previousState != state {
@@ -191,6 +261,7 @@ func (e *Engine) UpdateStateWithStats(ctx context.Context, agentID string, state
 			CurrentState:  state,
 			StateInfo:     info,
 			Timestamp:     now,
+			Failure:       agent.Metadata.Failure,
 		}
 		e.notifySubscribers(change)
 
@@ -208,6 +279,12 @@ func (e *Engine) DetectState(ctx context.Context, agentID string) (*DetectionRes
 		}
 		return nil, err
 	}
+	return e.detect(ctx, agent)
+}
+
+// detect captures the agent's screen and runs adapter detection.
+func (e *Engine) detect(ctx context.Context, agent *models.Agent) (*DetectionResult, error) {
+	agentID := agent.ID
 
 	// Capture the current screen
 	snapshot, err := CaptureSnapshot(ctx, e.tmuxClient, agent.TmuxPane, false)
@@ -275,16 +352,46 @@ func (e *Engine) DetectState(ctx context.Context, agentID string) (*DetectionRes
 
 	// Apply rule-based inference on top of adapter result when needed.
 	ApplyRuleBasedInference(result, screen)
+
+	if result.State == models.AgentStateError && e.failures != nil {
+		failure := e.failures.Classify(agent.Type, screen)
+		result.Failure = failure
+		result.Reason = appendReason(failure.String(), result.Reason)
+		if failure.Excerpt != "" {
+			result.Evidence = append(result.Evidence, "failure_excerpt="+failure.Excerpt)
+		}
+	}
 	return result, nil
 }
 
 // DetectAndUpdate detects the current state and updates the agent.
 func (e *Engine) DetectAndUpdate(ctx context.Context, agentID string) (*DetectionResult, error) {
-	result, err := e.DetectState(ctx, agentID)
+	agent, err := e.repo.Get(ctx, agentID)
 	if err != nil {
+		if errors.Is(err, db.ErrAgentNotFound) {
+			return nil, ErrAgentNotFound
+		}
 		return nil, err
 	}
 
+	result, err := e.detect(ctx, agent)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastOutput *time.Time
+	if e.stuck != nil {
+		verdict := e.stuck.Observe(ctx, agent, result.State, result.ScreenHash)
+		lastOutput = &verdict.LastOutputAt
+		if verdict.Stuck {
+			result.State = models.AgentStateStuck
+			result.Confidence = models.StateConfidenceMedium
+			result.Reason = verdict.Reason
+			result.Evidence = append(result.Evidence, "last output at "+verdict.LastOutputAt.Format(time.RFC3339))
+		}
+		e.recordStuckEvents(ctx, agentID, verdict)
+	}
+
 	info := models.StateInfo{
 		State:      result.State,
 		Confidence: result.Confidence,
@@ -293,70 +400,48 @@ func (e *Engine) DetectAndUpdate(ctx context.Context, agentID string) (*Detectio
 		DetectedAt: time.Now().UTC(),
 	}
 
-	if err := e.UpdateStateWithStats(ctx, agentID, result.State, info, result.UsageMetrics, result.DiffMetadata, result.ProcessStats); err != nil {
+	if err := e.updateState(ctx, agentID, result.State, info, result.UsageMetrics, result.DiffMetadata, result.ProcessStats, lastOutput, result.Failure); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
-// Subscribe registers a subscriber for state change notifications.
-func (e *Engine) Subscribe(id string, subscriber Subscriber) error {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-
-	if _, exists := e.subscribers[id]; exists {
-		return ErrSubscriberExists
+// recordStuckEvents logs the stuck flag and any recovery step it triggered.
+func (e *Engine) recordStuckEvents(ctx context.Context, agentID string, verdict StuckVerdict) {
+	if e.eventRepo == nil {
+		return
+	}
+	if verdict.Started {
+		e.createEvent(ctx, agentID, models.EventTypeAgentStuck, models.AgentStuckPayload{
+			LastOutputAt:  verdict.LastOutputAt,
+			SilentSeconds: verdict.SilentFor.Seconds(),
+			Reason:        verdict.Reason,
+		})
+	}
+	if attempt := verdict.Recovery; attempt != nil {
+		payload := models.AgentRecoveryPayload{Action: string(attempt.Action), Step: attempt.Step}
+		if attempt.Err != nil {
+			payload.Error = attempt.Err.Error()
+		}
+		e.createEvent(ctx, agentID, models.EventTypeAgentRecovery, payload)
 	}
-
-	e.subscribers[id] = subscriber
-	e.logger.Debug().Str("subscriber_id", id).Msg("subscriber registered")
-	return nil
 }
 
-// Unsubscribe removes a subscriber.
-func (e *Engine) Unsubscribe(id string) error {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-
-	if _, exists := e.subscribers[id]; !exists {
-		return ErrSubscriberMissing
+func (e *Engine) createEvent(ctx context.Context, agentID string, eventType models.EventType, payload any) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		e.logger.Warn().Err(err).Str("agent_id", agentID).Msg("failed to marshal event payload")
+		return
 	}
-
-	delete(e.subscribers, id)
-	e.logger.Debug().Str("subscriber_id", id).Msg("subscriber unregistered")
-	return nil
-}
-
-// SubscribeFunc is a convenience method to subscribe with a function.
-func (e *Engine) SubscribeFunc(id string, fn func(StateChange)) error {
-	return e.Subscribe(id, SubscriberFunc(fn))
-}
-
-// notifySubscribers sends a state change to all subscribers.
-func (e *Engine) notifySubscribers(change StateChange) {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
-
-	e.logger.Debug().
-		Str("agent_id", change.AgentID).
-		Str("from", string(change.PreviousState)).
-		Str("to", string(change.CurrentState)).
-		Int("subscribers", len(e.subscribers)).
-		Msg("notifying state change")
-
-	for id, subscriber := range e.subscribers {
-		go func(subID string, sub Subscriber) {
-			defer func() {
-				if r := recover(); r != nil {
-					e.logger.Error().
-						Str("subscriber_id", subID).
-						Interface("panic", r).
-						Msg("subscriber panicked")
-				}
-			}()
-			sub.OnStateChange(change)
-		}(id, subscriber)
+	event := &models.Event{
+		Type:       eventType,
+		EntityType: models.EntityTypeAgent,
+		EntityID:   agentID,
+		Payload:    payloadBytes,
+	}
+	if err := e.eventRepo.Create(ctx, event); err != nil {
+		e.logger.Warn().Err(err).Str("agent_id", agentID).Str("type", string(eventType)).Msg("failed to record event")
 	}
 }
 
@@ -458,7 +543,7 @@ func (e *Engine) logStateChange(ctx context.Context, agentID string, oldState, n
 		return nil
 	}
 
-	event, err := buildStateChangeEvent(agentID, oldState, newState, info, time.Time{})
+	event, err := buildStateChangeEvent(agentID, oldState, newState, info, nil, time.Time{})
 	if err != nil {
 		return err
 	}
@@ -466,12 +551,45 @@ func (e *Engine) logStateChange(ctx context.Context, agentID string, oldState, n
 	return e.eventRepo.Create(ctx, event)
 }
 
-func buildStateChangeEvent(agentID string, oldState, newState models.AgentState, info models.StateInfo, timestamp time.Time) (*models.Event, error) {
+// buildUsageRecordedEvent returns a usage.recorded event for the cost an
+// adapter reported for agent since its previous reading, or nil when the
+// cost did not go up. Adapters report running totals over a usage window,
+// so a first reading, or one from another source, is only a baseline.
+func buildUsageRecordedEvent(agent *models.Agent, previous, current *models.UsageMetrics, timestamp time.Time) (*models.Event, error) {
+	if previous == nil || current == nil || previous.Source != current.Source {
+		return nil, nil
+	}
+	cost := current.TotalCostCents - previous.TotalCostCents
+	if cost <= 0 {
+		return nil, nil
+	}
+	payloadBytes, err := json.Marshal(models.UsageRecordedPayload{
+		AccountID:   agent.AccountID,
+		AgentID:     agent.ID,
+		TotalTokens: max(current.TotalTokens-previous.TotalTokens, 0),
+		CostCents:   cost,
+		RecordedAt:  timestamp,
+		Source:      current.Source,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.Event{
+		Type:       models.EventTypeUsageRecorded,
+		EntityType: models.EntityTypeAgent,
+		EntityID:   agent.ID,
+		Timestamp:  timestamp,
+		Payload:    payloadBytes,
+	}, nil
+}
+
+func buildStateChangeEvent(agentID string, oldState, newState models.AgentState, info models.StateInfo, failure *models.FailureClassification, timestamp time.Time) (*models.Event, error) {
 	payload := models.StateChangedPayload{
 		OldState:   oldState,
 		NewState:   newState,
 		Confidence: info.Confidence,
 		Reason:     info.Reason,
+		Failure:    failure,
 	}
 
 	payloadBytes, err := json.Marshal(payload)
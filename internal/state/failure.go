@@ -0,0 +1,193 @@
+package state
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/redact"
+)
+
+// failureTailLines is how much of the end of the pane is classified.
+const failureTailLines = 60
+
+// maxFailureExcerpt caps the stored matching line.
+const maxFailureExcerpt = 240
+
+// FailureRule maps output matching Pattern to a failure classification.
+type FailureRule struct {
+	// AgentType limits the rule to one adapter; empty applies to all.
+	AgentType models.AgentType
+
+	Pattern  *regexp.Regexp
+	Category models.FailureCategory
+	Severity models.FailureSeverity
+	Action   models.FailureAction
+	Summary  string
+}
+
+// NewFailureRule compiles a rule. Patterns match case-insensitively.
+// Severity and action default from the category when empty.
+func NewFailureRule(agentType models.AgentType, pattern string, category models.FailureCategory, severity models.FailureSeverity, action models.FailureAction, summary string) (FailureRule, error) {
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return FailureRule{}, fmt.Errorf("invalid failure pattern %q: %w", pattern, err)
+	}
+	defaultSeverity, defaultAction, ok := categoryDefaults(category)
+	if !ok {
+		return FailureRule{}, fmt.Errorf("unknown failure category %q", category)
+	}
+	if severity == "" {
+		severity = defaultSeverity
+	}
+	if action == "" {
+		action = defaultAction
+	}
+	if summary == "" {
+		summary = strings.ReplaceAll(string(category), "_", " ") + " error"
+	}
+	return FailureRule{AgentType: agentType, Pattern: re, Category: category, Severity: severity, Action: action, Summary: summary}, nil
+}
+
+func categoryDefaults(category models.FailureCategory) (models.FailureSeverity, models.FailureAction, bool) {
+	switch category {
+	case models.FailureCategoryAuth:
+		return models.FailureSeverityCritical, models.FailureActionRotateAccount, true
+	case models.FailureCategoryRateLimit:
+		return models.FailureSeverityTransient, models.FailureActionRotateAccount, true
+	case models.FailureCategoryContextLength:
+		return models.FailureSeverityError, models.FailureActionCompactContext, true
+	case models.FailureCategoryNetwork:
+		return models.FailureSeverityTransient, models.FailureActionRestart, true
+	case models.FailureCategoryTask, models.FailureCategoryUnknown:
+		return models.FailureSeverityError, models.FailureActionInspect, true
+	default:
+		return "", "", false
+	}
+}
+
+func mustFailureRule(agentType models.AgentType, pattern string, category models.FailureCategory, summary string) FailureRule {
+	rule, err := NewFailureRule(agentType, pattern, category, "", "", summary)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// DefaultFailureRules returns the starter rules for the four adapters,
+// followed by rules that apply to any agent. Adapter rules come first so
+// their specific messages win over the generic ones.
+func DefaultFailureRules() []FailureRule {
+	const (
+		claude   = models.AgentTypeClaudeCode
+		codex    = models.AgentTypeCodex
+		gemini   = models.AgentTypeGemini
+		opencode = models.AgentTypeOpenCode
+	)
+	auth := models.FailureCategoryAuth
+	rate := models.FailureCategoryRateLimit
+	ctx := models.FailureCategoryContextLength
+	network := models.FailureCategoryNetwork
+	task := models.FailureCategoryTask
+
+	return []FailureRule{
+		// Claude Code
+		mustFailureRule(claude, `authentication_error|invalid x-api-key|Invalid API key.*/login`, auth, "API key rejected"),
+		mustFailureRule(claude, `OAuth token (has expired|revoked)`, auth, "login expired"),
+		mustFailureRule(claude, `usage limit reached|rate_limit_error`, rate, "usage limit reached"),
+		mustFailureRule(claude, `prompt is too long|context window.*exceeded`, ctx, "prompt exceeds the context window"),
+		mustFailureRule(claude, `overloaded_error|API Error \(Connection error`, network, "API overloaded or unreachable"),
+
+		// Codex
+		mustFailureRule(codex, `401 Unauthorized|invalid_api_key|Incorrect API key`, auth, "API key rejected"),
+		mustFailureRule(codex, `usage limit|429 Too Many Requests|rate_limit_exceeded`, rate, "usage limit reached"),
+		mustFailureRule(codex, `context_length_exceeded|exceeds the context window`, ctx, "input exceeds the context window"),
+		mustFailureRule(codex, `stream disconnected|stream error`, network, "response stream dropped"),
+
+		// Gemini
+		mustFailureRule(gemini, `API key not valid|PERMISSION_DENIED|UNAUTHENTICATED`, auth, "API key rejected"),
+		mustFailureRule(gemini, `RESOURCE_EXHAUSTED|Quota exceeded`, rate, "quota exhausted"),
+		mustFailureRule(gemini, `input token count.*exceeds the maximum`, ctx, "input exceeds the token limit"),
+		mustFailureRule(gemini, `fetch failed|UNAVAILABLE`, network, "API unreachable"),
+
+		// OpenCode
+		mustFailureRule(opencode, `ProviderAuthError|AI_APICallError: Unauthorized`, auth, "provider rejected the credentials"),
+		mustFailureRule(opencode, `AI_RetryError.*rate limit|AI_APICallError: Too Many Requests`, rate, "rate limit retries exhausted"),
+		mustFailureRule(opencode, `ContextOverflowError|maximum context length`, ctx, "conversation exceeds the context window"),
+		mustFailureRule(opencode, `AI_APICallError: (Cannot connect|fetch failed)`, network, "provider unreachable"),
+
+		// Any agent
+		mustFailureRule("", `\b401\b.*unauthori[sz]ed|invalid api key|authentication failed|not logged in`, auth, "credentials rejected"),
+		mustFailureRule("", `\b429\b|too many requests|rate limit`, rate, "rate limited"),
+		mustFailureRule("", `maximum context length|context_length_exceeded|context window|too many tokens`, ctx, "context window exceeded"),
+		mustFailureRule("", `\b(ECONNRESET|ECONNREFUSED|ETIMEDOUT|ENOTFOUND|EAI_AGAIN)\b|socket hang up|connection (reset|refused)|network (error|is unreachable)|TLS handshake timeout|i/o timeout|50[234] (Bad Gateway|Service Unavailable|Gateway Timeout)`, network, "network error"),
+		mustFailureRule("", `Traceback \(most recent call last\)|^panic: |npm ERR!|error\[E\d+\]|exit (code|status) [1-9]|command failed|tests? failed|(?-i:^FAIL\b)`, task, "the task's command failed"),
+	}
+}
+
+// FailureClassifier classifies the pane output of failed agents.
+type FailureClassifier struct {
+	rules []FailureRule
+	now   func() time.Time
+}
+
+// NewFailureClassifier creates a classifier that tries extra before the
+// default rules, so configured rules can override them.
+func NewFailureClassifier(extra ...FailureRule) *FailureClassifier {
+	rules := make([]FailureRule, 0, len(extra)+len(DefaultFailureRules()))
+	rules = append(rules, extra...)
+	rules = append(rules, DefaultFailureRules()...)
+	return &FailureClassifier{rules: rules, now: time.Now}
+}
+
+// Classify matches the tail of screen, newest line first, against the
+// rules for agentType. The most recent matching line decides; within a
+// line the first applicable rule wins. Output no rule matches is
+// classified unknown with its last line as the excerpt.
+func (c *FailureClassifier) Classify(agentType models.AgentType, screen string) *models.FailureClassification {
+	lines := tailLines(screen, failureTailLines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		for _, rule := range c.rules {
+			if rule.AgentType != "" && rule.AgentType != agentType {
+				continue
+			}
+			if rule.Pattern.MatchString(line) {
+				return c.result(rule.Category, rule.Severity, rule.Action, rule.Summary, line)
+			}
+		}
+	}
+
+	excerpt := ""
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			excerpt = line
+			break
+		}
+	}
+	return c.result(models.FailureCategoryUnknown, models.FailureSeverityError, models.FailureActionInspect, "no failure rule matched the output", excerpt)
+}
+
+func (c *FailureClassifier) result(category models.FailureCategory, severity models.FailureSeverity, action models.FailureAction, summary, line string) *models.FailureClassification {
+	return &models.FailureClassification{
+		Category:     category,
+		Severity:     severity,
+		Action:       action,
+		Summary:      summary,
+		Excerpt:      redact.TruncateTail(redact.Default().Redact(line), maxFailureExcerpt),
+		ClassifiedAt: c.now().UTC(),
+	}
+}
+
+func tailLines(screen string, n int) []string {
+	lines := strings.Split(strings.TrimRight(screen, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
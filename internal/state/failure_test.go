@@ -0,0 +1,286 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+func TestFailureClassifierDefaults(t *testing.T) {
+	tests := []struct {
+		name      string
+		agentType models.AgentType
+		screen    string
+		category  models.FailureCategory
+		action    models.FailureAction
+	}{
+		{
+			name:      "claude invalid key",
+			agentType: models.AgentTypeClaudeCode,
+			screen:    "> fix the tests\n⎿  Invalid API key · Please run /login\n",
+			category:  models.FailureCategoryAuth,
+			action:    models.FailureActionRotateAccount,
+		},
+		{
+			name:      "claude usage limit",
+			agentType: models.AgentTypeClaudeCode,
+			screen:    "● Working on it\n⎿  Claude usage limit reached. Your limit will reset at 3pm (Europe/Oslo).\n",
+			category:  models.FailureCategoryRateLimit,
+			action:    models.FailureActionRotateAccount,
+		},
+		{
+			name:      "claude prompt too long",
+			agentType: models.AgentTypeClaudeCode,
+			screen:    "API Error: 400 {\"type\":\"error\",\"error\":{\"type\":\"invalid_request_error\",\"message\":\"prompt is too long: 211842 tokens > 200000 maximum\"}}\n",
+			category:  models.FailureCategoryContextLength,
+			action:    models.FailureActionCompactContext,
+		},
+		{
+			name:      "claude overloaded",
+			agentType: models.AgentTypeClaudeCode,
+			screen:    "API Error: 529 {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n",
+			category:  models.FailureCategoryNetwork,
+			action:    models.FailureActionRestart,
+		},
+		{
+			name:      "codex unauthorized",
+			agentType: models.AgentTypeCodex,
+			screen:    "■ unexpected status 401 Unauthorized: Incorrect API key provided\n",
+			category:  models.FailureCategoryAuth,
+		},
+		{
+			name:      "codex usage limit",
+			agentType: models.AgentTypeCodex,
+			screen:    "■ You've hit your usage limit. Upgrade to Pro or try again in 2 days.\n",
+			category:  models.FailureCategoryRateLimit,
+		},
+		{
+			name:      "codex context window",
+			agentType: models.AgentTypeCodex,
+			screen:    "■ Your input exceeds the context window of this model. Please adjust your input and try again.\n",
+			category:  models.FailureCategoryContextLength,
+		},
+		{
+			name:      "codex stream dropped",
+			agentType: models.AgentTypeCodex,
+			screen:    "⚠ stream error: stream disconnected before completion: error sending request; retrying 5/5\n",
+			category:  models.FailureCategoryNetwork,
+		},
+		{
+			name:      "gemini quota",
+			agentType: models.AgentTypeGemini,
+			screen:    "✕ [API Error: {\"error\":{\"code\":429,\"message\":\"Quota exceeded for quota metric 'Generate Content API requests per minute'\",\"status\":\"RESOURCE_EXHAUSTED\"}}]\n",
+			category:  models.FailureCategoryRateLimit,
+		},
+		{
+			name:      "gemini bad key",
+			agentType: models.AgentTypeGemini,
+			screen:    "✕ [API Error: API key not valid. Please pass a valid API key.]\n",
+			category:  models.FailureCategoryAuth,
+		},
+		{
+			name:      "gemini token limit",
+			agentType: models.AgentTypeGemini,
+			screen:    "✕ [API Error: The input token count (1150342) exceeds the maximum number of tokens allowed (1048576).]\n",
+			category:  models.FailureCategoryContextLength,
+		},
+		{
+			name:      "opencode provider auth",
+			agentType: models.AgentTypeOpenCode,
+			screen:    "Error: ProviderAuthError: Provider anthropic requires an API key\n",
+			category:  models.FailureCategoryAuth,
+		},
+		{
+			name:      "opencode retries exhausted",
+			agentType: models.AgentTypeOpenCode,
+			screen:    "AI_RetryError: Failed after 3 attempts. Last error: Rate limit exceeded\n",
+			category:  models.FailureCategoryRateLimit,
+		},
+		{
+			name:      "generic connection reset",
+			agentType: models.AgentTypeGeneric,
+			screen:    "Error: read ECONNRESET\n    at TCP.onStreamRead (node:internal/stream_base_commons:217:20)\n",
+			category:  models.FailureCategoryNetwork,
+		},
+		{
+			name:      "task traceback",
+			agentType: models.AgentTypeClaudeCode,
+			screen:    "Traceback (most recent call last):\n  File \"manage.py\", line 22, in <module>\nModuleNotFoundError: No module named 'django'\n",
+			category:  models.FailureCategoryTask,
+			action:    models.FailureActionInspect,
+		},
+		{
+			name:      "task build failure",
+			agentType: models.AgentTypeCodex,
+			screen:    "error[E0425]: cannot find value `cfg` in this scope\n --> src/main.rs:12:5\n",
+			category:  models.FailureCategoryTask,
+		},
+		{
+			name:      "latest line wins",
+			agentType: models.AgentTypeClaudeCode,
+			screen:    "Error: read ECONNRESET\nretrying...\n⎿  Invalid API key · Please run /login\n",
+			category:  models.FailureCategoryAuth,
+		},
+		{
+			name:      "unmatched",
+			agentType: models.AgentTypeGeneric,
+			screen:    "something went sideways\n\n",
+			category:  models.FailureCategoryUnknown,
+			action:    models.FailureActionInspect,
+		},
+	}
+
+	classifier := NewFailureClassifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifier.Classify(tt.agentType, tt.screen)
+			if got.Category != tt.category {
+				t.Fatalf("category = %s (%s), want %s", got.Category, got.Summary, tt.category)
+			}
+			if tt.action != "" && got.Action != tt.action {
+				t.Fatalf("action = %s, want %s", got.Action, tt.action)
+			}
+			if got.Excerpt == "" || got.ClassifiedAt.IsZero() {
+				t.Fatalf("expected an excerpt and timestamp, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestFailureClassifierConfiguredRulesFirst(t *testing.T) {
+	rule, err := NewFailureRule(models.AgentTypeCodex, `sandbox denied`, models.FailureCategoryTask, "", models.FailureActionWait, "sandbox blocked a command")
+	if err != nil {
+		t.Fatalf("NewFailureRule: %v", err)
+	}
+	classifier := NewFailureClassifier(rule)
+
+	got := classifier.Classify(models.AgentTypeCodex, "429 Too Many Requests: sandbox denied\n")
+	if got.Category != models.FailureCategoryTask || got.Action != models.FailureActionWait || got.Severity != models.FailureSeverityError {
+		t.Fatalf("expected the configured rule to win, got %+v", got)
+	}
+	if got := classifier.Classify(models.AgentTypeGemini, "sandbox denied\n"); got.Category != models.FailureCategoryUnknown {
+		t.Fatalf("expected the codex rule not to apply to gemini, got %+v", got)
+	}
+
+	if _, err := NewFailureRule("", `(`, models.FailureCategoryTask, "", "", ""); err == nil {
+		t.Fatal("expected an invalid pattern to be rejected")
+	}
+	if _, err := NewFailureRule("", `x`, "disk", "", "", ""); err == nil {
+		t.Fatal("expected an unknown category to be rejected")
+	}
+}
+
+func TestFailureClassifierRedactsExcerpt(t *testing.T) {
+	classifier := NewFailureClassifier()
+	got := classifier.Classify(models.AgentTypeCodex, "401 Unauthorized: Incorrect API key provided: sk-proj-abcdefghijklmnopqrstuvwxyz0123456789\n")
+	if strings.Contains(got.Excerpt, "abcdefghijklmnopqrstuvwxyz") {
+		t.Fatalf("expected the key redacted, got %q", got.Excerpt)
+	}
+
+	long := "fatal: " + strings.Repeat("x", 1000) + " ECONNRESET"
+	if got := classifier.Classify(models.AgentTypeGeneric, long); len(got.Excerpt) > maxFailureExcerpt {
+		t.Fatalf("expected the excerpt capped at %d bytes, got %d", maxFailureExcerpt, len(got.Excerpt))
+	}
+}
+
+func TestEngine_DetectAndUpdateClassifiesFailure(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	workspace := &models.Workspace{NodeID: node.ID, RepoPath: "/tmp/repo", TmuxSession: "session"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, workspace); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agentRepo := db.NewAgentRepository(database)
+	agent := &models.Agent{WorkspaceID: workspace.ID, Type: models.AgentTypeGeneric, TmuxPane: "session:0.0", State: models.AgentStateWorking}
+	if err := agentRepo.Create(ctx, agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	srv := tmuxtest.NewServer()
+	if _, err := srv.Run("new-session", "-d", "-s", "session", "-c", "/tmp/repo"); err != nil {
+		t.Fatalf("failed to create tmux session: %v", err)
+	}
+	if err := srv.Print("session:0.0", "Error: connect ECONNREFUSED 127.0.0.1:8080"); err != nil {
+		t.Fatalf("failed to print to pane: %v", err)
+	}
+
+	registry := adapters.NewRegistry()
+	if err := registry.Register(adapters.GenericFallbackAdapter()); err != nil {
+		t.Fatalf("failed to register adapter: %v", err)
+	}
+	eventRepo := db.NewEventRepository(database)
+	engine := NewEngine(agentRepo, eventRepo, tmux.NewClient(srv), registry)
+
+	changes := make(chan StateChange, 1)
+	if err := engine.SubscribeFunc("test", func(change StateChange) { changes <- change }); err != nil {
+		t.Fatalf("SubscribeFunc: %v", err)
+	}
+
+	result, err := engine.DetectAndUpdate(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("DetectAndUpdate failed: %v", err)
+	}
+	if result.State != models.AgentStateError || result.Failure == nil {
+		t.Fatalf("expected a classified error, got %s (%s)", result.State, result.Reason)
+	}
+	if result.Failure.Category != models.FailureCategoryNetwork || !strings.HasPrefix(result.Reason, "network failure: ") {
+		t.Fatalf("unexpected failure %+v (reason %q)", result.Failure, result.Reason)
+	}
+
+	stored, err := agentRepo.Get(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("failed to get agent: %v", err)
+	}
+	if stored.Metadata.Failure == nil || stored.Metadata.Failure.Category != models.FailureCategoryNetwork {
+		t.Fatalf("expected the failure stored on the agent, got %+v", stored.Metadata.Failure)
+	}
+	if change := <-changes; change.Failure == nil || change.Failure.Category != models.FailureCategoryNetwork {
+		t.Fatalf("expected the failure on the state change, got %+v", change.Failure)
+	}
+
+	events, err := eventRepo.ListByEntity(ctx, models.EntityTypeAgent, agent.ID, 10)
+	if err != nil {
+		t.Fatalf("failed to list events: %v", err)
+	}
+	var payload models.StateChangedPayload
+	for _, event := range events {
+		if event.Type == models.EventTypeAgentStateChanged {
+			if err := json.Unmarshal(event.Payload, &payload); err != nil {
+				t.Fatalf("unmarshal payload: %v", err)
+			}
+		}
+	}
+	if payload.Failure == nil || payload.Failure.Excerpt == "" {
+		t.Fatalf("expected the failure in the state change event, got %+v", payload)
+	}
+
+	if err := engine.UpdateState(ctx, agent.ID, models.AgentStateIdle, models.StateInfo{State: models.AgentStateIdle}, nil, nil); err != nil {
+		t.Fatalf("UpdateState: %v", err)
+	}
+	stored, err = agentRepo.Get(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("failed to get agent: %v", err)
+	}
+	if stored.Metadata.Failure != nil {
+		t.Fatalf("expected leaving the error state to clear the failure, got %+v", stored.Metadata.Failure)
+	}
+}
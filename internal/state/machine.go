@@ -53,6 +53,7 @@ var validTransitions = map[models.AgentState]map[models.AgentState]bool{
 		models.AgentStateError:            true, // Error detected
 		models.AgentStateStopped:          true, // Terminated
 		models.AgentStateRateLimited:      true, // Rate limit hit
+		models.AgentStateHibernated:       true, // Idle past the workspace threshold
 	},
 	models.AgentStateWorking: {
 		models.AgentStateIdle:             true, // Work completed
@@ -61,6 +62,16 @@ var validTransitions = map[models.AgentState]map[models.AgentState]bool{
 		models.AgentStateError:            true, // Error during work
 		models.AgentStateStopped:          true, // Terminated
 		models.AgentStateRateLimited:      true, // Rate limit hit
+		models.AgentStateStuck:            true, // No output past the stuck timeout
+	},
+	models.AgentStateStuck: {
+		models.AgentStateWorking:          true, // Output resumed
+		models.AgentStateIdle:             true, // Recovered to the prompt
+		models.AgentStateAwaitingApproval: true, // Hidden permission prompt surfaced
+		models.AgentStatePaused:           true, // User paused
+		models.AgentStateError:            true, // Error detected
+		models.AgentStateStopped:          true, // Terminated
+		models.AgentStateStarting:         true, // Restarted by recovery
 	},
 	models.AgentStateAwaitingApproval: {
 		models.AgentStateWorking: true, // Approved, continuing
@@ -88,6 +99,11 @@ var validTransitions = map[models.AgentState]map[models.AgentState]bool{
 		models.AgentStateStarting: true, // Restarting
 		models.AgentStateStopped:  true, // Terminated after error
 	},
+	models.AgentStateHibernated: {
+		models.AgentStateStarting: true, // Woken
+		models.AgentStateError:    true, // Failed to wake
+		models.AgentStateStopped:  true, // Terminated while hibernated
+	},
 	models.AgentStateStopped: {
 		models.AgentStateStarting: true, // Restarting
 	},
@@ -330,6 +346,15 @@ func GetStateInfo(state models.AgentState) StateInfo {
 			IsActive:    true,
 			IsTerminal:  false,
 		}
+	case models.AgentStateHibernated:
+		return StateInfo{
+			State:       state,
+			DisplayName: "Hibernated",
+			Description: "Agent is checkpointed without a pane until work arrives",
+			IsBlocking:  true,
+			IsActive:    false,
+			IsTerminal:  false,
+		}
 	case models.AgentStateError:
 		return StateInfo{
 			State:       state,
@@ -213,6 +213,11 @@ func (p *Poller) pollTick() {
 
 // shouldPoll determines if an agent should be polled based on priority.
 func (p *Poller) shouldPoll(agent *models.Agent, now time.Time) bool {
+	// Hibernated agents have no pane to capture.
+	if agent.State == models.AgentStateHibernated {
+		return false
+	}
+
 	p.mu.RLock()
 	state, exists := p.pollStates[agent.ID]
 	p.mu.RUnlock()
@@ -0,0 +1,241 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// DefaultStuckTimeout is how long a working agent may go without output
+// before it is flagged stuck.
+const DefaultStuckTimeout = 10 * time.Minute
+
+// RecoveryAction is one rung of the stuck-agent escalation ladder.
+type RecoveryAction string
+
+const (
+	RecoveryEnter     RecoveryAction = "enter"
+	RecoveryInterrupt RecoveryAction = "interrupt"
+	RecoveryRestart   RecoveryAction = "restart"
+)
+
+// ParseRecoveryAction validates a recovery action name.
+func ParseRecoveryAction(value string) (RecoveryAction, error) {
+	switch action := RecoveryAction(value); action {
+	case RecoveryEnter, RecoveryInterrupt, RecoveryRestart:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown recovery action %q (use enter, interrupt, or restart)", value)
+	}
+}
+
+// EscalationStep runs Action once After has elapsed since the agent was
+// flagged stuck or, for later steps, since the previous step ran.
+type EscalationStep struct {
+	Action RecoveryAction
+	After  time.Duration
+}
+
+// Recoverer performs recovery actions against a stuck agent.
+type Recoverer interface {
+	Recover(ctx context.Context, agent *models.Agent, action RecoveryAction) error
+}
+
+// RecovererFunc is a function adapter for Recoverer.
+type RecovererFunc func(ctx context.Context, agent *models.Agent, action RecoveryAction) error
+
+// Recover implements Recoverer.
+func (f RecovererFunc) Recover(ctx context.Context, agent *models.Agent, action RecoveryAction) error {
+	return f(ctx, agent, action)
+}
+
+// StuckConfig configures stuck detection.
+type StuckConfig struct {
+	// Timeout is how long a working agent may produce no output.
+	// Default: 10m
+	Timeout time.Duration
+
+	// TypeTimeouts overrides Timeout for specific agent types.
+	TypeTimeouts map[models.AgentType]time.Duration
+
+	// Escalation lists recovery steps to run while the agent stays stuck.
+	// Empty means agents are only flagged.
+	Escalation []EscalationStep
+}
+
+// StuckOption configures a StuckDetector.
+type StuckOption func(*StuckDetector)
+
+// WithStuckClock sets the clock used to measure silence and step delays.
+func WithStuckClock(c clock.Clock) StuckOption {
+	return func(d *StuckDetector) {
+		d.clock = c
+	}
+}
+
+// WithRecoverer sets the recoverer that runs escalation steps. Without
+// one, escalation steps are skipped.
+func WithRecoverer(r Recoverer) StuckOption {
+	return func(d *StuckDetector) {
+		d.recoverer = r
+	}
+}
+
+// RecoveryAttempt records an escalation step run during an observation.
+type RecoveryAttempt struct {
+	Action RecoveryAction
+	Step   int
+	Err    error
+}
+
+// StuckVerdict is the outcome of observing an agent.
+type StuckVerdict struct {
+	// Stuck reports whether the agent is stuck.
+	Stuck bool
+
+	// Started reports whether this observation flagged the agent.
+	Started bool
+
+	// LastOutputAt is when the agent's output last changed.
+	LastOutputAt time.Time
+
+	// SilentFor is how long the agent has been working without output.
+	SilentFor time.Duration
+
+	// Reason explains the verdict when Stuck is true.
+	Reason string
+
+	// Recovery is set when an escalation step ran.
+	Recovery *RecoveryAttempt
+}
+
+// stuckTracker is the per-agent detection state.
+type stuckTracker struct {
+	screenHash   string
+	lastOutputAt time.Time
+	workingSince time.Time
+	stuckSince   time.Time
+	lastStepAt   time.Time
+	nextStep     int
+}
+
+// StuckDetector flags working agents whose output stops changing and
+// walks the escalation ladder while they stay stuck.
+type StuckDetector struct {
+	config    StuckConfig
+	clock     clock.Clock
+	recoverer Recoverer
+	logger    zerolog.Logger
+
+	mu     sync.Mutex
+	agents map[string]*stuckTracker
+}
+
+// NewStuckDetector creates a StuckDetector.
+func NewStuckDetector(config StuckConfig, opts ...StuckOption) *StuckDetector {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultStuckTimeout
+	}
+	d := &StuckDetector{
+		config: config,
+		logger: logging.Component("stuck-detector"),
+		agents: make(map[string]*stuckTracker),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.clock = clock.OrReal(d.clock)
+	return d
+}
+
+// TimeoutFor returns the silence threshold for an agent type.
+func (d *StuckDetector) TimeoutFor(agentType models.AgentType) time.Duration {
+	if timeout, ok := d.config.TypeTimeouts[agentType]; ok && timeout > 0 {
+		return timeout
+	}
+	return d.config.Timeout
+}
+
+// Observe records a detection for agent. detected is the state the adapter
+// reported and screenHash identifies the pane content; a changed hash
+// counts as output. An agent is stuck once it has been detected working
+// for longer than its timeout with no output. Output clears the flag, but
+// the ladder only rewinds once the agent leaves the working state, so an
+// agent that keeps hanging escalates instead of getting Enter forever.
+func (d *StuckDetector) Observe(ctx context.Context, agent *models.Agent, detected models.AgentState, screenHash string) StuckVerdict {
+	now := d.clock.Now().UTC()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tracker, ok := d.agents[agent.ID]
+	if !ok {
+		// Without a prior hash there is nothing to compare against, so the
+		// first observation counts as output.
+		tracker = &stuckTracker{screenHash: screenHash, lastOutputAt: now}
+		d.agents[agent.ID] = tracker
+	} else if screenHash != tracker.screenHash {
+		tracker.screenHash = screenHash
+		tracker.lastOutputAt = now
+		tracker.stuckSince = time.Time{}
+	}
+
+	verdict := StuckVerdict{LastOutputAt: tracker.lastOutputAt}
+
+	if detected != models.AgentStateWorking {
+		tracker.workingSince = time.Time{}
+		tracker.stuckSince = time.Time{}
+		tracker.nextStep = 0
+		return verdict
+	}
+	if tracker.workingSince.IsZero() {
+		tracker.workingSince = now
+	}
+
+	quietSince := tracker.lastOutputAt
+	if tracker.workingSince.After(quietSince) {
+		quietSince = tracker.workingSince
+	}
+	verdict.SilentFor = now.Sub(quietSince)
+	if verdict.SilentFor < d.TimeoutFor(agent.Type) {
+		return verdict
+	}
+
+	verdict.Stuck = true
+	verdict.Reason = fmt.Sprintf("no output for %s while working", verdict.SilentFor.Round(time.Second))
+	if tracker.stuckSince.IsZero() {
+		tracker.stuckSince = now
+		tracker.lastStepAt = now
+		verdict.Started = true
+		d.logger.Warn().Str("agent_id", agent.ID).Dur("silent_for", verdict.SilentFor).Msg("agent stuck")
+	}
+
+	if tracker.nextStep < len(d.config.Escalation) && d.recoverer != nil {
+		step := d.config.Escalation[tracker.nextStep]
+		if now.Sub(tracker.lastStepAt) >= step.After {
+			attempt := &RecoveryAttempt{Action: step.Action, Step: tracker.nextStep + 1}
+			attempt.Err = d.recoverer.Recover(ctx, agent, step.Action)
+			if attempt.Err != nil {
+				d.logger.Warn().Err(attempt.Err).Str("agent_id", agent.ID).Str("action", string(step.Action)).Msg("stuck recovery failed")
+			}
+			tracker.lastStepAt = now
+			tracker.nextStep++
+			verdict.Recovery = attempt
+		}
+	}
+
+	return verdict
+}
+
+// Forget drops the tracking state for an agent.
+func (d *StuckDetector) Forget(agentID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.agents, agentID)
+}
@@ -0,0 +1,285 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+type recoveryCall struct {
+	action RecoveryAction
+	at     time.Time
+}
+
+func newStuckTestDetector(t *testing.T, config StuckConfig) (*StuckDetector, *clock.Fake, *[]recoveryCall) {
+	t.Helper()
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	calls := &[]recoveryCall{}
+	recoverer := RecovererFunc(func(ctx context.Context, agent *models.Agent, action RecoveryAction) error {
+		*calls = append(*calls, recoveryCall{action: action, at: fake.Now()})
+		return nil
+	})
+	return NewStuckDetector(config, WithStuckClock(fake), WithRecoverer(recoverer)), fake, calls
+}
+
+func TestStuckDetector_NoFalsePositiveWhileOutputFlows(t *testing.T) {
+	detector, fake, calls := newStuckTestDetector(t, StuckConfig{
+		Timeout:    10 * time.Minute,
+		Escalation: []EscalationStep{{Action: RecoveryEnter}},
+	})
+	agent := &models.Agent{ID: "agent-1", Type: models.AgentTypeClaudeCode}
+
+	for i := 0; i < 60; i++ {
+		verdict := detector.Observe(context.Background(), agent, models.AgentStateWorking, fmt.Sprintf("hash-%d", i))
+		if verdict.Stuck {
+			t.Fatalf("flagged stuck at minute %d while output was changing", i)
+		}
+		if !verdict.LastOutputAt.Equal(fake.Now()) {
+			t.Fatalf("expected last output at %v, got %v", fake.Now(), verdict.LastOutputAt)
+		}
+		fake.Advance(time.Minute)
+	}
+
+	// A long quiet spell is fine while the agent is not working.
+	for i := 0; i < 30; i++ {
+		if verdict := detector.Observe(context.Background(), agent, models.AgentStateIdle, "prompt"); verdict.Stuck {
+			t.Fatalf("flagged idle agent stuck at minute %d", i)
+		}
+		fake.Advance(time.Minute)
+	}
+
+	// Work starting on an unchanged screen gets a full timeout.
+	detector.Observe(context.Background(), agent, models.AgentStateWorking, "prompt")
+	fake.Advance(9 * time.Minute)
+	if verdict := detector.Observe(context.Background(), agent, models.AgentStateWorking, "prompt"); verdict.Stuck {
+		t.Fatalf("flagged stuck before the timeout elapsed since work started")
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("expected no recovery, got %+v", *calls)
+	}
+}
+
+func TestStuckDetector_FlagsSilentWorkingAgent(t *testing.T) {
+	detector, fake, _ := newStuckTestDetector(t, StuckConfig{
+		Timeout:      10 * time.Minute,
+		TypeTimeouts: map[models.AgentType]time.Duration{models.AgentTypeCodex: 20 * time.Minute},
+	})
+	claude := &models.Agent{ID: "agent-1", Type: models.AgentTypeClaudeCode}
+	codex := &models.Agent{ID: "agent-2", Type: models.AgentTypeCodex}
+	start := fake.Now()
+
+	detector.Observe(context.Background(), claude, models.AgentStateWorking, "same")
+	detector.Observe(context.Background(), codex, models.AgentStateWorking, "same")
+	fake.Advance(10 * time.Minute)
+
+	verdict := detector.Observe(context.Background(), claude, models.AgentStateWorking, "same")
+	if !verdict.Stuck || !verdict.Started {
+		t.Fatalf("expected agent flagged stuck, got %+v", verdict)
+	}
+	if !verdict.LastOutputAt.Equal(start) || verdict.SilentFor != 10*time.Minute {
+		t.Fatalf("unexpected silence tracking: %+v", verdict)
+	}
+	if verdict.Reason != "no output for 10m0s while working" {
+		t.Fatalf("unexpected reason %q", verdict.Reason)
+	}
+	if verdict := detector.Observe(context.Background(), codex, models.AgentStateWorking, "same"); verdict.Stuck {
+		t.Fatalf("expected codex override to allow 20m, got %+v", verdict)
+	}
+
+	fake.Advance(time.Minute)
+	if verdict := detector.Observe(context.Background(), claude, models.AgentStateWorking, "same"); !verdict.Stuck || verdict.Started {
+		t.Fatalf("expected agent to stay stuck without a new flag, got %+v", verdict)
+	}
+
+	if verdict := detector.Observe(context.Background(), claude, models.AgentStateWorking, "changed"); verdict.Stuck {
+		t.Fatalf("expected output to clear the stuck flag, got %+v", verdict)
+	}
+}
+
+func TestStuckDetector_EscalationLadder(t *testing.T) {
+	detector, fake, calls := newStuckTestDetector(t, StuckConfig{
+		Timeout: 10 * time.Minute,
+		Escalation: []EscalationStep{
+			{Action: RecoveryEnter},
+			{Action: RecoveryInterrupt, After: 2 * time.Minute},
+			{Action: RecoveryRestart, After: 5 * time.Minute},
+		},
+	})
+	agent := &models.Agent{ID: "agent-1", Type: models.AgentTypeClaudeCode}
+	start := fake.Now()
+
+	for elapsed := time.Duration(0); elapsed <= 30*time.Minute; elapsed += 30 * time.Second {
+		detector.Observe(context.Background(), agent, models.AgentStateWorking, "hung")
+		fake.Advance(30 * time.Second)
+	}
+
+	want := []recoveryCall{
+		{action: RecoveryEnter, at: start.Add(10 * time.Minute)},
+		{action: RecoveryInterrupt, at: start.Add(12 * time.Minute)},
+		{action: RecoveryRestart, at: start.Add(17 * time.Minute)},
+	}
+	if len(*calls) != len(want) {
+		t.Fatalf("expected %d recovery steps, got %+v", len(want), *calls)
+	}
+	for i, call := range want {
+		got := (*calls)[i]
+		if got.action != call.action || !got.at.Equal(call.at) {
+			t.Errorf("step %d: expected %s at %v, got %s at %v", i+1, call.action, call.at, got.action, got.at)
+		}
+	}
+}
+
+func TestStuckDetector_LadderResumesAcrossRepeatedHangs(t *testing.T) {
+	detector, fake, calls := newStuckTestDetector(t, StuckConfig{
+		Timeout: 10 * time.Minute,
+		Escalation: []EscalationStep{
+			{Action: RecoveryEnter},
+			{Action: RecoveryInterrupt},
+		},
+	})
+	agent := &models.Agent{ID: "agent-1", Type: models.AgentTypeClaudeCode}
+
+	detector.Observe(context.Background(), agent, models.AgentStateWorking, "a")
+	fake.Advance(10 * time.Minute)
+	verdict := detector.Observe(context.Background(), agent, models.AgentStateWorking, "a")
+	if verdict.Recovery == nil || verdict.Recovery.Action != RecoveryEnter || verdict.Recovery.Step != 1 {
+		t.Fatalf("expected enter as step 1, got %+v", verdict.Recovery)
+	}
+
+	// Enter produced a little output, then the agent hung again.
+	detector.Observe(context.Background(), agent, models.AgentStateWorking, "b")
+	fake.Advance(10 * time.Minute)
+	verdict = detector.Observe(context.Background(), agent, models.AgentStateWorking, "b")
+	if !verdict.Started || verdict.Recovery == nil || verdict.Recovery.Action != RecoveryInterrupt {
+		t.Fatalf("expected second hang to escalate to interrupt, got %+v", verdict)
+	}
+
+	// Finishing the work rewinds the ladder.
+	detector.Observe(context.Background(), agent, models.AgentStateIdle, "c")
+	detector.Observe(context.Background(), agent, models.AgentStateWorking, "c")
+	fake.Advance(10 * time.Minute)
+	verdict = detector.Observe(context.Background(), agent, models.AgentStateWorking, "c")
+	if verdict.Recovery == nil || verdict.Recovery.Action != RecoveryEnter {
+		t.Fatalf("expected ladder to restart at enter, got %+v", verdict.Recovery)
+	}
+	if len(*calls) != 3 {
+		t.Fatalf("expected 3 recovery calls, got %+v", *calls)
+	}
+}
+
+func TestParseRecoveryAction(t *testing.T) {
+	for _, value := range []string{"enter", "interrupt", "restart"} {
+		if _, err := ParseRecoveryAction(value); err != nil {
+			t.Errorf("ParseRecoveryAction(%q) failed: %v", value, err)
+		}
+	}
+	if _, err := ParseRecoveryAction("reboot"); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
+
+func TestEngine_DetectAndUpdateFlagsStuckAgent(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	workspace := &models.Workspace{NodeID: node.ID, RepoPath: "/tmp/repo", TmuxSession: "session"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, workspace); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agentRepo := db.NewAgentRepository(database)
+	agent := &models.Agent{WorkspaceID: workspace.ID, Type: models.AgentTypeGeneric, TmuxPane: "session:0.0", State: models.AgentStateWorking}
+	if err := agentRepo.Create(ctx, agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	srv := tmuxtest.NewServer()
+	if _, err := srv.Run("new-session", "-d", "-s", "session", "-c", "/tmp/repo"); err != nil {
+		t.Fatalf("failed to create tmux session: %v", err)
+	}
+	if err := srv.Print("session:0.0", "thinking about the refactor"); err != nil {
+		t.Fatalf("failed to print to pane: %v", err)
+	}
+
+	registry := adapters.NewRegistry()
+	if err := registry.Register(adapters.GenericFallbackAdapter()); err != nil {
+		t.Fatalf("failed to register adapter: %v", err)
+	}
+	detector, fake, calls := newStuckTestDetector(t, StuckConfig{
+		Timeout:    10 * time.Minute,
+		Escalation: []EscalationStep{{Action: RecoveryEnter}},
+	})
+	eventRepo := db.NewEventRepository(database)
+	engine := NewEngine(agentRepo, eventRepo, tmux.NewClient(srv), registry, WithStuckDetection(detector))
+
+	result, err := engine.DetectAndUpdate(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("DetectAndUpdate failed: %v", err)
+	}
+	if result.State != models.AgentStateWorking {
+		t.Fatalf("expected working, got %s (%s)", result.State, result.Reason)
+	}
+
+	fake.Advance(10 * time.Minute)
+	result, err = engine.DetectAndUpdate(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("DetectAndUpdate failed: %v", err)
+	}
+	if result.State != models.AgentStateStuck {
+		t.Fatalf("expected stuck, got %s (%s)", result.State, result.Reason)
+	}
+
+	stored, err := agentRepo.Get(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("failed to get agent: %v", err)
+	}
+	if stored.State != models.AgentStateStuck || stored.LastOutputAt == nil {
+		t.Fatalf("expected stored stuck agent with last output, got %s / %v", stored.State, stored.LastOutputAt)
+	}
+	if len(*calls) != 1 || (*calls)[0].action != RecoveryEnter {
+		t.Fatalf("expected enter recovery, got %+v", *calls)
+	}
+
+	events, err := eventRepo.ListByEntity(ctx, models.EntityTypeAgent, agent.ID, 10)
+	if err != nil {
+		t.Fatalf("failed to list events: %v", err)
+	}
+	seen := make(map[models.EventType]bool)
+	for _, event := range events {
+		seen[event.Type] = true
+	}
+	for _, eventType := range []models.EventType{models.EventTypeAgentStuck, models.EventTypeAgentRecovery, models.EventTypeAgentStateChanged} {
+		if !seen[eventType] {
+			t.Errorf("expected %s event, got %+v", eventType, events)
+		}
+	}
+
+	if err := srv.Print("session:0.0", "thinking again"); err != nil {
+		t.Fatalf("failed to print to pane: %v", err)
+	}
+	result, err = engine.DetectAndUpdate(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("DetectAndUpdate failed: %v", err)
+	}
+	if result.State != models.AgentStateWorking {
+		t.Fatalf("expected output to return the agent to working, got %s", result.State)
+	}
+}
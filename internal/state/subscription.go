@@ -0,0 +1,205 @@
+package state
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// DefaultSubscriberBuffer is how many undelivered state changes a
+// subscriber may queue before the oldest are dropped.
+const DefaultSubscriberBuffer = 64
+
+// SubscriberStats reports delivery health for one subscriber.
+type SubscriberStats struct {
+	ID string
+
+	// Pending is the number of changes queued but not yet delivered.
+	Pending int
+
+	// Dropped counts changes discarded because the buffer was full.
+	Dropped int64
+
+	// Panics counts callbacks that panicked.
+	Panics int64
+}
+
+// subscription queues state changes for one subscriber. Callback
+// subscriptions are drained by their own goroutine; channel subscriptions
+// hand the queue to the consumer directly.
+type subscription struct {
+	id         string
+	subscriber Subscriber
+	ch         chan StateChange
+	done       chan struct{}
+	exited     chan struct{}
+
+	dropped atomic.Int64
+	panics  atomic.Int64
+}
+
+// enqueue adds change without blocking, dropping the oldest queued change
+// when the buffer is full.
+func (s *subscription) enqueue(change StateChange) (dropped bool) {
+	for {
+		select {
+		case s.ch <- change:
+			return dropped
+		default:
+		}
+		select {
+		case <-s.ch:
+			s.dropped.Add(1)
+			dropped = true
+		default:
+		}
+	}
+}
+
+// Subscribe registers a subscriber for state change notifications. Changes
+// are delivered in order on a goroutine owned by the subscription, so a
+// slow or panicking subscriber does not hold up detection or other
+// subscribers. A subscriber that falls more than the buffer behind loses
+// its oldest undelivered changes.
+func (e *Engine) Subscribe(id string, subscriber Subscriber) error {
+	sub, err := e.addSubscription(id, subscriber)
+	if err != nil {
+		return err
+	}
+	go e.runSubscription(sub)
+	return nil
+}
+
+// SubscribeFunc is a convenience method to subscribe with a function.
+func (e *Engine) SubscribeFunc(id string, fn func(StateChange)) error {
+	return e.Subscribe(id, SubscriberFunc(fn))
+}
+
+// SubscribeChan registers a subscriber that receives state changes on the
+// returned channel, for consumers that prefer a select loop. The channel
+// is closed by Unsubscribe. As with Subscribe, the oldest changes are
+// dropped if the consumer falls more than the buffer behind.
+func (e *Engine) SubscribeChan(id string) (<-chan StateChange, error) {
+	sub, err := e.addSubscription(id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return sub.ch, nil
+}
+
+func (e *Engine) addSubscription(id string, subscriber Subscriber) (*subscription, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.subscribers[id]; exists {
+		return nil, ErrSubscriberExists
+	}
+
+	sub := &subscription{
+		id:         id,
+		subscriber: subscriber,
+		ch:         make(chan StateChange, e.subscriberBuf),
+	}
+	if subscriber != nil {
+		sub.done = make(chan struct{})
+		sub.exited = make(chan struct{})
+	}
+	e.subscribers[id] = sub
+	e.logger.Debug().Str("subscriber_id", id).Msg("subscriber registered")
+	return sub, nil
+}
+
+// Unsubscribe removes a subscriber and discards its undelivered changes.
+// For callback subscribers it waits for an in-flight callback to return and
+// the delivery goroutine to exit, so it must not be called from the
+// subscriber's own callback or while holding a lock that callback takes.
+func (e *Engine) Unsubscribe(id string) error {
+	e.mu.Lock()
+	sub, exists := e.subscribers[id]
+	if !exists {
+		e.mu.Unlock()
+		return ErrSubscriberMissing
+	}
+	delete(e.subscribers, id)
+	// Senders hold the read lock, so nothing is mid-send on sub.ch here.
+	if sub.subscriber == nil {
+		close(sub.ch)
+	} else {
+		close(sub.done)
+	}
+	e.mu.Unlock()
+
+	if sub.exited != nil {
+		<-sub.exited
+	}
+	e.logger.Debug().Str("subscriber_id", id).Msg("subscriber unregistered")
+	return nil
+}
+
+// SubscriberStats returns delivery statistics for every subscriber, sorted
+// by ID.
+func (e *Engine) SubscriberStats() []SubscriberStats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	stats := make([]SubscriberStats, 0, len(e.subscribers))
+	for _, sub := range e.subscribers {
+		stats = append(stats, SubscriberStats{
+			ID:      sub.id,
+			Pending: len(sub.ch),
+			Dropped: sub.dropped.Load(),
+			Panics:  sub.panics.Load(),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ID < stats[j].ID })
+	return stats
+}
+
+// notifySubscribers queues a state change for all subscribers.
+func (e *Engine) notifySubscribers(change StateChange) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	e.logger.Debug().
+		Str("agent_id", change.AgentID).
+		Str("from", string(change.PreviousState)).
+		Str("to", string(change.CurrentState)).
+		Int("subscribers", len(e.subscribers)).
+		Msg("notifying state change")
+
+	for _, sub := range e.subscribers {
+		if sub.enqueue(change) && sub.dropped.Load() == 1 {
+			e.logger.Warn().
+				Str("subscriber_id", sub.id).
+				Int("buffer", cap(sub.ch)).
+				Msg("subscriber is falling behind; dropping oldest state changes")
+		}
+	}
+}
+
+func (e *Engine) runSubscription(sub *subscription) {
+	defer close(sub.exited)
+	for {
+		select {
+		case <-sub.done:
+			return
+		case change := <-sub.ch:
+			e.deliver(sub, change)
+		}
+	}
+}
+
+// deliver runs one callback, recovering a panic so the subscription keeps
+// receiving later changes.
+func (e *Engine) deliver(sub *subscription, change StateChange) {
+	defer func() {
+		if r := recover(); r != nil {
+			sub.panics.Add(1)
+			e.logger.Error().
+				Str("subscriber_id", sub.id).
+				Str("agent_id", change.AgentID).
+				Interface("panic", r).
+				Msg("subscriber panicked")
+		}
+	}()
+	sub.subscriber.OnStateChange(change)
+}
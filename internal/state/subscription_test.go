@@ -0,0 +1,158 @@
+package state
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func changeFor(i int) StateChange {
+	return StateChange{AgentID: fmt.Sprintf("agent-%03d", i)}
+}
+
+func TestSubscriberPanicIsolated(t *testing.T) {
+	engine := NewEngine(nil, nil, nil, nil)
+
+	var panics int
+	require.NoError(t, engine.SubscribeFunc("panicker", func(change StateChange) {
+		panics++
+		panic("intentional panic")
+	}))
+	good, err := engine.SubscribeChan("good")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		engine.notifySubscribers(changeFor(i))
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case change := <-good:
+			assert.Equal(t, changeFor(i).AgentID, change.AgentID)
+		case <-time.After(time.Second):
+			t.Fatalf("good subscriber missed change %d", i)
+		}
+	}
+
+	// Unsubscribe waits for the delivery goroutine, so the counters are final.
+	require.Eventually(t, func() bool {
+		for _, stats := range engine.SubscriberStats() {
+			if stats.ID == "panicker" {
+				return stats.Panics == 3
+			}
+		}
+		return false
+	}, time.Second, 5*time.Millisecond, "panicker should keep receiving after a panic")
+	require.NoError(t, engine.Unsubscribe("panicker"))
+	assert.Equal(t, 3, panics)
+}
+
+func TestSlowSubscriberDropsOldest(t *testing.T) {
+	engine := NewEngine(nil, nil, nil, nil, WithSubscriberBuffer(2))
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var mu sync.Mutex
+	var got []string
+	require.NoError(t, engine.SubscribeFunc("slow", func(change StateChange) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		mu.Lock()
+		got = append(got, change.AgentID)
+		mu.Unlock()
+	}))
+	fast, err := engine.SubscribeChan("fast")
+	require.NoError(t, err)
+
+	// The first change is taken by the callback, which then blocks.
+	engine.notifySubscribers(changeFor(0))
+	<-started
+	for i := 1; i < 10; i++ {
+		engine.notifySubscribers(changeFor(i))
+	}
+
+	stats := engine.SubscriberStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, SubscriberStats{ID: "fast", Pending: 2, Dropped: 8}, stats[0])
+	assert.Equal(t, SubscriberStats{ID: "slow", Pending: 2, Dropped: 7}, stats[1])
+	assert.Equal(t, changeFor(8).AgentID, (<-fast).AgentID)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) == 3
+	}, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, []string{"agent-000", "agent-008", "agent-009"}, got)
+	mu.Unlock()
+}
+
+func TestSubscriberDeliveryOrdered(t *testing.T) {
+	engine := NewEngine(nil, nil, nil, nil, WithSubscriberBuffer(200))
+
+	const n = 100
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	for _, id := range []string{"a", "b", "c"} {
+		id := id
+		require.NoError(t, engine.SubscribeFunc(id, func(change StateChange) {
+			mu.Lock()
+			received[id] = append(received[id], change.AgentID)
+			mu.Unlock()
+		}))
+	}
+
+	want := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		engine.notifySubscribers(changeFor(i))
+		want = append(want, changeFor(i).AgentID)
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received["a"]) == n && len(received["b"]) == n && len(received["c"]) == n
+	}, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	for id, got := range received {
+		assert.Equal(t, want, got, "subscriber %s should receive changes in order", id)
+	}
+}
+
+func TestUnsubscribeTearsDown(t *testing.T) {
+	engine := NewEngine(nil, nil, nil, nil)
+
+	calls := make(chan StateChange, 10)
+	require.NoError(t, engine.SubscribeFunc("callback", func(change StateChange) {
+		calls <- change
+	}))
+	ch, err := engine.SubscribeChan("chan")
+	require.NoError(t, err)
+
+	engine.notifySubscribers(changeFor(0))
+	<-calls
+
+	require.NoError(t, engine.Unsubscribe("callback"))
+	require.NoError(t, engine.Unsubscribe("chan"))
+	engine.notifySubscribers(changeFor(1))
+
+	// The channel keeps its undelivered change and is then closed.
+	assert.Equal(t, changeFor(0).AgentID, (<-ch).AgentID)
+	_, open := <-ch
+	assert.False(t, open, "channel should be closed after Unsubscribe")
+	select {
+	case change := <-calls:
+		t.Fatalf("unexpected delivery after Unsubscribe: %+v", change)
+	case <-time.After(20 * time.Millisecond):
+	}
+	assert.Empty(t, engine.SubscriberStats())
+	assert.ErrorIs(t, engine.Unsubscribe("chan"), ErrSubscriberMissing)
+}
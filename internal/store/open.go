@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/store/postgres"
+)
+
+var (
+	_ AgentStore = (*postgres.AgentStore)(nil)
+	_ QueueStore = (*postgres.QueueStore)(nil)
+	_ EventStore = (*postgres.EventStore)(nil)
+)
+
+// Stores holds one store of each kind.
+type Stores struct {
+	Agents     AgentStore
+	Workspaces WorkspaceStore
+	Queue      QueueStore
+	Events     EventStore
+	Usage      UsageStore
+	Accounts   AccountStore
+
+	pg *postgres.DB
+}
+
+// Open returns the stores for the backend cfg selects. The SQLite database
+// always backs workspaces, usage, and accounts; with the postgres backend,
+// agents, queues, and events move to the database at cfg.PostgresDSN,
+// migrated on open.
+func Open(ctx context.Context, cfg config.DatabaseConfig, sqlite *db.DB) (*Stores, error) {
+	if sqlite == nil {
+		return nil, fmt.Errorf("sqlite database is required")
+	}
+
+	stores := &Stores{
+		Agents:     db.NewAgentRepository(sqlite),
+		Workspaces: db.NewWorkspaceRepository(sqlite),
+		Queue:      db.NewQueueRepository(sqlite),
+		Events:     db.NewEventRepository(sqlite),
+		Usage:      db.NewUsageRepository(sqlite),
+		Accounts:   db.NewAccountRepository(sqlite),
+	}
+
+	switch cfg.Backend {
+	case "", config.DatabaseBackendSQLite:
+		return stores, nil
+	case config.DatabaseBackendPostgres:
+		pg, err := postgres.Open(ctx, cfg.PostgresDSN)
+		if err != nil {
+			return nil, err
+		}
+		if err := pg.Migrate(ctx); err != nil {
+			pg.Close()
+			return nil, err
+		}
+		stores.pg = pg
+		stores.Agents = postgres.NewAgentStore(pg)
+		stores.Queue = postgres.NewQueueStore(pg)
+		stores.Events = postgres.NewEventStore(pg)
+		return stores, nil
+	default:
+		return nil, fmt.Errorf("unknown database backend %q", cfg.Backend)
+	}
+}
+
+// Close releases the Postgres connection pool, if any. The SQLite database
+// belongs to the caller.
+func (s *Stores) Close() error {
+	if s == nil || s.pg == nil {
+		return nil
+	}
+	return s.pg.Close()
+}
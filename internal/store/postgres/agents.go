@@ -0,0 +1,427 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+const agentColumns = `
+	id, workspace_id, type, tmux_pane, tmux_session, remote_agent_id, account_id,
+	state, state_confidence, state_reason, state_detected_at,
+	paused_until, last_activity_at, last_output_at, metadata_json, standby, draining,
+	created_at, updated_at, deleted_at, terminal_state, terminal_pane`
+
+// AgentStore persists agents in Postgres.
+type AgentStore struct {
+	db *DB
+}
+
+// NewAgentStore creates a new AgentStore.
+func NewAgentStore(db *DB) *AgentStore {
+	return &AgentStore{db: db}
+}
+
+// liveAgentFilter returns the condition excluding terminated agents, for
+// the agents table under alias (empty for none), or "TRUE" when opts
+// include them.
+func liveAgentFilter(alias string, opts []db.AgentQueryOption) string {
+	if db.IncludesDeleted(opts) {
+		return "TRUE"
+	}
+	if alias != "" {
+		alias += "."
+	}
+	return alias + "deleted_at IS NULL"
+}
+
+// Create adds a new agent.
+func (s *AgentStore) Create(ctx context.Context, agent *models.Agent) error {
+	if err := agent.Validate(); err != nil {
+		return fmt.Errorf("invalid agent: %w", err)
+	}
+
+	if agent.ID == "" {
+		agent.ID = uuid.New().String()
+	}
+
+	now := stamp(time.Now())
+	agent.CreatedAt = now
+	agent.UpdatedAt = now
+	normalizeAgentState(agent)
+
+	metadataJSON, err := json.Marshal(agent.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO agents (
+			id, workspace_id, type, tmux_pane, tmux_session, remote_agent_id, account_id,
+			state, state_confidence, state_reason, state_detected_at,
+			paused_until, last_activity_at, last_output_at, metadata_json, standby,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	`,
+		agent.ID,
+		agent.WorkspaceID,
+		string(agent.Type),
+		agent.TmuxPane,
+		agent.TmuxSession,
+		agent.RemoteAgentID,
+		nullString(agent.AccountID),
+		string(agent.State),
+		string(agent.StateInfo.Confidence),
+		agent.StateInfo.Reason,
+		nullStamp(&agent.StateInfo.DetectedAt),
+		nullStamp(agent.PausedUntil),
+		nullStamp(agent.LastActivity),
+		nullStamp(agent.LastOutputAt),
+		string(metadataJSON),
+		agent.Standby,
+		agent.CreatedAt,
+		agent.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.ErrAgentAlreadyExists
+		}
+		return fmt.Errorf("failed to insert agent: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an agent by ID. Terminated agents are db.ErrAgentNotFound
+// unless opts include them.
+func (s *AgentStore) Get(ctx context.Context, id string, opts ...db.AgentQueryOption) (*models.Agent, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+agentColumns+`
+		FROM agents WHERE id = $1 AND `+liveAgentFilter("", opts), id)
+
+	agent, err := scanAgent(row, nil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrAgentNotFound
+	}
+	return agent, err
+}
+
+// List retrieves all agents.
+func (s *AgentStore) List(ctx context.Context, opts ...db.AgentQueryOption) ([]*models.Agent, error) {
+	return s.query(ctx, "failed to query agents", `SELECT `+agentColumns+`
+		FROM agents WHERE `+liveAgentFilter("", opts)+`
+		ORDER BY created_at, seq`)
+}
+
+// ListByWorkspace retrieves agents for a specific workspace.
+func (s *AgentStore) ListByWorkspace(ctx context.Context, workspaceID string, opts ...db.AgentQueryOption) ([]*models.Agent, error) {
+	return s.query(ctx, "failed to query agents by workspace", `SELECT `+agentColumns+`
+		FROM agents WHERE workspace_id = $1 AND `+liveAgentFilter("", opts)+`
+		ORDER BY created_at, seq`, workspaceID)
+}
+
+// ListByState retrieves agents with a specific state.
+func (s *AgentStore) ListByState(ctx context.Context, state models.AgentState, opts ...db.AgentQueryOption) ([]*models.Agent, error) {
+	return s.query(ctx, "failed to query agents by state", `SELECT `+agentColumns+`
+		FROM agents WHERE state = $1 AND `+liveAgentFilter("", opts)+`
+		ORDER BY created_at, seq`, string(state))
+}
+
+// ListWithQueueLength retrieves all agents with their pending queue
+// lengths.
+func (s *AgentStore) ListWithQueueLength(ctx context.Context, opts ...db.AgentQueryOption) ([]*models.Agent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+agentColumns+`,
+			(SELECT COUNT(*) FROM queue_items q WHERE q.agent_id = agents.id AND q.status = 'pending')
+		FROM agents WHERE `+liveAgentFilter("agents", opts)+`
+		ORDER BY created_at, seq
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agents with queue length: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*models.Agent
+	for rows.Next() {
+		var queueLength int
+		agent, err := scanAgent(rows, &queueLength)
+		if err != nil {
+			return nil, err
+		}
+		agent.QueueLength = queueLength
+		agents = append(agents, agent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating agents: %w", err)
+	}
+	return agents, nil
+}
+
+// Update updates an existing agent.
+func (s *AgentStore) Update(ctx context.Context, agent *models.Agent) error {
+	if err := agent.Validate(); err != nil {
+		return fmt.Errorf("invalid agent: %w", err)
+	}
+
+	agent.UpdatedAt = stamp(time.Now())
+	normalizeAgentState(agent)
+
+	metadataJSON, err := json.Marshal(agent.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE agents SET
+			workspace_id = $1,
+			type = $2,
+			tmux_pane = $3,
+			tmux_session = $4,
+			remote_agent_id = $5,
+			account_id = $6,
+			state = $7,
+			state_confidence = $8,
+			state_reason = $9,
+			state_detected_at = $10,
+			paused_until = $11,
+			last_activity_at = $12,
+			last_output_at = $13,
+			metadata_json = $14,
+			updated_at = $15
+		WHERE id = $16 AND deleted_at IS NULL
+	`,
+		agent.WorkspaceID,
+		string(agent.Type),
+		agent.TmuxPane,
+		agent.TmuxSession,
+		agent.RemoteAgentID,
+		nullString(agent.AccountID),
+		string(agent.State),
+		string(agent.StateInfo.Confidence),
+		agent.StateInfo.Reason,
+		nullStamp(&agent.StateInfo.DetectedAt),
+		nullStamp(agent.PausedUntil),
+		nullStamp(agent.LastActivity),
+		nullStamp(agent.LastOutputAt),
+		string(metadataJSON),
+		agent.UpdatedAt,
+		agent.ID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.ErrAgentAlreadyExists
+		}
+		return fmt.Errorf("failed to update agent: %w", err)
+	}
+	return requireAffected(result, db.ErrAgentNotFound)
+}
+
+// Delete marks an agent terminated, as db.AgentRepository.Delete does:
+// the row is kept, the agent stopped with its last state kept as
+// TerminalState, and its pane released for reuse.
+func (s *AgentStore) Delete(ctx context.Context, id string) error {
+	now := stamp(time.Now())
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE agents SET
+			deleted_at = $1,
+			terminal_state = state,
+			terminal_pane = tmux_pane,
+			tmux_pane = id,
+			state = $2,
+			state_reason = 'terminated',
+			standby = FALSE,
+			updated_at = $1
+		WHERE id = $3 AND deleted_at IS NULL
+	`, now, string(models.AgentStateStopped), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete agent: %w", err)
+	}
+	return requireAffected(result, db.ErrAgentNotFound)
+}
+
+// HardDelete removes an agent row, terminated or not, and its queue.
+func (s *AgentStore) HardDelete(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM agents WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete agent: %w", err)
+	}
+	return requireAffected(result, db.ErrAgentNotFound)
+}
+
+// PruneDeleted hard-deletes agents terminated before cutoff, and with them
+// their queues. Workspace queue items assigned to them live in SQLite and
+// are left for its retention. It returns the number of agents removed.
+func (s *AgentStore) PruneDeleted(ctx context.Context, cutoff time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM agents WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	`, stamp(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune terminated agents: %w", err)
+	}
+	pruned, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(pruned), nil
+}
+
+// SetDraining sets whether an agent is draining its queue.
+func (s *AgentStore) SetDraining(ctx context.Context, id string, draining bool) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE agents SET draining = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`, draining, stamp(time.Now()), id)
+	if err != nil {
+		return fmt.Errorf("failed to update agent draining: %w", err)
+	}
+	return requireAffected(result, db.ErrAgentNotFound)
+}
+
+// ClaimStandby takes the oldest idle standby agent of agentType (any type
+// when empty) in a workspace that is not draining and clears its standby
+// flag, in one statement. Rows another claim holds are skipped, so
+// concurrent claims never take the same agent. db.ErrNoStandbyAgent is
+// returned when none is left.
+func (s *AgentStore) ClaimStandby(ctx context.Context, workspaceID string, agentType models.AgentType) (*models.Agent, error) {
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE agents SET standby = FALSE, updated_at = $1
+		WHERE id = (
+			SELECT id FROM agents
+			WHERE workspace_id = $2 AND standby AND NOT draining AND state = $3
+				AND ($4 = '' OR type = $4) AND deleted_at IS NULL
+			ORDER BY created_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+agentColumns,
+		stamp(time.Now()), workspaceID, string(models.AgentStateIdle), string(agentType))
+
+	agent, err := scanAgent(row, nil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrNoStandbyAgent
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim standby agent: %w", err)
+	}
+	return agent, nil
+}
+
+func (s *AgentStore) query(ctx context.Context, failure, query string, args ...any) ([]*models.Agent, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", failure, err)
+	}
+	defer rows.Close()
+
+	var agents []*models.Agent
+	for rows.Next() {
+		agent, err := scanAgent(rows, nil)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, agent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating agents: %w", err)
+	}
+	return agents, nil
+}
+
+// scanAgent scans agentColumns, followed by extra when it is not nil.
+// sql.ErrNoRows is returned as is.
+func scanAgent(row rowScanner, extra any) (*models.Agent, error) {
+	var agent models.Agent
+	var agentType, state, confidence string
+	var accountID, stateReason, metadataJSON sql.NullString
+	var stateDetectedAt, pausedUntil, lastActivity, lastOutput, deletedAt sql.NullTime
+	var terminalState, terminalPane sql.NullString
+
+	dest := []any{
+		&agent.ID,
+		&agent.WorkspaceID,
+		&agentType,
+		&agent.TmuxPane,
+		&agent.TmuxSession,
+		&agent.RemoteAgentID,
+		&accountID,
+		&state,
+		&confidence,
+		&stateReason,
+		&stateDetectedAt,
+		&pausedUntil,
+		&lastActivity,
+		&lastOutput,
+		&metadataJSON,
+		&agent.Standby,
+		&agent.Draining,
+		&agent.CreatedAt,
+		&agent.UpdatedAt,
+		&deletedAt,
+		&terminalState,
+		&terminalPane,
+	}
+	if extra != nil {
+		dest = append(dest, extra)
+	}
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan agent: %w", err)
+	}
+
+	agent.Type = models.AgentType(agentType)
+	agent.State = models.AgentState(state)
+	agent.AccountID = accountID.String
+	agent.StateInfo.State = agent.State
+	agent.StateInfo.Confidence = models.StateConfidence(confidence)
+	agent.StateInfo.Reason = stateReason.String
+	if stateDetectedAt.Valid {
+		agent.StateInfo.DetectedAt = stateDetectedAt.Time.UTC()
+	}
+	agent.PausedUntil = timePtr(pausedUntil)
+	agent.LastActivity = timePtr(lastActivity)
+	agent.LastOutputAt = timePtr(lastOutput)
+	agent.CreatedAt = agent.CreatedAt.UTC()
+	agent.UpdatedAt = agent.UpdatedAt.UTC()
+
+	if metadataJSON.Valid && metadataJSON.String != "" {
+		// Malformed metadata leaves agent.Metadata empty, as in SQLite.
+		_ = json.Unmarshal([]byte(metadataJSON.String), &agent.Metadata)
+	}
+
+	if deletedAt.Valid {
+		agent.DeletedAt = timePtr(deletedAt)
+		agent.TerminalState = models.AgentState(terminalState.String)
+		agent.TmuxPane = terminalPane.String
+	}
+	return &agent, nil
+}
+
+// normalizeAgentState fills in the state and confidence defaults.
+func normalizeAgentState(agent *models.Agent) {
+	if agent.State == "" {
+		agent.State = models.AgentStateStarting
+	}
+	if agent.StateInfo.Confidence == "" {
+		agent.StateInfo.Confidence = models.StateConfidenceLow
+	}
+	agent.StateInfo.State = agent.State
+}
+
+// requireAffected returns notFound when result changed no rows.
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
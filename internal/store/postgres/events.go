@@ -0,0 +1,382 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/trace"
+)
+
+const eventColumns = `id, timestamp, type, entity_type, entity_id, payload_json, metadata_json, trace_id`
+
+// EventStore persists the event log in Postgres. It has no outbox: the
+// event bridge relays from SQLite only.
+type EventStore struct {
+	db *DB
+}
+
+// NewEventStore creates a new EventStore.
+func NewEventStore(db *DB) *EventStore {
+	return &EventStore{db: db}
+}
+
+// Append adds a new event to the event log.
+// Returns db.ErrInvalidEvent if required fields are missing.
+func (s *EventStore) Append(ctx context.Context, event *models.Event) error {
+	if event.Type == "" || event.EntityType == "" || event.EntityID == "" {
+		return db.ErrInvalidEvent
+	}
+	return s.Create(ctx, event)
+}
+
+// Create appends a new event to the event log.
+func (s *EventStore) Create(ctx context.Context, event *models.Event) error {
+	return s.db.Transaction(ctx, func(tx *sql.Tx) error {
+		return writeEvent(ctx, tx, event)
+	})
+}
+
+// CreateBatch appends several events in a single transaction. Either all
+// events are written or none are.
+func (s *EventStore) CreateBatch(ctx context.Context, events []*models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return s.db.Transaction(ctx, func(tx *sql.Tx) error {
+		for _, event := range events {
+			if err := writeEvent(ctx, tx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func writeEvent(ctx context.Context, tx *sql.Tx, event *models.Event) error {
+	if event.Type == "" {
+		return fmt.Errorf("event type is required")
+	}
+	if event.EntityType == "" {
+		return fmt.Errorf("event entity type is required")
+	}
+	if event.EntityID == "" {
+		return fmt.Errorf("event entity id is required")
+	}
+
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.TraceID == "" {
+		event.TraceID = trace.FromContext(ctx)
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	} else {
+		event.Timestamp = event.Timestamp.UTC()
+	}
+
+	var payloadJSON any
+	if len(event.Payload) > 0 {
+		payloadJSON = string(event.Payload)
+	}
+	var metadataJSON any
+	if event.Metadata != nil {
+		data, err := json.Marshal(event.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		metadataJSON = string(data)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO events (`+eventColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		event.ID,
+		stamp(event.Timestamp),
+		string(event.Type),
+		string(event.EntityType),
+		event.EntityID,
+		payloadJSON,
+		metadataJSON,
+		nullString(event.TraceID),
+	); err != nil {
+		return fmt.Errorf("failed to insert event: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an event by ID.
+func (s *EventStore) Get(ctx context.Context, id string) (*models.Event, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+eventColumns+` FROM events WHERE id = $1`, id)
+
+	event, err := s.scanEvent(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrEventNotFound
+	}
+	return event, err
+}
+
+// Query retrieves events matching the given filters with cursor-based
+// pagination, in timestamp order.
+func (s *EventStore) Query(ctx context.Context, q db.EventQuery) (*db.EventPage, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var where []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.Type != nil {
+		where = append(where, "type = "+arg(string(*q.Type)))
+	}
+	if q.EntityType != nil {
+		where = append(where, "entity_type = "+arg(string(*q.EntityType)))
+	}
+	if q.EntityID != nil {
+		where = append(where, "entity_id = "+arg(*q.EntityID))
+	}
+	if len(q.EntityIDs) > 0 {
+		placeholders := make([]string, len(q.EntityIDs))
+		for i, id := range q.EntityIDs {
+			placeholders[i] = arg(id)
+		}
+		where = append(where, "entity_id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if q.TraceID != "" {
+		where = append(where, "trace_id = "+arg(q.TraceID))
+	}
+	if q.Since != nil {
+		where = append(where, "timestamp >= "+arg(stamp(*q.Since)))
+	}
+	if q.Until != nil {
+		where = append(where, "timestamp < "+arg(stamp(*q.Until)))
+	}
+	if q.Cursor != "" {
+		where = append(where, "(timestamp, id) > (SELECT timestamp, id FROM events WHERE id = "+arg(q.Cursor)+")")
+	}
+
+	query := `SELECT ` + eventColumns + ` FROM events`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	// Fetch one extra to tell whether there is a next page.
+	query += ` ORDER BY timestamp, id LIMIT ` + arg(limit+1)
+
+	events, err := s.query(ctx, "failed to query events", query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &db.EventPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.NextCursor = events[limit-1].ID
+	}
+	return page, nil
+}
+
+// Iterate returns an iterator over events matching q in timestamp order.
+// Rows are fetched batchSize at a time; q.Limit caps the total (0 = all).
+func (s *EventStore) Iterate(ctx context.Context, q db.EventQuery, batchSize int) *db.EventIterator {
+	return db.NewEventIterator(ctx, s.Query, q, batchSize)
+}
+
+// ListByEntity retrieves events for an entity, ordered by timestamp.
+func (s *EventStore) ListByEntity(ctx context.Context, entityType models.EntityType, entityID string, limit int) ([]*models.Event, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return s.query(ctx, "failed to query events", `SELECT `+eventColumns+`
+		FROM events WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY timestamp, seq
+		LIMIT $3
+	`, string(entityType), entityID, limit)
+}
+
+// Count returns the total number of events.
+func (s *EventStore) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM events`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count events: %w", err)
+	}
+	return count, nil
+}
+
+// OldestTimestamp returns the timestamp of the oldest event, or nil when
+// there are none.
+func (s *EventStore) OldestTimestamp(ctx context.Context) (*time.Time, error) {
+	var oldest sql.NullTime
+	if err := s.db.QueryRowContext(ctx, `SELECT MIN(timestamp) FROM events`).Scan(&oldest); err != nil {
+		return nil, fmt.Errorf("failed to get oldest timestamp: %w", err)
+	}
+	return timePtr(oldest), nil
+}
+
+// DeleteOlderThan deletes up to limit events older than before, oldest
+// first. Returns the number of events deleted.
+func (s *EventStore) DeleteOlderThan(ctx context.Context, before time.Time, limit int) (int64, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM events WHERE id IN (
+			SELECT id FROM events WHERE timestamp < $1 ORDER BY timestamp, seq LIMIT $2
+		)
+	`, stamp(before), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// DeleteExcess deletes the oldest events beyond maxCount, at most limit at
+// a time. Returns the number of events deleted.
+func (s *EventStore) DeleteExcess(ctx context.Context, maxCount int, limit int) (int64, error) {
+	if maxCount <= 0 {
+		return 0, nil
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	total, err := s.Count(ctx)
+	if err != nil {
+		return 0, err
+	}
+	excess := total - int64(maxCount)
+	if excess <= 0 {
+		return 0, nil
+	}
+	if excess > int64(limit) {
+		excess = int64(limit)
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM events WHERE id IN (
+			SELECT id FROM events ORDER BY timestamp, seq LIMIT $1
+		)
+	`, excess)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete excess events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ListOlderThan retrieves events older than before, ordered by timestamp,
+// for archiving before deletion.
+func (s *EventStore) ListOlderThan(ctx context.Context, before time.Time, limit int) ([]*models.Event, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+	return s.query(ctx, "failed to query old events", `SELECT `+eventColumns+`
+		FROM events WHERE timestamp < $1
+		ORDER BY timestamp, seq
+		LIMIT $2
+	`, stamp(before), limit)
+}
+
+// ListOldest retrieves the oldest events up to limit, for archiving excess
+// events before deletion.
+func (s *EventStore) ListOldest(ctx context.Context, limit int) ([]*models.Event, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+	return s.query(ctx, "failed to query oldest events", `SELECT `+eventColumns+`
+		FROM events
+		ORDER BY timestamp, seq
+		LIMIT $1
+	`, limit)
+}
+
+// DeleteByIDs deletes events by their IDs.
+// Returns the number of events deleted.
+func (s *EventStore) DeleteByIDs(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM events WHERE id IN (`+strings.Join(placeholders, ", ")+`)`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete events by ids: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (s *EventStore) query(ctx context.Context, failure, query string, args ...any) ([]*models.Event, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", failure, err)
+	}
+	defer rows.Close()
+
+	var events []*models.Event
+	for rows.Next() {
+		event, err := s.scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating events: %w", err)
+	}
+	return events, nil
+}
+
+// scanEvent scans eventColumns. sql.ErrNoRows is returned as is.
+func (s *EventStore) scanEvent(row rowScanner) (*models.Event, error) {
+	var event models.Event
+	var eventType, entityType string
+	var payloadJSON, metadataJSON, traceID sql.NullString
+
+	if err := row.Scan(
+		&event.ID,
+		&event.Timestamp,
+		&eventType,
+		&entityType,
+		&event.EntityID,
+		&payloadJSON,
+		&metadataJSON,
+		&traceID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan event: %w", err)
+	}
+
+	event.Timestamp = event.Timestamp.UTC()
+	event.Type = models.EventType(eventType)
+	event.EntityType = models.EntityType(entityType)
+	event.TraceID = traceID.String
+	if payloadJSON.Valid {
+		event.Payload = json.RawMessage(payloadJSON.String)
+	}
+	if metadataJSON.Valid {
+		if err := json.Unmarshal([]byte(metadataJSON.String), &event.Metadata); err != nil {
+			s.db.logger.Warn().Err(err).Str("event_id", event.ID).Msg("failed to parse event metadata")
+		}
+	}
+	return &event, nil
+}
@@ -0,0 +1,79 @@
+-- Migration: 001_hot_stores (UP)
+-- Description: Agents, queue items, and events, as of SQLite migration 033
+-- Created: 2026-10-14
+
+-- workspace_id and account_id refer to rows in the SQLite database, so they
+-- carry no foreign keys. seq orders rows created in the same second as
+-- SQLite's rowid does.
+CREATE TABLE IF NOT EXISTS agents (
+    id TEXT PRIMARY KEY,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    workspace_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    tmux_pane TEXT NOT NULL,
+    tmux_session TEXT NOT NULL DEFAULT '',
+    remote_agent_id TEXT NOT NULL DEFAULT '',
+    account_id TEXT,
+    state TEXT NOT NULL DEFAULT 'starting' CHECK (state IN ('working', 'idle', 'awaiting_approval', 'rate_limited', 'error', 'paused', 'starting', 'stopped', 'stuck', 'hibernated')),
+    state_confidence TEXT NOT NULL DEFAULT 'low' CHECK (state_confidence IN ('high', 'medium', 'low')),
+    state_reason TEXT,
+    state_detected_at TIMESTAMPTZ,
+    paused_until TIMESTAMPTZ,
+    last_activity_at TIMESTAMPTZ,
+    last_output_at TIMESTAMPTZ,
+    metadata_json TEXT,
+    standby BOOLEAN NOT NULL DEFAULT FALSE,
+    draining BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ,
+    terminal_state TEXT,
+    terminal_pane TEXT,
+    UNIQUE (workspace_id, tmux_pane)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agents_workspace_id ON agents(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_agents_state ON agents(state);
+CREATE INDEX IF NOT EXISTS idx_agents_account_id ON agents(account_id);
+CREATE INDEX IF NOT EXISTS idx_agents_standby ON agents(workspace_id, standby);
+CREATE INDEX IF NOT EXISTS idx_agents_deleted_at ON agents(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_agents_created_at ON agents(created_at, seq);
+
+CREATE TABLE IF NOT EXISTS queue_items (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('message', 'pause', 'conditional', 'command', 'review')),
+    position INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'dispatched', 'completed', 'failed', 'skipped')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    payload_json TEXT NOT NULL,
+    error_message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    dispatched_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    task_id TEXT,
+    trace_id TEXT,
+    ignore_quiet_hours BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_queue_items_position ON queue_items(agent_id, position);
+CREATE INDEX IF NOT EXISTS idx_queue_items_status ON queue_items(status);
+CREATE INDEX IF NOT EXISTS idx_queue_items_task_id ON queue_items(task_id);
+
+-- Payloads are kept as text, not JSONB, so they round-trip byte for byte.
+CREATE TABLE IF NOT EXISTS events (
+    id TEXT PRIMARY KEY,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    timestamp TIMESTAMPTZ NOT NULL DEFAULT now(),
+    type TEXT NOT NULL,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('node', 'workspace', 'agent', 'queue', 'account', 'system')),
+    entity_id TEXT NOT NULL,
+    payload_json TEXT,
+    metadata_json TEXT,
+    trace_id TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_timestamp ON events(timestamp, id);
+CREATE INDEX IF NOT EXISTS idx_events_type ON events(type);
+CREATE INDEX IF NOT EXISTS idx_events_entity_timestamp ON events(entity_type, entity_id, timestamp);
+CREATE INDEX IF NOT EXISTS idx_events_trace_id ON events(trace_id) WHERE trace_id IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Migration: 002_queue_in_flight (UP)
+-- Description: Keep dequeued items in flight until their dispatch is recorded
+-- Created: 2026-10-14
+
+-- Dequeued items stay in flight, with the dispatcher that claimed them,
+-- until their dispatch is recorded.
+ALTER TABLE queue_items DROP CONSTRAINT IF EXISTS queue_items_status_check;
+ALTER TABLE queue_items ADD CONSTRAINT queue_items_status_check
+    CHECK (status IN ('pending', 'in_flight', 'dispatched', 'completed', 'failed', 'skipped'));
+ALTER TABLE queue_items ADD COLUMN IF NOT EXISTS dispatcher_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_queue_items_in_flight ON queue_items(dispatched_at) WHERE status = 'in_flight';
@@ -0,0 +1,216 @@
+// Package postgres implements the agent, queue, and event stores on
+// Postgres, for daemons serving many users where SQLite's single writer
+// becomes the bottleneck.
+//
+// The schema mirrors the SQLite one, with native timestamp and boolean
+// columns. Workspaces and accounts stay in SQLite, so the agents table
+// carries no foreign keys to them. Timestamps are stored to the second, as
+// SQLite stores them, so both backends order and page alike. Lookups
+// return the sentinel errors of internal/db.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the pgx database/sql driver
+
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationFilePattern matches migration filenames like "001_hot_stores.up.sql".
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.up\.sql$`)
+
+// migrationLockID is the advisory lock key serializing migrations across
+// daemons sharing a database.
+const migrationLockID = 0x7377_6172_6d // "swarm"
+
+// DB wraps a Postgres connection pool.
+type DB struct {
+	*sql.DB
+	logger zerolog.Logger
+}
+
+// Open connects to the Postgres database at dsn.
+func Open(ctx context.Context, dsn string) (*DB, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("postgres dsn is required")
+	}
+
+	conn, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres: %w", err)
+	}
+	if err := conn.PingContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to ping postgres: %w", err)
+	}
+
+	return &DB{DB: conn, logger: logging.Component("postgres")}, nil
+}
+
+// Migrate applies the pending migrations, each in its own transaction.
+// Daemons starting together wait on an advisory lock rather than racing.
+func (db *DB) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", m.version, err)
+		}
+		if _, err := tx.ExecContext(ctx, m.upSQL); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO schema_migrations (version, description) VALUES ($1, $2)
+		`, m.version, m.description); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+		}
+		db.logger.Info().Int("version", m.version).Str("description", m.description).Msg("applied migration")
+	}
+	return nil
+}
+
+// Transaction executes fn within a transaction, committing if it returns
+// nil and rolling back otherwise.
+func (db *DB) Transaction(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+type migration struct {
+	version     int
+	description string
+	upSQL       string
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		data, err := fs.ReadFile(migrationsFS, "migrations/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, migration{version: version, description: match[2], upSQL: string(data)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate
+// key.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// stamp returns t in UTC to the second, as it is stored.
+func stamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// nullStamp returns t stamped, or nil for NULL when t is nil or zero.
+func nullStamp(t *time.Time) any {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return stamp(*t)
+}
+
+// timePtr returns the scanned time in UTC, or nil when it was NULL.
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+// nullString returns s, or nil for NULL when it is empty.
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
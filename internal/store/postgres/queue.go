@@ -0,0 +1,525 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/trace"
+)
+
+const queueItemColumns = `
+	id, agent_id, type, position, status, attempts, payload_json,
+	error_message, created_at, dispatched_at, completed_at, task_id, trace_id,
+	ignore_quiet_hours, dispatcher_id`
+
+// QueueStore persists agent queues in Postgres.
+type QueueStore struct {
+	db *DB
+}
+
+// NewQueueStore creates a new QueueStore.
+func NewQueueStore(db *DB) *QueueStore {
+	return &QueueStore{db: db}
+}
+
+// Enqueue adds one or more items to the end of an agent's queue, as one
+// batch.
+func (s *QueueStore) Enqueue(ctx context.Context, agentID string, items ...*models.QueueItem) error {
+	return s.EnqueueBatch(ctx, agentID, false, items...)
+}
+
+// EnqueueBatch adds items to an agent's queue in one transaction, at
+// contiguous positions in the order given, as db.QueueRepository does.
+// Writers to the same queue are serialized on the agent's row, so two
+// batches never take the same positions.
+func (s *QueueStore) EnqueueBatch(ctx context.Context, agentID string, atHead bool, items ...*models.QueueItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	for i, item := range items {
+		if err := item.Validate(); err != nil {
+			return fmt.Errorf("invalid queue item at index %d: %w", i, err)
+		}
+	}
+
+	return s.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := lockQueue(ctx, tx, agentID); err != nil {
+			return err
+		}
+		first, err := batchStartPosition(ctx, tx, agentID, atHead)
+		if err != nil {
+			return err
+		}
+		if atHead {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE queue_items SET position = position + $1
+				WHERE agent_id = $2 AND position >= $3
+			`, len(items), agentID, first); err != nil {
+				return fmt.Errorf("failed to shift items: %w", err)
+			}
+		}
+
+		now := stamp(time.Now())
+		for i, item := range items {
+			if item.ID == "" {
+				item.ID = uuid.New().String()
+			}
+			item.AgentID = agentID
+			item.CreatedAt = now
+			item.Position = first + i
+			if item.Status == "" {
+				item.Status = models.QueueItemStatusPending
+			}
+			if err := writeQueueItem(ctx, tx, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lockQueue takes the agent's row lock for the rest of tx. An unknown
+// agent is left to the foreign key to reject.
+func lockQueue(ctx context.Context, tx *sql.Tx, agentID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx, `SELECT id FROM agents WHERE id = $1 FOR UPDATE`, agentID).Scan(&id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to lock queue: %w", err)
+	}
+	return nil
+}
+
+// batchStartPosition returns the position a batch starts at: the first
+// waiting item's position for the head, or one past the last item.
+func batchStartPosition(ctx context.Context, tx *sql.Tx, agentID string, atHead bool) (int, error) {
+	if atHead {
+		var head sql.NullInt64
+		if err := tx.QueryRowContext(ctx, `
+			SELECT MIN(position) FROM queue_items WHERE agent_id = $1 AND status = $2
+		`, agentID, string(models.QueueItemStatusPending)).Scan(&head); err != nil {
+			return 0, fmt.Errorf("failed to get queue head: %w", err)
+		}
+		if head.Valid {
+			return int(head.Int64), nil
+		}
+	}
+
+	var maxPos sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT MAX(position) FROM queue_items WHERE agent_id = $1
+	`, agentID).Scan(&maxPos); err != nil {
+		return 0, fmt.Errorf("failed to get max position: %w", err)
+	}
+	return int(maxPos.Int64) + 1, nil
+}
+
+// Dequeue claims the next pending item for dispatcherID, marking it in
+// flight, and returns it, in one statement holding the item's row lock, so
+// concurrent dequeues never return the same item. A dequeue that waited on
+// an item another one took moves on to the next.
+func (s *QueueStore) Dequeue(ctx context.Context, agentID, dispatcherID string) (*models.QueueItem, error) {
+	for {
+		row := s.db.QueryRowContext(ctx, `
+			UPDATE queue_items SET status = $1, dispatched_at = $2, dispatcher_id = $3
+			WHERE id = (
+				SELECT id FROM queue_items
+				WHERE agent_id = $4 AND status = $5
+				ORDER BY position
+				LIMIT 1
+				FOR UPDATE
+			)
+			RETURNING `+queueItemColumns,
+			string(models.QueueItemStatusInFlight), stamp(time.Now()), nullString(dispatcherID), agentID, string(models.QueueItemStatusPending))
+
+		item, err := scanQueueItem(row)
+		if err == nil {
+			return item, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		// The head we waited on was taken; nothing left means empty.
+		pending, err := s.Count(ctx, agentID)
+		if err != nil {
+			return nil, err
+		}
+		if pending == 0 {
+			return nil, db.ErrQueueEmpty
+		}
+	}
+}
+
+// Peek returns the next pending item without removing it.
+func (s *QueueStore) Peek(ctx context.Context, agentID string) (*models.QueueItem, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+queueItemColumns+`
+		FROM queue_items
+		WHERE agent_id = $1 AND status = $2
+		ORDER BY position
+		LIMIT 1
+	`, agentID, string(models.QueueItemStatusPending))
+
+	item, err := scanQueueItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrQueueEmpty
+	}
+	return item, err
+}
+
+// List returns all queue items for an agent.
+func (s *QueueStore) List(ctx context.Context, agentID string) ([]*models.QueueItem, error) {
+	return s.query(ctx, "failed to query queue items", `SELECT `+queueItemColumns+`
+		FROM queue_items WHERE agent_id = $1
+		ORDER BY position
+	`, agentID)
+}
+
+// ListPending returns only pending queue items for an agent.
+func (s *QueueStore) ListPending(ctx context.Context, agentID string) ([]*models.QueueItem, error) {
+	return s.query(ctx, "failed to query pending queue items", `SELECT `+queueItemColumns+`
+		FROM queue_items WHERE agent_id = $1 AND status = $2
+		ORDER BY position
+	`, agentID, string(models.QueueItemStatusPending))
+}
+
+// ListByTask returns the queue items belonging to a task in queue order.
+func (s *QueueStore) ListByTask(ctx context.Context, taskID string) ([]*models.QueueItem, error) {
+	return s.query(ctx, "failed to query task queue items", `SELECT `+queueItemColumns+`
+		FROM queue_items WHERE task_id = $1
+		ORDER BY position
+	`, taskID)
+}
+
+// SkipPendingByTask marks a task's pending items as skipped with reason so
+// they are never dispatched. Returns the number of items skipped.
+func (s *QueueStore) SkipPendingByTask(ctx context.Context, taskID, reason string) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE queue_items SET status = $1, error_message = $2
+		WHERE task_id = $3 AND status = $4
+	`, string(models.QueueItemStatusSkipped), reason, taskID, string(models.QueueItemStatusPending))
+	if err != nil {
+		return 0, fmt.Errorf("failed to skip task queue items: %w", err)
+	}
+	return rowsAffected(result)
+}
+
+// Reorder sets the order of an agent's pending items. itemIDs must list
+// every pending item exactly once.
+func (s *QueueStore) Reorder(ctx context.Context, agentID string, itemIDs []string) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	return s.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := lockQueue(ctx, tx, agentID); err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id FROM queue_items WHERE agent_id = $1 AND status = $2
+		`, agentID, string(models.QueueItemStatusPending))
+		if err != nil {
+			return fmt.Errorf("failed to query pending queue items: %w", err)
+		}
+		pendingIDs := make(map[string]struct{})
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan queue item: %w", err)
+			}
+			pendingIDs[id] = struct{}{}
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return fmt.Errorf("error iterating queue items: %w", err)
+		}
+		rows.Close()
+
+		if len(itemIDs) != len(pendingIDs) {
+			return fmt.Errorf("reorder list must include all pending items for agent %s", agentID)
+		}
+		seen := make(map[string]struct{}, len(itemIDs))
+		for _, id := range itemIDs {
+			if id == "" {
+				return fmt.Errorf("queue item id is required")
+			}
+			if _, ok := pendingIDs[id]; !ok {
+				return fmt.Errorf("queue item %s not found in pending queue for agent %s", id, agentID)
+			}
+			if _, dup := seen[id]; dup {
+				return fmt.Errorf("duplicate queue item %s in reorder list", id)
+			}
+			seen[id] = struct{}{}
+		}
+
+		for i, id := range itemIDs {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE queue_items SET position = $1 WHERE id = $2
+			`, i+1, id); err != nil {
+				return fmt.Errorf("failed to update position for item %s: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Clear removes all pending items from an agent's queue.
+func (s *QueueStore) Clear(ctx context.Context, agentID string) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM queue_items WHERE agent_id = $1 AND status = $2
+	`, agentID, string(models.QueueItemStatusPending))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear queue: %w", err)
+	}
+	return rowsAffected(result)
+}
+
+// InsertAt inserts a queue item at a specific position, shifting the items
+// at and after it down.
+func (s *QueueStore) InsertAt(ctx context.Context, agentID string, position int, item *models.QueueItem) error {
+	if err := item.Validate(); err != nil {
+		return fmt.Errorf("invalid queue item: %w", err)
+	}
+
+	return s.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := lockQueue(ctx, tx, agentID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE queue_items SET position = position + 1
+			WHERE agent_id = $1 AND position >= $2
+		`, agentID, position); err != nil {
+			return fmt.Errorf("failed to shift items: %w", err)
+		}
+
+		if item.ID == "" {
+			item.ID = uuid.New().String()
+		}
+		item.AgentID = agentID
+		item.Position = position
+		item.CreatedAt = stamp(time.Now())
+		if item.Status == "" {
+			item.Status = models.QueueItemStatusPending
+		}
+		return writeQueueItem(ctx, tx, item)
+	})
+}
+
+// Remove deletes a specific queue item.
+func (s *QueueStore) Remove(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM queue_items WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete queue item: %w", err)
+	}
+	return requireAffected(result, db.ErrQueueItemNotFound)
+}
+
+// Get retrieves a specific queue item by ID.
+func (s *QueueStore) Get(ctx context.Context, id string) (*models.QueueItem, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+queueItemColumns+` FROM queue_items WHERE id = $1`, id)
+
+	item, err := scanQueueItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ErrQueueItemNotFound
+	}
+	return item, err
+}
+
+// UpdateStatus updates the status of a queue item, stamping completed_at
+// when it completes or fails.
+func (s *QueueStore) UpdateStatus(ctx context.Context, id string, status models.QueueItemStatus, errorMsg string) error {
+	var completedAt any
+	if status == models.QueueItemStatusCompleted || status == models.QueueItemStatusFailed {
+		completedAt = stamp(time.Now())
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE queue_items SET status = $1, error_message = $2, completed_at = $3
+		WHERE id = $4
+	`, string(status), errorMsg, completedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update queue item status: %w", err)
+	}
+	return requireAffected(result, db.ErrQueueItemNotFound)
+}
+
+// ReleaseInFlight moves an in-flight item to status with the given attempt
+// count and error, reporting false when the item is not in flight.
+func (s *QueueStore) ReleaseInFlight(ctx context.Context, id string, status models.QueueItemStatus, attempts int, errorMsg string) (bool, error) {
+	var completedAt any
+	if status == models.QueueItemStatusCompleted || status == models.QueueItemStatusFailed {
+		completedAt = stamp(time.Now())
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE queue_items SET status = $1, attempts = $2, error_message = $3, completed_at = $4
+		WHERE id = $5 AND status = $6
+	`, string(status), attempts, errorMsg, completedAt, id, string(models.QueueItemStatusInFlight))
+	if err != nil {
+		return false, fmt.Errorf("failed to release queue item: %w", err)
+	}
+	released, err := rowsAffected(result)
+	if err != nil {
+		return false, err
+	}
+	return released > 0, nil
+}
+
+// ListInFlight returns the items of every agent claimed before cutoff and
+// still in flight, oldest claim first.
+func (s *QueueStore) ListInFlight(ctx context.Context, cutoff time.Time) ([]*models.QueueItem, error) {
+	return s.query(ctx, "failed to query in-flight queue items", `SELECT `+queueItemColumns+`
+		FROM queue_items WHERE status = $1 AND dispatched_at < $2
+		ORDER BY dispatched_at
+	`, string(models.QueueItemStatusInFlight), stamp(cutoff))
+}
+
+// UpdateAttempts updates the dispatch attempt count for a queue item.
+func (s *QueueStore) UpdateAttempts(ctx context.Context, id string, attempts int) error {
+	if attempts < 0 {
+		attempts = 0
+	}
+
+	result, err := s.db.ExecContext(ctx, `UPDATE queue_items SET attempts = $1 WHERE id = $2`, attempts, id)
+	if err != nil {
+		return fmt.Errorf("failed to update queue item attempts: %w", err)
+	}
+	return requireAffected(result, db.ErrQueueItemNotFound)
+}
+
+// Count returns the number of pending items in an agent's queue.
+func (s *QueueStore) Count(ctx context.Context, agentID string) (int, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM queue_items WHERE agent_id = $1 AND status = $2
+	`, agentID, string(models.QueueItemStatusPending)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count queue items: %w", err)
+	}
+	return count, nil
+}
+
+// AgentDraining reports whether an agent is draining its queue. An unknown
+// agent is not draining.
+func (s *QueueStore) AgentDraining(ctx context.Context, agentID string) (bool, error) {
+	var draining bool
+	err := s.db.QueryRowContext(ctx, `SELECT draining FROM agents WHERE id = $1`, agentID).Scan(&draining)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read agent draining: %w", err)
+	}
+	return draining, nil
+}
+
+func (s *QueueStore) query(ctx context.Context, failure, query string, args ...any) ([]*models.QueueItem, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", failure, err)
+	}
+	defer rows.Close()
+
+	var items []*models.QueueItem
+	for rows.Next() {
+		item, err := scanQueueItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating queue items: %w", err)
+	}
+	return items, nil
+}
+
+// writeQueueItem inserts a fully populated queue item.
+func writeQueueItem(ctx context.Context, tx *sql.Tx, item *models.QueueItem) error {
+	if item.TraceID == "" {
+		item.TraceID = trace.FromContext(ctx)
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO queue_items (`+queueItemColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`,
+		item.ID,
+		item.AgentID,
+		string(item.Type),
+		item.Position,
+		string(item.Status),
+		item.Attempts,
+		string(item.Payload),
+		item.Error,
+		item.CreatedAt,
+		nullStamp(item.DispatchedAt),
+		nullStamp(item.CompletedAt),
+		nullString(item.TaskID),
+		nullString(item.TraceID),
+		item.IgnoreQuietHours,
+		nullString(item.DispatcherID),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert queue item: %w", err)
+	}
+	return nil
+}
+
+// scanQueueItem scans queueItemColumns. sql.ErrNoRows is returned as is.
+func scanQueueItem(row rowScanner) (*models.QueueItem, error) {
+	var item models.QueueItem
+	var itemType, status, payloadJSON string
+	var errorMsg, taskID, traceID, dispatcherID sql.NullString
+	var dispatchedAt, completedAt sql.NullTime
+
+	if err := row.Scan(
+		&item.ID,
+		&item.AgentID,
+		&itemType,
+		&item.Position,
+		&status,
+		&item.Attempts,
+		&payloadJSON,
+		&errorMsg,
+		&item.CreatedAt,
+		&dispatchedAt,
+		&completedAt,
+		&taskID,
+		&traceID,
+		&item.IgnoreQuietHours,
+		&dispatcherID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan queue item: %w", err)
+	}
+
+	item.Type = models.QueueItemType(itemType)
+	item.Status = models.QueueItemStatus(status)
+	item.Payload = json.RawMessage(payloadJSON)
+	item.Error = errorMsg.String
+	item.TaskID = taskID.String
+	item.TraceID = traceID.String
+	item.DispatcherID = dispatcherID.String
+	item.CreatedAt = item.CreatedAt.UTC()
+	item.DispatchedAt = timePtr(dispatchedAt)
+	item.CompletedAt = timePtr(completedAt)
+	return &item, nil
+}
+
+// rowsAffected returns how many rows result changed.
+func rowsAffected(result sql.Result) (int, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(affected), nil
+}
@@ -0,0 +1,150 @@
+// Package store defines the storage interfaces services depend on, so the
+// database behind each store can change without touching them.
+//
+// The repositories in internal/db implement every store on SQLite and are
+// the default. internal/store/postgres implements the agent, queue, and
+// event stores, the ones written most, on Postgres; Open picks between the
+// two by configuration.
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// AgentStore persists agents. Missing agents are db.ErrAgentNotFound.
+type AgentStore interface {
+	Create(ctx context.Context, agent *models.Agent) error
+	Get(ctx context.Context, id string, opts ...db.AgentQueryOption) (*models.Agent, error)
+	List(ctx context.Context, opts ...db.AgentQueryOption) ([]*models.Agent, error)
+	ListByWorkspace(ctx context.Context, workspaceID string, opts ...db.AgentQueryOption) ([]*models.Agent, error)
+	ListByState(ctx context.Context, state models.AgentState, opts ...db.AgentQueryOption) ([]*models.Agent, error)
+	ListWithQueueLength(ctx context.Context, opts ...db.AgentQueryOption) ([]*models.Agent, error)
+	Update(ctx context.Context, agent *models.Agent) error
+	Delete(ctx context.Context, id string) error
+	HardDelete(ctx context.Context, id string) error
+	PruneDeleted(ctx context.Context, cutoff time.Time) (int, error)
+	SetDraining(ctx context.Context, id string, draining bool) error
+	ClaimStandby(ctx context.Context, workspaceID string, agentType models.AgentType) (*models.Agent, error)
+}
+
+// QueueStore persists agent queues. Missing items are
+// db.ErrQueueItemNotFound and an empty queue is db.ErrQueueEmpty.
+type QueueStore interface {
+	Enqueue(ctx context.Context, agentID string, items ...*models.QueueItem) error
+	EnqueueBatch(ctx context.Context, agentID string, atHead bool, items ...*models.QueueItem) error
+	Dequeue(ctx context.Context, agentID, dispatcherID string) (*models.QueueItem, error)
+	Peek(ctx context.Context, agentID string) (*models.QueueItem, error)
+	List(ctx context.Context, agentID string) ([]*models.QueueItem, error)
+	ListInFlight(ctx context.Context, cutoff time.Time) ([]*models.QueueItem, error)
+	ListPending(ctx context.Context, agentID string) ([]*models.QueueItem, error)
+	ListByTask(ctx context.Context, taskID string) ([]*models.QueueItem, error)
+	SkipPendingByTask(ctx context.Context, taskID, reason string) (int, error)
+	Reorder(ctx context.Context, agentID string, itemIDs []string) error
+	Clear(ctx context.Context, agentID string) (int, error)
+	InsertAt(ctx context.Context, agentID string, position int, item *models.QueueItem) error
+	Remove(ctx context.Context, id string) error
+	Get(ctx context.Context, id string) (*models.QueueItem, error)
+	UpdateStatus(ctx context.Context, id string, status models.QueueItemStatus, errorMsg string) error
+	ReleaseInFlight(ctx context.Context, id string, status models.QueueItemStatus, attempts int, errorMsg string) (bool, error)
+	UpdateAttempts(ctx context.Context, id string, attempts int) error
+	Count(ctx context.Context, agentID string) (int, error)
+	AgentDraining(ctx context.Context, agentID string) (bool, error)
+}
+
+// EventStore persists the event log. Missing events are
+// db.ErrEventNotFound.
+type EventStore interface {
+	Append(ctx context.Context, event *models.Event) error
+	Create(ctx context.Context, event *models.Event) error
+	CreateBatch(ctx context.Context, events []*models.Event) error
+	Get(ctx context.Context, id string) (*models.Event, error)
+	Query(ctx context.Context, q db.EventQuery) (*db.EventPage, error)
+	Iterate(ctx context.Context, q db.EventQuery, batchSize int) *db.EventIterator
+	ListByEntity(ctx context.Context, entityType models.EntityType, entityID string, limit int) ([]*models.Event, error)
+	Count(ctx context.Context) (int64, error)
+	OldestTimestamp(ctx context.Context) (*time.Time, error)
+	DeleteOlderThan(ctx context.Context, before time.Time, limit int) (int64, error)
+	DeleteExcess(ctx context.Context, maxCount int, limit int) (int64, error)
+	ListOlderThan(ctx context.Context, before time.Time, limit int) ([]*models.Event, error)
+	ListOldest(ctx context.Context, limit int) ([]*models.Event, error)
+	DeleteByIDs(ctx context.Context, ids []string) (int64, error)
+}
+
+// WorkspaceStore persists workspaces. Missing workspaces are
+// db.ErrWorkspaceNotFound.
+type WorkspaceStore interface {
+	Create(ctx context.Context, workspace *models.Workspace) error
+	Get(ctx context.Context, id string) (*models.Workspace, error)
+	GetByNodeAndPath(ctx context.Context, nodeID, repoPath string) (*models.Workspace, error)
+	GetByTmuxSession(ctx context.Context, nodeID, sessionName string) (*models.Workspace, error)
+	GetByName(ctx context.Context, name string) (*models.Workspace, error)
+	List(ctx context.Context) ([]*models.Workspace, error)
+	ListByNode(ctx context.Context, nodeID string) ([]*models.Workspace, error)
+	ListByStatus(ctx context.Context, status models.WorkspaceStatus) ([]*models.Workspace, error)
+	ListWithAgentCounts(ctx context.Context) ([]*models.Workspace, error)
+	Update(ctx context.Context, workspace *models.Workspace) error
+	UpdateStatus(ctx context.Context, id string, status models.WorkspaceStatus) error
+	UpdateGitInfo(ctx context.Context, id string, gitInfo *models.GitInfo) error
+	SetPause(ctx context.Context, id string, pause *models.WorkspacePause) error
+	SetDraining(ctx context.Context, id string, draining bool) error
+	SetBootstrap(ctx context.Context, id string, bootstrap *models.WorkspaceBootstrap) error
+	SetQuietHours(ctx context.Context, id, spec string) error
+	SetHibernateAfter(ctx context.Context, id string, seconds int) error
+	Delete(ctx context.Context, id string) error
+	GetAgentCount(ctx context.Context, workspaceID string) (int, error)
+	GetAgentStateCounts(ctx context.Context, workspaceID string) (map[models.AgentState]int, error)
+	Count(ctx context.Context) (int, error)
+}
+
+// UsageStore persists usage records and their daily rollups.
+type UsageStore interface {
+	Create(ctx context.Context, record *models.UsageRecord) error
+	CreateIfAbsent(ctx context.Context, record *models.UsageRecord) (bool, error)
+	Get(ctx context.Context, id string) (*models.UsageRecord, error)
+	Query(ctx context.Context, q models.UsageQuery) ([]*models.UsageRecord, error)
+	Iterate(ctx context.Context, q models.UsageQuery, batchSize int) *db.UsageIterator
+	Delete(ctx context.Context, id string) error
+	SummarizeByAccount(ctx context.Context, accountID string, since, until *time.Time) (*models.UsageSummary, error)
+	SummarizeByProvider(ctx context.Context, provider models.Provider, since, until *time.Time) (*models.UsageSummary, error)
+	SummarizeByWorkspace(ctx context.Context, workspaceID string, since, until *time.Time) (*models.UsageSummary, error)
+	SummarizeAll(ctx context.Context, since, until *time.Time) (*models.UsageSummary, error)
+	GetDailyUsage(ctx context.Context, accountID string, since, until time.Time, limit int) ([]*models.DailyUsage, error)
+	GetTopAccountsByUsage(ctx context.Context, since, until *time.Time, limit int) ([]*models.UsageSummary, error)
+	UpdateDailyCache(ctx context.Context, accountID, date string, provider models.Provider) error
+	GetDailyCache(ctx context.Context, date string) ([]*models.DailyUsage, error)
+	RefreshDailyCache(ctx context.Context, now time.Time) (int, error)
+	DailyCacheRefreshedAt(ctx context.Context) (*time.Time, error)
+	SummarizeDailyCache(ctx context.Context, accountID string, provider models.Provider, fromDate, toDate string) (*models.UsageSummary, error)
+	DeleteDailyCacheBefore(ctx context.Context, date string) (int64, error)
+	UpsertAgentDailyCosts(ctx context.Context, costs []models.AgentCost) error
+	GetAgentDailyCosts(ctx context.Context, date string) ([]models.AgentCost, error)
+	DeleteOlderThan(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// AccountStore persists provider accounts. Missing accounts are
+// db.ErrAccountNotFound.
+type AccountStore interface {
+	Create(ctx context.Context, account *models.Account) error
+	List(ctx context.Context, provider *models.Provider) ([]*models.Account, error)
+	Get(ctx context.Context, id string) (*models.Account, error)
+	Update(ctx context.Context, account *models.Account) error
+	Delete(ctx context.Context, id string) error
+	SetCooldown(ctx context.Context, id string, until time.Time) error
+	ClearCooldown(ctx context.Context, id string) error
+	SetCredentialExpiry(ctx context.Context, id string, expiresAt *time.Time) error
+	MarkCredentialExpiryWarned(ctx context.Context, id string, at time.Time) (bool, error)
+	GetNextAvailable(ctx context.Context, provider models.Provider) (*models.Account, error)
+}
+
+var (
+	_ AgentStore     = (*db.AgentRepository)(nil)
+	_ QueueStore     = (*db.QueueRepository)(nil)
+	_ EventStore     = (*db.EventRepository)(nil)
+	_ WorkspaceStore = (*db.WorkspaceRepository)(nil)
+	_ UsageStore     = (*db.UsageRepository)(nil)
+	_ AccountStore   = (*db.AccountRepository)(nil)
+)
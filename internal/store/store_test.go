@@ -0,0 +1,498 @@
+package store_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/store"
+	"github.com/opencode-ai/swarm/internal/store/postgres"
+)
+
+// postgresDSNEnv names a scratch Postgres database the tests may wipe.
+// The Postgres backend is skipped when it is unset.
+const postgresDSNEnv = "SWARM_TEST_POSTGRES_DSN"
+
+// forEachBackend runs fn against the stores of each backend, with a
+// workspace created for agents to join.
+func forEachBackend(t *testing.T, fn func(t *testing.T, stores *store.Stores, ws *models.Workspace)) {
+	t.Helper()
+
+	for _, backend := range []string{config.DatabaseBackendSQLite, config.DatabaseBackendPostgres} {
+		t.Run(backend, func(t *testing.T) {
+			cfg := config.DatabaseConfig{Backend: backend}
+			if backend == config.DatabaseBackendPostgres {
+				cfg.PostgresDSN = os.Getenv(postgresDSNEnv)
+				if cfg.PostgresDSN == "" {
+					t.Skipf("%s not set", postgresDSNEnv)
+				}
+				resetPostgres(t, cfg.PostgresDSN)
+			}
+
+			sqlite, err := db.OpenInMemory()
+			if err != nil {
+				t.Fatalf("failed to open in-memory database: %v", err)
+			}
+			t.Cleanup(func() { sqlite.Close() })
+			if err := sqlite.Migrate(context.Background()); err != nil {
+				t.Fatalf("failed to migrate database: %v", err)
+			}
+
+			stores, err := store.Open(context.Background(), cfg, sqlite)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			t.Cleanup(func() { stores.Close() })
+
+			fn(t, stores, createTestWorkspace(t, sqlite, stores))
+		})
+	}
+}
+
+func resetPostgres(t *testing.T, dsn string) {
+	t.Helper()
+
+	ctx := context.Background()
+	pg, err := postgres.Open(ctx, dsn)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	defer pg.Close()
+	if err := pg.Migrate(ctx); err != nil {
+		t.Fatalf("migrate postgres: %v", err)
+	}
+	if _, err := pg.ExecContext(ctx, `TRUNCATE events, queue_items, agents`); err != nil {
+		t.Fatalf("truncate postgres: %v", err)
+	}
+}
+
+func createTestWorkspace(t *testing.T, sqlite *db.DB, stores *store.Stores) *models.Workspace {
+	t.Helper()
+
+	node := &models.Node{
+		Name:       "test-node",
+		SSHBackend: models.SSHBackendAuto,
+		Status:     models.NodeStatusUnknown,
+		IsLocal:    true,
+	}
+	if err := db.NewNodeRepository(sqlite).Create(context.Background(), node); err != nil {
+		t.Fatalf("create node: %v", err)
+	}
+
+	ws := &models.Workspace{
+		NodeID:      node.ID,
+		RepoPath:    "/tmp/swarm-test",
+		TmuxSession: "swarm-test",
+	}
+	if err := stores.Workspaces.Create(context.Background(), ws); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	return ws
+}
+
+func createTestAgent(t *testing.T, agents store.AgentStore, ws *models.Workspace, pane string) *models.Agent {
+	t.Helper()
+
+	agent := &models.Agent{
+		WorkspaceID: ws.ID,
+		Type:        models.AgentTypeOpenCode,
+		TmuxPane:    pane,
+		State:       models.AgentStateIdle,
+	}
+	if err := agents.Create(context.Background(), agent); err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	return agent
+}
+
+func newMessageItem(t *testing.T, text string) *models.QueueItem {
+	t.Helper()
+
+	payload, err := json.Marshal(models.MessagePayload{Text: text})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return &models.QueueItem{
+		ID:      uuid.New().String(),
+		Type:    models.QueueItemTypeMessage,
+		Payload: payload,
+	}
+}
+
+func messageText(t *testing.T, item *models.QueueItem) string {
+	t.Helper()
+
+	var payload models.MessagePayload
+	if err := json.Unmarshal(item.Payload, &payload); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	return payload.Text
+}
+
+func TestAgentStore_CreateUpdateDelete(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, stores *store.Stores, ws *models.Workspace) {
+		ctx := context.Background()
+		agents := stores.Agents
+
+		agent := createTestAgent(t, agents, ws, "swarm-test:0.1")
+		other := createTestAgent(t, agents, ws, "swarm-test:0.2")
+
+		dup := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-test:0.1"}
+		if err := agents.Create(ctx, dup); !errors.Is(err, db.ErrAgentAlreadyExists) {
+			t.Fatalf("expected ErrAgentAlreadyExists, got %v", err)
+		}
+
+		agent.State = models.AgentStateWorking
+		agent.StateInfo.Reason = "busy"
+		agent.Metadata.Model = "gpt-5"
+		if err := agents.Update(ctx, agent); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		got, err := agents.Get(ctx, agent.ID)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if got.State != models.AgentStateWorking || got.StateInfo.Reason != "busy" || got.Metadata.Model != "gpt-5" {
+			t.Fatalf("unexpected agent after update: %+v", got)
+		}
+
+		working, err := agents.ListByState(ctx, models.AgentStateWorking)
+		if err != nil {
+			t.Fatalf("ListByState failed: %v", err)
+		}
+		if len(working) != 1 || working[0].ID != agent.ID {
+			t.Fatalf("expected only %s working, got %d agents", agent.ID, len(working))
+		}
+
+		listed, err := agents.ListByWorkspace(ctx, ws.ID)
+		if err != nil {
+			t.Fatalf("ListByWorkspace failed: %v", err)
+		}
+		if len(listed) != 2 || listed[0].ID != agent.ID || listed[1].ID != other.ID {
+			t.Fatalf("expected agents in creation order, got %d agents", len(listed))
+		}
+
+		if err := agents.Delete(ctx, agent.ID); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := agents.Get(ctx, agent.ID); !errors.Is(err, db.ErrAgentNotFound) {
+			t.Fatalf("expected ErrAgentNotFound, got %v", err)
+		}
+		deleted, err := agents.Get(ctx, agent.ID, db.IncludeDeleted())
+		if err != nil {
+			t.Fatalf("Get with deleted failed: %v", err)
+		}
+		if deleted.DeletedAt == nil {
+			t.Fatal("expected deleted_at set")
+		}
+		all, err := agents.List(ctx)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(all) != 1 || all[0].ID != other.ID {
+			t.Fatalf("expected only %s listed, got %d agents", other.ID, len(all))
+		}
+
+		if err := agents.HardDelete(ctx, other.ID); err != nil {
+			t.Fatalf("HardDelete failed: %v", err)
+		}
+		if err := agents.HardDelete(ctx, other.ID); !errors.Is(err, db.ErrAgentNotFound) {
+			t.Fatalf("expected ErrAgentNotFound, got %v", err)
+		}
+	})
+}
+
+func TestAgentStore_ClaimStandby(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, stores *store.Stores, ws *models.Workspace) {
+		ctx := context.Background()
+		agents := stores.Agents
+
+		var standby []*models.Agent
+		for i := 0; i < 3; i++ {
+			agent := &models.Agent{
+				WorkspaceID: ws.ID,
+				Type:        models.AgentTypeOpenCode,
+				TmuxPane:    fmt.Sprintf("swarm-test:1.%d", i),
+				State:       models.AgentStateIdle,
+				Standby:     true,
+			}
+			if err := agents.Create(ctx, agent); err != nil {
+				t.Fatalf("create agent: %v", err)
+			}
+			standby = append(standby, agent)
+		}
+		if err := agents.SetDraining(ctx, standby[0].ID, true); err != nil {
+			t.Fatalf("SetDraining failed: %v", err)
+		}
+
+		claimed := make(chan string, len(standby))
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				agent, err := agents.ClaimStandby(ctx, ws.ID, models.AgentTypeOpenCode)
+				if errors.Is(err, db.ErrNoStandbyAgent) {
+					return
+				}
+				if err != nil {
+					t.Errorf("ClaimStandby failed: %v", err)
+					return
+				}
+				claimed <- agent.ID
+			}()
+		}
+		wg.Wait()
+		close(claimed)
+
+		seen := make(map[string]bool)
+		for id := range claimed {
+			if seen[id] {
+				t.Fatalf("agent %s claimed twice", id)
+			}
+			if id == standby[0].ID {
+				t.Fatal("claimed a draining agent")
+			}
+			seen[id] = true
+		}
+		if len(seen) != 2 {
+			t.Fatalf("expected 2 claims, got %d", len(seen))
+		}
+	})
+}
+
+func TestQueueStore_Ordering(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, stores *store.Stores, ws *models.Workspace) {
+		ctx := context.Background()
+		queue := stores.Queue
+		agent := createTestAgent(t, stores.Agents, ws, "swarm-test:0.1")
+
+		first, second := newMessageItem(t, "first"), newMessageItem(t, "second")
+		if err := queue.Enqueue(ctx, agent.ID, first, second); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		urgent := newMessageItem(t, "urgent")
+		if err := queue.EnqueueBatch(ctx, agent.ID, true, urgent); err != nil {
+			t.Fatalf("EnqueueBatch failed: %v", err)
+		}
+		middle := newMessageItem(t, "middle")
+		if err := queue.InsertAt(ctx, agent.ID, 2, middle); err != nil {
+			t.Fatalf("InsertAt failed: %v", err)
+		}
+
+		assertOrder := func(want ...string) {
+			t.Helper()
+			items, err := queue.ListPending(ctx, agent.ID)
+			if err != nil {
+				t.Fatalf("ListPending failed: %v", err)
+			}
+			var got []string
+			for _, item := range items {
+				got = append(got, messageText(t, item))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		}
+		assertOrder("urgent", "middle", "first", "second")
+
+		if err := queue.Reorder(ctx, agent.ID, []string{second.ID, first.ID, middle.ID, urgent.ID}); err != nil {
+			t.Fatalf("Reorder failed: %v", err)
+		}
+		assertOrder("second", "first", "middle", "urgent")
+		if err := queue.Reorder(ctx, agent.ID, []string{second.ID}); err == nil {
+			t.Fatal("expected Reorder to require every pending item")
+		}
+
+		dequeued, err := queue.Dequeue(ctx, agent.ID, "test")
+		if err != nil {
+			t.Fatalf("Dequeue failed: %v", err)
+		}
+		if dequeued.ID != second.ID || dequeued.Status != models.QueueItemStatusInFlight || dequeued.DispatchedAt == nil || dequeued.DispatcherID != "test" {
+			t.Fatalf("unexpected dequeued item: %+v", dequeued)
+		}
+		if err := queue.UpdateStatus(ctx, dequeued.ID, models.QueueItemStatusFailed, "boom"); err != nil {
+			t.Fatalf("UpdateStatus failed: %v", err)
+		}
+		failed, err := queue.Get(ctx, dequeued.ID)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if failed.Status != models.QueueItemStatusFailed || failed.Error != "boom" || failed.CompletedAt == nil {
+			t.Fatalf("unexpected failed item: %+v", failed)
+		}
+
+		count, err := queue.Count(ctx, agent.ID)
+		if err != nil {
+			t.Fatalf("Count failed: %v", err)
+		}
+		if count != 3 {
+			t.Fatalf("expected 3 pending items, got %d", count)
+		}
+		cleared, err := queue.Clear(ctx, agent.ID)
+		if err != nil {
+			t.Fatalf("Clear failed: %v", err)
+		}
+		if cleared != 3 {
+			t.Fatalf("expected 3 cleared items, got %d", cleared)
+		}
+		if _, err := queue.Dequeue(ctx, agent.ID, "test"); !errors.Is(err, db.ErrQueueEmpty) {
+			t.Fatalf("expected ErrQueueEmpty, got %v", err)
+		}
+		if err := queue.Remove(ctx, first.ID); !errors.Is(err, db.ErrQueueItemNotFound) {
+			t.Fatalf("expected ErrQueueItemNotFound, got %v", err)
+		}
+	})
+}
+
+func TestQueueStore_ConcurrentDequeue(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, stores *store.Stores, ws *models.Workspace) {
+		ctx := context.Background()
+		queue := stores.Queue
+		agent := createTestAgent(t, stores.Agents, ws, "swarm-test:0.1")
+
+		const total = 20
+		for i := 0; i < total; i++ {
+			if err := queue.Enqueue(ctx, agent.ID, newMessageItem(t, fmt.Sprintf("item %d", i))); err != nil {
+				t.Fatalf("Enqueue failed: %v", err)
+			}
+		}
+
+		var mu sync.Mutex
+		seen := make(map[string]bool)
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					item, err := queue.Dequeue(ctx, agent.ID, "test")
+					if errors.Is(err, db.ErrQueueEmpty) {
+						return
+					}
+					if err != nil {
+						t.Errorf("Dequeue failed: %v", err)
+						return
+					}
+					mu.Lock()
+					if seen[item.ID] {
+						t.Errorf("item %s dequeued twice", item.ID)
+					}
+					seen[item.ID] = true
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if len(seen) != total {
+			t.Fatalf("expected %d dequeued items, got %d", total, len(seen))
+		}
+	})
+}
+
+func TestEventStore_QueryPagination(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, stores *store.Stores, ws *models.Workspace) {
+		ctx := context.Background()
+		events := stores.Events
+
+		base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		var batch []*models.Event
+		for i := 0; i < 5; i++ {
+			batch = append(batch, &models.Event{
+				// The first three share a second, so pages break ties by ID.
+				Timestamp:  base.Add(time.Duration(i/3) * time.Minute),
+				Type:       models.EventTypeAgentStateChanged,
+				EntityType: models.EntityTypeAgent,
+				EntityID:   fmt.Sprintf("agent-%d", i%2),
+				Payload:    json.RawMessage(`{"n":` + fmt.Sprint(i) + `}`),
+				Metadata:   map[string]string{"i": fmt.Sprint(i)},
+			})
+		}
+		if err := events.CreateBatch(ctx, batch); err != nil {
+			t.Fatalf("CreateBatch failed: %v", err)
+		}
+		if err := events.Append(ctx, &models.Event{Type: models.EventTypeAgentStateChanged}); !errors.Is(err, db.ErrInvalidEvent) {
+			t.Fatalf("expected ErrInvalidEvent, got %v", err)
+		}
+
+		var paged []*models.Event
+		q := db.EventQuery{Limit: 2}
+		for {
+			page, err := events.Query(ctx, q)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			paged = append(paged, page.Events...)
+			if page.NextCursor == "" {
+				break
+			}
+			q.Cursor = page.NextCursor
+		}
+		if len(paged) != 5 {
+			t.Fatalf("expected 5 events across pages, got %d", len(paged))
+		}
+		for i := 1; i < len(paged); i++ {
+			prev, cur := paged[i-1], paged[i]
+			if cur.Timestamp.Before(prev.Timestamp) || (cur.Timestamp.Equal(prev.Timestamp) && cur.ID < prev.ID) {
+				t.Fatalf("events out of order at %d", i)
+			}
+		}
+
+		got, err := events.Get(ctx, batch[4].ID)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if string(got.Payload) != `{"n":4}` || got.Metadata["i"] != "4" || !got.Timestamp.Equal(batch[4].Timestamp) {
+			t.Fatalf("unexpected event: %+v", got)
+		}
+		if _, err := events.Get(ctx, "missing"); !errors.Is(err, db.ErrEventNotFound) {
+			t.Fatalf("expected ErrEventNotFound, got %v", err)
+		}
+
+		since, until := base, base.Add(time.Minute)
+		page, err := events.Query(ctx, db.EventQuery{EntityIDs: []string{"agent-1"}, Since: &since, Until: &until})
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if len(page.Events) != 1 || page.Events[0].ID != batch[1].ID {
+			t.Fatalf("expected only event 1 in range, got %d events", len(page.Events))
+		}
+
+		it := events.Iterate(ctx, db.EventQuery{}, 2)
+		iterated := 0
+		for it.Next() {
+			iterated++
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("Iterate failed: %v", err)
+		}
+		if iterated != 5 {
+			t.Fatalf("expected 5 iterated events, got %d", iterated)
+		}
+
+		deleted, err := events.DeleteOlderThan(ctx, base.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("DeleteOlderThan failed: %v", err)
+		}
+		if deleted != 3 {
+			t.Fatalf("expected 3 deleted events, got %d", deleted)
+		}
+		oldest, err := events.OldestTimestamp(ctx)
+		if err != nil {
+			t.Fatalf("OldestTimestamp failed: %v", err)
+		}
+		if oldest == nil || !oldest.Equal(base.Add(time.Minute)) {
+			t.Fatalf("unexpected oldest timestamp: %v", oldest)
+		}
+	})
+}
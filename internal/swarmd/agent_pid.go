@@ -0,0 +1,99 @@
+package swarmd
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultPIDRefreshInterval is how often agents' process IDs are looked up
+// again, since a pane can restart its command.
+const DefaultPIDRefreshInterval = 10 * time.Second
+
+// processTable looks up and kills the processes agents run as.
+type processTable interface {
+	// Foreground returns the process in the foreground of the terminal
+	// session led by sessionPID, such as the command a pane's shell
+	// runs, or sessionPID itself when nothing else is.
+	Foreground(sessionPID int) (int, error)
+
+	// Kill sends SIGKILL to pid, and to the process group it leads, if
+	// it is still in the session led by sessionPID.
+	Kill(pid, sessionPID int) error
+}
+
+// agentPIDs returns the process running in paneID, pid, and the pane's own
+// process, panePID. pid is panePID when the foreground process cannot be
+// told.
+func (s *Server) agentPIDs(ctx context.Context, paneID string) (pid, panePID int, err error) {
+	panePID, err = s.backend.PanePID(ctx, paneID)
+	if err != nil {
+		return 0, 0, err
+	}
+	pid, err = s.processes.Foreground(panePID)
+	if err != nil {
+		s.logger.Debug().Ctx(ctx).Err(err).Str("pane_id", paneID).Int("pane_pid", panePID).Msg("failed to find pane foreground process")
+		return panePID, panePID, nil
+	}
+	return pid, panePID, nil
+}
+
+// RefreshAgentPIDs looks up the process of every agent again and returns
+// how many changed.
+func (s *Server) RefreshAgentPIDs(ctx context.Context) int {
+	changed := 0
+	for _, info := range s.agentHandles() {
+		pid, panePID, err := s.agentPIDs(ctx, info.paneID)
+		if err != nil {
+			s.logger.Debug().Ctx(ctx).Err(err).Str("agent_id", info.id).Msg("failed to refresh agent PID")
+			continue
+		}
+
+		info.mu.Lock()
+		if info.removed || (info.pid == pid && info.panePID == panePID) {
+			info.mu.Unlock()
+			continue
+		}
+		previous := info.pid
+		info.pid = pid
+		info.panePID = panePID
+		info.mu.Unlock()
+
+		if s.resourceMonitor != nil {
+			s.resourceMonitor.UpdateAgentPID(info.id, pid)
+		}
+		s.logger.Debug().Ctx(ctx).Str("agent_id", info.id).Int("previous_pid", previous).Int("pid", pid).Msg("agent PID changed")
+		changed++
+	}
+	return changed
+}
+
+// RunPIDRefresh refreshes agents' process IDs every interval until ctx is
+// canceled.
+func (s *Server) RunPIDRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPIDRefreshInterval
+	}
+	ticker := s.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			s.RefreshAgentPIDs(ctx)
+		}
+	}
+}
+
+// killAgentProcessLocked sends SIGKILL to the agent's foreground process,
+// so a command that detached from the pane does not outlive it. The pane's
+// own process is left to KillPane. The caller must hold info.mu.
+func (s *Server) killAgentProcessLocked(info *agentInfo) {
+	if info.pid <= 0 || info.pid == info.panePID {
+		return
+	}
+	if err := s.processes.Kill(info.pid, info.panePID); err != nil {
+		s.logger.Warn().Err(err).Str("agent_id", info.id).Int("pid", info.pid).Msg("failed to kill agent process")
+	}
+}
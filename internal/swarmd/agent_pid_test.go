@@ -0,0 +1,116 @@
+package swarmd
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+)
+
+// fakeProcesses puts foreground in the foreground of every session and
+// records the processes killed.
+type fakeProcesses struct {
+	mu         sync.Mutex
+	foreground int
+	killed     [][2]int // pid, session
+}
+
+func (p *fakeProcesses) setForeground(pid int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.foreground = pid
+}
+
+func (p *fakeProcesses) Foreground(sessionPID int) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.foreground == 0 {
+		return sessionPID, nil
+	}
+	return p.foreground, nil
+}
+
+func (p *fakeProcesses) Kill(pid, sessionPID int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.killed = append(p.killed, [2]int{pid, sessionPID})
+	return nil
+}
+
+func TestAgentPIDFollowsForegroundProcess(t *testing.T) {
+	ctx := context.Background()
+	srv := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	server := NewServer(zerolog.Nop(), WithTmuxClient(tmux.NewClient(srv)))
+	procs := &fakeProcesses{foreground: 4242}
+	server.processes = procs
+
+	spawned, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-1", WorkspaceId: "ws-1", Command: "claude"})
+	if err != nil {
+		t.Fatalf("SpawnAgent() error = %v", err)
+	}
+	if spawned.Agent.Pid != 4242 {
+		t.Fatalf("expected the foreground process 4242, got %d", spawned.Agent.Pid)
+	}
+
+	// The pane restarts its command.
+	procs.setForeground(4343)
+	if changed := server.RefreshAgentPIDs(ctx); changed != 1 {
+		t.Fatalf("expected 1 PID changed, got %d", changed)
+	}
+	if changed := server.RefreshAgentPIDs(ctx); changed != 0 {
+		t.Fatalf("expected no PID changed on an unchanged pane, got %d", changed)
+	}
+	got, err := server.GetAgent(ctx, &swarmdv1.GetAgentRequest{AgentId: "agent-1"})
+	if err != nil || got.Agent.Pid != 4343 {
+		t.Fatalf("expected GetAgent to report PID 4343, got %+v (%v)", got, err)
+	}
+	list, err := server.ListAgents(ctx, &swarmdv1.ListAgentsRequest{})
+	if err != nil || len(list.Agents) != 1 || list.Agents[0].Pid != 4343 {
+		t.Fatalf("expected ListAgents to report PID 4343, got %+v (%v)", list, err)
+	}
+
+	if _, err := server.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: "agent-1", Force: true}); err != nil {
+		t.Fatalf("KillAgent() error = %v", err)
+	}
+	if len(procs.killed) != 1 || procs.killed[0][0] != 4343 || procs.killed[0][1] <= 0 || procs.killed[0][1] == 4343 {
+		t.Fatalf("expected a forced kill to SIGKILL 4343 in the pane's session, got %v", procs.killed)
+	}
+}
+
+func TestKillAgentLeavesPaneProcessToKillPane(t *testing.T) {
+	ctx := context.Background()
+	srv := tmuxtest.NewServer()
+	server := NewServer(zerolog.Nop(), WithTmuxClient(tmux.NewClient(srv)))
+	procs := &fakeProcesses{}
+	server.processes = procs
+
+	for _, force := range []bool{false, true} {
+		if _, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-1", Command: "claude"}); err != nil {
+			t.Fatalf("SpawnAgent() error = %v", err)
+		}
+		if _, err := server.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: "agent-1", Force: force}); err != nil {
+			t.Fatalf("KillAgent(force=%v) error = %v", force, err)
+		}
+	}
+	if len(procs.killed) != 0 {
+		t.Fatalf("expected no process signaled when only the shell runs or the kill is graceful, got %v", procs.killed)
+	}
+
+	// A graceful kill never signals the process directly.
+	procs.setForeground(4242)
+	if _, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-2", Command: "claude"}); err != nil {
+		t.Fatalf("SpawnAgent() error = %v", err)
+	}
+	if _, err := server.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: "agent-2"}); err != nil {
+		t.Fatalf("KillAgent() error = %v", err)
+	}
+	if len(procs.killed) != 0 {
+		t.Fatalf("expected a graceful kill to leave the process to the pane, got %v", procs.killed)
+	}
+}
@@ -0,0 +1,127 @@
+package swarmd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/rs/zerolog"
+)
+
+// DefaultAgentPruneInterval is how often terminated agents are pruned.
+const DefaultAgentPruneInterval = time.Hour
+
+// AgentPruner deletes terminated agents once they are older than the
+// configured retention period.
+type AgentPruner struct {
+	agentRepo *db.AgentRepository
+	clock     clock.Clock
+	logger    zerolog.Logger
+
+	// mu guards the settings Reconfigure replaces.
+	mu           sync.Mutex
+	maxAge       time.Duration
+	interval     time.Duration
+	reconfigured chan struct{}
+}
+
+// AgentPrunerOption configures an AgentPruner.
+type AgentPrunerOption func(*AgentPruner)
+
+// WithAgentPruneInterval sets how often terminated agents are pruned.
+func WithAgentPruneInterval(d time.Duration) AgentPrunerOption {
+	return func(p *AgentPruner) {
+		p.interval = d
+	}
+}
+
+// WithAgentPruneClock sets the time source retention ages are measured
+// against.
+func WithAgentPruneClock(c clock.Clock) AgentPrunerOption {
+	return func(p *AgentPruner) {
+		p.clock = c
+	}
+}
+
+// NewAgentPruner creates a pruner keeping terminated agents for maxAge.
+// A zero maxAge keeps them forever.
+func NewAgentPruner(database *db.DB, maxAge time.Duration, logger zerolog.Logger, opts ...AgentPrunerOption) *AgentPruner {
+	p := &AgentPruner{
+		agentRepo:    db.NewAgentRepository(database),
+		maxAge:       maxAge,
+		interval:     DefaultAgentPruneInterval,
+		logger:       logger,
+		reconfigured: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.clock = clock.OrReal(p.clock)
+	if p.interval <= 0 {
+		p.interval = DefaultAgentPruneInterval
+	}
+	return p
+}
+
+// Reconfigure applies the agent retention settings of cfg. A running loop
+// prunes again at once and then every new interval.
+func (p *AgentPruner) Reconfigure(cfg *config.Config) error {
+	interval := cfg.AgentRetention.CleanupInterval
+	if interval <= 0 {
+		interval = DefaultAgentPruneInterval
+	}
+
+	p.mu.Lock()
+	p.maxAge, p.interval = cfg.AgentRetention.MaxAge, interval
+	p.mu.Unlock()
+
+	select {
+	case p.reconfigured <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (p *AgentPruner) settings() (time.Duration, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.maxAge, p.interval
+}
+
+// Run prunes immediately and then every interval until ctx is canceled.
+func (p *AgentPruner) Run(ctx context.Context) {
+	_, interval := p.settings()
+	ticker := p.clock.NewTicker(interval)
+	defer func() { ticker.Stop() }()
+
+	for {
+		if pruned, err := p.Prune(ctx); err != nil {
+			p.logger.Warn().Ctx(ctx).Err(err).Msg("agent pruning failed")
+		} else if pruned > 0 {
+			p.logger.Info().Ctx(ctx).Int("pruned", pruned).Msg("pruned terminated agents")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case <-p.reconfigured:
+			ticker.Stop()
+			_, interval = p.settings()
+			ticker = p.clock.NewTicker(interval)
+		}
+	}
+}
+
+// Prune deletes the agents terminated longer ago than the retention period
+// and returns how many were removed. It does nothing when retention is
+// unlimited.
+func (p *AgentPruner) Prune(ctx context.Context) (int, error) {
+	maxAge, _ := p.settings()
+	if maxAge <= 0 {
+		return 0, nil
+	}
+	return p.agentRepo.PruneDeleted(ctx, p.clock.Now().Add(-maxAge))
+}
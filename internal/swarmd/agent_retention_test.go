@@ -0,0 +1,71 @@
+package swarmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestAgentPrunerPrune(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "repo", RepoPath: "/repos/repo", TmuxSession: "repo"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agentRepo := db.NewAgentRepository(database)
+	agent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "repo:0.1", State: models.AgentStateIdle}
+	if err := agentRepo.Create(ctx, agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	if err := agentRepo.Delete(ctx, agent.ID); err != nil {
+		t.Fatalf("failed to terminate agent: %v", err)
+	}
+
+	fake := clock.NewFake(time.Now())
+	pruner := NewAgentPruner(database, 24*time.Hour, zerolog.Nop(), WithAgentPruneClock(fake))
+	if pruned, err := pruner.Prune(ctx); err != nil || pruned != 0 {
+		t.Fatalf("expected a fresh termination kept, pruned %d (%v)", pruned, err)
+	}
+
+	// Unlimited retention never prunes.
+	fake.Advance(48 * time.Hour)
+	cfg := config.DefaultConfig()
+	cfg.AgentRetention.MaxAge = 0
+	if err := pruner.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if pruned, err := pruner.Prune(ctx); err != nil || pruned != 0 {
+		t.Fatalf("expected nothing pruned without a max age, pruned %d (%v)", pruned, err)
+	}
+
+	cfg.AgentRetention.MaxAge = 24 * time.Hour
+	if err := pruner.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if pruned, err := pruner.Prune(ctx); err != nil || pruned != 1 {
+		t.Fatalf("expected the terminated agent pruned, pruned %d (%v)", pruned, err)
+	}
+	if _, err := agentRepo.Get(ctx, agent.ID, db.IncludeDeleted()); err != db.ErrAgentNotFound {
+		t.Fatalf("expected the agent row deleted, got %v", err)
+	}
+}
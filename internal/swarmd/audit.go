@@ -0,0 +1,149 @@
+package swarmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/redact"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// auditedMethods maps the mutating RPCs to the audit operation they are
+// recorded as and the parameters taken from their request. A params func
+// returning false leaves the call unrecorded, as for dry runs.
+var auditedMethods = map[string]struct {
+	operation string
+	params    func(req any) (map[string]string, bool)
+}{
+	swarmdv1.SwarmdService_SpawnAgent_FullMethodName: {"spawn_agent", func(req any) (map[string]string, bool) {
+		r := req.(*swarmdv1.SpawnAgentRequest)
+		// Env values are credentials more often than not; only the
+		// names are recorded.
+		envNames := make([]string, 0, len(r.GetEnv()))
+		for name := range r.GetEnv() {
+			envNames = append(envNames, name)
+		}
+		sort.Strings(envNames)
+		return audit.Params(
+			"workspace_id", r.GetWorkspaceId(),
+			"command", redactAssignments(r.GetCommand()),
+			"args", strings.Join(r.GetArgs(), " "),
+			"env", strings.Join(envNames, ","),
+			"adapter", r.GetAdapter(),
+			"pane_id", r.GetPaneId(),
+		), true
+	}},
+	swarmdv1.SwarmdService_KillAgent_FullMethodName: {"kill_agent", func(req any) (map[string]string, bool) {
+		r := req.(*swarmdv1.KillAgentRequest)
+		return audit.Params("force", strconv.FormatBool(r.GetForce())), true
+	}},
+	swarmdv1.SwarmdService_SendInput_FullMethodName: {"send_input", func(req any) (map[string]string, bool) {
+		r := req.(*swarmdv1.SendInputRequest)
+		text := r.GetText()
+		if r.GetSensitive() && text != "" {
+			text = "[sensitive input]"
+		}
+		return audit.Params("text", text, "keys", strings.Join(r.GetKeys(), " ")), true
+	}},
+	swarmdv1.SwarmdService_ResizePane_FullMethodName: {"resize_pane", func(req any) (map[string]string, bool) {
+		r := req.(*swarmdv1.ResizePaneRequest)
+		return audit.Params("width", strconv.Itoa(int(r.GetWidth())), "height", strconv.Itoa(int(r.GetHeight()))), true
+	}},
+	swarmdv1.SwarmdService_SetPaneLayout_FullMethodName: {"set_pane_layout", func(req any) (map[string]string, bool) {
+		r := req.(*swarmdv1.SetPaneLayoutRequest)
+		return audit.Params("session", r.GetSession(), "layout", r.GetLayout()), true
+	}},
+	swarmdv1.SwarmdService_CompactTranscripts_FullMethodName: {"compact_transcripts", func(req any) (map[string]string, bool) {
+		r := req.(*swarmdv1.CompactTranscriptsRequest)
+		var olderThan string
+		if r.GetOlderThan() != nil {
+			olderThan = r.GetOlderThan().AsDuration().String()
+		}
+		return audit.Params("older_than", olderThan), !r.GetDryRun()
+	}},
+	swarmdv1.SwarmdService_ReloadConfig_FullMethodName: {"reload_config", func(req any) (map[string]string, bool) {
+		return nil, true
+	}},
+}
+
+// envAssignment matches a variable assignment starting a word of a command
+// line, with its value unquoted or in single or double quotes.
+var envAssignment = regexp.MustCompile(`(^|\s)([A-Za-z_][A-Za-z0-9_]*)=('[^']*'(?:\\''[^']*')*|"(?:[^"\\]|\\.)*"|\S*)`)
+
+// redactAssignments replaces the values of the variables command sets, as
+// in "KEY=value claude", which for the same reason as env are recorded by
+// name only.
+func redactAssignments(command string) string {
+	return envAssignment.ReplaceAllString(command, "${1}${2}="+redact.Marker("env"))
+}
+
+// AuditInterceptor returns a server interceptor recording every call to a
+// mutating RPC once it returns. Callers presenting authToken are recorded
+// by its fingerprint; without auth, by their address. A failure to record
+// is logged by recorder and never fails the call.
+func AuditInterceptor(recorder *audit.Recorder, authToken string) grpc.UnaryServerInterceptor {
+	var tokenActor string
+	if authToken != "" {
+		sum := sha256.Sum256([]byte(authToken))
+		tokenActor = "token:" + hex.EncodeToString(sum[:])[:12]
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		method, ok := auditedMethods[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+		params, audited := method.params(req)
+
+		resp, err := handler(ctx, req)
+		if !audited {
+			return resp, err
+		}
+
+		actor := tokenActor
+		if actor == "" || !hasBearer(ctx, authToken) {
+			actor = peerActor(ctx)
+		}
+		var entityType models.EntityType
+		entityID := agentIDOf(req)
+		if spawned, ok := resp.(*swarmdv1.SpawnAgentResponse); ok && spawned.GetAgent() != nil {
+			entityID = spawned.GetAgent().GetId()
+		}
+		if entityID != "" {
+			entityType = models.EntityTypeAgent
+		}
+		recorder.Record(audit.ContextWithActor(ctx, actor), method.operation, entityType, entityID, params, err)
+		return resp, err
+	}
+}
+
+// agentIDOf returns the agent a request targets, if it names one.
+func agentIDOf(req any) string {
+	if r, ok := req.(interface{ GetAgentId() string }); ok {
+		return r.GetAgentId()
+	}
+	return ""
+}
+
+// peerActor names an unauthenticated caller by its address.
+func peerActor(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "anonymous"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return "anonymous@" + host
+}
@@ -0,0 +1,145 @@
+package swarmd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/rs/zerolog"
+)
+
+// DefaultAuditPruneInterval is how often the audit log is pruned.
+const DefaultAuditPruneInterval = time.Hour
+
+// AuditPruner deletes audit records beyond the configured age and count,
+// as event retention does for events.
+type AuditPruner struct {
+	auditRepo *db.AuditRepository
+	clock     clock.Clock
+	logger    zerolog.Logger
+
+	// mu guards the settings Reconfigure replaces.
+	mu           sync.Mutex
+	retention    config.AuditRetentionConfig
+	reconfigured chan struct{}
+}
+
+// AuditPrunerOption configures an AuditPruner.
+type AuditPrunerOption func(*AuditPruner)
+
+// WithAuditPruneClock sets the time source retention ages are measured
+// against.
+func WithAuditPruneClock(c clock.Clock) AuditPrunerOption {
+	return func(p *AuditPruner) {
+		p.clock = c
+	}
+}
+
+// NewAuditPruner creates a pruner applying retention to the audit log.
+func NewAuditPruner(database *db.DB, retention config.AuditRetentionConfig, logger zerolog.Logger, opts ...AuditPrunerOption) *AuditPruner {
+	p := &AuditPruner{
+		auditRepo:    db.NewAuditRepository(database),
+		retention:    retention,
+		logger:       logger,
+		reconfigured: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.clock = clock.OrReal(p.clock)
+	return p
+}
+
+// Reconfigure applies the audit retention settings of cfg. A running loop
+// prunes again at once and then every new interval.
+func (p *AuditPruner) Reconfigure(cfg *config.Config) error {
+	p.mu.Lock()
+	p.retention = cfg.AuditRetention
+	p.mu.Unlock()
+
+	select {
+	case p.reconfigured <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (p *AuditPruner) settings() config.AuditRetentionConfig {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.retention
+}
+
+func (p *AuditPruner) interval() time.Duration {
+	if interval := p.settings().CleanupInterval; interval > 0 {
+		return interval
+	}
+	return DefaultAuditPruneInterval
+}
+
+// Run prunes immediately and then every interval until ctx is canceled.
+func (p *AuditPruner) Run(ctx context.Context) {
+	ticker := p.clock.NewTicker(p.interval())
+	defer func() { ticker.Stop() }()
+
+	for {
+		if pruned, err := p.Prune(ctx); err != nil {
+			p.logger.Warn().Ctx(ctx).Err(err).Msg("audit log pruning failed")
+		} else if pruned > 0 {
+			p.logger.Info().Ctx(ctx).Int64("pruned", pruned).Msg("pruned audit records")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case <-p.reconfigured:
+			ticker.Stop()
+			ticker = p.clock.NewTicker(p.interval())
+		}
+	}
+}
+
+// Prune deletes the audit records older than the retention age, then the
+// oldest beyond the retention count, and returns how many were removed.
+// It does nothing while retention is disabled.
+func (p *AuditPruner) Prune(ctx context.Context) (int64, error) {
+	retention := p.settings()
+	if !retention.Enabled {
+		return 0, nil
+	}
+	batch := retention.BatchSize
+	if batch <= 0 {
+		batch = 1000
+	}
+
+	var pruned int64
+	if retention.MaxAge > 0 {
+		cutoff := p.clock.Now().Add(-retention.MaxAge)
+		for {
+			n, err := p.auditRepo.DeleteOlderThan(ctx, cutoff, batch)
+			pruned += n
+			if err != nil {
+				return pruned, err
+			}
+			if n < int64(batch) {
+				break
+			}
+		}
+	}
+	if retention.MaxCount > 0 {
+		for {
+			n, err := p.auditRepo.DeleteExcess(ctx, retention.MaxCount, batch)
+			pruned += n
+			if err != nil {
+				return pruned, err
+			}
+			if n < int64(batch) {
+				break
+			}
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,174 @@
+package swarmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+)
+
+func TestAuditInterceptorRecordsMutatingRPCs(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	srv := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	authUnary, _ := AuthInterceptors("secret")
+	d := startBufDaemon(t, NewServer(zerolog.Nop(), WithTmuxClient(tmux.NewClient(srv))),
+		grpc.ChainUnaryInterceptor(authUnary, AuditInterceptor(audit.New(database), "secret")))
+	client := d.dial(t, WithAuthToken("secret"))
+
+	key := "sk-ant-REDACTED"
+	if _, err := client.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{
+		AgentId:     "agent-1",
+		WorkspaceId: "ws-1",
+		Command:     "claude",
+		Env:         map[string]string{"ANTHROPIC_API_KEY": key},
+	}); err != nil {
+		t.Fatalf("SpawnAgent() error = %v", err)
+	}
+	if _, err := client.SendInput(ctx, &swarmdv1.SendInputRequest{AgentId: "agent-1", Text: "use " + key}); err != nil {
+		t.Fatalf("SendInput() error = %v", err)
+	}
+	if _, err := client.SendInput(ctx, &swarmdv1.SendInputRequest{AgentId: "agent-1", Text: "hunter2", Sensitive: true}); err != nil {
+		t.Fatalf("SendInput() error = %v", err)
+	}
+	if _, err := client.GetAgent(ctx, &swarmdv1.GetAgentRequest{AgentId: "agent-1"}); err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if _, err := client.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: "agent-1"}); err != nil {
+		t.Fatalf("KillAgent() error = %v", err)
+	}
+	if _, err := client.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: "agent-1"}); err == nil {
+		t.Fatal("expected killing a killed agent to fail")
+	}
+
+	records, err := db.NewAuditRepository(database).Query(ctx, db.AuditQuery{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	want := []string{"spawn_agent", "send_input", "send_input", "kill_agent", "kill_agent"}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d audit records, got %d: %+v", len(want), len(records), records)
+	}
+	for i, op := range want {
+		record := records[len(records)-1-i]
+		if record.Operation != op || record.EntityID != "agent-1" || !strings.HasPrefix(record.Actor, "token:") {
+			t.Errorf("record %d: got %s on %s by %s, want %s on agent-1", i, record.Operation, record.EntityID, record.Actor, op)
+		}
+		if strings.Contains(record.Actor, "secret") {
+			t.Errorf("record %d: actor leaks the auth token: %s", i, record.Actor)
+		}
+		for name, v := range record.Parameters {
+			if strings.Contains(v, key) || strings.Contains(v, "hunter2") {
+				t.Errorf("record %d: parameter %s not redacted: %q", i, name, v)
+			}
+		}
+	}
+	if spawn := records[len(records)-1]; spawn.Parameters["env"] != "ANTHROPIC_API_KEY" {
+		t.Errorf("expected env names recorded, got %+v", spawn.Parameters)
+	}
+	if failed := records[0]; failed.Result != models.AuditResultFailure || failed.Error == "" {
+		t.Errorf("expected the failed kill recorded as a failure, got %+v", failed)
+	}
+}
+
+func TestAuditSpawnAgentRedactsCommandAssignments(t *testing.T) {
+	spawn := auditedMethods[swarmdv1.SwarmdService_SpawnAgent_FullMethodName]
+	params, _ := spawn.params(&swarmdv1.SpawnAgentRequest{
+		Command: `DEPLOY_TOKEN='hunter2'\''s' REGION=eu NOTE="a \"b\"" claude --model=opus`,
+	})
+
+	command := params["command"]
+	for _, secret := range []string{"hunter2", "eu", `a \"b`} {
+		if strings.Contains(command, secret) {
+			t.Errorf("expected %q redacted from the recorded command, got %q", secret, command)
+		}
+	}
+	for _, kept := range []string{"DEPLOY_TOKEN=", "REGION=", "NOTE=", "claude --model=opus"} {
+		if !strings.Contains(command, kept) {
+			t.Errorf("expected %q kept in the recorded command, got %q", kept, command)
+		}
+	}
+}
+
+func TestAuditInterceptorIgnoresWriterFailure(t *testing.T) {
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	recorder := audit.New(database)
+	database.Close()
+
+	interceptor := AuditInterceptor(recorder, "")
+	want := &swarmdv1.KillAgentResponse{Success: true}
+	resp, err := interceptor(context.Background(), &swarmdv1.KillAgentRequest{AgentId: "agent-1"},
+		&grpc.UnaryServerInfo{FullMethod: swarmdv1.SwarmdService_KillAgent_FullMethodName},
+		func(ctx context.Context, req any) (any, error) { return want, nil })
+	if err != nil || resp != want {
+		t.Fatalf("expected the call's result despite the broken audit log, got %v, %v", resp, err)
+	}
+}
+
+func TestAuditPrunerPrune(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	now := time.Now().UTC()
+	repo := db.NewAuditRepository(database)
+	for _, age := range []time.Duration{100 * 24 * time.Hour, 10 * 24 * time.Hour, 2 * 24 * time.Hour, time.Hour} {
+		record := &models.AuditRecord{Timestamp: now.Add(-age), Actor: "dana", Operation: "kill_agent", Result: models.AuditResultSuccess}
+		if err := repo.Create(ctx, record); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	pruner := NewAuditPruner(database, cfg.AuditRetention, zerolog.Nop(), WithAuditPruneClock(clock.NewFake(now)))
+	if pruned, err := pruner.Prune(ctx); err != nil || pruned != 1 {
+		t.Fatalf("expected the record past 90 days pruned, pruned %d (%v)", pruned, err)
+	}
+
+	cfg.AuditRetention.MaxCount = 2
+	if err := pruner.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if pruned, err := pruner.Prune(ctx); err != nil || pruned != 1 {
+		t.Fatalf("expected the oldest record beyond the count pruned, pruned %d (%v)", pruned, err)
+	}
+
+	cfg.AuditRetention.Enabled = false
+	cfg.AuditRetention.MaxAge = time.Minute
+	if err := pruner.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if pruned, err := pruner.Prune(ctx); err != nil || pruned != 0 {
+		t.Fatalf("expected nothing pruned while disabled, pruned %d (%v)", pruned, err)
+	}
+}
@@ -0,0 +1,70 @@
+package swarmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthTokenEnv is the environment variable holding the token swarmd
+// requires from every caller. Authentication is disabled when it is unset.
+const AuthTokenEnv = "SWARMD_AUTH_TOKEN"
+
+// WithAuthToken makes the client send token as a bearer token on every
+// call, for daemons started with SWARMD_AUTH_TOKEN.
+func WithAuthToken(token string) ClientOption {
+	return func(c *clientConfig) {
+		if token == "" {
+			return
+		}
+		c.dialOpts = append(c.dialOpts,
+			grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				return invoker(withBearer(ctx, token), method, req, reply, cc, opts...)
+			}),
+			grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return streamer(withBearer(ctx, token), desc, cc, method, opts...)
+			}),
+		)
+	}
+}
+
+func withBearer(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, authKey, "Bearer "+token)
+}
+
+// hasBearer reports whether the incoming call carries token. A call may
+// carry several bearer tokens, such as the auth and debug tokens together.
+func hasBearer(ctx context.Context, token string) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(authKey) {
+		presented, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthInterceptors return server interceptors that reject calls not
+// presenting token.
+func AuthInterceptors(token string) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unauthenticated := status.Error(codes.Unauthenticated, "missing or invalid auth token")
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !hasBearer(ctx, token) {
+			return nil, unauthenticated
+		}
+		return handler(ctx, req)
+	}
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !hasBearer(ss.Context(), token) {
+			return unauthenticated
+		}
+		return handler(srv, ss)
+	}
+	return unary, stream
+}
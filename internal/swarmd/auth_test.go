@@ -0,0 +1,165 @@
+package swarmd
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// bufDaemon serves a Server over an in-memory listener that can be stopped
+// and started again, as a daemon restart would.
+type bufDaemon struct {
+	server *Server
+	opts   []grpc.ServerOption
+
+	mu   sync.Mutex
+	lis  *bufconn.Listener
+	grpc *grpc.Server
+}
+
+func startBufDaemon(t *testing.T, server *Server, opts ...grpc.ServerOption) *bufDaemon {
+	t.Helper()
+	d := &bufDaemon{server: server, opts: opts}
+	d.start()
+	t.Cleanup(d.stop)
+	return d
+}
+
+func (d *bufDaemon) start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lis = bufconn.Listen(1 << 20)
+	d.grpc = grpc.NewServer(d.opts...)
+	swarmdv1.RegisterSwarmdServiceServer(d.grpc, d.server)
+	go func(srv *grpc.Server, lis net.Listener) { _ = srv.Serve(lis) }(d.grpc, d.lis)
+}
+
+func (d *bufDaemon) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.grpc.Stop()
+}
+
+func (d *bufDaemon) dial(t *testing.T, opts ...ClientOption) *Client {
+	t.Helper()
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		d.mu.Lock()
+		lis := d.lis
+		d.mu.Unlock()
+		return lis.DialContext(ctx)
+	}
+	client, err := Dial(context.Background(), "bufnet", append(opts, WithDialOptions(grpc.WithContextDialer(dialer)))...)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestAuthTokenRequired(t *testing.T) {
+	unary, stream := AuthInterceptors("secret")
+	d := startBufDaemon(t, NewServer(zerolog.Nop(), WithDebugToken("dbg")),
+		grpc.ChainUnaryInterceptor(unary), grpc.ChainStreamInterceptor(stream))
+	ctx := context.Background()
+
+	for name, client := range map[string]*Client{
+		"no token":    d.dial(t),
+		"wrong token": d.dial(t, WithAuthToken("nope")),
+	} {
+		if _, err := client.Ping(ctx); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: expected Unauthenticated ping, got %v", name, err)
+		}
+		events, err := client.StreamEvents(ctx, &swarmdv1.StreamEventsRequest{})
+		if err == nil {
+			_, err = events.Recv()
+		}
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: expected Unauthenticated stream, got %v", name, err)
+		}
+	}
+
+	client := d.dial(t, WithAuthToken("secret"))
+	if _, err := client.Ping(ctx); err != nil {
+		t.Fatalf("expected ping with the auth token to pass, got %v", err)
+	}
+	if _, err := client.DumpState(ctx, "dbg"); err != nil {
+		t.Fatalf("expected DumpState with both tokens to pass, got %v", err)
+	}
+}
+
+func TestFollowPaneUpdatesResumesAfterRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	server := NewServer(zerolog.Nop(), WithTmuxClient(tmux.NewClient(srv)))
+	d := startBufDaemon(t, server)
+	client := d.dial(t)
+
+	spawned, err := client.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-1", WorkspaceId: "ws-1", Command: "claude"})
+	if err != nil {
+		t.Fatalf("SpawnAgent() error = %v", err)
+	}
+
+	updates := make(chan *swarmdv1.StreamPaneUpdatesResponse, 8)
+	done := make(chan error, 1)
+	go func() {
+		done <- client.FollowPaneUpdates(ctx, &swarmdv1.StreamPaneUpdatesRequest{
+			AgentId:        "agent-1",
+			IncludeContent: true,
+			MinInterval:    durationpb.New(5 * time.Millisecond),
+		}, func(update *swarmdv1.StreamPaneUpdatesResponse) error {
+			updates <- update
+			return nil
+		})
+	}()
+
+	next := func() *swarmdv1.StreamPaneUpdatesResponse {
+		t.Helper()
+		select {
+		case update := <-updates:
+			return update
+		case err := <-done:
+			t.Fatalf("FollowPaneUpdates returned early: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for a pane update")
+		}
+		return nil
+	}
+
+	first := next()
+	if !strings.Contains(first.Content, "claude> ") {
+		t.Fatalf("unexpected first update %q", first.Content)
+	}
+
+	d.stop()
+	d.start()
+	if err := srv.Print(spawned.PaneId, "resumed\n"); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	second := next()
+	if !strings.Contains(second.Content, "resumed") {
+		t.Fatalf("expected the resumed stream to skip the unchanged pane, got %q", second.Content)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected FollowPaneUpdates to end with the context, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package swarmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExecutionBackend runs agent processes for the server. Each agent lives in
+// a pane, named by the ID Start returns; the tmux backend's are tmux panes,
+// the runner backend's are PTYs the daemon holds itself.
+type ExecutionBackend interface {
+	// Name identifies the backend in health checks and logs.
+	Name() string
+
+	// Start runs cmdLine for the agent described by req and returns the
+	// ID of its pane. Errors are gRPC status errors.
+	Start(ctx context.Context, req *swarmdv1.SpawnAgentRequest, cmdLine string) (string, error)
+
+	// PaneExists reports whether a pane the backend did not start exists,
+	// so it can be adopted with attach_existing_pane.
+	PaneExists(ctx context.Context, paneID string) (bool, error)
+
+	// PanePID returns the ID of the process running in a pane.
+	PanePID(ctx context.Context, paneID string) (int, error)
+
+	// SendKeys types keys into a pane: literally, or as key names such as
+	// "Enter" or "C-c", followed by Enter when enter is set.
+	SendKeys(ctx context.Context, paneID, keys string, literal, enter bool) error
+
+	// SendInterrupt sends a pane Ctrl+C.
+	SendInterrupt(ctx context.Context, paneID string) error
+
+	// CapturePane returns a pane's visible content, or all of its
+	// scrollback when history is set.
+	CapturePane(ctx context.Context, paneID string, history bool) (string, error)
+
+	// CapturePaneRange returns a pane's last lines and whether older ones
+	// were left out.
+	CapturePaneRange(ctx context.Context, paneID string, lines int) (string, bool, error)
+
+	// KillPane stops a pane's process and waits for it to be gone. A pane
+	// that is already gone is not an error.
+	KillPane(ctx context.Context, paneID string, force bool) error
+
+	// ResizePane sets a pane's size in cells, keeping a dimension given as
+	// zero, and returns the size the pane ended up with.
+	ResizePane(ctx context.Context, paneID string, width, height int) (int, int, error)
+
+	// SelectLayout arranges the panes of a session's current window.
+	SelectLayout(ctx context.Context, session, layout string) error
+
+	// Check reports whether the backend can run agents.
+	Check(ctx context.Context) error
+}
+
+// NewExecutionBackend returns the backend named by daemon.execution_backend.
+// auto is the runner backend on Windows, which has no tmux, and tmux
+// elsewhere.
+func NewExecutionBackend(name string, logger zerolog.Logger) (ExecutionBackend, error) {
+	switch ResolveExecutionBackend(name) {
+	case config.ExecutionBackendTmux:
+		return newTmuxBackend(tmux.NewLocalClient(), logger), nil
+	case config.ExecutionBackendRunner:
+		return newRunnerBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown execution backend %q", name)
+	}
+}
+
+// ResolveExecutionBackend returns the backend auto stands for on this
+// platform; other names are returned unchanged.
+func ResolveExecutionBackend(name string) string {
+	if name == "" || name == config.ExecutionBackendAuto {
+		if runtime.GOOS == "windows" {
+			return config.ExecutionBackendRunner
+		}
+		return config.ExecutionBackendTmux
+	}
+	return name
+}
+
+// tmuxBackend runs agents in panes of the workspace's tmux session.
+type tmuxBackend struct {
+	client *tmux.Client
+	logger zerolog.Logger
+
+	// sessionMu serializes creating tmux sessions, so concurrent spawns
+	// into one workspace do not both try to create its session.
+	sessionMu sync.Mutex
+}
+
+func newTmuxBackend(client *tmux.Client, logger zerolog.Logger) *tmuxBackend {
+	return &tmuxBackend{client: client, logger: logger}
+}
+
+func (b *tmuxBackend) Name() string { return config.ExecutionBackendTmux }
+
+// Start splits a new pane into the agent's session and runs the command
+// line in it, returning the pane ID.
+func (b *tmuxBackend) Start(ctx context.Context, req *swarmdv1.SpawnAgentRequest, cmdLine string) (string, error) {
+	// Determine session and window names
+	sessionName := req.SessionName
+	if sessionName == "" {
+		sessionName = tmux.SessionName(tmux.DefaultSessionPrefix, "", req.WorkspaceId)
+	}
+
+	workDir := req.WorkingDir
+	if workDir == "" {
+		workDir, _ = os.Getwd()
+	}
+
+	if err := b.ensureSession(ctx, sessionName, workDir); err != nil {
+		return "", err
+	}
+
+	// Create a new pane by splitting the window
+	paneID, err := b.client.SplitWindow(ctx, sessionName, true, workDir)
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "failed to create pane: %v", err)
+	}
+
+	if req.PaneWidth > 0 || req.PaneHeight > 0 {
+		if err := b.client.ResizePane(ctx, paneID, int(req.PaneWidth), int(req.PaneHeight)); err != nil {
+			b.logger.Warn().Ctx(ctx).Err(err).Str("pane", paneID).Msg("failed to set initial pane size")
+		}
+	}
+
+	// Set environment variables and run the command
+	for k, v := range req.Env {
+		envCmd := fmt.Sprintf("export %s=%q", k, v)
+		if err := b.client.SendKeys(ctx, paneID, envCmd, true, true); err != nil {
+			b.logger.Warn().Ctx(ctx).Err(err).Str("pane", paneID).Msg("failed to set env var")
+		}
+	}
+
+	// Send the command to the pane
+	if err := b.client.SendKeys(ctx, paneID, cmdLine, true, true); err != nil {
+		// Try to clean up the pane
+		_ = b.client.KillPane(ctx, paneID)
+		return "", status.Errorf(codes.Internal, "failed to send command: %v", err)
+	}
+
+	// Give the process a moment to start before its PID is read
+	time.Sleep(100 * time.Millisecond)
+	return paneID, nil
+}
+
+// ensureSession creates the tmux session unless it already exists.
+func (b *tmuxBackend) ensureSession(ctx context.Context, sessionName, workDir string) error {
+	b.sessionMu.Lock()
+	defer b.sessionMu.Unlock()
+
+	hasSession, err := b.client.HasSession(ctx, sessionName)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check session: %v", err)
+	}
+	if !hasSession {
+		if err := b.client.NewSession(ctx, sessionName, workDir); err != nil {
+			return status.Errorf(codes.Internal, "failed to create session: %v", err)
+		}
+	}
+	return nil
+}
+
+func (b *tmuxBackend) PaneExists(ctx context.Context, paneID string) (bool, error) {
+	return b.client.PaneExists(ctx, paneID)
+}
+
+func (b *tmuxBackend) PanePID(ctx context.Context, paneID string) (int, error) {
+	return b.client.GetPanePID(ctx, paneID)
+}
+
+func (b *tmuxBackend) SendKeys(ctx context.Context, paneID, keys string, literal, enter bool) error {
+	return b.client.SendKeys(ctx, paneID, keys, literal, enter)
+}
+
+func (b *tmuxBackend) SendInterrupt(ctx context.Context, paneID string) error {
+	return b.client.SendInterrupt(ctx, paneID)
+}
+
+func (b *tmuxBackend) CapturePane(ctx context.Context, paneID string, history bool) (string, error) {
+	return b.client.CapturePane(ctx, paneID, history)
+}
+
+func (b *tmuxBackend) CapturePaneRange(ctx context.Context, paneID string, lines int) (string, bool, error) {
+	return b.client.CapturePaneRange(ctx, paneID, lines)
+}
+
+func (b *tmuxBackend) KillPane(ctx context.Context, paneID string, force bool) error {
+	return b.client.KillPaneVerified(ctx, paneID, force)
+}
+
+func (b *tmuxBackend) ResizePane(ctx context.Context, paneID string, width, height int) (int, int, error) {
+	if err := b.client.ResizePane(ctx, paneID, width, height); err != nil {
+		return 0, 0, err
+	}
+	return b.client.PaneSize(ctx, paneID)
+}
+
+func (b *tmuxBackend) SelectLayout(ctx context.Context, session, layout string) error {
+	return b.client.SelectLayout(ctx, session, layout)
+}
+
+// Check checks tmux answers.
+func (b *tmuxBackend) Check(ctx context.Context) error {
+	_, err := b.client.ListSessions(ctx)
+	return err
+}
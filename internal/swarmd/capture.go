@@ -0,0 +1,357 @@
+package swarmd
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/redact"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+// DefaultMaxPollInterval is the longest interval an unchanged pane backs
+// off to unless WithMaxPollInterval says otherwise.
+const DefaultMaxPollInterval = 5 * time.Second
+
+// paneSnapshot is one capture of an agent's pane, shared by every stream
+// watching the agent.
+type paneSnapshot struct {
+	content    string
+	hash       string
+	state      swarmdv1.AgentState
+	detector   string // name of the detector that read state
+	capturedAt time.Time
+	mono       time.Duration // monotonic capture time, for throttling
+	interval   time.Duration // polling interval the change was seen at
+}
+
+// captureLoop polls one agent's pane on behalf of all of its subscribers.
+// It owns transcript recording for the agent's output, so each content
+// change is recorded once and tmux is queried once per interval no matter
+// how many streams are open.
+//
+// The interval adapts to the pane: it doubles with every capture that
+// finds the content unchanged, up to the subscribers' ceiling, and drops
+// back to their floor when the content changes or input is sent.
+type captureLoop struct {
+	agentID string
+	cancel  context.CancelFunc
+	poke    chan struct{} // input was sent to the agent
+
+	// Guarded by Server.captureMu.
+	subs     map[*captureSub]struct{}
+	latest   *paneSnapshot
+	interval time.Duration // current polling interval; 0 is the floor
+}
+
+// captureSub receives a capture loop's snapshots. updates holds only the
+// newest snapshot, so a slow stream skips intermediate content instead of
+// blocking the loop. It is closed when the agent goes away.
+type captureSub struct {
+	loop        *captureLoop
+	interval    time.Duration
+	maxInterval time.Duration // 0 uses the server's maximum
+	updates     chan paneSnapshot
+	wake        chan struct{} // signaled after updates is written or closed; may be nil
+}
+
+// notify signals sub's wake channel, for streams reading several
+// subscriptions.
+func (sub *captureSub) notify() {
+	if sub.wake == nil {
+		return
+	}
+	select {
+	case sub.wake <- struct{}{}:
+	default:
+	}
+}
+
+// WithMaxPollInterval sets the longest interval an unchanged pane is
+// polled at when a stream does not ask for its own. Zero disables the
+// backoff.
+func WithMaxPollInterval(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.maxPollInterval.Store(int64(d))
+	}
+}
+
+// subscribeCapture attaches to the agent's capture loop, starting it for
+// the first subscriber. A loop that has already captured delivers its
+// latest snapshot right away.
+func (s *Server) subscribeCapture(agentID string, interval, maxInterval time.Duration) *captureSub {
+	return s.subscribeCaptureWake(agentID, interval, maxInterval, nil)
+}
+
+// subscribeCaptureWake is subscribeCapture for a subscriber that also
+// wants wake signaled at each snapshot and when the agent goes away.
+func (s *Server) subscribeCaptureWake(agentID string, interval, maxInterval time.Duration, wake chan struct{}) *captureSub {
+	s.captureMu.Lock()
+	defer s.captureMu.Unlock()
+
+	loop, ok := s.captures[agentID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		loop = &captureLoop{
+			agentID: agentID,
+			cancel:  cancel,
+			poke:    make(chan struct{}, 1),
+			subs:    make(map[*captureSub]struct{}),
+		}
+		s.captures[agentID] = loop
+		go s.runCapture(ctx, loop)
+	}
+
+	sub := &captureSub{loop: loop, interval: interval, maxInterval: maxInterval, updates: make(chan paneSnapshot, 1), wake: wake}
+	loop.subs[sub] = struct{}{}
+	if loop.latest != nil {
+		sub.updates <- *loop.latest
+		sub.notify()
+	}
+	return sub
+}
+
+// unsubscribeCapture detaches sub, stopping the loop when it was the last
+// subscriber.
+func (s *Server) unsubscribeCapture(sub *captureSub) {
+	s.captureMu.Lock()
+	defer s.captureMu.Unlock()
+
+	loop := sub.loop
+	if _, ok := loop.subs[sub]; !ok {
+		return // already closed by the loop
+	}
+	delete(loop.subs, sub)
+	if len(loop.subs) == 0 {
+		loop.cancel()
+		if s.captures[loop.agentID] == loop {
+			delete(s.captures, loop.agentID)
+		}
+	}
+}
+
+// stopCapture ends an agent's capture loop, closing its subscribers. It
+// does not wait for the loop to exit, so callers may hold the agent's lock.
+func (s *Server) stopCapture(agentID string) {
+	s.captureMu.Lock()
+	defer s.captureMu.Unlock()
+
+	if loop, ok := s.captures[agentID]; ok {
+		loop.cancel()
+		delete(s.captures, agentID)
+	}
+}
+
+// runCapture captures immediately and then once per interval until the
+// loop is cancelled or the agent no longer exists. Input sent to the agent
+// moves the next capture up to the floor interval.
+func (s *Server) runCapture(ctx context.Context, loop *captureLoop) {
+	defer s.closeCapture(loop)
+
+	timer := s.clock.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-loop.poke:
+			if !timer.Stop() {
+				select {
+				case <-timer.C():
+				default:
+				}
+			}
+			timer.Reset(s.captureInterval(loop))
+			continue
+		case <-timer.C():
+		}
+		if !s.captureOnce(ctx, loop) {
+			return
+		}
+		timer.Reset(s.captureInterval(loop))
+	}
+}
+
+// captureInterval is the loop's current interval, kept within its
+// subscribers' bounds.
+func (s *Server) captureInterval(loop *captureLoop) time.Duration {
+	s.captureMu.Lock()
+	defer s.captureMu.Unlock()
+	return s.captureIntervalLocked(loop)
+}
+
+func (s *Server) captureIntervalLocked(loop *captureLoop) time.Duration {
+	floor, ceiling := s.captureBoundsLocked(loop)
+	return min(max(loop.interval, floor), ceiling)
+}
+
+// captureBoundsLocked returns the shortest interval any subscriber asked
+// for and the longest the loop may back off to, which is the smallest
+// maximum of any subscriber and never below the floor. The caller must
+// hold captureMu.
+func (s *Server) captureBoundsLocked(loop *captureLoop) (floor, ceiling time.Duration) {
+	serverMax := time.Duration(s.maxPollInterval.Load())
+	for sub := range loop.subs {
+		if floor == 0 || sub.interval < floor {
+			floor = sub.interval
+		}
+		subMax := sub.maxInterval
+		if subMax <= 0 {
+			subMax = serverMax
+		}
+		if ceiling == 0 || subMax < ceiling {
+			ceiling = subMax
+		}
+	}
+	if floor <= 0 {
+		floor = defaultPollInterval
+	}
+	return floor, max(ceiling, floor)
+}
+
+// pokeCapture drops the agent's capture loop back to its floor interval,
+// since input usually changes the pane.
+func (s *Server) pokeCapture(agentID string) {
+	s.captureMu.Lock()
+	loop, ok := s.captures[agentID]
+	if ok {
+		loop.interval = 0
+	}
+	s.captureMu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case loop.poke <- struct{}{}:
+	default:
+	}
+}
+
+// closeCapture closes the loop's remaining subscribers, which tells their
+// streams the agent is gone.
+func (s *Server) closeCapture(loop *captureLoop) {
+	s.captureMu.Lock()
+	defer s.captureMu.Unlock()
+
+	for sub := range loop.subs {
+		close(sub.updates)
+		sub.notify()
+		delete(loop.subs, sub)
+	}
+	if s.captures[loop.agentID] == loop {
+		delete(s.captures, loop.agentID)
+	}
+}
+
+// captureOnce captures the agent's pane, records changes, and publishes
+// the snapshot. It returns false when the loop should stop.
+func (s *Server) captureOnce(ctx context.Context, loop *captureLoop) bool {
+	info, exists := s.lookupAgent(loop.agentID)
+	if !exists {
+		return false
+	}
+
+	content, err := s.backend.CapturePane(ctx, info.paneID, false)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", loop.agentID).Msg("failed to capture pane")
+		return true
+	}
+
+	state, detector := s.detectAgentState(content, info.adapter)
+	snap := paneSnapshot{
+		content:    content,
+		hash:       tmux.HashSnapshot(content),
+		state:      state,
+		detector:   detector,
+		capturedAt: s.clock.Now(),
+		mono:       s.clock.Monotonic(),
+	}
+	s.recordSnapshot(loop.agentID, snap)
+	s.publishSnapshot(loop, snap)
+	return true
+}
+
+// recordSnapshot updates the agent from a capture and, when the content
+// differs from the last recorded output, appends it to the transcript.
+func (s *Server) recordSnapshot(agentID string, snap paneSnapshot) {
+	var prevState swarmdv1.AgentState
+	var stateChanged, recorded bool
+	var workspaceID string
+
+	if agent, ok := s.lockAgent(agentID); ok {
+		agent.contentHash = snap.hash
+		workspaceID = agent.workspaceID
+
+		// Output that may show a sensitive input is left out.
+		if snap.hash != agent.recordedHash && !s.sensitiveOutputLocked(agent, snap) {
+			recorded = true
+			agent.recordedHash = snap.hash
+			agent.lastActive = s.clock.Now()
+
+			// Record content change in transcript (truncate if very long).
+			// Redact before truncating so secrets on the boundary are still matched.
+			outputContent := redact.TruncateTail(s.currentRedactor().Redact(snap.content), maxTranscriptOutputBytes)
+			metadata := map[string]string{"content_hash": snap.hash}
+			if agent.sensitiveSkipped > 0 {
+				metadata["sensitive_gap"] = "true"
+				metadata["sensitive_skipped"] = strconv.Itoa(agent.sensitiveSkipped)
+				agent.sensitiveSkipped = 0
+			}
+			s.addTranscriptEntryLocked(agent, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, outputContent, metadata)
+
+			if snap.state != swarmdv1.AgentState_AGENT_STATE_UNSPECIFIED {
+				// Record state change if different
+				if agent.state != snap.state {
+					prevState = agent.state
+					stateChanged = true
+					s.addTranscriptEntryLocked(agent, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE, snap.state.String(), map[string]string{
+						"previous": agent.state.String(),
+					})
+				}
+				agent.state = snap.state
+				if stateChanged {
+					agent.notifyStateLocked()
+				}
+			}
+		}
+		agent.mu.Unlock()
+	}
+
+	// Publish events outside lock
+	if stateChanged {
+		go s.publishAgentStateChanged(agentID, workspaceID, prevState, snap.state, "state detected from pane content")
+	}
+	if recorded {
+		go s.publishPaneContentChanged(agentID, workspaceID, snap.hash, int32(len(splitLines(snap.content))))
+	}
+}
+
+// publishSnapshot hands a changed snapshot to every subscriber, replacing
+// any snapshot it has not read yet, and adapts the loop's interval: an
+// unchanged pane doubles it, a change resets it to the floor.
+func (s *Server) publishSnapshot(loop *captureLoop, snap paneSnapshot) {
+	s.captureMu.Lock()
+	defer s.captureMu.Unlock()
+
+	snap.interval = s.captureIntervalLocked(loop)
+	if loop.latest != nil && loop.latest.hash == snap.hash {
+		_, ceiling := s.captureBoundsLocked(loop)
+		loop.interval = min(snap.interval*2, ceiling)
+		return
+	}
+	loop.interval = 0
+	loop.latest = &snap
+	for sub := range loop.subs {
+		select {
+		case <-sub.updates:
+		default:
+		}
+		sub.updates <- snap
+		sub.notify()
+	}
+}
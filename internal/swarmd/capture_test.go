@@ -0,0 +1,344 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// paneUpdateChannel is a pane update stream that hands each response to
+// the test as it is sent, for streams running concurrently.
+type paneUpdateChannel struct {
+	ctx     context.Context
+	updates chan *swarmdv1.StreamPaneUpdatesResponse
+}
+
+func newPaneUpdateChannel(ctx context.Context) *paneUpdateChannel {
+	return &paneUpdateChannel{ctx: ctx, updates: make(chan *swarmdv1.StreamPaneUpdatesResponse, 8)}
+}
+
+func (s *paneUpdateChannel) Send(resp *swarmdv1.StreamPaneUpdatesResponse) error {
+	s.updates <- resp
+	return nil
+}
+
+func (s *paneUpdateChannel) SetHeader(metadata.MD) error  { return nil }
+func (s *paneUpdateChannel) SendHeader(metadata.MD) error { return nil }
+func (s *paneUpdateChannel) SetTrailer(metadata.MD)       {}
+func (s *paneUpdateChannel) Context() context.Context     { return s.ctx }
+func (s *paneUpdateChannel) SendMsg(interface{}) error    { return nil }
+func (s *paneUpdateChannel) RecvMsg(interface{}) error    { return nil }
+
+func (s *paneUpdateChannel) next(t *testing.T) *swarmdv1.StreamPaneUpdatesResponse {
+	t.Helper()
+	select {
+	case resp := <-s.updates:
+		return resp
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for pane update")
+		return nil
+	}
+}
+
+func captureLoopCount(server *Server) int {
+	server.captureMu.Lock()
+	defer server.captureMu.Unlock()
+	return len(server.captures)
+}
+
+func outputEntryCount(t *testing.T, server *Server, agentID string) int {
+	t.Helper()
+	resp, err := server.GetTranscript(context.Background(), &swarmdv1.GetTranscriptRequest{AgentId: agentID})
+	if err != nil {
+		t.Fatalf("GetTranscript() error = %v", err)
+	}
+	count := 0
+	for _, entry := range resp.Entries {
+		if entry.Type == swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT {
+			count++
+		}
+	}
+	return count
+}
+
+func TestStreamPaneUpdatesSharesCaptureAcrossStreams(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := NewServer(zerolog.Nop(), WithClock(fake))
+
+	srv, paneID := newPaneServer(t, "first")
+	server.backend = newTmuxBackend(tmux.NewClient(srv), server.logger)
+	server.mu.Lock()
+	server.agents["agent-1"] = &agentInfo{id: "agent-1", paneID: paneID}
+	server.mu.Unlock()
+	srv.ResetCommands()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	streams := []*paneUpdateChannel{newPaneUpdateChannel(ctx), newPaneUpdateChannel(ctx)}
+	errs := make(chan error, len(streams))
+	for _, stream := range streams {
+		go func() {
+			errs <- server.StreamPaneUpdates(&swarmdv1.StreamPaneUpdatesRequest{
+				AgentId:        "agent-1",
+				IncludeContent: true,
+				MinInterval:    durationpb.New(time.Second),
+			}, stream)
+		}()
+	}
+	receive := func(want string) {
+		t.Helper()
+		for i, stream := range streams {
+			if resp := stream.next(t); !strings.Contains(resp.Content, want) {
+				t.Fatalf("stream %d: expected content containing %q, got %q", i, want, resp.Content)
+			}
+		}
+	}
+
+	receive("first")
+	if err := srv.Print(paneID, "\nsecond"); err != nil {
+		t.Fatalf("failed to print to pane: %v", err)
+	}
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	receive("second")
+
+	// An unchanged pane is captured but neither recorded nor sent.
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	fake.BlockUntil(1)
+	for i, stream := range streams {
+		select {
+		case resp := <-stream.updates:
+			t.Fatalf("stream %d: unexpected update %q", i, resp.ContentHash)
+		default:
+		}
+	}
+
+	cancel()
+	for range streams {
+		if err := <-errs; !errors.Is(err, context.Canceled) {
+			t.Fatalf("StreamPaneUpdates() error = %v", err)
+		}
+	}
+
+	if got := outputEntryCount(t, server, "agent-1"); got != 2 {
+		t.Errorf("expected one OUTPUT entry per content change (2), got %d", got)
+	}
+	captures := 0
+	for _, cmd := range srv.Commands() {
+		if strings.Contains(cmd, "capture-pane") {
+			captures++
+		}
+	}
+	if captures != 3 {
+		t.Errorf("expected one capture per interval (3), got %d", captures)
+	}
+	if n := captureLoopCount(server); n != 0 {
+		t.Errorf("expected capture loop to stop with its last stream, %d running", n)
+	}
+}
+
+func TestStreamPaneUpdatesEndsWhenAgentKilled(t *testing.T) {
+	server := NewServer(zerolog.Nop())
+
+	srv, paneID := newPaneServer(t, "working")
+	server.backend = newTmuxBackend(tmux.NewClient(srv), server.logger)
+	server.mu.Lock()
+	server.agents["agent-1"] = &agentInfo{id: "agent-1", paneID: paneID}
+	server.mu.Unlock()
+
+	stream := newPaneUpdateChannel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.StreamPaneUpdates(&swarmdv1.StreamPaneUpdatesRequest{
+			AgentId:     "agent-1",
+			MinInterval: durationpb.New(5 * time.Millisecond),
+		}, stream)
+	}()
+	stream.next(t)
+
+	if _, err := server.KillAgent(context.Background(), &swarmdv1.KillAgentRequest{AgentId: "agent-1", Force: true}); err != nil {
+		t.Fatalf("KillAgent() error = %v", err)
+	}
+	select {
+	case err := <-errs:
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("expected NotFound after kill, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not end after agent was killed")
+	}
+	if n := captureLoopCount(server); n != 0 {
+		t.Errorf("expected capture loop to stop on kill, %d running", n)
+	}
+}
+
+func TestCaptureLoopRefcountWithConcurrentStreams(t *testing.T) {
+	server := NewServer(zerolog.Nop())
+
+	srv, paneID := newPaneServer(t, "steady output")
+	server.backend = newTmuxBackend(tmux.NewClient(srv), server.logger)
+	server.mu.Lock()
+	server.agents["agent-1"] = &agentInfo{id: "agent-1", paneID: paneID}
+	server.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(i%4+1)*5*time.Millisecond)
+			defer cancel()
+			err := server.StreamPaneUpdates(&swarmdv1.StreamPaneUpdatesRequest{
+				AgentId:     "agent-1",
+				MinInterval: durationpb.New(time.Millisecond),
+			}, newPaneUpdateChannel(ctx))
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("StreamPaneUpdates() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := captureLoopCount(server); n != 0 {
+		t.Errorf("expected no capture loops after all streams ended, %d running", n)
+	}
+	if got := outputEntryCount(t, server, "agent-1"); got != 1 {
+		t.Errorf("expected unchanged output recorded once, got %d entries", got)
+	}
+}
+
+func TestCaptureBounds(t *testing.T) {
+	tests := []struct {
+		name        string
+		serverMax   time.Duration
+		subs        []captureSub
+		wantFloor   time.Duration
+		wantCeiling time.Duration
+	}{
+		{"no subscribers", 5 * time.Second, nil, defaultPollInterval, defaultPollInterval},
+		{"server ceiling", 5 * time.Second, []captureSub{{interval: time.Second}}, time.Second, 5 * time.Second},
+		{"stream ceiling", 5 * time.Second, []captureSub{{interval: time.Second, maxInterval: 2 * time.Second}}, time.Second, 2 * time.Second},
+		{"fastest floor and lowest ceiling win", 5 * time.Second, []captureSub{
+			{interval: 2 * time.Second},
+			{interval: 250 * time.Millisecond, maxInterval: 3 * time.Second},
+		}, 250 * time.Millisecond, 3 * time.Second},
+		{"ceiling never below floor", 5 * time.Second, []captureSub{{interval: 10 * time.Second}}, 10 * time.Second, 10 * time.Second},
+		{"backoff disabled", 0, []captureSub{{interval: time.Second}}, time.Second, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(zerolog.Nop(), WithMaxPollInterval(tt.serverMax))
+			loop := &captureLoop{subs: make(map[*captureSub]struct{})}
+			for i := range tt.subs {
+				loop.subs[&tt.subs[i]] = struct{}{}
+			}
+			floor, ceiling := server.captureBoundsLocked(loop)
+			if floor != tt.wantFloor || ceiling != tt.wantCeiling {
+				t.Fatalf("captureBoundsLocked() = %s, %s, want %s, %s", floor, ceiling, tt.wantFloor, tt.wantCeiling)
+			}
+		})
+	}
+}
+
+func TestCaptureLoopAdaptsInterval(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := NewServer(zerolog.Nop(), WithClock(fake))
+
+	srv, paneID := newPaneServer(t, "first")
+	server.backend = newTmuxBackend(tmux.NewClient(srv), server.logger)
+	server.mu.Lock()
+	server.agents["agent-1"] = &agentInfo{id: "agent-1", paneID: paneID}
+	server.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newPaneUpdateChannel(ctx)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.StreamPaneUpdates(&swarmdv1.StreamPaneUpdatesRequest{
+			AgentId:     "agent-1",
+			MinInterval: durationpb.New(time.Second),
+			MaxInterval: durationpb.New(4 * time.Second),
+		}, stream)
+	}()
+	interval := func() time.Duration {
+		server.captureMu.Lock()
+		defer server.captureMu.Unlock()
+		return server.captureIntervalLocked(server.captures["agent-1"])
+	}
+
+	if resp := stream.next(t); resp.GetPollInterval().AsDuration() != time.Second {
+		t.Fatalf("expected the first capture at the floor, got %s", resp.GetPollInterval().AsDuration())
+	}
+
+	// Each unchanged capture doubles the interval, up to the ceiling.
+	for _, want := range []time.Duration{2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		fake.BlockUntil(1)
+		fake.Advance(interval())
+		fake.BlockUntil(1)
+		if got := interval(); got != want {
+			t.Fatalf("expected interval %s after an unchanged capture, got %s", want, got)
+		}
+	}
+
+	// A change is reported with the interval it was seen at and resets
+	// polling to the floor.
+	if err := srv.Print(paneID, "\nsecond"); err != nil {
+		t.Fatalf("failed to print to pane: %v", err)
+	}
+	fake.Advance(4 * time.Second)
+	if resp := stream.next(t); resp.GetPollInterval().AsDuration() != 4*time.Second {
+		t.Fatalf("expected the change seen at 4s, got %s", resp.GetPollInterval().AsDuration())
+	}
+	fake.BlockUntil(1)
+	if got := interval(); got != time.Second {
+		t.Fatalf("expected interval reset to the floor after a change, got %s", got)
+	}
+	fake.Advance(time.Second)
+	fake.BlockUntil(1)
+	fake.Advance(2 * time.Second)
+	fake.BlockUntil(1)
+	if got := interval(); got != 4*time.Second {
+		t.Fatalf("expected interval backed off to 4s, got %s", got)
+	}
+
+	// Input brings the next capture forward to the floor instead of
+	// waiting out the backed-off interval.
+	if _, err := server.SendInput(context.Background(), &swarmdv1.SendInputRequest{AgentId: "agent-1", Text: "go"}); err != nil {
+		t.Fatalf("SendInput() error = %v", err)
+	}
+	var advanced time.Duration
+	for {
+		select {
+		case resp := <-stream.updates:
+			if advanced > time.Second+100*time.Millisecond || resp.GetPollInterval().AsDuration() != time.Second {
+				t.Fatalf("expected the input's echo within the floor interval, got it after %s at %s", advanced, resp.GetPollInterval().AsDuration())
+			}
+			cancel()
+			if err := <-errs; !errors.Is(err, context.Canceled) {
+				t.Fatalf("StreamPaneUpdates() error = %v", err)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+		if advanced >= 4*time.Second {
+			t.Fatal("input did not bring the next capture forward")
+		}
+		fake.Advance(100 * time.Millisecond)
+		advanced += 100 * time.Millisecond
+	}
+}
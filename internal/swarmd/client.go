@@ -3,15 +3,28 @@ package swarmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"sync"
+	"time"
 
 	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
 	"github.com/opencode-ai/swarm/internal/ssh"
+	"github.com/opencode-ai/swarm/internal/trace"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Bounds on the delay before FollowPaneUpdates reopens a dropped stream.
+const (
+	followRetryMin = 100 * time.Millisecond
+	followRetryMax = 5 * time.Second
 )
 
 // Client provides a gRPC client connection to a swarmd daemon.
@@ -68,6 +81,8 @@ func Dial(ctx context.Context, target string, opts ...ClientOption) (*Client, er
 
 	dialOpts := append([]grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor()),
 	}, cfg.dialOpts...)
 
 	conn, err := grpc.DialContext(ctx, target, dialOpts...)
@@ -76,6 +91,7 @@ func Dial(ctx context.Context, target string, opts ...ClientOption) (*Client, er
 	}
 
 	cfg.logger.Debug().
+		Ctx(ctx).
 		Str("target", target).
 		Msg("connected to swarmd directly")
 
@@ -133,6 +149,7 @@ func DialSSH(ctx context.Context, sshHost string, sshPort int, swarmdPort int, o
 	// Connect to swarmd through the tunnel
 	localAddr := tunnel.LocalAddr()
 	cfg.logger.Debug().
+		Ctx(ctx).
 		Str("ssh_host", sshHost).
 		Int("ssh_port", sshPort).
 		Int("swarmd_port", swarmdPort).
@@ -141,6 +158,8 @@ func DialSSH(ctx context.Context, sshHost string, sshPort int, swarmdPort int, o
 
 	dialOpts := append([]grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor()),
 	}, cfg.dialOpts...)
 
 	conn, err := grpc.DialContext(ctx, localAddr, dialOpts...)
@@ -150,6 +169,7 @@ func DialSSH(ctx context.Context, sshHost string, sshPort int, swarmdPort int, o
 	}
 
 	cfg.logger.Info().
+		Ctx(ctx).
 		Str("ssh_host", sshHost).
 		Str("local_addr", localAddr).
 		Msg("connected to swarmd via SSH tunnel")
@@ -194,12 +214,15 @@ func DialSSHWithExecutor(ctx context.Context, executor ssh.PortForwarder, swarmd
 
 	localAddr := tunnel.LocalAddr()
 	cfg.logger.Debug().
+		Ctx(ctx).
 		Str("local_addr", localAddr).
 		Int("swarmd_port", swarmdPort).
 		Msg("SSH tunnel established via executor")
 
 	dialOpts := append([]grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor()),
 	}, cfg.dialOpts...)
 
 	conn, err := grpc.DialContext(ctx, localAddr, dialOpts...)
@@ -266,6 +289,21 @@ func (c *Client) StreamPaneUpdates(ctx context.Context, req *swarmdv1.StreamPane
 	return c.svc.StreamPaneUpdates(ctx, req)
 }
 
+// StreamWorkspaceUpdates streams the pane updates of a set of agents.
+func (c *Client) StreamWorkspaceUpdates(ctx context.Context, req *swarmdv1.StreamWorkspaceUpdatesRequest) (swarmdv1.SwarmdService_StreamWorkspaceUpdatesClient, error) {
+	return c.svc.StreamWorkspaceUpdates(ctx, req)
+}
+
+// ResizePane sets the size of an agent's pane.
+func (c *Client) ResizePane(ctx context.Context, req *swarmdv1.ResizePaneRequest) (*swarmdv1.ResizePaneResponse, error) {
+	return c.svc.ResizePane(ctx, req)
+}
+
+// SetPaneLayout arranges the panes of a tmux session.
+func (c *Client) SetPaneLayout(ctx context.Context, req *swarmdv1.SetPaneLayoutRequest) (*swarmdv1.SetPaneLayoutResponse, error) {
+	return c.svc.SetPaneLayout(ctx, req)
+}
+
 // StreamEvents streams daemon events.
 func (c *Client) StreamEvents(ctx context.Context, req *swarmdv1.StreamEventsRequest) (swarmdv1.SwarmdService_StreamEventsClient, error) {
 	return c.svc.StreamEvents(ctx, req)
@@ -281,6 +319,73 @@ func (c *Client) StreamTranscript(ctx context.Context, req *swarmdv1.StreamTrans
 	return c.svc.StreamTranscript(ctx, req)
 }
 
+// CompactTranscripts summarizes old OUTPUT entries of agent transcripts.
+func (c *Client) CompactTranscripts(ctx context.Context, req *swarmdv1.CompactTranscriptsRequest) (*swarmdv1.CompactTranscriptsResponse, error) {
+	return c.svc.CompactTranscripts(ctx, req)
+}
+
+// FollowPaneUpdates streams an agent's pane updates to fn until ctx ends,
+// fn returns an error, or the daemon ends the stream. A stream dropped by
+// the connection is reopened from the last content hash received, so
+// updates already delivered are not sent again.
+func (c *Client) FollowPaneUpdates(ctx context.Context, req *swarmdv1.StreamPaneUpdatesRequest, fn func(*swarmdv1.StreamPaneUpdatesResponse) error) error {
+	req = proto.Clone(req).(*swarmdv1.StreamPaneUpdatesRequest)
+	backoff := followRetryMin
+
+	for {
+		stream, err := c.svc.StreamPaneUpdates(ctx, req)
+		for err == nil {
+			var update *swarmdv1.StreamPaneUpdatesResponse
+			if update, err = stream.Recv(); err != nil {
+				break
+			}
+			backoff = followRetryMin
+			if update.ContentHash != "" {
+				req.LastKnownHash = update.ContentHash
+			}
+			if fnErr := fn(update); fnErr != nil {
+				return fnErr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if status.Code(err) != codes.Unavailable {
+			return err
+		}
+
+		c.logger.Debug().Ctx(ctx).Err(err).
+			Str("agent_id", req.AgentId).
+			Dur("backoff", backoff).
+			Msg("pane update stream dropped, resuming")
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > followRetryMax {
+			backoff = followRetryMax
+		}
+	}
+}
+
+// DumpState fetches the daemon's debug state snapshot, authenticating with
+// the daemon's debug token.
+func (c *Client) DumpState(ctx context.Context, token string) (*swarmdv1.DumpStateResponse, error) {
+	return c.svc.DumpState(WithDebugAuth(ctx, token), &swarmdv1.DumpStateRequest{})
+}
+
+// ReloadConfig asks the daemon to re-read and apply its config.
+func (c *Client) ReloadConfig(ctx context.Context) (*swarmdv1.ReloadConfigResponse, error) {
+	return c.svc.ReloadConfig(ctx, &swarmdv1.ReloadConfigRequest{})
+}
+
 // LocalAddr returns the local address of the connection.
 // For SSH tunnel connections, this is the local tunnel endpoint.
 func (c *Client) LocalAddr() string {
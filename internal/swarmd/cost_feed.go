@@ -0,0 +1,194 @@
+package swarmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// DefaultCostFeedInterval is how often the event log is followed for the
+// cost ticker.
+const DefaultCostFeedInterval = 5 * time.Second
+
+// CostFeed keeps the daemon's cost ticker current: it replays the day from
+// the event log, follows the log for new usage and agent events, and saves
+// each day's final figures to the agent daily cost cache at midnight.
+type CostFeed struct {
+	ticker   *account.CostTicker
+	events   *db.EventRepository
+	agents   *db.AgentRepository
+	usage    *db.UsageRepository
+	clock    clock.Clock
+	logger   zerolog.Logger
+	interval time.Duration
+
+	// mu guards loaded and cursor, the ID of the last event read.
+	mu     sync.Mutex
+	loaded bool
+	cursor string
+}
+
+// CostFeedOption configures a CostFeed.
+type CostFeedOption func(*CostFeed)
+
+// WithCostFeedClock sets the time source of the feed and its ticker.
+func WithCostFeedClock(c clock.Clock) CostFeedOption {
+	return func(f *CostFeed) {
+		f.clock = c
+	}
+}
+
+// WithCostFeedInterval sets how often the event log is followed.
+func WithCostFeedInterval(d time.Duration) CostFeedOption {
+	return func(f *CostFeed) {
+		f.interval = d
+	}
+}
+
+// NewCostFeed creates a feed for database, estimating working time at the
+// budget rates of cfg and resetting at midnight in its cost time zone.
+func NewCostFeed(database *db.DB, cfg config.BudgetConfig, logger zerolog.Logger, opts ...CostFeedOption) (*CostFeed, error) {
+	loc, err := cfg.CostLocation()
+	if err != nil {
+		return nil, err
+	}
+	f := &CostFeed{
+		events:   db.NewEventRepository(database),
+		agents:   db.NewAgentRepository(database),
+		usage:    db.NewUsageRepository(database),
+		logger:   logger,
+		interval: DefaultCostFeedInterval,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	f.clock = clock.OrReal(f.clock)
+	if f.interval <= 0 {
+		f.interval = DefaultCostFeedInterval
+	}
+	f.ticker = account.NewCostTicker(cfg.CostPerHour(),
+		account.WithCostClock(f.clock),
+		account.WithCostLocation(loc),
+		account.WithCostRollover(f.saveDay),
+	)
+	return f, nil
+}
+
+// Ticker returns the ticker the feed keeps current.
+func (f *CostFeed) Ticker() *account.CostTicker {
+	return f.ticker
+}
+
+// Reconfigure applies the budget rates of cfg. The cost time zone is only
+// read at startup.
+func (f *CostFeed) Reconfigure(cfg *config.Config) error {
+	f.ticker.SetRates(cfg.Budget.CostPerHour())
+	return nil
+}
+
+// Run follows the event log until ctx is canceled.
+func (f *CostFeed) Run(ctx context.Context) {
+	ticker := f.clock.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.Sync(ctx); err != nil && ctx.Err() == nil {
+			f.logger.Warn().Ctx(ctx).Err(err).Msg("failed to follow the event log for agent costs")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Sync loads the day on first use, then reads the events logged since
+// the last call, and closes the day if midnight has passed.
+func (f *CostFeed) Sync(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.loaded {
+		cursor, err := account.LoadCostTicker(ctx, account.CostSources{Events: f.events, Agents: f.agents}, f.ticker, "")
+		if err != nil {
+			return err
+		}
+		f.cursor, f.loaded = cursor, true
+	}
+
+	for {
+		since := f.ticker.DayStart()
+		page, err := f.events.Query(ctx, db.EventQuery{Since: &since, Cursor: f.cursor, Limit: db.DefaultIterateBatchSize})
+		if err != nil {
+			return err
+		}
+		for _, event := range page.Events {
+			f.ticker.Handle(event)
+			f.cursor = event.ID
+		}
+		if page.NextCursor == "" {
+			break
+		}
+	}
+	f.ticker.Roll()
+	return nil
+}
+
+// saveDay writes a closed day's figures to the agent daily cost cache.
+func (f *CostFeed) saveDay(date string, costs []models.AgentCost) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := f.usage.UpsertAgentDailyCosts(ctx, costs); err != nil {
+		f.logger.Warn().Err(err).Str("date", date).Msg("failed to save the day's agent costs")
+		return
+	}
+	f.logger.Info().Str("date", date).Int("agents", len(costs)).Msg("saved the day's agent costs")
+}
+
+// MetricsHandler serves the ticker's figures in the Prometheus text
+// format, with recorded and estimated cost as separate gauges.
+func (f *CostFeed) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeCostMetrics(w, f.ticker.Costs())
+	})
+}
+
+func writeCostMetrics(w io.Writer, costs []models.AgentCost) {
+	gauges := []struct {
+		name  string
+		help  string
+		value func(models.AgentCost) int64
+	}{
+		{"swarm_agent_cost_actual_cents", "Usage cost recorded for the agent today, in cents.",
+			func(c models.AgentCost) int64 { return c.ActualCents }},
+		{"swarm_agent_cost_estimated_cents", "Estimated cost of the agent's working time today with no usage recorded for it, in cents. Not included in actual.",
+			func(c models.AgentCost) int64 { return c.Estimated.Cents }},
+		{"swarm_agent_estimated_working_seconds", "Working time the estimated cost covers, in seconds.",
+			func(c models.AgentCost) int64 { return c.Estimated.WorkingSeconds }},
+	}
+	for _, gauge := range gauges {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", gauge.name, gauge.help, gauge.name)
+		for _, cost := range costs {
+			fmt.Fprintf(w, "%s{agent_id=%q,agent_type=%q,date=%q} %d\n",
+				gauge.name, metricLabel(cost.AgentID), metricLabel(string(cost.AgentType)), cost.Date, gauge.value(cost))
+		}
+	}
+}
+
+// metricLabel strips what the text format cannot carry in a label value.
+func metricLabel(value string) string {
+	return strings.NewReplacer("\n", " ", "\\", "").Replace(value)
+}
@@ -0,0 +1,95 @@
+package swarmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestCostFeedSavesDayAtMidnight(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "repo", RepoPath: "/repos/repo", TmuxSession: "repo"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "repo:0.1", State: models.AgentStateWorking}
+	if err := db.NewAgentRepository(database).Create(ctx, agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	start := time.Date(2026, 10, 14, 23, 40, 0, 0, time.UTC)
+	fake := clock.NewFake(start.Add(5 * time.Minute))
+	cfg := config.DefaultConfig().Budget
+	cfg.CostTimezone = "UTC"
+	cfg.CostPerHourCents = map[string]int64{string(models.AgentTypeOpenCode): 240}
+	feed, err := NewCostFeed(database, cfg, zerolog.Nop(), WithCostFeedClock(fake))
+	if err != nil {
+		t.Fatalf("NewCostFeed failed: %v", err)
+	}
+
+	eventRepo := db.NewEventRepository(database)
+	logEvent := func(eventType models.EventType, at time.Time, payload any) {
+		t.Helper()
+		raw, _ := json.Marshal(payload)
+		event := &models.Event{Type: eventType, Timestamp: at, EntityType: models.EntityTypeAgent, EntityID: agent.ID, Payload: raw}
+		if err := eventRepo.Create(ctx, event); err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+	}
+	logEvent(models.EventTypeAgentStateChanged, start, models.StateChangedPayload{OldState: models.AgentStateIdle, NewState: models.AgentStateWorking})
+	if err := feed.Sync(ctx); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// Usage logged after the first sync is picked up from the log.
+	logEvent(models.EventTypeUsageRecorded, start.Add(5*time.Minute), models.UsageRecordedPayload{AgentID: agent.ID, CostCents: 30})
+	fake.Advance(15 * time.Minute)
+	if err := feed.Sync(ctx); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	saved, err := db.NewUsageRepository(database).GetAgentDailyCosts(ctx, "2026-10-14")
+	if err != nil {
+		t.Fatalf("GetAgentDailyCosts failed: %v", err)
+	}
+	if len(saved) != 1 || saved[0].ActualCents != 30 || saved[0].Estimated.WorkingSeconds != 900 || saved[0].Estimated.Cents != 60 {
+		t.Fatalf("expected the 14th saved with 30c recorded and 15m estimated, got %+v", saved)
+	}
+
+	fake.Advance(5 * time.Minute)
+	rec := httptest.NewRecorder()
+	feed.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`swarm_agent_cost_actual_cents{agent_id="` + agent.ID + `",agent_type="opencode",date="2026-10-15"} 0`,
+		`swarm_agent_cost_estimated_cents{agent_id="` + agent.ID + `",agent_type="opencode",date="2026-10-15"} 20`,
+		`swarm_agent_estimated_working_seconds{agent_id="` + agent.ID + `",agent_type="opencode",date="2026-10-15"} 300`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in metrics:\n%s", want, body)
+		}
+	}
+}
@@ -6,13 +6,22 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/audit"
 	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/eventbridge"
+	"github.com/opencode-ai/swarm/internal/redact"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/trace"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 // Options configure the daemon runtime.
@@ -21,15 +30,29 @@ type Options struct {
 	Port     int
 	Version  string
 
-	// RateLimitEnabled enables rate limiting (default: true).
+	// Commit and BuildDate are reported by the DumpState debug endpoint.
+	Commit    string
+	BuildDate string
+
+	// RateLimitEnabled enables rate limiting (default: the config's
+	// daemon.rate_limits.enabled).
 	RateLimitEnabled *bool
 
 	// CustomRateLimits allows overriding default rate limits per method.
+	// They take precedence over the config's, including after a reload.
 	CustomRateLimits map[string]RateLimitConfig
 
 	// GlobalRateLimit sets an optional global rate limit across all methods.
 	GlobalRateLimit *RateLimitConfig
 
+	// LoadConfig re-reads the config for Reload, returning it and the file
+	// it was read from. Reloading is unavailable when nil.
+	LoadConfig func() (*config.Config, string, error)
+
+	// ConfigFile is watched for changes, which are reloaded every
+	// daemon.config_watch_interval. Empty disables watching.
+	ConfigFile string
+
 	// ResourceMonitorEnabled enables resource monitoring (default: true).
 	ResourceMonitorEnabled *bool
 
@@ -38,6 +61,67 @@ type Options struct {
 
 	// DiskMonitorConfig customizes disk usage monitoring.
 	DiskMonitorConfig *DiskMonitorConfig
+
+	// Database enables the startup recovery pass against the agent records
+	// it holds, the audit log of mutating RPCs, and, with
+	// daemon.transcript_store.enabled, stored transcripts. Recovery is
+	// skipped and calls go unaudited when nil.
+	Database *db.DB
+
+	// TmuxClient is used by startup recovery (default: local tmux).
+	TmuxClient *tmux.Client
+
+	// SkipRecovery disables the startup recovery pass.
+	SkipRecovery bool
+
+	// SkipSchedules disables running recurring schedules from Database.
+	SkipSchedules bool
+
+	// StandbySpawner spawns the agents that keep configured standby pools
+	// filled. Pools are not maintained when nil or without Database.
+	StandbySpawner StandbySpawner
+
+	// SkipStandby disables standby pool maintenance.
+	SkipStandby bool
+
+	// SkipAgentPruning disables deleting terminated agents past their
+	// retention period.
+	SkipAgentPruning bool
+
+	// SkipTranscriptCompaction disables summarizing old transcript output.
+	SkipTranscriptCompaction bool
+
+	// SkipTranscriptPush disables delivering transcripts to the push
+	// subscriptions in Database.
+	SkipTranscriptPush bool
+
+	// SkipCostTicker disables following Database's event log for the
+	// per-agent cost ticker.
+	SkipCostTicker bool
+
+	// SkipProviderHealth disables probing the providers of Database's
+	// accounts.
+	SkipProviderHealth bool
+
+	// Reflection registers the gRPC server reflection service so tools
+	// like grpcurl can discover the API.
+	Reflection bool
+
+	// DebugEndpoint enables the DumpState RPC for callers presenting
+	// DebugToken, which is required when the endpoint is enabled.
+	DebugEndpoint bool
+	DebugToken    string
+
+	// AuthToken, when set, is required as a bearer token on every call.
+	AuthToken string
+
+	// HealthAddr, when set, is the address of an HTTP listener serving
+	// /healthz and /readyz, and /metrics while the cost ticker runs.
+	HealthAddr string
+
+	// ServeDegraded keeps /readyz ready while tmux fails its check,
+	// reporting it as degraded.
+	ServeDegraded bool
 }
 
 // Daemon is the long-running process responsible for node orchestration.
@@ -46,10 +130,26 @@ type Daemon struct {
 	logger zerolog.Logger
 	opts   Options
 
-	server          *Server
-	grpcServer      *grpc.Server
-	rateLimiter     *RateLimiter
-	resourceMonitor *ResourceMonitor
+	server           *Server
+	grpcServer       *grpc.Server
+	rateLimiter      *RateLimiter
+	resourceMonitor  *ResourceMonitor
+	scheduleRunner   *ScheduleRunner
+	standbyRunner    *StandbyRunner
+	agentPruner      *AgentPruner
+	auditPruner      *AuditPruner
+	usageCache       *UsageCacheMaintainer
+	compactor        *TranscriptCompactor
+	transcriptPruner *TranscriptPruner
+	pusher           *TranscriptPusher
+	costFeed         *CostFeed
+	sessionGC        *SessionCollector
+	providerMonitor  *ProviderMonitor
+	eventBridge      *eventbridge.Bridge
+	health           *Health
+
+	// reloadMu serializes reloads and guards cfg.
+	reloadMu sync.Mutex
 }
 
 // New constructs a daemon with the provided configuration.
@@ -64,36 +164,93 @@ func New(cfg *config.Config, logger zerolog.Logger, opts Options) (*Daemon, erro
 		opts.Port = DefaultPort
 	}
 
+	if opts.DebugEndpoint && opts.DebugToken == "" {
+		return nil, fmt.Errorf("debug endpoint requires a token (set %s)", DebugTokenEnv)
+	}
+
+	redactor, err := redact.FromConfig(cfg.Redaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build redactor: %w", err)
+	}
+
+	backend, err := NewExecutionBackend(cfg.Daemon.ExecutionBackend, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the gRPC service implementation
-	server := NewServer(logger, WithVersion(opts.Version))
+	serverOpts := []ServerOption{
+		WithExecutionBackend(backend),
+		WithVersion(opts.Version),
+		WithBuildInfo(opts.Commit, opts.BuildDate),
+		WithRedactor(redactor),
+		WithTranscriptBackfillLimit(cfg.Daemon.TranscriptBackfillMaxBytes),
+		WithTranscriptLimit(cfg.Daemon.TranscriptMaxEntries, cfg.Daemon.TranscriptMaxBytes),
+		WithSensitiveInputWindow(cfg.Daemon.SensitiveInputWindow),
+		WithMaxPollInterval(cfg.Daemon.PanePollMaxInterval),
+	}
+	if opts.DebugEndpoint {
+		serverOpts = append(serverOpts, WithDebugToken(opts.DebugToken))
+	}
+	var transcriptPruner *TranscriptPruner
+	if opts.Database != nil && cfg.Daemon.TranscriptStore.Enabled {
+		serverOpts = append(serverOpts, WithTranscriptStore(db.NewTranscriptRepository(opts.Database)))
+		transcriptPruner = NewTranscriptPruner(opts.Database, cfg.Daemon.TranscriptStore, logger)
+	}
+	server := NewServer(logger, serverOpts...)
 
-	// Create rate limiter with options
-	var rlOpts []RateLimiterOption
-	if opts.CustomRateLimits != nil {
-		rlOpts = append(rlOpts, WithMethodLimits(opts.CustomRateLimits))
+	// Create rate limiter from the config and options
+	rateLimits, err := rateLimitSettings(cfg, opts)
+	if err != nil {
+		return nil, err
 	}
-	if opts.GlobalRateLimit != nil {
-		rlOpts = append(rlOpts, WithGlobalLimit(*opts.GlobalRateLimit))
+	rlOpts := []RateLimiterOption{
+		WithMethodLimits(rateLimits.Methods),
+		WithEnabled(rateLimits.Enabled),
 	}
-	if opts.RateLimitEnabled != nil {
-		rlOpts = append(rlOpts, WithEnabled(*opts.RateLimitEnabled))
+	if rateLimits.Global != nil {
+		rlOpts = append(rlOpts, WithGlobalLimit(*rateLimits.Global))
 	}
 	rateLimiter := NewRateLimiter(rlOpts...)
 
-	// Create the gRPC server with rate limiting interceptors
+	// Create the gRPC server with tracing, auth, rate limiting, and audit
+	// interceptors
+	unary := []grpc.UnaryServerInterceptor{trace.UnaryServerInterceptor()}
+	stream := []grpc.StreamServerInterceptor{trace.StreamServerInterceptor()}
+	if opts.AuthToken != "" {
+		authUnary, authStream := AuthInterceptors(opts.AuthToken)
+		unary = append(unary, authUnary)
+		stream = append(stream, authStream)
+	}
+	unary = append(unary, rateLimiter.UnaryServerInterceptor())
+	stream = append(stream, rateLimiter.StreamServerInterceptor())
+	if opts.Database != nil {
+		unary = append(unary, AuditInterceptor(audit.New(opts.Database, audit.WithRedactor(redactor)), opts.AuthToken))
+	}
 	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(rateLimiter.UnaryServerInterceptor()),
-		grpc.ChainStreamInterceptor(rateLimiter.StreamServerInterceptor()),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
 	)
 	swarmdv1.RegisterSwarmdServiceServer(grpcServer, server)
+	if opts.Reflection {
+		reflection.Register(grpcServer)
+	}
 
 	// Store rate limiter reference in server for status reporting
 	server.SetRateLimiter(rateLimiter)
 
 	logger.Info().
 		Bool("rate_limiting_enabled", rateLimiter.IsEnabled()).
+		Bool("auth_required", opts.AuthToken != "").
 		Msg("rate limiter configured")
 
+	if opts.Reflection || opts.DebugEndpoint {
+		logger.Info().
+			Bool("reflection", opts.Reflection).
+			Bool("debug_endpoint", opts.DebugEndpoint).
+			Msg("debug services enabled")
+	}
+
 	// Create resource monitor if enabled (default: enabled)
 	var resourceMonitor *ResourceMonitor
 	resourceMonitorEnabled := opts.ResourceMonitorEnabled == nil || *opts.ResourceMonitorEnabled
@@ -134,15 +291,129 @@ func New(cfg *config.Config, logger zerolog.Logger, opts Options) (*Daemon, erro
 			Msg("resource monitor configured")
 	}
 
-	return &Daemon{
-		cfg:             cfg,
-		logger:          logger,
-		opts:            opts,
-		server:          server,
-		grpcServer:      grpcServer,
-		rateLimiter:     rateLimiter,
-		resourceMonitor: resourceMonitor,
-	}, nil
+	var scheduleRunner *ScheduleRunner
+	if opts.Database != nil && !opts.SkipSchedules {
+		loc, err := cfg.Scheduler.ScheduleLocation()
+		if err != nil {
+			return nil, err
+		}
+		scheduleRunner = NewScheduleRunner(opts.Database, logger,
+			WithScheduleLocation(loc),
+			WithScheduleCatchUp(cfg.Scheduler.ScheduleCatchUp),
+			WithScheduleInterval(cfg.Daemon.ScheduleInterval),
+		)
+	}
+
+	var standbyRunner *StandbyRunner
+	if opts.Database != nil && opts.StandbySpawner != nil && !opts.SkipStandby {
+		standbyRunner = NewStandbyRunner(cfg, opts.Database, opts.StandbySpawner, logger,
+			WithStandbyInterval(cfg.Daemon.StandbyInterval),
+			WithStandbySpawnLimit(rateLimits.Limit(swarmdv1.SwarmdService_SpawnAgent_FullMethodName)),
+		)
+	}
+
+	var agentPruner *AgentPruner
+	if opts.Database != nil && !opts.SkipAgentPruning {
+		agentPruner = NewAgentPruner(opts.Database, cfg.AgentRetention.MaxAge, logger,
+			WithAgentPruneInterval(cfg.AgentRetention.CleanupInterval),
+		)
+	}
+
+	var auditPruner *AuditPruner
+	if opts.Database != nil {
+		auditPruner = NewAuditPruner(opts.Database, cfg.AuditRetention, logger)
+	}
+
+	var usageCache *UsageCacheMaintainer
+	if opts.Database != nil {
+		usageCache = NewUsageCacheMaintainer(opts.Database, cfg.UsageRetention, logger)
+	}
+
+	var compactor *TranscriptCompactor
+	if !opts.SkipTranscriptCompaction {
+		compactor = NewTranscriptCompactor(server, cfg.Daemon.TranscriptCompaction.OlderThan, logger,
+			WithTranscriptCompactInterval(cfg.Daemon.TranscriptCompaction.Interval),
+		)
+	}
+
+	var pusher *TranscriptPusher
+	if opts.Database != nil && !opts.SkipTranscriptPush {
+		pusher = NewTranscriptPusher(server, opts.Database, logger)
+	}
+
+	var costFeed *CostFeed
+	if opts.Database != nil && !opts.SkipCostTicker {
+		costFeed, err = NewCostFeed(opts.Database, cfg.Budget, logger)
+		if err != nil {
+			return nil, err
+		}
+		server.SetCostTicker(costFeed.Ticker())
+	}
+
+	var providerMonitor *ProviderMonitor
+	if opts.Database != nil && !opts.SkipProviderHealth {
+		providerMonitor = NewProviderMonitor(opts.Database, cfg.Daemon.ProviderHealth, logger)
+	}
+
+	// Session GC and the tmux readiness check only apply to agents in tmux.
+	tmuxAgents, usesTmux := backend.(*tmuxBackend)
+
+	var sessionGC *SessionCollector
+	if opts.Database != nil && usesTmux {
+		sessionGC = NewSessionCollector(opts.Database, tmuxAgents.client, cfg.Daemon.SessionGC, logger,
+			WithSessionGCPrefix(cfg.WorkspaceDefaults.TmuxPrefix),
+		)
+	}
+
+	var eventBridge *eventbridge.Bridge
+	if opts.Database != nil && cfg.EventBridge.Enabled {
+		dial, err := eventbridge.NewDialer(cfg.EventBridge)
+		if err != nil {
+			return nil, err
+		}
+		eventBridge = eventbridge.New(opts.Database, dial, logger,
+			eventbridge.WithSubjectPrefix(cfg.EventBridge.SubjectPrefix),
+			eventbridge.WithBufferSize(cfg.EventBridge.BufferSize),
+			eventbridge.WithBatchSize(cfg.EventBridge.BatchSize),
+			eventbridge.WithPollInterval(cfg.EventBridge.PollInterval),
+			eventbridge.WithPublishTimeout(cfg.EventBridge.PublishTimeout),
+		)
+	}
+
+	healthOpts := []HealthOption{
+		WithServeDegraded(opts.ServeDegraded),
+	}
+	if usesTmux {
+		healthOpts = append(healthOpts, WithTmuxCheck(tmuxReadiness(tmuxAgents.client)))
+	}
+	if opts.Database != nil {
+		healthOpts = append(healthOpts, WithDatabaseCheck(databaseReadiness(opts.Database)))
+	}
+
+	daemon := &Daemon{
+		cfg:              cfg,
+		logger:           logger,
+		opts:             opts,
+		server:           server,
+		grpcServer:       grpcServer,
+		rateLimiter:      rateLimiter,
+		resourceMonitor:  resourceMonitor,
+		scheduleRunner:   scheduleRunner,
+		standbyRunner:    standbyRunner,
+		agentPruner:      agentPruner,
+		auditPruner:      auditPruner,
+		usageCache:       usageCache,
+		compactor:        compactor,
+		transcriptPruner: transcriptPruner,
+		pusher:           pusher,
+		costFeed:         costFeed,
+		sessionGC:        sessionGC,
+		providerMonitor:  providerMonitor,
+		eventBridge:      eventBridge,
+		health:           NewHealth(healthOpts...),
+	}
+	server.SetReloader(daemon.Reload)
+	return daemon, nil
 }
 
 // Run starts the gRPC server and blocks until the context is canceled.
@@ -158,16 +429,204 @@ func (d *Daemon) Run(ctx context.Context) error {
 	}
 
 	d.logger.Info().
+		Ctx(ctx).
 		Str("bind", bindAddr).
 		Str("version", d.opts.Version).
 		Msg("swarmd gRPC server starting")
 
+	// Health endpoints come up first so /readyz reports recovery in progress.
+	if d.opts.HealthAddr != "" {
+		stopHealth, err := d.serveHealth()
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		defer stopHealth()
+	}
+
+	if d.opts.Database != nil && !d.opts.SkipRecovery {
+		d.runStartupRecovery(ctx)
+	}
+
 	// Start resource monitor if configured
 	if d.resourceMonitor != nil {
 		d.resourceMonitor.Start(ctx)
 		defer d.resourceMonitor.Stop()
 	}
 
+	// Run recurring schedules after recovery so they see live agent states.
+	if d.scheduleRunner != nil {
+		scheduleCtx, cancelSchedules := context.WithCancel(ctx)
+		scheduleDone := make(chan struct{})
+		go func() {
+			defer close(scheduleDone)
+			d.scheduleRunner.Run(scheduleCtx)
+		}()
+		defer func() {
+			cancelSchedules()
+			<-scheduleDone
+		}()
+	}
+
+	if d.standbyRunner != nil {
+		standbyCtx, cancelStandby := context.WithCancel(ctx)
+		standbyDone := make(chan struct{})
+		go func() {
+			defer close(standbyDone)
+			d.standbyRunner.Run(standbyCtx)
+		}()
+		defer func() {
+			cancelStandby()
+			<-standbyDone
+		}()
+	}
+
+	if d.agentPruner != nil {
+		pruneCtx, cancelPrune := context.WithCancel(ctx)
+		pruneDone := make(chan struct{})
+		go func() {
+			defer close(pruneDone)
+			d.agentPruner.Run(pruneCtx)
+		}()
+		defer func() {
+			cancelPrune()
+			<-pruneDone
+		}()
+	}
+
+	if d.auditPruner != nil {
+		auditCtx, cancelAudit := context.WithCancel(ctx)
+		auditDone := make(chan struct{})
+		go func() {
+			defer close(auditDone)
+			d.auditPruner.Run(auditCtx)
+		}()
+		defer func() {
+			cancelAudit()
+			<-auditDone
+		}()
+	}
+
+	if d.usageCache != nil {
+		usageCtx, cancelUsage := context.WithCancel(ctx)
+		usageDone := make(chan struct{})
+		go func() {
+			defer close(usageDone)
+			d.usageCache.Run(usageCtx)
+		}()
+		defer func() {
+			cancelUsage()
+			<-usageDone
+		}()
+	}
+
+	if d.sessionGC != nil {
+		gcCtx, cancelGC := context.WithCancel(ctx)
+		gcDone := make(chan struct{})
+		go func() {
+			defer close(gcDone)
+			d.sessionGC.Run(gcCtx)
+		}()
+		defer func() {
+			cancelGC()
+			<-gcDone
+		}()
+	}
+
+	if d.compactor != nil {
+		compactCtx, cancelCompact := context.WithCancel(ctx)
+		compactDone := make(chan struct{})
+		go func() {
+			defer close(compactDone)
+			d.compactor.Run(compactCtx)
+		}()
+		defer func() {
+			cancelCompact()
+			<-compactDone
+		}()
+	}
+
+	if d.transcriptPruner != nil {
+		transcriptCtx, cancelTranscript := context.WithCancel(ctx)
+		transcriptDone := make(chan struct{})
+		go func() {
+			defer close(transcriptDone)
+			d.transcriptPruner.Run(transcriptCtx)
+		}()
+		defer func() {
+			cancelTranscript()
+			<-transcriptDone
+		}()
+	}
+
+	// Keep agents' PIDs current as panes restart their commands.
+	pidCtx, cancelPIDs := context.WithCancel(ctx)
+	pidDone := make(chan struct{})
+	go func() {
+		defer close(pidDone)
+		d.server.RunPIDRefresh(pidCtx, DefaultPIDRefreshInterval)
+	}()
+	defer func() {
+		cancelPIDs()
+		<-pidDone
+	}()
+
+	if d.pusher != nil {
+		pushCtx, cancelPush := context.WithCancel(ctx)
+		pushDone := make(chan struct{})
+		go func() {
+			defer close(pushDone)
+			d.pusher.Run(pushCtx)
+		}()
+		defer func() {
+			cancelPush()
+			<-pushDone
+		}()
+	}
+
+	if d.costFeed != nil {
+		costCtx, cancelCost := context.WithCancel(ctx)
+		costDone := make(chan struct{})
+		go func() {
+			defer close(costDone)
+			d.costFeed.Run(costCtx)
+		}()
+		defer func() {
+			cancelCost()
+			<-costDone
+		}()
+	}
+
+	if d.providerMonitor != nil {
+		providerCtx, cancelProvider := context.WithCancel(ctx)
+		providerDone := make(chan struct{})
+		go func() {
+			defer close(providerDone)
+			d.providerMonitor.Run(providerCtx)
+		}()
+		defer func() {
+			cancelProvider()
+			<-providerDone
+		}()
+	}
+
+	if d.eventBridge != nil {
+		bridgeCtx, cancelBridge := context.WithCancel(ctx)
+		bridgeDone := make(chan struct{})
+		go func() {
+			defer close(bridgeDone)
+			d.eventBridge.Run(bridgeCtx)
+		}()
+		defer func() {
+			cancelBridge()
+			<-bridgeDone
+		}()
+	}
+
+	if interval := d.Config().Daemon.ConfigWatchInterval; d.opts.ConfigFile != "" && d.opts.LoadConfig != nil && interval > 0 {
+		go d.watchConfig(ctx, d.opts.ConfigFile, interval)
+	}
+
 	// Start gRPC server in a goroutine
 	errCh := make(chan error, 1)
 	go func() {
@@ -177,21 +636,95 @@ func (d *Daemon) Run(ctx context.Context) error {
 		close(errCh)
 	}()
 
-	// Wait for shutdown signal or error
-	select {
-	case <-ctx.Done():
-		d.logger.Info().Msg("swarmd shutting down...")
-		d.grpcServer.GracefulStop()
-	case err := <-errCh:
-		if err != nil {
-			return fmt.Errorf("gRPC server error: %w", err)
+	d.health.MarkStarted()
+	if notified, err := sdNotify(sdNotifyReady); err != nil {
+		d.logger.Warn().Ctx(ctx).Err(err).Msg("failed to notify systemd of readiness")
+	} else if notified {
+		d.logger.Debug().Ctx(ctx).Msg("notified systemd of readiness")
+	}
+
+	// Wait for shutdown signal or error, beating the liveness heartbeat.
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info().Ctx(ctx).Msg("swarmd shutting down...")
+			// Fail readiness before the gRPC server stops accepting calls.
+			d.health.StartDraining()
+			if _, err := sdNotify(sdNotifyStopping); err != nil {
+				d.logger.Debug().Ctx(ctx).Err(err).Msg("failed to notify systemd of shutdown")
+			}
+			d.grpcServer.GracefulStop()
+		case err := <-errCh:
+			if err != nil {
+				return fmt.Errorf("gRPC server error: %w", err)
+			}
+		case <-heartbeat.C:
+			d.health.Beat()
+			continue
 		}
+		break
 	}
 
-	d.logger.Info().Msg("swarmd shutdown complete")
+	d.logger.Info().Ctx(ctx).Msg("swarmd shutdown complete")
 	return nil
 }
 
+// serveHealth starts the HTTP listener for the health endpoints and
+// returns a func that shuts it down.
+func (d *Daemon) serveHealth() (func(), error) {
+	listener, err := net.Listen("tcp", d.opts.HealthAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s for health endpoints: %w", d.opts.HealthAddr, err)
+	}
+	handler := d.health.Handler()
+	if d.costFeed != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/", handler)
+		mux.Handle("/metrics", d.costFeed.MetricsHandler())
+		handler = mux
+	}
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			d.logger.Warn().Err(err).Msg("health endpoint server failed")
+		}
+	}()
+	d.logger.Info().Str("bind", listener.Addr().String()).Msg("health endpoints listening")
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}, nil
+}
+
+// runStartupRecovery marks agents whose panes did not survive a restart as
+// stopped. Failures are logged; they never block startup.
+func (d *Daemon) runStartupRecovery(ctx context.Context) {
+	recovery := NewStartupRecovery(d.opts.Database, d.opts.TmuxClient, d.logger)
+	report, err := recovery.Run(ctx)
+	if err != nil {
+		d.logger.Warn().Ctx(ctx).Err(err).Msg("startup recovery failed")
+		return
+	}
+
+	for _, msg := range report.Errors {
+		d.logger.Warn().Ctx(ctx).Str("error", msg).Msg("startup recovery could not check agent")
+	}
+	d.logger.Info().
+		Ctx(ctx).
+		Int("checked", report.Checked).
+		Int("recovered", report.Recovered).
+		Int("skipped", report.Skipped).
+		Int("stopped", len(report.Stopped)).
+		Msg("startup recovery complete")
+}
+
 func (d *Daemon) bindAddr() string {
 	return net.JoinHostPort(d.opts.Hostname, strconv.Itoa(d.opts.Port))
 }
@@ -202,12 +735,24 @@ func (d *Daemon) Server() *Server {
 	return d.server
 }
 
+// StandbyRunner returns the standby pool runner, or nil when pools are not
+// maintained.
+func (d *Daemon) StandbyRunner() *StandbyRunner {
+	return d.standbyRunner
+}
+
 // RateLimiter returns the rate limiter.
 // Useful for testing and runtime configuration.
 func (d *Daemon) RateLimiter() *RateLimiter {
 	return d.rateLimiter
 }
 
+// Health returns the liveness and readiness state behind the health
+// endpoints.
+func (d *Daemon) Health() *Health {
+	return d.health
+}
+
 // ResourceMonitor returns the resource monitor.
 // Useful for testing and runtime configuration.
 func (d *Daemon) ResourceMonitor() *ResourceMonitor {
@@ -52,3 +52,32 @@ func TestRunReturnsOnCanceledContext(t *testing.T) {
 		t.Fatal("Run() did not return after context cancellation")
 	}
 }
+
+func TestNewDebugServicesAreGated(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if _, err := New(cfg, zerolog.Nop(), Options{DebugEndpoint: true}); err == nil {
+		t.Fatal("expected the debug endpoint to require a token")
+	}
+
+	daemon, err := New(cfg, zerolog.Nop(), Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := daemon.grpcServer.GetServiceInfo()["grpc.reflection.v1.ServerReflection"]; ok {
+		t.Fatal("expected reflection to be off by default")
+	}
+	if daemon.server.debugToken != "" {
+		t.Fatal("expected DumpState to be disabled by default")
+	}
+
+	daemon, err = New(cfg, zerolog.Nop(), Options{Reflection: true, DebugEndpoint: true, DebugToken: "tok"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := daemon.grpcServer.GetServiceInfo()["grpc.reflection.v1.ServerReflection"]; !ok {
+		t.Fatal("expected reflection to be registered")
+	}
+	if daemon.server.debugToken != "tok" {
+		t.Fatal("expected DumpState to be enabled")
+	}
+}
@@ -0,0 +1,223 @@
+package swarmd
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// DebugTokenEnv is the environment variable holding the token that
+	// enables DumpState on the daemon and authenticates debug clients.
+	DebugTokenEnv = "SWARMD_DEBUG_TOKEN"
+
+	// authKey is the metadata key carrying the auth and debug bearer tokens.
+	authKey = "authorization"
+
+	// debugLockTimeout bounds how long DumpState waits for each state lock,
+	// so a wedged lock holder cannot wedge the debug endpoint too.
+	debugLockTimeout = 2 * time.Second
+
+	// debugLockPoll is how often a busy lock is retried.
+	debugLockPoll = 5 * time.Millisecond
+)
+
+// WithDebugToken enables DumpState for callers presenting token. DumpState
+// is disabled when token is empty.
+func WithDebugToken(token string) ServerOption {
+	return func(s *Server) {
+		s.debugToken = token
+	}
+}
+
+// WithBuildInfo sets the commit and build date reported by DumpState.
+func WithBuildInfo(commit, built string) ServerOption {
+	return func(s *Server) {
+		s.commit = commit
+		s.built = built
+	}
+}
+
+// WithDebugAuth returns a context that sends token to DumpState.
+func WithDebugAuth(ctx context.Context, token string) context.Context {
+	return withBearer(ctx, token)
+}
+
+// DumpState returns a sanitized snapshot of the daemon's internal state.
+// Only identifiers, states, and counters are copied out; agent commands,
+// environment, pane content, and transcript text never leave the daemon.
+func (s *Server) DumpState(ctx context.Context, req *swarmdv1.DumpStateRequest) (*swarmdv1.DumpStateResponse, error) {
+	if err := s.authorizeDebug(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, debugLockTimeout)
+	defer cancel()
+
+	resp := &swarmdv1.DumpStateResponse{
+		CapturedAt: timestamppb.New(s.clock.Now()),
+		Uptime:     durationpb.New(clock.Since(s.clock, s.startedMono)),
+		Build: &swarmdv1.BuildInfo{
+			Version:   s.version,
+			Commit:    s.commit,
+			Built:     s.built,
+			GoVersion: runtime.Version(),
+		},
+	}
+
+	if !rlockWithin(ctx, &s.mu) {
+		return nil, status.Error(codes.Unavailable, "agent state is busy, try again")
+	}
+	handles := make([]*agentInfo, 0, len(s.agents))
+	for _, info := range s.agents {
+		handles = append(handles, info)
+	}
+	s.mu.RUnlock()
+
+	byID := make(map[string]*swarmdv1.AgentDebugSummary, len(handles))
+	for _, info := range handles {
+		// A wedged agent is reported as busy rather than stalling the dump.
+		if !lockWithin(ctx, &info.mu) {
+			return nil, status.Errorf(codes.Unavailable, "agent %q is busy, try again", info.id)
+		}
+		removed := info.removed
+		summary := &swarmdv1.AgentDebugSummary{
+			Id:                info.id,
+			WorkspaceId:       info.workspaceID,
+			State:             info.state,
+			PaneId:            info.paneID,
+			TranscriptEntries: int32(len(info.transcript)),
+			LastActivityAt:    timestamppb.New(info.lastActive),
+		}
+		info.mu.Unlock()
+		if removed {
+			continue
+		}
+		byID[info.id] = summary
+		resp.Agents = append(resp.Agents, summary)
+	}
+	if !lockWithin(ctx, &s.captureMu) {
+		return nil, status.Error(codes.Unavailable, "capture state is busy, try again")
+	}
+	for id, loop := range s.captures {
+		if summary, ok := byID[id]; ok {
+			summary.PaneStreams = int32(len(loop.subs))
+		}
+	}
+	s.captureMu.Unlock()
+
+	s.streamsMu.Lock()
+	for id, n := range s.transcriptStreams {
+		if summary, ok := byID[id]; ok {
+			summary.TranscriptStreams = int32(n)
+		}
+	}
+	s.streamsMu.Unlock()
+
+	if !rlockWithin(ctx, &s.eventsMu) {
+		return nil, status.Error(codes.Unavailable, "event state is busy, try again")
+	}
+	resp.EventSubscribers = int32(len(s.eventSubs))
+	resp.StoredEvents = int32(len(s.events))
+	for _, sub := range s.eventSubs {
+		for id := range sub.agentIDs {
+			if summary, ok := byID[id]; ok {
+				summary.EventStreams++
+			}
+		}
+	}
+	s.eventsMu.RUnlock()
+
+	sort.Slice(resp.Agents, func(i, j int) bool { return resp.Agents[i].Id < resp.Agents[j].Id })
+
+	s.healthMu.Lock()
+	resp.Health = s.lastHealth
+	s.healthMu.Unlock()
+
+	if s.rateLimiter != nil {
+		resp.RateLimiter = rateLimiterDebug(s.rateLimiter)
+	}
+
+	return resp, nil
+}
+
+// authorizeDebug checks the caller's bearer token against the configured
+// debug token.
+func (s *Server) authorizeDebug(ctx context.Context) error {
+	if s.debugToken == "" {
+		return status.Error(codes.PermissionDenied, "debug endpoint is disabled")
+	}
+	if hasBearer(ctx, s.debugToken) {
+		return nil
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid debug token")
+}
+
+// trackTranscriptStream counts an open StreamTranscript call for agentID and
+// returns a func that releases it.
+func (s *Server) trackTranscriptStream(agentID string) func() {
+	s.streamsMu.Lock()
+	s.transcriptStreams[agentID]++
+	s.streamsMu.Unlock()
+
+	return func() {
+		s.streamsMu.Lock()
+		defer s.streamsMu.Unlock()
+		if s.transcriptStreams[agentID]--; s.transcriptStreams[agentID] <= 0 {
+			delete(s.transcriptStreams, agentID)
+		}
+	}
+}
+
+func rateLimiterDebug(rl *RateLimiter) *swarmdv1.RateLimiterDebug {
+	out := &swarmdv1.RateLimiterDebug{Enabled: rl.IsEnabled()}
+	for _, ms := range rl.Stats() {
+		out.Methods = append(out.Methods, rateLimitCounter(ms))
+	}
+	sort.Slice(out.Methods, func(i, j int) bool { return out.Methods[i].Method < out.Methods[j].Method })
+	if global := rl.GlobalStats(); global != nil {
+		out.Global = rateLimitCounter(*global)
+	}
+	return out
+}
+
+func rateLimitCounter(ms MethodStats) *swarmdv1.RateLimitCounter {
+	return &swarmdv1.RateLimitCounter{
+		Method:         ms.Method,
+		Available:      ms.Available,
+		TotalRequests:  ms.TotalRequests,
+		DeniedRequests: ms.DeniedRequests,
+	}
+}
+
+// rlockWithin read-locks mu, giving up when ctx is done.
+func rlockWithin(ctx context.Context, mu *sync.RWMutex) bool {
+	return acquireWithin(ctx, mu.TryRLock)
+}
+
+// lockWithin locks mu, giving up when ctx is done.
+func lockWithin(ctx context.Context, mu *sync.Mutex) bool {
+	return acquireWithin(ctx, mu.TryLock)
+}
+
+func acquireWithin(ctx context.Context, try func() bool) bool {
+	ticker := time.NewTicker(debugLockPoll)
+	defer ticker.Stop()
+	for !try() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
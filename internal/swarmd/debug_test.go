@@ -0,0 +1,141 @@
+package swarmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func debugContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(authKey, "Bearer "+token))
+}
+
+func TestDumpStateOmitsSecrets(t *testing.T) {
+	ctx := context.Background()
+	const (
+		envSecret     = "ENV-SECRET-7f3a"
+		commandSecret = "CMD-SECRET-91bc"
+		paneSecret    = "PANE-SECRET-c04d"
+		entrySecret   = "ENTRY-SECRET-5e2f"
+	)
+
+	srv := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("working on " + paneSecret + "\nclaude> ")
+	}))
+	server := NewServer(zerolog.Nop(), WithDebugToken("tok"), WithVersion("1.2.3"), WithBuildInfo("abc123", "2026-10-14"))
+	server.backend = newTmuxBackend(tmux.NewClient(srv), server.logger)
+	server.SetRateLimiter(NewRateLimiter())
+
+	if _, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{
+		AgentId:     "agent-1",
+		WorkspaceId: "ws-1",
+		Command:     "claude --api-key " + commandSecret,
+		Env:         map[string]string{"API_KEY": envSecret},
+	}); err != nil {
+		t.Fatalf("SpawnAgent() error = %v", err)
+	}
+	server.addTranscriptEntry("agent-1", swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_COMMAND, entrySecret, map[string]string{"note": entrySecret})
+
+	// Keep a pane stream open so its capture loop holds the pane content.
+	streamCtx, cancel := context.WithCancel(ctx)
+	recorder := newPaneUpdateRecorder(time.Second)
+	recorder.ctx = streamCtx
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = server.StreamPaneUpdates(&swarmdv1.StreamPaneUpdatesRequest{
+			AgentId:        "agent-1",
+			IncludeContent: true,
+			MinInterval:    durationpb.New(5 * time.Millisecond),
+		}, recorder)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitFor(t, func() bool {
+		server.captureMu.Lock()
+		defer server.captureMu.Unlock()
+		loop := server.captures["agent-1"]
+		return loop != nil && loop.latest != nil && strings.Contains(loop.latest.content, paneSecret)
+	})
+	server.getHealthStatus()
+
+	dump, err := server.DumpState(debugContext("tok"), &swarmdv1.DumpStateRequest{})
+	if err != nil {
+		t.Fatalf("DumpState() error = %v", err)
+	}
+	if len(dump.Agents) != 1 || dump.Agents[0].Id != "agent-1" || dump.Agents[0].PaneStreams != 1 || dump.Agents[0].TranscriptEntries == 0 {
+		t.Fatalf("unexpected agent summaries: %v", dump.Agents)
+	}
+	if dump.Build.Version != "1.2.3" || dump.Build.Commit != "abc123" || dump.Health == nil || dump.RateLimiter == nil {
+		t.Fatalf("missing build, health, or rate limiter state: %v", dump)
+	}
+
+	encoded, err := protojson.Marshal(dump)
+	if err != nil {
+		t.Fatalf("marshal dump: %v", err)
+	}
+	for _, secret := range []string{envSecret, commandSecret, paneSecret, entrySecret} {
+		if strings.Contains(string(encoded), secret) {
+			t.Errorf("dump leaks %s:\n%s", secret, encoded)
+		}
+	}
+}
+
+func TestDumpStateRequiresToken(t *testing.T) {
+	disabled := NewServer(zerolog.Nop())
+	if _, err := disabled.DumpState(debugContext(""), &swarmdv1.DumpStateRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied without a debug token, got %v", err)
+	}
+
+	server := NewServer(zerolog.Nop(), WithDebugToken("tok"))
+	for name, ctx := range map[string]context.Context{
+		"no metadata": context.Background(),
+		"wrong token": debugContext("nope"),
+		"no bearer":   metadata.NewIncomingContext(context.Background(), metadata.Pairs(authKey, "tok")),
+	} {
+		if _, err := server.DumpState(ctx, &swarmdv1.DumpStateRequest{}); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: expected Unauthenticated, got %v", name, err)
+		}
+	}
+}
+
+func TestDumpStateGivesUpOnBusyLock(t *testing.T) {
+	server := NewServer(zerolog.Nop(), WithDebugToken("tok"))
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(debugContext("tok"), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := server.DumpState(ctx, &swarmdv1.DumpStateRequest{})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable while the state lock is held, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("DumpState blocked for %s", elapsed)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,278 @@
+package swarmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+const (
+	// DefaultLivenessDeadline is how long the daemon's run loop may go
+	// without a heartbeat before /healthz reports it unresponsive.
+	DefaultLivenessDeadline = 30 * time.Second
+
+	// heartbeatInterval is how often the run loop beats.
+	heartbeatInterval = time.Second
+
+	// readinessCheckTimeout bounds each /readyz dependency check.
+	readinessCheckTimeout = 5 * time.Second
+)
+
+// Health check statuses reported by /healthz and /readyz.
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusDegraded    = "degraded"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// HealthCheckResult is one check in a /healthz or /readyz response.
+type HealthCheckResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthResponse is the JSON body of /healthz and /readyz. Status is
+// unavailable, with a 503, when any check is unavailable.
+type HealthResponse struct {
+	Status string              `json:"status"`
+	Checks []HealthCheckResult `json:"checks"`
+}
+
+// Health serves the liveness and readiness endpoints used by systemd and
+// container orchestrators.
+//
+// Liveness only asks whether the run loop still beats. Readiness also
+// checks the database is reachable and fully migrated, that tmux answers,
+// and that startup recovery has finished; it turns unavailable once the
+// daemon starts shutting down, before the gRPC server stops.
+type Health struct {
+	clock            clock.Clock
+	livenessDeadline time.Duration
+	checkDatabase    func(ctx context.Context) error
+	checkTmux        func(ctx context.Context) error
+	serveDegraded    bool
+
+	mu        sync.Mutex
+	lastBeat  time.Time
+	startedUp bool
+	draining  bool
+}
+
+// HealthOption configures a Health.
+type HealthOption func(*Health)
+
+// WithHealthClock sets the clock heartbeats are measured with.
+func WithHealthClock(c clock.Clock) HealthOption {
+	return func(h *Health) {
+		h.clock = clock.OrReal(c)
+	}
+}
+
+// WithLivenessDeadline sets how stale the last heartbeat may be before
+// /healthz fails.
+func WithLivenessDeadline(d time.Duration) HealthOption {
+	return func(h *Health) {
+		if d > 0 {
+			h.livenessDeadline = d
+		}
+	}
+}
+
+// WithDatabaseCheck sets the readiness check for the database. Without one
+// the database check is left out.
+func WithDatabaseCheck(check func(ctx context.Context) error) HealthOption {
+	return func(h *Health) {
+		h.checkDatabase = check
+	}
+}
+
+// WithTmuxCheck sets the readiness check for tmux. Without one the tmux
+// check is left out.
+func WithTmuxCheck(check func(ctx context.Context) error) HealthOption {
+	return func(h *Health) {
+		h.checkTmux = check
+	}
+}
+
+// WithServeDegraded keeps the daemon ready when tmux fails its check,
+// reporting readiness as degraded instead of unavailable.
+func WithServeDegraded(serve bool) HealthOption {
+	return func(h *Health) {
+		h.serveDegraded = serve
+	}
+}
+
+// NewHealth creates a Health. It counts as beating from creation, and is
+// not ready until MarkStarted is called.
+func NewHealth(opts ...HealthOption) *Health {
+	h := &Health{
+		clock:            clock.Real(),
+		livenessDeadline: DefaultLivenessDeadline,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.lastBeat = h.clock.Now()
+	return h
+}
+
+// Beat records that the run loop is responsive.
+func (h *Health) Beat() {
+	h.mu.Lock()
+	h.lastBeat = h.clock.Now()
+	h.mu.Unlock()
+}
+
+// MarkStarted records that startup recovery finished and the daemon is
+// serving.
+func (h *Health) MarkStarted() {
+	h.mu.Lock()
+	h.startedUp = true
+	h.mu.Unlock()
+}
+
+// StartDraining makes readiness fail so traffic moves away before the
+// daemon stops.
+func (h *Health) StartDraining() {
+	h.mu.Lock()
+	h.draining = true
+	h.mu.Unlock()
+}
+
+// Handler returns a handler serving /healthz and /readyz, for a dedicated
+// listener or for mounting next to other HTTP endpoints.
+func (h *Health) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealthResponse(w, h.Liveness())
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealthResponse(w, h.Readiness(r.Context()))
+	})
+	return mux
+}
+
+// Liveness reports whether the run loop has beaten within the deadline.
+func (h *Health) Liveness() HealthResponse {
+	h.mu.Lock()
+	since := h.clock.Now().Sub(h.lastBeat)
+	h.mu.Unlock()
+
+	check := HealthCheckResult{Name: "run_loop", Status: HealthStatusOK}
+	if since > h.livenessDeadline {
+		check.Status = HealthStatusUnavailable
+		check.Message = fmt.Sprintf("no heartbeat for %s (deadline %s)", since.Round(time.Second), h.livenessDeadline)
+	}
+	return newHealthResponse([]HealthCheckResult{check})
+}
+
+// Readiness runs the readiness checks.
+func (h *Health) Readiness(ctx context.Context) HealthResponse {
+	h.mu.Lock()
+	startedUp, draining := h.startedUp, h.draining
+	h.mu.Unlock()
+
+	var checks []HealthCheckResult
+	switch {
+	case draining:
+		checks = append(checks, HealthCheckResult{Name: "startup", Status: HealthStatusUnavailable, Message: "shutting down"})
+	case !startedUp:
+		checks = append(checks, HealthCheckResult{Name: "startup", Status: HealthStatusUnavailable, Message: "startup recovery still running"})
+	default:
+		checks = append(checks, HealthCheckResult{Name: "startup", Status: HealthStatusOK})
+	}
+
+	if h.checkDatabase != nil {
+		check := HealthCheckResult{Name: "database", Status: HealthStatusOK}
+		if err := runHealthCheck(ctx, h.checkDatabase); err != nil {
+			check.Status = HealthStatusUnavailable
+			check.Message = err.Error()
+		}
+		checks = append(checks, check)
+	}
+	if h.checkTmux != nil {
+		check := HealthCheckResult{Name: "tmux", Status: HealthStatusOK}
+		if err := runHealthCheck(ctx, h.checkTmux); err != nil {
+			check.Status = HealthStatusUnavailable
+			if h.serveDegraded {
+				check.Status = HealthStatusDegraded
+			}
+			check.Message = err.Error()
+		}
+		checks = append(checks, check)
+	}
+	return newHealthResponse(checks)
+}
+
+func runHealthCheck(ctx context.Context, check func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+	return check(ctx)
+}
+
+func newHealthResponse(checks []HealthCheckResult) HealthResponse {
+	status := HealthStatusOK
+	for _, check := range checks {
+		if check.Status == HealthStatusUnavailable {
+			status = HealthStatusUnavailable
+			break
+		}
+		if check.Status == HealthStatusDegraded {
+			status = HealthStatusDegraded
+		}
+	}
+	return HealthResponse{Status: status, Checks: checks}
+}
+
+func writeHealthResponse(w http.ResponseWriter, resp HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if resp.Status == HealthStatusUnavailable {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// databaseReadiness checks the database answers and has every migration
+// applied.
+func databaseReadiness(database *db.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if err := database.HealthCheck(ctx); err != nil {
+			return fmt.Errorf("database unreachable: %w", err)
+		}
+		status, err := database.MigrationStatus(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read migration status: %w", err)
+		}
+		pending := 0
+		for _, m := range status {
+			if !m.Applied {
+				pending++
+			}
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d migration(s) not applied; run 'swarm migrate up'", pending)
+		}
+		return nil
+	}
+}
+
+// tmuxReadiness checks tmux answers.
+func tmuxReadiness(client *tmux.Client) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if _, err := client.ListSessions(ctx); err != nil {
+			return fmt.Errorf("tmux unavailable: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,246 @@
+package swarmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/rs/zerolog"
+)
+
+func getHealth(t *testing.T, url string) (int, HealthResponse) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected a JSON body, got Content-Type %q", ct)
+	}
+	var body HealthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode %s: %v", url, err)
+	}
+	return resp.StatusCode, body
+}
+
+func checkStatus(body HealthResponse, name string) string {
+	for _, check := range body.Checks {
+		if check.Name == name {
+			return check.Status
+		}
+	}
+	return ""
+}
+
+func TestHealthLiveness(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	health := NewHealth(WithHealthClock(fake), WithLivenessDeadline(10*time.Second))
+	srv := httptest.NewServer(health.Handler())
+	defer srv.Close()
+
+	if code, body := getHealth(t, srv.URL+"/healthz"); code != http.StatusOK || body.Status != HealthStatusOK {
+		t.Fatalf("expected live, got %d %+v", code, body)
+	}
+
+	fake.Advance(11 * time.Second)
+	code, body := getHealth(t, srv.URL+"/healthz")
+	if code != http.StatusServiceUnavailable || checkStatus(body, "run_loop") != HealthStatusUnavailable {
+		t.Fatalf("expected a stalled run loop to fail liveness, got %d %+v", code, body)
+	}
+
+	health.Beat()
+	if code, _ := getHealth(t, srv.URL+"/healthz"); code != http.StatusOK {
+		t.Fatalf("expected a heartbeat to restore liveness, got %d", code)
+	}
+
+	// Liveness ignores readiness: a draining daemon is still alive.
+	health.StartDraining()
+	if code, _ := getHealth(t, srv.URL+"/healthz"); code != http.StatusOK {
+		t.Fatalf("expected draining to leave liveness alone, got %d", code)
+	}
+}
+
+func TestHealthReadiness(t *testing.T) {
+	dbDown := errors.New("disk I/O error")
+	tmuxDown := errors.New("no tmux binary")
+
+	tests := []struct {
+		name          string
+		started       bool
+		draining      bool
+		dbErr         error
+		tmuxErr       error
+		serveDegraded bool
+		wantCode      int
+		wantStatus    string
+		wantChecks    map[string]string
+	}{
+		{
+			name: "recovery running", dbErr: nil,
+			wantCode: http.StatusServiceUnavailable, wantStatus: HealthStatusUnavailable,
+			wantChecks: map[string]string{"startup": HealthStatusUnavailable, "database": HealthStatusOK, "tmux": HealthStatusOK},
+		},
+		{
+			name: "ready", started: true,
+			wantCode: http.StatusOK, wantStatus: HealthStatusOK,
+			wantChecks: map[string]string{"startup": HealthStatusOK, "database": HealthStatusOK, "tmux": HealthStatusOK},
+		},
+		{
+			name: "database down", started: true, dbErr: dbDown,
+			wantCode: http.StatusServiceUnavailable, wantStatus: HealthStatusUnavailable,
+			wantChecks: map[string]string{"database": HealthStatusUnavailable},
+		},
+		{
+			name: "tmux down", started: true, tmuxErr: tmuxDown,
+			wantCode: http.StatusServiceUnavailable, wantStatus: HealthStatusUnavailable,
+			wantChecks: map[string]string{"tmux": HealthStatusUnavailable},
+		},
+		{
+			name: "tmux down but serving degraded", started: true, tmuxErr: tmuxDown, serveDegraded: true,
+			wantCode: http.StatusOK, wantStatus: HealthStatusDegraded,
+			wantChecks: map[string]string{"tmux": HealthStatusDegraded, "database": HealthStatusOK},
+		},
+		{
+			name: "draining", started: true, draining: true,
+			wantCode: http.StatusServiceUnavailable, wantStatus: HealthStatusUnavailable,
+			wantChecks: map[string]string{"startup": HealthStatusUnavailable, "database": HealthStatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := NewHealth(
+				WithDatabaseCheck(func(context.Context) error { return tt.dbErr }),
+				WithTmuxCheck(func(context.Context) error { return tt.tmuxErr }),
+				WithServeDegraded(tt.serveDegraded),
+			)
+			if tt.started {
+				health.MarkStarted()
+			}
+			if tt.draining {
+				health.StartDraining()
+			}
+			srv := httptest.NewServer(health.Handler())
+			defer srv.Close()
+
+			code, body := getHealth(t, srv.URL+"/readyz")
+			if code != tt.wantCode || body.Status != tt.wantStatus {
+				t.Fatalf("got %d %q, want %d %q (%+v)", code, body.Status, tt.wantCode, tt.wantStatus, body.Checks)
+			}
+			for name, want := range tt.wantChecks {
+				if got := checkStatus(body, name); got != want {
+					t.Errorf("check %s: got %q, want %q", name, got, want)
+				}
+			}
+			for _, check := range body.Checks {
+				if check.Status != HealthStatusOK && check.Message == "" {
+					t.Errorf("check %s: expected a message explaining %s", check.Name, check.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestDatabaseReadinessRequiresMigrations(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.Open(db.Config{Path: filepath.Join(t.TempDir(), "swarm.db")})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer database.Close()
+
+	check := databaseReadiness(database)
+	if err := check(ctx); err == nil {
+		t.Fatal("expected an unmigrated database to fail readiness")
+	}
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if err := check(ctx); err != nil {
+		t.Fatalf("expected a migrated database to be ready, got %v", err)
+	}
+}
+
+func TestSDNotify(t *testing.T) {
+	t.Setenv(NotifySocketEnv, "")
+	if notified, err := sdNotify(sdNotifyReady); notified || err != nil {
+		t.Fatalf("expected a no-op outside systemd, got %v (%v)", notified, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer conn.Close()
+
+	t.Setenv(NotifySocketEnv, path)
+	if notified, err := sdNotify(sdNotifyReady); !notified || err != nil {
+		t.Fatalf("expected the notification sent, got %v (%v)", notified, err)
+	}
+	buf := make([]byte, 64)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if got := string(buf[:n]); got != "READY=1" {
+		t.Fatalf("expected READY=1, got %q", got)
+	}
+}
+
+func TestDaemonServesHealthEndpoints(t *testing.T) {
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	healthAddr := probe.Addr().String()
+	probe.Close()
+
+	daemon, err := New(config.DefaultConfig(), zerolog.Nop(), Options{
+		Port:          50098,
+		HealthAddr:    healthAddr,
+		ServeDegraded: true, // the test host may have no tmux
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- daemon.Run(ctx) }()
+
+	url := "http://" + healthAddr + "/readyz"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daemon never became ready (last error %v)", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if ready := daemon.Health().Readiness(context.Background()); ready.Status != HealthStatusUnavailable {
+		t.Fatalf("expected readiness to fail after shutdown, got %+v", ready)
+	}
+}
@@ -0,0 +1,129 @@
+package swarmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// maxLongPollWait caps how long CapturePane and GetAgent hold a long poll,
+// so a client that asks for more re-polls rather than pinning the request.
+const maxLongPollWait = 60 * time.Second
+
+// longPollWait returns the requested max_wait within the server's cap. An
+// unset wait takes the cap.
+func longPollWait(d *durationpb.Duration) time.Duration {
+	wait := d.AsDuration()
+	if wait <= 0 || wait > maxLongPollWait {
+		return maxLongPollWait
+	}
+	return wait
+}
+
+// notifyStateLocked wakes the agent's state waiters. The caller must hold
+// a.mu.
+func (a *agentInfo) notifyStateLocked() {
+	if a.stateChanged != nil {
+		close(a.stateChanged)
+		a.stateChanged = nil
+	}
+}
+
+// stateWatchLocked returns a channel closed at the agent's next state
+// change or removal. Waiters share it, so any number of them cost one
+// channel. The caller must hold a.mu.
+func (a *agentInfo) stateWatchLocked() <-chan struct{} {
+	if a.stateChanged == nil {
+		a.stateChanged = make(chan struct{})
+	}
+	return a.stateChanged
+}
+
+// waitForStateChange holds until the agent is in a state other than state
+// or wait elapses, and returns the agent locked either way.
+func (s *Server) waitForStateChange(ctx context.Context, agentID string, state swarmdv1.AgentState, wait time.Duration) (*agentInfo, error) {
+	timer := s.clock.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		info, exists := s.lockAgent(agentID)
+		if !exists {
+			return nil, status.Errorf(codes.NotFound, "agent %q no longer exists", agentID)
+		}
+		if info.state != state {
+			return info, nil
+		}
+		changed := info.stateWatchLocked()
+		info.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-timer.C():
+			info, exists := s.lockAgent(agentID)
+			if !exists {
+				return nil, status.Errorf(codes.NotFound, "agent %q no longer exists", agentID)
+			}
+			return info, nil
+		case <-changed:
+		}
+	}
+}
+
+// capturePaneLines captures lines of the pane as CapturePane's lines
+// field describes them.
+func (s *Server) capturePaneLines(ctx context.Context, paneID string, lines int32) (string, bool, error) {
+	var (
+		content   string
+		truncated bool
+		err       error
+	)
+	switch {
+	case lines > 0:
+		content, truncated, err = s.backend.CapturePaneRange(ctx, paneID, int(lines))
+	default:
+		content, err = s.backend.CapturePane(ctx, paneID, lines < 0)
+	}
+	if err != nil {
+		return "", false, status.Errorf(codes.Internal, "failed to capture pane: %v", err)
+	}
+	return content, truncated, nil
+}
+
+// waitForPaneChange holds until a capture of lines hashes differently from
+// hash or wait elapses, and returns the last capture. It rides the agent's
+// shared capture loop, re-capturing only when the loop sees the pane
+// change, so waiters add no polling of their own.
+func (s *Server) waitForPaneChange(ctx context.Context, info *agentInfo, lines int32, hash string, wait time.Duration) (string, bool, error) {
+	sub := s.subscribeCapture(info.id, defaultPollInterval, 0)
+	defer s.unsubscribeCapture(sub)
+
+	timer := s.clock.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", false, status.FromContextError(ctx.Err()).Err()
+		case <-timer.C():
+			return s.capturePaneLines(ctx, info.paneID, lines)
+		case _, ok := <-sub.updates:
+			if !ok {
+				return "", false, status.Errorf(codes.NotFound, "agent %q no longer exists", info.id)
+			}
+		}
+
+		content, truncated, err := s.capturePaneLines(ctx, info.paneID, lines)
+		if err != nil {
+			return "", false, err
+		}
+		if tmux.HashSnapshot(content) != hash {
+			return content, truncated, nil
+		}
+	}
+}
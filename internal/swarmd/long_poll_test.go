@@ -0,0 +1,259 @@
+package swarmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newLongPollServer(t *testing.T, output string, state swarmdv1.AgentState) (*Server, *tmuxtest.Server, string) {
+	t.Helper()
+	server := NewServer(zerolog.Nop())
+	srv, paneID := newPaneServer(t, output)
+	server.backend = newTmuxBackend(tmux.NewClient(srv), server.logger)
+	server.mu.Lock()
+	server.agents["agent-1"] = &agentInfo{id: "agent-1", paneID: paneID, state: state}
+	server.mu.Unlock()
+	return server, srv, paneID
+}
+
+// waitUntil polls cond, for tests waiting on a long poll to block.
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func captureWaiting(server *Server) bool {
+	server.captureMu.Lock()
+	defer server.captureMu.Unlock()
+	loop, ok := server.captures["agent-1"]
+	return ok && loop.latest != nil
+}
+
+func stateWaiting(server *Server) bool {
+	info, ok := server.lockAgent("agent-1")
+	if !ok {
+		return false
+	}
+	defer info.mu.Unlock()
+	return info.stateChanged != nil
+}
+
+func TestLongPollWait(t *testing.T) {
+	tests := []struct {
+		wait *durationpb.Duration
+		want time.Duration
+	}{
+		{nil, maxLongPollWait},
+		{durationpb.New(0), maxLongPollWait},
+		{durationpb.New(5 * time.Second), 5 * time.Second},
+		{durationpb.New(10 * time.Minute), maxLongPollWait},
+	}
+	for _, tt := range tests {
+		if got := longPollWait(tt.wait); got != tt.want {
+			t.Errorf("longPollWait(%v) = %s, want %s", tt.wait.AsDuration(), got, tt.want)
+		}
+	}
+}
+
+func TestCapturePaneWaitForChange(t *testing.T) {
+	t.Run("returns at once when already changed", func(t *testing.T) {
+		server, _, _ := newLongPollServer(t, "first", swarmdv1.AgentState_AGENT_STATE_RUNNING)
+		resp, err := server.CapturePane(context.Background(), &swarmdv1.CapturePaneRequest{
+			AgentId:           "agent-1",
+			WaitForChangeFrom: "stale-hash",
+			MaxWait:           durationpb.New(time.Minute),
+		})
+		if err != nil || !strings.Contains(resp.Content, "first") {
+			t.Fatalf("CapturePane() = %v, %v", resp, err)
+		}
+		if n := captureLoopCount(server); n != 0 {
+			t.Errorf("expected no capture loop for a changed pane, %d running", n)
+		}
+	})
+
+	t.Run("times out with the unchanged pane", func(t *testing.T) {
+		server, srv, paneID := newLongPollServer(t, "first", swarmdv1.AgentState_AGENT_STATE_RUNNING)
+		hash := captureHash(t, srv, paneID)
+		resp, err := server.CapturePane(context.Background(), &swarmdv1.CapturePaneRequest{
+			AgentId:           "agent-1",
+			WaitForChangeFrom: hash,
+			MaxWait:           durationpb.New(20 * time.Millisecond),
+		})
+		if err != nil || resp.ContentHash != hash {
+			t.Fatalf("expected the unchanged hash after the wait, got %v, %v", resp, err)
+		}
+		if n := captureLoopCount(server); n != 0 {
+			t.Errorf("expected the capture loop to stop with the wait, %d running", n)
+		}
+	})
+
+	t.Run("returns the change made during the wait", func(t *testing.T) {
+		server, srv, paneID := newLongPollServer(t, "first", swarmdv1.AgentState_AGENT_STATE_RUNNING)
+		hash := captureHash(t, srv, paneID)
+
+		type result struct {
+			resp *swarmdv1.CapturePaneResponse
+			err  error
+		}
+		results := make(chan result, 1)
+		go func() {
+			resp, err := server.CapturePane(context.Background(), &swarmdv1.CapturePaneRequest{
+				AgentId:           "agent-1",
+				WaitForChangeFrom: hash,
+				MaxWait:           durationpb.New(10 * time.Second),
+			})
+			results <- result{resp, err}
+		}()
+		waitUntil(t, "the capture to wait", func() bool { return captureWaiting(server) })
+		if err := srv.Print(paneID, "\nsecond"); err != nil {
+			t.Fatalf("failed to print to pane: %v", err)
+		}
+
+		select {
+		case r := <-results:
+			if r.err != nil || r.resp.ContentHash == hash || !strings.Contains(r.resp.Content, "second") {
+				t.Fatalf("expected the changed pane, got %v, %v", r.resp, r.err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("CapturePane did not return after the pane changed")
+		}
+	})
+
+	t.Run("agent killed during the wait", func(t *testing.T) {
+		server, srv, paneID := newLongPollServer(t, "first", swarmdv1.AgentState_AGENT_STATE_RUNNING)
+		errs := make(chan error, 1)
+		go func() {
+			_, err := server.CapturePane(context.Background(), &swarmdv1.CapturePaneRequest{
+				AgentId:           "agent-1",
+				WaitForChangeFrom: captureHash(t, srv, paneID),
+				MaxWait:           durationpb.New(10 * time.Second),
+			})
+			errs <- err
+		}()
+		waitUntil(t, "the capture to wait", func() bool { return captureWaiting(server) })
+		if _, err := server.KillAgent(context.Background(), &swarmdv1.KillAgentRequest{AgentId: "agent-1", Force: true}); err != nil {
+			t.Fatalf("KillAgent() error = %v", err)
+		}
+
+		select {
+		case err := <-errs:
+			if status.Code(err) != codes.NotFound {
+				t.Fatalf("expected NotFound, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("CapturePane did not return after the agent was killed")
+		}
+	})
+}
+
+func TestGetAgentWaitForState(t *testing.T) {
+	const idle = swarmdv1.AgentState_AGENT_STATE_IDLE
+	const running = swarmdv1.AgentState_AGENT_STATE_RUNNING
+
+	t.Run("returns at once in another state", func(t *testing.T) {
+		server, _, _ := newLongPollServer(t, "first", running)
+		resp, err := server.GetAgent(context.Background(), &swarmdv1.GetAgentRequest{
+			AgentId:               "agent-1",
+			WaitForStateOtherThan: idle,
+			MaxWait:               durationpb.New(time.Minute),
+		})
+		if err != nil || resp.Agent.State != running {
+			t.Fatalf("GetAgent() = %v, %v", resp, err)
+		}
+	})
+
+	t.Run("times out in the same state", func(t *testing.T) {
+		server, _, _ := newLongPollServer(t, "first", idle)
+		resp, err := server.GetAgent(context.Background(), &swarmdv1.GetAgentRequest{
+			AgentId:               "agent-1",
+			WaitForStateOtherThan: idle,
+			MaxWait:               durationpb.New(20 * time.Millisecond),
+		})
+		if err != nil || resp.Agent.State != idle {
+			t.Fatalf("expected the agent still idle after the wait, got %v, %v", resp, err)
+		}
+	})
+
+	t.Run("wakes every waiter on a state change", func(t *testing.T) {
+		server, _, _ := newLongPollServer(t, "first", idle)
+
+		const waiters = 32
+		var wg sync.WaitGroup
+		states := make(chan swarmdv1.AgentState, waiters)
+		for range waiters {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := server.GetAgent(context.Background(), &swarmdv1.GetAgentRequest{
+					AgentId:               "agent-1",
+					WaitForStateOtherThan: idle,
+					MaxWait:               durationpb.New(10 * time.Second),
+				})
+				if err != nil {
+					t.Errorf("GetAgent() error = %v", err)
+					return
+				}
+				states <- resp.Agent.State
+			}()
+		}
+		waitUntil(t, "the waiters to block", func() bool { return stateWaiting(server) })
+
+		// The capture loop's path: new pane content detected as running.
+		server.recordSnapshot("agent-1", paneSnapshot{content: "Working...", hash: "changed", state: running})
+		wg.Wait()
+		close(states)
+		count := 0
+		for state := range states {
+			if state != running {
+				t.Errorf("expected the new state, got %s", state)
+			}
+			count++
+		}
+		if count != waiters {
+			t.Errorf("expected %d waiters to return, got %d", waiters, count)
+		}
+	})
+
+	t.Run("agent killed during the wait", func(t *testing.T) {
+		server, _, _ := newLongPollServer(t, "first", idle)
+		errs := make(chan error, 1)
+		go func() {
+			_, err := server.GetAgent(context.Background(), &swarmdv1.GetAgentRequest{
+				AgentId:               "agent-1",
+				WaitForStateOtherThan: idle,
+				MaxWait:               durationpb.New(10 * time.Second),
+			})
+			errs <- err
+		}()
+		waitUntil(t, "the waiter to block", func() bool { return stateWaiting(server) })
+		if _, err := server.KillAgent(context.Background(), &swarmdv1.KillAgentRequest{AgentId: "agent-1", Force: true}); err != nil {
+			t.Fatalf("KillAgent() error = %v", err)
+		}
+
+		select {
+		case err := <-errs:
+			if status.Code(err) != codes.NotFound {
+				t.Fatalf("expected NotFound, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("GetAgent did not return after the agent was killed")
+		}
+	})
+}
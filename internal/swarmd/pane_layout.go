@@ -0,0 +1,83 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResizePane sets the size of an agent's pane and reports the size it
+// ended up with. The agent's capture loop recaptures right away, since
+// the content rewraps.
+func (s *Server) ResizePane(ctx context.Context, req *swarmdv1.ResizePaneRequest) (*swarmdv1.ResizePaneResponse, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.Width < 0 || req.Height < 0 || (req.Width == 0 && req.Height == 0) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid pane size %dx%d: set a positive width, height, or both", req.Width, req.Height)
+	}
+
+	info, exists := s.lookupAgent(req.AgentId)
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "agent %q not found", req.AgentId)
+	}
+
+	width, height, err := s.backend.ResizePane(ctx, info.paneID, int(req.Width), int(req.Height))
+	if err != nil {
+		switch {
+		case errors.Is(err, tmux.ErrPaneNotFound):
+			return nil, status.Errorf(codes.NotFound, "pane %q of agent %q no longer exists", info.paneID, req.AgentId)
+		case errors.Is(err, ErrLayoutUnsupported):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to resize pane: %v", err)
+	}
+	s.pokeCapture(req.AgentId)
+
+	s.logger.Debug().
+		Ctx(ctx).
+		Str("agent_id", req.AgentId).
+		Int("width", width).
+		Int("height", height).
+		Msg("resized pane")
+
+	return &swarmdv1.ResizePaneResponse{Width: int32(width), Height: int32(height)}, nil
+}
+
+// SetPaneLayout applies a tmux layout to a session's current window. Every
+// streamed pane is recaptured right away, since the layout may have
+// resized it.
+func (s *Server) SetPaneLayout(ctx context.Context, req *swarmdv1.SetPaneLayoutRequest) (*swarmdv1.SetPaneLayoutResponse, error) {
+	if req.Session == "" {
+		return nil, status.Error(codes.InvalidArgument, "session is required")
+	}
+	if req.Layout == "" {
+		return nil, status.Error(codes.InvalidArgument, "layout is required")
+	}
+
+	if err := s.backend.SelectLayout(ctx, req.Session, req.Layout); err != nil {
+		switch {
+		case errors.Is(err, tmux.ErrPaneNotFound):
+			return nil, status.Errorf(codes.NotFound, "session %q not found", req.Session)
+		case errors.Is(err, ErrLayoutUnsupported):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to set layout %q: %v", req.Layout, err)
+	}
+
+	s.captureMu.Lock()
+	agentIDs := make([]string, 0, len(s.captures))
+	for id := range s.captures {
+		agentIDs = append(agentIDs, id)
+	}
+	s.captureMu.Unlock()
+	for _, id := range agentIDs {
+		s.pokeCapture(id)
+	}
+
+	return &swarmdv1.SetPaneLayoutResponse{}, nil
+}
@@ -0,0 +1,107 @@
+package swarmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResizePane(t *testing.T) {
+	ctx := context.Background()
+	srv := tmuxtest.NewServer()
+	client := tmux.NewClient(srv)
+	server := NewServer(zerolog.Nop(), WithTmuxClient(client))
+
+	spawned, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{
+		AgentId:     "agent-1",
+		SessionName: "ws",
+		Command:     "claude",
+		PaneWidth:   120,
+		PaneHeight:  40,
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent() error = %v", err)
+	}
+	paneID := spawned.Agent.PaneId
+	if w, h, err := client.PaneSize(ctx, paneID); err != nil || w != 120 || h != 40 {
+		t.Fatalf("expected the pane spawned at 120x40, got %dx%d (%v)", w, h, err)
+	}
+
+	resp, err := server.ResizePane(ctx, &swarmdv1.ResizePaneRequest{AgentId: "agent-1", Height: 30})
+	if err != nil {
+		t.Fatalf("ResizePane() error = %v", err)
+	}
+	if resp.Width != 120 || resp.Height != 30 {
+		t.Fatalf("expected 120x30 after resizing the height, got %dx%d", resp.Width, resp.Height)
+	}
+
+	for _, req := range []*swarmdv1.ResizePaneRequest{
+		{Width: 80},
+		{AgentId: "agent-1"},
+		{AgentId: "agent-1", Width: -1, Height: 30},
+	} {
+		if _, err := server.ResizePane(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%+v: expected InvalidArgument, got %v", req, err)
+		}
+	}
+	if _, err := server.ResizePane(ctx, &swarmdv1.ResizePaneRequest{AgentId: "missing", Width: 80}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown agent, got %v", err)
+	}
+
+	// The pane is gone while the agent is still registered.
+	if _, err := srv.Run("kill-pane", "-t", paneID); err != nil {
+		t.Fatalf("kill-pane failed: %v", err)
+	}
+	if _, err := server.ResizePane(ctx, &swarmdv1.ResizePaneRequest{AgentId: "agent-1", Width: 80}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a pane that no longer exists, got %v", err)
+	}
+}
+
+func TestSetPaneLayout(t *testing.T) {
+	ctx := context.Background()
+	srv := tmuxtest.NewServer()
+	server := NewServer(zerolog.Nop(), WithTmuxClient(tmux.NewClient(srv)))
+
+	for _, id := range []string{"agent-1", "agent-2"} {
+		if _, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: id, SessionName: "ws", Command: "claude"}); err != nil {
+			t.Fatalf("SpawnAgent(%s) error = %v", id, err)
+		}
+	}
+	srv.ResetCommands()
+
+	if _, err := server.SetPaneLayout(ctx, &swarmdv1.SetPaneLayoutRequest{Session: "ws", Layout: "tiled"}); err != nil {
+		t.Fatalf("SetPaneLayout() error = %v", err)
+	}
+	if cmds := strings.Join(srv.Commands(), "\n"); !strings.Contains(cmds, "select-layout -t 'ws' 'tiled'") {
+		t.Errorf("expected the session's window to be laid out, got %q", cmds)
+	}
+
+	for _, req := range []*swarmdv1.SetPaneLayoutRequest{{Layout: "tiled"}, {Session: "ws"}} {
+		if _, err := server.SetPaneLayout(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%+v: expected InvalidArgument, got %v", req, err)
+		}
+	}
+	if _, err := server.SetPaneLayout(ctx, &swarmdv1.SetPaneLayoutRequest{Session: "gone", Layout: "tiled"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a missing session, got %v", err)
+	}
+}
+
+func TestPaneLayoutUnsupportedByRunnerBackend(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(zerolog.Nop(), WithExecutionBackend(newRunnerBackend()))
+	server.agents["agent-1"] = &agentInfo{id: "agent-1", paneID: RunnerPanePrefix + "agent-1"}
+
+	if _, err := server.ResizePane(ctx, &swarmdv1.ResizePaneRequest{AgentId: "agent-1", Width: 80}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition resizing a runner pane, got %v", err)
+	}
+	if _, err := server.SetPaneLayout(ctx, &swarmdv1.SetPaneLayoutRequest{Session: "ws", Layout: "tiled"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition laying out runner panes, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+//go:build linux
+
+package swarmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// hostProcesses reads the process table from /proc.
+type hostProcesses struct{}
+
+// procStat holds the fields of /proc/<pid>/stat the process table uses.
+type procStat struct {
+	pgrp    int
+	session int
+	tpgid   int
+}
+
+func readProcStat(pid int) (procStat, error) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return procStat{}, err
+	}
+	// The command name is in parentheses and may hold spaces; the fields
+	// after it start with the state.
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 6 {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	// state ppid pgrp session tty_nr tpgid ...
+	var nums [6]int
+	for i := 1; i < len(nums); i++ {
+		if nums[i], err = strconv.Atoi(fields[i]); err != nil {
+			return procStat{}, fmt.Errorf("malformed stat for pid %d: %w", pid, err)
+		}
+	}
+	return procStat{pgrp: nums[2], session: nums[3], tpgid: nums[5]}, nil
+}
+
+func (hostProcesses) Foreground(sessionPID int) (int, error) {
+	leader, err := readProcStat(sessionPID)
+	if err != nil {
+		return 0, err
+	}
+	if leader.tpgid <= 0 || leader.tpgid == leader.pgrp {
+		return sessionPID, nil
+	}
+	// The foreground group's leader is its first process; a group whose
+	// leader has exited is left to the next refresh.
+	fg, err := readProcStat(leader.tpgid)
+	if err != nil || fg.session != sessionPID {
+		return sessionPID, nil
+	}
+	return leader.tpgid, nil
+}
+
+func (hostProcesses) Kill(pid, sessionPID int) error {
+	st, err := readProcStat(pid)
+	if err != nil {
+		return err
+	}
+	if st.session != sessionPID {
+		return fmt.Errorf("process %d is no longer in session %d", pid, sessionPID)
+	}
+	if st.pgrp == pid {
+		return syscall.Kill(-pid, syscall.SIGKILL)
+	}
+	return syscall.Kill(pid, syscall.SIGKILL)
+}
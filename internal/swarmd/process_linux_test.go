@@ -0,0 +1,51 @@
+//go:build linux
+
+package swarmd
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestReadProcStat(t *testing.T) {
+	st, err := readProcStat(os.Getpid())
+	if err != nil {
+		t.Fatalf("readProcStat() error = %v", err)
+	}
+	sid, err := unix.Getsid(0)
+	if err != nil {
+		t.Fatalf("Getsid() error = %v", err)
+	}
+	if st.pgrp != syscall.Getpgrp() || st.session != sid {
+		t.Fatalf("expected pgrp %d and session %d, got %+v", syscall.Getpgrp(), sid, st)
+	}
+}
+
+func TestHostProcessesKillChecksSession(t *testing.T) {
+	cmd := exec.Command("sleep", "60")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start sleep: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	// Not in the session given: left running.
+	if err := (hostProcesses{}).Kill(cmd.Process.Pid, os.Getpid()); err == nil {
+		t.Fatal("expected a process outside the session to be refused")
+	}
+	if fg, err := (hostProcesses{}).Foreground(cmd.Process.Pid); err != nil || fg != cmd.Process.Pid {
+		t.Fatalf("expected a session without a terminal to have its leader in the foreground, got %d (%v)", fg, err)
+	}
+
+	if err := (hostProcesses{}).Kill(cmd.Process.Pid, cmd.Process.Pid); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	err := cmd.Wait()
+	if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); !ok || !ws.Signaled() || ws.Signal() != syscall.SIGKILL {
+		t.Fatalf("expected sleep killed by SIGKILL, got %v", err)
+	}
+}
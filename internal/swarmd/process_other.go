@@ -0,0 +1,15 @@
+//go:build !linux
+
+package swarmd
+
+// hostProcesses has no process table to read outside Linux: an agent's
+// process is its pane's, which KillPane stops.
+type hostProcesses struct{}
+
+func (hostProcesses) Foreground(sessionPID int) (int, error) {
+	return sessionPID, nil
+}
+
+func (hostProcesses) Kill(pid, sessionPID int) error {
+	return nil
+}
@@ -0,0 +1,54 @@
+//go:build !windows
+
+package swarmd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// stopProcess suspends pid with SIGSTOP.
+func stopProcess(pid int) error {
+	return signalProcess(pid, syscall.SIGSTOP)
+}
+
+// continueProcess resumes pid with SIGCONT.
+func continueProcess(pid int) error {
+	return signalProcess(pid, syscall.SIGCONT)
+}
+
+func signalProcess(pid int, sig syscall.Signal) error {
+	if pid <= 0 {
+		return fmt.Errorf("invalid pid %d", pid)
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Signal(sig)
+}
+
+// diskSpace returns the total size of the filesystem holding path and the
+// bytes available to unprivileged users.
+func diskSpace(path string) (total, free uint64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return stat.Blocks * uint64(stat.Bsize), stat.Bavail * uint64(stat.Bsize), nil
+}
+
+// peakMemoryBytes returns the daemon's peak resident set size.
+func peakMemoryBytes() (int64, error) {
+	var rusage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &rusage); err != nil {
+		return 0, err
+	}
+	return rusage.Maxrss * 1024, nil // maxrss is in KB on Linux
+}
+
+// shellCommand runs cmdLine the way a tmux pane's shell would.
+func shellCommand(cmdLine string) []string {
+	return []string{"/bin/sh", "-c", cmdLine}
+}
@@ -0,0 +1,48 @@
+//go:build windows
+
+package swarmd
+
+import (
+	"errors"
+	"runtime"
+
+	"golang.org/x/sys/windows"
+)
+
+// errNoJobControl is returned for process suspension, which Windows has no
+// signal for; disk pressure leaves agents running there.
+var errNoJobControl = errors.New("suspending processes is not supported on windows")
+
+func stopProcess(pid int) error {
+	return errNoJobControl
+}
+
+func continueProcess(pid int) error {
+	return errNoJobControl
+}
+
+// diskSpace returns the total size of the volume holding path and the bytes
+// available to the daemon's user.
+func diskSpace(path string) (total, free uint64, err error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := windows.GetDiskFreeSpaceEx(name, &free, &total, nil); err != nil {
+		return 0, 0, err
+	}
+	return total, free, nil
+}
+
+// peakMemoryBytes approximates the daemon's memory with what the Go
+// runtime has obtained from the system.
+func peakMemoryBytes() (int64, error) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.Sys), nil
+}
+
+// shellCommand runs cmdLine through cmd.exe.
+func shellCommand(cmdLine string) []string {
+	return []string{"cmd.exe", "/C", cmdLine}
+}
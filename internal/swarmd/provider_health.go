@@ -0,0 +1,149 @@
+package swarmd
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/providerhealth"
+	"github.com/rs/zerolog"
+)
+
+// ProviderMonitor periodically probes the providers of the accounts in the
+// database and records their health changes in its event log.
+type ProviderMonitor struct {
+	prober *providerhealth.Prober
+	clock  clock.Clock
+	client *http.Client
+	logger zerolog.Logger
+
+	// mu guards the interval Reconfigure replaces.
+	mu           sync.Mutex
+	interval     time.Duration
+	reconfigured chan struct{}
+}
+
+// ProviderMonitorOption configures a ProviderMonitor.
+type ProviderMonitorOption func(*ProviderMonitor)
+
+// WithProviderMonitorClock sets the time source probes are scheduled and
+// timed against.
+func WithProviderMonitorClock(c clock.Clock) ProviderMonitorOption {
+	return func(m *ProviderMonitor) {
+		m.clock = c
+	}
+}
+
+// WithProviderMonitorHTTPClient sets the client probes are sent with.
+func WithProviderMonitorHTTPClient(client *http.Client) ProviderMonitorOption {
+	return func(m *ProviderMonitor) {
+		m.client = client
+	}
+}
+
+// NewProviderMonitor creates a monitor probing the providers of database's
+// accounts with settings. A zero settings.Interval disables probing.
+func NewProviderMonitor(database *db.DB, settings config.ProviderHealthConfig, logger zerolog.Logger, opts ...ProviderMonitorOption) *ProviderMonitor {
+	m := &ProviderMonitor{
+		interval:     settings.Interval,
+		logger:       logger,
+		reconfigured: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.clock = clock.OrReal(m.clock)
+
+	publisher := events.NewInMemoryPublisher(events.WithRepository(db.NewEventRepository(database)))
+	proberOpts := []providerhealth.Option{
+		providerhealth.WithClock(m.clock),
+		providerhealth.WithPublisher(publisher),
+		providerhealth.WithLogger(logger),
+	}
+	if m.client != nil {
+		proberOpts = append(proberOpts, providerhealth.WithHTTPClient(m.client))
+	}
+	m.prober = providerhealth.New(db.NewAccountRepository(database), providerhealth.SettingsFromConfig(settings), proberOpts...)
+	return m
+}
+
+// Prober returns the prober holding the providers' health, for components
+// that hold work while a provider is down.
+func (m *ProviderMonitor) Prober() *providerhealth.Prober {
+	return m.prober
+}
+
+// Reconfigure applies the provider health settings of cfg. Health already
+// tracked is kept; a running loop probes again at once and then every new
+// interval.
+func (m *ProviderMonitor) Reconfigure(cfg *config.Config) error {
+	settings := cfg.Daemon.ProviderHealth
+	m.prober.Reconfigure(providerhealth.SettingsFromConfig(settings))
+	m.mu.Lock()
+	m.interval = settings.Interval
+	m.mu.Unlock()
+
+	select {
+	case m.reconfigured <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (m *ProviderMonitor) currentInterval() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.interval
+}
+
+// Run probes immediately and then every interval until ctx is canceled.
+// While the interval is zero it only waits for a reconfigure.
+func (m *ProviderMonitor) Run(ctx context.Context) {
+	for {
+		interval := m.currentInterval()
+		if interval <= 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.reconfigured:
+				continue
+			}
+		}
+		if !m.runEvery(ctx, interval) {
+			return
+		}
+	}
+}
+
+// runEvery probes now and then every interval. It returns false once ctx
+// is canceled and true on a reconfigure.
+func (m *ProviderMonitor) runEvery(ctx context.Context, interval time.Duration) bool {
+	ticker := m.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.Probe(ctx)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C():
+		case <-m.reconfigured:
+			return true
+		}
+	}
+}
+
+// Probe checks every provider once.
+func (m *ProviderMonitor) Probe(ctx context.Context) ([]providerhealth.Status, error) {
+	statuses, err := m.prober.Probe(ctx)
+	if err != nil {
+		m.logger.Warn().Ctx(ctx).Err(err).Msg("failed to probe provider health")
+		return nil, err
+	}
+	return statuses, nil
+}
@@ -0,0 +1,88 @@
+package swarmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestProviderMonitorRecordsHealthChanges(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+	acct := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "primary", CredentialRef: "literal:sk-test", IsActive: true}
+	if err := db.NewAccountRepository(database).Create(ctx, acct); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Daemon.ProviderHealth.FailureThreshold = 1
+	cfg.Daemon.ProviderHealth.Endpoints = map[string]config.ProviderEndpointConfig{
+		"anthropic": {API: server.URL, StatusPage: config.ProviderEndpointNone},
+	}
+	fake := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	monitor := NewProviderMonitor(database, cfg.Daemon.ProviderHealth, zerolog.Nop(),
+		WithProviderMonitorClock(fake),
+		WithProviderMonitorHTTPClient(server.Client()),
+	)
+
+	if _, err := monitor.Probe(ctx); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	status.Store(http.StatusBadGateway)
+	if _, err := monitor.Probe(ctx); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if !monitor.Prober().IsDown(models.ProviderAnthropic) {
+		t.Fatal("expected anthropic down after one failure")
+	}
+
+	// Recovery takes the configured three probes unless reconfigured.
+	status.Store(http.StatusOK)
+	cfg.Daemon.ProviderHealth.RecoveryThreshold = 1
+	if err := monitor.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if _, err := monitor.Probe(ctx); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if monitor.Prober().IsDown(models.ProviderAnthropic) {
+		t.Fatal("expected anthropic recovered")
+	}
+
+	eventType := models.EventTypeProviderHealthChanged
+	page, err := db.NewEventRepository(database).Query(ctx, db.EventQuery{Type: &eventType})
+	if err != nil {
+		t.Fatalf("failed to query events: %v", err)
+	}
+	if len(page.Events) != 3 {
+		t.Fatalf("expected 3 health changes recorded, got %d", len(page.Events))
+	}
+	for _, event := range page.Events {
+		if event.EntityType != models.EntityTypeSystem || event.EntityID != string(models.ProviderAnthropic) {
+			t.Fatalf("unexpected event entity %s/%s", event.EntityType, event.EntityID)
+		}
+	}
+}
@@ -3,9 +3,13 @@ package swarmd
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/config"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -29,22 +33,101 @@ var DefaultRateLimits = map[string]RateLimitConfig{
 	// Input operations - moderate limits
 	"/swarmd.v1.SwarmdService/SendInput": {RequestsPerSecond: 50, BurstSize: 100},
 
+	// Pane layout - each call runs tmux and recaptures panes
+	"/swarmd.v1.SwarmdService/ResizePane":    {RequestsPerSecond: 10, BurstSize: 20},
+	"/swarmd.v1.SwarmdService/SetPaneLayout": {RequestsPerSecond: 5, BurstSize: 10},
+
 	// Read operations - higher limits
 	"/swarmd.v1.SwarmdService/ListAgents":  {RequestsPerSecond: 100, BurstSize: 200},
 	"/swarmd.v1.SwarmdService/GetAgent":    {RequestsPerSecond: 100, BurstSize: 200},
 	"/swarmd.v1.SwarmdService/CapturePane": {RequestsPerSecond: 50, BurstSize: 100},
 
 	// Transcript operations
-	"/swarmd.v1.SwarmdService/GetTranscript": {RequestsPerSecond: 50, BurstSize: 100},
+	"/swarmd.v1.SwarmdService/GetTranscript":      {RequestsPerSecond: 50, BurstSize: 100},
+	"/swarmd.v1.SwarmdService/CompactTranscripts": {RequestsPerSecond: 1, BurstSize: 5},
 
 	// Health/status - very high limits (essentially unlimited)
 	"/swarmd.v1.SwarmdService/GetStatus": {RequestsPerSecond: 1000, BurstSize: 1000},
 	"/swarmd.v1.SwarmdService/Ping":      {RequestsPerSecond: 1000, BurstSize: 1000},
 
 	// Streaming operations - limit connection rate, not message rate
-	"/swarmd.v1.SwarmdService/StreamPaneUpdates": {RequestsPerSecond: 10, BurstSize: 20},
-	"/swarmd.v1.SwarmdService/StreamEvents":      {RequestsPerSecond: 10, BurstSize: 20},
-	"/swarmd.v1.SwarmdService/StreamTranscript":  {RequestsPerSecond: 10, BurstSize: 20},
+	"/swarmd.v1.SwarmdService/StreamPaneUpdates":      {RequestsPerSecond: 10, BurstSize: 20},
+	"/swarmd.v1.SwarmdService/StreamWorkspaceUpdates": {RequestsPerSecond: 10, BurstSize: 20},
+	"/swarmd.v1.SwarmdService/StreamEvents":           {RequestsPerSecond: 10, BurstSize: 20},
+	"/swarmd.v1.SwarmdService/StreamTranscript":       {RequestsPerSecond: 10, BurstSize: 20},
+
+	// Debugging - state dumps walk every agent
+	"/swarmd.v1.SwarmdService/DumpState": {RequestsPerSecond: 1, BurstSize: 5},
+
+	// Configuration - reloads re-read and validate the config file
+	"/swarmd.v1.SwarmdService/ReloadConfig": {RequestsPerSecond: 1, BurstSize: 5},
+}
+
+// RateLimitSettings is a complete rate limiting setup.
+type RateLimitSettings struct {
+	// Enabled enables rate limiting.
+	Enabled bool
+
+	// Methods overrides DefaultRateLimits for the methods it lists, keyed
+	// by full gRPC method name.
+	Methods map[string]RateLimitConfig
+
+	// Global is the limit across all methods, or nil for none.
+	Global *RateLimitConfig
+}
+
+// Limit returns the limit that applies to method.
+func (s RateLimitSettings) Limit(method string) RateLimitConfig {
+	if limit, ok := s.Methods[method]; ok {
+		return limit
+	}
+	return DefaultRateLimits[method]
+}
+
+// RateLimitSettingsFromConfig converts the daemon rate limit configuration.
+// Methods are named by RPC, such as "SpawnAgent", or by full gRPC method
+// name; names of methods swarmd does not serve are an error.
+func RateLimitSettingsFromConfig(c config.DaemonRateLimitConfig) (RateLimitSettings, error) {
+	settings := RateLimitSettings{Enabled: c.Enabled}
+	if c.Global != (config.RateLimit{}) {
+		settings.Global = &RateLimitConfig{RequestsPerSecond: c.Global.RequestsPerSecond, BurstSize: c.Global.Burst}
+	}
+	if len(c.Methods) > 0 {
+		settings.Methods = make(map[string]RateLimitConfig, len(c.Methods))
+	}
+	for name, limit := range c.Methods {
+		method, ok := servedMethod(name)
+		if !ok {
+			return RateLimitSettings{}, fmt.Errorf("daemon.rate_limits.methods: unknown RPC %q", name)
+		}
+		settings.Methods[method] = RateLimitConfig{RequestsPerSecond: limit.RequestsPerSecond, BurstSize: limit.Burst}
+	}
+	return settings, nil
+}
+
+// servedMethod resolves name, an RPC name such as "SpawnAgent" or a full
+// gRPC method name, to the full method name of the swarmd service. Names
+// match regardless of case, since config keys are read lowercased.
+func servedMethod(name string) (string, bool) {
+	desc := swarmdv1.SwarmdService_ServiceDesc
+	prefix := "/" + desc.ServiceName + "/"
+	if strings.HasPrefix(name, "/") {
+		if len(name) < len(prefix) || !strings.EqualFold(name[:len(prefix)], prefix) {
+			return "", false
+		}
+		name = name[len(prefix):]
+	}
+	for _, m := range desc.Methods {
+		if strings.EqualFold(m.MethodName, name) {
+			return prefix + m.MethodName, true
+		}
+	}
+	for _, s := range desc.Streams {
+		if strings.EqualFold(s.StreamName, name) {
+			return prefix + s.StreamName, true
+		}
+	}
+	return "", false
 }
 
 // tokenBucket implements the token bucket algorithm for rate limiting.
@@ -174,12 +257,15 @@ func NewRateLimiter(opts ...RateLimiterOption) *RateLimiter {
 
 // Allow checks if a request to the given method is allowed.
 func (rl *RateLimiter) Allow(method string) bool {
-	if !rl.enabled {
+	rl.mu.RLock()
+	enabled, globalBucket := rl.enabled, rl.globalBucket
+	rl.mu.RUnlock()
+	if !enabled {
 		return true
 	}
 
 	// Check global limit first
-	if rl.globalBucket != nil && !rl.globalBucket.allow() {
+	if globalBucket != nil && !globalBucket.allow() {
 		return false
 	}
 
@@ -264,6 +350,8 @@ func (rl *RateLimiter) Stats() []MethodStats {
 
 // GlobalStats returns statistics for the global rate limit.
 func (rl *RateLimiter) GlobalStats() *MethodStats {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
 	if rl.globalBucket == nil || rl.globalConfig == nil {
 		return nil
 	}
@@ -285,6 +373,37 @@ func (rl *RateLimiter) GlobalStats() *MethodStats {
 	}
 }
 
+// Reconfigure replaces the limits with s at runtime. The next request to a
+// method whose limit changed starts a full bucket; methods whose limit is
+// unchanged keep their tokens and counters.
+func (rl *RateLimiter) Reconfigure(s RateLimitSettings) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	configs := make(map[string]RateLimitConfig, len(DefaultRateLimits)+len(s.Methods))
+	for method, cfg := range DefaultRateLimits {
+		configs[method] = cfg
+	}
+	for method, cfg := range s.Methods {
+		configs[method] = cfg
+	}
+	for method := range rl.buckets {
+		if cfg, ok := configs[method]; !ok || cfg != rl.configs[method] {
+			delete(rl.buckets, method)
+		}
+	}
+	rl.configs = configs
+
+	switch {
+	case s.Global == nil:
+		rl.globalConfig, rl.globalBucket = nil, nil
+	case rl.globalConfig == nil || *rl.globalConfig != *s.Global:
+		global := *s.Global
+		rl.globalConfig, rl.globalBucket = &global, newTokenBucket(global)
+	}
+	rl.enabled = s.Enabled
+}
+
 // SetEnabled enables or disables rate limiting at runtime.
 func (rl *RateLimiter) SetEnabled(enabled bool) {
 	rl.mu.Lock()
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/opencode-ai/swarm/internal/config"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -162,6 +163,94 @@ func TestRateLimiterGlobalLimit(t *testing.T) {
 	}
 }
 
+func TestRateLimiterReconfigure(t *testing.T) {
+	spawn := "/swarmd.v1.SwarmdService/SpawnAgent"
+	kill := "/swarmd.v1.SwarmdService/KillAgent"
+	rl := NewRateLimiter(WithMethodLimits(map[string]RateLimitConfig{
+		spawn: {RequestsPerSecond: 0.001, BurstSize: 1},
+		kill:  {RequestsPerSecond: 0.001, BurstSize: 1},
+	}))
+	rl.Allow(spawn)
+	rl.Allow(kill)
+	if rl.Allow(spawn) || rl.Allow(kill) {
+		t.Fatal("expected both buckets spent")
+	}
+
+	rl.Reconfigure(RateLimitSettings{
+		Enabled: true,
+		Methods: map[string]RateLimitConfig{
+			spawn: {RequestsPerSecond: 0.001, BurstSize: 3},
+			kill:  {RequestsPerSecond: 0.001, BurstSize: 1},
+		},
+		Global: &RateLimitConfig{RequestsPerSecond: 0.001, BurstSize: 100},
+	})
+
+	// The changed limit applies from the next request; the unchanged one
+	// keeps its spent tokens.
+	for i := 0; i < 3; i++ {
+		if !rl.Allow(spawn) {
+			t.Fatalf("request %d should be allowed by the new burst", i+1)
+		}
+	}
+	if rl.Allow(spawn) {
+		t.Fatal("expected the new burst to be enforced")
+	}
+	if rl.Allow(kill) {
+		t.Fatal("expected the unchanged limit to keep its state")
+	}
+	if global := rl.GlobalStats(); global == nil || global.BurstSize != 100 {
+		t.Fatalf("expected the global limit set, got %+v", global)
+	}
+
+	// Dropping an override restores the default; disabling lifts limits.
+	rl.Reconfigure(RateLimitSettings{Enabled: false})
+	if rl.GlobalStats() != nil {
+		t.Fatal("expected the global limit removed")
+	}
+	for _, stats := range rl.Stats() {
+		if stats.Method == spawn && stats.BurstSize != DefaultRateLimits[spawn].BurstSize {
+			t.Fatalf("expected the default spawn limit back, got %+v", stats)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if !rl.Allow(spawn) {
+			t.Fatal("expected no limits while disabled")
+		}
+	}
+}
+
+func TestRateLimitSettingsFromConfig(t *testing.T) {
+	settings, err := RateLimitSettingsFromConfig(config.DaemonRateLimitConfig{
+		Enabled: true,
+		Global:  config.RateLimit{RequestsPerSecond: 20, Burst: 40},
+		Methods: map[string]config.RateLimit{
+			"spawnagent":                         {RequestsPerSecond: 1, Burst: 2},
+			"/swarmd.v1.SwarmdService/KillAgent": {RequestsPerSecond: 3, Burst: 4},
+		},
+	})
+	if err != nil {
+		t.Fatalf("RateLimitSettingsFromConfig failed: %v", err)
+	}
+	if !settings.Enabled || settings.Global == nil || *settings.Global != (RateLimitConfig{RequestsPerSecond: 20, BurstSize: 40}) {
+		t.Fatalf("unexpected settings %+v", settings)
+	}
+	if got := settings.Limit("/swarmd.v1.SwarmdService/SpawnAgent"); got != (RateLimitConfig{RequestsPerSecond: 1, BurstSize: 2}) {
+		t.Fatalf("unexpected SpawnAgent limit %+v", got)
+	}
+	if got := settings.Limit("/swarmd.v1.SwarmdService/KillAgent"); got != (RateLimitConfig{RequestsPerSecond: 3, BurstSize: 4}) {
+		t.Fatalf("unexpected KillAgent limit %+v", got)
+	}
+	if got := settings.Limit("/swarmd.v1.SwarmdService/Ping"); got != DefaultRateLimits["/swarmd.v1.SwarmdService/Ping"] {
+		t.Fatalf("expected the default Ping limit, got %+v", got)
+	}
+
+	if _, err := RateLimitSettingsFromConfig(config.DaemonRateLimitConfig{
+		Methods: map[string]config.RateLimit{"SpawnAgents": {RequestsPerSecond: 1, Burst: 1}},
+	}); err == nil {
+		t.Fatal("expected an unknown RPC to be rejected")
+	}
+}
+
 func TestRateLimiterUnknownMethod(t *testing.T) {
 	rl := NewRateLimiter()
 
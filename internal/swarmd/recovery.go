@@ -0,0 +1,270 @@
+package swarmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/rs/zerolog"
+)
+
+// RecoveryReasonPaneLost is the state reason recorded for agents whose pane
+// no longer exists when the daemon starts.
+const RecoveryReasonPaneLost = "pane lost on startup"
+
+// RecoveryReport summarizes a startup recovery pass.
+type RecoveryReport struct {
+	// Checked is the number of non-stopped local agents examined.
+	Checked int
+
+	// Skipped is the number of non-stopped agents left alone because they
+	// run on another node or under a node's swarmd.
+	Skipped int
+
+	// Recovered is the number of agents whose pane is still alive.
+	Recovered int
+
+	// Stopped lists the agents marked stopped because their pane is gone.
+	Stopped []string
+
+	// Errors lists agents that could not be checked or updated.
+	Errors []string
+}
+
+// StartupRecovery reconciles agents recorded in the database against the
+// live tmux server, so agents whose panes vanished (e.g. after a host
+// reboot) stop looking alive to the scheduler and dashboards.
+type StartupRecovery struct {
+	agentRepo     *db.AgentRepository
+	workspaceRepo *db.WorkspaceRepository
+	nodeRepo      *db.NodeRepository
+	eventRepo     *db.EventRepository
+	tmuxClient    *tmux.Client
+	logger        zerolog.Logger
+}
+
+// NewStartupRecovery creates a recovery pass over the given database.
+func NewStartupRecovery(database *db.DB, tmuxClient *tmux.Client, logger zerolog.Logger) *StartupRecovery {
+	if tmuxClient == nil {
+		tmuxClient = tmux.NewLocalClient()
+	}
+	return &StartupRecovery{
+		agentRepo:     db.NewAgentRepository(database),
+		workspaceRepo: db.NewWorkspaceRepository(database),
+		nodeRepo:      db.NewNodeRepository(database),
+		eventRepo:     db.NewEventRepository(database),
+		tmuxClient:    tmuxClient,
+		logger:        logger,
+	}
+}
+
+// Run checks every agent on the local node that is not already stopped.
+// Agents whose pane is missing are marked stopped, their pause is cleared,
+// and an agent.state_changed event is recorded. Stopped agents are skipped,
+// so running the pass again is harmless. Agents in workspaces on other
+// nodes, and agents run by a node's swarmd, are not in the local tmux
+// server and are left alone.
+func (r *StartupRecovery) Run(ctx context.Context) (*RecoveryReport, error) {
+	agents, err := r.agentRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	report := &RecoveryReport{}
+	bySession := make(map[string][]*models.Agent)
+	sessions := make([]string, 0)
+	workspaces := make(map[string]*models.Workspace)
+	localNodes := make(map[string]bool)
+
+	for _, agent := range agents {
+		if agent.State == models.AgentStateStopped {
+			continue
+		}
+		if agent.RemoteAgentID != "" {
+			report.Skipped++
+			continue
+		}
+
+		ws, ok := workspaces[agent.WorkspaceID]
+		if !ok {
+			ws, err = r.workspaceRepo.Get(ctx, agent.WorkspaceID)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: failed to get workspace: %v", agent.ID, err))
+				continue
+			}
+			workspaces[agent.WorkspaceID] = ws
+		}
+		local, ok := localNodes[ws.NodeID]
+		if !ok {
+			node, err := r.nodeRepo.Get(ctx, ws.NodeID)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: failed to get node: %v", agent.ID, err))
+				continue
+			}
+			local = node.IsLocal
+			localNodes[ws.NodeID] = local
+		}
+		if !local {
+			report.Skipped++
+			continue
+		}
+		report.Checked++
+
+		// Runner processes belong to the daemon, so none survive a restart.
+		if IsRunnerPane(agent.TmuxPane) {
+			if err := r.markStopped(ctx, agent); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", agent.ID, err))
+				continue
+			}
+			report.Stopped = append(report.Stopped, agent.ID)
+			continue
+		}
+
+		session, ok := agent.TmuxSession, agent.TmuxSession != ""
+		if !ok {
+			session, ok = paneSession(agent.TmuxPane)
+		}
+		if !ok {
+			// Agents recorded without a session fall back to the workspace's.
+			session = ws.TmuxSession
+		}
+
+		if _, seen := bySession[session]; !seen {
+			sessions = append(sessions, session)
+		}
+		bySession[session] = append(bySession[session], agent)
+	}
+	sort.Strings(sessions)
+
+	for _, session := range sessions {
+		live, err := r.livePanes(ctx, session)
+		if err != nil {
+			for _, agent := range bySession[session] {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", agent.ID, err))
+			}
+			continue
+		}
+
+		for _, agent := range bySession[session] {
+			if live.has(agent.TmuxPane) {
+				report.Recovered++
+				continue
+			}
+			if err := r.markStopped(ctx, agent); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", agent.ID, err))
+				continue
+			}
+			report.Stopped = append(report.Stopped, agent.ID)
+		}
+	}
+
+	return report, nil
+}
+
+func (r *StartupRecovery) markStopped(ctx context.Context, agent *models.Agent) error {
+	oldState := agent.State
+	now := time.Now().UTC()
+
+	agent.State = models.AgentStateStopped
+	agent.StateInfo = models.StateInfo{
+		State:      models.AgentStateStopped,
+		Confidence: models.StateConfidenceHigh,
+		Reason:     RecoveryReasonPaneLost,
+		DetectedAt: now,
+	}
+	agent.PausedUntil = nil
+
+	payload, err := json.Marshal(models.StateChangedPayload{
+		OldState:   oldState,
+		NewState:   models.AgentStateStopped,
+		Confidence: models.StateConfidenceHigh,
+		Reason:     RecoveryReasonPaneLost,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal state change payload: %w", err)
+	}
+
+	event := &models.Event{
+		Timestamp:  now,
+		Type:       models.EventTypeAgentStateChanged,
+		EntityType: models.EntityTypeAgent,
+		EntityID:   agent.ID,
+		Payload:    payload,
+	}
+
+	if err := r.agentRepo.UpdateWithEvent(ctx, agent, event, r.eventRepo); err != nil {
+		return fmt.Errorf("failed to mark agent stopped: %w", err)
+	}
+
+	r.logger.Info().
+		Ctx(ctx).
+		Str("agent_id", agent.ID).
+		Str("tmux_pane", agent.TmuxPane).
+		Str("old_state", string(oldState)).
+		Msg("agent pane lost, marked stopped")
+	return nil
+}
+
+// livePanes lists the panes of a session with one has-session and one
+// list-panes call. A missing session yields an empty set.
+func (r *StartupRecovery) livePanes(ctx context.Context, session string) (paneSet, error) {
+	set := paneSet{session: session, targets: make(map[string]bool)}
+	if session == "" {
+		return set, nil
+	}
+
+	exists, err := r.tmuxClient.HasSession(ctx, session)
+	if err != nil {
+		return set, fmt.Errorf("failed to check session %s: %w", session, err)
+	}
+	if !exists {
+		return set, nil
+	}
+
+	panes, err := r.tmuxClient.ListPanes(ctx, session)
+	if err != nil {
+		if errors.Is(err, tmux.ErrSessionNotFound) {
+			return set, nil
+		}
+		return set, fmt.Errorf("failed to list panes for %s: %w", session, err)
+	}
+	for _, pane := range panes {
+		window := strconv.Itoa(pane.WindowIndex)
+		set.targets[pane.ID] = true
+		set.targets[window] = true
+		set.targets[window+"."+strconv.Itoa(pane.Index)] = true
+	}
+	return set, nil
+}
+
+// paneSet holds the live pane targets of one session.
+type paneSet struct {
+	session string
+	targets map[string]bool
+}
+
+// has reports whether target ("session:window.pane", "session:window", or
+// a bare "%id") refers to a live pane in the set.
+func (p paneSet) has(target string) bool {
+	if rest, ok := strings.CutPrefix(target, p.session+":"); ok {
+		return p.targets[rest]
+	}
+	return p.targets[target]
+}
+
+// paneSession extracts the session name from a "session:window.pane" target.
+func paneSession(target string) (string, bool) {
+	session, _, ok := strings.Cut(target, ":")
+	if !ok || session == "" {
+		return "", false
+	}
+	return session, true
+}
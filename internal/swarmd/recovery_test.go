@@ -0,0 +1,243 @@
+package swarmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+)
+
+func TestStartupRecovery(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	workspaceRepo := db.NewWorkspaceRepository(database)
+	workspaces := map[string]*models.Workspace{}
+	for _, session := range []string{"alive", "gone"} {
+		ws := &models.Workspace{NodeID: node.ID, Name: session, RepoPath: "/repos/" + session, TmuxSession: session}
+		if err := workspaceRepo.Create(ctx, ws); err != nil {
+			t.Fatalf("failed to create workspace: %v", err)
+		}
+		workspaces[session] = ws
+	}
+
+	agentRepo := db.NewAgentRepository(database)
+	pausedUntil := time.Now().UTC().Add(time.Hour)
+	seed := []struct {
+		name    string
+		ws      string
+		pane    string
+		state   models.AgentState
+		paused  bool
+		stopped bool
+	}{
+		{name: "live", ws: "alive", pane: "alive:1.0", state: models.AgentStateWorking},
+		{name: "live-by-id", ws: "alive", pane: "%2", state: models.AgentStateIdle},
+		{name: "lost-pane", ws: "alive", pane: "alive:1.3", state: models.AgentStatePaused, paused: true, stopped: true},
+		{name: "lost-session", ws: "gone", pane: "gone:1.0", state: models.AgentStateWorking, stopped: true},
+		{name: "already-stopped", ws: "gone", pane: "gone:1.1", state: models.AgentStateStopped},
+	}
+	ids := map[string]string{}
+	for _, s := range seed {
+		agent := &models.Agent{
+			WorkspaceID: workspaces[s.ws].ID,
+			Type:        models.AgentTypeOpenCode,
+			TmuxPane:    s.pane,
+			State:       s.state,
+			StateInfo:   models.StateInfo{State: s.state, Confidence: models.StateConfidenceHigh, DetectedAt: time.Now().UTC()},
+		}
+		if s.paused {
+			agent.PausedUntil = &pausedUntil
+		}
+		if err := agentRepo.Create(ctx, agent); err != nil {
+			t.Fatalf("failed to create agent %s: %v", s.name, err)
+		}
+		ids[s.name] = agent.ID
+	}
+
+	// "alive" has a shell in window 0 and two agent panes (%1, %2) in
+	// window 1; "gone" has no session at all.
+	srv := tmuxtest.NewServer()
+	for _, args := range [][]string{
+		{"new-session", "-d", "-s", "alive", "-c", "/repos/alive"},
+		{"new-window", "-t", "alive", "-c", "/repos/alive"},
+		{"split-window", "-t", "alive:1", "-c", "/repos/alive"},
+	} {
+		if _, err := srv.Run(args...); err != nil {
+			t.Fatalf("failed to seed tmux: %v", err)
+		}
+	}
+	recovery := NewStartupRecovery(database, tmux.NewClient(srv), zerolog.Nop())
+
+	report, err := recovery.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Checked != 4 || report.Recovered != 2 || len(report.Stopped) != 2 || len(report.Errors) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// Two sessions means two has-session and at most two list-panes calls.
+	if commands := srv.Commands(); len(commands) > 4 {
+		t.Fatalf("expected tmux calls grouped by session, got %v", commands)
+	}
+
+	for _, s := range seed {
+		agent, err := agentRepo.Get(ctx, ids[s.name])
+		if err != nil {
+			t.Fatalf("failed to get agent %s: %v", s.name, err)
+		}
+		switch {
+		case s.stopped:
+			if agent.State != models.AgentStateStopped || agent.StateInfo.Reason != RecoveryReasonPaneLost {
+				t.Fatalf("%s: expected stopped with reason %q, got %s/%q", s.name, RecoveryReasonPaneLost, agent.State, agent.StateInfo.Reason)
+			}
+			if agent.PausedUntil != nil {
+				t.Fatalf("%s: expected pause to be cleared", s.name)
+			}
+		default:
+			if agent.State != s.state {
+				t.Fatalf("%s: expected state %s unchanged, got %s", s.name, s.state, agent.State)
+			}
+		}
+	}
+
+	eventRepo := db.NewEventRepository(database)
+	eventType := models.EventTypeAgentStateChanged
+	page, err := eventRepo.Query(ctx, db.EventQuery{Type: &eventType, Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to query events: %v", err)
+	}
+	if len(page.Events) != 2 {
+		t.Fatalf("expected 2 state change events, got %d", len(page.Events))
+	}
+	for _, event := range page.Events {
+		var payload models.StateChangedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		if event.EntityID != ids["lost-pane"] && event.EntityID != ids["lost-session"] {
+			t.Fatalf("unexpected event for agent %s", event.EntityID)
+		}
+		if payload.NewState != models.AgentStateStopped || payload.Reason != RecoveryReasonPaneLost {
+			t.Fatalf("unexpected payload: %+v", payload)
+		}
+	}
+
+	// A second pass finds nothing new to stop.
+	again, err := recovery.Run(ctx)
+	if err != nil {
+		t.Fatalf("second Run failed: %v", err)
+	}
+	if again.Checked != 2 || again.Recovered != 2 || len(again.Stopped) != 0 {
+		t.Fatalf("expected idempotent second pass, got %+v", again)
+	}
+	if count, err := eventRepo.Count(ctx); err != nil || count != 2 {
+		t.Fatalf("expected no new events, got %d (err=%v)", count, err)
+	}
+}
+
+func TestStartupRecoverySkipsRemoteAgents(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	local := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	remote := &models.Node{Name: "remote", SSHTarget: "user@remote", Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	for _, node := range []*models.Node{local, remote} {
+		if err := nodeRepo.Create(ctx, node); err != nil {
+			t.Fatalf("failed to create node: %v", err)
+		}
+	}
+	workspaceRepo := db.NewWorkspaceRepository(database)
+	localWS := &models.Workspace{NodeID: local.ID, Name: "local", RepoPath: "/repos/local", TmuxSession: "local"}
+	remoteWS := &models.Workspace{NodeID: remote.ID, Name: "remote", RepoPath: "/repos/remote", TmuxSession: "remote"}
+	for _, ws := range []*models.Workspace{localWS, remoteWS} {
+		if err := workspaceRepo.Create(ctx, ws); err != nil {
+			t.Fatalf("failed to create workspace: %v", err)
+		}
+	}
+
+	// None of these panes exist in the local tmux server; recovery must not
+	// take that as a sign they are gone.
+	agentRepo := db.NewAgentRepository(database)
+	seed := []struct {
+		name     string
+		ws       *models.Workspace
+		pane     string
+		remoteID string
+	}{
+		{name: "remote-pane", ws: remoteWS, pane: "remote:1.0"},
+		{name: "remote-runner", ws: remoteWS, pane: RunnerPanePrefix + "agent"},
+		{name: "remote-swarmd", ws: remoteWS, pane: "remote:1.1", remoteID: "agent-on-node"},
+		{name: "local-swarmd", ws: localWS, pane: "local:1.0", remoteID: "agent-on-local"},
+	}
+	ids := map[string]string{}
+	for _, s := range seed {
+		agent := &models.Agent{
+			WorkspaceID:   s.ws.ID,
+			Type:          models.AgentTypeOpenCode,
+			TmuxPane:      s.pane,
+			RemoteAgentID: s.remoteID,
+			State:         models.AgentStateWorking,
+			StateInfo:     models.StateInfo{State: models.AgentStateWorking, Confidence: models.StateConfidenceHigh, DetectedAt: time.Now().UTC()},
+		}
+		if err := agentRepo.Create(ctx, agent); err != nil {
+			t.Fatalf("failed to create agent %s: %v", s.name, err)
+		}
+		ids[s.name] = agent.ID
+	}
+
+	srv := tmuxtest.NewServer()
+	recovery := NewStartupRecovery(database, tmux.NewClient(srv), zerolog.Nop())
+
+	report, err := recovery.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Checked != 0 || report.Skipped != 4 || len(report.Stopped) != 0 || len(report.Errors) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if commands := srv.Commands(); len(commands) != 0 {
+		t.Fatalf("expected no tmux calls, got %v", commands)
+	}
+
+	for _, s := range seed {
+		agent, err := agentRepo.Get(ctx, ids[s.name])
+		if err != nil {
+			t.Fatalf("failed to get agent %s: %v", s.name, err)
+		}
+		if agent.State != models.AgentStateWorking {
+			t.Fatalf("%s: expected state unchanged, got %s", s.name, agent.State)
+		}
+	}
+	if count, err := db.NewEventRepository(database).Count(ctx); err != nil || count != 0 {
+		t.Fatalf("expected no events, got %d (err=%v)", count, err)
+	}
+}
@@ -0,0 +1,310 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/redact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrReloadUnsupported is returned by Reload when the daemon was started
+// without a way to re-read its config.
+var ErrReloadUnsupported = errors.New("config reload is not available")
+
+// ReloadResult describes an applied config reload.
+type ReloadResult struct {
+	// ConfigFile is the file the config was read from, if any.
+	ConfigFile string
+
+	// RestartRequired lists the settings that changed but only take effect
+	// when the daemon restarts, such as "database.path".
+	RestartRequired []string
+}
+
+// reconfigureStep applies one component's part of a config.
+type reconfigureStep struct {
+	name  string
+	apply func(cfg *config.Config) error
+}
+
+// Reload re-reads the config through Options.LoadConfig and applies it
+// with Reconfigure. A config that fails to load or apply is rejected and
+// the running config stays active.
+func (d *Daemon) Reload() (*ReloadResult, error) {
+	if d.opts.LoadConfig == nil {
+		return nil, ErrReloadUnsupported
+	}
+	cfg, file, err := d.opts.LoadConfig()
+	if err == nil {
+		var result *ReloadResult
+		if result, err = d.Reconfigure(cfg); err == nil {
+			result.ConfigFile = file
+			return result, nil
+		}
+	}
+	d.logger.Warn().Err(err).Str("config_file", file).Msg("config reload rejected, keeping the running config")
+	return nil, err
+}
+
+// Reconfigure applies cfg to the running daemon: rate limits, redaction
+// patterns, adapter overrides, recurring schedule settings, and standby
+// pools. Each component takes the new values on its next cycle. When a
+// component refuses cfg, those already changed are given the running
+// config back, so a reload applies completely or not at all. Settings only
+// read at startup are kept; the result lists those that changed.
+func (d *Daemon) Reconfigure(cfg *config.Config) (*ReloadResult, error) {
+	if cfg == nil {
+		return nil, errors.New("config is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+
+	previous := d.cfg
+	restart := restartRequired(previous, cfg)
+	next := *cfg
+	keepStartupSettings(&next, previous)
+
+	steps := d.reconfigureSteps()
+	for i, step := range steps {
+		if err := step.apply(&next); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if rerr := steps[j].apply(previous); rerr != nil {
+					d.logger.Error().Err(rerr).Str("component", steps[j].name).Msg("failed to restore running config")
+				}
+			}
+			return nil, fmt.Errorf("%s: %w", step.name, err)
+		}
+	}
+	d.cfg = &next
+
+	if len(restart) > 0 {
+		d.logger.Warn().Strs("settings", restart).Msg("config changes need a restart and were not applied")
+	}
+	d.logger.Info().Msg("config reloaded")
+	return &ReloadResult{RestartRequired: restart}, nil
+}
+
+// Config returns the config the daemon is running with.
+func (d *Daemon) Config() *config.Config {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+	return d.cfg
+}
+
+func (d *Daemon) reconfigureSteps() []reconfigureStep {
+	steps := []reconfigureStep{
+		{"redaction", d.server.Reconfigure},
+		{"adapters", func(cfg *config.Config) error {
+			return adapters.ApplyOverrides(cfg.AdapterOverrides())
+		}},
+		{"rate limits", func(cfg *config.Config) error {
+			settings, err := rateLimitSettings(cfg, d.opts)
+			if err != nil {
+				return err
+			}
+			d.rateLimiter.Reconfigure(settings)
+			return nil
+		}},
+	}
+	if d.scheduleRunner != nil {
+		steps = append(steps, reconfigureStep{"schedules", d.scheduleRunner.Reconfigure})
+	}
+	if d.agentPruner != nil {
+		steps = append(steps, reconfigureStep{"agent retention", d.agentPruner.Reconfigure})
+	}
+	if d.auditPruner != nil {
+		steps = append(steps, reconfigureStep{"audit retention", d.auditPruner.Reconfigure})
+	}
+	if d.usageCache != nil {
+		steps = append(steps, reconfigureStep{"usage retention", d.usageCache.Reconfigure})
+	}
+	if d.compactor != nil {
+		steps = append(steps, reconfigureStep{"transcript compaction", d.compactor.Reconfigure})
+	}
+	if d.transcriptPruner != nil {
+		steps = append(steps, reconfigureStep{"transcript retention", d.transcriptPruner.Reconfigure})
+	}
+	if d.sessionGC != nil {
+		steps = append(steps, reconfigureStep{"session gc", d.sessionGC.Reconfigure})
+	}
+	if d.costFeed != nil {
+		steps = append(steps, reconfigureStep{"cost ticker", d.costFeed.Reconfigure})
+	}
+	if d.providerMonitor != nil {
+		steps = append(steps, reconfigureStep{"provider health", d.providerMonitor.Reconfigure})
+	}
+	if d.standbyRunner != nil {
+		steps = append(steps, reconfigureStep{"standby", func(cfg *config.Config) error {
+			settings, err := rateLimitSettings(cfg, d.opts)
+			if err != nil {
+				return err
+			}
+			return d.standbyRunner.Reconfigure(cfg, settings.Limit(swarmdv1.SwarmdService_SpawnAgent_FullMethodName))
+		}})
+	}
+	return steps
+}
+
+// rateLimitSettings returns the rate limits of cfg with the limits set in
+// opts taking precedence.
+func rateLimitSettings(cfg *config.Config, opts Options) (RateLimitSettings, error) {
+	settings, err := RateLimitSettingsFromConfig(cfg.Daemon.RateLimits)
+	if err != nil {
+		return RateLimitSettings{}, err
+	}
+	if len(opts.CustomRateLimits) > 0 && settings.Methods == nil {
+		settings.Methods = make(map[string]RateLimitConfig, len(opts.CustomRateLimits))
+	}
+	for method, limit := range opts.CustomRateLimits {
+		settings.Methods[method] = limit
+	}
+	if opts.GlobalRateLimit != nil {
+		global := *opts.GlobalRateLimit
+		settings.Global = &global
+	}
+	if opts.RateLimitEnabled != nil {
+		settings.Enabled = *opts.RateLimitEnabled
+	}
+	return settings, nil
+}
+
+// restartRequired lists the settings read only at startup that differ
+// between the running config and cfg.
+func restartRequired(running, cfg *config.Config) []string {
+	var changed []string
+	check := func(name string, differs bool) {
+		if differs {
+			changed = append(changed, name)
+		}
+	}
+	check("global.data_dir", running.Global.DataDir != cfg.Global.DataDir)
+	check("global.config_dir", running.Global.ConfigDir != cfg.Global.ConfigDir)
+	check("database.path", running.DatabasePath() != cfg.DatabasePath())
+	check("database.max_connections", running.Database.MaxConnections != cfg.Database.MaxConnections)
+	check("database.busy_timeout_ms", running.Database.BusyTimeoutMs != cfg.Database.BusyTimeoutMs)
+	check("logging", running.Logging != cfg.Logging)
+	check("daemon.config_watch_interval", running.Daemon.ConfigWatchInterval != cfg.Daemon.ConfigWatchInterval)
+	check("daemon.execution_backend", running.Daemon.ExecutionBackend != cfg.Daemon.ExecutionBackend)
+	check("daemon.transcript_store.enabled", running.Daemon.TranscriptStore.Enabled != cfg.Daemon.TranscriptStore.Enabled)
+	check("event_bridge", running.EventBridge != cfg.EventBridge)
+	check("budget.cost_timezone", running.Budget.CostTimezone != cfg.Budget.CostTimezone)
+	return changed
+}
+
+// keepStartupSettings copies the settings read only at startup from
+// running into cfg, so the daemon's config keeps describing what it runs
+// with and later reloads still report them.
+func keepStartupSettings(cfg, running *config.Config) {
+	cfg.Global.DataDir = running.Global.DataDir
+	cfg.Global.ConfigDir = running.Global.ConfigDir
+	cfg.Database = running.Database
+	cfg.Logging = running.Logging
+	cfg.Daemon.ConfigWatchInterval = running.Daemon.ConfigWatchInterval
+	cfg.Daemon.ExecutionBackend = running.Daemon.ExecutionBackend
+	cfg.Daemon.TranscriptStore.Enabled = running.Daemon.TranscriptStore.Enabled
+	cfg.EventBridge = running.EventBridge
+	cfg.Budget.CostTimezone = running.Budget.CostTimezone
+}
+
+// watchConfig reloads the config whenever the watched file's modification
+// time or size changes, polling at the configured interval.
+func (d *Daemon) watchConfig(ctx context.Context, path string, interval time.Duration) {
+	last, _ := statConfigFile(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stamp, err := statConfigFile(path)
+		if err != nil || stamp.same(last) {
+			continue
+		}
+		last = stamp
+		d.logger.Info().Ctx(ctx).Str("config_file", path).Msg("config file changed, reloading")
+		// Reload logs rejected configs itself.
+		_, _ = d.Reload()
+	}
+}
+
+type configFileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func (s configFileStamp) same(other configFileStamp) bool {
+	return s.modTime.Equal(other.modTime) && s.size == other.size
+}
+
+func statConfigFile(path string) (configFileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return configFileStamp{}, err
+	}
+	return configFileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// Reconfigure replaces the redaction patterns, the transcript backfill cap,
+// the transcript limits, the sensitive input window, and the pane polling
+// ceiling with those of cfg. Invalid patterns are an error and leave the
+// running settings in place. Lowered transcript limits apply as agents
+// record their next entries.
+func (s *Server) Reconfigure(cfg *config.Config) error {
+	redactor, err := redact.FromConfig(cfg.Redaction)
+	if err != nil {
+		return err
+	}
+	s.redactorMu.Lock()
+	s.redactor = redactor
+	s.redactorMu.Unlock()
+	s.backfillLimit.Store(int64(cfg.Daemon.TranscriptBackfillMaxBytes))
+	s.transcriptMaxEntries.Store(int64(cfg.Daemon.TranscriptMaxEntries))
+	s.transcriptMaxBytes.Store(cfg.Daemon.TranscriptMaxBytes)
+	s.sensitiveWindow.Store(int64(cfg.Daemon.SensitiveInputWindow))
+	s.maxPollInterval.Store(int64(cfg.Daemon.PanePollMaxInterval))
+	return nil
+}
+
+func (s *Server) currentRedactor() *redact.Redactor {
+	s.redactorMu.RLock()
+	defer s.redactorMu.RUnlock()
+	return s.redactor
+}
+
+// SetReloader sets the function ReloadConfig calls to reload the daemon.
+func (s *Server) SetReloader(reload func() (*ReloadResult, error)) {
+	s.reload = reload
+}
+
+// ReloadConfig re-reads the daemon's config and applies it.
+func (s *Server) ReloadConfig(ctx context.Context, req *swarmdv1.ReloadConfigRequest) (*swarmdv1.ReloadConfigResponse, error) {
+	if s.reload == nil {
+		return nil, status.Error(codes.FailedPrecondition, ErrReloadUnsupported.Error())
+	}
+	result, err := s.reload()
+	if err != nil {
+		if errors.Is(err, ErrReloadUnsupported) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "config rejected, running config kept: %v", err)
+	}
+	return &swarmdv1.ReloadConfigResponse{
+		ConfigFile:      result.ConfigFile,
+		RestartRequired: result.RestartRequired,
+	}, nil
+}
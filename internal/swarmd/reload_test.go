@@ -0,0 +1,213 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func rateLimitFor(rl *RateLimiter, method string) RateLimitConfig {
+	for _, stats := range rl.Stats() {
+		if stats.Method == method {
+			return RateLimitConfig{RequestsPerSecond: stats.RequestsPerSec, BurstSize: stats.BurstSize}
+		}
+	}
+	return RateLimitConfig{}
+}
+
+func TestDaemonReconfigure(t *testing.T) {
+	t.Cleanup(func() { _ = adapters.ApplyOverrides(nil) })
+
+	cfg := config.DefaultConfig()
+	daemon, err := New(cfg, zerolog.Nop(), Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	next := config.DefaultConfig()
+	next.Redaction.Patterns = []config.RedactionPattern{{Label: "ticket", Pattern: `TICKET-\d+`}}
+	next.AgentDefaults.Adapters = map[string]config.AdapterConfig{"codex": {Command: "my-codex"}}
+	next.Daemon.RateLimits.Methods = map[string]config.RateLimit{"SpawnAgent": {RequestsPerSecond: 1, Burst: 2}}
+	next.Database.Path = "/elsewhere/swarm.db"
+	next.Logging.Level = "debug"
+	next.EventBridge.Enabled = true
+
+	result, err := daemon.Reconfigure(next)
+	if err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.RestartRequired, []string{"database.path", "logging", "event_bridge"}) {
+		t.Fatalf("unexpected restart-required settings %v", result.RestartRequired)
+	}
+	if got := daemon.server.currentRedactor().Redact("see TICKET-42"); !strings.Contains(got, "[REDACTED:ticket]") {
+		t.Fatalf("expected the new redaction pattern applied, got %q", got)
+	}
+	if got := adapters.CapabilitiesFor(models.AgentTypeCodex).Binary; got != "my-codex" {
+		t.Fatalf("expected the adapter override applied, got %q", got)
+	}
+	if got := rateLimitFor(daemon.rateLimiter, swarmdv1.SwarmdService_SpawnAgent_FullMethodName); got != (RateLimitConfig{RequestsPerSecond: 1, BurstSize: 2}) {
+		t.Fatalf("expected the new SpawnAgent limit, got %+v", got)
+	}
+	// Startup-only settings keep their running values.
+	if running := daemon.Config(); running.Database.Path != cfg.Database.Path || running.Logging.Level != "info" || running.EventBridge.Enabled {
+		t.Fatalf("expected startup settings kept, got %+v %+v", running.Database, running.Logging)
+	}
+	if len(daemon.Config().Redaction.Patterns) != 1 {
+		t.Fatal("expected the daemon config to be the reloaded one")
+	}
+}
+
+func TestDaemonReconfigureRejectsAtomically(t *testing.T) {
+	t.Cleanup(func() { _ = adapters.ApplyOverrides(nil) })
+
+	daemon, err := New(config.DefaultConfig(), zerolog.Nop(), Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	running := daemon.Config()
+
+	// Redaction and adapters apply before the unknown RPC is found, and
+	// must be rolled back.
+	bad := config.DefaultConfig()
+	bad.Redaction.Patterns = []config.RedactionPattern{{Label: "ticket", Pattern: `TICKET-\d+`}}
+	bad.AgentDefaults.Adapters = map[string]config.AdapterConfig{"codex": {Command: "my-codex"}}
+	bad.Daemon.RateLimits.Methods = map[string]config.RateLimit{"SpawnAgents": {RequestsPerSecond: 1, Burst: 2}}
+	if _, err := daemon.Reconfigure(bad); err == nil || !strings.Contains(err.Error(), "SpawnAgents") {
+		t.Fatalf("expected the unknown RPC to be rejected, got %v", err)
+	}
+
+	if got := daemon.server.currentRedactor().Redact("see TICKET-42"); got != "see TICKET-42" {
+		t.Fatalf("expected the old redaction patterns kept, got %q", got)
+	}
+	if got := adapters.CapabilitiesFor(models.AgentTypeCodex).Binary; got != "codex" {
+		t.Fatalf("expected the adapter override rolled back, got %q", got)
+	}
+	if got := rateLimitFor(daemon.rateLimiter, swarmdv1.SwarmdService_SpawnAgent_FullMethodName); got != DefaultRateLimits[swarmdv1.SwarmdService_SpawnAgent_FullMethodName] {
+		t.Fatalf("expected the old rate limits kept, got %+v", got)
+	}
+	if daemon.Config() != running {
+		t.Fatal("expected the running config to stay active")
+	}
+
+	// A config that fails validation never reaches the components.
+	invalid := config.DefaultConfig()
+	invalid.Redaction.Patterns = []config.RedactionPattern{{Label: "broken", Pattern: "("}}
+	if _, err := daemon.Reconfigure(invalid); err == nil {
+		t.Fatal("expected an invalid config to be rejected")
+	}
+	if daemon.Config() != running {
+		t.Fatal("expected the running config to stay active")
+	}
+}
+
+func TestDaemonWatchConfigReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+	}
+	writeConfig("logging:\n  level: info\n")
+
+	var loads atomic.Int32
+	load := func() (*config.Config, string, error) {
+		defer loads.Add(1)
+		cfg, err := config.LoadFromFile(path)
+		return cfg, path, err
+	}
+	cfg, _, err := load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	daemon, err := New(cfg, zerolog.Nop(), Options{LoadConfig: load, ConfigFile: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		daemon.watchConfig(ctx, path, 5*time.Millisecond)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitForLoads := func(n int32) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for loads.Load() < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %d config loads, got %d", n, loads.Load())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// Let the watcher record the file as it is before changing it.
+	time.Sleep(50 * time.Millisecond)
+	writeConfig("redaction:\n  patterns:\n    - label: ticket\n      pattern: 'TICKET-\\d+'\n")
+	waitForLoads(2)
+	if got := daemon.server.currentRedactor().Redact("TICKET-7"); got != "[REDACTED:ticket]" {
+		t.Fatalf("expected the changed file applied, got %q", got)
+	}
+
+	// A broken edit is rejected and the running patterns stay.
+	writeConfig("redaction:\n  patterns:\n    - label: broken\n      pattern: '('\n")
+	waitForLoads(3)
+	if got := daemon.server.currentRedactor().Redact("TICKET-7"); got != "[REDACTED:ticket]" {
+		t.Fatalf("expected the running patterns kept, got %q", got)
+	}
+}
+
+func TestServerReloadConfig(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(zerolog.Nop())
+
+	if _, err := server.ReloadConfig(ctx, &swarmdv1.ReloadConfigRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition without a reloader, got %v", err)
+	}
+
+	server.SetReloader(func() (*ReloadResult, error) {
+		return nil, errors.New("redaction.patterns[0].pattern is invalid")
+	})
+	if _, err := server.ReloadConfig(ctx, &swarmdv1.ReloadConfigRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for a rejected config, got %v", err)
+	}
+
+	server.SetReloader(func() (*ReloadResult, error) {
+		return &ReloadResult{ConfigFile: "/etc/swarm/config.yaml", RestartRequired: []string{"database.path"}}, nil
+	})
+	resp, err := server.ReloadConfig(ctx, &swarmdv1.ReloadConfigRequest{})
+	if err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if resp.GetConfigFile() != "/etc/swarm/config.yaml" || !reflect.DeepEqual(resp.GetRestartRequired(), []string{"database.path"}) {
+		t.Fatalf("unexpected response %v", resp)
+	}
+
+	daemon, err := New(config.DefaultConfig(), zerolog.Nop(), Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := daemon.Reload(); !errors.Is(err, ErrReloadUnsupported) {
+		t.Fatalf("expected ErrReloadUnsupported without LoadConfig, got %v", err)
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/opencode-ai/swarm/gen/swarmd/v1"
@@ -258,6 +257,7 @@ func (rm *ResourceMonitor) Start(ctx context.Context) {
 	}()
 
 	rm.logger.Info().
+		Ctx(ctx).
 		Dur("interval", rm.interval).
 		Int64("memory_limit_mb", rm.limits.MaxMemoryBytes/(1024*1024)).
 		Float64("cpu_limit_pct", rm.limits.MaxCPUPercent).
@@ -496,7 +496,7 @@ func (rm *ResourceMonitor) pauseAgentsForDisk() {
 		if _, alreadyPaused := rm.diskState.pausedAgents[state.agentID]; alreadyPaused {
 			continue
 		}
-		if err := signalProcess(state.pid, syscall.SIGSTOP); err != nil {
+		if err := stopProcess(state.pid); err != nil {
 			rm.logger.Warn().Err(err).Str("agent_id", state.agentID).Int("pid", state.pid).Msg("failed to pause agent due to disk pressure")
 			continue
 		}
@@ -514,7 +514,7 @@ func (rm *ResourceMonitor) resumeAgentsForDisk() {
 		if state, ok := rm.agents[agentID]; ok && state.pid > 0 {
 			pid = state.pid
 		}
-		if err := signalProcess(pid, syscall.SIGCONT); err != nil {
+		if err := continueProcess(pid); err != nil {
 			rm.logger.Warn().Err(err).Str("agent_id", agentID).Int("pid", pid).Msg("failed to resume agent after disk pressure")
 			continue
 		}
@@ -530,17 +530,6 @@ func (rm *ResourceMonitor) publishDiskAlert(code, message string, recoverable bo
 	rm.server.publishError("", "", code, message, recoverable)
 }
 
-func signalProcess(pid int, sig syscall.Signal) error {
-	if pid <= 0 {
-		return fmt.Errorf("invalid pid %d", pid)
-	}
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return err
-	}
-	return process.Signal(sig)
-}
-
 // measureUsage measures the resource usage of a process.
 func (rm *ResourceMonitor) measureUsage(pid int) (ResourceUsage, error) {
 	usage := ResourceUsage{
@@ -669,13 +658,10 @@ func (rm *ResourceMonitor) getCPUUsage(pid int) (float64, error) {
 }
 
 func getDiskUsage(path string) (DiskUsage, error) {
-	var stat syscall.Statfs_t
-	if err := syscall.Statfs(path, &stat); err != nil {
+	total, free, err := diskSpace(path)
+	if err != nil {
 		return DiskUsage{}, err
 	}
-
-	total := stat.Blocks * uint64(stat.Bsize)
-	free := stat.Bavail * uint64(stat.Bsize)
 	used := total - free
 
 	var usedPercent float64
@@ -178,13 +178,13 @@ func TestResourceMonitor_ViolationCallback(t *testing.T) {
 	logger := zerolog.Nop()
 
 	var callbackCalled atomic.Bool
-	var lastViolation ResourceViolation
+	var lastViolation atomic.Value
 
 	rm := NewResourceMonitor(logger, nil,
 		WithMonitorInterval(50*time.Millisecond),
 		WithViolationCallback(func(v ResourceViolation) {
 			callbackCalled.Store(true)
-			lastViolation = v
+			lastViolation.Store(v)
 		}),
 	)
 
@@ -204,14 +204,15 @@ func TestResourceMonitor_ViolationCallback(t *testing.T) {
 	time.Sleep(200 * time.Millisecond)
 
 	if !callbackCalled.Load() {
-		t.Error("expected violation callback to be called")
+		t.Fatal("expected violation callback to be called")
 	}
 
-	if lastViolation.ViolationType != "memory" {
-		t.Errorf("expected memory violation, got %s", lastViolation.ViolationType)
+	violation := lastViolation.Load().(ResourceViolation)
+	if violation.ViolationType != "memory" {
+		t.Errorf("expected memory violation, got %s", violation.ViolationType)
 	}
-	if lastViolation.AgentID != "agent-1" {
-		t.Errorf("expected agent-1, got %s", lastViolation.AgentID)
+	if violation.AgentID != "agent-1" {
+		t.Errorf("expected agent-1, got %s", violation.AgentID)
 	}
 }
 
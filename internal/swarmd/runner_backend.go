@@ -0,0 +1,323 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/agent/runner"
+	"github.com/opencode-ai/swarm/internal/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RunnerPanePrefix starts the pane ID of every agent run by the runner
+// backend, followed by the agent ID.
+const RunnerPanePrefix = "runner:"
+
+const (
+	// runnerScreenLines is how many of the last output lines make up a
+	// runner pane's visible content.
+	runnerScreenLines = 50
+
+	// runnerHistoryBytes caps the output kept for each runner pane.
+	runnerHistoryBytes = 1 << 20
+
+	// runnerKillTimeout is how long a killed runner process has to exit.
+	runnerKillTimeout = 5 * time.Second
+)
+
+// ErrAttachUnsupported is returned for operations that need a terminal
+// the runner backend does not have: adopting an existing pane, or
+// attaching to an agent.
+var ErrAttachUnsupported = errors.New("the runner execution backend has no panes to attach to; use the tmux backend")
+
+// ErrLayoutUnsupported is returned for resizing or arranging panes, which
+// the runner backend does not have.
+var ErrLayoutUnsupported = errors.New("the runner execution backend has no panes to resize or arrange; use the tmux backend")
+
+// IsRunnerPane reports whether paneID names an agent run by the runner
+// backend rather than a tmux pane.
+func IsRunnerPane(paneID string) bool {
+	return strings.HasPrefix(paneID, RunnerPanePrefix)
+}
+
+// runnerKeys maps the tmux key names the daemon's callers send to the
+// bytes the runner writes for them. Enter is a newline, as in
+// runner.SendInput.
+var runnerKeys = map[string]string{
+	"Enter":  "\n",
+	"C-m":    "\n",
+	"Tab":    "\t",
+	"Space":  " ",
+	"Escape": "\x1b",
+	"BSpace": "\x7f",
+	"Up":     "\x1b[A",
+	"Down":   "\x1b[B",
+	"Right":  "\x1b[C",
+	"Left":   "\x1b[D",
+}
+
+// terminalEscapes matches the CSI, OSC, and two-byte escape sequences left
+// out of runner pane captures, which tmux would have rendered.
+var terminalEscapes = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`)
+
+// runnerBackend runs each agent under an agent runner held by the daemon,
+// on a PTY where the platform has one and on plain pipes otherwise. It
+// needs no tmux, so it is the backend on Windows. Agents do not outlive
+// the daemon, and there is nothing to attach to.
+type runnerBackend struct {
+	mu     sync.Mutex
+	agents map[string]*runnerProcess // keyed by pane ID
+}
+
+// runnerProcess is one agent run by the runner backend.
+type runnerProcess struct {
+	runner *runner.Runner
+	output *outputBuffer
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newRunnerBackend() *runnerBackend {
+	return &runnerBackend{agents: make(map[string]*runnerProcess)}
+}
+
+func (b *runnerBackend) Name() string { return config.ExecutionBackendRunner }
+
+// Start runs cmdLine through the platform's shell under a new runner. The
+// process is tied to the backend, not to ctx, which ends with the spawn
+// call.
+func (b *runnerBackend) Start(ctx context.Context, req *swarmdv1.SpawnAgentRequest, cmdLine string) (string, error) {
+	paneID := RunnerPanePrefix + req.AgentId
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, exists := b.agents[paneID]; exists {
+		return "", status.Errorf(codes.AlreadyExists, "pane %q already exists", paneID)
+	}
+
+	env := make([]string, 0, len(req.Env))
+	for k, v := range req.Env {
+		env = append(env, k+"="+v)
+	}
+	output := newOutputBuffer(runnerHistoryBytes)
+	r := &runner.Runner{
+		WorkspaceID:  req.WorkspaceId,
+		AgentID:      req.AgentId,
+		Command:      shellCommand(cmdLine),
+		Dir:          req.WorkingDir,
+		Env:          env,
+		Adapter:      req.Adapter,
+		OutputWriter: output,
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	if err := r.Start(runCtx); err != nil {
+		cancel()
+		return "", status.Errorf(codes.Internal, "failed to start agent: %v", err)
+	}
+
+	p := &runnerProcess{runner: r, output: output, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		_ = r.Wait()
+		close(p.done)
+	}()
+	b.agents[paneID] = p
+	return paneID, nil
+}
+
+func (b *runnerBackend) lookup(paneID string) (*runnerProcess, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p, ok := b.agents[paneID]
+	if !ok {
+		return nil, fmt.Errorf("pane %q not found", paneID)
+	}
+	return p, nil
+}
+
+// PaneExists refuses: the runner backend only knows the agents it started.
+func (b *runnerBackend) PaneExists(ctx context.Context, paneID string) (bool, error) {
+	return false, ErrAttachUnsupported
+}
+
+func (b *runnerBackend) PanePID(ctx context.Context, paneID string) (int, error) {
+	p, err := b.lookup(paneID)
+	if err != nil {
+		return 0, err
+	}
+	return p.runner.PID(), nil
+}
+
+func (b *runnerBackend) SendKeys(ctx context.Context, paneID, keys string, literal, enter bool) error {
+	p, err := b.lookup(paneID)
+	if err != nil {
+		return err
+	}
+	if !literal {
+		data, err := runnerKeyBytes(keys)
+		if err != nil {
+			return err
+		}
+		keys = data
+	}
+	if enter {
+		return p.runner.SendInput(ctx, keys)
+	}
+	return p.runner.SendKeys(keys)
+}
+
+// runnerKeyBytes returns what a tmux key name such as "Escape" or "C-c"
+// types.
+func runnerKeyBytes(key string) (string, error) {
+	if data, ok := runnerKeys[key]; ok {
+		return data, nil
+	}
+	if len(key) == 3 && strings.HasPrefix(key, "C-") {
+		if c := key[2] | 0x20; c >= 'a' && c <= 'z' {
+			return string(rune(c - 'a' + 1)), nil
+		}
+	}
+	if len(key) == 1 {
+		return key, nil
+	}
+	return "", fmt.Errorf("unsupported key %q", key)
+}
+
+func (b *runnerBackend) SendInterrupt(ctx context.Context, paneID string) error {
+	p, err := b.lookup(paneID)
+	if err != nil {
+		return err
+	}
+	return p.runner.Interrupt()
+}
+
+// CapturePane returns the last runnerScreenLines lines of output, or all
+// the output kept when history is set.
+func (b *runnerBackend) CapturePane(ctx context.Context, paneID string, history bool) (string, error) {
+	p, err := b.lookup(paneID)
+	if err != nil {
+		return "", err
+	}
+	lines := outputLines(p.output.String())
+	if !history && len(lines) > runnerScreenLines {
+		lines = lines[len(lines)-runnerScreenLines:]
+	}
+	return joinLines(lines), nil
+}
+
+func (b *runnerBackend) CapturePaneRange(ctx context.Context, paneID string, lines int) (string, bool, error) {
+	if lines <= 0 {
+		return "", false, fmt.Errorf("lines must be positive, got %d", lines)
+	}
+	p, err := b.lookup(paneID)
+	if err != nil {
+		return "", false, err
+	}
+	all := outputLines(p.output.String())
+	truncated := len(all) > lines
+	if truncated {
+		all = all[len(all)-lines:]
+	}
+	return joinLines(all), truncated, nil
+}
+
+// KillPane kills the agent's process and forgets its pane. Force makes no
+// difference: the process is always killed outright, after a caller's
+// interrupt and grace period.
+func (b *runnerBackend) KillPane(ctx context.Context, paneID string, force bool) error {
+	b.mu.Lock()
+	p, ok := b.agents[paneID]
+	b.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	p.cancel()
+	timer := time.NewTimer(runnerKillTimeout)
+	defer timer.Stop()
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return fmt.Errorf("agent process %d survived kill: %s", p.runner.PID(), paneID)
+	}
+
+	b.mu.Lock()
+	delete(b.agents, paneID)
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *runnerBackend) ResizePane(ctx context.Context, paneID string, width, height int) (int, int, error) {
+	return 0, 0, ErrLayoutUnsupported
+}
+
+func (b *runnerBackend) SelectLayout(ctx context.Context, session, layout string) error {
+	return ErrLayoutUnsupported
+}
+
+// Check always passes: the runner backend needs nothing outside the daemon.
+func (b *runnerBackend) Check(ctx context.Context) error {
+	return nil
+}
+
+// outputLines renders raw terminal output as lines of text: escape
+// sequences are dropped, and a carriage return overwrites its line.
+func outputLines(raw string) []string {
+	raw = terminalEscapes.ReplaceAllString(raw, "")
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(raw, "\n"), "\n")
+	for i, line := range lines {
+		if idx := strings.LastIndexByte(line, '\r'); idx >= 0 {
+			lines[i] = line[idx+1:]
+		}
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines
+}
+
+// joinLines joins lines into content ending in a newline, as tmux
+// captures do.
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// outputBuffer keeps the last max bytes written to it.
+type outputBuffer struct {
+	mu   sync.Mutex
+	max  int
+	data []byte
+}
+
+func newOutputBuffer(max int) *outputBuffer {
+	return &outputBuffer{max: max}
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.max; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+	}
+	return len(p), nil
+}
+
+func (b *outputBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
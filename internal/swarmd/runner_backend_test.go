@@ -0,0 +1,129 @@
+package swarmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestEchoHelperProcess prints a prompt and echoes each line of input back
+// until it is killed. It is run as the agent of the runner backend tests.
+func TestEchoHelperProcess(t *testing.T) {
+	if os.Getenv("SWARM_ECHO_HELPER") == "" {
+		t.Skip("helper process")
+	}
+	fmt.Println("ready>")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fmt.Printf("echo: %s\n", scanner.Text())
+	}
+	time.Sleep(time.Minute)
+}
+
+func TestRunnerBackendAgentLifecycle(t *testing.T) {
+	server := NewServer(zerolog.Nop(), WithExecutionBackend(newRunnerBackend()))
+	ctx := context.Background()
+
+	spawned, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{
+		AgentId:     "agent-1",
+		WorkspaceId: "ws-1",
+		Command:     os.Args[0],
+		Args:        []string{"-test.run=^TestEchoHelperProcess$"},
+		Env:         map[string]string{"SWARM_ECHO_HELPER": "1"},
+		WorkingDir:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent() error = %v", err)
+	}
+	if !IsRunnerPane(spawned.PaneId) || spawned.Agent.Pid <= 0 {
+		t.Fatalf("expected a runner pane with a process, got pane %q pid %d", spawned.PaneId, spawned.Agent.Pid)
+	}
+
+	waitForCapture(t, server, "ready>")
+	if _, err := server.SendInput(ctx, &swarmdv1.SendInputRequest{AgentId: "agent-1", Text: "hello", SendEnter: true}); err != nil {
+		t.Fatalf("SendInput() error = %v", err)
+	}
+	waitForCapture(t, server, "echo: hello")
+
+	resp, err := server.CapturePane(ctx, &swarmdv1.CapturePaneRequest{AgentId: "agent-1", Lines: 1})
+	if err != nil {
+		t.Fatalf("CapturePane() error = %v", err)
+	}
+	if resp.Content != "echo: hello\n" || !resp.Truncated {
+		t.Fatalf("expected the last line, truncated, got %+v", resp)
+	}
+
+	if _, err := server.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: "agent-1", Force: true}); err != nil {
+		t.Fatalf("KillAgent() error = %v", err)
+	}
+	if _, err := server.GetAgent(ctx, &swarmdv1.GetAgentRequest{AgentId: "agent-1"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected agent to be removed, got %v", err)
+	}
+	if _, err := server.backend.CapturePane(ctx, spawned.PaneId, false); err == nil {
+		t.Fatal("expected the killed agent's pane to be gone")
+	}
+}
+
+func TestRunnerBackendRefusesAttach(t *testing.T) {
+	server := NewServer(zerolog.Nop(), WithExecutionBackend(newRunnerBackend()))
+
+	_, err := server.SpawnAgent(context.Background(), &swarmdv1.SpawnAgentRequest{
+		AgentId:            "agent-1",
+		WorkspaceId:        "ws-1",
+		Command:            "agent",
+		PaneId:             "swarm-ws-1:0.1",
+		AttachExistingPane: true,
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+}
+
+func TestOutputLines(t *testing.T) {
+	raw := "\x1b[1mbold\x1b[0m\r\nloading 10%\rloading 100%\r\n\x1b]0;title\x07done\n"
+	got := strings.Join(outputLines(raw), "|")
+	if want := "bold|loading 100%|done"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if lines := outputLines(""); lines != nil {
+		t.Fatalf("expected no lines, got %q", lines)
+	}
+}
+
+func TestRunnerKeyBytes(t *testing.T) {
+	for key, want := range map[string]string{"Enter": "\n", "Escape": "\x1b", "C-c": "\x03", "C-D": "\x04", "y": "y"} {
+		if got, err := runnerKeyBytes(key); err != nil || got != want {
+			t.Fatalf("%s: expected %q, got %q (%v)", key, want, got, err)
+		}
+	}
+	if _, err := runnerKeyBytes("F13"); err == nil {
+		t.Fatal("expected an unsupported key error")
+	}
+}
+
+func waitForCapture(t *testing.T, server *Server, text string) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp, err := server.CapturePane(context.Background(), &swarmdv1.CapturePaneRequest{AgentId: "agent-1"})
+		if err != nil {
+			t.Fatalf("CapturePane() error = %v", err)
+		}
+		if strings.Contains(resp.Content, text) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q, got %q", text, resp.Content)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
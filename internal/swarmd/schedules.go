@@ -0,0 +1,277 @@
+package swarmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/cron"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultScheduleInterval is how often due schedules are checked.
+	DefaultScheduleInterval = 15 * time.Second
+
+	// scheduleMissedGrace is how late a run may be handled and still count
+	// as on time rather than missed.
+	scheduleMissedGrace = 2 * time.Minute
+)
+
+// ScheduleRunReport summarizes one pass over due schedules.
+type ScheduleRunReport struct {
+	// Ran lists schedules whose message was enqueued.
+	Ran []string
+
+	// Skipped lists schedules whose missed run was dropped by the skip
+	// catch-up policy.
+	Skipped []string
+
+	// Enqueued is the number of queue items created.
+	Enqueued int
+
+	// Errors lists schedules that could not be handled.
+	Errors []string
+}
+
+// ScheduleRunner enqueues the message of each recurring schedule when its
+// next run comes due, then advances the schedule to the following run.
+type ScheduleRunner struct {
+	scheduleRepo *db.ScheduleRepository
+	agentRepo    *db.AgentRepository
+	clock        clock.Clock
+	logger       zerolog.Logger
+
+	// mu guards the settings Reconfigure replaces.
+	mu           sync.Mutex
+	location     *time.Location
+	catchUp      string
+	interval     time.Duration
+	reconfigured chan struct{}
+}
+
+// ScheduleRunnerOption configures a ScheduleRunner.
+type ScheduleRunnerOption func(*ScheduleRunner)
+
+// WithScheduleLocation sets the time zone cron expressions are evaluated in.
+func WithScheduleLocation(loc *time.Location) ScheduleRunnerOption {
+	return func(r *ScheduleRunner) {
+		r.location = loc
+	}
+}
+
+// WithScheduleCatchUp sets the policy for runs missed while the daemon was
+// down (config.ScheduleCatchUpSkip or config.ScheduleCatchUpRunOnce).
+func WithScheduleCatchUp(policy string) ScheduleRunnerOption {
+	return func(r *ScheduleRunner) {
+		r.catchUp = policy
+	}
+}
+
+// WithScheduleInterval sets how often due schedules are checked.
+func WithScheduleInterval(d time.Duration) ScheduleRunnerOption {
+	return func(r *ScheduleRunner) {
+		r.interval = d
+	}
+}
+
+// WithScheduleClock sets the time source for due checks.
+func WithScheduleClock(c clock.Clock) ScheduleRunnerOption {
+	return func(r *ScheduleRunner) {
+		r.clock = c
+	}
+}
+
+// NewScheduleRunner creates a runner over the given database.
+func NewScheduleRunner(database *db.DB, logger zerolog.Logger, opts ...ScheduleRunnerOption) *ScheduleRunner {
+	r := &ScheduleRunner{
+		scheduleRepo: db.NewScheduleRepository(database),
+		agentRepo:    db.NewAgentRepository(database),
+		location:     time.Local,
+		catchUp:      config.ScheduleCatchUpSkip,
+		interval:     DefaultScheduleInterval,
+		logger:       logger,
+		reconfigured: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.clock = clock.OrReal(r.clock)
+	if r.interval <= 0 {
+		r.interval = DefaultScheduleInterval
+	}
+	return r
+}
+
+// Reconfigure applies the schedule time zone and catch-up policy, and the
+// check interval of cfg. An invalid time zone is an error and leaves the
+// runner unchanged. A running loop checks again at once and then every new
+// interval.
+func (r *ScheduleRunner) Reconfigure(cfg *config.Config) error {
+	loc, err := cfg.Scheduler.ScheduleLocation()
+	if err != nil {
+		return err
+	}
+	catchUp := cfg.Scheduler.ScheduleCatchUp
+	if catchUp == "" {
+		catchUp = config.ScheduleCatchUpSkip
+	}
+	interval := cfg.Daemon.ScheduleInterval
+	if interval <= 0 {
+		interval = DefaultScheduleInterval
+	}
+
+	r.mu.Lock()
+	r.location, r.catchUp, r.interval = loc, catchUp, interval
+	r.mu.Unlock()
+
+	select {
+	case r.reconfigured <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (r *ScheduleRunner) settings() (*time.Location, string, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.location, r.catchUp, r.interval
+}
+
+// Run checks for due schedules immediately and then every interval until
+// ctx is canceled.
+func (r *ScheduleRunner) Run(ctx context.Context) {
+	_, _, interval := r.settings()
+	ticker := r.clock.NewTicker(interval)
+	defer func() { ticker.Stop() }()
+
+	for {
+		r.runOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case <-r.reconfigured:
+			ticker.Stop()
+			_, _, interval = r.settings()
+			ticker = r.clock.NewTicker(interval)
+		}
+	}
+}
+
+func (r *ScheduleRunner) runOnce(ctx context.Context) {
+	report, err := r.RunDue(ctx)
+	if err != nil {
+		r.logger.Warn().Ctx(ctx).Err(err).Msg("schedule check failed")
+		return
+	}
+	for _, msg := range report.Errors {
+		r.logger.Warn().Ctx(ctx).Str("error", msg).Msg("schedule could not run")
+	}
+	if len(report.Ran) > 0 || len(report.Skipped) > 0 {
+		r.logger.Info().
+			Ctx(ctx).
+			Int("ran", len(report.Ran)).
+			Int("skipped", len(report.Skipped)).
+			Int("enqueued", report.Enqueued).
+			Msg("schedules handled")
+	}
+}
+
+// RunDue handles every schedule due at the current time. A run handled
+// more than a short grace period late counts as missed: the skip policy
+// drops it, run_once enqueues it once. Either way the schedule moves to its
+// first run after now, so a long outage never fires a backlog.
+func (r *ScheduleRunner) RunDue(ctx context.Context) (*ScheduleRunReport, error) {
+	now := r.clock.Now().UTC()
+	due, err := r.scheduleRepo.ListDue(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ScheduleRunReport{}
+	for _, schedule := range due {
+		if err := r.handle(ctx, schedule, now, report); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", schedule.ID, err))
+		}
+	}
+	return report, nil
+}
+
+func (r *ScheduleRunner) handle(ctx context.Context, schedule *models.Schedule, now time.Time, report *ScheduleRunReport) error {
+	dueAt := *schedule.NextRunAt
+	location, catchUp, _ := r.settings()
+
+	var next *time.Time
+	expr, parseErr := cron.Parse(schedule.Cron)
+	if parseErr == nil {
+		if t := expr.Next(now.In(location)); !t.IsZero() {
+			next = &t
+		}
+	}
+
+	if parseErr != nil || (now.Sub(dueAt) > scheduleMissedGrace && catchUp == config.ScheduleCatchUpSkip) {
+		advanced, err := r.scheduleRepo.Advance(ctx, schedule.ID, dueAt, next, nil, nil)
+		if err != nil {
+			return err
+		}
+		if parseErr != nil {
+			return fmt.Errorf("invalid cron expression, schedule stopped: %w", parseErr)
+		}
+		if advanced {
+			report.Skipped = append(report.Skipped, schedule.ID)
+		}
+		return nil
+	}
+
+	items, err := r.materialize(ctx, schedule)
+	if err != nil {
+		return err
+	}
+	advanced, err := r.scheduleRepo.Advance(ctx, schedule.ID, dueAt, next, &now, items)
+	if err != nil {
+		return err
+	}
+	if advanced {
+		report.Ran = append(report.Ran, schedule.ID)
+		report.Enqueued += len(items)
+	}
+	return nil
+}
+
+// materialize builds the queue items for one run: one for the target agent,
+// or one per agent currently in the target workspace.
+func (r *ScheduleRunner) materialize(ctx context.Context, schedule *models.Schedule) ([]*models.QueueItem, error) {
+	agentIDs := []string{schedule.AgentID}
+	if schedule.WorkspaceID != "" {
+		agents, err := r.agentRepo.ListByWorkspace(ctx, schedule.WorkspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workspace agents: %w", err)
+		}
+		agentIDs = agentIDs[:0]
+		for _, agent := range agents {
+			agentIDs = append(agentIDs, agent.ID)
+		}
+	}
+
+	payload, err := json.Marshal(models.MessagePayload{Text: schedule.Message})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+	items := make([]*models.QueueItem, 0, len(agentIDs))
+	for _, agentID := range agentIDs {
+		items = append(items, &models.QueueItem{
+			AgentID: agentID,
+			Type:    models.QueueItemTypeMessage,
+			Status:  models.QueueItemStatusPending,
+			Payload: payload,
+		})
+	}
+	return items, nil
+}
@@ -0,0 +1,275 @@
+package swarmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestScheduleRunnerRunDue(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "repo", RepoPath: "/repos/repo", TmuxSession: "repo"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agentRepo := db.NewAgentRepository(database)
+	agents := make([]*models.Agent, 2)
+	for i := range agents {
+		agents[i] = &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "repo:0." + string(rune('1'+i)), State: models.AgentStateIdle}
+		if err := agentRepo.Create(ctx, agents[i]); err != nil {
+			t.Fatalf("failed to create agent: %v", err)
+		}
+	}
+
+	// Wednesday 2026-10-14 09:00:10 UTC.
+	now := time.Date(2026, 10, 14, 9, 0, 10, 0, time.UTC)
+	scheduleRepo := db.NewScheduleRepository(database)
+	seed := func(target models.Schedule, due time.Time) *models.Schedule {
+		target.Enabled = true
+		target.NextRunAt = &due
+		if err := scheduleRepo.Create(ctx, &target); err != nil {
+			t.Fatalf("failed to create schedule: %v", err)
+		}
+		return &target
+	}
+	onTime := seed(models.Schedule{AgentID: agents[0].ID, Cron: "0 9 * * 1-5", Message: "standup"}, now.Add(-10*time.Second))
+	broadcast := seed(models.Schedule{WorkspaceID: ws.ID, Cron: "0 * * * *", Message: "commit"}, now.Add(-5*time.Second))
+	missed := seed(models.Schedule{AgentID: agents[1].ID, Cron: "0 6 * * *", Message: "nightly"}, now.Add(-3*time.Hour))
+	notYet := seed(models.Schedule{AgentID: agents[1].ID, Cron: "0 12 * * *", Message: "lunch"}, now.Add(3*time.Hour))
+
+	newRunner := func(policy string) *ScheduleRunner {
+		return NewScheduleRunner(database, zerolog.Nop(),
+			WithScheduleLocation(time.UTC),
+			WithScheduleCatchUp(policy),
+			WithScheduleClock(clock.NewFake(now)))
+	}
+
+	report, err := newRunner(config.ScheduleCatchUpSkip).RunDue(ctx)
+	if err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", report.Errors)
+	}
+	if len(report.Ran) != 2 || len(report.Skipped) != 1 || report.Skipped[0] != missed.ID || report.Enqueued != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	queueRepo := db.NewQueueRepository(database)
+	texts := func(agentID string) []string {
+		items, err := queueRepo.List(ctx, agentID)
+		if err != nil {
+			t.Fatalf("failed to list queue: %v", err)
+		}
+		var out []string
+		for _, item := range items {
+			var payload models.MessagePayload
+			if err := json.Unmarshal(item.Payload, &payload); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+			out = append(out, payload.Text)
+		}
+		return out
+	}
+	if got := texts(agents[0].ID); len(got) != 2 || got[0] != "standup" || got[1] != "commit" {
+		t.Fatalf("agent 0 queue = %v, want [standup commit]", got)
+	}
+	if got := texts(agents[1].ID); len(got) != 1 || got[0] != "commit" {
+		t.Fatalf("agent 1 queue = %v, want [commit]", got)
+	}
+
+	expectNext := map[string]time.Time{
+		onTime.ID:    time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC),
+		broadcast.ID: time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+		missed.ID:    time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC),
+		notYet.ID:    now.Add(3 * time.Hour),
+	}
+	for id, want := range expectNext {
+		got, err := scheduleRepo.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get schedule: %v", err)
+		}
+		if got.NextRunAt == nil || !got.NextRunAt.Equal(want) {
+			t.Errorf("schedule %s next run = %v, want %s", got.Message, got.NextRunAt, want)
+		}
+		if ran := got.LastRunAt != nil; ran != (id == onTime.ID || id == broadcast.ID) {
+			t.Errorf("schedule %s last run = %v", got.Message, got.LastRunAt)
+		}
+	}
+
+	// A restarted daemon finds nothing left to fire.
+	report, err = newRunner(config.ScheduleCatchUpSkip).RunDue(ctx)
+	if err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if len(report.Ran) != 0 || len(report.Skipped) != 0 {
+		t.Fatalf("expected no runs on restart, got %+v", report)
+	}
+}
+
+func TestScheduleRunnerCatchUpRunOnce(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "repo", RepoPath: "/repos/repo", TmuxSession: "repo"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "repo:0.1", State: models.AgentStateIdle}
+	if err := db.NewAgentRepository(database).Create(ctx, agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	// The daemon was down for several runs of an every-15-minutes schedule.
+	now := time.Date(2026, 10, 14, 9, 7, 0, 0, time.UTC)
+	due := now.Add(-2 * time.Hour)
+	schedule := &models.Schedule{AgentID: agent.ID, Cron: "*/15 * * * *", Message: "check in", Enabled: true, NextRunAt: &due}
+	if err := db.NewScheduleRepository(database).Create(ctx, schedule); err != nil {
+		t.Fatalf("failed to create schedule: %v", err)
+	}
+
+	runner := NewScheduleRunner(database, zerolog.Nop(),
+		WithScheduleLocation(time.UTC),
+		WithScheduleCatchUp(config.ScheduleCatchUpRunOnce),
+		WithScheduleClock(clock.NewFake(now)))
+	for i := 0; i < 2; i++ {
+		if _, err := runner.RunDue(ctx); err != nil {
+			t.Fatalf("RunDue failed: %v", err)
+		}
+	}
+
+	items, err := db.NewQueueRepository(database).List(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("failed to list queue: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected the missed runs to enqueue once, got %d items", len(items))
+	}
+	got, err := db.NewScheduleRepository(database).Get(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("failed to get schedule: %v", err)
+	}
+	if want := time.Date(2026, 10, 14, 9, 15, 0, 0, time.UTC); !got.NextRunAt.Equal(want) {
+		t.Fatalf("next run = %s, want %s", got.NextRunAt, want)
+	}
+}
+
+func TestScheduleRunnerReconfigure(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "repo", RepoPath: "/repos/repo", TmuxSession: "repo"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	// Wednesday 2026-10-14 09:00:00 UTC.
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	due := now.Add(10 * time.Minute)
+	scheduleRepo := db.NewScheduleRepository(database)
+	schedule := &models.Schedule{WorkspaceID: ws.ID, Cron: "0 9 * * *", Message: "standup", Enabled: true, NextRunAt: &due}
+	if err := scheduleRepo.Create(ctx, schedule); err != nil {
+		t.Fatalf("failed to create schedule: %v", err)
+	}
+
+	fake := clock.NewFake(now)
+	runner := NewScheduleRunner(database, zerolog.Nop(),
+		WithScheduleLocation(time.UTC),
+		WithScheduleInterval(time.Hour),
+		WithScheduleClock(fake))
+
+	// An invalid time zone is refused and the running settings stay.
+	bad := config.DefaultConfig()
+	bad.Scheduler.ScheduleTimezone = "Nowhere/Special"
+	if err := runner.Reconfigure(bad); err == nil {
+		t.Fatal("expected an invalid time zone to be rejected")
+	}
+	if loc, _, interval := runner.settings(); loc != time.UTC || interval != time.Hour {
+		t.Fatalf("expected the running settings kept, got %v %s", loc, interval)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runner.Run(runCtx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	fake.BlockUntil(1)
+
+	cfg := config.DefaultConfig()
+	cfg.Scheduler.ScheduleTimezone = "America/New_York"
+	cfg.Daemon.ScheduleInterval = time.Minute
+	if err := runner.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+
+	// With the old hourly check the schedule would still be waiting; the
+	// new one-minute check runs it shortly after it comes due.
+	var got *models.Schedule
+	for minute := 0; minute < 30 && (got == nil || got.LastRunAt == nil); minute++ {
+		fake.Advance(time.Minute)
+		for wait := 0; wait < 50; wait++ {
+			if got, err = scheduleRepo.Get(ctx, schedule.ID); err == nil && got.LastRunAt != nil {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if got == nil || got.LastRunAt == nil {
+		t.Fatal("expected the schedule to run on the new interval")
+	}
+	// The next run is 09:00 in New York, later the same day, not tomorrow
+	// at 09:00 UTC.
+	if want := time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC); !got.NextRunAt.Equal(want) {
+		t.Fatalf("expected the next run at %s, got %s", want, got.NextRunAt)
+	}
+}
@@ -0,0 +1,42 @@
+package swarmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// NotifySocketEnv is set by systemd for services with Type=notify.
+const NotifySocketEnv = "NOTIFY_SOCKET"
+
+// sd_notify states sent by swarmd.
+const (
+	sdNotifyReady    = "READY=1"
+	sdNotifyStopping = "STOPPING=1"
+)
+
+// sdNotify sends state to the service manager over the datagram socket
+// named by NOTIFY_SOCKET, as sd_notify(3) does. It reports false and does
+// nothing when the variable is unset, i.e. outside systemd.
+func sdNotify(state string) (bool, error) {
+	name := os.Getenv(NotifySocketEnv)
+	if name == "" {
+		return false, nil
+	}
+	// A leading @ names a socket in the abstract namespace.
+	if strings.HasPrefix(name, "@") {
+		name = "\x00" + name[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to %s: %w", NotifySocketEnv, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to notify service manager: %w", err)
+	}
+	return true, nil
+}
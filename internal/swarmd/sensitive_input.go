@@ -0,0 +1,83 @@
+package swarmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+)
+
+// DefaultSensitiveInputWindow is how long pane output goes unrecorded after
+// a sensitive input unless WithSensitiveInputWindow says otherwise.
+const DefaultSensitiveInputWindow = 2 * time.Second
+
+// sensitiveInputMarker is recorded in the transcript in place of the text
+// of a sensitive input.
+const sensitiveInputMarker = "[sensitive input]"
+
+// sensitiveInput is an agent's suppression of OUTPUT recording after a
+// sensitive input, while the pane may echo it.
+type sensitiveInput struct {
+	// texts are the secrets sent, held in memory only to spot them on
+	// screen, and dropped with the suppression.
+	texts []string
+	// window is when output may be recorded again, once the pane no
+	// longer shows any of texts.
+	window   clock.Deadline
+	lastHash string
+}
+
+// WithSensitiveInputWindow sets how long pane output goes unrecorded after
+// a sensitive input. Output that still shows the input stays unrecorded
+// past it.
+func WithSensitiveInputWindow(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.sensitiveWindow.Store(int64(d))
+	}
+}
+
+// beginSensitiveInputLocked stops OUTPUT recording for the agent ahead of
+// sending it text as a sensitive input. A suppression already underway is
+// extended. The caller must hold info.mu.
+func (s *Server) beginSensitiveInputLocked(info *agentInfo, text string) {
+	window := clock.NewDeadline(s.clock, time.Duration(s.sensitiveWindow.Load()))
+	if info.sensitive == nil {
+		info.sensitive = &sensitiveInput{}
+	}
+	if window.After(info.sensitive.window) {
+		info.sensitive.window = window
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		info.sensitive.texts = append(info.sensitive.texts, text)
+	}
+}
+
+// sensitiveOutputLocked reports whether a changed pane snapshot must not
+// be recorded because of a sensitive input, counting the snapshots left
+// out for the next OUTPUT entry's metadata. The suppression ends with the
+// first snapshot past the window that shows none of the inputs. The
+// caller must hold info.mu.
+func (s *Server) sensitiveOutputLocked(info *agentInfo, snap paneSnapshot) bool {
+	pending := info.sensitive
+	if pending == nil {
+		return false
+	}
+	if !pending.window.Expired(s.clock) || showsAny(snap.content, pending.texts) {
+		if snap.hash != pending.lastHash {
+			info.sensitiveSkipped++
+			pending.lastHash = snap.hash
+		}
+		return true
+	}
+	info.sensitive = nil
+	return false
+}
+
+func showsAny(content string, texts []string) bool {
+	for _, text := range texts {
+		if strings.Contains(content, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package swarmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+)
+
+func TestSendInputSensitiveKeepsSecretOutOfTranscript(t *testing.T) {
+	ctx := context.Background()
+	const secret = "ghp_s3cr3tT0k3nValue"
+
+	srv := tmuxtest.NewServer()
+	if _, err := srv.Run("new-session", "-d", "-s", "login"); err != nil {
+		t.Fatalf("new-session: %v", err)
+	}
+	fake := clock.NewFake(time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC))
+	server := NewServer(zerolog.Nop(),
+		WithTmuxClient(tmux.NewClient(srv)),
+		WithClock(fake),
+		WithTranscriptBackfillLimit(0),
+		WithSensitiveInputWindow(2*time.Second),
+	)
+	if _, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-1", AttachExistingPane: true, PaneId: "%0"}); err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	loop := &captureLoop{agentID: "agent-1"}
+	capture := func() {
+		t.Helper()
+		if !server.captureOnce(ctx, loop) {
+			t.Fatal("capture loop stopped")
+		}
+	}
+
+	if err := srv.Print("%0", "Paste your token:\n"); err != nil {
+		t.Fatalf("print: %v", err)
+	}
+	capture()
+	if _, err := server.SendInput(ctx, &swarmdv1.SendInputRequest{AgentId: "agent-1", Text: secret, SendEnter: true, Sensitive: true}); err != nil {
+		t.Fatalf("SendInput failed: %v", err)
+	}
+	if screen, _ := srv.Capture("%0"); !strings.Contains(screen, secret) {
+		t.Fatalf("expected the fake terminal to echo the input, got %q", screen)
+	}
+
+	// Within the window, and past it while the pane still shows the
+	// secret, nothing is recorded.
+	capture()
+	fake.Advance(3 * time.Second)
+	capture()
+	if _, err := server.SendInput(ctx, &swarmdv1.SendInputRequest{AgentId: "agent-1", Text: "clear", SendEnter: true}); err != nil {
+		t.Fatalf("SendInput failed: %v", err)
+	}
+	capture()
+
+	resp, err := server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetTranscript failed: %v", err)
+	}
+	var inputs, outputs []*swarmdv1.TranscriptEntry
+	for _, entry := range resp.Entries {
+		if strings.Contains(entry.Content, secret) {
+			t.Fatalf("secret stored in transcript entry %d: %q", entry.Id, entry.Content)
+		}
+		for key, value := range entry.Metadata {
+			if strings.Contains(value, secret) {
+				t.Fatalf("secret stored in metadata %q of entry %d", key, entry.Id)
+			}
+		}
+		switch entry.Type {
+		case swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_USER_INPUT:
+			inputs = append(inputs, entry)
+		case swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT:
+			outputs = append(outputs, entry)
+		}
+	}
+
+	if len(inputs) != 2 || inputs[0].Content != sensitiveInputMarker || inputs[0].Metadata["sensitive"] != "true" || inputs[1].Content != "clear" {
+		t.Fatalf("expected the marker and the clear recorded as input, got %v", inputs)
+	}
+	if len(outputs) != 2 {
+		t.Fatalf("expected the output before and after the gap, got %d entries", len(outputs))
+	}
+	if outputs[0].Metadata["sensitive_gap"] != "" {
+		t.Fatalf("expected no gap before the sensitive input, got %v", outputs[0].Metadata)
+	}
+	if after := outputs[1].Metadata; after["sensitive_gap"] != "true" || after["sensitive_skipped"] != "1" {
+		t.Fatalf("expected the entry after the gap flagged, got %v", after)
+	}
+
+	// Recording resumes as normal after the gap.
+	if err := srv.Print("%0", "logged in\n"); err != nil {
+		t.Fatalf("print: %v", err)
+	}
+	capture()
+	resp, err = server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetTranscript failed: %v", err)
+	}
+	var last *swarmdv1.TranscriptEntry
+	for _, entry := range resp.Entries {
+		if entry.Type == swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT {
+			last = entry
+		}
+	}
+	if !strings.Contains(last.Content, "logged in") || last.Metadata["sensitive_gap"] != "" {
+		t.Fatalf("expected normal recording after the gap, got %q %v", last.Content, last.Metadata)
+	}
+}
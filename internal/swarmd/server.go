@@ -3,14 +3,21 @@ package swarmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
+	"regexp"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"time"
 
 	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/account"
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/redact"
 	"github.com/opencode-ai/swarm/internal/tmux"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc/codes"
@@ -26,6 +33,17 @@ type transcriptEntry struct {
 	entryType swarmdv1.TranscriptEntryType
 	content   string
 	metadata  map[string]string
+
+	// endID is the last ID a SUMMARY entry stands for; zero otherwise.
+	endID int64
+}
+
+// lastID returns the last transcript ID e covers.
+func (e *transcriptEntry) lastID() int64 {
+	if e.endID > e.id {
+		return e.endID
+	}
+	return e.id
 }
 
 // storedEvent represents an event stored for cursor-based replay.
@@ -51,39 +69,94 @@ const (
 	eventChannelBuffer = 100
 )
 
-// agentInfo tracks a running agent's state.
+// agentInfo tracks a running agent's state. The fields set at spawn (id
+// through spawnedAt, resourceLimits, and transcriptStart) never change and
+// may be read without a lock; the rest are guarded by mu, so work on one agent never
+// waits on another.
 type agentInfo struct {
 	id          string
 	workspaceID string
 	paneID      string
 	command     string
 	adapter     string
-	pid         int
-	state       swarmdv1.AgentState
 	spawnedAt   time.Time
+
+	mu          sync.Mutex
+	state       swarmdv1.AgentState
 	lastActive  time.Time
 	contentHash string
 
+	// pid is the process in the pane's foreground, and panePID the pane's
+	// own process, usually its shell; see RefreshAgentPIDs.
+	pid     int
+	panePID int
+
+	// removed is set once the agent is killed and dropped from the
+	// registry; callers still holding it treat the agent as not found.
+	removed bool
+
+	// stateChanged is closed when state changes or the agent is removed,
+	// waking GetAgent long polls; see stateWatchLocked.
+	stateChanged chan struct{}
+
+	// recordedHash is the hash of the last OUTPUT transcript entry.
+	recordedHash string
+
+	// sensitive is set while OUTPUT recording is suppressed after a
+	// sensitive input; sensitiveSkipped counts the pane changes left out
+	// since the last OUTPUT entry.
+	sensitive        *sensitiveInput
+	sensitiveSkipped int
+
 	// Resource limits configured for this agent
 	resourceLimits *swarmdv1.ResourceLimits
 
-	// Transcript storage
-	transcript     []transcriptEntry
-	transcriptNext int64 // next ID for new entries
+	// Transcript storage, oldest first. Entries beyond the server's
+	// transcript limit are evicted from the front.
+	transcript      []transcriptEntry
+	transcriptNext  int64 // next ID for new entries
+	transcriptBytes int64 // size of transcript, see transcriptEntrySize
+
+	// transcriptFloor is the first ID not evicted from transcript, and
+	// transcriptEvictedAt the timestamp of the last entry evicted.
+	// Entries from transcriptStart up to transcriptFloor are only in the
+	// transcript store, if any.
+	transcriptFloor     int64
+	transcriptEvictedAt time.Time
+
+	// transcriptStart is the ID of the first entry recorded since spawn;
+	// earlier entries are only in the transcript store.
+	transcriptStart int64
 }
 
 // Server implements the SwarmdService gRPC interface.
 type Server struct {
 	swarmdv1.UnimplementedSwarmdServiceServer
 
-	logger    zerolog.Logger
-	tmux      *tmux.Client
-	startedAt time.Time
-	hostname  string
-	version   string
-
-	mu     sync.RWMutex
-	agents map[string]*agentInfo // keyed by agent ID
+	logger      zerolog.Logger
+	backend     ExecutionBackend
+	clock       clock.Clock
+	startedAt   time.Time
+	startedMono time.Duration
+	hostname    string
+	version     string
+	commit      string
+	built       string
+
+	// mu guards the agent registry only: which agents exist and which
+	// IDs are mid-spawn. Lock order: agentInfo.mu before mu before
+	// captureMu; mu is never held while waiting on an agent or on tmux.
+	mu       sync.RWMutex
+	agents   map[string]*agentInfo // keyed by agent ID
+	spawning map[string]bool       // IDs reserved by in-flight spawns
+
+	// agentsChanged is closed when an agent is registered or removed;
+	// see watchAgents.
+	agentsChanged chan struct{}
+
+	// Per-agent pane capture loops shared by pane update streams.
+	captureMu sync.Mutex
+	captures  map[string]*captureLoop // keyed by agent ID
 
 	// Event streaming infrastructure
 	eventsMu      sync.RWMutex
@@ -92,11 +165,58 @@ type Server struct {
 	eventSubs     map[string]*eventSubscriber // active subscribers keyed by ID
 	eventSubIDSeq int64                       // subscriber ID sequence
 
+	// Open StreamTranscript calls, keyed by agent ID, for DumpState.
+	streamsMu         sync.Mutex
+	transcriptStreams map[string]int
+
+	// Most recent health check results, reported by DumpState.
+	healthMu   sync.Mutex
+	lastHealth *swarmdv1.HealthStatus
+
+	// debugToken enables DumpState for callers presenting it.
+	debugToken string
+
 	// Rate limiter reference for status reporting
 	rateLimiter *RateLimiter
 
 	// Resource monitor for enforcing resource caps
 	resourceMonitor *ResourceMonitor
+
+	// processes resolves and kills the processes agents run as.
+	processes processTable
+
+	// stateDetectors read the state of agents by adapter name. Fixed once
+	// the server is built.
+	stateDetectors map[string]StateDetector
+
+	// costs reports what each agent has cost today, when configured.
+	costs *account.CostTicker
+
+	// transcripts persists transcript entries, when configured.
+	transcripts *db.TranscriptRepository
+
+	// Redactor scrubs secrets from transcript entries and event text
+	redactorMu sync.RWMutex
+	redactor   *redact.Redactor
+
+	// transcriptMaxEntries and transcriptMaxBytes cap each agent's
+	// in-memory transcript; zero is no cap.
+	transcriptMaxEntries atomic.Int64
+	transcriptMaxBytes   atomic.Int64
+
+	// backfillLimit caps the pane history imported for adopted agents.
+	backfillLimit atomic.Int64
+
+	// sensitiveWindow is how long output goes unrecorded after a
+	// sensitive input, as a time.Duration.
+	sensitiveWindow atomic.Int64
+
+	// maxPollInterval is the longest interval an unchanged pane backs
+	// off to, as a time.Duration.
+	maxPollInterval atomic.Int64
+
+	// reload re-reads and applies the daemon config for ReloadConfig.
+	reload func() (*ReloadResult, error)
 }
 
 // ServerOption configures the Server.
@@ -109,24 +229,71 @@ func WithVersion(version string) ServerOption {
 	}
 }
 
+// WithRedactor sets the redactor applied to transcript and event content.
+func WithRedactor(r *redact.Redactor) ServerOption {
+	return func(s *Server) {
+		s.redactor = r
+	}
+}
+
+// WithClock sets the time source for timestamps, uptime, and polling.
+func WithClock(c clock.Clock) ServerOption {
+	return func(s *Server) {
+		s.clock = c
+	}
+}
+
+// WithTmuxClient runs agents in tmux panes through c (default: local
+// tmux).
+func WithTmuxClient(c *tmux.Client) ServerOption {
+	return func(s *Server) {
+		s.backend = newTmuxBackend(c, s.logger)
+	}
+}
+
+// WithExecutionBackend sets what agents run under (default: local tmux).
+func WithExecutionBackend(b ExecutionBackend) ServerOption {
+	return func(s *Server) {
+		s.backend = b
+	}
+}
+
 // NewServer creates a new gRPC server for the swarmd service.
 func NewServer(logger zerolog.Logger, opts ...ServerOption) *Server {
 	hostname, _ := os.Hostname()
 
 	s := &Server{
 		logger:    logger,
-		tmux:      tmux.NewLocalClient(),
-		startedAt: time.Now(),
+		clock:     clock.Real(),
 		hostname:  hostname,
 		version:   "dev",
 		agents:    make(map[string]*agentInfo),
+		spawning:  make(map[string]bool),
+		captures:  make(map[string]*captureLoop),
 		events:    make([]storedEvent, 0, maxStoredEvents),
 		eventSubs: make(map[string]*eventSubscriber),
+		redactor:  redact.Default(),
+		processes: hostProcesses{},
+
+		stateDetectors: builtinStateDetectors(),
+
+		transcriptStreams: make(map[string]int),
 	}
+	s.transcriptMaxEntries.Store(DefaultTranscriptMaxEntries)
+	s.transcriptMaxBytes.Store(DefaultTranscriptMaxBytes)
+	s.backfillLimit.Store(DefaultTranscriptBackfillBytes)
+	s.sensitiveWindow.Store(int64(DefaultSensitiveInputWindow))
+	s.maxPollInterval.Store(int64(DefaultMaxPollInterval))
 
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.clock = clock.OrReal(s.clock)
+	if s.backend == nil {
+		s.backend = newTmuxBackend(tmux.NewLocalClient(), logger)
+	}
+	s.startedAt = s.clock.Now()
+	s.startedMono = s.clock.Monotonic()
 
 	return s
 }
@@ -146,6 +313,11 @@ func (s *Server) SetResourceMonitor(rm *ResourceMonitor) {
 	s.resourceMonitor = rm
 }
 
+// SetCostTicker sets the ticker agent costs are reported from.
+func (s *Server) SetCostTicker(t *account.CostTicker) {
+	s.costs = t
+}
+
 // ResourceMonitor returns the resource monitor, if configured.
 func (s *Server) ResourceMonitor() *ResourceMonitor {
 	return s.resourceMonitor
@@ -155,50 +327,42 @@ func (s *Server) ResourceMonitor() *ResourceMonitor {
 // Agent Control
 // =============================================================================
 
-// SpawnAgent creates a new agent in a tmux pane.
+// SpawnAgent creates a new agent in a tmux pane, or with
+// attach_existing_pane adopts an agent already running in one.
 func (s *Server) SpawnAgent(ctx context.Context, req *swarmdv1.SpawnAgentRequest) (*swarmdv1.SpawnAgentResponse, error) {
 	if req.AgentId == "" {
 		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
 	}
-	if req.Command == "" {
+	if req.AttachExistingPane {
+		if req.PaneId == "" {
+			return nil, status.Error(codes.InvalidArgument, "pane_id is required to attach an existing pane")
+		}
+	} else if req.Command == "" {
 		return nil, status.Error(codes.InvalidArgument, "command is required")
 	}
 
+	// Reserve the ID so a concurrent spawn of the same agent fails fast,
+	// without holding the registry lock through the tmux calls below.
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// Check if agent already exists
-	if _, exists := s.agents[req.AgentId]; exists {
+	if _, exists := s.agents[req.AgentId]; exists || s.spawning[req.AgentId] {
+		s.mu.Unlock()
 		return nil, status.Errorf(codes.AlreadyExists, "agent %q already exists", req.AgentId)
 	}
-
-	// Determine session and window names
-	sessionName := req.SessionName
-	if sessionName == "" {
-		sessionName = fmt.Sprintf("swarm-%s", req.WorkspaceId)
-	}
-
-	workDir := req.WorkingDir
-	if workDir == "" {
-		workDir, _ = os.Getwd()
-	}
-
-	// Ensure session exists
-	hasSession, err := s.tmux.HasSession(ctx, sessionName)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to check session: %v", err)
-	}
-	if !hasSession {
-		if err := s.tmux.NewSession(ctx, sessionName, workDir); err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to create session: %v", err)
+	if req.AttachExistingPane {
+		for _, info := range s.agents {
+			if info.paneID == req.PaneId {
+				s.mu.Unlock()
+				return nil, status.Errorf(codes.AlreadyExists, "pane %q already belongs to agent %q", req.PaneId, info.id)
+			}
 		}
 	}
-
-	// Create a new pane by splitting the window
-	paneID, err := s.tmux.SplitWindow(ctx, sessionName, true, workDir)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create pane: %v", err)
-	}
+	s.spawning[req.AgentId] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.spawning, req.AgentId)
+		s.mu.Unlock()
+	}()
 
 	// Build the command with args
 	cmdLine := req.Command
@@ -206,30 +370,35 @@ func (s *Server) SpawnAgent(ctx context.Context, req *swarmdv1.SpawnAgentRequest
 		cmdLine += " " + arg
 	}
 
-	// Set environment variables and run the command
-	for k, v := range req.Env {
-		envCmd := fmt.Sprintf("export %s=%q", k, v)
-		if err := s.tmux.SendKeys(ctx, paneID, envCmd, true, true); err != nil {
-			s.logger.Warn().Err(err).Str("pane", paneID).Msg("failed to set env var")
+	var paneID string
+	if req.AttachExistingPane {
+		exists, err := s.backend.PaneExists(ctx, req.PaneId)
+		if errors.Is(err, ErrAttachUnsupported) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to look up pane: %v", err)
+		}
+		if !exists {
+			return nil, status.Errorf(codes.NotFound, "pane %q not found", req.PaneId)
+		}
+		paneID = req.PaneId
+	} else {
+		var err error
+		if paneID, err = s.backend.Start(ctx, req, cmdLine); err != nil {
+			return nil, err
 		}
 	}
 
-	// Send the command to the pane
-	if err := s.tmux.SendKeys(ctx, paneID, cmdLine, true, true); err != nil {
-		// Try to clean up the pane
-		_ = s.tmux.KillPane(ctx, paneID)
-		return nil, status.Errorf(codes.Internal, "failed to send command: %v", err)
-	}
-
-	// Get the PID of the process in the pane (after a short delay to let it start)
-	time.Sleep(100 * time.Millisecond)
-	pid, err := s.tmux.GetPanePID(ctx, paneID)
+	// The command may not be in the foreground yet; RefreshAgentPIDs
+	// catches up with it.
+	pid, panePID, err := s.agentPIDs(ctx, paneID)
 	if err != nil {
-		s.logger.Warn().Err(err).Str("pane_id", paneID).Msg("failed to get pane PID")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("pane_id", paneID).Msg("failed to get pane PID")
 		// Continue without PID - resource monitoring will be limited
 	}
 
-	now := time.Now()
+	now := s.clock.Now()
 	info := &agentInfo{
 		id:             req.AgentId,
 		workspaceID:    req.WorkspaceId,
@@ -237,21 +406,47 @@ func (s *Server) SpawnAgent(ctx context.Context, req *swarmdv1.SpawnAgentRequest
 		command:        req.Command,
 		adapter:        req.Adapter,
 		pid:            pid,
+		panePID:        panePID,
 		state:          swarmdv1.AgentState_AGENT_STATE_STARTING,
 		spawnedAt:      now,
 		lastActive:     now,
 		resourceLimits: req.ResourceLimits,
 		transcript:     make([]transcriptEntry, 0, 100), // Pre-allocate for efficiency
 	}
-	s.agents[req.AgentId] = info
+	s.resumeTranscript(ctx, info)
 
-	// Record spawn event in transcript
+	// Record spawn event in transcript, after the pane's history when the
+	// agent is adopted.
+	event, action := "spawn", "agent spawned"
+	if req.AttachExistingPane {
+		event, action = "attach", "agent attached"
+		stats, err := s.backfillTranscript(ctx, info, now)
+		if err != nil {
+			s.logger.Warn().Ctx(ctx).Err(err).Str("pane_id", paneID).Msg("failed to import pane history")
+		} else if stats.entries > 0 {
+			s.logger.Debug().
+				Ctx(ctx).
+				Str("agent_id", req.AgentId).
+				Int("entries", stats.entries).
+				Int("bytes", stats.bytes).
+				Int("dropped_bytes", stats.dropped).
+				Msg("imported pane history into transcript")
+		}
+	}
 	s.addTranscriptEntryLocked(info, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_COMMAND, cmdLine, map[string]string{
-		"event":     "spawn",
+		"event":     event,
 		"adapter":   req.Adapter,
 		"workspace": req.WorkspaceId,
 	})
 
+	// Hold the agent until it is fully registered, so a kill arriving
+	// right after it becomes visible also unregisters it from the monitor.
+	info.mu.Lock()
+	s.mu.Lock()
+	s.agents[req.AgentId] = info
+	s.notifyAgentsLocked()
+	s.mu.Unlock()
+
 	// Register agent with resource monitor for tracking
 	if s.resourceMonitor != nil && pid > 0 {
 		var limits *ResourceLimits
@@ -260,12 +455,15 @@ func (s *Server) SpawnAgent(ctx context.Context, req *swarmdv1.SpawnAgentRequest
 		}
 		s.resourceMonitor.RegisterAgent(req.AgentId, req.WorkspaceId, pid, limits)
 	}
+	agent := s.agentToProto(info)
+	info.mu.Unlock()
 
 	s.logger.Info().
+		Ctx(ctx).
 		Str("agent_id", req.AgentId).
 		Str("pane_id", paneID).
 		Str("command", cmdLine).
-		Msg("agent spawned")
+		Msg(action)
 
 	// Publish agent state changed event (outside lock to avoid deadlock)
 	go s.publishAgentStateChanged(
@@ -273,61 +471,84 @@ func (s *Server) SpawnAgent(ctx context.Context, req *swarmdv1.SpawnAgentRequest
 		req.WorkspaceId,
 		swarmdv1.AgentState_AGENT_STATE_UNSPECIFIED,
 		swarmdv1.AgentState_AGENT_STATE_STARTING,
-		"agent spawned",
+		action,
 	)
 
 	return &swarmdv1.SpawnAgentResponse{
-		Agent:  s.agentToProto(info),
+		Agent:  agent,
 		PaneId: paneID,
 	}, nil
 }
 
-// KillAgent terminates an agent's process.
+// KillAgent terminates an agent's process. A forced kill also sends
+// SIGKILL to the process in the pane's foreground. With a transcript
+// store, the agent's transcript stays readable after it is killed.
 func (s *Server) KillAgent(ctx context.Context, req *swarmdv1.KillAgentRequest) (*swarmdv1.KillAgentResponse, error) {
 	if req.AgentId == "" {
 		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	info, exists := s.agents[req.AgentId]
+	info, exists := s.lockAgent(req.AgentId)
 	if !exists {
 		return nil, status.Errorf(codes.NotFound, "agent %q not found", req.AgentId)
 	}
+	defer info.mu.Unlock()
 
 	// Send interrupt first (Ctrl+C) unless force is set
 	if !req.Force {
-		if err := s.tmux.SendInterrupt(ctx, info.paneID); err != nil {
-			s.logger.Warn().Err(err).Str("agent_id", req.AgentId).Msg("failed to send interrupt")
+		if err := s.backend.SendInterrupt(ctx, info.paneID); err != nil {
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", req.AgentId).Msg("failed to send interrupt")
 		}
 
 		// Wait for grace period if specified
 		if req.GracePeriod != nil && req.GracePeriod.AsDuration() > 0 {
+			grace := s.clock.NewTimer(req.GracePeriod.AsDuration())
+			defer grace.Stop()
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
-			case <-time.After(req.GracePeriod.AsDuration()):
+			case <-grace.C():
 			}
 		}
 	}
 
-	// Record state change in transcript before killing
+	// A forced kill takes the agent's process down first, so a command
+	// that detached from the pane does not outlive it.
+	if req.Force {
+		s.killAgentProcessLocked(info)
+	}
+
+	// Kill the pane. An agent whose pane survives stays registered, marked
+	// failed, so its process is never left running untracked.
+	if err := s.backend.KillPane(ctx, info.paneID, req.Force); err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", req.AgentId).Bool("force", req.Force).Msg("agent pane survived kill")
+		s.addTranscriptEntryLocked(info, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_ERROR, "kill failed: "+err.Error(), map[string]string{
+			"event": "kill",
+			"force": fmt.Sprintf("%v", req.Force),
+		})
+		prevState := info.state
+		info.state = swarmdv1.AgentState_AGENT_STATE_FAILED
+		info.notifyStateLocked()
+		go s.publishAgentStateChanged(req.AgentId, info.workspaceID, prevState, info.state, "kill failed: "+err.Error())
+		return nil, status.Errorf(codes.FailedPrecondition, "agent %q pane survived kill: %v", req.AgentId, err)
+	}
+
 	s.addTranscriptEntryLocked(info, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE, "stopped", map[string]string{
 		"event":    "kill",
 		"force":    fmt.Sprintf("%v", req.Force),
 		"previous": info.state.String(),
 	})
 
-	// Kill the pane
-	if err := s.tmux.KillPane(ctx, info.paneID); err != nil {
-		s.logger.Warn().Err(err).Str("agent_id", req.AgentId).Msg("failed to kill pane")
-	}
-
 	prevState := info.state
 	info.state = swarmdv1.AgentState_AGENT_STATE_STOPPED
+	info.removed = true
+	info.notifyStateLocked()
 	workspaceID := info.workspaceID
+	s.mu.Lock()
 	delete(s.agents, req.AgentId)
+	s.notifyAgentsLocked()
+	s.mu.Unlock()
+	s.stopCapture(req.AgentId)
 
 	// Unregister agent from resource monitor
 	if s.resourceMonitor != nil {
@@ -335,6 +556,7 @@ func (s *Server) KillAgent(ctx context.Context, req *swarmdv1.KillAgentRequest)
 	}
 
 	s.logger.Info().
+		Ctx(ctx).
 		Str("agent_id", req.AgentId).
 		Bool("force", req.Force).
 		Msg("agent killed")
@@ -361,66 +583,80 @@ func (s *Server) SendInput(ctx context.Context, req *swarmdv1.SendInputRequest)
 		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
 	}
 
-	s.mu.RLock()
-	info, exists := s.agents[req.AgentId]
-	s.mu.RUnlock()
-
+	info, exists := s.lookupAgent(req.AgentId)
 	if !exists {
 		return nil, status.Errorf(codes.NotFound, "agent %q not found", req.AgentId)
 	}
 
+	// Stop recording output before the pane can echo a secret.
+	if req.Sensitive {
+		info.mu.Lock()
+		s.beginSensitiveInputLocked(info, req.Text)
+		info.mu.Unlock()
+	}
+
 	// Send special keys first
 	for _, key := range req.Keys {
-		keyCmd := fmt.Sprintf("tmux send-keys -t %s %s", info.paneID, key)
-		cmd := exec.CommandContext(ctx, "sh", "-c", keyCmd)
-		if err := cmd.Run(); err != nil {
+		if err := s.backend.SendKeys(ctx, info.paneID, key, false, false); err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to send key %q: %v", key, err)
 		}
 	}
 
 	// Send text if provided
 	if req.Text != "" {
-		if err := s.tmux.SendKeys(ctx, info.paneID, req.Text, true, req.SendEnter); err != nil {
+		if err := s.backend.SendKeys(ctx, info.paneID, req.Text, true, req.SendEnter); err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to send text: %v", err)
 		}
 	}
+	s.pokeCapture(req.AgentId)
 
 	// Update last active time and record transcript entry
-	s.mu.Lock()
-	if agent, ok := s.agents[req.AgentId]; ok {
-		agent.lastActive = time.Now()
+	info.mu.Lock()
+	if !info.removed {
+		info.lastActive = s.clock.Now()
 
 		// Record user input in transcript
 		inputContent := req.Text
+		var metadata map[string]string
+		if req.Sensitive {
+			inputContent = sensitiveInputMarker
+			metadata = map[string]string{"sensitive": "true"}
+		}
 		if len(req.Keys) > 0 {
-			inputContent = fmt.Sprintf("[keys: %v] %s", req.Keys, req.Text)
+			inputContent = fmt.Sprintf("[keys: %v] %s", req.Keys, inputContent)
 		}
 		if inputContent != "" {
-			s.addTranscriptEntryLocked(agent, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_USER_INPUT, inputContent, nil)
+			s.addTranscriptEntryLocked(info, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_USER_INPUT, inputContent, metadata)
 		}
 	}
-	s.mu.Unlock()
+	info.mu.Unlock()
 
 	return &swarmdv1.SendInputResponse{Success: true}, nil
 }
 
-// ListAgents returns all agents managed by this daemon.
+// ListAgents returns all agents managed by this daemon. Each agent is
+// snapshotted under its own lock, so listing never stalls per-agent work.
 func (s *Server) ListAgents(ctx context.Context, req *swarmdv1.ListAgentsRequest) (*swarmdv1.ListAgentsResponse, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	var agents []*swarmdv1.Agent
-	for _, info := range s.agents {
+	for _, info := range s.agentHandles() {
 		// Apply workspace filter
 		if req.WorkspaceId != "" && info.workspaceID != req.WorkspaceId {
 			continue
 		}
 
+		info.mu.Lock()
+		if info.removed {
+			info.mu.Unlock()
+			continue
+		}
+		agent := s.agentToProto(info)
+		info.mu.Unlock()
+
 		// Apply state filter
 		if len(req.States) > 0 {
 			matched := false
 			for _, state := range req.States {
-				if info.state == state {
+				if agent.State == state {
 					matched = true
 					break
 				}
@@ -430,7 +666,7 @@ func (s *Server) ListAgents(ctx context.Context, req *swarmdv1.ListAgentsRequest
 			}
 		}
 
-		agents = append(agents, s.agentToProto(info))
+		agents = append(agents, agent)
 	}
 
 	return &swarmdv1.ListAgentsResponse{Agents: agents}, nil
@@ -442,15 +678,21 @@ func (s *Server) GetAgent(ctx context.Context, req *swarmdv1.GetAgentRequest) (*
 		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
 	}
 
-	s.mu.RLock()
-	info, exists := s.agents[req.AgentId]
-	s.mu.RUnlock()
-
+	info, exists := s.lockAgent(req.AgentId)
 	if !exists {
 		return nil, status.Errorf(codes.NotFound, "agent %q not found", req.AgentId)
 	}
+	if wait := req.WaitForStateOtherThan; wait != swarmdv1.AgentState_AGENT_STATE_UNSPECIFIED && info.state == wait {
+		info.mu.Unlock()
+		var err error
+		if info, err = s.waitForStateChange(ctx, req.AgentId, wait, longPollWait(req.MaxWait)); err != nil {
+			return nil, err
+		}
+	}
+	agent := s.agentToProto(info)
+	info.mu.Unlock()
 
-	return &swarmdv1.GetAgentResponse{Agent: s.agentToProto(info)}, nil
+	return &swarmdv1.GetAgentResponse{Agent: agent}, nil
 }
 
 // =============================================================================
@@ -460,17 +702,18 @@ func (s *Server) GetAgent(ctx context.Context, req *swarmdv1.GetAgentRequest) (*
 // Default polling interval for StreamPaneUpdates.
 const defaultPollInterval = 500 * time.Millisecond
 
-// StreamPaneUpdates streams pane content changes in real-time.
+// maxTranscriptOutputBytes caps the pane tail stored per OUTPUT transcript entry.
+const maxTranscriptOutputBytes = 4096
+
+// StreamPaneUpdates streams pane content changes in real-time. Streams
+// subscribe to the agent's shared capture loop rather than capturing the
+// pane themselves.
 func (s *Server) StreamPaneUpdates(req *swarmdv1.StreamPaneUpdatesRequest, stream swarmdv1.SwarmdService_StreamPaneUpdatesServer) error {
 	if req.AgentId == "" {
 		return status.Error(codes.InvalidArgument, "agent_id is required")
 	}
 
-	s.mu.RLock()
-	info, exists := s.agents[req.AgentId]
-	s.mu.RUnlock()
-
-	if !exists {
+	if _, exists := s.lookupAgent(req.AgentId); !exists {
 		return status.Errorf(codes.NotFound, "agent %q not found", req.AgentId)
 	}
 
@@ -482,118 +725,106 @@ func (s *Server) StreamPaneUpdates(req *swarmdv1.StreamPaneUpdatesRequest, strea
 
 	// Track last known hash for change detection
 	lastHash := req.LastKnownHash
+	var lastSent time.Duration
+	sent := false
 
 	ctx := stream.Context()
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
+	sub := s.subscribeCapture(req.AgentId, pollInterval, req.GetMaxInterval().AsDuration())
+	defer s.unsubscribeCapture(sub)
 
 	s.logger.Debug().
+		Ctx(ctx).
 		Str("agent_id", req.AgentId).
 		Dur("poll_interval", pollInterval).
 		Msg("starting pane update stream")
 
 	for {
+		var snap paneSnapshot
+		var ok bool
 		select {
 		case <-ctx.Done():
 			s.logger.Debug().
+				Ctx(ctx).
 				Str("agent_id", req.AgentId).
 				Msg("pane update stream ended (context done)")
 			return ctx.Err()
-		case <-ticker.C:
-			// Check if agent still exists
-			s.mu.RLock()
-			info, exists = s.agents[req.AgentId]
-			s.mu.RUnlock()
-
-			if !exists {
-				return status.Errorf(codes.NotFound, "agent %q no longer exists", req.AgentId)
-			}
+		case snap, ok = <-sub.updates:
+		}
+		if !ok {
+			return status.Errorf(codes.NotFound, "agent %q no longer exists", req.AgentId)
+		}
 
-			// Capture pane content
-			content, err := s.tmux.CapturePane(ctx, info.paneID, false)
-			if err != nil {
-				s.logger.Warn().Err(err).Str("agent_id", req.AgentId).Msg("failed to capture pane")
-				continue
+		// Honor this stream's interval when the loop runs faster for
+		// another subscriber; whatever arrives meanwhile supersedes snap.
+		if wait := pollInterval - (snap.mono - lastSent); sent && wait > 0 {
+			timer := s.clock.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C():
 			}
-
-			currentHash := tmux.HashSnapshot(content)
-			changed := currentHash != lastHash
-
-			// Only send if changed, or if this is the first response
-			if changed || lastHash == "" {
-				resp := &swarmdv1.StreamPaneUpdatesResponse{
-					AgentId:     req.AgentId,
-					ContentHash: currentHash,
-					Changed:     changed,
-					Timestamp:   timestamppb.Now(),
+			select {
+			case newer, open := <-sub.updates:
+				if !open {
+					return status.Errorf(codes.NotFound, "agent %q no longer exists", req.AgentId)
 				}
+				snap = newer
+			default:
+			}
+		}
 
-				// Include content if requested
-				if req.IncludeContent {
-					resp.Content = content
-				}
+		// Only send if changed since this stream's last update
+		if snap.hash == lastHash {
+			continue
+		}
 
-				// Detect state from content
-				resp.DetectedState = s.detectAgentState(content, info.adapter)
-
-				// Update agent's content hash, last active time, and record output
-				var prevState swarmdv1.AgentState
-				var stateChanged bool
-				var workspaceID string
-
-				s.mu.Lock()
-				if agent, ok := s.agents[req.AgentId]; ok {
-					agent.contentHash = currentHash
-					agent.lastActive = time.Now()
-					workspaceID = agent.workspaceID
-
-					// Record content change in transcript (truncate if very long)
-					outputContent := content
-					if len(outputContent) > 4096 {
-						outputContent = outputContent[len(outputContent)-4096:]
-					}
-					s.addTranscriptEntryLocked(agent, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, outputContent, map[string]string{
-						"content_hash": currentHash,
-					})
-
-					if resp.DetectedState != swarmdv1.AgentState_AGENT_STATE_UNSPECIFIED {
-						// Record state change if different
-						if agent.state != resp.DetectedState {
-							prevState = agent.state
-							stateChanged = true
-							s.addTranscriptEntryLocked(agent, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE, resp.DetectedState.String(), map[string]string{
-								"previous": agent.state.String(),
-							})
-						}
-						agent.state = resp.DetectedState
-					}
-				}
-				s.mu.Unlock()
+		if err := stream.Send(paneUpdate(req.AgentId, snap, req.IncludeContent)); err != nil {
+			s.logger.Debug().Ctx(ctx).Err(err).Str("agent_id", req.AgentId).Msg("failed to send pane update")
+			return err
+		}
 
-				// Publish events outside lock
-				if stateChanged {
-					go s.publishAgentStateChanged(req.AgentId, workspaceID, prevState, resp.DetectedState, "state detected from pane content")
-				}
-				if changed {
-					go s.publishPaneContentChanged(req.AgentId, workspaceID, currentHash, int32(len(splitLines(content))))
-				}
+		lastHash = snap.hash
+		lastSent = snap.mono
+		sent = true
+	}
+}
 
-				if err := stream.Send(resp); err != nil {
-					s.logger.Debug().Err(err).Str("agent_id", req.AgentId).Msg("failed to send pane update")
-					return err
-				}
+// paneUpdate is the pane update stream response for snap, which includes
+// the content if includeContent is set.
+func paneUpdate(agentID string, snap paneSnapshot, includeContent bool) *swarmdv1.StreamPaneUpdatesResponse {
+	resp := &swarmdv1.StreamPaneUpdatesResponse{
+		AgentId:       agentID,
+		ContentHash:   snap.hash,
+		Changed:       true,
+		DetectedState: snap.state,
+		Timestamp:     timestamppb.New(snap.capturedAt),
+		PollInterval:  durationpb.New(snap.interval),
+		StateDetector: snap.detector,
+	}
+	if includeContent {
+		resp.Content = snap.content
+	}
+	return resp
+}
 
-				lastHash = currentHash
-			}
-		}
+// detectAgentState analyzes pane content to determine agent state, and
+// returns the name of the detector that read it. Adapters with a
+// StateDetector are read by it; the rest fall back to the simplified
+// patterns below, in which prompt and busy regexes declared by the
+// adapter take the place of the built-in prompt and activity patterns.
+func (s *Server) detectAgentState(content, adapter string) (swarmdv1.AgentState, string) {
+	if d, name := s.stateDetector(adapter); d != nil {
+		return d.detect(content), name
 	}
+	return detectDefaultState(content, adapter), DefaultStateDetector
 }
 
-// detectAgentState analyzes pane content to determine agent state.
-// This is a simplified version - full adapters have more sophisticated detection.
-func (s *Server) detectAgentState(content, adapter string) swarmdv1.AgentState {
+// detectDefaultState is the fallback state detection.
+func detectDefaultState(content, adapter string) swarmdv1.AgentState {
 	// Look for common patterns indicating different states
 	// These patterns are simplified - real adapters have more detailed detection
+	caps := adapters.CapabilitiesFor(models.AgentType(adapter))
 
 	// Check for approval/confirmation prompts
 	if containsAny(content,
@@ -608,7 +839,11 @@ func (s *Server) detectAgentState(content, adapter string) swarmdv1.AgentState {
 	}
 
 	// Check for idle prompts (command line ready)
-	if containsAny(content,
+	if caps.PromptRegex != nil {
+		if caps.PromptRegex.MatchString(content) {
+			return swarmdv1.AgentState_AGENT_STATE_IDLE
+		}
+	} else if containsAny(content,
 		"$",
 		"❯",
 		"→",
@@ -626,6 +861,9 @@ func (s *Server) detectAgentState(content, adapter string) swarmdv1.AgentState {
 	}
 
 	// Check for running indicators
+	if caps.BusyRegex != nil && caps.BusyRegex.MatchString(content) {
+		return swarmdv1.AgentState_AGENT_STATE_RUNNING
+	}
 	if containsAny(content,
 		"Thinking...",
 		"Working...",
@@ -686,35 +924,61 @@ func (s *Server) CapturePane(ctx context.Context, req *swarmdv1.CapturePaneReque
 		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
 	}
 
-	s.mu.RLock()
-	info, exists := s.agents[req.AgentId]
-	s.mu.RUnlock()
-
+	info, exists := s.lookupAgent(req.AgentId)
 	if !exists {
 		return nil, status.Errorf(codes.NotFound, "agent %q not found", req.AgentId)
 	}
 
-	// Capture with or without history based on lines parameter
-	includeHistory := req.Lines < 0
-	content, err := s.tmux.CapturePane(ctx, info.paneID, includeHistory)
+	if req.Lines < -1 {
+		return nil, status.Errorf(codes.InvalidArgument, "lines must be -1, 0, or positive, got %d", req.Lines)
+	}
+	var since *regexp.Regexp
+	if req.SincePattern != "" {
+		re, err := regexp.Compile(req.SincePattern)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid since_pattern: %v", err)
+		}
+		since = re
+	}
+
+	content, truncated, err := s.capturePaneLines(ctx, info.paneID, req.Lines)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to capture pane: %v", err)
+		return nil, err
 	}
 
+	// The hash tracks the pane itself, not the trimmed view of it.
 	hash := tmux.HashSnapshot(content)
+	if req.WaitForChangeFrom != "" && hash == req.WaitForChangeFrom {
+		content, truncated, err = s.waitForPaneChange(ctx, info, req.Lines, hash, longPollWait(req.MaxWait))
+		if err != nil {
+			return nil, err
+		}
+		hash = tmux.HashSnapshot(content)
+	}
+
+	totalLines := len(splitLines(content))
+	matched := false
+	if since != nil {
+		content, matched = tmux.ContentSince(content, since)
+		truncated = truncated || matched
+	}
 
 	// Update content hash
-	s.mu.Lock()
-	if agent, ok := s.agents[req.AgentId]; ok {
-		agent.contentHash = hash
-		agent.lastActive = time.Now()
+	info.mu.Lock()
+	if !info.removed {
+		info.contentHash = hash
+		info.lastActive = s.clock.Now()
 	}
-	s.mu.Unlock()
+	info.mu.Unlock()
 
 	return &swarmdv1.CapturePaneResponse{
-		Content:     content,
-		ContentHash: hash,
-		CapturedAt:  timestamppb.Now(),
+		Content:        content,
+		ContentHash:    hash,
+		CapturedAt:     timestamppb.Now(),
+		LineCount:      int32(len(splitLines(content))),
+		TotalLines:     int32(totalLines),
+		Truncated:      truncated,
+		PatternMatched: matched,
 	}, nil
 }
 
@@ -728,7 +992,7 @@ func (s *Server) GetStatus(ctx context.Context, req *swarmdv1.GetStatusRequest)
 	agentCount := len(s.agents)
 	s.mu.RUnlock()
 
-	uptime := time.Since(s.startedAt)
+	uptime := clock.Since(s.clock, s.startedMono)
 
 	return &swarmdv1.GetStatusResponse{
 		Status: &swarmdv1.DaemonStatus{
@@ -755,8 +1019,47 @@ func (s *Server) Ping(ctx context.Context, req *swarmdv1.PingRequest) (*swarmdv1
 // Helpers
 // =============================================================================
 
+// lookupAgent returns the registered agent with id. Fields guarded by
+// info.mu must be read under it.
+func (s *Server) lookupAgent(id string) (*agentInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	info, exists := s.agents[id]
+	return info, exists
+}
+
+// lockAgent returns the registered agent with id, locked. It reports false
+// if there is none or it was killed while the lock was awaited.
+func (s *Server) lockAgent(id string) (*agentInfo, bool) {
+	info, exists := s.lookupAgent(id)
+	if !exists {
+		return nil, false
+	}
+	info.mu.Lock()
+	if info.removed {
+		info.mu.Unlock()
+		return nil, false
+	}
+	return info, true
+}
+
+// agentHandles returns the registered agents. Only the registry is locked,
+// and only while the slice is built.
+func (s *Server) agentHandles() []*agentInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	handles := make([]*agentInfo, 0, len(s.agents))
+	for _, info := range s.agents {
+		handles = append(handles, info)
+	}
+	return handles
+}
+
+// agentToProto snapshots info. The caller must hold info.mu.
 func (s *Server) agentToProto(info *agentInfo) *swarmdv1.Agent {
-	return &swarmdv1.Agent{
+	agent := &swarmdv1.Agent{
 		Id:             info.id,
 		WorkspaceId:    info.workspaceID,
 		State:          info.state,
@@ -768,37 +1071,46 @@ func (s *Server) agentToProto(info *agentInfo) *swarmdv1.Agent {
 		LastActivityAt: timestamppb.New(info.lastActive),
 		ContentHash:    info.contentHash,
 	}
+	if s.costs != nil {
+		if cost, ok := s.costs.Cost(info.id); ok {
+			agent.CostDate = cost.Date
+			agent.ActualCostCents = cost.ActualCents
+			agent.EstimatedCostCents = cost.Estimated.Cents
+			agent.EstimatedWorkingSeconds = cost.Estimated.WorkingSeconds
+		}
+	}
+	return agent
 }
 
 func (s *Server) getResourceUsage() *swarmdv1.ResourceUsage {
-	var rusage syscall.Rusage
-	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &rusage); err != nil {
+	memory, err := peakMemoryBytes()
+	if err != nil {
 		return &swarmdv1.ResourceUsage{}
 	}
 
 	return &swarmdv1.ResourceUsage{
-		MemoryBytes: rusage.Maxrss * 1024, // maxrss is in KB on Linux
+		MemoryBytes: memory,
 	}
 }
 
 func (s *Server) getHealthStatus() *swarmdv1.HealthStatus {
+	name := s.backend.Name()
 	checks := []*swarmdv1.HealthCheck{
 		{
-			Name:      "tmux",
+			Name:      name,
 			Health:    swarmdv1.Health_HEALTH_HEALTHY,
-			Message:   "tmux available",
+			Message:   name + " available",
 			LastCheck: timestamppb.Now(),
 		},
 	}
 
-	// Check if tmux is available
+	// Check if the execution backend is available
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := s.tmux.ListSessions(ctx)
-	if err != nil {
+	if err := s.backend.Check(ctx); err != nil {
 		checks[0].Health = swarmdv1.Health_HEALTH_UNHEALTHY
-		checks[0].Message = fmt.Sprintf("tmux error: %v", err)
+		checks[0].Message = fmt.Sprintf("%s error: %v", name, err)
 	}
 
 	// Determine overall health
@@ -813,10 +1125,14 @@ func (s *Server) getHealthStatus() *swarmdv1.HealthStatus {
 		}
 	}
 
-	return &swarmdv1.HealthStatus{
+	health := &swarmdv1.HealthStatus{
 		Health: overallHealth,
 		Checks: checks,
 	}
+	s.healthMu.Lock()
+	s.lastHealth = health
+	s.healthMu.Unlock()
+	return health
 }
 
 // =============================================================================
@@ -824,52 +1140,112 @@ func (s *Server) getHealthStatus() *swarmdv1.HealthStatus {
 // =============================================================================
 
 // addTranscriptEntryLocked adds a transcript entry to an agent's transcript.
-// The caller must hold the write lock.
+// Content and metadata values are redacted before they are stored.
+// The caller must hold info.mu.
 func (s *Server) addTranscriptEntryLocked(info *agentInfo, entryType swarmdv1.TranscriptEntryType, content string, metadata map[string]string) {
+	s.addTranscriptEntryAtLocked(info, s.clock.Now(), entryType, content, metadata)
+}
+
+// addTranscriptEntryAtLocked is addTranscriptEntryLocked with an explicit
+// timestamp. The caller must hold info.mu.
+func (s *Server) addTranscriptEntryAtLocked(info *agentInfo, at time.Time, entryType swarmdv1.TranscriptEntryType, content string, metadata map[string]string) {
 	entry := transcriptEntry{
 		id:        info.transcriptNext,
-		timestamp: time.Now(),
+		timestamp: at,
 		entryType: entryType,
-		content:   content,
-		metadata:  metadata,
+		content:   s.currentRedactor().Redact(content),
+		metadata:  s.currentRedactor().RedactMap(metadata),
 	}
 	info.transcript = append(info.transcript, entry)
 	info.transcriptNext++
+	info.transcriptBytes += transcriptEntrySize(&entry)
+	s.storeTranscriptEntry(info.id, &entry)
+	s.evictTranscriptLocked(info)
 }
 
 // addTranscriptEntry adds a transcript entry (acquires lock).
 func (s *Server) addTranscriptEntry(agentID string, entryType swarmdv1.TranscriptEntryType, content string, metadata map[string]string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	info, exists := s.agents[agentID]
+	info, exists := s.lockAgent(agentID)
 	if !exists {
 		return
 	}
+	defer info.mu.Unlock()
 	s.addTranscriptEntryLocked(info, entryType, content, metadata)
 }
 
-// GetTranscript retrieves the full transcript for an agent.
+// GetTranscript retrieves the full transcript for an agent. With a
+// transcript store, entries recorded before the agent's current spawn or
+// evicted by the transcript limit, and the transcripts of killed agents,
+// are read from it. Without one, a response missing evicted entries is
+// marked truncated.
 func (s *Server) GetTranscript(ctx context.Context, req *swarmdv1.GetTranscriptRequest) (*swarmdv1.GetTranscriptResponse, error) {
 	if req.AgentId == "" {
 		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
 	}
 
-	s.mu.RLock()
-	info, exists := s.agents[req.AgentId]
-	if !exists {
-		s.mu.RUnlock()
+	var cursor int64
+	if req.Cursor != "" {
+		var err error
+		cursor, err = parseInt64(req.Cursor)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid cursor: %v", err)
+		}
+	}
+
+	// Apply limit
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = 1000 // Default limit
+	}
+
+	var since *time.Time
+	if req.StartTime != nil {
+		t := req.StartTime.AsTime()
+		since = &t
+	}
+
+	var entries []transcriptEntry
+	var floor int64
+	var truncated bool
+	info, exists := s.lockAgent(req.AgentId)
+	if exists {
+		// Copy transcript entries while holding lock
+		entries = make([]transcriptEntry, len(info.transcript))
+		copy(entries, info.transcript)
+		floor = info.transcriptFloor
+		truncated = s.transcripts == nil && transcriptTruncatedLocked(info, cursor, since)
+		info.mu.Unlock()
+	} else if s.transcripts == nil {
 		return nil, status.Errorf(codes.NotFound, "agent %q not found", req.AgentId)
 	}
 
-	// Copy transcript entries while holding lock
-	entries := make([]transcriptEntry, len(info.transcript))
-	copy(entries, info.transcript)
-	s.mu.RUnlock()
+	if s.transcripts != nil && (!exists || cursor < floor) {
+		opts := db.TranscriptListOptions{Cursor: cursor, Limit: limit + 1, Since: since}
+		if exists {
+			opts.Before = floor
+		}
+		if req.EndTime != nil {
+			until := req.EndTime.AsTime()
+			opts.Until = &until
+		}
+		stored, err := s.storedTranscript(ctx, req.AgentId, opts)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to read stored transcript: %v", err)
+		}
+		if !exists && len(stored) == 0 {
+			if found, err := s.hasStoredTranscript(ctx, req.AgentId); err != nil || !found {
+				return nil, status.Errorf(codes.NotFound, "agent %q not found", req.AgentId)
+			}
+		}
+		entries = append(stored, entries...)
+	}
 
-	// Apply time filters
+	// Apply cursor and time filters
 	var filtered []transcriptEntry
 	for _, e := range entries {
+		if e.lastID() < cursor {
+			continue
+		}
 		if req.StartTime != nil && e.timestamp.Before(req.StartTime.AsTime()) {
 			continue
 		}
@@ -879,11 +1255,6 @@ func (s *Server) GetTranscript(ctx context.Context, req *swarmdv1.GetTranscriptR
 		filtered = append(filtered, e)
 	}
 
-	// Apply limit
-	limit := int(req.Limit)
-	if limit <= 0 {
-		limit = 1000 // Default limit
-	}
 	hasMore := len(filtered) > limit
 	if hasMore {
 		filtered = filtered[:limit]
@@ -897,18 +1268,27 @@ func (s *Server) GetTranscript(ctx context.Context, req *swarmdv1.GetTranscriptR
 
 	var nextCursor string
 	if hasMore && len(filtered) > 0 {
-		nextCursor = fmt.Sprintf("%d", filtered[len(filtered)-1].id+1)
+		nextCursor = fmt.Sprintf("%d", filtered[len(filtered)-1].lastID()+1)
+	}
+
+	var earliestCursor string
+	if truncated {
+		earliestCursor = fmt.Sprintf("%d", floor)
 	}
 
 	return &swarmdv1.GetTranscriptResponse{
-		AgentId:    req.AgentId,
-		Entries:    protoEntries,
-		HasMore:    hasMore,
-		NextCursor: nextCursor,
+		AgentId:        req.AgentId,
+		Entries:        protoEntries,
+		HasMore:        hasMore,
+		NextCursor:     nextCursor,
+		Truncated:      truncated,
+		EarliestCursor: earliestCursor,
 	}, nil
 }
 
-// StreamTranscript streams transcript updates in real-time.
+// StreamTranscript streams transcript updates in real-time. A cursor at
+// an entry evicted by the transcript limit resumes at the oldest entry
+// left, in a response marked truncated.
 func (s *Server) StreamTranscript(req *swarmdv1.StreamTranscriptRequest, stream swarmdv1.SwarmdService_StreamTranscriptServer) error {
 	if req.AgentId == "" {
 		return status.Error(codes.InvalidArgument, "agent_id is required")
@@ -925,37 +1305,63 @@ func (s *Server) StreamTranscript(req *swarmdv1.StreamTranscriptRequest, stream
 	}
 
 	ctx := stream.Context()
-	ticker := time.NewTicker(100 * time.Millisecond) // Poll for new entries
-	defer ticker.Stop()
+	defer s.trackTranscriptStream(req.AgentId)()
 
 	s.logger.Debug().
+		Ctx(ctx).
 		Str("agent_id", req.AgentId).
 		Int64("cursor", cursor).
 		Msg("starting transcript stream")
 
+	if s.transcripts != nil {
+		var err error
+		if cursor, err = s.replayStoredTranscript(ctx, req.AgentId, cursor, stream); err != nil {
+			return err
+		}
+	}
+
+	ticker := s.clock.NewTicker(100 * time.Millisecond) // Poll for new entries
+	defer ticker.Stop()
+
+	var replayedFloor int64
 	for {
 		select {
 		case <-ctx.Done():
 			s.logger.Debug().
+				Ctx(ctx).
 				Str("agent_id", req.AgentId).
 				Msg("transcript stream ended (context done)")
 			return ctx.Err()
-		case <-ticker.C:
-			s.mu.RLock()
-			info, exists := s.agents[req.AgentId]
+		case <-ticker.C():
+			info, exists := s.lockAgent(req.AgentId)
 			if !exists {
-				s.mu.RUnlock()
 				return status.Errorf(codes.NotFound, "agent %q no longer exists", req.AgentId)
 			}
 
-			// Find new entries since cursor
+			// Entries evicted since the stored ones were replayed are
+			// read from the store too, once; any the store lacks are
+			// reported truncated below.
+			if s.transcripts != nil && cursor < info.transcriptFloor && info.transcriptFloor > replayedFloor {
+				replayedFloor = info.transcriptFloor
+				info.mu.Unlock()
+				var err error
+				if cursor, err = s.replayStoredTranscript(ctx, req.AgentId, cursor, stream); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Find new entries since cursor. A cursor inside a compacted
+			// range resumes at the summary that replaced it, and one at an
+			// evicted entry at the oldest entry left.
+			truncated := transcriptTruncatedLocked(info, cursor, nil)
 			var newEntries []transcriptEntry
 			for _, e := range info.transcript {
-				if e.id >= cursor {
+				if e.lastID() >= cursor {
 					newEntries = append(newEntries, e)
 				}
 			}
-			s.mu.RUnlock()
+			info.mu.Unlock()
 
 			if len(newEntries) > 0 {
 				// Convert to proto
@@ -965,15 +1371,16 @@ func (s *Server) StreamTranscript(req *swarmdv1.StreamTranscriptRequest, stream
 				}
 
 				// Update cursor for next iteration
-				cursor = newEntries[len(newEntries)-1].id + 1
+				cursor = newEntries[len(newEntries)-1].lastID() + 1
 
 				resp := &swarmdv1.StreamTranscriptResponse{
-					Entries: protoEntries,
-					Cursor:  fmt.Sprintf("%d", cursor),
+					Entries:   protoEntries,
+					Cursor:    fmt.Sprintf("%d", cursor),
+					Truncated: truncated,
 				}
 
 				if err := stream.Send(resp); err != nil {
-					s.logger.Debug().Err(err).Str("agent_id", req.AgentId).Msg("failed to send transcript update")
+					s.logger.Debug().Ctx(ctx).Err(err).Str("agent_id", req.AgentId).Msg("failed to send transcript update")
 					return err
 				}
 			}
@@ -981,9 +1388,53 @@ func (s *Server) StreamTranscript(req *swarmdv1.StreamTranscriptRequest, stream
 	}
 }
 
+// replayStoredTranscript sends the stored entries from cursor up to those
+// the agent still holds in memory, or all of them for an agent no longer
+// running, and returns the cursor to stream on from.
+func (s *Server) replayStoredTranscript(ctx context.Context, agentID string, cursor int64, stream swarmdv1.SwarmdService_StreamTranscriptServer) (int64, error) {
+	opts := db.TranscriptListOptions{Limit: storedTranscriptPage}
+	if info, exists := s.lockAgent(agentID); exists {
+		floor := info.transcriptFloor
+		info.mu.Unlock()
+		if cursor >= floor {
+			return cursor, nil
+		}
+		opts.Before = floor
+	}
+
+	for {
+		opts.Cursor = cursor
+		entries, err := s.storedTranscript(ctx, agentID, opts)
+		if err != nil {
+			return cursor, status.Errorf(codes.Internal, "failed to read stored transcript: %v", err)
+		}
+		if len(entries) == 0 {
+			return cursor, nil
+		}
+
+		protoEntries := make([]*swarmdv1.TranscriptEntry, len(entries))
+		for i, e := range entries {
+			protoEntries[i] = s.transcriptEntryToProto(&e)
+		}
+		cursor = entries[len(entries)-1].lastID() + 1
+		resp := &swarmdv1.StreamTranscriptResponse{
+			Entries: protoEntries,
+			Cursor:  fmt.Sprintf("%d", cursor),
+		}
+		if err := stream.Send(resp); err != nil {
+			s.logger.Debug().Ctx(ctx).Err(err).Str("agent_id", agentID).Msg("failed to send stored transcript")
+			return cursor, err
+		}
+		if len(entries) < storedTranscriptPage {
+			return cursor, nil
+		}
+	}
+}
+
 // transcriptEntryToProto converts a transcriptEntry to proto format.
 func (s *Server) transcriptEntryToProto(e *transcriptEntry) *swarmdv1.TranscriptEntry {
 	return &swarmdv1.TranscriptEntry{
+		Id:        e.id,
 		Timestamp: timestamppb.New(e.timestamp),
 		Type:      e.entryType,
 		Content:   e.content,
@@ -1097,6 +1548,7 @@ func (s *Server) StreamEvents(req *swarmdv1.StreamEventsRequest, stream swarmdv1
 		select {
 		case <-ctx.Done():
 			s.logger.Debug().
+				Ctx(ctx).
 				Str("subscriber_id", sub.id).
 				Msg("event stream ended (context done)")
 			return ctx.Err()
@@ -1105,7 +1557,7 @@ func (s *Server) StreamEvents(req *swarmdv1.StreamEventsRequest, stream swarmdv1
 				return nil // Channel closed
 			}
 			if err := stream.Send(&swarmdv1.StreamEventsResponse{Event: event}); err != nil {
-				s.logger.Debug().Err(err).Str("subscriber_id", sub.id).Msg("failed to send event")
+				s.logger.Debug().Ctx(ctx).Err(err).Str("subscriber_id", sub.id).Msg("failed to send event")
 				return err
 			}
 		}
@@ -1186,7 +1638,7 @@ func (s *Server) publishAgentStateChanged(agentID, workspaceID string, prevState
 			AgentStateChanged: &swarmdv1.AgentStateChangedEvent{
 				PreviousState: prevState,
 				NewState:      newState,
-				Reason:        reason,
+				Reason:        s.currentRedactor().Redact(reason),
 			},
 		},
 	})
@@ -1202,7 +1654,7 @@ func (s *Server) publishError(agentID, workspaceID, code, message string, recove
 		Payload: &swarmdv1.Event_Error{
 			Error: &swarmdv1.ErrorEvent{
 				Code:        code,
-				Message:     message,
+				Message:     s.currentRedactor().Redact(message),
 				Recoverable: recoverable,
 			},
 		},
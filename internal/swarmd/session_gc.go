@@ -0,0 +1,152 @@
+package swarmd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/sessiongc"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/rs/zerolog"
+)
+
+// SessionCollector periodically looks for tmux sessions that follow the
+// swarm naming convention but no workspace or agent refers to, and reports
+// them, or kills them when configured to.
+type SessionCollector struct {
+	collector *sessiongc.Collector
+	clock     clock.Clock
+	logger    zerolog.Logger
+
+	// mu guards the settings Reconfigure replaces.
+	mu           sync.Mutex
+	settings     config.SessionGCConfig
+	prefix       string
+	reconfigured chan struct{}
+}
+
+// SessionCollectorOption configures a SessionCollector.
+type SessionCollectorOption func(*SessionCollector)
+
+// WithSessionGCPrefix sets the session naming convention prefix.
+func WithSessionGCPrefix(prefix string) SessionCollectorOption {
+	return func(g *SessionCollector) {
+		g.prefix = prefix
+	}
+}
+
+// WithSessionGCClock sets the time source idle times are measured against.
+func WithSessionGCClock(c clock.Clock) SessionCollectorOption {
+	return func(g *SessionCollector) {
+		g.clock = c
+	}
+}
+
+// NewSessionCollector creates a collector checking the sessions of client
+// against database with settings. A zero settings.Interval disables the
+// check.
+func NewSessionCollector(database *db.DB, client *tmux.Client, settings config.SessionGCConfig, logger zerolog.Logger, opts ...SessionCollectorOption) *SessionCollector {
+	g := &SessionCollector{
+		settings:     settings,
+		logger:       logger,
+		reconfigured: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.clock = clock.OrReal(g.clock)
+	g.collector = sessiongc.New(database, client, logger, sessiongc.WithClock(g.clock))
+	return g
+}
+
+// Reconfigure applies the session GC settings of cfg. A running loop
+// checks again at once and then every new interval.
+func (g *SessionCollector) Reconfigure(cfg *config.Config) error {
+	g.mu.Lock()
+	g.settings, g.prefix = cfg.Daemon.SessionGC, cfg.WorkspaceDefaults.TmuxPrefix
+	g.mu.Unlock()
+
+	select {
+	case g.reconfigured <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (g *SessionCollector) current() (config.SessionGCConfig, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.settings, g.prefix
+}
+
+// Run checks immediately and then every interval until ctx is canceled.
+// While the interval is zero it only waits for a reconfigure.
+func (g *SessionCollector) Run(ctx context.Context) {
+	for {
+		settings, _ := g.current()
+		if settings.Interval <= 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-g.reconfigured:
+				continue
+			}
+		}
+		if !g.runEvery(ctx, settings.Interval) {
+			return
+		}
+	}
+}
+
+// runEvery collects now and then every interval. It returns false once
+// ctx is canceled and true on a reconfigure.
+func (g *SessionCollector) runEvery(ctx context.Context, interval time.Duration) bool {
+	ticker := g.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		g.Collect(ctx)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C():
+		case <-g.reconfigured:
+			return true
+		}
+	}
+}
+
+// Collect runs one check with the configured settings and logs the
+// unreferenced sessions it found.
+func (g *SessionCollector) Collect(ctx context.Context) (*sessiongc.Report, error) {
+	settings, prefix := g.current()
+	report, err := g.collector.Collect(ctx, sessiongc.Options{
+		Prefix:    prefix,
+		OlderThan: settings.OlderThan,
+		Kill:      settings.Kill,
+	})
+	if err != nil {
+		g.logger.Warn().Ctx(ctx).Err(err).Msg("failed to check for unreferenced tmux sessions")
+		return nil, err
+	}
+
+	names := make([]string, 0, len(report.Candidates))
+	for _, candidate := range report.Candidates {
+		if candidate.Error != "" {
+			g.logger.Warn().Ctx(ctx).Str("session", candidate.Name).Str("error", candidate.Error).Msg("failed to kill unreferenced tmux session")
+			continue
+		}
+		names = append(names, candidate.Name)
+	}
+	if len(names) > 0 && !report.Kill {
+		g.logger.Warn().
+			Ctx(ctx).
+			Strs("sessions", names).
+			Dur("older_than", settings.OlderThan).
+			Msg("unreferenced tmux sessions found; run swarm maintenance gc-sessions --kill to remove them")
+	}
+	return report, nil
+}
@@ -0,0 +1,74 @@
+package swarmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+)
+
+func TestSessionCollectorReportsUnlessKillSet(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "repo", RepoPath: "/repos/repo", TmuxSession: "swarm-repo"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	srv := tmuxtest.NewServer(tmuxtest.WithClock(fake))
+	for _, name := range []string{"swarm-repo", "swarm-crashed", "scratch"} {
+		if _, err := srv.Run("new-session", "-d", "-s", name); err != nil {
+			t.Fatalf("new-session %s: %v", name, err)
+		}
+	}
+	fake.Advance(48 * time.Hour)
+
+	cfg := config.DefaultConfig()
+	gc := NewSessionCollector(database, tmux.NewClient(srv), cfg.Daemon.SessionGC, zerolog.Nop(), WithSessionGCClock(fake))
+	report, err := gc.Collect(ctx)
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(report.Candidates) != 1 || report.Candidates[0].Name != "swarm-crashed" || report.Candidates[0].Killed {
+		t.Fatalf("expected swarm-crashed reported only, got %+v", report)
+	}
+	if _, err := srv.Run("has-session", "-t", "swarm-crashed"); err != nil {
+		t.Fatalf("report-only check killed swarm-crashed: %v", err)
+	}
+
+	cfg.Daemon.SessionGC.Kill = true
+	if err := gc.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if report, err = gc.Collect(ctx); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(report.Candidates) != 1 || !report.Candidates[0].Killed {
+		t.Fatalf("expected swarm-crashed killed, got %+v", report)
+	}
+	for _, name := range []string{"swarm-repo", "scratch"} {
+		if _, err := srv.Run("has-session", "-t", name); err != nil {
+			t.Fatalf("expected %s left alone: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// DefaultStandbyInterval is how often standby pools are checked.
+const DefaultStandbyInterval = 30 * time.Second
+
+// StandbySpawner spawns idle standby agents for a workspace pool.
+type StandbySpawner interface {
+	SpawnStandby(ctx context.Context, workspaceID string, agentType models.AgentType, model string) (*models.Agent, error)
+}
+
+// StandbyReport summarizes one pass over standby pools.
+type StandbyReport struct {
+	// Spawned lists the standby agents started.
+	Spawned []string
+
+	// Throttled is how many spawns were put off by the spawn rate limit.
+	Throttled int
+
+	// Capped is how many spawns were dropped by a workspace's max_agents.
+	Capped int
+
+	// Errors lists pools that could not be refilled.
+	Errors []string
+}
+
+// StandbyRunner keeps each workspace's standby pools filled with idle
+// agents, as configured by workspace_overrides standby entries.
+type StandbyRunner struct {
+	workspaceRepo *db.WorkspaceRepository
+	agentRepo     *db.AgentRepository
+	spawner       StandbySpawner
+	clock         clock.Clock
+	logger        zerolog.Logger
+	trigger       chan struct{}
+
+	// mu guards the settings Reconfigure replaces.
+	mu           sync.Mutex
+	cfg          *config.Config
+	spawnLimit   RateLimitConfig
+	limiter      *tokenBucket
+	interval     time.Duration
+	reconfigured chan struct{}
+}
+
+// StandbyRunnerOption configures a StandbyRunner.
+type StandbyRunnerOption func(*StandbyRunner)
+
+// WithStandbyInterval sets how often standby pools are checked.
+func WithStandbyInterval(d time.Duration) StandbyRunnerOption {
+	return func(r *StandbyRunner) {
+		r.interval = d
+	}
+}
+
+// WithStandbySpawnLimit sets the rate standby agents may be spawned at
+// (default: the SpawnAgent RPC limit).
+func WithStandbySpawnLimit(limit RateLimitConfig) StandbyRunnerOption {
+	return func(r *StandbyRunner) {
+		r.spawnLimit = limit
+		r.limiter = newTokenBucket(limit)
+	}
+}
+
+// WithStandbyClock sets the time source for the check interval.
+func WithStandbyClock(c clock.Clock) StandbyRunnerOption {
+	return func(r *StandbyRunner) {
+		r.clock = c
+	}
+}
+
+// NewStandbyRunner creates a runner that fills the pools in cfg using
+// spawner, over the given database.
+func NewStandbyRunner(cfg *config.Config, database *db.DB, spawner StandbySpawner, logger zerolog.Logger, opts ...StandbyRunnerOption) *StandbyRunner {
+	r := &StandbyRunner{
+		cfg:           cfg,
+		workspaceRepo: db.NewWorkspaceRepository(database),
+		agentRepo:     db.NewAgentRepository(database),
+		spawner:       spawner,
+		interval:      DefaultStandbyInterval,
+		logger:        logger,
+		trigger:       make(chan struct{}, 1),
+		reconfigured:  make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.clock = clock.OrReal(r.clock)
+	if r.interval <= 0 {
+		r.interval = DefaultStandbyInterval
+	}
+	if r.limiter == nil {
+		r.spawnLimit = DefaultRateLimits["/swarmd.v1.SwarmdService/SpawnAgent"]
+		r.limiter = newTokenBucket(r.spawnLimit)
+	}
+	return r
+}
+
+// Reconfigure applies the standby pools, workspace caps, and check
+// interval of cfg, and the rate standby agents may be spawned at. A spawn
+// limit that did not change keeps its tokens. A running loop refills the
+// pools at once and then every new interval.
+func (r *StandbyRunner) Reconfigure(cfg *config.Config, spawnLimit RateLimitConfig) error {
+	if cfg == nil {
+		return errors.New("config is required")
+	}
+	interval := cfg.Daemon.StandbyInterval
+	if interval <= 0 {
+		interval = DefaultStandbyInterval
+	}
+
+	r.mu.Lock()
+	r.cfg, r.interval = cfg, interval
+	if spawnLimit != r.spawnLimit {
+		r.spawnLimit = spawnLimit
+		r.limiter = newTokenBucket(spawnLimit)
+	}
+	r.mu.Unlock()
+
+	select {
+	case r.reconfigured <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (r *StandbyRunner) settings() (*config.Config, *tokenBucket, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cfg, r.limiter, r.interval
+}
+
+// Trigger asks for a pass soon, such as after a standby agent in the
+// workspace was claimed. It never blocks; triggers that arrive while a
+// pass is pending are folded into it, and each pass covers every pool.
+func (r *StandbyRunner) Trigger(workspaceID string) {
+	select {
+	case r.trigger <- struct{}{}:
+		r.logger.Debug().Str("workspace_id", workspaceID).Msg("standby refill requested")
+	default:
+	}
+}
+
+// Run fills standby pools immediately, then every interval and on each
+// Trigger, until ctx is canceled.
+func (r *StandbyRunner) Run(ctx context.Context) {
+	_, _, interval := r.settings()
+	ticker := r.clock.NewTicker(interval)
+	defer func() { ticker.Stop() }()
+
+	for {
+		r.runOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case <-r.trigger:
+		case <-r.reconfigured:
+			ticker.Stop()
+			_, _, interval = r.settings()
+			ticker = r.clock.NewTicker(interval)
+		}
+	}
+}
+
+func (r *StandbyRunner) runOnce(ctx context.Context) {
+	report, err := r.Replenish(ctx)
+	if err != nil {
+		r.logger.Warn().Ctx(ctx).Err(err).Msg("standby check failed")
+		return
+	}
+	for _, msg := range report.Errors {
+		r.logger.Warn().Ctx(ctx).Str("error", msg).Msg("standby pool could not be refilled")
+	}
+	if len(report.Spawned) > 0 || report.Throttled > 0 || report.Capped > 0 {
+		r.logger.Info().
+			Ctx(ctx).
+			Int("spawned", len(report.Spawned)).
+			Int("throttled", report.Throttled).
+			Int("capped", report.Capped).
+			Msg("standby pools refilled")
+	}
+}
+
+// Replenish spawns the standby agents each pool is short of. Agents still
+// starting count toward their pool, so a pass never spawns twice for the
+// same gap. Spawns beyond the rate limit wait for a later pass, and none
+// take a workspace past its max_agents.
+func (r *StandbyRunner) Replenish(ctx context.Context) (*StandbyReport, error) {
+	workspaces, err := r.workspaceRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	cfg, limiter, _ := r.settings()
+	report := &StandbyReport{}
+	for _, ws := range workspaces {
+		pools := cfg.StandbyPoolsForWorkspace(ws)
+		if len(pools) == 0 {
+			continue
+		}
+		if err := r.replenishWorkspace(ctx, ws, pools, cfg.MaxAgentsForWorkspace(ws), limiter, report); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", workspaceName(ws), err))
+		}
+	}
+	return report, nil
+}
+
+func (r *StandbyRunner) replenishWorkspace(ctx context.Context, ws *models.Workspace, pools []config.StandbyPoolConfig, maxAgents int, limiter *tokenBucket, report *StandbyReport) error {
+	agents, err := r.agentRepo.ListByWorkspace(ctx, ws.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	live := 0
+	standby := make(map[models.AgentType]int)
+	for _, agent := range agents {
+		if agent.State == models.AgentStateStopped || agent.State == models.AgentStateError {
+			continue
+		}
+		live++
+		if agent.Standby {
+			standby[agent.Type]++
+		}
+	}
+
+	for _, pool := range pools {
+		agentType := models.AgentType(pool.AgentType)
+		for missing := pool.Count - standby[agentType]; missing > 0; missing-- {
+			if maxAgents > 0 && live >= maxAgents {
+				report.Capped += missing
+				break
+			}
+			if !limiter.allow() {
+				report.Throttled += missing
+				break
+			}
+			agent, err := r.spawner.SpawnStandby(ctx, ws.ID, agentType, pool.Model)
+			if err != nil {
+				return fmt.Errorf("failed to spawn %s standby: %w", agentType, err)
+			}
+			standby[agentType]++
+			live++
+			report.Spawned = append(report.Spawned, agent.ID)
+		}
+	}
+	return nil
+}
+
+func workspaceName(ws *models.Workspace) string {
+	if ws.Name != "" {
+		return ws.Name
+	}
+	return ws.ID
+}
@@ -0,0 +1,213 @@
+package swarmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// fakeStandbySpawner records standby agents as idle rows, as a spawn that
+// reached ready would.
+type fakeStandbySpawner struct {
+	repo   *db.AgentRepository
+	models []string
+}
+
+func (f *fakeStandbySpawner) SpawnStandby(ctx context.Context, workspaceID string, agentType models.AgentType, model string) (*models.Agent, error) {
+	agent := &models.Agent{
+		WorkspaceID: workspaceID,
+		Type:        agentType,
+		TmuxPane:    fmt.Sprintf("standby:0.%d", len(f.models)+1),
+		State:       models.AgentStateIdle,
+		Standby:     true,
+	}
+	if err := f.repo.Create(ctx, agent); err != nil {
+		return nil, err
+	}
+	f.models = append(f.models, model)
+	return agent, nil
+}
+
+func TestStandbyRunnerReplenish(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	workspaceRepo := db.NewWorkspaceRepository(database)
+	pooled := &models.Workspace{NodeID: node.ID, Name: "pooled", RepoPath: "/repos/pooled", TmuxSession: "pooled"}
+	capped := &models.Workspace{NodeID: node.ID, Name: "capped", RepoPath: "/repos/capped", TmuxSession: "capped"}
+	plain := &models.Workspace{NodeID: node.ID, Name: "plain", RepoPath: "/repos/plain", TmuxSession: "plain"}
+	for _, ws := range []*models.Workspace{pooled, capped, plain} {
+		if err := workspaceRepo.Create(ctx, ws); err != nil {
+			t.Fatalf("failed to create workspace: %v", err)
+		}
+	}
+	agentRepo := db.NewAgentRepository(database)
+	busy := &models.Agent{WorkspaceID: capped.ID, Type: models.AgentTypeOpenCode, TmuxPane: "capped:0.1", State: models.AgentStateWorking}
+	if err := agentRepo.Create(ctx, busy); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.WorkspaceOverrides = []config.WorkspaceOverrideConfig{
+		{Name: "pooled", Standby: []config.StandbyPoolConfig{
+			{AgentType: "opencode", Count: 2, Model: "fast"},
+			{AgentType: "claude-code", Count: 1},
+		}},
+		{Name: "capped", MaxAgents: 2, Standby: []config.StandbyPoolConfig{{AgentType: "opencode", Count: 3}}},
+	}
+	spawner := &fakeStandbySpawner{repo: agentRepo}
+	runner := NewStandbyRunner(cfg, database, spawner, zerolog.Nop(),
+		WithStandbySpawnLimit(RateLimitConfig{RequestsPerSecond: 0, BurstSize: 4}))
+
+	standbyCount := func(workspaceID string) int {
+		agents, err := agentRepo.ListByWorkspace(ctx, workspaceID)
+		if err != nil {
+			t.Fatalf("failed to list agents: %v", err)
+		}
+		count := 0
+		for _, agent := range agents {
+			if agent.Standby {
+				count++
+			}
+		}
+		return count
+	}
+
+	report, err := runner.Replenish(ctx)
+	if err != nil {
+		t.Fatalf("Replenish failed: %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", report.Errors)
+	}
+	if len(report.Spawned) != 4 || report.Capped != 2 || report.Throttled != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if got := standbyCount(pooled.ID); got != 3 {
+		t.Fatalf("expected the pooled workspace to have 3 standby agents, got %d", got)
+	}
+	if got := standbyCount(capped.ID); got != 1 {
+		t.Fatalf("expected max_agents to stop the capped pool at 1, got %d", got)
+	}
+	if got := standbyCount(plain.ID); got != 0 {
+		t.Fatalf("expected no standby agents without a pool, got %d", got)
+	}
+	fast := 0
+	for _, model := range spawner.models {
+		if model == "fast" {
+			fast++
+		}
+	}
+	if fast != 2 {
+		t.Fatalf("expected the pool model to be passed through, got %v", spawner.models)
+	}
+
+	// A claimed standby is refilled, but the spawn budget is spent.
+	if _, err := agentRepo.ClaimStandby(ctx, pooled.ID, models.AgentTypeOpenCode); err != nil {
+		t.Fatalf("failed to claim standby: %v", err)
+	}
+	runner.Trigger(pooled.ID)
+	runner.Trigger(pooled.ID)
+	report, err = runner.Replenish(ctx)
+	if err != nil {
+		t.Fatalf("Replenish failed: %v", err)
+	}
+	if len(report.Spawned) != 0 || report.Throttled != 1 {
+		t.Fatalf("expected the refill to be throttled, got %+v", report)
+	}
+
+	runner.limiter = newTokenBucket(RateLimitConfig{RequestsPerSecond: 0, BurstSize: 1})
+	report, err = runner.Replenish(ctx)
+	if err != nil {
+		t.Fatalf("Replenish failed: %v", err)
+	}
+	if len(report.Spawned) != 1 || standbyCount(pooled.ID) != 3 {
+		t.Fatalf("expected the claimed standby to be replaced, got %+v", report)
+	}
+}
+
+func TestStandbyRunnerReconfigure(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "pooled", RepoPath: "/repos/pooled", TmuxSession: "pooled"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	spawner := &fakeStandbySpawner{repo: db.NewAgentRepository(database)}
+	limit := RateLimitConfig{RequestsPerSecond: 0, BurstSize: 2}
+	runner := NewStandbyRunner(config.DefaultConfig(), database, spawner, zerolog.Nop(), WithStandbySpawnLimit(limit))
+	if report, err := runner.Replenish(ctx); err != nil || len(report.Spawned) != 0 {
+		t.Fatalf("expected nothing to fill without pools, got %+v (%v)", report, err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Daemon.StandbyInterval = time.Minute
+	cfg.WorkspaceOverrides = []config.WorkspaceOverrideConfig{
+		{Name: "pooled", Standby: []config.StandbyPoolConfig{{AgentType: "opencode", Count: 3}}},
+	}
+	if err := runner.Reconfigure(cfg, limit); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if _, _, interval := runner.settings(); interval != time.Minute {
+		t.Fatalf("expected the new interval, got %s", interval)
+	}
+
+	// The next pass fills the new pool, within the unchanged spawn budget.
+	report, err := runner.Replenish(ctx)
+	if err != nil {
+		t.Fatalf("Replenish failed: %v", err)
+	}
+	if len(report.Spawned) != 2 || report.Throttled != 1 {
+		t.Fatalf("expected 2 spawns and 1 throttled, got %+v", report)
+	}
+
+	// The same limit keeps its spent tokens; a new one starts full.
+	if err := runner.Reconfigure(cfg, limit); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if report, _ := runner.Replenish(ctx); report.Throttled != 1 {
+		t.Fatalf("expected the spent budget kept, got %+v", report)
+	}
+	if err := runner.Reconfigure(cfg, RateLimitConfig{RequestsPerSecond: 0, BurstSize: 5}); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if report, _ := runner.Replenish(ctx); len(report.Spawned) != 1 {
+		t.Fatalf("expected the new spawn limit to apply, got %+v", report)
+	}
+
+	if err := runner.Reconfigure(nil, limit); err == nil {
+		t.Fatal("expected a nil config to be rejected")
+	}
+}
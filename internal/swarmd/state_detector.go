@@ -0,0 +1,110 @@
+package swarmd
+
+import (
+	"regexp"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// DefaultStateDetector names the fallback patterns agents whose adapter
+// has no StateDetector are read with.
+const DefaultStateDetector = "default"
+
+// StateDetector reads an agent's state from its pane content. The
+// patterns are tried in the order approval, busy, prompt, error, since
+// full-screen CLIs keep their prompt drawn while they work; the first
+// match decides, and content none matches is taken as running. A nil
+// pattern never matches.
+type StateDetector struct {
+	// Approval matches a request to approve an action.
+	Approval *regexp.Regexp
+
+	// Busy matches the CLI at work.
+	Busy *regexp.Regexp
+
+	// Prompt matches the CLI waiting for input.
+	Prompt *regexp.Regexp
+
+	// Error matches the CLI reporting a failure it stopped on.
+	Error *regexp.Regexp
+}
+
+// detect returns the state content shows.
+func (d *StateDetector) detect(content string) swarmdv1.AgentState {
+	switch {
+	case matches(d.Approval, content):
+		return swarmdv1.AgentState_AGENT_STATE_WAITING_APPROVAL
+	case matches(d.Busy, content):
+		return swarmdv1.AgentState_AGENT_STATE_RUNNING
+	case matches(d.Prompt, content):
+		return swarmdv1.AgentState_AGENT_STATE_IDLE
+	case matches(d.Error, content):
+		return swarmdv1.AgentState_AGENT_STATE_FAILED
+	default:
+		return swarmdv1.AgentState_AGENT_STATE_RUNNING
+	}
+}
+
+func matches(re *regexp.Regexp, content string) bool {
+	return re != nil && re.MatchString(content)
+}
+
+// WithStateDetector reads the state of agents spawned with adapter using
+// d, in place of any built-in detector for it. The prompt and busy regexes
+// of the adapter's configured overrides still take precedence.
+func WithStateDetector(adapter string, d StateDetector) ServerOption {
+	return func(s *Server) {
+		s.stateDetectors[adapter] = d
+	}
+}
+
+// builtinStateDetectors returns the detectors of the CLIs swarm ships
+// adapters for, keyed by adapter name.
+func builtinStateDetectors() map[string]StateDetector {
+	spinner := `[⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏]`
+	return map[string]StateDetector{
+		string(models.AgentTypeClaudeCode): {
+			Approval: regexp.MustCompile(`(?m)Do you want to [^\n]*\?\s*$|^\s*❯\s*1\.\s*Yes\b`),
+			Busy:     regexp.MustCompile(`(?i)\besc to interrupt\b|` + spinner),
+			Prompt:   regexp.MustCompile(`(?m)^\s*(?:│\s*)?(?:claude)?>(?:\s|$)|^\s*❯(?:\s|$)|\? for shortcuts`),
+			Error:    regexp.MustCompile(`(?m)^\s*(?:⎿\s*)?API Error\b`),
+		},
+		string(models.AgentTypeCodex): {
+			Approval: regexp.MustCompile(`(?i)do you want to proceed|approve this action|allow execution|run this command|execute\?|\[y/n\]|\(y/n\)`),
+			Busy:     regexp.MustCompile(`(?i)\besc to interrupt\b|` + spinner),
+			Prompt:   regexp.MustCompile(`(?m)^\s*(?:codex>|›|❯)(?:\s|$)`),
+			Error:    regexp.MustCompile(`(?m)^\s*(?:■\s*)?(?:error|fatal):`),
+		},
+		string(models.AgentTypeGemini): {
+			Approval: regexp.MustCompile(`(?i)do you want to proceed|allow this operation|allow execution|confirm execution|run this command|\[y/n\]|\(y/n\)|press y to confirm`),
+			Busy:     regexp.MustCompile(`(?i)\besc to cancel\b|` + spinner),
+			Prompt:   regexp.MustCompile(`(?m)^\s*(?:│\s*)?(?:gemini)?>(?:\s|$)|Type your message`),
+			Error:    regexp.MustCompile(`(?m)^\s*✕\s|\[API Error:`),
+		},
+		string(models.AgentTypeOpenCode): {
+			Approval: regexp.MustCompile(`(?i)permission required|\ballow once\b|\[y/n\]`),
+			Busy:     regexp.MustCompile(`(?i)\besc interrupt\b|▣|` + spinner),
+			Prompt:   regexp.MustCompile(`(?m)ctrl\+p commands|⊙|^\s*(?:opencode>|❯)(?:\s|$)`),
+			Error:    regexp.MustCompile(`(?m)^\s*(?:Error|error):`),
+		},
+	}
+}
+
+// stateDetector returns the detector for agents spawned with adapter and
+// its name, or nil and DefaultStateDetector when the adapter has none.
+func (s *Server) stateDetector(adapter string) (*StateDetector, string) {
+	d, ok := s.stateDetectors[adapter]
+	if !ok {
+		return nil, DefaultStateDetector
+	}
+	caps := adapters.CapabilitiesFor(models.AgentType(adapter))
+	if caps.PromptRegex != nil {
+		d.Prompt = caps.PromptRegex
+	}
+	if caps.BusyRegex != nil {
+		d.Busy = caps.BusyRegex
+	}
+	return &d, adapter
+}
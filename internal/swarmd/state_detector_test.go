@@ -0,0 +1,122 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestBuiltinStateDetectors(t *testing.T) {
+	server := NewServer(zerolog.Nop())
+
+	tests := []struct {
+		adapter string
+		content string
+		want    swarmdv1.AgentState
+	}{
+		{"claude-code", "╭────╮\n│ > │\n╰────╯\n  ? for shortcuts", swarmdv1.AgentState_AGENT_STATE_IDLE},
+		{"claude-code", "✻ Reticulating… (12s · esc to interrupt)\n│ > │", swarmdv1.AgentState_AGENT_STATE_RUNNING},
+		{"claude-code", "Bash(rm -rf build)\nDo you want to proceed?\n❯ 1. Yes\n  2. No", swarmdv1.AgentState_AGENT_STATE_WAITING_APPROVAL},
+		{"claude-code", "  ⎿  API Error: 529 overloaded", swarmdv1.AgentState_AGENT_STATE_FAILED},
+		// A diff that mentions errors is not a failure.
+		{"claude-code", "+\treturn fmt.Errorf(\"error: %w\", err)\n│ > │", swarmdv1.AgentState_AGENT_STATE_IDLE},
+		{"codex", "codex> ", swarmdv1.AgentState_AGENT_STATE_IDLE},
+		{"codex", "⠙ Working (esc to interrupt)\n› ", swarmdv1.AgentState_AGENT_STATE_RUNNING},
+		{"codex", "Run this command? (y/n)", swarmdv1.AgentState_AGENT_STATE_WAITING_APPROVAL},
+		{"gemini", "│ > Type your message or @path/to/file │", swarmdv1.AgentState_AGENT_STATE_IDLE},
+		{"gemini", "⠼ Thinking (esc to cancel, 3s)", swarmdv1.AgentState_AGENT_STATE_RUNNING},
+		{"gemini", "✕ [API Error: quota exceeded]", swarmdv1.AgentState_AGENT_STATE_FAILED},
+		{"opencode", "ctrl+p commands", swarmdv1.AgentState_AGENT_STATE_IDLE},
+		{"opencode", "▣ Build · esc interrupt", swarmdv1.AgentState_AGENT_STATE_RUNNING},
+		{"opencode", "Permission required: bash", swarmdv1.AgentState_AGENT_STATE_WAITING_APPROVAL},
+	}
+	for _, tt := range tests {
+		got, detector := server.detectAgentState(tt.content, tt.adapter)
+		if got != tt.want || detector != tt.adapter {
+			t.Errorf("detectAgentState(%q, %s) = %v by %q, want %v by %q", tt.content, tt.adapter, got, detector, tt.want, tt.adapter)
+		}
+	}
+}
+
+func TestWithStateDetector(t *testing.T) {
+	server := NewServer(zerolog.Nop(),
+		WithStateDetector("toy", StateDetector{
+			Prompt: regexp.MustCompile(`toy>\s*$`),
+			Error:  regexp.MustCompile(`(?m)^TOY FAULT`),
+		}),
+		WithStateDetector("claude-code", StateDetector{
+			Busy: regexp.MustCompile(`\[busy\]`),
+		}),
+	)
+
+	tests := []struct {
+		adapter string
+		content string
+		want    swarmdv1.AgentState
+	}{
+		{"toy", "toy> ", swarmdv1.AgentState_AGENT_STATE_IDLE},
+		{"toy", "TOY FAULT 3", swarmdv1.AgentState_AGENT_STATE_FAILED},
+		// Patterns left nil are not detected.
+		{"toy", "Proceed? [y/n]", swarmdv1.AgentState_AGENT_STATE_RUNNING},
+		// A registered detector replaces the built-in one.
+		{"claude-code", "│ > │", swarmdv1.AgentState_AGENT_STATE_RUNNING},
+	}
+	for _, tt := range tests {
+		got, detector := server.detectAgentState(tt.content, tt.adapter)
+		if got != tt.want || detector != tt.adapter {
+			t.Errorf("detectAgentState(%q, %s) = %v by %q, want %v by %q", tt.content, tt.adapter, got, detector, tt.want, tt.adapter)
+		}
+	}
+}
+
+func TestStateDetectorDefersToAdapterRegexes(t *testing.T) {
+	server := NewServer(zerolog.Nop(), WithStateDetector("toy", StateDetector{
+		Prompt: regexp.MustCompile(`toy>`),
+		Busy:   regexp.MustCompile(`\[busy\]`),
+	}))
+	t.Cleanup(adapters.RegisterAdapter(adapters.NewGenericAdapter("toy", "toy-cli",
+		adapters.WithPromptRegex(regexp.MustCompile(`toy:\d+>\s*$`)),
+	)))
+
+	tests := map[string]swarmdv1.AgentState{
+		"toy:3> ":       swarmdv1.AgentState_AGENT_STATE_IDLE,
+		"toy> ":         swarmdv1.AgentState_AGENT_STATE_RUNNING,
+		"[busy] toy:3>": swarmdv1.AgentState_AGENT_STATE_RUNNING,
+	}
+	for content, want := range tests {
+		if got, _ := server.detectAgentState(content, "toy"); got != want {
+			t.Errorf("detectAgentState(%q, toy) = %v, want %v", content, got, want)
+		}
+	}
+}
+
+func TestStreamPaneUpdatesReportsStateDetector(t *testing.T) {
+	for adapter, want := range map[string]string{"": DefaultStateDetector, "claude-code": "claude-code"} {
+		server := NewServer(zerolog.Nop())
+		srv, paneID := newPaneServer(t, "│ > │")
+		server.backend = newTmuxBackend(tmux.NewClient(srv), server.logger)
+		server.mu.Lock()
+		server.agents["agent-1"] = &agentInfo{id: "agent-1", paneID: paneID, adapter: adapter}
+		server.mu.Unlock()
+
+		stream := newPaneUpdateRecorder(60 * time.Millisecond)
+		err := server.StreamPaneUpdates(&swarmdv1.StreamPaneUpdatesRequest{
+			AgentId:     "agent-1",
+			MinInterval: durationpb.New(5 * time.Millisecond),
+		}, stream)
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("StreamPaneUpdates() error = %v", err)
+		}
+		if len(stream.responses) == 0 || stream.responses[0].StateDetector != want {
+			t.Fatalf("adapter %q: expected updates read by %q, got %v", adapter, want, stream.responses)
+		}
+	}
+}
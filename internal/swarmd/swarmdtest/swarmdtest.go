@@ -0,0 +1,84 @@
+// Package swarmdtest serves a swarmd.Server over an in-memory gRPC listener
+// for tests.
+//
+// Clients built with Daemon.ClientOptions reach the server through the real
+// gRPC stack, so interceptors, status codes, and streaming behave as they
+// would against a remote swarmd. The daemon can be stopped and started
+// again to exercise reconnects.
+package swarmdtest
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+	"github.com/opencode-ai/swarm/internal/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Endpoint is the daemon address tests store on nodes. Clients built with
+// ClientOptions ignore it and dial the in-memory listener.
+const Endpoint = "swarmd.test:50051"
+
+const bufferSize = 1 << 20
+
+// Daemon is a swarmd.Server served on an in-memory listener.
+type Daemon struct {
+	server *swarmd.Server
+	opts   []grpc.ServerOption
+
+	mu   sync.Mutex
+	lis  *bufconn.Listener
+	grpc *grpc.Server
+}
+
+// Serve starts serving server until the test ends. Calls are traced as
+// by swarmd. A non-empty token is required from every caller, as with
+// SWARMD_AUTH_TOKEN.
+func Serve(t testing.TB, server *swarmd.Server, token string) *Daemon {
+	t.Helper()
+	d := &Daemon{server: server}
+	unary := []grpc.UnaryServerInterceptor{trace.UnaryServerInterceptor()}
+	stream := []grpc.StreamServerInterceptor{trace.StreamServerInterceptor()}
+	if token != "" {
+		authUnary, authStream := swarmd.AuthInterceptors(token)
+		unary = append(unary, authUnary)
+		stream = append(stream, authStream)
+	}
+	d.opts = append(d.opts, grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+	d.Start()
+	t.Cleanup(d.Stop)
+	return d
+}
+
+// Start serves on a fresh listener, as a restarted daemon would.
+func (d *Daemon) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lis = bufconn.Listen(bufferSize)
+	d.grpc = grpc.NewServer(d.opts...)
+	swarmdv1.RegisterSwarmdServiceServer(d.grpc, d.server)
+	go func(srv *grpc.Server, lis net.Listener) { _ = srv.Serve(lis) }(d.grpc, d.lis)
+}
+
+// Stop closes the listener and every open connection.
+func (d *Daemon) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.grpc.Stop()
+}
+
+// ClientOptions routes swarmd.Dial to this daemon.
+func (d *Daemon) ClientOptions() []swarmd.ClientOption {
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		d.mu.Lock()
+		lis := d.lis
+		d.mu.Unlock()
+		return lis.DialContext(ctx)
+	}
+	return []swarmd.ClientOption{swarmd.WithDialOptions(grpc.WithContextDialer(dialer))}
+}
@@ -0,0 +1,104 @@
+package swarmd_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/swarmd"
+	"github.com/opencode-ai/swarm/internal/swarmd/swarmdtest"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/opencode-ai/swarm/internal/trace"
+	"github.com/rs/zerolog"
+)
+
+// syncBuffer is a bytes.Buffer safe for the server's concurrent logging.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// traceIDs returns the trace_id of each log line with message msg.
+func (b *syncBuffer) traceIDs(t *testing.T, msg string) []string {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var ids []string
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("failed to parse log line %q: %v", line, err)
+		}
+		if entry["message"] == msg {
+			id, _ := entry[logging.TraceIDField].(string)
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestTraceIDCrossesDaemonRoundTrip(t *testing.T) {
+	remote := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	level := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(level) })
+	logs := &syncBuffer{}
+	logger := zerolog.New(logs).Hook(logging.TraceHook{})
+	daemon := swarmdtest.Serve(t, swarmd.NewServer(logger, swarmd.WithTmuxClient(tmux.NewClient(remote))), "secret")
+
+	ctx := context.Background()
+	client, err := swarmd.Dial(ctx, swarmdtest.Endpoint, append(daemon.ClientOptions(), swarmd.WithAuthToken("secret"))...)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+
+	traced := trace.WithID(ctx, "cli-trace-1")
+	if _, err := client.SpawnAgent(traced, &swarmdv1.SpawnAgentRequest{
+		AgentId:     "agent-1",
+		WorkspaceId: "ws-1",
+		Command:     "claude",
+		WorkingDir:  "/repo",
+	}); err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	if ids := logs.traceIDs(t, "agent spawned"); len(ids) != 1 || ids[0] != "cli-trace-1" {
+		t.Fatalf("expected the caller's trace ID on the daemon's log line, got %v", ids)
+	}
+
+	// Streaming handlers log with the stream's trace too.
+	streamCtx, cancel := context.WithCancel(trace.WithID(ctx, "cli-trace-2"))
+	stream, err := client.StreamPaneUpdates(streamCtx, &swarmdv1.StreamPaneUpdatesRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("StreamPaneUpdates failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	cancel()
+	if ids := logs.traceIDs(t, "starting pane update stream"); len(ids) != 1 || ids[0] != "cli-trace-2" {
+		t.Fatalf("expected the stream's trace ID on the daemon's log line, got %v", ids)
+	}
+
+	// A caller without a trace gets one started by the daemon.
+	if _, err := client.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: "agent-1", Force: true}); err != nil {
+		t.Fatalf("KillAgent failed: %v", err)
+	}
+	if ids := logs.traceIDs(t, "agent killed"); len(ids) != 1 || !trace.Valid(ids[0]) || ids[0] == "cli-trace-1" {
+		t.Fatalf("expected a new trace ID for an untraced call, got %v", ids)
+	}
+}
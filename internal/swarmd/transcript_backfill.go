@@ -0,0 +1,114 @@
+package swarmd
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+)
+
+// DefaultTranscriptBackfillBytes caps the pane history imported into the
+// transcript of an adopted agent unless WithTranscriptBackfillLimit says
+// otherwise.
+const DefaultTranscriptBackfillBytes = 256 << 10
+
+// backfillSeparator is the content of the entry that ends an imported pane
+// history, so readers of a transcript see where swarmd started recording.
+const backfillSeparator = "──────── pane history before adoption ends here; live transcript follows ────────"
+
+// backfillStats describes the pane history imported for an adopted agent.
+type backfillStats struct {
+	entries int
+	bytes   int
+	dropped int // bytes of the oldest history left out over the cap
+}
+
+// WithTranscriptBackfillLimit caps the pane history imported into the
+// transcript of an adopted agent, in bytes; older history is dropped.
+// Zero disables the backfill.
+func WithTranscriptBackfillLimit(bytes int) ServerOption {
+	return func(s *Server) {
+		s.backfillLimit.Store(int64(bytes))
+	}
+}
+
+// backfillTranscript imports the scrollback of the agent's pane as OUTPUT
+// entries flagged backfilled, followed by a separator. Every entry takes a
+// timestamp just before adoptedAt, so time filters starting at the adoption
+// leave the history out, and carries its position in the history as a
+// sequence number. info must not be registered yet.
+func (s *Server) backfillTranscript(ctx context.Context, info *agentInfo, adoptedAt time.Time) (backfillStats, error) {
+	limit := int(s.backfillLimit.Load())
+	if limit <= 0 {
+		return backfillStats{}, nil
+	}
+	history, err := s.backend.CapturePane(ctx, info.paneID, true)
+	if err != nil {
+		return backfillStats{}, err
+	}
+
+	// Redact before splitting so secrets across a chunk boundary are
+	// still matched.
+	chunks, dropped := splitBackfill(s.currentRedactor().Redact(history), limit, maxTranscriptOutputBytes)
+	stats := backfillStats{entries: len(chunks), dropped: dropped}
+	if len(chunks) == 0 {
+		return stats, nil
+	}
+
+	backfilledAt := adoptedAt.Add(-time.Nanosecond)
+	for i, chunk := range chunks {
+		s.addTranscriptEntryAtLocked(info, backfilledAt, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, chunk, map[string]string{
+			"backfilled": "true",
+			"sequence":   strconv.Itoa(i),
+		})
+		stats.bytes += len(chunk)
+	}
+	s.addTranscriptEntryAtLocked(info, adoptedAt, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE, backfillSeparator, map[string]string{
+		"event":              "backfill_end",
+		"backfilled_entries": strconv.Itoa(stats.entries),
+		"dropped_bytes":      strconv.Itoa(stats.dropped),
+	})
+	return stats, nil
+}
+
+// splitBackfill splits pane history into chunks of at most chunkSize
+// bytes, cut at line ends where possible, after dropping trailing blank
+// lines and the oldest history beyond limit bytes. The chunks joined give
+// back the kept history; dropped is how many bytes were left out.
+func splitBackfill(history string, limit, chunkSize int) (chunks []string, dropped int) {
+	history = strings.TrimRight(history, " \t\r\n")
+	if limit > 0 && len(history) > limit {
+		cut := len(history) - limit
+		if history[cut-1] != '\n' {
+			if i := strings.IndexByte(history[cut:], '\n'); i >= 0 && i < len(history)-cut-1 {
+				cut += i + 1
+			} else {
+				for cut < len(history) && !utf8.RuneStart(history[cut]) {
+					cut++
+				}
+			}
+		}
+		dropped = cut
+		history = history[cut:]
+	}
+
+	for len(history) > 0 {
+		end := len(history)
+		if end > chunkSize {
+			end = chunkSize
+			if i := strings.LastIndexByte(history[:end], '\n'); i >= 0 {
+				end = i + 1
+			} else {
+				for end > 1 && !utf8.RuneStart(history[end]) {
+					end--
+				}
+			}
+		}
+		chunks = append(chunks, history[:end])
+		history = history[end:]
+	}
+	return chunks, dropped
+}
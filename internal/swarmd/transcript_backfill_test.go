@@ -0,0 +1,262 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSplitBackfill(t *testing.T) {
+	tests := []struct {
+		name        string
+		history     string
+		limit       int
+		chunkSize   int
+		wantChunks  []string
+		wantDropped int
+	}{
+		{
+			name:       "fits one chunk",
+			history:    "one\ntwo\n\n\n",
+			limit:      100,
+			chunkSize:  100,
+			wantChunks: []string{"one\ntwo"},
+		},
+		{
+			name:       "cut at line ends",
+			history:    "aaaa\nbbbb\ncccc\n",
+			limit:      100,
+			chunkSize:  11,
+			wantChunks: []string{"aaaa\nbbbb\n", "cccc"},
+		},
+		{
+			name:        "cap drops whole oldest lines",
+			history:     "old line\nkept one\nkept two",
+			limit:       15,
+			chunkSize:   100,
+			wantChunks:  []string{"kept two"},
+			wantDropped: 18,
+		},
+		{
+			name:        "cap at a line start",
+			history:     "old line\nkept one\nkept two",
+			limit:       17,
+			chunkSize:   100,
+			wantChunks:  []string{"kept one\nkept two"},
+			wantDropped: 9,
+		},
+		{
+			name:        "cap inside one long line",
+			history:     "ééééé",
+			limit:       5,
+			chunkSize:   100,
+			wantChunks:  []string{"éé"},
+			wantDropped: 6,
+		},
+		{
+			name:       "long line split on rune boundaries",
+			history:    "éééé",
+			limit:      100,
+			chunkSize:  3,
+			wantChunks: []string{"é", "é", "é", "é"},
+		},
+		{
+			name:      "blank history",
+			history:   "\n\n  \n",
+			limit:     100,
+			chunkSize: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, dropped := splitBackfill(tt.history, tt.limit, tt.chunkSize)
+			if fmt.Sprint(chunks) != fmt.Sprint(tt.wantChunks) || len(chunks) != len(tt.wantChunks) || dropped != tt.wantDropped {
+				t.Fatalf("splitBackfill() = %q, %d; want %q, %d", chunks, dropped, tt.wantChunks, tt.wantDropped)
+			}
+		})
+	}
+}
+
+// newBackfillServer returns a server on a fake tmux whose pane %0 has
+// printed lines of history.
+func newBackfillServer(t *testing.T, lines int, opts ...ServerOption) (*Server, *tmuxtest.Server) {
+	t.Helper()
+	srv := tmuxtest.NewServer()
+	if _, err := srv.Run("new-session", "-d", "-s", "legacy"); err != nil {
+		t.Fatalf("new-session: %v", err)
+	}
+	var history strings.Builder
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&history, "history line %05d of the session before swarmd\n", i)
+	}
+	if err := srv.Print("%0", history.String()); err != nil {
+		t.Fatalf("print history: %v", err)
+	}
+	server := NewServer(zerolog.Nop(), append([]ServerOption{WithTmuxClient(tmux.NewClient(srv))}, opts...)...)
+	return server, srv
+}
+
+func TestSpawnAgentAttachBackfillsHistory(t *testing.T) {
+	ctx := context.Background()
+	const lines = 3000 // about 150 KiB, several capture chunks of tmux history
+	const limit = 64 << 10
+	server, srv := newBackfillServer(t, lines, WithTranscriptBackfillLimit(limit))
+
+	resp, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{
+		AgentId:            "agent-1",
+		WorkspaceId:        "ws-1",
+		Command:            "claude",
+		AttachExistingPane: true,
+		PaneId:             "%0",
+	})
+	if err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	if resp.PaneId != "%0" {
+		t.Fatalf("expected the existing pane adopted, got %s", resp.PaneId)
+	}
+	if cmds := strings.Join(srv.Commands(), "\n"); strings.Contains(cmds, "split-window") || strings.Contains(cmds, "send-keys") {
+		t.Fatalf("expected nothing started in an adopted pane, got:\n%s", cmds)
+	}
+	transcript, err := server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetTranscript failed: %v", err)
+	}
+	entries := transcript.Entries
+	adoptedAt := entries[len(entries)-1].Timestamp.AsTime()
+	var backfilled strings.Builder
+	n := 0
+	for ; n < len(entries) && entries[n].Metadata["backfilled"] == "true"; n++ {
+		e := entries[n]
+		if e.Type != swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT {
+			t.Fatalf("entry %d: expected OUTPUT, got %s", n, e.Type)
+		}
+		if !e.Timestamp.AsTime().Before(adoptedAt) || !e.Timestamp.AsTime().Equal(entries[0].Timestamp.AsTime()) {
+			t.Fatalf("entry %d: expected one timestamp before adoption, got %s", n, e.Timestamp.AsTime())
+		}
+		if e.Metadata["sequence"] != strconv.Itoa(n) {
+			t.Fatalf("entry %d: expected sequence %d, got %q", n, n, e.Metadata["sequence"])
+		}
+		if len(e.Content) > maxTranscriptOutputBytes {
+			t.Fatalf("entry %d: %d bytes exceeds the entry cap", n, len(e.Content))
+		}
+		backfilled.WriteString(e.Content)
+	}
+	history := backfilled.String()
+	if n < limit/maxTranscriptOutputBytes || len(history) > limit {
+		t.Fatalf("expected the history capped at %d bytes, got %d entries of %d bytes", limit, n, len(history))
+	}
+	if !strings.HasPrefix(history, "history line ") || !strings.HasSuffix(history, fmt.Sprintf("line %05d of the session before swarmd", lines-1)) {
+		t.Fatalf("expected the newest whole lines kept, got %q...%q", history[:40], history[len(history)-40:])
+	}
+	if strings.Contains(history, "line 00000 ") {
+		t.Fatal("expected the oldest history dropped over the cap")
+	}
+
+	if len(entries) != n+2 {
+		t.Fatalf("expected a separator and the attach entry after the history, got %d more entries", len(entries)-n)
+	}
+	separator, attach := entries[n], entries[n+1]
+	if separator.Content != backfillSeparator || separator.Metadata["backfilled_entries"] != strconv.Itoa(n) || separator.Metadata["dropped_bytes"] == "0" {
+		t.Fatalf("unexpected separator %+v", separator)
+	}
+	if attach.Type != swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_COMMAND || attach.Metadata["event"] != "attach" {
+		t.Fatalf("unexpected attach entry %+v", attach)
+	}
+
+	// A time filter starting at the adoption leaves the history out.
+	live, err := server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1", StartTime: separator.Timestamp})
+	if err != nil {
+		t.Fatalf("GetTranscript failed: %v", err)
+	}
+	if len(live.Entries) != 2 || live.Entries[0].Id != separator.Id {
+		t.Fatalf("expected only the separator and attach entry from the adoption on, got %d entries", len(live.Entries))
+	}
+
+	// Live entries continue the IDs, and a cursor past the separator
+	// streams only them.
+	server.addTranscriptEntry("agent-1", swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_USER_INPUT, "hello", nil)
+	server.addTranscriptEntry("agent-1", swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "claude> hello", nil)
+	all, err := server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetTranscript failed: %v", err)
+	}
+	for i, e := range all.Entries {
+		if e.Id != int64(i) {
+			t.Fatalf("expected monotonic IDs, entry %d has ID %d", i, e.Id)
+		}
+	}
+	page, err := server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1", Limit: int32(n)})
+	if err != nil {
+		t.Fatalf("GetTranscript failed: %v", err)
+	}
+	if page.NextCursor != strconv.Itoa(n) {
+		t.Fatalf("expected the first page to end with the history, got cursor %q", page.NextCursor)
+	}
+
+	stream := newTranscriptStreamRecorder()
+	err = server.StreamTranscript(&swarmdv1.StreamTranscriptRequest{AgentId: "agent-1", Cursor: strconv.FormatInt(separator.Id+1, 10)}, stream)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("StreamTranscript failed: %v", err)
+	}
+	if len(stream.responses) != 1 || len(stream.responses[0].Entries) != 3 || stream.responses[0].Entries[0].Id != attach.Id {
+		t.Fatalf("expected the attach entry and live entries streamed, got %+v", stream.responses)
+	}
+	if stream.responses[0].Cursor != strconv.FormatInt(attach.Id+3, 10) {
+		t.Fatalf("expected resume cursor %d, got %s", attach.Id+3, stream.responses[0].Cursor)
+	}
+}
+
+func TestSpawnAgentAttachBackfillCap(t *testing.T) {
+	ctx := context.Background()
+
+	// Disabled backfill adopts the pane without its history.
+	server, _ := newBackfillServer(t, 50, WithTranscriptBackfillLimit(0))
+	if _, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-1", AttachExistingPane: true, PaneId: "%0"}); err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	transcript, err := server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetTranscript failed: %v", err)
+	}
+	if len(transcript.Entries) != 1 || transcript.Entries[0].Metadata["event"] != "attach" {
+		t.Fatalf("expected only the attach entry without backfill, got %d entries", len(transcript.Entries))
+	}
+
+	// Small history is imported whole, in one entry.
+	server, _ = newBackfillServer(t, 50)
+	if _, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-1", AttachExistingPane: true, PaneId: "%0"}); err != nil {
+		t.Fatalf("SpawnAgent failed: %v", err)
+	}
+	transcript, err = server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetTranscript failed: %v", err)
+	}
+	if len(transcript.Entries) != 3 || strings.Count(transcript.Entries[0].Content, "\n") != 49 || transcript.Entries[1].Metadata["dropped_bytes"] != "0" {
+		t.Fatalf("expected all 50 lines in one backfilled entry, got %+v", transcript.Entries)
+	}
+
+	// The pane can only be adopted once, and must exist.
+	_, err = server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-2", AttachExistingPane: true, PaneId: "%0"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("expected AlreadyExists adopting a pane twice, got %v", err)
+	}
+	_, err = server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-2", AttachExistingPane: true, PaneId: "%9"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for a missing pane, got %v", err)
+	}
+	_, err = server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-2", AttachExistingPane: true})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument without a pane, got %v", err)
+	}
+}
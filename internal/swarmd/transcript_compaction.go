@@ -0,0 +1,302 @@
+package swarmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultTranscriptCompactInterval is how often transcripts are compacted.
+const DefaultTranscriptCompactInterval = time.Hour
+
+// summaryPreviewLines is how many lines from each end of a compacted run a
+// SUMMARY entry keeps.
+const summaryPreviewLines = 5
+
+// CompactionStats describes what a transcript compaction replaced.
+type CompactionStats struct {
+	// Agents is the number of transcripts that changed.
+	Agents int
+	// Entries is the number of OUTPUT entries replaced.
+	Entries int
+	// Summaries is the number of SUMMARY entries written.
+	Summaries int
+	// Bytes is the content size of the replaced entries.
+	Bytes int64
+}
+
+// CompactTranscripts replaces runs of old OUTPUT entries with a single
+// SUMMARY entry each, keeping all other entries. A dry run reports the
+// same counts and leaves the transcripts as they are.
+func (s *Server) CompactTranscripts(ctx context.Context, req *swarmdv1.CompactTranscriptsRequest) (*swarmdv1.CompactTranscriptsResponse, error) {
+	if req.OlderThan == nil {
+		return nil, status.Error(codes.InvalidArgument, "older_than is required")
+	}
+	olderThan := req.OlderThan.AsDuration()
+	if olderThan < 0 {
+		return nil, status.Error(codes.InvalidArgument, "older_than must be zero or positive")
+	}
+
+	stats, err := s.compactTranscripts(req.AgentId, s.clock.Now().Add(-olderThan), req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	return &swarmdv1.CompactTranscriptsResponse{
+		Agents:           int32(stats.Agents),
+		EntriesCompacted: int32(stats.Entries),
+		Summaries:        int32(stats.Summaries),
+		BytesCompacted:   stats.Bytes,
+	}, nil
+}
+
+// compactTranscripts compacts the transcript of agentID, or of every agent
+// when agentID is empty, summarizing OUTPUT entries from before cutoff.
+// With dryRun, only the stats are computed.
+func (s *Server) compactTranscripts(agentID string, cutoff time.Time, dryRun bool) (CompactionStats, error) {
+	var total CompactionStats
+	compact := func(info *agentInfo) {
+		info.mu.Lock()
+		defer info.mu.Unlock()
+		if info.removed {
+			return
+		}
+		entries, stats := compactTranscript(info.transcript, cutoff)
+		if stats.Entries == 0 {
+			return
+		}
+		if !dryRun {
+			info.transcript = entries
+			info.transcriptBytes = transcriptSize(entries)
+		}
+		total.Agents++
+		total.Entries += stats.Entries
+		total.Summaries += stats.Summaries
+		total.Bytes += stats.Bytes
+	}
+
+	if agentID != "" {
+		info, exists := s.lookupAgent(agentID)
+		if !exists {
+			return total, status.Errorf(codes.NotFound, "agent %q not found", agentID)
+		}
+		compact(info)
+		return total, nil
+	}
+	for _, info := range s.agentHandles() {
+		compact(info)
+	}
+	return total, nil
+}
+
+// compactTranscript returns entries with each run of two or more
+// consecutive OUTPUT entries timestamped before cutoff replaced by one
+// SUMMARY entry. A summary takes the ID and timestamp of the first entry
+// of its run and covers IDs through the last, so IDs stay monotonic and
+// cursors inside the run resume at the summary. Other entries, including
+// earlier summaries, are kept as they are.
+func compactTranscript(entries []transcriptEntry, cutoff time.Time) ([]transcriptEntry, CompactionStats) {
+	var stats CompactionStats
+	var out []transcriptEntry
+	for i := 0; i < len(entries); {
+		end := i
+		for end < len(entries) && compactable(&entries[end], cutoff) {
+			end++
+		}
+		if end-i < 2 {
+			if end == i {
+				end++
+			}
+			out = append(out, entries[i:end]...)
+			i = end
+			continue
+		}
+
+		summary, size := summarizeOutput(entries[i:end])
+		out = append(out, summary)
+		stats.Entries += end - i
+		stats.Summaries++
+		stats.Bytes += size
+		i = end
+	}
+	if stats.Entries == 0 {
+		return entries, stats
+	}
+	return out, stats
+}
+
+func compactable(e *transcriptEntry, cutoff time.Time) bool {
+	return e.entryType == swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT && e.timestamp.Before(cutoff)
+}
+
+// summarizeOutput builds the SUMMARY entry replacing run and returns it
+// with the content size of run.
+func summarizeOutput(run []transcriptEntry) (transcriptEntry, int64) {
+	first, last := run[0], run[len(run)-1]
+	var size int64
+	for _, e := range run {
+		size += int64(len(e.content))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%d output entries compacted, %d bytes, %s to %s]\n",
+		len(run), size, first.timestamp.UTC().Format(time.RFC3339), last.timestamp.UTC().Format(time.RFC3339))
+	b.WriteString(headLines(first.content, summaryPreviewLines))
+	b.WriteString("\n...\n")
+	b.WriteString(tailLines(last.content, summaryPreviewLines))
+
+	return transcriptEntry{
+		id:        first.id,
+		endID:     last.lastID(),
+		timestamp: first.timestamp,
+		entryType: swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_SUMMARY,
+		content:   b.String(),
+		metadata: map[string]string{
+			"entries": strconv.Itoa(len(run)),
+			"bytes":   strconv.FormatInt(size, 10),
+			"start":   first.timestamp.UTC().Format(time.RFC3339Nano),
+			"end":     last.timestamp.UTC().Format(time.RFC3339Nano),
+			"last_id": strconv.FormatInt(last.lastID(), 10),
+		},
+	}, size
+}
+
+func headLines(content string, n int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func tailLines(content string, n int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TranscriptCompactor periodically compacts the transcripts of a Server.
+type TranscriptCompactor struct {
+	server *Server
+	clock  clock.Clock
+	logger zerolog.Logger
+
+	// mu guards the settings Reconfigure replaces.
+	mu           sync.Mutex
+	olderThan    time.Duration
+	interval     time.Duration
+	reconfigured chan struct{}
+}
+
+// TranscriptCompactorOption configures a TranscriptCompactor.
+type TranscriptCompactorOption func(*TranscriptCompactor)
+
+// WithTranscriptCompactInterval sets how often transcripts are compacted.
+func WithTranscriptCompactInterval(d time.Duration) TranscriptCompactorOption {
+	return func(c *TranscriptCompactor) {
+		c.interval = d
+	}
+}
+
+// WithTranscriptCompactClock sets the time source entry ages are measured
+// against.
+func WithTranscriptCompactClock(clk clock.Clock) TranscriptCompactorOption {
+	return func(c *TranscriptCompactor) {
+		c.clock = clk
+	}
+}
+
+// NewTranscriptCompactor creates a compactor summarizing server's OUTPUT
+// entries once they are older than olderThan. A zero olderThan disables
+// compaction.
+func NewTranscriptCompactor(server *Server, olderThan time.Duration, logger zerolog.Logger, opts ...TranscriptCompactorOption) *TranscriptCompactor {
+	c := &TranscriptCompactor{
+		server:       server,
+		olderThan:    olderThan,
+		interval:     DefaultTranscriptCompactInterval,
+		logger:       logger,
+		reconfigured: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.clock = clock.OrReal(c.clock)
+	if c.interval <= 0 {
+		c.interval = DefaultTranscriptCompactInterval
+	}
+	return c
+}
+
+// Reconfigure applies the transcript compaction settings of cfg. A running
+// loop compacts again at once and then every new interval.
+func (c *TranscriptCompactor) Reconfigure(cfg *config.Config) error {
+	interval := cfg.Daemon.TranscriptCompaction.Interval
+	if interval <= 0 {
+		interval = DefaultTranscriptCompactInterval
+	}
+
+	c.mu.Lock()
+	c.olderThan, c.interval = cfg.Daemon.TranscriptCompaction.OlderThan, interval
+	c.mu.Unlock()
+
+	select {
+	case c.reconfigured <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (c *TranscriptCompactor) settings() (time.Duration, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.olderThan, c.interval
+}
+
+// Run compacts immediately and then every interval until ctx is canceled.
+func (c *TranscriptCompactor) Run(ctx context.Context) {
+	_, interval := c.settings()
+	ticker := c.clock.NewTicker(interval)
+	defer func() { ticker.Stop() }()
+
+	for {
+		if stats := c.Compact(); stats.Entries > 0 {
+			c.logger.Info().
+				Ctx(ctx).
+				Int("agents", stats.Agents).
+				Int("entries", stats.Entries).
+				Int64("bytes", stats.Bytes).
+				Msg("compacted transcripts")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case <-c.reconfigured:
+			ticker.Stop()
+			_, interval = c.settings()
+			ticker = c.clock.NewTicker(interval)
+		}
+	}
+}
+
+// Compact summarizes the OUTPUT entries of every transcript older than the
+// configured age. It does nothing when compaction is disabled.
+func (c *TranscriptCompactor) Compact() CompactionStats {
+	olderThan, _ := c.settings()
+	if olderThan <= 0 {
+		return CompactionStats{}
+	}
+	stats, _ := c.server.compactTranscripts("", c.clock.Now().Add(-olderThan), false)
+	return stats
+}
@@ -0,0 +1,256 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// seedLongTranscript gives agent-1 a transcript of
+//
+//	0 COMMAND, 1-10 OUTPUT, 11 USER_INPUT, 12-16 OUTPUT, 17 STATE_CHANGE, 18 OUTPUT
+//
+// and returns the content size of the OUTPUT entries 1-10.
+func seedLongTranscript(server *Server) int {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	info := &agentInfo{id: "agent-1"}
+	server.agents["agent-1"] = info
+	add := func(entryType swarmdv1.TranscriptEntryType, content string) {
+		server.addTranscriptEntryLocked(info, entryType, content, nil)
+	}
+	output := func(n int) string {
+		var b strings.Builder
+		for line := 0; line < 8; line++ {
+			fmt.Fprintf(&b, "output %d line %d\n", n, line)
+		}
+		return b.String()
+	}
+
+	size := 0
+	add(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_COMMAND, "claude")
+	for n := 1; n <= 10; n++ {
+		add(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, output(n))
+		size += len(output(n))
+	}
+	add(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_USER_INPUT, "continue")
+	for n := 12; n <= 16; n++ {
+		add(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, output(n))
+	}
+	add(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE, "idle")
+	add(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, output(18))
+	return size
+}
+
+func TestCompactTranscripts(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := NewServer(zerolog.Nop(), WithClock(fake))
+	firstRunBytes := seedLongTranscript(server)
+
+	// Nothing is old enough yet.
+	resp, err := server.CompactTranscripts(ctx, &swarmdv1.CompactTranscriptsRequest{OlderThan: durationpb.New(time.Hour)})
+	if err != nil || resp.EntriesCompacted != 0 {
+		t.Fatalf("expected nothing compacted, got %+v (%v)", resp, err)
+	}
+
+	fake.Advance(2 * time.Hour)
+	plan, err := server.CompactTranscripts(ctx, &swarmdv1.CompactTranscriptsRequest{AgentId: "agent-1", OlderThan: durationpb.New(time.Hour), DryRun: true})
+	if err != nil {
+		t.Fatalf("CompactTranscripts() dry run error = %v", err)
+	}
+	if entries := len(server.agents["agent-1"].transcript); entries != 19 {
+		t.Fatalf("expected a dry run to leave all 19 entries, got %d", entries)
+	}
+
+	resp, err = server.CompactTranscripts(ctx, &swarmdv1.CompactTranscriptsRequest{AgentId: "agent-1", OlderThan: durationpb.New(time.Hour)})
+	if err != nil {
+		t.Fatalf("CompactTranscripts() error = %v", err)
+	}
+	if resp.Agents != 1 || resp.EntriesCompacted != 15 || resp.Summaries != 2 {
+		t.Fatalf("unexpected compaction %+v", resp)
+	}
+	if plan.Agents != resp.Agents || plan.EntriesCompacted != resp.EntriesCompacted || plan.Summaries != resp.Summaries || plan.BytesCompacted != resp.BytesCompacted {
+		t.Fatalf("expected the dry run %+v to match the compaction %+v", plan, resp)
+	}
+
+	got, err := server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetTranscript() error = %v", err)
+	}
+	want := []struct {
+		id        int64
+		entryType swarmdv1.TranscriptEntryType
+	}{
+		{0, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_COMMAND},
+		{1, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_SUMMARY},
+		{11, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_USER_INPUT},
+		{12, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_SUMMARY},
+		{17, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE},
+		{18, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT},
+	}
+	if len(got.Entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(got.Entries))
+	}
+	for i, e := range got.Entries {
+		if e.Id != want[i].id || e.Type != want[i].entryType {
+			t.Fatalf("entry %d: got %d %s, want %d %s", i, e.Id, e.Type, want[i].id, want[i].entryType)
+		}
+	}
+	if got.Entries[2].Content != "continue" || got.Entries[4].Content != "idle" {
+		t.Fatalf("expected non-OUTPUT entries kept verbatim, got %q and %q", got.Entries[2].Content, got.Entries[4].Content)
+	}
+
+	summary := got.Entries[1]
+	if summary.Metadata["entries"] != "10" || summary.Metadata["bytes"] != fmt.Sprint(firstRunBytes) || summary.Metadata["last_id"] != "10" {
+		t.Fatalf("unexpected summary metadata %v", summary.Metadata)
+	}
+	for _, line := range []string{"output 1 line 0", "output 1 line 4", "output 10 line 3", "output 10 line 7"} {
+		if !strings.Contains(summary.Content, line) {
+			t.Fatalf("expected summary to keep %q, got %q", line, summary.Content)
+		}
+	}
+	if strings.Contains(summary.Content, "output 1 line 5") || strings.Contains(summary.Content, "output 5 ") {
+		t.Fatalf("expected summary to drop the middle of the run, got %q", summary.Content)
+	}
+
+	// New entries keep counting from where the transcript left off, and a
+	// second pass leaves summaries alone.
+	server.addTranscriptEntry("agent-1", swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "later", nil)
+	if next := server.agents["agent-1"].transcript; next[len(next)-1].id != 19 {
+		t.Fatalf("expected the next entry to get ID 19, got %d", next[len(next)-1].id)
+	}
+	resp, err = server.CompactTranscripts(ctx, &swarmdv1.CompactTranscriptsRequest{OlderThan: durationpb.New(time.Hour)})
+	if err != nil || resp.EntriesCompacted != 0 {
+		t.Fatalf("expected a second pass to compact nothing, got %+v (%v)", resp, err)
+	}
+}
+
+func TestCompactTranscriptsValidation(t *testing.T) {
+	server := NewServer(zerolog.Nop())
+	ctx := context.Background()
+
+	if _, err := server.CompactTranscripts(ctx, &swarmdv1.CompactTranscriptsRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument without older_than, got %v", err)
+	}
+	if _, err := server.CompactTranscripts(ctx, &swarmdv1.CompactTranscriptsRequest{OlderThan: durationpb.New(-time.Hour)}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for a negative age, got %v", err)
+	}
+	if _, err := server.CompactTranscripts(ctx, &swarmdv1.CompactTranscriptsRequest{AgentId: "missing", OlderThan: durationpb.New(0)}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
+
+func TestTranscriptCursorsAcrossCompaction(t *testing.T) {
+	server := NewServer(zerolog.Nop())
+	seedLongTranscript(server)
+	if _, err := server.compactTranscripts("", time.Now().Add(time.Hour), false); err != nil {
+		t.Fatalf("compactTranscripts() error = %v", err)
+	}
+
+	// A page ending at a summary continues after the range it covers.
+	page, err := server.GetTranscript(context.Background(), &swarmdv1.GetTranscriptRequest{AgentId: "agent-1", Limit: 2})
+	if err != nil {
+		t.Fatalf("GetTranscript() error = %v", err)
+	}
+	if page.NextCursor != "11" {
+		t.Fatalf("expected next cursor 11, got %q", page.NextCursor)
+	}
+
+	tests := []struct {
+		cursor  string
+		firstID int64
+	}{
+		{"1", 1},   // start of a compacted range
+		{"5", 1},   // inside it
+		{"10", 1},  // its last ID
+		{"11", 11}, // just past it
+		{"14", 12}, // inside the second range
+	}
+	for _, tt := range tests {
+		stream := newTranscriptStreamRecorder()
+		err := server.StreamTranscript(&swarmdv1.StreamTranscriptRequest{AgentId: "agent-1", Cursor: tt.cursor}, stream)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			t.Fatalf("StreamTranscript(%s) error = %v", tt.cursor, err)
+		}
+		if len(stream.responses) != 1 {
+			t.Fatalf("cursor %s: expected 1 response, got %d", tt.cursor, len(stream.responses))
+		}
+		resp := stream.responses[0]
+		if resp.Entries[0].Id != tt.firstID {
+			t.Fatalf("cursor %s: expected entry %d first, got %d", tt.cursor, tt.firstID, resp.Entries[0].Id)
+		}
+		if resp.Cursor != "19" {
+			t.Fatalf("cursor %s: expected resume cursor 19, got %q", tt.cursor, resp.Cursor)
+		}
+	}
+}
+
+func TestTranscriptCompactor(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := NewServer(zerolog.Nop(), WithClock(fake))
+	seedLongTranscript(server)
+
+	compactor := NewTranscriptCompactor(server, 24*time.Hour, zerolog.Nop(), WithTranscriptCompactClock(fake))
+	if stats := compactor.Compact(); stats.Entries != 0 {
+		t.Fatalf("expected fresh output kept, got %+v", stats)
+	}
+
+	// Disabled compaction leaves old output alone.
+	fake.Advance(48 * time.Hour)
+	cfg := config.DefaultConfig()
+	cfg.Daemon.TranscriptCompaction.OlderThan = 0
+	if err := compactor.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if stats := compactor.Compact(); stats.Entries != 0 {
+		t.Fatalf("expected nothing compacted while disabled, got %+v", stats)
+	}
+
+	cfg.Daemon.TranscriptCompaction.OlderThan = 24 * time.Hour
+	if err := compactor.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if stats := compactor.Compact(); stats != (CompactionStats{Agents: 1, Entries: 15, Summaries: 2, Bytes: stats.Bytes}) || stats.Bytes == 0 {
+		t.Fatalf("unexpected compaction %+v", stats)
+	}
+}
+
+// transcriptStreamRecorder records the first StreamTranscript response and
+// then ends the stream.
+type transcriptStreamRecorder struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	responses []*swarmdv1.StreamTranscriptResponse
+}
+
+func newTranscriptStreamRecorder() *transcriptStreamRecorder {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	return &transcriptStreamRecorder{ctx: ctx, cancel: cancel}
+}
+
+func (s *transcriptStreamRecorder) Send(resp *swarmdv1.StreamTranscriptResponse) error {
+	s.responses = append(s.responses, resp)
+	s.cancel()
+	return nil
+}
+
+func (s *transcriptStreamRecorder) SetHeader(metadata.MD) error  { return nil }
+func (s *transcriptStreamRecorder) SendHeader(metadata.MD) error { return nil }
+func (s *transcriptStreamRecorder) SetTrailer(metadata.MD)       {}
+func (s *transcriptStreamRecorder) Context() context.Context     { return s.ctx }
+func (s *transcriptStreamRecorder) SendMsg(interface{}) error    { return nil }
+func (s *transcriptStreamRecorder) RecvMsg(interface{}) error    { return nil }
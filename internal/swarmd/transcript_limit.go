@@ -0,0 +1,76 @@
+package swarmd
+
+import "time"
+
+// Default per-agent transcript limits, unless WithTranscriptLimit says
+// otherwise.
+const (
+	DefaultTranscriptMaxEntries = 10000
+	DefaultTranscriptMaxBytes   = 16 << 20
+)
+
+// WithTranscriptLimit caps each agent's in-memory transcript at maxEntries
+// entries and maxBytes of content and metadata. The oldest entries are
+// evicted past either cap; IDs are never reused, so cursors stay valid. The
+// newest entry is always kept. Zero disables a cap.
+func WithTranscriptLimit(maxEntries int, maxBytes int64) ServerOption {
+	return func(s *Server) {
+		s.transcriptMaxEntries.Store(int64(maxEntries))
+		s.transcriptMaxBytes.Store(maxBytes)
+	}
+}
+
+// transcriptEntrySize is the size e counts for against the byte cap.
+func transcriptEntrySize(e *transcriptEntry) int64 {
+	size := int64(len(e.content))
+	for k, v := range e.metadata {
+		size += int64(len(k) + len(v))
+	}
+	return size
+}
+
+// transcriptSize is the total size of entries.
+func transcriptSize(entries []transcriptEntry) int64 {
+	var size int64
+	for i := range entries {
+		size += transcriptEntrySize(&entries[i])
+	}
+	return size
+}
+
+// evictTranscriptLocked drops the oldest entries of info's transcript
+// beyond the server's limits. The caller must hold info.mu.
+func (s *Server) evictTranscriptLocked(info *agentInfo) {
+	maxEntries := int(s.transcriptMaxEntries.Load())
+	maxBytes := s.transcriptMaxBytes.Load()
+
+	n := 0
+	for n < len(info.transcript)-1 {
+		overEntries := maxEntries > 0 && len(info.transcript)-n > maxEntries
+		overBytes := maxBytes > 0 && info.transcriptBytes > maxBytes
+		if !overEntries && !overBytes {
+			break
+		}
+		e := &info.transcript[n]
+		info.transcriptBytes -= transcriptEntrySize(e)
+		info.transcriptFloor = e.lastID() + 1
+		info.transcriptEvictedAt = e.timestamp
+		// Clear the slot so the evicted content can be collected before
+		// the next append reallocates.
+		*e = transcriptEntry{}
+		n++
+	}
+	if n > 0 {
+		info.transcript = info.transcript[n:]
+	}
+}
+
+// transcriptTruncatedLocked reports whether entries from cursor on, and
+// at or after since when set, were evicted from info's transcript. The
+// caller must hold info.mu.
+func transcriptTruncatedLocked(info *agentInfo, cursor int64, since *time.Time) bool {
+	if info.transcriptFloor <= info.transcriptStart || cursor >= info.transcriptFloor {
+		return false
+	}
+	return since == nil || !info.transcriptEvictedAt.Before(*since)
+}
@@ -0,0 +1,131 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// seedTranscript gives agent-1 count OUTPUT entries "output 0".."output N"
+// a minute apart and returns its agentInfo.
+func seedTranscript(server *Server, count int) *agentInfo {
+	server.mu.Lock()
+	info := &agentInfo{id: "agent-1"}
+	server.agents["agent-1"] = info
+	server.mu.Unlock()
+
+	fake, _ := server.clock.(*clock.Fake)
+	for i := 0; i < count; i++ {
+		server.addTranscriptEntry("agent-1", swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, fmt.Sprintf("output %d", i), nil)
+		if fake != nil {
+			fake.Advance(time.Minute)
+		}
+	}
+	return info
+}
+
+func TestTranscriptLimitEvictsOldestEntries(t *testing.T) {
+	server := NewServer(zerolog.Nop(), WithTranscriptLimit(3, 0))
+	info := seedTranscript(server, 5)
+
+	if len(info.transcript) != 3 || info.transcript[0].id != 2 || info.transcript[2].id != 4 {
+		t.Fatalf("expected entries 2-4 kept, got %+v", info.transcript)
+	}
+	if info.transcriptNext != 5 || info.transcriptFloor != 2 {
+		t.Fatalf("expected next ID 5 and floor 2, got %d and %d", info.transcriptNext, info.transcriptFloor)
+	}
+	if info.transcriptBytes != transcriptSize(info.transcript) {
+		t.Fatalf("expected %d bytes tracked, got %d", transcriptSize(info.transcript), info.transcriptBytes)
+	}
+
+	// The byte cap evicts down to what fits, always keeping the newest.
+	server = NewServer(zerolog.Nop(), WithTranscriptLimit(0, int64(2*len("output 0"))))
+	info = seedTranscript(server, 4)
+	if len(info.transcript) != 2 || info.transcript[0].id != 2 {
+		t.Fatalf("expected the 2 newest entries kept, got %+v", info.transcript)
+	}
+	server.addTranscriptEntry("agent-1", swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, strings.Repeat("x", 100), nil)
+	if len(info.transcript) != 1 || info.transcript[0].id != 4 {
+		t.Fatalf("expected only the oversized newest entry kept, got %+v", info.transcript)
+	}
+}
+
+func TestGetTranscriptReportsEvictedEntries(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	server := NewServer(zerolog.Nop(), WithClock(clock.NewFake(start)), WithTranscriptLimit(3, 0))
+	seedTranscript(server, 5)
+
+	resp, err := server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetTranscript() error = %v", err)
+	}
+	if !resp.Truncated || resp.EarliestCursor != "2" || len(resp.Entries) != 3 || resp.Entries[0].Id != 2 {
+		t.Fatalf("expected a truncated response from entry 2, got %+v", resp)
+	}
+
+	// Nothing is missing from a cursor at or past the oldest entry left, or
+	// from a time range starting after the evicted entries.
+	resp, err = server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1", Cursor: "2"})
+	if err != nil || resp.Truncated || resp.EarliestCursor != "" || len(resp.Entries) != 3 {
+		t.Fatalf("expected an untruncated response from the floor, got %+v (%v)", resp, err)
+	}
+	resp, err = server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1", StartTime: timestamppb.New(start.Add(90 * time.Second))})
+	if err != nil || resp.Truncated || len(resp.Entries) != 3 {
+		t.Fatalf("expected an untruncated response after the evicted entries, got %+v (%v)", resp, err)
+	}
+}
+
+func TestStreamTranscriptJumpsOverEvictedCursor(t *testing.T) {
+	server := NewServer(zerolog.Nop(), WithTranscriptLimit(3, 0))
+	seedTranscript(server, 5)
+
+	stream := newTranscriptStreamRecorder()
+	err := server.StreamTranscript(&swarmdv1.StreamTranscriptRequest{AgentId: "agent-1", Cursor: "1"}, stream)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("StreamTranscript() error = %v", err)
+	}
+	if len(stream.responses) != 1 {
+		t.Fatalf("expected one response, got %d", len(stream.responses))
+	}
+	resp := stream.responses[0]
+	if !resp.Truncated || len(resp.Entries) != 3 || resp.Entries[0].Id != 2 || resp.Cursor != "5" {
+		t.Fatalf("expected entries 2-4 marked truncated, got %+v", resp)
+	}
+}
+
+func TestTranscriptLimitServesEvictedEntriesFromStore(t *testing.T) {
+	ctx := context.Background()
+	database := openTranscriptStore(t)
+	server := NewServer(zerolog.Nop(),
+		WithTranscriptLimit(2, 0),
+		WithTranscriptStore(db.NewTranscriptRepository(database)),
+	)
+	seedTranscript(server, 5)
+
+	resp, err := server.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetTranscript() error = %v", err)
+	}
+	if resp.Truncated || len(resp.Entries) != 5 || resp.Entries[0].Id != 0 || resp.Entries[4].Id != 4 {
+		t.Fatalf("expected all 5 entries, evicted ones from the store, got %+v", resp)
+	}
+
+	stream := newTranscriptStreamRecorder()
+	err = server.StreamTranscript(&swarmdv1.StreamTranscriptRequest{AgentId: "agent-1"}, stream)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("StreamTranscript() error = %v", err)
+	}
+	if len(stream.responses) != 1 || len(stream.responses[0].Entries) != 3 || stream.responses[0].Cursor != "3" {
+		t.Fatalf("expected the evicted entries replayed from the store first, got %+v", stream.responses)
+	}
+}
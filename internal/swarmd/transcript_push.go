@@ -0,0 +1,494 @@
+package swarmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// Transcript push defaults, used when the matching option is unset.
+const (
+	DefaultTranscriptPushInterval  = time.Second
+	DefaultTranscriptPushBatchSize = 100
+	DefaultTranscriptPushTimeout   = 10 * time.Second
+
+	// maxTranscriptPushBackoff caps the wait between attempts while a
+	// receiver is failing.
+	maxTranscriptPushBackoff = 5 * time.Minute
+)
+
+// Headers set on each transcript push request.
+const (
+	// TranscriptPushSignatureHeader carries "sha256=" and the hex
+	// HMAC-SHA256 of the body under the subscription's secret. It is only
+	// set for subscriptions with a secret.
+	TranscriptPushSignatureHeader = "X-Swarm-Signature"
+
+	// TranscriptPushSubscriptionHeader carries the subscription ID.
+	TranscriptPushSubscriptionHeader = "X-Swarm-Subscription"
+
+	// TranscriptPushDeliveryHeader identifies the batch by its stream and
+	// first cursor. A batch retried after a failure keeps its delivery ID,
+	// so receivers can drop duplicates.
+	TranscriptPushDeliveryHeader = "X-Swarm-Delivery"
+)
+
+// TranscriptPushBatch is the JSON body POSTed to a subscription's URL.
+type TranscriptPushBatch struct {
+	SubscriptionID string `json:"subscription_id"`
+	AgentID        string `json:"agent_id"`
+
+	// Stream identifies the transcript the entries' IDs belong to; it
+	// changes when the agent is adopted again after a swarmd restart.
+	Stream string `json:"stream"`
+
+	// Cursor is the ID of the next entry after this batch.
+	Cursor  int64                 `json:"cursor"`
+	Entries []TranscriptPushEntry `json:"entries"`
+
+	// Final marks the last batch, sent once the agent has terminated and
+	// its transcript has been pushed in full.
+	Final bool `json:"final,omitempty"`
+}
+
+// TranscriptPushEntry is one transcript entry in a batch.
+type TranscriptPushEntry struct {
+	ID        int64             `json:"id"`
+	Timestamp time.Time         `json:"timestamp"`
+	Type      string            `json:"type"`
+	Content   string            `json:"content"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// SignTranscriptPush returns the TranscriptPushSignatureHeader value for
+// body under secret.
+func SignTranscriptPush(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyTranscriptPush reports whether signature is the signature of body
+// under secret, comparing in constant time.
+func VerifyTranscriptPush(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(SignTranscriptPush(secret, body)))
+}
+
+// TranscriptPusher follows the active transcript push subscriptions in a
+// database, with one worker per subscription delivering the new entries
+// of its agent's transcript in Server. Delivery is at least once: the
+// cursor is only persisted after the receiver answers 2xx, so a batch
+// whose acknowledgement is lost is sent again.
+type TranscriptPusher struct {
+	server    *Server
+	repo      *db.TranscriptPushRepository
+	agentRepo *db.AgentRepository
+	client    *http.Client
+	clock     clock.Clock
+	logger    zerolog.Logger
+	interval  time.Duration
+	batchSize int
+	timeout   time.Duration
+
+	mu      sync.Mutex
+	workers map[string]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// TranscriptPusherOption configures a TranscriptPusher.
+type TranscriptPusherOption func(*TranscriptPusher)
+
+// WithTranscriptPushInterval sets how often subscriptions are synced with
+// the database and transcripts checked for new entries.
+func WithTranscriptPushInterval(d time.Duration) TranscriptPusherOption {
+	return func(p *TranscriptPusher) {
+		p.interval = d
+	}
+}
+
+// WithTranscriptPushBatchSize caps the entries POSTed per request.
+func WithTranscriptPushBatchSize(n int) TranscriptPusherOption {
+	return func(p *TranscriptPusher) {
+		p.batchSize = n
+	}
+}
+
+// WithTranscriptPushTimeout bounds each request.
+func WithTranscriptPushTimeout(d time.Duration) TranscriptPusherOption {
+	return func(p *TranscriptPusher) {
+		p.timeout = d
+	}
+}
+
+// WithTranscriptPushClient sets the HTTP client batches are POSTed with.
+func WithTranscriptPushClient(c *http.Client) TranscriptPusherOption {
+	return func(p *TranscriptPusher) {
+		p.client = c
+	}
+}
+
+// WithTranscriptPushClock sets the time source for polling and retry
+// backoff.
+func WithTranscriptPushClock(c clock.Clock) TranscriptPusherOption {
+	return func(p *TranscriptPusher) {
+		p.clock = c
+	}
+}
+
+// NewTranscriptPusher creates a pusher for the subscriptions in database,
+// reading transcripts from server.
+func NewTranscriptPusher(server *Server, database *db.DB, logger zerolog.Logger, opts ...TranscriptPusherOption) *TranscriptPusher {
+	p := &TranscriptPusher{
+		server:    server,
+		repo:      db.NewTranscriptPushRepository(database),
+		agentRepo: db.NewAgentRepository(database),
+		client:    &http.Client{},
+		logger:    logger,
+		interval:  DefaultTranscriptPushInterval,
+		batchSize: DefaultTranscriptPushBatchSize,
+		timeout:   DefaultTranscriptPushTimeout,
+		workers:   make(map[string]context.CancelFunc),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.clock = clock.OrReal(p.clock)
+	if p.interval <= 0 {
+		p.interval = DefaultTranscriptPushInterval
+	}
+	if p.batchSize <= 0 {
+		p.batchSize = DefaultTranscriptPushBatchSize
+	}
+	if p.timeout <= 0 {
+		p.timeout = DefaultTranscriptPushTimeout
+	}
+	return p
+}
+
+// Run syncs workers with the active subscriptions at once and then every
+// interval until ctx is canceled, then waits for the workers to stop.
+// Subscriptions added since the last sync get a worker starting from
+// their persisted cursor; removed ones have theirs stopped.
+func (p *TranscriptPusher) Run(ctx context.Context) {
+	ticker := p.clock.NewTicker(p.interval)
+	defer ticker.Stop()
+	defer p.wg.Wait()
+
+	for {
+		p.sync(ctx)
+		select {
+		case <-ctx.Done():
+			p.stopWorkers(nil)
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// sync starts a worker for each active subscription without one and stops
+// the workers of subscriptions no longer active.
+func (p *TranscriptPusher) sync(ctx context.Context) {
+	subs, err := p.repo.ListActive(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Warn().Ctx(ctx).Err(err).Msg("failed to list transcript push subscriptions")
+		}
+		return
+	}
+
+	active := make(map[string]bool, len(subs))
+	p.mu.Lock()
+	for _, sub := range subs {
+		active[sub.ID] = true
+		if _, running := p.workers[sub.ID]; running {
+			continue
+		}
+		workerCtx, cancel := context.WithCancel(ctx)
+		p.workers[sub.ID] = cancel
+		p.wg.Add(1)
+		go func(sub *models.TranscriptPush) {
+			defer p.wg.Done()
+			defer p.forget(sub.ID)
+			p.follow(workerCtx, sub)
+		}(sub)
+	}
+	p.mu.Unlock()
+
+	p.stopWorkers(active)
+}
+
+// stopWorkers cancels the workers whose subscription is not in keep.
+func (p *TranscriptPusher) stopWorkers(keep map[string]bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, cancel := range p.workers {
+		if !keep[id] {
+			cancel()
+		}
+	}
+}
+
+func (p *TranscriptPusher) forget(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cancel, ok := p.workers[id]; ok {
+		cancel()
+		delete(p.workers, id)
+	}
+}
+
+// pushWorker is the state of one subscription's worker.
+type pushWorker struct {
+	sub *models.TranscriptPush
+
+	// info is the agent handle being followed. It is kept after the agent
+	// is killed, so the entries recorded before that are still pushed.
+	info *agentInfo
+
+	// drained is set once info was killed and its transcript pushed in
+	// full, so a new handle for a respawned agent can be followed.
+	drained bool
+}
+
+// follow pushes sub's entries until the subscription completes, is
+// removed, or ctx is canceled. After a failed attempt it backs off,
+// doubling up to maxTranscriptPushBackoff.
+func (p *TranscriptPusher) follow(ctx context.Context, sub *models.TranscriptPush) {
+	ticker := p.clock.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	w := &pushWorker{sub: sub}
+	var backoff time.Duration
+	var retryAt time.Time
+	for {
+		if !p.clock.Now().Before(retryAt) {
+			done, more, err := p.push(ctx, w)
+			switch {
+			case done || errors.Is(err, db.ErrTranscriptPushNotFound):
+				return
+			case err != nil:
+				if ctx.Err() != nil {
+					return
+				}
+				backoff = min(max(2*backoff, p.interval), maxTranscriptPushBackoff)
+				retryAt = p.clock.Now().Add(backoff)
+				p.logger.Warn().Ctx(ctx).Err(err).
+					Str("subscription_id", sub.ID).
+					Str("agent_id", sub.AgentID).
+					Dur("retry_in", backoff).
+					Msg("transcript push failed")
+				if err := p.repo.RecordFailure(ctx, sub.ID, sub.Head, err.Error()); errors.Is(err, db.ErrTranscriptPushNotFound) {
+					return
+				}
+			default:
+				if backoff > 0 {
+					p.logger.Info().Ctx(ctx).Str("subscription_id", sub.ID).Msg("transcript push delivering again")
+				}
+				backoff, retryAt = 0, time.Time{}
+				if more {
+					continue
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// push delivers the next batch of w's entries. It reports done once the
+// final batch is acknowledged, and more when entries remain beyond the
+// batch.
+func (p *TranscriptPusher) push(ctx context.Context, w *pushWorker) (done, more bool, err error) {
+	sub := w.sub
+	if info, ok := p.server.lookupAgent(sub.AgentID); ok && info != w.info && (w.info == nil || w.drained) {
+		w.info, w.drained = info, false
+	}
+	if w.info == nil {
+		// The agent is not running here (yet): swarmd may have restarted
+		// and not adopted it again. Finish once it is gone for good.
+		if !p.agentEnded(ctx, sub.AgentID) {
+			return false, false, nil
+		}
+		if err := p.finish(ctx, sub, nil, sub.Cursor); err != nil {
+			return false, false, err
+		}
+		return true, false, nil
+	}
+
+	// A new transcript starts over. Its pane history was imported from a
+	// pane whose output the earlier transcript already held.
+	stream := strconv.FormatInt(w.info.spawnedAt.UnixNano(), 10)
+	skipBackfill := false
+	if sub.Stream != stream {
+		skipBackfill = sub.Stream != ""
+		sub.Stream, sub.Cursor = stream, 0
+	}
+
+	entries, head, removed := p.server.transcriptFrom(w.info, sub.Cursor, p.batchSize)
+	sub.Head = head
+	cursor := sub.Cursor
+	var batch []TranscriptPushEntry
+	for _, e := range entries {
+		cursor = e.lastID() + 1
+		name := transcriptEntryTypeName(e.entryType)
+		if !sub.Pushes(name) || (skipBackfill && isBackfill(&e)) {
+			continue
+		}
+		batch = append(batch, TranscriptPushEntry{
+			ID:        e.id,
+			Timestamp: e.timestamp,
+			Type:      name,
+			Content:   e.content,
+			Metadata:  e.metadata,
+		})
+	}
+	more = cursor < head
+
+	// A killed agent may be respawned under the same ID, as restarts do;
+	// only a terminated one ends the subscription.
+	if removed && !more {
+		if p.agentEnded(ctx, sub.AgentID) {
+			if err := p.finish(ctx, sub, batch, cursor); err != nil {
+				return false, false, err
+			}
+			return true, false, nil
+		}
+		w.drained = true
+	}
+	if cursor == sub.Cursor {
+		return false, false, nil
+	}
+
+	var deliveredAt *time.Time
+	if len(batch) > 0 {
+		if err := p.post(ctx, sub, batch, cursor, false); err != nil {
+			return false, false, err
+		}
+		now := p.clock.Now()
+		deliveredAt = &now
+	}
+	if err := p.repo.Advance(ctx, sub.ID, sub.Stream, cursor, head, len(batch), deliveredAt); err != nil {
+		return false, false, err
+	}
+	sub.Cursor = cursor
+	sub.Delivered += int64(len(batch))
+	return false, more, nil
+}
+
+// finish sends the final batch and completes the subscription.
+func (p *TranscriptPusher) finish(ctx context.Context, sub *models.TranscriptPush, batch []TranscriptPushEntry, cursor int64) error {
+	if err := p.post(ctx, sub, batch, cursor, true); err != nil {
+		return err
+	}
+	if err := p.repo.Complete(ctx, sub.ID, sub.Stream, cursor, len(batch), p.clock.Now()); err != nil {
+		return err
+	}
+	p.logger.Info().
+		Ctx(ctx).
+		Str("subscription_id", sub.ID).
+		Str("agent_id", sub.AgentID).
+		Int64("delivered", sub.Delivered+int64(len(batch))).
+		Msg("transcript push completed")
+	return nil
+}
+
+// agentEnded reports whether the agent's record is gone or terminated.
+func (p *TranscriptPusher) agentEnded(ctx context.Context, agentID string) bool {
+	agent, err := p.agentRepo.Get(ctx, agentID)
+	if errors.Is(err, db.ErrAgentNotFound) {
+		return true
+	}
+	return err == nil && agent.IsTerminated()
+}
+
+// post POSTs one batch, signed when the subscription has a secret.
+func (p *TranscriptPusher) post(ctx context.Context, sub *models.TranscriptPush, entries []TranscriptPushEntry, cursor int64, final bool) error {
+	if entries == nil {
+		entries = []TranscriptPushEntry{}
+	}
+	body, err := json.Marshal(TranscriptPushBatch{
+		SubscriptionID: sub.ID,
+		AgentID:        sub.AgentID,
+		Stream:         sub.Stream,
+		Cursor:         cursor,
+		Entries:        entries,
+		Final:          final,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TranscriptPushSubscriptionHeader, sub.ID)
+	req.Header.Set(TranscriptPushDeliveryHeader, fmt.Sprintf("%s:%d", sub.Stream, sub.Cursor))
+	if sub.Secret != "" {
+		req.Header.Set(TranscriptPushSignatureHeader, SignTranscriptPush(sub.Secret, body))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("receiver returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// transcriptFrom returns up to limit entries of info's transcript from
+// cursor on, the ID its next entry will take, and whether the agent has
+// been killed. Unlike lockAgent it also reads killed agents, whose
+// transcripts no longer change.
+func (s *Server) transcriptFrom(info *agentInfo, cursor int64, limit int) ([]transcriptEntry, int64, bool) {
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	var entries []transcriptEntry
+	for _, e := range info.transcript {
+		if e.lastID() < cursor {
+			continue
+		}
+		if len(entries) == limit {
+			break
+		}
+		entries = append(entries, e)
+	}
+	return entries, info.transcriptNext, info.removed
+}
+
+// isBackfill reports whether e was imported from pane history at adoption.
+func isBackfill(e *transcriptEntry) bool {
+	return e.metadata["backfilled"] == "true" || e.metadata["event"] == "backfill_end"
+}
+
+// transcriptEntryTypeName returns the lower-case name of a transcript entry
+// type, e.g. "output" for TRANSCRIPT_ENTRY_TYPE_OUTPUT.
+func transcriptEntryTypeName(t swarmdv1.TranscriptEntryType) string {
+	return strings.ToLower(strings.TrimPrefix(t.String(), "TRANSCRIPT_ENTRY_TYPE_"))
+}
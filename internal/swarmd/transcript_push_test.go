@@ -0,0 +1,352 @@
+package swarmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// pushReceiver records the batches POSTed to it, answering with the
+// statuses in fail before accepting.
+type pushReceiver struct {
+	t      *testing.T
+	secret string
+
+	mu      sync.Mutex
+	fail    []int
+	batches []TranscriptPushBatch
+	retries map[string]int
+}
+
+func (r *pushReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		r.t.Errorf("failed to read body: %v", err)
+		return
+	}
+	if r.secret != "" && !VerifyTranscriptPush(r.secret, body, req.Header.Get(TranscriptPushSignatureHeader)) {
+		r.t.Errorf("bad signature %q", req.Header.Get(TranscriptPushSignatureHeader))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retries[req.Header.Get(TranscriptPushDeliveryHeader)]++
+	if len(r.fail) > 0 {
+		w.WriteHeader(r.fail[0])
+		r.fail = r.fail[1:]
+		return
+	}
+	var batch TranscriptPushBatch
+	if err := json.Unmarshal(body, &batch); err != nil {
+		r.t.Errorf("failed to decode batch: %v", err)
+	}
+	r.batches = append(r.batches, batch)
+}
+
+// contents returns the content of every entry received, in order.
+func (r *pushReceiver) contents() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var contents []string
+	for _, batch := range r.batches {
+		for _, e := range batch.Entries {
+			contents = append(contents, e.Content)
+		}
+	}
+	return contents
+}
+
+// batchStream returns the stream of the first batch accepted.
+func batchStream(r *pushReceiver) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.batches[0].Stream
+}
+
+type pushTestEnv struct {
+	database *db.DB
+	server   *Server
+	repo     *db.TranscriptPushRepository
+	agent    *models.Agent
+	info     *agentInfo
+}
+
+func newPushTestEnv(t *testing.T) *pushTestEnv {
+	t.Helper()
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "repo", RepoPath: "/repos/repo", TmuxSession: "repo"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "repo:0.1", State: models.AgentStateWorking}
+	if err := db.NewAgentRepository(database).Create(ctx, agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	server := NewServer(zerolog.Nop())
+	info := &agentInfo{id: agent.ID, spawnedAt: time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)}
+	server.mu.Lock()
+	server.agents[agent.ID] = info
+	server.mu.Unlock()
+
+	return &pushTestEnv{
+		database: database,
+		server:   server,
+		repo:     db.NewTranscriptPushRepository(database),
+		agent:    agent,
+		info:     info,
+	}
+}
+
+func (e *pushTestEnv) record(entryType swarmdv1.TranscriptEntryType, contents ...string) {
+	e.info.mu.Lock()
+	defer e.info.mu.Unlock()
+	for _, content := range contents {
+		e.server.addTranscriptEntryLocked(e.info, entryType, content, nil)
+	}
+}
+
+func (e *pushTestEnv) subscribe(t *testing.T, url, secret string, entryTypes ...string) *models.TranscriptPush {
+	t.Helper()
+	sub := &models.TranscriptPush{AgentID: e.agent.ID, URL: url, Secret: secret, EntryTypes: entryTypes}
+	if err := e.repo.Create(context.Background(), sub); err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+	return sub
+}
+
+// run starts a pusher polling every few milliseconds and returns a func
+// stopping it.
+func (e *pushTestEnv) run(opts ...TranscriptPusherOption) func() {
+	opts = append([]TranscriptPusherOption{WithTranscriptPushInterval(5 * time.Millisecond)}, opts...)
+	pusher := NewTranscriptPusher(e.server, e.database, zerolog.Nop(), opts...)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pusher.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (e *pushTestEnv) stored(t *testing.T, id string) *models.TranscriptPush {
+	t.Helper()
+	sub, err := e.repo.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to get subscription: %v", err)
+	}
+	return sub
+}
+
+func TestTranscriptPushSignsFilteredBatches(t *testing.T) {
+	env := newPushTestEnv(t)
+	receiver := &pushReceiver{t: t, secret: "s3cret", retries: make(map[string]int)}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	env.record(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_COMMAND, "opencode")
+	env.record(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "hello", "world")
+	env.record(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE, "idle")
+	sub := env.subscribe(t, srv.URL, "s3cret", "output", "state_change")
+
+	stop := env.run()
+	defer stop()
+	waitFor(t, func() bool { return env.stored(t, sub.ID).Cursor == 4 })
+
+	if got := strings.Join(receiver.contents(), " "); got != "hello world idle" {
+		t.Fatalf("expected the output and state entries, got %q", got)
+	}
+	batch := receiver.batches[0]
+	if batch.SubscriptionID != sub.ID || batch.AgentID != env.agent.ID || batch.Cursor != 4 || batch.Final {
+		t.Fatalf("unexpected batch %+v", batch)
+	}
+	if e := batch.Entries[0]; e.ID != 1 || e.Type != "output" {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	stored := env.stored(t, sub.ID)
+	if stored.Delivered != 3 || stored.Head != 4 || stored.Lag() != 0 || stored.LastDeliveredAt == nil {
+		t.Fatalf("unexpected stored subscription %+v", stored)
+	}
+
+	// A receiver holding another secret rejects the batch.
+	if VerifyTranscriptPush("other", []byte(`{}`), SignTranscriptPush("s3cret", []byte(`{}`))) {
+		t.Fatal("expected a signature under another secret to be rejected")
+	}
+}
+
+func TestTranscriptPushRetriesBeforeAdvancing(t *testing.T) {
+	env := newPushTestEnv(t)
+	receiver := &pushReceiver{t: t, fail: []int{http.StatusInternalServerError, http.StatusBadGateway}, retries: make(map[string]int)}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	env.record(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "one", "two")
+	sub := env.subscribe(t, srv.URL, "")
+
+	stop := env.run()
+	waitFor(t, func() bool { return env.stored(t, sub.ID).Cursor == 2 })
+	stop()
+
+	if got := strings.Join(receiver.contents(), " "); got != "one two" {
+		t.Fatalf("expected the batch delivered once, got %q", got)
+	}
+	if n := receiver.retries[batchStream(receiver)+":0"]; n != 3 || len(receiver.retries) != 1 {
+		t.Fatalf("expected the batch sent three times under one delivery ID, got %v", receiver.retries)
+	}
+	stored := env.stored(t, sub.ID)
+	if stored.Failures != 0 || stored.LastError != "" || stored.Delivered != 2 {
+		t.Fatalf("expected the failures cleared after delivery, got %+v", stored)
+	}
+}
+
+func TestTranscriptPushFailureRecorded(t *testing.T) {
+	env := newPushTestEnv(t)
+	receiver := &pushReceiver{t: t, fail: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable}, retries: make(map[string]int)}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	env.record(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "one")
+	sub := env.subscribe(t, srv.URL, "")
+
+	stop := env.run()
+	defer stop()
+	waitFor(t, func() bool { return env.stored(t, sub.ID).Failures >= 1 })
+	stored := env.stored(t, sub.ID)
+	if stored.Cursor != 0 || stored.Head != 1 || stored.Lag() != 1 || !strings.Contains(stored.LastError, "status 503") {
+		t.Fatalf("expected the failure recorded with the cursor held, got %+v", stored)
+	}
+}
+
+func TestTranscriptPushResumesFromPersistedCursor(t *testing.T) {
+	env := newPushTestEnv(t)
+	receiver := &pushReceiver{t: t, retries: make(map[string]int)}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	env.record(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "a", "b", "c")
+	sub := env.subscribe(t, srv.URL, "")
+
+	stop := env.run()
+	waitFor(t, func() bool { return env.stored(t, sub.ID).Cursor == 3 })
+	stop()
+
+	// Entries recorded while no pusher runs are delivered by the next one,
+	// without resending what was acknowledged.
+	env.record(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "d", "e")
+	stop = env.run(WithTranscriptPushBatchSize(1))
+	waitFor(t, func() bool { return env.stored(t, sub.ID).Cursor == 5 })
+	stop()
+
+	if got := strings.Join(receiver.contents(), " "); got != "a b c d e" {
+		t.Fatalf("expected every entry exactly once, got %q", got)
+	}
+	if len(receiver.batches) != 3 {
+		t.Fatalf("expected one batch then one per entry, got %d", len(receiver.batches))
+	}
+}
+
+func TestTranscriptPushNewStreamSkipsBackfill(t *testing.T) {
+	env := newPushTestEnv(t)
+	receiver := &pushReceiver{t: t, retries: make(map[string]int)}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	env.record(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "before restart")
+	sub := env.subscribe(t, srv.URL, "")
+	stop := env.run()
+	waitFor(t, func() bool { return env.stored(t, sub.ID).Cursor == 1 })
+	stop()
+
+	// swarmd restarted and adopted the agent again: its new transcript
+	// starts with the pane history, already pushed from the old one.
+	adopted := &agentInfo{id: env.agent.ID, spawnedAt: env.info.spawnedAt.Add(time.Hour)}
+	adopted.mu.Lock()
+	env.server.addTranscriptEntryLocked(adopted, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "before restart", map[string]string{"backfilled": "true"})
+	env.server.addTranscriptEntryLocked(adopted, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE, backfillSeparator, map[string]string{"event": "backfill_end"})
+	env.server.addTranscriptEntryLocked(adopted, swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "after restart", nil)
+	adopted.mu.Unlock()
+	env.server.mu.Lock()
+	env.server.agents[env.agent.ID] = adopted
+	env.server.mu.Unlock()
+
+	stop = env.run()
+	waitFor(t, func() bool { return env.stored(t, sub.ID).Cursor == 3 })
+	stop()
+
+	if got := strings.Join(receiver.contents(), " | "); got != "before restart | after restart" {
+		t.Fatalf("expected the adopted pane history skipped, got %q", got)
+	}
+	if last := receiver.batches[len(receiver.batches)-1]; last.Stream == receiver.batches[0].Stream {
+		t.Fatalf("expected the new transcript pushed under a new stream, got %q", last.Stream)
+	}
+}
+
+func TestTranscriptPushCompletesWhenAgentTerminates(t *testing.T) {
+	ctx := context.Background()
+	env := newPushTestEnv(t)
+	receiver := &pushReceiver{t: t, retries: make(map[string]int)}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	env.record(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_OUTPUT, "working")
+	sub := env.subscribe(t, srv.URL, "")
+	stop := env.run()
+	defer stop()
+	waitFor(t, func() bool { return env.stored(t, sub.ID).Cursor == 1 })
+
+	// As KillAgent does, the last entry is recorded before the agent is
+	// dropped.
+	env.record(swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE, "stopped")
+	env.info.mu.Lock()
+	env.info.removed = true
+	env.info.mu.Unlock()
+	env.server.mu.Lock()
+	delete(env.server.agents, env.agent.ID)
+	env.server.mu.Unlock()
+	if err := db.NewAgentRepository(env.database).Delete(ctx, env.agent.ID); err != nil {
+		t.Fatalf("failed to terminate agent: %v", err)
+	}
+
+	waitFor(t, func() bool { return env.stored(t, sub.ID).Status == models.TranscriptPushCompleted })
+	last := receiver.batches[len(receiver.batches)-1]
+	if !last.Final || len(last.Entries) != 1 || last.Entries[0].Content != "stopped" || last.Cursor != 2 {
+		t.Fatalf("expected a final batch with the last entry, got %+v", last)
+	}
+	stored := env.stored(t, sub.ID)
+	if stored.CompletedAt == nil || stored.Delivered != 2 || stored.Lag() != 0 {
+		t.Fatalf("unexpected completed subscription %+v", stored)
+	}
+	active, err := env.repo.ListActive(ctx)
+	if err != nil || len(active) != 0 {
+		t.Fatalf("expected no active subscriptions, got %d (%v)", len(active), err)
+	}
+}
@@ -0,0 +1,232 @@
+package swarmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// DefaultTranscriptPruneInterval is how often stored transcripts are
+// pruned.
+const DefaultTranscriptPruneInterval = time.Hour
+
+// transcriptStoreTimeout bounds each write of a transcript entry, which
+// happens under the agent's lock.
+const transcriptStoreTimeout = 5 * time.Second
+
+// storedTranscriptPage is how many stored entries StreamTranscript replays
+// per message.
+const storedTranscriptPage = 1000
+
+// WithTranscriptStore writes every transcript entry to repo, so
+// GetTranscript and StreamTranscript serve transcripts recorded before a
+// restart and those of killed agents. An agent's transcript IDs continue
+// from the last stored one.
+func WithTranscriptStore(repo *db.TranscriptRepository) ServerOption {
+	return func(s *Server) {
+		s.transcripts = repo
+	}
+}
+
+// resumeTranscript starts info's transcript after the entries already
+// stored for the agent. It must run before info records any entry.
+func (s *Server) resumeTranscript(ctx context.Context, info *agentInfo) {
+	if s.transcripts == nil {
+		return
+	}
+	next, err := s.transcripts.NextID(ctx, info.id)
+	if err != nil {
+		s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", info.id).Msg("failed to read stored transcript; starting a new one")
+		return
+	}
+	info.transcriptStart = next
+	info.transcriptNext = next
+	info.transcriptFloor = next
+}
+
+// storeTranscriptEntry writes e to the transcript store, if any. A failed
+// write is logged; the entry stays in memory.
+func (s *Server) storeTranscriptEntry(agentID string, e *transcriptEntry) {
+	if s.transcripts == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), transcriptStoreTimeout)
+	defer cancel()
+
+	err := s.transcripts.Create(ctx, &models.TranscriptEntry{
+		AgentID:   agentID,
+		EntryID:   e.id,
+		EndID:     e.endID,
+		Timestamp: e.timestamp,
+		Type:      transcriptEntryTypeName(e.entryType),
+		Content:   e.content,
+		Metadata:  e.metadata,
+	})
+	if err != nil {
+		s.logger.Warn().Err(err).Str("agent_id", agentID).Int64("entry_id", e.id).Msg("failed to store transcript entry")
+	}
+}
+
+// storedTranscript returns agentID's stored entries matching opts.
+func (s *Server) storedTranscript(ctx context.Context, agentID string, opts db.TranscriptListOptions) ([]transcriptEntry, error) {
+	stored, err := s.transcripts.ListByAgent(ctx, agentID, opts)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]transcriptEntry, len(stored))
+	for i, e := range stored {
+		entries[i] = transcriptEntry{
+			id:        e.EntryID,
+			endID:     e.EndID,
+			timestamp: e.Timestamp,
+			entryType: transcriptEntryType(e.Type),
+			content:   e.Content,
+			metadata:  e.Metadata,
+		}
+	}
+	return entries, nil
+}
+
+// hasStoredTranscript reports whether any entry of agentID is stored.
+func (s *Server) hasStoredTranscript(ctx context.Context, agentID string) (bool, error) {
+	next, err := s.transcripts.NextID(ctx, agentID)
+	return next > 0, err
+}
+
+// transcriptEntryType is the inverse of transcriptEntryTypeName.
+func transcriptEntryType(name string) swarmdv1.TranscriptEntryType {
+	return swarmdv1.TranscriptEntryType(swarmdv1.TranscriptEntryType_value["TRANSCRIPT_ENTRY_TYPE_"+strings.ToUpper(name)])
+}
+
+// TranscriptPruner deletes stored transcript entries beyond the configured
+// age and per-agent count.
+type TranscriptPruner struct {
+	repo   *db.TranscriptRepository
+	clock  clock.Clock
+	logger zerolog.Logger
+
+	// mu guards the settings Reconfigure replaces.
+	mu           sync.Mutex
+	retention    config.TranscriptStoreConfig
+	reconfigured chan struct{}
+}
+
+// TranscriptPrunerOption configures a TranscriptPruner.
+type TranscriptPrunerOption func(*TranscriptPruner)
+
+// WithTranscriptPruneClock sets the time source entry ages are measured
+// against.
+func WithTranscriptPruneClock(c clock.Clock) TranscriptPrunerOption {
+	return func(p *TranscriptPruner) {
+		p.clock = c
+	}
+}
+
+// NewTranscriptPruner creates a pruner applying retention to the stored
+// transcripts.
+func NewTranscriptPruner(database *db.DB, retention config.TranscriptStoreConfig, logger zerolog.Logger, opts ...TranscriptPrunerOption) *TranscriptPruner {
+	p := &TranscriptPruner{
+		repo:         db.NewTranscriptRepository(database),
+		retention:    retention,
+		logger:       logger,
+		reconfigured: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.clock = clock.OrReal(p.clock)
+	return p
+}
+
+// Reconfigure applies the transcript retention settings of cfg. A running
+// loop prunes again at once and then every new interval.
+func (p *TranscriptPruner) Reconfigure(cfg *config.Config) error {
+	p.mu.Lock()
+	p.retention = cfg.Daemon.TranscriptStore
+	p.mu.Unlock()
+
+	select {
+	case p.reconfigured <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (p *TranscriptPruner) settings() config.TranscriptStoreConfig {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.retention
+}
+
+func (p *TranscriptPruner) interval() time.Duration {
+	if interval := p.settings().CleanupInterval; interval > 0 {
+		return interval
+	}
+	return DefaultTranscriptPruneInterval
+}
+
+// Run prunes immediately and then every interval until ctx is canceled.
+func (p *TranscriptPruner) Run(ctx context.Context) {
+	ticker := p.clock.NewTicker(p.interval())
+	defer func() { ticker.Stop() }()
+
+	for {
+		if pruned, err := p.Prune(ctx); err != nil {
+			p.logger.Warn().Ctx(ctx).Err(err).Msg("transcript pruning failed")
+		} else if pruned > 0 {
+			p.logger.Info().Ctx(ctx).Int64("pruned", pruned).Msg("pruned stored transcript entries")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case <-p.reconfigured:
+			ticker.Stop()
+			ticker = p.clock.NewTicker(p.interval())
+		}
+	}
+}
+
+// Prune deletes the stored entries older than the retention age, then
+// each agent's oldest beyond the retention count, and returns how many
+// were removed.
+func (p *TranscriptPruner) Prune(ctx context.Context) (int64, error) {
+	const batch = 1000
+	retention := p.settings()
+
+	var pruned int64
+	if retention.MaxAge > 0 {
+		cutoff := p.clock.Now().Add(-retention.MaxAge)
+		for {
+			n, err := p.repo.DeleteOlderThan(ctx, cutoff, batch)
+			pruned += n
+			if err != nil {
+				return pruned, err
+			}
+			if n < batch {
+				break
+			}
+		}
+	}
+	if retention.MaxEntries > 0 {
+		for {
+			n, err := p.repo.DeleteExcess(ctx, retention.MaxEntries, batch)
+			pruned += n
+			if err != nil {
+				return pruned, err
+			}
+			if n < batch {
+				break
+			}
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,140 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func openTranscriptStore(t *testing.T) *db.DB {
+	t.Helper()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(context.Background()); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+	return database
+}
+
+func TestTranscriptStoreSurvivesKillAndRestart(t *testing.T) {
+	ctx := context.Background()
+	database := openTranscriptStore(t)
+	srv := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	newServer := func() *Server {
+		return NewServer(zerolog.Nop(),
+			WithTmuxClient(tmux.NewClient(srv)),
+			WithTranscriptStore(db.NewTranscriptRepository(database)),
+		)
+	}
+	spawn := func(server *Server) {
+		t.Helper()
+		if _, err := server.SpawnAgent(ctx, &swarmdv1.SpawnAgentRequest{AgentId: "agent-1", WorkspaceId: "ws-1", Command: "claude"}); err != nil {
+			t.Fatalf("SpawnAgent() error = %v", err)
+		}
+	}
+
+	first := newServer()
+	spawn(first)
+	if _, err := first.SendInput(ctx, &swarmdv1.SendInputRequest{AgentId: "agent-1", Text: "fix the build"}); err != nil {
+		t.Fatalf("SendInput() error = %v", err)
+	}
+	if _, err := first.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: "agent-1", Force: true}); err != nil {
+		t.Fatalf("KillAgent() error = %v", err)
+	}
+
+	// The killed agent's transcript is served from the store.
+	killed, err := first.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("GetTranscript() after kill error = %v", err)
+	}
+	if len(killed.Entries) != 3 || killed.Entries[1].Content != "fix the build" ||
+		killed.Entries[2].Type != swarmdv1.TranscriptEntryType_TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE {
+		t.Fatalf("expected spawn, input, and stop entries, got %+v", killed.Entries)
+	}
+	if _, err := first.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-2"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for an agent never seen, got %v", err)
+	}
+
+	// After a restart the agent's IDs continue from the stored ones, and
+	// pages cross from stored entries into live ones.
+	second := newServer()
+	spawn(second)
+	var ids []int64
+	var contents []string
+	cursor := ""
+	for page := 0; ; page++ {
+		resp, err := second.GetTranscript(ctx, &swarmdv1.GetTranscriptRequest{AgentId: "agent-1", Cursor: cursor, Limit: 2})
+		if err != nil {
+			t.Fatalf("GetTranscript() page %d error = %v", page, err)
+		}
+		for _, e := range resp.Entries {
+			ids = append(ids, e.Id)
+			contents = append(contents, e.Content)
+		}
+		if !resp.HasMore {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+	if len(ids) != 4 || ids[0] != 0 || ids[3] != 3 || contents[3] != "claude" {
+		t.Fatalf("expected stored entries 0-2 then the new spawn as 3, got %v %q", ids, contents)
+	}
+
+	stream := newTranscriptStreamRecorder()
+	err = second.StreamTranscript(&swarmdv1.StreamTranscriptRequest{AgentId: "agent-1"}, stream)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("StreamTranscript() error = %v", err)
+	}
+	if len(stream.responses) != 1 || len(stream.responses[0].Entries) != 3 || stream.responses[0].Cursor != "3" {
+		t.Fatalf("expected the stored entries replayed first, got %+v", stream.responses)
+	}
+}
+
+func TestTranscriptPruner(t *testing.T) {
+	ctx := context.Background()
+	database := openTranscriptStore(t)
+	repo := db.NewTranscriptRepository(database)
+	fake := clock.NewFake(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+
+	for i := 0; i < 4; i++ {
+		entry := &models.TranscriptEntry{AgentID: "agent-1", EntryID: int64(i), Timestamp: fake.Now().Add(time.Duration(i-4) * 24 * time.Hour), Type: "output"}
+		if err := repo.Create(ctx, entry); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	pruner := NewTranscriptPruner(database, config.TranscriptStoreConfig{MaxAge: 60 * time.Hour}, zerolog.Nop(), WithTranscriptPruneClock(fake))
+	if pruned, err := pruner.Prune(ctx); err != nil || pruned != 2 {
+		t.Fatalf("expected the 2 entries older than 60h pruned, got %d (%v)", pruned, err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Daemon.TranscriptStore = config.TranscriptStoreConfig{MaxEntries: 1}
+	if err := pruner.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if pruned, err := pruner.Prune(ctx); err != nil || pruned != 1 {
+		t.Fatalf("expected 1 entry beyond max_entries pruned, got %d (%v)", pruned, err)
+	}
+	left, err := repo.ListByAgent(ctx, "agent-1", db.TranscriptListOptions{})
+	if err != nil || len(left) != 1 || left[0].EntryID != 3 {
+		t.Fatalf("expected only the newest entry kept, got %+v (%v)", left, err)
+	}
+}
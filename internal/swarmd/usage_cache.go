@@ -0,0 +1,137 @@
+package swarmd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/rs/zerolog"
+)
+
+// DefaultUsageCacheInterval is how often the daily usage cache is
+// refreshed.
+const DefaultUsageCacheInterval = 15 * time.Minute
+
+// UsageCacheMaintainer keeps the daily usage cache current, recomputing the
+// days of the usage stored since its last run, and prunes usage records and
+// cache rows beyond the configured age.
+type UsageCacheMaintainer struct {
+	usageRepo *db.UsageRepository
+	clock     clock.Clock
+	logger    zerolog.Logger
+
+	// mu guards the settings Reconfigure replaces.
+	mu           sync.Mutex
+	retention    config.UsageRetentionConfig
+	reconfigured chan struct{}
+}
+
+// UsageCacheOption configures a UsageCacheMaintainer.
+type UsageCacheOption func(*UsageCacheMaintainer)
+
+// WithUsageCacheClock sets the time source refreshes are stamped with and
+// retention ages are measured against.
+func WithUsageCacheClock(c clock.Clock) UsageCacheOption {
+	return func(m *UsageCacheMaintainer) {
+		m.clock = c
+	}
+}
+
+// NewUsageCacheMaintainer creates a maintainer for the daily usage cache.
+func NewUsageCacheMaintainer(database *db.DB, retention config.UsageRetentionConfig, logger zerolog.Logger, opts ...UsageCacheOption) *UsageCacheMaintainer {
+	m := &UsageCacheMaintainer{
+		usageRepo:    db.NewUsageRepository(database),
+		retention:    retention,
+		logger:       logger,
+		reconfigured: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.clock = clock.OrReal(m.clock)
+	return m
+}
+
+// Reconfigure applies the usage retention settings of cfg. A running loop
+// maintains the cache again at once and then every new interval.
+func (m *UsageCacheMaintainer) Reconfigure(cfg *config.Config) error {
+	m.mu.Lock()
+	m.retention = cfg.UsageRetention
+	m.mu.Unlock()
+
+	select {
+	case m.reconfigured <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (m *UsageCacheMaintainer) settings() config.UsageRetentionConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.retention
+}
+
+func (m *UsageCacheMaintainer) interval() time.Duration {
+	if interval := m.settings().CleanupInterval; interval > 0 {
+		return interval
+	}
+	return DefaultUsageCacheInterval
+}
+
+// Run maintains the cache immediately and then every interval until ctx is
+// canceled.
+func (m *UsageCacheMaintainer) Run(ctx context.Context) {
+	ticker := m.clock.NewTicker(m.interval())
+	defer func() { ticker.Stop() }()
+
+	for {
+		if refreshed, pruned, err := m.Maintain(ctx); err != nil {
+			m.logger.Warn().Ctx(ctx).Err(err).Msg("usage cache maintenance failed")
+		} else if refreshed > 0 || pruned > 0 {
+			m.logger.Debug().Ctx(ctx).Int("refreshed", refreshed).Int64("pruned", pruned).Msg("maintained usage cache")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		case <-m.reconfigured:
+			ticker.Stop()
+			ticker = m.clock.NewTicker(m.interval())
+		}
+	}
+}
+
+// Maintain prunes the usage older than the retention age, then refreshes
+// the cache. It returns the number of cache rows recomputed and of usage
+// records pruned. Pruning stops at the start of the UTC day the cutoff
+// falls in, so no day is left half in the cache.
+func (m *UsageCacheMaintainer) Maintain(ctx context.Context) (int, int64, error) {
+	now := m.clock.Now()
+
+	var pruned int64
+	if maxAge := m.settings().MaxAge; maxAge > 0 {
+		cutoff := now.Add(-maxAge).UTC()
+		cutoff = time.Date(cutoff.Year(), cutoff.Month(), cutoff.Day(), 0, 0, 0, 0, time.UTC)
+		const batch = 1000
+		for {
+			n, err := m.usageRepo.DeleteOlderThan(ctx, cutoff, batch)
+			pruned += n
+			if err != nil {
+				return 0, pruned, err
+			}
+			if n < batch {
+				break
+			}
+		}
+		if _, err := m.usageRepo.DeleteDailyCacheBefore(ctx, cutoff.Format("2006-01-02")); err != nil {
+			return 0, pruned, err
+		}
+	}
+
+	refreshed, err := m.usageRepo.RefreshDailyCache(ctx, now)
+	return refreshed, pruned, err
+}
@@ -0,0 +1,84 @@
+package swarmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/config"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestUsageCacheMaintainerMaintain(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	acct := &models.Account{Provider: models.ProviderAnthropic, ProfileName: "primary"}
+	if err := db.NewAccountRepository(database).Create(ctx, acct); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	usageRepo := db.NewUsageRepository(database)
+
+	now := time.Date(2026, 3, 20, 15, 0, 0, 0, time.UTC)
+	// The cutoff of a 48h max age falls at 15:00 on the 18th: the record
+	// that morning is kept with the rest of its day.
+	recordedAt := []time.Time{
+		time.Date(2026, 3, 17, 20, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 18, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 20, 10, 0, 0, 0, time.UTC),
+	}
+	for _, at := range recordedAt {
+		record := &models.UsageRecord{AccountID: acct.ID, Provider: models.ProviderAnthropic, InputTokens: 100, CostCents: 1, RecordedAt: at}
+		if err := usageRepo.Create(ctx, record); err != nil {
+			t.Fatalf("failed to create usage: %v", err)
+		}
+	}
+
+	fake := clock.NewFake(now)
+	maintainer := NewUsageCacheMaintainer(database, config.UsageRetentionConfig{}, zerolog.Nop(), WithUsageCacheClock(fake))
+
+	// Without a max age the cache is only refreshed.
+	refreshed, pruned, err := maintainer.Maintain(ctx)
+	if err != nil {
+		t.Fatalf("Maintain failed: %v", err)
+	}
+	if refreshed != 3 || pruned != 0 {
+		t.Fatalf("expected 3 days cached and nothing pruned, got %d and %d", refreshed, pruned)
+	}
+	if refreshedAt, err := usageRepo.DailyCacheRefreshedAt(ctx); err != nil || refreshedAt == nil || !refreshedAt.Equal(now) {
+		t.Fatalf("expected the refresh stamped with the clock, got %v (%v)", refreshedAt, err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.UsageRetention.MaxAge = 48 * time.Hour
+	if err := maintainer.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if _, pruned, err = maintainer.Maintain(ctx); err != nil {
+		t.Fatalf("Maintain failed: %v", err)
+	}
+	if pruned != 1 {
+		t.Fatalf("expected the record before the cutoff day pruned, pruned %d", pruned)
+	}
+
+	for date, want := range map[string]int{"2026-03-17": 0, "2026-03-18": 1, "2026-03-20": 1} {
+		rows, err := usageRepo.GetDailyCache(ctx, date)
+		if err != nil {
+			t.Fatalf("GetDailyCache failed: %v", err)
+		}
+		if len(rows) != want {
+			t.Errorf("%s: expected %d cache rows, got %d", date, want, len(rows))
+		}
+	}
+}
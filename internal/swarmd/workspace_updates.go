@@ -0,0 +1,200 @@
+package swarmd
+
+import (
+	"sort"
+	"time"
+
+	"github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/clock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// workspaceMember is an agent a StreamWorkspaceUpdates stream watches.
+// Each member keeps its own hash and send time, so a busy pane neither
+// delays nor repeats the updates of quiet ones.
+type workspaceMember struct {
+	sub      *captureSub
+	lastHash string
+	lastSent time.Duration
+	sent     bool
+	pending  *paneSnapshot // arrived before the stream's interval had passed
+}
+
+// notifyAgentsLocked wakes the watchers of the agent registry. The caller
+// must hold s.mu.
+func (s *Server) notifyAgentsLocked() {
+	if s.agentsChanged != nil {
+		close(s.agentsChanged)
+		s.agentsChanged = nil
+	}
+}
+
+// watchAgents returns the IDs of the registered agents match accepts and
+// a channel closed at the next registration or removal. match may only
+// read the fields fixed at spawn.
+func (s *Server) watchAgents(match func(*agentInfo) bool) ([]string, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for id, info := range s.agents {
+		if match(info) {
+			ids = append(ids, id)
+		}
+	}
+	if s.agentsChanged == nil {
+		s.agentsChanged = make(chan struct{})
+	}
+	return ids, s.agentsChanged
+}
+
+// StreamWorkspaceUpdates streams the pane changes of every agent matching
+// the request over one stream. Each agent is read from its shared capture
+// loop, as StreamPaneUpdates does, so watching a workspace adds no
+// captures for agents already streamed. Agents spawned into the set join
+// the stream; killed agents leave it with a removed update.
+func (s *Server) StreamWorkspaceUpdates(req *swarmdv1.StreamWorkspaceUpdatesRequest, stream swarmdv1.SwarmdService_StreamWorkspaceUpdatesServer) error {
+	pollInterval := defaultPollInterval
+	if req.MinInterval != nil && req.MinInterval.AsDuration() > 0 {
+		pollInterval = req.MinInterval.AsDuration()
+	}
+	maxInterval := req.GetMaxInterval().AsDuration()
+
+	var agentIDs map[string]bool
+	if len(req.AgentIds) > 0 {
+		agentIDs = make(map[string]bool, len(req.AgentIds))
+		for _, id := range req.AgentIds {
+			agentIDs[id] = true
+		}
+	}
+	match := func(info *agentInfo) bool {
+		return (req.WorkspaceId == "" || info.workspaceID == req.WorkspaceId) &&
+			(agentIDs == nil || agentIDs[info.id])
+	}
+
+	ctx := stream.Context()
+	wake := make(chan struct{}, 1)
+	members := make(map[string]*workspaceMember)
+	defer func() {
+		for _, m := range members {
+			s.unsubscribeCapture(m.sub)
+		}
+	}()
+
+	s.logger.Debug().
+		Ctx(ctx).
+		Str("workspace_id", req.WorkspaceId).
+		Strs("agent_ids", req.AgentIds).
+		Dur("poll_interval", pollInterval).
+		Msg("starting workspace update stream")
+
+	var roster <-chan struct{}
+	var err error
+	for {
+		var left []string
+
+		if roster == nil {
+			var ids []string
+			ids, roster = s.watchAgents(match)
+			current := make(map[string]bool, len(ids))
+			for _, id := range ids {
+				current[id] = true
+				if _, ok := members[id]; !ok {
+					members[id] = &workspaceMember{
+						sub:      s.subscribeCaptureWake(id, pollInterval, maxInterval, wake),
+						lastHash: req.LastKnownHashes[id],
+					}
+				}
+			}
+			for id, m := range members {
+				if !current[id] {
+					s.unsubscribeCapture(m.sub)
+					delete(members, id)
+					left = append(left, id)
+				}
+			}
+		}
+
+		for id, m := range members {
+			select {
+			case snap, ok := <-m.sub.updates:
+				if !ok {
+					delete(members, id)
+					left = append(left, id)
+					continue
+				}
+				m.pending = &snap
+			default:
+			}
+		}
+
+		sort.Strings(left)
+		for _, id := range left {
+			if err := stream.Send(&swarmdv1.StreamPaneUpdatesResponse{
+				AgentId:   id,
+				Removed:   true,
+				Timestamp: timestamppb.New(s.clock.Now()),
+			}); err != nil {
+				return err
+			}
+		}
+
+		// Send what is due, in agent order, and wait for the rest.
+		ids := make([]string, 0, len(members))
+		for id := range members {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		now := s.clock.Monotonic()
+		var wait time.Duration
+		for _, id := range ids {
+			m := members[id]
+			if m.pending == nil {
+				continue
+			}
+			if due := pollInterval - (now - m.lastSent); m.sent && due > 0 {
+				if wait == 0 || due < wait {
+					wait = due
+				}
+				continue
+			}
+			snap := *m.pending
+			m.pending = nil
+			if snap.hash == m.lastHash {
+				continue
+			}
+			if err := stream.Send(paneUpdate(id, snap, req.IncludeContent)); err != nil {
+				s.logger.Debug().Ctx(ctx).Err(err).Str("agent_id", id).Msg("failed to send workspace pane update")
+				return err
+			}
+			m.lastHash = snap.hash
+			m.lastSent = snap.mono
+			m.sent = true
+		}
+
+		var timer clock.Timer
+		var due <-chan time.Time
+		if wait > 0 {
+			timer = s.clock.NewTimer(wait)
+			due = timer.C()
+		}
+		select {
+		case <-ctx.Done():
+			s.logger.Debug().
+				Ctx(ctx).
+				Str("workspace_id", req.WorkspaceId).
+				Msg("workspace update stream ended (context done)")
+			err = ctx.Err()
+		case <-roster:
+			roster = nil
+		case <-wake:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
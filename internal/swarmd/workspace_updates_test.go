@@ -0,0 +1,152 @@
+package swarmd
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	swarmdv1 "github.com/opencode-ai/swarm/gen/swarmd/v1"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newWorkspaceTestServer(t *testing.T) (*Server, *tmuxtest.Server) {
+	t.Helper()
+	srv := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	return NewServer(zerolog.Nop(), WithTmuxClient(tmux.NewClient(srv))), srv
+}
+
+func spawnInWorkspace(t *testing.T, server *Server, agentID, workspaceID string) string {
+	t.Helper()
+	resp, err := server.SpawnAgent(context.Background(), &swarmdv1.SpawnAgentRequest{AgentId: agentID, WorkspaceId: workspaceID, Command: "claude"})
+	if err != nil {
+		t.Fatalf("SpawnAgent(%s) error = %v", agentID, err)
+	}
+	return resp.Agent.PaneId
+}
+
+// expectNoUpdate fails if stream sends anything within a few capture
+// intervals.
+func expectNoUpdate(t *testing.T, stream *paneUpdateChannel) {
+	t.Helper()
+	select {
+	case resp := <-stream.updates:
+		t.Fatalf("unexpected update for %s: %+v", resp.AgentId, resp)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestStreamWorkspaceUpdatesFollowsWorkspaceAgents(t *testing.T) {
+	server, srv := newWorkspaceTestServer(t)
+	chattyPane := spawnInWorkspace(t, server, "chatty", "ws-1")
+	spawnInWorkspace(t, server, "quiet", "ws-1")
+	spawnInWorkspace(t, server, "other", "ws-2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newPaneUpdateChannel(ctx)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.StreamWorkspaceUpdates(&swarmdv1.StreamWorkspaceUpdatesRequest{
+			WorkspaceId:    "ws-1",
+			IncludeContent: true,
+			MinInterval:    durationpb.New(5 * time.Millisecond),
+		}, stream)
+	}()
+
+	var first []string
+	for range 2 {
+		resp := stream.next(t)
+		if !strings.Contains(resp.Content, "claude> ") || resp.ContentHash == "" {
+			t.Fatalf("expected a pane update for %s, got %+v", resp.AgentId, resp)
+		}
+		first = append(first, resp.AgentId)
+	}
+	sort.Strings(first)
+	if strings.Join(first, ",") != "chatty,quiet" {
+		t.Fatalf("expected the workspace's agents, got %v", first)
+	}
+
+	// Only the pane that changed is sent again.
+	for _, line := range []string{"one", "two"} {
+		if err := srv.Print(chattyPane, "\n"+line); err != nil {
+			t.Fatalf("failed to print to pane: %v", err)
+		}
+		if resp := stream.next(t); resp.AgentId != "chatty" || !strings.Contains(resp.Content, line) {
+			t.Fatalf("expected chatty's %q, got %s with %q", line, resp.AgentId, resp.Content)
+		}
+	}
+	expectNoUpdate(t, stream)
+
+	// Agents spawned into the workspace join; others do not.
+	spawnInWorkspace(t, server, "other-2", "ws-2")
+	spawnInWorkspace(t, server, "late", "ws-1")
+	if resp := stream.next(t); resp.AgentId != "late" || resp.Removed {
+		t.Fatalf("expected late to join, got %+v", resp)
+	}
+
+	// Killed agents leave.
+	if _, err := server.KillAgent(ctx, &swarmdv1.KillAgentRequest{AgentId: "quiet", Force: true}); err != nil {
+		t.Fatalf("KillAgent() error = %v", err)
+	}
+	if resp := stream.next(t); resp.AgentId != "quiet" || !resp.Removed || resp.ContentHash != "" {
+		t.Fatalf("expected quiet to leave, got %+v", resp)
+	}
+	expectNoUpdate(t, stream)
+
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("StreamWorkspaceUpdates() error = %v", err)
+	}
+	if n := captureLoopCount(server); n != 0 {
+		t.Errorf("expected capture loops to stop with the stream, %d running", n)
+	}
+}
+
+func TestStreamWorkspaceUpdatesFiltersAndResumes(t *testing.T) {
+	server, srv := newWorkspaceTestServer(t)
+	seenPane := spawnInWorkspace(t, server, "seen", "ws-1")
+	spawnInWorkspace(t, server, "unseen", "ws-1")
+	spawnInWorkspace(t, server, "unlisted", "ws-1")
+	spawnInWorkspace(t, server, "elsewhere", "ws-2")
+
+	seenHash := captureHash(t, srv, seenPane)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newPaneUpdateChannel(ctx)
+	go func() {
+		_ = server.StreamWorkspaceUpdates(&swarmdv1.StreamWorkspaceUpdatesRequest{
+			AgentIds:        []string{"seen", "unseen", "elsewhere"},
+			LastKnownHashes: map[string]string{"seen": seenHash},
+			MinInterval:     durationpb.New(5 * time.Millisecond),
+		}, stream)
+	}()
+
+	var got []string
+	for range 2 {
+		resp := stream.next(t)
+		if resp.Content != "" {
+			t.Fatalf("expected no content unless requested, got %q", resp.Content)
+		}
+		got = append(got, resp.AgentId)
+	}
+	sort.Strings(got)
+	if strings.Join(got, ",") != "elsewhere,unseen" {
+		t.Fatalf("expected the listed agents not already seen, got %v", got)
+	}
+	expectNoUpdate(t, stream)
+
+	if err := srv.Print(seenPane, "\nnews"); err != nil {
+		t.Fatalf("failed to print to pane: %v", err)
+	}
+	if resp := stream.next(t); resp.AgentId != "seen" || resp.ContentHash == seenHash {
+		t.Fatalf("expected seen's change, got %+v", resp)
+	}
+}
@@ -0,0 +1,236 @@
+// Package task groups queue items into tasks and tracks their status as the
+// scheduler dispatches them.
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// Service errors.
+var (
+	ErrTaskNotFound = errors.New("task not found")
+	ErrNoItems      = errors.New("task requires at least one queue item")
+)
+
+// skippedReason is recorded on items that never ran because their task failed.
+const skippedReason = "task failed"
+
+// Service creates tasks and drives their status:
+//
+//	pending -> running    when the first item is dispatched
+//	running -> completed  when every item has been dispatched and the agent
+//	                      next reaches idle
+//	any     -> failed     when an item fails permanently
+type Service struct {
+	repo      *db.TaskRepository
+	queueRepo *db.QueueRepository
+	clock     clock.Clock
+	logger    zerolog.Logger
+
+	// mu serializes transitions so dispatch and idle notifications for the
+	// same task cannot interleave.
+	mu sync.Mutex
+}
+
+// Option configures a task Service.
+type Option func(*Service)
+
+// WithClock sets the time source used for task timestamps.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) {
+		s.clock = c
+	}
+}
+
+// NewService creates a new task Service.
+func NewService(repo *db.TaskRepository, queueRepo *db.QueueRepository, opts ...Option) *Service {
+	s := &Service{
+		repo:      repo,
+		queueRepo: queueRepo,
+		logger:    logging.Component("task"),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.clock = clock.OrReal(s.clock)
+	return s
+}
+
+// Create stores a task and appends its items to the agent's queue.
+func (s *Service) Create(ctx context.Context, task *models.Task, items ...*models.QueueItem) error {
+	if len(items) == 0 {
+		return ErrNoItems
+	}
+	if err := s.repo.Create(ctx, task, items...); err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+	s.logger.Info().Str("task_id", task.ID).Str("agent_id", task.AgentID).Int("items", len(items)).Msg("task created")
+	return nil
+}
+
+// Get returns a task by ID.
+func (s *Service) Get(ctx context.Context, id string) (*models.Task, error) {
+	task, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrTaskNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	return task, nil
+}
+
+// List returns tasks matching filter, newest first.
+func (s *Service) List(ctx context.Context, filter db.TaskFilter) ([]*models.Task, error) {
+	tasks, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// Items returns a task's queue items in queue order.
+func (s *Service) Items(ctx context.Context, id string) ([]*models.QueueItem, error) {
+	items, err := s.queueRepo.ListByTask(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task items: %w", err)
+	}
+	return items, nil
+}
+
+// ItemDispatched records that item was dispatched successfully, starting
+// its task if this was the first item.
+func (s *Service) ItemDispatched(ctx context.Context, item *models.QueueItem) error {
+	if item == nil || item.TaskID == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.Get(ctx, item.TaskID)
+	if err != nil {
+		return err
+	}
+	if task.Status != models.TaskStatusPending {
+		return nil
+	}
+
+	now := s.clock.Now().UTC()
+	task.Status = models.TaskStatusRunning
+	task.StartedAt = &now
+	if err := s.repo.Update(ctx, task); err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	s.logger.Info().Str("task_id", task.ID).Str("agent_id", task.AgentID).Msg("task running")
+	return nil
+}
+
+// ItemFailed fails item's task after the item exhausted its retries or was
+// skipped as blocked. The task's remaining pending items are skipped.
+func (s *Service) ItemFailed(ctx context.Context, item *models.QueueItem, reason string) error {
+	if item == nil || item.TaskID == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.Get(ctx, item.TaskID)
+	if err != nil {
+		return err
+	}
+	if task.Status.IsTerminal() {
+		return nil
+	}
+
+	now := s.clock.Now().UTC()
+	task.Status = models.TaskStatusFailed
+	task.Error = fmt.Sprintf("item %s failed: %s", item.ID, reason)
+	task.CompletedAt = &now
+	if err := s.repo.Update(ctx, task); err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+
+	skipped, err := s.queueRepo.SkipPendingByTask(ctx, task.ID, skippedReason)
+	if err != nil {
+		return fmt.Errorf("failed to skip remaining task items: %w", err)
+	}
+	s.logger.Warn().
+		Str("task_id", task.ID).
+		Str("agent_id", task.AgentID).
+		Str("item_id", item.ID).
+		Int("skipped", skipped).
+		Msg("task failed")
+	return nil
+}
+
+// AgentIdle completes the agent's running tasks whose items have all been
+// dispatched before at, the time the agent was observed idle.
+func (s *Service) AgentIdle(ctx context.Context, agentID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	running, err := s.List(ctx, db.TaskFilter{AgentID: agentID, Status: models.TaskStatusRunning})
+	if err != nil {
+		return err
+	}
+
+	for _, task := range running {
+		items, err := s.Items(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		lastDispatch, done := lastDispatchTime(items)
+		if !done || at.Before(lastDispatch) {
+			continue
+		}
+
+		now := s.clock.Now().UTC()
+		task.Status = models.TaskStatusCompleted
+		task.CompletedAt = &now
+		if err := s.repo.Update(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+		for _, item := range items {
+			if item.Status != models.QueueItemStatusDispatched {
+				continue
+			}
+			if err := s.queueRepo.UpdateStatus(ctx, item.ID, models.QueueItemStatusCompleted, ""); err != nil {
+				return fmt.Errorf("failed to complete task item: %w", err)
+			}
+		}
+		s.logger.Info().Str("task_id", task.ID).Str("agent_id", agentID).Msg("task completed")
+	}
+	return nil
+}
+
+// lastDispatchTime returns when the last of items was dispatched, and
+// whether every item was dispatched. An item skipped because its condition
+// never held was blocked, not done, so it keeps the task from completing;
+// the scheduler fails the task when it skips the item.
+func lastDispatchTime(items []*models.QueueItem) (time.Time, bool) {
+	var last time.Time
+	for _, item := range items {
+		switch item.Status {
+		case models.QueueItemStatusPending, models.QueueItemStatusInFlight, models.QueueItemStatusFailed:
+			return time.Time{}, false
+		case models.QueueItemStatusSkipped:
+			return time.Time{}, false
+		}
+		if item.DispatchedAt != nil && item.DispatchedAt.After(last) {
+			last = *item.DispatchedAt
+		}
+	}
+	return last, len(items) > 0
+}
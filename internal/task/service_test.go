@@ -0,0 +1,273 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+type testEnv struct {
+	service   *Service
+	queueRepo *db.QueueRepository
+	agent     *models.Agent
+}
+
+func setupTestService(t *testing.T) *testEnv {
+	t.Helper()
+
+	testDB, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { testDB.Close() })
+
+	ctx := context.Background()
+	if err := testDB.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	node := &models.Node{Name: "test-node", SSHBackend: models.SSHBackendAuto, Status: models.NodeStatusUnknown, IsLocal: true}
+	if err := db.NewNodeRepository(testDB).Create(ctx, node); err != nil {
+		t.Fatalf("create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, RepoPath: "/tmp/swarm-test", TmuxSession: "swarm-test"}
+	if err := db.NewWorkspaceRepository(testDB).Create(ctx, ws); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	agent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "swarm-test:0.1", State: models.AgentStateIdle}
+	if err := db.NewAgentRepository(testDB).Create(ctx, agent); err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	queueRepo := db.NewQueueRepository(testDB)
+	return &testEnv{
+		service:   NewService(db.NewTaskRepository(testDB), queueRepo),
+		queueRepo: queueRepo,
+		agent:     agent,
+	}
+}
+
+func (e *testEnv) createTask(t *testing.T, texts ...string) *models.Task {
+	t.Helper()
+
+	items := make([]*models.QueueItem, 0, len(texts))
+	for _, text := range texts {
+		payload, err := json.Marshal(models.MessagePayload{Text: text})
+		if err != nil {
+			t.Fatalf("marshal payload: %v", err)
+		}
+		items = append(items, &models.QueueItem{Type: models.QueueItemTypeMessage, Payload: payload})
+	}
+
+	task := &models.Task{AgentID: e.agent.ID, WorkspaceID: e.agent.WorkspaceID, Title: "test task"}
+	if err := e.service.Create(context.Background(), task, items...); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	return task
+}
+
+// dispatch dequeues the agent's next item, records it dispatched, and
+// reports it, as the scheduler does after a successful send.
+func (e *testEnv) dispatch(t *testing.T) *models.QueueItem {
+	t.Helper()
+
+	ctx := context.Background()
+	item, err := e.queueRepo.Dequeue(ctx, e.agent.ID, "test")
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if _, err := e.queueRepo.ReleaseInFlight(ctx, item.ID, models.QueueItemStatusDispatched, item.Attempts, ""); err != nil {
+		t.Fatalf("ReleaseInFlight failed: %v", err)
+	}
+	item.Status = models.QueueItemStatusDispatched
+	if err := e.service.ItemDispatched(ctx, item); err != nil {
+		t.Fatalf("ItemDispatched failed: %v", err)
+	}
+	return item
+}
+
+func (e *testEnv) requireStatus(t *testing.T, id string, want models.TaskStatus) *models.Task {
+	t.Helper()
+
+	task, err := e.service.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if task.Status != want {
+		t.Fatalf("expected task status %s, got %s", want, task.Status)
+	}
+	return task
+}
+
+func TestService_CompletesAfterLastItemAndIdle(t *testing.T) {
+	env := setupTestService(t)
+	ctx := context.Background()
+
+	task := env.createTask(t, "one", "two")
+	env.requireStatus(t, task.ID, models.TaskStatusPending)
+
+	env.dispatch(t)
+	running := env.requireStatus(t, task.ID, models.TaskStatusRunning)
+	if running.StartedAt == nil {
+		t.Fatal("expected started_at on running task")
+	}
+
+	// Idle between items does not finish the task.
+	if err := env.service.AgentIdle(ctx, env.agent.ID, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("AgentIdle failed: %v", err)
+	}
+	env.requireStatus(t, task.ID, models.TaskStatusRunning)
+
+	last := env.dispatch(t)
+
+	// An idle observation from before the last dispatch is stale.
+	if err := env.service.AgentIdle(ctx, env.agent.ID, last.DispatchedAt.Add(-time.Second)); err != nil {
+		t.Fatalf("AgentIdle failed: %v", err)
+	}
+	env.requireStatus(t, task.ID, models.TaskStatusRunning)
+
+	if err := env.service.AgentIdle(ctx, env.agent.ID, last.DispatchedAt.Add(time.Second)); err != nil {
+		t.Fatalf("AgentIdle failed: %v", err)
+	}
+	done := env.requireStatus(t, task.ID, models.TaskStatusCompleted)
+	if done.CompletedAt == nil {
+		t.Fatal("expected completed_at on completed task")
+	}
+
+	items, err := env.service.Items(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("Items failed: %v", err)
+	}
+	for _, item := range items {
+		if item.Status != models.QueueItemStatusCompleted || item.CompletedAt == nil {
+			t.Fatalf("expected completed item, got %s", item.Status)
+		}
+	}
+}
+
+func TestService_FailsMidTaskAndSkipsRemainingItems(t *testing.T) {
+	env := setupTestService(t)
+	ctx := context.Background()
+
+	task := env.createTask(t, "one", "two", "three")
+	env.dispatch(t)
+
+	failedItem, err := env.queueRepo.Dequeue(ctx, env.agent.ID, "test")
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if err := env.queueRepo.UpdateStatus(ctx, failedItem.ID, models.QueueItemStatusFailed, "pane gone"); err != nil {
+		t.Fatalf("UpdateStatus failed: %v", err)
+	}
+	if err := env.service.ItemFailed(ctx, failedItem, "pane gone"); err != nil {
+		t.Fatalf("ItemFailed failed: %v", err)
+	}
+
+	failed := env.requireStatus(t, task.ID, models.TaskStatusFailed)
+	if failed.CompletedAt == nil || failed.Error == "" {
+		t.Fatalf("expected failure details, got %+v", failed)
+	}
+
+	items, err := env.service.Items(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("Items failed: %v", err)
+	}
+	want := []models.QueueItemStatus{models.QueueItemStatusDispatched, models.QueueItemStatusFailed, models.QueueItemStatusSkipped}
+	for i, item := range items {
+		if item.Status != want[i] {
+			t.Fatalf("item %d: expected %s, got %s", i, want[i], item.Status)
+		}
+	}
+	if _, err := env.queueRepo.Dequeue(ctx, env.agent.ID, "test"); !errors.Is(err, db.ErrQueueEmpty) {
+		t.Fatalf("expected skipped items to leave the queue, got %v", err)
+	}
+
+	// A failed task stays failed.
+	if err := env.service.AgentIdle(ctx, env.agent.ID, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("AgentIdle failed: %v", err)
+	}
+	if err := env.service.ItemDispatched(ctx, items[0]); err != nil {
+		t.Fatalf("ItemDispatched failed: %v", err)
+	}
+	env.requireStatus(t, task.ID, models.TaskStatusFailed)
+}
+
+func TestService_BlockedItemDoesNotComplete(t *testing.T) {
+	env := setupTestService(t)
+	ctx := context.Background()
+
+	task := env.createTask(t, "one", "two")
+	env.dispatch(t)
+
+	blocked, err := env.queueRepo.Dequeue(ctx, env.agent.ID, "test")
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if err := env.queueRepo.UpdateStatus(ctx, blocked.ID, models.QueueItemStatusSkipped, "max evaluations exceeded"); err != nil {
+		t.Fatalf("UpdateStatus failed: %v", err)
+	}
+
+	// Idle after the last dispatch does not complete a task with a blocked item.
+	if err := env.service.AgentIdle(ctx, env.agent.ID, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("AgentIdle failed: %v", err)
+	}
+	env.requireStatus(t, task.ID, models.TaskStatusRunning)
+
+	if err := env.service.ItemFailed(ctx, blocked, "condition not met after max evaluations"); err != nil {
+		t.Fatalf("ItemFailed failed: %v", err)
+	}
+	env.requireStatus(t, task.ID, models.TaskStatusFailed)
+}
+
+func TestService_FailsBeforeStarting(t *testing.T) {
+	env := setupTestService(t)
+	ctx := context.Background()
+
+	task := env.createTask(t, "one", "two")
+	item, err := env.queueRepo.Dequeue(ctx, env.agent.ID, "test")
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if err := env.service.ItemFailed(ctx, item, "send failed"); err != nil {
+		t.Fatalf("ItemFailed failed: %v", err)
+	}
+
+	failed := env.requireStatus(t, task.ID, models.TaskStatusFailed)
+	if failed.StartedAt != nil {
+		t.Fatalf("expected unstarted task, got started_at %v", failed.StartedAt)
+	}
+}
+
+func TestService_IgnoresItemsWithoutTask(t *testing.T) {
+	env := setupTestService(t)
+	ctx := context.Background()
+
+	item := &models.QueueItem{ID: "loose", AgentID: env.agent.ID}
+	if err := env.service.ItemDispatched(ctx, item); err != nil {
+		t.Fatalf("ItemDispatched failed: %v", err)
+	}
+	if err := env.service.ItemFailed(ctx, item, "boom"); err != nil {
+		t.Fatalf("ItemFailed failed: %v", err)
+	}
+	if err := env.service.AgentIdle(ctx, env.agent.ID, time.Now()); err != nil {
+		t.Fatalf("AgentIdle failed: %v", err)
+	}
+}
+
+func TestService_CreateAndGetErrors(t *testing.T) {
+	env := setupTestService(t)
+	ctx := context.Background()
+
+	task := &models.Task{AgentID: env.agent.ID, WorkspaceID: env.agent.WorkspaceID, Title: "empty"}
+	if err := env.service.Create(ctx, task); !errors.Is(err, ErrNoItems) {
+		t.Fatalf("expected ErrNoItems, got %v", err)
+	}
+	if _, err := env.service.Get(ctx, "missing"); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+}
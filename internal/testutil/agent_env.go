@@ -240,6 +240,7 @@ func (e *AgentTestEnv) CreateTestAgent(ctx context.Context, pane string) (*model
 	agentModel := &models.Agent{
 		WorkspaceID: e.TestWorkspace.ID,
 		TmuxPane:    pane,
+		TmuxSession: e.TestWorkspace.TmuxSession,
 		Type:        models.AgentType("generic"),
 		State:       models.AgentStateIdle,
 		StateInfo: models.StateInfo{
@@ -262,25 +263,11 @@ func (e *AgentTestEnv) CreateTestAgent(ctx context.Context, pane string) (*model
 func (e *AgentTestEnv) SubscribeStateChanges(id string) (<-chan state.StateChange, func()) {
 	e.t.Helper()
 
-	ch := make(chan state.StateChange, 10)
-
-	err := e.StateEngine.SubscribeFunc(id, func(change state.StateChange) {
-		select {
-		case ch <- change:
-		default:
-			// Channel full, drop oldest
-			select {
-			case <-ch:
-				ch <- change
-			default:
-			}
-		}
-	})
+	ch, err := e.StateEngine.SubscribeChan(id)
 	require.NoError(e.t, err, "failed to subscribe to state changes")
 
 	unsubscribe := func() {
 		_ = e.StateEngine.Unsubscribe(id)
-		close(ch)
 	}
 
 	return ch, unsubscribe
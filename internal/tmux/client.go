@@ -8,6 +8,9 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/shell"
 )
 
 // Executor runs tmux commands.
@@ -60,11 +63,14 @@ func NewLocalClient() *Client {
 type Session struct {
 	Name        string
 	WindowCount int
+	// Activity is when the session last saw activity, from tmux's
+	// session_activity. It is zero when tmux did not report it.
+	Activity time.Time
 }
 
 // ListSessions returns all known tmux sessions.
 func (c *Client) ListSessions(ctx context.Context) ([]Session, error) {
-	stdout, stderr, err := c.exec.Exec(ctx, "tmux list-sessions -F '#{session_name}|#{session_windows}'")
+	stdout, stderr, err := c.exec.Exec(ctx, "tmux list-sessions -F '#{session_name}|#{session_windows}|#{session_activity}'")
 	if err != nil {
 		if isNoServerRunning(stderr) {
 			return []Session{}, nil
@@ -81,8 +87,8 @@ func (c *Client) ListSessions(ctx context.Context) ([]Session, error) {
 	sessions := make([]Session, 0, len(lines))
 
 	for _, line := range lines {
-		parts := strings.SplitN(line, "|", 2)
-		if len(parts) != 2 {
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) < 2 {
 			return nil, fmt.Errorf("unexpected tmux output line: %q", line)
 		}
 
@@ -91,10 +97,18 @@ func (c *Client) ListSessions(ctx context.Context) ([]Session, error) {
 			return nil, fmt.Errorf("invalid window count in tmux output: %q", line)
 		}
 
-		sessions = append(sessions, Session{
+		session := Session{
 			Name:        strings.TrimSpace(parts[0]),
 			WindowCount: count,
-		})
+		}
+		if len(parts) == 3 && strings.TrimSpace(parts[2]) != "" {
+			activity, err := strconv.ParseInt(strings.TrimSpace(parts[2]), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid session activity in tmux output: %q", line)
+			}
+			session.Activity = time.Unix(activity, 0)
+		}
+		sessions = append(sessions, session)
 	}
 
 	return sessions, nil
@@ -171,7 +185,7 @@ func (c *Client) NewSession(ctx context.Context, session, workDir string) error
 
 	cmd := fmt.Sprintf("tmux new-session -d -s %s", escapeSessionName(session))
 	if workDir != "" {
-		cmd = fmt.Sprintf("%s -c %s", cmd, escapeArg(workDir))
+		cmd = fmt.Sprintf("%s -c %s", cmd, shell.Quote(workDir))
 	}
 
 	_, stderr, err := c.exec.Exec(ctx, cmd)
@@ -223,10 +237,10 @@ func (c *Client) NewWindow(ctx context.Context, session, name, workDir string) e
 
 	cmd := fmt.Sprintf("tmux new-window -t %s", escapeSessionName(session))
 	if strings.TrimSpace(name) != "" {
-		cmd = fmt.Sprintf("%s -n %s", cmd, escapeArg(name))
+		cmd = fmt.Sprintf("%s -n %s", cmd, shell.Quote(name))
 	}
 	if strings.TrimSpace(workDir) != "" {
-		cmd = fmt.Sprintf("%s -c %s", cmd, escapeArg(workDir))
+		cmd = fmt.Sprintf("%s -c %s", cmd, shell.Quote(workDir))
 	}
 
 	if _, _, err := c.exec.Exec(ctx, cmd); err != nil {
@@ -301,7 +315,7 @@ func (c *Client) SelectWindow(ctx context.Context, target string) error {
 		return fmt.Errorf("target is required")
 	}
 
-	cmd := fmt.Sprintf("tmux select-window -t %s", escapeArg(target))
+	cmd := fmt.Sprintf("tmux select-window -t %s", shell.Quote(target))
 	if _, _, err := c.exec.Exec(ctx, cmd); err != nil {
 		return fmt.Errorf("tmux select-window failed: %w", err)
 	}
@@ -310,6 +324,7 @@ func (c *Client) SelectWindow(ctx context.Context, target string) error {
 }
 
 // SelectLayout applies a tmux layout preset to a target.
+// Returns ErrPaneNotFound if nothing matches the target.
 func (c *Client) SelectLayout(ctx context.Context, target string, layout string) error {
 	if strings.TrimSpace(target) == "" {
 		return fmt.Errorf("target is required")
@@ -318,8 +333,12 @@ func (c *Client) SelectLayout(ctx context.Context, target string, layout string)
 		return fmt.Errorf("layout is required")
 	}
 
-	cmd := fmt.Sprintf("tmux select-layout -t %s %s", escapeArg(target), escapeArg(layout))
-	if _, _, err := c.exec.Exec(ctx, cmd); err != nil {
+	cmd := fmt.Sprintf("tmux select-layout -t %s %s", shell.Quote(target), shell.Quote(layout))
+	_, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isTargetGone(stderr) {
+			return ErrPaneNotFound
+		}
 		return fmt.Errorf("tmux select-layout failed: %w", err)
 	}
 
@@ -360,6 +379,32 @@ func (c *Client) KillSessionIfExists(ctx context.Context, session string) error
 	return err
 }
 
+// RenameSession renames a tmux session.
+// Returns ErrSessionNotFound if the session doesn't exist and ErrSessionExists
+// if the new name is already taken.
+func (c *Client) RenameSession(ctx context.Context, session, newName string) error {
+	if strings.TrimSpace(session) == "" {
+		return fmt.Errorf("session name is required")
+	}
+	if err := ValidateSessionName(newName); err != nil {
+		return err
+	}
+
+	cmd := fmt.Sprintf("tmux rename-session -t %s %s", escapeSessionName(session), shell.Quote(newName))
+	_, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isNoServerRunning(stderr) || isSessionNotFound(stderr) {
+			return ErrSessionNotFound
+		}
+		if isDuplicateSession(stderr) {
+			return ErrSessionExists
+		}
+		return fmt.Errorf("tmux rename-session failed: %w", err)
+	}
+
+	return nil
+}
+
 // Pane describes a tmux pane.
 type Pane struct {
 	ID          string // e.g., "%1"
@@ -368,15 +413,26 @@ type Pane struct {
 	CurrentDir  string
 	Command     string
 	Active      bool
+	Zoomed      bool // the pane's window is zoomed
 }
 
 // ListPanes returns all panes in a session.
 func (c *Client) ListPanes(ctx context.Context, session string) ([]Pane, error) {
+	return c.listPanes(ctx, session, "")
+}
+
+// ListSessionPanes returns the panes of every window in a session, where
+// ListPanes covers only the session's current window.
+func (c *Client) ListSessionPanes(ctx context.Context, session string) ([]Pane, error) {
+	return c.listPanes(ctx, session, " -s")
+}
+
+func (c *Client) listPanes(ctx context.Context, session, scope string) ([]Pane, error) {
 	if strings.TrimSpace(session) == "" {
 		return nil, fmt.Errorf("session name is required")
 	}
 
-	cmd := fmt.Sprintf("tmux list-panes -t %s -F '#{pane_id}|#{window_index}|#{pane_index}|#{pane_current_path}|#{pane_active}|#{pane_current_command}'", escapeSessionName(session))
+	cmd := fmt.Sprintf("tmux list-panes -t %s%s -F '#{pane_id}|#{window_index}|#{pane_index}|#{pane_current_path}|#{pane_active}|#{window_zoomed_flag}|#{pane_current_command}'", escapeSessionName(session), scope)
 	stdout, stderr, err := c.exec.Exec(ctx, cmd)
 	if err != nil {
 		if isNoServerRunning(stderr) {
@@ -392,8 +448,8 @@ func (c *Client) ListPanes(ctx context.Context, session string) ([]Pane, error)
 
 	var panes []Pane
 	for _, line := range strings.Split(output, "\n") {
-		parts := strings.SplitN(line, "|", 6)
-		if len(parts) != 6 {
+		parts := strings.SplitN(line, "|", 7)
+		if len(parts) != 7 {
 			return nil, fmt.Errorf("unexpected tmux output line: %q", line)
 		}
 
@@ -405,7 +461,8 @@ func (c *Client) ListPanes(ctx context.Context, session string) ([]Pane, error)
 			Index:       index,
 			CurrentDir:  strings.TrimSpace(parts[3]),
 			Active:      strings.TrimSpace(parts[4]) == "1",
-			Command:     strings.TrimSpace(parts[5]),
+			Zoomed:      strings.TrimSpace(parts[5]) == "1",
+			Command:     strings.TrimSpace(parts[6]),
 		})
 	}
 
@@ -426,9 +483,9 @@ func (c *Client) SplitWindow(ctx context.Context, target string, horizontal bool
 	}
 
 	// Use -P -F to print the new pane ID
-	cmd := fmt.Sprintf("tmux split-window %s -t %s -P -F '#{pane_id}'", splitFlag, escapeArg(target))
+	cmd := fmt.Sprintf("tmux split-window %s -t %s -P -F '#{pane_id}'", splitFlag, shell.Quote(target))
 	if workDir != "" {
-		cmd = fmt.Sprintf("%s -c %s", cmd, escapeArg(workDir))
+		cmd = fmt.Sprintf("%s -c %s", cmd, shell.Quote(workDir))
 	}
 
 	stdout, _, err := c.exec.Exec(ctx, cmd)
@@ -439,6 +496,46 @@ func (c *Client) SplitWindow(ctx context.Context, target string, horizontal bool
 	return strings.TrimSpace(string(stdout)), nil
 }
 
+// MovePane moves the source pane into the target window, keeping its pane
+// ID and running process. The target window's active pane is unchanged.
+func (c *Client) MovePane(ctx context.Context, source, target string) error {
+	if strings.TrimSpace(source) == "" || strings.TrimSpace(target) == "" {
+		return fmt.Errorf("source and target are required")
+	}
+
+	cmd := fmt.Sprintf("tmux move-pane -d -s %s -t %s", shell.Quote(source), shell.Quote(target))
+	_, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isPaneNotFound(stderr) {
+			return ErrPaneNotFound
+		}
+		return fmt.Errorf("tmux move-pane failed: %w", err)
+	}
+	return nil
+}
+
+// JoinPane joins the source pane to the target pane's window, splitting
+// left-right if horizontal is true and top-bottom otherwise.
+func (c *Client) JoinPane(ctx context.Context, source, target string, horizontal bool) error {
+	if strings.TrimSpace(source) == "" || strings.TrimSpace(target) == "" {
+		return fmt.Errorf("source and target are required")
+	}
+
+	splitFlag := "-v"
+	if horizontal {
+		splitFlag = "-h"
+	}
+	cmd := fmt.Sprintf("tmux join-pane -d %s -s %s -t %s", splitFlag, shell.Quote(source), shell.Quote(target))
+	_, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isPaneNotFound(stderr) {
+			return ErrPaneNotFound
+		}
+		return fmt.Errorf("tmux join-pane failed: %w", err)
+	}
+	return nil
+}
+
 // SendKeys sends keys to a tmux pane.
 // If literal is true, sends keys literally (no translation).
 // If enter is true, appends an Enter keypress.
@@ -447,21 +544,20 @@ func (c *Client) SendKeys(ctx context.Context, target, keys string, literal, ent
 		return fmt.Errorf("target is required")
 	}
 
-	literalFlag := ""
+	flags := "--"
 	if literal {
-		literalFlag = "-l"
+		flags = "-l --"
 	}
 
-	// Escape the keys for shell
-	escapedKeys := escapeArg(keys)
-	cmd := fmt.Sprintf("tmux send-keys -t %s %s %s", escapeArg(target), literalFlag, escapedKeys)
+	// "--" keeps text starting with "-" from being read as flags.
+	cmd := fmt.Sprintf("tmux send-keys -t %s %s %s", shell.Quote(target), flags, sendKeysArg(keys))
 
 	if _, _, err := c.exec.Exec(ctx, cmd); err != nil {
 		return fmt.Errorf("tmux send-keys failed: %w", err)
 	}
 
 	if enter {
-		enterCmd := fmt.Sprintf("tmux send-keys -t %s Enter", escapeArg(target))
+		enterCmd := fmt.Sprintf("tmux send-keys -t %s Enter", shell.Quote(target))
 		if _, _, err := c.exec.Exec(ctx, enterCmd); err != nil {
 			return fmt.Errorf("tmux send-keys Enter failed: %w", err)
 		}
@@ -476,7 +572,7 @@ func (c *Client) SendInterrupt(ctx context.Context, target string) error {
 		return fmt.Errorf("target is required")
 	}
 
-	cmd := fmt.Sprintf("tmux send-keys -t %s C-c", escapeArg(target))
+	cmd := fmt.Sprintf("tmux send-keys -t %s C-c", shell.Quote(target))
 	if _, _, err := c.exec.Exec(ctx, cmd); err != nil {
 		return fmt.Errorf("tmux send-keys C-c failed: %w", err)
 	}
@@ -537,13 +633,38 @@ func (c *Client) CapturePane(ctx context.Context, target string, history bool) (
 	return c.capturePaneHistory(ctx, target)
 }
 
+// CapturePaneRange captures the last lines of a pane, reaching back into
+// scrollback when the visible area holds fewer. Trailing blank lines are not
+// counted, and lines is capped at the history limit. It reports whether
+// older lines were left out.
+func (c *Client) CapturePaneRange(ctx context.Context, target string, lines int) (string, bool, error) {
+	if strings.TrimSpace(target) == "" {
+		return "", false, fmt.Errorf("target is required")
+	}
+	if lines <= 0 {
+		return "", false, fmt.Errorf("lines must be positive, got %d", lines)
+	}
+	if lines > historyMaxLines {
+		lines = historyMaxLines
+	}
+
+	// -S -N starts N lines above the visible area, which always covers the
+	// last N lines; the visible rows below them are trimmed off here.
+	content, err := c.capturePaneRange(ctx, target, strconv.Itoa(-lines), "")
+	if err != nil {
+		return "", false, err
+	}
+	trimmed, truncated := LastLines(content, lines)
+	return trimmed, truncated, nil
+}
+
 // HistorySize reports the number of history lines for a pane.
 func (c *Client) HistorySize(ctx context.Context, target string) (int, error) {
 	if strings.TrimSpace(target) == "" {
 		return 0, fmt.Errorf("target is required")
 	}
 
-	cmd := fmt.Sprintf("tmux display-message -p -t %s '#{history_size}'", escapeArg(target))
+	cmd := fmt.Sprintf("tmux display-message -p -t %s '#{history_size}'", shell.Quote(target))
 	stdout, _, err := c.exec.Exec(ctx, cmd)
 	if err != nil {
 		return 0, fmt.Errorf("tmux display-message failed: %w", err)
@@ -619,7 +740,7 @@ func (c *Client) capturePaneAll(ctx context.Context, target string) (string, err
 }
 
 func (c *Client) capturePaneRange(ctx context.Context, target, start, end string) (string, error) {
-	cmd := fmt.Sprintf("tmux capture-pane -t %s -p", escapeArg(target))
+	cmd := fmt.Sprintf("tmux capture-pane -t %s -p", shell.Quote(target))
 	if strings.TrimSpace(start) != "" {
 		cmd = fmt.Sprintf("%s -S %s", cmd, start)
 	}
@@ -641,7 +762,7 @@ func (c *Client) GetPanePID(ctx context.Context, target string) (int, error) {
 		return 0, fmt.Errorf("target is required")
 	}
 
-	cmd := fmt.Sprintf("tmux display-message -p -t %s '#{pane_pid}'", escapeArg(target))
+	cmd := fmt.Sprintf("tmux display-message -p -t %s '#{pane_pid}'", shell.Quote(target))
 	stdout, _, err := c.exec.Exec(ctx, cmd)
 	if err != nil {
 		return 0, fmt.Errorf("tmux display-message failed: %w", err)
@@ -658,6 +779,79 @@ func (c *Client) GetPanePID(ctx context.Context, target string) (int, error) {
 	return pid, nil
 }
 
+// PaneSize returns the width and height of a pane in cells.
+func (c *Client) PaneSize(ctx context.Context, target string) (width, height int, err error) {
+	if strings.TrimSpace(target) == "" {
+		return 0, 0, fmt.Errorf("target is required")
+	}
+
+	cmd := fmt.Sprintf("tmux display-message -p -t %s '#{pane_width} #{pane_height}'", shell.Quote(target))
+	stdout, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isPaneNotFound(stderr) {
+			return 0, 0, ErrPaneNotFound
+		}
+		return 0, 0, fmt.Errorf("tmux display-message failed: %w", err)
+	}
+
+	raw := strings.TrimSpace(string(stdout))
+	if _, err := fmt.Sscanf(raw, "%d %d", &width, &height); err != nil {
+		return 0, 0, fmt.Errorf("invalid pane size %q: %w", raw, err)
+	}
+	return width, height, nil
+}
+
+// ResizePane sets a pane's size in cells. A zero width or height leaves
+// that dimension as it is; tmux resizes the neighbouring panes to fit.
+// Returns ErrPaneNotFound if the pane doesn't exist.
+func (c *Client) ResizePane(ctx context.Context, target string, width, height int) error {
+	if strings.TrimSpace(target) == "" {
+		return fmt.Errorf("target is required")
+	}
+	if width < 0 || height < 0 || (width == 0 && height == 0) {
+		return fmt.Errorf("invalid pane size %dx%d", width, height)
+	}
+
+	cmd := fmt.Sprintf("tmux resize-pane -t %s", shell.Quote(target))
+	if width > 0 {
+		cmd += fmt.Sprintf(" -x %d", width)
+	}
+	if height > 0 {
+		cmd += fmt.Sprintf(" -y %d", height)
+	}
+	_, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isPaneNotFound(stderr) {
+			return ErrPaneNotFound
+		}
+		return fmt.Errorf("tmux resize-pane failed: %w", err)
+	}
+
+	return nil
+}
+
+// PipePane pipes everything the pane prints to the stdin of a shell
+// command, replacing any existing pipe. An empty command stops piping.
+func (c *Client) PipePane(ctx context.Context, target, command string) error {
+	if strings.TrimSpace(target) == "" {
+		return fmt.Errorf("target is required")
+	}
+
+	cmd := fmt.Sprintf("tmux pipe-pane -t %s", shell.Quote(target))
+	if strings.TrimSpace(command) != "" {
+		cmd = fmt.Sprintf("tmux pipe-pane -O -t %s %s", shell.Quote(target), shell.Quote(command))
+	}
+	_, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isPaneNotFound(stderr) {
+			return ErrPaneNotFound
+		}
+		return fmt.Errorf("tmux pipe-pane failed: %w", err)
+	}
+
+	return nil
+}
+
 // KillPane kills a specific pane.
 // Returns ErrPaneNotFound if the pane doesn't exist.
 func (c *Client) KillPane(ctx context.Context, target string) error {
@@ -665,7 +859,7 @@ func (c *Client) KillPane(ctx context.Context, target string) error {
 		return fmt.Errorf("target is required")
 	}
 
-	cmd := fmt.Sprintf("tmux kill-pane -t %s", escapeArg(target))
+	cmd := fmt.Sprintf("tmux kill-pane -t %s", shell.Quote(target))
 	_, stderr, err := c.exec.Exec(ctx, cmd)
 	if err != nil {
 		if isPaneNotFound(stderr) {
@@ -692,13 +886,176 @@ func (c *Client) KillPaneIfExists(ctx context.Context, target string) error {
 	return err
 }
 
+// KillWindow kills the window containing target, with every pane in it.
+func (c *Client) KillWindow(ctx context.Context, target string) error {
+	if strings.TrimSpace(target) == "" {
+		return fmt.Errorf("target is required")
+	}
+
+	cmd := fmt.Sprintf("tmux kill-window -t %s", shell.Quote(target))
+	_, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isTargetGone(stderr) {
+			return ErrPaneNotFound
+		}
+		return fmt.Errorf("tmux kill-window failed: %w", err)
+	}
+
+	return nil
+}
+
+// PaneExists reports whether target resolves to a live pane. A missing
+// session, window, or tmux server counts as the pane not existing.
+func (c *Client) PaneExists(ctx context.Context, target string) (bool, error) {
+	if _, err := c.resolvePaneID(ctx, target); err != nil {
+		if err == ErrPaneNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// KillPaneVerified kills a pane and waits briefly for it to disappear,
+// returning ErrPaneStillAlive if it does not. A pane that is already gone
+// is not an error. With force, a surviving pane's process is killed with
+// SIGKILL and, failing that, its window is killed if the pane is alone in
+// it; a window shared with other panes is left alone.
+//
+// The pane is tracked by its tmux pane ID throughout, so a positional
+// target like "session:0.1" that renumbers after the kill is never
+// mistaken for the original pane.
+func (c *Client) KillPaneVerified(ctx context.Context, target string, force bool) error {
+	paneID, err := c.resolvePaneID(ctx, target)
+	if err != nil {
+		if err == ErrPaneNotFound {
+			return nil
+		}
+		return err
+	}
+	pid, _ := c.GetPanePID(ctx, paneID)
+
+	killErr := c.KillPane(ctx, paneID)
+	if killErr == ErrPaneNotFound {
+		return nil
+	}
+	if gone, err := c.waitPaneGone(ctx, paneID); err != nil || gone {
+		return err
+	}
+	if !force {
+		return paneStillAliveError(target, killErr)
+	}
+
+	if pid > 0 {
+		_, _, _ = c.exec.Exec(ctx, fmt.Sprintf("kill -KILL %d", pid))
+		_ = c.KillPane(ctx, paneID)
+		if gone, err := c.waitPaneGone(ctx, paneID); err != nil || gone {
+			return err
+		}
+	}
+
+	panes, err := c.windowPaneCount(ctx, paneID)
+	if err == ErrPaneNotFound {
+		return nil
+	}
+	if err != nil || panes != 1 {
+		return paneStillAliveError(target, killErr)
+	}
+	windowErr := c.KillWindow(ctx, paneID)
+	if windowErr == ErrPaneNotFound {
+		return nil
+	}
+	if gone, err := c.waitPaneGone(ctx, paneID); err != nil || gone {
+		return err
+	}
+	if windowErr != nil {
+		killErr = windowErr
+	}
+	return paneStillAliveError(target, killErr)
+}
+
+// Pane kill verification timing. The pane normally disappears as soon as
+// kill-pane returns; the retry covers a slow tmux server.
+var (
+	paneKillWait = 500 * time.Millisecond
+	paneKillPoll = 50 * time.Millisecond
+)
+
+func (c *Client) waitPaneGone(ctx context.Context, paneID string) (bool, error) {
+	deadline := time.Now().Add(paneKillWait)
+	for {
+		exists, err := c.PaneExists(ctx, paneID)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			return true, nil
+		}
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(paneKillPoll):
+		}
+	}
+}
+
+func (c *Client) resolvePaneID(ctx context.Context, target string) (string, error) {
+	if strings.TrimSpace(target) == "" {
+		return "", fmt.Errorf("target is required")
+	}
+
+	cmd := fmt.Sprintf("tmux display-message -p -t %s '#{pane_id}'", shell.Quote(target))
+	stdout, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isTargetGone(stderr) {
+			return "", ErrPaneNotFound
+		}
+		return "", fmt.Errorf("tmux display-message failed: %w", err)
+	}
+
+	id := strings.TrimSpace(string(stdout))
+	if id == "" {
+		return "", ErrPaneNotFound
+	}
+	return id, nil
+}
+
+// windowPaneCount returns the number of panes in the window containing
+// paneID.
+func (c *Client) windowPaneCount(ctx context.Context, paneID string) (int, error) {
+	cmd := fmt.Sprintf("tmux display-message -p -t %s '#{window_panes}'", shell.Quote(paneID))
+	stdout, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isTargetGone(stderr) {
+			return 0, ErrPaneNotFound
+		}
+		return 0, fmt.Errorf("tmux display-message failed: %w", err)
+	}
+	raw := strings.TrimSpace(string(stdout))
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid window pane count %q: %w", raw, err)
+	}
+	return n, nil
+}
+
+func paneStillAliveError(target string, killErr error) error {
+	if killErr != nil {
+		return fmt.Errorf("%w: %s (%v)", ErrPaneStillAlive, target, killErr)
+	}
+	return fmt.Errorf("%w: %s", ErrPaneStillAlive, target)
+}
+
 // SelectPane selects (focuses) a pane.
 func (c *Client) SelectPane(ctx context.Context, target string) error {
 	if strings.TrimSpace(target) == "" {
 		return fmt.Errorf("target is required")
 	}
 
-	cmd := fmt.Sprintf("tmux select-pane -t %s", escapeArg(target))
+	cmd := fmt.Sprintf("tmux select-pane -t %s", shell.Quote(target))
 	if _, _, err := c.exec.Exec(ctx, cmd); err != nil {
 		return fmt.Errorf("tmux select-pane failed: %w", err)
 	}
@@ -706,11 +1063,39 @@ func (c *Client) SelectPane(ctx context.Context, target string) error {
 	return nil
 }
 
+// ZoomPane zooms a pane to fill its window. Unlike resize-pane -Z on its
+// own, it leaves an already zoomed window as it is.
+func (c *Client) ZoomPane(ctx context.Context, target string) error {
+	if strings.TrimSpace(target) == "" {
+		return fmt.Errorf("target is required")
+	}
+
+	cmd := fmt.Sprintf("tmux display-message -p -t %s '#{window_zoomed_flag}'", shell.Quote(target))
+	stdout, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isPaneNotFound(stderr) {
+			return ErrPaneNotFound
+		}
+		return fmt.Errorf("tmux display-message failed: %w", err)
+	}
+	if strings.TrimSpace(string(stdout)) == "1" {
+		return nil
+	}
+
+	cmd = fmt.Sprintf("tmux resize-pane -Z -t %s", shell.Quote(target))
+	if _, _, err := c.exec.Exec(ctx, cmd); err != nil {
+		return fmt.Errorf("tmux resize-pane failed: %w", err)
+	}
+
+	return nil
+}
+
 // Common errors
 var (
 	ErrSessionExists   = fmt.Errorf("session already exists")
 	ErrSessionNotFound = fmt.Errorf("session not found")
 	ErrPaneNotFound    = fmt.Errorf("pane not found")
+	ErrPaneStillAlive  = fmt.Errorf("pane still alive after kill")
 )
 
 func isNoServerRunning(stderr []byte) bool {
@@ -730,6 +1115,13 @@ func isPaneNotFound(stderr []byte) bool {
 		strings.Contains(s, "no such pane")
 }
 
+// isTargetGone reports whether tmux failed because nothing matches the
+// target any more.
+func isTargetGone(stderr []byte) bool {
+	return isPaneNotFound(stderr) || isSessionNotFound(stderr) || isNoServerRunning(stderr) ||
+		strings.Contains(strings.ToLower(string(stderr)), "can't find window")
+}
+
 func isDuplicateSession(stderr []byte) bool {
 	return strings.Contains(strings.ToLower(string(stderr)), "duplicate session")
 }
@@ -743,8 +1135,12 @@ func escapeSessionName(name string) string {
 	return name
 }
 
-// escapeArg escapes an argument for shell use.
-func escapeArg(arg string) string {
-	// Use single quotes and escape any internal single quotes
-	return fmt.Sprintf("'%s'", strings.ReplaceAll(arg, "'", "'\\''"))
+// sendKeysArg quotes keys as the last argument of a tmux command. tmux ends
+// a command at an argument ending in ";" and drops the semicolon, so a
+// trailing one is sent as "\;", which tmux reads as a literal ";".
+func sendKeysArg(keys string) string {
+	if strings.HasSuffix(keys, ";") {
+		keys = strings.TrimSuffix(keys, ";") + `\;`
+	}
+	return shell.Quote(keys)
 }
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 type fakeExecutor struct {
@@ -63,6 +64,34 @@ func TestListSessions(t *testing.T) {
 	}
 }
 
+func TestListSessions_Activity(t *testing.T) {
+	exec := &fakeExecutor{stdout: []byte("swarm-alpha|1|1760000000\nbeta|2|\n")}
+	client := NewClient(exec)
+
+	sessions, err := client.ListSessions(context.Background())
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	want := "tmux list-sessions -F '#{session_name}|#{session_windows}|#{session_activity}'"
+	if exec.lastCmd != want {
+		t.Fatalf("expected %q, got %q", want, exec.lastCmd)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if !sessions[0].Activity.Equal(time.Unix(1760000000, 0)) {
+		t.Fatalf("unexpected activity: %v", sessions[0].Activity)
+	}
+	if !sessions[1].Activity.IsZero() {
+		t.Fatalf("expected no activity for beta, got %v", sessions[1].Activity)
+	}
+
+	exec.stdout = []byte("alpha|1|yesterday\n")
+	if _, err := client.ListSessions(context.Background()); err == nil {
+		t.Fatalf("expected error for invalid activity")
+	}
+}
+
 func TestListSessions_NoServer(t *testing.T) {
 	exec := &fakeExecutor{
 		err:    errors.New("exit status 1"),
@@ -310,6 +339,19 @@ func TestSelectLayout(t *testing.T) {
 	}
 }
 
+func TestSelectLayout_TargetGone(t *testing.T) {
+	exec := &fakeExecutor{stderr: []byte("can't find session: gone"), err: errors.New("exit status 1")}
+	client := NewClient(exec)
+
+	err := client.SelectLayout(context.Background(), "gone:agents", "even-vertical")
+	if err != ErrPaneNotFound {
+		t.Fatalf("expected ErrPaneNotFound, got %v", err)
+	}
+	if exec.lastCmd != "tmux select-layout -t 'gone:agents' 'even-vertical'" {
+		t.Errorf("unexpected command: %s", exec.lastCmd)
+	}
+}
+
 func TestSelectLayout_EmptyTarget(t *testing.T) {
 	exec := &fakeExecutor{}
 	client := NewClient(exec)
@@ -357,8 +399,39 @@ func TestKillSession_NotFound(t *testing.T) {
 	}
 }
 
+func TestRenameSession(t *testing.T) {
+	exec := &fakeExecutor{}
+	client := NewClient(exec)
+
+	if err := client.RenameSession(context.Background(), "old_name", "swarm-app-1234abcd"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !containsAll(exec.lastCmd, "rename-session", "-t old_name", "'swarm-app-1234abcd'") {
+		t.Errorf("unexpected command: %s", exec.lastCmd)
+	}
+
+	if err := client.RenameSession(context.Background(), "old_name", "bad.name"); !errors.Is(err, ErrInvalidSessionName) {
+		t.Errorf("expected ErrInvalidSessionName, got %v", err)
+	}
+}
+
+func TestRenameSession_Errors(t *testing.T) {
+	exec := &fakeExecutor{
+		stderrQueue: [][]byte{[]byte("can't find session: old"), []byte("duplicate session: new")},
+		err:         errors.New("exit status 1"),
+	}
+	client := NewClient(exec)
+
+	if err := client.RenameSession(context.Background(), "old", "new"); err != ErrSessionNotFound {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+	if err := client.RenameSession(context.Background(), "old", "new"); err != ErrSessionExists {
+		t.Errorf("expected ErrSessionExists, got %v", err)
+	}
+}
+
 func TestListPanes(t *testing.T) {
-	exec := &fakeExecutor{stdout: []byte("%1|0|0|/home/user/project|1|bash\n%2|0|1|/home/user/project|0|opencode\n")}
+	exec := &fakeExecutor{stdout: []byte("%1|0|0|/home/user/project|1|0|bash\n%2|0|1|/home/user/project|0|0|opencode\n")}
 	client := NewClient(exec)
 
 	panes, err := client.ListPanes(context.Background(), "my-session")
@@ -382,6 +455,32 @@ func TestListPanes(t *testing.T) {
 	}
 }
 
+func TestZoomPane(t *testing.T) {
+	exec := &fakeExecutor{stdoutQueue: [][]byte{[]byte("0\n"), nil}}
+	client := NewClient(exec)
+
+	if err := client.ZoomPane(context.Background(), "%3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"tmux display-message -p -t '%3' '#{window_zoomed_flag}'",
+		"tmux resize-pane -Z -t '%3'",
+	}
+	if strings.Join(exec.commands, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("commands = %q, want %q", exec.commands, want)
+	}
+
+	// An already zoomed window is left alone rather than toggled back.
+	exec = &fakeExecutor{stdout: []byte("1\n")}
+	client = NewClient(exec)
+	if err := client.ZoomPane(context.Background(), "%3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exec.commands) != 1 {
+		t.Fatalf("expected only the zoom check, got %q", exec.commands)
+	}
+}
+
 func TestSplitWindow(t *testing.T) {
 	exec := &fakeExecutor{stdout: []byte("%3\n")}
 	client := NewClient(exec)
@@ -437,6 +536,73 @@ func TestSendKeys_WithEnter(t *testing.T) {
 	}
 }
 
+func TestSendKeys_QuotesText(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    string
+		literal bool
+		want    string
+	}{
+		{
+			name:    "leading dash",
+			keys:    "-v flag isn't working, please fix",
+			literal: true,
+			want:    `tmux send-keys -t '%1' -l -- '-v flag isn'\''t working, please fix'`,
+		},
+		{
+			name:    "trailing semicolon",
+			keys:    "run the tests;",
+			literal: true,
+			want:    `tmux send-keys -t '%1' -l -- 'run the tests\;'`,
+		},
+		{
+			name:    "inner semicolons",
+			keys:    "make; make test",
+			literal: true,
+			want:    `tmux send-keys -t '%1' -l -- 'make; make test'`,
+		},
+		{
+			name:    "single quotes",
+			keys:    "don't stop",
+			literal: true,
+			want:    `tmux send-keys -t '%1' -l -- 'don'\''t stop'`,
+		},
+		{
+			name:    "dash, quote, and semicolon",
+			keys:    "-n isn't set;",
+			literal: true,
+			want:    `tmux send-keys -t '%1' -l -- '-n isn'\''t set\;'`,
+		},
+		{
+			name: "key name",
+			keys: "C-u",
+			want: `tmux send-keys -t '%1' -- 'C-u'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := &fakeExecutor{}
+			if err := NewClient(exec).SendKeys(context.Background(), "%1", tt.keys, tt.literal, false); err != nil {
+				t.Fatalf("SendKeys failed: %v", err)
+			}
+			if exec.lastCmd != tt.want {
+				t.Errorf("got command\n  %s\nwant\n  %s", exec.lastCmd, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendAndWait_QuotesText(t *testing.T) {
+	exec := &fakeExecutor{stdout: []byte("ready\n")}
+	if _, err := NewClient(exec).SendAndWait(context.Background(), "%1", "-x;", true, false, 1); err != nil {
+		t.Fatalf("SendAndWait failed: %v", err)
+	}
+	if want := `tmux send-keys -t '%1' -l -- '-x\;'`; exec.commands[0] != want {
+		t.Fatalf("got command %s, want %s", exec.commands[0], want)
+	}
+}
+
 func TestSendInterrupt(t *testing.T) {
 	exec := &fakeExecutor{}
 	client := NewClient(exec)
@@ -512,6 +678,39 @@ func TestCapturePane(t *testing.T) {
 	}
 }
 
+func TestCapturePaneRange(t *testing.T) {
+	exec := &fakeExecutor{stdout: []byte("old 1\nold 2\nline 1\nline 2\nline 3\n\n\n")}
+	client := NewClient(exec)
+
+	content, truncated, err := client.CapturePaneRange(context.Background(), "%1", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !containsAll(exec.lastCmd, "capture-pane", "-p", "-S -3") || containsAll(exec.lastCmd, "-E") {
+		t.Errorf("unexpected command: %s", exec.lastCmd)
+	}
+	if content != "line 1\nline 2\nline 3\n" || !truncated {
+		t.Errorf("unexpected capture %q (truncated %v)", content, truncated)
+	}
+
+	// A pane shorter than the request is returned whole.
+	exec.stdout = []byte("only\n")
+	content, truncated, err = client.CapturePaneRange(context.Background(), "%1", 50)
+	if err != nil || content != "only\n" || truncated {
+		t.Errorf("unexpected short capture %q (truncated %v, err %v)", content, truncated, err)
+	}
+
+	if _, _, err := client.CapturePaneRange(context.Background(), "%1", 0); err == nil {
+		t.Error("expected an error for a non-positive line count")
+	}
+	if _, _, err := client.CapturePaneRange(context.Background(), "%1", historyMaxLines+1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !containsAll(exec.lastCmd, fmt.Sprintf("-S -%d", historyMaxLines)) {
+		t.Errorf("expected the line count capped at the history limit: %s", exec.lastCmd)
+	}
+}
+
 func TestCapturePane_WithHistory(t *testing.T) {
 	exec := &fakeExecutor{
 		stdoutQueue: [][]byte{
@@ -615,37 +814,49 @@ func TestKillPane(t *testing.T) {
 	}
 }
 
-func TestSelectPane(t *testing.T) {
+func TestMovePane(t *testing.T) {
 	exec := &fakeExecutor{}
 	client := NewClient(exec)
 
-	err := client.SelectPane(context.Background(), "%1")
-	if err != nil {
+	if err := client.MovePane(context.Background(), "%1", "other:agents"); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if !containsAll(exec.lastCmd, "select-pane", "-t") {
+	if !containsAll(exec.lastCmd, "move-pane", "-d", "-s '%1'", "-t 'other:agents'") {
 		t.Errorf("unexpected command: %s", exec.lastCmd)
 	}
+
+	exec.err = errors.New("exit status 1")
+	exec.stderr = []byte("can't find pane: %1")
+	if err := client.MovePane(context.Background(), "%1", "other"); !errors.Is(err, ErrPaneNotFound) {
+		t.Errorf("expected ErrPaneNotFound, got %v", err)
+	}
 }
 
-func TestEscapeArg(t *testing.T) {
-	tests := []struct {
-		input string
-		want  string
-	}{
-		{"simple", "'simple'"},
-		{"with spaces", "'with spaces'"},
-		{"with'quote", "'with'\\''quote'"},
-		{"$variable", "'$variable'"},
+func TestJoinPane(t *testing.T) {
+	exec := &fakeExecutor{}
+	client := NewClient(exec)
+
+	if err := client.JoinPane(context.Background(), "%1", "%4", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !containsAll(exec.lastCmd, "join-pane", "-h", "-s '%1'", "-t '%4'") {
+		t.Errorf("unexpected command: %s", exec.lastCmd)
+	}
+	if err := client.JoinPane(context.Background(), "", "%4", false); err == nil {
+		t.Error("expected error for empty source")
 	}
+}
 
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			got := escapeArg(tt.input)
-			if got != tt.want {
-				t.Errorf("escapeArg(%q) = %q, want %q", tt.input, got, tt.want)
-			}
-		})
+func TestSelectPane(t *testing.T) {
+	exec := &fakeExecutor{}
+	client := NewClient(exec)
+
+	err := client.SelectPane(context.Background(), "%1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !containsAll(exec.lastCmd, "select-pane", "-t") {
+		t.Errorf("unexpected command: %s", exec.lastCmd)
 	}
 }
 
@@ -974,14 +1185,14 @@ func TestListPanes_GoldenOutput(t *testing.T) {
 		},
 		{
 			name:   "single pane",
-			stdout: "%0|0|0|/home/user|1|zsh\n",
+			stdout: "%0|0|0|/home/user|1|0|zsh\n",
 			expected: []Pane{
 				{ID: "%0", WindowIndex: 0, Index: 0, CurrentDir: "/home/user", Active: true, Command: "zsh"},
 			},
 		},
 		{
 			name:   "multiple panes across windows",
-			stdout: "%0|0|0|/home/user|1|zsh\n%1|0|1|/home/user/code|0|vim\n%2|1|0|/tmp|0|bash\n",
+			stdout: "%0|0|0|/home/user|1|0|zsh\n%1|0|1|/home/user/code|0|0|vim\n%2|1|0|/tmp|0|0|bash\n",
 			expected: []Pane{
 				{ID: "%0", WindowIndex: 0, Index: 0, CurrentDir: "/home/user", Active: true, Command: "zsh"},
 				{ID: "%1", WindowIndex: 0, Index: 1, CurrentDir: "/home/user/code", Active: false, Command: "vim"},
@@ -990,11 +1201,19 @@ func TestListPanes_GoldenOutput(t *testing.T) {
 		},
 		{
 			name:   "path with spaces",
-			stdout: "%0|0|0|/home/user/My Documents|0|bash\n",
+			stdout: "%0|0|0|/home/user/My Documents|0|0|bash\n",
 			expected: []Pane{
 				{ID: "%0", WindowIndex: 0, Index: 0, CurrentDir: "/home/user/My Documents", Active: false, Command: "bash"},
 			},
 		},
+		{
+			name:   "zoomed window",
+			stdout: "%0|1|0|/repo|1|1|claude\n%1|1|1|/repo|0|1|opencode\n",
+			expected: []Pane{
+				{ID: "%0", WindowIndex: 1, Index: 0, CurrentDir: "/repo", Active: true, Zoomed: true, Command: "claude"},
+				{ID: "%1", WindowIndex: 1, Index: 1, CurrentDir: "/repo", Active: false, Zoomed: true, Command: "opencode"},
+			},
+		},
 		{
 			name:    "malformed output - missing fields",
 			stdout:  "%0|0|0\n",
@@ -1023,7 +1242,7 @@ func TestListPanes_GoldenOutput(t *testing.T) {
 			for i, exp := range tt.expected {
 				if panes[i].ID != exp.ID || panes[i].WindowIndex != exp.WindowIndex ||
 					panes[i].Index != exp.Index || panes[i].CurrentDir != exp.CurrentDir ||
-					panes[i].Active != exp.Active || panes[i].Command != exp.Command {
+					panes[i].Active != exp.Active || panes[i].Zoomed != exp.Zoomed || panes[i].Command != exp.Command {
 					t.Errorf("pane[%d] = %+v, want %+v", i, panes[i], exp)
 				}
 			}
@@ -1618,3 +1837,100 @@ func TestKillPane_PaneNotFound(t *testing.T) {
 		t.Fatalf("expected ErrPaneNotFound, got: %v", err)
 	}
 }
+
+func TestPipePane(t *testing.T) {
+	exec := &fakeExecutor{}
+	client := NewClient(exec)
+
+	err := client.PipePane(context.Background(), "swarm-repo:0.1", "'/usr/bin/swarm' agent record sink --output '/data/it'\\''s.cast'")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `tmux pipe-pane -O -t 'swarm-repo:0.1' ''\''/usr/bin/swarm'\'' agent record sink --output '\''/data/it'\''\'\'''\''s.cast'\'''`
+	if exec.lastCmd != want {
+		t.Fatalf("unexpected command:\n got: %s\nwant: %s", exec.lastCmd, want)
+	}
+}
+
+func TestPipePane_Stop(t *testing.T) {
+	exec := &fakeExecutor{}
+	client := NewClient(exec)
+
+	if err := client.PipePane(context.Background(), "%1", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exec.lastCmd != "tmux pipe-pane -t '%1'" {
+		t.Fatalf("unexpected command: %s", exec.lastCmd)
+	}
+}
+
+func TestPipePane_PaneNotFound(t *testing.T) {
+	exec := &fakeExecutor{
+		err:    errors.New("exit status 1"),
+		stderr: []byte("can't find pane: %999"),
+	}
+	client := NewClient(exec)
+
+	if err := client.PipePane(context.Background(), "%999", "cat"); err != ErrPaneNotFound {
+		t.Fatalf("expected ErrPaneNotFound, got: %v", err)
+	}
+}
+
+func TestResizePane(t *testing.T) {
+	tests := []struct {
+		width, height int
+		want          string
+	}{
+		{120, 40, "tmux resize-pane -t '%1' -x 120 -y 40"},
+		{120, 0, "tmux resize-pane -t '%1' -x 120"},
+		{0, 40, "tmux resize-pane -t '%1' -y 40"},
+	}
+	for _, tt := range tests {
+		exec := &fakeExecutor{}
+		client := NewClient(exec)
+
+		if err := client.ResizePane(context.Background(), "%1", tt.width, tt.height); err != nil {
+			t.Fatalf("ResizePane(%d, %d) error = %v", tt.width, tt.height, err)
+		}
+		if exec.lastCmd != tt.want {
+			t.Errorf("ResizePane(%d, %d) command = %q, want %q", tt.width, tt.height, exec.lastCmd, tt.want)
+		}
+	}
+}
+
+func TestResizePane_Errors(t *testing.T) {
+	exec := &fakeExecutor{stderr: []byte("can't find pane: %9"), err: errors.New("exit status 1")}
+	client := NewClient(exec)
+
+	if err := client.ResizePane(context.Background(), "%9", 80, 24); err != ErrPaneNotFound {
+		t.Fatalf("expected ErrPaneNotFound, got %v", err)
+	}
+	for _, size := range [][2]int{{0, 0}, {-1, 24}, {80, -1}} {
+		exec.commands = nil
+		if err := client.ResizePane(context.Background(), "%9", size[0], size[1]); err == nil {
+			t.Errorf("expected an error for size %dx%d", size[0], size[1])
+		}
+		if len(exec.commands) != 0 {
+			t.Errorf("expected no command for size %dx%d, got %q", size[0], size[1], exec.commands)
+		}
+	}
+	if err := client.ResizePane(context.Background(), "", 80, 24); err == nil {
+		t.Error("expected an error for an empty target")
+	}
+}
+
+func TestPaneSize(t *testing.T) {
+	exec := &fakeExecutor{stdout: []byte("120 40\n")}
+	client := NewClient(exec)
+
+	width, height, err := client.PaneSize(context.Background(), "%1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if width != 120 || height != 40 {
+		t.Fatalf("expected 120x40, got %dx%d", width, height)
+	}
+	if !containsAll(exec.lastCmd, "display-message", "#{pane_width} #{pane_height}") {
+		t.Errorf("unexpected command: %s", exec.lastCmd)
+	}
+}
@@ -8,7 +8,7 @@ import (
 func TestLayoutManager_EnsureLayout(t *testing.T) {
 	exec := &fakeExecutor{
 		stdoutQueue: [][]byte{
-			[]byte("%1|0|0|/repo|1|bash\n"),
+			[]byte("%1|0|0|/repo|1|0|bash\n"),
 			[]byte("%2\n"),
 			[]byte("%3\n"),
 			[]byte(""),
@@ -47,7 +47,7 @@ func TestLayoutManager_Balance(t *testing.T) {
 func TestLayoutManager_SplitDirectionUsesPreset(t *testing.T) {
 	exec := &fakeExecutor{
 		stdoutQueue: [][]byte{
-			[]byte("%1|0|0|/repo|1|bash\n"),
+			[]byte("%1|0|0|/repo|1|0|bash\n"),
 			[]byte("%2\n"),
 			[]byte(""),
 		},
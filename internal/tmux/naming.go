@@ -0,0 +1,99 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSessionName is returned for session names tmux cannot target.
+var ErrInvalidSessionName = errors.New("invalid tmux session name")
+
+const (
+	// DefaultSessionPrefix prefixes generated session names.
+	DefaultSessionPrefix = "swarm"
+
+	// maxSessionSlugLen caps the name slug so long paths stay readable.
+	maxSessionSlugLen = 32
+
+	// sessionIDSuffixLen is how much of the workspace ID is appended.
+	sessionIDSuffixLen = 8
+)
+
+// SessionName derives the session name for a workspace:
+// <prefix>-<name slug>-<short id>. Any empty part is omitted. The result is
+// deterministic for a workspace and distinct across workspaces whose names
+// share a slug.
+func SessionName(prefix, name, id string) string {
+	parts := make([]string, 0, 3)
+	if p := SanitizeSessionName(prefix); p != "" {
+		parts = append(parts, p)
+	}
+	if slug := truncateSlug(SanitizeSessionName(name), maxSessionSlugLen); slug != "" {
+		parts = append(parts, slug)
+	}
+	if suffix := SanitizeSessionName(id); suffix != "" {
+		if len(suffix) > sessionIDSuffixLen {
+			suffix = strings.TrimRight(suffix[:sessionIDSuffixLen], "-")
+		}
+		parts = append(parts, suffix)
+	}
+	if len(parts) == 0 {
+		return "workspace"
+	}
+	return strings.Join(parts, "-")
+}
+
+// SanitizeSessionName lowercases value and collapses every run of
+// characters outside [a-z0-9] into a single dash.
+func SanitizeSessionName(value string) string {
+	value = strings.ToLower(value)
+	var b strings.Builder
+	lastDash := false
+
+	for _, r := range value {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			lastDash = false
+			continue
+		}
+
+		if !lastDash {
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+
+	return strings.Trim(b.String(), "-")
+}
+
+// ValidateSessionName rejects names tmux would rewrite or that break
+// session:window.pane target parsing.
+func ValidateSessionName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: name is empty", ErrInvalidSessionName)
+	}
+	if strings.ContainsAny(name, ".:") {
+		return fmt.Errorf("%w: %q contains '.' or ':'", ErrInvalidSessionName, name)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("%w: %q contains control characters", ErrInvalidSessionName, name)
+		}
+	}
+	return nil
+}
+
+// NormalizeSessionName returns the name tmux actually gives a session,
+// which replaces '.' and ':' with '_'. Two names that normalize equally
+// refer to the same live session.
+func NormalizeSessionName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(name)
+}
+
+func truncateSlug(slug string, max int) string {
+	if len(slug) <= max {
+		return slug
+	}
+	return strings.TrimRight(slug[:max], "-")
+}
@@ -0,0 +1,81 @@
+package tmux
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSessionName(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		wsName   string
+		id       string
+		expected string
+	}{
+		{name: "basic", prefix: "swarm", wsName: "my-project", id: "1234abcd-5678", expected: "swarm-my-project-1234abcd"},
+		{name: "dots and colons", prefix: "swarm", wsName: "api.v2:main", id: "deadbeef", expected: "swarm-api-v2-main-deadbeef"},
+		{name: "unicode", prefix: "swarm", wsName: "Über Projekt 東京", id: "abc", expected: "swarm-ber-projekt-abc"},
+		{name: "unicode only", prefix: "swarm", wsName: "東京", id: "abc", expected: "swarm-abc"},
+		{name: "no name", prefix: "swarm", id: "0f1e2d3c-aaaa", expected: "swarm-0f1e2d3c"},
+		{name: "no prefix", wsName: "app", id: "0f1e2d3c", expected: "app-0f1e2d3c"},
+		{name: "empty", expected: "workspace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SessionName(tt.prefix, tt.wsName, tt.id)
+			if got != tt.expected {
+				t.Fatalf("SessionName(%q, %q, %q) = %q, want %q", tt.prefix, tt.wsName, tt.id, got, tt.expected)
+			}
+			if err := ValidateSessionName(got); err != nil {
+				t.Fatalf("generated name %q is not valid: %v", got, err)
+			}
+		})
+	}
+}
+
+func TestSessionNameLongName(t *testing.T) {
+	long := strings.Repeat("very-long-directory-name-", 10)
+	got := SessionName("swarm", long, "1234abcd")
+	if len(got) > len("swarm-")+maxSessionSlugLen+len("-1234abcd") {
+		t.Fatalf("expected slug to be truncated, got %q (%d chars)", got, len(got))
+	}
+	if strings.Contains(got, "--") || !strings.HasSuffix(got, "-1234abcd") {
+		t.Fatalf("unexpected truncated name %q", got)
+	}
+}
+
+func TestSessionNameDuplicateBasenames(t *testing.T) {
+	a := SessionName("swarm", "app", "11111111-aaaa")
+	b := SessionName("swarm", "app", "22222222-bbbb")
+	if a == b {
+		t.Fatalf("expected distinct names for workspaces sharing a basename, got %q", a)
+	}
+	if again := SessionName("swarm", "app", "11111111-aaaa"); again != a {
+		t.Fatalf("expected deterministic name, got %q and %q", a, again)
+	}
+}
+
+func TestValidateSessionName(t *testing.T) {
+	for _, name := range []string{"swarm-app-1234", "my_session", "Work Space"} {
+		if err := ValidateSessionName(name); err != nil {
+			t.Errorf("ValidateSessionName(%q) returned error: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "  ", "api.v2", "host:1", "tab\tname"} {
+		if err := ValidateSessionName(name); !errors.Is(err, ErrInvalidSessionName) {
+			t.Errorf("ValidateSessionName(%q) = %v, want ErrInvalidSessionName", name, err)
+		}
+	}
+}
+
+func TestNormalizeSessionName(t *testing.T) {
+	if got := NormalizeSessionName("api.v2:main"); got != "api_v2_main" {
+		t.Fatalf("unexpected normalized name %q", got)
+	}
+	if NormalizeSessionName("a.b") != NormalizeSessionName("a_b") {
+		t.Fatal("expected a.b and a_b to normalize to the same session")
+	}
+}
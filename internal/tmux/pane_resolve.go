@@ -0,0 +1,117 @@
+package tmux
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/shell"
+)
+
+// PaneDrift classifies where a stored pane target resolves to now.
+type PaneDrift string
+
+const (
+	// PaneInPlace means the pane is live in its expected session.
+	PaneInPlace PaneDrift = "ok"
+	// PaneMoved means the pane is live but now belongs to another session.
+	PaneMoved PaneDrift = "moved"
+	// PaneVanished means no live pane matches the target.
+	PaneVanished PaneDrift = "vanished"
+)
+
+// PaneResolution is the result of resolving a stored pane target against
+// the live tmux server.
+type PaneResolution struct {
+	// Target is the stored target that was resolved.
+	Target string `json:"target"`
+
+	// PaneID is the pane's immutable ID (e.g. "%12"); empty if vanished.
+	PaneID string `json:"pane_id,omitempty"`
+
+	// ExpectedSession is the session the pane should live in.
+	ExpectedSession string `json:"expected_session"`
+
+	// Session is the session the pane lives in now; empty if vanished.
+	Session string `json:"session,omitempty"`
+
+	// Drift reports whether the pane stayed put, moved, or vanished.
+	Drift PaneDrift `json:"drift"`
+}
+
+// IsPaneID reports whether target is a tmux pane ID like "%12" rather than
+// a positional session:window.pane target.
+func IsPaneID(target string) bool {
+	return len(target) > 1 && target[0] == '%'
+}
+
+// ResolvePane resolves a stored pane target to its pane ID and checks that
+// the pane still belongs to the expected session. Positional targets are
+// resolved to the pane they currently address, which may not be the pane
+// they were recorded for. With an empty session, any live pane is in place.
+func (c *Client) ResolvePane(ctx context.Context, session, target string) (*PaneResolution, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, fmt.Errorf("target is required")
+	}
+
+	res := &PaneResolution{Target: target, ExpectedSession: session, Drift: PaneVanished}
+
+	paneID := target
+	if !IsPaneID(target) {
+		id, err := c.resolvePaneID(ctx, target)
+		if err == ErrPaneNotFound {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		paneID = id
+	}
+
+	if session != "" {
+		// A session that cannot be listed (usually because it is gone) is
+		// not fatal: the pane is then looked up directly.
+		panes, _ := c.ListSessionPanes(ctx, session)
+		for _, pane := range panes {
+			if pane.ID == paneID {
+				res.PaneID = paneID
+				res.Session = session
+				res.Drift = PaneInPlace
+				return res, nil
+			}
+		}
+	}
+
+	current, err := c.paneSession(ctx, paneID)
+	if err == ErrPaneNotFound {
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.PaneID = paneID
+	res.Session = current
+	res.Drift = PaneMoved
+	if session == "" || current == session {
+		res.Drift = PaneInPlace
+	}
+	return res, nil
+}
+
+// paneSession returns the name of the session holding paneID.
+func (c *Client) paneSession(ctx context.Context, paneID string) (string, error) {
+	cmd := fmt.Sprintf("tmux display-message -p -t %s '#{session_name}'", shell.Quote(paneID))
+	stdout, stderr, err := c.exec.Exec(ctx, cmd)
+	if err != nil {
+		if isTargetGone(stderr) {
+			return "", ErrPaneNotFound
+		}
+		return "", fmt.Errorf("tmux display-message failed: %w", err)
+	}
+	name := strings.TrimSpace(string(stdout))
+	if name == "" {
+		return "", ErrPaneNotFound
+	}
+	return name, nil
+}
@@ -0,0 +1,131 @@
+package tmux
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolvePane(t *testing.T) {
+	listed := []byte("%1|0|0|/repo|1|0|bash\n%4|1|0|/repo|0|0|claude\n")
+	paneGone := []byte("can't find pane: %4")
+	exitErr := errors.New("exit status 1")
+
+	tests := []struct {
+		name     string
+		session  string
+		target   string
+		exec     *fakeExecutor
+		want     PaneResolution
+		wantCmds int
+	}{
+		{
+			name:    "stayed put",
+			session: "ws",
+			target:  "%4",
+			exec:    &fakeExecutor{stdoutQueue: [][]byte{listed}},
+			want: PaneResolution{
+				Target: "%4", PaneID: "%4", ExpectedSession: "ws", Session: "ws", Drift: PaneInPlace,
+			},
+			wantCmds: 1,
+		},
+		{
+			name:    "moved to another session",
+			session: "ws",
+			target:  "%9",
+			exec:    &fakeExecutor{stdoutQueue: [][]byte{listed, []byte("other\n")}},
+			want: PaneResolution{
+				Target: "%9", PaneID: "%9", ExpectedSession: "ws", Session: "other", Drift: PaneMoved,
+			},
+			wantCmds: 2,
+		},
+		{
+			name:    "vanished",
+			session: "ws",
+			target:  "%4",
+			exec: &fakeExecutor{
+				stdoutQueue: [][]byte{[]byte("%1|0|0|/repo|1|0|bash\n"), nil},
+				stderrQueue: [][]byte{nil, paneGone},
+				errQueue:    []error{nil, exitErr},
+			},
+			want:     PaneResolution{Target: "%4", ExpectedSession: "ws", Drift: PaneVanished},
+			wantCmds: 2,
+		},
+		{
+			name:    "session gone but pane moved",
+			session: "ws",
+			target:  "%4",
+			exec: &fakeExecutor{
+				stdoutQueue: [][]byte{nil, []byte("rescue\n")},
+				stderrQueue: [][]byte{[]byte("can't find session: ws"), nil},
+				errQueue:    []error{exitErr, nil},
+			},
+			want: PaneResolution{
+				Target: "%4", PaneID: "%4", ExpectedSession: "ws", Session: "rescue", Drift: PaneMoved,
+			},
+			wantCmds: 2,
+		},
+		{
+			name:    "positional target resolves to its pane ID",
+			session: "ws",
+			target:  "ws:1.0",
+			exec:    &fakeExecutor{stdoutQueue: [][]byte{[]byte("%4\n"), listed}},
+			want: PaneResolution{
+				Target: "ws:1.0", PaneID: "%4", ExpectedSession: "ws", Session: "ws", Drift: PaneInPlace,
+			},
+			wantCmds: 2,
+		},
+		{
+			name:    "positional target vanished",
+			session: "ws",
+			target:  "ws:3.0",
+			exec: &fakeExecutor{
+				stderrQueue: [][]byte{[]byte("can't find window: 3")},
+				errQueue:    []error{exitErr},
+			},
+			want:     PaneResolution{Target: "ws:3.0", ExpectedSession: "ws", Drift: PaneVanished},
+			wantCmds: 1,
+		},
+		{
+			name:     "no expected session",
+			target:   "%4",
+			exec:     &fakeExecutor{stdoutQueue: [][]byte{[]byte("anywhere\n")}},
+			want:     PaneResolution{Target: "%4", PaneID: "%4", Session: "anywhere", Drift: PaneInPlace},
+			wantCmds: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(tt.exec)
+			got, err := client.ResolvePane(context.Background(), tt.session, tt.target)
+			if err != nil {
+				t.Fatalf("ResolvePane failed: %v", err)
+			}
+			if *got != tt.want {
+				t.Fatalf("ResolvePane = %+v, want %+v", *got, tt.want)
+			}
+			if len(tt.exec.commands) != tt.wantCmds {
+				t.Fatalf("expected %d tmux commands, got %q", tt.wantCmds, tt.exec.commands)
+			}
+		})
+	}
+}
+
+func TestResolvePane_Error(t *testing.T) {
+	exec := &fakeExecutor{
+		stdoutQueue: [][]byte{[]byte("%1|0|0|/repo|1|0|bash\n"), nil},
+		stderrQueue: [][]byte{nil, []byte("lost connection")},
+		errQueue:    []error{nil, errors.New("exit status 255")},
+	}
+	client := NewClient(exec)
+
+	_, err := client.ResolvePane(context.Background(), "ws", "%4")
+	if err == nil || !strings.Contains(err.Error(), "display-message") {
+		t.Fatalf("expected display-message error, got %v", err)
+	}
+	if !strings.Contains(exec.commands[0], "list-panes -t ws -s") {
+		t.Fatalf("expected every window of the session to be listed, got %q", exec.commands[0])
+	}
+}
@@ -4,6 +4,8 @@ package tmux
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"regexp"
+	"strings"
 )
 
 // HashSnapshot returns a stable hash for captured pane content.
@@ -11,3 +13,33 @@ func HashSnapshot(content string) string {
 	sum := sha256.Sum256([]byte(content))
 	return hex.EncodeToString(sum[:])
 }
+
+// LastLines returns the last n lines of captured content, ignoring trailing
+// blank lines, and reports whether earlier lines were dropped.
+func LastLines(content string, n int) (string, bool) {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return "", false
+	}
+	truncated := n > 0 && len(lines) > n
+	if truncated {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n") + "\n", truncated
+}
+
+// ContentSince returns the lines of content after the last line matching
+// re, and whether any line matched. Without a match content is returned
+// unchanged.
+func ContentSince(content string, re *regexp.Regexp) (string, bool) {
+	lines := strings.SplitAfter(content, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if re.MatchString(strings.TrimSuffix(lines[i], "\n")) {
+			return strings.Join(lines[i+1:], ""), true
+		}
+	}
+	return content, false
+}
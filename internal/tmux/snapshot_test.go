@@ -1,6 +1,9 @@
 package tmux
 
-import "testing"
+import (
+	"regexp"
+	"testing"
+)
 
 func TestHashSnapshot(t *testing.T) {
 	hash := HashSnapshot("hello")
@@ -16,3 +19,24 @@ func TestHashSnapshot(t *testing.T) {
 		t.Fatal("hash should change when input changes")
 	}
 }
+
+func TestContentSince(t *testing.T) {
+	prompt := regexp.MustCompile(`^\$ `)
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		matched bool
+	}{
+		{"single match", "boot\n$ make\nok\n", "ok\n", true},
+		{"last of several", "$ ls\na\n$ make\nbuilding\nok\n", "building\nok\n", true},
+		{"match on last line", "out\n$ \n", "", true},
+		{"no match", "a\nb\n", "a\nb\n", false},
+	}
+	for _, tt := range tests {
+		got, matched := ContentSince(tt.content, prompt)
+		if got != tt.want || matched != tt.matched {
+			t.Errorf("%s: got %q (matched %v), want %q (matched %v)", tt.name, got, matched, tt.want, tt.matched)
+		}
+	}
+}
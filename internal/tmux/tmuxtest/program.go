@@ -0,0 +1,247 @@
+package tmuxtest
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Program simulates a process launched from a pane's shell, such as an
+// agent CLI. It is called once when the command starts and drives the pane
+// through term: printing output, scheduling more for later, and reacting to
+// input lines and Ctrl+C.
+//
+// Programs run while the server is locked, so Terminal methods must only be
+// called from the Program itself or from callbacks it registers. Use
+// Server.Print to write to a pane from elsewhere.
+type Program func(term *Terminal)
+
+// Terminal is a running program's view of its pane.
+type Terminal struct {
+	server      *Server
+	pane        *pane
+	name        string
+	args        []string
+	env         map[string]string
+	onInput     func(line string)
+	onInterrupt func()
+	exited      bool
+}
+
+// Name returns the command name the program was started as.
+func (t *Terminal) Name() string {
+	return t.name
+}
+
+// Args returns the command-line arguments after the command name.
+func (t *Terminal) Args() []string {
+	return append([]string(nil), t.args...)
+}
+
+// Env returns an environment variable set by export or a command prefix.
+func (t *Terminal) Env(key string) string {
+	return t.env[key]
+}
+
+// Dir returns the pane's working directory.
+func (t *Terminal) Dir() string {
+	return t.pane.dir
+}
+
+// PaneID returns the pane's global ID, e.g. "%3".
+func (t *Terminal) PaneID() string {
+	return "%" + strconv.Itoa(t.pane.id)
+}
+
+// Print writes text to the pane. Newlines start new lines; text without a
+// trailing newline stays on the cursor line, like a prompt.
+func (t *Terminal) Print(text string) {
+	if t.exited {
+		return
+	}
+	t.pane.print(text)
+}
+
+// After runs fn once d has elapsed on the server clock. Due callbacks run
+// on the next tmux command, so captures observe output as of that moment.
+func (t *Terminal) After(d time.Duration, fn func()) {
+	if t.exited {
+		return
+	}
+	t.server.schedule(t, d, fn)
+}
+
+// PrintAfter prints text once d has elapsed.
+func (t *Terminal) PrintAfter(d time.Duration, text string) {
+	t.After(d, func() { t.Print(text) })
+}
+
+// OnInput sets the handler for lines submitted with Enter. Without one,
+// input is echoed and otherwise ignored.
+func (t *Terminal) OnInput(fn func(line string)) {
+	t.onInput = fn
+}
+
+// OnInterrupt sets the handler for Ctrl+C. Without one, the program exits,
+// as most programs do on SIGINT.
+func (t *Terminal) OnInterrupt(fn func()) {
+	t.onInterrupt = fn
+}
+
+// Exit ends the program and returns the pane to its shell prompt.
+func (t *Terminal) Exit() {
+	if t.exited {
+		return
+	}
+	t.exited = true
+	p := t.pane
+	if p.proc == t {
+		p.proc = nil
+	}
+	if p.closed {
+		return
+	}
+	if p.cursor != "" {
+		p.print("\n")
+	}
+	p.print(t.server.prompt)
+}
+
+// Step is one piece of scripted program output.
+type Step struct {
+	// After is the delay since the previous step (or program start).
+	After time.Duration
+	// Output is printed when the step fires.
+	Output string
+}
+
+// Script returns a Program that prints its steps in order, each delayed
+// relative to the one before. The program keeps running afterwards.
+func Script(steps ...Step) Program {
+	return func(term *Terminal) {
+		var at time.Duration
+		for _, step := range steps {
+			at += step.After
+			term.PrintAfter(at, step.Output)
+		}
+	}
+}
+
+// =============================================================================
+// Shell
+// =============================================================================
+
+var envAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// typeText types text into a pane. Newlines submit the line, as they do
+// when pasted into a terminal.
+func (s *Server) typeText(p *pane, text string) {
+	text = strings.ReplaceAll(text, "\r", "\n")
+	for i, part := range strings.Split(text, "\n") {
+		if p.closed {
+			return
+		}
+		if i > 0 {
+			s.enter(p)
+		}
+		p.input += part
+	}
+}
+
+// enter submits the typed line to the foreground program or the shell.
+func (s *Server) enter(p *pane) {
+	line := p.input
+	p.lines = append(p.lines, p.cursor+line)
+	p.cursor = ""
+	p.input = ""
+
+	if p.proc != nil {
+		if p.proc.onInput != nil {
+			p.proc.onInput(line)
+		}
+		return
+	}
+	s.runShellLine(p, line)
+}
+
+// interrupt delivers Ctrl+C to the foreground program or the shell.
+func (s *Server) interrupt(p *pane) {
+	if proc := p.proc; proc != nil {
+		if proc.onInterrupt != nil {
+			proc.onInterrupt()
+			return
+		}
+		p.cursor += p.input + "^C"
+		p.input = ""
+		proc.Exit()
+		return
+	}
+	p.lines = append(p.lines, p.cursor+p.input+"^C")
+	p.cursor = s.prompt
+	p.input = ""
+}
+
+// runShellLine executes a line at the shell prompt: a few builtins, or a
+// registered program. Anything else is "command not found".
+func (s *Server) runShellLine(p *pane, line string) {
+	words, err := splitWords(line)
+	if err != nil {
+		p.print("bash: " + err.Error() + "\n" + s.prompt)
+		return
+	}
+
+	env := make(map[string]string, len(p.env))
+	for k, v := range p.env {
+		env[k] = v
+	}
+	for len(words) > 0 && envAssignment.MatchString(words[0]) {
+		key, value, _ := strings.Cut(words[0], "=")
+		env[key] = value
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		p.env = env
+		p.print(s.prompt)
+		return
+	}
+
+	name, args := words[0], words[1:]
+	switch name {
+	case "export":
+		for _, arg := range args {
+			if key, value, ok := strings.Cut(arg, "="); ok {
+				p.env[key] = value
+			}
+		}
+	case "cd":
+		if len(args) > 0 {
+			if path.IsAbs(args[0]) {
+				p.dir = path.Clean(args[0])
+			} else {
+				p.dir = path.Join(p.dir, args[0])
+			}
+		}
+	case "echo":
+		p.print(strings.Join(args, " ") + "\n")
+	case "clear":
+		p.lines = nil
+		p.cursor = ""
+	case "exit":
+		s.removePane(p)
+		return
+	case "true", ":":
+	default:
+		program, ok := s.programs[path.Base(name)]
+		if !ok {
+			p.print("bash: " + name + ": command not found\n")
+			break
+		}
+		term := &Terminal{server: s, pane: p, name: path.Base(name), args: args, env: env}
+		p.proc = term
+		program(term)
+		return
+	}
+	p.print(s.prompt)
+}
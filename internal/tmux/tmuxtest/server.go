@@ -0,0 +1,1218 @@
+// Package tmuxtest provides an in-memory tmux server for tests.
+//
+// Server implements tmux.Executor, so a tmux.Client built on it behaves as
+// if it were talking to a real tmux server: sessions, windows, and panes are
+// tracked, send-keys types into a pane's buffer, capture-pane returns it,
+// and failures carry the stderr tmux itself prints. Programs registered by
+// name stand in for agent CLIs launched from a pane's shell.
+package tmuxtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+)
+
+// NoServerMessage is the stderr tmux prints when no server is running.
+const NoServerMessage = "no server running on /tmp/tmux-1000/default"
+
+const (
+	defaultPrompt     = "$ "
+	defaultPaneWidth  = 80
+	defaultPaneHeight = 50
+	firstPanePID      = 1000
+)
+
+// errExit mirrors the error os/exec reports for a failed tmux invocation.
+var errExit = errors.New("exit status 1")
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithClock sets the time source used to schedule program output.
+func WithClock(c clock.Clock) Option {
+	return func(s *Server) {
+		s.clock = c
+	}
+}
+
+// WithPrompt sets the shell prompt printed in idle panes. Default: "$ ".
+func WithPrompt(prompt string) Option {
+	return func(s *Server) {
+		s.prompt = prompt
+	}
+}
+
+// WithPaneHeight sets the number of visible rows per pane; older rows count
+// as scrollback history. Default: 50.
+func WithPaneHeight(rows int) Option {
+	return func(s *Server) {
+		if rows > 0 {
+			s.height = rows
+		}
+	}
+}
+
+// WithProgram registers a program started when a pane runs name.
+func WithProgram(name string, program Program) Option {
+	return func(s *Server) {
+		s.programs[name] = program
+	}
+}
+
+// Server is an in-memory tmux server. It is safe for concurrent use.
+type Server struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	prompt   string
+	height   int
+	programs map[string]Program
+	sessions []*session
+	timers   []*timer
+	nextPane int
+	nextPID  int
+	nextSeq  int
+	commands []string
+	failures map[string]string
+}
+
+type session struct {
+	name     string
+	windows  []*window
+	active   *window
+	activity time.Time
+}
+
+type window struct {
+	session *session
+	index   int
+	name    string
+	panes   []*pane
+	active  *pane
+	zoomed  bool
+}
+
+type pane struct {
+	id     int
+	window *window
+	dir    string
+	pid    int
+	env    map[string]string
+	lines  []string
+	cursor string
+	input  string
+	proc   *Terminal
+	pipe   string
+	closed bool
+
+	width, height int // visible cells; resize-pane changes them
+}
+
+// NewServer creates an empty server. Like tmux, it reports "no server
+// running" until the first session is created.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		clock:    clock.Real(),
+		prompt:   defaultPrompt,
+		height:   defaultPaneHeight,
+		programs: make(map[string]Program),
+		nextPID:  firstPanePID,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.clock = clock.OrReal(s.clock)
+	return s
+}
+
+// Register adds or replaces the program started when a pane runs name.
+func (s *Server) Register(name string, program Program) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.programs[name] = program
+}
+
+// Exec runs a tmux command line as the shell would pass it to tmux.
+func (s *Server) Exec(ctx context.Context, cmd string) ([]byte, []byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, cmd)
+
+	args, err := splitWords(cmd)
+	if err != nil {
+		return nil, []byte("sh: " + err.Error()), errExit
+	}
+	if len(args) == 0 || args[0] != "tmux" {
+		return nil, []byte(fmt.Sprintf("tmuxtest: not a tmux command: %q", cmd)), errExit
+	}
+
+	var stdout strings.Builder
+	for _, command := range splitCommands(args[1:]) {
+		out, stderr := s.runLocked(command)
+		stdout.WriteString(out)
+		if stderr != "" {
+			return []byte(stdout.String()), []byte(stderr + "\n"), errExit
+		}
+	}
+	return []byte(stdout.String()), nil, nil
+}
+
+// splitCommands splits tmux arguments into a command sequence the way tmux
+// does: an argument ending in ";" ends a command and loses the semicolon,
+// unless it ends in "\;", which leaves a literal ";".
+func splitCommands(args []string) [][]string {
+	var commands [][]string
+	var current []string
+	for _, arg := range args {
+		if !strings.HasSuffix(arg, ";") {
+			current = append(current, arg)
+			continue
+		}
+		arg = strings.TrimSuffix(arg, ";")
+		if strings.HasSuffix(arg, `\`) {
+			current = append(current, strings.TrimSuffix(arg, `\`)+";")
+			continue
+		}
+		if arg != "" {
+			current = append(current, arg)
+		}
+		commands = append(commands, current)
+		current = nil
+	}
+	if len(current) > 0 || len(commands) == 0 {
+		commands = append(commands, current)
+	}
+	return commands
+}
+
+// Run executes a tmux command given as separate arguments, without the
+// leading "tmux". It is meant for seeding state and is not recorded in
+// Commands.
+func (s *Server) Run(args ...string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stdout, stderr := s.runLocked(args)
+	if stderr != "" {
+		return stdout, errors.New(stderr)
+	}
+	return stdout, nil
+}
+
+// FailCommand makes every later run of the named tmux command (such as
+// "kill-pane") fail with stderr and leave the server untouched, as when
+// tmux errors out or the process ignores the kill. An empty stderr
+// restores normal behavior.
+func (s *Server) FailCommand(name, stderr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stderr == "" {
+		delete(s.failures, name)
+		return
+	}
+	if s.failures == nil {
+		s.failures = make(map[string]string)
+	}
+	s.failures[name] = stderr
+}
+
+// Commands returns the command lines received by Exec, in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// ResetCommands clears the recorded command lines.
+func (s *Server) ResetCommands() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = nil
+}
+
+// PipeCommand returns the command a pane is piped into, or "" if none.
+func (s *Server) PipeCommand(target string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, errMsg := s.resolvePane(target)
+	if errMsg != "" {
+		return "", errors.New(errMsg)
+	}
+	return p.pipe, nil
+}
+
+// Capture returns the visible content of a pane, as capture-pane -p would.
+func (s *Server) Capture(target string) (string, error) {
+	return s.Run("capture-pane", "-p", "-t", target)
+}
+
+// Print writes text to a pane as if its foreground process printed it.
+func (s *Server) Print(target, text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fireTimersLocked()
+	p, errMsg := s.resolvePane(target)
+	if errMsg != "" {
+		return errors.New(errMsg)
+	}
+	p.print(text)
+	return nil
+}
+
+// runLocked dispatches one tmux command. Scheduled program output that has
+// come due is flushed before and after, so each call observes the pane as
+// it would look at that moment.
+func (s *Server) runLocked(args []string) (stdout, stderr string) {
+	if len(args) == 0 {
+		return "", "usage: tmux command [flags]"
+	}
+	s.fireTimersLocked()
+	defer s.fireTimersLocked()
+
+	name, rest := args[0], args[1:]
+	handler, ok := commandHandlers[name]
+	if !ok {
+		return "", "unknown command: " + name
+	}
+	spec := commandFlags[name]
+	fl, err := parseFlags(rest, spec)
+	if err != nil {
+		return "", fmt.Sprintf("command %s: %v", name, err)
+	}
+	if len(s.sessions) == 0 && name != "new-session" {
+		return "", NoServerMessage
+	}
+	if stderr, ok := s.failures[name]; ok {
+		return "", stderr
+	}
+	return handler(s, fl)
+}
+
+// =============================================================================
+// Command handlers
+// =============================================================================
+
+type commandHandler func(s *Server, fl flags) (stdout, stderr string)
+
+var commandHandlers map[string]commandHandler
+
+func init() {
+	commandHandlers = map[string]commandHandler{
+		"list-sessions":   (*Server).listSessions,
+		"has-session":     (*Server).hasSession,
+		"new-session":     (*Server).newSession,
+		"new-window":      (*Server).newWindow,
+		"list-windows":    (*Server).listWindows,
+		"list-panes":      (*Server).listPanes,
+		"select-window":   (*Server).selectWindow,
+		"select-layout":   (*Server).selectLayout,
+		"select-pane":     (*Server).selectPane,
+		"resize-pane":     (*Server).resizePane,
+		"kill-session":    (*Server).killSession,
+		"rename-session":  (*Server).renameSession,
+		"split-window":    (*Server).splitWindow,
+		"send-keys":       (*Server).sendKeys,
+		"capture-pane":    (*Server).capturePane,
+		"display-message": (*Server).displayMessage,
+		"kill-pane":       (*Server).killPane,
+		"kill-window":     (*Server).killWindow,
+		"move-pane":       (*Server).movePane,
+		"join-pane":       (*Server).movePane,
+		"kill-server":     (*Server).killServer,
+		"pipe-pane":       (*Server).pipePane,
+	}
+}
+
+// commandFlags lists, per command, the flags that take a value.
+var commandFlags = map[string]string{
+	"list-sessions":   "F",
+	"has-session":     "t",
+	"new-session":     "sncFxy",
+	"new-window":      "tncF",
+	"list-windows":    "tF",
+	"list-panes":      "tF",
+	"select-window":   "t",
+	"select-layout":   "t",
+	"select-pane":     "t",
+	"resize-pane":     "txy",
+	"kill-session":    "t",
+	"rename-session":  "t",
+	"split-window":    "tcFlp",
+	"send-keys":       "tN",
+	"capture-pane":    "tSEb",
+	"display-message": "tc",
+	"kill-pane":       "t",
+	"kill-window":     "t",
+	"move-pane":       "st",
+	"join-pane":       "st",
+	"kill-server":     "",
+	"pipe-pane":       "t",
+}
+
+func (s *Server) listSessions(fl flags) (string, string) {
+	format := fl.value("F", "#{session_name}: #{session_windows} windows")
+	var out strings.Builder
+	for _, sess := range s.sessions {
+		out.WriteString(s.format(format, sess.active.active) + "\n")
+	}
+	return out.String(), ""
+}
+
+func (s *Server) hasSession(fl flags) (string, string) {
+	_, errMsg := s.resolveSession(fl.value("t", ""))
+	return "", errMsg
+}
+
+func (s *Server) newSession(fl flags) (string, string) {
+	name := fl.value("s", "")
+	if name == "" {
+		for i := 0; ; i++ {
+			if s.findSession(strconv.Itoa(i)) == nil {
+				name = strconv.Itoa(i)
+				break
+			}
+		}
+	}
+	if strings.ContainsAny(name, ":.") {
+		return "", "invalid session: " + name
+	}
+	if s.findSession(name) != nil {
+		return "", "duplicate session: " + name
+	}
+
+	sess := &session{name: name, activity: s.clock.Now()}
+	s.sessions = append(s.sessions, sess)
+	w := s.addWindow(sess, 0, fl.value("n", ""), fl.value("c", ""))
+	return s.printCreated(fl, w.active), ""
+}
+
+func (s *Server) newWindow(fl flags) (string, string) {
+	target := fl.value("t", "")
+	sess, errMsg := s.resolveSession(target)
+	if errMsg != "" {
+		return "", errMsg
+	}
+
+	index := -1
+	if _, rest, ok := strings.Cut(target, ":"); ok && rest != "" {
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return "", "invalid window index: " + rest
+		}
+		if sess.window(n) != nil {
+			return "", fmt.Sprintf("create window failed: index %d in use", n)
+		}
+		index = n
+	}
+	if index < 0 {
+		for index = 0; sess.window(index) != nil; index++ {
+		}
+	}
+
+	w := s.addWindow(sess, index, fl.value("n", ""), fl.value("c", ""))
+	if fl.has("d") {
+		return s.printCreated(fl, w.active), ""
+	}
+	sess.active = w
+	return s.printCreated(fl, w.active), ""
+}
+
+func (s *Server) listWindows(fl flags) (string, string) {
+	sess, errMsg := s.resolveSession(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	format := fl.value("F", "#{window_index}: #{window_name} (#{window_panes} panes)")
+	var out strings.Builder
+	for _, w := range sess.windows {
+		out.WriteString(s.format(format, w.active) + "\n")
+	}
+	return out.String(), ""
+}
+
+// listPanes follows tmux: a session target lists only its current window
+// unless -s is given, and -a lists every pane on the server.
+func (s *Server) listPanes(fl flags) (string, string) {
+	var panes []*pane
+	switch {
+	case fl.has("a"):
+		for _, sess := range s.sessions {
+			panes = append(panes, sess.panes()...)
+		}
+	case fl.has("s"):
+		sess, errMsg := s.resolveSession(fl.value("t", ""))
+		if errMsg != "" {
+			return "", errMsg
+		}
+		panes = sess.panes()
+	default:
+		w, errMsg := s.resolveWindow(fl.value("t", ""))
+		if errMsg != "" {
+			return "", errMsg
+		}
+		panes = w.panes
+	}
+
+	format := fl.value("F", "#{pane_index}: [80x#{pane_height}] #{pane_id}")
+	var out strings.Builder
+	for _, p := range panes {
+		out.WriteString(s.format(format, p) + "\n")
+	}
+	return out.String(), ""
+}
+
+func (s *Server) selectWindow(fl flags) (string, string) {
+	w, errMsg := s.resolveWindow(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	w.session.active = w
+	return "", ""
+}
+
+func (s *Server) selectLayout(fl flags) (string, string) {
+	w, errMsg := s.resolveWindow(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	if len(fl.args) > 0 && !knownLayouts[fl.args[0]] {
+		return "", "invalid layout: " + fl.args[0]
+	}
+	w.zoomed = false
+	return "", ""
+}
+
+var knownLayouts = map[string]bool{
+	"even-horizontal": true,
+	"even-vertical":   true,
+	"main-horizontal": true,
+	"main-vertical":   true,
+	"tiled":           true,
+}
+
+func (s *Server) selectPane(fl flags) (string, string) {
+	p, errMsg := s.resolvePane(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	// Like tmux, moving to another pane unzooms the window.
+	if p.window.active != p {
+		p.window.zoomed = false
+	}
+	p.window.active = p
+	p.window.session.active = p.window
+	return "", ""
+}
+
+// resizePane supports -Z, which toggles zoom on the target pane, and
+// absolute sizes with -x and -y.
+func (s *Server) resizePane(fl flags) (string, string) {
+	p, errMsg := s.resolvePane(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	if fl.has("Z") {
+		p.window.active = p
+		p.window.zoomed = !p.window.zoomed
+		return "", ""
+	}
+	width, height := p.width, p.height
+	for flag, size := range map[string]*int{"x": &width, "y": &height} {
+		if raw, ok := fl.values[flag]; ok {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n <= 0 {
+				return "", "size invalid: " + raw
+			}
+			*size = n
+		}
+	}
+	p.width, p.height = width, height
+	return "", ""
+}
+
+func (s *Server) killSession(fl flags) (string, string) {
+	sess, errMsg := s.resolveSession(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	for _, p := range sess.panes() {
+		p.close()
+	}
+	s.removeSession(sess)
+	return "", ""
+}
+
+func (s *Server) renameSession(fl flags) (string, string) {
+	sess, errMsg := s.resolveSession(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	if len(fl.args) == 0 {
+		return "", "usage: rename-session [-t target-session] new-name"
+	}
+	name := fl.args[0]
+	if other := s.findSession(name); other != nil && other != sess {
+		return "", "duplicate session: " + name
+	}
+	sess.name = name
+	return "", ""
+}
+
+func (s *Server) splitWindow(fl flags) (string, string) {
+	target, errMsg := s.resolvePane(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	w := target.window
+
+	dir := fl.value("c", target.dir)
+	p := s.newPane(w, dir)
+	at := w.indexOf(target) + 1
+	w.panes = append(w.panes[:at], append([]*pane{p}, w.panes[at:]...)...)
+	if !fl.has("d") {
+		w.active = p
+	}
+	return s.printCreated(fl, p), ""
+}
+
+func (s *Server) sendKeys(fl flags) (string, string) {
+	p, errMsg := s.resolvePane(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	p.window.session.activity = s.clock.Now()
+
+	if fl.has("l") {
+		s.typeText(p, strings.Join(fl.args, ""))
+		return "", ""
+	}
+	for _, key := range fl.args {
+		if p.closed {
+			break
+		}
+		switch key {
+		case "Enter", "C-m", "KPEnter":
+			s.enter(p)
+		case "C-c":
+			s.interrupt(p)
+		case "C-u":
+			p.input = ""
+		case "BSpace", "C-h":
+			if n := len(p.input); n > 0 {
+				p.input = p.input[:n-1]
+			}
+		case "Escape", "Tab", "Up", "Down", "Left", "Right", "Home", "End", "C-d", "C-l":
+			// Navigation and control keys do not change the buffer.
+		default:
+			s.typeText(p, key)
+		}
+	}
+	return "", ""
+}
+
+func (s *Server) capturePane(fl flags) (string, string) {
+	p, errMsg := s.resolvePane(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	if !fl.has("p") {
+		return "", ""
+	}
+
+	rows := p.rows()
+	visibleStart := len(rows) - p.height
+	if visibleStart < 0 {
+		visibleStart = 0
+	}
+	start, end := visibleStart, len(rows)-1
+	if v, ok := fl.values["S"]; ok {
+		if v == "-" {
+			start = 0
+		} else if n, err := strconv.Atoi(v); err == nil {
+			start = visibleStart + n
+		}
+	}
+	if v, ok := fl.values["E"]; ok && v != "-" {
+		if n, err := strconv.Atoi(v); err == nil {
+			end = visibleStart + n
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(rows)-1 {
+		end = len(rows) - 1
+	}
+
+	var out strings.Builder
+	for i := start; i <= end; i++ {
+		out.WriteString(rows[i] + "\n")
+	}
+	return out.String(), ""
+}
+
+func (s *Server) displayMessage(fl flags) (string, string) {
+	p, errMsg := s.resolvePane(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	out := s.format(strings.Join(fl.args, " "), p)
+	if fl.has("p") {
+		return out + "\n", ""
+	}
+	return "", ""
+}
+
+func (s *Server) killPane(fl flags) (string, string) {
+	p, errMsg := s.resolvePane(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	s.removePane(p)
+	return "", ""
+}
+
+func (s *Server) killWindow(fl flags) (string, string) {
+	w, errMsg := s.resolveWindow(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	for _, p := range append([]*pane(nil), w.panes...) {
+		s.removePane(p)
+	}
+	return "", ""
+}
+
+// movePane moves -s next to the -t pane; move-pane and join-pane behave
+// the same here.
+func (s *Server) movePane(fl flags) (string, string) {
+	src, errMsg := s.resolvePane(fl.value("s", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	dst, errMsg := s.resolvePane(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	if src == dst {
+		return "", "source and target panes must be different"
+	}
+
+	s.detachPane(src)
+	w := dst.window
+	at := w.indexOf(dst) + 1
+	w.panes = append(w.panes[:at], append([]*pane{src}, w.panes[at:]...)...)
+	src.window = w
+	if !fl.has("d") {
+		w.active = src
+		w.session.active = w
+	}
+	return "", ""
+}
+
+// pipePane records the pipe command without running it; PipeCommand
+// reports it. No command closes the pipe.
+func (s *Server) pipePane(fl flags) (string, string) {
+	p, errMsg := s.resolvePane(fl.value("t", ""))
+	if errMsg != "" {
+		return "", errMsg
+	}
+	p.pipe = strings.Join(fl.args, " ")
+	return "", ""
+}
+
+func (s *Server) killServer(fl flags) (string, string) {
+	for _, sess := range s.sessions {
+		for _, p := range sess.panes() {
+			p.close()
+		}
+	}
+	s.sessions = nil
+	return "", ""
+}
+
+// printCreated renders -P output for a newly created pane.
+func (s *Server) printCreated(fl flags, p *pane) string {
+	if !fl.has("P") {
+		return ""
+	}
+	return s.format(fl.value("F", "#{session_name}:#{window_index}.#{pane_index}"), p) + "\n"
+}
+
+// =============================================================================
+// Targets and state
+// =============================================================================
+
+func (s *Server) findSession(name string) *session {
+	for _, sess := range s.sessions {
+		if sess.name == name {
+			return sess
+		}
+	}
+	return nil
+}
+
+func (s *Server) findPane(id string) *pane {
+	for _, sess := range s.sessions {
+		for _, p := range sess.panes() {
+			if "%"+strconv.Itoa(p.id) == id {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// resolveSession resolves the session part of a target. A pane ID resolves
+// to the session holding that pane.
+func (s *Server) resolveSession(target string) (*session, string) {
+	if strings.HasPrefix(target, "%") {
+		p := s.findPane(target)
+		if p == nil {
+			return nil, "can't find pane: " + target
+		}
+		return p.window.session, ""
+	}
+	name, _, _ := strings.Cut(target, ":")
+	name = strings.TrimPrefix(name, "=")
+	if name == "" {
+		return s.sessions[len(s.sessions)-1], ""
+	}
+	sess := s.findSession(name)
+	if sess == nil {
+		return nil, "can't find session: " + name
+	}
+	return sess, ""
+}
+
+// resolveWindow resolves "session", "session:window", or a pane ID to a
+// window; a window may be named by index or name.
+func (s *Server) resolveWindow(target string) (*window, string) {
+	if strings.HasPrefix(target, "%") {
+		p := s.findPane(target)
+		if p == nil {
+			return nil, "can't find pane: " + target
+		}
+		return p.window, ""
+	}
+	sess, errMsg := s.resolveSession(target)
+	if errMsg != "" {
+		return nil, errMsg
+	}
+	_, rest, _ := strings.Cut(target, ":")
+	windowPart, _, _ := strings.Cut(rest, ".")
+	if windowPart == "" {
+		return sess.active, ""
+	}
+	if n, err := strconv.Atoi(windowPart); err == nil {
+		if w := sess.window(n); w != nil {
+			return w, ""
+		}
+	}
+	for _, w := range sess.windows {
+		if w.name == windowPart {
+			return w, ""
+		}
+	}
+	return nil, "can't find window: " + windowPart
+}
+
+// resolvePane resolves "%id", "session", "session:window", or
+// "session:window.pane" to a pane, defaulting to the active one.
+func (s *Server) resolvePane(target string) (*pane, string) {
+	if strings.HasPrefix(target, "%") {
+		p := s.findPane(target)
+		if p == nil {
+			return nil, "can't find pane: " + target
+		}
+		return p, ""
+	}
+	w, errMsg := s.resolveWindow(target)
+	if errMsg != "" {
+		return nil, errMsg
+	}
+	_, rest, _ := strings.Cut(target, ":")
+	_, panePart, ok := strings.Cut(rest, ".")
+	if !ok || panePart == "" {
+		return w.active, ""
+	}
+	n, err := strconv.Atoi(panePart)
+	if err != nil || n < 0 || n >= len(w.panes) {
+		return nil, "can't find pane: " + panePart
+	}
+	return w.panes[n], ""
+}
+
+func (s *Server) addWindow(sess *session, index int, name, dir string) *window {
+	w := &window{session: sess, index: index, name: name}
+	if w.name == "" {
+		w.name = "bash"
+	}
+	p := s.newPane(w, dir)
+	w.panes = []*pane{p}
+	w.active = p
+
+	sess.windows = append(sess.windows, w)
+	sort.Slice(sess.windows, func(i, j int) bool { return sess.windows[i].index < sess.windows[j].index })
+	if sess.active == nil {
+		sess.active = w
+	}
+	return w
+}
+
+func (s *Server) newPane(w *window, dir string) *pane {
+	if dir == "" {
+		dir = "/"
+	}
+	p := &pane{
+		id:     s.nextPane,
+		window: w,
+		dir:    dir,
+		pid:    s.nextPID,
+		env:    make(map[string]string),
+		cursor: s.prompt,
+		width:  defaultPaneWidth,
+		height: s.height,
+	}
+	s.nextPane++
+	s.nextPID++
+	return p
+}
+
+// removePane closes a pane, dropping its window and session once empty.
+func (s *Server) removePane(p *pane) {
+	p.close()
+	s.detachPane(p)
+}
+
+// detachPane takes a pane out of its window without closing it, dropping
+// the window and session once empty.
+func (s *Server) detachPane(p *pane) {
+	w := p.window
+	i := w.indexOf(p)
+	if i < 0 {
+		return
+	}
+	w.panes = append(w.panes[:i], w.panes[i+1:]...)
+	if len(w.panes) > 0 {
+		if w.active == p {
+			w.active = w.panes[max(i-1, 0)]
+		}
+		return
+	}
+
+	sess := w.session
+	for j, candidate := range sess.windows {
+		if candidate == w {
+			sess.windows = append(sess.windows[:j], sess.windows[j+1:]...)
+			break
+		}
+	}
+	if len(sess.windows) == 0 {
+		s.removeSession(sess)
+		return
+	}
+	if sess.active == w {
+		sess.active = sess.windows[0]
+	}
+}
+
+func (s *Server) removeSession(sess *session) {
+	for i, candidate := range s.sessions {
+		if candidate == sess {
+			s.sessions = append(s.sessions[:i], s.sessions[i+1:]...)
+			return
+		}
+	}
+}
+
+func (sess *session) window(index int) *window {
+	for _, w := range sess.windows {
+		if w.index == index {
+			return w
+		}
+	}
+	return nil
+}
+
+func (sess *session) panes() []*pane {
+	var panes []*pane
+	for _, w := range sess.windows {
+		panes = append(panes, w.panes...)
+	}
+	return panes
+}
+
+func (w *window) indexOf(p *pane) int {
+	for i, candidate := range w.panes {
+		if candidate == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// rows returns the pane's lines plus the line holding the cursor.
+func (p *pane) rows() []string {
+	return append(append([]string(nil), p.lines...), p.cursor+p.input)
+}
+
+// print appends output at the cursor, splitting it into lines.
+func (p *pane) print(text string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	parts := strings.Split(text, "\n")
+	for i, part := range parts {
+		if i > 0 {
+			p.lines = append(p.lines, p.cursor)
+			p.cursor = ""
+		}
+		p.cursor += part
+	}
+}
+
+// close stops the pane's program so its scheduled output is dropped.
+func (p *pane) close() {
+	p.closed = true
+	if p.proc != nil {
+		p.proc.exited = true
+		p.proc = nil
+	}
+}
+
+// =============================================================================
+// Formats
+// =============================================================================
+
+var formatVar = regexp.MustCompile(`#\{([a-z_]+)\}`)
+
+// format expands #{...} variables for a pane and its window and session.
+func (s *Server) format(format string, p *pane) string {
+	return formatVar.ReplaceAllStringFunc(format, func(match string) string {
+		return s.formatValue(match[2:len(match)-1], p)
+	})
+}
+
+func (s *Server) formatValue(name string, p *pane) string {
+	w := p.window
+	sess := w.session
+	switch name {
+	case "session_name":
+		return sess.name
+	case "session_windows":
+		return strconv.Itoa(len(sess.windows))
+	case "session_activity":
+		return strconv.FormatInt(sess.activity.Unix(), 10)
+	case "session_attached":
+		return "0"
+	case "window_index":
+		return strconv.Itoa(w.index)
+	case "window_name":
+		return w.name
+	case "window_active":
+		return boolFormat(sess.active == w)
+	case "window_panes":
+		return strconv.Itoa(len(w.panes))
+	case "window_zoomed_flag":
+		return boolFormat(w.zoomed)
+	case "pane_id":
+		return "%" + strconv.Itoa(p.id)
+	case "pane_index":
+		return strconv.Itoa(w.indexOf(p))
+	case "pane_active":
+		return boolFormat(w.active == p)
+	case "pane_current_path":
+		return p.dir
+	case "pane_current_command":
+		if p.proc != nil {
+			return p.proc.name
+		}
+		return "bash"
+	case "pane_pid":
+		return strconv.Itoa(p.pid)
+	case "pane_dead":
+		return "0"
+	case "pane_width":
+		return strconv.Itoa(p.width)
+	case "pane_height":
+		return strconv.Itoa(p.height)
+	case "history_size":
+		return strconv.Itoa(max(len(p.rows())-p.height, 0))
+	default:
+		return ""
+	}
+}
+
+func boolFormat(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// =============================================================================
+// Timers
+// =============================================================================
+
+// timer is program output scheduled on the server clock.
+type timer struct {
+	due  time.Duration
+	seq  int
+	term *Terminal
+	fn   func()
+}
+
+func (s *Server) schedule(term *Terminal, d time.Duration, fn func()) {
+	s.nextSeq++
+	s.timers = append(s.timers, &timer{
+		due:  s.clock.Monotonic() + d,
+		seq:  s.nextSeq,
+		term: term,
+		fn:   fn,
+	})
+}
+
+// fireTimersLocked runs due timers in order. Timers of programs that have
+// exited are dropped.
+func (s *Server) fireTimersLocked() {
+	for {
+		now := s.clock.Monotonic()
+		next := -1
+		for i, t := range s.timers {
+			if t.due > now {
+				continue
+			}
+			if next < 0 || t.due < s.timers[next].due || (t.due == s.timers[next].due && t.seq < s.timers[next].seq) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return
+		}
+		t := s.timers[next]
+		s.timers = append(s.timers[:next], s.timers[next+1:]...)
+		if !t.term.exited {
+			t.fn()
+		}
+	}
+}
+
+// =============================================================================
+// Argument parsing
+// =============================================================================
+
+// flags holds parsed command flags and positional arguments.
+type flags struct {
+	bools  map[string]bool
+	values map[string]string
+	args   []string
+}
+
+func (f flags) has(name string) bool {
+	return f.bools[name]
+}
+
+func (f flags) value(name, fallback string) string {
+	if v, ok := f.values[name]; ok {
+		return v
+	}
+	return fallback
+}
+
+// parseFlags parses getopt-style flags; valueFlags lists those taking an
+// argument. Parsing stops at the first positional argument.
+func parseFlags(args []string, valueFlags string) (flags, error) {
+	fl := flags{bools: make(map[string]bool), values: make(map[string]string)}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			fl.args = append(fl.args, args[i+1:]...)
+			return fl, nil
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			fl.args = append(fl.args, args[i:]...)
+			return fl, nil
+		}
+		for j := 1; j < len(arg); j++ {
+			name := string(arg[j])
+			if !strings.Contains(valueFlags, name) {
+				fl.bools[name] = true
+				continue
+			}
+			if j+1 < len(arg) {
+				fl.values[name] = arg[j+1:]
+			} else if i+1 < len(args) {
+				i++
+				fl.values[name] = args[i]
+			} else {
+				return fl, fmt.Errorf("-%s expects an argument", name)
+			}
+			break
+		}
+	}
+	return fl, nil
+}
+
+// splitWords splits a command line the way sh would for the quoting the
+// tmux client produces: single quotes, double quotes, and backslashes.
+func splitWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated quoted string")
+			}
+			word.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) && strings.IndexByte("\"\\$`", line[i+1]) >= 0 {
+					i++
+				}
+				word.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, errors.New("unterminated quoted string")
+			}
+			inWord = true
+		case c == '\\' && i+1 < len(line):
+			i++
+			word.WriteByte(line[i])
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,414 @@
+package tmuxtest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+func TestServerSessionLifecycle(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer()
+	client := tmux.NewClient(srv)
+
+	sessions, err := client.ListSessions(ctx)
+	if err != nil || len(sessions) != 0 {
+		t.Fatalf("expected no sessions without a server, got %v (err=%v)", sessions, err)
+	}
+	if _, stderr, err := srv.Exec(ctx, "tmux list-sessions"); err == nil || !strings.Contains(string(stderr), "no server running") {
+		t.Fatalf("expected no server stderr, got %q (err=%v)", stderr, err)
+	}
+
+	if err := client.NewSession(ctx, "work", "/repo"); err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	if err := client.NewSession(ctx, "work", "/repo"); !errors.Is(err, tmux.ErrSessionExists) {
+		t.Fatalf("expected ErrSessionExists, got %v", err)
+	}
+	if ok, err := client.HasSession(ctx, "missing"); err != nil || ok {
+		t.Fatalf("expected missing session, got %v (err=%v)", ok, err)
+	}
+	if _, stderr, _ := srv.Exec(ctx, "tmux has-session -t missing"); !strings.Contains(string(stderr), "can't find session: missing") {
+		t.Fatalf("unexpected stderr %q", stderr)
+	}
+
+	if err := client.RenameSession(ctx, "work", "renamed"); err != nil {
+		t.Fatalf("RenameSession failed: %v", err)
+	}
+	sessions, err = client.ListSessions(ctx)
+	if err != nil || len(sessions) != 1 || sessions[0].Name != "renamed" || sessions[0].WindowCount != 1 {
+		t.Fatalf("unexpected sessions %+v (err=%v)", sessions, err)
+	}
+
+	if err := client.KillSession(ctx, "renamed"); err != nil {
+		t.Fatalf("KillSession failed: %v", err)
+	}
+	if ok, err := client.HasSession(ctx, "renamed"); err != nil || ok {
+		t.Fatalf("expected session gone, got %v (err=%v)", ok, err)
+	}
+}
+
+func TestServerPanesAndTargets(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer()
+	client := tmux.NewClient(srv)
+
+	if err := client.NewSession(ctx, "ws", "/repo"); err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	if err := client.NewWindow(ctx, "ws", tmux.AgentWindowName, "/repo"); err != nil {
+		t.Fatalf("NewWindow failed: %v", err)
+	}
+	paneID, err := client.SplitWindow(ctx, "ws:"+tmux.AgentWindowName, false, "/repo/sub")
+	if err != nil {
+		t.Fatalf("SplitWindow failed: %v", err)
+	}
+	if paneID != "%2" {
+		t.Fatalf("expected pane %%2, got %q", paneID)
+	}
+
+	// Like tmux, a session target lists the current window only.
+	panes, err := client.ListPanes(ctx, "ws")
+	if err != nil {
+		t.Fatalf("ListPanes failed: %v", err)
+	}
+	if len(panes) != 2 || panes[1].ID != paneID || panes[1].WindowIndex != 1 || panes[1].Index != 1 || !panes[1].Active || panes[1].CurrentDir != "/repo/sub" {
+		t.Fatalf("unexpected panes %+v", panes)
+	}
+
+	for _, target := range []string{"%2", "ws:1.1", "ws:agents.1", "ws:1", "ws"} {
+		if _, err := client.CapturePane(ctx, target, false); err != nil {
+			t.Fatalf("capture %q failed: %v", target, err)
+		}
+	}
+	if _, stderr, err := srv.Exec(ctx, "tmux capture-pane -t '%9' -p"); err == nil || !strings.Contains(string(stderr), "can't find pane: %9") {
+		t.Fatalf("expected missing pane stderr, got %q", stderr)
+	}
+
+	if pid, err := client.GetPanePID(ctx, paneID); err != nil || pid <= 0 {
+		t.Fatalf("expected pane pid, got %d (err=%v)", pid, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := client.ZoomPane(ctx, paneID); err != nil {
+			t.Fatalf("ZoomPane failed: %v", err)
+		}
+	}
+	if panes, _ := client.ListPanes(ctx, "ws"); len(panes) != 2 || !panes[1].Zoomed {
+		t.Fatalf("expected a zoomed window after zooming twice, got %+v", panes)
+	}
+	if err := client.SelectLayout(ctx, "ws:agents", "tiled"); err != nil {
+		t.Fatalf("SelectLayout failed: %v", err)
+	}
+	if panes, _ := client.ListPanes(ctx, "ws"); panes[1].Zoomed {
+		t.Fatalf("expected select-layout to unzoom, got %+v", panes)
+	}
+	if err := client.ResizePane(ctx, paneID, 0, 30); err != nil {
+		t.Fatalf("ResizePane failed: %v", err)
+	}
+	if w, h, err := client.PaneSize(ctx, paneID); err != nil || w != 80 || h != 30 {
+		t.Fatalf("expected an 80x30 pane, got %dx%d (%v)", w, h, err)
+	}
+
+	if err := client.KillPane(ctx, paneID); err != nil {
+		t.Fatalf("KillPane failed: %v", err)
+	}
+	if _, err := client.CapturePane(ctx, paneID, false); err == nil {
+		t.Fatal("expected capture of killed pane to fail")
+	}
+}
+
+func TestServerKillPaneVerified(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer()
+	client := tmux.NewClient(srv)
+
+	if err := client.NewSession(ctx, "ws", "/repo"); err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	if err := client.NewWindow(ctx, "ws", tmux.AgentWindowName, "/repo"); err != nil {
+		t.Fatalf("NewWindow failed: %v", err)
+	}
+	first, _ := client.SplitWindow(ctx, "ws:agents", false, "/repo")
+	second, _ := client.SplitWindow(ctx, "ws:agents", false, "/repo")
+
+	// Killing ws:agents.1 renumbers the window so that target now names
+	// the next pane; verification must follow the original pane ID.
+	if err := client.KillPaneVerified(ctx, "ws:agents.1", false); err != nil {
+		t.Fatalf("KillPaneVerified failed: %v", err)
+	}
+	if ok, _ := client.PaneExists(ctx, first); ok {
+		t.Fatalf("expected %s killed", first)
+	}
+	if ok, _ := client.PaneExists(ctx, second); !ok {
+		t.Fatalf("expected %s to survive", second)
+	}
+	if err := client.KillPaneVerified(ctx, first, false); err != nil {
+		t.Fatalf("expected killing a gone pane to succeed, got %v", err)
+	}
+
+	srv.FailCommand("kill-pane", "operation not permitted")
+	if err := client.KillPaneVerified(ctx, second, false); !errors.Is(err, tmux.ErrPaneStillAlive) {
+		t.Fatalf("expected ErrPaneStillAlive, got %v", err)
+	}
+	if ok, _ := client.PaneExists(ctx, second); !ok {
+		t.Fatalf("expected %s to survive the failed kill", second)
+	}
+
+	// Force never kills a window the pane shares with other panes.
+	if err := client.KillPaneVerified(ctx, second, true); !errors.Is(err, tmux.ErrPaneStillAlive) {
+		t.Fatalf("expected ErrPaneStillAlive for a pane sharing its window, got %v", err)
+	}
+	if ok, _ := client.PaneExists(ctx, "ws:agents.0"); !ok {
+		t.Fatal("expected the sibling pane to survive")
+	}
+
+	// Alone in its window, force falls back to killing the window; other
+	// windows stay.
+	srv.FailCommand("kill-pane", "")
+	if err := client.KillPane(ctx, "ws:agents.0"); err != nil {
+		t.Fatalf("KillPane failed: %v", err)
+	}
+	srv.FailCommand("kill-pane", "operation not permitted")
+	if err := client.KillPaneVerified(ctx, second, true); err != nil {
+		t.Fatalf("forced KillPaneVerified failed: %v", err)
+	}
+	if ok, _ := client.PaneExists(ctx, second); ok {
+		t.Fatalf("expected %s killed with its window", second)
+	}
+	if ok, err := client.PaneExists(ctx, "ws:0"); err != nil || !ok {
+		t.Fatalf("expected window 0 to survive, got %v (err=%v)", ok, err)
+	}
+}
+
+func TestServerMovePane(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer()
+	client := tmux.NewClient(srv)
+
+	for _, session := range []string{"src", "dst"} {
+		if err := client.NewSession(ctx, session, "/repo"); err != nil {
+			t.Fatalf("NewSession failed: %v", err)
+		}
+	}
+	paneID, err := client.SplitWindow(ctx, "src", false, "/repo")
+	if err != nil {
+		t.Fatalf("SplitWindow failed: %v", err)
+	}
+	if err := srv.Print(paneID, "still here\n"); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+
+	if err := client.MovePane(ctx, paneID, "dst"); err != nil {
+		t.Fatalf("MovePane failed: %v", err)
+	}
+	panes, err := client.ListPanes(ctx, "dst")
+	if err != nil {
+		t.Fatalf("ListPanes failed: %v", err)
+	}
+	if len(panes) != 2 || panes[1].ID != paneID || panes[1].Active {
+		t.Fatalf("expected %s moved into dst without focus, got %+v", paneID, panes)
+	}
+	if out, err := client.CapturePane(ctx, paneID, false); err != nil || !strings.Contains(out, "still here") {
+		t.Fatalf("expected moved pane to keep its output, got %q (err=%v)", out, err)
+	}
+	if panes, _ := client.ListPanes(ctx, "src"); len(panes) != 1 {
+		t.Fatalf("expected pane to leave src, got %+v", panes)
+	}
+
+	if err := client.MovePane(ctx, "%9", "dst"); err != tmux.ErrPaneNotFound {
+		t.Fatalf("expected ErrPaneNotFound for missing source, got %v", err)
+	}
+}
+
+func TestServerRunsPrograms(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var gotArgs []string
+	var gotEnv string
+	srv := NewServer(WithClock(fake), WithProgram("agent", func(term *Terminal) {
+		gotArgs = term.Args()
+		gotEnv = term.Env("AGENT_MODEL")
+		term.Print("booting\n")
+		term.PrintAfter(time.Second, "agent> ")
+		term.OnInput(func(line string) {
+			term.Print("thinking about " + line + "\n")
+			term.PrintAfter(2*time.Second, "done\nagent> ")
+		})
+	}))
+	client := tmux.NewClient(srv)
+
+	if err := client.NewSession(ctx, "ws", "/repo"); err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	if err := client.SendKeys(ctx, "ws", "AGENT_MODEL='m1' 'agent' '--flag'", true, true); err != nil {
+		t.Fatalf("SendKeys failed: %v", err)
+	}
+	if len(gotArgs) != 1 || gotArgs[0] != "--flag" || gotEnv != "m1" {
+		t.Fatalf("unexpected program args %v env %q", gotArgs, gotEnv)
+	}
+
+	capture := func() string {
+		t.Helper()
+		out, err := client.CapturePane(ctx, "ws", false)
+		if err != nil {
+			t.Fatalf("CapturePane failed: %v", err)
+		}
+		return out
+	}
+
+	if out := capture(); strings.Contains(out, "agent>") {
+		t.Fatalf("expected prompt to be pending, got %q", out)
+	}
+	fake.Advance(time.Second)
+	if out := capture(); !strings.HasSuffix(out, "booting\nagent> \n") {
+		t.Fatalf("expected agent prompt, got %q", out)
+	}
+
+	if err := client.SendKeys(ctx, "ws", "fix it", true, true); err != nil {
+		t.Fatalf("SendKeys failed: %v", err)
+	}
+	if out := capture(); !strings.Contains(out, "agent> fix it\nthinking about fix it\n") {
+		t.Fatalf("expected echoed input and reply, got %q", out)
+	}
+	fake.Advance(2 * time.Second)
+	if out := capture(); !strings.HasSuffix(out, "done\nagent> \n") {
+		t.Fatalf("expected scripted reply, got %q", out)
+	}
+
+	// Ctrl+C ends the program and drops its pending output.
+	if err := client.SendKeys(ctx, "ws", "again", true, true); err != nil {
+		t.Fatalf("SendKeys failed: %v", err)
+	}
+	if err := client.SendInterrupt(ctx, "ws"); err != nil {
+		t.Fatalf("SendInterrupt failed: %v", err)
+	}
+	fake.Advance(time.Minute)
+	out := capture()
+	if strings.Contains(out, "done\nagent> \n$") || !strings.HasSuffix(out, "^C\n$ \n") {
+		t.Fatalf("expected shell prompt after interrupt, got %q", out)
+	}
+
+	panes, err := client.ListPanes(ctx, "ws")
+	if err != nil || len(panes) != 1 || panes[0].Command != "bash" {
+		t.Fatalf("expected shell in foreground, got %+v (err=%v)", panes, err)
+	}
+}
+
+func TestServerSendKeysDeliversTextVerbatim(t *testing.T) {
+	ctx := context.Background()
+	var lines []string
+	srv := NewServer(WithProgram("agent", func(term *Terminal) {
+		term.OnInput(func(line string) {
+			lines = append(lines, line)
+		})
+	}))
+	client := tmux.NewClient(srv)
+	if err := client.NewSession(ctx, "ws", "/repo"); err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	if err := client.SendKeys(ctx, "ws", "agent", true, true); err != nil {
+		t.Fatalf("SendKeys failed: %v", err)
+	}
+
+	texts := []string{
+		"-v flag isn't working, please fix",
+		"run the tests;",
+		"make; make test",
+		"don't stop",
+		`ends in a backslash-semicolon \;`,
+		"-n isn't set; try again;",
+		";",
+	}
+	for _, text := range texts {
+		if err := client.SendKeys(ctx, "ws", text, true, true); err != nil {
+			t.Fatalf("SendKeys(%q) failed: %v", text, err)
+		}
+	}
+	if len(lines) != len(texts) {
+		t.Fatalf("expected %d input lines, got %q", len(texts), lines)
+	}
+	for i, text := range texts {
+		if lines[i] != text {
+			t.Errorf("line %d: got %q, want %q", i, lines[i], text)
+		}
+	}
+}
+
+func TestServerCommandSequences(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer()
+	if _, err := srv.Run("new-session", "-d", "-s", "ws"); err != nil {
+		t.Fatalf("new-session failed: %v", err)
+	}
+
+	// An argument ending in ";" runs the rest as a second command.
+	if _, stderr, err := srv.Exec(ctx, `tmux send-keys -t ws -l 'abc;' send-keys -t ws -l 'def\;'`); err != nil {
+		t.Fatalf("Exec failed: %v (%s)", err, stderr)
+	}
+	if out, _ := srv.Capture("ws"); !strings.Contains(out, "$ abcdef;") {
+		t.Fatalf("expected both commands typed, got %q", out)
+	}
+}
+
+func TestServerShell(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer()
+	client := tmux.NewClient(srv)
+
+	if err := client.NewSession(ctx, "ws", "/repo"); err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	for _, line := range []string{`export GREETING="hi there"`, `echo "it's"`, "nope --x"} {
+		if err := client.SendKeys(ctx, "ws", line, true, true); err != nil {
+			t.Fatalf("SendKeys failed: %v", err)
+		}
+	}
+
+	out, err := srv.Capture("ws")
+	if err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	want := "$ export GREETING=\"hi there\"\n$ echo \"it's\"\nit's\n$ nope --x\nbash: nope: command not found\n$ \n"
+	if out != want {
+		t.Fatalf("capture = %q, want %q", out, want)
+	}
+
+	if err := client.SendKeys(ctx, "ws", "exit", true, true); err != nil {
+		t.Fatalf("SendKeys failed: %v", err)
+	}
+	if ok, _ := client.HasSession(ctx, "ws"); ok {
+		t.Fatal("expected session to close with its last shell")
+	}
+}
+
+func TestServerCaptureHistory(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(WithPaneHeight(3))
+	client := tmux.NewClient(srv)
+
+	if err := client.NewSession(ctx, "ws", "/repo"); err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	if err := srv.Print("ws", "one\ntwo\nthree\nfour\n"); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+
+	visible, err := client.CapturePane(ctx, "ws", false)
+	if err != nil || visible != "three\nfour\n\n" {
+		t.Fatalf("visible = %q (err=%v)", visible, err)
+	}
+	if size, err := client.HistorySize(ctx, "ws"); err != nil || size != 2 {
+		t.Fatalf("history size = %d (err=%v)", size, err)
+	}
+	full, err := client.CapturePane(ctx, "ws", true)
+	if err != nil || full != "$ one\ntwo\nthree\nfour\n\n" {
+		t.Fatalf("history = %q (err=%v)", full, err)
+	}
+}
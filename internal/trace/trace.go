@@ -0,0 +1,91 @@
+// Package trace carries correlation IDs through a multi-step flow, so the
+// log lines and events of one CLI command or RPC can be found together.
+//
+// A trace ID is started at each entry point (a CLI command, an RPC, a
+// scheduler dispatch) unless the caller already has one, and travels in
+// the context from there: logging adds it to log lines, the event log
+// stores it with events, and the gRPC interceptors pass it to swarmd.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+)
+
+// EnvVar names the environment variable a CLI command takes its trace ID
+// from, so a script can use one ID across several commands.
+const EnvVar = "SWARM_TRACE_ID"
+
+// MetadataKey is the gRPC metadata key that carries the trace ID.
+const MetadataKey = "x-swarm-trace-id"
+
+// maxIDLength bounds trace IDs accepted from callers.
+const maxIDLength = 64
+
+type ctxKey struct{}
+
+// New returns a new random trace ID.
+func New() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms.
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether id can be used as a trace ID: 1 to 64 letters,
+// digits, dots, dashes, or underscores.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithID returns ctx carrying id. An invalid id leaves ctx unchanged.
+func WithID(ctx context.Context, id string) context.Context {
+	if !Valid(id) {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the trace ID ctx carries, or "".
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// Ensure returns ctx with a trace ID: the one it already carries, or else
+// id if valid, or else a new one.
+func Ensure(ctx context.Context, id string) context.Context {
+	if FromContext(ctx) != "" {
+		return ctx
+	}
+	id = strings.TrimSpace(id)
+	if !Valid(id) {
+		id = New()
+	}
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// Start returns ctx with the trace ID for a CLI command: the one in
+// SWARM_TRACE_ID if set and valid, or a new one.
+func Start(ctx context.Context) context.Context {
+	return Ensure(ctx, os.Getenv(EnvVar))
+}
@@ -0,0 +1,65 @@
+package trace
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"3f9a2c1e8b7d4a60", true},
+		{"nightly-run_2.1", true},
+		{"", false},
+		{"has space", false},
+		{"semi;colon", false},
+		{strings.Repeat("a", maxIDLength), true},
+		{strings.Repeat("a", maxIDLength+1), false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+	if id := New(); !Valid(id) || len(id) != 16 {
+		t.Fatalf("New returned unusable ID %q", id)
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	ctx := context.Background()
+	if FromContext(ctx) != "" {
+		t.Fatal("expected no trace ID on a bare context")
+	}
+	if got := FromContext(WithID(ctx, "bad id")); got != "" {
+		t.Fatalf("expected an invalid ID to be ignored, got %q", got)
+	}
+
+	traced := Ensure(ctx, " run-1 ")
+	if got := FromContext(traced); got != "run-1" {
+		t.Fatalf("expected the given ID, got %q", got)
+	}
+	if got := FromContext(Ensure(traced, "run-2")); got != "run-1" {
+		t.Fatalf("expected an existing ID to be kept, got %q", got)
+	}
+	if got := FromContext(Ensure(ctx, "bad id")); !Valid(got) || got == "bad id" {
+		t.Fatalf("expected a new ID in place of an invalid one, got %q", got)
+	}
+}
+
+func TestStart(t *testing.T) {
+	t.Setenv(EnvVar, "nightly-1")
+	if got := FromContext(Start(context.Background())); got != "nightly-1" {
+		t.Fatalf("expected the ID from %s, got %q", EnvVar, got)
+	}
+
+	t.Setenv(EnvVar, "")
+	first := FromContext(Start(context.Background()))
+	second := FromContext(Start(context.Background()))
+	if !Valid(first) || first == second {
+		t.Fatalf("expected a new ID per command, got %q and %q", first, second)
+	}
+}
@@ -2517,6 +2517,8 @@ func agentStateBlockReason(state models.AgentState) string {
 		return "paused"
 	case models.AgentStateRateLimited:
 		return "cooldown"
+	case models.AgentStateStuck:
+		return "stuck"
 	case models.AgentStateWorking, models.AgentStateStarting, models.AgentStateAwaitingApproval:
 		return "busy"
 	case models.AgentStateError, models.AgentStateStopped:
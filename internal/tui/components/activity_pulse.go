@@ -88,7 +88,7 @@ func renderPulseDots(styleSet styles.Styles, level ActivityLevel, state models.A
 	switch state {
 	case models.AgentStateWorking:
 		activeStyle = styleSet.Success.Copy().Bold(true)
-	case models.AgentStateError:
+	case models.AgentStateError, models.AgentStateStuck:
 		activeStyle = styleSet.Error.Copy().Bold(true)
 	case models.AgentStatePaused:
 		activeStyle = styleSet.Warning
@@ -176,7 +176,7 @@ func RenderActivitySparkline(styleSet styles.Styles, pulse ActivityPulse, width
 	switch pulse.CurrentState {
 	case models.AgentStateWorking:
 		style = styleSet.Success
-	case models.AgentStateError:
+	case models.AgentStateError, models.AgentStateStuck:
 		style = styleSet.Error
 	case models.AgentStatePaused, models.AgentStateRateLimited:
 		style = styleSet.Warning
@@ -35,6 +35,8 @@ func stateDescriptor(styleSet styles.Styles, state models.AgentState) (string, s
 		return "~", "Starting", styleSet.Info
 	case models.AgentStateStopped:
 		return "-", "Stopped", styleSet.Muted
+	case models.AgentStateStuck:
+		return "!!", "Stuck", styleSet.StatusError
 	default:
 		return "-", normalizeStateLabel(state), styleSet.Muted
 	}
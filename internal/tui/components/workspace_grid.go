@@ -353,6 +353,10 @@ func renderAlertToken(styleSet styles.Styles, alert models.Alert) string {
 		return styleSet.Warning.Render("[CD]")
 	case models.AlertTypeError:
 		return styleSet.Error.Render("[ERR]")
+	case models.AlertTypeStuck:
+		return styleSet.Error.Render("[STK]")
+	case models.AlertTypeVersionDrift:
+		return styleSet.Warning.Render("[VER]")
 	default:
 		return styleSet.Warning.Render("[!]")
 	}
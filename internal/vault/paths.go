@@ -168,6 +168,20 @@ func DefaultVaultPath() string {
 	return filepath.Join(home, ".config", "swarm", "vault")
 }
 
+// PermissionPaths returns the vault paths that must be owner-only: the
+// profile vault at vaultPath with its profiles, and the encrypted vault
+// with its salt.
+func PermissionPaths(vaultPath string) []string {
+	encrypted := DefaultPath()
+	return []string{
+		vaultPath,
+		ProfilesPath(vaultPath),
+		encrypted,
+		filepath.Join(encrypted, saltFileName),
+		filepath.Join(encrypted, vaultFileName),
+	}
+}
+
 // ProfilesPath returns the profiles subdirectory within a vault.
 func ProfilesPath(vaultPath string) string {
 	return filepath.Join(vaultPath, "profiles")
@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/opencode-ai/swarm/internal/adapters"
 	"github.com/opencode-ai/swarm/internal/models"
 )
 
-// BuildAlerts derives alert entries from agent states.
+// BuildAlerts derives alert entries from agent states and CLI versions.
 func BuildAlerts(agents []*models.Agent) []models.Alert {
 	alerts := make([]models.Alert, 0)
 	now := time.Now().UTC()
@@ -39,6 +40,14 @@ func BuildAlerts(agents []*models.Agent) []models.Alert {
 				AgentID:   agent.ID,
 				CreatedAt: now,
 			})
+		case models.AgentStateStuck:
+			alerts = append(alerts, models.Alert{
+				Type:      models.AlertTypeStuck,
+				Severity:  models.AlertSeverityError,
+				Message:   "Agent stuck: " + agent.StateInfo.Reason,
+				AgentID:   agent.ID,
+				CreatedAt: now,
+			})
 		case models.AgentStatePaused:
 			alerts = append(alerts, models.Alert{
 				Type:      models.AlertTypeCooldown,
@@ -50,6 +59,77 @@ func BuildAlerts(agents []*models.Agent) []models.Alert {
 		}
 	}
 
+	return append(alerts, BuildVersionAlerts(agents)...)
+}
+
+// BuildVersionAlerts flags agents running a different CLI version than the
+// other agents of their type in the workspace. Once the node has been
+// probed, agents are compared with the installed version, so an upgrade
+// flags every agent still running the old binary; until then they are
+// compared with the newest version any of them runs.
+func BuildVersionAlerts(agents []*models.Agent) []models.Alert {
+	type group struct {
+		installed string
+		checkedAt time.Time
+		newest    string
+	}
+	groups := make(map[string]*group)
+	key := func(a *models.Agent) string {
+		return a.WorkspaceID + "|" + string(a.Type)
+	}
+	running := func(a *models.Agent) bool {
+		return !a.IsTerminated() && a.State != models.AgentStateHibernated && a.Metadata.CLIVersion != ""
+	}
+
+	for _, agent := range agents {
+		if !running(agent) {
+			continue
+		}
+		g, ok := groups[key(agent)]
+		if !ok {
+			g = &group{}
+			groups[key(agent)] = g
+		}
+		if g.newest == "" || adapters.CompareVersions(agent.Metadata.CLIVersion, g.newest) > 0 {
+			g.newest = agent.Metadata.CLIVersion
+		}
+		// The most recent probe of the node wins.
+		if checked := agent.Metadata.CLIVersionCheckedAt; agent.Metadata.InstalledCLIVersion != "" && checked != nil && !checked.Before(g.checkedAt) {
+			g.installed = agent.Metadata.InstalledCLIVersion
+			g.checkedAt = *checked
+		}
+	}
+
+	alerts := make([]models.Alert, 0)
+	now := time.Now().UTC()
+	for _, agent := range agents {
+		if !running(agent) {
+			continue
+		}
+		g := groups[key(agent)]
+		version := agent.Metadata.CLIVersion
+
+		var message string
+		switch {
+		case g.installed != "":
+			if adapters.CompareVersions(version, g.installed) == 0 {
+				continue
+			}
+			message = fmt.Sprintf("%s %s is running; %s is installed (restart the agent to switch)", agent.Type, version, g.installed)
+		default:
+			if adapters.CompareVersions(version, g.newest) == 0 {
+				continue
+			}
+			message = fmt.Sprintf("%s %s is running; other %s agents run %s", agent.Type, version, agent.Type, g.newest)
+		}
+		alerts = append(alerts, models.Alert{
+			Type:      models.AlertTypeVersionDrift,
+			Severity:  models.AlertSeverityWarning,
+			Message:   message,
+			AgentID:   agent.ID,
+			CreatedAt: now,
+		})
+	}
 	return alerts
 }
 
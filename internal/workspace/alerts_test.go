@@ -0,0 +1,116 @@
+package workspace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestBuildVersionAlerts(t *testing.T) {
+	checked := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	later := checked.Add(time.Hour)
+	terminated := checked.Add(-time.Hour)
+
+	agent := func(id, workspaceID string, agentType models.AgentType, version string) *models.Agent {
+		return &models.Agent{
+			ID:          id,
+			WorkspaceID: workspaceID,
+			Type:        agentType,
+			State:       models.AgentStateIdle,
+			Metadata:    models.AgentMetadata{CLIVersion: version},
+		}
+	}
+	probed := func(a *models.Agent, installed string, at time.Time) *models.Agent {
+		a.Metadata.InstalledCLIVersion = installed
+		a.Metadata.CLIVersionCheckedAt = &at
+		return a
+	}
+
+	tests := []struct {
+		name   string
+		agents []*models.Agent
+		want   map[string]string
+	}{
+		{
+			name: "matching versions",
+			agents: []*models.Agent{
+				agent("a1", "ws1", models.AgentTypeClaudeCode, "1.0.17"),
+				agent("a2", "ws1", models.AgentTypeClaudeCode, "v1.0.17"),
+			},
+		},
+		{
+			name: "older than the newest running",
+			agents: []*models.Agent{
+				agent("a1", "ws1", models.AgentTypeCodex, "0.19.0"),
+				agent("a2", "ws1", models.AgentTypeCodex, "0.20.0"),
+				agent("a3", "ws1", models.AgentTypeCodex, "0.20.0"),
+			},
+			want: map[string]string{"a1": "codex 0.19.0 is running; other codex agents run 0.20.0"},
+		},
+		{
+			name: "all behind the installed version",
+			agents: []*models.Agent{
+				probed(agent("a1", "ws1", models.AgentTypeClaudeCode, "1.0.17"), "1.0.17", checked),
+				probed(agent("a2", "ws1", models.AgentTypeClaudeCode, "1.0.17"), "1.0.20", later),
+				agent("a3", "ws1", models.AgentTypeClaudeCode, "1.0.20"),
+			},
+			want: map[string]string{
+				"a1": "claude-code 1.0.17 is running; 1.0.20 is installed (restart the agent to switch)",
+				"a2": "claude-code 1.0.17 is running; 1.0.20 is installed (restart the agent to switch)",
+			},
+		},
+		{
+			name: "types and workspaces are compared apart",
+			agents: []*models.Agent{
+				agent("a1", "ws1", models.AgentTypeClaudeCode, "1.0.17"),
+				agent("a2", "ws1", models.AgentTypeCodex, "0.20.0"),
+				agent("a3", "ws2", models.AgentTypeClaudeCode, "1.0.20"),
+				agent("a4", "ws2", models.AgentTypeOpenCode, ""),
+			},
+		},
+		{
+			name: "stopped agents are left out",
+			agents: []*models.Agent{
+				agent("a1", "ws1", models.AgentTypeOpenCode, "0.3.58"),
+				func() *models.Agent {
+					a := agent("a2", "ws1", models.AgentTypeOpenCode, "0.3.60")
+					a.DeletedAt = &terminated
+					return a
+				}(),
+				func() *models.Agent {
+					a := agent("a3", "ws1", models.AgentTypeOpenCode, "0.4.0")
+					a.State = models.AgentStateHibernated
+					return a
+				}(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := BuildVersionAlerts(tt.agents)
+			if len(alerts) != len(tt.want) {
+				t.Fatalf("expected %d alerts, got %+v", len(tt.want), alerts)
+			}
+			for _, alert := range alerts {
+				if alert.Type != models.AlertTypeVersionDrift || alert.Severity != models.AlertSeverityWarning {
+					t.Errorf("unexpected alert kind: %+v", alert)
+				}
+				if want, ok := tt.want[alert.AgentID]; !ok || alert.Message != want {
+					t.Errorf("agent %s: got %q, want %q", alert.AgentID, alert.Message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildAlertsIncludesVersionDrift(t *testing.T) {
+	agents := []*models.Agent{
+		{ID: "a1", WorkspaceID: "ws1", Type: models.AgentTypeCodex, State: models.AgentStateError, Metadata: models.AgentMetadata{CLIVersion: "0.19.0"}},
+		{ID: "a2", WorkspaceID: "ws1", Type: models.AgentTypeCodex, State: models.AgentStateIdle, Metadata: models.AgentMetadata{CLIVersion: "0.20.0"}},
+	}
+	alerts := BuildAlerts(agents)
+	if len(alerts) != 2 || alerts[0].Type != models.AlertTypeError || alerts[1].Type != models.AlertTypeVersionDrift || alerts[1].AgentID != "a1" {
+		t.Fatalf("expected the error and the drift of a1, got %+v", alerts)
+	}
+}
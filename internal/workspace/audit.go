@@ -0,0 +1,43 @@
+package workspace
+
+import (
+	"context"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Audited operation names.
+const (
+	AuditCreateWorkspace    = "create_workspace"
+	AuditImportWorkspace    = "import_workspace"
+	AuditUpdateWorkspace    = "update_workspace"
+	AuditDeleteWorkspace    = "delete_workspace"
+	AuditUnmanageWorkspace  = "unmanage_workspace"
+	AuditDestroyWorkspace   = "destroy_workspace"
+	AuditPauseWorkspace     = "pause_workspace"
+	AuditResumeWorkspace    = "resume_workspace"
+	AuditDrainWorkspace     = "drain_workspace"
+	AuditUndrainWorkspace   = "undrain_workspace"
+	AuditSetQuietHours      = "set_quiet_hours"
+	AuditSetHibernateAfter  = "set_hibernate_after"
+	AuditBootstrapWorkspace = "bootstrap_workspace"
+)
+
+// WithAuditRecorder records the service's mutating operations.
+func WithAuditRecorder(recorder *audit.Recorder) ServiceOption {
+	return func(s *Service) {
+		s.auditor = recorder
+	}
+}
+
+func (s *Service) audit(ctx context.Context, operation, workspaceID string, params map[string]string, err error) {
+	s.auditor.Record(ctx, operation, models.EntityTypeWorkspace, workspaceID, params, err)
+}
+
+func workspaceID(ws *models.Workspace) string {
+	if ws == nil {
+		return ""
+	}
+	return ws.ID
+}
@@ -0,0 +1,77 @@
+package workspace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+)
+
+func TestMutationsAreAudited(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusUnknown, SSHBackend: models.SSHBackendAuto}
+	if err := nodeRepo.Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create local node: %v", err)
+	}
+	service := NewService(db.NewWorkspaceRepository(database), node.NewService(nodeRepo), db.NewAgentRepository(database),
+		WithAuditRecorder(audit.New(database, audit.WithActor("dana"))))
+
+	repoPath := t.TempDir()
+	ws, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{NodeID: localNode.ID, RepoPath: repoPath, Name: "demo"})
+	if err != nil {
+		t.Fatalf("CreateWorkspace failed: %v", err)
+	}
+	ws.Name = "renamed"
+	if err := service.UpdateWorkspace(ctx, ws); err != nil {
+		t.Fatalf("UpdateWorkspace failed: %v", err)
+	}
+	if _, _, err := service.DrainWorkspace(ctx, ws.ID); err != nil {
+		t.Fatalf("DrainWorkspace failed: %v", err)
+	}
+	if _, err := service.UndrainWorkspace(ctx, ws.ID); err != nil {
+		t.Fatalf("UndrainWorkspace failed: %v", err)
+	}
+	if _, err := service.UnmanageWorkspace(ctx, ws.ID, true); err != nil {
+		t.Fatalf("dry-run UnmanageWorkspace failed: %v", err)
+	}
+	if _, err := service.UnmanageWorkspace(ctx, ws.ID, false); err != nil {
+		t.Fatalf("UnmanageWorkspace failed: %v", err)
+	}
+	if err := service.DeleteWorkspace(ctx, ws.ID); err == nil {
+		t.Fatal("expected deleting an unmanaged workspace to fail")
+	}
+
+	records, err := db.NewAuditRepository(database).Query(ctx, db.AuditQuery{EntityType: models.EntityTypeWorkspace})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	want := []string{AuditCreateWorkspace, AuditUpdateWorkspace, AuditDrainWorkspace, AuditUndrainWorkspace, AuditUnmanageWorkspace, AuditDeleteWorkspace}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d audit records, got %d: %+v", len(want), len(records), records)
+	}
+	for i, op := range want {
+		record := records[len(records)-1-i]
+		if record.Operation != op || record.EntityID != ws.ID || record.Actor != "dana" {
+			t.Errorf("record %d: got %s on %s, want %s on %s", i, record.Operation, record.EntityID, op, ws.ID)
+		}
+	}
+	if created := records[len(records)-1]; created.Parameters["repo_path"] != repoPath || created.Parameters["name"] != "demo" {
+		t.Errorf("unexpected create parameters %+v", created.Parameters)
+	}
+	if records[0].Result != models.AuditResultFailure {
+		t.Errorf("expected the failed delete recorded as a failure, got %+v", records[0])
+	}
+}
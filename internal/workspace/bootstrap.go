@@ -0,0 +1,156 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/shell"
+)
+
+// Clone errors.
+var (
+	// ErrClonePathInUse is returned when the clone target already holds
+	// something other than a clone of the requested remote.
+	ErrClonePathInUse = errors.New("clone target path is in use")
+
+	// ErrCloneFailed is returned when git clone fails on the node.
+	ErrCloneFailed = errors.New("git clone failed")
+)
+
+// CommandExecutor runs shell commands on a node.
+type CommandExecutor interface {
+	ExecCommand(ctx context.Context, node *models.Node, cmd string) (*node.ExecResult, error)
+}
+
+// RepoClone describes a remote repository to clone when creating a
+// workspace. Credentials come from the node's own git and SSH config.
+type RepoClone struct {
+	// URL is the remote to clone, such as git@github.com:org/repo.git.
+	URL string
+
+	// Branch is an optional branch to check out instead of the remote's
+	// default branch.
+	Branch string
+
+	// Depth makes a shallow clone of this many commits. Zero clones the
+	// full history.
+	Depth int
+}
+
+func (c RepoClone) validate(repoPath string) error {
+	if strings.TrimSpace(c.URL) == "" {
+		return errors.New("clone URL is required")
+	}
+	if c.Depth < 0 {
+		return fmt.Errorf("clone depth must be >= 0, got %d", c.Depth)
+	}
+	// The path is on the workspace's node, which may not be this machine.
+	if !path.IsAbs(repoPath) {
+		return fmt.Errorf("clone target must be an absolute path: %s", repoPath)
+	}
+	return nil
+}
+
+// cloneRepo clones c into repoPath on n. A path holding a clone of the same
+// remote is used as is; any other non-empty path is ErrClonePathInUse.
+func (s *Service) cloneRepo(ctx context.Context, n *models.Node, repoPath string, c RepoClone) error {
+	exists, err := s.nodeCommand(ctx, n, "test -e "+shell.Quote(repoPath))
+	if err != nil {
+		return err
+	}
+	if exists.ExitCode == 0 {
+		skip, err := s.checkCloneTarget(ctx, n, repoPath, c.URL)
+		if err != nil || skip {
+			return err
+		}
+	}
+
+	args := []string{"GIT_TERMINAL_PROMPT=0", "git", "clone"}
+	if c.Branch != "" {
+		args = append(args, "--branch", shell.Quote(c.Branch))
+	}
+	if c.Depth > 0 {
+		args = append(args, "--depth", fmt.Sprint(c.Depth))
+	}
+	args = append(args, "--", shell.Quote(c.URL), shell.Quote(repoPath))
+	res, err := s.nodeCommand(ctx, n, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	if res.ExitCode != 0 {
+		// Surface git's own message, such as an authentication failure.
+		msg := strings.TrimSpace(res.Stderr)
+		if msg == "" {
+			msg = res.Error
+		}
+		return fmt.Errorf("%w: %s", ErrCloneFailed, msg)
+	}
+
+	res, err = s.nodeCommand(ctx, n, "git -C "+shell.Quote(repoPath)+" rev-parse --is-inside-work-tree")
+	if err != nil {
+		return err
+	}
+	if res.ExitCode != 0 || strings.TrimSpace(res.Stdout) != "true" {
+		return fmt.Errorf("%w: %s is not a git work tree after cloning", ErrRepoValidationFailed, repoPath)
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("node_id", n.ID).
+		Str("url", c.URL).
+		Str("repo_path", repoPath).
+		Msg("repository cloned")
+	return nil
+}
+
+// checkCloneTarget reports whether the existing repoPath is already a clone
+// of url, so the clone can be skipped. An empty directory is cloned into.
+func (s *Service) checkCloneTarget(ctx context.Context, n *models.Node, repoPath, url string) (bool, error) {
+	quoted := shell.Quote(repoPath)
+	res, err := s.nodeCommand(ctx, n, "git -C "+quoted+" rev-parse --show-toplevel && git -C "+quoted+" config --get remote.origin.url")
+	if err != nil {
+		return false, err
+	}
+	if res.ExitCode == 0 {
+		lines := strings.Split(strings.TrimSpace(res.Stdout), "\n")
+		if len(lines) == 2 && path.Clean(strings.TrimSpace(lines[0])) == path.Clean(repoPath) {
+			origin := strings.TrimSpace(lines[1])
+			if sameRemote(origin, url) {
+				s.logger.Info().Ctx(ctx).Str("repo_path", repoPath).Str("url", url).Msg("repository already cloned, skipping clone")
+				return true, nil
+			}
+			return false, fmt.Errorf("%w: %s is a clone of %s", ErrClonePathInUse, repoPath, origin)
+		}
+	}
+
+	res, err = s.nodeCommand(ctx, n, "test -d "+quoted+" && test -z \"$(ls -A "+quoted+")\"")
+	if err != nil {
+		return false, err
+	}
+	if res.ExitCode != 0 {
+		return false, fmt.Errorf("%w: %s already exists and is not a clone of %s", ErrClonePathInUse, repoPath, url)
+	}
+	return false, nil
+}
+
+func (s *Service) nodeCommand(ctx context.Context, n *models.Node, cmd string) (*node.ExecResult, error) {
+	res, err := s.executor.ExecCommand(ctx, n, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run command on node %s: %w", n.Name, err)
+	}
+	return res, nil
+}
+
+// sameRemote compares remote URLs, ignoring a trailing slash or ".git".
+func sameRemote(a, b string) bool {
+	normalize := func(url string) string {
+		url = strings.TrimSuffix(strings.TrimSpace(url), "/")
+		return strings.TrimSuffix(url, ".git")
+	}
+	return normalize(a) == normalize(b)
+}
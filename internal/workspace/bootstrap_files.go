@@ -0,0 +1,206 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/shell"
+)
+
+// Bootstrap errors.
+var (
+	// ErrNoBootstrapFiles is returned when a bootstrap has nothing to write.
+	ErrNoBootstrapFiles = errors.New("no bootstrap files configured")
+
+	// ErrBootstrapPathEscapes is returned for a bootstrap file whose path
+	// leads outside the repository.
+	ErrBootstrapPathEscapes = errors.New("bootstrap file path escapes the repository")
+)
+
+// BootstrapFile is a file written into a workspace's repository, such as
+// an AGENTS.md or a prompt scaffold.
+type BootstrapFile struct {
+	// Path is relative to the repository root.
+	Path string
+
+	// Content is a text/template rendered with BootstrapTemplateData.
+	Content string
+}
+
+// BootstrapTemplateData is what bootstrap file templates are rendered
+// with, as in {{.Name}} or {{.Branch}}.
+type BootstrapTemplateData struct {
+	ID     string
+	Name   string
+	Path   string
+	Branch string
+	Node   string
+}
+
+// bootstrapWrite is a rendered bootstrap file ready to be written.
+type bootstrapWrite struct {
+	rel     string
+	target  string
+	content string
+}
+
+// BootstrapWorkspace renders files and writes them into the workspace's
+// repository on its node. Files that already exist are skipped unless
+// overwrite is set. Every file is rendered and its path checked before
+// anything is written, so a bad template writes nothing. What was done is
+// recorded on the workspace and published as workspace.bootstrapped.
+func (s *Service) BootstrapWorkspace(ctx context.Context, id string, files []BootstrapFile, overwrite bool) (_ *models.WorkspaceBootstrap, err error) {
+	defer func() {
+		s.audit(ctx, AuditBootstrapWorkspace, id, audit.Params("overwrite", fmt.Sprint(overwrite)), err)
+	}()
+	if len(files) == 0 {
+		return nil, ErrNoBootstrapFiles
+	}
+
+	workspace, err := s.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	n, err := s.nodeService.GetNode(ctx, workspace.NodeID)
+	if err != nil {
+		if errors.Is(err, node.ErrNodeNotFound) {
+			return nil, ErrNodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	data := BootstrapTemplateData{
+		ID:   workspace.ID,
+		Name: workspace.Name,
+		Path: workspace.RepoPath,
+		Node: n.Name,
+	}
+	if workspace.GitInfo != nil {
+		data.Branch = workspace.GitInfo.Branch
+	}
+
+	writes, err := renderBootstrapFiles(workspace.RepoPath, files, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.WorkspaceBootstrap{AppliedAt: s.clock.Now().UTC()}
+	for _, w := range writes {
+		status, err := s.writeBootstrapFile(ctx, n, w, overwrite)
+		if err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, models.BootstrapFileResult{Path: w.rel, Status: status})
+	}
+
+	if err := s.repo.SetBootstrap(ctx, workspace.ID, result); err != nil {
+		if errors.Is(err, db.ErrWorkspaceNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, fmt.Errorf("failed to record workspace bootstrap: %w", err)
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("workspace_id", workspace.ID).
+		Int("files", len(result.Files)).
+		Bool("overwrite", overwrite).
+		Msg("workspace bootstrapped")
+	s.publishEvent(ctx, models.EventTypeWorkspaceBootstrapped, workspace.ID, models.WorkspaceBootstrappedPayload{
+		Name:  workspace.Name,
+		Files: result.Files,
+	})
+
+	return result, nil
+}
+
+// renderBootstrapFiles renders each file's template and resolves its
+// target under repoPath.
+func renderBootstrapFiles(repoPath string, files []BootstrapFile, data BootstrapTemplateData) ([]bootstrapWrite, error) {
+	seen := make(map[string]bool, len(files))
+	writes := make([]bootstrapWrite, 0, len(files))
+	for _, f := range files {
+		rel, target, err := bootstrapTarget(repoPath, f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if seen[rel] {
+			return nil, fmt.Errorf("duplicate bootstrap file %s", rel)
+		}
+		seen[rel] = true
+
+		tmpl, err := template.New(rel).Option("missingkey=error").Parse(f.Content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for bootstrap file %s: %w", rel, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render bootstrap file %s: %w", rel, err)
+		}
+		writes = append(writes, bootstrapWrite{rel: rel, target: target, content: buf.String()})
+	}
+	return writes, nil
+}
+
+// bootstrapTarget resolves rel against repoPath, returning the cleaned
+// relative path and the absolute target. The check is lexical: the
+// repository is on the workspace's node, which may not be this machine.
+func bootstrapTarget(repoPath, rel string) (string, string, error) {
+	if strings.TrimSpace(rel) == "" {
+		return "", "", errors.New("bootstrap file path is required")
+	}
+	if path.IsAbs(rel) {
+		return "", "", fmt.Errorf("%w: %s is absolute", ErrBootstrapPathEscapes, rel)
+	}
+	cleaned := path.Clean(rel)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", "", fmt.Errorf("%w: %s", ErrBootstrapPathEscapes, rel)
+	}
+	return cleaned, path.Join(repoPath, cleaned), nil
+}
+
+// writeBootstrapFile writes w on n through a temporary file, so an
+// interrupted write leaves no partial file behind.
+func (s *Service) writeBootstrapFile(ctx context.Context, n *models.Node, w bootstrapWrite, overwrite bool) (models.BootstrapFileStatus, error) {
+	quoted := shell.Quote(w.target)
+	res, err := s.nodeCommand(ctx, n, "if test -d "+quoted+"; then echo directory; elif test -e "+quoted+" || test -L "+quoted+"; then echo exists; fi")
+	if err != nil {
+		return "", err
+	}
+	status := models.BootstrapFileWritten
+	switch strings.TrimSpace(res.Stdout) {
+	case "directory":
+		return "", fmt.Errorf("bootstrap file %s is a directory", w.rel)
+	case "exists":
+		if !overwrite {
+			return models.BootstrapFileSkipped, nil
+		}
+		status = models.BootstrapFileOverwritten
+	}
+
+	tmp := shell.Quote(w.target + ".swarm-tmp")
+	cmd := "mkdir -p " + shell.Quote(path.Dir(w.target)) +
+		" && printf '%s' " + shell.Quote(w.content) + " > " + tmp +
+		" && mv -f " + tmp + " " + quoted
+	res, err = s.nodeCommand(ctx, n, cmd)
+	if err != nil {
+		return "", err
+	}
+	if res.ExitCode != 0 {
+		msg := strings.TrimSpace(res.Stderr)
+		if msg == "" {
+			msg = res.Error
+		}
+		return "", fmt.Errorf("failed to write bootstrap file %s: %s", w.rel, msg)
+	}
+	return status, nil
+}
@@ -0,0 +1,133 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestBootstrapWorkspace_RendersFiles(t *testing.T) {
+	repo := initGitRepo(t)
+	service, ws := setupCloneTest(t, repo)
+
+	result, err := service.BootstrapWorkspace(context.Background(), ws.ID, []BootstrapFile{
+		{Path: "AGENTS.md", Content: "# {{.Name}}\nRepo: {{.Path}}\nNode: {{.Node}}\n"},
+		{Path: "prompts/./start.md", Content: "Work on {{.Branch}}; it's {{.ID}}."},
+	}, false)
+	if err != nil {
+		t.Fatalf("BootstrapWorkspace failed: %v", err)
+	}
+
+	want := "# source\nRepo: " + repo + "\nNode: local\n"
+	if got := readFile(t, filepath.Join(repo, "AGENTS.md")); got != want {
+		t.Fatalf("expected AGENTS.md %q, got %q", want, got)
+	}
+	want = "Work on " + ws.GitInfo.Branch + "; it's " + ws.ID + "."
+	if got := readFile(t, filepath.Join(repo, "prompts", "start.md")); got != want {
+		t.Fatalf("expected start.md %q, got %q", want, got)
+	}
+	if len(result.Files) != 2 || result.Files[1].Path != "prompts/start.md" || result.Files[1].Status != models.BootstrapFileWritten {
+		t.Fatalf("unexpected result %+v", result.Files)
+	}
+
+	stored, err := service.GetWorkspace(context.Background(), ws.ID)
+	if err != nil {
+		t.Fatalf("GetWorkspace failed: %v", err)
+	}
+	if stored.Bootstrap == nil || len(stored.Bootstrap.Files) != 2 {
+		t.Fatalf("expected the bootstrap recorded on the workspace, got %+v", stored.Bootstrap)
+	}
+}
+
+func TestBootstrapWorkspace_SkipsExistingFiles(t *testing.T) {
+	repo := initGitRepo(t)
+	service, ws := setupCloneTest(t, repo)
+	ctx := context.Background()
+
+	existing := filepath.Join(repo, "AGENTS.md")
+	if err := os.WriteFile(existing, []byte("mine"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := []BootstrapFile{
+		{Path: "AGENTS.md", Content: "generated"},
+		{Path: "CLAUDE.md", Content: "generated"},
+	}
+
+	result, err := service.BootstrapWorkspace(ctx, ws.ID, files, false)
+	if err != nil {
+		t.Fatalf("BootstrapWorkspace failed: %v", err)
+	}
+	if got := readFile(t, existing); got != "mine" {
+		t.Fatalf("expected the existing file kept, got %q", got)
+	}
+	if result.Files[0].Status != models.BootstrapFileSkipped || result.Files[1].Status != models.BootstrapFileWritten {
+		t.Fatalf("unexpected result %+v", result.Files)
+	}
+
+	result, err = service.BootstrapWorkspace(ctx, ws.ID, files, true)
+	if err != nil {
+		t.Fatalf("BootstrapWorkspace with overwrite failed: %v", err)
+	}
+	if got := readFile(t, existing); got != "generated" {
+		t.Fatalf("expected the existing file overwritten, got %q", got)
+	}
+	for _, f := range result.Files {
+		if f.Status != models.BootstrapFileOverwritten {
+			t.Fatalf("expected every file overwritten, got %+v", result.Files)
+		}
+	}
+	if _, err := os.Stat(existing + ".swarm-tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected no temporary file left behind, got %v", err)
+	}
+}
+
+func TestBootstrapWorkspace_RejectsPathsOutsideRepo(t *testing.T) {
+	repo := initGitRepo(t)
+	service, ws := setupCloneTest(t, repo)
+
+	for _, p := range []string{"../escape.md", "docs/../../escape.md", "/tmp/escape.md", "."} {
+		_, err := service.BootstrapWorkspace(context.Background(), ws.ID, []BootstrapFile{
+			{Path: "AGENTS.md", Content: "ok"},
+			{Path: p, Content: "x"},
+		}, false)
+		if !errors.Is(err, ErrBootstrapPathEscapes) {
+			t.Fatalf("%s: expected ErrBootstrapPathEscapes, got %v", p, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repo, "AGENTS.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing written when a path is rejected, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(repo), "escape.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing written outside the repo, got %v", err)
+	}
+}
+
+func TestBootstrapWorkspace_TemplateErrors(t *testing.T) {
+	repo := initGitRepo(t)
+	service, ws := setupCloneTest(t, repo)
+
+	for _, content := range []string{"{{.Name", "{{.Owner}}"} {
+		_, err := service.BootstrapWorkspace(context.Background(), ws.ID, []BootstrapFile{
+			{Path: "AGENTS.md", Content: content},
+		}, false)
+		if err == nil {
+			t.Fatalf("%q: expected a template error", content)
+		}
+	}
+	if _, err := service.BootstrapWorkspace(context.Background(), ws.ID, nil, false); !errors.Is(err, ErrNoBootstrapFiles) {
+		t.Fatalf("expected ErrNoBootstrapFiles, got %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,227 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+)
+
+// initBareRepo returns a file:// URL of a bare repo with commits on main and
+// a feature branch.
+func initBareRepo(t *testing.T) string {
+	t.Helper()
+	work := initGitRepo(t)
+	bare := filepath.Join(t.TempDir(), "origin.git")
+	for _, args := range [][]string{
+		{"checkout", "-q", "-B", "main"},
+		{"-c", "user.email=test@example.com", "-c", "user.name=test", "commit", "-q", "--allow-empty", "-m", "second"},
+		{"branch", "feature"},
+		{"clone", "-q", "--bare", work, bare},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = work
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	return "file://" + bare
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestCreateWorkspace_Clone(t *testing.T) {
+	service, source := setupCloneTest(t, t.TempDir())
+	url := initBareRepo(t)
+	target := filepath.Join(t.TempDir(), "repo")
+
+	ws, err := service.CreateWorkspace(context.Background(), CreateWorkspaceInput{
+		NodeID:   source.NodeID,
+		RepoPath: target,
+		Name:     "cloned",
+		Clone:    &RepoClone{URL: url, Branch: "feature", Depth: 1},
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkspace failed: %v", err)
+	}
+	if ws.RepoPath != target || ws.GitInfo == nil || ws.GitInfo.Branch != "feature" {
+		t.Fatalf("unexpected workspace %+v (git %+v)", ws, ws.GitInfo)
+	}
+	if got := gitOutput(t, target, "rev-list", "--count", "HEAD"); got != "1" {
+		t.Fatalf("expected a shallow clone of depth 1, got %s commits", got)
+	}
+}
+
+func TestCreateWorkspace_CloneExistingPath(t *testing.T) {
+	service, source := setupCloneTest(t, t.TempDir())
+	url := initBareRepo(t)
+	ctx := context.Background()
+
+	// A path holding a clone of the same remote is used as is.
+	existing := filepath.Join(t.TempDir(), "repo")
+	if out, err := exec.Command("git", "clone", "-q", url, existing).CombinedOutput(); err != nil {
+		t.Fatalf("git clone: %v: %s", err, out)
+	}
+	marker := filepath.Join(existing, "local-change")
+	if err := os.WriteFile(marker, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{
+		NodeID:   source.NodeID,
+		RepoPath: existing,
+		Clone:    &RepoClone{URL: url + "/"},
+	}); err != nil {
+		t.Fatalf("expected the existing clone to be reused, got %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("expected the existing clone untouched: %v", err)
+	}
+
+	// Anything else at the path is an error.
+	other := initGitRepo(t)
+	cluttered := t.TempDir()
+	if err := os.WriteFile(filepath.Join(cluttered, "notes.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{other, cluttered} {
+		_, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{
+			NodeID:   source.NodeID,
+			RepoPath: path,
+			Clone:    &RepoClone{URL: url},
+		})
+		if !errors.Is(err, ErrClonePathInUse) {
+			t.Fatalf("%s: expected ErrClonePathInUse, got %v", path, err)
+		}
+	}
+
+	// An empty directory is cloned into.
+	empty := t.TempDir()
+	if _, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{
+		NodeID:   source.NodeID,
+		RepoPath: empty,
+		Clone:    &RepoClone{URL: url},
+	}); err != nil {
+		t.Fatalf("expected a clone into an empty directory, got %v", err)
+	}
+	if got := gitOutput(t, empty, "rev-parse", "--abbrev-ref", "HEAD"); got != "main" {
+		t.Fatalf("expected the default branch checked out, got %s", got)
+	}
+}
+
+func TestCreateWorkspace_CloneFailure(t *testing.T) {
+	service, source := setupCloneTest(t, t.TempDir())
+	ctx := context.Background()
+
+	_, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{
+		NodeID:   source.NodeID,
+		RepoPath: filepath.Join(t.TempDir(), "repo"),
+		Clone:    &RepoClone{URL: "file:///nonexistent/origin.git"},
+	})
+	if !errors.Is(err, ErrCloneFailed) || !strings.Contains(err.Error(), "fatal:") {
+		t.Fatalf("expected git's error surfaced, got %v", err)
+	}
+
+	_, err = service.CreateWorkspace(ctx, CreateWorkspaceInput{
+		NodeID:   source.NodeID,
+		RepoPath: "relative/repo",
+		Clone:    &RepoClone{URL: "file:///nonexistent/origin.git"},
+	})
+	if !errors.Is(err, ErrRepoValidationFailed) {
+		t.Fatalf("expected a relative clone target rejected, got %v", err)
+	}
+}
+
+type fakeCommandExecutor struct {
+	commands []string
+	results  map[string]*node.ExecResult
+}
+
+func (f *fakeCommandExecutor) ExecCommand(ctx context.Context, n *models.Node, cmd string) (*node.ExecResult, error) {
+	f.commands = append(f.commands, cmd)
+	for prefix, res := range f.results {
+		if strings.HasPrefix(cmd, prefix) {
+			return res, nil
+		}
+	}
+	return &node.ExecResult{}, nil
+}
+
+func TestCreateWorkspace_CloneRemoteNode(t *testing.T) {
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	ctx := context.Background()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	nodeRepo := db.NewNodeRepository(database)
+	remote := &models.Node{
+		Name:       "prod",
+		SSHTarget:  "deploy@prod.example.com",
+		Status:     models.NodeStatusOnline,
+		SSHBackend: models.SSHBackendSystem,
+	}
+	if err := nodeRepo.Create(ctx, remote); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+
+	executor := &fakeCommandExecutor{results: map[string]*node.ExecResult{
+		"test -e ": {ExitCode: 1},
+		"git -C '/data/repos/repo' rev-parse --is-inside-work-tree": {Stdout: "true\n"},
+	}}
+	service := NewService(db.NewWorkspaceRepository(database), node.NewService(nodeRepo), db.NewAgentRepository(database), WithCommandExecutor(executor))
+
+	ws, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{
+		NodeID:   remote.ID,
+		RepoPath: "/data/repos/repo",
+		Clone:    &RepoClone{URL: "git@github.com:org/repo.git", Branch: "main", Depth: 1},
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkspace failed: %v", err)
+	}
+	if ws.NodeID != remote.ID || ws.RepoPath != "/data/repos/repo" {
+		t.Fatalf("unexpected workspace %+v", ws)
+	}
+
+	want := []string{
+		"test -e '/data/repos/repo'",
+		"GIT_TERMINAL_PROMPT=0 git clone --branch 'main' --depth 1 -- 'git@github.com:org/repo.git' '/data/repos/repo'",
+		"git -C '/data/repos/repo' rev-parse --is-inside-work-tree",
+	}
+	if strings.Join(executor.commands, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected node commands:\n%s", strings.Join(executor.commands, "\n"))
+	}
+
+	// Authentication failures come back as git reported them.
+	executor.commands = nil
+	executor.results["GIT_TERMINAL_PROMPT=0 git clone"] = &node.ExecResult{
+		ExitCode: 128,
+		Stderr:   "git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.\n",
+	}
+	_, err = service.CreateWorkspace(ctx, CreateWorkspaceInput{
+		NodeID:   remote.ID,
+		RepoPath: "/data/repos/other",
+		Clone:    &RepoClone{URL: "git@github.com:org/private.git"},
+	})
+	if !errors.Is(err, ErrCloneFailed) || !strings.Contains(err.Error(), "Permission denied (publickey).\nfatal: Could not read from remote repository.") {
+		t.Fatalf("expected the auth failure surfaced verbatim, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// ErrCloneTargetRequired is returned when neither a path nor a worktree branch is given.
+var ErrCloneTargetRequired = errors.New("clone requires a target path or worktree branch")
+
+// CloneWorkspaceInput contains the parameters for cloning a workspace.
+type CloneWorkspaceInput struct {
+	// SourceID is the workspace to clone.
+	SourceID string
+
+	// RepoPath is the target directory. When WorktreeBranch is set and
+	// RepoPath is empty, a sibling directory of the source repo is used.
+	RepoPath string
+
+	// WorktreeBranch creates the target as a git worktree of the source repo
+	// on this branch. The branch is created if it does not exist.
+	WorktreeBranch string
+
+	// Name is an optional name for the new workspace.
+	Name string
+
+	// TmuxSession is an optional session name for the new workspace.
+	TmuxSession string
+
+	// CreateTmuxSession indicates whether to create a new tmux session.
+	CreateTmuxSession bool
+}
+
+// CloneWorkspace creates a new workspace on the source workspace's node,
+// optionally backed by a fresh git worktree. Agents are not copied; see
+// agent.Service.CloneAgents.
+func (s *Service) CloneWorkspace(ctx context.Context, input CloneWorkspaceInput) (*models.Workspace, error) {
+	source, err := s.GetWorkspace(ctx, input.SourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := strings.TrimSpace(input.WorktreeBranch)
+	targetPath := strings.TrimSpace(input.RepoPath)
+	if targetPath == "" {
+		if branch == "" {
+			return nil, ErrCloneTargetRequired
+		}
+		targetPath = defaultWorktreePath(source.RepoPath, branch)
+	}
+	targetPath = CanonicalRepoPath(targetPath)
+
+	if CanonicalRepoPath(source.RepoPath) == targetPath {
+		return nil, fmt.Errorf("%w: target path is the source workspace", ErrWorkspaceAlreadyExists)
+	}
+	existing, err := s.repo.GetByNodeAndPath(ctx, source.NodeID, targetPath)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %s is already workspace %s", ErrWorkspaceAlreadyExists, targetPath, existing.Name)
+	}
+	if !errors.Is(err, db.ErrWorkspaceNotFound) {
+		return nil, fmt.Errorf("failed to check target path: %w", err)
+	}
+
+	if branch != "" {
+		if err := addGitWorktree(source.RepoPath, targetPath, branch); err != nil {
+			return nil, err
+		}
+	}
+
+	name := input.Name
+	if name == "" && branch != "" {
+		name = fmt.Sprintf("%s-%s", source.Name, sanitizeTmuxName(branch))
+	}
+
+	clone, err := s.CreateWorkspace(ctx, CreateWorkspaceInput{
+		NodeID:            source.NodeID,
+		RepoPath:          targetPath,
+		Name:              name,
+		TmuxSession:       input.TmuxSession,
+		CreateTmuxSession: input.CreateTmuxSession,
+	})
+	if err != nil {
+		if branch != "" {
+			if _, stderr, rmErr := runGit(source.RepoPath, "worktree", "remove", "--force", targetPath); rmErr != nil {
+				s.logger.Warn().Ctx(ctx).Err(rmErr).Str("stderr", strings.TrimSpace(stderr)).Str("path", targetPath).Msg("failed to remove worktree after clone failure")
+			}
+		}
+		return nil, err
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("source_id", source.ID).
+		Str("workspace_id", clone.ID).
+		Str("repo_path", clone.RepoPath).
+		Str("worktree_branch", branch).
+		Msg("workspace cloned")
+
+	return clone, nil
+}
+
+// defaultWorktreePath places a worktree next to the source repo,
+// e.g. /src/app + feature/x -> /src/app-feature-x.
+func defaultWorktreePath(sourcePath, branch string) string {
+	slug := sanitizeTmuxName(branch)
+	if slug == "" {
+		slug = "worktree"
+	}
+	clean := filepath.Clean(sourcePath)
+	return filepath.Join(filepath.Dir(clean), filepath.Base(clean)+"-"+slug)
+}
+
+func addGitWorktree(repoPath, targetPath, branch string) error {
+	args := []string{"worktree", "add", targetPath, branch}
+	if _, _, err := runGit(repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err != nil {
+		args = []string{"worktree", "add", "-b", branch, targetPath}
+	}
+
+	if _, stderr, err := runGit(repoPath, args...); err != nil {
+		msg := strings.TrimSpace(stderr)
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("failed to create git worktree: %s", msg)
+	}
+	return nil
+}
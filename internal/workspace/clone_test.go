@@ -0,0 +1,153 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+)
+
+func setupCloneTest(t *testing.T, repoPath string) (*Service, *models.Workspace) {
+	t.Helper()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	localNode := &models.Node{
+		Name:       "local",
+		IsLocal:    true,
+		Status:     models.NodeStatusUnknown,
+		SSHBackend: models.SSHBackendAuto,
+	}
+	if err := nodeRepo.Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create local node: %v", err)
+	}
+
+	service := NewService(db.NewWorkspaceRepository(database), node.NewService(nodeRepo), db.NewAgentRepository(database))
+	source, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{
+		NodeID:   localNode.ID,
+		RepoPath: repoPath,
+		Name:     "source",
+	})
+	if err != nil {
+		t.Fatalf("failed to create source workspace: %v", err)
+	}
+	return service, source
+}
+
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	repo := filepath.Join(t.TempDir(), "app")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.email=test@example.com", "-c", "user.name=test", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	return repo
+}
+
+func TestCloneWorkspace_Path(t *testing.T) {
+	service, source := setupCloneTest(t, t.TempDir())
+	target := t.TempDir()
+
+	clone, err := service.CloneWorkspace(context.Background(), CloneWorkspaceInput{
+		SourceID: source.ID,
+		RepoPath: target,
+		Name:     "copy",
+	})
+	if err != nil {
+		t.Fatalf("CloneWorkspace failed: %v", err)
+	}
+	if clone.ID == source.ID {
+		t.Fatal("expected a new workspace ID")
+	}
+	if clone.NodeID != source.NodeID {
+		t.Fatalf("expected clone on node %s, got %s", source.NodeID, clone.NodeID)
+	}
+	if clone.RepoPath != target || clone.Name != "copy" {
+		t.Fatalf("unexpected clone %+v", clone)
+	}
+	if clone.TmuxSession == source.TmuxSession {
+		t.Fatal("expected a distinct tmux session name")
+	}
+}
+
+func TestCloneWorkspace_Conflicts(t *testing.T) {
+	service, source := setupCloneTest(t, t.TempDir())
+	ctx := context.Background()
+
+	if _, err := service.CloneWorkspace(ctx, CloneWorkspaceInput{SourceID: source.ID, RepoPath: source.RepoPath}); !errors.Is(err, ErrWorkspaceAlreadyExists) {
+		t.Fatalf("expected ErrWorkspaceAlreadyExists for source path, got %v", err)
+	}
+
+	target := t.TempDir()
+	if _, err := service.CloneWorkspace(ctx, CloneWorkspaceInput{SourceID: source.ID, RepoPath: target}); err != nil {
+		t.Fatalf("first clone failed: %v", err)
+	}
+	if _, err := service.CloneWorkspace(ctx, CloneWorkspaceInput{SourceID: source.ID, RepoPath: target}); !errors.Is(err, ErrWorkspaceAlreadyExists) {
+		t.Fatalf("expected ErrWorkspaceAlreadyExists for existing target, got %v", err)
+	}
+
+	if _, err := service.CloneWorkspace(ctx, CloneWorkspaceInput{SourceID: source.ID}); !errors.Is(err, ErrCloneTargetRequired) {
+		t.Fatalf("expected ErrCloneTargetRequired, got %v", err)
+	}
+	if _, err := service.CloneWorkspace(ctx, CloneWorkspaceInput{SourceID: "missing", RepoPath: target}); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Fatalf("expected ErrWorkspaceNotFound, got %v", err)
+	}
+}
+
+func TestCloneWorkspace_Worktree(t *testing.T) {
+	repo := initGitRepo(t)
+	service, source := setupCloneTest(t, repo)
+
+	clone, err := service.CloneWorkspace(context.Background(), CloneWorkspaceInput{
+		SourceID:       source.ID,
+		WorktreeBranch: "feature/x",
+	})
+	if err != nil {
+		t.Fatalf("CloneWorkspace failed: %v", err)
+	}
+
+	wantPath := filepath.Join(filepath.Dir(repo), "app-feature-x")
+	if clone.RepoPath != wantPath {
+		t.Fatalf("expected worktree at %s, got %s", wantPath, clone.RepoPath)
+	}
+	if clone.Name != "source-feature-x" {
+		t.Fatalf("expected derived name source-feature-x, got %s", clone.Name)
+	}
+	if clone.GitInfo == nil || clone.GitInfo.Branch != "feature/x" {
+		t.Fatalf("expected clone on branch feature/x, got %+v", clone.GitInfo)
+	}
+
+	out, err := exec.Command("git", "-C", repo, "worktree", "list").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git worktree list: %v", err)
+	}
+	if !strings.Contains(string(out), wantPath) {
+		t.Fatalf("expected worktree %s in list, got %s", wantPath, out)
+	}
+}
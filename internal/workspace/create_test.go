@@ -0,0 +1,121 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+)
+
+// newFileBackedService uses an on-disk database so concurrent creates run
+// on separate connections, as parallel CLI processes would.
+func newFileBackedService(t *testing.T) (*Service, *db.WorkspaceRepository, *models.Node) {
+	t.Helper()
+	ctx := context.Background()
+
+	cfg := db.DefaultConfig()
+	cfg.Path = filepath.Join(t.TempDir(), "swarm.db")
+	database, err := db.Open(cfg)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusUnknown, SSHBackend: models.SSHBackendAuto}
+	if err := nodeRepo.Create(ctx, localNode); err != nil {
+		t.Fatalf("create node: %v", err)
+	}
+	wsRepo := db.NewWorkspaceRepository(database)
+	return NewService(wsRepo, node.NewService(nodeRepo), db.NewAgentRepository(database)), wsRepo, localNode
+}
+
+func TestCreateWorkspaceConcurrent(t *testing.T) {
+	const creators = 8
+
+	for _, adopt := range []bool{true, false} {
+		service, wsRepo, localNode := newFileBackedService(t)
+		repoPath := t.TempDir()
+
+		var wg sync.WaitGroup
+		results := make([]*models.Workspace, creators)
+		errs := make([]error, creators)
+		for i := 0; i < creators; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				// Half the callers spell the path with a trailing slash.
+				path := repoPath
+				if i%2 == 1 {
+					path += "/"
+				}
+				results[i], errs[i] = service.CreateWorkspace(context.Background(), CreateWorkspaceInput{
+					NodeID:        localNode.ID,
+					RepoPath:      path,
+					AdoptExisting: adopt,
+				})
+			}(i)
+		}
+		wg.Wait()
+
+		var created string
+		conflicts := 0
+		for i := 0; i < creators; i++ {
+			switch {
+			case errs[i] == nil:
+				if created == "" {
+					created = results[i].ID
+				} else if results[i].ID != created {
+					t.Fatalf("adopt=%v: got workspaces %s and %s for one path", adopt, created, results[i].ID)
+				}
+			case errors.Is(errs[i], ErrWorkspaceAlreadyExists) && !adopt:
+				conflicts++
+			default:
+				t.Fatalf("adopt=%v: create %d failed: %v", adopt, i, errs[i])
+			}
+		}
+		if !adopt && conflicts != creators-1 {
+			t.Fatalf("expected %d conflicts, got %d", creators-1, conflicts)
+		}
+
+		all, err := wsRepo.ListByNode(context.Background(), localNode.ID)
+		if err != nil {
+			t.Fatalf("list workspaces: %v", err)
+		}
+		if len(all) != 1 || all[0].ID != created {
+			t.Fatalf("adopt=%v: expected exactly one workspace row, got %d", adopt, len(all))
+		}
+	}
+}
+
+func TestCreateWorkspaceCanonicalizesSymlinks(t *testing.T) {
+	service, _, localNode := newFileBackedService(t)
+	ctx := context.Background()
+
+	target := t.TempDir()
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	ws, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{NodeID: localNode.ID, RepoPath: target})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if _, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{NodeID: localNode.ID, RepoPath: link}); !errors.Is(err, ErrWorkspaceAlreadyExists) {
+		t.Fatalf("expected the symlinked path to conflict, got %v", err)
+	}
+	adopted, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{NodeID: localNode.ID, RepoPath: link, AdoptExisting: true})
+	if err != nil || adopted.ID != ws.ID {
+		t.Fatalf("expected to adopt %s, got %v (%v)", ws.ID, adopted, err)
+	}
+}
@@ -0,0 +1,108 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Drain errors.
+var (
+	ErrWorkspaceDraining    = errors.New("workspace is already draining")
+	ErrWorkspaceNotDraining = errors.New("workspace is not draining")
+)
+
+// DrainWorkspace marks a workspace and every agent in it draining. The
+// agents finish what is already queued but accept no new queue items, and
+// no new agents are spawned into the workspace until it is undrained. The
+// IDs of the draining agents are returned.
+func (s *Service) DrainWorkspace(ctx context.Context, id string) (_ *models.Workspace, _ []string, err error) {
+	defer func() { s.audit(ctx, AuditDrainWorkspace, id, nil, err) }()
+	workspace, err := s.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if workspace.Draining {
+		return nil, nil, ErrWorkspaceDraining
+	}
+
+	if err := s.setDraining(ctx, workspace.ID, true); err != nil {
+		return nil, nil, err
+	}
+	workspace.Draining = true
+
+	agentIDs, err := s.setAgentsDraining(ctx, workspace.ID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("workspace_id", workspace.ID).
+		Int("agents", len(agentIDs)).
+		Msg("workspace draining")
+
+	return workspace, agentIDs, nil
+}
+
+// UndrainWorkspace clears the drain of a workspace and of every agent in
+// it, including agents drained on their own. The IDs of the agents are
+// returned.
+func (s *Service) UndrainWorkspace(ctx context.Context, id string) (_ []string, err error) {
+	defer func() { s.audit(ctx, AuditUndrainWorkspace, id, nil, err) }()
+	workspace, err := s.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !workspace.Draining {
+		return nil, ErrWorkspaceNotDraining
+	}
+
+	agentIDs, err := s.setAgentsDraining(ctx, workspace.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setDraining(ctx, workspace.ID, false); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("workspace_id", workspace.ID).
+		Int("agents", len(agentIDs)).
+		Msg("workspace undrained")
+
+	return agentIDs, nil
+}
+
+func (s *Service) setAgentsDraining(ctx context.Context, workspaceID string, draining bool) ([]string, error) {
+	agents, err := s.agentRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	agentIDs := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		if err := s.agentRepo.SetDraining(ctx, agent.ID, draining); err != nil {
+			if errors.Is(err, db.ErrAgentNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to update agent %s: %w", agent.ID, err)
+		}
+		agentIDs = append(agentIDs, agent.ID)
+	}
+	return agentIDs, nil
+}
+
+func (s *Service) setDraining(ctx context.Context, id string, draining bool) error {
+	if err := s.repo.SetDraining(ctx, id, draining); err != nil {
+		if errors.Is(err, db.ErrWorkspaceNotFound) {
+			return ErrWorkspaceNotFound
+		}
+		return fmt.Errorf("failed to update workspace drain: %w", err)
+	}
+	return nil
+}
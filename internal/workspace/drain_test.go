@@ -0,0 +1,63 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestDrainWorkspace_FlagsAgents(t *testing.T) {
+	env := setupPauseTest(t)
+	ctx := context.Background()
+	working := env.addAgent(t, models.AgentStateWorking)
+	idle := env.addAgent(t, models.AgentStateIdle)
+
+	drained, agentIDs, err := env.service.DrainWorkspace(ctx, env.workspace.ID)
+	if err != nil {
+		t.Fatalf("DrainWorkspace failed: %v", err)
+	}
+	if !drained.Draining || len(agentIDs) != 2 {
+		t.Fatalf("unexpected drain result: draining=%v agents=%v", drained.Draining, agentIDs)
+	}
+	for _, id := range []string{working.ID, idle.ID} {
+		agent, err := env.agentRepo.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get agent: %v", err)
+		}
+		if !agent.Draining {
+			t.Fatalf("agent %s not draining", id)
+		}
+	}
+	stored, err := env.service.GetWorkspace(ctx, env.workspace.ID)
+	if err != nil {
+		t.Fatalf("GetWorkspace failed: %v", err)
+	}
+	if !stored.Draining {
+		t.Fatal("expected stored workspace to be draining")
+	}
+	if _, _, err := env.service.DrainWorkspace(ctx, env.workspace.ID); !errors.Is(err, ErrWorkspaceDraining) {
+		t.Fatalf("expected ErrWorkspaceDraining on second drain, got %v", err)
+	}
+
+	undrained, err := env.service.UndrainWorkspace(ctx, env.workspace.ID)
+	if err != nil {
+		t.Fatalf("UndrainWorkspace failed: %v", err)
+	}
+	if len(undrained) != 2 {
+		t.Fatalf("expected 2 agents undrained, got %v", undrained)
+	}
+	for _, id := range []string{working.ID, idle.ID} {
+		agent, err := env.agentRepo.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get agent: %v", err)
+		}
+		if agent.Draining {
+			t.Fatalf("agent %s still draining after undrain", id)
+		}
+	}
+	if _, err := env.service.UndrainWorkspace(ctx, env.workspace.ID); !errors.Is(err, ErrWorkspaceNotDraining) {
+		t.Fatalf("expected ErrWorkspaceNotDraining, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// SetHibernateAfter sets how long the workspace's agents may sit idle with
+// an empty queue before the scheduler hibernates them. Zero turns
+// hibernation off.
+func (s *Service) SetHibernateAfter(ctx context.Context, id string, after time.Duration) (_ *models.Workspace, err error) {
+	defer func() { s.audit(ctx, AuditSetHibernateAfter, id, audit.Params("hibernate_after", after.String()), err) }()
+
+	if after < 0 {
+		return nil, fmt.Errorf("hibernate after must not be negative, got %s", after)
+	}
+	if after > 0 && after < time.Second {
+		return nil, fmt.Errorf("hibernate after must be at least 1s, got %s", after)
+	}
+	if err := s.repo.SetHibernateAfter(ctx, id, int(after/time.Second)); err != nil {
+		if errors.Is(err, db.ErrWorkspaceNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, fmt.Errorf("failed to update workspace hibernation: %w", err)
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("workspace_id", id).
+		Dur("hibernate_after", after).
+		Msg("workspace hibernation set")
+
+	return s.GetWorkspace(ctx, id)
+}
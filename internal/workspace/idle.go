@@ -0,0 +1,215 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/logging"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+	"github.com/rs/zerolog"
+)
+
+// DefaultIdleDebounce is how long a workspace must stay idle before
+// workspace.idle is published.
+const DefaultIdleDebounce = 30 * time.Second
+
+// IdleEvaluator publishes workspace.idle when the last working agent of a
+// workspace goes idle with nothing left queued. It subscribes to agent state
+// changes; the event is held back for a debounce period so the brief idle
+// gap between two queue items does not fire it.
+type IdleEvaluator struct {
+	agentRepo   *db.AgentRepository
+	queueRepo   *db.QueueRepository
+	wsRepo      *db.WorkspaceRepository
+	wsQueueRepo *db.WorkspaceQueueRepository
+	publisher   events.Publisher
+	clock       clock.Clock
+	debounce    time.Duration
+	logger      zerolog.Logger
+
+	mu sync.Mutex
+	// pending maps a workspace to when its last working agent went idle.
+	pending map[string]time.Time
+}
+
+// IdleEvaluatorOption configures an IdleEvaluator.
+type IdleEvaluatorOption func(*IdleEvaluator)
+
+// WithIdleDebounce sets how long a workspace must stay idle before the
+// event is published.
+func WithIdleDebounce(d time.Duration) IdleEvaluatorOption {
+	return func(e *IdleEvaluator) {
+		e.debounce = d
+	}
+}
+
+// WithIdleClock sets the time source for the debounce period.
+func WithIdleClock(c clock.Clock) IdleEvaluatorOption {
+	return func(e *IdleEvaluator) {
+		e.clock = c
+	}
+}
+
+// NewIdleEvaluator creates an evaluator publishing to publisher.
+func NewIdleEvaluator(database *db.DB, publisher events.Publisher, opts ...IdleEvaluatorOption) *IdleEvaluator {
+	e := &IdleEvaluator{
+		agentRepo:   db.NewAgentRepository(database),
+		queueRepo:   db.NewQueueRepository(database),
+		wsRepo:      db.NewWorkspaceRepository(database),
+		wsQueueRepo: db.NewWorkspaceQueueRepository(database),
+		publisher:   publisher,
+		debounce:    DefaultIdleDebounce,
+		logger:      logging.Component("workspace-idle"),
+		pending:     make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.clock = clock.OrReal(e.clock)
+	if e.debounce <= 0 {
+		e.debounce = DefaultIdleDebounce
+	}
+	return e
+}
+
+// OnStateChange implements state.Subscriber.
+func (e *IdleEvaluator) OnStateChange(change state.StateChange) {
+	if !isBusy(change.PreviousState) && !isBusy(change.CurrentState) {
+		return
+	}
+
+	agent, err := e.agentRepo.Get(context.Background(), change.AgentID)
+	if err != nil {
+		e.logger.Debug().Err(err).Str("agent_id", change.AgentID).Msg("failed to load agent for idle check")
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch {
+	case isBusy(change.CurrentState):
+		delete(e.pending, agent.WorkspaceID)
+	case change.CurrentState == models.AgentStateIdle:
+		e.pending[agent.WorkspaceID] = e.clock.Now()
+	}
+}
+
+// Flush publishes workspace.idle for each workspace that has been idle for
+// the debounce period and still has no working agents or queued work. It
+// returns how many events were published.
+func (e *IdleEvaluator) Flush(ctx context.Context) int {
+	now := e.clock.Now()
+
+	e.mu.Lock()
+	due := make(map[string]time.Time)
+	for workspaceID, since := range e.pending {
+		if now.Sub(since) >= e.debounce {
+			due[workspaceID] = since
+			delete(e.pending, workspaceID)
+		}
+	}
+	e.mu.Unlock()
+
+	published := 0
+	for workspaceID, since := range due {
+		payload, idle, err := e.idlePayload(ctx, workspaceID)
+		if err != nil {
+			e.logger.Warn().Ctx(ctx).Err(err).Str("workspace_id", workspaceID).Msg("failed to check workspace idle")
+			continue
+		}
+		if !idle {
+			continue
+		}
+		payload.IdleSince = since
+		e.publish(ctx, workspaceID, payload)
+		published++
+	}
+	return published
+}
+
+// Run flushes due workspaces every second until ctx is canceled.
+func (e *IdleEvaluator) Run(ctx context.Context) {
+	ticker := e.clock.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			e.Flush(ctx)
+		}
+	}
+}
+
+func (e *IdleEvaluator) idlePayload(ctx context.Context, workspaceID string) (models.WorkspaceIdlePayload, bool, error) {
+	var payload models.WorkspaceIdlePayload
+
+	ws, err := e.wsRepo.Get(ctx, workspaceID)
+	if err != nil {
+		return payload, false, err
+	}
+	payload.Name = ws.Name
+
+	agents, err := e.agentRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return payload, false, err
+	}
+	for _, agent := range agents {
+		if isBusy(agent.State) {
+			return payload, false, nil
+		}
+		queued, err := e.queueRepo.Count(ctx, agent.ID)
+		if err != nil {
+			return payload, false, err
+		}
+		if queued > 0 {
+			return payload, false, nil
+		}
+	}
+	payload.Agents = len(agents)
+
+	items, err := e.wsQueueRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return payload, false, err
+	}
+	for _, item := range items {
+		if item.Status == models.WorkspaceQueueItemStatusPending {
+			return payload, false, nil
+		}
+	}
+	return payload, true, nil
+}
+
+func (e *IdleEvaluator) publish(ctx context.Context, workspaceID string, payload models.WorkspaceIdlePayload) {
+	if e.publisher == nil {
+		return
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		e.logger.Warn().Ctx(ctx).Err(err).Msg("failed to marshal workspace idle payload")
+		return
+	}
+	e.publisher.Publish(ctx, &models.Event{
+		Type:       models.EventTypeWorkspaceIdle,
+		EntityType: models.EntityTypeWorkspace,
+		EntityID:   workspaceID,
+		Payload:    data,
+	})
+}
+
+// isBusy reports whether an agent in state s still has work in hand; a
+// stuck agent is working as far as finishing goes.
+func isBusy(s models.AgentState) bool {
+	switch s {
+	case models.AgentStateWorking, models.AgentStateStarting, models.AgentStateStuck:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/events"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/state"
+)
+
+func TestIdleEvaluatorDebounce(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	node := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusOnline, SSHBackend: models.SSHBackendAuto}
+	if err := db.NewNodeRepository(database).Create(ctx, node); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	ws := &models.Workspace{NodeID: node.ID, Name: "api", RepoPath: "/repos/api", TmuxSession: "api"}
+	if err := db.NewWorkspaceRepository(database).Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	agentRepo := db.NewAgentRepository(database)
+	first := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "api:0.1", State: models.AgentStateWorking}
+	second := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeOpenCode, TmuxPane: "api:0.2", State: models.AgentStateWorking}
+	for _, a := range []*models.Agent{first, second} {
+		if err := agentRepo.Create(ctx, a); err != nil {
+			t.Fatalf("failed to create agent: %v", err)
+		}
+	}
+
+	var idle []*models.Event
+	publisher := events.NewInMemoryPublisher()
+	if err := publisher.Subscribe("idle", events.Filter{EventTypes: []models.EventType{models.EventTypeWorkspaceIdle}}, func(event *models.Event) {
+		idle = append(idle, event)
+	}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	fake := clock.NewFake(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	evaluator := NewIdleEvaluator(database, publisher, WithIdleClock(fake), WithIdleDebounce(30*time.Second))
+
+	// transition records a state change the way the state engine would:
+	// stored first, then reported to subscribers.
+	transition := func(agent *models.Agent, to models.AgentState) {
+		t.Helper()
+		from := agent.State
+		agent.State = to
+		if err := agentRepo.Update(ctx, agent); err != nil {
+			t.Fatalf("failed to update agent: %v", err)
+		}
+		evaluator.OnStateChange(state.StateChange{AgentID: agent.ID, PreviousState: from, CurrentState: to})
+	}
+	step := func(d time.Duration, wantIdle int) {
+		t.Helper()
+		fake.Advance(d)
+		evaluator.Flush(ctx)
+		if len(idle) != wantIdle {
+			t.Fatalf("after %s: expected %d workspace.idle events, got %d", fake.Now().Format(time.TimeOnly), wantIdle, len(idle))
+		}
+	}
+
+	// One agent finishing while another still works is not idle.
+	transition(first, models.AgentStateIdle)
+	step(time.Minute, 0)
+
+	// The last agent goes idle but picks up the next queue item within the
+	// debounce period.
+	transition(second, models.AgentStateIdle)
+	step(10*time.Second, 0)
+	transition(second, models.AgentStateWorking)
+	step(time.Minute, 0)
+
+	// Idle with work still queued is not finished.
+	insertQueueItem(t, database, first.ID)
+	transition(second, models.AgentStateIdle)
+	step(time.Minute, 0)
+	if _, err := database.ExecContext(ctx, "UPDATE queue_items SET status = ?", string(models.QueueItemStatusCompleted)); err != nil {
+		t.Fatalf("failed to complete queue item: %v", err)
+	}
+
+	// Finished: fires once, after the debounce period.
+	transition(second, models.AgentStateWorking)
+	transition(second, models.AgentStateIdle)
+	step(29*time.Second, 0)
+	step(time.Second, 1)
+	step(time.Minute, 1)
+
+	var payload models.WorkspaceIdlePayload
+	if err := json.Unmarshal(idle[0].Payload, &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if idle[0].EntityID != ws.ID || payload.Name != "api" || payload.Agents != 2 || !payload.IdleSince.Equal(fake.Now().Add(-time.Minute-30*time.Second)) {
+		t.Fatalf("unexpected workspace.idle event %+v (payload %+v)", idle[0], payload)
+	}
+}
+
+func insertQueueItem(t *testing.T, database *db.DB, agentID string) {
+	t.Helper()
+	item := &models.QueueItem{AgentID: agentID, Type: models.QueueItemTypeMessage, Payload: json.RawMessage(`{"text":"next"}`)}
+	if err := db.NewQueueRepository(database).Enqueue(context.Background(), agentID, item); err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+// LayoutPreset names a pane arrangement for a workspace session.
+type LayoutPreset string
+
+const (
+	// LayoutGrid tiles the agent panes evenly.
+	LayoutGrid LayoutPreset = "grid"
+	// LayoutFocus selects one agent's pane and zooms it.
+	LayoutFocus LayoutPreset = "focus"
+)
+
+// Layout is a parsed layout argument such as "grid" or "focus:<agent>".
+type Layout struct {
+	Preset LayoutPreset
+	// Agent is the agent to focus (ID or prefix); set only for LayoutFocus.
+	Agent string
+}
+
+// ParseLayout parses "grid" or "focus:<agent>".
+func ParseLayout(value string) (Layout, error) {
+	value = strings.TrimSpace(value)
+	preset, agent, hasAgent := strings.Cut(value, ":")
+	switch LayoutPreset(preset) {
+	case LayoutGrid:
+		if hasAgent {
+			return Layout{}, fmt.Errorf("layout %q takes no agent", LayoutGrid)
+		}
+		return Layout{Preset: LayoutGrid}, nil
+	case LayoutFocus:
+		agent = strings.TrimSpace(agent)
+		if agent == "" {
+			return Layout{}, fmt.Errorf("layout %q requires an agent, e.g. focus:<agent>", LayoutFocus)
+		}
+		return Layout{Preset: LayoutFocus, Agent: agent}, nil
+	default:
+		return Layout{}, fmt.Errorf("unknown layout %q (use grid or focus:<agent>)", value)
+	}
+}
+
+// ApplyLayout arranges the workspace's tmux session. Grid tiles the agents
+// window; focus selects the agent's window and pane and zooms it. focus is
+// the resolved agent for LayoutFocus and is ignored otherwise.
+func (s *Service) ApplyLayout(ctx context.Context, workspace *models.Workspace, layout Layout, focus *models.Agent) error {
+	if workspace == nil {
+		return fmt.Errorf("workspace is required")
+	}
+	if workspace.TmuxSession == "" {
+		return fmt.Errorf("workspace %s has no tmux session", workspace.ID)
+	}
+
+	client := s.tmuxClient()
+	switch layout.Preset {
+	case LayoutGrid:
+		target := fmt.Sprintf("%s:%s", workspace.TmuxSession, tmux.AgentWindowName)
+		if err := client.SelectWindow(ctx, target); err != nil {
+			return err
+		}
+		return client.SelectLayout(ctx, target, string(tmux.LayoutPresetTiled))
+	case LayoutFocus:
+		if focus == nil || focus.TmuxPane == "" {
+			return fmt.Errorf("agent to focus has no tmux pane")
+		}
+		if focus.WorkspaceID != workspace.ID {
+			return fmt.Errorf("agent %s is not in workspace %s", focus.ID, workspace.Name)
+		}
+		if err := client.SelectWindow(ctx, focus.TmuxPane); err != nil {
+			return err
+		}
+		if err := client.SelectPane(ctx, focus.TmuxPane); err != nil {
+			return err
+		}
+		return client.ZoomPane(ctx, focus.TmuxPane)
+	default:
+		return fmt.Errorf("unknown layout %q", layout.Preset)
+	}
+}
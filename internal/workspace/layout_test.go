@@ -0,0 +1,92 @@
+package workspace
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Layout
+		wantErr bool
+	}{
+		{value: "grid", want: Layout{Preset: LayoutGrid}},
+		{value: "focus:parser-fix", want: Layout{Preset: LayoutFocus, Agent: "parser-fix"}},
+		{value: "focus:", wantErr: true},
+		{value: "focus", wantErr: true},
+		{value: "grid:abc", wantErr: true},
+		{value: "stack", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLayout(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseLayout(%q) = %+v, want error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLayout(%q) = %+v, %v; want %+v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestApplyLayoutCommands(t *testing.T) {
+	ws := &models.Workspace{ID: "ws-1", Name: "demo", TmuxSession: "swarm-demo"}
+	agent := &models.Agent{ID: "agent-1", WorkspaceID: ws.ID, TmuxPane: "%4"}
+
+	tests := []struct {
+		name   string
+		layout Layout
+		want   []string
+	}{
+		{
+			name:   "grid",
+			layout: Layout{Preset: LayoutGrid},
+			want: []string{
+				"tmux select-window -t 'swarm-demo:agents'",
+				"tmux select-layout -t 'swarm-demo:agents' 'tiled'",
+			},
+		},
+		{
+			name:   "focus",
+			layout: Layout{Preset: LayoutFocus, Agent: "agent-1"},
+			want: []string{
+				"tmux select-window -t '%4'",
+				"tmux select-pane -t '%4'",
+				"tmux display-message -p -t '%4' '#{window_zoomed_flag}'",
+				"tmux resize-pane -Z -t '%4'",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newSessionTestEnv(t)
+			if err := env.service.ApplyLayout(context.Background(), ws, tt.layout, agent); err != nil {
+				t.Fatalf("ApplyLayout failed: %v", err)
+			}
+			if got := strings.Join(env.exec.commands, "\n"); got != strings.Join(tt.want, "\n") {
+				t.Fatalf("commands:\n%s\nwant:\n%s", got, strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestApplyLayoutFocusRejectsOtherWorkspace(t *testing.T) {
+	env := newSessionTestEnv(t)
+	ws := &models.Workspace{ID: "ws-1", Name: "demo", TmuxSession: "swarm-demo"}
+	stranger := &models.Agent{ID: "agent-2", WorkspaceID: "ws-2", TmuxPane: "%9"}
+
+	err := env.service.ApplyLayout(context.Background(), ws, Layout{Preset: LayoutFocus, Agent: "agent-2"}, stranger)
+	if err == nil || !strings.Contains(err.Error(), "not in workspace demo") {
+		t.Fatalf("expected a workspace mismatch error, got %v", err)
+	}
+	if len(env.exec.commands) != 0 {
+		t.Fatalf("expected no tmux commands, got %q", env.exec.commands)
+	}
+}
@@ -0,0 +1,171 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// Pause errors.
+var (
+	ErrWorkspacePaused    = errors.New("workspace is already paused")
+	ErrWorkspaceNotPaused = errors.New("workspace is not paused")
+)
+
+// AgentPauser pauses and resumes individual agents. The agent service
+// satisfies it; a duration of zero pauses until resumed.
+type AgentPauser interface {
+	PauseAgent(ctx context.Context, id string, duration time.Duration) error
+	ResumeAgent(ctx context.Context, id string) error
+}
+
+// SchedulerPauser stops and restarts dispatch to individual agents.
+type SchedulerPauser interface {
+	PauseAgent(agentID string) error
+	ResumeAgent(agentID string) error
+}
+
+// PauseControls carries what a workspace pause cascades to. Scheduler is
+// optional: without a running scheduler the agents' paused state alone
+// keeps work from being dispatched.
+type PauseControls struct {
+	Agents    AgentPauser
+	Scheduler SchedulerPauser
+}
+
+// PauseWorkspace pauses every running agent in a workspace and marks the
+// workspace paused so no new agents are spawned into it. A positive
+// duration lets the pause lapse on its own; the agents carry the same
+// deadline so the scheduler resumes them when it passes.
+//
+// Agents that are already paused are left alone and not recorded, so
+// ResumeWorkspace does not resume agents paused independently.
+func (s *Service) PauseWorkspace(ctx context.Context, id string, duration time.Duration, controls PauseControls) (_ *models.Workspace, err error) {
+	defer func() { s.audit(ctx, AuditPauseWorkspace, id, audit.Params("duration", duration.String()), err) }()
+	if controls.Agents == nil {
+		return nil, errors.New("agent pauser is required")
+	}
+
+	workspace, err := s.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now().UTC()
+	if workspace.IsPaused(now) {
+		return nil, ErrWorkspacePaused
+	}
+
+	agents, err := s.agentRepo.ListByWorkspace(ctx, workspace.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	pause := &models.WorkspacePause{PausedAt: now}
+	if duration > 0 {
+		until := now.Add(duration)
+		pause.Until = &until
+	}
+
+	for _, agent := range agents {
+		if agent.State == models.AgentStatePaused || agent.State == models.AgentStateStopped || agent.State == models.AgentStateHibernated {
+			continue
+		}
+		if err := controls.Agents.PauseAgent(ctx, agent.ID, duration); err != nil {
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to pause agent")
+			continue
+		}
+		if controls.Scheduler != nil {
+			if err := controls.Scheduler.PauseAgent(agent.ID); err != nil {
+				s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agent.ID).Msg("failed to pause agent in scheduler")
+			}
+		}
+		pause.AgentIDs = append(pause.AgentIDs, agent.ID)
+	}
+
+	if err := s.setPause(ctx, workspace.ID, pause); err != nil {
+		return nil, err
+	}
+	workspace.Pause = pause
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("workspace_id", workspace.ID).
+		Int("agents", len(pause.AgentIDs)).
+		Dur("duration", duration).
+		Msg("workspace paused")
+	s.publishEvent(ctx, models.EventTypeWorkspacePaused, workspace.ID, nil)
+
+	return workspace, nil
+}
+
+// ResumeWorkspace clears a workspace pause and resumes the agents the pause
+// cascaded to. Agents that were paused before the workspace was, or that
+// have already resumed, are left as they are. The resumed agent IDs are
+// returned.
+func (s *Service) ResumeWorkspace(ctx context.Context, id string, controls PauseControls) (_ []string, err error) {
+	defer func() { s.audit(ctx, AuditResumeWorkspace, id, nil, err) }()
+	if controls.Agents == nil {
+		return nil, errors.New("agent pauser is required")
+	}
+
+	workspace, err := s.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.Pause == nil {
+		return nil, ErrWorkspaceNotPaused
+	}
+
+	resumed := make([]string, 0, len(workspace.Pause.AgentIDs))
+	for _, agentID := range workspace.Pause.AgentIDs {
+		agent, err := s.agentRepo.Get(ctx, agentID)
+		if err != nil {
+			if !errors.Is(err, db.ErrAgentNotFound) {
+				s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agentID).Msg("failed to load paused agent")
+			}
+			continue
+		}
+		if agent.State != models.AgentStatePaused {
+			continue
+		}
+		if err := controls.Agents.ResumeAgent(ctx, agentID); err != nil {
+			s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agentID).Msg("failed to resume agent")
+			continue
+		}
+		if controls.Scheduler != nil {
+			if err := controls.Scheduler.ResumeAgent(agentID); err != nil {
+				s.logger.Warn().Ctx(ctx).Err(err).Str("agent_id", agentID).Msg("failed to resume agent in scheduler")
+			}
+		}
+		resumed = append(resumed, agentID)
+	}
+
+	if err := s.setPause(ctx, workspace.ID, nil); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("workspace_id", workspace.ID).
+		Int("agents", len(resumed)).
+		Msg("workspace resumed")
+	s.publishEvent(ctx, models.EventTypeWorkspaceResumed, workspace.ID, nil)
+
+	return resumed, nil
+}
+
+func (s *Service) setPause(ctx context.Context, id string, pause *models.WorkspacePause) error {
+	if err := s.repo.SetPause(ctx, id, pause); err != nil {
+		if errors.Is(err, db.ErrWorkspaceNotFound) {
+			return ErrWorkspaceNotFound
+		}
+		return fmt.Errorf("failed to update workspace pause: %w", err)
+	}
+	return nil
+}
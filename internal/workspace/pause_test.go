@@ -0,0 +1,272 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/clock"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+)
+
+// fakeAgentPauser applies pauses straight to the agent rows.
+type fakeAgentPauser struct {
+	repo      *db.AgentRepository
+	clock     clock.Clock
+	durations map[string]time.Duration
+}
+
+func (f *fakeAgentPauser) PauseAgent(ctx context.Context, id string, duration time.Duration) error {
+	agent, err := f.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	agent.State = models.AgentStatePaused
+	agent.PausedUntil = nil
+	if duration > 0 {
+		until := f.clock.Now().Add(duration)
+		agent.PausedUntil = &until
+	}
+	f.durations[id] = duration
+	return f.repo.Update(ctx, agent)
+}
+
+func (f *fakeAgentPauser) ResumeAgent(ctx context.Context, id string) error {
+	agent, err := f.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	agent.State = models.AgentStateIdle
+	agent.PausedUntil = nil
+	return f.repo.Update(ctx, agent)
+}
+
+type fakeSchedulerPauser struct {
+	paused map[string]bool
+}
+
+func (f *fakeSchedulerPauser) PauseAgent(agentID string) error {
+	f.paused[agentID] = true
+	return nil
+}
+
+func (f *fakeSchedulerPauser) ResumeAgent(agentID string) error {
+	delete(f.paused, agentID)
+	return nil
+}
+
+type pauseTestEnv struct {
+	service   *Service
+	agentRepo *db.AgentRepository
+	clock     *clock.Fake
+	agents    *fakeAgentPauser
+	scheduler *fakeSchedulerPauser
+	workspace *models.Workspace
+	panes     int
+}
+
+func (e *pauseTestEnv) controls() PauseControls {
+	return PauseControls{Agents: e.agents, Scheduler: e.scheduler}
+}
+
+func (e *pauseTestEnv) addAgent(t *testing.T, state models.AgentState) *models.Agent {
+	t.Helper()
+	e.panes++
+	agent := &models.Agent{
+		WorkspaceID: e.workspace.ID,
+		Type:        models.AgentTypeOpenCode,
+		TmuxPane:    fmt.Sprintf("demo:0.%d", e.panes),
+		State:       state,
+		StateInfo:   models.StateInfo{State: state, Confidence: models.StateConfidenceHigh},
+	}
+	if err := e.agentRepo.Create(context.Background(), agent); err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	return agent
+}
+
+func (e *pauseTestEnv) agentState(t *testing.T, id string) models.AgentState {
+	t.Helper()
+	agent, err := e.agentRepo.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to get agent: %v", err)
+	}
+	return agent.State
+}
+
+func setupPauseTest(t *testing.T) *pauseTestEnv {
+	t.Helper()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	localNode := &models.Node{
+		Name:       "local",
+		IsLocal:    true,
+		Status:     models.NodeStatusUnknown,
+		SSHBackend: models.SSHBackendAuto,
+	}
+	if err := nodeRepo.Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create local node: %v", err)
+	}
+
+	fake := clock.NewFake(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	agentRepo := db.NewAgentRepository(database)
+	service := NewService(db.NewWorkspaceRepository(database), node.NewService(nodeRepo), agentRepo, WithClock(fake))
+	ws, err := service.CreateWorkspace(ctx, CreateWorkspaceInput{
+		NodeID:   localNode.ID,
+		RepoPath: t.TempDir(),
+		Name:     "demo",
+	})
+	if err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	return &pauseTestEnv{
+		service:   service,
+		agentRepo: agentRepo,
+		clock:     fake,
+		agents:    &fakeAgentPauser{repo: agentRepo, clock: fake, durations: map[string]time.Duration{}},
+		scheduler: &fakeSchedulerPauser{paused: map[string]bool{}},
+		workspace: ws,
+	}
+}
+
+func TestPauseWorkspace_CascadesToAgents(t *testing.T) {
+	env := setupPauseTest(t)
+	ctx := context.Background()
+	working := env.addAgent(t, models.AgentStateWorking)
+	idle := env.addAgent(t, models.AgentStateIdle)
+
+	paused, err := env.service.PauseWorkspace(ctx, env.workspace.ID, 0, env.controls())
+	if err != nil {
+		t.Fatalf("PauseWorkspace failed: %v", err)
+	}
+	if len(paused.Pause.AgentIDs) != 2 || paused.Pause.Until != nil {
+		t.Fatalf("unexpected pause record: %+v", paused.Pause)
+	}
+	for _, id := range []string{working.ID, idle.ID} {
+		if state := env.agentState(t, id); state != models.AgentStatePaused {
+			t.Fatalf("agent %s state = %s, want paused", id, state)
+		}
+		if !env.scheduler.paused[id] {
+			t.Fatalf("agent %s not paused in scheduler", id)
+		}
+	}
+
+	stored, err := env.service.GetWorkspace(ctx, env.workspace.ID)
+	if err != nil {
+		t.Fatalf("GetWorkspace failed: %v", err)
+	}
+	if !stored.IsPaused(env.clock.Now()) {
+		t.Fatalf("expected stored workspace to be paused: %+v", stored.Pause)
+	}
+	if _, err := env.service.PauseWorkspace(ctx, env.workspace.ID, 0, env.controls()); !errors.Is(err, ErrWorkspacePaused) {
+		t.Fatalf("expected ErrWorkspacePaused on second pause, got %v", err)
+	}
+
+	resumed, err := env.service.ResumeWorkspace(ctx, env.workspace.ID, env.controls())
+	if err != nil {
+		t.Fatalf("ResumeWorkspace failed: %v", err)
+	}
+	if len(resumed) != 2 {
+		t.Fatalf("expected 2 agents resumed, got %v", resumed)
+	}
+	for _, id := range []string{working.ID, idle.ID} {
+		if state := env.agentState(t, id); state == models.AgentStatePaused {
+			t.Fatalf("agent %s still paused after resume", id)
+		}
+		if env.scheduler.paused[id] {
+			t.Fatalf("agent %s still paused in scheduler", id)
+		}
+	}
+
+	stored, err = env.service.GetWorkspace(ctx, env.workspace.ID)
+	if err != nil {
+		t.Fatalf("GetWorkspace failed: %v", err)
+	}
+	if stored.Pause != nil {
+		t.Fatalf("expected pause to be cleared, got %+v", stored.Pause)
+	}
+	if _, err := env.service.ResumeWorkspace(ctx, env.workspace.ID, env.controls()); !errors.Is(err, ErrWorkspaceNotPaused) {
+		t.Fatalf("expected ErrWorkspaceNotPaused, got %v", err)
+	}
+}
+
+func TestResumeWorkspace_KeepsIndependentPauses(t *testing.T) {
+	env := setupPauseTest(t)
+	ctx := context.Background()
+	running := env.addAgent(t, models.AgentStateWorking)
+	independent := env.addAgent(t, models.AgentStatePaused)
+
+	paused, err := env.service.PauseWorkspace(ctx, env.workspace.ID, 0, env.controls())
+	if err != nil {
+		t.Fatalf("PauseWorkspace failed: %v", err)
+	}
+	if len(paused.Pause.AgentIDs) != 1 || paused.Pause.AgentIDs[0] != running.ID {
+		t.Fatalf("expected only the running agent in the cascade, got %v", paused.Pause.AgentIDs)
+	}
+	if _, ok := env.agents.durations[independent.ID]; ok {
+		t.Fatalf("independently paused agent was paused again")
+	}
+
+	resumed, err := env.service.ResumeWorkspace(ctx, env.workspace.ID, env.controls())
+	if err != nil {
+		t.Fatalf("ResumeWorkspace failed: %v", err)
+	}
+	if len(resumed) != 1 || resumed[0] != running.ID {
+		t.Fatalf("expected only %s resumed, got %v", running.ID, resumed)
+	}
+	if state := env.agentState(t, independent.ID); state != models.AgentStatePaused {
+		t.Fatalf("independently paused agent state = %s, want paused", state)
+	}
+}
+
+func TestPauseWorkspace_LapsesAfterDuration(t *testing.T) {
+	env := setupPauseTest(t)
+	ctx := context.Background()
+	agent := env.addAgent(t, models.AgentStateIdle)
+
+	if _, err := env.service.PauseWorkspace(ctx, env.workspace.ID, 2*time.Hour, env.controls()); err != nil {
+		t.Fatalf("PauseWorkspace failed: %v", err)
+	}
+	if got := env.agents.durations[agent.ID]; got != 2*time.Hour {
+		t.Fatalf("agent paused for %s, want 2h so the scheduler resumes it", got)
+	}
+
+	status, err := env.service.GetWorkspaceStatus(ctx, env.workspace.ID)
+	if err != nil {
+		t.Fatalf("GetWorkspaceStatus failed: %v", err)
+	}
+	if !status.Paused {
+		t.Fatal("expected workspace to be paused before the duration elapses")
+	}
+
+	env.clock.Advance(2*time.Hour + time.Minute)
+
+	status, err = env.service.GetWorkspaceStatus(ctx, env.workspace.ID)
+	if err != nil {
+		t.Fatalf("GetWorkspaceStatus failed: %v", err)
+	}
+	if status.Paused {
+		t.Fatalf("expected pause to lapse, got %+v", status.Workspace.Pause)
+	}
+
+	// A lapsed pause does not block pausing again.
+	if _, err := env.service.PauseWorkspace(ctx, env.workspace.ID, time.Hour, env.controls()); err != nil {
+		t.Fatalf("PauseWorkspace after lapse failed: %v", err)
+	}
+}
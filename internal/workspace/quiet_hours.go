@@ -0,0 +1,39 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/audit"
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/quiethours"
+)
+
+// SetQuietHours sets the quiet hours of a workspace, during which the
+// scheduler dispatches nothing to its agents. An empty spec clears them
+// so the workspace inherits the global setting; "none" turns them off.
+func (s *Service) SetQuietHours(ctx context.Context, id, spec string) (_ *models.Workspace, err error) {
+	spec = strings.TrimSpace(spec)
+	defer func() { s.audit(ctx, AuditSetQuietHours, id, audit.Params("quiet_hours", spec), err) }()
+
+	if _, err := quiethours.ParseSchedule(spec); err != nil {
+		return nil, fmt.Errorf("invalid quiet hours: %w", err)
+	}
+	if err := s.repo.SetQuietHours(ctx, id, spec); err != nil {
+		if errors.Is(err, db.ErrWorkspaceNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, fmt.Errorf("failed to update workspace quiet hours: %w", err)
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("workspace_id", id).
+		Str("quiet_hours", spec).
+		Msg("workspace quiet hours set")
+
+	return s.GetWorkspace(ctx, id)
+}
@@ -56,6 +56,10 @@ func setupWorkspaceTestDB(t *testing.T) *db.DB {
 			is_local INTEGER NOT NULL DEFAULT 0,
 			last_seen_at TEXT,
 			metadata_json TEXT,
+			labels_json TEXT,
+			daemon_endpoint TEXT NOT NULL DEFAULT '',
+			daemon_token TEXT NOT NULL DEFAULT '',
+			metrics_json TEXT,
 			created_at TEXT NOT NULL DEFAULT (datetime('now')),
 			updated_at TEXT NOT NULL DEFAULT (datetime('now'))
 		);`,
@@ -67,6 +71,12 @@ func setupWorkspaceTestDB(t *testing.T) *db.DB {
 			tmux_session TEXT NOT NULL,
 			status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive', 'error')),
 			git_info_json TEXT,
+			placement_json TEXT,
+			pause_json TEXT,
+			draining INTEGER NOT NULL DEFAULT 0,
+			quiet_hours TEXT,
+			hibernate_after_seconds INTEGER,
+			bootstrap_json TEXT,
 			created_at TEXT NOT NULL DEFAULT (datetime('now')),
 			updated_at TEXT NOT NULL DEFAULT (datetime('now')),
 			UNIQUE(node_id, repo_path),
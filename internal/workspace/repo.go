@@ -240,6 +240,21 @@ func isGitRoot(path string) bool {
 	return info.IsDir() || info.Mode().IsRegular()
 }
 
+// CanonicalRepoPath returns the form of a repo path that workspaces are
+// stored and looked up by: absolute and cleaned, with symlinks resolved
+// when the path exists on this machine. Two spellings of the same
+// directory map to the same workspace.
+func CanonicalRepoPath(path string) string {
+	path = normalizePath(path)
+	if path == "" {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
 func normalizePath(path string) string {
 	if strings.TrimSpace(path) == "" {
 		return ""
@@ -3,18 +3,24 @@ package workspace
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/opencode-ai/swarm/internal/adapters"
 	"github.com/opencode-ai/swarm/internal/agentmail"
+	"github.com/opencode-ai/swarm/internal/audit"
 	"github.com/opencode-ai/swarm/internal/beads"
+	"github.com/opencode-ai/swarm/internal/clock"
 	"github.com/opencode-ai/swarm/internal/db"
 	"github.com/opencode-ai/swarm/internal/events"
 	"github.com/opencode-ai/swarm/internal/logging"
 	"github.com/opencode-ai/swarm/internal/models"
 	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/store"
 	"github.com/opencode-ai/swarm/internal/tmux"
 	"github.com/rs/zerolog"
 )
@@ -44,13 +50,16 @@ const (
 
 // Service manages workspace operations.
 type Service struct {
-	repo        *db.WorkspaceRepository
+	repo        store.WorkspaceStore
 	nodeService *node.Service
-	agentRepo   *db.AgentRepository
-	eventRepo   *db.EventRepository
+	agentRepo   store.AgentStore
+	eventRepo   store.EventStore
 	publisher   events.Publisher
 	tmuxFactory func() *tmux.Client
+	executor    CommandExecutor
+	clock       clock.Clock
 	logger      zerolog.Logger
+	auditor     *audit.Recorder
 }
 
 // ServiceOption configures a WorkspaceService.
@@ -63,8 +72,8 @@ func WithPublisher(publisher events.Publisher) ServiceOption {
 	}
 }
 
-// WithEventRepository sets the event repository for pulse calculations.
-func WithEventRepository(eventRepo *db.EventRepository) ServiceOption {
+// WithEventRepository sets the event store for pulse calculations.
+func WithEventRepository(eventRepo store.EventStore) ServiceOption {
 	return func(s *Service) {
 		s.eventRepo = eventRepo
 	}
@@ -77,8 +86,23 @@ func WithTmuxClientFactory(factory func() *tmux.Client) ServiceOption {
 	}
 }
 
+// WithCommandExecutor overrides how shell commands, such as the git clone
+// of CreateWorkspace, run on a workspace's node. Default: the node service.
+func WithCommandExecutor(executor CommandExecutor) ServiceOption {
+	return func(s *Service) {
+		s.executor = executor
+	}
+}
+
+// WithClock configures the time source used for workspace pauses.
+func WithClock(c clock.Clock) ServiceOption {
+	return func(s *Service) {
+		s.clock = c
+	}
+}
+
 // NewService creates a new WorkspaceService.
-func NewService(repo *db.WorkspaceRepository, nodeService *node.Service, agentRepo *db.AgentRepository, opts ...ServiceOption) *Service {
+func NewService(repo store.WorkspaceStore, nodeService *node.Service, agentRepo store.AgentStore, opts ...ServiceOption) *Service {
 	s := &Service{
 		repo:        repo,
 		nodeService: nodeService,
@@ -89,15 +113,22 @@ func NewService(repo *db.WorkspaceRepository, nodeService *node.Service, agentRe
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.executor == nil && nodeService != nil {
+		s.executor = nodeService
+	}
+	s.clock = clock.OrReal(s.clock)
 	return s
 }
 
 // CreateWorkspaceInput contains the parameters for creating a workspace.
 type CreateWorkspaceInput struct {
 	// NodeID is the node where the workspace will be created.
-	// If empty, uses the local node.
+	// If empty, Placement chooses one, or the local node is used.
 	NodeID string
 
+	// Placement selects a node automatically when NodeID is empty.
+	Placement *node.PlacementRequest
+
 	// RepoPath is the absolute path to the repository.
 	RepoPath string
 
@@ -111,22 +142,53 @@ type CreateWorkspaceInput struct {
 
 	// CreateTmuxSession indicates whether to create a new tmux session.
 	CreateTmuxSession bool
+
+	// Clone, when set, clones a remote repository into RepoPath on the
+	// node first. RepoPath must be absolute.
+	Clone *RepoClone
+
+	// AdoptExisting returns the workspace already registered for RepoPath
+	// on the node, including one created concurrently, instead of failing
+	// with ErrWorkspaceAlreadyExists.
+	AdoptExisting bool
 }
 
 // CreateWorkspace creates a new workspace for a repository.
-func (s *Service) CreateWorkspace(ctx context.Context, input CreateWorkspaceInput) (*models.Workspace, error) {
+func (s *Service) CreateWorkspace(ctx context.Context, input CreateWorkspaceInput) (created *models.Workspace, err error) {
+	defer func() {
+		params := audit.Params("node_id", input.NodeID, "repo_path", input.RepoPath, "name", input.Name)
+		if input.Clone != nil {
+			params["clone_url"] = input.Clone.URL
+		}
+		s.audit(ctx, AuditCreateWorkspace, workspaceID(created), params, err)
+	}()
 	s.logger.Debug().
+		Ctx(ctx).
 		Str("node_id", input.NodeID).
 		Str("repo_path", input.RepoPath).
 		Msg("creating workspace")
 
-	// Validate repo path exists
-	if err := ValidateRepoPath(input.RepoPath); err != nil {
+	// Validate repo path exists; a clone is validated on its node instead.
+	if input.Clone != nil {
+		if err := input.Clone.validate(input.RepoPath); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRepoValidationFailed, err)
+		}
+	} else if err := ValidateRepoPath(input.RepoPath); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrRepoValidationFailed, err)
 	}
+	input.RepoPath = CanonicalRepoPath(input.RepoPath)
 
-	// Get or default node
+	// Get, place, or default node
 	nodeID := input.NodeID
+	var placement *models.WorkspacePlacement
+	if nodeID == "" && input.Placement != nil {
+		placed, err := s.nodeService.PlaceWorkspace(ctx, *input.Placement)
+		if err != nil {
+			return nil, fmt.Errorf("failed to place workspace: %w", err)
+		}
+		nodeID = placed.Node.ID
+		placement = placed.Record()
+	}
 	if nodeID == "" {
 		// Use local node - it must exist
 		nodes, err := s.nodeService.ListNodes(ctx, nil)
@@ -145,7 +207,7 @@ func (s *Service) CreateWorkspace(ctx context.Context, input CreateWorkspaceInpu
 	}
 
 	// Verify node exists
-	_, err := s.nodeService.GetNode(ctx, nodeID)
+	nodeObj, err := s.nodeService.GetNode(ctx, nodeID)
 	if err != nil {
 		if errors.Is(err, node.ErrNodeNotFound) {
 			return nil, ErrNodeNotFound
@@ -153,30 +215,57 @@ func (s *Service) CreateWorkspace(ctx context.Context, input CreateWorkspaceInpu
 		return nil, fmt.Errorf("failed to get node: %w", err)
 	}
 
+	if existing, err := s.existingWorkspace(ctx, nodeID, input.RepoPath); err != nil {
+		return nil, err
+	} else if existing != nil {
+		if input.AdoptExisting {
+			return existing, nil
+		}
+		return nil, ErrWorkspaceAlreadyExists
+	}
+
+	if input.Clone != nil {
+		if err := s.cloneRepo(ctx, nodeObj, input.RepoPath, *input.Clone); err != nil {
+			return nil, err
+		}
+	}
+
+	// Assign the ID up front so the generated session name can include it.
+	workspaceID := uuid.New().String()
+
 	// Generate tmux session name if not provided
-	tmuxSession := input.TmuxSession
+	tmuxSession := strings.TrimSpace(input.TmuxSession)
 	if tmuxSession == "" {
-		tmuxSession, err = GenerateTmuxSessionName("swarm", input.RepoPath)
+		tmuxSession, err = s.uniqueSessionName(ctx, nodeID, sessionBaseName(input.Name, input.RepoPath, workspaceID), input.CreateTmuxSession, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate tmux session name: %w", err)
 		}
+	} else {
+		if err := tmux.ValidateSessionName(tmuxSession); err != nil {
+			return nil, err
+		}
+		if err := s.checkSessionAvailable(ctx, nodeID, tmuxSession, ""); err != nil {
+			return nil, err
+		}
 	}
 
 	// Detect git info
 	gitInfo, err := DetectGitInfo(input.RepoPath)
 	if err != nil {
-		s.logger.Warn().Err(err).Msg("failed to detect git info")
+		s.logger.Warn().Ctx(ctx).Err(err).Msg("failed to detect git info")
 		// Not fatal - continue without git info
 	}
 
 	// Create workspace record
 	workspace := &models.Workspace{
+		ID:          workspaceID,
 		Name:        input.Name,
 		NodeID:      nodeID,
 		RepoPath:    input.RepoPath,
 		TmuxSession: tmuxSession,
 		Status:      models.WorkspaceStatusActive,
 		GitInfo:     gitInfo,
+		Placement:   placement,
 	}
 
 	if workspace.Name == "" {
@@ -190,26 +279,51 @@ func (s *Service) CreateWorkspace(ctx context.Context, input CreateWorkspaceInpu
 		}
 	}
 
-	// Persist to database
+	// Persist to database. The unique index is what settles concurrent
+	// creates for the same path; the loser undoes its session.
 	if err := s.repo.Create(ctx, workspace); err != nil {
-		if errors.Is(err, db.ErrWorkspaceAlreadyExists) {
-			return nil, ErrWorkspaceAlreadyExists
+		if !errors.Is(err, db.ErrWorkspaceAlreadyExists) {
+			return nil, fmt.Errorf("failed to create workspace: %w", err)
 		}
-		return nil, fmt.Errorf("failed to create workspace: %w", err)
+		if input.CreateTmuxSession {
+			if killErr := s.tmuxClient().KillSession(ctx, workspace.TmuxSession); killErr != nil {
+				s.logger.Warn().Ctx(ctx).Err(killErr).Str("tmux_session", workspace.TmuxSession).Msg("failed to kill session of duplicate workspace")
+			}
+		}
+		if input.AdoptExisting {
+			if existing, findErr := s.existingWorkspace(ctx, nodeID, input.RepoPath); findErr == nil && existing != nil {
+				return existing, nil
+			}
+		}
+		return nil, ErrWorkspaceAlreadyExists
 	}
 
 	s.logger.Info().
+		Ctx(ctx).
 		Str("workspace_id", workspace.ID).
 		Str("name", workspace.Name).
 		Str("tmux_session", workspace.TmuxSession).
 		Msg("workspace created")
 
 	// Emit event
-	s.publishEvent(ctx, models.EventTypeWorkspaceCreated, workspace.ID, nil)
+	s.publishEvent(ctx, models.EventTypeWorkspaceCreated, workspace.ID, lifecyclePayload(workspace))
 
 	return workspace, nil
 }
 
+// existingWorkspace returns the workspace registered for repoPath on the
+// node, or nil if there is none.
+func (s *Service) existingWorkspace(ctx context.Context, nodeID, repoPath string) (*models.Workspace, error) {
+	existing, err := s.repo.GetByNodeAndPath(ctx, nodeID, repoPath)
+	if errors.Is(err, db.ErrWorkspaceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check repo path: %w", err)
+	}
+	return existing, nil
+}
+
 // ImportWorkspaceInput contains the parameters for importing an existing tmux session.
 type ImportWorkspaceInput struct {
 	// NodeID is the node where the session exists.
@@ -226,8 +340,12 @@ type ImportWorkspaceInput struct {
 }
 
 // ImportWorkspace imports an existing tmux session as a workspace.
-func (s *Service) ImportWorkspace(ctx context.Context, input ImportWorkspaceInput) (*models.Workspace, error) {
+func (s *Service) ImportWorkspace(ctx context.Context, input ImportWorkspaceInput) (imported *models.Workspace, err error) {
+	defer func() {
+		s.audit(ctx, AuditImportWorkspace, workspaceID(imported), audit.Params("node_id", input.NodeID, "tmux_session", input.TmuxSession, "repo_path", input.RepoPath), err)
+	}()
 	s.logger.Debug().
+		Ctx(ctx).
 		Str("node_id", input.NodeID).
 		Str("tmux_session", input.TmuxSession).
 		Msg("importing workspace")
@@ -241,6 +359,10 @@ func (s *Service) ImportWorkspace(ctx context.Context, input ImportWorkspaceInpu
 		return nil, fmt.Errorf("failed to get node: %w", err)
 	}
 
+	if err := s.checkSessionAvailable(ctx, input.NodeID, input.TmuxSession, ""); err != nil {
+		return nil, err
+	}
+
 	repoPath := input.RepoPath
 	if repoPath == "" {
 		// Get working directory from tmux session
@@ -251,11 +373,12 @@ func (s *Service) ImportWorkspace(ctx context.Context, input ImportWorkspaceInpu
 	} else if err := ValidateRepoPath(repoPath); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrRepoValidationFailed, err)
 	}
+	repoPath = CanonicalRepoPath(repoPath)
 
 	// Detect git info
 	gitInfo, err := DetectGitInfo(repoPath)
 	if err != nil {
-		s.logger.Warn().Err(err).Msg("failed to detect git info")
+		s.logger.Warn().Ctx(ctx).Err(err).Msg("failed to detect git info")
 	}
 
 	// Create workspace record
@@ -281,18 +404,19 @@ func (s *Service) ImportWorkspace(ctx context.Context, input ImportWorkspaceInpu
 	}
 
 	s.logger.Info().
+		Ctx(ctx).
 		Str("workspace_id", workspace.ID).
 		Str("name", workspace.Name).
 		Str("tmux_session", workspace.TmuxSession).
 		Msg("workspace imported")
 
 	// Emit event
-	s.publishEvent(ctx, models.EventTypeWorkspaceImported, workspace.ID, nil)
+	s.publishEvent(ctx, models.EventTypeWorkspaceImported, workspace.ID, lifecyclePayload(workspace))
 
 	// Best-effort discovery of existing agents in the session.
 	if s.agentRepo != nil && nodeObj.IsLocal {
 		if err := s.discoverAgentsInSession(ctx, workspace); err != nil {
-			s.logger.Warn().Err(err).Str("workspace_id", workspace.ID).Msg("failed to discover agents in session")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("workspace_id", workspace.ID).Msg("failed to discover agents in session")
 		}
 	}
 
@@ -335,7 +459,7 @@ func (s *Service) ListWorkspaces(ctx context.Context, opts ListWorkspacesOptions
 		for _, ws := range workspaces {
 			count, err := s.repo.GetAgentCount(ctx, ws.ID)
 			if err != nil {
-				s.logger.Warn().Err(err).Str("workspace_id", ws.ID).Msg("failed to get agent count")
+				s.logger.Warn().Ctx(ctx).Err(err).Str("workspace_id", ws.ID).Msg("failed to get agent count")
 				continue
 			}
 			ws.AgentCount = count
@@ -358,7 +482,7 @@ func (s *Service) GetWorkspace(ctx context.Context, id string) (*models.Workspac
 	// Populate agent count
 	count, err := s.repo.GetAgentCount(ctx, workspace.ID)
 	if err != nil {
-		s.logger.Warn().Err(err).Str("workspace_id", workspace.ID).Msg("failed to get agent count")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("workspace_id", workspace.ID).Msg("failed to get agent count")
 	} else {
 		workspace.AgentCount = count
 	}
@@ -374,6 +498,9 @@ type WorkspaceStatusResult struct {
 	// TmuxActive indicates if the tmux session is active.
 	TmuxActive bool
 
+	// PaneZoomed indicates the session's current window has a zoomed pane.
+	PaneZoomed bool
+
 	// NodeOnline indicates if the node is reachable.
 	NodeOnline bool
 
@@ -400,6 +527,13 @@ type WorkspaceStatusResult struct {
 
 	// Pulse captures recent activity for the workspace.
 	Pulse *WorkspacePulse
+
+	// Paused indicates the workspace is paused and rejects new agents.
+	Paused bool
+
+	// LatestNote is the newest note left on the workspace, if any. The
+	// service leaves it for callers to fill.
+	LatestNote *models.Note `json:",omitempty"`
 }
 
 // WorkspacePulse summarizes recent workspace activity.
@@ -421,6 +555,7 @@ func (s *Service) GetWorkspaceStatus(ctx context.Context, id string) (*Workspace
 	result := &WorkspaceStatusResult{
 		Workspace: workspace,
 		GitInfo:   workspace.GitInfo,
+		Paused:    workspace.IsPaused(s.clock.Now()),
 	}
 
 	// Check if node is online
@@ -433,6 +568,9 @@ func (s *Service) GetWorkspaceStatus(ctx context.Context, id string) (*Workspace
 	if result.NodeOnline {
 		result.TmuxActive = s.isTmuxSessionActive(ctx, nodeObj, workspace.TmuxSession)
 	}
+	if result.TmuxActive {
+		result.PaneZoomed = s.isPaneZoomed(ctx, workspace.TmuxSession)
+	}
 
 	// Refresh git info
 	if workspace.RepoPath != "" {
@@ -440,7 +578,7 @@ func (s *Service) GetWorkspaceStatus(ctx context.Context, id string) (*Workspace
 			result.GitInfo = gitInfo
 			// Update stored git info
 			if err := s.repo.UpdateGitInfo(ctx, workspace.ID, gitInfo); err != nil {
-				s.logger.Warn().Err(err).Msg("failed to update git info")
+				s.logger.Warn().Ctx(ctx).Err(err).Msg("failed to update git info")
 			}
 		}
 	}
@@ -448,7 +586,7 @@ func (s *Service) GetWorkspaceStatus(ctx context.Context, id string) (*Workspace
 	if workspace.RepoPath != "" {
 		detected, err := beads.HasBeadsDir(workspace.RepoPath)
 		if err != nil {
-			s.logger.Warn().Err(err).Msg("failed to detect beads directory")
+			s.logger.Warn().Ctx(ctx).Err(err).Msg("failed to detect beads directory")
 		} else {
 			result.BeadsDetected = detected
 		}
@@ -457,7 +595,7 @@ func (s *Service) GetWorkspaceStatus(ctx context.Context, id string) (*Workspace
 	if workspace.RepoPath != "" {
 		detected, err := agentmail.HasAgentMailConfig(workspace.RepoPath)
 		if err != nil {
-			s.logger.Warn().Err(err).Msg("failed to detect Agent Mail config")
+			s.logger.Warn().Ctx(ctx).Err(err).Msg("failed to detect Agent Mail config")
 		} else {
 			result.AgentMailDetected = detected
 		}
@@ -467,7 +605,7 @@ func (s *Service) GetWorkspaceStatus(ctx context.Context, id string) (*Workspace
 	if s.agentRepo != nil {
 		agents, err := s.agentRepo.ListByWorkspace(ctx, workspace.ID)
 		if err != nil {
-			s.logger.Warn().Err(err).Msg("failed to list agents for alerts")
+			s.logger.Warn().Ctx(ctx).Err(err).Msg("failed to list agents for alerts")
 		} else {
 			result.Alerts = append(result.Alerts, BuildAlerts(agents)...)
 
@@ -477,7 +615,7 @@ func (s *Service) GetWorkspaceStatus(ctx context.Context, id string) (*Workspace
 					result.ActiveAgents++
 				case models.AgentStateIdle:
 					result.IdleAgents++
-				case models.AgentStateAwaitingApproval, models.AgentStateRateLimited, models.AgentStateError:
+				case models.AgentStateAwaitingApproval, models.AgentStateRateLimited, models.AgentStateError, models.AgentStateStuck:
 					result.BlockedAgents++
 				}
 			}
@@ -494,8 +632,9 @@ func (s *Service) GetWorkspaceStatus(ctx context.Context, id string) (*Workspace
 
 // DeleteWorkspace removes a workspace from Swarm.
 // It does not terminate the tmux session or delete files.
-func (s *Service) DeleteWorkspace(ctx context.Context, id string) error {
-	s.logger.Debug().Str("workspace_id", id).Msg("deleting workspace")
+func (s *Service) DeleteWorkspace(ctx context.Context, id string) (err error) {
+	defer func() { s.audit(ctx, AuditDeleteWorkspace, id, nil, err) }()
+	s.logger.Debug().Ctx(ctx).Str("workspace_id", id).Msg("deleting workspace")
 
 	if err := s.repo.Delete(ctx, id); err != nil {
 		if errors.Is(err, db.ErrWorkspaceNotFound) {
@@ -504,13 +643,14 @@ func (s *Service) DeleteWorkspace(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to delete workspace: %w", err)
 	}
 
-	s.logger.Info().Str("workspace_id", id).Msg("workspace deleted")
+	s.logger.Info().Ctx(ctx).Str("workspace_id", id).Msg("workspace deleted")
 	return nil
 }
 
 // UpdateWorkspace updates a workspace's configuration.
-func (s *Service) UpdateWorkspace(ctx context.Context, workspace *models.Workspace) error {
-	s.logger.Debug().Str("workspace_id", workspace.ID).Msg("updating workspace")
+func (s *Service) UpdateWorkspace(ctx context.Context, workspace *models.Workspace) (err error) {
+	defer func() { s.audit(ctx, AuditUpdateWorkspace, workspace.ID, audit.Params("name", workspace.Name), err) }()
+	s.logger.Debug().Ctx(ctx).Str("workspace_id", workspace.ID).Msg("updating workspace")
 
 	if err := s.repo.Update(ctx, workspace); err != nil {
 		if errors.Is(err, db.ErrWorkspaceNotFound) {
@@ -519,7 +659,7 @@ func (s *Service) UpdateWorkspace(ctx context.Context, workspace *models.Workspa
 		return fmt.Errorf("failed to update workspace: %w", err)
 	}
 
-	s.logger.Info().Str("workspace_id", workspace.ID).Msg("workspace updated")
+	s.logger.Info().Ctx(ctx).Str("workspace_id", workspace.ID).Msg("workspace updated")
 	return nil
 }
 
@@ -559,75 +699,123 @@ func (s *Service) AttachWorkspace(ctx context.Context, id string) (string, error
 	return fmt.Sprintf("tmux attach -t %s", workspace.TmuxSession), nil
 }
 
+// RemovalPlan describes what removing a workspace does.
+type RemovalPlan struct {
+	WorkspaceID string `json:"workspace_id"`
+	Name        string `json:"name"`
+	NodeID      string `json:"node_id"`
+	TmuxSession string `json:"tmux_session,omitempty"`
+	// KillSession is true when the live tmux session is killed.
+	KillSession bool `json:"kill_session"`
+	DryRun      bool `json:"dry_run"`
+}
+
 // UnmanageWorkspace removes a workspace record while leaving tmux intact.
-func (s *Service) UnmanageWorkspace(ctx context.Context, id string) error {
+// With dryRun, only the plan is returned.
+func (s *Service) UnmanageWorkspace(ctx context.Context, id string, dryRun bool) (_ *RemovalPlan, err error) {
+	if !dryRun {
+		defer func() { s.audit(ctx, AuditUnmanageWorkspace, id, nil, err) }()
+	}
+	workspace, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrWorkspaceNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	plan := removalPlan(workspace, dryRun)
+	if dryRun {
+		return plan, nil
+	}
+
 	if err := s.repo.Delete(ctx, id); err != nil {
 		if errors.Is(err, db.ErrWorkspaceNotFound) {
-			return ErrWorkspaceNotFound
+			return nil, ErrWorkspaceNotFound
 		}
-		return fmt.Errorf("failed to unmanage workspace: %w", err)
+		return nil, fmt.Errorf("failed to unmanage workspace: %w", err)
 	}
 
-	s.logger.Info().Str("workspace_id", id).Msg("workspace unmanaged")
+	s.logger.Info().Ctx(ctx).Str("workspace_id", id).Msg("workspace unmanaged")
 
 	// Emit event
-	s.publishEvent(ctx, models.EventTypeWorkspaceUnmanaged, id, nil)
+	s.publishEvent(ctx, models.EventTypeWorkspaceUnmanaged, id, lifecyclePayload(workspace))
 
-	return nil
+	return plan, nil
 }
 
-// DestroyWorkspace kills the tmux session (if local) and removes the workspace record.
-func (s *Service) DestroyWorkspace(ctx context.Context, id string) error {
+// DestroyWorkspace kills the tmux session (if local) and removes the
+// workspace record. With dryRun, only the plan is returned.
+func (s *Service) DestroyWorkspace(ctx context.Context, id string, dryRun bool) (_ *RemovalPlan, err error) {
+	if !dryRun {
+		defer func() { s.audit(ctx, AuditDestroyWorkspace, id, nil, err) }()
+	}
 	workspace, err := s.repo.Get(ctx, id)
 	if err != nil {
 		if errors.Is(err, db.ErrWorkspaceNotFound) {
-			return ErrWorkspaceNotFound
+			return nil, ErrWorkspaceNotFound
 		}
-		return fmt.Errorf("failed to get workspace: %w", err)
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
 	}
 
 	nodeObj, err := s.nodeService.GetNode(ctx, workspace.NodeID)
 	if err != nil {
 		if errors.Is(err, node.ErrNodeNotFound) {
-			return ErrNodeNotFound
+			return nil, ErrNodeNotFound
 		}
-		return fmt.Errorf("failed to get node: %w", err)
+		return nil, fmt.Errorf("failed to get node: %w", err)
 	}
 
 	if !nodeObj.IsLocal {
-		return fmt.Errorf("remote workspace destroy not yet implemented")
+		return nil, fmt.Errorf("remote workspace destroy not yet implemented")
 	}
 
+	plan := removalPlan(workspace, dryRun)
 	if workspace.TmuxSession != "" {
 		client := s.tmuxClient()
 		exists, err := client.HasSession(ctx, workspace.TmuxSession)
 		if err != nil {
-			return fmt.Errorf("failed to check tmux session: %w", err)
+			return nil, fmt.Errorf("failed to check tmux session: %w", err)
 		}
-		if exists {
+		plan.KillSession = exists
+		if exists && !dryRun {
 			s.gracefulShutdownAgents(ctx, client, workspace.ID, workspace.TmuxSession)
 			if err := client.KillSession(ctx, workspace.TmuxSession); err != nil {
-				return fmt.Errorf("failed to kill tmux session %s: %w", workspace.TmuxSession, err)
+				return nil, fmt.Errorf("failed to kill tmux session %s: %w", workspace.TmuxSession, err)
 			}
 		}
 	}
+	if dryRun {
+		return plan, nil
+	}
 
 	if err := s.repo.Delete(ctx, id); err != nil {
 		if errors.Is(err, db.ErrWorkspaceNotFound) {
-			return ErrWorkspaceNotFound
+			return nil, ErrWorkspaceNotFound
 		}
-		return fmt.Errorf("failed to delete workspace: %w", err)
+		return nil, fmt.Errorf("failed to delete workspace: %w", err)
 	}
 
 	s.logger.Info().
+		Ctx(ctx).
 		Str("workspace_id", id).
 		Str("tmux_session", workspace.TmuxSession).
 		Msg("workspace destroyed")
 
 	// Emit event
-	s.publishEvent(ctx, models.EventTypeWorkspaceDestroyed, id, nil)
+	s.publishEvent(ctx, models.EventTypeWorkspaceDestroyed, id, lifecyclePayload(workspace))
 
-	return nil
+	return plan, nil
+}
+
+func removalPlan(workspace *models.Workspace, dryRun bool) *RemovalPlan {
+	return &RemovalPlan{
+		WorkspaceID: workspace.ID,
+		Name:        workspace.Name,
+		NodeID:      workspace.NodeID,
+		TmuxSession: workspace.TmuxSession,
+		DryRun:      dryRun,
+	}
 }
 
 func (s *Service) gracefulShutdownAgents(ctx context.Context, client *tmux.Client, workspaceID, session string) {
@@ -637,7 +825,7 @@ func (s *Service) gracefulShutdownAgents(ctx context.Context, client *tmux.Clien
 
 	agents, err := s.agentRepo.ListByWorkspace(ctx, workspaceID)
 	if err != nil {
-		s.logger.Warn().Err(err).Str("workspace_id", workspaceID).Msg("failed to list agents before destroy")
+		s.logger.Warn().Ctx(ctx).Err(err).Str("workspace_id", workspaceID).Msg("failed to list agents before destroy")
 		return
 	}
 	if len(agents) == 0 {
@@ -656,6 +844,7 @@ func (s *Service) gracefulShutdownAgents(ctx context.Context, client *tmux.Clien
 
 		if err := client.SendInterrupt(ctx, pane); err != nil {
 			s.logger.Warn().
+				Ctx(ctx).
 				Err(err).
 				Str("agent_id", agent.ID).
 				Str("pane", pane).
@@ -664,7 +853,7 @@ func (s *Service) gracefulShutdownAgents(ctx context.Context, client *tmux.Clien
 		}
 
 		interrupted++
-		if strings.HasPrefix(pane, session+":") {
+		if agent.TmuxSession == session {
 			targets = append(targets, pane)
 		} else {
 			hasMismatchedSession = true
@@ -682,6 +871,7 @@ func (s *Service) gracefulShutdownAgents(ctx context.Context, client *tmux.Clien
 	exited := s.waitForAgentsExit(ctx, client, session, targets, hasMismatchedSession)
 	if !exited {
 		s.logger.Debug().
+			Ctx(ctx).
 			Str("workspace_id", workspaceID).
 			Str("tmux_session", session).
 			Msg("grace period elapsed before agents exited")
@@ -698,7 +888,7 @@ func (s *Service) waitForAgentsExit(ctx context.Context, client *tmux.Client, se
 		if !forceTimeout {
 			allExited, err := s.allAgentPanesExited(ctx, client, session, targets)
 			if err != nil {
-				s.logger.Warn().Err(err).Str("tmux_session", session).Msg("failed to check agent panes during shutdown")
+				s.logger.Warn().Ctx(ctx).Err(err).Str("tmux_session", session).Msg("failed to check agent panes during shutdown")
 				return false
 			}
 			if allExited {
@@ -716,28 +906,25 @@ func (s *Service) waitForAgentsExit(ctx context.Context, client *tmux.Client, se
 	}
 }
 
+// allAgentPanesExited reports whether none of the agent panes in targets,
+// given by pane ID, still runs a program: each is gone or back at its
+// shell.
 func (s *Service) allAgentPanesExited(ctx context.Context, client *tmux.Client, session string, targets []string) (bool, error) {
 	if len(targets) == 0 {
 		return true, nil
 	}
 
-	panes, err := client.ListPanes(ctx, session)
+	panes, err := client.ListSessionPanes(ctx, session)
 	if err != nil {
 		return false, err
 	}
-	if len(panes) == 0 {
-		return true, nil
-	}
 
-	paneTargets := make(map[string]struct{}, len(panes)*3)
+	running := make(map[string]bool, len(panes))
 	for _, pane := range panes {
-		paneTargets[fmt.Sprintf("%s:%s", session, pane.ID)] = struct{}{}
-		paneTargets[fmt.Sprintf("%s:%d.%d", session, pane.WindowIndex, pane.Index)] = struct{}{}
-		paneTargets[fmt.Sprintf("%s:%d", session, pane.Index)] = struct{}{}
+		running[pane.ID] = !shellCommands[pane.Command]
 	}
-
 	for _, target := range targets {
-		if _, ok := paneTargets[target]; ok {
+		if running[target] {
 			return false, nil
 		}
 	}
@@ -745,6 +932,17 @@ func (s *Service) allAgentPanesExited(ctx context.Context, client *tmux.Client,
 	return true, nil
 }
 
+// shellCommands are the pane commands of a pane whose agent has exited to
+// the shell it was started from.
+var shellCommands = map[string]bool{
+	"bash": true,
+	"zsh":  true,
+	"sh":   true,
+	"dash": true,
+	"fish": true,
+	"ksh":  true,
+}
+
 func (s *Service) computeWorkspacePulse(ctx context.Context, workspace *models.Workspace) *WorkspacePulse {
 	if workspace == nil {
 		return nil
@@ -759,7 +957,7 @@ func (s *Service) computeWorkspacePulse(ctx context.Context, workspace *models.W
 	if s.eventRepo != nil && s.agentRepo != nil {
 		agents, err := s.agentRepo.ListByWorkspace(ctx, workspace.ID)
 		if err != nil {
-			s.logger.Warn().Err(err).Str("workspace_id", workspace.ID).Msg("failed to list agents for pulse")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("workspace_id", workspace.ID).Msg("failed to list agents for pulse")
 		} else {
 			entityType := models.EntityTypeAgent
 			hasSource = true
@@ -776,6 +974,7 @@ func (s *Service) computeWorkspacePulse(ctx context.Context, workspace *models.W
 				})
 				if err != nil {
 					s.logger.Warn().
+						Ctx(ctx).
 						Err(err).
 						Str("agent_id", agentID).
 						Msg("failed to query events for pulse")
@@ -798,6 +997,7 @@ func (s *Service) computeWorkspacePulse(ctx context.Context, workspace *models.W
 		commitTimes, err := listCommitTimesSince(workspace.RepoPath, since, pulseMaxCommits)
 		if err != nil {
 			s.logger.Debug().
+				Ctx(ctx).
 				Err(err).
 				Str("workspace_id", workspace.ID).
 				Msg("unable to read commit history for pulse")
@@ -958,7 +1158,7 @@ func (s *Service) discoverAgentsInSession(ctx context.Context, workspace *models
 	}
 
 	for _, pane := range panes {
-		paneTarget := fmt.Sprintf("%s:%s", workspace.TmuxSession, pane.ID)
+		paneTarget := pane.ID
 		if _, ok := existingPanes[paneTarget]; ok {
 			continue
 		}
@@ -980,6 +1180,7 @@ func (s *Service) discoverAgentsInSession(ctx context.Context, workspace *models
 			WorkspaceID: workspace.ID,
 			Type:        agentType,
 			TmuxPane:    paneTarget,
+			TmuxSession: workspace.TmuxSession,
 			State:       models.AgentStateIdle,
 			StateInfo: models.StateInfo{
 				State:      models.AgentStateIdle,
@@ -992,11 +1193,12 @@ func (s *Service) discoverAgentsInSession(ctx context.Context, workspace *models
 		}
 
 		if err := s.agentRepo.Create(ctx, agent); err != nil {
-			s.logger.Warn().Err(err).Str("tmux_pane", paneTarget).Msg("failed to record discovered agent")
+			s.logger.Warn().Ctx(ctx).Err(err).Str("tmux_pane", paneTarget).Msg("failed to record discovered agent")
 			continue
 		}
 
 		s.logger.Info().
+			Ctx(ctx).
 			Str("workspace_id", workspace.ID).
 			Str("agent_id", agent.ID).
 			Str("tmux_pane", agent.TmuxPane).
@@ -1007,52 +1209,21 @@ func (s *Service) discoverAgentsInSession(ctx context.Context, workspace *models
 	return nil
 }
 
+// detectAgentType identifies the agent CLI in a pane from the signatures
+// registered adapters declare, checking the pane command before its output.
 func detectAgentType(command, screen string) (models.AgentType, string, []string) {
 	lowerCmd := strings.ToLower(strings.TrimSpace(command))
-	if agentType, ok := agentTypeFromHint(lowerCmd); ok {
+	if agentType, _, ok := adapters.DetectAgentType(lowerCmd); ok {
 		return agentType, fmt.Sprintf("pane command detected: %s", lowerCmd), []string{lowerCmd}
 	}
 
-	lowerScreen := strings.ToLower(screen)
-	if agentType, ok := agentTypeFromHint(lowerScreen); ok {
-		return agentType, "agent signature detected in pane output", []string{agentTypeHint(agentType)}
+	if agentType, signature, ok := adapters.DetectAgentType(screen); ok {
+		return agentType, "agent signature detected in pane output", []string{signature}
 	}
 
 	return "", "", nil
 }
 
-func agentTypeFromHint(hint string) (models.AgentType, bool) {
-	switch {
-	case strings.Contains(hint, "opencode"):
-		return models.AgentTypeOpenCode, true
-	case strings.Contains(hint, "claude"):
-		return models.AgentTypeClaudeCode, true
-	case strings.Contains(hint, "codex"):
-		return models.AgentTypeCodex, true
-	case strings.Contains(hint, "gemini"):
-		return models.AgentTypeGemini, true
-	case strings.Contains(hint, "aider"):
-		return models.AgentTypeGeneric, true
-	default:
-		return "", false
-	}
-}
-
-func agentTypeHint(agentType models.AgentType) string {
-	switch agentType {
-	case models.AgentTypeOpenCode:
-		return "opencode"
-	case models.AgentTypeClaudeCode:
-		return "claude"
-	case models.AgentTypeCodex:
-		return "codex"
-	case models.AgentTypeGemini:
-		return "gemini"
-	default:
-		return "agent"
-	}
-}
-
 // isTmuxSessionActive checks if a tmux session is running.
 func (s *Service) isTmuxSessionActive(ctx context.Context, nodeObj *models.Node, sessionName string) bool {
 	if !nodeObj.IsLocal {
@@ -1069,6 +1240,20 @@ func (s *Service) isTmuxSessionActive(ctx context.Context, nodeObj *models.Node,
 	return exists
 }
 
+// isPaneZoomed reports whether the session's current window is zoomed.
+func (s *Service) isPaneZoomed(ctx context.Context, sessionName string) bool {
+	panes, err := s.tmuxClient().ListPanes(ctx, sessionName)
+	if err != nil {
+		return false
+	}
+	for _, pane := range panes {
+		if pane.Zoomed {
+			return true
+		}
+	}
+	return false
+}
+
 // publishEvent publishes an event if a publisher is configured.
 func (s *Service) publishEvent(ctx context.Context, eventType models.EventType, workspaceID string, payload any) {
 	if s.publisher == nil {
@@ -1080,10 +1265,29 @@ func (s *Service) publishEvent(ctx context.Context, eventType models.EventType,
 		EntityType: models.EntityTypeWorkspace,
 		EntityID:   workspaceID,
 	}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			s.logger.Warn().Ctx(ctx).Err(err).Str("event_type", string(eventType)).Msg("failed to marshal event payload")
+		} else {
+			event.Payload = data
+		}
+	}
 
 	s.publisher.Publish(ctx, event)
 }
 
+// lifecyclePayload describes ws for its lifecycle events, so hooks can name
+// a workspace that may no longer exist.
+func lifecyclePayload(ws *models.Workspace) models.WorkspacePayload {
+	return models.WorkspacePayload{
+		Name:        ws.Name,
+		NodeID:      ws.NodeID,
+		RepoPath:    ws.RepoPath,
+		TmuxSession: ws.TmuxSession,
+	}
+}
+
 func (s *Service) tmuxClient() *tmux.Client {
 	if s.tmuxFactory != nil {
 		return s.tmuxFactory()
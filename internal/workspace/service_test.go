@@ -0,0 +1,44 @@
+package workspace
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/swarm/internal/adapters"
+	"github.com/opencode-ai/swarm/internal/models"
+)
+
+// TestDetectAgentTypeGolden pins pane detection as it was before the
+// adapters declared their signatures.
+func TestDetectAgentTypeGolden(t *testing.T) {
+	tests := []struct {
+		command, screen string
+		want            models.AgentType
+		reason          string
+		evidence        string
+	}{
+		{"opencode", "", models.AgentTypeOpenCode, "pane command detected: opencode", "opencode"},
+		{" Claude ", "", models.AgentTypeClaudeCode, "pane command detected: claude", "claude"},
+		{"codex", "", models.AgentTypeCodex, "pane command detected: codex", "codex"},
+		{"gemini", "", models.AgentTypeGemini, "pane command detected: gemini", "gemini"},
+		{"aider", "", models.AgentTypeGeneric, "pane command detected: aider", "aider"},
+		{"node", "Welcome to Claude Code", models.AgentTypeClaudeCode, "agent signature detected in pane output", "claude"},
+		{"node", "opencode> ready", models.AgentTypeOpenCode, "agent signature detected in pane output", "opencode"},
+		{"node", "codex and gemini", models.AgentTypeCodex, "agent signature detected in pane output", "codex"},
+		{"bash", "$ ls", "", "", ""},
+	}
+	for _, tt := range tests {
+		got, reason, evidence := detectAgentType(tt.command, tt.screen)
+		if got != tt.want || reason != tt.reason || strings.Join(evidence, ",") != tt.evidence {
+			t.Errorf("detectAgentType(%q, %q) = %q, %q, %v; want %q, %q, %s", tt.command, tt.screen, got, reason, evidence, tt.want, tt.reason, tt.evidence)
+		}
+	}
+}
+
+func TestDetectAgentTypeRegisteredAdapter(t *testing.T) {
+	t.Cleanup(adapters.RegisterAdapter(adapters.NewGenericAdapter("toy", "toy-cli")))
+
+	if got, _, _ := detectAgentType("toy-cli", ""); got != "toy" {
+		t.Fatalf("expected the toy adapter detected, got %q", got)
+	}
+}
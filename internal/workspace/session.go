@@ -2,55 +2,110 @@
 package workspace
 
 import (
-	"crypto/sha1"
-	"encoding/hex"
+	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
-	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
 )
 
-// GenerateTmuxSessionName builds a tmux-safe session name for a repo path.
-func GenerateTmuxSessionName(prefix, repoPath string) (string, error) {
-	if err := ValidateRepoPath(repoPath); err != nil {
-		return "", err
+// ErrSessionNameConflict is returned when a session name is already used by
+// another workspace on the same node.
+var ErrSessionNameConflict = errors.New("tmux session name already in use")
+
+// maxSessionSuffix bounds how many numeric suffixes are tried when a
+// generated session name is taken.
+const maxSessionSuffix = 100
+
+// sessionBaseName derives the generated session name for a new workspace
+// from its name (or repo directory) and ID.
+func sessionBaseName(name, repoPath, workspaceID string) string {
+	if name == "" {
+		name = filepath.Base(filepath.Clean(repoPath))
 	}
+	return tmux.SessionName(tmux.DefaultSessionPrefix, name, workspaceID)
+}
 
-	base := filepath.Base(repoPath)
-	slug := sanitizeTmuxName(base)
-	if slug == "" {
-		slug = "workspace"
+// uniqueSessionName returns base, or base with a numeric suffix, such that no
+// other workspace on the node uses the name and, when checkTmux is set, no
+// live tmux session has it. Extra names in reserved are treated as taken.
+func (s *Service) uniqueSessionName(ctx context.Context, nodeID, base string, checkTmux bool, reserved map[string]string) (string, error) {
+	taken, err := s.sessionNamesOnNode(ctx, nodeID, "")
+	if err != nil {
+		return "", err
+	}
+	for name, owner := range reserved {
+		taken[name] = owner
 	}
 
-	hash := shortHash(repoPath)
-	prefix = sanitizeTmuxName(prefix)
-	if prefix != "" {
-		return fmt.Sprintf("%s-%s-%s", prefix, slug, hash), nil
+	var client *tmux.Client
+	if checkTmux {
+		client = s.tmuxClient()
 	}
-	return fmt.Sprintf("%s-%s", slug, hash), nil
-}
 
-func sanitizeTmuxName(value string) string {
-	value = strings.ToLower(value)
-	var b strings.Builder
-	lastDash := false
-
-	for _, r := range value {
-		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
-			b.WriteRune(r)
-			lastDash = false
+	for i := 1; i <= maxSessionSuffix; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		if _, ok := taken[tmux.NormalizeSessionName(candidate)]; ok {
 			continue
 		}
+		if client != nil {
+			exists, err := client.HasSession(ctx, candidate)
+			if err != nil {
+				return "", fmt.Errorf("failed to check tmux session %s: %w", candidate, err)
+			}
+			if exists {
+				continue
+			}
+		}
+		return candidate, nil
+	}
 
-		if !lastDash {
-			b.WriteByte('-')
-			lastDash = true
+	return "", fmt.Errorf("%w: no free name for %s after %d attempts", ErrSessionNameConflict, base, maxSessionSuffix)
+}
+
+// checkSessionAvailable fails if another workspace on the node tracks a
+// session that tmux would treat as the same one.
+func (s *Service) checkSessionAvailable(ctx context.Context, nodeID, session, excludeID string) error {
+	taken, err := s.sessionNamesOnNode(ctx, nodeID, excludeID)
+	if err != nil {
+		return err
+	}
+	if owner, ok := taken[tmux.NormalizeSessionName(session)]; ok {
+		return fmt.Errorf("%w: %s is used by workspace %s", ErrSessionNameConflict, session, owner)
+	}
+	return nil
+}
+
+// sessionNamesOnNode maps normalized session names on a node to the name of
+// the workspace using them.
+func (s *Service) sessionNamesOnNode(ctx context.Context, nodeID, excludeID string) (map[string]string, error) {
+	workspaces, err := s.repo.ListByNode(ctx, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces on node: %w", err)
+	}
+
+	taken := make(map[string]string, len(workspaces))
+	for _, ws := range workspaces {
+		if ws.ID == excludeID || ws.TmuxSession == "" {
+			continue
 		}
+		taken[tmux.NormalizeSessionName(ws.TmuxSession)] = workspaceLabel(ws)
 	}
+	return taken, nil
+}
 
-	return strings.Trim(b.String(), "-")
+func workspaceLabel(ws *models.Workspace) string {
+	if ws.Name != "" {
+		return ws.Name
+	}
+	return ws.ID
 }
 
-func shortHash(value string) string {
-	sum := sha1.Sum([]byte(value))
-	return hex.EncodeToString(sum[:])[:8]
+func sanitizeTmuxName(value string) string {
+	return tmux.SanitizeSessionName(value)
 }
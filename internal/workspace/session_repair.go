@@ -0,0 +1,241 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/tmux"
+)
+
+// SessionRepairReason explains why a workspace session needs repair.
+type SessionRepairReason string
+
+const (
+	// SessionRepairInvalid marks names containing characters tmux rewrites.
+	SessionRepairInvalid SessionRepairReason = "invalid"
+
+	// SessionRepairCollision marks workspaces that share a live session with
+	// an older workspace on the same node.
+	SessionRepairCollision SessionRepairReason = "collision"
+)
+
+// SessionRepair describes one workspace session rename.
+type SessionRepair struct {
+	WorkspaceID   string              `json:"workspace_id"`
+	WorkspaceName string              `json:"workspace_name"`
+	NodeID        string              `json:"node_id"`
+	Reason        SessionRepairReason `json:"reason"`
+	OldSession    string              `json:"old_session"`
+	NewSession    string              `json:"new_session"`
+	// SharedWith names the workspace that keeps the live session on a collision.
+	SharedWith string `json:"shared_with,omitempty"`
+	// Renamed is true when the live tmux session was renamed.
+	Renamed bool `json:"renamed"`
+	// AgentsUpdated counts agent pane targets rewritten to the new name.
+	AgentsUpdated int    `json:"agents_updated"`
+	Applied       bool   `json:"applied"`
+	Error         string `json:"error,omitempty"`
+}
+
+// SessionRepairReport summarizes a RepairSessions run.
+type SessionRepairReport struct {
+	DryRun  bool            `json:"dry_run"`
+	Checked int             `json:"checked"`
+	Repairs []SessionRepair `json:"repairs"`
+}
+
+// RepairSessions finds workspaces whose tmux session names are not
+// tmux-safe or resolve to the same live session as another workspace on the
+// node, and gives each a fresh unique name. Invalid names are fixed by
+// renaming the live session; on a collision the oldest workspace keeps the
+// session and the others are pointed at new names. With dryRun, only the
+// plan is returned.
+func (s *Service) RepairSessions(ctx context.Context, dryRun bool) (*SessionRepairReport, error) {
+	workspaces, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	report := &SessionRepairReport{DryRun: dryRun, Checked: len(workspaces), Repairs: []SessionRepair{}}
+
+	byNode := make(map[string][]*models.Workspace)
+	nodeIDs := make([]string, 0)
+	for _, ws := range workspaces {
+		if _, ok := byNode[ws.NodeID]; !ok {
+			nodeIDs = append(nodeIDs, ws.NodeID)
+		}
+		byNode[ws.NodeID] = append(byNode[ws.NodeID], ws)
+	}
+	sort.Strings(nodeIDs)
+
+	for _, nodeID := range nodeIDs {
+		repairs, err := s.repairNodeSessions(ctx, nodeID, byNode[nodeID], dryRun)
+		if err != nil {
+			return nil, err
+		}
+		report.Repairs = append(report.Repairs, repairs...)
+	}
+
+	return report, nil
+}
+
+func (s *Service) repairNodeSessions(ctx context.Context, nodeID string, workspaces []*models.Workspace, dryRun bool) ([]SessionRepair, error) {
+	sort.SliceStable(workspaces, func(i, j int) bool {
+		return workspaces[i].CreatedAt.Before(workspaces[j].CreatedAt)
+	})
+
+	var isLocal bool
+	if nodeObj, err := s.nodeService.GetNode(ctx, nodeID); err == nil {
+		isLocal = nodeObj.IsLocal
+	}
+
+	// owners maps a live (normalized) session name to the workspace keeping it.
+	owners := make(map[string]*models.Workspace, len(workspaces))
+	// reserved holds every name that is or will be in use after repair.
+	reserved := make(map[string]string, len(workspaces))
+	for _, ws := range workspaces {
+		if ws.TmuxSession != "" {
+			reserved[tmux.NormalizeSessionName(ws.TmuxSession)] = workspaceLabel(ws)
+		}
+	}
+
+	var repairs []SessionRepair
+	for _, ws := range workspaces {
+		if ws.TmuxSession == "" {
+			continue
+		}
+		live := tmux.NormalizeSessionName(ws.TmuxSession)
+		owner, shared := owners[live]
+		invalid := tmux.ValidateSessionName(ws.TmuxSession) != nil
+		if !shared {
+			owners[live] = ws
+		}
+		if !shared && !invalid {
+			continue
+		}
+
+		newName, err := s.uniqueSessionName(ctx, nodeID, tmux.SessionName(tmux.DefaultSessionPrefix, ws.Name, ws.ID), isLocal, reserved)
+		if err != nil {
+			return nil, err
+		}
+		reserved[tmux.NormalizeSessionName(newName)] = workspaceLabel(ws)
+
+		repair := SessionRepair{
+			WorkspaceID:   ws.ID,
+			WorkspaceName: ws.Name,
+			NodeID:        nodeID,
+			Reason:        SessionRepairInvalid,
+			OldSession:    ws.TmuxSession,
+			NewSession:    newName,
+		}
+		if shared {
+			repair.Reason = SessionRepairCollision
+			repair.SharedWith = workspaceLabel(owner)
+		}
+
+		if !dryRun {
+			if !isLocal {
+				repair.Error = "remote node tmux repair not yet implemented"
+			} else if err := s.applySessionRepair(ctx, ws, live, &repair, workspaces); err != nil {
+				repair.Error = err.Error()
+			} else {
+				repair.Applied = true
+			}
+		}
+		repairs = append(repairs, repair)
+	}
+
+	return repairs, nil
+}
+
+// applySessionRepair renames the live session when ws owns it, rewrites
+// agent pane targets that point at it, and updates the workspace row.
+func (s *Service) applySessionRepair(ctx context.Context, ws *models.Workspace, live string, repair *SessionRepair, nodeWorkspaces []*models.Workspace) error {
+	if repair.Reason == SessionRepairInvalid {
+		client := s.tmuxClient()
+		exists, err := client.HasSession(ctx, live)
+		if err != nil {
+			return fmt.Errorf("failed to check tmux session %s: %w", live, err)
+		}
+		if exists {
+			if err := client.RenameSession(ctx, live, repair.NewSession); err != nil {
+				return err
+			}
+			repair.Renamed = true
+
+			// Panes follow the live session, so every agent on the node that
+			// targets it moves to the new name.
+			updated, err := s.retargetAgentPanes(ctx, nodeWorkspaces, []string{ws.TmuxSession, live}, repair.NewSession)
+			if err != nil {
+				return err
+			}
+			repair.AgentsUpdated = updated
+		}
+	}
+
+	ws.TmuxSession = repair.NewSession
+	if err := s.repo.Update(ctx, ws); err != nil {
+		return fmt.Errorf("failed to update workspace: %w", err)
+	}
+
+	s.logger.Info().
+		Ctx(ctx).
+		Str("workspace_id", ws.ID).
+		Str("old_session", repair.OldSession).
+		Str("new_session", repair.NewSession).
+		Str("reason", string(repair.Reason)).
+		Bool("renamed", repair.Renamed).
+		Msg("workspace session repaired")
+
+	return nil
+}
+
+func (s *Service) retargetAgentPanes(ctx context.Context, workspaces []*models.Workspace, oldNames []string, newName string) (int, error) {
+	if s.agentRepo == nil {
+		return 0, nil
+	}
+
+	updated := 0
+	var errs []error
+	for _, ws := range workspaces {
+		agents, err := s.agentRepo.ListByWorkspace(ctx, ws.ID)
+		if err != nil {
+			return updated, fmt.Errorf("failed to list agents for workspace %s: %w", ws.ID, err)
+		}
+		for _, agent := range agents {
+			target, retargeted := retargetPane(agent.TmuxPane, oldNames, newName)
+			renamed := agent.TmuxSession != "" && slices.Contains(oldNames, agent.TmuxSession)
+			if !retargeted && !renamed {
+				continue
+			}
+			agent.TmuxPane = target
+			agent.TmuxSession = newName
+			if err := s.agentRepo.Update(ctx, agent); err != nil {
+				errs = append(errs, fmt.Errorf("agent %s: %w", agent.ID, err))
+				continue
+			}
+			updated++
+		}
+	}
+
+	return updated, errors.Join(errs...)
+}
+
+// retargetPane rewrites a legacy session:window.pane target whose session is
+// one of oldNames to use newName. Pane IDs carry no session and are kept.
+func retargetPane(target string, oldNames []string, newName string) (string, bool) {
+	for _, old := range oldNames {
+		if old == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(target, old+":"); ok {
+			return newName + ":" + rest, true
+		}
+	}
+	return target, false
+}
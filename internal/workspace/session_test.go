@@ -0,0 +1,377 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/swarm/internal/db"
+	"github.com/opencode-ai/swarm/internal/models"
+	"github.com/opencode-ai/swarm/internal/node"
+	"github.com/opencode-ai/swarm/internal/tmux"
+	"github.com/opencode-ai/swarm/internal/tmux/tmuxtest"
+)
+
+// sessionExecutor fakes a tmux server holding a fixed set of sessions.
+type sessionExecutor struct {
+	sessions map[string]bool
+	commands []string
+}
+
+func (e *sessionExecutor) Exec(ctx context.Context, cmd string) ([]byte, []byte, error) {
+	e.commands = append(e.commands, cmd)
+
+	fields := strings.Fields(cmd)
+	switch {
+	case strings.HasPrefix(cmd, "tmux has-session -t "):
+		name := strings.Trim(fields[3], "'")
+		if e.sessions[name] {
+			return nil, nil, nil
+		}
+		return nil, []byte("can't find session: " + name), fmt.Errorf("exit status 1")
+	case strings.HasPrefix(cmd, "tmux rename-session -t "):
+		oldName := strings.Trim(fields[3], "'")
+		newName := strings.Trim(fields[4], "'")
+		delete(e.sessions, oldName)
+		e.sessions[newName] = true
+		return nil, nil, nil
+	case strings.HasPrefix(cmd, "tmux kill-session -t "):
+		delete(e.sessions, strings.Trim(fields[3], "'"))
+		return nil, nil, nil
+	default:
+		return nil, nil, nil
+	}
+}
+
+type sessionTestEnv struct {
+	service   *Service
+	wsRepo    *db.WorkspaceRepository
+	agentRepo *db.AgentRepository
+	node      *models.Node
+	exec      *sessionExecutor
+	database  *db.DB
+}
+
+func newSessionTestEnv(t *testing.T) *sessionTestEnv {
+	t.Helper()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	nodeRepo := db.NewNodeRepository(database)
+	localNode := &models.Node{Name: "local", IsLocal: true, Status: models.NodeStatusUnknown, SSHBackend: models.SSHBackendAuto}
+	if err := nodeRepo.Create(ctx, localNode); err != nil {
+		t.Fatalf("failed to create local node: %v", err)
+	}
+
+	exec := &sessionExecutor{sessions: map[string]bool{}}
+	wsRepo := db.NewWorkspaceRepository(database)
+	agentRepo := db.NewAgentRepository(database)
+	service := NewService(wsRepo, node.NewService(nodeRepo), agentRepo,
+		WithTmuxClientFactory(func() *tmux.Client { return tmux.NewClient(exec) }))
+
+	return &sessionTestEnv{service: service, wsRepo: wsRepo, agentRepo: agentRepo, node: localNode, exec: exec, database: database}
+}
+
+func sameBasenameDirs(t *testing.T) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	first := filepath.Join(root, "one", "app")
+	second := filepath.Join(root, "two", "app")
+	for _, dir := range []string{first, second} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	return first, second
+}
+
+func TestCreateWorkspaceSessionNames(t *testing.T) {
+	env := newSessionTestEnv(t)
+	ctx := context.Background()
+	first, second := sameBasenameDirs(t)
+
+	a, err := env.service.CreateWorkspace(ctx, CreateWorkspaceInput{NodeID: env.node.ID, RepoPath: first})
+	if err != nil {
+		t.Fatalf("CreateWorkspace failed: %v", err)
+	}
+	b, err := env.service.CreateWorkspace(ctx, CreateWorkspaceInput{NodeID: env.node.ID, RepoPath: second})
+	if err != nil {
+		t.Fatalf("CreateWorkspace failed: %v", err)
+	}
+
+	if a.TmuxSession == b.TmuxSession {
+		t.Fatalf("expected distinct sessions for duplicate basenames, both got %q", a.TmuxSession)
+	}
+	if want := tmux.SessionName("swarm", "app", a.ID); a.TmuxSession != want {
+		t.Fatalf("expected session %q derived from name and ID, got %q", want, a.TmuxSession)
+	}
+}
+
+func TestCreateWorkspaceSessionSuffixesTakenNames(t *testing.T) {
+	env := newSessionTestEnv(t)
+	ctx := context.Background()
+	first, _ := sameBasenameDirs(t)
+
+	// Every base name is taken by a live session, so the first free suffix wins.
+	taken, err := env.service.uniqueSessionName(ctx, env.node.ID, "swarm-app-1234", false, nil)
+	if err != nil || taken != "swarm-app-1234" {
+		t.Fatalf("expected base name to be free, got %q, %v", taken, err)
+	}
+	env.exec.sessions["swarm-app-1234"] = true
+	env.exec.sessions["swarm-app-1234-2"] = true
+	got, err := env.service.uniqueSessionName(ctx, env.node.ID, "swarm-app-1234", true, nil)
+	if err != nil {
+		t.Fatalf("uniqueSessionName failed: %v", err)
+	}
+	if got != "swarm-app-1234-3" {
+		t.Fatalf("expected swarm-app-1234-3, got %q", got)
+	}
+
+	ws, err := env.service.CreateWorkspace(ctx, CreateWorkspaceInput{NodeID: env.node.ID, RepoPath: first, TmuxSession: "shared"})
+	if err != nil {
+		t.Fatalf("CreateWorkspace failed: %v", err)
+	}
+	got, err = env.service.uniqueSessionName(ctx, env.node.ID, ws.TmuxSession, false, nil)
+	if err != nil || got != "shared-2" {
+		t.Fatalf("expected workspace-owned name to be suffixed, got %q, %v", got, err)
+	}
+}
+
+func TestCreateWorkspaceRejectsBadSessionNames(t *testing.T) {
+	env := newSessionTestEnv(t)
+	ctx := context.Background()
+	first, second := sameBasenameDirs(t)
+
+	if _, err := env.service.CreateWorkspace(ctx, CreateWorkspaceInput{NodeID: env.node.ID, RepoPath: first, TmuxSession: "api.v2"}); !errors.Is(err, tmux.ErrInvalidSessionName) {
+		t.Fatalf("expected ErrInvalidSessionName, got %v", err)
+	}
+
+	if _, err := env.service.CreateWorkspace(ctx, CreateWorkspaceInput{NodeID: env.node.ID, RepoPath: first, TmuxSession: "api_v2"}); err != nil {
+		t.Fatalf("CreateWorkspace failed: %v", err)
+	}
+	if _, err := env.service.CreateWorkspace(ctx, CreateWorkspaceInput{NodeID: env.node.ID, RepoPath: second, TmuxSession: "api_v2"}); !errors.Is(err, ErrSessionNameConflict) {
+		t.Fatalf("expected ErrSessionNameConflict, got %v", err)
+	}
+	if _, err := env.service.ImportWorkspace(ctx, ImportWorkspaceInput{NodeID: env.node.ID, RepoPath: second, TmuxSession: "api_v2"}); !errors.Is(err, ErrSessionNameConflict) {
+		t.Fatalf("expected ErrSessionNameConflict on import, got %v", err)
+	}
+}
+
+func TestRepairSessions(t *testing.T) {
+	env := newSessionTestEnv(t)
+	ctx := context.Background()
+
+	// "api.v2" and "api_v2" are distinct rows but the same live tmux
+	// session; "web.ui" is unsafe but has the session to itself. The keeper
+	// sorts after the duplicate by name, so only creation time picks it.
+	base := time.Now().UTC().Add(-time.Hour)
+	rows := []*models.Workspace{
+		{ID: "ws-keeper-1", Name: "zeta-api", TmuxSession: "api.v2", RepoPath: "/repos/api"},
+		{ID: "ws-dup-2", Name: "api-copy", TmuxSession: "api_v2", RepoPath: "/repos/copy/api"},
+		{ID: "ws-web-3", Name: "web", TmuxSession: "web.ui", RepoPath: "/repos/web"},
+		{ID: "ws-ok-4", Name: "ok", TmuxSession: "swarm-ok-ws-ok-4", RepoPath: "/repos/ok"},
+	}
+	for i, ws := range rows {
+		ws.NodeID = env.node.ID
+		ws.Status = models.WorkspaceStatusActive
+		if err := env.wsRepo.Create(ctx, ws); err != nil {
+			t.Fatalf("failed to create workspace: %v", err)
+		}
+		createdAt := base.Add(time.Duration(i) * time.Minute).Format(time.RFC3339)
+		if _, err := env.database.ExecContext(ctx, "UPDATE workspaces SET created_at = ? WHERE id = ?", createdAt, ws.ID); err != nil {
+			t.Fatalf("failed to set created_at: %v", err)
+		}
+	}
+	env.exec.sessions["api_v2"] = true
+	env.exec.sessions["web_ui"] = true
+
+	agents := []*models.Agent{
+		{WorkspaceID: "ws-keeper-1", TmuxPane: "api_v2:1.0"},
+		{WorkspaceID: "ws-dup-2", TmuxPane: "api_v2:1.1"},
+		{WorkspaceID: "ws-web-3", TmuxPane: "web.ui:1.0"},
+	}
+	for _, a := range agents {
+		a.Type = models.AgentTypeOpenCode
+		a.State = models.AgentStateIdle
+		a.StateInfo = models.StateInfo{State: models.AgentStateIdle, Confidence: models.StateConfidenceHigh}
+		if err := env.agentRepo.Create(ctx, a); err != nil {
+			t.Fatalf("failed to create agent: %v", err)
+		}
+	}
+
+	plan, err := env.service.RepairSessions(ctx, true)
+	if err != nil {
+		t.Fatalf("RepairSessions dry run failed: %v", err)
+	}
+	if len(plan.Repairs) != 3 {
+		t.Fatalf("expected 3 planned repairs, got %+v", plan.Repairs)
+	}
+	for _, cmd := range env.exec.commands {
+		if strings.Contains(cmd, "rename-session") {
+			t.Fatalf("dry run must not rename sessions, ran %q", cmd)
+		}
+	}
+
+	report, err := env.service.RepairSessions(ctx, false)
+	if err != nil {
+		t.Fatalf("RepairSessions failed: %v", err)
+	}
+	byID := map[string]SessionRepair{}
+	for _, repair := range report.Repairs {
+		if repair.Error != "" || !repair.Applied {
+			t.Fatalf("expected repair to apply cleanly, got %+v", repair)
+		}
+		byID[repair.WorkspaceID] = repair
+	}
+
+	keeper := byID["ws-keeper-1"]
+	if keeper.Reason != SessionRepairInvalid || !keeper.Renamed || keeper.AgentsUpdated != 2 {
+		t.Fatalf("unexpected keeper repair %+v", keeper)
+	}
+	dup := byID["ws-dup-2"]
+	if dup.Reason != SessionRepairCollision || dup.Renamed || dup.SharedWith != "zeta-api" {
+		t.Fatalf("unexpected collision repair %+v", dup)
+	}
+	web := byID["ws-web-3"]
+	if web.Reason != SessionRepairInvalid || !web.Renamed || web.AgentsUpdated != 1 {
+		t.Fatalf("unexpected web repair %+v", web)
+	}
+	if _, ok := byID["ws-ok-4"]; ok {
+		t.Fatal("expected healthy workspace to be left alone")
+	}
+
+	seen := map[string]bool{}
+	for _, id := range []string{"ws-keeper-1", "ws-dup-2", "ws-web-3", "ws-ok-4"} {
+		ws, err := env.wsRepo.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to reload workspace: %v", err)
+		}
+		if err := tmux.ValidateSessionName(ws.TmuxSession); err != nil {
+			t.Fatalf("workspace %s still has invalid session: %v", id, err)
+		}
+		if seen[ws.TmuxSession] {
+			t.Fatalf("session %s is still shared", ws.TmuxSession)
+		}
+		seen[ws.TmuxSession] = true
+	}
+	if !env.exec.sessions[keeper.NewSession] || env.exec.sessions["api_v2"] {
+		t.Fatalf("expected live session renamed to %s, sessions: %v", keeper.NewSession, env.exec.sessions)
+	}
+
+	// Agents follow the live session they physically run in.
+	for _, a := range agents {
+		reloaded, err := env.agentRepo.Get(ctx, a.ID)
+		if err != nil {
+			t.Fatalf("failed to reload agent: %v", err)
+		}
+		wantSession := keeper.NewSession
+		if a.WorkspaceID == "ws-web-3" {
+			wantSession = web.NewSession
+		}
+		if !strings.HasPrefix(reloaded.TmuxPane, wantSession+":") {
+			t.Fatalf("expected agent pane in %s, got %s", wantSession, reloaded.TmuxPane)
+		}
+	}
+
+	again, err := env.service.RepairSessions(ctx, false)
+	if err != nil {
+		t.Fatalf("second RepairSessions failed: %v", err)
+	}
+	if len(again.Repairs) != 0 {
+		t.Fatalf("expected repair to be idempotent, got %+v", again.Repairs)
+	}
+}
+
+func TestDestroyWorkspaceDryRun(t *testing.T) {
+	env := newSessionTestEnv(t)
+	ctx := context.Background()
+
+	ws := &models.Workspace{NodeID: env.node.ID, Name: "api", TmuxSession: "swarm-api", RepoPath: "/repos/api", Status: models.WorkspaceStatusActive}
+	if err := env.wsRepo.Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	env.exec.sessions["swarm-api"] = true
+
+	plan, err := env.service.DestroyWorkspace(ctx, ws.ID, true)
+	if err != nil {
+		t.Fatalf("DestroyWorkspace dry run failed: %v", err)
+	}
+	if !plan.DryRun || !plan.KillSession || plan.TmuxSession != "swarm-api" {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	if !env.exec.sessions["swarm-api"] {
+		t.Fatal("dry run must not kill the session")
+	}
+	if _, err := env.wsRepo.Get(ctx, ws.ID); err != nil {
+		t.Fatalf("dry run must keep the workspace, got %v", err)
+	}
+
+	done, err := env.service.DestroyWorkspace(ctx, ws.ID, false)
+	if err != nil {
+		t.Fatalf("DestroyWorkspace failed: %v", err)
+	}
+	done.DryRun = true
+	if *done != *plan {
+		t.Fatalf("expected the real run to match the plan, got %+v, planned %+v", done, plan)
+	}
+	if env.exec.sessions["swarm-api"] {
+		t.Fatal("expected the session killed")
+	}
+	if _, err := env.wsRepo.Get(ctx, ws.ID); !errors.Is(err, db.ErrWorkspaceNotFound) {
+		t.Fatalf("expected the workspace removed, got %v", err)
+	}
+}
+
+func TestGracefulShutdownAgentsStopsWaitingOnceAgentsExit(t *testing.T) {
+	ctx := context.Background()
+	env := newSessionTestEnv(t)
+	ws := &models.Workspace{NodeID: env.node.ID, Name: "api", RepoPath: "/repo", TmuxSession: "swarm-api"}
+	if err := env.wsRepo.Create(ctx, ws); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	srv := tmuxtest.NewServer(tmuxtest.WithProgram("claude", func(term *tmuxtest.Terminal) {
+		term.Print("claude> ")
+	}))
+	client := tmux.NewClient(srv)
+	if err := client.NewSession(ctx, ws.TmuxSession, "/repo"); err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	if err := client.NewWindow(ctx, ws.TmuxSession, tmux.AgentWindowName, "/repo"); err != nil {
+		t.Fatalf("NewWindow failed: %v", err)
+	}
+	for range 2 {
+		paneID, err := client.SplitWindow(ctx, ws.TmuxSession+":"+tmux.AgentWindowName, false, "/repo")
+		if err != nil {
+			t.Fatalf("SplitWindow failed: %v", err)
+		}
+		if err := client.SendKeys(ctx, paneID, "claude", true, true); err != nil {
+			t.Fatalf("SendKeys failed: %v", err)
+		}
+		agent := &models.Agent{WorkspaceID: ws.ID, Type: models.AgentTypeClaudeCode, TmuxPane: paneID, TmuxSession: ws.TmuxSession, State: models.AgentStateIdle}
+		if err := env.agentRepo.Create(ctx, agent); err != nil {
+			t.Fatalf("failed to create agent: %v", err)
+		}
+	}
+
+	// Both CLIs exit on the interrupt, so the grace period is cut short.
+	start := time.Now()
+	env.service.gracefulShutdownAgents(ctx, client, ws.ID, ws.TmuxSession)
+	if elapsed := time.Since(start); elapsed >= agentShutdownGracePeriod {
+		t.Fatalf("expected shutdown to stop waiting once the agents exited, took %s", elapsed)
+	}
+}